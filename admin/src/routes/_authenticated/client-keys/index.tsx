@@ -208,6 +208,18 @@ const SCOPE_GROUPS = [
       },
     ],
   },
+  {
+    name: 'Queues',
+    description: 'Message queues',
+    scopes: [
+      { id: 'read:queues', label: 'Read', description: 'View queues' },
+      {
+        id: 'write:queues',
+        label: 'Write',
+        description: 'Manage queues, send and consume messages',
+      },
+    ],
+  },
 ]
 
 const ClientKeysPage = () => {
@@ -25,6 +25,7 @@ interface SystemSetting {
   description?: string
   is_overridden: boolean
   override_source?: string
+  warnings?: { code: string; severity: string; message: string }[]
   created_at: string
   updated_at: string
 }
@@ -136,13 +137,16 @@ function FeaturesPage() {
 
   const updateFeatureMutation = useMutation({
     mutationFn: async ({ key, value }: { key: string; value: boolean }) => {
-      await apiClient.put(`/api/v1/admin/system/settings/${key}`, {
-        value: { value },
-      })
+      const response = await apiClient.put<SystemSetting>(
+        `/api/v1/admin/system/settings/${key}`,
+        { value: { value } }
+      )
+      return response.data
     },
-    onSuccess: () => {
+    onSuccess: (setting) => {
       queryClient.invalidateQueries({ queryKey: ['feature-settings'] })
       toast.success('Feature settings updated')
+      setting?.warnings?.forEach((warning) => toast.warning(warning.message))
     },
     onError: (error: unknown) => {
       if (error && typeof error === 'object' && 'response' in error) {
@@ -147,13 +147,15 @@ function SecuritySettingsPage() {
   // Update setting mutation
   const updateSettingMutation = useMutation({
     mutationFn: async ({ key, value }: { key: string; value: boolean }) => {
-      await apiClient.put(`/api/v1/admin/system/settings/${key}`, {
-        value: { value },
-      })
+      const response = await apiClient.put<{
+        warnings?: { code: string; severity: string; message: string }[]
+      }>(`/api/v1/admin/system/settings/${key}`, { value: { value } })
+      return response.data
     },
-    onSuccess: () => {
+    onSuccess: (setting) => {
       queryClient.invalidateQueries({ queryKey: ['security-settings'] })
       toast.success('Security settings updated')
+      setting?.warnings?.forEach((warning) => toast.warning(warning.message))
     },
     onError: () => {
       toast.error('Failed to update security settings')
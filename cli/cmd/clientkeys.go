@@ -1,13 +1,18 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
 	"github.com/nimbleflux/fluxbase/cli/output"
 	"github.com/nimbleflux/fluxbase/cli/util"
@@ -25,6 +30,13 @@ var (
 	ckScopes    string
 	ckRateLimit int
 	ckExpires   string
+
+	ckBootstrapDescription string
+	ckBootstrapKeyExpires  string
+
+	ckEnrollServer string
+	ckEnrollToken  string
+	ckEnrollName   string
 )
 
 var clientkeysListCmd = &cobra.Command{
@@ -87,6 +99,67 @@ Examples:
 	RunE:    runClientKeysDelete,
 }
 
+var clientkeysBootstrapCmd = &cobra.Command{
+	Use:     "bootstrap",
+	Aliases: []string{"bootstrap-tokens"},
+	Short:   "Manage client key bootstrap tokens",
+	Long: `Manage one-time bootstrap tokens that new services exchange for a client key.
+
+A bootstrap token is short-lived and can only be used once. Hand it to a new
+service instead of a long-lived client key, and let the service enroll itself
+with 'fluxbase clientkeys enroll'.`,
+}
+
+var clientkeysBootstrapCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a bootstrap token",
+	Long: `Create a one-time bootstrap token (admin only).
+
+Examples:
+  fluxbase clientkeys bootstrap create --name "worker" --scopes "read:tables,write:tables"
+  fluxbase clientkeys bootstrap create --name "ci" --scopes "read:*" --expires 15m --key-expires 30d`,
+	PreRunE: requireAuth,
+	RunE:    runClientKeysBootstrapCreate,
+}
+
+var clientkeysBootstrapListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List bootstrap tokens",
+	Long: `List bootstrap tokens and whether they have been used.
+
+Examples:
+  fluxbase clientkeys bootstrap list`,
+	PreRunE: requireAuth,
+	RunE:    runClientKeysBootstrapList,
+}
+
+var clientkeysBootstrapRevokeCmd = &cobra.Command{
+	Use:   "revoke [id]",
+	Short: "Revoke a bootstrap token",
+	Long: `Revoke an unused bootstrap token so it can no longer be exchanged.
+
+Examples:
+  fluxbase clientkeys bootstrap revoke abc123`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: requireAuth,
+	RunE:    runClientKeysBootstrapRevoke,
+}
+
+var clientkeysEnrollCmd = &cobra.Command{
+	Use:   "enroll",
+	Short: "Exchange a bootstrap token for a client key",
+	Long: `Exchange a one-time bootstrap token for a client key.
+
+This command does not require a login. The server is taken from --server or
+FLUXBASE_SERVER, and the token from --token or FLUXBASE_BOOTSTRAP_TOKEN.
+Use --quiet to print only the key, e.g. to capture it in a script.
+
+Examples:
+  fluxbase clientkeys enroll --server https://api.example.com --token fbb_xxx --name worker-1
+  FLUXBASE_KEY=$(fluxbase clientkeys enroll --token "$FLUXBASE_BOOTSTRAP_TOKEN" -q)`,
+	RunE: runClientKeysEnroll,
+}
+
 func init() {
 	// Create flags
 	clientkeysCreateCmd.Flags().StringVar(&ckName, "name", "", "Client key name (required)")
@@ -100,6 +173,37 @@ func init() {
 	clientkeysCmd.AddCommand(clientkeysGetCmd)
 	clientkeysCmd.AddCommand(clientkeysRevokeCmd)
 	clientkeysCmd.AddCommand(clientkeysDeleteCmd)
+
+	// Bootstrap token flags
+	clientkeysBootstrapCreateCmd.Flags().StringVar(&ckName, "name", "", "Bootstrap token name, used as the prefix of enrolled key names (required)")
+	clientkeysBootstrapCreateCmd.Flags().StringVar(&ckBootstrapDescription, "description", "", "Bootstrap token description")
+	clientkeysBootstrapCreateCmd.Flags().StringVar(&ckScopes, "scopes", "", "Comma-separated scopes the enrolled key may have (required)")
+	clientkeysBootstrapCreateCmd.Flags().IntVar(&ckRateLimit, "rate-limit", 0, "Requests per minute for the enrolled key (default 100)")
+	clientkeysBootstrapCreateCmd.Flags().StringVar(&ckExpires, "expires", "", "How long the token can be used (e.g., 15m, 1h, 7d; default 1h)")
+	clientkeysBootstrapCreateCmd.Flags().StringVar(&ckBootstrapKeyExpires, "key-expires", "", "Lifetime of the enrolled client key (e.g., 30d, 1y; default no expiry)")
+	_ = clientkeysBootstrapCreateCmd.MarkFlagRequired("name")
+	_ = clientkeysBootstrapCreateCmd.MarkFlagRequired("scopes")
+
+	clientkeysBootstrapCmd.AddCommand(clientkeysBootstrapCreateCmd)
+	clientkeysBootstrapCmd.AddCommand(clientkeysBootstrapListCmd)
+	clientkeysBootstrapCmd.AddCommand(clientkeysBootstrapRevokeCmd)
+	clientkeysCmd.AddCommand(clientkeysBootstrapCmd)
+
+	// Enroll flags
+	clientkeysEnrollCmd.Flags().StringVar(&ckEnrollServer, "server", "", "Fluxbase server URL (default FLUXBASE_SERVER)")
+	clientkeysEnrollCmd.Flags().StringVar(&ckEnrollToken, "token", "", "Bootstrap token (default FLUXBASE_BOOTSTRAP_TOKEN)")
+	clientkeysEnrollCmd.Flags().StringVar(&ckEnrollName, "name", "", "Name appended to the bootstrap token name, e.g. the host name")
+	clientkeysEnrollCmd.Flags().StringVar(&ckScopes, "scopes", "", "Comma-separated scopes, narrowing the token's scopes")
+	clientkeysCmd.AddCommand(clientkeysEnrollCmd)
+}
+
+// splitScopes splits a comma-separated scope list
+func splitScopes(scopes string) []string {
+	scopeList := strings.Split(scopes, ",")
+	for i, s := range scopeList {
+		scopeList[i] = strings.TrimSpace(s)
+	}
+	return scopeList
 }
 
 func runClientKeysList(cmd *cobra.Command, args []string) error {
@@ -160,11 +264,7 @@ func runClientKeysCreate(cmd *cobra.Command, args []string) error {
 	}
 
 	if ckScopes != "" {
-		scopeList := strings.Split(ckScopes, ",")
-		for i, s := range scopeList {
-			scopeList[i] = strings.TrimSpace(s)
-		}
-		body["scopes"] = scopeList
+		body["scopes"] = splitScopes(ckScopes)
 	}
 
 	if ckRateLimit > 0 {
@@ -240,3 +340,194 @@ func runClientKeysDelete(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Client key '%s' deleted.\n", id)
 	return nil
 }
+
+func runClientKeysBootstrapCreate(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	body := map[string]interface{}{
+		"name":   ckName,
+		"scopes": splitScopes(ckScopes),
+	}
+
+	if ckBootstrapDescription != "" {
+		body["description"] = ckBootstrapDescription
+	}
+
+	if ckRateLimit > 0 {
+		body["rate_limit_per_minute"] = ckRateLimit
+	}
+
+	if ckExpires != "" {
+		d, err := parseDuration(ckExpires)
+		if err != nil {
+			return err
+		}
+		body["expires_in"] = int(d.Seconds())
+	}
+
+	if ckBootstrapKeyExpires != "" {
+		d, err := parseDuration(ckBootstrapKeyExpires)
+		if err != nil {
+			return err
+		}
+		body["key_expires_in"] = int(d.Seconds())
+	}
+
+	var result map[string]interface{}
+	if err := apiClient.DoPost(ctx, "/api/v1/client-keys/bootstrap-tokens", body, &result); err != nil {
+		return err
+	}
+
+	fmt.Printf("Bootstrap token created:\n")
+	fmt.Printf("  ID: %s\n", getStringValue(result, "id"))
+	fmt.Printf("  Token: %s\n", getStringValue(result, "token"))
+	fmt.Printf("  Expires: %s\n", getStringValue(result, "expires_at"))
+	fmt.Println()
+	fmt.Println("IMPORTANT: Save this token now. You won't be able to see it again!")
+	fmt.Println("It can be exchanged for a client key once, with 'fluxbase clientkeys enroll'.")
+
+	return nil
+}
+
+func runClientKeysBootstrapList(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var tokens []map[string]interface{}
+	if err := apiClient.DoGet(ctx, "/api/v1/client-keys/bootstrap-tokens", nil, &tokens); err != nil {
+		return err
+	}
+
+	if len(tokens) == 0 {
+		fmt.Println("No bootstrap tokens found.")
+		return nil
+	}
+
+	formatter := GetFormatter()
+
+	if formatter.Format == output.FormatTable {
+		data := output.TableData{
+			Headers: []string{"ID", "NAME", "PREFIX", "STATUS", "EXPIRES", "CLIENT KEY"},
+			Rows:    make([][]string, len(tokens)),
+		}
+
+		for i, token := range tokens {
+			data.Rows[i] = []string{
+				getStringValue(token, "id"),
+				getStringValue(token, "name"),
+				getStringValue(token, "token_prefix"),
+				getStringValue(token, "status"),
+				getStringValue(token, "expires_at"),
+				getStringValue(token, "client_key_id"),
+			}
+		}
+
+		formatter.PrintTable(data)
+	} else {
+		if err := formatter.Print(tokens); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func runClientKeysBootstrapRevoke(cmd *cobra.Command, args []string) error {
+	id := args[0]
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := apiClient.DoPost(ctx, "/api/v1/client-keys/bootstrap-tokens/"+url.PathEscape(id)+"/revoke", nil, nil); err != nil {
+		return err
+	}
+
+	fmt.Printf("Bootstrap token '%s' revoked.\n", id)
+	return nil
+}
+
+func runClientKeysEnroll(cmd *cobra.Command, args []string) error {
+	server := ckEnrollServer
+	if server == "" {
+		server = viper.GetString("server")
+	}
+	if server == "" {
+		return fmt.Errorf("server URL is required (use --server or FLUXBASE_SERVER)")
+	}
+	server = strings.TrimSuffix(strings.TrimSpace(server), "/")
+
+	token := ckEnrollToken
+	if token == "" {
+		token = os.Getenv("FLUXBASE_BOOTSTRAP_TOKEN")
+	}
+	if token == "" {
+		return fmt.Errorf("bootstrap token is required (use --token or FLUXBASE_BOOTSTRAP_TOKEN)")
+	}
+
+	body := map[string]interface{}{
+		"token": token,
+	}
+	if ckEnrollName != "" {
+		body["name"] = ckEnrollName
+	}
+	if ckScopes != "" {
+		body["scopes"] = splitScopes(ckScopes)
+	}
+
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server+"/api/v1/client-keys/enroll", bytes.NewReader(jsonBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "fluxbase-cli/1.0")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusCreated {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&errResp); err == nil && errResp.Error != "" {
+			return fmt.Errorf("enrollment failed: %s", errResp.Error)
+		}
+		return fmt.Errorf("enrollment failed: server returned status %d", resp.StatusCode)
+	}
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	formatter := GetFormatter()
+	if formatter.Format != output.FormatTable {
+		return formatter.Print(result)
+	}
+
+	if formatter.Quiet {
+		fmt.Println(getStringValue(result, "key"))
+		return nil
+	}
+
+	fmt.Printf("Client key enrolled:\n")
+	fmt.Printf("  ID: %s\n", getStringValue(result, "id"))
+	fmt.Printf("  Name: %s\n", getStringValue(result, "name"))
+	fmt.Printf("  Key: %s\n", getStringValue(result, "key"))
+	fmt.Println()
+	fmt.Println("IMPORTANT: Save this key now. You won't be able to see it again!")
+
+	return nil
+}
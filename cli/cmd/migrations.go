@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -12,7 +14,9 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/nimbleflux/fluxbase/cli/client"
 	"github.com/nimbleflux/fluxbase/cli/output"
+	"github.com/nimbleflux/fluxbase/cli/util"
 )
 
 var migrationsCmd = &cobra.Command{
@@ -29,6 +33,8 @@ var (
 	migSyncDir   string
 	migNoApply   bool
 	migDryRun    bool
+
+	migAllowDestructive bool
 )
 
 var migrationsListCmd = &cobra.Command{
@@ -72,8 +78,13 @@ var migrationsApplyCmd = &cobra.Command{
 	Short: "Apply a migration",
 	Long: `Apply a specific migration.
 
+Migrations that drop tables or columns, truncate tables or narrow column types
+are destructive. The affected tables are backed up before they run, and the
+changes must be confirmed interactively or with --allow-destructive.
+
 Examples:
-  fluxbase migrations apply 001_create_users`,
+  fluxbase migrations apply 001_create_users
+  fluxbase migrations apply 004_drop_legacy_tables --allow-destructive`,
 	Args:    cobra.ExactArgs(1),
 	PreRunE: requireAuth,
 	RunE:    runMigrationsApply,
@@ -127,11 +138,16 @@ func init() {
 	migrationsCreateCmd.Flags().StringVar(&migDownSQL, "down-sql", "", "Down migration SQL")
 	migrationsCreateCmd.Flags().StringVar(&migNamespace, "namespace", "default", "Migration namespace")
 
+	// Apply flags
+	migrationsApplyCmd.Flags().BoolVar(&migAllowDestructive, "allow-destructive", false, "Apply destructive changes without confirmation")
+	migrationsApplyPendingCmd.Flags().BoolVar(&migAllowDestructive, "allow-destructive", false, "Apply destructive changes without confirmation")
+
 	// Sync flags
 	migrationsSyncCmd.Flags().StringVar(&migSyncDir, "dir", "./migrations", "Directory containing migration files")
 	migrationsSyncCmd.Flags().StringVar(&migNamespace, "namespace", "default", "Target namespace")
 	migrationsSyncCmd.Flags().BoolVar(&migNoApply, "no-apply", false, "Do not apply migrations after sync")
 	migrationsSyncCmd.Flags().BoolVar(&migDryRun, "dry-run", false, "Preview changes without applying")
+	migrationsSyncCmd.Flags().BoolVar(&migAllowDestructive, "allow-destructive", false, "Apply destructive changes without confirmation")
 
 	migrationsCmd.AddCommand(migrationsListCmd)
 	migrationsCmd.AddCommand(migrationsGetCmd)
@@ -233,17 +249,91 @@ func runMigrationsCreate(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// destructiveMigrationResponse is returned with 409 when a migration has unconfirmed destructive changes
+type destructiveMigrationResponse struct {
+	Migration string `json:"migration"`
+	Changes   []struct {
+		Kind   string `json:"kind"`
+		Schema string `json:"schema"`
+		Table  string `json:"table"`
+		Column string `json:"column"`
+		Detail string `json:"detail"`
+	} `json:"changes"`
+	ConfirmationToken string `json:"confirmation_token"`
+}
+
+func (r *destructiveMigrationResponse) print() {
+	fmt.Printf("Migration '%s' contains destructive changes:\n", r.Migration)
+	for _, change := range r.Changes {
+		target := change.Table
+		if change.Schema != "" {
+			target = change.Schema + "." + target
+		}
+		if change.Column != "" {
+			target += "." + change.Column
+		}
+		line := "  - " + strings.TrimSpace(change.Kind+" "+target)
+		if change.Detail != "" {
+			line += " (" + change.Detail + ")"
+		}
+		fmt.Println(line)
+	}
+}
+
 func runMigrationsApply(cmd *cobra.Command, args []string) error {
 	name := args[0]
 
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
 
-	if err := apiClient.DoPost(ctx, "/api/v1/admin/migrations/"+url.PathEscape(name)+"/apply", nil, nil); err != nil {
+	path := "/api/v1/admin/migrations/" + url.PathEscape(name) + "/apply"
+	body := map[string]interface{}{"allow_destructive": migAllowDestructive}
+
+	resp, err := apiClient.Post(ctx, path, body)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusConflict {
+		var conflict destructiveMigrationResponse
+		if err := json.NewDecoder(resp.Body).Decode(&conflict); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		conflict.print()
+
+		if !util.IsInteractive() {
+			return fmt.Errorf("destructive changes not confirmed; re-run with --allow-destructive to apply them")
+		}
+		fmt.Println("The affected tables will be backed up before the migration runs.")
+		fmt.Printf("Type 'yes' to apply: ")
+
+		var confirm string
+		_, _ = fmt.Scanln(&confirm)
+		if strings.ToLower(confirm) != "yes" {
+			fmt.Println("Migration not applied")
+			return nil
+		}
+
+		body["confirmation_token"] = conflict.ConfirmationToken
+		resp, err = apiClient.Post(ctx, path, body)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = resp.Body.Close() }()
+	}
+
+	var result struct {
+		Backups []string `json:"backups"`
+	}
+	if err := client.DecodeResponse(resp, &result); err != nil {
 		return err
 	}
 
 	fmt.Printf("Migration '%s' applied successfully.\n", name)
+	for _, backup := range result.Backups {
+		fmt.Printf("  Backup: %s\n", backup)
+	}
 	return nil
 }
 
@@ -266,7 +356,8 @@ func runMigrationsApplyPending(cmd *cobra.Command, args []string) error {
 	defer cancel()
 
 	var result map[string]interface{}
-	if err := apiClient.DoPost(ctx, "/api/v1/admin/migrations/apply-pending", nil, &result); err != nil {
+	body := map[string]interface{}{"allow_destructive": migAllowDestructive}
+	if err := apiClient.DoPost(ctx, "/api/v1/admin/migrations/apply-pending", body, &result); err != nil {
 		return err
 	}
 
@@ -371,7 +462,8 @@ func runMigrationsSync(cmd *cobra.Command, args []string) error {
 		"namespace":  migNamespace,
 		"migrations": migList,
 		"options": map[string]interface{}{
-			"auto_apply": !migNoApply,
+			"auto_apply":        !migNoApply,
+			"allow_destructive": migAllowDestructive,
 		},
 	}
 
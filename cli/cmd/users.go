@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,7 +19,7 @@ var usersCmd = &cobra.Command{
 	Short:   "Manage application users",
 	Long: `Manage application users (end users of your application).
 
-Use subcommands to list, view, invite, and delete app users.
+Use subcommands to list, view, invite, ban, and delete app users.
 For admin/dashboard users, use the 'fluxbase admin users' command instead.`,
 }
 
@@ -30,12 +31,14 @@ var (
 var usersListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all app users",
-	Long: `List all application users.
+	Long: `List all application users, newest first.
 
 Examples:
   fluxbase users list
   fluxbase users list -o json
-  fluxbase users list --search john`,
+  fluxbase users list --search john
+  fluxbase users list --metadata '{"plan":"pro"}' --banned
+  fluxbase users list --limit 50 --offset 50`,
 	PreRunE: requireAuth,
 	RunE:    runUsersList,
 }
@@ -81,11 +84,98 @@ Examples:
 	RunE:    runUsersDelete,
 }
 
-var usersSearchQuery string
+var usersDuplicatesCmd = &cobra.Command{
+	Use:   "duplicates",
+	Short: "List app users with aliased email addresses",
+	Long: `List groups of application users whose email addresses are aliases of each other.
+
+Gmail ignores dots in addresses, and many providers ignore a "+tag" suffix, so
+jane.doe+promo@gmail.com and janedoe@gmail.com reach the same mailbox. With email
+normalization enabled, new aliases are rejected at signup; this command finds
+accounts created before that, so they can be merged or removed.
+
+Examples:
+  fluxbase users duplicates
+  fluxbase users duplicates -o json`,
+	PreRunE: requireAuth,
+	RunE:    runUsersDuplicates,
+}
+
+var usersBanCmd = &cobra.Command{
+	Use:   "ban [id]",
+	Short: "Ban an app user",
+	Long: `Ban an application user and sign them out of all sessions.
+
+Without --duration the ban lasts until the user is unbanned.
+
+Examples:
+  fluxbase users ban 550e8400-e29b-41d4-a716-446655440000 --reason spam
+  fluxbase users ban 550e8400-e29b-41d4-a716-446655440000 --duration 72h`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: requireAuth,
+	RunE:    runUsersBan,
+}
+
+var usersUnbanCmd = &cobra.Command{
+	Use:   "unban [id]",
+	Short: "Unban an app user",
+	Long: `Lift the ban, or the lockout after failed sign-ins, of an application user.
+
+Examples:
+  fluxbase users unban 550e8400-e29b-41d4-a716-446655440000`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: requireAuth,
+	RunE:    runUsersUnban,
+}
+
+var usersResetPasswordCmd = &cobra.Command{
+	Use:   "reset-password [id]",
+	Short: "Force an app user to reset their password",
+	Long: `Force an application user to choose a new password.
+
+Their current password stops working, they are signed out of all sessions and
+they are emailed a password reset link.
+
+Examples:
+  fluxbase users reset-password 550e8400-e29b-41d4-a716-446655440000`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: requireAuth,
+	RunE:    runUsersResetPassword,
+}
+
+var usersVerifyEmailCmd = &cobra.Command{
+	Use:   "verify-email [id]",
+	Short: "Mark an app user's email as verified",
+	Long: `Mark the email address of an application user as verified without a verification link.
+
+Examples:
+  fluxbase users verify-email 550e8400-e29b-41d4-a716-446655440000`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: requireAuth,
+	RunE:    runUsersVerifyEmail,
+}
+
+var (
+	usersSearchQuery string
+	usersMetadata    string
+	usersBanned      bool
+	usersLimit       int
+	usersOffset      int
+	usersBanDuration string
+	usersBanReason   string
+)
 
 func init() {
 	// List flags
-	usersListCmd.Flags().StringVar(&usersSearchQuery, "search", "", "Search users by email")
+	usersListCmd.Flags().StringVar(&usersSearchQuery, "search", "", "Search users by email or ID")
+	usersListCmd.Flags().StringVar(&usersMetadata, "metadata", "", "Only users whose metadata contains this JSON object")
+	usersListCmd.Flags().BoolVar(&usersBanned, "banned", false, "Only banned users")
+	usersListCmd.Flags().IntVar(&usersLimit, "limit", 100, "Maximum number of users to list")
+	usersListCmd.Flags().IntVar(&usersOffset, "offset", 0, "Number of users to skip")
+
+	// Ban flags
+	usersBanCmd.Flags().StringVar(&usersBanDuration, "duration", "", "How long the ban lasts, e.g. 24h (default: until unbanned)")
+	usersBanCmd.Flags().StringVar(&usersBanReason, "reason", "", "Reason recorded in the audit log")
 
 	// Invite flags
 	usersInviteCmd.Flags().StringVar(&appUserEmail, "email", "", "Email address to invite")
@@ -99,6 +189,11 @@ func init() {
 	usersCmd.AddCommand(usersGetCmd)
 	usersCmd.AddCommand(usersInviteCmd)
 	usersCmd.AddCommand(usersDeleteCmd)
+	usersCmd.AddCommand(usersDuplicatesCmd)
+	usersCmd.AddCommand(usersBanCmd)
+	usersCmd.AddCommand(usersUnbanCmd)
+	usersCmd.AddCommand(usersResetPasswordCmd)
+	usersCmd.AddCommand(usersVerifyEmailCmd)
 }
 
 // AppUser represents an application user
@@ -110,8 +205,9 @@ type AppUser struct {
 	EmailVerified  bool                   `json:"email_verified"`
 	PhoneVerified  bool                   `json:"phone_verified"`
 	IsActive       bool                   `json:"is_active"`
-	IsBanned       bool                   `json:"is_banned"`
-	LastSignInAt   *time.Time             `json:"last_sign_in_at"`
+	IsBanned       bool                   `json:"is_locked"`
+	BannedUntil    *time.Time             `json:"locked_until"`
+	LastSignInAt   *time.Time             `json:"last_sign_in"`
 	ActiveSessions int                    `json:"active_sessions"`
 	UserMetadata   map[string]interface{} `json:"user_metadata"`
 	CreatedAt      time.Time              `json:"created_at"`
@@ -124,10 +220,17 @@ func runUsersList(cmd *cobra.Command, args []string) error {
 
 	query := url.Values{}
 	query.Set("type", "app")
-	query.Set("limit", "100")
+	query.Set("limit", strconv.Itoa(usersLimit))
+	query.Set("offset", strconv.Itoa(usersOffset))
 	if usersSearchQuery != "" {
 		query.Set("search", usersSearchQuery)
 	}
+	if usersMetadata != "" {
+		query.Set("metadata", usersMetadata)
+	}
+	if usersBanned {
+		query.Set("banned", "true")
+	}
 
 	var result struct {
 		Users []*AppUser `json:"users"`
@@ -187,6 +290,65 @@ func runUsersList(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// DuplicateEmailGroup is a set of app users whose addresses normalize to the same email
+type DuplicateEmailGroup struct {
+	NormalizedEmail string `json:"normalized_email"`
+	Users           []struct {
+		ID            string    `json:"id"`
+		Email         string    `json:"email"`
+		EmailVerified bool      `json:"email_verified"`
+		CreatedAt     time.Time `json:"created_at"`
+	} `json:"users"`
+}
+
+func runUsersDuplicates(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	var result struct {
+		Groups []*DuplicateEmailGroup `json:"groups"`
+		Total  int                    `json:"total"`
+	}
+
+	if err := apiClient.DoGet(ctx, "/api/v1/admin/users/duplicate-emails", nil, &result); err != nil {
+		return err
+	}
+
+	if formatter.Format != output.FormatTable {
+		return formatter.Print(result.Groups)
+	}
+
+	if len(result.Groups) == 0 {
+		fmt.Println("No duplicate email addresses found")
+		return nil
+	}
+
+	data := output.TableData{
+		Headers: []string{"NORMALIZED EMAIL", "ID", "EMAIL", "VERIFIED", "CREATED"},
+	}
+
+	for _, group := range result.Groups {
+		for _, user := range group.Users {
+			verified := "no"
+			if user.EmailVerified {
+				verified = "yes"
+			}
+			data.Rows = append(data.Rows, []string{
+				group.NormalizedEmail,
+				user.ID,
+				user.Email,
+				verified,
+				formatTime(user.CreatedAt),
+			})
+		}
+	}
+
+	formatter.PrintTable(data)
+	fmt.Printf("\nTotal: %d groups\n", result.Total)
+
+	return nil
+}
+
 func runUsersGet(cmd *cobra.Command, args []string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -214,6 +376,9 @@ func runUsersGet(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Phone Verified:  %v\n", user.PhoneVerified)
 	fmt.Printf("Active:          %v\n", user.IsActive)
 	fmt.Printf("Banned:          %v\n", user.IsBanned)
+	if user.BannedUntil != nil {
+		fmt.Printf("Banned Until:    %s\n", formatTime(*user.BannedUntil))
+	}
 	fmt.Printf("Active Sessions: %d\n", user.ActiveSessions)
 	if user.LastSignInAt != nil {
 		fmt.Printf("Last Sign In:    %s\n", formatTime(*user.LastSignInAt))
@@ -279,3 +444,75 @@ func runUsersDelete(cmd *cobra.Command, args []string) error {
 	fmt.Printf("User '%s' deleted successfully\n", userID)
 	return nil
 }
+
+func runUsersBan(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	userID := args[0]
+	body := map[string]any{}
+	if usersBanDuration != "" {
+		body["duration"] = usersBanDuration
+	}
+	if usersBanReason != "" {
+		body["reason"] = usersBanReason
+	}
+
+	var result struct {
+		BannedUntil *time.Time `json:"banned_until"`
+	}
+	if err := apiClient.DoPost(ctx, "/api/v1/admin/users/"+url.PathEscape(userID)+"/ban?type=app", body, &result); err != nil {
+		return err
+	}
+
+	if result.BannedUntil != nil {
+		fmt.Printf("User '%s' banned until %s\n", userID, formatTime(*result.BannedUntil))
+	} else {
+		fmt.Printf("User '%s' banned until unbanned\n", userID)
+	}
+	return nil
+}
+
+func runUsersUnban(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	userID := args[0]
+	if err := apiClient.DoPost(ctx, "/api/v1/admin/users/"+url.PathEscape(userID)+"/unban?type=app", nil, nil); err != nil {
+		return err
+	}
+
+	fmt.Printf("User '%s' unbanned\n", userID)
+	return nil
+}
+
+func runUsersResetPassword(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	userID := args[0]
+	var result struct {
+		Message   string `json:"message"`
+		EmailSent bool   `json:"email_sent"`
+	}
+	if err := apiClient.DoPost(ctx, "/api/v1/admin/users/"+url.PathEscape(userID)+"/force-password-reset?type=app", nil, &result); err != nil {
+		return err
+	}
+
+	fmt.Println(result.Message)
+	return nil
+}
+
+func runUsersVerifyEmail(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	userID := args[0]
+	var user AppUser
+	if err := apiClient.DoPost(ctx, "/api/v1/admin/users/"+url.PathEscape(userID)+"/verify-email?type=app", nil, &user); err != nil {
+		return err
+	}
+
+	fmt.Printf("Email '%s' of user '%s' verified\n", user.Email, userID)
+	return nil
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/nimbleflux/fluxbase/internal/auth"
 	"github.com/nimbleflux/fluxbase/internal/config"
 	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/nimbleflux/fluxbase/internal/preflight"
 	"github.com/nimbleflux/fluxbase/internal/storage"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -135,6 +136,18 @@ func main() {
 		}
 		log.Info().Msg("Database connection test successful")
 
+		if cfg.Preflight.Enabled {
+			report := runPreflight(preflight.NewRunner(cfg, db), preflight.StageBeforeMigrations)
+			if err := report.Err(); err != nil {
+				db.Close()
+				if cleanupVips != nil {
+					cleanupVips()
+				}
+				log.Error().Err(err).Msg("Preflight checks failed")
+				os.Exit(1)
+			}
+		}
+
 		log.Info().Msg("All validation checks passed")
 		if cleanupVips != nil {
 			cleanupVips()
@@ -159,6 +172,23 @@ func main() {
 		db.Close()
 	}()
 
+	// Check the environment before migrating, so problems are reported with a fix instead
+	// of failing inside a migration or at the first request
+	var preflightRunner *preflight.Runner
+	var preflightReport *preflight.Report
+	if cfg.Preflight.Enabled {
+		preflightRunner = preflight.NewRunner(cfg, db)
+		preflightReport = runPreflight(preflightRunner, preflight.StageBeforeMigrations)
+		if err := preflightReport.Err(); err != nil {
+			if cleanupVips != nil {
+				cleanupVips()
+			}
+			db.Close() // Explicitly close since defer won't run with os.Exit
+			log.Error().Err(err).Msg("Preflight checks failed")
+			os.Exit(1)
+		}
+	}
+
 	// Run migrations
 	log.Info().Msg("Running database migrations...")
 	if err := db.Migrate(); err != nil {
@@ -180,8 +210,36 @@ func main() {
 		log.Warn().Err(err).Msg("Failed to recreate connection pool, continuing with existing pool")
 	}
 
+	// Check the migrated database
+	if preflightRunner != nil {
+		report := runPreflight(preflightRunner, preflight.StageAfterMigrations)
+		if err := report.Err(); err != nil {
+			if cleanupVips != nil {
+				cleanupVips()
+			}
+			db.Close() // Explicitly close since defer won't run with os.Exit
+			log.Error().Err(err).Msg("Preflight checks failed")
+			os.Exit(1)
+		}
+		preflightReport = preflight.Merge(preflightReport, report)
+	}
+
+	// Use the public base URL stored by the instance bootstrap unless one is configured
+	if cfg.PublicBaseURL == "" {
+		publicBaseURL, err := auth.NewSystemSettingsService(db).GetPublicBaseURL(context.Background())
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to load public base URL from settings")
+		} else if publicBaseURL != "" {
+			cfg.PublicBaseURL = publicBaseURL
+			log.Info().Str("public_url", publicBaseURL).Msg("Using public base URL from settings")
+		}
+	}
+
 	// Initialize API server
 	server := api.NewServer(cfg, db, Version)
+	if preflightReport != nil {
+		server.SetPreflightReport(preflightReport)
+	}
 
 	// Generate and set service role and anon keys for edge functions
 	// These are JWT tokens that edge functions can use to call the Fluxbase API
@@ -382,6 +440,30 @@ func validateStorageHealth(server *api.Server) error {
 	return nil
 }
 
+// runPreflight runs the preflight checks of stages and logs each result
+func runPreflight(runner *preflight.Runner, stages preflight.Stage) *preflight.Report {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	report := runner.Run(ctx, stages)
+	for _, check := range report.Checks {
+		var event *zerolog.Event
+		switch check.Status {
+		case preflight.StatusFail:
+			event = log.Error()
+		case preflight.StatusWarn:
+			event = log.Warn()
+		default:
+			event = log.Info()
+		}
+		if check.Hint != "" && check.Status != preflight.StatusPass {
+			event = event.Str("hint", check.Hint)
+		}
+		event.Str("check", check.Name).Str("status", string(check.Status)).Msg(check.Message)
+	}
+	return report
+}
+
 // printConfigSummary logs a summary of the current configuration
 func printConfigSummary(cfg *config.Config) {
 	log.Info().Msg("Configuration Summary:")
@@ -261,6 +261,8 @@ export default defineConfig({
             { label: "Realtime", link: "/guides/realtime/" },
             { label: "Edge Functions", link: "/guides/edge-functions/" },
             { label: "Background Jobs", link: "/guides/jobs/" },
+            { label: "Scheduled Jobs", link: "/guides/scheduled-jobs/" },
+            { label: "Message Queues", link: "/guides/queues/" },
             { label: "RPC", link: "/guides/rpc/" },
 
             // Database
@@ -275,6 +277,7 @@ export default defineConfig({
             // Advanced Auth
             { label: "OAuth Providers", link: "/guides/oauth-providers/" },
             { label: "SAML SSO", link: "/guides/saml-sso/" },
+            { label: "SCIM Provisioning", link: "/guides/scim-provisioning/" },
             { label: "Captcha", link: "/guides/captcha/" },
 
             // AI Features
@@ -390,6 +393,7 @@ export default defineConfig({
               autogenerate: { directory: "api/sdk-react" },
             },
             { label: "HTTP API", link: "/api/http/" },
+            { label: "Go Client", link: "/api/go-client/" },
           ],
         },
         {
@@ -23,8 +23,8 @@ type Config struct {
 
 // Handler serves the embedded admin UI
 type Handler struct {
-	config       Config
-	configScript []byte // Cached config script to inject into index.html
+	config     Config
+	configJSON []byte // Cached runtime config to inject into index.html
 }
 
 // New creates a new admin UI handler with runtime configuration
@@ -33,16 +33,25 @@ func New(publicBaseURL string) *Handler {
 		PublicBaseURL: publicBaseURL,
 	}
 
-	// Pre-compute the config script to inject
+	// Pre-compute the config to inject
 	configJSON, _ := json.Marshal(cfg)
-	configScript := fmt.Sprintf(`<script>window.__FLUXBASE_CONFIG__ = %s;</script>`, configJSON)
 
 	return &Handler{
-		config:       cfg,
-		configScript: []byte(configScript),
+		config:     cfg,
+		configJSON: configJSON,
 	}
 }
 
+// configScript returns the runtime config script tag. When the security headers
+// middleware issued a CSP nonce for the request, the script carries it so the
+// dashboard works without 'unsafe-inline'.
+func (h *Handler) configScript(nonce string) []byte {
+	if nonce != "" {
+		return []byte(fmt.Sprintf(`<script nonce="%s">window.__FLUXBASE_CONFIG__ = %s;</script>`, nonce, h.configJSON))
+	}
+	return []byte(fmt.Sprintf(`<script>window.__FLUXBASE_CONFIG__ = %s;</script>`, h.configJSON))
+}
+
 // RegisterRoutes registers admin UI routes
 func (h *Handler) RegisterRoutes(app *fiber.App) {
 	// Get the dist subdirectory from the embedded filesystem
@@ -101,21 +110,23 @@ func (h *Handler) RegisterRoutes(app *fiber.App) {
 		}
 
 		// Inject runtime config script before </head>
-		content = h.injectConfig(content)
+		nonce, _ := c.Locals("csp_nonce").(string)
+		content = h.injectConfig(content, nonce)
 
 		return c.Send(content)
 	})
 }
 
 // injectConfig injects the runtime config script into the HTML content before </head>
-func (h *Handler) injectConfig(content []byte) []byte {
+func (h *Handler) injectConfig(content []byte, nonce string) []byte {
 	// Find </head> and inject the config script before it
 	headClose := []byte("</head>")
 	if idx := bytes.Index(content, headClose); idx != -1 {
 		// Build new content with injected script
-		result := make([]byte, 0, len(content)+len(h.configScript)+1)
+		script := h.configScript(nonce)
+		result := make([]byte, 0, len(content)+len(script)+1)
 		result = append(result, content[:idx]...)
-		result = append(result, h.configScript...)
+		result = append(result, script...)
 		result = append(result, '\n')
 		result = append(result, content[idx:]...)
 		return result
@@ -13,7 +13,7 @@ func TestNew(t *testing.T) {
 		handler := New("http://localhost:8080")
 		assert.NotNil(t, handler)
 		assert.Equal(t, "http://localhost:8080", handler.config.PublicBaseURL)
-		assert.NotEmpty(t, handler.configScript)
+		assert.NotEmpty(t, handler.configJSON)
 	})
 
 	t.Run("creates handler with empty URL", func(t *testing.T) {
@@ -24,7 +24,7 @@ func TestNew(t *testing.T) {
 
 	t.Run("config script contains public URL", func(t *testing.T) {
 		handler := New("https://api.example.com")
-		scriptStr := string(handler.configScript)
+		scriptStr := string(handler.configScript(""))
 		assert.Contains(t, scriptStr, "__FLUXBASE_CONFIG__")
 		assert.Contains(t, scriptStr, "https://api.example.com")
 	})
@@ -57,7 +57,7 @@ func TestHandler_InjectConfig(t *testing.T) {
 	t.Run("injects config before </head>", func(t *testing.T) {
 		html := []byte(`<!DOCTYPE html><html><head><title>Test</title></head><body></body></html>`)
 
-		result := handler.injectConfig(html)
+		result := handler.injectConfig(html, "")
 
 		resultStr := string(result)
 		assert.Contains(t, resultStr, "__FLUXBASE_CONFIG__")
@@ -71,7 +71,7 @@ func TestHandler_InjectConfig(t *testing.T) {
 	t.Run("handles missing </head>", func(t *testing.T) {
 		html := []byte(`<html><body>No head tag</body></html>`)
 
-		result := handler.injectConfig(html)
+		result := handler.injectConfig(html, "")
 
 		// Should return content unchanged
 		assert.Equal(t, html, result)
@@ -80,15 +80,23 @@ func TestHandler_InjectConfig(t *testing.T) {
 	t.Run("handles empty content", func(t *testing.T) {
 		html := []byte(``)
 
-		result := handler.injectConfig(html)
+		result := handler.injectConfig(html, "")
 
 		assert.Empty(t, result)
 	})
 
+	t.Run("adds CSP nonce to script", func(t *testing.T) {
+		html := []byte(`<head></head><body></body>`)
+
+		result := handler.injectConfig(html, "abc123")
+
+		assert.Contains(t, string(result), `<script nonce="abc123">window.__FLUXBASE_CONFIG__`)
+	})
+
 	t.Run("preserves content after </head>", func(t *testing.T) {
 		html := []byte(`<head></head><body><p>Content</p></body>`)
 
-		result := handler.injectConfig(html)
+		result := handler.injectConfig(html, "")
 
 		resultStr := string(result)
 		assert.Contains(t, resultStr, "<body><p>Content</p></body>")
@@ -35,6 +35,10 @@ type ChatHandler struct {
 	providersMu    sync.RWMutex
 	// MCP integration
 	mcpExecutor *MCPToolExecutor
+	// Human escalation notifications (optional)
+	escalationNotifier *EscalationNotifier
+	// Connections attached to each conversation, for multi-device fan-out
+	rooms *conversationRooms
 }
 
 // NewChatHandler creates a new chat handler
@@ -55,6 +59,7 @@ func NewChatHandler(
 		knowledgeGraph := NewKnowledgeGraph(kbStorage)
 		entityExtractor := NewRuleBasedExtractor()
 		ragService = NewRAGService(kbStorage, embeddingService, knowledgeGraph, entityExtractor)
+		ragService.SetMetrics(metrics)
 	}
 
 	return &ChatHandler{
@@ -68,6 +73,7 @@ func NewChatHandler(
 		metrics:        metrics,
 		config:         cfg,
 		providers:      make(map[string]Provider),
+		rooms:          newConversationRooms(),
 	}
 }
 
@@ -88,6 +94,20 @@ func (h *ChatHandler) SetMCPResources(resources MCPResourceReader) {
 	h.schemaBuilder.SetMCPResources(resources)
 }
 
+// SetEscalationNotifier sets the notifier used to hand conversations off to humans
+func (h *ChatHandler) SetEscalationNotifier(notifier *EscalationNotifier) {
+	h.escalationNotifier = notifier
+}
+
+// SetKnowledgeBaseCache sets the cache for the knowledge base config and chatbot links read on
+// every chat turn. It should be shared with the storage used by the knowledge base admin endpoints
+// so that their mutations invalidate it.
+func (h *ChatHandler) SetKnowledgeBaseCache(cache *KnowledgeBaseCache) {
+	if h.ragService != nil {
+		h.ragService.storage.SetCache(cache)
+	}
+}
+
 // GetRAGService returns the RAG service (may be nil if not initialized)
 func (h *ChatHandler) GetRAGService() *RAGService {
 	return h.ragService
@@ -141,7 +161,13 @@ type ClientMessage struct {
 	Namespace         string `json:"namespace,omitempty"`
 	ConversationID    string `json:"conversation_id,omitempty"`
 	Content           string `json:"content,omitempty"`
+	ClientMessageID   string `json:"client_message_id,omitempty"`   // Echoed in the delivered receipt
+	Typing            *bool  `json:"typing,omitempty"`              // For "typing" messages; defaults to true
+	Sequence          int    `json:"sequence,omitempty"`            // For "read" messages: last message read
 	ImpersonateUserID string `json:"impersonate_user_id,omitempty"` // Admin-only: test as this user
+	// For "start_chat": metadata and tags of a new conversation
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
 }
 
 // ServerMessage represents a message to the client
@@ -160,6 +186,16 @@ type ServerMessage struct {
 	Usage          *UsageStats      `json:"usage,omitempty"`
 	Error          string           `json:"error,omitempty"`
 	Code           string           `json:"code,omitempty"`
+	EscalationID   string           `json:"escalation_id,omitempty"`
+	// Realtime conversation state
+	Seq             int64  `json:"seq,omitempty"`      // Per-conversation event order
+	Sequence        int    `json:"sequence,omitempty"` // Message position in the conversation
+	ReadSequence    int    `json:"read_sequence,omitempty"`
+	ClientMessageID string `json:"client_message_id,omitempty"`
+	Role            string `json:"role,omitempty"`
+	Content         string `json:"content,omitempty"`
+	Typing          *bool  `json:"typing,omitempty"`
+	Status          string `json:"status,omitempty"`
 }
 
 // ChatContext holds the context for a chat session
@@ -173,6 +209,10 @@ type ChatContext struct {
 	Conversations map[string]*ConversationState
 	ActiveChatbot *Chatbot
 	Cancel        context.CancelFunc
+	// sink receives server messages instead of Conn when set (used by the HTTP widget API)
+	sink func(ServerMessage)
+	// writeMu serializes writes, since other connections of the user broadcast to this one
+	writeMu sync.Mutex
 }
 
 // HandleWebSocket handles a WebSocket chat connection upgrade
@@ -219,6 +259,7 @@ func (h *ChatHandler) handleConnection(c *websocket.Conn) {
 
 	defer func() {
 		// Cleanup
+		h.leaveConversations(chatCtx)
 		if h.metrics != nil {
 			h.metrics.UpdateAIWebSocketConnections(-1) // Decrement
 		}
@@ -256,6 +297,10 @@ func (h *ChatHandler) handleConnection(c *websocket.Conn) {
 			h.handleMessage(ctx, chatCtx, &msg)
 		case "cancel":
 			h.handleCancel(chatCtx, &msg)
+		case "typing":
+			h.handleTyping(chatCtx, &msg)
+		case "read":
+			h.handleRead(ctx, chatCtx, &msg)
 		default:
 			h.sendError(chatCtx, msg.ConversationID, "UNKNOWN_TYPE", "Unknown message type")
 		}
@@ -335,7 +380,13 @@ func (h *ChatHandler) handleStartChat(ctx context.Context, chatCtx *ChatContext,
 	}
 
 	if state == nil {
-		state, err = h.conversations.CreateConversation(ctx, chatbot, chatCtx.UserID, nil)
+		attrs := &ConversationAttributes{Metadata: msg.Metadata, Tags: msg.Tags}
+		if err := attrs.Normalize(); err != nil {
+			h.sendError(chatCtx, "", "INVALID_METADATA", err.Error())
+			return
+		}
+
+		state, err = h.conversations.CreateConversation(ctx, chatbot, chatCtx.UserID, nil, attrs)
 		if err != nil {
 			log.Error().Err(err).Msg("Failed to create conversation")
 			h.sendError(chatCtx, "", "CONVERSATION_ERROR", "Failed to create conversation")
@@ -345,12 +396,15 @@ func (h *ChatHandler) handleStartChat(ctx context.Context, chatCtx *ChatContext,
 
 	chatCtx.ActiveChatbot = chatbot
 	chatCtx.Conversations[state.ID] = state
+	room := h.joinConversation(ctx, chatCtx, state)
 
 	// Send confirmation
 	h.send(chatCtx, ServerMessage{
 		Type:           "chat_started",
 		ConversationID: state.ID,
 		Chatbot:        chatbot.Name,
+		Sequence:       len(h.conversations.GetMessages(state.ID)),
+		ReadSequence:   room.lastRead(),
 	})
 
 	log.Debug().
@@ -375,6 +429,13 @@ func (h *ChatHandler) handleMessage(ctx context.Context, chatCtx *ChatContext, m
 		return
 	}
 
+	// Process one turn at a time per conversation, so messages sent from several devices
+	// are answered in the order they arrive
+	if room := h.roomFor(chatCtx, msg.ConversationID); room != nil {
+		room.turnMu.Lock()
+		defer room.turnMu.Unlock()
+	}
+
 	// Resolve template variables in chatbot annotation values (e.g., http-allowed-domains)
 	if err := h.ResolveChatbotTemplates(ctx, chatbot, chatCtx.UserID); err != nil {
 		log.Warn().Err(err).Str("chatbot", chatbot.Name).Msg("Failed to resolve chatbot templates")
@@ -387,6 +448,20 @@ func (h *ChatHandler) handleMessage(ctx context.Context, chatCtx *ChatContext, m
 		return
 	}
 
+	// Human escalation: keep escalated conversations on hold and honor explicit requests for a human
+	if chatbot.HasEscalation() {
+		if h.replyIfEscalated(ctx, chatCtx, chatbot, msg) {
+			return
+		}
+		if IsHumanRequest(msg.Content) && h.escalateConversation(ctx, chatCtx, state, chatbot, msg, EscalationReasonUserRequest, nil) {
+			return
+		}
+	}
+
+	// Show the assistant as typing until the turn ends
+	h.sendTyping(chatCtx, msg.ConversationID, RoleAssistant, true)
+	defer h.sendTyping(chatCtx, msg.ConversationID, RoleAssistant, false)
+
 	// Send thinking progress
 	h.sendProgress(chatCtx, msg.ConversationID, "thinking", "Thinking...")
 
@@ -403,13 +478,40 @@ func (h *ChatHandler) handleMessage(ctx context.Context, chatCtx *ChatContext, m
 		return
 	}
 
+	// Pin the reply to the detected language of the message when the chatbot matches it
+	userLanguage := ""
+	if chatbot.MatchesUserLanguage(h.config != nil && h.config.MatchUserLanguage) {
+		userLanguage = DetectLanguage(msg.Content)
+		if userLanguage != "" {
+			systemPrompt = systemPrompt + "\n\n" + userLanguageInstruction(userLanguage)
+		}
+	}
+
 	// Retrieve RAG context if available (with user isolation)
+	var bestSimilarity *float64
+	var liveRetrieval *RetrieveContextResult
+	basePrompt := systemPrompt
 	if h.ragService != nil {
-		ragSection, err := h.ragService.BuildRAGSystemPromptSectionWithUser(ctx, chatbot.ID, msg.Content, userID)
+		ragSection, ragResult, err := h.ragService.BuildRAGSystemPromptSectionWithResult(ctx, chatbot.ID, msg.Content, userID, h.queryExpansion(ctx, chatbot))
+		if err == nil && ragResult != nil {
+			best := ragResult.BestSimilarity()
+			bestSimilarity = &best
+			liveRetrieval = ragResult
+		}
 		if err != nil {
 			log.Warn().Err(err).Str("chatbot_id", chatbot.ID).Msg("Failed to retrieve RAG context")
 			// Continue without RAG - don't fail the request
+		} else if ragResult != nil && ShouldEscalateForConfidence(chatbot, ragResult.BestSimilarity()) {
+			// Not grounded enough to answer - hand off to a human instead of guessing
+			best := ragResult.BestSimilarity()
+			if h.escalateConversation(ctx, chatCtx, state, chatbot, msg, EscalationReasonLowConfidence, &best) {
+				return
+			}
 		} else if ragSection != "" {
+			if userLanguage != "" {
+				// Label sources in other languages so they are translated, not quoted
+				ragSection = formatContextForLanguage(ragResult.Chunks, userLanguage)
+			}
 			systemPrompt = systemPrompt + "\n\n" + ragSection
 			log.Debug().
 				Str("chatbot_id", chatbot.ID).
@@ -419,13 +521,27 @@ func (h *ChatHandler) handleMessage(ctx context.Context, chatCtx *ChatContext, m
 		}
 	}
 
+	// Pick the model for this message if the chatbot routes between models
+	selection := chatbot.SelectModel(ModelRouteInput{Message: msg.Content, BestSimilarity: bestSimilarity})
+	model := ""
+	if selection != nil {
+		model = selection.Model
+		log.Info().
+			Str("chatbot", chatbot.Name).
+			Str("conversation_id", msg.ConversationID).
+			Str("model", selection.Model).
+			Str("rule", selection.Rule).
+			Msg("Routed chat message to model")
+	}
+
 	// Build messages for LLM
 	messages := []Message{
 		{Role: RoleSystem, Content: systemPrompt},
 	}
 
 	// Add conversation history
-	messages = append(messages, state.Messages...)
+	history := append([]Message(nil), state.Messages...)
+	messages = append(messages, history...)
 
 	// Add user message
 	userMsg := Message{Role: RoleUser, Content: msg.Content}
@@ -440,10 +556,12 @@ func (h *ChatHandler) handleMessage(ctx context.Context, chatCtx *ChatContext, m
 	}
 
 	// Save user message to conversation
-	_ = h.conversations.AddMessage(ctx, msg.ConversationID, userMsg, 0, 0)
+	h.storeUserMessage(ctx, chatCtx, msg, userMsg)
 
 	// Tool calling loop - continue until AI generates content without tool calls
 	var totalUsage UsageStats
+	var assistantStored *StoredMessage
+	var finalAnswer string
 	var accumulatedQueryResults []QueryResult // Accumulate query results for persistence
 	maxIterations := 5                        // Prevent infinite loops
 
@@ -511,6 +629,13 @@ func (h *ChatHandler) handleMessage(ctx context.Context, chatCtx *ChatContext, m
 			tools = append(tools, ExecuteSQLTool)
 		}
 
+		// Add the chatbot's REST tools
+		for i := range chatbot.RESTTools {
+			if !isToolForbidden(chatbot.RESTTools[i].Name) {
+				tools = append(tools, chatbot.RESTTools[i].ToolDefinition())
+			}
+		}
+
 		// Enforce ReAct pattern: require think tool before other tools
 		// If reasoning mode is react/strict and think hasn't been used yet,
 		// only allow the think tool on the first iteration
@@ -540,6 +665,7 @@ func (h *ChatHandler) handleMessage(ctx context.Context, chatCtx *ChatContext, m
 
 		// Create chat request
 		chatReq := &ChatRequest{
+			Model:       model,
 			Messages:    messages,
 			MaxTokens:   chatbot.MaxTokens,
 			Temperature: chatbot.Temperature,
@@ -590,7 +716,16 @@ func (h *ChatHandler) handleMessage(ctx context.Context, chatCtx *ChatContext, m
 		// Stream the response
 		h.sendProgress(chatCtx, msg.ConversationID, "generating", "Generating response...")
 
-		if err := provider.ChatStream(ctx, chatReq, callback); err != nil {
+		streamStart := time.Now()
+		err := provider.ChatStream(ctx, chatReq, callback)
+		if h.metrics != nil {
+			status := "success"
+			if err != nil {
+				status = "error"
+			}
+			h.metrics.RecordAIProviderRequest(string(provider.Type()), status, time.Since(streamStart))
+		}
+		if err != nil {
 			log.Error().Err(err).Msg("Chat stream error")
 			h.sendError(chatCtx, msg.ConversationID, "STREAM_ERROR", "Error generating response")
 
@@ -603,12 +738,15 @@ func (h *ChatHandler) handleMessage(ctx context.Context, chatCtx *ChatContext, m
 		// If no tool calls, we're done
 		if len(pendingToolCalls) == 0 {
 			// Save assistant message with accumulated query results
+			selection.RecordUsage(chatbot, totalUsage)
 			assistantMsg := Message{
 				Role:         RoleAssistant,
 				Content:      responseContent.String(),
 				QueryResults: accumulatedQueryResults,
+				Model:        selection,
 			}
-			_ = h.conversations.AddMessage(ctx, msg.ConversationID, assistantMsg, totalUsage.PromptTokens, totalUsage.CompletionTokens)
+			assistantStored, _ = h.conversations.AppendMessage(ctx, msg.ConversationID, assistantMsg, totalUsage.PromptTokens, totalUsage.CompletionTokens)
+			finalAnswer = assistantMsg.Content
 			break
 		}
 
@@ -719,10 +857,29 @@ func (h *ChatHandler) handleMessage(ctx context.Context, chatCtx *ChatContext, m
 	}
 
 	// Send completion
-	h.send(chatCtx, ServerMessage{
+	done := ServerMessage{
 		Type:           "done",
 		ConversationID: msg.ConversationID,
 		Usage:          &totalUsage,
+	}
+	if assistantStored != nil {
+		done.MessageID = assistantStored.ID
+		done.Sequence = assistantStored.Sequence
+	}
+	h.send(chatCtx, done)
+
+	// Compare the live retrieval against the chatbot's shadow profile, if any
+	h.maybeRunShadowRetrieval(ctx, shadowTurn{
+		chatbot:        chatbot,
+		conversationID: msg.ConversationID,
+		userID:         userID,
+		query:          msg.Content,
+		userLanguage:   userLanguage,
+		basePrompt:     basePrompt,
+		history:        history,
+		model:          model,
+		live:           liveRetrieval,
+		liveAnswer:     finalAnswer,
 	})
 
 	// Record metrics
@@ -744,6 +901,11 @@ func (h *ChatHandler) handleMessage(ctx context.Context, chatCtx *ChatContext, m
 func (h *ChatHandler) executeToolCall(ctx context.Context, chatCtx *ChatContext, conversationID string, chatbot *Chatbot, toolCall *ToolCall, userID string, userMessage string) (string, *QueryResult) {
 	toolName := toolCall.Function.Name
 
+	// REST tools declared by the chatbot take precedence, their names can't clash with MCP tools
+	if tool := chatbot.FindRESTTool(toolName); tool != nil {
+		return h.executeRESTTool(ctx, chatCtx, conversationID, chatbot, tool, toolCall, userID)
+	}
+
 	// Check if this is an MCP tool
 	if chatbot.HasMCPTools() && h.mcpExecutor != nil && IsToolAllowed(toolName, chatbot.MCPTools) {
 		return h.executeMCPTool(ctx, chatCtx, conversationID, chatbot, toolCall)
@@ -837,38 +999,7 @@ func (h *ChatHandler) executeSQLTool(ctx context.Context, chatCtx *ChatContext,
 		return fmt.Sprintf("Error executing query: %v", err), nil
 	}
 
-	// Log to audit (unless execution logs are disabled)
-	if !chatbot.DisableExecutionLogs {
-		_ = h.auditLogger.LogFromExecuteResult(
-			ctx,
-			chatbot.ID, conversationID, "", userID,
-			args.SQL, result,
-			chatCtx.Role, chatCtx.IPAddress, chatCtx.UserAgent,
-		)
-
-		// Log to central logging service
-		if h.loggingService != nil {
-			h.loggingService.LogAI(ctx, map[string]any{
-				"tool":            "execute_sql",
-				"chatbot_id":      chatbot.ID,
-				"conversation_id": conversationID,
-				"success":         result.Success,
-				"rows_returned":   result.RowCount,
-				"tables":          result.TablesAccessed,
-				"duration_ms":     result.DurationMs,
-			}, "", userID)
-		}
-	}
-
-	// Send result to client for display
-	h.send(chatCtx, ServerMessage{
-		Type:           "query_result",
-		ConversationID: conversationID,
-		Query:          args.SQL,
-		Summary:        result.Summary,
-		RowCount:       result.RowCount,
-		Data:           result.Rows,
-	})
+	h.recordQueryExecution(ctx, chatCtx, conversationID, chatbot, userID, "execute_sql", args.SQL, result)
 
 	// Return result as string for AI to interpret
 	if !result.Success {
@@ -898,6 +1029,86 @@ func (h *ChatHandler) executeSQLTool(ctx context.Context, chatCtx *ChatContext,
 	return resultStr, queryResult
 }
 
+// executeRESTTool handles a call of one of the chatbot's REST tools. The query of the tool is
+// run with the user's RLS context and the result rows are returned to the model.
+func (h *ChatHandler) executeRESTTool(ctx context.Context, chatCtx *ChatContext, conversationID string, chatbot *Chatbot, tool *RESTTool, toolCall *ToolCall, userID string) (string, *QueryResult) {
+	sql, params, err := tool.BuildQuery(toolCall.Function.Arguments)
+	if err != nil {
+		log.Debug().Err(err).Str("tool", tool.Name).Str("args", toolCall.Function.Arguments).Msg("Invalid REST tool call")
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+
+	h.sendProgress(chatCtx, conversationID, "querying", fmt.Sprintf("Executing: %s", tool.Description))
+
+	result, err := h.executor.Execute(ctx, &ExecuteRequest{
+		ChatbotName:       chatbot.Name,
+		ChatbotID:         chatbot.ID,
+		ConversationID:    conversationID,
+		UserID:            userID,
+		Role:              chatCtx.Role,
+		Claims:            chatCtx.Claims,
+		SQL:               sql,
+		Args:              params,
+		Description:       tool.Description,
+		AllowedSchemas:    chatbot.AllowedSchemas,
+		AllowedTables:     chatbot.AllowedTables,
+		AllowedOperations: chatbot.AllowedOperations,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("tool", tool.Name).Msg("REST tool execution error")
+		return fmt.Sprintf("Error executing %s: %v", tool.Name, err), nil
+	}
+
+	h.recordQueryExecution(ctx, chatCtx, conversationID, chatbot, userID, tool.Name, sql, result)
+
+	if !result.Success {
+		return fmt.Sprintf("Query failed: %s", result.Error), nil
+	}
+
+	rows, _ := json.Marshal(result.Rows)
+	return fmt.Sprintf("%s\nRows: %s", result.Summary, rows), &QueryResult{
+		Query:    sql,
+		Summary:  result.Summary,
+		RowCount: result.RowCount,
+		Data:     result.Rows,
+	}
+}
+
+// recordQueryExecution logs a query run for a tool call (unless execution logs are disabled)
+// and sends its result to the client for display
+func (h *ChatHandler) recordQueryExecution(ctx context.Context, chatCtx *ChatContext, conversationID string, chatbot *Chatbot, userID, toolName, sql string, result *ExecuteResult) {
+	if !chatbot.DisableExecutionLogs {
+		_ = h.auditLogger.LogFromExecuteResult(
+			ctx,
+			chatbot.ID, conversationID, "", userID,
+			sql, result,
+			chatCtx.Role, chatCtx.IPAddress, chatCtx.UserAgent,
+		)
+
+		// Log to central logging service
+		if h.loggingService != nil {
+			h.loggingService.LogAI(ctx, map[string]any{
+				"tool":            toolName,
+				"chatbot_id":      chatbot.ID,
+				"conversation_id": conversationID,
+				"success":         result.Success,
+				"rows_returned":   result.RowCount,
+				"tables":          result.TablesAccessed,
+				"duration_ms":     result.DurationMs,
+			}, "", userID)
+		}
+	}
+
+	h.send(chatCtx, ServerMessage{
+		Type:           "query_result",
+		ConversationID: conversationID,
+		Query:          sql,
+		Summary:        result.Summary,
+		RowCount:       result.RowCount,
+		Data:           result.Rows,
+	})
+}
+
 // executeMCPTool handles MCP tool execution for chatbots with MCP tools configured
 func (h *ChatHandler) executeMCPTool(ctx context.Context, chatCtx *ChatContext, conversationID string, chatbot *Chatbot, toolCall *ToolCall) (string, *QueryResult) {
 	toolName := toolCall.Function.Name
@@ -1037,9 +1248,136 @@ func (h *ChatHandler) handleCancel(chatCtx *ChatContext, msg *ClientMessage) {
 	})
 }
 
+// replyIfEscalated answers with the holding message when the conversation has an
+// unresolved escalation. Returns true if the message was handled.
+func (h *ChatHandler) replyIfEscalated(ctx context.Context, chatCtx *ChatContext, chatbot *Chatbot, msg *ClientMessage) bool {
+	escalation, err := h.storage.GetOpenEscalation(ctx, msg.ConversationID)
+	if err != nil {
+		log.Warn().Err(err).Str("conversation_id", msg.ConversationID).Msg("Failed to check conversation escalation")
+		return false
+	}
+	if escalation == nil {
+		return false
+	}
+
+	h.sendHoldingMessage(ctx, chatCtx, chatbot, msg, escalation.ID)
+	return true
+}
+
+// escalateConversation records an escalation, notifies the configured webhook/email in the
+// background and switches the conversation to the holding message. Returns false if the
+// escalation could not be recorded so the caller can continue normally.
+func (h *ChatHandler) escalateConversation(ctx context.Context, chatCtx *ChatContext, state *ConversationState, chatbot *Chatbot, msg *ClientMessage, reason EscalationReason, bestSimilarity *float64) bool {
+	history := make([]Message, 0, len(state.Messages)+1)
+	history = append(history, state.Messages...)
+	history = append(history, Message{Role: RoleUser, Content: msg.Content})
+
+	escalation := &Escalation{
+		ConversationID: msg.ConversationID,
+		ChatbotID:      chatbot.ID,
+		UserID:         chatCtx.UserID,
+		Reason:         reason,
+		TriggerMessage: &msg.Content,
+		BestSimilarity: bestSimilarity,
+		Transcript:     BuildEscalationTranscript(history),
+		Status:         EscalationStatusOpen,
+	}
+	if err := h.storage.CreateEscalation(ctx, escalation); err != nil {
+		log.Error().Err(err).Str("conversation_id", msg.ConversationID).Msg("Failed to escalate conversation")
+		return false
+	}
+
+	if h.escalationNotifier != nil {
+		// Deliver notifications without blocking the chat; the connection may close first
+		notifyChatbot := *chatbot
+		go h.escalationNotifier.Notify(context.Background(), &notifyChatbot, escalation)
+	}
+
+	h.sendHoldingMessage(ctx, chatCtx, chatbot, msg, escalation.ID)
+	return true
+}
+
+// sendHoldingMessage stores the user message, replies with the chatbot's escalation
+// holding message and completes the turn without calling the AI provider
+func (h *ChatHandler) sendHoldingMessage(ctx context.Context, chatCtx *ChatContext, chatbot *Chatbot, msg *ClientMessage, escalationID string) {
+	holding := EscalationHoldingMessage(chatbot)
+
+	h.storeUserMessage(ctx, chatCtx, msg, Message{Role: RoleUser, Content: msg.Content})
+	holdingStored, _ := h.conversations.AppendMessage(ctx, msg.ConversationID, Message{Role: RoleAssistant, Content: holding}, 0, 0)
+
+	h.send(chatCtx, ServerMessage{
+		Type:           "escalated",
+		ConversationID: msg.ConversationID,
+		EscalationID:   escalationID,
+		Message:        holding,
+	})
+	h.send(chatCtx, ServerMessage{
+		Type:           "content",
+		ConversationID: msg.ConversationID,
+		Delta:          holding,
+	})
+	done := ServerMessage{
+		Type:           "done",
+		ConversationID: msg.ConversationID,
+		Usage:          &UsageStats{},
+	}
+	if holdingStored != nil {
+		done.MessageID = holdingStored.ID
+		done.Sequence = holdingStored.Sequence
+	}
+	h.send(chatCtx, done)
+}
+
+// storeUserMessage saves a user message, shows it on the user's other connections and
+// confirms delivery with the message's ID and sequence number
+func (h *ChatHandler) storeUserMessage(ctx context.Context, chatCtx *ChatContext, msg *ClientMessage, userMsg Message) {
+	stored, err := h.conversations.AppendMessage(ctx, msg.ConversationID, userMsg, 0, 0)
+	if err != nil || stored == nil {
+		return
+	}
+
+	h.sendToOthers(chatCtx, ServerMessage{
+		Type:           "user_message",
+		ConversationID: msg.ConversationID,
+		MessageID:      stored.ID,
+		Sequence:       stored.Sequence,
+		Role:           string(RoleUser),
+		Content:        userMsg.Content,
+	})
+	h.send(chatCtx, ServerMessage{
+		Type:            "receipt",
+		ConversationID:  msg.ConversationID,
+		Status:          ReceiptDelivered,
+		MessageID:       stored.ID,
+		Sequence:        stored.Sequence,
+		ClientMessageID: msg.ClientMessageID,
+	})
+}
+
 // Helper methods
 
+// send delivers a server message. Conversation events go to every connection attached to the
+// conversation in a single order; everything else only goes to chatCtx.
 func (h *ChatHandler) send(chatCtx *ChatContext, msg ServerMessage) {
+	if conversationEventTypes[msg.Type] {
+		if room := h.roomFor(chatCtx, msg.ConversationID); room != nil {
+			room.broadcast(msg, nil, h.write)
+			return
+		}
+	}
+	h.write(chatCtx, msg)
+}
+
+// write sends a server message to a single connection
+func (h *ChatHandler) write(chatCtx *ChatContext, msg ServerMessage) {
+	chatCtx.writeMu.Lock()
+	defer chatCtx.writeMu.Unlock()
+
+	if chatCtx.sink != nil {
+		chatCtx.sink(msg)
+		return
+	}
+
 	data, err := json.Marshal(msg)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to marshal server message")
@@ -0,0 +1,239 @@
+package ai
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Receipt statuses sent in "receipt" events
+const (
+	ReceiptDelivered = "delivered"
+	ReceiptRead      = "read"
+)
+
+// conversationEventTypes are server messages that every connection in a conversation receives,
+// so a user with several devices sees the same conversation state. Other messages, such as
+// errors, only go to the connection that caused them.
+var conversationEventTypes = map[string]bool{
+	"user_message": true,
+	"progress":     true,
+	"content":      true,
+	"query_result": true,
+	"tool_result":  true,
+	"done":         true,
+	"escalated":    true,
+	"typing":       true,
+	"receipt":      true,
+}
+
+// conversationRoom holds the WebSocket connections attached to one conversation
+type conversationRoom struct {
+	// turnMu serializes turns so messages sent from several devices are processed in order
+	turnMu sync.Mutex
+
+	mu           sync.Mutex
+	seq          int64 // sequence number of the last event sent to the room
+	readSequence int   // last message sequence the user has read on any device
+	members      map[*ChatContext]struct{}
+}
+
+// broadcast stamps msg with the next event sequence number and sends it to every member except skip.
+// Events are written while holding the room lock, so all members receive them in the same order.
+func (r *conversationRoom) broadcast(msg ServerMessage, skip *ChatContext, write func(*ChatContext, ServerMessage)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.seq++
+	msg.Seq = r.seq
+	for member := range r.members {
+		if member != skip {
+			write(member, msg)
+		}
+	}
+}
+
+// has reports whether chatCtx is attached to the room
+func (r *conversationRoom) has(chatCtx *ChatContext) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.members[chatCtx]
+	return ok
+}
+
+// markRead advances the read position and reports whether it moved
+func (r *conversationRoom) markRead(sequence int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if sequence <= r.readSequence {
+		return false
+	}
+	r.readSequence = sequence
+	return true
+}
+
+// lastRead returns the read position of the conversation
+func (r *conversationRoom) lastRead() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.readSequence
+}
+
+// conversationRooms tracks the rooms of conversations with connected clients on this instance
+type conversationRooms struct {
+	mu    sync.Mutex
+	rooms map[string]*conversationRoom
+}
+
+func newConversationRooms() *conversationRooms {
+	return &conversationRooms{rooms: make(map[string]*conversationRoom)}
+}
+
+// join attaches chatCtx to the conversation's room. loadReadSequence is called once when the room is created.
+func (c *conversationRooms) join(conversationID string, chatCtx *ChatContext, loadReadSequence func() int) *conversationRoom {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	room, ok := c.rooms[conversationID]
+	if !ok {
+		room = &conversationRoom{
+			readSequence: loadReadSequence(),
+			members:      make(map[*ChatContext]struct{}),
+		}
+		c.rooms[conversationID] = room
+	}
+
+	room.mu.Lock()
+	room.members[chatCtx] = struct{}{}
+	room.mu.Unlock()
+	return room
+}
+
+// leave detaches chatCtx and drops the room once it has no members
+func (c *conversationRooms) leave(conversationID string, chatCtx *ChatContext) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	room, ok := c.rooms[conversationID]
+	if !ok {
+		return
+	}
+
+	room.mu.Lock()
+	delete(room.members, chatCtx)
+	empty := len(room.members) == 0
+	room.mu.Unlock()
+
+	if empty {
+		delete(c.rooms, conversationID)
+	}
+}
+
+// get returns the room of a conversation, or nil if no client is attached
+func (c *conversationRooms) get(conversationID string) *conversationRoom {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rooms[conversationID]
+}
+
+// roomFor returns the conversation's room if chatCtx is attached to it
+func (h *ChatHandler) roomFor(chatCtx *ChatContext, conversationID string) *conversationRoom {
+	if conversationID == "" || h.rooms == nil {
+		return nil
+	}
+	room := h.rooms.get(conversationID)
+	if room == nil || !room.has(chatCtx) {
+		return nil
+	}
+	return room
+}
+
+// joinConversation attaches a connection to a conversation so it receives events from the user's other devices
+func (h *ChatHandler) joinConversation(ctx context.Context, chatCtx *ChatContext, state *ConversationState) *conversationRoom {
+	return h.rooms.join(state.ID, chatCtx, func() int {
+		if !state.PersistToDatabase || h.storage == nil {
+			return 0
+		}
+		sequence, err := h.storage.GetConversationReadSequence(ctx, state.ID)
+		if err != nil {
+			log.Warn().Err(err).Str("conversation_id", state.ID).Msg("Failed to load conversation read position")
+		}
+		return sequence
+	})
+}
+
+// leaveConversations detaches a closing connection from all its conversations
+func (h *ChatHandler) leaveConversations(chatCtx *ChatContext) {
+	for conversationID := range chatCtx.Conversations {
+		h.rooms.leave(conversationID, chatCtx)
+	}
+}
+
+// sendToOthers sends a conversation event to the user's other connections
+func (h *ChatHandler) sendToOthers(chatCtx *ChatContext, msg ServerMessage) {
+	if room := h.roomFor(chatCtx, msg.ConversationID); room != nil {
+		room.broadcast(msg, chatCtx, h.write)
+	}
+}
+
+// sendTyping tells the conversation's connections whether role is typing
+func (h *ChatHandler) sendTyping(chatCtx *ChatContext, conversationID string, role Role, typing bool) {
+	msg := ServerMessage{
+		Type:           "typing",
+		ConversationID: conversationID,
+		Role:           string(role),
+		Typing:         &typing,
+	}
+	if role == RoleUser {
+		// The typing user's own connection does not need the echo
+		h.sendToOthers(chatCtx, msg)
+		return
+	}
+	h.send(chatCtx, msg)
+}
+
+// handleTyping relays a user's typing state to their other connections
+func (h *ChatHandler) handleTyping(chatCtx *ChatContext, msg *ClientMessage) {
+	if h.roomFor(chatCtx, msg.ConversationID) == nil {
+		h.sendError(chatCtx, msg.ConversationID, "NO_SESSION", "No active chat session")
+		return
+	}
+	typing := msg.Typing == nil || *msg.Typing
+	h.sendTyping(chatCtx, msg.ConversationID, RoleUser, typing)
+}
+
+// handleRead records that the user has read the conversation up to msg.Sequence and
+// sends a read receipt to all of the user's connections
+func (h *ChatHandler) handleRead(ctx context.Context, chatCtx *ChatContext, msg *ClientMessage) {
+	room := h.roomFor(chatCtx, msg.ConversationID)
+	state := chatCtx.Conversations[msg.ConversationID]
+	if room == nil || state == nil {
+		h.sendError(chatCtx, msg.ConversationID, "NO_SESSION", "No active chat session")
+		return
+	}
+
+	messageCount := len(h.conversations.GetMessages(msg.ConversationID))
+	if msg.Sequence < 1 || msg.Sequence > messageCount {
+		h.sendError(chatCtx, msg.ConversationID, "INVALID_SEQUENCE", "Sequence does not match a message in the conversation")
+		return
+	}
+
+	// Stale or duplicate receipts from another device are ignored
+	if !room.markRead(msg.Sequence) {
+		return
+	}
+
+	if state.PersistToDatabase {
+		if err := h.storage.MarkConversationRead(ctx, msg.ConversationID, msg.Sequence); err != nil {
+			log.Warn().Err(err).Str("conversation_id", msg.ConversationID).Msg("Failed to save conversation read position")
+		}
+	}
+
+	h.send(chatCtx, ServerMessage{
+		Type:           "receipt",
+		ConversationID: msg.ConversationID,
+		Status:         ReceiptRead,
+		Sequence:       msg.Sequence,
+	})
+}
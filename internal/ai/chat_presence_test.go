@@ -0,0 +1,203 @@
+package ai
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingChatContext returns a chat context that records the messages sent to it
+func recordingChatContext() (*ChatContext, *[]ServerMessage) {
+	var received []ServerMessage
+	chatCtx := &ChatContext{
+		Conversations: make(map[string]*ConversationState),
+		sink:          func(msg ServerMessage) { received = append(received, msg) },
+	}
+	return chatCtx, &received
+}
+
+func newPresenceTestHandler(t *testing.T) (*ChatHandler, *ConversationManager) {
+	t.Helper()
+	manager := &ConversationManager{
+		cache:       make(map[string]*ConversationState),
+		cacheTTL:    30 * time.Minute,
+		maxTurns:    50,
+		cleanupDone: make(chan struct{}),
+	}
+	t.Cleanup(manager.Close)
+	return &ChatHandler{conversations: manager, rooms: newConversationRooms()}, manager
+}
+
+func TestConversationRooms(t *testing.T) {
+	t.Run("join loads the read position once and leave drops empty rooms", func(t *testing.T) {
+		rooms := newConversationRooms()
+		phone, _ := recordingChatContext()
+		laptop, _ := recordingChatContext()
+
+		loads := 0
+		load := func() int {
+			loads++
+			return 4
+		}
+
+		room := rooms.join("conv-1", phone, load)
+		assert.Same(t, room, rooms.join("conv-1", laptop, load))
+		assert.Equal(t, 1, loads)
+		assert.Equal(t, 4, room.lastRead())
+		assert.True(t, room.has(phone))
+
+		rooms.leave("conv-1", phone)
+		assert.False(t, room.has(phone))
+		assert.Same(t, room, rooms.get("conv-1"))
+
+		rooms.leave("conv-1", laptop)
+		assert.Nil(t, rooms.get("conv-1"))
+
+		// Leaving an unknown room is a no-op
+		rooms.leave("conv-2", laptop)
+	})
+
+	t.Run("broadcast numbers events and skips the sender", func(t *testing.T) {
+		rooms := newConversationRooms()
+		phone, phoneReceived := recordingChatContext()
+		laptop, laptopReceived := recordingChatContext()
+		room := rooms.join("conv-1", phone, func() int { return 0 })
+		rooms.join("conv-1", laptop, func() int { return 0 })
+
+		write := func(chatCtx *ChatContext, msg ServerMessage) { chatCtx.sink(msg) }
+		room.broadcast(ServerMessage{Type: "content"}, nil, write)
+		room.broadcast(ServerMessage{Type: "user_message"}, phone, write)
+
+		require.Len(t, *phoneReceived, 1)
+		assert.Equal(t, int64(1), (*phoneReceived)[0].Seq)
+		require.Len(t, *laptopReceived, 2)
+		assert.Equal(t, int64(1), (*laptopReceived)[0].Seq)
+		assert.Equal(t, int64(2), (*laptopReceived)[1].Seq)
+	})
+
+	t.Run("markRead only moves forward", func(t *testing.T) {
+		room := &conversationRoom{readSequence: 3, members: make(map[*ChatContext]struct{})}
+
+		assert.False(t, room.markRead(2))
+		assert.False(t, room.markRead(3))
+		assert.True(t, room.markRead(5))
+		assert.Equal(t, 5, room.lastRead())
+	})
+}
+
+func TestChatHandler_Send(t *testing.T) {
+	h, _ := newPresenceTestHandler(t)
+	phone, phoneReceived := recordingChatContext()
+	laptop, laptopReceived := recordingChatContext()
+	outsider, outsiderReceived := recordingChatContext()
+	h.rooms.join("conv-1", phone, func() int { return 0 })
+	h.rooms.join("conv-1", laptop, func() int { return 0 })
+
+	t.Run("conversation events reach every device", func(t *testing.T) {
+		h.send(phone, ServerMessage{Type: "content", ConversationID: "conv-1", Delta: "Hi"})
+
+		require.Len(t, *phoneReceived, 1)
+		require.Len(t, *laptopReceived, 1)
+		assert.Equal(t, "Hi", (*laptopReceived)[0].Delta)
+		assert.Empty(t, *outsiderReceived)
+	})
+
+	t.Run("errors only reach the sender", func(t *testing.T) {
+		h.sendError(phone, "conv-1", "PROCESSING_ERROR", "failed")
+
+		require.Len(t, *phoneReceived, 2)
+		assert.Equal(t, "error", (*phoneReceived)[1].Type)
+		assert.Len(t, *laptopReceived, 1)
+	})
+
+	t.Run("connections outside the room are written to directly", func(t *testing.T) {
+		h.send(outsider, ServerMessage{Type: "content", ConversationID: "conv-1", Delta: "Hi"})
+
+		require.Len(t, *outsiderReceived, 1)
+		assert.Zero(t, (*outsiderReceived)[0].Seq)
+		assert.Len(t, *laptopReceived, 1)
+	})
+
+	t.Run("user typing is not echoed to the typing device", func(t *testing.T) {
+		typing := true
+		h.handleTyping(phone, &ClientMessage{Type: "typing", ConversationID: "conv-1", Typing: &typing})
+
+		assert.Len(t, *phoneReceived, 2)
+		require.Len(t, *laptopReceived, 2)
+		assert.Equal(t, "typing", (*laptopReceived)[1].Type)
+		assert.Equal(t, string(RoleUser), (*laptopReceived)[1].Role)
+		require.NotNil(t, (*laptopReceived)[1].Typing)
+		assert.True(t, *(*laptopReceived)[1].Typing)
+	})
+
+	t.Run("typing without a session is rejected", func(t *testing.T) {
+		h.handleTyping(outsider, &ClientMessage{Type: "typing", ConversationID: "conv-1"})
+
+		require.Len(t, *outsiderReceived, 2)
+		assert.Equal(t, "NO_SESSION", (*outsiderReceived)[1].Code)
+	})
+}
+
+func TestChatHandler_HandleRead(t *testing.T) {
+	h, manager := newPresenceTestHandler(t)
+	state := &ConversationState{
+		ID:         "conv-1",
+		Messages:   []Message{{Role: RoleUser, Content: "Hi"}, {Role: RoleAssistant, Content: "Hello"}},
+		LastAccess: time.Now(),
+	}
+	manager.cache[state.ID] = state
+
+	phone, phoneReceived := recordingChatContext()
+	laptop, laptopReceived := recordingChatContext()
+	for _, chatCtx := range []*ChatContext{phone, laptop} {
+		chatCtx.Conversations[state.ID] = state
+		h.joinConversation(context.Background(), chatCtx, state)
+	}
+
+	t.Run("sends a read receipt to every device", func(t *testing.T) {
+		h.handleRead(context.Background(), phone, &ClientMessage{Type: "read", ConversationID: "conv-1", Sequence: 2})
+
+		require.Len(t, *laptopReceived, 1)
+		assert.Equal(t, "receipt", (*laptopReceived)[0].Type)
+		assert.Equal(t, ReceiptRead, (*laptopReceived)[0].Status)
+		assert.Equal(t, 2, (*laptopReceived)[0].Sequence)
+		assert.Len(t, *phoneReceived, 1)
+	})
+
+	t.Run("ignores stale receipts", func(t *testing.T) {
+		h.handleRead(context.Background(), laptop, &ClientMessage{Type: "read", ConversationID: "conv-1", Sequence: 1})
+
+		assert.Len(t, *laptopReceived, 1)
+		assert.Len(t, *phoneReceived, 1)
+	})
+
+	t.Run("rejects sequences outside the conversation", func(t *testing.T) {
+		h.handleRead(context.Background(), phone, &ClientMessage{Type: "read", ConversationID: "conv-1", Sequence: 3})
+
+		require.Len(t, *phoneReceived, 2)
+		assert.Equal(t, "INVALID_SEQUENCE", (*phoneReceived)[1].Code)
+		assert.Len(t, *laptopReceived, 1)
+	})
+}
+
+func TestConversationManager_AppendMessage(t *testing.T) {
+	_, manager := newPresenceTestHandler(t)
+	manager.cache["conv-1"] = &ConversationState{ID: "conv-1", LastAccess: time.Now()}
+
+	first, err := manager.AppendMessage(context.Background(), "conv-1", Message{Role: RoleUser, Content: "Hi"}, 0, 0)
+	require.NoError(t, err)
+	second, err := manager.AppendMessage(context.Background(), "conv-1", Message{Role: RoleAssistant, Content: "Hello"}, 10, 5)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, first.Sequence)
+	assert.Equal(t, 2, second.Sequence)
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Equal(t, 1, manager.cache["conv-1"].TurnCount)
+
+	missing, err := manager.AppendMessage(context.Background(), "conv-2", Message{Role: RoleUser, Content: "Hi"}, 0, 0)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
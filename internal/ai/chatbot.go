@@ -56,6 +56,7 @@ type Chatbot struct {
 	PersistConversations bool `json:"persist_conversations"`
 	ConversationTTLHours int  `json:"conversation_ttl_hours"`
 	MaxConversationTurns int  `json:"max_conversation_turns"`
+	ConversationSearch   bool `json:"conversation_search"` // Embed persisted messages for semantic search (parsed from annotations, not stored in DB)
 
 	// Rate limiting (per user, per chatbot)
 	RateLimitPerMinute int `json:"rate_limit_per_minute"`
@@ -68,7 +69,8 @@ type Chatbot struct {
 	RequireRoles         []string `json:"require_roles,omitempty"` // Required roles to access (OR semantics)
 
 	// Response language
-	ResponseLanguage string `json:"response_language"` // "auto" (default), ISO code, or language name
+	ResponseLanguage  string `json:"response_language"`             // "auto" (default), ISO code, or language name
+	MatchUserLanguage *bool  `json:"match_user_language,omitempty"` // Detect the user's language server-side (parsed from annotations, not stored in DB); nil uses ai.match_user_language
 
 	// Logging
 	DisableExecutionLogs bool `json:"disable_execution_logs"` // If true, skip creating execution logs
@@ -84,15 +86,29 @@ type Chatbot struct {
 	KnowledgeBases         []string `json:"knowledge_bases,omitempty"`
 	RAGMaxChunks           int      `json:"rag_max_chunks"`
 	RAGSimilarityThreshold float64  `json:"rag_similarity_threshold"`
-	RAGTable               string   `json:"rag_table,omitempty"`          // User table for vector search
-	RAGColumn              string   `json:"rag_column,omitempty"`         // Vector column in RAG table
-	RAGContentColumn       string   `json:"rag_content_column,omitempty"` // Text content column in RAG table
+	RAGTable               string   `json:"rag_table,omitempty"`           // User table for vector search
+	RAGColumn              string   `json:"rag_column,omitempty"`          // Vector column in RAG table
+	RAGContentColumn       string   `json:"rag_content_column,omitempty"`  // Text content column in RAG table
+	RAGQueryExpansion      int      `json:"rag_query_expansion,omitempty"` // Query reformulations searched too, fused with RRF (parsed from annotations, not stored in DB)
 
 	// Agent behavior settings
 	ReasoningMode     string `json:"reasoning_mode,omitempty"`      // "none" (default), "react", "strict" - controls think tool usage
 	MaxToolIterations int    `json:"max_tool_iterations,omitempty"` // Max tool calling iterations (default: 5)
 	ShowReasoning     bool   `json:"show_reasoning,omitempty"`      // If true, expose agent reasoning to users
 
+	// Human escalation settings (parsed from annotations, not stored in DB)
+	EscalationWebhookURL string  `json:"escalation_webhook_url,omitempty"` // Webhook notified when a conversation is escalated
+	EscalationEmail      string  `json:"escalation_email,omitempty"`       // Email address notified when a conversation is escalated
+	EscalationThreshold  float64 `json:"escalation_threshold,omitempty"`   // Escalate when best RAG similarity is below this value (0 = disabled)
+	EscalationMessage    string  `json:"escalation_message,omitempty"`     // Holding message sent while a human takes over
+
+	// Model routing settings (parsed from annotations, not stored in DB)
+	ModelRoutes []ModelRoute          `json:"model_routes,omitempty"` // Routes to other models, evaluated in order
+	ModelPrices map[string]ModelPrice `json:"model_prices,omitempty"` // USD per million tokens, for cost deltas
+
+	// Table queries offered to the model as tools (parsed from annotations, not stored in DB)
+	RESTTools []RESTTool `json:"rest_tools,omitempty"`
+
 	Version   int       `json:"version"`
 	Source    string    `json:"source"` // "filesystem" or "api"
 	CreatedBy *string   `json:"created_by,omitempty"`
@@ -122,6 +138,7 @@ type ChatbotConfig struct {
 	PersistConversations bool
 	ConversationTTL      time.Duration
 	MaxTurns             int
+	ConversationSearch   bool // Embed persisted messages for semantic search
 
 	// Rate limiting
 	RateLimitPerMinute int
@@ -140,9 +157,11 @@ type ChatbotConfig struct {
 	RAGTable               string   // User table for vector search (optional)
 	RAGColumn              string   // Vector column in RAG table
 	RAGContentColumn       string   // Text content column in RAG table
+	RAGQueryExpansion      int      // Query reformulations to generate and search too (0 = disabled)
 
 	// Response language
-	ResponseLanguage string // "auto" (default), ISO code, or language name
+	ResponseLanguage  string // "auto" (default), ISO code, or language name
+	MatchUserLanguage *bool  // Detect the user's language server-side; nil uses ai.match_user_language
 
 	// Logging
 	DisableExecutionLogs bool
@@ -159,6 +178,19 @@ type ChatbotConfig struct {
 	MaxToolIterations int    // Max tool calling iterations (default: 5)
 	ShowReasoning     bool   // If true, expose agent reasoning to users
 
+	// Human escalation settings
+	EscalationWebhookURL string  // Webhook notified when a conversation is escalated
+	EscalationEmail      string  // Email address notified when a conversation is escalated
+	EscalationThreshold  float64 // Escalate when best RAG similarity is below this value (0 = disabled)
+	EscalationMessage    string  // Holding message sent while a human takes over
+
+	// Model routing settings
+	ModelRoutes []ModelRoute          // Routes to other models, evaluated in order
+	ModelPrices map[string]ModelPrice // USD per million tokens, for cost deltas
+
+	// Table queries offered to the model as tools
+	RESTTools []RESTTool
+
 	// Metadata
 	Version int
 }
@@ -217,6 +249,9 @@ var (
 	// @fluxbase:persist-conversations true
 	persistConversationsPattern = regexp.MustCompile(`@fluxbase:persist-conversations\s+(true|false)`)
 
+	// @fluxbase:conversation-search true
+	conversationSearchPattern = regexp.MustCompile(`@fluxbase:conversation-search\s+(true|false)`)
+
 	// @fluxbase:conversation-ttl 24h
 	conversationTTLPattern = regexp.MustCompile(`@fluxbase:conversation-ttl\s+([^\n*]+)`)
 
@@ -276,9 +311,15 @@ var (
 	// @fluxbase:rag-content-column content (text column to retrieve)
 	ragContentColumnPattern = regexp.MustCompile(`@fluxbase:rag-content-column\s+([^\n*\s]+)`)
 
+	// @fluxbase:rag-query-expansion 3 (reformulations of the question searched too)
+	ragQueryExpansionPattern = regexp.MustCompile(`@fluxbase:rag-query-expansion\s+(\d+)`)
+
 	// @fluxbase:response-language auto | en | German | Deutsch
 	responseLanguagePattern = regexp.MustCompile(`@fluxbase:response-language\s+([^\n*]+)`)
 
+	// @fluxbase:match-user-language true
+	matchUserLanguagePattern = regexp.MustCompile(`@fluxbase:match-user-language\s+(true|false)`)
+
 	// @fluxbase:disable-execution-logs true
 	disableExecutionLogsPattern = regexp.MustCompile(`@fluxbase:disable-execution-logs(?:\s+(true|false))?`)
 
@@ -301,6 +342,19 @@ var (
 
 	// @fluxbase:show-reasoning true
 	showReasoningPattern = regexp.MustCompile(`@fluxbase:show-reasoning\s+(true|false)`)
+
+	// Human escalation annotations
+	// @fluxbase:escalation-webhook https://support.example.com/hooks/chat
+	escalationWebhookPattern = regexp.MustCompile(`@fluxbase:escalation-webhook\s+([^\n*\s]+)`)
+
+	// @fluxbase:escalation-email support@example.com
+	escalationEmailPattern = regexp.MustCompile(`@fluxbase:escalation-email\s+([^\n*\s]+)`)
+
+	// @fluxbase:escalation-threshold 0.5
+	escalationThresholdPattern = regexp.MustCompile(`@fluxbase:escalation-threshold\s+([\d.]+)`)
+
+	// @fluxbase:escalation-message A member of our team will get back to you shortly.
+	escalationMessagePattern = regexp.MustCompile(`@fluxbase:escalation-message\s+([^\n*]+)`)
 )
 
 // ParseChatbotConfig parses chatbot configuration from TypeScript source code
@@ -351,6 +405,11 @@ func ParseChatbotConfig(code string) ChatbotConfig {
 		config.PersistConversations = matches[1] == "true"
 	}
 
+	// Parse conversation search
+	if matches := conversationSearchPattern.FindStringSubmatch(code); len(matches) > 1 {
+		config.ConversationSearch = matches[1] == "true"
+	}
+
 	// Parse conversation TTL
 	if matches := conversationTTLPattern.FindStringSubmatch(code); len(matches) > 1 {
 		if d, err := time.ParseDuration(strings.TrimSpace(matches[1])); err == nil {
@@ -475,10 +534,14 @@ func ParseChatbotConfig(code string) ChatbotConfig {
 		config.RAGContentColumn = strings.TrimSpace(matches[1])
 	}
 
+	// Parse RAG query expansion
+	config.RAGQueryExpansion = parseRAGQueryExpansion(code)
+
 	// Parse response language
 	if matches := responseLanguagePattern.FindStringSubmatch(code); len(matches) > 1 {
 		config.ResponseLanguage = strings.TrimSpace(matches[1])
 	}
+	config.MatchUserLanguage = parseMatchUserLanguage(code)
 
 	// Parse disable-execution-logs flag
 	if matches := disableExecutionLogsPattern.FindStringSubmatch(code); matches != nil {
@@ -523,9 +586,34 @@ func ParseChatbotConfig(code string) ChatbotConfig {
 		config.ShowReasoning = matches[1] == "true"
 	}
 
+	parseEscalationConfig(code, &config)
+	parseModelRoutingConfig(code, &config)
+	parseRESTToolsConfig(code, &config)
+
 	return config
 }
 
+// parseEscalationConfig parses the human escalation annotations into config
+func parseEscalationConfig(code string, config *ChatbotConfig) {
+	if matches := escalationWebhookPattern.FindStringSubmatch(code); len(matches) > 1 {
+		config.EscalationWebhookURL = strings.TrimSpace(matches[1])
+	}
+
+	if matches := escalationEmailPattern.FindStringSubmatch(code); len(matches) > 1 {
+		config.EscalationEmail = strings.TrimSpace(matches[1])
+	}
+
+	if matches := escalationThresholdPattern.FindStringSubmatch(code); len(matches) > 1 {
+		if v, err := strconv.ParseFloat(matches[1], 64); err == nil && v >= 0 && v <= 1 {
+			config.EscalationThreshold = v
+		}
+	}
+
+	if matches := escalationMessagePattern.FindStringSubmatch(code); len(matches) > 1 {
+		config.EscalationMessage = strings.TrimSpace(matches[1])
+	}
+}
+
 // ParseDescription extracts the chatbot description from JSDoc comments
 func ParseDescription(code string) string {
 	if matches := descriptionPattern.FindStringSubmatch(code); len(matches) > 1 {
@@ -637,12 +725,14 @@ func (c *Chatbot) ApplyConfig(config ChatbotConfig) {
 	c.PersistConversations = config.PersistConversations
 	c.ConversationTTLHours = int(config.ConversationTTL.Hours())
 	c.MaxConversationTurns = config.MaxTurns
+	c.ConversationSearch = config.ConversationSearch
 	c.RateLimitPerMinute = config.RateLimitPerMinute
 	c.DailyRequestLimit = config.DailyRequestLimit
 	c.DailyTokenBudget = config.DailyTokenBudget
 	c.AllowUnauthenticated = config.AllowUnauthenticated
 	c.IsPublic = config.IsPublic
 	c.ResponseLanguage = config.ResponseLanguage
+	c.MatchUserLanguage = config.MatchUserLanguage
 	c.DisableExecutionLogs = config.DisableExecutionLogs
 	c.RequiredSettings = config.RequiredSettings
 	c.MCPTools = config.MCPTools
@@ -655,12 +745,26 @@ func (c *Chatbot) ApplyConfig(config ChatbotConfig) {
 	c.RAGTable = config.RAGTable
 	c.RAGColumn = config.RAGColumn
 	c.RAGContentColumn = config.RAGContentColumn
+	c.RAGQueryExpansion = config.RAGQueryExpansion
 
 	// Agent behavior settings
 	c.ReasoningMode = config.ReasoningMode
 	c.MaxToolIterations = config.MaxToolIterations
 	c.ShowReasoning = config.ShowReasoning
 
+	// Human escalation settings
+	c.EscalationWebhookURL = config.EscalationWebhookURL
+	c.EscalationEmail = config.EscalationEmail
+	c.EscalationThreshold = config.EscalationThreshold
+	c.EscalationMessage = config.EscalationMessage
+
+	// Model routing settings
+	c.ModelRoutes = config.ModelRoutes
+	c.ModelPrices = config.ModelPrices
+
+	// REST tools
+	c.RESTTools = config.RESTTools
+
 	// Only override version if explicitly set in annotation
 	if config.Version > 0 {
 		c.Version = config.Version
@@ -704,6 +808,37 @@ func (c *Chatbot) PopulateDerivedFields() {
 			c.Model = strings.TrimSpace(matches[1])
 		}
 	}
+
+	// Escalation, model routing, conversation search, language matching, query expansion and REST tool settings are not stored in the database, re-parse them from code
+	if c.Code != "" {
+		var escalation ChatbotConfig
+		parseEscalationConfig(c.Code, &escalation)
+		c.EscalationWebhookURL = escalation.EscalationWebhookURL
+		c.EscalationEmail = escalation.EscalationEmail
+		c.EscalationThreshold = escalation.EscalationThreshold
+		c.EscalationMessage = escalation.EscalationMessage
+
+		var routing ChatbotConfig
+		parseModelRoutingConfig(c.Code, &routing)
+		c.ModelRoutes = routing.ModelRoutes
+		c.ModelPrices = routing.ModelPrices
+
+		if matches := conversationSearchPattern.FindStringSubmatch(c.Code); len(matches) > 1 {
+			c.ConversationSearch = matches[1] == "true"
+		}
+
+		c.MatchUserLanguage = parseMatchUserLanguage(c.Code)
+		c.RAGQueryExpansion = parseRAGQueryExpansion(c.Code)
+
+		var restTools ChatbotConfig
+		parseRESTToolsConfig(c.Code, &restTools)
+		c.RESTTools = restTools.RESTTools
+	}
+}
+
+// HasEscalation returns true if the chatbot has a human escalation target configured
+func (c *Chatbot) HasEscalation() bool {
+	return c.EscalationWebhookURL != "" || c.EscalationEmail != ""
 }
 
 // QualifiedTable represents a table with its schema
@@ -461,6 +461,7 @@ func TestParseChatbotConfig_AllAnnotations(t *testing.T) {
 		" * @fluxbase:rag-table documents\n" +
 		" * @fluxbase:rag-column embedding\n" +
 		" * @fluxbase:rag-content-column content\n" +
+		" * @fluxbase:rag-query-expansion 4\n" +
 		" * @fluxbase:response-language Spanish\n" +
 		" * @fluxbase:disable-execution-logs true\n" +
 		" * @fluxbase:required-settings openai.api_key,stripe.endpoint\n" +
@@ -511,6 +512,7 @@ func TestParseChatbotConfig_AllAnnotations(t *testing.T) {
 	assert.Equal(t, "documents", config.RAGTable)
 	assert.Equal(t, "embedding", config.RAGColumn)
 	assert.Equal(t, "content", config.RAGContentColumn)
+	assert.Equal(t, 4, config.RAGQueryExpansion)
 
 	// Response language
 	assert.Equal(t, "Spanish", config.ResponseLanguage)
@@ -926,3 +928,68 @@ func TestApplyConfig_FullConfig(t *testing.T) {
 	assert.True(t, chatbot.UseMCPSchema)
 	assert.Equal(t, 2, chatbot.Version)
 }
+
+func TestParseChatbotConfig_Escalation(t *testing.T) {
+	code := "/**\n" +
+		" * Support bot\n" +
+		" *\n" +
+		" * @fluxbase:escalation-webhook https://support.example.com/hooks/chat\n" +
+		" * @fluxbase:escalation-email support@example.com\n" +
+		" * @fluxbase:escalation-threshold 0.55\n" +
+		" * @fluxbase:escalation-message A member of our team will reply shortly.\n" +
+		" */\n" +
+		"export default `You are a support assistant.`;\n"
+
+	config := ParseChatbotConfig(code)
+
+	assert.Equal(t, "https://support.example.com/hooks/chat", config.EscalationWebhookURL)
+	assert.Equal(t, "support@example.com", config.EscalationEmail)
+	assert.InDelta(t, 0.55, config.EscalationThreshold, 0.0001)
+	assert.Equal(t, "A member of our team will reply shortly.", config.EscalationMessage)
+
+	t.Run("persisted chatbots re-parse escalation settings from code", func(t *testing.T) {
+		chatbot := &Chatbot{Code: code}
+		chatbot.PopulateDerivedFields()
+
+		assert.True(t, chatbot.HasEscalation())
+		assert.Equal(t, "support@example.com", chatbot.EscalationEmail)
+		assert.InDelta(t, 0.55, chatbot.EscalationThreshold, 0.0001)
+	})
+
+	t.Run("out of range threshold is ignored", func(t *testing.T) {
+		config := ParseChatbotConfig(" * @fluxbase:escalation-threshold 1.5\n")
+		assert.Equal(t, 0.0, config.EscalationThreshold)
+	})
+
+	t.Run("no escalation by default", func(t *testing.T) {
+		chatbot := &Chatbot{}
+		chatbot.ApplyConfig(DefaultChatbotConfig())
+		assert.False(t, chatbot.HasEscalation())
+	})
+}
+
+func TestParseChatbotConfig_ConversationSearch(t *testing.T) {
+	code := "/**\n" +
+		" * @fluxbase:persist-conversations true\n" +
+		" * @fluxbase:conversation-search true\n" +
+		" */\n" +
+		"export default `You are a support assistant.`;\n"
+
+	config := ParseChatbotConfig(code)
+	assert.True(t, config.ConversationSearch)
+
+	chatbot := &Chatbot{}
+	chatbot.ApplyConfig(config)
+	assert.True(t, chatbot.ConversationSearch)
+
+	t.Run("persisted chatbots re-parse conversation search from code", func(t *testing.T) {
+		chatbot := &Chatbot{Code: code}
+		chatbot.PopulateDerivedFields()
+		assert.True(t, chatbot.ConversationSearch)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		assert.False(t, DefaultChatbotConfig().ConversationSearch)
+		assert.False(t, ParseChatbotConfig(" * @fluxbase:conversation-search false\n").ConversationSearch)
+	})
+}
@@ -56,6 +56,8 @@ type Conversation struct {
 	UpdatedAt             time.Time  `json:"updated_at"`
 	LastMessageAt         time.Time  `json:"last_message_at"`
 	ExpiresAt             *time.Time `json:"expires_at"`
+	Searchable            bool       `json:"searchable"`
+	ConversationAttributes
 }
 
 // ConversationMessage represents a message in a conversation
@@ -76,6 +78,9 @@ type ConversationMessage struct {
 	QueryResults     []map[string]interface{} `json:"query_results,omitempty"` // Full query results for assistant messages
 	PromptTokens     *int                     `json:"prompt_tokens,omitempty"`
 	CompletionTokens *int                     `json:"completion_tokens,omitempty"`
+	Model            *string                  `json:"model,omitempty"`          // Model chosen by the chatbot's model routes
+	RoutingRule      *string                  `json:"routing_rule,omitempty"`   // Route that chose the model, nil for the default model
+	CostDeltaUSD     *float64                 `json:"cost_delta_usd,omitempty"` // Cost compared to the default model
 	CreatedAt        time.Time                `json:"created_at"`
 	SequenceNumber   int                      `json:"sequence_number"`
 }
@@ -96,8 +101,9 @@ func NewConversationManager(db *database.Connection, cacheTTL time.Duration, max
 	return cm
 }
 
-// CreateConversation creates a new conversation
-func (cm *ConversationManager) CreateConversation(ctx context.Context, chatbot *Chatbot, userID *string, sessionID *string) (*ConversationState, error) {
+// CreateConversation creates a new conversation. attrs, which may be nil, sets the metadata and
+// tags of persisted conversations.
+func (cm *ConversationManager) CreateConversation(ctx context.Context, chatbot *Chatbot, userID *string, sessionID *string, attrs *ConversationAttributes) (*ConversationState, error) {
 	conversationID := uuid.New().String()
 
 	state := &ConversationState{
@@ -128,6 +134,11 @@ func (cm *ConversationManager) CreateConversation(ctx context.Context, chatbot *
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 			ExpiresAt: state.ExpiresAt,
+			// Only indexed for semantic search when the chatbot opts in
+			Searchable: chatbot.ConversationSearch,
+		}
+		if attrs != nil {
+			conversation.ConversationAttributes = *attrs
 		}
 
 		if err := cm.saveConversation(ctx, conversation); err != nil {
@@ -203,17 +214,34 @@ func (cm *ConversationManager) GetConversation(ctx context.Context, conversation
 	return state, nil
 }
 
+// StoredMessage identifies a message added to a conversation
+type StoredMessage struct {
+	ID       string
+	Sequence int // 1-based position in the conversation
+}
+
 // AddMessage adds a message to a conversation
 func (cm *ConversationManager) AddMessage(ctx context.Context, conversationID string, msg Message, promptTokens, completionTokens int) error {
+	_, err := cm.AppendMessage(ctx, conversationID, msg, promptTokens, completionTokens)
+	return err
+}
+
+// AppendMessage adds a message to a conversation and returns its ID and sequence number.
+// It returns nil if the conversation is not active.
+func (cm *ConversationManager) AppendMessage(ctx context.Context, conversationID string, msg Message, promptTokens, completionTokens int) (*StoredMessage, error) {
 	cm.cacheMu.Lock()
 	state, exists := cm.cache[conversationID]
 	if !exists {
 		cm.cacheMu.Unlock()
-		return nil // Conversation not found
+		return nil, nil // Conversation not found
 	}
 
 	// Add message to state
 	state.Messages = append(state.Messages, msg)
+	stored := &StoredMessage{
+		ID:       uuid.New().String(),
+		Sequence: len(state.Messages),
+	}
 	state.TotalPromptTokens += promptTokens
 	state.TotalCompletionTokens += completionTokens
 	isFirstUserMessage := false
@@ -233,11 +261,11 @@ func (cm *ConversationManager) AddMessage(ctx context.Context, conversationID st
 	// Persist to database if required
 	if persistToDb {
 		dbMsg := &ConversationMessage{
-			ID:             uuid.New().String(),
+			ID:             stored.ID,
 			ConversationID: conversationID,
 			Role:           string(msg.Role),
 			Content:        msg.Content,
-			SequenceNumber: len(state.Messages),
+			SequenceNumber: stored.Sequence,
 			CreatedAt:      time.Now(),
 		}
 
@@ -247,6 +275,11 @@ func (cm *ConversationManager) AddMessage(ctx context.Context, conversationID st
 		if completionTokens > 0 {
 			dbMsg.CompletionTokens = &completionTokens
 		}
+		if msg.Model != nil {
+			dbMsg.Model = optString(msg.Model.Model)
+			dbMsg.RoutingRule = optString(msg.Model.Rule)
+			dbMsg.CostDeltaUSD = msg.Model.CostDeltaUSD
+		}
 
 		// Convert QueryResults to the database format
 		if len(msg.QueryResults) > 0 {
@@ -272,7 +305,7 @@ func (cm *ConversationManager) AddMessage(ctx context.Context, conversationID st
 		}
 	}
 
-	return nil
+	return stored, nil
 }
 
 // GetMessages returns all messages in a conversation
@@ -343,16 +376,18 @@ func (cm *ConversationManager) saveConversation(ctx context.Context, conv *Conve
 		INSERT INTO ai.conversations (
 			id, chatbot_id, user_id, session_id, title, status,
 			turn_count, total_prompt_tokens, total_completion_tokens,
-			created_at, updated_at, last_message_at, expires_at
+			created_at, updated_at, last_message_at, expires_at, searchable,
+			metadata, tags
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15::jsonb, $16
 		)
 	`
 
 	_, err := cm.db.Exec(ctx, query,
 		conv.ID, conv.ChatbotID, validUserID, conv.SessionID, conv.Title, conv.Status,
 		conv.TurnCount, conv.TotalPromptTokens, conv.TotalCompletionTokens,
-		conv.CreatedAt, conv.UpdatedAt, conv.LastMessageAt, conv.ExpiresAt,
+		conv.CreatedAt, conv.UpdatedAt, conv.LastMessageAt, conv.ExpiresAt, conv.Searchable,
+		string(conv.metadataJSON()), conv.tagList(),
 	)
 
 	return err
@@ -426,9 +461,10 @@ func (cm *ConversationManager) saveMessage(ctx context.Context, msg *Conversatio
 		INSERT INTO ai.messages (
 			id, conversation_id, role, content, tool_call_id, tool_name,
 			executed_sql, sql_result_summary, sql_row_count, sql_error, sql_duration_ms,
-			query_results, prompt_tokens, completion_tokens, created_at, sequence_number
+			query_results, prompt_tokens, completion_tokens, created_at, sequence_number,
+			model, routing_rule, cost_delta_usd
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19
 		)
 	`
 
@@ -436,6 +472,7 @@ func (cm *ConversationManager) saveMessage(ctx context.Context, msg *Conversatio
 		msg.ID, msg.ConversationID, msg.Role, msg.Content, msg.ToolCallID, msg.ToolName,
 		msg.ExecutedSQL, msg.SQLResultSummary, msg.SQLRowCount, msg.SQLError, msg.SQLDurationMs,
 		msg.QueryResults, msg.PromptTokens, msg.CompletionTokens, msg.CreatedAt, msg.SequenceNumber,
+		msg.Model, msg.RoutingRule, msg.CostDeltaUSD,
 	)
 
 	return err
@@ -0,0 +1,265 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Limits on conversation metadata and tags
+const (
+	MaxConversationTags                = 20
+	MaxConversationTagLength           = 64
+	MaxConversationMetadataKeys        = 32
+	MaxConversationMetadataKeyLength   = 64
+	MaxConversationMetadataValueLength = 256
+)
+
+// conversationMetadataQueryPrefix prefixes query parameters that filter on a metadata key,
+// e.g. ?metadata.channel=web
+const conversationMetadataQueryPrefix = "metadata."
+
+// ErrConversationNotFound is returned when a conversation to update does not exist
+var ErrConversationNotFound = errors.New("conversation not found")
+
+// ErrInvalidConversationGroupBy is returned for an unsupported conversation breakdown dimension
+var ErrInvalidConversationGroupBy = errors.New(`group_by must be "tag" or "metadata.<key>"`)
+
+// ErrInvalidConversationAttributes is returned when conversation metadata or tags exceed their limits
+var ErrInvalidConversationAttributes = errors.New("invalid conversation metadata")
+
+// ConversationAttributes are the business dimensions attached to a conversation
+type ConversationAttributes struct {
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
+}
+
+// Normalize trims and de-duplicates tags and checks metadata and tags against their limits
+func (a *ConversationAttributes) Normalize() error {
+	if len(a.Metadata) > MaxConversationMetadataKeys {
+		return fmt.Errorf("%w: at most %d metadata keys are allowed", ErrInvalidConversationAttributes, MaxConversationMetadataKeys)
+	}
+	for key, value := range a.Metadata {
+		if key == "" || len(key) > MaxConversationMetadataKeyLength {
+			return fmt.Errorf("%w: metadata keys must be 1-%d characters", ErrInvalidConversationAttributes, MaxConversationMetadataKeyLength)
+		}
+		if len(value) > MaxConversationMetadataValueLength {
+			return fmt.Errorf("%w: metadata value for %q exceeds %d characters", ErrInvalidConversationAttributes, key, MaxConversationMetadataValueLength)
+		}
+	}
+
+	tags := make([]string, 0, len(a.Tags))
+	seen := make(map[string]bool, len(a.Tags))
+	for _, tag := range a.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || len(tag) > MaxConversationTagLength {
+			return fmt.Errorf("%w: tags must be 1-%d characters", ErrInvalidConversationAttributes, MaxConversationTagLength)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > MaxConversationTags {
+		return fmt.Errorf("%w: at most %d tags are allowed", ErrInvalidConversationAttributes, MaxConversationTags)
+	}
+	a.Tags = tags
+	return nil
+}
+
+// metadataJSON returns the metadata as the JSON stored in ai.conversations.metadata
+func (a *ConversationAttributes) metadataJSON() []byte {
+	if a == nil || len(a.Metadata) == 0 {
+		return []byte("{}")
+	}
+	data, _ := json.Marshal(a.Metadata)
+	return data
+}
+
+// tagList returns the tags as stored in ai.conversations.tags, never nil
+func (a *ConversationAttributes) tagList() []string {
+	if a == nil || a.Tags == nil {
+		return []string{}
+	}
+	return a.Tags
+}
+
+// ConversationFilter selects conversations by metadata and tags. A conversation matches when
+// it has all of the tags and all of the metadata values.
+type ConversationFilter struct {
+	Tags     []string
+	Metadata map[string]string
+}
+
+// ParseConversationFilter reads a filter from the query string: ?tag=a,b requires every listed
+// tag and ?metadata.<key>=<value> requires a metadata value
+func ParseConversationFilter(c fiber.Ctx) ConversationFilter {
+	var filter ConversationFilter
+	for _, tag := range strings.Split(c.Query("tag"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			filter.Tags = append(filter.Tags, tag)
+		}
+	}
+	for param, value := range c.Queries() {
+		key, ok := strings.CutPrefix(param, conversationMetadataQueryPrefix)
+		if !ok || key == "" {
+			continue
+		}
+		if filter.Metadata == nil {
+			filter.Metadata = make(map[string]string)
+		}
+		filter.Metadata[key] = value
+	}
+	return filter
+}
+
+// conditions returns SQL conditions on the conversations table alias, numbering parameters
+// from argIndex
+func (f ConversationFilter) conditions(alias string, argIndex int) ([]string, []interface{}) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	if len(f.Tags) > 0 {
+		conditions = append(conditions, fmt.Sprintf("%s.tags @> $%d::text[]", alias, argIndex))
+		args = append(args, f.Tags)
+		argIndex++
+	}
+	if len(f.Metadata) > 0 {
+		data, _ := json.Marshal(f.Metadata)
+		conditions = append(conditions, fmt.Sprintf("%s.metadata @> $%d::jsonb", alias, argIndex))
+		args = append(args, string(data))
+	}
+	return conditions, args
+}
+
+// appendTo appends the filter conditions to a query whose WHERE clause is already open
+func (f ConversationFilter) appendTo(query, alias string, args []interface{}) (string, []interface{}) {
+	conditions, filterArgs := f.conditions(alias, len(args)+1)
+	for _, condition := range conditions {
+		query += " AND " + condition
+	}
+	return query, append(args, filterArgs...)
+}
+
+// UpdateConversationAttributesRequest updates the metadata and/or tags of a conversation.
+// Each field that is set replaces the stored value.
+type UpdateConversationAttributesRequest struct {
+	Metadata *map[string]string `json:"metadata,omitempty"`
+	Tags     *[]string          `json:"tags,omitempty"`
+}
+
+// attributes validates the request and returns the values to store
+func (r *UpdateConversationAttributesRequest) attributes() (*ConversationAttributes, error) {
+	attrs := &ConversationAttributes{}
+	if r.Metadata != nil {
+		attrs.Metadata = *r.Metadata
+	}
+	if r.Tags != nil {
+		attrs.Tags = *r.Tags
+	}
+	if err := attrs.Normalize(); err != nil {
+		return nil, err
+	}
+	return attrs, nil
+}
+
+// UpdateConversationAttributes updates the metadata and/or tags of a conversation
+func (s *Storage) UpdateConversationAttributes(ctx context.Context, conversationID string, req *UpdateConversationAttributesRequest) error {
+	attrs, err := req.attributes()
+	if err != nil {
+		return err
+	}
+
+	var (
+		metadata *string
+		tags     []string
+	)
+	if req.Metadata != nil {
+		data := string(attrs.metadataJSON())
+		metadata = &data
+	}
+	if req.Tags != nil {
+		tags = attrs.tagList()
+	}
+
+	tag, err := s.db.Exec(ctx, `
+		UPDATE ai.conversations SET
+			metadata = COALESCE($2::jsonb, metadata),
+			tags = COALESCE($3::text[], tags),
+			updated_at = NOW()
+		WHERE id = $1
+	`, conversationID, metadata, tags)
+	if err != nil {
+		return fmt.Errorf("failed to update conversation metadata: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrConversationNotFound
+	}
+	return nil
+}
+
+// ConversationBreakdownEntry aggregates the conversations sharing one value of a dimension
+type ConversationBreakdownEntry struct {
+	Value                 *string `json:"value"` // nil groups conversations without the dimension
+	Conversations         int     `json:"conversations"`
+	Turns                 int64   `json:"turns"`
+	TotalPromptTokens     int64   `json:"total_prompt_tokens"`
+	TotalCompletionTokens int64   `json:"total_completion_tokens"`
+}
+
+// GetConversationBreakdown groups conversations by a metadata key, or by tag when groupBy is
+// "tag", optionally narrowed to a chatbot and a filter. A conversation with several tags counts
+// towards each of them.
+func (s *Storage) GetConversationBreakdown(ctx context.Context, groupBy, chatbotID string, filter ConversationFilter) ([]ConversationBreakdownEntry, error) {
+	var (
+		dimension string
+		from      = "ai.conversations c"
+		args      []interface{}
+	)
+	if groupBy == "tag" {
+		dimension = "t.tag"
+		from += " LEFT JOIN LATERAL unnest(c.tags) AS t(tag) ON true"
+	} else {
+		key, ok := strings.CutPrefix(groupBy, conversationMetadataQueryPrefix)
+		if !ok || key == "" {
+			return nil, ErrInvalidConversationGroupBy
+		}
+		args = append(args, key)
+		dimension = "c.metadata ->> $1"
+	}
+
+	query := "SELECT " + dimension + ` AS value,
+			COUNT(*),
+			COALESCE(SUM(c.turn_count), 0),
+			COALESCE(SUM(c.total_prompt_tokens), 0),
+			COALESCE(SUM(c.total_completion_tokens), 0)
+		FROM ` + from + `
+		WHERE 1=1`
+	if chatbotID != "" {
+		args = append(args, chatbotID)
+		query += fmt.Sprintf(" AND c.chatbot_id = $%d", len(args))
+	}
+	query, args = filter.appendTo(query, "c", args)
+	query += " GROUP BY value ORDER BY COUNT(*) DESC"
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversation breakdown: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]ConversationBreakdownEntry, 0)
+	for rows.Next() {
+		var entry ConversationBreakdownEntry
+		if err := rows.Scan(&entry.Value, &entry.Conversations, &entry.Turns, &entry.TotalPromptTokens, &entry.TotalCompletionTokens); err != nil {
+			return nil, fmt.Errorf("failed to scan conversation breakdown: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
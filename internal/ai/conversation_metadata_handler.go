@@ -0,0 +1,64 @@
+package ai
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// CONVERSATION METADATA ENDPOINTS
+// ============================================================================
+
+// UpdateConversationAttributes sets the metadata and/or tags of a conversation
+// PATCH /api/v1/admin/ai/conversations/:id
+func (h *Handler) UpdateConversationAttributes(c fiber.Ctx) error {
+	conversationID := c.Params("id")
+
+	var req UpdateConversationAttributesRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+	}
+
+	err := h.storage.UpdateConversationAttributes(c.RequestCtx(), conversationID, &req)
+	switch {
+	case errors.Is(err, ErrInvalidConversationAttributes):
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
+	case errors.Is(err, ErrConversationNotFound):
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Conversation not found")
+	case err != nil:
+		log.Error().Err(err).Str("id", conversationID).Msg("Failed to update conversation metadata")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to update conversation")
+	}
+
+	return c.JSON(fiber.Map{
+		"updated": true,
+		"id":      conversationID,
+	})
+}
+
+// GetConversationBreakdown returns conversation counts and token usage grouped by a metadata
+// key or by tag, narrowed by the same filters as the conversation list
+// GET /api/v1/admin/ai/conversations/breakdown?group_by=metadata.channel&chatbot_id=X&tag=vip
+func (h *Handler) GetConversationBreakdown(c fiber.Ctx) error {
+	groupBy := c.Query("group_by")
+	if groupBy == "" {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "group_by is required")
+	}
+
+	entries, err := h.storage.GetConversationBreakdown(c.RequestCtx(), groupBy, c.Query("chatbot_id"), ParseConversationFilter(c))
+	if errors.Is(err, ErrInvalidConversationGroupBy) {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
+	}
+	if err != nil {
+		log.Error().Err(err).Str("group_by", groupBy).Msg("Failed to get conversation breakdown")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get conversation breakdown")
+	}
+
+	return c.JSON(fiber.Map{
+		"group_by":  groupBy,
+		"breakdown": entries,
+	})
+}
@@ -0,0 +1,125 @@
+package ai
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConversationAttributes_Normalize(t *testing.T) {
+	t.Run("trims and de-duplicates tags", func(t *testing.T) {
+		attrs := ConversationAttributes{Tags: []string{" vip ", "vip", "trial"}}
+		require.NoError(t, attrs.Normalize())
+		assert.Equal(t, []string{"vip", "trial"}, attrs.Tags)
+	})
+
+	t.Run("rejects empty tags", func(t *testing.T) {
+		attrs := ConversationAttributes{Tags: []string{"  "}}
+		assert.ErrorIs(t, attrs.Normalize(), ErrInvalidConversationAttributes)
+	})
+
+	t.Run("rejects too many tags", func(t *testing.T) {
+		attrs := ConversationAttributes{}
+		for i := 0; i <= MaxConversationTags; i++ {
+			attrs.Tags = append(attrs.Tags, strings.Repeat("t", i+1))
+		}
+		assert.ErrorIs(t, attrs.Normalize(), ErrInvalidConversationAttributes)
+	})
+
+	t.Run("rejects oversized metadata values", func(t *testing.T) {
+		attrs := ConversationAttributes{Metadata: map[string]string{"channel": strings.Repeat("x", MaxConversationMetadataValueLength+1)}}
+		assert.ErrorIs(t, attrs.Normalize(), ErrInvalidConversationAttributes)
+	})
+
+	t.Run("rejects empty metadata keys", func(t *testing.T) {
+		attrs := ConversationAttributes{Metadata: map[string]string{"": "web"}}
+		assert.ErrorIs(t, attrs.Normalize(), ErrInvalidConversationAttributes)
+	})
+}
+
+func TestConversationAttributes_StoredValues(t *testing.T) {
+	var attrs *ConversationAttributes
+	assert.Equal(t, "{}", string(attrs.metadataJSON()))
+	assert.Equal(t, []string{}, attrs.tagList())
+
+	attrs = &ConversationAttributes{Metadata: map[string]string{"channel": "web"}, Tags: []string{"vip"}}
+	assert.JSONEq(t, `{"channel":"web"}`, string(attrs.metadataJSON()))
+	assert.Equal(t, []string{"vip"}, attrs.tagList())
+}
+
+func TestConversation_EmbedsAttributesInJSON(t *testing.T) {
+	conv := Conversation{ID: "conv-1", ConversationAttributes: ConversationAttributes{Tags: []string{"vip"}}}
+
+	data, err := json.Marshal(conv)
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, []interface{}{"vip"}, decoded["tags"])
+}
+
+func TestParseConversationFilter(t *testing.T) {
+	var filter ConversationFilter
+	app := fiber.New()
+	app.Get("/", func(c fiber.Ctx) error {
+		filter = ParseConversationFilter(c)
+		return nil
+	})
+
+	_, err := app.Test(httptest.NewRequest("GET", "/?tag=vip,%20trial&metadata.channel=web&metadata.=x&limit=5", nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"vip", "trial"}, filter.Tags)
+	assert.Equal(t, map[string]string{"channel": "web"}, filter.Metadata)
+}
+
+func TestConversationFilter_AppendTo(t *testing.T) {
+	t.Run("empty filter leaves the query unchanged", func(t *testing.T) {
+		query, args := ConversationFilter{}.appendTo("SELECT 1 WHERE 1=1", "c", nil)
+		assert.Equal(t, "SELECT 1 WHERE 1=1", query)
+		assert.Empty(t, args)
+	})
+
+	t.Run("numbers parameters after existing arguments", func(t *testing.T) {
+		filter := ConversationFilter{Tags: []string{"vip"}, Metadata: map[string]string{"channel": "web"}}
+		query, args := filter.appendTo("SELECT 1 WHERE c.user_id = $1", "c", []interface{}{"user-1"})
+
+		assert.Contains(t, query, "AND c.tags @> $2::text[]")
+		assert.Contains(t, query, "AND c.metadata @> $3::jsonb")
+		require.Len(t, args, 3)
+		assert.Equal(t, []string{"vip"}, args[1])
+		assert.JSONEq(t, `{"channel":"web"}`, args[2].(string))
+	})
+}
+
+func TestUpdateConversationAttributesRequest_Attributes(t *testing.T) {
+	tags := []string{"vip", " vip"}
+	attrs, err := (&UpdateConversationAttributesRequest{Tags: &tags}).attributes()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vip"}, attrs.Tags)
+	assert.Nil(t, attrs.Metadata)
+
+	metadata := map[string]string{"": "x"}
+	_, err = (&UpdateConversationAttributesRequest{Metadata: &metadata}).attributes()
+	assert.ErrorIs(t, err, ErrInvalidConversationAttributes)
+}
+
+func TestHandler_GetConversationBreakdownRequiresGroupBy(t *testing.T) {
+	app := fiber.New()
+	handler := NewHandler(&Storage{}, nil, nil, nil)
+	app.Get("/conversations/breakdown", handler.GetConversationBreakdown)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/conversations/breakdown", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestStorage_GetConversationBreakdownRejectsUnknownDimension(t *testing.T) {
+	_, err := (&Storage{}).GetConversationBreakdown(t.Context(), "channel", "", ConversationFilter{})
+	assert.ErrorIs(t, err, ErrInvalidConversationGroupBy)
+}
@@ -0,0 +1,448 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// conversationIndexBatchSize is the number of messages embedded per indexer pass
+	conversationIndexBatchSize = 100
+
+	// maxEmbeddedMessageChars truncates long messages before embedding to stay within provider input limits
+	maxEmbeddedMessageChars = 8000
+
+	defaultConversationSearchLimit         = 10
+	maxConversationSearchLimit             = 50
+	defaultConversationSearchMinSimilarity = 0.3
+)
+
+// ConversationSearchOptions contains options for semantic search across conversations
+type ConversationSearchOptions struct {
+	QueryEmbedding []float32
+	EmbeddingModel string
+	UserID         *string // nil searches all users (admin)
+	ChatbotName    *string
+	Namespace      *string
+	Limit          int
+	MinSimilarity  float64
+}
+
+// ConversationSearchResult is a message matching a conversation search
+type ConversationSearchResult struct {
+	MessageID      string    `json:"message_id"`
+	ConversationID string    `json:"conversation_id"`
+	Title          *string   `json:"title"`
+	ChatbotName    string    `json:"chatbot"`
+	Namespace      string    `json:"namespace"`
+	UserID         *string   `json:"user_id,omitempty"`
+	Role           string    `json:"role"`
+	Content        string    `json:"content"`
+	CreatedAt      time.Time `json:"created_at"`
+	Similarity     float64   `json:"similarity"`
+}
+
+// pendingMessage is a message of a searchable conversation that has no embedding yet
+type pendingMessage struct {
+	ID             string
+	ConversationID string
+	Content        string
+}
+
+// ListPendingMessageEmbeddings returns messages of searchable conversations that still need an embedding
+func (s *Storage) ListPendingMessageEmbeddings(ctx context.Context, limit int) ([]pendingMessage, error) {
+	query := `
+		SELECT m.id, m.conversation_id, m.content
+		FROM ai.messages m
+		JOIN ai.conversations c ON c.id = m.conversation_id
+		LEFT JOIN ai.message_embeddings e ON e.message_id = m.id
+		WHERE c.searchable
+		  AND c.status = 'active'
+		  AND (c.expires_at IS NULL OR c.expires_at > NOW())
+		  AND m.role IN ('user', 'assistant')
+		  AND m.content <> ''
+		  AND e.message_id IS NULL
+		ORDER BY m.created_at
+		LIMIT $1
+	`
+
+	rows, err := s.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages pending embedding: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []pendingMessage
+	for rows.Next() {
+		var m pendingMessage
+		if err := rows.Scan(&m.ID, &m.ConversationID, &m.Content); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
+
+// SaveMessageEmbedding stores the embedding of a conversation message
+func (s *Storage) SaveMessageEmbedding(ctx context.Context, messageID, conversationID string, embedding []float32, model string) error {
+	query := `
+		INSERT INTO ai.message_embeddings (message_id, conversation_id, embedding, embedding_model)
+		VALUES ($1, $2, $3::vector, $4)
+		ON CONFLICT (message_id) DO NOTHING
+	`
+
+	_, err := s.db.Exec(ctx, query, messageID, conversationID, formatEmbeddingLiteral(embedding), model)
+	if err != nil {
+		return fmt.Errorf("failed to save message embedding: %w", err)
+	}
+	return nil
+}
+
+// PurgeConversationEmbeddings removes embeddings of conversations that were deleted, archived or have expired
+func (s *Storage) PurgeConversationEmbeddings(ctx context.Context) (int64, error) {
+	query := `
+		DELETE FROM ai.message_embeddings e
+		USING ai.conversations c
+		WHERE c.id = e.conversation_id
+		  AND (c.status <> 'active' OR (c.expires_at IS NOT NULL AND c.expires_at <= NOW()))
+	`
+
+	result, err := s.db.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge conversation embeddings: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// SearchConversations finds conversation messages semantically similar to the query embedding.
+// Only embeddings created with the same model and dimensions as the query are compared.
+func (s *Storage) SearchConversations(ctx context.Context, opts ConversationSearchOptions) ([]ConversationSearchResult, error) {
+	query := `
+		SELECT
+			m.id,
+			c.id,
+			c.title,
+			COALESCE(cb.name, ''),
+			COALESCE(cb.namespace, ''),
+			c.user_id,
+			m.role,
+			m.content,
+			m.created_at,
+			1 - (e.embedding <=> $1::vector) AS similarity
+		FROM ai.message_embeddings e
+		JOIN ai.messages m ON m.id = e.message_id
+		JOIN ai.conversations c ON c.id = e.conversation_id
+		LEFT JOIN ai.chatbots cb ON cb.id = c.chatbot_id
+		WHERE e.embedding_model = $2
+		  AND vector_dims(e.embedding) = $3
+		  AND c.status = 'active'
+		  AND (c.expires_at IS NULL OR c.expires_at > NOW())
+		  AND 1 - (e.embedding <=> $1::vector) >= $4
+	`
+
+	args := []interface{}{formatEmbeddingLiteral(opts.QueryEmbedding), opts.EmbeddingModel, len(opts.QueryEmbedding), opts.MinSimilarity}
+	argIndex := 5
+
+	if opts.UserID != nil {
+		query += fmt.Sprintf(" AND c.user_id = $%d", argIndex)
+		args = append(args, *opts.UserID)
+		argIndex++
+	}
+	if opts.ChatbotName != nil {
+		query += fmt.Sprintf(" AND cb.name = $%d", argIndex)
+		args = append(args, *opts.ChatbotName)
+		argIndex++
+	}
+	if opts.Namespace != nil {
+		query += fmt.Sprintf(" AND cb.namespace = $%d", argIndex)
+		args = append(args, *opts.Namespace)
+		argIndex++
+	}
+
+	query += fmt.Sprintf(" ORDER BY e.embedding <=> $1::vector LIMIT $%d", argIndex)
+	args = append(args, opts.Limit)
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search conversations: %w", err)
+	}
+	defer rows.Close()
+
+	results := []ConversationSearchResult{}
+	for rows.Next() {
+		var r ConversationSearchResult
+		if err := rows.Scan(
+			&r.MessageID,
+			&r.ConversationID,
+			&r.Title,
+			&r.ChatbotName,
+			&r.Namespace,
+			&r.UserID,
+			&r.Role,
+			&r.Content,
+			&r.CreatedAt,
+			&r.Similarity,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan conversation search result: %w", err)
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// ConversationIndexer embeds messages of searchable conversations in the background
+// and removes embeddings once their conversation is deleted or expires
+type ConversationIndexer struct {
+	storage    *Storage
+	embeddings *EmbeddingService
+	interval   time.Duration
+	ctx        context.Context
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+	running    bool
+	mu         sync.Mutex
+}
+
+// NewConversationIndexer creates a new conversation indexer that runs every interval
+func NewConversationIndexer(storage *Storage, embeddings *EmbeddingService, interval time.Duration) *ConversationIndexer {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &ConversationIndexer{
+		storage:    storage,
+		embeddings: embeddings,
+		interval:   interval,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Start begins the conversation indexer
+func (i *ConversationIndexer) Start() {
+	i.mu.Lock()
+	if i.running {
+		i.mu.Unlock()
+		return
+	}
+	i.running = true
+	// A stopped service gets a fresh context so it can be restarted (e.g. on regaining leadership)
+	if i.ctx.Err() != nil {
+		i.ctx, i.cancel = context.WithCancel(context.Background())
+	}
+	i.mu.Unlock()
+
+	i.wg.Add(1)
+	go i.run()
+
+	log.Info().
+		Dur("interval", i.interval).
+		Msg("Conversation search indexer started")
+}
+
+// Stop gracefully stops the conversation indexer
+func (i *ConversationIndexer) Stop() {
+	i.mu.Lock()
+	if !i.running {
+		i.mu.Unlock()
+		return
+	}
+	i.running = false
+	i.mu.Unlock()
+
+	i.cancel()
+	i.wg.Wait()
+
+	log.Info().Msg("Conversation search indexer stopped")
+}
+
+// run is the main indexer loop
+func (i *ConversationIndexer) run() {
+	defer i.wg.Done()
+
+	ticker := time.NewTicker(i.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			i.purge()
+			i.indexPending()
+		case <-i.ctx.Done():
+			return
+		}
+	}
+}
+
+// indexPending embeds one batch of messages that have no embedding yet
+func (i *ConversationIndexer) indexPending() {
+	if i.embeddings == nil || !i.embeddings.IsConfigured() {
+		return
+	}
+
+	messages, err := i.storage.ListPendingMessageEmbeddings(i.ctx, conversationIndexBatchSize)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load messages for conversation search indexing")
+		return
+	}
+	if len(messages) == 0 {
+		return
+	}
+
+	texts := make([]string, len(messages))
+	for idx, m := range messages {
+		texts[idx] = truncateForEmbedding(m.Content)
+	}
+
+	resp, err := i.embeddings.Embed(i.ctx, texts, "")
+	if err != nil {
+		log.Error().Err(err).Int("messages", len(messages)).Msg("Failed to embed conversation messages")
+		return
+	}
+	if len(resp.Embeddings) != len(messages) {
+		log.Error().
+			Int("messages", len(messages)).
+			Int("embeddings", len(resp.Embeddings)).
+			Msg("Embedding provider returned an unexpected number of embeddings")
+		return
+	}
+
+	indexed := 0
+	for idx, m := range messages {
+		if err := i.storage.SaveMessageEmbedding(i.ctx, m.ID, m.ConversationID, resp.Embeddings[idx], resp.Model); err != nil {
+			log.Warn().Err(err).Str("message_id", m.ID).Msg("Failed to save message embedding")
+			continue
+		}
+		indexed++
+	}
+
+	log.Debug().
+		Int("indexed", indexed).
+		Str("model", resp.Model).
+		Msg("Indexed conversation messages for search")
+}
+
+// purge removes embeddings of conversations that are no longer searchable
+func (i *ConversationIndexer) purge() {
+	purged, err := i.storage.PurgeConversationEmbeddings(i.ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to purge conversation embeddings")
+		return
+	}
+	if purged > 0 {
+		log.Info().Int64("purged", purged).Msg("Purged embeddings of deleted or expired conversations")
+	}
+}
+
+// IsRunning returns whether the indexer is currently running
+func (i *ConversationIndexer) IsRunning() bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.running
+}
+
+// truncateForEmbedding shortens text to maxEmbeddedMessageChars runes
+func truncateForEmbedding(text string) string {
+	runes := []rune(text)
+	if len(runes) <= maxEmbeddedMessageChars {
+		return text
+	}
+	return string(runes[:maxEmbeddedMessageChars])
+}
+
+// SearchUserConversations searches the authenticated user's conversations by meaning
+// GET /api/v1/ai/conversations/search
+func (h *Handler) SearchUserConversations(c fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeAuthRequired, "Authentication required")
+	}
+
+	return h.searchConversations(c, &userID, false)
+}
+
+// SearchConversations searches conversations of all users by meaning (admin)
+// GET /api/v1/admin/ai/conversations/search
+func (h *Handler) SearchConversations(c fiber.Ctx) error {
+	var userID *string
+	if id := c.Query("user_id"); id != "" {
+		userID = &id
+	}
+
+	return h.searchConversations(c, userID, true)
+}
+
+// searchConversations embeds the query and runs a conversation search scoped to userID
+func (h *Handler) searchConversations(c fiber.Ctx, userID *string, includeOwner bool) error {
+	ctx := c.RequestCtx()
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "q is required")
+	}
+
+	if h.embeddingService == nil || !h.embeddingService.IsConfigured() {
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Conversation search requires an embedding provider")
+	}
+
+	limit := fiber.Query[int](c, "limit", defaultConversationSearchLimit)
+	if limit < 1 {
+		limit = defaultConversationSearchLimit
+	}
+	if limit > maxConversationSearchLimit {
+		limit = maxConversationSearchLimit
+	}
+
+	minSimilarity := fiber.Query[float64](c, "min_similarity", defaultConversationSearchMinSimilarity)
+	if minSimilarity < 0 || minSimilarity > 1 {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "min_similarity must be between 0 and 1")
+	}
+
+	resp, err := h.embeddingService.Embed(ctx, []string{truncateForEmbedding(query)}, "")
+	if err != nil || len(resp.Embeddings) == 0 {
+		log.Error().Err(err).Msg("Failed to embed conversation search query")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to embed search query")
+	}
+
+	opts := ConversationSearchOptions{
+		QueryEmbedding: resp.Embeddings[0],
+		EmbeddingModel: resp.Model,
+		UserID:         userID,
+		Limit:          limit,
+		MinSimilarity:  minSimilarity,
+	}
+	if chatbot := c.Query("chatbot"); chatbot != "" {
+		opts.ChatbotName = &chatbot
+	}
+	if namespace := c.Query("namespace"); namespace != "" {
+		opts.Namespace = &namespace
+	}
+
+	results, err := h.storage.SearchConversations(ctx, opts)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to search conversations")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to search conversations")
+	}
+
+	// Users only see their own conversations, so the owner is redundant
+	if !includeOwner {
+		for idx := range results {
+			results[idx].UserID = nil
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"results": results,
+		"count":   len(results),
+		"model":   resp.Model,
+	})
+}
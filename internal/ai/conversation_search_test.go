@@ -0,0 +1,73 @@
+package ai
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncateForEmbedding(t *testing.T) {
+	assert.Equal(t, "short message", truncateForEmbedding("short message"))
+
+	long := strings.Repeat("é", maxEmbeddedMessageChars+10)
+	truncated := truncateForEmbedding(long)
+	assert.Equal(t, maxEmbeddedMessageChars, len([]rune(truncated)))
+}
+
+func TestConversationIndexer_StartStop(t *testing.T) {
+	indexer := NewConversationIndexer(&Storage{}, nil, time.Hour)
+	assert.False(t, indexer.IsRunning())
+
+	indexer.Start()
+	indexer.Start() // idempotent
+	assert.True(t, indexer.IsRunning())
+
+	indexer.Stop()
+	indexer.Stop()
+	assert.False(t, indexer.IsRunning())
+
+	// Restartable after losing and regaining leadership
+	indexer.Start()
+	assert.True(t, indexer.IsRunning())
+	indexer.Stop()
+}
+
+func TestHandler_SearchConversationsValidation(t *testing.T) {
+	handler := NewHandler(&Storage{}, nil, nil, nil)
+
+	app := fiber.New()
+	app.Get("/user/search", handler.SearchUserConversations)
+	app.Get("/admin/search", handler.SearchConversations)
+	authed := fiber.New()
+	authed.Use(func(c fiber.Ctx) error {
+		c.Locals("user_id", "user-1")
+		return c.Next()
+	})
+	authed.Get("/user/search", handler.SearchUserConversations)
+
+	tests := []struct {
+		name   string
+		app    *fiber.App
+		target string
+		status int
+	}{
+		{"user search requires authentication", app, "/user/search?q=refund", fiber.StatusUnauthorized},
+		{"query is required", authed, "/user/search", fiber.StatusBadRequest},
+		{"blank query is rejected", authed, "/user/search?q=%20%20", fiber.StatusBadRequest},
+		{"admin query is required", app, "/admin/search", fiber.StatusBadRequest},
+		{"embedding provider is required", authed, "/user/search?q=refund", fiber.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := tt.app.Test(httptest.NewRequest("GET", tt.target, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
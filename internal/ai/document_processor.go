@@ -10,6 +10,9 @@ import (
 	"time"
 	"unicode"
 
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/observability"
+	"github.com/nimbleflux/fluxbase/internal/secretscan"
 	"github.com/rs/zerolog/log"
 )
 
@@ -19,6 +22,10 @@ type DocumentProcessor struct {
 	embeddingService *EmbeddingService
 	entityExtractor  EntityExtractor
 	knowledgeGraph   *KnowledgeGraph
+	secretScanner    *secretscan.Guard
+	embeddingCache   embeddingCacheStore    // nil disables the persistent embedding cache
+	metrics          *observability.Metrics // optional
+	batchOptions     EmbeddingBatchOptions
 }
 
 // NewDocumentProcessor creates a new document processor
@@ -28,12 +35,16 @@ func NewDocumentProcessor(
 	entityExtractor EntityExtractor,
 	knowledgeGraph *KnowledgeGraph,
 ) *DocumentProcessor {
-	return &DocumentProcessor{
+	p := &DocumentProcessor{
 		storage:          storage,
 		embeddingService: embeddingService,
 		entityExtractor:  entityExtractor,
 		knowledgeGraph:   knowledgeGraph,
 	}
+	if storage != nil {
+		p.embeddingCache = storage
+	}
+	return p
 }
 
 // ProcessDocumentOptions contains options for processing a document
@@ -68,8 +79,24 @@ func (p *DocumentProcessor) ProcessDocument(ctx context.Context, doc *Document,
 		opts.ChunkStrategy = ChunkingStrategyRecursive
 	}
 
-	// Chunk the document
-	textChunks, err := p.chunkDocument(doc.Content, opts)
+	// Chunk the document. Q&A documents get one chunk per pair that embeds the question
+	// and returns the answer; other documents embed the chunk text itself.
+	var (
+		textChunks    []string
+		embedTexts    []string
+		chunkMetadata []json.RawMessage
+		err           error
+	)
+	if doc.SourceType == DocumentSourceTypeQAPairs {
+		var pairs []QAPair
+		pairs, err = ParseQAPairs(doc.Content, "")
+		if err == nil {
+			textChunks, embedTexts, chunkMetadata = qaPairChunks(pairs)
+		}
+	} else {
+		textChunks, err = p.chunkDocument(doc.Content, opts)
+		embedTexts = textChunks
+	}
 	if err != nil {
 		_ = p.storage.UpdateDocumentStatus(ctx, doc.ID, DocumentStatusFailed, err.Error())
 		return fmt.Errorf("failed to chunk document: %w", err)
@@ -81,6 +108,13 @@ func (p *DocumentProcessor) ProcessDocument(ctx context.Context, doc *Document,
 	}
 
 	log.Info().Str("doc_id", doc.ID).Int("chunks", len(textChunks)).Msg("Document chunked")
+	p.storage.publishDocumentStatus(&DocumentStatusEvent{
+		KnowledgeBaseID: doc.KnowledgeBaseID,
+		DocumentID:      doc.ID,
+		Title:           doc.Title,
+		Status:          DocumentStatusProcessing,
+		ChunksTotal:     len(textChunks),
+	})
 
 	// Extract entities and relationships (best-effort, doesn't fail processing)
 	if p.entityExtractor != nil && p.knowledgeGraph != nil {
@@ -90,7 +124,7 @@ func (p *DocumentProcessor) ProcessDocument(ctx context.Context, doc *Document,
 	}
 
 	// Generate embeddings for all chunks
-	embeddings, err := p.generateEmbeddings(ctx, textChunks)
+	embeddings, err := p.generateEmbeddings(ctx, embedTexts)
 	if err != nil {
 		_ = p.storage.UpdateDocumentStatus(ctx, doc.ID, DocumentStatusFailed, err.Error())
 		return fmt.Errorf("failed to generate embeddings: %w", err)
@@ -108,6 +142,9 @@ func (p *DocumentProcessor) ProcessDocument(ctx context.Context, doc *Document,
 			TokenCount:      &tokenCount,
 			Embedding:       embeddings[i],
 		}
+		if chunkMetadata != nil {
+			chunks[i].Metadata = chunkMetadata[i]
+		}
 	}
 
 	// Save chunks
@@ -337,26 +374,73 @@ func (p *DocumentProcessor) generateEmbeddings(ctx context.Context, texts []stri
 		return nil, fmt.Errorf("embedding service not configured")
 	}
 
-	// Process in batches to avoid rate limits
-	batchSize := 100
-	var allEmbeddings [][]float32
+	// Reuse the cached embeddings of unchanged chunks, so only new or changed chunks are sent to
+	// the provider. Identical chunks are embedded once.
+	model := p.embeddingService.DefaultModel()
+	hashes := make([]string, len(texts))
+	for i, text := range texts {
+		hashes[i] = hashContent(text)
+	}
+	embeddings := make([][]float32, len(texts))
+	cached := p.cachedEmbeddings(ctx, model, hashes)
 
-	for i := 0; i < len(texts); i += batchSize {
-		end := i + batchSize
-		if end > len(texts) {
-			end = len(texts)
+	var missingTexts, missingHashes []string
+	missingIndices := make(map[string][]int)
+	hits := 0
+	for i, hash := range hashes {
+		if embedding, ok := cached[hash]; ok {
+			embeddings[i] = embedding
+			hits++
+			continue
+		}
+		if _, seen := missingIndices[hash]; !seen {
+			missingTexts = append(missingTexts, texts[i])
+			missingHashes = append(missingHashes, hash)
 		}
+		missingIndices[hash] = append(missingIndices[hash], i)
+	}
+	if p.metrics != nil {
+		p.metrics.RecordAIEmbeddingCache(hits, len(texts)-hits)
+	}
+	if len(missingTexts) == 0 {
+		return embeddings, nil
+	}
 
-		batch := texts[i:end]
-		resp, err := p.embeddingService.Embed(ctx, batch, "")
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate embeddings for batch %d: %w", i/batchSize, err)
+	result, err := EmbedInBatches(ctx, p.embeddingService, missingTexts, model, p.batchOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate embeddings: %w", err)
+	}
+
+	created := make(map[string][]float32, len(missingTexts))
+	for i, embedding := range result.Embeddings {
+		hash := missingHashes[i]
+		created[hash] = embedding
+		for _, idx := range missingIndices[hash] {
+			embeddings[idx] = embedding
 		}
+	}
 
-		allEmbeddings = append(allEmbeddings, resp.Embeddings...)
+	if p.embeddingCache != nil {
+		if err := p.embeddingCache.StoreCachedEmbeddings(ctx, model, created); err != nil {
+			log.Warn().Err(err).Str("model", model).Msg("Failed to cache chunk embeddings")
+		}
 	}
 
-	return allEmbeddings, nil
+	return embeddings, nil
+}
+
+// cachedEmbeddings returns the cached embeddings of a model by content hash. The cache is an
+// optimization, so failures to read it only cause the chunks to be embedded again.
+func (p *DocumentProcessor) cachedEmbeddings(ctx context.Context, model string, hashes []string) map[string][]float32 {
+	if p.embeddingCache == nil {
+		return nil
+	}
+	cached, err := p.embeddingCache.GetCachedEmbeddings(ctx, model, hashes)
+	if err != nil {
+		log.Warn().Err(err).Str("model", model).Msg("Failed to read cached chunk embeddings")
+		return nil
+	}
+	return cached
 }
 
 // splitSentences splits text into sentences
@@ -484,6 +568,21 @@ func (p *DocumentProcessor) ReprocessDocument(ctx context.Context, documentID st
 	return p.ProcessDocument(ctx, doc, opts)
 }
 
+// SetMetrics sets the metrics that record embedding cache hits and misses
+func (p *DocumentProcessor) SetMetrics(metrics *observability.Metrics) {
+	p.metrics = metrics
+}
+
+// SetEmbeddingBatchOptions sets how chunk embeddings are batched when sent to the provider
+func (p *DocumentProcessor) SetEmbeddingBatchOptions(opts EmbeddingBatchOptions) {
+	p.batchOptions = opts
+}
+
+// SetSecretScanner sets the guard that checks documents added to public knowledge bases for credentials
+func (p *DocumentProcessor) SetSecretScanner(guard *secretscan.Guard) {
+	p.secretScanner = guard
+}
+
 // AddDocument adds a document to a knowledge base and processes it
 func (p *DocumentProcessor) AddDocument(ctx context.Context, kbID string, req CreateDocumentRequest, userID *string) (*Document, error) {
 	// Get knowledge base config
@@ -495,6 +594,13 @@ func (p *DocumentProcessor) AddDocument(ctx context.Context, kbID string, req Cr
 		return nil, fmt.Errorf("knowledge base not found")
 	}
 
+	// Documents in public knowledge bases are readable by every user, so check them for credentials
+	if kb.Visibility == KBVisibilityPublic {
+		if err := p.checkSecrets(ctx, kb, req, userID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Set defaults
 	sourceType := req.SourceType
 	if sourceType == "" {
@@ -553,6 +659,29 @@ func (p *DocumentProcessor) AddDocument(ctx context.Context, kbID string, req Cr
 	return doc, nil
 }
 
+func (p *DocumentProcessor) checkSecrets(ctx context.Context, kb *KnowledgeBase, req CreateDocumentRequest, userID *string) error {
+	name := req.Title
+	if name == "" {
+		name = req.OriginalFilename
+	}
+	subject := secretscan.Subject{
+		Kind:           secretscan.KindKBDocument,
+		Name:           kb.Name + "/" + name,
+		OverrideReason: strings.TrimSpace(req.SecretsOverrideReason),
+	}
+
+	// Admin uploads have no creator, the uploading user is in the metadata
+	actor := req.Metadata["user_id"]
+	if userID != nil {
+		actor = *userID
+	}
+	if parsed, err := uuid.Parse(actor); err == nil {
+		subject.ActorID = &parsed
+	}
+
+	return p.secretScanner.Check(ctx, subject, map[string]string{name: req.Content})
+}
+
 // extractAndStoreEntities extracts entities and relationships from a document and stores them in the knowledge graph
 func (p *DocumentProcessor) extractAndStoreEntities(ctx context.Context, doc *Document) error {
 	// Use the entity extractor to get entities, relationships, and document-entity links
@@ -1,6 +1,8 @@
 package ai
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -15,6 +17,129 @@ func TestNewDocumentProcessor(t *testing.T) {
 		assert.Nil(t, processor.embeddingService)
 		assert.Nil(t, processor.entityExtractor)
 		assert.Nil(t, processor.knowledgeGraph)
+		assert.Nil(t, processor.embeddingCache)
+	})
+}
+
+// memoryEmbeddingCache is an in-memory embeddingCacheStore
+type memoryEmbeddingCache struct {
+	entries  map[string][]float32 // model + ":" + hash
+	getErr   error
+	storeErr error
+}
+
+func (c *memoryEmbeddingCache) GetCachedEmbeddings(ctx context.Context, model string, hashes []string) (map[string][]float32, error) {
+	if c.getErr != nil {
+		return nil, c.getErr
+	}
+	result := map[string][]float32{}
+	for _, hash := range hashes {
+		if embedding, ok := c.entries[model+":"+hash]; ok {
+			result[hash] = embedding
+		}
+	}
+	return result, nil
+}
+
+func (c *memoryEmbeddingCache) StoreCachedEmbeddings(ctx context.Context, model string, embeddings map[string][]float32) error {
+	if c.storeErr != nil {
+		return c.storeErr
+	}
+	for hash, embedding := range embeddings {
+		c.entries[model+":"+hash] = embedding
+	}
+	return nil
+}
+
+func TestDocumentProcessor_GenerateEmbeddings_Cache(t *testing.T) {
+	// newProcessor returns a processor whose provider embeds a text as [len(text)] and records what it embedded
+	newProcessor := func(cache *memoryEmbeddingCache) (*DocumentProcessor, *[]string) {
+		var embedded []string
+		provider := newMockEmbeddingProvider()
+		provider.embedFunc = func(ctx context.Context, texts []string, model string) (*EmbeddingResponse, error) {
+			embedded = append(embedded, texts...)
+			embeddings := make([][]float32, len(texts))
+			for i, text := range texts {
+				embeddings[i] = []float32{float32(len(text))}
+			}
+			return &EmbeddingResponse{Embeddings: embeddings, Model: model, Dimensions: 1}, nil
+		}
+		processor := NewDocumentProcessor(nil, &EmbeddingService{
+			provider:     provider,
+			defaultModel: "test-model",
+			cacheResults: make(map[string]*cachedEmbedding),
+		}, nil, nil)
+		processor.embeddingCache = cache
+		return processor, &embedded
+	}
+
+	t.Run("embeds only uncached chunks and caches them", func(t *testing.T) {
+		cache := &memoryEmbeddingCache{entries: map[string][]float32{
+			"test-model:" + hashContent("unchanged"): {42},
+		}}
+		processor, embedded := newProcessor(cache)
+
+		embeddings, err := processor.generateEmbeddings(context.Background(), []string{"unchanged", "new", "changed!"})
+		require.NoError(t, err)
+
+		assert.Equal(t, [][]float32{{42}, {3}, {8}}, embeddings)
+		assert.Equal(t, []string{"new", "changed!"}, *embedded)
+		assert.Equal(t, []float32{3}, cache.entries["test-model:"+hashContent("new")])
+		assert.Equal(t, []float32{8}, cache.entries["test-model:"+hashContent("changed!")])
+	})
+
+	t.Run("fully cached document makes no provider call", func(t *testing.T) {
+		cache := &memoryEmbeddingCache{entries: map[string][]float32{}}
+		processor, embedded := newProcessor(cache)
+
+		_, err := processor.generateEmbeddings(context.Background(), []string{"one", "three"})
+		require.NoError(t, err)
+		*embedded = nil
+
+		embeddings, err := processor.generateEmbeddings(context.Background(), []string{"one", "three"})
+		require.NoError(t, err)
+		assert.Equal(t, [][]float32{{3}, {5}}, embeddings)
+		assert.Empty(t, *embedded)
+	})
+
+	t.Run("duplicate chunks are embedded once", func(t *testing.T) {
+		processor, embedded := newProcessor(&memoryEmbeddingCache{entries: map[string][]float32{}})
+
+		embeddings, err := processor.generateEmbeddings(context.Background(), []string{"same", "other", "same"})
+		require.NoError(t, err)
+		assert.Equal(t, [][]float32{{4}, {5}, {4}}, embeddings)
+		assert.Equal(t, []string{"same", "other"}, *embedded)
+	})
+
+	t.Run("cache is keyed by model", func(t *testing.T) {
+		cache := &memoryEmbeddingCache{entries: map[string][]float32{
+			"other-model:" + hashContent("text"): {99},
+		}}
+		processor, embedded := newProcessor(cache)
+
+		embeddings, err := processor.generateEmbeddings(context.Background(), []string{"text"})
+		require.NoError(t, err)
+		assert.Equal(t, [][]float32{{4}}, embeddings)
+		assert.Equal(t, []string{"text"}, *embedded)
+	})
+
+	t.Run("cache failures fall back to the provider", func(t *testing.T) {
+		cache := &memoryEmbeddingCache{getErr: errors.New("read failed"), storeErr: errors.New("write failed")}
+		processor, embedded := newProcessor(cache)
+
+		embeddings, err := processor.generateEmbeddings(context.Background(), []string{"text"})
+		require.NoError(t, err)
+		assert.Equal(t, [][]float32{{4}}, embeddings)
+		assert.Equal(t, []string{"text"}, *embedded)
+	})
+
+	t.Run("without a cache every chunk is embedded", func(t *testing.T) {
+		processor, embedded := newProcessor(nil)
+		processor.embeddingCache = nil
+
+		_, err := processor.generateEmbeddings(context.Background(), []string{"a", "b"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, *embedded)
 	})
 }
 
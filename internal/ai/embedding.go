@@ -3,6 +3,9 @@ package ai
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strconv"
+	"time"
 )
 
 // EmbeddingProvider defines the interface for embedding generation
@@ -20,6 +23,46 @@ type EmbeddingProvider interface {
 	ValidateConfig() error
 }
 
+// EmbeddingProviderError is an error response of an embedding provider API
+type EmbeddingProviderError struct {
+	StatusCode int
+	RetryAfter time.Duration // Wait requested by the Retry-After header, 0 if absent
+	Message    string
+}
+
+func (e *EmbeddingProviderError) Error() string {
+	return e.Message
+}
+
+// Retryable reports whether the request may succeed when sent again later: the provider
+// rate limited it or was temporarily unavailable
+func (e *EmbeddingProviderError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusServiceUnavailable
+}
+
+// newEmbeddingProviderError creates the error of a failed provider response
+func newEmbeddingProviderError(resp *http.Response, message string) *EmbeddingProviderError {
+	return &EmbeddingProviderError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		Message:    message,
+	}
+}
+
+// parseRetryAfter parses a Retry-After header, given in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0)
+	}
+	return 0
+}
+
 // EmbeddingRequest represents a request to generate embeddings
 type EmbeddingRequest struct {
 	Texts []string `json:"texts"`
@@ -8,6 +8,8 @@ import (
 	"io"
 	"net/http"
 	"strings"
+
+	"github.com/nimbleflux/fluxbase/internal/egress"
 )
 
 // azureEmbeddingProvider implements EmbeddingProvider for Azure OpenAI
@@ -21,10 +23,8 @@ func newAzureEmbeddingProviderInternal(config AzureConfig) (*azureEmbeddingProvi
 	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
 
 	return &azureEmbeddingProvider{
-		config: config,
-		httpClient: &http.Client{
-			Timeout: embeddingTimeout,
-		},
+		config:     config,
+		httpClient: egress.NewHTTPClient(egress.SourceAI, embeddingTimeout),
 	}, nil
 }
 
@@ -84,10 +84,10 @@ func (p *azureEmbeddingProvider) Embed(ctx context.Context, texts []string, mode
 			} `json:"error"`
 		}
 		if err := json.Unmarshal(respBody, &errResp); err == nil && errResp.Error.Message != "" {
-			return nil, fmt.Errorf("azure embedding error: %s (type: %s, code: %s)",
-				errResp.Error.Message, errResp.Error.Type, errResp.Error.Code)
+			return nil, newEmbeddingProviderError(resp, fmt.Sprintf("azure embedding error: %s (type: %s, code: %s)",
+				errResp.Error.Message, errResp.Error.Type, errResp.Error.Code))
 		}
-		return nil, fmt.Errorf("azure embedding returned status %d: %s", resp.StatusCode, string(respBody))
+		return nil, newEmbeddingProviderError(resp, fmt.Sprintf("azure embedding returned status %d: %s", resp.StatusCode, string(respBody)))
 	}
 
 	// Parse response (same format as OpenAI)
@@ -0,0 +1,335 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+// Defaults for the embeddings API and batched embedding
+const (
+	DefaultEmbeddingBatchSize      = 100
+	DefaultEmbeddingBatchMaxTokens = 100000
+	DefaultEmbeddingConcurrency    = 4
+	DefaultEmbeddingMaxInputs      = 2048
+)
+
+// Backoff between retries of a rate limited batch. A provider asking to wait longer than
+// embeddingRetryMaxDelay fails the batch instead.
+var (
+	embeddingRetryBaseDelay = 500 * time.Millisecond
+	embeddingRetryMaxDelay  = 30 * time.Second
+)
+
+// EmbeddingBatchOptions controls how EmbedInBatches splits and sends texts. Zero sizes and
+// concurrency use the defaults.
+type EmbeddingBatchOptions struct {
+	BatchSize   int // Max texts per provider call
+	MaxTokens   int // Max estimated tokens per provider call
+	Concurrency int // Provider calls in flight at once
+	MaxRetries  int // Retries of a call the provider rate limited or was unavailable for
+}
+
+// EmbeddingBatchOptionsFromConfig returns the batch options of the AI configuration
+func EmbeddingBatchOptionsFromConfig(cfg *config.AIConfig) EmbeddingBatchOptions {
+	return EmbeddingBatchOptions{
+		BatchSize:   cfg.EmbeddingBatchSize,
+		MaxTokens:   cfg.EmbeddingBatchMaxTokens,
+		Concurrency: cfg.EmbeddingConcurrency,
+		MaxRetries:  cfg.EmbeddingMaxRetries,
+	}
+}
+
+// withDefaults fills in unset options
+func (o EmbeddingBatchOptions) withDefaults() EmbeddingBatchOptions {
+	if o.BatchSize <= 0 {
+		o.BatchSize = DefaultEmbeddingBatchSize
+	}
+	if o.MaxTokens <= 0 {
+		o.MaxTokens = DefaultEmbeddingBatchMaxTokens
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultEmbeddingConcurrency
+	}
+	o.MaxRetries = max(o.MaxRetries, 0)
+	return o
+}
+
+// BatchEmbeddingResult is the aggregated result of embedding texts in batches
+type BatchEmbeddingResult struct {
+	Embeddings      [][]float32
+	Model           string
+	Dimensions      int
+	Batches         int
+	Usage           EmbeddingUsage
+	TokensEstimated bool
+}
+
+// embeddingBatch is a range of texts sent in one provider call
+type embeddingBatch struct {
+	start, end int
+}
+
+// planEmbeddingBatches splits texts into consecutive batches of at most batchSize texts and
+// maxTokens estimated tokens. A text over the token budget is sent on its own.
+func planEmbeddingBatches(texts []string, batchSize, maxTokens int) []embeddingBatch {
+	var batches []embeddingBatch
+	start, tokens := 0, 0
+	for i, text := range texts {
+		textTokens := EstimateEmbeddingTokens([]string{text})
+		if i > start && (i-start >= batchSize || tokens+textTokens > maxTokens) {
+			batches = append(batches, embeddingBatch{start: start, end: i})
+			start, tokens = i, 0
+		}
+		tokens += textTokens
+	}
+	if start < len(texts) {
+		batches = append(batches, embeddingBatch{start: start, end: len(texts)})
+	}
+	return batches
+}
+
+// EmbedInBatches embeds texts in provider calls bounded by the batch size and token budget,
+// preserving input order. Up to opts.Concurrency calls run at once, and calls the provider
+// rate limits are retried with backoff. When a provider does not report usage, token counts
+// are estimated from the text length so quotas and cost tracking still apply.
+func EmbedInBatches(ctx context.Context, service *EmbeddingService, texts []string, model string, opts EmbeddingBatchOptions) (*BatchEmbeddingResult, error) {
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("no texts provided for embedding")
+	}
+	opts = opts.withDefaults()
+	batches := planEmbeddingBatches(texts, opts.BatchSize, opts.MaxTokens)
+
+	// The first failure cancels the batches still in flight
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	responses := make([]*EmbeddingResponse, len(batches))
+	var firstErr error
+	var errOnce sync.Once
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(opts.Concurrency, len(batches)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				b := batches[i]
+				resp, err := embedBatchWithRetry(ctx, service, texts[b.start:b.end], model, opts.MaxRetries)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = fmt.Errorf("batch %d failed: %w", i+1, err)
+						cancel()
+					})
+					continue
+				}
+				responses[i] = resp
+			}
+		}()
+	}
+
+dispatch:
+	for i := range batches {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &BatchEmbeddingResult{
+		Embeddings: make([][]float32, 0, len(texts)),
+		Batches:    len(batches),
+	}
+	for i, resp := range responses {
+		result.Embeddings = append(result.Embeddings, resp.Embeddings...)
+		result.Model = resp.Model
+		result.Dimensions = resp.Dimensions
+
+		if resp.Usage != nil {
+			result.Usage.PromptTokens += resp.Usage.PromptTokens
+			result.Usage.TotalTokens += resp.Usage.TotalTokens
+		} else {
+			b := batches[i]
+			estimated := EstimateEmbeddingTokens(texts[b.start:b.end])
+			result.Usage.PromptTokens += estimated
+			result.Usage.TotalTokens += estimated
+			result.TokensEstimated = true
+		}
+	}
+
+	return result, nil
+}
+
+// embedBatchWithRetry embeds one batch, retrying when the provider rate limits it
+func embedBatchWithRetry(ctx context.Context, service *EmbeddingService, batch []string, model string, maxRetries int) (*EmbeddingResponse, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := service.Embed(ctx, batch, model)
+		if err == nil {
+			return resp, nil
+		}
+
+		delay, retryable := embeddingRetryDelay(err, attempt)
+		if !retryable || attempt >= maxRetries {
+			return nil, err
+		}
+		log.Debug().Err(err).Int("attempt", attempt+1).Dur("delay", delay).Msg("Embedding provider is rate limiting, retrying batch")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// embeddingRetryDelay returns how long to wait before retrying after err, and whether the
+// error is worth retrying. The exponential backoff is jittered so concurrent batches do not
+// retry in lockstep, and a longer Retry-After from the provider is honored.
+func embeddingRetryDelay(err error, attempt int) (time.Duration, bool) {
+	var providerErr *EmbeddingProviderError
+	if !errors.As(err, &providerErr) || !providerErr.Retryable() {
+		return 0, false
+	}
+	if providerErr.RetryAfter > embeddingRetryMaxDelay {
+		return 0, false
+	}
+
+	backoff := embeddingRetryBaseDelay << attempt
+	if backoff <= 0 || backoff > embeddingRetryMaxDelay {
+		backoff = embeddingRetryMaxDelay
+	}
+	delay := backoff/2 + rand.N(backoff/2+1)
+	if providerErr.RetryAfter > delay {
+		delay = providerErr.RetryAfter + rand.N(embeddingRetryBaseDelay)
+	}
+	return delay, true
+}
+
+// EstimateEmbeddingTokens approximates the token count of texts (~4 characters per token)
+func EstimateEmbeddingTokens(texts []string) int {
+	total := 0
+	for _, text := range texts {
+		if text == "" {
+			continue
+		}
+		total += (len(text) + 3) / 4
+	}
+	return total
+}
+
+// ============================================================================
+// EMBEDDING USAGE OPERATIONS
+// ============================================================================
+
+// EmbeddingUsageRecord is a metered embeddings API request
+type EmbeddingUsageRecord struct {
+	ID              string    `json:"id"`
+	UserID          *string   `json:"user_id,omitempty"`
+	ClientKeyID     *string   `json:"client_key_id,omitempty"`
+	Source          string    `json:"source"`
+	Provider        *string   `json:"provider,omitempty"`
+	Model           string    `json:"model"`
+	InputCount      int       `json:"input_count"`
+	BatchCount      int       `json:"batch_count"`
+	PromptTokens    int       `json:"prompt_tokens"`
+	TotalTokens     int       `json:"total_tokens"`
+	TokensEstimated bool      `json:"tokens_estimated"`
+	DurationMs      *int      `json:"duration_ms,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// EmbeddingUsageSummary aggregates embedding usage per day and model
+type EmbeddingUsageSummary struct {
+	Date         string `json:"date"`
+	Model        string `json:"model"`
+	Requests     int    `json:"requests"`
+	Inputs       int    `json:"inputs"`
+	PromptTokens int    `json:"prompt_tokens"`
+	TotalTokens  int    `json:"total_tokens"`
+}
+
+// RecordEmbeddingUsage stores a metered embeddings API request
+func (s *Storage) RecordEmbeddingUsage(ctx context.Context, record *EmbeddingUsageRecord) error {
+	if record.Source == "" {
+		record.Source = "api"
+	}
+
+	query := `
+		INSERT INTO ai.embedding_usage (
+			user_id, client_key_id, source, provider, model, input_count, batch_count,
+			prompt_tokens, total_tokens, tokens_estimated, duration_ms
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at
+	`
+
+	err := s.db.QueryRow(ctx, query,
+		record.UserID, record.ClientKeyID, record.Source, record.Provider, record.Model, record.InputCount, record.BatchCount,
+		record.PromptTokens, record.TotalTokens, record.TokensEstimated, record.DurationMs,
+	).Scan(&record.ID, &record.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record embedding usage: %w", err)
+	}
+	return nil
+}
+
+// GetEmbeddingTokensUsedSince returns the total embedding tokens a user has used since the given time
+func (s *Storage) GetEmbeddingTokensUsedSince(ctx context.Context, userID string, since time.Time) (int, error) {
+	var total int
+	err := s.db.QueryRow(ctx, `
+		SELECT COALESCE(SUM(total_tokens), 0)
+		FROM ai.embedding_usage
+		WHERE user_id = $1 AND created_at >= $2
+	`, userID, since).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get embedding usage: %w", err)
+	}
+	return total, nil
+}
+
+// GetEmbeddingUsageSummary returns embedding usage per day and model, optionally for a single user
+func (s *Storage) GetEmbeddingUsageSummary(ctx context.Context, userID *string, since time.Time) ([]EmbeddingUsageSummary, error) {
+	query := `
+		SELECT to_char(date_trunc('day', created_at), 'YYYY-MM-DD') AS day, model,
+			COUNT(*), COALESCE(SUM(input_count), 0), COALESCE(SUM(prompt_tokens), 0), COALESCE(SUM(total_tokens), 0)
+		FROM ai.embedding_usage
+		WHERE created_at >= $1 AND ($2::uuid IS NULL OR user_id = $2::uuid)
+		GROUP BY day, model
+		ORDER BY day DESC, model
+	`
+
+	rows, err := s.db.Query(ctx, query, since, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embedding usage summary: %w", err)
+	}
+	defer rows.Close()
+
+	summary := make([]EmbeddingUsageSummary, 0)
+	for rows.Next() {
+		var row EmbeddingUsageSummary
+		if err := rows.Scan(&row.Date, &row.Model, &row.Requests, &row.Inputs, &row.PromptTokens, &row.TotalTokens); err != nil {
+			log.Error().Err(err).Msg("Failed to scan embedding usage summary")
+			continue
+		}
+		summary = append(summary, row)
+	}
+	return summary, nil
+}
@@ -0,0 +1,231 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbedInBatches(t *testing.T) {
+	newService := func(provider *mockEmbeddingProvider) *EmbeddingService {
+		return &EmbeddingService{
+			provider:     provider,
+			defaultModel: "test-model",
+			cacheResults: make(map[string]*cachedEmbedding),
+		}
+	}
+
+	texts := make([]string, 7)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("text %d", i)
+	}
+
+	t.Run("splits into batches and preserves order", func(t *testing.T) {
+		var batchSizes []int
+		provider := newMockEmbeddingProvider()
+		provider.embedFunc = func(ctx context.Context, batch []string, model string) (*EmbeddingResponse, error) {
+			batchSizes = append(batchSizes, len(batch))
+			embeddings := make([][]float32, len(batch))
+			for i, text := range batch {
+				var n float32
+				_, _ = fmt.Sscanf(text, "text %f", &n)
+				embeddings[i] = []float32{n}
+			}
+			return &EmbeddingResponse{
+				Embeddings: embeddings,
+				Model:      model,
+				Dimensions: 1,
+				Usage:      &EmbeddingUsage{PromptTokens: len(batch) * 2, TotalTokens: len(batch) * 2},
+			}, nil
+		}
+
+		result, err := EmbedInBatches(context.Background(), newService(provider), texts, "test-model", EmbeddingBatchOptions{BatchSize: 3, Concurrency: 1})
+		require.NoError(t, err)
+
+		assert.Equal(t, []int{3, 3, 1}, batchSizes)
+		assert.Equal(t, 3, result.Batches)
+		require.Len(t, result.Embeddings, 7)
+		for i, embedding := range result.Embeddings {
+			assert.Equal(t, float32(i), embedding[0])
+		}
+		assert.Equal(t, 14, result.Usage.TotalTokens)
+		assert.False(t, result.TokensEstimated)
+	})
+
+	t.Run("estimates tokens when provider reports no usage", func(t *testing.T) {
+		provider := newMockEmbeddingProvider()
+		provider.embedFunc = func(ctx context.Context, batch []string, model string) (*EmbeddingResponse, error) {
+			return &EmbeddingResponse{
+				Embeddings: make([][]float32, len(batch)),
+				Model:      model,
+			}, nil
+		}
+
+		result, err := EmbedInBatches(context.Background(), newService(provider), []string{"abcdefgh"}, "", EmbeddingBatchOptions{})
+		require.NoError(t, err)
+		assert.True(t, result.TokensEstimated)
+		assert.Equal(t, 2, result.Usage.TotalTokens)
+		assert.Equal(t, "test-model", result.Model)
+	})
+
+	t.Run("fails on provider error", func(t *testing.T) {
+		provider := newMockEmbeddingProvider()
+		provider.embedFunc = func(ctx context.Context, batch []string, model string) (*EmbeddingResponse, error) {
+			return nil, errors.New("provider down")
+		}
+
+		_, err := EmbedInBatches(context.Background(), newService(provider), texts, "", EmbeddingBatchOptions{BatchSize: 5, Concurrency: 1, MaxRetries: 3})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "batch 1 failed")
+	})
+
+	t.Run("fails on mismatched embedding count", func(t *testing.T) {
+		provider := newMockEmbeddingProvider()
+		provider.embedFunc = func(ctx context.Context, batch []string, model string) (*EmbeddingResponse, error) {
+			return &EmbeddingResponse{Embeddings: make([][]float32, 1), Model: model}, nil
+		}
+
+		_, err := EmbedInBatches(context.Background(), newService(provider), texts, "", EmbeddingBatchOptions{BatchSize: 5, Concurrency: 1})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "provider returned 1 embeddings for 5 texts")
+	})
+
+	t.Run("runs batches concurrently", func(t *testing.T) {
+		var inFlight, peak atomic.Int32
+		provider := newMockEmbeddingProvider()
+		provider.embedFunc = func(ctx context.Context, batch []string, model string) (*EmbeddingResponse, error) {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			embeddings := make([][]float32, len(batch))
+			for i, text := range batch {
+				var n float32
+				_, _ = fmt.Sscanf(text, "text %f", &n)
+				embeddings[i] = []float32{n}
+			}
+			return &EmbeddingResponse{Embeddings: embeddings, Model: model, Dimensions: 1}, nil
+		}
+
+		result, err := EmbedInBatches(context.Background(), newService(provider), texts, "", EmbeddingBatchOptions{BatchSize: 1, Concurrency: 3})
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), peak.Load())
+		assert.Equal(t, 7, result.Batches)
+		for i, embedding := range result.Embeddings {
+			assert.Equal(t, float32(i), embedding[0])
+		}
+	})
+
+	t.Run("retries rate limited batches", func(t *testing.T) {
+		setFastEmbeddingRetries(t)
+		var calls atomic.Int32
+		provider := newMockEmbeddingProvider()
+		provider.embedFunc = func(ctx context.Context, batch []string, model string) (*EmbeddingResponse, error) {
+			if calls.Add(1) <= 2 {
+				return nil, &EmbeddingProviderError{StatusCode: http.StatusTooManyRequests, Message: "rate limited"}
+			}
+			return &EmbeddingResponse{Embeddings: make([][]float32, len(batch)), Model: model}, nil
+		}
+
+		result, err := EmbedInBatches(context.Background(), newService(provider), texts, "", EmbeddingBatchOptions{MaxRetries: 2})
+		require.NoError(t, err)
+		assert.Len(t, result.Embeddings, 7)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		setFastEmbeddingRetries(t)
+		var calls atomic.Int32
+		provider := newMockEmbeddingProvider()
+		provider.embedFunc = func(ctx context.Context, batch []string, model string) (*EmbeddingResponse, error) {
+			calls.Add(1)
+			return nil, &EmbeddingProviderError{StatusCode: http.StatusTooManyRequests, Message: "rate limited"}
+		}
+
+		_, err := EmbedInBatches(context.Background(), newService(provider), texts, "", EmbeddingBatchOptions{MaxRetries: 2})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "rate limited")
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("rejects empty input", func(t *testing.T) {
+		_, err := EmbedInBatches(context.Background(), newService(newMockEmbeddingProvider()), nil, "", EmbeddingBatchOptions{})
+		require.Error(t, err)
+	})
+}
+
+func TestPlanEmbeddingBatches(t *testing.T) {
+	texts := []string{
+		strings.Repeat("a", 40), // 10 tokens
+		strings.Repeat("b", 40),
+		strings.Repeat("c", 200), // 50 tokens, over the budget
+		strings.Repeat("d", 40),
+		strings.Repeat("e", 40),
+		strings.Repeat("f", 40),
+	}
+
+	assert.Equal(t, []embeddingBatch{{0, 2}, {2, 3}, {3, 5}, {5, 6}}, planEmbeddingBatches(texts, 10, 25))
+	assert.Equal(t, []embeddingBatch{{0, 2}, {2, 4}, {4, 6}}, planEmbeddingBatches(texts, 2, 1000))
+	assert.Empty(t, planEmbeddingBatches(nil, 10, 25))
+}
+
+func TestEmbeddingRetryDelay(t *testing.T) {
+	setFastEmbeddingRetries(t)
+
+	_, retry := embeddingRetryDelay(errors.New("connection reset"), 0)
+	assert.False(t, retry)
+	_, retry = embeddingRetryDelay(&EmbeddingProviderError{StatusCode: http.StatusBadRequest}, 0)
+	assert.False(t, retry)
+
+	wrapped := fmt.Errorf("embedding failed: %w", &EmbeddingProviderError{StatusCode: http.StatusTooManyRequests})
+	delay, retry := embeddingRetryDelay(wrapped, 2)
+	assert.True(t, retry)
+	assert.GreaterOrEqual(t, delay, 2*time.Millisecond)
+	assert.LessOrEqual(t, delay, 4*time.Millisecond)
+
+	delay, retry = embeddingRetryDelay(&EmbeddingProviderError{StatusCode: http.StatusServiceUnavailable, RetryAfter: 50 * time.Millisecond}, 0)
+	assert.True(t, retry)
+	assert.GreaterOrEqual(t, delay, 50*time.Millisecond)
+
+	_, retry = embeddingRetryDelay(&EmbeddingProviderError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Hour}, 0)
+	assert.False(t, retry)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, 3*time.Second, parseRetryAfter("3", now))
+	assert.Equal(t, 90*time.Second, parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now))
+	assert.Zero(t, parseRetryAfter("", now))
+	assert.Zero(t, parseRetryAfter("soon", now))
+	assert.Zero(t, parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now))
+}
+
+// setFastEmbeddingRetries shortens the retry backoff for the duration of a test
+func setFastEmbeddingRetries(t *testing.T) {
+	t.Helper()
+	base, maxDelay := embeddingRetryBaseDelay, embeddingRetryMaxDelay
+	embeddingRetryBaseDelay, embeddingRetryMaxDelay = time.Millisecond, 100*time.Millisecond
+	t.Cleanup(func() {
+		embeddingRetryBaseDelay, embeddingRetryMaxDelay = base, maxDelay
+	})
+}
+
+func TestEstimateEmbeddingTokens(t *testing.T) {
+	assert.Equal(t, 0, EstimateEmbeddingTokens(nil))
+	assert.Equal(t, 0, EstimateEmbeddingTokens([]string{""}))
+	assert.Equal(t, 1, EstimateEmbeddingTokens([]string{"abc"}))
+	assert.Equal(t, 3, EstimateEmbeddingTokens([]string{"abcd", "abcde"}))
+}
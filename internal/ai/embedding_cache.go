@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// embeddingCacheStore persists embeddings by model and content hash, so that re-ingesting
+// unchanged content reuses them instead of calling the embedding provider
+type embeddingCacheStore interface {
+	GetCachedEmbeddings(ctx context.Context, model string, hashes []string) (map[string][]float32, error)
+	StoreCachedEmbeddings(ctx context.Context, model string, embeddings map[string][]float32) error
+}
+
+// GetCachedEmbeddings returns the cached embeddings of a model by content hash (hex SHA-256 of
+// the embedded text). Hashes without a cached embedding are left out of the result.
+func (s *KnowledgeBaseStorage) GetCachedEmbeddings(ctx context.Context, model string, hashes []string) (map[string][]float32, error) {
+	if len(hashes) == 0 {
+		return map[string][]float32{}, nil
+	}
+
+	rows, err := s.db.Pool().Query(ctx, `
+		UPDATE ai.embedding_cache
+		SET last_used_at = NOW()
+		WHERE model = $1 AND content_sha256 = ANY($2)
+		RETURNING content_sha256, embedding::text
+	`, model, hashes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	embeddings := make(map[string][]float32)
+	for rows.Next() {
+		var hash, literal string
+		if err := rows.Scan(&hash, &literal); err != nil {
+			return nil, fmt.Errorf("failed to scan cached embedding: %w", err)
+		}
+		embedding, err := parseEmbeddingLiteral(literal)
+		if err != nil {
+			return nil, err
+		}
+		embeddings[hash] = embedding
+	}
+	return embeddings, rows.Err()
+}
+
+// StoreCachedEmbeddings caches embeddings of a model by content hash
+func (s *KnowledgeBaseStorage) StoreCachedEmbeddings(ctx context.Context, model string, embeddings map[string][]float32) error {
+	if len(embeddings) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for hash, embedding := range embeddings {
+		batch.Queue(`
+			INSERT INTO ai.embedding_cache (model, content_sha256, embedding)
+			VALUES ($1, $2, $3::vector)
+			ON CONFLICT (model, content_sha256) DO UPDATE SET
+				embedding = EXCLUDED.embedding,
+				last_used_at = NOW()
+		`, model, hash, formatEmbeddingLiteral(embedding))
+	}
+
+	br := s.db.Pool().SendBatch(ctx, batch)
+	defer func() { _ = br.Close() }()
+
+	for range embeddings {
+		if _, err := br.Exec(); err != nil {
+			return fmt.Errorf("failed to cache embedding: %w", err)
+		}
+	}
+	return nil
+}
@@ -8,6 +8,8 @@ import (
 	"io"
 	"net/http"
 	"strings"
+
+	"github.com/nimbleflux/fluxbase/internal/egress"
 )
 
 // ollamaEmbeddingProvider implements EmbeddingProvider for Ollama
@@ -21,10 +23,8 @@ func newOllamaEmbeddingProviderInternal(config OllamaConfig) (*ollamaEmbeddingPr
 	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
 
 	return &ollamaEmbeddingProvider{
-		config: config,
-		httpClient: &http.Client{
-			Timeout: embeddingTimeout,
-		},
+		config:     config,
+		httpClient: egress.NewHTTPClient(egress.SourceAI, embeddingTimeout),
 	}, nil
 }
 
@@ -109,9 +109,9 @@ func (p *ollamaEmbeddingProvider) embedSingle(ctx context.Context, text, model s
 			Error string `json:"error"`
 		}
 		if err := json.Unmarshal(respBody, &errResp); err == nil && errResp.Error != "" {
-			return nil, 0, fmt.Errorf("ollama embedding error: %s", errResp.Error)
+			return nil, 0, newEmbeddingProviderError(resp, fmt.Sprintf("ollama embedding error: %s", errResp.Error))
 		}
-		return nil, 0, fmt.Errorf("ollama embedding returned status %d: %s", resp.StatusCode, string(respBody))
+		return nil, 0, newEmbeddingProviderError(resp, fmt.Sprintf("ollama embedding returned status %d: %s", resp.StatusCode, string(respBody)))
 	}
 
 	// Parse response
@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/nimbleflux/fluxbase/internal/egress"
 )
 
 const (
@@ -30,10 +32,8 @@ func newOpenAIEmbeddingProviderInternal(config OpenAIConfig) (*openAIEmbeddingPr
 	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
 
 	return &openAIEmbeddingProvider{
-		config: config,
-		httpClient: &http.Client{
-			Timeout: embeddingTimeout,
-		},
+		config:     config,
+		httpClient: egress.NewHTTPClient(egress.SourceAI, embeddingTimeout),
 	}, nil
 }
 
@@ -93,10 +93,10 @@ func (p *openAIEmbeddingProvider) Embed(ctx context.Context, texts []string, mod
 			} `json:"error"`
 		}
 		if err := json.Unmarshal(respBody, &errResp); err == nil && errResp.Error.Message != "" {
-			return nil, fmt.Errorf("openai embedding error: %s (type: %s, code: %s)",
-				errResp.Error.Message, errResp.Error.Type, errResp.Error.Code)
+			return nil, newEmbeddingProviderError(resp, fmt.Sprintf("openai embedding error: %s (type: %s, code: %s)",
+				errResp.Error.Message, errResp.Error.Type, errResp.Error.Code))
 		}
-		return nil, fmt.Errorf("openai embedding returned status %d: %s", resp.StatusCode, string(respBody))
+		return nil, newEmbeddingProviderError(resp, fmt.Sprintf("openai embedding returned status %d: %s", resp.StatusCode, string(respBody)))
 	}
 
 	// Parse response
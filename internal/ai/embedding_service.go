@@ -2,16 +2,24 @@ package ai
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
+	"math"
 	"sync"
 	"time"
 
+	"github.com/nimbleflux/fluxbase/internal/cache"
+	"github.com/nimbleflux/fluxbase/internal/observability"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // EmbeddingService coordinates embedding generation using configured providers
 type EmbeddingService struct {
 	provider     EmbeddingProvider
+	providerType ProviderType
 	providerMu   sync.RWMutex
 	defaultModel string
 	rateLimiter  *embeddingRateLimiter
@@ -19,6 +27,8 @@ type EmbeddingService struct {
 	cacheResults map[string]*cachedEmbedding
 	cacheMu      sync.RWMutex
 	cacheTTL     time.Duration
+	sharedCache  cache.Cache            // optional, shares embeddings across instances
+	metrics      *observability.Metrics // optional
 }
 
 // EmbeddingServiceConfig contains configuration for the embedding service
@@ -75,6 +85,7 @@ func NewEmbeddingService(cfg EmbeddingServiceConfig) (*EmbeddingService, error)
 
 	service := &EmbeddingService{
 		provider:     provider,
+		providerType: cfg.Provider.Type,
 		defaultModel: defaultModel,
 		rateLimiter:  rateLimiter,
 		cacheEnabled: cfg.CacheEnabled,
@@ -116,7 +127,7 @@ func (s *EmbeddingService) Embed(ctx context.Context, texts []string, model stri
 	if s.cacheEnabled {
 		for i, text := range texts {
 			cacheKey := s.cacheKey(text, model)
-			if cached := s.getFromCache(cacheKey); cached != nil {
+			if cached := s.getCached(ctx, cacheKey); cached != nil {
 				embeddings[i] = cached
 			} else {
 				uncachedTexts = append(uncachedTexts, text)
@@ -141,13 +152,28 @@ func (s *EmbeddingService) Embed(ctx context.Context, texts []string, model stri
 
 	// Get embeddings from provider
 	s.providerMu.RLock()
-	provider := s.provider
+	provider, providerType := s.provider, s.providerType
 	s.providerMu.RUnlock()
 
-	resp, err := provider.Embed(ctx, uncachedTexts, model)
+	spanCtx, span := observability.StartAISpan(ctx, "embed",
+		attribute.String("ai.provider", string(providerType)),
+		attribute.String("ai.model", model),
+		attribute.Int("ai.inputs", len(uncachedTexts)),
+		attribute.Int("ai.cached_inputs", len(texts)-len(uncachedTexts)),
+	)
+	start := time.Now()
+	resp, err := provider.Embed(spanCtx, uncachedTexts, model)
+	s.recordProviderRequest(providerType, model, uncachedTexts, resp, err, time.Since(start))
+	if err == nil && resp.Usage != nil {
+		span.SetAttributes(attribute.Int("ai.prompt_tokens", resp.Usage.PromptTokens))
+	}
+	observability.EndAISpan(span, err)
 	if err != nil {
 		return nil, fmt.Errorf("embedding failed: %w", err)
 	}
+	if len(resp.Embeddings) != len(uncachedTexts) {
+		return nil, fmt.Errorf("embedding failed: provider returned %d embeddings for %d texts", len(resp.Embeddings), len(uncachedTexts))
+	}
 
 	// Merge cached and new embeddings
 	for i, idx := range uncachedIndices {
@@ -156,7 +182,7 @@ func (s *EmbeddingService) Embed(ctx context.Context, texts []string, model stri
 		// Cache the new embedding
 		if s.cacheEnabled {
 			cacheKey := s.cacheKey(uncachedTexts[i], model)
-			s.addToCache(cacheKey, resp.Embeddings[i])
+			s.storeCached(ctx, cacheKey, resp.Embeddings[i])
 		}
 	}
 
@@ -207,6 +233,7 @@ func (s *EmbeddingService) SetProvider(cfg ProviderConfig) error {
 
 	s.providerMu.Lock()
 	s.provider = provider
+	s.providerType = cfg.Type
 	s.providerMu.Unlock()
 
 	return nil
@@ -248,6 +275,112 @@ func (s *EmbeddingService) addToCache(key string, embedding []float32) {
 	}
 }
 
+// SetMetrics sets the metrics that record embedding provider requests and token usage
+func (s *EmbeddingService) SetMetrics(metrics *observability.Metrics) {
+	s.metrics = metrics
+}
+
+// recordProviderRequest records a request to the embedding provider and the tokens it used,
+// estimating them when the provider doesn't report usage
+func (s *EmbeddingService) recordProviderRequest(providerType ProviderType, model string, texts []string, resp *EmbeddingResponse, err error, duration time.Duration) {
+	if s.metrics == nil {
+		return
+	}
+	if err != nil {
+		s.metrics.RecordAIProviderRequest(string(providerType), "error", duration)
+		return
+	}
+	s.metrics.RecordAIProviderRequest(string(providerType), "success", duration)
+
+	tokens := EstimateEmbeddingTokens(texts)
+	if resp.Usage != nil {
+		tokens = resp.Usage.PromptTokens
+	}
+	s.metrics.RecordAIEmbeddingTokens(string(providerType), model, tokens)
+}
+
+// SetCache sets a shared cache that embeddings are stored in alongside the local cache,
+// so instances reuse each other's results. Has no effect unless caching is enabled.
+func (s *EmbeddingService) SetCache(c cache.Cache) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	s.sharedCache = c
+}
+
+// sharedCacheKey keeps shared cache keys short regardless of the text length
+func sharedCacheKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return "embedding:" + hex.EncodeToString(hash[:])
+}
+
+// getCached retrieves an embedding from the local cache, then from the shared cache
+func (s *EmbeddingService) getCached(ctx context.Context, key string) []float32 {
+	if embedding := s.getFromCache(key); embedding != nil {
+		return embedding
+	}
+
+	s.cacheMu.RLock()
+	shared := s.sharedCache
+	s.cacheMu.RUnlock()
+	if shared == nil {
+		return nil
+	}
+
+	data, ok, err := shared.Get(ctx, sharedCacheKey(key))
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to read embedding from shared cache")
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+	embedding, err := decodeEmbedding(data)
+	if err != nil {
+		log.Warn().Err(err).Msg("Ignoring invalid embedding in shared cache")
+		return nil
+	}
+
+	s.addToCache(key, embedding)
+	return embedding
+}
+
+// storeCached adds an embedding to the local cache and the shared cache
+func (s *EmbeddingService) storeCached(ctx context.Context, key string, embedding []float32) {
+	s.addToCache(key, embedding)
+
+	s.cacheMu.RLock()
+	shared := s.sharedCache
+	s.cacheMu.RUnlock()
+	if shared == nil {
+		return
+	}
+
+	if err := shared.Set(ctx, sharedCacheKey(key), encodeEmbedding(embedding), s.cacheTTL); err != nil {
+		log.Warn().Err(err).Msg("Failed to write embedding to shared cache")
+	}
+}
+
+// encodeEmbedding serializes an embedding as little-endian float32s
+func encodeEmbedding(embedding []float32) []byte {
+	data := make([]byte, 4*len(embedding))
+	for i, v := range embedding {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(v))
+	}
+	return data
+}
+
+// decodeEmbedding is the inverse of encodeEmbedding
+func decodeEmbedding(data []byte) ([]float32, error) {
+	if len(data)%4 != 0 {
+		return nil, fmt.Errorf("embedding data length %d is not a multiple of 4", len(data))
+	}
+	embedding := make([]float32, len(data)/4)
+	for i := range embedding {
+		embedding[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return embedding, nil
+}
+
 // cleanupCache periodically removes expired cache entries
 func (s *EmbeddingService) cleanupCache() {
 	ticker := time.NewTicker(5 * time.Minute)
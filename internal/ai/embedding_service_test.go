@@ -2,9 +2,12 @@ package ai
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"testing"
 	"time"
 
+	"github.com/nimbleflux/fluxbase/internal/cache"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -267,6 +270,44 @@ func TestEmbeddingService_Embed(t *testing.T) {
 		// First embedding should be from cache
 		assert.Equal(t, float32(0.999), resp.Embeddings[0][0])
 	})
+
+	t.Run("shares embeddings through the shared cache", func(t *testing.T) {
+		shared := cache.NewMemoryCache(time.Minute)
+		defer func() { _ = shared.Close() }()
+
+		first := createTestService()
+		first.cacheEnabled = true
+		first.cacheTTL = 15 * time.Minute
+		first.SetCache(shared)
+
+		ctx := context.Background()
+		resp, err := first.Embed(ctx, []string{"shared text"}, "test-model")
+		require.NoError(t, err)
+
+		// A second instance reads the embedding from the shared cache instead of the provider
+		second := createTestService()
+		second.cacheEnabled = true
+		second.cacheTTL = 15 * time.Minute
+		second.SetCache(shared)
+		second.provider.(*mockEmbeddingProvider).embedFunc = func(ctx context.Context, texts []string, model string) (*EmbeddingResponse, error) {
+			return nil, fmt.Errorf("provider should not be called")
+		}
+
+		cached, err := second.Embed(ctx, []string{"shared text"}, "test-model")
+		require.NoError(t, err)
+		assert.Equal(t, resp.Embeddings[0], cached.Embeddings[0])
+	})
+}
+
+func TestEmbeddingEncoding(t *testing.T) {
+	embedding := []float32{0, 1.5, -0.25, float32(math.Pi)}
+
+	decoded, err := decodeEmbedding(encodeEmbedding(embedding))
+	require.NoError(t, err)
+	assert.Equal(t, embedding, decoded)
+
+	_, err = decodeEmbedding([]byte{1, 2, 3})
+	assert.Error(t, err)
 }
 
 func TestEmbeddingService_EmbedSingle(t *testing.T) {
@@ -0,0 +1,264 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nimbleflux/fluxbase/internal/email"
+	"github.com/rs/zerolog/log"
+)
+
+// EscalationReason describes why a conversation was handed off to a human
+type EscalationReason string
+
+const (
+	// EscalationReasonUserRequest - the user explicitly asked for a human
+	EscalationReasonUserRequest EscalationReason = "user_request"
+	// EscalationReasonLowConfidence - retrieval grounding was below the chatbot's threshold
+	EscalationReasonLowConfidence EscalationReason = "low_confidence"
+)
+
+// EscalationStatus is the lifecycle state of an escalation
+type EscalationStatus string
+
+const (
+	// EscalationStatusOpen - waiting for a human to pick it up
+	EscalationStatusOpen EscalationStatus = "open"
+	// EscalationStatusAcknowledged - a human is handling the conversation
+	EscalationStatusAcknowledged EscalationStatus = "acknowledged"
+	// EscalationStatusResolved - handed back to the chatbot
+	EscalationStatusResolved EscalationStatus = "resolved"
+)
+
+// DefaultEscalationMessage is sent to the user while a conversation is escalated
+const DefaultEscalationMessage = "I've passed this conversation on to a member of our team. Someone will get back to you as soon as possible."
+
+// Escalation represents a conversation handed off to a human
+type Escalation struct {
+	ID                 string             `json:"id"`
+	ConversationID     string             `json:"conversation_id"`
+	ChatbotID          string             `json:"chatbot_id"`
+	ChatbotName        *string            `json:"chatbot_name,omitempty"`
+	UserID             *string            `json:"user_id,omitempty"`
+	Reason             EscalationReason   `json:"reason"`
+	TriggerMessage     *string            `json:"trigger_message,omitempty"`
+	BestSimilarity     *float64           `json:"best_similarity,omitempty"`
+	Transcript         []EscalationRecord `json:"transcript"`
+	Status             EscalationStatus   `json:"status"`
+	Notes              *string            `json:"notes,omitempty"`
+	WebhookDeliveredAt *time.Time         `json:"webhook_delivered_at,omitempty"`
+	EmailDeliveredAt   *time.Time         `json:"email_delivered_at,omitempty"`
+	NotificationError  *string            `json:"notification_error,omitempty"`
+	ResolvedBy         *string            `json:"resolved_by,omitempty"`
+	ResolvedAt         *time.Time         `json:"resolved_at,omitempty"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+}
+
+// EscalationRecord is a single transcript entry attached to an escalation
+type EscalationRecord struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// EscalationWebhookPayload is the JSON body posted to a chatbot's escalation webhook
+type EscalationWebhookPayload struct {
+	Event          string             `json:"event"`
+	EscalationID   string             `json:"escalation_id"`
+	ConversationID string             `json:"conversation_id"`
+	ChatbotID      string             `json:"chatbot_id"`
+	ChatbotName    string             `json:"chatbot_name"`
+	UserID         *string            `json:"user_id,omitempty"`
+	Reason         EscalationReason   `json:"reason"`
+	BestSimilarity *float64           `json:"best_similarity,omitempty"`
+	Transcript     []EscalationRecord `json:"transcript"`
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
+// EscalationEmailSender sends plain email notifications (satisfied by email.Service)
+type EscalationEmailSender interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// humanRequestPattern matches common ways of asking to talk to a person
+var humanRequestPattern = regexp.MustCompile(`(?i)\b(` +
+	`(talk|speak|chat)\s+(to|with)\s+(a\s+|an\s+|some\s*one|somebody|your\s+)?(human|person|real\s+person|agent|representative|operator|someone|somebody|support(\s+team)?|staff)` +
+	`|human\s+(agent|support|operator|being)` +
+	`|real\s+(person|human)` +
+	`|live\s+(agent|person|support|chat)` +
+	`|customer\s+service\s+(agent|representative)` +
+	`|escalate` +
+	`)\b`)
+
+// IsHumanRequest returns true if the message asks to be handed off to a human
+func IsHumanRequest(content string) bool {
+	return humanRequestPattern.MatchString(content)
+}
+
+// ShouldEscalateForConfidence returns true if the best retrieval similarity is
+// below the chatbot's escalation threshold. A threshold of 0 disables the check.
+func ShouldEscalateForConfidence(chatbot *Chatbot, bestSimilarity float64) bool {
+	if chatbot == nil || !chatbot.HasEscalation() || chatbot.EscalationThreshold <= 0 {
+		return false
+	}
+	return bestSimilarity < chatbot.EscalationThreshold
+}
+
+// EscalationHoldingMessage returns the message shown to users while a conversation is escalated
+func EscalationHoldingMessage(chatbot *Chatbot) string {
+	if chatbot != nil && chatbot.EscalationMessage != "" {
+		return chatbot.EscalationMessage
+	}
+	return DefaultEscalationMessage
+}
+
+// BuildEscalationTranscript converts conversation messages into transcript records,
+// skipping system and tool messages which are not useful to a human agent
+func BuildEscalationTranscript(messages []Message) []EscalationRecord {
+	transcript := make([]EscalationRecord, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Role != RoleUser && msg.Role != RoleAssistant {
+			continue
+		}
+		if strings.TrimSpace(msg.Content) == "" {
+			continue
+		}
+		transcript = append(transcript, EscalationRecord{
+			Role:    string(msg.Role),
+			Content: msg.Content,
+		})
+	}
+	return transcript
+}
+
+// EscalationNotifier delivers escalation notifications to webhooks and email
+type EscalationNotifier struct {
+	storage    *Storage
+	email      EscalationEmailSender
+	httpClient *http.Client
+}
+
+// NewEscalationNotifier creates a new escalation notifier
+func NewEscalationNotifier(storage *Storage, email EscalationEmailSender) *EscalationNotifier {
+	return &EscalationNotifier{
+		storage:    storage,
+		email:      email,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify sends the configured webhook and email notifications for an escalation
+// and records the delivery outcome
+func (n *EscalationNotifier) Notify(ctx context.Context, chatbot *Chatbot, escalation *Escalation) {
+	var webhookAt, emailAt *time.Time
+	var errs []string
+
+	if chatbot.EscalationWebhookURL != "" {
+		if err := n.sendWebhook(ctx, chatbot, escalation); err != nil {
+			log.Warn().Err(err).Str("escalation_id", escalation.ID).Msg("Failed to deliver escalation webhook")
+			errs = append(errs, "webhook: "+err.Error())
+		} else {
+			now := time.Now()
+			webhookAt = &now
+		}
+	}
+
+	if chatbot.EscalationEmail != "" {
+		if n.email == nil {
+			errs = append(errs, "email: email service not configured")
+		} else if err := n.sendEmail(ctx, chatbot, escalation); err != nil {
+			log.Warn().Err(err).Str("escalation_id", escalation.ID).Msg("Failed to send escalation email")
+			errs = append(errs, "email: "+err.Error())
+		} else {
+			now := time.Now()
+			emailAt = &now
+		}
+	}
+
+	if n.storage == nil {
+		return
+	}
+
+	var notificationError *string
+	if len(errs) > 0 {
+		msg := strings.Join(errs, "; ")
+		notificationError = &msg
+	}
+	if err := n.storage.RecordEscalationDelivery(ctx, escalation.ID, webhookAt, emailAt, notificationError); err != nil {
+		log.Warn().Err(err).Str("escalation_id", escalation.ID).Msg("Failed to record escalation delivery")
+	}
+}
+
+// sendWebhook posts the escalation payload to the chatbot's webhook
+func (n *EscalationNotifier) sendWebhook(ctx context.Context, chatbot *Chatbot, escalation *Escalation) error {
+	payload := EscalationWebhookPayload{
+		Event:          "conversation.escalated",
+		EscalationID:   escalation.ID,
+		ConversationID: escalation.ConversationID,
+		ChatbotID:      chatbot.ID,
+		ChatbotName:    chatbot.Name,
+		UserID:         escalation.UserID,
+		Reason:         escalation.Reason,
+		BestSimilarity: escalation.BestSimilarity,
+		Transcript:     escalation.Transcript,
+		CreatedAt:      escalation.CreatedAt,
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, chatbot.EscalationWebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Fluxbase-Escalation/1.0")
+	req.Header.Set("X-Fluxbase-Event", payload.Event)
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sendEmail emails the escalation transcript to the chatbot's escalation address
+func (n *EscalationNotifier) sendEmail(ctx context.Context, chatbot *Chatbot, escalation *Escalation) error {
+	subject := fmt.Sprintf("[%s] Conversation escalated to a human", chatbot.Name)
+	ctx = email.WithNamespace(ctx, chatbot.Namespace)
+	return n.email.Send(ctx, chatbot.EscalationEmail, subject, FormatEscalationEmail(chatbot, escalation))
+}
+
+// FormatEscalationEmail renders the plain-text email body for an escalation
+func FormatEscalationEmail(chatbot *Chatbot, escalation *Escalation) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Chatbot: %s\n", chatbot.Name)
+	fmt.Fprintf(&sb, "Conversation: %s\n", escalation.ConversationID)
+	fmt.Fprintf(&sb, "Escalation: %s\n", escalation.ID)
+	fmt.Fprintf(&sb, "Reason: %s\n", escalation.Reason)
+	if escalation.UserID != nil {
+		fmt.Fprintf(&sb, "User: %s\n", *escalation.UserID)
+	}
+	if escalation.BestSimilarity != nil {
+		fmt.Fprintf(&sb, "Best similarity: %.2f\n", *escalation.BestSimilarity)
+	}
+
+	sb.WriteString("\nTranscript:\n\n")
+	for _, rec := range escalation.Transcript {
+		fmt.Fprintf(&sb, "%s: %s\n\n", rec.Role, rec.Content)
+	}
+	return sb.String()
+}
@@ -0,0 +1,173 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEscalationEmailSender struct {
+	to      string
+	subject string
+	body    string
+}
+
+func (f *fakeEscalationEmailSender) Send(ctx context.Context, to, subject, body string) error {
+	f.to = to
+	f.subject = subject
+	f.body = body
+	return nil
+}
+
+func TestIsHumanRequest(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected bool
+	}{
+		{"Can I talk to a human please?", true},
+		{"I want to speak with someone", true},
+		{"let me chat with a real person", true},
+		{"Is there a live agent available?", true},
+		{"please escalate this", true},
+		{"SPEAK TO AN AGENT", true},
+		{"What are your opening hours?", false},
+		{"How do humans use this product?", false},
+		{"Tell me about your support plans", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsHumanRequest(tt.message))
+		})
+	}
+}
+
+func TestShouldEscalateForConfidence(t *testing.T) {
+	chatbot := &Chatbot{EscalationEmail: "support@example.com", EscalationThreshold: 0.6}
+
+	assert.True(t, ShouldEscalateForConfidence(chatbot, 0.4))
+	assert.False(t, ShouldEscalateForConfidence(chatbot, 0.6))
+	assert.False(t, ShouldEscalateForConfidence(chatbot, 0.9))
+
+	t.Run("disabled without threshold", func(t *testing.T) {
+		assert.False(t, ShouldEscalateForConfidence(&Chatbot{EscalationEmail: "support@example.com"}, 0.1))
+	})
+
+	t.Run("disabled without target", func(t *testing.T) {
+		assert.False(t, ShouldEscalateForConfidence(&Chatbot{EscalationThreshold: 0.6}, 0.1))
+	})
+
+	t.Run("nil chatbot", func(t *testing.T) {
+		assert.False(t, ShouldEscalateForConfidence(nil, 0.1))
+	})
+}
+
+func TestEscalationHoldingMessage(t *testing.T) {
+	assert.Equal(t, DefaultEscalationMessage, EscalationHoldingMessage(&Chatbot{}))
+	assert.Equal(t, "Hold on", EscalationHoldingMessage(&Chatbot{EscalationMessage: "Hold on"}))
+}
+
+func TestBuildEscalationTranscript(t *testing.T) {
+	messages := []Message{
+		{Role: RoleSystem, Content: "system prompt"},
+		{Role: RoleUser, Content: "Where is my order?"},
+		{Role: RoleAssistant, Content: "", ToolCalls: []ToolCall{{ID: "1"}}},
+		{Role: RoleTool, Content: "[]"},
+		{Role: RoleAssistant, Content: "I could not find it."},
+		{Role: RoleUser, Content: "Talk to a human"},
+	}
+
+	transcript := BuildEscalationTranscript(messages)
+
+	require.Len(t, transcript, 3)
+	assert.Equal(t, EscalationRecord{Role: "user", Content: "Where is my order?"}, transcript[0])
+	assert.Equal(t, EscalationRecord{Role: "assistant", Content: "I could not find it."}, transcript[1])
+	assert.Equal(t, EscalationRecord{Role: "user", Content: "Talk to a human"}, transcript[2])
+}
+
+func TestRetrieveContextResult_BestSimilarity(t *testing.T) {
+	assert.Equal(t, 0.0, (&RetrieveContextResult{}).BestSimilarity())
+
+	result := &RetrieveContextResult{Chunks: []RetrievalResult{
+		{Similarity: 0.42},
+		{Similarity: 0.81},
+		{Similarity: 0.77},
+	}}
+	assert.Equal(t, 0.81, result.BestSimilarity())
+}
+
+func TestEscalationNotifier_Notify(t *testing.T) {
+	var received EscalationWebhookPayload
+	var eventHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		eventHeader = r.Header.Get("X-Fluxbase-Event")
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender := &fakeEscalationEmailSender{}
+	notifier := NewEscalationNotifier(nil, sender)
+
+	similarity := 0.31
+	userID := "user-1"
+	chatbot := &Chatbot{
+		ID:                   "bot-1",
+		Name:                 "support",
+		EscalationWebhookURL: server.URL,
+		EscalationEmail:      "support@example.com",
+	}
+	escalation := &Escalation{
+		ID:             "esc-1",
+		ConversationID: "conv-1",
+		ChatbotID:      "bot-1",
+		UserID:         &userID,
+		Reason:         EscalationReasonLowConfidence,
+		BestSimilarity: &similarity,
+		Transcript:     []EscalationRecord{{Role: "user", Content: "Where is my refund?"}},
+		CreatedAt:      time.Now(),
+	}
+
+	notifier.Notify(context.Background(), chatbot, escalation)
+
+	assert.Equal(t, "conversation.escalated", eventHeader)
+	assert.Equal(t, "esc-1", received.EscalationID)
+	assert.Equal(t, "conv-1", received.ConversationID)
+	assert.Equal(t, EscalationReasonLowConfidence, received.Reason)
+	require.Len(t, received.Transcript, 1)
+	assert.Equal(t, "Where is my refund?", received.Transcript[0].Content)
+
+	assert.Equal(t, "support@example.com", sender.to)
+	assert.Contains(t, sender.subject, "support")
+	assert.Contains(t, sender.body, "Reason: low_confidence")
+	assert.Contains(t, sender.body, "Best similarity: 0.31")
+	assert.Contains(t, sender.body, "user: Where is my refund?")
+}
+
+func TestEscalationNotifier_WebhookFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	notifier := NewEscalationNotifier(nil, nil)
+	chatbot := &Chatbot{ID: "bot-1", Name: "support", EscalationWebhookURL: server.URL}
+
+	err := notifier.sendWebhook(context.Background(), chatbot, &Escalation{ID: "esc-1"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 500")
+}
+
+func TestIsValidEscalationStatus(t *testing.T) {
+	assert.True(t, isValidEscalationStatus(EscalationStatusOpen))
+	assert.True(t, isValidEscalationStatus(EscalationStatusAcknowledged))
+	assert.True(t, isValidEscalationStatus(EscalationStatusResolved))
+	assert.False(t, isValidEscalationStatus("closed"))
+	assert.False(t, isValidEscalationStatus(""))
+}
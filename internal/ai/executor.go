@@ -42,6 +42,7 @@ type ExecuteRequest struct {
 	Role              string
 	Claims            *auth.TokenClaims
 	SQL               string
+	Args              []any // Parameters of SQL built by the server, e.g. for REST tools
 	Description       string
 	AllowedSchemas    []string
 	AllowedTables     []string
@@ -144,7 +145,7 @@ func (e *Executor) Execute(ctx context.Context, req *ExecuteRequest) (*ExecuteRe
 	}
 
 	// Execute the query
-	rows, queryErr = tx.Query(queryCtx, normalizedSQL)
+	rows, queryErr = tx.Query(queryCtx, normalizedSQL, req.Args...)
 	if queryErr != nil {
 		result.Error = fmt.Sprintf("Query execution failed: %s", queryErr.Error())
 		result.Summary = "Query failed to execute"
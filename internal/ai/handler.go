@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
 	"github.com/nimbleflux/fluxbase/internal/config"
 	"github.com/rs/zerolog/log"
 )
@@ -23,6 +24,7 @@ type Handler struct {
 	config               *config.AIConfig
 	vectorManager        VectorManagerInterface
 	knowledgeBaseStorage *KnowledgeBaseStorage // Optional: for syncing KB links
+	embeddingService     *EmbeddingService     // Optional: for conversation search
 }
 
 // NewHandler creates a new AI handler
@@ -45,6 +47,11 @@ func (h *Handler) SetKnowledgeBaseStorage(kbStorage *KnowledgeBaseStorage) {
 	h.knowledgeBaseStorage = kbStorage
 }
 
+// SetEmbeddingService sets the embedding service used to embed conversation search queries
+func (h *Handler) SetEmbeddingService(embeddingService *EmbeddingService) {
+	h.embeddingService = embeddingService
+}
+
 // ValidateConfig checks AI configuration and logs any issues at startup
 func (h *Handler) ValidateConfig() {
 	if h.config == nil || h.config.ProviderType == "" {
@@ -98,9 +105,7 @@ func (h *Handler) ListChatbots(c fiber.Ctx) error {
 	chatbots, err := h.storage.ListChatbots(ctx, false)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list chatbots")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to list chatbots",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list chatbots")
 	}
 
 	// Convert to summaries for API response
@@ -124,15 +129,11 @@ func (h *Handler) GetChatbot(c fiber.Ctx) error {
 	chatbot, err := h.storage.GetChatbot(ctx, id)
 	if err != nil {
 		log.Error().Err(err).Str("id", id).Msg("Failed to get chatbot")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get chatbot",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get chatbot")
 	}
 
 	if chatbot == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Chatbot not found",
-		})
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Chatbot not found")
 	}
 
 	return c.JSON(chatbot)
@@ -183,9 +184,7 @@ func (h *Handler) syncFromFilesystem(c fiber.Ctx, namespace string) error {
 	fsChatbots, err := h.loader.LoadAll()
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to load chatbots from filesystem")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to load chatbots from filesystem",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to load chatbots from filesystem")
 	}
 
 	// Override namespace for all loaded chatbots with the requested namespace
@@ -197,9 +196,7 @@ func (h *Handler) syncFromFilesystem(c fiber.Ctx, namespace string) error {
 	dbChatbots, err := h.storage.ListChatbotsByNamespace(ctx, namespace)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list chatbots from database")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to list chatbots from database",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list chatbots from database")
 	}
 
 	// Build map of existing chatbots by name (within this namespace)
@@ -333,9 +330,7 @@ func (h *Handler) syncFromPayload(c fiber.Ctx, namespace string, chatbots []stru
 	// Get existing chatbots in this namespace
 	dbChatbots, err := h.storage.ListChatbotsByNamespace(ctx, namespace)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to list existing chatbots",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list existing chatbots")
 	}
 
 	// Build map of existing chatbots by name
@@ -354,7 +349,7 @@ func (h *Handler) syncFromPayload(c fiber.Ctx, namespace string, chatbots []stru
 	created := []string{}
 	updated := []string{}
 	deleted := []string{}
-	errorList := []fiber.Map{}
+	errorList := []apierror.Response{}
 
 	// If dry run, calculate what would be done
 	if dryRun {
@@ -398,10 +393,7 @@ func (h *Handler) syncFromPayload(c fiber.Ctx, namespace string, chatbots []stru
 		chatbot, err := h.loader.ParseChatbotFromCode(spec.Code, namespace)
 		if err != nil {
 			log.Error().Err(err).Str("name", spec.Name).Msg("Failed to parse chatbot")
-			errorList = append(errorList, fiber.Map{
-				"name":  spec.Name,
-				"error": "Failed to parse chatbot: " + err.Error(),
-			})
+			errorList = append(errorList, chatbotSyncError(spec.Name, "Failed to parse chatbot: "+err.Error()))
 			continue
 		}
 
@@ -419,10 +411,7 @@ func (h *Handler) syncFromPayload(c fiber.Ctx, namespace string, chatbots []stru
 
 			if err := h.storage.UpdateChatbot(ctx, chatbot); err != nil {
 				log.Error().Err(err).Str("name", spec.Name).Msg("Failed to update chatbot")
-				errorList = append(errorList, fiber.Map{
-					"name":  spec.Name,
-					"error": "Failed to update: " + err.Error(),
-				})
+				errorList = append(errorList, chatbotSyncError(spec.Name, "Failed to update: "+err.Error()))
 				continue
 			}
 			updated = append(updated, spec.Name)
@@ -430,10 +419,7 @@ func (h *Handler) syncFromPayload(c fiber.Ctx, namespace string, chatbots []stru
 			// Create new chatbot
 			if err := h.storage.CreateChatbot(ctx, chatbot); err != nil {
 				log.Error().Err(err).Str("name", spec.Name).Msg("Failed to create chatbot")
-				errorList = append(errorList, fiber.Map{
-					"name":  spec.Name,
-					"error": "Failed to create: " + err.Error(),
-				})
+				errorList = append(errorList, chatbotSyncError(spec.Name, "Failed to create: "+err.Error()))
 				continue
 			}
 			created = append(created, spec.Name)
@@ -462,10 +448,7 @@ func (h *Handler) syncFromPayload(c fiber.Ctx, namespace string, chatbots []stru
 			if !payloadNames[name] && chatbot.Source == "sdk" {
 				if err := h.storage.DeleteChatbot(ctx, chatbot.ID); err != nil {
 					log.Error().Err(err).Str("name", name).Msg("Failed to delete chatbot")
-					errorList = append(errorList, fiber.Map{
-						"name":  name,
-						"error": "Failed to delete: " + err.Error(),
-					})
+					errorList = append(errorList, chatbotSyncError(name, "Failed to delete: "+err.Error()))
 					continue
 				}
 				deleted = append(deleted, name)
@@ -498,6 +481,11 @@ func (h *Handler) syncFromPayload(c fiber.Ctx, namespace string, chatbots []stru
 	})
 }
 
+// chatbotSyncError renders a chatbot that failed to sync like an API error, naming the chatbot in details
+func chatbotSyncError(name, message string) apierror.Response {
+	return apierror.Response{Error: message, Code: apierror.CodeOperationFailed, Details: fiber.Map{"name": name}}
+}
+
 // ToggleChatbotRequest represents the request to enable/disable a chatbot
 type ToggleChatbotRequest struct {
 	Enabled bool `json:"enabled"`
@@ -511,31 +499,23 @@ func (h *Handler) ToggleChatbot(c fiber.Ctx) error {
 
 	var req ToggleChatbotRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
 	}
 
 	chatbot, err := h.storage.GetChatbot(ctx, id)
 	if err != nil {
 		log.Error().Err(err).Str("id", id).Msg("Failed to get chatbot")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get chatbot",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get chatbot")
 	}
 
 	if chatbot == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Chatbot not found",
-		})
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Chatbot not found")
 	}
 
 	chatbot.Enabled = req.Enabled
 	if err := h.storage.UpdateChatbot(ctx, chatbot); err != nil {
 		log.Error().Err(err).Str("id", id).Msg("Failed to update chatbot")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update chatbot",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to update chatbot")
 	}
 
 	return c.JSON(fiber.Map{
@@ -552,9 +532,7 @@ func (h *Handler) DeleteChatbot(c fiber.Ctx) error {
 
 	if err := h.storage.DeleteChatbot(ctx, id); err != nil {
 		log.Error().Err(err).Str("id", id).Msg("Failed to delete chatbot")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete chatbot",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to delete chatbot")
 	}
 
 	return c.JSON(fiber.Map{
@@ -588,61 +566,41 @@ func (h *Handler) UpdateChatbot(c fiber.Ctx) error {
 
 	var req UpdateChatbotRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
 	}
 
 	// Validate inputs
 	if req.Temperature != nil && (*req.Temperature < 0 || *req.Temperature > 2) {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Temperature must be between 0 and 2",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Temperature must be between 0 and 2")
 	}
 	if req.MaxTokens != nil && *req.MaxTokens <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Max tokens must be positive",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Max tokens must be positive")
 	}
 	if req.ConversationTTLHours != nil && *req.ConversationTTLHours <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Conversation TTL hours must be positive",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Conversation TTL hours must be positive")
 	}
 	if req.MaxConversationTurns != nil && *req.MaxConversationTurns <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Max conversation turns must be positive",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Max conversation turns must be positive")
 	}
 	if req.RateLimitPerMinute != nil && *req.RateLimitPerMinute <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Rate limit per minute must be positive",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Rate limit per minute must be positive")
 	}
 	if req.DailyRequestLimit != nil && *req.DailyRequestLimit <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Daily request limit must be positive",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Daily request limit must be positive")
 	}
 	if req.DailyTokenBudget != nil && *req.DailyTokenBudget <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Daily token budget must be positive",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Daily token budget must be positive")
 	}
 
 	// Get existing chatbot
 	chatbot, err := h.storage.GetChatbot(ctx, id)
 	if err != nil {
 		log.Error().Err(err).Str("id", id).Msg("Failed to get chatbot")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get chatbot",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get chatbot")
 	}
 
 	if chatbot == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Chatbot not found",
-		})
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Chatbot not found")
 	}
 
 	// Apply partial updates (only non-nil fields)
@@ -693,9 +651,7 @@ func (h *Handler) UpdateChatbot(c fiber.Ctx) error {
 	// Update in database
 	if err := h.storage.UpdateChatbot(ctx, chatbot); err != nil {
 		log.Error().Err(err).Str("id", id).Msg("Failed to update chatbot")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update chatbot",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to update chatbot")
 	}
 
 	return c.JSON(chatbot)
@@ -713,9 +669,7 @@ func (h *Handler) ListProviders(c fiber.Ctx) error {
 	providers, err := h.storage.ListProviders(ctx, false)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list providers")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to list providers",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list providers")
 	}
 
 	// Remove sensitive config for API response
@@ -743,15 +697,11 @@ func (h *Handler) GetProvider(c fiber.Ctx) error {
 	provider, err := h.storage.GetProvider(ctx, id)
 	if err != nil {
 		log.Error().Err(err).Str("id", id).Msg("Failed to get provider")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get provider",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get provider")
 	}
 
 	if provider == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Provider not found",
-		})
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Provider not found")
 	}
 
 	// Mask API key
@@ -803,9 +753,7 @@ func (h *Handler) CreateProvider(c fiber.Ctx) error {
 
 	var req CreateProviderRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
 	}
 
 	// Normalize config to convert values to strings and remove empty/invalid values
@@ -813,9 +761,7 @@ func (h *Handler) CreateProvider(c fiber.Ctx) error {
 
 	// Validate provider type
 	if req.ProviderType != "openai" && req.ProviderType != "azure" && req.ProviderType != "ollama" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid provider type. Must be 'openai', 'azure', or 'ollama'",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid provider type. Must be 'openai', 'azure', or 'ollama'")
 	}
 
 	// Check if there's an existing default provider
@@ -843,9 +789,7 @@ func (h *Handler) CreateProvider(c fiber.Ctx) error {
 
 	if err := h.storage.CreateProvider(ctx, provider); err != nil {
 		log.Error().Err(err).Str("name", req.Name).Msg("Failed to create provider")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create provider",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to create provider")
 	}
 
 	// Reload embedding service from database providers
@@ -873,16 +817,12 @@ func (h *Handler) SetDefaultProvider(c fiber.Ctx) error {
 
 	// Prevent modifying config-based provider
 	if id == "FROM_CONFIG" {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Cannot modify config-based provider. This provider is configured via environment variables or fluxbase.yaml and is read-only.",
-		})
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Cannot modify config-based provider. This provider is configured via environment variables or fluxbase.yaml and is read-only.")
 	}
 
 	if err := h.storage.SetDefaultProvider(ctx, id); err != nil {
 		log.Error().Err(err).Str("id", id).Msg("Failed to set default provider")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to set default provider",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to set default provider")
 	}
 
 	// Reload embedding service from database providers
@@ -906,16 +846,12 @@ func (h *Handler) DeleteProvider(c fiber.Ctx) error {
 
 	// Prevent deleting config-based provider
 	if id == "FROM_CONFIG" {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Cannot delete config-based provider. This provider is configured via environment variables or fluxbase.yaml and is read-only.",
-		})
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Cannot delete config-based provider. This provider is configured via environment variables or fluxbase.yaml and is read-only.")
 	}
 
 	if err := h.storage.DeleteProvider(ctx, id); err != nil {
 		log.Error().Err(err).Str("id", id).Msg("Failed to delete provider")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete provider",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to delete provider")
 	}
 
 	// Reload embedding service from database providers
@@ -939,17 +875,13 @@ func (h *Handler) SetEmbeddingProvider(c fiber.Ctx) error {
 
 	// Prevent modifying config-based provider
 	if id == "FROM_CONFIG" {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Cannot modify config-based provider. This provider is configured via environment variables or fluxbase.yaml and is read-only.",
-		})
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Cannot modify config-based provider. This provider is configured via environment variables or fluxbase.yaml and is read-only.")
 	}
 
 	// Set embedding provider preference
 	if err := h.storage.SetEmbeddingProviderPreference(ctx, id); err != nil {
 		log.Error().Err(err).Str("id", id).Msg("Failed to set embedding provider")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to set embedding provider",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to set embedding provider")
 	}
 
 	// Reload embedding service from database providers
@@ -973,9 +905,7 @@ func (h *Handler) ClearEmbeddingProvider(c fiber.Ctx) error {
 	// Clear embedding preference (revert to auto/default)
 	if err := h.storage.SetEmbeddingProviderPreference(ctx, ""); err != nil {
 		log.Error().Err(err).Msg("Failed to clear embedding provider preference")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to clear embedding provider preference",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to clear embedding provider preference")
 	}
 
 	// Reload embedding service to use default provider
@@ -1006,31 +936,23 @@ func (h *Handler) UpdateProvider(c fiber.Ctx) error {
 
 	// Prevent modifying config-based provider
 	if id == "FROM_CONFIG" {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Cannot modify config-based provider. This provider is configured via environment variables or fluxbase.yaml and is read-only.",
-		})
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Cannot modify config-based provider. This provider is configured via environment variables or fluxbase.yaml and is read-only.")
 	}
 
 	var req UpdateProviderRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
 	}
 
 	// Get existing provider
 	provider, err := h.storage.GetProvider(ctx, id)
 	if err != nil {
 		log.Error().Err(err).Str("id", id).Msg("Failed to get provider")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get provider",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get provider")
 	}
 
 	if provider == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Provider not found",
-		})
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Provider not found")
 	}
 
 	// Apply updates
@@ -1065,9 +987,7 @@ func (h *Handler) UpdateProvider(c fiber.Ctx) error {
 
 	if err := h.storage.UpdateProvider(ctx, provider); err != nil {
 		log.Error().Err(err).Str("id", id).Msg("Failed to update provider")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update provider",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to update provider")
 	}
 
 	// Reload embedding service from database providers
@@ -1099,9 +1019,7 @@ func (h *Handler) ListPublicChatbots(c fiber.Ctx) error {
 	chatbots, err := h.storage.ListChatbots(ctx, true)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list chatbots")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to list chatbots",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list chatbots")
 	}
 
 	// Filter to only public chatbots
@@ -1128,15 +1046,11 @@ func (h *Handler) GetPublicChatbot(c fiber.Ctx) error {
 	chatbot, err := h.storage.GetChatbotByName(ctx, namespace, name)
 	if err != nil {
 		log.Error().Err(err).Str("namespace", namespace).Str("name", name).Msg("Failed to get chatbot")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get chatbot",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get chatbot")
 	}
 
 	if chatbot == nil || !chatbot.Enabled || !chatbot.IsPublic {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Chatbot not found",
-		})
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Chatbot not found")
 	}
 
 	// Return only public information
@@ -1264,7 +1178,8 @@ type AIMetrics struct {
 	AvgResponseTimeMS     float64          `json:"avg_response_time_ms"`
 }
 
-// GetAIMetrics returns aggregated AI metrics
+// GetAIMetrics returns aggregated AI metrics. Conversation totals can be narrowed with the
+// tag and metadata.<key> conversation filters.
 // GET /api/v1/admin/ai/metrics
 func (h *Handler) GetAIMetrics(c fiber.Ctx) error {
 	ctx := c.RequestCtx()
@@ -1281,9 +1196,11 @@ func (h *Handler) GetAIMetrics(c fiber.Ctx) error {
 			COUNT(*) FILTER (WHERE status = 'active') as active_conversations,
 			COALESCE(SUM(total_prompt_tokens), 0) as total_prompt_tokens,
 			COALESCE(SUM(total_completion_tokens), 0) as total_completion_tokens
-		FROM ai.conversations
+		FROM ai.conversations c
+		WHERE 1=1
 	`
-	err := h.storage.db.QueryRow(ctx, convQuery).Scan(
+	convQuery, convArgs := ParseConversationFilter(c).appendTo(convQuery, "c", nil)
+	err := h.storage.db.QueryRow(ctx, convQuery, convArgs...).Scan(
 		&metrics.TotalConversations,
 		&metrics.ActiveConversations,
 		&metrics.TotalPromptTokens,
@@ -1361,24 +1278,26 @@ func (h *Handler) GetAIMetrics(c fiber.Ctx) error {
 
 // ConversationSummary represents a conversation with basic info
 type ConversationSummary struct {
-	ID                    string     `json:"id"`
-	ChatbotID             string     `json:"chatbot_id"`
-	ChatbotName           string     `json:"chatbot_name"`
-	UserID                *string    `json:"user_id"`
-	UserEmail             *string    `json:"user_email"`
-	SessionID             *string    `json:"session_id"`
-	Title                 *string    `json:"title"`
-	Status                string     `json:"status"`
-	TurnCount             int        `json:"turn_count"`
-	TotalPromptTokens     int        `json:"total_prompt_tokens"`
-	TotalCompletionTokens int        `json:"total_completion_tokens"`
-	CreatedAt             time.Time  `json:"created_at"`
-	UpdatedAt             time.Time  `json:"updated_at"`
-	LastMessageAt         *time.Time `json:"last_message_at"`
+	ID                    string            `json:"id"`
+	ChatbotID             string            `json:"chatbot_id"`
+	ChatbotName           string            `json:"chatbot_name"`
+	UserID                *string           `json:"user_id"`
+	UserEmail             *string           `json:"user_email"`
+	SessionID             *string           `json:"session_id"`
+	Title                 *string           `json:"title"`
+	Status                string            `json:"status"`
+	TurnCount             int               `json:"turn_count"`
+	TotalPromptTokens     int               `json:"total_prompt_tokens"`
+	TotalCompletionTokens int               `json:"total_completion_tokens"`
+	CreatedAt             time.Time         `json:"created_at"`
+	UpdatedAt             time.Time         `json:"updated_at"`
+	LastMessageAt         *time.Time        `json:"last_message_at"`
+	Metadata              map[string]string `json:"metadata"`
+	Tags                  []string          `json:"tags"`
 }
 
 // GetConversations returns a list of AI conversations with optional filters
-// GET /api/v1/admin/ai/conversations?chatbot_id=X&user_id=Y&status=active&limit=50
+// GET /api/v1/admin/ai/conversations?chatbot_id=X&user_id=Y&status=active&tag=vip&metadata.channel=web&limit=50
 func (h *Handler) GetConversations(c fiber.Ctx) error {
 	ctx := c.RequestCtx()
 
@@ -1386,6 +1305,7 @@ func (h *Handler) GetConversations(c fiber.Ctx) error {
 	chatbotID := c.Query("chatbot_id")
 	userID := c.Query("user_id")
 	status := c.Query("status")
+	filter := ParseConversationFilter(c)
 	limit := fiber.Query[int](c, "limit", 50)
 	offset := fiber.Query[int](c, "offset", 0)
 
@@ -1405,7 +1325,9 @@ func (h *Handler) GetConversations(c fiber.Ctx) error {
 			c.total_completion_tokens,
 			c.created_at,
 			c.updated_at,
-			c.last_message_at
+			c.last_message_at,
+			c.metadata,
+			c.tags
 		FROM ai.conversations c
 		LEFT JOIN ai.chatbots cb ON cb.id = c.chatbot_id
 		LEFT JOIN auth.users u ON u.id = c.user_id
@@ -1430,9 +1352,11 @@ func (h *Handler) GetConversations(c fiber.Ctx) error {
 	if status != "" {
 		query += fmt.Sprintf(" AND c.status = $%d", argIndex)
 		args = append(args, status)
-		argIndex++
 	}
 
+	query, args = filter.appendTo(query, "c", args)
+	argIndex = len(args) + 1
+
 	// Build count query with same filters (without LIMIT/OFFSET)
 	countQuery := `
 		SELECT COUNT(*)
@@ -1456,6 +1380,7 @@ func (h *Handler) GetConversations(c fiber.Ctx) error {
 		countQuery += fmt.Sprintf(" AND c.status = $%d", countArgIndex)
 		countArgs = append(countArgs, status)
 	}
+	countQuery, countArgs = filter.appendTo(countQuery, "c", countArgs)
 
 	var totalCount int
 	if err := h.storage.db.QueryRow(ctx, countQuery, countArgs...).Scan(&totalCount); err != nil {
@@ -1469,9 +1394,7 @@ func (h *Handler) GetConversations(c fiber.Ctx) error {
 	rows, err := h.storage.db.Query(ctx, query, args...)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to query conversations")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to query conversations",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to query conversations")
 	}
 	defer rows.Close()
 
@@ -1493,6 +1416,8 @@ func (h *Handler) GetConversations(c fiber.Ctx) error {
 			&conv.CreatedAt,
 			&conv.UpdatedAt,
 			&conv.LastMessageAt,
+			&conv.Metadata,
+			&conv.Tags,
 		)
 		if err != nil {
 			log.Error().Err(err).Msg("Failed to scan conversation")
@@ -1523,6 +1448,9 @@ type MessageDetail struct {
 	SQLDurationMS    *int      `json:"sql_duration_ms"`
 	PromptTokens     *int      `json:"prompt_tokens"`
 	CompletionTokens *int      `json:"completion_tokens"`
+	Model            *string   `json:"model,omitempty"`
+	RoutingRule      *string   `json:"routing_rule,omitempty"`
+	CostDeltaUSD     *float64  `json:"cost_delta_usd,omitempty"`
 	CreatedAt        time.Time `json:"created_at"`
 	SequenceNumber   int       `json:"sequence_number"`
 }
@@ -1548,6 +1476,9 @@ func (h *Handler) GetConversationMessages(c fiber.Ctx) error {
 			sql_duration_ms,
 			prompt_tokens,
 			completion_tokens,
+			model,
+			routing_rule,
+			cost_delta_usd::float8,
 			created_at,
 			sequence_number
 		FROM ai.messages
@@ -1558,9 +1489,7 @@ func (h *Handler) GetConversationMessages(c fiber.Ctx) error {
 	rows, err := h.storage.db.Query(ctx, query, conversationID)
 	if err != nil {
 		log.Error().Err(err).Str("conversation_id", conversationID).Msg("Failed to query messages")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to query messages",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to query messages")
 	}
 	defer rows.Close()
 
@@ -1581,6 +1510,9 @@ func (h *Handler) GetConversationMessages(c fiber.Ctx) error {
 			&msg.SQLDurationMS,
 			&msg.PromptTokens,
 			&msg.CompletionTokens,
+			&msg.Model,
+			&msg.RoutingRule,
+			&msg.CostDeltaUSD,
 			&msg.CreatedAt,
 			&msg.SequenceNumber,
 		)
@@ -1723,9 +1655,7 @@ func (h *Handler) GetAuditLog(c fiber.Ctx) error {
 	rows, err := h.storage.db.Query(ctx, query, args...)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to query audit log")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to query audit log",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to query audit log")
 	}
 	defer rows.Close()
 
@@ -1846,16 +1776,12 @@ func (h *Handler) ListUserConversations(c fiber.Ctx) error {
 	// Get authenticated user ID from context
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeAuthRequired, "Authentication required")
 	}
 
 	userIDStr, ok := userID.(string)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeAuthRequired, "Invalid user ID")
 	}
 
 	// Parse query params
@@ -1884,14 +1810,13 @@ func (h *Handler) ListUserConversations(c fiber.Ctx) error {
 	if namespace := c.Query("namespace"); namespace != "" {
 		opts.Namespace = &namespace
 	}
+	opts.Filter = ParseConversationFilter(c)
 
 	// Query conversations
 	result, err := h.storage.ListUserConversations(ctx, opts)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list user conversations")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to list conversations",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list conversations")
 	}
 
 	return c.JSON(result)
@@ -1906,30 +1831,22 @@ func (h *Handler) GetUserConversation(c fiber.Ctx) error {
 	// Get authenticated user ID from context
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeAuthRequired, "Authentication required")
 	}
 
 	userIDStr, ok := userID.(string)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeAuthRequired, "Invalid user ID")
 	}
 
 	conversation, err := h.storage.GetUserConversation(ctx, userIDStr, conversationID)
 	if err != nil {
 		log.Error().Err(err).Str("id", conversationID).Msg("Failed to get conversation")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get conversation",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get conversation")
 	}
 
 	if conversation == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Conversation not found",
-		})
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Conversation not found")
 	}
 
 	return c.JSON(conversation)
@@ -1944,29 +1861,21 @@ func (h *Handler) DeleteUserConversation(c fiber.Ctx) error {
 	// Get authenticated user ID from context
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeAuthRequired, "Authentication required")
 	}
 
 	userIDStr, ok := userID.(string)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeAuthRequired, "Invalid user ID")
 	}
 
 	err := h.storage.DeleteUserConversation(ctx, userIDStr, conversationID)
 	if err != nil {
 		if err.Error() == "conversation not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Conversation not found",
-			})
+			return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Conversation not found")
 		}
 		log.Error().Err(err).Str("id", conversationID).Msg("Failed to delete conversation")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete conversation",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to delete conversation")
 	}
 
 	return c.JSON(fiber.Map{
@@ -1984,60 +1893,167 @@ func (h *Handler) UpdateUserConversation(c fiber.Ctx) error {
 	// Get authenticated user ID from context
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeAuthRequired, "Authentication required")
 	}
 
 	userIDStr, ok := userID.(string)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeAuthRequired, "Invalid user ID")
 	}
 
 	var req UpdateConversationTitleRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
 	}
 
 	// Validate title
 	if req.Title == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Title cannot be empty",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Title cannot be empty")
 	}
 	if len(req.Title) > 200 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Title must be 200 characters or less",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Title must be 200 characters or less")
 	}
 
 	err := h.storage.UpdateConversationTitle(ctx, userIDStr, conversationID, req.Title)
 	if err != nil {
 		if err.Error() == "conversation not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Conversation not found",
-			})
+			return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Conversation not found")
 		}
 		log.Error().Err(err).Str("id", conversationID).Msg("Failed to update conversation")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update conversation",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to update conversation")
 	}
 
 	// Return updated conversation
 	conversation, err := h.storage.GetUserConversation(ctx, userIDStr, conversationID)
 	if err != nil {
 		log.Error().Err(err).Str("id", conversationID).Msg("Failed to get updated conversation")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Conversation updated but failed to retrieve",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Conversation updated but failed to retrieve")
 	}
 
 	return c.JSON(conversation)
 }
 
+// ============================================================================
+// ESCALATION ENDPOINTS (support tooling)
+// ============================================================================
+
+// UpdateEscalationRequest represents a request to change an escalation's status
+type UpdateEscalationRequest struct {
+	Status EscalationStatus `json:"status"`
+	Notes  *string          `json:"notes,omitempty"`
+}
+
+// ListEscalations returns conversation escalations with optional filters
+// GET /api/v1/admin/ai/escalations?status=open&chatbot_id=X&conversation_id=Y&limit=50&offset=0
+func (h *Handler) ListEscalations(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+
+	opts := ListEscalationsOptions{
+		Limit:  fiber.Query[int](c, "limit", 50),
+		Offset: fiber.Query[int](c, "offset", 0),
+	}
+	if opts.Limit <= 0 || opts.Limit > 200 {
+		opts.Limit = 50
+	}
+	if opts.Offset < 0 {
+		opts.Offset = 0
+	}
+	if v := c.Query("chatbot_id"); v != "" {
+		opts.ChatbotID = &v
+	}
+	if v := c.Query("conversation_id"); v != "" {
+		opts.ConversationID = &v
+	}
+	if v := c.Query("status"); v != "" {
+		status := EscalationStatus(v)
+		if !isValidEscalationStatus(status) {
+			return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid status. Must be one of: open, acknowledged, resolved")
+		}
+		opts.Status = &status
+	}
+
+	escalations, total, err := h.storage.ListEscalations(ctx, opts)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list escalations")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list escalations")
+	}
+
+	return c.JSON(fiber.Map{
+		"escalations": escalations,
+		"total":       total,
+		"limit":       opts.Limit,
+		"offset":      opts.Offset,
+	})
+}
+
+// GetEscalation returns a single escalation including its transcript
+// GET /api/v1/admin/ai/escalations/:id
+func (h *Handler) GetEscalation(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+	id := c.Params("id")
+
+	escalation, err := h.storage.GetEscalation(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("Failed to get escalation")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get escalation")
+	}
+	if escalation == nil {
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Escalation not found")
+	}
+
+	return c.JSON(escalation)
+}
+
+// UpdateEscalation acknowledges or resolves an escalation. Resolving hands the
+// conversation back to the chatbot.
+// PATCH /api/v1/admin/ai/escalations/:id
+func (h *Handler) UpdateEscalation(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+	id := c.Params("id")
+
+	var req UpdateEscalationRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+	}
+	if !isValidEscalationStatus(req.Status) {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid status. Must be one of: open, acknowledged, resolved")
+	}
+
+	existing, err := h.storage.GetEscalation(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("Failed to get escalation")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get escalation")
+	}
+	if existing == nil {
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Escalation not found")
+	}
+
+	var resolvedBy *string
+	if userID, ok := c.Locals("user_id").(string); ok && userID != "" {
+		resolvedBy = &userID
+	}
+
+	if err := h.storage.UpdateEscalationStatus(ctx, id, req.Status, req.Notes, resolvedBy); err != nil {
+		log.Error().Err(err).Str("id", id).Msg("Failed to update escalation")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to update escalation")
+	}
+
+	escalation, err := h.storage.GetEscalation(ctx, id)
+	if err != nil || escalation == nil {
+		log.Error().Err(err).Str("id", id).Msg("Failed to get updated escalation")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Escalation updated but failed to retrieve")
+	}
+
+	return c.JSON(escalation)
+}
+
+// isValidEscalationStatus checks that a status is one of the known escalation states
+func isValidEscalationStatus(status EscalationStatus) bool {
+	switch status {
+	case EscalationStatusOpen, EscalationStatusAcknowledged, EscalationStatusResolved:
+		return true
+	}
+	return false
+}
+
 // fiber:context-methods migrated
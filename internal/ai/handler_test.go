@@ -160,6 +160,12 @@ func TestSyncChatbotsRequest_Struct(t *testing.T) {
 	})
 }
 
+func TestChatbotSyncError(t *testing.T) {
+	data, err := json.Marshal(chatbotSyncError("support-bot", "Failed to create: boom"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"error":"Failed to create: boom","code":"OPERATION_FAILED","details":{"name":"support-bot"}}`, string(data))
+}
+
 func TestToggleChatbotRequest_Struct(t *testing.T) {
 	t.Run("enabled true", func(t *testing.T) {
 		jsonData := `{"enabled": true}`
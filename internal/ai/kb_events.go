@@ -0,0 +1,53 @@
+package ai
+
+import (
+	"time"
+)
+
+// KnowledgeBaseChannelPrefix prefixes the realtime channel of a knowledge base, "kb:<id>"
+const KnowledgeBaseChannelPrefix = "kb:"
+
+// DocumentStatusEventName is the broadcast event sent on a knowledge base channel when a
+// document changes status
+const DocumentStatusEventName = "document_status"
+
+// KnowledgeBaseChannel returns the realtime channel that carries the document events of a
+// knowledge base
+func KnowledgeBaseChannel(kbID string) string {
+	return KnowledgeBaseChannelPrefix + kbID
+}
+
+// DocumentStatusEvent describes a step of a document's lifecycle:
+// pending → processing → indexed or failed
+type DocumentStatusEvent struct {
+	KnowledgeBaseID string         `json:"knowledge_base_id"`
+	DocumentID      string         `json:"document_id"`
+	Title           string         `json:"title,omitempty"`
+	Status          DocumentStatus `json:"status"`
+	ChunksCount     int            `json:"chunks_count"`           // Chunks stored for the document
+	ChunksTotal     int            `json:"chunks_total,omitempty"` // Chunks being embedded while processing
+	ErrorMessage    string         `json:"error_message,omitempty"`
+	Timestamp       time.Time      `json:"timestamp"`
+}
+
+// DocumentEventPublisher delivers document status events, typically to realtime subscribers.
+// Publishing is best-effort and must not block document processing.
+type DocumentEventPublisher interface {
+	PublishDocumentStatus(event *DocumentStatusEvent)
+}
+
+// SetDocumentEventPublisher sets the publisher notified when documents change status
+func (s *KnowledgeBaseStorage) SetDocumentEventPublisher(publisher DocumentEventPublisher) {
+	s.documentEvents = publisher
+}
+
+// publishDocumentStatus sends event to the document event publisher, if one is set
+func (s *KnowledgeBaseStorage) publishDocumentStatus(event *DocumentStatusEvent) {
+	if s == nil || s.documentEvents == nil {
+		return
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	s.documentEvents.PublishDocumentStatus(event)
+}
@@ -0,0 +1,48 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultFreshnessWeight is the share of a search score subject to the freshness decay when a
+// half-life is set without a weight. The rest of the score is independent of document age.
+const DefaultFreshnessWeight = 0.3
+
+// ErrInvalidFreshnessHalfLife is returned for a negative freshness half-life
+var ErrInvalidFreshnessHalfLife = errors.New("freshness_half_life_days must not be negative")
+
+// normalizeFreshnessHalfLife validates a freshness half-life from a request. Zero disables the
+// boost and is stored as NULL.
+func normalizeFreshnessHalfLife(days *float64) (*float64, error) {
+	if days == nil || *days == 0 {
+		return nil, nil
+	}
+	if *days < 0 {
+		return nil, ErrInvalidFreshnessHalfLife
+	}
+	return days, nil
+}
+
+// freshnessHalfLife returns the freshness half-life of a link in days, 0 if it has none
+func (l *ChatbotKnowledgeBase) freshnessHalfLife() float64 {
+	if l.FreshnessHalfLifeDays == nil {
+		return 0
+	}
+	return *l.FreshnessHalfLifeDays
+}
+
+// freshnessFactorSQL returns the SQL factor a search score on the document alias d is multiplied
+// with, appending its bind values to args. The factor is (1 - weight) + weight * 0.5^(age / half-life)
+// with the age taken from d.updated_at: 1 for a document updated just now, approaching 1 - weight
+// for old ones. Without a half-life the factor is the constant 1.
+func freshnessFactorSQL(opts HybridSearchOptions, args []interface{}) (string, []interface{}) {
+	if opts.FreshnessHalfLifeDays <= 0 {
+		return "1", args
+	}
+	halfLife, weight := len(args)+1, len(args)+2
+	factor := fmt.Sprintf(
+		"((1 - $%[2]d::float) + $%[2]d::float * power(0.5, GREATEST(EXTRACT(EPOCH FROM (NOW() - COALESCE(d.updated_at, NOW()))), 0) / 86400.0 / $%[1]d::float))",
+		halfLife, weight)
+	return factor, append(args, opts.FreshnessHalfLifeDays, opts.FreshnessWeight)
+}
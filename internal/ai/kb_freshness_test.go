@@ -0,0 +1,66 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeFreshnessHalfLife(t *testing.T) {
+	halfLife, err := normalizeFreshnessHalfLife(nil)
+	require.NoError(t, err)
+	assert.Nil(t, halfLife)
+
+	zero := 0.0
+	halfLife, err = normalizeFreshnessHalfLife(&zero)
+	require.NoError(t, err)
+	assert.Nil(t, halfLife, "zero disables the boost")
+
+	days := 30.0
+	halfLife, err = normalizeFreshnessHalfLife(&days)
+	require.NoError(t, err)
+	require.NotNil(t, halfLife)
+	assert.Equal(t, 30.0, *halfLife)
+
+	negative := -1.0
+	_, err = normalizeFreshnessHalfLife(&negative)
+	assert.ErrorIs(t, err, ErrInvalidFreshnessHalfLife)
+}
+
+func TestChatbotKnowledgeBase_FreshnessHalfLife(t *testing.T) {
+	days := 90.0
+	assert.Equal(t, 0.0, (&ChatbotKnowledgeBase{}).freshnessHalfLife())
+	assert.Equal(t, 90.0, (&ChatbotKnowledgeBase{FreshnessHalfLifeDays: &days}).freshnessHalfLife())
+}
+
+func TestHybridSearchOptions_FreshnessDefaults(t *testing.T) {
+	opts := HybridSearchOptions{}.withDefaults()
+	assert.Zero(t, opts.FreshnessWeight, "no weight without a half-life")
+
+	opts = HybridSearchOptions{FreshnessHalfLifeDays: 30}.withDefaults()
+	assert.Equal(t, DefaultFreshnessWeight, opts.FreshnessWeight)
+
+	opts = HybridSearchOptions{FreshnessHalfLifeDays: 30, FreshnessWeight: 0.8}.withDefaults()
+	assert.Equal(t, 0.8, opts.FreshnessWeight)
+}
+
+func TestFreshnessFactorSQL(t *testing.T) {
+	t.Run("constant without a half-life", func(t *testing.T) {
+		factor, args := freshnessFactorSQL(HybridSearchOptions{}, []interface{}{"kb-1"})
+		assert.Equal(t, "1", factor)
+		assert.Len(t, args, 1)
+	})
+
+	t.Run("numbers parameters after existing arguments", func(t *testing.T) {
+		opts := HybridSearchOptions{FreshnessHalfLifeDays: 30}.withDefaults()
+		factor, args := freshnessFactorSQL(opts, []interface{}{"kb-1", "query", 10})
+
+		assert.Contains(t, factor, "(1 - $5::float)")
+		assert.Contains(t, factor, "d.updated_at")
+		assert.Contains(t, factor, "/ $4::float")
+		require.Len(t, args, 5)
+		assert.Equal(t, 30.0, args[3])
+		assert.Equal(t, DefaultFreshnessWeight, args[4])
+	})
+}
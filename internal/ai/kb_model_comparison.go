@@ -0,0 +1,407 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultComparisonSampleSize = 200
+	maxComparisonSampleSize     = 1000
+	defaultComparisonTopK       = 5
+	maxComparisonTopK           = 20
+	maxComparisonQueries        = 50
+	comparisonEmbedBatchSize    = 100
+)
+
+// EmbeddingComparisonQuery is a golden query used to compare embedding models
+type EmbeddingComparisonQuery struct {
+	Query string `json:"query"`
+	// ExpectedDocumentIDs optionally lists documents that should be retrieved for the query
+	ExpectedDocumentIDs []string `json:"expected_document_ids,omitempty"`
+}
+
+// EmbeddingComparisonRequest is a request to compare a candidate embedding model against the current one
+type EmbeddingComparisonRequest struct {
+	Model      string                     `json:"model"`
+	Queries    []EmbeddingComparisonQuery `json:"queries"`
+	SampleSize int                        `json:"sample_size,omitempty"`
+	TopK       int                        `json:"top_k,omitempty"`
+}
+
+// Validate checks the request and applies defaults
+func (r *EmbeddingComparisonRequest) Validate() error {
+	r.Model = strings.TrimSpace(r.Model)
+	if r.Model == "" {
+		return fmt.Errorf("model is required")
+	}
+	if len(r.Queries) == 0 {
+		return fmt.Errorf("at least one query is required")
+	}
+	if len(r.Queries) > maxComparisonQueries {
+		return fmt.Errorf("at most %d queries are allowed", maxComparisonQueries)
+	}
+	for i, q := range r.Queries {
+		if strings.TrimSpace(q.Query) == "" {
+			return fmt.Errorf("query %d is empty", i+1)
+		}
+	}
+
+	if r.SampleSize <= 0 {
+		r.SampleSize = defaultComparisonSampleSize
+	}
+	if r.SampleSize > maxComparisonSampleSize {
+		return fmt.Errorf("sample_size must be at most %d", maxComparisonSampleSize)
+	}
+	if r.TopK <= 0 {
+		r.TopK = defaultComparisonTopK
+	}
+	if r.TopK > maxComparisonTopK {
+		return fmt.Errorf("top_k must be at most %d", maxComparisonTopK)
+	}
+	return nil
+}
+
+// EmbeddingComparisonHit is a chunk retrieved for a query by one of the models
+type EmbeddingComparisonHit struct {
+	Rank           int     `json:"rank"`
+	ChunkID        string  `json:"chunk_id"`
+	DocumentID     string  `json:"document_id"`
+	DocumentTitle  string  `json:"document_title,omitempty"`
+	ContentPreview string  `json:"content_preview"`
+	Similarity     float64 `json:"similarity"`
+}
+
+// EmbeddingComparisonQueryResult holds the side-by-side results of one query
+type EmbeddingComparisonQueryResult struct {
+	Query     string                   `json:"query"`
+	Baseline  []EmbeddingComparisonHit `json:"baseline"`
+	Candidate []EmbeddingComparisonHit `json:"candidate"`
+	// Overlap is the fraction of top-k chunks retrieved by both models
+	Overlap float64 `json:"overlap"`
+	// Recall of expected documents in the top-k, only set when expected_document_ids were given
+	BaselineRecall  *float64 `json:"baseline_recall,omitempty"`
+	CandidateRecall *float64 `json:"candidate_recall,omitempty"`
+}
+
+// EmbeddingComparisonSummary aggregates the comparison over all queries
+type EmbeddingComparisonSummary struct {
+	AverageOverlap                float64  `json:"average_overlap"`
+	AverageBaselineTopSimilarity  float64  `json:"average_baseline_top_similarity"`
+	AverageCandidateTopSimilarity float64  `json:"average_candidate_top_similarity"`
+	BaselineRecall                *float64 `json:"baseline_recall,omitempty"`
+	CandidateRecall               *float64 `json:"candidate_recall,omitempty"`
+}
+
+// EmbeddingComparisonReport compares retrieval with the current and a candidate embedding model
+// over the same sample of chunks. Nothing is written to the knowledge base.
+type EmbeddingComparisonReport struct {
+	KnowledgeBaseID     string                           `json:"knowledge_base_id"`
+	BaselineModel       string                           `json:"baseline_model"`
+	BaselineDimensions  int                              `json:"baseline_dimensions"`
+	CandidateModel      string                           `json:"candidate_model"`
+	CandidateDimensions int                              `json:"candidate_dimensions"`
+	SampledChunks       int                              `json:"sampled_chunks"`
+	TopK                int                              `json:"top_k"`
+	CandidateTokens     int                              `json:"candidate_tokens,omitempty"`
+	Queries             []EmbeddingComparisonQueryResult `json:"queries"`
+	Summary             EmbeddingComparisonSummary       `json:"summary"`
+	DurationMs          int64                            `json:"duration_ms"`
+}
+
+// sampledChunk is a chunk with its stored embedding
+type sampledChunk struct {
+	ID            string
+	DocumentID    string
+	DocumentTitle string
+	Content       string
+	Embedding     []float32
+}
+
+// SampleChunksWithEmbeddings returns a random sample of embedded chunks of a knowledge base
+func (s *KnowledgeBaseStorage) SampleChunksWithEmbeddings(ctx context.Context, kbID string, limit int) ([]sampledChunk, error) {
+	query := `
+		SELECT c.id, c.document_id, COALESCE(d.title, ''), c.content, c.embedding::text
+		FROM ai.chunks c
+		JOIN ai.documents d ON d.id = c.document_id
+		WHERE c.knowledge_base_id = $1 AND c.embedding IS NOT NULL
+		ORDER BY random()
+		LIMIT $2
+	`
+
+	rows, err := s.db.Query(ctx, query, kbID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []sampledChunk
+	for rows.Next() {
+		var chunk sampledChunk
+		var embedding string
+		if err := rows.Scan(&chunk.ID, &chunk.DocumentID, &chunk.DocumentTitle, &chunk.Content, &embedding); err != nil {
+			return nil, fmt.Errorf("failed to scan chunk: %w", err)
+		}
+		if chunk.Embedding, err = parseEmbeddingLiteral(embedding); err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, rows.Err()
+}
+
+// compareEmbeddingModels re-embeds a sample of the knowledge base with a candidate model and
+// runs the golden queries against the stored and the candidate embeddings of the same sample
+func compareEmbeddingModels(ctx context.Context, kbStorage *KnowledgeBaseStorage, embeddings *EmbeddingService, kbID string, req EmbeddingComparisonRequest) (*EmbeddingComparisonReport, error) {
+	start := time.Now()
+
+	chunks, err := kbStorage.SampleChunksWithEmbeddings(ctx, kbID, req.SampleSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("knowledge base has no embedded chunks")
+	}
+
+	queries := make([]string, len(req.Queries))
+	for i, q := range req.Queries {
+		queries[i] = q.Query
+	}
+
+	// Baseline: queries embedded with the model that indexed the knowledge base
+	baseline, err := embeddings.Embed(ctx, queries, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed queries with current model: %w", err)
+	}
+	baselineDims := len(baseline.Embeddings[0])
+	baselineVectors := make([][]float32, len(chunks))
+	for i, chunk := range chunks {
+		if len(chunk.Embedding) != baselineDims {
+			return nil, fmt.Errorf("stored embeddings have %d dimensions but the current model produces %d; re-index the knowledge base first", len(chunk.Embedding), baselineDims)
+		}
+		baselineVectors[i] = chunk.Embedding
+	}
+
+	// Candidate: queries and sampled chunks embedded with the candidate model
+	candidate, err := embeddings.Embed(ctx, queries, req.Model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed queries with %s: %w", req.Model, err)
+	}
+	candidateTokens := 0
+	if candidate.Usage != nil {
+		candidateTokens += candidate.Usage.TotalTokens
+	}
+	candidateVectors := make([][]float32, 0, len(chunks))
+	for i := 0; i < len(chunks); i += comparisonEmbedBatchSize {
+		end := min(i+comparisonEmbedBatchSize, len(chunks))
+		texts := make([]string, 0, end-i)
+		for _, chunk := range chunks[i:end] {
+			texts = append(texts, chunk.Content)
+		}
+		resp, err := embeddings.Embed(ctx, texts, req.Model)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed chunks with %s: %w", req.Model, err)
+		}
+		if resp.Usage != nil {
+			candidateTokens += resp.Usage.TotalTokens
+		}
+		candidateVectors = append(candidateVectors, resp.Embeddings...)
+	}
+
+	report := &EmbeddingComparisonReport{
+		KnowledgeBaseID:     kbID,
+		BaselineModel:       baseline.Model,
+		BaselineDimensions:  baselineDims,
+		CandidateModel:      candidate.Model,
+		CandidateDimensions: len(candidate.Embeddings[0]),
+		SampledChunks:       len(chunks),
+		TopK:                req.TopK,
+		CandidateTokens:     candidateTokens,
+		Queries:             make([]EmbeddingComparisonQueryResult, len(req.Queries)),
+	}
+
+	for i, q := range req.Queries {
+		result := EmbeddingComparisonQueryResult{
+			Query:     q.Query,
+			Baseline:  rankChunks(chunks, baselineVectors, baseline.Embeddings[i], req.TopK),
+			Candidate: rankChunks(chunks, candidateVectors, candidate.Embeddings[i], req.TopK),
+		}
+		result.Overlap = hitOverlap(result.Baseline, result.Candidate, req.TopK)
+		if len(q.ExpectedDocumentIDs) > 0 {
+			baselineRecall := documentRecall(result.Baseline, q.ExpectedDocumentIDs)
+			candidateRecall := documentRecall(result.Candidate, q.ExpectedDocumentIDs)
+			result.BaselineRecall = &baselineRecall
+			result.CandidateRecall = &candidateRecall
+		}
+		report.Queries[i] = result
+	}
+	report.Summary = summarizeComparison(report.Queries)
+	report.DurationMs = time.Since(start).Milliseconds()
+
+	return report, nil
+}
+
+// rankChunks returns the topK chunks most similar to query
+func rankChunks(chunks []sampledChunk, vectors [][]float32, query []float32, topK int) []EmbeddingComparisonHit {
+	hits := make([]EmbeddingComparisonHit, 0, len(chunks))
+	for i, chunk := range chunks {
+		if i >= len(vectors) || len(vectors[i]) != len(query) {
+			continue
+		}
+		preview := chunk.Content
+		if len(preview) > 200 {
+			preview = preview[:200] + "..."
+		}
+		hits = append(hits, EmbeddingComparisonHit{
+			ChunkID:        chunk.ID,
+			DocumentID:     chunk.DocumentID,
+			DocumentTitle:  chunk.DocumentTitle,
+			ContentPreview: preview,
+			Similarity:     cosineSimilarity(query, vectors[i]),
+		})
+	}
+
+	sort.SliceStable(hits, func(a, b int) bool {
+		return hits[a].Similarity > hits[b].Similarity
+	})
+	if len(hits) > topK {
+		hits = hits[:topK]
+	}
+	for i := range hits {
+		hits[i].Rank = i + 1
+	}
+	return hits
+}
+
+// cosineSimilarity returns the cosine similarity of two vectors of equal length
+func cosineSimilarity(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// hitOverlap returns the fraction of top-k chunks present in both result lists
+func hitOverlap(a, b []EmbeddingComparisonHit, topK int) float64 {
+	if topK == 0 {
+		return 0
+	}
+	seen := make(map[string]bool, len(a))
+	for _, hit := range a {
+		seen[hit.ChunkID] = true
+	}
+	shared := 0
+	for _, hit := range b {
+		if seen[hit.ChunkID] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(topK)
+}
+
+// documentRecall returns the fraction of expected documents that appear in hits
+func documentRecall(hits []EmbeddingComparisonHit, expected []string) float64 {
+	found := make(map[string]bool, len(hits))
+	for _, hit := range hits {
+		found[hit.DocumentID] = true
+	}
+	matched := 0
+	for _, id := range expected {
+		if found[id] {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(expected))
+}
+
+// summarizeComparison averages overlap, top similarity and recall over all queries
+func summarizeComparison(results []EmbeddingComparisonQueryResult) EmbeddingComparisonSummary {
+	var summary EmbeddingComparisonSummary
+	if len(results) == 0 {
+		return summary
+	}
+
+	var baselineRecall, candidateRecall float64
+	recallQueries := 0
+	for _, r := range results {
+		summary.AverageOverlap += r.Overlap
+		if len(r.Baseline) > 0 {
+			summary.AverageBaselineTopSimilarity += r.Baseline[0].Similarity
+		}
+		if len(r.Candidate) > 0 {
+			summary.AverageCandidateTopSimilarity += r.Candidate[0].Similarity
+		}
+		if r.BaselineRecall != nil && r.CandidateRecall != nil {
+			baselineRecall += *r.BaselineRecall
+			candidateRecall += *r.CandidateRecall
+			recallQueries++
+		}
+	}
+
+	n := float64(len(results))
+	summary.AverageOverlap /= n
+	summary.AverageBaselineTopSimilarity /= n
+	summary.AverageCandidateTopSimilarity /= n
+	if recallQueries > 0 {
+		baselineRecall /= float64(recallQueries)
+		candidateRecall /= float64(recallQueries)
+		summary.BaselineRecall = &baselineRecall
+		summary.CandidateRecall = &candidateRecall
+	}
+	return summary
+}
+
+// CompareEmbeddingModels re-embeds a sample of a knowledge base with a candidate model and
+// reports retrieval results of both models side by side
+// POST /api/v1/admin/ai/knowledge-bases/:id/embedding-comparison
+func (h *KnowledgeBaseHandler) CompareEmbeddingModels(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+	kbID := c.Params("id")
+
+	var req EmbeddingComparisonRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
+	}
+
+	if h.processor == nil || h.processor.embeddingService == nil {
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Embedding service not configured")
+	}
+
+	kb, err := h.storage.GetKnowledgeBase(ctx, kbID)
+	if err != nil || kb == nil {
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Knowledge base not found")
+	}
+
+	report, err := compareEmbeddingModels(ctx, h.storage, h.processor.embeddingService, kbID, req)
+	if err != nil {
+		log.Error().Err(err).Str("kb_id", kbID).Str("model", req.Model).Msg("Embedding model comparison failed")
+		return apierror.Send(c, fiber.StatusUnprocessableEntity, apierror.CodeValidationFailed, err.Error())
+	}
+
+	log.Info().
+		Str("kb_id", kbID).
+		Str("baseline_model", report.BaselineModel).
+		Str("candidate_model", report.CandidateModel).
+		Int("sampled_chunks", report.SampledChunks).
+		Float64("average_overlap", report.Summary.AverageOverlap).
+		Msg("Embedding model comparison completed")
+
+	return c.JSON(report)
+}
@@ -0,0 +1,124 @@
+package ai
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddingComparisonRequest_Validate(t *testing.T) {
+	t.Run("applies defaults", func(t *testing.T) {
+		req := EmbeddingComparisonRequest{
+			Model:   " text-embedding-3-large ",
+			Queries: []EmbeddingComparisonQuery{{Query: "reset password"}},
+		}
+		require.NoError(t, req.Validate())
+		assert.Equal(t, "text-embedding-3-large", req.Model)
+		assert.Equal(t, defaultComparisonSampleSize, req.SampleSize)
+		assert.Equal(t, defaultComparisonTopK, req.TopK)
+	})
+
+	tooMany := make([]EmbeddingComparisonQuery, maxComparisonQueries+1)
+	for i := range tooMany {
+		tooMany[i] = EmbeddingComparisonQuery{Query: "q"}
+	}
+	one := []EmbeddingComparisonQuery{{Query: "q"}}
+
+	tests := []struct {
+		name string
+		req  EmbeddingComparisonRequest
+		err  string
+	}{
+		{"missing model", EmbeddingComparisonRequest{Queries: one}, "model is required"},
+		{"missing queries", EmbeddingComparisonRequest{Model: "m"}, "at least one query"},
+		{"too many queries", EmbeddingComparisonRequest{Model: "m", Queries: tooMany}, "at most 50 queries"},
+		{"empty query", EmbeddingComparisonRequest{Model: "m", Queries: []EmbeddingComparisonQuery{{Query: " "}}}, "query 1 is empty"},
+		{"sample too large", EmbeddingComparisonRequest{Model: "m", Queries: one, SampleSize: maxComparisonSampleSize + 1}, "sample_size"},
+		{"top_k too large", EmbeddingComparisonRequest{Model: "m", Queries: one, TopK: maxComparisonTopK + 1}, "top_k"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	assert.InDelta(t, 1.0, cosineSimilarity([]float32{1, 2}, []float32{2, 4}), 1e-9)
+	assert.InDelta(t, 0.0, cosineSimilarity([]float32{1, 0}, []float32{0, 1}), 1e-9)
+	assert.InDelta(t, -1.0, cosineSimilarity([]float32{1, 0}, []float32{-1, 0}), 1e-9)
+	assert.Equal(t, 0.0, cosineSimilarity([]float32{0, 0}, []float32{1, 1}))
+}
+
+func TestRankChunks(t *testing.T) {
+	chunks := []sampledChunk{
+		{ID: "c1", DocumentID: "d1", Content: "billing"},
+		{ID: "c2", DocumentID: "d2", Content: strings.Repeat("x", 250)},
+		{ID: "c3", DocumentID: "d3", Content: "mismatched dimensions"},
+	}
+	vectors := [][]float32{{0, 1}, {1, 0}, {1, 0, 0}}
+
+	hits := rankChunks(chunks, vectors, []float32{1, 0.1}, 5)
+
+	require.Len(t, hits, 2)
+	assert.Equal(t, "c2", hits[0].ChunkID)
+	assert.Equal(t, 1, hits[0].Rank)
+	assert.Equal(t, "c1", hits[1].ChunkID)
+	assert.Equal(t, 2, hits[1].Rank)
+	assert.Len(t, hits[0].ContentPreview, 203)
+
+	assert.Len(t, rankChunks(chunks, vectors, []float32{1, 0.1}, 1), 1)
+}
+
+func TestComparisonMetrics(t *testing.T) {
+	baseline := []EmbeddingComparisonHit{{ChunkID: "a", DocumentID: "d1", Similarity: 0.9}, {ChunkID: "b", DocumentID: "d2", Similarity: 0.8}}
+	candidate := []EmbeddingComparisonHit{{ChunkID: "b", DocumentID: "d2", Similarity: 0.7}, {ChunkID: "c", DocumentID: "d3", Similarity: 0.6}}
+
+	assert.InDelta(t, 0.5, hitOverlap(baseline, candidate, 2), 1e-9)
+	assert.InDelta(t, 0.5, documentRecall(baseline, []string{"d1", "d3"}), 1e-9)
+	assert.InDelta(t, 0.5, documentRecall(candidate, []string{"d1", "d3"}), 1e-9)
+
+	full, none := 1.0, 0.0
+	summary := summarizeComparison([]EmbeddingComparisonQueryResult{
+		{Baseline: baseline, Candidate: candidate, Overlap: 0.5, BaselineRecall: &full, CandidateRecall: &none},
+		{Baseline: baseline[:1], Overlap: 0},
+	})
+
+	assert.InDelta(t, 0.25, summary.AverageOverlap, 1e-9)
+	assert.InDelta(t, 0.9, summary.AverageBaselineTopSimilarity, 1e-9)
+	assert.InDelta(t, 0.35, summary.AverageCandidateTopSimilarity, 1e-9)
+	require.NotNil(t, summary.BaselineRecall)
+	assert.InDelta(t, 1.0, *summary.BaselineRecall, 1e-9)
+	assert.InDelta(t, 0.0, *summary.CandidateRecall, 1e-9)
+
+	assert.Nil(t, summarizeComparison([]EmbeddingComparisonQueryResult{{Overlap: 1}}).BaselineRecall)
+}
+
+func TestKnowledgeBaseHandler_CompareEmbeddingModelsValidation(t *testing.T) {
+	app := fiber.New()
+	handler := NewKnowledgeBaseHandler(&KnowledgeBaseStorage{}, nil)
+	app.Post("/knowledge-bases/:id/embedding-comparison", handler.CompareEmbeddingModels)
+
+	tests := []struct {
+		body   string
+		status int
+	}{
+		{`not json`, fiber.StatusBadRequest},
+		{`{"queries":[{"query":"q"}]}`, fiber.StatusBadRequest},
+		{`{"model":"m","queries":[]}`, fiber.StatusBadRequest},
+		{`{"model":"m","queries":[{"query":"q"}]}`, fiber.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/knowledge-bases/kb-1/embedding-comparison", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, tt.status, resp.StatusCode, tt.body)
+	}
+}
@@ -2,6 +2,7 @@ package ai
 
 import (
 	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
 )
 
 // RequireKBPermission creates a middleware that checks if the user has the required
@@ -21,9 +22,7 @@ func RequireKBPermission(storage *KnowledgeBaseStorage, requiredPermission strin
 		// Get user ID from context (set by auth middleware)
 		userID, ok := c.Locals("user_id").(string)
 		if !ok || userID == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Authentication required",
-			})
+			return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeAuthRequired, "Authentication required")
 		}
 
 		// Get KB ID from URL params - try "id" first, then "kb_id"
@@ -32,23 +31,17 @@ func RequireKBPermission(storage *KnowledgeBaseStorage, requiredPermission strin
 			kbID = c.Params("kb_id")
 		}
 		if kbID == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Knowledge base ID is required",
-			})
+			return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Knowledge base ID is required")
 		}
 
 		// Check if user has the required permission
 		hasPermission, err := storage.CheckKBPermission(ctx, kbID, userID, requiredPermission)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to check permission",
-			})
+			return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to check permission")
 		}
 
 		if !hasPermission {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Insufficient permissions",
-			})
+			return apierror.Send(c, fiber.StatusForbidden, apierror.CodeInsufficientPermissions, "Insufficient permissions")
 		}
 
 		// Store the user's permission level in context for later use
@@ -0,0 +1,116 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// OCRMode controls when OCR is used to extract text from a PDF
+type OCRMode string
+
+const (
+	OCRModeAuto   OCRMode = "auto"   // OCR when the embedded text is missing or unreadable
+	OCRModeAlways OCRMode = "always" // OCR every page, e.g. for scans with a poor text layer
+	OCRModeNever  OCRMode = "never"  // Only use the embedded text
+)
+
+// maxOCRLanguages caps the languages Tesseract loads for one document
+const maxOCRLanguages = 5
+
+// ocrLanguagePattern matches Tesseract language codes such as eng, deu or chi_sim
+var ocrLanguagePattern = regexp.MustCompile(`^[a-z]{3}(_[a-z]+)?$`)
+
+var (
+	// ErrInvalidOCRMode is returned for an unsupported ocr processing option
+	ErrInvalidOCRMode = errors.New("processing_options.ocr must be one of: auto, always, never")
+	// ErrInvalidOCRLanguages is returned for malformed or too many OCR languages
+	ErrInvalidOCRLanguages = fmt.Errorf("processing_options.ocr_languages must be at most %d Tesseract language codes such as eng or chi_sim", maxOCRLanguages)
+)
+
+// ProcessingOptions controls how an uploaded file is turned into text before chunking. A
+// knowledge base sets the defaults for its uploads, and each upload can override them.
+type ProcessingOptions struct {
+	OCR           OCRMode  `json:"ocr,omitempty"`
+	OCRLanguages  []string `json:"ocr_languages,omitempty"`  // Empty uses ai.ocr_languages
+	ExtractTables *bool    `json:"extract_tables,omitempty"` // Serialize PDF tables to markdown
+}
+
+// Validate checks the options; zero values use the defaults
+func (o ProcessingOptions) Validate() error {
+	switch o.OCR {
+	case "", OCRModeAuto, OCRModeAlways, OCRModeNever:
+	default:
+		return ErrInvalidOCRMode
+	}
+	if len(o.OCRLanguages) > maxOCRLanguages {
+		return ErrInvalidOCRLanguages
+	}
+	for _, lang := range o.OCRLanguages {
+		if !ocrLanguagePattern.MatchString(lang) {
+			return ErrInvalidOCRLanguages
+		}
+	}
+	return nil
+}
+
+// Merge returns the options with the fields set in override replacing their own
+func (o ProcessingOptions) Merge(override ProcessingOptions) ProcessingOptions {
+	if override.OCR != "" {
+		o.OCR = override.OCR
+	}
+	if len(override.OCRLanguages) > 0 {
+		o.OCRLanguages = override.OCRLanguages
+	}
+	if override.ExtractTables != nil {
+		o.ExtractTables = override.ExtractTables
+	}
+	return o
+}
+
+// ocrMode returns the OCR mode, defaulting to auto
+func (o ProcessingOptions) ocrMode() OCRMode {
+	if o.OCR == "" {
+		return OCRModeAuto
+	}
+	return o.OCR
+}
+
+// extractTables reports whether PDF tables are serialized to markdown
+func (o ProcessingOptions) extractTables() bool {
+	return o.ExtractTables != nil && *o.ExtractTables
+}
+
+// ParseUploadProcessingOptions reads the processing options of a file upload from the
+// processing_options form field (JSON) and the older comma-separated language field
+func ParseUploadProcessingOptions(optionsJSON, languages string) (ProcessingOptions, error) {
+	var opts ProcessingOptions
+	if strings.TrimSpace(optionsJSON) != "" {
+		if err := json.Unmarshal([]byte(optionsJSON), &opts); err != nil {
+			return opts, fmt.Errorf("processing_options must be a JSON object: %w", err)
+		}
+	}
+	if len(opts.OCRLanguages) == 0 {
+		for _, lang := range strings.Split(languages, ",") {
+			if lang = strings.TrimSpace(lang); lang != "" {
+				opts.OCRLanguages = append(opts.OCRLanguages, lang)
+			}
+		}
+	}
+	return opts, opts.Validate()
+}
+
+// validateProcessingOptions checks the processing options of a create request
+func (r CreateKnowledgeBaseRequest) validateProcessingOptions() error {
+	return r.ProcessingOptions.Validate()
+}
+
+// validateProcessingOptions checks the processing options of an update request
+func (r UpdateKnowledgeBaseRequest) validateProcessingOptions() error {
+	if r.ProcessingOptions == nil {
+		return nil
+	}
+	return r.ProcessingOptions.Validate()
+}
@@ -0,0 +1,56 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessingOptions_Validate(t *testing.T) {
+	assert.NoError(t, ProcessingOptions{}.Validate())
+	assert.NoError(t, ProcessingOptions{OCR: OCRModeAlways, OCRLanguages: []string{"eng", "chi_sim"}}.Validate())
+	assert.ErrorIs(t, ProcessingOptions{OCR: "sometimes"}.Validate(), ErrInvalidOCRMode)
+	assert.ErrorIs(t, ProcessingOptions{OCRLanguages: []string{"--tessdata-dir"}}.Validate(), ErrInvalidOCRLanguages)
+	assert.ErrorIs(t, ProcessingOptions{OCRLanguages: []string{"eng", "deu", "fra", "nld", "spa", "ita"}}.Validate(), ErrInvalidOCRLanguages)
+}
+
+func TestProcessingOptions_Merge(t *testing.T) {
+	tables, noTables := true, false
+	kbOptions := ProcessingOptions{OCR: OCRModeNever, OCRLanguages: []string{"deu"}, ExtractTables: &tables}
+
+	assert.Equal(t, kbOptions, kbOptions.Merge(ProcessingOptions{}))
+
+	merged := kbOptions.Merge(ProcessingOptions{OCR: OCRModeAlways, ExtractTables: &noTables})
+	assert.Equal(t, OCRModeAlways, merged.OCR)
+	assert.Equal(t, []string{"deu"}, merged.OCRLanguages)
+	assert.False(t, merged.extractTables())
+	assert.Equal(t, OCRModeAuto, ProcessingOptions{}.ocrMode())
+}
+
+func TestParseUploadProcessingOptions(t *testing.T) {
+	t.Run("reads the JSON field", func(t *testing.T) {
+		opts, err := ParseUploadProcessingOptions(`{"ocr":"always","extract_tables":true}`, "")
+		require.NoError(t, err)
+		assert.Equal(t, OCRModeAlways, opts.OCR)
+		assert.True(t, opts.extractTables())
+	})
+
+	t.Run("falls back to the language field", func(t *testing.T) {
+		opts, err := ParseUploadProcessingOptions("", "eng, deu")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"eng", "deu"}, opts.OCRLanguages)
+
+		opts, err = ParseUploadProcessingOptions(`{"ocr_languages":["fra"]}`, "eng")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"fra"}, opts.OCRLanguages)
+	})
+
+	t.Run("rejects invalid options", func(t *testing.T) {
+		_, err := ParseUploadProcessingOptions("ocr=always", "")
+		assert.ErrorContains(t, err, "must be a JSON object")
+
+		_, err = ParseUploadProcessingOptions(`{"ocr":"maybe"}`, "")
+		assert.ErrorIs(t, err, ErrInvalidOCRMode)
+	})
+}
@@ -0,0 +1,297 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// Re-index job statuses
+const (
+	KBReindexStatusPending   = "pending"
+	KBReindexStatusRunning   = "running"
+	KBReindexStatusCompleted = "completed"
+	KBReindexStatusFailed    = "failed"
+)
+
+// kbReindexStaleAfter is how long an active job may go without a progress update before it is
+// considered interrupted (e.g. by a restart). It exceeds the per-document processing timeout.
+const kbReindexStaleAfter = 10 * time.Minute
+
+// kbReindexDocumentTimeout bounds the processing of a single document during a re-index
+const kbReindexDocumentTimeout = 5 * time.Minute
+
+// ErrKBReindexInProgress is returned when a knowledge base already has an active re-index job
+var ErrKBReindexInProgress = errors.New("a re-index is already in progress for this knowledge base")
+
+// KBReindexJob tracks the background re-chunking and re-embedding of a knowledge base
+type KBReindexJob struct {
+	ID                 string     `json:"id"`
+	KnowledgeBaseID    string     `json:"knowledge_base_id"`
+	Status             string     `json:"status"`
+	TotalDocuments     int        `json:"total_documents"`
+	ProcessedDocuments int        `json:"processed_documents"`
+	FailedDocuments    int        `json:"failed_documents"`
+	Progress           float64    `json:"progress"`
+	ErrorMessage       *string    `json:"error_message,omitempty"`
+	CreatedBy          *string    `json:"created_by,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	StartedAt          *time.Time `json:"started_at,omitempty"`
+	CompletedAt        *time.Time `json:"completed_at,omitempty"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// computeProgress sets Progress to the fraction (0-1) of documents the job has reached
+func (j *KBReindexJob) computeProgress() {
+	switch {
+	case j.Status == KBReindexStatusCompleted:
+		j.Progress = 1
+	case j.TotalDocuments > 0:
+		j.Progress = float64(j.ProcessedDocuments) / float64(j.TotalDocuments)
+	default:
+		j.Progress = 0
+	}
+}
+
+// ============================================================================
+// KNOWLEDGE BASE RE-INDEX OPERATIONS
+// ============================================================================
+
+const kbReindexJobColumns = `
+	id, knowledge_base_id, status, total_documents, processed_documents, failed_documents,
+	error_message, created_by, created_at, started_at, completed_at, updated_at
+`
+
+// ReindexKnowledgeBase creates a re-index job for a knowledge base and marks its documents
+// pending. The job is run by DocumentProcessor.RunReindexJob. Active jobs that stopped
+// reporting progress are failed first so an interrupted re-index can be restarted.
+func (s *KnowledgeBaseStorage) ReindexKnowledgeBase(ctx context.Context, kbID string, createdBy *string) (*KBReindexJob, error) {
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Serialize re-index requests for the knowledge base
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM ai.knowledge_bases WHERE id = $1 FOR UPDATE`, kbID); err != nil {
+		return nil, fmt.Errorf("failed to lock knowledge base: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE ai.knowledge_base_reindex_jobs
+		SET status = 'failed', error_message = 'Re-index was interrupted', completed_at = NOW(), updated_at = NOW()
+		WHERE knowledge_base_id = $1
+		  AND status IN ('pending', 'running')
+		  AND updated_at < NOW() - make_interval(secs => $2)
+	`, kbID, kbReindexStaleAfter.Seconds()); err != nil {
+		return nil, fmt.Errorf("failed to expire interrupted re-index jobs: %w", err)
+	}
+
+	var active bool
+	if err := tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM ai.knowledge_base_reindex_jobs
+			WHERE knowledge_base_id = $1 AND status IN ('pending', 'running')
+		)
+	`, kbID).Scan(&active); err != nil {
+		return nil, fmt.Errorf("failed to check for active re-index jobs: %w", err)
+	}
+	if active {
+		return nil, ErrKBReindexInProgress
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE ai.documents SET status = 'pending', error_message = '', updated_at = NOW()
+		WHERE knowledge_base_id = $1
+	`, kbID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark documents pending: %w", err)
+	}
+
+	job, err := scanKBReindexJob(tx.QueryRow(ctx, `
+		INSERT INTO ai.knowledge_base_reindex_jobs (knowledge_base_id, total_documents, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING `+kbReindexJobColumns,
+		kbID, tag.RowsAffected(), createdBy,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create re-index job: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit re-index job: %w", err)
+	}
+	return job, nil
+}
+
+// GetLatestKBReindexJob returns the most recent re-index job of a knowledge base, or nil
+func (s *KnowledgeBaseStorage) GetLatestKBReindexJob(ctx context.Context, kbID string) (*KBReindexJob, error) {
+	job, err := scanKBReindexJob(s.db.QueryRow(ctx, `
+		SELECT `+kbReindexJobColumns+`
+		FROM ai.knowledge_base_reindex_jobs
+		WHERE knowledge_base_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, kbID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get re-index job: %w", err)
+	}
+	return job, nil
+}
+
+// listKBReindexDocumentIDs returns the documents a re-index job covers: those that existed when
+// it was created. Later documents are processed by their own upload.
+func (s *KnowledgeBaseStorage) listKBReindexDocumentIDs(ctx context.Context, job *KBReindexJob) ([]string, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id FROM ai.documents
+		WHERE knowledge_base_id = $1 AND created_at <= $2
+		ORDER BY created_at
+	`, job.KnowledgeBaseID, job.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan document id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// updateKBReindexJob persists a job's status and progress
+func (s *KnowledgeBaseStorage) updateKBReindexJob(ctx context.Context, job *KBReindexJob) error {
+	job.computeProgress()
+	return s.db.QueryRow(ctx, `
+		UPDATE ai.knowledge_base_reindex_jobs SET
+			status = $2,
+			total_documents = $3,
+			processed_documents = $4,
+			failed_documents = $5,
+			error_message = $6,
+			started_at = $7,
+			completed_at = $8,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`, job.ID, job.Status, job.TotalDocuments, job.ProcessedDocuments, job.FailedDocuments,
+		job.ErrorMessage, job.StartedAt, job.CompletedAt,
+	).Scan(&job.UpdatedAt)
+}
+
+// scanKBReindexJob scans a row selected with kbReindexJobColumns
+func scanKBReindexJob(row pgx.Row) (*KBReindexJob, error) {
+	var job KBReindexJob
+	if err := row.Scan(
+		&job.ID, &job.KnowledgeBaseID, &job.Status, &job.TotalDocuments, &job.ProcessedDocuments, &job.FailedDocuments,
+		&job.ErrorMessage, &job.CreatedBy, &job.CreatedAt, &job.StartedAt, &job.CompletedAt, &job.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	job.computeProgress()
+	return &job, nil
+}
+
+// RunReindexJob re-chunks and re-embeds the documents of a re-index job with the knowledge
+// base's current chunking configuration, recording progress after every document. A failing
+// document is marked failed and counted; the job only fails when it cannot continue.
+func (p *DocumentProcessor) RunReindexJob(ctx context.Context, job *KBReindexJob) error {
+	fail := func(err error) error {
+		msg := err.Error()
+		now := time.Now()
+		job.Status = KBReindexStatusFailed
+		job.ErrorMessage = &msg
+		job.CompletedAt = &now
+		if updateErr := p.storage.updateKBReindexJob(context.WithoutCancel(ctx), job); updateErr != nil {
+			log.Warn().Err(updateErr).Str("job_id", job.ID).Msg("Failed to record re-index failure")
+		}
+		return err
+	}
+
+	kb, err := p.storage.GetKnowledgeBase(ctx, job.KnowledgeBaseID)
+	if err != nil {
+		return fail(err)
+	}
+	if kb == nil {
+		return fail(fmt.Errorf("knowledge base not found"))
+	}
+
+	docIDs, err := p.storage.listKBReindexDocumentIDs(ctx, job)
+	if err != nil {
+		return fail(err)
+	}
+
+	startedAt := time.Now()
+	job.Status = KBReindexStatusRunning
+	job.StartedAt = &startedAt
+	job.TotalDocuments = len(docIDs)
+	if err := p.storage.updateKBReindexJob(ctx, job); err != nil {
+		return fail(fmt.Errorf("failed to start re-index job: %w", err))
+	}
+
+	log.Info().Str("kb_id", kb.ID).Str("job_id", job.ID).Int("documents", len(docIDs)).Msg("Re-indexing knowledge base")
+
+	opts := ProcessDocumentOptions{
+		ChunkSize:     kb.ChunkSize,
+		ChunkOverlap:  kb.ChunkOverlap,
+		ChunkStrategy: ChunkingStrategy(kb.ChunkStrategy),
+	}
+
+	for _, docID := range docIDs {
+		if ctx.Err() != nil {
+			return fail(ctx.Err())
+		}
+
+		if err := p.reindexDocument(ctx, docID, opts); err != nil {
+			job.FailedDocuments++
+			log.Warn().Err(err).Str("doc_id", docID).Str("job_id", job.ID).Msg("Failed to re-index document")
+		}
+		job.ProcessedDocuments++
+
+		if err := p.storage.updateKBReindexJob(ctx, job); err != nil {
+			log.Warn().Err(err).Str("job_id", job.ID).Msg("Failed to record re-index progress")
+		}
+	}
+
+	completedAt := time.Now()
+	job.Status = KBReindexStatusCompleted
+	job.CompletedAt = &completedAt
+	if err := p.storage.updateKBReindexJob(ctx, job); err != nil {
+		return fmt.Errorf("failed to complete re-index job: %w", err)
+	}
+
+	log.Info().
+		Str("kb_id", kb.ID).
+		Str("job_id", job.ID).
+		Int("processed", job.ProcessedDocuments).
+		Int("failed", job.FailedDocuments).
+		Msg("Knowledge base re-index complete")
+	return nil
+}
+
+// reindexDocument re-processes a single document of a re-index job. Documents deleted since
+// the job started are skipped.
+func (p *DocumentProcessor) reindexDocument(ctx context.Context, docID string, opts ProcessDocumentOptions) error {
+	docCtx, cancel := context.WithTimeout(ctx, kbReindexDocumentTimeout)
+	defer cancel()
+
+	doc, err := p.storage.GetDocument(docCtx, docID)
+	if err != nil {
+		return err
+	}
+	if doc == nil {
+		return nil
+	}
+	return p.ProcessDocument(docCtx, doc, opts)
+}
@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// KNOWLEDGE BASE RE-INDEX ENDPOINTS
+// ============================================================================
+
+// ReindexKnowledgeBase starts re-chunking and re-embedding all documents of a knowledge base
+// with its current configuration. Progress is available from GetKBReindexStatus.
+// POST /api/v1/admin/ai/knowledge-bases/:id/reindex
+func (h *KnowledgeBaseHandler) ReindexKnowledgeBase(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+	kbID := c.Params("id")
+
+	if h.processor == nil {
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Document processing is not configured")
+	}
+
+	kb, err := h.storage.GetKnowledgeBase(ctx, kbID)
+	if err != nil || kb == nil {
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Knowledge base not found")
+	}
+
+	job, err := h.storage.ReindexKnowledgeBase(ctx, kbID, snapshotUserID(c))
+	if errors.Is(err, ErrKBReindexInProgress) {
+		return apierror.Send(c, fiber.StatusConflict, apierror.CodeConflict, err.Error())
+	}
+	if err != nil {
+		log.Error().Err(err).Str("kb_id", kbID).Msg("Failed to start knowledge base re-index")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to start re-index")
+	}
+
+	// Run the re-index in the background; it outlives the request
+	go func(job KBReindexJob) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				log.Error().
+					Interface("panic", rec).
+					Str("job_id", job.ID).
+					Str("goroutine", "ai_kb_reindex").
+					Msg("Panic in knowledge base re-index - recovered")
+			}
+		}()
+
+		if err := h.processor.RunReindexJob(context.Background(), &job); err != nil {
+			log.Error().Err(err).Str("kb_id", job.KnowledgeBaseID).Str("job_id", job.ID).Msg("Knowledge base re-index failed")
+		}
+	}(*job)
+
+	return c.Status(fiber.StatusAccepted).JSON(job)
+}
+
+// GetKBReindexStatus returns the progress of a knowledge base's latest re-index
+// GET /api/v1/admin/ai/knowledge-bases/:id/reindex
+func (h *KnowledgeBaseHandler) GetKBReindexStatus(c fiber.Ctx) error {
+	kbID := c.Params("id")
+
+	job, err := h.storage.GetLatestKBReindexJob(c.RequestCtx(), kbID)
+	if err != nil {
+		log.Error().Err(err).Str("kb_id", kbID).Msg("Failed to get knowledge base re-index status")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get re-index status")
+	}
+	if job == nil {
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Knowledge base has not been re-indexed")
+	}
+
+	return c.JSON(job)
+}
@@ -0,0 +1,51 @@
+package ai
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKBReindexJob_ComputeProgress(t *testing.T) {
+	t.Run("fraction of documents reached", func(t *testing.T) {
+		job := &KBReindexJob{Status: KBReindexStatusRunning, TotalDocuments: 4, ProcessedDocuments: 1}
+		job.computeProgress()
+		assert.Equal(t, 0.25, job.Progress)
+	})
+
+	t.Run("failed documents count as reached", func(t *testing.T) {
+		job := &KBReindexJob{Status: KBReindexStatusRunning, TotalDocuments: 2, ProcessedDocuments: 2, FailedDocuments: 1}
+		job.computeProgress()
+		assert.Equal(t, 1.0, job.Progress)
+	})
+
+	t.Run("empty pending job has no progress", func(t *testing.T) {
+		job := &KBReindexJob{Status: KBReindexStatusPending}
+		job.computeProgress()
+		assert.Zero(t, job.Progress)
+	})
+
+	t.Run("completed job is done even without documents", func(t *testing.T) {
+		job := &KBReindexJob{Status: KBReindexStatusCompleted}
+		job.computeProgress()
+		assert.Equal(t, 1.0, job.Progress)
+	})
+}
+
+func TestKBReindexStaleAfter_ExceedsDocumentTimeout(t *testing.T) {
+	// A job processing a slow document must not be considered interrupted
+	assert.Greater(t, kbReindexStaleAfter, kbReindexDocumentTimeout)
+}
+
+func TestKnowledgeBaseHandler_ReindexRequiresProcessor(t *testing.T) {
+	app := fiber.New()
+	handler := NewKnowledgeBaseHandler(&KnowledgeBaseStorage{}, nil)
+	app.Post("/knowledge-bases/:id/reindex", handler.ReindexKnowledgeBase)
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/knowledge-bases/kb-1/reindex", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+}
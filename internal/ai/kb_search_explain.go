@@ -0,0 +1,381 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultExplainCandidates = 50
+	maxExplainCandidates     = 500
+)
+
+// Reasons a search candidate was left out of the results
+const (
+	CutReasonFilter         = "filter"           // Document did not match the metadata/tag filter
+	CutReasonNoEmbedding    = "no_embedding"     // Chunk has no embedding, so it cannot be scored semantically
+	CutReasonNoKeywordMatch = "no_keyword_match" // Keyword mode only returns chunks that match the query text
+	CutReasonThreshold      = "threshold"        // Score was below the threshold
+	CutReasonLimit          = "limit"            // Scored high enough, but max_chunks results ranked higher
+)
+
+// SearchExplainRequest is a dry-run search that explains how each candidate chunk was scored.
+// Search parameters and defaults are the same as for the search endpoint.
+type SearchExplainRequest struct {
+	Query          string            `json:"query"`
+	MaxChunks      int               `json:"max_chunks,omitempty"`
+	Threshold      float64           `json:"threshold,omitempty"`
+	Mode           string            `json:"mode,omitempty"`            // "semantic", "keyword", or "hybrid"
+	SemanticWeight float64           `json:"semantic_weight,omitempty"` // For hybrid mode: 0-1, default 0.5
+	KeywordBoost   float64           `json:"keyword_boost,omitempty"`   // For hybrid mode: added when the chunk contains the query, default 0.3
+	Tags           []string          `json:"tags,omitempty"`            // For hybrid mode: documents must have all these tags
+	Metadata       map[string]string `json:"metadata,omitempty"`        // For hybrid mode: exact match on document metadata
+	// Candidates is the number of top scoring chunks to explain, including the ones that were cut
+	Candidates int `json:"candidates,omitempty"`
+}
+
+// Validate checks the request and applies defaults
+func (r *SearchExplainRequest) Validate() error {
+	r.Query = strings.TrimSpace(r.Query)
+	if r.Query == "" {
+		return fmt.Errorf("query is required")
+	}
+
+	switch r.Mode {
+	case "":
+		r.Mode = string(SearchModeSemantic)
+	case string(SearchModeSemantic), string(SearchModeKeyword), string(SearchModeHybrid):
+	default:
+		return fmt.Errorf("mode must be semantic, keyword or hybrid")
+	}
+	if r.SemanticWeight < 0 || r.SemanticWeight > 1 {
+		return fmt.Errorf("semantic_weight must be between 0 and 1")
+	}
+
+	if r.MaxChunks <= 0 {
+		r.MaxChunks = 10
+	}
+	if r.Threshold == 0 {
+		r.Threshold = 0.2
+	}
+	if r.Candidates <= 0 {
+		r.Candidates = defaultExplainCandidates
+	}
+	if r.Candidates > maxExplainCandidates {
+		return fmt.Errorf("candidates must be at most %d", maxExplainCandidates)
+	}
+	if r.Candidates < r.MaxChunks {
+		r.Candidates = r.MaxChunks
+	}
+	return nil
+}
+
+// searchOptions returns the hybrid search options the request would run with
+func (r *SearchExplainRequest) searchOptions(embedding []float32) HybridSearchOptions {
+	opts := HybridSearchOptions{
+		Query:          r.Query,
+		QueryEmbedding: embedding,
+		Limit:          r.MaxChunks,
+		Threshold:      r.Threshold,
+		Mode:           SearchMode(r.Mode),
+		SemanticWeight: r.SemanticWeight,
+		KeywordBoost:   r.KeywordBoost,
+	}
+	if len(r.Tags) > 0 || len(r.Metadata) > 0 {
+		opts.Filter = &MetadataFilter{Tags: r.Tags, Metadata: r.Metadata}
+	}
+	return opts.withDefaults()
+}
+
+// SearchCandidate is a chunk considered by a search, with its component scores
+type SearchCandidate struct {
+	Rank           int    `json:"rank,omitempty"` // Position in the results, 0 if the chunk was cut
+	ChunkID        string `json:"chunk_id"`
+	DocumentID     string `json:"document_id"`
+	DocumentTitle  string `json:"document_title,omitempty"`
+	ContentPreview string `json:"content_preview"`
+	// Score is the final score the threshold is applied to
+	Score float64 `json:"score"`
+	// VectorSimilarity is the cosine similarity to the query, nil if the chunk has no embedding
+	VectorSimilarity *float64 `json:"vector_similarity,omitempty"`
+	// TextRank is the full-text rank (ts_rank_cd) of the chunk for the query
+	TextRank float64 `json:"text_rank"`
+	// KeywordMatch is true if the chunk matches the query in full-text search
+	KeywordMatch bool `json:"keyword_match"`
+	// PhraseMatch is true if the chunk contains the query as a substring, which earns the keyword boost
+	PhraseMatch bool    `json:"phrase_match"`
+	Boost       float64 `json:"boost"`
+	// PassesFilter is false if the document is excluded by the filter
+	PassesFilter bool   `json:"passes_filter"`
+	Included     bool   `json:"included"`
+	CutReason    string `json:"cut_reason,omitempty"`
+}
+
+// SearchExplanation is the result of a dry-run search
+type SearchExplanation struct {
+	KnowledgeBaseID string  `json:"knowledge_base_id"`
+	Query           string  `json:"query"`
+	Mode            string  `json:"mode"`
+	Threshold       float64 `json:"threshold"`
+	MaxChunks       int     `json:"max_chunks"`
+	SemanticWeight  float64 `json:"semantic_weight"`
+	KeywordWeight   float64 `json:"keyword_weight"`
+	KeywordBoost    float64 `json:"keyword_boost"`
+	// FiltersApplied lists the filters that were applied, e.g. "tags" or "metadata.lang"
+	FiltersApplied []string  `json:"filters_applied"`
+	EmbeddingModel string    `json:"embedding_model,omitempty"`
+	EmbeddingDims  int       `json:"embedding_dims"`
+	QueryEmbedding []float32 `json:"query_embedding,omitempty"`
+	// Candidates are ordered by score, included and cut chunks alike
+	Candidates []SearchCandidate `json:"candidates"`
+	Returned   int               `json:"returned"`
+	// CutCounts counts the candidates cut for each reason
+	CutCounts map[string]int `json:"cut_counts"`
+}
+
+// searchScoreWeights returns the semantic weight, keyword weight and keyword boost a search mode scores with
+func searchScoreWeights(opts HybridSearchOptions) (semantic, keyword, boost float64) {
+	switch opts.Mode {
+	case SearchModeKeyword:
+		return 0, 1, 0
+	case SearchModeHybrid:
+		return opts.SemanticWeight, 1 - opts.SemanticWeight, opts.KeywordBoost
+	default:
+		return 1, 0, 0
+	}
+}
+
+// ExplainSearchCandidates returns the top scoring chunks of a knowledge base for a search with the
+// component scores of each. Filters are evaluated per candidate instead of excluding chunks.
+func (s *KnowledgeBaseStorage) ExplainSearchCandidates(ctx context.Context, knowledgeBaseID string, opts HybridSearchOptions, candidates int) ([]SearchCandidate, error) {
+	semanticWeight, keywordWeight, keywordBoost := searchScoreWeights(opts)
+
+	var embedding *string
+	if len(opts.QueryEmbedding) > 0 {
+		literal := formatEmbeddingLiteral(opts.QueryEmbedding)
+		embedding = &literal
+	}
+
+	args := []interface{}{knowledgeBaseID, opts.Query, embedding, semanticWeight, keywordWeight, keywordBoost, candidates}
+	filterConditions := ""
+	if opts.Mode == SearchModeHybrid {
+		filterConditions, args = hybridFilterConditions(opts.Filter, args)
+	}
+
+	query := fmt.Sprintf(`
+		WITH scored AS (
+			SELECT
+				c.id as chunk_id,
+				c.document_id,
+				COALESCE(d.title, '') as document_title,
+				c.content,
+				1 - (c.embedding <=> $3::vector) as vector_similarity,
+				ts_rank_cd(to_tsvector('simple', c.content), plainto_tsquery('simple', $2)) as text_rank,
+				to_tsvector('simple', c.content) @@ plainto_tsquery('simple', $2) as keyword_match,
+				c.content ILIKE '%%' || $2 || '%%' as phrase_match,
+				(TRUE %s) as passes_filter
+			FROM ai.chunks c
+			JOIN ai.documents d ON d.id = c.document_id
+			WHERE c.knowledge_base_id = $1
+		)
+		SELECT chunk_id, document_id, document_title, content, vector_similarity, text_rank, keyword_match, phrase_match, passes_filter
+		FROM scored
+		ORDER BY
+			($4::float * COALESCE(vector_similarity, 0)) + ($5::float * text_rank) + CASE WHEN phrase_match THEN $6::float ELSE 0 END DESC,
+			chunk_id
+		LIMIT $7
+	`, filterConditions)
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain search: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SearchCandidate
+	for rows.Next() {
+		var candidate SearchCandidate
+		var content string
+		if err := rows.Scan(
+			&candidate.ChunkID, &candidate.DocumentID, &candidate.DocumentTitle, &content,
+			&candidate.VectorSimilarity, &candidate.TextRank, &candidate.KeywordMatch, &candidate.PhraseMatch,
+			&candidate.PassesFilter,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan search candidate: %w", err)
+		}
+		candidate.ContentPreview = content
+		if len(candidate.ContentPreview) > 200 {
+			candidate.ContentPreview = candidate.ContentPreview[:200] + "..."
+		}
+		results = append(results, candidate)
+	}
+	return results, rows.Err()
+}
+
+// classifySearchCandidates scores the candidates the way the search endpoint does, orders them by
+// score, and marks which would be returned and why the others were cut
+func classifySearchCandidates(candidates []SearchCandidate, opts HybridSearchOptions) []SearchCandidate {
+	semanticWeight, keywordWeight, keywordBoost := searchScoreWeights(opts)
+
+	for i := range candidates {
+		c := &candidates[i]
+		c.Boost = 0
+		if c.PhraseMatch {
+			c.Boost = keywordBoost
+		}
+		vector := 0.0
+		if c.VectorSimilarity != nil {
+			vector = *c.VectorSimilarity
+		}
+		c.Score = semanticWeight*vector + keywordWeight*c.TextRank + c.Boost
+		if opts.Mode == SearchModeKeyword {
+			// Keyword search normalizes ts_rank_cd to 0-1
+			c.Score = math.Min(c.Score, 1)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	returned := 0
+	for i := range candidates {
+		c := &candidates[i]
+		c.Rank = 0
+		c.Included = false
+		c.CutReason = ""
+
+		switch {
+		case opts.Mode == SearchModeHybrid && !c.PassesFilter:
+			c.CutReason = CutReasonFilter
+		case opts.Mode != SearchModeKeyword && c.VectorSimilarity == nil:
+			c.CutReason = CutReasonNoEmbedding
+		case opts.Mode == SearchModeKeyword && !c.KeywordMatch && !c.PhraseMatch:
+			c.CutReason = CutReasonNoKeywordMatch
+		case opts.Mode != SearchModeKeyword && c.Score < opts.Threshold:
+			// Keyword search has no threshold
+			c.CutReason = CutReasonThreshold
+		case returned >= opts.Limit:
+			c.CutReason = CutReasonLimit
+		default:
+			returned++
+			c.Rank = returned
+			c.Included = true
+		}
+	}
+	return candidates
+}
+
+// describeSearchFilter lists the filters a hybrid search applies
+func describeSearchFilter(filter *MetadataFilter) []string {
+	applied := []string{}
+	if filter == nil {
+		return applied
+	}
+	if filter.UserID != nil {
+		applied = append(applied, "user_id")
+	}
+	if len(filter.Tags) > 0 {
+		applied = append(applied, "tags")
+	}
+	keys := make([]string, 0, len(filter.Metadata))
+	for key := range filter.Metadata {
+		keys = append(keys, "metadata."+key)
+	}
+	sort.Strings(keys)
+	return append(applied, keys...)
+}
+
+// explainSearch runs a dry-run search and explains the score of each candidate chunk
+func explainSearch(ctx context.Context, kbStorage *KnowledgeBaseStorage, embeddings *EmbeddingService, kbID string, req SearchExplainRequest) (*SearchExplanation, error) {
+	var embedding []float32
+	if SearchMode(req.Mode) != SearchModeKeyword {
+		var err error
+		embedding, err = embeddings.EmbedSingle(ctx, req.Query, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed query: %w", err)
+		}
+	}
+	opts := req.searchOptions(embedding)
+
+	candidates, err := kbStorage.ExplainSearchCandidates(ctx, kbID, opts, req.Candidates)
+	if err != nil {
+		return nil, err
+	}
+	candidates = classifySearchCandidates(candidates, opts)
+
+	semanticWeight, keywordWeight, keywordBoost := searchScoreWeights(opts)
+	explanation := &SearchExplanation{
+		KnowledgeBaseID: kbID,
+		Query:           req.Query,
+		Mode:            req.Mode,
+		Threshold:       opts.Threshold,
+		MaxChunks:       opts.Limit,
+		SemanticWeight:  semanticWeight,
+		KeywordWeight:   keywordWeight,
+		KeywordBoost:    keywordBoost,
+		FiltersApplied:  []string{},
+		EmbeddingDims:   len(embedding),
+		QueryEmbedding:  embedding,
+		Candidates:      candidates,
+		CutCounts:       map[string]int{},
+	}
+	if embedding != nil {
+		explanation.EmbeddingModel = embeddings.DefaultModel()
+	}
+	if opts.Mode == SearchModeHybrid {
+		explanation.FiltersApplied = describeSearchFilter(opts.Filter)
+	}
+	for _, c := range candidates {
+		if c.Included {
+			explanation.Returned++
+		} else {
+			explanation.CutCounts[c.CutReason]++
+		}
+	}
+	if explanation.Candidates == nil {
+		explanation.Candidates = []SearchCandidate{}
+	}
+
+	return explanation, nil
+}
+
+// ExplainSearch runs a search without side effects and returns the query embedding and every
+// candidate chunk with its component scores, including the chunks cut by filters and thresholds
+// POST /api/v1/admin/ai/knowledge-bases/:id/search/debug
+func (h *KnowledgeBaseHandler) ExplainSearch(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+	kbID := c.Params("id")
+
+	var req SearchExplainRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
+	}
+
+	if h.processor == nil || h.processor.embeddingService == nil {
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Search not available (embedding service not configured)")
+	}
+
+	kb, err := h.storage.GetKnowledgeBase(ctx, kbID)
+	if err != nil || kb == nil {
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Knowledge base not found")
+	}
+
+	explanation, err := explainSearch(ctx, h.storage, h.processor.embeddingService, kbID, req)
+	if err != nil {
+		log.Error().Err(err).Str("kb_id", kbID).Msg("Failed to explain search")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to explain search")
+	}
+
+	return c.JSON(explanation)
+}
@@ -0,0 +1,139 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchExplainRequest_Validate(t *testing.T) {
+	t.Run("applies search defaults", func(t *testing.T) {
+		req := SearchExplainRequest{Query: " refund policy "}
+		require.NoError(t, req.Validate())
+		assert.Equal(t, "refund policy", req.Query)
+		assert.Equal(t, string(SearchModeSemantic), req.Mode)
+		assert.Equal(t, 10, req.MaxChunks)
+		assert.Equal(t, 0.2, req.Threshold)
+		assert.Equal(t, defaultExplainCandidates, req.Candidates)
+
+		opts := req.searchOptions(nil)
+		assert.Equal(t, 0.5, opts.SemanticWeight)
+		assert.Equal(t, 0.3, opts.KeywordBoost)
+		assert.Nil(t, opts.Filter)
+	})
+
+	t.Run("explains at least max_chunks candidates", func(t *testing.T) {
+		req := SearchExplainRequest{Query: "q", MaxChunks: 80, Candidates: 20}
+		require.NoError(t, req.Validate())
+		assert.Equal(t, 80, req.Candidates)
+	})
+
+	tests := []struct {
+		name string
+		req  SearchExplainRequest
+		err  string
+	}{
+		{"missing query", SearchExplainRequest{Query: " "}, "query is required"},
+		{"unknown mode", SearchExplainRequest{Query: "q", Mode: "fuzzy"}, "mode must be"},
+		{"weight out of range", SearchExplainRequest{Query: "q", SemanticWeight: 1.5}, "semantic_weight"},
+		{"too many candidates", SearchExplainRequest{Query: "q", Candidates: maxExplainCandidates + 1}, "candidates"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestClassifySearchCandidates(t *testing.T) {
+	sim := func(v float64) *float64 { return &v }
+
+	t.Run("hybrid", func(t *testing.T) {
+		opts := HybridSearchOptions{Mode: SearchModeHybrid, Threshold: 0.4, Limit: 1}.withDefaults()
+		candidates := classifySearchCandidates([]SearchCandidate{
+			{ChunkID: "low", VectorSimilarity: sim(0.5), PassesFilter: true},
+			{ChunkID: "boosted", VectorSimilarity: sim(0.6), TextRank: 0.2, PhraseMatch: true, PassesFilter: true},
+			{ChunkID: "filtered", VectorSimilarity: sim(0.9), TextRank: 0.5, PassesFilter: false},
+			{ChunkID: "second", VectorSimilarity: sim(0.8), PassesFilter: true},
+			{ChunkID: "unembedded", TextRank: 0.9, PassesFilter: true},
+		}, opts)
+
+		byID := map[string]SearchCandidate{}
+		for _, c := range candidates {
+			byID[c.ChunkID] = c
+		}
+
+		boosted := byID["boosted"]
+		assert.InDelta(t, 0.5*0.6+0.5*0.2+0.3, boosted.Score, 1e-9)
+		assert.Equal(t, 0.3, boosted.Boost)
+		assert.True(t, boosted.Included)
+		assert.Equal(t, 1, boosted.Rank)
+
+		assert.Equal(t, CutReasonFilter, byID["filtered"].CutReason)
+		assert.Equal(t, CutReasonLimit, byID["second"].CutReason)
+		assert.Equal(t, CutReasonNoEmbedding, byID["unembedded"].CutReason)
+		assert.Equal(t, CutReasonThreshold, byID["low"].CutReason)
+		assert.Zero(t, byID["low"].Rank)
+
+		// Ordered by score
+		for i := 1; i < len(candidates); i++ {
+			assert.GreaterOrEqual(t, candidates[i-1].Score, candidates[i].Score)
+		}
+	})
+
+	t.Run("semantic ignores filters and keyword scores", func(t *testing.T) {
+		opts := HybridSearchOptions{Mode: SearchModeSemantic, Threshold: 0.2, Limit: 10}.withDefaults()
+		candidates := classifySearchCandidates([]SearchCandidate{
+			{ChunkID: "a", VectorSimilarity: sim(0.7), TextRank: 0.9, PhraseMatch: true, PassesFilter: false},
+		}, opts)
+
+		require.Len(t, candidates, 1)
+		assert.True(t, candidates[0].Included)
+		assert.Equal(t, 0.7, candidates[0].Score)
+		assert.Zero(t, candidates[0].Boost)
+	})
+
+	t.Run("keyword has no threshold but requires a match", func(t *testing.T) {
+		opts := HybridSearchOptions{Mode: SearchModeKeyword, Threshold: 0.5, Limit: 10}.withDefaults()
+		candidates := classifySearchCandidates([]SearchCandidate{
+			{ChunkID: "match", TextRank: 0.1, KeywordMatch: true},
+			{ChunkID: "high-rank", TextRank: 3, PhraseMatch: true},
+			{ChunkID: "miss"},
+		}, opts)
+
+		require.Len(t, candidates, 3)
+		assert.Equal(t, "high-rank", candidates[0].ChunkID)
+		assert.Equal(t, 1.0, candidates[0].Score)
+		assert.True(t, candidates[1].Included)
+		assert.Equal(t, CutReasonNoKeywordMatch, candidates[2].CutReason)
+	})
+}
+
+func TestDescribeSearchFilter(t *testing.T) {
+	assert.Empty(t, describeSearchFilter(nil))
+
+	userID := "user-1"
+	applied := describeSearchFilter(&MetadataFilter{
+		UserID:   &userID,
+		Tags:     []string{"faq"},
+		Metadata: map[string]string{"lang": "en", "brand": "acme"},
+	})
+	assert.Equal(t, []string{"user_id", "tags", "metadata.brand", "metadata.lang"}, applied)
+}
+
+func TestHybridFilterConditions(t *testing.T) {
+	conditions, args := hybridFilterConditions(nil, []interface{}{"kb"})
+	assert.Empty(t, conditions)
+	assert.Len(t, args, 1)
+
+	conditions, args = hybridFilterConditions(&MetadataFilter{
+		Tags:     []string{"faq"},
+		Metadata: map[string]string{"lang": "en"},
+	}, []interface{}{"kb", "query"})
+	assert.Contains(t, conditions, "d.tags @> $3")
+	assert.Contains(t, conditions, "d.metadata->>$4::text = $5")
+	assert.Equal(t, []interface{}{"kb", "query", []string{"faq"}, "lang", "en"}, args)
+}
@@ -0,0 +1,469 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// KBSnapshotFormatVersion is the version of the snapshot item format (to_jsonb of the source rows)
+const KBSnapshotFormatVersion = 1
+
+// Snapshot triggers
+const (
+	KBSnapshotTriggerManual      = "manual"
+	KBSnapshotTriggerScheduled   = "scheduled"
+	KBSnapshotTriggerPreRollback = "pre_rollback"
+)
+
+// kbSnapshotSources lists the tables captured by a snapshot, in restore order.
+// Each query selects the rows belonging to the knowledge base ($1).
+var kbSnapshotSources = []struct {
+	itemType string
+	table    string
+	query    string
+}{
+	{"document", "ai.documents", `SELECT to_jsonb(d.*) FROM ai.documents d WHERE d.knowledge_base_id = $1`},
+	{"chunk", "ai.chunks", `SELECT to_jsonb(c.*) FROM ai.chunks c WHERE c.knowledge_base_id = $1`},
+	{"document_permission", "ai.document_permissions", `
+		SELECT to_jsonb(p.*) FROM ai.document_permissions p
+		JOIN ai.documents d ON d.id = p.document_id
+		WHERE d.knowledge_base_id = $1`},
+	{"document_entity", "ai.document_entities", `
+		SELECT to_jsonb(de.*) FROM ai.document_entities de
+		JOIN ai.documents d ON d.id = de.document_id
+		WHERE d.knowledge_base_id = $1`},
+}
+
+// KBSnapshot is a point-in-time copy of a knowledge base's documents and chunks
+type KBSnapshot struct {
+	ID              string             `json:"id"`
+	KnowledgeBaseID string             `json:"knowledge_base_id"`
+	Label           *string            `json:"label,omitempty"`
+	Trigger         string             `json:"trigger"`
+	FormatVersion   int                `json:"format_version"`
+	DocumentCount   int                `json:"document_count"`
+	ChunkCount      int                `json:"chunk_count"`
+	Manifest        KBSnapshotManifest `json:"manifest"`
+	CreatedBy       *string            `json:"created_by,omitempty"`
+	CreatedAt       time.Time          `json:"created_at"`
+}
+
+// KBSnapshotManifest describes the embedding configuration and contents of a snapshot
+type KBSnapshotManifest struct {
+	EmbeddingModel      string         `json:"embedding_model"`
+	EmbeddingDimensions int            `json:"embedding_dimensions"`
+	Items               map[string]int `json:"items"`
+}
+
+// KBSnapshotSchedule configures recurring snapshots for a knowledge base
+type KBSnapshotSchedule struct {
+	KnowledgeBaseID string     `json:"knowledge_base_id"`
+	Enabled         bool       `json:"enabled"`
+	IntervalHours   int        `json:"interval_hours"`
+	Retention       int        `json:"retention"`
+	LastSnapshotAt  *time.Time `json:"last_snapshot_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// KBSnapshotExport is the portable representation of a snapshot returned by the export endpoint
+type KBSnapshotExport struct {
+	FormatVersion int                          `json:"format_version"`
+	Snapshot      *KBSnapshot                  `json:"snapshot"`
+	Items         map[string][]json.RawMessage `json:"items"`
+}
+
+// KBRollbackResult reports the outcome of rolling a knowledge base back to a snapshot
+type KBRollbackResult struct {
+	SnapshotID          string         `json:"snapshot_id"`
+	SafetySnapshotID    string         `json:"safety_snapshot_id,omitempty"`
+	DocumentsRemoved    int            `json:"documents_removed"`
+	Restored            map[string]int `json:"restored"`
+	EmbeddingModelDrift bool           `json:"embedding_model_drift,omitempty"`
+}
+
+// ============================================================================
+// KNOWLEDGE BASE SNAPSHOT OPERATIONS
+// ============================================================================
+
+const kbSnapshotColumns = `
+	id, knowledge_base_id, label, trigger, format_version, document_count, chunk_count, manifest, created_by, created_at
+`
+
+// CreateKBSnapshot captures the current documents, chunks (including embeddings), document
+// permissions and entity mentions of a knowledge base in a single transaction.
+func (s *KnowledgeBaseStorage) CreateKBSnapshot(ctx context.Context, kbID, trigger string, label, createdBy *string) (*KBSnapshot, error) {
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	snapshot, err := createKBSnapshotTx(ctx, tx, kbID, trigger, label, createdBy)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+func createKBSnapshotTx(ctx context.Context, tx pgx.Tx, kbID, trigger string, label, createdBy *string) (*KBSnapshot, error) {
+	snapshot := &KBSnapshot{
+		KnowledgeBaseID: kbID,
+		Label:           label,
+		Trigger:         trigger,
+		FormatVersion:   KBSnapshotFormatVersion,
+		CreatedBy:       createdBy,
+		Manifest:        KBSnapshotManifest{Items: make(map[string]int)},
+	}
+
+	// Lock the knowledge base row so a concurrent rollback cannot interleave with the copy
+	err := tx.QueryRow(ctx, `
+		SELECT COALESCE(embedding_model, ''), COALESCE(embedding_dimensions, 0)
+		FROM ai.knowledge_bases WHERE id = $1 FOR UPDATE
+	`, kbID).Scan(&snapshot.Manifest.EmbeddingModel, &snapshot.Manifest.EmbeddingDimensions)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("knowledge base not found: %s", kbID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock knowledge base: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO ai.knowledge_base_snapshots (knowledge_base_id, label, trigger, format_version, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, kbID, label, trigger, KBSnapshotFormatVersion, createdBy).Scan(&snapshot.ID, &snapshot.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot: %w", err)
+	}
+
+	for _, source := range kbSnapshotSources {
+		tag, err := tx.Exec(ctx, fmt.Sprintf(`
+			INSERT INTO ai.knowledge_base_snapshot_items (snapshot_id, item_type, data)
+			SELECT $2, '%s', src.data FROM (%s) AS src(data)
+		`, source.itemType, source.query), kbID, snapshot.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot %s rows: %w", source.itemType, err)
+		}
+		snapshot.Manifest.Items[source.itemType] = int(tag.RowsAffected())
+	}
+
+	snapshot.DocumentCount = snapshot.Manifest.Items["document"]
+	snapshot.ChunkCount = snapshot.Manifest.Items["chunk"]
+
+	manifestJSON, err := json.Marshal(snapshot.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot manifest: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE ai.knowledge_base_snapshots
+		SET document_count = $2, chunk_count = $3, manifest = $4
+		WHERE id = $1
+	`, snapshot.ID, snapshot.DocumentCount, snapshot.ChunkCount, manifestJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update snapshot manifest: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// GetKBSnapshot retrieves a snapshot by ID
+func (s *KnowledgeBaseStorage) GetKBSnapshot(ctx context.Context, id string) (*KBSnapshot, error) {
+	query := `SELECT ` + kbSnapshotColumns + ` FROM ai.knowledge_base_snapshots WHERE id = $1`
+
+	snapshot, err := scanKBSnapshot(s.db.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// ListKBSnapshots lists a knowledge base's snapshots, newest first
+func (s *KnowledgeBaseStorage) ListKBSnapshots(ctx context.Context, kbID string) ([]*KBSnapshot, error) {
+	query := `SELECT ` + kbSnapshotColumns + ` FROM ai.knowledge_base_snapshots
+		WHERE knowledge_base_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := s.db.Query(ctx, query, kbID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := make([]*KBSnapshot, 0)
+	for rows.Next() {
+		snapshot, err := scanKBSnapshot(rows)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to scan knowledge base snapshot")
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// DeleteKBSnapshot deletes a snapshot and its items
+func (s *KnowledgeBaseStorage) DeleteKBSnapshot(ctx context.Context, id string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM ai.knowledge_base_snapshots WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	return nil
+}
+
+// ExportKBSnapshot returns a snapshot with all captured rows grouped by item type
+func (s *KnowledgeBaseStorage) ExportKBSnapshot(ctx context.Context, snapshot *KBSnapshot) (*KBSnapshotExport, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT item_type, data FROM ai.knowledge_base_snapshot_items
+		WHERE snapshot_id = $1
+		ORDER BY id
+	`, snapshot.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export snapshot: %w", err)
+	}
+	defer rows.Close()
+
+	export := &KBSnapshotExport{
+		FormatVersion: snapshot.FormatVersion,
+		Snapshot:      snapshot,
+		Items:         make(map[string][]json.RawMessage),
+	}
+	for rows.Next() {
+		var itemType string
+		var data []byte
+		if err := rows.Scan(&itemType, &data); err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot item: %w", err)
+		}
+		export.Items[itemType] = append(export.Items[itemType], data)
+	}
+	return export, rows.Err()
+}
+
+// kbSnapshotRestoreGuard returns the condition snapshot items of a type must meet to be
+// restored: permissions are kept while their user or group still exists, and entity
+// mentions while their entity does
+func kbSnapshotRestoreGuard(itemType string) string {
+	switch itemType {
+	case "document_permission":
+		return `AND (i.data->>'user_id' IS NULL OR EXISTS (SELECT 1 FROM auth.users u WHERE u.id = (i.data->>'user_id')::uuid))
+			AND (i.data->>'group_id' IS NULL OR EXISTS (SELECT 1 FROM auth.groups g WHERE g.id = (i.data->>'group_id')::uuid))`
+	case "document_entity":
+		return `AND EXISTS (SELECT 1 FROM ai.entities e WHERE e.id = (i.data->>'entity_id')::uuid)`
+	}
+	return ""
+}
+
+// RollbackKBToSnapshot replaces a knowledge base's documents and chunks with the contents of a snapshot.
+// When safetySnapshot is true the current state is captured first (trigger "pre_rollback") so the
+// rollback itself can be undone. Permissions and entity mentions are only restored for users, groups
+// and entities that still exist.
+func (s *KnowledgeBaseStorage) RollbackKBToSnapshot(ctx context.Context, snapshot *KBSnapshot, safetySnapshot bool, userID *string) (*KBRollbackResult, error) {
+	if snapshot.FormatVersion != KBSnapshotFormatVersion {
+		return nil, fmt.Errorf("unsupported snapshot format version: %d", snapshot.FormatVersion)
+	}
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	result := &KBRollbackResult{
+		SnapshotID: snapshot.ID,
+		Restored:   make(map[string]int),
+	}
+
+	kbID := snapshot.KnowledgeBaseID
+	if safetySnapshot {
+		label := fmt.Sprintf("Before rollback to %s", snapshot.ID)
+		safety, err := createKBSnapshotTx(ctx, tx, kbID, KBSnapshotTriggerPreRollback, &label, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create safety snapshot: %w", err)
+		}
+		result.SafetySnapshotID = safety.ID
+	} else if _, err := tx.Exec(ctx, `SELECT 1 FROM ai.knowledge_bases WHERE id = $1 FOR UPDATE`, kbID); err != nil {
+		return nil, fmt.Errorf("failed to lock knowledge base: %w", err)
+	}
+
+	var currentModel string
+	if err := tx.QueryRow(ctx, `SELECT COALESCE(embedding_model, '') FROM ai.knowledge_bases WHERE id = $1`, kbID).Scan(&currentModel); err != nil {
+		return nil, fmt.Errorf("failed to get knowledge base: %w", err)
+	}
+	result.EmbeddingModelDrift = currentModel != snapshot.Manifest.EmbeddingModel
+
+	// Chunks, permissions and entity mentions cascade from documents
+	tag, err := tx.Exec(ctx, `DELETE FROM ai.documents WHERE knowledge_base_id = $1`, kbID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clear documents: %w", err)
+	}
+	result.DocumentsRemoved = int(tag.RowsAffected())
+
+	for _, source := range kbSnapshotSources {
+		guard := kbSnapshotRestoreGuard(source.itemType)
+		tag, err := tx.Exec(ctx, fmt.Sprintf(`
+			INSERT INTO %s
+			SELECT (jsonb_populate_record(NULL::%s, i.data)).*
+			FROM ai.knowledge_base_snapshot_items i
+			WHERE i.snapshot_id = $1 AND i.item_type = '%s' %s
+		`, source.table, source.table, source.itemType, guard), snapshot.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore %s rows: %w", source.itemType, err)
+		}
+		result.Restored[source.itemType] = int(tag.RowsAffected())
+	}
+
+	// Counter triggers fired for every restored row, so recompute counts from the restored data
+	_, err = tx.Exec(ctx, `
+		UPDATE ai.documents d
+		SET chunks_count = (SELECT COUNT(*) FROM ai.chunks c WHERE c.document_id = d.id)
+		WHERE d.knowledge_base_id = $1
+	`, kbID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recompute document chunk counts: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE ai.knowledge_bases
+		SET document_count = (SELECT COUNT(*) FROM ai.documents WHERE knowledge_base_id = $1),
+		    total_chunks = (SELECT COUNT(*) FROM ai.chunks WHERE knowledge_base_id = $1)
+		WHERE id = $1
+	`, kbID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recompute knowledge base counts: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit rollback: %w", err)
+	}
+	return result, nil
+}
+
+// scanKBSnapshot scans a row selected with kbSnapshotColumns
+func scanKBSnapshot(row pgx.Row) (*KBSnapshot, error) {
+	s := &KBSnapshot{}
+	var manifestJSON []byte
+	err := row.Scan(
+		&s.ID, &s.KnowledgeBaseID, &s.Label, &s.Trigger, &s.FormatVersion,
+		&s.DocumentCount, &s.ChunkCount, &manifestJSON, &s.CreatedBy, &s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(manifestJSON) > 0 {
+		_ = json.Unmarshal(manifestJSON, &s.Manifest)
+	}
+	return s, nil
+}
+
+// ============================================================================
+// SNAPSHOT SCHEDULES
+// ============================================================================
+
+// GetKBSnapshotSchedule returns a knowledge base's snapshot schedule, or nil if none is configured
+func (s *KnowledgeBaseStorage) GetKBSnapshotSchedule(ctx context.Context, kbID string) (*KBSnapshotSchedule, error) {
+	var schedule KBSnapshotSchedule
+	err := s.db.QueryRow(ctx, `
+		SELECT knowledge_base_id, enabled, interval_hours, retention, last_snapshot_at, created_at, updated_at
+		FROM ai.knowledge_base_snapshot_schedules
+		WHERE knowledge_base_id = $1
+	`, kbID).Scan(
+		&schedule.KnowledgeBaseID, &schedule.Enabled, &schedule.IntervalHours, &schedule.Retention,
+		&schedule.LastSnapshotAt, &schedule.CreatedAt, &schedule.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshot schedule: %w", err)
+	}
+	return &schedule, nil
+}
+
+// UpsertKBSnapshotSchedule creates or updates a knowledge base's snapshot schedule
+func (s *KnowledgeBaseStorage) UpsertKBSnapshotSchedule(ctx context.Context, schedule *KBSnapshotSchedule) error {
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO ai.knowledge_base_snapshot_schedules (knowledge_base_id, enabled, interval_hours, retention)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (knowledge_base_id) DO UPDATE
+		SET enabled = EXCLUDED.enabled,
+		    interval_hours = EXCLUDED.interval_hours,
+		    retention = EXCLUDED.retention,
+		    updated_at = NOW()
+		RETURNING last_snapshot_at, created_at, updated_at
+	`, schedule.KnowledgeBaseID, schedule.Enabled, schedule.IntervalHours, schedule.Retention).Scan(
+		&schedule.LastSnapshotAt, &schedule.CreatedAt, &schedule.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot schedule: %w", err)
+	}
+	return nil
+}
+
+// DeleteKBSnapshotSchedule removes a knowledge base's snapshot schedule (existing snapshots are kept)
+func (s *KnowledgeBaseStorage) DeleteKBSnapshotSchedule(ctx context.Context, kbID string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM ai.knowledge_base_snapshot_schedules WHERE knowledge_base_id = $1`, kbID)
+	if err != nil {
+		return fmt.Errorf("failed to delete snapshot schedule: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueKBSnapshotSchedules marks due schedules as run and returns them.
+// Claiming with SKIP LOCKED keeps multiple instances from snapshotting the same knowledge base.
+func (s *KnowledgeBaseStorage) ClaimDueKBSnapshotSchedules(ctx context.Context) ([]KBSnapshotSchedule, error) {
+	rows, err := s.db.Query(ctx, `
+		UPDATE ai.knowledge_base_snapshot_schedules sch
+		SET last_snapshot_at = NOW()
+		WHERE sch.knowledge_base_id IN (
+			SELECT knowledge_base_id FROM ai.knowledge_base_snapshot_schedules
+			WHERE enabled
+			  AND (last_snapshot_at IS NULL OR last_snapshot_at <= NOW() - make_interval(hours => interval_hours))
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING sch.knowledge_base_id, sch.enabled, sch.interval_hours, sch.retention, sch.last_snapshot_at, sch.created_at, sch.updated_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim snapshot schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := make([]KBSnapshotSchedule, 0)
+	for rows.Next() {
+		var sch KBSnapshotSchedule
+		if err := rows.Scan(&sch.KnowledgeBaseID, &sch.Enabled, &sch.IntervalHours, &sch.Retention, &sch.LastSnapshotAt, &sch.CreatedAt, &sch.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot schedule: %w", err)
+		}
+		schedules = append(schedules, sch)
+	}
+	return schedules, rows.Err()
+}
+
+// PruneScheduledKBSnapshots deletes scheduled snapshots beyond the newest `keep` for a knowledge base
+func (s *KnowledgeBaseStorage) PruneScheduledKBSnapshots(ctx context.Context, kbID string, keep int) (int, error) {
+	tag, err := s.db.Exec(ctx, `
+		DELETE FROM ai.knowledge_base_snapshots
+		WHERE id IN (
+			SELECT id FROM ai.knowledge_base_snapshots
+			WHERE knowledge_base_id = $1 AND trigger = 'scheduled'
+			ORDER BY created_at DESC
+			OFFSET $2
+		)
+	`, kbID, keep)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune snapshots: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
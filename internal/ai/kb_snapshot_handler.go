@@ -0,0 +1,228 @@
+package ai
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// KNOWLEDGE BASE SNAPSHOT ENDPOINTS
+// ============================================================================
+
+// ListKBSnapshots lists a knowledge base's snapshots
+// GET /api/v1/admin/ai/knowledge-bases/:id/snapshots
+func (h *KnowledgeBaseHandler) ListKBSnapshots(c fiber.Ctx) error {
+	kbID := c.Params("id")
+
+	snapshots, err := h.storage.ListKBSnapshots(c.RequestCtx(), kbID)
+	if err != nil {
+		log.Error().Err(err).Str("kb_id", kbID).Msg("Failed to list knowledge base snapshots")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list snapshots")
+	}
+
+	return c.JSON(fiber.Map{
+		"snapshots": snapshots,
+		"count":     len(snapshots),
+	})
+}
+
+// CreateKBSnapshot takes a manual snapshot of a knowledge base
+// POST /api/v1/admin/ai/knowledge-bases/:id/snapshots
+func (h *KnowledgeBaseHandler) CreateKBSnapshot(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+	kbID := c.Params("id")
+
+	var req struct {
+		Label string `json:"label"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&req); err != nil {
+			return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+		}
+	}
+
+	kb, err := h.storage.GetKnowledgeBase(ctx, kbID)
+	if err != nil || kb == nil {
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Knowledge base not found")
+	}
+
+	var label *string
+	if req.Label != "" {
+		label = &req.Label
+	}
+
+	snapshot, err := h.storage.CreateKBSnapshot(ctx, kbID, KBSnapshotTriggerManual, label, snapshotUserID(c))
+	if err != nil {
+		log.Error().Err(err).Str("kb_id", kbID).Msg("Failed to create knowledge base snapshot")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to create snapshot")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(snapshot)
+}
+
+// GetKBSnapshot returns a snapshot's metadata and manifest
+// GET /api/v1/admin/ai/knowledge-bases/:id/snapshots/:snapshot_id
+func (h *KnowledgeBaseHandler) GetKBSnapshot(c fiber.Ctx) error {
+	snapshot, ok, err := h.loadKBSnapshot(c)
+	if !ok {
+		return err
+	}
+	return c.JSON(snapshot)
+}
+
+// ExportKBSnapshot downloads a snapshot with all captured rows
+// GET /api/v1/admin/ai/knowledge-bases/:id/snapshots/:snapshot_id/export
+func (h *KnowledgeBaseHandler) ExportKBSnapshot(c fiber.Ctx) error {
+	snapshot, ok, err := h.loadKBSnapshot(c)
+	if !ok {
+		return err
+	}
+
+	export, err := h.storage.ExportKBSnapshot(c.RequestCtx(), snapshot)
+	if err != nil {
+		log.Error().Err(err).Str("snapshot_id", snapshot.ID).Msg("Failed to export knowledge base snapshot")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to export snapshot")
+	}
+
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="kb-snapshot-%s.json"`, snapshot.ID))
+	return c.JSON(export)
+}
+
+// DeleteKBSnapshot deletes a snapshot
+// DELETE /api/v1/admin/ai/knowledge-bases/:id/snapshots/:snapshot_id
+func (h *KnowledgeBaseHandler) DeleteKBSnapshot(c fiber.Ctx) error {
+	snapshot, ok, err := h.loadKBSnapshot(c)
+	if !ok {
+		return err
+	}
+
+	if err := h.storage.DeleteKBSnapshot(c.RequestCtx(), snapshot.ID); err != nil {
+		log.Error().Err(err).Str("snapshot_id", snapshot.ID).Msg("Failed to delete knowledge base snapshot")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to delete snapshot")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RollbackKBSnapshot restores a knowledge base to a snapshot.
+// A "pre_rollback" snapshot of the current state is taken first unless skip_safety_snapshot is set.
+// POST /api/v1/admin/ai/knowledge-bases/:id/snapshots/:snapshot_id/rollback
+func (h *KnowledgeBaseHandler) RollbackKBSnapshot(c fiber.Ctx) error {
+	snapshot, ok, err := h.loadKBSnapshot(c)
+	if !ok {
+		return err
+	}
+
+	var req struct {
+		SkipSafetySnapshot bool `json:"skip_safety_snapshot"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&req); err != nil {
+			return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+		}
+	}
+
+	result, err := h.storage.RollbackKBToSnapshot(c.RequestCtx(), snapshot, !req.SkipSafetySnapshot, snapshotUserID(c))
+	if err != nil {
+		log.Error().Err(err).
+			Str("kb_id", snapshot.KnowledgeBaseID).
+			Str("snapshot_id", snapshot.ID).
+			Msg("Failed to roll back knowledge base")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, fmt.Sprintf("Failed to roll back knowledge base: %v", err))
+	}
+
+	log.Info().
+		Str("kb_id", snapshot.KnowledgeBaseID).
+		Str("snapshot_id", snapshot.ID).
+		Int("documents_removed", result.DocumentsRemoved).
+		Int("documents_restored", result.Restored["document"]).
+		Msg("Knowledge base rolled back to snapshot")
+
+	return c.JSON(result)
+}
+
+// GetKBSnapshotSchedule returns a knowledge base's recurring snapshot schedule
+// GET /api/v1/admin/ai/knowledge-bases/:id/snapshot-schedule
+func (h *KnowledgeBaseHandler) GetKBSnapshotSchedule(c fiber.Ctx) error {
+	schedule, err := h.storage.GetKBSnapshotSchedule(c.RequestCtx(), c.Params("id"))
+	if err != nil {
+		log.Error().Err(err).Str("kb_id", c.Params("id")).Msg("Failed to get snapshot schedule")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get snapshot schedule")
+	}
+	if schedule == nil {
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "No snapshot schedule configured")
+	}
+	return c.JSON(schedule)
+}
+
+// UpdateKBSnapshotSchedule creates or updates a knowledge base's recurring snapshot schedule
+// PUT /api/v1/admin/ai/knowledge-bases/:id/snapshot-schedule
+func (h *KnowledgeBaseHandler) UpdateKBSnapshotSchedule(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+	kbID := c.Params("id")
+
+	req := struct {
+		Enabled       *bool `json:"enabled"`
+		IntervalHours int   `json:"interval_hours"`
+		Retention     int   `json:"retention"`
+	}{IntervalHours: 24, Retention: 7}
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+	}
+	if req.IntervalHours <= 0 || req.Retention <= 0 {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "interval_hours and retention must be positive")
+	}
+
+	kb, err := h.storage.GetKnowledgeBase(ctx, kbID)
+	if err != nil || kb == nil {
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Knowledge base not found")
+	}
+
+	schedule := &KBSnapshotSchedule{
+		KnowledgeBaseID: kbID,
+		Enabled:         req.Enabled == nil || *req.Enabled,
+		IntervalHours:   req.IntervalHours,
+		Retention:       req.Retention,
+	}
+	if err := h.storage.UpsertKBSnapshotSchedule(ctx, schedule); err != nil {
+		log.Error().Err(err).Str("kb_id", kbID).Msg("Failed to save snapshot schedule")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to save snapshot schedule")
+	}
+
+	return c.JSON(schedule)
+}
+
+// DeleteKBSnapshotSchedule removes a knowledge base's recurring snapshot schedule
+// DELETE /api/v1/admin/ai/knowledge-bases/:id/snapshot-schedule
+func (h *KnowledgeBaseHandler) DeleteKBSnapshotSchedule(c fiber.Ctx) error {
+	if err := h.storage.DeleteKBSnapshotSchedule(c.RequestCtx(), c.Params("id")); err != nil {
+		log.Error().Err(err).Str("kb_id", c.Params("id")).Msg("Failed to delete snapshot schedule")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to delete snapshot schedule")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// loadKBSnapshot fetches the snapshot named by :snapshot_id and checks it belongs to :id.
+// When ok is false the response has already been written and err should be returned.
+func (h *KnowledgeBaseHandler) loadKBSnapshot(c fiber.Ctx) (*KBSnapshot, bool, error) {
+	snapshot, err := h.storage.GetKBSnapshot(c.RequestCtx(), c.Params("snapshot_id"))
+	if err != nil {
+		log.Error().Err(err).Str("snapshot_id", c.Params("snapshot_id")).Msg("Failed to get knowledge base snapshot")
+		return nil, false, apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get snapshot")
+	}
+	if snapshot == nil || snapshot.KnowledgeBaseID != c.Params("id") {
+		return nil, false, apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Snapshot not found")
+	}
+	return snapshot, true, nil
+}
+
+// snapshotUserID returns the caller's user ID for snapshot attribution, if any
+func snapshotUserID(c fiber.Ctx) *string {
+	if userID, ok := c.Locals("user_id").(string); ok && userID != "" {
+		return &userID
+	}
+	return nil
+}
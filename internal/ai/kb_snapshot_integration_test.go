@@ -0,0 +1,64 @@
+//go:build integration
+
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/nimbleflux/fluxbase/test/dbhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestKBSnapshot_RestoresGroupGrants_Integration snapshots a document shared with a user and
+// two groups, deletes one group, and rolls back: the user and remaining group grants return
+func TestKBSnapshot_RestoresGroupGrants_Integration(t *testing.T) {
+	testCtx := dbhelpers.NewDBTestContext(t)
+	defer testCtx.Close()
+
+	ctx := context.Background()
+	db := testCtx.Pool
+	storage := NewKnowledgeBaseStorage(database.NewConnectionWithPool(db))
+
+	userID, keptGroupID, deletedGroupID := uuid.New(), uuid.New(), uuid.New()
+	_, err := db.Exec(ctx, `
+		INSERT INTO auth.users (id, email, password_hash, email_verified, role)
+		VALUES ($1, $1::text || '@test.local', 'hashed_password', true, 'authenticated')`, userID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, `INSERT INTO auth.groups (id, name) VALUES ($1, $1::text), ($2, $2::text)`, keptGroupID, deletedGroupID)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = db.Exec(ctx, `DELETE FROM auth.groups WHERE id = ANY($1)`, []uuid.UUID{keptGroupID, deletedGroupID})
+		_, _ = db.Exec(ctx, `DELETE FROM auth.users WHERE id = $1`, userID)
+	})
+
+	var kbID, documentID string
+	require.NoError(t, db.QueryRow(ctx, `INSERT INTO ai.knowledge_bases (name) VALUES ($1) RETURNING id`, "kb-"+userID.String()).Scan(&kbID))
+	t.Cleanup(func() { _, _ = db.Exec(ctx, `DELETE FROM ai.knowledge_bases WHERE id = $1`, kbID) })
+	require.NoError(t, db.QueryRow(ctx, `INSERT INTO ai.documents (knowledge_base_id, content) VALUES ($1, 'handbook') RETURNING id`, kbID).Scan(&documentID))
+	_, err = db.Exec(ctx, `
+		INSERT INTO ai.document_permissions (document_id, user_id, group_id, permission, granted_by)
+		VALUES ($1, $2, NULL, 'editor', $2), ($1, NULL, $3, 'viewer', $2), ($1, NULL, $4, 'viewer', $2)`,
+		documentID, userID, keptGroupID, deletedGroupID)
+	require.NoError(t, err)
+
+	snapshot, err := storage.CreateKBSnapshot(ctx, kbID, KBSnapshotTriggerManual, nil, nil)
+	require.NoError(t, err)
+
+	_, err = db.Exec(ctx, `DELETE FROM auth.groups WHERE id = $1`, deletedGroupID)
+	require.NoError(t, err)
+
+	result, err := storage.RollbackKBToSnapshot(ctx, snapshot, false, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Restored["document_permission"])
+
+	var userGrants, groupGrants int
+	require.NoError(t, db.QueryRow(ctx, `
+		SELECT count(*) FILTER (WHERE user_id = $2), count(*) FILTER (WHERE group_id = $3)
+		FROM ai.document_permissions WHERE document_id = $1`, documentID, userID, keptGroupID).Scan(&userGrants, &groupGrants))
+	assert.Equal(t, 1, userGrants)
+	assert.Equal(t, 1, groupGrants)
+}
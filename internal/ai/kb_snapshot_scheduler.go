@@ -0,0 +1,136 @@
+package ai
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// KBSnapshotScheduler takes recurring knowledge base snapshots and prunes old ones
+type KBSnapshotScheduler struct {
+	storage  *KnowledgeBaseStorage
+	interval time.Duration
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	running  bool
+	mu       sync.Mutex
+}
+
+// NewKBSnapshotScheduler creates a new snapshot scheduler that checks for due schedules every interval
+func NewKBSnapshotScheduler(storage *KnowledgeBaseStorage, interval time.Duration) *KBSnapshotScheduler {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &KBSnapshotScheduler{
+		storage:  storage,
+		interval: interval,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start begins the snapshot scheduler
+func (s *KBSnapshotScheduler) Start() {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = true
+	// A stopped service gets a fresh context so it can be restarted (e.g. on regaining leadership)
+	if s.ctx.Err() != nil {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+	}
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.run()
+
+	log.Info().
+		Dur("interval", s.interval).
+		Msg("Knowledge base snapshot scheduler started")
+}
+
+// Stop gracefully stops the snapshot scheduler
+func (s *KBSnapshotScheduler) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	s.mu.Unlock()
+
+	s.cancel()
+	s.wg.Wait()
+
+	log.Info().Msg("Knowledge base snapshot scheduler stopped")
+}
+
+// run is the main scheduler loop
+func (s *KBSnapshotScheduler) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.runDue()
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// runDue snapshots every knowledge base whose schedule is due
+func (s *KBSnapshotScheduler) runDue() {
+	schedules, err := s.storage.ClaimDueKBSnapshotSchedules(s.ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load due knowledge base snapshot schedules")
+		return
+	}
+
+	for _, schedule := range schedules {
+		select {
+		case <-s.ctx.Done():
+			return
+		default:
+		}
+
+		snapshot, err := s.storage.CreateKBSnapshot(s.ctx, schedule.KnowledgeBaseID, KBSnapshotTriggerScheduled, nil, nil)
+		if err != nil {
+			log.Error().Err(err).
+				Str("kb_id", schedule.KnowledgeBaseID).
+				Msg("Failed to create scheduled knowledge base snapshot")
+			continue
+		}
+
+		pruned, err := s.storage.PruneScheduledKBSnapshots(s.ctx, schedule.KnowledgeBaseID, schedule.Retention)
+		if err != nil {
+			log.Warn().Err(err).Str("kb_id", schedule.KnowledgeBaseID).Msg("Failed to prune knowledge base snapshots")
+		}
+
+		log.Info().
+			Str("kb_id", schedule.KnowledgeBaseID).
+			Str("snapshot_id", snapshot.ID).
+			Int("documents", snapshot.DocumentCount).
+			Int("chunks", snapshot.ChunkCount).
+			Int("pruned", pruned).
+			Msg("Scheduled knowledge base snapshot created")
+	}
+}
+
+// IsRunning returns whether the scheduler is currently running
+func (s *KBSnapshotScheduler) IsRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
@@ -0,0 +1,73 @@
+package ai
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKBSnapshotSources(t *testing.T) {
+	t.Run("documents are restored before dependent rows", func(t *testing.T) {
+		require.NotEmpty(t, kbSnapshotSources)
+		assert.Equal(t, "document", kbSnapshotSources[0].itemType)
+	})
+
+	t.Run("every source is scoped to the knowledge base", func(t *testing.T) {
+		seen := make(map[string]bool)
+		for _, source := range kbSnapshotSources {
+			assert.False(t, seen[source.itemType], "duplicate item type %s", source.itemType)
+			seen[source.itemType] = true
+			assert.Contains(t, source.query, "knowledge_base_id = $1")
+			assert.True(t, strings.HasPrefix(source.table, "ai."))
+		}
+	})
+}
+
+func TestKBSnapshotScheduler_StartStop(t *testing.T) {
+	scheduler := NewKBSnapshotScheduler(&KnowledgeBaseStorage{}, time.Hour)
+	assert.False(t, scheduler.IsRunning())
+
+	scheduler.Start()
+	scheduler.Start() // idempotent
+	assert.True(t, scheduler.IsRunning())
+
+	scheduler.Stop()
+	scheduler.Stop()
+	assert.False(t, scheduler.IsRunning())
+}
+
+func TestKnowledgeBaseHandler_UpdateKBSnapshotScheduleValidation(t *testing.T) {
+	app := fiber.New()
+	handler := NewKnowledgeBaseHandler(&KnowledgeBaseStorage{}, nil)
+	app.Put("/knowledge-bases/:id/snapshot-schedule", handler.UpdateKBSnapshotSchedule)
+
+	for _, body := range []string{`{"interval_hours":0}`, `{"retention":-1}`, `not json`} {
+		req := httptest.NewRequest("PUT", "/knowledge-bases/kb-1/snapshot-schedule", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, body)
+	}
+}
+
+func TestKBSnapshotRestoreGuard(t *testing.T) {
+	t.Run("permissions keep user and group grants", func(t *testing.T) {
+		guard := kbSnapshotRestoreGuard("document_permission")
+		assert.Contains(t, guard, "i.data->>'user_id' IS NULL OR EXISTS (SELECT 1 FROM auth.users")
+		assert.Contains(t, guard, "i.data->>'group_id' IS NULL OR EXISTS (SELECT 1 FROM auth.groups")
+	})
+
+	t.Run("entity mentions need their entity", func(t *testing.T) {
+		assert.Contains(t, kbSnapshotRestoreGuard("document_entity"), "FROM ai.entities")
+	})
+
+	t.Run("documents and chunks are unguarded", func(t *testing.T) {
+		assert.Empty(t, kbSnapshotRestoreGuard("document"))
+		assert.Empty(t, kbSnapshotRestoreGuard("chunk"))
+	})
+}
@@ -0,0 +1,105 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+)
+
+// KBVectorStorage defines how a knowledge base's chunk embeddings are indexed for search
+type KBVectorStorage string
+
+const (
+	KBVectorStorageFull   KBVectorStorage = "vector"  // Full-precision vectors
+	KBVectorStorageHalf   KBVectorStorage = "halfvec" // Half-precision copy searched through an HNSW index
+	KBVectorStorageBinary KBVectorStorage = "binary"  // Binary quantized copy searched through an HNSW index
+)
+
+const (
+	// DefaultKBRerankCandidates is how many candidates a quantized search reranks by default
+	DefaultKBRerankCandidates = 100
+	// MaxKBRerankCandidates caps the candidates reranked per search
+	MaxKBRerankCandidates = 1000
+)
+
+var (
+	// ErrInvalidKBVectorStorage is returned for an unsupported vector_storage
+	ErrInvalidKBVectorStorage = errors.New("vector_storage must be one of: vector, halfvec, binary")
+	// ErrInvalidKBRerankCandidates is returned for a rerank_candidates outside 1..MaxKBRerankCandidates
+	ErrInvalidKBRerankCandidates = fmt.Errorf("rerank_candidates must be between 1 and %d", MaxKBRerankCandidates)
+)
+
+// IsValidKBVectorStorage returns true if the storage is supported for knowledge bases
+func IsValidKBVectorStorage(storage KBVectorStorage) bool {
+	switch storage {
+	case KBVectorStorageFull, KBVectorStorageHalf, KBVectorStorageBinary:
+		return true
+	}
+	return false
+}
+
+// IsValidKBRerankCandidates returns true if n is an acceptable rerank candidate count
+func IsValidKBRerankCandidates(n int) bool {
+	return n > 0 && n <= MaxKBRerankCandidates
+}
+
+// validateVectorOptions checks the vector storage options of a create request; zero values use the defaults
+func (r CreateKnowledgeBaseRequest) validateVectorOptions() error {
+	if r.VectorStorage != "" && !IsValidKBVectorStorage(r.VectorStorage) {
+		return ErrInvalidKBVectorStorage
+	}
+	if r.RerankCandidates != 0 && !IsValidKBRerankCandidates(r.RerankCandidates) {
+		return ErrInvalidKBRerankCandidates
+	}
+	return nil
+}
+
+// validateVectorOptions checks the vector storage options of an update request
+func (r UpdateKnowledgeBaseRequest) validateVectorOptions() error {
+	if r.VectorStorage != nil && !IsValidKBVectorStorage(*r.VectorStorage) {
+		return ErrInvalidKBVectorStorage
+	}
+	if r.RerankCandidates != nil && !IsValidKBRerankCandidates(*r.RerankCandidates) {
+		return ErrInvalidKBRerankCandidates
+	}
+	return nil
+}
+
+// rerankCandidateCount returns how many candidates a quantized search of kb should rerank.
+// It never returns fewer than limit so a search can always fill its result set.
+func rerankCandidateCount(kb *KnowledgeBase, limit int) int {
+	candidates := DefaultKBRerankCandidates
+	if kb != nil && kb.RerankCandidates > 0 {
+		candidates = kb.RerankCandidates
+	}
+	if candidates < limit {
+		candidates = limit
+	}
+	return candidates
+}
+
+// quantizedCandidatesCondition returns a condition restricting the chunk alias c to the nearest
+// candidates in the knowledge base's quantized index, or "" for full-precision storage. The
+// caller still orders by the full vector, which reranks the candidates.
+func quantizedCandidatesCondition(storage KBVectorStorage, kbParam, embeddingParam, candidatesParam int) string {
+	var distance string
+	switch storage {
+	case KBVectorStorageHalf:
+		distance = fmt.Sprintf("qc.embedding_half <=> $%d::halfvec", embeddingParam)
+	case KBVectorStorageBinary:
+		distance = fmt.Sprintf("qc.embedding_binary <~> binary_quantize($%d::vector)", embeddingParam)
+	default:
+		return ""
+	}
+
+	column := "embedding_half"
+	if storage == KBVectorStorageBinary {
+		column = "embedding_binary"
+	}
+
+	return fmt.Sprintf(`c.id IN (
+			SELECT qc.id FROM ai.chunks qc
+			WHERE qc.knowledge_base_id = $%d AND qc.%s IS NOT NULL
+			ORDER BY %s
+			LIMIT $%d
+		)`, kbParam, column, distance, candidatesParam)
+}
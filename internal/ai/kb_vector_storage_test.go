@@ -0,0 +1,68 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidKBVectorStorage(t *testing.T) {
+	assert.True(t, IsValidKBVectorStorage(KBVectorStorageFull))
+	assert.True(t, IsValidKBVectorStorage(KBVectorStorageHalf))
+	assert.True(t, IsValidKBVectorStorage(KBVectorStorageBinary))
+	assert.False(t, IsValidKBVectorStorage(""))
+	assert.False(t, IsValidKBVectorStorage("int8"))
+}
+
+func TestValidateVectorOptions(t *testing.T) {
+	t.Run("create defaults are valid", func(t *testing.T) {
+		assert.NoError(t, CreateKnowledgeBaseRequest{}.validateVectorOptions())
+	})
+
+	t.Run("create rejects unknown storage", func(t *testing.T) {
+		err := CreateKnowledgeBaseRequest{VectorStorage: "int8"}.validateVectorOptions()
+		assert.ErrorIs(t, err, ErrInvalidKBVectorStorage)
+	})
+
+	t.Run("create rejects out of range candidates", func(t *testing.T) {
+		err := CreateKnowledgeBaseRequest{VectorStorage: KBVectorStorageBinary, RerankCandidates: MaxKBRerankCandidates + 1}.validateVectorOptions()
+		assert.ErrorIs(t, err, ErrInvalidKBRerankCandidates)
+	})
+
+	t.Run("update checks set fields only", func(t *testing.T) {
+		storage := KBVectorStorageHalf
+		assert.NoError(t, UpdateKnowledgeBaseRequest{VectorStorage: &storage}.validateVectorOptions())
+
+		candidates := 0
+		err := UpdateKnowledgeBaseRequest{RerankCandidates: &candidates}.validateVectorOptions()
+		assert.ErrorIs(t, err, ErrInvalidKBRerankCandidates)
+	})
+}
+
+func TestRerankCandidateCount(t *testing.T) {
+	assert.Equal(t, DefaultKBRerankCandidates, rerankCandidateCount(nil, 5))
+	assert.Equal(t, 40, rerankCandidateCount(&KnowledgeBase{RerankCandidates: 40}, 5))
+	assert.Equal(t, 50, rerankCandidateCount(&KnowledgeBase{RerankCandidates: 40}, 50), "never fewer than the limit")
+}
+
+func TestQuantizedCandidatesCondition(t *testing.T) {
+	t.Run("full precision has no condition", func(t *testing.T) {
+		assert.Empty(t, quantizedCandidatesCondition(KBVectorStorageFull, 1, 4, 5))
+		assert.Empty(t, quantizedCandidatesCondition("", 1, 4, 5))
+	})
+
+	t.Run("halfvec uses the half-precision index", func(t *testing.T) {
+		condition := quantizedCandidatesCondition(KBVectorStorageHalf, 1, 4, 5)
+		assert.Contains(t, condition, "qc.knowledge_base_id = $1")
+		assert.Contains(t, condition, "qc.embedding_half IS NOT NULL")
+		assert.Contains(t, condition, "qc.embedding_half <=> $4::halfvec")
+		assert.Contains(t, condition, "LIMIT $5")
+	})
+
+	t.Run("binary uses hamming distance on the quantized query", func(t *testing.T) {
+		condition := quantizedCandidatesCondition(KBVectorStorageBinary, 1, 4, 7)
+		assert.Contains(t, condition, "qc.embedding_binary IS NOT NULL")
+		assert.Contains(t, condition, "qc.embedding_binary <~> binary_quantize($4::vector)")
+		assert.Contains(t, condition, "LIMIT $7")
+	})
+}
@@ -33,6 +33,13 @@ type KnowledgeBase struct {
 	QuotaMaxDocuments    int   `json:"quota_max_documents"`
 	QuotaMaxChunks       int   `json:"quota_max_chunks"`
 	QuotaMaxStorageBytes int64 `json:"quota_max_storage_bytes"`
+	// Vector index: quantized storage searches a compact index and reranks on full vectors
+	VectorStorage    KBVectorStorage `json:"vector_storage"`
+	RerankCandidates int             `json:"rerank_candidates"`
+	// Webhook notified when a chatbot query finds no chunk above the similarity threshold
+	RetrievalMissWebhookURL string `json:"retrieval_miss_webhook_url,omitempty"`
+	// Text extraction defaults for uploaded files
+	ProcessingOptions ProcessingOptions `json:"processing_options"`
 	// Pipeline configuration
 	PipelineType           string                 `json:"pipeline_type"`
 	PipelineConfig         map[string]interface{} `json:"pipeline_config,omitempty"`
@@ -161,10 +168,12 @@ type ChatbotKnowledgeBase struct {
 	IntentKeywords      []string               `json:"intent_keywords"`      // For query routing
 	MaxChunks           *int                   `json:"max_chunks"`           // NULL = use default
 	SimilarityThreshold *float64               `json:"similarity_threshold"` // NULL = use default
-	Enabled             bool                   `json:"enabled"`
-	Metadata            map[string]interface{} `json:"metadata"`
-	CreatedAt           time.Time              `json:"created_at"`
-	UpdatedAt           time.Time              `json:"updated_at"`
+	// FreshnessHalfLifeDays ranks newer documents higher in hybrid search, NULL = no freshness boost
+	FreshnessHalfLifeDays *float64               `json:"freshness_half_life_days"`
+	Enabled               bool                   `json:"enabled"`
+	Metadata              map[string]interface{} `json:"metadata"`
+	CreatedAt             time.Time              `json:"created_at"`
+	UpdatedAt             time.Time              `json:"updated_at"`
 
 	// Joined fields (not in DB)
 	KnowledgeBaseName string `json:"knowledge_base_name,omitempty"`
@@ -282,36 +291,57 @@ type RetrievalLog struct {
 
 // CreateKnowledgeBaseRequest is the request to create a knowledge base
 type CreateKnowledgeBaseRequest struct {
-	Name                string        `json:"name"`
-	Namespace           string        `json:"namespace,omitempty"`
-	Description         string        `json:"description,omitempty"`
-	Visibility          *KBVisibility `json:"visibility,omitempty"`
-	EmbeddingModel      string        `json:"embedding_model,omitempty"`
-	EmbeddingDimensions int           `json:"embedding_dimensions,omitempty"`
-	ChunkSize           int           `json:"chunk_size,omitempty"`
-	ChunkOverlap        int           `json:"chunk_overlap,omitempty"`
-	ChunkStrategy       string        `json:"chunk_strategy,omitempty"`
+	Name                string            `json:"name"`
+	Namespace           string            `json:"namespace,omitempty"`
+	Description         string            `json:"description,omitempty"`
+	Visibility          *KBVisibility     `json:"visibility,omitempty"`
+	EmbeddingModel      string            `json:"embedding_model,omitempty"`
+	EmbeddingDimensions int               `json:"embedding_dimensions,omitempty"`
+	ChunkSize           int               `json:"chunk_size,omitempty"`
+	ChunkOverlap        int               `json:"chunk_overlap,omitempty"`
+	ChunkStrategy       string            `json:"chunk_strategy,omitempty"`
+	VectorStorage       KBVectorStorage   `json:"vector_storage,omitempty"`
+	RerankCandidates    int               `json:"rerank_candidates,omitempty"`
+	ProcessingOptions   ProcessingOptions `json:"processing_options,omitempty"`
 	// InitialPermissions grants permissions to users upon creation
 	InitialPermissions []KBInitialPermission `json:"initial_permissions,omitempty"`
 }
 
-// KBInitialPermission represents a permission to grant upon KB creation
+// ErrInvalidGrantee is returned when a permission grant does not name exactly one of a user and a group
+var ErrInvalidGrantee = errors.New("exactly one of user_id and group_id is required")
+
+// validateGrantee checks that exactly one of a user and a group is set
+func validateGrantee(userID, groupID string) error {
+	if (userID == "") == (groupID == "") {
+		return ErrInvalidGrantee
+	}
+	return nil
+}
+
+// KBInitialPermission represents a permission to grant upon KB creation.
+// Exactly one of UserID and GroupID must be set.
 type KBInitialPermission struct {
-	UserID     string       `json:"user_id"`
+	UserID     string       `json:"user_id,omitempty"`
+	GroupID    string       `json:"group_id,omitempty"`
 	Permission KBPermission `json:"permission"`
 }
 
 // UpdateKnowledgeBaseRequest is the request to update a knowledge base
 type UpdateKnowledgeBaseRequest struct {
-	Name                *string       `json:"name,omitempty"`
-	Description         *string       `json:"description,omitempty"`
-	Visibility          *KBVisibility `json:"visibility,omitempty"`
-	EmbeddingModel      *string       `json:"embedding_model,omitempty"`
-	EmbeddingDimensions *int          `json:"embedding_dimensions,omitempty"`
-	ChunkSize           *int          `json:"chunk_size,omitempty"`
-	ChunkOverlap        *int          `json:"chunk_overlap,omitempty"`
-	ChunkStrategy       *string       `json:"chunk_strategy,omitempty"`
-	Enabled             *bool         `json:"enabled,omitempty"`
+	Name                *string            `json:"name,omitempty"`
+	Description         *string            `json:"description,omitempty"`
+	Visibility          *KBVisibility      `json:"visibility,omitempty"`
+	EmbeddingModel      *string            `json:"embedding_model,omitempty"`
+	EmbeddingDimensions *int               `json:"embedding_dimensions,omitempty"`
+	ChunkSize           *int               `json:"chunk_size,omitempty"`
+	ChunkOverlap        *int               `json:"chunk_overlap,omitempty"`
+	ChunkStrategy       *string            `json:"chunk_strategy,omitempty"`
+	VectorStorage       *KBVectorStorage   `json:"vector_storage,omitempty"`
+	RerankCandidates    *int               `json:"rerank_candidates,omitempty"`
+	Enabled             *bool              `json:"enabled,omitempty"`
+	ProcessingOptions   *ProcessingOptions `json:"processing_options,omitempty"`
+	// RetrievalMissWebhookURL sets the retrieval miss webhook, an empty string removes it
+	RetrievalMissWebhookURL *string `json:"retrieval_miss_webhook_url,omitempty"`
 }
 
 // CreateDocumentRequest is the request to add a document to a knowledge base
@@ -324,6 +354,8 @@ type CreateDocumentRequest struct {
 	Metadata         map[string]string `json:"metadata,omitempty"`
 	Tags             []string          `json:"tags,omitempty"`
 	OriginalFilename string            `json:"original_filename,omitempty"`
+	// SecretsOverrideReason adds the document despite detected secrets. Only set it for admins.
+	SecretsOverrideReason string `json:"-"`
 }
 
 // LinkKnowledgeBaseRequest is the request to link a knowledge base to a chatbot
@@ -332,6 +364,8 @@ type LinkKnowledgeBaseRequest struct {
 	MaxChunks           *int     `json:"max_chunks,omitempty"`
 	SimilarityThreshold *float64 `json:"similarity_threshold,omitempty"`
 	Priority            *int     `json:"priority,omitempty"`
+	// FreshnessHalfLifeDays enables the freshness boost, 0 or unset = no boost
+	FreshnessHalfLifeDays *float64 `json:"freshness_half_life_days,omitempty"`
 }
 
 // ChunkingStrategy defines the strategy for splitting documents
@@ -353,6 +387,8 @@ func DefaultKnowledgeBaseConfig() CreateKnowledgeBaseRequest {
 		ChunkSize:           512,
 		ChunkOverlap:        50,
 		ChunkStrategy:       string(ChunkingStrategyRecursive),
+		VectorStorage:       KBVectorStorageFull,
+		RerankCandidates:    DefaultKBRerankCandidates,
 	}
 }
 
@@ -378,7 +414,8 @@ const (
 type KBPermissionGrant struct {
 	ID              string       `json:"id"`
 	KnowledgeBaseID string       `json:"knowledge_base_id"`
-	UserID          string       `json:"user_id"`
+	UserID          string       `json:"user_id,omitempty"`  // Set for user grants
+	GroupID         string       `json:"group_id,omitempty"` // Set for group grants
 	Permission      KBPermission `json:"permission"`
 	GrantedBy       *string      `json:"granted_by,omitempty"`
 	GrantedAt       time.Time    `json:"granted_at"`
@@ -400,15 +437,18 @@ const (
 type DocumentPermissionGrant struct {
 	ID         string             `json:"id"`
 	DocumentID string             `json:"document_id"`
-	UserID     string             `json:"user_id"`
+	UserID     string             `json:"user_id,omitempty"`  // Set for user grants
+	GroupID    string             `json:"group_id,omitempty"` // Set for group grants
 	Permission DocumentPermission `json:"permission"`
 	GrantedBy  string             `json:"granted_by"`
 	GrantedAt  time.Time          `json:"granted_at"`
 }
 
-// GrantDocumentPermissionRequest is the request to grant permission on a document
+// GrantDocumentPermissionRequest is the request to grant permission on a document.
+// Exactly one of UserID and GroupID must be set.
 type GrantDocumentPermissionRequest struct {
-	UserID     string             `json:"user_id"`
+	UserID     string             `json:"user_id,omitempty"`
+	GroupID    string             `json:"group_id,omitempty"`
 	Permission DocumentPermission `json:"permission"`
 }
 
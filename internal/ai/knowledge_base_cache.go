@@ -0,0 +1,270 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nimbleflux/fluxbase/internal/pubsub"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	kbCacheKnowledgeBasePrefix = "kb:"
+	kbCacheChatbotPrefix       = "chatbot:"
+)
+
+// KnowledgeBaseCache is a read-through cache for knowledge base config, chatbot links and shadow
+// retrieval profiles, which are read on every chat turn but rarely change. Entries expire after the TTL and are
+// invalidated by the knowledge base storage when they are mutated. When PubSub is configured,
+// invalidation is broadcast to all instances.
+type KnowledgeBaseCache struct {
+	mu             sync.RWMutex
+	ttl            time.Duration
+	knowledgeBases map[string]kbCacheEntry       // key: knowledge base ID
+	chatbotLinks   map[string]kbLinksCacheEntry  // key: chatbot ID
+	shadowProfiles map[string]kbShadowCacheEntry // key: chatbot ID
+
+	// generation is bumped on every invalidation so that a read which raced with a
+	// mutation does not store the value it loaded before the mutation
+	generation uint64
+
+	// PubSub for cross-instance cache invalidation
+	ps         pubsub.PubSub
+	ctx        context.Context
+	cancelFunc context.CancelFunc
+}
+
+type kbCacheEntry struct {
+	kb        KnowledgeBase
+	expiresAt time.Time
+}
+
+type kbLinksCacheEntry struct {
+	links     []ChatbotKnowledgeBase
+	expiresAt time.Time
+}
+
+type kbShadowCacheEntry struct {
+	profile   *ShadowRetrievalProfile // nil if the chatbot has no shadow profile
+	expiresAt time.Time
+}
+
+// NewKnowledgeBaseCache creates a new knowledge base cache with the given TTL
+func NewKnowledgeBaseCache(ttl time.Duration) *KnowledgeBaseCache {
+	return &KnowledgeBaseCache{
+		ttl:            ttl,
+		knowledgeBases: make(map[string]kbCacheEntry),
+		chatbotLinks:   make(map[string]kbLinksCacheEntry),
+		shadowProfiles: make(map[string]kbShadowCacheEntry),
+	}
+}
+
+// getKnowledgeBase returns a copy of the cached knowledge base and the current generation
+func (c *KnowledgeBaseCache) getKnowledgeBase(id string) (*KnowledgeBase, uint64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.knowledgeBases[id]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, c.generation
+	}
+	kb := entry.kb
+	return &kb, c.generation
+}
+
+// setKnowledgeBase stores a knowledge base unless the cache was invalidated since generation
+func (c *KnowledgeBaseCache) setKnowledgeBase(generation uint64, kb *KnowledgeBase) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	c.knowledgeBases[kb.ID] = kbCacheEntry{kb: *kb, expiresAt: time.Now().Add(c.ttl)}
+}
+
+// getChatbotLinks returns a copy of the cached links of a chatbot and the current generation
+func (c *KnowledgeBaseCache) getChatbotLinks(chatbotID string) ([]ChatbotKnowledgeBase, bool, uint64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.chatbotLinks[chatbotID]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false, c.generation
+	}
+	return append([]ChatbotKnowledgeBase(nil), entry.links...), true, c.generation
+}
+
+// setChatbotLinks stores the links of a chatbot unless the cache was invalidated since generation
+func (c *KnowledgeBaseCache) setChatbotLinks(generation uint64, chatbotID string, links []ChatbotKnowledgeBase) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	c.chatbotLinks[chatbotID] = kbLinksCacheEntry{
+		links:     append([]ChatbotKnowledgeBase(nil), links...),
+		expiresAt: time.Now().Add(c.ttl),
+	}
+}
+
+// getShadowProfile returns a copy of the cached shadow profile of a chatbot (nil if it has none),
+// whether it was cached, and the current generation
+func (c *KnowledgeBaseCache) getShadowProfile(chatbotID string) (*ShadowRetrievalProfile, bool, uint64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.shadowProfiles[chatbotID]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false, c.generation
+	}
+	if entry.profile == nil {
+		return nil, true, c.generation
+	}
+	profile := *entry.profile
+	return &profile, true, c.generation
+}
+
+// setShadowProfile stores the shadow profile of a chatbot, nil if it has none, unless the cache
+// was invalidated since generation
+func (c *KnowledgeBaseCache) setShadowProfile(generation uint64, chatbotID string, profile *ShadowRetrievalProfile) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	entry := kbShadowCacheEntry{expiresAt: time.Now().Add(c.ttl)}
+	if profile != nil {
+		stored := *profile
+		entry.profile = &stored
+	}
+	c.shadowProfiles[chatbotID] = entry
+}
+
+// InvalidateKnowledgeBase drops a knowledge base and the links of every chatbot that uses it,
+// and broadcasts the invalidation to all other instances
+func (c *KnowledgeBaseCache) InvalidateKnowledgeBase(ctx context.Context, id string) {
+	c.invalidateAll(ctx, kbCacheKnowledgeBasePrefix+id)
+}
+
+// InvalidateChatbot drops the knowledge base links and the shadow profile of a chatbot and
+// broadcasts the invalidation to all other instances
+func (c *KnowledgeBaseCache) InvalidateChatbot(ctx context.Context, chatbotID string) {
+	c.invalidateAll(ctx, kbCacheChatbotPrefix+chatbotID)
+}
+
+// invalidateAll invalidates locally, then broadcasts to other instances if PubSub is configured
+func (c *KnowledgeBaseCache) invalidateAll(ctx context.Context, key string) {
+	c.invalidate(key)
+
+	c.mu.RLock()
+	ps := c.ps
+	c.mu.RUnlock()
+
+	if ps != nil {
+		if err := ps.Publish(ctx, pubsub.KnowledgeBaseCacheChannel, []byte(key)); err != nil {
+			log.Error().Err(err).Str("key", key).Msg("Failed to broadcast knowledge base cache invalidation")
+		}
+	}
+}
+
+// invalidate drops the entries for an invalidation key. This only invalidates the local cache.
+func (c *KnowledgeBaseCache) invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+
+	switch {
+	case strings.HasPrefix(key, kbCacheKnowledgeBasePrefix):
+		id := strings.TrimPrefix(key, kbCacheKnowledgeBasePrefix)
+		delete(c.knowledgeBases, id)
+		// Links carry the knowledge base name
+		for chatbotID, entry := range c.chatbotLinks {
+			for _, link := range entry.links {
+				if link.KnowledgeBaseID == id {
+					delete(c.chatbotLinks, chatbotID)
+					break
+				}
+			}
+		}
+	case strings.HasPrefix(key, kbCacheChatbotPrefix):
+		chatbotID := strings.TrimPrefix(key, kbCacheChatbotPrefix)
+		delete(c.chatbotLinks, chatbotID)
+		delete(c.shadowProfiles, chatbotID)
+	default:
+		c.knowledgeBases = make(map[string]kbCacheEntry)
+		c.chatbotLinks = make(map[string]kbLinksCacheEntry)
+		c.shadowProfiles = make(map[string]kbShadowCacheEntry)
+	}
+}
+
+// SetPubSub configures the PubSub backend for cross-instance cache invalidation.
+// When set, invalidations are broadcast to all instances, and this instance
+// listens for invalidation messages from others.
+func (c *KnowledgeBaseCache) SetPubSub(ps pubsub.PubSub) {
+	c.mu.Lock()
+	c.ps = ps
+	c.mu.Unlock()
+
+	if ps != nil {
+		c.startInvalidationListener()
+	}
+}
+
+// startInvalidationListener subscribes to knowledge base cache invalidation messages
+// from other instances and invalidates the local cache when received.
+func (c *KnowledgeBaseCache) startInvalidationListener() {
+	c.mu.Lock()
+	// Cancel any existing listener
+	if c.cancelFunc != nil {
+		c.cancelFunc()
+	}
+	c.ctx, c.cancelFunc = context.WithCancel(context.Background())
+	ctx := c.ctx
+	ps := c.ps
+	c.mu.Unlock()
+
+	msgCh, err := ps.Subscribe(ctx, pubsub.KnowledgeBaseCacheChannel)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to subscribe to knowledge base cache invalidation channel")
+		return
+	}
+
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				log.Error().
+					Interface("panic", rec).
+					Str("goroutine", "kb_cache_invalidation").
+					Msg("Panic in knowledge base cache invalidation listener - recovered")
+			}
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-msgCh:
+				if !ok {
+					return
+				}
+				c.invalidate(string(msg.Payload))
+			}
+		}
+	}()
+}
+
+// Close stops the invalidation listener if running
+func (c *KnowledgeBaseCache) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cancelFunc != nil {
+		c.cancelFunc()
+		c.cancelFunc = nil
+	}
+}
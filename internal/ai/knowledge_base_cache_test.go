@@ -0,0 +1,135 @@
+package ai
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nimbleflux/fluxbase/internal/pubsub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKnowledgeBaseCache_KnowledgeBases(t *testing.T) {
+	cache := NewKnowledgeBaseCache(time.Minute)
+
+	kb, generation := cache.getKnowledgeBase("kb-1")
+	assert.Nil(t, kb)
+
+	cache.setKnowledgeBase(generation, &KnowledgeBase{ID: "kb-1", Name: "docs"})
+
+	kb, _ = cache.getKnowledgeBase("kb-1")
+	require.NotNil(t, kb)
+	assert.Equal(t, "docs", kb.Name)
+
+	t.Run("returns copies", func(t *testing.T) {
+		kb.Name = "changed"
+		cached, _ := cache.getKnowledgeBase("kb-1")
+		require.NotNil(t, cached)
+		assert.Equal(t, "docs", cached.Name)
+	})
+
+	t.Run("entries expire", func(t *testing.T) {
+		expiring := NewKnowledgeBaseCache(time.Millisecond)
+		_, generation := expiring.getKnowledgeBase("kb-1")
+		expiring.setKnowledgeBase(generation, &KnowledgeBase{ID: "kb-1"})
+		time.Sleep(5 * time.Millisecond)
+
+		kb, _ := expiring.getKnowledgeBase("kb-1")
+		assert.Nil(t, kb)
+	})
+
+	t.Run("reads that raced with an invalidation are not stored", func(t *testing.T) {
+		_, generation := cache.getKnowledgeBase("kb-2")
+		cache.InvalidateKnowledgeBase(context.Background(), "kb-2")
+		cache.setKnowledgeBase(generation, &KnowledgeBase{ID: "kb-2"})
+
+		kb, _ := cache.getKnowledgeBase("kb-2")
+		assert.Nil(t, kb)
+	})
+}
+
+func TestKnowledgeBaseCache_Invalidation(t *testing.T) {
+	ctx := context.Background()
+	fill := func(cache *KnowledgeBaseCache) {
+		_, generation := cache.getKnowledgeBase("kb-1")
+		cache.setKnowledgeBase(generation, &KnowledgeBase{ID: "kb-1"})
+		cache.setKnowledgeBase(generation, &KnowledgeBase{ID: "kb-2"})
+		cache.setChatbotLinks(generation, "bot-1", []ChatbotKnowledgeBase{{ChatbotID: "bot-1", KnowledgeBaseID: "kb-1"}})
+		cache.setChatbotLinks(generation, "bot-2", []ChatbotKnowledgeBase{{ChatbotID: "bot-2", KnowledgeBaseID: "kb-2"}})
+	}
+	cached := func(cache *KnowledgeBaseCache) (kbs, chatbots []string) {
+		for _, id := range []string{"kb-1", "kb-2"} {
+			if kb, _ := cache.getKnowledgeBase(id); kb != nil {
+				kbs = append(kbs, id)
+			}
+		}
+		for _, id := range []string{"bot-1", "bot-2"} {
+			if _, ok, _ := cache.getChatbotLinks(id); ok {
+				chatbots = append(chatbots, id)
+			}
+		}
+		return kbs, chatbots
+	}
+
+	t.Run("knowledge base drops the links that use it", func(t *testing.T) {
+		cache := NewKnowledgeBaseCache(time.Minute)
+		fill(cache)
+		cache.InvalidateKnowledgeBase(ctx, "kb-1")
+
+		kbs, chatbots := cached(cache)
+		assert.Equal(t, []string{"kb-2"}, kbs)
+		assert.Equal(t, []string{"bot-2"}, chatbots)
+	})
+
+	t.Run("chatbot", func(t *testing.T) {
+		cache := NewKnowledgeBaseCache(time.Minute)
+		fill(cache)
+		cache.InvalidateChatbot(ctx, "bot-2")
+
+		kbs, chatbots := cached(cache)
+		assert.Equal(t, []string{"kb-1", "kb-2"}, kbs)
+		assert.Equal(t, []string{"bot-1"}, chatbots)
+	})
+
+	t.Run("broadcast to other instances", func(t *testing.T) {
+		ps := pubsub.NewLocalPubSub()
+		defer func() { _ = ps.Close() }()
+
+		local := NewKnowledgeBaseCache(time.Minute)
+		local.SetPubSub(ps)
+		defer local.Close()
+		remote := NewKnowledgeBaseCache(time.Minute)
+		remote.SetPubSub(ps)
+		defer remote.Close()
+
+		fill(remote)
+		local.InvalidateChatbot(ctx, "bot-1")
+
+		assert.Eventually(t, func() bool {
+			_, chatbots := cached(remote)
+			return assert.ObjectsAreEqual([]string{"bot-2"}, chatbots)
+		}, time.Second, 5*time.Millisecond)
+	})
+}
+
+func TestKnowledgeBaseStorage_CachedReads(t *testing.T) {
+	storage := NewKnowledgeBaseStorage(nil)
+	assert.Nil(t, storage.cache)
+
+	cache := NewKnowledgeBaseCache(time.Minute)
+	storage.SetCache(cache)
+
+	_, generation := cache.getKnowledgeBase("kb-1")
+	cache.setKnowledgeBase(generation, &KnowledgeBase{ID: "kb-1", Name: "docs"})
+	cache.setChatbotLinks(generation, "bot-1", []ChatbotKnowledgeBase{{ChatbotID: "bot-1", KnowledgeBaseID: "kb-1"}})
+
+	// Cache hits do not touch the database
+	kb, err := storage.GetKnowledgeBaseCached(context.Background(), "kb-1")
+	require.NoError(t, err)
+	assert.Equal(t, "docs", kb.Name)
+
+	links, err := storage.GetChatbotKnowledgeBasesCached(context.Background(), "bot-1")
+	require.NoError(t, err)
+	assert.Len(t, links, 1)
+}
@@ -2,6 +2,7 @@ package ai
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"path/filepath"
@@ -10,7 +11,9 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
 	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/nimbleflux/fluxbase/internal/secretscan"
 	"github.com/nimbleflux/fluxbase/internal/storage"
 	"github.com/rs/zerolog/log"
 )
@@ -71,9 +74,7 @@ func (h *KnowledgeBaseHandler) ListKnowledgeBases(c fiber.Ctx) error {
 	kbs, err := h.storage.ListKnowledgeBases(ctx, namespace, false)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list knowledge bases")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to list knowledge bases",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list knowledge bases")
 	}
 
 	// Convert to summaries
@@ -95,22 +96,16 @@ func (h *KnowledgeBaseHandler) GetKnowledgeBase(c fiber.Ctx) error {
 	id := c.Params("id")
 
 	if id == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Knowledge base ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Knowledge base ID is required")
 	}
 
 	kb, err := h.storage.GetKnowledgeBase(ctx, id)
 	if err != nil {
 		log.Error().Err(err).Str("id", id).Msg("Failed to get knowledge base")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get knowledge base",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get knowledge base")
 	}
 	if kb == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Knowledge base not found",
-		})
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Knowledge base not found")
 	}
 
 	return c.JSON(kb)
@@ -123,24 +118,25 @@ func (h *KnowledgeBaseHandler) CreateKnowledgeBase(c fiber.Ctx) error {
 
 	var req CreateKnowledgeBaseRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
 	}
 
 	// Validate required fields
 	if req.Name == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Name is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Name is required")
+	}
+
+	if err := req.validateVectorOptions(); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
+	}
+	if err := req.validateProcessingOptions(); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
 	}
 
 	kb, err := h.storage.CreateKnowledgeBaseFromRequest(ctx, req)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to create knowledge base")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create knowledge base",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to create knowledge base")
 	}
 
 	// Set created_by and owner_id to current user if available
@@ -165,29 +161,31 @@ func (h *KnowledgeBaseHandler) UpdateKnowledgeBase(c fiber.Ctx) error {
 	id := c.Params("id")
 
 	if id == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Knowledge base ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Knowledge base ID is required")
 	}
 
 	var req UpdateKnowledgeBaseRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+	}
+
+	if err := req.validateVectorOptions(); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
+	}
+	if err := req.validateProcessingOptions(); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
+	}
+	if err := req.validateRetrievalMissWebhook(); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
 	}
 
 	kb, err := h.storage.UpdateKnowledgeBaseByID(ctx, id, req)
 	if err != nil {
 		log.Error().Err(err).Str("id", id).Msg("Failed to update knowledge base")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update knowledge base",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to update knowledge base")
 	}
 	if kb == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Knowledge base not found",
-		})
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Knowledge base not found")
 	}
 
 	return c.JSON(kb)
@@ -200,17 +198,13 @@ func (h *KnowledgeBaseHandler) DeleteKnowledgeBase(c fiber.Ctx) error {
 	id := c.Params("id")
 
 	if id == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Knowledge base ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Knowledge base ID is required")
 	}
 
 	err := h.storage.DeleteKnowledgeBase(ctx, id)
 	if err != nil {
 		log.Error().Err(err).Str("id", id).Msg("Failed to delete knowledge base")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete knowledge base",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to delete knowledge base")
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
@@ -227,17 +221,13 @@ func (h *KnowledgeBaseHandler) ListDocuments(c fiber.Ctx) error {
 	kbID := c.Params("id")
 
 	if kbID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Knowledge base ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Knowledge base ID is required")
 	}
 
 	docs, err := h.storage.ListDocuments(ctx, kbID)
 	if err != nil {
 		log.Error().Err(err).Str("kb_id", kbID).Msg("Failed to list documents")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to list documents",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list documents")
 	}
 
 	return c.JSON(fiber.Map{
@@ -253,22 +243,16 @@ func (h *KnowledgeBaseHandler) GetDocument(c fiber.Ctx) error {
 	docID := c.Params("doc_id")
 
 	if docID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Document ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Document ID is required")
 	}
 
 	doc, err := h.storage.GetDocument(ctx, docID)
 	if err != nil {
 		log.Error().Err(err).Str("doc_id", docID).Msg("Failed to get document")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get document",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get document")
 	}
 	if doc == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Document not found",
-		})
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Document not found")
 	}
 
 	return c.JSON(doc)
@@ -281,6 +265,25 @@ type AddDocumentRequest struct {
 	Source   string            `json:"source,omitempty"`
 	MimeType string            `json:"mime_type,omitempty"`
 	Metadata map[string]string `json:"metadata,omitempty"`
+	// SourceType "qa_pairs" indexes content as question/answer pairs
+	SourceType string `json:"source_type,omitempty"`
+	// SecretsOverrideReason adds a document with detected secrets to a public knowledge base (admins only)
+	SecretsOverrideReason string `json:"secrets_override_reason,omitempty"`
+}
+
+// addDocumentErrorResponse writes the response for an error from DocumentProcessor.AddDocument
+func addDocumentErrorResponse(c fiber.Ctx, err error) error {
+	var blocked *secretscan.BlockedError
+	if errors.As(err, &blocked) {
+		return apierror.SendError(c, &apierror.Error{
+			Status:  fiber.StatusUnprocessableEntity,
+			Code:    apierror.CodeValidationFailed,
+			Message: "Potential secrets detected",
+			Detail:  blocked.Error(),
+			Details: fiber.Map{"findings": blocked.Findings},
+		})
+	}
+	return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to add document")
 }
 
 // AddDocument adds a document to a knowledge base
@@ -290,43 +293,35 @@ func (h *KnowledgeBaseHandler) AddDocument(c fiber.Ctx) error {
 	kbID := c.Params("id")
 
 	if kbID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Knowledge base ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Knowledge base ID is required")
 	}
 
 	var req AddDocumentRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
 	}
 
 	if req.Content == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Content is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Content is required")
+	}
+
+	if err := req.normalizeQAPairs(); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
 	}
 
 	// Check if processor is available
 	if h.processor == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Document processing not available (embedding service not configured)",
-		})
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Document processing not available (embedding service not configured)")
 	}
 
 	// Get knowledge base to check it exists
 	kb, err := h.storage.GetKnowledgeBase(ctx, kbID)
 	if err != nil {
 		log.Error().Err(err).Str("kb_id", kbID).Msg("Failed to get knowledge base")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get knowledge base",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get knowledge base")
 	}
 	if kb == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Knowledge base not found",
-		})
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Knowledge base not found")
 	}
 
 	// Auto-set user_id in metadata for user isolation
@@ -340,19 +335,19 @@ func (h *KnowledgeBaseHandler) AddDocument(c fiber.Ctx) error {
 
 	// Add document asynchronously
 	docReq := CreateDocumentRequest{
-		Title:     req.Title,
-		Content:   req.Content,
-		SourceURL: req.Source,
-		MimeType:  req.MimeType,
-		Metadata:  metadata,
+		Title:                 req.Title,
+		Content:               req.Content,
+		SourceURL:             req.Source,
+		SourceType:            req.SourceType,
+		MimeType:              req.MimeType,
+		Metadata:              metadata,
+		SecretsOverrideReason: req.SecretsOverrideReason,
 	}
 
 	doc, err := h.processor.AddDocument(ctx, kbID, docReq, nil)
 	if err != nil {
 		log.Error().Err(err).Str("kb_id", kbID).Msg("Failed to add document")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to add document",
-		})
+		return addDocumentErrorResponse(c, err)
 	}
 
 	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
@@ -369,53 +364,39 @@ func (h *KnowledgeBaseHandler) UploadDocument(c fiber.Ctx) error {
 	kbID := c.Params("id")
 
 	if kbID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Knowledge base ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Knowledge base ID is required")
 	}
 
 	// Check if storage service is available
 	if h.storageService == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "File upload not available (storage service not configured)",
-		})
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "File upload not available (storage service not configured)")
 	}
 
 	// Check if processor is available
 	if h.processor == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Document processing not available (embedding service not configured)",
-		})
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Document processing not available (embedding service not configured)")
 	}
 
 	// Get knowledge base to check it exists
 	kb, err := h.storage.GetKnowledgeBase(ctx, kbID)
 	if err != nil {
 		log.Error().Err(err).Str("kb_id", kbID).Msg("Failed to get knowledge base")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get knowledge base",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get knowledge base")
 	}
 	if kb == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Knowledge base not found",
-		})
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Knowledge base not found")
 	}
 
 	// Get the uploaded file
 	file, err := c.FormFile("file")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "No file uploaded",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "No file uploaded")
 	}
 
 	// Check file size (max 50MB)
 	maxSize := int64(50 * 1024 * 1024)
 	if file.Size > maxSize {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": fmt.Sprintf("File too large. Maximum size is %dMB", maxSize/(1024*1024)),
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, fmt.Sprintf("File too large. Maximum size is %dMB", maxSize/(1024*1024)))
 	}
 
 	// Determine MIME type from file extension
@@ -434,6 +415,7 @@ func (h *KnowledgeBaseHandler) UploadDocument(c fiber.Ctx) error {
 	if !isSupported {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error":           fmt.Sprintf("Unsupported file type: %s", ext),
+			"code":            apierror.CodeInvalidInput,
 			"supported_types": supported,
 		})
 	}
@@ -441,43 +423,42 @@ func (h *KnowledgeBaseHandler) UploadDocument(c fiber.Ctx) error {
 	// Read file content
 	fileReader, err := file.Open()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to read uploaded file",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to read uploaded file")
 	}
 	defer func() { _ = fileReader.Close() }()
 
 	fileData, err := io.ReadAll(fileReader)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to read file content",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to read file content")
 	}
 
-	// Get optional OCR language from form (comma-separated, e.g., "eng,deu")
-	var ocrLanguages []string
-	if langStr := c.FormValue("language"); langStr != "" {
-		for _, lang := range strings.Split(langStr, ",") {
-			lang = strings.TrimSpace(lang)
-			if lang != "" {
-				ocrLanguages = append(ocrLanguages, lang)
-			}
-		}
+	// Processing options of the upload override those of the knowledge base. The older
+	// language field takes comma-separated OCR languages, e.g. "eng,deu".
+	uploadOptions, err := ParseUploadProcessingOptions(c.FormValue("processing_options"), c.FormValue("language"))
+	if err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
 	}
+	processingOptions := kb.ProcessingOptions.Merge(uploadOptions)
 
-	// Extract text from file (with OCR fallback if needed)
-	extractedText, err := h.textExtractor.ExtractWithLanguages(fileData, mimeType, ocrLanguages)
-	if err != nil {
-		log.Error().Err(err).Str("filename", file.Filename).Str("mime_type", mimeType).Msg("Failed to extract text from file")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to extract text from file: %v", err),
-		})
+	// Q&A files are parsed from the raw data; text extraction would flatten CSV fields
+	sourceType := c.FormValue("source_type")
+	var extractedText string
+	if sourceType == DocumentSourceTypeQAPairs {
+		extractedText, _, err = NormalizeQAPairs(string(fileData), mimeType)
+		if err != nil {
+			return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, fmt.Sprintf("Invalid Q&A pairs: %v", err))
+		}
+	} else {
+		// Extract text from file (with OCR as the processing options direct)
+		extractedText, err = h.textExtractor.ExtractWithOptions(ctx, fileData, mimeType, processingOptions)
+		if err != nil {
+			log.Error().Err(err).Str("filename", file.Filename).Str("mime_type", mimeType).Msg("Failed to extract text from file")
+			return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, fmt.Sprintf("Failed to extract text from file: %v", err))
+		}
 	}
 
 	if strings.TrimSpace(extractedText) == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "No text content could be extracted from the file",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "No text content could be extracted from the file")
 	}
 
 	// Get optional title from form
@@ -519,20 +500,20 @@ func (h *KnowledgeBaseHandler) UploadDocument(c fiber.Ctx) error {
 
 	// Create document with extracted content
 	docReq := CreateDocumentRequest{
-		Title:            title,
-		Content:          extractedText,
-		SourceURL:        sourceURL,
-		MimeType:         mimeType,
-		OriginalFilename: file.Filename,
-		Metadata:         metadata,
+		Title:                 title,
+		Content:               extractedText,
+		SourceURL:             sourceURL,
+		SourceType:            sourceType,
+		MimeType:              mimeType,
+		OriginalFilename:      file.Filename,
+		Metadata:              metadata,
+		SecretsOverrideReason: c.FormValue("secrets_override_reason"),
 	}
 
 	doc, err := h.processor.AddDocument(ctx, kbID, docReq, nil)
 	if err != nil {
 		log.Error().Err(err).Str("kb_id", kbID).Msg("Failed to add document")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to add document",
-		})
+		return addDocumentErrorResponse(c, err)
 	}
 
 	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
@@ -552,9 +533,7 @@ func (h *KnowledgeBaseHandler) DeleteDocument(c fiber.Ctx) error {
 	docID := c.Params("doc_id")
 
 	if docID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Document ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Document ID is required")
 	}
 
 	// First, clean up orphaned entities (those only referenced by this document)
@@ -568,9 +547,7 @@ func (h *KnowledgeBaseHandler) DeleteDocument(c fiber.Ctx) error {
 	err := h.storage.DeleteDocument(ctx, docID)
 	if err != nil {
 		log.Error().Err(err).Str("doc_id", docID).Msg("Failed to delete document")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete document",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to delete document")
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
@@ -583,9 +560,7 @@ func (h *KnowledgeBaseHandler) DeleteDocumentsByFilter(c fiber.Ctx) error {
 	kbID := c.Params("id")
 
 	if kbID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Knowledge base ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Knowledge base ID is required")
 	}
 
 	var req struct {
@@ -595,9 +570,7 @@ func (h *KnowledgeBaseHandler) DeleteDocumentsByFilter(c fiber.Ctx) error {
 	}
 
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
 	}
 
 	// Get user ID from context (for user isolation)
@@ -631,9 +604,7 @@ func (h *KnowledgeBaseHandler) DeleteDocumentsByFilter(c fiber.Ctx) error {
 	count, err := h.storage.DeleteDocumentsByFilter(ctx, kbID, filter)
 	if err != nil {
 		log.Error().Err(err).Str("kb_id", kbID).Msg("Failed to delete documents by filter")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete documents",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to delete documents")
 	}
 
 	return c.JSON(fiber.Map{
@@ -648,9 +619,7 @@ func (h *KnowledgeBaseHandler) UpdateDocument(c fiber.Ctx) error {
 	docID := c.Params("doc_id")
 
 	if docID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Document ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Document ID is required")
 	}
 
 	var req struct {
@@ -660,32 +629,24 @@ func (h *KnowledgeBaseHandler) UpdateDocument(c fiber.Ctx) error {
 	}
 
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
 	}
 
 	// Get existing document first
 	doc, err := h.storage.GetDocument(ctx, docID)
 	if err != nil {
 		log.Error().Err(err).Str("doc_id", docID).Msg("Failed to get document")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get document",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get document")
 	}
 	if doc == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Document not found",
-		})
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Document not found")
 	}
 
 	// Update document metadata
 	updatedDoc, err := h.storage.UpdateDocumentMetadata(ctx, docID, req.Title, req.Metadata, req.Tags)
 	if err != nil {
 		log.Error().Err(err).Str("doc_id", docID).Msg("Failed to update document")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update document",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to update document")
 	}
 
 	return c.JSON(updatedDoc)
@@ -702,17 +663,13 @@ func (h *KnowledgeBaseHandler) ListChatbotKnowledgeBases(c fiber.Ctx) error {
 	chatbotID := c.Params("id")
 
 	if chatbotID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Chatbot ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Chatbot ID is required")
 	}
 
 	links, err := h.storage.GetChatbotKnowledgeBases(ctx, chatbotID)
 	if err != nil {
 		log.Error().Err(err).Str("chatbot_id", chatbotID).Msg("Failed to get chatbot knowledge bases")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get chatbot knowledge bases",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get chatbot knowledge bases")
 	}
 
 	return c.JSON(fiber.Map{
@@ -728,22 +685,16 @@ func (h *KnowledgeBaseHandler) LinkKnowledgeBase(c fiber.Ctx) error {
 	chatbotID := c.Params("id")
 
 	if chatbotID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Chatbot ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Chatbot ID is required")
 	}
 
 	var req LinkKnowledgeBaseRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
 	}
 
 	if req.KnowledgeBaseID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Knowledge base ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Knowledge base ID is required")
 	}
 
 	// Set defaults
@@ -760,16 +711,27 @@ func (h *KnowledgeBaseHandler) LinkKnowledgeBase(c fiber.Ctx) error {
 	if req.SimilarityThreshold != nil {
 		similarityThreshold = *req.SimilarityThreshold
 	}
-
-	link, err := h.storage.LinkChatbotKnowledgeBaseSimple(ctx, chatbotID, req.KnowledgeBaseID, priority, maxChunks, similarityThreshold)
+	freshnessHalfLife, err := normalizeFreshnessHalfLife(req.FreshnessHalfLifeDays)
 	if err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
+	}
+
+	link := &ChatbotKnowledgeBase{
+		ChatbotID:             chatbotID,
+		KnowledgeBaseID:       req.KnowledgeBaseID,
+		AccessLevel:           "full",
+		Enabled:               true,
+		Priority:              priority,
+		MaxChunks:             &maxChunks,
+		SimilarityThreshold:   &similarityThreshold,
+		FreshnessHalfLifeDays: freshnessHalfLife,
+	}
+	if err := h.storage.LinkChatbotKnowledgeBase(ctx, link); err != nil {
 		log.Error().Err(err).
 			Str("chatbot_id", chatbotID).
 			Str("kb_id", req.KnowledgeBaseID).
 			Msg("Failed to link knowledge base")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to link knowledge base",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to link knowledge base")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(link)
@@ -781,6 +743,8 @@ type UpdateChatbotKnowledgeBaseRequest struct {
 	MaxChunks           *int     `json:"max_chunks,omitempty"`
 	SimilarityThreshold *float64 `json:"similarity_threshold,omitempty"`
 	Enabled             *bool    `json:"enabled,omitempty"`
+	// FreshnessHalfLifeDays sets the freshness half-life, 0 removes the freshness boost
+	FreshnessHalfLifeDays *float64 `json:"freshness_half_life_days,omitempty"`
 }
 
 // UpdateChatbotKnowledgeBase updates a chatbot-knowledge base link
@@ -791,23 +755,24 @@ func (h *KnowledgeBaseHandler) UpdateChatbotKnowledgeBase(c fiber.Ctx) error {
 	kbID := c.Params("kb_id")
 
 	if chatbotID == "" || kbID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Chatbot ID and knowledge base ID are required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Chatbot ID and knowledge base ID are required")
 	}
 
 	var req UpdateChatbotKnowledgeBaseRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+	}
+
+	if _, err := normalizeFreshnessHalfLife(req.FreshnessHalfLifeDays); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
 	}
 
 	opts := UpdateChatbotKnowledgeBaseOptions{
-		Priority:            req.Priority,
-		MaxChunks:           req.MaxChunks,
-		SimilarityThreshold: req.SimilarityThreshold,
-		Enabled:             req.Enabled,
+		Priority:              req.Priority,
+		MaxChunks:             req.MaxChunks,
+		SimilarityThreshold:   req.SimilarityThreshold,
+		Enabled:               req.Enabled,
+		FreshnessHalfLifeDays: req.FreshnessHalfLifeDays,
 	}
 
 	link, err := h.storage.UpdateChatbotKnowledgeBaseLink(ctx, chatbotID, kbID, opts)
@@ -816,14 +781,10 @@ func (h *KnowledgeBaseHandler) UpdateChatbotKnowledgeBase(c fiber.Ctx) error {
 			Str("chatbot_id", chatbotID).
 			Str("kb_id", kbID).
 			Msg("Failed to update chatbot knowledge base link")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update chatbot knowledge base link",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to update chatbot knowledge base link")
 	}
 	if link == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Chatbot-knowledge base link not found",
-		})
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Chatbot-knowledge base link not found")
 	}
 
 	return c.JSON(link)
@@ -837,9 +798,7 @@ func (h *KnowledgeBaseHandler) UnlinkKnowledgeBase(c fiber.Ctx) error {
 	kbID := c.Params("kb_id")
 
 	if chatbotID == "" || kbID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Chatbot ID and knowledge base ID are required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Chatbot ID and knowledge base ID are required")
 	}
 
 	err := h.storage.UnlinkChatbotKnowledgeBase(ctx, chatbotID, kbID)
@@ -848,9 +807,7 @@ func (h *KnowledgeBaseHandler) UnlinkKnowledgeBase(c fiber.Ctx) error {
 			Str("chatbot_id", chatbotID).
 			Str("kb_id", kbID).
 			Msg("Failed to unlink knowledge base")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to unlink knowledge base",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to unlink knowledge base")
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
@@ -867,6 +824,9 @@ type SearchKnowledgeBaseRequest struct {
 	Threshold      float64 `json:"threshold,omitempty"`
 	Mode           string  `json:"mode,omitempty"`            // "semantic", "keyword", or "hybrid"
 	SemanticWeight float64 `json:"semantic_weight,omitempty"` // For hybrid mode: 0-1, default 0.5
+	// FreshnessHalfLifeDays ranks newer documents higher in hybrid and keyword mode, 0 = no boost
+	FreshnessHalfLifeDays float64 `json:"freshness_half_life_days,omitempty"`
+	FreshnessWeight       float64 `json:"freshness_weight,omitempty"` // Share of the score subject to the decay, default 0.3
 }
 
 // SearchKnowledgeBase searches a specific knowledge base
@@ -876,29 +836,21 @@ func (h *KnowledgeBaseHandler) SearchKnowledgeBase(c fiber.Ctx) error {
 	kbID := c.Params("id")
 
 	if kbID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Knowledge base ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Knowledge base ID is required")
 	}
 
 	var req SearchKnowledgeBaseRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
 	}
 
 	if req.Query == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Query is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Query is required")
 	}
 
 	// Check if processor is available (has embedding service)
 	if h.processor == nil || h.processor.embeddingService == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Search not available (embedding service not configured)",
-		})
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Search not available (embedding service not configured)")
 	}
 
 	// Set defaults
@@ -911,6 +863,12 @@ func (h *KnowledgeBaseHandler) SearchKnowledgeBase(c fiber.Ctx) error {
 	if req.SemanticWeight == 0 {
 		req.SemanticWeight = 0.5 // Default 50/50 for hybrid
 	}
+	if req.FreshnessHalfLifeDays < 0 {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, ErrInvalidFreshnessHalfLife.Error())
+	}
+	if req.FreshnessWeight < 0 || req.FreshnessWeight > 1 {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "freshness_weight must be between 0 and 1")
+	}
 
 	// Determine search mode
 	searchMode := SearchModeSemantic
@@ -928,9 +886,7 @@ func (h *KnowledgeBaseHandler) SearchKnowledgeBase(c fiber.Ctx) error {
 		embedding, err = h.processor.embeddingService.EmbedSingle(ctx, req.Query, "")
 		if err != nil {
 			log.Error().Err(err).Msg("Failed to embed query")
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to embed query",
-			})
+			return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to embed query")
 		}
 	}
 
@@ -946,18 +902,18 @@ func (h *KnowledgeBaseHandler) SearchKnowledgeBase(c fiber.Ctx) error {
 
 	// Search using hybrid search
 	results, err := h.storage.SearchChunksHybrid(ctx, kbID, HybridSearchOptions{
-		Query:          req.Query,
-		QueryEmbedding: embedding,
-		Limit:          req.MaxChunks,
-		Threshold:      req.Threshold,
-		Mode:           searchMode,
-		SemanticWeight: req.SemanticWeight,
+		Query:                 req.Query,
+		QueryEmbedding:        embedding,
+		Limit:                 req.MaxChunks,
+		Threshold:             req.Threshold,
+		Mode:                  searchMode,
+		SemanticWeight:        req.SemanticWeight,
+		FreshnessHalfLifeDays: req.FreshnessHalfLifeDays,
+		FreshnessWeight:       req.FreshnessWeight,
 	})
 	if err != nil {
 		log.Error().Err(err).Str("kb_id", kbID).Msg("Failed to search knowledge base")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to search knowledge base",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to search knowledge base")
 	}
 
 	// Log results for debugging
@@ -1054,46 +1010,34 @@ func (h *KnowledgeBaseHandler) DebugSearch(c fiber.Ctx) error {
 	kbID := c.Params("id")
 
 	if kbID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Knowledge base ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Knowledge base ID is required")
 	}
 
 	var req DebugSearchRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
 	}
 
 	if req.Query == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Query is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Query is required")
 	}
 
 	// Check if processor is available
 	if h.processor == nil || h.processor.embeddingService == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Embedding service not configured",
-		})
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Embedding service not configured")
 	}
 
 	// Get KB info
 	kb, err := h.storage.GetKnowledgeBase(ctx, kbID)
 	if err != nil || kb == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Knowledge base not found",
-		})
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Knowledge base not found")
 	}
 
 	// Generate embedding for query
 	queryEmbedding, err := h.processor.embeddingService.EmbedSingle(ctx, req.Query, "")
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to embed query")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to embed query",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to embed query")
 	}
 
 	// Get embedding preview (first 10 values)
@@ -1121,9 +1065,7 @@ func (h *KnowledgeBaseHandler) DebugSearch(c fiber.Ctx) error {
 	results, err := h.storage.SearchChunks(ctx, kbID, queryEmbedding, 10, -2.0)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to search chunks")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to search: " + err.Error(),
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to search: "+err.Error())
 	}
 
 	log.Info().
@@ -1197,7 +1139,7 @@ func (h *KnowledgeBaseHandler) DebugSearch(c fiber.Ctx) error {
 // DOCUMENT PERMISSION ENDPOINTS
 // ============================================================================
 
-// GrantDocumentPermission grants permission on a document to a user
+// GrantDocumentPermission grants permission on a document to a user or a group
 // POST /api/v1/admin/ai/knowledge-bases/:kb_id/documents/:doc_id/permissions
 func (h *KnowledgeBaseHandler) GrantDocumentPermission(c fiber.Ctx) error {
 	ctx := c.RequestCtx()
@@ -1207,16 +1149,22 @@ func (h *KnowledgeBaseHandler) GrantDocumentPermission(c fiber.Ctx) error {
 
 	var req GrantDocumentPermissionRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+	}
+
+	if err := validateGrantee(req.UserID, req.GroupID); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
 	}
 
-	grant, err := h.storage.GrantDocumentPermission(ctx, docID, req.UserID, string(req.Permission), userID)
+	var grant *DocumentPermissionGrant
+	var err error
+	if req.GroupID != "" {
+		grant, err = h.storage.GrantDocumentGroupPermission(ctx, docID, req.GroupID, string(req.Permission), userID)
+	} else {
+		grant, err = h.storage.GrantDocumentPermission(ctx, docID, req.UserID, string(req.Permission), userID)
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to grant permission: " + err.Error(),
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to grant permission: "+err.Error())
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(grant)
@@ -1230,9 +1178,7 @@ func (h *KnowledgeBaseHandler) ListDocumentPermissions(c fiber.Ctx) error {
 
 	perms, err := h.storage.ListDocumentPermissions(ctx, docID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to list permissions",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list permissions")
 	}
 
 	return c.JSON(perms)
@@ -1247,9 +1193,22 @@ func (h *KnowledgeBaseHandler) RevokeDocumentPermission(c fiber.Ctx) error {
 
 	err := h.storage.RevokeDocumentPermission(ctx, docID, targetUserID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to revoke permission",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to revoke permission")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RevokeDocumentGroupPermission revokes permission from a group on a document
+// DELETE /api/v1/admin/ai/knowledge-bases/:kb_id/documents/:doc_id/permissions/groups/:group_id
+func (h *KnowledgeBaseHandler) RevokeDocumentGroupPermission(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+	docID := c.Params("doc_id")
+	groupID := c.Params("group_id")
+
+	err := h.storage.RevokeDocumentGroupPermission(ctx, docID, groupID)
+	if err != nil {
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to revoke permission")
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
@@ -1276,25 +1235,19 @@ func (h *KnowledgeBaseHandler) ExportTableToKnowledgeBase(c fiber.Ctx) error {
 	kbID := c.Params("id")
 
 	if kbID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Knowledge base ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Knowledge base ID is required")
 	}
 
 	var req ExportTableRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
 	}
 
 	req.KnowledgeBaseID = kbID
 
 	// Validate required fields
 	if req.Schema == "" || req.Table == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "schema and table are required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "schema and table are required")
 	}
 
 	// Determine owner_id for the document
@@ -1319,17 +1272,13 @@ func (h *KnowledgeBaseHandler) ExportTableToKnowledgeBase(c fiber.Ctx) error {
 	}
 
 	if h.tableExporter == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Table export service not configured",
-		})
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Table export service not configured")
 	}
 
 	result, err := h.tableExporter.ExportTable(ctx, req)
 	if err != nil {
 		log.Error().Err(err).Str("table", req.Table).Msg("Failed to export table")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to export table: %v", err),
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, fmt.Sprintf("Failed to export table: %v", err))
 	}
 
 	return c.JSON(result)
@@ -1343,17 +1292,13 @@ func (h *KnowledgeBaseHandler) ListExportableTables(c fiber.Ctx) error {
 	kbID := c.Query("knowledge_base_id", "")
 
 	if h.tableExporter == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Table export service not configured",
-		})
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Table export service not configured")
 	}
 
 	tables, err := h.tableExporter.ListExportableTables(ctx, []string{schema})
 	if err != nil {
 		log.Error().Err(err).Str("schema", schema).Msg("Failed to list tables")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to list tables",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list tables")
 	}
 
 	// Return simplified table info
@@ -1430,29 +1375,21 @@ func (h *KnowledgeBaseHandler) GetTableDetails(c fiber.Ctx) error {
 	table := c.Params("table")
 
 	if schema == "" || table == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Schema and table parameters are required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Schema and table parameters are required")
 	}
 
 	if h.tableExporter == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Table export service not configured",
-		})
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Table export service not configured")
 	}
 
 	inspector := database.NewSchemaInspector(h.tableExporter.conn)
 	tableInfo, err := inspector.GetTableInfo(ctx, schema, table)
 	if err != nil {
 		log.Error().Err(err).Str("schema", schema).Str("table", table).Msg("Failed to get table info")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get table info",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get table info")
 	}
 	if tableInfo == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Table not found",
-		})
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Table not found")
 	}
 
 	return c.JSON(tableInfo)
@@ -1469,22 +1406,16 @@ func (h *KnowledgeBaseHandler) CreateTableExportSync(c fiber.Ctx) error {
 	kbID := c.Params("id")
 
 	if kbID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Knowledge base ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Knowledge base ID is required")
 	}
 
 	if h.syncService == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Sync service not configured",
-		})
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Sync service not configured")
 	}
 
 	var req CreateTableExportSyncConfig
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
 	}
 
 	req.KnowledgeBaseID = kbID
@@ -1492,9 +1423,7 @@ func (h *KnowledgeBaseHandler) CreateTableExportSync(c fiber.Ctx) error {
 	config, err := h.syncService.CreateSyncConfig(ctx, &req)
 	if err != nil {
 		log.Error().Err(err).Str("kb_id", kbID).Msg("Failed to create sync config")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to create sync config: %v", err),
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, fmt.Sprintf("Failed to create sync config: %v", err))
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(config)
@@ -1507,23 +1436,17 @@ func (h *KnowledgeBaseHandler) ListTableExportSyncs(c fiber.Ctx) error {
 	kbID := c.Params("id")
 
 	if kbID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Knowledge base ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Knowledge base ID is required")
 	}
 
 	if h.syncService == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Sync service not configured",
-		})
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Sync service not configured")
 	}
 
 	configs, err := h.syncService.GetSyncConfigsByKnowledgeBase(ctx, kbID)
 	if err != nil {
 		log.Error().Err(err).Str("kb_id", kbID).Msg("Failed to list sync configs")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to list sync configs",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list sync configs")
 	}
 
 	return c.JSON(fiber.Map{
@@ -1539,30 +1462,22 @@ func (h *KnowledgeBaseHandler) UpdateTableExportSync(c fiber.Ctx) error {
 	syncID := c.Params("syncId")
 
 	if syncID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Sync config ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Sync config ID is required")
 	}
 
 	if h.syncService == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Sync service not configured",
-		})
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Sync service not configured")
 	}
 
 	var req UpdateTableExportSyncConfig
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
 	}
 
 	config, err := h.syncService.UpdateSyncConfig(ctx, syncID, req)
 	if err != nil {
 		log.Error().Err(err).Str("sync_id", syncID).Msg("Failed to update sync config")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to update sync config: %v", err),
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, fmt.Sprintf("Failed to update sync config: %v", err))
 	}
 
 	return c.JSON(config)
@@ -1575,23 +1490,17 @@ func (h *KnowledgeBaseHandler) DeleteTableExportSync(c fiber.Ctx) error {
 	syncID := c.Params("syncId")
 
 	if syncID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Sync config ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Sync config ID is required")
 	}
 
 	if h.syncService == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Sync service not configured",
-		})
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Sync service not configured")
 	}
 
 	err := h.syncService.DeleteSyncConfig(ctx, syncID)
 	if err != nil {
 		log.Error().Err(err).Str("sync_id", syncID).Msg("Failed to delete sync config")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to delete sync config: %v", err),
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, fmt.Sprintf("Failed to delete sync config: %v", err))
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
@@ -1604,23 +1513,17 @@ func (h *KnowledgeBaseHandler) TriggerTableExportSync(c fiber.Ctx) error {
 	syncID := c.Params("syncId")
 
 	if syncID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Sync config ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Sync config ID is required")
 	}
 
 	if h.syncService == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Sync service not configured",
-		})
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Sync service not configured")
 	}
 
 	result, err := h.syncService.TriggerSync(ctx, syncID)
 	if err != nil {
 		log.Error().Err(err).Str("sync_id", syncID).Msg("Failed to trigger sync")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to trigger sync: %v", err),
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, fmt.Sprintf("Failed to trigger sync: %v", err))
 	}
 
 	return c.JSON(result)
@@ -1637,17 +1540,13 @@ func (h *KnowledgeBaseHandler) ListKnowledgeBaseChatbots(c fiber.Ctx) error {
 	kbID := c.Params("id")
 
 	if kbID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Knowledge base ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Knowledge base ID is required")
 	}
 
 	links, err := h.storage.GetKnowledgeBaseChatbots(ctx, kbID)
 	if err != nil {
 		log.Error().Err(err).Str("kb_id", kbID).Msg("Failed to get knowledge base chatbots")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get linked chatbots",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get linked chatbots")
 	}
 
 	return c.JSON(fiber.Map{
@@ -1668,9 +1567,7 @@ func (h *KnowledgeBaseHandler) ListEntities(c fiber.Ctx) error {
 	entityType := c.Query("type")
 
 	if h.knowledgeGraph == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Knowledge graph service not available",
-		})
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Knowledge graph service not available")
 	}
 
 	var entityTypeFilter *EntityType
@@ -1682,9 +1579,7 @@ func (h *KnowledgeBaseHandler) ListEntities(c fiber.Ctx) error {
 	entities, err := h.knowledgeGraph.ListEntities(ctx, kbID, entityTypeFilter)
 	if err != nil {
 		log.Error().Err(err).Str("kb_id", kbID).Msg("Failed to list entities")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to list entities",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list entities")
 	}
 
 	return c.JSON(fiber.Map{
@@ -1710,15 +1605,11 @@ func (h *KnowledgeBaseHandler) SearchEntities(c fiber.Ctx) error {
 	}
 
 	if h.knowledgeGraph == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Knowledge graph service not available",
-		})
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Knowledge graph service not available")
 	}
 
 	if query == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Query parameter 'q' is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Query parameter 'q' is required")
 	}
 
 	var types []EntityType
@@ -1731,9 +1622,7 @@ func (h *KnowledgeBaseHandler) SearchEntities(c fiber.Ctx) error {
 	entities, err := h.knowledgeGraph.SearchEntities(ctx, kbID, query, types, limit)
 	if err != nil {
 		log.Error().Err(err).Str("kb_id", kbID).Str("query", query).Msg("Failed to search entities")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to search entities",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to search entities")
 	}
 
 	return c.JSON(fiber.Map{
@@ -1750,23 +1639,17 @@ func (h *KnowledgeBaseHandler) GetEntityRelationships(c fiber.Ctx) error {
 	entityID := c.Params("entity_id")
 
 	if h.knowledgeGraph == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Knowledge graph service not available",
-		})
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Knowledge graph service not available")
 	}
 
 	if entityID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Entity ID is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Entity ID is required")
 	}
 
 	relationships, err := h.knowledgeGraph.GetRelationships(ctx, kbID, entityID)
 	if err != nil {
 		log.Error().Err(err).Str("kb_id", kbID).Str("entity_id", entityID).Msg("Failed to get entity relationships")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get entity relationships",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get entity relationships")
 	}
 
 	return c.JSON(fiber.Map{
@@ -1782,18 +1665,14 @@ func (h *KnowledgeBaseHandler) GetKnowledgeGraph(c fiber.Ctx) error {
 	kbID := c.Params("id")
 
 	if h.knowledgeGraph == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Knowledge graph service not available",
-		})
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Knowledge graph service not available")
 	}
 
 	// Get all entities
 	entities, err := h.knowledgeGraph.ListEntities(ctx, kbID, nil)
 	if err != nil {
 		log.Error().Err(err).Str("kb_id", kbID).Msg("Failed to list entities for graph")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get knowledge graph",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get knowledge graph")
 	}
 
 	// Get document counts for each entity (for display in the graph)
@@ -2,8 +2,13 @@ package ai
 
 import (
 	"encoding/json"
+	"errors"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
+	"github.com/nimbleflux/fluxbase/internal/secretscan"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -368,3 +373,40 @@ func TestDebugSearchResponse_Struct(t *testing.T) {
 		assert.Contains(t, string(data), `"chunks_found":5`)
 	})
 }
+
+func TestAddDocumentErrorResponse(t *testing.T) {
+	send := func(t *testing.T, err error) (int, apierror.Response) {
+		t.Helper()
+		app := fiber.New()
+		app.Post("/", func(c fiber.Ctx) error { return addDocumentErrorResponse(c, err) })
+		resp, testErr := app.Test(httptest.NewRequest("POST", "/", nil))
+		require.NoError(t, testErr)
+		defer func() { _ = resp.Body.Close() }()
+
+		var body apierror.Response
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return resp.StatusCode, body
+	}
+
+	t.Run("secrets are reported with their findings in details", func(t *testing.T) {
+		status, body := send(t, &secretscan.BlockedError{Findings: []secretscan.Finding{{Rule: "private-key", Line: 1}}})
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+		assert.Equal(t, "Potential secrets detected", body.Error)
+		assert.Equal(t, apierror.CodeValidationFailed, body.Code)
+		assert.Contains(t, body.Message, "1 potential secret")
+
+		details, ok := body.Details.(map[string]interface{})
+		require.True(t, ok)
+		findings, ok := details["findings"].([]interface{})
+		require.True(t, ok)
+		require.Len(t, findings, 1)
+		assert.Equal(t, "private-key", findings[0].(map[string]interface{})["rule"])
+	})
+
+	t.Run("other errors are internal", func(t *testing.T) {
+		status, body := send(t, errors.New("boom"))
+		assert.Equal(t, fiber.StatusInternalServerError, status)
+		assert.Equal(t, apierror.CodeOperationFailed, body.Code)
+		assert.Nil(t, body.Details)
+	})
+}
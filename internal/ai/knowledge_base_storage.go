@@ -12,12 +12,17 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/nimbleflux/fluxbase/internal/observability"
+	"github.com/nimbleflux/fluxbase/internal/query"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // KnowledgeBaseStorage handles database operations for knowledge bases
 type KnowledgeBaseStorage struct {
-	db *database.Connection
+	db             *database.Connection
+	cache          *KnowledgeBaseCache
+	documentEvents DocumentEventPublisher
 }
 
 // NewKnowledgeBaseStorage creates a new knowledge base storage
@@ -25,6 +30,12 @@ func NewKnowledgeBaseStorage(db *database.Connection) *KnowledgeBaseStorage {
 	return &KnowledgeBaseStorage{db: db}
 }
 
+// SetCache sets the cache used by the cached reads of the chat path. Mutations through
+// this storage invalidate it.
+func (s *KnowledgeBaseStorage) SetCache(cache *KnowledgeBaseCache) {
+	s.cache = cache
+}
+
 // ============================================================================
 // Knowledge Base CRUD
 // ============================================================================
@@ -42,16 +53,25 @@ func (s *KnowledgeBaseStorage) CreateKnowledgeBase(ctx context.Context, kb *Know
 			id, name, namespace, description,
 			embedding_model, embedding_dimensions,
 			chunk_size, chunk_overlap, chunk_strategy,
-			enabled, source, created_by, visibility, owner_id
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+			enabled, source, created_by, visibility, owner_id,
+			vector_storage, rerank_candidates, processing_options
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 		RETURNING created_at, updated_at
 	`
 
+	if kb.VectorStorage == "" {
+		kb.VectorStorage = KBVectorStorageFull
+	}
+	if kb.RerankCandidates == 0 {
+		kb.RerankCandidates = DefaultKBRerankCandidates
+	}
+
 	return s.db.QueryRow(ctx, query,
 		kb.ID, kb.Name, kb.Namespace, kb.Description,
 		kb.EmbeddingModel, kb.EmbeddingDimensions,
 		kb.ChunkSize, kb.ChunkOverlap, kb.ChunkStrategy,
 		kb.Enabled, kb.Source, kb.CreatedBy, kb.Visibility, kb.OwnerID,
+		kb.VectorStorage, kb.RerankCandidates, kb.ProcessingOptions,
 	).Scan(&kb.CreatedAt, &kb.UpdatedAt)
 }
 
@@ -63,7 +83,8 @@ func (s *KnowledgeBaseStorage) GetKnowledgeBase(ctx context.Context, id string)
 			chunk_size, chunk_overlap, chunk_strategy,
 			enabled, document_count, total_chunks,
 			source, created_by, created_at, updated_at,
-			visibility, owner_id
+			visibility, owner_id, vector_storage, rerank_candidates, COALESCE(retrieval_miss_webhook_url, ''),
+			processing_options
 		FROM ai.knowledge_bases
 		WHERE id = $1
 	`
@@ -75,7 +96,8 @@ func (s *KnowledgeBaseStorage) GetKnowledgeBase(ctx context.Context, id string)
 		&kb.ChunkSize, &kb.ChunkOverlap, &kb.ChunkStrategy,
 		&kb.Enabled, &kb.DocumentCount, &kb.TotalChunks,
 		&kb.Source, &kb.CreatedBy, &kb.CreatedAt, &kb.UpdatedAt,
-		&kb.Visibility, &kb.OwnerID,
+		&kb.Visibility, &kb.OwnerID, &kb.VectorStorage, &kb.RerankCandidates, &kb.RetrievalMissWebhookURL,
+		&kb.ProcessingOptions,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
@@ -86,6 +108,26 @@ func (s *KnowledgeBaseStorage) GetKnowledgeBase(ctx context.Context, id string)
 	return &kb, nil
 }
 
+// GetKnowledgeBaseCached retrieves a knowledge base by ID through the cache. Document and
+// chunk counts may be up to the cache TTL old, so admin endpoints use GetKnowledgeBase.
+func (s *KnowledgeBaseStorage) GetKnowledgeBaseCached(ctx context.Context, id string) (*KnowledgeBase, error) {
+	if s.cache == nil {
+		return s.GetKnowledgeBase(ctx, id)
+	}
+
+	kb, generation := s.cache.getKnowledgeBase(id)
+	if kb != nil {
+		return kb, nil
+	}
+
+	kb, err := s.GetKnowledgeBase(ctx, id)
+	if err != nil || kb == nil {
+		return kb, err
+	}
+	s.cache.setKnowledgeBase(generation, kb)
+	return kb, nil
+}
+
 // GetKnowledgeBaseByName retrieves a knowledge base by name and namespace
 func (s *KnowledgeBaseStorage) GetKnowledgeBaseByName(ctx context.Context, name, namespace string) (*KnowledgeBase, error) {
 	query := `
@@ -93,7 +135,9 @@ func (s *KnowledgeBaseStorage) GetKnowledgeBaseByName(ctx context.Context, name,
 			embedding_model, embedding_dimensions,
 			chunk_size, chunk_overlap, chunk_strategy,
 			enabled, document_count, total_chunks,
-			source, created_by, created_at, updated_at, visibility
+			source, created_by, created_at, updated_at, visibility,
+			vector_storage, rerank_candidates, COALESCE(retrieval_miss_webhook_url, ''),
+			processing_options
 		FROM ai.knowledge_bases
 		WHERE name = $1 AND namespace = $2
 	`
@@ -105,6 +149,8 @@ func (s *KnowledgeBaseStorage) GetKnowledgeBaseByName(ctx context.Context, name,
 		&kb.ChunkSize, &kb.ChunkOverlap, &kb.ChunkStrategy,
 		&kb.Enabled, &kb.DocumentCount, &kb.TotalChunks,
 		&kb.Source, &kb.CreatedBy, &kb.CreatedAt, &kb.UpdatedAt, &kb.Visibility,
+		&kb.VectorStorage, &kb.RerankCandidates, &kb.RetrievalMissWebhookURL,
+		&kb.ProcessingOptions,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
@@ -122,7 +168,9 @@ func (s *KnowledgeBaseStorage) ListKnowledgeBases(ctx context.Context, namespace
 			embedding_model, embedding_dimensions,
 			chunk_size, chunk_overlap, chunk_strategy,
 			enabled, document_count, total_chunks,
-			source, created_by, created_at, updated_at
+			source, created_by, created_at, updated_at,
+			vector_storage, rerank_candidates, COALESCE(retrieval_miss_webhook_url, ''),
+			processing_options
 		FROM ai.knowledge_bases
 		WHERE ($1 = '' OR namespace = $1)
 		  AND ($2 = false OR enabled = true)
@@ -144,6 +192,8 @@ func (s *KnowledgeBaseStorage) ListKnowledgeBases(ctx context.Context, namespace
 			&kb.ChunkSize, &kb.ChunkOverlap, &kb.ChunkStrategy,
 			&kb.Enabled, &kb.DocumentCount, &kb.TotalChunks,
 			&kb.Source, &kb.CreatedBy, &kb.CreatedAt, &kb.UpdatedAt,
+			&kb.VectorStorage, &kb.RerankCandidates, &kb.RetrievalMissWebhookURL,
+			&kb.ProcessingOptions,
 		); err != nil {
 			log.Warn().Err(err).Msg("Failed to scan knowledge base row")
 			continue
@@ -169,22 +219,35 @@ func (s *KnowledgeBaseStorage) UpdateKnowledgeBase(ctx context.Context, kb *Know
 			visibility = $10,
 			created_by = $11,
 			owner_id = $12,
+			vector_storage = COALESCE(NULLIF($13, ''), vector_storage),
+			rerank_candidates = COALESCE(NULLIF($14, 0), rerank_candidates),
+			retrieval_miss_webhook_url = NULLIF($15, ''),
+			processing_options = $16,
 			updated_at = NOW()
 		WHERE id = $1
 		RETURNING updated_at
 	`
 
-	return s.db.QueryRow(ctx, query,
+	err := s.db.QueryRow(ctx, query,
 		kb.ID, kb.Name, kb.Description,
 		kb.EmbeddingModel, kb.EmbeddingDimensions,
 		kb.ChunkSize, kb.ChunkOverlap, kb.ChunkStrategy,
 		kb.Enabled, kb.Visibility, kb.CreatedBy, kb.OwnerID,
+		string(kb.VectorStorage), kb.RerankCandidates, kb.RetrievalMissWebhookURL,
+		kb.ProcessingOptions,
 	).Scan(&kb.UpdatedAt)
+	if err == nil && s.cache != nil {
+		s.cache.InvalidateKnowledgeBase(ctx, kb.ID)
+	}
+	return err
 }
 
 // DeleteKnowledgeBase deletes a knowledge base and all its documents/chunks
 func (s *KnowledgeBaseStorage) DeleteKnowledgeBase(ctx context.Context, id string) error {
 	_, err := s.db.Exec(ctx, "DELETE FROM ai.knowledge_bases WHERE id = $1", id)
+	if err == nil && s.cache != nil {
+		s.cache.InvalidateKnowledgeBase(ctx, id)
+	}
 	return err
 }
 
@@ -215,10 +278,22 @@ func (s *KnowledgeBaseStorage) CreateDocument(ctx context.Context, doc *Document
 		RETURNING created_at, updated_at
 	`
 
-	return s.db.QueryRow(ctx, query,
+	err := s.db.QueryRow(ctx, query,
 		doc.ID, doc.KnowledgeBaseID, doc.Title, doc.SourceURL, doc.SourceType,
 		doc.MimeType, doc.Content, doc.ContentHash, doc.Status, metadataJSON, doc.Tags, doc.CreatedBy, doc.OwnerID,
 	).Scan(&doc.CreatedAt, &doc.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	s.publishDocumentStatus(&DocumentStatusEvent{
+		KnowledgeBaseID: doc.KnowledgeBaseID,
+		DocumentID:      doc.ID,
+		Title:           doc.Title,
+		Status:          doc.Status,
+		Timestamp:       doc.CreatedAt,
+	})
+	return nil
 }
 
 // GetDocument retrieves a document by ID
@@ -286,9 +361,21 @@ func (s *KnowledgeBaseStorage) UpdateDocumentStatus(ctx context.Context, id stri
 		UPDATE ai.documents SET
 			status = $2, error_message = $3, updated_at = NOW()
 		WHERE id = $1
+		RETURNING knowledge_base_id, COALESCE(title, ''), COALESCE(chunks_count, 0), updated_at
 	`
-	_, err := s.db.Exec(ctx, query, id, status, errorMsg)
-	return err
+	event := DocumentStatusEvent{DocumentID: id, Status: status, ErrorMessage: errorMsg}
+	err := s.db.QueryRow(ctx, query, id, status, errorMsg).Scan(
+		&event.KnowledgeBaseID, &event.Title, &event.ChunksCount, &event.Timestamp,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.publishDocumentStatus(&event)
+	return nil
 }
 
 // MarkDocumentIndexed marks a document as indexed
@@ -297,9 +384,21 @@ func (s *KnowledgeBaseStorage) MarkDocumentIndexed(ctx context.Context, id strin
 		UPDATE ai.documents SET
 			status = 'indexed', indexed_at = NOW(), updated_at = NOW()
 		WHERE id = $1
+		RETURNING knowledge_base_id, COALESCE(title, ''), COALESCE(chunks_count, 0), updated_at
 	`
-	_, err := s.db.Exec(ctx, query, id)
-	return err
+	event := DocumentStatusEvent{DocumentID: id, Status: DocumentStatusIndexed}
+	err := s.db.QueryRow(ctx, query, id).Scan(
+		&event.KnowledgeBaseID, &event.Title, &event.ChunksCount, &event.Timestamp,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.publishDocumentStatus(&event)
+	return nil
 }
 
 // DeleteDocument deletes a document and its chunks
@@ -357,9 +456,9 @@ func (s *KnowledgeBaseStorage) DeleteDocumentsByFilter(
 	// Legacy simple metadata filter (exact match only)
 	if filter != nil && filter.AdvancedFilter == nil && len(filter.Metadata) > 0 {
 		for key, value := range filter.Metadata {
-			escapedKey := escapeStringLiteral(key)
-			whereConditions = append(whereConditions, fmt.Sprintf("metadata->>'%s' = $%d", escapedKey, argIndex))
-			args = append(args, value)
+			keyRef, keyArg := query.BindJSONBKey("metadata", query.JSONBFieldText, key, &argIndex)
+			whereConditions = append(whereConditions, fmt.Sprintf("%s = $%d", keyRef, argIndex))
+			args = append(args, keyArg, value)
 			argIndex++
 		}
 	}
@@ -428,10 +527,9 @@ func (s *KnowledgeBaseStorage) FindDocumentByMetadata(ctx context.Context, knowl
 	var args []interface{}
 
 	for key, value := range metadata {
-		// Escape key to prevent SQL injection
-		escapedKey := strings.ReplaceAll(key, "'", "''")
-		whereConditions = append(whereConditions, fmt.Sprintf("metadata->>'%s' = $%d", escapedKey, argIndex))
-		args = append(args, value)
+		keyRef, keyArg := query.BindJSONBKey("metadata", query.JSONBFieldText, key, &argIndex)
+		whereConditions = append(whereConditions, fmt.Sprintf("%s = $%d", keyRef, argIndex))
+		args = append(args, keyArg, value)
 		argIndex++
 	}
 
@@ -510,26 +608,23 @@ func (s *KnowledgeBaseStorage) CreateChunks(ctx context.Context, chunks []Chunk)
 			metadataJSON = chunk.Metadata
 		}
 
-		// Format embedding as PostgreSQL vector literal (pgx can't encode []float32 directly)
-		var embeddingExpr string
+		// Bind embedding as a vector literal (pgx can't encode []float32 directly)
+		var embedding *string
 		if chunk.Embedding != nil {
-			embeddingExpr = fmt.Sprintf("'%s'::vector", formatEmbeddingLiteral(chunk.Embedding))
-		} else {
-			embeddingExpr = "NULL"
+			literal := formatEmbeddingLiteral(chunk.Embedding)
+			embedding = &literal
 		}
 
-		query := fmt.Sprintf(`
+		batch.Queue(`
 			INSERT INTO ai.chunks (
 				id, document_id, knowledge_base_id, content,
 				chunk_index, start_offset, end_offset, token_count,
 				embedding, metadata
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, %s, $9)
-		`, embeddingExpr)
-
-		batch.Queue(query,
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9::vector, $10)
+		`,
 			chunk.ID, chunk.DocumentID, chunk.KnowledgeBaseID, chunk.Content,
 			chunk.ChunkIndex, chunk.StartOffset, chunk.EndOffset, chunk.TokenCount,
-			metadataJSON,
+			embedding, metadataJSON,
 		)
 	}
 
@@ -612,8 +707,8 @@ func (s *KnowledgeBaseStorage) LinkChatbotKnowledgeBase(ctx context.Context, lin
 			id, chatbot_id, knowledge_base_id,
 			access_level, filter_expression, context_weight, priority,
 			intent_keywords, max_chunks, similarity_threshold,
-			enabled, metadata
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			freshness_half_life_days, enabled, metadata
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		ON CONFLICT (chatbot_id, knowledge_base_id) DO UPDATE SET
 			access_level = EXCLUDED.access_level,
 			filter_expression = EXCLUDED.filter_expression,
@@ -622,18 +717,23 @@ func (s *KnowledgeBaseStorage) LinkChatbotKnowledgeBase(ctx context.Context, lin
 			intent_keywords = EXCLUDED.intent_keywords,
 			max_chunks = EXCLUDED.max_chunks,
 			similarity_threshold = EXCLUDED.similarity_threshold,
+			freshness_half_life_days = EXCLUDED.freshness_half_life_days,
 			enabled = EXCLUDED.enabled,
 			metadata = EXCLUDED.metadata,
 			updated_at = NOW()
 		RETURNING created_at, updated_at
 	`
 
-	return s.db.QueryRow(ctx, query,
+	err := s.db.QueryRow(ctx, query,
 		link.ID, link.ChatbotID, link.KnowledgeBaseID,
 		link.AccessLevel, link.FilterExpression, link.ContextWeight, link.Priority,
 		link.IntentKeywords, link.MaxChunks, link.SimilarityThreshold,
-		link.Enabled, link.Metadata,
+		link.FreshnessHalfLifeDays, link.Enabled, link.Metadata,
 	).Scan(&link.CreatedAt, &link.UpdatedAt)
+	if err == nil && s.cache != nil {
+		s.cache.InvalidateChatbot(ctx, link.ChatbotID)
+	}
+	return err
 }
 
 // GetChatbotKnowledgeBases retrieves all knowledge base links for a chatbot
@@ -642,7 +742,7 @@ func (s *KnowledgeBaseStorage) GetChatbotKnowledgeBases(ctx context.Context, cha
 		SELECT ckb.id, ckb.chatbot_id, ckb.knowledge_base_id,
 			ckb.access_level, ckb.filter_expression, ckb.context_weight,
 			ckb.priority, ckb.intent_keywords, ckb.max_chunks,
-			ckb.similarity_threshold, ckb.enabled, ckb.metadata,
+			ckb.similarity_threshold, ckb.freshness_half_life_days, ckb.enabled, ckb.metadata,
 			ckb.created_at, ckb.updated_at,
 			kb.name as knowledge_base_name
 		FROM ai.chatbot_knowledge_bases ckb
@@ -664,7 +764,7 @@ func (s *KnowledgeBaseStorage) GetChatbotKnowledgeBases(ctx context.Context, cha
 			&link.ID, &link.ChatbotID, &link.KnowledgeBaseID,
 			&link.AccessLevel, &link.FilterExpression, &link.ContextWeight,
 			&link.Priority, &link.IntentKeywords, &link.MaxChunks,
-			&link.SimilarityThreshold, &link.Enabled, &link.Metadata,
+			&link.SimilarityThreshold, &link.FreshnessHalfLifeDays, &link.Enabled, &link.Metadata,
 			&link.CreatedAt, &link.UpdatedAt,
 			&link.KnowledgeBaseName,
 		); err != nil {
@@ -677,6 +777,25 @@ func (s *KnowledgeBaseStorage) GetChatbotKnowledgeBases(ctx context.Context, cha
 	return links, nil
 }
 
+// GetChatbotKnowledgeBasesCached retrieves all knowledge base links for a chatbot through the cache
+func (s *KnowledgeBaseStorage) GetChatbotKnowledgeBasesCached(ctx context.Context, chatbotID string) ([]ChatbotKnowledgeBase, error) {
+	if s.cache == nil {
+		return s.GetChatbotKnowledgeBases(ctx, chatbotID)
+	}
+
+	links, ok, generation := s.cache.getChatbotLinks(chatbotID)
+	if ok {
+		return links, nil
+	}
+
+	links, err := s.GetChatbotKnowledgeBases(ctx, chatbotID)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.setChatbotLinks(generation, chatbotID, links)
+	return links, nil
+}
+
 // GetChatbotKnowledgeBaseLinks is an alias for GetChatbotKnowledgeBases for the query router
 func (s *KnowledgeBaseStorage) GetChatbotKnowledgeBaseLinks(ctx context.Context, chatbotID string) ([]ChatbotKnowledgeBase, error) {
 	return s.GetChatbotKnowledgeBases(ctx, chatbotID)
@@ -689,7 +808,7 @@ func (s *KnowledgeBaseStorage) GetKnowledgeBaseChatbots(ctx context.Context, kno
 		SELECT ckb.id, ckb.chatbot_id, ckb.knowledge_base_id,
 			ckb.access_level, ckb.filter_expression, ckb.context_weight,
 			ckb.priority, ckb.intent_keywords, ckb.max_chunks,
-			ckb.similarity_threshold, ckb.enabled, ckb.metadata,
+			ckb.similarity_threshold, ckb.freshness_half_life_days, ckb.enabled, ckb.metadata,
 			ckb.created_at, ckb.updated_at,
 			c.name as chatbot_name
 		FROM ai.chatbot_knowledge_bases ckb
@@ -711,7 +830,7 @@ func (s *KnowledgeBaseStorage) GetKnowledgeBaseChatbots(ctx context.Context, kno
 			&link.ID, &link.ChatbotID, &link.KnowledgeBaseID,
 			&link.AccessLevel, &link.FilterExpression, &link.ContextWeight,
 			&link.Priority, &link.IntentKeywords, &link.MaxChunks,
-			&link.SimilarityThreshold, &link.Enabled, &link.Metadata,
+			&link.SimilarityThreshold, &link.FreshnessHalfLifeDays, &link.Enabled, &link.Metadata,
 			&link.CreatedAt, &link.UpdatedAt,
 			&link.ChatbotName,
 		); err != nil {
@@ -730,6 +849,9 @@ func (s *KnowledgeBaseStorage) UnlinkChatbotKnowledgeBase(ctx context.Context, c
 		"DELETE FROM ai.chatbot_knowledge_bases WHERE chatbot_id = $1 AND knowledge_base_id = $2",
 		chatbotID, knowledgeBaseID,
 	)
+	if err == nil && s.cache != nil {
+		s.cache.InvalidateChatbot(ctx, chatbotID)
+	}
 	return err
 }
 
@@ -755,23 +877,30 @@ func (s *KnowledgeBaseStorage) SearchChunks(ctx context.Context, knowledgeBaseID
 		Str("embedding_preview", embeddingPreview).
 		Msg("SearchChunks starting")
 
+	args := []interface{}{knowledgeBaseID, threshold, limit, embeddingStr}
+	candidates, args := s.quantizedCandidates(ctx, knowledgeBaseID, limit, args)
+	if candidates != "" {
+		candidates = "AND " + candidates
+	}
+
 	query := fmt.Sprintf(`
 		SELECT
 			c.id as chunk_id,
 			c.document_id,
 			c.content,
-			1 - (c.embedding <=> '%s'::vector) as similarity,
+			1 - (c.embedding <=> $4::vector) as similarity,
 			c.metadata,
 			d.title as document_title
 		FROM ai.chunks c
 		JOIN ai.documents d ON d.id = c.document_id
 		WHERE c.knowledge_base_id = $1
-		  AND 1 - (c.embedding <=> '%s'::vector) >= $2
-		ORDER BY c.embedding <=> '%s'::vector
+		  AND 1 - (c.embedding <=> $4::vector) >= $2
+		  %s
+		ORDER BY c.embedding <=> $4::vector
 		LIMIT $3
-	`, embeddingStr, embeddingStr, embeddingStr)
+	`, candidates)
 
-	rows, err := s.db.Query(ctx, query, knowledgeBaseID, threshold, limit)
+	rows, err := s.db.Query(ctx, query, args...)
 	if err != nil {
 		log.Error().Err(err).Str("kb_id", knowledgeBaseID).Msg("SearchChunks query failed")
 		return nil, fmt.Errorf("failed to search chunks: %w", err)
@@ -810,6 +939,22 @@ func (s *KnowledgeBaseStorage) SearchChunks(ctx context.Context, knowledgeBaseID
 	return results, nil
 }
 
+// quantizedCandidates returns a condition limiting a search of the knowledge base to
+// the candidates of its quantized index, appending the candidate count to args. The query embedding
+// must be $4 and the knowledge base $1. Full-precision knowledge bases get no condition.
+func (s *KnowledgeBaseStorage) quantizedCandidates(ctx context.Context, knowledgeBaseID string, limit int, args []interface{}) (string, []interface{}) {
+	kb, err := s.GetKnowledgeBaseCached(ctx, knowledgeBaseID)
+	if err != nil || kb == nil {
+		return "", args
+	}
+
+	condition := quantizedCandidatesCondition(kb.VectorStorage, 1, 4, len(args)+1)
+	if condition == "" {
+		return "", args
+	}
+	return condition, append(args, rerankCandidateCount(kb, limit))
+}
+
 // SearchMode defines how search should be performed
 type SearchMode string
 
@@ -829,6 +974,26 @@ type HybridSearchOptions struct {
 	SemanticWeight float64         // Weight for semantic score (0-1), keyword weight = 1 - semantic
 	KeywordBoost   float64         // Boost factor for exact keyword matches
 	Filter         *MetadataFilter // Optional metadata filter for user isolation
+
+	// FreshnessHalfLifeDays ranks newer documents higher: the score decays with the age of the
+	// document (updated_at), halving every half-life. 0 disables the boost. Hybrid and keyword
+	// modes only.
+	FreshnessHalfLifeDays float64
+	FreshnessWeight       float64 // Share of the score subject to the decay (0-1), default 0.3
+}
+
+// withDefaults returns the options with the default hybrid weights applied
+func (o HybridSearchOptions) withDefaults() HybridSearchOptions {
+	if o.SemanticWeight == 0 {
+		o.SemanticWeight = 0.5 // 50/50 by default
+	}
+	if o.KeywordBoost == 0 {
+		o.KeywordBoost = 0.3 // 30% boost for keyword matches
+	}
+	if o.FreshnessHalfLifeDays > 0 && o.FreshnessWeight == 0 {
+		o.FreshnessWeight = DefaultFreshnessWeight
+	}
+	return o
 }
 
 // GraphBoostOptions contains options for graph-boosted search
@@ -842,19 +1007,14 @@ type GraphBoostOptions struct {
 
 // SearchChunksHybrid performs hybrid search combining vector similarity with full-text search
 func (s *KnowledgeBaseStorage) SearchChunksHybrid(ctx context.Context, knowledgeBaseID string, opts HybridSearchOptions) ([]RetrievalResult, error) {
-	// Default weights
-	if opts.SemanticWeight == 0 {
-		opts.SemanticWeight = 0.5 // 50/50 by default
-	}
-	if opts.KeywordBoost == 0 {
-		opts.KeywordBoost = 0.3 // 30% boost for keyword matches
-	}
+	opts = opts.withDefaults()
 
 	log.Debug().
 		Str("mode", string(opts.Mode)).
 		Str("query", opts.Query).
 		Float64("semantic_weight", opts.SemanticWeight).
 		Float64("threshold", opts.Threshold).
+		Float64("freshness_half_life_days", opts.FreshnessHalfLifeDays).
 		Msg("SearchChunksHybrid starting")
 
 	switch opts.Mode {
@@ -869,14 +1029,17 @@ func (s *KnowledgeBaseStorage) SearchChunksHybrid(ctx context.Context, knowledge
 
 // searchKeywordOnly performs full-text search only
 func (s *KnowledgeBaseStorage) searchKeywordOnly(ctx context.Context, knowledgeBaseID string, opts HybridSearchOptions) ([]RetrievalResult, error) {
+	args := []interface{}{knowledgeBaseID, opts.Query, opts.Limit}
+	freshness, args := freshnessFactorSQL(opts, args)
+
 	// Prepare the search query for PostgreSQL full-text search
 	// Use plainto_tsquery for simple word matching, or websearch_to_tsquery for more advanced
-	query := `
+	query := fmt.Sprintf(`
 		SELECT
 			c.id as chunk_id,
 			c.document_id,
 			c.content,
-			ts_rank_cd(to_tsvector('simple', c.content), plainto_tsquery('simple', $2)) as similarity,
+			ts_rank_cd(to_tsvector('simple', c.content), plainto_tsquery('simple', $2)) * %s as similarity,
 			c.metadata,
 			d.title as document_title
 		FROM ai.chunks c
@@ -884,13 +1047,13 @@ func (s *KnowledgeBaseStorage) searchKeywordOnly(ctx context.Context, knowledgeB
 		WHERE c.knowledge_base_id = $1
 		  AND (
 		    to_tsvector('simple', c.content) @@ plainto_tsquery('simple', $2)
-		    OR c.content ILIKE '%' || $2 || '%'
+		    OR c.content ILIKE '%%' || $2 || '%%'
 		  )
 		ORDER BY similarity DESC
 		LIMIT $3
-	`
+	`, freshness)
 
-	rows, err := s.db.Query(ctx, query, knowledgeBaseID, opts.Query, opts.Limit)
+	rows, err := s.db.Query(ctx, query, args...)
 	if err != nil {
 		log.Error().Err(err).Str("kb_id", knowledgeBaseID).Msg("Keyword search query failed")
 		return nil, fmt.Errorf("failed to search chunks: %w", err)
@@ -929,41 +1092,14 @@ func (s *KnowledgeBaseStorage) searchHybrid(ctx context.Context, knowledgeBaseID
 	embeddingStr := formatEmbeddingLiteral(opts.QueryEmbedding)
 	keywordWeight := 1 - opts.SemanticWeight
 
-	// Build dynamic filter conditions for user isolation
-	filterConditions := ""
-	args := []interface{}{knowledgeBaseID, opts.Query, opts.SemanticWeight, keywordWeight, opts.KeywordBoost, opts.Threshold, opts.Limit}
-	argIndex := 8
-
-	if opts.Filter != nil && opts.Filter.UserID != nil {
-		// Include user's content OR content without user_id (global)
-		filterConditions += fmt.Sprintf(` AND (
-			d.metadata->>'user_id' = $%d OR
-			d.metadata->>'user_id' IS NULL OR
-			NOT (d.metadata ? 'user_id')
-		)`, argIndex)
-		args = append(args, *opts.Filter.UserID)
-		argIndex++
-	}
-
-	if opts.Filter != nil && len(opts.Filter.Tags) > 0 {
-		filterConditions += fmt.Sprintf(" AND d.tags @> $%d", argIndex)
-		args = append(args, opts.Filter.Tags)
-		argIndex++
-	}
-
-	// Apply arbitrary metadata filters
-	if opts.Filter != nil && len(opts.Filter.Metadata) > 0 {
-		for key, value := range opts.Filter.Metadata {
-			// Use parameterized value but key must be sanitized (alphanumeric + underscore only)
-			safeKey := sanitizeMetadataKey(key)
-			filterConditions += fmt.Sprintf(" AND d.metadata->>'%s' = $%d", safeKey, argIndex)
-			args = append(args, value)
-			argIndex++
-		}
-	}
+	args := []interface{}{knowledgeBaseID, opts.Query, opts.SemanticWeight, keywordWeight, opts.KeywordBoost, opts.Threshold, opts.Limit, embeddingStr}
+	filterConditions, args := hybridFilterConditions(opts.Filter, args)
+	freshness, args := freshnessFactorSQL(opts, args)
 
 	// Hybrid query combining vector similarity and full-text search
-	// The final score is: (semantic_weight * vector_similarity) + (keyword_weight * text_rank) + keyword_boost_if_match
+	// The final score is: (semantic_weight * vector_similarity) + (keyword_weight * text_rank) + keyword_boost_if_match,
+	// multiplied by the freshness factor. The threshold applies to the score before the freshness
+	// factor, so the boost reorders relevant chunks but never drops them.
 	query := fmt.Sprintf(`
 		WITH vector_search AS (
 			SELECT
@@ -971,7 +1107,7 @@ func (s *KnowledgeBaseStorage) searchHybrid(ctx context.Context, knowledgeBaseID
 				c.document_id,
 				c.content,
 				c.metadata,
-				1 - (c.embedding <=> '%s'::vector) as vector_similarity
+				1 - (c.embedding <=> $8::vector) as vector_similarity
 			FROM ai.chunks c
 			WHERE c.knowledge_base_id = $1
 			  AND c.embedding IS NOT NULL
@@ -991,7 +1127,7 @@ func (s *KnowledgeBaseStorage) searchHybrid(ctx context.Context, knowledgeBaseID
 			v.chunk_id,
 			v.document_id,
 			v.content,
-			(($3::float * v.vector_similarity) + ($4::float * COALESCE(t.text_rank, 0)) + COALESCE(t.keyword_boost, 0)) as similarity,
+			(($3::float * v.vector_similarity) + ($4::float * COALESCE(t.text_rank, 0)) + COALESCE(t.keyword_boost, 0)) * %s as similarity,
 			v.metadata,
 			d.title as document_title,
 			d.tags,
@@ -1004,7 +1140,7 @@ func (s *KnowledgeBaseStorage) searchHybrid(ctx context.Context, knowledgeBaseID
 		%s
 		ORDER BY similarity DESC
 		LIMIT $7
-	`, embeddingStr, filterConditions)
+	`, freshness, filterConditions)
 
 	rows, err := s.db.Query(ctx, query, args...)
 	if err != nil {
@@ -1047,6 +1183,43 @@ func (s *KnowledgeBaseStorage) searchHybrid(ctx context.Context, knowledgeBaseID
 	return results, nil
 }
 
+// hybridFilterConditions builds the " AND ..." conditions on the document alias d for a
+// hybrid search filter, appending their bind values to args
+func hybridFilterConditions(filter *MetadataFilter, args []interface{}) (string, []interface{}) {
+	if filter == nil {
+		return "", args
+	}
+	filterConditions := ""
+	argIndex := len(args) + 1
+
+	if filter.UserID != nil {
+		// Include user's content OR content without user_id (global)
+		filterConditions += fmt.Sprintf(` AND (
+			d.metadata->>'user_id' = $%d OR
+			d.metadata->>'user_id' IS NULL OR
+			NOT (d.metadata ? 'user_id')
+		)`, argIndex)
+		args = append(args, *filter.UserID)
+		argIndex++
+	}
+
+	if len(filter.Tags) > 0 {
+		filterConditions += fmt.Sprintf(" AND d.tags @> $%d", argIndex)
+		args = append(args, filter.Tags)
+		argIndex++
+	}
+
+	// Apply arbitrary metadata filters
+	for key, value := range filter.Metadata {
+		keyRef, keyArg := query.BindJSONBKey("d.metadata", query.JSONBFieldText, key, &argIndex)
+		filterConditions += fmt.Sprintf(" AND %s = $%d", keyRef, argIndex)
+		args = append(args, keyArg, value)
+		argIndex++
+	}
+
+	return filterConditions, args
+}
+
 // SearchChunksWithGraphBoost performs vector search with entity-based boosting
 // This combines semantic similarity with knowledge graph entity salience
 // Entities are extracted from the query and documents mentioning those entities receive a ranking boost
@@ -1252,127 +1425,9 @@ func buildMetadataFilterSQL(group MetadataFilterGroup, argIndex *int) (string, [
 	return whereClause, args, nil
 }
 
-// buildConditionSQL builds SQL for a single MetadataCondition
+// buildConditionSQL builds SQL for a single MetadataCondition on documents aliased as "d"
 func buildConditionSQL(cond MetadataCondition, argIndex *int) (string, []interface{}, error) {
-	var args []interface{}
-	var sqlCond string
-
-	// Use d.metadata->>'key' syntax to extract metadata as text
-	metadataRef := fmt.Sprintf("d.metadata->>'%s'", escapeStringLiteral(cond.Key))
-
-	switch cond.Operator {
-	case MetadataOpEquals:
-		if cond.Value == nil {
-			return "", nil, errors.New("value is required for equals operator")
-		}
-		sqlCond = fmt.Sprintf("%s = $%d", metadataRef, *argIndex)
-		args = append(args, fmt.Sprintf("%v", cond.Value))
-		*argIndex++
-
-	case MetadataOpNotEquals:
-		if cond.Value == nil {
-			return "", nil, errors.New("value is required for not equals operator")
-		}
-		sqlCond = fmt.Sprintf("%s != $%d", metadataRef, *argIndex)
-		args = append(args, fmt.Sprintf("%v", cond.Value))
-		*argIndex++
-
-	case MetadataOpILike:
-		if cond.Value == nil {
-			return "", nil, errors.New("value is required for ilike operator")
-		}
-		sqlCond = fmt.Sprintf("%s ILIKE $%d", metadataRef, *argIndex)
-		args = append(args, fmt.Sprintf("%v", cond.Value))
-		*argIndex++
-
-	case MetadataOpLike:
-		if cond.Value == nil {
-			return "", nil, errors.New("value is required for like operator")
-		}
-		sqlCond = fmt.Sprintf("%s LIKE $%d", metadataRef, *argIndex)
-		args = append(args, fmt.Sprintf("%v", cond.Value))
-		*argIndex++
-
-	case MetadataOpIn:
-		if len(cond.Values) == 0 {
-			return "", nil, errors.New("values are required for IN operator")
-		}
-		placeholders := make([]string, len(cond.Values))
-		for i, v := range cond.Values {
-			placeholders[i] = fmt.Sprintf("$%d", *argIndex)
-			args = append(args, fmt.Sprintf("%v", v))
-			*argIndex++
-		}
-		sqlCond = fmt.Sprintf("%s IN (%s)", metadataRef, strings.Join(placeholders, ", "))
-
-	case MetadataOpNotIn:
-		if len(cond.Values) == 0 {
-			return "", nil, errors.New("values are required for NOT IN operator")
-		}
-		placeholders := make([]string, len(cond.Values))
-		for i, v := range cond.Values {
-			placeholders[i] = fmt.Sprintf("$%d", *argIndex)
-			args = append(args, fmt.Sprintf("%v", v))
-			*argIndex++
-		}
-		sqlCond = fmt.Sprintf("%s NOT IN (%s)", metadataRef, strings.Join(placeholders, ", "))
-
-	case MetadataOpGreaterThan:
-		if cond.Value == nil {
-			return "", nil, errors.New("value is required for greater than operator")
-		}
-		sqlCond = fmt.Sprintf("%s > $%d", metadataRef, *argIndex)
-		args = append(args, fmt.Sprintf("%v", cond.Value))
-		*argIndex++
-
-	case MetadataOpGreaterThanOr:
-		if cond.Value == nil {
-			return "", nil, errors.New("value is required for greater than or equal operator")
-		}
-		sqlCond = fmt.Sprintf("%s >= $%d", metadataRef, *argIndex)
-		args = append(args, fmt.Sprintf("%v", cond.Value))
-		*argIndex++
-
-	case MetadataOpLessThan:
-		if cond.Value == nil {
-			return "", nil, errors.New("value is required for less than operator")
-		}
-		sqlCond = fmt.Sprintf("%s < $%d", metadataRef, *argIndex)
-		args = append(args, fmt.Sprintf("%v", cond.Value))
-		*argIndex++
-
-	case MetadataOpLessThanOr:
-		if cond.Value == nil {
-			return "", nil, errors.New("value is required for less than or equal operator")
-		}
-		sqlCond = fmt.Sprintf("%s <= $%d", metadataRef, *argIndex)
-		args = append(args, fmt.Sprintf("%v", cond.Value))
-		*argIndex++
-
-	case MetadataOpBetween:
-		if cond.Min == nil || cond.Max == nil {
-			return "", nil, errors.New("min and max are required for BETWEEN operator")
-		}
-		sqlCond = fmt.Sprintf("%s BETWEEN $%d AND $%d", metadataRef, *argIndex, *argIndex+1)
-		args = append(args, fmt.Sprintf("%v", cond.Min), fmt.Sprintf("%v", cond.Max))
-		*argIndex += 2
-
-	case MetadataOpIsNull:
-		sqlCond = fmt.Sprintf("%s IS NULL", metadataRef)
-
-	case MetadataOpIsNotNull:
-		sqlCond = fmt.Sprintf("%s IS NOT NULL", metadataRef)
-
-	default:
-		return "", nil, fmt.Errorf("unsupported operator: %s", cond.Operator)
-	}
-
-	return sqlCond, args, nil
-}
-
-// escapeStringLiteral escapes single quotes in a string literal for SQL
-func escapeStringLiteral(s string) string {
-	return strings.ReplaceAll(s, "'", "''")
+	return buildConditionSQLForTable(cond, argIndex, "d.")
 }
 
 // buildMetadataFilterSQLForTable builds SQL WHERE conditions and args from a MetadataFilterGroup
@@ -1427,11 +1482,11 @@ func buildMetadataFilterSQLForTable(group MetadataFilterGroup, argIndex *int, ta
 
 // buildConditionSQLForTable builds SQL for a single MetadataCondition with a table prefix
 func buildConditionSQLForTable(cond MetadataCondition, argIndex *int, tablePrefix string) (string, []interface{}, error) {
-	var args []interface{}
 	var sqlCond string
 
-	// Use prefix + metadata->>'key' syntax
-	metadataRef := fmt.Sprintf("%smetadata->>'%s'", tablePrefix, escapeStringLiteral(cond.Key))
+	// Extract the metadata value as text; the key is bound as a parameter
+	metadataRef, keyArg := query.BindJSONBKey(tablePrefix+"metadata", query.JSONBFieldText, cond.Key, argIndex)
+	args := []interface{}{keyArg}
 
 	switch cond.Operator {
 	case MetadataOpEquals:
@@ -1558,10 +1613,14 @@ func (s *KnowledgeBaseStorage) SearchChunksWithFilter(
 	// Build dynamic WHERE clause for filtering
 	whereConditions := []string{
 		"c.knowledge_base_id = $1",
-		fmt.Sprintf("1 - (c.embedding <=> '%s'::vector) >= $2", embeddingStr),
+		"1 - (c.embedding <=> $4::vector) >= $2",
+	}
+	args := []interface{}{knowledgeBaseID, threshold, limit, embeddingStr}
+	if candidates, candidateArgs := s.quantizedCandidates(ctx, knowledgeBaseID, limit, args); candidates != "" {
+		whereConditions = append(whereConditions, candidates)
+		args = candidateArgs
 	}
-	args := []interface{}{knowledgeBaseID, threshold, limit}
-	argIndex := 4
+	argIndex := len(args) + 1
 
 	// User isolation filter
 	if filter != nil && filter.UserID != nil {
@@ -1597,9 +1656,9 @@ func (s *KnowledgeBaseStorage) SearchChunksWithFilter(
 	// Legacy simple metadata filter (exact match only) - for backward compatibility
 	if filter != nil && filter.AdvancedFilter == nil && len(filter.Metadata) > 0 {
 		for key, value := range filter.Metadata {
-			escapedKey := escapeStringLiteral(key)
-			whereConditions = append(whereConditions, fmt.Sprintf("d.metadata->>'%s' = $%d", escapedKey, argIndex))
-			args = append(args, value)
+			keyRef, keyArg := query.BindJSONBKey("d.metadata", query.JSONBFieldText, key, &argIndex)
+			whereConditions = append(whereConditions, fmt.Sprintf("%s = $%d", keyRef, argIndex))
+			args = append(args, keyArg, value)
 			argIndex++
 		}
 	}
@@ -1611,16 +1670,16 @@ func (s *KnowledgeBaseStorage) SearchChunksWithFilter(
 			c.id as chunk_id,
 			c.document_id,
 			c.content,
-			1 - (c.embedding <=> '%s'::vector) as similarity,
+			1 - (c.embedding <=> $4::vector) as similarity,
 			c.metadata,
 			d.title as document_title,
 			d.tags
 		FROM ai.chunks c
 		JOIN ai.documents d ON d.id = c.document_id
 		WHERE %s
-		ORDER BY c.embedding <=> '%s'::vector
+		ORDER BY c.embedding <=> $4::vector
 		LIMIT $3
-	`, embeddingStr, whereClause, embeddingStr)
+	`, whereClause)
 
 	rows, err := s.db.Query(ctx, query, args...)
 	if err != nil {
@@ -1663,7 +1722,7 @@ func (s *KnowledgeBaseStorage) SearchChatbotKnowledge(ctx context.Context, chatb
 // SearchChatbotKnowledgeWithOptions searches all knowledge bases linked to a chatbot with user context
 func (s *KnowledgeBaseStorage) SearchChatbotKnowledgeWithOptions(ctx context.Context, chatbotID string, queryEmbedding []float32, opts SearchChatbotKnowledgeOptions) ([]RetrievalResult, error) {
 	// Get linked knowledge bases
-	links, err := s.GetChatbotKnowledgeBases(ctx, chatbotID)
+	links, err := s.GetChatbotKnowledgeBasesCached(ctx, chatbotID)
 	if err != nil {
 		return nil, err
 	}
@@ -1679,50 +1738,24 @@ func (s *KnowledgeBaseStorage) SearchChatbotKnowledgeWithOptions(ctx context.Con
 			continue
 		}
 
-		// Use link defaults or fall back to system defaults
-		maxChunks := 5
-		if link.MaxChunks != nil {
-			maxChunks = *link.MaxChunks
-		}
-		if opts.MaxChunks > 0 {
-			maxChunks = opts.MaxChunks
-		}
-
-		threshold := 0.7
-		if link.SimilarityThreshold != nil {
-			threshold = *link.SimilarityThreshold
-		}
-		if opts.Threshold > 0 {
-			threshold = opts.Threshold
-		}
+		maxChunks, threshold := link.searchLimits(opts)
 
 		var results []RetrievalResult
 
-		// Build filter for user isolation and access level
-		var filter *MetadataFilter
-		if opts.UserID != nil || link.AccessLevel == "filtered" {
-			filter = &MetadataFilter{}
-
-			// Apply user isolation if UserID provided
-			if opts.UserID != nil {
-				filter.UserID = opts.UserID
-				filter.IncludeGlobal = true
-			}
-
-			// Apply FilterExpression for "filtered" access level
-			if link.AccessLevel == "filtered" && link.FilterExpression != nil {
-				advancedFilter := convertFilterExpression(link.FilterExpression, opts.UserID)
-				if advancedFilter != nil {
-					filter.AdvancedFilter = advancedFilter
-				}
-			}
-		}
-
+		filter := link.searchFilter(opts.UserID)
+		searchCtx, span := observability.StartAISpan(ctx, "vector_search",
+			attribute.String("ai.knowledge_base_id", link.KnowledgeBaseID),
+			attribute.Int("ai.max_chunks", maxChunks),
+			attribute.Float64("ai.threshold", threshold),
+			attribute.Bool("ai.filtered", filter != nil),
+		)
 		if filter != nil {
-			results, err = s.SearchChunksWithFilter(ctx, link.KnowledgeBaseID, queryEmbedding, maxChunks, threshold, filter)
+			results, err = s.SearchChunksWithFilter(searchCtx, link.KnowledgeBaseID, queryEmbedding, maxChunks, threshold, filter)
 		} else {
-			results, err = s.SearchChunks(ctx, link.KnowledgeBaseID, queryEmbedding, maxChunks, threshold)
+			results, err = s.SearchChunks(searchCtx, link.KnowledgeBaseID, queryEmbedding, maxChunks, threshold)
 		}
+		span.SetAttributes(attribute.Int("ai.results", len(results)))
+		observability.EndAISpan(span, err)
 
 		if err != nil {
 			log.Warn().Err(err).Str("kb_id", link.KnowledgeBaseID).Msg("Failed to search knowledge base")
@@ -1730,7 +1763,7 @@ func (s *KnowledgeBaseStorage) SearchChatbotKnowledgeWithOptions(ctx context.Con
 		}
 
 		// Get KB name for context
-		kb, err := s.GetKnowledgeBase(ctx, link.KnowledgeBaseID)
+		kb, err := s.GetKnowledgeBaseCached(ctx, link.KnowledgeBaseID)
 		if err == nil && kb != nil {
 			for i := range results {
 				results[i].KnowledgeBaseName = kb.Name
@@ -1743,6 +1776,50 @@ func (s *KnowledgeBaseStorage) SearchChatbotKnowledgeWithOptions(ctx context.Con
 	return allResults, nil
 }
 
+// searchLimits returns the chunk limit and similarity threshold of searches through the link,
+// using the link defaults or falling back to system defaults
+func (l *ChatbotKnowledgeBase) searchLimits(opts SearchChatbotKnowledgeOptions) (int, float64) {
+	maxChunks := 5
+	if l.MaxChunks != nil {
+		maxChunks = *l.MaxChunks
+	}
+	if opts.MaxChunks > 0 {
+		maxChunks = opts.MaxChunks
+	}
+
+	threshold := 0.7
+	if l.SimilarityThreshold != nil {
+		threshold = *l.SimilarityThreshold
+	}
+	if opts.Threshold > 0 {
+		threshold = opts.Threshold
+	}
+	return maxChunks, threshold
+}
+
+// searchFilter returns the filter for user isolation and the access level of the link, or nil
+// when searches through the link are unfiltered
+func (l *ChatbotKnowledgeBase) searchFilter(userID *string) *MetadataFilter {
+	if userID == nil && l.AccessLevel != "filtered" {
+		return nil
+	}
+	filter := &MetadataFilter{}
+
+	// Apply user isolation if UserID provided
+	if userID != nil {
+		filter.UserID = userID
+		filter.IncludeGlobal = true
+	}
+
+	// Apply FilterExpression for "filtered" access level
+	if l.AccessLevel == "filtered" && l.FilterExpression != nil {
+		if advancedFilter := convertFilterExpression(l.FilterExpression, userID); advancedFilter != nil {
+			filter.AdvancedFilter = advancedFilter
+		}
+	}
+	return filter
+}
+
 // convertFilterExpression converts a FilterExpression map to a MetadataFilterGroup
 // It also substitutes special variables like $user_id
 func convertFilterExpression(expr map[string]interface{}, userID *string) *MetadataFilterGroup {
@@ -1833,18 +1910,6 @@ func joinStrings(parts []string, sep string) string {
 	return result
 }
 
-// sanitizeMetadataKey sanitizes a metadata key to prevent SQL injection
-// Only allows alphanumeric characters and underscores
-func sanitizeMetadataKey(key string) string {
-	var result strings.Builder
-	for _, r := range key {
-		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
-			result.WriteRune(r)
-		}
-	}
-	return result.String()
-}
-
 // GetPendingDocuments retrieves documents pending processing
 func (s *KnowledgeBaseStorage) GetPendingDocuments(ctx context.Context, limit int) ([]Document, error) {
 	query := `
@@ -2023,6 +2088,17 @@ func (s *KnowledgeBaseStorage) CreateKnowledgeBaseFromRequest(ctx context.Contex
 	} else {
 		kb.ChunkStrategy = defaults.ChunkStrategy
 	}
+	if req.VectorStorage != "" {
+		kb.VectorStorage = req.VectorStorage
+	} else {
+		kb.VectorStorage = defaults.VectorStorage
+	}
+	if req.RerankCandidates > 0 {
+		kb.RerankCandidates = req.RerankCandidates
+	} else {
+		kb.RerankCandidates = defaults.RerankCandidates
+	}
+	kb.ProcessingOptions = req.ProcessingOptions
 
 	if err := s.CreateKnowledgeBase(ctx, kb); err != nil {
 		return nil, err
@@ -2067,6 +2143,18 @@ func (s *KnowledgeBaseStorage) UpdateKnowledgeBaseByID(ctx context.Context, id s
 	if req.ChunkStrategy != nil {
 		kb.ChunkStrategy = *req.ChunkStrategy
 	}
+	if req.VectorStorage != nil {
+		kb.VectorStorage = *req.VectorStorage
+	}
+	if req.RerankCandidates != nil {
+		kb.RerankCandidates = *req.RerankCandidates
+	}
+	if req.RetrievalMissWebhookURL != nil {
+		kb.RetrievalMissWebhookURL = strings.TrimSpace(*req.RetrievalMissWebhookURL)
+	}
+	if req.ProcessingOptions != nil {
+		kb.ProcessingOptions = *req.ProcessingOptions
+	}
 	if req.Enabled != nil {
 		kb.Enabled = *req.Enabled
 	}
@@ -2093,6 +2181,8 @@ type UpdateChatbotKnowledgeBaseOptions struct {
 	MaxChunks           *int
 	SimilarityThreshold *float64
 	Enabled             *bool
+	// FreshnessHalfLifeDays sets the freshness half-life, 0 removes the freshness boost
+	FreshnessHalfLifeDays *float64
 }
 
 // UpdateChatbotKnowledgeBaseLink updates a chatbot-knowledge base link
@@ -2140,6 +2230,13 @@ func (s *KnowledgeBaseStorage) UpdateChatbotKnowledgeBaseLink(ctx context.Contex
 	if opts.Enabled != nil {
 		existingLink.Enabled = *opts.Enabled
 	}
+	if opts.FreshnessHalfLifeDays != nil {
+		halfLife, err := normalizeFreshnessHalfLife(opts.FreshnessHalfLifeDays)
+		if err != nil {
+			return nil, err
+		}
+		existingLink.FreshnessHalfLifeDays = halfLife
+	}
 
 	// Update using the existing link method (which handles upsert)
 	if err := s.LinkChatbotKnowledgeBase(ctx, existingLink); err != nil {
@@ -2200,16 +2297,23 @@ func (s *KnowledgeBaseStorage) SyncChatbotKnowledgeBaseLinks(ctx context.Context
 		}
 	}
 
+	// The freshness half-life is not part of the chatbot config, so keep what was set via the API
+	freshnessHalfLives := make(map[string]*float64)
+	for _, existingLink := range existingLinks {
+		freshnessHalfLives[existingLink.KnowledgeBaseID] = existingLink.FreshnessHalfLifeDays
+	}
+
 	// Create or update links for KBs in the config
 	for kbID := range expectedKbIDs {
 		link := &ChatbotKnowledgeBase{
-			ChatbotID:           chatbotID,
-			KnowledgeBaseID:     kbID,
-			AccessLevel:         "full",
-			Enabled:             true,
-			Priority:            1,
-			MaxChunks:           &maxChunks,
-			SimilarityThreshold: &similarityThreshold,
+			ChatbotID:             chatbotID,
+			KnowledgeBaseID:       kbID,
+			AccessLevel:           "full",
+			Enabled:               true,
+			Priority:              1,
+			MaxChunks:             &maxChunks,
+			SimilarityThreshold:   &similarityThreshold,
+			FreshnessHalfLifeDays: freshnessHalfLives[kbID],
 		}
 
 		if err := s.LinkChatbotKnowledgeBase(ctx, link); err != nil {
@@ -2233,22 +2337,37 @@ func (s *KnowledgeBaseStorage) SyncChatbotKnowledgeBaseLinks(ctx context.Context
 // Knowledge Base Ownership and Permissions
 // ============================================================================
 
-// ListUserKnowledgeBases returns KBs accessible to user
+// granteeCondition matches permission rows (alias) granted to the user ($param) directly
+// or to one of the groups the user belongs to
+func granteeCondition(alias, param string) string {
+	return "(" + alias + ".user_id = " + param + " OR " + alias + ".group_id IN (" +
+		"SELECT gm.group_id FROM auth.group_members gm WHERE gm.user_id = " + param + "))"
+}
+
+// kbPermissionRank orders KB permission rows from the highest permission to the lowest
+const kbPermissionRank = "CASE kbp.permission WHEN 'owner' THEN 3 WHEN 'editor' THEN 2 ELSE 1 END DESC"
+
+// ListUserKnowledgeBases returns KBs accessible to user. The user permission is the
+// highest permission granted to the user directly or through a group.
 func (s *KnowledgeBaseStorage) ListUserKnowledgeBases(ctx context.Context, userID string) ([]KnowledgeBaseSummary, error) {
 	query := `
 		SELECT kb.id, kb.name, kb.namespace, kb.description, kb.enabled,
 			   kb.document_count, kb.total_chunks, kb.visibility,
 			   kb.updated_at,
 			   CASE
-				   WHEN kbp.permission IS NOT NULL THEN kbp.permission
+				   WHEN p.permission IS NOT NULL THEN p.permission
 				   WHEN kb.visibility = 'public' THEN 'viewer'
 				   ELSE NULL
 			   END as user_permission
 		FROM ai.knowledge_bases kb
-		LEFT JOIN ai.knowledge_base_permissions kbp
-			   ON kbp.knowledge_base_id = kb.id AND kbp.user_id = $1
+		LEFT JOIN LATERAL (
+			SELECT kbp.permission FROM ai.knowledge_base_permissions kbp
+			WHERE kbp.knowledge_base_id = kb.id AND ` + granteeCondition("kbp", "$1") + `
+			ORDER BY ` + kbPermissionRank + `
+			LIMIT 1
+		) p ON true
 		WHERE kb.enabled = true
-		  AND (kbp.user_id = $1 OR kb.visibility = 'public')
+		  AND (p.permission IS NOT NULL OR kb.visibility = 'public')
 		ORDER BY kb.name
 	`
 
@@ -2282,17 +2401,18 @@ func (s *KnowledgeBaseStorage) ListUserKnowledgeBases(ctx context.Context, userI
 	return kbs, nil
 }
 
-// CanUserAccessKB checks if user has access
+// CanUserAccessKB checks if user has access directly, through a group, or because the KB is public
 func (s *KnowledgeBaseStorage) CanUserAccessKB(ctx context.Context, kbID, userID string) bool {
 	var hasAccess bool
 	query := `
 		SELECT EXISTS (
 			SELECT 1 FROM ai.knowledge_bases kb
-			LEFT JOIN ai.knowledge_base_permissions kbp
-				   ON kbp.knowledge_base_id = kb.id AND kbp.user_id = $2
 			WHERE kb.id = $1
 			  AND kb.enabled = true
-			  AND (kbp.user_id = $2 OR kb.visibility = 'public')
+			  AND (kb.visibility = 'public' OR EXISTS (
+				  SELECT 1 FROM ai.knowledge_base_permissions kbp
+				  WHERE kbp.knowledge_base_id = kb.id AND ` + granteeCondition("kbp", "$2") + `
+			  ))
 		)
 	`
 	err := s.db.QueryRow(ctx, query, kbID, userID).Scan(&hasAccess)
@@ -2304,6 +2424,7 @@ func (s *KnowledgeBaseStorage) CanUserAccessKB(ctx context.Context, kbID, userID
 // - If required is "viewer": user needs any permission (viewer, editor, or owner)
 // - If required is "editor": user needs editor or owner permission
 // - If required is "owner": user must be the KB owner or have owner permission
+// Permissions granted to any of the user's groups count as granted to the user.
 func (s *KnowledgeBaseStorage) CheckKBPermission(ctx context.Context, kbID, userID, requiredPermission string) (bool, error) {
 	// Build the permission check based on required level
 	var permissionCheck string
@@ -2325,11 +2446,14 @@ func (s *KnowledgeBaseStorage) CheckKBPermission(ctx context.Context, kbID, user
 	query := `
 		SELECT EXISTS (
 			SELECT 1 FROM ai.knowledge_bases kb
-			LEFT JOIN ai.knowledge_base_permissions kbp
-				ON kbp.knowledge_base_id = kb.id AND kbp.user_id = $2
 			WHERE kb.id = $1
 			  AND kb.enabled = true
-			  AND (kb.owner_id = $2 OR ` + permissionCheck + `)
+			  AND (kb.owner_id = $2 OR EXISTS (
+				  SELECT 1 FROM ai.knowledge_base_permissions kbp
+				  WHERE kbp.knowledge_base_id = kb.id
+				    AND ` + granteeCondition("kbp", "$2") + `
+				    AND ` + permissionCheck + `
+			  ))
 		)
 	`
 
@@ -2344,18 +2468,23 @@ func (s *KnowledgeBaseStorage) CheckKBPermission(ctx context.Context, kbID, user
 // GetUserKBPermission gets the user's effective permission level on a KB.
 // Returns the permission level or empty string if no access.
 // For public KBs, returns 'viewer' if no explicit permission exists.
+// When the user has grants both directly and through groups, the highest one wins.
 func (s *KnowledgeBaseStorage) GetUserKBPermission(ctx context.Context, kbID, userID string) (string, error) {
 	query := `
 		SELECT
 			CASE
 				WHEN kb.owner_id = $2 THEN 'owner'
-				WHEN kbp.permission IS NOT NULL THEN kbp.permission
+				WHEN p.permission IS NOT NULL THEN p.permission
 				WHEN kb.visibility = 'public' THEN 'viewer'
 				ELSE ''
 			END as permission
 		FROM ai.knowledge_bases kb
-		LEFT JOIN ai.knowledge_base_permissions kbp
-			ON kbp.knowledge_base_id = kb.id AND kbp.user_id = $2
+		LEFT JOIN LATERAL (
+			SELECT kbp.permission FROM ai.knowledge_base_permissions kbp
+			WHERE kbp.knowledge_base_id = kb.id AND ` + granteeCondition("kbp", "$2") + `
+			ORDER BY ` + kbPermissionRank + `
+			LIMIT 1
+		) p ON true
 		WHERE kb.id = $1 AND kb.enabled = true
 	`
 
@@ -2372,30 +2501,49 @@ func (s *KnowledgeBaseStorage) GetUserKBPermission(ctx context.Context, kbID, us
 
 // GrantKBPermission grants permission to user
 func (s *KnowledgeBaseStorage) GrantKBPermission(ctx context.Context, kbID, userID, permission string, grantedBy *string) (*KBPermissionGrant, error) {
-	// Upsert permission
+	return s.grantKBPermission(ctx, kbID, "user_id", userID, permission, grantedBy)
+}
+
+// GrantKBGroupPermission grants permission to every member of a group
+func (s *KnowledgeBaseStorage) GrantKBGroupPermission(ctx context.Context, kbID, groupID, permission string, grantedBy *string) (*KBPermissionGrant, error) {
+	return s.grantKBPermission(ctx, kbID, "group_id", groupID, permission, grantedBy)
+}
+
+// grantKBPermission upserts a permission for the grantee in column (user_id or group_id)
+func (s *KnowledgeBaseStorage) grantKBPermission(ctx context.Context, kbID, column, granteeID, permission string, grantedBy *string) (*KBPermissionGrant, error) {
 	query := `
-		INSERT INTO ai.knowledge_base_permissions (knowledge_base_id, user_id, permission, granted_by)
+		INSERT INTO ai.knowledge_base_permissions (knowledge_base_id, ` + column + `, permission, granted_by)
 		VALUES ($1, $2, $3, $4)
-		ON CONFLICT (knowledge_base_id, user_id)
+		ON CONFLICT (knowledge_base_id, ` + column + `)
 		DO UPDATE SET permission = $3, granted_by = $4, granted_at = NOW()
-		RETURNING id, knowledge_base_id, user_id, permission, granted_by, granted_at
+		RETURNING id, knowledge_base_id, ` + kbPermissionGranteeColumns + `, permission, granted_by, granted_at
 	`
 
-	var grant KBPermissionGrant
-	err := s.db.QueryRow(ctx, query, kbID, userID, permission, grantedBy).Scan(
-		&grant.ID, &grant.KnowledgeBaseID, &grant.UserID, &grant.Permission, &grant.GrantedBy, &grant.GrantedAt,
-	)
+	grant, err := scanKBPermissionGrant(s.db.QueryRow(ctx, query, kbID, granteeID, permission, grantedBy))
 	if err != nil {
 		return nil, fmt.Errorf("failed to grant permission: %w", err)
 	}
 
+	return grant, nil
+}
+
+// kbPermissionGranteeColumns selects user_id and group_id of a grant, empty for the unset one
+const kbPermissionGranteeColumns = "COALESCE(user_id::text, ''), COALESCE(group_id::text, '')"
+
+func scanKBPermissionGrant(row pgx.Row) (*KBPermissionGrant, error) {
+	var grant KBPermissionGrant
+	if err := row.Scan(
+		&grant.ID, &grant.KnowledgeBaseID, &grant.UserID, &grant.GroupID, &grant.Permission, &grant.GrantedBy, &grant.GrantedAt,
+	); err != nil {
+		return nil, err
+	}
 	return &grant, nil
 }
 
-// ListKBPermissions lists all permissions for a KB
+// ListKBPermissions lists all permissions for a KB, both user and group grants
 func (s *KnowledgeBaseStorage) ListKBPermissions(ctx context.Context, kbID string) ([]KBPermissionGrant, error) {
 	query := `
-		SELECT id, knowledge_base_id, user_id, permission, granted_by, granted_at
+		SELECT id, knowledge_base_id, ` + kbPermissionGranteeColumns + `, permission, granted_by, granted_at
 		FROM ai.knowledge_base_permissions
 		WHERE knowledge_base_id = $1
 		ORDER BY granted_at DESC
@@ -2409,14 +2557,12 @@ func (s *KnowledgeBaseStorage) ListKBPermissions(ctx context.Context, kbID strin
 
 	var grants []KBPermissionGrant
 	for rows.Next() {
-		var grant KBPermissionGrant
-		if err := rows.Scan(
-			&grant.ID, &grant.KnowledgeBaseID, &grant.UserID, &grant.Permission, &grant.GrantedBy, &grant.GrantedAt,
-		); err != nil {
+		grant, err := scanKBPermissionGrant(rows)
+		if err != nil {
 			log.Warn().Err(err).Msg("Failed to scan permission row")
 			continue
 		}
-		grants = append(grants, grant)
+		grants = append(grants, *grant)
 	}
 
 	return grants, nil
@@ -2432,12 +2578,32 @@ func (s *KnowledgeBaseStorage) RevokeKBPermission(ctx context.Context, kbID, use
 	return nil
 }
 
+// RevokeKBGroupPermission revokes permission from a group
+func (s *KnowledgeBaseStorage) RevokeKBGroupPermission(ctx context.Context, kbID, groupID string) error {
+	query := `DELETE FROM ai.knowledge_base_permissions WHERE knowledge_base_id = $1 AND group_id = $2`
+	_, err := s.db.Exec(ctx, query, kbID, groupID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke permission: %w", err)
+	}
+	return nil
+}
+
 // ============================================================================
 // DOCUMENT PERMISSIONS
 // ============================================================================
 
 // GrantDocumentPermission grants permission on a document to a user
 func (s *KnowledgeBaseStorage) GrantDocumentPermission(ctx context.Context, documentID, userID, permission, grantedBy string) (*DocumentPermissionGrant, error) {
+	return s.grantDocumentPermission(ctx, documentID, "user_id", userID, permission, grantedBy)
+}
+
+// GrantDocumentGroupPermission grants permission on a document to every member of a group
+func (s *KnowledgeBaseStorage) GrantDocumentGroupPermission(ctx context.Context, documentID, groupID, permission, grantedBy string) (*DocumentPermissionGrant, error) {
+	return s.grantDocumentPermission(ctx, documentID, "group_id", groupID, permission, grantedBy)
+}
+
+// grantDocumentPermission upserts a permission for the grantee in column (user_id or group_id)
+func (s *KnowledgeBaseStorage) grantDocumentPermission(ctx context.Context, documentID, column, granteeID, permission, grantedBy string) (*DocumentPermissionGrant, error) {
 	// First check if the requester owns the document
 	var ownerID string
 	checkQuery := `SELECT owner_id FROM ai.documents WHERE id = $1`
@@ -2455,16 +2621,16 @@ func (s *KnowledgeBaseStorage) GrantDocumentPermission(ctx context.Context, docu
 
 	// Upsert permission
 	query := `
-		INSERT INTO ai.document_permissions (document_id, user_id, permission, granted_by)
+		INSERT INTO ai.document_permissions (document_id, ` + column + `, permission, granted_by)
 		VALUES ($1, $2, $3, $4)
-		ON CONFLICT (document_id, user_id)
+		ON CONFLICT (document_id, ` + column + `)
 		DO UPDATE SET permission = $3, granted_by = $4, granted_at = NOW()
-		RETURNING id, document_id, user_id, permission, granted_by, granted_at
+		RETURNING id, document_id, ` + kbPermissionGranteeColumns + `, permission, granted_by, granted_at
 	`
 
 	var grant DocumentPermissionGrant
-	err = s.db.QueryRow(ctx, query, documentID, userID, permission, grantedBy).Scan(
-		&grant.ID, &grant.DocumentID, &grant.UserID, &grant.Permission, &grant.GrantedBy, &grant.GrantedAt,
+	err = s.db.QueryRow(ctx, query, documentID, granteeID, permission, grantedBy).Scan(
+		&grant.ID, &grant.DocumentID, &grant.UserID, &grant.GroupID, &grant.Permission, &grant.GrantedBy, &grant.GrantedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to grant document permission: %w", err)
@@ -2473,10 +2639,10 @@ func (s *KnowledgeBaseStorage) GrantDocumentPermission(ctx context.Context, docu
 	return &grant, nil
 }
 
-// ListDocumentPermissions lists all permissions for a document
+// ListDocumentPermissions lists all permissions for a document, both user and group grants
 func (s *KnowledgeBaseStorage) ListDocumentPermissions(ctx context.Context, documentID string) ([]DocumentPermissionGrant, error) {
 	query := `
-		SELECT id, document_id, user_id, permission, granted_by, granted_at
+		SELECT id, document_id, ` + kbPermissionGranteeColumns + `, permission, granted_by, granted_at
 		FROM ai.document_permissions
 		WHERE document_id = $1
 		ORDER BY granted_at DESC
@@ -2492,7 +2658,7 @@ func (s *KnowledgeBaseStorage) ListDocumentPermissions(ctx context.Context, docu
 	for rows.Next() {
 		var grant DocumentPermissionGrant
 		if err := rows.Scan(
-			&grant.ID, &grant.DocumentID, &grant.UserID, &grant.Permission, &grant.GrantedBy, &grant.GrantedAt,
+			&grant.ID, &grant.DocumentID, &grant.UserID, &grant.GroupID, &grant.Permission, &grant.GrantedBy, &grant.GrantedAt,
 		); err != nil {
 			log.Warn().Err(err).Msg("Failed to scan document permission row")
 			continue
@@ -2513,6 +2679,16 @@ func (s *KnowledgeBaseStorage) RevokeDocumentPermission(ctx context.Context, doc
 	return nil
 }
 
+// RevokeDocumentGroupPermission revokes permission from a group on a document
+func (s *KnowledgeBaseStorage) RevokeDocumentGroupPermission(ctx context.Context, documentID, groupID string) error {
+	query := `DELETE FROM ai.document_permissions WHERE document_id = $1 AND group_id = $2`
+	_, err := s.db.Exec(ctx, query, documentID, groupID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke document permission: %w", err)
+	}
+	return nil
+}
+
 // CanUserAccessDocument checks if a user can access a document
 func (s *KnowledgeBaseStorage) CanUserAccessDocument(ctx context.Context, documentID, userID string) (bool, error) {
 	// Check if user owns the document
@@ -2531,12 +2707,12 @@ func (s *KnowledgeBaseStorage) CanUserAccessDocument(ctx context.Context, docume
 		return true, nil
 	}
 
-	// Check if user has been granted permission
+	// Check if user or one of their groups has been granted permission
 	var hasPermission bool
 	permQuery := `
 		SELECT EXISTS(
-			SELECT 1 FROM ai.document_permissions
-			WHERE document_id = $1 AND user_id = $2
+			SELECT 1 FROM ai.document_permissions dp
+			WHERE dp.document_id = $1 AND ` + granteeCondition("dp", "$2") + `
 		)
 	`
 	err = s.db.QueryRow(ctx, permQuery, documentID, userID).Scan(&hasPermission)
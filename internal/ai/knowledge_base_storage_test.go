@@ -104,67 +104,6 @@ func TestJoinStrings(t *testing.T) {
 	}
 }
 
-func TestSanitizeMetadataKey(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected string
-	}{
-		{
-			name:     "alphanumeric only",
-			input:    "user123",
-			expected: "user123",
-		},
-		{
-			name:     "with underscores",
-			input:    "user_id",
-			expected: "user_id",
-		},
-		{
-			name:     "mixed case",
-			input:    "UserID",
-			expected: "UserID",
-		},
-		{
-			name:     "removes special chars",
-			input:    "user-id!@#$%",
-			expected: "userid",
-		},
-		{
-			name:     "removes spaces",
-			input:    "user id",
-			expected: "userid",
-		},
-		{
-			name:     "removes dots",
-			input:    "user.id",
-			expected: "userid",
-		},
-		{
-			name:     "empty string",
-			input:    "",
-			expected: "",
-		},
-		{
-			name:     "only special chars",
-			input:    "!@#$%^&*()",
-			expected: "",
-		},
-		{
-			name:     "SQL injection attempt",
-			input:    "user'; DROP TABLE users; --",
-			expected: "userDROPTABLEusers",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := sanitizeMetadataKey(tt.input)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
 func TestSearchMode_Constants(t *testing.T) {
 	t.Run("all modes defined", func(t *testing.T) {
 		assert.Equal(t, SearchMode("semantic"), SearchModeSemantic)
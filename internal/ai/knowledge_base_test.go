@@ -499,3 +499,16 @@ func TestVectorSearchResult_Struct(t *testing.T) {
 		assert.Contains(t, string(data), `"similarity":0.95`)
 	})
 }
+
+func TestValidateGrantee(t *testing.T) {
+	assert.NoError(t, validateGrantee("user-1", ""))
+	assert.NoError(t, validateGrantee("", "group-1"))
+	assert.ErrorIs(t, validateGrantee("", ""), ErrInvalidGrantee)
+	assert.ErrorIs(t, validateGrantee("user-1", "group-1"), ErrInvalidGrantee)
+}
+
+func TestGranteeCondition(t *testing.T) {
+	assert.Equal(t,
+		"(kbp.user_id = $2 OR kbp.group_id IN (SELECT gm.group_id FROM auth.group_members gm WHERE gm.user_id = $2))",
+		granteeCondition("kbp", "$2"))
+}
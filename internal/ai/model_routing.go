@@ -0,0 +1,227 @@
+package ai
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Model routing
+//
+// A chatbot can answer simple messages with a cheap model and hard ones with a premium model.
+// Routes are evaluated in order and the first route whose conditions all match picks the model;
+// otherwise the chatbot's @fluxbase:model is used:
+//
+//	@fluxbase:model gpt-4o-mini
+//	@fluxbase:model-route gpt-4o when confidence < 0.6
+//	@fluxbase:model-route gpt-4o when length > 1000
+//	@fluxbase:model-route gpt-4o when intent refund,cancel and length > 200
+//	@fluxbase:model-cost gpt-4o 2.50/10.00
+//	@fluxbase:model-cost gpt-4o-mini 0.15/0.60
+//
+// Prices are USD per million prompt/completion tokens and are only used to record the cost
+// difference between the routed model and the default model.
+
+// RouteConditionKind is what a model route condition tests
+type RouteConditionKind string
+
+const (
+	// RouteConditionLength compares the user message length in characters
+	RouteConditionLength RouteConditionKind = "length"
+	// RouteConditionConfidence compares the best knowledge base similarity; it never matches
+	// when the chatbot did not retrieve context for the message
+	RouteConditionConfidence RouteConditionKind = "confidence"
+	// RouteConditionIntent matches when the user message contains any of the keywords
+	RouteConditionIntent RouteConditionKind = "intent"
+)
+
+// RouteCondition is a single test of a model route
+type RouteCondition struct {
+	Kind     RouteConditionKind `json:"kind"`
+	Operator string             `json:"operator,omitempty"` // "<" or ">" for length and confidence
+	Value    float64            `json:"value,omitempty"`
+	Keywords []string           `json:"keywords,omitempty"` // Lowercased keywords for intent
+}
+
+// ModelRoute sends a message to Model when all of its conditions match
+type ModelRoute struct {
+	Model      string           `json:"model"`
+	Conditions []RouteCondition `json:"conditions"`
+	Rule       string           `json:"rule"` // Condition text from the annotation, used in logs and usage records
+}
+
+// ModelPrice is the price of a model in USD per million tokens
+type ModelPrice struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// ModelRouteInput is what model routes are evaluated against
+type ModelRouteInput struct {
+	Message        string
+	BestSimilarity *float64 // nil when no knowledge base context was retrieved
+}
+
+// ModelSelection is the model chosen for a message
+type ModelSelection struct {
+	Model        string   // Empty if neither a route nor the chatbot set a model
+	Rule         string   // Rule of the matching route, empty when the default model is used
+	CostDeltaUSD *float64 // Cost of the answer minus its cost on the default model, nil if a price is unknown
+}
+
+var (
+	// @fluxbase:model-route gpt-4o when confidence < 0.6 and length > 200
+	modelRoutePattern = regexp.MustCompile(`@fluxbase:model-route\s+([^\s*]+)\s+when\s+([^\n*]+)`)
+
+	// @fluxbase:model-cost gpt-4o 2.50/10.00
+	modelCostPattern = regexp.MustCompile(`@fluxbase:model-cost\s+([^\s*]+)\s+([\d.]+)/([\d.]+)`)
+
+	routeConditionSeparator = regexp.MustCompile(`(?i)\s+and\s+`)
+	routeComparisonPattern  = regexp.MustCompile(`(?i)^(length|confidence)\s*([<>])\s*([\d.]+)$`)
+	routeIntentPattern      = regexp.MustCompile(`(?i)^intent\s+(.+)$`)
+)
+
+// parseModelRoutingConfig parses the model route and model cost annotations into config.
+// Routes with a condition that cannot be parsed are ignored.
+func parseModelRoutingConfig(code string, config *ChatbotConfig) {
+	config.ModelRoutes = nil
+	for _, matches := range modelRoutePattern.FindAllStringSubmatch(code, -1) {
+		if route, ok := parseModelRoute(matches[1], matches[2]); ok {
+			config.ModelRoutes = append(config.ModelRoutes, route)
+		}
+	}
+
+	config.ModelPrices = nil
+	for _, matches := range modelCostPattern.FindAllStringSubmatch(code, -1) {
+		input, inErr := strconv.ParseFloat(matches[2], 64)
+		output, outErr := strconv.ParseFloat(matches[3], 64)
+		if inErr != nil || outErr != nil {
+			continue
+		}
+		if config.ModelPrices == nil {
+			config.ModelPrices = make(map[string]ModelPrice)
+		}
+		config.ModelPrices[matches[1]] = ModelPrice{Input: input, Output: output}
+	}
+}
+
+// parseModelRoute parses the conditions of a single route
+func parseModelRoute(model, rule string) (ModelRoute, bool) {
+	rule = strings.TrimSpace(rule)
+	route := ModelRoute{Model: model, Rule: rule}
+
+	for _, part := range routeConditionSeparator.Split(rule, -1) {
+		part = strings.TrimSpace(part)
+
+		if m := routeComparisonPattern.FindStringSubmatch(part); m != nil {
+			value, err := strconv.ParseFloat(m[3], 64)
+			if err != nil {
+				return ModelRoute{}, false
+			}
+			route.Conditions = append(route.Conditions, RouteCondition{
+				Kind:     RouteConditionKind(strings.ToLower(m[1])),
+				Operator: m[2],
+				Value:    value,
+			})
+			continue
+		}
+
+		if m := routeIntentPattern.FindStringSubmatch(part); m != nil {
+			var keywords []string
+			for _, keyword := range parseCSV(m[1]) {
+				keywords = append(keywords, strings.ToLower(keyword))
+			}
+			if len(keywords) == 0 {
+				return ModelRoute{}, false
+			}
+			route.Conditions = append(route.Conditions, RouteCondition{Kind: RouteConditionIntent, Keywords: keywords})
+			continue
+		}
+
+		return ModelRoute{}, false
+	}
+
+	return route, len(route.Conditions) > 0
+}
+
+// matches reports whether the condition holds for the input
+func (c RouteCondition) matches(input ModelRouteInput) bool {
+	switch c.Kind {
+	case RouteConditionLength:
+		return compareRouteValue(float64(len([]rune(input.Message))), c.Operator, c.Value)
+	case RouteConditionConfidence:
+		if input.BestSimilarity == nil {
+			return false
+		}
+		return compareRouteValue(*input.BestSimilarity, c.Operator, c.Value)
+	case RouteConditionIntent:
+		message := strings.ToLower(input.Message)
+		for _, keyword := range c.Keywords {
+			if strings.Contains(message, keyword) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func compareRouteValue(actual float64, operator string, value float64) bool {
+	if operator == "<" {
+		return actual < value
+	}
+	return actual > value
+}
+
+// HasModelRouting returns true if the chatbot routes messages between models
+func (c *Chatbot) HasModelRouting() bool {
+	return len(c.ModelRoutes) > 0
+}
+
+// SelectModel returns the model for a message: the model of the first route whose conditions
+// all match, or the chatbot's default model. It returns nil if the chatbot has no routes.
+func (c *Chatbot) SelectModel(input ModelRouteInput) *ModelSelection {
+	if !c.HasModelRouting() {
+		return nil
+	}
+
+	for _, route := range c.ModelRoutes {
+		matched := true
+		for _, condition := range route.Conditions {
+			if !condition.matches(input) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return &ModelSelection{Model: route.Model, Rule: route.Rule}
+		}
+	}
+
+	return &ModelSelection{Model: c.Model}
+}
+
+// RecordUsage sets the selection's cost delta for the tokens used to answer. The delta is
+// zero for the default model and nil if the price of either model is unknown.
+func (s *ModelSelection) RecordUsage(chatbot *Chatbot, usage UsageStats) {
+	if s == nil {
+		return
+	}
+	if s.Model == chatbot.Model {
+		zero := 0.0
+		s.CostDeltaUSD = &zero
+		return
+	}
+
+	routed, ok := chatbot.ModelPrices[s.Model]
+	if !ok {
+		return
+	}
+	base, ok := chatbot.ModelPrices[chatbot.Model]
+	if !ok {
+		return
+	}
+
+	delta := (float64(usage.PromptTokens)*(routed.Input-base.Input) +
+		float64(usage.CompletionTokens)*(routed.Output-base.Output)) / 1_000_000
+	s.CostDeltaUSD = &delta
+}
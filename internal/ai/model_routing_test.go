@@ -0,0 +1,153 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const modelRoutingCode = "/**\n" +
+	" * Support bot\n" +
+	" *\n" +
+	" * @fluxbase:model gpt-4o-mini\n" +
+	" * @fluxbase:model-route gpt-4o when confidence < 0.6\n" +
+	" * @fluxbase:model-route gpt-4o when intent Refund, cancel and length > 40\n" +
+	" * @fluxbase:model-route o1 when length > 2000\n" +
+	" * @fluxbase:model-route broken when mood > 3\n" +
+	" * @fluxbase:model-cost gpt-4o 2.50/10.00\n" +
+	" * @fluxbase:model-cost gpt-4o-mini 0.15/0.60\n" +
+	" */\n" +
+	"export default `You are a support assistant.`;\n"
+
+func TestParseChatbotConfig_ModelRouting(t *testing.T) {
+	config := ParseChatbotConfig(modelRoutingCode)
+
+	require.Len(t, config.ModelRoutes, 3, "routes with unknown conditions are ignored")
+	assert.Equal(t, ModelRoute{
+		Model:      "gpt-4o",
+		Rule:       "confidence < 0.6",
+		Conditions: []RouteCondition{{Kind: RouteConditionConfidence, Operator: "<", Value: 0.6}},
+	}, config.ModelRoutes[0])
+	assert.Equal(t, []RouteCondition{
+		{Kind: RouteConditionIntent, Keywords: []string{"refund", "cancel"}},
+		{Kind: RouteConditionLength, Operator: ">", Value: 40},
+	}, config.ModelRoutes[1].Conditions)
+	assert.Equal(t, "o1", config.ModelRoutes[2].Model)
+
+	assert.Equal(t, map[string]ModelPrice{
+		"gpt-4o":      {Input: 2.50, Output: 10.00},
+		"gpt-4o-mini": {Input: 0.15, Output: 0.60},
+	}, config.ModelPrices)
+	assert.Equal(t, "gpt-4o-mini", config.Model, "model-route and model-cost do not match the model annotation")
+
+	t.Run("persisted chatbots re-parse model routing from code", func(t *testing.T) {
+		chatbot := &Chatbot{Code: modelRoutingCode}
+		chatbot.PopulateDerivedFields()
+
+		assert.True(t, chatbot.HasModelRouting())
+		assert.Len(t, chatbot.ModelRoutes, 3)
+		assert.Len(t, chatbot.ModelPrices, 2)
+	})
+
+	t.Run("no routing by default", func(t *testing.T) {
+		chatbot := &Chatbot{}
+		chatbot.ApplyConfig(DefaultChatbotConfig())
+
+		assert.False(t, chatbot.HasModelRouting())
+		assert.Nil(t, chatbot.SelectModel(ModelRouteInput{Message: "Hello"}))
+	})
+}
+
+func TestChatbot_SelectModel(t *testing.T) {
+	chatbot := &Chatbot{}
+	chatbot.ApplyConfig(ParseChatbotConfig(modelRoutingCode))
+
+	similarity := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name      string
+		input     ModelRouteInput
+		wantModel string
+		wantRule  string
+	}{
+		{
+			name:      "simple message uses the default model",
+			input:     ModelRouteInput{Message: "What are your opening hours?", BestSimilarity: similarity(0.82)},
+			wantModel: "gpt-4o-mini",
+		},
+		{
+			name:      "low confidence uses the premium model",
+			input:     ModelRouteInput{Message: "What are your opening hours?", BestSimilarity: similarity(0.41)},
+			wantModel: "gpt-4o",
+			wantRule:  "confidence < 0.6",
+		},
+		{
+			name:      "confidence never matches without retrieval",
+			input:     ModelRouteInput{Message: "Hi"},
+			wantModel: "gpt-4o-mini",
+		},
+		{
+			name:      "all conditions of a route must match",
+			input:     ModelRouteInput{Message: "I want a REFUND for my order from last week please"},
+			wantModel: "gpt-4o",
+			wantRule:  "intent Refund, cancel and length > 40",
+		},
+		{
+			name:      "intent without enough length does not match",
+			input:     ModelRouteInput{Message: "refund"},
+			wantModel: "gpt-4o-mini",
+		},
+		{
+			name:      "long message",
+			input:     ModelRouteInput{Message: strings.Repeat("a", 2001)},
+			wantModel: "o1",
+			wantRule:  "length > 2000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selection := chatbot.SelectModel(tt.input)
+			require.NotNil(t, selection)
+			assert.Equal(t, tt.wantModel, selection.Model)
+			assert.Equal(t, tt.wantRule, selection.Rule)
+		})
+	}
+}
+
+func TestModelSelection_RecordUsage(t *testing.T) {
+	chatbot := &Chatbot{}
+	chatbot.ApplyConfig(ParseChatbotConfig(modelRoutingCode))
+	usage := UsageStats{PromptTokens: 1_000_000, CompletionTokens: 100_000}
+
+	t.Run("routed model", func(t *testing.T) {
+		selection := &ModelSelection{Model: "gpt-4o", Rule: "confidence < 0.6"}
+		selection.RecordUsage(chatbot, usage)
+
+		require.NotNil(t, selection.CostDeltaUSD)
+		// (2.50 - 0.15) for prompt tokens + (10.00 - 0.60) / 10 for completion tokens
+		assert.InDelta(t, 3.29, *selection.CostDeltaUSD, 0.000001)
+	})
+
+	t.Run("default model", func(t *testing.T) {
+		selection := &ModelSelection{Model: "gpt-4o-mini"}
+		selection.RecordUsage(chatbot, usage)
+
+		require.NotNil(t, selection.CostDeltaUSD)
+		assert.Equal(t, 0.0, *selection.CostDeltaUSD)
+	})
+
+	t.Run("unknown price", func(t *testing.T) {
+		selection := &ModelSelection{Model: "o1", Rule: "length > 2000"}
+		selection.RecordUsage(chatbot, usage)
+
+		assert.Nil(t, selection.CostDeltaUSD)
+	})
+
+	t.Run("nil selection", func(t *testing.T) {
+		var selection *ModelSelection
+		assert.NotPanics(t, func() { selection.RecordUsage(chatbot, usage) })
+	})
+}
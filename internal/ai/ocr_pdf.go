@@ -0,0 +1,81 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// renderPDFPages converts each page of a PDF to a PNG image using pdftoppm. The caller
+// removes the returned temp directory.
+func renderPDFPages(ctx context.Context, pdftoppmPath string, pdfData []byte) ([]string, string, error) {
+	// Create temp directory for images
+	tmpDir, err := os.MkdirTemp("", "ocr-pdf-*")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create temp dir: %w", err)
+	}
+
+	// Write PDF to temp file
+	pdfPath := filepath.Join(tmpDir, "input.pdf")
+	if err := os.WriteFile(pdfPath, pdfData, 0o600); err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return nil, "", fmt.Errorf("failed to write PDF temp file: %w", err)
+	}
+
+	// Convert PDF to PNG images using pdftoppm
+	// -png: output PNG format
+	// -r 300: 300 DPI for good OCR quality
+	outputPrefix := filepath.Join(tmpDir, "page")
+	cmd := exec.CommandContext(ctx, pdftoppmPath, "-png", "-r", "300", pdfPath, outputPrefix)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return nil, "", fmt.Errorf("pdftoppm failed: %w, output: %s", err, string(output))
+	}
+
+	// Find generated image files
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return nil, "", fmt.Errorf("failed to read temp dir: %w", err)
+	}
+
+	var images []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".png") {
+			images = append(images, filepath.Join(tmpDir, entry.Name()))
+		}
+	}
+
+	// Sort images by name to maintain page order
+	sort.Strings(images)
+
+	return images, tmpDir, nil
+}
+
+// estimateOCRConfidence estimates OCR confidence based on text characteristics
+func estimateOCRConfidence(text string) float64 {
+	if len(text) == 0 {
+		return 0
+	}
+
+	printable := 0
+	total := 0
+	for _, r := range text {
+		total++
+		if unicode.IsPrint(r) || unicode.IsSpace(r) {
+			printable++
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	return float64(printable) / float64(total)
+}
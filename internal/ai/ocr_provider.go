@@ -8,7 +8,8 @@ import (
 type OCRProviderType string
 
 const (
-	OCRProviderTypeTesseract OCRProviderType = "tesseract"
+	OCRProviderTypeTesseract    OCRProviderType = "tesseract"     // Tesseract library, requires a cgo build with the ocr tag
+	OCRProviderTypeTesseractCLI OCRProviderType = "tesseract_cli" // tesseract binary from PATH
 )
 
 // OCRResult represents the result of OCR processing
@@ -48,10 +49,17 @@ type OCRProviderConfig struct {
 
 // NewOCRProvider creates an OCR provider based on configuration
 func NewOCRProvider(cfg OCRProviderConfig) (OCRProvider, error) {
-	switch cfg.Type {
-	case OCRProviderTypeTesseract:
-		return NewTesseractProvider(cfg)
-	default:
-		return NewTesseractProvider(cfg) // Default to Tesseract
+	if cfg.Type == OCRProviderTypeTesseractCLI {
+		return NewTesseractCLIProvider(cfg)
 	}
+
+	// Default to the Tesseract library, falling back to the binary in builds without it
+	provider, err := NewTesseractProvider(cfg)
+	if err != nil || provider.IsAvailable() {
+		return provider, err
+	}
+	if cli, err := NewTesseractCLIProvider(cfg); err == nil && cli.IsAvailable() {
+		return cli, nil
+	}
+	return provider, nil
 }
@@ -7,10 +7,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
-	"sort"
 	"strings"
-	"unicode"
 
 	"github.com/otiai10/gosseract/v2"
 	"github.com/rs/zerolog/log"
@@ -85,7 +82,7 @@ func (p *TesseractProvider) ExtractTextFromPDF(ctx context.Context, pdfData []by
 	}
 
 	// Convert PDF to images
-	images, tmpDir, err := p.pdfToImages(ctx, pdfData)
+	images, tmpDir, err := renderPDFPages(ctx, p.pdftoppmPath, pdfData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert PDF to images: %w", err)
 	}
@@ -165,52 +162,6 @@ func (p *TesseractProvider) Close() error {
 	return nil
 }
 
-// pdfToImages converts PDF to PNG images using pdftoppm
-func (p *TesseractProvider) pdfToImages(ctx context.Context, pdfData []byte) ([]string, string, error) {
-	// Create temp directory for images
-	tmpDir, err := os.MkdirTemp("", "ocr-pdf-*")
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to create temp dir: %w", err)
-	}
-
-	// Write PDF to temp file
-	pdfPath := filepath.Join(tmpDir, "input.pdf")
-	if err := os.WriteFile(pdfPath, pdfData, 0o600); err != nil {
-		os.RemoveAll(tmpDir)
-		return nil, "", fmt.Errorf("failed to write PDF temp file: %w", err)
-	}
-
-	// Convert PDF to PNG images using pdftoppm
-	// -png: output PNG format
-	// -r 300: 300 DPI for good OCR quality
-	outputPrefix := filepath.Join(tmpDir, "page")
-	cmd := exec.CommandContext(ctx, p.pdftoppmPath, "-png", "-r", "300", pdfPath, outputPrefix)
-
-	if output, err := cmd.CombinedOutput(); err != nil {
-		os.RemoveAll(tmpDir)
-		return nil, "", fmt.Errorf("pdftoppm failed: %w, output: %s", err, string(output))
-	}
-
-	// Find generated image files
-	entries, err := os.ReadDir(tmpDir)
-	if err != nil {
-		os.RemoveAll(tmpDir)
-		return nil, "", fmt.Errorf("failed to read temp dir: %w", err)
-	}
-
-	var images []string
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".png") {
-			images = append(images, filepath.Join(tmpDir, entry.Name()))
-		}
-	}
-
-	// Sort images by name to maintain page order
-	sort.Strings(images)
-
-	return images, tmpDir, nil
-}
-
 // ocrImage runs Tesseract on a single image file
 func (p *TesseractProvider) ocrImage(imagePath string, languages []string) (string, float64, error) {
 	client := gosseract.NewClient()
@@ -234,29 +185,7 @@ func (p *TesseractProvider) ocrImage(imagePath string, languages []string) (stri
 	}
 
 	// Estimate confidence based on text quality
-	confidence := p.estimateConfidence(text)
+	confidence := estimateOCRConfidence(text)
 
 	return strings.TrimSpace(text), confidence, nil
 }
-
-// estimateConfidence estimates OCR confidence based on text characteristics
-func (p *TesseractProvider) estimateConfidence(text string) float64 {
-	if len(text) == 0 {
-		return 0
-	}
-
-	printable := 0
-	total := 0
-	for _, r := range text {
-		total++
-		if unicode.IsPrint(r) || unicode.IsSpace(r) {
-			printable++
-		}
-	}
-
-	if total == 0 {
-		return 0
-	}
-
-	return float64(printable) / float64(total)
-}
@@ -0,0 +1,164 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// TesseractCLIProvider implements OCR by running the tesseract binary. Unlike
+// TesseractProvider it needs no cgo, so it works in the default build.
+type TesseractCLIProvider struct {
+	defaultLanguages []string
+	tesseractPath    string
+	pdftoppmPath     string
+}
+
+// NewTesseractCLIProvider creates a new provider that runs the tesseract binary from PATH
+func NewTesseractCLIProvider(cfg OCRProviderConfig) (*TesseractCLIProvider, error) {
+	languages := cfg.Languages
+	if len(languages) == 0 {
+		languages = []string{"eng"}
+	}
+
+	tesseractPath, _ := exec.LookPath("tesseract")
+	pdftoppmPath, _ := exec.LookPath("pdftoppm")
+
+	if tesseractPath == "" {
+		log.Warn().Msg("Tesseract binary not found in PATH, OCR will be unavailable")
+	} else {
+		log.Debug().
+			Str("tesseract_path", tesseractPath).
+			Str("pdftoppm_path", pdftoppmPath).
+			Strs("languages", languages).
+			Msg("Tesseract CLI provider initialized")
+	}
+
+	return &TesseractCLIProvider{
+		defaultLanguages: languages,
+		tesseractPath:    tesseractPath,
+		pdftoppmPath:     pdftoppmPath,
+	}, nil
+}
+
+func (p *TesseractCLIProvider) Name() string {
+	return "tesseract-cli"
+}
+
+func (p *TesseractCLIProvider) Type() OCRProviderType {
+	return OCRProviderTypeTesseractCLI
+}
+
+func (p *TesseractCLIProvider) IsAvailable() bool {
+	return p.tesseractPath != ""
+}
+
+func (p *TesseractCLIProvider) ExtractTextFromPDF(ctx context.Context, pdfData []byte, languages []string) (*OCRResult, error) {
+	if !p.IsAvailable() {
+		return nil, fmt.Errorf("tesseract is not available")
+	}
+	if p.pdftoppmPath == "" {
+		return nil, fmt.Errorf("pdftoppm (poppler-utils) is required for PDF OCR but not found")
+	}
+	if len(languages) == 0 {
+		languages = p.defaultLanguages
+	}
+
+	images, tmpDir, err := renderPDFPages(ctx, p.pdftoppmPath, pdfData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert PDF to images: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no pages extracted from PDF")
+	}
+
+	var allText strings.Builder
+	var totalConfidence float64
+	for i, imgPath := range images {
+		text, err := p.ocrImage(ctx, imgPath, languages)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			log.Warn().Err(err).Int("page", i+1).Msg("OCR failed for page, continuing with others")
+			continue
+		}
+
+		if text != "" {
+			allText.WriteString(text)
+			allText.WriteString("\n\n")
+			totalConfidence += estimateOCRConfidence(text)
+		}
+	}
+
+	return &OCRResult{
+		Text:       strings.TrimSpace(allText.String()),
+		Confidence: totalConfidence / float64(len(images)),
+		Pages:      len(images),
+		Language:   strings.Join(languages, "+"),
+	}, nil
+}
+
+func (p *TesseractCLIProvider) ExtractTextFromImage(ctx context.Context, imageData []byte, languages []string) (*OCRResult, error) {
+	if !p.IsAvailable() {
+		return nil, fmt.Errorf("tesseract is not available")
+	}
+	if len(languages) == 0 {
+		languages = p.defaultLanguages
+	}
+
+	tmpFile, err := os.CreateTemp("", "ocr-image-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+	if _, err := tmpFile.Write(imageData); err != nil {
+		_ = tmpFile.Close()
+		return nil, fmt.Errorf("failed to write temp file: %w", err)
+	}
+	_ = tmpFile.Close()
+
+	text, err := p.ocrImage(ctx, tmpFile.Name(), languages)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OCRResult{
+		Text:       text,
+		Confidence: estimateOCRConfidence(text),
+		Pages:      1,
+		Language:   strings.Join(languages, "+"),
+	}, nil
+}
+
+func (p *TesseractCLIProvider) Close() error {
+	return nil
+}
+
+// ocrImage runs tesseract on a single image file and returns the recognized text
+func (p *TesseractCLIProvider) ocrImage(ctx context.Context, imagePath string, languages []string) (string, error) {
+	// Languages become command arguments, so only accept plain language codes
+	for _, lang := range languages {
+		if !ocrLanguagePattern.MatchString(lang) {
+			return "", fmt.Errorf("invalid OCR language: %q", lang)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.tesseractPath, imagePath, "stdout", "-l", strings.Join(languages, "+"))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("tesseract failed: %w, output: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,55 @@
+package ai
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTesseract writes a script that prints its arguments in place of the tesseract binary
+func fakeTesseract(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tesseract")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\necho \"recognized $4\"\n"), 0o755))
+	return path
+}
+
+func TestTesseractCLIProvider(t *testing.T) {
+	t.Run("unavailable without the binary", func(t *testing.T) {
+		provider := &TesseractCLIProvider{}
+		assert.False(t, provider.IsAvailable())
+		_, err := provider.ExtractTextFromImage(context.Background(), []byte("png"), nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("runs tesseract on an image", func(t *testing.T) {
+		provider := &TesseractCLIProvider{tesseractPath: fakeTesseract(t), defaultLanguages: []string{"eng"}}
+		require.True(t, provider.IsAvailable())
+
+		result, err := provider.ExtractTextFromImage(context.Background(), []byte("png"), []string{"eng", "deu"})
+		require.NoError(t, err)
+		assert.Equal(t, "recognized eng+deu", result.Text)
+		assert.Equal(t, "eng+deu", result.Language)
+		assert.Equal(t, 1, result.Pages)
+
+		result, err = provider.ExtractTextFromImage(context.Background(), []byte("png"), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "recognized eng", result.Text)
+	})
+
+	t.Run("rejects malformed languages", func(t *testing.T) {
+		provider := &TesseractCLIProvider{tesseractPath: fakeTesseract(t)}
+		_, err := provider.ExtractTextFromImage(context.Background(), []byte("png"), []string{"--user-words=/etc/passwd"})
+		assert.ErrorContains(t, err, "invalid OCR language")
+	})
+
+	t.Run("requires pdftoppm for PDFs", func(t *testing.T) {
+		provider := &TesseractCLIProvider{tesseractPath: fakeTesseract(t)}
+		_, err := provider.ExtractTextFromPDF(context.Background(), []byte("%PDF"), nil)
+		assert.ErrorContains(t, err, "pdftoppm")
+	})
+}
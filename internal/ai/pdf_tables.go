@@ -0,0 +1,149 @@
+package ai
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// The PDF reader reports where text runs start but not how wide they are, so run widths are
+// estimated from an average glyph width at body text sizes.
+const (
+	pdfCharWidth       = 5.0  // Average glyph width in points
+	pdfCellGap         = 12.0 // Minimum gap in points between two table cells on a row
+	pdfColumnTolerance = 4.0  // Maximum offset in points of a cell from its column
+	minPDFTableRows    = 2    // A header and at least one row
+)
+
+// pdfCell is a run of text on a PDF row
+type pdfCell struct {
+	x    float64
+	text string
+}
+
+// pdfRowsToText lays out the text rows of a PDF page, serializing runs of rows whose cells
+// line up in columns as markdown tables so chunking and retrieval keep their structure
+func pdfRowsToText(rows pdf.Rows) string {
+	lines := make([][]pdfCell, 0, len(rows))
+	for _, row := range rows {
+		if cells := pdfRowCells(row.Content); len(cells) > 0 {
+			lines = append(lines, cells)
+		}
+	}
+
+	var text strings.Builder
+	for i := 0; i < len(lines); {
+		if end := pdfTableEnd(lines, i); end-i >= minPDFTableRows {
+			if text.Len() > 0 {
+				text.WriteString("\n")
+			}
+			writeMarkdownTable(&text, lines[i:end])
+			text.WriteString("\n")
+			i = end
+			continue
+		}
+
+		cells := make([]string, len(lines[i]))
+		for j, cell := range lines[i] {
+			cells[j] = cell.text
+		}
+		text.WriteString(strings.Join(cells, " "))
+		text.WriteString("\n")
+		i++
+	}
+
+	return strings.TrimSpace(text.String())
+}
+
+// pdfRowCells merges the text runs of a row into cells, starting a new cell after a gap
+func pdfRowCells(runs pdf.TextHorizontal) []pdfCell {
+	var cells []pdfCell
+	end := 0.0
+	spaced := false // The previous run ended with a space
+	for _, run := range runs {
+		text := strings.TrimSpace(run.S)
+		if text == "" {
+			continue
+		}
+
+		gap := run.X - end
+		switch {
+		case len(cells) == 0 || gap > pdfCellGap:
+			cells = append(cells, pdfCell{x: run.X, text: text})
+		case spaced || gap > pdfCharWidth/2 || strings.HasPrefix(run.S, " "):
+			cells[len(cells)-1].text += " " + text
+		default:
+			cells[len(cells)-1].text += text
+		}
+		end = run.X + float64(utf8.RuneCountInString(run.S))*pdfCharWidth
+		spaced = strings.HasSuffix(run.S, " ")
+	}
+	return cells
+}
+
+// pdfTableEnd returns the end of the table starting at lines[start]. Its first row sets the
+// columns, and it continues while rows have two or more cells that all start at a column.
+func pdfTableEnd(lines [][]pdfCell, start int) int {
+	if len(lines[start]) < 2 {
+		return start
+	}
+	columns := lines[start]
+
+	end := start + 1
+	for end < len(lines) && len(lines[end]) >= 2 && pdfColumnIndexes(lines[end], columns) != nil {
+		end++
+	}
+	return end
+}
+
+// pdfColumnIndexes returns the column of each cell, or nil if a cell is not in a column
+func pdfColumnIndexes(cells, columns []pdfCell) []int {
+	indexes := make([]int, len(cells))
+	next := 0
+	for i, cell := range cells {
+		found := false
+		for ; next < len(columns); next++ {
+			if d := cell.x - columns[next].x; d >= -pdfColumnTolerance && d <= pdfColumnTolerance {
+				indexes[i] = next
+				next++
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+	return indexes
+}
+
+// writeMarkdownTable writes rows as a markdown table with the first row as the header
+func writeMarkdownTable(b *strings.Builder, rows [][]pdfCell) {
+	columns := rows[0]
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for _, cell := range cells {
+			b.WriteString(" ")
+			b.WriteString(strings.ReplaceAll(cell, "|", `\|`))
+			b.WriteString(" |")
+		}
+		b.WriteString("\n")
+	}
+
+	for i, row := range rows {
+		cells := make([]string, len(columns))
+		for j, column := range pdfColumnIndexes(row, columns) {
+			cells[column] = row[j].text
+		}
+		writeRow(cells)
+
+		if i == 0 {
+			separator := make([]string, len(columns))
+			for j := range separator {
+				separator[j] = "---"
+			}
+			writeRow(separator)
+		}
+	}
+}
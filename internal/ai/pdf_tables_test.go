@@ -0,0 +1,53 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/ledongthuc/pdf"
+	"github.com/stretchr/testify/assert"
+)
+
+// pdfRow builds a row of text runs at the given x positions
+func pdfRow(y int64, runs ...any) *pdf.Row {
+	row := &pdf.Row{Position: y}
+	for i := 0; i < len(runs); i += 2 {
+		row.Content = append(row.Content, pdf.Text{X: runs[i].(float64), S: runs[i+1].(string)})
+	}
+	return row
+}
+
+func TestPDFRowsToText(t *testing.T) {
+	t.Run("serializes aligned rows as a markdown table", func(t *testing.T) {
+		rows := pdf.Rows{
+			pdfRow(700, 72.0, "Quarterly results"),
+			pdfRow(680, 72.0, "Region", 200.0, "Revenue", 300.0, "Growth"),
+			pdfRow(665, 72.0, "EMEA", 200.0, "1.2M", 300.0, "4%"),
+			pdfRow(650, 72.0, "APAC|JP", 300.0, "7%"),
+			pdfRow(620, 72.0, "Figures are unaudited."),
+		}
+
+		want := "Quarterly results\n\n" +
+			"| Region | Revenue | Growth |\n" +
+			"| --- | --- | --- |\n" +
+			"| EMEA | 1.2M | 4% |\n" +
+			"| APAC\\|JP |  | 7% |\n\n" +
+			"Figures are unaudited."
+		assert.Equal(t, want, pdfRowsToText(rows))
+	})
+
+	t.Run("keeps prose split into runs on one line", func(t *testing.T) {
+		rows := pdf.Rows{
+			pdfRow(700, 72.0, "The quick ", 122.0, "brown fox"),
+			pdfRow(685, 72.0, "jumps over", 127.0, "the lazy dog"),
+		}
+		assert.Equal(t, "The quick brown fox\njumps over the lazy dog", pdfRowsToText(rows))
+	})
+
+	t.Run("does not treat a single multi-cell row as a table", func(t *testing.T) {
+		rows := pdf.Rows{
+			pdfRow(700, 72.0, "Name:", 200.0, "Jane"),
+			pdfRow(685, 72.0, "Notes follow below."),
+		}
+		assert.Equal(t, "Name: Jane\nNotes follow below.", pdfRowsToText(rows))
+	})
+}
@@ -27,12 +27,13 @@ const (
 
 // Message represents a message in a conversation
 type Message struct {
-	Role         Role          `json:"role"`
-	Content      string        `json:"content"`
-	ToolCalls    []ToolCall    `json:"tool_calls,omitempty"`
-	ToolCallID   string        `json:"tool_call_id,omitempty"`
-	Name         string        `json:"name,omitempty"`
-	QueryResults []QueryResult `json:"query_results,omitempty"` // SQL query results for assistant messages
+	Role         Role            `json:"role"`
+	Content      string          `json:"content"`
+	ToolCalls    []ToolCall      `json:"tool_calls,omitempty"`
+	ToolCallID   string          `json:"tool_call_id,omitempty"`
+	Name         string          `json:"name,omitempty"`
+	QueryResults []QueryResult   `json:"query_results,omitempty"` // SQL query results for assistant messages
+	Model        *ModelSelection `json:"-"`                       // Routed model for assistant messages, not sent to providers
 }
 
 // QueryResult represents the result of a SQL query execution
@@ -190,16 +191,22 @@ type OllamaConfig struct {
 
 // NewProvider creates a new AI provider based on the configuration
 func NewProvider(config ProviderConfig) (Provider, error) {
+	var provider Provider
+	var err error
 	switch config.Type {
 	case ProviderTypeOpenAI:
-		return NewOpenAIProvider(config)
+		provider, err = NewOpenAIProvider(config)
 	case ProviderTypeAzure:
-		return NewAzureProvider(config)
+		provider, err = NewAzureProvider(config)
 	case ProviderTypeOllama:
-		return NewOllamaProvider(config)
+		provider, err = NewOllamaProvider(config)
 	default:
 		return nil, fmt.Errorf("unsupported provider type: %s", config.Type)
 	}
+	if err != nil {
+		return nil, err
+	}
+	return tracedProvider{Provider: provider}, nil
 }
 
 // NewOpenAIProvider creates a new OpenAI provider (implemented in provider_openai.go)
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/nimbleflux/fluxbase/internal/egress"
 	"github.com/rs/zerolog/log"
 )
 
@@ -31,11 +32,9 @@ func newAzureProviderInternal(name string, config AzureConfig) (*azureProvider,
 	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
 
 	return &azureProvider{
-		name:   name,
-		config: config,
-		httpClient: &http.Client{
-			Timeout: azureTimeout,
-		},
+		name:       name,
+		config:     config,
+		httpClient: egress.NewHTTPClient(egress.SourceAI, azureTimeout),
 	}, nil
 }
 
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/nimbleflux/fluxbase/internal/egress"
 	"github.com/rs/zerolog/log"
 )
 
@@ -31,11 +32,9 @@ func newOllamaProviderInternal(name string, config OllamaConfig) (*ollamaProvide
 	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
 
 	return &ollamaProvider{
-		name:   name,
-		config: config,
-		httpClient: &http.Client{
-			Timeout: ollamaTimeout,
-		},
+		name:       name,
+		config:     config,
+		httpClient: egress.NewHTTPClient(egress.SourceAI, ollamaTimeout),
 	}, nil
 }
 
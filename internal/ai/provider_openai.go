@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/nimbleflux/fluxbase/internal/egress"
 	"github.com/rs/zerolog/log"
 )
 
@@ -36,11 +37,9 @@ func newOpenAIProviderInternal(name string, config OpenAIConfig) (*openAIProvide
 	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
 
 	return &openAIProvider{
-		name:   name,
-		config: config,
-		httpClient: &http.Client{
-			Timeout: openAITimeout,
-		},
+		name:       name,
+		config:     config,
+		httpClient: egress.NewHTTPClient(egress.SourceAI, openAITimeout),
 	}, nil
 }
 
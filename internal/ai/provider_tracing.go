@@ -0,0 +1,62 @@
+package ai
+
+import (
+	"context"
+
+	"github.com/nimbleflux/fluxbase/internal/observability"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedProvider creates a span for every chat completion of the provider it wraps, so a
+// request's trace shows how long the LLM took and how many tokens it used
+type tracedProvider struct {
+	Provider
+}
+
+// Chat sends a non-streaming chat request in a span
+func (p tracedProvider) Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
+	ctx, span := p.startSpan(ctx, "chat", req)
+	resp, err := p.Provider.Chat(ctx, req)
+	if err == nil && resp != nil && resp.Usage != nil {
+		setUsageAttributes(span, resp.Usage)
+	}
+	observability.EndAISpan(span, err)
+	return resp, err
+}
+
+// ChatStream sends a streaming chat request in a span, marking when the first content arrived
+func (p tracedProvider) ChatStream(ctx context.Context, req *ChatRequest, callback StreamCallback) error {
+	ctx, span := p.startSpan(ctx, "chat_stream", req)
+	firstContent := true
+	err := p.Provider.ChatStream(ctx, req, func(event StreamEvent) error {
+		switch {
+		case event.Type == "content" && firstContent:
+			firstContent = false
+			span.AddEvent("first_content")
+		case event.Type == "done" && event.Usage != nil:
+			setUsageAttributes(span, event.Usage)
+		}
+		return callback(event)
+	})
+	observability.EndAISpan(span, err)
+	return err
+}
+
+// startSpan starts the span of a chat completion
+func (p tracedProvider) startSpan(ctx context.Context, operation string, req *ChatRequest) (context.Context, trace.Span) {
+	return observability.StartAISpan(ctx, operation,
+		attribute.String("ai.provider", string(p.Type())),
+		attribute.String("ai.provider_name", p.Name()),
+		attribute.String("ai.model", req.Model),
+		attribute.Int("ai.messages", len(req.Messages)),
+	)
+}
+
+// setUsageAttributes records the tokens a chat completion used on its span
+func setUsageAttributes(span trace.Span, usage *UsageStats) {
+	span.SetAttributes(
+		attribute.Int("ai.prompt_tokens", usage.PromptTokens),
+		attribute.Int("ai.completion_tokens", usage.CompletionTokens),
+	)
+}
@@ -0,0 +1,52 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// streamingProvider streams a fixed answer
+type streamingProvider struct {
+	Provider
+}
+
+func (streamingProvider) Name() string       { return "local" }
+func (streamingProvider) Type() ProviderType { return ProviderTypeOllama }
+
+func (streamingProvider) ChatStream(_ context.Context, _ *ChatRequest, callback StreamCallback) error {
+	if err := callback(StreamEvent{Type: "content", Delta: "Hello"}); err != nil {
+		return err
+	}
+	return callback(StreamEvent{Type: "done", Usage: &UsageStats{PromptTokens: 12, CompletionTokens: 3}})
+}
+
+func TestTracedProvider_ChatStream(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	var events []string
+	provider := tracedProvider{Provider: streamingProvider{}}
+	err := provider.ChatStream(context.Background(), &ChatRequest{Model: "llama3"}, func(event StreamEvent) error {
+		events = append(events, event.Type)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"content", "done"}, events)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "ai.chat_stream", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), attribute.String("ai.model", "llama3"))
+	assert.Contains(t, spans[0].Attributes(), attribute.Int("ai.prompt_tokens", 12))
+	require.Len(t, spans[0].Events(), 1)
+	assert.Equal(t, "first_content", spans[0].Events()[0].Name)
+}
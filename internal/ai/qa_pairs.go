@@ -0,0 +1,176 @@
+package ai
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DocumentSourceTypeQAPairs marks a document whose content is a list of question/answer pairs.
+// Each pair becomes one chunk: the question is embedded and the answer is the returned content.
+const DocumentSourceTypeQAPairs = "qa_pairs"
+
+// MaxQAPairsPerDocument caps the pairs in a single Q&A document
+const MaxQAPairsPerDocument = 10000
+
+// ErrNoQAPairs is returned when Q&A content contains no usable pairs
+var ErrNoQAPairs = errors.New("no question/answer pairs found")
+
+// QAPair is a single question and its answer
+type QAPair struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// ParseQAPairs parses question/answer pairs from a JSON array, JSON Lines, or CSV (mimeType
+// text/csv) with a header row naming question and answer columns. Pairs missing either side are
+// rejected so a malformed import fails instead of indexing partial entries.
+func ParseQAPairs(content, mimeType string) ([]QAPair, error) {
+	var (
+		pairs []QAPair
+		err   error
+	)
+
+	trimmed := strings.TrimSpace(content)
+	switch {
+	case mimeType == "text/csv":
+		pairs, err = parseQAPairsCSV(trimmed)
+	case strings.HasPrefix(trimmed, "["):
+		err = json.Unmarshal([]byte(trimmed), &pairs)
+		if err != nil {
+			err = fmt.Errorf("invalid JSON: %w", err)
+		}
+	default:
+		pairs, err = parseQAPairsJSONLines(trimmed)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pairs) == 0 {
+		return nil, ErrNoQAPairs
+	}
+	if len(pairs) > MaxQAPairsPerDocument {
+		return nil, fmt.Errorf("too many question/answer pairs: %d (max %d)", len(pairs), MaxQAPairsPerDocument)
+	}
+	for i := range pairs {
+		pairs[i].Question = strings.TrimSpace(pairs[i].Question)
+		pairs[i].Answer = strings.TrimSpace(pairs[i].Answer)
+		if pairs[i].Question == "" || pairs[i].Answer == "" {
+			return nil, fmt.Errorf("pair %d: question and answer are required", i+1)
+		}
+	}
+	return pairs, nil
+}
+
+// NormalizeQAPairs parses Q&A content and re-encodes it as the JSON array stored as the
+// document content, returning the number of pairs
+func NormalizeQAPairs(content, mimeType string) (string, int, error) {
+	pairs, err := ParseQAPairs(content, mimeType)
+	if err != nil {
+		return "", 0, err
+	}
+	data, err := json.Marshal(pairs)
+	if err != nil {
+		return "", 0, err
+	}
+	return string(data), len(pairs), nil
+}
+
+func parseQAPairsJSONLines(content string) ([]QAPair, error) {
+	var pairs []QAPair
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var pair QAPair
+		if err := json.Unmarshal([]byte(text), &pair); err != nil {
+			return nil, fmt.Errorf("invalid JSON on line %d: %w", line, err)
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, scanner.Err()
+}
+
+func parseQAPairsCSV(content string) ([]QAPair, error) {
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, ErrNoQAPairs
+		}
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	questionCol, answerCol := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "question":
+			questionCol = i
+		case "answer":
+			answerCol = i
+		}
+	}
+	if questionCol < 0 || answerCol < 0 {
+		return nil, fmt.Errorf("CSV header must contain question and answer columns")
+	}
+
+	var pairs []QAPair
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		pair := QAPair{}
+		if questionCol < len(record) {
+			pair.Question = record[questionCol]
+		}
+		if answerCol < len(record) {
+			pair.Answer = record[answerCol]
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, nil
+}
+
+// qaPairChunks turns pairs into chunks with the answer as content and the question in the
+// chunk metadata, returning the questions as the texts to embed
+func qaPairChunks(pairs []QAPair) (contents, embedTexts []string, metadata []json.RawMessage) {
+	contents = make([]string, len(pairs))
+	embedTexts = make([]string, len(pairs))
+	metadata = make([]json.RawMessage, len(pairs))
+	for i, pair := range pairs {
+		contents[i] = pair.Answer
+		embedTexts[i] = pair.Question
+		metadata[i], _ = json.Marshal(map[string]string{"question": pair.Question})
+	}
+	return contents, embedTexts, metadata
+}
+
+// normalizeQAPairs replaces the content of a qa_pairs request with its normalized pairs
+func (r *AddDocumentRequest) normalizeQAPairs() error {
+	if r.SourceType != DocumentSourceTypeQAPairs {
+		return nil
+	}
+	content, _, err := NormalizeQAPairs(r.Content, r.MimeType)
+	if err != nil {
+		return fmt.Errorf("invalid Q&A pairs: %w", err)
+	}
+	r.Content = content
+	r.MimeType = "application/json"
+	return nil
+}
@@ -0,0 +1,108 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQAPairs(t *testing.T) {
+	t.Run("JSON array", func(t *testing.T) {
+		pairs, err := ParseQAPairs(`[{"question":" How do I reset my password? ","answer":"Use the reset link."}]`, "")
+		require.NoError(t, err)
+		assert.Equal(t, []QAPair{{Question: "How do I reset my password?", Answer: "Use the reset link."}}, pairs)
+	})
+
+	t.Run("JSON Lines", func(t *testing.T) {
+		content := "{\"question\":\"Q1\",\"answer\":\"A1\"}\n\n{\"question\":\"Q2\",\"answer\":\"A2\"}\n"
+		pairs, err := ParseQAPairs(content, "application/json")
+		require.NoError(t, err)
+		assert.Equal(t, []QAPair{{Question: "Q1", Answer: "A1"}, {Question: "Q2", Answer: "A2"}}, pairs)
+	})
+
+	t.Run("CSV keeps multiline answers", func(t *testing.T) {
+		content := "id,Answer,Question\n1,\"Line one\nLine two\",Q1\n"
+		pairs, err := ParseQAPairs(content, "text/csv")
+		require.NoError(t, err)
+		assert.Equal(t, []QAPair{{Question: "Q1", Answer: "Line one\nLine two"}}, pairs)
+	})
+
+	t.Run("CSV without required columns", func(t *testing.T) {
+		_, err := ParseQAPairs("q,a\nQ1,A1\n", "text/csv")
+		assert.Error(t, err)
+	})
+
+	t.Run("missing answer", func(t *testing.T) {
+		_, err := ParseQAPairs(`[{"question":"Q1","answer":"A1"},{"question":"Q2"}]`, "")
+		assert.ErrorContains(t, err, "pair 2")
+	})
+
+	t.Run("invalid JSON line", func(t *testing.T) {
+		_, err := ParseQAPairs("{\"question\":\"Q1\",\"answer\":\"A1\"}\nnot json\n", "")
+		assert.ErrorContains(t, err, "line 2")
+	})
+
+	t.Run("empty content", func(t *testing.T) {
+		_, err := ParseQAPairs("  ", "")
+		assert.ErrorIs(t, err, ErrNoQAPairs)
+
+		_, err = ParseQAPairs("question,answer\n", "text/csv")
+		assert.ErrorIs(t, err, ErrNoQAPairs)
+	})
+
+	t.Run("too many pairs", func(t *testing.T) {
+		var b strings.Builder
+		for i := 0; i <= MaxQAPairsPerDocument; i++ {
+			fmt.Fprintf(&b, "{\"question\":\"Q%d\",\"answer\":\"A\"}\n", i)
+		}
+		_, err := ParseQAPairs(b.String(), "")
+		assert.ErrorContains(t, err, "too many")
+	})
+}
+
+func TestNormalizeQAPairs(t *testing.T) {
+	content, count, err := NormalizeQAPairs("question,answer\nQ1,A1\nQ2,A2\n", "text/csv")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// The normalized content is parsed again when the document is processed
+	pairs, err := ParseQAPairs(content, "")
+	require.NoError(t, err)
+	assert.Equal(t, []QAPair{{Question: "Q1", Answer: "A1"}, {Question: "Q2", Answer: "A2"}}, pairs)
+}
+
+func TestQAPairChunks(t *testing.T) {
+	contents, embedTexts, metadata := qaPairChunks([]QAPair{{Question: "Q1", Answer: "A1"}})
+
+	assert.Equal(t, []string{"A1"}, contents)
+	assert.Equal(t, []string{"Q1"}, embedTexts)
+	require.Len(t, metadata, 1)
+
+	var meta map[string]string
+	require.NoError(t, json.Unmarshal(metadata[0], &meta))
+	assert.Equal(t, "Q1", meta["question"])
+}
+
+func TestAddDocumentRequest_NormalizeQAPairs(t *testing.T) {
+	t.Run("other source types are untouched", func(t *testing.T) {
+		req := AddDocumentRequest{Content: "plain text"}
+		require.NoError(t, req.normalizeQAPairs())
+		assert.Equal(t, "plain text", req.Content)
+	})
+
+	t.Run("qa_pairs content is normalized", func(t *testing.T) {
+		req := AddDocumentRequest{Content: "question,answer\nQ1,A1\n", MimeType: "text/csv", SourceType: DocumentSourceTypeQAPairs}
+		require.NoError(t, req.normalizeQAPairs())
+		assert.JSONEq(t, `[{"question":"Q1","answer":"A1"}]`, req.Content)
+		assert.Equal(t, "application/json", req.MimeType)
+	})
+
+	t.Run("invalid qa_pairs content", func(t *testing.T) {
+		req := AddDocumentRequest{Content: "[]", SourceType: DocumentSourceTypeQAPairs}
+		assert.ErrorIs(t, req.normalizeQAPairs(), ErrNoQAPairs)
+	})
+}
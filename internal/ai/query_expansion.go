@@ -0,0 +1,147 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// maxQueryExpansions bounds the reformulations generated for a query
+	maxQueryExpansions = 5
+
+	// rrfK dampens the weight of top ranks in Reciprocal Rank Fusion (the usual constant from
+	// Cormack et al.)
+	rrfK = 60
+)
+
+// QueryExpansion configures multi-query retrieval: the LLM rewrites the user's question Count
+// times, every version is searched, and the rankings are fused with Reciprocal Rank Fusion
+type QueryExpansion struct {
+	Provider Provider
+	Model    string
+	Count    int // Reformulations to generate (1-5)
+}
+
+// parseRAGQueryExpansion returns the number of reformulations set with the
+// @fluxbase:rag-query-expansion annotation, capped at maxQueryExpansions (0 when not set)
+func parseRAGQueryExpansion(code string) int {
+	matches := ragQueryExpansionPattern.FindStringSubmatch(code)
+	if len(matches) < 2 {
+		return 0
+	}
+	count, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0
+	}
+	return min(count, maxQueryExpansions)
+}
+
+// queryExpansionPrompt asks for reformulations, one per line
+const queryExpansionPrompt = `You rewrite search queries for a document retrieval system.
+Write %d different reformulations of the user's question. Use synonyms, different phrasing and the terms a document answering the question would use. Keep each reformulation in the language of the question and self-contained.
+Answer with the reformulations only, one per line, without numbering or explanations.`
+
+// expandQuery generates reformulations of a query with the LLM. The original query is not included.
+func expandQuery(ctx context.Context, exp *QueryExpansion, query string) ([]string, error) {
+	count := min(exp.Count, maxQueryExpansions)
+	resp, err := exp.Provider.Chat(ctx, &ChatRequest{
+		Model: exp.Model,
+		Messages: []Message{
+			{Role: RoleSystem, Content: fmt.Sprintf(queryExpansionPrompt, count)},
+			{Role: RoleUser, Content: query},
+		},
+		MaxTokens:   100 * count,
+		Temperature: 0.7,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate query reformulations: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no query reformulations generated")
+	}
+	return parseQueryReformulations(resp.Choices[0].Message.Content, query, count), nil
+}
+
+// reformulationPrefix matches list markers models add despite being asked not to
+var reformulationPrefix = regexp.MustCompile(`^\s*(?:[-*•]|\d+[.)]|\(\d+\))\s*`)
+
+// parseQueryReformulations extracts up to count distinct reformulations, one per line, that differ
+// from the original query
+func parseQueryReformulations(content, query string, count int) []string {
+	seen := map[string]bool{strings.ToLower(strings.TrimSpace(query)): true}
+	var reformulations []string
+	for _, line := range strings.Split(content, "\n") {
+		line = reformulationPrefix.ReplaceAllString(line, "")
+		line = strings.Trim(strings.TrimSpace(line), `"'`)
+		key := strings.ToLower(line)
+		if line == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		reformulations = append(reformulations, line)
+		if len(reformulations) == count {
+			break
+		}
+	}
+	return reformulations
+}
+
+// fuseRankings merges the result lists of several queries, each ordered best first, with
+// Reciprocal Rank Fusion: a chunk scores 1/(rrfK+rank) in every list it appears in. Chunks are
+// returned by fused score, keeping their best similarity, and cut to limit.
+func fuseRankings(rankings [][]RetrievalResult, limit int) []RetrievalResult {
+	scores := make(map[string]float64)
+	best := make(map[string]RetrievalResult)
+	for _, ranking := range rankings {
+		for rank, result := range ranking {
+			scores[result.ChunkID] += 1.0 / float64(rrfK+rank+1)
+			if current, ok := best[result.ChunkID]; !ok || result.Similarity > current.Similarity {
+				best[result.ChunkID] = result
+			}
+		}
+	}
+
+	fused := make([]RetrievalResult, 0, len(best))
+	for _, result := range best {
+		fused = append(fused, result)
+	}
+	sort.Slice(fused, func(i, j int) bool {
+		si, sj := scores[fused[i].ChunkID], scores[fused[j].ChunkID]
+		if si != sj {
+			return si > sj
+		}
+		if fused[i].Similarity != fused[j].Similarity {
+			return fused[i].Similarity > fused[j].Similarity
+		}
+		return fused[i].ChunkID < fused[j].ChunkID
+	})
+
+	if limit > 0 && len(fused) > limit {
+		fused = fused[:limit]
+	}
+	return fused
+}
+
+// queryExpansion returns the multi-query retrieval settings of a chatbot, or nil when it searches
+// the user's question alone. Reformulations are written by the chatbot's own model.
+func (h *ChatHandler) queryExpansion(ctx context.Context, chatbot *Chatbot) *QueryExpansion {
+	if chatbot.RAGQueryExpansion <= 0 {
+		return nil
+	}
+	provider, err := h.getProvider(ctx, chatbot)
+	if err != nil {
+		log.Warn().Err(err).Str("chatbot_id", chatbot.ID).Msg("Failed to get provider for RAG query expansion")
+		return nil
+	}
+	return &QueryExpansion{
+		Provider: provider,
+		Model:    chatbot.Model,
+		Count:    chatbot.RAGQueryExpansion,
+	}
+}
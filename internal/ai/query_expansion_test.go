@@ -0,0 +1,118 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reformulatingProvider answers every chat request with a fixed completion
+type reformulatingProvider struct {
+	content string
+	err     error
+	request *ChatRequest
+}
+
+func (p *reformulatingProvider) Name() string       { return "test" }
+func (p *reformulatingProvider) Type() ProviderType { return ProviderTypeOpenAI }
+func (p *reformulatingProvider) Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
+	p.request = req
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &ChatResponse{Choices: []Choice{{Message: Message{Role: RoleAssistant, Content: p.content}}}}, nil
+}
+func (p *reformulatingProvider) ChatStream(ctx context.Context, req *ChatRequest, callback StreamCallback) error {
+	return fmt.Errorf("not supported")
+}
+func (p *reformulatingProvider) ValidateConfig() error { return nil }
+func (p *reformulatingProvider) Close() error          { return nil }
+
+func TestExpandQuery(t *testing.T) {
+	provider := &reformulatingProvider{content: "1. How do I change my password?\n2) Reset account password\n- \"password recovery steps\"\n\nHow do I reset my password?\n"}
+
+	reformulations, err := expandQuery(context.Background(), &QueryExpansion{Provider: provider, Model: "gpt-4o-mini", Count: 3}, "How do I reset my password?")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"How do I change my password?", "Reset account password", "password recovery steps"}, reformulations)
+	assert.Equal(t, "gpt-4o-mini", provider.request.Model)
+	assert.Contains(t, provider.request.Messages[0].Content, "Write 3 different reformulations")
+	assert.Equal(t, "How do I reset my password?", provider.request.Messages[1].Content)
+}
+
+func TestExpandQuery_Error(t *testing.T) {
+	provider := &reformulatingProvider{err: fmt.Errorf("rate limited")}
+
+	_, err := expandQuery(context.Background(), &QueryExpansion{Provider: provider, Count: 3}, "query")
+	assert.ErrorContains(t, err, "rate limited")
+}
+
+func TestParseQueryReformulations(t *testing.T) {
+	t.Run("drops the original and duplicates", func(t *testing.T) {
+		content := "Opening hours\nWhen are you open?\nopening hours\nwhen are you OPEN?\n"
+		assert.Equal(t, []string{"Opening hours"}, parseQueryReformulations(content, "When are you open?", 5))
+	})
+
+	t.Run("caps the count", func(t *testing.T) {
+		content := "a\nb\nc\nd"
+		assert.Equal(t, []string{"a", "b"}, parseQueryReformulations(content, "q", 2))
+	})
+
+	t.Run("empty completion", func(t *testing.T) {
+		assert.Empty(t, parseQueryReformulations("\n  \n", "q", 3))
+	})
+}
+
+func TestParseRAGQueryExpansion(t *testing.T) {
+	assert.Equal(t, 0, parseRAGQueryExpansion("/** @fluxbase:rag-max-chunks 5 */"))
+	assert.Equal(t, 3, parseRAGQueryExpansion("/** @fluxbase:rag-query-expansion 3 */"))
+	assert.Equal(t, maxQueryExpansions, parseRAGQueryExpansion("/** @fluxbase:rag-query-expansion 12 */"))
+}
+
+func TestFuseRankings(t *testing.T) {
+	result := func(id string, similarity float64) RetrievalResult {
+		return RetrievalResult{ChunkID: id, Similarity: similarity}
+	}
+
+	t.Run("chunks found by several queries rank first", func(t *testing.T) {
+		fused := fuseRankings([][]RetrievalResult{
+			{result("a", 0.9), result("b", 0.8), result("c", 0.7)},
+			{result("c", 0.85), result("d", 0.8)},
+			{result("d", 0.75), result("c", 0.72)},
+		}, 0)
+
+		ids := make([]string, len(fused))
+		for i, r := range fused {
+			ids[i] = r.ChunkID
+		}
+		assert.Equal(t, []string{"c", "d", "a", "b"}, ids)
+
+		// The best similarity of a chunk across queries is kept
+		assert.Equal(t, 0.85, fused[0].Similarity)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		fused := fuseRankings([][]RetrievalResult{
+			{result("a", 0.9), result("b", 0.8), result("c", 0.7)},
+		}, 2)
+		require.Len(t, fused, 2)
+		assert.Equal(t, "a", fused[0].ChunkID)
+		assert.Equal(t, "b", fused[1].ChunkID)
+	})
+
+	t.Run("no results", func(t *testing.T) {
+		assert.Empty(t, fuseRankings([][]RetrievalResult{nil, {}}, 5))
+	})
+}
+
+func TestRankBySimilarity(t *testing.T) {
+	results := []RetrievalResult{{ChunkID: "kb1-a", Similarity: 0.75}, {ChunkID: "kb2-a", Similarity: 0.9}}
+
+	ranked := rankBySimilarity(results)
+
+	assert.Equal(t, "kb2-a", ranked[0].ChunkID)
+	assert.Equal(t, "kb1-a", results[0].ChunkID, "input is not reordered")
+}
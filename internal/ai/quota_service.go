@@ -162,3 +162,16 @@ func (s *QuotaService) SetUserQuota(ctx context.Context, userID string, req SetU
 
 	return s.storage.SetUserQuota(ctx, quota)
 }
+
+// CheckVectorCollectionQuota checks if adding points would exceed a vector collection's quota
+func (s *QuotaService) CheckVectorCollectionQuota(collection *VectorCollection, additionalPoints int) error {
+	if collection.QuotaMaxPoints > 0 && collection.PointCount+additionalPoints > collection.QuotaMaxPoints {
+		return &QuotaError{
+			ResourceType: "points",
+			Used:         int64(collection.PointCount),
+			Limit:        int64(collection.QuotaMaxPoints),
+			Requested:    int64(additionalPoints),
+		}
+	}
+	return nil
+}
@@ -3,10 +3,13 @@ package ai
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/nimbleflux/fluxbase/internal/observability"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // RAGService handles retrieval-augmented generation for chatbots
@@ -15,6 +18,8 @@ type RAGService struct {
 	embeddingService *EmbeddingService
 	knowledgeGraph   *KnowledgeGraph // For graph-boosted search
 	entityExtractor  EntityExtractor // For extracting entities from queries
+	missNotifier     *RetrievalMissNotifier
+	metrics          *observability.Metrics // optional
 }
 
 // NewRAGService creates a new RAG service
@@ -29,17 +34,24 @@ func NewRAGService(
 		embeddingService: embeddingService,
 		knowledgeGraph:   knowledgeGraph,
 		entityExtractor:  entityExtractor,
+		missNotifier:     NewRetrievalMissNotifier(storage),
 	}
 }
 
+// SetMetrics sets the metrics that record retrieval latency
+func (r *RAGService) SetMetrics(metrics *observability.Metrics) {
+	r.metrics = metrics
+}
+
 // RetrieveContextOptions contains options for retrieval
 type RetrieveContextOptions struct {
 	ChatbotID      string
 	ConversationID string
 	UserID         string
 	Query          string
-	MaxChunks      int     // Override max chunks (optional)
-	Threshold      float64 // Override threshold (optional)
+	MaxChunks      int             // Override max chunks (optional)
+	Threshold      float64         // Override threshold (optional)
+	Expansion      *QueryExpansion // Search reformulations of the query too (optional)
 }
 
 // RetrieveContextResult contains the retrieval results
@@ -49,21 +61,61 @@ type RetrieveContextResult struct {
 	TotalRetrieved   int
 	DurationMs       int64
 	EmbeddingModel   string
+	Reformulations   []string // Reformulations of the query that were searched too
 }
 
 // RetrieveContext retrieves relevant context for a user query
 func (r *RAGService) RetrieveContext(ctx context.Context, opts RetrieveContextOptions) (*RetrieveContextResult, error) {
+	ctx, span := observability.StartAISpan(ctx, "rag.retrieve",
+		attribute.String("ai.chatbot_id", opts.ChatbotID),
+		attribute.Bool("ai.query_expansion", opts.Expansion != nil && opts.Expansion.Count > 0),
+	)
+	start := time.Now()
+	result, err := r.retrieveContext(ctx, opts)
+	if err == nil {
+		span.SetAttributes(attribute.Int("ai.chunks", result.TotalRetrieved))
+	}
+	observability.EndAISpan(span, err)
+	if r.metrics != nil {
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		r.metrics.RecordAIRAGRetrieval(status, time.Since(start))
+	}
+	return result, err
+}
+
+// retrieveContext embeds the query, searches the chatbot's knowledge bases and logs the retrieval
+func (r *RAGService) retrieveContext(ctx context.Context, opts RetrieveContextOptions) (*RetrieveContextResult, error) {
 	if r.embeddingService == nil {
 		return nil, fmt.Errorf("embedding service not configured")
 	}
 
 	start := time.Now()
 
-	// Generate embedding for the query
-	queryEmbedding, err := r.embeddingService.EmbedSingle(ctx, opts.Query, "")
+	// Multi-query retrieval also searches LLM reformulations of the query
+	queries := []string{opts.Query}
+	var reformulations []string
+	if opts.Expansion != nil && opts.Expansion.Count > 0 && opts.Expansion.Provider != nil {
+		var err error
+		reformulations, err = expandQuery(ctx, opts.Expansion, opts.Query)
+		if err != nil {
+			// Fall back to the query alone
+			log.Warn().Err(err).Str("chatbot_id", opts.ChatbotID).Msg("Failed to expand RAG query")
+		}
+		queries = append(queries, reformulations...)
+	}
+
+	// Generate embeddings for the queries
+	embeddings, err := r.embeddingService.Embed(ctx, queries, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to embed query: %w", err)
 	}
+	if len(embeddings.Embeddings) != len(queries) {
+		return nil, fmt.Errorf("failed to embed query: got %d embeddings for %d queries", len(embeddings.Embeddings), len(queries))
+	}
+	queryEmbedding := embeddings.Embeddings[0]
 
 	// Build search options with user context for isolation
 	searchOpts := SearchChatbotKnowledgeOptions{
@@ -80,6 +132,27 @@ func (r *RAGService) RetrieveContext(ctx context.Context, opts RetrieveContextOp
 		return nil, fmt.Errorf("failed to search knowledge: %w", err)
 	}
 
+	// Search every reformulation the same way and fuse the rankings, keeping as many chunks as
+	// the most productive single search returned
+	if len(queries) > 1 {
+		rankings := [][]RetrievalResult{rankBySimilarity(chunks)}
+		limit := len(chunks)
+		for _, embedding := range embeddings.Embeddings[1:] {
+			results, err := r.storage.SearchChatbotKnowledgeWithOptions(ctx, opts.ChatbotID, embedding, searchOpts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to search knowledge: %w", err)
+			}
+			rankings = append(rankings, rankBySimilarity(results))
+			limit = max(limit, len(results))
+		}
+		chunks = fuseRankings(rankings, limit)
+	}
+
+	// Report knowledge bases that had nothing above the threshold, before truncation drops any hits
+	if r.missNotifier != nil && r.storage != nil {
+		r.missNotifier.NotifyAsync(opts, queryEmbedding, chunks)
+	}
+
 	// Apply optional overrides
 	if opts.MaxChunks > 0 && len(chunks) > opts.MaxChunks {
 		chunks = chunks[:opts.MaxChunks]
@@ -125,11 +198,28 @@ func (r *RAGService) RetrieveContext(ctx context.Context, opts RetrieveContextOp
 		TotalRetrieved:   len(chunks),
 		DurationMs:       duration.Milliseconds(),
 		EmbeddingModel:   r.embeddingService.DefaultModel(),
+		Reformulations:   reformulations,
 	}, nil
 }
 
+// rankBySimilarity orders the results of a search across knowledge bases best first
+func rankBySimilarity(results []RetrievalResult) []RetrievalResult {
+	ranked := append([]RetrievalResult(nil), results...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Similarity > ranked[j].Similarity
+	})
+	return ranked
+}
+
 // formatContext formats retrieved chunks into a string for the LLM prompt
 func (r *RAGService) formatContext(chunks []RetrievalResult) string {
+	return formatContextForLanguage(chunks, "")
+}
+
+// formatContextForLanguage formats retrieved chunks for the LLM prompt. When userLanguage is
+// set, sources detected to be in another language are labeled so the model translates them
+// into the user's language instead of quoting them verbatim.
+func formatContextForLanguage(chunks []RetrievalResult, userLanguage string) string {
 	if len(chunks) == 0 {
 		return ""
 	}
@@ -137,6 +227,10 @@ func (r *RAGService) formatContext(chunks []RetrievalResult) string {
 	var sb strings.Builder
 	sb.WriteString("## Relevant Knowledge\n\n")
 	sb.WriteString("The following information was retrieved from the knowledge base and may be relevant to the user's question:\n\n")
+	if userLanguage != "" {
+		fmt.Fprintf(&sb, "Sources labeled with a language other than %[1]s must be translated into %[1]s when you use them.\n\n",
+			LanguageName(userLanguage))
+	}
 
 	for i, chunk := range chunks {
 		fmt.Fprintf(&sb, "### Source %d", i+1)
@@ -146,7 +240,13 @@ func (r *RAGService) formatContext(chunks []RetrievalResult) string {
 		if chunk.KnowledgeBaseName != "" {
 			fmt.Fprintf(&sb, " (from %s)", chunk.KnowledgeBaseName)
 		}
-		fmt.Fprintf(&sb, " [similarity: %.2f]\n\n", chunk.Similarity)
+		fmt.Fprintf(&sb, " [similarity: %.2f]", chunk.Similarity)
+		if userLanguage != "" {
+			if lang := DetectLanguage(chunk.Content); lang != "" && lang != userLanguage {
+				fmt.Fprintf(&sb, " [language: %s]", LanguageName(lang))
+			}
+		}
+		sb.WriteString("\n\n")
 		sb.WriteString(chunk.Content)
 		sb.WriteString("\n\n---\n\n")
 	}
@@ -200,7 +300,7 @@ func (r *RAGService) VectorSearch(ctx context.Context, opts VectorSearchOptions)
 	}
 
 	// Get linked knowledge bases for this chatbot
-	links, err := r.storage.GetChatbotKnowledgeBases(ctx, opts.ChatbotID)
+	links, err := r.storage.GetChatbotKnowledgeBasesCached(ctx, opts.ChatbotID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get linked knowledge bases: %w", err)
 	}
@@ -229,14 +329,16 @@ func (r *RAGService) VectorSearch(ctx context.Context, opts VectorSearchOptions)
 
 	// Resolve which KBs to search
 	kbsToSearch := make(map[string]*KnowledgeBase)
+	freshnessHalfLives := make(map[string]float64)
 	for _, link := range links {
 		if !link.Enabled {
 			continue
 		}
-		kb, err := r.storage.GetKnowledgeBase(ctx, link.KnowledgeBaseID)
+		kb, err := r.storage.GetKnowledgeBaseCached(ctx, link.KnowledgeBaseID)
 		if err != nil || kb == nil || !kb.Enabled {
 			continue
 		}
+		freshnessHalfLives[kb.ID] = link.freshnessHalfLife()
 
 		// If specific KBs requested, filter to those
 		if len(opts.KnowledgeBases) > 0 {
@@ -285,14 +387,15 @@ func (r *RAGService) VectorSearch(ctx context.Context, opts VectorSearchOptions)
 		// Fallback to hybrid search if graph search wasn't used or failed
 		if len(results) == 0 {
 			hybridOpts := HybridSearchOptions{
-				Query:          opts.Query,
-				QueryEmbedding: queryEmbedding,
-				Limit:          perKBLimit,
-				Threshold:      opts.Threshold,
-				Mode:           SearchModeHybrid,
-				SemanticWeight: 0.7, // 70% semantic, 30% keyword
-				KeywordBoost:   0.2, // 20% boost for exact keyword matches
-				Filter:         filter,
+				Query:                 opts.Query,
+				QueryEmbedding:        queryEmbedding,
+				Limit:                 perKBLimit,
+				Threshold:             opts.Threshold,
+				Mode:                  SearchModeHybrid,
+				SemanticWeight:        0.7, // 70% semantic, 30% keyword
+				KeywordBoost:          0.2, // 20% boost for exact keyword matches
+				Filter:                filter,
+				FreshnessHalfLifeDays: freshnessHalfLives[kbID],
 			}
 
 			results, err = r.storage.SearchChunksHybrid(ctx, kbID, hybridOpts)
@@ -356,7 +459,7 @@ func sortVectorSearchResults(results []VectorSearchResult) {
 
 // GetChatbotRAGConfig returns the RAG configuration for a chatbot
 func (r *RAGService) GetChatbotRAGConfig(ctx context.Context, chatbotID string) (*ChatbotRAGConfig, error) {
-	links, err := r.storage.GetChatbotKnowledgeBases(ctx, chatbotID)
+	links, err := r.storage.GetChatbotKnowledgeBasesCached(ctx, chatbotID)
 	if err != nil {
 		return nil, err
 	}
@@ -381,7 +484,7 @@ func (r *RAGService) GetChatbotRAGConfig(ctx context.Context, chatbotID string)
 		}
 		totalMaxChunks += maxChunks
 
-		kb, err := r.storage.GetKnowledgeBase(ctx, link.KnowledgeBaseID)
+		kb, err := r.storage.GetKnowledgeBaseCached(ctx, link.KnowledgeBaseID)
 		if err == nil && kb != nil && kb.Enabled {
 			knowledgeBases = append(knowledgeBases, kb.ToSummary())
 		}
@@ -403,7 +506,7 @@ type ChatbotRAGConfig struct {
 
 // IsRAGEnabled checks if a chatbot has RAG enabled
 func (r *RAGService) IsRAGEnabled(ctx context.Context, chatbotID string) bool {
-	links, err := r.storage.GetChatbotKnowledgeBases(ctx, chatbotID)
+	links, err := r.storage.GetChatbotKnowledgeBasesCached(ctx, chatbotID)
 	if err != nil {
 		return false
 	}
@@ -424,25 +527,45 @@ func (r *RAGService) BuildRAGSystemPromptSection(ctx context.Context, chatbotID,
 
 // BuildRAGSystemPromptSectionWithUser builds the RAG section for a system prompt with user context
 func (r *RAGService) BuildRAGSystemPromptSectionWithUser(ctx context.Context, chatbotID, userQuery, userID string) (string, error) {
+	section, _, err := r.BuildRAGSystemPromptSectionWithResult(ctx, chatbotID, userQuery, userID, nil)
+	return section, err
+}
+
+// BuildRAGSystemPromptSectionWithResult builds the RAG section for a system prompt and also
+// returns the raw retrieval result. The result is nil when RAG is disabled for the chatbot
+// or retrieval failed. With an expansion, reformulations of the query are searched too.
+func (r *RAGService) BuildRAGSystemPromptSectionWithResult(ctx context.Context, chatbotID, userQuery, userID string, expansion *QueryExpansion) (string, *RetrieveContextResult, error) {
 	if !r.IsRAGEnabled(ctx, chatbotID) {
-		return "", nil
+		return "", nil, nil
 	}
 
 	result, err := r.RetrieveContext(ctx, RetrieveContextOptions{
 		ChatbotID: chatbotID,
 		Query:     userQuery,
 		UserID:    userID,
+		Expansion: expansion,
 	})
 	if err != nil {
 		log.Warn().Err(err).Str("chatbot_id", chatbotID).Msg("Failed to retrieve RAG context")
-		return "", nil // Don't fail the request, just skip RAG
+		return "", nil, nil // Don't fail the request, just skip RAG
 	}
 
 	if result.TotalRetrieved == 0 {
-		return "", nil
+		return "", result, nil
 	}
 
-	return result.FormattedContext, nil
+	return result.FormattedContext, result, nil
+}
+
+// BestSimilarity returns the highest similarity score among the retrieved chunks (0 if none)
+func (r *RetrieveContextResult) BestSimilarity() float64 {
+	best := 0.0
+	for _, chunk := range r.Chunks {
+		if chunk.Similarity > best {
+			best = chunk.Similarity
+		}
+	}
+	return best
 }
 
 // optString returns a pointer to a string, or nil if empty
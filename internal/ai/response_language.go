@@ -0,0 +1,199 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// languageNames maps the ISO 639-1 codes returned by DetectLanguage to English names
+var languageNames = map[string]string{
+	"ar": "Arabic",
+	"de": "German",
+	"el": "Greek",
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"he": "Hebrew",
+	"hi": "Hindi",
+	"it": "Italian",
+	"ja": "Japanese",
+	"ko": "Korean",
+	"nl": "Dutch",
+	"pt": "Portuguese",
+	"ru": "Russian",
+	"th": "Thai",
+	"uk": "Ukrainian",
+	"zh": "Chinese",
+}
+
+// latinStopwords are frequent words that identify Latin-script languages. Words shared by
+// several languages count towards each of them.
+var latinStopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "to", "of", "what", "how", "can", "i", "you", "my", "do", "does", "with", "for", "this", "that", "it", "have", "please", "where", "when", "why", "which", "not"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ich", "wie", "was", "ein", "eine", "mit", "für", "auf", "sie", "wir", "kann", "können", "mein", "meine", "bitte", "wo", "warum", "gibt", "zu", "den", "dem", "habe", "es"},
+	"fr": {"le", "la", "les", "et", "est", "je", "vous", "une", "un", "des", "pour", "dans", "que", "qui", "comment", "pourquoi", "où", "mon", "ma", "mes", "avec", "pas", "ne", "sur", "du", "au", "puis"},
+	"es": {"el", "la", "los", "las", "y", "es", "que", "de", "en", "un", "una", "por", "para", "cómo", "como", "qué", "dónde", "mi", "mis", "con", "no", "se", "del", "puedo", "hola", "está"},
+	"it": {"il", "lo", "la", "gli", "le", "e", "è", "che", "di", "un", "una", "per", "come", "cosa", "dove", "mio", "mia", "con", "non", "sono", "del", "della", "posso", "ciao", "perché"},
+	"pt": {"o", "a", "os", "as", "e", "é", "que", "de", "um", "uma", "para", "com", "não", "como", "onde", "meu", "minha", "do", "da", "em", "posso", "olá", "você", "está", "por"},
+	"nl": {"de", "het", "een", "en", "is", "niet", "ik", "je", "wat", "hoe", "waar", "mijn", "met", "voor", "op", "van", "dat", "kan", "zijn", "waarom", "graag", "hallo"},
+}
+
+// latinLetterHints are letters that (nearly) only occur in one Latin-script language
+var latinLetterHints = map[rune]string{
+	'ß': "de", 'ä': "de", 'ö': "de", 'ü': "de",
+	'ñ': "es", '¿': "es", '¡': "es",
+	'ç': "fr", 'ê': "fr", 'è': "fr", 'œ': "fr",
+	'ã': "pt", 'õ': "pt",
+	'ì': "it", 'ò': "it",
+}
+
+// latinStopwordIndex maps each stopword to the languages using it
+var latinStopwordIndex = func() map[string][]string {
+	index := make(map[string][]string)
+	for lang, words := range latinStopwords {
+		for _, word := range words {
+			index[word] = append(index[word], lang)
+		}
+	}
+	return index
+}()
+
+// minLanguageScore is the lowest score at which a Latin-script language is reported
+const minLanguageScore = 2
+
+// DetectLanguage returns the ISO 639-1 code of the language text is written in, or "" when
+// it cannot be determined with reasonable confidence (e.g. for very short messages).
+// Non-Latin scripts are identified by their characters and Latin-script languages by
+// frequent words and language-specific letters.
+func DetectLanguage(text string) string {
+	var letters, latin int
+	scripts := make(map[string]int)
+	ukrainian := false
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+			scripts["ja"]++
+		case unicode.Is(unicode.Han, r):
+			scripts["zh"]++
+		case unicode.Is(unicode.Hangul, r):
+			scripts["ko"]++
+		case unicode.Is(unicode.Arabic, r):
+			scripts["ar"]++
+		case unicode.Is(unicode.Hebrew, r):
+			scripts["he"]++
+		case unicode.Is(unicode.Greek, r):
+			scripts["el"]++
+		case unicode.Is(unicode.Thai, r):
+			scripts["th"]++
+		case unicode.Is(unicode.Devanagari, r):
+			scripts["hi"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			scripts["ru"]++
+			if strings.ContainsRune("іїєґІЇЄҐ", r) {
+				ukrainian = true
+			}
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+
+	// Mostly non-Latin text: the script decides. Kana marks Japanese even when mixed with kanji.
+	if latin*2 < letters {
+		if scripts["ja"] > 0 {
+			return "ja"
+		}
+		best, bestCount := "", 0
+		for lang, count := range scripts {
+			if count > bestCount || (count == bestCount && lang < best) {
+				best, bestCount = lang, count
+			}
+		}
+		if best == "ru" && ukrainian {
+			return "uk"
+		}
+		return best
+	}
+
+	return detectLatinLanguage(text)
+}
+
+// detectLatinLanguage scores Latin-script text by stopwords and language-specific letters
+func detectLatinLanguage(text string) string {
+	lower := strings.ToLower(text)
+	scores := make(map[string]int)
+	for _, word := range strings.FieldsFunc(lower, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}) {
+		for _, lang := range latinStopwordIndex[word] {
+			scores[lang]++
+		}
+	}
+	for _, r := range lower {
+		if lang, ok := latinLetterHints[r]; ok {
+			scores[lang]++
+		}
+	}
+
+	best, bestScore, secondScore := "", 0, 0
+	for lang, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, secondScore = lang, score, bestScore
+		case score > secondScore:
+			secondScore = score
+		}
+	}
+	if bestScore < minLanguageScore || bestScore == secondScore {
+		return ""
+	}
+	return best
+}
+
+// LanguageName returns the English name of an ISO 639-1 code, or the code itself
+func LanguageName(code string) string {
+	if name, ok := languageNames[code]; ok {
+		return name
+	}
+	return code
+}
+
+// MatchesUserLanguage reports whether replies should follow the detected language of each user
+// message. A fixed response language always wins; otherwise the chatbot's
+// @fluxbase:match-user-language annotation overrides the ai.match_user_language default.
+func (c *Chatbot) MatchesUserLanguage(defaultEnabled bool) bool {
+	if c.ResponseLanguage != "" && c.ResponseLanguage != "auto" {
+		return false
+	}
+	if c.MatchUserLanguage != nil {
+		return *c.MatchUserLanguage
+	}
+	return defaultEnabled
+}
+
+// parseMatchUserLanguage returns the value of the @fluxbase:match-user-language annotation, or
+// nil when it is not set
+func parseMatchUserLanguage(code string) *bool {
+	matches := matchUserLanguagePattern.FindStringSubmatch(code)
+	if len(matches) < 2 {
+		return nil
+	}
+	enabled := matches[1] == "true"
+	return &enabled
+}
+
+// userLanguageInstruction is the system prompt section that pins the reply to the detected
+// language of the user's message
+func userLanguageInstruction(lang string) string {
+	name := LanguageName(lang)
+	return fmt.Sprintf("## Reply Language\n\n"+
+		"The user's latest message is written in %[1]s. Reply in %[1]s, even if the retrieved knowledge, "+
+		"tool results or earlier messages are in another language. Translate any information you use from them into %[1]s.\n", name)
+}
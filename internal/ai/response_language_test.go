@@ -0,0 +1,106 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"english", "How do I reset the password for my account?", "en"},
+		{"german", "Wie kann ich mein Passwort zurücksetzen? Bitte helfen Sie mir.", "de"},
+		{"french", "Comment est-ce que je peux changer le mot de passe de mon compte ?", "fr"},
+		{"spanish", "¿Cómo puedo cambiar la contraseña de mi cuenta?", "es"},
+		{"italian", "Come posso cambiare la password del mio account?", "it"},
+		{"portuguese", "Como posso mudar a senha da minha conta? Não consigo entrar.", "pt"},
+		{"dutch", "Hoe kan ik het wachtwoord van mijn account wijzigen?", "nl"},
+		{"russian", "Как мне сменить пароль?", "ru"},
+		{"ukrainian", "Як змінити пароль від мого облікового запису?", "uk"},
+		{"japanese", "パスワードを変更するにはどうすればいいですか", "ja"},
+		{"chinese", "我怎么修改密码", "zh"},
+		{"korean", "비밀번호를 어떻게 변경하나요", "ko"},
+		{"arabic", "كيف يمكنني تغيير كلمة المرور", "ar"},
+		{"too short", "ok", ""},
+		{"no letters", "12345 !?", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DetectLanguage(tt.text))
+		})
+	}
+}
+
+func TestLanguageName(t *testing.T) {
+	assert.Equal(t, "German", LanguageName("de"))
+	assert.Equal(t, "xx", LanguageName("xx"))
+}
+
+func TestChatbot_MatchesUserLanguage(t *testing.T) {
+	enabled, disabled := true, false
+
+	assert.True(t, (&Chatbot{ResponseLanguage: "auto"}).MatchesUserLanguage(true), "inherits the default")
+	assert.False(t, (&Chatbot{ResponseLanguage: "auto"}).MatchesUserLanguage(false))
+	assert.True(t, (&Chatbot{MatchUserLanguage: &enabled}).MatchesUserLanguage(false), "chatbot enables it")
+	assert.False(t, (&Chatbot{MatchUserLanguage: &disabled}).MatchesUserLanguage(true), "chatbot disables it")
+	assert.False(t, (&Chatbot{ResponseLanguage: "German", MatchUserLanguage: &enabled}).MatchesUserLanguage(true), "fixed language wins")
+}
+
+func TestParseMatchUserLanguage(t *testing.T) {
+	assert.Nil(t, parseMatchUserLanguage("// @fluxbase:response-language auto"))
+
+	value := parseMatchUserLanguage("// @fluxbase:match-user-language true")
+	require.NotNil(t, value)
+	assert.True(t, *value)
+
+	value = parseMatchUserLanguage("// @fluxbase:match-user-language false")
+	require.NotNil(t, value)
+	assert.False(t, *value)
+}
+
+func TestChatbot_MatchUserLanguageAnnotation(t *testing.T) {
+	code := "/**\n * @fluxbase:match-user-language true\n */\nexport default `You are helpful.`;"
+
+	config := ParseChatbotConfig(code)
+	require.NotNil(t, config.MatchUserLanguage)
+	assert.True(t, *config.MatchUserLanguage)
+
+	// Not stored in the database: re-parsed from code when loaded
+	chatbot := &Chatbot{Code: code}
+	chatbot.PopulateDerivedFields()
+	require.NotNil(t, chatbot.MatchUserLanguage)
+	assert.True(t, *chatbot.MatchUserLanguage)
+}
+
+func TestUserLanguageInstruction(t *testing.T) {
+	instruction := userLanguageInstruction("de")
+	assert.Contains(t, instruction, "written in German")
+	assert.Contains(t, instruction, "Translate any information")
+}
+
+func TestFormatContextForLanguage(t *testing.T) {
+	chunks := []RetrievalResult{
+		{DocumentTitle: "FAQ", Content: "Um Ihr Passwort zurückzusetzen, klicken Sie auf den Link in der E-Mail.", Similarity: 0.9},
+		{DocumentTitle: "Guide", Content: "To reset your password, click the link in the email.", Similarity: 0.8},
+	}
+
+	t.Run("labels sources in other languages", func(t *testing.T) {
+		result := formatContextForLanguage(chunks, "en")
+		assert.Contains(t, result, "must be translated into English")
+		assert.Contains(t, result, "### Source 1: FAQ [similarity: 0.90] [language: German]")
+		assert.Contains(t, result, "### Source 2: Guide [similarity: 0.80]\n")
+	})
+
+	t.Run("no labels without a user language", func(t *testing.T) {
+		result := formatContextForLanguage(chunks, "")
+		assert.NotContains(t, result, "[language:")
+		assert.NotContains(t, result, "must be translated")
+	})
+}
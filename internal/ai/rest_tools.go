@@ -0,0 +1,376 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nimbleflux/fluxbase/internal/query"
+)
+
+// REST tools
+//
+// A chatbot can offer the model fixed table queries as tools, so it can answer questions such
+// as "where is my order?" without writing SQL. Each tool is a query on the table API with
+// placeholders for the arguments the model fills in:
+//
+//	@fluxbase:rest-tool lookup_order orders?select=id,status,shipped_at&id=eq.{order_id:integer}&limit=1 Look up the status of an order by its ID
+//	@fluxbase:rest-tool recent_orders sales.orders?select=id,total&status=neq.cancelled&order=created_at.desc&limit=5 List the user's latest orders
+//
+// The query names a table (schema.table, public by default) and takes select, order, limit and
+// column=operator.value filters. A value is a literal or a {name:type} placeholder, where type is
+// string (default), integer, number or boolean. Placeholders become the required parameters of
+// the tool's function-calling schema. Queries run with the user's RLS context and the chatbot's
+// allowed schemas and tables, and only ever read.
+
+// RESTTool is a table query the model can call by name
+type RESTTool struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Schema      string           `json:"schema"`
+	Table       string           `json:"table"`
+	Select      []string         `json:"select,omitempty"` // Empty selects every column
+	Filters     []RESTToolFilter `json:"filters,omitempty"`
+	Order       []RESTToolOrder  `json:"order,omitempty"`
+	Limit       int              `json:"limit"`
+	Params      []RESTToolParam  `json:"params,omitempty"` // Placeholders in order of appearance
+}
+
+// RESTToolFilter is a column=operator.value filter of a REST tool
+type RESTToolFilter struct {
+	Column   string `json:"column"`
+	Operator string `json:"operator"`
+	Value    string `json:"value,omitempty"` // Literal value, when Param is empty
+	Param    string `json:"param,omitempty"` // Placeholder filled in by the model
+}
+
+// RESTToolOrder is an order=column.direction entry of a REST tool
+type RESTToolOrder struct {
+	Column string `json:"column"`
+	Desc   bool   `json:"desc,omitempty"`
+}
+
+// RESTToolParam is an argument of a REST tool
+type RESTToolParam struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // "string", "integer", "number" or "boolean"
+}
+
+const (
+	restToolDefaultLimit = 10
+	restToolMaxLimit     = 100
+)
+
+// restToolOperators maps the filter operators of REST tools to SQL
+var restToolOperators = map[string]string{
+	"eq":    "=",
+	"neq":   "<>",
+	"gt":    ">",
+	"gte":   ">=",
+	"lt":    "<",
+	"lte":   "<=",
+	"like":  "LIKE",
+	"ilike": "ILIKE",
+}
+
+var restToolParamTypes = map[string]bool{"string": true, "integer": true, "number": true, "boolean": true}
+
+var (
+	// @fluxbase:rest-tool lookup_order orders?id=eq.{order_id:integer}&limit=1 Look up an order
+	restToolPattern = regexp.MustCompile(`@fluxbase:rest-tool\s+([^\s*]+)\s+([^\s*]+)[ \t]*([^\n*]*)`)
+
+	restToolIdentifierPattern  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	restToolPlaceholderPattern = regexp.MustCompile(`^\{([a-zA-Z_][a-zA-Z0-9_]*)(?::([a-z]+))?\}$`)
+)
+
+// parseRESTToolsConfig parses the REST tool annotations into config. Tools that cannot be
+// parsed are ignored.
+func parseRESTToolsConfig(code string, config *ChatbotConfig) {
+	config.RESTTools = nil
+	seen := make(map[string]bool)
+	for _, matches := range restToolPattern.FindAllStringSubmatch(code, -1) {
+		tool, err := parseRESTTool(matches[1], matches[2], matches[3])
+		if err != nil || seen[tool.Name] {
+			continue
+		}
+		seen[tool.Name] = true
+		config.RESTTools = append(config.RESTTools, tool)
+	}
+}
+
+// parseRESTTool parses a single REST tool annotation
+func parseRESTTool(name, path, description string) (RESTTool, error) {
+	if !restToolIdentifierPattern.MatchString(name) || len(name) > 64 {
+		return RESTTool{}, fmt.Errorf("invalid tool name %q", name)
+	}
+	if _, builtin := MCPToolInfoMap[name]; builtin || name == "execute_sql" {
+		return RESTTool{}, fmt.Errorf("tool name %q is reserved", name)
+	}
+
+	table, rawQuery, _ := strings.Cut(path, "?")
+	tool := RESTTool{
+		Name:        name,
+		Description: strings.TrimSpace(description),
+		Schema:      "public",
+		Table:       table,
+		Limit:       restToolDefaultLimit,
+	}
+	if tool.Description == "" {
+		tool.Description = "Query the " + table + " table"
+	}
+	if schema, tableName, ok := strings.Cut(table, "."); ok {
+		tool.Schema, tool.Table = schema, tableName
+	}
+	if !restToolIdentifierPattern.MatchString(tool.Schema) || !restToolIdentifierPattern.MatchString(tool.Table) {
+		return RESTTool{}, fmt.Errorf("invalid table %q", table)
+	}
+
+	paramTypes := make(map[string]string)
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return RESTTool{}, fmt.Errorf("invalid query parameter %q", pair)
+		}
+
+		switch key {
+		case "select":
+			for _, col := range strings.Split(value, ",") {
+				if col == "*" {
+					continue
+				}
+				if !restToolIdentifierPattern.MatchString(col) {
+					return RESTTool{}, fmt.Errorf("invalid select column %q", col)
+				}
+				tool.Select = append(tool.Select, col)
+			}
+		case "order":
+			for _, part := range strings.Split(value, ",") {
+				col, dir, _ := strings.Cut(part, ".")
+				if !restToolIdentifierPattern.MatchString(col) || (dir != "" && dir != "asc" && dir != "desc") {
+					return RESTTool{}, fmt.Errorf("invalid order %q", part)
+				}
+				tool.Order = append(tool.Order, RESTToolOrder{Column: col, Desc: dir == "desc"})
+			}
+		case "limit":
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 1 || limit > restToolMaxLimit {
+				return RESTTool{}, fmt.Errorf("limit must be between 1 and %d", restToolMaxLimit)
+			}
+			tool.Limit = limit
+		default:
+			filter, paramType, err := parseRESTToolFilter(key, value)
+			if err != nil {
+				return RESTTool{}, err
+			}
+			if filter.Param != "" {
+				if existing, ok := paramTypes[filter.Param]; !ok {
+					paramTypes[filter.Param] = paramType
+					tool.Params = append(tool.Params, RESTToolParam{Name: filter.Param, Type: paramType})
+				} else if existing != paramType {
+					return RESTTool{}, fmt.Errorf("parameter %s is used with types %s and %s", filter.Param, existing, paramType)
+				}
+			}
+			tool.Filters = append(tool.Filters, filter)
+		}
+	}
+	return tool, nil
+}
+
+// parseRESTToolFilter parses a column=operator.value filter. For a placeholder it also returns
+// the parameter type.
+func parseRESTToolFilter(column, value string) (RESTToolFilter, string, error) {
+	if !restToolIdentifierPattern.MatchString(column) {
+		return RESTToolFilter{}, "", fmt.Errorf("invalid filter column %q", column)
+	}
+	operator, operand, ok := strings.Cut(value, ".")
+	if _, known := restToolOperators[operator]; !ok || !known {
+		return RESTToolFilter{}, "", fmt.Errorf("filter %s must be operator.value with one of eq, neq, gt, gte, lt, lte, like or ilike", column)
+	}
+
+	filter := RESTToolFilter{Column: column, Operator: operator}
+	if m := restToolPlaceholderPattern.FindStringSubmatch(operand); m != nil {
+		paramType := m[2]
+		if paramType == "" {
+			paramType = "string"
+		}
+		if !restToolParamTypes[paramType] {
+			return RESTToolFilter{}, "", fmt.Errorf("parameter %s has unknown type %s", m[1], paramType)
+		}
+		filter.Param = m[1]
+		return filter, paramType, nil
+	}
+	if strings.ContainsAny(operand, "{}") {
+		return RESTToolFilter{}, "", fmt.Errorf("filter %s: placeholders must be the whole value", column)
+	}
+	literal, err := url.QueryUnescape(operand)
+	if err != nil {
+		return RESTToolFilter{}, "", fmt.Errorf("filter %s: %w", column, err)
+	}
+	filter.Value = literal
+	return filter, "", nil
+}
+
+// ToolDefinition returns the function-calling definition of the tool
+func (t *RESTTool) ToolDefinition() Tool {
+	properties := make(map[string]any, len(t.Params))
+	required := make([]string, 0, len(t.Params))
+	for _, param := range t.Params {
+		var columns []string
+		for _, filter := range t.Filters {
+			if filter.Param == param.Name {
+				columns = append(columns, fmt.Sprintf("%s (%s)", filter.Column, filter.Operator))
+			}
+		}
+		properties[param.Name] = map[string]any{
+			"type":        param.Type,
+			"description": fmt.Sprintf("Compared with %s of %s.%s", strings.Join(columns, ", "), t.Schema, t.Table),
+		}
+		required = append(required, param.Name)
+	}
+
+	return Tool{
+		Type: "function",
+		Function: ToolFunction{
+			Name:        t.Name,
+			Description: t.Description,
+			Parameters: map[string]any{
+				"type":                 "object",
+				"properties":           properties,
+				"required":             required,
+				"additionalProperties": false,
+			},
+		},
+	}
+}
+
+// BuildQuery returns the SELECT statement of the tool and its parameters for the arguments of a
+// tool call. Arguments are bound as text, so PostgreSQL converts them to the column types.
+func (t *RESTTool) BuildQuery(rawArgs string) (string, []any, error) {
+	args := map[string]any{}
+	if strings.TrimSpace(rawArgs) != "" {
+		if err := json.Unmarshal([]byte(rawArgs), &args); err != nil {
+			return "", nil, fmt.Errorf("arguments must be a JSON object: %w", err)
+		}
+	}
+
+	values := make(map[string]string, len(t.Params))
+	for _, param := range t.Params {
+		arg, ok := args[param.Name]
+		if !ok || arg == nil {
+			return "", nil, fmt.Errorf("missing argument %s", param.Name)
+		}
+		value, err := restToolArgument(arg, param.Type)
+		if err != nil {
+			return "", nil, fmt.Errorf("argument %s: %w", param.Name, err)
+		}
+		values[param.Name] = value
+	}
+
+	columns := "*"
+	if len(t.Select) > 0 {
+		quoted := make([]string, len(t.Select))
+		for i, col := range t.Select {
+			quoted[i] = query.QuoteIdentifier(col)
+		}
+		columns = strings.Join(quoted, ", ")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "SELECT %s FROM %s", columns, query.QuoteQualifiedName(t.Schema, t.Table))
+
+	var params []any
+	argIndex := 1
+	for i, filter := range t.Filters {
+		if i == 0 {
+			b.WriteString(" WHERE ")
+		} else {
+			b.WriteString(" AND ")
+		}
+		value := filter.Value
+		if filter.Param != "" {
+			value = values[filter.Param]
+		}
+		fmt.Fprintf(&b, "%s %s %s", query.QuoteIdentifier(filter.Column), restToolOperators[filter.Operator], query.Placeholder(&argIndex))
+		params = append(params, value)
+	}
+
+	for i, order := range t.Order {
+		if i == 0 {
+			b.WriteString(" ORDER BY ")
+		} else {
+			b.WriteString(", ")
+		}
+		b.WriteString(query.QuoteIdentifier(order.Column))
+		if order.Desc {
+			b.WriteString(" DESC")
+		}
+	}
+	fmt.Fprintf(&b, " LIMIT %d", t.Limit)
+
+	return b.String(), params, nil
+}
+
+// restToolArgument checks an argument of a tool call against its parameter type and returns it as text
+func restToolArgument(arg any, paramType string) (string, error) {
+	switch paramType {
+	case "integer":
+		switch v := arg.(type) {
+		case float64:
+			if v != float64(int64(v)) {
+				return "", fmt.Errorf("expected an integer")
+			}
+			return strconv.FormatInt(int64(v), 10), nil
+		case string:
+			if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+				return "", fmt.Errorf("expected an integer")
+			}
+			return v, nil
+		}
+		return "", fmt.Errorf("expected an integer")
+	case "number":
+		switch v := arg.(type) {
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case string:
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				return "", fmt.Errorf("expected a number")
+			}
+			return v, nil
+		}
+		return "", fmt.Errorf("expected a number")
+	case "boolean":
+		switch v := arg.(type) {
+		case bool:
+			return strconv.FormatBool(v), nil
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return strconv.FormatBool(b), nil
+			}
+		}
+		return "", fmt.Errorf("expected a boolean")
+	default:
+		switch v := arg.(type) {
+		case string:
+			return v, nil
+		case float64, bool:
+			return fmt.Sprint(v), nil
+		}
+		return "", fmt.Errorf("expected a string")
+	}
+}
+
+// FindRESTTool returns the REST tool of the chatbot with the given name, or nil
+func (c *Chatbot) FindRESTTool(name string) *RESTTool {
+	for i := range c.RESTTools {
+		if c.RESTTools[i].Name == name {
+			return &c.RESTTools[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const restToolsCode = "/**\n" +
+	" * Order bot\n" +
+	" *\n" +
+	" * @fluxbase:rest-tool lookup_order orders?select=id,status,shipped_at&id=eq.{order_id:integer}&limit=1 Look up the status of an order by its ID\n" +
+	" * @fluxbase:rest-tool recent_orders sales.orders?select=id,total&status=neq.cancelled&order=created_at.desc,id&limit=5\n" +
+	" * @fluxbase:rest-tool query_table orders?id=eq.{id} Shadows an MCP tool\n" +
+	" * @fluxbase:rest-tool broken orders?id=contains.{id} Unknown operator\n" +
+	" */\n" +
+	"export default `You are an order assistant.`;\n"
+
+func TestParseChatbotConfig_RESTTools(t *testing.T) {
+	config := ParseChatbotConfig(restToolsCode)
+
+	require.Len(t, config.RESTTools, 2, "tools with reserved names or invalid queries are ignored")
+	assert.Equal(t, RESTTool{
+		Name:        "lookup_order",
+		Description: "Look up the status of an order by its ID",
+		Schema:      "public",
+		Table:       "orders",
+		Select:      []string{"id", "status", "shipped_at"},
+		Filters:     []RESTToolFilter{{Column: "id", Operator: "eq", Param: "order_id"}},
+		Limit:       1,
+		Params:      []RESTToolParam{{Name: "order_id", Type: "integer"}},
+	}, config.RESTTools[0])
+
+	recent := config.RESTTools[1]
+	assert.Equal(t, "Query the sales.orders table", recent.Description)
+	assert.Equal(t, "sales", recent.Schema)
+	assert.Equal(t, []RESTToolFilter{{Column: "status", Operator: "neq", Value: "cancelled"}}, recent.Filters)
+	assert.Equal(t, []RESTToolOrder{{Column: "created_at", Desc: true}, {Column: "id"}}, recent.Order)
+	assert.Empty(t, recent.Params)
+
+	chatbot := &Chatbot{Code: restToolsCode}
+	chatbot.PopulateDerivedFields()
+	assert.NotNil(t, chatbot.FindRESTTool("recent_orders"), "REST tools are re-parsed from code")
+	assert.Nil(t, chatbot.FindRESTTool("query_table"))
+}
+
+func TestParseRESTTool_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"invalid table", "orders;drop"},
+		{"invalid select", "orders?select=id,count(*)"},
+		{"invalid order", "orders?order=id.sideways"},
+		{"limit too high", "orders?limit=1000"},
+		{"filter without operator", "orders?id={id}"},
+		{"unknown parameter type", "orders?id=eq.{id:uuid}"},
+		{"partial placeholder", "orders?name=like.{prefix}%25"},
+		{"conflicting parameter types", "orders?id=eq.{n:integer}&total=gt.{n:number}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseRESTTool("tool", tt.path, "")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRESTTool_ToolDefinition(t *testing.T) {
+	tool, err := parseRESTTool("find_orders", "orders?customer_id=eq.{customer:integer}&total=gte.{min_total:number}", "Find orders")
+	require.NoError(t, err)
+
+	def := tool.ToolDefinition()
+	assert.Equal(t, "function", def.Type)
+	assert.Equal(t, "find_orders", def.Function.Name)
+	assert.Equal(t, "Find orders", def.Function.Description)
+	assert.Equal(t, []string{"customer", "min_total"}, def.Function.Parameters["required"])
+
+	props := def.Function.Parameters["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"type":        "integer",
+		"description": "Compared with customer_id (eq) of public.orders",
+	}, props["customer"])
+	assert.Equal(t, "number", props["min_total"].(map[string]any)["type"])
+}
+
+func TestRESTTool_BuildQuery(t *testing.T) {
+	tool, err := parseRESTTool("lookup_order",
+		"sales.orders?select=id,status&id=eq.{order_id:integer}&status=neq.cancelled%20by%20user&order=created_at.desc&limit=3", "")
+	require.NoError(t, err)
+
+	sql, params, err := tool.BuildQuery(`{"order_id": 42}`)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT "id", "status" FROM "sales"."orders" WHERE "id" = $1 AND "status" <> $2 ORDER BY "created_at" DESC LIMIT 3`, sql)
+	assert.Equal(t, []any{"42", "cancelled by user"}, params)
+
+	_, params, err = tool.BuildQuery(`{"order_id": "42"}`)
+	require.NoError(t, err, "numeric strings are accepted for integers")
+	assert.Equal(t, "42", params[0])
+
+	for _, args := range []string{``, `{}`, `{"order_id": 4.5}`, `{"order_id": "1; drop"}`, `{"order_id": null}`, `[1]`} {
+		_, _, err := tool.BuildQuery(args)
+		assert.Error(t, err, args)
+	}
+}
+
+func TestRestToolArgument(t *testing.T) {
+	tests := []struct {
+		arg       any
+		paramType string
+		want      string
+		wantErr   bool
+	}{
+		{"abc", "string", "abc", false},
+		{float64(7), "string", "7", false},
+		{float64(7), "integer", "7", false},
+		{1.5, "number", "1.5", false},
+		{"2.25", "number", "2.25", false},
+		{true, "boolean", "true", false},
+		{"false", "boolean", "false", false},
+		{"yes", "boolean", "", true},
+		{map[string]any{}, "string", "", true},
+		{"abc", "number", "", true},
+	}
+	for _, tt := range tests {
+		got, err := restToolArgument(tt.arg, tt.paramType)
+		if tt.wantErr {
+			assert.Error(t, err, "%v as %s", tt.arg, tt.paramType)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+}
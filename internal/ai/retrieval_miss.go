@@ -0,0 +1,209 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/nimbleflux/fluxbase/internal/egress"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// RetrievalMissEvent is the event posted to a knowledge base's retrieval miss webhook
+	RetrievalMissEvent = "knowledge_base.retrieval_miss"
+	// retrievalMissTopScores is how many of the best chunks below the threshold a miss reports
+	retrievalMissTopScores = 3
+	// retrievalMissTimeout bounds looking up the best chunks and delivering the webhook
+	retrievalMissTimeout = 15 * time.Second
+)
+
+// ErrInvalidRetrievalMissWebhookURL is returned for a retrieval miss webhook that is not an http(s) URL
+var ErrInvalidRetrievalMissWebhookURL = errors.New("retrieval_miss_webhook_url must be an http or https URL")
+
+// RetrievalMissScore is one of the best chunks of a knowledge base, all below the threshold
+type RetrievalMissScore struct {
+	ChunkID       string  `json:"chunk_id"`
+	DocumentID    string  `json:"document_id"`
+	DocumentTitle string  `json:"document_title,omitempty"`
+	Similarity    float64 `json:"similarity"`
+}
+
+// RetrievalMissPayload is the JSON body posted to a knowledge base's retrieval miss webhook
+type RetrievalMissPayload struct {
+	Event             string               `json:"event"`
+	KnowledgeBaseID   string               `json:"knowledge_base_id"`
+	KnowledgeBaseName string               `json:"knowledge_base_name"`
+	Namespace         string               `json:"namespace"`
+	ChatbotID         string               `json:"chatbot_id"`
+	ConversationID    string               `json:"conversation_id,omitempty"`
+	Query             string               `json:"query"`
+	Threshold         float64              `json:"threshold"`
+	BestSimilarity    *float64             `json:"best_similarity,omitempty"`
+	TopScores         []RetrievalMissScore `json:"top_scores"`
+	CreatedAt         time.Time            `json:"created_at"`
+}
+
+// validateRetrievalMissWebhook checks the retrieval miss webhook of an update request
+func (r UpdateKnowledgeBaseRequest) validateRetrievalMissWebhook() error {
+	if r.RetrievalMissWebhookURL == nil || *r.RetrievalMissWebhookURL == "" {
+		return nil
+	}
+	u, err := url.Parse(*r.RetrievalMissWebhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidRetrievalMissWebhookURL
+	}
+	return nil
+}
+
+// findRetrievalMisses returns the enabled links whose knowledge base contributed no chunk
+func findRetrievalMisses(links []ChatbotKnowledgeBase, chunks []RetrievalResult) []ChatbotKnowledgeBase {
+	hits := make(map[string]bool, len(chunks))
+	for _, chunk := range chunks {
+		hits[chunk.KnowledgeBaseID] = true
+	}
+
+	var misses []ChatbotKnowledgeBase
+	for _, link := range links {
+		if link.Enabled && !hits[link.KnowledgeBaseID] {
+			misses = append(misses, link)
+		}
+	}
+	return misses
+}
+
+// RetrievalMissNotifier posts chatbot queries that found nothing in a knowledge base to
+// the knowledge base's retrieval miss webhook
+type RetrievalMissNotifier struct {
+	storage    *KnowledgeBaseStorage
+	httpClient *http.Client
+}
+
+// NewRetrievalMissNotifier creates a new retrieval miss notifier
+func NewRetrievalMissNotifier(storage *KnowledgeBaseStorage) *RetrievalMissNotifier {
+	return &RetrievalMissNotifier{
+		storage:    storage,
+		httpClient: egress.NewHTTPClient(egress.SourceAI, 10*time.Second),
+	}
+}
+
+// NotifyAsync reports the misses of a retrieval in the background
+func (n *RetrievalMissNotifier) NotifyAsync(opts RetrieveContextOptions, queryEmbedding []float32, chunks []RetrievalResult) {
+	chunks = append([]RetrievalResult(nil), chunks...)
+
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				log.Error().
+					Interface("panic", rec).
+					Str("chatbot_id", opts.ChatbotID).
+					Str("goroutine", "ai_retrieval_miss_notifier").
+					Msg("Panic in retrieval miss notification - recovered")
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), retrievalMissTimeout)
+		defer cancel()
+		n.Notify(ctx, opts, queryEmbedding, chunks)
+	}()
+}
+
+// Notify posts a miss for each knowledge base linked to the chatbot that has a webhook and
+// contributed no chunk to the retrieval
+func (n *RetrievalMissNotifier) Notify(ctx context.Context, opts RetrieveContextOptions, queryEmbedding []float32, chunks []RetrievalResult) {
+	links, err := n.storage.GetChatbotKnowledgeBasesCached(ctx, opts.ChatbotID)
+	if err != nil {
+		log.Warn().Err(err).Str("chatbot_id", opts.ChatbotID).Msg("Failed to load knowledge bases for retrieval miss")
+		return
+	}
+
+	searchOpts := SearchChatbotKnowledgeOptions{MaxChunks: opts.MaxChunks, Threshold: opts.Threshold}
+	if opts.UserID != "" {
+		searchOpts.UserID = &opts.UserID
+	}
+
+	for _, link := range findRetrievalMisses(links, chunks) {
+		kb, err := n.storage.GetKnowledgeBaseCached(ctx, link.KnowledgeBaseID)
+		if err != nil || kb == nil || kb.RetrievalMissWebhookURL == "" {
+			continue
+		}
+
+		_, threshold := link.searchLimits(searchOpts)
+		payload := RetrievalMissPayload{
+			Event:             RetrievalMissEvent,
+			KnowledgeBaseID:   kb.ID,
+			KnowledgeBaseName: kb.Name,
+			Namespace:         kb.Namespace,
+			ChatbotID:         opts.ChatbotID,
+			ConversationID:    opts.ConversationID,
+			Query:             opts.Query,
+			Threshold:         threshold,
+			TopScores:         n.topScores(ctx, &link, queryEmbedding, searchOpts.UserID),
+			CreatedAt:         time.Now(),
+		}
+		if len(payload.TopScores) > 0 {
+			payload.BestSimilarity = &payload.TopScores[0].Similarity
+		}
+
+		if err := n.sendWebhook(ctx, kb.RetrievalMissWebhookURL, &payload); err != nil {
+			log.Warn().Err(err).Str("kb_id", kb.ID).Msg("Failed to deliver retrieval miss webhook")
+		}
+	}
+}
+
+// topScores returns the best chunks the user could have retrieved through the link, ignoring the threshold
+func (n *RetrievalMissNotifier) topScores(ctx context.Context, link *ChatbotKnowledgeBase, queryEmbedding []float32, userID *string) []RetrievalMissScore {
+	var results []RetrievalResult
+	var err error
+	if filter := link.searchFilter(userID); filter != nil {
+		results, err = n.storage.SearchChunksWithFilter(ctx, link.KnowledgeBaseID, queryEmbedding, retrievalMissTopScores, -1, filter)
+	} else {
+		results, err = n.storage.SearchChunks(ctx, link.KnowledgeBaseID, queryEmbedding, retrievalMissTopScores, -1)
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("kb_id", link.KnowledgeBaseID).Msg("Failed to look up scores for retrieval miss")
+	}
+
+	scores := make([]RetrievalMissScore, 0, len(results))
+	for _, result := range results {
+		scores = append(scores, RetrievalMissScore{
+			ChunkID:       result.ChunkID,
+			DocumentID:    result.DocumentID,
+			DocumentTitle: result.DocumentTitle,
+			Similarity:    result.Similarity,
+		})
+	}
+	return scores
+}
+
+// sendWebhook posts a retrieval miss to a knowledge base's webhook
+func (n *RetrievalMissNotifier) sendWebhook(ctx context.Context, webhookURL string, payload *RetrievalMissPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Fluxbase-RetrievalMiss/1.0")
+	req.Header.Set("X-Fluxbase-Event", payload.Event)
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
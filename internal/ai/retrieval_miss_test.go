@@ -0,0 +1,121 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindRetrievalMisses(t *testing.T) {
+	links := []ChatbotKnowledgeBase{
+		{KnowledgeBaseID: "kb-hit", Enabled: true},
+		{KnowledgeBaseID: "kb-miss", Enabled: true},
+		{KnowledgeBaseID: "kb-disabled", Enabled: false},
+	}
+	chunks := []RetrievalResult{
+		{ChunkID: "c1", KnowledgeBaseID: "kb-hit"},
+		{ChunkID: "c2", KnowledgeBaseID: "kb-hit"},
+	}
+
+	misses := findRetrievalMisses(links, chunks)
+	require.Len(t, misses, 1)
+	assert.Equal(t, "kb-miss", misses[0].KnowledgeBaseID)
+
+	assert.Len(t, findRetrievalMisses(links, nil), 2)
+}
+
+func TestUpdateKnowledgeBaseRequest_ValidateRetrievalMissWebhook(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name    string
+		url     *string
+		wantErr bool
+	}{
+		{"unset", nil, false},
+		{"cleared", ptr(""), false},
+		{"https", ptr("https://hooks.example.com/kb-misses"), false},
+		{"http", ptr("http://content-team.internal:8080/misses"), false},
+		{"relative", ptr("/misses"), true},
+		{"other scheme", ptr("ftp://example.com/misses"), true},
+		{"no host", ptr("https://"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := UpdateKnowledgeBaseRequest{RetrievalMissWebhookURL: tt.url}.validateRetrievalMissWebhook()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidRetrievalMissWebhookURL)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestChatbotKnowledgeBase_SearchLimits(t *testing.T) {
+	maxChunks := 8
+	threshold := 0.5
+
+	limit, minSimilarity := (&ChatbotKnowledgeBase{}).searchLimits(SearchChatbotKnowledgeOptions{})
+	assert.Equal(t, 5, limit)
+	assert.Equal(t, 0.7, minSimilarity)
+
+	link := &ChatbotKnowledgeBase{MaxChunks: &maxChunks, SimilarityThreshold: &threshold}
+	limit, minSimilarity = link.searchLimits(SearchChatbotKnowledgeOptions{})
+	assert.Equal(t, 8, limit)
+	assert.Equal(t, 0.5, minSimilarity)
+
+	limit, minSimilarity = link.searchLimits(SearchChatbotKnowledgeOptions{MaxChunks: 2, Threshold: 0.9})
+	assert.Equal(t, 2, limit)
+	assert.Equal(t, 0.9, minSimilarity)
+}
+
+func TestRetrievalMissNotifier_SendWebhook(t *testing.T) {
+	var received RetrievalMissPayload
+	var eventHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		eventHeader = r.Header.Get("X-Fluxbase-Event")
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	best := 0.42
+	payload := &RetrievalMissPayload{
+		Event:           RetrievalMissEvent,
+		KnowledgeBaseID: "kb-1",
+		ChatbotID:       "bot-1",
+		Query:           "How do I export invoices?",
+		Threshold:       0.7,
+		BestSimilarity:  &best,
+		TopScores:       []RetrievalMissScore{{ChunkID: "c1", DocumentID: "d1", Similarity: best}},
+	}
+
+	notifier := NewRetrievalMissNotifier(nil)
+	require.NoError(t, notifier.sendWebhook(context.Background(), server.URL, payload))
+
+	assert.Equal(t, RetrievalMissEvent, eventHeader)
+	assert.Equal(t, "kb-1", received.KnowledgeBaseID)
+	assert.Equal(t, "How do I export invoices?", received.Query)
+	require.NotNil(t, received.BestSimilarity)
+	assert.Equal(t, 0.42, *received.BestSimilarity)
+	require.Len(t, received.TopScores, 1)
+	assert.Equal(t, "c1", received.TopScores[0].ChunkID)
+}
+
+func TestRetrievalMissNotifier_WebhookFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := NewRetrievalMissNotifier(nil).sendWebhook(context.Background(), server.URL, &RetrievalMissPayload{Event: RetrievalMissEvent})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 502")
+}
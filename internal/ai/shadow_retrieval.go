@@ -0,0 +1,661 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// Defaults and limits of shadow retrieval profiles
+const (
+	DefaultShadowSampleRate = 0.1
+	DefaultShadowMaxChunks  = 5
+	DefaultShadowThreshold  = 0.7
+	MaxShadowMaxChunks      = 50
+)
+
+// shadowRunTimeout bounds the shadow retrieval and answer generation of one sampled chat turn
+const shadowRunTimeout = 2 * time.Minute
+
+// maxAnswerSimilarityWords caps the words of each answer compared by answerSimilarity
+const maxAnswerSimilarityWords = 2000
+
+// ErrInvalidShadowProfile is returned when a shadow retrieval profile fails validation
+var ErrInvalidShadowProfile = errors.New("invalid shadow retrieval profile")
+
+// ShadowRetrievalProfile is a retrieval configuration that runs alongside the live one on a sample
+// of a chatbot's chat turns. Its results, and optionally the answer they would have produced, are
+// logged as ShadowRetrievalRuns; users only see the live answer.
+type ShadowRetrievalProfile struct {
+	ChatbotID  string  `json:"chatbot_id"`
+	Enabled    bool    `json:"enabled"`
+	SampleRate float64 `json:"sample_rate"` // Fraction of chat turns with RAG context that also run the profile (0-1)
+
+	Mode                SearchMode `json:"mode"`
+	MaxChunks           int        `json:"max_chunks"`           // Per linked knowledge base
+	SimilarityThreshold float64    `json:"similarity_threshold"` // Applied to the score of the mode
+	SemanticWeight      float64    `json:"semantic_weight"`      // Hybrid mode, 0 = default
+	KeywordBoost        float64    `json:"keyword_boost"`        // Hybrid mode, 0 = default
+	// FreshnessHalfLifeDays overrides the freshness half-life of the links (hybrid and keyword
+	// mode), nil = use the links'
+	FreshnessHalfLifeDays *float64 `json:"freshness_half_life_days,omitempty"`
+
+	// CompareAnswers also generates the answer for the shadow context and compares it to the live one
+	CompareAnswers bool      `json:"compare_answers"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ShadowRetrievalProfileRequest creates or replaces the shadow retrieval profile of a chatbot
+type ShadowRetrievalProfileRequest struct {
+	Enabled               *bool    `json:"enabled,omitempty"` // Default true
+	SampleRate            *float64 `json:"sample_rate,omitempty"`
+	Mode                  string   `json:"mode,omitempty"` // "semantic", "keyword", or "hybrid" (default)
+	MaxChunks             int      `json:"max_chunks,omitempty"`
+	SimilarityThreshold   *float64 `json:"similarity_threshold,omitempty"`
+	SemanticWeight        float64  `json:"semantic_weight,omitempty"`
+	KeywordBoost          float64  `json:"keyword_boost,omitempty"`
+	FreshnessHalfLifeDays *float64 `json:"freshness_half_life_days,omitempty"`
+	CompareAnswers        bool     `json:"compare_answers,omitempty"`
+}
+
+// Profile validates the request and returns the profile it describes, with defaults applied
+func (r *ShadowRetrievalProfileRequest) Profile(chatbotID string) (*ShadowRetrievalProfile, error) {
+	profile := &ShadowRetrievalProfile{
+		ChatbotID:           chatbotID,
+		Enabled:             true,
+		SampleRate:          DefaultShadowSampleRate,
+		Mode:                SearchModeHybrid,
+		MaxChunks:           DefaultShadowMaxChunks,
+		SimilarityThreshold: DefaultShadowThreshold,
+		SemanticWeight:      r.SemanticWeight,
+		KeywordBoost:        r.KeywordBoost,
+		CompareAnswers:      r.CompareAnswers,
+	}
+	if r.Enabled != nil {
+		profile.Enabled = *r.Enabled
+	}
+
+	if r.SampleRate != nil {
+		if *r.SampleRate < 0 || *r.SampleRate > 1 {
+			return nil, fmt.Errorf("%w: sample_rate must be between 0 and 1", ErrInvalidShadowProfile)
+		}
+		profile.SampleRate = *r.SampleRate
+	}
+
+	switch SearchMode(r.Mode) {
+	case "":
+	case SearchModeSemantic, SearchModeKeyword, SearchModeHybrid:
+		profile.Mode = SearchMode(r.Mode)
+	default:
+		return nil, fmt.Errorf("%w: mode must be semantic, keyword or hybrid", ErrInvalidShadowProfile)
+	}
+
+	if r.MaxChunks < 0 || r.MaxChunks > MaxShadowMaxChunks {
+		return nil, fmt.Errorf("%w: max_chunks must be between 1 and %d", ErrInvalidShadowProfile, MaxShadowMaxChunks)
+	}
+	if r.MaxChunks > 0 {
+		profile.MaxChunks = r.MaxChunks
+	}
+
+	if r.SimilarityThreshold != nil {
+		if *r.SimilarityThreshold < 0 {
+			return nil, fmt.Errorf("%w: similarity_threshold must not be negative", ErrInvalidShadowProfile)
+		}
+		profile.SimilarityThreshold = *r.SimilarityThreshold
+	}
+	if r.SemanticWeight < 0 || r.SemanticWeight > 1 {
+		return nil, fmt.Errorf("%w: semantic_weight must be between 0 and 1", ErrInvalidShadowProfile)
+	}
+	if r.KeywordBoost < 0 {
+		return nil, fmt.Errorf("%w: keyword_boost must not be negative", ErrInvalidShadowProfile)
+	}
+
+	halfLife, err := normalizeFreshnessHalfLife(r.FreshnessHalfLifeDays)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidShadowProfile, err.Error())
+	}
+	// Unlike on links, an explicit 0 disables the links' freshness boost for the shadow profile
+	if r.FreshnessHalfLifeDays != nil && halfLife == nil {
+		zero := 0.0
+		halfLife = &zero
+	}
+	profile.FreshnessHalfLifeDays = halfLife
+
+	return profile, nil
+}
+
+// sampled reports whether a chat turn with the given random number in [0, 1) runs the profile
+func (p *ShadowRetrievalProfile) sampled(random float64) bool {
+	return p != nil && p.Enabled && random < p.SampleRate
+}
+
+// searchOptions returns the hybrid search options the profile searches a linked knowledge base with
+func (p *ShadowRetrievalProfile) searchOptions(link *ChatbotKnowledgeBase, query string, embedding []float32, filter *MetadataFilter) HybridSearchOptions {
+	opts := HybridSearchOptions{
+		Query:                 query,
+		QueryEmbedding:        embedding,
+		Limit:                 p.MaxChunks,
+		Threshold:             p.SimilarityThreshold,
+		Mode:                  p.Mode,
+		SemanticWeight:        p.SemanticWeight,
+		KeywordBoost:          p.KeywordBoost,
+		Filter:                filter,
+		FreshnessHalfLifeDays: link.freshnessHalfLife(),
+	}
+	if p.FreshnessHalfLifeDays != nil {
+		opts.FreshnessHalfLifeDays = *p.FreshnessHalfLifeDays
+	}
+	return opts
+}
+
+// ShadowRetrievalRun compares the live and shadow retrieval of one sampled chat turn
+type ShadowRetrievalRun struct {
+	ID             string                 `json:"id"`
+	ChatbotID      string                 `json:"chatbot_id"`
+	ConversationID *string                `json:"conversation_id,omitempty"`
+	QueryText      string                 `json:"query_text"`
+	Profile        ShadowRetrievalProfile `json:"profile"`
+	LiveChunkIDs   []string               `json:"live_chunk_ids"`
+	LiveScores     []float64              `json:"live_scores"`
+	ShadowChunkIDs []string               `json:"shadow_chunk_ids"`
+	ShadowScores   []float64              `json:"shadow_scores"`
+	// Overlap is the fraction of chunks retrieved by both profiles, nil if the shadow retrieval failed
+	Overlap      *float64 `json:"overlap,omitempty"`
+	LiveAnswer   *string  `json:"live_answer,omitempty"`
+	ShadowAnswer *string  `json:"shadow_answer,omitempty"`
+	// AnswerSimilarity is the word-level similarity (0-1) of the live and shadow answers
+	AnswerSimilarity       *float64  `json:"answer_similarity,omitempty"`
+	ShadowPromptTokens     int       `json:"shadow_prompt_tokens"`
+	ShadowCompletionTokens int       `json:"shadow_completion_tokens"`
+	DurationMs             int       `json:"duration_ms"`
+	ErrorMessage           *string   `json:"error_message,omitempty"`
+	CreatedAt              time.Time `json:"created_at"`
+}
+
+// setResults records the chunks retrieved by the live and the shadow profile and their overlap
+func (r *ShadowRetrievalRun) setResults(live, shadow []RetrievalResult) {
+	r.LiveChunkIDs, r.LiveScores = retrievalIDsAndScores(live)
+	r.ShadowChunkIDs, r.ShadowScores = retrievalIDsAndScores(shadow)
+	overlap := chunkOverlap(r.LiveChunkIDs, r.ShadowChunkIDs)
+	r.Overlap = &overlap
+}
+
+// ShadowRetrievalSummary aggregates the shadow retrieval runs of a chatbot
+type ShadowRetrievalSummary struct {
+	Runs int `json:"runs"`
+	// ChangedResults counts the runs in which the shadow profile retrieved different chunks
+	ChangedResults          int      `json:"changed_results"`
+	AverageOverlap          *float64 `json:"average_overlap"`
+	AnswersCompared         int      `json:"answers_compared"`
+	AverageAnswerSimilarity *float64 `json:"average_answer_similarity"`
+	Errors                  int      `json:"errors"`
+}
+
+// retrievalIDsAndScores returns the chunk IDs and similarity scores of retrieval results
+func retrievalIDsAndScores(results []RetrievalResult) ([]string, []float64) {
+	ids := make([]string, len(results))
+	scores := make([]float64, len(results))
+	for i, result := range results {
+		ids[i] = result.ChunkID
+		scores[i] = result.Similarity
+	}
+	return ids, scores
+}
+
+// chunkOverlap returns the fraction of all retrieved chunks that both result lists contain.
+// Two empty lists overlap completely.
+func chunkOverlap(a, b []string) float64 {
+	union := make(map[string]int, len(a)+len(b))
+	for _, id := range a {
+		union[id] |= 1
+	}
+	for _, id := range b {
+		union[id] |= 2
+	}
+	if len(union) == 0 {
+		return 1
+	}
+	both := 0
+	for _, in := range union {
+		if in == 3 {
+			both++
+		}
+	}
+	return float64(both) / float64(len(union))
+}
+
+// answerSimilarity returns how similar two answers are (0-1), from the longest common
+// subsequence of their words: 2 * LCS / (words in a + words in b). Case and punctuation are ignored.
+func answerSimilarity(a, b string) float64 {
+	wordsA, wordsB := answerWords(a), answerWords(b)
+	if len(wordsA) == 0 && len(wordsB) == 0 {
+		return 1
+	}
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 0
+	}
+
+	prev := make([]int, len(wordsB)+1)
+	curr := make([]int, len(wordsB)+1)
+	for i := 1; i <= len(wordsA); i++ {
+		for j := 1; j <= len(wordsB); j++ {
+			switch {
+			case wordsA[i-1] == wordsB[j-1]:
+				curr[j] = prev[j-1] + 1
+			case prev[j] >= curr[j-1]:
+				curr[j] = prev[j]
+			default:
+				curr[j] = curr[j-1]
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return 2 * float64(prev[len(wordsB)]) / float64(len(wordsA)+len(wordsB))
+}
+
+// answerWords splits an answer into lower-case words, capped at maxAnswerSimilarityWords
+func answerWords(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if len(words) > maxAnswerSimilarityWords {
+		words = words[:maxAnswerSimilarityWords]
+	}
+	return words
+}
+
+// ============================================================================
+// SHADOW RETRIEVAL OPERATIONS
+// ============================================================================
+
+// GetShadowRetrievalProfile returns the shadow retrieval profile of a chatbot, nil if it has none
+func (s *KnowledgeBaseStorage) GetShadowRetrievalProfile(ctx context.Context, chatbotID string) (*ShadowRetrievalProfile, error) {
+	var p ShadowRetrievalProfile
+	var mode string
+	err := s.db.QueryRow(ctx, `
+		SELECT chatbot_id, enabled, sample_rate, mode, max_chunks, similarity_threshold,
+			semantic_weight, keyword_boost, freshness_half_life_days, compare_answers,
+			created_at, updated_at
+		FROM ai.chatbot_shadow_retrieval_profiles
+		WHERE chatbot_id = $1
+	`, chatbotID).Scan(
+		&p.ChatbotID, &p.Enabled, &p.SampleRate, &mode, &p.MaxChunks, &p.SimilarityThreshold,
+		&p.SemanticWeight, &p.KeywordBoost, &p.FreshnessHalfLifeDays, &p.CompareAnswers,
+		&p.CreatedAt, &p.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shadow retrieval profile: %w", err)
+	}
+	p.Mode = SearchMode(mode)
+	return &p, nil
+}
+
+// GetShadowRetrievalProfileCached returns the shadow retrieval profile of a chatbot through the cache
+func (s *KnowledgeBaseStorage) GetShadowRetrievalProfileCached(ctx context.Context, chatbotID string) (*ShadowRetrievalProfile, error) {
+	if s.cache == nil {
+		return s.GetShadowRetrievalProfile(ctx, chatbotID)
+	}
+
+	profile, ok, generation := s.cache.getShadowProfile(chatbotID)
+	if ok {
+		return profile, nil
+	}
+
+	profile, err := s.GetShadowRetrievalProfile(ctx, chatbotID)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.setShadowProfile(generation, chatbotID, profile)
+	return profile, nil
+}
+
+// SaveShadowRetrievalProfile creates or replaces the shadow retrieval profile of a chatbot
+func (s *KnowledgeBaseStorage) SaveShadowRetrievalProfile(ctx context.Context, p *ShadowRetrievalProfile) error {
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO ai.chatbot_shadow_retrieval_profiles (
+			chatbot_id, enabled, sample_rate, mode, max_chunks, similarity_threshold,
+			semantic_weight, keyword_boost, freshness_half_life_days, compare_answers
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (chatbot_id) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			sample_rate = EXCLUDED.sample_rate,
+			mode = EXCLUDED.mode,
+			max_chunks = EXCLUDED.max_chunks,
+			similarity_threshold = EXCLUDED.similarity_threshold,
+			semantic_weight = EXCLUDED.semantic_weight,
+			keyword_boost = EXCLUDED.keyword_boost,
+			freshness_half_life_days = EXCLUDED.freshness_half_life_days,
+			compare_answers = EXCLUDED.compare_answers,
+			updated_at = NOW()
+		RETURNING created_at, updated_at
+	`,
+		p.ChatbotID, p.Enabled, p.SampleRate, string(p.Mode), p.MaxChunks, p.SimilarityThreshold,
+		p.SemanticWeight, p.KeywordBoost, p.FreshnessHalfLifeDays, p.CompareAnswers,
+	).Scan(&p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save shadow retrieval profile: %w", err)
+	}
+	if s.cache != nil {
+		s.cache.InvalidateChatbot(ctx, p.ChatbotID)
+	}
+	return nil
+}
+
+// DeleteShadowRetrievalProfile removes the shadow retrieval profile of a chatbot. Its runs are kept.
+// It reports whether the chatbot had a profile.
+func (s *KnowledgeBaseStorage) DeleteShadowRetrievalProfile(ctx context.Context, chatbotID string) (bool, error) {
+	tag, err := s.db.Exec(ctx, `DELETE FROM ai.chatbot_shadow_retrieval_profiles WHERE chatbot_id = $1`, chatbotID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete shadow retrieval profile: %w", err)
+	}
+	if s.cache != nil {
+		s.cache.InvalidateChatbot(ctx, chatbotID)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// CreateShadowRetrievalRun stores the result of a shadow retrieval run
+func (s *KnowledgeBaseStorage) CreateShadowRetrievalRun(ctx context.Context, run *ShadowRetrievalRun) error {
+	if run.ID == "" {
+		run.ID = uuid.New().String()
+	}
+	profile, err := json.Marshal(run.Profile)
+	if err != nil {
+		return fmt.Errorf("failed to encode shadow retrieval profile: %w", err)
+	}
+
+	err = s.db.QueryRow(ctx, `
+		INSERT INTO ai.shadow_retrieval_runs (
+			id, chatbot_id, conversation_id, query_text, profile,
+			live_chunk_ids, live_scores, shadow_chunk_ids, shadow_scores, overlap,
+			live_answer, shadow_answer, answer_similarity,
+			shadow_prompt_tokens, shadow_completion_tokens, duration_ms, error_message
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING created_at
+	`,
+		run.ID, run.ChatbotID, run.ConversationID, run.QueryText, profile,
+		nonNilStrings(run.LiveChunkIDs), nonNilFloats(run.LiveScores),
+		nonNilStrings(run.ShadowChunkIDs), nonNilFloats(run.ShadowScores), run.Overlap,
+		run.LiveAnswer, run.ShadowAnswer, run.AnswerSimilarity,
+		run.ShadowPromptTokens, run.ShadowCompletionTokens, run.DurationMs, run.ErrorMessage,
+	).Scan(&run.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create shadow retrieval run: %w", err)
+	}
+	return nil
+}
+
+// ListShadowRetrievalRuns returns the most recent shadow retrieval runs of a chatbot
+func (s *KnowledgeBaseStorage) ListShadowRetrievalRuns(ctx context.Context, chatbotID string, limit, offset int) ([]ShadowRetrievalRun, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id, chatbot_id, conversation_id, query_text, profile,
+			live_chunk_ids::text[], live_scores, shadow_chunk_ids::text[], shadow_scores, overlap,
+			live_answer, shadow_answer, answer_similarity,
+			shadow_prompt_tokens, shadow_completion_tokens, duration_ms, error_message, created_at
+		FROM ai.shadow_retrieval_runs
+		WHERE chatbot_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, chatbotID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shadow retrieval runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]ShadowRetrievalRun, 0)
+	for rows.Next() {
+		var run ShadowRetrievalRun
+		var profile []byte
+		if err := rows.Scan(
+			&run.ID, &run.ChatbotID, &run.ConversationID, &run.QueryText, &profile,
+			&run.LiveChunkIDs, &run.LiveScores, &run.ShadowChunkIDs, &run.ShadowScores, &run.Overlap,
+			&run.LiveAnswer, &run.ShadowAnswer, &run.AnswerSimilarity,
+			&run.ShadowPromptTokens, &run.ShadowCompletionTokens, &run.DurationMs, &run.ErrorMessage, &run.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan shadow retrieval run: %w", err)
+		}
+		if err := json.Unmarshal(profile, &run.Profile); err != nil {
+			return nil, fmt.Errorf("failed to decode shadow retrieval profile: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// GetShadowRetrievalSummary aggregates all shadow retrieval runs of a chatbot
+func (s *KnowledgeBaseStorage) GetShadowRetrievalSummary(ctx context.Context, chatbotID string) (*ShadowRetrievalSummary, error) {
+	var summary ShadowRetrievalSummary
+	err := s.db.QueryRow(ctx, `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE overlap < 1),
+			AVG(overlap),
+			COUNT(answer_similarity),
+			AVG(answer_similarity),
+			COUNT(error_message)
+		FROM ai.shadow_retrieval_runs
+		WHERE chatbot_id = $1
+	`, chatbotID).Scan(
+		&summary.Runs, &summary.ChangedResults, &summary.AverageOverlap,
+		&summary.AnswersCompared, &summary.AverageAnswerSimilarity, &summary.Errors,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize shadow retrieval runs: %w", err)
+	}
+	return &summary, nil
+}
+
+// nonNilStrings returns s, or an empty slice if it is nil, for NOT NULL array columns
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// nonNilFloats returns f, or an empty slice if it is nil, for NOT NULL array columns
+func nonNilFloats(f []float64) []float64 {
+	if f == nil {
+		return []float64{}
+	}
+	return f
+}
+
+// ============================================================================
+// SHADOW RETRIEVAL
+// ============================================================================
+
+// RetrieveShadow retrieves context for a query from the knowledge bases linked to a chatbot with
+// the settings of a shadow profile instead of the links' own. Link access levels and user isolation
+// apply as for live retrieval. Nothing is written to the retrieval log.
+func (r *RAGService) RetrieveShadow(ctx context.Context, chatbotID, userID, query string, profile *ShadowRetrievalProfile) ([]RetrievalResult, error) {
+	links, err := r.storage.GetChatbotKnowledgeBasesCached(ctx, chatbotID)
+	if err != nil {
+		return nil, err
+	}
+
+	var embedding []float32
+	if profile.Mode != SearchModeKeyword {
+		if r.embeddingService == nil {
+			return nil, fmt.Errorf("embedding service not configured")
+		}
+		embedding, err = r.embeddingService.EmbedSingle(ctx, query, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed query: %w", err)
+		}
+	}
+
+	var user *string
+	if userID != "" {
+		user = &userID
+	}
+
+	var results []RetrievalResult
+	for i := range links {
+		link := &links[i]
+		if !link.Enabled {
+			continue
+		}
+		filter := link.searchFilter(user)
+		opts := profile.searchOptions(link, query, embedding, filter)
+
+		var linkResults []RetrievalResult
+		if opts.Mode == SearchModeSemantic && filter != nil {
+			// Semantic hybrid search does not filter, so isolate users as live retrieval does
+			linkResults, err = r.storage.SearchChunksWithFilter(ctx, link.KnowledgeBaseID, embedding, opts.Limit, opts.Threshold, filter)
+		} else {
+			linkResults, err = r.storage.SearchChunksHybrid(ctx, link.KnowledgeBaseID, opts)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to search knowledge base %s: %w", link.KnowledgeBaseID, err)
+		}
+		for j := range linkResults {
+			linkResults[j].KnowledgeBaseName = link.KnowledgeBaseName
+		}
+		results = append(results, linkResults...)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Similarity > results[j].Similarity
+	})
+	return results, nil
+}
+
+// shadowTurn is the live side of a chat turn that a shadow profile is compared against
+type shadowTurn struct {
+	chatbot        *Chatbot
+	conversationID string
+	userID         string
+	query          string
+	userLanguage   string
+	basePrompt     string    // System prompt without the RAG context
+	history        []Message // Messages before the user message
+	model          string
+	live           *RetrieveContextResult
+	liveAnswer     string
+}
+
+// maybeRunShadowRetrieval runs the chatbot's shadow retrieval profile for a finished chat turn in
+// the background if the turn is sampled. It never affects the live conversation.
+func (h *ChatHandler) maybeRunShadowRetrieval(ctx context.Context, turn shadowTurn) {
+	if h.ragService == nil || turn.live == nil {
+		return
+	}
+	profile, err := h.ragService.storage.GetShadowRetrievalProfileCached(ctx, turn.chatbot.ID)
+	if err != nil {
+		log.Warn().Err(err).Str("chatbot_id", turn.chatbot.ID).Msg("Failed to get shadow retrieval profile")
+		return
+	}
+	if !profile.sampled(rand.Float64()) {
+		return
+	}
+
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				log.Error().
+					Interface("panic", rec).
+					Str("goroutine", "shadow_retrieval").
+					Str("chatbot_id", turn.chatbot.ID).
+					Msg("Panic in shadow retrieval - recovered")
+			}
+		}()
+
+		runCtx, cancel := context.WithTimeout(context.Background(), shadowRunTimeout)
+		defer cancel()
+		h.runShadowRetrieval(runCtx, profile, turn)
+	}()
+}
+
+// runShadowRetrieval retrieves with the shadow profile, optionally generates the shadow answer,
+// and stores the comparison with the live turn
+func (h *ChatHandler) runShadowRetrieval(ctx context.Context, profile *ShadowRetrievalProfile, turn shadowTurn) {
+	start := time.Now()
+	run := &ShadowRetrievalRun{
+		ChatbotID:      turn.chatbot.ID,
+		ConversationID: optString(turn.conversationID),
+		QueryText:      turn.query,
+		Profile:        *profile,
+	}
+
+	shadow, err := h.ragService.RetrieveShadow(ctx, turn.chatbot.ID, turn.userID, turn.query, profile)
+	if err != nil {
+		run.LiveChunkIDs, run.LiveScores = retrievalIDsAndScores(turn.live.Chunks)
+		run.ErrorMessage = optString(err.Error())
+	} else {
+		run.setResults(turn.live.Chunks, shadow)
+		if profile.CompareAnswers && turn.liveAnswer != "" {
+			if err := h.compareShadowAnswer(ctx, run, turn, shadow); err != nil {
+				run.ErrorMessage = optString(err.Error())
+			}
+		}
+	}
+	run.DurationMs = int(time.Since(start).Milliseconds())
+
+	if err := h.ragService.storage.CreateShadowRetrievalRun(ctx, run); err != nil {
+		log.Warn().Err(err).Str("chatbot_id", turn.chatbot.ID).Msg("Failed to store shadow retrieval run")
+		return
+	}
+	log.Debug().
+		Str("chatbot_id", turn.chatbot.ID).
+		Str("conversation_id", turn.conversationID).
+		Int("live_chunks", len(run.LiveChunkIDs)).
+		Int("shadow_chunks", len(run.ShadowChunkIDs)).
+		Int("duration_ms", run.DurationMs).
+		Msg("Shadow retrieval run completed")
+}
+
+// compareShadowAnswer generates the answer the live prompt would have produced with the shadow
+// context and records it next to the live answer. Tools are not offered, so the shadow answer
+// relies on the retrieved context alone.
+func (h *ChatHandler) compareShadowAnswer(ctx context.Context, run *ShadowRetrievalRun, turn shadowTurn, shadow []RetrievalResult) error {
+	provider, err := h.getProvider(ctx, turn.chatbot)
+	if err != nil {
+		return fmt.Errorf("failed to get provider: %w", err)
+	}
+
+	systemPrompt := turn.basePrompt
+	if len(shadow) > 0 {
+		systemPrompt = systemPrompt + "\n\n" + formatContextForLanguage(shadow, turn.userLanguage)
+	}
+	messages := append([]Message{{Role: RoleSystem, Content: systemPrompt}}, turn.history...)
+	messages = append(messages, Message{Role: RoleUser, Content: turn.query})
+
+	resp, err := provider.Chat(ctx, &ChatRequest{
+		Model:       turn.model,
+		Messages:    messages,
+		MaxTokens:   turn.chatbot.MaxTokens,
+		Temperature: turn.chatbot.Temperature,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate shadow answer: %w", err)
+	}
+	if resp.Usage != nil {
+		run.ShadowPromptTokens = resp.Usage.PromptTokens
+		run.ShadowCompletionTokens = resp.Usage.CompletionTokens
+	}
+	if len(resp.Choices) == 0 {
+		return fmt.Errorf("shadow answer is empty")
+	}
+
+	shadowAnswer := resp.Choices[0].Message.Content
+	similarity := answerSimilarity(turn.liveAnswer, shadowAnswer)
+	run.LiveAnswer = &turn.liveAnswer
+	run.ShadowAnswer = &shadowAnswer
+	run.AnswerSimilarity = &similarity
+	return nil
+}
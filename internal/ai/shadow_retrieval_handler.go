@@ -0,0 +1,105 @@
+package ai
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/rs/zerolog/log"
+)
+
+// ============================================================================
+// SHADOW RETRIEVAL ENDPOINTS
+// ============================================================================
+
+// GetShadowRetrievalProfile returns the shadow retrieval profile of a chatbot
+// GET /api/v1/admin/ai/chatbots/:id/shadow-retrieval
+func (h *KnowledgeBaseHandler) GetShadowRetrievalProfile(c fiber.Ctx) error {
+	chatbotID := c.Params("id")
+
+	profile, err := h.storage.GetShadowRetrievalProfile(c.RequestCtx(), chatbotID)
+	if err != nil {
+		log.Error().Err(err).Str("chatbot_id", chatbotID).Msg("Failed to get shadow retrieval profile")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get shadow retrieval profile")
+	}
+	if profile == nil {
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Chatbot has no shadow retrieval profile")
+	}
+
+	return c.JSON(profile)
+}
+
+// SetShadowRetrievalProfile creates or replaces the shadow retrieval profile of a chatbot
+// PUT /api/v1/admin/ai/chatbots/:id/shadow-retrieval
+func (h *KnowledgeBaseHandler) SetShadowRetrievalProfile(c fiber.Ctx) error {
+	chatbotID := c.Params("id")
+
+	var req ShadowRetrievalProfileRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+	}
+
+	profile, err := req.Profile(chatbotID)
+	if err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
+	}
+
+	err = h.storage.SaveShadowRetrievalProfile(c.RequestCtx(), profile)
+	if database.IsForeignKeyViolation(err) {
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Chatbot not found")
+	}
+	if err != nil {
+		log.Error().Err(err).Str("chatbot_id", chatbotID).Msg("Failed to save shadow retrieval profile")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to save shadow retrieval profile")
+	}
+
+	return c.JSON(profile)
+}
+
+// DeleteShadowRetrievalProfile stops shadow retrieval for a chatbot. Recorded runs are kept.
+// DELETE /api/v1/admin/ai/chatbots/:id/shadow-retrieval
+func (h *KnowledgeBaseHandler) DeleteShadowRetrievalProfile(c fiber.Ctx) error {
+	chatbotID := c.Params("id")
+
+	deleted, err := h.storage.DeleteShadowRetrievalProfile(c.RequestCtx(), chatbotID)
+	if err != nil {
+		log.Error().Err(err).Str("chatbot_id", chatbotID).Msg("Failed to delete shadow retrieval profile")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to delete shadow retrieval profile")
+	}
+	if !deleted {
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Chatbot has no shadow retrieval profile")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListShadowRetrievalRuns returns the recent shadow retrieval runs of a chatbot and a summary of all runs
+// GET /api/v1/admin/ai/chatbots/:id/shadow-retrieval/runs?limit=50&offset=0
+func (h *KnowledgeBaseHandler) ListShadowRetrievalRuns(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+	chatbotID := c.Params("id")
+
+	limit := fiber.Query[int](c, "limit", 50)
+	offset := fiber.Query[int](c, "offset", 0)
+	if limit <= 0 || limit > 500 || offset < 0 {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "limit must be between 1 and 500 and offset must not be negative")
+	}
+
+	runs, err := h.storage.ListShadowRetrievalRuns(ctx, chatbotID, limit, offset)
+	if err != nil {
+		log.Error().Err(err).Str("chatbot_id", chatbotID).Msg("Failed to list shadow retrieval runs")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list shadow retrieval runs")
+	}
+
+	summary, err := h.storage.GetShadowRetrievalSummary(ctx, chatbotID)
+	if err != nil {
+		log.Error().Err(err).Str("chatbot_id", chatbotID).Msg("Failed to summarize shadow retrieval runs")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list shadow retrieval runs")
+	}
+
+	return c.JSON(fiber.Map{
+		"runs":    runs,
+		"summary": summary,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
@@ -0,0 +1,173 @@
+package ai
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShadowRetrievalProfileRequest_Profile(t *testing.T) {
+	t.Run("applies defaults", func(t *testing.T) {
+		profile, err := (&ShadowRetrievalProfileRequest{}).Profile("bot-1")
+		require.NoError(t, err)
+
+		assert.Equal(t, "bot-1", profile.ChatbotID)
+		assert.True(t, profile.Enabled)
+		assert.Equal(t, DefaultShadowSampleRate, profile.SampleRate)
+		assert.Equal(t, SearchModeHybrid, profile.Mode)
+		assert.Equal(t, DefaultShadowMaxChunks, profile.MaxChunks)
+		assert.Equal(t, DefaultShadowThreshold, profile.SimilarityThreshold)
+		assert.Nil(t, profile.FreshnessHalfLifeDays)
+	})
+
+	t.Run("keeps explicit values", func(t *testing.T) {
+		disabled, rate, threshold, halfLife := false, 0.0, 0.0, 0.0
+		profile, err := (&ShadowRetrievalProfileRequest{
+			Enabled:               &disabled,
+			SampleRate:            &rate,
+			Mode:                  "keyword",
+			MaxChunks:             8,
+			SimilarityThreshold:   &threshold,
+			FreshnessHalfLifeDays: &halfLife,
+			CompareAnswers:        true,
+		}).Profile("bot-1")
+		require.NoError(t, err)
+
+		assert.False(t, profile.Enabled)
+		assert.Zero(t, profile.SampleRate)
+		assert.Equal(t, SearchModeKeyword, profile.Mode)
+		assert.Equal(t, 8, profile.MaxChunks)
+		assert.Zero(t, profile.SimilarityThreshold)
+		require.NotNil(t, profile.FreshnessHalfLifeDays, "0 turns the links' freshness boost off")
+		assert.Zero(t, *profile.FreshnessHalfLifeDays)
+		assert.True(t, profile.CompareAnswers)
+	})
+
+	invalid := map[string]ShadowRetrievalProfileRequest{
+		"sample rate above 1":   {SampleRate: ptrFloat(1.5)},
+		"unknown mode":          {Mode: "fuzzy"},
+		"too many chunks":       {MaxChunks: MaxShadowMaxChunks + 1},
+		"negative threshold":    {SimilarityThreshold: ptrFloat(-0.1)},
+		"semantic weight":       {SemanticWeight: 2},
+		"negative boost":        {KeywordBoost: -1},
+		"negative freshness":    {FreshnessHalfLifeDays: ptrFloat(-3)},
+		"negative sample rate":  {SampleRate: ptrFloat(-0.5)},
+		"negative chunk budget": {MaxChunks: -1},
+	}
+	for name, req := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := req.Profile("bot-1")
+			assert.ErrorIs(t, err, ErrInvalidShadowProfile)
+		})
+	}
+}
+
+func ptrFloat(f float64) *float64 {
+	return &f
+}
+
+func TestShadowRetrievalProfile_Sampled(t *testing.T) {
+	var none *ShadowRetrievalProfile
+	assert.False(t, none.sampled(0))
+
+	profile := &ShadowRetrievalProfile{Enabled: true, SampleRate: 0.25}
+	assert.True(t, profile.sampled(0.1))
+	assert.False(t, profile.sampled(0.25))
+
+	profile.Enabled = false
+	assert.False(t, profile.sampled(0.1))
+}
+
+func TestShadowRetrievalProfile_SearchOptions(t *testing.T) {
+	linkHalfLife := 30.0
+	link := &ChatbotKnowledgeBase{FreshnessHalfLifeDays: &linkHalfLife}
+	profile := &ShadowRetrievalProfile{Mode: SearchModeHybrid, MaxChunks: 4, SimilarityThreshold: 0.3, SemanticWeight: 0.6}
+
+	opts := profile.searchOptions(link, "refund policy", nil, nil)
+	assert.Equal(t, "refund policy", opts.Query)
+	assert.Equal(t, 4, opts.Limit)
+	assert.Equal(t, 0.3, opts.Threshold)
+	assert.Equal(t, 0.6, opts.SemanticWeight)
+	assert.Equal(t, 30.0, opts.FreshnessHalfLifeDays, "inherits the link's half-life")
+
+	profile.FreshnessHalfLifeDays = ptrFloat(0)
+	opts = profile.searchOptions(link, "refund policy", nil, nil)
+	assert.Zero(t, opts.FreshnessHalfLifeDays, "the profile overrides the link")
+}
+
+func TestChunkOverlap(t *testing.T) {
+	assert.Equal(t, 1.0, chunkOverlap(nil, nil))
+	assert.Equal(t, 1.0, chunkOverlap([]string{"a", "b"}, []string{"b", "a"}))
+	assert.Equal(t, 0.0, chunkOverlap([]string{"a"}, nil))
+	assert.InDelta(t, 1.0/3, chunkOverlap([]string{"a", "b"}, []string{"b", "c"}), 1e-9)
+}
+
+func TestAnswerSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, answerSimilarity("", ""))
+	assert.Equal(t, 1.0, answerSimilarity("Refunds take 5 days.", "refunds take 5 days"))
+	assert.Equal(t, 0.0, answerSimilarity("Refunds take 5 days.", ""))
+	assert.Equal(t, 0.0, answerSimilarity("yes", "no"))
+	// LCS "refunds take days" = 3 of 4 + 4 words
+	assert.InDelta(t, 0.75, answerSimilarity("refunds take 5 days", "refunds take 10 days"), 1e-9)
+
+	long := strings.Repeat("word ", maxAnswerSimilarityWords+100)
+	assert.Equal(t, 1.0, answerSimilarity(long, long))
+}
+
+func TestShadowRetrievalRun_SetResults(t *testing.T) {
+	run := &ShadowRetrievalRun{}
+	run.setResults(
+		[]RetrievalResult{{ChunkID: "a", Similarity: 0.9}, {ChunkID: "b", Similarity: 0.8}},
+		[]RetrievalResult{{ChunkID: "b", Similarity: 0.85}},
+	)
+
+	assert.Equal(t, []string{"a", "b"}, run.LiveChunkIDs)
+	assert.Equal(t, []float64{0.9, 0.8}, run.LiveScores)
+	assert.Equal(t, []string{"b"}, run.ShadowChunkIDs)
+	assert.Equal(t, []float64{0.85}, run.ShadowScores)
+	require.NotNil(t, run.Overlap)
+	assert.Equal(t, 0.5, *run.Overlap)
+}
+
+func TestKnowledgeBaseCache_ShadowProfiles(t *testing.T) {
+	cache := NewKnowledgeBaseCache(time.Minute)
+
+	_, ok, generation := cache.getShadowProfile("bot-1")
+	assert.False(t, ok)
+
+	cache.setShadowProfile(generation, "bot-1", &ShadowRetrievalProfile{ChatbotID: "bot-1", SampleRate: 0.5})
+	cache.setShadowProfile(generation, "bot-2", nil)
+
+	profile, ok, _ := cache.getShadowProfile("bot-1")
+	require.True(t, ok)
+	require.NotNil(t, profile)
+	assert.Equal(t, 0.5, profile.SampleRate)
+
+	profile, ok, _ = cache.getShadowProfile("bot-2")
+	assert.True(t, ok, "chatbots without a profile are cached too")
+	assert.Nil(t, profile)
+
+	cache.InvalidateChatbot(context.Background(), "bot-1")
+	_, ok, _ = cache.getShadowProfile("bot-1")
+	assert.False(t, ok)
+	_, ok, _ = cache.getShadowProfile("bot-2")
+	assert.True(t, ok)
+}
+
+func TestKnowledgeBaseHandler_SetShadowRetrievalProfileValidates(t *testing.T) {
+	app := fiber.New()
+	handler := NewKnowledgeBaseHandler(&KnowledgeBaseStorage{}, nil)
+	app.Put("/chatbots/:id/shadow-retrieval", handler.SetShadowRetrievalProfile)
+
+	req := httptest.NewRequest("PUT", "/chatbots/bot-1/shadow-retrieval", strings.NewReader(`{"sample_rate": 2}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
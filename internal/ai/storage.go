@@ -1119,19 +1119,22 @@ func (s *Storage) SetEmbeddingProviderPreference(ctx context.Context, id string)
 
 // UserConversationSummary represents a conversation for the user API (not admin)
 type UserConversationSummary struct {
-	ID           string    `json:"id"`
-	ChatbotName  string    `json:"chatbot"`
-	Namespace    string    `json:"namespace"`
-	Title        *string   `json:"title"`
-	Preview      string    `json:"preview"`
-	MessageCount int       `json:"message_count"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           string            `json:"id"`
+	ChatbotName  string            `json:"chatbot"`
+	Namespace    string            `json:"namespace"`
+	Title        *string           `json:"title"`
+	Preview      string            `json:"preview"`
+	MessageCount int               `json:"message_count"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	Metadata     map[string]string `json:"metadata"`
+	Tags         []string          `json:"tags"`
 }
 
 // UserMessageDetail represents a message in user API response
 type UserMessageDetail struct {
 	ID           string            `json:"id"`
+	Sequence     int               `json:"sequence"`
 	Role         string            `json:"role"`
 	Content      string            `json:"content"`
 	Timestamp    time.Time         `json:"timestamp"`
@@ -1156,13 +1159,16 @@ type UserUsageStats struct {
 
 // UserConversationDetail represents a full conversation with messages for user API
 type UserConversationDetail struct {
-	ID          string              `json:"id"`
-	ChatbotName string              `json:"chatbot"`
-	Namespace   string              `json:"namespace"`
-	Title       *string             `json:"title"`
-	CreatedAt   time.Time           `json:"created_at"`
-	UpdatedAt   time.Time           `json:"updated_at"`
-	Messages    []UserMessageDetail `json:"messages"`
+	ID               string              `json:"id"`
+	ChatbotName      string              `json:"chatbot"`
+	Namespace        string              `json:"namespace"`
+	Title            *string             `json:"title"`
+	CreatedAt        time.Time           `json:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at"`
+	LastReadSequence int                 `json:"last_read_sequence"`
+	Metadata         map[string]string   `json:"metadata"`
+	Tags             []string            `json:"tags"`
+	Messages         []UserMessageDetail `json:"messages"`
 }
 
 // ListUserConversationsOptions contains options for listing user conversations
@@ -1170,6 +1176,7 @@ type ListUserConversationsOptions struct {
 	UserID      string
 	ChatbotName *string
 	Namespace   *string
+	Filter      ConversationFilter
 	Limit       int
 	Offset      int
 }
@@ -1206,7 +1213,9 @@ func (s *Storage) ListUserConversations(ctx context.Context, opts ListUserConver
 			COALESCE(cp.preview, '') AS preview,
 			COALESCE(cc.message_count, 0) AS message_count,
 			c.created_at,
-			c.updated_at
+			c.updated_at,
+			c.metadata,
+			c.tags
 		FROM ai.conversations c
 		LEFT JOIN ai.chatbots cb ON cb.id = c.chatbot_id
 		LEFT JOIN conv_preview cp ON cp.conversation_id = c.id
@@ -1226,9 +1235,11 @@ func (s *Storage) ListUserConversations(ctx context.Context, opts ListUserConver
 	if opts.Namespace != nil {
 		query += fmt.Sprintf(" AND cb.namespace = $%d", argIndex)
 		args = append(args, *opts.Namespace)
-		argIndex++
 	}
 
+	query, args = opts.Filter.appendTo(query, "c", args)
+	argIndex = len(args) + 1
+
 	query += fmt.Sprintf(" ORDER BY c.updated_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
 	args = append(args, opts.Limit, opts.Offset)
 
@@ -1250,6 +1261,8 @@ func (s *Storage) ListUserConversations(ctx context.Context, opts ListUserConver
 			&conv.MessageCount,
 			&conv.CreatedAt,
 			&conv.UpdatedAt,
+			&conv.Metadata,
+			&conv.Tags,
 		)
 		if err != nil {
 			log.Error().Err(err).Msg("Failed to scan conversation")
@@ -1278,6 +1291,7 @@ func (s *Storage) ListUserConversations(ctx context.Context, opts ListUserConver
 		countQuery += fmt.Sprintf(" AND cb.namespace = $%d", countArgIndex)
 		countArgs = append(countArgs, *opts.Namespace)
 	}
+	countQuery, countArgs = opts.Filter.appendTo(countQuery, "c", countArgs)
 
 	var total int
 	err = s.db.QueryRow(ctx, countQuery, countArgs...).Scan(&total)
@@ -1308,7 +1322,10 @@ func (s *Storage) GetUserConversation(ctx context.Context, userID, conversationI
 			cb.namespace,
 			c.title,
 			c.created_at,
-			c.updated_at
+			c.updated_at,
+			c.last_read_sequence,
+			c.metadata,
+			c.tags
 		FROM ai.conversations c
 		LEFT JOIN ai.chatbots cb ON cb.id = c.chatbot_id
 		WHERE c.id = $1 AND c.user_id = $2 AND c.status = 'active'
@@ -1322,6 +1339,9 @@ func (s *Storage) GetUserConversation(ctx context.Context, userID, conversationI
 		&conv.Title,
 		&conv.CreatedAt,
 		&conv.UpdatedAt,
+		&conv.LastReadSequence,
+		&conv.Metadata,
+		&conv.Tags,
 	)
 
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -1335,6 +1355,7 @@ func (s *Storage) GetUserConversation(ctx context.Context, userID, conversationI
 	msgQuery := `
 		SELECT
 			id,
+			sequence_number,
 			role,
 			content,
 			query_results,
@@ -1367,6 +1388,7 @@ func (s *Storage) GetUserConversation(ctx context.Context, userID, conversationI
 
 		err := rows.Scan(
 			&msg.ID,
+			&msg.Sequence,
 			&msg.Role,
 			&msg.Content,
 			&queryResultsJSON,
@@ -1448,6 +1470,11 @@ func (s *Storage) DeleteUserConversation(ctx context.Context, userID, conversati
 		return fmt.Errorf("conversation not found")
 	}
 
+	// Drop search embeddings right away instead of waiting for the indexer's purge
+	if _, err := s.db.Exec(ctx, `DELETE FROM ai.message_embeddings WHERE conversation_id = $1`, conversationID); err != nil {
+		log.Warn().Err(err).Str("conversation_id", conversationID).Msg("Failed to delete conversation embeddings")
+	}
+
 	log.Info().
 		Str("conversation_id", conversationID).
 		Str("user_id", userID).
@@ -1456,6 +1483,33 @@ func (s *Storage) DeleteUserConversation(ctx context.Context, userID, conversati
 	return nil
 }
 
+// GetConversationReadSequence returns the sequence number of the last message read in a conversation
+func (s *Storage) GetConversationReadSequence(ctx context.Context, conversationID string) (int, error) {
+	var sequence int
+	err := s.db.QueryRow(ctx, `SELECT last_read_sequence FROM ai.conversations WHERE id = $1`, conversationID).Scan(&sequence)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get conversation read sequence: %w", err)
+	}
+	return sequence, nil
+}
+
+// MarkConversationRead advances the read position of a conversation. It never moves backwards.
+func (s *Storage) MarkConversationRead(ctx context.Context, conversationID string, sequence int) error {
+	query := `
+		UPDATE ai.conversations
+		SET last_read_sequence = GREATEST(last_read_sequence, $2)
+		WHERE id = $1
+	`
+
+	if _, err := s.db.Exec(ctx, query, conversationID, sequence); err != nil {
+		return fmt.Errorf("failed to mark conversation read: %w", err)
+	}
+	return nil
+}
+
 // UpdateConversationTitle updates the title of a conversation owned by the user
 func (s *Storage) UpdateConversationTitle(ctx context.Context, userID, conversationID, title string) error {
 	query := `
@@ -1496,3 +1550,215 @@ func (s *Storage) SetConversationTitle(ctx context.Context, conversationID, titl
 
 	return nil
 }
+
+// ============================================================================
+// ESCALATION OPERATIONS
+// ============================================================================
+
+// escalationColumns is the column list shared by escalation queries
+const escalationColumns = `
+	e.id, e.conversation_id, e.chatbot_id, cb.name, e.user_id, e.reason,
+	e.trigger_message, e.best_similarity, e.transcript, e.status, e.notes,
+	e.webhook_delivered_at, e.email_delivered_at, e.notification_error,
+	e.resolved_by, e.resolved_at, e.created_at, e.updated_at
+`
+
+// ListEscalationsOptions contains filters for listing escalations
+type ListEscalationsOptions struct {
+	ChatbotID      *string
+	ConversationID *string
+	Status         *EscalationStatus
+	Limit          int
+	Offset         int
+}
+
+// CreateEscalation stores a new escalation record
+func (s *Storage) CreateEscalation(ctx context.Context, escalation *Escalation) error {
+	transcriptJSON, err := json.Marshal(escalation.Transcript)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transcript: %w", err)
+	}
+
+	if escalation.Status == "" {
+		escalation.Status = EscalationStatusOpen
+	}
+
+	query := `
+		INSERT INTO ai.conversation_escalations (
+			conversation_id, chatbot_id, user_id, reason, trigger_message,
+			best_similarity, transcript, status
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = s.db.QueryRow(ctx, query,
+		escalation.ConversationID, escalation.ChatbotID, escalation.UserID, escalation.Reason,
+		escalation.TriggerMessage, escalation.BestSimilarity, transcriptJSON, escalation.Status,
+	).Scan(&escalation.ID, &escalation.CreatedAt, &escalation.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create escalation: %w", err)
+	}
+
+	log.Info().
+		Str("escalation_id", escalation.ID).
+		Str("conversation_id", escalation.ConversationID).
+		Str("reason", string(escalation.Reason)).
+		Msg("Conversation escalated")
+
+	return nil
+}
+
+// GetEscalation retrieves an escalation by ID
+func (s *Storage) GetEscalation(ctx context.Context, id string) (*Escalation, error) {
+	query := `SELECT ` + escalationColumns + `
+		FROM ai.conversation_escalations e
+		LEFT JOIN ai.chatbots cb ON cb.id = e.chatbot_id
+		WHERE e.id = $1
+	`
+
+	escalation, err := scanEscalation(s.db.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get escalation: %w", err)
+	}
+	return escalation, nil
+}
+
+// GetOpenEscalation returns the unresolved escalation for a conversation, if any
+func (s *Storage) GetOpenEscalation(ctx context.Context, conversationID string) (*Escalation, error) {
+	query := `SELECT ` + escalationColumns + `
+		FROM ai.conversation_escalations e
+		LEFT JOIN ai.chatbots cb ON cb.id = e.chatbot_id
+		WHERE e.conversation_id = $1 AND e.status <> 'resolved'
+		ORDER BY e.created_at DESC
+		LIMIT 1
+	`
+
+	escalation, err := scanEscalation(s.db.QueryRow(ctx, query, conversationID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open escalation: %w", err)
+	}
+	return escalation, nil
+}
+
+// ListEscalations lists escalations with optional filters, newest first
+func (s *Storage) ListEscalations(ctx context.Context, opts ListEscalationsOptions) ([]*Escalation, int, error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	argIndex := 1
+
+	if opts.ChatbotID != nil {
+		where += fmt.Sprintf(" AND e.chatbot_id = $%d", argIndex)
+		args = append(args, *opts.ChatbotID)
+		argIndex++
+	}
+	if opts.ConversationID != nil {
+		where += fmt.Sprintf(" AND e.conversation_id = $%d", argIndex)
+		args = append(args, *opts.ConversationID)
+		argIndex++
+	}
+	if opts.Status != nil {
+		where += fmt.Sprintf(" AND e.status = $%d", argIndex)
+		args = append(args, string(*opts.Status))
+		argIndex++
+	}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM ai.conversation_escalations e` + where
+	if err := s.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count escalations: %w", err)
+	}
+
+	query := `SELECT ` + escalationColumns + `
+		FROM ai.conversation_escalations e
+		LEFT JOIN ai.chatbots cb ON cb.id = e.chatbot_id` + where +
+		fmt.Sprintf(" ORDER BY e.created_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, opts.Limit, opts.Offset)
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list escalations: %w", err)
+	}
+	defer rows.Close()
+
+	escalations := make([]*Escalation, 0)
+	for rows.Next() {
+		escalation, err := scanEscalation(rows)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to scan escalation")
+			continue
+		}
+		escalations = append(escalations, escalation)
+	}
+
+	return escalations, total, nil
+}
+
+// UpdateEscalationStatus changes the status of an escalation. Resolving an
+// escalation hands the conversation back to the chatbot.
+func (s *Storage) UpdateEscalationStatus(ctx context.Context, id string, status EscalationStatus, notes *string, resolvedBy *string) error {
+	query := `
+		UPDATE ai.conversation_escalations SET
+			status = $2,
+			notes = COALESCE($3, notes),
+			resolved_by = CASE WHEN $2 = 'resolved' THEN $4::uuid ELSE NULL END,
+			resolved_at = CASE WHEN $2 = 'resolved' THEN NOW() ELSE NULL END
+		WHERE id = $1
+	`
+
+	result, err := s.db.Exec(ctx, query, id, string(status), notes, resolvedBy)
+	if err != nil {
+		return fmt.Errorf("failed to update escalation: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("escalation not found: %s", id)
+	}
+	return nil
+}
+
+// RecordEscalationDelivery stores the outcome of escalation notifications
+func (s *Storage) RecordEscalationDelivery(ctx context.Context, id string, webhookAt, emailAt *time.Time, notificationError *string) error {
+	query := `
+		UPDATE ai.conversation_escalations SET
+			webhook_delivered_at = $2,
+			email_delivered_at = $3,
+			notification_error = $4
+		WHERE id = $1
+	`
+
+	if _, err := s.db.Exec(ctx, query, id, webhookAt, emailAt, notificationError); err != nil {
+		return fmt.Errorf("failed to record escalation delivery: %w", err)
+	}
+	return nil
+}
+
+// scanEscalation scans an escalation row selected with escalationColumns
+func scanEscalation(row pgx.Row) (*Escalation, error) {
+	escalation := &Escalation{}
+	var transcriptJSON []byte
+	err := row.Scan(
+		&escalation.ID, &escalation.ConversationID, &escalation.ChatbotID, &escalation.ChatbotName,
+		&escalation.UserID, &escalation.Reason, &escalation.TriggerMessage, &escalation.BestSimilarity,
+		&transcriptJSON, &escalation.Status, &escalation.Notes,
+		&escalation.WebhookDeliveredAt, &escalation.EmailDeliveredAt, &escalation.NotificationError,
+		&escalation.ResolvedBy, &escalation.ResolvedAt, &escalation.CreatedAt, &escalation.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(transcriptJSON) > 0 {
+		if err := json.Unmarshal(transcriptJSON, &escalation.Transcript); err != nil {
+			log.Warn().Err(err).Str("escalation_id", escalation.ID).Msg("Failed to unmarshal escalation transcript")
+		}
+	}
+	if escalation.Transcript == nil {
+		escalation.Transcript = []EscalationRecord{}
+	}
+	return escalation, nil
+}
@@ -57,12 +57,17 @@ func (e *TextExtractor) Extract(data []byte, mimeType string) (string, error) {
 
 // ExtractWithLanguages extracts text from a document with optional OCR language hints
 func (e *TextExtractor) ExtractWithLanguages(data []byte, mimeType string, languages []string) (string, error) {
+	return e.ExtractWithOptions(context.Background(), data, mimeType, ProcessingOptions{OCRLanguages: languages})
+}
+
+// ExtractWithOptions extracts text from a document as directed by its processing options
+func (e *TextExtractor) ExtractWithOptions(ctx context.Context, data []byte, mimeType string, opts ProcessingOptions) (string, error) {
 	var text string
 	var err error
 
 	switch mimeType {
 	case "application/pdf":
-		text, err = e.ExtractFromPDFWithLanguages(data, languages)
+		text, err = e.extractPDF(ctx, data, opts)
 	case "application/vnd.openxmlformats-officedocument.wordprocessingml.document":
 		text, err = e.ExtractFromDOCX(data)
 	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
@@ -118,13 +123,24 @@ func (e *TextExtractor) ExtractFromPDF(data []byte) (string, error) {
 // ExtractFromPDFWithLanguages extracts text from PDF documents with OCR fallback
 // If standard text extraction returns garbage/binary data, it falls back to OCR
 func (e *TextExtractor) ExtractFromPDFWithLanguages(data []byte, languages []string) (string, error) {
+	return e.extractPDF(context.Background(), data, ProcessingOptions{OCRLanguages: languages})
+}
+
+// extractPDF extracts text from a PDF, using OCR as the options' OCR mode directs
+func (e *TextExtractor) extractPDF(ctx context.Context, data []byte, opts ProcessingOptions) (string, error) {
+	mode := opts.ocrMode()
+	if mode == OCRModeAlways {
+		return e.extractPDFWithOCR(ctx, data, opts.OCRLanguages)
+	}
+	ocrFallback := mode == OCRModeAuto && e.ocrService != nil && e.ocrService.IsEnabled()
+
 	// First, try standard PDF text extraction
-	text, err := e.extractPDFText(data)
+	text, err := e.extractPDFText(data, opts.extractTables())
 	if err != nil {
 		// If PDF parsing fails completely, try OCR if available
-		if e.ocrService != nil && e.ocrService.IsEnabled() {
+		if ocrFallback {
 			log.Debug().Err(err).Msg("Standard PDF parsing failed, attempting OCR")
-			return e.extractPDFWithOCR(data, languages)
+			return e.extractPDFWithOCR(ctx, data, opts.OCRLanguages)
 		}
 		return "", err
 	}
@@ -135,13 +151,13 @@ func (e *TextExtractor) ExtractFromPDFWithLanguages(data []byte, languages []str
 	}
 
 	// Text quality is poor - try OCR if available
-	if e.ocrService != nil && e.ocrService.IsEnabled() {
+	if ocrFallback {
 		log.Debug().
 			Int("original_length", len(text)).
 			Float64("quality_score", TextQualityScore(text)).
 			Msg("Standard PDF extraction returned poor quality text, attempting OCR")
 
-		ocrText, ocrErr := e.extractPDFWithOCR(data, languages)
+		ocrText, ocrErr := e.extractPDFWithOCR(ctx, data, opts.OCRLanguages)
 		if ocrErr == nil && IsValidTextContent(ocrText) {
 			return ocrText, nil
 		}
@@ -152,6 +168,9 @@ func (e *TextExtractor) ExtractFromPDFWithLanguages(data []byte, languages []str
 
 	// Return original text if OCR not available or failed
 	if strings.TrimSpace(text) == "" {
+		if mode == OCRModeNever {
+			return "", fmt.Errorf("no text could be extracted from PDF (document may be image-based and OCR is disabled by its processing options)")
+		}
 		return "", fmt.Errorf("no text could be extracted from PDF (document may be image-based and OCR is not available)")
 	}
 
@@ -159,8 +178,9 @@ func (e *TextExtractor) ExtractFromPDFWithLanguages(data []byte, languages []str
 	return text, nil
 }
 
-// extractPDFText is the original PDF text extraction logic
-func (e *TextExtractor) extractPDFText(data []byte) (string, error) {
+// extractPDFText extracts the embedded text of a PDF. With tables set, pages are laid out
+// by rows and tables are serialized to markdown.
+func (e *TextExtractor) extractPDFText(data []byte, tables bool) (string, error) {
 	reader := bytes.NewReader(data)
 	pdfReader, err := pdf.NewReader(reader, int64(len(data)))
 	if err != nil {
@@ -176,6 +196,14 @@ func (e *TextExtractor) extractPDFText(data []byte) (string, error) {
 			continue
 		}
 
+		if tables {
+			if rows, err := page.GetTextByRow(); err == nil && len(rows) > 0 {
+				text.WriteString(pdfRowsToText(rows))
+				text.WriteString("\n\n")
+				continue
+			}
+		}
+
 		content, err := page.GetPlainText(nil)
 		if err != nil {
 			// Continue with other pages even if one fails
@@ -189,12 +217,12 @@ func (e *TextExtractor) extractPDFText(data []byte) (string, error) {
 }
 
 // extractPDFWithOCR uses the OCR service to extract text from a PDF
-func (e *TextExtractor) extractPDFWithOCR(data []byte, languages []string) (string, error) {
+func (e *TextExtractor) extractPDFWithOCR(ctx context.Context, data []byte, languages []string) (string, error) {
 	if e.ocrService == nil || !e.ocrService.IsEnabled() {
 		return "", fmt.Errorf("OCR service not available")
 	}
 
-	result, err := e.ocrService.ExtractTextFromPDF(context.Background(), data, languages)
+	result, err := e.ocrService.ExtractTextFromPDF(ctx, data, languages)
 	if err != nil {
 		return "", err
 	}
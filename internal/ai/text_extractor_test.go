@@ -1,6 +1,9 @@
 package ai
 
 import (
+	"context"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -274,3 +277,55 @@ func TestTextExtractor_Extract_UnsupportedMimeType(t *testing.T) {
 		t.Error("Expected error for unsupported MIME type")
 	}
 }
+
+func TestTextExtractor_ExtractWithOptions(t *testing.T) {
+	var gotLanguages []string
+	provider := newMockOCRProvider(true)
+	provider.extractPDFFunc = func(ctx context.Context, data []byte, languages []string) (*OCRResult, error) {
+		gotLanguages = languages
+		return &OCRResult{Text: "Scanned invoice total 120 EUR", Pages: 1}, nil
+	}
+	ocrExtractor := NewTextExtractorWithOCR(&OCRService{provider: provider, defaultLanguages: []string{"eng"}, enabled: true})
+	notPDF := []byte("not a pdf")
+
+	t.Run("auto falls back to OCR", func(t *testing.T) {
+		text, err := ocrExtractor.ExtractWithOptions(context.Background(), notPDF, "application/pdf", ProcessingOptions{})
+		if err != nil {
+			t.Fatalf("ExtractWithOptions() error = %v", err)
+		}
+		if text != "Scanned invoice total 120 EUR" {
+			t.Errorf("ExtractWithOptions() = %q, want OCR text", text)
+		}
+		if !slices.Equal(gotLanguages, []string{"eng"}) {
+			t.Errorf("OCR languages = %v, want [eng]", gotLanguages)
+		}
+	})
+
+	t.Run("always uses OCR with the requested languages", func(t *testing.T) {
+		opts := ProcessingOptions{OCR: OCRModeAlways, OCRLanguages: []string{"deu"}}
+		text, err := ocrExtractor.ExtractWithOptions(context.Background(), notPDF, "application/pdf", opts)
+		if err != nil {
+			t.Fatalf("ExtractWithOptions() error = %v", err)
+		}
+		if text != "Scanned invoice total 120 EUR" {
+			t.Errorf("ExtractWithOptions() = %q, want OCR text", text)
+		}
+		if !slices.Equal(gotLanguages, []string{"deu"}) {
+			t.Errorf("OCR languages = %v, want [deu]", gotLanguages)
+		}
+	})
+
+	t.Run("never skips OCR", func(t *testing.T) {
+		_, err := ocrExtractor.ExtractWithOptions(context.Background(), notPDF, "application/pdf", ProcessingOptions{OCR: OCRModeNever})
+		if err == nil || !strings.Contains(err.Error(), "failed to read PDF") {
+			t.Errorf("ExtractWithOptions() error = %v, want PDF parse error", err)
+		}
+	})
+
+	t.Run("always fails without OCR", func(t *testing.T) {
+		_, err := NewTextExtractor().ExtractWithOptions(context.Background(), notPDF, "application/pdf", ProcessingOptions{OCR: OCRModeAlways})
+		if err == nil || !strings.Contains(err.Error(), "OCR service not available") {
+			t.Errorf("ExtractWithOptions() error = %v, want OCR unavailable error", err)
+		}
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
+	"github.com/nimbleflux/fluxbase/internal/audit"
 	"github.com/nimbleflux/fluxbase/internal/storage"
 	"github.com/rs/zerolog/log"
 )
@@ -15,6 +17,7 @@ type UserKnowledgeBaseHandler struct {
 	processor      *DocumentProcessor
 	storageService *storage.Service
 	textExtractor  *TextExtractor
+	auditLogger    *audit.Logger // nil when the audit log is disabled
 }
 
 // NewUserKnowledgeBaseHandler creates a new user KB handler
@@ -39,6 +42,27 @@ func (h *UserKnowledgeBaseHandler) SetStorageService(svc *storage.Service) {
 	h.storageService = svc
 }
 
+// recordPermissionChange records a grant or revocation of access to a knowledge base in the
+// audit log. Exactly one of granteeUserID and granteeGroupID is set.
+func (h *UserKnowledgeBaseHandler) recordPermissionChange(c fiber.Ctx, action, kbID, granteeUserID, granteeGroupID, permission string) {
+	details := map[string]any{}
+	if granteeGroupID != "" {
+		details["group_id"] = granteeGroupID
+	} else {
+		details["user_id"] = granteeUserID
+	}
+	if permission != "" {
+		details["permission"] = permission
+	}
+
+	h.auditLogger.RecordRequest(c, audit.Event{
+		Action:       action,
+		ResourceType: "knowledge_base",
+		ResourceID:   kbID,
+		Details:      details,
+	})
+}
+
 // ListMyKnowledgeBases returns KBs accessible to current user
 // GET /api/v1/ai/knowledge-bases
 func (h *UserKnowledgeBaseHandler) ListMyKnowledgeBases(c fiber.Ctx) error {
@@ -47,9 +71,7 @@ func (h *UserKnowledgeBaseHandler) ListMyKnowledgeBases(c fiber.Ctx) error {
 
 	kbs, err := h.storage.ListUserKnowledgeBases(ctx, userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to list knowledge bases",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list knowledge bases")
 	}
 
 	return c.JSON(fiber.Map{
@@ -66,24 +88,25 @@ func (h *UserKnowledgeBaseHandler) CreateMyKnowledgeBase(c fiber.Ctx) error {
 
 	var req CreateKnowledgeBaseRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
 	}
 
 	// Validate required fields
 	if req.Name == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Name is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Name is required")
+	}
+
+	if err := req.validateVectorOptions(); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
+	}
+	if err := req.validateProcessingOptions(); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
 	}
 
 	// Create KB using the shared method (handles defaults including embedding model)
 	kb, err := h.storage.CreateKnowledgeBaseFromRequest(ctx, req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create knowledge base",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to create knowledge base")
 	}
 
 	// Set owner to current user
@@ -94,12 +117,18 @@ func (h *UserKnowledgeBaseHandler) CreateMyKnowledgeBase(c fiber.Ctx) error {
 
 	// Grant initial permissions if specified
 	for _, perm := range req.InitialPermissions {
-		_, err := h.storage.GrantKBPermission(ctx, kb.ID, perm.UserID, string(perm.Permission), &userID)
+		var err error
+		if perm.GroupID != "" {
+			_, err = h.storage.GrantKBGroupPermission(ctx, kb.ID, perm.GroupID, string(perm.Permission), &userID)
+		} else {
+			_, err = h.storage.GrantKBPermission(ctx, kb.ID, perm.UserID, string(perm.Permission), &userID)
+		}
 		if err != nil {
 			// Log error but don't fail the entire request
 			// The KB was created successfully, just permission grant failed
 			continue
 		}
+		h.recordPermissionChange(c, audit.ActionPermissionGrant, kb.ID, perm.UserID, perm.GroupID, string(perm.Permission))
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(kb)
@@ -113,22 +142,18 @@ func (h *UserKnowledgeBaseHandler) GetMyKnowledgeBase(c fiber.Ctx) error {
 	kbID := c.Params("id")
 
 	if !h.storage.CanUserAccessKB(ctx, kbID, userID) {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Access denied",
-		})
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Access denied")
 	}
 
 	kb, err := h.storage.GetKnowledgeBase(ctx, kbID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Knowledge base not found",
-		})
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Knowledge base not found")
 	}
 
 	return c.JSON(kb)
 }
 
-// ShareKnowledgeBase grants permission to another user
+// ShareKnowledgeBase grants permission to another user or to a group
 // POST /api/v1/ai/knowledge-bases/:id/share
 func (h *UserKnowledgeBaseHandler) ShareKnowledgeBase(c fiber.Ctx) error {
 	ctx := c.RequestCtx()
@@ -137,28 +162,32 @@ func (h *UserKnowledgeBaseHandler) ShareKnowledgeBase(c fiber.Ctx) error {
 
 	kb, err := h.storage.GetKnowledgeBase(ctx, kbID)
 	if err != nil || kb.OwnerID == nil || *kb.OwnerID != userID {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Only owner can share knowledge base",
-		})
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Only owner can share knowledge base")
 	}
 
 	var req struct {
 		UserID     string `json:"user_id"`
+		GroupID    string `json:"group_id"`
 		Permission string `json:"permission"`
 	}
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+	}
+	if err := validateGrantee(req.UserID, req.GroupID); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
 	}
 
-	grant, err := h.storage.GrantKBPermission(ctx, kbID, req.UserID, req.Permission, &userID)
+	var grant *KBPermissionGrant
+	if req.GroupID != "" {
+		grant, err = h.storage.GrantKBGroupPermission(ctx, kbID, req.GroupID, req.Permission, &userID)
+	} else {
+		grant, err = h.storage.GrantKBPermission(ctx, kbID, req.UserID, req.Permission, &userID)
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to grant permission",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to grant permission")
 	}
 
+	h.recordPermissionChange(c, audit.ActionPermissionGrant, kbID, req.UserID, req.GroupID, req.Permission)
 	return c.Status(fiber.StatusCreated).JSON(grant)
 }
 
@@ -171,16 +200,12 @@ func (h *UserKnowledgeBaseHandler) ListPermissions(c fiber.Ctx) error {
 
 	kb, err := h.storage.GetKnowledgeBase(ctx, kbID)
 	if err != nil || kb.OwnerID == nil || *kb.OwnerID != userID {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Only owner can view permissions",
-		})
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeInsufficientPermissions, "Only owner can view permissions")
 	}
 
 	perms, err := h.storage.ListKBPermissions(ctx, kbID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to list permissions",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list permissions")
 	}
 
 	return c.JSON(perms)
@@ -196,18 +221,39 @@ func (h *UserKnowledgeBaseHandler) RevokePermission(c fiber.Ctx) error {
 
 	kb, err := h.storage.GetKnowledgeBase(ctx, kbID)
 	if err != nil || kb.OwnerID == nil || *kb.OwnerID != userID {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Only owner can revoke permissions",
-		})
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeInsufficientPermissions, "Only owner can revoke permissions")
 	}
 
 	err = h.storage.RevokeKBPermission(ctx, kbID, targetUserID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to revoke permission",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to revoke permission")
 	}
 
+	h.recordPermissionChange(c, audit.ActionPermissionRevoke, kbID, targetUserID, "", "")
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RevokeGroupPermission revokes a group's permission
+// DELETE /api/v1/ai/knowledge-bases/:id/permissions/groups/:group_id
+func (h *UserKnowledgeBaseHandler) RevokeGroupPermission(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+	userID := c.Locals("user_id").(string)
+	kbID := c.Params("id")
+	groupID := c.Params("group_id")
+
+	kb, err := h.storage.GetKnowledgeBase(ctx, kbID)
+	if err != nil || kb.OwnerID == nil || *kb.OwnerID != userID {
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeInsufficientPermissions, "Only owner can revoke permissions")
+	}
+
+	err = h.storage.RevokeKBGroupPermission(ctx, kbID, groupID)
+	if err != nil {
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to revoke permission")
+	}
+
+	h.recordPermissionChange(c, audit.ActionPermissionRevoke, kbID, "", groupID, "")
+
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
@@ -225,23 +271,17 @@ func (h *UserKnowledgeBaseHandler) ListMyDocuments(c fiber.Ctx) error {
 	// Check read permission (viewer or higher)
 	hasPermission, err := h.storage.CheckKBPermission(ctx, kbID, userID, string(KBPermissionViewer))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to check permission",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to check permission")
 	}
 	if !hasPermission {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Access denied",
-		})
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Access denied")
 	}
 
 	// Get documents (the storage layer will filter by user's access)
 	documents, err := h.storage.ListDocuments(ctx, kbID)
 	if err != nil {
 		log.Error().Err(err).Str("kb_id", kbID).Msg("Failed to list documents")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to list documents",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list documents")
 	}
 
 	return c.JSON(fiber.Map{
@@ -261,28 +301,20 @@ func (h *UserKnowledgeBaseHandler) GetMyDocument(c fiber.Ctx) error {
 	// Check read permission
 	hasPermission, err := h.storage.CheckKBPermission(ctx, kbID, userID, string(KBPermissionViewer))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to check permission",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to check permission")
 	}
 	if !hasPermission {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Access denied",
-		})
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Access denied")
 	}
 
 	doc, err := h.storage.GetDocument(ctx, docID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Document not found",
-		})
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Document not found")
 	}
 
 	// Verify document belongs to the KB
 	if doc.KnowledgeBaseID != kbID {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Document not found",
-		})
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Document not found")
 	}
 
 	return c.JSON(doc)
@@ -298,34 +330,28 @@ func (h *UserKnowledgeBaseHandler) AddMyDocument(c fiber.Ctx) error {
 	// Check write permission (editor or higher)
 	hasPermission, err := h.storage.CheckKBPermission(ctx, kbID, userID, string(KBPermissionEditor))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to check permission",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to check permission")
 	}
 	if !hasPermission {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Editor permission required to add documents",
-		})
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeInsufficientPermissions, "Editor permission required to add documents")
 	}
 
 	// Check if processor is available
 	if h.processor == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Document processing not available (embedding service not configured)",
-		})
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Document processing not available (embedding service not configured)")
 	}
 
 	var req AddDocumentRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
 	}
 
 	if req.Content == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Content is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Content is required")
+	}
+
+	if err := req.normalizeQAPairs(); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
 	}
 
 	// Auto-set user_id in metadata for user isolation
@@ -335,21 +361,25 @@ func (h *UserKnowledgeBaseHandler) AddMyDocument(c fiber.Ctx) error {
 	}
 	metadata["user_id"] = userID
 
+	if req.SecretsOverrideReason != "" && !isSecretsOverrideAllowed(c) {
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAdminRequired, "Only admins can override secret scanning")
+	}
+
 	// Add document
 	docReq := CreateDocumentRequest{
-		Title:     req.Title,
-		Content:   req.Content,
-		SourceURL: req.Source,
-		MimeType:  req.MimeType,
-		Metadata:  metadata,
+		Title:                 req.Title,
+		Content:               req.Content,
+		SourceURL:             req.Source,
+		SourceType:            req.SourceType,
+		MimeType:              req.MimeType,
+		Metadata:              metadata,
+		SecretsOverrideReason: req.SecretsOverrideReason,
 	}
 
 	doc, err := h.processor.AddDocument(ctx, kbID, docReq, &userID)
 	if err != nil {
 		log.Error().Err(err).Str("kb_id", kbID).Msg("Failed to add document")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to add document",
-		})
+		return addDocumentErrorResponse(c, err)
 	}
 
 	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
@@ -369,37 +399,27 @@ func (h *UserKnowledgeBaseHandler) UploadMyDocument(c fiber.Ctx) error {
 	// Check write permission (editor or higher)
 	hasPermission, err := h.storage.CheckKBPermission(ctx, kbID, userID, string(KBPermissionEditor))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to check permission",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to check permission")
 	}
 	if !hasPermission {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Editor permission required to upload documents",
-		})
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeInsufficientPermissions, "Editor permission required to upload documents")
 	}
 
 	// Check if processor is available
 	if h.processor == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Document processing not available (embedding service not configured)",
-		})
+		return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Document processing not available (embedding service not configured)")
 	}
 
 	// Get the uploaded file
 	file, err := c.FormFile("file")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "No file uploaded",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "No file uploaded")
 	}
 
 	// Check file size (max 50MB)
 	maxSize := int64(50 * 1024 * 1024)
 	if file.Size > maxSize {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": fmt.Sprintf("File too large. Maximum size is %dMB", maxSize/(1024*1024)),
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, fmt.Sprintf("File too large. Maximum size is %dMB", maxSize/(1024*1024)))
 	}
 
 	// Determine MIME type from file extension
@@ -418,6 +438,7 @@ func (h *UserKnowledgeBaseHandler) UploadMyDocument(c fiber.Ctx) error {
 	if !isSupported {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error":           fmt.Sprintf("Unsupported file type: %s", ext),
+			"code":            apierror.CodeInvalidInput,
 			"supported_types": supported,
 		})
 	}
@@ -425,46 +446,66 @@ func (h *UserKnowledgeBaseHandler) UploadMyDocument(c fiber.Ctx) error {
 	// Read file content
 	fileReader, err := file.Open()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to read uploaded file",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to read uploaded file")
 	}
 	defer func() { _ = fileReader.Close() }()
 
 	fileContent, err := readFileContent(fileReader, int(file.Size))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to read file content",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to read file content")
 	}
 
-	// Extract text from file
-	extractedText, err := h.textExtractor.Extract(fileContent, mimeType)
+	// Processing options of the upload override those of the knowledge base
+	uploadOptions, err := ParseUploadProcessingOptions(c.FormValue("processing_options"), c.FormValue("language"))
 	if err != nil {
-		log.Error().Err(err).Str("mime_type", mimeType).Msg("Failed to extract text from file")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to extract text from file: %v", err),
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
+	}
+	kb, err := h.storage.GetKnowledgeBaseCached(ctx, kbID)
+	if err != nil || kb == nil {
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get knowledge base")
+	}
+	processingOptions := kb.ProcessingOptions.Merge(uploadOptions)
+
+	// Q&A files are parsed from the raw data; text extraction would flatten CSV fields
+	sourceType := c.FormValue("source_type")
+	var extractedText string
+	if sourceType == DocumentSourceTypeQAPairs {
+		extractedText, _, err = NormalizeQAPairs(string(fileContent), mimeType)
+		if err != nil {
+			return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, fmt.Sprintf("Invalid Q&A pairs: %v", err))
+		}
+	} else {
+		// Extract text from file (with OCR as the processing options direct)
+		extractedText, err = h.textExtractor.ExtractWithOptions(ctx, fileContent, mimeType, processingOptions)
+		if err != nil {
+			log.Error().Err(err).Str("mime_type", mimeType).Msg("Failed to extract text from file")
+			return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, fmt.Sprintf("Failed to extract text from file: %v", err))
+		}
 	}
 
 	// Prepare metadata with user isolation
 	metadata := map[string]string{"user_id": userID}
 
+	overrideReason := c.FormValue("secrets_override_reason")
+	if overrideReason != "" && !isSecretsOverrideAllowed(c) {
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAdminRequired, "Only admins can override secret scanning")
+	}
+
 	// Create document request
 	docReq := CreateDocumentRequest{
-		Title:    file.Filename,
-		Content:  extractedText,
-		MimeType: mimeType,
-		Metadata: metadata,
+		Title:                 file.Filename,
+		Content:               extractedText,
+		SourceType:            sourceType,
+		MimeType:              mimeType,
+		Metadata:              metadata,
+		SecretsOverrideReason: overrideReason,
 	}
 
 	// Add document
 	doc, err := h.processor.AddDocument(ctx, kbID, docReq, &userID)
 	if err != nil {
 		log.Error().Err(err).Str("kb_id", kbID).Msg("Failed to add document from upload")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to add document",
-		})
+		return addDocumentErrorResponse(c, err)
 	}
 
 	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
@@ -485,35 +526,25 @@ func (h *UserKnowledgeBaseHandler) DeleteMyDocument(c fiber.Ctx) error {
 	// Check write permission (editor or higher)
 	hasPermission, err := h.storage.CheckKBPermission(ctx, kbID, userID, string(KBPermissionEditor))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to check permission",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to check permission")
 	}
 	if !hasPermission {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Editor permission required to delete documents",
-		})
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeInsufficientPermissions, "Editor permission required to delete documents")
 	}
 
 	// Get document to verify it belongs to this KB
 	doc, err := h.storage.GetDocument(ctx, docID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Document not found",
-		})
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Document not found")
 	}
 	if doc.KnowledgeBaseID != kbID {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Document not found",
-		})
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Document not found")
 	}
 
 	// Delete document
 	if err := h.storage.DeleteDocument(ctx, docID); err != nil {
 		log.Error().Err(err).Str("doc_id", docID).Msg("Failed to delete document")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete document",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to delete document")
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
@@ -529,27 +560,19 @@ func (h *UserKnowledgeBaseHandler) SearchMyKB(c fiber.Ctx) error {
 	// Check read permission
 	hasPermission, err := h.storage.CheckKBPermission(ctx, kbID, userID, string(KBPermissionViewer))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to check permission",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to check permission")
 	}
 	if !hasPermission {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Access denied",
-		})
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Access denied")
 	}
 
 	var req SearchRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
 	}
 
 	if req.Query == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Query is required",
-		})
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Query is required")
 	}
 
 	// Set defaults
@@ -577,9 +600,7 @@ func (h *UserKnowledgeBaseHandler) SearchMyKB(c fiber.Ctx) error {
 	results, err := h.storage.SearchChunksHybrid(ctx, kbID, opts)
 	if err != nil {
 		log.Error().Err(err).Str("kb_id", kbID).Msg("Search failed")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Search failed",
-		})
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Search failed")
 	}
 
 	return c.JSON(fiber.Map{
@@ -611,20 +632,29 @@ func readFileContent(reader interface{ Read([]byte) (int, error) }, maxSize int)
 	return buf, nil
 }
 
-// RegisterUserKnowledgeBaseRoutes registers user-facing routes
-func RegisterUserKnowledgeBaseRoutes(router fiber.Router, storage *KnowledgeBaseStorage) {
+// RegisterUserKnowledgeBaseRoutes registers user-facing routes. auditLogger records permission
+// changes and may be nil.
+func RegisterUserKnowledgeBaseRoutes(router fiber.Router, storage *KnowledgeBaseStorage, auditLogger *audit.Logger) {
 	handler := NewUserKnowledgeBaseHandler(storage)
+	handler.auditLogger = auditLogger
 	router.Get("/knowledge-bases", handler.ListMyKnowledgeBases)
 	router.Post("/knowledge-bases", handler.CreateMyKnowledgeBase)
 	router.Get("/knowledge-bases/:id", handler.GetMyKnowledgeBase)
 	router.Post("/knowledge-bases/:id/share", handler.ShareKnowledgeBase)
 	router.Get("/knowledge-bases/:id/permissions", handler.ListPermissions)
 	router.Delete("/knowledge-bases/:id/permissions/:user_id", handler.RevokePermission)
+	router.Delete("/knowledge-bases/:id/permissions/groups/:group_id", handler.RevokeGroupPermission)
 }
 
-// RegisterUserKnowledgeBaseRoutesWithDocuments registers user-facing routes including document operations
-func RegisterUserKnowledgeBaseRoutesWithDocuments(router fiber.Router, storage *KnowledgeBaseStorage, processor *DocumentProcessor) {
+// RegisterUserKnowledgeBaseRoutesWithDocuments registers user-facing routes including document operations.
+// ocrService may be nil, in which case uploads are extracted without OCR. auditLogger records
+// permission changes and may be nil.
+func RegisterUserKnowledgeBaseRoutesWithDocuments(router fiber.Router, storage *KnowledgeBaseStorage, processor *DocumentProcessor, ocrService *OCRService, auditLogger *audit.Logger) {
 	handler := NewUserKnowledgeBaseHandlerWithProcessor(storage, processor)
+	handler.auditLogger = auditLogger
+	if ocrService != nil && ocrService.IsEnabled() {
+		handler.textExtractor = NewTextExtractorWithOCR(ocrService)
+	}
 
 	// KB management routes
 	router.Get("/knowledge-bases", handler.ListMyKnowledgeBases)
@@ -633,6 +663,7 @@ func RegisterUserKnowledgeBaseRoutesWithDocuments(router fiber.Router, storage *
 	router.Post("/knowledge-bases/:id/share", handler.ShareKnowledgeBase)
 	router.Get("/knowledge-bases/:id/permissions", handler.ListPermissions)
 	router.Delete("/knowledge-bases/:id/permissions/:user_id", handler.RevokePermission)
+	router.Delete("/knowledge-bases/:id/permissions/groups/:group_id", handler.RevokeGroupPermission)
 
 	// Document routes (permission checks are in handlers)
 	router.Get("/knowledge-bases/:id/documents", handler.ListMyDocuments)
@@ -650,3 +681,9 @@ type SearchRequest struct {
 	Query string `json:"query"`
 	Limit int    `json:"limit,omitempty"`
 }
+
+// isSecretsOverrideAllowed returns true for roles that may add documents with detected secrets
+func isSecretsOverrideAllowed(c fiber.Ctx) bool {
+	role, _ := c.Locals("user_role").(string)
+	return role == "admin" || role == "dashboard_admin" || role == "service_role"
+}
@@ -0,0 +1,550 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// VectorCollection is a standalone vector store with payloads, independent of knowledge bases
+type VectorCollection struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Namespace      string    `json:"namespace"`
+	Description    string    `json:"description,omitempty"`
+	Dimensions     int       `json:"dimensions"`
+	DistanceMetric string    `json:"distance_metric"`
+	EmbeddingModel *string   `json:"embedding_model,omitempty"`
+	PointCount     int       `json:"point_count"`
+	QuotaMaxPoints int       `json:"quota_max_points"`
+	OwnerID        *string   `json:"owner_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// VectorPoint is a vector and its payload stored in a collection
+type VectorPoint struct {
+	ID        string         `json:"id"`
+	Vector    []float32      `json:"vector,omitempty"`
+	Content   *string        `json:"content,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// VectorQueryOptions controls a collection similarity query
+type VectorQueryOptions struct {
+	Vector         []float32
+	TopK           int
+	MinScore       *float64
+	Filter         *MetadataFilterGroup
+	IncludeVectors bool
+}
+
+// VectorQueryMatch is a single collection query result.
+// Score is higher-is-better for every metric: 1-distance for cosine,
+// -distance for l2 and the inner product for inner_product.
+type VectorQueryMatch struct {
+	ID       string         `json:"id"`
+	Score    float64        `json:"score"`
+	Distance float64        `json:"distance"`
+	Content  *string        `json:"content,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+	Vector   []float32      `json:"vector,omitempty"`
+}
+
+// Vector collection limits
+const (
+	// maxHNSWDimensions is the largest dimension count pgvector can index with HNSW
+	maxHNSWDimensions       = 2000
+	DefaultVectorQueryTopK  = 10
+	MaxVectorQueryTopK      = 1000
+	MaxVectorPointsPerWrite = 1000
+
+	DefaultVectorCollectionMaxPoints = 100000
+)
+
+// ErrVectorPointExists is returned when inserting a point whose ID already exists in the collection
+var ErrVectorPointExists = errors.New("point already exists")
+
+// vectorDistanceOperators maps distance metrics to pgvector operators and index operator classes
+var vectorDistanceOperators = map[string]struct {
+	operator string
+	opsClass string
+}{
+	"cosine":        {"<=>", "vector_cosine_ops"},
+	"l2":            {"<->", "vector_l2_ops"},
+	"inner_product": {"<#>", "vector_ip_ops"},
+}
+
+// IsValidDistanceMetric returns true if the metric is supported by vector collections
+func IsValidDistanceMetric(metric string) bool {
+	_, ok := vectorDistanceOperators[metric]
+	return ok
+}
+
+// vectorScore converts a pgvector distance into a higher-is-better score
+func vectorScore(metric string, distance float64) float64 {
+	switch metric {
+	case "cosine":
+		return 1 - distance
+	default:
+		// l2 distance and negative inner product: negate so higher is better
+		return -distance
+	}
+}
+
+// ValidateVector checks a vector against the collection's dimensions
+func (c *VectorCollection) ValidateVector(v []float32) error {
+	if len(v) != c.Dimensions {
+		return fmt.Errorf("vector has %d dimensions, collection %q expects %d", len(v), c.Name, c.Dimensions)
+	}
+	return nil
+}
+
+// indexName returns the name of the collection's partial HNSW index
+func (c *VectorCollection) indexName() string {
+	return "idx_ai_vector_points_" + strings.ReplaceAll(c.ID, "-", "")
+}
+
+// embeddingExpr returns the typed embedding expression used by the collection's index and queries
+func (c *VectorCollection) embeddingExpr(column string) string {
+	return fmt.Sprintf("(%s::vector(%d))", column, c.Dimensions)
+}
+
+// ============================================================================
+// VECTOR COLLECTION STORAGE OPERATIONS
+// ============================================================================
+
+const vectorCollectionColumns = `
+	id, name, namespace, COALESCE(description, ''), dimensions, distance_metric, embedding_model,
+	point_count, quota_max_points, owner_id, created_at, updated_at
+`
+
+// CreateVectorCollection creates a collection and its per-collection vector index
+func (s *KnowledgeBaseStorage) CreateVectorCollection(ctx context.Context, collection *VectorCollection) error {
+	if collection.Namespace == "" {
+		collection.Namespace = "default"
+	}
+	if collection.DistanceMetric == "" {
+		collection.DistanceMetric = "cosine"
+	}
+	if !IsValidDistanceMetric(collection.DistanceMetric) {
+		return fmt.Errorf("unsupported distance metric: %s", collection.DistanceMetric)
+	}
+
+	query := `
+		INSERT INTO ai.vector_collections (
+			name, namespace, description, dimensions, distance_metric, embedding_model, quota_max_points, owner_id
+		) VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8)
+		RETURNING id, point_count, created_at, updated_at
+	`
+
+	err := s.db.QueryRow(ctx, query,
+		collection.Name, collection.Namespace, collection.Description, collection.Dimensions,
+		collection.DistanceMetric, collection.EmbeddingModel, collection.QuotaMaxPoints, collection.OwnerID,
+	).Scan(&collection.ID, &collection.PointCount, &collection.CreatedAt, &collection.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create vector collection: %w", err)
+	}
+
+	if err := s.createVectorCollectionIndex(ctx, collection); err != nil {
+		// The collection still works with exact (sequential) search
+		log.Warn().Err(err).Str("collection_id", collection.ID).Msg("Failed to create vector collection index")
+	}
+
+	return nil
+}
+
+// createVectorCollectionIndex creates a partial HNSW index over the collection's points.
+// The index expression casts to the collection's dimensions so collections of different
+// sizes can share ai.vector_points.
+func (s *KnowledgeBaseStorage) createVectorCollectionIndex(ctx context.Context, collection *VectorCollection) error {
+	if collection.Dimensions > maxHNSWDimensions {
+		log.Info().
+			Str("collection_id", collection.ID).
+			Int("dimensions", collection.Dimensions).
+			Msg("Vector collection exceeds HNSW dimension limit, using exact search")
+		return nil
+	}
+
+	ops := vectorDistanceOperators[collection.DistanceMetric]
+	// ID comes from gen_random_uuid() and dimensions are an integer, so both are safe to inline
+	ddl := fmt.Sprintf(
+		`CREATE INDEX IF NOT EXISTS %s ON ai.vector_points USING hnsw (%s %s) WHERE collection_id = '%s'`,
+		collection.indexName(), collection.embeddingExpr("embedding"), ops.opsClass, collection.ID,
+	)
+	_, err := s.db.Exec(ctx, ddl)
+	return err
+}
+
+// GetVectorCollection retrieves a collection by ID
+func (s *KnowledgeBaseStorage) GetVectorCollection(ctx context.Context, id string) (*VectorCollection, error) {
+	query := `SELECT ` + vectorCollectionColumns + ` FROM ai.vector_collections WHERE id = $1`
+
+	collection, err := scanVectorCollection(s.db.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vector collection: %w", err)
+	}
+	return collection, nil
+}
+
+// ListVectorCollections lists collections. When userID is set, only collections the user
+// owns or has been granted access to are returned.
+func (s *KnowledgeBaseStorage) ListVectorCollections(ctx context.Context, userID *string) ([]*VectorCollection, error) {
+	query := `SELECT ` + vectorCollectionColumns + ` FROM ai.vector_collections vc
+		WHERE $1::uuid IS NULL
+		   OR vc.owner_id = $1::uuid
+		   OR EXISTS (
+				SELECT 1 FROM ai.vector_collection_permissions vcp
+				WHERE vcp.collection_id = vc.id AND vcp.user_id = $1::uuid
+		   )
+		ORDER BY vc.namespace, vc.name
+	`
+
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vector collections: %w", err)
+	}
+	defer rows.Close()
+
+	collections := make([]*VectorCollection, 0)
+	for rows.Next() {
+		collection, err := scanVectorCollection(rows)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to scan vector collection")
+			continue
+		}
+		collections = append(collections, collection)
+	}
+	return collections, nil
+}
+
+// DeleteVectorCollection deletes a collection, its points and its index
+func (s *KnowledgeBaseStorage) DeleteVectorCollection(ctx context.Context, collection *VectorCollection) error {
+	if _, err := s.db.Exec(ctx, `DELETE FROM ai.vector_collections WHERE id = $1`, collection.ID); err != nil {
+		return fmt.Errorf("failed to delete vector collection: %w", err)
+	}
+
+	if _, err := s.db.Exec(ctx, `DROP INDEX IF EXISTS ai.`+collection.indexName()); err != nil {
+		log.Warn().Err(err).Str("collection_id", collection.ID).Msg("Failed to drop vector collection index")
+	}
+	return nil
+}
+
+// scanVectorCollection scans a row selected with vectorCollectionColumns
+func scanVectorCollection(row pgx.Row) (*VectorCollection, error) {
+	c := &VectorCollection{}
+	err := row.Scan(
+		&c.ID, &c.Name, &c.Namespace, &c.Description, &c.Dimensions, &c.DistanceMetric, &c.EmbeddingModel,
+		&c.PointCount, &c.QuotaMaxPoints, &c.OwnerID, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// UpsertVectorPoints writes points to a collection. When upsert is false, existing IDs are rejected.
+// Returns the number of newly inserted points (updates are not counted).
+func (s *KnowledgeBaseStorage) UpsertVectorPoints(ctx context.Context, collection *VectorCollection, points []VectorPoint, upsert bool) (int, error) {
+	conflict := `ON CONFLICT (collection_id, external_id) DO NOTHING`
+	if upsert {
+		conflict = `ON CONFLICT (collection_id, external_id) DO UPDATE SET
+			embedding = EXCLUDED.embedding,
+			content = EXCLUDED.content,
+			metadata = EXCLUDED.metadata`
+	}
+
+	query := `
+		INSERT INTO ai.vector_points (collection_id, external_id, embedding, content, metadata)
+		VALUES ($1, $2, $3::vector, $4, $5)
+		` + conflict + `
+		RETURNING (xmax = 0) AS inserted
+	`
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	inserted := 0
+	for _, p := range points {
+		metadata := p.Metadata
+		if metadata == nil {
+			metadata = map[string]any{}
+		}
+		metadataJSON, err := json.Marshal(metadata)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal metadata for point %s: %w", p.ID, err)
+		}
+
+		var isInsert bool
+		err = tx.QueryRow(ctx, query, collection.ID, p.ID, formatEmbeddingLiteral(p.Vector), p.Content, metadataJSON).Scan(&isInsert)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("%w: %s", ErrVectorPointExists, p.ID)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to write point %s: %w", p.ID, err)
+		}
+		if isInsert {
+			inserted++
+		}
+	}
+
+	if inserted > 0 {
+		if _, err := tx.Exec(ctx, `UPDATE ai.vector_collections SET point_count = point_count + $2 WHERE id = $1`, collection.ID, inserted); err != nil {
+			return 0, fmt.Errorf("failed to update point count: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit points: %w", err)
+	}
+	return inserted, nil
+}
+
+// DeleteVectorPoints deletes points by ID and returns how many were removed
+func (s *KnowledgeBaseStorage) DeleteVectorPoints(ctx context.Context, collection *VectorCollection, ids []string) (int, error) {
+	query := `
+		WITH deleted AS (
+			DELETE FROM ai.vector_points WHERE collection_id = $1 AND external_id = ANY($2) RETURNING 1
+		)
+		UPDATE ai.vector_collections
+		SET point_count = GREATEST(0, point_count - (SELECT COUNT(*) FROM deleted))
+		WHERE id = $1
+		RETURNING (SELECT COUNT(*) FROM deleted)
+	`
+
+	var deleted int
+	if err := s.db.QueryRow(ctx, query, collection.ID, ids).Scan(&deleted); err != nil {
+		return 0, fmt.Errorf("failed to delete points: %w", err)
+	}
+	return deleted, nil
+}
+
+// GetVectorPoint retrieves a single point including its vector
+func (s *KnowledgeBaseStorage) GetVectorPoint(ctx context.Context, collection *VectorCollection, id string) (*VectorPoint, error) {
+	query := `
+		SELECT external_id, embedding::text, content, metadata, created_at, updated_at
+		FROM ai.vector_points
+		WHERE collection_id = $1 AND external_id = $2
+	`
+
+	var p VectorPoint
+	var embeddingText string
+	var metadataJSON []byte
+	err := s.db.QueryRow(ctx, query, collection.ID, id).Scan(&p.ID, &embeddingText, &p.Content, &metadataJSON, &p.CreatedAt, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get point: %w", err)
+	}
+
+	if p.Vector, err = parseEmbeddingLiteral(embeddingText); err != nil {
+		return nil, err
+	}
+	if len(metadataJSON) > 0 {
+		_ = json.Unmarshal(metadataJSON, &p.Metadata)
+	}
+	return &p, nil
+}
+
+// QueryVectorCollection returns the top-k points closest to the query vector, optionally filtered by payload
+func (s *KnowledgeBaseStorage) QueryVectorCollection(ctx context.Context, collection *VectorCollection, opts VectorQueryOptions) ([]VectorQueryMatch, error) {
+	ops, ok := vectorDistanceOperators[collection.DistanceMetric]
+	if !ok {
+		return nil, fmt.Errorf("unsupported distance metric: %s", collection.DistanceMetric)
+	}
+
+	topK := opts.TopK
+	if topK <= 0 {
+		topK = DefaultVectorQueryTopK
+	}
+	if topK > MaxVectorQueryTopK {
+		topK = MaxVectorQueryTopK
+	}
+
+	// Use the same typed expression as the partial index so the planner can use it
+	distanceExpr := fmt.Sprintf("%s %s $2::vector(%d)", collection.embeddingExpr("p.embedding"), ops.operator, collection.Dimensions)
+
+	args := []interface{}{collection.ID, formatEmbeddingLiteral(opts.Vector)}
+	where := "p.collection_id = $1"
+
+	if opts.Filter != nil {
+		argIndex := len(args) + 1
+		filterSQL, filterArgs, err := buildMetadataFilterSQLForTable(*opts.Filter, &argIndex, "p")
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter: %w", err)
+		}
+		if filterSQL != "" {
+			where += " AND (" + filterSQL + ")"
+			args = append(args, filterArgs...)
+		}
+	}
+
+	vectorColumn := "NULL::text"
+	if opts.IncludeVectors {
+		vectorColumn = "p.embedding::text"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT p.external_id, %s AS distance, p.content, p.metadata, %s
+		FROM ai.vector_points p
+		WHERE %s
+		ORDER BY distance
+		LIMIT %d
+	`, distanceExpr, vectorColumn, where, topK)
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query vector collection: %w", err)
+	}
+	defer rows.Close()
+
+	matches := make([]VectorQueryMatch, 0, topK)
+	for rows.Next() {
+		var m VectorQueryMatch
+		var metadataJSON []byte
+		var embeddingText *string
+		if err := rows.Scan(&m.ID, &m.Distance, &m.Content, &metadataJSON, &embeddingText); err != nil {
+			return nil, fmt.Errorf("failed to scan query result: %w", err)
+		}
+
+		m.Score = vectorScore(collection.DistanceMetric, m.Distance)
+		if opts.MinScore != nil && m.Score < *opts.MinScore {
+			continue
+		}
+		if len(metadataJSON) > 0 {
+			_ = json.Unmarshal(metadataJSON, &m.Metadata)
+		}
+		if embeddingText != nil {
+			if m.Vector, err = parseEmbeddingLiteral(*embeddingText); err != nil {
+				return nil, err
+			}
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+// parseEmbeddingLiteral parses a pgvector text literal such as "[1,2,3]"
+func parseEmbeddingLiteral(s string) ([]float32, error) {
+	var v []float32
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return nil, fmt.Errorf("failed to parse vector: %w", err)
+	}
+	return v, nil
+}
+
+// ============================================================================
+// VECTOR COLLECTION PERMISSIONS
+// ============================================================================
+
+// VectorCollectionPermissionGrant is a user's access to a shared collection
+type VectorCollectionPermissionGrant struct {
+	ID           string    `json:"id"`
+	CollectionID string    `json:"collection_id"`
+	UserID       string    `json:"user_id"`
+	Permission   string    `json:"permission"`
+	GrantedBy    *string   `json:"granted_by,omitempty"`
+	GrantedAt    time.Time `json:"granted_at"`
+}
+
+// CheckVectorCollectionPermission checks whether a user has at least the required permission level.
+// Owners always have full access. Uses the same hierarchy as knowledge bases (viewer < editor < owner).
+func (s *KnowledgeBaseStorage) CheckVectorCollectionPermission(ctx context.Context, collection *VectorCollection, userID, requiredPermission string) (bool, error) {
+	if collection.OwnerID != nil && *collection.OwnerID == userID {
+		return true, nil
+	}
+
+	var allowed []string
+	switch requiredPermission {
+	case string(KBPermissionViewer):
+		allowed = []string{string(KBPermissionViewer), string(KBPermissionEditor), string(KBPermissionOwner)}
+	case string(KBPermissionEditor):
+		allowed = []string{string(KBPermissionEditor), string(KBPermissionOwner)}
+	case string(KBPermissionOwner):
+		allowed = []string{string(KBPermissionOwner)}
+	default:
+		return false, nil
+	}
+
+	var hasPermission bool
+	err := s.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM ai.vector_collection_permissions
+			WHERE collection_id = $1 AND user_id = $2 AND permission = ANY($3)
+		)
+	`, collection.ID, userID, allowed).Scan(&hasPermission)
+	if err != nil {
+		return false, fmt.Errorf("failed to check vector collection permission: %w", err)
+	}
+	return hasPermission, nil
+}
+
+// GrantVectorCollectionPermission grants or updates a user's permission on a collection
+func (s *KnowledgeBaseStorage) GrantVectorCollectionPermission(ctx context.Context, collectionID, userID, permission string, grantedBy *string) (*VectorCollectionPermissionGrant, error) {
+	grant := &VectorCollectionPermissionGrant{
+		CollectionID: collectionID,
+		UserID:       userID,
+		Permission:   permission,
+		GrantedBy:    grantedBy,
+	}
+
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO ai.vector_collection_permissions (collection_id, user_id, permission, granted_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (collection_id, user_id) DO UPDATE
+		SET permission = EXCLUDED.permission, granted_by = EXCLUDED.granted_by, granted_at = NOW()
+		RETURNING id, granted_at
+	`, collectionID, userID, permission, grantedBy).Scan(&grant.ID, &grant.GrantedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to grant vector collection permission: %w", err)
+	}
+	return grant, nil
+}
+
+// ListVectorCollectionPermissions lists the grants on a collection
+func (s *KnowledgeBaseStorage) ListVectorCollectionPermissions(ctx context.Context, collectionID string) ([]VectorCollectionPermissionGrant, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id, collection_id, user_id, permission, granted_by, granted_at
+		FROM ai.vector_collection_permissions
+		WHERE collection_id = $1
+		ORDER BY granted_at
+	`, collectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vector collection permissions: %w", err)
+	}
+	defer rows.Close()
+
+	grants := make([]VectorCollectionPermissionGrant, 0)
+	for rows.Next() {
+		var g VectorCollectionPermissionGrant
+		if err := rows.Scan(&g.ID, &g.CollectionID, &g.UserID, &g.Permission, &g.GrantedBy, &g.GrantedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan permission: %w", err)
+		}
+		grants = append(grants, g)
+	}
+	return grants, nil
+}
+
+// RevokeVectorCollectionPermission removes a user's access to a collection
+func (s *KnowledgeBaseStorage) RevokeVectorCollectionPermission(ctx context.Context, collectionID, userID string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM ai.vector_collection_permissions WHERE collection_id = $1 AND user_id = $2`, collectionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke vector collection permission: %w", err)
+	}
+	return nil
+}
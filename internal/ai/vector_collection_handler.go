@@ -0,0 +1,571 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
+	"github.com/rs/zerolog/log"
+)
+
+// VectorCollectionHandler handles the standalone vector collection API.
+// Collections store caller-provided vectors and payloads directly, without the
+// document/chunk model used by knowledge bases.
+type VectorCollectionHandler struct {
+	storage          *KnowledgeBaseStorage
+	quotaService     *QuotaService
+	embeddingService *EmbeddingService
+}
+
+// NewVectorCollectionHandler creates a new vector collection handler.
+// embeddingService is optional; when set, points and queries may supply text instead of a vector.
+func NewVectorCollectionHandler(storage *KnowledgeBaseStorage, embeddingService *EmbeddingService) *VectorCollectionHandler {
+	return &VectorCollectionHandler{
+		storage:          storage,
+		quotaService:     NewQuotaService(storage),
+		embeddingService: embeddingService,
+	}
+}
+
+// CreateVectorCollectionRequest is the request to create a vector collection
+type CreateVectorCollectionRequest struct {
+	Name           string  `json:"name"`
+	Namespace      string  `json:"namespace,omitempty"`
+	Description    string  `json:"description,omitempty"`
+	Dimensions     int     `json:"dimensions"`
+	DistanceMetric string  `json:"distance_metric,omitempty"`
+	EmbeddingModel *string `json:"embedding_model,omitempty"`
+	QuotaMaxPoints int     `json:"quota_max_points,omitempty"`
+}
+
+// VectorPointInput is a point to insert or upsert. Either Vector or Text must be set.
+type VectorPointInput struct {
+	ID       string         `json:"id"`
+	Vector   []float32      `json:"vector,omitempty"`
+	Text     string         `json:"text,omitempty"`
+	Content  *string        `json:"content,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// VectorQueryRequest is the request to query a collection. Either Vector or Text must be set.
+type VectorQueryRequest struct {
+	Vector         []float32            `json:"vector,omitempty"`
+	Text           string               `json:"text,omitempty"`
+	TopK           int                  `json:"top_k,omitempty"`
+	MinScore       *float64             `json:"min_score,omitempty"`
+	Filter         *MetadataFilterGroup `json:"filter,omitempty"`
+	IncludeVectors bool                 `json:"include_vectors,omitempty"`
+}
+
+// Validate checks the create request and applies defaults
+func (r *CreateVectorCollectionRequest) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if r.Dimensions < 1 || r.Dimensions > 16000 {
+		return fmt.Errorf("dimensions must be between 1 and 16000")
+	}
+	if r.DistanceMetric == "" {
+		r.DistanceMetric = "cosine"
+	}
+	if !IsValidDistanceMetric(r.DistanceMetric) {
+		return fmt.Errorf("distance_metric must be one of: cosine, l2, inner_product")
+	}
+	if r.QuotaMaxPoints < 0 {
+		return fmt.Errorf("quota_max_points must not be negative")
+	}
+	return nil
+}
+
+// ListCollections lists collections accessible to the caller
+// GET /api/v1/ai/collections
+func (h *VectorCollectionHandler) ListCollections(c fiber.Ctx) error {
+	var userID *string
+	if !isVectorCollectionAdmin(c) {
+		id, ok := c.Locals("user_id").(string)
+		if !ok || id == "" {
+			return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeAuthRequired, "Authentication required")
+		}
+		userID = &id
+	}
+
+	collections, err := h.storage.ListVectorCollections(c.RequestCtx(), userID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list vector collections")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list collections")
+	}
+
+	return c.JSON(fiber.Map{
+		"collections": collections,
+		"count":       len(collections),
+	})
+}
+
+// CreateCollection creates a collection owned by the caller
+// POST /api/v1/ai/collections
+func (h *VectorCollectionHandler) CreateCollection(c fiber.Ctx) error {
+	var req CreateVectorCollectionRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
+	}
+
+	collection := &VectorCollection{
+		Name:           req.Name,
+		Namespace:      req.Namespace,
+		Description:    req.Description,
+		Dimensions:     req.Dimensions,
+		DistanceMetric: req.DistanceMetric,
+		EmbeddingModel: req.EmbeddingModel,
+		QuotaMaxPoints: req.QuotaMaxPoints,
+	}
+	if collection.QuotaMaxPoints == 0 {
+		collection.QuotaMaxPoints = DefaultVectorCollectionMaxPoints
+	}
+	if userID, ok := c.Locals("user_id").(string); ok && userID != "" {
+		collection.OwnerID = &userID
+	}
+
+	if err := h.storage.CreateVectorCollection(c.RequestCtx(), collection); err != nil {
+		log.Error().Err(err).Str("name", req.Name).Msg("Failed to create vector collection")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to create collection")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(collection)
+}
+
+// GetCollection returns a collection (requires viewer permission)
+// GET /api/v1/ai/collections/:id
+func (h *VectorCollectionHandler) GetCollection(c fiber.Ctx) error {
+	collection, ok, err := h.loadCollection(c, KBPermissionViewer)
+	if !ok {
+		return err
+	}
+	return c.JSON(collection)
+}
+
+// DeleteCollection deletes a collection and all of its points (requires owner permission)
+// DELETE /api/v1/ai/collections/:id
+func (h *VectorCollectionHandler) DeleteCollection(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+	collection, ok, err := h.loadCollection(c, KBPermissionOwner)
+	if !ok {
+		return err
+	}
+
+	if err := h.storage.DeleteVectorCollection(ctx, collection); err != nil {
+		log.Error().Err(err).Str("collection_id", collection.ID).Msg("Failed to delete vector collection")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to delete collection")
+	}
+
+	// Release the owner's quota usage
+	if collection.OwnerID != nil && collection.PointCount > 0 {
+		if err := h.storage.UpdateUserQuotaUsage(ctx, *collection.OwnerID, 0, -collection.PointCount, 0); err != nil {
+			log.Warn().Err(err).Str("collection_id", collection.ID).Msg("Failed to update quota usage")
+		}
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// InsertPoints inserts new points, rejecting IDs that already exist (requires editor permission)
+// POST /api/v1/ai/collections/:id/points
+func (h *VectorCollectionHandler) InsertPoints(c fiber.Ctx) error {
+	return h.writePoints(c, false)
+}
+
+// UpsertPoints inserts points or replaces existing points with the same ID (requires editor permission)
+// PUT /api/v1/ai/collections/:id/points
+func (h *VectorCollectionHandler) UpsertPoints(c fiber.Ctx) error {
+	return h.writePoints(c, true)
+}
+
+func (h *VectorCollectionHandler) writePoints(c fiber.Ctx, upsert bool) error {
+	ctx := c.RequestCtx()
+	collection, ok, err := h.loadCollection(c, KBPermissionEditor)
+	if !ok {
+		return err
+	}
+
+	var req struct {
+		Points []VectorPointInput `json:"points"`
+	}
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+	}
+	if len(req.Points) == 0 {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "At least one point is required")
+	}
+	if len(req.Points) > MaxVectorPointsPerWrite {
+		return apierror.Send(c, fiber.StatusRequestEntityTooLarge, apierror.CodePayloadTooLarge, fmt.Sprintf("Too many points: %d (maximum %d per request)", len(req.Points), MaxVectorPointsPerWrite))
+	}
+
+	points, err := h.resolvePoints(c, collection, req.Points)
+	if err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
+	}
+
+	// Check quotas against the worst case where every point is new
+	if err := h.checkQuota(c, collection, len(points)); err != nil {
+		return err
+	}
+
+	inserted, err := h.storage.UpsertVectorPoints(ctx, collection, points, upsert)
+	if errors.Is(err, ErrVectorPointExists) {
+		return apierror.Send(c, fiber.StatusConflict, apierror.CodeConflict, err.Error())
+	}
+	if err != nil {
+		log.Error().Err(err).Str("collection_id", collection.ID).Msg("Failed to write vector points")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to write points")
+	}
+
+	if collection.OwnerID != nil && inserted > 0 {
+		if err := h.storage.UpdateUserQuotaUsage(ctx, *collection.OwnerID, 0, inserted, 0); err != nil {
+			log.Warn().Err(err).Str("collection_id", collection.ID).Msg("Failed to update quota usage")
+		}
+	}
+
+	status := fiber.StatusCreated
+	if upsert {
+		status = fiber.StatusOK
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"inserted": inserted,
+		"updated":  len(points) - inserted,
+	})
+}
+
+// DeletePoints deletes points by ID (requires editor permission)
+// POST /api/v1/ai/collections/:id/points/delete
+func (h *VectorCollectionHandler) DeletePoints(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+	collection, ok, err := h.loadCollection(c, KBPermissionEditor)
+	if !ok {
+		return err
+	}
+
+	var req struct {
+		IDs []string `json:"ids"`
+	}
+	if err := c.Bind().Body(&req); err != nil || len(req.IDs) == 0 {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "ids is required")
+	}
+
+	deleted, err := h.storage.DeleteVectorPoints(ctx, collection, req.IDs)
+	if err != nil {
+		log.Error().Err(err).Str("collection_id", collection.ID).Msg("Failed to delete vector points")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to delete points")
+	}
+
+	if collection.OwnerID != nil && deleted > 0 {
+		if err := h.storage.UpdateUserQuotaUsage(ctx, *collection.OwnerID, 0, -deleted, 0); err != nil {
+			log.Warn().Err(err).Str("collection_id", collection.ID).Msg("Failed to update quota usage")
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"deleted": deleted,
+	})
+}
+
+// GetPoint returns a single point with its vector (requires viewer permission)
+// GET /api/v1/ai/collections/:id/points/:point_id
+func (h *VectorCollectionHandler) GetPoint(c fiber.Ctx) error {
+	collection, ok, err := h.loadCollection(c, KBPermissionViewer)
+	if !ok {
+		return err
+	}
+
+	point, err := h.storage.GetVectorPoint(c.RequestCtx(), collection, c.Params("point_id"))
+	if err != nil {
+		log.Error().Err(err).Str("collection_id", collection.ID).Msg("Failed to get vector point")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get point")
+	}
+	if point == nil {
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Point not found")
+	}
+
+	return c.JSON(point)
+}
+
+// Query returns the top-k points most similar to a vector or text (requires viewer permission)
+// POST /api/v1/ai/collections/:id/query
+func (h *VectorCollectionHandler) Query(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+	collection, ok, err := h.loadCollection(c, KBPermissionViewer)
+	if !ok {
+		return err
+	}
+
+	var req VectorQueryRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+	}
+	if req.TopK < 0 || req.TopK > MaxVectorQueryTopK {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, fmt.Sprintf("top_k must be between 1 and %d", MaxVectorQueryTopK))
+	}
+
+	vector := req.Vector
+	if len(vector) == 0 && req.Text != "" {
+		vector, err = h.embedText(c, collection, req.Text)
+		if err != nil {
+			return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
+		}
+	}
+	if len(vector) == 0 {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "vector or text is required")
+	}
+	if err := collection.ValidateVector(vector); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
+	}
+
+	matches, err := h.storage.QueryVectorCollection(ctx, collection, VectorQueryOptions{
+		Vector:         vector,
+		TopK:           req.TopK,
+		MinScore:       req.MinScore,
+		Filter:         req.Filter,
+		IncludeVectors: req.IncludeVectors,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("collection_id", collection.ID).Msg("Vector collection query failed")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Query failed")
+	}
+
+	return c.JSON(fiber.Map{
+		"matches":         matches,
+		"count":           len(matches),
+		"distance_metric": collection.DistanceMetric,
+	})
+}
+
+// ShareCollection grants another user access to a collection (owner only)
+// POST /api/v1/ai/collections/:id/share
+func (h *VectorCollectionHandler) ShareCollection(c fiber.Ctx) error {
+	collection, ok, err := h.loadCollection(c, KBPermissionOwner)
+	if !ok {
+		return err
+	}
+
+	var req struct {
+		UserID     string `json:"user_id"`
+		Permission string `json:"permission"`
+	}
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+	}
+	if req.UserID == "" {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "user_id is required")
+	}
+	switch KBPermission(req.Permission) {
+	case KBPermissionViewer, KBPermissionEditor, KBPermissionOwner:
+	default:
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "permission must be one of: viewer, editor, owner")
+	}
+
+	var grantedBy *string
+	if userID, ok := c.Locals("user_id").(string); ok && userID != "" {
+		grantedBy = &userID
+	}
+
+	grant, err := h.storage.GrantVectorCollectionPermission(c.RequestCtx(), collection.ID, req.UserID, req.Permission, grantedBy)
+	if err != nil {
+		log.Error().Err(err).Str("collection_id", collection.ID).Msg("Failed to grant vector collection permission")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to grant permission")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(grant)
+}
+
+// ListPermissions lists the grants on a collection (owner only)
+// GET /api/v1/ai/collections/:id/permissions
+func (h *VectorCollectionHandler) ListPermissions(c fiber.Ctx) error {
+	collection, ok, err := h.loadCollection(c, KBPermissionOwner)
+	if !ok {
+		return err
+	}
+
+	grants, err := h.storage.ListVectorCollectionPermissions(c.RequestCtx(), collection.ID)
+	if err != nil {
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list permissions")
+	}
+
+	return c.JSON(grants)
+}
+
+// RevokePermission revokes a user's access to a collection (owner only)
+// DELETE /api/v1/ai/collections/:id/permissions/:user_id
+func (h *VectorCollectionHandler) RevokePermission(c fiber.Ctx) error {
+	collection, ok, err := h.loadCollection(c, KBPermissionOwner)
+	if !ok {
+		return err
+	}
+
+	if err := h.storage.RevokeVectorCollectionPermission(c.RequestCtx(), collection.ID, c.Params("user_id")); err != nil {
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to revoke permission")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// loadCollection fetches the collection named by :id and checks the caller's permission.
+// When ok is false the response has already been written and err should be returned.
+func (h *VectorCollectionHandler) loadCollection(c fiber.Ctx, required KBPermission) (*VectorCollection, bool, error) {
+	ctx := c.RequestCtx()
+
+	collection, err := h.storage.GetVectorCollection(ctx, c.Params("id"))
+	if err != nil {
+		log.Error().Err(err).Str("collection_id", c.Params("id")).Msg("Failed to get vector collection")
+		return nil, false, apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get collection")
+	}
+	if collection == nil {
+		return nil, false, apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Collection not found")
+	}
+
+	if isVectorCollectionAdmin(c) {
+		return collection, true, nil
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	if userID == "" {
+		return nil, false, apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Access denied")
+	}
+
+	allowed, err := h.storage.CheckVectorCollectionPermission(ctx, collection, userID, string(required))
+	if err != nil {
+		return nil, false, apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to check permission")
+	}
+	if !allowed {
+		return nil, false, apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Access denied")
+	}
+
+	return collection, true, nil
+}
+
+// checkQuota enforces the collection's point quota and the owner's chunk quota.
+// Points count against the same per-user chunk quota as knowledge base chunks.
+func (h *VectorCollectionHandler) checkQuota(c fiber.Ctx, collection *VectorCollection, additionalPoints int) error {
+	err := h.quotaService.CheckVectorCollectionQuota(collection, additionalPoints)
+	if err == nil && collection.OwnerID != nil {
+		err = h.quotaService.CheckUserQuota(c.RequestCtx(), *collection.OwnerID, 0, additionalPoints, 0)
+	}
+	if err == nil {
+		return nil
+	}
+
+	var quotaErr *QuotaError
+	if errors.As(err, &quotaErr) {
+		return apierror.SendError(c, &apierror.Error{
+			Status:  fiber.StatusForbidden,
+			Code:    apierror.CodeAccessDenied,
+			Message: quotaErr.Error(),
+			Details: fiber.Map{
+				"resource_type": quotaErr.ResourceType,
+				"used":          quotaErr.Used,
+				"limit":         quotaErr.Limit,
+			},
+		})
+	}
+
+	log.Error().Err(err).Str("collection_id", collection.ID).Msg("Failed to check quota")
+	return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to check quota")
+}
+
+// resolvePoints validates point inputs and embeds any text-only points
+func (h *VectorCollectionHandler) resolvePoints(c fiber.Ctx, collection *VectorCollection, inputs []VectorPointInput) ([]VectorPoint, error) {
+	points := make([]VectorPoint, len(inputs))
+	seen := make(map[string]bool, len(inputs))
+
+	var textIdx []int
+	var texts []string
+	for i, in := range inputs {
+		if in.ID == "" {
+			return nil, fmt.Errorf("point %d: id is required", i)
+		}
+		if seen[in.ID] {
+			return nil, fmt.Errorf("point %d: duplicate id %q", i, in.ID)
+		}
+		seen[in.ID] = true
+
+		points[i] = VectorPoint{ID: in.ID, Vector: in.Vector, Content: in.Content, Metadata: in.Metadata}
+		if len(in.Vector) == 0 {
+			if in.Text == "" {
+				return nil, fmt.Errorf("point %q: vector or text is required", in.ID)
+			}
+			textIdx = append(textIdx, i)
+			texts = append(texts, in.Text)
+			if points[i].Content == nil {
+				text := in.Text
+				points[i].Content = &text
+			}
+		}
+	}
+
+	if len(texts) > 0 {
+		if h.embeddingService == nil {
+			return nil, fmt.Errorf("embedding service not configured; provide vectors instead of text")
+		}
+		resp, err := h.embeddingService.Embed(c.RequestCtx(), texts, collectionModel(collection))
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed text: %w", err)
+		}
+		for j, i := range textIdx {
+			points[i].Vector = resp.Embeddings[j]
+		}
+	}
+
+	for _, p := range points {
+		if err := collection.ValidateVector(p.Vector); err != nil {
+			return nil, fmt.Errorf("point %q: %w", p.ID, err)
+		}
+	}
+	return points, nil
+}
+
+// embedText embeds query text with the collection's embedding model
+func (h *VectorCollectionHandler) embedText(c fiber.Ctx, collection *VectorCollection, text string) ([]float32, error) {
+	if h.embeddingService == nil {
+		return nil, fmt.Errorf("embedding service not configured; provide a vector instead of text")
+	}
+	vector, err := h.embeddingService.EmbedSingle(c.RequestCtx(), text, collectionModel(collection))
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed text: %w", err)
+	}
+	return vector, nil
+}
+
+func collectionModel(collection *VectorCollection) string {
+	if collection.EmbeddingModel != nil {
+		return *collection.EmbeddingModel
+	}
+	return ""
+}
+
+// isVectorCollectionAdmin returns true for roles that bypass collection permissions
+func isVectorCollectionAdmin(c fiber.Ctx) bool {
+	role, _ := c.Locals("user_role").(string)
+	return role == "admin" || role == "dashboard_admin" || role == "service_role"
+}
+
+// RegisterVectorCollectionRoutes registers the vector collection routes
+func RegisterVectorCollectionRoutes(router fiber.Router, storage *KnowledgeBaseStorage, embeddingService *EmbeddingService) {
+	handler := NewVectorCollectionHandler(storage, embeddingService)
+
+	router.Get("/collections", handler.ListCollections)
+	router.Post("/collections", handler.CreateCollection)
+	router.Get("/collections/:id", handler.GetCollection)
+	router.Delete("/collections/:id", handler.DeleteCollection)
+
+	router.Post("/collections/:id/points", handler.InsertPoints)
+	router.Put("/collections/:id/points", handler.UpsertPoints)
+	router.Post("/collections/:id/points/delete", handler.DeletePoints)
+	router.Get("/collections/:id/points/:point_id", handler.GetPoint)
+
+	router.Post("/collections/:id/query", handler.Query)
+
+	router.Post("/collections/:id/share", handler.ShareCollection)
+	router.Get("/collections/:id/permissions", handler.ListPermissions)
+	router.Delete("/collections/:id/permissions/:user_id", handler.RevokePermission)
+}
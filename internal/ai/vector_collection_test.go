@@ -0,0 +1,116 @@
+package ai
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateVectorCollectionRequest_Validate(t *testing.T) {
+	t.Run("applies default distance metric", func(t *testing.T) {
+		req := CreateVectorCollectionRequest{Name: "products", Dimensions: 384}
+		require.NoError(t, req.Validate())
+		assert.Equal(t, "cosine", req.DistanceMetric)
+	})
+
+	tests := []struct {
+		name    string
+		req     CreateVectorCollectionRequest
+		wantErr string
+	}{
+		{"missing name", CreateVectorCollectionRequest{Dimensions: 3}, "name is required"},
+		{"zero dimensions", CreateVectorCollectionRequest{Name: "a"}, "dimensions"},
+		{"too many dimensions", CreateVectorCollectionRequest{Name: "a", Dimensions: 16001}, "dimensions"},
+		{"unknown metric", CreateVectorCollectionRequest{Name: "a", Dimensions: 3, DistanceMetric: "hamming"}, "distance_metric"},
+		{"negative quota", CreateVectorCollectionRequest{Name: "a", Dimensions: 3, QuotaMaxPoints: -1}, "quota_max_points"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestVectorScore(t *testing.T) {
+	assert.InDelta(t, 0.75, vectorScore("cosine", 0.25), 1e-9)
+	assert.InDelta(t, -2.5, vectorScore("l2", 2.5), 1e-9)
+	// pgvector's <#> returns the negative inner product
+	assert.InDelta(t, 0.9, vectorScore("inner_product", -0.9), 1e-9)
+}
+
+func TestVectorCollection_Helpers(t *testing.T) {
+	collection := &VectorCollection{
+		ID:             "0b6e8c3a-1f2d-4c5e-9a7b-123456789abc",
+		Name:           "products",
+		Dimensions:     3,
+		DistanceMetric: "cosine",
+	}
+
+	t.Run("index name is derived from the collection ID", func(t *testing.T) {
+		assert.Equal(t, "idx_ai_vector_points_0b6e8c3a1f2d4c5e9a7b123456789abc", collection.indexName())
+	})
+
+	t.Run("embedding expression casts to the collection dimensions", func(t *testing.T) {
+		assert.Equal(t, "(p.embedding::vector(3))", collection.embeddingExpr("p.embedding"))
+	})
+
+	t.Run("validates vector dimensions", func(t *testing.T) {
+		assert.NoError(t, collection.ValidateVector([]float32{1, 2, 3}))
+		err := collection.ValidateVector([]float32{1, 2})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "expects 3")
+	})
+
+	t.Run("supported distance metrics", func(t *testing.T) {
+		for _, metric := range []string{"cosine", "l2", "inner_product"} {
+			assert.True(t, IsValidDistanceMetric(metric), metric)
+		}
+		assert.False(t, IsValidDistanceMetric("dot"))
+	})
+}
+
+func TestParseEmbeddingLiteral(t *testing.T) {
+	v := []float32{0.5, -1.25, 3}
+	parsed, err := parseEmbeddingLiteral(formatEmbeddingLiteral(v))
+	require.NoError(t, err)
+	assert.Equal(t, v, parsed)
+
+	_, err = parseEmbeddingLiteral("not a vector")
+	assert.Error(t, err)
+}
+
+func TestQuotaService_CheckVectorCollectionQuota(t *testing.T) {
+	svc := NewQuotaService(&KnowledgeBaseStorage{})
+	collection := &VectorCollection{PointCount: 90, QuotaMaxPoints: 100}
+
+	assert.NoError(t, svc.CheckVectorCollectionQuota(collection, 10))
+
+	err := svc.CheckVectorCollectionQuota(collection, 11)
+	require.Error(t, err)
+	assert.True(t, IsQuotaError(err))
+	assert.Contains(t, err.Error(), "points")
+
+	assert.NoError(t, svc.CheckVectorCollectionQuota(&VectorCollection{PointCount: 5}, 1000), "zero quota is unlimited")
+}
+
+func TestVectorCollectionHandler_CreateCollectionValidation(t *testing.T) {
+	app := fiber.New()
+	handler := NewVectorCollectionHandler(&KnowledgeBaseStorage{}, nil)
+	app.Post("/collections", handler.CreateCollection)
+
+	req := httptest.NewRequest("POST", "/collections", strings.NewReader(`{"name":"products","dimensions":0}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "dimensions")
+}
@@ -0,0 +1,460 @@
+package ai
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// ChatWidget exposes a chatbot to anonymous website visitors through a public key
+type ChatWidget struct {
+	ID                 string    `json:"id"`
+	Name               string    `json:"name"`
+	ChatbotID          string    `json:"chatbot_id"`
+	ChatbotName        *string   `json:"chatbot_name,omitempty"`
+	PublicKey          string    `json:"public_key"`
+	AllowedDomains     []string  `json:"allowed_domains"`
+	RateLimitPerMinute int       `json:"rate_limit_per_minute"`
+	SessionTTLHours    int       `json:"session_ttl_hours"`
+	RequireCaptcha     bool      `json:"require_captcha"`
+	CollectLeads       bool      `json:"collect_leads"`
+	Enabled            bool      `json:"enabled"`
+	CreatedBy          *string   `json:"created_by,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// WidgetSession is an anonymous visitor session created through a chat widget
+type WidgetSession struct {
+	ID             string         `json:"id"`
+	WidgetID       string         `json:"widget_id"`
+	ConversationID string         `json:"conversation_id"`
+	VisitorID      string         `json:"visitor_id"`
+	Origin         *string        `json:"origin,omitempty"`
+	IPAddress      *string        `json:"ip_address,omitempty"`
+	UserAgent      *string        `json:"user_agent,omitempty"`
+	LeadEmail      *string        `json:"lead_email,omitempty"`
+	LeadName       *string        `json:"lead_name,omitempty"`
+	LeadMetadata   map[string]any `json:"lead_metadata,omitempty"`
+	LeadCapturedAt *time.Time     `json:"lead_captured_at,omitempty"`
+	MessageCount   int            `json:"message_count"`
+	ExpiresAt      time.Time      `json:"expires_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	LastSeenAt     time.Time      `json:"last_seen_at"`
+}
+
+// IsExpired returns true if the session can no longer be used
+func (s *WidgetSession) IsExpired() bool {
+	return time.Now().After(s.ExpiresAt)
+}
+
+// Widget key and session token prefixes
+const (
+	widgetPublicKeyPrefix    = "fbw_"
+	widgetSessionTokenPrefix = "fbws_"
+)
+
+// GenerateWidgetPublicKey generates a new public widget key
+func GenerateWidgetPublicKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate widget key: %w", err)
+	}
+	return widgetPublicKeyPrefix + hex.EncodeToString(b), nil
+}
+
+// generateWidgetSessionToken generates a bearer token for a widget session
+func generateWidgetSessionToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session token: %w", err)
+	}
+	return widgetSessionTokenPrefix + hex.EncodeToString(b), nil
+}
+
+// hashWidgetSessionToken hashes a session token for storage.
+// SECURITY: Session tokens are stored as hashes to prevent exposure if the database is breached.
+func hashWidgetSessionToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
+
+// NormalizeWidgetDomain lowercases a domain entry and strips any scheme, port or path,
+// so admins can paste either "example.com" or "https://example.com/"
+func NormalizeWidgetDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if domain == "" {
+		return ""
+	}
+	if strings.Contains(domain, "://") {
+		if u, err := url.Parse(domain); err == nil {
+			domain = u.Host
+		}
+	}
+	if idx := strings.IndexAny(domain, "/?#"); idx >= 0 {
+		domain = domain[:idx]
+	}
+	if idx := strings.LastIndex(domain, ":"); idx >= 0 && !strings.Contains(domain, "]") {
+		domain = domain[:idx]
+	}
+	return domain
+}
+
+// OriginHost extracts the hostname from an Origin or Referer header value
+func OriginHost(origin string) string {
+	if origin == "" || origin == "null" {
+		return ""
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// IsWidgetOriginAllowed checks an origin hostname against a widget domain allowlist.
+// Entries match exactly, and "*.example.com" matches any subdomain of example.com
+// (but not example.com itself). An empty allowlist allows nothing.
+func IsWidgetOriginAllowed(host string, allowedDomains []string) bool {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if host == "" {
+		return false
+	}
+	for _, entry := range allowedDomains {
+		domain := NormalizeWidgetDomain(entry)
+		if domain == "" {
+			continue
+		}
+		if suffix, ok := strings.CutPrefix(domain, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == domain {
+			return true
+		}
+	}
+	return false
+}
+
+// ============================================================================
+// WIDGET STORAGE OPERATIONS
+// ============================================================================
+
+// widgetColumns is the column list shared by widget queries
+const widgetColumns = `
+	w.id, w.name, w.chatbot_id, cb.name, w.public_key, w.allowed_domains,
+	w.rate_limit_per_minute, w.session_ttl_hours, w.require_captcha, w.collect_leads,
+	w.enabled, w.created_by, w.created_at, w.updated_at
+`
+
+// widgetSessionColumns is the column list shared by widget session queries
+const widgetSessionColumns = `
+	id, widget_id, conversation_id, visitor_id, origin, ip_address, user_agent,
+	lead_email, lead_name, lead_metadata, lead_captured_at, message_count,
+	expires_at, created_at, last_seen_at
+`
+
+// CreateWidget stores a new chat widget
+func (s *Storage) CreateWidget(ctx context.Context, widget *ChatWidget) error {
+	query := `
+		INSERT INTO ai.chat_widgets (
+			name, chatbot_id, public_key, allowed_domains, rate_limit_per_minute,
+			session_ttl_hours, require_captcha, collect_leads, enabled, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := s.db.QueryRow(ctx, query,
+		widget.Name, widget.ChatbotID, widget.PublicKey, widget.AllowedDomains, widget.RateLimitPerMinute,
+		widget.SessionTTLHours, widget.RequireCaptcha, widget.CollectLeads, widget.Enabled, widget.CreatedBy,
+	).Scan(&widget.ID, &widget.CreatedAt, &widget.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create widget: %w", err)
+	}
+
+	log.Info().Str("id", widget.ID).Str("name", widget.Name).Msg("Created chat widget")
+	return nil
+}
+
+// UpdateWidget updates a chat widget's settings (the public key is rotated separately)
+func (s *Storage) UpdateWidget(ctx context.Context, widget *ChatWidget) error {
+	query := `
+		UPDATE ai.chat_widgets SET
+			name = $2,
+			chatbot_id = $3,
+			allowed_domains = $4,
+			rate_limit_per_minute = $5,
+			session_ttl_hours = $6,
+			require_captcha = $7,
+			collect_leads = $8,
+			enabled = $9
+		WHERE id = $1
+	`
+
+	result, err := s.db.Exec(ctx, query,
+		widget.ID, widget.Name, widget.ChatbotID, widget.AllowedDomains, widget.RateLimitPerMinute,
+		widget.SessionTTLHours, widget.RequireCaptcha, widget.CollectLeads, widget.Enabled,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update widget: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("widget not found: %s", widget.ID)
+	}
+	return nil
+}
+
+// RotateWidgetKey replaces a widget's public key
+func (s *Storage) RotateWidgetKey(ctx context.Context, id, publicKey string) error {
+	result, err := s.db.Exec(ctx, `UPDATE ai.chat_widgets SET public_key = $2 WHERE id = $1`, id, publicKey)
+	if err != nil {
+		return fmt.Errorf("failed to rotate widget key: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("widget not found: %s", id)
+	}
+	return nil
+}
+
+// DeleteWidget deletes a chat widget and its sessions
+func (s *Storage) DeleteWidget(ctx context.Context, id string) error {
+	result, err := s.db.Exec(ctx, `DELETE FROM ai.chat_widgets WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete widget: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("widget not found: %s", id)
+	}
+	return nil
+}
+
+// GetWidget retrieves a chat widget by ID
+func (s *Storage) GetWidget(ctx context.Context, id string) (*ChatWidget, error) {
+	return s.getWidgetBy(ctx, "w.id", id)
+}
+
+// GetWidgetByPublicKey retrieves a chat widget by its public key
+func (s *Storage) GetWidgetByPublicKey(ctx context.Context, publicKey string) (*ChatWidget, error) {
+	return s.getWidgetBy(ctx, "w.public_key", publicKey)
+}
+
+// getWidgetBy retrieves a single widget matching the given column
+func (s *Storage) getWidgetBy(ctx context.Context, column, value string) (*ChatWidget, error) {
+	query := `SELECT ` + widgetColumns + `
+		FROM ai.chat_widgets w
+		LEFT JOIN ai.chatbots cb ON cb.id = w.chatbot_id
+		WHERE ` + column + ` = $1
+	`
+
+	widget, err := scanWidget(s.db.QueryRow(ctx, query, value))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get widget: %w", err)
+	}
+	return widget, nil
+}
+
+// ListWidgets lists all chat widgets
+func (s *Storage) ListWidgets(ctx context.Context) ([]*ChatWidget, error) {
+	query := `SELECT ` + widgetColumns + `
+		FROM ai.chat_widgets w
+		LEFT JOIN ai.chatbots cb ON cb.id = w.chatbot_id
+		ORDER BY w.name
+	`
+
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list widgets: %w", err)
+	}
+	defer rows.Close()
+
+	widgets := make([]*ChatWidget, 0)
+	for rows.Next() {
+		widget, err := scanWidget(rows)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to scan widget")
+			continue
+		}
+		widgets = append(widgets, widget)
+	}
+	return widgets, nil
+}
+
+// ListWidgetAllowedDomains returns the union of allowed domains across enabled widgets
+func (s *Storage) ListWidgetAllowedDomains(ctx context.Context) ([]string, error) {
+	query := `SELECT DISTINCT unnest(allowed_domains) FROM ai.chat_widgets WHERE enabled = true`
+
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list widget domains: %w", err)
+	}
+	defer rows.Close()
+
+	domains := make([]string, 0)
+	for rows.Next() {
+		var domain string
+		if err := rows.Scan(&domain); err != nil {
+			continue
+		}
+		domains = append(domains, domain)
+	}
+	return domains, nil
+}
+
+// scanWidget scans a widget row selected with widgetColumns
+func scanWidget(row pgx.Row) (*ChatWidget, error) {
+	widget := &ChatWidget{}
+	err := row.Scan(
+		&widget.ID, &widget.Name, &widget.ChatbotID, &widget.ChatbotName, &widget.PublicKey, &widget.AllowedDomains,
+		&widget.RateLimitPerMinute, &widget.SessionTTLHours, &widget.RequireCaptcha, &widget.CollectLeads,
+		&widget.Enabled, &widget.CreatedBy, &widget.CreatedAt, &widget.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if widget.AllowedDomains == nil {
+		widget.AllowedDomains = []string{}
+	}
+	return widget, nil
+}
+
+// CreateWidgetSession stores a new visitor session and returns the plaintext bearer token.
+// The token is only available at creation time.
+func (s *Storage) CreateWidgetSession(ctx context.Context, session *WidgetSession) (string, error) {
+	token, err := generateWidgetSessionToken()
+	if err != nil {
+		return "", err
+	}
+
+	query := `
+		INSERT INTO ai.chat_widget_sessions (
+			widget_id, conversation_id, visitor_id, token_hash, origin, ip_address, user_agent, expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, last_seen_at
+	`
+
+	err = s.db.QueryRow(ctx, query,
+		session.WidgetID, session.ConversationID, session.VisitorID, hashWidgetSessionToken(token),
+		session.Origin, session.IPAddress, session.UserAgent, session.ExpiresAt,
+	).Scan(&session.ID, &session.CreatedAt, &session.LastSeenAt)
+	if err != nil {
+		return "", fmt.Errorf("failed to create widget session: %w", err)
+	}
+	return token, nil
+}
+
+// GetWidgetSessionByToken retrieves a session by its plaintext bearer token
+func (s *Storage) GetWidgetSessionByToken(ctx context.Context, token string) (*WidgetSession, error) {
+	query := `SELECT ` + widgetSessionColumns + ` FROM ai.chat_widget_sessions WHERE token_hash = $1`
+
+	session, err := scanWidgetSession(s.db.QueryRow(ctx, query, hashWidgetSessionToken(token)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get widget session: %w", err)
+	}
+	return session, nil
+}
+
+// ListWidgetSessions lists the sessions of a widget, newest first
+func (s *Storage) ListWidgetSessions(ctx context.Context, widgetID string, leadsOnly bool, limit, offset int) ([]*WidgetSession, int, error) {
+	where := ` WHERE widget_id = $1`
+	if leadsOnly {
+		where += ` AND lead_email IS NOT NULL`
+	}
+
+	var total int
+	if err := s.db.QueryRow(ctx, `SELECT COUNT(*) FROM ai.chat_widget_sessions`+where, widgetID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count widget sessions: %w", err)
+	}
+
+	query := `SELECT ` + widgetSessionColumns + ` FROM ai.chat_widget_sessions` + where +
+		` ORDER BY created_at DESC LIMIT $2 OFFSET $3`
+
+	rows, err := s.db.Query(ctx, query, widgetID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list widget sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]*WidgetSession, 0)
+	for rows.Next() {
+		session, err := scanWidgetSession(rows)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to scan widget session")
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, total, nil
+}
+
+// TouchWidgetSession records activity on a session
+func (s *Storage) TouchWidgetSession(ctx context.Context, id string) error {
+	query := `
+		UPDATE ai.chat_widget_sessions
+		SET message_count = message_count + 1, last_seen_at = NOW()
+		WHERE id = $1
+	`
+	if _, err := s.db.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to update widget session: %w", err)
+	}
+	return nil
+}
+
+// SetWidgetSessionLead links lead details captured by the widget to a session
+func (s *Storage) SetWidgetSessionLead(ctx context.Context, id, email string, name *string, metadata map[string]any) error {
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal lead metadata: %w", err)
+	}
+
+	query := `
+		UPDATE ai.chat_widget_sessions
+		SET lead_email = $2, lead_name = $3, lead_metadata = $4, lead_captured_at = NOW()
+		WHERE id = $1
+	`
+	if _, err := s.db.Exec(ctx, query, id, email, name, metadataJSON); err != nil {
+		return fmt.Errorf("failed to set widget session lead: %w", err)
+	}
+	return nil
+}
+
+// scanWidgetSession scans a session row selected with widgetSessionColumns
+func scanWidgetSession(row pgx.Row) (*WidgetSession, error) {
+	session := &WidgetSession{}
+	var metadataJSON []byte
+	err := row.Scan(
+		&session.ID, &session.WidgetID, &session.ConversationID, &session.VisitorID,
+		&session.Origin, &session.IPAddress, &session.UserAgent,
+		&session.LeadEmail, &session.LeadName, &metadataJSON, &session.LeadCapturedAt, &session.MessageCount,
+		&session.ExpiresAt, &session.CreatedAt, &session.LastSeenAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(metadataJSON) > 0 {
+		if err := json.Unmarshal(metadataJSON, &session.LeadMetadata); err != nil {
+			log.Warn().Err(err).Str("session_id", session.ID).Msg("Failed to unmarshal lead metadata")
+		}
+	}
+	return session, nil
+}
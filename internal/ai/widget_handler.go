@@ -0,0 +1,739 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog/log"
+
+	"github.com/nimbleflux/fluxbase/internal/apierror"
+	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/nimbleflux/fluxbase/internal/middleware"
+	"github.com/nimbleflux/fluxbase/internal/ratelimit"
+)
+
+// WidgetCaptchaVerifier verifies CAPTCHA tokens for widget sessions (satisfied by auth.CaptchaService)
+type WidgetCaptchaVerifier interface {
+	IsEnabled() bool
+	Verify(ctx context.Context, token string, remoteIP string) error
+	GetConfig() auth.CaptchaConfigResponse
+}
+
+// widgetDomainCacheTTL controls how often the CORS allowlist is refreshed from the database
+const widgetDomainCacheTTL = 30 * time.Second
+
+// WidgetHandler serves the public chat widget API and its admin endpoints
+type WidgetHandler struct {
+	storage *Storage
+	chat    *ChatHandler
+	captcha WidgetCaptchaVerifier
+
+	domainsMu       sync.RWMutex
+	domains         []string
+	domainsLoadedAt time.Time
+}
+
+// NewWidgetHandler creates a new widget handler. captcha may be nil.
+func NewWidgetHandler(storage *Storage, chat *ChatHandler, captcha WidgetCaptchaVerifier) *WidgetHandler {
+	return &WidgetHandler{
+		storage: storage,
+		chat:    chat,
+		captcha: captcha,
+	}
+}
+
+// ============================================================================
+// CORS
+// ============================================================================
+
+// CORS allows cross-origin requests from domains on any enabled widget's allowlist.
+// It must be registered before the global CORS middleware so preflight requests
+// from customer websites are answered here.
+func (h *WidgetHandler) CORS(c fiber.Ctx) error {
+	origin := c.Get(fiber.HeaderOrigin)
+	if origin == "" || !IsWidgetOriginAllowed(OriginHost(origin), h.allowedDomains(c.RequestCtx())) {
+		return c.Next()
+	}
+
+	c.Vary(fiber.HeaderOrigin)
+	c.Set(fiber.HeaderAccessControlAllowOrigin, origin)
+
+	if c.Method() == fiber.MethodOptions {
+		c.Set(fiber.HeaderAccessControlAllowMethods, "GET, POST, OPTIONS")
+		c.Set(fiber.HeaderAccessControlAllowHeaders, "Authorization, Content-Type")
+		c.Set(fiber.HeaderAccessControlMaxAge, "600")
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+
+	return c.Next()
+}
+
+// allowedDomains returns the cached union of enabled widget allowlists
+func (h *WidgetHandler) allowedDomains(ctx context.Context) []string {
+	h.domainsMu.RLock()
+	if time.Since(h.domainsLoadedAt) < widgetDomainCacheTTL {
+		domains := h.domains
+		h.domainsMu.RUnlock()
+		return domains
+	}
+	h.domainsMu.RUnlock()
+
+	domains, err := h.storage.ListWidgetAllowedDomains(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load widget allowed domains")
+	}
+
+	h.domainsMu.Lock()
+	defer h.domainsMu.Unlock()
+	if err == nil {
+		h.domains = domains
+	}
+	h.domainsLoadedAt = time.Now()
+	return h.domains
+}
+
+// invalidateDomains forces the CORS allowlist to be reloaded on the next request
+func (h *WidgetHandler) invalidateDomains() {
+	h.domainsMu.Lock()
+	h.domainsLoadedAt = time.Time{}
+	h.domainsMu.Unlock()
+}
+
+// ============================================================================
+// PUBLIC ENDPOINTS
+// ============================================================================
+
+// CreateWidgetSessionRequest is the request body for starting a widget session
+type CreateWidgetSessionRequest struct {
+	WidgetKey    string `json:"widget_key"`
+	VisitorID    string `json:"visitor_id"`
+	CaptchaToken string `json:"captcha_token,omitempty"`
+	// Metadata and tags of the visitor's conversation
+	ConversationAttributes
+}
+
+// WidgetMessageRequest is the request body for sending a widget message
+type WidgetMessageRequest struct {
+	Content string `json:"content"`
+}
+
+// WidgetLeadRequest is the request body for capturing a lead from a widget session
+type WidgetLeadRequest struct {
+	Email    string         `json:"email"`
+	Name     *string        `json:"name,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// maxWidgetVisitorIDLength bounds client-supplied visitor identifiers
+const maxWidgetVisitorIDLength = 128
+
+// GetWidgetConfig returns the public configuration for a widget key
+// GET /api/v1/ai/widget/config?key=...
+func (h *WidgetHandler) GetWidgetConfig(c fiber.Ctx) error {
+	widget, errResp := h.resolveWidget(c, c.Query("key"))
+	if widget == nil {
+		return errResp
+	}
+
+	response := fiber.Map{
+		"name":            widget.Name,
+		"require_captcha": widget.RequireCaptcha,
+		"collect_leads":   widget.CollectLeads,
+	}
+	if widget.RequireCaptcha && h.captcha != nil {
+		response["captcha"] = h.captcha.GetConfig()
+	}
+	return c.JSON(response)
+}
+
+// CreateSession starts a new anonymous visitor session for a widget
+// POST /api/v1/ai/widget/sessions
+func (h *WidgetHandler) CreateSession(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+
+	var req CreateWidgetSessionRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+	}
+
+	req.VisitorID = strings.TrimSpace(req.VisitorID)
+	if req.VisitorID == "" || len(req.VisitorID) > maxWidgetVisitorIDLength {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, fmt.Sprintf("visitor_id is required and must be at most %d characters", maxWidgetVisitorIDLength))
+	}
+	if err := req.Normalize(); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
+	}
+
+	widget, errResp := h.resolveWidget(c, req.WidgetKey)
+	if widget == nil {
+		return errResp
+	}
+
+	if limited := h.checkRateLimit(c, widget, "session:ip:"+c.IP()); limited != nil {
+		return limited
+	}
+
+	if widget.RequireCaptcha {
+		if h.captcha == nil || !h.captcha.IsEnabled() {
+			log.Warn().Str("widget_id", widget.ID).Msg("Widget requires CAPTCHA but CAPTCHA is not configured")
+			return apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "CAPTCHA is not configured")
+		}
+		if err := h.captcha.Verify(ctx, req.CaptchaToken, c.IP()); err != nil {
+			return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "CAPTCHA verification failed")
+		}
+	}
+
+	chatbot, errResp := h.loadChatbot(c, widget)
+	if chatbot == nil {
+		return errResp
+	}
+
+	// Widget transcripts must survive the request, so always persist them
+	persisted := *chatbot
+	persisted.PersistConversations = true
+	sessionRef := "widget:" + widget.ID + ":" + req.VisitorID
+
+	state, err := h.chat.conversations.CreateConversation(ctx, &persisted, nil, &sessionRef, &req.ConversationAttributes)
+	if err != nil {
+		log.Error().Err(err).Str("widget_id", widget.ID).Msg("Failed to create widget conversation")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to create conversation")
+	}
+
+	origin := c.Get(fiber.HeaderOrigin)
+	ip := c.IP()
+	userAgent := c.Get(fiber.HeaderUserAgent)
+	session := &WidgetSession{
+		WidgetID:       widget.ID,
+		ConversationID: state.ID,
+		VisitorID:      req.VisitorID,
+		Origin:         &origin,
+		IPAddress:      &ip,
+		UserAgent:      &userAgent,
+		ExpiresAt:      time.Now().Add(time.Duration(widget.SessionTTLHours) * time.Hour),
+	}
+
+	token, err := h.storage.CreateWidgetSession(ctx, session)
+	if err != nil {
+		log.Error().Err(err).Str("widget_id", widget.ID).Msg("Failed to create widget session")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to create session")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"session_token":   token,
+		"conversation_id": state.ID,
+		"expires_at":      session.ExpiresAt,
+		"collect_leads":   widget.CollectLeads,
+	})
+}
+
+// SendMessage sends a visitor message and returns the chatbot's reply
+// POST /api/v1/ai/widget/messages
+func (h *WidgetHandler) SendMessage(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+
+	session, widget, errResp := h.resolveSession(c)
+	if session == nil {
+		return errResp
+	}
+
+	var req WidgetMessageRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+	}
+	req.Content = strings.TrimSpace(req.Content)
+	if req.Content == "" {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "content is required")
+	}
+
+	// Limit per visitor and per IP so rotating either alone does not bypass the limit
+	if limited := h.checkRateLimit(c, widget, "visitor:"+session.VisitorID); limited != nil {
+		return limited
+	}
+	if limited := h.checkRateLimit(c, widget, "ip:"+c.IP()); limited != nil {
+		return limited
+	}
+
+	chatbot, errResp := h.loadChatbot(c, widget)
+	if chatbot == nil {
+		return errResp
+	}
+
+	state, err := h.chat.conversations.GetConversation(ctx, session.ConversationID)
+	if err != nil || state == nil {
+		if err != nil {
+			log.Error().Err(err).Str("conversation_id", session.ConversationID).Msg("Failed to load widget conversation")
+		}
+		return apierror.Send(c, fiber.StatusGone, apierror.CodeGone, "Conversation is no longer available")
+	}
+
+	reply := &widgetReply{ConversationID: session.ConversationID}
+	chatCtx := &ChatContext{
+		Role:          "anon",
+		IPAddress:     c.IP(),
+		UserAgent:     c.Get(fiber.HeaderUserAgent),
+		Conversations: map[string]*ConversationState{state.ID: state},
+		ActiveChatbot: chatbot,
+		sink:          reply.collect,
+	}
+
+	h.chat.handleMessage(ctx, chatCtx, &ClientMessage{
+		Type:           "message",
+		ConversationID: session.ConversationID,
+		Content:        req.Content,
+	})
+
+	if err := h.storage.TouchWidgetSession(ctx, session.ID); err != nil {
+		log.Warn().Err(err).Str("session_id", session.ID).Msg("Failed to update widget session")
+	}
+
+	if reply.Error != "" {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(reply)
+	}
+	return c.JSON(reply)
+}
+
+// CaptureLead links visitor contact details to the session's transcript
+// POST /api/v1/ai/widget/lead
+func (h *WidgetHandler) CaptureLead(c fiber.Ctx) error {
+	session, widget, errResp := h.resolveSession(c)
+	if session == nil {
+		return errResp
+	}
+
+	if !widget.CollectLeads {
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeFeatureDisabled, "Lead collection is not enabled for this widget")
+	}
+
+	var req WidgetLeadRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+	}
+	req.Email = strings.TrimSpace(req.Email)
+	if err := auth.ValidateEmail(req.Email); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "A valid email is required")
+	}
+
+	if err := h.storage.SetWidgetSessionLead(c.RequestCtx(), session.ID, req.Email, req.Name, req.Metadata); err != nil {
+		log.Error().Err(err).Str("session_id", session.ID).Msg("Failed to capture widget lead")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to save lead")
+	}
+
+	return c.JSON(fiber.Map{
+		"success":         true,
+		"conversation_id": session.ConversationID,
+	})
+}
+
+// GetTranscript returns the visitor-visible transcript of the session's conversation
+// GET /api/v1/ai/widget/transcript
+func (h *WidgetHandler) GetTranscript(c fiber.Ctx) error {
+	session, _, errResp := h.resolveSession(c)
+	if session == nil {
+		return errResp
+	}
+
+	state, err := h.chat.conversations.GetConversation(c.RequestCtx(), session.ConversationID)
+	if err != nil {
+		log.Error().Err(err).Str("conversation_id", session.ConversationID).Msg("Failed to load widget conversation")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to load transcript")
+	}
+
+	messages := []EscalationRecord{}
+	if state != nil {
+		messages = BuildEscalationTranscript(state.Messages)
+	}
+
+	return c.JSON(fiber.Map{
+		"conversation_id": session.ConversationID,
+		"messages":        messages,
+	})
+}
+
+// widgetReply aggregates the streamed chat messages into a single HTTP response
+type widgetReply struct {
+	ConversationID string      `json:"conversation_id"`
+	Content        string      `json:"content"`
+	Escalated      bool        `json:"escalated,omitempty"`
+	EscalationID   string      `json:"escalation_id,omitempty"`
+	Usage          *UsageStats `json:"usage,omitempty"`
+	Error          string      `json:"error,omitempty"`
+	Code           string      `json:"code,omitempty"`
+
+	content strings.Builder
+}
+
+// collect receives messages from the chat handler in place of a WebSocket
+func (r *widgetReply) collect(msg ServerMessage) {
+	switch msg.Type {
+	case "content":
+		r.content.WriteString(msg.Delta)
+		r.Content = r.content.String()
+	case "escalated":
+		r.Escalated = true
+		r.EscalationID = msg.EscalationID
+	case "done":
+		r.Usage = msg.Usage
+	case "error":
+		r.Error = msg.Error
+		r.Code = msg.Code
+	}
+}
+
+// resolveWidget loads an enabled widget by public key and checks the request origin
+// against its allowlist. On failure it returns a nil widget and the error response.
+func (h *WidgetHandler) resolveWidget(c fiber.Ctx, publicKey string) (*ChatWidget, error) {
+	if publicKey == "" {
+		return nil, apierror.Send(c, fiber.StatusBadRequest, apierror.CodeMissingField, "Widget key is required")
+	}
+
+	widget, err := h.storage.GetWidgetByPublicKey(c.RequestCtx(), publicKey)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get widget")
+		return nil, apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get widget")
+	}
+	if widget == nil || !widget.Enabled {
+		return nil, apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Widget not found")
+	}
+
+	if !IsWidgetOriginAllowed(requestOriginHost(c), widget.AllowedDomains) {
+		return nil, apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Origin not allowed for this widget")
+	}
+
+	return widget, nil
+}
+
+// resolveSession authenticates the widget session bearer token.
+// On failure it returns a nil session and the error response.
+func (h *WidgetHandler) resolveSession(c fiber.Ctx) (*WidgetSession, *ChatWidget, error) {
+	token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if !ok || !strings.HasPrefix(token, widgetSessionTokenPrefix) {
+		return nil, nil, apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeAuthRequired, "Widget session token required")
+	}
+
+	ctx := c.RequestCtx()
+	session, err := h.storage.GetWidgetSessionByToken(ctx, token)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get widget session")
+		return nil, nil, apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get session")
+	}
+	if session == nil || session.IsExpired() {
+		return nil, nil, apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeExpiredToken, "Invalid or expired widget session")
+	}
+
+	widget, err := h.storage.GetWidget(ctx, session.WidgetID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get widget")
+		return nil, nil, apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get widget")
+	}
+	if widget == nil || !widget.Enabled {
+		return nil, nil, apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Widget not found")
+	}
+
+	if !IsWidgetOriginAllowed(requestOriginHost(c), widget.AllowedDomains) {
+		return nil, nil, apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Origin not allowed for this widget")
+	}
+
+	return session, widget, nil
+}
+
+// loadChatbot loads the widget's chatbot. On failure it returns nil and the error response.
+func (h *WidgetHandler) loadChatbot(c fiber.Ctx, widget *ChatWidget) (*Chatbot, error) {
+	chatbot, err := h.storage.GetChatbot(c.RequestCtx(), widget.ChatbotID)
+	if err != nil {
+		log.Error().Err(err).Str("chatbot_id", widget.ChatbotID).Msg("Failed to get widget chatbot")
+		return nil, apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get chatbot")
+	}
+	if chatbot == nil || !chatbot.Enabled {
+		return nil, apierror.Send(c, fiber.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Chatbot not available")
+	}
+	return chatbot, nil
+}
+
+// checkRateLimit applies the widget's per-minute limit to the given identifier.
+// Returns a non-nil error response when the limit is exceeded. Fails open if no store is available.
+func (h *WidgetHandler) checkRateLimit(c fiber.Ctx, widget *ChatWidget, identifier string) error {
+	store := ratelimit.GetGlobalStore()
+	if store == nil {
+		return nil
+	}
+
+	key := fmt.Sprintf("ai_widget:%s:%s", widget.ID, identifier)
+	result, err := ratelimit.Check(c.RequestCtx(), store, key, int64(widget.RateLimitPerMinute), time.Minute)
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("Rate limit check failed")
+		return nil
+	}
+
+	if !result.Allowed {
+		return middleware.SendRateLimitExceeded(c, result,
+			fmt.Sprintf("Rate limit exceeded: %d requests per minute", widget.RateLimitPerMinute))
+	}
+	middleware.SetRateLimitHeaders(c, result)
+	return nil
+}
+
+// requestOriginHost returns the embedding page's host from the Origin header, falling back to Referer
+func requestOriginHost(c fiber.Ctx) string {
+	if host := OriginHost(c.Get(fiber.HeaderOrigin)); host != "" {
+		return host
+	}
+	return OriginHost(c.Get(fiber.HeaderReferer))
+}
+
+// ============================================================================
+// ADMIN ENDPOINTS
+// ============================================================================
+
+// WidgetRequest is the request body for creating or updating a widget
+type WidgetRequest struct {
+	Name               string   `json:"name"`
+	ChatbotID          string   `json:"chatbot_id"`
+	AllowedDomains     []string `json:"allowed_domains"`
+	RateLimitPerMinute *int     `json:"rate_limit_per_minute,omitempty"`
+	SessionTTLHours    *int     `json:"session_ttl_hours,omitempty"`
+	RequireCaptcha     *bool    `json:"require_captcha,omitempty"`
+	CollectLeads       *bool    `json:"collect_leads,omitempty"`
+	Enabled            *bool    `json:"enabled,omitempty"`
+}
+
+// applyTo validates the request and copies it onto the widget
+func (r *WidgetRequest) applyTo(widget *ChatWidget) error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if r.ChatbotID == "" {
+		return fmt.Errorf("chatbot_id is required")
+	}
+
+	domains := make([]string, 0, len(r.AllowedDomains))
+	for _, d := range r.AllowedDomains {
+		if normalized := NormalizeWidgetDomain(d); normalized != "" {
+			domains = append(domains, normalized)
+		}
+	}
+	if len(domains) == 0 {
+		return fmt.Errorf("at least one allowed domain is required")
+	}
+
+	widget.Name = strings.TrimSpace(r.Name)
+	widget.ChatbotID = r.ChatbotID
+	widget.AllowedDomains = domains
+	if r.RateLimitPerMinute != nil {
+		if *r.RateLimitPerMinute <= 0 {
+			return fmt.Errorf("rate_limit_per_minute must be positive")
+		}
+		widget.RateLimitPerMinute = *r.RateLimitPerMinute
+	}
+	if r.SessionTTLHours != nil {
+		if *r.SessionTTLHours <= 0 {
+			return fmt.Errorf("session_ttl_hours must be positive")
+		}
+		widget.SessionTTLHours = *r.SessionTTLHours
+	}
+	if r.RequireCaptcha != nil {
+		widget.RequireCaptcha = *r.RequireCaptcha
+	}
+	if r.CollectLeads != nil {
+		widget.CollectLeads = *r.CollectLeads
+	}
+	if r.Enabled != nil {
+		widget.Enabled = *r.Enabled
+	}
+	return nil
+}
+
+// ListWidgets lists all chat widgets
+// GET /api/v1/admin/ai/widgets
+func (h *WidgetHandler) ListWidgets(c fiber.Ctx) error {
+	widgets, err := h.storage.ListWidgets(c.RequestCtx())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list widgets")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list widgets")
+	}
+
+	return c.JSON(fiber.Map{
+		"widgets": widgets,
+		"count":   len(widgets),
+	})
+}
+
+// GetWidget returns a chat widget
+// GET /api/v1/admin/ai/widgets/:id
+func (h *WidgetHandler) GetWidget(c fiber.Ctx) error {
+	widget, err := h.storage.GetWidget(c.RequestCtx(), c.Params("id"))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get widget")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get widget")
+	}
+	if widget == nil {
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Widget not found")
+	}
+	return c.JSON(widget)
+}
+
+// CreateWidget creates a chat widget with a new public key
+// POST /api/v1/admin/ai/widgets
+func (h *WidgetHandler) CreateWidget(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+
+	var req WidgetRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+	}
+
+	widget := &ChatWidget{
+		RateLimitPerMinute: 10,
+		SessionTTLHours:    24,
+		Enabled:            true,
+	}
+	if err := req.applyTo(widget); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
+	}
+
+	if errResp := h.checkChatbotExists(c, widget.ChatbotID); errResp != nil {
+		return errResp
+	}
+
+	publicKey, err := GenerateWidgetPublicKey()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to generate widget key")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to generate widget key")
+	}
+	widget.PublicKey = publicKey
+
+	if userID, ok := c.Locals("user_id").(string); ok && userID != "" {
+		widget.CreatedBy = &userID
+	}
+
+	if err := h.storage.CreateWidget(ctx, widget); err != nil {
+		log.Error().Err(err).Msg("Failed to create widget")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to create widget")
+	}
+	h.invalidateDomains()
+
+	return c.Status(fiber.StatusCreated).JSON(widget)
+}
+
+// UpdateWidget updates a chat widget
+// PUT /api/v1/admin/ai/widgets/:id
+func (h *WidgetHandler) UpdateWidget(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+
+	widget, err := h.storage.GetWidget(ctx, c.Params("id"))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get widget")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get widget")
+	}
+	if widget == nil {
+		return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Widget not found")
+	}
+
+	var req WidgetRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+	}
+	if err := req.applyTo(widget); err != nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, err.Error())
+	}
+
+	if errResp := h.checkChatbotExists(c, widget.ChatbotID); errResp != nil {
+		return errResp
+	}
+
+	if err := h.storage.UpdateWidget(ctx, widget); err != nil {
+		log.Error().Err(err).Str("id", widget.ID).Msg("Failed to update widget")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to update widget")
+	}
+	h.invalidateDomains()
+
+	updated, err := h.storage.GetWidget(ctx, widget.ID)
+	if err != nil || updated == nil {
+		return c.JSON(widget)
+	}
+	return c.JSON(updated)
+}
+
+// DeleteWidget deletes a chat widget and its sessions
+// DELETE /api/v1/admin/ai/widgets/:id
+func (h *WidgetHandler) DeleteWidget(c fiber.Ctx) error {
+	id := c.Params("id")
+	if err := h.storage.DeleteWidget(c.RequestCtx(), id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Widget not found")
+		}
+		log.Error().Err(err).Str("id", id).Msg("Failed to delete widget")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to delete widget")
+	}
+	h.invalidateDomains()
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RotateWidgetKey replaces a widget's public key. Existing sessions keep working until they expire.
+// POST /api/v1/admin/ai/widgets/:id/rotate-key
+func (h *WidgetHandler) RotateWidgetKey(c fiber.Ctx) error {
+	id := c.Params("id")
+
+	publicKey, err := GenerateWidgetPublicKey()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to generate widget key")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to generate widget key")
+	}
+
+	if err := h.storage.RotateWidgetKey(c.RequestCtx(), id, publicKey); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Widget not found")
+		}
+		log.Error().Err(err).Str("id", id).Msg("Failed to rotate widget key")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to rotate widget key")
+	}
+
+	return c.JSON(fiber.Map{
+		"id":         id,
+		"public_key": publicKey,
+	})
+}
+
+// ListWidgetSessions lists visitor sessions (and captured leads) for a widget
+// GET /api/v1/admin/ai/widgets/:id/sessions
+func (h *WidgetHandler) ListWidgetSessions(c fiber.Ctx) error {
+	limit := fiber.Query[int](c, "limit", 50)
+	offset := fiber.Query[int](c, "offset", 0)
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	leadsOnly := c.Query("leads_only") == "true"
+
+	sessions, total, err := h.storage.ListWidgetSessions(c.RequestCtx(), c.Params("id"), leadsOnly, limit, offset)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list widget sessions")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to list widget sessions")
+	}
+
+	return c.JSON(fiber.Map{
+		"sessions": sessions,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
+
+// checkChatbotExists returns an error response if the chatbot does not exist
+func (h *WidgetHandler) checkChatbotExists(c fiber.Ctx, chatbotID string) error {
+	chatbot, err := h.storage.GetChatbot(c.RequestCtx(), chatbotID)
+	if err != nil {
+		log.Error().Err(err).Str("chatbot_id", chatbotID).Msg("Failed to get chatbot")
+		return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeOperationFailed, "Failed to get chatbot")
+	}
+	if chatbot == nil {
+		return apierror.Send(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Chatbot not found")
+	}
+	return nil
+}
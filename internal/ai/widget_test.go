@@ -0,0 +1,151 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeWidgetDomain(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"example.com", "example.com"},
+		{"  Example.COM ", "example.com"},
+		{"https://example.com/", "example.com"},
+		{"https://app.example.com:8443/path?q=1", "app.example.com"},
+		{"example.com:3000", "example.com"},
+		{"*.example.com", "*.example.com"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NormalizeWidgetDomain(tt.input))
+		})
+	}
+}
+
+func TestOriginHost(t *testing.T) {
+	assert.Equal(t, "example.com", OriginHost("https://example.com"))
+	assert.Equal(t, "app.example.com", OriginHost("https://APP.example.com:8443"))
+	assert.Equal(t, "example.com", OriginHost("https://example.com/pricing?x=1"))
+	assert.Equal(t, "", OriginHost(""))
+	assert.Equal(t, "", OriginHost("null"))
+	assert.Equal(t, "", OriginHost("not a url"))
+}
+
+func TestIsWidgetOriginAllowed(t *testing.T) {
+	allowed := []string{"example.com", "*.shop.example.org", "https://docs.example.net/"}
+
+	t.Run("exact match", func(t *testing.T) {
+		assert.True(t, IsWidgetOriginAllowed("example.com", allowed))
+		assert.True(t, IsWidgetOriginAllowed("docs.example.net", allowed))
+	})
+
+	t.Run("wildcard matches subdomains only", func(t *testing.T) {
+		assert.True(t, IsWidgetOriginAllowed("eu.shop.example.org", allowed))
+		assert.True(t, IsWidgetOriginAllowed("a.b.shop.example.org", allowed))
+		assert.False(t, IsWidgetOriginAllowed("shop.example.org", allowed))
+	})
+
+	t.Run("rejects other hosts", func(t *testing.T) {
+		assert.False(t, IsWidgetOriginAllowed("www.example.com", allowed))
+		assert.False(t, IsWidgetOriginAllowed("example.com.evil.io", allowed))
+		assert.False(t, IsWidgetOriginAllowed("evilshop.example.org", allowed))
+	})
+
+	t.Run("empty host or allowlist", func(t *testing.T) {
+		assert.False(t, IsWidgetOriginAllowed("", allowed))
+		assert.False(t, IsWidgetOriginAllowed("example.com", nil))
+		assert.False(t, IsWidgetOriginAllowed("example.com", []string{""}))
+	})
+}
+
+func TestWidgetTokens(t *testing.T) {
+	key, err := GenerateWidgetPublicKey()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, widgetPublicKeyPrefix))
+	assert.Len(t, key, len(widgetPublicKeyPrefix)+32)
+
+	token, err := generateWidgetSessionToken()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, widgetSessionTokenPrefix))
+
+	other, err := generateWidgetSessionToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+
+	hash := hashWidgetSessionToken(token)
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, hashWidgetSessionToken(token))
+	assert.NotEqual(t, hash, hashWidgetSessionToken(other))
+}
+
+func TestWidgetSession_IsExpired(t *testing.T) {
+	assert.False(t, (&WidgetSession{ExpiresAt: time.Now().Add(time.Hour)}).IsExpired())
+	assert.True(t, (&WidgetSession{ExpiresAt: time.Now().Add(-time.Minute)}).IsExpired())
+}
+
+func TestWidgetReply_Collect(t *testing.T) {
+	reply := &widgetReply{ConversationID: "conv-1"}
+	chatCtx := &ChatContext{sink: reply.collect}
+	h := &ChatHandler{}
+
+	h.send(chatCtx, ServerMessage{Type: "progress", Message: "Thinking..."})
+	h.send(chatCtx, ServerMessage{Type: "content", Delta: "Hello"})
+	h.send(chatCtx, ServerMessage{Type: "content", Delta: ", world"})
+	h.send(chatCtx, ServerMessage{Type: "done", Usage: &UsageStats{PromptTokens: 3, CompletionTokens: 2}})
+
+	assert.Equal(t, "Hello, world", reply.Content)
+	require.NotNil(t, reply.Usage)
+	assert.Equal(t, 3, reply.Usage.PromptTokens)
+	assert.False(t, reply.Escalated)
+	assert.Empty(t, reply.Error)
+
+	h.send(chatCtx, ServerMessage{Type: "escalated", EscalationID: "esc-1"})
+	h.sendError(chatCtx, "conv-1", "TURN_LIMIT", "Conversation turn limit reached")
+
+	assert.True(t, reply.Escalated)
+	assert.Equal(t, "esc-1", reply.EscalationID)
+	assert.Equal(t, "TURN_LIMIT", reply.Code)
+	assert.Equal(t, "Conversation turn limit reached", reply.Error)
+}
+
+func TestWidgetRequest_ApplyTo(t *testing.T) {
+	t.Run("normalizes domains and applies overrides", func(t *testing.T) {
+		limit := 30
+		captcha := true
+		req := &WidgetRequest{
+			Name:               " support ",
+			ChatbotID:          "bot-1",
+			AllowedDomains:     []string{"https://Example.com/", "", "*.example.com"},
+			RateLimitPerMinute: &limit,
+			RequireCaptcha:     &captcha,
+		}
+		widget := &ChatWidget{RateLimitPerMinute: 10, SessionTTLHours: 24, Enabled: true}
+
+		require.NoError(t, req.applyTo(widget))
+		assert.Equal(t, "support", widget.Name)
+		assert.Equal(t, []string{"example.com", "*.example.com"}, widget.AllowedDomains)
+		assert.Equal(t, 30, widget.RateLimitPerMinute)
+		assert.Equal(t, 24, widget.SessionTTLHours)
+		assert.True(t, widget.RequireCaptcha)
+		assert.True(t, widget.Enabled)
+	})
+
+	t.Run("requires at least one domain", func(t *testing.T) {
+		req := &WidgetRequest{Name: "support", ChatbotID: "bot-1", AllowedDomains: []string{" "}}
+		assert.Error(t, req.applyTo(&ChatWidget{}))
+	})
+
+	t.Run("rejects non-positive limits", func(t *testing.T) {
+		zero := 0
+		req := &WidgetRequest{Name: "support", ChatbotID: "bot-1", AllowedDomains: []string{"example.com"}, RateLimitPerMinute: &zero}
+		assert.Error(t, req.applyTo(&ChatWidget{}))
+	})
+}
@@ -0,0 +1,522 @@
+package analytics
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// settleDelay keeps the newest rows out of an export, so rows of transactions that are still
+// in flight are not skipped by a watermark that has already moved past their cursor value
+const settleDelay = time.Minute
+
+// maxBatchesPerRun bounds the files one source exports per run, so a large backfill proceeds
+// over several runs instead of blocking the other sources
+const maxBatchesPerRun = 50
+
+// ErrSourceNotEnabled is returned when exporting a source that is unknown or not configured
+var ErrSourceNotEnabled = errors.New("analytics export source is not enabled")
+
+// ExportRecord is one row of api.analytics_exports
+type ExportRecord struct {
+	ID            string     `json:"id"`
+	Source        string     `json:"source"`
+	Status        string     `json:"status"`
+	RowCount      int        `json:"row_count"`
+	ObjectKey     *string    `json:"object_key,omitempty"`
+	WatermarkAt   *time.Time `json:"watermark_at,omitempty"`
+	WatermarkID   *string    `json:"watermark_id,omitempty"`
+	SchemaVersion int        `json:"schema_version"`
+	ErrorMessage  *string    `json:"error_message,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// SourceStatus is the export progress of one source
+type SourceStatus struct {
+	Source
+	WatermarkAt   *time.Time `json:"watermark_at"`
+	WatermarkID   *string    `json:"watermark_id"`
+	SchemaVersion int        `json:"schema_version"`
+	RowsExported  int64      `json:"rows_exported"`
+	LastExportAt  *time.Time `json:"last_export_at"`
+	// LastError is the error of the latest failed export since the last completed one
+	LastError *string `json:"last_error"`
+}
+
+// SchemaFile is written next to a source's data files whenever its columns change
+type SchemaFile struct {
+	Source
+	Version   int           `json:"version"`
+	Columns   []Column      `json:"columns"`
+	Changes   *SchemaChange `json:"changes,omitempty"` // nil for the first version
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// sourceState is what an export continues from: the latest completed export of a source
+type sourceState struct {
+	watermarkAt   *time.Time
+	watermarkID   string
+	schemaVersion int
+	columns       []Column
+}
+
+// Exporter periodically exports new and changed rows of AI and usage tables as gzipped JSON
+// lines to a storage bucket, from which analytics warehouses load them. Each source continues
+// from its watermark. It must run on a single node (leader-elected).
+type Exporter struct {
+	cfg     *config.AnalyticsConfig
+	db      *pgxpool.Pool
+	storage storage.Provider
+	now     func() time.Time
+
+	// exportMu serializes scheduled and manual exports, which would otherwise export the same rows twice
+	exportMu sync.Mutex
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running bool
+	mu      sync.Mutex
+}
+
+// NewExporter creates an analytics exporter
+func NewExporter(cfg *config.AnalyticsConfig, db *pgxpool.Pool, storageProvider storage.Provider) *Exporter {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Exporter{
+		cfg:     cfg,
+		db:      db,
+		storage: storageProvider,
+		now:     time.Now,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Start begins periodic exports
+func (e *Exporter) Start() {
+	e.mu.Lock()
+	if e.running {
+		e.mu.Unlock()
+		return
+	}
+	e.running = true
+	if e.ctx.Err() != nil {
+		e.ctx, e.cancel = context.WithCancel(context.Background())
+	}
+	e.mu.Unlock()
+
+	e.wg.Add(1)
+	go e.run()
+
+	log.Info().
+		Dur("interval", e.cfg.Interval).
+		Str("bucket", e.cfg.Bucket).
+		Strs("sources", e.cfg.EnabledSources()).
+		Msg("Analytics exporter started")
+}
+
+// Stop stops periodic exports
+func (e *Exporter) Stop() {
+	e.mu.Lock()
+	if !e.running {
+		e.mu.Unlock()
+		return
+	}
+	e.running = false
+	e.mu.Unlock()
+
+	e.cancel()
+	e.wg.Wait()
+
+	log.Info().Msg("Analytics exporter stopped")
+}
+
+// run exports on start and then every interval
+func (e *Exporter) run() {
+	defer e.wg.Done()
+
+	e.ExportAll(e.ctx)
+
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+			e.ExportAll(e.ctx)
+		}
+	}
+}
+
+// ExportAll exports the pending rows of every enabled source. A failing source is retried on
+// the next run and does not hold back the others.
+func (e *Exporter) ExportAll(ctx context.Context) []ExportRecord {
+	records := []ExportRecord{}
+	for _, name := range e.cfg.EnabledSources() {
+		if ctx.Err() != nil {
+			break
+		}
+		sourceRecords, err := e.ExportSource(ctx, name)
+		records = append(records, sourceRecords...)
+		if err != nil {
+			log.Error().Err(err).Str("source", name).Msg("Analytics export failed, will retry")
+		}
+	}
+	return records
+}
+
+// ExportSource exports the rows of a source after its watermark, one file per batch, until it is
+// caught up or maxBatchesPerRun files were written. It returns the completed exports.
+func (e *Exporter) ExportSource(ctx context.Context, name string) ([]ExportRecord, error) {
+	source, ok := LookupSource(name)
+	if !ok || !slices.Contains(e.cfg.EnabledSources(), name) {
+		return nil, fmt.Errorf("%w: %s", ErrSourceNotEnabled, name)
+	}
+
+	e.exportMu.Lock()
+	defer e.exportMu.Unlock()
+
+	state, err := e.lastCompleted(ctx, source.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export watermark: %w", err)
+	}
+
+	columns, excluded, err := loadColumns(ctx, e.db, source)
+	if err != nil {
+		return nil, e.recordFailure(ctx, source.Name, err)
+	}
+
+	// A new schema version starts with the first export after the columns changed
+	schemaVersion := 1
+	var changes *SchemaChange
+	if state != nil {
+		schemaVersion = state.schemaVersion
+		if change := diffColumns(state.columns, columns); !change.IsEmpty() {
+			schemaVersion++
+			changes = &change
+		}
+	}
+	schemaWritten := state != nil && changes == nil
+
+	cutoff := e.now().Add(-settleDelay)
+	records := []ExportRecord{}
+	for range maxBatchesPerRun {
+		lines, last, err := e.readBatch(ctx, source, state, excluded, cutoff)
+		if err != nil {
+			return records, e.recordFailure(ctx, source.Name, err)
+		}
+		if len(lines) == 0 {
+			break
+		}
+
+		if !schemaWritten {
+			if err := e.writeSchema(ctx, source, schemaVersion, columns, changes); err != nil {
+				return records, e.recordFailure(ctx, source.Name, fmt.Errorf("schema export failed: %w", err))
+			}
+			schemaWritten = true
+		}
+
+		record, err := e.writeBatch(ctx, source, lines, last, schemaVersion, columns)
+		if err != nil {
+			return records, e.recordFailure(ctx, source.Name, err)
+		}
+		records = append(records, *record)
+
+		state = &sourceState{
+			watermarkAt:   last.cursor,
+			watermarkID:   last.id,
+			schemaVersion: schemaVersion,
+			columns:       columns,
+		}
+		if len(lines) < e.cfg.BatchSize {
+			break
+		}
+	}
+
+	return records, nil
+}
+
+// batchRow identifies the last row of a batch, which becomes the new watermark
+type batchRow struct {
+	cursor *time.Time
+	id     string
+}
+
+// readBatch reads up to BatchSize rows after the watermark whose cursor value is before cutoff,
+// as JSON objects without the excluded columns
+func (e *Exporter) readBatch(ctx context.Context, source Source, state *sourceState, excluded []string, cutoff time.Time) ([]json.RawMessage, batchRow, error) {
+	var afterAt *time.Time
+	var afterID string
+	if state != nil {
+		afterAt, afterID = state.watermarkAt, state.watermarkID
+	}
+
+	// Table and column names come from the fixed source list, never from user input
+	query := fmt.Sprintf(`
+		SELECT to_jsonb(t) - $5::text[], t.%[2]s, t.id::text
+		FROM %[1]s t
+		WHERE t.%[2]s < $1
+			AND ($2::timestamptz IS NULL OR (t.%[2]s, t.id::text) > ($2::timestamptz, $3::text))
+		ORDER BY t.%[2]s, t.id::text
+		LIMIT $4
+	`, source.Table, source.Cursor)
+
+	rows, err := e.db.Query(ctx, query, cutoff, afterAt, afterID, e.cfg.BatchSize, excluded)
+	if err != nil {
+		return nil, batchRow{}, fmt.Errorf("failed to read %s: %w", source.Table, err)
+	}
+	defer rows.Close()
+
+	var lines []json.RawMessage
+	var last batchRow
+	for rows.Next() {
+		var line json.RawMessage
+		if err := rows.Scan(&line, &last.cursor, &last.id); err != nil {
+			return nil, batchRow{}, fmt.Errorf("failed to read %s: %w", source.Table, err)
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, batchRow{}, fmt.Errorf("failed to read %s: %w", source.Table, err)
+	}
+	return lines, last, nil
+}
+
+// encodeBatch gzips rows as JSON lines
+func encodeBatch(lines []json.RawMessage) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	for _, line := range lines {
+		if _, err := zw.Write(line); err != nil {
+			return nil, err
+		}
+		if _, err := zw.Write([]byte{'\n'}); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// dataKey returns the bucket key of an export file, partitioned by export date for external
+// tables, e.g. analytics/conversations/dt=2026-03-01/20260301T100000Z_3f9c0e6b.jsonl.gz
+func dataKey(prefix, source string, exportedAt time.Time, id string) string {
+	exportedAt = exportedAt.UTC()
+	return fmt.Sprintf("%s/%s/dt=%s/%s_%s.jsonl.gz", strings.Trim(prefix, "/"), source,
+		exportedAt.Format("2006-01-02"), exportedAt.Format("20060102T150405Z"), strings.SplitN(id, "-", 2)[0])
+}
+
+// schemaKey returns the bucket key of a schema version, e.g. analytics/conversations/_schema/v2.json
+func schemaKey(prefix, source string, version int) string {
+	return fmt.Sprintf("%s/%s/_schema/v%d.json", strings.Trim(prefix, "/"), source, version)
+}
+
+// writeBatch uploads one batch and records it as a completed export with the new watermark
+func (e *Exporter) writeBatch(ctx context.Context, source Source, lines []json.RawMessage, last batchRow, schemaVersion int, columns []Column) (*ExportRecord, error) {
+	data, err := encodeBatch(lines)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s rows: %w", source.Name, err)
+	}
+
+	id := uuid.NewString()
+	key := dataKey(e.cfg.PathPrefix, source.Name, e.now(), id)
+	err = e.upload(ctx, key, data, "application/gzip", map[string]string{
+		"source":         source.Name,
+		"schema-version": strconv.Itoa(schemaVersion),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("bucket export failed: %w", err)
+	}
+
+	columnsJSON, err := json.Marshal(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &ExportRecord{}
+	err = e.db.QueryRow(ctx, `
+		INSERT INTO api.analytics_exports (id, source, status, row_count, object_key, watermark_at, watermark_id, schema_version, columns)
+		VALUES ($1, $2, 'completed', $3, $4, $5, $6, $7, $8)
+		RETURNING id::text, source, status, row_count, object_key, watermark_at, watermark_id, schema_version, error_message, created_at
+	`, id, source.Name, len(lines), key, last.cursor, last.id, schemaVersion, columnsJSON).Scan(
+		&record.ID, &record.Source, &record.Status, &record.RowCount, &record.ObjectKey,
+		&record.WatermarkAt, &record.WatermarkID, &record.SchemaVersion, &record.ErrorMessage, &record.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record analytics export: %w", err)
+	}
+
+	log.Info().
+		Str("source", source.Name).
+		Int("rows", len(lines)).
+		Int("schema_version", schemaVersion).
+		Str("object_key", key).
+		Msg("Analytics batch exported")
+
+	return record, nil
+}
+
+// writeSchema uploads the description of a schema version
+func (e *Exporter) writeSchema(ctx context.Context, source Source, version int, columns []Column, changes *SchemaChange) error {
+	data, err := json.MarshalIndent(SchemaFile{
+		Source:    source,
+		Version:   version,
+		Columns:   columns,
+		Changes:   changes,
+		CreatedAt: e.now().UTC(),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if changes != nil {
+		log.Info().
+			Str("source", source.Name).
+			Int("schema_version", version).
+			Int("added", len(changes.Added)).
+			Int("removed", len(changes.Removed)).
+			Int("changed", len(changes.Changed)).
+			Msg("Analytics export schema changed")
+	}
+
+	return e.upload(ctx, schemaKey(e.cfg.PathPrefix, source.Name, version), data, "application/json", nil)
+}
+
+// upload writes an object to the export bucket, creating the bucket if needed
+func (e *Exporter) upload(ctx context.Context, key string, data []byte, contentType string, metadata map[string]string) error {
+	if e.storage == nil {
+		return errors.New("storage provider not available")
+	}
+
+	exists, err := e.storage.BucketExists(ctx, e.cfg.Bucket)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if err := e.storage.CreateBucket(ctx, e.cfg.Bucket); err != nil {
+			return err
+		}
+	}
+
+	_, err = e.storage.Upload(ctx, e.cfg.Bucket, key, bytes.NewReader(data), int64(len(data)), &storage.UploadOptions{
+		ContentType: contentType,
+		Metadata:    metadata,
+	})
+	return err
+}
+
+// lastCompleted returns the state of a source's latest completed export, or nil before the first one
+func (e *Exporter) lastCompleted(ctx context.Context, source string) (*sourceState, error) {
+	state := &sourceState{}
+	var watermarkID *string
+	var columnsJSON []byte
+	err := e.db.QueryRow(ctx, `
+		SELECT watermark_at, watermark_id, schema_version, columns
+		FROM api.analytics_exports
+		WHERE source = $1 AND status = 'completed'
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, source).Scan(&state.watermarkAt, &watermarkID, &state.schemaVersion, &columnsJSON)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if watermarkID != nil {
+		state.watermarkID = *watermarkID
+	}
+	if err := json.Unmarshal(columnsJSON, &state.columns); err != nil {
+		return nil, fmt.Errorf("invalid columns of the last export: %w", err)
+	}
+	return state, nil
+}
+
+// recordFailure stores a failed export attempt and returns the original error
+func (e *Exporter) recordFailure(ctx context.Context, source string, cause error) error {
+	_, err := e.db.Exec(ctx, `
+		INSERT INTO api.analytics_exports (source, status, error_message)
+		VALUES ($1, 'failed', $2)
+	`, source, cause.Error())
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to record analytics export failure")
+	}
+	return cause
+}
+
+// ListExports returns the most recent exports, newest first, optionally of one source
+func (e *Exporter) ListExports(ctx context.Context, source string, limit int) ([]ExportRecord, error) {
+	rows, err := e.db.Query(ctx, `
+		SELECT id::text, source, status, row_count, object_key, watermark_at, watermark_id,
+			schema_version, error_message, created_at
+		FROM api.analytics_exports
+		WHERE $1 = '' OR source = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, source, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (ExportRecord, error) {
+		var r ExportRecord
+		err := row.Scan(&r.ID, &r.Source, &r.Status, &r.RowCount, &r.ObjectKey, &r.WatermarkAt,
+			&r.WatermarkID, &r.SchemaVersion, &r.ErrorMessage, &r.CreatedAt)
+		return r, err
+	})
+}
+
+// SourceStatuses returns the watermark and totals of every enabled source
+func (e *Exporter) SourceStatuses(ctx context.Context) ([]SourceStatus, error) {
+	statuses := make([]SourceStatus, 0, len(e.cfg.EnabledSources()))
+	for _, name := range e.cfg.EnabledSources() {
+		source, ok := LookupSource(name)
+		if !ok {
+			continue
+		}
+
+		status := SourceStatus{Source: source}
+		err := e.db.QueryRow(ctx, `
+			SELECT c.watermark_at, c.watermark_id, COALESCE(c.schema_version, 0), c.created_at,
+				(SELECT COALESCE(SUM(row_count), 0) FROM api.analytics_exports WHERE source = $1 AND status = 'completed'),
+				(SELECT error_message FROM api.analytics_exports
+					WHERE source = $1 AND status = 'failed' AND created_at > COALESCE(c.created_at, '-infinity')
+					ORDER BY created_at DESC LIMIT 1)
+			FROM (SELECT 1) AS one
+			LEFT JOIN LATERAL (
+				SELECT watermark_at, watermark_id, schema_version, created_at
+				FROM api.analytics_exports
+				WHERE source = $1 AND status = 'completed'
+				ORDER BY created_at DESC
+				LIMIT 1
+			) c ON true
+		`, name).Scan(&status.WatermarkAt, &status.WatermarkID, &status.SchemaVersion, &status.LastExportAt,
+			&status.RowsExported, &status.LastError)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read status of %s: %w", name, err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
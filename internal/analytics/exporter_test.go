@@ -0,0 +1,120 @@
+package analytics
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSources_MatchConfig(t *testing.T) {
+	for _, name := range config.AnalyticsExportSources {
+		source, ok := LookupSource(name)
+		require.True(t, ok, name)
+		assert.Equal(t, name, source.Name)
+	}
+	assert.Len(t, sources, len(config.AnalyticsExportSources))
+}
+
+func TestDiffColumns(t *testing.T) {
+	previous := []Column{{"id", "uuid"}, {"title", "text"}, {"turn_count", "integer"}}
+
+	assert.True(t, diffColumns(previous, previous).IsEmpty())
+	assert.True(t, diffColumns(previous, []Column{{"turn_count", "integer"}, {"id", "uuid"}, {"title", "text"}}).IsEmpty(),
+		"column order is ignored")
+
+	change := diffColumns(previous, []Column{{"id", "uuid"}, {"turn_count", "bigint"}, {"tags", "_text"}})
+	assert.Equal(t, []Column{{"tags", "_text"}}, change.Added)
+	assert.Equal(t, []Column{{"title", "text"}}, change.Removed)
+	assert.Equal(t, []Column{{"turn_count", "bigint"}}, change.Changed)
+}
+
+func TestSplitExcludedColumns(t *testing.T) {
+	columns, excluded := splitExcludedColumns([]Column{
+		{"id", "uuid"}, {"embedding", "vector"}, {"content", "text"}, {"search", "tsvector"},
+	})
+	assert.Equal(t, []Column{{"id", "uuid"}, {"content", "text"}}, columns)
+	assert.Equal(t, []string{"embedding", "search"}, excluded)
+
+	_, excluded = splitExcludedColumns([]Column{{"id", "uuid"}})
+	assert.NotNil(t, excluded, "an empty list is passed as text[]")
+}
+
+func TestObjectKeys(t *testing.T) {
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, "analytics/conversations/dt=2026-03-01/20260301T100000Z_3f9c0e6b.jsonl.gz",
+		dataKey("analytics", "conversations", at, "3f9c0e6b-1a2d-4c5e-8f7a-6b5c4d3e2f10"))
+	assert.Equal(t, "warehouse/rag/retrieval_log/_schema/v2.json", schemaKey("/warehouse/rag/", "retrieval_log", 2))
+}
+
+func TestEncodeBatch(t *testing.T) {
+	data, err := encodeBatch([]json.RawMessage{
+		json.RawMessage(`{"id":"a","query_text":"refunds"}`),
+		json.RawMessage(`{"id":"b","query_text":"pricing"}`),
+	})
+	require.NoError(t, err)
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	var lines []string
+	scanner := bufio.NewScanner(zr)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, []string{`{"id":"a","query_text":"refunds"}`, `{"id":"b","query_text":"pricing"}`}, lines)
+}
+
+func TestExporter_WriteSchema(t *testing.T) {
+	provider, err := storage.NewLocalStorage(t.TempDir(), "", "secret")
+	require.NoError(t, err)
+
+	e := NewExporter(&config.AnalyticsConfig{Bucket: "warehouse", PathPrefix: "analytics"}, nil, provider)
+	e.now = func() time.Time { return time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC) }
+
+	source, _ := LookupSource("conversations")
+	columns := []Column{{"id", "uuid"}, {"tags", "_text"}}
+	changes := &SchemaChange{Added: []Column{{"tags", "_text"}}}
+	require.NoError(t, e.writeSchema(context.Background(), source, 2, columns, changes))
+
+	reader, _, err := provider.Download(context.Background(), "warehouse", "analytics/conversations/_schema/v2.json", nil)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+	stored, err := io.ReadAll(reader)
+	require.NoError(t, err)
+
+	var schema SchemaFile
+	require.NoError(t, json.Unmarshal(stored, &schema))
+	assert.Equal(t, "conversations", schema.Name)
+	assert.Equal(t, "ai.conversations", schema.Table)
+	assert.Equal(t, "updated_at", schema.Cursor)
+	assert.True(t, schema.Mutable)
+	assert.Equal(t, 2, schema.Version)
+	assert.Equal(t, columns, schema.Columns)
+	require.NotNil(t, schema.Changes)
+	assert.Equal(t, changes.Added, schema.Changes.Added)
+}
+
+func TestExporter_UploadWithoutStorage(t *testing.T) {
+	e := NewExporter(&config.AnalyticsConfig{Bucket: "warehouse"}, nil, nil)
+	assert.Error(t, e.upload(context.Background(), "analytics/x.json", nil, "application/json", nil))
+}
+
+func TestExporter_ExportSourceRequiresEnabledSource(t *testing.T) {
+	e := NewExporter(&config.AnalyticsConfig{Bucket: "warehouse", Sources: []string{"retrieval_log"}}, nil, nil)
+
+	_, err := e.ExportSource(context.Background(), "auth_users")
+	assert.ErrorIs(t, err, ErrSourceNotEnabled)
+
+	_, err = e.ExportSource(context.Background(), "conversations")
+	assert.ErrorIs(t, err, ErrSourceNotEnabled)
+}
@@ -0,0 +1,124 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Source is a table exported to the analytics warehouse. Rows are exported in the order of
+// (Cursor, id), so the last exported pair is the source's watermark.
+type Source struct {
+	Name   string `json:"source"`
+	Table  string `json:"table"`  // Schema-qualified table name
+	Cursor string `json:"cursor"` // Timestamp column rows are exported in order of
+	// Mutable sources update rows in place and bump the cursor column, so a row is exported
+	// again after each change. Warehouses keep the latest version per id.
+	Mutable bool `json:"mutable"`
+}
+
+// sources are all exportable tables by name. Their names match config.AnalyticsExportSources.
+var sources = map[string]Source{
+	"retrieval_log":   {Name: "retrieval_log", Table: "ai.retrieval_log", Cursor: "created_at"},
+	"conversations":   {Name: "conversations", Table: "ai.conversations", Cursor: "updated_at", Mutable: true},
+	"messages":        {Name: "messages", Table: "ai.messages", Cursor: "created_at"},
+	"embedding_usage": {Name: "embedding_usage", Table: "ai.embedding_usage", Cursor: "created_at"},
+	"api_usage":       {Name: "api_usage", Table: "api.usage_hourly", Cursor: "updated_at", Mutable: true},
+}
+
+// LookupSource returns the source with the given name
+func LookupSource(name string) (Source, bool) {
+	source, ok := sources[name]
+	return source, ok
+}
+
+// excludedColumnTypes are column types that are not exported: embeddings and search vectors
+// are large and of no use outside Postgres
+var excludedColumnTypes = []string{"vector", "halfvec", "sparsevec", "tsvector"}
+
+// Column is an exported column of a source
+type Column struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// SchemaChange describes how the columns of a source changed between two schema versions
+type SchemaChange struct {
+	Added   []Column `json:"added,omitempty"`
+	Removed []Column `json:"removed,omitempty"`
+	// Changed holds the new definition of columns whose type changed
+	Changed []Column `json:"changed,omitempty"`
+}
+
+// IsEmpty reports whether the columns did not change
+func (c SchemaChange) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0
+}
+
+// diffColumns compares the columns of the previous schema version with the current ones.
+// Column order is ignored since rows are exported as JSON objects.
+func diffColumns(previous, current []Column) SchemaChange {
+	var change SchemaChange
+	for _, col := range current {
+		i := slices.IndexFunc(previous, func(p Column) bool { return p.Name == col.Name })
+		switch {
+		case i < 0:
+			change.Added = append(change.Added, col)
+		case previous[i].Type != col.Type:
+			change.Changed = append(change.Changed, col)
+		}
+	}
+	for _, col := range previous {
+		if !slices.ContainsFunc(current, func(c Column) bool { return c.Name == col.Name }) {
+			change.Removed = append(change.Removed, col)
+		}
+	}
+	return change
+}
+
+// loadColumns returns the current columns of a source's table in definition order, split into
+// exported columns and the names of excluded ones
+func loadColumns(ctx context.Context, db *pgxpool.Pool, source Source) ([]Column, []string, error) {
+	rows, err := db.Query(ctx, `
+		SELECT column_name::text,
+			CASE WHEN data_type IN ('USER-DEFINED', 'ARRAY') THEN udt_name::text ELSE data_type::text END
+		FROM information_schema.columns
+		WHERE table_schema = split_part($1, '.', 1) AND table_name = split_part($1, '.', 2)
+		ORDER BY ordinal_position
+	`, source.Table)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load columns of %s: %w", source.Table, err)
+	}
+
+	all, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Column, error) {
+		var col Column
+		err := row.Scan(&col.Name, &col.Type)
+		return col, err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load columns of %s: %w", source.Table, err)
+	}
+	if len(all) == 0 {
+		return nil, nil, fmt.Errorf("table %s not found", source.Table)
+	}
+
+	columns, excluded := splitExcludedColumns(all)
+	return columns, excluded, nil
+}
+
+// splitExcludedColumns separates the columns of excluded types from the exported ones
+func splitExcludedColumns(all []Column) ([]Column, []string) {
+	columns := make([]Column, 0, len(all))
+	excluded := []string{}
+	for _, col := range all {
+		if slices.Contains(excludedColumnTypes, col.Type) {
+			excluded = append(excluded, col.Name)
+			continue
+		}
+		columns = append(columns, col)
+	}
+	return columns, excluded
+}
@@ -0,0 +1,215 @@
+// Package anonymize manages read-only anonymized views of sensitive tables.
+//
+// A view is declared with a rule for each column it exposes; columns without a rule are left
+// out. The views are created in the anonymized schema and served by the REST API like any
+// other view. They run with the privileges of their owner, so analysts can read them while
+// the source tables stay restricted to the service role.
+package anonymize
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nimbleflux/fluxbase/internal/query"
+)
+
+// Schema is the schema that holds the anonymized views
+const Schema = "anonymized"
+
+// Column rules
+const (
+	RuleKeep     = "keep"     // The value as is
+	RuleHash     = "hash"     // A salted SHA-256 pseudonym, stable within the view
+	RuleBucket   = "bucket"   // Numbers rounded down to a multiple of size
+	RuleTruncate = "truncate" // Text cut to length characters, or timestamps truncated to unit
+	RuleMask     = "mask"     // Every character replaced by * except the last keep_last
+)
+
+// truncateUnits are the units timestamps can be truncated to
+var truncateUnits = map[string]bool{
+	"minute": true, "hour": true, "day": true, "week": true, "month": true, "quarter": true, "year": true,
+}
+
+// maxColumnsPerView bounds the columns of a view
+const maxColumnsPerView = 200
+
+var (
+	// ErrViewNotFound is returned for unknown anonymized views
+	ErrViewNotFound = errors.New("anonymized view not found")
+	// ErrViewExists is returned when an anonymized view with the same name exists
+	ErrViewExists = errors.New("an anonymized view with this name already exists")
+	// ErrInvalidView is returned for declarations that can't be created
+	ErrInvalidView = errors.New("invalid anonymized view")
+)
+
+var (
+	identifierPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	rolePattern       = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+)
+
+// View is the declaration of an anonymized view
+type View struct {
+	ID             string       `json:"id"`
+	Name           string       `json:"name"`
+	Source         string       `json:"source"` // "schema.table"
+	Columns        []ColumnRule `json:"columns"`
+	AllowedRoles   []string     `json:"allowed_roles"`   // Roles that may read the view, empty for every authenticated user
+	RestrictSource bool         `json:"restrict_source"` // Revoke anon and authenticated from the source table on creation
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+// ColumnRule is how one column of the source table appears in a view
+type ColumnRule struct {
+	Column   string   `json:"column"`
+	Rule     string   `json:"rule"`
+	Size     *float64 `json:"size,omitempty"`      // bucket: width of the buckets
+	Length   *int     `json:"length,omitempty"`    // truncate: characters kept of text
+	Unit     string   `json:"unit,omitempty"`      // truncate: precision kept of timestamps
+	KeepLast *int     `json:"keep_last,omitempty"` // mask: trailing characters left visible
+}
+
+// Validate normalizes a view declaration and checks it
+func (v *View) Validate() error {
+	v.Name = strings.TrimSpace(v.Name)
+	if !identifierPattern.MatchString(v.Name) {
+		return fmt.Errorf("name must start with a letter or underscore and contain only letters, digits and underscores")
+	}
+
+	schema, table, err := v.SourceTable()
+	if err != nil {
+		return err
+	}
+	if schema == Schema {
+		return fmt.Errorf("source must not be in the %s schema", Schema)
+	}
+	v.Source = schema + "." + table
+
+	if len(v.Columns) == 0 {
+		return fmt.Errorf("at least one column is required")
+	}
+	if len(v.Columns) > maxColumnsPerView {
+		return fmt.Errorf("at most %d columns can be declared", maxColumnsPerView)
+	}
+	seen := make(map[string]bool, len(v.Columns))
+	for i := range v.Columns {
+		col := &v.Columns[i]
+		if err := col.validate(); err != nil {
+			return err
+		}
+		if seen[col.Column] {
+			return fmt.Errorf("column %s is declared twice", col.Column)
+		}
+		seen[col.Column] = true
+	}
+
+	if v.AllowedRoles == nil {
+		v.AllowedRoles = []string{}
+	}
+	for _, role := range v.AllowedRoles {
+		if !rolePattern.MatchString(role) {
+			return fmt.Errorf("invalid role %q", role)
+		}
+	}
+	return nil
+}
+
+// SourceTable splits the source of a view, defaulting to the public schema
+func (v *View) SourceTable() (string, string, error) {
+	schema, table, ok := strings.Cut(strings.TrimSpace(v.Source), ".")
+	if !ok {
+		schema, table = "public", schema
+	}
+	if !identifierPattern.MatchString(schema) || !identifierPattern.MatchString(table) {
+		return "", "", fmt.Errorf("invalid source %q, expected schema.table", v.Source)
+	}
+	return schema, table, nil
+}
+
+func (r *ColumnRule) validate() error {
+	if !identifierPattern.MatchString(r.Column) {
+		return fmt.Errorf("invalid column %q", r.Column)
+	}
+
+	switch r.Rule {
+	case RuleKeep, RuleHash:
+	case RuleBucket:
+		if r.Size == nil || *r.Size <= 0 {
+			return fmt.Errorf("column %s: bucket requires a positive size", r.Column)
+		}
+	case RuleTruncate:
+		switch {
+		case r.Unit != "" && r.Length != nil:
+			return fmt.Errorf("column %s: truncate takes either length or unit", r.Column)
+		case r.Unit != "":
+			if !truncateUnits[r.Unit] {
+				return fmt.Errorf("column %s: unit must be one of minute, hour, day, week, month, quarter or year", r.Column)
+			}
+		case r.Length == nil || *r.Length < 1:
+			return fmt.Errorf("column %s: truncate requires a length of at least 1 or a unit", r.Column)
+		}
+	case RuleMask:
+		if r.KeepLast != nil && *r.KeepLast < 0 {
+			return fmt.Errorf("column %s: keep_last must not be negative", r.Column)
+		}
+	default:
+		return fmt.Errorf("column %s: rule must be one of keep, hash, bucket, truncate or mask", r.Column)
+	}
+	return nil
+}
+
+// expression returns the SQL expression of the column in the view named viewName, on the
+// source row alias t
+func (r *ColumnRule) expression(viewName string) string {
+	col := "t." + query.QuoteIdentifier(r.Column)
+	switch r.Rule {
+	case RuleHash:
+		return fmt.Sprintf("%s.pseudonym(%s::text, %s)", Schema, col, query.QuoteLiteral(viewName))
+	case RuleBucket:
+		size := strconv.FormatFloat(*r.Size, 'f', -1, 64)
+		return fmt.Sprintf("floor(%s::numeric / %s) * %s", col, size, size)
+	case RuleTruncate:
+		if r.Unit != "" {
+			return fmt.Sprintf("date_trunc(%s, %s)", query.QuoteLiteral(r.Unit), col)
+		}
+		return fmt.Sprintf("left(%s::text, %d)", col, *r.Length)
+	case RuleMask:
+		keep := 0
+		if r.KeepLast != nil {
+			keep = *r.KeepLast
+		}
+		return fmt.Sprintf("CASE WHEN %[1]s IS NULL THEN NULL ELSE repeat('*', greatest(length(%[1]s::text) - %[2]d, 0)) || right(%[1]s::text, %[2]d) END",
+			col, keep)
+	default:
+		return col
+	}
+}
+
+// buildViewSQL returns the statement creating the view of a validated declaration. Views with
+// allowed roles only return rows to those roles; the service role and dashboard admins can
+// always read them.
+func buildViewSQL(v *View) string {
+	schema, table, _ := v.SourceTable()
+
+	columns := make([]string, len(v.Columns))
+	for i := range v.Columns {
+		columns[i] = v.Columns[i].expression(v.Name) + " AS " + query.QuoteIdentifier(v.Columns[i].Column)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE VIEW %s WITH (security_barrier = true) AS\nSELECT %s\nFROM %s AS t",
+		query.QuoteQualifiedName(Schema, v.Name), strings.Join(columns, ",\n       "), query.QuoteQualifiedName(schema, table))
+
+	if len(v.AllowedRoles) > 0 {
+		roles := make([]string, 0, len(v.AllowedRoles)+2)
+		for _, role := range append([]string{"service_role", "dashboard_admin"}, v.AllowedRoles...) {
+			roles = append(roles, query.QuoteLiteral(role))
+		}
+		fmt.Fprintf(&b, "\nWHERE auth.role() = ANY (ARRAY[%s])", strings.Join(roles, ", "))
+	}
+	return b.String()
+}
@@ -0,0 +1,100 @@
+package anonymize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(n int) *int           { return &n }
+func floatPtr(f float64) *float64 { return &f }
+
+func TestView_Validate(t *testing.T) {
+	valid := func() *View {
+		return &View{
+			Name:   "patients",
+			Source: "clinic.patients",
+			Columns: []ColumnRule{
+				{Column: "id", Rule: RuleHash},
+				{Column: "age", Rule: RuleBucket, Size: floatPtr(10)},
+			},
+		}
+	}
+
+	t.Run("valid declaration", func(t *testing.T) {
+		v := valid()
+		require.NoError(t, v.Validate())
+		assert.Equal(t, []string{}, v.AllowedRoles)
+	})
+
+	t.Run("source defaults to public", func(t *testing.T) {
+		v := valid()
+		v.Source = "patients"
+		require.NoError(t, v.Validate())
+		assert.Equal(t, "public.patients", v.Source)
+	})
+
+	tests := []struct {
+		name    string
+		modify  func(v *View)
+		wantErr string
+	}{
+		{"invalid name", func(v *View) { v.Name = "patients; drop" }, "name must start"},
+		{"invalid source", func(v *View) { v.Source = "clinic.patients.x" }, "invalid source"},
+		{"source in anonymized schema", func(v *View) { v.Source = "anonymized.other" }, "must not be in the anonymized schema"},
+		{"no columns", func(v *View) { v.Columns = nil }, "at least one column"},
+		{"duplicate column", func(v *View) { v.Columns = append(v.Columns, ColumnRule{Column: "id", Rule: RuleKeep}) }, "declared twice"},
+		{"invalid column", func(v *View) { v.Columns[0].Column = "id\"" }, "invalid column"},
+		{"unknown rule", func(v *View) { v.Columns[0].Rule = "encrypt" }, "rule must be one of"},
+		{"bucket without size", func(v *View) { v.Columns[1].Size = nil }, "positive size"},
+		{"truncate without length or unit", func(v *View) { v.Columns[0] = ColumnRule{Column: "id", Rule: RuleTruncate} }, "length of at least 1"},
+		{"truncate with length and unit", func(v *View) {
+			v.Columns[0] = ColumnRule{Column: "id", Rule: RuleTruncate, Length: intPtr(3), Unit: "day"}
+		}, "either length or unit"},
+		{"truncate with unknown unit", func(v *View) { v.Columns[0] = ColumnRule{Column: "id", Rule: RuleTruncate, Unit: "second"} }, "unit must be one of"},
+		{"mask with negative keep_last", func(v *View) { v.Columns[0] = ColumnRule{Column: "id", Rule: RuleMask, KeepLast: intPtr(-1)} }, "must not be negative"},
+		{"invalid role", func(v *View) { v.AllowedRoles = []string{"analyst'"} }, "invalid role"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := valid()
+			tt.modify(v)
+			err := v.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestBuildViewSQL(t *testing.T) {
+	v := &View{
+		Name:   "patients",
+		Source: "clinic.patients",
+		Columns: []ColumnRule{
+			{Column: "id", Rule: RuleHash},
+			{Column: "age", Rule: RuleBucket, Size: floatPtr(10)},
+			{Column: "admitted_at", Rule: RuleTruncate, Unit: "month"},
+			{Column: "zip", Rule: RuleTruncate, Length: intPtr(3)},
+			{Column: "phone", Rule: RuleMask, KeepLast: intPtr(4)},
+			{Column: "ward", Rule: RuleKeep},
+		},
+	}
+	require.NoError(t, v.Validate())
+
+	sql := buildViewSQL(v)
+	assert.Contains(t, sql, `CREATE VIEW "anonymized"."patients" WITH (security_barrier = true) AS`)
+	assert.Contains(t, sql, `anonymized.pseudonym(t."id"::text, 'patients') AS "id"`)
+	assert.Contains(t, sql, `floor(t."age"::numeric / 10) * 10 AS "age"`)
+	assert.Contains(t, sql, `date_trunc('month', t."admitted_at") AS "admitted_at"`)
+	assert.Contains(t, sql, `left(t."zip"::text, 3) AS "zip"`)
+	assert.Contains(t, sql, `right(t."phone"::text, 4) END AS "phone"`)
+	assert.Contains(t, sql, `t."ward" AS "ward"`)
+	assert.Contains(t, sql, `FROM "clinic"."patients" AS t`)
+	assert.NotContains(t, sql, "WHERE")
+
+	t.Run("allowed roles filter rows", func(t *testing.T) {
+		v.AllowedRoles = []string{"analyst"}
+		assert.Contains(t, buildViewSQL(v), `WHERE auth.role() = ANY (ARRAY['service_role', 'dashboard_admin', 'analyst'])`)
+	})
+}
@@ -0,0 +1,271 @@
+package anonymize
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/nimbleflux/fluxbase/internal/query"
+)
+
+// Service creates, replaces and drops anonymized views together with their declarations
+type Service struct {
+	db          *database.Connection
+	schemaCache *database.SchemaCache // may be nil
+}
+
+// NewService creates an anonymized view service. schemaCache is refreshed after every change
+// so the REST API sees the views right away; it may be nil.
+func NewService(db *database.Connection, schemaCache *database.SchemaCache) *Service {
+	return &Service{db: db, schemaCache: schemaCache}
+}
+
+const viewColumns = `id, name, source_schema || '.' || source_table, columns, allowed_roles, restrict_source, created_at, updated_at`
+
+func scanView(row pgx.Row) (*View, error) {
+	view := &View{}
+	var columns []byte
+	if err := row.Scan(&view.ID, &view.Name, &view.Source, &columns, &view.AllowedRoles, &view.RestrictSource,
+		&view.CreatedAt, &view.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(columns, &view.Columns); err != nil {
+		return nil, fmt.Errorf("invalid columns of anonymized view %s: %w", view.Name, err)
+	}
+	return view, nil
+}
+
+// List returns all anonymized view declarations
+func (s *Service) List(ctx context.Context) ([]*View, error) {
+	rows, err := s.db.Pool().Query(ctx, `SELECT `+viewColumns+` FROM api.anonymized_views ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list anonymized views: %w", err)
+	}
+	defer rows.Close()
+
+	views := []*View{}
+	for rows.Next() {
+		view, err := scanView(rows)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list anonymized views: %w", err)
+	}
+	return views, nil
+}
+
+// Get returns an anonymized view declaration
+func (s *Service) Get(ctx context.Context, id string) (*View, error) {
+	view, err := scanView(s.db.Pool().QueryRow(ctx, `SELECT `+viewColumns+` FROM api.anonymized_views WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrViewNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get anonymized view: %w", err)
+	}
+	return view, nil
+}
+
+// Create stores a validated declaration and creates its view
+func (s *Service) Create(ctx context.Context, view *View, createdBy *uuid.UUID) error {
+	if err := s.checkSource(ctx, view); err != nil {
+		return err
+	}
+	columns, err := json.Marshal(view.Columns)
+	if err != nil {
+		return err
+	}
+	salt, err := newSalt()
+	if err != nil {
+		return err
+	}
+	schema, table, _ := view.SourceTable()
+
+	err = s.db.ExecuteWithAdminRole(ctx, func(conn *pgx.Conn) error {
+		err := conn.QueryRow(ctx, `
+			INSERT INTO api.anonymized_views (name, source_schema, source_table, columns, allowed_roles, restrict_source, salt, created_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id, created_at, updated_at
+		`, view.Name, schema, table, columns, view.AllowedRoles, view.RestrictSource, salt, createdBy,
+		).Scan(&view.ID, &view.CreatedAt, &view.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		return createView(ctx, conn, view)
+	})
+	if err != nil {
+		return viewError(err, "create")
+	}
+
+	s.refreshSchemaCache(ctx)
+	return nil
+}
+
+// Update replaces the declaration of a view and recreates the view. The salt is kept, so
+// hashed values stay the same.
+func (s *Service) Update(ctx context.Context, view *View) error {
+	if err := s.checkSource(ctx, view); err != nil {
+		return err
+	}
+	columns, err := json.Marshal(view.Columns)
+	if err != nil {
+		return err
+	}
+	schema, table, _ := view.SourceTable()
+
+	err = s.db.ExecuteWithAdminRole(ctx, func(conn *pgx.Conn) error {
+		var previousName string
+		err := conn.QueryRow(ctx, `SELECT name FROM api.anonymized_views WHERE id = $1 FOR UPDATE`, view.ID).Scan(&previousName)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrViewNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := conn.Exec(ctx, "DROP VIEW IF EXISTS "+query.QuoteQualifiedName(Schema, previousName)); err != nil {
+			return err
+		}
+		err = conn.QueryRow(ctx, `
+			UPDATE api.anonymized_views SET
+				name = $2, source_schema = $3, source_table = $4, columns = $5, allowed_roles = $6,
+				restrict_source = $7, updated_at = NOW()
+			WHERE id = $1
+			RETURNING created_at, updated_at
+		`, view.ID, view.Name, schema, table, columns, view.AllowedRoles, view.RestrictSource,
+		).Scan(&view.CreatedAt, &view.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		return createView(ctx, conn, view)
+	})
+	if err != nil {
+		return viewError(err, "update")
+	}
+
+	s.refreshSchemaCache(ctx)
+	return nil
+}
+
+// Delete drops a view and its declaration. Grants revoked from the source table are not restored.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	err := s.db.ExecuteWithAdminRole(ctx, func(conn *pgx.Conn) error {
+		var name string
+		err := conn.QueryRow(ctx, `DELETE FROM api.anonymized_views WHERE id = $1 RETURNING name`, id).Scan(&name)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrViewNotFound
+		}
+		if err != nil {
+			return err
+		}
+		_, err = conn.Exec(ctx, "DROP VIEW IF EXISTS "+query.QuoteQualifiedName(Schema, name))
+		return err
+	})
+	if err != nil {
+		return viewError(err, "delete")
+	}
+
+	s.refreshSchemaCache(ctx)
+	return nil
+}
+
+// createView creates the view of a declaration and grants it to authenticated users, who
+// read it through the REST API. Writes are never granted.
+func createView(ctx context.Context, conn *pgx.Conn, view *View) error {
+	if _, err := conn.Exec(ctx, buildViewSQL(view)); err != nil {
+		return err
+	}
+	name := query.QuoteQualifiedName(Schema, view.Name)
+	if _, err := conn.Exec(ctx, "REVOKE ALL ON "+name+" FROM PUBLIC"); err != nil {
+		return err
+	}
+	if _, err := conn.Exec(ctx, "GRANT SELECT ON "+name+" TO authenticated, service_role"); err != nil {
+		return err
+	}
+
+	if view.RestrictSource {
+		schema, table, _ := view.SourceTable()
+		if _, err := conn.Exec(ctx, "REVOKE ALL ON "+query.QuoteQualifiedName(schema, table)+" FROM anon, authenticated"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSource checks that the source of a declaration exists and has the declared columns
+func (s *Service) checkSource(ctx context.Context, view *View) error {
+	schema, table, err := view.SourceTable()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidView, err)
+	}
+
+	rows, err := s.db.Pool().Query(ctx, `
+		SELECT a.attname
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid AND c.relkind IN ('r', 'p', 'v', 'm')
+		WHERE a.attrelid = to_regclass($1) AND a.attnum > 0 AND NOT a.attisdropped
+	`, query.QuoteQualifiedName(schema, table))
+	if err != nil {
+		return fmt.Errorf("failed to inspect source table: %w", err)
+	}
+	columns, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return fmt.Errorf("failed to inspect source table: %w", err)
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("%w: source table %s.%s not found", ErrInvalidView, schema, table)
+	}
+
+	existing := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		existing[col] = true
+	}
+	for _, rule := range view.Columns {
+		if !existing[rule.Column] {
+			return fmt.Errorf("%w: column %s does not exist in %s.%s", ErrInvalidView, rule.Column, schema, table)
+		}
+	}
+	return nil
+}
+
+// viewError maps errors of statements run for action to the errors of the package. Errors
+// reported by PostgreSQL, e.g. a rule that doesn't fit the column type, reject the declaration.
+func viewError(err error, action string) error {
+	if errors.Is(err, ErrViewNotFound) {
+		return err
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		if pgErr.Code == "23505" || pgErr.Code == "42P07" { // unique_violation, duplicate_table
+			return ErrViewExists
+		}
+		return fmt.Errorf("%w: %s", ErrInvalidView, pgErr.Message)
+	}
+	return fmt.Errorf("failed to %s anonymized view: %w", action, err)
+}
+
+// refreshSchemaCache makes the REST API see view changes right away
+func (s *Service) refreshSchemaCache(ctx context.Context) {
+	if s.schemaCache != nil {
+		s.schemaCache.InvalidateAll(ctx)
+	}
+}
+
+// newSalt returns a random salt for the pseudonyms of a view
+func newSalt() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/gofiber/fiber/v3"
 	"github.com/nimbleflux/fluxbase/internal/auth"
 	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/email"
 	"github.com/rs/zerolog/log"
 )
 
@@ -21,6 +22,10 @@ type AdminAuthHandler struct {
 	dashboardAuth  *auth.DashboardAuthService
 	systemSettings *auth.SystemSettingsService
 	config         *config.Config
+
+	// Instance bootstrap (admin_bootstrap.go)
+	settingsCache *auth.SettingsCache
+	emailManager  *email.Manager
 }
 
 // NewAdminAuthHandler creates a new admin auth handler
@@ -116,19 +121,8 @@ func (h *AdminAuthHandler) InitialSetup(c fiber.Ctx) error {
 		return SendInvalidBody(c)
 	}
 
-	// Validate setup token using constant-time comparison to prevent timing attacks
-	configuredToken := h.config.Security.SetupToken
-	if configuredToken == "" {
-		return SendForbidden(c, "Admin setup is disabled. Set FLUXBASE_SECURITY_SETUP_TOKEN to enable.", ErrCodeSetupDisabled)
-	}
-
-	if req.SetupToken == "" {
-		return SendMissingField(c, "setup_token")
-	}
-
-	// Use constant-time comparison to prevent timing attacks
-	if subtle.ConstantTimeCompare([]byte(req.SetupToken), []byte(configuredToken)) != 1 {
-		return SendUnauthorized(c, "Invalid setup token", ErrCodeInvalidSetupToken)
+	if ok, err := h.checkSetupToken(c, req.SetupToken); !ok {
+		return err
 	}
 
 	// Validate password strength
@@ -172,6 +166,25 @@ func (h *AdminAuthHandler) InitialSetup(c fiber.Ctx) error {
 	})
 }
 
+// checkSetupToken validates the setup token using constant-time comparison to prevent timing
+// attacks. When it returns false, the error response has already been sent.
+func (h *AdminAuthHandler) checkSetupToken(c fiber.Ctx, token string) (bool, error) {
+	configuredToken := h.config.Security.SetupToken
+	if configuredToken == "" {
+		return false, SendForbidden(c, "Admin setup is disabled. Set FLUXBASE_SECURITY_SETUP_TOKEN to enable.", ErrCodeSetupDisabled)
+	}
+
+	if token == "" {
+		return false, SendMissingField(c, "setup_token")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(configuredToken)) != 1 {
+		return false, SendUnauthorized(c, "Invalid setup token", ErrCodeInvalidSetupToken)
+	}
+
+	return true, nil
+}
+
 // AdminLogin authenticates an admin user
 // POST /api/v1/admin/login
 func (h *AdminAuthHandler) AdminLogin(c fiber.Ctx) error {
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/nimbleflux/fluxbase/internal/email"
+	"github.com/rs/zerolog/log"
+)
+
+// InstanceBootstrapRequest represents the instance bootstrap request
+type InstanceBootstrapRequest struct {
+	Email         string                     `json:"email"`
+	Password      string                     `json:"password"`
+	Name          string                     `json:"name"`
+	SetupToken    string                     `json:"setup_token"`
+	PublicBaseURL string                     `json:"public_base_url,omitempty"`
+	SMTP          *auth.BootstrapSMTPOptions `json:"smtp,omitempty"`
+}
+
+// BootstrapServiceKey is the service key created during instance bootstrap
+type BootstrapServiceKey struct {
+	ID  uuid.UUID `json:"id"`
+	Key string    `json:"key"`
+}
+
+// InstanceBootstrapResponse represents the instance bootstrap response.
+// The service and anon keys are only shown here.
+type InstanceBootstrapResponse struct {
+	User            *auth.DashboardUser          `json:"user"`
+	AccessToken     string                       `json:"access_token"`
+	RefreshToken    string                       `json:"refresh_token"`
+	ExpiresIn       int64                        `json:"expires_in"`
+	ServiceKey      BootstrapServiceKey          `json:"service_key"`
+	AnonKey         *auth.ClientKeyWithPlaintext `json:"anon_key"`
+	PublicBaseURL   string                       `json:"public_base_url,omitempty"`
+	EmailConfigured bool                         `json:"email_configured"`
+	RestartRequired bool                         `json:"restart_required"`
+}
+
+// SetInstanceBootstrap provides the settings cache and email manager used by the instance
+// bootstrap, so settings it stores are picked up without a restart where possible
+func (h *AdminAuthHandler) SetInstanceBootstrap(settingsCache *auth.SettingsCache, emailManager *email.Manager) {
+	h.settingsCache = settingsCache
+	h.emailManager = emailManager
+}
+
+// BootstrapInstance sets up a fresh instance in one transaction: the first admin, a service
+// key, an anon key, the public base URL and SMTP. Afterwards it is locked like InitialSetup.
+// POST /api/v1/admin/bootstrap
+func (h *AdminAuthHandler) BootstrapInstance(c fiber.Ctx) error {
+	ctx := context.Background()
+
+	setupComplete, err := h.systemSettings.IsSetupComplete(ctx)
+	if err != nil {
+		return SendOperationFailed(c, "check setup status")
+	}
+	if setupComplete {
+		return SendForbidden(c, "Setup has already been completed", ErrCodeSetupCompleted)
+	}
+
+	var req InstanceBootstrapRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+
+	if ok, err := h.checkSetupToken(c, req.SetupToken); !ok {
+		return err
+	}
+
+	opts := auth.InstanceBootstrapOptions{
+		AdminEmail:    req.Email,
+		AdminPassword: req.Password,
+		AdminName:     req.Name,
+		PublicBaseURL: req.PublicBaseURL,
+		SMTP:          req.SMTP,
+	}
+	if err := opts.Validate(); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeValidationFailed)
+	}
+
+	// Settings controlled by the environment or config file would be ignored, so refuse them
+	// instead of storing values that never take effect
+	if opts.PublicBaseURL != "" && h.config.PublicBaseURL != "" {
+		return h.sendBootstrapOverride(c, auth.PublicBaseURLSettingKey)
+	}
+	if h.settingsCache != nil {
+		for _, key := range opts.SettingKeys() {
+			if h.settingsCache.IsOverriddenByEnv(key) {
+				return h.sendBootstrapOverride(c, key)
+			}
+		}
+	}
+
+	result, err := h.systemSettings.BootstrapInstance(ctx, opts, h.config.EncryptionKey)
+	if err != nil {
+		if errors.Is(err, auth.ErrSetupAlreadyCompleted) {
+			return SendForbidden(c, "Setup has already been completed", ErrCodeSetupCompleted)
+		}
+		log.Error().Err(err).Msg("Instance bootstrap failed")
+		return SendOperationFailed(c, "bootstrap instance")
+	}
+
+	if h.settingsCache != nil {
+		for _, key := range opts.SettingKeys() {
+			h.settingsCache.Invalidate(key)
+		}
+	}
+	if opts.SMTP != nil && h.emailManager != nil {
+		if err := h.emailManager.RefreshFromSettings(ctx); err != nil {
+			log.Warn().Err(err).Msg("Failed to refresh email service after instance bootstrap")
+		}
+	}
+
+	log.Info().
+		Str("admin_email", result.Admin.Email).
+		Str("service_key_id", result.ServiceKeyID.String()).
+		Str("anon_key_id", result.AnonKey.ID.String()).
+		Bool("public_base_url", opts.PublicBaseURL != "").
+		Bool("smtp", opts.SMTP != nil).
+		Msg("Instance bootstrapped")
+
+	loggedInUser, loginResp, err := h.dashboardAuth.Login(ctx, opts.AdminEmail, opts.AdminPassword, nil, c.Get("User-Agent"))
+	if err != nil {
+		return SendInternalError(c, "Instance bootstrapped but failed to generate access token")
+	}
+
+	// The response carries secrets that are never shown again
+	c.Set("Cache-Control", "no-store")
+	return c.Status(http.StatusCreated).JSON(InstanceBootstrapResponse{
+		User:            loggedInUser,
+		AccessToken:     loginResp.AccessToken,
+		RefreshToken:    loginResp.RefreshToken,
+		ExpiresIn:       loginResp.ExpiresIn,
+		ServiceKey:      BootstrapServiceKey{ID: result.ServiceKeyID, Key: result.ServiceKey},
+		AnonKey:         result.AnonKey,
+		PublicBaseURL:   opts.PublicBaseURL,
+		EmailConfigured: opts.SMTP != nil,
+		// The public base URL is read from settings at startup only
+		RestartRequired: opts.PublicBaseURL != "",
+	})
+}
+
+func (h *AdminAuthHandler) sendBootstrapOverride(c fiber.Ctx, key string) error {
+	return SendErrorWithDetails(c, fiber.StatusConflict, "This setting is controlled by an environment variable or the config file and cannot be set during bootstrap", "ENV_OVERRIDE", "", "", fiber.Map{
+		"key": key,
+	})
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminAuthHandler_CheckSetupToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		token      string
+		wantStatus int
+		wantCode   string
+	}{
+		{"valid token", "setup-token", "setup-token", fiber.StatusOK, ""},
+		{"setup disabled", "", "setup-token", fiber.StatusForbidden, ErrCodeSetupDisabled},
+		{"missing token", "setup-token", "", fiber.StatusBadRequest, ""},
+		{"wrong token", "setup-token", "other-token", fiber.StatusUnauthorized, ErrCodeInvalidSetupToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Security.SetupToken = tt.configured
+			handler := NewAdminAuthHandler(nil, nil, nil, nil, cfg)
+
+			app := fiber.New()
+			app.Get("/", func(c fiber.Ctx) error {
+				if ok, err := handler.checkSetupToken(c, tt.token); !ok {
+					return err
+				}
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			if tt.wantCode != "" {
+				var body map[string]interface{}
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+				assert.Equal(t, tt.wantCode, body["code"])
+			}
+		})
+	}
+}
+
+func TestInstanceBootstrapRequest_JSON(t *testing.T) {
+	body := `{
+		"email": "admin@example.com",
+		"password": "Sup3r-Secret-Passw0rd!",
+		"name": "Admin",
+		"setup_token": "setup-token",
+		"public_base_url": "https://db.example.com",
+		"smtp": {"host": "smtp.example.com", "port": 465, "tls": false, "from_address": "noreply@example.com"}
+	}`
+
+	var req InstanceBootstrapRequest
+	require.NoError(t, json.Unmarshal([]byte(body), &req))
+	assert.Equal(t, "https://db.example.com", req.PublicBaseURL)
+	require.NotNil(t, req.SMTP)
+	assert.Equal(t, 465, req.SMTP.Port)
+	require.NotNil(t, req.SMTP.TLS)
+	assert.False(t, *req.SMTP.TLS)
+}
+
+func TestInstanceBootstrapResponse_JSON(t *testing.T) {
+	resp := InstanceBootstrapResponse{
+		User:       &auth.DashboardUser{Email: "admin@example.com"},
+		ServiceKey: BootstrapServiceKey{ID: uuid.New(), Key: "sk_test"},
+		AnonKey: &auth.ClientKeyWithPlaintext{
+			ClientKey:    auth.ClientKey{Name: "anon", Scopes: auth.AnonKeyScopes},
+			PlaintextKey: "fbk_test",
+		},
+		RestartRequired: true,
+	}
+
+	data, err := json.Marshal(resp)
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "sk_test", decoded["service_key"].(map[string]interface{})["key"])
+	assert.Equal(t, "fbk_test", decoded["anon_key"].(map[string]interface{})["key"])
+	assert.Equal(t, true, decoded["restart_required"])
+	assert.NotContains(t, decoded, "public_base_url")
+}
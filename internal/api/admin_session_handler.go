@@ -48,9 +48,7 @@ func (h *AdminSessionHandler) ListSessions(c fiber.Ctx) error {
 	sessions, total, err := h.sessionRepo.ListAllPaginated(ctx, includeExpired, limit, offset)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list sessions")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to list sessions",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to list sessions", ErrCodeOperationFailed)
 	}
 
 	return c.JSON(fiber.Map{
@@ -68,22 +66,16 @@ func (h *AdminSessionHandler) RevokeSession(c fiber.Ctx) error {
 	sessionID := c.Params("id")
 
 	if sessionID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Session ID is required",
-		})
+		return SendBadRequest(c, "Session ID is required", ErrCodeMissingField)
 	}
 
 	err := h.sessionRepo.Delete(ctx, sessionID)
 	if err != nil {
 		if errors.Is(err, auth.ErrSessionNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Session not found",
-			})
+			return SendNotFound(c, "Session not found")
 		}
 		log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to revoke session")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to revoke session",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to revoke session", ErrCodeOperationFailed)
 	}
 
 	return c.JSON(fiber.Map{
@@ -97,17 +89,13 @@ func (h *AdminSessionHandler) RevokeUserSessions(c fiber.Ctx) error {
 	userID := c.Params("user_id")
 
 	if userID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "User ID is required",
-		})
+		return SendBadRequest(c, "User ID is required", ErrCodeMissingField)
 	}
 
 	err := h.sessionRepo.DeleteByUserID(ctx, userID)
 	if err != nil {
 		log.Error().Err(err).Str("user_id", userID).Msg("Failed to revoke user sessions")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to revoke user sessions",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to revoke user sessions", ErrCodeOperationFailed)
 	}
 
 	return c.JSON(fiber.Map{
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/analytics"
+	"github.com/rs/zerolog/log"
+)
+
+// AnalyticsHandler exposes the progress of analytics warehouse exports
+type AnalyticsHandler struct {
+	exporter *analytics.Exporter // nil when analytics exports are disabled
+}
+
+// NewAnalyticsHandler creates a new analytics handler. exporter may be nil.
+func NewAnalyticsHandler(exporter *analytics.Exporter) *AnalyticsHandler {
+	return &AnalyticsHandler{exporter: exporter}
+}
+
+// requireExporter responds with 503 when analytics exports are disabled
+func (h *AnalyticsHandler) requireExporter(c fiber.Ctx) bool {
+	if h.exporter != nil {
+		return true
+	}
+	_ = SendErrorWithCode(c, fiber.StatusServiceUnavailable, "Analytics exports are not enabled", ErrCodeFeatureDisabled)
+	return false
+}
+
+// ListSources returns the watermark, schema version and exported row count of each source
+// GET /api/v1/admin/analytics/sources
+func (h *AnalyticsHandler) ListSources(c fiber.Ctx) error {
+	if !h.requireExporter(c) {
+		return nil
+	}
+
+	statuses, err := h.exporter.SourceStatuses(c.RequestCtx())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get analytics export sources")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to get analytics export sources", ErrCodeOperationFailed)
+	}
+
+	return c.JSON(fiber.Map{
+		"sources": statuses,
+	})
+}
+
+// ListExports returns the most recent analytics exports
+// GET /api/v1/admin/analytics/exports?source=conversations&limit=50
+func (h *AnalyticsHandler) ListExports(c fiber.Ctx) error {
+	if !h.requireExporter(c) {
+		return nil
+	}
+
+	source := c.Query("source")
+	if _, ok := analytics.LookupSource(source); source != "" && !ok {
+		return SendBadRequest(c, fmt.Sprintf("Unknown analytics export source: %s", source), ErrCodeInvalidInput)
+	}
+
+	limit := 50
+	if v := c.Query("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l <= 0 {
+			return SendBadRequest(c, "limit must be a positive integer", ErrCodeInvalidInput)
+		}
+		limit = min(l, 1000)
+	}
+
+	exports, err := h.exporter.ListExports(c.RequestCtx(), source, limit)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list analytics exports")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to list analytics exports", ErrCodeOperationFailed)
+	}
+
+	return c.JSON(fiber.Map{
+		"exports": exports,
+	})
+}
+
+// Export exports the pending rows of one source, or of all enabled sources, right away
+// POST /api/v1/admin/analytics/exports {"source": "conversations"}
+func (h *AnalyticsHandler) Export(c fiber.Ctx) error {
+	if !h.requireExporter(c) {
+		return nil
+	}
+
+	var req struct {
+		Source string `json:"source"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&req); err != nil {
+			return SendInvalidBody(c)
+		}
+	}
+
+	if req.Source == "" {
+		records := h.exporter.ExportAll(c.RequestCtx())
+		return c.JSON(fiber.Map{
+			"exports": records,
+		})
+	}
+
+	records, err := h.exporter.ExportSource(c.RequestCtx(), req.Source)
+	if errors.Is(err, analytics.ErrSourceNotEnabled) {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("source", req.Source).Msg("Manual analytics export failed")
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"error":   fmt.Sprintf("Analytics export failed: %v", err),
+			"code":    ErrCodeUpstreamError,
+			"exports": records,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"exports": records,
+	})
+}
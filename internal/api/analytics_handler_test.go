@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/analytics"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyticsHandler_ExportsDisabled(t *testing.T) {
+	h := NewAnalyticsHandler(nil)
+	app := fiber.New()
+	app.Get("/analytics/sources", h.ListSources)
+	app.Get("/analytics/exports", h.ListExports)
+	app.Post("/analytics/exports", h.Export)
+
+	for _, route := range [][2]string{{"GET", "/analytics/sources"}, {"GET", "/analytics/exports"}, {"POST", "/analytics/exports"}} {
+		t.Run(route[0]+" "+route[1], func(t *testing.T) {
+			req := httptest.NewRequest(route[0], route[1], strings.NewReader(`{}`))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+			assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+		})
+	}
+}
+
+func TestAnalyticsHandler_RejectsUnknownSource(t *testing.T) {
+	exporter := analytics.NewExporter(&config.AnalyticsConfig{Bucket: "warehouse", Sources: []string{"retrieval_log"}}, nil, nil)
+	h := NewAnalyticsHandler(exporter)
+	app := fiber.New()
+	app.Get("/analytics/exports", h.ListExports)
+	app.Post("/analytics/exports", h.Export)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/analytics/exports?source=auth_users", nil))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	req := httptest.NewRequest("POST", "/analytics/exports", strings.NewReader(`{"source":"conversations"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, "known but not enabled")
+}
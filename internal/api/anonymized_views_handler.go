@@ -0,0 +1,138 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/anonymize"
+	"github.com/rs/zerolog/log"
+)
+
+// AnonymizedViewsHandler manages the anonymized views of sensitive tables that analysts read
+// through the REST API
+type AnonymizedViewsHandler struct {
+	service *anonymize.Service
+}
+
+// NewAnonymizedViewsHandler creates a new anonymized views handler
+func NewAnonymizedViewsHandler(service *anonymize.Service) *AnonymizedViewsHandler {
+	return &AnonymizedViewsHandler{service: service}
+}
+
+// respondInvalidAnonymizedViewID rejects an :id parameter that is not a UUID
+func respondInvalidAnonymizedViewID(c fiber.Ctx) error {
+	return SendBadRequest(c, "Invalid anonymized view ID", ErrCodeInvalidID)
+}
+
+// respondAnonymizedViewError maps service errors to responses
+func respondAnonymizedViewError(c fiber.Ctx, err error, action string) error {
+	switch {
+	case errors.Is(err, anonymize.ErrViewNotFound):
+		return SendNotFound(c, "Anonymized view not found")
+	case errors.Is(err, anonymize.ErrViewExists):
+		return SendConflict(c, err.Error(), ErrCodeConflict)
+	case errors.Is(err, anonymize.ErrInvalidView):
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+	log.Error().Err(err).Msgf("Failed to %s", action)
+	return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to %s", action), ErrCodeOperationFailed)
+}
+
+// ListViews returns the anonymized view declarations
+// GET /api/v1/admin/anonymized-views
+func (h *AnonymizedViewsHandler) ListViews(c fiber.Ctx) error {
+	views, err := h.service.List(c.RequestCtx())
+	if err != nil {
+		return respondAnonymizedViewError(c, err, "list anonymized views")
+	}
+	return c.JSON(fiber.Map{
+		"views": views,
+	})
+}
+
+// GetView returns an anonymized view declaration
+// GET /api/v1/admin/anonymized-views/:id
+func (h *AnonymizedViewsHandler) GetView(c fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return respondInvalidAnonymizedViewID(c)
+	}
+
+	view, err := h.service.Get(c.RequestCtx(), id)
+	if err != nil {
+		return respondAnonymizedViewError(c, err, "get anonymized view")
+	}
+	return c.JSON(view)
+}
+
+// CreateView declares an anonymized view and creates it in the anonymized schema
+// POST /api/v1/admin/anonymized-views
+func (h *AnonymizedViewsHandler) CreateView(c fiber.Ctx) error {
+	var view anonymize.View
+	if err := c.Bind().Body(&view); err != nil {
+		return SendInvalidBody(c)
+	}
+	if err := view.Validate(); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	if err := h.service.Create(c.RequestCtx(), &view, getUserIDFromContext(c)); err != nil {
+		return respondAnonymizedViewError(c, err, "create anonymized view")
+	}
+
+	log.Info().
+		Str("view", view.Name).
+		Str("source", view.Source).
+		Bool("restrict_source", view.RestrictSource).
+		Str("user_id", getUserID(c)).
+		Msg("Anonymized view created")
+
+	return c.Status(fiber.StatusCreated).JSON(view)
+}
+
+// UpdateView replaces the declaration of an anonymized view and recreates it
+// PUT /api/v1/admin/anonymized-views/:id
+func (h *AnonymizedViewsHandler) UpdateView(c fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return respondInvalidAnonymizedViewID(c)
+	}
+
+	var view anonymize.View
+	if err := c.Bind().Body(&view); err != nil {
+		return SendInvalidBody(c)
+	}
+	view.ID = id
+	if err := view.Validate(); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	if err := h.service.Update(c.RequestCtx(), &view); err != nil {
+		return respondAnonymizedViewError(c, err, "update anonymized view")
+	}
+
+	log.Info().
+		Str("view", view.Name).
+		Str("source", view.Source).
+		Bool("restrict_source", view.RestrictSource).
+		Str("user_id", getUserID(c)).
+		Msg("Anonymized view updated")
+
+	return c.JSON(view)
+}
+
+// DeleteView drops an anonymized view. Grants revoked from its source table are not restored.
+// DELETE /api/v1/admin/anonymized-views/:id
+func (h *AnonymizedViewsHandler) DeleteView(c fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return respondInvalidAnonymizedViewID(c)
+	}
+
+	if err := h.service.Delete(c.RequestCtx(), id); err != nil {
+		return respondAnonymizedViewError(c, err, "delete anonymized view")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func newTestAnonymizedViewsApp() *fiber.App {
+	h := NewAnonymizedViewsHandler(nil)
+	app := fiber.New()
+	app.Post("/anonymized-views", h.CreateView)
+	app.Get("/anonymized-views/:id", h.GetView)
+	app.Put("/anonymized-views/:id", h.UpdateView)
+	app.Delete("/anonymized-views/:id", h.DeleteView)
+	return app
+}
+
+func TestAnonymizedViewsHandler_RejectsInvalidRequests(t *testing.T) {
+	const id = "5d1c7b0e-2f4a-4e8b-9c36-a7e05f1d2b94"
+
+	assertErrorResponses(t, newTestAnonymizedViewsApp(), []errorRequestCase{
+		{"view id must be a UUID", "GET", "/anonymized-views/patients", "", fiber.StatusBadRequest, "Invalid anonymized view ID"},
+		{"delete needs a UUID", "DELETE", "/anonymized-views/patients", "", fiber.StatusBadRequest, "Invalid anonymized view ID"},
+		{"create validates the name", "POST", "/anonymized-views",
+			`{"name":"patients view","source":"public.patients","columns":[{"column":"id","rule":"hash"}]}`, fiber.StatusBadRequest, "name must start"},
+		{"create requires columns", "POST", "/anonymized-views",
+			`{"name":"patients","source":"public.patients","columns":[]}`, fiber.StatusBadRequest, "at least one column"},
+		{"create validates rules", "POST", "/anonymized-views",
+			`{"name":"patients","source":"public.patients","columns":[{"column":"email","rule":"encrypt"}]}`, fiber.StatusBadRequest, "rule must be one of"},
+		{"update validates bucket sizes", "PUT", "/anonymized-views/" + id,
+			`{"name":"patients","source":"public.patients","columns":[{"column":"age","rule":"bucket","size":0}]}`, fiber.StatusBadRequest, "positive size"},
+		{"update validates roles", "PUT", "/anonymized-views/" + id,
+			`{"name":"patients","source":"public.patients","columns":[{"column":"id","rule":"keep"}],"allowed_roles":["analyst;"]}`, fiber.StatusBadRequest, "invalid role"},
+	})
+}
@@ -127,9 +127,7 @@ func (h *AppSettingsHandler) GetAppSettings(c fiber.Ctx) error {
 	settings, err := h.settingsService.ListSettings(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list system settings")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve application settings",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve application settings", ErrCodeOperationFailed)
 	}
 
 	// Build structured response
@@ -146,18 +144,14 @@ func (h *AppSettingsHandler) UpdateAppSettings(c fiber.Ctx) error {
 	var req UpdateAppSettingsRequest
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse request body")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Update authentication settings
 	if req.Authentication != nil {
 		if err := h.updateAuthSettings(ctx, req.Authentication); err != nil {
 			log.Error().Err(err).Msg("Failed to update authentication settings")
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to update authentication settings",
-			})
+			return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to update authentication settings", ErrCodeOperationFailed)
 		}
 	}
 
@@ -165,9 +159,7 @@ func (h *AppSettingsHandler) UpdateAppSettings(c fiber.Ctx) error {
 	if req.Features != nil {
 		if err := h.updateFeatureSettings(ctx, req.Features); err != nil {
 			log.Error().Err(err).Msg("Failed to update feature settings")
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to update feature settings",
-			})
+			return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to update feature settings", ErrCodeOperationFailed)
 		}
 	}
 
@@ -175,9 +167,7 @@ func (h *AppSettingsHandler) UpdateAppSettings(c fiber.Ctx) error {
 	if req.Email != nil {
 		if err := h.updateEmailSettings(ctx, req.Email); err != nil {
 			log.Error().Err(err).Msg("Failed to update email settings")
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to update email settings",
-			})
+			return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to update email settings", ErrCodeOperationFailed)
 		}
 	}
 
@@ -185,9 +175,7 @@ func (h *AppSettingsHandler) UpdateAppSettings(c fiber.Ctx) error {
 	if req.Security != nil {
 		if err := h.updateSecuritySettings(ctx, req.Security); err != nil {
 			log.Error().Err(err).Msg("Failed to update security settings")
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to update security settings",
-			})
+			return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to update security settings", ErrCodeOperationFailed)
 		}
 	}
 
@@ -195,9 +183,7 @@ func (h *AppSettingsHandler) UpdateAppSettings(c fiber.Ctx) error {
 	settings, err := h.settingsService.ListSettings(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list system settings after update")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve updated settings",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve updated settings", ErrCodeOperationFailed)
 	}
 
 	appSettings := h.buildAppSettings(settings)
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/audit"
+	"github.com/rs/zerolog/log"
+)
+
+// AuditHandler serves the audit log
+type AuditHandler struct {
+	store *audit.Store
+}
+
+// NewAuditHandler creates a new audit log handler
+func NewAuditHandler(store *audit.Store) *AuditHandler {
+	return &AuditHandler{store: store}
+}
+
+// parseAuditFilter validates ?actor=&action=&category=&resource_type=&resource_id=&since=&until=&limit=&offset=
+func parseAuditFilter(c fiber.Ctx) (audit.Filter, error) {
+	f := audit.Filter{
+		Action:       c.Query("action"),
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+		Limit:        100,
+	}
+
+	if v := c.Query("actor"); v != "" {
+		if _, err := uuid.Parse(v); err != nil {
+			return f, errors.New("actor must be a user ID")
+		}
+		f.ActorID = v
+	}
+
+	if v := c.Query("category"); v != "" {
+		f.Category = audit.Category(v)
+		if !f.Category.Valid() {
+			return f, errors.New("category must be one of: auth, admin, data")
+		}
+	}
+
+	for _, bound := range []struct {
+		param string
+		t     *time.Time
+	}{{"since", &f.Since}, {"until", &f.Until}} {
+		if v := c.Query(bound.param); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return f, errors.New(bound.param + " must be an RFC 3339 timestamp")
+			}
+			*bound.t = parsed
+		}
+	}
+	if !f.Since.IsZero() && !f.Until.IsZero() && !f.Since.Before(f.Until) {
+		return f, errors.New("since must be before until")
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return f, errors.New("limit must be a positive integer")
+		}
+		f.Limit = min(limit, 1000)
+	}
+
+	if v := c.Query("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return f, errors.New("offset must be a non-negative integer")
+		}
+		f.Offset = offset
+	}
+
+	return f, nil
+}
+
+// ListEvents returns the audit events matching the filters, newest first
+// GET /api/v1/admin/audit/events?actor=<user id>&action=auth.sign_in&since=2026-10-01T00:00:00Z&limit=50
+func (h *AuditHandler) ListEvents(c fiber.Ctx) error {
+	f, err := parseAuditFilter(c)
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	result, err := h.store.Query(c.RequestCtx(), f)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to query audit events")
+		return SendOperationFailed(c, "query audit events")
+	}
+
+	return c.JSON(result)
+}
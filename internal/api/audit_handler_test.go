@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditHandler_RejectsInvalidFilter(t *testing.T) {
+	h := NewAuditHandler(audit.NewStore(nil))
+	app := fiber.New()
+	app.Get("/audit/events", h.ListEvents)
+
+	for _, query := range []string{
+		"actor=admin",
+		"category=billing",
+		"since=yesterday",
+		"until=2026-10-18",
+		"since=2026-10-18T00:00:00Z&until=2026-10-01T00:00:00Z",
+		"limit=0",
+		"offset=-1",
+	} {
+		t.Run(query, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", "/audit/events?"+query, nil))
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+	}
+}
@@ -5,11 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/audit"
 	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/dsar"
 	"github.com/nimbleflux/fluxbase/internal/middleware"
 	"github.com/rs/zerolog/log"
 )
@@ -26,6 +30,10 @@ type AuthHandler struct {
 	authService         *auth.Service
 	captchaService      *auth.CaptchaService
 	captchaTrustService *auth.CaptchaTrustService
+	knownDeviceService  *auth.KnownDeviceService
+	serverConfig        *config.ServerConfig // Trusted proxies for the client IP and location of new-device alerts
+	dataExportService   *dsar.Service        // nil when data exports are disabled
+	auditLogger         *audit.Logger        // nil when the audit log is disabled
 	samlService         *auth.SAMLService
 	baseURL             string
 	secureCookie        bool // Whether to set Secure flag on cookies (true in production)
@@ -57,6 +65,24 @@ func (h *AuthHandler) SetCaptchaTrustService(trustService *auth.CaptchaTrustServ
 	h.captchaTrustService = trustService
 }
 
+// SetKnownDeviceService sets the service that tracks the devices users sign in from and sends
+// new-device alerts. The server config selects the trusted proxies whose client IP and
+// geolocation headers are used in the alerts.
+func (h *AuthHandler) SetKnownDeviceService(service *auth.KnownDeviceService, serverConfig *config.ServerConfig) {
+	h.knownDeviceService = service
+	h.serverConfig = serverConfig
+}
+
+// SetDataExportService sets the service that compiles users' self-serve data exports
+func (h *AuthHandler) SetDataExportService(service *dsar.Service) {
+	h.dataExportService = service
+}
+
+// SetAuditLogger sets the logger that records sign-ins and impersonation in the audit log
+func (h *AuthHandler) SetAuditLogger(logger *audit.Logger) {
+	h.auditLogger = logger
+}
+
 // AuthConfigResponse represents the public authentication configuration
 type AuthConfigResponse struct {
 	SignupEnabled            bool                        `json:"signup_enabled"`
@@ -166,18 +192,13 @@ func (h *AuthHandler) getRefreshToken(c fiber.Ctx) string {
 func (h *AuthHandler) SignUp(c fiber.Ctx) error {
 	// Check if signup is enabled
 	if !h.authService.IsSignupEnabled() {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "User registration is currently disabled",
-			"code":  "SIGNUP_DISABLED",
-		})
+		return SendForbidden(c, "User registration is currently disabled", "SIGNUP_DISABLED")
 	}
 
 	var req auth.SignUpRequest
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse signup request")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// CAPTCHA verification with adaptive trust support
@@ -189,10 +210,7 @@ func (h *AuthHandler) SignUp(c fiber.Ctx) error {
 			if req.CaptchaToken != "" {
 				if err := h.captchaService.Verify(c.RequestCtx(), req.CaptchaToken, c.IP()); err != nil {
 					log.Warn().Err(err).Str("email", req.Email).Msg("CAPTCHA verification failed for signup")
-					return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-						"error": "CAPTCHA verification failed",
-						"code":  "CAPTCHA_INVALID",
-					})
+					return SendBadRequest(c, "CAPTCHA verification failed", "CAPTCHA_INVALID")
 				}
 				captchaVerified = true
 			}
@@ -200,43 +218,25 @@ func (h *AuthHandler) SignUp(c fiber.Ctx) error {
 			// Validate the challenge (checks if CAPTCHA was required and if it was verified)
 			if err := h.captchaTrustService.ValidateChallenge(c.RequestCtx(), req.ChallengeID, "signup", c.IP(), captchaVerified); err != nil {
 				if errors.Is(err, auth.ErrCaptchaRequired) {
-					return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-						"error": "CAPTCHA verification required",
-						"code":  "CAPTCHA_REQUIRED",
-					})
+					return SendBadRequest(c, "CAPTCHA verification required", "CAPTCHA_REQUIRED")
 				}
 				if errors.Is(err, auth.ErrChallengeExpired) {
-					return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-						"error": "Challenge expired, please request a new one",
-						"code":  "CHALLENGE_EXPIRED",
-					})
+					return SendBadRequest(c, "Challenge expired, please request a new one", "CHALLENGE_EXPIRED")
 				}
 				if errors.Is(err, auth.ErrChallengeConsumed) {
-					return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-						"error": "Challenge already used, please request a new one",
-						"code":  "CHALLENGE_CONSUMED",
-					})
+					return SendBadRequest(c, "Challenge already used, please request a new one", "CHALLENGE_CONSUMED")
 				}
 				log.Warn().Err(err).Str("email", req.Email).Msg("Challenge validation failed for signup")
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": "Invalid challenge",
-					"code":  "CHALLENGE_INVALID",
-				})
+				return SendBadRequest(c, "Invalid challenge", "CHALLENGE_INVALID")
 			}
 		} else {
 			// Fall back to static CAPTCHA verification (no challenge_id provided)
 			if err := h.captchaService.VerifyForEndpoint(c.RequestCtx(), "signup", req.CaptchaToken, c.IP()); err != nil {
 				if errors.Is(err, auth.ErrCaptchaRequired) {
-					return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-						"error": "CAPTCHA verification required",
-						"code":  "CAPTCHA_REQUIRED",
-					})
+					return SendBadRequest(c, "CAPTCHA verification required", "CAPTCHA_REQUIRED")
 				}
 				log.Warn().Err(err).Str("email", req.Email).Msg("CAPTCHA verification failed for signup")
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": "CAPTCHA verification failed",
-					"code":  "CAPTCHA_INVALID",
-				})
+				return SendBadRequest(c, "CAPTCHA verification failed", "CAPTCHA_INVALID")
 			}
 			captchaVerified = req.CaptchaToken != ""
 		}
@@ -244,25 +244,22 @@ func (h *AuthHandler) SignUp(c fiber.Ctx) error {
 
 	// Validate required fields
 	if req.Email == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Email is required",
-		})
+		return SendBadRequest(c, "Email is required", ErrCodeMissingField)
 	}
 	if req.Password == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Password is required",
-		})
+		return SendBadRequest(c, "Password is required", ErrCodeMissingField)
 	}
 
 	// Create user
 	resp, err := h.authService.SignUp(c.RequestCtx(), req)
 	if err != nil {
 		log.Error().Err(err).Str("email", req.Email).Msg("Failed to sign up user")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
+	// The device that created the account is the user's first known device
+	h.recordKnownDevice(c, resp.User, req.DeviceFingerprint)
+
 	// Issue trust token if CAPTCHA was verified (for use in subsequent requests)
 	var trustToken string
 	if captchaVerified && h.captchaTrustService != nil && h.captchaTrustService.IsEnabled() {
@@ -306,18 +303,13 @@ func (h *AuthHandler) SignIn(c fiber.Ctx) error {
 
 	// Check if password login is disabled for app users
 	if h.isPasswordLoginDisabled(ctx) {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Password login is disabled. Please use an OAuth or SAML provider to sign in.",
-			"code":  "PASSWORD_LOGIN_DISABLED",
-		})
+		return SendForbidden(c, "Password login is disabled. Please use an OAuth or SAML provider to sign in.", "PASSWORD_LOGIN_DISABLED")
 	}
 
 	var req auth.SignInRequest
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse signin request")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// CAPTCHA verification with adaptive trust support
@@ -329,10 +321,7 @@ func (h *AuthHandler) SignIn(c fiber.Ctx) error {
 			if req.CaptchaToken != "" {
 				if err := h.captchaService.Verify(c.RequestCtx(), req.CaptchaToken, c.IP()); err != nil {
 					log.Warn().Err(err).Str("email", req.Email).Msg("CAPTCHA verification failed for login")
-					return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-						"error": "CAPTCHA verification failed",
-						"code":  "CAPTCHA_INVALID",
-					})
+					return SendBadRequest(c, "CAPTCHA verification failed", "CAPTCHA_INVALID")
 				}
 				captchaVerified = true
 			}
@@ -340,43 +329,25 @@ func (h *AuthHandler) SignIn(c fiber.Ctx) error {
 			// Validate the challenge (checks if CAPTCHA was required and if it was verified)
 			if err := h.captchaTrustService.ValidateChallenge(c.RequestCtx(), req.ChallengeID, "login", c.IP(), captchaVerified); err != nil {
 				if errors.Is(err, auth.ErrCaptchaRequired) {
-					return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-						"error": "CAPTCHA verification required",
-						"code":  "CAPTCHA_REQUIRED",
-					})
+					return SendBadRequest(c, "CAPTCHA verification required", "CAPTCHA_REQUIRED")
 				}
 				if errors.Is(err, auth.ErrChallengeExpired) {
-					return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-						"error": "Challenge expired, please request a new one",
-						"code":  "CHALLENGE_EXPIRED",
-					})
+					return SendBadRequest(c, "Challenge expired, please request a new one", "CHALLENGE_EXPIRED")
 				}
 				if errors.Is(err, auth.ErrChallengeConsumed) {
-					return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-						"error": "Challenge already used, please request a new one",
-						"code":  "CHALLENGE_CONSUMED",
-					})
+					return SendBadRequest(c, "Challenge already used, please request a new one", "CHALLENGE_CONSUMED")
 				}
 				log.Warn().Err(err).Str("email", req.Email).Msg("Challenge validation failed for login")
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": "Invalid challenge",
-					"code":  "CHALLENGE_INVALID",
-				})
+				return SendBadRequest(c, "Invalid challenge", "CHALLENGE_INVALID")
 			}
 		} else {
 			// Fall back to static CAPTCHA verification (no challenge_id provided)
 			if err := h.captchaService.VerifyForEndpoint(c.RequestCtx(), "login", req.CaptchaToken, c.IP()); err != nil {
 				if errors.Is(err, auth.ErrCaptchaRequired) {
-					return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-						"error": "CAPTCHA verification required",
-						"code":  "CAPTCHA_REQUIRED",
-					})
+					return SendBadRequest(c, "CAPTCHA verification required", "CAPTCHA_REQUIRED")
 				}
 				log.Warn().Err(err).Str("email", req.Email).Msg("CAPTCHA verification failed for login")
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": "CAPTCHA verification failed",
-					"code":  "CAPTCHA_INVALID",
-				})
+				return SendBadRequest(c, "CAPTCHA verification failed", "CAPTCHA_INVALID")
 			}
 			captchaVerified = req.CaptchaToken != ""
 		}
@@ -384,9 +355,7 @@ func (h *AuthHandler) SignIn(c fiber.Ctx) error {
 
 	// Validate required fields
 	if req.Email == "" || req.Password == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Email and password are required",
-		})
+		return SendBadRequest(c, "Email and password are required", ErrCodeMissingField)
 	}
 
 	// Authenticate user
@@ -396,14 +365,12 @@ func (h *AuthHandler) SignIn(c fiber.Ctx) error {
 		if h.captchaTrustService != nil {
 			_ = h.captchaTrustService.RecordFailedAttempt(ctx, nil, c.IP(), req.DeviceFingerprint, c.Get("User-Agent"))
 		}
+		h.recordSignInFailure(c, audit.Event{ActorEmail: req.Email}, "password", err)
 
 		// Check for locked account
 		if errors.Is(err, auth.ErrAccountLocked) {
 			log.Warn().Str("email", req.Email).Msg("Login attempt on locked account")
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Account locked due to too many failed login attempts. Please contact support.",
-				"code":  "ACCOUNT_LOCKED",
-			})
+			return SendForbidden(c, "Account locked due to too many failed login attempts. Please contact support.", "ACCOUNT_LOCKED")
 		}
 		// Check for email not verified
 		if errors.Is(err, auth.ErrEmailNotVerified) {
@@ -415,9 +382,7 @@ func (h *AuthHandler) SignIn(c fiber.Ctx) error {
 			})
 		}
 		log.Error().Err(err).Str("email", req.Email).Msg("Failed to sign in user")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid email or password",
-		})
+		return SendUnauthorized(c, "Invalid email or password", ErrCodeInvalidCredentials)
 	}
 
 	// Record successful login for trust tracking
@@ -430,6 +395,9 @@ func (h *AuthHandler) SignIn(c fiber.Ctx) error {
 		}
 	}
 
+	// Record the device and alert the user when it is new
+	h.recordKnownDevice(c, resp.User, req.DeviceFingerprint)
+
 	// Issue trust token if CAPTCHA was verified (for use in subsequent requests)
 	var trustToken string
 	if captchaVerified && h.captchaTrustService != nil && h.captchaTrustService.IsEnabled() {
@@ -441,6 +409,7 @@ func (h *AuthHandler) SignIn(c fiber.Ctx) error {
 	if err != nil {
 		log.Error().Err(err).Str("user_id", resp.User.ID).Msg("Failed to check 2FA status")
 		// Continue with login - don't block if 2FA check fails
+		h.recordSignIn(c, resp.User, "password")
 		// Set httpOnly cookies for tokens
 		h.setAuthCookies(c, resp.AccessToken, resp.RefreshToken, resp.ExpiresIn)
 		if trustToken != "" {
@@ -468,6 +437,8 @@ func (h *AuthHandler) SignIn(c fiber.Ctx) error {
 		return c.Status(fiber.StatusOK).JSON(response)
 	}
 
+	h.recordSignIn(c, resp.User, "password")
+
 	// Set httpOnly cookies for tokens
 	h.setAuthCookies(c, resp.AccessToken, resp.RefreshToken, resp.ExpiresIn)
 
@@ -491,9 +462,7 @@ func (h *AuthHandler) SignOut(c fiber.Ctx) error {
 	// Get token from cookie or Authorization header
 	token := h.getAccessToken(c)
 	if token == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "No authentication token provided",
-		})
+		return SendBadRequest(c, "No authentication token provided", ErrCodeInvalidInput)
 	}
 
 	ctx := c.RequestCtx()
@@ -532,9 +501,7 @@ func (h *AuthHandler) SignOut(c fiber.Ctx) error {
 		log.Error().Err(err).Msg("Failed to sign out user")
 		// Clear cookies even if sign out fails
 		h.clearAuthCookies(c)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to sign out",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to sign out", ErrCodeOperationFailed)
 	}
 
 	// Clear authentication cookies
@@ -571,9 +538,7 @@ func (h *AuthHandler) RefreshToken(c fiber.Ctx) error {
 
 	// Validate required fields
 	if req.RefreshToken == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Refresh token is required",
-		})
+		return SendBadRequest(c, "Refresh token is required", ErrCodeMissingField)
 	}
 
 	// Refresh token
@@ -582,9 +547,7 @@ func (h *AuthHandler) RefreshToken(c fiber.Ctx) error {
 		log.Error().Err(err).Msg("Failed to refresh token")
 		// Clear cookies on refresh failure
 		h.clearAuthCookies(c)
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid or expired refresh token",
-		})
+		return SendUnauthorized(c, "Invalid or expired refresh token", ErrCodeExpiredToken)
 	}
 
 	// Set httpOnly cookies for new tokens
@@ -593,15 +556,54 @@ func (h *AuthHandler) RefreshToken(c fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(resp)
 }
 
+// SwitchOrganizationRequest represents a request to change the active organization
+type SwitchOrganizationRequest struct {
+	RefreshToken string `json:"refresh_token"`
+	// Organization to activate, empty to clear the active organization
+	OrganizationID string `json:"organization_id"`
+}
+
+// SwitchOrganization rotates the session into tokens with another active organization in the
+// org_id and org_role claims
+// POST /auth/orgs/switch
+func (h *AuthHandler) SwitchOrganization(c fiber.Ctx) error {
+	var req SwitchOrganizationRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+	if req.RefreshToken == "" {
+		req.RefreshToken = h.getRefreshToken(c)
+	}
+	if req.RefreshToken == "" {
+		return SendBadRequest(c, "Refresh token is required", ErrCodeMissingField)
+	}
+	if req.OrganizationID != "" {
+		if _, err := uuid.Parse(req.OrganizationID); err != nil {
+			return SendBadRequest(c, "Invalid organization_id", ErrCodeInvalidInput)
+		}
+	}
+
+	resp, err := h.authService.SwitchOrganization(c.RequestCtx(), req.RefreshToken, req.OrganizationID)
+	if errors.Is(err, auth.ErrOrgMemberNotFound) {
+		return SendNotFound(c, auth.ErrOrganizationNotFound.Error())
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to switch organization")
+		return SendUnauthorized(c, "Invalid or expired refresh token", ErrCodeExpiredToken)
+	}
+
+	h.setAuthCookies(c, resp.AccessToken, resp.RefreshToken, resp.ExpiresIn)
+
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
 // GetUser handles getting current user profile
 // GET /auth/user
 func (h *AuthHandler) GetUser(c fiber.Ctx) error {
 	// Get token from Authorization header
 	token := c.Get("Authorization")
 	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authorization header is required",
-		})
+		return SendUnauthorized(c, "Authorization header is required", ErrCodeAuthRequired)
 	}
 
 	// Remove "Bearer " prefix if present
@@ -613,9 +615,7 @@ func (h *AuthHandler) GetUser(c fiber.Ctx) error {
 	user, err := h.authService.GetUser(c.RequestCtx(), token)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get user")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid or expired token",
-		})
+		return SendUnauthorized(c, "Invalid or expired token", ErrCodeExpiredToken)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(user)
@@ -627,26 +627,20 @@ func (h *AuthHandler) UpdateUser(c fiber.Ctx) error {
 	// Get user ID from context (set by auth middleware)
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Unauthorized",
-		})
+		return SendUnauthorized(c, "Unauthorized", ErrCodeAuthRequired)
 	}
 
 	var req auth.UpdateUserRequest
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse update user request")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Update user
 	user, err := h.authService.UpdateUser(c.RequestCtx(), userID.(string), req)
 	if err != nil {
 		log.Error().Err(err).Str("user_id", userID.(string)).Msg("Failed to update user")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(user)
@@ -661,41 +655,29 @@ func (h *AuthHandler) SendMagicLink(c fiber.Ctx) error {
 	}
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse magic link request")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Verify CAPTCHA if enabled for magic_link
 	if h.captchaService != nil {
 		if err := h.captchaService.VerifyForEndpoint(c.RequestCtx(), "magic_link", req.CaptchaToken, c.IP()); err != nil {
 			if errors.Is(err, auth.ErrCaptchaRequired) {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": "CAPTCHA verification required",
-					"code":  "CAPTCHA_REQUIRED",
-				})
+				return SendBadRequest(c, "CAPTCHA verification required", "CAPTCHA_REQUIRED")
 			}
 			log.Warn().Err(err).Str("email", req.Email).Msg("CAPTCHA verification failed for magic link")
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "CAPTCHA verification failed",
-				"code":  "CAPTCHA_INVALID",
-			})
+			return SendBadRequest(c, "CAPTCHA verification failed", "CAPTCHA_INVALID")
 		}
 	}
 
 	// Validate email
 	if req.Email == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Email is required",
-		})
+		return SendBadRequest(c, "Email is required", ErrCodeMissingField)
 	}
 
 	// Send magic link
 	if err := h.authService.SendMagicLink(c.RequestCtx(), req.Email); err != nil {
 		log.Error().Err(err).Str("email", req.Email).Msg("Failed to send magic link")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	// Return Supabase-compatible OTP response
@@ -713,27 +695,26 @@ func (h *AuthHandler) VerifyMagicLink(c fiber.Ctx) error {
 	}
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse verify magic link request")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate token
 	if req.Token == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Token is required",
-		})
+		return SendBadRequest(c, "Token is required", ErrCodeMissingField)
 	}
 
 	// Verify magic link
 	resp, err := h.authService.VerifyMagicLink(c.RequestCtx(), req.Token)
 	if err != nil {
+		h.recordSignInFailure(c, audit.Event{}, "magic_link", err)
+		if errors.Is(err, auth.ErrAccountLocked) {
+			return SendForbidden(c, "Account locked. Please contact support.", "ACCOUNT_LOCKED")
+		}
 		log.Error().Err(err).Msg("Failed to verify magic link")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
+	h.recordSignIn(c, resp.User, "magic_link")
 	return c.Status(fiber.StatusOK).JSON(resp)
 }
 
@@ -748,65 +729,43 @@ func (h *AuthHandler) RequestPasswordReset(c fiber.Ctx) error {
 
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse password reset request")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Verify CAPTCHA if enabled for password_reset
 	if h.captchaService != nil {
 		if err := h.captchaService.VerifyForEndpoint(c.RequestCtx(), "password_reset", req.CaptchaToken, c.IP()); err != nil {
 			if errors.Is(err, auth.ErrCaptchaRequired) {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": "CAPTCHA verification required",
-					"code":  "CAPTCHA_REQUIRED",
-				})
+				return SendBadRequest(c, "CAPTCHA verification required", "CAPTCHA_REQUIRED")
 			}
 			log.Warn().Err(err).Str("email", req.Email).Msg("CAPTCHA verification failed for password reset")
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "CAPTCHA verification failed",
-				"code":  "CAPTCHA_INVALID",
-			})
+			return SendBadRequest(c, "CAPTCHA verification failed", "CAPTCHA_INVALID")
 		}
 	}
 
 	// Validate email
 	if req.Email == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Email is required",
-		})
+		return SendBadRequest(c, "Email is required", ErrCodeMissingField)
 	}
 
 	// Request password reset (this won't reveal if user exists)
 	if err := h.authService.RequestPasswordReset(c.RequestCtx(), req.Email, req.RedirectTo); err != nil {
 		// Check for SMTP not configured error - this should be returned to the user
 		if errors.Is(err, auth.ErrSMTPNotConfigured) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "SMTP is not configured. Please configure an email provider to enable password reset.",
-				"code":  "SMTP_NOT_CONFIGURED",
-			})
+			return SendBadRequest(c, "SMTP is not configured. Please configure an email provider to enable password reset.", "SMTP_NOT_CONFIGURED")
 		}
 		// Check for invalid redirect URL - return error to prevent misuse
 		if errors.Is(err, auth.ErrInvalidRedirectURL) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid redirect_to URL. Must be a valid HTTP or HTTPS URL.",
-				"code":  "INVALID_REDIRECT_URL",
-			})
+			return SendBadRequest(c, "Invalid redirect_to URL. Must be a valid HTTP or HTTPS URL.", "INVALID_REDIRECT_URL")
 		}
 		// Check for rate limiting - user requested reset too soon
 		if errors.Is(err, auth.ErrPasswordResetTooSoon) {
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"error": "Password reset requested too recently. Please wait 60 seconds before trying again.",
-				"code":  "RATE_LIMITED",
-			})
+			return SendErrorWithCode(c, fiber.StatusTooManyRequests, "Password reset requested too recently. Please wait 60 seconds before trying again.", "RATE_LIMITED")
 		}
 		// Check for email sending failure - this should be returned to the user
 		if errors.Is(err, auth.ErrEmailSendFailed) {
 			log.Error().Err(err).Str("email", req.Email).Msg("Failed to send password reset email")
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to send password reset email. Please try again later.",
-				"code":  "EMAIL_SEND_FAILED",
-			})
+			return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to send password reset email. Please try again later.", "EMAIL_SEND_FAILED")
 		}
 		log.Error().Err(err).Str("email", req.Email).Msg("Failed to request password reset")
 		// Don't reveal if user exists - always return success
@@ -829,39 +788,29 @@ func (h *AuthHandler) ResetPassword(c fiber.Ctx) error {
 
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse reset password request")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate required fields
 	if req.Token == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Token is required",
-		})
+		return SendBadRequest(c, "Token is required", ErrCodeMissingField)
 	}
 	if req.NewPassword == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "New password is required",
-		})
+		return SendBadRequest(c, "New password is required", ErrCodeMissingField)
 	}
 
 	// Reset password and get user ID
 	userID, err := h.authService.ResetPassword(c.RequestCtx(), req.Token, req.NewPassword)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to reset password")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	// Generate new tokens for the user (Supabase-compatible)
 	resp, err := h.authService.GenerateTokensForUser(c.RequestCtx(), userID)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to generate tokens after password reset")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to generate authentication tokens",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to generate authentication tokens", ErrCodeOperationFailed)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(resp)
@@ -876,24 +825,18 @@ func (h *AuthHandler) VerifyPasswordResetToken(c fiber.Ctx) error {
 
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse verify token request")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate token
 	if req.Token == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Token is required",
-		})
+		return SendBadRequest(c, "Token is required", ErrCodeMissingField)
 	}
 
 	// Verify token
 	if err := h.authService.VerifyPasswordResetToken(c.RequestCtx(), req.Token); err != nil {
 		log.Error().Err(err).Msg("Failed to verify password reset token")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -908,42 +851,27 @@ func (h *AuthHandler) VerifyEmail(c fiber.Ctx) error {
 		Token string `json:"token"`
 	}
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	if req.Token == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Token is required",
-		})
+		return SendBadRequest(c, "Token is required", ErrCodeMissingField)
 	}
 
 	user, err := h.authService.VerifyEmailToken(c.RequestCtx(), req.Token)
 	if err != nil {
 		// Check for specific token errors
 		if errors.Is(err, auth.ErrEmailVerificationTokenNotFound) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid or expired verification token",
-				"code":  "INVALID_TOKEN",
-			})
+			return SendBadRequest(c, "Invalid or expired verification token", "INVALID_TOKEN")
 		}
 		if errors.Is(err, auth.ErrEmailVerificationTokenExpired) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Verification token has expired. Please request a new one.",
-				"code":  "TOKEN_EXPIRED",
-			})
+			return SendBadRequest(c, "Verification token has expired. Please request a new one.", "TOKEN_EXPIRED")
 		}
 		if errors.Is(err, auth.ErrEmailVerificationTokenUsed) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "This verification token has already been used",
-				"code":  "TOKEN_USED",
-			})
+			return SendBadRequest(c, "This verification token has already been used", "TOKEN_USED")
 		}
 		log.Error().Err(err).Msg("Failed to verify email")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -959,15 +887,11 @@ func (h *AuthHandler) ResendVerificationEmail(c fiber.Ctx) error {
 		Email string `json:"email"`
 	}
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	if req.Email == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Email is required",
-		})
+		return SendBadRequest(c, "Email is required", ErrCodeMissingField)
 	}
 
 	// Get user by email
@@ -989,9 +913,7 @@ func (h *AuthHandler) ResendVerificationEmail(c fiber.Ctx) error {
 	// Send verification email
 	if err := h.authService.SendEmailVerification(c.RequestCtx(), user.ID, user.Email); err != nil {
 		log.Error().Err(err).Str("email", req.Email).Msg("Failed to resend verification email")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to send verification email. Please try again later.",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to send verification email. Please try again later.", ErrCodeOperationFailed)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -1021,6 +943,7 @@ func (h *AuthHandler) RegisterRoutes(router fiber.Router, rateLimiters map[strin
 	router.Post("/signin", rateLimiters["login"], h.SignIn)
 	// NOTE: Anonymous sign-in endpoint removed for security - reduces attack surface
 	router.Post("/refresh", rateLimiters["refresh"], h.RefreshToken)
+	router.Post("/orgs/switch", rateLimiters["refresh"], h.SwitchOrganization) // Authenticated by the refresh token
 	router.Post("/magiclink", rateLimiters["magiclink"], h.SendMagicLink)
 	router.Post("/magiclink/verify", h.VerifyMagicLink) // No rate limit on verification
 	router.Post("/password/reset", rateLimiters["password_reset"], h.RequestPasswordReset)
@@ -1071,6 +994,16 @@ func (h *AuthHandler) RegisterRoutes(router fiber.Router, rateLimiters map[strin
 	router.Post("/user/identities", authMiddleware, middleware.RequireScope(auth.ScopeAuthWrite), h.LinkIdentity)
 	router.Delete("/user/identities/:id", authMiddleware, middleware.RequireScope(auth.ScopeAuthWrite), h.UnlinkIdentity)
 
+	// Known device routes (protected - authentication required) with scope enforcement
+	router.Get("/user/devices", authMiddleware, middleware.RequireScope(auth.ScopeAuthRead), h.ListKnownDevices)
+	router.Delete("/user/devices/:id", authMiddleware, middleware.RequireScope(auth.ScopeAuthWrite), h.DeleteKnownDevice)
+
+	// Data export routes (protected - authentication required) with scope enforcement
+	router.Post("/user/data-exports", authMiddleware, middleware.RequireScope(auth.ScopeAuthWrite), h.RequestDataExport)
+	router.Get("/user/data-exports", authMiddleware, middleware.RequireScope(auth.ScopeAuthRead), h.ListDataExports)
+	router.Get("/user/data-exports/:id", authMiddleware, middleware.RequireScope(auth.ScopeAuthRead), h.GetDataExport)
+	router.Get("/user/data-exports/:id/download", authMiddleware, middleware.RequireScope(auth.ScopeAuthRead), h.DownloadDataExport)
+
 	// Reauthentication route (protected - authentication required)
 	router.Post("/reauthenticate", authMiddleware, middleware.RequireScope(auth.ScopeAuthWrite), h.Reauthenticate)
 }
@@ -1079,9 +1012,7 @@ func (h *AuthHandler) RegisterRoutes(router fiber.Router, rateLimiters map[strin
 // Anonymous sign-in reduces security by allowing anyone to get tokens
 // Use regular signup/signin flow instead
 func (h *AuthHandler) SignInAnonymous(c fiber.Ctx) error {
-	return c.Status(fiber.StatusGone).JSON(fiber.Map{
-		"error": "Anonymous sign-in has been disabled for security reasons",
-	})
+	return SendErrorWithCode(c, fiber.StatusGone, "Anonymous sign-in has been disabled for security reasons", ErrCodeGone)
 }
 
 // GetCSRFToken returns the current CSRF token for the client
@@ -1101,16 +1032,12 @@ func (h *AuthHandler) StartImpersonation(c fiber.Ctx) error {
 	// Get admin user ID from context (must be authenticated)
 	adminUserID := c.Locals("user_id")
 	if adminUserID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 
 	var req auth.StartImpersonationRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Set IP and user agent from request
@@ -1125,11 +1052,10 @@ func (h *AuthHandler) StartImpersonation(c fiber.Ctx) error {
 		} else if errors.Is(err, auth.ErrSelfImpersonation) {
 			statusCode = fiber.StatusBadRequest
 		}
-		return c.Status(statusCode).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendError(c, statusCode, err.Error())
 	}
 
+	h.recordImpersonationStart(c, resp)
 	return c.Status(fiber.StatusOK).JSON(resp)
 }
 
@@ -1138,23 +1064,31 @@ func (h *AuthHandler) StopImpersonation(c fiber.Ctx) error {
 	// Get admin user ID from context
 	adminUserID := c.Locals("user_id")
 	if adminUserID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
+	}
+
+	// Look up the session first so the audit event can name it
+	var sessionID string
+	if h.auditLogger != nil {
+		if session, err := h.authService.GetActiveImpersonation(c.RequestCtx(), adminUserID.(string)); err == nil {
+			sessionID = session.ID
+		}
 	}
 
 	err := h.authService.StopImpersonation(c.RequestCtx(), adminUserID.(string))
 	if err != nil {
 		if errors.Is(err, auth.ErrNoActiveImpersonation) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": err.Error(),
-			})
+			return SendNotFound(c, err.Error())
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendInternalError(c, err.Error())
 	}
 
+	h.auditLogger.RecordRequest(c, audit.Event{
+		Action:       audit.ActionImpersonationStop,
+		ResourceType: "impersonation_session",
+		ResourceID:   sessionID,
+	})
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "Impersonation session ended",
 	})
@@ -1165,21 +1099,15 @@ func (h *AuthHandler) GetActiveImpersonation(c fiber.Ctx) error {
 	// Get admin user ID from context
 	adminUserID := c.Locals("user_id")
 	if adminUserID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 
 	session, err := h.authService.GetActiveImpersonation(c.RequestCtx(), adminUserID.(string))
 	if err != nil {
 		if errors.Is(err, auth.ErrNoActiveImpersonation) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": err.Error(),
-			})
+			return SendNotFound(c, err.Error())
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendInternalError(c, err.Error())
 	}
 
 	return c.Status(fiber.StatusOK).JSON(session)
@@ -1190,9 +1118,7 @@ func (h *AuthHandler) ListImpersonationSessions(c fiber.Ctx) error {
 	// Get admin user ID from context
 	adminUserID := c.Locals("user_id")
 	if adminUserID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 
 	limit := fiber.Query[int](c, "limit", 50)
@@ -1200,9 +1126,7 @@ func (h *AuthHandler) ListImpersonationSessions(c fiber.Ctx) error {
 
 	sessions, err := h.authService.ListImpersonationSessions(c.RequestCtx(), adminUserID.(string), limit, offset)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendInternalError(c, err.Error())
 	}
 
 	return c.Status(fiber.StatusOK).JSON(sessions)
@@ -1213,24 +1137,18 @@ func (h *AuthHandler) StartAnonImpersonation(c fiber.Ctx) error {
 	// Get admin user ID from context (must be authenticated)
 	adminUserID := c.Locals("user_id")
 	if adminUserID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 
 	var req struct {
 		Reason string `json:"reason"`
 	}
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	if req.Reason == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Reason is required",
-		})
+		return SendBadRequest(c, "Reason is required", ErrCodeMissingField)
 	}
 
 	// Set IP and user agent from request
@@ -1243,11 +1161,10 @@ func (h *AuthHandler) StartAnonImpersonation(c fiber.Ctx) error {
 		if errors.Is(err, auth.ErrNotAdmin) {
 			statusCode = fiber.StatusForbidden
 		}
-		return c.Status(statusCode).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendError(c, statusCode, err.Error())
 	}
 
+	h.recordImpersonationStart(c, resp)
 	return c.Status(fiber.StatusOK).JSON(resp)
 }
 
@@ -1256,24 +1173,18 @@ func (h *AuthHandler) StartServiceImpersonation(c fiber.Ctx) error {
 	// Get admin user ID from context (must be authenticated)
 	adminUserID := c.Locals("user_id")
 	if adminUserID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 
 	var req struct {
 		Reason string `json:"reason"`
 	}
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	if req.Reason == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Reason is required",
-		})
+		return SendBadRequest(c, "Reason is required", ErrCodeMissingField)
 	}
 
 	// Set IP and user agent from request
@@ -1286,11 +1197,10 @@ func (h *AuthHandler) StartServiceImpersonation(c fiber.Ctx) error {
 		if errors.Is(err, auth.ErrNotAdmin) {
 			statusCode = fiber.StatusForbidden
 		}
-		return c.Status(statusCode).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendError(c, statusCode, err.Error())
 	}
 
+	h.recordImpersonationStart(c, resp)
 	return c.Status(fiber.StatusOK).JSON(resp)
 }
 
@@ -1300,9 +1210,7 @@ func (h *AuthHandler) SetupTOTP(c fiber.Ctx) error {
 	// Get user ID from JWT token
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Unauthorized",
-		})
+		return SendUnauthorized(c, "Unauthorized", ErrCodeAuthRequired)
 	}
 
 	// Parse optional issuer from request body
@@ -1315,9 +1223,7 @@ func (h *AuthHandler) SetupTOTP(c fiber.Ctx) error {
 	response, err := h.authService.SetupTOTP(c.RequestCtx(), userID.(string), req.Issuer)
 	if err != nil {
 		log.Error().Err(err).Str("user_id", userID.(string)).Msg("Failed to setup TOTP")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to setup 2FA",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to setup 2FA", ErrCodeOperationFailed)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(response)
@@ -1329,32 +1235,24 @@ func (h *AuthHandler) EnableTOTP(c fiber.Ctx) error {
 	// Get user ID from JWT token
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Unauthorized",
-		})
+		return SendUnauthorized(c, "Unauthorized", ErrCodeAuthRequired)
 	}
 
 	var req struct {
 		Code string `json:"code"`
 	}
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	if req.Code == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Code is required",
-		})
+		return SendBadRequest(c, "Code is required", ErrCodeMissingField)
 	}
 
 	backupCodes, err := h.authService.EnableTOTP(c.RequestCtx(), userID.(string), req.Code)
 	if err != nil {
 		log.Error().Err(err).Str("user_id", userID.(string)).Msg("Failed to enable TOTP")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -1372,35 +1270,29 @@ func (h *AuthHandler) VerifyTOTP(c fiber.Ctx) error {
 		Code   string `json:"code"`
 	}
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	if req.UserID == "" || req.Code == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "User ID and code are required",
-		})
+		return SendBadRequest(c, "User ID and code are required", ErrCodeMissingField)
 	}
 
 	// Verify the 2FA code
 	err := h.authService.VerifyTOTP(c.RequestCtx(), req.UserID, req.Code)
 	if err != nil {
+		h.recordSignInFailure(c, audit.Event{ActorID: req.UserID}, "totp", err)
 		log.Warn().Err(err).Str("user_id", req.UserID).Msg("Failed to verify TOTP")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	// Generate a complete sign-in response with tokens
 	resp, err := h.authService.GenerateTokensForUser(c.RequestCtx(), req.UserID)
 	if err != nil {
 		log.Error().Err(err).Str("user_id", req.UserID).Msg("Failed to generate tokens after 2FA verification")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to complete authentication",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to complete authentication", ErrCodeOperationFailed)
 	}
 
+	h.recordSignIn(c, resp.User, "totp")
 	return c.Status(fiber.StatusOK).JSON(resp)
 }
 
@@ -1410,32 +1302,24 @@ func (h *AuthHandler) DisableTOTP(c fiber.Ctx) error {
 	// Get user ID from JWT token
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Unauthorized",
-		})
+		return SendUnauthorized(c, "Unauthorized", ErrCodeAuthRequired)
 	}
 
 	var req struct {
 		Password string `json:"password"`
 	}
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	if req.Password == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Password is required to disable 2FA",
-		})
+		return SendBadRequest(c, "Password is required to disable 2FA", ErrCodeMissingField)
 	}
 
 	err := h.authService.DisableTOTP(c.RequestCtx(), userID.(string), req.Password)
 	if err != nil {
 		log.Error().Err(err).Str("user_id", userID.(string)).Msg("Failed to disable TOTP")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -1450,17 +1334,13 @@ func (h *AuthHandler) GetTOTPStatus(c fiber.Ctx) error {
 	// Get user ID from JWT token
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Unauthorized",
-		})
+		return SendUnauthorized(c, "Unauthorized", ErrCodeAuthRequired)
 	}
 
 	enabled, err := h.authService.IsTOTPEnabled(c.RequestCtx(), userID.(string))
 	if err != nil {
 		log.Error().Err(err).Str("user_id", userID.(string)).Msg("Failed to check TOTP status")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to check 2FA status",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to check 2FA status", ErrCodeOperationFailed)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -1478,23 +1358,17 @@ func (h *AuthHandler) SendOTP(c fiber.Ctx) error {
 	}
 
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate that either email or phone is provided
 	if err := auth.ValidateOTPContact(req.Email, req.Phone); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	// Validate auth service is initialized (after input validation)
 	if h.authService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Authentication service not available",
-		})
+		return SendInternalError(c, "Authentication service not available")
 	}
 
 	// Send OTP
@@ -1515,9 +1389,7 @@ func (h *AuthHandler) SendOTP(c fiber.Ctx) error {
 
 	if err != nil {
 		log.Error().Str("error", err.Error()).Msg("Failed to send OTP")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to send OTP code",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to send OTP code", ErrCodeOperationFailed)
 	}
 
 	// Return Supabase-compatible OTP response
@@ -1539,15 +1411,11 @@ func (h *AuthHandler) VerifyOTP(c fiber.Ctx) error {
 	}
 
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	if req.Token == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "OTP token is required",
-		})
+		return SendBadRequest(c, "OTP token is required", ErrCodeMissingField)
 	}
 
 	// Verify OTP
@@ -1556,25 +1424,20 @@ func (h *AuthHandler) VerifyOTP(c fiber.Ctx) error {
 
 	// Validate that either email or phone is provided
 	if err := auth.ValidateOTPContact(req.Email, req.Phone); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	if req.Email != nil {
 		otpCode, err = h.authService.VerifyOTP(c.RequestCtx(), *req.Email, req.Token)
 	} else if req.Phone != nil {
 		// Phone OTP not yet fully implemented
-		return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{
-			"error": "Phone-based OTP authentication not yet implemented",
-		})
+		return SendErrorWithCode(c, fiber.StatusNotImplemented, "Phone-based OTP authentication not yet implemented", ErrCodeNotImplemented)
 	}
 
 	if err != nil {
+		h.recordSignInFailure(c, audit.Event{ActorEmail: *req.Email}, "otp", err)
 		log.Warn().Err(err).Msg("Failed to verify OTP")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid or expired OTP code",
-		})
+		return SendUnauthorized(c, "Invalid or expired OTP code", ErrCodeExpiredToken)
 	}
 
 	// Get existing user - auto-creation is disabled for security
@@ -1584,9 +1447,7 @@ func (h *AuthHandler) VerifyOTP(c fiber.Ctx) error {
 		user, err = h.authService.GetUserByEmail(c.RequestCtx(), *otpCode.Email)
 		if err != nil {
 			log.Warn().Str("email", *otpCode.Email).Msg("OTP verification for non-existent user")
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "No account found for this email - please sign up first",
-			})
+			return SendNotFound(c, "No account found for this email - please sign up first")
 		}
 	}
 
@@ -1594,11 +1455,10 @@ func (h *AuthHandler) VerifyOTP(c fiber.Ctx) error {
 	resp, err := h.authService.GenerateTokensForUser(c.RequestCtx(), user.ID)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to generate tokens")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to complete authentication",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to complete authentication", ErrCodeOperationFailed)
 	}
 
+	h.recordSignIn(c, resp.User, "otp")
 	return c.Status(fiber.StatusOK).JSON(resp)
 }
 
@@ -1613,16 +1473,12 @@ func (h *AuthHandler) ResendOTP(c fiber.Ctx) error {
 	}
 
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate that either email or phone is provided
 	if err := auth.ValidateOTPContact(req.Email, req.Phone); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	purpose := "signin" // Default purpose
@@ -1643,9 +1499,7 @@ func (h *AuthHandler) ResendOTP(c fiber.Ctx) error {
 
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to resend OTP")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to resend OTP code",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to resend OTP code", ErrCodeOperationFailed)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -1659,17 +1513,13 @@ func (h *AuthHandler) ResendOTP(c fiber.Ctx) error {
 func (h *AuthHandler) GetUserIdentities(c fiber.Ctx) error {
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Unauthorized",
-		})
+		return SendUnauthorized(c, "Unauthorized", ErrCodeAuthRequired)
 	}
 
 	identities, err := h.authService.GetUserIdentities(c.RequestCtx(), userID.(string))
 	if err != nil {
 		log.Error().Err(err).Str("user_id", userID.(string)).Msg("Failed to get user identities")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve identities",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve identities", ErrCodeOperationFailed)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -1682,9 +1532,7 @@ func (h *AuthHandler) GetUserIdentities(c fiber.Ctx) error {
 func (h *AuthHandler) LinkIdentity(c fiber.Ctx) error {
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Unauthorized",
-		})
+		return SendUnauthorized(c, "Unauthorized", ErrCodeAuthRequired)
 	}
 
 	var req struct {
@@ -1692,23 +1540,17 @@ func (h *AuthHandler) LinkIdentity(c fiber.Ctx) error {
 	}
 
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	if req.Provider == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Provider is required",
-		})
+		return SendBadRequest(c, "Provider is required", ErrCodeMissingField)
 	}
 
 	authURL, state, err := h.authService.LinkIdentity(c.RequestCtx(), userID.(string), req.Provider)
 	if err != nil {
 		log.Error().Err(err).Str("provider", req.Provider).Msg("Failed to initiate identity linking")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -1723,24 +1565,18 @@ func (h *AuthHandler) LinkIdentity(c fiber.Ctx) error {
 func (h *AuthHandler) UnlinkIdentity(c fiber.Ctx) error {
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Unauthorized",
-		})
+		return SendUnauthorized(c, "Unauthorized", ErrCodeAuthRequired)
 	}
 
 	identityID := c.Params("id")
 	if identityID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Identity ID is required",
-		})
+		return SendBadRequest(c, "Identity ID is required", ErrCodeMissingField)
 	}
 
 	err := h.authService.UnlinkIdentity(c.RequestCtx(), userID.(string), identityID)
 	if err != nil {
 		log.Error().Err(err).Str("identity_id", identityID).Msg("Failed to unlink identity")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -1748,22 +1584,167 @@ func (h *AuthHandler) UnlinkIdentity(c fiber.Ctx) error {
 	})
 }
 
+// recordKnownDevice records a successful sign-in from a device and, if the device is new to the
+// user, emails them a security notification in the background
+func (h *AuthHandler) recordKnownDevice(c fiber.Ctx, user *auth.User, deviceFingerprint string) {
+	if h.knownDeviceService == nil || deviceFingerprint == "" || user == nil {
+		return
+	}
+	userUUID, err := uuid.Parse(user.ID)
+	if err != nil {
+		return
+	}
+
+	signIn := auth.DeviceSignIn{
+		DeviceFingerprint: deviceFingerprint,
+		IPAddress:         c.IP(),
+		UserAgent:         c.Get("User-Agent"),
+	}
+	if h.serverConfig != nil {
+		signIn.IPAddress = middleware.GetTrustedClientIP(c, h.serverConfig).String()
+		signIn.Location = middleware.GetApproximateLocation(c, h.serverConfig)
+	}
+
+	isNew, err := h.knownDeviceService.RecordSignIn(c.RequestCtx(), userUUID, signIn)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", user.ID).Msg("Failed to record known device")
+		return
+	}
+	if !isNew {
+		return
+	}
+
+	log.Info().Str("user_id", user.ID).Str("ip_address", signIn.IPAddress).Msg("Sign-in from a new device")
+	signedInAt := time.Now()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := h.knownDeviceService.SendNewDeviceAlert(ctx, user.Email, signIn, signedInAt); err != nil {
+			log.Warn().Err(err).Str("user_id", user.ID).Msg("Failed to send new device alert")
+		}
+	}()
+}
+
+// recordSignIn records a successful sign-in in the audit log
+func (h *AuthHandler) recordSignIn(c fiber.Ctx, user *auth.User, method string) {
+	if user == nil {
+		return
+	}
+	h.auditLogger.RecordRequest(c, audit.Event{
+		Action:     audit.ActionSignIn,
+		ActorID:    user.ID,
+		ActorEmail: user.Email,
+		ActorRole:  user.Role,
+		Details:    map[string]any{"method": method},
+	})
+}
+
+// recordSignInFailure records a failed sign-in attempt in the audit log. e identifies the
+// account the attempt was for, as far as the request tells.
+func (h *AuthHandler) recordSignInFailure(c fiber.Ctx, e audit.Event, method string, err error) {
+	reason := "invalid_credentials"
+	switch {
+	case errors.Is(err, auth.ErrAccountLocked):
+		reason = "account_locked"
+	case errors.Is(err, auth.ErrEmailNotVerified):
+		reason = "email_not_verified"
+	}
+
+	e.Action = audit.ActionSignInFailed
+	e.Details = map[string]any{"method": method, "reason": reason}
+	h.auditLogger.RecordRequest(c, e)
+}
+
+// recordImpersonationStart records the start of an impersonation session in the audit log
+func (h *AuthHandler) recordImpersonationStart(c fiber.Ctx, resp *auth.StartImpersonationResponse) {
+	if resp == nil || resp.Session == nil {
+		return
+	}
+	session := resp.Session
+
+	details := map[string]any{
+		"impersonation_type": session.ImpersonationType,
+		"reason":             session.Reason,
+	}
+	if session.TargetUserID != nil {
+		details["target_user_id"] = *session.TargetUserID
+	}
+	if session.TargetRole != nil {
+		details["target_role"] = *session.TargetRole
+	}
+
+	h.auditLogger.RecordRequest(c, audit.Event{
+		Action:       audit.ActionImpersonationStart,
+		ResourceType: "impersonation_session",
+		ResourceID:   session.ID,
+		Details:      details,
+	})
+}
+
+// ListKnownDevices lists the devices the current user has signed in from
+// GET /auth/user/devices
+func (h *AuthHandler) ListKnownDevices(c fiber.Ctx) error {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		return SendUnauthorized(c, "Unauthorized", ErrCodeAuthRequired)
+	}
+	if h.knownDeviceService == nil {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"devices": []auth.KnownDevice{},
+		})
+	}
+
+	devices, err := h.knownDeviceService.ListDevices(c.RequestCtx(), *userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID.String()).Msg("Failed to list known devices")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve devices", ErrCodeOperationFailed)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"devices": devices,
+	})
+}
+
+// DeleteKnownDevice removes a device of the current user, so that the next sign-in from it
+// sends a new-device alert again
+// DELETE /auth/user/devices/:id
+func (h *AuthHandler) DeleteKnownDevice(c fiber.Ctx) error {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		return SendUnauthorized(c, "Unauthorized", ErrCodeAuthRequired)
+	}
+
+	deviceID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return SendBadRequest(c, "Invalid device ID", ErrCodeInvalidID)
+	}
+	if h.knownDeviceService == nil {
+		return SendNotFound(c, "Device not found")
+	}
+
+	if err := h.knownDeviceService.DeleteDevice(c.RequestCtx(), *userID, deviceID); err != nil {
+		if errors.Is(err, auth.ErrKnownDeviceNotFound) {
+			return SendNotFound(c, "Device not found")
+		}
+		log.Error().Err(err).Str("device_id", deviceID.String()).Msg("Failed to delete known device")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to delete device", ErrCodeOperationFailed)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
 // Reauthenticate generates a security nonce
 // POST /auth/reauthenticate
 func (h *AuthHandler) Reauthenticate(c fiber.Ctx) error {
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Unauthorized",
-		})
+		return SendUnauthorized(c, "Unauthorized", ErrCodeAuthRequired)
 	}
 
 	nonce, err := h.authService.Reauthenticate(c.RequestCtx(), userID.(string))
 	if err != nil {
 		log.Error().Err(err).Str("user_id", userID.(string)).Msg("Failed to reauthenticate")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to generate security nonce",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to generate security nonce", ErrCodeOperationFailed)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -1781,15 +1762,11 @@ func (h *AuthHandler) SignInWithIDToken(c fiber.Ctx) error {
 	}
 
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	if req.Provider == "" || req.Token == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Provider and token are required",
-		})
+		return SendBadRequest(c, "Provider and token are required", ErrCodeMissingField)
 	}
 
 	nonce := ""
@@ -1799,12 +1776,15 @@ func (h *AuthHandler) SignInWithIDToken(c fiber.Ctx) error {
 
 	resp, err := h.authService.SignInWithIDToken(c.RequestCtx(), req.Provider, req.Token, nonce)
 	if err != nil {
+		h.recordSignInFailure(c, audit.Event{}, "id_token", err)
+		if errors.Is(err, auth.ErrAccountLocked) {
+			return SendForbidden(c, "Account locked. Please contact support.", "ACCOUNT_LOCKED")
+		}
 		log.Error().Err(err).Str("provider", req.Provider).Msg("Failed to sign in with ID token")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
+	h.recordSignIn(c, resp.User, "id_token")
 	return c.Status(fiber.StatusOK).JSON(resp)
 }
 
@@ -1852,10 +1832,7 @@ func (h *AuthHandler) CheckCaptcha(c fiber.Ctx) error {
 	// Parse request
 	var req auth.CaptchaCheckRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-			"code":  "INVALID_REQUEST",
-		})
+		return SendBadRequest(c, "Invalid request body", "INVALID_REQUEST")
 	}
 
 	// Validate endpoint
@@ -1866,10 +1843,7 @@ func (h *AuthHandler) CheckCaptcha(c fiber.Ctx) error {
 		"magic_link":     true,
 	}
 	if !validEndpoints[req.Endpoint] {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid endpoint. Must be one of: signup, login, password_reset, magic_link",
-			"code":  "INVALID_ENDPOINT",
-		})
+		return SendBadRequest(c, "Invalid endpoint. Must be one of: signup, login, password_reset, magic_link", "INVALID_ENDPOINT")
 	}
 
 	// If CAPTCHA is not enabled at all, return early
@@ -1998,3 +1972,115 @@ func (h *AuthHandler) isPasswordLoginDisabled(ctx context.Context) bool {
 }
 
 // fiber:context-methods migrated
+
+// RequestDataExport starts compiling an archive of everything Fluxbase holds about the current
+// user. The user is emailed when it's ready to download.
+// POST /auth/user/data-exports
+func (h *AuthHandler) RequestDataExport(c fiber.Ctx) error {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		return SendUnauthorized(c, "Unauthorized", ErrCodeAuthRequired)
+	}
+	if h.dataExportService == nil {
+		return SendErrorWithCode(c, fiber.StatusNotFound, "Data exports are disabled", ErrCodeFeatureDisabled)
+	}
+
+	export, err := h.dataExportService.Request(c.RequestCtx(), *userID)
+	if err != nil {
+		if errors.Is(err, dsar.ErrExportInProgress) {
+			return SendConflict(c, "A data export is already in progress", ErrCodeConflict)
+		}
+		log.Error().Err(err).Str("user_id", userID.String()).Msg("Failed to request data export")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to request data export", ErrCodeOperationFailed)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(export)
+}
+
+// ListDataExports lists the data exports of the current user
+// GET /auth/user/data-exports
+func (h *AuthHandler) ListDataExports(c fiber.Ctx) error {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		return SendUnauthorized(c, "Unauthorized", ErrCodeAuthRequired)
+	}
+	if h.dataExportService == nil {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"exports": []dsar.Export{},
+		})
+	}
+
+	exports, err := h.dataExportService.List(c.RequestCtx(), *userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID.String()).Msg("Failed to list data exports")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve data exports", ErrCodeOperationFailed)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"exports": exports,
+	})
+}
+
+// GetDataExport returns a data export of the current user
+// GET /auth/user/data-exports/:id
+func (h *AuthHandler) GetDataExport(c fiber.Ctx) error {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		return SendUnauthorized(c, "Unauthorized", ErrCodeAuthRequired)
+	}
+
+	exportID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return SendBadRequest(c, "Invalid export ID", ErrCodeInvalidID)
+	}
+	if h.dataExportService == nil {
+		return SendNotFound(c, "Data export not found")
+	}
+
+	export, err := h.dataExportService.Get(c.RequestCtx(), *userID, exportID)
+	if err != nil {
+		if errors.Is(err, dsar.ErrExportNotFound) {
+			return SendNotFound(c, "Data export not found")
+		}
+		log.Error().Err(err).Str("export_id", exportID.String()).Msg("Failed to get data export")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve data export", ErrCodeOperationFailed)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(export)
+}
+
+// DownloadDataExport streams the archive of a completed data export of the current user
+// GET /auth/user/data-exports/:id/download
+func (h *AuthHandler) DownloadDataExport(c fiber.Ctx) error {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		return SendUnauthorized(c, "Unauthorized", ErrCodeAuthRequired)
+	}
+
+	exportID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return SendBadRequest(c, "Invalid export ID", ErrCodeInvalidID)
+	}
+	if h.dataExportService == nil {
+		return SendNotFound(c, "Data export not found")
+	}
+
+	reader, export, err := h.dataExportService.Open(c.RequestCtx(), *userID, exportID)
+	if err != nil {
+		switch {
+		case errors.Is(err, dsar.ErrExportNotFound):
+			return SendNotFound(c, "Data export not found")
+		case errors.Is(err, dsar.ErrExportNotReady):
+			return SendConflict(c, "Data export is not ready", ErrCodeConflict)
+		case errors.Is(err, dsar.ErrExportExpired):
+			return SendErrorWithCode(c, fiber.StatusGone, "Data export has expired", ErrCodeGone)
+		}
+		log.Error().Err(err).Str("export_id", exportID.String()).Msg("Failed to download data export")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to download data export", ErrCodeOperationFailed)
+	}
+
+	c.Set("Content-Type", "application/zip")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"data-export-%s.zip\"", export.CreatedAt.UTC().Format("2006-01-02")))
+	// SendStream closes the reader
+	return c.SendStream(reader)
+}
@@ -1661,3 +1661,113 @@ func TestListImpersonationSessions_QueryParameters(t *testing.T) {
 		})
 	}
 }
+
+// =============================================================================
+// Known Device Tests
+// =============================================================================
+
+func TestAuthHandler_KnownDevices(t *testing.T) {
+	handler := NewAuthHandler(nil, nil, nil, "https://example.com")
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	app := fiber.New()
+	withUser := func(c fiber.Ctx) error {
+		if c.Get("X-Test-User") != "" {
+			c.Locals("user_id", c.Get("X-Test-User"))
+		}
+		return c.Next()
+	}
+	app.Get("/auth/user/devices", withUser, handler.ListKnownDevices)
+	app.Delete("/auth/user/devices/:id", withUser, handler.DeleteKnownDevice)
+
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		user       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"list unauthenticated", "GET", "/auth/user/devices", "", fiber.StatusUnauthorized, ""},
+		{"list without device tracking", "GET", "/auth/user/devices", userID, fiber.StatusOK, `{"devices":[]}`},
+		{"delete unauthenticated", "DELETE", "/auth/user/devices/22222222-2222-2222-2222-222222222222", "", fiber.StatusUnauthorized, ""},
+		{"delete invalid id", "DELETE", "/auth/user/devices/not-a-uuid", userID, fiber.StatusBadRequest, ""},
+		{"delete without device tracking", "DELETE", "/auth/user/devices/22222222-2222-2222-2222-222222222222", userID, fiber.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			if tt.user != "" {
+				req.Header.Set("X-Test-User", tt.user)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			if tt.wantBody != "" {
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, tt.wantBody, string(body))
+			}
+		})
+	}
+}
+
+func TestAuthHandler_DataExports(t *testing.T) {
+	handler := NewAuthHandler(nil, nil, nil, "https://example.com")
+	userID := "11111111-1111-1111-1111-111111111111"
+	exportURL := "/auth/user/data-exports/22222222-2222-2222-2222-222222222222"
+
+	app := fiber.New()
+	withUser := func(c fiber.Ctx) error {
+		if c.Get("X-Test-User") != "" {
+			c.Locals("user_id", c.Get("X-Test-User"))
+		}
+		return c.Next()
+	}
+	app.Post("/auth/user/data-exports", withUser, handler.RequestDataExport)
+	app.Get("/auth/user/data-exports", withUser, handler.ListDataExports)
+	app.Get("/auth/user/data-exports/:id", withUser, handler.GetDataExport)
+	app.Get("/auth/user/data-exports/:id/download", withUser, handler.DownloadDataExport)
+
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		user       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"request unauthenticated", "POST", "/auth/user/data-exports", "", fiber.StatusUnauthorized, ""},
+		{"request with exports disabled", "POST", "/auth/user/data-exports", userID, fiber.StatusNotFound, `{"error":"Data exports are disabled","code":"FEATURE_DISABLED"}`},
+		{"list unauthenticated", "GET", "/auth/user/data-exports", "", fiber.StatusUnauthorized, ""},
+		{"list with exports disabled", "GET", "/auth/user/data-exports", userID, fiber.StatusOK, `{"exports":[]}`},
+		{"get unauthenticated", "GET", exportURL, "", fiber.StatusUnauthorized, ""},
+		{"get invalid id", "GET", "/auth/user/data-exports/not-a-uuid", userID, fiber.StatusBadRequest, ""},
+		{"get with exports disabled", "GET", exportURL, userID, fiber.StatusNotFound, ""},
+		{"download unauthenticated", "GET", exportURL + "/download", "", fiber.StatusUnauthorized, ""},
+		{"download invalid id", "GET", "/auth/user/data-exports/not-a-uuid/download", userID, fiber.StatusBadRequest, ""},
+		{"download with exports disabled", "GET", exportURL + "/download", userID, fiber.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			if tt.user != "" {
+				req.Header.Set("X-Test-User", tt.user)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			if tt.wantBody != "" {
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, tt.wantBody, string(body))
+			}
+		})
+	}
+}
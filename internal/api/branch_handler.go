@@ -73,25 +73,16 @@ type CreateBranchRequest struct {
 // CreateBranch handles POST /admin/branches
 func (h *BranchHandler) CreateBranch(c fiber.Ctx) error {
 	if !h.config.Enabled {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error":   "branching_disabled",
-			"message": "Database branching is not enabled",
-		})
+		return SendErrorWithDetails(c, fiber.StatusServiceUnavailable, "branching_disabled", "BRANCHING_DISABLED", "Database branching is not enabled", "", nil)
 	}
 
 	var req CreateBranchRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "invalid_request",
-			"message": "Failed to parse request body: " + err.Error(),
-		})
+		return SendErrorWithDetails(c, fiber.StatusBadRequest, "invalid_request", ErrCodeInvalidInput, "Failed to parse request body: "+err.Error(), "", nil)
 	}
 
 	if req.Name == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "validation_error",
-			"message": "Branch name is required",
-		})
+		return SendErrorWithDetails(c, fiber.StatusBadRequest, "validation_error", ErrCodeValidationFailed, "Branch name is required", "", nil)
 	}
 
 	// Get user ID from context
@@ -107,10 +98,7 @@ func (h *BranchHandler) CreateBranch(c fiber.Ctx) error {
 	if req.ExpiresIn != nil && *req.ExpiresIn != "" {
 		duration, err := time.ParseDuration(*req.ExpiresIn)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error":   "validation_error",
-				"message": "Invalid expires_in duration: " + err.Error(),
-			})
+			return SendErrorWithDetails(c, fiber.StatusBadRequest, "validation_error", ErrCodeValidationFailed, "Invalid expires_in duration: "+err.Error(), "", nil)
 		}
 		t := time.Now().Add(duration)
 		expiresAt = &t
@@ -133,27 +121,15 @@ func (h *BranchHandler) CreateBranch(c fiber.Ctx) error {
 		log.Error().Err(err).Str("name", req.Name).Msg("Failed to create branch")
 
 		if errors.Is(err, branching.ErrBranchExists) {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error":   "branch_exists",
-				"message": "A branch with this name already exists",
-			})
+			return SendErrorWithDetails(c, fiber.StatusConflict, "branch_exists", "BRANCH_EXISTS", "A branch with this name already exists", "", nil)
 		}
 		if errors.Is(err, branching.ErrMaxBranchesReached) {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":   "max_branches_reached",
-				"message": "Maximum number of branches has been reached",
-			})
+			return SendErrorWithDetails(c, fiber.StatusForbidden, "max_branches_reached", "MAX_BRANCHES_REACHED", "Maximum number of branches has been reached", "", nil)
 		}
 		if errors.Is(err, branching.ErrInvalidSlug) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error":   "invalid_slug",
-				"message": err.Error(),
-			})
+			return SendErrorWithDetails(c, fiber.StatusBadRequest, "invalid_slug", "INVALID_SLUG", err.Error(), "", nil)
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "create_failed",
-			"message": "Failed to create branch: " + err.Error(),
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "create_failed", ErrCodeOperationFailed, "Failed to create branch: "+err.Error(), "", nil)
 	}
 
 	// Warmup the connection pool
@@ -172,10 +148,7 @@ func (h *BranchHandler) CreateBranch(c fiber.Ctx) error {
 func (h *BranchHandler) ListBranches(c fiber.Ctx) error {
 	// Nil check for manager (can happen in tests)
 	if h.manager == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "not_initialized",
-			"message": "Branch manager not initialized",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "not_initialized", "NOT_INITIALIZED", "Branch manager not initialized", "", nil)
 	}
 
 	filter := branching.ListBranchesFilter{
@@ -214,10 +187,7 @@ func (h *BranchHandler) ListBranches(c fiber.Ctx) error {
 	branches, err := h.manager.GetStorage().ListBranches(c.RequestCtx(), filter)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list branches")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "list_failed",
-			"message": "Failed to list branches",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "list_failed", ErrCodeOperationFailed, "Failed to list branches", "", nil)
 	}
 
 	// Get total count
@@ -239,10 +209,7 @@ func (h *BranchHandler) ListBranches(c fiber.Ctx) error {
 func (h *BranchHandler) GetBranch(c fiber.Ctx) error {
 	// Nil check for manager (can happen in tests)
 	if h.manager == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "not_initialized",
-			"message": "Branch manager not initialized",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "not_initialized", "NOT_INITIALIZED", "Branch manager not initialized", "", nil)
 	}
 
 	idParam := c.Params("id")
@@ -260,16 +227,10 @@ func (h *BranchHandler) GetBranch(c fiber.Ctx) error {
 
 	if err != nil {
 		if errors.Is(err, branching.ErrBranchNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":   "branch_not_found",
-				"message": "Branch not found",
-			})
+			return SendErrorWithDetails(c, fiber.StatusNotFound, "branch_not_found", "BRANCH_NOT_FOUND", "Branch not found", "", nil)
 		}
 		log.Error().Err(err).Str("id", idParam).Msg("Failed to get branch")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "get_failed",
-			"message": "Failed to get branch",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "get_failed", ErrCodeOperationFailed, "Failed to get branch", "", nil)
 	}
 
 	return c.JSON(branch)
@@ -279,10 +240,7 @@ func (h *BranchHandler) GetBranch(c fiber.Ctx) error {
 func (h *BranchHandler) DeleteBranch(c fiber.Ctx) error {
 	// Nil check for manager (can happen in tests)
 	if h.manager == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "not_initialized",
-			"message": "Branch manager not initialized",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "not_initialized", "NOT_INITIALIZED", "Branch manager not initialized", "", nil)
 	}
 
 	idParam := c.Params("id")
@@ -305,15 +263,9 @@ func (h *BranchHandler) DeleteBranch(c fiber.Ctx) error {
 
 	if err != nil {
 		if errors.Is(err, branching.ErrBranchNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":   "branch_not_found",
-				"message": "Branch not found",
-			})
+			return SendErrorWithDetails(c, fiber.StatusNotFound, "branch_not_found", "BRANCH_NOT_FOUND", "Branch not found", "", nil)
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "get_failed",
-			"message": "Failed to get branch",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "get_failed", ErrCodeOperationFailed, "Failed to get branch", "", nil)
 	}
 
 	// Get user ID from context
@@ -334,16 +286,10 @@ func (h *BranchHandler) DeleteBranch(c fiber.Ctx) error {
 		hasAccess, err := h.manager.GetStorage().HasAccess(c.RequestCtx(), branch.ID, *userID, branching.BranchAccessAdmin)
 		if err != nil {
 			log.Error().Err(err).Str("branch_id", branch.ID.String()).Msg("Failed to check branch access")
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   "access_check_failed",
-				"message": "Failed to verify branch access",
-			})
+			return SendErrorWithDetails(c, fiber.StatusInternalServerError, "access_check_failed", ErrCodeOperationFailed, "Failed to verify branch access", "", nil)
 		}
 		if !hasAccess {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":   "access_denied",
-				"message": "You do not have permission to delete this branch",
-			})
+			return SendErrorWithDetails(c, fiber.StatusForbidden, "access_denied", ErrCodeAccessDenied, "You do not have permission to delete this branch", "", nil)
 		}
 	}
 
@@ -357,16 +303,10 @@ func (h *BranchHandler) DeleteBranch(c fiber.Ctx) error {
 		log.Error().Err(err).Str("id", idParam).Msg("Failed to delete branch")
 
 		if errors.Is(err, branching.ErrCannotDeleteMainBranch) {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":   "cannot_delete_main",
-				"message": "Cannot delete the main branch",
-			})
+			return SendErrorWithDetails(c, fiber.StatusForbidden, "cannot_delete_main", "CANNOT_DELETE_MAIN", "Cannot delete the main branch", "", nil)
 		}
 
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "delete_failed",
-			"message": "Failed to delete branch: " + err.Error(),
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "delete_failed", ErrCodeOperationFailed, "Failed to delete branch: "+err.Error(), "", nil)
 	}
 
 	return c.Status(fiber.StatusNoContent).Send(nil)
@@ -376,10 +316,7 @@ func (h *BranchHandler) DeleteBranch(c fiber.Ctx) error {
 func (h *BranchHandler) ResetBranch(c fiber.Ctx) error {
 	// Nil check for manager (can happen in tests)
 	if h.manager == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "not_initialized",
-			"message": "Branch manager not initialized",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "not_initialized", "NOT_INITIALIZED", "Branch manager not initialized", "", nil)
 	}
 
 	idParam := c.Params("id")
@@ -402,15 +339,9 @@ func (h *BranchHandler) ResetBranch(c fiber.Ctx) error {
 
 	if err != nil {
 		if errors.Is(err, branching.ErrBranchNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":   "branch_not_found",
-				"message": "Branch not found",
-			})
+			return SendErrorWithDetails(c, fiber.StatusNotFound, "branch_not_found", "BRANCH_NOT_FOUND", "Branch not found", "", nil)
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "get_failed",
-			"message": "Failed to get branch",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "get_failed", ErrCodeOperationFailed, "Failed to get branch", "", nil)
 	}
 
 	// Get user ID from context
@@ -431,16 +362,10 @@ func (h *BranchHandler) ResetBranch(c fiber.Ctx) error {
 		hasAccess, err := h.manager.GetStorage().HasAccess(c.RequestCtx(), branch.ID, *userID, branching.BranchAccessAdmin)
 		if err != nil {
 			log.Error().Err(err).Str("branch_id", branch.ID.String()).Msg("Failed to check branch access")
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   "access_check_failed",
-				"message": "Failed to verify branch access",
-			})
+			return SendErrorWithDetails(c, fiber.StatusInternalServerError, "access_check_failed", ErrCodeOperationFailed, "Failed to verify branch access", "", nil)
 		}
 		if !hasAccess {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":   "access_denied",
-				"message": "You do not have permission to reset this branch",
-			})
+			return SendErrorWithDetails(c, fiber.StatusForbidden, "access_denied", ErrCodeAccessDenied, "You do not have permission to reset this branch", "", nil)
 		}
 	}
 
@@ -454,16 +379,10 @@ func (h *BranchHandler) ResetBranch(c fiber.Ctx) error {
 		log.Error().Err(err).Str("id", idParam).Msg("Failed to reset branch")
 
 		if errors.Is(err, branching.ErrCannotDeleteMainBranch) {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":   "cannot_reset_main",
-				"message": "Cannot reset the main branch",
-			})
+			return SendErrorWithDetails(c, fiber.StatusForbidden, "cannot_reset_main", "CANNOT_RESET_MAIN", "Cannot reset the main branch", "", nil)
 		}
 
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "reset_failed",
-			"message": "Failed to reset branch: " + err.Error(),
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "reset_failed", ErrCodeOperationFailed, "Failed to reset branch: "+err.Error(), "", nil)
 	}
 
 	// Refresh the connection pool
@@ -486,10 +405,7 @@ func (h *BranchHandler) ResetBranch(c fiber.Ctx) error {
 func (h *BranchHandler) GetBranchActivity(c fiber.Ctx) error {
 	// Nil check for manager (can happen in tests)
 	if h.manager == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "not_initialized",
-			"message": "Branch manager not initialized",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "not_initialized", "NOT_INITIALIZED", "Branch manager not initialized", "", nil)
 	}
 
 	idParam := c.Params("id")
@@ -504,15 +420,9 @@ func (h *BranchHandler) GetBranchActivity(c fiber.Ctx) error {
 		branch, err := h.manager.GetStorage().GetBranchBySlug(c.RequestCtx(), idParam)
 		if err != nil {
 			if errors.Is(err, branching.ErrBranchNotFound) {
-				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-					"error":   "branch_not_found",
-					"message": "Branch not found",
-				})
+				return SendErrorWithDetails(c, fiber.StatusNotFound, "branch_not_found", "BRANCH_NOT_FOUND", "Branch not found", "", nil)
 			}
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   "get_failed",
-				"message": "Failed to get branch",
-			})
+			return SendErrorWithDetails(c, fiber.StatusInternalServerError, "get_failed", ErrCodeOperationFailed, "Failed to get branch", "", nil)
 		}
 		branchID = branch.ID
 	}
@@ -525,10 +435,7 @@ func (h *BranchHandler) GetBranchActivity(c fiber.Ctx) error {
 	activity, err := h.manager.GetStorage().GetActivityLog(c.RequestCtx(), branchID, limit)
 	if err != nil {
 		log.Error().Err(err).Str("id", idParam).Msg("Failed to get branch activity")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "get_activity_failed",
-			"message": "Failed to get branch activity",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "get_activity_failed", ErrCodeOperationFailed, "Failed to get branch activity", "", nil)
 	}
 
 	return c.JSON(fiber.Map{
@@ -578,33 +485,21 @@ type SetActiveBranchRequest struct {
 // SetActiveBranch handles POST /admin/branches/active
 func (h *BranchHandler) SetActiveBranch(c fiber.Ctx) error {
 	if !h.config.Enabled {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error":   "branching_disabled",
-			"message": "Database branching is not enabled",
-		})
+		return SendErrorWithDetails(c, fiber.StatusServiceUnavailable, "branching_disabled", "BRANCHING_DISABLED", "Database branching is not enabled", "", nil)
 	}
 
 	var req SetActiveBranchRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "invalid_request",
-			"message": "Failed to parse request body: " + err.Error(),
-		})
+		return SendErrorWithDetails(c, fiber.StatusBadRequest, "invalid_request", ErrCodeInvalidInput, "Failed to parse request body: "+err.Error(), "", nil)
 	}
 
 	if req.Branch == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "validation_error",
-			"message": "Branch slug is required",
-		})
+		return SendErrorWithDetails(c, fiber.StatusBadRequest, "validation_error", ErrCodeValidationFailed, "Branch slug is required", "", nil)
 	}
 
 	// Nil check for manager (can happen in tests)
 	if h.manager == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "not_initialized",
-			"message": "Branch manager not initialized",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "not_initialized", "NOT_INITIALIZED", "Branch manager not initialized", "", nil)
 	}
 
 	// Verify the branch exists (unless it's "main")
@@ -612,16 +507,10 @@ func (h *BranchHandler) SetActiveBranch(c fiber.Ctx) error {
 		_, err := h.manager.GetStorage().GetBranchBySlug(c.RequestCtx(), req.Branch)
 		if err != nil {
 			if errors.Is(err, branching.ErrBranchNotFound) {
-				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-					"error":   "branch_not_found",
-					"message": "Branch not found: " + req.Branch,
-				})
+				return SendErrorWithDetails(c, fiber.StatusNotFound, "branch_not_found", "BRANCH_NOT_FOUND", "Branch not found: "+req.Branch, "", nil)
 			}
 			log.Error().Err(err).Str("branch", req.Branch).Msg("Failed to verify branch")
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   "verification_failed",
-				"message": "Failed to verify branch exists",
-			})
+			return SendErrorWithDetails(c, fiber.StatusInternalServerError, "verification_failed", ErrCodeOperationFailed, "Failed to verify branch exists", "", nil)
 		}
 	}
 
@@ -688,10 +577,7 @@ func (h *BranchHandler) ListGitHubConfigs(c fiber.Ctx) error {
 	configs, err := h.manager.GetStorage().ListGitHubConfigs(c.RequestCtx())
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list GitHub configs")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "list_failed",
-			"message": "Failed to list GitHub configurations",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "list_failed", ErrCodeOperationFailed, "Failed to list GitHub configurations", "", nil)
 	}
 
 	return c.JSON(fiber.Map{
@@ -712,25 +598,16 @@ type UpsertGitHubConfigRequest struct {
 func (h *BranchHandler) UpsertGitHubConfig(c fiber.Ctx) error {
 	var req UpsertGitHubConfigRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "invalid_request",
-			"message": "Failed to parse request body: " + err.Error(),
-		})
+		return SendErrorWithDetails(c, fiber.StatusBadRequest, "invalid_request", ErrCodeInvalidInput, "Failed to parse request body: "+err.Error(), "", nil)
 	}
 
 	if req.Repository == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "validation_error",
-			"message": "Repository is required",
-		})
+		return SendErrorWithDetails(c, fiber.StatusBadRequest, "validation_error", ErrCodeValidationFailed, "Repository is required", "", nil)
 	}
 
 	// Nil check for manager (can happen in tests)
 	if h.manager == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "not_initialized",
-			"message": "Branch manager not initialized",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "not_initialized", "NOT_INITIALIZED", "Branch manager not initialized", "", nil)
 	}
 
 	config := &branching.GitHubConfig{
@@ -755,10 +632,7 @@ func (h *BranchHandler) UpsertGitHubConfig(c fiber.Ctx) error {
 
 	if err := h.manager.GetStorage().UpsertGitHubConfig(c.RequestCtx(), config); err != nil {
 		log.Error().Err(err).Str("repository", req.Repository).Msg("Failed to upsert GitHub config")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "upsert_failed",
-			"message": "Failed to save GitHub configuration",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "upsert_failed", ErrCodeOperationFailed, "Failed to save GitHub configuration", "", nil)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(config)
@@ -768,26 +642,17 @@ func (h *BranchHandler) UpsertGitHubConfig(c fiber.Ctx) error {
 func (h *BranchHandler) DeleteGitHubConfig(c fiber.Ctx) error {
 	// Nil check for manager (can happen in tests)
 	if h.manager == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "not_initialized",
-			"message": "Branch manager not initialized",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "not_initialized", "NOT_INITIALIZED", "Branch manager not initialized", "", nil)
 	}
 
 	repository := c.Params("repository")
 
 	if err := h.manager.GetStorage().DeleteGitHubConfig(c.RequestCtx(), repository); err != nil {
 		if errors.Is(err, branching.ErrGitHubConfigNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":   "config_not_found",
-				"message": "GitHub configuration not found",
-			})
+			return SendErrorWithDetails(c, fiber.StatusNotFound, "config_not_found", "CONFIG_NOT_FOUND", "GitHub configuration not found", "", nil)
 		}
 		log.Error().Err(err).Str("repository", repository).Msg("Failed to delete GitHub config")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "delete_failed",
-			"message": "Failed to delete GitHub configuration",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "delete_failed", ErrCodeOperationFailed, "Failed to delete GitHub configuration", "", nil)
 	}
 
 	return c.Status(fiber.StatusNoContent).Send(nil)
@@ -799,10 +664,7 @@ func (h *BranchHandler) DeleteGitHubConfig(c fiber.Ctx) error {
 func (h *BranchHandler) ListBranchAccess(c fiber.Ctx) error {
 	// Nil check for manager (can happen in tests)
 	if h.manager == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "not_initialized",
-			"message": "Branch manager not initialized",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "not_initialized", "NOT_INITIALIZED", "Branch manager not initialized", "", nil)
 	}
 
 	idParam := c.Params("id")
@@ -819,15 +681,9 @@ func (h *BranchHandler) ListBranchAccess(c fiber.Ctx) error {
 
 	if err != nil {
 		if errors.Is(err, branching.ErrBranchNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":   "branch_not_found",
-				"message": "Branch not found",
-			})
+			return SendErrorWithDetails(c, fiber.StatusNotFound, "branch_not_found", "BRANCH_NOT_FOUND", "Branch not found", "", nil)
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "get_failed",
-			"message": "Failed to get branch",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "get_failed", ErrCodeOperationFailed, "Failed to get branch", "", nil)
 	}
 
 	// Check authorization
@@ -845,26 +701,17 @@ func (h *BranchHandler) ListBranchAccess(c fiber.Ctx) error {
 	if !isAdmin && userID != nil {
 		hasAccess, err := h.manager.GetStorage().HasAccess(c.RequestCtx(), branch.ID, *userID, branching.BranchAccessAdmin)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   "access_check_failed",
-				"message": "Failed to verify branch access",
-			})
+			return SendErrorWithDetails(c, fiber.StatusInternalServerError, "access_check_failed", ErrCodeOperationFailed, "Failed to verify branch access", "", nil)
 		}
 		if !hasAccess {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":   "access_denied",
-				"message": "You do not have permission to view access grants for this branch",
-			})
+			return SendErrorWithDetails(c, fiber.StatusForbidden, "access_denied", ErrCodeAccessDenied, "You do not have permission to view access grants for this branch", "", nil)
 		}
 	}
 
 	accessList, err := h.manager.GetStorage().GetBranchAccessList(c.RequestCtx(), branch.ID)
 	if err != nil {
 		log.Error().Err(err).Str("branch_id", branch.ID.String()).Msg("Failed to list branch access")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "list_failed",
-			"message": "Failed to list branch access",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "list_failed", ErrCodeOperationFailed, "Failed to list branch access", "", nil)
 	}
 
 	return c.JSON(fiber.Map{
@@ -882,10 +729,7 @@ type GrantBranchAccessRequest struct {
 func (h *BranchHandler) GrantBranchAccess(c fiber.Ctx) error {
 	// Nil check for manager (can happen in tests)
 	if h.manager == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "not_initialized",
-			"message": "Branch manager not initialized",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "not_initialized", "NOT_INITIALIZED", "Branch manager not initialized", "", nil)
 	}
 
 	idParam := c.Params("id")
@@ -902,49 +746,31 @@ func (h *BranchHandler) GrantBranchAccess(c fiber.Ctx) error {
 
 	if err != nil {
 		if errors.Is(err, branching.ErrBranchNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":   "branch_not_found",
-				"message": "Branch not found",
-			})
+			return SendErrorWithDetails(c, fiber.StatusNotFound, "branch_not_found", "BRANCH_NOT_FOUND", "Branch not found", "", nil)
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "get_failed",
-			"message": "Failed to get branch",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "get_failed", ErrCodeOperationFailed, "Failed to get branch", "", nil)
 	}
 
 	// Parse request body
 	var req GrantBranchAccessRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "invalid_request",
-			"message": "Failed to parse request body: " + err.Error(),
-		})
+		return SendErrorWithDetails(c, fiber.StatusBadRequest, "invalid_request", ErrCodeInvalidInput, "Failed to parse request body: "+err.Error(), "", nil)
 	}
 
 	if req.UserID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "validation_error",
-			"message": "user_id is required",
-		})
+		return SendErrorWithDetails(c, fiber.StatusBadRequest, "validation_error", ErrCodeValidationFailed, "user_id is required", "", nil)
 	}
 
 	targetUserID, err := uuid.Parse(req.UserID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "validation_error",
-			"message": "Invalid user_id format",
-		})
+		return SendErrorWithDetails(c, fiber.StatusBadRequest, "validation_error", ErrCodeValidationFailed, "Invalid user_id format", "", nil)
 	}
 
 	accessLevel := branching.BranchAccessLevel(req.AccessLevel)
 	if accessLevel != branching.BranchAccessRead &&
 		accessLevel != branching.BranchAccessWrite &&
 		accessLevel != branching.BranchAccessAdmin {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "validation_error",
-			"message": "access_level must be one of: read, write, admin",
-		})
+		return SendErrorWithDetails(c, fiber.StatusBadRequest, "validation_error", ErrCodeValidationFailed, "access_level must be one of: read, write, admin", "", nil)
 	}
 
 	// Check authorization
@@ -962,16 +788,10 @@ func (h *BranchHandler) GrantBranchAccess(c fiber.Ctx) error {
 	if !isAdmin && grantedBy != nil {
 		hasAccess, err := h.manager.GetStorage().HasAccess(c.RequestCtx(), branch.ID, *grantedBy, branching.BranchAccessAdmin)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   "access_check_failed",
-				"message": "Failed to verify branch access",
-			})
+			return SendErrorWithDetails(c, fiber.StatusInternalServerError, "access_check_failed", ErrCodeOperationFailed, "Failed to verify branch access", "", nil)
 		}
 		if !hasAccess {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":   "access_denied",
-				"message": "You do not have permission to grant access to this branch",
-			})
+			return SendErrorWithDetails(c, fiber.StatusForbidden, "access_denied", ErrCodeAccessDenied, "You do not have permission to grant access to this branch", "", nil)
 		}
 	}
 
@@ -989,10 +809,7 @@ func (h *BranchHandler) GrantBranchAccess(c fiber.Ctx) error {
 			Str("branch_id", branch.ID.String()).
 			Str("user_id", targetUserID.String()).
 			Msg("Failed to grant branch access")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "grant_failed",
-			"message": "Failed to grant access",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "grant_failed", ErrCodeOperationFailed, "Failed to grant access", "", nil)
 	}
 
 	// Log activity
@@ -1014,10 +831,7 @@ func (h *BranchHandler) GrantBranchAccess(c fiber.Ctx) error {
 func (h *BranchHandler) RevokeBranchAccess(c fiber.Ctx) error {
 	// Nil check for manager (can happen in tests)
 	if h.manager == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "not_initialized",
-			"message": "Branch manager not initialized",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "not_initialized", "NOT_INITIALIZED", "Branch manager not initialized", "", nil)
 	}
 
 	idParam := c.Params("id")
@@ -1035,23 +849,14 @@ func (h *BranchHandler) RevokeBranchAccess(c fiber.Ctx) error {
 
 	if err != nil {
 		if errors.Is(err, branching.ErrBranchNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":   "branch_not_found",
-				"message": "Branch not found",
-			})
+			return SendErrorWithDetails(c, fiber.StatusNotFound, "branch_not_found", "BRANCH_NOT_FOUND", "Branch not found", "", nil)
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "get_failed",
-			"message": "Failed to get branch",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "get_failed", ErrCodeOperationFailed, "Failed to get branch", "", nil)
 	}
 
 	targetUserID, err := uuid.Parse(userIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "validation_error",
-			"message": "Invalid user_id format",
-		})
+		return SendErrorWithDetails(c, fiber.StatusBadRequest, "validation_error", ErrCodeValidationFailed, "Invalid user_id format", "", nil)
 	}
 
 	// Check authorization
@@ -1069,16 +874,10 @@ func (h *BranchHandler) RevokeBranchAccess(c fiber.Ctx) error {
 	if !isAdmin && currentUserID != nil {
 		hasAccess, err := h.manager.GetStorage().HasAccess(c.RequestCtx(), branch.ID, *currentUserID, branching.BranchAccessAdmin)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   "access_check_failed",
-				"message": "Failed to verify branch access",
-			})
+			return SendErrorWithDetails(c, fiber.StatusInternalServerError, "access_check_failed", ErrCodeOperationFailed, "Failed to verify branch access", "", nil)
 		}
 		if !hasAccess {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":   "access_denied",
-				"message": "You do not have permission to revoke access from this branch",
-			})
+			return SendErrorWithDetails(c, fiber.StatusForbidden, "access_denied", ErrCodeAccessDenied, "You do not have permission to revoke access from this branch", "", nil)
 		}
 	}
 
@@ -1088,10 +887,7 @@ func (h *BranchHandler) RevokeBranchAccess(c fiber.Ctx) error {
 			Str("branch_id", branch.ID.String()).
 			Str("user_id", targetUserID.String()).
 			Msg("Failed to revoke branch access")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "revoke_failed",
-			"message": "Failed to revoke access",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "revoke_failed", ErrCodeOperationFailed, "Failed to revoke access", "", nil)
 	}
 
 	// Log activity
@@ -177,18 +177,13 @@ func (h *CaptchaSettingsHandler) UpdateSettings(c fiber.Ctx) error {
 	var req UpdateCaptchaSettingsRequest
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse update captcha settings request")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate provider if provided
 	if req.Provider != nil {
 		if !validProviders[*req.Provider] {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid provider. Must be one of: hcaptcha, recaptcha_v3, turnstile, cap",
-				"code":  "INVALID_PROVIDER",
-			})
+			return SendBadRequest(c, "Invalid provider. Must be one of: hcaptcha, recaptcha_v3, turnstile, cap", "INVALID_PROVIDER")
 		}
 	}
 
@@ -196,10 +191,7 @@ func (h *CaptchaSettingsHandler) UpdateSettings(c fiber.Ctx) error {
 	if req.Endpoints != nil {
 		for _, endpoint := range *req.Endpoints {
 			if !validEndpoints[endpoint] {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": fmt.Sprintf("Invalid endpoint: %s. Must be one of: signup, login, password_reset, magic_link", endpoint),
-					"code":  "INVALID_ENDPOINT",
-				})
+				return SendBadRequest(c, fmt.Sprintf("Invalid endpoint: %s. Must be one of: signup, login, password_reset, magic_link", endpoint), "INVALID_ENDPOINT")
 			}
 		}
 	}
@@ -207,19 +199,13 @@ func (h *CaptchaSettingsHandler) UpdateSettings(c fiber.Ctx) error {
 	// Validate score threshold if provided
 	if req.ScoreThreshold != nil {
 		if *req.ScoreThreshold < 0.0 || *req.ScoreThreshold > 1.0 {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Score threshold must be between 0.0 and 1.0",
-				"code":  "INVALID_SCORE_THRESHOLD",
-			})
+			return SendBadRequest(c, "Score threshold must be between 0.0 and 1.0", "INVALID_SCORE_THRESHOLD")
 		}
 	}
 
 	// Nil check for dependencies (can happen in tests)
 	if h.settingsService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Settings service not initialized",
-			"code":  "NOT_INITIALIZED",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Settings service not initialized", "NOT_INITIALIZED")
 	}
 
 	// Track which settings were updated
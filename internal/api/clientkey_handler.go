@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -30,6 +31,10 @@ type CreateClientKeyRequest struct {
 	Scopes             []string   `json:"scopes"`
 	RateLimitPerMinute int        `json:"rate_limit_per_minute"`
 	ExpiresAt          *time.Time `json:"expires_at,omitempty"`
+	// JSONKeyCase is the key case of table rows for requests made with the key: "snake" (default) or "camel"
+	JSONKeyCase *string `json:"json_key_case,omitempty"`
+	// Tables, buckets and IP addresses the key is limited to (default: no restriction)
+	auth.ClientKeyRestrictions
 }
 
 // UpdateClientKeyRequest represents a request to update a client key
@@ -38,6 +43,75 @@ type UpdateClientKeyRequest struct {
 	Description        *string  `json:"description,omitempty"`
 	Scopes             []string `json:"scopes,omitempty"`
 	RateLimitPerMinute *int     `json:"rate_limit_per_minute,omitempty"`
+	JSONKeyCase        *string  `json:"json_key_case,omitempty"`
+	// Restrictions replace the key's current ones when set; an empty value removes them
+	AllowedTables  *map[string]string `json:"allowed_tables,omitempty"`
+	AllowedBuckets *[]string          `json:"allowed_buckets,omitempty"`
+	AllowedIPs     *[]string          `json:"allowed_ips,omitempty"`
+}
+
+// hasRestrictions reports whether the request changes any restriction of the key
+func (r *UpdateClientKeyRequest) hasRestrictions() bool {
+	return r.AllowedTables != nil || r.AllowedBuckets != nil || r.AllowedIPs != nil
+}
+
+// applyRestrictions returns current with the restrictions set by the request replaced
+func (r *UpdateClientKeyRequest) applyRestrictions(current auth.ClientKeyRestrictions) auth.ClientKeyRestrictions {
+	if r.AllowedTables != nil {
+		current.AllowedTables = *r.AllowedTables
+	}
+	if r.AllowedBuckets != nil {
+		current.AllowedBuckets = *r.AllowedBuckets
+	}
+	if r.AllowedIPs != nil {
+		current.AllowedIPs = *r.AllowedIPs
+	}
+	return current
+}
+
+// RotateClientKeyRequest represents a request to replace a client key with a new one
+type RotateClientKeyRequest struct {
+	// GracePeriod is how long the old key stays valid, in seconds (default 24 hours, max 30 days)
+	GracePeriod *int `json:"grace_period,omitempty"`
+}
+
+// gracePeriod returns the validated grace period of the request
+func (r *RotateClientKeyRequest) gracePeriod() (time.Duration, error) {
+	if r.GracePeriod == nil {
+		return auth.DefaultClientKeyRotationGrace, nil
+	}
+	grace := time.Duration(*r.GracePeriod) * time.Second
+	if grace < 0 || grace > auth.MaxClientKeyRotationGrace {
+		return 0, fmt.Errorf("grace_period must be between 0 and %d seconds", int(auth.MaxClientKeyRotationGrace.Seconds()))
+	}
+	return grace, nil
+}
+
+// validateJSONKeyCase checks the JSON key case of a client key request
+func validateJSONKeyCase(keyCase *string) error {
+	if keyCase != nil && *keyCase != auth.JSONKeyCaseSnake && *keyCase != auth.JSONKeyCaseCamel {
+		return auth.ErrInvalidJSONKeyCase
+	}
+	return nil
+}
+
+// CreateBootstrapTokenRequest represents a request to create a client key bootstrap token
+type CreateBootstrapTokenRequest struct {
+	Name               string   `json:"name"`
+	Description        *string  `json:"description,omitempty"`
+	Scopes             []string `json:"scopes"`
+	RateLimitPerMinute int      `json:"rate_limit_per_minute"`
+	// ExpiresIn is how long the token can be used, in seconds (default 1 hour, max 7 days)
+	ExpiresIn int `json:"expires_in,omitempty"`
+	// KeyExpiresIn is the lifetime of the enrolled client key, in seconds (default: no expiry)
+	KeyExpiresIn int `json:"key_expires_in,omitempty"`
+}
+
+// EnrollClientKeyRequest represents a request to exchange a bootstrap token for a client key
+type EnrollClientKeyRequest struct {
+	Token  string   `json:"token"`
+	Name   string   `json:"name,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
 }
 
 // RegisterRoutes registers client key routes with authentication
@@ -51,6 +125,11 @@ func (h *ClientKeyHandler) RegisterRoutes(app *fiber.App, authService *auth.Serv
 		middleware.RequireAdminIfClientKeysDisabled(settingsCache),
 	)
 
+	// Bootstrap tokens are admin-only, registered before /:id so they are not matched as a key ID
+	clientKeys.Get("/bootstrap-tokens", middleware.RequireAdmin(), middleware.RequireScope(auth.ScopeClientKeysRead), h.ListBootstrapTokens)
+	clientKeys.Post("/bootstrap-tokens", middleware.RequireAdmin(), middleware.RequireScope(auth.ScopeClientKeysWrite), h.CreateBootstrapToken)
+	clientKeys.Post("/bootstrap-tokens/:id/revoke", middleware.RequireAdmin(), middleware.RequireScope(auth.ScopeClientKeysWrite), h.RevokeBootstrapToken)
+
 	// Read operations require read:clientkeys scope
 	clientKeys.Get("/", middleware.RequireScope(auth.ScopeClientKeysRead), h.ListClientKeys)
 	clientKeys.Get("/:id", middleware.RequireScope(auth.ScopeClientKeysRead), h.GetClientKey)
@@ -60,21 +139,24 @@ func (h *ClientKeyHandler) RegisterRoutes(app *fiber.App, authService *auth.Serv
 	clientKeys.Patch("/:id", middleware.RequireScope(auth.ScopeClientKeysWrite), h.UpdateClientKey)
 	clientKeys.Delete("/:id", middleware.RequireScope(auth.ScopeClientKeysWrite), h.DeleteClientKey)
 	clientKeys.Post("/:id/revoke", middleware.RequireScope(auth.ScopeClientKeysWrite), h.RevokeClientKey)
+	clientKeys.Post("/:id/rotate", middleware.RequireScope(auth.ScopeClientKeysWrite), h.RotateClientKey)
 }
 
 // CreateClientKey creates a new client key
 func (h *ClientKeyHandler) CreateClientKey(c fiber.Ctx) error {
 	var req CreateClientKeyRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	if req.Name == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Name is required",
-		})
+		return SendBadRequest(c, "Name is required", ErrCodeMissingField)
+	}
+	if err := validateJSONKeyCase(req.JSONKeyCase); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+	if err := req.ClientKeyRestrictions.Validate(); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	// Get user ID from context (set by auth middleware)
@@ -94,10 +176,20 @@ func (h *ClientKeyHandler) CreateClientKey(c fiber.Ctx) error {
 		req.ExpiresAt,
 	)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to create client key: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to create client key: %v", err), ErrCodeOperationFailed)
+	}
+
+	if req.JSONKeyCase != nil && *req.JSONKeyCase != clientKey.JSONKeyCase {
+		if err := h.clientKeyService.SetClientKeyJSONKeyCase(c.RequestCtx(), clientKey.ID, *req.JSONKeyCase); err != nil {
+			return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to create client key: %v", err), ErrCodeOperationFailed)
+		}
+		clientKey.JSONKeyCase = *req.JSONKeyCase
+	}
+
+	if err := h.clientKeyService.SetClientKeyRestrictions(c.RequestCtx(), clientKey.ID, req.ClientKeyRestrictions); err != nil {
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to create client key: %v", err), ErrCodeOperationFailed)
 	}
+	clientKey.ClientKeyRestrictions = req.ClientKeyRestrictions
 
 	return c.Status(fiber.StatusCreated).JSON(clientKey)
 }
@@ -117,16 +209,12 @@ func (h *ClientKeyHandler) ListClientKeys(c fiber.Ctx) error {
 		// User specified a user_id filter
 		id, err := uuid.Parse(userIDStr)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid user ID",
-			})
+			return SendBadRequest(c, "Invalid user ID", ErrCodeInvalidID)
 		}
 
 		// Non-admin users can only view their own keys
 		if !isAdmin && userIDStr != currentUserID {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Cannot view other users' client keys",
-			})
+			return SendForbidden(c, "Cannot view other users' client keys", ErrCodeAccessDenied)
 		}
 		userID = &id
 	} else if !isAdmin && currentUserID != "" {
@@ -140,16 +228,12 @@ func (h *ClientKeyHandler) ListClientKeys(c fiber.Ctx) error {
 
 	// Nil check for service (can happen in tests)
 	if h.clientKeyService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Client key service not initialized",
-		})
+		return SendInternalError(c, "Client key service not initialized")
 	}
 
 	clientKeys, err := h.clientKeyService.ListClientKeys(c.RequestCtx(), userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to list client keys: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to list client keys: %v", err), ErrCodeOperationFailed)
 	}
 
 	return c.JSON(clientKeys)
@@ -162,16 +246,12 @@ func (h *ClientKeyHandler) GetClientKey(c fiber.Ctx) error {
 	idStr := c.Params("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid client key ID",
-		})
+		return SendBadRequest(c, "Invalid client key ID", ErrCodeInvalidID)
 	}
 
 	// Nil check for service (can happen in tests)
 	if h.clientKeyService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Client key service not initialized",
-		})
+		return SendInternalError(c, "Client key service not initialized")
 	}
 
 	// Get current user info
@@ -182,26 +262,20 @@ func (h *ClientKeyHandler) GetClientKey(c fiber.Ctx) error {
 	// For simplicity, we'll just list and filter (in production, add a GetByID method)
 	clientKeys, err := h.clientKeyService.ListClientKeys(c.RequestCtx(), nil)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to get client key: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to get client key: %v", err), ErrCodeOperationFailed)
 	}
 
 	for _, key := range clientKeys {
 		if key.ID == id {
 			// Non-admin users can only view their own keys
 			if !isAdmin && key.UserID != nil && key.UserID.String() != currentUserID {
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-					"error": "Cannot view other users' client keys",
-				})
+				return SendForbidden(c, "Cannot view other users' client keys", ErrCodeAccessDenied)
 			}
 			return c.JSON(key)
 		}
 	}
 
-	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-		"error": "Client key not found",
-	})
+	return SendNotFound(c, "Client key not found")
 }
 
 // UpdateClientKey updates a client key's metadata
@@ -210,31 +284,38 @@ func (h *ClientKeyHandler) UpdateClientKey(c fiber.Ctx) error {
 	idStr := c.Params("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid client key ID",
-		})
+		return SendBadRequest(c, "Invalid client key ID", ErrCodeInvalidID)
 	}
 
 	// Parse request body before checking service
 	var req UpdateClientKeyRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
+	}
+	if err := validateJSONKeyCase(req.JSONKeyCase); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+	if err := req.applyRestrictions(auth.ClientKeyRestrictions{}).Validate(); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	// Nil check for service (can happen in tests)
 	if h.clientKeyService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Client key service not initialized",
-		})
+		return SendInternalError(c, "Client key service not initialized")
 	}
 
 	err = h.clientKeyService.UpdateClientKey(c.RequestCtx(), id, req.Name, req.Description, req.Scopes, req.RateLimitPerMinute)
+	if err == nil && req.JSONKeyCase != nil {
+		err = h.clientKeyService.SetClientKeyJSONKeyCase(c.RequestCtx(), id, *req.JSONKeyCase)
+	}
+	if err == nil && req.hasRestrictions() {
+		var key *auth.ClientKey
+		if key, err = h.clientKeyService.GetClientKey(c.RequestCtx(), id); err == nil {
+			err = h.clientKeyService.SetClientKeyRestrictions(c.RequestCtx(), id, req.applyRestrictions(key.ClientKeyRestrictions))
+		}
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to update client key: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to update client key: %v", err), ErrCodeOperationFailed)
 	}
 
 	return c.JSON(fiber.Map{
@@ -249,23 +330,17 @@ func (h *ClientKeyHandler) RevokeClientKey(c fiber.Ctx) error {
 	idStr := c.Params("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid client key ID",
-		})
+		return SendBadRequest(c, "Invalid client key ID", ErrCodeInvalidID)
 	}
 
 	// Nil check for service (can happen in tests)
 	if h.clientKeyService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Client key service not initialized",
-		})
+		return SendInternalError(c, "Client key service not initialized")
 	}
 
 	err = h.clientKeyService.RevokeClientKey(c.RequestCtx(), id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to revoke client key: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to revoke client key: %v", err), ErrCodeOperationFailed)
 	}
 
 	return c.JSON(fiber.Map{
@@ -274,32 +349,188 @@ func (h *ClientKeyHandler) RevokeClientKey(c fiber.Ctx) error {
 	})
 }
 
+// RotateClientKey replaces a client key with a new key with the same settings. The old key
+// stays valid for the grace period so clients can switch over; the new key is returned once.
+// Non-admin users can only rotate their own keys.
+func (h *ClientKeyHandler) RotateClientKey(c fiber.Ctx) error {
+	// Validate ID format first
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return SendBadRequest(c, "Invalid client key ID", ErrCodeInvalidID)
+	}
+
+	var req RotateClientKeyRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&req); err != nil {
+			return SendInvalidBody(c)
+		}
+	}
+	grace, err := req.gracePeriod()
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	// Nil check for service (can happen in tests)
+	if h.clientKeyService == nil {
+		return SendInternalError(c, "Client key service not initialized")
+	}
+
+	key, err := h.clientKeyService.GetClientKey(c.RequestCtx(), id)
+	if errors.Is(err, auth.ErrClientKeyNotFound) {
+		return SendNotFound(c, "Client key not found")
+	}
+	if err != nil {
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to rotate client key: %v", err), ErrCodeOperationFailed)
+	}
+	role, _ := c.Locals("user_role").(string)
+	isAdmin := role == "admin" || role == "dashboard_admin" || role == "service_role"
+	if !isAdmin && (key.UserID == nil || key.UserID.String() != fmt.Sprint(c.Locals("user_id"))) {
+		return SendForbidden(c, "Cannot rotate other users' client keys", ErrCodeAccessDenied)
+	}
+
+	clientKey, err := h.clientKeyService.RotateClientKey(c.RequestCtx(), id, grace)
+	switch {
+	case errors.Is(err, auth.ErrClientKeyNotFound):
+		return SendNotFound(c, "Client key not found")
+	case errors.Is(err, auth.ErrClientKeyRevoked), errors.Is(err, auth.ErrClientKeyExpired), errors.Is(err, auth.ErrClientKeyRotated):
+		return SendErrorWithCode(c, fiber.StatusConflict, fmt.Sprintf("Cannot rotate client key: %v", err), ErrCodeConflict)
+	case err != nil:
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to rotate client key: %v", err), ErrCodeOperationFailed)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(clientKey)
+}
+
 // DeleteClientKey permanently deletes a client key
 func (h *ClientKeyHandler) DeleteClientKey(c fiber.Ctx) error {
 	// Validate ID format first
 	idStr := c.Params("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid client key ID",
-		})
+		return SendBadRequest(c, "Invalid client key ID", ErrCodeInvalidID)
 	}
 
 	// Nil check for service (can happen in tests)
 	if h.clientKeyService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Client key service not initialized",
-		})
+		return SendInternalError(c, "Client key service not initialized")
 	}
 
 	err = h.clientKeyService.DeleteClientKey(c.RequestCtx(), id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to delete client key: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to delete client key: %v", err), ErrCodeOperationFailed)
 	}
 
 	return c.Status(fiber.StatusNoContent).Send(nil)
 }
 
+// CreateBootstrapToken creates a one-time token that a new service can exchange for a client key
+func (h *ClientKeyHandler) CreateBootstrapToken(c fiber.Ctx) error {
+	var req CreateBootstrapTokenRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+
+	opts := auth.BootstrapTokenOptions{
+		Name:               req.Name,
+		Description:        req.Description,
+		Scopes:             req.Scopes,
+		RateLimitPerMinute: req.RateLimitPerMinute,
+		TTL:                time.Duration(req.ExpiresIn) * time.Second,
+		KeyTTL:             time.Duration(req.KeyExpiresIn) * time.Second,
+	}
+	if err := opts.Validate(); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	// Nil check for service (can happen in tests)
+	if h.clientKeyService == nil {
+		return SendInternalError(c, "Client key service not initialized")
+	}
+
+	// user_id is a UUID for client key auth and a string for JWT auth
+	switch userID := c.Locals("user_id").(type) {
+	case uuid.UUID:
+		opts.CreatedBy = &userID
+	case string:
+		if id, err := uuid.Parse(userID); err == nil {
+			opts.CreatedBy = &id
+		}
+	}
+
+	token, err := h.clientKeyService.CreateBootstrapToken(c.RequestCtx(), opts)
+	if err != nil {
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to create bootstrap token: %v", err), ErrCodeOperationFailed)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(token)
+}
+
+// ListBootstrapTokens lists bootstrap tokens and whether they have been used
+func (h *ClientKeyHandler) ListBootstrapTokens(c fiber.Ctx) error {
+	// Nil check for service (can happen in tests)
+	if h.clientKeyService == nil {
+		return SendInternalError(c, "Client key service not initialized")
+	}
+
+	tokens, err := h.clientKeyService.ListBootstrapTokens(c.RequestCtx())
+	if err != nil {
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to list bootstrap tokens: %v", err), ErrCodeOperationFailed)
+	}
+
+	return c.JSON(tokens)
+}
+
+// RevokeBootstrapToken revokes an unused bootstrap token
+func (h *ClientKeyHandler) RevokeBootstrapToken(c fiber.Ctx) error {
+	// Validate ID format first
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return SendBadRequest(c, "Invalid bootstrap token ID", ErrCodeInvalidID)
+	}
+
+	// Nil check for service (can happen in tests)
+	if h.clientKeyService == nil {
+		return SendInternalError(c, "Client key service not initialized")
+	}
+
+	if err := h.clientKeyService.RevokeBootstrapToken(c.RequestCtx(), id); err != nil {
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to revoke bootstrap token: %v", err), ErrCodeOperationFailed)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Bootstrap token revoked successfully",
+	})
+}
+
+// EnrollClientKey exchanges a bootstrap token for a client key
+// This endpoint is unauthenticated: the bootstrap token is the credential
+func (h *ClientKeyHandler) EnrollClientKey(c fiber.Ctx) error {
+	var req EnrollClientKeyRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+
+	if req.Token == "" {
+		return SendBadRequest(c, "Token is required", ErrCodeMissingField)
+	}
+
+	// Nil check for service (can happen in tests)
+	if h.clientKeyService == nil {
+		return SendInternalError(c, "Client key service not initialized")
+	}
+
+	clientKey, err := h.clientKeyService.EnrollClientKey(c.RequestCtx(), req.Token, req.Name, req.Scopes, c.IP())
+	switch {
+	case errors.Is(err, auth.ErrInvalidBootstrapToken):
+		return SendUnauthorized(c, err.Error(), ErrCodeAuthRequired)
+	case errors.Is(err, auth.ErrBootstrapScopesExceeded):
+		return SendForbidden(c, err.Error(), ErrCodeAccessDenied)
+	case err != nil:
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to enroll client key: %v", err), ErrCodeOperationFailed)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(clientKey)
+}
+
 // fiber:context-methods migrated
@@ -11,6 +11,7 @@ import (
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/auth"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -276,6 +277,27 @@ func TestCreateClientKey_Validation(t *testing.T) {
 		assert.Contains(t, result["error"], "Name is required")
 	})
 
+	t.Run("invalid json key case", func(t *testing.T) {
+		app := fiber.New()
+		handler := NewClientKeyHandler(nil)
+
+		app.Post("/client-keys", handler.CreateClientKey)
+
+		body := `{"name": "Test Key", "scopes": ["read:*"], "json_key_case": "kebab"}`
+		req := httptest.NewRequest(http.MethodPost, "/client-keys", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+		var result map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.Contains(t, result["error"], "json_key_case")
+	})
+
 	t.Run("valid body but nil service", func(t *testing.T) {
 		app := fiber.New()
 		handler := NewClientKeyHandler(nil)
@@ -598,6 +620,23 @@ func TestUpdateClientKey_Validation(t *testing.T) {
 		assert.Contains(t, result["error"], "Invalid request body")
 	})
 
+	t.Run("invalid json key case", func(t *testing.T) {
+		app := fiber.New()
+		handler := NewClientKeyHandler(nil)
+
+		app.Patch("/client-keys/:id", handler.UpdateClientKey)
+
+		keyID := uuid.New().String()
+		req := httptest.NewRequest(http.MethodPatch, "/client-keys/"+keyID, bytes.NewReader([]byte(`{"json_key_case": "Camel"}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+
 	t.Run("valid request", func(t *testing.T) {
 		app := fiber.New()
 		handler := NewClientKeyHandler(nil)
@@ -665,6 +704,92 @@ func TestRevokeClientKey_Validation(t *testing.T) {
 	})
 }
 
+// =============================================================================
+// Client Key Restriction and Rotation Tests
+// =============================================================================
+
+func TestClientKeyRestrictions_Validation(t *testing.T) {
+	send := func(method, path, body string, h fiber.Handler) (int, string) {
+		app := fiber.New()
+		app.Add([]string{method}, "/client-keys", h)
+		app.Add([]string{method}, "/client-keys/:id", h)
+
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		respBody, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(respBody)
+	}
+	handler := NewClientKeyHandler(nil)
+
+	t.Run("create with an invalid IP range", func(t *testing.T) {
+		status, body := send(http.MethodPost, "/client-keys", `{"name": "ci", "scopes": ["read:tables"], "allowed_ips": ["10.0.0.0/33"]}`, handler.CreateClientKey)
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		assert.Contains(t, body, "invalid allowed IP")
+	})
+
+	t.Run("update with an invalid table access", func(t *testing.T) {
+		status, body := send(http.MethodPatch, "/client-keys/"+uuid.New().String(), `{"allowed_tables": {"public.posts": "admin"}}`, handler.UpdateClientKey)
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		assert.Contains(t, body, "invalid access")
+	})
+
+	t.Run("update replaces only the restrictions it sets", func(t *testing.T) {
+		buckets := []string{}
+		req := UpdateClientKeyRequest{AllowedBuckets: &buckets}
+		assert.True(t, req.hasRestrictions())
+
+		current := auth.ClientKeyRestrictions{
+			AllowedTables:  map[string]string{"posts": auth.TableAccessRead},
+			AllowedBuckets: []string{"avatars"},
+		}
+		updated := req.applyRestrictions(current)
+		assert.Equal(t, current.AllowedTables, updated.AllowedTables)
+		assert.Empty(t, updated.AllowedBuckets)
+		assert.False(t, (&UpdateClientKeyRequest{}).hasRestrictions())
+	})
+}
+
+func TestRotateClientKey_Validation(t *testing.T) {
+	rotate := func(path, body string) int {
+		app := fiber.New()
+		handler := NewClientKeyHandler(nil)
+		app.Post("/client-keys/:id/rotate", handler.RotateClientKey)
+
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader([]byte(body)))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		return resp.StatusCode
+	}
+	path := "/client-keys/" + uuid.New().String() + "/rotate"
+
+	assert.Equal(t, fiber.StatusBadRequest, rotate("/client-keys/invalid-uuid/rotate", ""))
+	assert.Equal(t, fiber.StatusBadRequest, rotate(path, `{"grace_period": -1}`))
+	assert.Equal(t, fiber.StatusBadRequest, rotate(path, `{"grace_period": 2592001}`))
+	// Valid requests fail at the missing service
+	assert.Equal(t, fiber.StatusInternalServerError, rotate(path, ""))
+	assert.Equal(t, fiber.StatusInternalServerError, rotate(path, `{"grace_period": 0}`))
+}
+
+func TestRotateClientKeyRequest_GracePeriod(t *testing.T) {
+	grace, err := (&RotateClientKeyRequest{}).gracePeriod()
+	require.NoError(t, err)
+	assert.Equal(t, auth.DefaultClientKeyRotationGrace, grace)
+
+	seconds := 3600
+	grace, err = (&RotateClientKeyRequest{GracePeriod: &seconds}).gracePeriod()
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, grace)
+}
+
 // =============================================================================
 // DeleteClientKey Handler Tests
 // =============================================================================
@@ -855,3 +980,98 @@ func TestAdminRoleVerification(t *testing.T) {
 		}
 	})
 }
+
+// =============================================================================
+// Bootstrap Token Handler Tests
+// =============================================================================
+
+func TestCreateBootstrapToken_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "invalid body", body: `not json`, want: "Invalid request body"},
+		{name: "missing name", body: `{"scopes":["read:tables"]}`, want: "name is required"},
+		{name: "missing scopes", body: `{"name":"worker"}`, want: "invalid scopes"},
+		{name: "expiry too short", body: `{"name":"worker","scopes":["read:tables"],"expires_in":30}`, want: "token expiry must be between"},
+		{name: "expiry too long", body: `{"name":"worker","scopes":["read:tables"],"expires_in":1209600}`, want: "token expiry must be between"},
+		{name: "negative key expiry", body: `{"name":"worker","scopes":["read:tables"],"key_expires_in":-1}`, want: "key expiry cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			handler := NewClientKeyHandler(nil)
+
+			app.Post("/client-keys/bootstrap-tokens", handler.CreateBootstrapToken)
+
+			req := httptest.NewRequest(http.MethodPost, "/client-keys/bootstrap-tokens", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+			var result map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+			assert.Contains(t, result["error"], tt.want)
+		})
+	}
+
+	t.Run("valid request reaches service", func(t *testing.T) {
+		app := fiber.New()
+		handler := NewClientKeyHandler(nil)
+
+		app.Post("/client-keys/bootstrap-tokens", handler.CreateBootstrapToken)
+
+		req := httptest.NewRequest(http.MethodPost, "/client-keys/bootstrap-tokens", bytes.NewReader([]byte(`{"name":"worker","scopes":["read:tables"],"expires_in":600}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		// Request valid, fails at service call
+		assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+func TestRevokeBootstrapToken_Validation(t *testing.T) {
+	app := fiber.New()
+	handler := NewClientKeyHandler(nil)
+
+	app.Post("/client-keys/bootstrap-tokens/:id/revoke", handler.RevokeBootstrapToken)
+
+	req := httptest.NewRequest(http.MethodPost, "/client-keys/bootstrap-tokens/invalid-uuid/revoke", nil)
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestEnrollClientKey_Validation(t *testing.T) {
+	t.Run("missing token", func(t *testing.T) {
+		app := fiber.New()
+		handler := NewClientKeyHandler(nil)
+
+		app.Post("/client-keys/enroll", handler.EnrollClientKey)
+
+		req := httptest.NewRequest(http.MethodPost, "/client-keys/enroll", bytes.NewReader([]byte(`{"name":"worker-1"}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+		var result map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.Equal(t, "Token is required", result["error"])
+	})
+}
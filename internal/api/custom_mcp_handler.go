@@ -107,9 +107,7 @@ func (h *CustomMCPHandler) ListTools(c fiber.Ctx) error {
 
 	tools, err := h.storage.ListTools(c.RequestCtx(), filter)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to list custom tools: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to list custom tools: %v", err), ErrCodeOperationFailed)
 	}
 
 	return c.JSON(fiber.Map{
@@ -122,28 +120,20 @@ func (h *CustomMCPHandler) ListTools(c fiber.Ctx) error {
 func (h *CustomMCPHandler) GetTool(c fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid tool ID",
-		})
+		return SendBadRequest(c, "Invalid tool ID", ErrCodeInvalidID)
 	}
 
 	// Nil check for storage (can happen in tests)
 	if h.storage == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Storage not initialized",
-		})
+		return SendInternalError(c, "Storage not initialized")
 	}
 
 	tool, err := h.storage.GetTool(c.RequestCtx(), id)
 	if err != nil {
 		if errors.Is(err, custom.ErrToolNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Tool not found",
-			})
+			return SendNotFound(c, "Tool not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to get tool: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to get tool: %v", err), ErrCodeOperationFailed)
 	}
 
 	return c.JSON(tool)
@@ -153,28 +143,20 @@ func (h *CustomMCPHandler) GetTool(c fiber.Ctx) error {
 func (h *CustomMCPHandler) CreateTool(c fiber.Ctx) error {
 	var req custom.CreateToolRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate required fields
 	if req.Name == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Name is required",
-		})
+		return SendBadRequest(c, "Name is required", ErrCodeMissingField)
 	}
 	if req.Code == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Code is required",
-		})
+		return SendBadRequest(c, "Code is required", ErrCodeMissingField)
 	}
 
 	// Validate code
 	if err := custom.ValidateToolCode(req.Code); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": fmt.Sprintf("Invalid tool code: %v", err),
-		})
+		return SendBadRequest(c, fmt.Sprintf("Invalid tool code: %v", err), ErrCodeInvalidInput)
 	}
 
 	// Get user ID from context
@@ -186,13 +168,9 @@ func (h *CustomMCPHandler) CreateTool(c fiber.Ctx) error {
 	tool, err := h.storage.CreateTool(c.RequestCtx(), &req, createdBy)
 	if err != nil {
 		if errors.Is(err, custom.ErrToolAlreadyExists) {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error": "A tool with this name already exists in the namespace",
-			})
+			return SendConflict(c, "A tool with this name already exists in the namespace", ErrCodeAlreadyExists)
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to create tool: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to create tool: %v", err), ErrCodeOperationFailed)
 	}
 
 	// Register with MCP server
@@ -210,42 +188,30 @@ func (h *CustomMCPHandler) CreateTool(c fiber.Ctx) error {
 func (h *CustomMCPHandler) UpdateTool(c fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid tool ID",
-		})
+		return SendBadRequest(c, "Invalid tool ID", ErrCodeInvalidID)
 	}
 
 	var req custom.UpdateToolRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate code if provided
 	if req.Code != nil {
 		if err := custom.ValidateToolCode(*req.Code); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": fmt.Sprintf("Invalid tool code: %v", err),
-			})
+			return SendBadRequest(c, fmt.Sprintf("Invalid tool code: %v", err), ErrCodeInvalidInput)
 		}
 	}
 
 	tool, err := h.storage.UpdateTool(c.RequestCtx(), id, &req)
 	if err != nil {
 		if errors.Is(err, custom.ErrToolNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Tool not found",
-			})
+			return SendNotFound(c, "Tool not found")
 		}
 		if errors.Is(err, custom.ErrToolAlreadyExists) {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error": "A tool with this name already exists in the namespace",
-			})
+			return SendConflict(c, "A tool with this name already exists in the namespace", ErrCodeAlreadyExists)
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to update tool: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to update tool: %v", err), ErrCodeOperationFailed)
 	}
 
 	// Re-register with MCP server
@@ -264,28 +230,20 @@ func (h *CustomMCPHandler) UpdateTool(c fiber.Ctx) error {
 func (h *CustomMCPHandler) DeleteTool(c fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid tool ID",
-		})
+		return SendBadRequest(c, "Invalid tool ID", ErrCodeInvalidID)
 	}
 
 	// Get tool first to get name for unregistering
 	tool, err := h.storage.GetTool(c.RequestCtx(), id)
 	if err != nil {
 		if errors.Is(err, custom.ErrToolNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Tool not found",
-			})
+			return SendNotFound(c, "Tool not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to get tool: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to get tool: %v", err), ErrCodeOperationFailed)
 	}
 
 	if err := h.storage.DeleteTool(c.RequestCtx(), id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to delete tool: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to delete tool: %v", err), ErrCodeOperationFailed)
 	}
 
 	// Unregister from MCP server
@@ -300,28 +258,20 @@ func (h *CustomMCPHandler) DeleteTool(c fiber.Ctx) error {
 func (h *CustomMCPHandler) SyncTool(c fiber.Ctx) error {
 	var req custom.SyncToolRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate required fields
 	if req.Name == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Name is required",
-		})
+		return SendBadRequest(c, "Name is required", ErrCodeMissingField)
 	}
 	if req.Code == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Code is required",
-		})
+		return SendBadRequest(c, "Code is required", ErrCodeMissingField)
 	}
 
 	// Validate code
 	if err := custom.ValidateToolCode(req.Code); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": fmt.Sprintf("Invalid tool code: %v", err),
-		})
+		return SendBadRequest(c, fmt.Sprintf("Invalid tool code: %v", err), ErrCodeInvalidInput)
 	}
 
 	// Default upsert to true for sync operation
@@ -335,9 +285,7 @@ func (h *CustomMCPHandler) SyncTool(c fiber.Ctx) error {
 
 	tool, err := h.storage.SyncTool(c.RequestCtx(), &req, createdBy)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to sync tool: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to sync tool: %v", err), ErrCodeOperationFailed)
 	}
 
 	// Register with MCP server
@@ -352,36 +300,26 @@ func (h *CustomMCPHandler) SyncTool(c fiber.Ctx) error {
 func (h *CustomMCPHandler) TestTool(c fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid tool ID",
-		})
+		return SendBadRequest(c, "Invalid tool ID", ErrCodeInvalidID)
 	}
 
 	var req struct {
 		Args map[string]any `json:"args"`
 	}
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	tool, err := h.storage.GetTool(c.RequestCtx(), id)
 	if err != nil {
 		if errors.Is(err, custom.ErrToolNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Tool not found",
-			})
+			return SendNotFound(c, "Tool not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to get tool: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to get tool: %v", err), ErrCodeOperationFailed)
 	}
 
 	if h.manager == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "MCP manager not initialized",
-		})
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "MCP manager not initialized", ErrCodeServiceUnavailable)
 	}
 
 	// Execute the tool (manager has the executor)
@@ -390,6 +328,7 @@ func (h *CustomMCPHandler) TestTool(c fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":  fmt.Sprintf("Tool execution failed: %v", err),
+			"code":   ErrCodeOperationFailed,
 			"result": result,
 		})
 	}
@@ -418,9 +357,7 @@ func (h *CustomMCPHandler) ListResources(c fiber.Ctx) error {
 
 	resources, err := h.storage.ListResources(c.RequestCtx(), filter)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to list custom resources: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to list custom resources: %v", err), ErrCodeOperationFailed)
 	}
 
 	return c.JSON(fiber.Map{
@@ -433,21 +370,15 @@ func (h *CustomMCPHandler) ListResources(c fiber.Ctx) error {
 func (h *CustomMCPHandler) GetResource(c fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid resource ID",
-		})
+		return SendBadRequest(c, "Invalid resource ID", ErrCodeInvalidID)
 	}
 
 	resource, err := h.storage.GetResource(c.RequestCtx(), id)
 	if err != nil {
 		if errors.Is(err, custom.ErrResourceNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Resource not found",
-			})
+			return SendNotFound(c, "Resource not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to get resource: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to get resource: %v", err), ErrCodeOperationFailed)
 	}
 
 	return c.JSON(resource)
@@ -457,33 +388,23 @@ func (h *CustomMCPHandler) GetResource(c fiber.Ctx) error {
 func (h *CustomMCPHandler) CreateResource(c fiber.Ctx) error {
 	var req custom.CreateResourceRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate required fields
 	if req.URI == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "URI is required",
-		})
+		return SendBadRequest(c, "URI is required", ErrCodeMissingField)
 	}
 	if req.Name == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Name is required",
-		})
+		return SendBadRequest(c, "Name is required", ErrCodeMissingField)
 	}
 	if req.Code == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Code is required",
-		})
+		return SendBadRequest(c, "Code is required", ErrCodeMissingField)
 	}
 
 	// Validate code
 	if err := custom.ValidateResourceCode(req.Code); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": fmt.Sprintf("Invalid resource code: %v", err),
-		})
+		return SendBadRequest(c, fmt.Sprintf("Invalid resource code: %v", err), ErrCodeInvalidInput)
 	}
 
 	// Get user ID from context
@@ -495,13 +416,9 @@ func (h *CustomMCPHandler) CreateResource(c fiber.Ctx) error {
 	resource, err := h.storage.CreateResource(c.RequestCtx(), &req, createdBy)
 	if err != nil {
 		if errors.Is(err, custom.ErrResourceExists) {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error": "A resource with this URI already exists in the namespace",
-			})
+			return SendConflict(c, "A resource with this URI already exists in the namespace", ErrCodeAlreadyExists)
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to create resource: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to create resource: %v", err), ErrCodeOperationFailed)
 	}
 
 	// Register with MCP server
@@ -518,42 +435,30 @@ func (h *CustomMCPHandler) CreateResource(c fiber.Ctx) error {
 func (h *CustomMCPHandler) UpdateResource(c fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid resource ID",
-		})
+		return SendBadRequest(c, "Invalid resource ID", ErrCodeInvalidID)
 	}
 
 	var req custom.UpdateResourceRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate code if provided
 	if req.Code != nil {
 		if err := custom.ValidateResourceCode(*req.Code); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": fmt.Sprintf("Invalid resource code: %v", err),
-			})
+			return SendBadRequest(c, fmt.Sprintf("Invalid resource code: %v", err), ErrCodeInvalidInput)
 		}
 	}
 
 	resource, err := h.storage.UpdateResource(c.RequestCtx(), id, &req)
 	if err != nil {
 		if errors.Is(err, custom.ErrResourceNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Resource not found",
-			})
+			return SendNotFound(c, "Resource not found")
 		}
 		if errors.Is(err, custom.ErrResourceExists) {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error": "A resource with this URI already exists in the namespace",
-			})
+			return SendConflict(c, "A resource with this URI already exists in the namespace", ErrCodeAlreadyExists)
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to update resource: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to update resource: %v", err), ErrCodeOperationFailed)
 	}
 
 	// Re-register with MCP server
@@ -572,28 +477,20 @@ func (h *CustomMCPHandler) UpdateResource(c fiber.Ctx) error {
 func (h *CustomMCPHandler) DeleteResource(c fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid resource ID",
-		})
+		return SendBadRequest(c, "Invalid resource ID", ErrCodeInvalidID)
 	}
 
 	// Get resource first to get URI for unregistering
 	resource, err := h.storage.GetResource(c.RequestCtx(), id)
 	if err != nil {
 		if errors.Is(err, custom.ErrResourceNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Resource not found",
-			})
+			return SendNotFound(c, "Resource not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to get resource: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to get resource: %v", err), ErrCodeOperationFailed)
 	}
 
 	if err := h.storage.DeleteResource(c.RequestCtx(), id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to delete resource: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to delete resource: %v", err), ErrCodeOperationFailed)
 	}
 
 	// Unregister from MCP server
@@ -608,33 +505,23 @@ func (h *CustomMCPHandler) DeleteResource(c fiber.Ctx) error {
 func (h *CustomMCPHandler) SyncResource(c fiber.Ctx) error {
 	var req custom.SyncResourceRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate required fields
 	if req.URI == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "URI is required",
-		})
+		return SendBadRequest(c, "URI is required", ErrCodeMissingField)
 	}
 	if req.Name == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Name is required",
-		})
+		return SendBadRequest(c, "Name is required", ErrCodeMissingField)
 	}
 	if req.Code == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Code is required",
-		})
+		return SendBadRequest(c, "Code is required", ErrCodeMissingField)
 	}
 
 	// Validate code
 	if err := custom.ValidateResourceCode(req.Code); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": fmt.Sprintf("Invalid resource code: %v", err),
-		})
+		return SendBadRequest(c, fmt.Sprintf("Invalid resource code: %v", err), ErrCodeInvalidInput)
 	}
 
 	// Default upsert to true for sync operation
@@ -648,9 +535,7 @@ func (h *CustomMCPHandler) SyncResource(c fiber.Ctx) error {
 
 	resource, err := h.storage.SyncResource(c.RequestCtx(), &req, createdBy)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to sync resource: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to sync resource: %v", err), ErrCodeOperationFailed)
 	}
 
 	// Register with MCP server
@@ -665,44 +550,32 @@ func (h *CustomMCPHandler) SyncResource(c fiber.Ctx) error {
 func (h *CustomMCPHandler) TestResource(c fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid resource ID",
-		})
+		return SendBadRequest(c, "Invalid resource ID", ErrCodeInvalidID)
 	}
 
 	var req struct {
 		Params map[string]string `json:"params"`
 	}
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	resource, err := h.storage.GetResource(c.RequestCtx(), id)
 	if err != nil {
 		if errors.Is(err, custom.ErrResourceNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Resource not found",
-			})
+			return SendNotFound(c, "Resource not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to get resource: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to get resource: %v", err), ErrCodeOperationFailed)
 	}
 
 	if h.manager == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "MCP manager not initialized",
-		})
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "MCP manager not initialized", ErrCodeServiceUnavailable)
 	}
 
 	// Execute the resource
 	contents, err := h.manager.ExecuteResourceForTest(c.RequestCtx(), resource, req.Params)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Resource read failed: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Resource read failed: %v", err), ErrCodeOperationFailed)
 	}
 
 	return c.JSON(fiber.Map{
@@ -32,38 +32,28 @@ func (h *CustomSettingsHandler) CreateSetting(c fiber.Ctx) error {
 	userRole := c.Locals("user_role")
 
 	if userIDStr == nil || userRole == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 
 	userID, err := uuid.Parse(userIDStr.(string))
 	if err != nil {
 		log.Error().Err(err).Msg("Invalid user ID in context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return SendInternalError(c, "Invalid user ID")
 	}
 
 	var req settings.CreateCustomSettingRequest
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse request body")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate required fields
 	if req.Key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting key is required",
-		})
+		return SendBadRequest(c, "Setting key is required", ErrCodeMissingField)
 	}
 
 	if req.Value == nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting value is required",
-		})
+		return SendBadRequest(c, "Setting value is required", ErrCodeMissingField)
 	}
 
 	// If is_secret is true, encrypt the value using the secret settings path
@@ -71,9 +61,7 @@ func (h *CustomSettingsHandler) CreateSetting(c fiber.Ctx) error {
 		// Extract string value from the map for encryption
 		valueStr := extractStringValueFromMap(req.Value)
 		if valueStr == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Secret value must be a non-empty string (use {\"value\": \"your-secret\"})",
-			})
+			return SendBadRequest(c, "Secret value must be a non-empty string (use {\"value\": \"your-secret\"})", ErrCodeInvalidInput)
 		}
 
 		secretReq := settings.CreateSecretSettingRequest{
@@ -86,21 +74,13 @@ func (h *CustomSettingsHandler) CreateSetting(c fiber.Ctx) error {
 		metadata, err := h.settingsService.CreateSecretSetting(ctx, secretReq, nil, userID)
 		if err != nil {
 			if errors.Is(err, settings.ErrCustomSettingDuplicate) {
-				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-					"error": "A setting with this key already exists",
-					"code":  "DUPLICATE_KEY",
-				})
+				return SendConflict(c, "A setting with this key already exists", "DUPLICATE_KEY")
 			}
 			if errors.Is(err, settings.ErrCustomSettingInvalidKey) {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": "Invalid setting key format",
-					"code":  "INVALID_KEY",
-				})
+				return SendBadRequest(c, "Invalid setting key format", "INVALID_KEY")
 			}
 			log.Error().Err(err).Str("key", req.Key).Msg("Failed to create secret setting")
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to create setting",
-			})
+			return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to create setting", ErrCodeOperationFailed)
 		}
 
 		log.Info().
@@ -116,21 +96,13 @@ func (h *CustomSettingsHandler) CreateSetting(c fiber.Ctx) error {
 	setting, err := h.settingsService.CreateSetting(ctx, req, userID)
 	if err != nil {
 		if errors.Is(err, settings.ErrCustomSettingDuplicate) {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error": "A setting with this key already exists",
-				"code":  "DUPLICATE_KEY",
-			})
+			return SendConflict(c, "A setting with this key already exists", "DUPLICATE_KEY")
 		}
 		if errors.Is(err, settings.ErrCustomSettingInvalidKey) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid setting key format",
-				"code":  "INVALID_KEY",
-			})
+			return SendBadRequest(c, "Invalid setting key format", "INVALID_KEY")
 		}
 		log.Error().Err(err).Str("key", req.Key).Msg("Failed to create custom setting")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create setting",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to create setting", ErrCodeOperationFailed)
 	}
 
 	log.Info().
@@ -150,17 +122,13 @@ func (h *CustomSettingsHandler) ListSettings(c fiber.Ctx) error {
 	// Get user role from context
 	userRole := c.Locals("user_role")
 	if userRole == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 
 	settings, err := h.settingsService.ListSettings(ctx, userRole.(string))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list custom settings")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve custom settings",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve custom settings", ErrCodeOperationFailed)
 	}
 
 	return c.JSON(settings)
@@ -173,22 +141,16 @@ func (h *CustomSettingsHandler) GetSetting(c fiber.Ctx) error {
 	key := c.Params("*")
 
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting key is required",
-		})
+		return SendBadRequest(c, "Setting key is required", ErrCodeMissingField)
 	}
 
 	setting, err := h.settingsService.GetSetting(ctx, key)
 	if err != nil {
 		if errors.Is(err, settings.ErrCustomSettingNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Setting not found",
-			})
+			return SendNotFound(c, "Setting not found")
 		}
 		log.Error().Err(err).Str("key", key).Msg("Failed to get custom setting")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve setting",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve setting", ErrCodeOperationFailed)
 	}
 
 	return c.JSON(setting)
@@ -201,9 +163,7 @@ func (h *CustomSettingsHandler) UpdateSetting(c fiber.Ctx) error {
 	key := c.Params("*")
 
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting key is required",
-		})
+		return SendBadRequest(c, "Setting key is required", ErrCodeMissingField)
 	}
 
 	// Get user ID and role from context
@@ -211,51 +171,36 @@ func (h *CustomSettingsHandler) UpdateSetting(c fiber.Ctx) error {
 	userRole := c.Locals("user_role")
 
 	if userIDStr == nil || userRole == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 
 	userID, err := uuid.Parse(userIDStr.(string))
 	if err != nil {
 		log.Error().Err(err).Msg("Invalid user ID in context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return SendInternalError(c, "Invalid user ID")
 	}
 
 	var req settings.UpdateCustomSettingRequest
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse request body")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate that at least value is provided
 	if req.Value == nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting value is required",
-		})
+		return SendBadRequest(c, "Setting value is required", ErrCodeMissingField)
 	}
 
 	setting, err := h.settingsService.UpdateSetting(ctx, key, req, userID, userRole.(string))
 	if err != nil {
 		if errors.Is(err, settings.ErrCustomSettingNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Setting not found",
-			})
+			return SendNotFound(c, "Setting not found")
 		}
 		if errors.Is(err, settings.ErrCustomSettingPermissionDenied) {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "You do not have permission to edit this setting",
-				"code":  "PERMISSION_DENIED",
-			})
+			return SendForbidden(c, "You do not have permission to edit this setting", "PERMISSION_DENIED")
 		}
 		log.Error().Err(err).Str("key", key).Msg("Failed to update custom setting")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update setting",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to update setting", ErrCodeOperationFailed)
 	}
 
 	log.Info().
@@ -274,36 +219,25 @@ func (h *CustomSettingsHandler) DeleteSetting(c fiber.Ctx) error {
 	key := c.Params("*")
 
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting key is required",
-		})
+		return SendBadRequest(c, "Setting key is required", ErrCodeMissingField)
 	}
 
 	// Get user role from context
 	userRole := c.Locals("user_role")
 	if userRole == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 
 	err := h.settingsService.DeleteSetting(ctx, key, userRole.(string))
 	if err != nil {
 		if errors.Is(err, settings.ErrCustomSettingNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Setting not found",
-			})
+			return SendNotFound(c, "Setting not found")
 		}
 		if errors.Is(err, settings.ErrCustomSettingPermissionDenied) {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "You do not have permission to delete this setting",
-				"code":  "PERMISSION_DENIED",
-			})
+			return SendForbidden(c, "You do not have permission to delete this setting", "PERMISSION_DENIED")
 		}
 		log.Error().Err(err).Str("key", key).Msg("Failed to delete custom setting")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete setting",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to delete setting", ErrCodeOperationFailed)
 	}
 
 	log.Info().
@@ -322,59 +256,41 @@ func (h *CustomSettingsHandler) CreateSecretSetting(c fiber.Ctx) error {
 	// Get user ID from context
 	userIDStr := c.Locals("user_id")
 	if userIDStr == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 
 	userID, err := uuid.Parse(userIDStr.(string))
 	if err != nil {
 		log.Error().Err(err).Msg("Invalid user ID in context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return SendInternalError(c, "Invalid user ID")
 	}
 
 	var req settings.CreateSecretSettingRequest
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse request body")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate required fields
 	if req.Key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting key is required",
-		})
+		return SendBadRequest(c, "Setting key is required", ErrCodeMissingField)
 	}
 
 	if req.Value == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting value is required",
-		})
+		return SendBadRequest(c, "Setting value is required", ErrCodeMissingField)
 	}
 
 	// Create system secret (userID = nil for system-level)
 	metadata, err := h.settingsService.CreateSecretSetting(ctx, req, nil, userID)
 	if err != nil {
 		if errors.Is(err, settings.ErrCustomSettingDuplicate) {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error": "A secret setting with this key already exists",
-				"code":  "DUPLICATE_KEY",
-			})
+			return SendConflict(c, "A secret setting with this key already exists", "DUPLICATE_KEY")
 		}
 		if errors.Is(err, settings.ErrCustomSettingInvalidKey) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid setting key format",
-				"code":  "INVALID_KEY",
-			})
+			return SendBadRequest(c, "Invalid setting key format", "INVALID_KEY")
 		}
 		log.Error().Err(err).Str("key", req.Key).Msg("Failed to create secret setting")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create secret setting",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to create secret setting", ErrCodeOperationFailed)
 	}
 
 	log.Info().
@@ -392,23 +308,17 @@ func (h *CustomSettingsHandler) GetSecretSetting(c fiber.Ctx) error {
 	key := c.Params("*")
 
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting key is required",
-		})
+		return SendBadRequest(c, "Setting key is required", ErrCodeMissingField)
 	}
 
 	// Get system secret (userID = nil)
 	metadata, err := h.settingsService.GetSecretSettingMetadata(ctx, key, nil)
 	if err != nil {
 		if errors.Is(err, settings.ErrCustomSettingNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Secret setting not found",
-			})
+			return SendNotFound(c, "Secret setting not found")
 		}
 		log.Error().Err(err).Str("key", key).Msg("Failed to get secret setting")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve secret setting",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve secret setting", ErrCodeOperationFailed)
 	}
 
 	return c.JSON(metadata)
@@ -421,47 +331,35 @@ func (h *CustomSettingsHandler) UpdateSecretSetting(c fiber.Ctx) error {
 	key := c.Params("*")
 
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting key is required",
-		})
+		return SendBadRequest(c, "Setting key is required", ErrCodeMissingField)
 	}
 
 	// Get user ID from context
 	userIDStr := c.Locals("user_id")
 	if userIDStr == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 
 	userID, err := uuid.Parse(userIDStr.(string))
 	if err != nil {
 		log.Error().Err(err).Msg("Invalid user ID in context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return SendInternalError(c, "Invalid user ID")
 	}
 
 	var req settings.UpdateSecretSettingRequest
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse request body")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Update system secret (userID = nil)
 	metadata, err := h.settingsService.UpdateSecretSetting(ctx, key, req, nil, userID)
 	if err != nil {
 		if errors.Is(err, settings.ErrCustomSettingNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Secret setting not found",
-			})
+			return SendNotFound(c, "Secret setting not found")
 		}
 		log.Error().Err(err).Str("key", key).Msg("Failed to update secret setting")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update secret setting",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to update secret setting", ErrCodeOperationFailed)
 	}
 
 	log.Info().
@@ -479,23 +377,17 @@ func (h *CustomSettingsHandler) DeleteSecretSetting(c fiber.Ctx) error {
 	key := c.Params("*")
 
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting key is required",
-		})
+		return SendBadRequest(c, "Setting key is required", ErrCodeMissingField)
 	}
 
 	// Delete system secret (userID = nil)
 	err := h.settingsService.DeleteSecretSetting(ctx, key, nil)
 	if err != nil {
 		if errors.Is(err, settings.ErrCustomSettingNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Secret setting not found",
-			})
+			return SendNotFound(c, "Secret setting not found")
 		}
 		log.Error().Err(err).Str("key", key).Msg("Failed to delete secret setting")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete secret setting",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to delete secret setting", ErrCodeOperationFailed)
 	}
 
 	log.Info().
@@ -514,9 +406,7 @@ func (h *CustomSettingsHandler) ListSecretSettings(c fiber.Ctx) error {
 	secrets, err := h.settingsService.ListSecretSettings(ctx, nil)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list secret settings")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve secret settings",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve secret settings", ErrCodeOperationFailed)
 	}
 
 	return c.JSON(secrets)
@@ -18,6 +18,7 @@ import (
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/audit"
 	"github.com/nimbleflux/fluxbase/internal/auth"
 	"github.com/nimbleflux/fluxbase/internal/crypto"
 	"github.com/nimbleflux/fluxbase/internal/database"
@@ -36,6 +37,7 @@ type DashboardAuthHandler struct {
 	baseURL       string
 	encryptionKey string
 	oauthHandler  *OAuthHandler // Reference to app OAuth handler for state validation
+	auditLogger   *audit.Logger // nil when the audit log is disabled
 
 	// OAuth state storage (in production, use Redis or database)
 	oauthStates    map[string]*dashboardOAuthState
@@ -68,6 +70,22 @@ func NewDashboardAuthHandler(authService *auth.DashboardAuthService, jwtManager
 	}
 }
 
+// SetAuditLogger sets the logger that records dashboard sign-ins in the audit log
+func (h *DashboardAuthHandler) SetAuditLogger(logger *audit.Logger) {
+	h.auditLogger = logger
+}
+
+// recordSignIn records a dashboard sign-in attempt in the audit log
+func (h *DashboardAuthHandler) recordSignIn(c fiber.Ctx, action string, userID, email, method string) {
+	h.auditLogger.RecordRequest(c, audit.Event{
+		Action:     action,
+		ActorID:    userID,
+		ActorEmail: email,
+		ActorRole:  "dashboard_admin",
+		Details:    map[string]any{"method": method},
+	})
+}
+
 // RegisterRoutes registers dashboard auth routes
 func (h *DashboardAuthHandler) RegisterRoutes(app *fiber.App) {
 	dashboard := app.Group("/dashboard/auth")
@@ -173,6 +191,7 @@ func (h *DashboardAuthHandler) Login(c fiber.Ctx) error {
 
 	user, loginResp, err := h.authService.Login(c.RequestCtx(), req.Email, req.Password, ipAddress, userAgent)
 	if err != nil {
+		h.recordSignIn(c, audit.ActionSignInFailed, "", req.Email, "password")
 		if errors.Is(err, pgx.ErrNoRows) || err.Error() == "invalid credentials" {
 			return fiber.NewError(fiber.StatusUnauthorized, "Invalid email or password")
 		}
@@ -195,6 +214,8 @@ func (h *DashboardAuthHandler) Login(c fiber.Ctx) error {
 		})
 	}
 
+	h.recordSignIn(c, audit.ActionSignIn, user.ID.String(), user.Email, "password")
+
 	return c.JSON(fiber.Map{
 		"access_token":  loginResp.AccessToken,
 		"refresh_token": loginResp.RefreshToken,
@@ -251,6 +272,7 @@ func (h *DashboardAuthHandler) VerifyTOTP(c fiber.Ctx) error {
 
 	err = h.authService.VerifyTOTP(c.RequestCtx(), userID, req.Code)
 	if err != nil {
+		h.recordSignIn(c, audit.ActionSignInFailed, req.UserID, "", "totp")
 		return fiber.NewError(fiber.StatusUnauthorized, "Invalid 2FA code")
 	}
 
@@ -266,6 +288,8 @@ func (h *DashboardAuthHandler) VerifyTOTP(c fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to generate tokens")
 	}
 
+	h.recordSignIn(c, audit.ActionSignIn, user.ID.String(), user.Email, "totp")
+
 	return c.JSON(fiber.Map{
 		"access_token":  accessToken,
 		"refresh_token": refreshToken,
@@ -487,10 +511,7 @@ func (h *DashboardAuthHandler) DisableTOTP(c fiber.Ctx) error {
 func (h *DashboardAuthHandler) RequestPasswordReset(c fiber.Ctx) error {
 	// Check if email service is configured
 	if h.emailService == nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Email service is not configured. Please configure an email provider to enable password reset.",
-			"code":  "SMTP_NOT_CONFIGURED",
-		})
+		return SendBadRequest(c, "Email service is not configured. Please configure an email provider to enable password reset.", "SMTP_NOT_CONFIGURED")
 	}
 
 	var req struct {
@@ -831,9 +852,7 @@ func (h *DashboardAuthHandler) InitiateOAuthLogin(c fiber.Ctx) error {
 			log.Warn().
 				Str("provider_id", providerID).
 				Msg("OAuth provider not found or not enabled for dashboard login")
-			return c.Status(404).JSON(fiber.Map{
-				"error": "OAuth provider not found or not enabled for dashboard login",
-			})
+			return SendErrorWithCode(c, 404, "OAuth provider not found or not enabled for dashboard login", ErrCodeFeatureDisabled)
 		}
 		log.Error().Err(err).Str("provider_id", providerID).Msg("Failed to fetch OAuth provider")
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch OAuth provider")
@@ -1324,9 +1343,7 @@ func (h *DashboardAuthHandler) InitiateSAMLLogin(c fiber.Ctx) error {
 
 	if h.samlService == nil {
 		log.Error().Msg("SAML service not configured for dashboard")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "SAML not configured",
-		})
+		return SendInternalError(c, "SAML not configured")
 	}
 
 	// Resolve provider name from ID or name
@@ -1345,9 +1362,7 @@ func (h *DashboardAuthHandler) InitiateSAMLLogin(c fiber.Ctx) error {
 			log.Warn().
 				Str("provider_id", providerIDOrName).
 				Msg("SAML provider not found for dashboard login")
-			return c.Status(404).JSON(fiber.Map{
-				"error": "SAML provider not found or not enabled for dashboard login",
-			})
+			return SendErrorWithCode(c, 404, "SAML provider not found or not enabled for dashboard login", ErrCodeFeatureDisabled)
 		}
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch SAML provider")
 	}
@@ -1357,9 +1372,7 @@ func (h *DashboardAuthHandler) InitiateSAMLLogin(c fiber.Ctx) error {
 		log.Warn().
 			Str("provider", providerName).
 			Msg("SAML provider not enabled for dashboard login")
-		return c.Status(403).JSON(fiber.Map{
-			"error": "SAML provider not enabled for dashboard login",
-		})
+		return SendForbidden(c, "SAML provider not enabled for dashboard login", ErrCodeFeatureDisabled)
 	}
 
 	// Get provider from service (by name)
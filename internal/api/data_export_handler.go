@@ -38,50 +38,36 @@ func (h *DataExportHandler) HandleDataExport(c fiber.Ctx) error {
 
 	// Validate request
 	if format != "csv" && format != "json" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid export format. Must be 'csv' or 'json'",
-		})
+		return SendBadRequest(c, "Invalid export format. Must be 'csv' or 'json'", ErrCodeInvalidFormat)
 	}
 
 	if tableName == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Table name is required",
-		})
+		return SendBadRequest(c, "Table name is required", ErrCodeMissingField)
 	}
 
 	// Parse selected items as JSON array
 	var targetIds []string
 	if err := json.Unmarshal([]byte(selectedItems), &targetIds); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": fmt.Sprintf("Invalid items format: %v", err),
-		})
+		return SendBadRequest(c, fmt.Sprintf("Invalid items format: %v", err), ErrCodeInvalidFormat)
 	}
 
 	if len(targetIds) == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "At least one item ID is required",
-		})
+		return SendBadRequest(c, "At least one item ID is required", ErrCodeMissingField)
 	}
 
 	// Parse schema and table name
 	schema, table, err := parseTableIdentifier(tableName)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": fmt.Sprintf("Invalid table name: %v", err),
-		})
+		return SendBadRequest(c, fmt.Sprintf("Invalid table name: %v", err), ErrCodeInvalidInput)
 	}
 
 	// Get table metadata
 	tableInfo, exists, err := h.schemaCache.GetTable(c.RequestCtx(), schema, table)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to lookup table: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to lookup table: %v", err), ErrCodeOperationFailed)
 	}
 	if !exists {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": fmt.Sprintf("Table not found: %s", tableName),
-		})
+		return SendNotFound(c, fmt.Sprintf("Table not found: %s", tableName))
 	}
 
 	// Get primary key column
@@ -110,15 +96,11 @@ func (h *DataExportHandler) HandleDataExport(c fiber.Ctx) error {
 		return err
 	})
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to export records: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to export records: %v", err), ErrCodeOperationFailed)
 	}
 
 	if len(results) == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "No records found",
-		})
+		return SendNotFound(c, "No records found")
 	}
 
 	// Format based on requested format
@@ -128,18 +110,14 @@ func (h *DataExportHandler) HandleDataExport(c fiber.Ctx) error {
 	case "json":
 		return c.JSON(results)
 	default:
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid export format",
-		})
+		return SendBadRequest(c, "Invalid export format", ErrCodeInvalidFormat)
 	}
 }
 
 // exportAsCSV converts results to CSV format
 func (h *DataExportHandler) exportAsCSV(c fiber.Ctx, tableInfo *database.TableInfo, results []map[string]interface{}) error {
 	if len(results) == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "No records to export",
-		})
+		return SendNotFound(c, "No records to export")
 	}
 
 	// Get column names from table metadata
@@ -154,9 +132,7 @@ func (h *DataExportHandler) exportAsCSV(c fiber.Ctx, tableInfo *database.TableIn
 
 	// Write header
 	if err := writer.Write(columns); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to write CSV header: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to write CSV header: %v", err), ErrCodeOperationFailed)
 	}
 
 	// Write rows
@@ -167,17 +143,13 @@ func (h *DataExportHandler) exportAsCSV(c fiber.Ctx, tableInfo *database.TableIn
 			record[i] = formatValue(val)
 		}
 		if err := writer.Write(record); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": fmt.Sprintf("Failed to write CSV row: %v", err),
-			})
+			return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to write CSV row: %v", err), ErrCodeOperationFailed)
 		}
 	}
 
 	writer.Flush()
 	if err := writer.Error(); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to generate CSV: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to generate CSV: %v", err), ErrCodeOperationFailed)
 	}
 
 	// Set headers for CSV download
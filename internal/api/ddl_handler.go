@@ -77,23 +77,17 @@ type CreateColumnRequest struct {
 func (h *DDLHandler) CreateSchema(c fiber.Ctx) error {
 	var req CreateSchemaRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate schema name
 	if err := validateIdentifier(req.Name, "schema"); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	ctx := c.RequestCtx()
@@ -102,14 +96,10 @@ func (h *DDLHandler) CreateSchema(c fiber.Ctx) error {
 	exists, err := h.schemaExists(ctx, req.Name)
 	if err != nil {
 		log.Error().Err(err).Str("schema", req.Name).Msg("Failed to check schema existence")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to check schema existence",
-		})
+		return SendErrorWithCode(c, 500, "Failed to check schema existence", ErrCodeOperationFailed)
 	}
 	if exists {
-		return c.Status(409).JSON(fiber.Map{
-			"error": fmt.Sprintf("Schema '%s' already exists", req.Name),
-		})
+		return SendConflict(c, fmt.Sprintf("Schema '%s' already exists", req.Name), ErrCodeAlreadyExists)
 	}
 
 	// Create schema (using quoted identifier for safety)
@@ -124,9 +114,7 @@ func (h *DDLHandler) CreateSchema(c fiber.Ctx) error {
 	})
 	if err != nil {
 		log.Error().Err(err).Str("schema", req.Name).Msg("Failed to create schema")
-		return c.Status(500).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to create schema: %v", err),
-		})
+		return SendErrorWithCode(c, 500, fmt.Sprintf("Failed to create schema: %v", err), ErrCodeOperationFailed)
 	}
 
 	// Set up default privileges for tables created in this schema by the admin user
@@ -148,37 +136,27 @@ func (h *DDLHandler) CreateSchema(c fiber.Ctx) error {
 func (h *DDLHandler) CreateTable(c fiber.Ctx) error {
 	var req CreateTableRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate schema name
 	if err := validateIdentifier(req.Schema, "schema"); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	// Validate table name
 	if err := validateIdentifier(req.Name, "table"); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	// Validate columns
 	if len(req.Columns) == 0 {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "At least one column is required",
-		})
+		return SendBadRequest(c, "At least one column is required", ErrCodeMissingField)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	ctx := c.RequestCtx()
@@ -187,36 +165,26 @@ func (h *DDLHandler) CreateTable(c fiber.Ctx) error {
 	exists, err := h.schemaExists(ctx, req.Schema)
 	if err != nil {
 		log.Error().Err(err).Str("schema", req.Schema).Msg("Failed to check schema existence")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to check schema existence",
-		})
+		return SendErrorWithCode(c, 500, "Failed to check schema existence", ErrCodeOperationFailed)
 	}
 	if !exists {
-		return c.Status(404).JSON(fiber.Map{
-			"error": fmt.Sprintf("Schema '%s' does not exist", req.Schema),
-		})
+		return SendNotFound(c, fmt.Sprintf("Schema '%s' does not exist", req.Schema))
 	}
 
 	// Check if table already exists
 	tableExists, err := h.tableExists(ctx, req.Schema, req.Name)
 	if err != nil {
 		log.Error().Err(err).Str("table", req.Schema+"."+req.Name).Msg("Failed to check table existence")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to check table existence",
-		})
+		return SendErrorWithCode(c, 500, "Failed to check table existence", ErrCodeOperationFailed)
 	}
 	if tableExists {
-		return c.Status(409).JSON(fiber.Map{
-			"error": fmt.Sprintf("Table '%s.%s' already exists", req.Schema, req.Name),
-		})
+		return SendConflict(c, fmt.Sprintf("Table '%s.%s' already exists", req.Schema, req.Name), ErrCodeAlreadyExists)
 	}
 
 	// Build CREATE TABLE statement
 	query, err := h.buildCreateTableQuery(req)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	log.Info().
@@ -232,9 +200,7 @@ func (h *DDLHandler) CreateTable(c fiber.Ctx) error {
 	})
 	if err != nil {
 		log.Error().Err(err).Str("table", req.Schema+"."+req.Name).Msg("Failed to create table")
-		return c.Status(500).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to create table: %v", err),
-		})
+		return SendErrorWithCode(c, 500, fmt.Sprintf("Failed to create table: %v", err), ErrCodeOperationFailed)
 	}
 
 	// Grant permissions to service_role for dashboard_admin access
@@ -261,21 +227,15 @@ func (h *DDLHandler) DeleteTable(c fiber.Ctx) error {
 
 	// Validate identifiers
 	if err := validateIdentifier(schema, "schema"); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 	if err := validateIdentifier(table, "table"); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	ctx := c.RequestCtx()
@@ -284,14 +244,10 @@ func (h *DDLHandler) DeleteTable(c fiber.Ctx) error {
 	exists, err := h.tableExists(ctx, schema, table)
 	if err != nil {
 		log.Error().Err(err).Str("table", schema+"."+table).Msg("Failed to check table existence")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to check table existence",
-		})
+		return SendErrorWithCode(c, 500, "Failed to check table existence", ErrCodeOperationFailed)
 	}
 	if !exists {
-		return c.Status(404).JSON(fiber.Map{
-			"error": fmt.Sprintf("Table '%s.%s' does not exist", schema, table),
-		})
+		return SendNotFound(c, fmt.Sprintf("Table '%s.%s' does not exist", schema, table))
 	}
 
 	// Build DROP TABLE statement
@@ -305,9 +261,7 @@ func (h *DDLHandler) DeleteTable(c fiber.Ctx) error {
 	})
 	if err != nil {
 		log.Error().Err(err).Str("table", schema+"."+table).Msg("Failed to drop table")
-		return c.Status(500).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to drop table: %v", err),
-		})
+		return SendErrorWithCode(c, 500, fmt.Sprintf("Failed to drop table: %v", err), ErrCodeOperationFailed)
 	}
 
 	log.Info().Str("table", schema+"."+table).Msg("Table dropped successfully")
@@ -350,9 +304,7 @@ func (h *DDLHandler) AddColumn(c fiber.Ctx) error {
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	// Validate data type
@@ -403,9 +355,7 @@ func (h *DDLHandler) AddColumn(c fiber.Ctx) error {
 	})
 	if err != nil {
 		log.Error().Err(err).Str("table", schema+"."+table).Str("column", req.Name).Msg("Failed to add column")
-		return c.Status(500).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to add column: %v", err),
-		})
+		return SendErrorWithCode(c, 500, fmt.Sprintf("Failed to add column: %v", err), ErrCodeOperationFailed)
 	}
 
 	log.Info().Str("table", schema+"."+table).Str("column", req.Name).Msg("Column added successfully")
@@ -434,9 +384,7 @@ func (h *DDLHandler) DropColumn(c fiber.Ctx) error {
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	ctx := c.RequestCtx()
@@ -507,9 +455,7 @@ func (h *DDLHandler) RenameTable(c fiber.Ctx) error {
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	// Validate new table name
@@ -550,9 +496,7 @@ func (h *DDLHandler) RenameTable(c fiber.Ctx) error {
 	})
 	if err != nil {
 		log.Error().Err(err).Str("table", schema+"."+table).Str("newName", req.NewName).Msg("Failed to rename table")
-		return c.Status(500).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to rename table: %v", err),
-		})
+		return SendErrorWithCode(c, 500, fmt.Sprintf("Failed to rename table: %v", err), ErrCodeOperationFailed)
 	}
 
 	log.Info().Str("table", schema+"."+table).Str("newName", req.NewName).Msg("Table renamed successfully")
@@ -562,6 +506,205 @@ func (h *DDLHandler) RenameTable(c fiber.Ctx) error {
 	})
 }
 
+// CreateIndexRequest represents a request to create an index on a table
+type CreateIndexRequest struct {
+	// Name of the index, generated from the table and columns if empty
+	Name string `json:"name,omitempty"`
+	// Columns to index. A JSONB path like "data->address->>city" indexes the extracted text.
+	Columns []string `json:"columns"`
+	// Method is "btree" (default) or "gin"
+	Method string `json:"method,omitempty"`
+	// OpClass is an optional operator class for GIN indexes, e.g. "jsonb_path_ops"
+	OpClass string `json:"opClass,omitempty"`
+	Unique  bool   `json:"unique,omitempty"`
+}
+
+// jsonPathKeyPattern matches the keys allowed in JSONB path index expressions
+var jsonPathKeyPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// indexColumnExpression builds the indexed expression for a column or a JSONB path
+func indexColumnExpression(column string) (string, error) {
+	name, path := splitJSONPath(column)
+	if err := validateIdentifier(name, "column"); err != nil {
+		return "", err
+	}
+	if len(path) == 0 {
+		return quoteIdentifier(name), nil
+	}
+	if !strings.HasSuffix(column, "->>"+path[len(path)-1]) || strings.Count(column, "->>") != 1 {
+		return "", fmt.Errorf("JSONB path '%s' must end with ->> and use -> for intermediate keys", column)
+	}
+
+	expr := quoteIdentifier(name)
+	for i, key := range path {
+		if !jsonPathKeyPattern.MatchString(key) {
+			return "", fmt.Errorf("invalid JSONB key '%s' in '%s'", key, column)
+		}
+		op := "->"
+		if i == len(path)-1 {
+			op = "->>"
+		}
+		expr += op + escapeLiteral(key)
+	}
+	return "(" + expr + ")", nil
+}
+
+// defaultIndexName generates an index name from the table and columns
+func defaultIndexName(table string, columns []string) string {
+	parts := []string{"idx", table}
+	for _, column := range columns {
+		name, path := splitJSONPath(column)
+		parts = append(parts, name)
+		for _, key := range path {
+			parts = append(parts, strings.ReplaceAll(key, "-", "_"))
+		}
+	}
+	name := strings.ToLower(strings.Join(parts, "_"))
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return name
+}
+
+// buildCreateIndexQuery builds a CREATE INDEX CONCURRENTLY statement and returns it with the index name
+func buildCreateIndexQuery(schema, table string, req CreateIndexRequest) (string, string, error) {
+	if len(req.Columns) == 0 {
+		return "", "", fmt.Errorf("at least one column is required")
+	}
+
+	method := strings.ToLower(strings.TrimSpace(req.Method))
+	switch method {
+	case "":
+		method = "btree"
+	case "btree":
+	case "gin":
+		if len(req.Columns) != 1 || strings.Contains(req.Columns[0], "->") {
+			return "", "", fmt.Errorf("gin indexes must cover a single column")
+		}
+		if req.Unique {
+			return "", "", fmt.Errorf("gin indexes cannot be unique")
+		}
+	default:
+		return "", "", fmt.Errorf("invalid index method '%s': must be btree or gin", req.Method)
+	}
+	if req.OpClass != "" && (method != "gin" || req.OpClass != "jsonb_path_ops") {
+		return "", "", fmt.Errorf("invalid operator class '%s': only jsonb_path_ops is supported, for gin indexes", req.OpClass)
+	}
+
+	exprs := make([]string, len(req.Columns))
+	for i, column := range req.Columns {
+		expr, err := indexColumnExpression(column)
+		if err != nil {
+			return "", "", err
+		}
+		if req.OpClass != "" {
+			expr += " " + req.OpClass
+		}
+		exprs[i] = expr
+	}
+
+	name := req.Name
+	if name == "" {
+		name = defaultIndexName(table, req.Columns)
+	}
+	if err := validateIdentifier(name, "index"); err != nil {
+		return "", "", err
+	}
+
+	unique := ""
+	if req.Unique {
+		unique = "UNIQUE "
+	}
+	query := fmt.Sprintf("CREATE %sINDEX CONCURRENTLY %s ON %s.%s USING %s (%s)",
+		unique, quoteIdentifier(name), quoteIdentifier(schema), quoteIdentifier(table), method, strings.Join(exprs, ", "))
+	return query, name, nil
+}
+
+// createIndex runs a CREATE INDEX CONCURRENTLY statement with the admin role.
+// A failed concurrent build leaves an invalid index behind, which is dropped.
+func (h *DDLHandler) createIndex(ctx context.Context, schema, name, query string) error {
+	return h.db.ExecuteWithAdminRole(ctx, func(conn *pgx.Conn) error {
+		_, execErr := conn.Exec(ctx, query)
+		if execErr != nil {
+			_, _ = conn.Exec(ctx, fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %s.%s", quoteIdentifier(schema), quoteIdentifier(name)))
+		}
+		return execErr
+	})
+}
+
+// indexExists checks if an index exists in a schema
+func (h *DDLHandler) indexExists(ctx context.Context, schema, name string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM pg_indexes WHERE schemaname = $1 AND indexname = $2)`
+	err := h.db.Pool().QueryRow(ctx, query, schema, name).Scan(&exists)
+	return exists, err
+}
+
+// CreateIndex creates an index on a table without blocking writes
+func (h *DDLHandler) CreateIndex(c fiber.Ctx) error {
+	schema := c.Params("schema")
+	table := c.Params("table")
+
+	// Validate identifiers
+	if err := validateIdentifier(schema, "schema"); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeValidationFailed)
+	}
+	if err := validateIdentifier(table, "table"); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeValidationFailed)
+	}
+
+	var req CreateIndexRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+
+	query, name, err := buildCreateIndexQuery(schema, table, req)
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeValidationFailed)
+	}
+
+	// Check if database connection is available
+	if h.db == nil {
+		return SendInternalError(c, "Database connection not initialized")
+	}
+
+	ctx := c.RequestCtx()
+
+	// Check if table exists
+	exists, err := h.tableExists(ctx, schema, table)
+	if err != nil {
+		log.Error().Err(err).Str("table", schema+"."+table).Msg("Failed to check table existence")
+		return SendOperationFailed(c, "check table existence")
+	}
+	if !exists {
+		return SendNotFound(c, fmt.Sprintf("Table '%s.%s' does not exist", schema, table))
+	}
+
+	// Check if index already exists
+	idxExists, err := h.indexExists(ctx, schema, name)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to check index existence")
+		return SendOperationFailed(c, "check index existence")
+	}
+	if idxExists {
+		return SendConflict(c, fmt.Sprintf("Index '%s' already exists in schema '%s'", name, schema), ErrCodeAlreadyExists)
+	}
+
+	log.Info().Str("table", schema+"."+table).Str("index", name).Str("operation", logutil.ExtractDDLMetadata(query)).Msg("Creating index")
+
+	if err := h.createIndex(ctx, schema, name, query); err != nil {
+		log.Error().Err(err).Str("table", schema+"."+table).Str("index", name).Msg("Failed to create index")
+		return SendInternalError(c, fmt.Sprintf("Failed to create index: %v", err))
+	}
+
+	log.Info().Str("table", schema+"."+table).Str("index", name).Msg("Index created successfully")
+	return c.Status(201).JSON(fiber.Map{
+		"success": true,
+		"index":   name,
+		"message": fmt.Sprintf("Index '%s' created on table '%s.%s'", name, schema, table),
+	})
+}
+
 // Helper functions
 
 // validateIdentifier validates a PostgreSQL identifier (schema/table/column name)
@@ -873,9 +1016,7 @@ func (h *DDLHandler) setupSchemaDefaultPrivileges(ctx context.Context, schema st
 func (h *DDLHandler) ListSchemas(c fiber.Ctx) error {
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	ctx := c.RequestCtx()
@@ -907,9 +1048,7 @@ func (h *DDLHandler) ListSchemas(c fiber.Ctx) error {
 func (h *DDLHandler) ListTables(c fiber.Ctx) error {
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	ctx := c.RequestCtx()
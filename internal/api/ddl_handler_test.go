@@ -970,3 +970,89 @@ func TestDDLHandler_NilDatabase(t *testing.T) {
 		})
 	}
 }
+
+// =============================================================================
+// Index Tests
+// =============================================================================
+
+func TestBuildCreateIndexQuery(t *testing.T) {
+	t.Run("btree on columns with generated name", func(t *testing.T) {
+		query, name, err := buildCreateIndexQuery("public", "orders", CreateIndexRequest{Columns: []string{"customer_id", "created_at"}})
+		require.NoError(t, err)
+		assert.Equal(t, "idx_orders_customer_id_created_at", name)
+		assert.Equal(t, `CREATE INDEX CONCURRENTLY "idx_orders_customer_id_created_at" ON "public"."orders" USING btree ("customer_id", "created_at")`, query)
+	})
+
+	t.Run("jsonb path expression", func(t *testing.T) {
+		query, name, err := buildCreateIndexQuery("public", "orders", CreateIndexRequest{Columns: []string{"data->address->>city"}})
+		require.NoError(t, err)
+		assert.Equal(t, "idx_orders_data_address_city", name)
+		assert.Contains(t, query, `USING btree (("data"->'address'->>'city'))`)
+	})
+
+	t.Run("gin with operator class", func(t *testing.T) {
+		query, _, err := buildCreateIndexQuery("public", "orders", CreateIndexRequest{
+			Name: "orders_data_gin", Columns: []string{"data"}, Method: "gin", OpClass: "jsonb_path_ops",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `CREATE INDEX CONCURRENTLY "orders_data_gin" ON "public"."orders" USING gin ("data" jsonb_path_ops)`, query)
+	})
+
+	t.Run("unique", func(t *testing.T) {
+		query, _, err := buildCreateIndexQuery("public", "users", CreateIndexRequest{Columns: []string{"email"}, Unique: true})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(query, "CREATE UNIQUE INDEX CONCURRENTLY"))
+	})
+
+	invalid := []struct {
+		name string
+		req  CreateIndexRequest
+		err  string
+	}{
+		{"no columns", CreateIndexRequest{}, "at least one column"},
+		{"bad method", CreateIndexRequest{Columns: []string{"a"}, Method: "hash"}, "invalid index method"},
+		{"multi-column gin", CreateIndexRequest{Columns: []string{"a", "b"}, Method: "gin"}, "single column"},
+		{"unique gin", CreateIndexRequest{Columns: []string{"a"}, Method: "gin", Unique: true}, "cannot be unique"},
+		{"opclass on btree", CreateIndexRequest{Columns: []string{"a"}, OpClass: "jsonb_path_ops"}, "operator class"},
+		{"unknown opclass", CreateIndexRequest{Columns: []string{"a"}, Method: "gin", OpClass: "gin_trgm_ops"}, "operator class"},
+		{"bad column", CreateIndexRequest{Columns: []string{"a; DROP TABLE x"}}, "column name"},
+		{"bad path key", CreateIndexRequest{Columns: []string{"data->>'x'"}}, "invalid JSONB key"},
+		{"path without text extraction", CreateIndexRequest{Columns: []string{"data->address"}}, "must end with ->>"},
+		{"bad name", CreateIndexRequest{Name: "1idx", Columns: []string{"a"}}, "index name"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := buildCreateIndexQuery("public", "t", tt.req)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestDDLHandler_CreateIndex_Validation(t *testing.T) {
+	handler := NewDDLHandler(nil)
+	app := fiber.New()
+	app.Post("/tables/:schema/:table/indexes", handler.CreateIndex)
+
+	tests := []struct {
+		name       string
+		url        string
+		body       string
+		wantStatus int
+	}{
+		{"invalid schema", "/tables/1bad/orders/indexes", `{"columns": ["a"]}`, 400},
+		{"invalid body", "/tables/public/orders/indexes", `{`, 400},
+		{"invalid index", "/tables/public/orders/indexes", `{"columns": []}`, 400},
+		{"nil database", "/tables/public/orders/indexes", `{"columns": ["customer_id"]}`, 500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+		})
+	}
+}
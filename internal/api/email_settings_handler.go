@@ -215,16 +215,12 @@ func (h *EmailSettingsHandler) UpdateSettings(c fiber.Ctx) error {
 	var req UpdateEmailSettingsRequest
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse update email settings request")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Nil check for dependencies (can happen in tests)
 	if h.settingsService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Settings service not initialized",
-		})
+		return SendInternalError(c, "Settings service not initialized")
 	}
 
 	// Track which settings were updated
@@ -386,29 +382,21 @@ func (h *EmailSettingsHandler) TestSettings(c fiber.Ctx) error {
 	var req TestEmailSettingsRequest
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse test email request")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	if req.RecipientEmail == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Recipient email is required",
-		})
+		return SendBadRequest(c, "Recipient email is required", ErrCodeMissingField)
 	}
 
 	// Get current email service
 	if h.emailManager == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Email service not initialized",
-		})
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "Email service not initialized", ErrCodeServiceUnavailable)
 	}
 
 	service := h.emailManager.GetService()
 	if service == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Email service not available",
-		})
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "Email service not available", ErrCodeServiceUnavailable)
 	}
 
 	// Send test email
@@ -428,10 +416,7 @@ func (h *EmailSettingsHandler) TestSettings(c fiber.Ctx) error {
 
 	if err := service.Send(ctx, req.RecipientEmail, subject, body); err != nil {
 		log.Error().Err(err).Str("recipient", req.RecipientEmail).Msg("Failed to send test email")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "Failed to send test email",
-			"details": err.Error(),
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "Failed to send test email", ErrCodeOperationFailed, "", "", err.Error())
 	}
 
 	log.Info().Str("recipient", req.RecipientEmail).Msg("Test email sent successfully")
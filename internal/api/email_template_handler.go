@@ -1,15 +1,10 @@
 package api
 
 import (
-	"bytes"
-	"context"
-	"errors"
-	"html/template"
-	"time"
+	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v3"
-	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5"
 	"github.com/nimbleflux/fluxbase/internal/database"
 	"github.com/nimbleflux/fluxbase/internal/email"
 	"github.com/rs/zerolog/log"
@@ -19,27 +14,23 @@ import (
 type EmailTemplateHandler struct {
 	db           *database.Connection
 	emailService email.Service
+	store        *email.TemplateStore
 }
 
 // NewEmailTemplateHandler creates a new email template handler
 func NewEmailTemplateHandler(db *database.Connection, emailService email.Service) *EmailTemplateHandler {
-	return &EmailTemplateHandler{
+	h := &EmailTemplateHandler{
 		db:           db,
 		emailService: emailService,
 	}
+	if db != nil {
+		h.store = email.NewTemplateStore(db)
+	}
+	return h
 }
 
 // EmailTemplate represents an email template
-type EmailTemplate struct {
-	ID           uuid.UUID `json:"id"`
-	TemplateType string    `json:"template_type"`
-	Subject      string    `json:"subject"`
-	HTMLBody     string    `json:"html_body"`
-	TextBody     *string   `json:"text_body,omitempty"`
-	IsCustom     bool      `json:"is_custom"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-}
+type EmailTemplate = email.Template
 
 // UpdateTemplateRequest represents the request to update a template
 type UpdateTemplateRequest struct {
@@ -51,8 +42,20 @@ type UpdateTemplateRequest struct {
 // TestEmailRequest represents a request to send a test email
 type TestEmailRequest struct {
 	RecipientEmail string `json:"recipient_email"`
+	Locale         string `json:"locale,omitempty"`
 }
 
+// PreviewTemplateRequest represents a request to render a template with sample data.
+// Without a subject and HTML body, the saved template for the locale is rendered.
+type PreviewTemplateRequest struct {
+	Locale   string            `json:"locale,omitempty"`
+	Subject  string            `json:"subject,omitempty"`
+	HTMLBody string            `json:"html_body,omitempty"`
+	TextBody *string           `json:"text_body,omitempty"`
+	Data     map[string]string `json:"data,omitempty"`
+}
+
+// Default email templates
 // Default email templates
 var defaultTemplates = map[string]EmailTemplate{
 	"magic_link": {
@@ -154,57 +157,154 @@ This link will expire in 1 hour.
 If you didn't request a password reset, you can safely ignore this email. Your password will not be changed.`),
 		IsCustom: false,
 	},
+	"invitation": {
+		TemplateType: "invitation",
+		Subject:      "You've Been Invited to {{.AppName}}",
+		HTMLBody: `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
+    <div style="background-color: #f4f4f4; padding: 20px; border-radius: 5px;">
+        <h1 style="color: #2c3e50; margin-bottom: 20px;">You've Been Invited</h1>
+        <p>{{.InviterName}} has invited you to join {{.AppName}}. Click the button below to accept the invitation.</p>
+        <div style="text-align: center; margin: 30px 0;">
+            <a href="{{.InviteLink}}" style="background-color: #3498db; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; display: inline-block;">Accept Invitation</a>
+        </div>
+        <p style="color: #7f8c8d; font-size: 14px;">This invitation expires in 7 days.</p>
+        <p style="color: #7f8c8d; font-size: 14px;">If the button doesn't work, copy and paste this link into your browser:</p>
+        <p style="word-break: break-all; color: #3498db; font-size: 12px;">{{.InviteLink}}</p>
+    </div>
+</body>
+</html>`,
+		TextBody: stringPtr(`You've Been Invited
+
+{{.InviterName}} has invited you to join {{.AppName}}. Open the link below to accept the invitation.
+
+{{.InviteLink}}
+
+This invitation expires in 7 days.`),
+		IsCustom: false,
+	},
+	"otp": {
+		TemplateType: "otp",
+		Subject:      "Your {{.AppName}} Verification Code",
+		HTMLBody: `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
+    <div style="background-color: #f4f4f4; padding: 20px; border-radius: 5px;">
+        <h1 style="color: #2c3e50; margin-bottom: 20px;">Your Verification Code</h1>
+        <p>Use the code below to continue with {{.AppName}}. This code will expire in 15 minutes.</p>
+        <p style="text-align: center; font-size: 32px; letter-spacing: 6px; font-weight: bold; margin: 30px 0;">{{.Code}}</p>
+        <p style="color: #7f8c8d; font-size: 14px;">If you didn't request this code, you can safely ignore this email.</p>
+    </div>
+</body>
+</html>`,
+		TextBody: stringPtr(`Your verification code is: {{.Code}}
+
+Use this code to continue with {{.AppName}}. This code will expire in 15 minutes.
+
+If you didn't request this code, you can safely ignore this email.`),
+		IsCustom: false,
+	},
+	"new_device": {
+		TemplateType: "new_device",
+		Subject:      "New sign-in to your {{.AppName}} account",
+		HTMLBody: `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
+    <div style="background-color: #f4f4f4; padding: 20px; border-radius: 5px;">
+        <h1 style="color: #2c3e50; margin-bottom: 20px;">New Sign-In Detected</h1>
+        <p>Your {{.AppName}} account was signed in to from a device that hasn't been used before.</p>
+        <table style="margin: 20px 0; font-size: 14px;">
+            <tr><td style="color: #7f8c8d; padding-right: 15px;">Time</td><td>{{.SignedInAt}}</td></tr>
+            <tr><td style="color: #7f8c8d; padding-right: 15px;">IP address</td><td>{{.IPAddress}}</td></tr>
+            {{if .Location}}<tr><td style="color: #7f8c8d; padding-right: 15px;">Location</td><td>{{.Location}}</td></tr>{{end}}
+            <tr><td style="color: #7f8c8d; padding-right: 15px;">Device</td><td>{{.UserAgent}}</td></tr>
+        </table>
+        <p>If this was you, you can ignore this email.</p>
+        <p style="color: #c0392b;">If you don't recognize this sign-in, change your password right away and review your devices.</p>
+    </div>
+</body>
+</html>`,
+		TextBody: stringPtr(`New Sign-In Detected
+
+Your {{.AppName}} account was signed in to from a device that hasn't been used before.
+
+Time: {{.SignedInAt}}
+IP address: {{.IPAddress}}
+{{if .Location}}Location: {{.Location}}
+{{end}}Device: {{.UserAgent}}
+
+If this was you, you can ignore this email.
+
+If you don't recognize this sign-in, change your password right away and review your devices.`),
+		IsCustom: false,
+	},
+}
+
+// defaultTemplate returns the default template of a type for a locale
+func defaultTemplate(templateType, locale string) EmailTemplate {
+	template := defaultTemplates[templateType]
+	template.Locale = locale
+	return template
+}
+
+// validateTemplateRequest validates the template type and locale of a request. It returns the
+// normalized locale, or sends the error response and returns ok false.
+func validateTemplateRequest(c fiber.Ctx, locale string) (string, bool, error) {
+	if _, exists := defaultTemplates[c.Params("type")]; !exists {
+		return "", false, SendBadRequest(c, "Invalid template type", ErrCodeInvalidInput)
+	}
+	locale, err := email.NormalizeLocale(locale)
+	if err != nil {
+		return "", false, SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+	return locale, true, nil
+}
+
+// resolveTemplate returns the saved template that best matches a locale, or the default template
+func (h *EmailTemplateHandler) resolveTemplate(c fiber.Ctx, templateType, locale string) (*EmailTemplate, error) {
+	template, err := h.store.Resolve(c.RequestCtx(), templateType, locale)
+	if err != nil || template != nil {
+		return template, err
+	}
+	fallback := defaultTemplate(templateType, "")
+	return &fallback, nil
 }
 
-// ListTemplates returns all email templates
+// ListTemplates returns all email templates and their locale variants
 // GET /api/v1/admin/email/templates
 func (h *EmailTemplateHandler) ListTemplates(c fiber.Ctx) error {
-	ctx := context.Background()
-
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
-	rows, err := h.db.Query(ctx, `
-		SELECT id, template_type, subject, html_body, text_body, is_custom, created_at, updated_at
-		FROM dashboard.email_templates
-		ORDER BY template_type
-	`)
+	templates, err := h.store.List(c.RequestCtx())
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list email templates")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve email templates",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve email templates", ErrCodeOperationFailed)
 	}
-	defer rows.Close()
 
-	var templates []EmailTemplate
 	existingTypes := make(map[string]bool)
-
-	for rows.Next() {
-		var template EmailTemplate
-		err := rows.Scan(
-			&template.ID,
-			&template.TemplateType,
-			&template.Subject,
-			&template.HTMLBody,
-			&template.TextBody,
-			&template.IsCustom,
-			&template.CreatedAt,
-			&template.UpdatedAt,
-		)
-		if err != nil {
-			log.Error().Err(err).Msg("Failed to scan email template")
-			continue
+	for _, template := range templates {
+		if template.Locale == "" {
+			existingTypes[template.TemplateType] = true
 		}
-		templates = append(templates, template)
-		existingTypes[template.TemplateType] = true
 	}
 
-	// Add default templates for types that don't exist in the database
+	// Add default templates for types that don't have a default variant in the database
 	for templateType, defaultTemplate := range defaultTemplates {
 		if !existingTypes[templateType] {
 			templates = append(templates, defaultTemplate)
@@ -215,283 +315,291 @@ func (h *EmailTemplateHandler) ListTemplates(c fiber.Ctx) error {
 }
 
 // GetTemplate returns a specific email template by type
-// GET /api/v1/admin/email/templates/:type
+// GET /api/v1/admin/email/templates/:type?locale=de
 func (h *EmailTemplateHandler) GetTemplate(c fiber.Ctx) error {
-	ctx := context.Background()
 	templateType := c.Params("type")
-
-	// Validate template type
-	if _, exists := defaultTemplates[templateType]; !exists {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid template type",
-		})
+	locale, ok, err := validateTemplateRequest(c, c.Query("locale"))
+	if !ok {
+		return err
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
-	}
-
-	var template EmailTemplate
-	err := h.db.QueryRow(ctx, `
-		SELECT id, template_type, subject, html_body, text_body, is_custom, created_at, updated_at
-		FROM dashboard.email_templates
-		WHERE template_type = $1
-	`, templateType).Scan(
-		&template.ID,
-		&template.TemplateType,
-		&template.Subject,
-		&template.HTMLBody,
-		&template.TextBody,
-		&template.IsCustom,
-		&template.CreatedAt,
-		&template.UpdatedAt,
-	)
+		return SendInternalError(c, "Database connection not initialized")
+	}
+
+	template, err := h.store.Get(c.RequestCtx(), templateType, locale)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			// Return default template
-			defaultTemplate, exists := defaultTemplates[templateType]
-			if !exists {
-				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-					"error": "Template not found",
-				})
-			}
-			return c.JSON(defaultTemplate)
+		log.Error().Err(err).Str("type", templateType).Str("locale", locale).Msg("Failed to get email template")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve email template", ErrCodeOperationFailed)
+	}
+	if template == nil {
+		if locale != "" {
+			return SendNotFound(c, "Template not found for locale")
 		}
-		log.Error().Err(err).Str("type", templateType).Msg("Failed to get email template")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve email template",
-		})
+		// Return default template
+		return c.JSON(defaultTemplate(templateType, ""))
 	}
 
 	return c.JSON(template)
 }
 
-// UpdateTemplate updates or creates an email template
-// PUT /api/v1/admin/email/templates/:type
+// UpdateTemplate saves a new version of an email template
+// PUT /api/v1/admin/email/templates/:type?locale=de
 func (h *EmailTemplateHandler) UpdateTemplate(c fiber.Ctx) error {
-	ctx := context.Background()
 	templateType := c.Params("type")
-
-	// Validate template type
-	if _, exists := defaultTemplates[templateType]; !exists {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid template type",
-		})
+	locale, ok, err := validateTemplateRequest(c, c.Query("locale"))
+	if !ok {
+		return err
 	}
 
 	var req UpdateTemplateRequest
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse update template request")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate required fields
 	if req.Subject == "" || req.HTMLBody == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Subject and HTML body are required",
-		})
+		return SendBadRequest(c, "Subject and HTML body are required", ErrCodeMissingField)
+	}
+
+	template := &EmailTemplate{
+		TemplateType: templateType,
+		Locale:       locale,
+		Subject:      req.Subject,
+		HTMLBody:     req.HTMLBody,
+		TextBody:     req.TextBody,
+	}
+	if err := template.Validate(); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
-	}
-
-	// Insert or update template
-	var templateID uuid.UUID
-	err := h.db.QueryRow(ctx, `
-		INSERT INTO dashboard.email_templates (template_type, subject, html_body, text_body, is_custom)
-		VALUES ($1, $2, $3, $4, true)
-		ON CONFLICT (template_type) DO UPDATE
-		SET subject = EXCLUDED.subject,
-		    html_body = EXCLUDED.html_body,
-		    text_body = EXCLUDED.text_body,
-		    is_custom = true,
-		    updated_at = NOW()
-		RETURNING id
-	`, templateType, req.Subject, req.HTMLBody, req.TextBody).Scan(&templateID)
+		return SendInternalError(c, "Database connection not initialized")
+	}
+
+	saved, err := h.store.Save(c.RequestCtx(), template, getUserIDFromContext(c))
 	if err != nil {
-		log.Error().Err(err).Str("type", templateType).Msg("Failed to update email template")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update email template",
-		})
+		log.Error().Err(err).Str("type", templateType).Str("locale", locale).Msg("Failed to update email template")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to update email template", ErrCodeOperationFailed)
 	}
 
-	log.Info().Str("type", templateType).Str("id", templateID.String()).Msg("Email template updated")
+	log.Info().Str("type", templateType).Str("locale", locale).Int("version", saved.Version).Msg("Email template updated")
 
-	// Fetch and return the updated template
-	return h.GetTemplate(c)
+	return c.JSON(saved)
 }
 
-// ResetTemplate resets an email template to its default
-// POST /api/v1/admin/email/templates/:type/reset
+// ResetTemplate removes a customized email template. The default variant falls back to the
+// built-in template, a locale variant to the default variant. Saved versions are kept.
+// POST /api/v1/admin/email/templates/:type/reset?locale=de
 func (h *EmailTemplateHandler) ResetTemplate(c fiber.Ctx) error {
-	ctx := context.Background()
 	templateType := c.Params("type")
+	locale, ok, err := validateTemplateRequest(c, c.Query("locale"))
+	if !ok {
+		return err
+	}
 
-	// Validate template type
-	defaultTemplate, exists := defaultTemplates[templateType]
-	if !exists {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid template type",
-		})
+	// Check if database connection is available
+	if h.db == nil {
+		return SendInternalError(c, "Database connection not initialized")
+	}
+
+	if err := h.store.Delete(c.RequestCtx(), templateType, locale); err != nil {
+		log.Error().Err(err).Str("type", templateType).Str("locale", locale).Msg("Failed to reset email template")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to reset email template", ErrCodeOperationFailed)
+	}
+
+	log.Info().Str("type", templateType).Str("locale", locale).Msg("Email template reset to default")
+
+	return c.JSON(defaultTemplate(templateType, locale))
+}
+
+// ListTemplateVersions returns the saved versions of an email template, newest first
+// GET /api/v1/admin/email/templates/:type/versions?locale=de
+func (h *EmailTemplateHandler) ListTemplateVersions(c fiber.Ctx) error {
+	templateType := c.Params("type")
+	locale, ok, err := validateTemplateRequest(c, c.Query("locale"))
+	if !ok {
+		return err
+	}
+
+	// Check if database connection is available
+	if h.db == nil {
+		return SendInternalError(c, "Database connection not initialized")
+	}
+
+	versions, err := h.store.Versions(c.RequestCtx(), templateType, locale)
+	if err != nil {
+		log.Error().Err(err).Str("type", templateType).Str("locale", locale).Msg("Failed to list email template versions")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve email template versions", ErrCodeOperationFailed)
+	}
+
+	return c.JSON(fiber.Map{"versions": versions, "count": len(versions)})
+}
+
+// RestoreTemplateVersion saves an earlier version of an email template as its newest version
+// POST /api/v1/admin/email/templates/:type/versions/:version/restore?locale=de
+func (h *EmailTemplateHandler) RestoreTemplateVersion(c fiber.Ctx) error {
+	templateType := c.Params("type")
+	locale, ok, err := validateTemplateRequest(c, c.Query("locale"))
+	if !ok {
+		return err
+	}
+
+	version, err := strconv.Atoi(c.Params("version"))
+	if err != nil || version < 1 {
+		return SendBadRequest(c, "Invalid version", ErrCodeInvalidInput)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
-	// Delete custom template to fall back to default
-	_, err := h.db.Exec(ctx, `
-		DELETE FROM dashboard.email_templates
-		WHERE template_type = $1
-	`, templateType)
+	restored, err := h.store.Restore(c.RequestCtx(), templateType, locale, version, getUserIDFromContext(c))
 	if err != nil {
-		log.Error().Err(err).Str("type", templateType).Msg("Failed to reset email template")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to reset email template",
-		})
+		log.Error().Err(err).Str("type", templateType).Str("locale", locale).Int("version", version).Msg("Failed to restore email template version")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to restore email template version", ErrCodeOperationFailed)
+	}
+	if restored == nil {
+		return SendNotFound(c, "Template version not found")
 	}
 
-	log.Info().Str("type", templateType).Msg("Email template reset to default")
+	log.Info().Str("type", templateType).Str("locale", locale).Int("restored_version", version).Int("version", restored.Version).Msg("Email template version restored")
 
-	return c.JSON(defaultTemplate)
+	return c.JSON(restored)
 }
 
-// TestTemplate sends a test email using the specified template
+// PreviewTemplate renders an email template with sample data. A draft in the request is
+// rendered as is, otherwise the saved template that best matches the locale.
+// POST /api/v1/admin/email/templates/:type/preview
+func (h *EmailTemplateHandler) PreviewTemplate(c fiber.Ctx) error {
+	templateType := c.Params("type")
+
+	var req PreviewTemplateRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&req); err != nil {
+			return SendInvalidBody(c)
+		}
+	}
+
+	locale, ok, err := validateTemplateRequest(c, req.Locale)
+	if !ok {
+		return err
+	}
+
+	template := &EmailTemplate{
+		TemplateType: templateType,
+		Locale:       locale,
+		Subject:      req.Subject,
+		HTMLBody:     req.HTMLBody,
+		TextBody:     req.TextBody,
+	}
+	if req.Subject == "" && req.HTMLBody == "" {
+		// Check if database connection is available
+		if h.db == nil {
+			return SendInternalError(c, "Database connection not initialized")
+		}
+		if template, err = h.resolveTemplate(c, templateType, locale); err != nil {
+			log.Error().Err(err).Str("type", templateType).Str("locale", locale).Msg("Failed to get email template")
+			return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to get email template", ErrCodeOperationFailed)
+		}
+	}
+
+	data := email.SampleTemplateData(templateType, locale)
+	for key, value := range req.Data {
+		data[key] = value
+	}
+
+	rendered, err := template.Render(data)
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	return c.JSON(fiber.Map{
+		"template_type": templateType,
+		"locale":        template.Locale,
+		"subject":       rendered.Subject,
+		"html_body":     rendered.HTMLBody,
+		"text_body":     rendered.TextBody,
+		"variables":     email.TemplateVariables(templateType),
+	})
+}
+
+// TestTemplate sends a test email using the saved template that best matches the locale
 // POST /api/v1/admin/email/templates/:type/test
 func (h *EmailTemplateHandler) TestTemplate(c fiber.Ctx) error {
 	templateType := c.Params("type")
 
 	// Validate template type
 	if _, exists := defaultTemplates[templateType]; !exists {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid template type",
-		})
+		return SendBadRequest(c, "Invalid template type", ErrCodeInvalidInput)
 	}
 
 	var req TestEmailRequest
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse test email request")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
+	}
+
+	if strings.TrimSpace(req.RecipientEmail) == "" {
+		return SendBadRequest(c, "Recipient email is required", ErrCodeMissingField)
 	}
 
-	if req.RecipientEmail == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Recipient email is required",
-		})
+	locale, ok, err := validateTemplateRequest(c, req.Locale)
+	if !ok {
+		return err
 	}
 
 	// Check if email service is available first (more specific error for this endpoint)
 	if h.emailService == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Email service not configured",
-		})
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "Email service not configured", ErrCodeServiceUnavailable)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
-	ctx := context.Background()
+	ctx := c.RequestCtx()
 
 	// Get template (custom or default)
-	var emailTemplate EmailTemplate
-	err := h.db.QueryRow(ctx, `
-		SELECT id, template_type, subject, html_body, text_body, is_custom, created_at, updated_at
-		FROM dashboard.email_templates
-		WHERE template_type = $1
-	`, templateType).Scan(
-		&emailTemplate.ID,
-		&emailTemplate.TemplateType,
-		&emailTemplate.Subject,
-		&emailTemplate.HTMLBody,
-		&emailTemplate.TextBody,
-		&emailTemplate.IsCustom,
-		&emailTemplate.CreatedAt,
-		&emailTemplate.UpdatedAt,
-	)
+	template, err := h.resolveTemplate(c, templateType, locale)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			// Use default template
-			emailTemplate = defaultTemplates[templateType]
-		} else {
-			log.Error().Err(err).Str("type", templateType).Msg("Failed to get email template")
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to get email template",
-			})
-		}
-	}
-
-	// Create test data for rendering
-	testData := map[string]string{
-		"AppName":     "Test Application",
-		"Link":        "https://example.com/test-link",
-		"Token":       "test-token-12345",
-		"MagicLink":   "https://example.com/magic-link/test-token",
-		"ResetLink":   "https://example.com/reset/test-token",
-		"VerifyLink":  "https://example.com/verify/test-token",
-		"InviteLink":  "https://example.com/invite/test-token",
-		"InviterName": "Test Admin",
-		"Expiry":      "15 minutes",
+		log.Error().Err(err).Str("type", templateType).Msg("Failed to get email template")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to get email template", ErrCodeOperationFailed)
 	}
 
 	// Render template with test data
-	renderedSubject := renderTemplateString(emailTemplate.Subject, testData)
-	renderedBody := renderTemplateString(emailTemplate.HTMLBody, testData)
+	rendered, err := template.Render(email.SampleTemplateData(templateType, locale))
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
 
 	// Send test email
-	if err := h.emailService.Send(ctx, req.RecipientEmail, renderedSubject, renderedBody); err != nil {
+	if err := email.SendRendered(ctx, h.emailService, req.RecipientEmail, rendered); err != nil {
 		log.Error().Err(err).
 			Str("type", templateType).
 			Str("recipient", req.RecipientEmail).
 			Msg("Failed to send test email")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to send test email: " + err.Error(),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to send test email: "+err.Error(), ErrCodeOperationFailed)
 	}
 
 	log.Info().
 		Str("type", templateType).
+		Str("locale", template.Locale).
 		Str("recipient", req.RecipientEmail).
 		Msg("Test email sent successfully")
 
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Test email sent successfully",
+		"locale":  template.Locale,
 	})
 }
 
-// renderTemplateString renders a template string with the given data
-func renderTemplateString(templateStr string, data map[string]string) string {
-	tmpl, err := template.New("email").Parse(templateStr)
-	if err != nil {
-		return templateStr // Return original if parsing fails
-	}
-
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return templateStr // Return original if execution fails
-	}
-
-	return buf.String()
-}
-
 // Helper function to create string pointers
 func stringPtr(s string) *string {
 	return &s
@@ -639,12 +639,106 @@ func TestTestTemplate_Validation(t *testing.T) {
 	})
 }
 
+// =============================================================================
+// PreviewTemplate Handler Tests
+// =============================================================================
+
+func TestPreviewTemplate(t *testing.T) {
+	preview := func(t *testing.T, path, body string) (int, map[string]interface{}) {
+		app := fiber.New()
+		handler := NewEmailTemplateHandler(nil, nil)
+		app.Post("/templates/:type/preview", handler.PreviewTemplate)
+
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		var result map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		return resp.StatusCode, result
+	}
+
+	t.Run("renders a draft with sample data", func(t *testing.T) {
+		status, result := preview(t, "/templates/otp/preview", `{
+			"locale": "de_DE",
+			"subject": "Dein {{.AppName}} Code",
+			"html_body": "<p>{{.Code}} ({{.Locale}})</p>",
+			"text_body": "Code: {{.Code}}",
+			"data": {"AppName": "Acme"}
+		}`)
+
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, "de-de", result["locale"])
+		assert.Equal(t, "Dein Acme Code", result["subject"])
+		assert.Equal(t, "<p>123456 (de-de)</p>", result["html_body"])
+		assert.Equal(t, "Code: 123456", result["text_body"])
+		assert.Contains(t, result["variables"], "Purpose")
+	})
+
+	t.Run("invalid draft", func(t *testing.T) {
+		status, result := preview(t, "/templates/magic_link/preview", `{"subject": "Hi", "html_body": "{{.MagicLink"}`)
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		assert.Contains(t, result["error"], "invalid html_body")
+	})
+
+	t.Run("invalid locale", func(t *testing.T) {
+		status, result := preview(t, "/templates/magic_link/preview", `{"locale": "../en", "subject": "Hi", "html_body": "<p>Hi</p>"}`)
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		assert.Contains(t, result["error"], "locale")
+	})
+
+	t.Run("invalid template type", func(t *testing.T) {
+		status, _ := preview(t, "/templates/welcome/preview", `{"subject": "Hi", "html_body": "<p>Hi</p>"}`)
+		assert.Equal(t, fiber.StatusBadRequest, status)
+	})
+
+	t.Run("saved template requires database", func(t *testing.T) {
+		status, _ := preview(t, "/templates/magic_link/preview", `{"locale": "de"}`)
+		assert.Equal(t, fiber.StatusInternalServerError, status)
+	})
+}
+
+func TestUpdateTemplate_InvalidTemplateSyntax(t *testing.T) {
+	app := fiber.New()
+	handler := NewEmailTemplateHandler(nil, nil)
+	app.Put("/templates/:type", handler.UpdateTemplate)
+
+	body := `{"subject":"{{.AppName","html_body":"<p>Hi</p>"}`
+	req := httptest.NewRequest(http.MethodPut, "/templates/magic_link?locale=fr", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestRestoreTemplateVersion_Validation(t *testing.T) {
+	for _, path := range []string{
+		"/templates/magic_link/versions/abc/restore",
+		"/templates/magic_link/versions/0/restore",
+		"/templates/magic_link/versions/1/restore?locale=en_",
+		"/templates/welcome/versions/1/restore",
+	} {
+		app := fiber.New()
+		handler := NewEmailTemplateHandler(nil, nil)
+		app.Post("/templates/:type/versions/:version/restore", handler.RestoreTemplateVersion)
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, path, nil))
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, path)
+	}
+}
+
 // =============================================================================
 // Template Type Constants Tests
 // =============================================================================
 
 func TestTemplateTypes(t *testing.T) {
-	validTypes := []string{"magic_link", "email_verification", "password_reset"}
+	validTypes := []string{"magic_link", "email_verification", "password_reset", "invitation", "otp"}
 	invalidTypes := []string{"invalid", "custom", "unknown", "invite", "welcome"}
 
 	t.Run("valid template types exist in defaultTemplates", func(t *testing.T) {
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/ai"
+	"github.com/rs/zerolog/log"
+)
+
+// BatchEmbedResponse represents the response from the batch embeddings API
+type BatchEmbedResponse struct {
+	Embeddings [][]float32      `json:"embeddings"`
+	Model      string           `json:"model"`
+	Dimensions int              `json:"dimensions"`
+	Batches    int              `json:"batches"`
+	Usage      BatchEmbedUsage  `json:"usage"`
+	Quota      *EmbedQuotaUsage `json:"quota,omitempty"`
+}
+
+// BatchEmbedUsage represents metered token usage for a batch embeddings request
+type BatchEmbedUsage struct {
+	PromptTokens int  `json:"prompt_tokens"`
+	TotalTokens  int  `json:"total_tokens"`
+	Estimated    bool `json:"estimated,omitempty"`
+}
+
+// EmbedQuotaUsage reports the caller's daily embedding quota after the request
+type EmbedQuotaUsage struct {
+	DailyLimit int       `json:"daily_limit"`
+	Used       int       `json:"used"`
+	ResetsAt   time.Time `json:"resets_at"`
+}
+
+// HandleBatchEmbed handles POST /api/v1/ai/embeddings
+// Embeds arbitrary texts with the configured providers in batches, enforcing the
+// per-user daily token quota and metering usage in ai.embedding_usage.
+func (h *VectorHandler) HandleBatchEmbed(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+	start := time.Now()
+
+	var req EmbedRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+
+	var texts []string
+	if req.Text != "" {
+		texts = append(texts, req.Text)
+	}
+	texts = append(texts, req.Texts...)
+
+	if len(texts) == 0 {
+		return SendBadRequest(c, "No text provided for embedding", ErrCodeInvalidInput)
+	}
+
+	maxInputs := h.config.EmbeddingMaxInputs
+	if maxInputs <= 0 {
+		maxInputs = ai.DefaultEmbeddingMaxInputs
+	}
+	if len(texts) > maxInputs {
+		return SendErrorWithCode(c, fiber.StatusRequestEntityTooLarge, fmt.Sprintf("Too many texts: %d (maximum %d per request)", len(texts), maxInputs), ErrCodePayloadTooLarge)
+	}
+
+	// Resolve the embedding service (provider selection is admin-only, as for /vector/embed)
+	embeddingService := h.vectorManager.GetEmbeddingService()
+	providerName := inferProviderType(h.config)
+	if req.Provider != "" {
+		role, _ := c.Locals("user_role").(string)
+		if role != "admin" && role != "dashboard_admin" && role != "service_role" {
+			return SendForbidden(c, "Provider selection requires admin privileges", ErrCodeAdminRequired)
+		}
+
+		var err error
+		embeddingService, err = h.vectorManager.GetEmbeddingServiceForProvider(ctx, req.Provider)
+		if err != nil {
+			return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+		}
+		providerName = req.Provider
+	}
+
+	if embeddingService == nil {
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "Embedding service not configured", ErrCodeServiceUnavailable)
+	}
+
+	storage := h.vectorManager.aiStorage
+	userID, _ := c.Locals("user_id").(string)
+
+	// Enforce the per-user daily token quota before calling the provider
+	var quota *EmbedQuotaUsage
+	if limit := h.config.EmbeddingDailyTokenQuota; limit > 0 && userID != "" && storage != nil {
+		dayStart := time.Now().UTC().Truncate(24 * time.Hour)
+		used, err := storage.GetEmbeddingTokensUsedSince(ctx, userID, dayStart)
+		if err != nil {
+			log.Error().Err(err).Str("user_id", userID).Msg("Failed to check embedding quota")
+			return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to check embedding quota", ErrCodeOperationFailed)
+		}
+
+		quota = &EmbedQuotaUsage{DailyLimit: limit, Used: used, ResetsAt: dayStart.Add(24 * time.Hour)}
+		if used+ai.EstimateEmbeddingTokens(texts) > limit {
+			c.Set("Retry-After", strconv.Itoa(int(time.Until(quota.ResetsAt).Seconds())+1))
+			return SendErrorWithDetails(c, fiber.StatusTooManyRequests, "Daily embedding token quota exceeded", ErrCodeRateLimited, "", "", fiber.Map{
+				"quota": quota,
+			})
+		}
+	}
+
+	result, err := ai.EmbedInBatches(ctx, embeddingService, texts, req.Model, ai.EmbeddingBatchOptionsFromConfig(h.config))
+	if err != nil {
+		log.Error().Err(err).Int("texts", len(texts)).Msg("Failed to generate batch embeddings")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to generate embeddings: "+err.Error(), ErrCodeOperationFailed)
+	}
+
+	// Meter usage under the same records used for quota enforcement
+	if storage != nil {
+		durationMs := int(time.Since(start).Milliseconds())
+		record := &ai.EmbeddingUsageRecord{
+			Source:          "api",
+			Model:           result.Model,
+			InputCount:      len(texts),
+			BatchCount:      result.Batches,
+			PromptTokens:    result.Usage.PromptTokens,
+			TotalTokens:     result.Usage.TotalTokens,
+			TokensEstimated: result.TokensEstimated,
+			DurationMs:      &durationMs,
+		}
+		if userID != "" {
+			record.UserID = &userID
+		}
+		if keyID, ok := c.Locals("client_key_id").(string); ok && keyID != "" {
+			record.ClientKeyID = &keyID
+		}
+		if providerName != "" {
+			record.Provider = &providerName
+		}
+		if err := storage.RecordEmbeddingUsage(ctx, record); err != nil {
+			log.Warn().Err(err).Msg("Failed to record embedding usage")
+		}
+	}
+
+	if quota != nil {
+		quota.Used += result.Usage.TotalTokens
+	}
+
+	return c.JSON(BatchEmbedResponse{
+		Embeddings: result.Embeddings,
+		Model:      result.Model,
+		Dimensions: result.Dimensions,
+		Batches:    result.Batches,
+		Usage: BatchEmbedUsage{
+			PromptTokens: result.Usage.PromptTokens,
+			TotalTokens:  result.Usage.TotalTokens,
+			Estimated:    result.TokensEstimated,
+		},
+		Quota: quota,
+	})
+}
+
+// HandleEmbeddingUsage handles GET /api/v1/admin/ai/embeddings/usage
+// Returns embeddings API usage per day and model for cost reporting.
+func (h *VectorHandler) HandleEmbeddingUsage(c fiber.Ctx) error {
+	storage := h.vectorManager.aiStorage
+	if storage == nil {
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "AI storage not available", ErrCodeServiceUnavailable)
+	}
+
+	days := fiber.Query[int](c, "days", 30)
+	if days <= 0 || days > 365 {
+		days = 30
+	}
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+
+	var userID *string
+	if v := c.Query("user_id"); v != "" {
+		userID = &v
+	}
+
+	summary, err := storage.GetEmbeddingUsageSummary(c.RequestCtx(), userID, since)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get embedding usage")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to get embedding usage", ErrCodeOperationFailed)
+	}
+
+	return c.JSON(fiber.Map{
+		"usage": summary,
+		"days":  days,
+		"since": since,
+	})
+}
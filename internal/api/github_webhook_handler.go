@@ -110,19 +110,13 @@ type GitHubInstallation struct {
 // HandleWebhook handles incoming GitHub webhook requests
 func (h *GitHubWebhookHandler) HandleWebhook(c fiber.Ctx) error {
 	if !h.config.Enabled {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error":   "branching_disabled",
-			"message": "Database branching is not enabled",
-		})
+		return SendErrorWithDetails(c, fiber.StatusServiceUnavailable, "branching_disabled", "BRANCHING_DISABLED", "Database branching is not enabled", "", nil)
 	}
 
 	// Get event type from header
 	eventType := c.Get("X-GitHub-Event")
 	if eventType == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "missing_event",
-			"message": "Missing X-GitHub-Event header",
-		})
+		return SendErrorWithDetails(c, fiber.StatusBadRequest, "missing_event", "MISSING_EVENT", "Missing X-GitHub-Event header", "", nil)
 	}
 
 	// Get delivery ID for logging
@@ -136,18 +130,12 @@ func (h *GitHubWebhookHandler) HandleWebhook(c fiber.Ctx) error {
 	// Parse the payload to get repository info for signature verification
 	var payload GitHubWebhookPayload
 	if err := json.Unmarshal(c.Body(), &payload); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "invalid_payload",
-			"message": "Failed to parse webhook payload: " + err.Error(),
-		})
+		return SendErrorWithDetails(c, fiber.StatusBadRequest, "invalid_payload", "INVALID_PAYLOAD", "Failed to parse webhook payload: "+err.Error(), "", nil)
 	}
 
 	// Get repository full name
 	if payload.Repository == nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "missing_repository",
-			"message": "Missing repository in webhook payload",
-		})
+		return SendErrorWithDetails(c, fiber.StatusBadRequest, "missing_repository", "MISSING_REPOSITORY", "Missing repository in webhook payload", "", nil)
 	}
 
 	repoFullName := payload.Repository.FullName
@@ -159,10 +147,7 @@ func (h *GitHubWebhookHandler) HandleWebhook(c fiber.Ctx) error {
 			Str("repository", repoFullName).
 			Str("delivery_id", deliveryID).
 			Msg("Webhook signature verification failed")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error":   "invalid_signature",
-			"message": "Webhook signature verification failed",
-		})
+		return SendErrorWithDetails(c, fiber.StatusUnauthorized, "invalid_signature", "INVALID_SIGNATURE", "Webhook signature verification failed", "", nil)
 	}
 
 	// Handle different event types
@@ -253,10 +238,7 @@ func computeHMACSHA256(data []byte, key string) string {
 // handlePullRequestEvent handles pull request events
 func (h *GitHubWebhookHandler) handlePullRequestEvent(c fiber.Ctx, payload *GitHubWebhookPayload) error {
 	if payload.PullRequest == nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "missing_pull_request",
-			"message": "Missing pull_request in payload",
-		})
+		return SendErrorWithDetails(c, fiber.StatusBadRequest, "missing_pull_request", "MISSING_PULL_REQUEST", "Missing pull_request in payload", "", nil)
 	}
 
 	pr := payload.PullRequest
@@ -272,10 +254,7 @@ func (h *GitHubWebhookHandler) handlePullRequestEvent(c fiber.Ctx, payload *GitH
 	ghConfig, err := h.manager.GetStorage().GetGitHubConfig(c.RequestCtx(), repo)
 	if err != nil && !errors.Is(err, branching.ErrGitHubConfigNotFound) {
 		log.Error().Err(err).Str("repository", repo).Msg("Failed to get GitHub config")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "config_error",
-			"message": "Failed to get GitHub configuration",
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "config_error", "CONFIG_ERROR", "Failed to get GitHub configuration", "", nil)
 	}
 
 	// Use default settings if no config
@@ -339,16 +318,10 @@ func (h *GitHubWebhookHandler) createBranchForPR(c fiber.Ctx, repo string, pr *G
 		}
 
 		if errors.Is(err, branching.ErrMaxBranchesReached) {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":   "max_branches_reached",
-				"message": "Maximum number of branches has been reached",
-			})
+			return SendErrorWithDetails(c, fiber.StatusForbidden, "max_branches_reached", "MAX_BRANCHES_REACHED", "Maximum number of branches has been reached", "", nil)
 		}
 
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "create_failed",
-			"message": "Failed to create branch: " + err.Error(),
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "create_failed", ErrCodeOperationFailed, "Failed to create branch: "+err.Error(), "", nil)
 	}
 
 	log.Info().
@@ -389,10 +362,7 @@ func (h *GitHubWebhookHandler) deleteBranchForPR(c fiber.Ctx, repo string, pr *G
 			Str("repository", repo).
 			Int("pr_number", pr.Number).
 			Msg("Failed to find branch for PR")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "find_failed",
-			"message": "Failed to find branch: " + err.Error(),
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "find_failed", ErrCodeOperationFailed, "Failed to find branch: "+err.Error(), "", nil)
 	}
 
 	// Close the connection pool first
@@ -405,10 +375,7 @@ func (h *GitHubWebhookHandler) deleteBranchForPR(c fiber.Ctx, repo string, pr *G
 			Int("pr_number", pr.Number).
 			Str("branch_id", branch.ID.String()).
 			Msg("Failed to delete branch for PR")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "delete_failed",
-			"message": "Failed to delete branch: " + err.Error(),
-		})
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, "delete_failed", ErrCodeOperationFailed, "Failed to delete branch: "+err.Error(), "", nil)
 	}
 
 	log.Info().
@@ -446,10 +413,7 @@ func (h *GitHubWebhookHandler) handlePingEvent(c fiber.Ctx, payload *GitHubWebho
 // handleIssueEvent handles issue events (opened, labeled, closed, etc.)
 func (h *GitHubWebhookHandler) handleIssueEvent(c fiber.Ctx, payload *GitHubWebhookPayload) error {
 	if payload.Issue == nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "missing_issue",
-			"message": "Missing issue in payload",
-		})
+		return SendErrorWithDetails(c, fiber.StatusBadRequest, "missing_issue", "MISSING_ISSUE", "Missing issue in payload", "", nil)
 	}
 
 	issue := payload.Issue
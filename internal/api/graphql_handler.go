@@ -24,6 +24,7 @@ type GraphQLHandler struct {
 	schemaGenerator *GraphQLSchemaGenerator
 	db              *database.Connection
 	config          *config.GraphQLConfig
+	apiConfig       *config.APIConfig
 	resolverFactory *GraphQLResolverFactory
 }
 
@@ -54,8 +55,9 @@ type GraphQLErrorLocation struct {
 	Column int `json:"column"`
 }
 
-// NewGraphQLHandler creates a new GraphQL handler
-func NewGraphQLHandler(db *database.Connection, schemaCache *database.SchemaCache, cfg *config.GraphQLConfig) *GraphQLHandler {
+// NewGraphQLHandler creates a new GraphQL handler. apiCfg supplies the read-only schemas and
+// tables that mutations honor like the REST API does.
+func NewGraphQLHandler(db *database.Connection, schemaCache *database.SchemaCache, cfg *config.GraphQLConfig, apiCfg *config.APIConfig) *GraphQLHandler {
 	// Create resolver factory
 	resolverFactory := NewGraphQLResolverFactory(db.Pool(), schemaCache)
 
@@ -67,6 +69,7 @@ func NewGraphQLHandler(db *database.Connection, schemaCache *database.SchemaCach
 		schemaGenerator: schemaGenerator,
 		db:              db,
 		config:          cfg,
+		apiConfig:       apiCfg,
 		resolverFactory: resolverFactory,
 	}
 }
@@ -177,6 +180,12 @@ func (h *GraphQLHandler) HandleGraphQL(c fiber.Ctx) error {
 
 	// Set up RLS context if user is authenticated
 	ctx = h.setupRLSContext(c, ctx)
+	if restrictions, ok := c.Locals("client_key_restrictions").(auth.ClientKeyRestrictions); ok {
+		ctx = context.WithValue(ctx, GraphQLClientKeyRestrictionsContextKey, restrictions)
+	}
+	if role, _ := c.Locals("user_role").(string); role != "service_role" && h.apiConfig != nil {
+		ctx = context.WithValue(ctx, GraphQLReadOnlyContextKey, h.apiConfig)
+	}
 
 	// Execute the query
 	result := graphql.Do(graphql.Params{
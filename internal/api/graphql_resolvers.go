@@ -9,6 +9,8 @@ import (
 	"github.com/graphql-go/graphql"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/nimbleflux/fluxbase/internal/config"
 	"github.com/nimbleflux/fluxbase/internal/database"
 	"github.com/rs/zerolog/log"
 )
@@ -33,8 +35,40 @@ type graphqlContextKey string
 const (
 	// GraphQLRLSContextKey is used to store RLS context in the request context
 	GraphQLRLSContextKey graphqlContextKey = "graphql_rls_context"
+
+	// GraphQLClientKeyRestrictionsContextKey is used to store the restrictions of the client key
+	// that authenticated the request
+	GraphQLClientKeyRestrictionsContextKey graphqlContextKey = "graphql_client_key_restrictions"
+
+	// GraphQLReadOnlyContextKey is used to store the API config whose read-only tables apply to
+	// the request. It is not set for the service role, which may write to them.
+	GraphQLReadOnlyContextKey graphqlContextKey = "graphql_read_only"
 )
 
+// checkTableAccess returns an error when the request's client key is restricted to tables that
+// do not include schema.table, mirroring the check the REST API makes in resolveTable
+func checkTableAccess(ctx context.Context, schema, table string, write bool) error {
+	restrictions, ok := ctx.Value(GraphQLClientKeyRestrictionsContextKey).(auth.ClientKeyRestrictions)
+	if !ok || restrictions.AllowsTable(schema, table, write) {
+		return nil
+	}
+	access := "read"
+	if write {
+		access = "write"
+	}
+	return fmt.Errorf("client key is not allowed to %s table '%s.%s'", access, schema, table)
+}
+
+// checkReadOnly returns an error when schema.table is configured as read-only for the request,
+// mirroring the check the REST API makes in readOnlyReason
+func checkReadOnly(ctx context.Context, schema, table string) error {
+	cfg, ok := ctx.Value(GraphQLReadOnlyContextKey).(*config.APIConfig)
+	if !ok || !isReadOnlyTable(cfg, schema, table) {
+		return nil
+	}
+	return fmt.Errorf("table '%s.%s' is read-only through the API", schema, table)
+}
+
 // RLSContext contains information needed for Row Level Security
 type RLSContext struct {
 	UserID string
@@ -202,42 +236,59 @@ func setGraphQLRLSContext(ctx context.Context, tx pgx.Tx, rlsCtx *RLSContext) er
 // makeCollectionResolver creates a resolver for querying a collection of records
 func (g *GraphQLSchemaGenerator) makeCollectionResolver(table database.TableInfo) graphql.FieldResolveFn {
 	return func(p graphql.ResolveParams) (interface{}, error) {
+		if err := checkTableAccess(p.Context, table.Schema, table.Name, false); err != nil {
+			return nil, err
+		}
+
 		ctx := p.Context
 
 		// Build the query
 		qb := NewQueryBuilder(table.Schema, table.Name)
+		g.applyCollectionArgs(qb, table, p.Args, nil)
 
-		// Apply filters
-		if filter, ok := p.Args["filter"].(map[string]interface{}); ok && len(filter) > 0 {
-			filters := g.buildFiltersFromArgs(table, filter)
-			qb.WithFilters(filters)
-		}
+		// Build and execute query with RLS
+		sql, args := qb.BuildSelect()
+		return g.queryWithRLS(ctx, sql, args...)
+	}
+}
 
-		// Apply ordering
-		if orderBy, ok := p.Args["orderBy"].([]interface{}); ok && len(orderBy) > 0 {
-			orders := g.buildOrderFromArgs(table, orderBy)
-			qb.WithOrder(orders)
-		}
+// applyCollectionArgs applies the filter, orderBy, limit and offset arguments of a list
+// field to the query, ANDing the filter with the given base filters
+func (g *GraphQLSchemaGenerator) applyCollectionArgs(qb *QueryBuilder, table database.TableInfo, args map[string]interface{}, base []Filter) {
+	filters := base
 
-		// Apply limit
-		if limit, ok := p.Args["limit"].(int); ok {
-			qb.WithLimit(limit)
-		}
+	// Apply filters
+	if filter, ok := args["filter"].(map[string]interface{}); ok && len(filter) > 0 {
+		filters = append(filters, g.buildFiltersFromArgs(table, filter)...)
+	}
+	if len(filters) > 0 {
+		qb.WithFilters(filters)
+	}
 
-		// Apply offset
-		if offset, ok := p.Args["offset"].(int); ok {
-			qb.WithOffset(offset)
-		}
+	// Apply ordering
+	if orderBy, ok := args["orderBy"].([]interface{}); ok && len(orderBy) > 0 {
+		orders := g.buildOrderFromArgs(table, orderBy)
+		qb.WithOrder(orders)
+	}
 
-		// Build and execute query with RLS
-		sql, args := qb.BuildSelect()
-		return g.queryWithRLS(ctx, sql, args...)
+	// Apply limit
+	if limit, ok := args["limit"].(int); ok {
+		qb.WithLimit(limit)
+	}
+
+	// Apply offset
+	if offset, ok := args["offset"].(int); ok {
+		qb.WithOffset(offset)
 	}
 }
 
 // makeSingleResolver creates a resolver for querying a single record by primary key
 func (g *GraphQLSchemaGenerator) makeSingleResolver(table database.TableInfo) graphql.FieldResolveFn {
 	return func(p graphql.ResolveParams) (interface{}, error) {
+		if err := checkTableAccess(p.Context, table.Schema, table.Name, false); err != nil {
+			return nil, err
+		}
+
 		ctx := p.Context
 
 		// Build filters from primary key arguments
@@ -280,6 +331,13 @@ func (g *GraphQLSchemaGenerator) makeSingleResolver(table database.TableInfo) gr
 // makeInsertResolver creates a resolver for inserting a single record
 func (g *GraphQLSchemaGenerator) makeInsertResolver(table database.TableInfo) graphql.FieldResolveFn {
 	return func(p graphql.ResolveParams) (interface{}, error) {
+		if err := checkTableAccess(p.Context, table.Schema, table.Name, true); err != nil {
+			return nil, err
+		}
+		if err := checkReadOnly(p.Context, table.Schema, table.Name); err != nil {
+			return nil, err
+		}
+
 		ctx := p.Context
 
 		data, ok := p.Args["data"].(map[string]interface{})
@@ -311,6 +369,13 @@ func (g *GraphQLSchemaGenerator) makeInsertResolver(table database.TableInfo) gr
 // makeInsertManyResolver creates a resolver for inserting multiple records
 func (g *GraphQLSchemaGenerator) makeInsertManyResolver(table database.TableInfo) graphql.FieldResolveFn {
 	return func(p graphql.ResolveParams) (interface{}, error) {
+		if err := checkTableAccess(p.Context, table.Schema, table.Name, true); err != nil {
+			return nil, err
+		}
+		if err := checkReadOnly(p.Context, table.Schema, table.Name); err != nil {
+			return nil, err
+		}
+
 		ctx := p.Context
 
 		dataArr, ok := p.Args["data"].([]interface{})
@@ -383,6 +448,13 @@ func (g *GraphQLSchemaGenerator) makeInsertManyResolver(table database.TableInfo
 // makeUpdateResolver creates a resolver for updating a single record by primary key
 func (g *GraphQLSchemaGenerator) makeUpdateResolver(table database.TableInfo) graphql.FieldResolveFn {
 	return func(p graphql.ResolveParams) (interface{}, error) {
+		if err := checkTableAccess(p.Context, table.Schema, table.Name, true); err != nil {
+			return nil, err
+		}
+		if err := checkReadOnly(p.Context, table.Schema, table.Name); err != nil {
+			return nil, err
+		}
+
 		ctx := p.Context
 
 		data, ok := p.Args["data"].(map[string]interface{})
@@ -430,6 +502,13 @@ func (g *GraphQLSchemaGenerator) makeUpdateResolver(table database.TableInfo) gr
 // makeUpdateManyResolver creates a resolver for updating multiple records
 func (g *GraphQLSchemaGenerator) makeUpdateManyResolver(table database.TableInfo) graphql.FieldResolveFn {
 	return func(p graphql.ResolveParams) (interface{}, error) {
+		if err := checkTableAccess(p.Context, table.Schema, table.Name, true); err != nil {
+			return nil, err
+		}
+		if err := checkReadOnly(p.Context, table.Schema, table.Name); err != nil {
+			return nil, err
+		}
+
 		ctx := p.Context
 
 		data, ok := p.Args["data"].(map[string]interface{})
@@ -458,6 +537,13 @@ func (g *GraphQLSchemaGenerator) makeUpdateManyResolver(table database.TableInfo
 // makeDeleteResolver creates a resolver for deleting a single record by primary key
 func (g *GraphQLSchemaGenerator) makeDeleteResolver(table database.TableInfo) graphql.FieldResolveFn {
 	return func(p graphql.ResolveParams) (interface{}, error) {
+		if err := checkTableAccess(p.Context, table.Schema, table.Name, true); err != nil {
+			return nil, err
+		}
+		if err := checkReadOnly(p.Context, table.Schema, table.Name); err != nil {
+			return nil, err
+		}
+
 		ctx := p.Context
 
 		// Build filters from primary key arguments
@@ -498,6 +584,13 @@ func (g *GraphQLSchemaGenerator) makeDeleteResolver(table database.TableInfo) gr
 // makeDeleteManyResolver creates a resolver for deleting multiple records
 func (g *GraphQLSchemaGenerator) makeDeleteManyResolver(table database.TableInfo) graphql.FieldResolveFn {
 	return func(p graphql.ResolveParams) (interface{}, error) {
+		if err := checkTableAccess(p.Context, table.Schema, table.Name, true); err != nil {
+			return nil, err
+		}
+		if err := checkReadOnly(p.Context, table.Schema, table.Name); err != nil {
+			return nil, err
+		}
+
 		ctx := p.Context
 
 		filter, ok := p.Args["filter"].(map[string]interface{})
@@ -580,9 +673,13 @@ func (g *GraphQLSchemaGenerator) makeForeignKeyResolver(table database.TableInfo
 		}
 
 		ctx := p.Context
+		refSchema, refName := referencedTableName(table, fk)
+		if err := checkTableAccess(ctx, refSchema, refName, false); err != nil {
+			return nil, err
+		}
 
 		// Query the related table
-		qb := NewQueryBuilder(table.Schema, fk.ReferencedTable)
+		qb := NewQueryBuilder(refSchema, refName)
 		qb.WithFilters([]Filter{{
 			Column:   fk.ReferencedColumn,
 			Operator: OpEqual,
@@ -606,6 +703,41 @@ func (g *GraphQLSchemaGenerator) makeForeignKeyResolver(table database.TableInfo
 	}
 }
 
+// makeReverseRelationResolver creates a resolver for the records of another table whose
+// foreign key references the source record (one-to-many). Like the foreign key resolver it
+// queries with RLS, so a traversal only returns rows the caller could read directly.
+func (g *GraphQLSchemaGenerator) makeReverseRelationResolver(table database.TableInfo, fk database.ForeignKey) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		source, ok := p.Source.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+
+		// Get the referenced key value from the source record
+		keyValue := source[fk.ReferencedColumn]
+		if keyValue == nil {
+			return []map[string]interface{}{}, nil
+		}
+		if err := checkTableAccess(p.Context, table.Schema, table.Name, false); err != nil {
+			return nil, err
+		}
+
+		qb := NewQueryBuilder(table.Schema, table.Name)
+		g.applyCollectionArgs(qb, table, p.Args, []Filter{{
+			Column:   fk.ColumnName,
+			Operator: OpEqual,
+			Value:    keyValue,
+		}})
+
+		sql, args := qb.BuildSelect()
+		results, err := g.queryWithRLS(p.Context, sql, args...)
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %w", err)
+		}
+		return results, nil
+	}
+}
+
 // buildFiltersFromArgs converts GraphQL filter arguments to query filters
 func (g *GraphQLSchemaGenerator) buildFiltersFromArgs(table database.TableInfo, args map[string]interface{}) []Filter {
 	orGroupCounter := 0
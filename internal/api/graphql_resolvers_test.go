@@ -1,9 +1,15 @@
 package api
 
 import (
+	"context"
 	"testing"
 
+	"github.com/graphql-go/graphql"
+	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/database"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // =============================================================================
@@ -216,6 +222,95 @@ func TestGraphQLContextKeys(t *testing.T) {
 	})
 }
 
+// =============================================================================
+// Client Key Table Restriction Tests
+// =============================================================================
+
+func TestGraphQLResolvers_ClientKeyTableRestrictions(t *testing.T) {
+	g := NewGraphQLSchemaGenerator(nil, nil, false)
+	posts := database.TableInfo{Schema: "public", Name: "posts", PrimaryKey: []string{"id"}}
+	restricted := context.WithValue(context.Background(), GraphQLClientKeyRestrictionsContextKey, auth.ClientKeyRestrictions{
+		AllowedTables: map[string]string{"public.users": auth.TableAccessWrite, "public.posts": auth.TableAccessRead},
+	})
+
+	t.Run("rejects queries of tables outside the allowed list", func(t *testing.T) {
+		comments := database.TableInfo{Schema: "public", Name: "comments"}
+		_, err := g.makeCollectionResolver(comments)(graphql.ResolveParams{Context: restricted, Args: map[string]interface{}{}})
+		require.Error(t, err)
+		assert.Equal(t, "client key is not allowed to read table 'public.comments'", err.Error())
+	})
+
+	t.Run("rejects mutations of read-only tables", func(t *testing.T) {
+		_, err := g.makeInsertResolver(posts)(graphql.ResolveParams{Context: restricted, Args: map[string]interface{}{"data": map[string]interface{}{}}})
+		require.Error(t, err)
+		assert.Equal(t, "client key is not allowed to write table 'public.posts'", err.Error())
+
+		_, err = g.makeDeleteManyResolver(posts)(graphql.ResolveParams{Context: restricted, Args: map[string]interface{}{}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not allowed to write")
+	})
+
+	t.Run("rejects traversals to tables outside the allowed list", func(t *testing.T) {
+		fk := database.ForeignKey{ColumnName: "author_id", ReferencedTable: "auth.users", ReferencedColumn: "id"}
+		_, err := g.makeForeignKeyResolver(posts, fk)(graphql.ResolveParams{
+			Context: restricted,
+			Source:  map[string]interface{}{"author_id": 1},
+		})
+		require.Error(t, err)
+		assert.Equal(t, "client key is not allowed to read table 'auth.users'", err.Error())
+	})
+
+	t.Run("allows tables without restrictions in context", func(t *testing.T) {
+		assert.NoError(t, checkTableAccess(context.Background(), "public", "comments", true))
+		assert.NoError(t, checkTableAccess(restricted, "public", "users", true))
+		assert.NoError(t, checkTableAccess(restricted, "public", "posts", false))
+	})
+}
+
+// =============================================================================
+// Read-Only Table Tests
+// =============================================================================
+
+func TestGraphQLResolvers_ReadOnlyTables(t *testing.T) {
+	g := NewGraphQLSchemaGenerator(nil, nil, false)
+	invoices := database.TableInfo{Schema: "public", Name: "invoices", PrimaryKey: []string{"id"}}
+	readOnly := context.WithValue(context.Background(), GraphQLReadOnlyContextKey, &config.APIConfig{
+		ReadOnlySchemas: []string{"reporting"},
+		ReadOnlyTables:  []string{"invoices"},
+	})
+
+	t.Run("rejects every mutation of a read-only table", func(t *testing.T) {
+		resolvers := map[string]graphql.FieldResolveFn{
+			"insert":     g.makeInsertResolver(invoices),
+			"insertMany": g.makeInsertManyResolver(invoices),
+			"update":     g.makeUpdateResolver(invoices),
+			"updateMany": g.makeUpdateManyResolver(invoices),
+			"delete":     g.makeDeleteResolver(invoices),
+			"deleteMany": g.makeDeleteManyResolver(invoices),
+		}
+		for name, resolve := range resolvers {
+			t.Run(name, func(t *testing.T) {
+				_, err := resolve(graphql.ResolveParams{Context: readOnly, Args: map[string]interface{}{}})
+				require.Error(t, err)
+				assert.Equal(t, "table 'public.invoices' is read-only through the API", err.Error())
+			})
+		}
+	})
+
+	t.Run("rejects mutations of tables in read-only schemas", func(t *testing.T) {
+		daily := database.TableInfo{Schema: "reporting", Name: "daily", PrimaryKey: []string{"id"}}
+		_, err := g.makeInsertResolver(daily)(graphql.ResolveParams{Context: readOnly, Args: map[string]interface{}{"data": map[string]interface{}{}}})
+		require.Error(t, err)
+		assert.Equal(t, "table 'reporting.daily' is read-only through the API", err.Error())
+	})
+
+	t.Run("allows writes without read-only config in context", func(t *testing.T) {
+		// The handler leaves the config out of the context for the service role
+		assert.NoError(t, checkReadOnly(context.Background(), "public", "invoices"))
+		assert.NoError(t, checkReadOnly(readOnly, "public", "posts"))
+	})
+}
+
 // =============================================================================
 // Role Mapping Edge Cases
 // =============================================================================
@@ -108,6 +108,18 @@ func (g *GraphQLSchemaGenerator) regenerateSchema(ctx context.Context) (*graphql
 		}
 	}
 
+	schema, err := g.buildSchema(publicTables)
+	if err != nil {
+		return nil, err
+	}
+
+	g.schema = schema
+	return schema, nil
+}
+
+// buildSchema generates the GraphQL schema for the given tables.
+// The caller must hold g.mu.
+func (g *GraphQLSchemaGenerator) buildSchema(publicTables []database.TableInfo) (*graphql.Schema, error) {
 	// Generate object types for each table (first pass - create stubs)
 	for _, table := range publicTables {
 		typeName := g.tableToTypeName(table.Schema, table.Name)
@@ -118,25 +130,36 @@ func (g *GraphQLSchemaGenerator) regenerateSchema(ctx context.Context) (*graphql
 		})
 	}
 
-	// Second pass - populate fields (allows for circular references via foreign keys)
+	// Second pass - input, filter and order by types, which relation fields need
+	for _, table := range publicTables {
+		typeName := g.tableToTypeName(table.Schema, table.Name)
+		g.inputTypes[typeName+"Input"] = g.generateInputType(table)
+		g.filterTypes[typeName+"Filter"] = g.generateFilterType(table)
+		g.orderByTypes[typeName+"OrderBy"] = g.generateOrderByType(table)
+	}
+
+	// Index the foreign keys by the schema-qualified table they reference, for one-to-many
+	// relation fields
+	referencedBy := make(map[string][]reverseRelation)
+	for _, table := range publicTables {
+		for _, fk := range table.ForeignKeys {
+			refSchema, refName := referencedTableName(table, fk)
+			key := refSchema + "." + refName
+			referencedBy[key] = append(referencedBy[key], reverseRelation{table: table, fk: fk})
+		}
+	}
+
+	// Third pass - populate fields (allows for circular references via foreign keys)
 	for _, table := range publicTables {
 		typeName := g.tableToTypeName(table.Schema, table.Name)
 		objType := g.objectTypes[typeName]
 
 		// Generate fields for this table
 		fields := g.generateTableFields(table)
+		g.addReverseRelationFields(fields, referencedBy[table.Schema+"."+table.Name])
 		for name, field := range fields {
 			objType.AddFieldConfig(name, field)
 		}
-
-		// Generate input type
-		g.inputTypes[typeName+"Input"] = g.generateInputType(table)
-
-		// Generate filter type
-		g.filterTypes[typeName+"Filter"] = g.generateFilterType(table)
-
-		// Generate order by type
-		g.orderByTypes[typeName+"OrderBy"] = g.generateOrderByType(table)
 	}
 
 	// Build query fields
@@ -162,25 +185,8 @@ func (g *GraphQLSchemaGenerator) regenerateSchema(ctx context.Context) (*graphql
 		queryFields[collectionName] = &graphql.Field{
 			Type:        graphql.NewList(objType),
 			Description: fmt.Sprintf("Query %s records", table.Name),
-			Args: graphql.FieldConfigArgument{
-				"filter": &graphql.ArgumentConfig{
-					Type:        filterType,
-					Description: "Filter conditions",
-				},
-				"orderBy": &graphql.ArgumentConfig{
-					Type:        graphql.NewList(orderByType),
-					Description: "Sort order",
-				},
-				"limit": &graphql.ArgumentConfig{
-					Type:        graphql.Int,
-					Description: "Maximum number of records to return",
-				},
-				"offset": &graphql.ArgumentConfig{
-					Type:        graphql.Int,
-					Description: "Number of records to skip",
-				},
-			},
-			Resolve: g.makeCollectionResolver(table),
+			Args:        collectionArgs(filterType, orderByType),
+			Resolve:     g.makeCollectionResolver(table),
 		}
 
 		// Single record query by primary key (e.g., user, post)
@@ -318,10 +324,85 @@ func (g *GraphQLSchemaGenerator) regenerateSchema(ctx context.Context) (*graphql
 		return nil, fmt.Errorf("failed to create GraphQL schema: %w", err)
 	}
 
-	g.schema = &schema
 	return &schema, nil
 }
 
+// collectionArgs returns the filter, sort and pagination arguments of list fields
+func collectionArgs(filterType, orderByType *graphql.InputObject) graphql.FieldConfigArgument {
+	return graphql.FieldConfigArgument{
+		"filter": &graphql.ArgumentConfig{
+			Type:        filterType,
+			Description: "Filter conditions",
+		},
+		"orderBy": &graphql.ArgumentConfig{
+			Type:        graphql.NewList(orderByType),
+			Description: "Sort order",
+		},
+		"limit": &graphql.ArgumentConfig{
+			Type:        graphql.Int,
+			Description: "Maximum number of records to return",
+		},
+		"offset": &graphql.ArgumentConfig{
+			Type:        graphql.Int,
+			Description: "Number of records to skip",
+		},
+	}
+}
+
+// referencedTableName splits the "schema.table" a foreign key references. Bare names, which
+// the schema inspector does not return, are taken to be in the schema of the referencing table.
+func referencedTableName(table database.TableInfo, fk database.ForeignKey) (schema, name string) {
+	if refSchema, refName, ok := strings.Cut(fk.ReferencedTable, "."); ok {
+		return refSchema, refName
+	}
+	return table.Schema, fk.ReferencedTable
+}
+
+// reverseRelation is a foreign key on another table that references the table being generated
+type reverseRelation struct {
+	table database.TableInfo // the referencing table
+	fk    database.ForeignKey
+}
+
+// addReverseRelationFields adds one-to-many relation fields for the foreign keys that
+// reference a table, e.g. "posts" on User for posts.author_id. The field is named after the
+// referencing table, qualified by the foreign key column ("postsByEditor") when that table
+// references this one more than once or the name is already taken.
+func (g *GraphQLSchemaGenerator) addReverseRelationFields(fields graphql.Fields, relations []reverseRelation) {
+	perTable := make(map[string]int)
+	for _, rel := range relations {
+		perTable[rel.table.Name]++
+	}
+
+	for _, rel := range relations {
+		typeName := g.tableToTypeName(rel.table.Schema, rel.table.Name)
+		objType, ok := g.objectTypes[typeName]
+		if !ok {
+			continue
+		}
+
+		name := g.tableToCollectionName(rel.table.Name)
+		if _, taken := fields[name]; taken || perTable[rel.table.Name] > 1 {
+			name += "By" + toPascalCase(strings.TrimSuffix(rel.fk.ColumnName, "_id"))
+		}
+		if _, taken := fields[name]; taken {
+			log.Debug().
+				Str("table", rel.table.Name).
+				Str("column", rel.fk.ColumnName).
+				Str("field", name).
+				Msg("Skipping GraphQL relation field that collides with an existing field")
+			continue
+		}
+
+		fields[name] = &graphql.Field{
+			Type:        graphql.NewList(objType),
+			Description: fmt.Sprintf("Related %s records via %s.%s", rel.table.Name, rel.table.Name, rel.fk.ColumnName),
+			Args:        collectionArgs(g.filterTypes[typeName+"Filter"], g.orderByTypes[typeName+"OrderBy"]),
+			Resolve:     g.makeReverseRelationResolver(rel.table, rel.fk),
+		}
+	}
+}
+
 // generateTableFields generates GraphQL fields for a table's columns
 func (g *GraphQLSchemaGenerator) generateTableFields(table database.TableInfo) graphql.Fields {
 	fields := graphql.Fields{}
@@ -345,13 +426,14 @@ func (g *GraphQLSchemaGenerator) generateTableFields(table database.TableInfo) g
 	// Add foreign key relationship fields
 	for _, fk := range table.ForeignKeys {
 		// Find the referenced table's type
-		refTypeName := g.tableToTypeName(table.Schema, fk.ReferencedTable)
+		refSchema, refName := referencedTableName(table, fk)
+		refTypeName := g.tableToTypeName(refSchema, refName)
 		if refType, ok := g.objectTypes[refTypeName]; ok {
 			// Create a field for the relationship (singular, e.g., "author" for author_id)
 			relFieldName := g.fkToRelationName(fk.ColumnName)
 			fields[relFieldName] = &graphql.Field{
 				Type:        refType,
-				Description: fmt.Sprintf("Related %s via %s", fk.ReferencedTable, fk.ColumnName),
+				Description: fmt.Sprintf("Related %s via %s", refName, fk.ColumnName),
 				Resolve:     g.makeForeignKeyResolver(table, fk),
 			}
 		}
@@ -3,8 +3,10 @@ package api
 import (
 	"testing"
 
+	"github.com/graphql-go/graphql"
 	"github.com/nimbleflux/fluxbase/internal/database"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // =============================================================================
@@ -471,3 +473,122 @@ func TestFkToRelationName(t *testing.T) {
 		})
 	}
 }
+
+// =============================================================================
+// Relation Field Tests
+// =============================================================================
+
+func relationTestTables() []database.TableInfo {
+	return []database.TableInfo{
+		{
+			Schema:     "public",
+			Name:       "users",
+			Type:       "table",
+			PrimaryKey: []string{"id"},
+			Columns: []database.ColumnInfo{
+				{Name: "id", DataType: "uuid"},
+				{Name: "email", DataType: "text"},
+			},
+		},
+		{
+			Schema:     "public",
+			Name:       "posts",
+			Type:       "table",
+			PrimaryKey: []string{"id"},
+			Columns: []database.ColumnInfo{
+				{Name: "id", DataType: "uuid"},
+				{Name: "title", DataType: "text"},
+				{Name: "author_id", DataType: "uuid"},
+				{Name: "editor_id", DataType: "uuid", IsNullable: true},
+			},
+			ForeignKeys: []database.ForeignKey{
+				{Name: "posts_author_id_fkey", ColumnName: "author_id", ReferencedTable: "public.users", ReferencedColumn: "id"},
+				{Name: "posts_editor_id_fkey", ColumnName: "editor_id", ReferencedTable: "public.users", ReferencedColumn: "id"},
+			},
+		},
+		{
+			Schema:     "public",
+			Name:       "comments",
+			Type:       "table",
+			PrimaryKey: []string{"id"},
+			Columns: []database.ColumnInfo{
+				{Name: "id", DataType: "uuid"},
+				{Name: "post_id", DataType: "uuid"},
+				{Name: "body", DataType: "text"},
+			},
+			ForeignKeys: []database.ForeignKey{
+				{Name: "comments_post_id_fkey", ColumnName: "post_id", ReferencedTable: "public.posts", ReferencedColumn: "id"},
+			},
+		},
+	}
+}
+
+func TestBuildSchema_RelationFields(t *testing.T) {
+	gen := NewGraphQLSchemaGenerator(nil, nil, true)
+	schema, err := gen.buildSchema(relationTestTables())
+	require.NoError(t, err)
+
+	t.Run("many-to-one fields", func(t *testing.T) {
+		fields := gen.objectTypes["Comments"].Fields()
+		require.Contains(t, fields, "post")
+		assert.Equal(t, "Posts", fields["post"].Type.Name())
+	})
+
+	t.Run("one-to-many field is named after the referencing table", func(t *testing.T) {
+		fields := gen.objectTypes["Posts"].Fields()
+		require.Contains(t, fields, "comments")
+		assert.Equal(t, "[Comments]", fields["comments"].Type.String())
+
+		var argNames []string
+		for _, arg := range fields["comments"].Args {
+			argNames = append(argNames, arg.Name())
+		}
+		assert.ElementsMatch(t, []string{"filter", "orderBy", "limit", "offset"}, argNames)
+	})
+
+	t.Run("one-to-many fields are qualified when a table references another twice", func(t *testing.T) {
+		fields := gen.objectTypes["Users"].Fields()
+		assert.Contains(t, fields, "postsByAuthor")
+		assert.Contains(t, fields, "postsByEditor")
+		assert.NotContains(t, fields, "posts")
+	})
+
+	t.Run("collection queries keep their arguments", func(t *testing.T) {
+		field := schema.QueryType().Fields()["posts"]
+		require.NotNil(t, field)
+		assert.Len(t, field.Args, 4)
+	})
+}
+
+func TestAddReverseRelationFields_SkipsCollisions(t *testing.T) {
+	gen := NewGraphQLSchemaGenerator(nil, nil, true)
+	_, err := gen.buildSchema(relationTestTables())
+	require.NoError(t, err)
+
+	existing := &graphql.Field{Type: graphql.String}
+	fields := graphql.Fields{
+		"comments":       existing,
+		"commentsByPost": existing,
+	}
+	gen.addReverseRelationFields(fields, []reverseRelation{{
+		table: relationTestTables()[2],
+		fk:    relationTestTables()[2].ForeignKeys[0],
+	}})
+
+	assert.Len(t, fields, 2)
+	assert.Same(t, existing, fields["comments"])
+}
+
+func TestMakeReverseRelationResolver_NullKey(t *testing.T) {
+	gen := NewGraphQLSchemaGenerator(nil, nil, true)
+	table := relationTestTables()[2]
+	resolve := gen.makeReverseRelationResolver(table, table.ForeignKeys[0])
+
+	result, err := resolve(graphql.ResolveParams{Source: map[string]interface{}{"id": nil}})
+	require.NoError(t, err)
+	assert.Empty(t, result)
+
+	result, err = resolve(graphql.ResolveParams{Source: "not a row"})
+	require.NoError(t, err)
+	assert.Nil(t, result)
+}
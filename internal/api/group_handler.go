@@ -0,0 +1,230 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/auth"
+)
+
+// GroupHandler handles admin endpoints for user groups
+type GroupHandler struct {
+	groupService *auth.GroupService
+}
+
+// NewGroupHandler creates a new group handler
+func NewGroupHandler(groupService *auth.GroupService) *GroupHandler {
+	return &GroupHandler{groupService: groupService}
+}
+
+// CreateGroupRequest represents a request to create a group
+type CreateGroupRequest struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+}
+
+// UpdateGroupRequest represents a request to update a group. Omitted fields are left unchanged.
+type UpdateGroupRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+// AddGroupMembersRequest represents a request to add users to a group
+type AddGroupMembersRequest struct {
+	UserIDs []string `json:"user_ids"`
+}
+
+// groupErrorStatus maps group errors to HTTP status codes
+func groupErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, auth.ErrGroupNotFound),
+		errors.Is(err, auth.ErrGroupUserNotFound),
+		errors.Is(err, auth.ErrGroupMemberNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, auth.ErrGroupNameTaken):
+		return fiber.StatusConflict
+	case errors.Is(err, auth.ErrGroupNameRequired):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+func groupError(c fiber.Ctx, err error) error {
+	status := groupErrorStatus(err)
+	message := err.Error()
+	if status == fiber.StatusInternalServerError {
+		message = "Failed to process group request"
+	}
+	return SendError(c, status, message)
+}
+
+// validIDParams returns false and writes a 400 response if any of the params is not a UUID
+func validIDParams(c fiber.Ctx, params ...string) bool {
+	for _, param := range params {
+		if _, err := uuid.Parse(c.Params(param)); err != nil {
+			_ = SendBadRequest(c, "Invalid "+param, ErrCodeInvalidInput)
+			return false
+		}
+	}
+	return true
+}
+
+// currentUserID returns the authenticated user ID, or nil for service role requests
+func currentUserID(c fiber.Ctx) *string {
+	if userID, ok := c.Locals("user_id").(string); ok && userID != "" {
+		if _, err := uuid.Parse(userID); err == nil {
+			return &userID
+		}
+	}
+	return nil
+}
+
+// ListGroups lists all groups
+// GET /api/v1/admin/groups
+func (h *GroupHandler) ListGroups(c fiber.Ctx) error {
+	groups, err := h.groupService.ListGroups(c.RequestCtx())
+	if err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"groups": groups,
+		"count":  len(groups),
+	})
+}
+
+// CreateGroup creates a group
+// POST /api/v1/admin/groups
+func (h *GroupHandler) CreateGroup(c fiber.Ctx) error {
+	var req CreateGroupRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+
+	group, err := h.groupService.CreateGroup(c.RequestCtx(), req.Name, req.Description, currentUserID(c))
+	if err != nil {
+		return groupError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(group)
+}
+
+// GetGroup returns a group
+// GET /api/v1/admin/groups/:id
+func (h *GroupHandler) GetGroup(c fiber.Ctx) error {
+	if !validIDParams(c, "id") {
+		return nil
+	}
+
+	group, err := h.groupService.GetGroup(c.RequestCtx(), c.Params("id"))
+	if err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(group)
+}
+
+// UpdateGroup renames a group or changes its description
+// PATCH /api/v1/admin/groups/:id
+func (h *GroupHandler) UpdateGroup(c fiber.Ctx) error {
+	if !validIDParams(c, "id") {
+		return nil
+	}
+
+	var req UpdateGroupRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+
+	group, err := h.groupService.UpdateGroup(c.RequestCtx(), c.Params("id"), req.Name, req.Description)
+	if err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(group)
+}
+
+// DeleteGroup deletes a group and revokes everything granted to it
+// DELETE /api/v1/admin/groups/:id
+func (h *GroupHandler) DeleteGroup(c fiber.Ctx) error {
+	if !validIDParams(c, "id") {
+		return nil
+	}
+
+	if err := h.groupService.DeleteGroup(c.RequestCtx(), c.Params("id")); err != nil {
+		return groupError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListGroupMembers lists the members of a group
+// GET /api/v1/admin/groups/:id/members
+func (h *GroupHandler) ListGroupMembers(c fiber.Ctx) error {
+	if !validIDParams(c, "id") {
+		return nil
+	}
+
+	members, err := h.groupService.ListMembers(c.RequestCtx(), c.Params("id"))
+	if err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"members": members,
+		"count":   len(members),
+	})
+}
+
+// AddGroupMembers adds users to a group
+// POST /api/v1/admin/groups/:id/members
+func (h *GroupHandler) AddGroupMembers(c fiber.Ctx) error {
+	if !validIDParams(c, "id") {
+		return nil
+	}
+
+	var req AddGroupMembersRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+	if len(req.UserIDs) == 0 {
+		return SendBadRequest(c, "user_ids is required", ErrCodeMissingField)
+	}
+	for _, userID := range req.UserIDs {
+		if _, err := uuid.Parse(userID); err != nil {
+			return SendBadRequest(c, "Invalid user ID: "+userID, ErrCodeInvalidInput)
+		}
+	}
+
+	added, err := h.groupService.AddMembers(c.RequestCtx(), c.Params("id"), req.UserIDs, currentUserID(c))
+	if err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(fiber.Map{"added": added})
+}
+
+// RemoveGroupMember removes a user from a group
+// DELETE /api/v1/admin/groups/:id/members/:user_id
+func (h *GroupHandler) RemoveGroupMember(c fiber.Ctx) error {
+	if !validIDParams(c, "id", "user_id") {
+		return nil
+	}
+
+	if err := h.groupService.RemoveMember(c.RequestCtx(), c.Params("id"), c.Params("user_id")); err != nil {
+		return groupError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListUserGroups lists the groups a user belongs to
+// GET /api/v1/admin/users/:id/groups
+func (h *GroupHandler) ListUserGroups(c fiber.Ctx) error {
+	if !validIDParams(c, "id") {
+		return nil
+	}
+
+	groups, err := h.groupService.ListUserGroups(c.RequestCtx(), c.Params("id"))
+	if err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"groups": groups,
+		"count":  len(groups),
+	})
+}
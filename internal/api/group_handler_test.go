@@ -0,0 +1,69 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupErrorStatus(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{auth.ErrGroupNotFound, fiber.StatusNotFound},
+		{auth.ErrGroupUserNotFound, fiber.StatusNotFound},
+		{auth.ErrGroupMemberNotFound, fiber.StatusNotFound},
+		{auth.ErrGroupNameTaken, fiber.StatusConflict},
+		{auth.ErrGroupNameRequired, fiber.StatusBadRequest},
+		{errors.New("connection refused"), fiber.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			assert.Equal(t, tt.status, groupErrorStatus(tt.err))
+		})
+	}
+}
+
+func TestGroupHandler_Validation(t *testing.T) {
+	// Requests are rejected before the group service is used
+	handler := NewGroupHandler(nil)
+	app := fiber.New()
+	app.Get("/groups/:id", handler.GetGroup)
+	app.Post("/groups/:id/members", handler.AddGroupMembers)
+	app.Delete("/groups/:id/members/:user_id", handler.RemoveGroupMember)
+
+	const groupID = "550e8400-e29b-41d4-a716-446655440000"
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"invalid group ID", http.MethodGet, "/groups/not-a-uuid", ""},
+		{"invalid member user ID", http.MethodDelete, "/groups/" + groupID + "/members/not-a-uuid", ""},
+		{"no users to add", http.MethodPost, "/groups/" + groupID + "/members", `{"user_ids":[]}`},
+		{"invalid user ID to add", http.MethodPost, "/groups/" + groupID + "/members", `{"user_ids":["not-a-uuid"]}`},
+		{"invalid body", http.MethodPost, "/groups/" + groupID + "/members", `{`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,666 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultAdvisorMinRows    = 1000
+	defaultAdvisorMinQueries = 10
+	maxAdvisorHotspots       = 10
+	maxAdvisorStatements     = 3
+	advisorStatementSample   = 200
+	indexMeasurementRuns     = 3
+	indexMeasurementTimeout  = "30s"
+)
+
+// IndexAdvisorHandler recommends indexes for user tables from table statistics,
+// pg_stat_statements and the columns REST queries filter and order by
+type IndexAdvisorHandler struct {
+	db       *database.Connection
+	ddl      *DDLHandler
+	patterns *queryPatternTracker
+}
+
+// NewIndexAdvisorHandler creates a new index advisor handler
+func NewIndexAdvisorHandler(db *database.Connection, ddl *DDLHandler, patterns *queryPatternTracker) *IndexAdvisorHandler {
+	return &IndexAdvisorHandler{db: db, ddl: ddl, patterns: patterns}
+}
+
+// TableScanStats are the scan counters of a table from pg_stat_user_tables
+type TableScanStats struct {
+	Schema        string `json:"schema"`
+	Table         string `json:"table"`
+	SeqScans      int64  `json:"seq_scans"`
+	SeqTuplesRead int64  `json:"seq_tuples_read"`
+	IndexScans    int64  `json:"index_scans"`
+	LiveRows      int64  `json:"live_rows"`
+}
+
+// StatementStat is a statement from pg_stat_statements
+type StatementStat struct {
+	Query       string  `json:"query"`
+	Calls       int64   `json:"calls"`
+	TotalTimeMs float64 `json:"total_time_ms"`
+	MeanTimeMs  float64 `json:"mean_time_ms"`
+	Rows        int64   `json:"rows"`
+}
+
+// IndexRecommendation is a missing index the advisor recommends
+type IndexRecommendation struct {
+	ID     string `json:"id"`
+	Schema string `json:"schema"`
+	Table  string `json:"table"`
+	// Index is the request body for POST /api/v1/admin/tables/:schema/:table/indexes
+	Index     CreateIndexRequest `json:"index"`
+	Statement string             `json:"statement"`
+	Reason    string             `json:"reason"`
+	// Patterns are the ways REST queries used the indexed column
+	Patterns    []QueryPatternKind `json:"patterns"`
+	RESTQueries int64              `json:"rest_queries"`
+	TableStats  TableScanStats     `json:"table_stats"`
+	// Statements are the slowest pg_stat_statements entries that mention the table and column
+	Statements []StatementStat `json:"statements,omitempty"`
+}
+
+// SeqScanHotspot is a large table that is read mostly by sequential scans,
+// for which REST query patterns did not point at a specific column
+type SeqScanHotspot struct {
+	TableScanStats
+	Statements []StatementStat `json:"statements,omitempty"`
+}
+
+// IndexAdvisorReport is the result of an index analysis
+type IndexAdvisorReport struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	// PatternsSince is when this instance started recording REST query patterns
+	PatternsSince       time.Time             `json:"patterns_since"`
+	StatementsAvailable bool                  `json:"statements_available"`
+	Recommendations     []IndexRecommendation `json:"recommendations"`
+	Hotspots            []SeqScanHotspot      `json:"hotspots"`
+}
+
+// indexAdvisorOptions are the thresholds a recommendation must pass
+type indexAdvisorOptions struct {
+	MinRows    int64
+	MinQueries int64
+}
+
+// existingIndex is an index of a user table
+type existingIndex struct {
+	Name          string
+	Method        string
+	Definition    string
+	LeadingColumn string // Empty for expression indexes
+}
+
+// indexAdvisorInput is everything the advisor analyzes
+type indexAdvisorInput struct {
+	Patterns    []QueryPattern
+	Stats       map[string]TableScanStats    // By schema.table
+	Indexes     map[string][]existingIndex   // By schema.table
+	ColumnTypes map[string]map[string]string // By schema.table, then column, to the udt name
+	Statements  []StatementStat              // Slowest first
+}
+
+func tableKey(schema, table string) string {
+	return schema + "." + table
+}
+
+// recommendedIndex decides which index serves a query pattern. ok is false if no index helps.
+func recommendedIndex(pattern QueryPattern, udt string) (req CreateIndexRequest, ok bool) {
+	isJSON := udt == "jsonb" || udt == "json"
+	isArray := strings.HasPrefix(udt, "_")
+
+	switch {
+	case pattern.Kind == QueryPatternContains:
+		// Containment is served by GIN. jsonb_path_ops is smaller and faster for @>.
+		if udt == "jsonb" {
+			return CreateIndexRequest{Columns: []string{pattern.Column}, Method: "gin", OpClass: "jsonb_path_ops"}, true
+		}
+		if isArray {
+			return CreateIndexRequest{Columns: []string{pattern.Column}, Method: "gin"}, true
+		}
+		return CreateIndexRequest{}, false
+	case len(pattern.Path) > 0:
+		// Filters on JSONB paths compare the extracted text, which needs an expression index
+		if !isJSON {
+			return CreateIndexRequest{}, false
+		}
+		column := pattern.Column
+		for i, key := range pattern.Path {
+			if i == len(pattern.Path)-1 {
+				column += "->>" + key
+			} else {
+				column += "->" + key
+			}
+		}
+		return CreateIndexRequest{Columns: []string{column}, Method: "btree"}, true
+	case isJSON || isArray:
+		return CreateIndexRequest{}, false
+	default:
+		return CreateIndexRequest{Columns: []string{pattern.Column}, Method: "btree"}, true
+	}
+}
+
+// normalizeIndexDefinition strips quoting, casts, parentheses and whitespace so expression
+// indexes can be compared with the expressions the advisor would create
+func normalizeIndexDefinition(def string) string {
+	replacer := strings.NewReplacer("::text", "", `"`, "", "(", "", ")", "", " ", "", "\t", "", "\n", "")
+	return strings.ToLower(replacer.Replace(def))
+}
+
+// isIndexCovered checks if an existing index already serves the recommended index
+func isIndexCovered(req CreateIndexRequest, indexes []existingIndex) bool {
+	column := req.Columns[0]
+	name, path := splitJSONPath(column)
+	var expr string
+	if len(path) > 0 {
+		if e, err := indexColumnExpression(column); err == nil {
+			expr = normalizeIndexDefinition(e)
+		}
+	}
+
+	for _, idx := range indexes {
+		if idx.Method != req.Method {
+			continue
+		}
+		if len(path) == 0 && idx.LeadingColumn == name {
+			return true
+		}
+		if expr != "" && idx.LeadingColumn == "" && strings.Contains(normalizeIndexDefinition(idx.Definition), expr) {
+			return true
+		}
+	}
+	return false
+}
+
+// statementsMentioning returns the statements that mention all the words
+func statementsMentioning(statements []StatementStat, words ...string) []StatementStat {
+	var matches []StatementStat
+	for _, stmt := range statements {
+		query := strings.ToLower(stmt.Query)
+		found := true
+		for _, word := range words {
+			if !strings.Contains(query, strings.ToLower(word)) {
+				found = false
+				break
+			}
+		}
+		if found {
+			matches = append(matches, stmt)
+			if len(matches) == maxAdvisorStatements {
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// buildIndexRecommendations turns query patterns and table statistics into index recommendations
+func buildIndexRecommendations(input indexAdvisorInput, opts indexAdvisorOptions) ([]IndexRecommendation, []SeqScanHotspot) {
+	byID := map[string]*IndexRecommendation{}
+	var order []string
+	recommendedTables := map[string]bool{}
+
+	for _, pattern := range input.Patterns {
+		key := tableKey(pattern.Schema, pattern.Table)
+		stats, ok := input.Stats[key]
+		if !ok || stats.LiveRows < opts.MinRows || stats.SeqScans == 0 {
+			continue
+		}
+		udt, ok := input.ColumnTypes[key][pattern.Column]
+		if !ok {
+			continue
+		}
+		req, ok := recommendedIndex(pattern, udt)
+		if !ok || isIndexCovered(req, input.Indexes[key]) {
+			continue
+		}
+
+		id := tableKey(pattern.Schema, pattern.Table) + ":" + req.Method + ":" + req.Columns[0]
+		rec, exists := byID[id]
+		if !exists {
+			rec = &IndexRecommendation{
+				ID:         id,
+				Schema:     pattern.Schema,
+				Table:      pattern.Table,
+				Index:      req,
+				TableStats: stats,
+			}
+			byID[id] = rec
+			order = append(order, id)
+		}
+		rec.RESTQueries += pattern.Count
+		rec.Patterns = append(rec.Patterns, pattern.Kind)
+	}
+
+	recommendations := make([]IndexRecommendation, 0, len(order))
+	for _, id := range order {
+		rec := byID[id]
+		if rec.RESTQueries < opts.MinQueries {
+			continue
+		}
+
+		statement, name, err := buildCreateIndexQuery(rec.Schema, rec.Table, rec.Index)
+		if err != nil {
+			// Columns that can't be indexed through the DDL API, e.g. reserved words
+			continue
+		}
+		rec.Index.Name = name
+		rec.Statement = statement
+
+		patterns := make([]string, len(rec.Patterns))
+		for i, p := range rec.Patterns {
+			patterns[i] = string(p)
+		}
+		rec.Reason = fmt.Sprintf("%d REST queries used %s for %s, and %s.%s (%d rows) had %d sequential scans reading %d rows",
+			rec.RESTQueries, rec.Index.Columns[0], strings.Join(patterns, ", "),
+			rec.Schema, rec.Table, rec.TableStats.LiveRows, rec.TableStats.SeqScans, rec.TableStats.SeqTuplesRead)
+
+		column, _ := splitJSONPath(rec.Index.Columns[0])
+		rec.Statements = statementsMentioning(input.Statements, rec.Table, column)
+		recommendedTables[tableKey(rec.Schema, rec.Table)] = true
+		recommendations = append(recommendations, *rec)
+	}
+
+	// Tables with the most rows to avoid reading first
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].RESTQueries*recommendations[i].TableStats.LiveRows >
+			recommendations[j].RESTQueries*recommendations[j].TableStats.LiveRows
+	})
+
+	hotspots := []SeqScanHotspot{}
+	for key, stats := range input.Stats {
+		if recommendedTables[key] || stats.LiveRows < opts.MinRows || stats.SeqScans <= stats.IndexScans {
+			continue
+		}
+		hotspots = append(hotspots, SeqScanHotspot{
+			TableScanStats: stats,
+			Statements:     statementsMentioning(input.Statements, stats.Table),
+		})
+	}
+	sort.Slice(hotspots, func(i, j int) bool {
+		if hotspots[i].SeqTuplesRead != hotspots[j].SeqTuplesRead {
+			return hotspots[i].SeqTuplesRead > hotspots[j].SeqTuplesRead
+		}
+		return tableKey(hotspots[i].Schema, hotspots[i].Table) < tableKey(hotspots[j].Schema, hotspots[j].Table)
+	})
+	if len(hotspots) > maxAdvisorHotspots {
+		hotspots = hotspots[:maxAdvisorHotspots]
+	}
+
+	return recommendations, hotspots
+}
+
+// loadTableStats reads the scan counters of all user tables
+func (h *IndexAdvisorHandler) loadTableStats(ctx context.Context) (map[string]TableScanStats, error) {
+	rows, err := h.db.Pool().Query(ctx, `
+		SELECT schemaname, relname, COALESCE(seq_scan, 0), COALESCE(seq_tup_read, 0),
+		       COALESCE(idx_scan, 0), COALESCE(n_live_tup, 0)
+		FROM pg_stat_user_tables
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table statistics: %w", err)
+	}
+	defer rows.Close()
+
+	stats := map[string]TableScanStats{}
+	for rows.Next() {
+		var s TableScanStats
+		if err := rows.Scan(&s.Schema, &s.Table, &s.SeqScans, &s.SeqTuplesRead, &s.IndexScans, &s.LiveRows); err != nil {
+			return nil, fmt.Errorf("failed to scan table statistics: %w", err)
+		}
+		stats[tableKey(s.Schema, s.Table)] = s
+	}
+	return stats, rows.Err()
+}
+
+// loadIndexes reads the indexes of all user tables
+func (h *IndexAdvisorHandler) loadIndexes(ctx context.Context) (map[string][]existingIndex, error) {
+	rows, err := h.db.Pool().Query(ctx, `
+		SELECT n.nspname, t.relname, i.relname, am.amname, pg_get_indexdef(i.oid), COALESCE(a.attname, '')
+		FROM pg_index x
+		JOIN pg_class i ON i.oid = x.indexrelid
+		JOIN pg_class t ON t.oid = x.indrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_am am ON am.oid = i.relam
+		LEFT JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = x.indkey[0] AND x.indkey[0] <> 0
+		WHERE n.nspname NOT IN ('pg_catalog', 'information_schema')
+		  AND n.nspname NOT LIKE 'pg_toast%'
+		  AND x.indisvalid
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexes: %w", err)
+	}
+	defer rows.Close()
+
+	indexes := map[string][]existingIndex{}
+	for rows.Next() {
+		var schema, table string
+		var idx existingIndex
+		if err := rows.Scan(&schema, &table, &idx.Name, &idx.Method, &idx.Definition, &idx.LeadingColumn); err != nil {
+			return nil, fmt.Errorf("failed to scan index: %w", err)
+		}
+		key := tableKey(schema, table)
+		indexes[key] = append(indexes[key], idx)
+	}
+	return indexes, rows.Err()
+}
+
+// loadColumnTypes reads the column types of the tables that appear in the query patterns
+func (h *IndexAdvisorHandler) loadColumnTypes(ctx context.Context, patterns []QueryPattern) (map[string]map[string]string, error) {
+	seen := map[string]bool{}
+	var tables []string
+	for _, p := range patterns {
+		key := tableKey(p.Schema, p.Table)
+		if !seen[key] {
+			seen[key] = true
+			tables = append(tables, key)
+		}
+	}
+	types := map[string]map[string]string{}
+	if len(tables) == 0 {
+		return types, nil
+	}
+
+	rows, err := h.db.Pool().Query(ctx, `
+		SELECT table_schema, table_name, column_name, udt_name
+		FROM information_schema.columns
+		WHERE table_schema || '.' || table_name = ANY($1)
+	`, tables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read column types: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schema, table, column, udt string
+		if err := rows.Scan(&schema, &table, &column, &udt); err != nil {
+			return nil, fmt.Errorf("failed to scan column type: %w", err)
+		}
+		key := tableKey(schema, table)
+		if types[key] == nil {
+			types[key] = map[string]string{}
+		}
+		types[key][column] = udt
+	}
+	return types, rows.Err()
+}
+
+// loadStatements reads the slowest statements from pg_stat_statements.
+// available is false if the extension is not installed or readable.
+func (h *IndexAdvisorHandler) loadStatements(ctx context.Context) (statements []StatementStat, available bool) {
+	var installed bool
+	if err := h.db.Pool().QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM pg_extension WHERE extname = 'pg_stat_statements')`).Scan(&installed); err != nil || !installed {
+		return nil, false
+	}
+
+	rows, err := h.db.Pool().Query(ctx, `
+		SELECT query, calls, total_exec_time, mean_exec_time, rows
+		FROM pg_stat_statements
+		WHERE dbid = (SELECT oid FROM pg_database WHERE datname = current_database())
+		  AND query ILIKE 'select%'
+		ORDER BY total_exec_time DESC
+		LIMIT $1
+	`, advisorStatementSample)
+	if err != nil {
+		log.Warn().Err(err).Msg("Index advisor could not read pg_stat_statements")
+		return nil, false
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s StatementStat
+		if err := rows.Scan(&s.Query, &s.Calls, &s.TotalTimeMs, &s.MeanTimeMs, &s.Rows); err != nil {
+			log.Warn().Err(err).Msg("Index advisor could not scan pg_stat_statements row")
+			return nil, false
+		}
+		statements = append(statements, s)
+	}
+	return statements, rows.Err() == nil
+}
+
+// analyze collects statistics and builds the advisor report
+func (h *IndexAdvisorHandler) analyze(ctx context.Context, opts indexAdvisorOptions) (*IndexAdvisorReport, error) {
+	patterns, since := h.patterns.Snapshot()
+
+	stats, err := h.loadTableStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := h.loadIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	columnTypes, err := h.loadColumnTypes(ctx, patterns)
+	if err != nil {
+		return nil, err
+	}
+	statements, available := h.loadStatements(ctx)
+
+	recommendations, hotspots := buildIndexRecommendations(indexAdvisorInput{
+		Patterns:    patterns,
+		Stats:       stats,
+		Indexes:     indexes,
+		ColumnTypes: columnTypes,
+		Statements:  statements,
+	}, opts)
+
+	return &IndexAdvisorReport{
+		GeneratedAt:         time.Now(),
+		PatternsSince:       since,
+		StatementsAvailable: available,
+		Recommendations:     recommendations,
+		Hotspots:            hotspots,
+	}, nil
+}
+
+// GetRecommendations analyzes user tables and recommends missing indexes
+// GET /api/v1/admin/index-advisor/recommendations
+func (h *IndexAdvisorHandler) GetRecommendations(c fiber.Ctx) error {
+	opts := indexAdvisorOptions{MinRows: defaultAdvisorMinRows, MinQueries: defaultAdvisorMinQueries}
+	for param, target := range map[string]*int64{"min_rows": &opts.MinRows, "min_queries": &opts.MinQueries} {
+		if raw := c.Query(param); raw != "" {
+			value, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || value < 0 {
+				return SendBadRequest(c, fmt.Sprintf("%s must be a non-negative integer", param), ErrCodeInvalidInput)
+			}
+			*target = value
+		}
+	}
+
+	report, err := h.analyze(c.RequestCtx(), opts)
+	if err != nil {
+		log.Error().Err(err).Msg("Index advisor analysis failed")
+		return SendOperationFailed(c, "analyze indexes")
+	}
+	return c.JSON(report)
+}
+
+// ResetPatterns clears the REST query patterns recorded by this instance
+// DELETE /api/v1/admin/index-advisor/patterns
+func (h *IndexAdvisorHandler) ResetPatterns(c fiber.Ctx) error {
+	h.patterns.Reset()
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ApplyIndexRequest is a request to create a recommended index and measure its effect
+type ApplyIndexRequest struct {
+	Schema string             `json:"schema"`
+	Table  string             `json:"table"`
+	Index  CreateIndexRequest `json:"index"`
+}
+
+// QueryLatency is the measured execution time of a sample query
+type QueryLatency struct {
+	MedianMs  float64   `json:"median_ms"`
+	RunsMs    []float64 `json:"runs_ms"`
+	UsesIndex bool      `json:"uses_index"`
+}
+
+// ApplyIndexResult reports the index that was created and the latency of a sample query before and after
+type ApplyIndexResult struct {
+	Index       string        `json:"index"`
+	Statement   string        `json:"statement"`
+	SampleQuery string        `json:"sample_query,omitempty"`
+	Before      *QueryLatency `json:"before,omitempty"`
+	After       *QueryLatency `json:"after,omitempty"`
+	// Speedup is the before/after ratio of the median latencies
+	Speedup          float64 `json:"speedup,omitempty"`
+	MeasurementError string  `json:"measurement_error,omitempty"`
+}
+
+// buildIndexSampleQuery builds a query that filters the table on the indexed expression with a
+// value sampled from the table itself, for measuring the index
+func buildIndexSampleQuery(schema, table string, req CreateIndexRequest) (string, error) {
+	if len(req.Columns) == 0 {
+		return "", fmt.Errorf("at least one column is required")
+	}
+	expr, err := indexColumnExpression(req.Columns[0])
+	if err != nil {
+		return "", err
+	}
+	from := quoteIdentifier(schema) + "." + quoteIdentifier(table)
+
+	op := "="
+	if strings.EqualFold(req.Method, "gin") {
+		op = "@>"
+	}
+	return fmt.Sprintf("SELECT * FROM %s WHERE %s %s (SELECT %s FROM %s WHERE %s IS NOT NULL LIMIT 1)",
+		from, expr, op, expr, from, expr), nil
+}
+
+// explainTiming extracts the execution time from EXPLAIN (ANALYZE, FORMAT JSON) output and
+// reports whether the plan used the named index
+func explainTiming(plan []byte, indexName string) (float64, bool, error) {
+	var result []struct {
+		ExecutionTime float64 `json:"Execution Time"`
+	}
+	if err := json.Unmarshal(plan, &result); err != nil || len(result) == 0 {
+		return 0, false, fmt.Errorf("unexpected EXPLAIN output")
+	}
+	usesIndex := strings.Contains(string(plan), fmt.Sprintf(`"Index Name": %q`, indexName))
+	return result[0].ExecutionTime, usesIndex, nil
+}
+
+// measureQuery runs a query under EXPLAIN ANALYZE a few times and returns the median latency
+func (h *IndexAdvisorHandler) measureQuery(ctx context.Context, query, indexName string) (*QueryLatency, error) {
+	latency := &QueryLatency{}
+	for i := 0; i < indexMeasurementRuns; i++ {
+		var plan []byte
+		err := pgx.BeginTxFunc(ctx, h.db.Pool(), pgx.TxOptions{AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, "SET LOCAL statement_timeout = '"+indexMeasurementTimeout+"'"); err != nil {
+				return err
+			}
+			return tx.QueryRow(ctx, "EXPLAIN (ANALYZE, FORMAT JSON) "+query).Scan(&plan)
+		})
+		if err != nil {
+			return nil, err
+		}
+		ms, usesIndex, err := explainTiming(plan, indexName)
+		if err != nil {
+			return nil, err
+		}
+		latency.RunsMs = append(latency.RunsMs, ms)
+		latency.UsesIndex = latency.UsesIndex || usesIndex
+	}
+
+	sorted := append([]float64(nil), latency.RunsMs...)
+	sort.Float64s(sorted)
+	latency.MedianMs = sorted[len(sorted)/2]
+	return latency, nil
+}
+
+// ApplyRecommendation creates a recommended index through the DDL API and measures the
+// latency of a sample query before and after
+// POST /api/v1/admin/index-advisor/apply
+func (h *IndexAdvisorHandler) ApplyRecommendation(c fiber.Ctx) error {
+	var req ApplyIndexRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+	if err := validateIdentifier(req.Schema, "schema"); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeValidationFailed)
+	}
+	if err := validateIdentifier(req.Table, "table"); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeValidationFailed)
+	}
+	statement, name, err := buildCreateIndexQuery(req.Schema, req.Table, req.Index)
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeValidationFailed)
+	}
+
+	ctx := c.RequestCtx()
+
+	exists, err := h.ddl.tableExists(ctx, req.Schema, req.Table)
+	if err != nil {
+		log.Error().Err(err).Str("table", req.Schema+"."+req.Table).Msg("Failed to check table existence")
+		return SendOperationFailed(c, "check table existence")
+	}
+	if !exists {
+		return SendNotFound(c, fmt.Sprintf("Table '%s.%s' does not exist", req.Schema, req.Table))
+	}
+	idxExists, err := h.ddl.indexExists(ctx, req.Schema, name)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to check index existence")
+		return SendOperationFailed(c, "check index existence")
+	}
+	if idxExists {
+		return SendConflict(c, fmt.Sprintf("Index '%s' already exists in schema '%s'", name, req.Schema), ErrCodeAlreadyExists)
+	}
+
+	result := ApplyIndexResult{Index: name, Statement: statement}
+
+	sample, err := buildIndexSampleQuery(req.Schema, req.Table, req.Index)
+	if err == nil {
+		result.SampleQuery = sample
+		result.Before, err = h.measureQuery(ctx, sample, name)
+	}
+	if err != nil {
+		result.MeasurementError = fmt.Sprintf("before: %v", err)
+	}
+
+	log.Info().Str("table", req.Schema+"."+req.Table).Str("index", name).Msg("Creating recommended index")
+	if err := h.ddl.createIndex(ctx, req.Schema, name, statement); err != nil {
+		log.Error().Err(err).Str("table", req.Schema+"."+req.Table).Str("index", name).Msg("Failed to create recommended index")
+		return SendInternalError(c, fmt.Sprintf("Failed to create index: %v", err))
+	}
+
+	// Refresh planner statistics so the new index is considered
+	err = h.db.ExecuteWithAdminRole(ctx, func(conn *pgx.Conn) error {
+		_, execErr := conn.Exec(ctx, fmt.Sprintf("ANALYZE %s.%s", quoteIdentifier(req.Schema), quoteIdentifier(req.Table)))
+		return execErr
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("table", req.Schema+"."+req.Table).Msg("Failed to analyze table after creating index")
+	}
+
+	if result.Before != nil {
+		result.After, err = h.measureQuery(ctx, sample, name)
+		if err != nil {
+			result.MeasurementError = fmt.Sprintf("after: %v", err)
+		} else if result.After.MedianMs > 0 {
+			result.Speedup = result.Before.MedianMs / result.After.MedianMs
+		}
+	}
+
+	log.Info().
+		Str("table", req.Schema+"."+req.Table).
+		Str("index", name).
+		Float64("speedup", result.Speedup).
+		Msg("Recommended index created")
+
+	return c.Status(fiber.StatusCreated).JSON(result)
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecommendedIndex(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern QueryPattern
+		udt     string
+		want    CreateIndexRequest
+		ok      bool
+	}{
+		{"equality on scalar", QueryPattern{Column: "status", Kind: QueryPatternEquality}, "text",
+			CreateIndexRequest{Columns: []string{"status"}, Method: "btree"}, true},
+		{"order on timestamp", QueryPattern{Column: "created_at", Kind: QueryPatternOrder}, "timestamptz",
+			CreateIndexRequest{Columns: []string{"created_at"}, Method: "btree"}, true},
+		{"jsonb containment", QueryPattern{Column: "data", Kind: QueryPatternContains}, "jsonb",
+			CreateIndexRequest{Columns: []string{"data"}, Method: "gin", OpClass: "jsonb_path_ops"}, true},
+		{"array containment", QueryPattern{Column: "tags", Kind: QueryPatternContains}, "_text",
+			CreateIndexRequest{Columns: []string{"tags"}, Method: "gin"}, true},
+		{"jsonb path", QueryPattern{Column: "data", Path: []string{"address", "city"}, Kind: QueryPatternEquality}, "jsonb",
+			CreateIndexRequest{Columns: []string{"data->address->>city"}, Method: "btree"}, true},
+		{"containment on scalar", QueryPattern{Column: "status", Kind: QueryPatternContains}, "text", CreateIndexRequest{}, false},
+		{"path on text column", QueryPattern{Column: "status", Path: []string{"x"}, Kind: QueryPatternEquality}, "text", CreateIndexRequest{}, false},
+		{"equality on whole jsonb", QueryPattern{Column: "data", Kind: QueryPatternEquality}, "jsonb", CreateIndexRequest{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := recommendedIndex(tt.pattern, tt.udt)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestIsIndexCovered(t *testing.T) {
+	indexes := []existingIndex{
+		{Name: "orders_pkey", Method: "btree", LeadingColumn: "id"},
+		{Name: "idx_orders_customer", Method: "btree", LeadingColumn: "customer_id"},
+		{Name: "idx_orders_city", Method: "btree", Definition: `CREATE INDEX idx_orders_city ON public.orders USING btree (((data -> 'address'::text) ->> 'city'::text))`},
+		{Name: "idx_orders_tags", Method: "gin", LeadingColumn: "tags"},
+	}
+
+	assert.True(t, isIndexCovered(CreateIndexRequest{Columns: []string{"customer_id"}, Method: "btree"}, indexes))
+	assert.True(t, isIndexCovered(CreateIndexRequest{Columns: []string{"data->address->>city"}, Method: "btree"}, indexes))
+	assert.True(t, isIndexCovered(CreateIndexRequest{Columns: []string{"tags"}, Method: "gin"}, indexes))
+
+	assert.False(t, isIndexCovered(CreateIndexRequest{Columns: []string{"status"}, Method: "btree"}, indexes))
+	assert.False(t, isIndexCovered(CreateIndexRequest{Columns: []string{"data->address->>zip"}, Method: "btree"}, indexes))
+	assert.False(t, isIndexCovered(CreateIndexRequest{Columns: []string{"customer_id"}, Method: "gin"}, indexes), "a btree index does not serve containment")
+}
+
+func TestBuildIndexRecommendations(t *testing.T) {
+	now := time.Now()
+	input := indexAdvisorInput{
+		Patterns: []QueryPattern{
+			{Schema: "public", Table: "orders", Column: "status", Kind: QueryPatternEquality, Count: 40, LastSeen: now},
+			{Schema: "public", Table: "orders", Column: "status", Kind: QueryPatternOrder, Count: 15, LastSeen: now},
+			{Schema: "public", Table: "orders", Column: "customer_id", Kind: QueryPatternEquality, Count: 500, LastSeen: now},
+			{Schema: "public", Table: "orders", Column: "data", Path: []string{"city"}, Kind: QueryPatternEquality, Count: 30, LastSeen: now},
+			{Schema: "public", Table: "orders", Column: "note", Kind: QueryPatternEquality, Count: 3, LastSeen: now},
+			{Schema: "public", Table: "tiny", Column: "name", Kind: QueryPatternEquality, Count: 1000, LastSeen: now},
+			{Schema: "public", Table: "events", Column: "payload", Kind: QueryPatternContains, Count: 80, LastSeen: now},
+		},
+		Stats: map[string]TableScanStats{
+			"public.orders": {Schema: "public", Table: "orders", SeqScans: 900, SeqTuplesRead: 9_000_000, IndexScans: 100, LiveRows: 10_000},
+			"public.tiny":   {Schema: "public", Table: "tiny", SeqScans: 5000, SeqTuplesRead: 50_000, LiveRows: 10},
+			"public.events": {Schema: "public", Table: "events", SeqScans: 50, SeqTuplesRead: 25_000_000, LiveRows: 500_000},
+			"public.logs":   {Schema: "public", Table: "logs", SeqScans: 300, SeqTuplesRead: 90_000_000, IndexScans: 2, LiveRows: 300_000},
+		},
+		Indexes: map[string][]existingIndex{
+			"public.orders": {{Name: "idx_orders_customer_id", Method: "btree", LeadingColumn: "customer_id"}},
+		},
+		ColumnTypes: map[string]map[string]string{
+			"public.orders": {"status": "text", "customer_id": "uuid", "data": "jsonb", "note": "text"},
+			"public.tiny":   {"name": "text"},
+			"public.events": {"payload": "jsonb"},
+		},
+		Statements: []StatementStat{
+			{Query: `SELECT * FROM "public"."orders" WHERE "status" = $1`, Calls: 40, MeanTimeMs: 120},
+			{Query: `SELECT * FROM "public"."logs" WHERE level = $1`, Calls: 10, MeanTimeMs: 900},
+		},
+	}
+
+	recs, hotspots := buildIndexRecommendations(input, indexAdvisorOptions{MinRows: 1000, MinQueries: 10})
+
+	ids := make([]string, len(recs))
+	for i, r := range recs {
+		ids[i] = r.ID
+	}
+	// Ordered by REST queries times rows: events (80*500k), status (55*10k), data->>city (30*10k)
+	assert.Equal(t, []string{
+		"public.events:gin:payload",
+		"public.orders:btree:status",
+		"public.orders:btree:data->>city",
+	}, ids, "covered, rare and small-table patterns are skipped")
+
+	status := recs[1]
+	assert.Equal(t, int64(55), status.RESTQueries)
+	assert.Equal(t, []QueryPatternKind{QueryPatternEquality, QueryPatternOrder}, status.Patterns)
+	assert.Equal(t, "idx_orders_status", status.Index.Name)
+	assert.Equal(t, `CREATE INDEX CONCURRENTLY "idx_orders_status" ON "public"."orders" USING btree ("status")`, status.Statement)
+	assert.Contains(t, status.Reason, "55 REST queries")
+	require.Len(t, status.Statements, 1)
+	assert.Equal(t, int64(40), status.Statements[0].Calls)
+
+	assert.Equal(t, "jsonb_path_ops", recs[0].Index.OpClass)
+
+	// logs is read by sequential scans but no REST pattern points at a column
+	require.Len(t, hotspots, 1)
+	assert.Equal(t, "logs", hotspots[0].Table)
+	require.Len(t, hotspots[0].Statements, 1)
+}
+
+func TestBuildIndexSampleQuery(t *testing.T) {
+	query, err := buildIndexSampleQuery("public", "orders", CreateIndexRequest{Columns: []string{"status"}})
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM "public"."orders" WHERE "status" = (SELECT "status" FROM "public"."orders" WHERE "status" IS NOT NULL LIMIT 1)`, query)
+
+	query, err = buildIndexSampleQuery("public", "orders", CreateIndexRequest{Columns: []string{"data"}, Method: "gin"})
+	require.NoError(t, err)
+	assert.Contains(t, query, `WHERE "data" @> (SELECT "data"`)
+
+	query, err = buildIndexSampleQuery("public", "orders", CreateIndexRequest{Columns: []string{"data->>city"}})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(query, `SELECT * FROM "public"."orders" WHERE ("data"->>'city') = `))
+
+	_, err = buildIndexSampleQuery("public", "orders", CreateIndexRequest{})
+	assert.Error(t, err)
+}
+
+func TestExplainTiming(t *testing.T) {
+	plan := []byte(`[{"Plan": {"Node Type": "Index Scan", "Index Name": "idx_orders_status"}, "Planning Time": 0.1, "Execution Time": 1.25}]`)
+
+	ms, usesIndex, err := explainTiming(plan, "idx_orders_status")
+	require.NoError(t, err)
+	assert.Equal(t, 1.25, ms)
+	assert.True(t, usesIndex)
+
+	_, usesIndex, err = explainTiming(plan, "idx_orders_other")
+	require.NoError(t, err)
+	assert.False(t, usesIndex)
+
+	_, _, err = explainTiming([]byte(`{}`), "x")
+	assert.Error(t, err)
+}
+
+func TestIndexAdvisorHandler_Validation(t *testing.T) {
+	handler := NewIndexAdvisorHandler(nil, NewDDLHandler(nil), newQueryPatternTracker())
+	app := fiber.New()
+	app.Get("/index-advisor/recommendations", handler.GetRecommendations)
+	app.Post("/index-advisor/apply", handler.ApplyRecommendation)
+	app.Delete("/index-advisor/patterns", handler.ResetPatterns)
+
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		body       string
+		wantStatus int
+	}{
+		{"negative min_rows", "GET", "/index-advisor/recommendations?min_rows=-1", "", 400},
+		{"invalid min_queries", "GET", "/index-advisor/recommendations?min_queries=x", "", 400},
+		{"invalid body", "POST", "/index-advisor/apply", `{`, 400},
+		{"invalid table", "POST", "/index-advisor/apply", `{"schema": "public", "table": "1x", "index": {"columns": ["a"]}}`, 400},
+		{"invalid index", "POST", "/index-advisor/apply", `{"schema": "public", "table": "orders", "index": {"columns": ["a"], "method": "hash"}}`, 400},
+		{"reset patterns", "DELETE", "/index-advisor/patterns", "", 204},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+		})
+	}
+}
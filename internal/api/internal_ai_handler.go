@@ -69,22 +69,16 @@ type InternalEmbedResponse struct {
 // This endpoint allows custom MCP tools, edge functions, and jobs to make AI completions.
 func (h *InternalAIHandler) HandleChat(c fiber.Ctx) error {
 	if h.aiStorage == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "AI service not configured",
-		})
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "AI service not configured", ErrCodeServiceUnavailable)
 	}
 
 	var req InternalChatRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": fmt.Sprintf("Invalid request body: %v", err),
-		})
+		return SendBadRequest(c, fmt.Sprintf("Invalid request body: %v", err), ErrCodeInvalidBody)
 	}
 
 	if len(req.Messages) == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "messages array is required",
-		})
+		return SendBadRequest(c, "messages array is required", ErrCodeMissingField)
 	}
 
 	// Get provider - use specified or default
@@ -93,18 +87,14 @@ func (h *InternalAIHandler) HandleChat(c fiber.Ctx) error {
 		providerName = h.defaultProvider
 	}
 	if providerName == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "No AI provider configured. Set 'provider' in request or configure default provider.",
-		})
+		return SendBadRequest(c, "No AI provider configured. Set 'provider' in request or configure default provider.", ErrCodeInvalidInput)
 	}
 
 	// Get the provider from storage
 	provider, err := h.aiStorage.GetProviderByName(c.RequestCtx(), providerName)
 	if err != nil {
 		log.Warn().Err(err).Str("provider", providerName).Msg("Failed to get AI provider")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": fmt.Sprintf("AI provider '%s' not found", providerName),
-		})
+		return SendBadRequest(c, fmt.Sprintf("AI provider '%s' not found", providerName), ErrCodeInvalidInput)
 	}
 
 	// Build provider config
@@ -128,9 +118,7 @@ func (h *InternalAIHandler) HandleChat(c fiber.Ctx) error {
 	aiProvider, err := ai.NewProvider(providerConfig)
 	if err != nil {
 		log.Error().Err(err).Str("provider", providerName).Msg("Failed to create AI provider")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to initialize AI provider: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to initialize AI provider: %v", err), ErrCodeOperationFailed)
 	}
 	defer func() { _ = aiProvider.Close() }()
 
@@ -165,15 +153,11 @@ func (h *InternalAIHandler) HandleChat(c fiber.Ctx) error {
 	resp, err := aiProvider.Chat(c.RequestCtx(), chatReq)
 	if err != nil {
 		log.Error().Err(err).Msg("AI chat request failed")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("AI request failed: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("AI request failed: %v", err), ErrCodeOperationFailed)
 	}
 
 	if len(resp.Choices) == 0 {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "AI returned no response",
-		})
+		return SendInternalError(c, "AI returned no response")
 	}
 
 	// Build response
@@ -202,31 +186,23 @@ func (h *InternalAIHandler) HandleChat(c fiber.Ctx) error {
 // This endpoint allows custom MCP tools, edge functions, and jobs to generate embeddings.
 func (h *InternalAIHandler) HandleEmbed(c fiber.Ctx) error {
 	if h.embeddingService == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Embedding service not configured",
-		})
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "Embedding service not configured", ErrCodeServiceUnavailable)
 	}
 
 	var req InternalEmbedRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": fmt.Sprintf("Invalid request body: %v", err),
-		})
+		return SendBadRequest(c, fmt.Sprintf("Invalid request body: %v", err), ErrCodeInvalidBody)
 	}
 
 	if req.Text == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "text is required",
-		})
+		return SendBadRequest(c, "text is required", ErrCodeMissingField)
 	}
 
 	// Generate embedding
 	embedding, err := h.embeddingService.GenerateEmbedding(c.RequestCtx(), req.Text)
 	if err != nil {
 		log.Error().Err(err).Msg("Embedding generation failed")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Embedding generation failed: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Embedding generation failed: %v", err), ErrCodeOperationFailed)
 	}
 
 	// Get default model name
@@ -242,16 +218,12 @@ func (h *InternalAIHandler) HandleEmbed(c fiber.Ctx) error {
 // This endpoint lists available AI providers.
 func (h *InternalAIHandler) HandleListProviders(c fiber.Ctx) error {
 	if h.aiStorage == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "AI service not configured",
-		})
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "AI service not configured", ErrCodeServiceUnavailable)
 	}
 
 	providers, err := h.aiStorage.ListProviders(c.RequestCtx(), true) // Only enabled providers
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to list providers: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to list providers: %v", err), ErrCodeOperationFailed)
 	}
 
 	// Return simplified provider info (hide config/API keys)
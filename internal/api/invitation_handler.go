@@ -109,31 +109,23 @@ func (h *InvitationHandler) CreateInvitation(c fiber.Ctx) error {
 	// Get inviter info from context (set by auth middleware)
 	inviterID, ok := c.Locals("user_id").(string)
 	if !ok {
-		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
-			"error": "User not authenticated",
-		})
+		return SendUnauthorized(c, "User not authenticated", ErrCodeAuthRequired)
 	}
 
 	inviterUUID, err := uuid.Parse(inviterID)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return SendInternalError(c, "Invalid user ID")
 	}
 
 	// Parse request
 	var req CreateInvitationRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate role
 	if err := auth.ValidateDashboardRole(req.Role); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	// Calculate expiry duration (default 7 days)
@@ -145,9 +137,7 @@ func (h *InvitationHandler) CreateInvitation(c fiber.Ctx) error {
 	// Create invitation
 	invitation, err := h.invitationService.CreateInvitation(ctx, req.Email, req.Role, &inviterUUID, expiryDuration)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to create invitation: %v", err),
-		})
+		return SendErrorWithCode(c, http.StatusInternalServerError, fmt.Sprintf("Failed to create invitation: %v", err), ErrCodeOperationFailed)
 	}
 
 	// Generate invite link using base URL from config
@@ -215,49 +205,37 @@ func (h *InvitationHandler) AcceptInvitation(c fiber.Ctx) error {
 
 	token := c.Params("token")
 	if token == "" {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "Token is required",
-		})
+		return SendBadRequest(c, "Token is required", ErrCodeMissingField)
 	}
 
 	// Parse request
 	var req AcceptInvitationRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate password strength
 	if err := auth.ValidateDashboardPassword(req.Password); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	// Validate invitation token
 	invitation, err := h.invitationService.ValidateToken(ctx, token)
 	if err != nil {
-		return c.Status(invitationErrorStatus(err)).JSON(fiber.Map{
-			"error": invitationErrorDetails(err),
-		})
+		return SendError(c, invitationErrorStatus(err), invitationErrorDetails(err))
 	}
 
 	// Set the invitation token in the database session
 	// This allows the RLS policy to verify the invitation
 	_, err = h.dashboardAuth.GetDB().Exec(ctx, "SET LOCAL app.invitation_token = $1", token)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to set session context",
-		})
+		return SendErrorWithCode(c, http.StatusInternalServerError, "Failed to set session context", ErrCodeOperationFailed)
 	}
 
 	// Create the user in dashboard.users
 	user, err := h.dashboardAuth.CreateUser(ctx, invitation.Email, req.Password, req.Name)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to create user: %v", err),
-		})
+		return SendErrorWithCode(c, http.StatusInternalServerError, fmt.Sprintf("Failed to create user: %v", err), ErrCodeOperationFailed)
 	}
 
 	// Update user role and verify email
@@ -267,9 +245,7 @@ func (h *InvitationHandler) AcceptInvitation(c fiber.Ctx) error {
 		WHERE id = $2
 	`, invitation.Role, user.ID)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to set user role and verify email",
-		})
+		return SendErrorWithCode(c, http.StatusInternalServerError, "Failed to set user role and verify email", ErrCodeOperationFailed)
 	}
 
 	// Mark invitation as accepted
@@ -285,9 +261,7 @@ func (h *InvitationHandler) AcceptInvitation(c fiber.Ctx) error {
 	// Log in the user to get access token
 	loggedInUser, loginResp, err := h.dashboardAuth.Login(ctx, invitation.Email, req.Password, nil, c.Get("User-Agent"))
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "User created but failed to generate access token",
-		})
+		return SendErrorWithCode(c, http.StatusInternalServerError, "User created but failed to generate access token", ErrCodeOperationFailed)
 	}
 
 	return c.Status(http.StatusCreated).JSON(AcceptInvitationResponse{
@@ -309,9 +283,7 @@ func (h *InvitationHandler) ListInvitations(c fiber.Ctx) error {
 
 	invitations, err := h.invitationService.ListInvitations(ctx, includeAccepted, includeExpired)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to list invitations",
-		})
+		return SendErrorWithCode(c, http.StatusInternalServerError, "Failed to list invitations", ErrCodeOperationFailed)
 	}
 
 	// Don't expose tokens in the list
@@ -331,20 +303,14 @@ func (h *InvitationHandler) RevokeInvitation(c fiber.Ctx) error {
 
 	token := c.Params("token")
 	if token == "" {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "Token is required",
-		})
+		return SendBadRequest(c, "Token is required", ErrCodeMissingField)
 	}
 
 	if err := h.invitationService.RevokeInvitation(ctx, token); err != nil {
 		if errors.Is(err, auth.ErrInvitationNotFound) {
-			return c.Status(http.StatusNotFound).JSON(fiber.Map{
-				"error": "Invitation not found",
-			})
+			return SendNotFound(c, "Invitation not found")
 		}
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to revoke invitation",
-		})
+		return SendErrorWithCode(c, http.StatusInternalServerError, "Failed to revoke invitation", ErrCodeOperationFailed)
 	}
 
 	return c.JSON(fiber.Map{
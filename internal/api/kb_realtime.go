@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/ai"
+	"github.com/nimbleflux/fluxbase/internal/realtime"
+	"github.com/rs/zerolog/log"
+)
+
+// kbDocumentPublisher broadcasts document status events on the realtime channel of their
+// knowledge base, so dashboards can follow ingestion without polling
+type kbDocumentPublisher struct {
+	manager *realtime.Manager
+}
+
+// PublishDocumentStatus implements ai.DocumentEventPublisher
+func (p *kbDocumentPublisher) PublishDocumentStatus(event *ai.DocumentStatusEvent) {
+	channel := ai.KnowledgeBaseChannel(event.KnowledgeBaseID)
+	err := p.manager.BroadcastGlobal(channel, realtime.ServerMessage{
+		Type:    realtime.MessageTypeBroadcast,
+		Channel: channel,
+		Payload: map[string]interface{}{
+			"broadcast": map[string]interface{}{
+				"event":   ai.DocumentStatusEventName,
+				"payload": event,
+			},
+		},
+	})
+	if err != nil {
+		log.Warn().Err(err).
+			Str("document_id", event.DocumentID).
+			Str("channel", channel).
+			Msg("Failed to publish document status event")
+	}
+}
+
+// kbPermissionChecker checks knowledge base permissions, implemented by ai.KnowledgeBaseStorage
+type kbPermissionChecker interface {
+	CheckKBPermission(ctx context.Context, kbID, userID, requiredPermission string) (bool, error)
+}
+
+// authorizeKBChannel allows admins and users with viewer access to the knowledge base to
+// subscribe to its kb:<id> channel
+func authorizeKBChannel(permissions kbPermissionChecker) realtime.ChannelAuthorizer {
+	return func(ctx context.Context, conn *realtime.Connection, channel string) error {
+		kbID := strings.TrimPrefix(channel, ai.KnowledgeBaseChannelPrefix)
+		if _, err := uuid.Parse(kbID); err != nil {
+			return errors.New("invalid knowledge base channel")
+		}
+
+		switch conn.Role {
+		case "admin", "dashboard_admin", "service_role":
+			return nil
+		}
+		if conn.UserID == nil {
+			return errors.New("authentication required to subscribe to knowledge base channels")
+		}
+
+		allowed, err := permissions.CheckKBPermission(ctx, kbID, *conn.UserID, string(ai.KBPermissionViewer))
+		if err != nil {
+			log.Error().Err(err).Str("knowledge_base_id", kbID).Msg("Failed to check knowledge base channel access")
+			return errors.New("failed to check knowledge base access")
+		}
+		if !allowed {
+			return errors.New("access to this knowledge base is not allowed")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nimbleflux/fluxbase/internal/realtime"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeKBPermissions struct {
+	allowed map[string]bool // kbID/userID -> viewer access
+	err     error
+}
+
+func (f *fakeKBPermissions) CheckKBPermission(_ context.Context, kbID, userID, requiredPermission string) (bool, error) {
+	if requiredPermission != "viewer" {
+		return false, nil
+	}
+	return f.allowed[kbID+"/"+userID], f.err
+}
+
+func TestAuthorizeKBChannel(t *testing.T) {
+	const kbID = "6f1c2d3e-4a5b-4c6d-8e9f-0a1b2c3d4e5f"
+	userID := "user-1"
+	otherID := "user-2"
+	authorize := authorizeKBChannel(&fakeKBPermissions{allowed: map[string]bool{kbID + "/" + userID: true}})
+
+	tests := []struct {
+		name    string
+		conn    *realtime.Connection
+		channel string
+		wantErr string
+	}{
+		{"viewer", realtime.NewConnectionSync("c1", nil, &userID, "authenticated", nil), "kb:" + kbID, ""},
+		{"admin without grant", realtime.NewConnectionSync("c2", nil, nil, "dashboard_admin", nil), "kb:" + kbID, ""},
+		{"user without grant", realtime.NewConnectionSync("c3", nil, &otherID, "authenticated", nil), "kb:" + kbID, "not allowed"},
+		{"anonymous", realtime.NewConnectionSync("c4", nil, nil, "anon", nil), "kb:" + kbID, "authentication required"},
+		{"invalid id", realtime.NewConnectionSync("c5", nil, nil, "service_role", nil), "kb:not-a-uuid", "invalid knowledge base channel"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := authorize(context.Background(), tt.conn, tt.channel)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("permission lookup failure", func(t *testing.T) {
+		failing := authorizeKBChannel(&fakeKBPermissions{err: errors.New("connection refused")})
+		err := failing(context.Background(), realtime.NewConnectionSync("c6", nil, &userID, "authenticated", nil), "kb:"+kbID)
+		assert.EqualError(t, err, "failed to check knowledge base access")
+	})
+}
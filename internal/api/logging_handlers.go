@@ -50,9 +50,7 @@ func NewLoggingHandler(loggingService *logging.Service) *LoggingHandler {
 // @Router /admin/logs [get]
 func (h *LoggingHandler) QueryLogs(c fiber.Ctx) error {
 	if h.loggingService == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Logging service not available",
-		})
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "Logging service not available", ErrCodeServiceUnavailable)
 	}
 
 	opts := storage.LogQueryOptions{}
@@ -118,9 +116,7 @@ func (h *LoggingHandler) QueryLogs(c fiber.Ctx) error {
 	// Query logs
 	result, err := h.loggingService.Query(c.RequestCtx(), opts)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendInternalError(c, err.Error())
 	}
 
 	return c.JSON(fiber.Map{
@@ -145,16 +141,12 @@ func (h *LoggingHandler) QueryLogs(c fiber.Ctx) error {
 // @Router /admin/logs/executions/{execution_id} [get]
 func (h *LoggingHandler) GetExecutionLogs(c fiber.Ctx) error {
 	if h.loggingService == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Logging service not available",
-		})
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "Logging service not available", ErrCodeServiceUnavailable)
 	}
 
 	executionID := c.Params("execution_id")
 	if executionID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "execution_id is required",
-		})
+		return SendBadRequest(c, "execution_id is required", ErrCodeMissingField)
 	}
 
 	afterLine := 0
@@ -166,9 +158,7 @@ func (h *LoggingHandler) GetExecutionLogs(c fiber.Ctx) error {
 
 	entries, err := h.loggingService.GetExecutionLogs(c.RequestCtx(), executionID, afterLine)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendInternalError(c, err.Error())
 	}
 
 	return c.JSON(fiber.Map{
@@ -189,16 +179,12 @@ func (h *LoggingHandler) GetExecutionLogs(c fiber.Ctx) error {
 // @Router /admin/logs/stats [get]
 func (h *LoggingHandler) GetLogStats(c fiber.Ctx) error {
 	if h.loggingService == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Logging service not available",
-		})
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "Logging service not available", ErrCodeServiceUnavailable)
 	}
 
 	stats, err := h.loggingService.Stats(c.RequestCtx())
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendInternalError(c, err.Error())
 	}
 
 	return c.JSON(stats)
@@ -216,15 +202,11 @@ func (h *LoggingHandler) GetLogStats(c fiber.Ctx) error {
 // @Router /admin/logs/flush [post]
 func (h *LoggingHandler) FlushLogs(c fiber.Ctx) error {
 	if h.loggingService == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Logging service not available",
-		})
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "Logging service not available", ErrCodeServiceUnavailable)
 	}
 
 	if err := h.loggingService.Flush(c.RequestCtx()); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendInternalError(c, err.Error())
 	}
 
 	return c.JSON(fiber.Map{
@@ -244,9 +226,7 @@ func (h *LoggingHandler) FlushLogs(c fiber.Ctx) error {
 // @Router /admin/logs/test [post]
 func (h *LoggingHandler) GenerateTestLogs(c fiber.Ctx) error {
 	if h.loggingService == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Logging service not available",
-		})
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "Logging service not available", ErrCodeServiceUnavailable)
 	}
 
 	// Generate test logs of different types and levels
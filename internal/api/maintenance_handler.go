@@ -0,0 +1,388 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/maintenance"
+	"github.com/rs/zerolog/log"
+)
+
+// MaintenanceHandler reports autovacuum activity, runs VACUUM/ANALYZE and manages
+// maintenance schedules
+type MaintenanceHandler struct {
+	store  *maintenance.Store
+	runner *maintenance.Runner
+	now    func() time.Time
+}
+
+// NewMaintenanceHandler creates a new maintenance handler
+func NewMaintenanceHandler(store *maintenance.Store, runner *maintenance.Runner) *MaintenanceHandler {
+	return &MaintenanceHandler{store: store, runner: runner, now: time.Now}
+}
+
+// maintenanceTarget is the table and operation of a run or schedule request
+type maintenanceTarget struct {
+	Schema    string                `json:"schema"`
+	Table     string                `json:"table"`
+	Operation maintenance.Operation `json:"operation"`
+}
+
+// validate defaults the schema to public and checks the operation
+func (t *maintenanceTarget) validate() error {
+	if t.Schema == "" {
+		t.Schema = "public"
+	}
+	if t.Table == "" {
+		return errors.New("table is required")
+	}
+	if !t.Operation.Valid() {
+		return errors.New("operation must be one of: vacuum, analyze, vacuum_analyze")
+	}
+	return nil
+}
+
+// GetActivity returns the vacuum activity of each user table and the vacuums in progress
+// GET /api/v1/admin/maintenance/activity?schema=public
+func (h *MaintenanceHandler) GetActivity(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+
+	tables, err := h.store.Activity(ctx, c.Query("schema"))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get vacuum activity")
+		return SendOperationFailed(c, "get vacuum activity")
+	}
+
+	running, err := h.store.RunningVacuums(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get vacuum progress")
+		return SendOperationFailed(c, "get vacuum progress")
+	}
+
+	return c.JSON(fiber.Map{
+		"tables":  tables,
+		"running": running,
+	})
+}
+
+// RunMaintenance starts a VACUUM or ANALYZE of a table in the background. The request must
+// repeat the table name in confirm, so a table is not vacuumed by accident.
+// POST /api/v1/admin/maintenance/run {"schema": "public", "table": "chunks", "operation": "vacuum_analyze", "confirm": "public.chunks"}
+func (h *MaintenanceHandler) RunMaintenance(c fiber.Ctx) error {
+	var req struct {
+		maintenanceTarget
+		Confirm string `json:"confirm"`
+	}
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+	if err := req.validate(); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+	if qualified := req.Schema + "." + req.Table; req.Confirm != qualified {
+		return SendBadRequest(c, fmt.Sprintf("confirm must be %q to run %s", qualified, req.Operation), ErrCodeInvalidInput)
+	}
+
+	run, err := h.runner.Start(c.RequestCtx(), maintenance.RunRequest{
+		Schema:      req.Schema,
+		Table:       req.Table,
+		Operation:   req.Operation,
+		Trigger:     maintenance.TriggerManual,
+		TriggeredBy: getUserIDFromContext(c),
+	})
+	switch {
+	case errors.Is(err, maintenance.ErrTableNotFound):
+		return SendNotFound(c, fmt.Sprintf("Table %s.%s not found", req.Schema, req.Table))
+	case errors.Is(err, maintenance.ErrRunInProgress):
+		return SendConflict(c, "Maintenance of this table is already running", ErrCodeConflict)
+	case err != nil:
+		log.Error().Err(err).Str("schema", req.Schema).Str("table", req.Table).Msg("Failed to start table maintenance")
+		return SendOperationFailed(c, "start table maintenance")
+	}
+
+	log.Info().
+		Str("schema", req.Schema).
+		Str("table", req.Table).
+		Str("operation", string(req.Operation)).
+		Msg("Manual table maintenance started")
+
+	return c.Status(fiber.StatusAccepted).JSON(run)
+}
+
+// ListRuns returns the most recent manual and scheduled runs with their durations
+// GET /api/v1/admin/maintenance/runs?schema=public&table=chunks&limit=50
+func (h *MaintenanceHandler) ListRuns(c fiber.Ctx) error {
+	filter := maintenance.RunFilter{Schema: c.Query("schema"), Table: c.Query("table"), Limit: 50}
+	if v := c.Query("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l <= 0 {
+			return SendBadRequest(c, "limit must be a positive integer", ErrCodeInvalidInput)
+		}
+		filter.Limit = min(l, 1000)
+	}
+
+	runs, err := h.store.ListRuns(c.RequestCtx(), filter)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list maintenance runs")
+		return SendOperationFailed(c, "list maintenance runs")
+	}
+
+	return c.JSON(fiber.Map{
+		"runs": runs,
+	})
+}
+
+// ListSchedules returns the maintenance schedules with their next run
+// GET /api/v1/admin/maintenance/schedules
+func (h *MaintenanceHandler) ListSchedules(c fiber.Ctx) error {
+	schedules, err := h.store.ListSchedules(c.RequestCtx())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list maintenance schedules")
+		return SendOperationFailed(c, "list maintenance schedules")
+	}
+
+	now := h.now()
+	for i := range schedules {
+		if schedules[i].Enabled {
+			schedules[i].NextRunAt = maintenance.NextRun(schedules[i], now)
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"schedules": schedules,
+	})
+}
+
+// CreateSchedule schedules an operation on a table
+// POST /api/v1/admin/maintenance/schedules {"schema": "logging", "table": "entries", "operation": "vacuum_analyze", "cron_schedule": "0 3 * * *"}
+func (h *MaintenanceHandler) CreateSchedule(c fiber.Ctx) error {
+	var req struct {
+		maintenanceTarget
+		CronSchedule string `json:"cron_schedule"`
+		Enabled      *bool  `json:"enabled"`
+	}
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+	if err := req.validate(); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+	if _, err := maintenance.ParseSchedule(req.CronSchedule); err != nil {
+		return SendBadRequest(c, fmt.Sprintf("Invalid cron_schedule: %v", err), ErrCodeInvalidInput)
+	}
+
+	ctx := c.RequestCtx()
+	exists, err := h.store.TableExists(ctx, req.Schema, req.Table)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to check maintenance table")
+		return SendOperationFailed(c, "create maintenance schedule")
+	}
+	if !exists {
+		return SendNotFound(c, fmt.Sprintf("Table %s.%s not found", req.Schema, req.Table))
+	}
+
+	schedule, err := h.store.CreateSchedule(ctx, maintenance.Schedule{
+		Schema:       req.Schema,
+		Table:        req.Table,
+		Operation:    req.Operation,
+		CronSchedule: req.CronSchedule,
+		Enabled:      req.Enabled == nil || *req.Enabled,
+	}, getUserIDFromContext(c))
+	if errors.Is(err, maintenance.ErrScheduleExists) {
+		return SendConflict(c, "Table already has a schedule for this operation", ErrCodeConflict)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create maintenance schedule")
+		return SendOperationFailed(c, "create maintenance schedule")
+	}
+
+	if schedule.Enabled {
+		schedule.NextRunAt = maintenance.NextRun(*schedule, h.now())
+	}
+	return c.Status(fiber.StatusCreated).JSON(schedule)
+}
+
+// UpdateSchedule changes the cron expression or enables/disables a schedule
+// PATCH /api/v1/admin/maintenance/schedules/:id {"cron_schedule": "0 4 * * 0", "enabled": false}
+func (h *MaintenanceHandler) UpdateSchedule(c fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return SendInvalidID(c, "schedule ID")
+	}
+
+	var req struct {
+		CronSchedule *string `json:"cron_schedule"`
+		Enabled      *bool   `json:"enabled"`
+	}
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+	if req.CronSchedule != nil {
+		if _, err := maintenance.ParseSchedule(*req.CronSchedule); err != nil {
+			return SendBadRequest(c, fmt.Sprintf("Invalid cron_schedule: %v", err), ErrCodeInvalidInput)
+		}
+	}
+
+	ctx := c.RequestCtx()
+	current, err := h.store.GetSchedule(ctx, id)
+	if errors.Is(err, maintenance.ErrScheduleNotFound) {
+		return SendResourceNotFound(c, "Maintenance schedule")
+	}
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("Failed to get maintenance schedule")
+		return SendOperationFailed(c, "update maintenance schedule")
+	}
+
+	cronSchedule, enabled := current.CronSchedule, current.Enabled
+	if req.CronSchedule != nil {
+		cronSchedule = *req.CronSchedule
+	}
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	schedule, err := h.store.UpdateSchedule(ctx, id, cronSchedule, enabled)
+	if errors.Is(err, maintenance.ErrScheduleNotFound) {
+		return SendResourceNotFound(c, "Maintenance schedule")
+	}
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("Failed to update maintenance schedule")
+		return SendOperationFailed(c, "update maintenance schedule")
+	}
+
+	if schedule.Enabled {
+		schedule.NextRunAt = maintenance.NextRun(*schedule, h.now())
+	}
+	return c.JSON(schedule)
+}
+
+// DeleteSchedule deletes a schedule, keeping its runs in the history
+// DELETE /api/v1/admin/maintenance/schedules/:id
+func (h *MaintenanceHandler) DeleteSchedule(c fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return SendInvalidID(c, "schedule ID")
+	}
+
+	err := h.store.DeleteSchedule(c.RequestCtx(), id)
+	if errors.Is(err, maintenance.ErrScheduleNotFound) {
+		return SendResourceNotFound(c, "Maintenance schedule")
+	}
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("Failed to delete maintenance schedule")
+		return SendOperationFailed(c, "delete maintenance schedule")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListPartitions returns the retention of every table partitioned by month with its partitions
+// GET /api/v1/admin/maintenance/partitions
+func (h *MaintenanceHandler) ListPartitions(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+
+	policies, err := h.store.ListPartitionPolicies(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list partition policies")
+		return SendOperationFailed(c, "list partitions")
+	}
+
+	for i := range policies {
+		policies[i].Partitions, err = h.store.Partitions(ctx, policies[i].Schema, policies[i].Table)
+		if err != nil {
+			log.Error().Err(err).Str("schema", policies[i].Schema).Str("table", policies[i].Table).Msg("Failed to list partitions")
+			return SendOperationFailed(c, "list partitions")
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"tables": policies,
+	})
+}
+
+// UpdatePartitionPolicy changes how many months of a partitioned table are kept and created
+// ahead. A retention_months of 0 keeps all data. Expired partitions are dropped by the
+// maintenance scheduler or by ManagePartitions.
+// PATCH /api/v1/admin/maintenance/partitions/:schema/:table {"retention_months": 6, "premake_months": 3}
+func (h *MaintenanceHandler) UpdatePartitionPolicy(c fiber.Ctx) error {
+	schema, table := c.Params("schema"), c.Params("table")
+
+	var req struct {
+		RetentionMonths *int `json:"retention_months"`
+		PremakeMonths   *int `json:"premake_months"`
+	}
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+	if req.RetentionMonths != nil && *req.RetentionMonths < 0 {
+		return SendBadRequest(c, "retention_months must be 0 (keep all data) or a positive number of months", ErrCodeInvalidInput)
+	}
+	if req.PremakeMonths != nil && (*req.PremakeMonths < 1 || *req.PremakeMonths > 24) {
+		return SendBadRequest(c, "premake_months must be between 1 and 24", ErrCodeInvalidInput)
+	}
+
+	ctx := c.RequestCtx()
+	current, err := h.store.GetPartitionPolicy(ctx, schema, table)
+	if errors.Is(err, maintenance.ErrPartitionPolicyNotFound) {
+		return SendNotFound(c, fmt.Sprintf("Table %s.%s is not partitioned by month", schema, table))
+	}
+	if err != nil {
+		log.Error().Err(err).Str("schema", schema).Str("table", table).Msg("Failed to get partition policy")
+		return SendOperationFailed(c, "update partition policy")
+	}
+
+	retention, premake := current.RetentionMonths, current.PremakeMonths
+	if req.RetentionMonths != nil {
+		retention = nil
+		if *req.RetentionMonths > 0 {
+			retention = req.RetentionMonths
+		}
+	}
+	if req.PremakeMonths != nil {
+		premake = *req.PremakeMonths
+	}
+
+	policy, err := h.store.UpdatePartitionPolicy(ctx, schema, table, retention, premake, getUserIDFromContext(c))
+	if errors.Is(err, maintenance.ErrPartitionPolicyNotFound) {
+		return SendNotFound(c, fmt.Sprintf("Table %s.%s is not partitioned by month", schema, table))
+	}
+	if err != nil {
+		log.Error().Err(err).Str("schema", schema).Str("table", table).Msg("Failed to update partition policy")
+		return SendOperationFailed(c, "update partition policy")
+	}
+
+	return c.JSON(policy)
+}
+
+// ManagePartitions creates the upcoming partitions of a table and drops the expired ones now,
+// instead of waiting for the maintenance scheduler. Expired data is deleted, so the request
+// must repeat the table name in confirm.
+// POST /api/v1/admin/maintenance/partitions/:schema/:table/manage {"confirm": "auth.rls_audit_log"}
+func (h *MaintenanceHandler) ManagePartitions(c fiber.Ctx) error {
+	schema, table := c.Params("schema"), c.Params("table")
+
+	var req struct {
+		Confirm string `json:"confirm"`
+	}
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+	if qualified := schema + "." + table; req.Confirm != qualified {
+		return SendBadRequest(c, fmt.Sprintf("confirm must be %q to manage its partitions", qualified), ErrCodeInvalidInput)
+	}
+
+	result, err := h.runner.ManagePartitions(c.RequestCtx(), schema, table)
+	if errors.Is(err, maintenance.ErrPartitionPolicyNotFound) {
+		return SendNotFound(c, fmt.Sprintf("Table %s.%s is not partitioned by month", schema, table))
+	}
+	if err != nil {
+		log.Error().Err(err).Str("schema", schema).Str("table", table).Msg("Failed to manage partitions")
+		return SendOperationFailed(c, "manage partitions")
+	}
+
+	return c.JSON(result)
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/maintenance"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMaintenanceApp() *fiber.App {
+	store := maintenance.NewStore(nil)
+	h := NewMaintenanceHandler(store, maintenance.NewRunner(store, time.Hour))
+	app := fiber.New()
+	app.Post("/maintenance/run", h.RunMaintenance)
+	app.Get("/maintenance/runs", h.ListRuns)
+	app.Post("/maintenance/schedules", h.CreateSchedule)
+	app.Patch("/maintenance/schedules/:id", h.UpdateSchedule)
+	app.Delete("/maintenance/schedules/:id", h.DeleteSchedule)
+	app.Patch("/maintenance/partitions/:schema/:table", h.UpdatePartitionPolicy)
+	app.Post("/maintenance/partitions/:schema/:table/manage", h.ManagePartitions)
+	return app
+}
+
+func TestMaintenanceHandler_RunRequiresConfirmation(t *testing.T) {
+	app := newTestMaintenanceApp()
+
+	tests := []struct {
+		name string
+		body string
+		msg  string
+	}{
+		{"missing table", `{"operation":"vacuum","confirm":"public."}`, "table is required"},
+		{"unknown operation", `{"table":"chunks","operation":"vacuum_full","confirm":"public.chunks"}`, "operation must be one of"},
+		{"missing confirmation", `{"table":"chunks","operation":"vacuum"}`, `confirm must be \"public.chunks\"`},
+		{"wrong confirmation", `{"schema":"logging","table":"entries","operation":"analyze","confirm":"entries"}`, `confirm must be \"logging.entries\"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/maintenance/run", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			body := new(strings.Builder)
+			_, _ = io.Copy(body, resp.Body)
+			assert.Contains(t, body.String(), tt.msg)
+		})
+	}
+}
+
+func TestMaintenanceHandler_ValidatesSchedules(t *testing.T) {
+	app := newTestMaintenanceApp()
+
+	req := httptest.NewRequest("POST", "/maintenance/schedules",
+		strings.NewReader(`{"table":"chunks","operation":"vacuum","cron_schedule":"every night"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, "invalid cron expression")
+
+	req = httptest.NewRequest("PATCH", "/maintenance/schedules/not-a-uuid", strings.NewReader(`{"enabled":false}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, "invalid schedule ID")
+
+	resp, err = app.Test(httptest.NewRequest("DELETE", "/maintenance/schedules/42", nil))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, "invalid schedule ID")
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/maintenance/runs?limit=0", nil))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, "invalid limit")
+}
+
+func TestMaintenanceHandler_ValidatesPartitions(t *testing.T) {
+	app := newTestMaintenanceApp()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		msg    string
+	}{
+		{"negative retention", "PATCH", "/maintenance/partitions/auth/rls_audit_log", `{"retention_months":-1}`, "retention_months must be 0"},
+		{"too many premade months", "PATCH", "/maintenance/partitions/auth/rls_audit_log", `{"premake_months":36}`, "premake_months must be between 1 and 24"},
+		{"missing confirmation", "POST", "/maintenance/partitions/ai/retrieval_log/manage", `{}`, `confirm must be \"ai.retrieval_log\"`},
+		{"wrong confirmation", "POST", "/maintenance/partitions/ai/retrieval_log/manage", `{"confirm":"retrieval_log"}`, `confirm must be \"ai.retrieval_log\"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			body := new(strings.Builder)
+			_, _ = io.Copy(body, resp.Body)
+			assert.Contains(t, body.String(), tt.msg)
+		})
+	}
+}
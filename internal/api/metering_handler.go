@@ -0,0 +1,137 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/metering"
+	"github.com/rs/zerolog/log"
+)
+
+// maxMeteringPreviewRange bounds ad-hoc event collection so a preview cannot scan months of usage
+const maxMeteringPreviewRange = 31 * 24 * time.Hour
+
+// MeteringHandler exposes billable usage events and the history of metering exports
+type MeteringHandler struct {
+	collector *metering.Collector
+	exporter  *metering.Exporter // nil when metering exports are disabled
+	instance  string
+}
+
+// NewMeteringHandler creates a new metering handler. exporter may be nil.
+func NewMeteringHandler(db *pgxpool.Pool, exporter *metering.Exporter, instance string) *MeteringHandler {
+	collector := metering.NewCollector(db)
+	if exporter != nil {
+		collector = exporter.Collector()
+	}
+	return &MeteringHandler{collector: collector, exporter: exporter, instance: instance}
+}
+
+// parseMeteringPeriod validates a required RFC3339 period of at most 31 days
+func parseMeteringPeriod(startStr, endStr string) (time.Time, time.Time, error) {
+	if startStr == "" || endStr == "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("start_time and end_time are required")
+	}
+	start, err := time.Parse(time.RFC3339, startStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("start_time must be an RFC3339 timestamp")
+	}
+	end, err := time.Parse(time.RFC3339, endStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("end_time must be an RFC3339 timestamp")
+	}
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end_time must be after start_time")
+	}
+	if end.Sub(start) > maxMeteringPreviewRange {
+		return time.Time{}, time.Time{}, fmt.Errorf("period must not exceed 31 days")
+	}
+	return start.UTC(), end.UTC(), nil
+}
+
+// GetEvents returns the billable events of a period as JSON lines, in the exported schema
+// GET /api/v1/admin/metering/events?start_time=...&end_time=...
+func (h *MeteringHandler) GetEvents(c fiber.Ctx) error {
+	start, end, err := parseMeteringPeriod(c.Query("start_time"), c.Query("end_time"))
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	events, err := h.collector.Collect(c.RequestCtx(), start, end)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to collect metering events")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to collect metering events", ErrCodeOperationFailed)
+	}
+
+	data, err := metering.EncodeJSONLines(events, h.instance)
+	if err != nil {
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to encode metering events: %v", err), ErrCodeOperationFailed)
+	}
+
+	c.Set("Content-Type", "application/x-ndjson")
+	return c.Send(data)
+}
+
+// ListExports returns the most recent metering exports
+// GET /api/v1/admin/metering/exports?limit=50
+func (h *MeteringHandler) ListExports(c fiber.Ctx) error {
+	if h.exporter == nil {
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "Metering exports are not enabled", ErrCodeFeatureDisabled)
+	}
+
+	limit := 50
+	if v := c.Query("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l <= 0 {
+			return SendBadRequest(c, "limit must be a positive integer", ErrCodeInvalidInput)
+		}
+		limit = min(l, 1000)
+	}
+
+	exports, err := h.exporter.ListExports(c.RequestCtx(), limit)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list metering exports")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to list metering exports", ErrCodeOperationFailed)
+	}
+
+	return c.JSON(fiber.Map{
+		"exports": exports,
+	})
+}
+
+// ExportPeriod re-runs the export of one period, e.g. after a destination outage
+// POST /api/v1/admin/metering/exports {"start_time": "...", "end_time": "..."}
+func (h *MeteringHandler) ExportPeriod(c fiber.Ctx) error {
+	if h.exporter == nil {
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "Metering exports are not enabled", ErrCodeFeatureDisabled)
+	}
+
+	var req struct {
+		StartTime string `json:"start_time"`
+		EndTime   string `json:"end_time"`
+	}
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+
+	start, end, err := parseMeteringPeriod(req.StartTime, req.EndTime)
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	// The latest exported period is the export cursor, so a future period would skip the ones before it
+	if end.After(time.Now()) {
+		return SendBadRequest(c, "end_time must not be in the future", ErrCodeInvalidInput)
+	}
+
+	record, err := h.exporter.ExportPeriod(c.RequestCtx(), start, end)
+	if err != nil {
+		log.Error().Err(err).Msg("Manual metering export failed")
+		return SendErrorWithCode(c, fiber.StatusBadGateway, fmt.Sprintf("Metering export failed: %v", err), ErrCodeUpstreamError)
+	}
+
+	return c.JSON(record)
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMeteringPeriod(t *testing.T) {
+	t.Run("valid period", func(t *testing.T) {
+		start, end, err := parseMeteringPeriod("2026-03-01T10:00:00+01:00", "2026-03-01T11:00:00+01:00")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC), start)
+		assert.Equal(t, time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), end)
+	})
+
+	tests := []struct {
+		name, start, end, wantErr string
+	}{
+		{"missing start", "", "2026-03-01T11:00:00Z", "required"},
+		{"invalid start", "yesterday", "2026-03-01T11:00:00Z", "start_time"},
+		{"invalid end", "2026-03-01T10:00:00Z", "now", "end_time"},
+		{"end before start", "2026-03-01T11:00:00Z", "2026-03-01T10:00:00Z", "after start_time"},
+		{"too long", "2026-01-01T00:00:00Z", "2026-03-01T00:00:00Z", "31 days"},
+	}
+	for _, tt := range tests {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			_, _, err := parseMeteringPeriod(tt.start, tt.end)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestMeteringHandler_ExportsDisabled(t *testing.T) {
+	h := NewMeteringHandler(nil, nil, "")
+	app := fiber.New()
+	app.Get("/metering/exports", h.ListExports)
+	app.Post("/metering/exports", h.ExportPeriod)
+
+	for _, method := range []string{"GET", "POST"} {
+		t.Run(method, func(t *testing.T) {
+			req := httptest.NewRequest(method, "/metering/exports", strings.NewReader(`{}`))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+			assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+		})
+	}
+}
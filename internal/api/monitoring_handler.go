@@ -9,6 +9,7 @@ import (
 	"github.com/gofiber/fiber/v3"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/nimbleflux/fluxbase/internal/database"
 	"github.com/nimbleflux/fluxbase/internal/jobs"
 	"github.com/nimbleflux/fluxbase/internal/logging"
 	"github.com/nimbleflux/fluxbase/internal/middleware"
@@ -23,6 +24,9 @@ type MonitoringHandler struct {
 	storageProvider storage.Provider
 	loggingService  *logging.Service // Optional - may be nil if logging not configured
 	jobsStorage     *jobs.Storage    // Optional - may be nil if jobs not enabled
+
+	// Optional - reports read replica health when replicas are configured
+	replicaStatus func() []database.ReplicaStatus
 }
 
 // NewMonitoringHandler creates a new monitoring handler
@@ -44,6 +48,11 @@ func (h *MonitoringHandler) SetJobsStorage(jobsStorage *jobs.Storage) {
 	h.jobsStorage = jobsStorage
 }
 
+// SetReplicaStatus sets the source of read replica health for the health endpoint
+func (h *MonitoringHandler) SetReplicaStatus(fn func() []database.ReplicaStatus) {
+	h.replicaStatus = fn
+}
+
 // RegisterRoutes registers monitoring routes with authentication
 func (h *MonitoringHandler) RegisterRoutes(app *fiber.App, authService *auth.Service, clientKeyService *auth.ClientKeyService, db *pgxpool.Pool, jwtManager *auth.JWTManager) {
 	// Apply authentication middleware to all monitoring routes
@@ -132,16 +141,12 @@ func (h *MonitoringHandler) GetMetrics(c fiber.Ctx) error {
 	// Check if user has admin role
 	role, _ := c.Locals("user_role").(string)
 	if role != "admin" && role != "dashboard_admin" && role != "service_role" {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Admin access required to view system metrics",
-		})
+		return SendForbidden(c, "Admin access required to view system metrics", ErrCodeAdminRequired)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	var m runtime.MemStats
@@ -234,16 +239,12 @@ func (h *MonitoringHandler) GetHealth(c fiber.Ctx) error {
 	// Check if user has admin role
 	role, _ := c.Locals("user_role").(string)
 	if role != "admin" && role != "dashboard_admin" && role != "service_role" {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Admin access required to view system health",
-		})
+		return SendForbidden(c, "Admin access required to view system health", ErrCodeAdminRequired)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	health := SystemHealth{
@@ -273,6 +274,24 @@ func (h *MonitoringHandler) GetHealth(c fiber.Ctx) error {
 		}
 	}
 
+	// Read replicas out of rotation degrade read capacity, reads fall back to the primary
+	if h.replicaStatus != nil {
+		for _, replica := range h.replicaStatus() {
+			status := HealthStatus{
+				Status:  "healthy",
+				Message: fmt.Sprintf("replication lag %.1fs", replica.LagSeconds),
+			}
+			if !replica.Healthy {
+				status.Status = "degraded"
+				status.Message = replica.Error
+				if health.Status == "healthy" {
+					health.Status = "degraded"
+				}
+			}
+			health.Services["database_replica:"+replica.Name] = status
+		}
+	}
+
 	// Check realtime health
 	health.Services["realtime"] = HealthStatus{
 		Status:  "healthy",
@@ -358,9 +377,7 @@ func (h *MonitoringHandler) GetLogs(c fiber.Ctx) error {
 	// Check if user has admin role
 	role, _ := c.Locals("user_role").(string)
 	if role != "admin" && role != "dashboard_admin" && role != "service_role" {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Admin access required to view logs",
-		})
+		return SendForbidden(c, "Admin access required to view logs", ErrCodeAdminRequired)
 	}
 
 	// Check if logging service is available
@@ -420,9 +437,7 @@ func (h *MonitoringHandler) GetLogs(c fiber.Ctx) error {
 	// Query logs from storage
 	result, err := h.loggingService.Storage().Query(c.RequestCtx(), opts)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to query logs: " + err.Error(),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to query logs: "+err.Error(), ErrCodeOperationFailed)
 	}
 
 	// Convert to response format
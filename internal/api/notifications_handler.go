@@ -0,0 +1,70 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/notifications"
+	"github.com/rs/zerolog/log"
+)
+
+// NotificationsHandler exposes the dashboard notification center
+type NotificationsHandler struct {
+	store *notifications.Store
+}
+
+// NewNotificationsHandler creates a new notifications handler
+func NewNotificationsHandler(store *notifications.Store) *NotificationsHandler {
+	return &NotificationsHandler{store: store}
+}
+
+// ListNotifications returns open notifications, most recently updated first
+// GET /api/v1/admin/notifications?include_resolved=false&include_dismissed=false&limit=100
+func (h *NotificationsHandler) ListNotifications(c fiber.Ctx) error {
+	opts := notifications.ListOptions{
+		IncludeResolved:  fiber.Query[bool](c, "include_resolved", false),
+		IncludeDismissed: fiber.Query[bool](c, "include_dismissed", false),
+		Limit:            100,
+	}
+	if v := c.Query("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l <= 0 {
+			return SendBadRequest(c, "limit must be a positive integer", ErrCodeInvalidInput)
+		}
+		opts.Limit = min(l, 1000)
+	}
+
+	list, err := h.store.List(c.RequestCtx(), opts)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list notifications")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to list notifications", ErrCodeOperationFailed)
+	}
+
+	return c.JSON(fiber.Map{
+		"notifications": list,
+		"count":         len(list),
+	})
+}
+
+// DismissNotification hides a notification until its condition is raised again
+// POST /api/v1/admin/notifications/:id/dismiss
+func (h *NotificationsHandler) DismissNotification(c fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return SendBadRequest(c, "Invalid notification ID", ErrCodeInvalidID)
+	}
+
+	found, err := h.store.Dismiss(c.RequestCtx(), id, getUserIDFromContext(c))
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("Failed to dismiss notification")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to dismiss notification", ErrCodeOperationFailed)
+	}
+	if !found {
+		return SendNotFound(c, "Notification not found")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+	})
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/notifications"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationsHandler_RejectsInvalidInput(t *testing.T) {
+	h := NewNotificationsHandler(notifications.NewStore(nil))
+	app := fiber.New()
+	app.Get("/notifications", h.ListNotifications)
+	app.Post("/notifications/:id/dismiss", h.DismissNotification)
+
+	tests := []struct {
+		name, method, path string
+	}{
+		{"non-numeric limit", "GET", "/notifications?limit=abc"},
+		{"zero limit", "GET", "/notifications?limit=0"},
+		{"invalid notification id", "POST", "/notifications/not-a-uuid/dismiss"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+	}
+}
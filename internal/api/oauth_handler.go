@@ -128,9 +128,7 @@ func (h *OAuthHandler) Authorize(c fiber.Ctx) error {
 	oauthConfig, err := h.getProviderConfig(ctx, providerName)
 	if err != nil {
 		log.Error().Err(err).Str("provider", providerName).Msg("Failed to get OAuth provider config")
-		return c.Status(400).JSON(fiber.Map{
-			"error": fmt.Sprintf("OAuth provider '%s' not configured or disabled", providerName),
-		})
+		return SendBadRequest(c, fmt.Sprintf("OAuth provider '%s' not configured or disabled", providerName), ErrCodeInvalidInput)
 	}
 
 	// Override redirect URL if custom redirect_uri is provided
@@ -146,18 +144,14 @@ func (h *OAuthHandler) Authorize(c fiber.Ctx) error {
 	state, err := auth.GenerateState()
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to generate OAuth state")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to initiate OAuth flow",
-		})
+		return SendErrorWithCode(c, 500, "Failed to initiate OAuth flow", ErrCodeOperationFailed)
 	}
 
 	// Store state with optional redirect URI for callback validation
 	metadata := auth.StateMetadata{RedirectURI: redirectURI}
 	if err := h.stateStore.Set(ctx, state, metadata); err != nil {
 		log.Error().Err(err).Msg("Failed to store OAuth state")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to initiate OAuth flow",
-		})
+		return SendErrorWithCode(c, 500, "Failed to initiate OAuth flow", ErrCodeOperationFailed)
 	}
 
 	// Build auth URL options
@@ -204,6 +198,7 @@ func (h *OAuthHandler) Callback(c fiber.Ctx) error {
 
 		return c.Status(400).JSON(fiber.Map{
 			"error":       "OAuth authentication failed",
+			"code":        ErrCodeInvalidInput,
 			"description": errorDesc,
 		})
 	}
@@ -212,25 +207,19 @@ func (h *OAuthHandler) Callback(c fiber.Ctx) error {
 	stateMetadata, valid := h.stateStore.GetAndValidate(ctx, state)
 	if !valid {
 		log.Warn().Str("provider", providerName).Str("state", state).Msg("Invalid OAuth state")
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid OAuth state parameter",
-		})
+		return SendBadRequest(c, "Invalid OAuth state parameter", ErrCodeInvalidInput)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	// Get OAuth provider configuration
 	oauthConfig, err := h.getProviderConfig(ctx, providerName)
 	if err != nil {
 		log.Error().Err(err).Str("provider", providerName).Msg("Failed to get OAuth provider config")
-		return c.Status(400).JSON(fiber.Map{
-			"error": "OAuth provider not configured",
-		})
+		return SendBadRequest(c, "OAuth provider not configured", ErrCodeInvalidInput)
 	}
 
 	// Determine redirect_uri to use (query parameter takes precedence over state metadata for SDK compatibility)
@@ -258,18 +247,14 @@ func (h *OAuthHandler) Callback(c fiber.Ctx) error {
 	token, err := oauthConfig.Exchange(ctx, code)
 	if err != nil {
 		log.Error().Err(err).Str("provider", providerName).Msg("Failed to exchange OAuth code")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to complete OAuth authentication",
-		})
+		return SendErrorWithCode(c, 500, "Failed to complete OAuth authentication", ErrCodeOperationFailed)
 	}
 
 	// Get user info from OAuth provider
 	userInfo, err := h.getUserInfo(ctx, providerName, oauthConfig, token)
 	if err != nil {
 		log.Error().Err(err).Str("provider", providerName).Msg("Failed to get user info from OAuth provider")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to retrieve user information",
-		})
+		return SendErrorWithCode(c, 500, "Failed to retrieve user information", ErrCodeOperationFailed)
 	}
 
 	// Extract email and provider user ID
@@ -281,9 +266,7 @@ func (h *OAuthHandler) Callback(c fiber.Ctx) error {
 			Str("provider", providerName).
 			Interface("userInfo", userInfo).
 			Msg("Missing required user information from OAuth provider")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "OAuth provider did not return required user information",
-		})
+		return SendInternalError(c, "OAuth provider did not return required user information")
 	}
 
 	// RBAC: Fetch provider RBAC config and validate claims if configured (OPTIONAL for app users)
@@ -337,9 +320,7 @@ func (h *OAuthHandler) Callback(c fiber.Ctx) error {
 					Str("provider", providerName).
 					Interface("claims", idTokenClaims).
 					Msg("App OAuth access denied due to claims validation")
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-					"error": err.Error(),
-				})
+				return SendForbidden(c, err.Error(), ErrCodeAccessDenied)
 			}
 		}
 	}
@@ -348,9 +329,7 @@ func (h *OAuthHandler) Callback(c fiber.Ctx) error {
 	user, isNewUser, err := h.createOrLinkOAuthUser(ctx, providerName, providerUserID, email, userInfo, token)
 	if err != nil {
 		log.Error().Err(err).Str("provider", providerName).Str("email", email).Msg("Failed to create/link OAuth user")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to create user account",
-		})
+		return SendErrorWithCode(c, 500, "Failed to create user account", ErrCodeOperationFailed)
 	}
 
 	// Generate JWT tokens and create session in database
@@ -358,9 +337,7 @@ func (h *OAuthHandler) Callback(c fiber.Ctx) error {
 	tokenResp, err := h.authSvc.GenerateTokensForUser(ctx, user.ID)
 	if err != nil {
 		log.Error().Err(err).Str("user_id", user.ID).Msg("Failed to generate tokens and create session")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to generate authentication token",
-		})
+		return SendErrorWithCode(c, 500, "Failed to generate authentication token", ErrCodeOperationFailed)
 	}
 
 	log.Info().
@@ -386,9 +363,7 @@ func (h *OAuthHandler) ListEnabledProviders(c fiber.Ctx) error {
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	// SECURITY: Only list providers that allow app login
@@ -402,9 +377,7 @@ func (h *OAuthHandler) ListEnabledProviders(c fiber.Ctx) error {
 	rows, err := h.db.Query(ctx, query)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list enabled OAuth providers")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to retrieve OAuth providers",
-		})
+		return SendErrorWithCode(c, 500, "Failed to retrieve OAuth providers", ErrCodeOperationFailed)
 	}
 	defer rows.Close()
 
@@ -718,9 +691,7 @@ func (h *OAuthHandler) Logout(c fiber.Ctx) error {
 	// Get user ID from JWT
 	userID := c.Locals("user_id")
 	if userID == nil || userID.(string) == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 	userIDStr := userID.(string)
 
@@ -732,9 +703,7 @@ func (h *OAuthHandler) Logout(c fiber.Ctx) error {
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	// Get provider configuration to check for SLO endpoints
@@ -748,9 +717,7 @@ func (h *OAuthHandler) Logout(c fiber.Ctx) error {
 	`, providerName).Scan(&clientID, &clientSecret, &revocationEndpoint, &endSessionEndpoint, &isEncrypted)
 	if err != nil {
 		log.Error().Err(err).Str("provider", providerName).Msg("Failed to get OAuth provider for logout")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": fmt.Sprintf("OAuth provider '%s' not found or disabled", providerName),
-		})
+		return SendBadRequest(c, fmt.Sprintf("OAuth provider '%s' not found or disabled", providerName), ErrCodeInvalidInput)
 	}
 
 	// Use default endpoints if not configured
@@ -890,18 +857,14 @@ func (h *OAuthHandler) LogoutCallback(c fiber.Ctx) error {
 
 	if state == "" {
 		log.Warn().Str("provider", providerName).Msg("OAuth logout callback missing state parameter")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Missing state parameter",
-		})
+		return SendBadRequest(c, "Missing state parameter", ErrCodeMissingField)
 	}
 
 	// Validate and consume the state
 	logoutState, err := h.logoutService.ValidateLogoutState(ctx, state)
 	if err != nil {
 		log.Warn().Err(err).Str("provider", providerName).Str("state", state).Msg("Invalid or expired logout state")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid or expired logout state",
-		})
+		return SendBadRequest(c, "Invalid or expired logout state", ErrCodeInvalidInput)
 	}
 
 	log.Info().
@@ -949,24 +912,18 @@ func (h *OAuthHandler) GetProviderToken(c fiber.Ctx) error {
 
 	userID := c.Locals("user_id")
 	if userID == nil || userID.(string) == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 	userIDStr := userID.(string)
 
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	oauthConfig, err := h.getProviderConfigForToken(ctx, providerName)
 	if err != nil {
 		log.Error().Err(err).Str("provider", providerName).Msg("Failed to get OAuth provider config for token retrieval")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": fmt.Sprintf("OAuth provider '%s' not configured or disabled", providerName),
-		})
+		return SendBadRequest(c, fmt.Sprintf("OAuth provider '%s' not configured or disabled", providerName), ErrCodeInvalidInput)
 	}
 
 	storedToken, err := h.logoutService.GetUserOAuthToken(ctx, userIDStr, providerName)
@@ -974,6 +931,7 @@ func (h *OAuthHandler) GetProviderToken(c fiber.Ctx) error {
 		if errors.Is(err, auth.ErrOAuthTokenNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error":         "No OAuth token found for this provider",
+				"code":          ErrCodeNotFound,
 				"error_code":    "oauth_token_not_found",
 				"error_hint":    "You need to sign in with this provider first",
 				"provider":      providerName,
@@ -981,9 +939,7 @@ func (h *OAuthHandler) GetProviderToken(c fiber.Ctx) error {
 			})
 		}
 		log.Error().Err(err).Str("provider", providerName).Str("user_id", userIDStr).Msg("Failed to get stored OAuth token")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve OAuth token",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve OAuth token", ErrCodeOperationFailed)
 	}
 
 	accessToken := storedToken.AccessToken
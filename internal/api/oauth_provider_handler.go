@@ -114,8 +114,9 @@ type OAuthProvider struct {
 	DisplayName         string              `json:"display_name"`
 	Enabled             bool                `json:"enabled"`
 	ClientID            string              `json:"client_id"`
-	ClientSecret        string              `json:"client_secret,omitempty"` // Omitted in GET responses
-	HasSecret           bool                `json:"has_secret"`              // Indicates if a client secret is set
+	ClientSecret        string              `json:"client_secret,omitempty"`            // Omitted in GET responses
+	HasSecret           bool                `json:"has_secret"`                         // Indicates if a client secret is set
+	SecretExpiresAt     *time.Time          `json:"client_secret_expires_at,omitempty"` // Rotation date of the client secret
 	RedirectURL         string              `json:"redirect_url"`
 	Scopes              []string            `json:"scopes"`
 	IsCustom            bool                `json:"is_custom"`
@@ -140,6 +141,7 @@ type CreateOAuthProviderRequest struct {
 	Enabled             bool                `json:"enabled"`
 	ClientID            string              `json:"client_id"`
 	ClientSecret        string              `json:"client_secret"`
+	SecretExpiresAt     *time.Time          `json:"client_secret_expires_at,omitempty"` // Rotation date of the client secret
 	RedirectURL         string              `json:"redirect_url"`
 	Scopes              []string            `json:"scopes"`
 	IsCustom            bool                `json:"is_custom"`
@@ -160,6 +162,7 @@ type UpdateOAuthProviderRequest struct {
 	Enabled             *bool               `json:"enabled,omitempty"`
 	ClientID            *string             `json:"client_id,omitempty"`
 	ClientSecret        *string             `json:"client_secret,omitempty"`
+	SecretExpiresAt     *time.Time          `json:"client_secret_expires_at,omitempty"` // Rotation date; cleared when a new secret is set without one
 	RedirectURL         *string             `json:"redirect_url,omitempty"`
 	Scopes              []string            `json:"scopes,omitempty"`
 	AuthorizationURL    *string             `json:"authorization_url,omitempty"`
@@ -204,9 +207,7 @@ func (h *OAuthProviderHandler) ListOAuthProviders(c fiber.Ctx) error {
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	query := `
@@ -216,7 +217,8 @@ func (h *OAuthProviderHandler) ListOAuthProviders(c fiber.Ctx) error {
 		       COALESCE(allow_dashboard_login, false), COALESCE(allow_app_login, true),
 		       required_claims, denied_claims,
 		       created_at, updated_at,
-		       (client_secret IS NOT NULL AND client_secret != '') AS has_secret
+		       (client_secret IS NOT NULL AND client_secret != '') AS has_secret,
+		       client_secret_expires_at
 		FROM dashboard.oauth_providers
 		ORDER BY display_name
 	`
@@ -224,9 +226,7 @@ func (h *OAuthProviderHandler) ListOAuthProviders(c fiber.Ctx) error {
 	rows, err := h.db.Query(ctx, query)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list OAuth providers")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to retrieve OAuth providers",
-		})
+		return SendErrorWithCode(c, 500, "Failed to retrieve OAuth providers", ErrCodeOperationFailed)
 	}
 	defer rows.Close()
 
@@ -240,7 +240,7 @@ func (h *OAuthProviderHandler) ListOAuthProviders(c fiber.Ctx) error {
 			&p.TokenURL, &p.UserInfoURL, &p.RevocationEndpoint, &p.EndSessionEndpoint,
 			&p.AllowDashboardLogin, &p.AllowAppLogin,
 			&requiredClaimsJSON, &deniedClaimsJSON,
-			&p.CreatedAt, &p.UpdatedAt, &p.HasSecret,
+			&p.CreatedAt, &p.UpdatedAt, &p.HasSecret, &p.SecretExpiresAt,
 		)
 		if err != nil {
 			log.Error().Err(err).Msg("Failed to scan OAuth provider")
@@ -317,16 +317,12 @@ func (h *OAuthProviderHandler) GetOAuthProvider(c fiber.Ctx) error {
 
 	providerID, err := uuid.Parse(id)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid provider ID",
-		})
+		return SendBadRequest(c, "Invalid provider ID", ErrCodeInvalidID)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	query := `
@@ -336,7 +332,8 @@ func (h *OAuthProviderHandler) GetOAuthProvider(c fiber.Ctx) error {
 		       COALESCE(allow_dashboard_login, false), COALESCE(allow_app_login, true),
 		       required_claims, denied_claims,
 		       created_at, updated_at,
-		       (client_secret IS NOT NULL AND client_secret != '') AS has_secret
+		       (client_secret IS NOT NULL AND client_secret != '') AS has_secret,
+		       client_secret_expires_at
 		FROM dashboard.oauth_providers
 		WHERE id = $1
 	`
@@ -349,19 +346,15 @@ func (h *OAuthProviderHandler) GetOAuthProvider(c fiber.Ctx) error {
 		&p.TokenURL, &p.UserInfoURL, &p.RevocationEndpoint, &p.EndSessionEndpoint,
 		&p.AllowDashboardLogin, &p.AllowAppLogin,
 		&requiredClaimsJSON, &deniedClaimsJSON,
-		&p.CreatedAt, &p.UpdatedAt, &p.HasSecret,
+		&p.CreatedAt, &p.UpdatedAt, &p.HasSecret, &p.SecretExpiresAt,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
-		return c.Status(404).JSON(fiber.Map{
-			"error": "OAuth provider not found",
-		})
+		return SendNotFound(c, "OAuth provider not found")
 	}
 	if err != nil {
 		log.Error().Err(err).Str("id", id).Msg("Failed to get OAuth provider")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to retrieve OAuth provider",
-		})
+		return SendErrorWithCode(c, 500, "Failed to retrieve OAuth provider", ErrCodeOperationFailed)
 	}
 
 	// Unmarshal RBAC fields
@@ -383,39 +376,29 @@ func (h *OAuthProviderHandler) CreateOAuthProvider(c fiber.Ctx) error {
 	var req CreateOAuthProviderRequest
 
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate provider name
 	if !providerNamePattern.MatchString(req.ProviderName) {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Provider name must start with a letter and contain only lowercase letters, numbers, and underscores (2-50 chars)",
-		})
+		return SendBadRequest(c, "Provider name must start with a letter and contain only lowercase letters, numbers, and underscores (2-50 chars)", ErrCodeInvalidInput)
 	}
 
 	// Validate required fields
 	if req.DisplayName == "" || req.ClientID == "" || req.ClientSecret == "" || req.RedirectURL == "" {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Missing required fields: display_name, client_id, client_secret, redirect_url",
-		})
+		return SendBadRequest(c, "Missing required fields: display_name, client_id, client_secret, redirect_url", ErrCodeMissingField)
 	}
 
 	// For custom providers, require custom URLs
 	if req.IsCustom {
 		if req.AuthorizationURL == nil || req.TokenURL == nil || req.UserInfoURL == nil {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "Custom providers require authorization_url, token_url, and user_info_url",
-			})
+			return SendBadRequest(c, "Custom providers require authorization_url, token_url, and user_info_url", ErrCodeInvalidInput)
 		}
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	// Get user ID from context (set by auth middleware)
@@ -444,9 +427,7 @@ func (h *OAuthProviderHandler) CreateOAuthProvider(c fiber.Ctx) error {
 	encryptedSecret, err := crypto.Encrypt(req.ClientSecret, h.encryptionKey)
 	if err != nil {
 		log.Error().Err(err).Str("provider", req.ProviderName).Msg("Failed to encrypt client secret")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to encrypt client secret",
-		})
+		return SendErrorWithCode(c, 500, "Failed to encrypt client secret", ErrCodeOperationFailed)
 	}
 
 	query := `
@@ -455,8 +436,8 @@ func (h *OAuthProviderHandler) CreateOAuthProvider(c fiber.Ctx) error {
 			redirect_url, scopes, is_custom, authorization_url, token_url,
 			user_info_url, revocation_endpoint, end_session_endpoint,
 			allow_dashboard_login, allow_app_login, required_claims, denied_claims,
-			created_by, updated_by, is_encrypted
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $18, true)
+			created_by, updated_by, is_encrypted, client_secret_expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $18, true, $19)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -468,17 +449,14 @@ func (h *OAuthProviderHandler) CreateOAuthProvider(c fiber.Ctx) error {
 		req.RedirectURL, req.Scopes, req.IsCustom, req.AuthorizationURL, req.TokenURL,
 		req.UserInfoURL, req.RevocationEndpoint, req.EndSessionEndpoint,
 		allowDashboardLogin, allowAppLogin, requiredClaimsJSON, deniedClaimsJSON, userID,
+		req.SecretExpiresAt,
 	).Scan(&id, &createdAt, &updatedAt)
 	if err != nil {
 		if strings.Contains(err.Error(), "duplicate key") {
-			return c.Status(409).JSON(fiber.Map{
-				"error": fmt.Sprintf("OAuth provider '%s' already exists", req.ProviderName),
-			})
+			return SendConflict(c, fmt.Sprintf("OAuth provider '%s' already exists", req.ProviderName), ErrCodeAlreadyExists)
 		}
 		log.Error().Err(err).Str("provider", req.ProviderName).Msg("Failed to create OAuth provider")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to create OAuth provider",
-		})
+		return SendErrorWithCode(c, 500, "Failed to create OAuth provider", ErrCodeOperationFailed)
 	}
 
 	log.Info().Str("id", id.String()).Str("provider", req.ProviderName).Msg("OAuth provider created")
@@ -500,35 +478,27 @@ func (h *OAuthProviderHandler) UpdateOAuthProvider(c fiber.Ctx) error {
 
 	providerID, err := uuid.Parse(id)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid provider ID",
-		})
+		return SendBadRequest(c, "Invalid provider ID", ErrCodeInvalidID)
 	}
 
 	var req UpdateOAuthProviderRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate that at least one field is provided
 	if req.DisplayName == nil && req.Enabled == nil && req.ClientID == nil &&
-		req.ClientSecret == nil && req.RedirectURL == nil && req.Scopes == nil &&
+		req.ClientSecret == nil && req.SecretExpiresAt == nil && req.RedirectURL == nil && req.Scopes == nil &&
 		req.AuthorizationURL == nil && req.TokenURL == nil && req.UserInfoURL == nil &&
 		req.RevocationEndpoint == nil && req.EndSessionEndpoint == nil &&
 		req.AllowDashboardLogin == nil && req.AllowAppLogin == nil &&
 		req.RequiredClaims == nil && req.DeniedClaims == nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "No fields to update",
-		})
+		return SendBadRequest(c, "No fields to update", ErrCodeInvalidInput)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	// Build dynamic update query
@@ -556,9 +526,7 @@ func (h *OAuthProviderHandler) UpdateOAuthProvider(c fiber.Ctx) error {
 		encryptedSecret, encErr := crypto.Encrypt(*req.ClientSecret, h.encryptionKey)
 		if encErr != nil {
 			log.Error().Err(encErr).Msg("Failed to encrypt client secret")
-			return c.Status(500).JSON(fiber.Map{
-				"error": "Failed to encrypt client secret",
-			})
+			return SendErrorWithCode(c, 500, "Failed to encrypt client secret", ErrCodeOperationFailed)
 		}
 		updates = append(updates, fmt.Sprintf("client_secret = $%d", argPos))
 		args = append(args, encryptedSecret)
@@ -566,6 +534,15 @@ func (h *OAuthProviderHandler) UpdateOAuthProvider(c fiber.Ctx) error {
 		updates = append(updates, fmt.Sprintf("is_encrypted = $%d", argPos))
 		args = append(args, true)
 		argPos++
+		// A new secret has its own rotation date; the old one no longer applies
+		if req.SecretExpiresAt == nil {
+			updates = append(updates, "client_secret_expires_at = NULL")
+		}
+	}
+	if req.SecretExpiresAt != nil {
+		updates = append(updates, fmt.Sprintf("client_secret_expires_at = $%d", argPos))
+		args = append(args, *req.SecretExpiresAt)
+		argPos++
 	}
 	if req.RedirectURL != nil {
 		updates = append(updates, fmt.Sprintf("redirect_url = $%d", argPos))
@@ -639,15 +616,11 @@ func (h *OAuthProviderHandler) UpdateOAuthProvider(c fiber.Ctx) error {
 	err = h.db.QueryRow(ctx, query, args...).Scan(&displayName)
 
 	if errors.Is(err, sql.ErrNoRows) {
-		return c.Status(404).JSON(fiber.Map{
-			"error": "OAuth provider not found",
-		})
+		return SendNotFound(c, "OAuth provider not found")
 	}
 	if err != nil {
 		log.Error().Err(err).Str("id", id).Msg("Failed to update OAuth provider")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to update OAuth provider",
-		})
+		return SendErrorWithCode(c, 500, "Failed to update OAuth provider", ErrCodeOperationFailed)
 	}
 
 	log.Info().Str("id", id).Msg("OAuth provider updated")
@@ -665,16 +638,12 @@ func (h *OAuthProviderHandler) DeleteOAuthProvider(c fiber.Ctx) error {
 
 	providerID, err := uuid.Parse(id)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid provider ID",
-		})
+		return SendBadRequest(c, "Invalid provider ID", ErrCodeInvalidID)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	query := "DELETE FROM dashboard.oauth_providers WHERE id = $1 RETURNING display_name"
@@ -683,15 +652,11 @@ func (h *OAuthProviderHandler) DeleteOAuthProvider(c fiber.Ctx) error {
 	err = h.db.QueryRow(ctx, query, providerID).Scan(&displayName)
 
 	if errors.Is(err, sql.ErrNoRows) {
-		return c.Status(404).JSON(fiber.Map{
-			"error": "OAuth provider not found",
-		})
+		return SendNotFound(c, "OAuth provider not found")
 	}
 	if err != nil {
 		log.Error().Err(err).Str("id", id).Msg("Failed to delete OAuth provider")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to delete OAuth provider",
-		})
+		return SendErrorWithCode(c, 500, "Failed to delete OAuth provider", ErrCodeOperationFailed)
 	}
 
 	log.Info().Str("id", id).Str("provider", displayName).Msg("OAuth provider deleted")
@@ -708,18 +673,14 @@ func (h *OAuthProviderHandler) GetAuthSettings(c fiber.Ctx) error {
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	query := "SELECT key, value FROM app.settings WHERE category = 'auth'"
 	rows, err := h.db.Query(ctx, query)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get auth settings")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to retrieve auth settings",
-		})
+		return SendErrorWithCode(c, 500, "Failed to retrieve auth settings", ErrCodeOperationFailed)
 	}
 	defer rows.Close()
 
@@ -818,16 +779,12 @@ func (h *OAuthProviderHandler) UpdateAuthSettings(c fiber.Ctx) error {
 	var req AuthSettings
 
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	// Check for environment variable overrides before updating
@@ -863,15 +820,10 @@ func (h *OAuthProviderHandler) UpdateAuthSettings(c fiber.Ctx) error {
 		hasSSO, err := h.hasDashboardSSOProviders(ctx)
 		if err != nil {
 			log.Error().Err(err).Msg("Failed to check dashboard SSO providers")
-			return c.Status(500).JSON(fiber.Map{
-				"error": "Failed to verify SSO providers",
-			})
+			return SendErrorWithCode(c, 500, "Failed to verify SSO providers", ErrCodeOperationFailed)
 		}
 		if !hasSSO {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "Cannot disable password login: No SSO providers are configured for dashboard login. Configure at least one OAuth or SAML provider with 'Allow dashboard login' enabled first.",
-				"code":  "NO_SSO_PROVIDERS",
-			})
+			return SendBadRequest(c, "Cannot disable password login: No SSO providers are configured for dashboard login. Configure at least one OAuth or SAML provider with 'Allow dashboard login' enabled first.", "NO_SSO_PROVIDERS")
 		}
 	}
 
@@ -880,15 +832,10 @@ func (h *OAuthProviderHandler) UpdateAuthSettings(c fiber.Ctx) error {
 		hasSSO, err := h.hasAppSSOProviders(ctx)
 		if err != nil {
 			log.Error().Err(err).Msg("Failed to check app SSO providers")
-			return c.Status(500).JSON(fiber.Map{
-				"error": "Failed to verify SSO providers",
-			})
+			return SendErrorWithCode(c, 500, "Failed to verify SSO providers", ErrCodeOperationFailed)
 		}
 		if !hasSSO {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "Cannot disable password login: No OAuth or SAML providers are configured for app login. Configure at least one OAuth or SAML provider with 'Allow app login' enabled first.",
-				"code":  "NO_APP_SSO_PROVIDERS",
-			})
+			return SendBadRequest(c, "Cannot disable password login: No OAuth or SAML providers are configured for app login. Configure at least one OAuth or SAML provider with 'Allow app login' enabled first.", "NO_APP_SSO_PROVIDERS")
 		}
 	}
 
@@ -911,9 +858,7 @@ func (h *OAuthProviderHandler) UpdateAuthSettings(c fiber.Ctx) error {
 		_, err := h.db.Exec(ctx, upsertQuery, key, value)
 		if err != nil {
 			log.Error().Err(err).Str("setting", key).Msg("Failed to upsert auth setting")
-			return c.Status(500).JSON(fiber.Map{
-				"error": fmt.Sprintf("Failed to update setting: %s", key),
-			})
+			return SendErrorWithCode(c, 500, fmt.Sprintf("Failed to update setting: %s", key), ErrCodeOperationFailed)
 		}
 	}
 
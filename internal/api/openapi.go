@@ -7,6 +7,7 @@ import (
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/rs/zerolog/log"
 )
 
 // OpenAPISpec represents the OpenAPI 3.0 specification
@@ -105,9 +106,21 @@ func (h *OpenAPIHandler) GetOpenAPISpec(c fiber.Ctx) error {
 	// Get all tables (admin only)
 	tables, err := inspector.GetAllTables(ctx, "public", "auth")
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to fetch database schema",
-		})
+		return SendErrorWithCode(c, 500, "Failed to fetch database schema", ErrCodeOperationFailed)
+	}
+
+	// Views and materialized views are served by the same endpoints
+	views, err := inspector.GetAllViews(ctx, "public", "auth")
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to get views for OpenAPI spec")
+	} else {
+		tables = append(tables, views...)
+	}
+	matViews, err := inspector.GetAllMaterializedViews(ctx, "public", "auth")
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to get materialized views for OpenAPI spec")
+	} else {
+		tables = append(tables, matViews...)
 	}
 
 	spec := h.generateSpec(tables, c.BaseURL())
@@ -1023,6 +1036,11 @@ func (h *OpenAPIHandler) addAPIKeyEndpoints(spec *OpenAPISpec) {
 			"key_prefix":            map[string]string{"type": "string"},
 			"scopes":                map[string]interface{}{"type": "array", "items": map[string]string{"type": "string"}},
 			"rate_limit_per_minute": map[string]string{"type": "integer"},
+			"json_key_case":         map[string]interface{}{"type": "string", "enum": []string{"snake", "camel"}},
+			"allowed_tables":        map[string]interface{}{"type": "object", "description": "Tables the key is limited to, as schema.table or schema.* mapped to read or write", "additionalProperties": map[string]interface{}{"type": "string", "enum": []string{"read", "write"}}},
+			"allowed_buckets":       map[string]interface{}{"type": "array", "description": "Storage buckets the key is limited to; a trailing * matches by prefix", "items": map[string]string{"type": "string"}},
+			"allowed_ips":           map[string]interface{}{"type": "array", "description": "IP addresses and CIDR ranges the key can be used from", "items": map[string]string{"type": "string"}},
+			"rotated_to":            map[string]string{"type": "string", "format": "uuid", "description": "Key that replaced this one when it was rotated"},
 			"expires_at":            map[string]string{"type": "string", "format": "date-time"},
 			"created_at":            map[string]string{"type": "string", "format": "date-time"},
 			"last_used_at":          map[string]string{"type": "string", "format": "date-time"},
@@ -1039,6 +1057,10 @@ func (h *OpenAPIHandler) addAPIKeyEndpoints(spec *OpenAPISpec) {
 			"scopes":                map[string]interface{}{"type": "array", "items": map[string]string{"type": "string"}},
 			"rate_limit_per_minute": map[string]string{"type": "integer"},
 			"expires_at":            map[string]string{"type": "string", "format": "date-time"},
+			"json_key_case":         map[string]interface{}{"type": "string", "enum": []string{"snake", "camel"}},
+			"allowed_tables":        map[string]interface{}{"type": "object", "description": "Tables the key is limited to, as schema.table or schema.* mapped to read or write", "additionalProperties": map[string]interface{}{"type": "string", "enum": []string{"read", "write"}}},
+			"allowed_buckets":       map[string]interface{}{"type": "array", "description": "Storage buckets the key is limited to; a trailing * matches by prefix", "items": map[string]string{"type": "string"}},
+			"allowed_ips":           map[string]interface{}{"type": "array", "description": "IP addresses and CIDR ranges the key can be used from", "items": map[string]string{"type": "string"}},
 		},
 	}
 
@@ -1051,6 +1073,7 @@ func (h *OpenAPIHandler) addAPIKeyEndpoints(spec *OpenAPISpec) {
 			"key_prefix":            map[string]string{"type": "string"},
 			"scopes":                map[string]interface{}{"type": "array", "items": map[string]string{"type": "string"}},
 			"rate_limit_per_minute": map[string]string{"type": "integer"},
+			"json_key_case":         map[string]interface{}{"type": "string", "enum": []string{"snake", "camel"}},
 			"expires_at":            map[string]string{"type": "string", "format": "date-time"},
 			"created_at":            map[string]string{"type": "string", "format": "date-time"},
 		},
@@ -1163,6 +1186,10 @@ func (h *OpenAPIHandler) addAPIKeyEndpoints(spec *OpenAPISpec) {
 								"description":           map[string]string{"type": "string"},
 								"scopes":                map[string]interface{}{"type": "array", "items": map[string]string{"type": "string"}},
 								"rate_limit_per_minute": map[string]string{"type": "integer"},
+								"json_key_case":         map[string]interface{}{"type": "string", "enum": []string{"snake", "camel"}},
+								"allowed_tables":        map[string]interface{}{"type": "object", "description": "Tables the key is limited to, as schema.table or schema.* mapped to read or write", "additionalProperties": map[string]interface{}{"type": "string", "enum": []string{"read", "write"}}},
+								"allowed_buckets":       map[string]interface{}{"type": "array", "description": "Storage buckets the key is limited to; a trailing * matches by prefix", "items": map[string]string{"type": "string"}},
+								"allowed_ips":           map[string]interface{}{"type": "array", "description": "IP addresses and CIDR ranges the key can be used from", "items": map[string]string{"type": "string"}},
 							},
 						},
 					},
@@ -1223,6 +1250,47 @@ func (h *OpenAPIHandler) addAPIKeyEndpoints(spec *OpenAPISpec) {
 			},
 		},
 	}
+
+	// POST /api/v1/client-keys/:id/rotate
+	spec.Paths["/api/v1/client-keys/{id}/rotate"] = OpenAPIPath{
+		"post": OpenAPIOperation{
+			Summary:     "Rotate API key",
+			Description: "Replace an API key with a new key that has the same name, scopes, restrictions and expiry. The old key stays valid for grace_period seconds (default 86400, max 2592000) and then expires. The new key is only returned once. Users can only rotate their own keys.",
+			OperationID: "apikeys_rotate",
+			Tags:        []string{"Client Keys"},
+			Security: []map[string][]string{
+				{"bearerAuth": {}},
+			},
+			Parameters: []OpenAPIParameter{
+				{Name: "id", In: "path", Required: true, Schema: map[string]string{"type": "string", "format": "uuid"}},
+			},
+			RequestBody: &OpenAPIRequestBody{
+				Content: map[string]OpenAPIMedia{
+					"application/json": {
+						Schema: map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"grace_period": map[string]interface{}{"type": "integer", "minimum": 0, "maximum": 2592000},
+							},
+						},
+					},
+				},
+			},
+			Responses: map[string]OpenAPIResponse{
+				"201": {
+					Description: "New API key (includes the full key)",
+					Content: map[string]OpenAPIMedia{
+						"application/json": {
+							Schema: map[string]string{"$ref": "#/components/schemas/APIKey"},
+						},
+					},
+				},
+				"409": {
+					Description: "API key is revoked, expired or already rotated",
+				},
+			},
+		},
+	}
 }
 
 // addWebhookEndpoints adds webhook management endpoints to the spec
@@ -1231,15 +1299,19 @@ func (h *OpenAPIHandler) addWebhookEndpoints(spec *OpenAPISpec) {
 	spec.Components.Schemas["Webhook"] = map[string]interface{}{
 		"type": "object",
 		"properties": map[string]interface{}{
-			"id":          map[string]string{"type": "string", "format": "uuid"},
-			"name":        map[string]string{"type": "string"},
-			"url":         map[string]string{"type": "string", "format": "uri"},
-			"events":      map[string]interface{}{"type": "array", "items": map[string]string{"type": "string"}},
-			"secret":      map[string]string{"type": "string"},
-			"is_active":   map[string]string{"type": "boolean"},
-			"created_at":  map[string]string{"type": "string", "format": "date-time"},
-			"updated_at":  map[string]string{"type": "string", "format": "date-time"},
-			"last_status": map[string]string{"type": "string"},
+			"id":                        map[string]string{"type": "string", "format": "uuid"},
+			"name":                      map[string]string{"type": "string"},
+			"url":                       map[string]string{"type": "string", "format": "uri"},
+			"events":                    map[string]interface{}{"type": "array", "items": map[string]string{"type": "string"}},
+			"secret":                    map[string]string{"type": "string"},
+			"is_active":                 map[string]string{"type": "boolean"},
+			"created_at":                map[string]string{"type": "string", "format": "date-time"},
+			"updated_at":                map[string]string{"type": "string", "format": "date-time"},
+			"last_status":               map[string]string{"type": "string"},
+			"max_retries":               map[string]string{"type": "integer"},
+			"retry_backoff_seconds":     map[string]string{"type": "integer"},
+			"retry_backoff_strategy":    map[string]interface{}{"type": "string", "enum": []string{"linear", "exponential", "fixed"}},
+			"max_retry_backoff_seconds": map[string]string{"type": "integer"},
 		},
 	}
 
@@ -1252,6 +1324,11 @@ func (h *OpenAPIHandler) addWebhookEndpoints(spec *OpenAPISpec) {
 			"payload":       map[string]string{"type": "object"},
 			"response_code": map[string]string{"type": "integer"},
 			"response_body": map[string]string{"type": "string"},
+			"status":        map[string]interface{}{"type": "string", "enum": []string{"pending", "success", "failed"}},
+			"status_code":   map[string]string{"type": "integer"},
+			"error":         map[string]string{"type": "string"},
+			"attempt":       map[string]string{"type": "integer"},
+			"replay_of":     map[string]string{"type": "string", "format": "uuid"},
 			"created_at":    map[string]string{"type": "string", "format": "date-time"},
 			"delivered_at":  map[string]string{"type": "string", "format": "date-time"},
 		},
@@ -1463,6 +1540,131 @@ func (h *OpenAPIHandler) addWebhookEndpoints(spec *OpenAPISpec) {
 			},
 		},
 	}
+
+	deliveryResponse := map[string]OpenAPIResponse{
+		"200": {
+			Description: "Delivery",
+			Content: map[string]OpenAPIMedia{
+				"application/json": {
+					Schema: map[string]string{"$ref": "#/components/schemas/WebhookDelivery"},
+				},
+			},
+		},
+		"404": {Description: "Delivery not found"},
+	}
+
+	// GET /api/v1/webhooks/deliveries
+	spec.Paths["/api/v1/webhooks/deliveries"] = OpenAPIPath{
+		"get": OpenAPIOperation{
+			Summary:     "Search webhook deliveries",
+			Description: "List deliveries across webhooks, failed ones unless status is given, with payloads and responses",
+			OperationID: "webhooks_deliveries_search",
+			Tags:        []string{"Webhooks"},
+			Security: []map[string][]string{
+				{"bearerAuth": {}},
+			},
+			Parameters: []OpenAPIParameter{
+				{Name: "status", In: "query", Schema: map[string]interface{}{"type": "string", "enum": []string{"pending", "success", "failed", "all"}}},
+				{Name: "webhook_id", In: "query", Schema: map[string]string{"type": "string", "format": "uuid"}},
+				{Name: "since", In: "query", Schema: map[string]string{"type": "string", "format": "date-time"}},
+				{Name: "until", In: "query", Schema: map[string]string{"type": "string", "format": "date-time"}},
+				{Name: "limit", In: "query", Schema: map[string]string{"type": "integer"}},
+			},
+			Responses: map[string]OpenAPIResponse{
+				"200": {
+					Description: "List of deliveries",
+					Content: map[string]OpenAPIMedia{
+						"application/json": {
+							Schema: map[string]interface{}{
+								"type":  "array",
+								"items": map[string]string{"$ref": "#/components/schemas/WebhookDelivery"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// GET /api/v1/webhooks/deliveries/:delivery_id
+	spec.Paths["/api/v1/webhooks/deliveries/{delivery_id}"] = OpenAPIPath{
+		"get": OpenAPIOperation{
+			Summary:     "Get webhook delivery",
+			Description: "Get a delivery with its payload and the endpoint's response",
+			OperationID: "webhooks_deliveries_get",
+			Tags:        []string{"Webhooks"},
+			Security: []map[string][]string{
+				{"bearerAuth": {}},
+			},
+			Parameters: []OpenAPIParameter{
+				{Name: "delivery_id", In: "path", Required: true, Schema: map[string]string{"type": "string", "format": "uuid"}},
+			},
+			Responses: deliveryResponse,
+		},
+	}
+
+	// POST /api/v1/webhooks/deliveries/:delivery_id/replay
+	spec.Paths["/api/v1/webhooks/deliveries/{delivery_id}/replay"] = OpenAPIPath{
+		"post": OpenAPIOperation{
+			Summary:     "Replay webhook delivery",
+			Description: "Send a delivery's payload to its webhook again; returns the new delivery",
+			OperationID: "webhooks_deliveries_replay",
+			Tags:        []string{"Webhooks"},
+			Security: []map[string][]string{
+				{"bearerAuth": {}},
+			},
+			Parameters: []OpenAPIParameter{
+				{Name: "delivery_id", In: "path", Required: true, Schema: map[string]string{"type": "string", "format": "uuid"}},
+			},
+			Responses: deliveryResponse,
+		},
+	}
+
+	// POST /api/v1/webhooks/deliveries/replay
+	spec.Paths["/api/v1/webhooks/deliveries/replay"] = OpenAPIPath{
+		"post": OpenAPIOperation{
+			Summary:     "Replay webhook deliveries",
+			Description: "Replay up to 100 deliveries, given by ID or by time range",
+			OperationID: "webhooks_deliveries_replay_range",
+			Tags:        []string{"Webhooks"},
+			Security: []map[string][]string{
+				{"bearerAuth": {}},
+			},
+			RequestBody: &OpenAPIRequestBody{
+				Required: true,
+				Content: map[string]OpenAPIMedia{
+					"application/json": {
+						Schema: map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"ids":        map[string]interface{}{"type": "array", "items": map[string]string{"type": "string", "format": "uuid"}},
+								"webhook_id": map[string]string{"type": "string", "format": "uuid"},
+								"status":     map[string]interface{}{"type": "string", "enum": []string{"pending", "success", "failed"}},
+								"since":      map[string]string{"type": "string", "format": "date-time"},
+								"until":      map[string]string{"type": "string", "format": "date-time"},
+							},
+						},
+					},
+				},
+			},
+			Responses: map[string]OpenAPIResponse{
+				"200": {
+					Description: "Replays",
+					Content: map[string]OpenAPIMedia{
+						"application/json": {
+							Schema: map[string]interface{}{
+								"type": "object",
+								"properties": map[string]interface{}{
+									"replayed": map[string]interface{}{"type": "array", "items": map[string]string{"$ref": "#/components/schemas/WebhookDelivery"}},
+									"count":    map[string]string{"type": "integer"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
 }
 
 // addMonitoringEndpoints adds monitoring endpoints to the spec
@@ -2348,6 +2550,7 @@ func (h *OpenAPIHandler) addAIEndpoints(spec *OpenAPISpec) {
 			},
 			Parameters: []OpenAPIParameter{
 				{Name: "chatbot_id", In: "query", Description: "Filter by chatbot", Schema: map[string]string{"type": "string", "format": "uuid"}},
+				{Name: "tag", In: "query", Description: "Comma-separated tags the conversation must all have", Schema: map[string]string{"type": "string"}},
 				{Name: "limit", In: "query", Schema: map[string]string{"type": "integer"}},
 				{Name: "offset", In: "query", Schema: map[string]string{"type": "integer"}},
 			},
@@ -3034,11 +3237,14 @@ func (h *OpenAPIHandler) addAdminEndpoints(spec *OpenAPISpec) {
 	spec.Components.Schemas["EmailTemplate"] = map[string]interface{}{
 		"type": "object",
 		"properties": map[string]interface{}{
-			"type":       map[string]string{"type": "string"},
-			"subject":    map[string]string{"type": "string"},
-			"html_body":  map[string]string{"type": "string"},
-			"text_body":  map[string]string{"type": "string"},
-			"updated_at": map[string]string{"type": "string", "format": "date-time"},
+			"template_type": map[string]string{"type": "string"},
+			"locale":        map[string]string{"type": "string"},
+			"subject":       map[string]string{"type": "string"},
+			"html_body":     map[string]string{"type": "string"},
+			"text_body":     map[string]string{"type": "string"},
+			"version":       map[string]string{"type": "integer"},
+			"is_custom":     map[string]string{"type": "boolean"},
+			"updated_at":    map[string]string{"type": "string", "format": "date-time"},
 		},
 	}
 
@@ -3477,7 +3683,8 @@ func (h *OpenAPIHandler) addAdminEndpoints(spec *OpenAPISpec) {
 				{"bearerAuth": {}},
 			},
 			Parameters: []OpenAPIParameter{
-				{Name: "type", In: "path", Required: true, Description: "Template type (e.g., welcome, password_reset)", Schema: map[string]string{"type": "string"}},
+				{Name: "type", In: "path", Required: true, Description: "Template type (magic_link, email_verification, password_reset, invitation, otp, new_device)", Schema: map[string]string{"type": "string"}},
+				{Name: "locale", In: "query", Description: "Locale variant (e.g., de, pt-BR); omit for the default variant", Schema: map[string]string{"type": "string"}},
 			},
 			Responses: map[string]OpenAPIResponse{
 				"200": {
@@ -3492,7 +3699,7 @@ func (h *OpenAPIHandler) addAdminEndpoints(spec *OpenAPISpec) {
 		},
 		"put": OpenAPIOperation{
 			Summary:     "Update email template",
-			Description: "Update an email template",
+			Description: "Save a new version of an email template or one of its locale variants",
 			OperationID: "admin_email_templates_update",
 			Tags:        []string{"Admin"},
 			Security: []map[string][]string{
@@ -3500,6 +3707,7 @@ func (h *OpenAPIHandler) addAdminEndpoints(spec *OpenAPISpec) {
 			},
 			Parameters: []OpenAPIParameter{
 				{Name: "type", In: "path", Required: true, Schema: map[string]string{"type": "string"}},
+				{Name: "locale", In: "query", Schema: map[string]string{"type": "string"}},
 			},
 			RequestBody: &OpenAPIRequestBody{
 				Required: true,
@@ -3532,7 +3740,7 @@ func (h *OpenAPIHandler) addAdminEndpoints(spec *OpenAPISpec) {
 	spec.Paths["/api/v1/admin/email/templates/{type}/reset"] = OpenAPIPath{
 		"post": OpenAPIOperation{
 			Summary:     "Reset email template",
-			Description: "Reset an email template to default",
+			Description: "Reset an email template or one of its locale variants to default",
 			OperationID: "admin_email_templates_reset",
 			Tags:        []string{"Admin"},
 			Security: []map[string][]string{
@@ -3540,6 +3748,7 @@ func (h *OpenAPIHandler) addAdminEndpoints(spec *OpenAPISpec) {
 			},
 			Parameters: []OpenAPIParameter{
 				{Name: "type", In: "path", Required: true, Schema: map[string]string{"type": "string"}},
+				{Name: "locale", In: "query", Schema: map[string]string{"type": "string"}},
 			},
 			Responses: map[string]OpenAPIResponse{
 				"200": {
@@ -3554,6 +3763,88 @@ func (h *OpenAPIHandler) addAdminEndpoints(spec *OpenAPISpec) {
 		},
 	}
 
+	spec.Paths["/api/v1/admin/email/templates/{type}/preview"] = OpenAPIPath{
+		"post": OpenAPIOperation{
+			Summary:     "Preview email template",
+			Description: "Render a draft or the saved template for a locale with sample data",
+			OperationID: "admin_email_templates_preview",
+			Tags:        []string{"Admin"},
+			Security: []map[string][]string{
+				{"bearerAuth": {}},
+			},
+			Parameters: []OpenAPIParameter{
+				{Name: "type", In: "path", Required: true, Schema: map[string]string{"type": "string"}},
+			},
+			RequestBody: &OpenAPIRequestBody{
+				Content: map[string]OpenAPIMedia{
+					"application/json": {
+						Schema: map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"locale":    map[string]string{"type": "string"},
+								"subject":   map[string]string{"type": "string"},
+								"html_body": map[string]string{"type": "string"},
+								"text_body": map[string]string{"type": "string"},
+								"data":      map[string]interface{}{"type": "object", "additionalProperties": map[string]string{"type": "string"}},
+							},
+						},
+					},
+				},
+			},
+			Responses: map[string]OpenAPIResponse{
+				"200": {Description: "Rendered subject and bodies"},
+				"400": {Description: "Invalid template"},
+			},
+		},
+	}
+
+	spec.Paths["/api/v1/admin/email/templates/{type}/versions"] = OpenAPIPath{
+		"get": OpenAPIOperation{
+			Summary:     "List email template versions",
+			Description: "Get the saved versions of an email template locale variant, newest first",
+			OperationID: "admin_email_templates_versions",
+			Tags:        []string{"Admin"},
+			Security: []map[string][]string{
+				{"bearerAuth": {}},
+			},
+			Parameters: []OpenAPIParameter{
+				{Name: "type", In: "path", Required: true, Schema: map[string]string{"type": "string"}},
+				{Name: "locale", In: "query", Schema: map[string]string{"type": "string"}},
+			},
+			Responses: map[string]OpenAPIResponse{
+				"200": {Description: "Template versions"},
+			},
+		},
+	}
+
+	spec.Paths["/api/v1/admin/email/templates/{type}/versions/{version}/restore"] = OpenAPIPath{
+		"post": OpenAPIOperation{
+			Summary:     "Restore email template version",
+			Description: "Save an earlier version of an email template as its newest version",
+			OperationID: "admin_email_templates_restore",
+			Tags:        []string{"Admin"},
+			Security: []map[string][]string{
+				{"bearerAuth": {}},
+			},
+			Parameters: []OpenAPIParameter{
+				{Name: "type", In: "path", Required: true, Schema: map[string]string{"type": "string"}},
+				{Name: "version", In: "path", Required: true, Schema: map[string]string{"type": "integer"}},
+				{Name: "locale", In: "query", Schema: map[string]string{"type": "string"}},
+			},
+			Responses: map[string]OpenAPIResponse{
+				"200": {
+					Description: "Restored template",
+					Content: map[string]OpenAPIMedia{
+						"application/json": {
+							Schema: map[string]string{"$ref": "#/components/schemas/EmailTemplate"},
+						},
+					},
+				},
+				"404": {Description: "Version not found"},
+			},
+		},
+	}
+
 	// Extensions
 	spec.Paths["/api/v1/admin/extensions"] = OpenAPIPath{
 		"get": OpenAPIOperation{
@@ -3924,6 +4215,31 @@ func (h *OpenAPIHandler) addTableToSpec(spec *OpenAPISpec, table database.TableI
 	// Generate schema
 	schemaRef := h.generateTableSchema(spec, table)
 
+	spec.Paths[path+"/export"] = OpenAPIPath{
+		"get": h.generateExportOperation(tableName, schemaName),
+	}
+
+	// Views are looked up by their "id" column, as they have no primary key
+	hasID := table.Type == "table" || len(table.PrimaryKey) > 0 || table.HasColumn("id")
+
+	// Materialized views and views Postgres can't update are read-only
+	if !table.IsUpdatable {
+		spec.Paths[path] = OpenAPIPath{
+			"get": h.generateListOperation(tableName, schemaName, schemaRef),
+		}
+		if hasID {
+			spec.Paths[pathWithID] = OpenAPIPath{
+				"get": h.generateGetOperation(tableName, schemaName, schemaRef),
+			}
+		}
+		if table.Type == "materialized_view" {
+			spec.Paths[path+"/refresh"] = OpenAPIPath{
+				"post": h.generateRefreshOperation(tableName, schemaName),
+			}
+		}
+		return
+	}
+
 	// Add paths
 	spec.Paths[path] = OpenAPIPath{
 		"get":    h.generateListOperation(tableName, schemaName, schemaRef),
@@ -3932,11 +4248,13 @@ func (h *OpenAPIHandler) addTableToSpec(spec *OpenAPISpec, table database.TableI
 		"delete": h.generateBatchDeleteOperation(tableName, schemaName, schemaRef),
 	}
 
-	spec.Paths[pathWithID] = OpenAPIPath{
-		"get":    h.generateGetOperation(tableName, schemaName, schemaRef),
-		"put":    h.generateReplaceOperation(tableName, schemaName, schemaRef),
-		"patch":  h.generateUpdateOperation(tableName, schemaName, schemaRef),
-		"delete": h.generateDeleteOperation(tableName, schemaName, schemaRef),
+	if hasID {
+		spec.Paths[pathWithID] = OpenAPIPath{
+			"get":    h.generateGetOperation(tableName, schemaName, schemaRef),
+			"put":    h.generateReplaceOperation(tableName, schemaName, schemaRef),
+			"patch":  h.generateUpdateOperation(tableName, schemaName, schemaRef),
+			"delete": h.generateDeleteOperation(tableName, schemaName, schemaRef),
+		}
 	}
 }
 
@@ -3973,7 +4291,7 @@ func (h *OpenAPIHandler) generateTableSchema(spec *OpenAPISpec, table database.T
 	for _, col := range table.Columns {
 		properties[col.Name] = h.columnToSchema(col)
 
-		if !col.IsNullable && col.DefaultValue == nil {
+		if !col.IsNullable && col.DefaultValue == nil && !col.IsGenerated {
 			required = append(required, col.Name)
 		}
 	}
@@ -4022,6 +4340,9 @@ func (h *OpenAPIHandler) columnToSchema(col database.ColumnInfo) map[string]inte
 	if col.IsNullable {
 		schema["nullable"] = true
 	}
+	if col.IsGenerated {
+		schema["readOnly"] = true
+	}
 
 	return schema
 }
@@ -4052,6 +4373,71 @@ func (h *OpenAPIHandler) generateListOperation(tableName, schemaName, schemaRef
 	}
 }
 
+// generateExportOperation generates the GET operation streaming records as CSV
+func (h *OpenAPIHandler) generateExportOperation(tableName, schemaName string) OpenAPIOperation {
+	parameters := []OpenAPIParameter{
+		{
+			Name:        "format",
+			In:          "query",
+			Description: "Export format",
+			Schema:      map[string]interface{}{"type": "string", "enum": []string{"csv"}, "default": "csv"},
+		},
+		{
+			Name:        "Range",
+			In:          "header",
+			Description: "Rows to export, counted from 0: rows=<first>-[<last>]. Resumes an interrupted export.",
+			Schema:      map[string]string{"type": "string"},
+		},
+	}
+	for _, param := range h.getQueryParameters() {
+		if param.Name != "limit" && param.Name != "offset" {
+			parameters = append(parameters, param)
+		}
+	}
+
+	csv := map[string]OpenAPIMedia{
+		"text/csv": {
+			Schema: map[string]string{"type": "string"},
+		},
+	}
+	return OpenAPIOperation{
+		Summary:     fmt.Sprintf("Export %s.%s records as CSV", schemaName, tableName),
+		Description: "Stream the records matching the filters with PostgreSQL COPY, ordered by the primary key unless an order is given",
+		OperationID: fmt.Sprintf("export_%s_%s", schemaName, tableName),
+		Tags:        []string{"Tables"},
+		Parameters:  parameters,
+		Responses: map[string]OpenAPIResponse{
+			"200": {Description: "All records", Content: csv},
+			"206": {Description: "The records of the requested row range, without the header row unless the range starts at 0", Content: csv},
+			"416": {Description: "Invalid Range header"},
+			"429": {Description: "Too many exports"},
+		},
+	}
+}
+
+// generateRefreshOperation generates POST operation for refreshing a materialized view
+func (h *OpenAPIHandler) generateRefreshOperation(tableName, schemaName string) OpenAPIOperation {
+	return OpenAPIOperation{
+		Summary:     fmt.Sprintf("Refresh materialized view %s.%s", schemaName, tableName),
+		Description: "Re-run the query of the materialized view. Requires owning it or the MAINTAIN privilege.",
+		OperationID: fmt.Sprintf("refresh_%s_%s", schemaName, tableName),
+		Tags:        []string{"Tables"},
+		Parameters: []OpenAPIParameter{
+			{
+				Name:        "concurrently",
+				In:          "query",
+				Description: "Keep the view readable during the refresh; needs a unique index on the view",
+				Schema:      map[string]interface{}{"type": "boolean", "default": false},
+			},
+		},
+		Responses: map[string]OpenAPIResponse{
+			"200": {Description: "Materialized view refreshed"},
+			"400": {Description: "Not a materialized view, or a concurrent refresh without a unique index"},
+			"403": {Description: "Not allowed to refresh the materialized view"},
+		},
+	}
+}
+
 // generateCreateOperation generates POST operation for creating records
 func (h *OpenAPIHandler) generateCreateOperation(tableName, schemaName, schemaRef string) OpenAPIOperation {
 	return OpenAPIOperation{
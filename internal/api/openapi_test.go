@@ -1,8 +1,10 @@
 package api
 
 import (
+	"maps"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 
@@ -762,6 +764,32 @@ func TestOpenAPIHandler_generateListOperation(t *testing.T) {
 	})
 }
 
+// =============================================================================
+// generateExportOperation Tests
+// =============================================================================
+
+func TestOpenAPIHandler_generateExportOperation(t *testing.T) {
+	handler := NewOpenAPIHandler(nil)
+
+	t.Run("generates export operation", func(t *testing.T) {
+		op := handler.generateExportOperation("users", "public")
+
+		assert.Equal(t, "Export public.users records as CSV", op.Summary)
+		assert.Equal(t, "export_public_users", op.OperationID)
+		assert.Contains(t, op.Responses["200"].Content, "text/csv")
+		assert.Contains(t, op.Responses, "206")
+
+		names := []string{}
+		for _, param := range op.Parameters {
+			names = append(names, param.Name)
+		}
+		assert.Contains(t, names, "Range")
+		assert.Contains(t, names, "filter")
+		assert.NotContains(t, names, "limit")
+		assert.NotContains(t, names, "offset")
+	})
+}
+
 // =============================================================================
 // generateCreateOperation Tests
 // =============================================================================
@@ -874,6 +902,73 @@ func TestOpenAPIHandler_generateTableSchema(t *testing.T) {
 	})
 }
 
+func TestOpenAPIHandler_addTableToSpec(t *testing.T) {
+	handler := NewOpenAPIHandler(nil)
+	newSpec := func() *OpenAPISpec {
+		return &OpenAPISpec{Paths: make(map[string]OpenAPIPath), Components: OpenAPIComponents{Schemas: make(map[string]interface{})}}
+	}
+
+	t.Run("updatable view", func(t *testing.T) {
+		spec := newSpec()
+		handler.addTableToSpec(spec, database.TableInfo{
+			Schema: "public", Name: "active_users", Type: "view", IsUpdatable: true,
+			Columns: []database.ColumnInfo{{Name: "id", DataType: "uuid"}},
+		})
+
+		assert.Len(t, spec.Paths["/api/v1/tables/active_users"], 4)
+		assert.Len(t, spec.Paths["/api/v1/tables/active_users/{id}"], 4)
+	})
+
+	t.Run("read-only view without id", func(t *testing.T) {
+		spec := newSpec()
+		handler.addTableToSpec(spec, database.TableInfo{
+			Schema: "public", Name: "user_stats", Type: "view",
+			Columns: []database.ColumnInfo{{Name: "signups", DataType: "bigint"}},
+		})
+
+		assert.Equal(t, []string{"get"}, pathMethods(spec.Paths["/api/v1/tables/user_stats"]))
+		assert.NotContains(t, spec.Paths, "/api/v1/tables/user_stats/{id}")
+		assert.NotContains(t, spec.Paths, "/api/v1/tables/user_stats/refresh")
+	})
+
+	t.Run("materialized view", func(t *testing.T) {
+		spec := newSpec()
+		handler.addTableToSpec(spec, database.TableInfo{
+			Schema: "public", Name: "daily_totals", Type: "materialized_view",
+			Columns: []database.ColumnInfo{{Name: "id", DataType: "integer"}},
+		})
+
+		assert.Equal(t, []string{"get"}, pathMethods(spec.Paths["/api/v1/tables/daily_totals"]))
+		assert.Equal(t, []string{"get"}, pathMethods(spec.Paths["/api/v1/tables/daily_totals/{id}"]))
+		assert.Equal(t, "refresh_public_daily_totals", spec.Paths["/api/v1/tables/daily_totals/refresh"]["post"].OperationID)
+	})
+}
+
+func TestOpenAPIHandler_generatedColumns(t *testing.T) {
+	handler := NewOpenAPIHandler(nil)
+	spec := OpenAPISpec{Components: OpenAPIComponents{Schemas: make(map[string]interface{})}}
+
+	handler.generateTableSchema(&spec, database.TableInfo{
+		Schema: "public",
+		Name:   "items",
+		Columns: []database.ColumnInfo{
+			{Name: "price", DataType: "numeric"},
+			{Name: "price_with_tax", DataType: "numeric", IsGenerated: true},
+		},
+	})
+
+	schema := spec.Components.Schemas["public.items"].(map[string]interface{})
+	assert.Equal(t, []string{"price"}, schema["required"])
+	properties := schema["properties"].(map[string]interface{})
+	assert.Equal(t, true, properties["price_with_tax"].(map[string]interface{})["readOnly"])
+	assert.NotContains(t, properties["price"], "readOnly")
+}
+
+// pathMethods returns the sorted HTTP methods of a path
+func pathMethods(path OpenAPIPath) []string {
+	return slices.Sorted(maps.Keys(path))
+}
+
 // =============================================================================
 // Pluralization Edge Cases Tests
 // =============================================================================
@@ -0,0 +1,427 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/nimbleflux/fluxbase/internal/email"
+	"github.com/rs/zerolog/log"
+)
+
+// OrganizationHandler handles the organization endpoints of signed in users. Access is
+// decided by the user's role in the organization, non-members get a 404.
+type OrganizationHandler struct {
+	orgService   *auth.OrganizationService
+	emailService email.Service
+	baseURL      string
+}
+
+// NewOrganizationHandler creates a new organization handler
+func NewOrganizationHandler(orgService *auth.OrganizationService, emailService email.Service, baseURL string) *OrganizationHandler {
+	return &OrganizationHandler{
+		orgService:   orgService,
+		emailService: emailService,
+		baseURL:      baseURL,
+	}
+}
+
+// CreateOrganizationRequest represents a request to create an organization
+type CreateOrganizationRequest struct {
+	Name     string         `json:"name"`
+	Slug     string         `json:"slug"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// UpdateOrganizationRequest represents a request to update an organization. Omitted fields are
+// left unchanged.
+type UpdateOrganizationRequest struct {
+	Name     *string        `json:"name,omitempty"`
+	Slug     *string        `json:"slug,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// UpdateOrganizationMemberRequest represents a request to change the role of a member
+type UpdateOrganizationMemberRequest struct {
+	Role string `json:"role"`
+}
+
+// CreateOrganizationInvitationRequest represents a request to invite an email to an organization
+type CreateOrganizationInvitationRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+	// Seconds until the invitation expires, 7 days by default
+	ExpiresIn int `json:"expires_in,omitempty"`
+}
+
+// AcceptOrganizationInvitationRequest represents a request to accept an invitation
+type AcceptOrganizationInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+// orgErrorStatus maps organization errors to HTTP status codes
+func orgErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, auth.ErrOrganizationNotFound),
+		errors.Is(err, auth.ErrOrgMemberNotFound),
+		errors.Is(err, auth.ErrOrgInvitationNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, auth.ErrOrganizationSlugTaken),
+		errors.Is(err, auth.ErrOrgInvitationExists),
+		errors.Is(err, auth.ErrOrganizationHasBuckets),
+		errors.Is(err, auth.ErrLastOrgOwner):
+		return fiber.StatusConflict
+	case errors.Is(err, auth.ErrOrganizationNameRequired),
+		errors.Is(err, auth.ErrInvalidOrganizationSlug),
+		errors.Is(err, auth.ErrInvalidOrgRole),
+		errors.Is(err, auth.ErrInvalidEmail):
+		return fiber.StatusBadRequest
+	case errors.Is(err, auth.ErrOrgInvitationEmailMismatch):
+		return fiber.StatusForbidden
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+func orgError(c fiber.Ctx, err error) error {
+	status := orgErrorStatus(err)
+	message := err.Error()
+	if status == fiber.StatusInternalServerError {
+		log.Error().Err(err).Str("path", c.Path()).Msg("Organization request failed")
+		message = "Failed to process organization request"
+	}
+	return SendError(c, status, message)
+}
+
+// requireOrgRole returns the user's role in the organization of the :id param, or false after
+// writing the response if the user isn't a member with at least minRole
+func (h *OrganizationHandler) requireOrgRole(c fiber.Ctx, minRole string) (string, bool) {
+	if !validIDParams(c, "id") {
+		return "", false
+	}
+	userID := currentUserID(c)
+	if userID == nil {
+		_ = SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
+		return "", false
+	}
+
+	role, err := h.orgService.GetMemberRole(c.RequestCtx(), c.Params("id"), *userID)
+	if errors.Is(err, auth.ErrOrgMemberNotFound) {
+		_ = SendNotFound(c, auth.ErrOrganizationNotFound.Error())
+		return "", false
+	}
+	if err != nil {
+		_ = orgError(c, err)
+		return "", false
+	}
+	if auth.OrgRoleRank(role) < auth.OrgRoleRank(minRole) {
+		_ = SendForbidden(c, fmt.Sprintf("Requires the %s role in the organization", minRole), ErrCodeAccessDenied)
+		return "", false
+	}
+	return role, true
+}
+
+// canManageMember reports whether a user with actorRole may change or remove a member with
+// targetRole. Owners manage everyone, admins manage members.
+func canManageMember(actorRole, targetRole string) bool {
+	return actorRole == auth.OrgRoleOwner || auth.OrgRoleRank(actorRole) > auth.OrgRoleRank(targetRole)
+}
+
+// ListOrganizations lists the organizations of the current user with their role in each
+// GET /api/v1/auth/orgs
+func (h *OrganizationHandler) ListOrganizations(c fiber.Ctx) error {
+	userID := currentUserID(c)
+	if userID == nil {
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
+	}
+
+	orgs, err := h.orgService.ListUserOrganizations(c.RequestCtx(), *userID)
+	if err != nil {
+		return orgError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"organizations": orgs,
+		"count":         len(orgs),
+	})
+}
+
+// CreateOrganization creates an organization with the current user as its owner
+// POST /api/v1/auth/orgs
+func (h *OrganizationHandler) CreateOrganization(c fiber.Ctx) error {
+	userID := currentUserID(c)
+	if userID == nil {
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
+	}
+	if claims, ok := c.Locals("jwt_claims").(*auth.TokenClaims); ok && claims.IsAnonymous {
+		return SendForbidden(c, "Anonymous users can't create organizations", ErrCodeAccessDenied)
+	}
+
+	var req CreateOrganizationRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+
+	org, err := h.orgService.CreateOrganization(c.RequestCtx(), req.Name, req.Slug, req.Metadata, *userID)
+	if err != nil {
+		return orgError(c, err)
+	}
+
+	log.Info().Str("org_id", org.ID).Str("slug", org.Slug).Str("user_id", *userID).Msg("Organization created")
+	return c.Status(fiber.StatusCreated).JSON(org)
+}
+
+// GetOrganization returns an organization of the current user
+// GET /api/v1/auth/orgs/:id
+func (h *OrganizationHandler) GetOrganization(c fiber.Ctx) error {
+	role, ok := h.requireOrgRole(c, auth.OrgRoleMember)
+	if !ok {
+		return nil
+	}
+
+	org, err := h.orgService.GetOrganization(c.RequestCtx(), c.Params("id"))
+	if err != nil {
+		return orgError(c, err)
+	}
+	org.Role = role
+	return c.JSON(org)
+}
+
+// UpdateOrganization updates an organization, requires the admin role
+// PATCH /api/v1/auth/orgs/:id
+func (h *OrganizationHandler) UpdateOrganization(c fiber.Ctx) error {
+	role, ok := h.requireOrgRole(c, auth.OrgRoleAdmin)
+	if !ok {
+		return nil
+	}
+
+	var req UpdateOrganizationRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+
+	org, err := h.orgService.UpdateOrganization(c.RequestCtx(), c.Params("id"), req.Name, req.Slug, req.Metadata)
+	if err != nil {
+		return orgError(c, err)
+	}
+	org.Role = role
+	return c.JSON(org)
+}
+
+// DeleteOrganization deletes an organization, requires the owner role
+// DELETE /api/v1/auth/orgs/:id
+func (h *OrganizationHandler) DeleteOrganization(c fiber.Ctx) error {
+	if _, ok := h.requireOrgRole(c, auth.OrgRoleOwner); !ok {
+		return nil
+	}
+
+	if err := h.orgService.DeleteOrganization(c.RequestCtx(), c.Params("id")); err != nil {
+		return orgError(c, err)
+	}
+
+	log.Info().Str("org_id", c.Params("id")).Str("user_id", getUserID(c)).Msg("Organization deleted")
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListMembers lists the members of an organization
+// GET /api/v1/auth/orgs/:id/members
+func (h *OrganizationHandler) ListMembers(c fiber.Ctx) error {
+	if _, ok := h.requireOrgRole(c, auth.OrgRoleMember); !ok {
+		return nil
+	}
+
+	members, err := h.orgService.ListMembers(c.RequestCtx(), c.Params("id"))
+	if err != nil {
+		return orgError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"members": members,
+		"count":   len(members),
+	})
+}
+
+// UpdateMember changes the role of a member. Admins manage members, owners manage everyone
+// and are the only ones that can make other members owners.
+// PATCH /api/v1/auth/orgs/:id/members/:user_id
+func (h *OrganizationHandler) UpdateMember(c fiber.Ctx) error {
+	actorRole, ok := h.requireOrgRole(c, auth.OrgRoleAdmin)
+	if !ok || !validIDParams(c, "user_id") {
+		return nil
+	}
+
+	var req UpdateOrganizationMemberRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+	if !auth.ValidOrgRole(req.Role) {
+		return orgError(c, auth.ErrInvalidOrgRole)
+	}
+
+	ctx := c.RequestCtx()
+	orgID, targetID := c.Params("id"), c.Params("user_id")
+	targetRole, err := h.orgService.GetMemberRole(ctx, orgID, targetID)
+	if err != nil {
+		return orgError(c, err)
+	}
+	if !canManageMember(actorRole, targetRole) || !canManageMember(actorRole, req.Role) {
+		return SendForbidden(c, "Insufficient role to change this member", ErrCodeInsufficientPermissions)
+	}
+
+	if err := h.orgService.UpdateMemberRole(ctx, orgID, targetID, req.Role); err != nil {
+		return orgError(c, err)
+	}
+
+	log.Info().
+		Str("org_id", orgID).
+		Str("member_id", targetID).
+		Str("role", req.Role).
+		Str("user_id", getUserID(c)).
+		Msg("Organization member role changed")
+	return c.JSON(fiber.Map{
+		"organization_id": orgID,
+		"user_id":         targetID,
+		"role":            req.Role,
+	})
+}
+
+// RemoveMember removes a member from an organization. Any member can leave, removing others
+// follows the same rules as changing their role.
+// DELETE /api/v1/auth/orgs/:id/members/:user_id
+func (h *OrganizationHandler) RemoveMember(c fiber.Ctx) error {
+	actorRole, ok := h.requireOrgRole(c, auth.OrgRoleMember)
+	if !ok || !validIDParams(c, "user_id") {
+		return nil
+	}
+
+	ctx := c.RequestCtx()
+	orgID, targetID := c.Params("id"), c.Params("user_id")
+	if userID := currentUserID(c); *userID != targetID {
+		targetRole, err := h.orgService.GetMemberRole(ctx, orgID, targetID)
+		if err != nil {
+			return orgError(c, err)
+		}
+		if !canManageMember(actorRole, targetRole) {
+			return SendForbidden(c, "Insufficient role to remove this member", ErrCodeInsufficientPermissions)
+		}
+	}
+
+	if err := h.orgService.RemoveMember(ctx, orgID, targetID); err != nil {
+		return orgError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListInvitations lists the pending invitations of an organization, requires the admin role
+// GET /api/v1/auth/orgs/:id/invitations
+func (h *OrganizationHandler) ListInvitations(c fiber.Ctx) error {
+	if _, ok := h.requireOrgRole(c, auth.OrgRoleAdmin); !ok {
+		return nil
+	}
+
+	invitations, err := h.orgService.ListInvitations(c.RequestCtx(), c.Params("id"))
+	if err != nil {
+		return orgError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"invitations": invitations,
+		"count":       len(invitations),
+	})
+}
+
+// CreateInvitation invites an email to an organization and emails the invite link, requires
+// the admin role
+// POST /api/v1/auth/orgs/:id/invitations {"email": "jane@example.com", "role": "member"}
+func (h *OrganizationHandler) CreateInvitation(c fiber.Ctx) error {
+	if _, ok := h.requireOrgRole(c, auth.OrgRoleAdmin); !ok {
+		return nil
+	}
+
+	var req CreateOrganizationInvitationRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+	if req.Role == "" {
+		req.Role = auth.OrgRoleMember
+	}
+	if req.ExpiresIn < 0 {
+		return SendBadRequest(c, "expires_in must be positive", ErrCodeInvalidInput)
+	}
+	if err := auth.ValidateEmail(req.Email); err != nil {
+		return orgError(c, auth.ErrInvalidEmail)
+	}
+
+	ctx := c.RequestCtx()
+	orgID := c.Params("id")
+	org, err := h.orgService.GetOrganization(ctx, orgID)
+	if err != nil {
+		return orgError(c, err)
+	}
+
+	invitation, token, err := h.orgService.CreateInvitation(ctx, orgID, req.Email, req.Role, currentUserID(c),
+		time.Duration(req.ExpiresIn)*time.Second)
+	if err != nil {
+		return orgError(c, err)
+	}
+
+	inviteLink := fmt.Sprintf("%s/org-invite/%s", h.baseURL, url.PathEscape(token))
+	emailSent := false
+	if h.emailService != nil {
+		inviterName := getUserID(c)
+		if inviterEmail, _ := c.Locals("user_email").(string); inviterEmail != "" {
+			inviterName = inviterEmail
+		}
+		inviterName = fmt.Sprintf("%s (%s)", inviterName, org.Name)
+		if err := h.emailService.SendInvitationEmail(ctx, invitation.Email, inviterName, inviteLink); err != nil {
+			log.Warn().Err(err).Str("org_id", orgID).Str("email", invitation.Email).Msg("Failed to send organization invitation email")
+		} else {
+			emailSent = true
+		}
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"invitation":  invitation,
+		"token":       token,
+		"invite_link": inviteLink,
+		"email_sent":  emailSent,
+	})
+}
+
+// RevokeInvitation revokes a pending invitation, requires the admin role
+// DELETE /api/v1/auth/orgs/:id/invitations/:invitation_id
+func (h *OrganizationHandler) RevokeInvitation(c fiber.Ctx) error {
+	if _, ok := h.requireOrgRole(c, auth.OrgRoleAdmin); !ok {
+		return nil
+	}
+	if !validIDParams(c, "invitation_id") {
+		return nil
+	}
+
+	if err := h.orgService.RevokeInvitation(c.RequestCtx(), c.Params("id"), c.Params("invitation_id")); err != nil {
+		return orgError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// AcceptInvitation adds the current user to the organization of an invitation sent to their email
+// POST /api/v1/auth/orgs/invitations/accept {"token": "..."}
+func (h *OrganizationHandler) AcceptInvitation(c fiber.Ctx) error {
+	userID := currentUserID(c)
+	if userID == nil {
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
+	}
+
+	var req AcceptOrganizationInvitationRequest
+	if err := c.Bind().Body(&req); err != nil || req.Token == "" {
+		return SendBadRequest(c, "token is required", ErrCodeMissingField)
+	}
+
+	org, err := h.orgService.AcceptInvitation(c.RequestCtx(), req.Token, *userID)
+	if err != nil {
+		return orgError(c, err)
+	}
+
+	log.Info().Str("org_id", org.ID).Str("user_id", *userID).Msg("Organization invitation accepted")
+	return c.JSON(org)
+}
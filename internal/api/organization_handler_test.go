@@ -0,0 +1,98 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrgErrorStatus(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{auth.ErrOrganizationNotFound, fiber.StatusNotFound},
+		{auth.ErrOrgMemberNotFound, fiber.StatusNotFound},
+		{auth.ErrOrgInvitationNotFound, fiber.StatusNotFound},
+		{auth.ErrOrganizationSlugTaken, fiber.StatusConflict},
+		{auth.ErrOrgInvitationExists, fiber.StatusConflict},
+		{auth.ErrOrganizationHasBuckets, fiber.StatusConflict},
+		{auth.ErrLastOrgOwner, fiber.StatusConflict},
+		{auth.ErrInvalidOrganizationSlug, fiber.StatusBadRequest},
+		{auth.ErrInvalidOrgRole, fiber.StatusBadRequest},
+		{auth.ErrOrgInvitationEmailMismatch, fiber.StatusForbidden},
+		{errors.New("connection refused"), fiber.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			assert.Equal(t, tt.status, orgErrorStatus(tt.err))
+		})
+	}
+}
+
+func TestCanManageMember(t *testing.T) {
+	assert.True(t, canManageMember(auth.OrgRoleOwner, auth.OrgRoleOwner))
+	assert.True(t, canManageMember(auth.OrgRoleOwner, auth.OrgRoleMember))
+	assert.True(t, canManageMember(auth.OrgRoleAdmin, auth.OrgRoleMember))
+	assert.False(t, canManageMember(auth.OrgRoleAdmin, auth.OrgRoleAdmin))
+	assert.False(t, canManageMember(auth.OrgRoleAdmin, auth.OrgRoleOwner))
+	assert.False(t, canManageMember(auth.OrgRoleMember, auth.OrgRoleMember))
+}
+
+func TestOrganizationHandler_Validation(t *testing.T) {
+	// Requests are rejected before the organization service is used
+	handler := NewOrganizationHandler(nil, nil, "http://localhost")
+	app := fiber.New()
+	app.Use(func(c fiber.Ctx) error {
+		if userID := c.Get("X-Test-User"); userID != "" {
+			c.Locals("user_id", userID)
+		}
+		return c.Next()
+	})
+	app.Get("/orgs", handler.ListOrganizations)
+	app.Post("/orgs", handler.CreateOrganization)
+	app.Post("/orgs/invitations/accept", handler.AcceptInvitation)
+	app.Get("/orgs/:id", handler.GetOrganization)
+	app.Delete("/orgs/:id/members/:user_id", handler.RemoveMember)
+
+	const userID = "550e8400-e29b-41d4-a716-446655440000"
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		user   string
+		body   string
+		status int
+	}{
+		{"list without user", http.MethodGet, "/orgs", "", "", fiber.StatusUnauthorized},
+		{"create without user", http.MethodPost, "/orgs", "", `{"name":"Acme","slug":"acme"}`, fiber.StatusUnauthorized},
+		{"invalid organization ID", http.MethodGet, "/orgs/not-a-uuid", userID, "", fiber.StatusBadRequest},
+		{"get without user", http.MethodGet, "/orgs/" + userID, "", "", fiber.StatusUnauthorized},
+		{"invalid member ID", http.MethodDelete, "/orgs/not-a-uuid/members/" + userID, userID, "", fiber.StatusBadRequest},
+		{"accept without token", http.MethodPost, "/orgs/invitations/accept", userID, `{}`, fiber.StatusBadRequest},
+		{"accept without user", http.MethodPost, "/orgs/invitations/accept", "", `{"token":"abc"}`, fiber.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.user != "" {
+				req.Header.Set("X-Test-User", tt.user)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"sync"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/preflight"
+)
+
+// PreflightHandler exposes the results of the startup preflight checks
+type PreflightHandler struct {
+	runner *preflight.Runner
+
+	mu     sync.RWMutex
+	report *preflight.Report
+}
+
+// NewPreflightHandler creates a new preflight handler
+func NewPreflightHandler(runner *preflight.Runner) *PreflightHandler {
+	return &PreflightHandler{runner: runner}
+}
+
+// SetReport stores the report of the checks run on boot
+func (h *PreflightHandler) SetReport(report *preflight.Report) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.report = report
+}
+
+// GetReport returns the latest preflight report. With ?refresh=true, or when preflight
+// checks were disabled on boot, the checks run again first.
+func (h *PreflightHandler) GetReport(c fiber.Ctx) error {
+	h.mu.RLock()
+	report := h.report
+	h.mu.RUnlock()
+
+	if report == nil || fiber.Query[bool](c, "refresh", false) {
+		report = h.runner.Run(c.RequestCtx(), preflight.StageAll)
+		h.SetReport(report)
+	}
+
+	return c.JSON(report)
+}
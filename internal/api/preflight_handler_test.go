@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/preflight"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreflightHandler_GetReport(t *testing.T) {
+	// Skipping every check lets the handler run them without a database
+	cfg := &config.Config{Preflight: config.PreflightConfig{
+		Enabled: true,
+		Skip:    []string{"secret_keys", "extensions", "clock_skew", "smtp", "migrations", "schemas"},
+	}}
+	h := NewPreflightHandler(preflight.NewRunner(cfg, nil))
+	h.SetReport(&preflight.Report{
+		Status:    preflight.StatusWarn,
+		Checks:    []preflight.Result{{Name: "smtp", Status: preflight.StatusWarn, Message: "cannot connect to SMTP server"}},
+		CheckedAt: time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC),
+	})
+
+	app := fiber.New()
+	app.Get("/preflight", h.GetReport)
+	get := func(path string) preflight.Report {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var report preflight.Report
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+		return report
+	}
+
+	boot := get("/preflight")
+	assert.Equal(t, preflight.StatusWarn, boot.Status)
+	require.Len(t, boot.Checks, 1)
+	assert.Equal(t, "smtp", boot.Checks[0].Name)
+
+	refreshed := get("/preflight?refresh=true")
+	assert.Equal(t, preflight.StatusPass, refreshed.Status)
+	assert.Len(t, refreshed.Checks, 6)
+	for _, check := range refreshed.Checks {
+		assert.Equal(t, preflight.StatusSkipped, check.Status, check.Name)
+	}
+	assert.Equal(t, refreshed.Checks, get("/preflight").Checks, "the refreshed report replaces the boot report")
+}
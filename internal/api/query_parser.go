@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -27,8 +28,7 @@ func quoteIdentifier(s string) string {
 	if !isValidIdentifier(s) {
 		return ""
 	}
-	// Escape any embedded double quotes and wrap in double quotes
-	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	return query.QuoteIdentifier(s)
 }
 
 // QueryParams represents parsed query parameters for REST API
@@ -92,6 +92,7 @@ const (
 	OpSTTouches    = query.OpSTTouches
 	OpSTCrosses    = query.OpSTCrosses
 	OpSTOverlaps   = query.OpSTOverlaps
+	OpBBox         = query.OpBBox
 
 	// pgvector similarity operators
 	OpVectorL2     = query.OpVectorL2
@@ -102,11 +103,25 @@ const (
 // OrderBy is an alias for query.OrderBy for backward compatibility
 type OrderBy = query.OrderBy
 
-// EmbeddedRelation represents a relation to embed
+// EmbeddedRelation represents a relation to embed: select=id,author:users!posts_author_id_fkey(name)
 type EmbeddedRelation struct {
-	Name    string   // Relation name
-	Select  []string // Fields to select from relation
-	Filters []Filter // Filters for the relation
+	Name     string             // Relation name: the related table, or a foreign key column
+	Alias    string             // Key of the relation in the response (default: Name)
+	Hint     string             // Foreign key constraint, column or junction table disambiguating the relation
+	Select   []string           // Fields to select from relation (empty = all columns)
+	Embedded []EmbeddedRelation // Relations embedded in this relation
+	Filters  []Filter           // Filters for the relation, e.g. posts.published=eq.true
+	Order    []OrderBy          // Order of embedded rows, e.g. posts.order=created_at.desc
+	Limit    *int               // Maximum number of embedded rows, e.g. posts.limit=5
+	Offset   *int               // Number of embedded rows to skip
+}
+
+// Key returns the key of the relation in the response
+func (r EmbeddedRelation) Key() string {
+	if r.Alias != "" {
+		return r.Alias
+	}
+	return r.Name
 }
 
 // CountType represents row count preferences
@@ -169,13 +184,18 @@ func (qp *QueryParser) ParseWithOptions(values url.Values, opts ParseOptions) (*
 		Order:   []OrderBy{},
 	}
 
+	// Parse select first so parameters of embedded relations can be told apart from filters
+	if vals, ok := values["select"]; ok {
+		if err := qp.parseSelect(vals[0], params); err != nil {
+			return nil, fmt.Errorf("invalid select parameter: %w", err)
+		}
+	}
+
 	// Parse each parameter type
 	for key, vals := range values {
 		switch key {
 		case "select":
-			if err := qp.parseSelect(vals[0], params); err != nil {
-				return nil, fmt.Errorf("invalid select parameter: %w", err)
-			}
+			// Already parsed
 
 		case "order":
 			if err := qp.parseOrder(vals[0], params); err != nil {
@@ -252,6 +272,14 @@ func (qp *QueryParser) ParseWithOptions(values url.Values, opts ParseOptions) (*
 			}
 
 		default:
+			// Filters, order, limit and offset of embedded relations: posts.published=eq.true
+			if rel, param := findEmbeddedParam(params.Embedded, key); rel != nil {
+				if err := qp.parseEmbeddedParam(rel, param, vals); err != nil {
+					return nil, fmt.Errorf("invalid parameter %s: %w", key, err)
+				}
+				continue
+			}
+
 			// Check if it's a filter parameter
 			// PostgREST format: column=operator.value (dot in value)
 			// Old format: column.operator=value (dot in key)
@@ -315,7 +343,10 @@ func (qp *QueryParser) ParseWithOptions(values url.Values, opts ParseOptions) (*
 func (qp *QueryParser) parseSelect(value string, params *QueryParams) error {
 	// Parse format: select=id,name,posts(id,title,author(name))
 	// Or with aggregations: select=category,count(*),sum(price),avg(rating)
-	fields, embedded := qp.parseSelectFields(value)
+	fields, embedded, err := qp.parseSelectFields(value)
+	if err != nil {
+		return err
+	}
 
 	// Separate regular fields from aggregations
 	regularFields := []string{}
@@ -328,21 +359,16 @@ func (qp *QueryParser) parseSelect(value string, params *QueryParams) error {
 	}
 
 	params.Select = regularFields
-
-	for name, subSelect := range embedded {
-		params.Embedded = append(params.Embedded, EmbeddedRelation{
-			Name:   name,
-			Select: subSelect,
-		})
-	}
+	params.Embedded = embedded
 
 	return nil
 }
 
-// parseSelectFields parses select fields and embedded relations
-func (qp *QueryParser) parseSelectFields(value string) ([]string, map[string][]string) {
+// parseSelectFields parses select fields and embedded relations. Relations are parsed
+// recursively, so posts(id,comments(body)) embeds comments in posts.
+func (qp *QueryParser) parseSelectFields(value string) ([]string, []EmbeddedRelation, error) {
 	fields := []string{}
-	embedded := make(map[string][]string)
+	embedded := []EmbeddedRelation{}
 
 	// Known aggregation function names
 	aggFuncs := map[string]bool{
@@ -385,13 +411,21 @@ func (qp *QueryParser) parseSelectFields(value string) ([]string, map[string][]s
 		case ')':
 			depth--
 			switch {
+			case depth < 0:
+				return nil, nil, fmt.Errorf("unbalanced parentheses")
 			case depth == 0 && inRelation && !isAggregation:
 				// End of relation fields
-				subFields := strings.Split(current.String(), ",")
-				for j := range subFields {
-					subFields[j] = strings.TrimSpace(subFields[j])
+				rel, err := parseRelationName(relationName)
+				if err != nil {
+					return nil, nil, err
+				}
+				subFields, subEmbedded, err := qp.parseSelectFields(current.String())
+				if err != nil {
+					return nil, nil, fmt.Errorf("relation %s: %w", rel.Key(), err)
 				}
-				embedded[relationName] = subFields
+				rel.Select = subFields
+				rel.Embedded = subEmbedded
+				embedded = append(embedded, rel)
 				current.Reset()
 				inRelation = false
 			case depth == 0 && isAggregation:
@@ -416,13 +450,99 @@ func (qp *QueryParser) parseSelectFields(value string) ([]string, map[string][]s
 			current.WriteByte(ch)
 		}
 	}
+	if depth != 0 {
+		return nil, nil, fmt.Errorf("unbalanced parentheses")
+	}
 
 	// Add the last field
 	if field := strings.TrimSpace(current.String()); field != "" {
 		fields = append(fields, field)
 	}
 
-	return fields, embedded
+	return fields, embedded, nil
+}
+
+// parseRelationName parses the [alias:]name[!hint] prefix of an embedded relation
+func parseRelationName(value string) (EmbeddedRelation, error) {
+	var rel EmbeddedRelation
+	if alias, rest, found := strings.Cut(value, ":"); found {
+		rel.Alias = strings.TrimSpace(alias)
+		value = rest
+	}
+	name, hint, _ := strings.Cut(value, "!")
+	rel.Name = strings.TrimSpace(name)
+	rel.Hint = strings.TrimSpace(hint)
+
+	if !isValidIdentifier(rel.Name) {
+		return rel, fmt.Errorf("invalid relation name: %q", rel.Name)
+	}
+	if rel.Alias != "" && !isValidIdentifier(rel.Alias) {
+		return rel, fmt.Errorf("invalid relation alias: %q", rel.Alias)
+	}
+	if rel.Hint != "" && !isValidIdentifier(rel.Hint) {
+		return rel, fmt.Errorf("invalid relation hint: %q", rel.Hint)
+	}
+	return rel, nil
+}
+
+// findEmbeddedParam resolves a parameter key of an embedded relation, e.g. posts.comments.limit,
+// to the relation and the parameter name. It returns nil when the key names no relation.
+func findEmbeddedParam(relations []EmbeddedRelation, key string) (*EmbeddedRelation, string) {
+	parts := strings.Split(key, ".")
+	var rel *EmbeddedRelation
+	i := 0
+	for ; i < len(parts)-1; i++ {
+		idx := slices.IndexFunc(relations, func(r EmbeddedRelation) bool { return r.Key() == parts[i] })
+		if idx < 0 {
+			break
+		}
+		rel = &relations[idx]
+		relations = rel.Embedded
+	}
+	if rel == nil || i != len(parts)-1 {
+		return nil, ""
+	}
+	return rel, parts[i]
+}
+
+// parseEmbeddedParam parses a filter, order, limit or offset of an embedded relation
+func (qp *QueryParser) parseEmbeddedParam(rel *EmbeddedRelation, param string, vals []string) error {
+	switch param {
+	case "order":
+		tmp := &QueryParams{}
+		if err := qp.parseOrder(vals[0], tmp); err != nil {
+			return err
+		}
+		rel.Order = tmp.Order
+
+	case "limit", "offset":
+		n, err := strconv.Atoi(vals[0])
+		if err != nil || n < 0 {
+			return fmt.Errorf("%s must be a non-negative integer", param)
+		}
+		if param == "offset" {
+			rel.Offset = &n
+			return nil
+		}
+		if qp.config.API.MaxPageSize > 0 && n > qp.config.API.MaxPageSize {
+			n = qp.config.API.MaxPageSize
+		}
+		rel.Limit = &n
+
+	default:
+		// Continue OR group IDs after the relation's existing filters
+		tmp := &QueryParams{}
+		for _, f := range rel.Filters {
+			tmp.orGroupCounter = max(tmp.orGroupCounter, f.OrGroupID)
+		}
+		for _, val := range vals {
+			if err := qp.parseFilter(param, val, tmp); err != nil {
+				return err
+			}
+		}
+		rel.Filters = append(rel.Filters, tmp.Filters...)
+	}
+	return nil
 }
 
 // parseAggregation parses aggregation functions from a select field
@@ -480,6 +600,7 @@ func (qp *QueryParser) parseAggregation(field string) *Aggregation {
 func (qp *QueryParser) parseOrder(value string, params *QueryParams) error {
 	// Parse format: order=name.asc,created_at.desc.nullslast
 	// Vector ordering format: order=embedding.vec_cos.[0.1,0.2,...].asc
+	// Distance ordering format: order=location.st_distance.{"type":"Point",...}.asc
 	orders := splitOrderParams(value)
 
 	for _, order := range orders {
@@ -488,6 +609,15 @@ func (qp *QueryParser) parseOrder(value string, params *QueryParams) error {
 			continue
 		}
 
+		// Check for distance ordering format: column.st_distance.{geojson}.direction
+		if distanceOrder, ok, err := parseDistanceOrder(order); ok || err != nil {
+			if err != nil {
+				return err
+			}
+			params.Order = append(params.Order, distanceOrder)
+			continue
+		}
+
 		// Check for vector ordering format: column.vec_op.[vector].direction
 		// The vector is enclosed in brackets, so we need special parsing
 		if vectorOrder, ok := qp.parseVectorOrder(order); ok {
@@ -528,7 +658,7 @@ func (qp *QueryParser) parseOrder(value string, params *QueryParams) error {
 	return nil
 }
 
-// splitOrderParams splits order parameters by comma, respecting brackets
+// splitOrderParams splits order parameters by comma, respecting brackets and braces
 func splitOrderParams(value string) []string {
 	var orders []string
 	var current strings.Builder
@@ -536,10 +666,10 @@ func splitOrderParams(value string) []string {
 
 	for _, ch := range value {
 		switch ch {
-		case '[':
+		case '[', '{':
 			bracketDepth++
 			current.WriteRune(ch)
-		case ']':
+		case ']', '}':
 			bracketDepth--
 			current.WriteRune(ch)
 		case ',':
@@ -620,6 +750,18 @@ func (qp *QueryParser) parseVectorOrder(order string) (OrderBy, bool) {
 
 // parseFilter parses filter parameters
 func (qp *QueryParser) parseFilter(key, value string, params *QueryParams) error {
+	parsed := len(params.Filters)
+	if err := qp.parseFilterExpression(key, value, params); err != nil {
+		return err
+	}
+	if err := parseTextSearchLanguages(params.Filters[parsed:]); err != nil {
+		return err
+	}
+	return parseBBoxValues(params.Filters[parsed:])
+}
+
+// parseFilterExpression parses a filter parameter into one or more filters
+func (qp *QueryParser) parseFilterExpression(key, value string, params *QueryParams) error {
 	// Handle logical operators
 	if key == "or" {
 		return qp.parseLogicalFilter(value, params, true)
@@ -929,13 +1071,18 @@ func (qp *QueryParser) parseArrayValue(value string) []string {
 
 // ToSQL converts QueryParams to SQL WHERE, ORDER BY, LIMIT, OFFSET clauses
 func (params *QueryParams) ToSQL(tableName string) (string, []interface{}) {
+	argCounter := 1
+	return params.toSQL(&argCounter)
+}
+
+// toSQL builds the WHERE, ORDER BY, LIMIT and OFFSET clauses numbering placeholders from argCounter
+func (params *QueryParams) toSQL(argCounter *int) (string, []interface{}) {
 	var sqlParts []string
 	var args []interface{}
-	argCounter := 1
 
 	// Build WHERE clause
 	if len(params.Filters) > 0 {
-		whereClause, whereArgs := params.buildWhereClause(&argCounter)
+		whereClause, whereArgs := params.buildWhereClause(argCounter)
 		if whereClause != "" {
 			sqlParts = append(sqlParts, "WHERE "+whereClause)
 			args = append(args, whereArgs...)
@@ -944,7 +1091,7 @@ func (params *QueryParams) ToSQL(tableName string) (string, []interface{}) {
 
 	// Build ORDER BY clause
 	if len(params.Order) > 0 {
-		orderClause, orderArgs := params.buildOrderClause(&argCounter)
+		orderClause, orderArgs := params.buildOrderClause(argCounter)
 		if orderClause != "" {
 			sqlParts = append(sqlParts, "ORDER BY "+orderClause)
 			args = append(args, orderArgs...)
@@ -953,16 +1100,16 @@ func (params *QueryParams) ToSQL(tableName string) (string, []interface{}) {
 
 	// Build LIMIT clause
 	if params.Limit != nil {
-		sqlParts = append(sqlParts, fmt.Sprintf("LIMIT $%d", argCounter))
+		sqlParts = append(sqlParts, fmt.Sprintf("LIMIT $%d", *argCounter))
 		args = append(args, *params.Limit)
-		argCounter++
+		*argCounter++
 	}
 
 	// Build OFFSET clause
 	if params.Offset != nil {
-		sqlParts = append(sqlParts, fmt.Sprintf("OFFSET $%d", argCounter))
+		sqlParts = append(sqlParts, fmt.Sprintf("OFFSET $%d", *argCounter))
 		args = append(args, *params.Offset)
-		argCounter++
+		*argCounter++
 	}
 
 	return strings.Join(sqlParts, " "), args
@@ -1202,6 +1349,11 @@ func (params *QueryParams) buildOrderClause(argCounter *int) (string, []interfac
 			part = fmt.Sprintf("%s %s $%d::vector", quotedCol, opSQL, *argCounter)
 			args = append(args, vectorVal)
 			*argCounter++
+		} else if order.Geometry != "" {
+			// Distance ordering: the KNN operator can use a GiST index for nearest-first scans
+			part = fmt.Sprintf("%s <-> ST_GeomFromGeoJSON($%d)", quotedCol, *argCounter)
+			args = append(args, order.Geometry)
+			*argCounter++
 		} else {
 			// Standard column ordering
 			part = quotedCol
@@ -1254,89 +1406,65 @@ func validateAndFormatVector(value interface{}) (string, error) {
 	return vectorStr, nil
 }
 
-// parseJSONBPath parses a column name that may contain JSONB path operators
-// and returns the properly formatted SQL expression.
-// Examples:
+// bindJSONBPath parses a column name that may contain JSONB path operators and returns
+// the SQL expression with each path key bound as a parameter, plus the key arguments
+// in placeholder order. Numeric keys are bound as array indexes.
+// Examples (starting at $1):
 //   - "name" -> "name" (simple column)
-//   - "data->key" -> "data"->'key' (JSON access)
-//   - "data->>key" -> "data"->>'key' (text access)
-//   - "data->nested->>value" -> "data"->'nested'->>'value' (chained)
-//   - "data->0->name" -> "data"->0->'name' (array index)
-func parseJSONBPath(column string) string {
+//   - "data->key" -> "data"->$1::text
+//   - "data->>key" -> "data"->>$1::text
+//   - "data->nested->>value" -> "data"->$1::text->>$2::text
+//   - "data->0->name" -> "data"->$1::int->$2::text
+func bindJSONBPath(column string, argCounter *int) (string, []interface{}) {
 	// Check if column contains JSONB path operators
 	if !strings.Contains(column, "->") {
-		// Simple column name - quote it
-		return fmt.Sprintf(`"%s"`, column)
+		return query.QuoteIdentifier(column), nil
 	}
 
-	// Split the path into segments, preserving ->> vs ->
-	// We need to handle both -> (JSON) and ->> (text) operators
-	var result strings.Builder
+	var expr string
+	var args []interface{}
+	var pendingOp query.JSONBOperator
 	remaining := column
 
 	isFirst := true
-	for len(remaining) > 0 {
+	for {
 		// Find the next operator (->> or ->)
 		textOpIdx := strings.Index(remaining, "->>")
 		jsonOpIdx := strings.Index(remaining, "->")
 
 		// Determine which operator comes first
-		var opIdx int
-		var opLen int
-		var op string
-
-		//nolint:gocritic // Conditions check different indices, not switch-compatible
-		if textOpIdx >= 0 && (jsonOpIdx < 0 || textOpIdx <= jsonOpIdx) {
-			opIdx = textOpIdx
-			opLen = 3
-			op = "->>"
-		} else if jsonOpIdx >= 0 {
-			opIdx = jsonOpIdx
-			opLen = 2
-			op = "->"
-		} else {
-			// No more operators - this is the last key
-			key := remaining
-			if isFirst {
-				fmt.Fprintf(&result, `"%s"`, key)
-			} else {
-				result.WriteString(formatJSONKey(key))
-			}
-			break
+		part := remaining
+		var op query.JSONBOperator
+		switch {
+		case textOpIdx >= 0 && (jsonOpIdx < 0 || textOpIdx <= jsonOpIdx):
+			part, op = remaining[:textOpIdx], query.JSONBFieldText
+			remaining = remaining[textOpIdx+3:]
+		case jsonOpIdx >= 0:
+			part, op = remaining[:jsonOpIdx], query.JSONBField
+			remaining = remaining[jsonOpIdx+2:]
 		}
 
-		// Extract the part before the operator
-		part := remaining[:opIdx]
 		if isFirst {
 			// First part is the column name - quote it as identifier
-			fmt.Fprintf(&result, `"%s"`, part)
+			expr = query.QuoteIdentifier(part)
 			isFirst = false
 		} else {
-			// Subsequent parts are JSON keys
-			result.WriteString(formatJSONKey(part))
+			// Subsequent parts are JSON keys - bind them
+			var arg interface{}
+			if index, err := strconv.Atoi(part); err == nil {
+				expr, arg = query.BindJSONBIndex(expr, pendingOp, index, argCounter)
+			} else {
+				expr, arg = query.BindJSONBKey(expr, pendingOp, part, argCounter)
+			}
+			args = append(args, arg)
 		}
 
-		// Add the operator
-		result.WriteString(op)
-
-		// Move past the operator
-		remaining = remaining[opIdx+opLen:]
+		if op == "" {
+			// No more operators - this was the last key
+			return expr, args
+		}
+		pendingOp = op
 	}
-
-	return result.String()
-}
-
-// formatJSONKey formats a JSON key for use in a JSONB path expression.
-// Numeric keys are left unquoted (for array access), string keys are quoted.
-func formatJSONKey(key string) string {
-	// Check if it's a numeric key (array index)
-	if _, err := strconv.Atoi(key); err == nil {
-		return key
-	}
-	// String key - wrap in single quotes with proper escaping
-	// Escape single quotes by doubling them to prevent SQL injection
-	escaped := strings.ReplaceAll(key, "'", "''")
-	return fmt.Sprintf("'%s'", escaped)
 }
 
 // needsNumericCast checks if a JSONB path expression needs numeric casting
@@ -1366,11 +1494,28 @@ func needsNumericCast(column string, value interface{}) bool {
 	return false
 }
 
-// filterToSQL converts a filter to SQL condition
+// filterToSQL converts a filter to SQL condition.
+// JSONB path keys are bound ahead of the filter value; when present the
+// returned argument is a []interface{} holding the keys followed by the value(s).
 func filterToSQL(f Filter, argCounter *int) (string, interface{}) {
-	// Parse JSONB path for proper SQL formatting
-	colExpr := parseJSONBPath(f.Column)
+	colExpr, keyArgs := bindJSONBPath(f.Column, argCounter)
+	sql, arg := filterConditionSQL(f, colExpr, argCounter)
+	if len(keyArgs) == 0 {
+		return sql, arg
+	}
 
+	switch v := arg.(type) {
+	case nil:
+		return sql, keyArgs
+	case []interface{}:
+		return sql, append(keyArgs, v...)
+	default:
+		return sql, append(keyArgs, v)
+	}
+}
+
+// filterConditionSQL builds the SQL condition for a filter on an already formatted column expression
+func filterConditionSQL(f Filter, colExpr string, argCounter *int) (string, interface{}) {
 	switch f.Operator {
 	case OpEqual:
 		sql := fmt.Sprintf("%s = $%d", colExpr, *argCounter)
@@ -1461,19 +1606,13 @@ func filterToSQL(f Filter, argCounter *int) (string, interface{}) {
 		return sql, f.Value
 
 	case OpTextSearch:
-		sql := fmt.Sprintf("%s @@ plainto_tsquery($%d)", colExpr, *argCounter)
-		*argCounter++
-		return sql, f.Value
+		return textSearchConditionSQL(f, colExpr, "plainto_tsquery", argCounter)
 
 	case OpPhraseSearch:
-		sql := fmt.Sprintf("%s @@ phraseto_tsquery($%d)", colExpr, *argCounter)
-		*argCounter++
-		return sql, f.Value
+		return textSearchConditionSQL(f, colExpr, "phraseto_tsquery", argCounter)
 
 	case OpWebSearch:
-		sql := fmt.Sprintf("%s @@ websearch_to_tsquery($%d)", colExpr, *argCounter)
-		*argCounter++
-		return sql, f.Value
+		return textSearchConditionSQL(f, colExpr, "websearch_to_tsquery", argCounter)
 
 	case OpNot:
 		// NOT operator - negates the condition
@@ -1517,13 +1656,15 @@ func filterToSQL(f Filter, argCounter *int) (string, interface{}) {
 
 		// Create a filter with the nested operator
 		nestedFilter := Filter{
-			Column:   f.Column,
-			Operator: nestedOp,
-			Value:    parsedValue,
+			Column:     f.Column,
+			Operator:   nestedOp,
+			Value:      parsedValue,
+			Language:   f.Language,
+			IsTSVector: f.IsTSVector,
 		}
 
-		// Generate SQL for the nested filter
-		nestedSQL, nestedArg := filterToSQL(nestedFilter, argCounter)
+		// Generate SQL for the nested filter (reusing the already bound column expression)
+		nestedSQL, nestedArg := filterConditionSQL(nestedFilter, colExpr, argCounter)
 
 		// Wrap in NOT
 		sql := fmt.Sprintf("NOT (%s)", nestedSQL)
@@ -1608,6 +1749,9 @@ func filterToSQL(f Filter, argCounter *int) (string, interface{}) {
 		*argCounter++
 		return sql, f.Value
 
+	case OpBBox:
+		return bboxConditionSQL(f, colExpr, argCounter)
+
 	// pgvector similarity operators
 	// These operators calculate distance - lower values = more similar
 	// Used for vector search with ORDER BY to find most similar vectors
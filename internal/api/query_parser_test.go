@@ -2,6 +2,7 @@ package api
 
 import (
 	"net/url"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -57,6 +58,66 @@ func TestQueryParser_ParseSelect(t *testing.T) {
 	}
 }
 
+func TestQueryParser_ParseEmbeddedRelations(t *testing.T) {
+	parser := NewQueryParser(testConfig())
+
+	values, _ := url.ParseQuery("select=id,author:users!posts_author_id_fkey(name),comments(body,user:user_id(*))" +
+		"&comments.approved=eq.true&comments.order=created_at.desc&comments.limit=3&comments.offset=1" +
+		"&comments.user.active=is.true&title=eq.Hello")
+	params, err := parser.Parse(values)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"id"}, params.Select)
+	require.Len(t, params.Embedded, 2)
+
+	author := params.Embedded[0]
+	assert.Equal(t, "users", author.Name)
+	assert.Equal(t, "author", author.Key())
+	assert.Equal(t, "posts_author_id_fkey", author.Hint)
+	assert.Equal(t, []string{"name"}, author.Select)
+
+	comments := params.Embedded[1]
+	assert.Equal(t, "comments", comments.Key())
+	assert.Equal(t, []string{"body"}, comments.Select)
+	require.Len(t, comments.Filters, 1)
+	assert.Equal(t, Filter{Column: "approved", Operator: OpEqual, Value: "true"}, comments.Filters[0])
+	require.Len(t, comments.Order, 1)
+	assert.Equal(t, "created_at", comments.Order[0].Column)
+	assert.True(t, comments.Order[0].Desc)
+	require.NotNil(t, comments.Limit)
+	assert.Equal(t, 3, *comments.Limit)
+	require.NotNil(t, comments.Offset)
+	assert.Equal(t, 1, *comments.Offset)
+
+	require.Len(t, comments.Embedded, 1)
+	user := comments.Embedded[0]
+	assert.Equal(t, "user_id", user.Name)
+	assert.Equal(t, "user", user.Key())
+	assert.Equal(t, []string{"*"}, user.Select)
+	require.Len(t, user.Filters, 1)
+	assert.Equal(t, "active", user.Filters[0].Column)
+
+	// Filters on the parent are not affected
+	require.Len(t, params.Filters, 1)
+	assert.Equal(t, "title", params.Filters[0].Column)
+}
+
+func TestQueryParser_ParseEmbeddedRelations_Invalid(t *testing.T) {
+	parser := NewQueryParser(testConfig())
+
+	for _, query := range []string{
+		"select=id,posts(id",
+		"select=id,posts(id))",
+		"select=id,po-sts(id)",
+		"select=id,my-posts:posts(id)",
+		"select=id,posts(id)&posts.limit=-1",
+	} {
+		values, _ := url.ParseQuery(query)
+		_, err := parser.Parse(values)
+		assert.Error(t, err, query)
+	}
+}
+
 func TestQueryParser_ParseFilters(t *testing.T) {
 	parser := NewQueryParser(testConfig())
 
@@ -764,11 +825,12 @@ func TestPaginationLimitEnforcement(t *testing.T) {
 	}
 }
 
-func TestParseJSONBPath(t *testing.T) {
+func TestBindJSONBPath(t *testing.T) {
 	tests := []struct {
-		name     string
-		column   string
-		expected string
+		name         string
+		column       string
+		expected     string
+		expectedArgs []interface{}
 	}{
 		{
 			name:     "simple column",
@@ -776,71 +838,124 @@ func TestParseJSONBPath(t *testing.T) {
 			expected: `"name"`,
 		},
 		{
-			name:     "json access single key",
-			column:   "data->key",
-			expected: `"data"->'key'`,
+			name:         "json access single key",
+			column:       "data->key",
+			expected:     `"data"->$1::text`,
+			expectedArgs: []interface{}{"key"},
+		},
+		{
+			name:         "text access single key",
+			column:       "data->>key",
+			expected:     `"data"->>$1::text`,
+			expectedArgs: []interface{}{"key"},
 		},
 		{
-			name:     "text access single key",
-			column:   "data->>key",
-			expected: `"data"->>'key'`,
+			name:         "chained json access",
+			column:       "data->nested->value",
+			expected:     `"data"->$1::text->$2::text`,
+			expectedArgs: []interface{}{"nested", "value"},
 		},
 		{
-			name:     "chained json access",
-			column:   "data->nested->value",
-			expected: `"data"->'nested'->'value'`,
+			name:         "mixed json and text access",
+			column:       "data->nested->>value",
+			expected:     `"data"->$1::text->>$2::text`,
+			expectedArgs: []interface{}{"nested", "value"},
 		},
 		{
-			name:     "mixed json and text access",
-			column:   "data->nested->>value",
-			expected: `"data"->'nested'->>'value'`,
+			name:         "deep nesting",
+			column:       "a->b->c->d->>e",
+			expected:     `"a"->$1::text->$2::text->$3::text->>$4::text`,
+			expectedArgs: []interface{}{"b", "c", "d", "e"},
 		},
 		{
-			name:     "deep nesting",
-			column:   "a->b->c->d->>e",
-			expected: `"a"->'b'->'c'->'d'->>'e'`,
+			name:         "array index",
+			column:       "data->0",
+			expected:     `"data"->$1::int`,
+			expectedArgs: []interface{}{0},
 		},
 		{
-			name:     "array index",
-			column:   "data->0",
-			expected: `"data"->0`,
+			name:         "array index with nested key",
+			column:       "data->0->name",
+			expected:     `"data"->$1::int->$2::text`,
+			expectedArgs: []interface{}{0, "name"},
 		},
 		{
-			name:     "array index with nested key",
-			column:   "data->0->name",
-			expected: `"data"->0->'name'`,
+			name:         "array index with text extraction",
+			column:       "data->0->>name",
+			expected:     `"data"->$1::int->>$2::text`,
+			expectedArgs: []interface{}{0, "name"},
 		},
 		{
-			name:     "array index with text extraction",
-			column:   "data->0->>name",
-			expected: `"data"->0->>'name'`,
+			name:         "realistic geocode example",
+			column:       "geocode->properties->>country",
+			expected:     `"geocode"->$1::text->>$2::text`,
+			expectedArgs: []interface{}{"properties", "country"},
 		},
 		{
-			name:     "realistic geocode example",
-			column:   "geocode->properties->>country",
-			expected: `"geocode"->'properties'->>'country'`,
+			name:         "key with quotes is bound, not interpolated",
+			column:       "data->>x' OR '1'='1",
+			expected:     `"data"->>$1::text`,
+			expectedArgs: []interface{}{"x' OR '1'='1"},
 		},
 		{
-			name:     "metadata stats count",
-			column:   "metadata->stats->>count",
-			expected: `"metadata"->'stats'->>'count'`,
+			name:     "column name with double quote is escaped",
+			column:   `na"me`,
+			expected: `"na""me"`,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := parseJSONBPath(tt.column)
+			argCounter := 1
+			result, args := bindJSONBPath(tt.column, &argCounter)
 			assert.Equal(t, tt.expected, result)
+			assert.Equal(t, tt.expectedArgs, args)
+			assert.Equal(t, 1+len(tt.expectedArgs), argCounter)
 		})
 	}
 }
 
+// boundJSONBPathSuffix matches the key accessors bindJSONBPath is allowed to emit
+var boundJSONBPathSuffix = regexp.MustCompile(`^((->>|->)\$\d+::(text|int))*$`)
+
+func FuzzBindJSONBPath(f *testing.F) {
+	for _, seed := range []string{"data->>key", "data->0->name", "a->b->c->>d", `data->>x' OR '1'='1`, `d"x->>'; DROP TABLE t; --`} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, column string) {
+		argCounter := 1
+		expr, args := bindJSONBPath(column, &argCounter)
+
+		// The column part is a single quoted identifier; everything after it must be bound accessors
+		end := 1
+		for end < len(expr) {
+			if expr[end] == '"' {
+				if end+1 < len(expr) && expr[end+1] == '"' {
+					end += 2
+					continue
+				}
+				break
+			}
+			end++
+		}
+		if expr == "" || expr[0] != '"' || end >= len(expr) {
+			t.Fatalf("bindJSONBPath(%q) = %q does not start with a quoted identifier", column, expr)
+		}
+		if !boundJSONBPathSuffix.MatchString(expr[end+1:]) {
+			t.Fatalf("bindJSONBPath(%q) = %q interpolates path keys", column, expr)
+		}
+		if argCounter != 1+len(args) {
+			t.Fatalf("bindJSONBPath(%q) used %d placeholders for %d args", column, argCounter-1, len(args))
+		}
+	})
+}
+
 func TestFilterToSQLWithJSONBPath(t *testing.T) {
 	tests := []struct {
-		name        string
-		filter      Filter
-		expectedSQL string
-		expectValue bool
+		name         string
+		filter       Filter
+		expectedSQL  string
+		expectedArgs interface{}
 	}{
 		{
 			name: "simple column equality",
@@ -849,8 +964,8 @@ func TestFilterToSQLWithJSONBPath(t *testing.T) {
 				Operator: OpEqual,
 				Value:    "John",
 			},
-			expectedSQL: `"name" = $1`,
-			expectValue: true,
+			expectedSQL:  `"name" = $1`,
+			expectedArgs: "John",
 		},
 		{
 			name: "jsonb path equality",
@@ -859,8 +974,8 @@ func TestFilterToSQLWithJSONBPath(t *testing.T) {
 				Operator: OpEqual,
 				Value:    "value",
 			},
-			expectedSQL: `"data"->'key' = $1`,
-			expectValue: true,
+			expectedSQL:  `"data"->$1::text = $2`,
+			expectedArgs: []interface{}{"key", "value"},
 		},
 		{
 			name: "jsonb text extraction equality",
@@ -869,8 +984,8 @@ func TestFilterToSQLWithJSONBPath(t *testing.T) {
 				Operator: OpEqual,
 				Value:    "value",
 			},
-			expectedSQL: `"data"->>'key' = $1`,
-			expectValue: true,
+			expectedSQL:  `"data"->>$1::text = $2`,
+			expectedArgs: []interface{}{"key", "value"},
 		},
 		{
 			name: "nested jsonb path IS NULL",
@@ -879,8 +994,8 @@ func TestFilterToSQLWithJSONBPath(t *testing.T) {
 				Operator: OpIs,
 				Value:    nil,
 			},
-			expectedSQL: `"geocode"->'properties'->>'country' IS NULL`,
-			expectValue: false,
+			expectedSQL:  `"geocode"->$1::text->>$2::text IS NULL`,
+			expectedArgs: []interface{}{"properties", "country"},
 		},
 		{
 			name: "jsonb text extraction greater than with numeric",
@@ -889,8 +1004,8 @@ func TestFilterToSQLWithJSONBPath(t *testing.T) {
 				Operator: OpGreaterThan,
 				Value:    10,
 			},
-			expectedSQL: `("metadata"->'stats'->>'count')::numeric > $1`,
-			expectValue: true,
+			expectedSQL:  `("metadata"->$1::text->>$2::text)::numeric > $3`,
+			expectedArgs: []interface{}{"stats", "count", 10},
 		},
 		{
 			name: "jsonb text extraction less than with string number",
@@ -899,8 +1014,8 @@ func TestFilterToSQLWithJSONBPath(t *testing.T) {
 				Operator: OpLessThan,
 				Value:    "100",
 			},
-			expectedSQL: `("data"->>'amount')::numeric < $1`,
-			expectValue: true,
+			expectedSQL:  `("data"->>$1::text)::numeric < $2`,
+			expectedArgs: []interface{}{"amount", "100"},
 		},
 		{
 			name: "jsonb json access greater than (no cast)",
@@ -909,8 +1024,8 @@ func TestFilterToSQLWithJSONBPath(t *testing.T) {
 				Operator: OpGreaterThan,
 				Value:    10,
 			},
-			expectedSQL: `"data"->'count' > $1`,
-			expectValue: true,
+			expectedSQL:  `"data"->$1::text > $2`,
+			expectedArgs: []interface{}{"count", 10},
 		},
 		{
 			name: "jsonb IN operator",
@@ -919,8 +1034,8 @@ func TestFilterToSQLWithJSONBPath(t *testing.T) {
 				Operator: OpIn,
 				Value:    []string{"active", "pending"},
 			},
-			expectedSQL: `"data"->>'status' = ANY($1)`,
-			expectValue: true,
+			expectedSQL:  `"data"->>$1::text = ANY($2)`,
+			expectedArgs: []interface{}{"status", []string{"active", "pending"}},
 		},
 		{
 			name: "jsonb LIKE operator",
@@ -929,8 +1044,8 @@ func TestFilterToSQLWithJSONBPath(t *testing.T) {
 				Operator: OpLike,
 				Value:    "%@example.com",
 			},
-			expectedSQL: `"data"->>'email' LIKE $1`,
-			expectValue: true,
+			expectedSQL:  `"data"->>$1::text LIKE $2`,
+			expectedArgs: []interface{}{"email", "%@example.com"},
 		},
 		{
 			name: "array index access",
@@ -939,23 +1054,28 @@ func TestFilterToSQLWithJSONBPath(t *testing.T) {
 				Operator: OpEqual,
 				Value:    "first",
 			},
-			expectedSQL: `"items"->0->>'name' = $1`,
-			expectValue: true,
+			expectedSQL:  `"items"->$1::int->>$2::text = $3`,
+			expectedArgs: []interface{}{0, "name", "first"},
+		},
+		{
+			name: "negated jsonb filter binds the path once",
+			filter: Filter{
+				Column:   "data->>status",
+				Operator: OpNot,
+				Value:    "eq.archived",
+			},
+			expectedSQL:  `NOT ("data"->>$1::text = $2)`,
+			expectedArgs: []interface{}{"status", "archived"},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			argCounter := 1
-			sql, value := filterToSQL(tt.filter, &argCounter)
+			sql, args := filterToSQL(tt.filter, &argCounter)
 
 			assert.Equal(t, tt.expectedSQL, sql)
-
-			if tt.expectValue {
-				assert.Equal(t, tt.filter.Value, value)
-			} else {
-				assert.Nil(t, value)
-			}
+			assert.Equal(t, tt.expectedArgs, args)
 		})
 	}
 }
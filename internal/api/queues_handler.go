@@ -0,0 +1,383 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/middleware"
+	"github.com/nimbleflux/fluxbase/internal/queues"
+	"github.com/rs/zerolog/log"
+)
+
+// maxNackDelay bounds how long a rejected message can be held back, in seconds
+const maxNackDelay = 12 * 60 * 60
+
+// QueuesHandler serves the message queue API used by functions and external workers
+type QueuesHandler struct {
+	store *queues.Store
+	cfg   *config.QueuesConfig
+}
+
+// NewQueuesHandler creates a new queues handler
+func NewQueuesHandler(store *queues.Store, cfg *config.QueuesConfig) *QueuesHandler {
+	return &QueuesHandler{store: store, cfg: cfg}
+}
+
+// RegisterRoutes registers the queue routes. Queues are shared by all users of the instance,
+// so they are limited to admins and service keys.
+func (h *QueuesHandler) RegisterRoutes(app *fiber.App, authService *auth.Service, clientKeyService *auth.ClientKeyService, db *pgxpool.Pool, jwtManager *auth.JWTManager) {
+	q := app.Group("/api/v1/queues",
+		middleware.RequireAuthOrServiceKey(authService, clientKeyService, db, jwtManager),
+		RequireRole("admin", "dashboard_admin", "service_role"),
+	)
+
+	// Read operations require read:queues scope
+	q.Get("/", middleware.RequireScope(auth.ScopeQueuesRead), h.ListQueues)
+	q.Get("/:name", middleware.RequireScope(auth.ScopeQueuesRead), h.GetQueue)
+
+	// Managing queues, sending and consuming messages require write:queues scope
+	q.Post("/", middleware.RequireScope(auth.ScopeQueuesWrite), h.CreateQueue)
+	q.Patch("/:name", middleware.RequireScope(auth.ScopeQueuesWrite), h.UpdateQueue)
+	q.Delete("/:name", middleware.RequireScope(auth.ScopeQueuesWrite), h.DeleteQueue)
+	q.Post("/:name/groups", middleware.RequireScope(auth.ScopeQueuesWrite), h.CreateGroup)
+	q.Delete("/:name/groups/:group", middleware.RequireScope(auth.ScopeQueuesWrite), h.DeleteGroup)
+	q.Post("/:name/messages", middleware.RequireScope(auth.ScopeQueuesWrite), h.Send)
+	q.Delete("/:name/messages", middleware.RequireScope(auth.ScopeQueuesWrite), h.Purge)
+	q.Post("/:name/read", middleware.RequireScope(auth.ScopeQueuesWrite), h.Read)
+	q.Post("/:name/ack", middleware.RequireScope(auth.ScopeQueuesWrite), h.Ack)
+	q.Post("/:name/nack", middleware.RequireScope(auth.ScopeQueuesWrite), h.Nack)
+}
+
+// sendQueueError maps queue store errors to responses
+func sendQueueError(c fiber.Ctx, err error, action string) error {
+	switch {
+	case errors.Is(err, queues.ErrQueueNotFound):
+		return SendResourceNotFound(c, "Queue")
+	case errors.Is(err, queues.ErrGroupNotFound):
+		return SendResourceNotFound(c, "Consumer group")
+	case errors.Is(err, queues.ErrQueueExists):
+		return SendConflict(c, "A queue with this name already exists", ErrCodeAlreadyExists)
+	case errors.Is(err, queues.ErrGroupExists):
+		return SendConflict(c, "Queue already has this consumer group", ErrCodeAlreadyExists)
+	case errors.Is(err, queues.ErrDeadLetterQueueNotFound):
+		return SendBadRequest(c, "dead_letter_queue does not exist", ErrCodeInvalidInput)
+	}
+	log.Error().Err(err).Str("queue", c.Params("name")).Msg("Failed to " + action)
+	return SendOperationFailed(c, action)
+}
+
+// queueGroup returns the consumer group of a request, the default group when omitted
+func queueGroup(group string) (string, error) {
+	if group == "" {
+		return queues.DefaultGroup, nil
+	}
+	if !queues.ValidName(group) {
+		return "", errors.New("group must be 1-63 lowercase letters, digits, '-' or '_'")
+	}
+	return group, nil
+}
+
+// messageIDs validates the message IDs of an ack or nack request
+func (h *QueuesHandler) messageIDs(ids []int64) error {
+	if len(ids) == 0 {
+		return errors.New("ids is required")
+	}
+	if len(ids) > h.cfg.MaxBatchSize {
+		return fmt.Errorf("at most %d ids can be given at once", h.cfg.MaxBatchSize)
+	}
+	return nil
+}
+
+// ListQueues returns every queue with the pending messages of its consumer groups
+// GET /api/v1/queues
+func (h *QueuesHandler) ListQueues(c fiber.Ctx) error {
+	list, err := h.store.ListQueues(c.RequestCtx())
+	if err != nil {
+		return sendQueueError(c, err, "list queues")
+	}
+
+	return c.JSON(fiber.Map{
+		"queues": list,
+	})
+}
+
+// GetQueue returns a queue with the pending messages of its consumer groups
+// GET /api/v1/queues/:name
+func (h *QueuesHandler) GetQueue(c fiber.Ctx) error {
+	q, err := h.store.GetQueue(c.RequestCtx(), c.Params("name"))
+	if err != nil {
+		return sendQueueError(c, err, "get queue")
+	}
+	return c.JSON(q)
+}
+
+// queueSettings is the body of a create or update request
+type queueSettings struct {
+	Name              string  `json:"name"`
+	VisibilityTimeout *int    `json:"visibility_timeout"`
+	MaxDeliveries     *int    `json:"max_deliveries"`
+	DeadLetterQueue   *string `json:"dead_letter_queue"`
+}
+
+// apply copies the settings given in the request onto q
+func (s *queueSettings) apply(q *queues.Queue) {
+	if s.VisibilityTimeout != nil {
+		q.VisibilityTimeout = *s.VisibilityTimeout
+	}
+	if s.MaxDeliveries != nil {
+		q.MaxDeliveries = *s.MaxDeliveries
+	}
+	if s.DeadLetterQueue != nil {
+		q.DeadLetterQueue = s.DeadLetterQueue
+	}
+}
+
+// CreateQueue creates a queue with the default consumer group
+// POST /api/v1/queues {"name": "emails", "visibility_timeout": 60, "max_deliveries": 5, "dead_letter_queue": "emails-dead"}
+func (h *QueuesHandler) CreateQueue(c fiber.Ctx) error {
+	var req queueSettings
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+
+	q := queues.Queue{Name: req.Name}
+	req.apply(&q)
+	if err := q.Validate(); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	created, err := h.store.CreateQueue(c.RequestCtx(), q, getUserIDFromContext(c))
+	if err != nil {
+		return sendQueueError(c, err, "create queue")
+	}
+
+	log.Info().Str("queue", created.Name).Msg("Queue created")
+	return c.Status(fiber.StatusCreated).JSON(created)
+}
+
+// UpdateQueue changes the delivery settings given in the body. A null dead_letter_queue
+// is left unchanged, an empty one removes it.
+// PATCH /api/v1/queues/:name {"visibility_timeout": 120, "dead_letter_queue": ""}
+func (h *QueuesHandler) UpdateQueue(c fiber.Ctx) error {
+	var req queueSettings
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+
+	ctx := c.RequestCtx()
+	q, err := h.store.GetQueue(ctx, c.Params("name"))
+	if err != nil {
+		return sendQueueError(c, err, "update queue")
+	}
+
+	req.apply(q)
+	if err := q.Validate(); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	updated, err := h.store.UpdateQueue(ctx, *q)
+	if err != nil {
+		return sendQueueError(c, err, "update queue")
+	}
+	return c.JSON(updated)
+}
+
+// DeleteQueue deletes a queue with its consumer groups and messages
+// DELETE /api/v1/queues/:name
+func (h *QueuesHandler) DeleteQueue(c fiber.Ctx) error {
+	if err := h.store.DeleteQueue(c.RequestCtx(), c.Params("name")); err != nil {
+		return sendQueueError(c, err, "delete queue")
+	}
+
+	log.Info().Str("queue", c.Params("name")).Msg("Queue deleted")
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// CreateGroup adds a consumer group, which receives the messages sent from now on
+// POST /api/v1/queues/:name/groups {"name": "analytics"}
+func (h *QueuesHandler) CreateGroup(c fiber.Ctx) error {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+	if !queues.ValidName(req.Name) {
+		return SendBadRequest(c, "name must be 1-63 lowercase letters, digits, '-' or '_'", ErrCodeInvalidInput)
+	}
+
+	if err := h.store.CreateGroup(c.RequestCtx(), c.Params("name"), req.Name); err != nil {
+		return sendQueueError(c, err, "create consumer group")
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"name": req.Name,
+	})
+}
+
+// DeleteGroup deletes a consumer group and its pending messages
+// DELETE /api/v1/queues/:name/groups/:group
+func (h *QueuesHandler) DeleteGroup(c fiber.Ctx) error {
+	if err := h.store.DeleteGroup(c.RequestCtx(), c.Params("name"), c.Params("group")); err != nil {
+		return sendQueueError(c, err, "delete consumer group")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Send enqueues messages for every consumer group of the queue
+// POST /api/v1/queues/:name/messages {"messages": [{"body": {"to": "a@example.com"}, "headers": {"trace": "abc"}, "delay_seconds": 10}]}
+func (h *QueuesHandler) Send(c fiber.Ctx) error {
+	var req struct {
+		Messages []queues.OutgoingMessage `json:"messages"`
+	}
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+	if len(req.Messages) == 0 {
+		return SendBadRequest(c, "messages is required", ErrCodeInvalidInput)
+	}
+	if len(req.Messages) > h.cfg.MaxBatchSize {
+		return SendBadRequest(c, fmt.Sprintf("at most %d messages can be sent at once", h.cfg.MaxBatchSize), ErrCodeInvalidInput)
+	}
+	for i, m := range req.Messages {
+		switch {
+		case len(m.Body) == 0 || !json.Valid(m.Body):
+			return SendBadRequest(c, fmt.Sprintf("messages[%d].body must be JSON", i), ErrCodeInvalidInput)
+		case len(m.Body) > h.cfg.MaxMessageBytes:
+			return SendErrorWithCode(c, fiber.StatusRequestEntityTooLarge,
+				fmt.Sprintf("messages[%d].body exceeds %d bytes", i, h.cfg.MaxMessageBytes), ErrCodePayloadTooLarge)
+		case m.Delay < 0 || m.Delay > maxNackDelay:
+			return SendBadRequest(c, fmt.Sprintf("messages[%d].delay_seconds must be between 0 and %d", i, maxNackDelay), ErrCodeInvalidInput)
+		}
+	}
+
+	sent, err := h.store.Send(c.RequestCtx(), c.Params("name"), req.Messages)
+	if err != nil {
+		return sendQueueError(c, err, "send messages")
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"sent": sent,
+	})
+}
+
+// Read delivers visible messages of a consumer group, hiding them from other consumers
+// until the visibility timeout expires. Messages must be acknowledged once processed.
+// POST /api/v1/queues/:name/read {"group": "default", "limit": 10, "visibility_timeout": 60}
+func (h *QueuesHandler) Read(c fiber.Ctx) error {
+	var req struct {
+		Group             string `json:"group"`
+		Limit             int    `json:"limit"`
+		VisibilityTimeout int    `json:"visibility_timeout"`
+	}
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+	group, err := queueGroup(req.Group)
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+	if req.Limit == 0 {
+		req.Limit = 1
+	}
+	if req.Limit < 1 || req.Limit > h.cfg.MaxBatchSize {
+		return SendBadRequest(c, fmt.Sprintf("limit must be between 1 and %d", h.cfg.MaxBatchSize), ErrCodeInvalidInput)
+	}
+	if req.VisibilityTimeout < 0 || req.VisibilityTimeout > maxNackDelay {
+		return SendBadRequest(c, fmt.Sprintf("visibility_timeout must be between 0 and %d", maxNackDelay), ErrCodeInvalidInput)
+	}
+
+	messages, err := h.store.Read(c.RequestCtx(), c.Params("name"), queues.ReadOptions{
+		Group:             group,
+		Limit:             req.Limit,
+		VisibilityTimeout: req.VisibilityTimeout,
+	})
+	if err != nil {
+		return sendQueueError(c, err, "read messages")
+	}
+	return c.JSON(fiber.Map{
+		"messages": messages,
+	})
+}
+
+// Ack deletes processed messages of a consumer group
+// POST /api/v1/queues/:name/ack {"group": "default", "ids": [1, 2]}
+func (h *QueuesHandler) Ack(c fiber.Ctx) error {
+	var req struct {
+		Group string  `json:"group"`
+		IDs   []int64 `json:"ids"`
+	}
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+	group, err := queueGroup(req.Group)
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+	if err := h.messageIDs(req.IDs); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	acked, err := h.store.Ack(c.RequestCtx(), c.Params("name"), group, req.IDs)
+	if err != nil {
+		return sendQueueError(c, err, "acknowledge messages")
+	}
+	return c.JSON(fiber.Map{
+		"acknowledged": acked,
+	})
+}
+
+// Nack returns messages to the queue, visible again after delay_seconds. Each delivery
+// counts towards the queue's max_deliveries.
+// POST /api/v1/queues/:name/nack {"group": "default", "ids": [3], "delay_seconds": 30, "error": "SMTP timeout"}
+func (h *QueuesHandler) Nack(c fiber.Ctx) error {
+	var req struct {
+		Group string  `json:"group"`
+		IDs   []int64 `json:"ids"`
+		Delay int     `json:"delay_seconds"`
+		Error *string `json:"error"`
+	}
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+	group, err := queueGroup(req.Group)
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+	if err := h.messageIDs(req.IDs); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+	if req.Delay < 0 || req.Delay > maxNackDelay {
+		return SendBadRequest(c, fmt.Sprintf("delay_seconds must be between 0 and %d", maxNackDelay), ErrCodeInvalidInput)
+	}
+
+	rejected, err := h.store.Nack(c.RequestCtx(), c.Params("name"), group, req.IDs, req.Delay, req.Error)
+	if err != nil {
+		return sendQueueError(c, err, "reject messages")
+	}
+	return c.JSON(fiber.Map{
+		"rejected": rejected,
+	})
+}
+
+// Purge deletes all messages of the queue, or of one consumer group
+// DELETE /api/v1/queues/:name/messages?group=analytics
+func (h *QueuesHandler) Purge(c fiber.Ctx) error {
+	group := c.Query("group")
+	if group != "" && !queues.ValidName(group) {
+		return SendBadRequest(c, "group must be 1-63 lowercase letters, digits, '-' or '_'", ErrCodeInvalidInput)
+	}
+
+	purged, err := h.store.Purge(c.RequestCtx(), c.Params("name"), group)
+	if err != nil {
+		return sendQueueError(c, err, "purge queue")
+	}
+
+	log.Info().Str("queue", c.Params("name")).Str("group", group).Int64("messages", purged).Msg("Queue purged")
+	return c.JSON(fiber.Map{
+		"purged": purged,
+	})
+}
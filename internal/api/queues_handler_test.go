@@ -0,0 +1,71 @@
+package api
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/queues"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestQueuesApp() *fiber.App {
+	h := NewQueuesHandler(queues.NewStore(nil), &config.QueuesConfig{MaxMessageBytes: 1024, MaxBatchSize: 2})
+	app := fiber.New()
+	app.Post("/queues", h.CreateQueue)
+	app.Post("/queues/:name/groups", h.CreateGroup)
+	app.Post("/queues/:name/messages", h.Send)
+	app.Delete("/queues/:name/messages", h.Purge)
+	app.Post("/queues/:name/read", h.Read)
+	app.Post("/queues/:name/ack", h.Ack)
+	app.Post("/queues/:name/nack", h.Nack)
+	return app
+}
+
+func TestQueuesHandler_ValidatesRequests(t *testing.T) {
+	app := newTestQueuesApp()
+	large := `{"data":"` + strings.Repeat("x", 1024) + `"}`
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		msg    string
+	}{
+		{"invalid queue name", "POST", "/queues", `{"name":"Emails"}`, fiber.StatusBadRequest, "name must be"},
+		{"visibility timeout out of range", "POST", "/queues", `{"name":"emails","visibility_timeout":50000}`, fiber.StatusBadRequest, "visibility_timeout"},
+		{"invalid group name", "POST", "/queues/emails/groups", `{"name":"a b"}`, fiber.StatusBadRequest, "name must be"},
+		{"no messages", "POST", "/queues/emails/messages", `{"messages":[]}`, fiber.StatusBadRequest, "messages is required"},
+		{"batch too large", "POST", "/queues/emails/messages", `{"messages":[{"body":1},{"body":2},{"body":3}]}`, fiber.StatusBadRequest, "at most 2 messages"},
+		{"missing body", "POST", "/queues/emails/messages", `{"messages":[{"headers":{"a":"b"}}]}`, fiber.StatusBadRequest, "messages[0].body must be JSON"},
+		{"body too large", "POST", "/queues/emails/messages", `{"messages":[{"body":` + large + `}]}`, fiber.StatusRequestEntityTooLarge, "exceeds 1024 bytes"},
+		{"negative delay", "POST", "/queues/emails/messages", `{"messages":[{"body":{},"delay_seconds":-1}]}`, fiber.StatusBadRequest, "delay_seconds"},
+		{"read limit too large", "POST", "/queues/emails/read", `{"limit":3}`, fiber.StatusBadRequest, "limit must be between 1 and 2"},
+		{"read invalid group", "POST", "/queues/emails/read", `{"group":"A"}`, fiber.StatusBadRequest, "group must be"},
+		{"ack without ids", "POST", "/queues/emails/ack", `{}`, fiber.StatusBadRequest, "ids is required"},
+		{"ack too many ids", "POST", "/queues/emails/ack", `{"ids":[1,2,3]}`, fiber.StatusBadRequest, "at most 2 ids"},
+		{"nack negative delay", "POST", "/queues/emails/nack", `{"ids":[1],"delay_seconds":-5}`, fiber.StatusBadRequest, "delay_seconds"},
+		{"purge invalid group", "DELETE", "/queues/emails/messages?group=A", ``, fiber.StatusBadRequest, "group must be"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			body := new(strings.Builder)
+			_, _ = io.Copy(body, resp.Body)
+			assert.Contains(t, body.String(), tt.msg)
+		})
+	}
+}
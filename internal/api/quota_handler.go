@@ -26,17 +26,13 @@ func NewQuotaHandler(quotaService *ai.QuotaService, userMgmtService *auth.UserMa
 // GET /api/v1/admin/users
 func (h *QuotaHandler) ListUsersWithQuotas(c fiber.Ctx) error {
 	if h.userMgmtService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "User management service not initialized",
-		})
+		return SendInternalError(c, "User management service not initialized")
 	}
 
 	// Get all users
 	users, err := h.userMgmtService.ListEnrichedUsers(c.RequestCtx(), "app")
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch users",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to fetch users", ErrCodeOperationFailed)
 	}
 
 	if users == nil {
@@ -79,16 +75,12 @@ func (h *QuotaHandler) ListUsersWithQuotas(c fiber.Ctx) error {
 func (h *QuotaHandler) GetUserQuota(c fiber.Ctx) error {
 	userID := c.Params("id")
 	if userID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "User ID is required",
-		})
+		return SendBadRequest(c, "User ID is required", ErrCodeMissingField)
 	}
 
 	quota, err := h.quotaService.GetUserQuotaUsage(c.RequestCtx(), userID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "User not found or quota not set",
-		})
+		return SendNotFound(c, "User not found or quota not set")
 	}
 
 	return c.JSON(quota)
@@ -99,9 +91,7 @@ func (h *QuotaHandler) GetUserQuota(c fiber.Ctx) error {
 func (h *QuotaHandler) SetUserQuota(c fiber.Ctx) error {
 	userID := c.Params("id")
 	if userID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "User ID is required",
-		})
+		return SendBadRequest(c, "User ID is required", ErrCodeMissingField)
 	}
 
 	var req struct {
@@ -111,28 +101,20 @@ func (h *QuotaHandler) SetUserQuota(c fiber.Ctx) error {
 	}
 
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate limits
 	if req.MaxDocuments <= 0 || req.MaxDocuments > 1000000 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "max_documents must be between 1 and 1000000",
-		})
+		return SendBadRequest(c, "max_documents must be between 1 and 1000000", ErrCodeInvalidInput)
 	}
 
 	if req.MaxChunks <= 0 || req.MaxChunks > 10000000 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "max_chunks must be between 1 and 10000000",
-		})
+		return SendBadRequest(c, "max_chunks must be between 1 and 10000000", ErrCodeInvalidInput)
 	}
 
 	if req.MaxStorageBytes <= 0 || req.MaxStorageBytes > 1024*1024*1024*1024 { // 1TB max
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "max_storage_bytes must be between 1 and 1TB",
-		})
+		return SendBadRequest(c, "max_storage_bytes must be between 1 and 1TB", ErrCodeInvalidInput)
 	}
 
 	setReq := ai.SetUserQuotaRequest{
@@ -142,17 +124,13 @@ func (h *QuotaHandler) SetUserQuota(c fiber.Ctx) error {
 	}
 
 	if err := h.quotaService.SetUserQuota(c.RequestCtx(), userID, setReq); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to set quota: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to set quota: %v", err), ErrCodeOperationFailed)
 	}
 
 	// Return the updated quota
 	quota, err := h.quotaService.GetUserQuotaUsage(c.RequestCtx(), userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve updated quota",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve updated quota", ErrCodeOperationFailed)
 	}
 
 	return c.JSON(quota)
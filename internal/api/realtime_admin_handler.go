@@ -15,6 +15,11 @@ import (
 // RealtimeAdminHandler handles realtime enablement for user tables
 type RealtimeAdminHandler struct {
 	db *database.Connection
+
+	// Logical replication publication; tables are added to it instead of getting a
+	// notify trigger when replication is on
+	publication string
+	replication bool
 }
 
 // NewRealtimeAdminHandler creates a new realtime admin handler
@@ -22,6 +27,14 @@ func NewRealtimeAdminHandler(db *database.Connection) *RealtimeAdminHandler {
 	return &RealtimeAdminHandler{db: db}
 }
 
+// SetReplication sets the realtime publication, and whether enabled tables are published
+// through it rather than a notify trigger. Tables are moved between the two when they are
+// enabled again.
+func (h *RealtimeAdminHandler) SetReplication(publication string, enabled bool) {
+	h.publication = publication
+	h.replication = enabled
+}
+
 // EnableRealtimeRequest represents a request to enable realtime on a table
 type EnableRealtimeRequest struct {
 	Schema  string   `json:"schema"`
@@ -35,7 +48,8 @@ type EnableRealtimeResponse struct {
 	Schema      string   `json:"schema"`
 	Table       string   `json:"table"`
 	Events      []string `json:"events"`
-	TriggerName string   `json:"trigger_name"`
+	TriggerName string   `json:"trigger_name,omitempty"`
+	Publication string   `json:"publication,omitempty"`
 	Exclude     []string `json:"exclude,omitempty"`
 }
 
@@ -55,9 +69,7 @@ type RealtimeTableStatus struct {
 func (h *RealtimeAdminHandler) HandleEnableRealtime(c fiber.Ctx) error {
 	var req EnableRealtimeRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Default schema to public
@@ -67,21 +79,15 @@ func (h *RealtimeAdminHandler) HandleEnableRealtime(c fiber.Ctx) error {
 
 	// Validate schema name
 	if err := validateIdentifier(req.Schema, "schema"); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	// Validate table name
 	if req.Table == "" {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Table name is required",
-		})
+		return SendBadRequest(c, "Table name is required", ErrCodeMissingField)
 	}
 	if err := validateIdentifier(req.Table, "table"); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	// Validate and set default events
@@ -90,9 +96,7 @@ func (h *RealtimeAdminHandler) HandleEnableRealtime(c fiber.Ctx) error {
 	} else {
 		for _, event := range req.Events {
 			if event != "INSERT" && event != "UPDATE" && event != "DELETE" {
-				return c.Status(400).JSON(fiber.Map{
-					"error": fmt.Sprintf("Invalid event type: %s. Must be INSERT, UPDATE, or DELETE", event),
-				})
+				return SendBadRequest(c, fmt.Sprintf("Invalid event type: %s. Must be INSERT, UPDATE, or DELETE", event), ErrCodeInvalidInput)
 			}
 		}
 	}
@@ -100,9 +104,7 @@ func (h *RealtimeAdminHandler) HandleEnableRealtime(c fiber.Ctx) error {
 	// Validate excluded columns
 	for _, col := range req.Exclude {
 		if err := validateIdentifier(col, "column"); err != nil {
-			return c.Status(400).JSON(fiber.Map{
-				"error": fmt.Sprintf("Invalid excluded column: %s", err.Error()),
-			})
+			return SendBadRequest(c, fmt.Sprintf("Invalid excluded column: %s", err.Error()), ErrCodeInvalidInput)
 		}
 	}
 
@@ -115,16 +117,12 @@ func (h *RealtimeAdminHandler) HandleEnableRealtime(c fiber.Ctx) error {
 		"realtime":           true,
 	}
 	if systemSchemas[req.Schema] {
-		return c.Status(400).JSON(fiber.Map{
-			"error": fmt.Sprintf("Cannot enable realtime on system schema '%s'", req.Schema),
-		})
+		return SendBadRequest(c, fmt.Sprintf("Cannot enable realtime on system schema '%s'", req.Schema), ErrCodeInvalidInput)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	ctx := c.RequestCtx()
@@ -133,14 +131,10 @@ func (h *RealtimeAdminHandler) HandleEnableRealtime(c fiber.Ctx) error {
 	exists, err := h.tableExists(ctx, req.Schema, req.Table)
 	if err != nil {
 		log.Error().Err(err).Str("table", req.Schema+"."+req.Table).Msg("Failed to check table existence")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to check table existence",
-		})
+		return SendErrorWithCode(c, 500, "Failed to check table existence", ErrCodeOperationFailed)
 	}
 	if !exists {
-		return c.Status(404).JSON(fiber.Map{
-			"error": fmt.Sprintf("Table '%s.%s' does not exist", req.Schema, req.Table),
-		})
+		return SendNotFound(c, fmt.Sprintf("Table '%s.%s' does not exist", req.Schema, req.Table))
 	}
 
 	triggerName := fmt.Sprintf("%s_realtime_notify", req.Table)
@@ -170,14 +164,23 @@ func (h *RealtimeAdminHandler) HandleEnableRealtime(c fiber.Ctx) error {
 			return fmt.Errorf("failed to drop existing trigger: %w", execErr)
 		}
 
-		// 3. Create trigger
-		triggerQuery := fmt.Sprintf(`CREATE TRIGGER %s
+		// 3. Publish changes through logical replication or a notify trigger
+		if h.replication {
+			if pubErr := h.addToPublication(ctx, tx, req.Schema, req.Table); pubErr != nil {
+				return pubErr
+			}
+		} else {
+			triggerQuery := fmt.Sprintf(`CREATE TRIGGER %s
 AFTER INSERT OR UPDATE OR DELETE ON %s.%s
 FOR EACH ROW EXECUTE FUNCTION public.notify_realtime_change()`,
-			quoteIdentifier(triggerName), quoteIdentifier(req.Schema), quoteIdentifier(req.Table))
-		log.Debug().Str("query", triggerQuery).Msg("Creating realtime trigger")
-		if _, execErr := tx.Exec(ctx, triggerQuery); execErr != nil {
-			return fmt.Errorf("failed to create trigger: %w", execErr)
+				quoteIdentifier(triggerName), quoteIdentifier(req.Schema), quoteIdentifier(req.Table))
+			log.Debug().Str("query", triggerQuery).Msg("Creating realtime trigger")
+			if _, execErr := tx.Exec(ctx, triggerQuery); execErr != nil {
+				return fmt.Errorf("failed to create trigger: %w", execErr)
+			}
+			if pubErr := h.dropFromPublication(ctx, tx, req.Schema, req.Table); pubErr != nil {
+				return pubErr
+			}
 		}
 
 		// 4. Upsert into realtime.schema_registry
@@ -198,9 +201,7 @@ SET realtime_enabled = true,
 	})
 	if err != nil {
 		log.Error().Err(err).Str("table", req.Schema+"."+req.Table).Msg("Failed to enable realtime")
-		return c.Status(500).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to enable realtime: %v", err),
-		})
+		return SendErrorWithCode(c, 500, fmt.Sprintf("Failed to enable realtime: %v", err), ErrCodeOperationFailed)
 	}
 
 	log.Info().
@@ -210,13 +211,18 @@ SET realtime_enabled = true,
 		Strs("exclude", req.Exclude).
 		Msg("Realtime enabled on table")
 
-	return c.Status(201).JSON(EnableRealtimeResponse{
-		Schema:      req.Schema,
-		Table:       req.Table,
-		Events:      req.Events,
-		TriggerName: triggerName,
-		Exclude:     req.Exclude,
-	})
+	resp := EnableRealtimeResponse{
+		Schema:  req.Schema,
+		Table:   req.Table,
+		Events:  req.Events,
+		Exclude: req.Exclude,
+	}
+	if h.replication {
+		resp.Publication = h.publication
+	} else {
+		resp.TriggerName = triggerName
+	}
+	return c.Status(201).JSON(resp)
 }
 
 // HandleDisableRealtime disables realtime on a table
@@ -234,9 +240,7 @@ func (h *RealtimeAdminHandler) HandleDisableRealtime(c fiber.Ctx) error {
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	ctx := c.RequestCtx()
@@ -245,14 +249,10 @@ func (h *RealtimeAdminHandler) HandleDisableRealtime(c fiber.Ctx) error {
 	exists, err := h.tableExists(ctx, schema, table)
 	if err != nil {
 		log.Error().Err(err).Str("table", schema+"."+table).Msg("Failed to check table existence")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to check table existence",
-		})
+		return SendErrorWithCode(c, 500, "Failed to check table existence", ErrCodeOperationFailed)
 	}
 	if !exists {
-		return c.Status(404).JSON(fiber.Map{
-			"error": fmt.Sprintf("Table '%s.%s' does not exist", schema, table),
-		})
+		return SendNotFound(c, fmt.Sprintf("Table '%s.%s' does not exist", schema, table))
 	}
 
 	triggerName := fmt.Sprintf("%s_realtime_notify", table)
@@ -265,13 +265,16 @@ func (h *RealtimeAdminHandler) HandleDisableRealtime(c fiber.Ctx) error {
 		}
 		defer tx.Rollback(ctx) //nolint:errcheck
 
-		// 1. Drop the trigger
+		// 1. Drop the trigger and leave the publication
 		dropQuery := fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s.%s",
 			quoteIdentifier(triggerName), quoteIdentifier(schema), quoteIdentifier(table))
 		log.Debug().Str("query", dropQuery).Msg("Dropping realtime trigger")
 		if _, execErr := tx.Exec(ctx, dropQuery); execErr != nil {
 			return fmt.Errorf("failed to drop trigger: %w", execErr)
 		}
+		if pubErr := h.dropFromPublication(ctx, tx, schema, table); pubErr != nil {
+			return pubErr
+		}
 
 		// 2. Update registry (set realtime_enabled = false, keep record for history)
 		updateQuery := `
@@ -287,9 +290,7 @@ WHERE schema_name = $1 AND table_name = $2`
 	})
 	if err != nil {
 		log.Error().Err(err).Str("table", schema+"."+table).Msg("Failed to disable realtime")
-		return c.Status(500).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to disable realtime: %v", err),
-		})
+		return SendErrorWithCode(c, 500, fmt.Sprintf("Failed to disable realtime: %v", err), ErrCodeOperationFailed)
 	}
 
 	log.Info().Str("schema", schema).Str("table", table).Msg("Realtime disabled on table")
@@ -304,9 +305,7 @@ WHERE schema_name = $1 AND table_name = $2`
 func (h *RealtimeAdminHandler) HandleListRealtimeTables(c fiber.Ctx) error {
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	ctx := c.RequestCtx()
@@ -329,9 +328,7 @@ FROM realtime.schema_registry`
 	rows, err := h.db.Pool().Query(ctx, query)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list realtime tables")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to list realtime tables",
-		})
+		return SendErrorWithCode(c, 500, "Failed to list realtime tables", ErrCodeOperationFailed)
 	}
 	defer rows.Close()
 
@@ -373,9 +370,7 @@ func (h *RealtimeAdminHandler) HandleGetRealtimeStatus(c fiber.Ctx) error {
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	ctx := c.RequestCtx()
@@ -397,14 +392,10 @@ WHERE schema_name = $1 AND table_name = $2`
 		// Table not registered - check if the table exists at all
 		exists, checkErr := h.tableExists(ctx, schema, table)
 		if checkErr != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"error": "Failed to check table existence",
-			})
+			return SendErrorWithCode(c, 500, "Failed to check table existence", ErrCodeOperationFailed)
 		}
 		if !exists {
-			return c.Status(404).JSON(fiber.Map{
-				"error": fmt.Sprintf("Table '%s.%s' does not exist", schema, table),
-			})
+			return SendNotFound(c, fmt.Sprintf("Table '%s.%s' does not exist", schema, table))
 		}
 		// Table exists but not registered for realtime
 		return c.JSON(RealtimeTableStatus{
@@ -417,9 +408,7 @@ WHERE schema_name = $1 AND table_name = $2`
 	}
 	if err != nil {
 		log.Error().Err(err).Str("table", schema+"."+table).Msg("Failed to get realtime status")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to get realtime status",
-		})
+		return SendErrorWithCode(c, 500, "Failed to get realtime status", ErrCodeOperationFailed)
 	}
 
 	if createdAt != nil {
@@ -455,18 +444,14 @@ func (h *RealtimeAdminHandler) HandleUpdateRealtimeConfig(c fiber.Ctx) error {
 
 	// Validate that at least one update is provided
 	if len(req.Events) == 0 && req.Exclude == nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "No updates provided",
-		})
+		return SendBadRequest(c, "No updates provided", ErrCodeInvalidInput)
 	}
 
 	// Validate event types
 	if len(req.Events) > 0 {
 		for _, event := range req.Events {
 			if event != "INSERT" && event != "UPDATE" && event != "DELETE" {
-				return c.Status(400).JSON(fiber.Map{
-					"error": fmt.Sprintf("Invalid event type: %s", event),
-				})
+				return SendBadRequest(c, fmt.Sprintf("Invalid event type: %s", event), ErrCodeInvalidInput)
 			}
 		}
 	}
@@ -475,18 +460,14 @@ func (h *RealtimeAdminHandler) HandleUpdateRealtimeConfig(c fiber.Ctx) error {
 	if req.Exclude != nil {
 		for _, col := range req.Exclude {
 			if err := validateIdentifier(col, "column"); err != nil {
-				return c.Status(400).JSON(fiber.Map{
-					"error": fmt.Sprintf("Invalid excluded column: %s", err.Error()),
-				})
+				return SendBadRequest(c, fmt.Sprintf("Invalid excluded column: %s", err.Error()), ErrCodeInvalidInput)
 			}
 		}
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	ctx := c.RequestCtx()
@@ -516,15 +497,11 @@ RETURNING id`, strings.Join(updates, ", "))
 	var id int
 	err := h.db.Pool().QueryRow(ctx, query, args...).Scan(&id)
 	if errors.Is(err, pgx.ErrNoRows) {
-		return c.Status(404).JSON(fiber.Map{
-			"error": fmt.Sprintf("Realtime not enabled on table '%s.%s'", schema, table),
-		})
+		return SendErrorWithCode(c, 404, fmt.Sprintf("Realtime not enabled on table '%s.%s'", schema, table), ErrCodeFeatureDisabled)
 	}
 	if err != nil {
 		log.Error().Err(err).Str("table", schema+"."+table).Msg("Failed to update realtime config")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to update realtime configuration",
-		})
+		return SendErrorWithCode(c, 500, "Failed to update realtime configuration", ErrCodeOperationFailed)
 	}
 
 	log.Info().Str("schema", schema).Str("table", table).Msg("Realtime config updated")
@@ -535,6 +512,60 @@ RETURNING id`, strings.Join(updates, ", "))
 	})
 }
 
+// addToPublication adds a table to the realtime publication, creating the publication
+// when it does not exist yet
+func (h *RealtimeAdminHandler) addToPublication(ctx context.Context, tx pgx.Tx, schema, table string) error {
+	var exists, member bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM pg_publication WHERE pubname = $1),
+		       EXISTS(SELECT 1 FROM pg_publication_tables WHERE pubname = $1 AND schemaname = $2 AND tablename = $3)
+	`, h.publication, schema, table).Scan(&exists, &member)
+	if err != nil {
+		return fmt.Errorf("failed to check publication: %w", err)
+	}
+	if member {
+		return nil
+	}
+
+	query := fmt.Sprintf("ALTER PUBLICATION %s ADD TABLE %s.%s",
+		quoteIdentifier(h.publication), quoteIdentifier(schema), quoteIdentifier(table))
+	if !exists {
+		query = fmt.Sprintf("CREATE PUBLICATION %s FOR TABLE %s.%s",
+			quoteIdentifier(h.publication), quoteIdentifier(schema), quoteIdentifier(table))
+	}
+	log.Debug().Str("query", query).Msg("Adding table to realtime publication")
+	if _, err := tx.Exec(ctx, query); err != nil {
+		return fmt.Errorf("failed to add table to publication: %w", err)
+	}
+	return nil
+}
+
+// dropFromPublication removes a table from the realtime publication if it is in it
+func (h *RealtimeAdminHandler) dropFromPublication(ctx context.Context, tx pgx.Tx, schema, table string) error {
+	if h.publication == "" {
+		return nil
+	}
+
+	var member bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM pg_publication_tables WHERE pubname = $1 AND schemaname = $2 AND tablename = $3)
+	`, h.publication, schema, table).Scan(&member)
+	if err != nil {
+		return fmt.Errorf("failed to check publication: %w", err)
+	}
+	if !member {
+		return nil
+	}
+
+	query := fmt.Sprintf("ALTER PUBLICATION %s DROP TABLE %s.%s",
+		quoteIdentifier(h.publication), quoteIdentifier(schema), quoteIdentifier(table))
+	log.Debug().Str("query", query).Msg("Removing table from realtime publication")
+	if _, err := tx.Exec(ctx, query); err != nil {
+		return fmt.Errorf("failed to remove table from publication: %w", err)
+	}
+	return nil
+}
+
 // tableExists checks if a table exists in the database
 func (h *RealtimeAdminHandler) tableExists(ctx context.Context, schema, table string) (bool, error) {
 	var exists bool
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/realtime"
+)
+
+// channelRulePolicy restricts the broadcast and presence channels of a realtime.channels
+// rule to its actions, roles and JWT claim. Admins and the service role may do anything,
+// so the server can publish on channels clients may only subscribe to.
+func channelRulePolicy(rule config.RealtimeChannelRule) realtime.ChannelPolicy {
+	return func(ctx context.Context, conn *realtime.Connection, channel string, action realtime.ChannelAction) error {
+		switch conn.Role {
+		case "admin", "dashboard_admin", "service_role":
+			return nil
+		}
+
+		if len(rule.Actions) > 0 && !slices.Contains(rule.Actions, string(action)) {
+			return fmt.Errorf("%s is not allowed on this channel", action)
+		}
+		if len(rule.Roles) > 0 {
+			if !slices.Contains(rule.Roles, conn.Role) {
+				return errors.New("access to this channel denied")
+			}
+		} else if conn.UserID == nil {
+			return errors.New("authentication required for this channel")
+		}
+
+		if rule.Claim != "" {
+			id := strings.TrimPrefix(channel, rule.Prefix)
+			if id == "" || !claimMatches(conn.Claims, rule.Claim, id) {
+				return errors.New("access to this channel denied")
+			}
+		}
+		return nil
+	}
+}
+
+// claimMatches reports whether the claim at path (dot-separated for nested claims) equals
+// value, or contains it when the claim is an array
+func claimMatches(claims map[string]interface{}, path, value string) bool {
+	var claim interface{} = claims
+	for _, key := range strings.Split(path, ".") {
+		object, ok := claim.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if claim, ok = object[key]; !ok {
+			return false
+		}
+	}
+
+	if values, ok := claim.([]interface{}); ok {
+		for _, v := range values {
+			if claimString(v) == value {
+				return true
+			}
+		}
+		return false
+	}
+	return claimString(claim) == value
+}
+
+// claimString formats a scalar claim value, or returns "" for other values
+func claimString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
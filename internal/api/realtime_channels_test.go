@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/realtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannelRulePolicy(t *testing.T) {
+	userID := "2b8a4a9e-2f34-4a4f-8f0e-2f2a0d1b5c3d"
+	teams := channelRulePolicy(config.RealtimeChannelRule{Prefix: "team:", Claim: "app_metadata.teams"})
+	announcements := channelRulePolicy(config.RealtimeChannelRule{Prefix: "announcements:", Actions: []string{"subscribe"}})
+	moderators := channelRulePolicy(config.RealtimeChannelRule{Prefix: "mod:", Roles: []string{"moderator"}, Claim: "org_id"})
+
+	member := map[string]interface{}{
+		"app_metadata": map[string]interface{}{"teams": []interface{}{"red", "blue"}},
+		"org_id":       float64(7),
+	}
+
+	tests := []struct {
+		name    string
+		policy  realtime.ChannelPolicy
+		conn    *realtime.Connection
+		channel string
+		action  realtime.ChannelAction
+		wantErr string
+	}{
+		{"claim array contains team", teams, realtime.NewConnectionSync("c1", nil, &userID, "authenticated", member), "team:blue", realtime.ChannelActionBroadcast, ""},
+		{"other team", teams, realtime.NewConnectionSync("c2", nil, &userID, "authenticated", member), "team:green", realtime.ChannelActionSubscribe, "access to this channel denied"},
+		{"missing claim", teams, realtime.NewConnectionSync("c3", nil, &userID, "authenticated", nil), "team:blue", realtime.ChannelActionSubscribe, "access to this channel denied"},
+		{"empty channel id", teams, realtime.NewConnectionSync("c4", nil, &userID, "authenticated", member), "team:", realtime.ChannelActionSubscribe, "access to this channel denied"},
+		{"anonymous", teams, realtime.NewConnectionSync("c5", nil, nil, "anon", member), "team:blue", realtime.ChannelActionSubscribe, "authentication required"},
+		{"service role bypasses", teams, realtime.NewConnectionSync("c6", nil, nil, "service_role", nil), "team:green", realtime.ChannelActionBroadcast, ""},
+		{"allowed action", announcements, realtime.NewConnectionSync("c7", nil, &userID, "authenticated", nil), "announcements:all", realtime.ChannelActionSubscribe, ""},
+		{"disallowed action", announcements, realtime.NewConnectionSync("c8", nil, &userID, "authenticated", nil), "announcements:all", realtime.ChannelActionBroadcast, "broadcast is not allowed"},
+		{"admin publishes on read-only channel", announcements, realtime.NewConnectionSync("c9", nil, nil, "admin", nil), "announcements:all", realtime.ChannelActionBroadcast, ""},
+		{"role and numeric claim", moderators, realtime.NewConnectionSync("c10", nil, &userID, "moderator", member), "mod:7", realtime.ChannelActionPresence, ""},
+		{"wrong role", moderators, realtime.NewConnectionSync("c11", nil, &userID, "authenticated", member), "mod:7", realtime.ChannelActionPresence, "access to this channel denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy(context.Background(), tt.conn, tt.channel, tt.action)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,488 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+// validationGlobalQueryParams are consumed by middleware rather than handlers, so they are accepted on every route
+var validationGlobalQueryParams = map[string]bool{
+	"token":     true, // auth token for clients that cannot set headers (WebSocket, EventSource)
+	"clientkey": true,
+	"branch":    true,
+}
+
+// RequestViolation describes one way a request does not match its endpoint's schema
+type RequestViolation struct {
+	In      string `json:"in"` // "path", "query" or "body"
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// RequestValidator rejects requests to built-in endpoints that do not match their OpenAPI
+// schemas, before any handler runs. Routes that are not in the spec are passed through.
+type RequestValidator struct {
+	routes  map[string][]validatedRoute // by HTTP method
+	schemas map[string]any              // components.schemas, for resolving $ref
+}
+
+type validatedRoute struct {
+	segments     []string // path template segments; "{name}" marks a path parameter
+	literals     int      // number of literal segments, so "/items/search" wins over "/items/{id}"
+	params       []validatedParam
+	body         map[string]any
+	bodyRequired bool
+}
+
+type validatedParam struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required"`
+	Schema   map[string]any `json:"schema"`
+}
+
+// NewRequestValidator builds a validator for the operations of spec whose route group is listed in groups.
+// The route group is the first path segment after /api/v1 (e.g. "auth" or "storage"); "*" selects all groups.
+func NewRequestValidator(spec OpenAPISpec, groups []string) (*RequestValidator, error) {
+	// Round-trip through JSON so the hand-written schemas, which mix map and slice types, share one shape
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OpenAPI spec: %w", err)
+	}
+	var normalized struct {
+		Paths map[string]map[string]struct {
+			Parameters  []validatedParam `json:"parameters"`
+			RequestBody *struct {
+				Required bool `json:"required"`
+				Content  map[string]struct {
+					Schema map[string]any `json:"schema"`
+				} `json:"content"`
+			} `json:"requestBody"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, fmt.Errorf("failed to decode OpenAPI spec: %w", err)
+	}
+
+	v := &RequestValidator{
+		routes:  make(map[string][]validatedRoute),
+		schemas: normalized.Components.Schemas,
+	}
+	for path, operations := range normalized.Paths {
+		group := requestValidationGroup(path)
+		if group == "" || !(slices.Contains(groups, "*") || slices.Contains(groups, group)) {
+			continue
+		}
+
+		segments := strings.Split(strings.Trim(path, "/"), "/")
+		literals := 0
+		for _, segment := range segments {
+			if !strings.HasPrefix(segment, "{") {
+				literals++
+			}
+		}
+
+		for method, op := range operations {
+			route := validatedRoute{segments: segments, literals: literals, params: op.Parameters}
+			if op.RequestBody != nil {
+				if media, ok := op.RequestBody.Content["application/json"]; ok {
+					route.body = media.Schema
+					route.bodyRequired = op.RequestBody.Required
+				}
+			}
+			method = strings.ToUpper(method)
+			v.routes[method] = append(v.routes[method], route)
+		}
+	}
+	return v, nil
+}
+
+// requestValidationGroup returns the route group of an /api/v1 path
+func requestValidationGroup(path string) string {
+	rest, ok := strings.CutPrefix(path, "/api/v1/")
+	if !ok {
+		return ""
+	}
+	group, _, _ := strings.Cut(rest, "/")
+	return group
+}
+
+// OperationCount returns the number of operations being validated
+func (v *RequestValidator) OperationCount() int {
+	count := 0
+	for _, routes := range v.routes {
+		count += len(routes)
+	}
+	return count
+}
+
+// Middleware returns a handler that rejects invalid requests with 400 and a list of violations
+func (v *RequestValidator) Middleware() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		route, pathParams := v.match(c.Method(), c.Path())
+		if route == nil {
+			return c.Next()
+		}
+
+		violations := v.validate(c, route, pathParams)
+		if len(violations) == 0 {
+			return c.Next()
+		}
+		return SendErrorWithDetails(c, fiber.StatusBadRequest, "Request validation failed", ErrCodeValidationFailed,
+			violations[0].String(), "", violations)
+	}
+}
+
+// String formats the violation for the error message
+func (rv RequestViolation) String() string {
+	switch {
+	case rv.In == "body" && rv.Field == "":
+		return "request body " + rv.Message
+	case rv.In == "body":
+		return fmt.Sprintf("body field %q %s", rv.Field, rv.Message)
+	default:
+		return fmt.Sprintf("%s parameter %q %s", rv.In, rv.Field, rv.Message)
+	}
+}
+
+// match finds the operation for a request path, preferring the template with the most literal segments
+func (v *RequestValidator) match(method, path string) (*validatedRoute, map[string]string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	var best *validatedRoute
+	var bestParams map[string]string
+	routes := v.routes[method]
+	for i := range routes {
+		route := &routes[i]
+		if len(route.segments) != len(segments) || (best != nil && route.literals <= best.literals) {
+			continue
+		}
+		params := make(map[string]string)
+		matched := true
+		for j, segment := range route.segments {
+			if name, ok := strings.CutPrefix(segment, "{"); ok {
+				value, err := url.PathUnescape(segments[j])
+				if err != nil {
+					value = segments[j]
+				}
+				params[strings.TrimSuffix(name, "}")] = value
+				continue
+			}
+			if segment != segments[j] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			best, bestParams = route, params
+		}
+	}
+	return best, bestParams
+}
+
+// validate checks the request's path parameters, query parameters and JSON body
+func (v *RequestValidator) validate(c fiber.Ctx, route *validatedRoute, pathParams map[string]string) []RequestViolation {
+	var violations []RequestViolation
+	queries := c.Queries()
+
+	declared := make(map[string]bool)
+	for _, param := range route.params {
+		switch param.In {
+		case "path":
+			if message := v.checkParam(param.Schema, pathParams[param.Name]); message != "" {
+				violations = append(violations, RequestViolation{In: "path", Field: param.Name, Message: message})
+			}
+		case "query":
+			declared[param.Name] = true
+			value, present := queries[param.Name]
+			if !present {
+				if param.Required {
+					violations = append(violations, RequestViolation{In: "query", Field: param.Name, Message: "is required"})
+				}
+				continue
+			}
+			if message := v.checkParam(param.Schema, value); message != "" {
+				violations = append(violations, RequestViolation{In: "query", Field: param.Name, Message: message})
+			}
+		}
+	}
+
+	var unknown []string
+	for name := range queries {
+		if !declared[name] && !validationGlobalQueryParams[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		violations = append(violations, RequestViolation{In: "query", Field: name, Message: "is not a known parameter"})
+	}
+
+	if route.body != nil {
+		violations = append(violations, v.validateBody(c, route)...)
+	}
+	return violations
+}
+
+// validateBody checks a JSON request body. Bodies of other content types (e.g. multipart uploads) are left to the handler.
+func (v *RequestValidator) validateBody(c fiber.Ctx, route *validatedRoute) []RequestViolation {
+	body := c.Body()
+	if len(strings.TrimSpace(string(body))) == 0 {
+		if route.bodyRequired {
+			return []RequestViolation{{In: "body", Message: "is required"}}
+		}
+		return nil
+	}
+
+	contentType := strings.ToLower(c.Get(fiber.HeaderContentType))
+	if contentType != "" && !strings.HasPrefix(contentType, fiber.MIMEApplicationJSON) {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []RequestViolation{{In: "body", Message: "is not valid JSON"}}
+	}
+
+	var violations []RequestViolation
+	v.checkValue(route.body, value, "", &violations, 0)
+	return violations
+}
+
+// checkParam validates a path or query parameter, which arrives as a string, against its schema
+func (v *RequestValidator) checkParam(schema map[string]any, raw string) string {
+	schema = v.resolve(schema, 0)
+	if schema == nil {
+		return ""
+	}
+
+	var value any = raw
+	switch schema["type"] {
+	case "integer":
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return "must be an integer"
+		}
+		value = float64(n)
+	case "number":
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return "must be a number"
+		}
+		value = n
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return "must be true or false"
+		}
+		value = b
+	}
+
+	var violations []RequestViolation
+	v.checkValue(schema, value, "", &violations, 0)
+	if len(violations) > 0 {
+		return violations[0].Message
+	}
+	return ""
+}
+
+// maxSchemaDepth bounds $ref resolution and nesting, so recursive schemas cannot loop
+const maxSchemaDepth = 32
+
+// resolve follows a local $ref to its component schema
+func (v *RequestValidator) resolve(schema map[string]any, depth int) map[string]any {
+	for depth < maxSchemaDepth {
+		ref, ok := schema["$ref"].(string)
+		if !ok {
+			return schema
+		}
+		target, _ := v.schemas[strings.TrimPrefix(ref, "#/components/schemas/")].(map[string]any)
+		schema = target
+		depth++
+	}
+	return nil
+}
+
+// checkValue validates a decoded JSON value against a schema and records violations under field
+func (v *RequestValidator) checkValue(schema map[string]any, value any, field string, violations *[]RequestViolation, depth int) {
+	schema = v.resolve(schema, depth)
+	if schema == nil || depth > maxSchemaDepth {
+		return
+	}
+	fail := func(message string) {
+		*violations = append(*violations, RequestViolation{In: "body", Field: field, Message: message})
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > 0 && !slices.Contains(enum, value) {
+		options := make([]string, len(enum))
+		for i, option := range enum {
+			options[i] = fmt.Sprint(option)
+		}
+		fail("must be one of: " + strings.Join(options, ", "))
+		return
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		v.checkObject(schema, object, field, violations, depth)
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		itemSchema, _ := schema["items"].(map[string]any)
+		for i, item := range items {
+			v.checkValue(itemSchema, item, fmt.Sprintf("%s[%d]", field, i), violations, depth+1)
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		if message := checkString(schema, s); message != "" {
+			fail(message)
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			fail("must be an integer")
+			return
+		}
+		if message := checkRange(schema, n); message != "" {
+			fail(message)
+		}
+	case "number":
+		n, ok := value.(float64)
+		if !ok {
+			fail("must be a number")
+			return
+		}
+		if message := checkRange(schema, n); message != "" {
+			fail(message)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be a boolean")
+		}
+	}
+}
+
+// checkObject validates required and known properties of a JSON object
+func (v *RequestValidator) checkObject(schema map[string]any, object map[string]any, field string, violations *[]RequestViolation, depth int) {
+	join := func(name string) string {
+		if field == "" {
+			return name
+		}
+		return field + "." + name
+	}
+
+	required, _ := schema["required"].([]any)
+	for _, name := range required {
+		name, _ := name.(string)
+		if object[name] == nil {
+			*violations = append(*violations, RequestViolation{In: "body", Field: join(name), Message: "is required"})
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := object[name]
+		// Handlers treat null like an omitted field; missing required fields are reported above
+		if value == nil {
+			continue
+		}
+		if propertySchema, ok := properties[name].(map[string]any); ok {
+			v.checkValue(propertySchema, value, join(name), violations, depth+1)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				*violations = append(*violations, RequestViolation{In: "body", Field: join(name), Message: "is not a known field"})
+			}
+		case map[string]any:
+			v.checkValue(additional, value, join(name), violations, depth+1)
+		}
+	}
+}
+
+// checkString validates string length and format
+func checkString(schema map[string]any, s string) string {
+	length := len([]rune(s))
+	if minLength, ok := schemaNumber(schema, "minLength"); ok && float64(length) < minLength {
+		return fmt.Sprintf("must be at least %d characters", int(minLength))
+	}
+	if maxLength, ok := schemaNumber(schema, "maxLength"); ok && float64(length) > maxLength {
+		return fmt.Sprintf("must be at most %d characters", int(maxLength))
+	}
+
+	switch schema["format"] {
+	case "uuid":
+		if _, err := uuid.Parse(s); err != nil {
+			return "must be a UUID"
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return "must be an RFC 3339 date-time"
+		}
+	case "email":
+		if _, err := mail.ParseAddress(s); err != nil {
+			return "must be an email address"
+		}
+	case "uri":
+		if u, err := url.Parse(s); err != nil || u.Scheme == "" {
+			return "must be an absolute URI"
+		}
+	}
+	return ""
+}
+
+// checkRange validates numeric bounds
+func checkRange(schema map[string]any, n float64) string {
+	if minimum, ok := schemaNumber(schema, "minimum"); ok && n < minimum {
+		return fmt.Sprintf("must be at least %v", minimum)
+	}
+	if maximum, ok := schemaNumber(schema, "maximum"); ok && n > maximum {
+		return fmt.Sprintf("must be at most %v", maximum)
+	}
+	return ""
+}
+
+// schemaNumber reads a numeric schema keyword, which some hand-written schemas give as a string
+func schemaNumber(schema map[string]any, keyword string) (float64, bool) {
+	switch value := schema[keyword].(type) {
+	case float64:
+		return value, true
+	case string:
+		n, err := strconv.ParseFloat(value, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requestValidationTestSpec() OpenAPISpec {
+	return OpenAPISpec{
+		Paths: map[string]OpenAPIPath{
+			"/api/v1/widgets": {
+				"get": OpenAPIOperation{
+					Parameters: []OpenAPIParameter{
+						{Name: "limit", In: "query", Schema: map[string]string{"type": "integer"}},
+						{Name: "active", In: "query", Schema: map[string]string{"type": "boolean"}},
+					},
+				},
+				"post": OpenAPIOperation{
+					RequestBody: &OpenAPIRequestBody{
+						Required: true,
+						Content: map[string]OpenAPIMedia{
+							"application/json": {Schema: map[string]string{"$ref": "#/components/schemas/Widget"}},
+						},
+					},
+				},
+			},
+			"/api/v1/widgets/{id}": {
+				"get": OpenAPIOperation{
+					Parameters: []OpenAPIParameter{
+						{Name: "id", In: "path", Required: true, Schema: map[string]string{"type": "string", "format": "uuid"}},
+					},
+				},
+			},
+			"/api/v1/widgets/search": {
+				"get": OpenAPIOperation{
+					Parameters: []OpenAPIParameter{
+						{Name: "q", In: "query", Required: true, Schema: map[string]string{"type": "string"}},
+					},
+				},
+			},
+			"/api/v1/gadgets": {
+				"get": OpenAPIOperation{},
+			},
+		},
+		Components: OpenAPIComponents{
+			Schemas: map[string]interface{}{
+				"Widget": map[string]interface{}{
+					"type":                 "object",
+					"required":             []string{"name"},
+					"additionalProperties": false,
+					"properties": map[string]interface{}{
+						"name":  map[string]string{"type": "string", "minLength": "3"},
+						"size":  map[string]interface{}{"type": "integer", "minimum": 1},
+						"color": map[string]interface{}{"type": "string", "enum": []string{"red", "blue"}},
+						"tags":  map[string]interface{}{"type": "array", "items": map[string]string{"type": "string"}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newRequestValidationTestApp(t *testing.T, groups ...string) *fiber.App {
+	t.Helper()
+	validator, err := NewRequestValidator(requestValidationTestSpec(), groups)
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Use(validator.Middleware())
+	app.All("/*", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	return app
+}
+
+func doValidationRequest(t *testing.T, app *fiber.App, method, target, body string) (int, ErrorResponse) {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var result ErrorResponse
+	if resp.StatusCode == fiber.StatusBadRequest {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	}
+	return resp.StatusCode, result
+}
+
+func violationFields(t *testing.T, result ErrorResponse) []string {
+	t.Helper()
+	details, ok := result.Details.([]interface{})
+	require.True(t, ok)
+	fields := make([]string, 0, len(details))
+	for _, detail := range details {
+		entry := detail.(map[string]interface{})
+		field, _ := entry["field"].(string)
+		fields = append(fields, entry["in"].(string)+":"+field)
+	}
+	return fields
+}
+
+func TestRequestValidator_Query(t *testing.T) {
+	app := newRequestValidationTestApp(t, "widgets")
+
+	t.Run("valid parameters pass", func(t *testing.T) {
+		status, _ := doValidationRequest(t, app, http.MethodGet, "/api/v1/widgets?limit=10&active=true&branch=dev", "")
+		assert.Equal(t, fiber.StatusNoContent, status)
+	})
+
+	t.Run("wrong types and unknown parameters are rejected", func(t *testing.T) {
+		status, result := doValidationRequest(t, app, http.MethodGet, "/api/v1/widgets?limit=ten&colour=red", "")
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		assert.Equal(t, ErrCodeValidationFailed, result.Code)
+		assert.Equal(t, `query parameter "limit" must be an integer`, result.Message)
+		assert.Equal(t, []string{"query:limit", "query:colour"}, violationFields(t, result))
+	})
+
+	t.Run("literal segments win over path parameters", func(t *testing.T) {
+		status, result := doValidationRequest(t, app, http.MethodGet, "/api/v1/widgets/search", "")
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		assert.Equal(t, []string{"query:q"}, violationFields(t, result))
+	})
+
+	t.Run("path parameters are checked", func(t *testing.T) {
+		status, result := doValidationRequest(t, app, http.MethodGet, "/api/v1/widgets/not-a-uuid", "")
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		assert.Equal(t, `path parameter "id" must be a UUID`, result.Message)
+
+		status, _ = doValidationRequest(t, app, http.MethodGet, "/api/v1/widgets/8f14e45f-ceea-4e6b-9c1a-2f3b0f4b6c11", "")
+		assert.Equal(t, fiber.StatusNoContent, status)
+	})
+}
+
+func TestRequestValidator_Body(t *testing.T) {
+	app := newRequestValidationTestApp(t, "widgets")
+
+	tests := []struct {
+		name   string
+		body   string
+		fields []string
+	}{
+		{name: "valid body", body: `{"name":"sprocket","size":2,"color":"red","tags":["a"]}`},
+		{name: "null optional field", body: `{"name":"sprocket","size":null}`},
+		{name: "missing body", body: "", fields: []string{"body:"}},
+		{name: "invalid JSON", body: `{"name":`, fields: []string{"body:"}},
+		{name: "missing required field", body: `{"size":2}`, fields: []string{"body:name"}},
+		{name: "unknown field", body: `{"name":"sprocket","weight":3}`, fields: []string{"body:weight"}},
+		{
+			name:   "constraint violations",
+			body:   `{"name":"ab","size":0.5,"color":"green","tags":["a",1]}`,
+			fields: []string{"body:color", "body:name", "body:size", "body:tags[1]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, result := doValidationRequest(t, app, http.MethodPost, "/api/v1/widgets", tt.body)
+			if tt.fields == nil {
+				assert.Equal(t, fiber.StatusNoContent, status)
+				return
+			}
+			assert.Equal(t, fiber.StatusBadRequest, status)
+			assert.Equal(t, tt.fields, violationFields(t, result))
+		})
+	}
+
+	t.Run("non-JSON bodies are left to the handler", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/widgets", strings.NewReader("name=sprocket"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	})
+}
+
+func TestRequestValidator_Groups(t *testing.T) {
+	t.Run("groups that are not listed are not validated", func(t *testing.T) {
+		app := newRequestValidationTestApp(t, "widgets")
+		status, _ := doValidationRequest(t, app, http.MethodGet, "/api/v1/gadgets?anything=1", "")
+		assert.Equal(t, fiber.StatusNoContent, status)
+	})
+
+	t.Run("wildcard validates every group", func(t *testing.T) {
+		app := newRequestValidationTestApp(t, "*")
+		status, _ := doValidationRequest(t, app, http.MethodGet, "/api/v1/gadgets?anything=1", "")
+		assert.Equal(t, fiber.StatusBadRequest, status)
+	})
+
+	t.Run("routes missing from the spec pass through", func(t *testing.T) {
+		app := newRequestValidationTestApp(t, "*")
+		status, _ := doValidationRequest(t, app, http.MethodDelete, "/api/v1/widgets?anything=1", "")
+		assert.Equal(t, fiber.StatusNoContent, status)
+	})
+}
+
+func TestRequestValidator_BuiltInSpec(t *testing.T) {
+	spec := NewOpenAPIHandler(nil).generateSpec(nil, "")
+	validator, err := NewRequestValidator(spec, []string{"auth"})
+	require.NoError(t, err)
+	assert.Positive(t, validator.OperationCount())
+
+	app := fiber.New()
+	app.Use(validator.Middleware())
+	app.Post("/api/v1/auth/signup", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	status, result := doValidationRequest(t, app, http.MethodPost, "/api/v1/auth/signup", `{"email":"not-an-email","password":"short"}`)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Equal(t, []string{"body:email", "body:password"}, violationFields(t, result))
+
+	status, _ = doValidationRequest(t, app, http.MethodPost, "/api/v1/auth/signup", `{"email":"user@example.com","password":"long-enough"}`)
+	assert.Equal(t, fiber.StatusNoContent, status)
+}
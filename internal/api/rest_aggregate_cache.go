@@ -0,0 +1,227 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// parseMaxStalePreference extracts the staleness a client accepts from a Prefer header,
+// e.g. "Prefer: max-stale=60". It returns 0 when the preference is absent.
+func parseMaxStalePreference(prefer string) (time.Duration, error) {
+	for _, pref := range strings.Split(prefer, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pref), "=")
+		if strings.TrimSpace(key) != "max-stale" {
+			continue
+		}
+
+		seconds, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || seconds <= 0 {
+			return 0, fmt.Errorf("invalid max-stale preference %q: must be a positive number of seconds", value)
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return 0, nil
+}
+
+// aggregateCacheEntry is a cached aggregate response
+type aggregateCacheEntry struct {
+	body         []byte
+	contentRange string
+	storedAt     time.Time
+}
+
+// aggregateCache holds the responses of aggregate queries (aggregations or group_by) for
+// clients that accept stale results. Entries are grouped by table so that a change to a
+// table drops all of its results; entries older than maxStale are never served.
+type aggregateCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxStale   time.Duration
+	tables     map[string]map[string]*aggregateCacheEntry
+	size       int
+	now        func() time.Time
+}
+
+// newAggregateCache creates an aggregate result cache. A maxEntries of 0 disables caching.
+func newAggregateCache(maxEntries int, maxStale time.Duration) *aggregateCache {
+	return &aggregateCache{
+		maxEntries: maxEntries,
+		maxStale:   maxStale,
+		tables:     make(map[string]map[string]*aggregateCacheEntry),
+		now:        time.Now,
+	}
+}
+
+// enabled reports whether results are cached
+func (ac *aggregateCache) enabled() bool {
+	return ac != nil && ac.maxEntries > 0 && ac.maxStale > 0
+}
+
+// clampStale limits the staleness a client accepts to the configured maximum
+func (ac *aggregateCache) clampStale(maxStale time.Duration) time.Duration {
+	return min(maxStale, ac.maxStale)
+}
+
+// get returns the cached response for a key if it is no older than maxStale
+func (ac *aggregateCache) get(table, key string, maxStale time.Duration) (*aggregateCacheEntry, bool) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	entry, ok := ac.tables[table][key]
+	if !ok || ac.now().Sub(entry.storedAt) > maxStale {
+		return nil, false
+	}
+	return entry, true
+}
+
+// put stores a response, evicting expired entries and then the oldest entry when full
+func (ac *aggregateCache) put(table, key string, entry *aggregateCacheEntry) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	if _, exists := ac.tables[table][key]; !exists && ac.size >= ac.maxEntries {
+		ac.evictLocked()
+	}
+
+	entries, ok := ac.tables[table]
+	if !ok {
+		entries = make(map[string]*aggregateCacheEntry)
+		ac.tables[table] = entries
+	}
+	if _, exists := entries[key]; !exists {
+		ac.size++
+	}
+	entries[key] = entry
+}
+
+// evictLocked drops expired entries, or the oldest entry if none has expired
+func (ac *aggregateCache) evictLocked() {
+	now := ac.now()
+	var oldestTable, oldestKey string
+	var oldest time.Time
+
+	for table, entries := range ac.tables {
+		for key, entry := range entries {
+			if now.Sub(entry.storedAt) > ac.maxStale {
+				delete(entries, key)
+				ac.size--
+				continue
+			}
+			if oldestKey == "" || entry.storedAt.Before(oldest) {
+				oldestTable, oldestKey, oldest = table, key, entry.storedAt
+			}
+		}
+		if len(entries) == 0 {
+			delete(ac.tables, table)
+		}
+	}
+
+	if ac.size >= ac.maxEntries && oldestKey != "" {
+		delete(ac.tables[oldestTable], oldestKey)
+		ac.size--
+		if len(ac.tables[oldestTable]) == 0 {
+			delete(ac.tables, oldestTable)
+		}
+	}
+}
+
+// invalidate drops the cached results of a table
+func (ac *aggregateCache) invalidate(table string) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	ac.size -= len(ac.tables[table])
+	delete(ac.tables, table)
+}
+
+// aggregateCacheKey identifies an aggregate response: the query and its arguments, the
+// requested count, and the RLS context (role, user and JWT claims) it was read under
+func aggregateCacheKey(c fiber.Ctx, query string, args []interface{}, count CountType) (string, error) {
+	encodedArgs, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(c.Locals("jwt_claims"))
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	for _, part := range []string{
+		fmt.Sprintf("%v", c.Locals("rls_role")),
+		fmt.Sprintf("%v", c.Locals("rls_user_id")),
+		string(claims),
+		query,
+		string(encodedArgs),
+		string(count),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// InvalidateAggregates drops the cached aggregate results of a table. It is called for
+// writes through the REST API and for realtime change events.
+func (h *RESTHandler) InvalidateAggregates(schema, table string) {
+	if h.aggregates.enabled() {
+		h.aggregates.invalidate(schema + "." + table)
+	}
+}
+
+// invalidateAggregatesAfterWrite drops the cached aggregate results of a table after a
+// successful write request
+func (h *RESTHandler) invalidateAggregatesAfterWrite(c fiber.Ctx, schema, table string) {
+	if status := c.Response().StatusCode(); status >= 200 && status < 300 {
+		h.InvalidateAggregates(schema, table)
+	}
+}
+
+// aggregateMaxStale returns how stale a cached response to an aggregate request may be, or 0
+// when the response isn't cached: caching is disabled, the request has no aggregations or
+// group_by, it reads from a pagination snapshot, or it doesn't ask for "Prefer: max-stale"
+func (h *RESTHandler) aggregateMaxStale(c fiber.Ctx, params *QueryParams) (time.Duration, error) {
+	maxStale, err := parseMaxStalePreference(c.Get("Prefer"))
+	if err != nil || maxStale == 0 || !h.aggregates.enabled() {
+		return 0, err
+	}
+	if (len(params.Aggregations) == 0 && len(params.GroupBy) == 0) || wantsSnapshot(c) {
+		return 0, nil
+	}
+	return h.aggregates.clampStale(maxStale), nil
+}
+
+// sendCachedAggregate responds with a cached aggregate response and its age in seconds
+func (h *RESTHandler) sendCachedAggregate(c fiber.Ctx, entry *aggregateCacheEntry, maxStale time.Duration) error {
+	age := h.aggregates.now().Sub(entry.storedAt)
+	c.Set("Age", strconv.Itoa(int(age.Seconds())))
+	c.Set("Preference-Applied", fmt.Sprintf("max-stale=%d", int(maxStale.Seconds())))
+	if entry.contentRange != "" {
+		c.Set("Content-Range", entry.contentRange)
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(entry.body)
+}
+
+// storeAggregate caches an aggregate response and sends it as a fresh response
+func (h *RESTHandler) storeAggregate(c fiber.Ctx, table, key string, maxStale time.Duration, results []map[string]interface{}) error {
+	body, err := json.Marshal(results)
+	if err != nil {
+		return c.JSON(results)
+	}
+	entry := &aggregateCacheEntry{
+		body:         body,
+		contentRange: c.GetRespHeader("Content-Range"),
+		storedAt:     h.aggregates.now(),
+	}
+	h.aggregates.put(table, key, entry)
+	return h.sendCachedAggregate(c, entry, maxStale)
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMaxStalePreference(t *testing.T) {
+	tests := []struct {
+		prefer  string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"count=exact", 0, false},
+		{"max-stale=60", time.Minute, false},
+		{"count=exact, max-stale=5", 5 * time.Second, false},
+		{"max-stale", 0, true},
+		{"max-stale=0", 0, true},
+		{"max-stale=soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefer, func(t *testing.T) {
+			got, err := parseMaxStalePreference(tt.prefer)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAggregateCache_GetPut(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := newAggregateCache(10, 5*time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.put("public.orders", "k1", &aggregateCacheEntry{body: []byte(`[{"count":3}]`), storedAt: now})
+
+	entry, ok := cache.get("public.orders", "k1", time.Minute)
+	require.True(t, ok)
+	assert.Equal(t, `[{"count":3}]`, string(entry.body))
+
+	_, ok = cache.get("public.orders", "other", time.Minute)
+	assert.False(t, ok)
+
+	// An entry older than the client's max-stale is not served
+	now = now.Add(2 * time.Minute)
+	_, ok = cache.get("public.orders", "k1", time.Minute)
+	assert.False(t, ok)
+	_, ok = cache.get("public.orders", "k1", 3*time.Minute)
+	assert.True(t, ok)
+}
+
+func TestAggregateCache_Invalidate(t *testing.T) {
+	cache := newAggregateCache(10, time.Minute)
+	cache.put("public.orders", "k1", &aggregateCacheEntry{storedAt: cache.now()})
+	cache.put("public.orders", "k2", &aggregateCacheEntry{storedAt: cache.now()})
+	cache.put("public.users", "k1", &aggregateCacheEntry{storedAt: cache.now()})
+
+	cache.invalidate("public.orders")
+
+	_, ok := cache.get("public.orders", "k1", time.Minute)
+	assert.False(t, ok)
+	_, ok = cache.get("public.users", "k1", time.Minute)
+	assert.True(t, ok)
+	assert.Equal(t, 1, cache.size)
+}
+
+func TestAggregateCache_EvictsOldest(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := newAggregateCache(2, time.Hour)
+	cache.now = func() time.Time { return now }
+
+	cache.put("public.orders", "old", &aggregateCacheEntry{storedAt: now.Add(-time.Minute)})
+	cache.put("public.orders", "new", &aggregateCacheEntry{storedAt: now})
+	cache.put("public.users", "newest", &aggregateCacheEntry{storedAt: now})
+
+	assert.Equal(t, 2, cache.size)
+	_, ok := cache.get("public.orders", "old", time.Hour)
+	assert.False(t, ok)
+	_, ok = cache.get("public.orders", "new", time.Hour)
+	assert.True(t, ok)
+	_, ok = cache.get("public.users", "newest", time.Hour)
+	assert.True(t, ok)
+}
+
+func TestAggregateCacheKey_IncludesRLSContext(t *testing.T) {
+	keyFor := func(userID string) string {
+		app := fiber.New()
+		var key string
+		app.Get("/", func(c fiber.Ctx) error {
+			c.Locals("rls_role", "authenticated")
+			c.Locals("rls_user_id", userID)
+			var err error
+			key, err = aggregateCacheKey(c, "SELECT count(*) FROM orders", []interface{}{int64(1)}, CountNone)
+			return err
+		})
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return key
+	}
+
+	alice := keyFor("alice")
+	assert.NotEmpty(t, alice)
+	assert.Equal(t, alice, keyFor("alice"))
+	assert.NotEqual(t, alice, keyFor("bob"))
+}
+
+func TestRESTHandler_AggregateMaxStale(t *testing.T) {
+	handler := &RESTHandler{aggregates: newAggregateCache(10, time.Minute)}
+	aggregate := &QueryParams{GroupBy: []string{"status"}}
+
+	tests := []struct {
+		name    string
+		handler *RESTHandler
+		params  *QueryParams
+		headers map[string]string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"no preference", handler, aggregate, nil, 0, false},
+		{"aggregate", handler, aggregate, map[string]string{"Prefer": "max-stale=30"}, 30 * time.Second, false},
+		{"clamped to configured maximum", handler, aggregate, map[string]string{"Prefer": "max-stale=3600"}, time.Minute, false},
+		{"not an aggregate", handler, &QueryParams{}, map[string]string{"Prefer": "max-stale=30"}, 0, false},
+		{"snapshot", handler, aggregate, map[string]string{"Prefer": "max-stale=30, snapshot"}, 0, false},
+		{"disabled", &RESTHandler{aggregates: newAggregateCache(0, time.Minute)}, aggregate, map[string]string{"Prefer": "max-stale=30"}, 0, false},
+		{"invalid", handler, aggregate, map[string]string{"Prefer": "max-stale=-1"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			var got time.Duration
+			var gotErr error
+			app.Get("/", func(c fiber.Ctx) error {
+				got, gotErr = tt.handler.aggregateMaxStale(c, tt.params)
+				return nil
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+
+			if tt.wantErr {
+				assert.Error(t, gotErr)
+				return
+			}
+			require.NoError(t, gotErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRESTHandler_SendCachedAggregate(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	handler := &RESTHandler{aggregates: newAggregateCache(10, time.Minute)}
+	handler.aggregates.now = func() time.Time { return now }
+
+	app := fiber.New()
+	app.Get("/", func(c fiber.Ctx) error {
+		entry := &aggregateCacheEntry{body: []byte(`[{"status":"paid","count":2}]`), contentRange: "0-0/1", storedAt: now.Add(-42 * time.Second)}
+		return handler.sendCachedAggregate(c, entry, time.Minute)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, "42", resp.Header.Get("Age"))
+	assert.Equal(t, "max-stale=60", resp.Header.Get("Preference-Applied"))
+	assert.Equal(t, "0-0/1", resp.Header.Get("Content-Range"))
+	assert.Contains(t, resp.Header.Get("Content-Type"), "application/json")
+}
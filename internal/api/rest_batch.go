@@ -17,16 +17,12 @@ import (
 // batchInsert handles batch insert operations
 func (h *RESTHandler) batchInsert(ctx context.Context, c fiber.Ctx, table database.TableInfo, dataArray []map[string]interface{}, isUpsert bool, ignoreDuplicates bool, defaultToNull bool, onConflict string) error {
 	if len(dataArray) == 0 {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Empty array provided",
-		})
+		return SendBadRequest(c, "Empty array provided", ErrCodeInvalidInput)
 	}
 
 	// Validate batch size limit (H-4)
 	if h.config.API.MaxBatchSize > 0 && len(dataArray) > h.config.API.MaxBatchSize {
-		return c.Status(400).JSON(fiber.Map{
-			"error": fmt.Sprintf("Batch size %d exceeds maximum allowed batch size of %d", len(dataArray), h.config.API.MaxBatchSize),
-		})
+		return SendBadRequest(c, fmt.Sprintf("Batch size %d exceeds maximum allowed batch size of %d", len(dataArray), h.config.API.MaxBatchSize), ErrCodeInvalidInput)
 	}
 
 	// Get all unique columns from the first record
@@ -34,10 +30,8 @@ func (h *RESTHandler) batchInsert(ctx context.Context, c fiber.Ctx, table databa
 	columns := make([]string, 0, len(firstRecord))     // Quoted column names for SQL
 	columnNames := make([]string, 0, len(firstRecord)) // Unquoted column names for conflict checking
 	for col := range firstRecord {
-		if !h.columnExists(table, col) {
-			return c.Status(400).JSON(fiber.Map{
-				"error": fmt.Sprintf("Unknown column: %s", col),
-			})
+		if msg := h.columnWriteError(table, col); msg != "" {
+			return SendBadRequest(c, msg, ErrCodeInvalidInput)
 		}
 		columns = append(columns, quoteIdentifier(col))
 		columnNames = append(columnNames, col)
@@ -61,9 +55,7 @@ func (h *RESTHandler) batchInsert(ctx context.Context, c fiber.Ctx, table databa
 				// Convert GeoJSON to JSON string and use ST_GeomFromGeoJSON
 				geoJSON, err := json.Marshal(val)
 				if err != nil {
-					return c.Status(400).JSON(fiber.Map{
-						"error": fmt.Sprintf("Invalid GeoJSON for column %s: %v", col, err),
-					})
+					return SendBadRequest(c, fmt.Sprintf("Invalid GeoJSON for column %s: %v", col, err), ErrCodeInvalidInput)
 				}
 				values = append(values, string(geoJSON))
 				placeholders[i] = fmt.Sprintf("ST_GeomFromGeoJSON($%d)", argCounter)
@@ -85,99 +77,11 @@ func (h *RESTHandler) batchInsert(ctx context.Context, c fiber.Ctx, table databa
 
 	// Add ON CONFLICT clause for upsert
 	if isUpsert {
-		// Use custom conflict target if provided, otherwise auto-detect
-		var conflictTarget string
-		var conflictTargetColumns []string
-
-		if onConflict != "" {
-			// Validate and quote custom conflict target columns
-			conflictCols := strings.Split(onConflict, ",")
-			quotedConflictCols := make([]string, 0, len(conflictCols))
-			for _, col := range conflictCols {
-				col = strings.TrimSpace(col)
-				if !h.columnExists(table, col) {
-					return c.Status(400).JSON(fiber.Map{
-						"error": fmt.Sprintf("Unknown column in on_conflict: %s", col),
-					})
-				}
-				quotedConflictCols = append(quotedConflictCols, quoteIdentifier(col))
-				conflictTargetColumns = append(conflictTargetColumns, col)
-			}
-			conflictTarget = strings.Join(quotedConflictCols, ", ")
-		} else {
-			conflictTarget = h.getConflictTarget(table)
-			conflictTargetColumns = h.getConflictTargetUnquoted(table)
-		}
-
-		if conflictTarget == "" {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "Cannot perform upsert: table has no primary key or unique constraint",
-			})
-		}
-
-		// Handle ignore duplicates (DO NOTHING)
-		if ignoreDuplicates {
-			query += fmt.Sprintf(
-				" ON CONFLICT (%s) DO NOTHING",
-				conflictTarget,
-			)
-		} else {
-			// Build UPDATE SET clause (all columns except conflict target)
-			updateClauses := make([]string, 0)
-
-			// If defaultToNull is true, we need to update ALL columns in the table, not just the ones provided
-			if defaultToNull {
-				// Get all columns from table and set them either to EXCLUDED.column or NULL
-				for _, tableCol := range table.Columns {
-					colName := tableCol.Name
-					// Skip columns that are part of the conflict target
-					if h.isInConflictTarget(colName, conflictTargetColumns) {
-						continue
-					}
-
-					quotedColName := quoteIdentifier(colName)
-
-					// Check if column was provided in the data
-					columnProvided := false
-					for _, providedCol := range columnNames {
-						if providedCol == colName {
-							columnProvided = true
-							break
-						}
-					}
-
-					if columnProvided {
-						// Use the provided value
-						updateClauses = append(updateClauses, fmt.Sprintf("%s = EXCLUDED.%s", quotedColName, quotedColName))
-					} else {
-						// Set to NULL (missing column)
-						updateClauses = append(updateClauses, fmt.Sprintf("%s = NULL", quotedColName))
-					}
-				}
-			} else {
-				// Only update columns that were provided
-				for i, col := range columns {
-					// Skip columns that are part of the conflict target (use unquoted name for comparison)
-					if !h.isInConflictTarget(columnNames[i], conflictTargetColumns) {
-						// col is already quoted
-						updateClauses = append(updateClauses, fmt.Sprintf("%s = EXCLUDED.%s", col, col))
-					}
-				}
-			}
-
-			if len(updateClauses) > 0 {
-				query += fmt.Sprintf(
-					" ON CONFLICT (%s) DO UPDATE SET %s",
-					conflictTarget,
-					strings.Join(updateClauses, ", "),
-				)
-			} else {
-				query += fmt.Sprintf(
-					" ON CONFLICT (%s) DO NOTHING",
-					conflictTarget,
-				)
-			}
+		clause, _, err := h.buildOnConflictClause(table, onConflict, columnNames, ignoreDuplicates, defaultToNull)
+		if err != nil {
+			return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 		}
+		query += clause
 	}
 
 	query += buildReturningClause(table)
@@ -223,65 +127,41 @@ func (h *RESTHandler) makeBatchPatchHandler(table database.TableInfo) fiber.Hand
 	return func(c fiber.Ctx) error {
 		ctx := c.RequestCtx()
 
+		// Patch documents are applied to one row at a time
+		if mediaType := patchMediaType(c.Get(fiber.HeaderContentType)); mediaType != "" {
+			return SendErrorWithCode(c, fiber.StatusUnsupportedMediaType, fmt.Sprintf("%s is only supported when patching a single record by id", mediaType), ErrCodeUnsupportedMediaType)
+		}
+
 		// Parse request body
 		var data map[string]interface{}
 		if err := c.Bind().Body(&data); err != nil {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "Invalid request body",
-			})
+			return SendInvalidBody(c)
 		}
 
 		if len(data) == 0 {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "No fields to update",
-			})
+			return SendBadRequest(c, "No fields to update", ErrCodeInvalidInput)
 		}
 
 		// Parse raw query string to preserve multiple values for the same key
 		rawQuery := string(c.Request().URI().QueryString())
 		urlValues, err := url.ParseQuery(rawQuery)
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{
-				"error": fmt.Sprintf("Invalid query string: %v", err),
-			})
+			return SendBadRequest(c, fmt.Sprintf("Invalid query string: %v", err), ErrCodeInvalidInput)
 		}
 
 		params, err := h.parser.Parse(urlValues)
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{
-				"error": fmt.Sprintf("Invalid query parameters: %v", err),
-			})
+			return SendBadRequest(c, fmt.Sprintf("Invalid query parameters: %v", err), ErrCodeInvalidInput)
+		}
+		if err := coerceFilterValues(&table, params.Filters); err != nil {
+			return respondFilterTypeError(c, err)
 		}
 
 		// Build SET clause
-		setClauses := make([]string, 0, len(data))
-		values := make([]interface{}, 0, len(data))
 		argCounter := 1
-
-		for col, val := range data {
-			if !h.columnExists(table, col) {
-				return c.Status(400).JSON(fiber.Map{
-					"error": fmt.Sprintf("Unknown column: %s", col),
-				})
-			}
-
-			quotedCol := quoteIdentifier(col)
-			// Check if value is GeoJSON and needs PostGIS conversion
-			if isGeoJSON(val) {
-				// Convert GeoJSON to JSON string and use ST_GeomFromGeoJSON
-				geoJSON, err := json.Marshal(val)
-				if err != nil {
-					return c.Status(400).JSON(fiber.Map{
-						"error": fmt.Sprintf("Invalid GeoJSON for column %s: %v", col, err),
-					})
-				}
-				setClauses = append(setClauses, fmt.Sprintf("%s = ST_GeomFromGeoJSON($%d)", quotedCol, argCounter))
-				values = append(values, string(geoJSON))
-			} else {
-				setClauses = append(setClauses, fmt.Sprintf("%s = $%d", quotedCol, argCounter))
-				values = append(values, val)
-			}
-			argCounter++
+		setClauses, values, err := h.buildSetClauses(table, data, &argCounter)
+		if err != nil {
+			return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 		}
 
 		// Build WHERE clause from filters
@@ -338,6 +218,36 @@ func (h *RESTHandler) makeBatchPatchHandler(table database.TableInfo) fiber.Hand
 	}
 }
 
+// buildSetClauses builds the SET assignments of an update from column values, converting
+// GeoJSON values with ST_GeomFromGeoJSON
+func (h *RESTHandler) buildSetClauses(table database.TableInfo, data map[string]interface{}, argCounter *int) ([]string, []interface{}, error) {
+	setClauses := make([]string, 0, len(data))
+	values := make([]interface{}, 0, len(data))
+
+	for col, val := range data {
+		if msg := h.columnWriteError(table, col); msg != "" {
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest, msg)
+		}
+
+		quotedCol := quoteIdentifier(col)
+		// Check if value is GeoJSON and needs PostGIS conversion
+		if isGeoJSON(val) {
+			// Convert GeoJSON to JSON string and use ST_GeomFromGeoJSON
+			geoJSON, err := json.Marshal(val)
+			if err != nil {
+				return nil, nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Invalid GeoJSON for column %s: %v", col, err))
+			}
+			setClauses = append(setClauses, fmt.Sprintf("%s = ST_GeomFromGeoJSON($%d)", quotedCol, *argCounter))
+			values = append(values, string(geoJSON))
+		} else {
+			setClauses = append(setClauses, fmt.Sprintf("%s = $%d", quotedCol, *argCounter))
+			values = append(values, val)
+		}
+		*argCounter++
+	}
+	return setClauses, values, nil
+}
+
 // makeBatchDeleteHandler creates a DELETE handler for batch deletes with filters
 func (h *RESTHandler) makeBatchDeleteHandler(table database.TableInfo) fiber.Handler {
 	return func(c fiber.Ctx) error {
@@ -347,23 +257,20 @@ func (h *RESTHandler) makeBatchDeleteHandler(table database.TableInfo) fiber.Han
 		rawQuery := string(c.Request().URI().QueryString())
 		urlValues, err := url.ParseQuery(rawQuery)
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{
-				"error": fmt.Sprintf("Invalid query string: %v", err),
-			})
+			return SendBadRequest(c, fmt.Sprintf("Invalid query string: %v", err), ErrCodeInvalidInput)
 		}
 
 		params, err := h.parser.Parse(urlValues)
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{
-				"error": fmt.Sprintf("Invalid query parameters: %v", err),
-			})
+			return SendBadRequest(c, fmt.Sprintf("Invalid query parameters: %v", err), ErrCodeInvalidInput)
+		}
+		if err := coerceFilterValues(&table, params.Filters); err != nil {
+			return respondFilterTypeError(c, err)
 		}
 
 		// Require at least one filter for safety
 		if len(params.Filters) == 0 {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "Batch delete requires at least one filter. Use DELETE /:id for single deletes",
-			})
+			return SendBadRequest(c, "Batch delete requires at least one filter. Use DELETE /:id for single deletes", ErrCodeInvalidInput)
 		}
 
 		// Build WHERE clause from filters
@@ -547,6 +547,24 @@ func TestColumnExistsInBatch(t *testing.T) {
 	})
 }
 
+// TestColumnWriteError tests which columns batch and single-record writes accept
+func TestColumnWriteError(t *testing.T) {
+	handler := &RESTHandler{config: &config.Config{}}
+
+	table := database.TableInfo{
+		Schema: "public",
+		Name:   "items",
+		Columns: []database.ColumnInfo{
+			{Name: "price", DataType: "numeric"},
+			{Name: "price_with_tax", DataType: "numeric", IsGenerated: true},
+		},
+	}
+
+	assert.Empty(t, handler.columnWriteError(table, "price"))
+	assert.Equal(t, "Column price_with_tax is generated and cannot be written", handler.columnWriteError(table, "price_with_tax"))
+	assert.Equal(t, "Unknown column: tax", handler.columnWriteError(table, "tax"))
+}
+
 // TestConflictTargetBuilding tests various conflict target scenarios
 func TestConflictTargetBuilding(t *testing.T) {
 	handler := &RESTHandler{config: &config.Config{}}
@@ -0,0 +1,829 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/nimbleflux/fluxbase/internal/middleware"
+	"github.com/rs/zerolog/log"
+)
+
+// bulkStagingTable receives the rows of a bulk load. COPY FROM is not allowed on tables with
+// row-level security, so rows are copied into this temporary table as text and then inserted
+// into the table with INSERT ... SELECT, which applies policies, triggers and type checks.
+// The table is dropped with the transaction.
+const bulkStagingTable = "fluxbase_bulk"
+
+// bulkMaxValidationErrors caps the invalid values a dry run reports
+const bulkMaxValidationErrors = 100
+
+// bulkQueryParams are the query parameters of bulk loads, the others are rejected
+var bulkQueryParams = []string{"columns", "header", "delimiter", "null", "dry_run"}
+
+// bulkColumn maps a CSV header field or NDJSON key to a table column
+type bulkColumn struct {
+	Source string
+	Target string
+}
+
+// parseBulkColumns parses a column mapping: a comma-separated list of columns, each optionally
+// prefixed with the CSV header field or NDJSON key it is read from ("Email Address:email")
+func parseBulkColumns(value string) ([]bulkColumn, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var columns []bulkColumn
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(value, ",") {
+		source, target, mapped := strings.Cut(entry, ":")
+		source, target = strings.TrimSpace(source), strings.TrimSpace(target)
+		if !mapped {
+			target = source
+		}
+		if source == "" || target == "" {
+			return nil, fmt.Errorf("invalid column mapping %q, expected column or field:column", strings.TrimSpace(entry))
+		}
+		if seen[target] {
+			return nil, fmt.Errorf("column %q is mapped more than once", target)
+		}
+		seen[target] = true
+		columns = append(columns, bulkColumn{Source: source, Target: target})
+	}
+	return columns, nil
+}
+
+// bulkOptions are the options of a bulk load
+type bulkOptions struct {
+	Columns   []bulkColumn
+	Header    bool   // CSV: the first line names the fields (default true)
+	Delimiter rune   // CSV: field delimiter (default ',')
+	Null      string // CSV: fields equal to this text are NULL (default: empty)
+	DryRun    bool
+}
+
+// parseBulkOptions reads the options of a bulk load from its query string
+func parseBulkOptions(values url.Values) (*bulkOptions, error) {
+	for key := range values {
+		if !slices.Contains(bulkQueryParams, key) {
+			return nil, fmt.Errorf("unsupported parameter '%s' for bulk loads", key)
+		}
+	}
+
+	opts := &bulkOptions{Header: true, Delimiter: ',', Null: values.Get("null")}
+
+	var err error
+	if opts.Columns, err = parseBulkColumns(values.Get("columns")); err != nil {
+		return nil, err
+	}
+	if v := values.Get("header"); v != "" {
+		if opts.Header, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("invalid header parameter %q, expected true or false", v)
+		}
+	}
+	if v := values.Get("dry_run"); v != "" {
+		if opts.DryRun, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("invalid dry_run parameter %q, expected true or false", v)
+		}
+	}
+	if v := values.Get("delimiter"); v != "" {
+		r, size := utf8.DecodeRuneInString(v)
+		if size != len(v) || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+			return nil, fmt.Errorf("invalid delimiter %q, expected a single character", v)
+		}
+		opts.Delimiter = r
+	}
+	return opts, nil
+}
+
+// bulkRows reads the rows of a bulk load body as the text of each column, nil for NULL
+type bulkRows struct {
+	columns []string // Table columns, in the order of the values
+	read    func() ([]*string, error)
+	row     int // Rows read so far
+}
+
+// next returns the values of the next row, or io.EOF after the last one
+func (r *bulkRows) next() ([]*string, error) {
+	values, err := r.read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("row %d: %w", r.row+1, err)
+	}
+	r.row++
+	return values, nil
+}
+
+// newCSVBulkRows reads CSV rows. With a header, columns are the header fields unless a mapping
+// picks and renames them; without one, the mapping lists the columns in field order.
+func newCSVBulkRows(body io.Reader, opts *bulkOptions) (*bulkRows, error) {
+	reader := csv.NewReader(body)
+	reader.Comma = opts.Delimiter
+	reader.ReuseRecord = true
+
+	rows := &bulkRows{}
+	var indexes []int
+
+	if opts.Header {
+		header, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("the CSV body is empty")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV header: %w", err)
+		}
+		header = append([]string(nil), header...)
+		if len(header) > 0 {
+			header[0] = strings.TrimPrefix(header[0], "\ufeff")
+		}
+
+		if len(opts.Columns) == 0 {
+			for i, field := range header {
+				rows.columns = append(rows.columns, strings.TrimSpace(field))
+				indexes = append(indexes, i)
+			}
+		}
+		for _, col := range opts.Columns {
+			i := indexOfField(header, col.Source)
+			if i < 0 {
+				return nil, fmt.Errorf("field %q of the column mapping is not in the CSV header", col.Source)
+			}
+			rows.columns = append(rows.columns, col.Target)
+			indexes = append(indexes, i)
+		}
+	} else {
+		if len(opts.Columns) == 0 {
+			return nil, errors.New("the columns parameter is required for CSV without a header")
+		}
+		for i, col := range opts.Columns {
+			if col.Source != col.Target {
+				return nil, errors.New("column mappings need a CSV header, list the columns in field order instead")
+			}
+			rows.columns = append(rows.columns, col.Target)
+			indexes = append(indexes, i)
+		}
+		reader.FieldsPerRecord = len(opts.Columns)
+	}
+
+	rows.read = func() ([]*string, error) {
+		record, err := reader.Read()
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, parseErr.Err
+			}
+			return nil, err
+		}
+
+		values := make([]*string, len(indexes))
+		for i, index := range indexes {
+			if field := record[index]; field != opts.Null {
+				values[i] = &field
+			}
+		}
+		return values, nil
+	}
+	return rows, nil
+}
+
+// indexOfField returns the position of a header field, ignoring surrounding spaces
+func indexOfField(header []string, name string) int {
+	for i, field := range header {
+		if strings.TrimSpace(field) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// newNDJSONBulkRows reads newline-delimited JSON objects. Columns are the keys of the first
+// object unless a mapping picks and renames them; keys outside the mapping are ignored, and
+// missing keys are NULL. Values for json and jsonb columns are kept as JSON, strings are
+// unquoted and arrays become Postgres array literals.
+func newNDJSONBulkRows(body io.Reader, opts *bulkOptions, table *database.TableInfo) (*bulkRows, error) {
+	decoder := json.NewDecoder(body)
+	decode := func() (map[string]json.RawMessage, error) {
+		var object map[string]json.RawMessage
+		if err := decoder.Decode(&object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		if object == nil {
+			return nil, errors.New("expected a JSON object")
+		}
+		return object, nil
+	}
+
+	rows := &bulkRows{}
+	sources := make([]string, 0, len(opts.Columns))
+	for _, col := range opts.Columns {
+		rows.columns = append(rows.columns, col.Target)
+		sources = append(sources, col.Source)
+	}
+
+	// Without a mapping the first object names the columns
+	var first map[string]json.RawMessage
+	strict := len(opts.Columns) == 0
+	if strict {
+		var err error
+		if first, err = decode(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("the NDJSON body is empty")
+			}
+			return nil, fmt.Errorf("row 1: %w", err)
+		}
+		for key := range first {
+			rows.columns = append(rows.columns, key)
+		}
+		sort.Strings(rows.columns)
+		sources = rows.columns
+	}
+
+	jsonColumns := make([]bool, len(rows.columns))
+	for i, name := range rows.columns {
+		if col := table.GetColumn(name); col != nil {
+			jsonColumns[i] = col.DataType == "json" || col.DataType == "jsonb"
+		}
+	}
+
+	rows.read = func() ([]*string, error) {
+		object := first
+		first = nil
+		if object == nil {
+			var err error
+			if object, err = decode(); err != nil {
+				return nil, err
+			}
+		}
+
+		if strict {
+			for key := range object {
+				if !slices.Contains(sources, key) {
+					return nil, fmt.Errorf("field %q is not in the first row, list the columns to load with the columns parameter", key)
+				}
+			}
+		}
+
+		values := make([]*string, len(sources))
+		for i, source := range sources {
+			raw, ok := object[source]
+			if !ok {
+				continue
+			}
+			value, err := jsonValueText(raw, jsonColumns[i])
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %w", source, err)
+			}
+			values[i] = value
+		}
+		return values, nil
+	}
+	return rows, nil
+}
+
+// jsonValueText converts a JSON value to the text Postgres parses for a column, nil for null
+func jsonValueText(raw json.RawMessage, jsonColumn bool) (*string, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var text string
+	switch {
+	case jsonColumn:
+		text = string(raw)
+	case raw[0] == '"':
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return nil, err
+		}
+	case raw[0] == '[':
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		var elements []interface{}
+		if err := decoder.Decode(&elements); err != nil {
+			return nil, err
+		}
+		literal, err := pgArrayLiteral(elements)
+		if err != nil {
+			return nil, err
+		}
+		text = literal
+	default:
+		// Numbers, booleans and objects (GeoJSON for geometry columns) as written
+		text = string(raw)
+	}
+	return &text, nil
+}
+
+// pgArrayLiteral formats JSON array elements as a Postgres array literal
+func pgArrayLiteral(elements []interface{}) (string, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, element := range elements {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		switch v := element.(type) {
+		case nil:
+			b.WriteString("NULL")
+		case []interface{}:
+			nested, err := pgArrayLiteral(v)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(nested)
+		case string:
+			writeArrayElement(&b, v)
+		case json.Number:
+			b.WriteString(v.String())
+		case bool:
+			b.WriteString(strconv.FormatBool(v))
+		default:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return "", err
+			}
+			writeArrayElement(&b, string(encoded))
+		}
+	}
+	b.WriteByte('}')
+	return b.String(), nil
+}
+
+// writeArrayElement writes a quoted array element, escaping quotes and backslashes
+func writeArrayElement(b *strings.Builder, s string) {
+	b.WriteByte('"')
+	for _, r := range s {
+		if r == '"' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+}
+
+// bulkColumnType is a column of a bulk load with the type its text is cast to
+type bulkColumnType struct {
+	Name     string
+	Type     string // format_type without modifiers, so that assignment checks lengths
+	Geometry bool
+}
+
+// stagingColumn returns the name of the staging table column holding the i-th column
+func stagingColumn(i int) string {
+	return fmt.Sprintf("c%d", i+1)
+}
+
+// bulkCreateStagingSQL creates the staging table: the row number and one text column per column
+func bulkCreateStagingSQL(columns int) string {
+	defs := []string{"n bigint"}
+	for i := 0; i < columns; i++ {
+		defs = append(defs, stagingColumn(i)+" text")
+	}
+	return fmt.Sprintf("CREATE TEMP TABLE %s (%s) ON COMMIT DROP", bulkStagingTable, strings.Join(defs, ", "))
+}
+
+// bulkValueSQL casts a staging column to the type of its table column. Geometry columns
+// accept GeoJSON as well as WKT and hex EWKB.
+func bulkValueSQL(i int, col bulkColumnType) string {
+	staging := stagingColumn(i)
+	if col.Geometry {
+		return fmt.Sprintf("CASE WHEN ltrim(%[1]s) LIKE '{%%' THEN ST_GeomFromGeoJSON(%[1]s) ELSE %[1]s::%[2]s END", staging, col.Type)
+	}
+	return fmt.Sprintf("%s::%s", staging, col.Type)
+}
+
+// bulkInsertSQL inserts the staged rows into the table in the order they were sent
+func bulkInsertSQL(table database.TableInfo, columns []bulkColumnType) string {
+	names := make([]string, len(columns))
+	values := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = quoteIdentifier(col.Name)
+		values[i] = bulkValueSQL(i, col)
+	}
+	return fmt.Sprintf("INSERT INTO %s.%s (%s) SELECT %s FROM %s ORDER BY n",
+		quoteIdentifier(table.Schema), quoteIdentifier(table.Name),
+		strings.Join(names, ", "), strings.Join(values, ", "), bulkStagingTable)
+}
+
+// bulkValidateSQL finds staged values that cannot be read as the type of their column. GeoJSON
+// in geometry columns is left to the insert.
+func bulkValidateSQL(columns []bulkColumnType) (string, []interface{}) {
+	rows := make([]string, len(columns))
+	args := make([]interface{}, 0, 2*len(columns))
+	for i, col := range columns {
+		value := "s." + stagingColumn(i)
+		if col.Geometry {
+			value = fmt.Sprintf("CASE WHEN ltrim(%[1]s) LIKE '{%%' THEN NULL ELSE %[1]s END", value)
+		}
+		rows[i] = fmt.Sprintf("($%d::text, %s, $%d::text)", 2*i+1, value, 2*i+2)
+		args = append(args, col.Name, col.Type)
+	}
+	query := fmt.Sprintf(`SELECT s.n, v.col, v.value, v.type FROM %s s
+		CROSS JOIN LATERAL (VALUES %s) AS v(col, value, type)
+		WHERE v.value IS NOT NULL AND NOT pg_input_is_valid(v.value, v.type)
+		ORDER BY s.n LIMIT %d`, bulkStagingTable, strings.Join(rows, ", "), bulkMaxValidationErrors)
+	return query, args
+}
+
+// bulkColumnTypesQuery returns the types of the columns of a table, without modifiers
+const bulkColumnTypesQuery = `
+	SELECT a.attname, format_type(a.atttypid, NULL)
+	FROM pg_attribute a
+	WHERE a.attrelid = $1::regclass AND a.attnum > 0 AND NOT a.attisdropped AND a.attname = ANY($2)`
+
+// BulkValidationError is an invalid value, or a failed insert, found by a dry run
+type BulkValidationError struct {
+	Row        int64  `json:"row,omitempty"`
+	Column     string `json:"column,omitempty"`
+	Value      string `json:"value,omitempty"`
+	Message    string `json:"message"`
+	Detail     string `json:"detail,omitempty"`
+	Constraint string `json:"constraint,omitempty"`
+}
+
+// BulkLoadResponse is the result of a bulk load
+type BulkLoadResponse struct {
+	Inserted int64                 `json:"inserted"`
+	DryRun   bool                  `json:"dry_run,omitempty"`
+	Rows     int                   `json:"rows,omitempty"`
+	Valid    *bool                 `json:"valid,omitempty"`
+	Errors   []BulkValidationError `json:"errors,omitempty"`
+}
+
+// HandleTableBulk loads rows into a table with COPY (POST) or updates and deletes the rows
+// matching filters (PATCH, DELETE) in one statement, guarded by an affected-row cap
+// POST|PATCH|DELETE /api/v1/tables/:table/bulk
+func (h *RESTHandler) HandleTableBulk(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+	schema, tableName, accessErr := h.resolveTable(c)
+	if accessErr != nil {
+		return SendError(c, accessErr.status, accessErr.message)
+	}
+
+	tableInfo, exists, err := h.schemaCache.GetTable(ctx, schema, tableName)
+	if err != nil {
+		log.Error().Err(err).Str("schema", schema).Str("table", tableName).Msg("Failed to lookup table")
+		return SendErrorWithCode(c, 500, "Failed to lookup table metadata", ErrCodeOperationFailed)
+	}
+	if !exists {
+		return SendNotFound(c, fmt.Sprintf("Table '%s.%s' not found", schema, tableName))
+	}
+
+	isWritable, err := h.schemaCache.IsTableWritable(ctx, schema, tableName)
+	if err != nil {
+		return SendErrorWithCode(c, 500, "Failed to check table permissions", ErrCodeOperationFailed)
+	}
+	if reason := h.readOnlyReason(c, schema, tableName, isWritable); reason != "" {
+		return SendErrorWithCode(c, 405, reason, ErrCodeMethodNotAllowed)
+	}
+
+	// Drop cached aggregates of the table once a write succeeds
+	defer h.invalidateAggregatesAfterWrite(c, schema, tableName)
+
+	switch c.Method() {
+	case fiber.MethodPost:
+		return h.bulkLoad(c, *tableInfo)
+	case fiber.MethodPatch, fiber.MethodDelete:
+		return h.bulkModify(c, *tableInfo)
+	default:
+		return SendErrorWithCode(c, 405, fmt.Sprintf("Method %s not allowed", c.Method()), ErrCodeMethodNotAllowed)
+	}
+}
+
+// bulkLoad streams a CSV or NDJSON body into the staging table with COPY and inserts the rows
+// into the table. A dry run checks every value against its column type and runs the insert,
+// reporting what failed, and then rolls back.
+func (h *RESTHandler) bulkLoad(c fiber.Ctx, table database.TableInfo) error {
+	ctx := c.RequestCtx()
+
+	urlValues, err := url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return SendBadRequest(c, fmt.Sprintf("Invalid query string: %v", err), ErrCodeInvalidInput)
+	}
+	opts, err := parseBulkOptions(urlValues)
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	var body io.Reader = c.Request().BodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.Body())
+	}
+
+	var rows *bulkRows
+	switch mediaType := strings.ToLower(strings.TrimSpace(strings.Split(c.Get(fiber.HeaderContentType), ";")[0])); mediaType {
+	case "text/csv":
+		rows, err = newCSVBulkRows(body, opts)
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		rows, err = newNDJSONBulkRows(body, opts, &table)
+	default:
+		return SendErrorWithCode(c, fiber.StatusUnsupportedMediaType, "Bulk loads accept text/csv or application/x-ndjson bodies", ErrCodeUnsupportedMediaType)
+	}
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+	if len(rows.columns) == 0 {
+		return SendBadRequest(c, "No columns to load", ErrCodeInvalidInput)
+	}
+	for _, name := range rows.columns {
+		if msg := h.columnWriteError(table, name); msg != "" {
+			return SendBadRequest(c, msg, ErrCodeInvalidInput)
+		}
+	}
+
+	tx, err := middleware.BeginWithRLS(ctx, h.db, c)
+	if err != nil {
+		log.Error().Err(err).Str("table", table.Schema+"."+table.Name).Msg("Failed to start bulk load")
+		return SendErrorWithCode(c, 500, "Failed to start bulk load", ErrCodeOperationFailed)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	columns, err := bulkColumnTypes(ctx, tx, table, rows.columns)
+	if err != nil {
+		return handleDatabaseError(c, err, "load records")
+	}
+	if _, err := tx.Exec(ctx, bulkCreateStagingSQL(len(columns))); err != nil {
+		return handleDatabaseError(c, err, "load records")
+	}
+
+	stagingColumns := []string{"n"}
+	for i := range columns {
+		stagingColumns = append(stagingColumns, stagingColumn(i))
+	}
+	var readErr error
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{bulkStagingTable}, stagingColumns, pgx.CopyFromFunc(func() ([]any, error) {
+		values, err := rows.next()
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		if err != nil {
+			readErr = err
+			return nil, err
+		}
+		row := make([]any, 0, len(values)+1)
+		row = append(row, int64(rows.row))
+		for _, value := range values {
+			if value == nil {
+				row = append(row, nil)
+			} else {
+				row = append(row, *value)
+			}
+		}
+		return row, nil
+	}))
+	if readErr != nil {
+		return SendBadRequest(c, readErr.Error(), ErrCodeInvalidInput)
+	}
+	if err != nil {
+		return handleDatabaseError(c, err, "load records")
+	}
+	if rows.row == 0 {
+		return SendBadRequest(c, "The body contains no rows", ErrCodeInvalidInput)
+	}
+
+	if opts.DryRun {
+		return h.bulkDryRun(c, tx, table, columns, rows.row)
+	}
+
+	tag, err := tx.Exec(ctx, bulkInsertSQL(table, columns))
+	if err != nil {
+		log.Debug().Err(err).Str("table", table.Schema+"."+table.Name).Msg("Bulk load failed")
+		return handleDatabaseError(c, err, "load records")
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return handleDatabaseError(c, err, "load records")
+	}
+
+	c.Set("X-Affected-Count", strconv.FormatInt(tag.RowsAffected(), 10))
+	return c.Status(fiber.StatusCreated).JSON(BulkLoadResponse{Inserted: tag.RowsAffected()})
+}
+
+// bulkDryRun reports the staged values that don't fit their column type or, when all do, the
+// error of the insert. The caller rolls the transaction back.
+func (h *RESTHandler) bulkDryRun(c fiber.Ctx, tx pgx.Tx, table database.TableInfo, columns []bulkColumnType, staged int) error {
+	ctx := c.RequestCtx()
+	validationErrors := []BulkValidationError{}
+
+	query, args := bulkValidateSQL(columns)
+	invalid, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return handleDatabaseError(c, err, "validate records")
+	}
+	for invalid.Next() {
+		var e BulkValidationError
+		var typ string
+		if err := invalid.Scan(&e.Row, &e.Column, &e.Value, &typ); err != nil {
+			invalid.Close()
+			return handleDatabaseError(c, err, "validate records")
+		}
+		e.Message = fmt.Sprintf("invalid input for type %s", typ)
+		validationErrors = append(validationErrors, e)
+	}
+	invalid.Close()
+	if err := invalid.Err(); err != nil {
+		return handleDatabaseError(c, err, "validate records")
+	}
+
+	var inserted int64
+	if len(validationErrors) == 0 {
+		tag, err := tx.Exec(ctx, bulkInsertSQL(table, columns))
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr):
+			validationErrors = append(validationErrors, BulkValidationError{
+				Column:     pgErr.ColumnName,
+				Message:    pgErr.Message,
+				Detail:     pgErr.Detail,
+				Constraint: pgErr.ConstraintName,
+			})
+		case err != nil:
+			return handleDatabaseError(c, err, "validate records")
+		default:
+			inserted = tag.RowsAffected()
+		}
+	}
+
+	valid := len(validationErrors) == 0
+	return c.JSON(BulkLoadResponse{
+		Inserted: inserted,
+		DryRun:   true,
+		Rows:     staged,
+		Valid:    &valid,
+		Errors:   validationErrors,
+	})
+}
+
+// bulkColumnTypes looks up the types the loaded columns are cast to
+func bulkColumnTypes(ctx context.Context, tx pgx.Tx, table database.TableInfo, names []string) ([]bulkColumnType, error) {
+	rows, err := tx.Query(ctx, bulkColumnTypesQuery, quoteIdentifier(table.Schema)+"."+quoteIdentifier(table.Name), names)
+	if err != nil {
+		return nil, err
+	}
+	types, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) ([2]string, error) {
+		var nameType [2]string
+		err := row.Scan(&nameType[0], &nameType[1])
+		return nameType, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]string, len(types))
+	for _, t := range types {
+		byName[t[0]] = t[1]
+	}
+
+	columns := make([]bulkColumnType, len(names))
+	for i, name := range names {
+		typ, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("column %q of %s.%s not found", name, table.Schema, table.Name)
+		}
+		col := table.GetColumn(name)
+		columns[i] = bulkColumnType{Name: name, Type: typ, Geometry: col != nil && isGeometryColumn(col.DataType)}
+	}
+	return columns, nil
+}
+
+// parseMaxAffectedPreference extracts the most rows a client allows a bulk update or delete to
+// affect from a Prefer header, e.g. "Prefer: max-affected=500". It returns 0 when the preference
+// is absent.
+func parseMaxAffectedPreference(prefer string) (int64, error) {
+	for _, pref := range strings.Split(prefer, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pref), "=")
+		if strings.TrimSpace(key) != "max-affected" {
+			continue
+		}
+
+		limit, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil || limit <= 0 {
+			return 0, fmt.Errorf("invalid max-affected preference %q: must be a positive number of rows", value)
+		}
+		return limit, nil
+	}
+	return 0, nil
+}
+
+// bulkAffectedLimit returns the most rows a bulk update or delete may affect: the client's
+// max-affected preference, capped by api.bulk_max_affected_rows. 0 means no limit.
+func bulkAffectedLimit(prefer string, configured int) (int64, error) {
+	limit, err := parseMaxAffectedPreference(prefer)
+	if err != nil {
+		return 0, err
+	}
+	if configured > 0 && (limit == 0 || limit > int64(configured)) {
+		limit = int64(configured)
+	}
+	return limit, nil
+}
+
+// BulkModifyResponse is the result of a bulk update or delete
+type BulkModifyResponse struct {
+	Affected int64 `json:"affected"`
+	DryRun   bool  `json:"dry_run,omitempty"`
+}
+
+// bulkModify updates (PATCH) or deletes (DELETE) the rows matching the filters in a single
+// statement. The change is rolled back when it affects more rows than allowed, and always on a
+// dry run, which only reports how many rows would change.
+func (h *RESTHandler) bulkModify(c fiber.Ctx, table database.TableInfo) error {
+	ctx := c.RequestCtx()
+
+	urlValues, err := url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return SendBadRequest(c, fmt.Sprintf("Invalid query string: %v", err), ErrCodeInvalidInput)
+	}
+	dryRun := false
+	if v := urlValues.Get("dry_run"); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			return SendBadRequest(c, fmt.Sprintf("Invalid dry_run parameter %q, expected true or false", v), ErrCodeInvalidInput)
+		}
+	}
+	urlValues.Del("dry_run")
+
+	params, err := h.parser.Parse(urlValues)
+	if err != nil {
+		return SendBadRequest(c, fmt.Sprintf("Invalid query parameters: %v", err), ErrCodeInvalidInput)
+	}
+	if len(params.Filters) == 0 {
+		return SendBadRequest(c, "Bulk updates and deletes require at least one filter", ErrCodeInvalidInput)
+	}
+	if err := coerceFilterValues(&table, params.Filters); err != nil {
+		return respondFilterTypeError(c, err)
+	}
+
+	limit, err := bulkAffectedLimit(c.Get("Prefer"), h.config.API.BulkMaxAffectedRows)
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	tableRef := quoteIdentifier(table.Schema) + "." + quoteIdentifier(table.Name)
+	argCounter := 1
+	var statement string
+	var args []interface{}
+	if c.Method() == fiber.MethodPatch {
+		var data map[string]interface{}
+		if err := c.Bind().Body(&data); err != nil {
+			return SendInvalidBody(c)
+		}
+		if len(data) == 0 {
+			return SendBadRequest(c, "No fields to update", ErrCodeInvalidInput)
+		}
+		setClauses, setArgs, err := h.buildSetClauses(table, data, &argCounter)
+		if err != nil {
+			return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+		}
+		statement = fmt.Sprintf("UPDATE %s SET %s", tableRef, strings.Join(setClauses, ", "))
+		args = setArgs
+	} else {
+		statement = "DELETE FROM " + tableRef
+	}
+	whereSQL, whereArgs := params.buildWhereClause(&argCounter)
+	args = append(args, whereArgs...)
+	query := fmt.Sprintf("WITH affected AS (%s WHERE %s RETURNING 1) SELECT count(*) FROM affected", statement, whereSQL)
+
+	tx, err := middleware.BeginWithRLS(ctx, h.db, c)
+	if err != nil {
+		log.Error().Err(err).Str("table", table.Schema+"."+table.Name).Msg("Failed to start bulk operation")
+		return SendErrorWithCode(c, 500, "Failed to start bulk operation", ErrCodeOperationFailed)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var affected int64
+	if err := tx.QueryRow(ctx, query, args...).Scan(&affected); err != nil {
+		log.Debug().Err(err).Str("query", query).Msg("Bulk operation failed")
+		return handleDatabaseError(c, err, "modify records")
+	}
+	if limit > 0 && affected > limit {
+		return SendBadRequest(c, fmt.Sprintf("The operation would affect %d rows, more than the limit of %d; narrow the filters", affected, limit), ErrCodeInvalidInput)
+	}
+	if dryRun {
+		return c.JSON(BulkModifyResponse{Affected: affected, DryRun: true})
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return handleDatabaseError(c, err, "modify records")
+	}
+
+	c.Set("X-Affected-Count", strconv.FormatInt(affected, 10))
+	return c.JSON(BulkModifyResponse{Affected: affected})
+}
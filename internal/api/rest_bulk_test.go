@@ -0,0 +1,225 @@
+package api
+
+import (
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readBulkRows reads all rows, with NULL as "<null>"
+func readBulkRows(t *testing.T, rows *bulkRows) [][]string {
+	t.Helper()
+	var result [][]string
+	for {
+		values, err := rows.next()
+		if err == io.EOF {
+			return result
+		}
+		require.NoError(t, err)
+		row := make([]string, len(values))
+		for i, v := range values {
+			if v == nil {
+				row[i] = "<null>"
+			} else {
+				row[i] = *v
+			}
+		}
+		result = append(result, row)
+	}
+}
+
+func bulkTestTable() *database.TableInfo {
+	return &database.TableInfo{
+		Schema: "public",
+		Name:   "contacts",
+		Columns: []database.ColumnInfo{
+			{Name: "id", DataType: "integer"},
+			{Name: "email", DataType: "text"},
+			{Name: "tags", DataType: "ARRAY"},
+			{Name: "meta", DataType: "jsonb"},
+			{Name: "location", DataType: "geometry"},
+		},
+	}
+}
+
+func TestParseBulkOptions(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		opts, err := parseBulkOptions(url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, &bulkOptions{Header: true, Delimiter: ','}, opts)
+	})
+
+	t.Run("all options", func(t *testing.T) {
+		values, _ := url.ParseQuery("columns=Email Address:email,id&header=false&delimiter=%3B&null=NULL&dry_run=true")
+		opts, err := parseBulkOptions(values)
+		require.NoError(t, err)
+		assert.Equal(t, []bulkColumn{{Source: "Email Address", Target: "email"}, {Source: "id", Target: "id"}}, opts.Columns)
+		assert.False(t, opts.Header)
+		assert.Equal(t, ';', opts.Delimiter)
+		assert.Equal(t, "NULL", opts.Null)
+		assert.True(t, opts.DryRun)
+	})
+
+	for query, wantErr := range map[string]string{
+		"limit=10":           "unsupported parameter 'limit'",
+		"columns=a,,b":       "invalid column mapping",
+		"columns=a:x,b:x":    `column "x" is mapped more than once`,
+		"header=maybe":       "invalid header parameter",
+		"dry_run=yes please": "invalid dry_run parameter",
+		"delimiter=ab":       "invalid delimiter",
+	} {
+		t.Run(query, func(t *testing.T) {
+			values, _ := url.ParseQuery(query)
+			_, err := parseBulkOptions(values)
+			assert.ErrorContains(t, err, wantErr)
+		})
+	}
+}
+
+func TestCSVBulkRows(t *testing.T) {
+	t.Run("header names the columns", func(t *testing.T) {
+		rows, err := newCSVBulkRows(strings.NewReader("\ufeffid,email\n1,a@example.com\n2,\n"), &bulkOptions{Header: true, Delimiter: ','})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"id", "email"}, rows.columns)
+		assert.Equal(t, [][]string{{"1", "a@example.com"}, {"2", "<null>"}}, readBulkRows(t, rows))
+		assert.Equal(t, 2, rows.row)
+	})
+
+	t.Run("mapping picks and renames header fields", func(t *testing.T) {
+		opts := &bulkOptions{Header: true, Delimiter: ';', Null: `\N`, Columns: []bulkColumn{{Source: "Email Address", Target: "email"}}}
+		rows, err := newCSVBulkRows(strings.NewReader("Name;Email Address\nAda;ada@example.com\nBob;\\N\nEve;\n"), opts)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"email"}, rows.columns)
+		assert.Equal(t, [][]string{{"ada@example.com"}, {"<null>"}, {""}}, readBulkRows(t, rows))
+	})
+
+	t.Run("positional columns without header", func(t *testing.T) {
+		opts := &bulkOptions{Delimiter: ',', Columns: []bulkColumn{{Source: "id", Target: "id"}, {Source: "email", Target: "email"}}}
+		rows, err := newCSVBulkRows(strings.NewReader("1,a@example.com\n2,b@example.com,extra\n"), opts)
+		require.NoError(t, err)
+
+		_, err = rows.next()
+		require.NoError(t, err)
+		_, err = rows.next()
+		assert.ErrorContains(t, err, "row 2: wrong number of fields")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := newCSVBulkRows(strings.NewReader(""), &bulkOptions{Header: true, Delimiter: ','})
+		assert.ErrorContains(t, err, "the CSV body is empty")
+
+		_, err = newCSVBulkRows(strings.NewReader("id\n1\n"), &bulkOptions{Header: true, Delimiter: ',', Columns: []bulkColumn{{Source: "ID", Target: "id"}}})
+		assert.ErrorContains(t, err, `field "ID" of the column mapping is not in the CSV header`)
+
+		_, err = newCSVBulkRows(strings.NewReader("1\n"), &bulkOptions{Delimiter: ','})
+		assert.ErrorContains(t, err, "the columns parameter is required")
+
+		_, err = newCSVBulkRows(strings.NewReader("1\n"), &bulkOptions{Delimiter: ',', Columns: []bulkColumn{{Source: "ID", Target: "id"}}})
+		assert.ErrorContains(t, err, "column mappings need a CSV header")
+	})
+}
+
+func TestNDJSONBulkRows(t *testing.T) {
+	t.Run("first object names the columns", func(t *testing.T) {
+		body := `{"id": 1, "email": "a@example.com", "tags": ["x", "y \"z\""], "meta": {"vip": true}}
+{"id": 2, "meta": "plain"}
+
+{"id": 3, "email": null, "tags": [[1, 2], [null, 4]], "meta": null}
+`
+		rows, err := newNDJSONBulkRows(strings.NewReader(body), &bulkOptions{}, bulkTestTable())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"email", "id", "meta", "tags"}, rows.columns)
+		assert.Equal(t, [][]string{
+			{"a@example.com", "1", `{"vip": true}`, `{"x","y \"z\""}`},
+			{"<null>", "2", `"plain"`, "<null>"},
+			{"<null>", "3", "<null>", "{{1,2},{NULL,4}}"},
+		}, readBulkRows(t, rows))
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		rows, err := newNDJSONBulkRows(strings.NewReader("{\"id\": 1}\n{\"id\": 2, \"email\": \"b\"}\n"), &bulkOptions{}, bulkTestTable())
+		require.NoError(t, err)
+		_, err = rows.next()
+		require.NoError(t, err)
+		_, err = rows.next()
+		assert.ErrorContains(t, err, `row 2: field "email" is not in the first row`)
+	})
+
+	t.Run("mapping picks and renames keys", func(t *testing.T) {
+		opts := &bulkOptions{Columns: []bulkColumn{{Source: "mail", Target: "email"}, {Source: "where", Target: "location"}}}
+		body := `{"mail": "a@example.com", "ignored": 1, "where": {"type": "Point", "coordinates": [1, 2]}}`
+		rows, err := newNDJSONBulkRows(strings.NewReader(body), opts, bulkTestTable())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"email", "location"}, rows.columns)
+		assert.Equal(t, [][]string{{"a@example.com", `{"type": "Point", "coordinates": [1, 2]}`}}, readBulkRows(t, rows))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := newNDJSONBulkRows(strings.NewReader(""), &bulkOptions{}, bulkTestTable())
+		assert.ErrorContains(t, err, "the NDJSON body is empty")
+
+		_, err = newNDJSONBulkRows(strings.NewReader("[1, 2]"), &bulkOptions{}, bulkTestTable())
+		assert.ErrorContains(t, err, "row 1: invalid JSON")
+
+		rows, err := newNDJSONBulkRows(strings.NewReader(`{"id": 1} {"id": `), &bulkOptions{}, bulkTestTable())
+		require.NoError(t, err)
+		_, err = rows.next()
+		require.NoError(t, err)
+		_, err = rows.next()
+		assert.ErrorContains(t, err, "row 2: invalid JSON")
+	})
+}
+
+func TestBulkSQL(t *testing.T) {
+	table := *bulkTestTable()
+	columns := []bulkColumnType{
+		{Name: "id", Type: "integer"},
+		{Name: "location", Type: "geometry", Geometry: true},
+	}
+
+	assert.Equal(t, "CREATE TEMP TABLE fluxbase_bulk (n bigint, c1 text, c2 text) ON COMMIT DROP", bulkCreateStagingSQL(2))
+	assert.Equal(t,
+		`INSERT INTO "public"."contacts" ("id", "location") SELECT c1::integer, `+
+			`CASE WHEN ltrim(c2) LIKE '{%' THEN ST_GeomFromGeoJSON(c2) ELSE c2::geometry END FROM fluxbase_bulk ORDER BY n`,
+		bulkInsertSQL(table, columns))
+
+	query, args := bulkValidateSQL(columns)
+	assert.Contains(t, query, "VALUES ($1::text, s.c1, $2::text), ($3::text, CASE WHEN ltrim(s.c2) LIKE '{%' THEN NULL ELSE s.c2 END, $4::text)")
+	assert.Contains(t, query, "NOT pg_input_is_valid(v.value, v.type)")
+	assert.Contains(t, query, "LIMIT 100")
+	assert.Equal(t, []interface{}{"id", "integer", "location", "geometry"}, args)
+}
+
+func TestBulkAffectedLimit(t *testing.T) {
+	tests := []struct {
+		prefer     string
+		configured int
+		want       int64
+		wantErr    bool
+	}{
+		{"", 10000, 10000, false},
+		{"", 0, 0, false},
+		{"max-affected=50", 10000, 50, false},
+		{"return=minimal, max-affected=50000", 10000, 10000, false},
+		{"max-affected=50000", 0, 50000, false},
+		{"max-affected=0", 10000, 0, true},
+		{"max-affected=many", 10000, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefer, func(t *testing.T) {
+			got, err := bulkAffectedLimit(tt.prefer, tt.configured)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
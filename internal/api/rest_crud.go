@@ -2,12 +2,14 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/auth"
 	"github.com/nimbleflux/fluxbase/internal/database"
 	"github.com/nimbleflux/fluxbase/internal/middleware"
 	"github.com/rs/zerolog/log"
@@ -30,27 +32,87 @@ func (h *RESTHandler) makeGetHandler(table database.TableInfo) fiber.Handler {
 		rawQuery := string(c.Request().URI().QueryString())
 		urlValues, err := url.ParseQuery(rawQuery)
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{
-				"error": fmt.Sprintf("Invalid query string: %v", err),
-			})
+			return SendBadRequest(c, fmt.Sprintf("Invalid query string: %v", err), ErrCodeInvalidInput)
 		}
 
 		// Parse query parameters
-		// Admin users bypass max_total_results to allow browsing all data
-		opts := ParseOptions{BypassMaxTotalResults: isAdminUser(c)}
+		// Admin users bypass max_total_results to allow browsing all data, and snapshot
+		// pagination lifts it so large exports can page through consistent data
+		opts := ParseOptions{BypassMaxTotalResults: isAdminUser(c) || wantsSnapshot(c)}
 		params, err := h.parser.ParseWithOptions(urlValues, opts)
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{
-				"error": fmt.Sprintf("Invalid query parameters: %v", err),
-			})
+			return SendBadRequest(c, fmt.Sprintf("Invalid query parameters: %v", err), ErrCodeInvalidInput)
+		}
+
+		// Bind filter values with their column's type
+		if err := coerceFilterValues(&table, params.Filters); err != nil {
+			return respondFilterTypeError(c, err)
+		}
+		if err := checkDistanceOrders(&table, params.Order); err != nil {
+			return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+		}
+
+		// Count filtered and ordered columns for the index advisor
+		h.patterns.Record(table.Schema, table.Name, params)
+
+		// Resolve embedded relations (select=id,posts(title)) against the foreign keys
+		var embeds []resolvedEmbed
+		if len(params.Embedded) > 0 {
+			if len(params.Aggregations) > 0 || len(params.GroupBy) > 0 {
+				return SendBadRequest(c, "Embedded relations cannot be combined with aggregations or group_by", ErrCodeInvalidInput)
+			}
+			embeds, err = h.resolveEmbeds(ctx, table, params.Embedded)
+			if errors.Is(err, errInvalidEmbed) {
+				return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+			}
+			if err != nil {
+				log.Error().Err(err).Str("table", fmt.Sprintf("%s.%s", table.Schema, table.Name)).Msg("Failed to resolve embedded relations")
+				return SendErrorWithCode(c, 500, "Failed to resolve embedded relations", ErrCodeOperationFailed)
+			}
+			if !canAccessHiddenSchemas(c) {
+				if hidden := h.hiddenEmbedRelation(embeds); hidden != "" {
+					return SendBadRequest(c, fmt.Sprintf("%s: no relationship found for %s", errInvalidEmbed, hidden), ErrCodeInvalidInput)
+				}
+			}
+			if restrictions, ok := c.Locals("client_key_restrictions").(auth.ClientKeyRestrictions); ok {
+				if denied := deniedEmbedTable(restrictions, embeds); denied != "" {
+					return SendForbidden(c, fmt.Sprintf("Client key is not allowed to read table '%s'", denied), ErrCodeAccessDenied)
+				}
+			}
 		}
 
 		// Build SELECT query using fresh metadata
-		query, args := h.buildSelectQuery(table, params)
+		query, args := h.buildSelectQuery(table, params, embeds)
+
+		// Return the query plan instead of results when requested (Prefer: explain=plan|analyze)
+		if handled, err := h.handleExplain(ctx, c, query, args); handled {
+			return err
+		}
+
+		// Serve aggregates from the cache when the client accepts stale results (Prefer: max-stale=<seconds>)
+		maxStale, err := h.aggregateMaxStale(c, params)
+		if err != nil {
+			return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+		}
+		var cacheKey string
+		cacheTable := table.Schema + "." + table.Name
+		if maxStale > 0 {
+			if cacheKey, err = aggregateCacheKey(c, query, args, params.Count); err != nil {
+				cacheKey = ""
+			} else if entry, ok := h.aggregates.get(cacheTable, cacheKey, maxStale); ok {
+				return h.sendCachedAggregate(c, entry, maxStale)
+			}
+		}
+
+		// Open or continue a pagination snapshot (Prefer: snapshot / X-Snapshot-Token)
+		snapshotID, snapshotErr := h.resolveSnapshot(ctx, c)
+		if snapshotErr != nil {
+			return SendError(c, snapshotErr.status, snapshotErr.message)
+		}
 
 		// Execute query with RLS context
 		var results []map[string]interface{}
-		err = middleware.WrapWithRLS(ctx, h.db, c, func(tx pgx.Tx) error {
+		err = h.wrapCachedRead(ctx, c, snapshotID, query, args, func(tx pgx.Tx, args []interface{}) error {
 			log.Debug().Str("query", query).Interface("args", args).Msg("Executing SELECT query")
 			rows, err := tx.Query(ctx, query, args...)
 			if err != nil {
@@ -64,16 +126,17 @@ func (h *RESTHandler) makeGetHandler(table database.TableInfo) fiber.Handler {
 			log.Debug().Int("count", len(results)).Msg("Query results")
 			return err
 		})
+		if errors.Is(err, middleware.ErrSnapshotUnavailable) {
+			return SendErrorWithCode(c, fiber.StatusGone, errSnapshotExpired.Error(), ErrCodeGone)
+		}
 		if err != nil {
 			log.Error().Err(err).Str("table", fmt.Sprintf("%s.%s", table.Schema, table.Name)).Msg("Failed to fetch records")
-			return c.Status(500).JSON(fiber.Map{
-				"error": "Failed to fetch records",
-			})
+			return SendErrorWithCode(c, 500, "Failed to fetch records", ErrCodeOperationFailed)
 		}
 
 		// Handle count if requested
 		if params.Count != CountNone && params.Count != "" {
-			count, err := h.getCount(ctx, c, table, params)
+			count, err := h.getCount(ctx, c, table, params, snapshotID)
 			if err != nil {
 				log.Warn().Err(err).Msg("Failed to get count")
 			} else {
@@ -81,6 +144,9 @@ func (h *RESTHandler) makeGetHandler(table database.TableInfo) fiber.Handler {
 			}
 		}
 
+		if cacheKey != "" {
+			return h.storeAggregate(c, cacheTable, cacheKey, maxStale, results)
+		}
 		return c.JSON(results)
 	}
 }
@@ -103,10 +169,10 @@ func (h *RESTHandler) makeGetByIdHandler(table database.TableInfo) fiber.Handler
 			table.Schema, table.Name, pkColumn,
 		)
 
-		// Execute query with RLS context
+		// Execute query with RLS context, on a read replica when there is one
 		var results []map[string]interface{}
-		err := middleware.WrapWithRLS(ctx, h.db, c, func(tx pgx.Tx) error {
-			rows, err := tx.Query(ctx, query, id)
+		err := h.wrapCachedRead(ctx, c, "", query, []interface{}{id}, func(tx pgx.Tx, args []interface{}) error {
+			rows, err := tx.Query(ctx, query, args...)
 			if err != nil {
 				return err
 			}
@@ -117,15 +183,11 @@ func (h *RESTHandler) makeGetByIdHandler(table database.TableInfo) fiber.Handler
 			return err
 		})
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"error": "Failed to fetch record",
-			})
+			return SendErrorWithCode(c, 500, "Failed to fetch record", ErrCodeOperationFailed)
 		}
 
 		if len(results) == 0 {
-			return c.Status(404).JSON(fiber.Map{
-				"error": "Record not found",
-			})
+			return SendNotFound(c, "Record not found")
 		}
 
 		return c.JSON(results[0])
@@ -156,9 +218,7 @@ func (h *RESTHandler) makePostHandler(table database.TableInfo) fiber.Handler {
 		// Otherwise parse as single object
 		var data map[string]interface{}
 		if err := c.Bind().Body(&data); err != nil {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "Invalid request body",
-			})
+			return SendInvalidBody(c)
 		}
 
 		// Build INSERT query
@@ -169,11 +229,9 @@ func (h *RESTHandler) makePostHandler(table database.TableInfo) fiber.Handler {
 
 		i := 1
 		for col, val := range data {
-			// Validate column exists
-			if !h.columnExists(table, col) {
-				return c.Status(400).JSON(fiber.Map{
-					"error": fmt.Sprintf("Unknown column: %s", col),
-				})
+			// Validate column exists and is writable
+			if msg := h.columnWriteError(table, col); msg != "" {
+				return SendBadRequest(c, msg, ErrCodeInvalidInput)
 			}
 
 			columns = append(columns, quoteIdentifier(col))
@@ -185,17 +243,13 @@ func (h *RESTHandler) makePostHandler(table database.TableInfo) fiber.Handler {
 				// Convert GeoJSON to JSON string and use ST_GeomFromGeoJSON
 				geoJSON, err := json.Marshal(val)
 				if err != nil {
-					return c.Status(400).JSON(fiber.Map{
-						"error": fmt.Sprintf("Invalid GeoJSON for column %s: %v", col, err),
-					})
+					return SendBadRequest(c, fmt.Sprintf("Invalid GeoJSON for column %s: %v", col, err), ErrCodeInvalidInput)
 				}
 				values = append(values, string(geoJSON))
 				placeholders = append(placeholders, fmt.Sprintf("ST_GeomFromGeoJSON($%d)", i))
 			} else if isPartialGeoJSON(val) {
 				// Value looks like GeoJSON but is incomplete - return validation error
-				return c.Status(400).JSON(fiber.Map{
-					"error": fmt.Sprintf("Invalid GeoJSON for column %s: missing required 'coordinates' field", col),
-				})
+				return SendBadRequest(c, fmt.Sprintf("Invalid GeoJSON for column %s: missing required 'coordinates' field", col), ErrCodeInvalidInput)
 			} else {
 				values = append(values, val)
 				placeholders = append(placeholders, fmt.Sprintf("$%d", i))
@@ -211,67 +265,14 @@ func (h *RESTHandler) makePostHandler(table database.TableInfo) fiber.Handler {
 		)
 
 		// Add ON CONFLICT clause for upsert
+		skipsDuplicates := false
 		if isUpsert {
-			// Use custom conflict target if provided, otherwise auto-detect
-			var conflictTarget string
-			var conflictTargetColumns []string
-
-			if onConflict != "" {
-				// Validate and quote custom conflict target columns
-				conflictCols := strings.Split(onConflict, ",")
-				quotedConflictCols := make([]string, 0, len(conflictCols))
-				for _, col := range conflictCols {
-					col = strings.TrimSpace(col)
-					if !h.columnExists(table, col) {
-						return c.Status(400).JSON(fiber.Map{
-							"error": fmt.Sprintf("Unknown column in on_conflict: %s", col),
-						})
-					}
-					quotedConflictCols = append(quotedConflictCols, quoteIdentifier(col))
-					conflictTargetColumns = append(conflictTargetColumns, col)
-				}
-				conflictTarget = strings.Join(quotedConflictCols, ", ")
-			} else {
-				conflictTarget = h.getConflictTarget(table)
-				conflictTargetColumns = h.getConflictTargetUnquoted(table)
-			}
-
-			if conflictTarget == "" {
-				return c.Status(400).JSON(fiber.Map{
-					"error": "Cannot perform upsert: table has no primary key or unique constraint",
-				})
-			}
-
-			// Handle ignore duplicates (DO NOTHING)
-			if ignoreDuplicates {
-				query += fmt.Sprintf(
-					" ON CONFLICT (%s) DO NOTHING",
-					conflictTarget,
-				)
-			} else {
-				// Build UPDATE SET clause (all columns except conflict target)
-				updateClauses := make([]string, 0)
-				for i, col := range columns {
-					// Skip columns that are part of the conflict target (use unquoted name for comparison)
-					if !h.isInConflictTarget(columnNames[i], conflictTargetColumns) {
-						// col is already quoted
-						updateClauses = append(updateClauses, fmt.Sprintf("%s = EXCLUDED.%s", col, col))
-					}
-				}
-
-				if len(updateClauses) > 0 {
-					query += fmt.Sprintf(
-						" ON CONFLICT (%s) DO UPDATE SET %s",
-						conflictTarget,
-						strings.Join(updateClauses, ", "),
-					)
-				} else {
-					query += fmt.Sprintf(
-						" ON CONFLICT (%s) DO NOTHING",
-						conflictTarget,
-					)
-				}
+			clause, doNothing, err := h.buildOnConflictClause(table, onConflict, columnNames, ignoreDuplicates, defaultToNull)
+			if err != nil {
+				return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 			}
+			query += clause
+			skipsDuplicates = doNothing
 		}
 
 		query += buildReturningClause(table)
@@ -294,6 +295,10 @@ func (h *RESTHandler) makePostHandler(table database.TableInfo) fiber.Handler {
 		}
 
 		if len(results) == 0 {
+			// ON CONFLICT DO NOTHING returns no row when the record already exists
+			if skipsDuplicates {
+				return c.SendStatus(fiber.StatusNoContent)
+			}
 			// INSERT with RETURNING 0 rows typically indicates RLS policy blocked the operation
 			return h.handleRLSViolation(c, "INSERT", fmt.Sprintf("%s.%s", table.Schema, table.Name))
 		}
@@ -311,9 +316,7 @@ func (h *RESTHandler) makePutHandler(table database.TableInfo) fiber.Handler {
 		// Parse request body
 		var data map[string]interface{}
 		if err := c.Bind().Body(&data); err != nil {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "Invalid request body",
-			})
+			return SendInvalidBody(c)
 		}
 
 		// Determine primary key column
@@ -333,11 +336,9 @@ func (h *RESTHandler) makePutHandler(table database.TableInfo) fiber.Handler {
 				continue
 			}
 
-			// Validate column exists
-			if !h.columnExists(table, col) {
-				return c.Status(400).JSON(fiber.Map{
-					"error": fmt.Sprintf("Unknown column: %s", col),
-				})
+			// Validate column exists and is writable
+			if msg := h.columnWriteError(table, col); msg != "" {
+				return SendBadRequest(c, msg, ErrCodeInvalidInput)
 			}
 
 			// Check if value is GeoJSON and needs PostGIS conversion
@@ -346,9 +347,7 @@ func (h *RESTHandler) makePutHandler(table database.TableInfo) fiber.Handler {
 				// Convert GeoJSON to JSON string and use ST_GeomFromGeoJSON
 				geoJSON, err := json.Marshal(val)
 				if err != nil {
-					return c.Status(400).JSON(fiber.Map{
-						"error": fmt.Sprintf("Invalid GeoJSON for column %s: %v", col, err),
-					})
+					return SendBadRequest(c, fmt.Sprintf("Invalid GeoJSON for column %s: %v", col, err), ErrCodeInvalidInput)
 				}
 				setClauses = append(setClauses, fmt.Sprintf("%s = ST_GeomFromGeoJSON($%d)", quotedCol, i))
 				values = append(values, string(geoJSON))
@@ -396,10 +395,17 @@ func (h *RESTHandler) makePutHandler(table database.TableInfo) fiber.Handler {
 	}
 }
 
-// makePatchHandler creates a PATCH handler for partial updates
+// makePatchHandler creates a PATCH handler for partial updates. A merge patch
+// (application/merge-patch+json) or JSON Patch (application/json-patch+json) body is applied
+// to the stored row; any other body updates the columns it contains, like PUT.
 func (h *RESTHandler) makePatchHandler(table database.TableInfo) fiber.Handler {
-	// PATCH is the same as PUT but allows partial updates
-	return h.makePutHandler(table)
+	put := h.makePutHandler(table)
+	return func(c fiber.Ctx) error {
+		if mediaType := patchMediaType(c.Get(fiber.HeaderContentType)); mediaType != "" {
+			return h.handleRowPatch(c, table, mediaType)
+		}
+		return put(c)
+	}
 }
 
 // makeDeleteHandler creates a DELETE handler for removing records
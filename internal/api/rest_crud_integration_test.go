@@ -6,6 +6,7 @@ package api_test
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"testing"
 
 	"github.com/google/uuid"
@@ -780,6 +781,79 @@ func TestRESTHandler_Selection_Integration(t *testing.T) {
 	assert.False(t, hasPhone, "Should NOT have phone (not selected)")
 }
 
+func TestRESTHandler_EmbeddedRelations_Integration(t *testing.T) {
+	tc := testutil.NewIntegrationTestContext(t)
+	defer tc.Close()
+	defer tc.CleanupTestData()
+
+	// Drop tables if they exist to ensure clean state
+	tc.ExecuteSQLAsSuperuser(`DROP TABLE IF EXISTS public.embed_books, public.embed_authors CASCADE`)
+
+	tc.ExecuteSQL(`
+		CREATE TABLE embed_authors (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			name TEXT NOT NULL
+		)
+	`)
+	tc.ExecuteSQL(`
+		CREATE TABLE embed_books (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			author_id UUID NOT NULL REFERENCES embed_authors(id),
+			title TEXT NOT NULL,
+			published BOOLEAN NOT NULL DEFAULT true
+		)
+	`)
+
+	// Refresh schema cache so REST API discovers the new tables and their foreign keys
+	refreshSchemaCache(tc)
+	grantTablePermissions(tc, "public", "embed_authors")
+	grantTablePermissions(tc, "public", "embed_books")
+
+	authorName := "Author " + uuid.New().String()[:8]
+	authors := tc.QuerySQL(`INSERT INTO embed_authors (name) VALUES ($1) RETURNING id`, authorName)
+	require.Len(t, authors, 1)
+	authorID := authors[0]["id"]
+	tc.ExecuteSQL(`INSERT INTO embed_books (author_id, title, published) VALUES ($1, 'A', true), ($1, 'B', true), ($1, 'Draft', false)`, authorID)
+
+	_, token := tc.CreateTestUser(randomEmail(), "password123")
+
+	// One-to-many: books of an author, filtered and ordered
+	resp := tc.NewRequest("GET", "/api/v1/tables/public/embed_authors?select=name,embed_books(title)&name=eq."+url.QueryEscape(authorName)+
+		"&embed_books.published=eq.true&embed_books.order=title.desc").
+		WithAuth(token).
+		Send().
+		AssertStatus(200)
+
+	var withBooks []map[string]interface{}
+	resp.JSON(&withBooks)
+	require.Len(t, withBooks, 1)
+	assert.Equal(t, authorName, withBooks[0]["name"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"title": "B"},
+		map[string]interface{}{"title": "A"},
+	}, withBooks[0]["embed_books"])
+
+	// Many-to-one: the author of each book as an object
+	resp = tc.NewRequest("GET", "/api/v1/tables/public/embed_books?select=title,author:embed_authors(name)&title=eq.Draft").
+		WithAuth(token).
+		Send().
+		AssertStatus(200)
+
+	var withAuthor []map[string]interface{}
+	resp.JSON(&withAuthor)
+	require.NotEmpty(t, withAuthor)
+	assert.Contains(t, withAuthor, map[string]interface{}{
+		"title":  "Draft",
+		"author": map[string]interface{}{"name": authorName},
+	})
+
+	// Unknown relations are rejected
+	tc.NewRequest("GET", "/api/v1/tables/public/embed_books?select=title,reviews(body)").
+		WithAuth(token).
+		Send().
+		AssertStatus(400)
+}
+
 func TestRESTHandler_Aggregation_Count_Integration(t *testing.T) {
 	tc := testutil.NewIntegrationTestContext(t)
 	defer tc.Close()
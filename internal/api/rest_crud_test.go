@@ -1900,7 +1900,7 @@ func TestBuildSelectQuery_QueryGeneration(t *testing.T) {
 			Select: []string{"id", "name"},
 		}
 
-		query, args := handler.buildSelectQuery(table, params)
+		query, args := handler.buildSelectQuery(table, params, nil)
 		assert.Contains(t, query, "SELECT")
 		assert.Contains(t, query, "FROM")
 		assert.Contains(t, query, `"public"."items"`)
@@ -1916,7 +1916,7 @@ func TestBuildSelectQuery_QueryGeneration(t *testing.T) {
 			Limit:   &limit,
 		}
 
-		query, args := handler.buildSelectQuery(table, params)
+		query, args := handler.buildSelectQuery(table, params, nil)
 		assert.Contains(t, query, "WHERE")
 		assert.NotEmpty(t, args)
 	})
@@ -1927,7 +1927,7 @@ func TestBuildSelectQuery_QueryGeneration(t *testing.T) {
 			Order:  []OrderBy{{Column: "name", Desc: false}},
 		}
 
-		query, args := handler.buildSelectQuery(table, params)
+		query, args := handler.buildSelectQuery(table, params, nil)
 		assert.Contains(t, query, "ORDER BY")
 		_ = args
 	})
@@ -1938,7 +1938,7 @@ func TestBuildSelectQuery_QueryGeneration(t *testing.T) {
 			Order:  []OrderBy{{Column: "name", Desc: true}},
 		}
 
-		query, args := handler.buildSelectQuery(table, params)
+		query, args := handler.buildSelectQuery(table, params, nil)
 		assert.Contains(t, query, "ORDER BY")
 		assert.Contains(t, query, "DESC")
 		_ = args
@@ -1951,7 +1951,7 @@ func TestBuildSelectQuery_QueryGeneration(t *testing.T) {
 			Limit:  &limit,
 		}
 
-		query, args := handler.buildSelectQuery(table, params)
+		query, args := handler.buildSelectQuery(table, params, nil)
 		assert.Contains(t, query, "LIMIT")
 		_ = args
 	})
@@ -1965,7 +1965,7 @@ func TestBuildSelectQuery_QueryGeneration(t *testing.T) {
 			Offset: &offset,
 		}
 
-		query, args := handler.buildSelectQuery(table, params)
+		query, args := handler.buildSelectQuery(table, params, nil)
 		assert.Contains(t, query, "OFFSET")
 		_ = args
 	})
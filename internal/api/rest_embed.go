@@ -0,0 +1,349 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/nimbleflux/fluxbase/internal/database"
+)
+
+// errInvalidEmbed means an embedded relation in select cannot be resolved (400)
+var errInvalidEmbed = errors.New("invalid embedded relation")
+
+// resolvedEmbed is an embedded relation resolved against the foreign keys of the schema
+type resolvedEmbed struct {
+	relation EmbeddedRelation
+	table    database.TableInfo
+	// toOne relations embed a single object (or null) instead of an array
+	toOne bool
+	// Rows match when table.childColumn = parent.parentColumn, or, for many-to-many
+	// relations, when a junction row links both
+	parentColumn string
+	childColumn  string
+	junction     *junctionTable
+	children     []resolvedEmbed
+}
+
+// junctionTable links two tables in a many-to-many relation
+type junctionTable struct {
+	table        database.TableInfo
+	parentColumn string // Junction column referencing the parent table
+	childColumn  string // Junction column referencing the embedded table
+}
+
+// resolveEmbeds resolves the embedded relations of a select against the foreign keys of table
+func (h *RESTHandler) resolveEmbeds(ctx context.Context, table database.TableInfo, relations []EmbeddedRelation) ([]resolvedEmbed, error) {
+	embeds := make([]resolvedEmbed, 0, len(relations))
+	for _, rel := range relations {
+		embed, err := h.resolveEmbed(ctx, table, rel)
+		if err != nil {
+			return nil, err
+		}
+		embeds = append(embeds, embed)
+	}
+	return embeds, nil
+}
+
+// deniedEmbedTable returns the first embedded table, junction tables included, that a client
+// key's restrictions don't let it read, or "" when it can read them all
+func deniedEmbedTable(restrictions auth.ClientKeyRestrictions, embeds []resolvedEmbed) string {
+	for _, embed := range embeds {
+		if !restrictions.AllowsTable(embed.table.Schema, embed.table.Name, false) {
+			return embed.table.Schema + "." + embed.table.Name
+		}
+		if j := embed.junction; j != nil && !restrictions.AllowsTable(j.table.Schema, j.table.Name, false) {
+			return j.table.Schema + "." + j.table.Name
+		}
+		if denied := deniedEmbedTable(restrictions, embed.children); denied != "" {
+			return denied
+		}
+	}
+	return ""
+}
+
+// hiddenEmbedRelation returns the first embedded relation whose table or junction table lives
+// in a schema that isn't exposed, or "" when every table is exposed. Callers that cannot access
+// hidden schemas get the same answer as for a missing relationship so those tables cannot be probed.
+func (h *RESTHandler) hiddenEmbedRelation(embeds []resolvedEmbed) string {
+	for _, embed := range embeds {
+		if !h.isSchemaExposed(embed.table.Schema) {
+			return embed.relation.Key()
+		}
+		if j := embed.junction; j != nil && !h.isSchemaExposed(j.table.Schema) {
+			return embed.relation.Key()
+		}
+		if hidden := h.hiddenEmbedRelation(embed.children); hidden != "" {
+			return hidden
+		}
+	}
+	return ""
+}
+
+// resolveEmbed finds the single relationship between parent and rel. Like PostgREST, a
+// relation is a table referenced by parent (many-to-one), a table referencing parent
+// (one-to-many) or a table linked to parent through a junction table (many-to-many).
+func (h *RESTHandler) resolveEmbed(ctx context.Context, parent database.TableInfo, rel EmbeddedRelation) (resolvedEmbed, error) {
+	var candidates []resolvedEmbed
+	var names []string
+
+	// Many-to-one: parent.fk_column references the embedded table
+	for _, fk := range singleColumnForeignKeys(parent.ForeignKeys) {
+		refSchema, refName, _ := strings.Cut(fk.ReferencedTable, ".")
+		if rel.Name != refName && rel.Name != fk.ColumnName {
+			continue
+		}
+		if !hintMatches(rel.Hint, fk) {
+			continue
+		}
+		target, ok, err := h.schemaCache.GetTable(ctx, refSchema, refName)
+		if err != nil {
+			return resolvedEmbed{}, fmt.Errorf("failed to load table %s: %w", fk.ReferencedTable, err)
+		}
+		if !ok {
+			continue
+		}
+		candidates = append(candidates, resolvedEmbed{
+			relation:     rel,
+			table:        *target,
+			toOne:        true,
+			parentColumn: fk.ColumnName,
+			childColumn:  fk.ReferencedColumn,
+		})
+		names = append(names, fk.Name)
+	}
+
+	target, ok, err := h.schemaCache.GetTable(ctx, parent.Schema, rel.Name)
+	if err != nil {
+		return resolvedEmbed{}, fmt.Errorf("failed to load table %s.%s: %w", parent.Schema, rel.Name, err)
+	}
+
+	// One-to-many: the embedded table references parent. A unique foreign key column makes
+	// it one-to-one.
+	if ok {
+		for _, fk := range singleColumnForeignKeys(target.ForeignKeys) {
+			if fk.ReferencedTable != parent.Schema+"."+parent.Name || !hintMatches(rel.Hint, fk) {
+				continue
+			}
+			candidates = append(candidates, resolvedEmbed{
+				relation:     rel,
+				table:        *target,
+				toOne:        isUniqueColumn(*target, fk.ColumnName),
+				parentColumn: fk.ReferencedColumn,
+				childColumn:  fk.ColumnName,
+			})
+			names = append(names, fk.Name)
+		}
+	}
+
+	// Many-to-many: a junction table references both tables
+	if ok && len(candidates) == 0 {
+		tables, err := h.schemaCache.GetAllTables(ctx)
+		if err != nil {
+			return resolvedEmbed{}, fmt.Errorf("failed to load tables: %w", err)
+		}
+		for _, junction := range tables {
+			if junction.Schema != parent.Schema || (rel.Hint != "" && rel.Hint != junction.Name) {
+				continue
+			}
+			for _, toParent := range singleColumnForeignKeys(junction.ForeignKeys) {
+				if toParent.ReferencedTable != parent.Schema+"."+parent.Name {
+					continue
+				}
+				for _, toTarget := range singleColumnForeignKeys(junction.ForeignKeys) {
+					if toTarget.Name == toParent.Name || toTarget.ReferencedTable != target.Schema+"."+target.Name {
+						continue
+					}
+					candidates = append(candidates, resolvedEmbed{
+						relation:     rel,
+						table:        *target,
+						parentColumn: toParent.ReferencedColumn,
+						childColumn:  toTarget.ReferencedColumn,
+						junction: &junctionTable{
+							table:        junction,
+							parentColumn: toParent.ColumnName,
+							childColumn:  toTarget.ColumnName,
+						},
+					})
+					names = append(names, junction.Name)
+				}
+			}
+		}
+	}
+
+	switch {
+	case len(candidates) == 0:
+		if rel.Hint != "" {
+			return resolvedEmbed{}, fmt.Errorf("%w: no relationship between %s and %s matches %q",
+				errInvalidEmbed, parent.Name, rel.Name, rel.Hint)
+		}
+		return resolvedEmbed{}, fmt.Errorf("%w: no relationship found between %s and %s",
+			errInvalidEmbed, parent.Name, rel.Name)
+	case len(candidates) > 1:
+		return resolvedEmbed{}, fmt.Errorf("%w: more than one relationship found between %s and %s, disambiguate with %s!<hint> using one of: %s",
+			errInvalidEmbed, parent.Name, rel.Name, rel.Name, strings.Join(names, ", "))
+	}
+
+	embed := candidates[0]
+	if err := validateEmbedColumns(embed); err != nil {
+		return resolvedEmbed{}, err
+	}
+	if embed.children, err = h.resolveEmbeds(ctx, embed.table, rel.Embedded); err != nil {
+		return resolvedEmbed{}, err
+	}
+	return embed, nil
+}
+
+// singleColumnForeignKeys returns the foreign keys spanning a single column. Composite
+// foreign keys are listed once per column and cannot be embedded.
+func singleColumnForeignKeys(fks []database.ForeignKey) []database.ForeignKey {
+	counts := make(map[string]int, len(fks))
+	for _, fk := range fks {
+		counts[fk.Name]++
+	}
+	single := make([]database.ForeignKey, 0, len(fks))
+	for _, fk := range fks {
+		if counts[fk.Name] == 1 {
+			single = append(single, fk)
+		}
+	}
+	return single
+}
+
+// hintMatches reports whether a relation hint names the foreign key constraint or column
+func hintMatches(hint string, fk database.ForeignKey) bool {
+	return hint == "" || hint == fk.Name || hint == fk.ColumnName
+}
+
+// isUniqueColumn reports whether each value of column occurs in at most one row
+func isUniqueColumn(table database.TableInfo, column string) bool {
+	if len(table.PrimaryKey) == 1 && table.PrimaryKey[0] == column {
+		return true
+	}
+	col := table.GetColumn(column)
+	return col != nil && col.IsUnique
+}
+
+// validateEmbedColumns checks that the selected, filtered and ordered columns of an embedded
+// relation exist, since unknown columns would otherwise resolve against the parent table
+func validateEmbedColumns(embed resolvedEmbed) error {
+	rel := embed.relation
+	check := func(column string) error {
+		name, _, _ := strings.Cut(column, "->")
+		if !embed.table.HasColumn(strings.TrimSpace(name)) {
+			return fmt.Errorf("%w: unknown column %s in relation %s", errInvalidEmbed, column, rel.Key())
+		}
+		return nil
+	}
+
+	for _, field := range rel.Select {
+		if field != "*" && !embed.table.HasColumn(field) {
+			return fmt.Errorf("%w: unknown column %s in relation %s", errInvalidEmbed, field, rel.Key())
+		}
+	}
+	for _, f := range rel.Filters {
+		if err := check(f.Column); err != nil {
+			return err
+		}
+	}
+	for _, o := range rel.Order {
+		if err := check(o.Column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildEmbedColumns renders embedded relations as select columns. Each relation is a
+// correlated subquery on parentRef returning a JSON array, or an object for to-one relations.
+func buildEmbedColumns(embeds []resolvedEmbed, parentRef string, argCounter *int, aliasCounter *int) ([]string, []interface{}) {
+	var columns []string
+	var args []interface{}
+	for _, embed := range embeds {
+		column, embedArgs := buildEmbedSubquery(embed, parentRef, argCounter, aliasCounter)
+		columns = append(columns, column)
+		args = append(args, embedArgs...)
+	}
+	return columns, args
+}
+
+// buildEmbedSubquery renders a single embedded relation
+func buildEmbedSubquery(embed resolvedEmbed, parentRef string, argCounter *int, aliasCounter *int) (string, []interface{}) {
+	*aliasCounter++
+	alias := fmt.Sprintf("_embed_%d", *aliasCounter)
+	rel := embed.relation
+	var args []interface{}
+
+	// Columns of the embedded rows, including the relations embedded in them
+	var columns []string
+	if len(rel.Select) == 0 || slices.Contains(rel.Select, "*") {
+		for _, col := range embed.table.Columns {
+			if !isValidIdentifier(col.Name) {
+				continue // Skip invalid column names
+			}
+			columns = append(columns, embedColumnSQL(alias, col))
+		}
+	} else {
+		for _, field := range rel.Select {
+			columns = append(columns, embedColumnSQL(alias, *embed.table.GetColumn(field)))
+		}
+	}
+	childColumns, childArgs := buildEmbedColumns(embed.children, alias, argCounter, aliasCounter)
+	columns = append(columns, childColumns...)
+	args = append(args, childArgs...)
+
+	// Join condition
+	var conditions []string
+	if embed.junction != nil {
+		junctionAlias := alias + "_junction"
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM %s.%s AS %s WHERE %s.%s = %s.%s AND %s.%s = %s.%s)",
+			quoteIdentifier(embed.junction.table.Schema), quoteIdentifier(embed.junction.table.Name), junctionAlias,
+			junctionAlias, quoteIdentifier(embed.junction.childColumn), alias, quoteIdentifier(embed.childColumn),
+			junctionAlias, quoteIdentifier(embed.junction.parentColumn), parentRef, quoteIdentifier(embed.parentColumn),
+		))
+	} else {
+		conditions = append(conditions, fmt.Sprintf("%s.%s = %s.%s",
+			alias, quoteIdentifier(embed.childColumn), parentRef, quoteIdentifier(embed.parentColumn)))
+	}
+
+	// Filters are unqualified, so they resolve against the embedded table
+	filterParams := &QueryParams{Filters: rel.Filters}
+	if where, whereArgs := filterParams.buildWhereClause(argCounter); where != "" {
+		conditions = append(conditions, where)
+		args = append(args, whereArgs...)
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s.%s AS %s WHERE %s",
+		strings.Join(columns, ", "),
+		quoteIdentifier(embed.table.Schema), quoteIdentifier(embed.table.Name), alias,
+		strings.Join(conditions, " AND "),
+	)
+
+	rowsAlias := alias + "_rows"
+	if embed.toOne {
+		query += " LIMIT 1"
+		return fmt.Sprintf("(SELECT to_jsonb(%s) FROM (%s) AS %s) AS %s",
+			rowsAlias, query, rowsAlias, quoteIdentifier(rel.Key())), args
+	}
+
+	pageParams := &QueryParams{Order: rel.Order, Limit: rel.Limit, Offset: rel.Offset}
+	if page, pageArgs := pageParams.toSQL(argCounter); page != "" {
+		query += " " + page
+		args = append(args, pageArgs...)
+	}
+	return fmt.Sprintf("COALESCE((SELECT jsonb_agg(to_jsonb(%s)) FROM (%s) AS %s), '[]'::jsonb) AS %s",
+		rowsAlias, query, rowsAlias, quoteIdentifier(rel.Key())), args
+}
+
+// embedColumnSQL selects a column of an embedded table, converting geometries to GeoJSON
+func embedColumnSQL(alias string, col database.ColumnInfo) string {
+	quotedName := quoteIdentifier(col.Name)
+	if isGeometryColumn(col.DataType) {
+		return fmt.Sprintf("ST_AsGeoJSON(%s.%s)::jsonb AS %s", alias, quotedName, quotedName)
+	}
+	return fmt.Sprintf("%s.%s", alias, quotedName)
+}
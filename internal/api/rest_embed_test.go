@@ -0,0 +1,243 @@
+package api
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func embedTestTables() (users, posts, comments database.TableInfo) {
+	users = database.TableInfo{
+		Schema:     "public",
+		Name:       "users",
+		PrimaryKey: []string{"id"},
+		Columns: []database.ColumnInfo{
+			{Name: "id", DataType: "uuid"},
+			{Name: "name", DataType: "text"},
+		},
+	}
+	posts = database.TableInfo{
+		Schema:     "public",
+		Name:       "posts",
+		PrimaryKey: []string{"id"},
+		Columns: []database.ColumnInfo{
+			{Name: "id", DataType: "uuid"},
+			{Name: "author_id", DataType: "uuid"},
+			{Name: "title", DataType: "text"},
+			{Name: "location", DataType: "geometry"},
+		},
+		ForeignKeys: []database.ForeignKey{
+			{Name: "posts_author_id_fkey", ColumnName: "author_id", ReferencedTable: "public.users", ReferencedColumn: "id"},
+		},
+	}
+	comments = database.TableInfo{
+		Schema:     "public",
+		Name:       "comments",
+		PrimaryKey: []string{"id"},
+		Columns: []database.ColumnInfo{
+			{Name: "id", DataType: "uuid"},
+			{Name: "post_id", DataType: "uuid"},
+			{Name: "body", DataType: "text"},
+		},
+	}
+	return users, posts, comments
+}
+
+func TestBuildSelectQuery_EmbedsToMany(t *testing.T) {
+	users, posts, comments := embedTestTables()
+	handler := &RESTHandler{parser: NewQueryParser(testConfig())}
+
+	values, err := url.ParseQuery("select=id,name,posts(title,comments(body))&posts.title=neq.Draft&posts.order=title.desc&posts.limit=5&name=eq.ada")
+	require.NoError(t, err)
+	params, err := handler.parser.Parse(values)
+	require.NoError(t, err)
+
+	embeds := []resolvedEmbed{{
+		relation:     params.Embedded[0],
+		table:        posts,
+		parentColumn: "id",
+		childColumn:  "author_id",
+		children: []resolvedEmbed{{
+			relation:     params.Embedded[0].Embedded[0],
+			table:        comments,
+			parentColumn: "id",
+			childColumn:  "post_id",
+		}},
+	}}
+
+	query, args := handler.buildSelectQuery(users, params, embeds)
+	assert.Equal(t, `SELECT "id", "name", `+
+		`COALESCE((SELECT jsonb_agg(to_jsonb(_embed_1_rows)) FROM (SELECT _embed_1."title", `+
+		`COALESCE((SELECT jsonb_agg(to_jsonb(_embed_2_rows)) FROM (SELECT _embed_2."body" FROM "public"."comments" AS _embed_2 `+
+		`WHERE _embed_2."post_id" = _embed_1."id") AS _embed_2_rows), '[]'::jsonb) AS "comments" `+
+		`FROM "public"."posts" AS _embed_1 WHERE _embed_1."author_id" = "public"."users"."id" AND "title" != $1 `+
+		`ORDER BY "title" DESC LIMIT $2) AS _embed_1_rows), '[]'::jsonb) AS "posts" `+
+		`FROM "public"."users" WHERE "name" = $3`, query)
+	assert.Equal(t, []interface{}{"Draft", 5, "ada"}, args)
+}
+
+func TestBuildSelectQuery_EmbedsToOne(t *testing.T) {
+	users, posts, _ := embedTestTables()
+	handler := &RESTHandler{parser: NewQueryParser(testConfig())}
+
+	values, err := url.ParseQuery("select=author:author_id(*)")
+	require.NoError(t, err)
+	params, err := handler.parser.Parse(values)
+	require.NoError(t, err)
+
+	embeds := []resolvedEmbed{{
+		relation:     params.Embedded[0],
+		table:        users,
+		toOne:        true,
+		parentColumn: "author_id",
+		childColumn:  "id",
+	}}
+
+	// Only relations are selected, so no columns of posts are returned
+	query, args := handler.buildSelectQuery(posts, params, embeds)
+	assert.Equal(t, `SELECT (SELECT to_jsonb(_embed_1_rows) FROM (SELECT _embed_1."id", _embed_1."name" `+
+		`FROM "public"."users" AS _embed_1 WHERE _embed_1."id" = "public"."posts"."author_id" LIMIT 1) AS _embed_1_rows) AS "author" `+
+		`FROM "public"."posts"`, query)
+	assert.Empty(t, args)
+}
+
+func TestBuildSelectQuery_EmbedsManyToMany(t *testing.T) {
+	_, posts, _ := embedTestTables()
+	handler := &RESTHandler{parser: NewQueryParser(testConfig())}
+	tags := database.TableInfo{
+		Schema:  "public",
+		Name:    "tags",
+		Columns: []database.ColumnInfo{{Name: "id", DataType: "uuid"}, {Name: "label", DataType: "text"}},
+	}
+
+	embeds := []resolvedEmbed{{
+		relation:     EmbeddedRelation{Name: "tags", Select: []string{"label"}},
+		table:        tags,
+		parentColumn: "id",
+		childColumn:  "id",
+		junction: &junctionTable{
+			table:        database.TableInfo{Schema: "public", Name: "post_tags"},
+			parentColumn: "post_id",
+			childColumn:  "tag_id",
+		},
+	}}
+
+	query, _ := handler.buildSelectQuery(posts, &QueryParams{Select: []string{"title"}}, embeds)
+	assert.Contains(t, query, `FROM "public"."tags" AS _embed_1 WHERE EXISTS (SELECT 1 FROM "public"."post_tags" AS _embed_1_junction `+
+		`WHERE _embed_1_junction."tag_id" = _embed_1."id" AND _embed_1_junction."post_id" = "public"."posts"."id")`)
+}
+
+func TestBuildSelectQuery_EmbedConvertsGeometry(t *testing.T) {
+	users, posts, _ := embedTestTables()
+	handler := &RESTHandler{parser: NewQueryParser(testConfig())}
+
+	embeds := []resolvedEmbed{{
+		relation:     EmbeddedRelation{Name: "posts", Select: []string{"location"}},
+		table:        posts,
+		parentColumn: "id",
+		childColumn:  "author_id",
+	}}
+
+	query, _ := handler.buildSelectQuery(users, &QueryParams{}, embeds)
+	assert.Contains(t, query, `ST_AsGeoJSON(_embed_1."location")::jsonb AS "location"`)
+}
+
+func TestValidateEmbedColumns(t *testing.T) {
+	_, posts, _ := embedTestTables()
+	embed := resolvedEmbed{table: posts}
+
+	embed.relation = EmbeddedRelation{Name: "posts", Select: []string{"*"}, Filters: []Filter{{Column: "title", Operator: OpEqual}}}
+	assert.NoError(t, validateEmbedColumns(embed))
+
+	embed.relation = EmbeddedRelation{Name: "posts", Select: []string{"name"}}
+	assert.ErrorIs(t, validateEmbedColumns(embed), errInvalidEmbed, "name is a column of the parent only")
+
+	embed.relation = EmbeddedRelation{Name: "posts", Filters: []Filter{{Column: "name", Operator: OpEqual}}}
+	assert.ErrorIs(t, validateEmbedColumns(embed), errInvalidEmbed)
+
+	embed.relation = EmbeddedRelation{Name: "posts", Order: []OrderBy{{Column: "missing"}}}
+	assert.ErrorIs(t, validateEmbedColumns(embed), errInvalidEmbed)
+}
+
+func TestSingleColumnForeignKeys(t *testing.T) {
+	fks := []database.ForeignKey{
+		{Name: "orders_customer_fkey", ColumnName: "customer_id"},
+		{Name: "orders_item_fkey", ColumnName: "item_id"},
+		{Name: "orders_item_fkey", ColumnName: "item_version"},
+	}
+	single := singleColumnForeignKeys(fks)
+	require.Len(t, single, 1)
+	assert.Equal(t, "customer_id", single[0].ColumnName)
+}
+
+func TestHintMatches(t *testing.T) {
+	fk := database.ForeignKey{Name: "posts_author_id_fkey", ColumnName: "author_id"}
+	assert.True(t, hintMatches("", fk))
+	assert.True(t, hintMatches("posts_author_id_fkey", fk))
+	assert.True(t, hintMatches("author_id", fk))
+	assert.False(t, hintMatches("editor_id", fk))
+}
+
+func TestIsUniqueColumn(t *testing.T) {
+	table := database.TableInfo{
+		PrimaryKey: []string{"id"},
+		Columns: []database.ColumnInfo{
+			{Name: "id"}, {Name: "user_id", IsUnique: true}, {Name: "team_id"},
+		},
+	}
+	assert.True(t, isUniqueColumn(table, "id"))
+	assert.True(t, isUniqueColumn(table, "user_id"))
+	assert.False(t, isUniqueColumn(table, "team_id"))
+}
+
+func TestDeniedEmbedTable(t *testing.T) {
+	table := func(schema, name string) database.TableInfo {
+		return database.TableInfo{Schema: schema, Name: name}
+	}
+	embeds := []resolvedEmbed{
+		{table: table("public", "authors")},
+		{
+			table:    table("public", "tags"),
+			junction: &junctionTable{table: table("public", "post_tags")},
+			children: []resolvedEmbed{{table: table("public", "tag_groups")}},
+		},
+	}
+
+	assert.Empty(t, deniedEmbedTable(auth.ClientKeyRestrictions{}, embeds), "unrestricted keys read every table")
+	assert.Empty(t, deniedEmbedTable(auth.ClientKeyRestrictions{AllowedTables: map[string]string{"public.*": auth.TableAccessRead}}, embeds))
+	assert.Equal(t, "public.post_tags", deniedEmbedTable(auth.ClientKeyRestrictions{AllowedTables: map[string]string{
+		"authors": auth.TableAccessRead, "tags": auth.TableAccessRead, "tag_groups": auth.TableAccessRead,
+	}}, embeds))
+	assert.Equal(t, "public.tag_groups", deniedEmbedTable(auth.ClientKeyRestrictions{AllowedTables: map[string]string{
+		"authors": auth.TableAccessRead, "tags": auth.TableAccessRead, "post_tags": auth.TableAccessWrite,
+	}}, embeds))
+}
+
+func TestHiddenEmbedRelation(t *testing.T) {
+	handler := &RESTHandler{config: &config.Config{API: config.APIConfig{HiddenSchemas: []string{"auth"}}}}
+	table := func(schema, name string) database.TableInfo {
+		return database.TableInfo{Schema: schema, Name: name}
+	}
+
+	// select=*,users(*) through a foreign key to auth.users
+	assert.Equal(t, "users", handler.hiddenEmbedRelation([]resolvedEmbed{
+		{relation: EmbeddedRelation{Name: "authors"}, table: table("public", "authors")},
+		{relation: EmbeddedRelation{Name: "users"}, table: table("auth", "users")},
+	}))
+	assert.Equal(t, "tags", handler.hiddenEmbedRelation([]resolvedEmbed{{
+		relation: EmbeddedRelation{Name: "tags"},
+		table:    table("public", "tags"),
+		junction: &junctionTable{table: table("auth", "post_tags")},
+	}}), "junction tables must be exposed too")
+	assert.Equal(t, "users", handler.hiddenEmbedRelation([]resolvedEmbed{{
+		relation: EmbeddedRelation{Name: "authors"},
+		table:    table("public", "authors"),
+		children: []resolvedEmbed{{relation: EmbeddedRelation{Name: "users"}, table: table("auth", "users")}},
+	}}))
+	assert.Empty(t, handler.hiddenEmbedRelation([]resolvedEmbed{{relation: EmbeddedRelation{Name: "authors"}, table: table("public", "authors")}}))
+}
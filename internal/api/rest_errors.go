@@ -5,112 +5,104 @@ import (
 	"strings"
 
 	"github.com/gofiber/fiber/v3"
-	"github.com/gofiber/fiber/v3/middleware/requestid"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
 	"github.com/nimbleflux/fluxbase/internal/middleware"
 	"github.com/rs/zerolog/log"
 )
 
 // Standard error codes for consistent API error responses.
-// These codes are returned in the "code" field of error responses.
+// These alias the apierror codes so handlers in this package can keep the ErrCode prefix.
 const (
 	// Authentication errors (401)
-	ErrCodeMissingAuth        = "MISSING_AUTHENTICATION"
-	ErrCodeInvalidToken       = "INVALID_TOKEN"
-	ErrCodeExpiredToken       = "EXPIRED_TOKEN"
-	ErrCodeRevokedToken       = "REVOKED_TOKEN"
-	ErrCodeAuthRequired       = "AUTHENTICATION_REQUIRED"
-	ErrCodeInvalidUserID      = "INVALID_USER_ID"
-	ErrCodeAccountLocked      = "ACCOUNT_LOCKED"
-	ErrCodeInvalidCredentials = "INVALID_CREDENTIALS" //nolint:gosec // Not a credential, just an error code constant
+	ErrCodeMissingAuth        = apierror.CodeMissingAuth
+	ErrCodeInvalidToken       = apierror.CodeInvalidToken
+	ErrCodeExpiredToken       = apierror.CodeExpiredToken
+	ErrCodeRevokedToken       = apierror.CodeRevokedToken
+	ErrCodeAuthRequired       = apierror.CodeAuthRequired
+	ErrCodeInvalidUserID      = apierror.CodeInvalidUserID
+	ErrCodeAccountLocked      = apierror.CodeAccountLocked
+	ErrCodeInvalidCredentials = apierror.CodeInvalidCredentials
 
 	// Authorization errors (403)
-	ErrCodeInsufficientPermissions = "INSUFFICIENT_PERMISSIONS"
-	ErrCodeAdminRequired           = "ADMIN_REQUIRED"
-	ErrCodeInvalidRole             = "INVALID_ROLE"
-	ErrCodeRLSViolation            = "RLS_POLICY_VIOLATION"
-	ErrCodeAccessDenied            = "ACCESS_DENIED"
-	ErrCodeFeatureDisabled         = "FEATURE_DISABLED"
+	ErrCodeInsufficientPermissions = apierror.CodeInsufficientPermissions
+	ErrCodeAdminRequired           = apierror.CodeAdminRequired
+	ErrCodeInvalidRole             = apierror.CodeInvalidRole
+	ErrCodeRLSViolation            = apierror.CodeRLSViolation
+	ErrCodeAccessDenied            = apierror.CodeAccessDenied
+	ErrCodeFeatureDisabled         = apierror.CodeFeatureDisabled
 
 	// Validation errors (400)
-	ErrCodeInvalidBody      = "INVALID_REQUEST_BODY"
-	ErrCodeMissingField     = "MISSING_REQUIRED_FIELD"
-	ErrCodeInvalidInput     = "INVALID_INPUT"
-	ErrCodeInvalidID        = "INVALID_ID"
-	ErrCodeInvalidFormat    = "INVALID_FORMAT"
-	ErrCodeValidationFailed = "VALIDATION_FAILED"
-
-	// Resource errors (404, 409)
-	ErrCodeNotFound            = "NOT_FOUND"
-	ErrCodeAlreadyExists       = "ALREADY_EXISTS"
-	ErrCodeDuplicateKey        = "DUPLICATE_KEY"
-	ErrCodeConflict            = "CONFLICT"
-	ErrCodeForeignKeyViolation = "FOREIGN_KEY_VIOLATION"
+	ErrCodeInvalidBody      = apierror.CodeInvalidBody
+	ErrCodeMissingField     = apierror.CodeMissingField
+	ErrCodeInvalidInput     = apierror.CodeInvalidInput
+	ErrCodeInvalidID        = apierror.CodeInvalidID
+	ErrCodeInvalidFormat    = apierror.CodeInvalidFormat
+	ErrCodeValidationFailed = apierror.CodeValidationFailed
+
+	// Resource errors (404, 405, 409, 410, 413, 415)
+	ErrCodeNotFound             = apierror.CodeNotFound
+	ErrCodeMethodNotAllowed     = apierror.CodeMethodNotAllowed
+	ErrCodeAlreadyExists        = apierror.CodeAlreadyExists
+	ErrCodeDuplicateKey         = apierror.CodeDuplicateKey
+	ErrCodeConflict             = apierror.CodeConflict
+	ErrCodeForeignKeyViolation  = apierror.CodeForeignKeyViolation
+	ErrCodeGone                 = apierror.CodeGone
+	ErrCodePayloadTooLarge      = apierror.CodePayloadTooLarge
+	ErrCodeUnsupportedMediaType = apierror.CodeUnsupportedMediaType
 
 	// Constraint errors (400)
-	ErrCodeNotNullViolation = "NOT_NULL_VIOLATION"
-	ErrCodeCheckViolation   = "CHECK_VIOLATION"
-
-	// Server errors (500)
-	ErrCodeInternalError   = "INTERNAL_ERROR"
-	ErrCodeDatabaseError   = "DATABASE_ERROR"
-	ErrCodeOperationFailed = "OPERATION_FAILED"
+	ErrCodeNotNullViolation = apierror.CodeNotNullViolation
+	ErrCodeCheckViolation   = apierror.CodeCheckViolation
+
+	// Server errors (5xx)
+	ErrCodeInternalError      = apierror.CodeInternalError
+	ErrCodeDatabaseError      = apierror.CodeDatabaseError
+	ErrCodeOperationFailed    = apierror.CodeOperationFailed
+	ErrCodeNotImplemented     = apierror.CodeNotImplemented
+	ErrCodeUpstreamError      = apierror.CodeUpstreamError
+	ErrCodeServiceUnavailable = apierror.CodeServiceUnavailable
+	ErrCodeTimeout            = apierror.CodeTimeout
 
 	// Rate limiting (429)
-	ErrCodeRateLimited     = "RATE_LIMIT_EXCEEDED"
-	ErrCodeTooManyRequests = "TOO_MANY_REQUESTS"
+	ErrCodeRateLimited     = apierror.CodeRateLimited
+	ErrCodeTooManyRequests = apierror.CodeTooManyRequests
 
 	// Setup/config errors
-	ErrCodeSetupRequired     = "SETUP_REQUIRED"
-	ErrCodeSetupCompleted    = "SETUP_ALREADY_COMPLETED"
-	ErrCodeSetupDisabled     = "SETUP_DISABLED"
-	ErrCodeInvalidSetupToken = "INVALID_SETUP_TOKEN"
+	ErrCodeSetupRequired     = apierror.CodeSetupRequired
+	ErrCodeSetupCompleted    = apierror.CodeSetupCompleted
+	ErrCodeSetupDisabled     = apierror.CodeSetupDisabled
+	ErrCodeInvalidSetupToken = apierror.CodeInvalidSetupToken
 )
 
 // getRequestID extracts the request ID from the Fiber context.
 // It first checks the requestid middleware local, then falls back to the X-Request-ID header.
 func getRequestID(c fiber.Ctx) string {
-	if requestID := requestid.FromContext(c); requestID != "" {
-		return requestID
-	}
-	return c.Get("X-Request-ID", "")
+	return apierror.RequestID(c)
 }
 
 // ErrorResponse represents a standardized API error response
-type ErrorResponse struct {
-	Error     string      `json:"error"`
-	Code      string      `json:"code,omitempty"`
-	Message   string      `json:"message,omitempty"`
-	Hint      string      `json:"hint,omitempty"`
-	Details   interface{} `json:"details,omitempty"`
-	RequestID string      `json:"request_id,omitempty"`
-}
+type ErrorResponse = apierror.Response
 
-// SendError sends a standardized error response with request ID
+// SendError sends a standardized error response with request ID.
+// The code defaults to the one for the status.
 func SendError(c fiber.Ctx, statusCode int, errMsg string) error {
-	return c.Status(statusCode).JSON(ErrorResponse{
-		Error:     errMsg,
-		RequestID: getRequestID(c),
-	})
+	return apierror.Send(c, statusCode, "", errMsg)
 }
 
 // SendErrorWithCode sends a standardized error response with error code and request ID
 func SendErrorWithCode(c fiber.Ctx, statusCode int, errMsg string, code string) error {
-	return c.Status(statusCode).JSON(ErrorResponse{
-		Error:     errMsg,
-		Code:      code,
-		RequestID: getRequestID(c),
-	})
+	return apierror.Send(c, statusCode, code, errMsg)
 }
 
 // SendErrorWithDetails sends a detailed error response with request ID
 func SendErrorWithDetails(c fiber.Ctx, statusCode int, errMsg string, code string, message string, hint string, details interface{}) error {
-	return c.Status(statusCode).JSON(ErrorResponse{
-		Error:     errMsg,
-		Code:      code,
-		Message:   message,
-		Hint:      hint,
-		Details:   details,
-		RequestID: getRequestID(c),
+	return apierror.SendError(c, &apierror.Error{
+		Status:  statusCode,
+		Code:    code,
+		Message: errMsg,
+		Detail:  message,
+		Hint:    hint,
+		Details: details,
 	})
 }
 
@@ -220,6 +212,16 @@ func handleDatabaseError(c fiber.Ctx, err error, operation string) error {
 		return SendErrorWithCode(c, 409, "Record with this value already exists", ErrCodeDuplicateKey)
 	}
 
+	// Upsert conflict target that no unique constraint covers (on_conflict)
+	if strings.Contains(errMsg, "no unique or exclusion constraint matching the ON CONFLICT specification") {
+		return SendErrorWithCode(c, 400, "on_conflict columns must match a primary key or unique constraint", ErrCodeInvalidInput)
+	}
+
+	// Upsert batch containing the same conflict key more than once
+	if strings.Contains(errMsg, "ON CONFLICT DO UPDATE command cannot affect row a second time") {
+		return SendErrorWithCode(c, 400, "Upsert batch contains duplicate values for the conflict columns", ErrCodeInvalidInput)
+	}
+
 	// Foreign key constraint violation
 	if strings.Contains(errMsg, "foreign key constraint") {
 		return SendErrorWithCode(c, 409, "Cannot complete operation due to foreign key constraint", ErrCodeForeignKeyViolation)
@@ -240,6 +242,11 @@ func handleDatabaseError(c fiber.Ctx, err error, operation string) error {
 		return SendErrorWithCode(c, 400, "Data violates table constraints", ErrCodeCheckViolation)
 	}
 
+	// Unknown language of a text search filter (fts(language))
+	if strings.Contains(errMsg, "text search configuration") && strings.Contains(errMsg, "does not exist") {
+		return SendErrorWithCode(c, 400, "Unknown text search language", ErrCodeInvalidInput)
+	}
+
 	// Generic server error for other cases
 	log.Error().
 		Err(err).
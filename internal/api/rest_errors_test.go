@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/middleware/requestid"
+	"github.com/nimbleflux/fluxbase/pkg/client"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -69,6 +71,27 @@ func TestHandleDatabaseError(t *testing.T) {
 			expectedStatus: 400,
 			expectedError:  "Data violates table constraints",
 		},
+		{
+			name:           "unknown text search language",
+			err:            errors.New(`ERROR: text search configuration "klingon" does not exist (SQLSTATE 42704)`),
+			operation:      "query table",
+			expectedStatus: 400,
+			expectedError:  "Unknown text search language",
+		},
+		{
+			name:           "on_conflict without matching unique constraint",
+			err:            errors.New("ERROR: there is no unique or exclusion constraint matching the ON CONFLICT specification (SQLSTATE 42P10)"),
+			operation:      "create records",
+			expectedStatus: 400,
+			expectedError:  "on_conflict columns must match a primary key or unique constraint",
+		},
+		{
+			name:           "upsert batch with duplicate conflict keys",
+			err:            errors.New("ERROR: ON CONFLICT DO UPDATE command cannot affect row a second time (SQLSTATE 21000)"),
+			operation:      "create records",
+			expectedStatus: 400,
+			expectedError:  "Upsert batch contains duplicate values for the conflict columns",
+		},
 		{
 			name:           "generic error",
 			err:            errors.New("some unknown database error"),
@@ -723,3 +746,77 @@ func TestErrorCodeConstants(t *testing.T) {
 		})
 	}
 }
+
+// The Go SDK mirrors the error catalog so callers can match codes without importing internal packages
+func TestErrorCodes_MatchClientSDK(t *testing.T) {
+	codes := map[string]string{
+		ErrCodeMissingAuth:             client.CodeMissingAuth,
+		ErrCodeInvalidToken:            client.CodeInvalidToken,
+		ErrCodeExpiredToken:            client.CodeExpiredToken,
+		ErrCodeRevokedToken:            client.CodeRevokedToken,
+		ErrCodeAuthRequired:            client.CodeAuthRequired,
+		ErrCodeInvalidUserID:           client.CodeInvalidUserID,
+		ErrCodeAccountLocked:           client.CodeAccountLocked,
+		ErrCodeInvalidCredentials:      client.CodeInvalidCredentials,
+		ErrCodeInsufficientPermissions: client.CodeInsufficientPermissions,
+		ErrCodeAdminRequired:           client.CodeAdminRequired,
+		ErrCodeInvalidRole:             client.CodeInvalidRole,
+		ErrCodeRLSViolation:            client.CodeRLSViolation,
+		ErrCodeAccessDenied:            client.CodeAccessDenied,
+		ErrCodeFeatureDisabled:         client.CodeFeatureDisabled,
+		ErrCodeInvalidBody:             client.CodeInvalidBody,
+		ErrCodeMissingField:            client.CodeMissingField,
+		ErrCodeInvalidInput:            client.CodeInvalidInput,
+		ErrCodeInvalidID:               client.CodeInvalidID,
+		ErrCodeInvalidFormat:           client.CodeInvalidFormat,
+		ErrCodeValidationFailed:        client.CodeValidationFailed,
+		ErrCodeNotFound:                client.CodeNotFound,
+		ErrCodeAlreadyExists:           client.CodeAlreadyExists,
+		ErrCodeDuplicateKey:            client.CodeDuplicateKey,
+		ErrCodeConflict:                client.CodeConflict,
+		ErrCodeForeignKeyViolation:     client.CodeForeignKeyViolation,
+		ErrCodeNotNullViolation:        client.CodeNotNullViolation,
+		ErrCodeCheckViolation:          client.CodeCheckViolation,
+		ErrCodeInternalError:           client.CodeInternalError,
+		ErrCodeDatabaseError:           client.CodeDatabaseError,
+		ErrCodeOperationFailed:         client.CodeOperationFailed,
+		ErrCodeRateLimited:             client.CodeRateLimited,
+		ErrCodeTooManyRequests:         client.CodeTooManyRequests,
+		ErrCodeSetupRequired:           client.CodeSetupRequired,
+		ErrCodeSetupCompleted:          client.CodeSetupCompleted,
+		ErrCodeSetupDisabled:           client.CodeSetupDisabled,
+		ErrCodeInvalidSetupToken:       client.CodeInvalidSetupToken,
+	}
+	for server, sdk := range codes {
+		assert.Equal(t, server, sdk)
+	}
+}
+
+// errorRequestCase is a request a handler must reject, and the response it must answer with
+type errorRequestCase struct {
+	name         string
+	method, path string
+	body         string
+	wantStatus   int
+	wantError    string
+}
+
+// assertErrorResponses sends each request to app and checks its status and error message
+func assertErrorResponses(t *testing.T, app *fiber.App, tests []errorRequestCase) {
+	t.Helper()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+
+			var body ErrorResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.Contains(t, body.Error, tt.wantError)
+		})
+	}
+}
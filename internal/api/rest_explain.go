@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/middleware"
+)
+
+// explainMode is the EXPLAIN variant requested with the "Prefer: explain=..." header
+type explainMode string
+
+const (
+	explainNone    explainMode = ""
+	explainPlan    explainMode = "plan"
+	explainAnalyze explainMode = "analyze"
+)
+
+// parseExplainPreference extracts the explain mode from a Prefer header.
+// Prefer may carry several comma-separated preferences, e.g. "count=exact, explain=plan".
+func parseExplainPreference(prefer string) (explainMode, error) {
+	for _, pref := range strings.Split(prefer, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(pref), "=")
+		if !found || strings.TrimSpace(key) != "explain" {
+			continue
+		}
+
+		switch mode := explainMode(strings.ToLower(strings.TrimSpace(value))); mode {
+		case explainPlan, explainAnalyze:
+			return mode, nil
+		default:
+			return explainNone, fmt.Errorf("invalid explain preference %q: must be 'plan' or 'analyze'", value)
+		}
+	}
+	return explainNone, nil
+}
+
+// canExplain checks if the request may ask for query plans.
+// Plans expose table structure and row estimates, so they are limited to admins and the service role.
+func canExplain(c fiber.Ctx) bool {
+	role, _ := c.Locals("user_role").(string)
+	return role == "admin" || role == "dashboard_admin" || role == "service_role"
+}
+
+// buildExplainStatement wraps a query in EXPLAIN for the given mode
+func buildExplainStatement(query string, mode explainMode) string {
+	if mode == explainAnalyze {
+		return "EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) " + query
+	}
+	return "EXPLAIN (FORMAT JSON) " + query
+}
+
+// handleExplain responds with the query plan when the request asks for one.
+// When handled is true the response has been written and err should be returned.
+func (h *RESTHandler) handleExplain(ctx context.Context, c fiber.Ctx, query string, args []interface{}) (bool, error) {
+	mode, err := parseExplainPreference(c.Get("Prefer"))
+	if err != nil {
+		return true, SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+	if mode == explainNone {
+		return false, nil
+	}
+	if !canExplain(c) {
+		return true, SendForbidden(c, "Query plans require an admin or service role", ErrCodeAdminRequired)
+	}
+	return true, h.sendExplain(ctx, c, query, args, mode)
+}
+
+// sendExplain runs EXPLAIN for a generated query under the caller's RLS context
+// and responds with the plan instead of the query results.
+func (h *RESTHandler) sendExplain(ctx context.Context, c fiber.Ctx, query string, args []interface{}, mode explainMode) error {
+	var plan []byte
+	err := middleware.WrapWithRLS(ctx, h.db, c, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, buildExplainStatement(query, mode), args...).Scan(&plan)
+	})
+	if err != nil {
+		return handleDatabaseError(c, err, "explain query")
+	}
+
+	c.Set("Preference-Applied", "explain="+string(mode))
+	return c.JSON(fiber.Map{
+		"explain": mode,
+		"query":   query,
+		"args":    args,
+		"plan":    json.RawMessage(plan),
+	})
+}
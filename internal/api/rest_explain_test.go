@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExplainPreference(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefer   string
+		expected explainMode
+		wantErr  bool
+	}{
+		{name: "empty header", prefer: "", expected: explainNone},
+		{name: "unrelated preferences", prefer: "return=representation, count=exact", expected: explainNone},
+		{name: "plan", prefer: "explain=plan", expected: explainPlan},
+		{name: "analyze with other preferences", prefer: "count=exact, explain=analyze", expected: explainAnalyze},
+		{name: "case insensitive value", prefer: "explain=ANALYZE", expected: explainAnalyze},
+		{name: "invalid value", prefer: "explain=verbose", wantErr: true},
+		{name: "empty value", prefer: "explain=", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode, err := parseExplainPreference(tt.prefer)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, mode)
+		})
+	}
+}
+
+func TestBuildExplainStatement(t *testing.T) {
+	query := `SELECT * FROM "public"."posts" WHERE "id" = $1`
+
+	assert.Equal(t, `EXPLAIN (FORMAT JSON) `+query, buildExplainStatement(query, explainPlan))
+	assert.Equal(t, `EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) `+query, buildExplainStatement(query, explainAnalyze))
+}
+
+func TestCanExplain(t *testing.T) {
+	tests := []struct {
+		role     interface{}
+		expected bool
+	}{
+		{role: "admin", expected: true},
+		{role: "dashboard_admin", expected: true},
+		{role: "service_role", expected: true},
+		{role: "authenticated", expected: false},
+		{role: "anon", expected: false},
+		{role: nil, expected: false},
+	}
+
+	for _, tt := range tests {
+		app := fiber.New()
+
+		var result bool
+		app.Get("/test", func(c fiber.Ctx) error {
+			if tt.role != nil {
+				c.Locals("user_role", tt.role)
+			}
+			result = canExplain(c)
+			return c.SendStatus(200)
+		})
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/test", nil))
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+
+		assert.Equal(t, tt.expected, result, "role %v", tt.role)
+	}
+}
+
+func TestRESTHandler_handleExplain(t *testing.T) {
+	h := &RESTHandler{}
+
+	newApp := func(role string) *fiber.App {
+		app := fiber.New()
+		app.Get("/test", func(c fiber.Ctx) error {
+			if role != "" {
+				c.Locals("user_role", role)
+			}
+			if handled, err := h.handleExplain(c.RequestCtx(), c, "SELECT 1", nil); handled {
+				return err
+			}
+			return c.SendString("rows")
+		})
+		return app
+	}
+
+	t.Run("passes through without explain preference", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Prefer", "count=exact")
+		resp, err := newApp("authenticated").Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+	})
+
+	t.Run("rejects invalid mode", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Prefer", "explain=verbose")
+		resp, err := newApp("service_role").Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, 400, resp.StatusCode)
+
+		var body ErrorResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, ErrCodeInvalidInput, body.Code)
+	})
+
+	t.Run("forbids non-admin roles", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Prefer", "explain=plan")
+		resp, err := newApp("authenticated").Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, 403, resp.StatusCode)
+
+		var body ErrorResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, ErrCodeAdminRequired, body.Code)
+	})
+}
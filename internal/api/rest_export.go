@@ -0,0 +1,199 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/middleware"
+	"github.com/rs/zerolog/log"
+)
+
+// exportView is the temporary view holding the query of an export. COPY doesn't accept bind
+// parameters, so the query is created as a view with pgx inlining the arguments (simple protocol)
+// and COPY reads from the view. The view is dropped with the transaction.
+const exportView = "pg_temp.fluxbase_table_export"
+
+// exportRangeUnit is the unit of Range requests on exports: rows of the export, counted from 0
+const exportRangeUnit = "rows"
+
+// exportUnsupportedParams are query parameters of list requests that exports reject; rows are
+// selected with the Range header instead of limit and offset
+var exportUnsupportedParams = []string{"limit", "offset", "cursor", "cursor_column", "count", "truncate", "group_by"}
+
+var errInvalidRowRange = errors.New("invalid Range header, expected rows=<first>-[<last>]")
+
+// rowRange is the part of an export selected with a "Range: rows=<first>-[<last>]" header
+type rowRange struct {
+	First int
+	Last  int // Inclusive, -1 for the rest of the rows
+}
+
+// parseRowRange parses a Range header of an export. It returns nil when the header is empty.
+func parseRowRange(header string) (*rowRange, error) {
+	if header == "" {
+		return nil, nil
+	}
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header), exportRangeUnit+"=")
+	if !ok || strings.Contains(spec, ",") {
+		return nil, errInvalidRowRange
+	}
+	firstStr, lastStr, ok := strings.Cut(spec, "-")
+	if !ok {
+		return nil, errInvalidRowRange
+	}
+
+	first, err := strconv.Atoi(strings.TrimSpace(firstStr))
+	if err != nil || first < 0 {
+		return nil, errInvalidRowRange
+	}
+	last := -1
+	if lastStr = strings.TrimSpace(lastStr); lastStr != "" {
+		last, err = strconv.Atoi(lastStr)
+		if err != nil || last < first {
+			return nil, errInvalidRowRange
+		}
+	}
+	return &rowRange{First: first, Last: last}, nil
+}
+
+// contentRange returns the Content-Range of a ranged export. The total is unknown while streaming.
+func (r *rowRange) contentRange() string {
+	if r.Last < 0 {
+		return fmt.Sprintf("%s %d-/*", exportRangeUnit, r.First)
+	}
+	return fmt.Sprintf("%s %d-%d/*", exportRangeUnit, r.First, r.Last)
+}
+
+// exportCopySQL returns the COPY statement streaming the export view as CSV
+func exportCopySQL(header bool) string {
+	return fmt.Sprintf("COPY (SELECT * FROM %s) TO STDOUT WITH (FORMAT csv, HEADER %t)", exportView, header)
+}
+
+// HandleTableExport streams the rows of a table as CSV using COPY TO STDOUT, with the caller's
+// filters, select and order applied under RLS. Rows are ordered by the primary key unless an
+// order is given, so a "Range: rows=<first>-" request resumes an interrupted export.
+// GET /api/v1/tables/:table/export?format=csv
+func (h *RESTHandler) HandleTableExport(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+	schema, tableName, accessErr := h.resolveTable(c)
+	if accessErr != nil {
+		return SendError(c, accessErr.status, accessErr.message)
+	}
+
+	tableInfo, exists, err := h.schemaCache.GetTable(ctx, schema, tableName)
+	if err != nil {
+		log.Error().Err(err).Str("schema", schema).Str("table", tableName).Msg("Failed to lookup table")
+		return SendErrorWithCode(c, 500, "Failed to lookup table metadata", ErrCodeOperationFailed)
+	}
+	if !exists {
+		return SendNotFound(c, fmt.Sprintf("Table '%s.%s' not found", schema, tableName))
+	}
+
+	if format := c.Query("format", "csv"); format != "csv" {
+		return SendBadRequest(c, fmt.Sprintf("Unsupported export format '%s', only csv is supported", format), ErrCodeInvalidFormat)
+	}
+
+	urlValues, err := url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return SendBadRequest(c, fmt.Sprintf("Invalid query string: %v", err), ErrCodeInvalidInput)
+	}
+	urlValues.Del("format")
+	for _, key := range exportUnsupportedParams {
+		if urlValues.Has(key) {
+			return SendBadRequest(c, fmt.Sprintf("Parameter '%s' is not supported by exports, use the Range header to export part of the rows", key), ErrCodeInvalidInput)
+		}
+	}
+
+	params, err := h.parser.ParseWithOptions(urlValues, ParseOptions{BypassMaxTotalResults: true})
+	if err != nil {
+		return SendBadRequest(c, fmt.Sprintf("Invalid query parameters: %v", err), ErrCodeInvalidInput)
+	}
+	if len(params.Embedded) > 0 || len(params.Aggregations) > 0 {
+		return SendBadRequest(c, "Exports cannot include embedded relations or aggregations", ErrCodeInvalidInput)
+	}
+	if err := coerceFilterValues(tableInfo, params.Filters); err != nil {
+		return respondFilterTypeError(c, err)
+	}
+	if err := checkDistanceOrders(tableInfo, params.Order); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	rows, err := parseRowRange(c.Get(fiber.HeaderRange))
+	if err != nil {
+		c.Set(fiber.HeaderContentRange, exportRangeUnit+" */*")
+		return SendErrorWithCode(c, fiber.StatusRequestedRangeNotSatisfiable, err.Error(), ErrCodeInvalidInput)
+	}
+	applyExportRange(params, tableInfo.PrimaryKey, rows)
+
+	query, args := h.buildSelectQuery(*tableInfo, params, nil)
+
+	tx, err := middleware.BeginWithRLS(ctx, h.db, c)
+	if err != nil {
+		log.Error().Err(err).Str("table", fmt.Sprintf("%s.%s", schema, tableName)).Msg("Failed to start table export")
+		return SendErrorWithCode(c, 500, "Failed to start export", ErrCodeOperationFailed)
+	}
+	// The query is checked here, so that errors are still reported with a status code
+	viewArgs := append([]any{pgx.QueryExecModeSimpleProtocol}, args...)
+	if _, err := tx.Exec(ctx, "CREATE TEMP VIEW "+exportView+" AS "+query, viewArgs...); err != nil {
+		_ = tx.Rollback(ctx)
+		return handleDatabaseError(c, err, "export records")
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"%s.csv\"", tableName))
+	c.Set(fiber.HeaderAcceptRanges, exportRangeUnit)
+	if rows != nil {
+		c.Set(fiber.HeaderContentRange, rows.contentRange())
+		c.Status(fiber.StatusPartialContent)
+	}
+
+	// The export outlives the handler: COPY writes into the pipe while the response is sent,
+	// and the transaction ends when it is done. The header row is only sent at the start.
+	reader, writer := io.Pipe()
+	go streamExport(tx, exportCopySQL(rows == nil || rows.First == 0), writer, schema+"."+tableName)
+	// SendStream closes the reader, which stops COPY if the client goes away
+	return c.SendStream(reader)
+}
+
+// applyExportRange orders the rows of an export by the primary key when no order is given, so
+// that row numbers are stable across requests, and limits them to the requested range
+func applyExportRange(params *QueryParams, primaryKey []string, rows *rowRange) {
+	params.Limit, params.Offset = nil, nil
+	if len(params.Order) == 0 {
+		for _, column := range primaryKey {
+			params.Order = append(params.Order, OrderBy{Column: column})
+		}
+	}
+	if rows == nil {
+		return
+	}
+	offset := rows.First
+	params.Offset = &offset
+	if rows.Last >= 0 {
+		limit := rows.Last - rows.First + 1
+		params.Limit = &limit
+	}
+}
+
+// streamExport copies the export view into w and ends the transaction. Once the response has
+// started, a failure can only cut the body short; clients resume with a Range request.
+func streamExport(tx pgx.Tx, copySQL string, w *io.PipeWriter, table string) {
+	ctx := context.Background()
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	tag, err := tx.Conn().PgConn().CopyTo(ctx, w, copySQL)
+	if err != nil {
+		log.Warn().Err(err).Str("table", table).Msg("Table export ended early")
+		_ = w.CloseWithError(err)
+		return
+	}
+	log.Debug().Str("table", table).Int64("rows", tag.RowsAffected()).Msg("Table export completed")
+	_ = w.Close()
+}
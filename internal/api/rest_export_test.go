@@ -0,0 +1,93 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRowRange(t *testing.T) {
+	t.Run("no header", func(t *testing.T) {
+		rows, err := parseRowRange("")
+		require.NoError(t, err)
+		assert.Nil(t, rows)
+	})
+
+	t.Run("open range", func(t *testing.T) {
+		rows, err := parseRowRange("rows=1000-")
+		require.NoError(t, err)
+		assert.Equal(t, &rowRange{First: 1000, Last: -1}, rows)
+		assert.Equal(t, "rows 1000-/*", rows.contentRange())
+	})
+
+	t.Run("closed range", func(t *testing.T) {
+		rows, err := parseRowRange("rows=0-99")
+		require.NoError(t, err)
+		assert.Equal(t, &rowRange{First: 0, Last: 99}, rows)
+		assert.Equal(t, "rows 0-99/*", rows.contentRange())
+	})
+
+	for _, header := range []string{"bytes=0-99", "rows=-10", "rows=10", "rows=10-5", "rows=0-9,20-29", "rows=a-"} {
+		t.Run("invalid "+header, func(t *testing.T) {
+			_, err := parseRowRange(header)
+			assert.ErrorIs(t, err, errInvalidRowRange)
+		})
+	}
+}
+
+func TestExportCopySQL(t *testing.T) {
+	assert.Equal(t,
+		"COPY (SELECT * FROM pg_temp.fluxbase_table_export) TO STDOUT WITH (FORMAT csv, HEADER true)",
+		exportCopySQL(true))
+	assert.Contains(t, exportCopySQL(false), "HEADER false")
+}
+
+func TestApplyExportRange(t *testing.T) {
+	handler := NewRESTHandler(nil, nil, nil, &config.Config{})
+	table := database.TableInfo{
+		Schema:     "public",
+		Name:       "orders",
+		PrimaryKey: []string{"id"},
+		Columns: []database.ColumnInfo{
+			{Name: "id", DataType: "bigint"},
+			{Name: "status", DataType: "text"},
+		},
+	}
+
+	t.Run("orders by primary key and drops the default page size", func(t *testing.T) {
+		limit := 1000
+		params := &QueryParams{Limit: &limit}
+		applyExportRange(params, table.PrimaryKey, nil)
+
+		query, _ := handler.buildSelectQuery(table, params, nil)
+		assert.Contains(t, query, `ORDER BY "id"`)
+		assert.NotContains(t, query, "LIMIT")
+		assert.NotContains(t, query, "OFFSET")
+	})
+
+	t.Run("keeps the requested order", func(t *testing.T) {
+		params := &QueryParams{Order: []OrderBy{{Column: "status", Desc: true}}}
+		applyExportRange(params, table.PrimaryKey, nil)
+		assert.Equal(t, []OrderBy{{Column: "status", Desc: true}}, params.Order)
+	})
+
+	t.Run("limits to the row range", func(t *testing.T) {
+		params := &QueryParams{}
+		applyExportRange(params, table.PrimaryKey, &rowRange{First: 100, Last: 199})
+		require.NotNil(t, params.Offset)
+		require.NotNil(t, params.Limit)
+		assert.Equal(t, 100, *params.Offset)
+		assert.Equal(t, 100, *params.Limit)
+	})
+
+	t.Run("open range has no limit", func(t *testing.T) {
+		params := &QueryParams{}
+		applyExportRange(params, table.PrimaryKey, &rowRange{First: 100, Last: -1})
+		require.NotNil(t, params.Offset)
+		assert.Equal(t, 100, *params.Offset)
+		assert.Nil(t, params.Limit)
+	})
+}
@@ -0,0 +1,232 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/database"
+)
+
+// FilterTypeError is returned for a filter value that doesn't match the type of its column
+type FilterTypeError struct {
+	Column       string
+	ExpectedType string
+	Value        string
+}
+
+func (e *FilterTypeError) Error() string {
+	return fmt.Sprintf("invalid value %q for column %s: expected %s", e.Value, e.Column, e.ExpectedType)
+}
+
+// filterValueTypes are the kinds of column a filter value is coerced to
+const (
+	filterTypeInteger   = "integer"
+	filterTypeNumber    = "number"
+	filterTypeBoolean   = "boolean"
+	filterTypeTimestamp = "timestamp"
+	filterTypeDate      = "date"
+	filterTypeUUID      = "uuid"
+)
+
+// timestampLayouts are the timestamp formats accepted in filters, tried in order
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// specialTimestamps are the PostgreSQL date/time inputs that are passed through unchanged
+var specialTimestamps = map[string]bool{
+	"epoch": true, "infinity": true, "-infinity": true,
+	"now": true, "today": true, "tomorrow": true, "yesterday": true,
+}
+
+// filterValueType returns the kind of value a column of a PostgreSQL type takes, or ""
+// for types whose filter values are bound as text
+func filterValueType(dataType string) string {
+	dt := strings.ToLower(dataType)
+	if strings.HasSuffix(dt, "[]") || dt == "array" {
+		return ""
+	}
+	switch {
+	case dt == "smallint" || dt == "integer" || dt == "bigint" || dt == "int2" || dt == "int4" || dt == "int8":
+		return filterTypeInteger
+	case dt == "real" || dt == "double precision" || dt == "float4" || dt == "float8" ||
+		dt == "numeric" || strings.HasPrefix(dt, "numeric(") || dt == "decimal" || strings.HasPrefix(dt, "decimal("):
+		return filterTypeNumber
+	case dt == "boolean" || dt == "bool":
+		return filterTypeBoolean
+	case strings.HasPrefix(dt, "timestamp"):
+		return filterTypeTimestamp
+	case dt == "date":
+		return filterTypeDate
+	case dt == "uuid":
+		return filterTypeUUID
+	}
+	return ""
+}
+
+// integerBits returns the size of a PostgreSQL integer type
+func integerBits(dataType string) int {
+	switch strings.ToLower(dataType) {
+	case "smallint", "int2":
+		return 16
+	case "integer", "int4":
+		return 32
+	}
+	return 64
+}
+
+// coerceFilterValue converts a filter value given as text to the Go type of its column
+func coerceFilterValue(col *database.ColumnInfo, kind, value string) (interface{}, error) {
+	typeErr := &FilterTypeError{Column: col.Name, ExpectedType: kind, Value: value}
+
+	switch kind {
+	case filterTypeInteger:
+		n, err := strconv.ParseInt(value, 10, integerBits(col.DataType))
+		if err != nil {
+			return nil, typeErr
+		}
+		return n, nil
+
+	case filterTypeNumber:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, typeErr
+		}
+		// numeric is bound as validated text so that no precision is lost
+		if strings.HasPrefix(strings.ToLower(col.DataType), "numeric") || strings.HasPrefix(strings.ToLower(col.DataType), "decimal") {
+			return value, nil
+		}
+		return f, nil
+
+	case filterTypeBoolean:
+		switch strings.ToLower(value) {
+		case "true", "t", "yes", "y", "on", "1":
+			return true, nil
+		case "false", "f", "no", "n", "off", "0":
+			return false, nil
+		}
+		return nil, typeErr
+
+	case filterTypeTimestamp, filterTypeDate:
+		if specialTimestamps[strings.ToLower(value)] {
+			return value, nil
+		}
+		for _, layout := range timestampLayouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, nil
+			}
+		}
+		return nil, typeErr
+
+	case filterTypeUUID:
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return nil, typeErr
+		}
+		return id, nil
+	}
+	return value, nil
+}
+
+// coerceFilterValues converts the values of filters on the table's integer, number, boolean,
+// timestamp, date and uuid columns to Go values of the column's type, so that they are bound
+// with the column's type instead of being cast from text by PostgreSQL. Filters on unknown
+// columns and JSONB paths, and operators that don't compare against the column's type, are
+// left unchanged. A value that doesn't parse returns a *FilterTypeError. Text search filters
+// are completed with the column's text search configuration.
+func coerceFilterValues(table *database.TableInfo, filters []Filter) error {
+	for i := range filters {
+		f := &filters[i]
+		col := table.GetColumn(f.Column)
+		if col == nil {
+			continue
+		}
+		if isTextSearchFilter(*f) {
+			applyTextSearchColumn(f, col)
+			continue
+		}
+		kind := filterValueType(col.DataType)
+		if kind == "" {
+			continue
+		}
+
+		switch f.Operator {
+		case OpEqual, OpNotEqual, OpGreaterThan, OpGreaterOrEqual, OpLessThan, OpLessOrEqual:
+			value, ok := f.Value.(string)
+			if !ok {
+				continue
+			}
+			coerced, err := coerceFilterValue(col, kind, value)
+			if err != nil {
+				return err
+			}
+			f.Value = coerced
+
+		case OpIn, OpNotIn:
+			values, ok := f.Value.([]string)
+			if !ok {
+				continue
+			}
+			coerced, err := coerceFilterArray(col, kind, values)
+			if err != nil {
+				return err
+			}
+			f.Value = coerced
+		}
+	}
+	return nil
+}
+
+// coerceFilterArray converts the values of an in filter to a slice of the column's type
+func coerceFilterArray(col *database.ColumnInfo, kind string, values []string) (interface{}, error) {
+	converted := make([]interface{}, len(values))
+	for i, value := range values {
+		v, err := coerceFilterValue(col, kind, strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+		converted[i] = v
+	}
+
+	// Bind a typed slice so that pgx encodes a PostgreSQL array of the column's type
+	switch kind {
+	case filterTypeInteger:
+		return typedSlice[int64](converted), nil
+	case filterTypeBoolean:
+		return typedSlice[bool](converted), nil
+	case filterTypeUUID:
+		return typedSlice[uuid.UUID](converted), nil
+	}
+	// Numbers, timestamps and dates may mix parsed values and text, so they stay text
+	return values, nil
+}
+
+func typedSlice[T any](values []interface{}) []T {
+	out := make([]T, len(values))
+	for i, v := range values {
+		out[i] = v.(T)
+	}
+	return out
+}
+
+// respondFilterTypeError writes a 422 response for a *FilterTypeError returned by coerceFilterValues
+func respondFilterTypeError(c fiber.Ctx, err error) error {
+	var typeErr *FilterTypeError
+	if !errors.As(err, &typeErr) {
+		return SendBadRequest(c, fmt.Sprintf("Invalid query parameters: %v", err), ErrCodeInvalidInput)
+	}
+	return SendErrorWithDetails(c, fiber.StatusUnprocessableEntity, typeErr.Error(), ErrCodeValidationFailed, "", "", fiber.Map{
+		"column":        typeErr.Column,
+		"expected_type": typeErr.ExpectedType,
+	})
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func filterTypesTable() database.TableInfo {
+	return database.TableInfo{
+		Schema:     "public",
+		Name:       "orders",
+		PrimaryKey: []string{"id"},
+		Columns: []database.ColumnInfo{
+			{Name: "id", DataType: "uuid"},
+			{Name: "quantity", DataType: "smallint"},
+			{Name: "customer_id", DataType: "bigint"},
+			{Name: "total", DataType: "numeric(10,2)"},
+			{Name: "weight", DataType: "double precision"},
+			{Name: "paid", DataType: "boolean"},
+			{Name: "created_at", DataType: "timestamp with time zone"},
+			{Name: "due_on", DataType: "date"},
+			{Name: "note", DataType: "text"},
+			{Name: "tags", DataType: "text[]"},
+			{Name: "data", DataType: "jsonb"},
+		},
+	}
+}
+
+func TestCoerceFilterValues(t *testing.T) {
+	id := uuid.New()
+	table := filterTypesTable()
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   interface{}
+	}{
+		{"integer", Filter{Column: "customer_id", Operator: OpEqual, Value: "42"}, int64(42)},
+		{"negative integer", Filter{Column: "quantity", Operator: OpGreaterThan, Value: "-3"}, int64(-3)},
+		{"numeric stays text", Filter{Column: "total", Operator: OpLessOrEqual, Value: "19.99"}, "19.99"},
+		{"float", Filter{Column: "weight", Operator: OpGreaterOrEqual, Value: "1.5"}, 1.5},
+		{"boolean", Filter{Column: "paid", Operator: OpEqual, Value: "true"}, true},
+		{"boolean alias", Filter{Column: "paid", Operator: OpNotEqual, Value: "off"}, false},
+		{"timestamp", Filter{Column: "created_at", Operator: OpGreaterThan, Value: "2025-01-02T03:04:05Z"}, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"timestamp with space", Filter{Column: "created_at", Operator: OpLessThan, Value: "2025-01-02 03:04:05"}, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"special timestamp", Filter{Column: "created_at", Operator: OpLessThan, Value: "now"}, "now"},
+		{"date", Filter{Column: "due_on", Operator: OpEqual, Value: "2025-06-30"}, time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)},
+		{"uuid", Filter{Column: "id", Operator: OpEqual, Value: id.String()}, id},
+		{"integer in", Filter{Column: "customer_id", Operator: OpIn, Value: []string{"1", " 2", "3"}}, []int64{1, 2, 3}},
+		{"uuid in", Filter{Column: "id", Operator: OpIn, Value: []string{id.String()}}, []uuid.UUID{id}},
+		{"timestamp in stays text", Filter{Column: "created_at", Operator: OpIn, Value: []string{"2025-01-01"}}, []string{"2025-01-01"}},
+		{"text unchanged", Filter{Column: "note", Operator: OpEqual, Value: "42"}, "42"},
+		{"array column unchanged", Filter{Column: "tags", Operator: OpContains, Value: "{a}"}, "{a}"},
+		{"jsonb path unchanged", Filter{Column: "data->>count", Operator: OpGreaterThan, Value: "abc"}, "abc"},
+		{"like unchanged", Filter{Column: "customer_id", Operator: OpLike, Value: "4%"}, "4%"},
+		{"is unchanged", Filter{Column: "paid", Operator: OpIs, Value: nil}, nil},
+		{"non-string unchanged", Filter{Column: "customer_id", Operator: OpEqual, Value: float64(7)}, float64(7)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters := []Filter{tt.filter}
+			require.NoError(t, coerceFilterValues(&table, filters))
+			assert.Equal(t, tt.want, filters[0].Value)
+		})
+	}
+}
+
+func TestCoerceFilterValues_Mismatch(t *testing.T) {
+	table := filterTypesTable()
+
+	tests := []struct {
+		name         string
+		filter       Filter
+		expectedType string
+	}{
+		{"integer", Filter{Column: "customer_id", Operator: OpEqual, Value: "abc"}, "integer"},
+		{"integer overflow", Filter{Column: "quantity", Operator: OpEqual, Value: "40000"}, "integer"},
+		{"number", Filter{Column: "total", Operator: OpGreaterThan, Value: "ten"}, "number"},
+		{"boolean", Filter{Column: "paid", Operator: OpEqual, Value: "maybe"}, "boolean"},
+		{"timestamp", Filter{Column: "created_at", Operator: OpGreaterThan, Value: "yesterday-ish"}, "timestamp"},
+		{"date", Filter{Column: "due_on", Operator: OpEqual, Value: "30/06/2025"}, "date"},
+		{"uuid", Filter{Column: "id", Operator: OpEqual, Value: "not-a-uuid"}, "uuid"},
+		{"in element", Filter{Column: "customer_id", Operator: OpIn, Value: []string{"1", "x"}}, "integer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := coerceFilterValues(&table, []Filter{tt.filter})
+			var typeErr *FilterTypeError
+			require.ErrorAs(t, err, &typeErr)
+			assert.Equal(t, tt.filter.Column, typeErr.Column)
+			assert.Equal(t, tt.expectedType, typeErr.ExpectedType)
+		})
+	}
+}
+
+func TestMakeGetHandler_FilterTypeMismatch(t *testing.T) {
+	app := fiber.New()
+	handler := &RESTHandler{parser: NewQueryParser(testConfig())}
+	app.Get("/orders", handler.makeGetHandler(filterTypesTable()))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/orders?customer_id=eq.abc", nil))
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+
+	var body ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, ErrCodeValidationFailed, body.Code)
+	details, ok := body.Details.(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "customer_id", details["column"])
+	assert.Equal(t, "integer", details["expected_type"])
+}
@@ -18,6 +18,10 @@ type RESTHandler struct {
 	parser      *QueryParser
 	schemaCache *database.SchemaCache
 	config      *config.Config
+	snapshots   *SnapshotManager
+	patterns    *queryPatternTracker
+	aggregates  *aggregateCache
+	statements  *statementCache
 }
 
 // NewRESTHandler creates a new REST handler
@@ -27,9 +31,18 @@ func NewRESTHandler(db *database.Connection, parser *QueryParser, schemaCache *d
 		parser:      parser,
 		schemaCache: schemaCache,
 		config:      cfg,
+		snapshots:   NewSnapshotManager(cfg.Auth.JWTSecret, cfg.API.SnapshotTTL, cfg.API.SnapshotMaxTTL, cfg.API.MaxOpenSnapshots),
+		patterns:    newQueryPatternTracker(),
+		aggregates:  newAggregateCache(cfg.API.AggregateCacheMaxEntries, cfg.API.AggregateCacheMaxStale),
+		statements:  newStatementCache(cfg.API.StatementCacheSize),
 	}
 }
 
+// Close releases the pagination snapshots held by this node
+func (h *RESTHandler) Close() {
+	h.snapshots.Close()
+}
+
 // SchemaCache returns the schema cache for external access (e.g., migrations handler)
 func (h *RESTHandler) SchemaCache() *database.SchemaCache {
 	return h.schemaCache
@@ -56,7 +69,10 @@ func (h *RESTHandler) parseTableFromPath(c fiber.Ctx) (schema, table string) {
 // Supports GET (list), POST (create), PATCH (batch update), DELETE (batch delete)
 func (h *RESTHandler) HandleDynamicTable(c fiber.Ctx) error {
 	ctx := c.RequestCtx()
-	schema, tableName := h.parseTableFromPath(c)
+	schema, tableName, accessErr := h.resolveTable(c)
+	if accessErr != nil {
+		return SendError(c, accessErr.status, accessErr.message)
+	}
 
 	// Debug logging for service_role troubleshooting
 	log.Debug().
@@ -73,24 +89,30 @@ func (h *RESTHandler) HandleDynamicTable(c fiber.Ctx) error {
 	tableInfo, exists, err := h.schemaCache.GetTable(ctx, schema, tableName)
 	if err != nil {
 		log.Error().Err(err).Str("schema", schema).Str("table", tableName).Msg("Failed to lookup table")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to lookup table metadata",
-		})
+		return SendErrorWithCode(c, 500, "Failed to lookup table metadata", ErrCodeOperationFailed)
 	}
 	if !exists {
-		return c.Status(404).JSON(fiber.Map{
-			"error": fmt.Sprintf("Table '%s.%s' not found", schema, tableName),
-		})
+		return SendNotFound(c, fmt.Sprintf("Table '%s.%s' not found", schema, tableName))
 	}
 
 	// Check if table is writable for write operations
 	isWritable, err := h.schemaCache.IsTableWritable(ctx, schema, tableName)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to check table permissions",
-		})
+		return SendErrorWithCode(c, 500, "Failed to check table permissions", ErrCodeOperationFailed)
 	}
 
+	// Drop cached aggregates of the table once a write succeeds
+	if c.Method() != "GET" && !strings.HasSuffix(c.Path(), "/query") {
+		defer h.invalidateAggregatesAfterWrite(c, schema, tableName)
+	}
+
+	// Read and write rows with camelCase keys when requested (Prefer: key-case=camel or the client key's setting)
+	convertResponseKeys, err := applyRequestKeyCase(c, tableInfo)
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+	defer convertResponseKeys()
+
 	// Dispatch based on HTTP method
 	switch c.Method() {
 	case "GET":
@@ -101,30 +123,22 @@ func (h *RESTHandler) HandleDynamicTable(c fiber.Ctx) error {
 		if strings.HasSuffix(c.Path(), "/query") {
 			return h.makePostQueryHandler(*tableInfo)(c)
 		}
-		if !isWritable {
-			return c.Status(405).JSON(fiber.Map{
-				"error": fmt.Sprintf("Table '%s.%s' is read-only (view or materialized view)", schema, tableName),
-			})
+		if reason := h.readOnlyReason(c, schema, tableName, isWritable); reason != "" {
+			return SendErrorWithCode(c, 405, reason, ErrCodeMethodNotAllowed)
 		}
 		return h.makePostHandler(*tableInfo)(c)
 	case "PATCH":
-		if !isWritable {
-			return c.Status(405).JSON(fiber.Map{
-				"error": fmt.Sprintf("Table '%s.%s' is read-only (view or materialized view)", schema, tableName),
-			})
+		if reason := h.readOnlyReason(c, schema, tableName, isWritable); reason != "" {
+			return SendErrorWithCode(c, 405, reason, ErrCodeMethodNotAllowed)
 		}
 		return h.makeBatchPatchHandler(*tableInfo)(c)
 	case "DELETE":
-		if !isWritable {
-			return c.Status(405).JSON(fiber.Map{
-				"error": fmt.Sprintf("Table '%s.%s' is read-only (view or materialized view)", schema, tableName),
-			})
+		if reason := h.readOnlyReason(c, schema, tableName, isWritable); reason != "" {
+			return SendErrorWithCode(c, 405, reason, ErrCodeMethodNotAllowed)
 		}
 		return h.makeBatchDeleteHandler(*tableInfo)(c)
 	default:
-		return c.Status(405).JSON(fiber.Map{
-			"error": fmt.Sprintf("Method %s not allowed", c.Method()),
-		})
+		return SendErrorWithCode(c, 405, fmt.Sprintf("Method %s not allowed", c.Method()), ErrCodeMethodNotAllowed)
 	}
 }
 
@@ -132,79 +146,79 @@ func (h *RESTHandler) HandleDynamicTable(c fiber.Ctx) error {
 // Supports GET (fetch), PUT (replace), PATCH (update), DELETE (remove)
 func (h *RESTHandler) HandleDynamicTableById(c fiber.Ctx) error {
 	ctx := c.RequestCtx()
-	schema, tableName := h.parseTableFromPath(c)
+	schema, tableName, accessErr := h.resolveTable(c)
+	if accessErr != nil {
+		return SendError(c, accessErr.status, accessErr.message)
+	}
 
 	// Look up table in cache
 	tableInfo, exists, err := h.schemaCache.GetTable(ctx, schema, tableName)
 	if err != nil {
 		log.Error().Err(err).Str("schema", schema).Str("table", tableName).Msg("Failed to lookup table")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to lookup table metadata",
-		})
+		return SendErrorWithCode(c, 500, "Failed to lookup table metadata", ErrCodeOperationFailed)
 	}
 	if !exists {
-		return c.Status(404).JSON(fiber.Map{
-			"error": fmt.Sprintf("Table '%s.%s' not found", schema, tableName),
-		})
+		return SendNotFound(c, fmt.Sprintf("Table '%s.%s' not found", schema, tableName))
 	}
 
 	// Check if table is writable for write operations
 	isWritable, err := h.schemaCache.IsTableWritable(ctx, schema, tableName)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to check table permissions",
-		})
+		return SendErrorWithCode(c, 500, "Failed to check table permissions", ErrCodeOperationFailed)
 	}
 
+	// Drop cached aggregates of the table once a write succeeds
+	if c.Method() != "GET" {
+		defer h.invalidateAggregatesAfterWrite(c, schema, tableName)
+	}
+
+	// Read and write rows with camelCase keys when requested (Prefer: key-case=camel or the client key's setting)
+	convertResponseKeys, err := applyRequestKeyCase(c, tableInfo)
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+	defer convertResponseKeys()
+
 	// Dispatch based on HTTP method
 	switch c.Method() {
 	case "GET":
 		return h.makeGetByIdHandler(*tableInfo)(c)
 	case "PUT":
-		if !isWritable {
-			return c.Status(405).JSON(fiber.Map{
-				"error": fmt.Sprintf("Table '%s.%s' is read-only (view or materialized view)", schema, tableName),
-			})
+		if reason := h.readOnlyReason(c, schema, tableName, isWritable); reason != "" {
+			return SendErrorWithCode(c, 405, reason, ErrCodeMethodNotAllowed)
 		}
 		return h.makePutHandler(*tableInfo)(c)
 	case "PATCH":
-		if !isWritable {
-			return c.Status(405).JSON(fiber.Map{
-				"error": fmt.Sprintf("Table '%s.%s' is read-only (view or materialized view)", schema, tableName),
-			})
+		if reason := h.readOnlyReason(c, schema, tableName, isWritable); reason != "" {
+			return SendErrorWithCode(c, 405, reason, ErrCodeMethodNotAllowed)
 		}
 		return h.makePatchHandler(*tableInfo)(c)
 	case "DELETE":
-		if !isWritable {
-			return c.Status(405).JSON(fiber.Map{
-				"error": fmt.Sprintf("Table '%s.%s' is read-only (view or materialized view)", schema, tableName),
-			})
+		if reason := h.readOnlyReason(c, schema, tableName, isWritable); reason != "" {
+			return SendErrorWithCode(c, 405, reason, ErrCodeMethodNotAllowed)
 		}
 		return h.makeDeleteHandler(*tableInfo)(c)
 	default:
-		return c.Status(405).JSON(fiber.Map{
-			"error": fmt.Sprintf("Method %s not allowed", c.Method()),
-		})
+		return SendErrorWithCode(c, 405, fmt.Sprintf("Method %s not allowed", c.Method()), ErrCodeMethodNotAllowed)
 	}
 }
 
 // HandleDynamicQuery handles POST-based query for complex filters
 func (h *RESTHandler) HandleDynamicQuery(c fiber.Ctx) error {
 	ctx := c.RequestCtx()
-	schema, tableName := h.parseTableFromPath(c)
+	schema, tableName, accessErr := h.resolveTable(c)
+	if accessErr != nil {
+		return SendError(c, accessErr.status, accessErr.message)
+	}
 
 	// Look up table in cache
 	tableInfo, exists, err := h.schemaCache.GetTable(ctx, schema, tableName)
 	if err != nil {
 		log.Error().Err(err).Str("schema", schema).Str("table", tableName).Msg("Failed to lookup table")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to lookup table metadata",
-		})
+		return SendErrorWithCode(c, 500, "Failed to lookup table metadata", ErrCodeOperationFailed)
 	}
 	if !exists {
-		return c.Status(404).JSON(fiber.Map{
-			"error": fmt.Sprintf("Table '%s.%s' not found", schema, tableName),
-		})
+		return SendNotFound(c, fmt.Sprintf("Table '%s.%s' not found", schema, tableName))
 	}
 
 	return h.makePostQueryHandler(*tableInfo)(c)
@@ -280,9 +294,7 @@ func (h *RESTHandler) HandleGetTables(c fiber.Ctx) error {
 	// Get all tables from cache
 	tables, err := h.schemaCache.GetAllTables(ctx)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to get tables",
-		})
+		return SendErrorWithCode(c, 500, "Failed to get tables", ErrCodeOperationFailed)
 	}
 
 	// Get all views from cache
@@ -320,13 +332,14 @@ func (h *RESTHandler) HandleGetTables(c fiber.Ctx) error {
 		}
 
 		response = append(response, fiber.Map{
-			"schema":      table.Schema,
-			"name":        table.Name,
-			"type":        table.Type,
-			"path":        h.BuildFullTablePath(table),
-			"columns":     table.Columns,
-			"primary_key": table.PrimaryKey,
-			"rls_enabled": table.RLSEnabled,
+			"schema":       table.Schema,
+			"name":         table.Name,
+			"type":         table.Type,
+			"path":         h.BuildFullTablePath(table),
+			"columns":      table.Columns,
+			"primary_key":  table.PrimaryKey,
+			"rls_enabled":  table.RLSEnabled,
+			"is_updatable": table.IsUpdatable,
 		})
 	}
 
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/rs/zerolog/log"
+)
+
+// parseKeyCasePreference extracts the key case from a Prefer header, e.g. "Prefer: key-case=camel".
+// It returns "" when the preference is absent.
+func parseKeyCasePreference(prefer string) (string, error) {
+	for _, pref := range strings.Split(prefer, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pref), "=")
+		if strings.TrimSpace(key) != "key-case" {
+			continue
+		}
+
+		switch keyCase := strings.ToLower(strings.TrimSpace(value)); keyCase {
+		case auth.JSONKeyCaseSnake, auth.JSONKeyCaseCamel:
+			return keyCase, nil
+		default:
+			return "", fmt.Errorf("invalid key-case preference %q: must be 'snake' or 'camel'", value)
+		}
+	}
+	return "", nil
+}
+
+// requestKeyCase returns the key case of table rows for a request: the Prefer header, then
+// the setting of the client key the request was made with, then snake_case column names
+func requestKeyCase(c fiber.Ctx) (string, error) {
+	keyCase, err := parseKeyCasePreference(c.Get("Prefer"))
+	if err != nil || keyCase != "" {
+		return keyCase, err
+	}
+	if keyCase, ok := c.Locals("json_key_case").(string); ok && keyCase != "" {
+		return keyCase, nil
+	}
+	return auth.JSONKeyCaseSnake, nil
+}
+
+// snakeToCamel converts a snake_case name to camelCase: "created_at" becomes "createdAt".
+// Leading underscores and names without underscores are kept.
+func snakeToCamel(name string) string {
+	trimmed := strings.TrimLeft(name, "_")
+	if !strings.Contains(trimmed, "_") {
+		return name
+	}
+
+	var b strings.Builder
+	b.Grow(len(name))
+	b.WriteString(name[:len(name)-len(trimmed)])
+	upper := false
+	for _, r := range trimmed {
+		switch {
+		case r == '_':
+			upper = true
+		case upper:
+			b.WriteString(strings.ToUpper(string(r)))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// camelizeRow renames the snake_case keys of a row to camelCase. Values, including JSONB
+// documents and embedded rows, are kept as they are. A key is kept when its camelCase name
+// is already a key of the row.
+func camelizeRow(row map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(row))
+	for key, value := range row {
+		camel := snakeToCamel(key)
+		if _, taken := row[camel]; taken && camel != key {
+			camel = key
+		}
+		out[camel] = value
+	}
+	return out
+}
+
+// decamelizeRow renames the camelCase keys of a written row to the table's snake_case
+// column names. Keys that are column names, or match no column, are kept.
+func decamelizeRow(row map[string]interface{}, columns map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(row))
+	for key, value := range row {
+		if column, ok := columns[key]; ok {
+			if _, taken := row[column]; !taken {
+				key = column
+			}
+		}
+		out[key] = value
+	}
+	return out
+}
+
+// camelColumns maps the camelCase names of a table's snake_case columns to the column names
+func camelColumns(table *database.TableInfo) map[string]string {
+	columns := make(map[string]string, len(table.Columns))
+	for _, col := range table.Columns {
+		if camel := snakeToCamel(col.Name); camel != col.Name && !table.HasColumn(camel) {
+			columns[camel] = col.Name
+		}
+	}
+	return columns
+}
+
+// transformRows decodes a JSON object or array of objects, renames the keys of each object
+// and encodes it again. Other JSON values are returned unchanged.
+func transformRows(body []byte, rename func(map[string]interface{}) map[string]interface{}) ([]byte, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return body, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		doc = rename(v)
+	case []interface{}:
+		for i, item := range v {
+			if row, ok := item.(map[string]interface{}); ok {
+				v[i] = rename(row)
+			}
+		}
+	}
+	return json.Marshal(doc)
+}
+
+// jsonMediaType returns the lowercase media type of a Content-Type header without parameters
+func jsonMediaType(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+// applyRequestKeyCase prepares a table request for its key case. In camelCase mode the keys
+// of a JSON body are renamed to column names, and the returned function renames the keys of
+// the rows in a successful JSON response to camelCase; it must be called after the handler.
+func applyRequestKeyCase(c fiber.Ctx, table *database.TableInfo) (func(), error) {
+	keyCase, err := requestKeyCase(c)
+	if err != nil || keyCase != auth.JSONKeyCaseCamel {
+		return func() {}, err
+	}
+
+	// JSON Patch documents address columns in their paths, and POST /query bodies describe
+	// the query rather than rows, so both are passed through unchanged
+	contentType := jsonMediaType(c.Get(fiber.HeaderContentType))
+	body := c.Body()
+	isRowBody := contentType == fiber.MIMEApplicationJSON || contentType == mergePatchContentType
+	if len(body) > 0 && isRowBody && !strings.HasSuffix(c.Path(), "/query") {
+		columns := camelColumns(table)
+		converted, err := transformRows(body, func(row map[string]interface{}) map[string]interface{} {
+			return decamelizeRow(row, columns)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid request body: %w", err)
+		}
+		c.Request().SetBody(converted)
+		// The converted body is no longer compressed
+		c.Request().Header.Del(fiber.HeaderContentEncoding)
+	}
+
+	return func() {
+		resp := c.Response()
+		if resp.StatusCode() < 200 || resp.StatusCode() >= 300 ||
+			jsonMediaType(string(resp.Header.ContentType())) != fiber.MIMEApplicationJSON {
+			return
+		}
+		converted, err := transformRows(resp.Body(), camelizeRow)
+		if err != nil {
+			log.Warn().Err(err).Str("path", c.Path()).Msg("Failed to convert response keys to camelCase")
+			return
+		}
+		resp.SetBody(converted)
+	}, nil
+}
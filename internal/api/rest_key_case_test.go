@@ -0,0 +1,166 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeyCasePreference(t *testing.T) {
+	tests := []struct {
+		prefer  string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"return=representation", "", false},
+		{"key-case=camel", "camel", false},
+		{"return=representation, key-case=Snake", "snake", false},
+		{"key-case=kebab", "", true},
+		{"key-case", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefer, func(t *testing.T) {
+			got, err := parseKeyCasePreference(tt.prefer)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSnakeToCamel(t *testing.T) {
+	for input, want := range map[string]string{
+		"id":              "id",
+		"created_at":      "createdAt",
+		"user_id2":        "userId2",
+		"last_sign_in_ip": "lastSignInIp",
+		"_internal_flag":  "_internalFlag",
+		"double__score":   "doubleScore",
+		"alreadyCamel":    "alreadyCamel",
+	} {
+		assert.Equal(t, want, snakeToCamel(input), input)
+	}
+}
+
+func TestCamelizeRow(t *testing.T) {
+	row := map[string]interface{}{
+		"id":         1,
+		"created_at": "2026-01-01",
+		"metadata":   map[string]interface{}{"source_ip": "10.0.0.1"},
+		"user_id":    "a",
+		"userId":     "b",
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"id":        1,
+		"createdAt": "2026-01-01",
+		"metadata":  map[string]interface{}{"source_ip": "10.0.0.1"},
+		"user_id":   "a",
+		"userId":    "b",
+	}, camelizeRow(row))
+}
+
+func TestDecamelizeRow(t *testing.T) {
+	table := database.TableInfo{Columns: []database.ColumnInfo{
+		{Name: "id"}, {Name: "created_at"}, {Name: "display_name"},
+	}}
+	columns := camelColumns(&table)
+
+	assert.Equal(t, map[string]interface{}{
+		"created_at":   "2026-01-01",
+		"display_name": "Ada",
+		"unknownKey":   true,
+	}, decamelizeRow(map[string]interface{}{
+		"createdAt":    "2026-01-01",
+		"display_name": "Ada",
+		"unknownKey":   true,
+	}, columns))
+}
+
+func TestTransformRows(t *testing.T) {
+	out, err := transformRows([]byte(`[{"big_number":12345678901234567890,"nested_obj":{"inner_key":1}},"x"]`), camelizeRow)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"bigNumber":12345678901234567890,"nestedObj":{"inner_key":1}},"x"]`, string(out))
+
+	out, err = transformRows([]byte(`42`), camelizeRow)
+	require.NoError(t, err)
+	assert.Equal(t, `42`, string(out))
+
+	_, err = transformRows([]byte(`{"broken":`), camelizeRow)
+	assert.Error(t, err)
+}
+
+func TestApplyRequestKeyCase(t *testing.T) {
+	table := database.TableInfo{Columns: []database.ColumnInfo{{Name: "id"}, {Name: "display_name"}}}
+
+	newApp := func(clientKeyCase string) *fiber.App {
+		app := fiber.New()
+		app.Post("/tables/users", func(c fiber.Ctx) error {
+			if clientKeyCase != "" {
+				c.Locals("json_key_case", clientKeyCase)
+			}
+			convert, err := applyRequestKeyCase(c, &table)
+			if err != nil {
+				return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+			}
+			defer convert()
+			// Report the body the table handler sees
+			return c.Status(201).JSON(fiber.Map{"received_body": string(c.Body())})
+		})
+		return app
+	}
+
+	send := func(app *fiber.App, prefer, body string) (int, string) {
+		req := httptest.NewRequest("POST", "/tables/users", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		if prefer != "" {
+			req.Header.Set("Prefer", prefer)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		respBody, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(respBody)
+	}
+
+	t.Run("snake by default", func(t *testing.T) {
+		status, body := send(newApp(""), "", `{"displayName":"Ada"}`)
+		assert.Equal(t, 201, status)
+		assert.JSONEq(t, `{"received_body":"{\"displayName\":\"Ada\"}"}`, body)
+	})
+
+	t.Run("camel from prefer header", func(t *testing.T) {
+		status, body := send(newApp(""), "key-case=camel", `[{"displayName":"Ada"}]`)
+		assert.Equal(t, 201, status)
+		assert.JSONEq(t, `{"receivedBody":"[{\"display_name\":\"Ada\"}]"}`, body)
+	})
+
+	t.Run("camel from client key", func(t *testing.T) {
+		status, body := send(newApp("camel"), "", `{"displayName":"Ada"}`)
+		assert.Equal(t, 201, status)
+		assert.JSONEq(t, `{"receivedBody":"{\"display_name\":\"Ada\"}"}`, body)
+	})
+
+	t.Run("prefer header overrides client key", func(t *testing.T) {
+		status, body := send(newApp("camel"), "key-case=snake", `{"displayName":"Ada"}`)
+		assert.Equal(t, 201, status)
+		assert.JSONEq(t, `{"received_body":"{\"displayName\":\"Ada\"}"}`, body)
+	})
+
+	t.Run("invalid preference", func(t *testing.T) {
+		status, _ := send(newApp(""), "key-case=upper", `{}`)
+		assert.Equal(t, 400, status)
+	})
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/nimbleflux/fluxbase/internal/middleware"
+	"github.com/rs/zerolog/log"
+)
+
+// MaterializedViewRefreshResponse is the result of refreshing a materialized view
+type MaterializedViewRefreshResponse struct {
+	Schema       string    `json:"schema"`
+	Name         string    `json:"name"`
+	Concurrently bool      `json:"concurrently"`
+	RefreshedAt  time.Time `json:"refreshed_at"`
+	DurationMs   int64     `json:"duration_ms"`
+}
+
+// refreshMaterializedViewSQL builds the REFRESH statement of a materialized view
+func refreshMaterializedViewSQL(schema, name string, concurrently bool) string {
+	mode := ""
+	if concurrently {
+		mode = "CONCURRENTLY "
+	}
+	return fmt.Sprintf("REFRESH MATERIALIZED VIEW %s%s.%s", mode, quoteIdentifier(schema), quoteIdentifier(name))
+}
+
+// HandleRefreshMaterializedView re-runs the query of a materialized view on demand:
+// POST /tables/:schema/:table/refresh and /tables/:table/refresh. With ?concurrently=true the
+// view stays readable during the refresh, which needs a unique index on it.
+//
+// The refresh runs as the requesting role, so it must own the view or hold the MAINTAIN
+// privilege on it.
+func (h *RESTHandler) HandleRefreshMaterializedView(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+	schema, tableName, accessErr := h.resolveTable(c)
+	if accessErr != nil {
+		return SendError(c, accessErr.status, accessErr.message)
+	}
+
+	tableInfo, exists, err := h.schemaCache.GetTable(ctx, schema, tableName)
+	if err != nil {
+		log.Error().Err(err).Str("schema", schema).Str("table", tableName).Msg("Failed to lookup table")
+		return SendErrorWithCode(c, 500, "Failed to lookup table metadata", ErrCodeOperationFailed)
+	}
+	if !exists {
+		return SendNotFound(c, fmt.Sprintf("Table '%s.%s' not found", schema, tableName))
+	}
+	if tableInfo.Type != "materialized_view" {
+		return SendBadRequest(c, fmt.Sprintf("Table '%s.%s' is not a materialized view", schema, tableName), ErrCodeInvalidInput)
+	}
+
+	concurrently := false
+	if v := c.Query("concurrently"); v != "" {
+		if concurrently, err = strconv.ParseBool(v); err != nil {
+			return SendBadRequest(c, fmt.Sprintf("Invalid concurrently parameter %q, expected true or false", v), ErrCodeInvalidInput)
+		}
+	}
+
+	// Cached aggregates of the view are outdated once the refresh succeeds
+	defer h.invalidateAggregatesAfterWrite(c, schema, tableName)
+
+	tx, err := middleware.BeginWithRLS(ctx, h.db, c)
+	if err != nil {
+		log.Error().Err(err).Str("table", schema+"."+tableName).Msg("Failed to start materialized view refresh")
+		return SendErrorWithCode(c, 500, "Failed to refresh materialized view", ErrCodeOperationFailed)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	start := time.Now()
+	if _, err := tx.Exec(ctx, refreshMaterializedViewSQL(schema, tableName, concurrently)); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "42501": // insufficient_privilege
+				return SendForbidden(c, fmt.Sprintf("Refreshing '%s.%s' requires owning it or the MAINTAIN privilege", schema, tableName), ErrCodeInsufficientPermissions)
+			case "55000": // object_not_in_prerequisite_state, e.g. a concurrent refresh without a unique index
+				return SendBadRequest(c, pgErr.Message, ErrCodeInvalidInput)
+			}
+		}
+		return handleDatabaseError(c, err, "refresh materialized view")
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return handleDatabaseError(c, err, "refresh materialized view")
+	}
+
+	return c.JSON(MaterializedViewRefreshResponse{
+		Schema:       schema,
+		Name:         tableName,
+		Concurrently: concurrently,
+		RefreshedAt:  time.Now().UTC(),
+		DurationMs:   time.Since(start).Milliseconds(),
+	})
+}
@@ -0,0 +1,12 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshMaterializedViewSQL(t *testing.T) {
+	assert.Equal(t, `REFRESH MATERIALIZED VIEW "public"."daily_totals"`, refreshMaterializedViewSQL("public", "daily_totals", false))
+	assert.Equal(t, `REFRESH MATERIALIZED VIEW CONCURRENTLY "analytics"."Totals"`, refreshMaterializedViewSQL("analytics", "Totals", true))
+}
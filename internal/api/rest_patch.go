@@ -0,0 +1,596 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/nimbleflux/fluxbase/internal/middleware"
+	"github.com/rs/zerolog/log"
+)
+
+// Patch media types accepted on PATCH /tables/{table}/{id}. Any other content type
+// updates the columns present in the body, like PUT.
+const (
+	mergePatchContentType = "application/merge-patch+json" // RFC 7386
+	jsonPatchContentType  = "application/json-patch+json"  // RFC 6902
+)
+
+var (
+	// errPatchInvalid means the patch document itself is malformed (400)
+	errPatchInvalid = errors.New("invalid patch document")
+	// errPatchUnprocessable means the patch is well-formed but cannot be applied to the row (422)
+	errPatchUnprocessable = errors.New("patch cannot be applied")
+	// errPatchTestFailed means a JSON Patch "test" operation did not match (409)
+	errPatchTestFailed = errors.New("patch test failed")
+)
+
+// patchMediaType returns the patch media type of a request, or "" for a plain JSON body
+func patchMediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	switch mediaType {
+	case mergePatchContentType, jsonPatchContentType:
+		return mediaType
+	}
+	return ""
+}
+
+// decodePatchJSON decodes JSON keeping numbers as json.Number so values of nested JSONB
+// documents round-trip without losing precision
+func decodePatchJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return v, nil
+}
+
+// applyMergePatch applies an RFC 7386 merge patch to target and returns the result.
+// Objects are merged recursively, null removes a member and any other value replaces it.
+func applyMergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = applyMergePatch(targetObj[key], value)
+	}
+	return targetObj
+}
+
+// jsonPatchOperation is a single RFC 6902 operation
+type jsonPatchOperation struct {
+	Op    string           `json:"op"`
+	Path  *string          `json:"path"`
+	From  *string          `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+// parseJSONPatch parses and validates an RFC 6902 patch document
+func parseJSONPatch(data []byte) ([]jsonPatchOperation, error) {
+	var ops []jsonPatchOperation
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return nil, fmt.Errorf("%w: a JSON Patch must be an array of operations", errPatchInvalid)
+	}
+
+	for i, op := range ops {
+		if op.Path == nil {
+			return nil, fmt.Errorf("%w: operation %d is missing \"path\"", errPatchInvalid, i)
+		}
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value == nil {
+				return nil, fmt.Errorf("%w: %s operation %d is missing \"value\"", errPatchInvalid, op.Op, i)
+			}
+		case "move", "copy":
+			if op.From == nil {
+				return nil, fmt.Errorf("%w: %s operation %d is missing \"from\"", errPatchInvalid, op.Op, i)
+			}
+		case "remove":
+		default:
+			return nil, fmt.Errorf("%w: unknown operation %q", errPatchInvalid, op.Op)
+		}
+	}
+	return ops, nil
+}
+
+// parseJSONPointer splits an RFC 6901 pointer into its unescaped reference tokens
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: JSON pointer %q must start with \"/\"", errPatchInvalid, pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// applyJSONPatch applies RFC 6902 operations to doc in order. The operations are atomic: on
+// error the returned document must be discarded.
+func applyJSONPatch(doc interface{}, ops []jsonPatchOperation) (interface{}, error) {
+	for _, op := range ops {
+		path, err := parseJSONPointer(*op.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		var value interface{}
+		if op.Value != nil {
+			if value, err = decodePatchJSON(*op.Value); err != nil {
+				return nil, fmt.Errorf("%w: invalid value at %q", errPatchInvalid, *op.Path)
+			}
+		}
+
+		switch op.Op {
+		case "add":
+			doc, err = jsonPointerAdd(doc, path, value)
+		case "remove":
+			doc, _, err = jsonPointerRemove(doc, path)
+		case "replace":
+			if doc, _, err = jsonPointerRemove(doc, path); err == nil {
+				doc, err = jsonPointerAdd(doc, path, value)
+			}
+		case "move", "copy":
+			var from []string
+			if from, err = parseJSONPointer(*op.From); err != nil {
+				return nil, err
+			}
+			if op.Op == "move" {
+				if len(path) > len(from) && reflect.DeepEqual(path[:len(from)], from) {
+					return nil, fmt.Errorf("%w: cannot move %q into one of its children", errPatchInvalid, *op.From)
+				}
+				doc, value, err = jsonPointerRemove(doc, from)
+			} else {
+				value, err = jsonPointerGet(doc, from)
+				value = deepCopyJSON(value)
+			}
+			if err == nil {
+				doc, err = jsonPointerAdd(doc, path, value)
+			}
+		case "test":
+			var current interface{}
+			if current, err = jsonPointerGet(doc, path); err == nil && !jsonEqual(current, value) {
+				err = fmt.Errorf("%w: value at %q does not match", errPatchTestFailed, *op.Path)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// jsonPointerGet returns the value a pointer references
+func jsonPointerGet(doc interface{}, path []string) (interface{}, error) {
+	for i, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, pathNotFound(path[:i+1])
+			}
+			doc = value
+		case []interface{}:
+			idx, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[idx]
+		default:
+			return nil, pathNotFound(path[:i+1])
+		}
+	}
+	return doc, nil
+}
+
+// jsonPointerAdd adds value at path: it sets an object member, or inserts into an array
+// ("-" appends). The root is replaced when path is empty.
+func jsonPointerAdd(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := jsonPointerGet(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[token] = value
+		return doc, nil
+	case []interface{}:
+		idx := len(node)
+		if token != "-" {
+			if idx, err = arrayIndex(token, len(node)); err != nil {
+				return nil, err
+			}
+		}
+		node = append(node, nil)
+		copy(node[idx+1:], node[idx:])
+		node[idx] = value
+		return setJSONPointer(doc, path[:len(path)-1], node)
+	default:
+		return nil, pathNotFound(path)
+	}
+}
+
+// jsonPointerRemove removes the value at path and returns the updated document and the
+// removed value
+func jsonPointerRemove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+
+	parent, err := jsonPointerGet(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	token := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		value, ok := node[token]
+		if !ok {
+			return nil, nil, pathNotFound(path)
+		}
+		delete(node, token)
+		return doc, value, nil
+	case []interface{}:
+		idx, err := arrayIndex(token, len(node)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		value := node[idx]
+		node = append(node[:idx:idx], node[idx+1:]...)
+		doc, err = setJSONPointer(doc, path[:len(path)-1], node)
+		return doc, value, err
+	default:
+		return nil, nil, pathNotFound(path)
+	}
+}
+
+// setJSONPointer replaces the value at an existing path. Arrays change identity when they
+// grow or shrink, so their parent has to be updated.
+func setJSONPointer(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := jsonPointerGet(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[token] = value
+	case []interface{}:
+		idx, err := arrayIndex(token, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+		node[idx] = value
+	}
+	return doc, nil
+}
+
+// arrayIndex parses an array index token, which must be in [0, limit]
+func arrayIndex(token string, limit int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.TrimLeft(token, "0123456789") != "" {
+		return 0, fmt.Errorf("%w: invalid array index %q", errPatchUnprocessable, token)
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx > limit {
+		return 0, fmt.Errorf("%w: array index %s is out of range", errPatchUnprocessable, token)
+	}
+	return idx, nil
+}
+
+func pathNotFound(path []string) error {
+	escaped := make([]string, len(path))
+	for i, token := range path {
+		escaped[i] = strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+	}
+	return fmt.Errorf("%w: path \"/%s\" does not exist", errPatchUnprocessable, strings.Join(escaped, "/"))
+}
+
+// deepCopyJSON copies decoded JSON so a copied value can be patched independently
+func deepCopyJSON(v interface{}) interface{} {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(node))
+		for k, val := range node {
+			out[k] = deepCopyJSON(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(node))
+		for i, val := range node {
+			out[i] = deepCopyJSON(val)
+		}
+		return out
+	}
+	return v
+}
+
+// jsonEqual compares decoded JSON values, treating numbers by value (1 == 1.0)
+func jsonEqual(a, b interface{}) bool {
+	an, aok := a.(json.Number)
+	bn, bok := b.(json.Number)
+	if aok && bok {
+		if an == bn {
+			return true
+		}
+		af, aerr := an.Float64()
+		bf, berr := bn.Float64()
+		return aerr == nil && berr == nil && af == bf
+	}
+
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, val := range av {
+			other, ok := bv[k]
+			if !ok || !jsonEqual(val, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !jsonEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// patchDocument applies a merge patch or JSON Patch body to a row
+func patchDocument(mediaType string, row map[string]interface{}, body []byte) (map[string]interface{}, error) {
+	var patched interface{}
+	switch mediaType {
+	case mergePatchContentType:
+		patch, err := decodePatchJSON(body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errPatchInvalid, err)
+		}
+		if _, ok := patch.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("%w: a merge patch for a row must be a JSON object", errPatchInvalid)
+		}
+		patched = applyMergePatch(row, patch)
+	case jsonPatchContentType:
+		ops, err := parseJSONPatch(body)
+		if err != nil {
+			return nil, err
+		}
+		if patched, err = applyJSONPatch(row, ops); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported media type %s", errPatchInvalid, mediaType)
+	}
+
+	result, ok := patched.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: the patched row must remain a JSON object", errPatchUnprocessable)
+	}
+	return result, nil
+}
+
+// changedColumns returns the columns whose value differs between the stored row and the
+// patched one in a stable order. Removed columns are set to NULL.
+func changedColumns(before, after map[string]interface{}) ([]string, map[string]interface{}) {
+	values := make(map[string]interface{})
+	for col, val := range after {
+		if old, ok := before[col]; !ok || !jsonEqual(old, val) {
+			values[col] = columnValue(val)
+		}
+	}
+	for col := range before {
+		if _, ok := after[col]; !ok {
+			values[col] = nil
+		}
+	}
+
+	columns := make([]string, 0, len(values))
+	for col := range values {
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+	return columns, values
+}
+
+// columnValue converts a top-level json.Number to a Go number pgx can encode. Nested
+// numbers stay json.Number and are marshaled verbatim into JSONB.
+func columnValue(v interface{}) interface{} {
+	n, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if f, err := n.Float64(); err == nil {
+		return f
+	}
+	return n.String()
+}
+
+// patchErrorStatus maps a patch error to its HTTP status
+func patchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errPatchTestFailed):
+		return fiber.StatusConflict
+	case errors.Is(err, errPatchUnprocessable):
+		return fiber.StatusUnprocessableEntity
+	default:
+		return fiber.StatusBadRequest
+	}
+}
+
+// handleRowPatch applies a merge patch or JSON Patch to a single row. The row is locked
+// while the patch is applied, so concurrent patches to different keys of the same JSONB
+// document do not overwrite each other.
+func (h *RESTHandler) handleRowPatch(c fiber.Ctx, table database.TableInfo, mediaType string) error {
+	ctx := c.RequestCtx()
+	id := c.Params("id")
+	body := c.Body()
+
+	// Validate the patch document before touching the database
+	switch mediaType {
+	case mergePatchContentType:
+		if _, err := patchDocument(mediaType, map[string]interface{}{}, body); errors.Is(err, errPatchInvalid) {
+			return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+		}
+	case jsonPatchContentType:
+		if _, err := parseJSONPatch(body); err != nil {
+			return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+		}
+	}
+
+	// Determine primary key column
+	pkColumn := "id"
+	if len(table.PrimaryKey) > 0 {
+		pkColumn = table.PrimaryKey[0]
+	}
+
+	selectQuery := fmt.Sprintf(
+		`SELECT to_jsonb(t) FROM "%s"."%s" AS t WHERE t.%s = $1 FOR UPDATE`,
+		table.Schema, table.Name, quoteIdentifier(pkColumn),
+	)
+
+	var results []map[string]interface{}
+	var patchErr error
+	var columnErr string
+	err := middleware.WrapWithRLS(ctx, h.db, c, func(tx pgx.Tx) error {
+		var stored []byte
+		if err := tx.QueryRow(ctx, selectQuery, id).Scan(&stored); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
+			}
+			log.Error().Err(err).Str("query", selectQuery).Msg("Failed to lock record for patch")
+			return err
+		}
+
+		decoded, err := decodePatchJSON(stored)
+		if err != nil {
+			return err
+		}
+		before := decoded.(map[string]interface{})
+		after, err := patchDocument(mediaType, deepCopyJSON(before).(map[string]interface{}), body)
+		if err != nil {
+			patchErr = err
+			return nil
+		}
+
+		columns, values := changedColumns(before, after)
+		setClauses := make([]string, 0, len(columns))
+		args := make([]interface{}, 0, len(columns)+1)
+		for _, col := range columns {
+			// Skip primary key in update
+			if col == pkColumn {
+				continue
+			}
+			if msg := h.columnWriteError(table, col); msg != "" {
+				columnErr = msg
+				return nil
+			}
+
+			quotedCol := quoteIdentifier(col)
+			val := values[col]
+			if isGeoJSON(val) {
+				geoJSON, err := json.Marshal(val)
+				if err != nil {
+					columnErr = fmt.Sprintf("Invalid GeoJSON for column %s: %v", col, err)
+					return nil
+				}
+				setClauses = append(setClauses, fmt.Sprintf("%s = ST_GeomFromGeoJSON($%d)", quotedCol, len(args)+1))
+				args = append(args, string(geoJSON))
+			} else {
+				setClauses = append(setClauses, fmt.Sprintf("%s = $%d", quotedCol, len(args)+1))
+				args = append(args, val)
+			}
+		}
+		args = append(args, id)
+
+		var query string
+		if len(setClauses) == 0 {
+			// Nothing changed: return the row as it is
+			query = fmt.Sprintf(`SELECT %s FROM "%s"."%s" WHERE %s = $1`,
+				buildSelectColumns(table), table.Schema, table.Name, quoteIdentifier(pkColumn))
+		} else {
+			query = fmt.Sprintf(
+				`UPDATE "%s"."%s" SET %s WHERE %s = $%d`,
+				table.Schema, table.Name,
+				strings.Join(setClauses, ", "),
+				quoteIdentifier(pkColumn), len(args),
+			) + buildReturningClause(table)
+		}
+
+		rows, err := tx.Query(ctx, query, args...)
+		if err != nil {
+			log.Error().Err(err).Str("query", query).Msg("Failed to patch record")
+			return err
+		}
+		defer rows.Close()
+
+		results, err = pgxRowsToJSON(rows)
+		return err
+	})
+	if err != nil {
+		return handleDatabaseError(c, err, "update record")
+	}
+	if patchErr != nil {
+		return SendError(c, patchErrorStatus(patchErr), patchErr.Error())
+	}
+	if columnErr != "" {
+		return SendBadRequest(c, columnErr, ErrCodeInvalidInput)
+	}
+
+	if len(results) == 0 {
+		// The row either does not exist or is hidden by RLS
+		return h.handleRLSViolation(c, "UPDATE", fmt.Sprintf("%s.%s", table.Schema, table.Name))
+	}
+
+	return c.JSON(results[0])
+}
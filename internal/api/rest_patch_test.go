@@ -0,0 +1,234 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustDecodePatchJSON(t *testing.T, s string) interface{} {
+	t.Helper()
+	v, err := decodePatchJSON([]byte(s))
+	require.NoError(t, err)
+	return v
+}
+
+func TestPatchMediaType(t *testing.T) {
+	assert.Equal(t, mergePatchContentType, patchMediaType("application/merge-patch+json"))
+	assert.Equal(t, jsonPatchContentType, patchMediaType("application/json-patch+json; charset=utf-8"))
+	assert.Equal(t, "", patchMediaType("application/json"))
+	assert.Equal(t, "", patchMediaType(""))
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	// Examples from RFC 7386, appendix A
+	tests := []struct {
+		target, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.patch, func(t *testing.T) {
+			got := applyMergePatch(mustDecodePatchJSON(t, tt.target), mustDecodePatchJSON(t, tt.patch))
+			assert.True(t, jsonEqual(mustDecodePatchJSON(t, tt.want), got), "got %v", got)
+		})
+	}
+}
+
+func TestParseJSONPointer(t *testing.T) {
+	tokens, err := parseJSONPointer("/settings/a~1b/m~0n/0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"settings", "a/b", "m~n", "0"}, tokens)
+
+	tokens, err = parseJSONPointer("")
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+
+	_, err = parseJSONPointer("settings")
+	assert.ErrorIs(t, err, errPatchInvalid)
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	doc := `{"id":1,"settings":{"theme":"dark","tags":["a","b"],"limits":{"daily":10}}}`
+
+	tests := []struct {
+		name    string
+		patch   string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "add member",
+			patch: `[{"op":"add","path":"/settings/locale","value":"de"}]`,
+			want:  `{"id":1,"settings":{"theme":"dark","locale":"de","tags":["a","b"],"limits":{"daily":10}}}`,
+		},
+		{
+			name:  "insert and append to array",
+			patch: `[{"op":"add","path":"/settings/tags/1","value":"x"},{"op":"add","path":"/settings/tags/-","value":"z"}]`,
+			want:  `{"id":1,"settings":{"theme":"dark","tags":["a","x","b","z"],"limits":{"daily":10}}}`,
+		},
+		{
+			name:  "remove and replace",
+			patch: `[{"op":"remove","path":"/settings/tags/0"},{"op":"replace","path":"/settings/limits/daily","value":20}]`,
+			want:  `{"id":1,"settings":{"theme":"dark","tags":["b"],"limits":{"daily":20}}}`,
+		},
+		{
+			name:  "move and copy",
+			patch: `[{"op":"move","from":"/settings/theme","path":"/theme"},{"op":"copy","from":"/settings/limits","path":"/limits"}]`,
+			want:  `{"id":1,"theme":"dark","limits":{"daily":10},"settings":{"tags":["a","b"],"limits":{"daily":10}}}`,
+		},
+		{
+			name:  "test passes",
+			patch: `[{"op":"test","path":"/settings/limits/daily","value":10.0},{"op":"replace","path":"/settings/theme","value":"light"}]`,
+			want:  `{"id":1,"settings":{"theme":"light","tags":["a","b"],"limits":{"daily":10}}}`,
+		},
+		{
+			name:    "test fails",
+			patch:   `[{"op":"test","path":"/settings/theme","value":"light"}]`,
+			wantErr: errPatchTestFailed,
+		},
+		{
+			name:    "missing path",
+			patch:   `[{"op":"replace","path":"/settings/missing","value":1}]`,
+			wantErr: errPatchUnprocessable,
+		},
+		{
+			name:    "array index out of range",
+			patch:   `[{"op":"add","path":"/settings/tags/5","value":"x"}]`,
+			wantErr: errPatchUnprocessable,
+		},
+		{
+			name:    "move into own child",
+			patch:   `[{"op":"move","from":"/settings","path":"/settings/nested"}]`,
+			wantErr: errPatchInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops, err := parseJSONPatch([]byte(tt.patch))
+			require.NoError(t, err)
+
+			got, err := applyJSONPatch(mustDecodePatchJSON(t, doc), ops)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, jsonEqual(mustDecodePatchJSON(t, tt.want), got), "got %v", got)
+		})
+	}
+}
+
+func TestParseJSONPatch_Invalid(t *testing.T) {
+	for _, patch := range []string{
+		`{"op":"add","path":"/a","value":1}`,
+		`[{"op":"add","value":1}]`,
+		`[{"op":"add","path":"/a"}]`,
+		`[{"op":"move","path":"/a"}]`,
+		`[{"op":"merge","path":"/a","value":1}]`,
+	} {
+		_, err := parseJSONPatch([]byte(patch))
+		assert.ErrorIs(t, err, errPatchInvalid, patch)
+	}
+}
+
+func TestPatchDocument_RowMustStayObject(t *testing.T) {
+	_, err := patchDocument(mergePatchContentType, map[string]interface{}{}, []byte(`[1]`))
+	assert.ErrorIs(t, err, errPatchInvalid)
+
+	_, err = patchDocument(jsonPatchContentType, map[string]interface{}{"id": 1}, []byte(`[{"op":"replace","path":"","value":3}]`))
+	assert.ErrorIs(t, err, errPatchUnprocessable)
+}
+
+func TestChangedColumns(t *testing.T) {
+	before := mustDecodePatchJSON(t, `{"id":1,"name":"a","count":2,"settings":{"theme":"dark"},"note":"x"}`).(map[string]interface{})
+	after := mustDecodePatchJSON(t, `{"id":1,"name":"a","count":3,"settings":{"theme":"light","n":12345678901234567890}}`).(map[string]interface{})
+
+	columns, values := changedColumns(before, after)
+	assert.Equal(t, []string{"count", "note", "settings"}, columns)
+	assert.Equal(t, int64(3), values["count"])
+	assert.Nil(t, values["note"])
+
+	// Nested numbers are kept verbatim for JSONB
+	encoded, err := json.Marshal(values["settings"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"theme":"light","n":12345678901234567890}`, string(encoded))
+	assert.Contains(t, string(encoded), "12345678901234567890")
+}
+
+func TestPatchErrorStatus(t *testing.T) {
+	assert.Equal(t, fiber.StatusBadRequest, patchErrorStatus(errPatchInvalid))
+	assert.Equal(t, fiber.StatusUnprocessableEntity, patchErrorStatus(pathNotFound([]string{"a"})))
+	assert.Equal(t, fiber.StatusConflict, patchErrorStatus(errPatchTestFailed))
+}
+
+func TestMakePatchHandler_PatchDocuments(t *testing.T) {
+	app := fiber.New()
+	handler := &RESTHandler{
+		parser: NewQueryParser(&config.Config{}),
+	}
+
+	table := database.TableInfo{
+		Schema:     "public",
+		Name:       "items",
+		PrimaryKey: []string{"id"},
+		Columns: []database.ColumnInfo{
+			{Name: "id", DataType: "uuid"},
+			{Name: "settings", DataType: "jsonb"},
+		},
+	}
+
+	app.Patch("/items", handler.makeBatchPatchHandler(table))
+	app.Patch("/items/:id", handler.makePatchHandler(table))
+
+	t.Run("batch patch rejects patch documents", func(t *testing.T) {
+		req := httptest.NewRequest("PATCH", "/items?name=eq.a", strings.NewReader(`{"settings":{"theme":"dark"}}`))
+		req.Header.Set("Content-Type", mergePatchContentType)
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, fiber.StatusUnsupportedMediaType, resp.StatusCode)
+	})
+
+	t.Run("malformed JSON Patch is rejected before querying", func(t *testing.T) {
+		req := httptest.NewRequest("PATCH", "/items/1", strings.NewReader(`{"op":"add"}`))
+		req.Header.Set("Content-Type", jsonPatchContentType)
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), "array of operations")
+	})
+
+	t.Run("malformed merge patch is rejected before querying", func(t *testing.T) {
+		req := httptest.NewRequest("PATCH", "/items/1", strings.NewReader(`{invalid`))
+		req.Header.Set("Content-Type", mergePatchContentType)
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/auth"
+)
+
+// Schema selection headers (PostgREST parity). Reads use Accept-Profile, writes use Content-Profile.
+const (
+	acceptProfileHeader  = "Accept-Profile"
+	contentProfileHeader = "Content-Profile"
+)
+
+// restError is a request error with the HTTP status it should be reported with
+type restError struct {
+	status  int
+	message string
+}
+
+func (e *restError) Error() string {
+	return e.message
+}
+
+// requestedProfile returns the schema selected by the profile header matching the request method
+func requestedProfile(c fiber.Ctx) string {
+	header := contentProfileHeader
+	if c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead {
+		header = acceptProfileHeader
+	}
+	return strings.TrimSpace(c.Get(header))
+}
+
+// canAccessHiddenSchemas reports whether the caller may bypass schema exposure rules.
+// The dashboard table editor and service integrations browse every schema.
+func canAccessHiddenSchemas(c fiber.Ctx) bool {
+	role, _ := c.Locals("user_role").(string)
+	return role == "admin" || role == "dashboard_admin" || role == "service_role"
+}
+
+// isSchemaExposed checks a schema against api.exposed_schemas and api.hidden_schemas.
+// An empty exposed list exposes every schema that is not hidden.
+func (h *RESTHandler) isSchemaExposed(schema string) bool {
+	if h.config == nil {
+		return true
+	}
+	if slices.Contains(h.config.API.HiddenSchemas, schema) {
+		return false
+	}
+	return len(h.config.API.ExposedSchemas) == 0 || slices.Contains(h.config.API.ExposedSchemas, schema)
+}
+
+// resolveTable determines the schema and table of a REST request from the path and profile headers.
+// An explicit schema segment wins; a profile header selects the schema of unqualified paths.
+// Hidden schemas are reported as missing tables so their contents cannot be probed.
+func (h *RESTHandler) resolveTable(c fiber.Ctx) (schema, table string, err *restError) {
+	schema, table = h.parseTableFromPath(c)
+	qualified := c.Params("table") != ""
+	profile := requestedProfile(c)
+
+	if profile != "" {
+		if qualified && profile != schema {
+			return "", "", &restError{
+				status:  fiber.StatusBadRequest,
+				message: fmt.Sprintf("Profile header '%s' conflicts with schema '%s' in the path", profile, schema),
+			}
+		}
+		if !canAccessHiddenSchemas(c) && !h.isSchemaExposed(profile) {
+			return "", "", &restError{
+				status:  fiber.StatusNotAcceptable,
+				message: fmt.Sprintf("Schema '%s' is not exposed by the REST API", profile),
+			}
+		}
+		schema = profile
+	}
+
+	if !canAccessHiddenSchemas(c) && !h.isSchemaExposed(schema) {
+		return "", "", &restError{
+			status:  fiber.StatusNotFound,
+			message: fmt.Sprintf("Table '%s.%s' not found", schema, table),
+		}
+	}
+
+	// Client keys restricted to specific tables can only read, or write, those tables
+	if restrictions, ok := c.Locals("client_key_restrictions").(auth.ClientKeyRestrictions); ok {
+		write := isTableWrite(c)
+		if !restrictions.AllowsTable(schema, table, write) {
+			access := "read"
+			if write {
+				access = "write"
+			}
+			return "", "", &restError{
+				status:  fiber.StatusForbidden,
+				message: fmt.Sprintf("Client key is not allowed to %s table '%s.%s'", access, schema, table),
+			}
+		}
+	}
+
+	if profile != "" {
+		c.Set(contentProfileHeader, schema)
+	}
+	return schema, table, nil
+}
+
+// isTableWrite reports whether a REST request modifies rows. POST queries only read.
+func isTableWrite(c fiber.Ctx) bool {
+	switch c.Method() {
+	case fiber.MethodGet, fiber.MethodHead:
+		return false
+	case fiber.MethodPost:
+		return !strings.HasSuffix(c.Path(), "/query")
+	default:
+		return true
+	}
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRESTHandler_IsSchemaExposed(t *testing.T) {
+	t.Run("everything exposed without configuration", func(t *testing.T) {
+		handler := NewRESTHandler(nil, nil, nil, &config.Config{})
+		assert.True(t, handler.isSchemaExposed("public"))
+		assert.True(t, handler.isSchemaExposed("auth"))
+	})
+
+	t.Run("hidden schemas", func(t *testing.T) {
+		handler := NewRESTHandler(nil, nil, nil, &config.Config{
+			API: config.APIConfig{HiddenSchemas: []string{"auth", "dashboard"}},
+		})
+		assert.True(t, handler.isSchemaExposed("public"))
+		assert.True(t, handler.isSchemaExposed("analytics"))
+		assert.False(t, handler.isSchemaExposed("auth"))
+	})
+
+	t.Run("allowlist with hidden schemas", func(t *testing.T) {
+		handler := NewRESTHandler(nil, nil, nil, &config.Config{
+			API: config.APIConfig{
+				ExposedSchemas: []string{"public", "analytics", "auth"},
+				HiddenSchemas:  []string{"auth"},
+			},
+		})
+		assert.True(t, handler.isSchemaExposed("analytics"))
+		assert.False(t, handler.isSchemaExposed("logging"))
+		assert.False(t, handler.isSchemaExposed("auth"), "hidden wins over exposed")
+	})
+}
+
+func TestRESTHandler_ResolveTable(t *testing.T) {
+	handler := NewRESTHandler(nil, nil, nil, &config.Config{
+		API: config.APIConfig{HiddenSchemas: []string{"auth"}},
+	})
+
+	type result struct {
+		schema, table string
+		status        int
+		profile       string
+	}
+	resolve := func(method, path, role string, headers map[string]string) result {
+		app := fiber.New()
+		var res result
+		h := func(c fiber.Ctx) error {
+			if role != "" {
+				c.Locals("user_role", role)
+			}
+			schema, table, err := handler.resolveTable(c)
+			res.schema, res.table = schema, table
+			if err != nil {
+				res.status = err.status
+				return c.SendStatus(err.status)
+			}
+			return c.SendStatus(fiber.StatusOK)
+		}
+		app.Add([]string{method}, "/tables/:schema/:table", h)
+		app.Add([]string{method}, "/tables/:schema", h)
+
+		req := httptest.NewRequest(method, path, nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		res.profile = resp.Header.Get(contentProfileHeader)
+		return res
+	}
+
+	t.Run("unqualified path defaults to public", func(t *testing.T) {
+		res := resolve("GET", "/tables/posts", "authenticated", nil)
+		assert.Equal(t, result{schema: "public", table: "posts"}, res)
+	})
+
+	t.Run("explicit schema segment", func(t *testing.T) {
+		res := resolve("GET", "/tables/analytics/events", "authenticated", nil)
+		assert.Equal(t, "analytics", res.schema)
+		assert.Equal(t, "events", res.table)
+		assert.Empty(t, res.profile)
+	})
+
+	t.Run("Accept-Profile selects schema for reads", func(t *testing.T) {
+		res := resolve("GET", "/tables/events", "anon", map[string]string{acceptProfileHeader: "analytics"})
+		assert.Equal(t, "analytics", res.schema)
+		assert.Equal(t, "events", res.table)
+		assert.Equal(t, "analytics", res.profile)
+	})
+
+	t.Run("Content-Profile selects schema for writes", func(t *testing.T) {
+		res := resolve("POST", "/tables/events", "authenticated", map[string]string{contentProfileHeader: "analytics"})
+		assert.Equal(t, "analytics", res.schema)
+		assert.Equal(t, "analytics", res.profile)
+	})
+
+	t.Run("Accept-Profile is ignored for writes", func(t *testing.T) {
+		res := resolve("POST", "/tables/events", "authenticated", map[string]string{acceptProfileHeader: "analytics"})
+		assert.Equal(t, "public", res.schema)
+	})
+
+	t.Run("profile matching the path schema", func(t *testing.T) {
+		res := resolve("GET", "/tables/analytics/events", "authenticated", map[string]string{acceptProfileHeader: "analytics"})
+		assert.Equal(t, 0, res.status)
+		assert.Equal(t, "analytics", res.schema)
+	})
+
+	t.Run("profile conflicting with the path schema", func(t *testing.T) {
+		res := resolve("GET", "/tables/analytics/events", "authenticated", map[string]string{acceptProfileHeader: "public"})
+		assert.Equal(t, fiber.StatusBadRequest, res.status)
+	})
+
+	t.Run("hidden schema in path is not found", func(t *testing.T) {
+		res := resolve("GET", "/tables/auth/users", "authenticated", nil)
+		assert.Equal(t, fiber.StatusNotFound, res.status)
+	})
+
+	t.Run("hidden schema in profile is not acceptable", func(t *testing.T) {
+		res := resolve("GET", "/tables/users", "anon", map[string]string{acceptProfileHeader: "auth"})
+		assert.Equal(t, fiber.StatusNotAcceptable, res.status)
+	})
+
+	for _, role := range []string{"admin", "dashboard_admin", "service_role"} {
+		t.Run(role+" can reach hidden schemas", func(t *testing.T) {
+			res := resolve("GET", "/tables/auth/users", role, nil)
+			assert.Equal(t, 0, res.status)
+			assert.Equal(t, "auth", res.schema)
+
+			res = resolve("GET", "/tables/users", role, map[string]string{acceptProfileHeader: "auth"})
+			assert.Equal(t, 0, res.status)
+			assert.Equal(t, "auth", res.schema)
+		})
+	}
+}
+
+func TestRESTHandler_ResolveTable_ClientKeyTables(t *testing.T) {
+	handler := NewRESTHandler(nil, nil, nil, &config.Config{})
+	restrictions := auth.ClientKeyRestrictions{AllowedTables: map[string]string{
+		"posts":         auth.TableAccessWrite,
+		"public.tags":   auth.TableAccessRead,
+		"analytics.*":   auth.TableAccessRead,
+		"analytics.raw": auth.TableAccessWrite,
+	}}
+
+	status := func(method, path string) int {
+		app := fiber.New()
+		h := func(c fiber.Ctx) error {
+			c.Locals("client_key_restrictions", restrictions)
+			if _, _, err := handler.resolveTable(c); err != nil {
+				return c.SendStatus(err.status)
+			}
+			return c.SendStatus(fiber.StatusOK)
+		}
+		app.Add([]string{method}, "/tables/:schema/:table/query", h)
+		app.Add([]string{method}, "/tables/:schema/query", h)
+		app.Add([]string{method}, "/tables/:schema/:table", h)
+		app.Add([]string{method}, "/tables/:schema", h)
+
+		resp, err := app.Test(httptest.NewRequest(method, path, nil))
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusOK, status("GET", "/tables/posts"))
+	assert.Equal(t, fiber.StatusOK, status("DELETE", "/tables/public/posts"))
+	assert.Equal(t, fiber.StatusOK, status("GET", "/tables/tags"))
+	assert.Equal(t, fiber.StatusOK, status("POST", "/tables/tags/query"), "POST queries only read")
+	assert.Equal(t, fiber.StatusForbidden, status("POST", "/tables/tags"))
+	assert.Equal(t, fiber.StatusForbidden, status("GET", "/tables/users"))
+	assert.Equal(t, fiber.StatusOK, status("GET", "/tables/analytics/events"))
+	assert.Equal(t, fiber.StatusForbidden, status("PATCH", "/tables/analytics/events"))
+	assert.Equal(t, fiber.StatusOK, status("PATCH", "/tables/analytics/raw"), "exact entries win over schema wildcards")
+}
@@ -43,9 +43,10 @@ type PostQueryBetweenFilter struct {
 
 // PostQueryOrderBy represents an order clause in the POST body
 type PostQueryOrderBy struct {
-	Column    string `json:"column"`
-	Direction string `json:"direction"`
-	Nulls     string `json:"nulls,omitempty"`
+	Column    string      `json:"column"`
+	Direction string      `json:"direction"`
+	Nulls     string      `json:"nulls,omitempty"`
+	Geometry  interface{} `json:"geometry,omitempty"` // GeoJSON geometry to order by distance from
 }
 
 // makePostQueryHandler creates a handler for POST-based queries
@@ -57,23 +58,33 @@ func (h *RESTHandler) makePostQueryHandler(table database.TableInfo) fiber.Handl
 		// Parse request body
 		var req PostQueryRequest
 		if err := c.Bind().Body(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error":   "Invalid request body",
-				"details": err.Error(),
-			})
+			return SendErrorWithDetails(c, fiber.StatusBadRequest, "Invalid request body", ErrCodeInvalidBody, "", "", err.Error())
 		}
 
 		// Convert POST request to QueryParams
 		params, err := h.convertPostQueryToParams(&req)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error":   "Invalid query parameters",
-				"details": err.Error(),
-			})
+			return SendErrorWithDetails(c, fiber.StatusBadRequest, "Invalid query parameters", ErrCodeInvalidInput, "", "", err.Error())
 		}
 
+		// Bind filter values with their column's type
+		if err := coerceFilterValues(&table, params.Filters); err != nil {
+			return respondFilterTypeError(c, err)
+		}
+		if err := checkDistanceOrders(&table, params.Order); err != nil {
+			return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+		}
+
+		// Count filtered and ordered columns for the index advisor
+		h.patterns.Record(table.Schema, table.Name, params)
+
 		// Build and execute query (reuse existing logic from GET handler)
-		query, args := h.buildSelectQuery(table, params)
+		query, args := h.buildSelectQuery(table, params, nil)
+
+		// Return the query plan instead of results when requested (Prefer: explain=plan|analyze)
+		if handled, err := h.handleExplain(ctx, c, query, args); handled {
+			return err
+		}
 
 		// Execute query with RLS context
 		var results []map[string]interface{}
@@ -88,18 +99,14 @@ func (h *RESTHandler) makePostQueryHandler(table database.TableInfo) fiber.Handl
 			return err
 		})
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to execute query",
-			})
+			return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to execute query", ErrCodeOperationFailed)
 		}
 
 		// Handle count if requested
 		if params.Count != "" {
-			count, err := h.getCount(ctx, c, table, params)
+			count, err := h.getCount(ctx, c, table, params, "")
 			if err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Failed to get count",
-				})
+				return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to get count", ErrCodeOperationFailed)
 			}
 
 			// Set Content-Range header
@@ -221,6 +228,13 @@ func (h *RESTHandler) convertPostQueryToParams(req *PostQueryRequest) (*QueryPar
 		}
 	}
 
+	if err := parseTextSearchLanguages(params.Filters); err != nil {
+		return nil, err
+	}
+	if err := parseBBoxValues(params.Filters); err != nil {
+		return nil, err
+	}
+
 	// Convert order
 	for _, o := range req.Order {
 		orderBy := OrderBy{
@@ -228,6 +242,13 @@ func (h *RESTHandler) convertPostQueryToParams(req *PostQueryRequest) (*QueryPar
 			Desc:   strings.ToLower(o.Direction) == "desc",
 			Nulls:  strings.ToLower(o.Nulls),
 		}
+		if o.Geometry != nil {
+			geometry, err := parseGeoJSONGeometry(o.Geometry)
+			if err != nil {
+				return nil, fmt.Errorf("order by %s: %w", o.Column, err)
+			}
+			orderBy.Geometry = geometry
+		}
 		params.Order = append(params.Order, orderBy)
 	}
 
@@ -246,15 +267,18 @@ func (h *RESTHandler) convertPostQueryToParams(req *PostQueryRequest) (*QueryPar
 	return params, nil
 }
 
-// buildSelectQuery builds a SELECT query from parameters
-func (h *RESTHandler) buildSelectQuery(table database.TableInfo, params *QueryParams) (string, []interface{}) {
+// buildSelectQuery builds a SELECT query from parameters. Embedded relations are added as
+// JSON columns after the selected ones.
+func (h *RESTHandler) buildSelectQuery(table database.TableInfo, params *QueryParams, embeds []resolvedEmbed) (string, []interface{}) {
 	var selectClause string
+	selectDistance := false
 
 	// If we have aggregations, use BuildSelectClause (handles aggregations)
 	//nolint:gocritic // Conditions check different params, not switch-compatible
 	if len(params.Aggregations) > 0 || len(params.GroupBy) > 0 {
 		selectClause = params.BuildSelectClause(table.Name)
 	} else if len(params.Select) > 0 {
+		distance := params.distanceOrder()
 		// Validate and sanitize column names for regular selects
 		validColumns := []string{}
 		for _, col := range params.Select {
@@ -272,6 +296,8 @@ func (h *RESTHandler) buildSelectQuery(table database.TableInfo, params *QueryPa
 				} else {
 					validColumns = append(validColumns, quotedCol)
 				}
+			} else if col == distanceField && distance != nil {
+				selectDistance = true
 			}
 		}
 		if len(validColumns) > 0 {
@@ -279,19 +305,41 @@ func (h *RESTHandler) buildSelectQuery(table database.TableInfo, params *QueryPa
 		} else {
 			selectClause = buildSelectColumnsWithTruncation(table, params.TruncateLength)
 		}
-	} else {
+	} else if len(embeds) == 0 {
 		// Use buildSelectColumnsWithTruncation to handle geometry columns and truncation
 		selectClause = buildSelectColumnsWithTruncation(table, params.TruncateLength)
 	}
 
+	// Add embedded relations as correlated subqueries on the table
+	argCounter := 1
+	var args []interface{}
+	if len(embeds) > 0 {
+		tableRef := quoteIdentifier(table.Schema) + "." + quoteIdentifier(table.Name)
+		aliasCounter := 0
+		embedColumns, embedArgs := buildEmbedColumns(embeds, tableRef, &argCounter, &aliasCounter)
+		if selectClause != "" {
+			embedColumns = append([]string{selectClause}, embedColumns...)
+		}
+		selectClause = strings.Join(embedColumns, ", ")
+		args = append(args, embedArgs...)
+	}
+
+	// Add the distance from the geometry of an st_distance ordering (select=*,_distance)
+	if selectDistance {
+		distanceColumn, distanceArg := distanceSelectSQL(*params.distanceOrder(), &argCounter)
+		selectClause += ", " + distanceColumn
+		args = append(args, distanceArg)
+	}
+
 	// Start building query - use quoteIdentifier for defense in depth
 	query := fmt.Sprintf("SELECT %s FROM %s.%s", selectClause, quoteIdentifier(table.Schema), quoteIdentifier(table.Name))
 
 	// Add WHERE, ORDER BY, LIMIT, OFFSET
-	whereAndMore, args := params.ToSQL(table.Name)
+	whereAndMore, whereArgs := params.toSQL(&argCounter)
 	if whereAndMore != "" {
 		query += " " + whereAndMore
 	}
+	args = append(args, whereArgs...)
 
 	// Add GROUP BY clause
 	groupByClause := params.BuildGroupByClause()
@@ -307,8 +355,20 @@ func (h *RESTHandler) columnExists(table database.TableInfo, columnName string)
 	return table.HasColumn(columnName)
 }
 
+// columnWriteError returns why a column can't be written, or "" when it can
+func (h *RESTHandler) columnWriteError(table database.TableInfo, columnName string) string {
+	col := table.GetColumn(columnName)
+	if col == nil {
+		return fmt.Sprintf("Unknown column: %s", columnName)
+	}
+	if col.IsGenerated {
+		return fmt.Sprintf("Column %s is generated and cannot be written", columnName)
+	}
+	return ""
+}
+
 // getCount gets the row count for a query
-func (h *RESTHandler) getCount(ctx context.Context, c fiber.Ctx, table database.TableInfo, params *QueryParams) (int, error) {
+func (h *RESTHandler) getCount(ctx context.Context, c fiber.Ctx, table database.TableInfo, params *QueryParams, snapshotID string) (int, error) {
 	// Build count query - use quoteIdentifier for defense in depth
 	query := fmt.Sprintf("SELECT COUNT(*) FROM %s.%s", quoteIdentifier(table.Schema), quoteIdentifier(table.Name))
 
@@ -325,7 +385,7 @@ func (h *RESTHandler) getCount(ctx context.Context, c fiber.Ctx, table database.
 
 	// Execute count query with RLS context
 	var count int
-	err := middleware.WrapWithRLS(ctx, h.db, c, func(tx pgx.Tx) error {
+	err := h.wrapCachedRead(ctx, c, snapshotID, query, args, func(tx pgx.Tx, args []interface{}) error {
 		return tx.QueryRow(ctx, query, args...).Scan(&count)
 	})
 
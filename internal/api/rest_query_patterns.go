@@ -0,0 +1,160 @@
+package api
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTrackedQueryPatterns bounds the memory used by the REST query pattern tracker.
+// Patterns seen after the limit is reached are dropped until the tracker is reset.
+const maxTrackedQueryPatterns = 10000
+
+// QueryPatternKind classifies how a REST query uses a column
+type QueryPatternKind string
+
+const (
+	QueryPatternEquality QueryPatternKind = "equality" // eq, in, is
+	QueryPatternRange    QueryPatternKind = "range"    // gt, gte, lt, lte
+	QueryPatternOrder    QueryPatternKind = "order"    // order=column
+	QueryPatternContains QueryPatternKind = "contains" // cs, cd, ov on jsonb or array columns
+)
+
+// QueryPattern counts how often REST queries filter or order a table by a column
+type QueryPattern struct {
+	Schema string `json:"schema"`
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	// Path is the JSONB path of a filter like data->address->>city, empty for plain columns
+	Path     []string         `json:"path,omitempty"`
+	Kind     QueryPatternKind `json:"kind"`
+	Count    int64            `json:"count"`
+	LastSeen time.Time        `json:"last_seen"`
+}
+
+type queryPatternKey struct {
+	schema, table, column, path string
+	kind                        QueryPatternKind
+}
+
+// queryPatternTracker records the columns REST queries filter and order by, so the
+// index advisor can recommend indexes for them. Counts are kept in memory per instance.
+type queryPatternTracker struct {
+	mu       sync.Mutex
+	patterns map[queryPatternKey]*QueryPattern
+	since    time.Time
+}
+
+func newQueryPatternTracker() *queryPatternTracker {
+	return &queryPatternTracker{
+		patterns: make(map[queryPatternKey]*QueryPattern),
+		since:    time.Now(),
+	}
+}
+
+// filterPatternKind maps a filter operator to the pattern it forms, false if indexes don't help it
+func filterPatternKind(op FilterOperator) (QueryPatternKind, bool) {
+	switch op {
+	case OpEqual, OpIn, OpIs:
+		return QueryPatternEquality, true
+	case OpGreaterThan, OpGreaterOrEqual, OpLessThan, OpLessOrEqual:
+		return QueryPatternRange, true
+	case OpContains, OpContained, OpOverlap:
+		return QueryPatternContains, true
+	default:
+		return "", false
+	}
+}
+
+// splitJSONPath splits a filter column like data->address->>city into the column and path keys
+func splitJSONPath(column string) (string, []string) {
+	if !strings.Contains(column, "->") {
+		return column, nil
+	}
+	parts := strings.Split(strings.ReplaceAll(column, "->>", "->"), "->")
+	return parts[0], parts[1:]
+}
+
+// Record counts the filters and orderings of a parsed REST query
+func (t *queryPatternTracker) Record(schema, table string, params *QueryParams) {
+	if t == nil || params == nil || (len(params.Filters) == 0 && len(params.Order) == 0) {
+		return
+	}
+
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, filter := range params.Filters {
+		kind, ok := filterPatternKind(filter.Operator)
+		if !ok {
+			continue
+		}
+		column, path := splitJSONPath(filter.Column)
+		t.add(schema, table, column, path, kind, now)
+	}
+	for _, order := range params.Order {
+		if order.VectorOp != "" || order.Geometry != "" {
+			continue
+		}
+		column, path := splitJSONPath(order.Column)
+		t.add(schema, table, column, path, QueryPatternOrder, now)
+	}
+}
+
+func (t *queryPatternTracker) add(schema, table, column string, path []string, kind QueryPatternKind, now time.Time) {
+	key := queryPatternKey{schema: schema, table: table, column: column, path: strings.Join(path, "->"), kind: kind}
+	pattern, ok := t.patterns[key]
+	if !ok {
+		if len(t.patterns) >= maxTrackedQueryPatterns {
+			return
+		}
+		pattern = &QueryPattern{Schema: schema, Table: table, Column: column, Path: path, Kind: kind}
+		t.patterns[key] = pattern
+	}
+	pattern.Count++
+	pattern.LastSeen = now
+}
+
+// Snapshot returns the recorded patterns, most frequent first, and when recording started
+func (t *queryPatternTracker) Snapshot() ([]QueryPattern, time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	patterns := make([]QueryPattern, 0, len(t.patterns))
+	for _, pattern := range t.patterns {
+		patterns = append(patterns, *pattern)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if patterns[i].Count != patterns[j].Count {
+			return patterns[i].Count > patterns[j].Count
+		}
+		return patternLess(patterns[i], patterns[j])
+	})
+	return patterns, t.since
+}
+
+// Reset clears the recorded patterns
+func (t *queryPatternTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.patterns = make(map[queryPatternKey]*QueryPattern)
+	t.since = time.Now()
+}
+
+func patternLess(a, b QueryPattern) bool {
+	if a.Schema != b.Schema {
+		return a.Schema < b.Schema
+	}
+	if a.Table != b.Table {
+		return a.Table < b.Table
+	}
+	if a.Column != b.Column {
+		return a.Column < b.Column
+	}
+	if ap, bp := strings.Join(a.Path, "->"), strings.Join(b.Path, "->"); ap != bp {
+		return ap < bp
+	}
+	return a.Kind < b.Kind
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryPatternTracker(t *testing.T) {
+	tracker := newQueryPatternTracker()
+
+	params := &QueryParams{
+		Filters: []Filter{
+			{Column: "status", Operator: OpEqual, Value: "open"},
+			{Column: "created_at", Operator: OpGreaterOrEqual, Value: "2025-01-01"},
+			{Column: "data->address->>city", Operator: OpEqual, Value: "Oslo"},
+			{Column: "tags", Operator: OpContains, Value: "{a}"},
+			{Column: "title", Operator: OpILike, Value: "%x%"},
+		},
+		Order: []OrderBy{
+			{Column: "created_at", Desc: true},
+			{Column: "embedding", VectorOp: OpVectorCosine},
+		},
+	}
+	tracker.Record("public", "orders", params)
+	tracker.Record("public", "orders", &QueryParams{Filters: []Filter{{Column: "status", Operator: OpIn}}})
+
+	patterns, since := tracker.Snapshot()
+	assert.False(t, since.IsZero())
+	require.Len(t, patterns, 5)
+
+	// Most frequent first
+	assert.Equal(t, "status", patterns[0].Column)
+	assert.Equal(t, QueryPatternEquality, patterns[0].Kind)
+	assert.Equal(t, int64(2), patterns[0].Count)
+
+	byColumn := map[string]QueryPattern{}
+	for _, p := range patterns {
+		byColumn[p.Column+"/"+string(p.Kind)] = p
+	}
+	assert.Contains(t, byColumn, "created_at/range")
+	assert.Contains(t, byColumn, "created_at/order")
+	assert.Contains(t, byColumn, "tags/contains")
+	assert.Equal(t, []string{"address", "city"}, byColumn["data/equality"].Path)
+	assert.NotContains(t, byColumn, "title/equality", "ilike filters are not recorded")
+	assert.NotContains(t, byColumn, "embedding/order", "vector orderings are not recorded")
+
+	tracker.Reset()
+	patterns, _ = tracker.Snapshot()
+	assert.Empty(t, patterns)
+}
+
+func TestQueryPatternTracker_NilSafe(t *testing.T) {
+	var tracker *queryPatternTracker
+	assert.NotPanics(t, func() {
+		tracker.Record("public", "orders", &QueryParams{Filters: []Filter{{Column: "a", Operator: OpEqual}}})
+	})
+}
+
+func TestQueryPatternTracker_Bounded(t *testing.T) {
+	tracker := newQueryPatternTracker()
+	for i := 0; i < maxTrackedQueryPatterns+10; i++ {
+		tracker.Record("public", "t", &QueryParams{Order: []OrderBy{{Column: string(rune('a'+i%26)) + string(rune(i))}}})
+	}
+	patterns, _ := tracker.Snapshot()
+	assert.Len(t, patterns, maxTrackedQueryPatterns)
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/config"
+)
+
+// isConfiguredReadOnly checks a table against api.read_only_schemas and api.read_only_tables.
+func (h *RESTHandler) isConfiguredReadOnly(schema, table string) bool {
+	if h.config == nil {
+		return false
+	}
+	return isReadOnlyTable(&h.config.API, schema, table)
+}
+
+// isReadOnlyTable checks a table against the read-only schemas and tables of the API config.
+// Table entries are "schema.table", or a bare table name for the public schema.
+func isReadOnlyTable(cfg *config.APIConfig, schema, table string) bool {
+	if slices.Contains(cfg.ReadOnlySchemas, schema) {
+		return true
+	}
+	if slices.Contains(cfg.ReadOnlyTables, schema+"."+table) {
+		return true
+	}
+	return schema == "public" && slices.Contains(cfg.ReadOnlyTables, table)
+}
+
+// readOnlyReason returns why writes to a table are rejected, or "" when they are allowed.
+// Materialized views and views Postgres can't update are never writable; tables configured as
+// read-only reject writes from everyone but the service role, regardless of database grants, so
+// writes can be funneled through functions.
+func (h *RESTHandler) readOnlyReason(c fiber.Ctx, schema, table string, isWritable bool) string {
+	if !isWritable {
+		return fmt.Sprintf("Table '%s.%s' is read-only (materialized view or non-updatable view)", schema, table)
+	}
+	if role, _ := c.Locals("user_role").(string); role != "service_role" && h.isConfiguredReadOnly(schema, table) {
+		return fmt.Sprintf("Table '%s.%s' is read-only through the REST API", schema, table)
+	}
+	return ""
+}
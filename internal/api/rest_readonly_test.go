@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRESTHandler_IsConfiguredReadOnly(t *testing.T) {
+	handler := NewRESTHandler(nil, nil, nil, &config.Config{
+		API: config.APIConfig{
+			ReadOnlySchemas: []string{"reporting"},
+			ReadOnlyTables:  []string{"analytics.daily_totals", "invoices"},
+		},
+	})
+
+	tests := []struct {
+		schema, table string
+		expected      bool
+	}{
+		{"reporting", "anything", true},
+		{"analytics", "daily_totals", true},
+		{"analytics", "events", false},
+		{"public", "invoices", true},
+		{"billing", "invoices", false},
+		{"public", "posts", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schema+"."+tt.table, func(t *testing.T) {
+			assert.Equal(t, tt.expected, handler.isConfiguredReadOnly(tt.schema, tt.table))
+		})
+	}
+
+	t.Run("nothing is read-only without configuration", func(t *testing.T) {
+		handler := NewRESTHandler(nil, nil, nil, &config.Config{})
+		assert.False(t, handler.isConfiguredReadOnly("public", "invoices"))
+	})
+}
+
+func TestRESTHandler_ReadOnlyReason(t *testing.T) {
+	handler := NewRESTHandler(nil, nil, nil, &config.Config{
+		API: config.APIConfig{ReadOnlyTables: []string{"invoices"}},
+	})
+
+	reason := func(role, table string, isWritable bool) string {
+		var got string
+		app := fiber.New()
+		app.Post("/", func(c fiber.Ctx) error {
+			if role != "" {
+				c.Locals("user_role", role)
+			}
+			got = handler.readOnlyReason(c, "public", table, isWritable)
+			return nil
+		})
+		resp, err := app.Test(httptest.NewRequest("POST", "/", nil))
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return got
+	}
+
+	assert.Empty(t, reason("authenticated", "posts", true))
+	assert.Contains(t, reason("authenticated", "report_view", false), "materialized view or non-updatable view")
+	assert.Contains(t, reason("authenticated", "invoices", true), "read-only through the REST API")
+	assert.Contains(t, reason("admin", "invoices", true), "read-only through the REST API")
+	assert.Empty(t, reason("service_role", "invoices", true), "service role writes are exempt")
+	assert.Contains(t, reason("service_role", "report_view", false), "view", "views stay read-only for everyone")
+}
@@ -0,0 +1,351 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/middleware"
+	"github.com/rs/zerolog/log"
+)
+
+// Snapshot pagination headers. A client opens a snapshot with "Prefer: snapshot" (or
+// "Prefer: snapshot=<seconds>" to choose its lifetime) and sends the returned token on
+// every following page so all pages read the same database state.
+const (
+	snapshotTokenHeader     = "X-Snapshot-Token"
+	snapshotExpiresAtHeader = "X-Snapshot-Expires-At"
+)
+
+var (
+	errSnapshotsDisabled = errors.New("snapshot pagination is disabled")
+	errSnapshotLimit     = errors.New("too many open snapshots, retry later")
+	errSnapshotToken     = errors.New("invalid snapshot token")
+	errSnapshotExpired   = errors.New("snapshot has expired, restart the export")
+)
+
+// parseSnapshotPreference extracts the snapshot preference from a Prefer header.
+// It returns whether a snapshot was requested and the requested lifetime (0 = default).
+func parseSnapshotPreference(prefer string) (bool, time.Duration, error) {
+	for _, pref := range strings.Split(prefer, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(pref), "=")
+		if strings.TrimSpace(key) != "snapshot" {
+			continue
+		}
+		if !found {
+			return true, 0, nil
+		}
+
+		seconds, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || seconds <= 0 {
+			return false, 0, fmt.Errorf("invalid snapshot preference %q: must be a positive number of seconds", value)
+		}
+		return true, time.Duration(seconds) * time.Second, nil
+	}
+	return false, 0, nil
+}
+
+// snapshotClaims is the signed payload of a snapshot token
+type snapshotClaims struct {
+	SnapshotID string `json:"sid"`
+	Subject    string `json:"sub"` // role and user the snapshot was opened for
+	ExpiresAt  int64  `json:"exp"`
+}
+
+// heldSnapshot is an open transaction keeping an exported snapshot alive
+type heldSnapshot struct {
+	tx    pgx.Tx
+	timer *time.Timer
+}
+
+// SnapshotManager opens exported snapshots for consistent deep pagination.
+// Each snapshot holds a database connection in an idle repeatable-read transaction until it
+// expires. Tokens are signed and carry the snapshot id, so any node connected to the same
+// database can serve the following pages while the opening node keeps the snapshot alive.
+type SnapshotManager struct {
+	secret     []byte
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+	maxOpen    int
+
+	mu      sync.Mutex
+	held    map[string]*heldSnapshot
+	pending int // snapshots being opened
+	now     func() time.Time
+}
+
+// NewSnapshotManager creates a snapshot manager. maxOpen limits the connections held by
+// snapshots on this node; 0 disables snapshot pagination.
+func NewSnapshotManager(secret string, defaultTTL, maxTTL time.Duration, maxOpen int) *SnapshotManager {
+	return &SnapshotManager{
+		secret:     []byte(secret),
+		defaultTTL: defaultTTL,
+		maxTTL:     maxTTL,
+		maxOpen:    maxOpen,
+		held:       make(map[string]*heldSnapshot),
+		now:        time.Now,
+	}
+}
+
+// Open exports a new snapshot from pool and returns its id, token and expiry.
+// ttl is capped to the configured maximum; 0 uses the default.
+func (m *SnapshotManager) Open(ctx context.Context, pool *pgxpool.Pool, subject string, ttl time.Duration) (string, string, time.Time, error) {
+	if m == nil || m.maxOpen <= 0 {
+		return "", "", time.Time{}, errSnapshotsDisabled
+	}
+	if ttl <= 0 {
+		ttl = m.defaultTTL
+	}
+	ttl = min(ttl, m.maxTTL)
+
+	// Reserve a slot while the transaction is opened
+	m.mu.Lock()
+	if len(m.held)+m.pending >= m.maxOpen {
+		m.mu.Unlock()
+		return "", "", time.Time{}, errSnapshotLimit
+	}
+	m.pending++
+	m.mu.Unlock()
+
+	snapshotID, tx, err := exportSnapshot(ctx, pool)
+
+	m.mu.Lock()
+	m.pending--
+	if err == nil {
+		snapshot := &heldSnapshot{tx: tx}
+		snapshot.timer = time.AfterFunc(ttl, func() { m.release(snapshotID) })
+		m.held[snapshotID] = snapshot
+	}
+	m.mu.Unlock()
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+
+	expiresAt := m.now().Add(ttl)
+	token, err := m.sign(snapshotClaims{SnapshotID: snapshotID, Subject: subject, ExpiresAt: expiresAt.Unix()})
+	if err != nil {
+		m.release(snapshotID)
+		return "", "", time.Time{}, err
+	}
+
+	log.Debug().Str("snapshot_id", snapshotID).Dur("ttl", ttl).Msg("Opened pagination snapshot")
+	return snapshotID, token, expiresAt, nil
+}
+
+// exportSnapshot begins the transaction that keeps a snapshot alive and exports it
+func exportSnapshot(ctx context.Context, pool *pgxpool.Pool) (string, pgx.Tx, error) {
+	// The transaction outlives the request, so it must not use the request context
+	tx, err := pool.BeginTx(context.Background(), pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to begin snapshot transaction: %w", err)
+	}
+
+	// The transaction is idle between pages; its lifetime is bounded by the snapshot TTL instead
+	if _, err := tx.Exec(ctx, "SET LOCAL idle_in_transaction_session_timeout = 0"); err != nil {
+		_ = tx.Rollback(context.Background())
+		return "", nil, fmt.Errorf("failed to configure snapshot transaction: %w", err)
+	}
+
+	var snapshotID string
+	if err := tx.QueryRow(ctx, "SELECT pg_export_snapshot()").Scan(&snapshotID); err != nil {
+		_ = tx.Rollback(context.Background())
+		return "", nil, fmt.Errorf("failed to export snapshot: %w", err)
+	}
+	return snapshotID, tx, nil
+}
+
+// Resolve validates a token for subject and returns its snapshot id and expiry
+func (m *SnapshotManager) Resolve(token, subject string) (string, time.Time, error) {
+	if m == nil || m.maxOpen <= 0 {
+		return "", time.Time{}, errSnapshotsDisabled
+	}
+
+	claims, err := m.verify(token)
+	if err != nil {
+		return "", time.Time{}, errSnapshotToken
+	}
+	if claims.Subject != subject {
+		return "", time.Time{}, errSnapshotToken
+	}
+	expiresAt := time.Unix(claims.ExpiresAt, 0)
+	if !m.now().Before(expiresAt) {
+		return "", time.Time{}, errSnapshotExpired
+	}
+	return claims.SnapshotID, expiresAt, nil
+}
+
+// OpenCount returns the number of snapshots held by this node
+func (m *SnapshotManager) OpenCount() int {
+	if m == nil {
+		return 0
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.held)
+}
+
+// release ends the transaction keeping a snapshot alive
+func (m *SnapshotManager) release(snapshotID string) {
+	m.mu.Lock()
+	snapshot, ok := m.held[snapshotID]
+	delete(m.held, snapshotID)
+	m.mu.Unlock()
+
+	if !ok || snapshot.tx == nil {
+		return
+	}
+	if snapshot.timer != nil {
+		snapshot.timer.Stop()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := snapshot.tx.Rollback(ctx); err != nil {
+		log.Debug().Err(err).Str("snapshot_id", snapshotID).Msg("Failed to release pagination snapshot")
+	}
+}
+
+// Close releases all snapshots held by this node
+func (m *SnapshotManager) Close() {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	ids := make([]string, 0, len(m.held))
+	for id := range m.held {
+		ids = append(ids, id)
+	}
+	m.mu.Unlock()
+
+	for _, id := range ids {
+		m.release(id)
+	}
+}
+
+// sign encodes claims as base64url(json) + "." + base64url(hmac)
+func (m *SnapshotManager) sign(claims snapshotClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(m.mac(encoded)), nil
+}
+
+// verify checks the signature of a token and decodes its claims
+func (m *SnapshotManager) verify(token string) (*snapshotClaims, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, errSnapshotToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, m.mac(encoded)) {
+		return nil, errSnapshotToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errSnapshotToken
+	}
+	var claims snapshotClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, errSnapshotToken
+	}
+	return &claims, nil
+}
+
+func (m *SnapshotManager) mac(data string) []byte {
+	h := hmac.New(sha256.New, m.secret)
+	h.Write([]byte("fluxbase-rest-snapshot:" + data))
+	return h.Sum(nil)
+}
+
+// snapshotSubject identifies the caller a snapshot token is bound to
+func snapshotSubject(c fiber.Ctx) string {
+	role := c.Locals("rls_role")
+	if role == nil {
+		role = "anon"
+	}
+	userID := c.Locals("rls_user_id")
+	if userID == nil {
+		userID = ""
+	}
+	return fmt.Sprintf("%v:%v", role, userID)
+}
+
+// wantsSnapshot reports whether a read request opens or continues a snapshot
+func wantsSnapshot(c fiber.Ctx) bool {
+	if c.Get(snapshotTokenHeader) != "" {
+		return true
+	}
+	requested, _, _ := parseSnapshotPreference(c.Get("Prefer"))
+	return requested
+}
+
+// resolveSnapshot opens or continues the snapshot of a read request and sets the response headers.
+// It returns "" when the request does not use snapshot pagination.
+func (h *RESTHandler) resolveSnapshot(ctx context.Context, c fiber.Ctx) (string, *restError) {
+	subject := snapshotSubject(c)
+
+	if token := c.Get(snapshotTokenHeader); token != "" {
+		snapshotID, expiresAt, err := h.snapshots.Resolve(token, subject)
+		if err != nil {
+			return "", snapshotError(err)
+		}
+		c.Set(snapshotTokenHeader, token)
+		c.Set(snapshotExpiresAtHeader, expiresAt.UTC().Format(time.RFC3339))
+		return snapshotID, nil
+	}
+
+	requested, ttl, err := parseSnapshotPreference(c.Get("Prefer"))
+	if err != nil {
+		return "", &restError{status: fiber.StatusBadRequest, message: err.Error()}
+	}
+	if !requested {
+		return "", nil
+	}
+
+	snapshotID, token, expiresAt, err := h.snapshots.Open(ctx, middleware.ConnectionPool(h.db, c), subject, ttl)
+	if err != nil {
+		return "", snapshotError(err)
+	}
+	c.Set(snapshotTokenHeader, token)
+	c.Set(snapshotExpiresAtHeader, expiresAt.UTC().Format(time.RFC3339))
+	return snapshotID, nil
+}
+
+// wrapRead runs a read with RLS context, inside the pagination snapshot when there is one.
+// Reads outside a snapshot may go to a read replica; snapshots live on the primary.
+func (h *RESTHandler) wrapRead(ctx context.Context, c fiber.Ctx, snapshotID string, fn func(tx pgx.Tx) error) error {
+	if snapshotID == "" {
+		return middleware.WrapWithRLSRead(ctx, h.db, c, fn)
+	}
+	return middleware.WrapWithRLSSnapshot(ctx, h.db, c, snapshotID, fn)
+}
+
+// snapshotError maps snapshot failures to responses
+func snapshotError(err error) *restError {
+	switch {
+	case errors.Is(err, errSnapshotsDisabled), errors.Is(err, errSnapshotToken):
+		return &restError{status: fiber.StatusBadRequest, message: err.Error()}
+	case errors.Is(err, errSnapshotExpired):
+		return &restError{status: fiber.StatusGone, message: err.Error()}
+	case errors.Is(err, errSnapshotLimit):
+		return &restError{status: fiber.StatusServiceUnavailable, message: err.Error()}
+	default:
+		log.Error().Err(err).Msg("Failed to open pagination snapshot")
+		return &restError{status: fiber.StatusInternalServerError, message: "Failed to open snapshot"}
+	}
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSnapshotPreference(t *testing.T) {
+	tests := []struct {
+		prefer    string
+		requested bool
+		ttl       time.Duration
+		wantErr   bool
+	}{
+		{"", false, 0, false},
+		{"count=exact", false, 0, false},
+		{"snapshot", true, 0, false},
+		{"count=exact, snapshot=600", true, 10 * time.Minute, false},
+		{"snapshot=0", false, 0, true},
+		{"snapshot=soon", false, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefer, func(t *testing.T) {
+			requested, ttl, err := parseSnapshotPreference(tt.prefer)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.requested, requested)
+			assert.Equal(t, tt.ttl, ttl)
+		})
+	}
+}
+
+func TestSnapshotManager_Tokens(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	m := NewSnapshotManager("secret", 5*time.Minute, time.Hour, 10)
+	m.now = func() time.Time { return now }
+
+	token, err := m.sign(snapshotClaims{SnapshotID: "00000003-0000001B-1", Subject: "authenticated:user-1", ExpiresAt: now.Add(5 * time.Minute).Unix()})
+	require.NoError(t, err)
+
+	t.Run("resolves for the same subject", func(t *testing.T) {
+		id, expiresAt, err := m.Resolve(token, "authenticated:user-1")
+		require.NoError(t, err)
+		assert.Equal(t, "00000003-0000001B-1", id)
+		assert.Equal(t, now.Add(5*time.Minute).Unix(), expiresAt.Unix())
+	})
+
+	t.Run("bound to the subject", func(t *testing.T) {
+		_, _, err := m.Resolve(token, "authenticated:user-2")
+		assert.ErrorIs(t, err, errSnapshotToken)
+	})
+
+	t.Run("rejects tampered tokens", func(t *testing.T) {
+		payload, sig, _ := strings.Cut(token, ".")
+		_, _, err := m.Resolve(payload+"x."+sig, "authenticated:user-1")
+		assert.ErrorIs(t, err, errSnapshotToken)
+
+		_, _, err = m.Resolve("garbage", "authenticated:user-1")
+		assert.ErrorIs(t, err, errSnapshotToken)
+	})
+
+	t.Run("rejects tokens signed with another secret", func(t *testing.T) {
+		other := NewSnapshotManager("other", 5*time.Minute, time.Hour, 10)
+		_, _, err := other.Resolve(token, "authenticated:user-1")
+		assert.ErrorIs(t, err, errSnapshotToken)
+	})
+
+	t.Run("expires", func(t *testing.T) {
+		m.now = func() time.Time { return now.Add(6 * time.Minute) }
+		defer func() { m.now = func() time.Time { return now } }()
+
+		_, _, err := m.Resolve(token, "authenticated:user-1")
+		assert.ErrorIs(t, err, errSnapshotExpired)
+	})
+}
+
+func TestSnapshotManager_Limits(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		m := NewSnapshotManager("secret", time.Minute, time.Hour, 0)
+		_, _, _, err := m.Open(context.Background(), nil, "anon:", 0)
+		assert.ErrorIs(t, err, errSnapshotsDisabled)
+		_, _, err = m.Resolve("token", "anon:")
+		assert.ErrorIs(t, err, errSnapshotsDisabled)
+	})
+
+	t.Run("too many open snapshots", func(t *testing.T) {
+		m := NewSnapshotManager("secret", time.Minute, time.Hour, 1)
+		m.held["00000003-0000001B-1"] = &heldSnapshot{}
+
+		_, _, _, err := m.Open(context.Background(), nil, "anon:", 0)
+		assert.ErrorIs(t, err, errSnapshotLimit)
+		assert.Equal(t, 1, m.OpenCount())
+
+		m.Close()
+		assert.Equal(t, 0, m.OpenCount())
+	})
+}
+
+func TestSnapshotError(t *testing.T) {
+	assert.Equal(t, fiber.StatusBadRequest, snapshotError(errSnapshotsDisabled).status)
+	assert.Equal(t, fiber.StatusBadRequest, snapshotError(errSnapshotToken).status)
+	assert.Equal(t, fiber.StatusGone, snapshotError(errSnapshotExpired).status)
+	assert.Equal(t, fiber.StatusServiceUnavailable, snapshotError(errSnapshotLimit).status)
+	assert.Equal(t, fiber.StatusInternalServerError, snapshotError(assert.AnError).status)
+}
+
+func TestRESTHandler_ResolveSnapshot(t *testing.T) {
+	handler := NewRESTHandler(nil, nil, nil, &config.Config{
+		Auth: config.AuthConfig{JWTSecret: "secret"},
+		API:  config.APIConfig{SnapshotTTL: 5 * time.Minute, SnapshotMaxTTL: time.Hour, MaxOpenSnapshots: 5},
+	})
+
+	resolve := func(headers map[string]string) (string, *restError) {
+		var id string
+		var resErr *restError
+		app := fiber.New()
+		app.Get("/", func(c fiber.Ctx) error {
+			c.Locals("rls_role", "authenticated")
+			c.Locals("rls_user_id", "user-1")
+			id, resErr = handler.resolveSnapshot(context.Background(), c)
+			return nil
+		})
+		req := httptest.NewRequest("GET", "/", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return id, resErr
+	}
+
+	t.Run("no snapshot requested", func(t *testing.T) {
+		id, err := resolve(nil)
+		assert.Nil(t, err)
+		assert.Empty(t, id)
+	})
+
+	t.Run("continues with a valid token", func(t *testing.T) {
+		token, signErr := handler.snapshots.sign(snapshotClaims{
+			SnapshotID: "00000003-0000001B-1",
+			Subject:    "authenticated:user-1",
+			ExpiresAt:  time.Now().Add(time.Minute).Unix(),
+		})
+		require.NoError(t, signErr)
+
+		id, err := resolve(map[string]string{snapshotTokenHeader: token})
+		assert.Nil(t, err)
+		assert.Equal(t, "00000003-0000001B-1", id)
+	})
+
+	t.Run("rejects an invalid token", func(t *testing.T) {
+		_, err := resolve(map[string]string{snapshotTokenHeader: "forged.token"})
+		require.NotNil(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, err.status)
+	})
+
+	t.Run("rejects an invalid preference", func(t *testing.T) {
+		_, err := resolve(map[string]string{"Prefer": "snapshot=-1"})
+		require.NotNil(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, err.status)
+	})
+}
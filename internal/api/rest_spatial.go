@@ -0,0 +1,222 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/nimbleflux/fluxbase/internal/database"
+)
+
+// distanceField is the computed field holding the distance of a row from the geometry of an
+// st_distance ordering, named like the distance returned by vector searches
+const distanceField = "_distance"
+
+// errInvalidBBox is returned for bbox filter values that are neither bounds nor a geometry
+var errInvalidBBox = errors.New("bbox value must be minLon,minLat,maxLon,maxLat or a GeoJSON geometry")
+
+// parseGeoJSONGeometry checks that value, GeoJSON text or a decoded JSON object, is a GeoJSON
+// geometry and returns it as text for ST_GeomFromGeoJSON
+func parseGeoJSONGeometry(value interface{}) (string, error) {
+	var raw []byte
+	if s, ok := value.(string); ok {
+		raw = []byte(strings.TrimSpace(s))
+	} else {
+		var err error
+		if raw, err = json.Marshal(value); err != nil {
+			return "", fmt.Errorf("invalid GeoJSON geometry: %w", err)
+		}
+	}
+
+	var geometry map[string]interface{}
+	if err := json.Unmarshal(raw, &geometry); err != nil || !isGeoJSON(geometry) {
+		return "", fmt.Errorf("invalid GeoJSON geometry")
+	}
+	return string(raw), nil
+}
+
+// parseBBoxValue parses the value of a bbox filter: bounds as "minLon,minLat,maxLon,maxLat"
+// or a JSON array of four numbers, returned as []float64, or a GeoJSON geometry whose bounding
+// box is used, returned as text
+func parseBBoxValue(value interface{}) (interface{}, error) {
+	var parts []string
+	switch v := value.(type) {
+	case []float64:
+		return v, nil
+	case string:
+		s := strings.TrimSpace(v)
+		if strings.HasPrefix(s, "{") {
+			return parseGeoJSONGeometry(s)
+		}
+		parts = strings.Split(strings.Trim(s, "()[]"), ",")
+	case []interface{}:
+		for _, item := range v {
+			parts = append(parts, fmt.Sprint(item))
+		}
+	case map[string]interface{}:
+		return parseGeoJSONGeometry(v)
+	default:
+		return nil, errInvalidBBox
+	}
+
+	if len(parts) != 4 {
+		return nil, errInvalidBBox
+	}
+	bounds := make([]float64, 4)
+	for i, part := range parts {
+		n, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, errInvalidBBox
+		}
+		bounds[i] = n
+	}
+	if bounds[0] > bounds[2] || bounds[1] > bounds[3] {
+		return nil, fmt.Errorf("bbox minimum %g,%g must not exceed maximum %g,%g", bounds[0], bounds[1], bounds[2], bounds[3])
+	}
+	return bounds, nil
+}
+
+// parseBBoxValues parses the values of bbox filters, including negated ones, so that
+// malformed bounds are rejected before the query runs
+func parseBBoxValues(filters []Filter) error {
+	for i := range filters {
+		f := &filters[i]
+		switch f.Operator {
+		case OpBBox:
+			value, err := parseBBoxValue(f.Value)
+			if err != nil {
+				return err
+			}
+			f.Value = value
+		case OpNot:
+			value, ok := f.Value.(string)
+			if !ok {
+				continue
+			}
+			if bounds, ok := strings.CutPrefix(value, string(OpBBox)+"."); ok {
+				if _, err := parseBBoxValue(bounds); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// bboxConditionSQL builds the condition of a bbox filter: the bounding box of the column
+// intersects the bounds, taken as WGS 84 longitudes and latitudes, or the geometry's box
+func bboxConditionSQL(f Filter, colExpr string, argCounter *int) (string, interface{}) {
+	value, err := parseBBoxValue(f.Value)
+	if err != nil {
+		return "", nil
+	}
+
+	if bounds, ok := value.([]float64); ok {
+		n := *argCounter
+		sql := fmt.Sprintf("%s && ST_MakeEnvelope($%d, $%d, $%d, $%d, 4326)", colExpr, n, n+1, n+2, n+3)
+		*argCounter += 4
+		return sql, []interface{}{bounds[0], bounds[1], bounds[2], bounds[3]}
+	}
+
+	sql := fmt.Sprintf("%s && ST_GeomFromGeoJSON($%d)", colExpr, *argCounter)
+	*argCounter++
+	return sql, value
+}
+
+// parseDistanceOrder parses the distance ordering format column.st_distance.{geojson}, with
+// optional .asc/.desc and .nullsfirst/.nullslast after the geometry. ok is false for orders
+// in other formats.
+func parseDistanceOrder(order string) (orderBy OrderBy, ok bool, err error) {
+	const marker = "." + string(OpSTDistance) + "."
+	idx := strings.Index(order, marker)
+	if idx <= 0 {
+		return OrderBy{}, false, nil
+	}
+
+	column := order[:idx]
+	if !isValidIdentifier(column) {
+		return OrderBy{}, true, fmt.Errorf("invalid order column name: %s", column)
+	}
+
+	// The geometry runs up to the brace closing its opening brace
+	remainder := order[idx+len(marker):]
+	end, depth := -1, 0
+	for i, ch := range remainder {
+		switch ch {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		}
+		if depth == 0 {
+			end = i
+			break
+		}
+	}
+	if !strings.HasPrefix(remainder, "{") || end < 0 {
+		return OrderBy{}, true, fmt.Errorf("st_distance ordering of %s needs a GeoJSON geometry", column)
+	}
+	geometry, err := parseGeoJSONGeometry(remainder[:end+1])
+	if err != nil {
+		return OrderBy{}, true, fmt.Errorf("st_distance ordering of %s: %w", column, err)
+	}
+
+	orderBy = OrderBy{Column: column, Geometry: geometry}
+	if modifiers := remainder[end+1:]; modifiers != "" {
+		for _, modifier := range strings.Split(strings.TrimPrefix(modifiers, "."), ".") {
+			switch modifier {
+			case "asc":
+				orderBy.Desc = false
+			case "desc":
+				orderBy.Desc = true
+			case "nullsfirst":
+				orderBy.Nulls = "first"
+			case "nullslast":
+				orderBy.Nulls = "last"
+			default:
+				return OrderBy{}, true, fmt.Errorf("invalid st_distance ordering modifier %q", modifier)
+			}
+		}
+	}
+	return orderBy, true, nil
+}
+
+// distanceOrder returns the first st_distance ordering of a query, or nil
+func (params *QueryParams) distanceOrder() *OrderBy {
+	for i := range params.Order {
+		if params.Order[i].Geometry != "" {
+			return &params.Order[i]
+		}
+	}
+	return nil
+}
+
+// distanceSelectSQL builds the _distance column of an st_distance ordering: the distance in
+// the units of the column, meters for geography columns
+func distanceSelectSQL(order OrderBy, argCounter *int) (string, interface{}) {
+	sql := fmt.Sprintf("ST_Distance(%s, ST_GeomFromGeoJSON($%d)) AS %s",
+		quoteIdentifier(order.Column), *argCounter, quoteIdentifier(distanceField))
+	*argCounter++
+	return sql, order.Geometry
+}
+
+// checkDistanceOrders checks that st_distance orderings name geometry or geography columns
+// of the table
+func checkDistanceOrders(table *database.TableInfo, orders []OrderBy) error {
+	for _, order := range orders {
+		if order.Geometry == "" {
+			continue
+		}
+		col := table.GetColumn(order.Column)
+		if col == nil {
+			return fmt.Errorf("unknown order column: %s", order.Column)
+		}
+		if !isGeometryColumn(col.DataType) {
+			return fmt.Errorf("column %s is not a geometry or geography column and can't be ordered by st_distance", order.Column)
+		}
+	}
+	return nil
+}
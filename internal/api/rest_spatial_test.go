@@ -0,0 +1,148 @@
+package api
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPoint = `{"type":"Point","coordinates":[-122.4,37.8]}`
+
+func spatialTable() database.TableInfo {
+	return database.TableInfo{
+		Schema: "public",
+		Name:   "places",
+		Columns: []database.ColumnInfo{
+			{Name: "id", DataType: "integer"},
+			{Name: "name", DataType: "text"},
+			{Name: "location", DataType: "geography(Point,4326)"},
+		},
+	}
+}
+
+func TestParseBBoxValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    interface{}
+		wantErr string
+	}{
+		{"bounds", "-122.5,37.7,-122.3,37.9", []float64{-122.5, 37.7, -122.3, 37.9}, ""},
+		{"bounds in parentheses", "(1, 2, 3, 4)", []float64{1, 2, 3, 4}, ""},
+		{"JSON array", []interface{}{1.0, 2.0, 3.0, 4.0}, []float64{1, 2, 3, 4}, ""},
+		{"GeoJSON text", testPoint, testPoint, ""},
+		{"GeoJSON object", map[string]interface{}{"type": "Point", "coordinates": []interface{}{1.0, 2.0}}, `{"coordinates":[1,2],"type":"Point"}`, ""},
+		{"three numbers", "1,2,3", nil, "minLon,minLat"},
+		{"not numbers", "a,b,c,d", nil, "minLon,minLat"},
+		{"infinite", "1,2,Inf,4", nil, "minLon,minLat"},
+		{"inverted", "3,2,1,4", nil, "must not exceed"},
+		{"not a geometry", `{"type":"Feature"}`, nil, "invalid GeoJSON geometry"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseBBoxValue(tt.value)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestQueryParser_BBoxFilter(t *testing.T) {
+	parser := NewQueryParser(testConfig())
+
+	values := url.Values{"location": {"bbox.-122.5,37.7,-122.3,37.9"}}
+	params, err := parser.Parse(values)
+	require.NoError(t, err)
+	require.Len(t, params.Filters, 1)
+	assert.Equal(t, OpBBox, params.Filters[0].Operator)
+
+	argCounter := 1
+	sql, args := filterToSQL(params.Filters[0], &argCounter)
+	assert.Equal(t, `"location" && ST_MakeEnvelope($1, $2, $3, $4, 4326)`, sql)
+	assert.Equal(t, []interface{}{-122.5, 37.7, -122.3, 37.9}, args)
+	assert.Equal(t, 5, argCounter)
+
+	argCounter = 1
+	sql, args = filterToSQL(Filter{Column: "location", Operator: OpNot, Value: "bbox." + testPoint}, &argCounter)
+	assert.Equal(t, `NOT ("location" && ST_GeomFromGeoJSON($1))`, sql)
+	assert.Equal(t, testPoint, args)
+
+	for _, value := range []string{"bbox.1,2,3", "not.bbox.4,3,2,1"} {
+		_, err := parser.Parse(url.Values{"location": {value}})
+		assert.Error(t, err, value)
+	}
+}
+
+func TestParseDistanceOrder(t *testing.T) {
+	tests := []struct {
+		order   string
+		want    OrderBy
+		wantErr string
+	}{
+		{"location.st_distance." + testPoint, OrderBy{Column: "location", Geometry: testPoint}, ""},
+		{"location.st_distance." + testPoint + ".desc.nullslast", OrderBy{Column: "location", Geometry: testPoint, Desc: true, Nulls: "last"}, ""},
+		{"location.st_distance.-122.4,37.8", OrderBy{}, "needs a GeoJSON geometry"},
+		{"location.st_distance." + `{"type":"Point"`, OrderBy{}, "needs a GeoJSON geometry"},
+		{"location.st_distance." + `{"type":"Feature"}`, OrderBy{}, "invalid GeoJSON geometry"},
+		{"location.st_distance." + testPoint + ".sideways", OrderBy{}, "invalid st_distance ordering modifier"},
+		{"loc;ation.st_distance." + testPoint, OrderBy{}, "invalid order column name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			got, ok, err := parseDistanceOrder(tt.order)
+			assert.True(t, ok)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, ok, err := parseDistanceOrder("name.asc")
+	assert.False(t, ok)
+	assert.NoError(t, err)
+}
+
+func TestBuildSelectQuery_NearestWithDistance(t *testing.T) {
+	handler := &RESTHandler{parser: NewQueryParser(testConfig())}
+	values := url.Values{
+		"select": {"id,name,_distance"},
+		"order":  {"location.st_distance." + testPoint + ",id.asc"},
+		"name":   {"neq.closed"},
+		"limit":  {"5"},
+	}
+	table := spatialTable()
+	params, err := handler.parser.Parse(values)
+	require.NoError(t, err)
+	require.NoError(t, checkDistanceOrders(&table, params.Order))
+
+	query, args := handler.buildSelectQuery(table, params, nil)
+	assert.Equal(t, `SELECT "id", "name", ST_Distance("location", ST_GeomFromGeoJSON($1)) AS "_distance" FROM "public"."places"`+
+		` WHERE "name" != $2 ORDER BY "location" <-> ST_GeomFromGeoJSON($3) ASC, "id" ASC LIMIT $4`, query)
+	assert.Equal(t, []interface{}{testPoint, "closed", testPoint, 5}, args)
+
+	t.Run("_distance needs a distance ordering", func(t *testing.T) {
+		params, err := handler.parser.Parse(url.Values{"select": {"id,_distance"}})
+		require.NoError(t, err)
+		query, _ := handler.buildSelectQuery(spatialTable(), params, nil)
+		assert.Equal(t, `SELECT "id" FROM "public"."places"`, query)
+	})
+}
+
+func TestCheckDistanceOrders(t *testing.T) {
+	table := spatialTable()
+	assert.NoError(t, checkDistanceOrders(&table, []OrderBy{{Column: "name"}, {Column: "location", Geometry: testPoint}}))
+	assert.ErrorContains(t, checkDistanceOrders(&table, []OrderBy{{Column: "name", Geometry: testPoint}}), "not a geometry or geography column")
+	assert.ErrorContains(t, checkDistanceOrders(&table, []OrderBy{{Column: "missing", Geometry: testPoint}}), "unknown order column")
+}
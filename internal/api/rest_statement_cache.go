@@ -0,0 +1,105 @@
+package api
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/nimbleflux/fluxbase/internal/observability"
+)
+
+// statementCache decides which REST reads run as cached prepared statements. The SQL the
+// query builder produces is the normalized form of a request: the table, selected columns,
+// filter columns and operators, order and paging make up the text while every value is a
+// placeholder, so requests of the same shape produce the same SQL.
+//
+// The cache remembers the most recently used shapes. A shape seen before is a hit and runs
+// with pgx.QueryExecModeCacheStatement, which prepares it once per connection and then skips
+// parsing and planning; a new shape is a miss and runs unprepared, so one-off queries don't
+// crowd the hot ones out of the connections' statement caches.
+type statementCache struct {
+	mu       sync.Mutex
+	capacity int
+	shapes   map[string]*list.Element
+	order    *list.List // most recently used first, values are the SQL
+	metrics  *observability.Metrics
+}
+
+// newStatementCache creates a statement cache tracking up to capacity query shapes. A
+// capacity of 0 disables it.
+func newStatementCache(capacity int) *statementCache {
+	return &statementCache{
+		capacity: capacity,
+		shapes:   make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// enabled reports whether reads may run as cached statements
+func (sc *statementCache) enabled() bool {
+	return sc != nil && sc.capacity > 0
+}
+
+// lookup records the shape of query and reports whether it was seen before
+func (sc *statementCache) lookup(query string) bool {
+	sc.mu.Lock()
+	el, hit := sc.shapes[query]
+	if hit {
+		sc.order.MoveToFront(el)
+	} else {
+		sc.shapes[query] = sc.order.PushFront(query)
+		if sc.order.Len() > sc.capacity {
+			oldest := sc.order.Back()
+			sc.order.Remove(oldest)
+			delete(sc.shapes, oldest.Value.(string))
+		}
+	}
+	sc.mu.Unlock()
+
+	if sc.metrics != nil {
+		sc.metrics.RecordRESTStatementCache(hit)
+	}
+	return hit
+}
+
+// queryArgs returns the arguments to run query with, asking pgx for a cached prepared
+// statement when its shape was seen before
+func (sc *statementCache) queryArgs(query string, args []interface{}) []interface{} {
+	if !sc.enabled() || !sc.lookup(query) {
+		return args
+	}
+	return append([]interface{}{pgx.QueryExecModeCacheStatement}, args...)
+}
+
+// isStalePlanError reports whether a cached statement failed because a schema change altered
+// its result columns. pgx drops the statement from the connection's cache on such an error.
+func isStalePlanError(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "0A000" && strings.Contains(pgErr.Message, "cached plan must not change result type")
+}
+
+// SetMetrics records statement cache hits and misses in Prometheus
+func (h *RESTHandler) SetMetrics(metrics *observability.Metrics) {
+	h.statements.metrics = metrics
+}
+
+// wrapCachedRead runs query like wrapRead, passing fn the arguments to run it with. Outside
+// pagination snapshots, hot query shapes run as cached prepared statements, and a read whose
+// cached statement went stale after a schema change is retried once without it.
+func (h *RESTHandler) wrapCachedRead(ctx context.Context, c fiber.Ctx, snapshotID, query string, args []interface{}, fn func(tx pgx.Tx, args []interface{}) error) error {
+	if snapshotID != "" || !h.statements.enabled() {
+		return h.wrapRead(ctx, c, snapshotID, func(tx pgx.Tx) error { return fn(tx, args) })
+	}
+
+	queryArgs := h.statements.queryArgs(query, args)
+	err := h.wrapRead(ctx, c, "", func(tx pgx.Tx) error { return fn(tx, queryArgs) })
+	if isStalePlanError(err) {
+		err = h.wrapRead(ctx, c, "", func(tx pgx.Tx) error { return fn(tx, args) })
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementCache_QueryArgs(t *testing.T) {
+	t.Run("prepares shapes seen before", func(t *testing.T) {
+		sc := newStatementCache(10)
+		query := `SELECT "id" FROM "public"."todos" WHERE "id" = $1`
+
+		assert.Equal(t, []interface{}{1}, sc.queryArgs(query, []interface{}{1}))
+		assert.Equal(t, []interface{}{pgx.QueryExecModeCacheStatement, 2}, sc.queryArgs(query, []interface{}{2}))
+	})
+
+	t.Run("evicts the least recently used shape", func(t *testing.T) {
+		sc := newStatementCache(2)
+		sc.lookup("a")
+		sc.lookup("b")
+		assert.True(t, sc.lookup("a"))
+
+		sc.lookup("c") // evicts b
+		assert.False(t, sc.lookup("b"))
+		assert.Len(t, sc.shapes, 2)
+		assert.Equal(t, 2, sc.order.Len())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		sc := newStatementCache(0)
+		assert.False(t, sc.enabled())
+		sc.queryArgs("SELECT 1", nil)
+		assert.Equal(t, []interface{}{1}, sc.queryArgs("SELECT 1", []interface{}{1}))
+
+		var nilCache *statementCache
+		assert.False(t, nilCache.enabled())
+	})
+}
+
+func TestStatementCache_SameShapeSameSQL(t *testing.T) {
+	parser := NewQueryParser(testConfig())
+	handler := &RESTHandler{parser: parser}
+	table := database.TableInfo{
+		Schema: "public",
+		Name:   "items",
+		Columns: []database.ColumnInfo{
+			{Name: "id", DataType: "integer"},
+			{Name: "name", DataType: "text"},
+		},
+	}
+
+	build := func(rawQuery string) string {
+		values, err := url.ParseQuery(rawQuery)
+		require.NoError(t, err)
+		params, err := parser.Parse(values)
+		require.NoError(t, err)
+		query, _ := handler.buildSelectQuery(table, params, nil)
+		return query
+	}
+
+	// Values are placeholders, so only the shape of a request changes the SQL
+	assert.Equal(t, build("select=id,name&id=eq.1&order=name.asc&limit=10"), build("select=id,name&id=eq.42&order=name.asc&limit=5"))
+	assert.NotEqual(t, build("select=id,name&id=eq.1"), build("select=id,name&id=gt.1"))
+	assert.NotEqual(t, build("select=id,name&id=eq.1"), build("select=id&id=eq.1"))
+}
+
+func TestIsStalePlanError(t *testing.T) {
+	assert.True(t, isStalePlanError(fmt.Errorf("query: %w", &pgconn.PgError{Code: "0A000", Message: "cached plan must not change result type"})))
+	assert.False(t, isStalePlanError(&pgconn.PgError{Code: "0A000", Message: "FOR UPDATE is not allowed with aggregate functions"}))
+	assert.False(t, isStalePlanError(&pgconn.PgError{Code: "42P01"}))
+	assert.False(t, isStalePlanError(nil))
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/nimbleflux/fluxbase/internal/textsearch"
+)
+
+// isTextSearchOperator reports whether op is one of the full text search operators
+func isTextSearchOperator(op FilterOperator) bool {
+	return op == OpTextSearch || op == OpPhraseSearch || op == OpWebSearch
+}
+
+// isTextSearchFilter reports whether a filter is a full text search, possibly negated
+func isTextSearchFilter(f Filter) bool {
+	if isTextSearchOperator(f.Operator) {
+		return true
+	}
+	if f.Operator != OpNot {
+		return false
+	}
+	value, ok := f.Value.(string)
+	if !ok {
+		return false
+	}
+	op, _, _ := strings.Cut(value, ".")
+	return isTextSearchOperator(FilterOperator(op))
+}
+
+// splitTextSearchLanguage splits a text search operator with a language override, such as
+// "fts(fr)", into the operator and the resolved text search configuration. Other operators
+// are returned unchanged with no language.
+func splitTextSearchLanguage(op string) (FilterOperator, string, error) {
+	name, language, ok := strings.Cut(op, "(")
+	if !ok || !isTextSearchOperator(FilterOperator(name)) {
+		return FilterOperator(op), "", nil
+	}
+	language, ok = strings.CutSuffix(language, ")")
+	if !ok {
+		return "", "", fmt.Errorf("invalid text search operator %q, expected %s(language)", op, name)
+	}
+	resolved, err := textsearch.ResolveLanguage(language)
+	if err != nil {
+		return "", "", err
+	}
+	return FilterOperator(name), resolved, nil
+}
+
+// parseTextSearchLanguages moves the language overrides of text search filters, such as
+// content.fts(fr)=term or content=not.fts(fr).term, out of their operators into the
+// filters' Language
+func parseTextSearchLanguages(filters []Filter) error {
+	for i := range filters {
+		f := &filters[i]
+		if f.Operator == OpNot {
+			value, ok := f.Value.(string)
+			if !ok {
+				continue
+			}
+			op, rest, ok := strings.Cut(value, ".")
+			if !ok {
+				continue
+			}
+			nestedOp, language, err := splitTextSearchLanguage(op)
+			if err != nil {
+				return err
+			}
+			if language != "" {
+				f.Language = language
+				f.Value = string(nestedOp) + "." + rest
+			}
+			continue
+		}
+
+		op, language, err := splitTextSearchLanguage(string(f.Operator))
+		if err != nil {
+			return err
+		}
+		if language != "" {
+			f.Operator = op
+			f.Language = language
+		}
+	}
+	return nil
+}
+
+// applyTextSearchColumn completes a text search filter on a column: without a language
+// override it searches in the column's configured language, and tsvector columns are
+// searched as they are instead of being converted
+func applyTextSearchColumn(f *Filter, col *database.ColumnInfo) {
+	if f.Language == "" && col.TextSearch != nil {
+		f.Language = col.TextSearch.Language
+	}
+	f.IsTSVector = strings.EqualFold(col.DataType, "tsvector")
+}
+
+// textSearchConditionSQL builds the condition of a text search filter, where toQuery is the
+// tsquery function of its operator. Filters with a language bind it as the first argument
+// and use it for both the document and the query; the others use the database default.
+func textSearchConditionSQL(f Filter, colExpr, toQuery string, argCounter *int) (string, interface{}) {
+	if f.Language == "" {
+		sql := fmt.Sprintf("%s @@ %s($%d)", colExpr, toQuery, *argCounter)
+		*argCounter++
+		return sql, f.Value
+	}
+
+	languageArg := *argCounter
+	document := colExpr
+	if !f.IsTSVector {
+		document = fmt.Sprintf("to_tsvector($%d::regconfig, %s)", languageArg, colExpr)
+	}
+	sql := fmt.Sprintf("%s @@ %s($%d::regconfig, $%d)", document, toQuery, languageArg, languageArg+1)
+	*argCounter += 2
+	return sql, []interface{}{f.Language, f.Value}
+}
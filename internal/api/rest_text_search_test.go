@@ -0,0 +1,159 @@
+package api
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryParser_TextSearchLanguage(t *testing.T) {
+	parser := NewQueryParser(testConfig())
+
+	tests := []struct {
+		name     string
+		query    string
+		want     Filter
+		wantNone bool
+	}{
+		{"classic format", "content.fts(fr)=bonjour",
+			Filter{Column: "content", Operator: OpTextSearch, Value: "bonjour", Language: "french"}, false},
+		{"postgrest format", "content=wfts(english).cats -dogs",
+			Filter{Column: "content", Operator: OpWebSearch, Value: "cats -dogs", Language: "english"}, false},
+		{"schema-qualified configuration", "content.plfts(public.french_unaccent)=la maison",
+			Filter{Column: "content", Operator: OpPhraseSearch, Value: "la maison", Language: "public.french_unaccent"}, false},
+		{"negated", "content=not.fts(de).hallo",
+			Filter{Column: "content", Operator: OpNot, Value: "fts.hallo", Language: "german"}, false},
+		{"logical group", "or=(title.fts(fr).chat,body.fts.chat)",
+			Filter{Column: "title", Operator: OpTextSearch, Value: "chat", Language: "french", IsOr: true}, false},
+		{"without language", "content.fts=hello",
+			Filter{Column: "content", Operator: OpTextSearch, Value: "hello"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+			params, err := parser.Parse(values)
+			require.NoError(t, err)
+			require.NotEmpty(t, params.Filters)
+			assert.Equal(t, tt.want, params.Filters[0])
+		})
+	}
+
+	for _, query := range []string{"content.fts(fr'--)=x", "content=fts(fr.x", "content.fts()=x"} {
+		t.Run("rejects "+query, func(t *testing.T) {
+			values, err := url.ParseQuery(query)
+			require.NoError(t, err)
+			_, err = parser.Parse(values)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCoerceFilterValues_TextSearch(t *testing.T) {
+	table := database.TableInfo{
+		Schema: "public",
+		Name:   "posts",
+		Columns: []database.ColumnInfo{
+			{Name: "title", DataType: "text", TextSearch: &database.TextSearchInfo{Language: "french", Weight: "A"}},
+			{Name: "body", DataType: "text"},
+			{Name: "search", DataType: "tsvector", TextSearch: &database.TextSearchInfo{Language: "english"}},
+		},
+	}
+
+	filters := []Filter{
+		{Column: "title", Operator: OpTextSearch, Value: "chat"},
+		{Column: "title", Operator: OpTextSearch, Value: "cat", Language: "english"},
+		{Column: "body", Operator: OpTextSearch, Value: "cat"},
+		{Column: "search", Operator: OpNot, Value: "wfts.cat"},
+	}
+	require.NoError(t, coerceFilterValues(&table, filters))
+
+	assert.Equal(t, "french", filters[0].Language, "configured language of the column")
+	assert.Equal(t, "english", filters[1].Language, "override wins over the configuration")
+	assert.Empty(t, filters[2].Language, "unconfigured columns keep the database default")
+	assert.Equal(t, "english", filters[3].Language)
+	assert.True(t, filters[3].IsTSVector)
+}
+
+func TestFilterToSQL_TextSearch(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   Filter
+		wantSQL  string
+		wantArgs interface{}
+	}{
+		{"database default", Filter{Column: "body", Operator: OpTextSearch, Value: "cat"},
+			`"body" @@ plainto_tsquery($1)`, "cat"},
+		{"text column with language", Filter{Column: "body", Operator: OpPhraseSearch, Value: "le chat", Language: "french"},
+			`to_tsvector($1::regconfig, "body") @@ phraseto_tsquery($1::regconfig, $2)`, []interface{}{"french", "le chat"}},
+		{"tsvector column with language", Filter{Column: "search", Operator: OpWebSearch, Value: "cat", Language: "english", IsTSVector: true},
+			`"search" @@ websearch_to_tsquery($1::regconfig, $2)`, []interface{}{"english", "cat"}},
+		{"negated", Filter{Column: "body", Operator: OpNot, Value: "fts.cat", Language: "english"},
+			`NOT (to_tsvector($1::regconfig, "body") @@ plainto_tsquery($1::regconfig, $2))`, []interface{}{"english", "cat"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argCounter := 1
+			sql, args := filterToSQL(tt.filter, &argCounter)
+			assert.Equal(t, tt.wantSQL, sql)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+
+	t.Run("arguments follow the language", func(t *testing.T) {
+		params := &QueryParams{Filters: []Filter{
+			{Column: "body", Operator: OpTextSearch, Value: "cat", Language: "english"},
+			{Column: "id", Operator: OpEqual, Value: "7"},
+		}}
+		argCounter := 1
+		where, args := params.buildWhereClause(&argCounter)
+		assert.Contains(t, where, `"id" = $3`)
+		assert.Equal(t, []interface{}{"english", "cat", "7"}, args)
+	})
+}
+
+func TestBuildSelectQuery_TextSearch(t *testing.T) {
+	table := database.TableInfo{
+		Schema: "public",
+		Name:   "posts",
+		Columns: []database.ColumnInfo{
+			{Name: "id", DataType: "integer"},
+			{Name: "title", DataType: "text", TextSearch: &database.TextSearchInfo{Language: "french", Weight: "A"}},
+			{Name: "body", DataType: "text"},
+			{Name: "search", DataType: "tsvector", TextSearch: &database.TextSearchInfo{Language: "english"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{"configured column language", "select=id&title=fts.chat",
+			`SELECT "id" FROM "public"."posts" WHERE to_tsvector($1::regconfig, "title") @@ plainto_tsquery($1::regconfig, $2)`,
+			[]interface{}{"french", "chat"}},
+		{"generated vector with language override", "select=id&search=wfts(de).katze -hund&order=id.desc&limit=10",
+			`SELECT "id" FROM "public"."posts" WHERE "search" @@ websearch_to_tsquery($1::regconfig, $2) ORDER BY "id" DESC LIMIT $3`,
+			[]interface{}{"german", "katze -hund", 10}},
+		{"negated phrase on an unconfigured column", "select=id&body=not.plfts.le chat&id=gt.5",
+			`SELECT "id" FROM "public"."posts" WHERE NOT ("body" @@ phraseto_tsquery($1)) AND "id" > $2`,
+			[]interface{}{"le chat", int64(5)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+			params, err := NewQueryParser(testConfig()).Parse(values)
+			require.NoError(t, err)
+			require.NoError(t, coerceFilterValues(&table, params.Filters))
+
+			sql, args := (&RESTHandler{}).buildSelectQuery(table, params, nil)
+			assert.Equal(t, tt.wantSQL, sql)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
@@ -2,8 +2,11 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
+	"slices"
 	"strings"
 
+	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/nimbleflux/fluxbase/internal/database"
@@ -86,22 +89,37 @@ func pgxRowsToJSON(rows pgx.Rows) ([]map[string]interface{}, error) {
 }
 
 // getConflictTarget determines the conflict target for ON CONFLICT clause
-// Returns the primary key columns as a comma-separated quoted string, or empty string if no PK exists
+// Returns the conflict columns as a comma-separated quoted string, or empty string if there are none
 func (h *RESTHandler) getConflictTarget(table database.TableInfo) string {
-	if len(table.PrimaryKey) == 0 {
+	columns := h.getConflictTargetUnquoted(table)
+	if len(columns) == 0 {
 		return ""
 	}
 	// Quote each column name to prevent SQL injection
-	quotedColumns := make([]string, 0, len(table.PrimaryKey))
-	for _, col := range table.PrimaryKey {
+	quotedColumns := make([]string, 0, len(columns))
+	for _, col := range columns {
 		quotedColumns = append(quotedColumns, quoteIdentifier(col))
 	}
 	return strings.Join(quotedColumns, ", ")
 }
 
-// getConflictTargetUnquoted returns unquoted column names for comparison purposes
+// getConflictTargetUnquoted returns the primary key columns or, for tables without one,
+// the columns of their only unique index
 func (h *RESTHandler) getConflictTargetUnquoted(table database.TableInfo) []string {
-	return table.PrimaryKey
+	if len(table.PrimaryKey) > 0 {
+		return table.PrimaryKey
+	}
+	var unique [][]string
+	for _, idx := range table.Indexes {
+		if idx.IsUnique && len(idx.Columns) > 0 {
+			unique = append(unique, idx.Columns)
+		}
+	}
+	// With several unique indexes the client must pick one with on_conflict
+	if len(unique) != 1 {
+		return table.PrimaryKey
+	}
+	return unique[0]
 }
 
 // isInConflictTarget checks if a column is part of the conflict target columns
@@ -113,3 +131,66 @@ func (h *RESTHandler) isInConflictTarget(column string, conflictTargetColumns []
 	}
 	return false
 }
+
+// buildOnConflictClause builds the ON CONFLICT clause of an upsert inserting columnNames.
+// The conflict target is onConflict (comma separated columns) or the primary key. Merged
+// rows update the inserted columns, and with defaultToNull every other column is set to NULL.
+// doNothing reports whether conflicting rows are skipped, in which case they are not returned.
+func (h *RESTHandler) buildOnConflictClause(table database.TableInfo, onConflict string, columnNames []string, ignoreDuplicates, defaultToNull bool) (clause string, doNothing bool, err error) {
+	var conflictTarget string
+	var conflictTargetColumns []string
+
+	if onConflict != "" {
+		conflictCols := strings.Split(onConflict, ",")
+		quotedConflictCols := make([]string, 0, len(conflictCols))
+		for _, col := range conflictCols {
+			col = strings.TrimSpace(col)
+			if !h.columnExists(table, col) {
+				return "", false, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Unknown column in on_conflict: %s", col))
+			}
+			quotedConflictCols = append(quotedConflictCols, quoteIdentifier(col))
+			conflictTargetColumns = append(conflictTargetColumns, col)
+		}
+		conflictTarget = strings.Join(quotedConflictCols, ", ")
+	} else {
+		conflictTarget = h.getConflictTarget(table)
+		conflictTargetColumns = h.getConflictTargetUnquoted(table)
+	}
+
+	if conflictTarget == "" {
+		return "", false, fiber.NewError(fiber.StatusBadRequest, "Cannot perform upsert: table has no primary key or unique constraint")
+	}
+
+	if ignoreDuplicates {
+		return fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", conflictTarget), true, nil
+	}
+
+	// Update every inserted column except the conflict target
+	updateClauses := make([]string, 0, len(columnNames))
+	if defaultToNull {
+		for _, tableCol := range table.Columns {
+			if h.isInConflictTarget(tableCol.Name, conflictTargetColumns) {
+				continue
+			}
+			quoted := quoteIdentifier(tableCol.Name)
+			if slices.Contains(columnNames, tableCol.Name) {
+				updateClauses = append(updateClauses, fmt.Sprintf("%s = EXCLUDED.%s", quoted, quoted))
+			} else {
+				updateClauses = append(updateClauses, fmt.Sprintf("%s = NULL", quoted))
+			}
+		}
+	} else {
+		for _, col := range columnNames {
+			if !h.isInConflictTarget(col, conflictTargetColumns) {
+				quoted := quoteIdentifier(col)
+				updateClauses = append(updateClauses, fmt.Sprintf("%s = EXCLUDED.%s", quoted, quoted))
+			}
+		}
+	}
+
+	// Only conflict target columns were inserted, so there is nothing to update
+	if len(updateClauses) == 0 {
+		return fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", conflictTarget), true, nil
+	}
+	return fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", conflictTarget, strings.Join(updateClauses, ", ")), false, nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/nimbleflux/fluxbase/internal/database"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetConflictTarget(t *testing.T) {
@@ -300,3 +301,111 @@ func TestConflictTarget_InvalidIdentifiers(t *testing.T) {
 		})
 	}
 }
+
+func TestConflictTarget_UniqueIndexFallback(t *testing.T) {
+	h := &RESTHandler{}
+
+	t.Run("only unique index is used without a primary key", func(t *testing.T) {
+		table := database.TableInfo{
+			Indexes: []database.IndexInfo{
+				{Name: "events_created_at_idx", Columns: []string{"created_at"}},
+				{Name: "events_source_external_id_key", Columns: []string{"source", "external_id"}, IsUnique: true},
+			},
+		}
+		assert.Equal(t, `"source", "external_id"`, h.getConflictTarget(table))
+	})
+
+	t.Run("primary key wins over unique index", func(t *testing.T) {
+		table := database.TableInfo{
+			PrimaryKey: []string{"id"},
+			Indexes: []database.IndexInfo{
+				{Name: "users_pkey", Columns: []string{"id"}, IsUnique: true, IsPrimary: true},
+				{Name: "users_email_key", Columns: []string{"email"}, IsUnique: true},
+			},
+		}
+		assert.Equal(t, `"id"`, h.getConflictTarget(table))
+	})
+
+	t.Run("several unique indexes are ambiguous", func(t *testing.T) {
+		table := database.TableInfo{
+			Indexes: []database.IndexInfo{
+				{Name: "users_email_key", Columns: []string{"email"}, IsUnique: true},
+				{Name: "users_username_key", Columns: []string{"username"}, IsUnique: true},
+			},
+		}
+		assert.Empty(t, h.getConflictTarget(table))
+	})
+}
+
+func TestBuildOnConflictClause(t *testing.T) {
+	h := &RESTHandler{}
+	table := database.TableInfo{
+		Schema:     "public",
+		Name:       "products",
+		PrimaryKey: []string{"id"},
+		Columns: []database.ColumnInfo{
+			{Name: "id"}, {Name: "sku"}, {Name: "name"}, {Name: "price"},
+		},
+	}
+	table.BuildColumnMap()
+
+	tests := []struct {
+		name             string
+		onConflict       string
+		columns          []string
+		ignoreDuplicates bool
+		defaultToNull    bool
+		expected         string
+		doNothing        bool
+	}{
+		{
+			name:     "merge on primary key",
+			columns:  []string{"id", "name", "price"},
+			expected: ` ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name", "price" = EXCLUDED."price"`,
+		},
+		{
+			name:       "merge on custom target",
+			onConflict: "sku",
+			columns:    []string{"sku", "price"},
+			expected:   ` ON CONFLICT ("sku") DO UPDATE SET "price" = EXCLUDED."price"`,
+		},
+		{
+			name:             "ignore duplicates",
+			columns:          []string{"id", "name"},
+			ignoreDuplicates: true,
+			expected:         ` ON CONFLICT ("id") DO NOTHING`,
+			doNothing:        true,
+		},
+		{
+			name:      "only conflict columns inserted",
+			columns:   []string{"id"},
+			expected:  ` ON CONFLICT ("id") DO NOTHING`,
+			doNothing: true,
+		},
+		{
+			name:          "missing columns set to null",
+			columns:       []string{"id", "name"},
+			defaultToNull: true,
+			expected:      ` ON CONFLICT ("id") DO UPDATE SET "sku" = NULL, "name" = EXCLUDED."name", "price" = NULL`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clause, doNothing, err := h.buildOnConflictClause(table, tt.onConflict, tt.columns, tt.ignoreDuplicates, tt.defaultToNull)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, clause)
+			assert.Equal(t, tt.doNothing, doNothing)
+		})
+	}
+
+	t.Run("unknown on_conflict column", func(t *testing.T) {
+		_, _, err := h.buildOnConflictClause(table, "name, nope", []string{"name"}, false, false)
+		assert.EqualError(t, err, "Unknown column in on_conflict: nope")
+	})
+
+	t.Run("no conflict target", func(t *testing.T) {
+		_, _, err := h.buildOnConflictClause(database.TableInfo{Name: "logs"}, "", []string{"message"}, false, false)
+		assert.ErrorContains(t, err, "no primary key or unique constraint")
+	})
+}
@@ -129,9 +129,7 @@ func (h *SAMLProviderHandler) ListSAMLProviders(c fiber.Ctx) error {
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	// Get database-managed providers
@@ -150,9 +148,7 @@ func (h *SAMLProviderHandler) ListSAMLProviders(c fiber.Ctx) error {
 	rows, err := h.db.Query(ctx, query)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list SAML providers")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to retrieve SAML providers",
-		})
+		return SendErrorWithCode(c, 500, "Failed to retrieve SAML providers", ErrCodeOperationFailed)
 	}
 	defer rows.Close()
 
@@ -224,16 +220,12 @@ func (h *SAMLProviderHandler) GetSAMLProvider(c fiber.Ctx) error {
 
 	providerID, err := uuid.Parse(id)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid provider ID",
-		})
+		return SendBadRequest(c, "Invalid provider ID", ErrCodeInvalidID)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	query := `
@@ -258,15 +250,11 @@ func (h *SAMLProviderHandler) GetSAMLProvider(c fiber.Ctx) error {
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
-		return c.Status(404).JSON(fiber.Map{
-			"error": "SAML provider not found",
-		})
+		return SendNotFound(c, "SAML provider not found")
 	}
 	if err != nil {
 		log.Error().Err(err).Str("id", id).Msg("Failed to get SAML provider")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to retrieve SAML provider",
-		})
+		return SendErrorWithCode(c, 500, "Failed to retrieve SAML provider", ErrCodeOperationFailed)
 	}
 
 	p.AttributeMapping = attrMapping
@@ -283,39 +271,29 @@ func (h *SAMLProviderHandler) CreateSAMLProvider(c fiber.Ctx) error {
 	var req CreateSAMLProviderRequest
 
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate provider name
 	if !samlProviderNamePattern.MatchString(req.Name) {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Provider name must start with a letter and contain only lowercase letters, numbers, underscores, and hyphens (2-50 chars)",
-		})
+		return SendBadRequest(c, "Provider name must start with a letter and contain only lowercase letters, numbers, underscores, and hyphens (2-50 chars)", ErrCodeInvalidInput)
 	}
 
 	// Require metadata URL or XML
 	if (req.IdPMetadataURL == nil || *req.IdPMetadataURL == "") &&
 		(req.IdPMetadataXML == nil || *req.IdPMetadataXML == "") {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Either idp_metadata_url or idp_metadata_xml must be provided",
-		})
+		return SendBadRequest(c, "Either idp_metadata_url or idp_metadata_xml must be provided", ErrCodeInvalidInput)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	// Validate and parse metadata
 	metadataInfo, err := h.validateMetadata(ctx, req.IdPMetadataURL, req.IdPMetadataXML)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": fmt.Sprintf("Invalid IdP metadata: %v", err),
-		})
+		return SendBadRequest(c, fmt.Sprintf("Invalid IdP metadata: %v", err), ErrCodeInvalidInput)
 	}
 
 	// Set defaults
@@ -391,14 +369,10 @@ func (h *SAMLProviderHandler) CreateSAMLProvider(c fiber.Ctx) error {
 	).Scan(&id, &createdAt, &updatedAt)
 	if err != nil {
 		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint") {
-			return c.Status(409).JSON(fiber.Map{
-				"error": fmt.Sprintf("SAML provider '%s' already exists", req.Name),
-			})
+			return SendConflict(c, fmt.Sprintf("SAML provider '%s' already exists", req.Name), ErrCodeAlreadyExists)
 		}
 		log.Error().Err(err).Str("provider", req.Name).Msg("Failed to create SAML provider")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to create SAML provider",
-		})
+		return SendErrorWithCode(c, 500, "Failed to create SAML provider", ErrCodeOperationFailed)
 	}
 
 	// Reload SAML service to pick up new provider
@@ -429,16 +403,12 @@ func (h *SAMLProviderHandler) UpdateSAMLProvider(c fiber.Ctx) error {
 
 	providerID, err := uuid.Parse(id)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid provider ID",
-		})
+		return SendBadRequest(c, "Invalid provider ID", ErrCodeInvalidID)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	// Check if provider exists and is database-managed
@@ -446,27 +416,19 @@ func (h *SAMLProviderHandler) UpdateSAMLProvider(c fiber.Ctx) error {
 	var providerName string
 	err = h.db.QueryRow(ctx, "SELECT name, COALESCE(source, 'database') FROM auth.saml_providers WHERE id = $1", providerID).Scan(&providerName, &source)
 	if errors.Is(err, sql.ErrNoRows) {
-		return c.Status(404).JSON(fiber.Map{
-			"error": "SAML provider not found",
-		})
+		return SendNotFound(c, "SAML provider not found")
 	}
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to check provider",
-		})
+		return SendErrorWithCode(c, 500, "Failed to check provider", ErrCodeOperationFailed)
 	}
 
 	if source == "config" {
-		return c.Status(403).JSON(fiber.Map{
-			"error": "Cannot modify config-file managed providers. Edit your fluxbase.yaml instead.",
-		})
+		return SendForbidden(c, "Cannot modify config-file managed providers. Edit your fluxbase.yaml instead.", ErrCodeAccessDenied)
 	}
 
 	var req UpdateSAMLProviderRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// If metadata is being updated, validate it
@@ -475,9 +437,7 @@ func (h *SAMLProviderHandler) UpdateSAMLProvider(c fiber.Ctx) error {
 		(req.IdPMetadataXML != nil && *req.IdPMetadataXML != "") {
 		metadataInfo, err = h.validateMetadata(ctx, req.IdPMetadataURL, req.IdPMetadataXML)
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{
-				"error": fmt.Sprintf("Invalid IdP metadata: %v", err),
-			})
+			return SendBadRequest(c, fmt.Sprintf("Invalid IdP metadata: %v", err), ErrCodeInvalidInput)
 		}
 	}
 
@@ -568,9 +528,7 @@ func (h *SAMLProviderHandler) UpdateSAMLProvider(c fiber.Ctx) error {
 	}
 
 	if len(updates) == 0 {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "No fields to update",
-		})
+		return SendBadRequest(c, "No fields to update", ErrCodeInvalidInput)
 	}
 
 	query := fmt.Sprintf(
@@ -582,15 +540,11 @@ func (h *SAMLProviderHandler) UpdateSAMLProvider(c fiber.Ctx) error {
 	err = h.db.QueryRow(ctx, query, args...).Scan(&displayName)
 
 	if errors.Is(err, sql.ErrNoRows) {
-		return c.Status(404).JSON(fiber.Map{
-			"error": "SAML provider not found",
-		})
+		return SendNotFound(c, "SAML provider not found")
 	}
 	if err != nil {
 		log.Error().Err(err).Str("id", id).Msg("Failed to update SAML provider")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to update SAML provider",
-		})
+		return SendErrorWithCode(c, 500, "Failed to update SAML provider", ErrCodeOperationFailed)
 	}
 
 	// Reload SAML service
@@ -615,16 +569,12 @@ func (h *SAMLProviderHandler) DeleteSAMLProvider(c fiber.Ctx) error {
 
 	providerID, err := uuid.Parse(id)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid provider ID",
-		})
+		return SendBadRequest(c, "Invalid provider ID", ErrCodeInvalidID)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	// Check source before deleting
@@ -632,14 +582,10 @@ func (h *SAMLProviderHandler) DeleteSAMLProvider(c fiber.Ctx) error {
 	var providerName string
 	err = h.db.QueryRow(ctx, "SELECT name, COALESCE(source, 'database') FROM auth.saml_providers WHERE id = $1", providerID).Scan(&providerName, &source)
 	if errors.Is(err, sql.ErrNoRows) {
-		return c.Status(404).JSON(fiber.Map{
-			"error": "SAML provider not found",
-		})
+		return SendNotFound(c, "SAML provider not found")
 	}
 	if source == "config" {
-		return c.Status(403).JSON(fiber.Map{
-			"error": "Cannot delete config-file managed providers. Remove from your fluxbase.yaml instead.",
-		})
+		return SendForbidden(c, "Cannot delete config-file managed providers. Remove from your fluxbase.yaml instead.", ErrCodeAccessDenied)
 	}
 
 	query := "DELETE FROM auth.saml_providers WHERE id = $1 RETURNING display_name"
@@ -648,15 +594,11 @@ func (h *SAMLProviderHandler) DeleteSAMLProvider(c fiber.Ctx) error {
 	err = h.db.QueryRow(ctx, query, providerID).Scan(&displayName)
 
 	if errors.Is(err, sql.ErrNoRows) {
-		return c.Status(404).JSON(fiber.Map{
-			"error": "SAML provider not found",
-		})
+		return SendNotFound(c, "SAML provider not found")
 	}
 	if err != nil {
 		log.Error().Err(err).Str("id", id).Msg("Failed to delete SAML provider")
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to delete SAML provider",
-		})
+		return SendErrorWithCode(c, 500, "Failed to delete SAML provider", ErrCodeOperationFailed)
 	}
 
 	// Remove from SAML service
@@ -677,16 +619,12 @@ func (h *SAMLProviderHandler) ValidateMetadata(c fiber.Ctx) error {
 	var req ValidateMetadataRequest
 
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	if (req.MetadataURL == nil || *req.MetadataURL == "") &&
 		(req.MetadataXML == nil || *req.MetadataXML == "") {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Either metadata_url or metadata_xml must be provided",
-		})
+		return SendBadRequest(c, "Either metadata_url or metadata_xml must be provided", ErrCodeInvalidInput)
 	}
 
 	result, err := h.validateMetadata(c.RequestCtx(), req.MetadataURL, req.MetadataXML)
@@ -711,32 +649,24 @@ func (h *SAMLProviderHandler) ValidateMetadata(c fiber.Ctx) error {
 func (h *SAMLProviderHandler) UploadMetadata(c fiber.Ctx) error {
 	file, err := c.FormFile("metadata")
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "No metadata file provided",
-		})
+		return SendBadRequest(c, "No metadata file provided", ErrCodeInvalidInput)
 	}
 
 	// Check file size (max 1MB)
 	if file.Size > 1024*1024 {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Metadata file too large (max 1MB)",
-		})
+		return SendBadRequest(c, "Metadata file too large (max 1MB)", ErrCodeInvalidInput)
 	}
 
 	// Read file content
 	f, err := file.Open()
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to read file",
-		})
+		return SendErrorWithCode(c, 500, "Failed to read file", ErrCodeOperationFailed)
 	}
 	defer func() { _ = f.Close() }()
 
 	content, err := io.ReadAll(f)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to read file content",
-		})
+		return SendErrorWithCode(c, 500, "Failed to read file content", ErrCodeOperationFailed)
 	}
 
 	xmlStr := string(content)
@@ -764,16 +694,12 @@ func (h *SAMLProviderHandler) GetSPMetadata(c fiber.Ctx) error {
 	providerName := c.Params("provider")
 
 	if h.samlService == nil {
-		return c.Status(503).JSON(fiber.Map{
-			"error": "SAML service not available",
-		})
+		return SendErrorWithCode(c, 503, "SAML service not available", ErrCodeServiceUnavailable)
 	}
 
 	metadata, err := h.samlService.GetSPMetadata(providerName)
 	if err != nil {
-		return c.Status(404).JSON(fiber.Map{
-			"error": "Provider not found or not initialized",
-		})
+		return SendNotFound(c, "Provider not found or not initialized")
 	}
 
 	c.Set("Content-Type", "application/xml")
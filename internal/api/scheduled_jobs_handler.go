@@ -0,0 +1,289 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/scheduledjobs"
+	"github.com/rs/zerolog/log"
+)
+
+// ScheduledJobsHandler manages jobs that run edge functions and SQL snippets on cron
+// schedules, runs them on demand and reports their run history
+type ScheduledJobsHandler struct {
+	store  *scheduledjobs.Store
+	runner *scheduledjobs.Runner
+	now    func() time.Time
+}
+
+// NewScheduledJobsHandler creates a new scheduled jobs handler
+func NewScheduledJobsHandler(store *scheduledjobs.Store, runner *scheduledjobs.Runner) *ScheduledJobsHandler {
+	return &ScheduledJobsHandler{store: store, runner: runner, now: time.Now}
+}
+
+// scheduledJobRequest is the body of a create or update request. Omitted fields keep their
+// current value on update.
+type scheduledJobRequest struct {
+	Name              *string                   `json:"name"`
+	Description       *string                   `json:"description"`
+	CronSchedule      *string                   `json:"cron_schedule"`
+	Timezone          *string                   `json:"timezone"`
+	TargetType        *scheduledjobs.TargetType `json:"target_type"`
+	FunctionName      *string                   `json:"function_name"`
+	FunctionNamespace *string                   `json:"function_namespace"`
+	Payload           json.RawMessage           `json:"payload"`
+	SQL               *string                   `json:"sql"`
+	Enabled           *bool                     `json:"enabled"`
+}
+
+// apply copies the fields set in the request onto job
+func (r *scheduledJobRequest) apply(job *scheduledjobs.Job) {
+	if r.Name != nil {
+		job.Name = *r.Name
+	}
+	if r.Description != nil {
+		job.Description = r.Description
+	}
+	if r.CronSchedule != nil {
+		job.CronSchedule = *r.CronSchedule
+	}
+	if r.Timezone != nil {
+		job.Timezone = *r.Timezone
+	}
+	if r.TargetType != nil {
+		job.TargetType = *r.TargetType
+	}
+	if r.FunctionName != nil {
+		job.FunctionName = r.FunctionName
+	}
+	if r.FunctionNamespace != nil {
+		job.FunctionNamespace = r.FunctionNamespace
+	}
+	if r.Payload != nil {
+		job.Payload = r.Payload
+	}
+	if r.SQL != nil {
+		job.SQL = r.SQL
+	}
+	if r.Enabled != nil {
+		job.Enabled = *r.Enabled
+	}
+}
+
+// withNextRun sets when an enabled job fires next
+func (h *ScheduledJobsHandler) withNextRun(job *scheduledjobs.Job) {
+	if job.Enabled {
+		job.NextRunAt = scheduledjobs.NextRun(*job, h.now())
+	}
+}
+
+// ListJobs returns the scheduled jobs with their next run
+// GET /api/v1/admin/scheduled-jobs
+func (h *ScheduledJobsHandler) ListJobs(c fiber.Ctx) error {
+	jobs, err := h.store.ListJobs(c.RequestCtx())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list scheduled jobs")
+		return SendOperationFailed(c, "list scheduled jobs")
+	}
+
+	for i := range jobs {
+		h.withNextRun(&jobs[i])
+	}
+
+	return c.JSON(fiber.Map{
+		"jobs": jobs,
+	})
+}
+
+// GetJob returns a scheduled job
+// GET /api/v1/admin/scheduled-jobs/:id
+func (h *ScheduledJobsHandler) GetJob(c fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return SendInvalidID(c, "job ID")
+	}
+
+	job, err := h.store.GetJob(c.RequestCtx(), id)
+	if errors.Is(err, scheduledjobs.ErrJobNotFound) {
+		return SendResourceNotFound(c, "Scheduled job")
+	}
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("Failed to get scheduled job")
+		return SendOperationFailed(c, "get scheduled job")
+	}
+
+	h.withNextRun(job)
+	return c.JSON(job)
+}
+
+// CreateJob schedules an edge function or a SQL snippet
+// POST /api/v1/admin/scheduled-jobs {"name": "nightly-report", "cron_schedule": "0 6 * * *", "timezone": "Europe/Berlin", "target_type": "function", "function_name": "report", "payload": {"period": "day"}}
+// POST /api/v1/admin/scheduled-jobs {"name": "expire-sessions", "cron_schedule": "*/15 * * * *", "target_type": "sql", "sql": "DELETE FROM app.sessions WHERE expires_at < now()"}
+func (h *ScheduledJobsHandler) CreateJob(c fiber.Ctx) error {
+	var req scheduledJobRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+
+	job := scheduledjobs.Job{Enabled: true}
+	req.apply(&job)
+	if err := job.Validate(); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	created, err := h.store.CreateJob(c.RequestCtx(), job, getUserIDFromContext(c))
+	if errors.Is(err, scheduledjobs.ErrJobExists) {
+		return SendConflict(c, "A scheduled job with this name already exists", ErrCodeAlreadyExists)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("name", job.Name).Msg("Failed to create scheduled job")
+		return SendOperationFailed(c, "create scheduled job")
+	}
+
+	log.Info().
+		Str("job", created.Name).
+		Str("target_type", string(created.TargetType)).
+		Str("cron_schedule", created.CronSchedule).
+		Msg("Scheduled job created")
+
+	h.withNextRun(created)
+	return c.Status(fiber.StatusCreated).JSON(created)
+}
+
+// UpdateJob changes the fields of a job given in the body
+// PATCH /api/v1/admin/scheduled-jobs/:id {"cron_schedule": "0 7 * * *", "enabled": false}
+func (h *ScheduledJobsHandler) UpdateJob(c fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return SendInvalidID(c, "job ID")
+	}
+
+	var req scheduledJobRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+
+	ctx := c.RequestCtx()
+	job, err := h.store.GetJob(ctx, id)
+	if errors.Is(err, scheduledjobs.ErrJobNotFound) {
+		return SendResourceNotFound(c, "Scheduled job")
+	}
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("Failed to get scheduled job")
+		return SendOperationFailed(c, "update scheduled job")
+	}
+
+	req.apply(job)
+	if err := job.Validate(); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	updated, err := h.store.UpdateJob(ctx, id, *job)
+	switch {
+	case errors.Is(err, scheduledjobs.ErrJobNotFound):
+		return SendResourceNotFound(c, "Scheduled job")
+	case errors.Is(err, scheduledjobs.ErrJobExists):
+		return SendConflict(c, "A scheduled job with this name already exists", ErrCodeAlreadyExists)
+	case err != nil:
+		log.Error().Err(err).Str("id", id).Msg("Failed to update scheduled job")
+		return SendOperationFailed(c, "update scheduled job")
+	}
+
+	h.withNextRun(updated)
+	return c.JSON(updated)
+}
+
+// DeleteJob deletes a job and its run history
+// DELETE /api/v1/admin/scheduled-jobs/:id
+func (h *ScheduledJobsHandler) DeleteJob(c fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return SendInvalidID(c, "job ID")
+	}
+
+	err := h.store.DeleteJob(c.RequestCtx(), id)
+	if errors.Is(err, scheduledjobs.ErrJobNotFound) {
+		return SendResourceNotFound(c, "Scheduled job")
+	}
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("Failed to delete scheduled job")
+		return SendOperationFailed(c, "delete scheduled job")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RunJob runs a job now in the background, whether or not it is enabled
+// POST /api/v1/admin/scheduled-jobs/:id/run
+func (h *ScheduledJobsHandler) RunJob(c fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return SendInvalidID(c, "job ID")
+	}
+
+	ctx := c.RequestCtx()
+	job, err := h.store.GetJob(ctx, id)
+	if errors.Is(err, scheduledjobs.ErrJobNotFound) {
+		return SendResourceNotFound(c, "Scheduled job")
+	}
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("Failed to get scheduled job")
+		return SendOperationFailed(c, "run scheduled job")
+	}
+
+	run, err := h.runner.Start(ctx, scheduledjobs.RunRequest{
+		Job:         *job,
+		Trigger:     scheduledjobs.TriggerManual,
+		TriggeredBy: getUserIDFromContext(c),
+	})
+	if errors.Is(err, scheduledjobs.ErrRunInProgress) {
+		return SendConflict(c, "This job is already running", ErrCodeConflict)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("job", job.Name).Msg("Failed to start scheduled job")
+		return SendOperationFailed(c, "run scheduled job")
+	}
+
+	log.Info().Str("job", job.Name).Msg("Manual scheduled job run started")
+
+	return c.Status(fiber.StatusAccepted).JSON(run)
+}
+
+// ListRuns returns the most recent runs with their output, of every job or of the job in
+// the path
+// GET /api/v1/admin/scheduled-jobs/runs?status=failed&limit=50
+// GET /api/v1/admin/scheduled-jobs/:id/runs?limit=50
+func (h *ScheduledJobsHandler) ListRuns(c fiber.Ctx) error {
+	filter := scheduledjobs.RunFilter{JobID: c.Params("id"), Status: c.Query("status"), Limit: 50}
+	if filter.JobID != "" {
+		if _, err := uuid.Parse(filter.JobID); err != nil {
+			return SendInvalidID(c, "job ID")
+		}
+	}
+	switch filter.Status {
+	case "", scheduledjobs.StatusRunning, scheduledjobs.StatusCompleted, scheduledjobs.StatusFailed:
+	default:
+		return SendBadRequest(c, "status must be one of: running, completed, failed", ErrCodeInvalidInput)
+	}
+	if v := c.Query("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l <= 0 {
+			return SendBadRequest(c, "limit must be a positive integer", ErrCodeInvalidInput)
+		}
+		filter.Limit = min(l, 1000)
+	}
+
+	runs, err := h.store.ListRuns(c.RequestCtx(), filter)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list scheduled job runs")
+		return SendOperationFailed(c, "list scheduled job runs")
+	}
+
+	return c.JSON(fiber.Map{
+		"runs": runs,
+	})
+}
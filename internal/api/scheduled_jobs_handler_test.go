@@ -0,0 +1,90 @@
+package api
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/scheduledjobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestScheduledJobsApp() *fiber.App {
+	store := scheduledjobs.NewStore(nil)
+	h := NewScheduledJobsHandler(store, scheduledjobs.NewRunner(store, nil, time.Minute, 0))
+	app := fiber.New()
+	app.Post("/scheduled-jobs", h.CreateJob)
+	app.Get("/scheduled-jobs/runs", h.ListRuns)
+	app.Get("/scheduled-jobs/:id", h.GetJob)
+	app.Patch("/scheduled-jobs/:id", h.UpdateJob)
+	app.Delete("/scheduled-jobs/:id", h.DeleteJob)
+	app.Post("/scheduled-jobs/:id/run", h.RunJob)
+	app.Get("/scheduled-jobs/:id/runs", h.ListRuns)
+	return app
+}
+
+func TestScheduledJobsHandler_ValidatesJobs(t *testing.T) {
+	app := newTestScheduledJobsApp()
+
+	tests := []struct {
+		name string
+		body string
+		msg  string
+	}{
+		{"missing name", `{"cron_schedule":"@daily","target_type":"sql","sql":"SELECT 1"}`, "name is required"},
+		{"invalid cron expression", `{"name":"x","cron_schedule":"every night","target_type":"sql","sql":"SELECT 1"}`, "invalid cron_schedule"},
+		{"unknown timezone", `{"name":"x","cron_schedule":"@daily","timezone":"Mars/Olympus","target_type":"sql","sql":"SELECT 1"}`, "unknown timezone"},
+		{"unknown target", `{"name":"x","cron_schedule":"@daily","target_type":"webhook"}`, "target_type must be"},
+		{"function without name", `{"name":"x","cron_schedule":"@daily","target_type":"function"}`, "function_name is required"},
+		{"sql without statements", `{"name":"x","cron_schedule":"@daily","target_type":"sql"}`, "sql is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/scheduled-jobs", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			body := new(strings.Builder)
+			_, _ = io.Copy(body, resp.Body)
+			assert.Contains(t, body.String(), tt.msg)
+		})
+	}
+}
+
+func TestScheduledJobsHandler_RejectsInvalidIDs(t *testing.T) {
+	app := newTestScheduledJobsApp()
+
+	for _, tc := range []struct{ method, path string }{
+		{"GET", "/scheduled-jobs/42"},
+		{"PATCH", "/scheduled-jobs/not-a-uuid"},
+		{"DELETE", "/scheduled-jobs/42"},
+		{"POST", "/scheduled-jobs/42/run"},
+		{"GET", "/scheduled-jobs/42/runs"},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, tc.method+" "+tc.path)
+	}
+}
+
+func TestScheduledJobsHandler_ValidatesRunFilter(t *testing.T) {
+	app := newTestScheduledJobsApp()
+
+	for _, query := range []string{"?status=exploded", "?limit=0", "?limit=abc"} {
+		resp, err := app.Test(httptest.NewRequest("GET", "/scheduled-jobs/runs"+query, nil))
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, query)
+	}
+}
@@ -62,9 +62,7 @@ type SchemaGraphResponse struct {
 func (s *Server) GetSchemaGraph(c fiber.Ctx) error {
 	// Check if database connection is available
 	if s.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	ctx := c.RequestCtx()
@@ -433,9 +431,7 @@ func (s *Server) GetTableRelationships(c fiber.Ctx) error {
 
 	// Check if database connection is available
 	if s.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	query := `
@@ -0,0 +1,437 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/scim"
+	"github.com/rs/zerolog/log"
+)
+
+// SCIMHandler serves the SCIM 2.0 provisioning API at /api/v1/scim/v2, through which identity
+// providers such as Okta and Entra ID manage users and groups
+type SCIMHandler struct {
+	store *scim.Store
+	// validateToken checks provisioning tokens; a field so tests can stub it
+	validateToken func(ctx context.Context, token string) (*scim.Token, error)
+	baseURL       string
+}
+
+// NewSCIMHandler creates a SCIM handler. baseURL is the public base URL of the instance,
+// used for resource locations.
+func NewSCIMHandler(store *scim.Store, baseURL string) *SCIMHandler {
+	h := &SCIMHandler{store: store, baseURL: strings.TrimSuffix(baseURL, "/") + "/api/v1/scim/v2"}
+	if store != nil {
+		h.validateToken = store.ValidateToken
+	}
+	return h
+}
+
+// CreateSCIMTokenRequest represents a request to create a provisioning token
+type CreateSCIMTokenRequest struct {
+	Name      string     `json:"name"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// RegisterRoutes registers the SCIM endpoints, all of which require a provisioning token
+func (h *SCIMHandler) RegisterRoutes(router fiber.Router) {
+	router.Use(h.requireToken)
+
+	router.Get("/ServiceProviderConfig", h.GetServiceProviderConfig)
+	router.Get("/ResourceTypes", h.ListResourceTypes)
+	router.Get("/Schemas", h.ListSchemas)
+
+	router.Get("/Users", h.ListUsers)
+	router.Post("/Users", h.CreateUser)
+	router.Get("/Users/:id", h.GetUser)
+	router.Put("/Users/:id", h.ReplaceUser)
+	router.Patch("/Users/:id", h.PatchUser)
+	router.Delete("/Users/:id", h.DeleteUser)
+
+	router.Get("/Groups", h.ListGroups)
+	router.Post("/Groups", h.CreateGroup)
+	router.Get("/Groups/:id", h.GetGroup)
+	router.Put("/Groups/:id", h.ReplaceGroup)
+	router.Patch("/Groups/:id", h.PatchGroup)
+	router.Delete("/Groups/:id", h.DeleteGroup)
+}
+
+// requireToken authenticates the identity provider with a bearer provisioning token
+func (h *SCIMHandler) requireToken(c fiber.Ctx) error {
+	token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if !ok || strings.TrimSpace(token) == "" {
+		c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+		return h.sendError(c, scim.NewError(http.StatusUnauthorized, "", "a SCIM provisioning token is required"))
+	}
+
+	scimToken, err := h.validateToken(c.RequestCtx(), strings.TrimSpace(token))
+	if err != nil {
+		if errors.Is(err, scim.ErrInvalidToken) {
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+			return h.sendError(c, scim.NewError(http.StatusUnauthorized, "", "invalid SCIM provisioning token"))
+		}
+		return h.sendError(c, err)
+	}
+
+	c.Locals("scim_token_id", scimToken.ID.String())
+	return c.Next()
+}
+
+// GetServiceProviderConfig returns the supported SCIM features
+// GET /api/v1/scim/v2/ServiceProviderConfig
+func (h *SCIMHandler) GetServiceProviderConfig(c fiber.Ctx) error {
+	return h.send(c, fiber.StatusOK, scim.ServiceProviderConfig(h.baseURL+"/ServiceProviderConfig"))
+}
+
+// ListResourceTypes returns the User and Group resource types
+// GET /api/v1/scim/v2/ResourceTypes
+func (h *SCIMHandler) ListResourceTypes(c fiber.Ctx) error {
+	types := scim.ResourceTypes(h.baseURL)
+	return h.send(c, fiber.StatusOK, scim.NewListResponse(types, len(types), 1))
+}
+
+// ListSchemas returns the User and Group schemas
+// GET /api/v1/scim/v2/Schemas
+func (h *SCIMHandler) ListSchemas(c fiber.Ctx) error {
+	schemas := scim.Schemas(h.baseURL)
+	return h.send(c, fiber.StatusOK, scim.NewListResponse(schemas, len(schemas), 1))
+}
+
+// ListUsers lists users, optionally filtered
+// GET /api/v1/scim/v2/Users?filter=userName eq "jane@example.com"
+func (h *SCIMHandler) ListUsers(c fiber.Ctx) error {
+	query, err := h.listQuery(c)
+	if err != nil {
+		return h.sendError(c, err)
+	}
+
+	users, total, err := h.store.ListUsers(c.RequestCtx(), query)
+	if err != nil {
+		return h.sendError(c, err)
+	}
+	for _, user := range users {
+		h.setLocation(user.Meta, "Users", user.ID)
+	}
+	return h.send(c, fiber.StatusOK, scim.NewListResponse(users, total, query.StartIndex))
+}
+
+// CreateUser provisions a user
+// POST /api/v1/scim/v2/Users
+func (h *SCIMHandler) CreateUser(c fiber.Ctx) error {
+	var user scim.User
+	if err := h.parseBody(c, &user); err != nil {
+		return h.sendError(c, err)
+	}
+
+	created, err := h.store.CreateUser(c.RequestCtx(), &user)
+	if err != nil {
+		return h.sendError(c, err)
+	}
+
+	log.Info().
+		Str("user_id", created.ID).
+		Str("scim_token_id", h.tokenID(c)).
+		Msg("SCIM user provisioned")
+
+	return h.sendResource(c, fiber.StatusCreated, created.Meta, "Users", created.ID, created)
+}
+
+// GetUser returns a user
+// GET /api/v1/scim/v2/Users/:id
+func (h *SCIMHandler) GetUser(c fiber.Ctx) error {
+	user, err := h.store.GetUser(c.RequestCtx(), c.Params("id"))
+	if err != nil {
+		return h.sendError(c, err)
+	}
+	return h.sendResource(c, fiber.StatusOK, user.Meta, "Users", user.ID, user)
+}
+
+// ReplaceUser replaces a user
+// PUT /api/v1/scim/v2/Users/:id
+func (h *SCIMHandler) ReplaceUser(c fiber.Ctx) error {
+	var user scim.User
+	if err := h.parseBody(c, &user); err != nil {
+		return h.sendError(c, err)
+	}
+	return h.saveUser(c, &user)
+}
+
+// PatchUser applies PATCH operations to a user, such as setting active to false
+// PATCH /api/v1/scim/v2/Users/:id
+func (h *SCIMHandler) PatchUser(c fiber.Ctx) error {
+	var patch scim.PatchRequest
+	if err := h.parseBody(c, &patch); err != nil {
+		return h.sendError(c, err)
+	}
+
+	user, err := h.store.GetUser(c.RequestCtx(), c.Params("id"))
+	if err != nil {
+		return h.sendError(c, err)
+	}
+	if err := scim.ApplyUserPatch(user, patch.Operations); err != nil {
+		return h.sendError(c, err)
+	}
+	return h.saveUser(c, user)
+}
+
+func (h *SCIMHandler) saveUser(c fiber.Ctx, user *scim.User) error {
+	saved, err := h.store.ReplaceUser(c.RequestCtx(), c.Params("id"), user)
+	if err != nil {
+		return h.sendError(c, err)
+	}
+
+	log.Info().
+		Str("user_id", saved.ID).
+		Bool("active", saved.IsActive()).
+		Str("scim_token_id", h.tokenID(c)).
+		Msg("SCIM user updated")
+
+	return h.sendResource(c, fiber.StatusOK, saved.Meta, "Users", saved.ID, saved)
+}
+
+// DeleteUser deletes a user
+// DELETE /api/v1/scim/v2/Users/:id
+func (h *SCIMHandler) DeleteUser(c fiber.Ctx) error {
+	if err := h.store.DeleteUser(c.RequestCtx(), c.Params("id")); err != nil {
+		return h.sendError(c, err)
+	}
+
+	log.Info().
+		Str("user_id", c.Params("id")).
+		Str("scim_token_id", h.tokenID(c)).
+		Msg("SCIM user deleted")
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListGroups lists groups, optionally filtered
+// GET /api/v1/scim/v2/Groups?filter=displayName eq "Engineering"&excludedAttributes=members
+func (h *SCIMHandler) ListGroups(c fiber.Ctx) error {
+	query, err := h.listQuery(c)
+	if err != nil {
+		return h.sendError(c, err)
+	}
+
+	groups, total, err := h.store.ListGroups(c.RequestCtx(), query)
+	if err != nil {
+		return h.sendError(c, err)
+	}
+	for _, group := range groups {
+		h.setLocation(group.Meta, "Groups", group.ID)
+	}
+	return h.send(c, fiber.StatusOK, scim.NewListResponse(groups, total, query.StartIndex))
+}
+
+// CreateGroup creates a group with its members
+// POST /api/v1/scim/v2/Groups
+func (h *SCIMHandler) CreateGroup(c fiber.Ctx) error {
+	var group scim.Group
+	if err := h.parseBody(c, &group); err != nil {
+		return h.sendError(c, err)
+	}
+
+	created, err := h.store.CreateGroup(c.RequestCtx(), &group)
+	if err != nil {
+		return h.sendError(c, err)
+	}
+	return h.sendResource(c, fiber.StatusCreated, created.Meta, "Groups", created.ID, created)
+}
+
+// GetGroup returns a group
+// GET /api/v1/scim/v2/Groups/:id
+func (h *SCIMHandler) GetGroup(c fiber.Ctx) error {
+	group, err := h.store.GetGroup(c.RequestCtx(), c.Params("id"), excludesMembers(c))
+	if err != nil {
+		return h.sendError(c, err)
+	}
+	return h.sendResource(c, fiber.StatusOK, group.Meta, "Groups", group.ID, group)
+}
+
+// ReplaceGroup replaces a group and its members
+// PUT /api/v1/scim/v2/Groups/:id
+func (h *SCIMHandler) ReplaceGroup(c fiber.Ctx) error {
+	var group scim.Group
+	if err := h.parseBody(c, &group); err != nil {
+		return h.sendError(c, err)
+	}
+	return h.saveGroup(c, &group)
+}
+
+// PatchGroup applies PATCH operations to a group, such as adding or removing members
+// PATCH /api/v1/scim/v2/Groups/:id
+func (h *SCIMHandler) PatchGroup(c fiber.Ctx) error {
+	var patch scim.PatchRequest
+	if err := h.parseBody(c, &patch); err != nil {
+		return h.sendError(c, err)
+	}
+
+	group, err := h.store.GetGroup(c.RequestCtx(), c.Params("id"), false)
+	if err != nil {
+		return h.sendError(c, err)
+	}
+	if err := scim.ApplyGroupPatch(group, patch.Operations); err != nil {
+		return h.sendError(c, err)
+	}
+	return h.saveGroup(c, group)
+}
+
+func (h *SCIMHandler) saveGroup(c fiber.Ctx, group *scim.Group) error {
+	saved, err := h.store.ReplaceGroup(c.RequestCtx(), c.Params("id"), group)
+	if err != nil {
+		return h.sendError(c, err)
+	}
+	if excludesMembers(c) {
+		saved.Members = nil
+	}
+	return h.sendResource(c, fiber.StatusOK, saved.Meta, "Groups", saved.ID, saved)
+}
+
+// DeleteGroup deletes a group
+// DELETE /api/v1/scim/v2/Groups/:id
+func (h *SCIMHandler) DeleteGroup(c fiber.Ctx) error {
+	if err := h.store.DeleteGroup(c.RequestCtx(), c.Params("id")); err != nil {
+		return h.sendError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListSCIMTokens lists provisioning tokens
+// GET /api/v1/admin/scim/tokens
+func (h *SCIMHandler) ListSCIMTokens(c fiber.Ctx) error {
+	tokens, err := h.store.ListTokens(c.RequestCtx())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list SCIM tokens")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to list SCIM tokens", ErrCodeOperationFailed)
+	}
+	return c.JSON(fiber.Map{
+		"tokens": tokens,
+		"count":  len(tokens),
+	})
+}
+
+// CreateSCIMToken creates a provisioning token. The token is only returned in this response.
+// POST /api/v1/admin/scim/tokens
+func (h *SCIMHandler) CreateSCIMToken(c fiber.Ctx) error {
+	var req CreateSCIMTokenRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return SendBadRequest(c, "name is required", ErrCodeMissingField)
+	}
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		return SendBadRequest(c, "expires_at must be in the future", ErrCodeInvalidInput)
+	}
+
+	var createdBy *uuid.UUID
+	if userID := currentUserID(c); userID != nil {
+		id := uuid.MustParse(*userID)
+		createdBy = &id
+	}
+
+	token, err := h.store.CreateToken(c.RequestCtx(), req.Name, req.ExpiresAt, createdBy)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create SCIM token")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to create SCIM token", ErrCodeOperationFailed)
+	}
+
+	log.Info().
+		Str("scim_token_id", token.ID.String()).
+		Str("name", token.Name).
+		Msg("SCIM token created")
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusCreated).JSON(token)
+}
+
+// RevokeSCIMToken revokes a provisioning token
+// DELETE /api/v1/admin/scim/tokens/:id
+func (h *SCIMHandler) RevokeSCIMToken(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return SendBadRequest(c, "Invalid id", ErrCodeInvalidID)
+	}
+
+	if err := h.store.RevokeToken(c.RequestCtx(), id); err != nil {
+		if errors.Is(err, scim.ErrTokenNotFound) {
+			return SendNotFound(c, err.Error())
+		}
+		log.Error().Err(err).Msg("Failed to revoke SCIM token")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to revoke SCIM token", ErrCodeOperationFailed)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// listQuery parses the filter and pagination parameters of a list request
+func (h *SCIMHandler) listQuery(c fiber.Ctx) (scim.ListQuery, error) {
+	query := scim.ListQuery{ExcludeMembers: excludesMembers(c)}
+	query.StartIndex, query.Count = scim.ParsePagination(c.Query("startIndex"), c.Query("count"))
+	if filter := c.Query("filter"); filter != "" {
+		expr, err := scim.ParseFilter(filter)
+		if err != nil {
+			return query, err
+		}
+		query.Filter = expr
+	}
+	return query, nil
+}
+
+// excludesMembers reports whether the request asks to leave out group members
+func excludesMembers(c fiber.Ctx) bool {
+	for _, attr := range strings.Split(c.Query("excludedAttributes"), ",") {
+		if strings.EqualFold(strings.TrimSpace(attr), "members") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseBody decodes a request body. Identity providers send application/scim+json,
+// which the body binder does not handle, so the body is decoded directly.
+func (h *SCIMHandler) parseBody(c fiber.Ctx, v any) error {
+	if err := json.Unmarshal(c.Body(), v); err != nil {
+		return scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidSyntax, "invalid JSON body")
+	}
+	return nil
+}
+
+func (h *SCIMHandler) tokenID(c fiber.Ctx) string {
+	id, _ := c.Locals("scim_token_id").(string)
+	return id
+}
+
+func (h *SCIMHandler) setLocation(meta *scim.Meta, endpoint, id string) {
+	if meta != nil {
+		meta.Location = h.baseURL + "/" + endpoint + "/" + id
+	}
+}
+
+func (h *SCIMHandler) sendResource(c fiber.Ctx, status int, meta *scim.Meta, endpoint, id string, resource any) error {
+	h.setLocation(meta, endpoint, id)
+	if meta != nil {
+		c.Set(fiber.HeaderLocation, meta.Location)
+	}
+	return h.send(c, status, resource)
+}
+
+func (h *SCIMHandler) send(c fiber.Ctx, status int, body any) error {
+	return c.Status(status).JSON(body, scim.ContentType)
+}
+
+// sendError writes a SCIM error response. Errors other than *scim.Error are logged and
+// reported as internal errors without details.
+func (h *SCIMHandler) sendError(c fiber.Ctx, err error) error {
+	var scimErr *scim.Error
+	if !errors.As(err, &scimErr) {
+		log.Error().Err(err).Str("path", c.Path()).Msg("SCIM request failed")
+		scimErr = scim.NewError(http.StatusInternalServerError, "", "internal server error")
+	}
+	return h.send(c, scimErr.Status, scimErr)
+}
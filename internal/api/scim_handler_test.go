@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/scim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSCIMApp() *fiber.App {
+	handler := NewSCIMHandler(nil, "https://db.example.com/")
+	handler.validateToken = func(_ context.Context, token string) (*scim.Token, error) {
+		if token != "scim_valid" {
+			return nil, scim.ErrInvalidToken
+		}
+		return &scim.Token{ID: uuid.New()}, nil
+	}
+
+	app := fiber.New()
+	handler.RegisterRoutes(app.Group("/api/v1/scim/v2"))
+	return app
+}
+
+func scimRequest(t *testing.T, app *fiber.App, method, target, token string) (*http.Response, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	return resp, body
+}
+
+func TestSCIMHandler_RequiresToken(t *testing.T) {
+	app := newTestSCIMApp()
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"missing token", ""},
+		{"invalid token", "scim_wrong"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := scimRequest(t, app, http.MethodGet, "/api/v1/scim/v2/Users", tt.token)
+			assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+			assert.Contains(t, resp.Header.Get("WWW-Authenticate"), "Bearer")
+			assert.Equal(t, scim.ContentType, resp.Header.Get("Content-Type"))
+			assert.Equal(t, "401", body["status"])
+			assert.Equal(t, []interface{}{scim.SchemaError}, body["schemas"])
+		})
+	}
+}
+
+func TestSCIMHandler_Discovery(t *testing.T) {
+	app := newTestSCIMApp()
+
+	resp, body := scimRequest(t, app, http.MethodGet, "/api/v1/scim/v2/ServiceProviderConfig", "scim_valid")
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, map[string]interface{}{"supported": true}, body["patch"])
+	assert.Equal(t, "https://db.example.com/api/v1/scim/v2/ServiceProviderConfig",
+		body["meta"].(map[string]interface{})["location"])
+
+	resp, body = scimRequest(t, app, http.MethodGet, "/api/v1/scim/v2/ResourceTypes", "scim_valid")
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, float64(2), body["totalResults"])
+
+	resp, body = scimRequest(t, app, http.MethodGet, "/api/v1/scim/v2/Schemas", "scim_valid")
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resources := body["Resources"].([]interface{})
+	require.Len(t, resources, 2)
+	assert.Equal(t, scim.SchemaUser, resources[0].(map[string]interface{})["id"])
+}
+
+func TestSCIMHandler_InvalidFilter(t *testing.T) {
+	app := newTestSCIMApp()
+
+	resp, body := scimRequest(t, app, http.MethodGet, `/api/v1/scim/v2/Users?filter=userName%20eq`, "scim_valid")
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, scim.ErrorTypeInvalidFilter, body["scimType"])
+}
+
+func TestSCIMHandler_InvalidBody(t *testing.T) {
+	app := newTestSCIMApp()
+
+	resp, body := scimRequest(t, app, http.MethodPost, "/api/v1/scim/v2/Users", "scim_valid")
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, scim.ErrorTypeInvalidSyntax, body["scimType"])
+}
+
+func TestExcludesMembers(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.JSON(fiber.Map{"excluded": excludesMembers(c)})
+	})
+
+	for query, want := range map[string]bool{
+		"":                                       false,
+		"?excludedAttributes=members":            true,
+		"?excludedAttributes=externalId,Members": true,
+		"?excludedAttributes=displayName":        false,
+	} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/"+query, nil))
+		require.NoError(t, err)
+		var body map[string]bool
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		_ = resp.Body.Close()
+		assert.Equal(t, want, body["excluded"], query)
+	}
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/secretscan"
+	"github.com/rs/zerolog/log"
+)
+
+// SecretScanHandler handles admin endpoints for secret scanning events
+type SecretScanHandler struct {
+	store *secretscan.Store
+}
+
+// NewSecretScanHandler creates a new secret scanning handler
+func NewSecretScanHandler(store *secretscan.Store) *SecretScanHandler {
+	return &SecretScanHandler{store: store}
+}
+
+// ListSecretScanEvents lists uploads in which credentials were detected
+// GET /api/v1/admin/secret-scanning/events?kind=function&outcome=overridden
+func (h *SecretScanHandler) ListSecretScanEvents(c fiber.Ctx) error {
+	filter := secretscan.ListFilter{
+		Kind:    c.Query("kind"),
+		Outcome: c.Query("outcome"),
+		Limit:   fiber.Query[int](c, "limit", 100),
+		Offset:  fiber.Query[int](c, "offset", 0),
+	}
+
+	events, err := h.store.List(c.RequestCtx(), filter)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list secret scan events")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to list secret scan events", ErrCodeOperationFailed)
+	}
+
+	return c.JSON(fiber.Map{"events": events, "count": len(events)})
+}
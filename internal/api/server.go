@@ -2,7 +2,6 @@ package api
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -18,30 +17,50 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/nimbleflux/fluxbase/internal/adminui"
 	"github.com/nimbleflux/fluxbase/internal/ai"
+	"github.com/nimbleflux/fluxbase/internal/analytics"
+	"github.com/nimbleflux/fluxbase/internal/anonymize"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
+	"github.com/nimbleflux/fluxbase/internal/audit"
 	"github.com/nimbleflux/fluxbase/internal/auth"
 	"github.com/nimbleflux/fluxbase/internal/branching"
+	"github.com/nimbleflux/fluxbase/internal/cache"
+	"github.com/nimbleflux/fluxbase/internal/cdn"
 	"github.com/nimbleflux/fluxbase/internal/config"
 	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/nimbleflux/fluxbase/internal/dsar"
+	"github.com/nimbleflux/fluxbase/internal/egress"
 	"github.com/nimbleflux/fluxbase/internal/email"
 	"github.com/nimbleflux/fluxbase/internal/extensions"
 	"github.com/nimbleflux/fluxbase/internal/functions"
 	"github.com/nimbleflux/fluxbase/internal/jobs"
 	"github.com/nimbleflux/fluxbase/internal/logging"
+	"github.com/nimbleflux/fluxbase/internal/maintenance"
 	"github.com/nimbleflux/fluxbase/internal/mcp"
 	"github.com/nimbleflux/fluxbase/internal/mcp/custom"
 	mcpresources "github.com/nimbleflux/fluxbase/internal/mcp/resources"
 	mcptools "github.com/nimbleflux/fluxbase/internal/mcp/tools"
+	"github.com/nimbleflux/fluxbase/internal/metering"
 	"github.com/nimbleflux/fluxbase/internal/middleware"
 	"github.com/nimbleflux/fluxbase/internal/migrations"
+	"github.com/nimbleflux/fluxbase/internal/notifications"
 	"github.com/nimbleflux/fluxbase/internal/observability"
+	"github.com/nimbleflux/fluxbase/internal/preflight"
 	"github.com/nimbleflux/fluxbase/internal/pubsub"
+	"github.com/nimbleflux/fluxbase/internal/queues"
+	"github.com/nimbleflux/fluxbase/internal/quota"
 	"github.com/nimbleflux/fluxbase/internal/ratelimit"
 	"github.com/nimbleflux/fluxbase/internal/realtime"
 	"github.com/nimbleflux/fluxbase/internal/rpc"
 	"github.com/nimbleflux/fluxbase/internal/scaling"
+	"github.com/nimbleflux/fluxbase/internal/scheduledjobs"
+	"github.com/nimbleflux/fluxbase/internal/scim"
 	"github.com/nimbleflux/fluxbase/internal/secrets"
+	"github.com/nimbleflux/fluxbase/internal/secretscan"
 	"github.com/nimbleflux/fluxbase/internal/settings"
 	"github.com/nimbleflux/fluxbase/internal/storage"
+	"github.com/nimbleflux/fluxbase/internal/tablestats"
+	"github.com/nimbleflux/fluxbase/internal/tablesync"
+	"github.com/nimbleflux/fluxbase/internal/textsearch"
 	"github.com/nimbleflux/fluxbase/internal/webhook"
 	"github.com/rs/zerolog/log"
 )
@@ -59,12 +78,20 @@ type Server struct {
 	clientKeyService       *auth.ClientKeyService // Added for service-wide access
 	clientKeyHandler       *ClientKeyHandler
 	storageHandler         *StorageHandler
+	cdnService             *cdn.Service
+	objectTriggers         *functions.ObjectTriggers
 	webhookHandler         *WebhookHandler
 	monitoringHandler      *MonitoringHandler
 	userManagementHandler  *UserManagementHandler
 	quotaHandler           *QuotaHandler
 	invitationHandler      *InvitationHandler
+	groupHandler           *GroupHandler
+	scimHandler            *SCIMHandler
+	secretScanHandler      *SecretScanHandler
+	organizationHandler    *OrganizationHandler
 	ddlHandler             *DDLHandler
+	indexAdvisorHandler    *IndexAdvisorHandler
+	tableDiffHandler       *TableDiffHandler
 	oauthProviderHandler   *OAuthProviderHandler
 	oauthHandler           *OAuthHandler
 	samlProviderHandler    *SAMLProviderHandler
@@ -89,15 +116,21 @@ type Server struct {
 	realtimeManager        *realtime.Manager
 	realtimeHandler        *realtime.RealtimeHandler
 	realtimeListener       realtime.RealtimeListener
+	realtimeReplication    *realtime.ReplicationListener
 	realtimeAdminHandler   *RealtimeAdminHandler
 	webhookTriggerService  *webhook.TriggerService
 	aiHandler              *ai.Handler
 	aiChatHandler          *ai.ChatHandler
+	aiWidgetHandler        *ai.WidgetHandler
 	aiConversations        *ai.ConversationManager
 	aiMetrics              *observability.Metrics
 	knowledgeBaseHandler   *ai.KnowledgeBaseHandler
 	kbStorage              *ai.KnowledgeBaseStorage
+	kbCache                *ai.KnowledgeBaseCache
+	kbSnapshotScheduler    *ai.KBSnapshotScheduler
+	conversationIndexer    *ai.ConversationIndexer
 	docProcessor           *ai.DocumentProcessor
+	ocrService             *ai.OCRService
 	tableExportSyncService *ai.TableExportSyncService
 	rpcHandler             *rpc.Handler
 	rpcScheduler           *rpc.Scheduler
@@ -108,6 +141,37 @@ type Server struct {
 	loggingService         *logging.Service
 	loggingHandler         *LoggingHandler
 	retentionService       *logging.RetentionService
+	usageRecorder          *observability.UsageRecorder
+	usageHandler           *UsageHandler
+	meteringExporter       *metering.Exporter
+	meteringHandler        *MeteringHandler
+	quotaService           *quota.Service
+	usageQuotaHandler      *UsageQuotaHandler
+	analyticsExporter      *analytics.Exporter
+	analyticsHandler       *AnalyticsHandler
+	tableSyncer            *tablesync.Syncer
+	tableSyncHandler       *TableSyncHandler
+	anonymizedViewsHandler *AnonymizedViewsHandler
+	textSearchHandler      *TextSearchHandler
+	preflightHandler       *PreflightHandler
+	dataExportService      *dsar.Service
+	auditLogger            *audit.Logger // nil when the audit log is disabled
+	auditPruner            *audit.Pruner
+	tusPruner              *storage.TUSPruner        // nil when resumable uploads are disabled
+	lifecycleSweeper       *storage.LifecycleSweeper // nil when the lifecycle sweeper is disabled
+	auditHandler           *AuditHandler
+	tableStatsCollector    *tablestats.Collector
+	tableStatsHandler      *TableStatsHandler
+	maintenanceRunner      *maintenance.Runner
+	maintenanceScheduler   *maintenance.Scheduler
+	maintenanceHandler     *MaintenanceHandler
+	scheduledJobsRunner    *scheduledjobs.Runner
+	scheduledJobsScheduler *scheduledjobs.Scheduler
+	scheduledJobsHandler   *ScheduledJobsHandler
+	queuesHandler          *QueuesHandler // nil when queues are disabled
+	notificationChecker    *notifications.Checker
+	egressProxy            *egress.Proxy
+	notificationsHandler   *NotificationsHandler
 	schemaCache            *database.SchemaCache
 	secretsHandler         *secrets.Handler
 	secretsStorage         *secrets.Storage
@@ -126,10 +190,8 @@ type Server struct {
 	githubWebhook   *GitHubWebhookHandler
 	branchScheduler *branching.CleanupScheduler
 
-	// Leader election for schedulers (used in multi-instance deployments)
-	jobsSchedulerLeader      *scaling.LeaderElector
-	functionsSchedulerLeader *scaling.LeaderElector
-	rpcSchedulerLeader       *scaling.LeaderElector
+	// Leader election for background services (used in multi-instance deployments)
+	leaderElectors []*scaling.LeaderElector
 
 	// Metrics components
 	metrics         *observability.Metrics
@@ -143,6 +205,8 @@ type Server struct {
 	// Server-owned dependencies (instead of global singletons)
 	rateLimiter ratelimit.Store
 	pubSub      pubsub.PubSub
+	sharedCache cache.Cache
+	jobQueue    cache.Queue
 
 	// Shared storage for middleware (rate limiter, CSRF, etc.)
 	// This prevents creating multiple GC goroutines from Fiber's memory.New()
@@ -155,6 +219,8 @@ type Server struct {
 
 // NewServer creates a new HTTP server
 func NewServer(cfg *config.Config, db *database.Connection, version string) *Server {
+	scaling.SetNodeID(cfg.Scaling.NodeID)
+
 	// Create Fiber app with config
 	app := fiber.New(fiber.Config{
 		ServerHeader:      "Fluxbase",
@@ -164,7 +230,7 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 		ReadTimeout:       cfg.Server.ReadTimeout,
 		WriteTimeout:      cfg.Server.WriteTimeout,
 		IdleTimeout:       cfg.Server.IdleTimeout,
-		ErrorHandler:      customErrorHandler,
+		ErrorHandler:      apierror.Handler,
 	})
 
 	// In debug mode, add no-cache headers to prevent browser from caching
@@ -192,13 +258,34 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 		log.Warn().Err(err).Msg("Failed to initialize OpenTelemetry tracer, tracing will be disabled")
 	}
 
+	// Initialize egress policy for outbound requests from functions, jobs and AI providers
+	var egressProxy *egress.Proxy
+	if cfg.Egress.Enabled {
+		egressPolicy, err := egress.NewPolicy(&cfg.Egress, observability.NewMetrics())
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to initialize egress policy")
+		}
+		egressProxy = egress.NewProxy(egressPolicy)
+		if err := egressProxy.Start("127.0.0.1:0"); err != nil {
+			log.Fatal().Err(err).Msg("Failed to start egress proxy")
+		}
+		egressPolicy.SetRuntimeProxyURL(egressProxy.URL())
+		egress.SetGlobalPolicy(egressPolicy)
+		log.Info().
+			Int("allowed_hosts", len(cfg.Egress.AllowedHosts)).
+			Bool("upstream_proxy", cfg.Egress.ProxyURL != "").
+			Msg("Egress policy enabled")
+	} else {
+		egress.SetGlobalPolicy(nil)
+	}
+
 	// Initialize rate limit store based on scaling configuration
 	rateLimitStore, err := ratelimit.NewStore(&cfg.Scaling, db.Pool())
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to initialize rate limit store, falling back to memory")
 		rateLimitStore = nil
 	} else {
-		log.Info().Str("backend", cfg.Scaling.Backend).Msg("Rate limit store initialized")
+		log.Info().Str("backend", cfg.Scaling.BackendFor(config.ScalingComponentRateLimit)).Msg("Rate limit store initialized")
 	}
 
 	// Initialize pub/sub for cross-instance communication
@@ -210,6 +297,23 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 		log.Info().Str("backend", cfg.Scaling.Backend).Msg("Pub/sub initialized for cross-instance broadcasting")
 	}
 
+	// Initialize the shared cache and queue; each falls back to scaling.backend unless overridden
+	sharedCache, err := cache.NewCache(&cfg.Scaling, db.Pool())
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to initialize shared cache, caches will be per-instance")
+		sharedCache = nil
+	} else {
+		log.Info().Str("backend", cfg.Scaling.BackendFor(config.ScalingComponentCache)).Msg("Shared cache initialized")
+	}
+
+	jobQueue, err := cache.NewQueue(&cfg.Scaling, db.Pool())
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to initialize queue, job workers will rely on polling")
+		jobQueue = nil
+	} else {
+		log.Info().Str("backend", cfg.Scaling.BackendFor(config.ScalingComponentQueue)).Msg("Queue initialized")
+	}
+
 	// Initialize shared middleware storage to prevent multiple GC goroutines
 	// Fiber's memory.New() spawns GC goroutines that cannot be stopped
 	// By using a single shared storage, we only get one set of GC goroutines per server
@@ -225,11 +329,16 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 	// Initialize email manager (handles dynamic refresh from settings)
 	// The settings cache and secrets service will be injected later once they're initialized
 	emailManager := email.NewManager(&cfg.Email, nil, nil)
+	if db != nil {
+		// Auth emails use the customized templates for the recipient's locale
+		emailManager.SetTemplateStore(email.NewTemplateStore(db))
+	}
 	// Get a service wrapper that delegates to the manager's current service
 	emailService := emailManager.WrapAsService()
 
 	// Initialize auth service (use public URL for user-facing links like magic links, password resets)
-	authService := auth.NewService(db, &cfg.Auth, emailService, cfg.GetPublicBaseURL())
+	// Its generic sends (OTP codes) are auth email, so they follow the auth email route
+	authService := auth.NewService(db, &cfg.Auth, email.ForCategory(emailService, email.CategoryAuth), cfg.GetPublicBaseURL())
 
 	// Set encryption key for TOTP secrets (uses the global encryption key)
 	authService.SetEncryptionKey(cfg.EncryptionKey)
@@ -327,8 +436,27 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 		captchaService = nil
 	}
 
+	// Record sign-ins, impersonation and admin actions in the audit log
+	auditStore := audit.NewStore(db.Pool())
+	var auditLogger *audit.Logger
+	if cfg.Audit.Enabled {
+		auditLogger = audit.NewLogger(auditStore)
+	}
+
 	// Create handlers
 	authHandler := NewAuthHandler(db.Pool(), authService, captchaService, cfg.GetPublicBaseURL())
+	authHandler.SetAuditLogger(auditLogger)
+	// Track the devices users sign in from and email them about new ones
+	authHandler.SetKnownDeviceService(
+		auth.NewKnownDeviceService(db.Pool(), email.ForCategory(emailService, email.CategoryAuth), cfg.Email.FromName, cfg.Auth.NewDeviceAlerts),
+		&cfg.Server,
+	)
+	// Compile users' self-serve data exports in the background and email them when ready
+	var dataExportService *dsar.Service
+	if cfg.DataExport.Enabled {
+		dataExportService = dsar.NewService(&cfg.DataExport, db.Pool(), storageService.Provider, emailService, cfg.Email.FromName)
+		authHandler.SetDataExportService(dataExportService)
+	}
 	// Create dashboard JWT manager first (shared between auth service and handler)
 	dashboardJWTManager, err := auth.NewJWTManager(cfg.Auth.JWTSecret, 24*time.Hour, 168*time.Hour)
 	if err != nil {
@@ -340,6 +468,17 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 	// Note: dashboardAuthHandler is initialized later after samlService is created
 	clientKeyHandler := NewClientKeyHandler(clientKeyService)
 	storageHandler := NewStorageHandler(storageService, db, &cfg.Storage.Transforms)
+	cdnService := cdn.NewService(cdn.NewStore(db.Pool(), cfg.EncryptionKey), cdn.NewPurger())
+	storageHandler.SetCDN(cdnService)
+	storageHandler.SetS3(clientKeyService, cfg.Auth.JWTSecret, cfg.GetPublicBaseURL()+"/api/v1/storage/s3")
+	storageHandler.SetSignedUploads(cfg.Auth.JWTSecret, cfg.GetPublicBaseURL())
+	var tusStore *storage.TUSStore
+	if cfg.Storage.TUS.Enabled {
+		tusStore = storage.NewTUSStore(db.Pool())
+		storageHandler.SetTUS(tusStore, &cfg.Storage.TUS)
+	}
+	lifecycleStore := storage.NewLifecycleStore(db.Pool())
+	storageHandler.SetLifecycle(lifecycleStore)
 	webhookHandler := NewWebhookHandler(webhookService)
 
 	// Initialize secrets storage and handler
@@ -347,10 +486,28 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 	secretsHandler := secrets.NewHandler(secretsStorage)
 
 	userMgmtHandler := NewUserManagementHandler(userMgmtService, authService)
+	userMgmtHandler.SetAuditLogger(auditLogger)
 	invitationService := auth.NewInvitationService(db)
 	invitationHandler := NewInvitationHandler(invitationService, dashboardAuthService, emailService, cfg.GetPublicBaseURL())
+	groupHandler := NewGroupHandler(auth.NewGroupService(db))
+	scimHandler := NewSCIMHandler(scim.NewStore(db), cfg.GetPublicBaseURL())
+
+	// Secret scanning of function deploys and public knowledge base documents
+	secretScanStore := secretscan.NewStore(db)
+	secretScanHandler := NewSecretScanHandler(secretScanStore)
+	functionsSecretPolicy, err := secretscan.ParsePolicy(cfg.Security.SecretScanning.Functions)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid secret scanning policy for functions")
+	}
+	kbSecretPolicy, err := secretscan.ParsePolicy(cfg.Security.SecretScanning.KnowledgeBases)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid secret scanning policy for knowledge bases")
+	}
+	organizationHandler := NewOrganizationHandler(authService.GetOrganizationService(), email.ForCategory(emailService, email.CategoryAuth), cfg.GetPublicBaseURL())
 	ddlHandler := NewDDLHandler(db)
+	tableDiffHandler := NewTableDiffHandler(db)
 	realtimeAdminHandler := NewRealtimeAdminHandler(db)
+	realtimeAdminHandler.SetReplication(cfg.Realtime.Replication.Publication, cfg.Realtime.Replication.Enabled)
 	serviceKeyHandler := NewServiceKeyHandler(db.Pool())
 	oauthProviderHandler := NewOAuthProviderHandler(db.Pool(), authService.GetSettingsCache(), cfg.EncryptionKey, cfg.GetPublicBaseURL(), cfg.Auth.OAuthProviders)
 	jwtManager, err := auth.NewJWTManager(cfg.Auth.JWTSecret, cfg.Auth.JWTExpiry, cfg.Auth.RefreshExpiry)
@@ -377,6 +534,7 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 	samlProviderHandler = NewSAMLProviderHandler(db.Pool(), samlService)
 	// Initialize dashboard auth handler now that samlService and oauthHandler are available
 	dashboardAuthHandler := NewDashboardAuthHandler(dashboardAuthService, dashboardJWTManager, db, samlService, emailService, cfg.GetPublicBaseURL(), cfg.EncryptionKey, oauthHandler)
+	dashboardAuthHandler.SetAuditLogger(auditLogger)
 	adminSessionHandler := NewAdminSessionHandler(auth.NewSessionRepository(db))
 	systemSettingsHandler := NewSystemSettingsHandler(systemSettingsService, authService.GetSettingsCache())
 	customSettingsService := settings.NewCustomSettingsService(db, cfg.EncryptionKey)
@@ -386,7 +544,7 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 	userSettingsHandler.SetSecretsService(secretsService)
 	appSettingsHandler := NewAppSettingsHandler(systemSettingsService, authService.GetSettingsCache(), cfg)
 	settingsHandler := NewSettingsHandler(db)
-	emailTemplateHandler := NewEmailTemplateHandler(db, emailService)
+	emailTemplateHandler := NewEmailTemplateHandler(db, email.ForCategory(emailService, email.CategoryAuth))
 
 	// Initialize email settings handler with settings cache for dynamic configuration
 	emailSettingsHandler := NewEmailSettingsHandler(
@@ -403,6 +561,7 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 	if err := emailManager.RefreshFromSettings(context.Background()); err != nil {
 		log.Warn().Err(err).Msg("Failed to refresh email service from settings on startup")
 	}
+	adminAuthHandler.SetInstanceBootstrap(authService.GetSettingsCache(), emailManager)
 
 	// Initialize captcha settings handler with settings cache for dynamic configuration
 	captchaSettingsHandler := NewCaptchaSettingsHandler(
@@ -428,6 +587,7 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 		log.Error().Err(err).Msg("Failed to encrypt existing OAuth provider secrets")
 	}
 	sqlHandler := NewSQLHandler(db.Pool(), authService)
+	sqlHandler.SetAuditLogger(auditLogger)
 
 	// Determine public URL for functions SDK client
 	// For edge functions running inside the container, they should use the internal BaseURL
@@ -438,8 +598,11 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 	}
 	functionsHandler := functions.NewHandler(db, cfg.Functions.FunctionsDir, cfg.CORS, cfg.Auth.JWTSecret, functionsInternalURL, cfg.Deno.NpmRegistry, cfg.Deno.JsrRegistry, authService, loggingService, secretsStorage)
 	functionsHandler.SetSettingsSecretsService(secretsService)
+	functionsHandler.SetSecretScanner(secretscan.NewGuard(functionsSecretPolicy, secretScanStore))
 	functionsScheduler := functions.NewScheduler(db, cfg.Auth.JWTSecret, functionsInternalURL, secretsStorage)
 	functionsHandler.SetScheduler(functionsScheduler)
+	objectTriggers := functions.NewObjectTriggers(db, cfg.Auth.JWTSecret, functionsInternalURL, secretsStorage)
+	storageHandler.SetObjectTriggers(objectTriggers)
 
 	// Only create jobs components if jobs are enabled
 	var jobsManager *jobs.Manager
@@ -494,6 +657,10 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 
 	// Create vector manager with hot-reload capability
 	vectorManager := NewVectorManager(&cfg.AI, aiStorage, db.Inspector(), db)
+	// Embedding services keep their own in-memory cache, so only share one across instances
+	if sharedCache != nil && cfg.Scaling.BackendFor(config.ScalingComponentCache) != "local" {
+		vectorManager.SetCache(sharedCache)
+	}
 
 	// Create vector search handler (for pgvector support) - create early for embedding service sharing
 	// Embedding can be enabled explicitly (EmbeddingEnabled=true) or via fallback from AI provider
@@ -524,8 +691,11 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 	// Create AI components (only if AI is enabled)
 	var aiHandler *ai.Handler
 	var aiChatHandler *ai.ChatHandler
+	var aiWidgetHandler *ai.WidgetHandler
 	var aiConversations *ai.ConversationManager
+	var conversationIndexer *ai.ConversationIndexer
 	var aiMetrics *observability.Metrics
+	var kbCache *ai.KnowledgeBaseCache
 	if cfg.AI.Enabled {
 		// Create AI metrics
 		aiMetrics = observability.NewMetrics()
@@ -546,13 +716,37 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 			embeddingService = vectorHandler.GetEmbeddingService()
 		}
 
+		// Embed messages of searchable conversations for semantic conversation search
+		aiHandler.SetEmbeddingService(embeddingService)
+		if embeddingService != nil {
+			conversationIndexer = ai.NewConversationIndexer(aiStorage, embeddingService, 30*time.Second)
+		}
+
 		// Create AI chat handler for WebSocket with RAG support
 		aiChatHandler = ai.NewChatHandler(db, aiStorage, aiConversations, aiMetrics, &cfg.AI, embeddingService, loggingService)
 
+		// Cache knowledge base config and chatbot links read on every chat turn,
+		// invalidated by knowledge base mutations on any instance
+		kbCache = ai.NewKnowledgeBaseCache(time.Minute)
+		if ps != nil {
+			kbCache.SetPubSub(ps)
+		}
+		aiChatHandler.SetKnowledgeBaseCache(kbCache)
+
 		// Create settings resolver for chatbot template variable resolution
 		settingsResolver := ai.NewSettingsResolver(secretsService, 5*time.Minute)
 		aiChatHandler.SetSettingsResolver(settingsResolver)
 
+		// Hand conversations off to humans via webhook/email when chatbots request it
+		aiChatHandler.SetEscalationNotifier(ai.NewEscalationNotifier(aiStorage, emailService))
+
+		// Create public chat widget handler (CAPTCHA is optional per widget)
+		var widgetCaptcha ai.WidgetCaptchaVerifier
+		if captchaService != nil {
+			widgetCaptcha = captchaService
+		}
+		aiWidgetHandler = ai.NewWidgetHandler(aiStorage, aiChatHandler, widgetCaptcha)
+
 		log.Info().
 			Str("chatbots_dir", cfg.AI.ChatbotsDir).
 			Bool("auto_load", cfg.AI.AutoLoadOnBoot).
@@ -590,6 +784,7 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 		}
 
 		kbStorage = ai.NewKnowledgeBaseStorage(db)
+		kbStorage.SetCache(kbCache)
 
 		// Initialize knowledge graph for entity and relationship storage
 		knowledgeGraph := ai.NewKnowledgeGraph(kbStorage)
@@ -601,6 +796,9 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 
 		if vectorHandler != nil && vectorHandler.GetEmbeddingService() != nil {
 			docProcessor = ai.NewDocumentProcessor(kbStorage, vectorHandler.GetEmbeddingService(), entityExtractor, knowledgeGraph)
+			docProcessor.SetSecretScanner(secretscan.NewGuard(kbSecretPolicy, secretScanStore))
+			docProcessor.SetMetrics(aiMetrics)
+			docProcessor.SetEmbeddingBatchOptions(ai.EmbeddingBatchOptionsFromConfig(&cfg.AI))
 		}
 
 		// Use OCR-enabled handler if OCR service is available
@@ -696,6 +894,16 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 		},
 	)
 	realtimeHandler := realtime.NewRealtimeHandler(realtimeManager, realtimeAuthAdapter, realtimeSubManager)
+
+	// Publish knowledge base document status changes on kb:<id> channels
+	if kbStorage != nil {
+		kbStorage.SetDocumentEventPublisher(&kbDocumentPublisher{manager: realtimeManager})
+		realtimeHandler.SetChannelAuthorizer(ai.KnowledgeBaseChannelPrefix, authorizeKBChannel(kbStorage))
+	}
+	// Restrict broadcast and presence channels by role and JWT claim
+	for _, rule := range cfg.Realtime.Channels {
+		realtimeHandler.SetChannelPolicy(rule.Prefix, channelRulePolicy(rule))
+	}
 	realtimeListener := realtime.NewListenerPool(
 		db.Pool(),
 		realtimeHandler,
@@ -707,9 +915,25 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 			QueueSize:   cfg.Realtime.NotificationQueueSize,
 		},
 	)
+	// Tables in the realtime publication stream their changes through logical replication
+	var realtimeReplication *realtime.ReplicationListener
+	if cfg.Realtime.Replication.Enabled {
+		realtimeReplication = realtime.NewReplicationListener(
+			cfg.Database.AdminConnectionString(),
+			db.Pool(),
+			realtimeHandler,
+			realtimeSubManager,
+			realtime.ReplicationConfig{
+				Publication:    cfg.Realtime.Replication.Publication,
+				SlotPrefix:     cfg.Realtime.Replication.SlotPrefix,
+				StatusInterval: cfg.Realtime.Replication.StatusInterval,
+			},
+		)
+	}
 
 	// Create monitoring handler
 	monitoringHandler := NewMonitoringHandler(db.Pool(), realtimeHandler, storageService.Provider)
+	monitoringHandler.SetReplicaStatus(db.ReplicaStatus)
 
 	// Set logging service if available for log queries
 	if loggingService != nil {
@@ -724,17 +948,26 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 		tracer:                 tracer,
 		rest:                   NewRESTHandler(db, NewQueryParser(cfg), schemaCache, cfg),
 		authHandler:            authHandler,
+		auditLogger:            auditLogger,
+		dataExportService:      dataExportService,
 		adminAuthHandler:       adminAuthHandler,
 		dashboardAuthHandler:   dashboardAuthHandler,
 		clientKeyService:       clientKeyService, // Added for service-wide access
 		clientKeyHandler:       clientKeyHandler,
 		storageHandler:         storageHandler,
+		cdnService:             cdnService,
+		objectTriggers:         objectTriggers,
 		webhookHandler:         webhookHandler,
 		monitoringHandler:      monitoringHandler,
 		userManagementHandler:  userMgmtHandler,
 		quotaHandler:           quotaHandler,
 		invitationHandler:      invitationHandler,
+		groupHandler:           groupHandler,
+		scimHandler:            scimHandler,
+		secretScanHandler:      secretScanHandler,
+		organizationHandler:    organizationHandler,
 		ddlHandler:             ddlHandler,
+		tableDiffHandler:       tableDiffHandler,
 		realtimeAdminHandler:   realtimeAdminHandler,
 		oauthProviderHandler:   oauthProviderHandler,
 		oauthHandler:           oauthHandler,
@@ -760,14 +993,19 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 		realtimeManager:        realtimeManager,
 		realtimeHandler:        realtimeHandler,
 		realtimeListener:       realtimeListener,
+		realtimeReplication:    realtimeReplication,
 		webhookTriggerService:  webhookTriggerService,
 		aiHandler:              aiHandler,
 		aiChatHandler:          aiChatHandler,
+		aiWidgetHandler:        aiWidgetHandler,
 		aiConversations:        aiConversations,
 		aiMetrics:              aiMetrics,
 		knowledgeBaseHandler:   knowledgeBaseHandler,
 		kbStorage:              kbStorage,
+		kbCache:                kbCache,
+		conversationIndexer:    conversationIndexer,
 		docProcessor:           docProcessor,
+		ocrService:             ocrService,
 		tableExportSyncService: tableExportSyncService,
 		rpcHandler:             rpcHandler,
 		rpcScheduler:           rpcScheduler,
@@ -777,6 +1015,7 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 		loggingService:         loggingService,
 		loggingHandler:         loggingHandler,
 		retentionService:       retentionService,
+		usageHandler:           NewUsageHandler(db.Pool()),
 		schemaCache:            schemaCache,
 		secretsHandler:         secretsHandler,
 		secretsStorage:         secretsStorage,
@@ -786,10 +1025,13 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 		mcpOAuthHandler:        NewMCPOAuthHandler(db.Pool(), &cfg.MCP, authService, cfg.BaseURL, cfg.GetPublicBaseURL()),
 		internalAIHandler:      internalAIHandler,
 		metrics:                observability.NewMetrics(),
+		egressProxy:            egressProxy,
 		startTime:              time.Now(),
 		// Server-owned dependencies
 		rateLimiter:             rateLimitStore,
 		pubSub:                  ps,
+		sharedCache:             sharedCache,
+		jobQueue:                jobQueue,
 		sharedMiddlewareStorage: sharedMiddlewareStorage,
 	}
 
@@ -837,9 +1079,12 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 			Msg("Database Branching enabled")
 	}
 
+	// The index advisor reads the query patterns recorded by the REST handler
+	server.indexAdvisorHandler = NewIndexAdvisorHandler(db, ddlHandler, server.rest.patterns)
+
 	// Create GraphQL handler (if enabled)
 	if cfg.GraphQL.Enabled {
-		server.graphqlHandler = NewGraphQLHandler(db, schemaCache, &cfg.GraphQL)
+		server.graphqlHandler = NewGraphQLHandler(db, schemaCache, &cfg.GraphQL, &cfg.API)
 		log.Info().
 			Int("max_depth", cfg.GraphQL.MaxDepth).
 			Int("max_complexity", cfg.GraphQL.MaxComplexity).
@@ -847,11 +1092,22 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 			Msg("GraphQL API enabled")
 	}
 
+	// Drop cached aggregate results of tables that change
+	realtimeListener.SetChangeObserver(server.rest.InvalidateAggregates)
+	if realtimeReplication != nil {
+		realtimeReplication.SetChangeObserver(server.rest.InvalidateAggregates)
+	}
+
 	// Start realtime listener (unless disabled or in worker-only mode)
 	if !cfg.Scaling.DisableRealtime && !cfg.Scaling.WorkerOnly {
 		if err := realtimeListener.Start(); err != nil {
 			log.Error().Err(err).Msg("Failed to start realtime listener")
 		}
+		if realtimeReplication != nil {
+			if err := realtimeReplication.Start(); err != nil {
+				log.Error().Err(err).Msg("Failed to start realtime replication listener")
+			}
+		}
 	} else {
 		log.Info().
 			Bool("disable_realtime", cfg.Scaling.DisableRealtime).
@@ -861,33 +1117,14 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 
 	// Start edge functions scheduler (respects scaling configuration)
 	if !cfg.Scaling.DisableScheduler && !cfg.Scaling.WorkerOnly {
-		if cfg.Scaling.EnableSchedulerLeaderElection {
-			// Use leader election - only the leader will run the scheduler
-			server.functionsSchedulerLeader = scaling.NewLeaderElector(
-				db.Pool(),
-				scaling.FunctionsSchedulerLockID,
-				"functions-scheduler",
-			)
-			server.functionsSchedulerLeader.Start(
-				func() {
-					// Became leader - start the scheduler
-					log.Info().Msg("This instance is now the functions scheduler leader")
-					if err := functionsScheduler.Start(); err != nil {
-						log.Error().Err(err).Msg("Failed to start edge functions scheduler")
-					}
-				},
-				func() {
-					// Lost leadership - stop the scheduler
-					log.Warn().Msg("Lost functions scheduler leadership - stopping scheduler")
-					functionsScheduler.Stop()
-				},
-			)
-		} else {
-			// No leader election - start scheduler directly
-			if err := functionsScheduler.Start(); err != nil {
-				log.Error().Err(err).Msg("Failed to start edge functions scheduler")
-			}
-		}
+		server.startLeaderElected(cfg.Scaling, scaling.FunctionsSchedulerLockID, "functions-scheduler",
+			func() {
+				if err := functionsScheduler.Start(); err != nil {
+					log.Error().Err(err).Msg("Failed to start edge functions scheduler")
+				}
+			},
+			functionsScheduler.Stop,
+		)
 	} else {
 		log.Info().
 			Bool("disable_scheduler", cfg.Scaling.DisableScheduler).
@@ -912,33 +1149,14 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 		// Start jobs scheduler for cron-based execution (respects scaling configuration)
 		if jobsScheduler != nil {
 			if !cfg.Scaling.DisableScheduler && !cfg.Scaling.WorkerOnly {
-				if cfg.Scaling.EnableSchedulerLeaderElection {
-					// Use leader election - only the leader will run the scheduler
-					server.jobsSchedulerLeader = scaling.NewLeaderElector(
-						db.Pool(),
-						scaling.JobsSchedulerLockID,
-						"jobs-scheduler",
-					)
-					server.jobsSchedulerLeader.Start(
-						func() {
-							// Became leader - start the scheduler
-							log.Info().Msg("This instance is now the jobs scheduler leader")
-							if err := jobsScheduler.Start(); err != nil {
-								log.Error().Err(err).Msg("Failed to start jobs scheduler")
-							}
-						},
-						func() {
-							// Lost leadership - stop the scheduler
-							log.Warn().Msg("Lost jobs scheduler leadership - stopping scheduler")
-							jobsScheduler.Stop()
-						},
-					)
-				} else {
-					// No leader election - start scheduler directly
-					if err := jobsScheduler.Start(); err != nil {
-						log.Error().Err(err).Msg("Failed to start jobs scheduler")
-					}
-				}
+				server.startLeaderElected(cfg.Scaling, scaling.JobsSchedulerLockID, "jobs-scheduler",
+					func() {
+						if err := jobsScheduler.Start(); err != nil {
+							log.Error().Err(err).Msg("Failed to start jobs scheduler")
+						}
+					},
+					jobsScheduler.Stop,
+				)
 			} else {
 				log.Info().
 					Bool("disable_scheduler", cfg.Scaling.DisableScheduler).
@@ -951,33 +1169,14 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 	// Start RPC scheduler for cron-based procedure execution (respects scaling configuration)
 	if cfg.RPC.Enabled && rpcScheduler != nil {
 		if !cfg.Scaling.DisableScheduler && !cfg.Scaling.WorkerOnly {
-			if cfg.Scaling.EnableSchedulerLeaderElection {
-				// Use leader election - only the leader will run the scheduler
-				server.rpcSchedulerLeader = scaling.NewLeaderElector(
-					db.Pool(),
-					scaling.RPCSchedulerLockID,
-					"rpc-scheduler",
-				)
-				server.rpcSchedulerLeader.Start(
-					func() {
-						// Became leader - start the scheduler
-						log.Info().Msg("This instance is now the RPC scheduler leader")
-						if err := rpcScheduler.Start(); err != nil {
-							log.Error().Err(err).Msg("Failed to start RPC scheduler")
-						}
-					},
-					func() {
-						// Lost leadership - stop the scheduler
-						log.Warn().Msg("Lost RPC scheduler leadership - stopping scheduler")
-						rpcScheduler.Stop()
-					},
-				)
-			} else {
-				// No leader election - start scheduler directly
-				if err := rpcScheduler.Start(); err != nil {
-					log.Error().Err(err).Msg("Failed to start RPC scheduler")
-				}
-			}
+			server.startLeaderElected(cfg.Scaling, scaling.RPCSchedulerLockID, "rpc-scheduler",
+				func() {
+					if err := rpcScheduler.Start(); err != nil {
+						log.Error().Err(err).Msg("Failed to start RPC scheduler")
+					}
+				},
+				rpcScheduler.Stop,
+			)
 		} else {
 			log.Info().
 				Bool("disable_scheduler", cfg.Scaling.DisableScheduler).
@@ -991,17 +1190,132 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 		log.Error().Err(err).Msg("Failed to start webhook trigger service")
 	}
 
+	// Start API usage analytics (every node records its own traffic)
+	if cfg.API.UsageTracking {
+		server.usageRecorder = observability.NewUsageRecorder(db.Pool(), cfg.API.UsageFlushInterval, cfg.API.UsageRetentionDays)
+		server.usageRecorder.Start()
+	}
+
+	// Start billing/metering exports (a single node exports each period)
+	if cfg.Metering.Enabled {
+		server.meteringExporter = metering.NewExporter(&cfg.Metering, db.Pool(), storageService.Provider, cfg.GetPublicBaseURL())
+		server.startLeaderElected(cfg.Scaling, scaling.MeteringExportLockID, "metering-export", server.meteringExporter.Start, server.meteringExporter.Stop)
+	}
+	server.meteringHandler = NewMeteringHandler(db.Pool(), server.meteringExporter, cfg.GetPublicBaseURL())
+
+	// Start usage quota enforcement (every node counts its own traffic into shared counters)
+	if cfg.Quotas.Enabled {
+		server.quotaService = quota.NewService(db.Pool(), cfg.Quotas.FlushInterval, cfg.Quotas.CacheTTL)
+		server.quotaService.Start()
+	}
+	server.usageQuotaHandler = NewUsageQuotaHandler(server.quotaService)
+
+	// Start analytics warehouse exports (a single node exports each source)
+	if cfg.Analytics.Enabled {
+		server.analyticsExporter = analytics.NewExporter(&cfg.Analytics, db.Pool(), storageService.Provider)
+		server.startLeaderElected(cfg.Scaling, scaling.AnalyticsExportLockID, "analytics-export", server.analyticsExporter.Start, server.analyticsExporter.Stop)
+	}
+	server.analyticsHandler = NewAnalyticsHandler(server.analyticsExporter)
+
+	// Start syncing selected tables to other instances (a single node sends each change)
+	tableSyncStore := tablesync.NewStore(db.Pool(), cfg.EncryptionKey)
+	if cfg.TableSync.Enabled {
+		server.tableSyncer = tablesync.NewSyncer(&cfg.TableSync, db, tableSyncStore, cfg.GetPublicBaseURL(), server.metrics)
+		server.startLeaderElected(cfg.Scaling, scaling.TableSyncLockID, "table-sync", server.tableSyncer.Start, server.tableSyncer.Stop)
+	}
+	server.tableSyncHandler = NewTableSyncHandler(tableSyncStore, tablesync.NewIngester(db), cfg.TableSync.Enabled, cfg.TableSync.AcceptIngest)
+
+	// Anonymized views of sensitive tables, served read-only by the REST API
+	server.anonymizedViewsHandler = NewAnonymizedViewsHandler(anonymize.NewService(db, schemaCache))
+
+	// Full text search languages, weights and generated tsvector columns of tables
+	server.textSearchHandler = NewTextSearchHandler(textsearch.NewService(db, schemaCache))
+
+	// Results of the startup preflight checks; main sets the boot report
+	server.preflightHandler = NewPreflightHandler(preflight.NewRunner(cfg, db))
+
+	// Prune audit events older than the retention (a single node prunes)
+	if cfg.Audit.Enabled && cfg.Audit.RetentionDays > 0 {
+		server.auditPruner = audit.NewPruner(&cfg.Audit, auditStore)
+		server.startLeaderElected(cfg.Scaling, scaling.AuditRetentionLockID, "audit-retention", server.auditPruner.Start, server.auditPruner.Stop)
+	}
+	server.auditHandler = NewAuditHandler(auditStore)
+
+	// Abort expired resumable uploads (a single node prunes)
+	if uploader, ok := storageService.Provider.(storage.ChunkedUploader); ok && tusStore != nil {
+		server.tusPruner = storage.NewTUSPruner(tusStore, uploader)
+		server.startLeaderElected(cfg.Scaling, scaling.TUSPruneLockID, "tus-prune", server.tusPruner.Start, server.tusPruner.Stop)
+	}
+
+	// Apply storage lifecycle rules (a single node sweeps)
+	if cfg.Storage.Lifecycle.Enabled {
+		server.lifecycleSweeper = storage.NewLifecycleSweeper(lifecycleStore, storageService.Provider, &cfg.Storage.Lifecycle)
+		server.startLeaderElected(cfg.Scaling, scaling.StorageLifecycleLockID, "storage-lifecycle", server.lifecycleSweeper.Start, server.lifecycleSweeper.Stop)
+	}
+
+	// Start daily table size snapshots (a single node takes each day's snapshot)
+	tableStatsStore := tablestats.NewStore(db.Pool())
+	if cfg.TableStats.Enabled {
+		server.tableStatsCollector = tablestats.NewCollector(&cfg.TableStats, tableStatsStore)
+		server.startLeaderElected(cfg.Scaling, scaling.TableStatsLockID, "table-stats", server.tableStatsCollector.Start, server.tableStatsCollector.Stop)
+	}
+	server.tableStatsHandler = NewTableStatsHandler(tableStatsStore, server.tableStatsCollector)
+
+	// Start table maintenance schedules and log partition management (a single node runs
+	// them, manual runs execute on the node that received the request)
+	maintenanceStore := maintenance.NewStore(db.Pool())
+	server.maintenanceRunner = maintenance.NewRunner(maintenanceStore, cfg.Maintenance.RunTimeout)
+	if cfg.Maintenance.Enabled {
+		server.maintenanceScheduler = maintenance.NewScheduler(&cfg.Maintenance, maintenanceStore, server.maintenanceRunner)
+		server.startLeaderElected(cfg.Scaling, scaling.MaintenanceSchedulerLockID, "maintenance-scheduler", server.maintenanceScheduler.Start, server.maintenanceScheduler.Stop)
+	}
+	server.maintenanceHandler = NewMaintenanceHandler(maintenanceStore, server.maintenanceRunner)
+
+	// Start scheduled edge functions and SQL snippets (a single node fires the schedules,
+	// manual runs execute on the node that received the request)
+	scheduledJobsStore := scheduledjobs.NewStore(db.Pool())
+	server.scheduledJobsRunner = scheduledjobs.NewRunner(scheduledJobsStore, functionsScheduler, cfg.ScheduledJobs.RunTimeout, cfg.ScheduledJobs.MaxOutputBytes)
+	if cfg.ScheduledJobs.Enabled && !cfg.Scaling.DisableScheduler && !cfg.Scaling.WorkerOnly {
+		server.scheduledJobsScheduler = scheduledjobs.NewScheduler(&cfg.ScheduledJobs, scheduledJobsStore, server.scheduledJobsRunner)
+		server.startLeaderElected(cfg.Scaling, scaling.ScheduledJobsLockID, "scheduled-jobs", server.scheduledJobsScheduler.Start, server.scheduledJobsScheduler.Stop)
+	}
+	server.scheduledJobsHandler = NewScheduledJobsHandler(scheduledJobsStore, server.scheduledJobsRunner)
+
+	if cfg.Queues.Enabled {
+		server.queuesHandler = NewQueuesHandler(queues.NewStore(db.Pool()), &cfg.Queues)
+	}
+
+	// Start credential expiry and configuration drift checks (a single node runs them,
+	// every node reports its own email delivery failures)
+	notificationStore := notifications.NewStore(db.Pool())
+	if cfg.Notifications.Enabled {
+		server.notificationChecker = notifications.NewChecker(&cfg.Notifications, db.Pool(), notificationStore, samlService)
+		server.startLeaderElected(cfg.Scaling, scaling.NotificationCheckLockID, "notification-check", server.notificationChecker.Start, server.notificationChecker.Stop)
+		emailManager.SetDeliveryObserver(func(status email.DeliveryStatus) {
+			go server.notificationChecker.RecordEmailDelivery(status)
+		})
+	}
+	server.notificationsHandler = NewNotificationsHandler(notificationStore)
+
 	// Start retention cleanup service (for central logging)
 	if retentionService != nil {
-		retentionService.Start()
-		log.Info().
-			Dur("interval", cfg.Logging.RetentionCheckInterval).
-			Msg("Log retention cleanup service started")
+		server.startLeaderElected(cfg.Scaling, scaling.LogRetentionLockID, "log-retention", retentionService.Start, retentionService.Stop)
 	}
 
 	// Start branch cleanup scheduler
 	if server.branchScheduler != nil {
-		server.branchScheduler.Start()
+		server.startLeaderElected(cfg.Scaling, scaling.BranchCleanupLockID, "branch-cleanup", server.branchScheduler.Start, server.branchScheduler.Stop)
+	}
+
+	// Start knowledge base snapshot scheduler
+	if server.kbStorage != nil {
+		server.kbSnapshotScheduler = ai.NewKBSnapshotScheduler(server.kbStorage, 5*time.Minute)
+		server.startLeaderElected(cfg.Scaling, scaling.KBSnapshotSchedulerLockID, "kb-snapshot-scheduler", server.kbSnapshotScheduler.Start, server.kbSnapshotScheduler.Stop)
+	}
+
+	// Start conversation search indexer
+	if server.conversationIndexer != nil {
+		server.startLeaderElected(cfg.Scaling, scaling.ConversationIndexLockID, "conversation-index", server.conversationIndexer.Start, server.conversationIndexer.Stop)
 	}
 
 	// Start Prometheus metrics server if enabled
@@ -1030,7 +1344,13 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 		// Wire up rate limiter metrics
 		middleware.SetRateLimiterMetrics(server.metrics)
 
-		// Start uptime tracking goroutine
+		// Wire up embedding provider metrics
+		vectorManager.SetMetrics(server.metrics)
+
+		// Wire up REST statement cache metrics
+		server.rest.SetMetrics(server.metrics)
+
+		// Start uptime and connection pool tracking goroutine
 		server.metricsStopChan = make(chan struct{})
 		go func() {
 			ticker := time.NewTicker(15 * time.Second)
@@ -1039,6 +1359,7 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 				select {
 				case <-ticker.C:
 					server.metrics.UpdateUptime(server.startTime)
+					db.RecordPoolStats()
 				case <-server.metricsStopChan:
 					return
 				}
@@ -1068,6 +1389,9 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 	log.Debug().Msg("Setting up middlewares")
 	server.setupMiddlewares()
 
+	// Rate limiters created by the routes count with the configured algorithm
+	middleware.SetRateLimitAlgorithm(ratelimit.Algorithm(cfg.Security.RateLimitAlgorithm))
+
 	// Setup routes
 	log.Debug().Msg("Setting up routes")
 	server.setupRoutes()
@@ -1076,10 +1400,15 @@ func NewServer(cfg *config.Config, db *database.Connection, version string) *Ser
 	// The server owns these dependencies and will close them on shutdown
 	if server.rateLimiter != nil {
 		ratelimit.SetGlobalStore(server.rateLimiter)
+		// Middleware rate limiters count in the same store so limits hold across instances
+		middleware.SetRateLimitStore(server.rateLimiter)
 	}
 	if server.pubSub != nil {
 		pubsub.SetGlobalPubSub(server.pubSub)
 	}
+	if server.jobQueue != nil {
+		jobs.SetWakeupQueue(server.jobQueue)
+	}
 
 	log.Debug().Msg("Server initialization complete")
 	return server
@@ -1350,17 +1679,22 @@ func (s *Server) setupMiddlewares() {
 		s.app.Use(s.metrics.MetricsMiddleware())
 	}
 
+	// API usage middleware - hourly rollups per client key and per user
+	if s.usageRecorder != nil {
+		log.Debug().Msg("Adding API usage middleware")
+		s.app.Use(s.usageRecorder.Middleware([]string{"/health", "/ready", "/metrics", "/admin"}))
+	}
+
 	// Security headers middleware - protect against common attacks
-	// Apply different CSP for admin UI (needs Google Fonts) vs API routes
-	log.Debug().Msg("Adding security headers middleware")
-	s.app.Use(func(c fiber.Ctx) error {
-		// Apply relaxed CSP for admin UI
-		if strings.HasPrefix(c.Path(), "/admin") {
-			return middleware.AdminUISecurityHeaders()(c)
-		}
-		// Apply strict CSP for all other routes
-		return middleware.SecurityHeaders()(c)
-	})
+	// Admin UI gets a relaxed, nonce-based CSP (needs Google Fonts) while API routes stay strict.
+	// Values from the settings registry override the static configuration at runtime.
+	if s.config.Security.Headers.Enabled {
+		log.Debug().Msg("Adding security headers middleware")
+		s.app.Use(middleware.PolicySecurityHeaders(
+			securityHeadersPolicy(s.config.Security.Headers),
+			s.authHandler.authService.GetSettingsCache(),
+		))
+	}
 
 	// Structured logger middleware - logs HTTP requests through zerolog
 	// This allows HTTP logs to be captured by the central logging system
@@ -1377,6 +1711,12 @@ func (s *Server) setupMiddlewares() {
 		EnableStackTrace: s.config.Debug,
 	}))
 
+	// Chat widget CORS - widgets are embedded on customer sites outside the configured origins,
+	// so their allowlists are checked before the global CORS middleware
+	if s.aiWidgetHandler != nil {
+		s.app.Use("/api/v1/ai/widget", s.aiWidgetHandler.CORS)
+	}
+
 	// CORS middleware
 	// Note: AllowCredentials cannot be used with AllowOrigins="*" per CORS spec
 	// If AllowOrigins contains "*", we must disable credentials
@@ -1439,6 +1779,33 @@ func (s *Server) setupMiddlewares() {
 	s.app.Use(cors.New(corsConfig))
 	log.Debug().Msg("CORS middleware added")
 
+	// Load shedding - reject anonymous reads with 503 while the database pool is saturated or
+	// requests are slow, so auth, dashboard and webhook traffic keeps working.
+	// Runs after CORS so browsers can read the 503.
+	if s.config.Server.LoadShedding.Enabled && s.db != nil {
+		sheddingCfg := s.config.Server.LoadShedding
+		shedder := middleware.NewLoadShedder(middleware.LoadSheddingConfig{
+			PoolSaturation:   sheddingCfg.PoolSaturation,
+			LatencyThreshold: sheddingCfg.LatencyThreshold,
+			Cooldown:         sheddingCfg.Cooldown,
+			ProtectedPaths:   sheddingCfg.ProtectedPaths,
+			PoolUsage: func() float64 {
+				stat := s.db.Pool().Stat()
+				if stat.MaxConns() == 0 {
+					return 0
+				}
+				return float64(stat.AcquiredConns()) / float64(stat.MaxConns())
+			},
+			Metrics: s.metrics,
+		})
+		s.app.Use(shedder.Middleware())
+		log.Info().
+			Float64("pool_saturation", sheddingCfg.PoolSaturation).
+			Dur("latency_threshold", sheddingCfg.LatencyThreshold).
+			Dur("cooldown", sheddingCfg.Cooldown).
+			Msg("Load shedding enabled")
+	}
+
 	// Global IP allowlist - restrict access to entire API
 	// Only log and apply if ranges are configured (empty = allow all)
 	if len(s.config.Server.AllowedIPRanges) > 0 {
@@ -1478,6 +1845,21 @@ func (s *Server) setupMiddlewares() {
 			Msg("Per-endpoint body limits enabled")
 	}
 
+	// Validate requests to built-in endpoints against the OpenAPI spec for the configured route groups
+	if len(s.config.API.ValidateRequests) > 0 {
+		spec := NewOpenAPIHandler(s.db).generateSpec(nil, "")
+		validator, err := NewRequestValidator(spec, s.config.API.ValidateRequests)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to build request validator, request validation disabled")
+		} else {
+			s.app.Use(validator.Middleware())
+			log.Info().
+				Strs("groups", s.config.API.ValidateRequests).
+				Int("operations", validator.OperationCount()).
+				Msg("OpenAPI request validation enabled")
+		}
+	}
+
 	// Idempotency key support for safe request retries
 	// Stores responses in database to return cached results for duplicate POST/PUT/DELETE/PATCH requests
 	idempotencyConfig := middleware.DefaultIdempotencyConfig()
@@ -1495,6 +1877,45 @@ func (s *Server) setupMiddlewares() {
 	}))
 }
 
+// securityHeadersPolicy builds the security headers policy from configuration.
+func securityHeadersPolicy(cfg config.SecurityHeadersConfig) middleware.SecurityHeadersPolicy {
+	// The dashboard only relaxes the CSP; every other header follows the defaults
+	dashboardCSP := middleware.AdminUISecurityHeadersConfig().ContentSecurityPolicy
+	if cfg.DashboardContentSecurityPolicy != "" {
+		dashboardCSP = cfg.DashboardContentSecurityPolicy
+	}
+
+	policy := middleware.SecurityHeadersPolicy{
+		Default: middleware.DefaultSecurityHeadersConfig(),
+		Routes: []middleware.SecurityHeadersRoute{{
+			PathPrefix:    "/admin",
+			Headers:       middleware.SecurityHeadersConfig{ContentSecurityPolicy: dashboardCSP},
+			CSPSettingKey: middleware.SettingSecurityHeadersDashboardCSP,
+		}},
+	}
+	policy.Default = policy.Default.Merge(middleware.SecurityHeadersConfig{
+		ContentSecurityPolicy:   cfg.ContentSecurityPolicy,
+		XFrameOptions:           cfg.XFrameOptions,
+		StrictTransportSecurity: cfg.StrictTransportSecurity,
+		ReferrerPolicy:          cfg.ReferrerPolicy,
+		PermissionsPolicy:       cfg.PermissionsPolicy,
+	})
+
+	for _, route := range cfg.Routes {
+		policy.Routes = append(policy.Routes, middleware.SecurityHeadersRoute{
+			PathPrefix: route.PathPrefix,
+			Headers: middleware.SecurityHeadersConfig{
+				ContentSecurityPolicy:   route.ContentSecurityPolicy,
+				XFrameOptions:           route.XFrameOptions,
+				StrictTransportSecurity: route.StrictTransportSecurity,
+				ReferrerPolicy:          route.ReferrerPolicy,
+				PermissionsPolicy:       route.PermissionsPolicy,
+			},
+		})
+	}
+	return policy
+}
+
 // setupRoutes sets up all routes
 func (s *Server) setupRoutes() {
 	// Root path - simple health response
@@ -1523,6 +1944,7 @@ func (s *Server) setupRoutes() {
 	restMiddlewares := []any{
 		middleware.RequireAuthOrServiceKey(s.authHandler.authService, s.clientKeyService, s.DB(), s.dashboardAuthHandler.jwtManager),
 		middleware.RLSMiddleware(rlsConfig),
+		s.quotaMiddleware(quota.MetricAPICalls, nil),
 	}
 	// Add branch context middleware if branching is enabled
 	if s.branchRouter != nil {
@@ -1559,6 +1981,9 @@ func (s *Server) setupRoutes() {
 	authRoutes := v1.Group("/auth", csrfMiddleware)
 	s.setupAuthRoutes(authRoutes)
 
+	// SCIM provisioning routes - authenticated with a provisioning token instead of a user
+	s.scimHandler.RegisterRoutes(v1.Group("/scim/v2"))
+
 	// Public settings routes - optional authentication with RLS support
 	// These routes respect app.settings RLS policies based on is_public and is_secret flags
 	settings := v1.Group("/settings", OptionalAuthMiddleware(s.authHandler.authService))
@@ -1591,6 +2016,8 @@ func (s *Server) setupRoutes() {
 
 	// client keys routes - require authentication
 	// When 'allow_user_client_keys' setting is disabled, only admins can manage keys
+	// Enrollment is authenticated by the bootstrap token itself, so it is registered before the group middleware
+	s.app.Post("/api/v1/client-keys/enroll", middleware.ClientKeyEnrollLimiter(s.sharedMiddlewareStorage), s.clientKeyHandler.EnrollClientKey)
 	s.clientKeyHandler.RegisterRoutes(s.app, s.authHandler.authService, s.clientKeyService, s.DB(), s.dashboardAuthHandler.jwtManager, s.authHandler.authService.GetSettingsCache())
 
 	// Secrets routes - require authentication
@@ -1604,6 +2031,11 @@ func (s *Server) setupRoutes() {
 	// Webhook routes - require authentication
 	s.webhookHandler.RegisterRoutes(s.app, s.authHandler.authService, s.clientKeyService, s.DB(), s.dashboardAuthHandler.jwtManager)
 
+	// Message queue routes (only when queues are enabled)
+	if s.queuesHandler != nil {
+		s.queuesHandler.RegisterRoutes(s.app, s.authHandler.authService, s.clientKeyService, s.DB(), s.dashboardAuthHandler.jwtManager)
+	}
+
 	// Monitoring routes - require authentication
 	s.monitoringHandler.RegisterRoutes(s.app, s.authHandler.authService, s.clientKeyService, s.DB(), s.dashboardAuthHandler.jwtManager)
 
@@ -1639,6 +2071,7 @@ func (s *Server) setupRoutes() {
 	storageMiddlewares := []any{
 		middleware.RequireStorageEnabled(s.authHandler.authService.GetSettingsCache()),
 		middleware.OptionalAuthOrServiceKey(s.authHandler.authService, s.clientKeyService, s.DB()),
+		s.quotaMiddleware(quota.MetricAPICalls, nil),
 	}
 	if s.branchRouter != nil {
 		storageMiddlewares = append(storageMiddlewares, middleware.BranchContextSimple(s.branchRouter))
@@ -1646,6 +2079,12 @@ func (s *Server) setupRoutes() {
 	storage := v1.Group("/storage", storageMiddlewares...)
 	s.setupStorageRoutes(storage)
 
+	// Usage of the caller and its active organization against their quotas
+	v1.Get("/usage",
+		middleware.RequireAuthOrServiceKey(s.authHandler.authService, s.clientKeyService, s.DB(), s.dashboardAuthHandler.jwtManager),
+		s.usageQuotaHandler.GetMyUsage,
+	)
+
 	// MCP routes - Model Context Protocol for AI assistants
 	// Requires authentication via client key, service key, or OAuth token
 	if s.config.MCP.Enabled && s.mcpHandler != nil {
@@ -1709,6 +2148,7 @@ func (s *Server) setupRoutes() {
 		s.app.Get("/ai/ws",
 			middleware.RequireAIEnabled(s.authHandler.authService.GetSettingsCache()),
 			middleware.OptionalAuthOrServiceKey(s.authHandler.authService, s.clientKeyService, s.db.Pool(), s.dashboardAuthHandler.jwtManager),
+			s.quotaMiddleware(quota.MetricAITokens, nil),
 			s.aiChatHandler.HandleWebSocket,
 		)
 
@@ -1733,12 +2173,30 @@ func (s *Server) setupRoutes() {
 			s.aiHandler.GetPublicChatbot,
 		)
 
+		// Public chat widget endpoints (widget key / session token scoped, no user auth)
+		if s.aiWidgetHandler != nil {
+			widgetRouter := s.app.Group("/api/v1/ai/widget",
+				middleware.RequireAIEnabled(s.authHandler.authService.GetSettingsCache()),
+			)
+			widgetRouter.Get("/config", s.aiWidgetHandler.GetWidgetConfig)
+			widgetRouter.Post("/sessions", s.aiWidgetHandler.CreateSession)
+			widgetRouter.Post("/messages", s.aiWidgetHandler.SendMessage)
+			widgetRouter.Post("/lead", s.aiWidgetHandler.CaptureLead)
+			widgetRouter.Get("/transcript", s.aiWidgetHandler.GetTranscript)
+		}
+
 		// User conversation history endpoints (require authentication)
 		s.app.Get("/api/v1/ai/conversations",
 			middleware.RequireAIEnabled(s.authHandler.authService.GetSettingsCache()),
 			middleware.RequireAuthOrServiceKey(s.authHandler.authService, s.clientKeyService, s.db.Pool(), s.dashboardAuthHandler.jwtManager),
 			s.aiHandler.ListUserConversations,
 		)
+		// Registered before /:id so "search" is not taken as a conversation ID
+		s.app.Get("/api/v1/ai/conversations/search",
+			middleware.RequireAIEnabled(s.authHandler.authService.GetSettingsCache()),
+			middleware.RequireAuthOrServiceKey(s.authHandler.authService, s.clientKeyService, s.db.Pool(), s.dashboardAuthHandler.jwtManager),
+			s.aiHandler.SearchUserConversations,
+		)
 
 		s.app.Get("/api/v1/ai/conversations/:id",
 			middleware.RequireAIEnabled(s.authHandler.authService.GetSettingsCache()),
@@ -1766,12 +2224,19 @@ func (s *Server) setupRoutes() {
 			)
 			// Use document-enabled routes if processor is available
 			if s.docProcessor != nil {
-				ai.RegisterUserKnowledgeBaseRoutesWithDocuments(userKBRouter, s.kbStorage, s.docProcessor)
+				ai.RegisterUserKnowledgeBaseRoutesWithDocuments(userKBRouter, s.kbStorage, s.docProcessor, s.ocrService, s.auditLogger)
 				log.Info().Msg("User-facing knowledge base routes registered (with document support)")
 			} else {
-				ai.RegisterUserKnowledgeBaseRoutes(userKBRouter, s.kbStorage)
+				ai.RegisterUserKnowledgeBaseRoutes(userKBRouter, s.kbStorage, s.auditLogger)
 				log.Info().Msg("User-facing knowledge base routes registered")
 			}
+
+			// Standalone vector collections (text inputs are embedded when an embedding service is configured)
+			var collectionEmbeddingService *ai.EmbeddingService
+			if s.vectorHandler != nil {
+				collectionEmbeddingService = s.vectorHandler.GetEmbeddingService()
+			}
+			ai.RegisterVectorCollectionRoutes(userKBRouter, s.kbStorage, collectionEmbeddingService)
 		}
 	}
 
@@ -1790,6 +2255,7 @@ func (s *Server) setupRoutes() {
 			middleware.RequireRPCEnabled(s.authHandler.authService.GetSettingsCache()),
 			middleware.OptionalAuthOrServiceKey(s.authHandler.authService, s.clientKeyService, s.db.Pool(), s.dashboardAuthHandler.jwtManager),
 			middleware.RequireScope(auth.ScopeRPCExecute),
+			s.quotaMiddleware(quota.MetricAPICalls, nil),
 			s.rpcHandler.Invoke,
 		)
 
@@ -1819,12 +2285,24 @@ func (s *Server) setupRoutes() {
 		// Embedding endpoint (requires authentication)
 		s.app.Post("/api/v1/vector/embed",
 			middleware.RequireAuthOrServiceKey(s.authHandler.authService, s.clientKeyService, s.db.Pool(), s.dashboardAuthHandler.jwtManager),
+			s.quotaMiddleware(quota.MetricAPICalls, nil),
+			s.quotaMiddleware(quota.MetricAITokens, nil),
 			s.vectorHandler.HandleEmbed,
 		)
 
+		// Batch embeddings API (requires authentication, metered against embedding quotas)
+		s.app.Post("/api/v1/ai/embeddings",
+			middleware.RequireAIEnabled(s.authHandler.authService.GetSettingsCache()),
+			middleware.RequireAuthOrServiceKey(s.authHandler.authService, s.clientKeyService, s.db.Pool(), s.dashboardAuthHandler.jwtManager),
+			s.quotaMiddleware(quota.MetricAPICalls, nil),
+			s.quotaMiddleware(quota.MetricAITokens, nil),
+			s.vectorHandler.HandleBatchEmbed,
+		)
+
 		// Vector search endpoint (requires authentication)
 		s.app.Post("/api/v1/vector/search",
 			middleware.RequireAuthOrServiceKey(s.authHandler.authService, s.clientKeyService, s.db.Pool(), s.dashboardAuthHandler.jwtManager),
+			s.quotaMiddleware(quota.MetricAPICalls, nil),
 			s.vectorHandler.HandleSearch,
 		)
 
@@ -1836,6 +2314,7 @@ func (s *Server) setupRoutes() {
 		// GraphQL uses its own auth handling to set up RLS context
 		s.app.Post("/api/v1/graphql",
 			middleware.OptionalAuthOrServiceKey(s.authHandler.authService, s.clientKeyService, s.DB(), s.dashboardAuthHandler.jwtManager),
+			s.quotaMiddleware(quota.MetricAPICalls, nil),
 			s.graphqlHandler.HandleGraphQL,
 		)
 		// Introspection endpoint (GET)
@@ -1850,6 +2329,15 @@ func (s *Server) setupRoutes() {
 	admin := v1.Group("/admin")
 	s.setupAdminRoutes(admin)
 
+	// Instance bootstrap for fresh installs - available with a setup token even without the admin UI
+	if s.config.Security.SetupToken != "" {
+		admin.Post("/bootstrap", middleware.AdminSetupLimiterWithConfig(
+			s.config.Security.AdminSetupRateLimit,
+			s.config.Security.AdminSetupRateWindow,
+			s.sharedMiddlewareStorage,
+		), s.adminAuthHandler.BootstrapInstance)
+	}
+
 	// Public invitation routes (no auth required)
 	invitations := v1.Group("/invitations")
 	s.setupPublicInvitationRoutes(invitations)
@@ -1951,6 +2439,7 @@ func (s *Server) setupRoutes() {
 	s.app.Use(func(c fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{
 			"error": "Not Found",
+			"code":  ErrCodeNotFound,
 			"path":  c.Path(),
 		})
 	})
@@ -1978,6 +2467,45 @@ func (s *Server) setupRESTRoutes(router fiber.Router) {
 		middleware.RequireScope(auth.ScopeTablesRead),
 		s.rest.HandleDynamicQuery)
 
+	// Streaming CSV export: /tables/:schema/:table/export and /tables/:table/export
+	exportLimiter := middleware.TableExportLimiterWithConfig(s.config.API.ExportRateLimit, s.config.API.ExportRateWindow, s.sharedMiddlewareStorage)
+	router.Get("/:schema/:table/export",
+		middleware.RequireScope(auth.ScopeTablesRead),
+		exportLimiter,
+		s.rest.HandleTableExport)
+	router.Get("/:schema/export",
+		middleware.RequireScope(auth.ScopeTablesRead),
+		exportLimiter,
+		s.rest.HandleTableExport)
+
+	// Bulk loads (COPY) and bulk updates/deletes by filter: /tables/:schema/:table/bulk and /tables/:table/bulk
+	router.Post("/:schema/:table/bulk",
+		middleware.RequireScope(auth.ScopeTablesWrite),
+		s.rest.HandleTableBulk)
+	router.Patch("/:schema/:table/bulk",
+		middleware.RequireScope(auth.ScopeTablesWrite),
+		s.rest.HandleTableBulk)
+	router.Delete("/:schema/:table/bulk",
+		middleware.RequireScope(auth.ScopeTablesWrite),
+		s.rest.HandleTableBulk)
+	router.Post("/:schema/bulk",
+		middleware.RequireScope(auth.ScopeTablesWrite),
+		s.rest.HandleTableBulk)
+	router.Patch("/:schema/bulk",
+		middleware.RequireScope(auth.ScopeTablesWrite),
+		s.rest.HandleTableBulk)
+	router.Delete("/:schema/bulk",
+		middleware.RequireScope(auth.ScopeTablesWrite),
+		s.rest.HandleTableBulk)
+
+	// Materialized view refresh: /tables/:schema/:table/refresh and /tables/:table/refresh
+	router.Post("/:schema/:table/refresh",
+		middleware.RequireScope(auth.ScopeTablesWrite),
+		s.rest.HandleRefreshMaterializedView)
+	router.Post("/:schema/refresh",
+		middleware.RequireScope(auth.ScopeTablesWrite),
+		s.rest.HandleRefreshMaterializedView)
+
 	// Routes with ID parameter: /tables/:schema/:table/:id and /tables/:table/:id
 	// These handle GET (fetch one), PUT (replace), PATCH (update), DELETE (remove)
 	router.Get("/:schema/:table/:id",
@@ -2045,6 +2573,21 @@ func (s *Server) setupAuthRoutes(router fiber.Router) {
 	// Pass the router (which is /api/v1/auth) instead of the whole app
 	s.authHandler.RegisterRoutes(router, rateLimiters)
 
+	// Organization routes, access within an organization is decided by the member's role
+	orgAuth := AuthMiddleware(s.authHandler.authService)
+	router.Get("/orgs", orgAuth, middleware.RequireScope(auth.ScopeAuthRead), s.organizationHandler.ListOrganizations)
+	router.Post("/orgs", orgAuth, middleware.RequireScope(auth.ScopeAuthWrite), s.organizationHandler.CreateOrganization)
+	router.Post("/orgs/invitations/accept", orgAuth, middleware.RequireScope(auth.ScopeAuthWrite), s.organizationHandler.AcceptInvitation)
+	router.Get("/orgs/:id", orgAuth, middleware.RequireScope(auth.ScopeAuthRead), s.organizationHandler.GetOrganization)
+	router.Patch("/orgs/:id", orgAuth, middleware.RequireScope(auth.ScopeAuthWrite), s.organizationHandler.UpdateOrganization)
+	router.Delete("/orgs/:id", orgAuth, middleware.RequireScope(auth.ScopeAuthWrite), s.organizationHandler.DeleteOrganization)
+	router.Get("/orgs/:id/members", orgAuth, middleware.RequireScope(auth.ScopeAuthRead), s.organizationHandler.ListMembers)
+	router.Patch("/orgs/:id/members/:user_id", orgAuth, middleware.RequireScope(auth.ScopeAuthWrite), s.organizationHandler.UpdateMember)
+	router.Delete("/orgs/:id/members/:user_id", orgAuth, middleware.RequireScope(auth.ScopeAuthWrite), s.organizationHandler.RemoveMember)
+	router.Get("/orgs/:id/invitations", orgAuth, middleware.RequireScope(auth.ScopeAuthRead), s.organizationHandler.ListInvitations)
+	router.Post("/orgs/:id/invitations", orgAuth, middleware.RequireScope(auth.ScopeAuthWrite), s.organizationHandler.CreateInvitation)
+	router.Delete("/orgs/:id/invitations/:invitation_id", orgAuth, middleware.RequireScope(auth.ScopeAuthWrite), s.organizationHandler.RevokeInvitation)
+
 	// OAuth routes
 	router.Get("/oauth/providers", s.oauthHandler.ListEnabledProviders)
 	router.Get("/oauth/:provider/authorize", s.oauthHandler.Authorize)
@@ -2068,23 +2611,48 @@ func (s *Server) setupAuthRoutes(router fiber.Router) {
 
 // setupStorageRoutes sets up storage routes
 func (s *Server) setupStorageRoutes(router fiber.Router) {
-	// Signed URL download (PUBLIC - no auth required, token provides authorization)
+	// Signed URL download and upload (PUBLIC - no auth required, token provides authorization)
 	router.Get("/object", s.storageHandler.DownloadSignedObject)
+	router.Put("/object", middleware.StorageUploadLimiter(s.sharedMiddlewareStorage), s.storageHandler.RequireUploadToken, s.quotaMiddleware(quota.MetricStorageBytes, middleware.UploadSizeCost), s.storageHandler.UploadSignedObject)
 
 	// Transform config (PUBLIC - no auth required, just returns config info)
 	router.Get("/config/transforms", s.storageHandler.GetTransformConfig)
 
+	// S3-compatible API (path-style, must come before /:bucket)
+	// Requests are signed with SigV4 credentials derived from a client key
+	router.Get("/s3-credentials", s.storageHandler.GetS3Credentials)
+	s3 := router.Group("/s3", s.storageHandler.S3Auth, s3Unsupported)
+	s3.Get("/", s3RequireScope(auth.ScopeStorageRead), s.storageHandler.S3ListBuckets)
+	s3.Head("/:bucket", s3RequireScope(auth.ScopeStorageRead), s.storageHandler.S3HeadBucket)
+	s3.Get("/:bucket", s3RequireScope(auth.ScopeStorageRead), s.storageHandler.S3ListObjects)
+	s3.Get("/:bucket/*", s3RequireScope(auth.ScopeStorageRead), s.storageHandler.S3GetObject)
+	s3.Head("/:bucket/*", s3RequireScope(auth.ScopeStorageRead), s.storageHandler.S3HeadObject)
+	s3.Put("/:bucket/*", middleware.StorageUploadLimiter(s.sharedMiddlewareStorage), s3RequireScope(auth.ScopeStorageWrite), s.quotaMiddleware(quota.MetricStorageBytes, middleware.UploadSizeCost), s.storageHandler.S3PutObject)
+	s3.Delete("/:bucket/*", s3RequireScope(auth.ScopeStorageWrite), s.storageHandler.S3DeleteObject)
+
 	// Bucket management with scope enforcement
 	router.Get("/buckets", middleware.RequireScope(auth.ScopeStorageRead), s.storageHandler.ListBuckets)
 	router.Post("/buckets/:bucket", middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.CreateBucket)
 	router.Put("/buckets/:bucket", middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.UpdateBucketSettings)
 	router.Delete("/buckets/:bucket", middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.DeleteBucket)
+	router.Get("/buckets/:bucket/cdn", middleware.RequireScope(auth.ScopeStorageRead), s.storageHandler.GetBucketCDN)
+	router.Put("/buckets/:bucket/cdn", middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.PutBucketCDN)
+	router.Delete("/buckets/:bucket/cdn", middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.DeleteBucketCDN)
+	router.Post("/buckets/:bucket/cdn/purge", middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.PurgeBucketCDN)
+	router.Get("/buckets/:bucket/triggers", middleware.RequireScope(auth.ScopeStorageRead), s.storageHandler.ListBucketTriggers)
+	router.Post("/buckets/:bucket/triggers", middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.CreateBucketTrigger)
+	router.Delete("/buckets/:bucket/triggers/:id", middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.DeleteBucketTrigger)
+	router.Get("/buckets/:bucket/lifecycle", middleware.RequireScope(auth.ScopeStorageRead), s.storageHandler.ListBucketLifecycle)
+	router.Post("/buckets/:bucket/lifecycle", middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.CreateBucketLifecycleRule)
+	router.Put("/buckets/:bucket/lifecycle/:id", middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.UpdateBucketLifecycleRule)
+	router.Delete("/buckets/:bucket/lifecycle/:id", middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.DeleteBucketLifecycleRule)
+	router.Post("/buckets/:bucket/lifecycle/:id/dry-run", middleware.RequireScope(auth.ScopeStorageRead), s.storageHandler.DryRunBucketLifecycleRule)
 
 	// List files in bucket (must come before /:bucket/*)
 	router.Get("/:bucket", middleware.RequireScope(auth.ScopeStorageRead), s.storageHandler.ListFiles)
 
 	// Multipart upload (must come before /:bucket/*)
-	router.Post("/:bucket/multipart", middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.MultipartUpload)
+	router.Post("/:bucket/multipart", middleware.RequireScope(auth.ScopeStorageWrite), s.quotaMiddleware(quota.MetricStorageBytes, middleware.UploadSizeCost), s.storageHandler.MultipartUpload)
 
 	// File sharing (must come before /:bucket/* to avoid matching generic routes)
 	router.Post("/:bucket/*/share", middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.ShareObject)            // Share file with user
@@ -2096,18 +2664,29 @@ func (s *Server) setupStorageRoutes(router fiber.Router) {
 
 	// Streaming upload (must come before /:bucket/*)
 	// Apply storage upload rate limiting before authentication to prevent abuse
-	router.Post("/:bucket/stream/*", middleware.StorageUploadLimiter(s.sharedMiddlewareStorage), middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.StreamUpload)
+	router.Post("/:bucket/stream/*", middleware.StorageUploadLimiter(s.sharedMiddlewareStorage), middleware.RequireScope(auth.ScopeStorageWrite), s.quotaMiddleware(quota.MetricStorageBytes, middleware.UploadSizeCost), s.storageHandler.StreamUpload)
+
+	// Resumable uploads through the tus protocol (must come before /:bucket/*)
+	if s.storageHandler.tus != nil {
+		router.Options("/:bucket/tus", s.storageHandler.TUSOptions)
+		router.Post("/:bucket/tus", middleware.StorageUploadLimiter(s.sharedMiddlewareStorage), middleware.RequireScope(auth.ScopeStorageWrite), s.quotaMiddleware(quota.MetricStorageBytes, middleware.UploadSizeCost), s.storageHandler.CreateTUSUpload)
+		router.Head("/:bucket/tus/:uploadId", middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.GetTUSUploadOffset)
+		router.Patch("/:bucket/tus/:uploadId", middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.PatchTUSUpload)
+		router.Delete("/:bucket/tus/:uploadId", middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.TerminateTUSUpload)
+	}
 
 	// Chunked upload routes (for resumable large file uploads, must come before /:bucket/*)
 	// Apply storage upload rate limiting to chunked upload init
-	router.Post("/:bucket/chunked/init", middleware.StorageUploadLimiter(s.sharedMiddlewareStorage), middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.InitChunkedUpload)
-	router.Put("/:bucket/chunked/:uploadId/:chunkIndex", middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.UploadChunk)
+	// Storage quotas are checked on init and each chunk counts as it is uploaded
+	router.Post("/:bucket/chunked/init", middleware.StorageUploadLimiter(s.sharedMiddlewareStorage), middleware.RequireScope(auth.ScopeStorageWrite), s.quotaMiddleware(quota.MetricStorageBytes, nil), s.storageHandler.InitChunkedUpload)
+	router.Put("/:bucket/chunked/:uploadId/:chunkIndex", middleware.RequireScope(auth.ScopeStorageWrite), s.quotaMiddleware(quota.MetricStorageBytes, middleware.UploadSizeCost), s.storageHandler.UploadChunk)
 	router.Post("/:bucket/chunked/:uploadId/complete", middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.CompleteChunkedUpload)
 	router.Get("/:bucket/chunked/:uploadId/status", middleware.RequireScope(auth.ScopeStorageRead), s.storageHandler.GetChunkedUploadStatus)
 	router.Delete("/:bucket/chunked/:uploadId", middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.AbortChunkedUpload)
 
 	// File operations (generic wildcard routes - must come LAST)
-	router.Post("/:bucket/*", middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.UploadFile)   // Upload file
+	// Uploads count against storage quotas
+	router.Post("/:bucket/*", middleware.RequireScope(auth.ScopeStorageWrite), s.quotaMiddleware(quota.MetricStorageBytes, middleware.UploadSizeCost), s.storageHandler.UploadFile)
 	router.Get("/:bucket/*", middleware.RequireScope(auth.ScopeStorageRead), s.storageHandler.DownloadFile)   // Download file
 	router.Head("/:bucket/*", middleware.RequireScope(auth.ScopeStorageRead), s.storageHandler.DownloadFile)  // HEAD delegates to GetFileInfo for Content-Length
 	router.Delete("/:bucket/*", middleware.RequireScope(auth.ScopeStorageWrite), s.storageHandler.DeleteFile) // Delete file
@@ -2167,6 +2746,41 @@ func (s *Server) setupAdminRoutes(router fiber.Router) {
 	router.Patch("/tables/:schema/:table", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.ddlHandler.RenameTable)
 	router.Post("/tables/:schema/:table/columns", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.ddlHandler.AddColumn)
 	router.Delete("/tables/:schema/:table/columns/:column", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.ddlHandler.DropColumn)
+	router.Post("/tables/:schema/:table/indexes", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.ddlHandler.CreateIndex)
+
+	// Index advisor routes - recommend and create missing indexes
+	router.Get("/index-advisor/recommendations", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.indexAdvisorHandler.GetRecommendations)
+	router.Post("/index-advisor/apply", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.indexAdvisorHandler.ApplyRecommendation)
+	router.Delete("/index-advisor/patterns", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.indexAdvisorHandler.ResetPatterns)
+
+	// Audit log routes - sign-ins, impersonation, permission grants and admin actions
+	router.Get("/audit/events", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.auditHandler.ListEvents)
+
+	// Table size routes - row estimates, sizes, bloat and growth trends
+	router.Get("/tables/sizes", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.tableStatsHandler.GetTableSizes)
+	router.Post("/tables/sizes/snapshot", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.tableStatsHandler.TakeSnapshot)
+
+	// Table maintenance routes - autovacuum activity, manual VACUUM/ANALYZE, schedules and log partitions
+	router.Get("/maintenance/activity", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.maintenanceHandler.GetActivity)
+	router.Post("/maintenance/run", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.maintenanceHandler.RunMaintenance)
+	router.Get("/maintenance/runs", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.maintenanceHandler.ListRuns)
+	router.Get("/maintenance/schedules", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.maintenanceHandler.ListSchedules)
+	router.Post("/maintenance/schedules", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.maintenanceHandler.CreateSchedule)
+	router.Patch("/maintenance/schedules/:id", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.maintenanceHandler.UpdateSchedule)
+	router.Delete("/maintenance/schedules/:id", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.maintenanceHandler.DeleteSchedule)
+	router.Get("/maintenance/partitions", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.maintenanceHandler.ListPartitions)
+	router.Patch("/maintenance/partitions/:schema/:table", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.maintenanceHandler.UpdatePartitionPolicy)
+	router.Post("/maintenance/partitions/:schema/:table/manage", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.maintenanceHandler.ManagePartitions)
+
+	// Scheduled job routes - edge functions and SQL snippets on cron schedules, manual runs and run history
+	router.Get("/scheduled-jobs", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.scheduledJobsHandler.ListJobs)
+	router.Post("/scheduled-jobs", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.scheduledJobsHandler.CreateJob)
+	router.Get("/scheduled-jobs/runs", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.scheduledJobsHandler.ListRuns)
+	router.Get("/scheduled-jobs/:id", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.scheduledJobsHandler.GetJob)
+	router.Patch("/scheduled-jobs/:id", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.scheduledJobsHandler.UpdateJob)
+	router.Delete("/scheduled-jobs/:id", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.scheduledJobsHandler.DeleteJob)
+	router.Post("/scheduled-jobs/:id/run", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.scheduledJobsHandler.RunJob)
+	router.Get("/scheduled-jobs/:id/runs", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.scheduledJobsHandler.ListRuns)
 
 	// Realtime admin routes - manage realtime enablement for tables
 	router.Post("/realtime/tables", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.realtimeAdminHandler.HandleEnableRealtime)
@@ -2193,50 +2807,105 @@ func (s *Server) setupAdminRoutes(router fiber.Router) {
 
 	// Auth settings routes (require admin or dashboard_admin role)
 	router.Get("/auth/settings", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.oauthProviderHandler.GetAuthSettings)
-	router.Put("/auth/settings", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.oauthProviderHandler.UpdateAuthSettings)
+	router.Put("/auth/settings", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.auditLogger.Middleware(audit.ActionSettingsUpdate, "auth_settings"), s.oauthProviderHandler.UpdateAuthSettings)
 
 	// Session management routes (require admin or dashboard_admin role)
 	router.Get("/auth/sessions", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.adminSessionHandler.ListSessions)
 	router.Delete("/auth/sessions/:id", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.adminSessionHandler.RevokeSession)
 	router.Delete("/auth/sessions/user/:user_id", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.adminSessionHandler.RevokeUserSessions)
 
+	// Cluster status (which node holds each leader-elected background role)
+	router.Get("/cluster/status", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.handleClusterStatus)
+
+	// API usage analytics per client key and per user
+	router.Get("/usage", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.usageHandler.GetUsage)
+	router.Get("/usage/top", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.usageHandler.GetTopConsumers)
+	router.Get("/usage/export", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.usageHandler.ExportUsage)
+
+	// Usage quotas of users and organizations
+	router.Get("/quotas", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.usageQuotaHandler.ListQuotas)
+	router.Put("/quotas", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.usageQuotaHandler.SetQuota)
+	router.Get("/quotas/usage", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.usageQuotaHandler.GetSubjectUsage)
+	router.Delete("/quotas/:id", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.usageQuotaHandler.DeleteQuota)
+
+	// Billable usage events and metering export history
+	router.Get("/metering/events", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.meteringHandler.GetEvents)
+	router.Get("/metering/exports", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.meteringHandler.ListExports)
+	router.Post("/metering/exports", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.meteringHandler.ExportPeriod)
+
+	// Analytics warehouse export progress
+	router.Get("/analytics/sources", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.analyticsHandler.ListSources)
+	router.Get("/analytics/exports", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.analyticsHandler.ListExports)
+	router.Post("/analytics/exports", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.analyticsHandler.Export)
+
+	// Instance-to-instance table sync: targets this instance pushes to, and rows pushed to it
+	router.Get("/sync/targets", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.tableSyncHandler.ListTargets)
+	router.Post("/sync/targets", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.tableSyncHandler.CreateTarget)
+	router.Get("/sync/targets/:id", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.tableSyncHandler.GetTarget)
+	router.Put("/sync/targets/:id", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.tableSyncHandler.UpdateTarget)
+	router.Delete("/sync/targets/:id", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.tableSyncHandler.DeleteTarget)
+	router.Post("/sync/targets/:id/pause", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.tableSyncHandler.PauseTarget)
+	router.Post("/sync/targets/:id/resume", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.tableSyncHandler.ResumeTarget)
+	router.Post("/sync/ingest", unifiedAuth, RequireRole("service_role"), s.tableSyncHandler.Ingest)
+
+	// Anonymized views of sensitive tables for analysts
+	router.Get("/anonymized-views", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.anonymizedViewsHandler.ListViews)
+	router.Post("/anonymized-views", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.anonymizedViewsHandler.CreateView)
+	router.Get("/anonymized-views/:id", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.anonymizedViewsHandler.GetView)
+	router.Put("/anonymized-views/:id", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.anonymizedViewsHandler.UpdateView)
+	router.Delete("/anonymized-views/:id", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.anonymizedViewsHandler.DeleteView)
+
+	// Full text search configuration of tables
+	router.Get("/text-search", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.textSearchHandler.ListConfigs)
+	router.Post("/text-search", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.textSearchHandler.CreateConfig)
+	router.Get("/text-search/:id", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.textSearchHandler.GetConfig)
+	router.Put("/text-search/:id", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.textSearchHandler.UpdateConfig)
+	router.Delete("/text-search/:id", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.textSearchHandler.DeleteConfig)
+
+	// Startup preflight checks
+	router.Get("/preflight", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.preflightHandler.GetReport)
+
+	// Notification center (expiring credentials and configuration drift)
+	router.Get("/notifications", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.notificationsHandler.ListNotifications)
+	router.Post("/notifications/:id/dismiss", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.notificationsHandler.DismissNotification)
+
 	// System settings routes (require admin or dashboard_admin role)
 	router.Get("/system/settings", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.systemSettingsHandler.ListSettings)
 	router.Get("/system/settings/*", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.systemSettingsHandler.GetSetting)
-	router.Put("/system/settings/*", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.systemSettingsHandler.UpdateSetting)
-	router.Delete("/system/settings/*", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.systemSettingsHandler.DeleteSetting)
+	router.Put("/system/settings/*", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.auditLogger.Middleware(audit.ActionSettingsUpdate, "system_setting", "*"), s.systemSettingsHandler.UpdateSetting)
+	router.Delete("/system/settings/*", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.auditLogger.Middleware(audit.ActionSettingsDelete, "system_setting", "*"), s.systemSettingsHandler.DeleteSetting)
 
 	// Custom settings routes (require admin, dashboard_admin, or service_role)
-	router.Post("/settings/custom", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.customSettingsHandler.CreateSetting)
+	router.Post("/settings/custom", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.auditLogger.Middleware(audit.ActionSettingsUpdate, "custom_setting"), s.customSettingsHandler.CreateSetting)
 	router.Get("/settings/custom", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.customSettingsHandler.ListSettings)
 
 	// System secret settings routes (must come before wildcard routes)
-	router.Post("/settings/custom/secret", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.customSettingsHandler.CreateSecretSetting)
+	router.Post("/settings/custom/secret", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.auditLogger.Middleware(audit.ActionSettingsUpdate, "custom_secret"), s.customSettingsHandler.CreateSecretSetting)
 	router.Get("/settings/custom/secrets", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.customSettingsHandler.ListSecretSettings)
 	router.Get("/settings/custom/secret/*", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.customSettingsHandler.GetSecretSetting)
-	router.Put("/settings/custom/secret/*", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.customSettingsHandler.UpdateSecretSetting)
-	router.Delete("/settings/custom/secret/*", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.customSettingsHandler.DeleteSecretSetting)
+	router.Put("/settings/custom/secret/*", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.auditLogger.Middleware(audit.ActionSettingsUpdate, "custom_secret", "*"), s.customSettingsHandler.UpdateSecretSetting)
+	router.Delete("/settings/custom/secret/*", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.auditLogger.Middleware(audit.ActionSettingsDelete, "custom_secret", "*"), s.customSettingsHandler.DeleteSecretSetting)
 
 	// User secret decryption route (service_role only - used by edge functions to decrypt user secrets)
 	router.Get("/settings/user/:user_id/secret/:key/decrypt", unifiedAuth, RequireRole("service_role"), s.userSettingsHandler.GetUserSecretValue)
 
 	// Regular custom settings wildcard routes
 	router.Get("/settings/custom/*", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.customSettingsHandler.GetSetting)
-	router.Put("/settings/custom/*", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.customSettingsHandler.UpdateSetting)
-	router.Delete("/settings/custom/*", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.customSettingsHandler.DeleteSetting)
+	router.Put("/settings/custom/*", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.auditLogger.Middleware(audit.ActionSettingsUpdate, "custom_setting", "*"), s.customSettingsHandler.UpdateSetting)
+	router.Delete("/settings/custom/*", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.auditLogger.Middleware(audit.ActionSettingsDelete, "custom_setting", "*"), s.customSettingsHandler.DeleteSetting)
 
 	// App settings routes (require admin or dashboard_admin role)
 	router.Get("/app/settings", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.appSettingsHandler.GetAppSettings)
-	router.Put("/app/settings", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.appSettingsHandler.UpdateAppSettings)
+	router.Put("/app/settings", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.auditLogger.Middleware(audit.ActionSettingsUpdate, "app_settings"), s.appSettingsHandler.UpdateAppSettings)
 
 	// Email settings routes (require admin or dashboard_admin role)
 	router.Get("/email/settings", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.emailSettingsHandler.GetSettings)
-	router.Put("/email/settings", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.emailSettingsHandler.UpdateSettings)
+	router.Put("/email/settings", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.auditLogger.Middleware(audit.ActionSettingsUpdate, "email_settings"), s.emailSettingsHandler.UpdateSettings)
 	router.Post("/email/settings/test", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.emailSettingsHandler.TestSettings)
 
 	// Captcha settings routes (require admin or dashboard_admin role)
 	router.Get("/settings/captcha", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.captchaSettingsHandler.GetSettings)
-	router.Put("/settings/captcha", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.captchaSettingsHandler.UpdateSettings)
+	router.Put("/settings/captcha", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.auditLogger.Middleware(audit.ActionSettingsUpdate, "captcha_settings"), s.captchaSettingsHandler.UpdateSettings)
 
 	// Email template routes (require admin or dashboard_admin role)
 	router.Get("/email/templates", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.emailTemplateHandler.ListTemplates)
@@ -2244,14 +2913,43 @@ func (s *Server) setupAdminRoutes(router fiber.Router) {
 	router.Put("/email/templates/:type", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.emailTemplateHandler.UpdateTemplate)
 	router.Post("/email/templates/:type/reset", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.emailTemplateHandler.ResetTemplate)
 	router.Post("/email/templates/:type/test", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.emailTemplateHandler.TestTemplate)
+	router.Post("/email/templates/:type/preview", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.emailTemplateHandler.PreviewTemplate)
+	router.Get("/email/templates/:type/versions", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.emailTemplateHandler.ListTemplateVersions)
+	router.Post("/email/templates/:type/versions/:version/restore", unifiedAuth, RequireRole("admin", "dashboard_admin"), s.emailTemplateHandler.RestoreTemplateVersion)
 
 	// User management routes (require admin, dashboard_admin, or service_role)
 	router.Get("/users", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.userManagementHandler.ListUsers)
+	router.Get("/users/duplicate-emails", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.userManagementHandler.ListDuplicateEmails)
 	router.Post("/users/invite", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.userManagementHandler.InviteUser)
+	router.Post("/users/import", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.userManagementHandler.ImportUsers)
 	router.Delete("/users/:id", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.userManagementHandler.DeleteUser)
 	router.Patch("/users/:id", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.userManagementHandler.UpdateUser)
 	router.Patch("/users/:id/role", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.userManagementHandler.UpdateUserRole)
 	router.Post("/users/:id/reset-password", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.userManagementHandler.ResetUserPassword)
+	router.Get("/users/:id", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.userManagementHandler.GetUserByID)
+	router.Post("/users/:id/force-password-reset", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.userManagementHandler.ForcePasswordReset)
+	router.Post("/users/:id/verify-email", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.userManagementHandler.VerifyEmail)
+	router.Post("/users/:id/ban", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.userManagementHandler.BanUser)
+	router.Post("/users/:id/unban", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.userManagementHandler.UnbanUser)
+	router.Get("/users/:id/groups", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.groupHandler.ListUserGroups)
+
+	// User group routes (require admin, dashboard_admin, or service_role)
+	router.Get("/groups", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.groupHandler.ListGroups)
+	router.Post("/groups", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.groupHandler.CreateGroup)
+	router.Get("/groups/:id", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.groupHandler.GetGroup)
+	router.Patch("/groups/:id", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.groupHandler.UpdateGroup)
+	router.Delete("/groups/:id", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.groupHandler.DeleteGroup)
+	router.Get("/groups/:id/members", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.groupHandler.ListGroupMembers)
+	router.Post("/groups/:id/members", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.groupHandler.AddGroupMembers)
+	router.Delete("/groups/:id/members/:user_id", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.groupHandler.RemoveGroupMember)
+
+	// SCIM provisioning token routes (require admin, dashboard_admin, or service_role)
+	router.Get("/scim/tokens", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.scimHandler.ListSCIMTokens)
+	router.Post("/scim/tokens", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.scimHandler.CreateSCIMToken)
+	router.Delete("/scim/tokens/:id", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.scimHandler.RevokeSCIMToken)
+
+	// Secret scanning event routes (require admin, dashboard_admin, or service_role)
+	router.Get("/secret-scanning/events", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.secretScanHandler.ListSecretScanEvents)
 
 	// Quota management routes (require admin, dashboard_admin, or service_role)
 	if s.quotaHandler != nil {
@@ -2333,9 +3031,33 @@ func (s *Server) setupAdminRoutes(router fiber.Router) {
 
 		// Conversations & Audit
 		router.Get("/ai/conversations", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.aiHandler.GetConversations)
+		router.Get("/ai/conversations/search", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.aiHandler.SearchConversations)
+		router.Get("/ai/conversations/breakdown", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.aiHandler.GetConversationBreakdown)
+		router.Patch("/ai/conversations/:id", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.aiHandler.UpdateConversationAttributes)
 		router.Get("/ai/conversations/:id/messages", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.aiHandler.GetConversationMessages)
 		router.Get("/ai/audit", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.aiHandler.GetAuditLog)
 
+		// Human escalations (support tooling)
+		router.Get("/ai/escalations", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.aiHandler.ListEscalations)
+		router.Get("/ai/escalations/:id", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.aiHandler.GetEscalation)
+		router.Patch("/ai/escalations/:id", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.aiHandler.UpdateEscalation)
+
+		// Embeddings API usage (cost tracking)
+		if s.vectorHandler != nil {
+			router.Get("/ai/embeddings/usage", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.vectorHandler.HandleEmbeddingUsage)
+		}
+
+		// Embeddable chat widgets
+		if s.aiWidgetHandler != nil {
+			router.Get("/ai/widgets", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.aiWidgetHandler.ListWidgets)
+			router.Post("/ai/widgets", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.aiWidgetHandler.CreateWidget)
+			router.Get("/ai/widgets/:id", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.aiWidgetHandler.GetWidget)
+			router.Put("/ai/widgets/:id", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.aiWidgetHandler.UpdateWidget)
+			router.Delete("/ai/widgets/:id", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.aiWidgetHandler.DeleteWidget)
+			router.Post("/ai/widgets/:id/rotate-key", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.aiWidgetHandler.RotateWidgetKey)
+			router.Get("/ai/widgets/:id/sessions", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.aiWidgetHandler.ListWidgetSessions)
+		}
+
 		// Provider management
 		router.Get("/ai/providers", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.aiHandler.ListProviders)
 		router.Get("/ai/providers/:id", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.aiHandler.GetProvider)
@@ -2353,7 +3075,7 @@ func (s *Server) setupAdminRoutes(router fiber.Router) {
 			router.Get("/ai/knowledge-bases/:id", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.GetKnowledgeBase)
 			router.Post("/ai/knowledge-bases", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.CreateKnowledgeBase)
 			router.Put("/ai/knowledge-bases/:id", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.UpdateKnowledgeBase)
-			router.Delete("/ai/knowledge-bases/:id", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.DeleteKnowledgeBase)
+			router.Delete("/ai/knowledge-bases/:id", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.auditLogger.Middleware(audit.ActionKnowledgeBaseDelete, "knowledge_base", "id"), s.knowledgeBaseHandler.DeleteKnowledgeBase)
 
 			// Documents within a knowledge base
 			router.Get("/ai/knowledge-bases/:id/documents", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.ListDocuments)
@@ -2365,13 +3087,20 @@ func (s *Server) setupAdminRoutes(router fiber.Router) {
 			router.Post("/ai/knowledge-bases/:id/documents/delete-by-filter", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.DeleteDocumentsByFilter)
 
 			// Document permissions
-			router.Post("/ai/knowledge-bases/:id/documents/:doc_id/permissions", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.GrantDocumentPermission)
+			router.Post("/ai/knowledge-bases/:id/documents/:doc_id/permissions", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.auditLogger.Middleware(audit.ActionPermissionGrant, "document", "id", "doc_id"), s.knowledgeBaseHandler.GrantDocumentPermission)
 			router.Get("/ai/knowledge-bases/:id/documents/:doc_id/permissions", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.ListDocumentPermissions)
-			router.Delete("/ai/knowledge-bases/:id/documents/:doc_id/permissions/:user_id", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.RevokeDocumentPermission)
+			router.Delete("/ai/knowledge-bases/:id/documents/:doc_id/permissions/:user_id", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.auditLogger.Middleware(audit.ActionPermissionRevoke, "document", "id", "doc_id"), s.knowledgeBaseHandler.RevokeDocumentPermission)
+			router.Delete("/ai/knowledge-bases/:id/documents/:doc_id/permissions/groups/:group_id", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.auditLogger.Middleware(audit.ActionPermissionRevoke, "document", "id", "doc_id"), s.knowledgeBaseHandler.RevokeDocumentGroupPermission)
 
 			// Search/test endpoint
 			router.Post("/ai/knowledge-bases/:id/search", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.SearchKnowledgeBase)
+			router.Post("/ai/knowledge-bases/:id/search/debug", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.ExplainSearch)
 			router.Post("/ai/knowledge-bases/:id/debug-search", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.DebugSearch)
+			router.Post("/ai/knowledge-bases/:id/embedding-comparison", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.CompareEmbeddingModels)
+
+			// Re-index after chunking or embedding configuration changes
+			router.Post("/ai/knowledge-bases/:id/reindex", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.ReindexKnowledgeBase)
+			router.Get("/ai/knowledge-bases/:id/reindex", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.GetKBReindexStatus)
 
 			// Chatbot knowledge base linking
 			router.Get("/ai/chatbots/:id/knowledge-bases", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.ListChatbotKnowledgeBases)
@@ -2379,6 +3108,12 @@ func (s *Server) setupAdminRoutes(router fiber.Router) {
 			router.Put("/ai/chatbots/:id/knowledge-bases/:kb_id", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.UpdateChatbotKnowledgeBase)
 			router.Delete("/ai/chatbots/:id/knowledge-bases/:kb_id", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.UnlinkKnowledgeBase)
 
+			// Shadow retrieval profiles
+			router.Get("/ai/chatbots/:id/shadow-retrieval", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.GetShadowRetrievalProfile)
+			router.Put("/ai/chatbots/:id/shadow-retrieval", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.SetShadowRetrievalProfile)
+			router.Delete("/ai/chatbots/:id/shadow-retrieval", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.DeleteShadowRetrievalProfile)
+			router.Get("/ai/chatbots/:id/shadow-retrieval/runs", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.ListShadowRetrievalRuns)
+
 			// Table export routes
 			router.Post("/ai/knowledge-bases/:id/tables/export", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.ExportTableToKnowledgeBase)
 			router.Get("/ai/tables", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.ListExportableTables)
@@ -2391,6 +3126,17 @@ func (s *Server) setupAdminRoutes(router fiber.Router) {
 			router.Delete("/ai/knowledge-bases/:id/sync-configs/:syncId", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.DeleteTableExportSync)
 			router.Post("/ai/knowledge-bases/:id/sync-configs/:syncId/trigger", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.TriggerTableExportSync)
 
+			// Knowledge base snapshots and rollback
+			router.Get("/ai/knowledge-bases/:id/snapshots", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.ListKBSnapshots)
+			router.Post("/ai/knowledge-bases/:id/snapshots", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.CreateKBSnapshot)
+			router.Get("/ai/knowledge-bases/:id/snapshots/:snapshot_id", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.GetKBSnapshot)
+			router.Get("/ai/knowledge-bases/:id/snapshots/:snapshot_id/export", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.ExportKBSnapshot)
+			router.Delete("/ai/knowledge-bases/:id/snapshots/:snapshot_id", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.DeleteKBSnapshot)
+			router.Post("/ai/knowledge-bases/:id/snapshots/:snapshot_id/rollback", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.RollbackKBSnapshot)
+			router.Get("/ai/knowledge-bases/:id/snapshot-schedule", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.GetKBSnapshotSchedule)
+			router.Put("/ai/knowledge-bases/:id/snapshot-schedule", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.UpdateKBSnapshotSchedule)
+			router.Delete("/ai/knowledge-bases/:id/snapshot-schedule", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.DeleteKBSnapshotSchedule)
+
 			// Knowledge base chatbots (reverse lookup - which chatbots use this KB)
 			router.Get("/ai/knowledge-bases/:id/chatbots", requireAI, unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.knowledgeBaseHandler.ListKnowledgeBaseChatbots)
 
@@ -2435,15 +3181,19 @@ func (s *Server) setupAdminRoutes(router fiber.Router) {
 
 	// RLS Policy management routes
 	router.Get("/policies", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.ListPolicies)
-	router.Post("/policies", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.CreatePolicy)
-	router.Put("/policies/:schema/:table/:policy", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.UpdatePolicy)
-	router.Delete("/policies/:schema/:table/:policy", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.DeletePolicy)
+	router.Post("/policies", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.auditLogger.Middleware(audit.ActionRLSPolicyCreate, "rls_policy"), s.CreatePolicy)
+	router.Put("/policies/:schema/:table/:policy", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.auditLogger.Middleware(audit.ActionRLSPolicyUpdate, "rls_policy", "schema", "table", "policy"), s.UpdatePolicy)
+	router.Delete("/policies/:schema/:table/:policy", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.auditLogger.Middleware(audit.ActionRLSPolicyDelete, "rls_policy", "schema", "table", "policy"), s.DeletePolicy)
 	router.Get("/policies/templates", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.GetPolicyTemplates)
 
 	// Table RLS status routes
 	router.Get("/tables/rls", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.GetTablesWithRLS)
 	router.Get("/tables/:schema/:table/rls", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.GetTableRLSStatus)
-	router.Post("/tables/:schema/:table/rls/toggle", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.ToggleTableRLS)
+	router.Post("/tables/:schema/:table/rls/toggle", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.auditLogger.Middleware(audit.ActionRLSToggle, "table", "schema", "table"), s.ToggleTableRLS)
+
+	// Table data diff routes (compare rows between environments)
+	router.Get("/tables/:schema/:table/fingerprint", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.tableDiffHandler.GetFingerprint)
+	router.Post("/tables/:schema/:table/diff", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.tableDiffHandler.DiffTable)
 
 	// Security warnings/advisor
 	router.Get("/security/warnings", unifiedAuth, RequireRole("admin", "dashboard_admin", "service_role"), s.GetSecurityWarnings)
@@ -2599,17 +3349,13 @@ func (s *Server) handleGetTableSchema(c fiber.Ctx) error {
 	table := c.Params("table")
 
 	if schema == "" || table == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Schema and table parameters are required",
-		})
+		return SendBadRequest(c, "Schema and table parameters are required", ErrCodeMissingField)
 	}
 
 	// Get table information including column details
 	tableInfo, err := s.db.Inspector().GetTableInfo(ctx, schema, table)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": fmt.Sprintf("Table not found: %s.%s", schema, table),
-		})
+		return SendNotFound(c, fmt.Sprintf("Table not found: %s.%s", schema, table))
 	}
 
 	return c.JSON(tableInfo)
@@ -2668,18 +3414,13 @@ func (s *Server) handleRefreshSchema(c fiber.Ctx) error {
 	// Get the schema cache from the REST handler
 	schemaCache := s.rest.SchemaCache()
 	if schemaCache == nil {
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Schema cache not initialized",
-		})
+		return SendInternalError(c, "Schema cache not initialized")
 	}
 
 	// Force refresh the schema cache
 	if err := schemaCache.Refresh(c.RequestCtx()); err != nil {
 		log.Error().Err(err).Msg("Failed to refresh schema cache")
-		return c.Status(500).JSON(fiber.Map{
-			"error":   "Failed to refresh schema cache",
-			"details": err.Error(),
-		})
+		return SendErrorWithDetails(c, 500, "Failed to refresh schema cache", ErrCodeOperationFailed, "", "", err.Error())
 	}
 
 	log.Info().
@@ -2694,6 +3435,48 @@ func (s *Server) handleRefreshSchema(c fiber.Ctx) error {
 	})
 }
 
+// startLeaderElected runs a background service. With scheduler leader election enabled,
+// start and stop are tied to holding the role's advisory lock so that only one instance
+// in the cluster runs the service at a time; otherwise the service starts immediately.
+func (s *Server) startLeaderElected(cfg config.ScalingConfig, lockID int64, role string, start, stop func()) {
+	if !cfg.EnableSchedulerLeaderElection {
+		start()
+		return
+	}
+
+	le := scaling.NewLeaderElector(s.db.Pool(), lockID, role)
+	le.Start(
+		func() {
+			log.Info().Str("role", role).Msg("This instance is now the leader - starting service")
+			start()
+		},
+		func() {
+			log.Warn().Str("role", role).Msg("Lost leadership - stopping service")
+			stop()
+		},
+	)
+	s.leaderElectors = append(s.leaderElectors, le)
+}
+
+// quotaMiddleware enforces the usage quotas of metric on a route, or lets requests through
+// when quotas are disabled. See middleware.RequireQuota for cost.
+func (s *Server) quotaMiddleware(metric quota.Metric, cost func(fiber.Ctx) int64) fiber.Handler {
+	if s.quotaService == nil {
+		return func(c fiber.Ctx) error { return c.Next() }
+	}
+	return middleware.RequireQuota(s.quotaService, metric, cost)
+}
+
+// handleClusterStatus reports which node holds each leader-elected background role
+func (s *Server) handleClusterStatus(c fiber.Ctx) error {
+	status, err := scaling.GetClusterStatus(c.RequestCtx(), s.db.Pool(), s.leaderElectors)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get cluster status")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to get cluster status", ErrCodeOperationFailed)
+	}
+	return c.JSON(status)
+}
+
 // Start starts the HTTP server
 func (s *Server) Start() error {
 	return s.app.Listen(s.config.Server.Address, fiber.ListenConfig{EnablePrefork: false, DisableStartupMessage: !s.config.Debug})
@@ -2702,17 +3485,8 @@ func (s *Server) Start() error {
 // Shutdown gracefully shuts down the server
 func (s *Server) Shutdown(ctx context.Context) error {
 	// Stop leader electors first (releases advisory locks)
-	if s.functionsSchedulerLeader != nil {
-		log.Info().Msg("Stopping functions scheduler leader election")
-		s.functionsSchedulerLeader.Stop()
-	}
-	if s.jobsSchedulerLeader != nil {
-		log.Info().Msg("Stopping jobs scheduler leader election")
-		s.jobsSchedulerLeader.Stop()
-	}
-	if s.rpcSchedulerLeader != nil {
-		log.Info().Msg("Stopping RPC scheduler leader election")
-		s.rpcSchedulerLeader.Stop()
+	for _, le := range s.leaderElectors {
+		le.Stop()
 	}
 
 	// Stop realtime listener (PostgreSQL LISTEN/NOTIFY)
@@ -2721,6 +3495,12 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		s.realtimeListener.Stop()
 	}
 
+	// Stop realtime logical replication listener (drops its temporary slot)
+	if s.realtimeReplication != nil {
+		log.Info().Msg("Stopping realtime replication listener")
+		s.realtimeReplication.Stop()
+	}
+
 	// Shutdown realtime manager (close all WebSocket connections)
 	if s.realtimeManager != nil {
 		log.Info().Msg("Closing WebSocket connections")
@@ -2775,6 +3555,16 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		s.branchScheduler.Stop()
 	}
 
+	// Stop knowledge base snapshot scheduler
+	if s.kbSnapshotScheduler != nil {
+		s.kbSnapshotScheduler.Stop()
+	}
+
+	// Stop conversation search indexer
+	if s.conversationIndexer != nil {
+		s.conversationIndexer.Stop()
+	}
+
 	// Close database branching components
 	if s.branchRouter != nil {
 		log.Info().Msg("Closing branch connection pools")
@@ -2804,6 +3594,113 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	// Stop API usage recorder (flushes pending rollups)
+	if s.usageRecorder != nil {
+		log.Info().Msg("Stopping API usage recorder")
+		s.usageRecorder.Stop()
+	}
+
+	// Stop usage quota service (flushes pending counters)
+	if s.quotaService != nil {
+		log.Info().Msg("Stopping usage quota service")
+		s.quotaService.Stop()
+	}
+
+	// Release REST pagination snapshots (each holds a database connection)
+	if s.rest != nil {
+		s.rest.Close()
+	}
+
+	// Stop metering exporter
+	if s.meteringExporter != nil {
+		log.Info().Msg("Stopping metering exporter")
+		s.meteringExporter.Stop()
+	}
+
+	// Stop analytics exporter
+	if s.analyticsExporter != nil {
+		log.Info().Msg("Stopping analytics exporter")
+		s.analyticsExporter.Stop()
+	}
+
+	// Stop table syncer
+	if s.tableSyncer != nil {
+		log.Info().Msg("Stopping table syncer")
+		s.tableSyncer.Stop()
+	}
+
+	// Stop data exports in progress
+	if s.dataExportService != nil {
+		log.Info().Msg("Stopping data exports")
+		s.dataExportService.Stop()
+	}
+
+	// Wait for pending CDN cache purges
+	if s.cdnService != nil {
+		s.cdnService.Stop()
+	}
+
+	// Cancel functions processing uploads
+	if s.objectTriggers != nil {
+		s.objectTriggers.Stop()
+	}
+
+	// Stop table maintenance scheduler and cancel manual runs
+	if s.maintenanceScheduler != nil {
+		log.Info().Msg("Stopping table maintenance scheduler")
+		s.maintenanceScheduler.Stop()
+	}
+	if s.maintenanceRunner != nil {
+		s.maintenanceRunner.Stop()
+	}
+
+	// Stop scheduled jobs scheduler and cancel manual runs
+	if s.scheduledJobsScheduler != nil {
+		log.Info().Msg("Stopping scheduled jobs scheduler")
+		s.scheduledJobsScheduler.Stop()
+	}
+	if s.scheduledJobsRunner != nil {
+		s.scheduledJobsRunner.Stop()
+	}
+
+	// Stop audit log pruner
+	if s.auditPruner != nil {
+		log.Info().Msg("Stopping audit log pruner")
+		s.auditPruner.Stop()
+	}
+
+	// Stop resumable upload pruner
+	if s.tusPruner != nil {
+		log.Info().Msg("Stopping resumable upload pruner")
+		s.tusPruner.Stop()
+	}
+
+	// Stop storage lifecycle sweeper
+	if s.lifecycleSweeper != nil {
+		log.Info().Msg("Stopping storage lifecycle sweeper")
+		s.lifecycleSweeper.Stop()
+	}
+
+	// Stop table size collector
+	if s.tableStatsCollector != nil {
+		log.Info().Msg("Stopping table size collector")
+		s.tableStatsCollector.Stop()
+	}
+
+	// Stop notification checker
+	if s.notificationChecker != nil {
+		log.Info().Msg("Stopping notification checker")
+		s.notificationChecker.Stop()
+	}
+
+	// Stop egress proxy
+	if s.egressProxy != nil {
+		log.Info().Msg("Stopping egress proxy")
+		if err := s.egressProxy.Shutdown(ctx); err != nil {
+			log.Error().Err(err).Msg("Error stopping egress proxy")
+		}
+	}
+
 	// Stop retention cleanup service
 	if s.retentionService != nil {
 		log.Info().Msg("Stopping log retention cleanup service")
@@ -2823,6 +3720,11 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		s.schemaCache.Close()
 	}
 
+	// Close knowledge base cache (stops invalidation listener)
+	if s.kbCache != nil {
+		s.kbCache.Close()
+	}
+
 	// Close server-owned pub/sub (releases PostgreSQL LISTEN connection)
 	if s.pubSub != nil {
 		log.Info().Msg("Closing pub/sub")
@@ -2839,6 +3741,21 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	// Close server-owned shared cache and queue
+	if s.sharedCache != nil {
+		log.Info().Msg("Closing shared cache")
+		if err := s.sharedCache.Close(); err != nil {
+			log.Warn().Err(err).Msg("Failed to close shared cache")
+		}
+	}
+	if s.jobQueue != nil {
+		log.Info().Msg("Closing queue")
+		jobs.SetWakeupQueue(nil)
+		if err := s.jobQueue.Close(); err != nil {
+			log.Warn().Err(err).Msg("Failed to close queue")
+		}
+	}
+
 	log.Info().Msg("Shutting down HTTP server")
 	return s.app.ShutdownWithContext(ctx)
 }
@@ -2848,6 +3765,11 @@ func (s *Server) App() *fiber.App {
 	return s.app
 }
 
+// SetPreflightReport stores the report of the preflight checks run on boot
+func (s *Server) SetPreflightReport(report *preflight.Report) {
+	s.preflightHandler.SetReport(report)
+}
+
 // GetStorageService returns the storage service from the storage handler
 func (s *Server) GetStorageService() *storage.Service {
 	if s.storageHandler == nil {
@@ -2908,40 +3830,13 @@ func (s *Server) LoadAIChatbotsFromFilesystem(ctx context.Context) error {
 	return s.aiHandler.AutoLoadChatbots(ctx)
 }
 
-// customErrorHandler handles errors globally
-func customErrorHandler(c fiber.Ctx, err error) error {
-	// Default to 500 status code
-	code := fiber.StatusInternalServerError
-	message := "Internal Server Error"
-
-	// Check if it's a Fiber error
-	var e *fiber.Error
-	if errors.As(err, &e) {
-		code = e.Code
-		message = e.Message
-	}
-
-	// Log error
-	if code >= 500 {
-		log.Error().Err(err).Str("path", c.Path()).Msg("Server error")
-	}
-
-	// Return JSON error response
-	return c.Status(code).JSON(fiber.Map{
-		"error": message,
-		"code":  code,
-	})
-}
-
 // handleRealtimeStats returns realtime statistics
 // Admin-only endpoint - non-admin users receive 403 Forbidden
 func (s *Server) handleRealtimeStats(c fiber.Ctx) error {
 	// Check if user has admin role
 	role, _ := c.Locals("user_role").(string)
 	if role != "admin" && role != "dashboard_admin" && role != "service_role" {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Admin access required to view realtime stats",
-		})
+		return SendForbidden(c, "Admin access required to view realtime stats", ErrCodeAdminRequired)
 	}
 
 	// Parse pagination parameters
@@ -3049,22 +3944,16 @@ type BroadcastRequest struct {
 func (s *Server) handleRealtimeBroadcast(c fiber.Ctx) error {
 	var req BroadcastRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	if req.Channel == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Channel is required",
-		})
+		return SendBadRequest(c, "Channel is required", ErrCodeMissingField)
 	}
 
 	// Get the realtime manager and broadcast to the channel
 	if s.realtimeHandler == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Realtime service not available",
-		})
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "Realtime service not available", ErrCodeServiceUnavailable)
 	}
 
 	manager := s.realtimeHandler.GetManager()
@@ -2,14 +2,13 @@ package api
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"io"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
 	"github.com/nimbleflux/fluxbase/internal/config"
 	"github.com/nimbleflux/fluxbase/internal/database"
 	"github.com/nimbleflux/fluxbase/internal/pubsub"
@@ -127,93 +126,6 @@ func TestNormalizePaginationParams_DifferentDefaults(t *testing.T) {
 	})
 }
 
-// =============================================================================
-// customErrorHandler Tests
-// =============================================================================
-
-func TestCustomErrorHandler(t *testing.T) {
-	tests := []struct {
-		name          string
-		err           error
-		expectedCode  int
-		expectedError string
-	}{
-		{
-			name:          "generic error returns 500",
-			err:           errors.New("something went wrong"),
-			expectedCode:  500,
-			expectedError: "Internal Server Error",
-		},
-		{
-			name:          "fiber 400 error",
-			err:           fiber.NewError(fiber.StatusBadRequest, "Invalid request"),
-			expectedCode:  400,
-			expectedError: "Invalid request",
-		},
-		{
-			name:          "fiber 401 error",
-			err:           fiber.NewError(fiber.StatusUnauthorized, "Unauthorized"),
-			expectedCode:  401,
-			expectedError: "Unauthorized",
-		},
-		{
-			name:          "fiber 403 error",
-			err:           fiber.NewError(fiber.StatusForbidden, "Forbidden"),
-			expectedCode:  403,
-			expectedError: "Forbidden",
-		},
-		{
-			name:          "fiber 404 error",
-			err:           fiber.NewError(fiber.StatusNotFound, "Not found"),
-			expectedCode:  404,
-			expectedError: "Not found",
-		},
-		{
-			name:          "fiber 429 error",
-			err:           fiber.NewError(fiber.StatusTooManyRequests, "Rate limit exceeded"),
-			expectedCode:  429,
-			expectedError: "Rate limit exceeded",
-		},
-		{
-			name:          "fiber 502 error",
-			err:           fiber.NewError(fiber.StatusBadGateway, "Bad gateway"),
-			expectedCode:  502,
-			expectedError: "Bad gateway",
-		},
-		{
-			name:          "fiber 503 error",
-			err:           fiber.NewError(fiber.StatusServiceUnavailable, "Service unavailable"),
-			expectedCode:  503,
-			expectedError: "Service unavailable",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			app := fiber.New(fiber.Config{ErrorHandler: customErrorHandler})
-
-			app.Get("/test", func(c fiber.Ctx) error {
-				return tt.err
-			})
-
-			req := httptest.NewRequest("GET", "/test", nil)
-			resp, err := app.Test(req)
-			require.NoError(t, err)
-			defer func() { _ = resp.Body.Close() }()
-
-			assert.Equal(t, tt.expectedCode, resp.StatusCode)
-
-			body, _ := io.ReadAll(resp.Body)
-			var result map[string]interface{}
-			err = json.Unmarshal(body, &result)
-			require.NoError(t, err)
-
-			assert.Equal(t, tt.expectedError, result["error"])
-			assert.Equal(t, float64(tt.expectedCode), result["code"])
-		})
-	}
-}
-
 // =============================================================================
 // Admin Role Checking Pattern Tests
 // =============================================================================
@@ -291,7 +203,7 @@ func TestAdminRoleCheckingInFiberContext(t *testing.T) {
 
 func TestFiberAppConfiguration(t *testing.T) {
 	t.Run("default error handler returns JSON", func(t *testing.T) {
-		app := fiber.New(fiber.Config{ErrorHandler: customErrorHandler})
+		app := fiber.New(fiber.Config{ErrorHandler: apierror.Handler})
 
 		app.Get("/error", func(c fiber.Ctx) error {
 			return errors.New("test error")
@@ -479,22 +391,6 @@ func BenchmarkAdminRoleCheck(b *testing.B) {
 	}
 }
 
-func BenchmarkCustomErrorHandler(b *testing.B) {
-	app := fiber.New(fiber.Config{ErrorHandler: customErrorHandler})
-
-	app.Get("/test", func(c fiber.Ctx) error {
-		return fiber.NewError(fiber.StatusBadRequest, "Test error")
-	})
-
-	for i := 0; i < b.N; i++ {
-		req := httptest.NewRequest("GET", "/test", nil)
-		resp, _ := app.Test(req)
-		if resp != nil {
-			_ = resp.Body.Close()
-		}
-	}
-}
-
 // =============================================================================
 // Test Server with Dependency Injection
 // =============================================================================
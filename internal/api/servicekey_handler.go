@@ -73,9 +73,7 @@ type UpdateServiceKeyRequest struct {
 func (h *ServiceKeyHandler) ListServiceKeys(c fiber.Ctx) error {
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	rows, err := h.db.Query(c.RequestCtx(), `
@@ -87,9 +85,7 @@ func (h *ServiceKeyHandler) ListServiceKeys(c fiber.Ctx) error {
 	`)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list service keys")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to list service keys",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to list service keys", ErrCodeOperationFailed)
 	}
 	defer rows.Close()
 
@@ -120,16 +116,12 @@ func (h *ServiceKeyHandler) GetServiceKey(c fiber.Ctx) error {
 	idStr := c.Params("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid service key ID",
-		})
+		return SendBadRequest(c, "Invalid service key ID", ErrCodeInvalidID)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	var key ServiceKey
@@ -145,9 +137,7 @@ func (h *ServiceKeyHandler) GetServiceKey(c fiber.Ctx) error {
 		&key.CreatedBy, &key.CreatedAt, &key.LastUsedAt, &key.ExpiresAt,
 	)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Service key not found",
-		})
+		return SendNotFound(c, "Service key not found")
 	}
 
 	return c.JSON(key)
@@ -157,30 +147,22 @@ func (h *ServiceKeyHandler) GetServiceKey(c fiber.Ctx) error {
 func (h *ServiceKeyHandler) CreateServiceKey(c fiber.Ctx) error {
 	var req CreateServiceKeyRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	if req.Name == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Name is required",
-		})
+		return SendBadRequest(c, "Name is required", ErrCodeMissingField)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	// Generate a secure random key
 	keyBytes := make([]byte, 32)
 	if _, err := rand.Read(keyBytes); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to generate key",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to generate key", ErrCodeOperationFailed)
 	}
 	randomPart := base64.RawURLEncoding.EncodeToString(keyBytes)
 	plainKey := fmt.Sprintf("sk_%s", randomPart)
@@ -189,9 +171,7 @@ func (h *ServiceKeyHandler) CreateServiceKey(c fiber.Ctx) error {
 	// Hash the key
 	keyHash, err := bcrypt.GenerateFromPassword([]byte(plainKey), bcrypt.DefaultCost)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to hash key",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to hash key", ErrCodeOperationFailed)
 	}
 
 	// Default scopes if not provided
@@ -202,9 +182,7 @@ func (h *ServiceKeyHandler) CreateServiceKey(c fiber.Ctx) error {
 
 	// Validate scopes
 	if err := auth.ValidateScopes(scopes); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": fmt.Sprintf("Invalid scopes: %v", err),
-		})
+		return SendBadRequest(c, fmt.Sprintf("Invalid scopes: %v", err), ErrCodeInvalidInput)
 	}
 
 	// Get creator user ID if available
@@ -233,9 +211,7 @@ func (h *ServiceKeyHandler) CreateServiceKey(c fiber.Ctx) error {
 	)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to create service key")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to create service key: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to create service key: %v", err), ErrCodeOperationFailed)
 	}
 
 	// Return the key with the plaintext (only time it's shown)
@@ -258,24 +234,18 @@ func (h *ServiceKeyHandler) UpdateServiceKey(c fiber.Ctx) error {
 	idStr := c.Params("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid service key ID",
-		})
+		return SendBadRequest(c, "Invalid service key ID", ErrCodeInvalidID)
 	}
 
 	var req UpdateServiceKeyRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate scopes if provided
 	if len(req.Scopes) > 0 {
 		if err := auth.ValidateScopes(req.Scopes); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": fmt.Sprintf("Invalid scopes: %v", err),
-			})
+			return SendBadRequest(c, fmt.Sprintf("Invalid scopes: %v", err), ErrCodeInvalidInput)
 		}
 	}
 
@@ -301,16 +271,12 @@ func (h *ServiceKeyHandler) UpdateServiceKey(c fiber.Ctx) error {
 	}
 
 	if len(updates) == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "No fields to update",
-		})
+		return SendBadRequest(c, "No fields to update", ErrCodeInvalidInput)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	// Build SET clause dynamically
@@ -336,15 +302,11 @@ func (h *ServiceKeyHandler) UpdateServiceKey(c fiber.Ctx) error {
 	result, err := h.db.Exec(c.RequestCtx(), query, args...)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to update service key")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update service key",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to update service key", ErrCodeOperationFailed)
 	}
 
 	if result.RowsAffected() == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Service key not found",
-		})
+		return SendNotFound(c, "Service key not found")
 	}
 
 	log.Info().
@@ -362,16 +324,12 @@ func (h *ServiceKeyHandler) DeleteServiceKey(c fiber.Ctx) error {
 	idStr := c.Params("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid service key ID",
-		})
+		return SendBadRequest(c, "Invalid service key ID", ErrCodeInvalidID)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	result, err := h.db.Exec(c.RequestCtx(), `
@@ -379,15 +337,11 @@ func (h *ServiceKeyHandler) DeleteServiceKey(c fiber.Ctx) error {
 	`, id)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to delete service key")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete service key",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to delete service key", ErrCodeOperationFailed)
 	}
 
 	if result.RowsAffected() == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Service key not found",
-		})
+		return SendNotFound(c, "Service key not found")
 	}
 
 	log.Info().
@@ -402,16 +356,12 @@ func (h *ServiceKeyHandler) DisableServiceKey(c fiber.Ctx) error {
 	idStr := c.Params("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid service key ID",
-		})
+		return SendBadRequest(c, "Invalid service key ID", ErrCodeInvalidID)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	result, err := h.db.Exec(c.RequestCtx(), `
@@ -419,15 +369,11 @@ func (h *ServiceKeyHandler) DisableServiceKey(c fiber.Ctx) error {
 	`, id)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to disable service key")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to disable service key",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to disable service key", ErrCodeOperationFailed)
 	}
 
 	if result.RowsAffected() == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Service key not found",
-		})
+		return SendNotFound(c, "Service key not found")
 	}
 
 	log.Info().
@@ -445,16 +391,12 @@ func (h *ServiceKeyHandler) EnableServiceKey(c fiber.Ctx) error {
 	idStr := c.Params("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid service key ID",
-		})
+		return SendBadRequest(c, "Invalid service key ID", ErrCodeInvalidID)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	result, err := h.db.Exec(c.RequestCtx(), `
@@ -462,15 +404,11 @@ func (h *ServiceKeyHandler) EnableServiceKey(c fiber.Ctx) error {
 	`, id)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to enable service key")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to enable service key",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to enable service key", ErrCodeOperationFailed)
 	}
 
 	if result.RowsAffected() == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Service key not found",
-		})
+		return SendNotFound(c, "Service key not found")
 	}
 
 	log.Info().
@@ -518,9 +456,7 @@ func (h *ServiceKeyHandler) RevokeServiceKey(c fiber.Ctx) error {
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	// Get the key prefix for audit log
@@ -617,9 +553,7 @@ func (h *ServiceKeyHandler) DeprecateServiceKey(c fiber.Ctx) error {
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	graceEndTime := time.Now().Add(time.Duration(gracePeriod) * time.Hour)
@@ -677,9 +611,7 @@ func (h *ServiceKeyHandler) RotateServiceKey(c fiber.Ctx) error {
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	// Get the old key details
@@ -824,9 +756,7 @@ func (h *ServiceKeyHandler) GetRevocationHistory(c fiber.Ctx) error {
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	rows, err := h.db.Query(c.RequestCtx(), `
@@ -47,16 +47,12 @@ func (h *SettingsHandler) GetSetting(c fiber.Ctx) error {
 	key := c.Params("key")
 
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting key is required",
-		})
+		return SendBadRequest(c, "Setting key is required", ErrCodeMissingField)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	var value interface{}
@@ -93,14 +89,10 @@ func (h *SettingsHandler) GetSetting(c fiber.Ctx) error {
 	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Setting not found or access denied",
-			})
+			return SendNotFound(c, "Setting not found or access denied")
 		}
 		log.Error().Err(err).Str("key", key).Msg("Failed to get setting")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve setting",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve setting", ErrCodeOperationFailed)
 	}
 
 	return c.JSON(SettingResponse{Value: value})
@@ -114,29 +106,21 @@ func (h *SettingsHandler) GetSettings(c fiber.Ctx) error {
 	var req BatchSettingsRequest
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse batch settings request")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	if len(req.Keys) == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "At least one key is required",
-		})
+		return SendBadRequest(c, "At least one key is required", ErrCodeMissingField)
 	}
 
 	// Limit the number of keys to prevent abuse
 	if len(req.Keys) > 100 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Maximum 100 keys allowed per request",
-		})
+		return SendBadRequest(c, "Maximum 100 keys allowed per request", ErrCodeInvalidInput)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	results := make(map[string]interface{})
@@ -181,9 +165,7 @@ func (h *SettingsHandler) GetSettings(c fiber.Ctx) error {
 	})
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get settings")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve settings",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve settings", ErrCodeOperationFailed)
 	}
 
 	// Convert map to array for response
@@ -11,6 +11,7 @@ import (
 	"github.com/gofiber/fiber/v3"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/audit"
 	"github.com/nimbleflux/fluxbase/internal/auth"
 	"github.com/rs/zerolog/log"
 )
@@ -19,6 +20,7 @@ import (
 type SQLHandler struct {
 	db          *pgxpool.Pool
 	authService *auth.Service
+	auditLogger *audit.Logger // nil when the audit log is disabled
 }
 
 // NewSQLHandler creates a new SQL handler
@@ -29,6 +31,11 @@ func NewSQLHandler(db *pgxpool.Pool, authService *auth.Service) *SQLHandler {
 	}
 }
 
+// SetAuditLogger sets the logger that records SQL editor executions in the audit log
+func (h *SQLHandler) SetAuditLogger(logger *audit.Logger) {
+	h.auditLogger = logger
+}
+
 // ExecuteSQLRequest represents a SQL execution request
 type ExecuteSQLRequest struct {
 	Query string `json:"query"`
@@ -53,6 +60,7 @@ type ExecuteSQLResponse struct {
 const (
 	maxRowsPerQuery = 1000
 	queryTimeout    = 30 * time.Second
+	maxAuditedQuery = 4000 // Bytes of a query kept in its audit event
 )
 
 // ExecuteSQL executes SQL queries provided by the user
@@ -72,23 +80,17 @@ func (h *SQLHandler) ExecuteSQL(c fiber.Ctx) error {
 	// Parse request
 	var req ExecuteSQLRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate query
 	if strings.TrimSpace(req.Query) == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Query cannot be empty",
-		})
+		return SendBadRequest(c, "Query cannot be empty", ErrCodeInvalidInput)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Database connection not initialized",
-		})
+		return SendInternalError(c, "Database connection not initialized")
 	}
 
 	// Get user information for audit logging
@@ -131,9 +133,7 @@ func (h *SQLHandler) ExecuteSQL(c fiber.Ctx) error {
 				Str("user_id", userID).
 				Str("token_preview", tokenPreview).
 				Msg("Invalid impersonation token in SQL query")
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid impersonation token",
-			})
+			return SendBadRequest(c, "Invalid impersonation token", ErrCodeInvalidInput)
 		}
 
 		log.Info().
@@ -145,6 +145,7 @@ func (h *SQLHandler) ExecuteSQL(c fiber.Ctx) error {
 			Msg("SQL query execution with impersonation token")
 
 		// Use impersonation claims for RLS context
+		h.recordExecution(c, req.Query, statements, impersonationClaims)
 		return h.executeWithRLSContext(c, req.Query, statements, impersonationClaims, userID)
 	}
 
@@ -162,13 +163,33 @@ func (h *SQLHandler) ExecuteSQL(c fiber.Ctx) error {
 	// Only use RLS context for known database roles (direct token, not impersonation)
 	// Dashboard admins (role like "dashboard_admin") get service_role access
 	if hasClaims && claims != nil && isKnownDatabaseRole(claims.Role) {
+		h.recordExecution(c, req.Query, statements, nil)
 		return h.executeWithRLSContext(c, req.Query, statements, claims, userID)
 	}
 
 	// Admin mode: execute with service_role for full access
+	h.recordExecution(c, req.Query, statements, nil)
 	return h.executeAsServiceRole(c, req.Query, statements, userID)
 }
 
+// recordExecution records a SQL editor execution in the audit log. impersonated is set when
+// the query runs as another user.
+func (h *SQLHandler) recordExecution(c fiber.Ctx, query string, statements []string, impersonated *auth.TokenClaims) {
+	details := map[string]any{
+		"query":      truncateString(query, maxAuditedQuery),
+		"statements": len(statements),
+	}
+	if impersonated != nil {
+		details["impersonated_user_id"] = impersonated.UserID
+		details["impersonated_role"] = impersonated.Role
+	}
+
+	h.auditLogger.RecordRequest(c, audit.Event{
+		Action:  audit.ActionSQLExecute,
+		Details: details,
+	})
+}
+
 // executeWithRLSContext executes SQL statements with Row Level Security context
 // This is used when impersonating a user to test RLS policies
 func (h *SQLHandler) executeWithRLSContext(c fiber.Ctx, fullQuery string, statements []string, claims *auth.TokenClaims, auditUserID string) error {
@@ -179,9 +200,7 @@ func (h *SQLHandler) executeWithRLSContext(c fiber.Ctx, fullQuery string, statem
 	// Acquire a dedicated connection for setting session variables
 	conn, err := h.db.Acquire(ctx)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to acquire database connection: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to acquire database connection: %v", err), ErrCodeOperationFailed)
 	}
 	defer conn.Release()
 
@@ -202,12 +221,14 @@ func (h *SQLHandler) executeWithRLSContext(c fiber.Ctx, fullQuery string, statem
 	if claims.AppMetadata != nil {
 		claimsMap["app_metadata"] = claims.AppMetadata
 	}
+	if claims.OrgID != "" {
+		claimsMap["org_id"] = claims.OrgID
+		claimsMap["org_role"] = claims.OrgRole
+	}
 
 	claimsJSON, err := json.Marshal(claimsMap)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to serialize JWT claims: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to serialize JWT claims: %v", err), ErrCodeOperationFailed)
 	}
 
 	// Determine the database role to use
@@ -245,27 +266,21 @@ func (h *SQLHandler) executeWithRLSContext(c fiber.Ctx, fullQuery string, statem
 
 	tx, err := conn.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to begin transaction: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to begin transaction: %v", err), ErrCodeOperationFailed)
 	}
 	defer func() { _ = tx.Rollback(ctx) }() // Will be no-op if committed
 
 	// Set RLS session variables
 	_, err = tx.Exec(ctx, "SELECT set_config('request.jwt.claims', $1, true)", string(claimsJSON))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to set JWT claims: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to set JWT claims: %v", err), ErrCodeOperationFailed)
 	}
 
 	// Set the role (use SET LOCAL to limit to current transaction)
 	// Note: We quote the role identifier to prevent SQL injection
 	_, err = tx.Exec(ctx, fmt.Sprintf("SET LOCAL ROLE %q", dbRole))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to set role '%s': %v", dbRole, err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to set role '%s': %v", dbRole, err), ErrCodeOperationFailed)
 	}
 
 	// Execute each statement
@@ -326,9 +341,7 @@ func (h *SQLHandler) executeAsServiceRole(c fiber.Ctx, fullQuery string, stateme
 
 	conn, err := h.db.Acquire(ctx)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to acquire database connection: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to acquire database connection: %v", err), ErrCodeOperationFailed)
 	}
 	defer conn.Release()
 
@@ -342,18 +355,14 @@ func (h *SQLHandler) executeAsServiceRole(c fiber.Ctx, fullQuery string, stateme
 
 	tx, err := conn.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to begin transaction: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to begin transaction: %v", err), ErrCodeOperationFailed)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
 	// Set service_role for full admin access
 	_, err = tx.Exec(ctx, "SET LOCAL ROLE service_role")
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to set service_role: %v", err),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to set service_role: %v", err), ErrCodeOperationFailed)
 	}
 
 	// Execute each statement
@@ -1,39 +1,79 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/database"
 	"github.com/rs/zerolog/log"
 )
 
+// bucketOrganizationID validates the organization_id of a bucket request. Returns nil for an
+// omitted or empty ID, which leaves the bucket without an organization.
+func bucketOrganizationID(id *string) (*string, error) {
+	if id == nil || *id == "" {
+		return nil, nil
+	}
+	if _, err := uuid.Parse(*id); err != nil {
+		return nil, errors.New("organization_id must be a UUID")
+	}
+	return id, nil
+}
+
 // CreateBucket handles bucket creation
 // POST /api/v1/storage/buckets/:bucket
 func (h *StorageHandler) CreateBucket(c fiber.Ctx) error {
 	bucket := c.Params("bucket")
 
 	if bucket == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "bucket name is required",
-		})
+		return SendBadRequest(c, "bucket name is required", ErrCodeMissingField)
 	}
 
 	// Parse request body for bucket configuration
 	var req struct {
-		Public           bool     `json:"public"`
-		AllowedMimeTypes []string `json:"allowed_mime_types"`
-		MaxFileSize      *int64   `json:"max_file_size"`
+		Public             bool     `json:"public"`
+		AllowedMimeTypes   []string `json:"allowed_mime_types"`
+		MaxFileSize        *int64   `json:"max_file_size"`
+		ContentDisposition *string  `json:"content_disposition"`
+		InlineMimeTypes    []string `json:"inline_mime_types"`
+		NoSniff            *bool    `json:"nosniff"`
+		HTMLPolicy         *string  `json:"html_policy"`
+		// Image transformations are enabled unless turned off
+		TransformationsEnabled *bool `json:"transformations_enabled"`
+		// Only members of the organization can access the bucket's objects
+		OrganizationID *string `json:"organization_id"`
 	}
 	// Try to parse body, but allow empty body (use defaults)
 	_ = c.Bind().Body(&req)
 
+	if err := validateDownloadSettings(req.ContentDisposition, req.HTMLPolicy, req.InlineMimeTypes); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+	organizationID, err := bucketOrganizationID(req.OrganizationID)
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	// Download settings not given in the request use the secure defaults
+	download := defaultBucketDownloadSettings()
+	if req.ContentDisposition != nil {
+		download.ContentDisposition = *req.ContentDisposition
+	}
+	if req.NoSniff != nil {
+		download.NoSniff = *req.NoSniff
+	}
+	if req.HTMLPolicy != nil {
+		download.HTMLPolicy = *req.HTMLPolicy
+	}
+	transformationsEnabled := req.TransformationsEnabled == nil || *req.TransformationsEnabled
+
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "database connection not initialized",
-		})
+		return SendInternalError(c, "database connection not initialized")
 	}
 
 	// Start database transaction
@@ -41,62 +81,51 @@ func (h *StorageHandler) CreateBucket(c fiber.Ctx) error {
 	tx, err := h.db.Pool().Begin(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to start transaction for bucket creation")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to create bucket",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to create bucket", ErrCodeOperationFailed)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
 	// Set RLS context
 	if err := h.setRLSContext(ctx, tx, c); err != nil {
 		log.Error().Err(err).Msg("Failed to set RLS context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to create bucket",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to create bucket", ErrCodeOperationFailed)
 	}
 
 	// Insert bucket into database (RLS will check permissions)
 	_, err = tx.Exec(ctx, `
-		INSERT INTO storage.buckets (id, name, public, allowed_mime_types, max_file_size)
-		VALUES ($1, $2, $3, $4, $5)
-	`, bucket, bucket, req.Public, req.AllowedMimeTypes, req.MaxFileSize)
+		INSERT INTO storage.buckets (id, name, public, allowed_mime_types, max_file_size,
+			content_disposition, inline_mime_types, nosniff, html_policy, transformations_enabled, organization_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, bucket, bucket, req.Public, req.AllowedMimeTypes, req.MaxFileSize,
+		download.ContentDisposition, req.InlineMimeTypes, download.NoSniff, download.HTMLPolicy, transformationsEnabled, organizationID)
 	if err != nil {
 		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "already exists") {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error": "bucket already exists",
-			})
+			return SendConflict(c, "bucket already exists", ErrCodeAlreadyExists)
+		}
+		if database.IsForeignKeyViolation(err) {
+			return SendBadRequest(c, "organization not found", ErrCodeInvalidInput)
 		}
 		if strings.Contains(err.Error(), "permission denied") || strings.Contains(err.Error(), "policy") {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "insufficient permissions to create bucket",
-			})
+			return SendForbidden(c, "insufficient permissions to create bucket", ErrCodeInsufficientPermissions)
 		}
 		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to insert bucket into database")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to create bucket",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to create bucket", ErrCodeOperationFailed)
 	}
 
 	// Create the bucket in storage provider
 	if err := h.storage.Provider.CreateBucket(ctx, bucket); err != nil {
 		// Rollback will happen via defer
 		if strings.Contains(err.Error(), "already exists") {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error": "bucket already exists in storage",
-			})
+			return SendConflict(c, "bucket already exists in storage", ErrCodeAlreadyExists)
 		}
 		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to create bucket in provider")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to create bucket in storage provider",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to create bucket in storage provider", ErrCodeOperationFailed)
 	}
 
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to commit bucket creation")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to create bucket",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to create bucket", ErrCodeOperationFailed)
 	}
 
 	log.Info().
@@ -106,13 +135,19 @@ func (h *StorageHandler) CreateBucket(c fiber.Ctx) error {
 		Msg("Bucket created")
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"bucket":             bucket,
-		"id":                 bucket,
-		"name":               bucket,
-		"public":             req.Public,
-		"allowed_mime_types": req.AllowedMimeTypes,
-		"max_file_size":      req.MaxFileSize,
-		"message":            "bucket created successfully",
+		"bucket":                  bucket,
+		"id":                      bucket,
+		"name":                    bucket,
+		"public":                  req.Public,
+		"allowed_mime_types":      req.AllowedMimeTypes,
+		"max_file_size":           req.MaxFileSize,
+		"content_disposition":     download.ContentDisposition,
+		"inline_mime_types":       req.InlineMimeTypes,
+		"nosniff":                 download.NoSniff,
+		"html_policy":             download.HTMLPolicy,
+		"transformations_enabled": transformationsEnabled,
+		"organization_id":         organizationID,
+		"message":                 "bucket created successfully",
 	})
 }
 
@@ -122,28 +157,37 @@ func (h *StorageHandler) UpdateBucketSettings(c fiber.Ctx) error {
 	bucket := c.Params("bucket")
 
 	if bucket == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "bucket name is required",
-		})
+		return SendBadRequest(c, "bucket name is required", ErrCodeMissingField)
 	}
 
 	// Parse request body
 	var req struct {
-		Public           *bool    `json:"public"`
-		AllowedMimeTypes []string `json:"allowed_mime_types"`
-		MaxFileSize      *int64   `json:"max_file_size"`
+		Public                 *bool    `json:"public"`
+		AllowedMimeTypes       []string `json:"allowed_mime_types"`
+		MaxFileSize            *int64   `json:"max_file_size"`
+		ContentDisposition     *string  `json:"content_disposition"`
+		InlineMimeTypes        []string `json:"inline_mime_types"`
+		NoSniff                *bool    `json:"nosniff"`
+		HTMLPolicy             *string  `json:"html_policy"`
+		TransformationsEnabled *bool    `json:"transformations_enabled"`
+		// An empty organization_id removes the bucket from its organization
+		OrganizationID *string `json:"organization_id"`
 	}
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		return SendBadRequest(c, "invalid request body", ErrCodeInvalidBody)
+	}
+
+	if err := validateDownloadSettings(req.ContentDisposition, req.HTMLPolicy, req.InlineMimeTypes); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+	organizationID, err := bucketOrganizationID(req.OrganizationID)
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "database connection not initialized",
-		})
+		return SendInternalError(c, "database connection not initialized")
 	}
 
 	ctx := c.RequestCtx()
@@ -152,18 +196,14 @@ func (h *StorageHandler) UpdateBucketSettings(c fiber.Ctx) error {
 	tx, err := h.db.Pool().Begin(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to start transaction for bucket update")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to update bucket",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to update bucket", ErrCodeOperationFailed)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
 	// Set RLS context
 	if err := h.setRLSContext(ctx, tx, c); err != nil {
 		log.Error().Err(err).Msg("Failed to set RLS context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to update bucket",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to update bucket", ErrCodeOperationFailed)
 	}
 
 	// Build dynamic UPDATE query based on provided fields
@@ -189,10 +229,44 @@ func (h *StorageHandler) UpdateBucketSettings(c fiber.Ctx) error {
 		args = append(args, req.MaxFileSize)
 	}
 
+	if req.ContentDisposition != nil {
+		argCount++
+		updates = append(updates, fmt.Sprintf("content_disposition = $%d", argCount))
+		args = append(args, *req.ContentDisposition)
+	}
+
+	if req.InlineMimeTypes != nil {
+		argCount++
+		updates = append(updates, fmt.Sprintf("inline_mime_types = $%d", argCount))
+		args = append(args, req.InlineMimeTypes)
+	}
+
+	if req.NoSniff != nil {
+		argCount++
+		updates = append(updates, fmt.Sprintf("nosniff = $%d", argCount))
+		args = append(args, *req.NoSniff)
+	}
+
+	if req.HTMLPolicy != nil {
+		argCount++
+		updates = append(updates, fmt.Sprintf("html_policy = $%d", argCount))
+		args = append(args, *req.HTMLPolicy)
+	}
+
+	if req.TransformationsEnabled != nil {
+		argCount++
+		updates = append(updates, fmt.Sprintf("transformations_enabled = $%d", argCount))
+		args = append(args, *req.TransformationsEnabled)
+	}
+
+	if req.OrganizationID != nil {
+		argCount++
+		updates = append(updates, fmt.Sprintf("organization_id = $%d", argCount))
+		args = append(args, organizationID)
+	}
+
 	if len(updates) == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "no fields to update",
-		})
+		return SendBadRequest(c, "no fields to update", ErrCodeInvalidInput)
 	}
 
 	updates = append(updates, "updated_at = NOW()")
@@ -206,29 +280,24 @@ func (h *StorageHandler) UpdateBucketSettings(c fiber.Ctx) error {
 	result, err := tx.Exec(ctx, query, args...)
 	if err != nil {
 		if strings.Contains(err.Error(), "permission denied") || strings.Contains(err.Error(), "policy") {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "insufficient permissions to update bucket",
-			})
+			return SendForbidden(c, "insufficient permissions to update bucket", ErrCodeInsufficientPermissions)
+		}
+		if database.IsForeignKeyViolation(err) {
+			return SendBadRequest(c, "organization not found", ErrCodeInvalidInput)
 		}
 		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to update bucket in database")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to update bucket",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to update bucket", ErrCodeOperationFailed)
 	}
 
 	// Check if any rows were affected
 	if result.RowsAffected() == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "bucket not found or insufficient permissions",
-		})
+		return SendNotFound(c, "bucket not found or insufficient permissions")
 	}
 
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to commit bucket update")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to update bucket",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to update bucket", ErrCodeOperationFailed)
 	}
 
 	log.Info().
@@ -248,34 +317,24 @@ func (h *StorageHandler) DeleteBucket(c fiber.Ctx) error {
 	bucket := c.Params("bucket")
 
 	if bucket == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "bucket name is required",
-		})
+		return SendBadRequest(c, "bucket name is required", ErrCodeMissingField)
 	}
 
 	// Check if storage service is available
 	if h.storage == nil || h.storage.Provider == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "storage service not initialized",
-		})
+		return SendInternalError(c, "storage service not initialized")
 	}
 
 	// Delete the bucket
 	if err := h.storage.Provider.DeleteBucket(c.RequestCtx(), bucket); err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "bucket not found",
-			})
+			return SendNotFound(c, "bucket not found")
 		}
 		if strings.Contains(err.Error(), "not empty") {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error": "bucket is not empty",
-			})
+			return SendConflict(c, "bucket is not empty", ErrCodeConflict)
 		}
 		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to delete bucket")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to delete bucket",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to delete bucket", ErrCodeOperationFailed)
 	}
 
 	log.Info().
@@ -293,16 +352,12 @@ func (h *StorageHandler) ListBuckets(c fiber.Ctx) error {
 	// Check if user has admin role
 	role, _ := c.Locals("user_role").(string)
 	if role != "admin" && role != "dashboard_admin" && role != "service_role" {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Admin access required to list buckets",
-		})
+		return SendForbidden(c, "Admin access required to list buckets", ErrCodeAdminRequired)
 	}
 
 	// Check if database connection is available
 	if h.db == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "database connection not initialized",
-		})
+		return SendInternalError(c, "database connection not initialized")
 	}
 
 	ctx := c.RequestCtx()
@@ -311,49 +366,54 @@ func (h *StorageHandler) ListBuckets(c fiber.Ctx) error {
 	tx, err := h.db.Pool().Begin(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to start transaction for listing buckets")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to list buckets",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to list buckets", ErrCodeOperationFailed)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
 	// Set RLS context
 	if err := h.setRLSContext(ctx, tx, c); err != nil {
 		log.Error().Err(err).Msg("Failed to set RLS context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to list buckets",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to list buckets", ErrCodeOperationFailed)
 	}
 
 	// Query buckets from database (RLS will filter based on permissions)
 	rows, err := tx.Query(ctx, `
-		SELECT id, name, public, allowed_mime_types, max_file_size, created_at, updated_at
+		SELECT id, name, public, allowed_mime_types, max_file_size,
+			content_disposition, inline_mime_types, nosniff, html_policy, transformations_enabled,
+			organization_id, created_at, updated_at
 		FROM storage.buckets
 		ORDER BY created_at DESC
 	`)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to query buckets from database")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to list buckets",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to list buckets", ErrCodeOperationFailed)
 	}
 	defer rows.Close()
 
 	// Parse results
 	type Bucket struct {
-		ID               string    `json:"id"`
-		Name             string    `json:"name"`
-		Public           bool      `json:"public"`
-		AllowedMimeTypes []string  `json:"allowed_mime_types"`
-		MaxFileSize      *int64    `json:"max_file_size"`
-		CreatedAt        time.Time `json:"created_at"`
-		UpdatedAt        time.Time `json:"updated_at"`
+		ID               string   `json:"id"`
+		Name             string   `json:"name"`
+		Public           bool     `json:"public"`
+		AllowedMimeTypes []string `json:"allowed_mime_types"`
+		MaxFileSize      *int64   `json:"max_file_size"`
+		// Download settings
+		ContentDisposition string   `json:"content_disposition"`
+		InlineMimeTypes    []string `json:"inline_mime_types"`
+		NoSniff            bool     `json:"nosniff"`
+		HTMLPolicy         string   `json:"html_policy"`
+		// Image transformations of the bucket's objects
+		TransformationsEnabled bool      `json:"transformations_enabled"`
+		OrganizationID         *string   `json:"organization_id"`
+		CreatedAt              time.Time `json:"created_at"`
+		UpdatedAt              time.Time `json:"updated_at"`
 	}
 
 	var buckets []Bucket
 	for rows.Next() {
 		var b Bucket
-		if err := rows.Scan(&b.ID, &b.Name, &b.Public, &b.AllowedMimeTypes, &b.MaxFileSize, &b.CreatedAt, &b.UpdatedAt); err != nil {
+		if err := rows.Scan(&b.ID, &b.Name, &b.Public, &b.AllowedMimeTypes, &b.MaxFileSize,
+			&b.ContentDisposition, &b.InlineMimeTypes, &b.NoSniff, &b.HTMLPolicy, &b.TransformationsEnabled, &b.OrganizationID, &b.CreatedAt, &b.UpdatedAt); err != nil {
 			log.Error().Err(err).Msg("Failed to scan bucket row")
 			continue
 		}
@@ -362,17 +422,13 @@ func (h *StorageHandler) ListBuckets(c fiber.Ctx) error {
 
 	if err := rows.Err(); err != nil {
 		log.Error().Err(err).Msg("Error iterating bucket rows")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to list buckets",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to list buckets", ErrCodeOperationFailed)
 	}
 
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		log.Error().Err(err).Msg("Failed to commit bucket list transaction")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to list buckets",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to list buckets", ErrCodeOperationFailed)
 	}
 
 	return c.JSON(fiber.Map{
@@ -358,6 +358,45 @@ func TestStorageHandler_UpdateBucketSettings_InvalidBody(t *testing.T) {
 	assert.Contains(t, result["error"], "invalid request body")
 }
 
+func TestStorageHandler_BucketDownloadSettings_Invalid(t *testing.T) {
+	handler := &StorageHandler{}
+
+	app := setupTestFiberApp()
+	app.Post("/storage/buckets/:bucket", handler.CreateBucket)
+	app.Put("/storage/buckets/:bucket", handler.UpdateBucketSettings)
+
+	tests := []struct {
+		name          string
+		method        string
+		body          string
+		expectedError string
+	}{
+		{"create with unknown disposition", "POST", `{"content_disposition": "preview"}`, "content_disposition must be"},
+		{"create with unknown html policy", "POST", `{"html_policy": "allow"}`, "html_policy must be"},
+		{"update with invalid inline type", "PUT", `{"inline_mime_types": ["not a type"]}`, "invalid inline MIME type"},
+		{"create with invalid organization", "POST", `{"organization_id": "acme"}`, "organization_id must be a UUID"},
+		{"update with invalid organization", "PUT", `{"organization_id": "acme"}`, "organization_id must be a UUID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/storage/buckets/mybucket", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+			var result map[string]interface{}
+			err = json.NewDecoder(resp.Body).Decode(&result)
+			require.NoError(t, err)
+			assert.Contains(t, result["error"], tt.expectedError)
+		})
+	}
+}
+
 // NOTE: TestStorageHandler_UpdateBucketSettings_NoFieldsToUpdate was removed
 // because empty JSON `{}` passes body parsing validation, then the handler
 // calls h.db.Pool().Begin() which panics with nil db. This test case requires
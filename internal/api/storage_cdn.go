@@ -0,0 +1,215 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/cdn"
+	"github.com/rs/zerolog/log"
+)
+
+// maxPurgeKeys bounds the keys of a manual purge request
+const maxPurgeKeys = 100
+
+// SetCDN enables per-bucket CDN URLs and cache purges
+func (h *StorageHandler) SetCDN(cdnService *cdn.Service) {
+	h.cdn = cdnService
+}
+
+// isStorageAdmin reports whether the request may manage bucket settings
+func isStorageAdmin(c fiber.Ctx) bool {
+	role, _ := c.Locals("user_role").(string)
+	return role == "admin" || role == "dashboard_admin" || role == "service_role"
+}
+
+// purgeCDN purges updated or deleted objects from the bucket's CDN in the background
+func (h *StorageHandler) purgeCDN(bucket string, keys ...string) {
+	if h.cdn != nil && len(keys) > 0 {
+		h.cdn.Purge(bucket, keys...)
+	}
+}
+
+// cdnPublicURL returns the CDN URL of an object, empty if the bucket isn't served openly
+// by a CDN
+func (h *StorageHandler) cdnPublicURL(ctx context.Context, bucket, key string) string {
+	if h.cdn == nil {
+		return ""
+	}
+	return h.cdn.PublicURL(ctx, bucket, key)
+}
+
+// GetBucketCDN returns the CDN settings of a bucket. Credentials are never returned, only
+// whether they are set.
+// GET /api/v1/storage/buckets/:bucket/cdn
+func (h *StorageHandler) GetBucketCDN(c fiber.Ctx) error {
+	if !isStorageAdmin(c) {
+		return SendForbidden(c, "Admin access required to manage bucket CDN settings", ErrCodeAdminRequired)
+	}
+	if h.cdn == nil {
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "CDN integration is not available", ErrCodeServiceUnavailable)
+	}
+
+	bucket := c.Params("bucket")
+	settings, err := h.cdn.Get(c.RequestCtx(), bucket)
+	if errors.Is(err, cdn.ErrNotConfigured) {
+		return SendNotFound(c, "bucket has no CDN configured")
+	}
+	if err != nil {
+		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to get bucket CDN settings")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to get CDN settings", ErrCodeOperationFailed)
+	}
+
+	return c.JSON(settings)
+}
+
+// PutBucketCDN creates or replaces the CDN settings of a bucket. signing_key and purge_token
+// keep their current value when omitted and are removed when empty.
+// PUT /api/v1/storage/buckets/:bucket/cdn
+func (h *StorageHandler) PutBucketCDN(c fiber.Ctx) error {
+	if !isStorageAdmin(c) {
+		return SendForbidden(c, "Admin access required to manage bucket CDN settings", ErrCodeAdminRequired)
+	}
+	if h.cdn == nil {
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "CDN integration is not available", ErrCodeServiceUnavailable)
+	}
+
+	var req struct {
+		Enabled       *bool   `json:"enabled"`
+		BaseURL       string  `json:"base_url"`
+		SigningScheme string  `json:"signing_scheme"`
+		SigningKey    *string `json:"signing_key"`
+		PurgeProvider string  `json:"purge_provider"`
+		PurgeZoneID   string  `json:"purge_zone_id"`
+		PurgeURL      string  `json:"purge_url"`
+		PurgeToken    *string `json:"purge_token"`
+	}
+	if err := c.Bind().Body(&req); err != nil {
+		return SendBadRequest(c, "invalid request body", ErrCodeInvalidBody)
+	}
+
+	ctx := c.RequestCtx()
+	bucket := c.Params("bucket")
+	settings := &cdn.Settings{
+		Bucket:        bucket,
+		Enabled:       req.Enabled == nil || *req.Enabled,
+		BaseURL:       req.BaseURL,
+		SigningScheme: req.SigningScheme,
+		PurgeProvider: req.PurgeProvider,
+		PurgeZoneID:   req.PurgeZoneID,
+		PurgeURL:      req.PurgeURL,
+	}
+	if settings.SigningScheme == "" {
+		settings.SigningScheme = cdn.SigningNone
+	}
+	if settings.PurgeProvider == "" {
+		settings.PurgeProvider = cdn.PurgeNone
+	}
+
+	// Keep the stored credentials the request doesn't replace
+	if req.SigningKey == nil || req.PurgeToken == nil {
+		current, err := h.cdn.Get(ctx, bucket)
+		if err != nil && !errors.Is(err, cdn.ErrNotConfigured) {
+			log.Error().Err(err).Str("bucket", bucket).Msg("Failed to get bucket CDN settings")
+			return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to update CDN settings", ErrCodeOperationFailed)
+		}
+		if current != nil {
+			settings.SigningKey, settings.PurgeToken = current.SigningKey, current.PurgeToken
+		}
+	}
+	if req.SigningKey != nil {
+		settings.SigningKey = *req.SigningKey
+	}
+	if req.PurgeToken != nil {
+		settings.PurgeToken = *req.PurgeToken
+	}
+	// Credentials the scheme and provider don't use are not kept
+	if settings.SigningScheme == cdn.SigningNone {
+		settings.SigningKey = ""
+	}
+	if settings.PurgeProvider == cdn.PurgeNone {
+		settings.PurgeToken = ""
+	}
+
+	if err := settings.Validate(); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	err := h.cdn.Put(ctx, settings, getUserIDFromContext(c))
+	if errors.Is(err, cdn.ErrBucketNotFound) {
+		return SendNotFound(c, "bucket not found")
+	}
+	if err != nil {
+		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to save bucket CDN settings")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to update CDN settings", ErrCodeOperationFailed)
+	}
+
+	log.Info().
+		Str("bucket", bucket).
+		Str("base_url", settings.BaseURL).
+		Str("signing_scheme", settings.SigningScheme).
+		Str("purge_provider", settings.PurgeProvider).
+		Str("user_id", getUserID(c)).
+		Msg("Bucket CDN settings updated")
+
+	return c.JSON(settings)
+}
+
+// DeleteBucketCDN removes the CDN settings of a bucket, serving it from the API again
+// DELETE /api/v1/storage/buckets/:bucket/cdn
+func (h *StorageHandler) DeleteBucketCDN(c fiber.Ctx) error {
+	if !isStorageAdmin(c) {
+		return SendForbidden(c, "Admin access required to manage bucket CDN settings", ErrCodeAdminRequired)
+	}
+	if h.cdn == nil {
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "CDN integration is not available", ErrCodeServiceUnavailable)
+	}
+
+	bucket := c.Params("bucket")
+	err := h.cdn.Delete(c.RequestCtx(), bucket)
+	if errors.Is(err, cdn.ErrNotConfigured) {
+		return SendNotFound(c, "bucket has no CDN configured")
+	}
+	if err != nil {
+		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to delete bucket CDN settings")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to delete CDN settings", ErrCodeOperationFailed)
+	}
+
+	return c.Status(fiber.StatusNoContent).Send(nil)
+}
+
+// PurgeBucketCDN purges objects from the bucket's CDN right away, e.g. after changing them
+// outside the API
+// POST /api/v1/storage/buckets/:bucket/cdn/purge {"keys": ["users/42/avatar.png"]}
+func (h *StorageHandler) PurgeBucketCDN(c fiber.Ctx) error {
+	if !isStorageAdmin(c) {
+		return SendForbidden(c, "Admin access required to manage bucket CDN settings", ErrCodeAdminRequired)
+	}
+	if h.cdn == nil {
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "CDN integration is not available", ErrCodeServiceUnavailable)
+	}
+
+	var req struct {
+		Keys []string `json:"keys"`
+	}
+	if err := c.Bind().Body(&req); err != nil {
+		return SendBadRequest(c, "invalid request body", ErrCodeInvalidBody)
+	}
+	if len(req.Keys) == 0 || len(req.Keys) > maxPurgeKeys {
+		return SendBadRequest(c, "keys must list between 1 and 100 object keys", ErrCodeInvalidInput)
+	}
+
+	bucket := c.Params("bucket")
+	err := h.cdn.PurgeNow(c.RequestCtx(), bucket, req.Keys)
+	if errors.Is(err, cdn.ErrNotConfigured) {
+		return SendNotFound(c, "bucket has no CDN purge provider configured")
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("bucket", bucket).Msg("Failed to purge CDN cache")
+		return SendErrorWithCode(c, fiber.StatusBadGateway, "CDN purge failed: "+err.Error(), ErrCodeUpstreamError)
+	}
+
+	return c.JSON(fiber.Map{
+		"purged": len(req.Keys),
+	})
+}
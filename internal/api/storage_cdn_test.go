@@ -0,0 +1,54 @@
+package api
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/cdn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withTestCDN configures a handler with the CDN
+func withTestCDN(h *StorageHandler) {
+	h.SetCDN(cdn.NewService(cdn.NewStore(nil, ""), cdn.NewPurger()))
+}
+
+func TestStorageCDN_RequiresAdmin(t *testing.T) {
+	assertStorageAdminOnly(t, "/buckets/avatars/cdn", withTestCDN)
+}
+
+func TestStorageCDN_ValidatesRequests(t *testing.T) {
+	app := newStorageAdminTestApp("service_role", withTestCDN)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		msg    string
+	}{
+		{"missing base URL", "PUT", "/buckets/avatars/cdn", `{"signing_key":"","purge_token":""}`, "base_url must be an absolute"},
+		{"signing without key", "PUT", "/buckets/avatars/cdn", `{"base_url":"https://cdn.example.com","signing_scheme":"hmac","signing_key":"","purge_token":""}`, "signing_key is required"},
+		{"cloudflare without zone", "PUT", "/buckets/avatars/cdn", `{"base_url":"https://cdn.example.com","purge_provider":"cloudflare","signing_key":"","purge_token":"t"}`, "purge_zone_id and purge_token"},
+		{"no keys to purge", "POST", "/buckets/avatars/cdn/purge", `{"keys":[]}`, "keys must list between 1 and 100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			body := new(strings.Builder)
+			_, _ = io.Copy(body, resp.Body)
+			assert.Contains(t, body.String(), tt.msg)
+		})
+	}
+}
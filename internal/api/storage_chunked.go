@@ -59,29 +59,21 @@ type CompleteChunkedUploadResponse struct {
 func (h *StorageHandler) InitChunkedUpload(c fiber.Ctx) error {
 	bucket := c.Params("bucket")
 	if bucket == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "bucket is required",
-		})
+		return SendBadRequest(c, "bucket is required", ErrCodeMissingField)
 	}
 
 	// Parse request body
 	var req InitChunkedUploadRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body: " + err.Error(),
-		})
+		return SendBadRequest(c, "invalid request body: "+err.Error(), ErrCodeInvalidBody)
 	}
 
 	if req.Path == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "path is required",
-		})
+		return SendBadRequest(c, "path is required", ErrCodeMissingField)
 	}
 
 	if req.TotalSize <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "total_size must be greater than 0",
-		})
+		return SendBadRequest(c, "total_size must be greater than 0", ErrCodeInvalidInput)
 	}
 
 	// Default chunk size to 5MB if not specified
@@ -97,9 +89,7 @@ func (h *StorageHandler) InitChunkedUpload(c fiber.Ctx) error {
 
 	// Validate total size
 	if err := h.storage.ValidateUploadSize(req.TotalSize); err != nil {
-		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendErrorWithCode(c, fiber.StatusRequestEntityTooLarge, err.Error(), ErrCodePayloadTooLarge)
 	}
 
 	// Get owner ID from authenticated user
@@ -125,16 +115,12 @@ func (h *StorageHandler) InitChunkedUpload(c fiber.Ctx) error {
 	case *storage.S3Storage:
 		session, err = provider.InitChunkedUpload(ctx, bucket, req.Path, req.TotalSize, chunkSize, opts)
 	default:
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "storage provider does not support chunked uploads",
-		})
+		return SendInternalError(c, "storage provider does not support chunked uploads")
 	}
 
 	if err != nil {
 		log.Error().Err(err).Str("bucket", bucket).Str("path", req.Path).Msg("Failed to initialize chunked upload")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to initialize chunked upload: " + err.Error(),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to initialize chunked upload: "+err.Error(), ErrCodeOperationFailed)
 	}
 
 	session.OwnerID = ownerID
@@ -174,24 +160,18 @@ func (h *StorageHandler) UploadChunk(c fiber.Ctx) error {
 	chunkIndexStr := c.Params("chunkIndex")
 
 	if bucket == "" || uploadID == "" || chunkIndexStr == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "bucket, uploadId, and chunkIndex are required",
-		})
+		return SendBadRequest(c, "bucket, uploadId, and chunkIndex are required", ErrCodeMissingField)
 	}
 
 	chunkIndex, err := strconv.Atoi(chunkIndexStr)
 	if err != nil || chunkIndex < 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid chunkIndex: must be a non-negative integer",
-		})
+		return SendBadRequest(c, "invalid chunkIndex: must be a non-negative integer", ErrCodeInvalidInput)
 	}
 
 	// Get chunk size from Content-Length header
 	size := int64(c.Request().Header.ContentLength())
 	if size <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Content-Length header is required",
-		})
+		return SendBadRequest(c, "Content-Length header is required", ErrCodeMissingField)
 	}
 
 	ctx := c.RequestCtx()
@@ -199,30 +179,22 @@ func (h *StorageHandler) UploadChunk(c fiber.Ctx) error {
 	// Retrieve session
 	session, err := h.getChunkedUploadSession(ctx, uploadID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "upload session not found: " + err.Error(),
-		})
+		return SendNotFound(c, "upload session not found: "+err.Error())
 	}
 
 	// Verify bucket matches
 	if session.Bucket != bucket {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "bucket mismatch",
-		})
+		return SendBadRequest(c, "bucket mismatch", ErrCodeInvalidInput)
 	}
 
 	// Check if session is still active
 	if session.Status != "active" {
-		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-			"error": fmt.Sprintf("upload session is not active (status: %s)", session.Status),
-		})
+		return SendConflict(c, fmt.Sprintf("upload session is not active (status: %s)", session.Status), ErrCodeConflict)
 	}
 
 	// Check expiration
 	if time.Now().After(session.ExpiresAt) {
-		return c.Status(fiber.StatusGone).JSON(fiber.Map{
-			"error": "upload session has expired",
-		})
+		return SendErrorWithCode(c, fiber.StatusGone, "upload session has expired", ErrCodeGone)
 	}
 
 	// Get the request body as a stream reader
@@ -234,9 +206,7 @@ func (h *StorageHandler) UploadChunk(c fiber.Ctx) error {
 		// Fall back to reading the buffered body
 		bodyBytes := c.Body()
 		if len(bodyBytes) == 0 {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "request body is required",
-			})
+			return SendBadRequest(c, "request body is required", ErrCodeInvalidBody)
 		}
 		body = bytes.NewReader(bodyBytes)
 	}
@@ -250,16 +220,12 @@ func (h *StorageHandler) UploadChunk(c fiber.Ctx) error {
 	case *storage.S3Storage:
 		result, err = provider.UploadChunk(ctx, session, chunkIndex, body, size)
 	default:
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "storage provider does not support chunked uploads",
-		})
+		return SendInternalError(c, "storage provider does not support chunked uploads")
 	}
 
 	if err != nil {
 		log.Error().Err(err).Str("uploadID", uploadID).Int("chunkIndex", chunkIndex).Msg("Failed to upload chunk")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to upload chunk: " + err.Error(),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to upload chunk: "+err.Error(), ErrCodeOperationFailed)
 	}
 
 	// Update session with the completed chunk
@@ -306,9 +272,7 @@ func (h *StorageHandler) CompleteChunkedUpload(c fiber.Ctx) error {
 	uploadID := c.Params("uploadId")
 
 	if bucket == "" || uploadID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "bucket and uploadId are required",
-		})
+		return SendBadRequest(c, "bucket and uploadId are required", ErrCodeMissingField)
 	}
 
 	ctx := c.RequestCtx()
@@ -316,16 +280,12 @@ func (h *StorageHandler) CompleteChunkedUpload(c fiber.Ctx) error {
 	// Retrieve session
 	session, err := h.getChunkedUploadSession(ctx, uploadID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "upload session not found: " + err.Error(),
-		})
+		return SendNotFound(c, "upload session not found: "+err.Error())
 	}
 
 	// Verify bucket matches
 	if session.Bucket != bucket {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "bucket mismatch",
-		})
+		return SendBadRequest(c, "bucket mismatch", ErrCodeInvalidInput)
 	}
 
 	// Check if all chunks are uploaded
@@ -342,6 +302,7 @@ func (h *StorageHandler) CompleteChunkedUpload(c fiber.Ctx) error {
 		}
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error":          "not all chunks have been uploaded",
+			"code":           ErrCodeInvalidInput,
 			"missing_chunks": missingChunks,
 			"uploaded":       len(session.CompletedChunks),
 			"total":          session.TotalChunks,
@@ -361,18 +322,14 @@ func (h *StorageHandler) CompleteChunkedUpload(c fiber.Ctx) error {
 	case *storage.S3Storage:
 		object, err = provider.CompleteChunkedUpload(ctx, session)
 	default:
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "storage provider does not support chunked uploads",
-		})
+		return SendInternalError(c, "storage provider does not support chunked uploads")
 	}
 
 	if err != nil {
 		session.Status = "active" // Revert status on failure
 		_ = h.updateChunkedUploadSession(ctx, session)
 		log.Error().Err(err).Str("uploadID", uploadID).Msg("Failed to complete chunked upload")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to complete chunked upload: " + err.Error(),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to complete chunked upload: "+err.Error(), ErrCodeOperationFailed)
 	}
 
 	// Store object record in database
@@ -391,6 +348,9 @@ func (h *StorageHandler) CompleteChunkedUpload(c fiber.Ctx) error {
 		Int64("size", object.Size).
 		Msg("Chunked upload completed")
 
+	h.purgeCDN(bucket, session.Key)
+	h.fireObjectTriggers(c, bucket, session.Key, session.ContentType, object.Size)
+
 	return c.Status(fiber.StatusOK).JSON(CompleteChunkedUploadResponse{
 		ID:          object.ETag,
 		Path:        object.Key,
@@ -407,9 +367,7 @@ func (h *StorageHandler) GetChunkedUploadStatus(c fiber.Ctx) error {
 	uploadID := c.Params("uploadId")
 
 	if bucket == "" || uploadID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "bucket and uploadId are required",
-		})
+		return SendBadRequest(c, "bucket and uploadId are required", ErrCodeMissingField)
 	}
 
 	ctx := c.RequestCtx()
@@ -417,16 +375,12 @@ func (h *StorageHandler) GetChunkedUploadStatus(c fiber.Ctx) error {
 	// Retrieve session
 	session, err := h.getChunkedUploadSession(ctx, uploadID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "upload session not found: " + err.Error(),
-		})
+		return SendNotFound(c, "upload session not found: "+err.Error())
 	}
 
 	// Verify bucket matches
 	if session.Bucket != bucket {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "bucket mismatch",
-		})
+		return SendBadRequest(c, "bucket mismatch", ErrCodeInvalidInput)
 	}
 
 	// Calculate missing chunks
@@ -465,9 +419,7 @@ func (h *StorageHandler) AbortChunkedUpload(c fiber.Ctx) error {
 	uploadID := c.Params("uploadId")
 
 	if bucket == "" || uploadID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "bucket and uploadId are required",
-		})
+		return SendBadRequest(c, "bucket and uploadId are required", ErrCodeMissingField)
 	}
 
 	ctx := c.RequestCtx()
@@ -475,16 +427,12 @@ func (h *StorageHandler) AbortChunkedUpload(c fiber.Ctx) error {
 	// Retrieve session
 	session, err := h.getChunkedUploadSession(ctx, uploadID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "upload session not found: " + err.Error(),
-		})
+		return SendNotFound(c, "upload session not found: "+err.Error())
 	}
 
 	// Verify bucket matches
 	if session.Bucket != bucket {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "bucket mismatch",
-		})
+		return SendBadRequest(c, "bucket mismatch", ErrCodeInvalidInput)
 	}
 
 	// Abort the upload
@@ -494,16 +442,12 @@ func (h *StorageHandler) AbortChunkedUpload(c fiber.Ctx) error {
 	case *storage.S3Storage:
 		err = provider.AbortChunkedUpload(ctx, session)
 	default:
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "storage provider does not support chunked uploads",
-		})
+		return SendInternalError(c, "storage provider does not support chunked uploads")
 	}
 
 	if err != nil {
 		log.Error().Err(err).Str("uploadID", uploadID).Msg("Failed to abort chunked upload")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to abort chunked upload: " + err.Error(),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to abort chunked upload: "+err.Error(), ErrCodeOperationFailed)
 	}
 
 	// Delete session from database
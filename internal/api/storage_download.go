@@ -0,0 +1,226 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+)
+
+// Bucket Content-Disposition defaults
+const (
+	DispositionAttachment = "attachment"
+	DispositionInline     = "inline"
+)
+
+// Bucket policies for HTML, SVG and XML objects, which browsers can execute scripts from
+const (
+	HTMLPolicyAttachment = "attachment" // always download
+	HTMLPolicyText       = "text"       // serve the source as text/plain
+	HTMLPolicySandbox    = "sandbox"    // allow inline display under a CSP sandbox
+)
+
+// sandboxCSP blocks scripts, plugins, forms and same-origin access for HTML served from storage
+const sandboxCSP = "sandbox; default-src 'none'; img-src 'self' data:; style-src 'unsafe-inline'; media-src 'self'"
+
+// defaultInlineMimeTypes may be displayed inline when a bucket doesn't list its own
+var defaultInlineMimeTypes = []string{
+	"image/jpeg",
+	"image/png",
+	"image/gif",
+	"image/webp",
+	"application/pdf",
+	"video/mp4",
+	"audio/mpeg",
+}
+
+// activeContentTypes are rendered by browsers as documents that can run scripts
+var activeContentTypes = map[string]bool{
+	"text/html":             true,
+	"application/xhtml+xml": true,
+	"image/svg+xml":         true,
+	"text/xml":              true,
+	"application/xml":       true,
+}
+
+// bucketDownloadSettings controls the headers storage downloads are served with
+type bucketDownloadSettings struct {
+	ContentDisposition string
+	InlineMimeTypes    []string
+	NoSniff            bool
+	HTMLPolicy         string
+}
+
+func defaultBucketDownloadSettings() bucketDownloadSettings {
+	return bucketDownloadSettings{
+		ContentDisposition: DispositionAttachment,
+		NoSniff:            true,
+		HTMLPolicy:         HTMLPolicyAttachment,
+	}
+}
+
+// validateDownloadSettings checks bucket download settings from a create or update request
+func validateDownloadSettings(contentDisposition, htmlPolicy *string, inlineMimeTypes []string) error {
+	if contentDisposition != nil && *contentDisposition != DispositionAttachment && *contentDisposition != DispositionInline {
+		return fmt.Errorf("content_disposition must be '%s' or '%s'", DispositionAttachment, DispositionInline)
+	}
+	if htmlPolicy != nil {
+		switch *htmlPolicy {
+		case HTMLPolicyAttachment, HTMLPolicyText, HTMLPolicySandbox:
+		default:
+			return fmt.Errorf("html_policy must be '%s', '%s' or '%s'", HTMLPolicyAttachment, HTMLPolicyText, HTMLPolicySandbox)
+		}
+	}
+	for _, mimeType := range inlineMimeTypes {
+		if strings.HasSuffix(mimeType, "/*") && strings.Count(mimeType, "/") == 1 {
+			continue
+		}
+		if _, _, err := mime.ParseMediaType(mimeType); err != nil || !strings.Contains(mimeType, "/") {
+			return fmt.Errorf("invalid inline MIME type %q", mimeType)
+		}
+	}
+	return nil
+}
+
+// mimeTypeAllowed reports whether contentType matches an entry of allowed, which may use wildcards like "image/*"
+func mimeTypeAllowed(allowed []string, contentType string) bool {
+	for _, allowedType := range allowed {
+		if allowedType == contentType || allowedType == "*/*" {
+			return true
+		}
+		if strings.HasSuffix(allowedType, "/*") {
+			prefix := strings.TrimSuffix(allowedType, "/*")
+			if strings.HasPrefix(contentType, prefix+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// baseMediaType strips parameters like charset from a Content-Type
+func baseMediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, _, _ = strings.Cut(contentType, ";")
+	}
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+// downloadHeaders are the presentation headers for a single download
+type downloadHeaders struct {
+	ContentType           string
+	ContentDisposition    string
+	ContentSecurityPolicy string
+	NoSniff               bool
+}
+
+// resolveDownloadHeaders decides how a stored object is presented. inline is the caller's
+// preference (nil uses the bucket default); inline display is only granted to MIME types the
+// bucket allows inline, and HTML-like objects follow the bucket's HTML policy.
+func resolveDownloadHeaders(settings bucketDownloadSettings, contentType, filename string, inline *bool) downloadHeaders {
+	mediaType := baseMediaType(contentType)
+	headers := downloadHeaders{ContentType: contentType, NoSniff: settings.NoSniff}
+	if mediaType == "" {
+		headers.ContentType = "application/octet-stream"
+	}
+
+	wantInline := settings.ContentDisposition == DispositionInline
+	if inline != nil {
+		wantInline = *inline
+	}
+
+	inlineTypes := settings.InlineMimeTypes
+	if inlineTypes == nil {
+		inlineTypes = defaultInlineMimeTypes
+	}
+	allowInline := mediaType != "" && mimeTypeAllowed(inlineTypes, mediaType)
+
+	if activeContentTypes[mediaType] {
+		// Even when downloaded, keep the document from running scripts if it is opened from the origin
+		headers.ContentSecurityPolicy = sandboxCSP
+		switch settings.HTMLPolicy {
+		case HTMLPolicyText:
+			headers.ContentType = "text/plain; charset=utf-8"
+			allowInline = true
+		case HTMLPolicySandbox:
+			allowInline = true
+		default:
+			allowInline = false
+		}
+	}
+
+	disposition := DispositionAttachment
+	if wantInline && allowInline {
+		disposition = DispositionInline
+	}
+	headers.ContentDisposition = formatContentDisposition(disposition, filename)
+	return headers
+}
+
+// formatContentDisposition quotes the filename, encoding non-ASCII names per RFC 2231
+func formatContentDisposition(disposition, filename string) string {
+	printable := true
+	for _, r := range filename {
+		if r < 0x20 || r > 0x7e {
+			printable = false
+			break
+		}
+	}
+	if printable {
+		return fmt.Sprintf(`%s; filename="%s"`, disposition, strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(filename))
+	}
+	if value := mime.FormatMediaType(disposition, map[string]string{"filename": filename}); value != "" {
+		return value
+	}
+	return disposition
+}
+
+// apply sets the headers on the response
+func (d downloadHeaders) apply(c fiber.Ctx) {
+	c.Set("Content-Type", d.ContentType)
+	c.Set("Content-Disposition", d.ContentDisposition)
+	if d.ContentSecurityPolicy != "" {
+		c.Set("Content-Security-Policy", d.ContentSecurityPolicy)
+	}
+	if d.NoSniff {
+		c.Set("X-Content-Type-Options", "nosniff")
+	}
+}
+
+// downloadPreference reads the inline/download query parameters, nil when neither is set
+func downloadPreference(c fiber.Ctx) *bool {
+	if c.Query("download") == "true" {
+		inline := false
+		return &inline
+	}
+	if value := c.Query("inline"); value != "" {
+		inline := value == "true"
+		return &inline
+	}
+	return nil
+}
+
+// getDownloadSettings loads a bucket's download settings, falling back to the secure defaults
+func (h *StorageHandler) getDownloadSettings(ctx context.Context, bucket string) (bucketDownloadSettings, error) {
+	settings := defaultBucketDownloadSettings()
+	if h.db == nil {
+		return settings, nil
+	}
+
+	err := h.db.Pool().QueryRow(ctx,
+		`SELECT content_disposition, inline_mime_types, nosniff, html_policy FROM storage.get_bucket_download_settings($1)`,
+		bucket,
+	).Scan(&settings.ContentDisposition, &settings.InlineMimeTypes, &settings.NoSniff, &settings.HTMLPolicy)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return defaultBucketDownloadSettings(), nil
+	}
+	if err != nil {
+		return defaultBucketDownloadSettings(), err
+	}
+	return settings, nil
+}
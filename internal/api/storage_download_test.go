@@ -0,0 +1,93 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveDownloadHeaders(t *testing.T) {
+	yes, no := true, false
+	defaults := defaultBucketDownloadSettings()
+	inlineBucket := defaultBucketDownloadSettings()
+	inlineBucket.ContentDisposition = DispositionInline
+
+	tests := []struct {
+		name        string
+		settings    bucketDownloadSettings
+		contentType string
+		inline      *bool
+		wantType    string
+		wantDisp    string
+		wantCSP     bool
+	}{
+		{"attachment by default", defaults, "image/png", nil, "image/png", `attachment; filename="a.png"`, false},
+		{"inline on request for safe type", defaults, "image/png", &yes, "image/png", `inline; filename="a.png"`, false},
+		{"inline refused for unlisted type", defaults, "application/zip", &yes, "application/zip", `attachment; filename="a.png"`, false},
+		{"inline bucket default", inlineBucket, "application/pdf", nil, "application/pdf", `inline; filename="a.png"`, false},
+		{"download overrides inline bucket", inlineBucket, "application/pdf", &no, "application/pdf", `attachment; filename="a.png"`, false},
+		{"html forced to download", inlineBucket, "text/html; charset=utf-8", &yes, "text/html; charset=utf-8", `attachment; filename="a.png"`, true},
+		{"svg is active content", inlineBucket, "image/svg+xml", nil, "image/svg+xml", `attachment; filename="a.png"`, true},
+		{"missing type", defaults, "", nil, "application/octet-stream", `attachment; filename="a.png"`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := resolveDownloadHeaders(tt.settings, tt.contentType, "a.png", tt.inline)
+			assert.Equal(t, tt.wantType, headers.ContentType)
+			assert.Equal(t, tt.wantDisp, headers.ContentDisposition)
+			assert.Equal(t, tt.wantCSP, headers.ContentSecurityPolicy != "")
+			assert.True(t, headers.NoSniff)
+		})
+	}
+
+	t.Run("html policies", func(t *testing.T) {
+		settings := inlineBucket
+		settings.HTMLPolicy = HTMLPolicyText
+		headers := resolveDownloadHeaders(settings, "text/html", "page.html", nil)
+		assert.Equal(t, "text/plain; charset=utf-8", headers.ContentType)
+		assert.Equal(t, `inline; filename="page.html"`, headers.ContentDisposition)
+
+		settings.HTMLPolicy = HTMLPolicySandbox
+		headers = resolveDownloadHeaders(settings, "text/html", "page.html", nil)
+		assert.Equal(t, "text/html", headers.ContentType)
+		assert.Equal(t, `inline; filename="page.html"`, headers.ContentDisposition)
+		assert.Equal(t, sandboxCSP, headers.ContentSecurityPolicy)
+	})
+
+	t.Run("bucket inline list and nosniff", func(t *testing.T) {
+		settings := defaults
+		settings.InlineMimeTypes = []string{"text/*"}
+		settings.NoSniff = false
+		headers := resolveDownloadHeaders(settings, "text/plain", "notes.txt", &yes)
+		assert.Equal(t, `inline; filename="notes.txt"`, headers.ContentDisposition)
+		assert.False(t, headers.NoSniff)
+
+		headers = resolveDownloadHeaders(settings, "image/png", "a.png", &yes)
+		assert.Contains(t, headers.ContentDisposition, "attachment")
+	})
+
+	t.Run("filename is quoted", func(t *testing.T) {
+		headers := resolveDownloadHeaders(defaults, "image/png", `a"b.png`, nil)
+		assert.Equal(t, `attachment; filename="a\"b.png"`, headers.ContentDisposition)
+
+		headers = resolveDownloadHeaders(defaults, "image/png", "résumé.png", nil)
+		assert.Equal(t, `attachment; filename*=utf-8''r%C3%A9sum%C3%A9.png`, headers.ContentDisposition)
+	})
+}
+
+func TestMimeTypeAllowed(t *testing.T) {
+	assert.True(t, mimeTypeAllowed([]string{"image/png"}, "image/png"))
+	assert.True(t, mimeTypeAllowed([]string{"image/*"}, "image/webp"))
+	assert.True(t, mimeTypeAllowed([]string{"*/*"}, "application/zip"))
+	assert.False(t, mimeTypeAllowed([]string{"image/*"}, "imagex/png"))
+	assert.False(t, mimeTypeAllowed(nil, "image/png"))
+}
+
+func TestValidateDownloadSettings(t *testing.T) {
+	inline, sandbox, bogus := DispositionInline, HTMLPolicySandbox, "bogus"
+	assert.NoError(t, validateDownloadSettings(nil, nil, nil))
+	assert.NoError(t, validateDownloadSettings(&inline, &sandbox, []string{"image/*", "application/pdf"}))
+	assert.Error(t, validateDownloadSettings(&bogus, nil, nil))
+	assert.Error(t, validateDownloadSettings(nil, &bogus, nil))
+	assert.Error(t, validateDownloadSettings(nil, nil, []string{"pdf"}))
+}
@@ -23,17 +23,13 @@ func (h *StorageHandler) UploadFile(c fiber.Ctx) error {
 	key := c.Params("*") // Capture the rest of the path
 
 	if bucket == "" || key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "bucket and key are required",
-		})
+		return SendBadRequest(c, "bucket and key are required", ErrCodeMissingField)
 	}
 
 	// H-20: Sanitize the filename/key to prevent path traversal and control characters
 	key = sanitizeFilename(key)
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid filename after sanitization",
-		})
+		return SendBadRequest(c, "invalid filename after sanitization", ErrCodeInvalidInput)
 	}
 
 	// H-19: Check if bucket exists before upload
@@ -45,29 +41,21 @@ func (h *StorageHandler) UploadFile(c fiber.Ctx) error {
 	).Scan(&bucketExists)
 	if err != nil {
 		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to check bucket existence")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to validate bucket",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to validate bucket", ErrCodeOperationFailed)
 	}
 	if !bucketExists {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": fmt.Sprintf("bucket '%s' does not exist", bucket),
-		})
+		return SendNotFound(c, fmt.Sprintf("bucket '%s' does not exist", bucket))
 	}
 
 	// Get file from form data
 	file, err := c.FormFile("file")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "file is required",
-		})
+		return SendBadRequest(c, "file is required", ErrCodeMissingField)
 	}
 
 	// Validate file size against global limit
 	if err := h.storage.ValidateUploadSize(file.Size); err != nil {
-		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendErrorWithCode(c, fiber.StatusRequestEntityTooLarge, err.Error(), ErrCodePayloadTooLarge)
 	}
 
 	// Get bucket settings for additional validation
@@ -80,16 +68,12 @@ func (h *StorageHandler) UploadFile(c fiber.Ctx) error {
 	).Scan(&bucketMaxFileSize, &bucketAllowedMimeTypes)
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to get bucket settings")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to validate bucket settings",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to validate bucket settings", ErrCodeOperationFailed)
 	}
 
 	// Validate file size against bucket-specific limit
 	if bucketMaxFileSize != nil && *bucketMaxFileSize > 0 && file.Size > *bucketMaxFileSize {
-		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
-			"error": fmt.Sprintf("file size %d exceeds bucket maximum of %d bytes", file.Size, *bucketMaxFileSize),
-		})
+		return SendErrorWithCode(c, fiber.StatusRequestEntityTooLarge, fmt.Sprintf("file size %d exceeds bucket maximum of %d bytes", file.Size, *bucketMaxFileSize), ErrCodePayloadTooLarge)
 	}
 
 	// Detect content type early for MIME validation
@@ -100,34 +84,15 @@ func (h *StorageHandler) UploadFile(c fiber.Ctx) error {
 
 	// Validate MIME type against bucket-specific allowed types
 	if len(bucketAllowedMimeTypes) > 0 {
-		mimeAllowed := false
-		for _, allowedType := range bucketAllowedMimeTypes {
-			if allowedType == contentType || allowedType == "*/*" {
-				mimeAllowed = true
-				break
-			}
-			// Support wildcard matching (e.g., "image/*")
-			if strings.HasSuffix(allowedType, "/*") {
-				prefix := strings.TrimSuffix(allowedType, "/*")
-				if strings.HasPrefix(contentType, prefix+"/") {
-					mimeAllowed = true
-					break
-				}
-			}
-		}
-		if !mimeAllowed {
-			return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{
-				"error": fmt.Sprintf("file type %s is not allowed for this bucket", contentType),
-			})
+		if !mimeTypeAllowed(bucketAllowedMimeTypes, contentType) {
+			return SendErrorWithCode(c, fiber.StatusUnsupportedMediaType, fmt.Sprintf("file type %s is not allowed for this bucket", contentType), ErrCodeUnsupportedMediaType)
 		}
 	}
 
 	// Open the uploaded file
 	src, err := file.Open()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to open uploaded file",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to open uploaded file", ErrCodeOperationFailed)
 	}
 	defer func() { _ = src.Close() }()
 
@@ -153,9 +118,7 @@ func (h *StorageHandler) UploadFile(c fiber.Ctx) error {
 	object, err := h.storage.Provider.Upload(ctx, bucket, key, src, file.Size, opts)
 	if err != nil {
 		log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to upload file")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to upload file",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to upload file", ErrCodeOperationFailed)
 	}
 
 	// Start database transaction to store metadata
@@ -164,9 +127,7 @@ func (h *StorageHandler) UploadFile(c fiber.Ctx) error {
 		// Delete from provider since DB insert failed
 		_ = h.storage.Provider.Delete(ctx, bucket, key)
 		log.Error().Err(err).Msg("Failed to start transaction for file upload")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to save file metadata",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to save file metadata", ErrCodeOperationFailed)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
@@ -174,9 +135,7 @@ func (h *StorageHandler) UploadFile(c fiber.Ctx) error {
 	if err := h.setRLSContext(ctx, tx, c); err != nil {
 		_ = h.storage.Provider.Delete(ctx, bucket, key)
 		log.Error().Err(err).Msg("Failed to set RLS context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to save file metadata",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to save file metadata", ErrCodeOperationFailed)
 	}
 
 	// Convert metadata map to JSONB
@@ -212,21 +171,18 @@ func (h *StorageHandler) UploadFile(c fiber.Ctx) error {
 		if strings.Contains(errMsg, "permission denied") || strings.Contains(errMsg, "policy") {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"error":  "insufficient permissions to upload file",
+				"code":   ErrCodeInsufficientPermissions,
 				"detail": errMsg,
 			})
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to save file metadata",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to save file metadata", ErrCodeOperationFailed)
 	}
 
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		_ = h.storage.Provider.Delete(ctx, bucket, key)
 		log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to commit file upload")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to save file metadata",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to save file metadata", ErrCodeOperationFailed)
 	}
 
 	log.Info().
@@ -236,6 +192,10 @@ func (h *StorageHandler) UploadFile(c fiber.Ctx) error {
 		Str("user_id", ownerID).
 		Msg("File uploaded")
 
+	// Uploads replace existing objects, so cached copies are purged
+	h.purgeCDN(bucket, key)
+	h.fireObjectTriggers(c, bucket, key, contentType, object.Size)
+
 	// Add owner_id to response
 	response := map[string]interface{}{
 		"key":           object.Key,
@@ -247,6 +207,9 @@ func (h *StorageHandler) UploadFile(c fiber.Ctx) error {
 	if ownerUUID != nil {
 		response["owner_id"] = *ownerUUID
 	}
+	if publicURL := h.cdnPublicURL(ctx, bucket, key); publicURL != "" {
+		response["public_url"] = publicURL
+	}
 
 	return c.Status(fiber.StatusCreated).JSON(response)
 }
@@ -270,9 +233,7 @@ func (h *StorageHandler) DownloadFile(c fiber.Ctx) error {
 	}
 
 	if bucket == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "bucket is required",
-		})
+		return SendBadRequest(c, "bucket is required", ErrCodeMissingField)
 	}
 
 	ctx := c.RequestCtx()
@@ -281,18 +242,14 @@ func (h *StorageHandler) DownloadFile(c fiber.Ctx) error {
 	tx, err := h.db.Pool().Begin(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to start transaction for file download")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to download file",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to download file", ErrCodeOperationFailed)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
 	// Set RLS context
 	if err := h.setRLSContext(ctx, tx, c); err != nil {
 		log.Error().Err(err).Msg("Failed to set RLS context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to download file",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to download file", ErrCodeOperationFailed)
 	}
 
 	// Check if user has permission to access this file (RLS will filter)
@@ -306,22 +263,16 @@ func (h *StorageHandler) DownloadFile(c fiber.Ctx) error {
 	`, bucket, key).Scan(&objectID, &mimeType, &fileSize)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "file not found or insufficient permissions",
-			})
+			return SendNotFound(c, "file not found or insufficient permissions")
 		}
 		log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to check file permissions")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to download file",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to download file", ErrCodeOperationFailed)
 	}
 
 	// Commit transaction (permission check passed)
 	if err := tx.Commit(ctx); err != nil {
 		log.Error().Err(err).Msg("Failed to commit file download transaction")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to download file",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to download file", ErrCodeOperationFailed)
 	}
 
 	// Parse download options
@@ -332,20 +283,6 @@ func (h *StorageHandler) DownloadFile(c fiber.Ctx) error {
 		opts.Range = rangeHeader
 	}
 
-	// Download the file from provider
-	reader, object, err := h.storage.Provider.Download(ctx, bucket, key, opts)
-	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "file not found",
-			})
-		}
-		log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to download file from provider")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to download file",
-		})
-	}
-
 	// Parse transform options from query parameters
 	transformOpts := storage.ParseTransformOptions(
 		fiber.Query[int](c, "w", fiber.Query[int](c, "width", 0)),
@@ -354,6 +291,25 @@ func (h *StorageHandler) DownloadFile(c fiber.Ctx) error {
 		fiber.Query[int](c, "q", fiber.Query[int](c, "quality", 0)),
 		c.Query("fit", ""),
 	)
+	if transformOpts != nil && h.transformer != nil {
+		if err := h.applyTransformDefaults(transformOpts); err != nil {
+			return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+		}
+		// Buckets with transformations turned off serve the original object
+		if !h.transformsEnabled(ctx, bucket) {
+			transformOpts = nil
+		}
+	}
+
+	// Download the file from provider
+	reader, object, err := h.storage.Provider.Download(ctx, bucket, key, opts)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return SendNotFound(c, "file not found")
+		}
+		log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to download file from provider")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to download file", ErrCodeOperationFailed)
+	}
 
 	// Apply image transformation if enabled and requested
 	responseReader := reader
@@ -365,9 +321,7 @@ func (h *StorageHandler) DownloadFile(c fiber.Ctx) error {
 		limiterKey := c.IP() + ":" + getUserID(c)
 		if limiter := h.getTransformLimiter(limiterKey); limiter != nil && !limiter.Allow() {
 			_ = reader.Close()
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"error": "transform rate limit exceeded",
-			})
+			return SendErrorWithCode(c, fiber.StatusTooManyRequests, "transform rate limit exceeded", ErrCodeRateLimited)
 		}
 
 		// Check cache first (before acquiring transform slot)
@@ -385,9 +339,7 @@ func (h *StorageHandler) DownloadFile(c fiber.Ctx) error {
 		// Acquire transform slot (concurrency limit)
 		if !h.acquireTransformSlot(5 * time.Second) {
 			_ = reader.Close()
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-				"error": "transform service busy, try again later",
-			})
+			return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "transform service busy, try again later", ErrCodeServiceUnavailable)
 		}
 		defer h.releaseTransformSlot()
 
@@ -403,9 +355,7 @@ func (h *StorageHandler) DownloadFile(c fiber.Ctx) error {
 			// Re-download original since we consumed the reader
 			reader, object, err = h.storage.Provider.Download(ctx, bucket, key, opts)
 			if err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "failed to download file",
-				})
+				return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to download file", ErrCodeOperationFailed)
 			}
 			responseReader = reader
 		} else if result != nil {
@@ -424,9 +374,7 @@ func (h *StorageHandler) DownloadFile(c fiber.Ctx) error {
 			// Re-download original since we consumed the reader
 			reader, object, err = h.storage.Provider.Download(ctx, bucket, key, opts)
 			if err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "failed to download file",
-				})
+				return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to download file", ErrCodeOperationFailed)
 			}
 			responseReader = reader
 		}
@@ -435,8 +383,7 @@ func (h *StorageHandler) DownloadFile(c fiber.Ctx) error {
 sendResponse:
 	// Note: Don't defer responseReader.Close() here - SendStream handles closing the reader
 
-	// Set response headers
-	c.Set("Content-Type", responseContentType)
+	// Set response headers (Content-Type is set with the disposition below)
 	c.Set("Content-Length", strconv.FormatInt(responseSize, 10))
 	c.Set("Last-Modified", object.LastModified.Format(time.RFC1123))
 	c.Set("ETag", object.ETag)
@@ -457,8 +404,8 @@ sendResponse:
 		}
 	}
 
-	// Set content disposition - default to attachment for security
-	// Only allow inline for safe MIME types when explicitly requested
+	// Set content disposition - the bucket decides the default and which MIME types may be
+	// displayed inline; HTML-like objects follow the bucket's HTML policy
 	filename := filepath.Base(key)
 	// If format was changed, update the filename extension
 	if transformOpts != nil && transformOpts.Format != "" {
@@ -468,23 +415,11 @@ sendResponse:
 		}
 	}
 
-	disposition := "attachment"
-	if c.Query("inline") == "true" {
-		// Only allow inline for safe content types
-		safeTypes := map[string]bool{
-			"image/jpeg":      true,
-			"image/png":       true,
-			"image/gif":       true,
-			"image/webp":      true,
-			"application/pdf": true,
-			"video/mp4":       true,
-			"audio/mpeg":      true,
-		}
-		if safeTypes[object.ContentType] {
-			disposition = "inline"
-		}
+	settings, err := h.getDownloadSettings(ctx, bucket)
+	if err != nil {
+		log.Warn().Err(err).Str("bucket", bucket).Msg("Failed to get bucket download settings, using defaults")
 	}
-	c.Set("Content-Disposition", fmt.Sprintf("%s; filename=\"%s\"", disposition, filename))
+	resolveDownloadHeaders(settings, responseContentType, filename, downloadPreference(c)).apply(c)
 
 	log.Debug().
 		Str("bucket", bucket).
@@ -507,15 +442,11 @@ func (h *StorageHandler) DeleteFile(c fiber.Ctx) error {
 	key := c.Params("*")
 
 	if bucket == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "bucket is required",
-		})
+		return SendBadRequest(c, "bucket is required", ErrCodeMissingField)
 	}
 
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "key is required",
-		})
+		return SendBadRequest(c, "key is required", ErrCodeMissingField)
 	}
 
 	ctx := c.RequestCtx()
@@ -524,18 +455,14 @@ func (h *StorageHandler) DeleteFile(c fiber.Ctx) error {
 	tx, err := h.db.Pool().Begin(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to start transaction for file deletion")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to delete file",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to delete file", ErrCodeOperationFailed)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
 	// Set RLS context
 	if err := h.setRLSContext(ctx, tx, c); err != nil {
 		log.Error().Err(err).Msg("Failed to set RLS context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to delete file",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to delete file", ErrCodeOperationFailed)
 	}
 
 	// Delete from database (RLS will check permissions)
@@ -545,14 +472,10 @@ func (h *StorageHandler) DeleteFile(c fiber.Ctx) error {
 	`, bucket, key)
 	if err != nil {
 		if strings.Contains(err.Error(), "permission denied") || strings.Contains(err.Error(), "policy") {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "insufficient permissions to delete file",
-			})
+			return SendForbidden(c, "insufficient permissions to delete file", ErrCodeInsufficientPermissions)
 		}
 		log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to delete file from database")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to delete file",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to delete file", ErrCodeOperationFailed)
 	}
 
 	// Check if any rows were affected (file existed and was deleted)
@@ -568,21 +491,15 @@ func (h *StorageHandler) DeleteFile(c fiber.Ctx) error {
 			// If we can't check existence, log it but still return 404
 			// This is safer than returning 500 for a delete operation
 			log.Warn().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to check file existence after delete returned 0 rows")
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "file not found",
-			})
+			return SendNotFound(c, "file not found")
 		}
 
 		if fileExists {
 			// File exists but RLS prevented delete - return 403
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "insufficient permissions to delete file",
-			})
+			return SendForbidden(c, "insufficient permissions to delete file", ErrCodeInsufficientPermissions)
 		}
 		// File doesn't exist at all
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "file not found",
-		})
+		return SendNotFound(c, "file not found")
 	}
 
 	// Delete from storage provider
@@ -600,9 +517,7 @@ func (h *StorageHandler) DeleteFile(c fiber.Ctx) error {
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to commit file deletion")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to delete file",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to delete file", ErrCodeOperationFailed)
 	}
 
 	log.Info().
@@ -611,6 +526,8 @@ func (h *StorageHandler) DeleteFile(c fiber.Ctx) error {
 		Str("user_id", getUserID(c)).
 		Msg("File deleted")
 
+	h.purgeCDN(bucket, key)
+
 	return c.Status(fiber.StatusNoContent).Send(nil)
 }
 
@@ -625,9 +542,7 @@ func (h *StorageHandler) GetFileInfo(c fiber.Ctx) error {
 	}
 
 	if bucket == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "bucket is required",
-		})
+		return SendBadRequest(c, "bucket is required", ErrCodeMissingField)
 	}
 
 	ctx := c.RequestCtx()
@@ -635,17 +550,13 @@ func (h *StorageHandler) GetFileInfo(c fiber.Ctx) error {
 	tx, err := h.db.Pool().Begin(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to start transaction for getting file info")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to get file info",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to get file info", ErrCodeOperationFailed)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
 	if err := h.setRLSContext(ctx, tx, c); err != nil {
 		log.Error().Err(err).Msg("Failed to set RLS context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to get file info",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to get file info", ErrCodeOperationFailed)
 	}
 
 	var id string
@@ -662,21 +573,15 @@ func (h *StorageHandler) GetFileInfo(c fiber.Ctx) error {
 	`, bucket, key).Scan(&id, &mimeType, &size, &metadata, &ownerID, &createdAt, &updatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "file not found or insufficient permissions",
-			})
+			return SendNotFound(c, "file not found or insufficient permissions")
 		}
 		log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to get file metadata")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to get file info",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to get file info", ErrCodeOperationFailed)
 	}
 
 	if err := tx.Commit(ctx); err != nil {
 		log.Error().Err(err).Msg("Failed to commit get file info transaction")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to get file info",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to get file info", ErrCodeOperationFailed)
 	}
 
 	contentType := "application/octet-stream"
@@ -686,7 +591,11 @@ func (h *StorageHandler) GetFileInfo(c fiber.Ctx) error {
 
 	if c.Method() == "HEAD" {
 		log.Debug().Str("bucket", bucket).Str("key", key).Int64("size", size).Msg("HEAD request")
-		c.Response().Header.SetContentType(contentType)
+		settings, err := h.getDownloadSettings(ctx, bucket)
+		if err != nil {
+			log.Warn().Err(err).Str("bucket", bucket).Msg("Failed to get bucket download settings, using defaults")
+		}
+		resolveDownloadHeaders(settings, contentType, filepath.Base(key), downloadPreference(c)).apply(c)
 		c.Response().Header.SetContentLength(int(size))
 		c.Response().Header.Set("Accept-Ranges", "bytes")
 		c.Response().Header.Set("Last-Modified", updatedAt.Format(time.RFC1123))
@@ -722,9 +631,7 @@ func (h *StorageHandler) ListFiles(c fiber.Ctx) error {
 	bucket := c.Params("bucket")
 
 	if bucket == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "bucket is required",
-		})
+		return SendBadRequest(c, "bucket is required", ErrCodeMissingField)
 	}
 
 	prefix := c.Query("prefix", "")
@@ -737,17 +644,13 @@ func (h *StorageHandler) ListFiles(c fiber.Ctx) error {
 	tx, err := h.db.Pool().Begin(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to start transaction for listing files")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to list files",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to list files", ErrCodeOperationFailed)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
 	if err := h.setRLSContext(ctx, tx, c); err != nil {
 		log.Error().Err(err).Msg("Failed to set RLS context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to list files",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to list files", ErrCodeOperationFailed)
 	}
 
 	type StorageObject struct {
@@ -2,12 +2,17 @@ package api
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/cdn"
 	"github.com/nimbleflux/fluxbase/internal/config"
 	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/nimbleflux/fluxbase/internal/functions"
 	"github.com/nimbleflux/fluxbase/internal/storage"
 	"github.com/rs/zerolog/log"
 	"golang.org/x/time/rate"
@@ -17,9 +22,12 @@ import (
 // Methods are split across multiple files:
 // - storage_files.go: UploadFile, DownloadFile, DeleteFile, GetFileInfo, ListFiles
 // - storage_buckets.go: CreateBucket, UpdateBucketSettings, DeleteBucket, ListBuckets
-// - storage_signed.go: GenerateSignedURL, DownloadSignedObject
+// - storage_signed.go: GenerateSignedURL, DownloadSignedObject, UploadSignedObject
 // - storage_multipart.go: MultipartUpload
 // - storage_sharing.go: ShareObject, RevokeShare, ListShares
+// - storage_cdn.go: GetBucketCDN, PutBucketCDN, DeleteBucketCDN, PurgeBucketCDN
+// - storage_lifecycle.go: ListBucketLifecycle, CreateBucketLifecycleRule, UpdateBucketLifecycleRule, ...
+// - storage_s3.go: S3-compatible API (S3Auth, S3ListObjects, S3GetObject, S3PutObject, ...)
 // - storage_utils.go: helper functions (detectContentType, parseMetadata, getUserID, setRLSContext)
 type StorageHandler struct {
 	storage         *storage.Service
@@ -27,6 +35,16 @@ type StorageHandler struct {
 	transformer     *storage.ImageTransformer
 	transformConfig *config.TransformConfig
 	transformCache  *storage.TransformCache
+	cdn             *cdn.Service
+	objectTriggers  *functions.ObjectTriggers
+	s3              *s3API
+	tus             *storage.TUSStore
+	tusConfig       *config.TUSConfig
+	lifecycle       *storage.LifecycleStore
+
+	// Signed upload URLs
+	uploadSigningSecret string
+	publicBaseURL       string
 
 	// Rate limiting for transforms
 	transformLimiters   map[string]*rate.Limiter
@@ -42,8 +60,8 @@ type StorageHandler struct {
 func NewStorageHandler(storageSvc *storage.Service, db *database.Connection, transformCfg *config.TransformConfig) *StorageHandler {
 	var cache *storage.TransformCache
 
-	// Initialize transform cache if transforms are enabled
-	if transformCfg != nil && transformCfg.Enabled && storageSvc != nil {
+	// Initialize transform cache if transforms and caching are enabled
+	if transformCfg != nil && transformCfg.Enabled && transformCfg.CacheEnabled && storageSvc != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
@@ -59,8 +77,15 @@ func NewStorageHandler(storageSvc *storage.Service, db *database.Connection, tra
 			cacheOpts.MaxSize = 1024 * 1024 * 1024 // 1GB
 		}
 
+		// The disk backend keeps variants on local disk instead of the storage provider
+		var provider storage.Provider = storageSvc.Provider
 		var err error
-		cache, err = storage.NewTransformCache(ctx, storageSvc.Provider, cacheOpts)
+		if transformCfg.CacheBackend == "disk" {
+			provider, err = storage.NewLocalStorage(transformCfg.CacheDir, "", "")
+		}
+		if err == nil {
+			cache, err = storage.NewTransformCache(ctx, provider, cacheOpts)
+		}
 		if err != nil {
 			// Log error but don't fail - transforms will work without caching
 			log.Warn().Err(err).Msg("Failed to initialize transform cache, transforms will not be cached")
@@ -160,6 +185,43 @@ func (h *StorageHandler) releaseTransformSlot() {
 	}
 }
 
+// applyTransformDefaults fills in the configured default quality and checks the output
+// format against the allowed formats
+func (h *StorageHandler) applyTransformDefaults(opts *storage.TransformOptions) error {
+	if opts == nil || h.transformConfig == nil {
+		return nil
+	}
+	if opts.Quality == 0 && h.transformConfig.DefaultQuality > 0 {
+		opts.Quality = h.transformConfig.DefaultQuality
+	}
+	if opts.Format != "" && len(h.transformConfig.AllowedFormats) > 0 {
+		format := strings.ToLower(opts.Format)
+		if format == "jpeg" {
+			format = "jpg"
+		}
+		if !slices.Contains(h.transformConfig.AllowedFormats, format) {
+			return fmt.Errorf("output format %q is not allowed", opts.Format)
+		}
+	}
+	return nil
+}
+
+// transformsEnabled reports whether a bucket allows image transformations. Lookup
+// failures serve the original object.
+func (h *StorageHandler) transformsEnabled(ctx context.Context, bucket string) bool {
+	if h.db == nil {
+		return true
+	}
+
+	var enabled bool
+	err := h.db.Pool().QueryRow(ctx, `SELECT storage.bucket_transformations_enabled($1)`, bucket).Scan(&enabled)
+	if err != nil {
+		log.Warn().Err(err).Str("bucket", bucket).Msg("Failed to check bucket transformations, serving original")
+		return false
+	}
+	return enabled
+}
+
 // TransformConfigResponse represents the response for the transform config endpoint
 type TransformConfigResponse struct {
 	Enabled        bool     `json:"enabled"`
@@ -3,6 +3,7 @@ package api
 import (
 	"io"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -82,6 +83,79 @@ func TestNewStorageHandler_DefaultRateLimit(t *testing.T) {
 	assert.NotZero(t, handler.transformRateLimit)
 }
 
+func TestNewStorageHandler_DiskTransformCache(t *testing.T) {
+	storageSvc, err := storage.NewService(&config.StorageConfig{
+		Provider:  "local",
+		LocalPath: t.TempDir(),
+	}, "http://localhost:8080", "test-signing-secret")
+	require.NoError(t, err)
+
+	cacheDir := filepath.Join(t.TempDir(), "transform-cache")
+	cfg := &config.TransformConfig{
+		Enabled:      true,
+		CacheEnabled: true,
+		CacheBackend: "disk",
+		CacheDir:     cacheDir,
+	}
+
+	handler := NewStorageHandler(storageSvc, nil, cfg)
+
+	require.NotNil(t, handler.transformCache)
+	assert.DirExists(t, filepath.Join(cacheDir, storage.TransformCacheBucket))
+}
+
+func TestNewStorageHandler_TransformCacheDisabled(t *testing.T) {
+	storageSvc, err := storage.NewService(&config.StorageConfig{
+		Provider:  "local",
+		LocalPath: t.TempDir(),
+	}, "http://localhost:8080", "test-signing-secret")
+	require.NoError(t, err)
+
+	handler := NewStorageHandler(storageSvc, nil, &config.TransformConfig{Enabled: true})
+
+	assert.NotNil(t, handler.transformer)
+	assert.Nil(t, handler.transformCache)
+}
+
+// =============================================================================
+// applyTransformDefaults Tests
+// =============================================================================
+
+func TestStorageHandler_applyTransformDefaults(t *testing.T) {
+	handler := NewStorageHandlerWithCache(nil, nil, &config.TransformConfig{
+		Enabled:        true,
+		DefaultQuality: 75,
+		AllowedFormats: []string{"webp", "jpg"},
+	}, nil)
+
+	t.Run("fills in the default quality", func(t *testing.T) {
+		opts := &storage.TransformOptions{Width: 100}
+		require.NoError(t, handler.applyTransformDefaults(opts))
+		assert.Equal(t, 75, opts.Quality)
+	})
+
+	t.Run("keeps the requested quality", func(t *testing.T) {
+		opts := &storage.TransformOptions{Width: 100, Quality: 90}
+		require.NoError(t, handler.applyTransformDefaults(opts))
+		assert.Equal(t, 90, opts.Quality)
+	})
+
+	t.Run("allows listed formats", func(t *testing.T) {
+		require.NoError(t, handler.applyTransformDefaults(&storage.TransformOptions{Format: "WEBP"}))
+		require.NoError(t, handler.applyTransformDefaults(&storage.TransformOptions{Format: "jpeg"}))
+	})
+
+	t.Run("rejects unlisted formats", func(t *testing.T) {
+		err := handler.applyTransformDefaults(&storage.TransformOptions{Format: "avif"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not allowed")
+	})
+
+	t.Run("nil options", func(t *testing.T) {
+		assert.NoError(t, handler.applyTransformDefaults(nil))
+	})
+}
+
 // =============================================================================
 // getTransformLimiter Tests
 // =============================================================================
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// maxLifecycleDryRunObjects caps the objects listed by a lifecycle dry run
+const maxLifecycleDryRunObjects = 1000
+
+// lifecycleRuleRequest is the body of lifecycle rule create and update requests. Fields
+// left out of an update keep their value.
+type lifecycleRuleRequest struct {
+	Name         *string `json:"name"`
+	Prefix       *string `json:"prefix"`
+	Action       *string `json:"action"`
+	AgeDays      *int    `json:"age_days"`
+	TargetBucket *string `json:"target_bucket"`
+	Enabled      *bool   `json:"enabled"`
+}
+
+// apply sets the fields present in the request on the rule
+func (req *lifecycleRuleRequest) apply(rule *storage.LifecycleRule) {
+	if req.Name != nil {
+		rule.Name = *req.Name
+	}
+	if req.Prefix != nil {
+		rule.Prefix = *req.Prefix
+	}
+	if req.Action != nil {
+		rule.Action = storage.LifecycleAction(*req.Action)
+	}
+	if req.AgeDays != nil {
+		rule.AgeDays = *req.AgeDays
+	}
+	if req.TargetBucket != nil {
+		rule.TargetBucket = *req.TargetBucket
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+}
+
+// SetLifecycle enables lifecycle rule management
+func (h *StorageHandler) SetLifecycle(store *storage.LifecycleStore) {
+	h.lifecycle = store
+}
+
+// sendLifecycleError answers a failed lifecycle rule operation
+func sendLifecycleError(c fiber.Ctx, err error, bucket, action string) error {
+	switch {
+	case errors.Is(err, storage.ErrLifecycleRuleNotFound):
+		return SendNotFound(c, "lifecycle rule not found")
+	case errors.Is(err, storage.ErrLifecycleBucketNotFound):
+		return SendNotFound(c, "bucket not found")
+	case errors.Is(err, storage.ErrLifecycleRuleExists):
+		return SendConflict(c, err.Error(), ErrCodeConflict)
+	}
+	log.Error().Err(err).Str("bucket", bucket).Msg("Failed to " + action + " lifecycle rule")
+	return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to "+action+" lifecycle rule", ErrCodeOperationFailed)
+}
+
+// ListBucketLifecycle lists the lifecycle rules of a bucket
+// GET /api/v1/storage/buckets/:bucket/lifecycle
+func (h *StorageHandler) ListBucketLifecycle(c fiber.Ctx) error {
+	if !isStorageAdmin(c) {
+		return SendForbidden(c, "Admin access required to manage lifecycle rules", ErrCodeAdminRequired)
+	}
+	if h.lifecycle == nil {
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "storage lifecycle rules are not available", ErrCodeServiceUnavailable)
+	}
+
+	bucket := c.Params("bucket")
+	rules, err := h.lifecycle.List(c.RequestCtx(), bucket)
+	if err != nil {
+		return sendLifecycleError(c, err, bucket, "list")
+	}
+
+	return c.JSON(fiber.Map{
+		"rules": rules,
+	})
+}
+
+// CreateBucketLifecycleRule adds a rule deleting or archiving old objects of a bucket
+// POST /api/v1/storage/buckets/:bucket/lifecycle {"name": "expire-logs", "prefix": "logs/", "action": "delete", "age_days": 30}
+func (h *StorageHandler) CreateBucketLifecycleRule(c fiber.Ctx) error {
+	if !isStorageAdmin(c) {
+		return SendForbidden(c, "Admin access required to manage lifecycle rules", ErrCodeAdminRequired)
+	}
+	if h.lifecycle == nil {
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "storage lifecycle rules are not available", ErrCodeServiceUnavailable)
+	}
+
+	var req lifecycleRuleRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendBadRequest(c, "invalid request body", ErrCodeInvalidBody)
+	}
+
+	rule := &storage.LifecycleRule{
+		Bucket:    c.Params("bucket"),
+		Enabled:   true,
+		CreatedBy: getUserIDFromContext(c),
+	}
+	req.apply(rule)
+	if err := rule.Validate(); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	if err := h.lifecycle.Create(c.RequestCtx(), rule); err != nil {
+		return sendLifecycleError(c, err, rule.Bucket, "create")
+	}
+
+	log.Info().
+		Str("bucket", rule.Bucket).
+		Str("rule", rule.Name).
+		Str("action", string(rule.Action)).
+		Int("age_days", rule.AgeDays).
+		Str("user_id", getUserID(c)).
+		Msg("Storage lifecycle rule created")
+
+	return c.Status(fiber.StatusCreated).JSON(rule)
+}
+
+// UpdateBucketLifecycleRule changes a lifecycle rule
+// PUT /api/v1/storage/buckets/:bucket/lifecycle/:id {"age_days": 90, "enabled": false}
+func (h *StorageHandler) UpdateBucketLifecycleRule(c fiber.Ctx) error {
+	if !isStorageAdmin(c) {
+		return SendForbidden(c, "Admin access required to manage lifecycle rules", ErrCodeAdminRequired)
+	}
+	if h.lifecycle == nil {
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "storage lifecycle rules are not available", ErrCodeServiceUnavailable)
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return SendBadRequest(c, "invalid lifecycle rule ID", ErrCodeInvalidID)
+	}
+	var req lifecycleRuleRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendBadRequest(c, "invalid request body", ErrCodeInvalidBody)
+	}
+
+	bucket := c.Params("bucket")
+	rule, err := h.lifecycle.Get(c.RequestCtx(), bucket, id)
+	if err != nil {
+		return sendLifecycleError(c, err, bucket, "get")
+	}
+	req.apply(rule)
+	// Switching to delete drops the archive target
+	if rule.Action == storage.LifecycleActionDelete && req.TargetBucket == nil {
+		rule.TargetBucket = ""
+	}
+	if err := rule.Validate(); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	if err := h.lifecycle.Update(c.RequestCtx(), rule); err != nil {
+		return sendLifecycleError(c, err, bucket, "update")
+	}
+
+	return c.JSON(rule)
+}
+
+// DeleteBucketLifecycleRule removes a lifecycle rule from a bucket
+// DELETE /api/v1/storage/buckets/:bucket/lifecycle/:id
+func (h *StorageHandler) DeleteBucketLifecycleRule(c fiber.Ctx) error {
+	if !isStorageAdmin(c) {
+		return SendForbidden(c, "Admin access required to manage lifecycle rules", ErrCodeAdminRequired)
+	}
+	if h.lifecycle == nil {
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "storage lifecycle rules are not available", ErrCodeServiceUnavailable)
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return SendBadRequest(c, "invalid lifecycle rule ID", ErrCodeInvalidID)
+	}
+
+	bucket := c.Params("bucket")
+	if err := h.lifecycle.Delete(c.RequestCtx(), bucket, id); err != nil {
+		return sendLifecycleError(c, err, bucket, "delete")
+	}
+
+	return c.Status(fiber.StatusNoContent).Send(nil)
+}
+
+// DryRunBucketLifecycleRule lists the objects a lifecycle rule would delete or archive now,
+// without changing anything. Disabled rules can be dry-run too, to check a rule before
+// enabling it.
+// POST /api/v1/storage/buckets/:bucket/lifecycle/:id/dry-run?limit=100
+func (h *StorageHandler) DryRunBucketLifecycleRule(c fiber.Ctx) error {
+	if !isStorageAdmin(c) {
+		return SendForbidden(c, "Admin access required to manage lifecycle rules", ErrCodeAdminRequired)
+	}
+	if h.lifecycle == nil {
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "storage lifecycle rules are not available", ErrCodeServiceUnavailable)
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return SendBadRequest(c, "invalid lifecycle rule ID", ErrCodeInvalidID)
+	}
+	limit := fiber.Query[int](c, "limit", 100)
+	if limit <= 0 || limit > maxLifecycleDryRunObjects {
+		return SendBadRequest(c, "limit must be between 1 and 1000", ErrCodeInvalidInput)
+	}
+
+	ctx := c.RequestCtx()
+	bucket := c.Params("bucket")
+	rule, err := h.lifecycle.Get(ctx, bucket, id)
+	if err != nil {
+		return sendLifecycleError(c, err, bucket, "get")
+	}
+
+	now := time.Now()
+	count, size, err := h.lifecycle.CountDue(ctx, rule, now)
+	if err != nil {
+		return sendLifecycleError(c, err, bucket, "dry-run")
+	}
+	objects, err := h.lifecycle.Due(ctx, rule, now, limit)
+	if err != nil {
+		return sendLifecycleError(c, err, bucket, "dry-run")
+	}
+
+	return c.JSON(fiber.Map{
+		"rule":         rule,
+		"cutoff":       rule.Cutoff(now),
+		"object_count": count,
+		"total_size":   size,
+		"objects":      objects,
+		"truncated":    int64(len(objects)) < count,
+	})
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withTestLifecycle configures a handler with lifecycle rules
+func withTestLifecycle(h *StorageHandler) {
+	h.SetLifecycle(storage.NewLifecycleStore(nil))
+}
+
+func TestStorageLifecycle_RequiresAdmin(t *testing.T) {
+	assertStorageAdminOnly(t, "/buckets/logs/lifecycle", withTestLifecycle)
+}
+
+func TestStorageLifecycle_ValidatesRequests(t *testing.T) {
+	app := newStorageAdminTestApp("service_role", withTestLifecycle)
+	ruleID := "5f0e8c1a-3b2d-4c6e-9a7f-1d2e3f4a5b6c"
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		msg    string
+	}{
+		{"missing name", "POST", "/buckets/logs/lifecycle", `{"action":"delete","age_days":30}`, "name is required"},
+		{"missing age", "POST", "/buckets/logs/lifecycle", `{"name":"expire","action":"delete"}`, "age_days must be positive"},
+		{"unknown action", "POST", "/buckets/logs/lifecycle", `{"name":"expire","action":"glacier","age_days":30}`, "invalid action"},
+		{"archive without target", "POST", "/buckets/logs/lifecycle", `{"name":"archive","action":"archive","age_days":30}`, "target_bucket is required"},
+		{"archive into same bucket", "POST", "/buckets/logs/lifecycle", `{"name":"archive","action":"archive","age_days":30,"target_bucket":"logs"}`, "must differ"},
+		{"invalid rule ID on update", "PUT", "/buckets/logs/lifecycle/abc", `{"age_days":90}`, "invalid lifecycle rule ID"},
+		{"invalid rule ID on delete", "DELETE", "/buckets/logs/lifecycle/abc", ``, "invalid lifecycle rule ID"},
+		{"dry run limit too large", "POST", "/buckets/logs/lifecycle/" + ruleID + "/dry-run?limit=5000", ``, "limit must be between 1 and 1000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			body := new(strings.Builder)
+			_, _ = io.Copy(body, resp.Body)
+			assert.Contains(t, body.String(), tt.msg)
+		})
+	}
+}
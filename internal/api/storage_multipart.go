@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"mime/multipart"
-	"strings"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/jackc/pgx/v5"
@@ -18,9 +17,7 @@ func (h *StorageHandler) MultipartUpload(c fiber.Ctx) error {
 	bucket := c.Params("bucket")
 
 	if bucket == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "bucket is required",
-		})
+		return SendBadRequest(c, "bucket is required", ErrCodeMissingField)
 	}
 
 	// H-19: Check if bucket exists before upload
@@ -32,14 +29,10 @@ func (h *StorageHandler) MultipartUpload(c fiber.Ctx) error {
 	).Scan(&bucketExists)
 	if err != nil {
 		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to check bucket existence")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to validate bucket",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to validate bucket", ErrCodeOperationFailed)
 	}
 	if !bucketExists {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": fmt.Sprintf("bucket '%s' does not exist", bucket),
-		})
+		return SendNotFound(c, fmt.Sprintf("bucket '%s' does not exist", bucket))
 	}
 
 	// C-3: Get bucket MIME type settings
@@ -51,24 +44,18 @@ func (h *StorageHandler) MultipartUpload(c fiber.Ctx) error {
 	).Scan(&bucketAllowedMimeTypes)
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to get bucket settings")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to validate bucket settings",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to validate bucket settings", ErrCodeOperationFailed)
 	}
 
 	// Parse multipart form
 	form, err := c.MultipartForm()
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "failed to parse multipart form",
-		})
+		return SendBadRequest(c, "failed to parse multipart form", ErrCodeInvalidInput)
 	}
 
 	files := form.File["files"]
 	if len(files) == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "no files provided",
-		})
+		return SendBadRequest(c, "no files provided", ErrCodeInvalidInput)
 	}
 
 	var uploaded []storage.Object
@@ -99,22 +86,7 @@ func (h *StorageHandler) MultipartUpload(c fiber.Ctx) error {
 
 		// C-3: Validate MIME type against bucket-specific allowed types
 		if len(bucketAllowedMimeTypes) > 0 {
-			mimeAllowed := false
-			for _, allowedType := range bucketAllowedMimeTypes {
-				if allowedType == contentType || allowedType == "*/*" {
-					mimeAllowed = true
-					break
-				}
-				// Support wildcard matching (e.g., "image/*")
-				if strings.HasSuffix(allowedType, "/*") {
-					prefix := strings.TrimSuffix(allowedType, "/*")
-					if strings.HasPrefix(contentType, prefix+"/") {
-						mimeAllowed = true
-						break
-					}
-				}
-			}
-			if !mimeAllowed {
+			if !mimeTypeAllowed(bucketAllowedMimeTypes, contentType) {
 				errors = append(errors, fmt.Sprintf("%s: file type %s is not allowed for this bucket", file.Filename, contentType))
 				continue
 			}
@@ -127,12 +99,22 @@ func (h *StorageHandler) MultipartUpload(c fiber.Ctx) error {
 		}
 
 		uploaded = append(uploaded, storage.Object{
-			Key:    key,
-			Bucket: bucket,
-			Size:   file.Size,
+			Key:         key,
+			Bucket:      bucket,
+			Size:        file.Size,
+			ContentType: contentType,
 		})
 	}
 
+	keys := make([]string, len(uploaded))
+	for i, object := range uploaded {
+		keys[i] = object.Key
+	}
+	h.purgeCDN(bucket, keys...)
+	for _, object := range uploaded {
+		h.fireObjectTriggers(c, bucket, object.Key, object.ContentType, object.Size)
+	}
+
 	response := fiber.Map{
 		"uploaded": uploaded,
 		"count":    len(uploaded),
@@ -0,0 +1,916 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/nimbleflux/fluxbase/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// s3Namespace is the XML namespace of S3 responses
+	s3Namespace = "http://s3.amazonaws.com/doc/2006-03-01/"
+	// s3DefaultRegion is reported to clients, any region is accepted in signatures
+	s3DefaultRegion = "us-east-1"
+	// s3MaxKeys is the most keys returned by one list request
+	s3MaxKeys = 1000
+	// s3ListBatchSize is how many rows a listing reads from the database at a time
+	s3ListBatchSize = 1000
+	// s3TimeFormat is the timestamp format of S3 XML responses
+	s3TimeFormat = "2006-01-02T15:04:05.000Z"
+)
+
+// s3UnsupportedSubresources are S3 operations the facade doesn't implement
+var s3UnsupportedSubresources = []string{
+	"acl", "cors", "delete", "lifecycle", "policy", "tagging", "uploads", "uploadId", "versioning", "versions", "website",
+}
+
+// s3API authenticates S3-compatible requests with credentials derived from client keys
+type s3API struct {
+	lookupKey     func(ctx context.Context, id uuid.UUID) (*auth.ClientKey, error)
+	signingSecret string
+	endpoint      string
+	now           func() time.Time
+}
+
+// SetS3 enables the S3-compatible API. Access keys are derived from client keys with the
+// signing secret, endpoint is the public URL of the API.
+func (h *StorageHandler) SetS3(clientKeys *auth.ClientKeyService, signingSecret, endpoint string) {
+	h.s3 = &s3API{
+		lookupKey:     clientKeys.ValidateClientKeyByID,
+		signingSecret: signingSecret,
+		endpoint:      endpoint,
+		now:           time.Now,
+	}
+}
+
+// s3ErrorResponse is the body of S3 errors
+type s3ErrorResponse struct {
+	XMLName  xml.Name `xml:"Error"`
+	Code     string   `xml:"Code"`
+	Message  string   `xml:"Message"`
+	Resource string   `xml:"Resource,omitempty"`
+}
+
+// s3Error sends an S3 error, which clients parse by its code
+func s3Error(c fiber.Ctx, status int, code, message string) error {
+	return c.Status(status).XML(s3ErrorResponse{Code: code, Message: message, Resource: c.Path()})
+}
+
+// s3SigV4Request adapts a request for signature verification
+func s3SigV4Request(c fiber.Ctx) storage.SigV4Request {
+	path := string(c.Request().URI().PathOriginal())
+	if decoded, err := url.PathUnescape(path); err == nil {
+		path = decoded
+	}
+	query, _ := url.ParseQuery(string(c.Request().URI().QueryString()))
+
+	return storage.SigV4Request{
+		Method: c.Method(),
+		Path:   path,
+		Query:  query,
+		Header: func(name string) string {
+			if strings.EqualFold(name, "host") {
+				return string(c.Request().Host())
+			}
+			return c.Get(name)
+		},
+	}
+}
+
+// S3Auth authenticates requests signed with the S3 credentials of a client key. The request
+// then runs as the client key, the same as requests sending the key itself. Unsigned requests
+// keep the authentication of the storage routes, so anonymous ones only reach public buckets.
+func (h *StorageHandler) S3Auth(c fiber.Ctx) error {
+	req := s3SigV4Request(c)
+	if !storage.IsSigV4Request(req) {
+		return c.Next()
+	}
+	if h.s3 == nil {
+		return s3Error(c, fiber.StatusNotImplemented, "NotImplemented", "the S3 API is not enabled")
+	}
+	if c.Locals("auth_type") != nil {
+		return s3Error(c, fiber.StatusBadRequest, "InvalidRequest", "only one authentication method may be used")
+	}
+
+	sig, err := storage.ParseSigV4(req)
+	if err != nil {
+		code := "AuthorizationHeaderMalformed"
+		if req.Header("Authorization") == "" {
+			code = "AuthorizationQueryParametersError"
+		}
+		return s3Error(c, fiber.StatusBadRequest, code, err.Error())
+	}
+	if strings.HasPrefix(sig.PayloadHash, "STREAMING-") {
+		return s3Error(c, fiber.StatusNotImplemented, "NotImplemented",
+			"aws-chunked uploads are not supported, sign the payload or send it unsigned")
+	}
+
+	keyID, err := auth.ParseS3AccessKeyID(sig.AccessKeyID)
+	if err != nil {
+		return s3Error(c, fiber.StatusForbidden, "InvalidAccessKeyId", "the access key ID does not exist")
+	}
+	key, err := h.s3.lookupKey(c.RequestCtx(), keyID)
+	if errors.Is(err, auth.ErrUserClientKeysDisabled) {
+		return s3Error(c, fiber.StatusForbidden, "AccessDenied", "user client keys are disabled")
+	}
+	if err != nil {
+		log.Debug().Err(err).Str("access_key_id", sig.AccessKeyID).Msg("Invalid S3 access key")
+		return s3Error(c, fiber.StatusForbidden, "InvalidAccessKeyId", "the access key ID does not exist")
+	}
+
+	creds := auth.DeriveS3Credentials(h.s3.signingSecret, key)
+	switch err := sig.Verify(req, creds.SecretAccessKey, h.s3.now()); {
+	case errors.Is(err, storage.ErrSigV4Skewed):
+		return s3Error(c, fiber.StatusForbidden, "RequestTimeTooSkewed", "the difference between the request time and the server's time is too large")
+	case errors.Is(err, storage.ErrSigV4Expired):
+		return s3Error(c, fiber.StatusForbidden, "AccessDenied", "request has expired")
+	case err != nil:
+		return s3Error(c, fiber.StatusForbidden, "SignatureDoesNotMatch", "the request signature does not match the signature calculated with the access key")
+	}
+
+	if !key.AllowsIP(c.IP()) {
+		return s3Error(c, fiber.StatusForbidden, "AccessDenied", "the access key cannot be used from this IP address")
+	}
+
+	c.Locals("client_key_id", key.ID)
+	c.Locals("client_key_name", key.Name)
+	c.Locals("client_key_scopes", key.Scopes)
+	c.Locals("client_key_restrictions", key.ClientKeyRestrictions)
+	if key.AllowedNamespaces != nil {
+		c.Locals("allowed_namespaces", key.AllowedNamespaces)
+	}
+	c.Locals("auth_type", "clientkey")
+	if key.UserID != nil {
+		c.Locals("user_id", key.UserID.String())
+		c.Locals("rls_user_id", key.UserID.String())
+		c.Locals("rls_role", "authenticated")
+	}
+	c.Locals("s3_payload_hash", sig.PayloadHash)
+
+	return c.Next()
+}
+
+// s3RequireScope checks the scopes of client and service keys, like middleware.RequireScope
+// but with S3 errors
+func s3RequireScope(scope string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		var scopes []string
+		switch c.Locals("auth_type") {
+		case "clientkey":
+			scopes, _ = c.Locals("client_key_scopes").([]string)
+		case "service_key":
+			scopes, _ = c.Locals("service_key_scopes").([]string)
+		default:
+			return c.Next()
+		}
+		if !slices.Contains(scopes, scope) && !slices.Contains(scopes, "*") {
+			return s3Error(c, fiber.StatusForbidden, "AccessDenied", fmt.Sprintf("the access key is missing the %s scope", scope))
+		}
+		restrictions, _ := c.Locals("client_key_restrictions").(auth.ClientKeyRestrictions)
+		if bucket := c.Params("bucket"); bucket != "" && !restrictions.AllowsBucket(bucket) {
+			return s3Error(c, fiber.StatusForbidden, "AccessDenied", fmt.Sprintf("the access key cannot access bucket %s", bucket))
+		}
+		return c.Next()
+	}
+}
+
+// s3Unsupported rejects requests for S3 operations the facade doesn't implement
+func s3Unsupported(c fiber.Ctx) error {
+	args := c.Request().URI().QueryArgs()
+	for _, name := range s3UnsupportedSubresources {
+		if args.Has(name) {
+			return s3Error(c, fiber.StatusNotImplemented, "NotImplemented", fmt.Sprintf("the %s operation is not supported", name))
+		}
+	}
+	return c.Next()
+}
+
+// GetS3Credentials returns the S3 access key pair of the calling client key
+// GET /api/v1/storage/s3-credentials (X-Client-Key)
+func (h *StorageHandler) GetS3Credentials(c fiber.Ctx) error {
+	if h.s3 == nil {
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "the S3 API is not enabled", ErrCodeFeatureDisabled)
+	}
+	keyID, ok := c.Locals("client_key_id").(uuid.UUID)
+	if !ok || c.Locals("auth_type") != "clientkey" {
+		return SendBadRequest(c, "S3 credentials are derived from a client key, authenticate with the X-Client-Key header", ErrCodeInvalidInput)
+	}
+
+	key, err := h.s3.lookupKey(c.RequestCtx(), keyID)
+	if err != nil {
+		return SendUnauthorized(c, "invalid client key", ErrCodeInvalidToken)
+	}
+
+	creds := auth.DeriveS3Credentials(h.s3.signingSecret, key)
+	c.Set("Cache-Control", "no-store")
+	return c.JSON(fiber.Map{
+		"access_key_id":     creds.AccessKeyID,
+		"secret_access_key": creds.SecretAccessKey,
+		"endpoint":          h.s3.endpoint,
+		"region":            s3DefaultRegion,
+		"force_path_style":  true,
+	})
+}
+
+// s3WithRLS runs fn in a transaction under the caller's RLS context
+func (h *StorageHandler) s3WithRLS(c fiber.Ctx, fn func(ctx context.Context, tx pgx.Tx) error) error {
+	ctx := c.RequestCtx()
+	tx, err := h.db.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := h.setRLSContext(ctx, tx, c); err != nil {
+		return err
+	}
+	if err := fn(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// s3BucketExists checks a bucket exists regardless of the caller's access to it
+func (h *StorageHandler) s3BucketExists(ctx context.Context, bucket string) (bool, error) {
+	var exists bool
+	err := h.db.Pool().QueryRow(ctx, `SELECT storage.bucket_exists($1)`, bucket).Scan(&exists)
+	return exists, err
+}
+
+// s3ObjectKey returns the decoded object key of a path-style request
+func s3ObjectKey(c fiber.Ctx) (string, bool) {
+	key, err := url.PathUnescape(c.Params("*"))
+	return key, err == nil
+}
+
+type s3Owner struct {
+	ID string `xml:"ID"`
+}
+
+type s3Bucket struct {
+	Name         string `xml:"Name"`
+	CreationDate string `xml:"CreationDate"`
+}
+
+type s3ListAllMyBucketsResult struct {
+	XMLName xml.Name   `xml:"ListAllMyBucketsResult"`
+	Xmlns   string     `xml:"xmlns,attr"`
+	Owner   s3Owner    `xml:"Owner"`
+	Buckets []s3Bucket `xml:"Buckets>Bucket"`
+}
+
+// S3ListBuckets lists the buckets the caller can see
+// GET /api/v1/storage/s3
+func (h *StorageHandler) S3ListBuckets(c fiber.Ctx) error {
+	result := s3ListAllMyBucketsResult{Xmlns: s3Namespace, Owner: s3Owner{ID: getUserID(c)}, Buckets: []s3Bucket{}}
+
+	err := h.s3WithRLS(c, func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `SELECT id, created_at FROM storage.buckets ORDER BY id`)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var name string
+			var createdAt time.Time
+			if err := rows.Scan(&name, &createdAt); err != nil {
+				return err
+			}
+			result.Buckets = append(result.Buckets, s3Bucket{Name: name, CreationDate: createdAt.UTC().Format(s3TimeFormat)})
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list buckets for S3")
+		return s3Error(c, fiber.StatusInternalServerError, "InternalError", "failed to list buckets")
+	}
+
+	return c.XML(result)
+}
+
+// S3HeadBucket checks a bucket exists
+// HEAD /api/v1/storage/s3/:bucket
+func (h *StorageHandler) S3HeadBucket(c fiber.Ctx) error {
+	exists, err := h.s3BucketExists(c.RequestCtx(), c.Params("bucket"))
+	if err != nil {
+		log.Error().Err(err).Str("bucket", c.Params("bucket")).Msg("Failed to check bucket existence")
+		return s3Error(c, fiber.StatusInternalServerError, "InternalError", "failed to check bucket")
+	}
+	if !exists {
+		return s3Error(c, fiber.StatusNotFound, "NoSuchBucket", "the specified bucket does not exist")
+	}
+	c.Set("x-amz-bucket-region", s3DefaultRegion)
+	return c.SendStatus(fiber.StatusOK)
+}
+
+type s3ListObject struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	Size         int64  `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type s3CommonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type s3ListBucketResult struct {
+	XMLName               xml.Name         `xml:"ListBucketResult"`
+	Xmlns                 string           `xml:"xmlns,attr"`
+	Name                  string           `xml:"Name"`
+	Prefix                string           `xml:"Prefix"`
+	Delimiter             string           `xml:"Delimiter,omitempty"`
+	Marker                *string          `xml:"Marker"`
+	NextMarker            string           `xml:"NextMarker,omitempty"`
+	StartAfter            string           `xml:"StartAfter,omitempty"`
+	ContinuationToken     string           `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string           `xml:"NextContinuationToken,omitempty"`
+	KeyCount              *int             `xml:"KeyCount"`
+	MaxKeys               int              `xml:"MaxKeys"`
+	EncodingType          string           `xml:"EncodingType,omitempty"`
+	IsTruncated           bool             `xml:"IsTruncated"`
+	Contents              []s3ListObject   `xml:"Contents"`
+	CommonPrefixes        []s3CommonPrefix `xml:"CommonPrefixes"`
+}
+
+// s3Listing collects one page of a listing. Keys must be added in order; with a delimiter,
+// keys containing it after the prefix are rolled up into common prefixes.
+type s3Listing struct {
+	prefix    string
+	delimiter string
+	maxKeys   int
+
+	contents       []s3ListObject
+	commonPrefixes []string
+	truncated      bool
+	// last is the last key or common prefix listed, where the next page continues
+	last string
+}
+
+// add lists the next key, reporting false once the page is full
+func (l *s3Listing) add(obj s3ListObject) bool {
+	entry := obj.Key
+	isPrefix := false
+	if l.delimiter != "" {
+		if i := strings.Index(obj.Key[len(l.prefix):], l.delimiter); i >= 0 {
+			entry = obj.Key[:len(l.prefix)+i+len(l.delimiter)]
+			isPrefix = true
+			// The keys of a common prefix are consecutive, including those of the common
+			// prefix a previous page ended with
+			if entry == l.last {
+				return true
+			}
+		}
+	}
+
+	if len(l.contents)+len(l.commonPrefixes) >= l.maxKeys {
+		l.truncated = true
+		return false
+	}
+	if isPrefix {
+		l.commonPrefixes = append(l.commonPrefixes, entry)
+	} else {
+		l.contents = append(l.contents, obj)
+	}
+	l.last = entry
+	return true
+}
+
+// S3ListObjects lists the objects of a bucket the caller can read, with ListObjectsV2
+// (list-type=2) or the original ListObjects. GetBucketLocation is answered here too.
+// GET /api/v1/storage/s3/:bucket
+func (h *StorageHandler) S3ListObjects(c fiber.Ctx) error {
+	bucket := c.Params("bucket")
+	args := c.Request().URI().QueryArgs()
+	ctx := c.RequestCtx()
+
+	exists, err := h.s3BucketExists(ctx, bucket)
+	if err != nil {
+		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to check bucket existence")
+		return s3Error(c, fiber.StatusInternalServerError, "InternalError", "failed to list objects")
+	}
+	if !exists {
+		return s3Error(c, fiber.StatusNotFound, "NoSuchBucket", "the specified bucket does not exist")
+	}
+
+	if args.Has("location") {
+		return c.XML(struct {
+			XMLName xml.Name `xml:"LocationConstraint"`
+			Xmlns   string   `xml:"xmlns,attr"`
+		}{Xmlns: s3Namespace})
+	}
+
+	v2 := c.Query("list-type") == "2"
+	urlEncoded := c.Query("encoding-type") == "url"
+	result := s3ListBucketResult{
+		Xmlns:     s3Namespace,
+		Name:      bucket,
+		Prefix:    c.Query("prefix"),
+		Delimiter: c.Query("delimiter"),
+		MaxKeys:   s3MaxKeys,
+	}
+	if urlEncoded {
+		result.EncodingType = "url"
+	}
+	if maxKeys := c.Query("max-keys"); maxKeys != "" {
+		n, err := strconv.Atoi(maxKeys)
+		if err != nil || n < 0 {
+			return s3Error(c, fiber.StatusBadRequest, "InvalidArgument", "max-keys must be a non-negative integer")
+		}
+		result.MaxKeys = min(n, s3MaxKeys)
+	}
+
+	// Listing continues after the marker, or the later of start-after and the continuation token
+	var after string
+	if v2 {
+		result.StartAfter = c.Query("start-after")
+		after = result.StartAfter
+		if token := c.Query("continuation-token"); token != "" {
+			decoded, err := base64.RawURLEncoding.DecodeString(token)
+			if err != nil {
+				return s3Error(c, fiber.StatusBadRequest, "InvalidArgument", "the continuation token is not valid")
+			}
+			result.ContinuationToken = token
+			after = max(after, string(decoded))
+		}
+	} else {
+		marker := c.Query("marker")
+		result.Marker = &marker
+		after = marker
+	}
+
+	listing := &s3Listing{prefix: result.Prefix, delimiter: result.Delimiter, maxKeys: result.MaxKeys, last: after}
+	if result.MaxKeys > 0 {
+		err = h.s3WithRLS(c, func(ctx context.Context, tx pgx.Tx) error {
+			for {
+				// Keys are compared byte-wise as in S3, not by the database collation
+				rows, err := tx.Query(ctx, `
+					SELECT path, size, updated_at
+					FROM storage.objects
+					WHERE bucket_id = $1 AND starts_with(path, $2) AND path COLLATE "C" > $3
+					ORDER BY path COLLATE "C"
+					LIMIT $4
+				`, bucket, result.Prefix, after, s3ListBatchSize)
+				if err != nil {
+					return err
+				}
+
+				read, full := 0, false
+				for rows.Next() {
+					var obj s3ListObject
+					var updatedAt time.Time
+					if err := rows.Scan(&obj.Key, &obj.Size, &updatedAt); err != nil {
+						rows.Close()
+						return err
+					}
+					read++
+					after = obj.Key
+					obj.LastModified = updatedAt.UTC().Format(s3TimeFormat)
+					obj.StorageClass = "STANDARD"
+					if !listing.add(obj) {
+						full = true
+						break
+					}
+				}
+				rows.Close()
+				if err := rows.Err(); err != nil {
+					return err
+				}
+				if full || read < s3ListBatchSize {
+					return nil
+				}
+			}
+		})
+		if err != nil {
+			log.Error().Err(err).Str("bucket", bucket).Msg("Failed to list objects for S3")
+			return s3Error(c, fiber.StatusInternalServerError, "InternalError", "failed to list objects")
+		}
+	}
+
+	encode := func(s string) string {
+		if urlEncoded {
+			return url.QueryEscape(s)
+		}
+		return s
+	}
+	result.IsTruncated = listing.truncated
+	result.Contents = listing.contents
+	for i := range result.Contents {
+		result.Contents[i].Key = encode(result.Contents[i].Key)
+	}
+	for _, prefix := range listing.commonPrefixes {
+		result.CommonPrefixes = append(result.CommonPrefixes, s3CommonPrefix{Prefix: encode(prefix)})
+	}
+	result.Prefix = encode(result.Prefix)
+	result.Delimiter = encode(result.Delimiter)
+	result.StartAfter = encode(result.StartAfter)
+
+	if v2 {
+		keyCount := len(listing.contents) + len(listing.commonPrefixes)
+		result.KeyCount = &keyCount
+		if listing.truncated {
+			result.NextContinuationToken = base64.RawURLEncoding.EncodeToString([]byte(listing.last))
+		}
+	} else if listing.truncated {
+		result.NextMarker = encode(listing.last)
+	}
+
+	return c.XML(result)
+}
+
+// s3Object is the stored state of an object the caller can read
+type s3Object struct {
+	contentType string
+	size        int64
+	updatedAt   time.Time
+	metadata    map[string]interface{}
+}
+
+// s3GetObject reads an object's metadata, nil when it doesn't exist or the caller can't read it
+func (h *StorageHandler) s3GetObject(c fiber.Ctx, bucket, key string) (*s3Object, error) {
+	var obj *s3Object
+	err := h.s3WithRLS(c, func(ctx context.Context, tx pgx.Tx) error {
+		var mimeType *string
+		o := &s3Object{}
+		err := tx.QueryRow(ctx, `
+			SELECT mime_type, size, updated_at, metadata
+			FROM storage.objects
+			WHERE bucket_id = $1 AND path = $2
+		`, bucket, key).Scan(&mimeType, &o.size, &o.updatedAt, &o.metadata)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		o.contentType = "application/octet-stream"
+		if mimeType != nil && *mimeType != "" {
+			o.contentType = *mimeType
+		}
+		obj = o
+		return nil
+	})
+	return obj, err
+}
+
+// setHeaders sets the headers describing the object
+func (o *s3Object) setHeaders(c fiber.Ctx) {
+	c.Set("Last-Modified", o.updatedAt.UTC().Format(http.TimeFormat))
+	c.Set("Accept-Ranges", "bytes")
+	for name, value := range o.metadata {
+		c.Set("x-amz-meta-"+name, fmt.Sprintf("%v", value))
+	}
+}
+
+// parseS3Range resolves a single byte range against the object size. ok is false for an
+// unsatisfiable range; ranges that can't be parsed are ignored, as HTTP requires.
+func parseS3Range(header string, size int64) (start, end int64, ranged, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false, true
+	}
+	first, last, found := strings.Cut(spec, "-")
+	if !found {
+		return 0, 0, false, true
+	}
+
+	if first == "" {
+		// Suffix range, the last n bytes
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return 0, 0, false, true
+		}
+		if n == 0 || size == 0 {
+			return 0, 0, true, false
+		}
+		return max(size-n, 0), size - 1, true, true
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false, true
+	}
+	end = size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false, true
+		}
+		end = min(end, size-1)
+	}
+	if start >= size {
+		return 0, 0, true, false
+	}
+	return start, end, true, true
+}
+
+// S3GetObject downloads an object, with support for a byte range
+// GET /api/v1/storage/s3/:bucket/:key
+func (h *StorageHandler) S3GetObject(c fiber.Ctx) error {
+	bucket := c.Params("bucket")
+	key, ok := s3ObjectKey(c)
+	if !ok {
+		return s3Error(c, fiber.StatusBadRequest, "InvalidURI", "the object key is not valid")
+	}
+	// Some clients list with a trailing slash after the bucket
+	if key == "" {
+		return h.S3ListObjects(c)
+	}
+
+	obj, err := h.s3GetObject(c, bucket, key)
+	if err != nil {
+		log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to get object for S3")
+		return s3Error(c, fiber.StatusInternalServerError, "InternalError", "failed to get object")
+	}
+	if obj == nil {
+		return s3Error(c, fiber.StatusNotFound, "NoSuchKey", "the specified key does not exist")
+	}
+
+	ctx := c.RequestCtx()
+	opts := &storage.DownloadOptions{}
+	start, end, ranged, satisfiable := parseS3Range(c.Get("Range"), obj.size)
+	if !satisfiable {
+		c.Set("Content-Range", fmt.Sprintf("bytes */%d", obj.size))
+		return s3Error(c, fiber.StatusRequestedRangeNotSatisfiable, "InvalidRange", "the requested range is not satisfiable")
+	}
+	if ranged {
+		opts.Range = fmt.Sprintf("bytes=%d-%d", start, end)
+	}
+
+	var reader io.ReadCloser = io.NopCloser(bytes.NewReader(nil))
+	var etag string
+	if obj.size > 0 {
+		r, object, err := h.storage.Provider.Download(ctx, bucket, key, opts)
+		if err != nil {
+			log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to download object from provider for S3")
+			return s3Error(c, fiber.StatusInternalServerError, "InternalError", "failed to get object")
+		}
+		reader, etag = r, object.ETag
+	}
+
+	// Presigned URLs can be opened in browsers, so the bucket's download settings apply
+	settings, err := h.getDownloadSettings(ctx, bucket)
+	if err != nil {
+		log.Warn().Err(err).Str("bucket", bucket).Msg("Failed to get bucket download settings, using defaults")
+	}
+	resolveDownloadHeaders(settings, obj.contentType, filepath.Base(key), nil).apply(c)
+	obj.setHeaders(c)
+	if etag != "" {
+		c.Set("ETag", strconv.Quote(strings.Trim(etag, `"`)))
+	}
+
+	length := obj.size
+	if ranged {
+		length = end - start + 1
+		c.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, obj.size))
+		c.Status(fiber.StatusPartialContent)
+	}
+	c.Set("Content-Length", strconv.FormatInt(length, 10))
+
+	log.Debug().
+		Str("bucket", bucket).
+		Str("key", key).
+		Str("user_id", getUserID(c)).
+		Msg("Object downloaded via S3 API")
+
+	return c.SendStream(reader, int(length))
+}
+
+// S3HeadObject returns an object's metadata
+// HEAD /api/v1/storage/s3/:bucket/:key
+func (h *StorageHandler) S3HeadObject(c fiber.Ctx) error {
+	bucket := c.Params("bucket")
+	key, ok := s3ObjectKey(c)
+	if !ok || key == "" {
+		return s3Error(c, fiber.StatusBadRequest, "InvalidURI", "the object key is not valid")
+	}
+
+	obj, err := h.s3GetObject(c, bucket, key)
+	if err != nil {
+		log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to get object for S3")
+		return s3Error(c, fiber.StatusInternalServerError, "InternalError", "failed to get object")
+	}
+	if obj == nil {
+		return s3Error(c, fiber.StatusNotFound, "NoSuchKey", "the specified key does not exist")
+	}
+
+	c.Set("Content-Type", obj.contentType)
+	obj.setHeaders(c)
+	c.Response().Header.SetContentLength(int(obj.size))
+	c.Status(fiber.StatusOK)
+	return nil
+}
+
+// S3PutObject uploads an object, replacing an existing one. The body is streamed to the
+// provider and checked against its signed SHA-256 unless it was sent unsigned.
+// PUT /api/v1/storage/s3/:bucket/:key
+func (h *StorageHandler) S3PutObject(c fiber.Ctx) error {
+	bucket := c.Params("bucket")
+	key, ok := s3ObjectKey(c)
+	if !ok || key == "" || sanitizeFilename(key) != key {
+		return s3Error(c, fiber.StatusBadRequest, "InvalidArgument", "the object key is not valid")
+	}
+	if c.Get("X-Amz-Copy-Source") != "" {
+		return s3Error(c, fiber.StatusNotImplemented, "NotImplemented", "the CopyObject operation is not supported")
+	}
+
+	size := int64(c.Request().Header.ContentLength())
+	if size < 0 {
+		return s3Error(c, fiber.StatusLengthRequired, "MissingContentLength", "the Content-Length header is required")
+	}
+	if err := h.storage.ValidateUploadSize(size); err != nil {
+		return s3Error(c, fiber.StatusBadRequest, "EntityTooLarge", err.Error())
+	}
+
+	ctx := c.RequestCtx()
+	exists, err := h.s3BucketExists(ctx, bucket)
+	if err != nil {
+		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to check bucket existence")
+		return s3Error(c, fiber.StatusInternalServerError, "InternalError", "failed to upload object")
+	}
+	if !exists {
+		return s3Error(c, fiber.StatusNotFound, "NoSuchBucket", "the specified bucket does not exist")
+	}
+
+	contentType := c.Get("Content-Type")
+	if contentType == "" {
+		contentType = detectContentType(key)
+	}
+
+	var maxFileSize *int64
+	var allowedMimeTypes []string
+	err = h.db.Pool().QueryRow(ctx,
+		`SELECT max_file_size, allowed_mime_types FROM storage.get_bucket_settings($1)`,
+		bucket,
+	).Scan(&maxFileSize, &allowedMimeTypes)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to get bucket settings")
+		return s3Error(c, fiber.StatusInternalServerError, "InternalError", "failed to upload object")
+	}
+	if maxFileSize != nil && *maxFileSize > 0 && size > *maxFileSize {
+		return s3Error(c, fiber.StatusBadRequest, "EntityTooLarge",
+			fmt.Sprintf("object size %d exceeds bucket maximum of %d bytes", size, *maxFileSize))
+	}
+	if len(allowedMimeTypes) > 0 && !mimeTypeAllowed(allowedMimeTypes, contentType) {
+		return s3Error(c, fiber.StatusBadRequest, "InvalidArgument",
+			fmt.Sprintf("content type %s is not allowed for this bucket", contentType))
+	}
+
+	metadata := make(map[string]string)
+	for name, value := range c.Request().Header.All() {
+		if metaKey, ok := strings.CutPrefix(strings.ToLower(string(name)), "x-amz-meta-"); ok {
+			metadata[metaKey] = string(value)
+		}
+	}
+
+	body := c.Request().BodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.Body())
+	}
+	if payloadHash, _ := c.Locals("s3_payload_hash").(string); payloadHash != "" && payloadHash != storage.UnsignedPayload {
+		body = storage.NewPayloadVerifier(body, payloadHash)
+	}
+
+	object, err := h.storage.Provider.Upload(ctx, bucket, key, body, size, &storage.UploadOptions{
+		ContentType:  contentType,
+		Metadata:     metadata,
+		CacheControl: c.Get("Cache-Control"),
+	})
+	if errors.Is(err, storage.ErrPayloadHashMismatch) {
+		return s3Error(c, fiber.StatusBadRequest, "XAmzContentSHA256Mismatch", "the body does not match x-amz-content-sha256")
+	}
+	if err != nil {
+		log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to upload object via S3 API")
+		return s3Error(c, fiber.StatusInternalServerError, "InternalError", "failed to upload object")
+	}
+
+	ownerID := getUserID(c)
+	var ownerUUID *string
+	if ownerID != "anonymous" {
+		ownerUUID = &ownerID
+	}
+	var metadataJSON map[string]interface{}
+	if len(metadata) > 0 {
+		metadataJSON = make(map[string]interface{}, len(metadata))
+		for k, v := range metadata {
+			metadataJSON[k] = v
+		}
+	}
+
+	// Insert object metadata into database (RLS will check permissions)
+	err = h.s3WithRLS(c, func(ctx context.Context, tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO storage.objects (bucket_id, path, mime_type, size, metadata, owner_id)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (bucket_id, path)
+			DO UPDATE SET mime_type = $3, size = $4, metadata = $5, owner_id = $6, updated_at = NOW()
+		`, bucket, key, contentType, object.Size, metadataJSON, ownerUUID)
+		return err
+	})
+	if err != nil {
+		// Delete from provider since DB insert failed
+		_ = h.storage.Provider.Delete(ctx, bucket, key)
+
+		if errMsg := err.Error(); strings.Contains(errMsg, "permission denied") || strings.Contains(errMsg, "policy") {
+			return s3Error(c, fiber.StatusForbidden, "AccessDenied", "insufficient permissions to upload object")
+		}
+		if database.IsForeignKeyViolation(err) {
+			return s3Error(c, fiber.StatusNotFound, "NoSuchBucket", "the specified bucket does not exist")
+		}
+		log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to save object metadata via S3 API")
+		return s3Error(c, fiber.StatusInternalServerError, "InternalError", "failed to upload object")
+	}
+
+	log.Info().
+		Str("bucket", bucket).
+		Str("key", key).
+		Int64("size", object.Size).
+		Str("user_id", ownerID).
+		Msg("Object uploaded via S3 API")
+
+	h.purgeCDN(bucket, key)
+	h.fireObjectTriggers(c, bucket, key, contentType, object.Size)
+
+	if object.ETag != "" {
+		c.Set("ETag", strconv.Quote(strings.Trim(object.ETag, `"`)))
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// S3DeleteObject deletes an object. As in S3, deleting a key that doesn't exist succeeds.
+// DELETE /api/v1/storage/s3/:bucket/:key
+func (h *StorageHandler) S3DeleteObject(c fiber.Ctx) error {
+	bucket := c.Params("bucket")
+	key, ok := s3ObjectKey(c)
+	if !ok || key == "" {
+		return s3Error(c, fiber.StatusBadRequest, "InvalidArgument", "the object key is not valid")
+	}
+
+	var deleted, forbidden bool
+	err := h.s3WithRLS(c, func(ctx context.Context, tx pgx.Tx) error {
+		// Delete from database (RLS will check permissions)
+		result, err := tx.Exec(ctx, `DELETE FROM storage.objects WHERE bucket_id = $1 AND path = $2`, bucket, key)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			// Distinguish an object the caller may not delete from one that doesn't exist
+			return h.db.Pool().QueryRow(ctx, `
+				SELECT EXISTS(SELECT 1 FROM storage.objects WHERE bucket_id = $1 AND path = $2)
+			`, bucket, key).Scan(&forbidden)
+		}
+
+		deleted = true
+		if err := h.storage.Provider.Delete(ctx, bucket, key); err != nil {
+			log.Warn().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to delete file from provider (metadata already deleted)")
+		}
+		if h.transformCache != nil {
+			if err := h.transformCache.Invalidate(ctx, bucket, key); err != nil {
+				log.Warn().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to invalidate transform cache")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errMsg := err.Error(); strings.Contains(errMsg, "permission denied") || strings.Contains(errMsg, "policy") {
+			return s3Error(c, fiber.StatusForbidden, "AccessDenied", "insufficient permissions to delete object")
+		}
+		log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to delete object via S3 API")
+		return s3Error(c, fiber.StatusInternalServerError, "InternalError", "failed to delete object")
+	}
+	if forbidden {
+		return s3Error(c, fiber.StatusForbidden, "AccessDenied", "insufficient permissions to delete object")
+	}
+
+	if deleted {
+		log.Info().
+			Str("bucket", bucket).
+			Str("key", key).
+			Str("user_id", getUserID(c)).
+			Msg("Object deleted via S3 API")
+		h.purgeCDN(bucket, key)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -1,7 +1,11 @@
 package api
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"net/url"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -9,6 +13,8 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/auth"
 	"github.com/nimbleflux/fluxbase/internal/storage"
 	"github.com/rs/zerolog/log"
 )
@@ -57,22 +63,25 @@ func (r *ipRateLimiter) allow(ip string) bool {
 	return true
 }
 
-// GenerateSignedURL generates a presigned URL for temporary access
+// GenerateSignedURL generates a presigned URL for temporary access. PUT URLs are signed
+// upload URLs served by Fluxbase for every provider.
 // POST /api/v1/storage/:bucket/sign/*
 func (h *StorageHandler) GenerateSignedURL(c fiber.Ctx) error {
 	bucket := c.Params("bucket")
 	key := c.Params("*")
 
 	if bucket == "" || key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "bucket and key are required",
-		})
+		return SendBadRequest(c, "bucket and key are required", ErrCodeMissingField)
 	}
 
 	// Parse request body
 	var req struct {
 		ExpiresIn int    `json:"expires_in"` // seconds
 		Method    string `json:"method"`     // GET, PUT, DELETE
+		// Upload constraints (PUT only)
+		ContentTypes []string `json:"content_types,omitempty"`
+		MaxSize      int64    `json:"max_size,omitempty"`
+		Upsert       bool     `json:"upsert,omitempty"`
 		// Transform options (for image downloads)
 		Transform *struct {
 			Width   int    `json:"width"`
@@ -83,9 +92,7 @@ func (h *StorageHandler) GenerateSignedURL(c fiber.Ctx) error {
 		} `json:"transform,omitempty"`
 	}
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		return SendBadRequest(c, "invalid request body", ErrCodeInvalidBody)
 	}
 
 	// Default values
@@ -96,6 +103,30 @@ func (h *StorageHandler) GenerateSignedURL(c fiber.Ctx) error {
 		req.Method = "GET"
 	}
 
+	// Uploads go through Fluxbase, which enforces the URL's constraints and records the object
+	if strings.EqualFold(req.Method, "PUT") {
+		return h.generateSignedUploadURL(c, bucket, key, req.ExpiresIn, req.ContentTypes, req.MaxSize, req.Upsert)
+	}
+
+	// Plain downloads from buckets behind a signing CDN get a CDN URL
+	if req.Method == "GET" && req.Transform == nil && h.cdn != nil {
+		settings, err := h.cdn.Settings(c.RequestCtx(), bucket)
+		if err != nil {
+			log.Warn().Err(err).Str("bucket", bucket).Msg("Failed to get CDN settings, signing a storage URL")
+		} else if settings != nil && settings.Signs() {
+			url, err := settings.SignedURL(key, time.Duration(req.ExpiresIn)*time.Second, time.Now())
+			if err != nil {
+				log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to generate CDN signed URL")
+				return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to generate signed URL", ErrCodeOperationFailed)
+			}
+			return c.JSON(fiber.Map{
+				"signed_url": url,
+				"expires_in": req.ExpiresIn,
+				"method":     req.Method,
+			})
+		}
+	}
+
 	// Generate signed URL
 	opts := &storage.SignedURLOptions{
 		ExpiresIn: time.Duration(req.ExpiresIn) * time.Second,
@@ -114,9 +145,7 @@ func (h *StorageHandler) GenerateSignedURL(c fiber.Ctx) error {
 	url, err := h.storage.Provider.GenerateSignedURL(c.RequestCtx(), bucket, key, opts)
 	if err != nil {
 		log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to generate signed URL")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to generate signed URL",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to generate signed URL", ErrCodeOperationFailed)
 	}
 
 	return c.JSON(fiber.Map{
@@ -134,41 +163,31 @@ func (h *StorageHandler) DownloadSignedObject(c fiber.Ctx) error {
 	clientIP := c.IP()
 	if !signedURLRateLimiter.allow(clientIP) {
 		log.Warn().Str("ip", clientIP).Msg("Rate limit exceeded for signed URL download")
-		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-			"error": "rate limit exceeded, please try again later",
-		})
+		return SendErrorWithCode(c, fiber.StatusTooManyRequests, "rate limit exceeded, please try again later", ErrCodeRateLimited)
 	}
 
 	token := c.Query("token")
 	if token == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "token is required",
-		})
+		return SendBadRequest(c, "token is required", ErrCodeMissingField)
 	}
 
 	// Only local storage supports signed URL validation
 	localStorage, ok := h.storage.Provider.(*storage.LocalStorage)
 	if !ok {
 		// For S3, the signed URL is handled directly by S3
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "this endpoint is only for local storage signed URLs",
-		})
+		return SendBadRequest(c, "this endpoint is only for local storage signed URLs", ErrCodeInvalidInput)
 	}
 
 	// Validate the token (use full validation to get transform options)
 	tokenResult, err := localStorage.ValidateSignedTokenFull(token)
 	if err != nil {
 		log.Warn().Err(err).Msg("Invalid signed URL token")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "invalid or expired token",
-		})
+		return SendUnauthorized(c, "invalid or expired token", ErrCodeExpiredToken)
 	}
 
 	// Verify the request method matches the token
 	if tokenResult.Method != c.Method() {
-		return c.Status(fiber.StatusMethodNotAllowed).JSON(fiber.Map{
-			"error": fmt.Sprintf("token is only valid for %s requests", tokenResult.Method),
-		})
+		return SendErrorWithCode(c, fiber.StatusMethodNotAllowed, fmt.Sprintf("token is only valid for %s requests", tokenResult.Method), ErrCodeMethodNotAllowed)
 	}
 
 	// Download the file (no RLS check - token is the authorization)
@@ -180,26 +199,27 @@ func (h *StorageHandler) DownloadSignedObject(c fiber.Ctx) error {
 	reader, object, err := h.storage.Provider.Download(c.RequestCtx(), tokenResult.Bucket, tokenResult.Key, opts)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "file not found",
-			})
+			return SendNotFound(c, "file not found")
 		}
 		log.Error().Err(err).Str("bucket", tokenResult.Bucket).Str("key", tokenResult.Key).Msg("Failed to download file via signed URL")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to download file",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to download file", ErrCodeOperationFailed)
 	}
 	defer func() { _ = reader.Close() }()
 
 	contentType := object.ContentType
 	contentLength := object.Size
 
+	settings, err := h.getDownloadSettings(c.RequestCtx(), tokenResult.Bucket)
+	if err != nil {
+		log.Warn().Err(err).Str("bucket", tokenResult.Bucket).Msg("Failed to get bucket download settings, using defaults")
+	}
+
 	// Check if transforms are requested and applicable
 	hasTransform := tokenResult.TransformWidth > 0 || tokenResult.TransformHeight > 0 ||
 		tokenResult.TransformFormat != "" || tokenResult.TransformQuality > 0
 	canTransform := h.transformer != nil && storage.CanTransform(object.ContentType)
 
-	if hasTransform && canTransform {
+	if hasTransform && canTransform && h.transformsEnabled(c.RequestCtx(), tokenResult.Bucket) {
 		// Apply image transformation
 		transformOpts := storage.ParseTransformOptions(
 			tokenResult.TransformWidth,
@@ -208,6 +228,10 @@ func (h *StorageHandler) DownloadSignedObject(c fiber.Ctx) error {
 			tokenResult.TransformQuality,
 			tokenResult.TransformFit,
 		)
+		if err := h.applyTransformDefaults(transformOpts); err != nil {
+			log.Warn().Err(err).Msg("Signed URL transform not allowed, serving original")
+			transformOpts = nil
+		}
 
 		if transformOpts != nil {
 			transformedReader, newContentType, newSize, err := h.transformer.TransformReader(reader, object.ContentType, transformOpts)
@@ -220,7 +244,6 @@ func (h *StorageHandler) DownloadSignedObject(c fiber.Ctx) error {
 				contentLength = newSize
 
 				// Set response headers
-				c.Set("Content-Type", contentType)
 				c.Set("Content-Length", strconv.FormatInt(contentLength, 10))
 				c.Set("Last-Modified", object.LastModified.UTC().Format("Mon, 02 Jan 2006 15:04:05 GMT"))
 				c.Set("X-Image-Transformed", "true")
@@ -235,7 +258,8 @@ func (h *StorageHandler) DownloadSignedObject(c fiber.Ctx) error {
 					}
 					filename = strings.TrimSuffix(filename, filepath.Ext(filename)) + ext
 				}
-				c.Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", filename))
+				inline := true
+				resolveDownloadHeaders(settings, contentType, filename, &inline).apply(c)
 
 				return c.SendStream(transformedReader)
 			}
@@ -243,16 +267,263 @@ func (h *StorageHandler) DownloadSignedObject(c fiber.Ctx) error {
 	}
 
 	// No transform or transform not applicable - serve original file
-	c.Set("Content-Type", contentType)
 	c.Set("Content-Length", strconv.FormatInt(contentLength, 10))
 	c.Set("Last-Modified", object.LastModified.UTC().Format("Mon, 02 Jan 2006 15:04:05 GMT"))
 
-	// Set Content-Disposition for download
+	// Set Content-Type and Content-Disposition from the bucket's download settings
 	filename := filepath.Base(tokenResult.Key)
-	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	resolveDownloadHeaders(settings, contentType, filename, downloadPreference(c)).apply(c)
 
 	// Stream the file
 	return c.SendStream(reader)
 }
 
+// maxSignedUploadTTL is the longest a signed upload URL can be valid
+const maxSignedUploadTTL = 7 * 24 * time.Hour
+
+// SetSignedUploads enables signed upload URLs. Tokens are signed with secret and the URLs
+// point at baseURL.
+func (h *StorageHandler) SetSignedUploads(secret, baseURL string) {
+	h.uploadSigningSecret = secret
+	h.publicBaseURL = strings.TrimSuffix(baseURL, "/")
+}
+
+// generateSignedUploadURL signs an upload URL for an object. The URL's size and content type
+// limits are narrowed to the bucket's, and the bucket's policies are checked for the
+// requester up front, so a client doesn't upload a file that would be rejected.
+func (h *StorageHandler) generateSignedUploadURL(c fiber.Ctx, bucket, key string, expiresIn int, contentTypes []string, maxSize int64, upsert bool) error {
+	if h.uploadSigningSecret == "" || h.publicBaseURL == "" {
+		return SendErrorWithCode(c, fiber.StatusNotImplemented, "signed uploads are not configured", ErrCodeNotImplemented)
+	}
+	ttl := time.Duration(expiresIn) * time.Second
+	if ttl <= 0 || ttl > maxSignedUploadTTL {
+		return SendBadRequest(c, fmt.Sprintf("expires_in must be between 1 and %d seconds", int(maxSignedUploadTTL.Seconds())), ErrCodeInvalidInput)
+	}
+	key = sanitizeFilename(key)
+	if key == "" || key == "." {
+		return SendBadRequest(c, "invalid object key", ErrCodeInvalidInput)
+	}
+	if maxSize < 0 {
+		return SendBadRequest(c, "max_size must not be negative", ErrCodeInvalidInput)
+	}
+	for i, contentType := range contentTypes {
+		contentTypes[i] = baseMediaType(contentType)
+		if !strings.Contains(contentTypes[i], "/") {
+			return SendBadRequest(c, fmt.Sprintf("invalid content type %q", contentType), ErrCodeInvalidInput)
+		}
+	}
+
+	ctx := c.RequestCtx()
+
+	// Use SECURITY DEFINER functions to bypass RLS when reading the bucket
+	var bucketExists bool
+	if err := h.db.Pool().QueryRow(ctx, `SELECT storage.bucket_exists($1)`, bucket).Scan(&bucketExists); err != nil {
+		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to check bucket existence")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to validate bucket", ErrCodeOperationFailed)
+	}
+	if !bucketExists {
+		return SendNotFound(c, fmt.Sprintf("bucket '%s' does not exist", bucket))
+	}
+
+	var bucketMaxFileSize *int64
+	var bucketAllowedMimeTypes []string
+	err := h.db.Pool().QueryRow(ctx,
+		`SELECT max_file_size, allowed_mime_types FROM storage.get_bucket_settings($1)`,
+		bucket,
+	).Scan(&bucketMaxFileSize, &bucketAllowedMimeTypes)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to get bucket settings")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to validate bucket settings", ErrCodeOperationFailed)
+	}
+
+	limit := h.storage.MaxUploadSize()
+	if bucketMaxFileSize != nil && *bucketMaxFileSize > 0 && *bucketMaxFileSize < limit {
+		limit = *bucketMaxFileSize
+	}
+	switch {
+	case maxSize == 0:
+		maxSize = limit
+	case maxSize > limit:
+		return SendBadRequest(c, fmt.Sprintf("max_size exceeds the upload limit of %d bytes", limit), ErrCodeInvalidInput)
+	}
+
+	if len(bucketAllowedMimeTypes) > 0 {
+		if len(contentTypes) == 0 {
+			contentTypes = bucketAllowedMimeTypes
+		}
+		for _, contentType := range contentTypes {
+			if !mimeTypeAllowed(bucketAllowedMimeTypes, contentType) {
+				return SendErrorWithCode(c, fiber.StatusUnsupportedMediaType, fmt.Sprintf("file type %s is not allowed for this bucket", contentType), ErrCodeUnsupportedMediaType)
+			}
+		}
+	}
+
+	// Check the bucket's policies by saving the object record in a transaction that is
+	// rolled back
+	probeType := "application/octet-stream"
+	if len(contentTypes) > 0 && !strings.Contains(contentTypes[0], "*") {
+		probeType = contentTypes[0]
+	}
+	if err := h.saveObjectRecord(ctx, c, bucket, key, probeType, 0, nil, false); err != nil {
+		return h.sendObjectRecordError(c, err, bucket, key)
+	}
+
+	claims := &storage.UploadTokenClaims{
+		Bucket:       bucket,
+		Key:          key,
+		ExpiresAt:    time.Now().Add(ttl).Unix(),
+		ContentTypes: contentTypes,
+		MaxSize:      maxSize,
+		Upsert:       upsert,
+	}
+	if userID := getUserID(c); userID != "anonymous" {
+		claims.UserID = userID
+	}
+	if role, ok := c.Locals("user_role").(string); ok {
+		claims.Role = role
+	}
+	if jwtClaims, ok := c.Locals("jwt_claims").(*auth.TokenClaims); ok {
+		claims.OrgID = jwtClaims.OrgID
+		claims.OrgRole = jwtClaims.OrgRole
+	}
+
+	token, err := storage.SignUploadToken(h.uploadSigningSecret, claims)
+	if err != nil {
+		log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to sign upload URL")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to generate signed URL", ErrCodeOperationFailed)
+	}
+
+	return c.JSON(fiber.Map{
+		"signed_url":    fmt.Sprintf("%s/api/v1/storage/object?token=%s", h.publicBaseURL, url.QueryEscape(token)),
+		"expires_in":    expiresIn,
+		"method":        "PUT",
+		"key":           key,
+		"max_size":      maxSize,
+		"content_types": contentTypes,
+	})
+}
+
+// RequireUploadToken authenticates a signed upload from its token, without a database
+// lookup. The request then acts as the user who created the URL.
+func (h *StorageHandler) RequireUploadToken(c fiber.Ctx) error {
+	token := c.Query("token")
+	if token == "" {
+		return SendBadRequest(c, "token is required", ErrCodeMissingField)
+	}
+
+	claims, err := storage.VerifyUploadToken(h.uploadSigningSecret, token, time.Now())
+	if err != nil {
+		log.Warn().Err(err).Msg("Invalid signed upload token")
+		return SendUnauthorized(c, "invalid or expired token", ErrCodeExpiredToken)
+	}
+
+	c.Locals("upload_claims", claims)
+	c.Locals("user_id", nil)
+	c.Locals("user_email", nil)
+	c.Locals("user_role", claims.Role)
+	c.Locals("jwt_claims", nil)
+	if claims.UserID != "" {
+		c.Locals("user_id", claims.UserID)
+	}
+	if claims.OrgID != "" {
+		c.Locals("jwt_claims", &auth.TokenClaims{
+			UserID:  claims.UserID,
+			Role:    claims.Role,
+			OrgID:   claims.OrgID,
+			OrgRole: claims.OrgRole,
+		})
+	}
+	return c.Next()
+}
+
+// UploadSignedObject stores the body as the object of a signed upload URL. The body must
+// declare its Content-Length and match the URL's size and content type limits.
+// PUT /api/v1/storage/object?token=...
+// This is a PUBLIC endpoint - authorization is provided by the signed token
+func (h *StorageHandler) UploadSignedObject(c fiber.Ctx) error {
+	claims, ok := c.Locals("upload_claims").(*storage.UploadTokenClaims)
+	if !ok {
+		return SendUnauthorized(c, "invalid or expired token", ErrCodeInvalidToken)
+	}
+
+	size := int64(c.Request().Header.ContentLength())
+	if size < 0 {
+		return SendErrorWithCode(c, fiber.StatusLengthRequired, "Content-Length header is required", ErrCodeMissingField)
+	}
+	if size > claims.MaxSize {
+		return SendErrorWithCode(c, fiber.StatusRequestEntityTooLarge, fmt.Sprintf("file size %d exceeds the signed limit of %d bytes", size, claims.MaxSize), ErrCodePayloadTooLarge)
+	}
+
+	contentType := baseMediaType(c.Get(fiber.HeaderContentType))
+	if contentType == "" {
+		contentType = detectContentType(claims.Key)
+	}
+	if len(claims.ContentTypes) > 0 && !mimeTypeAllowed(claims.ContentTypes, contentType) {
+		return SendErrorWithCode(c, fiber.StatusUnsupportedMediaType, fmt.Sprintf("file type %s is not allowed by the signed URL", contentType), ErrCodeUnsupportedMediaType)
+	}
+
+	ctx := c.RequestCtx()
+
+	if !claims.Upsert {
+		exists, err := h.storage.Provider.Exists(ctx, claims.Bucket, claims.Key)
+		if err != nil {
+			log.Error().Err(err).Str("bucket", claims.Bucket).Str("key", claims.Key).Msg("Failed to check object existence")
+			return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to upload file", ErrCodeOperationFailed)
+		}
+		if exists {
+			return SendConflict(c, "file already exists", ErrCodeAlreadyExists)
+		}
+	}
+
+	// Record the object before writing it, so a policy refusal never reaches the provider,
+	// where deleting the upload would destroy the object an upsert replaces
+	tx, err := h.beginObjectRecord(ctx, c, claims.Bucket, claims.Key, contentType, size, nil)
+	if err != nil {
+		return h.sendObjectRecordError(c, err, claims.Bucket, claims.Key)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var body io.Reader = c.Request().BodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.Body())
+	}
+
+	object, err := h.storage.Provider.Upload(ctx, claims.Bucket, claims.Key, io.LimitReader(body, size), size, &storage.UploadOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("bucket", claims.Bucket).Str("key", claims.Key).Msg("Failed to upload file via signed URL")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to upload file", ErrCodeOperationFailed)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		if !claims.Upsert {
+			_ = h.storage.Provider.Delete(ctx, claims.Bucket, claims.Key)
+		}
+		return h.sendObjectRecordError(c, err, claims.Bucket, claims.Key)
+	}
+
+	log.Info().
+		Str("bucket", claims.Bucket).
+		Str("key", claims.Key).
+		Int64("size", object.Size).
+		Str("user_id", getUserID(c)).
+		Msg("File uploaded via signed URL")
+
+	h.purgeCDN(claims.Bucket, claims.Key)
+	h.fireObjectTriggers(c, claims.Bucket, claims.Key, contentType, object.Size)
+
+	response := fiber.Map{
+		"key":           object.Key,
+		"bucket":        object.Bucket,
+		"size":          object.Size,
+		"content_type":  contentType,
+		"last_modified": object.LastModified,
+	}
+	if publicURL := h.cdnPublicURL(ctx, claims.Bucket, claims.Key); publicURL != "" {
+		response["public_url"] = publicURL
+	}
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
 // fiber:context-methods migrated
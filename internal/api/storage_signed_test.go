@@ -1,11 +1,16 @@
 package api
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -187,3 +192,98 @@ func TestIPRateLimiter_Struct(t *testing.T) {
 		assert.Equal(t, time.Minute, signedURLRateLimiter.window)
 	})
 }
+
+// =============================================================================
+// Signed Upload Tests
+// =============================================================================
+
+func TestStorageHandler_GenerateSignedUploadURL_Validation(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		body   string
+		status int
+	}{
+		{"not configured", "", `{"method":"PUT"}`, http.StatusNotImplemented},
+		{"ttl too long", "secret", `{"method":"PUT","expires_in":999999999}`, http.StatusBadRequest},
+		{"negative ttl", "secret", `{"method":"PUT","expires_in":-5}`, http.StatusBadRequest},
+		{"invalid content type", "secret", `{"method":"PUT","content_types":["png"]}`, http.StatusBadRequest},
+		{"negative max size", "secret", `{"method":"PUT","max_size":-1}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &StorageHandler{}
+			if tt.secret != "" {
+				handler.SetSignedUploads(tt.secret, "http://localhost:8080")
+			}
+			app := setupTestFiberApp()
+			app.Post("/storage/:bucket/sign/*", handler.GenerateSignedURL)
+
+			req := httptest.NewRequest(http.MethodPost, "/storage/avatars/sign/a.png", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestStorageHandler_UploadSignedObject_Validation(t *testing.T) {
+	storageSvc, err := storage.NewService(&config.StorageConfig{
+		Provider:      "local",
+		LocalPath:     t.TempDir(),
+		MaxUploadSize: 1024,
+	}, "http://localhost:8080", "secret")
+	require.NoError(t, err)
+	require.NoError(t, storageSvc.Provider.CreateBucket(context.Background(), "avatars"))
+	_, err = storageSvc.Provider.Upload(context.Background(), "avatars", "taken.png", strings.NewReader("png"), 3, &storage.UploadOptions{ContentType: "image/png"})
+	require.NoError(t, err)
+
+	handler := &StorageHandler{storage: storageSvc}
+	handler.SetSignedUploads("secret", "http://localhost:8080")
+
+	app := setupTestFiberApp()
+	app.Put("/storage/object", handler.RequireUploadToken, handler.UploadSignedObject)
+
+	sign := func(key string, contentTypes []string, expiresAt time.Time) string {
+		token, err := storage.SignUploadToken("secret", &storage.UploadTokenClaims{
+			Bucket:       "avatars",
+			Key:          key,
+			ExpiresAt:    expiresAt.Unix(),
+			ContentTypes: contentTypes,
+			MaxSize:      4,
+		})
+		require.NoError(t, err)
+		return url.QueryEscape(token)
+	}
+
+	tests := []struct {
+		name        string
+		token       string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"missing token", "", "image/png", "png", http.StatusBadRequest},
+		{"invalid token", "abc.def", "image/png", "png", http.StatusUnauthorized},
+		{"expired token", sign("a.png", nil, time.Now().Add(-time.Minute)), "image/png", "png", http.StatusUnauthorized},
+		{"too large", sign("a.png", nil, time.Now().Add(time.Minute)), "image/png", "large", http.StatusRequestEntityTooLarge},
+		{"content type not allowed", sign("a.png", []string{"image/*"}, time.Now().Add(time.Minute)), "text/plain", "png", http.StatusUnsupportedMediaType},
+		{"existing object", sign("taken.png", []string{"image/*"}, time.Now().Add(time.Minute)), "image/png", "png", http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/storage/object?token="+tt.token, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
@@ -29,24 +29,18 @@ func (h *StorageHandler) StreamUpload(c fiber.Ctx) error {
 	key := c.Params("*") // Capture the rest of the path
 
 	if bucket == "" || key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "bucket and key are required",
-		})
+		return SendBadRequest(c, "bucket and key are required", ErrCodeMissingField)
 	}
 
 	// Get file size from Content-Length header (required for streaming)
 	size := int64(c.Request().Header.ContentLength())
 	if size <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Content-Length header is required for streaming uploads",
-		})
+		return SendBadRequest(c, "Content-Length header is required for streaming uploads", ErrCodeMissingField)
 	}
 
 	// Validate file size
 	if err := h.storage.ValidateUploadSize(size); err != nil {
-		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendErrorWithCode(c, fiber.StatusRequestEntityTooLarge, err.Error(), ErrCodePayloadTooLarge)
 	}
 
 	// Get content type from header
@@ -63,9 +57,7 @@ func (h *StorageHandler) StreamUpload(c fiber.Ctx) error {
 	metadata := make(map[string]string)
 	if metadataHeader := c.Get("X-Storage-Metadata"); metadataHeader != "" {
 		if err := json.Unmarshal([]byte(metadataHeader), &metadata); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "invalid X-Storage-Metadata header: must be valid JSON object",
-			})
+			return SendBadRequest(c, "invalid X-Storage-Metadata header: must be valid JSON object", ErrCodeInvalidInput)
 		}
 	}
 
@@ -97,9 +89,7 @@ func (h *StorageHandler) StreamUpload(c fiber.Ctx) error {
 		// Fall back to reading the buffered body
 		bodyBytes := c.Body()
 		if len(bodyBytes) == 0 {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "request body is required",
-			})
+			return SendBadRequest(c, "request body is required", ErrCodeInvalidBody)
 		}
 		body = bytes.NewReader(bodyBytes)
 	}
@@ -108,9 +98,7 @@ func (h *StorageHandler) StreamUpload(c fiber.Ctx) error {
 	object, err := h.storage.Provider.Upload(ctx, bucket, key, body, size, opts)
 	if err != nil {
 		log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to upload file (streaming)")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to upload file",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to upload file", ErrCodeOperationFailed)
 	}
 
 	// Start database transaction to store metadata
@@ -119,9 +107,7 @@ func (h *StorageHandler) StreamUpload(c fiber.Ctx) error {
 		// Delete from provider since DB insert failed
 		_ = h.storage.Provider.Delete(ctx, bucket, key)
 		log.Error().Err(err).Msg("Failed to start transaction for streaming file upload")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to save file metadata",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to save file metadata", ErrCodeOperationFailed)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
@@ -129,9 +115,7 @@ func (h *StorageHandler) StreamUpload(c fiber.Ctx) error {
 	if err := h.setRLSContext(ctx, tx, c); err != nil {
 		_ = h.storage.Provider.Delete(ctx, bucket, key)
 		log.Error().Err(err).Msg("Failed to set RLS context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to save file metadata",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to save file metadata", ErrCodeOperationFailed)
 	}
 
 	// Convert metadata map to JSONB
@@ -167,21 +151,18 @@ func (h *StorageHandler) StreamUpload(c fiber.Ctx) error {
 		if strings.Contains(errMsg, "permission denied") || strings.Contains(errMsg, "policy") {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"error":  "insufficient permissions to upload file",
+				"code":   ErrCodeInsufficientPermissions,
 				"detail": errMsg,
 			})
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to save file metadata",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to save file metadata", ErrCodeOperationFailed)
 	}
 
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		_ = h.storage.Provider.Delete(ctx, bucket, key)
 		log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to commit streaming file upload")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to save file metadata",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to save file metadata", ErrCodeOperationFailed)
 	}
 
 	log.Info().
@@ -191,6 +172,9 @@ func (h *StorageHandler) StreamUpload(c fiber.Ctx) error {
 		Str("user_id", ownerID).
 		Msg("File uploaded (streaming)")
 
+	h.purgeCDN(bucket, key)
+	h.fireObjectTriggers(c, bucket, key, contentType, object.Size)
+
 	// Add owner_id to response
 	response := map[string]interface{}{
 		"key":           object.Key,
@@ -202,6 +186,9 @@ func (h *StorageHandler) StreamUpload(c fiber.Ctx) error {
 	if ownerUUID != nil {
 		response["owner_id"] = *ownerUUID
 	}
+	if publicURL := h.cdnPublicURL(ctx, bucket, key); publicURL != "" {
+		response["public_url"] = publicURL
+	}
 
 	return c.Status(fiber.StatusCreated).JSON(response)
 }
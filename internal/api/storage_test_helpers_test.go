@@ -15,6 +15,7 @@ import (
 	"github.com/nimbleflux/fluxbase/internal/config"
 	"github.com/nimbleflux/fluxbase/internal/database"
 	"github.com/nimbleflux/fluxbase/internal/storage"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -195,3 +196,46 @@ func uploadTestFile(t *testing.T, app *fiber.App, bucket, path, content string)
 		t.Fatalf("Expected status 201 or 200, got %d", resp.StatusCode)
 	}
 }
+
+// newStorageAdminTestApp serves the admin-only bucket settings routes (CDN, lifecycle rules
+// and object triggers) as the given role, from a handler with the features configure sets
+func newStorageAdminTestApp(role string, configure ...func(h *StorageHandler)) *fiber.App {
+	h := &StorageHandler{}
+	for _, fn := range configure {
+		fn(h)
+	}
+
+	app := fiber.New()
+	app.Use(func(c fiber.Ctx) error {
+		c.Locals("user_role", role)
+		return c.Next()
+	})
+	app.Get("/buckets/:bucket/cdn", h.GetBucketCDN)
+	app.Put("/buckets/:bucket/cdn", h.PutBucketCDN)
+	app.Post("/buckets/:bucket/cdn/purge", h.PurgeBucketCDN)
+	app.Get("/buckets/:bucket/lifecycle", h.ListBucketLifecycle)
+	app.Post("/buckets/:bucket/lifecycle", h.CreateBucketLifecycleRule)
+	app.Put("/buckets/:bucket/lifecycle/:id", h.UpdateBucketLifecycleRule)
+	app.Delete("/buckets/:bucket/lifecycle/:id", h.DeleteBucketLifecycleRule)
+	app.Post("/buckets/:bucket/lifecycle/:id/dry-run", h.DryRunBucketLifecycleRule)
+	app.Get("/buckets/:bucket/triggers", h.ListBucketTriggers)
+	app.Post("/buckets/:bucket/triggers", h.CreateBucketTrigger)
+	app.Delete("/buckets/:bucket/triggers/:id", h.DeleteBucketTrigger)
+	return app
+}
+
+// assertStorageAdminOnly checks that a bucket settings route is forbidden to non-admins and
+// unavailable to admins while the feature configure sets up is not configured
+func assertStorageAdminOnly(t *testing.T, path string, configure func(h *StorageHandler)) {
+	t.Helper()
+
+	resp, err := newStorageAdminTestApp("authenticated", configure).Test(httptest.NewRequest("GET", path, nil))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	resp, err = newStorageAdminTestApp("admin").Test(httptest.NewRequest("GET", path, nil))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+}
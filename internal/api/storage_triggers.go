@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/functions"
+	"github.com/rs/zerolog/log"
+)
+
+// SetObjectTriggers enables edge functions triggered by uploads
+func (h *StorageHandler) SetObjectTriggers(triggers *functions.ObjectTriggers) {
+	h.objectTriggers = triggers
+}
+
+// fireObjectTriggers invokes the functions triggered by an uploaded object in the background,
+// as the uploading user
+func (h *StorageHandler) fireObjectTriggers(c fiber.Ctx, bucket, key, contentType string, size int64) {
+	if h.objectTriggers == nil {
+		return
+	}
+
+	event := functions.ObjectEvent{
+		Bucket:      bucket,
+		Key:         key,
+		ContentType: contentType,
+		Size:        size,
+		Open: func(ctx context.Context) (io.ReadCloser, error) {
+			reader, _, err := h.storage.Provider.Download(ctx, bucket, key, nil)
+			return reader, err
+		},
+	}
+	event.UserID, _ = c.Locals("user_id").(string)
+	event.UserEmail, _ = c.Locals("user_email").(string)
+	event.UserRole, _ = c.Locals("user_role").(string)
+
+	h.objectTriggers.Fire(event)
+}
+
+// ListBucketTriggers lists the functions triggered by uploads to a bucket
+// GET /api/v1/storage/buckets/:bucket/triggers
+func (h *StorageHandler) ListBucketTriggers(c fiber.Ctx) error {
+	if !isStorageAdmin(c) {
+		return SendForbidden(c, "Admin access required to manage bucket triggers", ErrCodeAdminRequired)
+	}
+	if h.objectTriggers == nil {
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "storage triggers are not available", ErrCodeServiceUnavailable)
+	}
+
+	bucket := c.Params("bucket")
+	triggers, err := h.objectTriggers.List(c.RequestCtx(), bucket)
+	if err != nil {
+		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to list storage triggers")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to list triggers", ErrCodeOperationFailed)
+	}
+
+	return c.JSON(fiber.Map{
+		"triggers": triggers,
+	})
+}
+
+// CreateBucketTrigger makes uploads to a bucket invoke an edge function with the object as
+// the request body
+// POST /api/v1/storage/buckets/:bucket/triggers {"function": "normalize-csv", "prefix": "imports/", "content_types": ["text/csv"]}
+func (h *StorageHandler) CreateBucketTrigger(c fiber.Ctx) error {
+	if !isStorageAdmin(c) {
+		return SendForbidden(c, "Admin access required to manage bucket triggers", ErrCodeAdminRequired)
+	}
+	if h.objectTriggers == nil {
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "storage triggers are not available", ErrCodeServiceUnavailable)
+	}
+
+	var req struct {
+		Function     string   `json:"function"`
+		Namespace    string   `json:"namespace"`
+		Prefix       string   `json:"prefix"`
+		ContentTypes []string `json:"content_types"`
+		MaxSizeBytes int64    `json:"max_size_bytes"`
+		Enabled      *bool    `json:"enabled"`
+	}
+	if err := c.Bind().Body(&req); err != nil {
+		return SendBadRequest(c, "invalid request body", ErrCodeInvalidBody)
+	}
+	if req.Function == "" {
+		return SendBadRequest(c, "function is required", ErrCodeMissingField)
+	}
+
+	trigger := &functions.StorageTrigger{
+		Bucket:            c.Params("bucket"),
+		FunctionName:      req.Function,
+		FunctionNamespace: req.Namespace,
+		Prefix:            req.Prefix,
+		ContentTypes:      req.ContentTypes,
+		MaxSizeBytes:      req.MaxSizeBytes,
+		Enabled:           req.Enabled == nil || *req.Enabled,
+		CreatedBy:         getUserIDFromContext(c),
+	}
+	if trigger.FunctionNamespace == "" {
+		trigger.FunctionNamespace = "default"
+	}
+	if trigger.MaxSizeBytes == 0 {
+		trigger.MaxSizeBytes = functions.DefaultTriggerMaxSize
+	}
+	if err := trigger.Validate(); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	err := h.objectTriggers.Create(c.RequestCtx(), trigger)
+	switch {
+	case errors.Is(err, functions.ErrTriggerBucketNotFound):
+		return SendNotFound(c, "bucket not found")
+	case errors.Is(err, functions.ErrStorageTriggerExists):
+		return SendConflict(c, err.Error(), ErrCodeConflict)
+	case errors.Is(err, functions.ErrTriggerFunctionNotFound):
+		return SendNotFound(c, "function not found")
+	case err != nil:
+		log.Error().Err(err).Str("bucket", trigger.Bucket).Msg("Failed to create storage trigger")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to create trigger", ErrCodeOperationFailed)
+	}
+
+	log.Info().
+		Str("bucket", trigger.Bucket).
+		Str("function", trigger.FunctionName).
+		Str("namespace", trigger.FunctionNamespace).
+		Str("prefix", trigger.Prefix).
+		Str("user_id", getUserID(c)).
+		Msg("Storage trigger created")
+
+	return c.Status(fiber.StatusCreated).JSON(trigger)
+}
+
+// DeleteBucketTrigger removes a function trigger from a bucket
+// DELETE /api/v1/storage/buckets/:bucket/triggers/:id
+func (h *StorageHandler) DeleteBucketTrigger(c fiber.Ctx) error {
+	if !isStorageAdmin(c) {
+		return SendForbidden(c, "Admin access required to manage bucket triggers", ErrCodeAdminRequired)
+	}
+	if h.objectTriggers == nil {
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "storage triggers are not available", ErrCodeServiceUnavailable)
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return SendBadRequest(c, "invalid trigger ID", ErrCodeInvalidID)
+	}
+
+	bucket := c.Params("bucket")
+	err = h.objectTriggers.Delete(c.RequestCtx(), bucket, id)
+	if errors.Is(err, functions.ErrStorageTriggerNotFound) {
+		return SendNotFound(c, "trigger not found")
+	}
+	if err != nil {
+		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to delete storage trigger")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to delete trigger", ErrCodeOperationFailed)
+	}
+
+	return c.Status(fiber.StatusNoContent).Send(nil)
+}
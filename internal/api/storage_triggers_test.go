@@ -0,0 +1,54 @@
+package api
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/functions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withTestTriggers configures a handler with object triggers
+func withTestTriggers(h *StorageHandler) {
+	h.SetObjectTriggers(functions.NewObjectTriggers(nil, "secret", "http://localhost:8080", nil))
+}
+
+func TestStorageTriggers_RequiresAdmin(t *testing.T) {
+	assertStorageAdminOnly(t, "/buckets/uploads/triggers", withTestTriggers)
+}
+
+func TestStorageTriggers_ValidatesRequests(t *testing.T) {
+	app := newStorageAdminTestApp("service_role", withTestTriggers)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		msg    string
+	}{
+		{"missing function", "POST", "/buckets/uploads/triggers", `{"prefix":"imports/"}`, "function is required"},
+		{"negative max size", "POST", "/buckets/uploads/triggers", `{"function":"normalize-csv","max_size_bytes":-1}`, "max_size_bytes must be positive"},
+		{"invalid content type", "POST", "/buckets/uploads/triggers", `{"function":"normalize-csv","content_types":["csv"]}`, "invalid content type"},
+		{"invalid trigger ID", "DELETE", "/buckets/uploads/triggers/abc", ``, "invalid trigger ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			body := new(strings.Builder)
+			_, _ = io.Copy(body, resp.Body)
+			assert.Contains(t, body.String(), tt.msg)
+		})
+	}
+}
@@ -0,0 +1,428 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// tusVersion is the tus protocol version served
+	tusVersion = "1.0.0"
+	// tusExtensions are the tus protocol extensions served
+	tusExtensions = "creation,termination,expiration"
+	// tusContentType is the content type of PATCH requests
+	tusContentType = "application/offset+octet-stream"
+)
+
+// tusReservedMetadata are the Upload-Metadata keys that describe the object rather than
+// being stored as its metadata
+var tusReservedMetadata = map[string]bool{
+	"objectName":   true,
+	"filename":     true,
+	"contentType":  true,
+	"filetype":     true,
+	"cacheControl": true,
+}
+
+// SetTUS enables resumable uploads through the tus protocol
+func (h *StorageHandler) SetTUS(store *storage.TUSStore, cfg *config.TUSConfig) {
+	h.tus = store
+	h.tusConfig = cfg
+}
+
+// tusResumable checks the protocol version of a tus request. It sets the Tus-Resumable
+// response header and returns false after answering 412 for unsupported versions.
+func tusResumable(c fiber.Ctx) bool {
+	c.Set("Tus-Resumable", tusVersion)
+	if c.Get("Tus-Resumable") != tusVersion {
+		c.Set("Tus-Version", tusVersion)
+		_ = SendErrorWithCode(c, fiber.StatusPreconditionFailed, "unsupported tus version, expected "+tusVersion, ErrCodeInvalidInput)
+		return false
+	}
+	return true
+}
+
+// setTUSUploadHeaders sets the headers describing the progress of an upload
+func setTUSUploadHeaders(c fiber.Ctx, u *storage.TUSUpload) {
+	c.Set("Upload-Offset", strconv.FormatInt(u.Offset, 10))
+	c.Set("Upload-Expires", u.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+}
+
+// TUSOptions advertises the tus protocol version, extensions and maximum upload size
+// OPTIONS /api/v1/storage/:bucket/tus
+func (h *StorageHandler) TUSOptions(c fiber.Ctx) error {
+	c.Set("Tus-Resumable", tusVersion)
+	c.Set("Tus-Version", tusVersion)
+	c.Set("Tus-Extension", tusExtensions)
+	c.Set("Tus-Max-Size", strconv.FormatInt(h.storage.MaxUploadSize(), 10))
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// CreateTUSUpload creates a resumable upload. The object path is taken from the objectName
+// (or filename) metadata, the content type from contentType (or filetype) and the cache
+// control from cacheControl. Other metadata is stored with the object. Size limits, allowed
+// MIME types and the bucket's policies are checked up front, so a client doesn't upload a
+// file that would be rejected.
+// POST /api/v1/storage/:bucket/tus
+// Upload-Length: 1073741824
+// Upload-Metadata: objectName dmlkZW9zL2ludHJvLm1wNA==,contentType dmlkZW8vbXA0
+func (h *StorageHandler) CreateTUSUpload(c fiber.Ctx) error {
+	if !tusResumable(c) {
+		return nil
+	}
+	bucket := c.Params("bucket")
+
+	if c.Get("Upload-Defer-Length") != "" {
+		return SendBadRequest(c, "Upload-Defer-Length is not supported", ErrCodeInvalidInput)
+	}
+	length, err := strconv.ParseInt(c.Get("Upload-Length"), 10, 64)
+	if err != nil || length <= 0 {
+		return SendBadRequest(c, "Upload-Length must be a positive integer", ErrCodeInvalidInput)
+	}
+	if err := h.storage.ValidateUploadSize(length); err != nil {
+		return SendErrorWithCode(c, fiber.StatusRequestEntityTooLarge, err.Error(), ErrCodePayloadTooLarge)
+	}
+
+	metadata, err := storage.ParseTUSMetadata(c.Get("Upload-Metadata"))
+	if err != nil {
+		return SendBadRequest(c, "invalid Upload-Metadata: "+err.Error(), ErrCodeInvalidInput)
+	}
+	key := metadata["objectName"]
+	if key == "" {
+		key = metadata["filename"]
+	}
+	key = sanitizeFilename(key)
+	if key == "" || key == "." {
+		return SendBadRequest(c, "Upload-Metadata must include objectName", ErrCodeMissingField)
+	}
+	contentType := metadata["contentType"]
+	if contentType == "" {
+		contentType = metadata["filetype"]
+	}
+	if contentType == "" {
+		contentType = detectContentType(key)
+	}
+	objectMetadata := map[string]string{}
+	for k, v := range metadata {
+		if !tusReservedMetadata[k] {
+			objectMetadata[k] = v
+		}
+	}
+
+	ctx := c.RequestCtx()
+
+	// Use SECURITY DEFINER functions to bypass RLS when reading the bucket
+	var bucketExists bool
+	if err := h.db.Pool().QueryRow(ctx, `SELECT storage.bucket_exists($1)`, bucket).Scan(&bucketExists); err != nil {
+		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to check bucket existence")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to validate bucket", ErrCodeOperationFailed)
+	}
+	if !bucketExists {
+		return SendNotFound(c, fmt.Sprintf("bucket '%s' does not exist", bucket))
+	}
+
+	var bucketMaxFileSize *int64
+	var bucketAllowedMimeTypes []string
+	err = h.db.Pool().QueryRow(ctx,
+		`SELECT max_file_size, allowed_mime_types FROM storage.get_bucket_settings($1)`,
+		bucket,
+	).Scan(&bucketMaxFileSize, &bucketAllowedMimeTypes)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Error().Err(err).Str("bucket", bucket).Msg("Failed to get bucket settings")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to validate bucket settings", ErrCodeOperationFailed)
+	}
+	if bucketMaxFileSize != nil && *bucketMaxFileSize > 0 && length > *bucketMaxFileSize {
+		return SendErrorWithCode(c, fiber.StatusRequestEntityTooLarge, fmt.Sprintf("file size %d exceeds bucket maximum of %d bytes", length, *bucketMaxFileSize), ErrCodePayloadTooLarge)
+	}
+	if len(bucketAllowedMimeTypes) > 0 && !mimeTypeAllowed(bucketAllowedMimeTypes, contentType) {
+		return SendErrorWithCode(c, fiber.StatusUnsupportedMediaType, fmt.Sprintf("file type %s is not allowed for this bucket", contentType), ErrCodeUnsupportedMediaType)
+	}
+
+	// Check the bucket's policies by saving the object record in a transaction that is
+	// rolled back
+	if err := h.saveObjectRecord(ctx, c, bucket, key, contentType, length, objectMetadata, false); err != nil {
+		return h.sendObjectRecordError(c, err, bucket, key)
+	}
+
+	uploader, ok := h.storage.Provider.(storage.ChunkedUploader)
+	if !ok {
+		return SendErrorWithCode(c, fiber.StatusNotImplemented, "storage provider does not support resumable uploads", ErrCodeNotImplemented)
+	}
+
+	id, err := storage.NewTUSUploadID()
+	if err != nil {
+		return SendInternalError(c, "failed to create upload")
+	}
+	session, err := uploader.InitChunkedUpload(ctx, bucket, key, length, h.tusConfig.ChunkSize, &storage.UploadOptions{
+		ContentType:  contentType,
+		Metadata:     objectMetadata,
+		CacheControl: metadata["cacheControl"],
+	})
+	if err != nil {
+		log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to initialize resumable upload")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to create upload", ErrCodeOperationFailed)
+	}
+
+	upload := &storage.TUSUpload{
+		ID:           id,
+		Bucket:       bucket,
+		Path:         key,
+		Length:       length,
+		ChunkSize:    h.tusConfig.ChunkSize,
+		ContentType:  contentType,
+		CacheControl: metadata["cacheControl"],
+		Metadata:     objectMetadata,
+		Session:      session,
+		ExpiresAt:    time.Now().Add(h.tusConfig.Expiration),
+	}
+	session.ExpiresAt = upload.ExpiresAt
+	if ownerID := getUserID(c); ownerID != "anonymous" {
+		upload.OwnerID = &ownerID
+	}
+	if err := h.tus.Create(ctx, upload); err != nil {
+		_ = uploader.AbortChunkedUpload(ctx, session)
+		log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to record resumable upload")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to create upload", ErrCodeOperationFailed)
+	}
+
+	log.Info().
+		Str("upload_id", id).
+		Str("bucket", bucket).
+		Str("key", key).
+		Int64("length", length).
+		Msg("Resumable upload created")
+
+	c.Set(fiber.HeaderLocation, strings.TrimSuffix(c.Path(), "/")+"/"+id)
+	c.Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	return c.SendStatus(fiber.StatusCreated)
+}
+
+// getTUSUpload returns the upload in the path if it belongs to the requester, answering
+// the request otherwise
+func (h *StorageHandler) getTUSUpload(c fiber.Ctx) (*storage.TUSUpload, bool) {
+	upload, err := h.tus.Get(c.RequestCtx(), c.Params("uploadId"))
+	if errors.Is(err, storage.ErrTUSUploadNotFound) {
+		_ = SendResourceNotFound(c, "Upload")
+		return nil, false
+	}
+	if err != nil {
+		log.Error().Err(err).Str("upload_id", c.Params("uploadId")).Msg("Failed to get resumable upload")
+		_ = SendOperationFailed(c, "get upload")
+		return nil, false
+	}
+
+	owner := "anonymous"
+	if upload.OwnerID != nil {
+		owner = *upload.OwnerID
+	}
+	if upload.Bucket != c.Params("bucket") || owner != getUserID(c) {
+		_ = SendResourceNotFound(c, "Upload")
+		return nil, false
+	}
+	if time.Now().After(upload.ExpiresAt) {
+		_ = SendErrorWithCode(c, fiber.StatusGone, "upload has expired", ErrCodeGone)
+		return nil, false
+	}
+	return upload, true
+}
+
+// GetTUSUploadOffset returns how many bytes of an upload were stored
+// HEAD /api/v1/storage/:bucket/tus/:uploadId
+func (h *StorageHandler) GetTUSUploadOffset(c fiber.Ctx) error {
+	if !tusResumable(c) {
+		return nil
+	}
+	upload, ok := h.getTUSUpload(c)
+	if !ok {
+		return nil
+	}
+
+	setTUSUploadHeaders(c, upload)
+	c.Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// PatchTUSUpload appends the body to an upload at Upload-Offset. Data is stored in chunks
+// of the configured chunk size, so a request must carry at least one chunk, or the rest of
+// the upload. Bytes past the last whole chunk are not stored: the client resumes from the
+// returned Upload-Offset. The object is created once the last byte is stored.
+// PATCH /api/v1/storage/:bucket/tus/:uploadId
+func (h *StorageHandler) PatchTUSUpload(c fiber.Ctx) error {
+	if !tusResumable(c) {
+		return nil
+	}
+	if c.Get(fiber.HeaderContentType) != tusContentType {
+		return SendErrorWithCode(c, fiber.StatusUnsupportedMediaType, "Content-Type must be "+tusContentType, ErrCodeUnsupportedMediaType)
+	}
+	offset, err := strconv.ParseInt(c.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return SendBadRequest(c, "Upload-Offset must be a non-negative integer", ErrCodeInvalidInput)
+	}
+
+	upload, ok := h.getTUSUpload(c)
+	if !ok {
+		return nil
+	}
+	if offset != upload.Offset {
+		setTUSUploadHeaders(c, upload)
+		return SendConflict(c, fmt.Sprintf("Upload-Offset %d does not match the upload offset %d", offset, upload.Offset), ErrCodeConflict)
+	}
+	if upload.Complete() {
+		setTUSUploadHeaders(c, upload)
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+
+	// A negative Content-Length means the body is streamed without a declared length
+	if size := int64(c.Request().Header.ContentLength()); size >= 0 {
+		_, chunk := upload.NextChunk()
+		if offset+size > upload.Length {
+			return SendErrorWithCode(c, fiber.StatusRequestEntityTooLarge, "request body exceeds Upload-Length", ErrCodePayloadTooLarge)
+		}
+		if size < chunk {
+			return SendBadRequest(c, fmt.Sprintf("requests must carry at least %d bytes, the configured chunk size, or the rest of the upload", chunk), ErrCodeInvalidInput)
+		}
+	}
+
+	var body io.Reader = c.Request().BodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.Body())
+	}
+
+	ctx := c.RequestCtx()
+	if err := h.storeTUSChunks(ctx, upload, bufio.NewReader(body)); err != nil {
+		if errors.Is(err, storage.ErrTUSOffsetMismatch) {
+			return SendConflict(c, "upload was modified by another request", ErrCodeConflict)
+		}
+		log.Error().Err(err).Str("upload_id", upload.ID).Int64("offset", upload.Offset).Msg("Failed to store resumable upload chunk")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to store upload data", ErrCodeOperationFailed)
+	}
+
+	if upload.Complete() {
+		if err := h.finishTUSUpload(ctx, c, upload); err != nil {
+			return err
+		}
+	}
+
+	setTUSUploadHeaders(c, upload)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// storeTUSChunks stores whole chunks read from body until it ends or the upload is
+// complete, recording the offset after each chunk
+func (h *StorageHandler) storeTUSChunks(ctx context.Context, upload *storage.TUSUpload, body *bufio.Reader) error {
+	uploader, ok := h.storage.Provider.(storage.ChunkedUploader)
+	if !ok {
+		return errors.New("storage provider does not support resumable uploads")
+	}
+
+	for !upload.Complete() {
+		if _, err := body.Peek(1); err != nil {
+			return nil
+		}
+
+		index, size := upload.NextChunk()
+		result, err := uploader.UploadChunk(ctx, upload.Session, index, io.LimitReader(body, size), size)
+		if err != nil {
+			// A body that ends mid-chunk fails the chunk, which is sent again on resume
+			if _, peekErr := body.Peek(1); peekErr != nil {
+				log.Debug().Err(err).Str("upload_id", upload.ID).Int("chunk", index).Msg("Resumable upload request ended mid-chunk")
+				return nil
+			}
+			return err
+		}
+		if result.Size < size {
+			return nil
+		}
+
+		if !slices.Contains(upload.Session.CompletedChunks, index) {
+			upload.Session.CompletedChunks = append(upload.Session.CompletedChunks, index)
+		}
+		if upload.Session.S3PartETags == nil {
+			upload.Session.S3PartETags = make(map[int]string)
+		}
+		upload.Session.S3PartETags[index] = result.ETag
+
+		from := upload.Offset
+		upload.Offset += size
+		if err := h.tus.Advance(ctx, upload, from); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finishTUSUpload assembles the stored chunks into the object and records it. It answers
+// the request itself when the upload can't be finished.
+func (h *StorageHandler) finishTUSUpload(ctx context.Context, c fiber.Ctx, upload *storage.TUSUpload) error {
+	uploader := h.storage.Provider.(storage.ChunkedUploader)
+
+	object, err := uploader.CompleteChunkedUpload(ctx, upload.Session)
+	if err != nil {
+		log.Error().Err(err).Str("upload_id", upload.ID).Msg("Failed to complete resumable upload")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to complete upload", ErrCodeOperationFailed)
+	}
+
+	if err := h.saveObjectRecord(ctx, c, upload.Bucket, upload.Path, upload.ContentType, object.Size, upload.Metadata, true); err != nil {
+		// The policies changed since the upload was created
+		_ = h.storage.Provider.Delete(ctx, upload.Bucket, upload.Path)
+		_ = h.tus.Delete(ctx, upload.ID)
+		return h.sendObjectRecordError(c, err, upload.Bucket, upload.Path)
+	}
+	if err := h.tus.Delete(ctx, upload.ID); err != nil {
+		log.Warn().Err(err).Str("upload_id", upload.ID).Msg("Failed to delete finished resumable upload")
+	}
+
+	log.Info().
+		Str("upload_id", upload.ID).
+		Str("bucket", upload.Bucket).
+		Str("key", upload.Path).
+		Int64("size", object.Size).
+		Str("user_id", getUserID(c)).
+		Msg("Resumable upload completed")
+
+	h.purgeCDN(upload.Bucket, upload.Path)
+	h.fireObjectTriggers(c, upload.Bucket, upload.Path, upload.ContentType, object.Size)
+	return nil
+}
+
+// TerminateTUSUpload aborts an upload and deletes its stored data
+// DELETE /api/v1/storage/:bucket/tus/:uploadId
+func (h *StorageHandler) TerminateTUSUpload(c fiber.Ctx) error {
+	if !tusResumable(c) {
+		return nil
+	}
+	upload, ok := h.getTUSUpload(c)
+	if !ok {
+		return nil
+	}
+
+	ctx := c.RequestCtx()
+	if uploader, ok := h.storage.Provider.(storage.ChunkedUploader); ok {
+		if err := uploader.AbortChunkedUpload(ctx, upload.Session); err != nil {
+			log.Warn().Err(err).Str("upload_id", upload.ID).Msg("Failed to abort resumable upload")
+		}
+	}
+	if err := h.tus.Delete(ctx, upload.ID); err != nil && !errors.Is(err, storage.ErrTUSUploadNotFound) {
+		log.Error().Err(err).Str("upload_id", upload.ID).Msg("Failed to delete resumable upload")
+		return SendOperationFailed(c, "terminate upload")
+	}
+
+	log.Info().Str("upload_id", upload.ID).Str("bucket", upload.Bucket).Msg("Resumable upload terminated")
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// TUS Request Validation Tests
+// =============================================================================
+
+func TestStorageHandler_TUS_Validation(t *testing.T) {
+	handler := &StorageHandler{}
+
+	app := setupTestFiberApp()
+	app.Post("/storage/:bucket/tus", handler.CreateTUSUpload)
+	app.Patch("/storage/:bucket/tus/:uploadId", handler.PatchTUSUpload)
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		status  int
+	}{
+		{
+			name:   "create without Tus-Resumable",
+			method: http.MethodPost,
+			headers: map[string]string{
+				"Upload-Length": "100",
+			},
+			status: http.StatusPreconditionFailed,
+		},
+		{
+			name:   "create with unsupported version",
+			method: http.MethodPost,
+			headers: map[string]string{
+				"Tus-Resumable": "0.2.2",
+				"Upload-Length": "100",
+			},
+			status: http.StatusPreconditionFailed,
+		},
+		{
+			name:   "create with deferred length",
+			method: http.MethodPost,
+			headers: map[string]string{
+				"Tus-Resumable":       tusVersion,
+				"Upload-Defer-Length": "1",
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "create with invalid length",
+			method: http.MethodPost,
+			headers: map[string]string{
+				"Tus-Resumable": tusVersion,
+				"Upload-Length": "-1",
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "patch with wrong content type",
+			method: http.MethodPatch,
+			headers: map[string]string{
+				"Tus-Resumable": tusVersion,
+				"Content-Type":  "application/json",
+				"Upload-Offset": "0",
+			},
+			status: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:   "patch without offset",
+			method: http.MethodPatch,
+			headers: map[string]string{
+				"Tus-Resumable": tusVersion,
+				"Content-Type":  tusContentType,
+			},
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := "/storage/uploads/tus"
+			if tt.method == http.MethodPatch {
+				path += "/abc"
+			}
+			req := httptest.NewRequest(tt.method, path, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tusVersion, resp.Header.Get("Tus-Resumable"))
+		})
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/auth"
 	"github.com/rs/zerolog/log"
 )
 
@@ -98,15 +100,21 @@ func (h *StorageHandler) setRLSContext(ctx context.Context, tx pgx.Tx, c fiber.C
 	}
 
 	// Set request.jwt.claims with user ID and role (Supabase/Fluxbase format)
-	// This is read by auth.current_user_id() and auth.current_user_role() functions
-	var jwtClaims string
+	// This is read by auth.current_user_id(), auth.current_user_role() and auth.current_org_id()
+	jwtClaims := map[string]any{"role": roleStr}
 	if userIDStr != "" {
-		jwtClaims = fmt.Sprintf(`{"sub":"%s","role":"%s"}`, userIDStr, roleStr)
-	} else {
-		jwtClaims = fmt.Sprintf(`{"role":"%s"}`, roleStr)
+		jwtClaims["sub"] = userIDStr
+	}
+	if claims, ok := c.Locals("jwt_claims").(*auth.TokenClaims); ok && claims.OrgID != "" {
+		jwtClaims["org_id"] = claims.OrgID
+		jwtClaims["org_role"] = claims.OrgRole
+	}
+	jwtClaimsJSON, err := json.Marshal(jwtClaims)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JWT claims: %w", err)
 	}
 
-	if _, err := tx.Exec(ctx, "SELECT set_config('request.jwt.claims', $1, true)", jwtClaims); err != nil {
+	if _, err := tx.Exec(ctx, "SELECT set_config('request.jwt.claims', $1, true)", string(jwtClaimsJSON)); err != nil {
 		return fmt.Errorf("failed to set request.jwt.claims: %w", err)
 	}
 
@@ -148,3 +156,66 @@ func sanitizeFilename(filename string) string {
 
 	return filename
 }
+
+// saveObjectRecord records an object as the requester, so the bucket's policies apply. The
+// record is only kept when commit is set.
+func (h *StorageHandler) saveObjectRecord(ctx context.Context, c fiber.Ctx, bucket, key, contentType string, size int64, metadata map[string]string, commit bool) error {
+	tx, err := h.beginObjectRecord(ctx, c, bucket, key, contentType, size, metadata)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if !commit {
+		return nil
+	}
+	return tx.Commit(ctx)
+}
+
+// beginObjectRecord records an object as the requester in a transaction the caller commits
+// or rolls back, so a policy refusal is known before the object's content is written
+func (h *StorageHandler) beginObjectRecord(ctx context.Context, c fiber.Ctx, bucket, key, contentType string, size int64, metadata map[string]string) (pgx.Tx, error) {
+	tx, err := h.db.Pool().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.setRLSContext(ctx, tx, c); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+
+	var metadataJSON map[string]any
+	if len(metadata) > 0 {
+		metadataJSON = make(map[string]any, len(metadata))
+		for k, v := range metadata {
+			metadataJSON[k] = v
+		}
+	}
+	var ownerUUID *string
+	if ownerID := getUserID(c); ownerID != "anonymous" {
+		ownerUUID = &ownerID
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO storage.objects (bucket_id, path, mime_type, size, metadata, owner_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (bucket_id, path)
+		DO UPDATE SET mime_type = $3, size = $4, metadata = $5, owner_id = $6, updated_at = NOW()
+	`, bucket, key, contentType, size, metadataJSON, ownerUUID)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+	return tx, nil
+}
+
+// sendObjectRecordError answers a request whose object record could not be saved
+func (h *StorageHandler) sendObjectRecordError(c fiber.Ctx, err error, bucket, key string) error {
+	errMsg := err.Error()
+	if strings.Contains(errMsg, "permission denied") || strings.Contains(errMsg, "policy") {
+		return SendErrorWithCode(c, fiber.StatusForbidden, "insufficient permissions to upload file", ErrCodeInsufficientPermissions)
+	}
+	log.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("Failed to save object metadata")
+	return SendErrorWithCode(c, fiber.StatusInternalServerError, "failed to save file metadata", ErrCodeOperationFailed)
+}
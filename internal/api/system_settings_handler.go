@@ -32,17 +32,13 @@ func (h *SystemSettingsHandler) ListSettings(c fiber.Ctx) error {
 
 	// Check if settings service is available
 	if h.settingsService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Settings service not initialized",
-		})
+		return SendInternalError(c, "Settings service not initialized")
 	}
 
 	settings, err := h.settingsService.ListSettings(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get system settings")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve system settings",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve system settings", ErrCodeOperationFailed)
 	}
 
 	// Populate override information for each setting
@@ -55,6 +51,13 @@ func (h *SystemSettingsHandler) ListSettings(c fiber.Ctx) error {
 		}
 	}
 
+	// Explain each setting and flag conflicts between them
+	warnings := h.settingWarnings(ctx, "", nil)
+	for i := range settings {
+		settings[i].Metadata = getSettingMetadata(settings[i].Key)
+		settings[i].Warnings = warningsForSetting(warnings, settings[i].Key)
+	}
+
 	return c.JSON(settings)
 }
 
@@ -65,16 +68,12 @@ func (h *SystemSettingsHandler) GetSetting(c fiber.Ctx) error {
 	key := c.Params("*")
 
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting key is required",
-		})
+		return SendBadRequest(c, "Setting key is required", ErrCodeMissingField)
 	}
 
 	// Check if settings service is available
 	if h.settingsService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Settings service not initialized",
-		})
+		return SendInternalError(c, "Settings service not initialized")
 	}
 
 	setting, err := h.settingsService.GetSetting(ctx, key)
@@ -89,16 +88,14 @@ func (h *SystemSettingsHandler) GetSetting(c fiber.Ctx) error {
 						defaultSetting.OverrideSource = h.settingsCache.GetEnvVarName(key)
 					}
 				}
+				defaultSetting.Metadata = getSettingMetadata(key)
+				defaultSetting.Warnings = h.settingWarnings(ctx, key, nil)
 				return c.JSON(defaultSetting)
 			}
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Setting not found",
-			})
+			return SendNotFound(c, "Setting not found")
 		}
 		log.Error().Err(err).Str("key", key).Msg("Failed to get setting")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve setting",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve setting", ErrCodeOperationFailed)
 	}
 
 	// Populate override information
@@ -108,6 +105,8 @@ func (h *SystemSettingsHandler) GetSetting(c fiber.Ctx) error {
 			setting.OverrideSource = h.settingsCache.GetEnvVarName(key)
 		}
 	}
+	setting.Metadata = getSettingMetadata(key)
+	setting.Warnings = h.settingWarnings(ctx, key, nil)
 
 	return c.JSON(setting)
 }
@@ -119,9 +118,7 @@ func (h *SystemSettingsHandler) UpdateSetting(c fiber.Ctx) error {
 	key := c.Params("*")
 
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting key is required",
-		})
+		return SendBadRequest(c, "Setting key is required", ErrCodeMissingField)
 	}
 
 	var req struct {
@@ -131,17 +128,12 @@ func (h *SystemSettingsHandler) UpdateSetting(c fiber.Ctx) error {
 
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse request body")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate setting key is in allowlist
 	if !h.isValidSettingKey(key) {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid setting key",
-			"code":  "INVALID_SETTING_KEY",
-		})
+		return SendBadRequest(c, "Invalid setting key", "INVALID_SETTING_KEY")
 	}
 
 	// Check if setting is overridden by environment variable
@@ -155,20 +147,20 @@ func (h *SystemSettingsHandler) UpdateSetting(c fiber.Ctx) error {
 
 	// Check if settings service is available
 	if h.settingsService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Settings service not initialized",
-		})
+		return SendInternalError(c, "Settings service not initialized")
 	}
 
 	if err := h.settingsService.SetSetting(ctx, key, req.Value, req.Description); err != nil {
 		log.Error().Err(err).Str("key", key).Msg("Failed to update setting")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update setting",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to update setting", ErrCodeOperationFailed)
 	}
 
 	log.Info().Str("key", key).Interface("value", req.Value).Msg("System setting updated")
 
+	// The change is saved either way; warnings tell the dashboard which other settings now conflict.
+	// The new value is passed explicitly because the settings cache may still hold the old one.
+	warnings := h.settingWarnings(ctx, key, map[string]interface{}{key: req.Value["value"]})
+
 	// Return the updated setting
 	setting, err := h.settingsService.GetSetting(ctx, key)
 	if err != nil {
@@ -177,8 +169,12 @@ func (h *SystemSettingsHandler) UpdateSetting(c fiber.Ctx) error {
 			"key":         key,
 			"value":       req.Value,
 			"description": req.Description,
+			"metadata":    getSettingMetadata(key),
+			"warnings":    warnings,
 		})
 	}
+	setting.Metadata = getSettingMetadata(key)
+	setting.Warnings = warnings
 
 	return c.JSON(setting)
 }
@@ -190,28 +186,20 @@ func (h *SystemSettingsHandler) DeleteSetting(c fiber.Ctx) error {
 	key := c.Params("*")
 
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting key is required",
-		})
+		return SendBadRequest(c, "Setting key is required", ErrCodeMissingField)
 	}
 
 	// Check if settings service is available
 	if h.settingsService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Settings service not initialized",
-		})
+		return SendInternalError(c, "Settings service not initialized")
 	}
 
 	if err := h.settingsService.DeleteSetting(ctx, key); err != nil {
 		if errors.Is(err, auth.ErrSettingNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Setting not found",
-			})
+			return SendNotFound(c, "Setting not found")
 		}
 		log.Error().Err(err).Str("key", key).Msg("Failed to delete setting")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete setting",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to delete setting", ErrCodeOperationFailed)
 	}
 
 	log.Info().Str("key", key).Msg("System setting deleted")
@@ -225,6 +213,7 @@ var settingDefaults = map[string]map[string]interface{}{
 	"app.auth.magic_link_enabled":           {"value": false},
 	"app.auth.password_min_length":          {"value": 12},
 	"app.auth.require_email_verification":   {"value": false},
+	"app.auth.email_normalization_enabled":  {"value": true},
 	"app.realtime.enabled":                  {"value": true},
 	"app.storage.enabled":                   {"value": true},
 	"app.functions.enabled":                 {"value": true},
@@ -234,6 +223,7 @@ var settingDefaults = map[string]map[string]interface{}{
 	"app.email.enabled":                     {"value": true},
 	"app.email.provider":                    {"value": ""},
 	"app.security.enable_global_rate_limit": {"value": true},
+	"app.public_base_url":                   {"value": ""},
 	// Email provider settings (for UI configuration)
 	"app.email.from_address":     {"value": ""},
 	"app.email.from_name":        {"value": ""},
@@ -257,6 +247,13 @@ var settingDefaults = map[string]map[string]interface{}{
 	"app.security.captcha.endpoints":       {"value": []string{"signup", "login", "password_reset", "magic_link"}},
 	"app.security.captcha.cap_server_url":  {"value": ""},
 	"app.security.captcha.cap_api_key":     {"value": ""}, // Encrypted in database
+	// Security headers (empty values keep the configured policy)
+	"app.security.headers.content_security_policy":           {"value": ""},
+	"app.security.headers.dashboard_content_security_policy": {"value": ""},
+	"app.security.headers.x_frame_options":                   {"value": ""},
+	"app.security.headers.strict_transport_security":         {"value": ""},
+	"app.security.headers.referrer_policy":                   {"value": ""},
+	"app.security.headers.permissions_policy":                {"value": ""},
 }
 
 // isValidSettingKey checks if a setting key is in the allowlist
@@ -0,0 +1,358 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/nimbleflux/fluxbase/internal/auth"
+)
+
+// settingMetadata explains each setting in settingDefaults for the dashboard.
+// Email and captcha provider settings are loaded when their settings page is saved or on
+// startup, so changing them through the generic settings API only takes effect after a restart.
+var settingMetadata = map[string]auth.SettingMetadata{
+	"app.auth.signup_enabled": {
+		Explanation:     "Allows anyone to create an account. When disabled, users can only be created by an admin or by invitation.",
+		AffectsSecurity: true,
+	},
+	"app.auth.magic_link_enabled": {
+		Explanation:     "Allows users to sign in with a one-time link sent by email. Requires a working email provider.",
+		AffectsSecurity: true,
+	},
+	"app.auth.password_min_length": {
+		Explanation:     "Minimum number of characters for new passwords. Existing passwords are not re-checked.",
+		AffectsSecurity: true,
+	},
+	"app.auth.require_email_verification": {
+		Explanation:     "Users must confirm their email address before they can sign in. Requires a working email provider.",
+		AffectsSecurity: true,
+	},
+	"app.auth.email_normalization_enabled": {
+		Explanation:     "Treats email addresses that differ only in case or provider-specific aliases as the same account.",
+		AffectsSecurity: true,
+	},
+	"app.realtime.enabled": {
+		Explanation: "Enables the realtime API. When disabled, realtime requests are rejected.",
+	},
+	"app.storage.enabled": {
+		Explanation: "Enables the storage API. When disabled, storage requests are rejected; stored files are kept.",
+	},
+	"app.functions.enabled": {
+		Explanation: "Enables edge function invocation. When disabled, function requests are rejected.",
+	},
+	"app.ai.enabled": {
+		Explanation: "Enables the AI APIs, including chatbots and knowledge bases.",
+	},
+	"app.rpc.enabled": {
+		Explanation: "Enables RPC procedure calls. When disabled, RPC requests are rejected.",
+	},
+	"app.jobs.enabled": {
+		Explanation: "Enables the background jobs API. When disabled, jobs cannot be submitted.",
+	},
+	"app.public_base_url": {
+		Explanation:     "Public URL of this instance, used for links in emails and OAuth callbacks. Set during instance bootstrap; the public_base_url config takes precedence.",
+		RequiresRestart: true,
+	},
+	"app.email.enabled": {
+		Explanation:     "Enables sending email. Verification, magic link and password reset emails depend on it.",
+		RequiresRestart: true,
+	},
+	"app.email.provider": {
+		Explanation:     "Service used to send email: smtp, sendgrid, mailgun or ses.",
+		RequiresRestart: true,
+	},
+	"app.email.from_address": {
+		Explanation:     "Sender address for outgoing email.",
+		RequiresRestart: true,
+	},
+	"app.email.from_name": {
+		Explanation:     "Sender name shown with the from address.",
+		RequiresRestart: true,
+	},
+	"app.email.smtp_host": {
+		Explanation:     "Hostname of the SMTP server, used when the provider is smtp.",
+		RequiresRestart: true,
+	},
+	"app.email.smtp_port": {
+		Explanation:     "Port of the SMTP server, usually 587 for STARTTLS or 465 for implicit TLS.",
+		RequiresRestart: true,
+	},
+	"app.email.smtp_username": {
+		Explanation:     "Username for SMTP authentication.",
+		RequiresRestart: true,
+	},
+	"app.email.smtp_password": {
+		Explanation:     "Password for SMTP authentication. Stored encrypted.",
+		RequiresRestart: true,
+		AffectsSecurity: true,
+	},
+	"app.email.smtp_tls": {
+		Explanation:     "Encrypts the connection to the SMTP server. Disabling it sends credentials and email in plain text.",
+		RequiresRestart: true,
+		AffectsSecurity: true,
+	},
+	"app.email.sendgrid_api_key": {
+		Explanation:     "SendGrid API key, used when the provider is sendgrid. Stored encrypted.",
+		RequiresRestart: true,
+		AffectsSecurity: true,
+	},
+	"app.email.mailgun_api_key": {
+		Explanation:     "Mailgun API key, used when the provider is mailgun. Stored encrypted.",
+		RequiresRestart: true,
+		AffectsSecurity: true,
+	},
+	"app.email.mailgun_domain": {
+		Explanation:     "Mailgun sending domain, used when the provider is mailgun.",
+		RequiresRestart: true,
+	},
+	"app.email.ses_access_key": {
+		Explanation:     "AWS access key for SES, used when the provider is ses. Stored encrypted.",
+		RequiresRestart: true,
+		AffectsSecurity: true,
+	},
+	"app.email.ses_secret_key": {
+		Explanation:     "AWS secret key for SES, used when the provider is ses. Stored encrypted.",
+		RequiresRestart: true,
+		AffectsSecurity: true,
+	},
+	"app.email.ses_region": {
+		Explanation:     "AWS region of the SES endpoint.",
+		RequiresRestart: true,
+	},
+	"app.security.enable_global_rate_limit": {
+		Explanation:     "Limits the request rate of every client across the whole API, in addition to endpoint-specific limits.",
+		AffectsSecurity: true,
+	},
+	"app.security.captcha.enabled": {
+		Explanation:     "Requires a captcha on the protected auth endpoints to block automated signups and credential stuffing.",
+		RequiresRestart: true,
+		AffectsSecurity: true,
+	},
+	"app.security.captcha.provider": {
+		Explanation:     "Captcha service: hcaptcha, recaptcha_v3, turnstile or cap.",
+		RequiresRestart: true,
+		AffectsSecurity: true,
+	},
+	"app.security.captcha.site_key": {
+		Explanation:     "Public site key of the captcha provider, sent to clients.",
+		RequiresRestart: true,
+	},
+	"app.security.captcha.secret_key": {
+		Explanation:     "Secret key used to verify captcha responses. Stored encrypted.",
+		RequiresRestart: true,
+		AffectsSecurity: true,
+	},
+	"app.security.captcha.score_threshold": {
+		Explanation:     "Minimum reCAPTCHA v3 score (0.0-1.0) to accept a request. Higher values block more bots and more real users.",
+		RequiresRestart: true,
+		AffectsSecurity: true,
+	},
+	"app.security.captcha.endpoints": {
+		Explanation:     "Auth endpoints that require a captcha: signup, login, password_reset and magic_link.",
+		RequiresRestart: true,
+		AffectsSecurity: true,
+	},
+	"app.security.captcha.cap_server_url": {
+		Explanation:     "URL of the self-hosted Cap server, used when the provider is cap.",
+		RequiresRestart: true,
+	},
+	"app.security.captcha.cap_api_key": {
+		Explanation:     "API key for the Cap server. Stored encrypted.",
+		RequiresRestart: true,
+		AffectsSecurity: true,
+	},
+	"app.security.headers.content_security_policy": {
+		Explanation:     "Content-Security-Policy header for API responses. Empty keeps the configured policy.",
+		AffectsSecurity: true,
+	},
+	"app.security.headers.dashboard_content_security_policy": {
+		Explanation:     "Content-Security-Policy header for the admin dashboard. Empty keeps the configured policy.",
+		AffectsSecurity: true,
+	},
+	"app.security.headers.x_frame_options": {
+		Explanation:     "X-Frame-Options header, which controls whether pages may be embedded in frames. Empty keeps the configured value.",
+		AffectsSecurity: true,
+	},
+	"app.security.headers.strict_transport_security": {
+		Explanation:     "Strict-Transport-Security header, which makes browsers use HTTPS only. Empty keeps the configured value.",
+		AffectsSecurity: true,
+	},
+	"app.security.headers.referrer_policy": {
+		Explanation:     "Referrer-Policy header. Empty keeps the configured value.",
+		AffectsSecurity: true,
+	},
+	"app.security.headers.permissions_policy": {
+		Explanation:     "Permissions-Policy header, which restricts browser features. Empty keeps the configured value.",
+		AffectsSecurity: true,
+	},
+}
+
+// getSettingMetadata returns the metadata for a setting, nil for unknown keys
+func getSettingMetadata(key string) *auth.SettingMetadata {
+	metadata, exists := settingMetadata[key]
+	if !exists {
+		return nil
+	}
+	return &metadata
+}
+
+// settingDependencyRule flags a combination of setting values that doesn't work together
+type settingDependencyRule struct {
+	Code     string
+	Severity string
+	Settings []string
+	Message  string
+	// Conflicts reports whether the rule is violated, reading effective values through get
+	Conflicts func(get func(key string) interface{}) bool
+}
+
+// settingDependencyRules are checked whenever settings are read or changed
+var settingDependencyRules = []settingDependencyRule{
+	{
+		Code:     "EMAIL_VERIFICATION_WITHOUT_EMAIL",
+		Severity: auth.SettingWarningSeverityCritical,
+		Settings: []string{"app.auth.require_email_verification", "app.email.enabled"},
+		Message:  "Email verification is required but email is disabled, so new users cannot verify their address and will be unable to sign in.",
+		Conflicts: func(get func(string) interface{}) bool {
+			return settingBool(get("app.auth.require_email_verification")) && !settingBool(get("app.email.enabled"))
+		},
+	},
+	{
+		Code:     "EMAIL_VERIFICATION_WITHOUT_SMTP_HOST",
+		Severity: auth.SettingWarningSeverityCritical,
+		Settings: []string{"app.auth.require_email_verification", "app.email.provider", "app.email.smtp_host"},
+		Message:  "Email verification is required but the SMTP provider has no host configured, so verification emails cannot be sent.",
+		Conflicts: func(get func(string) interface{}) bool {
+			return settingBool(get("app.auth.require_email_verification")) &&
+				settingString(get("app.email.provider")) == "smtp" && settingString(get("app.email.smtp_host")) == ""
+		},
+	},
+	{
+		Code:     "MAGIC_LINK_WITHOUT_EMAIL",
+		Severity: auth.SettingWarningSeverityWarning,
+		Settings: []string{"app.auth.magic_link_enabled", "app.email.enabled"},
+		Message:  "Magic link sign-in is enabled but email is disabled, so magic links cannot be delivered.",
+		Conflicts: func(get func(string) interface{}) bool {
+			return settingBool(get("app.auth.magic_link_enabled")) && !settingBool(get("app.email.enabled"))
+		},
+	},
+	{
+		Code:     "CAPTCHA_WITHOUT_SITE_KEY",
+		Severity: auth.SettingWarningSeverityCritical,
+		Settings: []string{"app.security.captcha.enabled", "app.security.captcha.provider", "app.security.captcha.site_key"},
+		Message:  "Captcha is enabled but no site key is configured, so clients cannot solve captchas and protected endpoints will reject every request.",
+		Conflicts: func(get func(string) interface{}) bool {
+			return settingBool(get("app.security.captcha.enabled")) &&
+				settingString(get("app.security.captcha.provider")) != "cap" && settingString(get("app.security.captcha.site_key")) == ""
+		},
+	},
+	{
+		Code:     "CAPTCHA_WITHOUT_CAP_SERVER",
+		Severity: auth.SettingWarningSeverityCritical,
+		Settings: []string{"app.security.captcha.enabled", "app.security.captcha.provider", "app.security.captcha.cap_server_url"},
+		Message:  "Captcha is enabled with the cap provider but no Cap server URL is configured.",
+		Conflicts: func(get func(string) interface{}) bool {
+			return settingBool(get("app.security.captcha.enabled")) &&
+				settingString(get("app.security.captcha.provider")) == "cap" && settingString(get("app.security.captcha.cap_server_url")) == ""
+		},
+	},
+	{
+		Code:     "OPEN_SIGNUP_WITHOUT_ABUSE_PROTECTION",
+		Severity: auth.SettingWarningSeverityWarning,
+		Settings: []string{"app.auth.signup_enabled", "app.security.captcha.enabled", "app.security.enable_global_rate_limit"},
+		Message:  "Signup is open with neither captcha nor the global rate limit enabled, which leaves it exposed to automated account creation.",
+		Conflicts: func(get func(string) interface{}) bool {
+			return settingBool(get("app.auth.signup_enabled")) &&
+				!settingBool(get("app.security.captcha.enabled")) && !settingBool(get("app.security.enable_global_rate_limit"))
+		},
+	},
+}
+
+// settingBool interprets a setting value as a boolean
+func settingBool(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	default:
+		return false
+	}
+}
+
+// settingString interprets a setting value as a string
+func settingString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// currentSettingValue returns the effective value of a known setting, including environment
+// overrides, or its default when the settings cache is unavailable
+func (h *SystemSettingsHandler) currentSettingValue(ctx context.Context, key string) interface{} {
+	defaultValue := settingDefaults[key]["value"]
+	if h.settingsCache == nil {
+		return defaultValue
+	}
+
+	switch v := defaultValue.(type) {
+	case bool:
+		return h.settingsCache.GetBool(ctx, key, v)
+	case string:
+		return h.settingsCache.GetString(ctx, key, v)
+	case int:
+		return h.settingsCache.GetInt(ctx, key, v)
+	default:
+		return defaultValue
+	}
+}
+
+// settingWarnings evaluates the dependency rules against the effective settings, with
+// proposed values taking precedence, and returns the warnings involving key ("" for all)
+func (h *SystemSettingsHandler) settingWarnings(ctx context.Context, key string, proposed map[string]interface{}) []auth.SettingWarning {
+	values := make(map[string]interface{})
+	get := func(k string) interface{} {
+		if v, ok := proposed[k]; ok {
+			return v
+		}
+		if v, ok := values[k]; ok {
+			return v
+		}
+		v := h.currentSettingValue(ctx, k)
+		values[k] = v
+		return v
+	}
+
+	var warnings []auth.SettingWarning
+	for _, rule := range settingDependencyRules {
+		if key != "" && !slices.Contains(rule.Settings, key) {
+			continue
+		}
+		if rule.Conflicts(get) {
+			warnings = append(warnings, auth.SettingWarning{
+				Code:     rule.Code,
+				Severity: rule.Severity,
+				Message:  rule.Message,
+				Settings: rule.Settings,
+			})
+		}
+	}
+	return warnings
+}
+
+// warningsForSetting picks the warnings that involve key
+func warningsForSetting(warnings []auth.SettingWarning, key string) []auth.SettingWarning {
+	var matched []auth.SettingWarning
+	for _, w := range warnings {
+		if slices.Contains(w.Settings, key) {
+			matched = append(matched, w)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettingMetadata(t *testing.T) {
+	t.Run("every known setting is explained", func(t *testing.T) {
+		for key := range settingDefaults {
+			metadata := getSettingMetadata(key)
+			require.NotNil(t, metadata, "Expected metadata for %s", key)
+			assert.NotEmpty(t, metadata.Explanation, "Expected explanation for %s", key)
+		}
+	})
+
+	t.Run("metadata only covers known settings", func(t *testing.T) {
+		for key := range settingMetadata {
+			assert.Contains(t, settingDefaults, key)
+		}
+	})
+
+	t.Run("unknown key has no metadata", func(t *testing.T) {
+		assert.Nil(t, getSettingMetadata("unknown.key"))
+	})
+
+	t.Run("secrets affect security", func(t *testing.T) {
+		assert.True(t, getSettingMetadata("app.email.smtp_password").AffectsSecurity)
+		assert.True(t, getSettingMetadata("app.security.captcha.secret_key").AffectsSecurity)
+		assert.False(t, getSettingMetadata("app.realtime.enabled").AffectsSecurity)
+	})
+}
+
+func TestSettingDependencyRules(t *testing.T) {
+	for _, rule := range settingDependencyRules {
+		t.Run(rule.Code, func(t *testing.T) {
+			assert.NotEmpty(t, rule.Message)
+			assert.Contains(t, []string{auth.SettingWarningSeverityWarning, auth.SettingWarningSeverityCritical}, rule.Severity)
+			assert.GreaterOrEqual(t, len(rule.Settings), 2)
+			for _, key := range rule.Settings {
+				assert.Contains(t, settingDefaults, key)
+			}
+		})
+	}
+}
+
+func TestSettingWarnings(t *testing.T) {
+	handler := NewSystemSettingsHandler(nil, nil)
+	ctx := context.Background()
+
+	codes := func(warnings []auth.SettingWarning) []string {
+		var result []string
+		for _, w := range warnings {
+			result = append(result, w.Code)
+		}
+		return result
+	}
+
+	t.Run("defaults don't conflict", func(t *testing.T) {
+		assert.Empty(t, handler.settingWarnings(ctx, "", nil))
+	})
+
+	t.Run("disabling email while verification is required", func(t *testing.T) {
+		warnings := handler.settingWarnings(ctx, "app.email.enabled", map[string]interface{}{
+			"app.email.enabled":                   false,
+			"app.auth.require_email_verification": true,
+		})
+		require.Len(t, warnings, 1)
+		assert.Equal(t, "EMAIL_VERIFICATION_WITHOUT_EMAIL", warnings[0].Code)
+		assert.Equal(t, auth.SettingWarningSeverityCritical, warnings[0].Severity)
+		assert.Contains(t, warnings[0].Settings, "app.auth.require_email_verification")
+	})
+
+	t.Run("smtp provider without a host", func(t *testing.T) {
+		warnings := handler.settingWarnings(ctx, "app.email.provider", map[string]interface{}{
+			"app.email.provider":                  "smtp",
+			"app.auth.require_email_verification": true,
+		})
+		assert.Equal(t, []string{"EMAIL_VERIFICATION_WITHOUT_SMTP_HOST"}, codes(warnings))
+	})
+
+	t.Run("captcha without keys", func(t *testing.T) {
+		warnings := handler.settingWarnings(ctx, "app.security.captcha.enabled", map[string]interface{}{
+			"app.security.captcha.enabled": true,
+		})
+		assert.Equal(t, []string{"CAPTCHA_WITHOUT_SITE_KEY"}, codes(warnings))
+
+		warnings = handler.settingWarnings(ctx, "app.security.captcha.enabled", map[string]interface{}{
+			"app.security.captcha.enabled":  true,
+			"app.security.captcha.provider": "cap",
+		})
+		assert.Equal(t, []string{"CAPTCHA_WITHOUT_CAP_SERVER"}, codes(warnings))
+	})
+
+	t.Run("open signup without abuse protection", func(t *testing.T) {
+		warnings := handler.settingWarnings(ctx, "app.security.enable_global_rate_limit", map[string]interface{}{
+			"app.security.enable_global_rate_limit": false,
+		})
+		assert.Equal(t, []string{"OPEN_SIGNUP_WITHOUT_ABUSE_PROTECTION"}, codes(warnings))
+	})
+
+	t.Run("only warnings involving the key are returned", func(t *testing.T) {
+		proposed := map[string]interface{}{
+			"app.email.enabled":           false,
+			"app.auth.magic_link_enabled": true,
+		}
+		assert.Equal(t, []string{"MAGIC_LINK_WITHOUT_EMAIL"}, codes(handler.settingWarnings(ctx, "app.auth.magic_link_enabled", proposed)))
+		assert.Empty(t, handler.settingWarnings(ctx, "app.realtime.enabled", proposed))
+	})
+
+	t.Run("string booleans are understood", func(t *testing.T) {
+		warnings := handler.settingWarnings(ctx, "app.email.enabled", map[string]interface{}{
+			"app.email.enabled":                   "false",
+			"app.auth.require_email_verification": "true",
+		})
+		assert.Equal(t, []string{"EMAIL_VERIFICATION_WITHOUT_EMAIL"}, codes(warnings))
+	})
+}
+
+func TestWarningsForSetting(t *testing.T) {
+	warnings := []auth.SettingWarning{
+		{Code: "A", Settings: []string{"x", "y"}},
+		{Code: "B", Settings: []string{"y", "z"}},
+	}
+
+	assert.Len(t, warningsForSetting(warnings, "y"), 2)
+	assert.Len(t, warningsForSetting(warnings, "x"), 1)
+	assert.Empty(t, warningsForSetting(warnings, "w"))
+}
@@ -0,0 +1,551 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/nimbleflux/fluxbase/internal/egress"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// defaultTableDiffMaxRows is the number of rows fingerprinted before large tables are sampled
+	defaultTableDiffMaxRows = 100000
+	maxTableDiffMaxRows     = 1000000
+
+	// defaultTableDiffMaxKeys is the number of keys listed per diff category
+	defaultTableDiffMaxKeys = 100
+	maxTableDiffMaxKeys     = 10000
+
+	// tableDiffSampleBuckets is the resolution of key sampling (0.01%)
+	tableDiffSampleBuckets = 10000
+
+	tableDiffTimeout = 2 * time.Minute
+
+	// maxFingerprintResponseBytes caps the size of a remote fingerprint response
+	maxFingerprintResponseBytes = 256 << 20
+)
+
+// TableDiffHandler compares a table's rows with another Fluxbase instance or a
+// fingerprint snapshot, to validate that data was promoted between environments.
+// Rows are compared by primary key using a hash of each row, so only keys and
+// hashes are exchanged between instances.
+type TableDiffHandler struct {
+	db         *database.Connection
+	httpClient *http.Client
+}
+
+// NewTableDiffHandler creates a new table diff handler
+func NewTableDiffHandler(db *database.Connection) *TableDiffHandler {
+	return &TableDiffHandler{
+		db:         db,
+		httpClient: egress.NewHTTPClient(egress.SourceAdmin, tableDiffTimeout),
+	}
+}
+
+// TableFingerprint holds a hash of every (sampled) row of a table, keyed by primary key
+type TableFingerprint struct {
+	Schema        string   `json:"schema"`
+	Table         string   `json:"table"`
+	PrimaryKey    []string `json:"primary_key"`
+	Columns       []string `json:"columns"`
+	IgnoreColumns []string `json:"ignore_columns,omitempty"`
+	SamplePercent float64  `json:"sample_percent"`
+	// Rows maps the primary key (a JSON array of key values) to an MD5 hash of the row
+	Rows      map[string]string `json:"rows"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// FingerprintOptions controls which rows and columns are fingerprinted
+type FingerprintOptions struct {
+	// SamplePercent fingerprints only keys in this share of the key space. 0 picks a
+	// percentage that keeps the fingerprint under MaxRows.
+	SamplePercent float64  `json:"sample_percent,omitempty"`
+	IgnoreColumns []string `json:"ignore_columns,omitempty"`
+	MaxRows       int      `json:"max_rows,omitempty"`
+}
+
+// TableDiffRemote identifies the Fluxbase instance to compare with
+type TableDiffRemote struct {
+	URL        string `json:"url"`
+	ServiceKey string `json:"service_key"`
+}
+
+// TableDiffRequest is the body of a table diff request. Exactly one of Remote and
+// Snapshot must be set. A snapshot's sample percentage and ignored columns are used
+// for the local fingerprint so both sides hash the same rows and columns.
+type TableDiffRequest struct {
+	FingerprintOptions
+	Remote   *TableDiffRemote  `json:"remote,omitempty"`
+	Snapshot *TableFingerprint `json:"snapshot,omitempty"`
+	MaxKeys  int               `json:"max_keys,omitempty"`
+}
+
+// TableDiffSummary counts rows per diff category
+type TableDiffSummary struct {
+	Added     int `json:"added"`
+	Changed   int `json:"changed"`
+	Removed   int `json:"removed"`
+	Unchanged int `json:"unchanged"`
+}
+
+// TableDiffResult describes how this instance's rows differ from the remote rows.
+// Added rows exist only here, removed rows exist only on the remote side.
+type TableDiffResult struct {
+	Schema        string            `json:"schema"`
+	Table         string            `json:"table"`
+	PrimaryKey    []string          `json:"primary_key"`
+	SamplePercent float64           `json:"sample_percent"`
+	Sampled       bool              `json:"sampled"`
+	LocalRows     int               `json:"local_rows"`
+	RemoteRows    int               `json:"remote_rows"`
+	Identical     bool              `json:"identical"`
+	Summary       TableDiffSummary  `json:"summary"`
+	Added         []json.RawMessage `json:"added"`
+	Changed       []json.RawMessage `json:"changed"`
+	Removed       []json.RawMessage `json:"removed"`
+	KeysTruncated bool              `json:"keys_truncated,omitempty"`
+	Warnings      []string          `json:"warnings,omitempty"`
+}
+
+// errFingerprintTooLarge is returned when a table (sample) has more rows than allowed
+var errFingerprintTooLarge = errors.New("too many rows to fingerprint")
+
+// GetFingerprint returns the fingerprint of a table
+// GET /api/v1/admin/tables/:schema/:table/fingerprint
+func (h *TableDiffHandler) GetFingerprint(c fiber.Ctx) error {
+	schema, table := c.Params("schema"), c.Params("table")
+
+	opts := FingerprintOptions{MaxRows: fiber.Query[int](c, "max_rows")}
+	if v := c.Query("sample_percent"); v != "" {
+		percent, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return SendBadRequest(c, "sample_percent must be a number", ErrCodeInvalidInput)
+		}
+		opts.SamplePercent = percent
+	}
+	if v := c.Query("ignore_columns"); v != "" {
+		opts.IgnoreColumns = strings.Split(v, ",")
+	}
+
+	fingerprint, err := h.fingerprint(c.RequestCtx(), schema, table, opts)
+	if err != nil {
+		return h.sendFingerprintError(c, schema, table, err)
+	}
+	return c.JSON(fingerprint)
+}
+
+// DiffTable compares a table's rows with a remote instance or a snapshot
+// POST /api/v1/admin/tables/:schema/:table/diff
+func (h *TableDiffHandler) DiffTable(c fiber.Ctx) error {
+	schema, table := c.Params("schema"), c.Params("table")
+
+	var req TableDiffRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendBadRequest(c, "Invalid request body", ErrCodeInvalidBody)
+	}
+	if (req.Remote == nil) == (req.Snapshot == nil) {
+		return SendBadRequest(c, "Provide either remote or snapshot", ErrCodeInvalidInput)
+	}
+	if req.MaxKeys < 0 || req.MaxKeys > maxTableDiffMaxKeys {
+		return SendBadRequest(c, fmt.Sprintf("max_keys must be between 0 and %d", maxTableDiffMaxKeys), ErrCodeInvalidInput)
+	}
+	if req.MaxKeys == 0 {
+		req.MaxKeys = defaultTableDiffMaxKeys
+	}
+
+	opts := req.FingerprintOptions
+	if req.Snapshot != nil {
+		if req.Snapshot.SamplePercent == 0 {
+			req.Snapshot.SamplePercent = 100
+		}
+		opts.SamplePercent = req.Snapshot.SamplePercent
+		opts.IgnoreColumns = req.Snapshot.IgnoreColumns
+	}
+
+	ctx := c.RequestCtx()
+	local, err := h.fingerprint(ctx, schema, table, opts)
+	if err != nil {
+		return h.sendFingerprintError(c, schema, table, err)
+	}
+
+	remote := req.Snapshot
+	if req.Remote != nil {
+		// The remote must hash exactly the same key space and columns
+		opts.SamplePercent = local.SamplePercent
+		remote, err = h.fetchRemoteFingerprint(ctx, req.Remote, schema, table, opts)
+		if err != nil {
+			log.Warn().Err(err).Str("table", schema+"."+table).Msg("Failed to fetch remote table fingerprint")
+			return SendErrorWithCode(c, fiber.StatusBadGateway, "Failed to fetch remote fingerprint: "+err.Error(), ErrCodeOperationFailed)
+		}
+	}
+
+	if !slices.Equal(local.PrimaryKey, remote.PrimaryKey) {
+		return SendErrorWithDetails(c, fiber.StatusConflict, "Primary keys differ between environments", ErrCodeConflict,
+			"", "Rows can only be compared when both tables have the same primary key",
+			fiber.Map{"local": local.PrimaryKey, "remote": remote.PrimaryKey})
+	}
+	if local.SamplePercent != remote.SamplePercent {
+		return SendBadRequest(c, fmt.Sprintf("Remote fingerprint sampled %g%% of rows, expected %g%%", remote.SamplePercent, local.SamplePercent), ErrCodeInvalidInput)
+	}
+
+	return c.JSON(diffFingerprints(local, remote, req.MaxKeys))
+}
+
+func (h *TableDiffHandler) sendFingerprintError(c fiber.Ctx, schema, table string, err error) error {
+	var badRequest *fingerprintRequestError
+	switch {
+	case errors.As(err, &badRequest):
+		return SendBadRequest(c, badRequest.Error(), ErrCodeInvalidInput)
+	case errors.Is(err, errFingerprintTooLarge):
+		return SendErrorWithDetails(c, fiber.StatusRequestEntityTooLarge, "Table has too many rows to fingerprint", ErrCodeValidationFailed,
+			err.Error(), "Use a lower sample_percent or a higher max_rows", nil)
+	default:
+		log.Error().Err(err).Str("table", schema+"."+table).Msg("Failed to fingerprint table")
+		return SendInternalError(c, "Failed to fingerprint table")
+	}
+}
+
+// fingerprintRequestError reports invalid fingerprint input
+type fingerprintRequestError struct {
+	msg string
+}
+
+func (e *fingerprintRequestError) Error() string {
+	return e.msg
+}
+
+func badFingerprintRequest(format string, args ...interface{}) error {
+	return &fingerprintRequestError{msg: fmt.Sprintf(format, args...)}
+}
+
+// fingerprint hashes the rows of a table
+func (h *TableDiffHandler) fingerprint(ctx context.Context, schema, table string, opts FingerprintOptions) (*TableFingerprint, error) {
+	if err := validateIdentifier(schema, "schema"); err != nil {
+		return nil, &fingerprintRequestError{msg: err.Error()}
+	}
+	if err := validateIdentifier(table, "table"); err != nil {
+		return nil, &fingerprintRequestError{msg: err.Error()}
+	}
+	if opts.MaxRows < 0 || opts.MaxRows > maxTableDiffMaxRows {
+		return nil, badFingerprintRequest("max_rows must be between 0 and %d", maxTableDiffMaxRows)
+	}
+	if opts.MaxRows == 0 {
+		opts.MaxRows = defaultTableDiffMaxRows
+	}
+	if opts.SamplePercent < 0 || opts.SamplePercent > 100 {
+		return nil, badFingerprintRequest("sample_percent must be between 0 and 100")
+	}
+	if opts.SamplePercent > 0 && sampleBuckets(opts.SamplePercent) == 0 {
+		return nil, badFingerprintRequest("sample_percent must be at least %g", 100.0/tableDiffSampleBuckets)
+	}
+
+	tableInfo, err := h.db.Inspector().GetTableInfo(ctx, schema, table)
+	if err != nil {
+		return nil, badFingerprintRequest("table %s.%s not found", schema, table)
+	}
+	if len(tableInfo.PrimaryKey) == 0 {
+		return nil, badFingerprintRequest("table %s.%s has no primary key", schema, table)
+	}
+
+	columns := make([]string, 0, len(tableInfo.Columns))
+	for _, col := range tableInfo.Columns {
+		columns = append(columns, col.Name)
+	}
+	ignore, err := normalizeIgnoreColumns(opts.IgnoreColumns, columns, tableInfo.PrimaryKey)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint := &TableFingerprint{
+		Schema:        schema,
+		Table:         table,
+		PrimaryKey:    tableInfo.PrimaryKey,
+		Columns:       hashedColumns(columns, ignore),
+		IgnoreColumns: ignore,
+		SamplePercent: opts.SamplePercent,
+		Rows:          make(map[string]string),
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	err = database.WrapWithServiceRole(ctx, h.db, func(tx pgx.Tx) error {
+		// to_jsonb renders timestamptz in the session time zone, which must match across instances
+		if _, err := tx.Exec(ctx, "SET LOCAL TimeZone = 'UTC'"); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", tableDiffTimeout.Milliseconds())); err != nil {
+			return err
+		}
+
+		if fingerprint.SamplePercent == 0 {
+			var estimate float64
+			err := tx.QueryRow(ctx, "SELECT reltuples FROM pg_class WHERE oid = to_regclass($1)",
+				quoteIdentifier(schema)+"."+quoteIdentifier(table)).Scan(&estimate)
+			if err != nil {
+				return err
+			}
+			fingerprint.SamplePercent = autoSamplePercent(estimate, opts.MaxRows)
+		}
+
+		query := buildFingerprintQuery(schema, table, tableInfo.PrimaryKey)
+		rows, err := tx.Query(ctx, query, ignore, sampleBuckets(fingerprint.SamplePercent), opts.MaxRows+1)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var key, hash string
+			if err := rows.Scan(&key, &hash); err != nil {
+				return err
+			}
+			fingerprint.Rows[key] = hash
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fingerprint.Rows) > opts.MaxRows {
+		return nil, fmt.Errorf("%w: more than %d rows in a %g%% sample", errFingerprintTooLarge, opts.MaxRows, fingerprint.SamplePercent)
+	}
+	return fingerprint, nil
+}
+
+// buildFingerprintQuery returns a query producing (key, hash) for every sampled row.
+// Parameters: $1 ignored columns, $2 sample buckets out of tableDiffSampleBuckets, $3 row limit.
+// Keys are sampled by a hash of the key itself, so every instance samples the same keys.
+func buildFingerprintQuery(schema, table string, primaryKey []string) string {
+	keyColumns := make([]string, len(primaryKey))
+	for i, col := range primaryKey {
+		keyColumns[i] = "t." + quoteIdentifier(col)
+	}
+
+	return fmt.Sprintf(`SELECT k, h FROM (
+	SELECT jsonb_build_array(%s)::text AS k, md5((to_jsonb(t) - $1::text[])::text) AS h
+	FROM %s.%s AS t
+) AS rows
+WHERE $2::int >= %d OR (get_byte(decode(md5(k), 'hex'), 0) * 256 + get_byte(decode(md5(k), 'hex'), 1)) %% %d < $2::int
+LIMIT $3`,
+		strings.Join(keyColumns, ", "), quoteIdentifier(schema), quoteIdentifier(table),
+		tableDiffSampleBuckets, tableDiffSampleBuckets)
+}
+
+// sampleBuckets converts a sample percentage to a number of sample buckets
+func sampleBuckets(percent float64) int {
+	return int(math.Round(percent * tableDiffSampleBuckets / 100))
+}
+
+// autoSamplePercent picks a sample percentage that keeps an estimated row count under maxRows,
+// leaving headroom because the estimate comes from table statistics
+func autoSamplePercent(estimatedRows float64, maxRows int) float64 {
+	if estimatedRows <= float64(maxRows) {
+		return 100
+	}
+	percent := math.Floor(float64(maxRows)*0.9/estimatedRows*tableDiffSampleBuckets) * 100 / tableDiffSampleBuckets
+	return math.Max(percent, 100.0/tableDiffSampleBuckets)
+}
+
+// normalizeIgnoreColumns validates ignored columns and returns them sorted and de-duplicated
+func normalizeIgnoreColumns(ignore, columns, primaryKey []string) ([]string, error) {
+	known := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		known[col] = true
+	}
+	isKey := make(map[string]bool, len(primaryKey))
+	for _, col := range primaryKey {
+		isKey[col] = true
+	}
+
+	seen := make(map[string]bool, len(ignore))
+	result := make([]string, 0, len(ignore))
+	for _, col := range ignore {
+		col = strings.TrimSpace(col)
+		if col == "" || seen[col] {
+			continue
+		}
+		if !known[col] {
+			return nil, badFingerprintRequest("ignore_columns: unknown column %q", col)
+		}
+		if isKey[col] {
+			return nil, badFingerprintRequest("ignore_columns: %q is part of the primary key", col)
+		}
+		seen[col] = true
+		result = append(result, col)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// hashedColumns returns the sorted columns that contribute to row hashes
+func hashedColumns(columns, ignore []string) []string {
+	ignored := make(map[string]bool, len(ignore))
+	for _, col := range ignore {
+		ignored[col] = true
+	}
+	result := make([]string, 0, len(columns))
+	for _, col := range columns {
+		if !ignored[col] {
+			result = append(result, col)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// fetchRemoteFingerprint requests a table fingerprint from another Fluxbase instance
+func (h *TableDiffHandler) fetchRemoteFingerprint(ctx context.Context, remote *TableDiffRemote, schema, table string, opts FingerprintOptions) (*TableFingerprint, error) {
+	base, err := url.Parse(strings.TrimSpace(remote.URL))
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("remote url must be an http or https URL")
+	}
+	if remote.ServiceKey == "" {
+		return nil, fmt.Errorf("remote service_key is required")
+	}
+
+	query := url.Values{}
+	query.Set("sample_percent", strconv.FormatFloat(opts.SamplePercent, 'f', -1, 64))
+	if len(opts.IgnoreColumns) > 0 {
+		query.Set("ignore_columns", strings.Join(opts.IgnoreColumns, ","))
+	}
+	if opts.MaxRows > 0 {
+		query.Set("max_rows", strconv.Itoa(opts.MaxRows))
+	}
+	endpoint := strings.TrimSuffix(base.String(), "/") +
+		"/api/v1/admin/tables/" + url.PathEscape(schema) + "/" + url.PathEscape(table) + "/fingerprint?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Service-Key", remote.ServiceKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body := io.LimitReader(resp.Body, maxFingerprintResponseBytes)
+	if resp.StatusCode != http.StatusOK {
+		var errResp ErrorResponse
+		if json.NewDecoder(body).Decode(&errResp) == nil && errResp.Error != "" {
+			return nil, fmt.Errorf("remote returned %d: %s", resp.StatusCode, errResp.Error)
+		}
+		return nil, fmt.Errorf("remote returned %d", resp.StatusCode)
+	}
+
+	var fingerprint TableFingerprint
+	if err := json.NewDecoder(body).Decode(&fingerprint); err != nil {
+		return nil, fmt.Errorf("invalid fingerprint response: %w", err)
+	}
+	return &fingerprint, nil
+}
+
+// diffFingerprints compares local rows with remote rows, listing at most maxKeys keys per category
+func diffFingerprints(local, remote *TableFingerprint, maxKeys int) *TableDiffResult {
+	result := &TableDiffResult{
+		Schema:        local.Schema,
+		Table:         local.Table,
+		PrimaryKey:    local.PrimaryKey,
+		SamplePercent: local.SamplePercent,
+		Sampled:       local.SamplePercent < 100,
+		LocalRows:     len(local.Rows),
+		RemoteRows:    len(remote.Rows),
+		Added:         []json.RawMessage{},
+		Changed:       []json.RawMessage{},
+		Removed:       []json.RawMessage{},
+	}
+
+	var added, changed, removed []string
+	for key, hash := range local.Rows {
+		remoteHash, ok := remote.Rows[key]
+		switch {
+		case !ok:
+			added = append(added, key)
+		case remoteHash != hash:
+			changed = append(changed, key)
+		default:
+			result.Summary.Unchanged++
+		}
+	}
+	for key := range remote.Rows {
+		if _, ok := local.Rows[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+
+	result.Summary.Added, result.Summary.Changed, result.Summary.Removed = len(added), len(changed), len(removed)
+	result.Identical = len(added) == 0 && len(changed) == 0 && len(removed) == 0
+
+	var truncated bool
+	result.Added, truncated = limitKeys(added, maxKeys)
+	result.KeysTruncated = truncated
+	result.Changed, truncated = limitKeys(changed, maxKeys)
+	result.KeysTruncated = result.KeysTruncated || truncated
+	result.Removed, truncated = limitKeys(removed, maxKeys)
+	result.KeysTruncated = result.KeysTruncated || truncated
+
+	if onlyLocal, onlyRemote := stringSetDiff(local.Columns, remote.Columns); len(onlyLocal) > 0 || len(onlyRemote) > 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf(
+			"columns differ (only here: %s; only remote: %s), so every row is reported as changed; use ignore_columns to compare the shared columns",
+			strings.Join(onlyLocal, ", "), strings.Join(onlyRemote, ", ")))
+	}
+	return result
+}
+
+// limitKeys sorts keys and returns at most max of them as JSON values
+func limitKeys(keys []string, max int) ([]json.RawMessage, bool) {
+	sort.Strings(keys)
+	truncated := len(keys) > max
+	if truncated {
+		keys = keys[:max]
+	}
+	result := make([]json.RawMessage, len(keys))
+	for i, key := range keys {
+		if json.Valid([]byte(key)) {
+			result[i] = json.RawMessage(key)
+		} else {
+			// Keys from a hand-edited snapshot may not be JSON
+			result[i], _ = json.Marshal(key)
+		}
+	}
+	return result, truncated
+}
+
+// stringSetDiff returns the values only in a and only in b
+func stringSetDiff(a, b []string) (onlyA, onlyB []string) {
+	inA := make(map[string]bool, len(a))
+	for _, v := range a {
+		inA[v] = true
+	}
+	inB := make(map[string]bool, len(b))
+	for _, v := range b {
+		inB[v] = true
+		if !inA[v] {
+			onlyB = append(onlyB, v)
+		}
+	}
+	for _, v := range a {
+		if !inB[v] {
+			onlyA = append(onlyA, v)
+		}
+	}
+	return onlyA, onlyB
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffFingerprints(t *testing.T) {
+	local := &TableFingerprint{
+		Schema:        "public",
+		Table:         "orders",
+		PrimaryKey:    []string{"id"},
+		Columns:       []string{"id", "status", "total"},
+		SamplePercent: 100,
+		Rows:          map[string]string{"[1]": "a", "[2]": "b", "[3]": "c", "[5]": "e"},
+	}
+	remote := &TableFingerprint{
+		PrimaryKey:    []string{"id"},
+		Columns:       []string{"id", "status", "total"},
+		SamplePercent: 100,
+		Rows:          map[string]string{"[1]": "a", "[2]": "x", "[4]": "d"},
+	}
+
+	result := diffFingerprints(local, remote, 10)
+	assert.Equal(t, TableDiffSummary{Added: 2, Changed: 1, Removed: 1, Unchanged: 1}, result.Summary)
+	assert.False(t, result.Identical)
+	assert.False(t, result.Sampled)
+	assert.Equal(t, 4, result.LocalRows)
+	assert.Equal(t, 3, result.RemoteRows)
+
+	body, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"added":[[3],[5]]`)
+	assert.Contains(t, string(body), `"changed":[[2]]`)
+	assert.Contains(t, string(body), `"removed":[[4]]`)
+	assert.Empty(t, result.Warnings)
+
+	t.Run("keys are limited", func(t *testing.T) {
+		result := diffFingerprints(local, remote, 1)
+		assert.Equal(t, 2, result.Summary.Added)
+		assert.Len(t, result.Added, 1)
+		assert.True(t, result.KeysTruncated)
+	})
+
+	t.Run("identical tables", func(t *testing.T) {
+		result := diffFingerprints(local, local, 10)
+		assert.True(t, result.Identical)
+		assert.Equal(t, 4, result.Summary.Unchanged)
+	})
+
+	t.Run("column drift is reported", func(t *testing.T) {
+		drifted := &TableFingerprint{PrimaryKey: []string{"id"}, Columns: []string{"id", "status", "notes"}, SamplePercent: 100}
+		result := diffFingerprints(local, drifted, 10)
+		require.Len(t, result.Warnings, 1)
+		assert.Contains(t, result.Warnings[0], "only here: total; only remote: notes")
+	})
+
+	t.Run("invalid snapshot keys are quoted", func(t *testing.T) {
+		snapshot := &TableFingerprint{PrimaryKey: []string{"id"}, Columns: local.Columns, Rows: map[string]string{"not json": "z"}}
+		body, err := json.Marshal(diffFingerprints(local, snapshot, 10))
+		require.NoError(t, err)
+		assert.Contains(t, string(body), `"removed":["not json"]`)
+	})
+}
+
+func TestBuildFingerprintQuery(t *testing.T) {
+	query := buildFingerprintQuery("sales", "order_items", []string{"order_id", "line"})
+	assert.Contains(t, query, `jsonb_build_array(t."order_id", t."line")::text AS k`)
+	assert.Contains(t, query, `md5((to_jsonb(t) - $1::text[])::text) AS h`)
+	assert.Contains(t, query, `FROM "sales"."order_items" AS t`)
+	assert.Contains(t, query, "$2::int >= 10000 OR")
+	assert.Contains(t, query, "% 10000 < $2::int")
+	assert.NotContains(t, query, "%!")
+}
+
+func TestTableDiffSampling(t *testing.T) {
+	assert.Equal(t, 10000, sampleBuckets(100))
+	assert.Equal(t, 250, sampleBuckets(2.5))
+	assert.Equal(t, 1, sampleBuckets(0.01))
+	assert.Equal(t, 0, sampleBuckets(0.001))
+
+	assert.Equal(t, 100.0, autoSamplePercent(-1, 1000), "tables without statistics are not sampled")
+	assert.Equal(t, 100.0, autoSamplePercent(1000, 1000))
+	assert.Equal(t, 9.0, autoSamplePercent(1000000, 100000))
+	assert.Equal(t, 0.01, autoSamplePercent(1e12, 100000))
+}
+
+func TestNormalizeIgnoreColumns(t *testing.T) {
+	columns := []string{"id", "name", "updated_at", "synced_at"}
+
+	ignore, err := normalizeIgnoreColumns([]string{"updated_at", " synced_at", "updated_at", ""}, columns, []string{"id"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"synced_at", "updated_at"}, ignore)
+	assert.Equal(t, []string{"id", "name"}, hashedColumns(columns, ignore))
+
+	_, err = normalizeIgnoreColumns([]string{"missing"}, columns, []string{"id"})
+	assert.EqualError(t, err, `ignore_columns: unknown column "missing"`)
+
+	_, err = normalizeIgnoreColumns([]string{"id"}, columns, []string{"id"})
+	assert.EqualError(t, err, `ignore_columns: "id" is part of the primary key`)
+}
+
+func TestFetchRemoteFingerprint(t *testing.T) {
+	var gotPath, gotQuery, gotKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery, gotKey = r.URL.Path, r.URL.RawQuery, r.Header.Get("X-Service-Key")
+		if r.URL.Path == "/api/v1/admin/tables/public/missing/fingerprint" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "table public.missing not found"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(TableFingerprint{PrimaryKey: []string{"id"}, SamplePercent: 12.5, Rows: map[string]string{"[1]": "a"}})
+	}))
+	defer server.Close()
+
+	h := &TableDiffHandler{httpClient: server.Client()}
+	remote := &TableDiffRemote{URL: server.URL + "/", ServiceKey: "sk_test"}
+	opts := FingerprintOptions{SamplePercent: 12.5, IgnoreColumns: []string{"updated_at"}, MaxRows: 5000}
+
+	fingerprint, err := h.fetchRemoteFingerprint(context.Background(), remote, "public", "orders", opts)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/admin/tables/public/orders/fingerprint", gotPath)
+	assert.Equal(t, "ignore_columns=updated_at&max_rows=5000&sample_percent=12.5", gotQuery)
+	assert.Equal(t, "sk_test", gotKey)
+	assert.Equal(t, map[string]string{"[1]": "a"}, fingerprint.Rows)
+
+	_, err = h.fetchRemoteFingerprint(context.Background(), remote, "public", "missing", opts)
+	assert.EqualError(t, err, "remote returned 400: table public.missing not found")
+
+	_, err = h.fetchRemoteFingerprint(context.Background(), &TableDiffRemote{URL: "ftp://example.com", ServiceKey: "sk"}, "public", "orders", opts)
+	assert.Error(t, err)
+
+	_, err = h.fetchRemoteFingerprint(context.Background(), &TableDiffRemote{URL: server.URL}, "public", "orders", opts)
+	assert.EqualError(t, err, "remote service_key is required")
+}
+
+func TestDiffTable_RequestValidation(t *testing.T) {
+	app := fiber.New()
+	app.Post("/tables/:schema/:table/diff", NewTableDiffHandler(nil).DiffTable)
+
+	tests := []struct {
+		name string
+		path string
+		body string
+		want string
+	}{
+		{name: "neither remote nor snapshot", path: "/tables/public/orders/diff", body: `{}`, want: "Provide either remote or snapshot"},
+		{name: "both remote and snapshot", path: "/tables/public/orders/diff", body: `{"remote":{"url":"https://example.com"},"snapshot":{"rows":{}}}`, want: "Provide either remote or snapshot"},
+		{name: "max_keys out of range", path: "/tables/public/orders/diff", body: `{"snapshot":{"rows":{}},"max_keys":100000}`, want: "max_keys must be between 0 and 10000"},
+		{name: "invalid table name", path: "/tables/public/1orders/diff", body: `{"snapshot":{"rows":{}}}`, want: "table name must start with a letter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			var result ErrorResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			assert.Contains(t, result.Error, tt.want)
+		})
+	}
+}
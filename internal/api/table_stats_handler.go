@@ -0,0 +1,119 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/tablestats"
+	"github.com/rs/zerolog/log"
+)
+
+// TableStatsHandler reports table row estimates, sizes and growth trends
+type TableStatsHandler struct {
+	store     *tablestats.Store
+	collector *tablestats.Collector // nil when daily snapshots are disabled
+	now       func() time.Time
+}
+
+// NewTableStatsHandler creates a new table stats handler. collector may be nil.
+func NewTableStatsHandler(store *tablestats.Store, collector *tablestats.Collector) *TableStatsHandler {
+	return &TableStatsHandler{store: store, collector: collector, now: time.Now}
+}
+
+// tableStatsQuery is the validated query of a table size report
+type tableStatsQuery struct {
+	Schema string
+	Days   int
+	Sort   string
+	Limit  int
+}
+
+// parseTableStatsQuery validates ?schema=&days=&sort=&limit=
+func parseTableStatsQuery(c fiber.Ctx) (tableStatsQuery, error) {
+	q := tableStatsQuery{Schema: c.Query("schema"), Days: 30, Sort: tablestats.SortBySize}
+
+	if v := c.Query("days"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days <= 0 || days > 366 {
+			return q, errors.New("days must be an integer between 1 and 366")
+		}
+		q.Days = days
+	}
+
+	if v := c.Query("sort"); v != "" {
+		switch v {
+		case tablestats.SortBySize, tablestats.SortByGrowth, tablestats.SortByBloat:
+			q.Sort = v
+		default:
+			return q, errors.New("sort must be one of: size, growth, bloat")
+		}
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return q, errors.New("limit must be a positive integer")
+		}
+		q.Limit = min(limit, 1000)
+	}
+
+	return q, nil
+}
+
+// GetTableSizes returns the row estimate, size, bloat estimate and growth trend of each
+// user table, largest first
+// GET /api/v1/admin/tables/sizes?schema=public&days=30&sort=growth&limit=20
+func (h *TableStatsHandler) GetTableSizes(c fiber.Ctx) error {
+	q, err := parseTableStatsQuery(c)
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	ctx := c.RequestCtx()
+	now := h.now()
+
+	current, err := h.store.Current(ctx, q.Schema)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get table sizes")
+		return SendOperationFailed(c, "get table sizes")
+	}
+
+	history, err := h.store.History(ctx, tablestats.SnapshotDate(now).AddDate(0, 0, -q.Days), q.Schema)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get table size history")
+		return SendOperationFailed(c, "get table size history")
+	}
+
+	databaseBytes, err := h.store.DatabaseBytes(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get database size")
+		return SendOperationFailed(c, "get database size")
+	}
+
+	return c.JSON(tablestats.Report{
+		GeneratedAt:   now,
+		Days:          q.Days,
+		DatabaseBytes: databaseBytes,
+		Tables:        tablestats.BuildReport(current, history, now, q.Sort, q.Limit),
+	})
+}
+
+// TakeSnapshot records today's table sizes right away, replacing today's snapshot
+// POST /api/v1/admin/tables/sizes/snapshot
+func (h *TableStatsHandler) TakeSnapshot(c fiber.Ctx) error {
+	if h.collector == nil {
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "Table size snapshots are not enabled", ErrCodeFeatureDisabled)
+	}
+
+	tables, err := h.collector.Snapshot(c.RequestCtx())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to snapshot table sizes")
+		return SendOperationFailed(c, "snapshot table sizes")
+	}
+
+	return c.JSON(fiber.Map{
+		"tables": tables,
+	})
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/tablestats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableStatsHandler_RejectsInvalidQuery(t *testing.T) {
+	h := NewTableStatsHandler(tablestats.NewStore(nil), nil)
+	app := fiber.New()
+	app.Get("/tables/sizes", h.GetTableSizes)
+
+	for _, query := range []string{"days=0", "days=abc", "days=400", "sort=name", "limit=-1"} {
+		t.Run(query, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", "/tables/sizes?"+query, nil))
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+	}
+}
+
+func TestTableStatsHandler_SnapshotsDisabled(t *testing.T) {
+	h := NewTableStatsHandler(tablestats.NewStore(nil), nil)
+	app := fiber.New()
+	app.Post("/tables/sizes/snapshot", h.TakeSnapshot)
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/tables/sizes/snapshot", nil))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+}
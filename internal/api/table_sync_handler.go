@@ -0,0 +1,219 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/tablesync"
+	"github.com/rs/zerolog/log"
+)
+
+// TableSyncHandler manages the targets that tables are synced to and receives rows synced
+// from other instances
+type TableSyncHandler struct {
+	store        *tablesync.Store
+	ingester     *tablesync.Ingester
+	syncEnabled  bool // Whether this instance runs the syncer
+	acceptIngest bool // Whether this instance accepts synced rows
+}
+
+// NewTableSyncHandler creates a new table sync handler
+func NewTableSyncHandler(store *tablesync.Store, ingester *tablesync.Ingester, syncEnabled, acceptIngest bool) *TableSyncHandler {
+	return &TableSyncHandler{store: store, ingester: ingester, syncEnabled: syncEnabled, acceptIngest: acceptIngest}
+}
+
+// withoutServiceKey clears the decrypted service key of targets before they are returned
+func withoutServiceKey(targets ...*tablesync.Target) {
+	for _, target := range targets {
+		target.ServiceKey = ""
+	}
+}
+
+// respondInvalidSyncTargetID rejects an :id parameter that is not a UUID
+func respondInvalidSyncTargetID(c fiber.Ctx) error {
+	return SendBadRequest(c, "Invalid sync target ID", ErrCodeInvalidID)
+}
+
+// respondSyncTargetError maps store errors to responses
+func respondSyncTargetError(c fiber.Ctx, err error, action string) error {
+	switch {
+	case errors.Is(err, tablesync.ErrTargetNotFound):
+		return SendNotFound(c, "Sync target not found")
+	case errors.Is(err, tablesync.ErrTargetExists):
+		return SendConflict(c, err.Error(), ErrCodeConflict)
+	}
+	log.Error().Err(err).Msgf("Failed to %s", action)
+	return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to %s", action), ErrCodeOperationFailed)
+}
+
+// ListTargets returns the sync targets with the cursor and lag of each table
+// GET /api/v1/admin/sync/targets
+func (h *TableSyncHandler) ListTargets(c fiber.Ctx) error {
+	targets, err := h.store.List(c.RequestCtx())
+	if err != nil {
+		return respondSyncTargetError(c, err, "list sync targets")
+	}
+	withoutServiceKey(targets...)
+
+	return c.JSON(fiber.Map{
+		"targets": targets,
+		"enabled": h.syncEnabled,
+	})
+}
+
+// GetTarget returns a sync target with the cursor and lag of each table
+// GET /api/v1/admin/sync/targets/:id
+func (h *TableSyncHandler) GetTarget(c fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return respondInvalidSyncTargetID(c)
+	}
+
+	target, err := h.store.Get(c.RequestCtx(), id)
+	if err != nil {
+		return respondSyncTargetError(c, err, "get sync target")
+	}
+	withoutServiceKey(target)
+	return c.JSON(target)
+}
+
+// CreateTarget adds an instance to sync tables to
+// POST /api/v1/admin/sync/targets
+func (h *TableSyncHandler) CreateTarget(c fiber.Ctx) error {
+	var target tablesync.Target
+	if err := c.Bind().Body(&target); err != nil {
+		return SendInvalidBody(c)
+	}
+	if err := target.Validate(true); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	if err := h.store.Create(c.RequestCtx(), &target, getUserIDFromContext(c)); err != nil {
+		return respondSyncTargetError(c, err, "create sync target")
+	}
+
+	log.Info().
+		Str("target", target.Name).
+		Str("url", target.URL).
+		Strs("tables", target.Tables).
+		Str("user_id", getUserID(c)).
+		Msg("Sync target created")
+
+	withoutServiceKey(&target)
+	return c.Status(fiber.StatusCreated).JSON(target)
+}
+
+// UpdateTarget replaces the settings of a sync target. The service key is kept when omitted.
+// PUT /api/v1/admin/sync/targets/:id
+func (h *TableSyncHandler) UpdateTarget(c fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return respondInvalidSyncTargetID(c)
+	}
+
+	var target tablesync.Target
+	if err := c.Bind().Body(&target); err != nil {
+		return SendInvalidBody(c)
+	}
+	target.ID = id
+	if err := target.Validate(false); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	if err := h.store.Update(c.RequestCtx(), &target); err != nil {
+		return respondSyncTargetError(c, err, "update sync target")
+	}
+
+	log.Info().
+		Str("target", target.Name).
+		Strs("tables", target.Tables).
+		Str("user_id", getUserID(c)).
+		Msg("Sync target updated")
+
+	withoutServiceKey(&target)
+	return c.JSON(target)
+}
+
+// DeleteTarget removes a sync target and its cursors. Rows already synced stay on the target.
+// DELETE /api/v1/admin/sync/targets/:id
+func (h *TableSyncHandler) DeleteTarget(c fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return respondInvalidSyncTargetID(c)
+	}
+
+	if err := h.store.Delete(c.RequestCtx(), id); err != nil {
+		return respondSyncTargetError(c, err, "delete sync target")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// PauseTarget stops syncing to a target until it is resumed
+// POST /api/v1/admin/sync/targets/:id/pause
+func (h *TableSyncHandler) PauseTarget(c fiber.Ctx) error {
+	return h.setPaused(c, true)
+}
+
+// ResumeTarget resumes syncing to a target from where it was paused
+// POST /api/v1/admin/sync/targets/:id/resume
+func (h *TableSyncHandler) ResumeTarget(c fiber.Ctx) error {
+	return h.setPaused(c, false)
+}
+
+func (h *TableSyncHandler) setPaused(c fiber.Ctx, paused bool) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return respondInvalidSyncTargetID(c)
+	}
+
+	ctx := c.RequestCtx()
+	if err := h.store.SetPaused(ctx, id, paused); err != nil {
+		return respondSyncTargetError(c, err, "update sync target")
+	}
+	target, err := h.store.Get(ctx, id)
+	if err != nil {
+		return respondSyncTargetError(c, err, "get sync target")
+	}
+
+	log.Info().
+		Str("target", target.Name).
+		Bool("paused", paused).
+		Str("user_id", getUserID(c)).
+		Msg("Sync target paused state changed")
+
+	withoutServiceKey(target)
+	return c.JSON(target)
+}
+
+// Ingest applies a batch of rows synced from another instance
+// POST /api/v1/admin/sync/ingest
+func (h *TableSyncHandler) Ingest(c fiber.Ctx) error {
+	if !h.acceptIngest {
+		return SendForbidden(c, "Table sync ingest is disabled on this instance", ErrCodeFeatureDisabled)
+	}
+
+	var req tablesync.IngestRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+
+	result, err := h.ingester.Apply(c.RequestCtx(), &req)
+	if errors.Is(err, tablesync.ErrInvalidIngest) {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("source", req.Source).Str("table", req.Table).Msg("Failed to apply synced rows")
+		return handleDatabaseError(c, err, "apply synced rows")
+	}
+
+	log.Debug().
+		Str("source", req.Source).
+		Str("table", req.Table).
+		Int("applied", result.Applied).
+		Int("skipped", result.Skipped).
+		Msg("Applied synced rows")
+
+	return c.JSON(result)
+}
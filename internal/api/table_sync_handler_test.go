@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTableSyncApp(acceptIngest bool) *fiber.App {
+	h := NewTableSyncHandler(nil, nil, false, acceptIngest)
+	app := fiber.New()
+	app.Post("/sync/targets", h.CreateTarget)
+	app.Get("/sync/targets/:id", h.GetTarget)
+	app.Put("/sync/targets/:id", h.UpdateTarget)
+	app.Delete("/sync/targets/:id", h.DeleteTarget)
+	app.Post("/sync/targets/:id/pause", h.PauseTarget)
+	app.Post("/sync/targets/:id/resume", h.ResumeTarget)
+	app.Post("/sync/ingest", h.Ingest)
+	return app
+}
+
+func TestTableSyncHandler_RejectsInvalidRequests(t *testing.T) {
+	const id = "0b6f2a1e-7c39-4d5e-9a51-3f0d2b8c4e17"
+
+	assertErrorResponses(t, newTestTableSyncApp(false), []errorRequestCase{
+		{"target id must be a UUID", "GET", "/sync/targets/central", "", fiber.StatusBadRequest, "Invalid sync target ID"},
+		{"pause needs a UUID", "POST", "/sync/targets/central/pause", "", fiber.StatusBadRequest, "Invalid sync target ID"},
+		{"resume needs a UUID", "POST", "/sync/targets/central/resume", "", fiber.StatusBadRequest, "Invalid sync target ID"},
+		{"delete needs a UUID", "DELETE", "/sync/targets/central", "", fiber.StatusBadRequest, "Invalid sync target ID"},
+		{"create needs a service key", "POST", "/sync/targets",
+			`{"name":"central","url":"https://central.example.com","tables":["public.orders"]}`, fiber.StatusBadRequest, "service_key is required"},
+		{"create validates the url", "POST", "/sync/targets",
+			`{"name":"central","url":"central.example.com","service_key":"k","tables":["public.orders"]}`, fiber.StatusBadRequest, "http or https"},
+		{"update validates the conflict policy", "PUT", "/sync/targets/" + id,
+			`{"name":"central","url":"https://central.example.com","tables":["orders"],"conflict_policy":"merge"}`, fiber.StatusBadRequest, "conflict_policy"},
+		{"ingest is disabled by default", "POST", "/sync/ingest",
+			`{"table":"public.orders","conflict_policy":"source_wins","rows":[]}`, fiber.StatusForbidden, "disabled"},
+	})
+
+	assertErrorResponses(t, newTestTableSyncApp(true), []errorRequestCase{
+		{"ingest validates the table", "POST", "/sync/ingest",
+			`{"table":"public.orders;","conflict_policy":"source_wins","rows":[{"id":1}]}`, fiber.StatusBadRequest, "invalid table"},
+		{"ingest validates the conflict policy", "POST", "/sync/ingest",
+			`{"table":"public.orders","conflict_policy":"merge","rows":[{"id":1}]}`, fiber.StatusBadRequest, "conflict_policy"},
+		{"ingest requires object rows", "POST", "/sync/ingest",
+			`{"table":"public.orders","conflict_policy":"source_wins","rows":[[1]]}`, fiber.StatusBadRequest, "not a JSON object"},
+	})
+}
+
+func TestTableSyncHandler_IngestEmptyBatch(t *testing.T) {
+	req := httptest.NewRequest("POST", "/sync/ingest", strings.NewReader(`{"table":"public.orders","conflict_policy":"target_wins","rows":[]}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := newTestTableSyncApp(true).Test(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/textsearch"
+	"github.com/rs/zerolog/log"
+)
+
+// TextSearchHandler manages the full text search configuration of tables: the language and
+// weight of their text columns and their generated tsvector columns
+type TextSearchHandler struct {
+	service *textsearch.Service
+}
+
+// NewTextSearchHandler creates a new text search configuration handler
+func NewTextSearchHandler(service *textsearch.Service) *TextSearchHandler {
+	return &TextSearchHandler{service: service}
+}
+
+// respondInvalidTextSearchConfigID rejects an :id parameter that is not a UUID
+func respondInvalidTextSearchConfigID(c fiber.Ctx) error {
+	return SendBadRequest(c, "Invalid text search configuration ID", ErrCodeInvalidID)
+}
+
+// respondTextSearchConfigError maps service errors to responses
+func respondTextSearchConfigError(c fiber.Ctx, err error, action string) error {
+	switch {
+	case errors.Is(err, textsearch.ErrConfigNotFound):
+		return SendNotFound(c, "Text search configuration not found")
+	case errors.Is(err, textsearch.ErrConfigExists):
+		return SendConflict(c, err.Error(), ErrCodeConflict)
+	case errors.Is(err, textsearch.ErrInvalidConfig):
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+	log.Error().Err(err).Msgf("Failed to %s", action)
+	return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to %s", action), ErrCodeOperationFailed)
+}
+
+// ListConfigs returns the text search configurations
+// GET /api/v1/admin/text-search
+func (h *TextSearchHandler) ListConfigs(c fiber.Ctx) error {
+	configs, err := h.service.List(c.RequestCtx())
+	if err != nil {
+		return respondTextSearchConfigError(c, err, "list text search configurations")
+	}
+	return c.JSON(fiber.Map{
+		"configs": configs,
+	})
+}
+
+// GetConfig returns a text search configuration
+// GET /api/v1/admin/text-search/:id
+func (h *TextSearchHandler) GetConfig(c fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return respondInvalidTextSearchConfigID(c)
+	}
+
+	cfg, err := h.service.Get(c.RequestCtx(), id)
+	if err != nil {
+		return respondTextSearchConfigError(c, err, "get text search configuration")
+	}
+	return c.JSON(cfg)
+}
+
+// CreateConfig configures text search on a table and adds its generated tsvector column
+// POST /api/v1/admin/text-search
+func (h *TextSearchHandler) CreateConfig(c fiber.Ctx) error {
+	var cfg textsearch.Config
+	if err := c.Bind().Body(&cfg); err != nil {
+		return SendInvalidBody(c)
+	}
+	if err := cfg.Validate(); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	if err := h.service.Create(c.RequestCtx(), &cfg, getUserIDFromContext(c)); err != nil {
+		return respondTextSearchConfigError(c, err, "create text search configuration")
+	}
+
+	log.Info().
+		Str("table", cfg.Table).
+		Str("language", cfg.Language).
+		Str("vector_column", cfg.VectorColumn).
+		Str("user_id", getUserID(c)).
+		Msg("Text search configuration created")
+
+	return c.Status(fiber.StatusCreated).JSON(cfg)
+}
+
+// UpdateConfig replaces the text search configuration of a table and regenerates its
+// tsvector column
+// PUT /api/v1/admin/text-search/:id
+func (h *TextSearchHandler) UpdateConfig(c fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return respondInvalidTextSearchConfigID(c)
+	}
+
+	var cfg textsearch.Config
+	if err := c.Bind().Body(&cfg); err != nil {
+		return SendInvalidBody(c)
+	}
+	cfg.ID = id
+	if err := cfg.Validate(); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	if err := h.service.Update(c.RequestCtx(), &cfg); err != nil {
+		return respondTextSearchConfigError(c, err, "update text search configuration")
+	}
+
+	log.Info().
+		Str("table", cfg.Table).
+		Str("language", cfg.Language).
+		Str("vector_column", cfg.VectorColumn).
+		Str("user_id", getUserID(c)).
+		Msg("Text search configuration updated")
+
+	return c.JSON(cfg)
+}
+
+// DeleteConfig removes the text search configuration of a table and drops its generated
+// tsvector column
+// DELETE /api/v1/admin/text-search/:id
+func (h *TextSearchHandler) DeleteConfig(c fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return respondInvalidTextSearchConfigID(c)
+	}
+
+	if err := h.service.Delete(c.RequestCtx(), id); err != nil {
+		return respondTextSearchConfigError(c, err, "delete text search configuration")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
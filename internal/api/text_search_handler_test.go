@@ -0,0 +1,36 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func newTestTextSearchApp() *fiber.App {
+	h := NewTextSearchHandler(nil)
+	app := fiber.New()
+	app.Post("/text-search", h.CreateConfig)
+	app.Get("/text-search/:id", h.GetConfig)
+	app.Put("/text-search/:id", h.UpdateConfig)
+	app.Delete("/text-search/:id", h.DeleteConfig)
+	return app
+}
+
+func TestTextSearchHandler_RejectsInvalidRequests(t *testing.T) {
+	const id = "0c6f2a8e-91b4-4d7a-b3e5-5f2d8c1a7e46"
+
+	assertErrorResponses(t, newTestTextSearchApp(), []errorRequestCase{
+		{"config id must be a UUID", "GET", "/text-search/posts", "", fiber.StatusBadRequest, "Invalid text search configuration ID"},
+		{"delete needs a UUID", "DELETE", "/text-search/posts", "", fiber.StatusBadRequest, "Invalid text search configuration ID"},
+		{"create validates the table", "POST", "/text-search",
+			`{"table":"public.posts;","columns":[{"column":"title"}]}`, fiber.StatusBadRequest, "invalid table"},
+		{"create requires columns", "POST", "/text-search",
+			`{"table":"public.posts","columns":[]}`, fiber.StatusBadRequest, "at least one column"},
+		{"create validates the language", "POST", "/text-search",
+			`{"table":"public.posts","language":"fr'","columns":[{"column":"title"}]}`, fiber.StatusBadRequest, "invalid text search language"},
+		{"update validates weights", "PUT", "/text-search/" + id,
+			`{"table":"public.posts","columns":[{"column":"title","weight":"Z"}]}`, fiber.StatusBadRequest, "weight must be one of"},
+		{"update validates the vector column", "PUT", "/text-search/" + id,
+			`{"table":"public.posts","columns":[{"column":"title"}],"vector_column":"title"}`, fiber.StatusBadRequest, "must not be one of"},
+	})
+}
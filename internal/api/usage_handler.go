@@ -0,0 +1,308 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// UsageHandler exposes the per-key and per-user API usage rollups recorded by observability.UsageRecorder
+type UsageHandler struct {
+	db *pgxpool.Pool
+}
+
+// NewUsageHandler creates a new usage analytics handler
+func NewUsageHandler(db *pgxpool.Pool) *UsageHandler {
+	return &UsageHandler{db: db}
+}
+
+// usageQueryParams are the filters shared by the usage endpoints
+type usageQueryParams struct {
+	Granularity string // "hour" or "day"
+	GroupBy     string // "client_key" or "user"
+	StartTime   time.Time
+	EndTime     time.Time
+	ClientKeyID string
+	UserID      string
+	Limit       int
+}
+
+// UsageRow is one aggregated usage row
+type UsageRow struct {
+	Bucket        *time.Time `json:"bucket,omitempty"`
+	ClientKeyID   *string    `json:"client_key_id,omitempty"`
+	ClientKeyName *string    `json:"client_key_name,omitempty"`
+	UserID        *string    `json:"user_id,omitempty"`
+	UserEmail     *string    `json:"user_email,omitempty"`
+	Requests      int64      `json:"requests"`
+	Errors        int64      `json:"errors"`
+	BytesIn       int64      `json:"bytes_in"`
+	BytesOut      int64      `json:"bytes_out"`
+	AvgDurationMs float64    `json:"avg_duration_ms"`
+	MaxDurationMs int64      `json:"max_duration_ms"`
+}
+
+const maxUsageRows = 10000
+
+// parseUsageQueryParams validates the query string of a usage request.
+// The time range defaults to the last 7 days.
+func parseUsageQueryParams(c fiber.Ctx, defaultLimit int) (*usageQueryParams, error) {
+	params := &usageQueryParams{
+		Granularity: c.Query("granularity", "day"),
+		GroupBy:     c.Query("group_by", "client_key"),
+		EndTime:     time.Now().UTC(),
+		ClientKeyID: c.Query("client_key_id"),
+		UserID:      c.Query("user_id"),
+		Limit:       defaultLimit,
+	}
+	params.StartTime = params.EndTime.AddDate(0, 0, -7)
+
+	if params.Granularity != "hour" && params.Granularity != "day" {
+		return nil, fmt.Errorf("granularity must be 'hour' or 'day'")
+	}
+	if params.GroupBy != "client_key" && params.GroupBy != "user" {
+		return nil, fmt.Errorf("group_by must be 'client_key' or 'user'")
+	}
+	if v := c.Query("start_time"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("start_time must be an RFC3339 timestamp")
+		}
+		params.StartTime = t
+	}
+	if v := c.Query("end_time"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("end_time must be an RFC3339 timestamp")
+		}
+		params.EndTime = t
+	}
+	if !params.EndTime.After(params.StartTime) {
+		return nil, fmt.Errorf("end_time must be after start_time")
+	}
+	if params.ClientKeyID != "" {
+		if _, err := uuid.Parse(params.ClientKeyID); err != nil {
+			return nil, fmt.Errorf("client_key_id must be a UUID")
+		}
+	}
+	if params.UserID != "" {
+		if _, err := uuid.Parse(params.UserID); err != nil {
+			return nil, fmt.Errorf("user_id must be a UUID")
+		}
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("limit must be a positive integer")
+		}
+		params.Limit = min(limit, maxUsageRows)
+	}
+	return params, nil
+}
+
+// buildUsageQuery builds the aggregation over api.usage_hourly.
+// With bucketed=false rows are totals over the whole range, ordered by request count,
+// which is what the top consumers view needs.
+func buildUsageQuery(params *usageQueryParams, bucketed bool) (string, []interface{}) {
+	args := []interface{}{params.StartTime, params.EndTime}
+	where := []string{"u.bucket >= $1", "u.bucket < $2"}
+
+	if params.ClientKeyID != "" {
+		args = append(args, params.ClientKeyID)
+		where = append(where, fmt.Sprintf("u.client_key_id = $%d", len(args)))
+	}
+	if params.UserID != "" {
+		args = append(args, params.UserID)
+		where = append(where, fmt.Sprintf("u.user_id = $%d", len(args)))
+	}
+
+	var subject, join, groupBy string
+	if params.GroupBy == "user" {
+		subject = "NULL::text AS client_key_id, NULL::text AS client_key_name, u.user_id::text AS user_id, usr.email AS user_email"
+		join = "LEFT JOIN auth.users usr ON usr.id = u.user_id"
+		groupBy = "u.user_id, usr.email"
+	} else {
+		subject = "u.client_key_id::text AS client_key_id, k.name AS client_key_name, NULL::text AS user_id, NULL::text AS user_email"
+		join = "LEFT JOIN auth.client_keys k ON k.id = u.client_key_id"
+		groupBy = "u.client_key_id, k.name"
+	}
+
+	bucketCol := "NULL::timestamptz AS bucket"
+	orderBy := "requests DESC"
+	if bucketed {
+		// granularity is validated to 'hour' or 'day' but is bound anyway
+		args = append(args, params.Granularity)
+		bucketCol = fmt.Sprintf("date_trunc($%d, u.bucket) AS bucket", len(args))
+		groupBy = "1, " + groupBy
+		orderBy = "bucket ASC, requests DESC"
+	}
+
+	args = append(args, params.Limit)
+	query := fmt.Sprintf(`
+		SELECT %s, %s,
+			SUM(u.request_count)::bigint AS requests,
+			SUM(u.error_count)::bigint AS errors,
+			SUM(u.bytes_in)::bigint AS bytes_in,
+			SUM(u.bytes_out)::bigint AS bytes_out,
+			COALESCE(SUM(u.total_duration_ms)::float8 / NULLIF(SUM(u.request_count), 0), 0) AS avg_duration_ms,
+			MAX(u.max_duration_ms)::bigint AS max_duration_ms
+		FROM api.usage_hourly u
+		%s
+		WHERE %s
+		GROUP BY %s
+		ORDER BY %s
+		LIMIT $%d`,
+		bucketCol, subject, join, strings.Join(where, " AND "), groupBy, orderBy, len(args))
+
+	return query, args
+}
+
+// queryUsage runs a usage aggregation
+func (h *UsageHandler) queryUsage(c fiber.Ctx, params *usageQueryParams, bucketed bool) ([]UsageRow, error) {
+	query, args := buildUsageQuery(params, bucketed)
+
+	rows, err := h.db.Query(c.RequestCtx(), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []UsageRow{}
+	for rows.Next() {
+		var row UsageRow
+		if err := rows.Scan(&row.Bucket, &row.ClientKeyID, &row.ClientKeyName, &row.UserID, &row.UserEmail,
+			&row.Requests, &row.Errors, &row.BytesIn, &row.BytesOut, &row.AvgDurationMs, &row.MaxDurationMs); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// GetUsage returns usage per client key or user in hourly or daily buckets
+// GET /api/v1/admin/usage?granularity=day&group_by=client_key&start_time=...&end_time=...
+func (h *UsageHandler) GetUsage(c fiber.Ctx) error {
+	params, err := parseUsageQueryParams(c, 1000)
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	rows, err := h.queryUsage(c, params, true)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to query API usage")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to query API usage", ErrCodeOperationFailed)
+	}
+
+	return c.JSON(fiber.Map{
+		"granularity": params.Granularity,
+		"group_by":    params.GroupBy,
+		"start_time":  params.StartTime,
+		"end_time":    params.EndTime,
+		"usage":       rows,
+	})
+}
+
+// GetTopConsumers returns the client keys or users with the most requests in the range
+// GET /api/v1/admin/usage/top?group_by=client_key&start_time=...&end_time=...&limit=10
+func (h *UsageHandler) GetTopConsumers(c fiber.Ctx) error {
+	params, err := parseUsageQueryParams(c, 10)
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	rows, err := h.queryUsage(c, params, false)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to query top API consumers")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to query API usage", ErrCodeOperationFailed)
+	}
+
+	return c.JSON(fiber.Map{
+		"group_by":   params.GroupBy,
+		"start_time": params.StartTime,
+		"end_time":   params.EndTime,
+		"consumers":  rows,
+	})
+}
+
+// ExportUsage downloads bucketed usage as CSV or JSON
+// GET /api/v1/admin/usage/export?format=csv&granularity=hour&group_by=user
+func (h *UsageHandler) ExportUsage(c fiber.Ctx) error {
+	format := c.Query("format", "csv")
+	if format != "csv" && format != "json" {
+		return SendBadRequest(c, "Invalid export format. Must be 'csv' or 'json'", ErrCodeInvalidFormat)
+	}
+
+	params, err := parseUsageQueryParams(c, maxUsageRows)
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	rows, err := h.queryUsage(c, params, true)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to export API usage")
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to export API usage", ErrCodeOperationFailed)
+	}
+
+	filename := fmt.Sprintf("usage_%s_%s_%s", params.GroupBy, params.Granularity, params.StartTime.Format("20060102"))
+	if format == "json" {
+		c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.json", filename))
+		return c.JSON(rows)
+	}
+
+	data, err := usageRowsToCSV(rows)
+	if err != nil {
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, fmt.Sprintf("Failed to generate CSV: %v", err), ErrCodeOperationFailed)
+	}
+
+	c.Set("Content-Type", "text/csv")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.csv", filename))
+	return c.Send(data)
+}
+
+// usageRowsToCSV renders usage rows as CSV with a header line
+func usageRowsToCSV(rows []UsageRow) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	header := []string{"bucket", "client_key_id", "client_key_name", "user_id", "user_email",
+		"requests", "errors", "bytes_in", "bytes_out", "avg_duration_ms", "max_duration_ms"}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	str := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	for _, row := range rows {
+		bucket := ""
+		if row.Bucket != nil {
+			bucket = row.Bucket.UTC().Format(time.RFC3339)
+		}
+		record := []string{
+			bucket, str(row.ClientKeyID), str(row.ClientKeyName), str(row.UserID), str(row.UserEmail),
+			strconv.FormatInt(row.Requests, 10),
+			strconv.FormatInt(row.Errors, 10),
+			strconv.FormatInt(row.BytesIn, 10),
+			strconv.FormatInt(row.BytesOut, 10),
+			strconv.FormatFloat(row.AvgDurationMs, 'f', 2, 64),
+			strconv.FormatInt(row.MaxDurationMs, 10),
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
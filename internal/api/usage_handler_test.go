@@ -0,0 +1,111 @@
+package api
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUsageQueryParams(t *testing.T) {
+	parse := func(query string) (*usageQueryParams, error) {
+		var params *usageQueryParams
+		var parseErr error
+		app := fiber.New()
+		app.Get("/usage", func(c fiber.Ctx) error {
+			params, parseErr = parseUsageQueryParams(c, 100)
+			return nil
+		})
+		resp, err := app.Test(httptest.NewRequest("GET", "/usage?"+query, nil))
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return params, parseErr
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		params, err := parse("")
+		require.NoError(t, err)
+		assert.Equal(t, "day", params.Granularity)
+		assert.Equal(t, "client_key", params.GroupBy)
+		assert.Equal(t, 100, params.Limit)
+		assert.WithinDuration(t, params.EndTime.AddDate(0, 0, -7), params.StartTime, time.Second)
+	})
+
+	t.Run("explicit values", func(t *testing.T) {
+		params, err := parse("granularity=hour&group_by=user&start_time=2026-03-01T00:00:00Z&end_time=2026-03-02T00:00:00Z&limit=50000")
+		require.NoError(t, err)
+		assert.Equal(t, "hour", params.Granularity)
+		assert.Equal(t, "user", params.GroupBy)
+		assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), params.StartTime)
+		assert.Equal(t, maxUsageRows, params.Limit)
+	})
+
+	for _, query := range []string{
+		"granularity=minute",
+		"group_by=ip",
+		"start_time=yesterday",
+		"start_time=2026-03-02T00:00:00Z&end_time=2026-03-01T00:00:00Z",
+		"client_key_id=not-a-uuid",
+		"user_id=1",
+		"limit=0",
+	} {
+		t.Run("rejects "+query, func(t *testing.T) {
+			_, err := parse(query)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestBuildUsageQuery(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+
+	t.Run("bucketed per client key", func(t *testing.T) {
+		query, args := buildUsageQuery(&usageQueryParams{
+			Granularity: "hour", GroupBy: "client_key", StartTime: start, EndTime: end, Limit: 100,
+		}, true)
+
+		assert.Contains(t, query, "date_trunc($3, u.bucket) AS bucket")
+		assert.Contains(t, query, "LEFT JOIN auth.client_keys k ON k.id = u.client_key_id")
+		assert.Contains(t, query, "GROUP BY 1, u.client_key_id, k.name")
+		assert.Contains(t, query, "LIMIT $4")
+		assert.Equal(t, []interface{}{start, end, "hour", 100}, args)
+	})
+
+	t.Run("totals per user with filters", func(t *testing.T) {
+		keyID := "7b0c6e1e-8b7a-4f34-a4de-0f1f3f2d8c11"
+		userID := "2f1d4e6a-1f0e-4a9a-9a57-5b7f1f3b2c22"
+		query, args := buildUsageQuery(&usageQueryParams{
+			Granularity: "day", GroupBy: "user", StartTime: start, EndTime: end,
+			ClientKeyID: keyID, UserID: userID, Limit: 10,
+		}, false)
+
+		assert.Contains(t, query, "u.client_key_id = $3")
+		assert.Contains(t, query, "u.user_id = $4")
+		assert.Contains(t, query, "LEFT JOIN auth.users usr ON usr.id = u.user_id")
+		assert.Contains(t, query, "ORDER BY requests DESC")
+		assert.NotContains(t, query, "date_trunc")
+		assert.Equal(t, []interface{}{start, end, keyID, userID, 10}, args)
+	})
+}
+
+func TestUsageRowsToCSV(t *testing.T) {
+	bucket := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	keyID := "7b0c6e1e-8b7a-4f34-a4de-0f1f3f2d8c11"
+	keyName := "mobile, ios"
+
+	data, err := usageRowsToCSV([]UsageRow{{
+		Bucket: &bucket, ClientKeyID: &keyID, ClientKeyName: &keyName,
+		Requests: 10, Errors: 1, BytesIn: 100, BytesOut: 2000, AvgDurationMs: 12.5, MaxDurationMs: 40,
+	}})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "bucket,client_key_id,client_key_name,user_id"))
+	assert.Equal(t, `2026-03-01T10:00:00Z,`+keyID+`,"mobile, ios",,,10,1,100,2000,12.50,40`, lines[1])
+}
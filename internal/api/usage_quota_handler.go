@@ -0,0 +1,148 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/middleware"
+	"github.com/nimbleflux/fluxbase/internal/quota"
+	"github.com/rs/zerolog/log"
+)
+
+// UsageQuotaHandler manages the usage quotas of users and organizations and reports usage
+// against them
+type UsageQuotaHandler struct {
+	quotas *quota.Service // nil when quotas are disabled
+}
+
+// NewUsageQuotaHandler creates a new usage quota handler. quotas may be nil.
+func NewUsageQuotaHandler(quotas *quota.Service) *UsageQuotaHandler {
+	return &UsageQuotaHandler{quotas: quotas}
+}
+
+// sendQuotasDisabled responds that usage quotas are not enabled
+func sendQuotasDisabled(c fiber.Ctx) error {
+	return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "Usage quotas are not enabled", ErrCodeFeatureDisabled)
+}
+
+// GetMyUsage returns the usage of the caller and of the active organization of its token,
+// with the limits and reset times of their quotas
+// GET /api/v1/usage
+func (h *UsageQuotaHandler) GetMyUsage(c fiber.Ctx) error {
+	if h.quotas == nil {
+		return sendQuotasDisabled(c)
+	}
+
+	usage := []quota.Usage{}
+	for _, subject := range middleware.QuotaSubjects(c) {
+		subjectUsage, err := h.quotas.Usage(c.RequestCtx(), subject)
+		if err != nil {
+			log.Error().Err(err).Str("subject_id", subject.ID).Msg("Failed to read usage")
+			return SendOperationFailed(c, "read usage")
+		}
+		usage = append(usage, subjectUsage...)
+	}
+
+	return c.JSON(fiber.Map{
+		"usage": usage,
+	})
+}
+
+// ListQuotas returns quotas, optionally of one subject type or subject
+// GET /api/v1/admin/quotas?subject_type=user&subject_id=...
+func (h *UsageQuotaHandler) ListQuotas(c fiber.Ctx) error {
+	if h.quotas == nil {
+		return sendQuotasDisabled(c)
+	}
+
+	subject := quota.Subject{Type: quota.SubjectType(c.Query("subject_type")), ID: c.Query("subject_id")}
+	if subject.Type != "" && subject.Type != quota.SubjectUser && subject.Type != quota.SubjectOrganization {
+		return SendBadRequest(c, "subject_type must be user or organization", ErrCodeInvalidInput)
+	}
+	if subject.ID != "" {
+		if _, err := uuid.Parse(subject.ID); err != nil {
+			return SendInvalidID(c, "subject_id")
+		}
+	}
+
+	quotas, err := h.quotas.ListQuotas(c.RequestCtx(), subject)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list usage quotas")
+		return SendOperationFailed(c, "list quotas")
+	}
+
+	return c.JSON(fiber.Map{
+		"quotas": quotas,
+	})
+}
+
+// SetQuota creates the quota of a subject, metric and period, or replaces its limit
+// PUT /api/v1/admin/quotas {"subject_type": "organization", "subject_id": "...", "metric": "api_calls", "period": "month", "limit": 100000}
+func (h *UsageQuotaHandler) SetQuota(c fiber.Ctx) error {
+	if h.quotas == nil {
+		return sendQuotasDisabled(c)
+	}
+
+	var req quota.Quota
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+	if err := req.Validate(); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeValidationFailed)
+	}
+
+	saved, err := h.quotas.SetQuota(c.RequestCtx(), req)
+	if err != nil {
+		log.Error().Err(err).Str("subject_id", req.SubjectID).Msg("Failed to set usage quota")
+		return SendOperationFailed(c, "set quota")
+	}
+
+	return c.JSON(saved)
+}
+
+// DeleteQuota deletes a quota
+// DELETE /api/v1/admin/quotas/:id
+func (h *UsageQuotaHandler) DeleteQuota(c fiber.Ctx) error {
+	if h.quotas == nil {
+		return sendQuotasDisabled(c)
+	}
+
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return SendInvalidID(c, "quota ID")
+	}
+
+	if err := h.quotas.DeleteQuota(c.RequestCtx(), id); err != nil {
+		if errors.Is(err, quota.ErrQuotaNotFound) {
+			return SendResourceNotFound(c, "Quota")
+		}
+		log.Error().Err(err).Str("id", id).Msg("Failed to delete usage quota")
+		return SendOperationFailed(c, "delete quota")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetSubjectUsage returns the usage of a user or organization against its quotas
+// GET /api/v1/admin/quotas/usage?subject_type=organization&subject_id=...
+func (h *UsageQuotaHandler) GetSubjectUsage(c fiber.Ctx) error {
+	if h.quotas == nil {
+		return sendQuotasDisabled(c)
+	}
+
+	subject := quota.Subject{Type: quota.SubjectType(c.Query("subject_type")), ID: c.Query("subject_id")}
+	if err := subject.Validate(); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	usage, err := h.quotas.Usage(c.RequestCtx(), subject)
+	if err != nil {
+		log.Error().Err(err).Str("subject_id", subject.ID).Msg("Failed to read usage")
+		return SendOperationFailed(c, "read usage")
+	}
+
+	return c.JSON(fiber.Map{
+		"usage": usage,
+	})
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/quota"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageQuotaHandler_QuotasDisabled(t *testing.T) {
+	h := NewUsageQuotaHandler(nil)
+	app := fiber.New()
+	app.Get("/usage", h.GetMyUsage)
+	app.Get("/quotas", h.ListQuotas)
+	app.Put("/quotas", h.SetQuota)
+	app.Get("/quotas/usage", h.GetSubjectUsage)
+	app.Delete("/quotas/:id", h.DeleteQuota)
+
+	for _, route := range [][2]string{
+		{"GET", "/usage"},
+		{"GET", "/quotas"},
+		{"PUT", "/quotas"},
+		{"GET", "/quotas/usage"},
+		{"DELETE", "/quotas/3f1c9a52-7d3e-4c41-9f0a-2b6d8e5a1c70"},
+	} {
+		t.Run(route[0]+" "+route[1], func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(route[0], route[1], nil))
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+		})
+	}
+}
+
+func TestUsageQuotaHandler_Validation(t *testing.T) {
+	// Requests are rejected before the service touches the database
+	h := NewUsageQuotaHandler(quota.NewService(nil, 0, 0))
+	app := fiber.New()
+	app.Get("/quotas", h.ListQuotas)
+	app.Put("/quotas", h.SetQuota)
+	app.Get("/quotas/usage", h.GetSubjectUsage)
+	app.Delete("/quotas/:id", h.DeleteQuota)
+
+	tests := []struct {
+		name, method, path, body string
+	}{
+		{"unknown subject type filter", "GET", "/quotas?subject_type=team", ""},
+		{"invalid subject ID filter", "GET", "/quotas?subject_id=alice", ""},
+		{"invalid body", "PUT", "/quotas", `{`},
+		{"storage quota per month", "PUT", "/quotas", `{"subject_type":"user","subject_id":"3f1c9a52-7d3e-4c41-9f0a-2b6d8e5a1c70","metric":"storage_bytes","period":"month","limit":1}`},
+		{"usage without subject", "GET", "/quotas/usage", ""},
+		{"invalid quota ID", "DELETE", "/quotas/not-a-uuid", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+	}
+}
@@ -1,17 +1,25 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/audit"
 	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/rs/zerolog/log"
 )
 
 // UserManagementHandler handles admin user management operations
 type UserManagementHandler struct {
 	userMgmtService *auth.UserManagementService
 	authService     *auth.Service
+	auditLogger     *audit.Logger // nil when the audit log is disabled
 }
 
 // NewUserManagementHandler creates a new user management handler
@@ -22,110 +30,99 @@ func NewUserManagementHandler(userMgmtService *auth.UserManagementService, authS
 	}
 }
 
-// ListUsers lists all users with enriched metadata
+// SetAuditLogger sets the logger that records changes to users in the audit log
+func (h *UserManagementHandler) SetAuditLogger(logger *audit.Logger) {
+	h.auditLogger = logger
+}
+
+// recordUserAction records an admin action on a user in the audit log
+func (h *UserManagementHandler) recordUserAction(c fiber.Ctx, action, userID, userType string, details map[string]any) {
+	if details == nil {
+		details = map[string]any{}
+	}
+	details["user_type"] = userType
+	h.auditLogger.RecordRequest(c, audit.Event{
+		Action:       action,
+		ResourceType: "user",
+		ResourceID:   userID,
+		Details:      details,
+	})
+}
+
+// ListUsers lists users with enriched metadata, newest first
+// GET /api/v1/admin/users?search=...&metadata={"plan":"pro"}&banned=true&exclude_admins=true&limit=100&offset=0
 func (h *UserManagementHandler) ListUsers(c fiber.Ctx) error {
 	// Nil check for service (can happen in tests)
 	if h.userMgmtService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "User management service not initialized",
-		})
+		return SendInternalError(c, "User management service not initialized")
 	}
 
-	const defaultLimit = 100
-	const maxLimit = 1000
-
-	excludeAdmins := fiber.Query[bool](c, "exclude_admins", false)
-	search := c.Query("search", "")
-	limit := fiber.Query[int](c, "limit", defaultLimit)
-	offset := fiber.Query[int](c, "offset", 0)
-	userType := c.Query("type", "app") // "app" for auth.users, "dashboard" for dashboard.users
-
-	// Normalize pagination parameters
-	limit, offset = NormalizePaginationParams(limit, offset, defaultLimit, maxLimit)
-
-	users, err := h.userMgmtService.ListEnrichedUsers(c.RequestCtx(), userType)
+	opts, err := parseListUsersOptions(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
-	// Ensure we never return null (nil slice serializes to null in JSON)
-	if users == nil {
-		users = []*auth.EnrichedUser{}
+	users, total, err := h.userMgmtService.ListUsers(c.RequestCtx(), opts)
+	if err != nil {
+		return SendInternalError(c, err.Error())
 	}
 
-	// Filter users based on query parameters
-	filteredUsers := users
+	return c.JSON(fiber.Map{
+		"users":  users,
+		"total":  total,
+		"limit":  opts.Limit,
+		"offset": opts.Offset,
+	})
+}
 
-	// Exclude admins if requested
-	if excludeAdmins {
-		nonAdminUsers := make([]*auth.EnrichedUser, 0)
-		for _, user := range filteredUsers {
-			if user.Role != "admin" {
-				nonAdminUsers = append(nonAdminUsers, user)
-			}
-		}
-		filteredUsers = nonAdminUsers
-	}
-
-	// Search by email if provided (case-insensitive)
-	if search != "" {
-		searchLower := strings.ToLower(search)
-		searchResults := make([]*auth.EnrichedUser, 0)
-		for _, user := range filteredUsers {
-			emailLower := strings.ToLower(user.Email)
-			if strings.Contains(emailLower, searchLower) {
-				searchResults = append(searchResults, user)
-			}
-		}
-		filteredUsers = searchResults
-	}
+// parseListUsersOptions reads the filters and pagination of ListUsers from the query string
+func parseListUsersOptions(c fiber.Ctx) (auth.ListUsersOptions, error) {
+	const defaultLimit = 100
+	const maxLimit = 1000
 
-	// Calculate total before pagination
-	total := len(filteredUsers)
+	opts := auth.ListUsersOptions{
+		UserType:      c.Query("type", "app"), // "app" for auth.users, "dashboard" for dashboard.users
+		Search:        strings.TrimSpace(c.Query("search")),
+		ExcludeAdmins: fiber.Query[bool](c, "exclude_admins", false),
+	}
+	opts.Limit, opts.Offset = NormalizePaginationParams(fiber.Query[int](c, "limit", defaultLimit), fiber.Query[int](c, "offset", 0), defaultLimit, maxLimit)
 
-	// Apply offset
-	if offset >= len(filteredUsers) {
-		filteredUsers = []*auth.EnrichedUser{}
-	} else {
-		filteredUsers = filteredUsers[offset:]
+	if metadata := c.Query("metadata"); metadata != "" {
+		if err := json.Unmarshal([]byte(metadata), &opts.Metadata); err != nil || opts.Metadata == nil {
+			return opts, errors.New("metadata must be a JSON object, e.g. {\"plan\":\"pro\"}")
+		}
 	}
 
-	// Apply limit
-	if len(filteredUsers) > limit {
-		filteredUsers = filteredUsers[:limit]
+	if banned := c.Query("banned"); banned != "" {
+		value, err := strconv.ParseBool(banned)
+		if err != nil {
+			return opts, errors.New("banned must be true or false")
+		}
+		opts.Banned = &value
 	}
 
-	return c.JSON(fiber.Map{
-		"users":  filteredUsers,
-		"total":  total,
-		"limit":  limit,
-		"offset": offset,
-	})
+	return opts, nil
 }
 
 // GetUserByID gets a single user by ID with enriched metadata
+// GET /api/v1/admin/users/:id
 func (h *UserManagementHandler) GetUserByID(c fiber.Ctx) error {
 	if h.userMgmtService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "User management service not initialized",
-		})
+		return SendInternalError(c, "User management service not initialized")
 	}
 
 	userID := c.Params("id")
 	userType := c.Query("type", "app") // "app" for auth.users, "dashboard" for dashboard.users
+	if _, err := uuid.Parse(userID); err != nil {
+		return SendInvalidID(c, "user ID")
+	}
 
 	user, err := h.userMgmtService.GetEnrichedUserByID(c.RequestCtx(), userID, userType)
 	if err != nil {
 		if errors.Is(err, auth.ErrUserNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "User not found",
-			})
+			return SendNotFound(c, "User not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendInternalError(c, err.Error())
 	}
 
 	return c.JSON(user)
@@ -135,52 +132,107 @@ func (h *UserManagementHandler) GetUserByID(c fiber.Ctx) error {
 func (h *UserManagementHandler) InviteUser(c fiber.Ctx) error {
 	var req auth.InviteUserRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	if h.userMgmtService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "User management service not initialized",
-		})
+		return SendInternalError(c, "User management service not initialized")
 	}
 
 	userType := c.Query("type", "app") // "app" for auth.users, "dashboard" for dashboard.users
 
 	resp, err := h.userMgmtService.InviteUser(c.RequestCtx(), req, userType)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendInternalError(c, err.Error())
 	}
 
+	h.recordUserAction(c, audit.ActionUserInvite, resp.User.ID, userType, map[string]any{
+		"email":      resp.User.Email,
+		"role":       resp.User.Role,
+		"email_sent": resp.EmailSent,
+	})
 	return c.Status(fiber.StatusCreated).JSON(resp)
 }
 
-// DeleteUser deletes a user
-func (h *UserManagementHandler) DeleteUser(c fiber.Ctx) error {
+// ImportUsers imports app users from a Supabase, Firebase or Auth0 export
+// POST /api/v1/admin/users/import
+func (h *UserManagementHandler) ImportUsers(c fiber.Ctx) error {
+	var req auth.UserImportRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+
 	if h.userMgmtService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "User management service not initialized",
+		return SendInternalError(c, "User management service not initialized")
+	}
+
+	result, err := h.userMgmtService.ImportUsers(c.RequestCtx(), req)
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	if !req.DryRun {
+		h.auditLogger.RecordRequest(c, audit.Event{
+			Action:       audit.ActionUserImport,
+			ResourceType: "user",
+			Details: map[string]any{
+				"format":   req.Format,
+				"imported": result.Imported,
+				"skipped":  result.Skipped,
+				"failed":   result.Failed,
+			},
 		})
 	}
+	return c.JSON(result)
+}
+
+// ListDuplicateEmails lists groups of app users whose addresses are aliases of each other
+// (e.g. Gmail dots or "+tag" suffixes), typically created before email normalization was enabled
+// GET /api/v1/admin/users/duplicate-emails
+func (h *UserManagementHandler) ListDuplicateEmails(c fiber.Ctx) error {
+	if h.userMgmtService == nil {
+		return SendInternalError(c, "User management service not initialized")
+	}
+
+	groups, err := h.userMgmtService.FindDuplicateEmails(c.RequestCtx())
+	if err != nil {
+		return SendInternalError(c, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"groups": groups,
+		"total":  len(groups),
+	})
+}
+
+// DeleteUser permanently deletes a user with its sessions, identities and MFA factors
+// DELETE /api/v1/admin/users/:id
+func (h *UserManagementHandler) DeleteUser(c fiber.Ctx) error {
+	if h.userMgmtService == nil {
+		return SendInternalError(c, "User management service not initialized")
+	}
 
 	userID := c.Params("id")
 	userType := c.Query("type", "app") // "app" for auth.users, "dashboard" for dashboard.users
+	if _, err := uuid.Parse(userID); err != nil {
+		return SendInvalidID(c, "user ID")
+	}
+	if isCurrentUser(c, userID) {
+		return SendBadRequest(c, "You cannot delete your own account", ErrCodeInvalidInput)
+	}
 
-	err := h.userMgmtService.DeleteUser(c.RequestCtx(), userID, userType)
+	user, err := h.userMgmtService.DeleteUser(c.RequestCtx(), userID, userType)
 	if err != nil {
 		if errors.Is(err, auth.ErrUserNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "User not found",
-			})
+			return SendNotFound(c, "User not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendInternalError(c, err.Error())
 	}
 
+	h.recordUserAction(c, audit.ActionUserDelete, userID, userType, map[string]any{
+		"email": user.Email,
+		"role":  user.Role,
+	})
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "User deleted successfully",
 	})
@@ -195,29 +247,25 @@ func (h *UserManagementHandler) UpdateUserRole(c fiber.Ctx) error {
 		Role string `json:"role"`
 	}
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	if h.userMgmtService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "User management service not initialized",
-		})
+		return SendInternalError(c, "User management service not initialized")
 	}
 
 	user, err := h.userMgmtService.UpdateUserRole(c.RequestCtx(), userID, req.Role, userType)
 	if err != nil {
 		if errors.Is(err, auth.ErrUserNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "User not found",
-			})
+			return SendNotFound(c, "User not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendInternalError(c, err.Error())
 	}
 
+	h.recordUserAction(c, audit.ActionUserUpdate, userID, userType, map[string]any{
+		"fields": []string{"role"},
+		"role":   req.Role,
+	})
 	return c.JSON(user)
 }
 
@@ -228,38 +276,45 @@ func (h *UserManagementHandler) UpdateUser(c fiber.Ctx) error {
 
 	var req auth.UpdateAdminUserRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	if h.userMgmtService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "User management service not initialized",
-		})
+		return SendInternalError(c, "User management service not initialized")
 	}
 
 	user, err := h.userMgmtService.UpdateUser(c.RequestCtx(), userID, req, userType)
 	if err != nil {
 		if errors.Is(err, auth.ErrUserNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "User not found",
-			})
+			return SendNotFound(c, "User not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendInternalError(c, err.Error())
 	}
 
+	// Record which fields changed, never the password
+	var fields []string
+	if req.Email != nil {
+		fields = append(fields, "email")
+	}
+	if req.Role != nil {
+		fields = append(fields, "role")
+	}
+	if req.Password != nil && *req.Password != "" {
+		fields = append(fields, "password")
+	}
+	if req.UserMetadata != nil {
+		fields = append(fields, "user_metadata")
+	}
+	h.recordUserAction(c, audit.ActionUserUpdate, userID, userType, map[string]any{
+		"fields": fields,
+	})
 	return c.JSON(user)
 }
 
 // ResetUserPassword resets a user's password
 func (h *UserManagementHandler) ResetUserPassword(c fiber.Ctx) error {
 	if h.userMgmtService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "User management service not initialized",
-		})
+		return SendInternalError(c, "User management service not initialized")
 	}
 
 	userID := c.Params("id")
@@ -268,26 +323,200 @@ func (h *UserManagementHandler) ResetUserPassword(c fiber.Ctx) error {
 	result, err := h.userMgmtService.ResetUserPassword(c.RequestCtx(), userID, userType)
 	if err != nil {
 		if errors.Is(err, auth.ErrUserNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "User not found",
-			})
+			return SendNotFound(c, "User not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendInternalError(c, err.Error())
 	}
 
+	h.recordUserAction(c, audit.ActionUserPasswordReset, userID, userType, map[string]any{
+		"method": "temporary_password",
+	})
 	return c.JSON(fiber.Map{
 		"message": result,
 	})
 }
 
+// ForcePasswordReset makes a user choose a new password: their current password stops working
+// and they are signed out everywhere. App users are emailed a password reset link; when that
+// fails the response says why, and an admin can set a password with UpdateUser instead.
+// POST /api/v1/admin/users/:id/force-password-reset
+func (h *UserManagementHandler) ForcePasswordReset(c fiber.Ctx) error {
+	if h.userMgmtService == nil {
+		return SendInternalError(c, "User management service not initialized")
+	}
+
+	userID := c.Params("id")
+	userType := c.Query("type", "app") // "app" for auth.users, "dashboard" for dashboard.users
+	if _, err := uuid.Parse(userID); err != nil {
+		return SendInvalidID(c, "user ID")
+	}
+
+	user, err := h.userMgmtService.ForcePasswordReset(c.RequestCtx(), userID, userType)
+	if err != nil {
+		if errors.Is(err, auth.ErrUserNotFound) {
+			return SendNotFound(c, "User not found")
+		}
+		return SendInternalError(c, err.Error())
+	}
+
+	emailSent := false
+	message := "Password reset forced. The user must reset their password from the sign-in page."
+	if userType != "dashboard" && h.authService != nil {
+		if err := h.authService.RequestPasswordReset(c.RequestCtx(), user.Email, ""); err != nil {
+			log.Warn().Err(err).Str("user_id", userID).Msg("Failed to send forced password reset email")
+			message = fmt.Sprintf("Password reset forced, but the reset email could not be sent: %v", err)
+		} else {
+			emailSent = true
+			message = fmt.Sprintf("Password reset forced. A reset link was sent to %s", user.Email)
+		}
+	}
+
+	h.recordUserAction(c, audit.ActionUserPasswordReset, userID, userType, map[string]any{
+		"method":     "forced",
+		"email_sent": emailSent,
+	})
+	return c.JSON(fiber.Map{
+		"message":    message,
+		"email_sent": emailSent,
+	})
+}
+
+// VerifyEmail marks a user's email as verified without a verification link
+// POST /api/v1/admin/users/:id/verify-email
+func (h *UserManagementHandler) VerifyEmail(c fiber.Ctx) error {
+	if h.userMgmtService == nil {
+		return SendInternalError(c, "User management service not initialized")
+	}
+
+	userID := c.Params("id")
+	userType := c.Query("type", "app") // "app" for auth.users, "dashboard" for dashboard.users
+	if _, err := uuid.Parse(userID); err != nil {
+		return SendInvalidID(c, "user ID")
+	}
+
+	user, err := h.userMgmtService.VerifyUserEmail(c.RequestCtx(), userID, userType)
+	if err != nil {
+		if errors.Is(err, auth.ErrUserNotFound) {
+			return SendNotFound(c, "User not found")
+		}
+		return SendInternalError(c, err.Error())
+	}
+
+	h.recordUserAction(c, audit.ActionUserEmailVerify, userID, userType, map[string]any{
+		"email": user.Email,
+	})
+	return c.JSON(user)
+}
+
+// BanUserRequest is the body of a ban. Without a duration or until the ban lasts until the
+// user is unbanned.
+type BanUserRequest struct {
+	Duration string     `json:"duration,omitempty"` // e.g. "24h" or "30m"
+	Until    *time.Time `json:"until,omitempty"`
+	Reason   string     `json:"reason,omitempty"`
+}
+
+// bannedUntil returns when a ban ends, or nil for a ban until the user is unbanned
+func (r BanUserRequest) bannedUntil(now time.Time) (*time.Time, error) {
+	switch {
+	case r.Duration != "" && r.Until != nil:
+		return nil, errors.New("set either duration or until, not both")
+	case r.Duration != "":
+		duration, err := time.ParseDuration(r.Duration)
+		if err != nil || duration <= 0 {
+			return nil, errors.New("duration must be a positive duration such as 24h")
+		}
+		until := now.Add(duration)
+		return &until, nil
+	case r.Until != nil:
+		if !r.Until.After(now) {
+			return nil, errors.New("until must be in the future")
+		}
+		return r.Until, nil
+	}
+	return nil, nil
+}
+
+// BanUser locks a user out and signs them out of all their sessions, until they are unbanned
+// or for a duration
+// POST /api/v1/admin/users/:id/ban {"duration": "72h", "reason": "spam"}
+func (h *UserManagementHandler) BanUser(c fiber.Ctx) error {
+	userID := c.Params("id")
+	userType := c.Query("type", "app") // "app" for auth.users, "dashboard" for dashboard.users
+	if _, err := uuid.Parse(userID); err != nil {
+		return SendInvalidID(c, "user ID")
+	}
+	if isCurrentUser(c, userID) {
+		return SendBadRequest(c, "You cannot ban your own account", ErrCodeInvalidInput)
+	}
+
+	var req BanUserRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&req); err != nil {
+			return SendInvalidBody(c)
+		}
+	}
+	until, err := req.bannedUntil(time.Now())
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeValidationFailed)
+	}
+
+	if h.userMgmtService == nil {
+		return SendInternalError(c, "User management service not initialized")
+	}
+
+	if err := h.userMgmtService.BanUser(c.RequestCtx(), userID, userType, until); err != nil {
+		if errors.Is(err, auth.ErrUserNotFound) {
+			return SendNotFound(c, "User not found")
+		}
+		return SendInternalError(c, err.Error())
+	}
+
+	details := map[string]any{}
+	if req.Reason != "" {
+		details["reason"] = req.Reason
+	}
+	if until != nil {
+		details["until"] = until.UTC().Format(time.RFC3339)
+	}
+	h.recordUserAction(c, audit.ActionUserBan, userID, userType, details)
+
+	return c.JSON(fiber.Map{
+		"message":      "User banned successfully",
+		"banned_until": until,
+	})
+}
+
+// UnbanUser lifts a ban or an automatic lockout of a user
+// POST /api/v1/admin/users/:id/unban
+func (h *UserManagementHandler) UnbanUser(c fiber.Ctx) error {
+	if h.userMgmtService == nil {
+		return SendInternalError(c, "User management service not initialized")
+	}
+
+	userID := c.Params("id")
+	userType := c.Query("type", "app") // "app" for auth.users, "dashboard" for dashboard.users
+	if _, err := uuid.Parse(userID); err != nil {
+		return SendInvalidID(c, "user ID")
+	}
+
+	if err := h.userMgmtService.UnlockUser(c.RequestCtx(), userID, userType); err != nil {
+		if errors.Is(err, auth.ErrUserNotFound) {
+			return SendNotFound(c, "User not found")
+		}
+		return SendInternalError(c, err.Error())
+	}
+
+	h.recordUserAction(c, audit.ActionUserUnban, userID, userType, nil)
+	return c.JSON(fiber.Map{
+		"message": "User unbanned successfully",
+	})
+}
+
 // LockUser locks a user account
 func (h *UserManagementHandler) LockUser(c fiber.Ctx) error {
 	if h.userMgmtService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "User management service not initialized",
-		})
+		return SendInternalError(c, "User management service not initialized")
 	}
 
 	userID := c.Params("id")
@@ -296,15 +525,12 @@ func (h *UserManagementHandler) LockUser(c fiber.Ctx) error {
 	err := h.userMgmtService.LockUser(c.RequestCtx(), userID, userType)
 	if err != nil {
 		if errors.Is(err, auth.ErrUserNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "User not found",
-			})
+			return SendNotFound(c, "User not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendInternalError(c, err.Error())
 	}
 
+	h.recordUserAction(c, audit.ActionUserBan, userID, userType, nil)
 	return c.JSON(fiber.Map{
 		"message": "User account locked successfully",
 	})
@@ -313,9 +539,7 @@ func (h *UserManagementHandler) LockUser(c fiber.Ctx) error {
 // UnlockUser unlocks a user account
 func (h *UserManagementHandler) UnlockUser(c fiber.Ctx) error {
 	if h.userMgmtService == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "User management service not initialized",
-		})
+		return SendInternalError(c, "User management service not initialized")
 	}
 
 	userID := c.Params("id")
@@ -324,20 +548,23 @@ func (h *UserManagementHandler) UnlockUser(c fiber.Ctx) error {
 	err := h.userMgmtService.UnlockUser(c.RequestCtx(), userID, userType)
 	if err != nil {
 		if errors.Is(err, auth.ErrUserNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "User not found",
-			})
+			return SendNotFound(c, "User not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendInternalError(c, err.Error())
 	}
 
+	h.recordUserAction(c, audit.ActionUserUnban, userID, userType, nil)
 	return c.JSON(fiber.Map{
 		"message": "User account unlocked successfully",
 	})
 }
 
+// isCurrentUser reports whether userID is the authenticated caller, who must not lock themselves out
+func isCurrentUser(c fiber.Ctx, userID string) bool {
+	callerID, _ := c.Locals("user_id").(string)
+	return callerID != "" && strings.EqualFold(callerID, userID)
+}
+
 // RegisterRoutes registers user management routes
 func (h *UserManagementHandler) RegisterRoutes(app *fiber.App) {
 	admin := app.Group("/api/v1/admin",
@@ -347,12 +574,18 @@ func (h *UserManagementHandler) RegisterRoutes(app *fiber.App) {
 
 	// User management routes (admin only)
 	admin.Get("/users", h.ListUsers)
+	admin.Get("/users/duplicate-emails", h.ListDuplicateEmails)
 	admin.Get("/users/:id", h.GetUserByID)
 	admin.Post("/users/invite", h.InviteUser)
+	admin.Post("/users/import", h.ImportUsers)
 	admin.Patch("/users/:id", h.UpdateUser)
 	admin.Delete("/users/:id", h.DeleteUser)
 	admin.Patch("/users/:id/role", h.UpdateUserRole)
 	admin.Post("/users/:id/reset-password", h.ResetUserPassword)
+	admin.Post("/users/:id/force-password-reset", h.ForcePasswordReset)
+	admin.Post("/users/:id/verify-email", h.VerifyEmail)
+	admin.Post("/users/:id/ban", h.BanUser)
+	admin.Post("/users/:id/unban", h.UnbanUser)
 	admin.Post("/users/:id/lock", h.LockUser)
 	admin.Post("/users/:id/unlock", h.UnlockUser)
 }
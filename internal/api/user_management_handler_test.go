@@ -6,9 +6,13 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/auth"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -424,6 +428,28 @@ func TestUnlockUser_ParameterParsing(t *testing.T) {
 	})
 }
 
+// =============================================================================
+// ListDuplicateEmails Handler Tests
+// =============================================================================
+
+func TestListDuplicateEmails_NilService(t *testing.T) {
+	app := fiber.New()
+	handler := NewUserManagementHandler(nil, nil)
+
+	app.Get("/users/duplicate-emails", handler.ListDuplicateEmails)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/duplicate-emails", nil)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+
+	var result map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, "User management service not initialized", result["error"])
+}
+
 // =============================================================================
 // RegisterRoutes Tests
 // =============================================================================
@@ -446,6 +472,11 @@ func TestUserManagementHandler_RegisterRoutes(t *testing.T) {
 		assert.NotNil(t, handler.ResetUserPassword)
 		assert.NotNil(t, handler.LockUser)
 		assert.NotNil(t, handler.UnlockUser)
+		assert.NotNil(t, handler.ListDuplicateEmails)
+		assert.NotNil(t, handler.BanUser)
+		assert.NotNil(t, handler.UnbanUser)
+		assert.NotNil(t, handler.ForcePasswordReset)
+		assert.NotNil(t, handler.VerifyEmail)
 
 		_ = app // Prevent unused variable warning
 	})
@@ -515,3 +546,161 @@ func TestUserTypeParameter(t *testing.T) {
 		assert.Equal(t, "app", defaultType)
 	})
 }
+
+// =============================================================================
+// ListUsers Filter Tests
+// =============================================================================
+
+func TestParseListUsersOptions(t *testing.T) {
+	parse := func(t *testing.T, query string) (auth.ListUsersOptions, error) {
+		t.Helper()
+		app := fiber.New()
+		var opts auth.ListUsersOptions
+		var parseErr error
+		app.Get("/users", func(c fiber.Ctx) error {
+			opts, parseErr = parseListUsersOptions(c)
+			return nil
+		})
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/users"+query, nil))
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return opts, parseErr
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		opts, err := parse(t, "")
+		require.NoError(t, err)
+		assert.Equal(t, "app", opts.UserType)
+		assert.Equal(t, 100, opts.Limit)
+		assert.Equal(t, 0, opts.Offset)
+		assert.Nil(t, opts.Metadata)
+		assert.Nil(t, opts.Banned)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		query := "?type=dashboard&search=%20alice%20&metadata=" + url.QueryEscape(`{"plan":"pro"}`) + "&banned=true&exclude_admins=true&limit=20&offset=40"
+		opts, err := parse(t, query)
+		require.NoError(t, err)
+		assert.Equal(t, "dashboard", opts.UserType)
+		assert.Equal(t, "alice", opts.Search)
+		assert.Equal(t, map[string]interface{}{"plan": "pro"}, opts.Metadata)
+		require.NotNil(t, opts.Banned)
+		assert.True(t, *opts.Banned)
+		assert.True(t, opts.ExcludeAdmins)
+		assert.Equal(t, 20, opts.Limit)
+		assert.Equal(t, 40, opts.Offset)
+	})
+
+	t.Run("metadata must be a JSON object", func(t *testing.T) {
+		for _, metadata := range []string{"plan", `["pro"]`, "null"} {
+			_, err := parse(t, "?metadata="+url.QueryEscape(metadata))
+			assert.Error(t, err, metadata)
+		}
+	})
+
+	t.Run("banned must be a boolean", func(t *testing.T) {
+		_, err := parse(t, "?banned=maybe")
+		assert.Error(t, err)
+	})
+}
+
+// =============================================================================
+// BanUser Handler Tests
+// =============================================================================
+
+func TestBanUserRequest_BannedUntil(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tomorrow := now.Add(24 * time.Hour)
+	yesterday := now.Add(-24 * time.Hour)
+
+	until, err := BanUserRequest{}.bannedUntil(now)
+	require.NoError(t, err)
+	assert.Nil(t, until, "bans without an end last until the user is unbanned")
+
+	until, err = BanUserRequest{Duration: "72h"}.bannedUntil(now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(72*time.Hour), *until)
+
+	until, err = BanUserRequest{Until: &tomorrow}.bannedUntil(now)
+	require.NoError(t, err)
+	assert.Equal(t, tomorrow, *until)
+
+	for name, req := range map[string]BanUserRequest{
+		"duration and until": {Duration: "1h", Until: &tomorrow},
+		"invalid duration":   {Duration: "a week"},
+		"negative duration":  {Duration: "-1h"},
+		"until in the past":  {Until: &yesterday},
+	} {
+		_, err := req.bannedUntil(now)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestBanUser_Validation(t *testing.T) {
+	const userID = "550e8400-e29b-41d4-a716-446655440000"
+
+	newApp := func() *fiber.App {
+		app := fiber.New()
+		handler := NewUserManagementHandler(nil, nil)
+		app.Use(func(c fiber.Ctx) error {
+			c.Locals("user_id", "7c9e6679-7425-40de-944b-e07fc1f90ae7")
+			return c.Next()
+		})
+		app.Post("/users/:id/ban", handler.BanUser)
+		return app
+	}
+
+	tests := []struct {
+		name       string
+		userID     string
+		body       string
+		wantStatus int
+	}{
+		{"invalid user ID", "123", "", fiber.StatusBadRequest},
+		{"own account", "7c9e6679-7425-40de-944b-e07fc1f90ae7", "", fiber.StatusBadRequest},
+		{"invalid body", userID, "not json", fiber.StatusBadRequest},
+		{"invalid duration", userID, `{"duration":"forever"}`, fiber.StatusBadRequest},
+		{"valid ban reaches the service", userID, `{"duration":"24h","reason":"spam"}`, fiber.StatusInternalServerError},
+		{"ban without a body", userID, "", fiber.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/users/"+tt.userID+"/ban", bytes.NewBufferString(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			resp, err := newApp().Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+		})
+	}
+}
+
+// =============================================================================
+// Admin Action Handler Tests
+// =============================================================================
+
+func TestUserAdminActions_NilService(t *testing.T) {
+	handler := NewUserManagementHandler(nil, nil)
+
+	for path, h := range map[string]fiber.Handler{
+		"/users/:id/unban":                handler.UnbanUser,
+		"/users/:id/verify-email":         handler.VerifyEmail,
+		"/users/:id/force-password-reset": handler.ForcePasswordReset,
+	} {
+		t.Run(path, func(t *testing.T) {
+			app := fiber.New()
+			app.Post(path, h)
+
+			target := strings.Replace(path, ":id", "550e8400-e29b-41d4-a716-446655440000", 1)
+			resp, err := app.Test(httptest.NewRequest(http.MethodPost, target, nil))
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+		})
+	}
+}
@@ -40,38 +40,28 @@ func (h *UserSettingsHandler) CreateSecret(c fiber.Ctx) error {
 	// Get user ID from context
 	userIDStr := c.Locals("user_id")
 	if userIDStr == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 
 	userID, err := uuid.Parse(userIDStr.(string))
 	if err != nil {
 		log.Error().Err(err).Msg("Invalid user ID in context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return SendInternalError(c, "Invalid user ID")
 	}
 
 	var req settings.CreateSecretSettingRequest
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse request body")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validate required fields
 	if req.Key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting key is required",
-		})
+		return SendBadRequest(c, "Setting key is required", ErrCodeMissingField)
 	}
 
 	if req.Value == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting value is required",
-		})
+		return SendBadRequest(c, "Setting value is required", ErrCodeMissingField)
 	}
 
 	// Create user-specific secret with RLS context
@@ -83,21 +73,13 @@ func (h *UserSettingsHandler) CreateSecret(c fiber.Ctx) error {
 	})
 	if err != nil {
 		if errors.Is(err, settings.ErrCustomSettingDuplicate) {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error": "A secret with this key already exists",
-				"code":  "DUPLICATE_KEY",
-			})
+			return SendConflict(c, "A secret with this key already exists", "DUPLICATE_KEY")
 		}
 		if errors.Is(err, settings.ErrCustomSettingInvalidKey) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid setting key format",
-				"code":  "INVALID_KEY",
-			})
+			return SendBadRequest(c, "Invalid setting key format", "INVALID_KEY")
 		}
 		log.Error().Err(err).Str("key", req.Key).Str("user_id", userID.String()).Msg("Failed to create user secret")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create secret",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to create secret", ErrCodeOperationFailed)
 	}
 
 	log.Info().
@@ -115,25 +97,19 @@ func (h *UserSettingsHandler) GetSecret(c fiber.Ctx) error {
 	key := c.Params("*")
 
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting key is required",
-		})
+		return SendBadRequest(c, "Setting key is required", ErrCodeMissingField)
 	}
 
 	// Get user ID from context
 	userIDStr := c.Locals("user_id")
 	if userIDStr == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 
 	userID, err := uuid.Parse(userIDStr.(string))
 	if err != nil {
 		log.Error().Err(err).Msg("Invalid user ID in context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return SendInternalError(c, "Invalid user ID")
 	}
 
 	// Get user's secret with RLS context
@@ -145,14 +121,10 @@ func (h *UserSettingsHandler) GetSecret(c fiber.Ctx) error {
 	})
 	if err != nil {
 		if errors.Is(err, settings.ErrCustomSettingNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Secret not found",
-			})
+			return SendNotFound(c, "Secret not found")
 		}
 		log.Error().Err(err).Str("key", key).Str("user_id", userID.String()).Msg("Failed to get user secret")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve secret",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve secret", ErrCodeOperationFailed)
 	}
 
 	return c.JSON(metadata)
@@ -165,33 +137,25 @@ func (h *UserSettingsHandler) UpdateSecret(c fiber.Ctx) error {
 	key := c.Params("*")
 
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting key is required",
-		})
+		return SendBadRequest(c, "Setting key is required", ErrCodeMissingField)
 	}
 
 	// Get user ID from context
 	userIDStr := c.Locals("user_id")
 	if userIDStr == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 
 	userID, err := uuid.Parse(userIDStr.(string))
 	if err != nil {
 		log.Error().Err(err).Msg("Invalid user ID in context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return SendInternalError(c, "Invalid user ID")
 	}
 
 	var req settings.UpdateSecretSettingRequest
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse request body")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Update user's secret with RLS context
@@ -203,14 +167,10 @@ func (h *UserSettingsHandler) UpdateSecret(c fiber.Ctx) error {
 	})
 	if err != nil {
 		if errors.Is(err, settings.ErrCustomSettingNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Secret not found",
-			})
+			return SendNotFound(c, "Secret not found")
 		}
 		log.Error().Err(err).Str("key", key).Str("user_id", userID.String()).Msg("Failed to update user secret")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update secret",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to update secret", ErrCodeOperationFailed)
 	}
 
 	log.Info().
@@ -228,25 +188,19 @@ func (h *UserSettingsHandler) DeleteSecret(c fiber.Ctx) error {
 	key := c.Params("*")
 
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting key is required",
-		})
+		return SendBadRequest(c, "Setting key is required", ErrCodeMissingField)
 	}
 
 	// Get user ID from context
 	userIDStr := c.Locals("user_id")
 	if userIDStr == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 
 	userID, err := uuid.Parse(userIDStr.(string))
 	if err != nil {
 		log.Error().Err(err).Msg("Invalid user ID in context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return SendInternalError(c, "Invalid user ID")
 	}
 
 	// Delete user's secret with RLS context
@@ -255,14 +209,10 @@ func (h *UserSettingsHandler) DeleteSecret(c fiber.Ctx) error {
 	})
 	if err != nil {
 		if errors.Is(err, settings.ErrCustomSettingNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Secret not found",
-			})
+			return SendNotFound(c, "Secret not found")
 		}
 		log.Error().Err(err).Str("key", key).Str("user_id", userID.String()).Msg("Failed to delete user secret")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete secret",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to delete secret", ErrCodeOperationFailed)
 	}
 
 	log.Info().
@@ -281,17 +231,13 @@ func (h *UserSettingsHandler) ListSecrets(c fiber.Ctx) error {
 	// Get user ID from context
 	userIDStr := c.Locals("user_id")
 	if userIDStr == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 
 	userID, err := uuid.Parse(userIDStr.(string))
 	if err != nil {
 		log.Error().Err(err).Msg("Invalid user ID in context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return SendInternalError(c, "Invalid user ID")
 	}
 
 	// List user's secrets with RLS context
@@ -303,9 +249,7 @@ func (h *UserSettingsHandler) ListSecrets(c fiber.Ctx) error {
 	})
 	if err != nil {
 		log.Error().Err(err).Str("user_id", userID.String()).Msg("Failed to list user secrets")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve secrets",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve secrets", ErrCodeOperationFailed)
 	}
 
 	return c.JSON(secrets)
@@ -320,54 +264,40 @@ func (h *UserSettingsHandler) GetUserSecretValue(c fiber.Ctx) error {
 	// Require service_role for this privileged operation
 	role := c.Locals("user_role")
 	if role != "service_role" {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "This operation requires service_role",
-		})
+		return SendForbidden(c, "This operation requires service_role", ErrCodeAccessDenied)
 	}
 
 	// Check if secrets service is configured
 	if h.secretsService == nil {
 		log.Error().Msg("SecretsService not configured for UserSettingsHandler")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Secrets service not configured",
-		})
+		return SendInternalError(c, "Secrets service not configured")
 	}
 
 	// Parse target user ID from URL
 	targetUserIDStr := c.Params("user_id")
 	targetUserID, err := uuid.Parse(targetUserIDStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid user_id format",
-		})
+		return SendBadRequest(c, "Invalid user_id format", ErrCodeInvalidFormat)
 	}
 
 	// Get secret key from URL
 	key := c.Params("key")
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Secret key is required",
-		})
+		return SendBadRequest(c, "Secret key is required", ErrCodeMissingField)
 	}
 
 	// Retrieve and decrypt the secret (service_role bypasses RLS)
 	value, err := h.secretsService.GetUserSecret(ctx, targetUserID, key)
 	if err != nil {
 		if errors.Is(err, settings.ErrSecretNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Secret not found",
-			})
+			return SendNotFound(c, "Secret not found")
 		}
 		if errors.Is(err, settings.ErrDecryptionFailed) {
 			log.Error().Err(err).Str("key", key).Str("user_id", targetUserID.String()).Msg("Failed to decrypt user secret")
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to decrypt secret",
-			})
+			return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to decrypt secret", ErrCodeOperationFailed)
 		}
 		log.Error().Err(err).Str("key", key).Str("user_id", targetUserID.String()).Msg("Failed to retrieve user secret")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve secret",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve secret", ErrCodeOperationFailed)
 	}
 
 	log.Debug().
@@ -392,25 +322,19 @@ func (h *UserSettingsHandler) GetSetting(c fiber.Ctx) error {
 	key := c.Params("key")
 
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting key is required",
-		})
+		return SendBadRequest(c, "Setting key is required", ErrCodeMissingField)
 	}
 
 	// Get user ID from context
 	userIDStr := c.Locals("user_id")
 	if userIDStr == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 
 	userID, err := uuid.Parse(userIDStr.(string))
 	if err != nil {
 		log.Error().Err(err).Msg("Invalid user ID in context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return SendInternalError(c, "Invalid user ID")
 	}
 
 	// Get setting with fallback using RLS context
@@ -422,14 +346,10 @@ func (h *UserSettingsHandler) GetSetting(c fiber.Ctx) error {
 	})
 	if err != nil {
 		if errors.Is(err, settings.ErrCustomSettingNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Setting not found",
-			})
+			return SendNotFound(c, "Setting not found")
 		}
 		log.Error().Err(err).Str("key", key).Str("user_id", userID.String()).Msg("Failed to get setting with fallback")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve setting",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve setting", ErrCodeOperationFailed)
 	}
 
 	return c.JSON(result)
@@ -442,25 +362,19 @@ func (h *UserSettingsHandler) GetUserOwnSetting(c fiber.Ctx) error {
 	key := c.Params("key")
 
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting key is required",
-		})
+		return SendBadRequest(c, "Setting key is required", ErrCodeMissingField)
 	}
 
 	// Get user ID from context
 	userIDStr := c.Locals("user_id")
 	if userIDStr == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 
 	userID, err := uuid.Parse(userIDStr.(string))
 	if err != nil {
 		log.Error().Err(err).Msg("Invalid user ID in context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return SendInternalError(c, "Invalid user ID")
 	}
 
 	// Get user's own setting with RLS context
@@ -472,14 +386,10 @@ func (h *UserSettingsHandler) GetUserOwnSetting(c fiber.Ctx) error {
 	})
 	if err != nil {
 		if errors.Is(err, settings.ErrCustomSettingNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Setting not found",
-			})
+			return SendNotFound(c, "Setting not found")
 		}
 		log.Error().Err(err).Str("key", key).Str("user_id", userID.String()).Msg("Failed to get user setting")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve setting",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve setting", ErrCodeOperationFailed)
 	}
 
 	return c.JSON(setting)
@@ -492,9 +402,7 @@ func (h *UserSettingsHandler) GetSystemSettingPublic(c fiber.Ctx) error {
 	key := c.Params("key")
 
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting key is required",
-		})
+		return SendBadRequest(c, "Setting key is required", ErrCodeMissingField)
 	}
 
 	// Get system setting with RLS context
@@ -506,14 +414,10 @@ func (h *UserSettingsHandler) GetSystemSettingPublic(c fiber.Ctx) error {
 	})
 	if err != nil {
 		if errors.Is(err, settings.ErrCustomSettingNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Setting not found",
-			})
+			return SendNotFound(c, "Setting not found")
 		}
 		log.Error().Err(err).Str("key", key).Msg("Failed to get system setting")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve setting",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve setting", ErrCodeOperationFailed)
 	}
 
 	// Return only the value (not all metadata) for public access
@@ -530,33 +434,25 @@ func (h *UserSettingsHandler) SetSetting(c fiber.Ctx) error {
 	key := c.Params("key")
 
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting key is required",
-		})
+		return SendBadRequest(c, "Setting key is required", ErrCodeMissingField)
 	}
 
 	// Get user ID from context
 	userIDStr := c.Locals("user_id")
 	if userIDStr == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 
 	userID, err := uuid.Parse(userIDStr.(string))
 	if err != nil {
 		log.Error().Err(err).Msg("Invalid user ID in context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return SendInternalError(c, "Invalid user ID")
 	}
 
 	var req settings.CreateUserSettingRequest
 	if err := c.Bind().Body(&req); err != nil {
 		log.Error().Err(err).Msg("Failed to parse request body")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Use key from URL
@@ -564,9 +460,7 @@ func (h *UserSettingsHandler) SetSetting(c fiber.Ctx) error {
 
 	// Validate required fields
 	if req.Value == nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting value is required",
-		})
+		return SendBadRequest(c, "Setting value is required", ErrCodeMissingField)
 	}
 
 	// Upsert the setting with RLS context
@@ -578,15 +472,10 @@ func (h *UserSettingsHandler) SetSetting(c fiber.Ctx) error {
 	})
 	if err != nil {
 		if errors.Is(err, settings.ErrCustomSettingInvalidKey) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid setting key format",
-				"code":  "INVALID_KEY",
-			})
+			return SendBadRequest(c, "Invalid setting key format", "INVALID_KEY")
 		}
 		log.Error().Err(err).Str("key", key).Str("user_id", userID.String()).Msg("Failed to set user setting")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to save setting",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to save setting", ErrCodeOperationFailed)
 	}
 
 	log.Info().
@@ -604,25 +493,19 @@ func (h *UserSettingsHandler) DeleteSetting(c fiber.Ctx) error {
 	key := c.Params("key")
 
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Setting key is required",
-		})
+		return SendBadRequest(c, "Setting key is required", ErrCodeMissingField)
 	}
 
 	// Get user ID from context
 	userIDStr := c.Locals("user_id")
 	if userIDStr == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 
 	userID, err := uuid.Parse(userIDStr.(string))
 	if err != nil {
 		log.Error().Err(err).Msg("Invalid user ID in context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return SendInternalError(c, "Invalid user ID")
 	}
 
 	// Delete user's setting with RLS context
@@ -631,14 +514,10 @@ func (h *UserSettingsHandler) DeleteSetting(c fiber.Ctx) error {
 	})
 	if err != nil {
 		if errors.Is(err, settings.ErrCustomSettingNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Setting not found",
-			})
+			return SendNotFound(c, "Setting not found")
 		}
 		log.Error().Err(err).Str("key", key).Str("user_id", userID.String()).Msg("Failed to delete user setting")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete setting",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to delete setting", ErrCodeOperationFailed)
 	}
 
 	log.Info().
@@ -657,17 +536,13 @@ func (h *UserSettingsHandler) ListSettings(c fiber.Ctx) error {
 	// Get user ID from context
 	userIDStr := c.Locals("user_id")
 	if userIDStr == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required",
-		})
+		return SendUnauthorized(c, "Authentication required", ErrCodeAuthRequired)
 	}
 
 	userID, err := uuid.Parse(userIDStr.(string))
 	if err != nil {
 		log.Error().Err(err).Msg("Invalid user ID in context")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return SendInternalError(c, "Invalid user ID")
 	}
 
 	// List user's settings with RLS context
@@ -679,9 +554,7 @@ func (h *UserSettingsHandler) ListSettings(c fiber.Ctx) error {
 	})
 	if err != nil {
 		log.Error().Err(err).Str("user_id", userID.String()).Msg("Failed to list user settings")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve settings",
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to retrieve settings", ErrCodeOperationFailed)
 	}
 
 	// Return empty array instead of null
@@ -11,6 +11,7 @@ import (
 	"github.com/nimbleflux/fluxbase/internal/config"
 	"github.com/nimbleflux/fluxbase/internal/database"
 	"github.com/nimbleflux/fluxbase/internal/middleware"
+	"github.com/nimbleflux/fluxbase/internal/query"
 	"github.com/rs/zerolog/log"
 )
 
@@ -242,9 +243,7 @@ type VectorSearchResponse struct {
 func (h *VectorHandler) HandleEmbed(c fiber.Ctx) error {
 	var req EmbedRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Determine which embedding service to use
@@ -257,17 +256,13 @@ func (h *VectorHandler) HandleEmbed(c fiber.Ctx) error {
 		isAdmin := role == "admin" || role == "dashboard_admin" || role == "service_role"
 
 		if !isAdmin {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Provider selection requires admin privileges",
-			})
+			return SendForbidden(c, "Provider selection requires admin privileges", ErrCodeAdminRequired)
 		}
 
 		// Get embedding service for specified provider
 		embeddingService, err = h.vectorManager.GetEmbeddingServiceForProvider(c.RequestCtx(), req.Provider)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": err.Error(),
-			})
+			return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 		}
 	} else {
 		// Use default embedding service
@@ -275,9 +270,7 @@ func (h *VectorHandler) HandleEmbed(c fiber.Ctx) error {
 	}
 
 	if embeddingService == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Embedding service not configured",
-		})
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "Embedding service not configured", ErrCodeServiceUnavailable)
 	}
 
 	// Collect texts to embed
@@ -288,18 +281,14 @@ func (h *VectorHandler) HandleEmbed(c fiber.Ctx) error {
 	texts = append(texts, req.Texts...)
 
 	if len(texts) == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "No text provided for embedding",
-		})
+		return SendBadRequest(c, "No text provided for embedding", ErrCodeInvalidInput)
 	}
 
 	// Generate embeddings
 	resp, err := embeddingService.Embed(c.RequestCtx(), texts, req.Model)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to generate embeddings")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to generate embeddings: " + err.Error(),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to generate embeddings: "+err.Error(), ErrCodeOperationFailed)
 	}
 
 	result := EmbedResponse{
@@ -322,29 +311,28 @@ func (h *VectorHandler) HandleEmbed(c fiber.Ctx) error {
 // This is a convenience endpoint that auto-embeds query text and performs vector similarity search
 func (h *VectorHandler) HandleSearch(c fiber.Ctx) error {
 	if h.db == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Database not configured",
-		})
+		return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "Database not configured", ErrCodeServiceUnavailable)
 	}
 
 	var req VectorSearchRequest
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	if req.Table == "" || req.Column == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "table and column are required",
-		})
+		return SendBadRequest(c, "table and column are required", ErrCodeMissingField)
 	}
 
 	// Validate table and column names (prevent SQL injection)
 	if !isValidIdentifier(req.Table) || !isValidIdentifier(req.Column) {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid table or column name",
-		})
+		return SendBadRequest(c, "Invalid table or column name", ErrCodeInvalidInput)
+	}
+
+	// The search runs against the unqualified table name, so restrictions are checked in public
+	if restrictions, ok := c.Locals("client_key_restrictions").(auth.ClientKeyRestrictions); ok {
+		if !restrictions.AllowsTable("public", req.Table, false) {
+			return SendForbidden(c, fmt.Sprintf("Client key is not allowed to read table 'public.%s'", req.Table), ErrCodeAccessDenied)
+		}
 	}
 
 	// Determine the query vector
@@ -356,17 +344,13 @@ func (h *VectorHandler) HandleSearch(c fiber.Ctx) error {
 		// Auto-embed the query text
 		embeddingService := h.vectorManager.GetEmbeddingService()
 		if embeddingService == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-				"error": "Embedding service not configured; provide vector directly",
-			})
+			return SendErrorWithCode(c, fiber.StatusServiceUnavailable, "Embedding service not configured; provide vector directly", ErrCodeServiceUnavailable)
 		}
 
 		embedding, err := embeddingService.EmbedSingle(c.RequestCtx(), req.Query, "")
 		if err != nil {
 			log.Error().Err(err).Msg("Failed to embed query")
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to embed query: " + err.Error(),
-			})
+			return SendErrorWithCode(c, fiber.StatusInternalServerError, "Failed to embed query: "+err.Error(), ErrCodeOperationFailed)
 		}
 
 		// Convert float32 to float64
@@ -378,9 +362,7 @@ func (h *VectorHandler) HandleSearch(c fiber.Ctx) error {
 	} else if len(req.Vector) > 0 {
 		queryVector = req.Vector
 	} else {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Either query or vector must be provided",
-		})
+		return SendBadRequest(c, "Either query or vector must be provided", ErrCodeInvalidInput)
 	}
 
 	// Validate and normalize metric
@@ -398,9 +380,7 @@ func (h *VectorHandler) HandleSearch(c fiber.Ctx) error {
 	case "inner_product", "ip":
 		distanceOp = "<#>"
 	default:
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid metric; use l2, cosine, or inner_product",
-		})
+		return SendBadRequest(c, "Invalid metric; use l2, cosine, or inner_product", ErrCodeInvalidInput)
 	}
 
 	// Build select columns
@@ -412,7 +392,7 @@ func (h *VectorHandler) HandleSearch(c fiber.Ctx) error {
 		for _, col := range cols {
 			col = strings.TrimSpace(col)
 			if isValidIdentifier(col) {
-				validCols = append(validCols, col)
+				validCols = append(validCols, query.QuoteIdentifier(col))
 			}
 		}
 		if len(validCols) > 0 {
@@ -473,9 +453,7 @@ func (h *VectorHandler) HandleSearch(c fiber.Ctx) error {
 			Str("table", req.Table).
 			Str("column", req.Column).
 			Msg("Vector search failed")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Vector search failed: " + err.Error(),
-		})
+		return SendErrorWithCode(c, fiber.StatusInternalServerError, "Vector search failed: "+err.Error(), ErrCodeOperationFailed)
 	}
 
 	result := VectorSearchResponse{
@@ -511,44 +489,7 @@ type vectorSearchParams struct {
 
 // executeVectorSearch executes the vector similarity search with RLS context
 func (h *VectorHandler) executeVectorSearch(ctx context.Context, params vectorSearchParams) ([]map[string]interface{}, []float64, error) {
-	// Format the vector as PostgreSQL array literal
-	vectorStr := formatVectorLiteral(params.queryVector)
-
-	// Build the base query
-	// Using subquery to calculate distance once
-	query := fmt.Sprintf(`
-		SELECT %s, (%s %s '%s'::vector) as _distance
-		FROM %s
-		WHERE 1=1
-	`, params.selectCols, params.column, params.distanceOp, vectorStr, params.table)
-
-	// Add threshold filter if specified
-	if params.matchThreshold != nil {
-		query += fmt.Sprintf(" AND (%s %s '%s'::vector) < %f",
-			params.column, params.distanceOp, vectorStr, *params.matchThreshold)
-	}
-
-	// Add custom filters
-	for i, filter := range params.filters {
-		if !isValidIdentifier(filter.Column) {
-			continue
-		}
-		op := normalizeOperator(filter.Operator)
-		if op == "" {
-			continue
-		}
-		// Use parameter placeholder to prevent injection
-		query += fmt.Sprintf(" AND %s %s $%d", filter.Column, op, i+1)
-	}
-
-	// Add ordering and limit
-	query += fmt.Sprintf(" ORDER BY _distance LIMIT %d", params.matchCount)
-
-	// Collect filter values for parameterized query
-	filterValues := make([]interface{}, len(params.filters))
-	for i, filter := range params.filters {
-		filterValues[i] = filter.Value
-	}
+	query, args := buildVectorSearchQuery(params)
 
 	// Execute with RLS context
 	tx, err := h.db.Pool().Begin(ctx)
@@ -563,7 +504,7 @@ func (h *VectorHandler) executeVectorSearch(ctx context.Context, params vectorSe
 	}
 
 	// Execute query
-	rows, err := tx.Query(ctx, query, filterValues...)
+	rows, err := tx.Query(ctx, query, args...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("query failed: %w", err)
 	}
@@ -609,6 +550,44 @@ func (h *VectorHandler) executeVectorSearch(ctx context.Context, params vectorSe
 	return data, distances, nil
 }
 
+// buildVectorSearchQuery builds the similarity search SQL. The query vector, threshold
+// and filter values are bound as parameters; table and column names are validated
+// identifiers and are quoted.
+func buildVectorSearchQuery(params vectorSearchParams) (string, []interface{}) {
+	argIndex := 1
+	vectorParam := query.Placeholder(&argIndex) + "::vector"
+	args := []interface{}{formatVectorLiteral(params.queryVector)}
+
+	column := query.QuoteIdentifier(params.column)
+	distanceExpr := fmt.Sprintf("(%s %s %s)", column, params.distanceOp, vectorParam)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "SELECT %s, %s as _distance FROM %s WHERE 1=1",
+		params.selectCols, distanceExpr, query.QuoteIdentifier(params.table))
+
+	// Add threshold filter if specified
+	if params.matchThreshold != nil {
+		fmt.Fprintf(&sb, " AND %s < %s", distanceExpr, query.Placeholder(&argIndex))
+		args = append(args, *params.matchThreshold)
+	}
+
+	// Add custom filters
+	for _, filter := range params.filters {
+		if !isValidIdentifier(filter.Column) {
+			continue
+		}
+		op := normalizeOperator(filter.Operator)
+		if op == "" {
+			continue
+		}
+		fmt.Fprintf(&sb, " AND %s %s %s", query.QuoteIdentifier(filter.Column), op, query.Placeholder(&argIndex))
+		args = append(args, filter.Value)
+	}
+
+	fmt.Fprintf(&sb, " ORDER BY _distance LIMIT %d", params.matchCount)
+	return sb.String(), args
+}
+
 // formatVectorLiteral formats a float64 slice as PostgreSQL vector literal
 func formatVectorLiteral(v []float64) string {
 	parts := make([]string, len(v))
@@ -5,10 +5,13 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/auth"
 	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/database"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -480,6 +483,28 @@ func TestHandleSearch_Validation(t *testing.T) {
 		assert.Empty(t, req.Query)
 		assert.Empty(t, req.Vector)
 	})
+
+	t.Run("client key not allowed to read table", func(t *testing.T) {
+		handler := &VectorHandler{db: &database.Connection{}}
+		app := fiber.New()
+		app.Post("/search", func(c fiber.Ctx) error {
+			c.Locals("client_key_restrictions", auth.ClientKeyRestrictions{
+				AllowedTables: map[string]string{"public.posts": auth.TableAccessRead},
+			})
+			return handler.HandleSearch(c)
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/search", strings.NewReader(`{"table":"documents","column":"embedding","vector":[0.1,0.2]}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "not allowed to read table 'public.documents'")
+	})
 }
 
 // =============================================================================
@@ -835,3 +860,26 @@ func TestInferProviderType(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildVectorSearchQuery(t *testing.T) {
+	threshold := 0.5
+	query, args := buildVectorSearchQuery(vectorSearchParams{
+		table:          "documents",
+		column:         "embedding",
+		selectCols:     `"id", "title"`,
+		queryVector:    []float64{0.1, 0.2},
+		distanceOp:     "<=>",
+		matchThreshold: &threshold,
+		matchCount:     5,
+		filters: []VectorQueryFilter{
+			{Column: "bad column", Operator: "eq", Value: "skipped"},
+			{Column: "category", Operator: "eq", Value: "news"},
+		},
+	})
+
+	assert.Equal(t,
+		`SELECT "id", "title", ("embedding" <=> $1::vector) as _distance FROM "documents" WHERE 1=1`+
+			` AND ("embedding" <=> $1::vector) < $2 AND "category" = $3 ORDER BY _distance LIMIT 5`,
+		query)
+	assert.Equal(t, []interface{}{"[0.1,0.2]", 0.5, "news"}, args)
+}
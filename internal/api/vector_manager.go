@@ -6,8 +6,10 @@ import (
 	"sync"
 
 	"github.com/nimbleflux/fluxbase/internal/ai"
+	"github.com/nimbleflux/fluxbase/internal/cache"
 	"github.com/nimbleflux/fluxbase/internal/config"
 	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/nimbleflux/fluxbase/internal/observability"
 	"github.com/rs/zerolog/log"
 )
 
@@ -20,6 +22,8 @@ type VectorManager struct {
 	envConfig        *config.AIConfig
 	schemaInspector  *database.SchemaInspector
 	db               *database.Connection
+	sharedCache      cache.Cache            // optional, shared with every embedding service
+	metrics          *observability.Metrics // optional, set on every embedding service
 }
 
 // NewVectorManager creates a new vector manager with hot-reload capability
@@ -53,6 +57,30 @@ func (m *VectorManager) GetEmbeddingService() *ai.EmbeddingService {
 	return m.embeddingService
 }
 
+// SetCache sets the shared cache embedding services store results in, including services
+// created by later refreshes
+func (m *VectorManager) SetCache(c cache.Cache) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sharedCache = c
+	if m.embeddingService != nil {
+		m.embeddingService.SetCache(c)
+	}
+}
+
+// SetMetrics sets the metrics embedding services record provider requests and token usage in,
+// including services created by later refreshes
+func (m *VectorManager) SetMetrics(metrics *observability.Metrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.metrics = metrics
+	if m.embeddingService != nil {
+		m.embeddingService.SetMetrics(metrics)
+	}
+}
+
 // GetEmbeddingServiceForProvider creates an embedding service for a specific provider by ID
 // This is used when admins want to use a different provider than the default
 func (m *VectorManager) GetEmbeddingServiceForProvider(ctx context.Context, providerID string) (*ai.EmbeddingService, error) {
@@ -79,6 +107,11 @@ func (m *VectorManager) GetEmbeddingServiceForProvider(ctx context.Context, prov
 		return nil, fmt.Errorf("failed to create embedding service for provider %s: %w", providerID, err)
 	}
 
+	m.mu.RLock()
+	service.SetCache(m.sharedCache)
+	service.SetMetrics(m.metrics)
+	m.mu.RUnlock()
+
 	return service, nil
 }
 
@@ -221,6 +254,8 @@ func (m *VectorManager) RefreshFromDatabase(ctx context.Context) error {
 
 	// Atomically swap the service
 	m.mu.Lock()
+	service.SetCache(m.sharedCache)
+	service.SetMetrics(m.metrics)
 	m.embeddingService = service
 	m.mu.Unlock()
 
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
@@ -14,39 +16,43 @@ import (
 // WebhookResponse represents a webhook response without the secret
 // H-21: WebhookResponse DTO excludes secret field for security
 type WebhookResponse struct {
-	ID                  uuid.UUID             `json:"id"`
-	Name                string                `json:"name"`
-	Description         *string               `json:"description,omitempty"`
-	URL                 string                `json:"url"`
-	Enabled             bool                  `json:"enabled"`
-	Events              []webhook.EventConfig `json:"events"`
-	MaxRetries          int                   `json:"max_retries"`
-	RetryBackoffSeconds int                   `json:"retry_backoff_seconds"`
-	TimeoutSeconds      int                   `json:"timeout_seconds"`
-	Headers             map[string]string     `json:"headers"`
-	Scope               string                `json:"scope"` // "user" or "global"
-	CreatedBy           *uuid.UUID            `json:"created_by,omitempty"`
-	CreatedAt           time.Time             `json:"created_at"`
-	UpdatedAt           time.Time             `json:"updated_at"`
+	ID                     uuid.UUID             `json:"id"`
+	Name                   string                `json:"name"`
+	Description            *string               `json:"description,omitempty"`
+	URL                    string                `json:"url"`
+	Enabled                bool                  `json:"enabled"`
+	Events                 []webhook.EventConfig `json:"events"`
+	MaxRetries             int                   `json:"max_retries"`
+	RetryBackoffSeconds    int                   `json:"retry_backoff_seconds"`
+	RetryBackoffStrategy   string                `json:"retry_backoff_strategy"`
+	MaxRetryBackoffSeconds int                   `json:"max_retry_backoff_seconds"`
+	TimeoutSeconds         int                   `json:"timeout_seconds"`
+	Headers                map[string]string     `json:"headers"`
+	Scope                  string                `json:"scope"` // "user" or "global"
+	CreatedBy              *uuid.UUID            `json:"created_by,omitempty"`
+	CreatedAt              time.Time             `json:"created_at"`
+	UpdatedAt              time.Time             `json:"updated_at"`
 }
 
 // toWebhookResponse converts a webhook.Webhook to WebhookResponse (without secret)
 func toWebhookResponse(w webhook.Webhook) WebhookResponse {
 	return WebhookResponse{
-		ID:                  w.ID,
-		Name:                w.Name,
-		Description:         w.Description,
-		URL:                 w.URL,
-		Enabled:             w.Enabled,
-		Events:              w.Events,
-		MaxRetries:          w.MaxRetries,
-		RetryBackoffSeconds: w.RetryBackoffSeconds,
-		TimeoutSeconds:      w.TimeoutSeconds,
-		Headers:             w.Headers,
-		Scope:               w.Scope,
-		CreatedBy:           w.CreatedBy,
-		CreatedAt:           w.CreatedAt,
-		UpdatedAt:           w.UpdatedAt,
+		ID:                     w.ID,
+		Name:                   w.Name,
+		Description:            w.Description,
+		URL:                    w.URL,
+		Enabled:                w.Enabled,
+		Events:                 w.Events,
+		MaxRetries:             w.MaxRetries,
+		RetryBackoffSeconds:    w.RetryBackoffSeconds,
+		RetryBackoffStrategy:   w.RetryBackoffStrategy,
+		MaxRetryBackoffSeconds: w.MaxRetryBackoffSeconds,
+		TimeoutSeconds:         w.TimeoutSeconds,
+		Headers:                w.Headers,
+		Scope:                  w.Scope,
+		CreatedBy:              w.CreatedBy,
+		CreatedAt:              w.CreatedAt,
+		UpdatedAt:              w.UpdatedAt,
 	}
 }
 
@@ -69,6 +75,12 @@ func (h *WebhookHandler) RegisterRoutes(app *fiber.App, authService *auth.Servic
 		middleware.RequireAuthOrServiceKey(authService, clientKeyService, db, jwtManager),
 	)
 
+	// Delivery history across webhooks is registered before /:id so "deliveries" isn't taken as an ID
+	webhooks.Get("/deliveries", middleware.RequireScope(auth.ScopeWebhooksRead), h.SearchDeliveries)
+	webhooks.Get("/deliveries/:delivery_id", middleware.RequireScope(auth.ScopeWebhooksRead), h.GetDelivery)
+	webhooks.Post("/deliveries/replay", middleware.RequireScope(auth.ScopeWebhooksWrite), h.ReplayDeliveries)
+	webhooks.Post("/deliveries/:delivery_id/replay", middleware.RequireScope(auth.ScopeWebhooksWrite), h.ReplayDelivery)
+
 	// Read operations require read:webhooks scope
 	webhooks.Get("/", middleware.RequireScope(auth.ScopeWebhooksRead), h.ListWebhooks)
 	webhooks.Get("/:id", middleware.RequireScope(auth.ScopeWebhooksRead), h.GetWebhook)
@@ -85,21 +97,15 @@ func (h *WebhookHandler) RegisterRoutes(app *fiber.App, authService *auth.Servic
 func (h *WebhookHandler) CreateWebhook(c fiber.Ctx) error {
 	var req webhook.Webhook
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
 	}
 
 	// Validation
 	if req.Name == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Name is required",
-		})
+		return SendBadRequest(c, "Name is required", ErrCodeMissingField)
 	}
 	if req.URL == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "URL is required",
-		})
+		return SendBadRequest(c, "URL is required", ErrCodeMissingField)
 	}
 
 	// Set defaults
@@ -118,6 +124,9 @@ func (h *WebhookHandler) CreateWebhook(c fiber.Ctx) error {
 	if req.Scope == "" {
 		req.Scope = "user"
 	}
+	if err := webhook.ValidateRetryPolicy(&req); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
 
 	// Set CreatedBy from authenticated user
 	if uid := c.Locals("user_id"); uid != nil {
@@ -130,9 +139,7 @@ func (h *WebhookHandler) CreateWebhook(c fiber.Ctx) error {
 
 	err := h.webhookService.Create(c.RequestCtx(), &req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendInternalError(c, err.Error())
 	}
 
 	// H-21: Return WebhookResponse (without secret)
@@ -143,9 +150,7 @@ func (h *WebhookHandler) CreateWebhook(c fiber.Ctx) error {
 func (h *WebhookHandler) ListWebhooks(c fiber.Ctx) error {
 	webhooks, err := h.webhookService.List(c.RequestCtx())
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendInternalError(c, err.Error())
 	}
 
 	// H-21: Convert to WebhookResponse (without secret)
@@ -161,16 +166,12 @@ func (h *WebhookHandler) ListWebhooks(c fiber.Ctx) error {
 func (h *WebhookHandler) GetWebhook(c fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid webhook ID",
-		})
+		return SendBadRequest(c, "Invalid webhook ID", ErrCodeInvalidID)
 	}
 
 	wh, err := h.webhookService.Get(c.RequestCtx(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Webhook not found",
-		})
+		return SendNotFound(c, "Webhook not found")
 	}
 
 	// H-21: Return WebhookResponse (without secret)
@@ -181,23 +182,20 @@ func (h *WebhookHandler) GetWebhook(c fiber.Ctx) error {
 func (h *WebhookHandler) UpdateWebhook(c fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid webhook ID",
-		})
+		return SendBadRequest(c, "Invalid webhook ID", ErrCodeInvalidID)
 	}
 
 	var req webhook.Webhook
 	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return SendInvalidBody(c)
+	}
+	if err := webhook.ValidateRetryPolicy(&req); err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
 	}
 
 	err = h.webhookService.Update(c.RequestCtx(), id, &req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendInternalError(c, err.Error())
 	}
 
 	return c.JSON(fiber.Map{
@@ -209,16 +207,12 @@ func (h *WebhookHandler) UpdateWebhook(c fiber.Ctx) error {
 func (h *WebhookHandler) DeleteWebhook(c fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid webhook ID",
-		})
+		return SendBadRequest(c, "Invalid webhook ID", ErrCodeInvalidID)
 	}
 
 	err = h.webhookService.Delete(c.RequestCtx(), id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendInternalError(c, err.Error())
 	}
 
 	return c.JSON(fiber.Map{
@@ -230,16 +224,12 @@ func (h *WebhookHandler) DeleteWebhook(c fiber.Ctx) error {
 func (h *WebhookHandler) TestWebhook(c fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid webhook ID",
-		})
+		return SendBadRequest(c, "Invalid webhook ID", ErrCodeInvalidID)
 	}
 
 	wh, err := h.webhookService.Get(c.RequestCtx(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Webhook not found",
-		})
+		return SendNotFound(c, "Webhook not found")
 	}
 
 	// Create test payload
@@ -253,9 +243,7 @@ func (h *WebhookHandler) TestWebhook(c fiber.Ctx) error {
 
 	err = h.webhookService.Deliver(c.RequestCtx(), wh, testPayload)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendInternalError(c, err.Error())
 	}
 
 	return c.JSON(fiber.Map{
@@ -267,9 +255,7 @@ func (h *WebhookHandler) TestWebhook(c fiber.Ctx) error {
 func (h *WebhookHandler) ListDeliveries(c fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid webhook ID",
-		})
+		return SendBadRequest(c, "Invalid webhook ID", ErrCodeInvalidID)
 	}
 
 	// Default limit is 50
@@ -281,12 +267,168 @@ func (h *WebhookHandler) ListDeliveries(c fiber.Ctx) error {
 
 	deliveries, err := h.webhookService.ListDeliveries(c.RequestCtx(), id, limit)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return SendInternalError(c, err.Error())
 	}
 
 	return c.JSON(deliveries)
 }
 
+// maxDeliveryReplays bounds how many deliveries a single range replay sends
+const maxDeliveryReplays = 100
+
+// deliveryFilterFromQuery parses the filter of the delivery history. Only failed deliveries are
+// listed unless status is given ("all" lists every status).
+func deliveryFilterFromQuery(c fiber.Ctx) (webhook.DeliveryFilter, error) {
+	filter := webhook.DeliveryFilter{
+		Status: webhook.DeliveryFailed,
+		Limit:  fiber.Query[int](c, "limit", 50),
+	}
+	if filter.Limit <= 0 || filter.Limit > 500 {
+		return filter, fmt.Errorf("limit must be between 1 and 500")
+	}
+
+	switch status := c.Query("status"); status {
+	case "":
+	case "all":
+		filter.Status = ""
+	case webhook.DeliveryPending, webhook.DeliverySuccess, webhook.DeliveryFailed:
+		filter.Status = status
+	default:
+		return filter, fmt.Errorf("status must be pending, success, failed or all")
+	}
+
+	if raw := c.Query("webhook_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid webhook_id")
+		}
+		filter.WebhookID = &id
+	}
+	for name, target := range map[string]**time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if raw := c.Query(name); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return filter, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+			}
+			*target = &t
+		}
+	}
+
+	return filter, nil
+}
+
+// SearchDeliveries lists deliveries across webhooks, failed ones by default, with their payloads
+// and the responses of the endpoints
+func (h *WebhookHandler) SearchDeliveries(c fiber.Ctx) error {
+	filter, err := deliveryFilterFromQuery(c)
+	if err != nil {
+		return SendBadRequest(c, err.Error(), ErrCodeInvalidInput)
+	}
+
+	deliveries, err := h.webhookService.SearchDeliveries(c.RequestCtx(), filter)
+	if err != nil {
+		return SendInternalError(c, err.Error())
+	}
+
+	return c.JSON(deliveries)
+}
+
+// GetDelivery retrieves a delivery by ID
+func (h *WebhookHandler) GetDelivery(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("delivery_id"))
+	if err != nil {
+		return SendBadRequest(c, "Invalid delivery ID", ErrCodeInvalidID)
+	}
+
+	delivery, err := h.webhookService.GetDelivery(c.RequestCtx(), id)
+	if errors.Is(err, webhook.ErrDeliveryNotFound) {
+		return SendNotFound(c, "Delivery not found")
+	}
+	if err != nil {
+		return SendInternalError(c, err.Error())
+	}
+
+	return c.JSON(delivery)
+}
+
+// ReplayDelivery sends a delivery's payload to its webhook again and returns the new delivery
+func (h *WebhookHandler) ReplayDelivery(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("delivery_id"))
+	if err != nil {
+		return SendBadRequest(c, "Invalid delivery ID", ErrCodeInvalidID)
+	}
+
+	replay, err := h.webhookService.ReplayDelivery(c.RequestCtx(), id)
+	if errors.Is(err, webhook.ErrDeliveryNotFound) {
+		return SendNotFound(c, "Delivery not found")
+	}
+	if err != nil {
+		return SendInternalError(c, err.Error())
+	}
+
+	return c.JSON(replay)
+}
+
+// ReplayDeliveriesRequest selects the deliveries to replay: either explicit IDs, or the deliveries
+// created in a time range (failed ones unless status is given), oldest first
+type ReplayDeliveriesRequest struct {
+	IDs       []uuid.UUID `json:"ids,omitempty"`
+	WebhookID *uuid.UUID  `json:"webhook_id,omitempty"`
+	Status    string      `json:"status,omitempty"`
+	Since     *time.Time  `json:"since,omitempty"`
+	Until     *time.Time  `json:"until,omitempty"`
+}
+
+// ReplayDeliveries replays up to 100 deliveries, one after another
+func (h *WebhookHandler) ReplayDeliveries(c fiber.Ctx) error {
+	var req ReplayDeliveriesRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return SendInvalidBody(c)
+	}
+
+	ids := req.IDs
+	switch {
+	case len(ids) > maxDeliveryReplays:
+		return SendBadRequest(c, fmt.Sprintf("At most %d deliveries can be replayed at once", maxDeliveryReplays), ErrCodeInvalidInput)
+	case len(ids) == 0 && req.Since == nil:
+		return SendBadRequest(c, "Either ids or since is required", ErrCodeMissingField)
+	case req.Status != "" && req.Status != webhook.DeliveryPending && req.Status != webhook.DeliverySuccess && req.Status != webhook.DeliveryFailed:
+		return SendBadRequest(c, "status must be pending, success or failed", ErrCodeInvalidInput)
+	case len(ids) == 0:
+		status := req.Status
+		if status == "" {
+			status = webhook.DeliveryFailed
+		}
+		deliveries, err := h.webhookService.SearchDeliveries(c.RequestCtx(), webhook.DeliveryFilter{
+			WebhookID: req.WebhookID,
+			Status:    status,
+			Since:     req.Since,
+			Until:     req.Until,
+			Limit:     maxDeliveryReplays + 1,
+		})
+		if err != nil {
+			return SendInternalError(c, err.Error())
+		}
+		if len(deliveries) > maxDeliveryReplays {
+			return SendBadRequest(c, fmt.Sprintf("More than %d deliveries match; narrow the range", maxDeliveryReplays), ErrCodeInvalidInput)
+		}
+		// Replay in the order the events originally happened
+		for i := len(deliveries) - 1; i >= 0; i-- {
+			ids = append(ids, deliveries[i].ID)
+		}
+	}
+
+	replays, err := h.webhookService.ReplayDeliveries(c.RequestCtx(), ids)
+	if err != nil {
+		return SendErrorWithDetails(c, fiber.StatusInternalServerError, err.Error(), ErrCodeInternalError, "", "", fiber.Map{
+			"replayed": replays,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"replayed": replays,
+		"count":    len(replays),
+	})
+}
+
 // fiber:context-methods migrated
@@ -68,6 +68,18 @@ func TestCreateWebhook_Validation(t *testing.T) {
 			expectedStatus: fiber.StatusBadRequest,
 			expectedError:  "URL is required",
 		},
+		{
+			name:           "unknown retry backoff strategy",
+			body:           map[string]interface{}{"name": "test", "url": "https://example.com", "retry_backoff_strategy": "random"},
+			expectedStatus: fiber.StatusBadRequest,
+			expectedError:  "retry_backoff_strategy must be one of",
+		},
+		{
+			name:           "negative max retry backoff",
+			body:           map[string]interface{}{"name": "test", "url": "https://example.com", "max_retry_backoff_seconds": -1},
+			expectedStatus: fiber.StatusBadRequest,
+			expectedError:  "max_retry_backoff_seconds must not be negative",
+		},
 	}
 
 	for _, tt := range tests {
@@ -384,6 +396,77 @@ func TestListDeliveries_InvalidID(t *testing.T) {
 	assert.Contains(t, result["error"], "Invalid webhook ID")
 }
 
+// =============================================================================
+// Delivery History and Replay Tests
+// =============================================================================
+
+func TestDeliveryHistory_Validation(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		body          string
+		expectedError string
+	}{
+		{"unknown status", http.MethodGet, "/webhooks/deliveries?status=lost", "", "status must be pending, success, failed or all"},
+		{"limit too large", http.MethodGet, "/webhooks/deliveries?limit=1000", "", "limit must be between 1 and 500"},
+		{"invalid webhook id", http.MethodGet, "/webhooks/deliveries?webhook_id=abc", "", "invalid webhook_id"},
+		{"invalid since", http.MethodGet, "/webhooks/deliveries?since=yesterday", "", "since must be an RFC 3339 timestamp"},
+		{"get invalid delivery id", http.MethodGet, "/webhooks/deliveries/abc", "", "Invalid delivery ID"},
+		{"replay invalid delivery id", http.MethodPost, "/webhooks/deliveries/abc/replay", "", "Invalid delivery ID"},
+		{"replay without ids or range", http.MethodPost, "/webhooks/deliveries/replay", `{}`, "Either ids or since is required"},
+		{"replay unknown status", http.MethodPost, "/webhooks/deliveries/replay", `{"since": "2026-01-01T00:00:00Z", "status": "lost"}`, "status must be pending, success or failed"},
+		{"replay invalid body", http.MethodPost, "/webhooks/deliveries/replay", `not json`, "Invalid request body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			handler := NewWebhookHandler(nil)
+
+			app.Get("/webhooks/deliveries", handler.SearchDeliveries)
+			app.Get("/webhooks/deliveries/:delivery_id", handler.GetDelivery)
+			app.Post("/webhooks/deliveries/replay", handler.ReplayDeliveries)
+			app.Post("/webhooks/deliveries/:delivery_id/replay", handler.ReplayDelivery)
+
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+			var result map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+			assert.Contains(t, result["error"], tt.expectedError)
+		})
+	}
+}
+
+func TestReplayDeliveries_TooMany(t *testing.T) {
+	app := fiber.New()
+	handler := NewWebhookHandler(nil)
+	app.Post("/webhooks/deliveries/replay", handler.ReplayDeliveries)
+
+	ids := make([]uuid.UUID, maxDeliveryReplays+1)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+	body, err := json.Marshal(ReplayDeliveriesRequest{IDs: ids})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/deliveries/replay", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
 // =============================================================================
 // Webhook Model Tests
 // =============================================================================
@@ -0,0 +1,137 @@
+// Package apierror defines the error responses of the HTTP API.
+//
+// Every error response has the same shape, so SDKs can branch on the machine-readable code
+// instead of parsing messages:
+//
+//	{"error": "Invalid request body", "code": "INVALID_REQUEST_BODY", "request_id": "..."}
+//
+// The "error" key carries the human-readable message, as it always has; "message", "hint" and
+// "details" are only present when a handler has more to say.
+package apierror
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/requestid"
+	"github.com/rs/zerolog/log"
+)
+
+// Response is the JSON body of every API error response
+type Response struct {
+	Error     string      `json:"error"`
+	Code      string      `json:"code,omitempty"`
+	Message   string      `json:"message,omitempty"`
+	Hint      string      `json:"hint,omitempty"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+// Error is an API error. Handlers either send it with SendError or return it, in which case
+// Handler renders it.
+type Error struct {
+	// Status is the HTTP status code
+	Status int
+	// Code is the machine-readable error code, one of the Code* constants or a
+	// handler-specific code
+	Code string
+	// Message is the human-readable error, rendered as "error"
+	Message string
+	// Detail explains the error further, rendered as "message"
+	Detail string
+	// Hint suggests how to fix the request
+	Hint string
+	// Details carries structured context such as validation failures
+	Details interface{}
+}
+
+// New creates an API error
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// WithDetail returns a copy of the error with the given detail message
+func (e *Error) WithDetail(detail string) *Error {
+	copied := *e
+	copied.Detail = detail
+	return &copied
+}
+
+// WithHint returns a copy of the error with the given hint
+func (e *Error) WithHint(hint string) *Error {
+	copied := *e
+	copied.Hint = hint
+	return &copied
+}
+
+// WithDetails returns a copy of the error with the given structured details
+func (e *Error) WithDetails(details interface{}) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// RequestID extracts the request ID from the Fiber context.
+// It first checks the requestid middleware local, then falls back to the X-Request-ID header.
+func RequestID(c fiber.Ctx) string {
+	if requestID := requestid.FromContext(c); requestID != "" {
+		return requestID
+	}
+	return c.Get("X-Request-ID", "")
+}
+
+// Send writes an error response with the given status, code and message
+func Send(c fiber.Ctx, status int, code, message string) error {
+	return SendError(c, New(status, code, message))
+}
+
+// SendError writes the error response for e, tagged with the request ID
+func SendError(c fiber.Ctx, e *Error) error {
+	status := e.Status
+	if status == 0 {
+		status = fiber.StatusInternalServerError
+	}
+	code := e.Code
+	if code == "" {
+		code = CodeForStatus(status)
+	}
+	return c.Status(status).JSON(Response{
+		Error:     e.Message,
+		Code:      code,
+		Message:   e.Detail,
+		Hint:      e.Hint,
+		Details:   e.Details,
+		RequestID: RequestID(c),
+	})
+}
+
+// Handler is the Fiber error handler. It renders errors returned by handlers and middleware:
+// an *Error as is, a *fiber.Error with the code for its status, and anything else as a
+// generic 500 so internal error messages don't leak to clients.
+func Handler(c fiber.Ctx, err error) error {
+	var apiErr *Error
+	var fiberErr *fiber.Error
+	switch {
+	case errors.As(err, &apiErr):
+	case errors.As(err, &fiberErr):
+		apiErr = New(fiberErr.Code, CodeForStatus(fiberErr.Code), fiberErr.Message)
+	default:
+		apiErr = New(fiber.StatusInternalServerError, CodeInternalError, http.StatusText(fiber.StatusInternalServerError))
+	}
+
+	if apiErr.Status >= 500 || apiErr.Status == 0 {
+		log.Error().
+			Err(err).
+			Str("path", c.Path()).
+			Str("request_id", RequestID(c)).
+			Msg("Server error")
+	}
+
+	return SendError(c, apiErr)
+}
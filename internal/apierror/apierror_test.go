@@ -0,0 +1,182 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func doRequest(t *testing.T, app *fiber.App, requestID string) (int, map[string]interface{}) {
+	t.Helper()
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	if requestID != "" {
+		req.Header.Set("X-Request-ID", requestID)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &result))
+	return resp.StatusCode, result
+}
+
+func TestSend(t *testing.T) {
+	app := fiber.New()
+	app.Get("/test", func(c fiber.Ctx) error {
+		return Send(c, fiber.StatusBadRequest, CodeInvalidBody, "Invalid request body")
+	})
+
+	status, body := doRequest(t, app, "req-123")
+
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Equal(t, map[string]interface{}{
+		"error":      "Invalid request body",
+		"code":       "INVALID_REQUEST_BODY",
+		"request_id": "req-123",
+	}, body)
+}
+
+func TestSendError(t *testing.T) {
+	t.Run("renders all fields", func(t *testing.T) {
+		app := fiber.New()
+		app.Get("/test", func(c fiber.Ctx) error {
+			return SendError(c, New(fiber.StatusForbidden, CodeRLSViolation, "Insufficient permissions").
+				WithDetail("Row-level security policy blocks this operation").
+				WithHint("Verify your table access policies").
+				WithDetails(fiber.Map{"table": "posts"}))
+		})
+
+		status, body := doRequest(t, app, "")
+
+		assert.Equal(t, fiber.StatusForbidden, status)
+		assert.Equal(t, "Insufficient permissions", body["error"])
+		assert.Equal(t, "RLS_POLICY_VIOLATION", body["code"])
+		assert.Equal(t, "Row-level security policy blocks this operation", body["message"])
+		assert.Equal(t, "Verify your table access policies", body["hint"])
+		assert.Equal(t, map[string]interface{}{"table": "posts"}, body["details"])
+		assert.NotContains(t, body, "request_id")
+	})
+
+	t.Run("defaults code from status", func(t *testing.T) {
+		app := fiber.New()
+		app.Get("/test", func(c fiber.Ctx) error {
+			return SendError(c, &Error{Status: fiber.StatusNotFound, Message: "Bucket not found"})
+		})
+
+		status, body := doRequest(t, app, "")
+
+		assert.Equal(t, fiber.StatusNotFound, status)
+		assert.Equal(t, "NOT_FOUND", body["code"])
+	})
+}
+
+func TestError_WithersCopy(t *testing.T) {
+	base := New(fiber.StatusBadRequest, CodeInvalidInput, "Invalid input")
+	detailed := base.WithDetail("limit must be positive")
+
+	assert.Empty(t, base.Detail)
+	assert.Equal(t, "limit must be positive", detailed.Detail)
+	assert.Equal(t, "Invalid input", detailed.Error())
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+		expectedErr  string
+		expectedBody string
+	}{
+		{
+			name:         "generic error is hidden behind 500",
+			err:          errors.New("pq: password authentication failed"),
+			expectedCode: 500,
+			expectedErr:  "Internal Server Error",
+			expectedBody: "INTERNAL_ERROR",
+		},
+		{
+			name:         "api error is rendered as is",
+			err:          New(fiber.StatusConflict, CodeDuplicateKey, "Record already exists"),
+			expectedCode: 409,
+			expectedErr:  "Record already exists",
+			expectedBody: "DUPLICATE_KEY",
+		},
+		{
+			name:         "wrapped api error",
+			err:          fmt.Errorf("create bucket: %w", New(fiber.StatusBadRequest, CodeInvalidInput, "Invalid bucket name")),
+			expectedCode: 400,
+			expectedErr:  "Invalid bucket name",
+			expectedBody: "INVALID_INPUT",
+		},
+		{
+			name:         "fiber 401 error",
+			err:          fiber.NewError(fiber.StatusUnauthorized, "Unauthorized"),
+			expectedCode: 401,
+			expectedErr:  "Unauthorized",
+			expectedBody: "AUTHENTICATION_REQUIRED",
+		},
+		{
+			name:         "fiber 404 error",
+			err:          fiber.ErrNotFound,
+			expectedCode: 404,
+			expectedErr:  "Not Found",
+			expectedBody: "NOT_FOUND",
+		},
+		{
+			name:         "fiber 413 error",
+			err:          fiber.ErrRequestEntityTooLarge,
+			expectedCode: 413,
+			expectedErr:  "Request Entity Too Large",
+			expectedBody: "PAYLOAD_TOO_LARGE",
+		},
+		{
+			name:         "fiber 429 error",
+			err:          fiber.NewError(fiber.StatusTooManyRequests, "Rate limit exceeded"),
+			expectedCode: 429,
+			expectedErr:  "Rate limit exceeded",
+			expectedBody: "RATE_LIMIT_EXCEEDED",
+		},
+		{
+			name:         "fiber 503 error",
+			err:          fiber.NewError(fiber.StatusServiceUnavailable, "Service unavailable"),
+			expectedCode: 503,
+			expectedErr:  "Service unavailable",
+			expectedBody: "SERVICE_UNAVAILABLE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: Handler})
+			app.Get("/test", func(c fiber.Ctx) error {
+				return tt.err
+			})
+
+			status, body := doRequest(t, app, "req-456")
+
+			assert.Equal(t, tt.expectedCode, status)
+			assert.Equal(t, tt.expectedErr, body["error"])
+			assert.Equal(t, tt.expectedBody, body["code"])
+			assert.Equal(t, "req-456", body["request_id"])
+		})
+	}
+}
+
+func TestCodeForStatus(t *testing.T) {
+	assert.Equal(t, CodeInvalidInput, CodeForStatus(fiber.StatusBadRequest))
+	assert.Equal(t, CodeAccessDenied, CodeForStatus(fiber.StatusForbidden))
+	assert.Equal(t, CodeTimeout, CodeForStatus(fiber.StatusGatewayTimeout))
+	assert.Equal(t, CodeInternalError, CodeForStatus(fiber.StatusInsufficientStorage))
+	assert.Equal(t, CodeInvalidInput, CodeForStatus(fiber.StatusTeapot))
+}
@@ -0,0 +1,109 @@
+package apierror
+
+import "github.com/gofiber/fiber/v3"
+
+// Standard error codes, returned in the "code" field of error responses.
+// Handlers may use more specific codes (e.g. CAPTCHA_REQUIRED) where clients need them.
+const (
+	// Authentication errors (401)
+	CodeMissingAuth        = "MISSING_AUTHENTICATION"
+	CodeInvalidToken       = "INVALID_TOKEN"
+	CodeExpiredToken       = "EXPIRED_TOKEN"
+	CodeRevokedToken       = "REVOKED_TOKEN"
+	CodeAuthRequired       = "AUTHENTICATION_REQUIRED"
+	CodeInvalidUserID      = "INVALID_USER_ID"
+	CodeAccountLocked      = "ACCOUNT_LOCKED"
+	CodeInvalidCredentials = "INVALID_CREDENTIALS" //nolint:gosec // Not a credential, just an error code constant
+
+	// Authorization errors (403)
+	CodeInsufficientPermissions = "INSUFFICIENT_PERMISSIONS"
+	CodeAdminRequired           = "ADMIN_REQUIRED"
+	CodeInvalidRole             = "INVALID_ROLE"
+	CodeRLSViolation            = "RLS_POLICY_VIOLATION"
+	CodeAccessDenied            = "ACCESS_DENIED"
+	CodeFeatureDisabled         = "FEATURE_DISABLED"
+
+	// Validation errors (400)
+	CodeInvalidBody      = "INVALID_REQUEST_BODY"
+	CodeMissingField     = "MISSING_REQUIRED_FIELD"
+	CodeInvalidInput     = "INVALID_INPUT"
+	CodeInvalidID        = "INVALID_ID"
+	CodeInvalidFormat    = "INVALID_FORMAT"
+	CodeValidationFailed = "VALIDATION_FAILED"
+
+	// Resource errors (404, 405, 409, 410, 413, 415)
+	CodeNotFound             = "NOT_FOUND"
+	CodeMethodNotAllowed     = "METHOD_NOT_ALLOWED"
+	CodeAlreadyExists        = "ALREADY_EXISTS"
+	CodeDuplicateKey         = "DUPLICATE_KEY"
+	CodeConflict             = "CONFLICT"
+	CodeForeignKeyViolation  = "FOREIGN_KEY_VIOLATION"
+	CodeGone                 = "GONE"
+	CodePayloadTooLarge      = "PAYLOAD_TOO_LARGE"
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+
+	// Constraint errors (400)
+	CodeNotNullViolation = "NOT_NULL_VIOLATION"
+	CodeCheckViolation   = "CHECK_VIOLATION"
+
+	// Server errors (5xx)
+	CodeInternalError      = "INTERNAL_ERROR"
+	CodeDatabaseError      = "DATABASE_ERROR"
+	CodeOperationFailed    = "OPERATION_FAILED"
+	CodeNotImplemented     = "NOT_IMPLEMENTED"
+	CodeUpstreamError      = "UPSTREAM_ERROR"
+	CodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+	CodeTimeout            = "TIMEOUT"
+
+	// Rate limiting (429)
+	CodeRateLimited     = "RATE_LIMIT_EXCEEDED"
+	CodeTooManyRequests = "TOO_MANY_REQUESTS"
+	CodeQuotaExceeded   = "QUOTA_EXCEEDED"
+
+	// Setup/config errors
+	CodeSetupRequired     = "SETUP_REQUIRED"
+	CodeSetupCompleted    = "SETUP_ALREADY_COMPLETED"
+	CodeSetupDisabled     = "SETUP_DISABLED"
+	CodeInvalidSetupToken = "INVALID_SETUP_TOKEN"
+)
+
+// CodeForStatus returns the default code for an HTTP status, used for errors that only
+// carry a status (e.g. fiber.ErrNotFound for unknown routes)
+func CodeForStatus(status int) string {
+	switch status {
+	case fiber.StatusBadRequest:
+		return CodeInvalidInput
+	case fiber.StatusUnauthorized:
+		return CodeAuthRequired
+	case fiber.StatusForbidden:
+		return CodeAccessDenied
+	case fiber.StatusNotFound:
+		return CodeNotFound
+	case fiber.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case fiber.StatusRequestTimeout, fiber.StatusGatewayTimeout:
+		return CodeTimeout
+	case fiber.StatusConflict:
+		return CodeConflict
+	case fiber.StatusGone:
+		return CodeGone
+	case fiber.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case fiber.StatusUnsupportedMediaType:
+		return CodeUnsupportedMediaType
+	case fiber.StatusUnprocessableEntity:
+		return CodeValidationFailed
+	case fiber.StatusTooManyRequests:
+		return CodeRateLimited
+	case fiber.StatusNotImplemented:
+		return CodeNotImplemented
+	case fiber.StatusBadGateway:
+		return CodeUpstreamError
+	case fiber.StatusServiceUnavailable:
+		return CodeServiceUnavailable
+	}
+	if status >= 500 {
+		return CodeInternalError
+	}
+	return CodeInvalidInput
+}
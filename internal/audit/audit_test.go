@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryWriter keeps recorded events in memory
+type memoryWriter struct {
+	events []Event
+}
+
+func (w *memoryWriter) Insert(_ context.Context, e Event) error {
+	w.events = append(w.events, e)
+	return nil
+}
+
+func newTestLogger() (*Logger, *memoryWriter) {
+	w := &memoryWriter{}
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	return &Logger{writer: w, now: func() time.Time { return now }}, w
+}
+
+func TestCategoryOf(t *testing.T) {
+	assert.Equal(t, CategoryAuth, CategoryOf(ActionSignIn))
+	assert.Equal(t, CategoryAdmin, CategoryOf(ActionRLSPolicyCreate))
+	assert.Equal(t, CategoryData, CategoryOf(ActionSQLExecute))
+	assert.False(t, CategoryOf("unknown").Valid())
+}
+
+func TestLogger_Record(t *testing.T) {
+	t.Run("nil logger records nothing", func(t *testing.T) {
+		var l *Logger
+		assert.NotPanics(t, func() { l.Record(context.Background(), Event{Action: ActionSignIn}) })
+	})
+
+	t.Run("fills in time and category", func(t *testing.T) {
+		l, w := newTestLogger()
+		l.Record(context.Background(), Event{Action: ActionSignIn, ActorID: "u1"})
+
+		require.Len(t, w.events, 1)
+		assert.Equal(t, CategoryAuth, w.events[0].Category)
+		assert.Equal(t, l.now(), w.events[0].OccurredAt)
+	})
+}
+
+func TestLogger_Middleware(t *testing.T) {
+	l, w := newTestLogger()
+	app := fiber.New()
+	app.Delete("/policies/:schema/:table/:policy", func(c fiber.Ctx) error {
+		c.Locals("user_id", "admin-1")
+		c.Locals("user_role", "dashboard_admin")
+		return c.Next()
+	}, l.Middleware(ActionRLSPolicyDelete, "rls_policy", "schema", "table", "policy"), func(c fiber.Ctx) error {
+		if c.Params("policy") == "missing" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "not found"})
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("DELETE", "/policies/public/posts/owner_only", nil))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	require.Len(t, w.events, 1)
+	e := w.events[0]
+	assert.Equal(t, ActionRLSPolicyDelete, e.Action)
+	assert.Equal(t, CategoryAdmin, e.Category)
+	assert.Equal(t, "admin-1", e.ActorID)
+	assert.Equal(t, "dashboard_admin", e.ActorRole)
+	assert.Equal(t, "rls_policy", e.ResourceType)
+	assert.Equal(t, "public/posts/owner_only", e.ResourceID)
+	assert.Equal(t, "DELETE", e.Details["method"])
+
+	t.Run("failed requests are not recorded", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("DELETE", "/policies/public/posts/missing", nil))
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Len(t, w.events, 1)
+	})
+}
+
+func TestFilter_Where(t *testing.T) {
+	t.Run("empty filter matches everything", func(t *testing.T) {
+		where, args := Filter{}.where()
+		assert.Empty(t, where)
+		assert.Empty(t, args)
+	})
+
+	t.Run("numbers placeholders in order", func(t *testing.T) {
+		since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+		where, args := Filter{Action: ActionSignIn, ResourceType: "user", Since: since}.where()
+		assert.Equal(t, "WHERE action = $1 AND resource_type = $2 AND occurred_at >= $3", where)
+		assert.Equal(t, []any{ActionSignIn, "user", since}, args)
+	})
+}
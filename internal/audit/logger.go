@@ -0,0 +1,114 @@
+package audit
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/requestid"
+	"github.com/rs/zerolog/log"
+)
+
+// recordTimeout bounds how long recording an event can delay the request that caused it
+const recordTimeout = 5 * time.Second
+
+// eventWriter persists events; *Store in production
+type eventWriter interface {
+	Insert(ctx context.Context, e Event) error
+}
+
+// Logger records audit events. A nil *Logger records nothing, so handlers can call it
+// unconditionally when the audit log is disabled.
+type Logger struct {
+	writer eventWriter
+	now    func() time.Time
+}
+
+// NewLogger creates an audit logger that writes to the store
+func NewLogger(store *Store) *Logger {
+	return &Logger{writer: store, now: time.Now}
+}
+
+// Record writes an event. Failures are logged rather than returned: a lost audit event must
+// not fail the action it describes. The write outlives cancellation of ctx, so an event is
+// still recorded when the client disconnects.
+func (l *Logger) Record(ctx context.Context, e Event) {
+	if l == nil {
+		return
+	}
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = l.now()
+	}
+	if e.Category == "" {
+		e.Category = CategoryOf(e.Action)
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+
+	if err := l.writer.Insert(ctx, e); err != nil {
+		log.Error().Err(err).Str("action", e.Action).Str("actor_id", e.ActorID).Msg("Failed to record audit event")
+	}
+}
+
+// RecordRequest records an action by the authenticated caller of a request
+func (l *Logger) RecordRequest(c fiber.Ctx, e Event) {
+	if l == nil {
+		return
+	}
+	l.Record(c.RequestCtx(), FromRequest(c, e))
+}
+
+// Middleware records an action after the handler that follows it succeeds. The resource ID is
+// the value of the given route parameters, joined with "/" (e.g. schema/table/policy).
+func (l *Logger) Middleware(action, resourceType string, params ...string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		err := c.Next()
+		if l == nil || err != nil || c.Response().StatusCode() >= fiber.StatusBadRequest {
+			return err
+		}
+
+		var ids []string
+		for _, param := range params {
+			if v := c.Params(param); v != "" {
+				ids = append(ids, v)
+			}
+		}
+
+		l.RecordRequest(c, Event{
+			Action:       action,
+			ResourceType: resourceType,
+			ResourceID:   strings.Join(ids, "/"),
+			Details: map[string]any{
+				"method": c.Method(),
+				"path":   c.Path(),
+			},
+		})
+		return nil
+	}
+}
+
+// FromRequest fills in the actor, client and request ID of an event from the request, keeping
+// the fields the event already sets
+func FromRequest(c fiber.Ctx, e Event) Event {
+	if e.ActorID == "" {
+		e.ActorID, _ = c.Locals("user_id").(string)
+	}
+	if e.ActorEmail == "" {
+		e.ActorEmail, _ = c.Locals("user_email").(string)
+	}
+	if e.ActorRole == "" {
+		e.ActorRole, _ = c.Locals("user_role").(string)
+	}
+	if e.IPAddress == "" {
+		e.IPAddress = c.IP()
+	}
+	if e.UserAgent == "" {
+		e.UserAgent = c.Get(fiber.HeaderUserAgent)
+	}
+	if e.RequestID == "" {
+		e.RequestID = requestid.FromContext(c)
+	}
+	return e
+}
@@ -0,0 +1,106 @@
+package audit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+// pruneInterval is how often events older than the retention are deleted
+const pruneInterval = time.Hour
+
+// Pruner deletes audit events older than the retention. It must run on a single node
+// (leader-elected).
+type Pruner struct {
+	cfg   *config.AuditConfig
+	store *Store
+	now   func() time.Time
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running bool
+	mu      sync.Mutex
+}
+
+// NewPruner creates an audit retention pruner
+func NewPruner(cfg *config.AuditConfig, store *Store) *Pruner {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Pruner{
+		cfg:    cfg,
+		store:  store,
+		now:    time.Now,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start begins pruning expired events
+func (p *Pruner) Start() {
+	p.mu.Lock()
+	if p.running {
+		p.mu.Unlock()
+		return
+	}
+	p.running = true
+	if p.ctx.Err() != nil {
+		p.ctx, p.cancel = context.WithCancel(context.Background())
+	}
+	p.mu.Unlock()
+
+	p.wg.Add(1)
+	go p.run()
+
+	log.Info().Int("retention_days", p.cfg.RetentionDays).Msg("Audit log pruner started")
+}
+
+// Stop stops pruning
+func (p *Pruner) Stop() {
+	p.mu.Lock()
+	if !p.running {
+		p.mu.Unlock()
+		return
+	}
+	p.running = false
+	p.mu.Unlock()
+
+	p.cancel()
+	p.wg.Wait()
+
+	log.Info().Msg("Audit log pruner stopped")
+}
+
+// run prunes on start and then every prune interval
+func (p *Pruner) run() {
+	defer p.wg.Done()
+
+	p.prune(p.ctx)
+
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			p.prune(p.ctx)
+		}
+	}
+}
+
+// prune deletes the events older than the retention
+func (p *Pruner) prune(ctx context.Context) {
+	pruned, err := p.store.Prune(ctx, p.now().AddDate(0, 0, -p.cfg.RetentionDays))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to prune audit events")
+		return
+	}
+	if pruned > 0 {
+		log.Debug().Int64("events", pruned).Msg("Pruned expired audit events")
+	}
+}
@@ -0,0 +1,189 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Filter selects audit events; zero fields match every event
+type Filter struct {
+	ActorID      string
+	Action       string
+	Category     Category
+	ResourceType string
+	ResourceID   string
+	Since        time.Time // Inclusive
+	Until        time.Time // Exclusive
+	Limit        int
+	Offset       int
+}
+
+// QueryResult is a page of audit events, newest first
+type QueryResult struct {
+	Events     []Event `json:"events"`
+	TotalCount int64   `json:"total_count"`
+	HasMore    bool    `json:"has_more"`
+}
+
+// Store persists audit events in audit.events
+type Store struct {
+	db *pgxpool.Pool
+}
+
+// NewStore creates an audit event store
+func NewStore(db *pgxpool.Pool) *Store {
+	return &Store{db: db}
+}
+
+// Insert records an event
+func (s *Store) Insert(ctx context.Context, e Event) error {
+	details, err := json.Marshal(e.Details)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit event details: %w", err)
+	}
+	if e.Details == nil {
+		details = []byte("{}")
+	}
+
+	_, err = s.db.Exec(ctx, `
+		INSERT INTO audit.events (
+			occurred_at, category, action, actor_id, actor_email, actor_role,
+			resource_type, resource_id, ip_address, user_agent, request_id, details
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9::inet, $10, $11, $12)
+	`, e.OccurredAt, string(e.Category), e.Action, uuidOrNil(e.ActorID), nilIfEmpty(e.ActorEmail), nilIfEmpty(e.ActorRole),
+		nilIfEmpty(e.ResourceType), nilIfEmpty(e.ResourceID), ipOrNil(e.IPAddress), nilIfEmpty(e.UserAgent), nilIfEmpty(e.RequestID), details)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit event: %w", err)
+	}
+	return nil
+}
+
+// Query returns the events matching the filter, newest first
+func (s *Store) Query(ctx context.Context, f Filter) (*QueryResult, error) {
+	where, args := f.where()
+	args = append(args, f.Limit+1, f.Offset)
+
+	rows, err := s.db.Query(ctx, `
+		SELECT id, occurred_at, category, action,
+		       COALESCE(actor_id::text, ''), COALESCE(actor_email, ''), COALESCE(actor_role, ''),
+		       COALESCE(resource_type, ''), COALESCE(resource_id, ''),
+		       COALESCE(host(ip_address), ''), COALESCE(user_agent, ''), COALESCE(request_id, ''),
+		       details, COUNT(*) OVER ()
+		FROM audit.events
+		`+where+`
+		ORDER BY occurred_at DESC, id
+		LIMIT $`+fmt.Sprint(len(args)-1)+` OFFSET $`+fmt.Sprint(len(args)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit events: %w", err)
+	}
+	defer rows.Close()
+
+	result := &QueryResult{Events: []Event{}}
+	for rows.Next() {
+		var e Event
+		var category string
+		var details []byte
+		if err := rows.Scan(&e.ID, &e.OccurredAt, &category, &e.Action,
+			&e.ActorID, &e.ActorEmail, &e.ActorRole, &e.ResourceType, &e.ResourceID,
+			&e.IPAddress, &e.UserAgent, &e.RequestID, &details, &result.TotalCount); err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		e.Category = Category(category)
+		if err := json.Unmarshal(details, &e.Details); err != nil {
+			return nil, fmt.Errorf("failed to decode audit event details: %w", err)
+		}
+		result.Events = append(result.Events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit events: %w", err)
+	}
+
+	if len(result.Events) > f.Limit {
+		result.Events = result.Events[:f.Limit]
+		result.HasMore = true
+	}
+	if len(result.Events) == 0 && f.Offset > 0 {
+		// COUNT(*) OVER () has no rows to report on past the last page
+		if err := s.db.QueryRow(ctx, `SELECT COUNT(*) FROM audit.events `+where, args[:len(args)-2]...).Scan(&result.TotalCount); err != nil {
+			return nil, fmt.Errorf("failed to count audit events: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// Prune deletes the events that occurred before the given time
+func (s *Store) Prune(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := s.db.Exec(ctx, `DELETE FROM audit.events WHERE occurred_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune audit events: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// where builds the WHERE clause of the filter and its arguments
+func (f Filter) where() (string, []any) {
+	var conditions []string
+	var args []any
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if f.ActorID != "" {
+		add("actor_id = $%d", f.ActorID)
+	}
+	if f.Action != "" {
+		add("action = $%d", f.Action)
+	}
+	if f.Category != "" {
+		add("category = $%d", string(f.Category))
+	}
+	if f.ResourceType != "" {
+		add("resource_type = $%d", f.ResourceType)
+	}
+	if f.ResourceID != "" {
+		add("resource_id = $%d", f.ResourceID)
+	}
+	if !f.Since.IsZero() {
+		add("occurred_at >= $%d", f.Since)
+	}
+	if !f.Until.IsZero() {
+		add("occurred_at < $%d", f.Until)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// nilIfEmpty stores empty strings as NULL
+func nilIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// uuidOrNil stores actor IDs that are not UUIDs, such as service keys, as NULL
+func uuidOrNil(s string) any {
+	if _, err := uuid.Parse(s); err != nil {
+		return nil
+	}
+	return s
+}
+
+// ipOrNil stores unparseable client addresses as NULL
+func ipOrNil(s string) any {
+	if net.ParseIP(s) == nil {
+		return nil
+	}
+	return s
+}
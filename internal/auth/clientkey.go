@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog/log"
 )
@@ -23,6 +24,25 @@ var (
 	ErrClientKeyRevoked = errors.New("client key has been revoked")
 	// ErrUserClientKeysDisabled is returned when user client keys are disabled via settings
 	ErrUserClientKeysDisabled = errors.New("user client keys are disabled")
+	// ErrClientKeyNotFound is returned when no client key has the given ID
+	ErrClientKeyNotFound = errors.New("client key not found")
+	// ErrClientKeyRotated is returned when rotating a key that has already been rotated
+	ErrClientKeyRotated = errors.New("client key has already been rotated")
+	// ErrInvalidJSONKeyCase is returned for a JSON key case other than snake or camel
+	ErrInvalidJSONKeyCase = errors.New("json_key_case must be 'snake' or 'camel'")
+)
+
+const (
+	// DefaultClientKeyRotationGrace is how long a rotated key stays valid when no grace period is given
+	DefaultClientKeyRotationGrace = 24 * time.Hour
+	// MaxClientKeyRotationGrace is the longest a rotated key can stay valid
+	MaxClientKeyRotationGrace = 30 * 24 * time.Hour
+)
+
+// JSON key cases of table rows in REST requests and responses
+const (
+	JSONKeyCaseSnake = "snake" // keys are column names
+	JSONKeyCaseCamel = "camel" // snake_case column names are camelCase keys
 )
 
 // ClientKey represents a client key
@@ -36,11 +56,15 @@ type ClientKey struct {
 	Scopes             []string   `json:"scopes"`
 	AllowedNamespaces  []string   `json:"allowed_namespaces,omitempty"` // nil = all namespaces, empty = default only
 	RateLimitPerMinute int        `json:"rate_limit_per_minute"`
+	JSONKeyCase        string     `json:"json_key_case"`
+	RotatedTo          *uuid.UUID `json:"rotated_to,omitempty"` // replacement key after a rotation
 	LastUsedAt         *time.Time `json:"last_used_at,omitempty"`
 	ExpiresAt          *time.Time `json:"expires_at,omitempty"`
 	RevokedAt          *time.Time `json:"revoked_at,omitempty"`
 	CreatedAt          time.Time  `json:"created_at"`
 	UpdatedAt          time.Time  `json:"updated_at"`
+	// Table, bucket and IP restrictions beyond the scopes
+	ClientKeyRestrictions
 }
 
 // ClientKeyWithPlaintext includes the plaintext key (only returned once during creation)
@@ -71,6 +95,17 @@ func (s *ClientKeyService) SetSettingsCache(cache *SettingsCache) {
 
 // GenerateClientKey generates a new client key with format: fbk_<random_string>
 func (s *ClientKeyService) GenerateClientKey(ctx context.Context, name string, description *string, userID *uuid.UUID, scopes []string, rateLimitPerMinute int, expiresAt *time.Time) (*ClientKeyWithPlaintext, error) {
+	// Validate scopes - at least one scope is required
+	if err := ValidateScopes(scopes); err != nil {
+		return nil, fmt.Errorf("invalid scopes: %w", err)
+	}
+
+	return createClientKey(ctx, s.db, name, description, userID, scopes, rateLimitPerMinute, expiresAt)
+}
+
+// createClientKey generates and stores a new client key using q (the pool or a transaction)
+// Scopes must already be validated
+func createClientKey(ctx context.Context, q DBPool, name string, description *string, userID *uuid.UUID, scopes []string, rateLimitPerMinute int, expiresAt *time.Time) (*ClientKeyWithPlaintext, error) {
 	// Generate random bytes for the key
 	keyBytes := make([]byte, 32)
 	if _, err := rand.Read(keyBytes); err != nil {
@@ -86,77 +121,45 @@ func (s *ClientKeyService) GenerateClientKey(ctx context.Context, name string, d
 	// Extract prefix (first 12 chars: "fbk_" + 8 chars)
 	keyPrefix := plaintextKey[:12]
 
-	// Validate scopes - at least one scope is required
-	if err := ValidateScopes(scopes); err != nil {
-		return nil, fmt.Errorf("invalid scopes: %w", err)
-	}
-
 	// Set default rate limit
 	if rateLimitPerMinute == 0 {
 		rateLimitPerMinute = 100
 	}
 
 	// Insert into database
-	var clientKey ClientKey
 	query := `
 		INSERT INTO auth.client_keys (name, description, key_hash, key_prefix, user_id, scopes, rate_limit_per_minute, expires_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, name, description, key_hash, key_prefix, user_id, scopes, rate_limit_per_minute, last_used_at, expires_at, revoked_at, created_at, updated_at
-	`
+		RETURNING ` + clientKeyColumns
 
-	err := s.db.QueryRow(ctx, query, name, description, keyHash, keyPrefix, userID, scopes, rateLimitPerMinute, expiresAt).Scan(
-		&clientKey.ID,
-		&clientKey.Name,
-		&clientKey.Description,
-		&clientKey.KeyHash,
-		&clientKey.KeyPrefix,
-		&clientKey.UserID,
-		&clientKey.Scopes,
-		&clientKey.RateLimitPerMinute,
-		&clientKey.LastUsedAt,
-		&clientKey.ExpiresAt,
-		&clientKey.RevokedAt,
-		&clientKey.CreatedAt,
-		&clientKey.UpdatedAt,
-	)
+	clientKey, err := scanClientKey(q.QueryRow(ctx, query, name, description, keyHash, keyPrefix, userID, scopes, rateLimitPerMinute, expiresAt))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client key: %w", err)
 	}
 
 	return &ClientKeyWithPlaintext{
-		ClientKey:    clientKey,
+		ClientKey:    *clientKey,
 		PlaintextKey: plaintextKey,
 	}, nil
 }
 
 // ValidateClientKey validates a client key and returns the associated client key info
 func (s *ClientKeyService) ValidateClientKey(ctx context.Context, plaintextKey string) (*ClientKey, error) {
-	// Hash the provided key
-	keyHash := hashClientKey(plaintextKey)
+	return s.validateClientKey(ctx, "key_hash", hashClientKey(plaintextKey))
+}
+
+// ValidateClientKeyByID validates the client key with the given ID. It is used for
+// credentials derived from a client key, such as S3 access keys.
+func (s *ClientKeyService) ValidateClientKeyByID(ctx context.Context, id uuid.UUID) (*ClientKey, error) {
+	return s.validateClientKey(ctx, "id", id)
+}
 
+// validateClientKey looks up a client key by a unique column and checks it can be used
+func (s *ClientKeyService) validateClientKey(ctx context.Context, column string, value any) (*ClientKey, error) {
 	// Query the database
-	var clientKey ClientKey
-	query := `
-		SELECT id, name, description, key_hash, key_prefix, user_id, scopes, rate_limit_per_minute, last_used_at, expires_at, revoked_at, created_at, updated_at
-		FROM auth.client_keys
-		WHERE key_hash = $1
-	`
+	query := `SELECT ` + clientKeyColumns + ` FROM auth.client_keys WHERE ` + column + ` = $1`
 
-	err := s.db.QueryRow(ctx, query, keyHash).Scan(
-		&clientKey.ID,
-		&clientKey.Name,
-		&clientKey.Description,
-		&clientKey.KeyHash,
-		&clientKey.KeyPrefix,
-		&clientKey.UserID,
-		&clientKey.Scopes,
-		&clientKey.RateLimitPerMinute,
-		&clientKey.LastUsedAt,
-		&clientKey.ExpiresAt,
-		&clientKey.RevokedAt,
-		&clientKey.CreatedAt,
-		&clientKey.UpdatedAt,
-	)
+	clientKey, err := scanClientKey(s.db.QueryRow(ctx, query, value))
 	if err != nil {
 		return nil, ErrInvalidClientKey
 	}
@@ -191,7 +194,7 @@ func (s *ClientKeyService) ValidateClientKey(ctx context.Context, plaintextKey s
 		clientKey.LastUsedAt = &now
 	}
 
-	return &clientKey, nil
+	return clientKey, nil
 }
 
 // ListClientKeys lists all client keys (optionally filtered by user)
@@ -200,19 +203,10 @@ func (s *ClientKeyService) ListClientKeys(ctx context.Context, userID *uuid.UUID
 	var args []interface{}
 
 	if userID != nil {
-		query = `
-			SELECT id, name, description, key_hash, key_prefix, user_id, scopes, rate_limit_per_minute, last_used_at, expires_at, revoked_at, created_at, updated_at
-			FROM auth.client_keys
-			WHERE user_id = $1
-			ORDER BY created_at DESC
-		`
+		query = `SELECT ` + clientKeyColumns + ` FROM auth.client_keys WHERE user_id = $1 ORDER BY created_at DESC`
 		args = []interface{}{userID}
 	} else {
-		query = `
-			SELECT id, name, description, key_hash, key_prefix, user_id, scopes, rate_limit_per_minute, last_used_at, expires_at, revoked_at, created_at, updated_at
-			FROM auth.client_keys
-			ORDER BY created_at DESC
-		`
+		query = `SELECT ` + clientKeyColumns + ` FROM auth.client_keys ORDER BY created_at DESC`
 	}
 
 	rows, err := s.db.Query(ctx, query, args...)
@@ -223,26 +217,11 @@ func (s *ClientKeyService) ListClientKeys(ctx context.Context, userID *uuid.UUID
 
 	var clientKeys []ClientKey
 	for rows.Next() {
-		var clientKey ClientKey
-		err := rows.Scan(
-			&clientKey.ID,
-			&clientKey.Name,
-			&clientKey.Description,
-			&clientKey.KeyHash,
-			&clientKey.KeyPrefix,
-			&clientKey.UserID,
-			&clientKey.Scopes,
-			&clientKey.RateLimitPerMinute,
-			&clientKey.LastUsedAt,
-			&clientKey.ExpiresAt,
-			&clientKey.RevokedAt,
-			&clientKey.CreatedAt,
-			&clientKey.UpdatedAt,
-		)
+		clientKey, err := scanClientKey(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan client key: %w", err)
 		}
-		clientKeys = append(clientKeys, clientKey)
+		clientKeys = append(clientKeys, *clientKey)
 	}
 
 	return clientKeys, nil
@@ -257,7 +236,7 @@ func (s *ClientKeyService) RevokeClientKey(ctx context.Context, id uuid.UUID) er
 	}
 
 	if result.RowsAffected() == 0 {
-		return errors.New("client key not found")
+		return ErrClientKeyNotFound
 	}
 
 	return nil
@@ -272,7 +251,7 @@ func (s *ClientKeyService) DeleteClientKey(ctx context.Context, id uuid.UUID) er
 	}
 
 	if result.RowsAffected() == 0 {
-		return errors.New("client key not found")
+		return ErrClientKeyNotFound
 	}
 
 	return nil
@@ -302,12 +281,166 @@ func (s *ClientKeyService) UpdateClientKey(ctx context.Context, id uuid.UUID, na
 	}
 
 	if result.RowsAffected() == 0 {
-		return errors.New("client key not found")
+		return ErrClientKeyNotFound
+	}
+
+	return nil
+}
+
+// SetClientKeyJSONKeyCase sets the JSON key case of table rows for requests made with a client key
+func (s *ClientKeyService) SetClientKeyJSONKeyCase(ctx context.Context, id uuid.UUID, keyCase string) error {
+	if keyCase != JSONKeyCaseSnake && keyCase != JSONKeyCaseCamel {
+		return ErrInvalidJSONKeyCase
+	}
+
+	result, err := s.db.Exec(ctx, "UPDATE auth.client_keys SET json_key_case = $2 WHERE id = $1", id, keyCase)
+	if err != nil {
+		return fmt.Errorf("failed to update client key: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrClientKeyNotFound
 	}
 
 	return nil
 }
 
+// GetClientKey returns the client key with the given ID
+func (s *ClientKeyService) GetClientKey(ctx context.Context, id uuid.UUID) (*ClientKey, error) {
+	clientKey, err := scanClientKey(s.db.QueryRow(ctx, `SELECT `+clientKeyColumns+` FROM auth.client_keys WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrClientKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client key: %w", err)
+	}
+	return clientKey, nil
+}
+
+// SetClientKeyRestrictions replaces the table, bucket and IP restrictions of a client key
+func (s *ClientKeyService) SetClientKeyRestrictions(ctx context.Context, id uuid.UUID, restrictions ClientKeyRestrictions) error {
+	if err := restrictions.Validate(); err != nil {
+		return err
+	}
+	return setClientKeyRestrictions(ctx, s.db, id, restrictions)
+}
+
+// setClientKeyRestrictions stores validated restrictions using q (the pool or a transaction).
+// Empty restrictions are stored as NULL.
+func setClientKeyRestrictions(ctx context.Context, q DBPool, id uuid.UUID, restrictions ClientKeyRestrictions) error {
+	var tables map[string]string
+	var buckets, ips []string
+	if len(restrictions.AllowedTables) > 0 {
+		tables = restrictions.AllowedTables
+	}
+	if len(restrictions.AllowedBuckets) > 0 {
+		buckets = restrictions.AllowedBuckets
+	}
+	if len(restrictions.AllowedIPs) > 0 {
+		ips = restrictions.AllowedIPs
+	}
+
+	result, err := q.Exec(ctx, `
+		UPDATE auth.client_keys
+		SET allowed_tables = $2, allowed_buckets = $3, allowed_ips = $4
+		WHERE id = $1
+	`, id, tables, buckets, ips)
+	if err != nil {
+		return fmt.Errorf("failed to update client key restrictions: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrClientKeyNotFound
+	}
+
+	return nil
+}
+
+// RotateClientKey replaces a client key with a new key that has the same name, scopes,
+// restrictions and expiry. The old key stays valid for the grace period, so clients can
+// switch over without downtime, and then expires. A zero grace period expires it at once.
+func (s *ClientKeyService) RotateClientKey(ctx context.Context, id uuid.UUID, grace time.Duration) (*ClientKeyWithPlaintext, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	old, err := scanClientKey(tx.QueryRow(ctx, `SELECT `+clientKeyColumns+` FROM auth.client_keys WHERE id = $1 FOR UPDATE`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrClientKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client key: %w", err)
+	}
+	switch {
+	case old.RevokedAt != nil:
+		return nil, ErrClientKeyRevoked
+	case old.ExpiresAt != nil && old.ExpiresAt.Before(time.Now()):
+		return nil, ErrClientKeyExpired
+	case old.RotatedTo != nil:
+		return nil, ErrClientKeyRotated
+	}
+
+	clientKey, err := createClientKey(ctx, tx, old.Name, old.Description, old.UserID, old.Scopes, old.RateLimitPerMinute, old.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, "UPDATE auth.client_keys SET json_key_case = $2 WHERE id = $1", clientKey.ID, old.JSONKeyCase); err != nil {
+		return nil, fmt.Errorf("failed to copy client key settings: %w", err)
+	}
+	if err := setClientKeyRestrictions(ctx, tx, clientKey.ID, old.ClientKeyRestrictions); err != nil {
+		return nil, err
+	}
+	clientKey.JSONKeyCase = old.JSONKeyCase
+	clientKey.ClientKeyRestrictions = old.ClientKeyRestrictions
+
+	// The old key keeps its own expiry if that comes before the end of the grace period
+	graceEnd := time.Now().Add(grace)
+	if old.ExpiresAt != nil && old.ExpiresAt.Before(graceEnd) {
+		graceEnd = *old.ExpiresAt
+	}
+	if _, err := tx.Exec(ctx, "UPDATE auth.client_keys SET rotated_to = $2, expires_at = $3 WHERE id = $1", id, clientKey.ID, graceEnd); err != nil {
+		return nil, fmt.Errorf("failed to expire rotated client key: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit rotation: %w", err)
+	}
+	return clientKey, nil
+}
+
+const clientKeyColumns = `id, name, description, key_hash, key_prefix, user_id, scopes, rate_limit_per_minute, json_key_case,
+		allowed_tables, allowed_buckets, allowed_ips, rotated_to, last_used_at, expires_at, revoked_at, created_at, updated_at`
+
+func scanClientKey(row pgx.Row) (*ClientKey, error) {
+	var clientKey ClientKey
+	err := row.Scan(
+		&clientKey.ID,
+		&clientKey.Name,
+		&clientKey.Description,
+		&clientKey.KeyHash,
+		&clientKey.KeyPrefix,
+		&clientKey.UserID,
+		&clientKey.Scopes,
+		&clientKey.RateLimitPerMinute,
+		&clientKey.JSONKeyCase,
+		&clientKey.AllowedTables,
+		&clientKey.AllowedBuckets,
+		&clientKey.AllowedIPs,
+		&clientKey.RotatedTo,
+		&clientKey.LastUsedAt,
+		&clientKey.ExpiresAt,
+		&clientKey.RevokedAt,
+		&clientKey.CreatedAt,
+		&clientKey.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &clientKey, nil
+}
+
 // hashClientKey hashes a client key using SHA-256
 func hashClientKey(key string) string {
 	hash := sha256.Sum256([]byte(key))
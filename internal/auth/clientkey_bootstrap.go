@@ -0,0 +1,282 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// DefaultBootstrapTokenTTL is how long a bootstrap token can be used when no expiry is given
+	DefaultBootstrapTokenTTL = time.Hour
+	// MaxBootstrapTokenTTL is the longest a bootstrap token can be valid
+	MaxBootstrapTokenTTL = 7 * 24 * time.Hour
+)
+
+var (
+	// ErrInvalidBootstrapToken is returned when a bootstrap token is unknown, expired, revoked or already used
+	ErrInvalidBootstrapToken = errors.New("invalid, expired or already used bootstrap token")
+	// ErrBootstrapScopesExceeded is returned when enrollment requests scopes the bootstrap token does not grant
+	ErrBootstrapScopesExceeded = errors.New("requested scopes are not allowed by the bootstrap token")
+)
+
+// ClientKeyBootstrapToken is a one-time token that a new service exchanges for a client key
+type ClientKeyBootstrapToken struct {
+	ID                 uuid.UUID  `json:"id"`
+	Name               string     `json:"name"`
+	Description        *string    `json:"description,omitempty"`
+	TokenPrefix        string     `json:"token_prefix"`
+	Scopes             []string   `json:"scopes"`
+	RateLimitPerMinute int        `json:"rate_limit_per_minute"`
+	KeyTTLSeconds      *int       `json:"key_ttl_seconds,omitempty"`
+	ExpiresAt          time.Time  `json:"expires_at"`
+	CreatedBy          *uuid.UUID `json:"created_by,omitempty"`
+	UsedAt             *time.Time `json:"used_at,omitempty"`
+	UsedByIP           *string    `json:"used_by_ip,omitempty"`
+	ClientKeyID        *uuid.UUID `json:"client_key_id,omitempty"`
+	RevokedAt          *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	// Status is "pending", "used", "expired" or "revoked"
+	Status string `json:"status"`
+}
+
+// bootstrapTokenStatus returns the status of a token at now
+func bootstrapTokenStatus(t *ClientKeyBootstrapToken, now time.Time) string {
+	switch {
+	case t.RevokedAt != nil:
+		return "revoked"
+	case t.UsedAt != nil:
+		return "used"
+	case !t.ExpiresAt.After(now):
+		return "expired"
+	default:
+		return "pending"
+	}
+}
+
+// ClientKeyBootstrapTokenWithPlaintext includes the plaintext token (only returned once during creation)
+type ClientKeyBootstrapTokenWithPlaintext struct {
+	ClientKeyBootstrapToken
+	PlaintextToken string `json:"token"` // Full token, only shown once
+}
+
+// BootstrapTokenOptions describes the client key a bootstrap token can be exchanged for
+type BootstrapTokenOptions struct {
+	Name               string
+	Description        *string
+	Scopes             []string
+	RateLimitPerMinute int
+	// TTL is how long the token can be used, DefaultBootstrapTokenTTL when zero
+	TTL time.Duration
+	// KeyTTL is the lifetime of the issued client key, no expiry when zero
+	KeyTTL    time.Duration
+	CreatedBy *uuid.UUID
+}
+
+// Validate checks the options and applies defaults
+func (o *BootstrapTokenOptions) Validate() error {
+	if strings.TrimSpace(o.Name) == "" {
+		return errors.New("name is required")
+	}
+	if err := ValidateScopes(o.Scopes); err != nil {
+		return fmt.Errorf("invalid scopes: %w", err)
+	}
+	if o.TTL == 0 {
+		o.TTL = DefaultBootstrapTokenTTL
+	}
+	if o.TTL < time.Minute || o.TTL > MaxBootstrapTokenTTL {
+		return fmt.Errorf("token expiry must be between 1 minute and %s", MaxBootstrapTokenTTL)
+	}
+	if o.KeyTTL < 0 {
+		return errors.New("key expiry cannot be negative")
+	}
+	if o.RateLimitPerMinute < 0 {
+		return errors.New("rate limit cannot be negative")
+	}
+	if o.RateLimitPerMinute == 0 {
+		o.RateLimitPerMinute = 100
+	}
+	return nil
+}
+
+// CreateBootstrapToken creates a one-time enrollment token with format: fbb_<random_string>
+func (s *ClientKeyService) CreateBootstrapToken(ctx context.Context, opts BootstrapTokenOptions) (*ClientKeyBootstrapTokenWithPlaintext, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate random token: %w", err)
+	}
+	plaintextToken := "fbb_" + base64.URLEncoding.EncodeToString(tokenBytes)
+
+	var keyTTLSeconds *int
+	if opts.KeyTTL > 0 {
+		seconds := int(opts.KeyTTL.Seconds())
+		keyTTLSeconds = &seconds
+	}
+
+	query := `
+		INSERT INTO auth.client_key_bootstrap_tokens (name, description, token_hash, token_prefix, scopes, rate_limit_per_minute, key_ttl_seconds, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING ` + bootstrapTokenColumns
+
+	token, err := scanBootstrapToken(s.db.QueryRow(ctx, query,
+		opts.Name, opts.Description, hashClientKey(plaintextToken), plaintextToken[:12], opts.Scopes,
+		opts.RateLimitPerMinute, keyTTLSeconds, time.Now().Add(opts.TTL), opts.CreatedBy))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bootstrap token: %w", err)
+	}
+
+	return &ClientKeyBootstrapTokenWithPlaintext{
+		ClientKeyBootstrapToken: *token,
+		PlaintextToken:          plaintextToken,
+	}, nil
+}
+
+// ListBootstrapTokens lists bootstrap tokens, newest first
+func (s *ClientKeyService) ListBootstrapTokens(ctx context.Context) ([]ClientKeyBootstrapToken, error) {
+	rows, err := s.db.Query(ctx, `SELECT `+bootstrapTokenColumns+` FROM auth.client_key_bootstrap_tokens ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bootstrap tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []ClientKeyBootstrapToken{}
+	for rows.Next() {
+		token, err := scanBootstrapToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bootstrap token: %w", err)
+		}
+		tokens = append(tokens, *token)
+	}
+	return tokens, rows.Err()
+}
+
+// RevokeBootstrapToken revokes a bootstrap token so it can no longer be used
+func (s *ClientKeyService) RevokeBootstrapToken(ctx context.Context, id uuid.UUID) error {
+	result, err := s.db.Exec(ctx, "UPDATE auth.client_key_bootstrap_tokens SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL", id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke bootstrap token: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("bootstrap token not found")
+	}
+
+	return nil
+}
+
+// EnrollClientKey exchanges a bootstrap token for a client key. The token is consumed in the
+// same transaction that creates the key, so it can only ever be used once. Scopes default to
+// the token's scopes and may be narrowed, and name is appended to the token's name when set.
+func (s *ClientKeyService) EnrollClientKey(ctx context.Context, plaintextToken, name string, scopes []string, clientIP string) (*ClientKeyWithPlaintext, error) {
+	if !strings.HasPrefix(plaintextToken, "fbb_") {
+		return nil, ErrInvalidBootstrapToken
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	query := `
+		UPDATE auth.client_key_bootstrap_tokens
+		SET used_at = NOW(), used_by_ip = $2
+		WHERE token_hash = $1 AND used_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+		RETURNING ` + bootstrapTokenColumns
+
+	token, err := scanBootstrapToken(tx.QueryRow(ctx, query, hashClientKey(plaintextToken), clientIP))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrInvalidBootstrapToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to use bootstrap token: %w", err)
+	}
+
+	keyScopes, err := enrollmentScopes(token.Scopes, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	keyName := token.Name
+	if name = strings.TrimSpace(name); name != "" {
+		keyName = token.Name + ": " + name
+	}
+	description := "Enrolled with bootstrap token " + token.TokenPrefix
+	var expiresAt *time.Time
+	if token.KeyTTLSeconds != nil {
+		expiry := time.Now().Add(time.Duration(*token.KeyTTLSeconds) * time.Second)
+		expiresAt = &expiry
+	}
+
+	clientKey, err := createClientKey(ctx, tx, keyName, &description, nil, keyScopes, token.RateLimitPerMinute, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE auth.client_key_bootstrap_tokens SET client_key_id = $2 WHERE id = $1", token.ID, clientKey.ID); err != nil {
+		return nil, fmt.Errorf("failed to record enrolled client key: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit enrollment: %w", err)
+	}
+	return clientKey, nil
+}
+
+// enrollmentScopes returns the scopes for an enrolled key: the requested scopes if they are
+// all valid and granted by the token, or the token's scopes when none are requested
+func enrollmentScopes(allowed, requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return allowed, nil
+	}
+
+	var denied []string
+	for _, scope := range requested {
+		if !IsValidScope(scope) || !HasScope(allowed, scope) {
+			denied = append(denied, scope)
+		}
+	}
+	if len(denied) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrBootstrapScopesExceeded, strings.Join(denied, ", "))
+	}
+	return requested, nil
+}
+
+const bootstrapTokenColumns = `id, name, description, token_prefix, scopes, rate_limit_per_minute, key_ttl_seconds,
+		expires_at, created_by, used_at, used_by_ip, client_key_id, revoked_at, created_at`
+
+func scanBootstrapToken(row pgx.Row) (*ClientKeyBootstrapToken, error) {
+	var token ClientKeyBootstrapToken
+	err := row.Scan(
+		&token.ID,
+		&token.Name,
+		&token.Description,
+		&token.TokenPrefix,
+		&token.Scopes,
+		&token.RateLimitPerMinute,
+		&token.KeyTTLSeconds,
+		&token.ExpiresAt,
+		&token.CreatedBy,
+		&token.UsedAt,
+		&token.UsedByIP,
+		&token.ClientKeyID,
+		&token.RevokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	token.Status = bootstrapTokenStatus(&token, time.Now())
+	return &token, nil
+}
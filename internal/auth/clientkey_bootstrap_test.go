@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBootstrapTokenOptions_Validate(t *testing.T) {
+	t.Run("applies defaults", func(t *testing.T) {
+		opts := BootstrapTokenOptions{Name: "worker", Scopes: []string{"read:tables"}}
+		require.NoError(t, opts.Validate())
+		assert.Equal(t, DefaultBootstrapTokenTTL, opts.TTL)
+		assert.Equal(t, 100, opts.RateLimitPerMinute)
+		assert.Zero(t, opts.KeyTTL)
+	})
+
+	tests := []struct {
+		name string
+		opts BootstrapTokenOptions
+		want string
+	}{
+		{name: "missing name", opts: BootstrapTokenOptions{Name: " ", Scopes: []string{"read:tables"}}, want: "name is required"},
+		{name: "missing scopes", opts: BootstrapTokenOptions{Name: "worker"}, want: "invalid scopes"},
+		{name: "unknown scope", opts: BootstrapTokenOptions{Name: "worker", Scopes: []string{"read:nothing"}}, want: "invalid scopes"},
+		{name: "ttl too short", opts: BootstrapTokenOptions{Name: "worker", Scopes: []string{"read:tables"}, TTL: time.Second}, want: "token expiry must be between"},
+		{name: "ttl too long", opts: BootstrapTokenOptions{Name: "worker", Scopes: []string{"read:tables"}, TTL: 8 * 24 * time.Hour}, want: "token expiry must be between"},
+		{name: "negative key ttl", opts: BootstrapTokenOptions{Name: "worker", Scopes: []string{"read:tables"}, KeyTTL: -time.Hour}, want: "key expiry cannot be negative"},
+		{name: "negative rate limit", opts: BootstrapTokenOptions{Name: "worker", Scopes: []string{"read:tables"}, RateLimitPerMinute: -1}, want: "rate limit cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestEnrollmentScopes(t *testing.T) {
+	allowed := []string{"read:tables", "write:tables", "read:storage"}
+
+	scopes, err := enrollmentScopes(allowed, nil)
+	require.NoError(t, err)
+	assert.Equal(t, allowed, scopes, "token scopes are used when none are requested")
+
+	scopes, err = enrollmentScopes(allowed, []string{"read:tables"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"read:tables"}, scopes)
+
+	_, err = enrollmentScopes(allowed, []string{"read:tables", "write:storage", "execute:functions"})
+	assert.ErrorIs(t, err, ErrBootstrapScopesExceeded)
+	assert.Contains(t, err.Error(), "write:storage, execute:functions")
+
+	scopes, err = enrollmentScopes([]string{"*"}, []string{"write:storage"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"write:storage"}, scopes)
+
+	_, err = enrollmentScopes([]string{"*"}, []string{"write:nothing"})
+	assert.ErrorIs(t, err, ErrBootstrapScopesExceeded)
+}
+
+func TestBootstrapTokenStatus(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+
+	assert.Equal(t, "pending", bootstrapTokenStatus(&ClientKeyBootstrapToken{ExpiresAt: now.Add(time.Hour)}, now))
+	assert.Equal(t, "expired", bootstrapTokenStatus(&ClientKeyBootstrapToken{ExpiresAt: past}, now))
+	assert.Equal(t, "used", bootstrapTokenStatus(&ClientKeyBootstrapToken{ExpiresAt: past, UsedAt: &past}, now))
+	assert.Equal(t, "revoked", bootstrapTokenStatus(&ClientKeyBootstrapToken{ExpiresAt: now.Add(time.Hour), RevokedAt: &past}, now))
+}
+
+func TestEnrollClientKey(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	db := getSharedTestDB(t)
+	service := NewClientKeyService(db, nil)
+	ctx := context.Background()
+
+	t.Run("token is exchanged once", func(t *testing.T) {
+		token, err := service.CreateBootstrapToken(ctx, BootstrapTokenOptions{
+			Name:   "test-bootstrap-worker",
+			Scopes: []string{"read:tables", "write:tables"},
+			KeyTTL: 24 * time.Hour,
+		})
+		require.NoError(t, err)
+		assert.Contains(t, token.PlaintextToken, "fbb_")
+		assert.Equal(t, token.PlaintextToken[:12], token.TokenPrefix)
+		assert.Equal(t, "pending", token.Status)
+
+		key, err := service.EnrollClientKey(ctx, token.PlaintextToken, "worker-1", []string{"read:tables"}, "10.0.0.1")
+		require.NoError(t, err)
+		assert.Equal(t, "test-bootstrap-worker: worker-1", key.Name)
+		assert.Equal(t, []string{"read:tables"}, key.Scopes)
+		assert.Nil(t, key.UserID)
+		require.NotNil(t, key.ExpiresAt)
+		assert.WithinDuration(t, time.Now().Add(24*time.Hour), *key.ExpiresAt, time.Minute)
+
+		validated, err := service.ValidateClientKey(ctx, key.PlaintextKey)
+		require.NoError(t, err)
+		assert.Equal(t, key.ID, validated.ID)
+
+		_, err = service.EnrollClientKey(ctx, token.PlaintextToken, "worker-2", nil, "10.0.0.2")
+		assert.ErrorIs(t, err, ErrInvalidBootstrapToken)
+
+		tokens, err := service.ListBootstrapTokens(ctx)
+		require.NoError(t, err)
+		for _, listed := range tokens {
+			if listed.ID == token.ID {
+				assert.Equal(t, "used", listed.Status)
+				require.NotNil(t, listed.ClientKeyID)
+				assert.Equal(t, key.ID, *listed.ClientKeyID)
+				require.NotNil(t, listed.UsedByIP)
+				assert.Equal(t, "10.0.0.1", *listed.UsedByIP)
+			}
+		}
+	})
+
+	t.Run("scope violation does not consume the token", func(t *testing.T) {
+		token, err := service.CreateBootstrapToken(ctx, BootstrapTokenOptions{Name: "test-bootstrap-scoped", Scopes: []string{"read:tables"}})
+		require.NoError(t, err)
+
+		_, err = service.EnrollClientKey(ctx, token.PlaintextToken, "", []string{"write:tables"}, "10.0.0.1")
+		assert.ErrorIs(t, err, ErrBootstrapScopesExceeded)
+
+		key, err := service.EnrollClientKey(ctx, token.PlaintextToken, "", nil, "10.0.0.1")
+		require.NoError(t, err)
+		assert.Equal(t, "test-bootstrap-scoped", key.Name)
+		assert.Equal(t, []string{"read:tables"}, key.Scopes)
+	})
+
+	t.Run("revoked token cannot be used", func(t *testing.T) {
+		token, err := service.CreateBootstrapToken(ctx, BootstrapTokenOptions{Name: "test-bootstrap-revoked", Scopes: []string{"read:tables"}})
+		require.NoError(t, err)
+		require.NoError(t, service.RevokeBootstrapToken(ctx, token.ID))
+
+		_, err = service.EnrollClientKey(ctx, token.PlaintextToken, "", nil, "10.0.0.1")
+		assert.ErrorIs(t, err, ErrInvalidBootstrapToken)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		_, err := service.EnrollClientKey(ctx, "fbb_unknown", "", nil, "10.0.0.1")
+		assert.ErrorIs(t, err, ErrInvalidBootstrapToken)
+
+		_, err = service.EnrollClientKey(ctx, "fbk_not_a_bootstrap_token", "", nil, "10.0.0.1")
+		assert.ErrorIs(t, err, ErrInvalidBootstrapToken)
+	})
+}
@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// Access levels of the tables a client key is restricted to
+const (
+	TableAccessRead  = "read"  // select and query rows
+	TableAccessWrite = "write" // read, insert, update and delete rows
+)
+
+// ErrClientKeyIPNotAllowed is returned when a client key is used from an address outside its allowlist
+var ErrClientKeyIPNotAllowed = errors.New("client key cannot be used from this IP address")
+
+// ClientKeyRestrictions limits what a client key can reach beyond its scopes.
+// A nil or empty restriction allows everything its scopes allow.
+type ClientKeyRestrictions struct {
+	// AllowedTables maps "schema.table" (public when the schema is omitted) or "schema.*" to
+	// the key's access to it, TableAccessRead or TableAccessWrite
+	AllowedTables map[string]string `json:"allowed_tables,omitempty"`
+	// AllowedBuckets lists storage bucket names, or prefixes ending in "*"
+	AllowedBuckets []string `json:"allowed_buckets,omitempty"`
+	// AllowedIPs lists client IP addresses and CIDR ranges
+	AllowedIPs []string `json:"allowed_ips,omitempty"`
+}
+
+// Validate checks the table access levels, bucket patterns and IP ranges of the restrictions
+func (r ClientKeyRestrictions) Validate() error {
+	for table, access := range r.AllowedTables {
+		if table == "" || strings.Count(table, ".") > 1 {
+			return fmt.Errorf("invalid allowed table %q, expected schema.table", table)
+		}
+		if access != TableAccessRead && access != TableAccessWrite {
+			return fmt.Errorf("invalid access %q for table %s, expected %q or %q", access, table, TableAccessRead, TableAccessWrite)
+		}
+	}
+	for _, bucket := range r.AllowedBuckets {
+		if strings.TrimSuffix(bucket, "*") == "" && bucket != "*" {
+			return fmt.Errorf("invalid allowed bucket %q", bucket)
+		}
+	}
+	for _, ip := range r.AllowedIPs {
+		if _, err := parseIPRange(ip); err != nil {
+			return fmt.Errorf("invalid allowed IP %q: expected an IP address or CIDR range", ip)
+		}
+	}
+	return nil
+}
+
+// AllowsTable reports whether the key can read, or with write also modify, schema.table.
+// An exact table entry takes precedence over a schema wildcard.
+func (r ClientKeyRestrictions) AllowsTable(schema, table string, write bool) bool {
+	if len(r.AllowedTables) == 0 {
+		return true
+	}
+
+	access, ok := r.AllowedTables[schema+"."+table]
+	if !ok && schema == "public" {
+		access, ok = r.AllowedTables[table]
+	}
+	if !ok {
+		access, ok = r.AllowedTables[schema+".*"]
+	}
+	if !ok {
+		return false
+	}
+	return !write || access == TableAccessWrite
+}
+
+// AllowsBucket reports whether the key can access a storage bucket
+func (r ClientKeyRestrictions) AllowsBucket(bucket string) bool {
+	if len(r.AllowedBuckets) == 0 {
+		return true
+	}
+	for _, pattern := range r.AllowedBuckets {
+		if MatchNamespacePattern(bucket, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsIP reports whether the key can be used from a client IP address
+func (r ClientKeyRestrictions) AllowsIP(ip string) bool {
+	if len(r.AllowedIPs) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, allowed := range r.AllowedIPs {
+		if prefix, err := parseIPRange(allowed); err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIPRange parses a CIDR range, or a single address as a range of one
+func parseIPRange(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientKeyRestrictions_Validate(t *testing.T) {
+	valid := ClientKeyRestrictions{
+		AllowedTables:  map[string]string{"posts": TableAccessRead, "analytics.*": TableAccessWrite},
+		AllowedBuckets: []string{"avatars", "exports-*", "*"},
+		AllowedIPs:     []string{"203.0.113.7", "10.0.0.0/8", "2001:db8::/32"},
+	}
+	assert.NoError(t, valid.Validate())
+	assert.NoError(t, ClientKeyRestrictions{}.Validate())
+
+	for name, r := range map[string]ClientKeyRestrictions{
+		"unknown access":    {AllowedTables: map[string]string{"posts": "delete"}},
+		"empty table":       {AllowedTables: map[string]string{"": TableAccessRead}},
+		"nested table name": {AllowedTables: map[string]string{"a.b.c": TableAccessRead}},
+		"empty bucket":      {AllowedBuckets: []string{""}},
+		"invalid IP":        {AllowedIPs: []string{"10.0.0"}},
+		"invalid CIDR":      {AllowedIPs: []string{"10.0.0.0/40"}},
+	} {
+		assert.Error(t, r.Validate(), name)
+	}
+}
+
+func TestClientKeyRestrictions_AllowsTable(t *testing.T) {
+	r := ClientKeyRestrictions{AllowedTables: map[string]string{
+		"posts":           TableAccessWrite,
+		"public.comments": TableAccessRead,
+		"analytics.*":     TableAccessRead,
+		"analytics.raw":   TableAccessWrite,
+	}}
+
+	tests := []struct {
+		schema, table string
+		write         bool
+		want          bool
+	}{
+		{"public", "posts", true, true},
+		{"public", "comments", false, true},
+		{"public", "comments", true, false},
+		{"public", "users", false, false},
+		{"other", "posts", false, false},
+		{"analytics", "events", false, true},
+		{"analytics", "events", true, false},
+		{"analytics", "raw", true, true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, r.AllowsTable(tt.schema, tt.table, tt.write), "%s.%s write=%v", tt.schema, tt.table, tt.write)
+	}
+
+	assert.True(t, ClientKeyRestrictions{}.AllowsTable("auth", "users", true), "no restriction allows every table")
+}
+
+func TestClientKeyRestrictions_AllowsBucket(t *testing.T) {
+	r := ClientKeyRestrictions{AllowedBuckets: []string{"avatars", "exports-*"}}
+
+	assert.True(t, r.AllowsBucket("avatars"))
+	assert.True(t, r.AllowsBucket("exports-2026"))
+	assert.False(t, r.AllowsBucket("avatars-private"))
+	assert.False(t, r.AllowsBucket("invoices"))
+	assert.True(t, ClientKeyRestrictions{}.AllowsBucket("invoices"))
+}
+
+func TestClientKeyRestrictions_AllowsIP(t *testing.T) {
+	r := ClientKeyRestrictions{AllowedIPs: []string{"203.0.113.7", "10.0.0.0/8", "2001:db8::/32"}}
+
+	assert.True(t, r.AllowsIP("203.0.113.7"))
+	assert.False(t, r.AllowsIP("203.0.113.8"))
+	assert.True(t, r.AllowsIP("10.20.30.40"))
+	assert.True(t, r.AllowsIP("::ffff:10.1.2.3"), "IPv4-mapped addresses match IPv4 ranges")
+	assert.True(t, r.AllowsIP("2001:db8::1"))
+	assert.False(t, r.AllowsIP("2001:db9::1"))
+	assert.False(t, r.AllowsIP("not-an-ip"))
+	assert.True(t, ClientKeyRestrictions{}.AllowsIP("198.51.100.1"))
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// s3AccessKeyPrefix marks access key IDs derived from client keys
+const s3AccessKeyPrefix = "FBK"
+
+// ErrInvalidS3AccessKey is returned for an access key ID that isn't derived from a client key
+var ErrInvalidS3AccessKey = errors.New("invalid S3 access key")
+
+// S3Credentials are the S3 access key pair of a client key
+type S3Credentials struct {
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+}
+
+// S3AccessKeyID returns the S3 access key ID of a client key, FBK followed by its ID in
+// upper-case hex
+func S3AccessKeyID(clientKeyID uuid.UUID) string {
+	return s3AccessKeyPrefix + strings.ToUpper(hex.EncodeToString(clientKeyID[:]))
+}
+
+// ParseS3AccessKeyID returns the ID of the client key an S3 access key ID was derived from
+func ParseS3AccessKeyID(accessKeyID string) (uuid.UUID, error) {
+	encoded, ok := strings.CutPrefix(accessKeyID, s3AccessKeyPrefix)
+	if !ok || len(encoded) != 32 {
+		return uuid.Nil, ErrInvalidS3AccessKey
+	}
+	raw, err := hex.DecodeString(encoded)
+	if err != nil {
+		return uuid.Nil, ErrInvalidS3AccessKey
+	}
+	return uuid.FromBytes(raw)
+}
+
+// DeriveS3Credentials returns the S3 access key pair of a client key.
+// Client keys are only stored hashed, so the secret access key is derived from the key's
+// hash with the server's signing secret: it is never stored, a database dump alone doesn't
+// reveal it, and revoking the client key or rotating the signing secret invalidates it.
+func DeriveS3Credentials(signingSecret string, key *ClientKey) S3Credentials {
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	mac.Write([]byte("s3-secret-access-key:" + key.KeyHash))
+
+	return S3Credentials{
+		AccessKeyID:     S3AccessKeyID(key.ID),
+		SecretAccessKey: base64.RawURLEncoding.EncodeToString(mac.Sum(nil)),
+	}
+}
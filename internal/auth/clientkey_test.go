@@ -76,6 +76,7 @@ func TestMain(m *testing.M) {
 
 	// Clean up test data before closing
 	ctx := context.Background()
+	_, _ = sharedTestDB.Exec(ctx, "DELETE FROM auth.client_key_bootstrap_tokens WHERE name LIKE 'test-%'")
 	_, _ = sharedTestDB.Exec(ctx, "DELETE FROM auth.client_keys WHERE name LIKE 'test-%'")
 	_, _ = sharedTestDB.Exec(ctx, "DELETE FROM auth.users WHERE email LIKE '%@example.com'")
 
@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/database"
+)
+
+// Email normalization
+//
+// Some mail providers deliver several spellings of an address to the same mailbox:
+// Gmail ignores dots in the local part, and most large providers ignore a "+tag" suffix.
+// NormalizeEmail maps every such alias to one canonical address so signup can reject an
+// alias of an existing account and sign-in can accept one.
+//
+// The rules must stay in sync with auth.normalize_email() in migration
+// 109_email_normalization, which fills the auth.users.email_normalized column.
+
+// googleMailDomains are Gmail domains; dots in the local part are ignored and
+// googlemail.com is an alias of gmail.com.
+var googleMailDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+}
+
+// plusAddressingDomains are providers that ignore a "+tag" suffix in the local part.
+var plusAddressingDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+	"outlook.com":    true,
+	"hotmail.com":    true,
+	"live.com":       true,
+	"msn.com":        true,
+	"icloud.com":     true,
+	"me.com":         true,
+	"mac.com":        true,
+	"protonmail.com": true,
+	"proton.me":      true,
+	"pm.me":          true,
+	"fastmail.com":   true,
+}
+
+// NormalizeEmail returns the canonical form of an email address: lowercased, with the
+// "+tag" suffix removed for providers that support plus addressing, and with dots removed
+// and the domain set to gmail.com for Gmail addresses. Addresses at other domains are only
+// lowercased, since their providers may treat dots and "+" as significant.
+func NormalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	at := strings.LastIndex(email, "@")
+	if at <= 0 || at == len(email)-1 {
+		return email
+	}
+	local, domain := email[:at], email[at+1:]
+
+	if plusAddressingDomains[domain] {
+		if plus := strings.Index(local, "+"); plus > 0 {
+			local = local[:plus]
+		}
+	}
+
+	if googleMailDomains[domain] {
+		local = strings.ReplaceAll(local, ".", "")
+		domain = "gmail.com"
+	}
+
+	return local + "@" + domain
+}
+
+// isEmailNormalizationEnabled reports whether email aliases are treated as one account.
+// The app.auth.email_normalization_enabled setting overrides the config default.
+func (s *Service) isEmailNormalizationEnabled(ctx context.Context) bool {
+	if s.config == nil {
+		return false
+	}
+	if s.settingsCache == nil {
+		return s.config.EmailNormalization
+	}
+	return s.settingsCache.GetBool(ctx, "app.auth.email_normalization_enabled", s.config.EmailNormalization)
+}
+
+// getUserByEmailAlias returns the user whose address is an alias of email. Pre-existing
+// duplicates are ambiguous, so ErrUserNotFound is returned unless exactly one user matches.
+func (s *Service) getUserByEmailAlias(ctx context.Context, email string) (*User, error) {
+	users, err := s.userRepo.ListByNormalizedEmail(ctx, NormalizeEmail(email))
+	if err != nil {
+		return nil, err
+	}
+	if len(users) != 1 {
+		return nil, ErrUserNotFound
+	}
+	return users[0], nil
+}
+
+// DuplicateEmailGroup is a set of users whose addresses normalize to the same email
+type DuplicateEmailGroup struct {
+	NormalizedEmail string                `json:"normalized_email"`
+	Users           []*DuplicateEmailUser `json:"users"`
+}
+
+// DuplicateEmailUser is a user in a DuplicateEmailGroup
+type DuplicateEmailUser struct {
+	ID            string    `json:"id"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ListByNormalizedEmail returns the users whose email normalizes to normalizedEmail,
+// oldest first. More than one user is returned only for accounts created before
+// normalization was enabled.
+func (r *UserRepository) ListByNormalizedEmail(ctx context.Context, normalizedEmail string) ([]*User, error) {
+	query := `
+		SELECT id, email, COALESCE(password_hash, ''), email_verified, role, user_metadata, app_metadata,
+		       COALESCE(failed_login_attempts, 0), COALESCE(is_locked, false), locked_until,
+		       created_at, updated_at
+		FROM auth.users
+		WHERE email_normalized = $1
+		ORDER BY created_at
+	`
+
+	users := []*User{}
+	err := database.WrapWithServiceRole(ctx, r.db, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, query, normalizedEmail)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			user := &User{}
+			if err := rows.Scan(
+				&user.ID,
+				&user.Email,
+				&user.PasswordHash,
+				&user.EmailVerified,
+				&user.Role,
+				&user.UserMetadata,
+				&user.AppMetadata,
+				&user.FailedLoginAttempts,
+				&user.IsLocked,
+				&user.LockedUntil,
+				&user.CreatedAt,
+				&user.UpdatedAt,
+			); err != nil {
+				return err
+			}
+			users = append(users, user)
+		}
+
+		return rows.Err()
+	})
+
+	return users, err
+}
+
+// FindDuplicateEmails returns groups of existing users whose addresses are aliases of each other
+func (r *UserRepository) FindDuplicateEmails(ctx context.Context) ([]*DuplicateEmailGroup, error) {
+	query := `
+		SELECT email_normalized, id, email, email_verified, created_at
+		FROM auth.users
+		WHERE email_normalized IN (
+			SELECT email_normalized
+			FROM auth.users
+			GROUP BY email_normalized
+			HAVING COUNT(*) > 1
+		)
+		ORDER BY email_normalized, created_at
+	`
+
+	groups := []*DuplicateEmailGroup{}
+	err := database.WrapWithServiceRole(ctx, r.db, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		var current *DuplicateEmailGroup
+		for rows.Next() {
+			var normalized string
+			user := &DuplicateEmailUser{}
+			if err := rows.Scan(&normalized, &user.ID, &user.Email, &user.EmailVerified, &user.CreatedAt); err != nil {
+				return err
+			}
+			if current == nil || current.NormalizedEmail != normalized {
+				current = &DuplicateEmailGroup{NormalizedEmail: normalized}
+				groups = append(groups, current)
+			}
+			current.Users = append(current.Users, user)
+		}
+
+		return rows.Err()
+	})
+
+	return groups, err
+}
+
+// FindDuplicateEmails returns groups of app users whose addresses are aliases of each other,
+// so admins can merge or remove accounts created before email normalization was enabled
+func (s *UserManagementService) FindDuplicateEmails(ctx context.Context) ([]*DuplicateEmailGroup, error) {
+	return s.userRepo.FindDuplicateEmails(ctx)
+}
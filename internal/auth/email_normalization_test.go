@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nimbleflux/fluxbase/internal/config"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		name  string
+		email string
+		want  string
+	}{
+		{name: "lowercases", email: "User@Example.COM", want: "user@example.com"},
+		{name: "trims whitespace", email: "  user@example.com ", want: "user@example.com"},
+		{name: "gmail removes dots", email: "jane.doe@gmail.com", want: "janedoe@gmail.com"},
+		{name: "gmail strips plus tag", email: "jane.doe+promo@gmail.com", want: "janedoe@gmail.com"},
+		{name: "googlemail is gmail", email: "Jane.Doe@googlemail.com", want: "janedoe@gmail.com"},
+		{name: "outlook strips plus tag", email: "jane.doe+news@outlook.com", want: "jane.doe@outlook.com"},
+		{name: "icloud strips plus tag", email: "jane+a+b@icloud.com", want: "jane@icloud.com"},
+		{name: "other domains keep plus tag", email: "user+tag@example.com", want: "user+tag@example.com"},
+		{name: "other domains keep dots", email: "first.last@example.com", want: "first.last@example.com"},
+		{name: "leading plus is kept", email: "+tag@gmail.com", want: "+tag@gmail.com"},
+		{name: "splits at last at sign", email: `"a@b"+x@gmail.com`, want: `"a@b"@gmail.com`},
+		{name: "no at sign", email: "Not-An-Email", want: "not-an-email"},
+		{name: "missing domain", email: "user@", want: "user@"},
+		{name: "missing local part", email: "@gmail.com", want: "@gmail.com"},
+		{name: "empty", email: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormalizeEmail(tt.email))
+		})
+	}
+}
+
+func TestNormalizeEmail_Idempotent(t *testing.T) {
+	for _, email := range []string{"Jane.Doe+promo@GoogleMail.com", "jane+x@outlook.com", "User@Example.com"} {
+		once := NormalizeEmail(email)
+		assert.Equal(t, once, NormalizeEmail(once), email)
+	}
+}
+
+func TestService_isEmailNormalizationEnabled(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("disabled without config", func(t *testing.T) {
+		assert.False(t, (&Service{}).isEmailNormalizationEnabled(ctx))
+	})
+
+	t.Run("uses config without settings cache", func(t *testing.T) {
+		assert.True(t, (&Service{config: &config.AuthConfig{EmailNormalization: true}}).isEmailNormalizationEnabled(ctx))
+		assert.False(t, (&Service{config: &config.AuthConfig{EmailNormalization: false}}).isEmailNormalizationEnabled(ctx))
+	})
+
+	t.Run("environment override wins", func(t *testing.T) {
+		t.Setenv("FLUXBASE_AUTH_EMAIL_NORMALIZATION_ENABLED", "false")
+		svc := &Service{
+			config:        &config.AuthConfig{EmailNormalization: true},
+			settingsCache: NewSettingsCache(nil, 0),
+		}
+		assert.False(t, svc.isEmailNormalizationEnabled(ctx))
+	})
+}
@@ -0,0 +1,266 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/database"
+)
+
+var (
+	// ErrGroupNotFound is returned when a group is not found
+	ErrGroupNotFound = errors.New("group not found")
+	// ErrGroupNameTaken is returned when a group with the same name already exists
+	ErrGroupNameTaken = errors.New("group name already exists")
+	// ErrGroupNameRequired is returned when a group is created or renamed without a name
+	ErrGroupNameRequired = errors.New("group name is required")
+	// ErrGroupUserNotFound is returned when a user added to a group does not exist
+	ErrGroupUserNotFound = errors.New("user not found")
+	// ErrGroupMemberNotFound is returned when a user is not a member of the group
+	ErrGroupMemberNotFound = errors.New("user is not a member of the group")
+)
+
+// Group is a named set of users. Knowledge base and document permissions can be
+// granted to a group, and apply to every member at access-check time.
+type Group struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description,omitempty"`
+	CreatedBy   *string   `json:"created_by,omitempty"`
+	MemberCount int       `json:"member_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// GroupMember is a user that belongs to a group
+type GroupMember struct {
+	GroupID string    `json:"group_id"`
+	UserID  string    `json:"user_id"`
+	Email   string    `json:"email"`
+	AddedBy *string   `json:"added_by,omitempty"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// GroupService manages user groups and their members
+type GroupService struct {
+	db *database.Connection
+}
+
+// NewGroupService creates a new group service
+func NewGroupService(db *database.Connection) *GroupService {
+	return &GroupService{db: db}
+}
+
+const groupColumns = `
+	g.id, g.name, g.description, g.created_by,
+	(SELECT COUNT(*) FROM auth.group_members gm WHERE gm.group_id = g.id),
+	g.created_at, g.updated_at`
+
+func scanGroup(row pgx.Row) (*Group, error) {
+	var group Group
+	err := row.Scan(
+		&group.ID, &group.Name, &group.Description, &group.CreatedBy,
+		&group.MemberCount, &group.CreatedAt, &group.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// CreateGroup creates a new group
+func (s *GroupService) CreateGroup(ctx context.Context, name string, description *string, createdBy *string) (*Group, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrGroupNameRequired
+	}
+
+	group, err := scanGroup(s.db.QueryRow(ctx, `
+		WITH g AS (
+			INSERT INTO auth.groups (name, description, created_by)
+			VALUES ($1, $2, $3)
+			RETURNING *
+		)
+		SELECT `+groupColumns+` FROM g
+	`, name, description, createdBy))
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, ErrGroupNameTaken
+		}
+		return nil, fmt.Errorf("failed to create group: %w", err)
+	}
+	return group, nil
+}
+
+// GetGroup returns a group by ID
+func (s *GroupService) GetGroup(ctx context.Context, id string) (*Group, error) {
+	group, err := scanGroup(s.db.QueryRow(ctx, `
+		SELECT `+groupColumns+` FROM auth.groups g WHERE g.id = $1
+	`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrGroupNotFound
+		}
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+	return group, nil
+}
+
+// ListGroups returns all groups ordered by name
+func (s *GroupService) ListGroups(ctx context.Context) ([]*Group, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT `+groupColumns+` FROM auth.groups g ORDER BY g.name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []*Group{}
+	for rows.Next() {
+		group, err := scanGroup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+	return groups, rows.Err()
+}
+
+// UpdateGroup updates the name and description of a group. Nil fields are left unchanged.
+func (s *GroupService) UpdateGroup(ctx context.Context, id string, name *string, description *string) (*Group, error) {
+	if name != nil {
+		trimmed := strings.TrimSpace(*name)
+		if trimmed == "" {
+			return nil, ErrGroupNameRequired
+		}
+		name = &trimmed
+	}
+
+	group, err := scanGroup(s.db.QueryRow(ctx, `
+		WITH g AS (
+			UPDATE auth.groups SET
+				name = COALESCE($2, name),
+				description = COALESCE($3, description),
+				updated_at = NOW()
+			WHERE id = $1
+			RETURNING *
+		)
+		SELECT `+groupColumns+` FROM g
+	`, id, name, description))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrGroupNotFound
+		}
+		if database.IsUniqueViolation(err) {
+			return nil, ErrGroupNameTaken
+		}
+		return nil, fmt.Errorf("failed to update group: %w", err)
+	}
+	return group, nil
+}
+
+// DeleteGroup deletes a group. Its memberships and permission grants are removed with it.
+func (s *GroupService) DeleteGroup(ctx context.Context, id string) error {
+	result, err := s.db.Exec(ctx, `DELETE FROM auth.groups WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrGroupNotFound
+	}
+	return nil
+}
+
+// ListMembers returns the members of a group ordered by email
+func (s *GroupService) ListMembers(ctx context.Context, groupID string) ([]*GroupMember, error) {
+	if _, err := s.GetGroup(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT gm.group_id, gm.user_id, u.email, gm.added_by, gm.added_at
+		FROM auth.group_members gm
+		JOIN auth.users u ON u.id = gm.user_id
+		WHERE gm.group_id = $1
+		ORDER BY u.email
+	`, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list group members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []*GroupMember{}
+	for rows.Next() {
+		var member GroupMember
+		if err := rows.Scan(&member.GroupID, &member.UserID, &member.Email, &member.AddedBy, &member.AddedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan group member: %w", err)
+		}
+		members = append(members, &member)
+	}
+	return members, rows.Err()
+}
+
+// AddMembers adds users to a group. Users that are already members are skipped.
+// Returns the number of users added.
+func (s *GroupService) AddMembers(ctx context.Context, groupID string, userIDs []string, addedBy *string) (int, error) {
+	if _, err := s.GetGroup(ctx, groupID); err != nil {
+		return 0, err
+	}
+
+	result, err := s.db.Exec(ctx, `
+		INSERT INTO auth.group_members (group_id, user_id, added_by)
+		SELECT $1, user_id, $3 FROM unnest($2::uuid[]) AS user_id
+		ON CONFLICT (group_id, user_id) DO NOTHING
+	`, groupID, userIDs, addedBy)
+	if err != nil {
+		if database.IsForeignKeyViolation(err) {
+			return 0, ErrGroupUserNotFound
+		}
+		return 0, fmt.Errorf("failed to add group members: %w", err)
+	}
+	return int(result.RowsAffected()), nil
+}
+
+// RemoveMember removes a user from a group
+func (s *GroupService) RemoveMember(ctx context.Context, groupID, userID string) error {
+	result, err := s.db.Exec(ctx, `
+		DELETE FROM auth.group_members WHERE group_id = $1 AND user_id = $2
+	`, groupID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove group member: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrGroupMemberNotFound
+	}
+	return nil
+}
+
+// ListUserGroups returns the groups a user belongs to
+func (s *GroupService) ListUserGroups(ctx context.Context, userID string) ([]*Group, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT `+groupColumns+`
+		FROM auth.groups g
+		JOIN auth.group_members m ON m.group_id = g.id
+		WHERE m.user_id = $1
+		ORDER BY g.name
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user groups: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []*Group{}
+	for rows.Next() {
+		group, err := scanGroup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+	return groups, rows.Err()
+}
@@ -0,0 +1,308 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/crypto"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PublicBaseURLSettingKey stores the public base URL configured during instance bootstrap
+const PublicBaseURLSettingKey = "app.public_base_url"
+
+// ErrSetupAlreadyCompleted is returned when bootstrapping an instance that has already been set up
+var ErrSetupAlreadyCompleted = errors.New("setup has already been completed")
+
+// AnonKeyScopes are the scopes of the anon key created during instance bootstrap.
+// The key has no user, so every request made with it is subject to RLS as an anonymous user.
+var AnonKeyScopes = []string{
+	ScopeTablesRead,
+	ScopeTablesWrite,
+	ScopeStorageRead,
+	ScopeStorageWrite,
+	ScopeFunctionsExecute,
+	ScopeRPCExecute,
+	ScopeRealtimeConnect,
+}
+
+// BootstrapSMTPOptions configures SMTP email delivery during instance bootstrap
+type BootstrapSMTPOptions struct {
+	Host        string `json:"host"`
+	Port        int    `json:"port"`
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	TLS         *bool  `json:"tls"`
+	FromAddress string `json:"from_address"`
+	FromName    string `json:"from_name"`
+}
+
+// InstanceBootstrapOptions configures a fresh instance
+type InstanceBootstrapOptions struct {
+	AdminEmail    string
+	AdminPassword string
+	AdminName     string
+	PublicBaseURL string
+	SMTP          *BootstrapSMTPOptions
+}
+
+// InstanceBootstrapResult is the outcome of an instance bootstrap.
+// The keys are only returned here and can't be retrieved again.
+type InstanceBootstrapResult struct {
+	Admin        *DashboardUser
+	ServiceKeyID uuid.UUID
+	ServiceKey   string
+	AnonKey      *ClientKeyWithPlaintext
+}
+
+// Validate checks the options and fills in defaults
+func (o *InstanceBootstrapOptions) Validate() error {
+	o.AdminEmail = strings.TrimSpace(o.AdminEmail)
+	if err := ValidateEmail(o.AdminEmail); err != nil {
+		return fmt.Errorf("invalid admin email: %w", err)
+	}
+	if err := ValidateName(o.AdminName); err != nil {
+		return fmt.Errorf("invalid admin name: %w", err)
+	}
+	if err := ValidateDashboardPassword(o.AdminPassword); err != nil {
+		return err
+	}
+
+	if o.PublicBaseURL != "" {
+		o.PublicBaseURL = strings.TrimSuffix(strings.TrimSpace(o.PublicBaseURL), "/")
+		parsed, err := url.Parse(o.PublicBaseURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return errors.New("public base URL must be an absolute http or https URL")
+		}
+		if parsed.RawQuery != "" || parsed.Fragment != "" {
+			return errors.New("public base URL must not have a query or fragment")
+		}
+	}
+
+	if o.SMTP != nil {
+		smtp := o.SMTP
+		smtp.Host = strings.TrimSpace(smtp.Host)
+		if smtp.Host == "" {
+			return errors.New("SMTP host is required")
+		}
+		if smtp.Port == 0 {
+			smtp.Port = 587
+		}
+		if smtp.Port < 1 || smtp.Port > 65535 {
+			return errors.New("SMTP port must be between 1 and 65535")
+		}
+		if smtp.TLS == nil {
+			enabled := true
+			smtp.TLS = &enabled
+		}
+		if err := ValidateEmail(smtp.FromAddress); err != nil {
+			return fmt.Errorf("invalid SMTP from address: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// settingValues returns the plain settings written during bootstrap, keyed by setting key
+func (o *InstanceBootstrapOptions) settingValues() map[string]interface{} {
+	values := make(map[string]interface{})
+	if o.PublicBaseURL != "" {
+		values[PublicBaseURLSettingKey] = o.PublicBaseURL
+	}
+	if o.SMTP != nil {
+		values["app.email.enabled"] = true
+		values["app.email.provider"] = "smtp"
+		values["app.email.from_address"] = o.SMTP.FromAddress
+		values["app.email.from_name"] = o.SMTP.FromName
+		values["app.email.smtp_host"] = o.SMTP.Host
+		values["app.email.smtp_port"] = o.SMTP.Port
+		values["app.email.smtp_username"] = o.SMTP.Username
+		values["app.email.smtp_tls"] = *o.SMTP.TLS
+	}
+	return values
+}
+
+// SettingKeys returns the keys of the settings written during bootstrap
+func (o *InstanceBootstrapOptions) SettingKeys() []string {
+	values := o.settingValues()
+	keys := make([]string, 0, len(values)+1)
+	for key := range values {
+		keys = append(keys, key)
+	}
+	if o.SMTP != nil && o.SMTP.Password != "" {
+		keys = append(keys, "app.email.smtp_password")
+	}
+	return keys
+}
+
+// BootstrapInstance sets up a fresh instance in a single transaction: it creates the first
+// dashboard admin, a service key and an anon key, stores the public base URL and SMTP settings,
+// and marks setup as complete so neither this nor the dashboard setup can run again.
+// The SMTP password is encrypted with encryptionKey like other secret settings.
+func (s *SystemSettingsService) BootstrapInstance(ctx context.Context, opts InstanceBootstrapOptions, encryptionKey string) (*InstanceBootstrapResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(opts.AdminPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	var encryptedSMTPPassword string
+	if opts.SMTP != nil && opts.SMTP.Password != "" {
+		encryptedSMTPPassword, err = crypto.Encrypt(opts.SMTP.Password, encryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt SMTP password: %w", err)
+		}
+	}
+
+	result := &InstanceBootstrapResult{Admin: &DashboardUser{}}
+	err = database.WrapWithServiceRole(ctx, s.db, func(tx pgx.Tx) error {
+		// Serialize concurrent bootstraps so only the first one can pass the check below
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('fluxbase_instance_bootstrap'))`); err != nil {
+			return fmt.Errorf("failed to lock bootstrap: %w", err)
+		}
+
+		var complete bool
+		if err := tx.QueryRow(ctx, `
+			SELECT EXISTS(SELECT 1 FROM app.settings WHERE key = 'setup_completed')
+		`).Scan(&complete); err != nil {
+			return fmt.Errorf("failed to check setup status: %w", err)
+		}
+		if complete {
+			return ErrSetupAlreadyCompleted
+		}
+
+		admin := result.Admin
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO dashboard.users (email, password_hash, full_name, email_verified, email_verified_at, role)
+			VALUES ($1, $2, $3, true, NOW(), 'dashboard_admin')
+			RETURNING id, email, email_verified, full_name, avatar_url, totp_enabled,
+			          is_active, is_locked, last_login_at, created_at, updated_at, role
+		`, opts.AdminEmail, passwordHash, opts.AdminName).Scan(
+			&admin.ID, &admin.Email, &admin.EmailVerified, &admin.FullName, &admin.AvatarURL,
+			&admin.TOTPEnabled, &admin.IsActive, &admin.IsLocked, &admin.LastLoginAt,
+			&admin.CreatedAt, &admin.UpdatedAt, &admin.Role,
+		); err != nil {
+			return fmt.Errorf("failed to create admin user: %w", err)
+		}
+
+		serviceKeyID, serviceKey, err := createBootstrapServiceKey(ctx, tx)
+		if err != nil {
+			return err
+		}
+		result.ServiceKeyID = serviceKeyID
+		result.ServiceKey = serviceKey
+
+		description := "Public key for client applications, created during instance bootstrap"
+		result.AnonKey, err = createClientKey(ctx, tx, "anon", &description, nil, AnonKeyScopes, 0, nil)
+		if err != nil {
+			return err
+		}
+
+		for key, value := range opts.settingValues() {
+			valueJSON, err := json.Marshal(map[string]interface{}{"value": value})
+			if err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO app.settings (key, value, description, category)
+				VALUES ($1, $2, '', 'system')
+				ON CONFLICT (key) WHERE user_id IS NULL DO UPDATE
+				SET value = EXCLUDED.value, updated_at = NOW()
+			`, key, valueJSON); err != nil {
+				return fmt.Errorf("failed to store setting %s: %w", key, err)
+			}
+		}
+
+		if encryptedSMTPPassword != "" {
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO app.settings (key, value, description, is_secret, encrypted_value, user_id, created_at, updated_at)
+				VALUES ('app.email.smtp_password', '{"value": "[ENCRYPTED]"}', 'Email provider secret', true, $1, NULL, NOW(), NOW())
+				ON CONFLICT (key, COALESCE(user_id, '00000000-0000-0000-0000-000000000000'::UUID))
+				DO UPDATE SET encrypted_value = EXCLUDED.encrypted_value, updated_at = NOW()
+			`, encryptedSMTPPassword); err != nil {
+				return fmt.Errorf("failed to store SMTP password: %w", err)
+			}
+		}
+
+		setupJSON, err := json.Marshal(SetupCompleteValue{
+			Completed:       true,
+			CompletedAt:     admin.CreatedAt,
+			FirstAdminID:    &admin.ID,
+			FirstAdminEmail: &admin.Email,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO app.settings (key, value, description, category)
+			VALUES ('setup_completed', $1, 'Tracks initial setup completion', 'system')
+		`, setupJSON); err != nil {
+			return fmt.Errorf("failed to mark setup as complete: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cache != nil {
+		for _, key := range opts.SettingKeys() {
+			s.cache.Invalidate(key)
+		}
+	}
+
+	return result, nil
+}
+
+// createBootstrapServiceKey creates a service key with full access, hashed like the ones
+// created through the service key API
+func createBootstrapServiceKey(ctx context.Context, tx pgx.Tx) (uuid.UUID, string, error) {
+	keyBytes := make([]byte, 32)
+	if _, err := rand.Read(keyBytes); err != nil {
+		return uuid.Nil, "", fmt.Errorf("failed to generate service key: %w", err)
+	}
+	plainKey := "sk_" + base64.RawURLEncoding.EncodeToString(keyBytes)
+
+	keyHash, err := bcrypt.GenerateFromPassword([]byte(plainKey), bcrypt.DefaultCost)
+	if err != nil {
+		return uuid.Nil, "", fmt.Errorf("failed to hash service key: %w", err)
+	}
+
+	var id uuid.UUID
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO auth.service_keys (name, description, key_hash, key_prefix, scopes, enabled)
+		VALUES ('bootstrap', 'Service key created during instance bootstrap', $1, $2, ARRAY['*'], true)
+		RETURNING id
+	`, string(keyHash), plainKey[:16]).Scan(&id); err != nil {
+		return uuid.Nil, "", fmt.Errorf("failed to create service key: %w", err)
+	}
+
+	return id, plainKey, nil
+}
+
+// GetPublicBaseURL returns the public base URL stored during instance bootstrap, or an
+// empty string when none was stored
+func (s *SystemSettingsService) GetPublicBaseURL(ctx context.Context) (string, error) {
+	setting, err := s.GetSetting(ctx, PublicBaseURLSettingKey)
+	if err != nil {
+		if errors.Is(err, ErrSettingNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	value, _ := setting.Value["value"].(string)
+	return value, nil
+}
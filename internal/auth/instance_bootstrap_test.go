@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validBootstrapOptions() InstanceBootstrapOptions {
+	return InstanceBootstrapOptions{
+		AdminEmail:    "admin@example.com",
+		AdminPassword: "Sup3r-Secret-Passw0rd!",
+		AdminName:     "Admin",
+	}
+}
+
+func TestInstanceBootstrapOptions_Validate(t *testing.T) {
+	t.Run("admin only", func(t *testing.T) {
+		opts := validBootstrapOptions()
+		require.NoError(t, opts.Validate())
+		assert.Empty(t, opts.SettingKeys())
+	})
+
+	t.Run("normalizes public base URL", func(t *testing.T) {
+		opts := validBootstrapOptions()
+		opts.PublicBaseURL = " https://db.example.com/ "
+		require.NoError(t, opts.Validate())
+		assert.Equal(t, "https://db.example.com", opts.PublicBaseURL)
+		assert.Equal(t, []string{PublicBaseURLSettingKey}, opts.SettingKeys())
+	})
+
+	t.Run("fills SMTP defaults", func(t *testing.T) {
+		opts := validBootstrapOptions()
+		opts.SMTP = &BootstrapSMTPOptions{Host: "smtp.example.com", FromAddress: "noreply@example.com", Password: "secret"}
+		require.NoError(t, opts.Validate())
+		assert.Equal(t, 587, opts.SMTP.Port)
+		require.NotNil(t, opts.SMTP.TLS)
+		assert.True(t, *opts.SMTP.TLS)
+
+		assert.ElementsMatch(t, []string{
+			"app.email.enabled",
+			"app.email.provider",
+			"app.email.from_address",
+			"app.email.from_name",
+			"app.email.smtp_host",
+			"app.email.smtp_port",
+			"app.email.smtp_username",
+			"app.email.smtp_tls",
+			"app.email.smtp_password",
+		}, opts.SettingKeys())
+	})
+
+	t.Run("keeps TLS disabled when requested", func(t *testing.T) {
+		disabled := false
+		opts := validBootstrapOptions()
+		opts.SMTP = &BootstrapSMTPOptions{Host: "mailpit", Port: 1025, TLS: &disabled, FromAddress: "noreply@example.com"}
+		require.NoError(t, opts.Validate())
+		assert.False(t, opts.settingValues()["app.email.smtp_tls"].(bool))
+		assert.NotContains(t, opts.SettingKeys(), "app.email.smtp_password")
+	})
+
+	invalid := []struct {
+		name   string
+		modify func(*InstanceBootstrapOptions)
+	}{
+		{"invalid admin email", func(o *InstanceBootstrapOptions) { o.AdminEmail = "not-an-email" }},
+		{"missing admin name", func(o *InstanceBootstrapOptions) { o.AdminName = "" }},
+		{"weak password", func(o *InstanceBootstrapOptions) { o.AdminPassword = "short" }},
+		{"relative base URL", func(o *InstanceBootstrapOptions) { o.PublicBaseURL = "db.example.com" }},
+		{"base URL with unsupported scheme", func(o *InstanceBootstrapOptions) { o.PublicBaseURL = "ftp://db.example.com" }},
+		{"base URL with query", func(o *InstanceBootstrapOptions) { o.PublicBaseURL = "https://db.example.com?x=1" }},
+		{"SMTP without host", func(o *InstanceBootstrapOptions) {
+			o.SMTP = &BootstrapSMTPOptions{FromAddress: "noreply@example.com"}
+		}},
+		{"SMTP port out of range", func(o *InstanceBootstrapOptions) {
+			o.SMTP = &BootstrapSMTPOptions{Host: "smtp.example.com", Port: 70000, FromAddress: "noreply@example.com"}
+		}},
+		{"SMTP without from address", func(o *InstanceBootstrapOptions) {
+			o.SMTP = &BootstrapSMTPOptions{Host: "smtp.example.com"}
+		}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := validBootstrapOptions()
+			tt.modify(&opts)
+			assert.Error(t, opts.Validate())
+		})
+	}
+}
+
+func TestAnonKeyScopes_Valid(t *testing.T) {
+	require.NoError(t, ValidateScopes(AnonKeyScopes))
+	assert.NotContains(t, AnonKeyScopes, ScopeWildcard)
+}
@@ -29,6 +29,8 @@ type TokenClaims struct {
 	IsAnonymous  bool                   `json:"is_anonymous,omitempty"`  // True for anonymous users
 	UserMetadata any                    `json:"user_metadata,omitempty"` // User-editable metadata
 	AppMetadata  any                    `json:"app_metadata,omitempty"`  // Application/admin-only metadata
+	OrgID        string                 `json:"org_id,omitempty"`        // Active organization, empty if none
+	OrgRole      string                 `json:"org_role,omitempty"`      // Role in the active organization
 	RawClaims    map[string]interface{} `json:"-"`                       // Full claims map for RLS (not serialized)
 	jwt.RegisteredClaims
 }
@@ -169,6 +171,42 @@ func (m *JWTManager) GenerateTokenPair(userID, email, role string, userMetadata,
 	return accessToken, refreshToken, sessionID, nil
 }
 
+// GenerateOrgTokenPair generates an access and refresh token pair for an existing session with
+// orgID as the active organization. An empty orgID generates tokens without one.
+func (m *JWTManager) GenerateOrgTokenPair(userID, email, role, sessionID, orgID, orgRole string, userMetadata, appMetadata any) (accessToken, refreshToken string, err error) {
+	now := time.Now()
+	sign := func(tokenType string, ttl time.Duration) (string, error) {
+		claims := &TokenClaims{
+			UserID:       userID,
+			Email:        email,
+			Role:         role,
+			SessionID:    sessionID,
+			TokenType:    tokenType,
+			UserMetadata: userMetadata,
+			AppMetadata:  appMetadata,
+			OrgID:        orgID,
+			OrgRole:      orgRole,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    m.issuer,
+				Subject:   userID,
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+				NotBefore: jwt.NewNumericDate(now),
+				ID:        uuid.New().String(),
+			},
+		}
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secretKey)
+	}
+
+	if accessToken, err = sign("access", m.accessTokenTTL); err != nil {
+		return "", "", err
+	}
+	if refreshToken, err = sign("refresh", m.refreshTokenTTL); err != nil {
+		return "", "", err
+	}
+	return accessToken, refreshToken, nil
+}
+
 // ValidateToken validates and parses a JWT token
 func (m *JWTManager) ValidateToken(tokenString string) (*TokenClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
@@ -0,0 +1,196 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrKnownDeviceNotFound is returned when a known device doesn't exist or belongs to another user
+var ErrKnownDeviceNotFound = errors.New("known device not found")
+
+// KnownDevice is a device a user has signed in from, identified by its device fingerprint
+type KnownDevice struct {
+	ID          uuid.UUID `json:"id"`
+	UserID      uuid.UUID `json:"user_id"`
+	IPAddress   string    `json:"ip_address,omitempty"`
+	Location    string    `json:"location,omitempty"`
+	UserAgent   string    `json:"user_agent,omitempty"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+}
+
+// DeviceSignIn describes the device and network of a successful sign-in
+type DeviceSignIn struct {
+	DeviceFingerprint string
+	IPAddress         string
+	Location          string // Approximate location, empty when unknown
+	UserAgent         string
+}
+
+// templatedNewDeviceEmailService is implemented by email services that render new-device alerts
+// from a customized template. It reports false without sending when there is none.
+type templatedNewDeviceEmailService interface {
+	SendNewDeviceAlert(ctx context.Context, to string, data map[string]string) (bool, error)
+}
+
+// KnownDeviceService tracks the devices users sign in from and emails a security notification
+// when a sign-in succeeds from a device the user hasn't used before
+type KnownDeviceService struct {
+	db            *pgxpool.Pool
+	emailService  RealEmailService
+	appName       string
+	alertsEnabled bool
+}
+
+// NewKnownDeviceService creates a known device service. Devices are tracked even when alerts
+// are disabled, so that users can review them.
+func NewKnownDeviceService(db *pgxpool.Pool, emailService RealEmailService, appName string, alertsEnabled bool) *KnownDeviceService {
+	if appName == "" {
+		appName = "Fluxbase"
+	}
+	return &KnownDeviceService{
+		db:            db,
+		emailService:  emailService,
+		appName:       appName,
+		alertsEnabled: alertsEnabled,
+	}
+}
+
+// RecordSignIn records a successful sign-in from a device and reports whether the device is new
+// to a user who had signed in from other devices before. The first device of a user is recorded
+// without being reported, so that a user's first sign-in doesn't trigger an alert. Sign-ins
+// without a device fingerprint are not recorded.
+func (s *KnownDeviceService) RecordSignIn(ctx context.Context, userID uuid.UUID, signIn DeviceSignIn) (bool, error) {
+	if signIn.DeviceFingerprint == "" {
+		return false, nil
+	}
+
+	query := `
+		WITH previous AS (
+			SELECT EXISTS(SELECT 1 FROM auth.known_devices WHERE user_id = $1) AS has_devices
+		)
+		INSERT INTO auth.known_devices (user_id, device_fingerprint, ip_address, location, user_agent)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''))
+		ON CONFLICT (user_id, device_fingerprint) DO UPDATE SET
+			ip_address = EXCLUDED.ip_address,
+			location = EXCLUDED.location,
+			user_agent = EXCLUDED.user_agent,
+			last_seen_at = NOW()
+		RETURNING (xmax = 0), (SELECT has_devices FROM previous)
+	`
+
+	var inserted, hadDevices bool
+	err := s.db.QueryRow(ctx, query, userID, signIn.DeviceFingerprint, signIn.IPAddress, signIn.Location, signIn.UserAgent).
+		Scan(&inserted, &hadDevices)
+	if err != nil {
+		return false, fmt.Errorf("failed to record known device: %w", err)
+	}
+	return inserted && hadDevices, nil
+}
+
+// SendNewDeviceAlert emails a user about a sign-in from a new device, with the customized
+// new_device template if there is one
+func (s *KnownDeviceService) SendNewDeviceAlert(ctx context.Context, to string, signIn DeviceSignIn, signedInAt time.Time) error {
+	if !s.alertsEnabled || s.emailService == nil || !s.emailService.IsConfigured() {
+		return nil
+	}
+
+	data := newDeviceAlertData(signIn, signedInAt)
+	if templated, ok := s.emailService.(templatedNewDeviceEmailService); ok {
+		sent, err := templated.SendNewDeviceAlert(ctx, to, data)
+		if err != nil {
+			return fmt.Errorf("failed to send new device alert: %w", err)
+		}
+		if sent {
+			return nil
+		}
+	}
+
+	subject := fmt.Sprintf("New sign-in to your %s account", s.appName)
+	if err := s.emailService.Send(ctx, to, subject, s.newDeviceAlertBody(data)); err != nil {
+		return fmt.Errorf("failed to send new device alert: %w", err)
+	}
+	return nil
+}
+
+// newDeviceAlertData returns the template variables of a new-device alert
+func newDeviceAlertData(signIn DeviceSignIn, signedInAt time.Time) map[string]string {
+	userAgent := signIn.UserAgent
+	if userAgent == "" {
+		userAgent = "Unknown device"
+	}
+	return map[string]string{
+		"IPAddress":  signIn.IPAddress,
+		"Location":   signIn.Location,
+		"UserAgent":  userAgent,
+		"SignedInAt": signedInAt.UTC().Format("2006-01-02 15:04 MST"),
+	}
+}
+
+// newDeviceAlertBody returns the body of a new-device alert without a customized template
+func (s *KnownDeviceService) newDeviceAlertBody(data map[string]string) string {
+	location := ""
+	if data["Location"] != "" {
+		location = fmt.Sprintf("Location: %s\n", data["Location"])
+	}
+
+	return fmt.Sprintf(`Hello,
+
+Your %s account was signed in to from a device that hasn't been used before.
+
+Time: %s
+IP address: %s
+%sDevice: %s
+
+If this was you, you can ignore this email.
+
+If you don't recognize this sign-in, change your password right away and review your devices.
+
+Best regards,
+The %s Team`, s.appName, data["SignedInAt"], data["IPAddress"], location, data["UserAgent"], s.appName)
+}
+
+// ListDevices returns the known devices of a user, most recently used first
+func (s *KnownDeviceService) ListDevices(ctx context.Context, userID uuid.UUID) ([]KnownDevice, error) {
+	query := `
+		SELECT id, user_id, COALESCE(ip_address, ''), COALESCE(location, ''), COALESCE(user_agent, ''),
+		       first_seen_at, last_seen_at
+		FROM auth.known_devices
+		WHERE user_id = $1
+		ORDER BY last_seen_at DESC
+	`
+
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list known devices: %w", err)
+	}
+	defer rows.Close()
+
+	devices := []KnownDevice{}
+	for rows.Next() {
+		var d KnownDevice
+		if err := rows.Scan(&d.ID, &d.UserID, &d.IPAddress, &d.Location, &d.UserAgent, &d.FirstSeenAt, &d.LastSeenAt); err != nil {
+			return nil, fmt.Errorf("failed to scan known device: %w", err)
+		}
+		devices = append(devices, d)
+	}
+	return devices, rows.Err()
+}
+
+// DeleteDevice removes a known device of a user. The next sign-in from the device sends an
+// alert again.
+func (s *KnownDeviceService) DeleteDevice(ctx context.Context, userID, deviceID uuid.UUID) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM auth.known_devices WHERE id = $1 AND user_id = $2`, deviceID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete known device: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrKnownDeviceNotFound
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDeviceTemplateMockEmailService renders new-device alerts from a customized template when it has one
+type newDeviceTemplateMockEmailService struct {
+	MockEmailService
+	hasTemplate bool
+	alerts      []map[string]string
+}
+
+func (m *newDeviceTemplateMockEmailService) SendNewDeviceAlert(ctx context.Context, to string, data map[string]string) (bool, error) {
+	if !m.hasTemplate {
+		return false, nil
+	}
+	m.alerts = append(m.alerts, data)
+	return true, m.sendError
+}
+
+func TestKnownDeviceService_RecordSignIn_WithoutFingerprint(t *testing.T) {
+	// Sign-ins without a device fingerprint are not recorded, so the database isn't touched
+	service := NewKnownDeviceService(nil, nil, "", true)
+
+	isNew, err := service.RecordSignIn(context.Background(), uuid.New(), DeviceSignIn{IPAddress: "203.0.113.7"})
+	require.NoError(t, err)
+	assert.False(t, isNew)
+}
+
+func TestKnownDeviceService_SendNewDeviceAlert(t *testing.T) {
+	signIn := DeviceSignIn{
+		DeviceFingerprint: "fp-123",
+		IPAddress:         "203.0.113.7",
+		Location:          "Berlin, DE",
+		UserAgent:         "Mozilla/5.0 Firefox/128.0",
+	}
+	signedInAt := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	t.Run("built-in email", func(t *testing.T) {
+		mockEmail := &MockEmailService{configured: true}
+		service := NewKnownDeviceService(nil, mockEmail, "TestApp", true)
+
+		require.NoError(t, service.SendNewDeviceAlert(context.Background(), "user@example.com", signIn, signedInAt))
+		require.Len(t, mockEmail.sendCalls, 1)
+		call := mockEmail.sendCalls[0]
+		assert.Equal(t, "user@example.com", call.To)
+		assert.Equal(t, "New sign-in to your TestApp account", call.Subject)
+		assert.Contains(t, call.Body, "Time: 2026-03-01 09:30 UTC")
+		assert.Contains(t, call.Body, "IP address: 203.0.113.7")
+		assert.Contains(t, call.Body, "Location: Berlin, DE")
+		assert.Contains(t, call.Body, "Device: Mozilla/5.0 Firefox/128.0")
+	})
+
+	t.Run("unknown location is left out", func(t *testing.T) {
+		mockEmail := &MockEmailService{configured: true}
+		service := NewKnownDeviceService(nil, mockEmail, "TestApp", true)
+
+		require.NoError(t, service.SendNewDeviceAlert(context.Background(), "user@example.com", DeviceSignIn{IPAddress: "203.0.113.7"}, signedInAt))
+		require.Len(t, mockEmail.sendCalls, 1)
+		assert.NotContains(t, mockEmail.sendCalls[0].Body, "Location:")
+		assert.Contains(t, mockEmail.sendCalls[0].Body, "Device: Unknown device")
+	})
+
+	t.Run("customized template", func(t *testing.T) {
+		mockEmail := &newDeviceTemplateMockEmailService{MockEmailService: MockEmailService{configured: true}, hasTemplate: true}
+		service := NewKnownDeviceService(nil, mockEmail, "TestApp", true)
+
+		require.NoError(t, service.SendNewDeviceAlert(context.Background(), "user@example.com", signIn, signedInAt))
+		assert.Empty(t, mockEmail.sendCalls)
+		require.Len(t, mockEmail.alerts, 1)
+		assert.Equal(t, map[string]string{
+			"IPAddress":  "203.0.113.7",
+			"Location":   "Berlin, DE",
+			"UserAgent":  "Mozilla/5.0 Firefox/128.0",
+			"SignedInAt": "2026-03-01 09:30 UTC",
+		}, mockEmail.alerts[0])
+	})
+
+	t.Run("alerts disabled", func(t *testing.T) {
+		mockEmail := &MockEmailService{configured: true}
+		service := NewKnownDeviceService(nil, mockEmail, "TestApp", false)
+
+		require.NoError(t, service.SendNewDeviceAlert(context.Background(), "user@example.com", signIn, signedInAt))
+		assert.Empty(t, mockEmail.sendCalls)
+	})
+
+	t.Run("email not configured", func(t *testing.T) {
+		mockEmail := &MockEmailService{}
+		service := NewKnownDeviceService(nil, mockEmail, "TestApp", true)
+
+		require.NoError(t, service.SendNewDeviceAlert(context.Background(), "user@example.com", signIn, signedInAt))
+		assert.Empty(t, mockEmail.sendCalls)
+	})
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrgRoleRank(t *testing.T) {
+	assert.Greater(t, OrgRoleRank(OrgRoleOwner), OrgRoleRank(OrgRoleAdmin))
+	assert.Greater(t, OrgRoleRank(OrgRoleAdmin), OrgRoleRank(OrgRoleMember))
+	assert.Greater(t, OrgRoleRank(OrgRoleMember), 0)
+	assert.Equal(t, 0, OrgRoleRank("superuser"))
+
+	assert.True(t, ValidOrgRole(OrgRoleMember))
+	assert.False(t, ValidOrgRole(""))
+	assert.False(t, ValidOrgRole("Owner"))
+}
+
+func TestValidateOrganizationSlug(t *testing.T) {
+	for _, slug := range []string{"acme", "acme-inc", "a1", "42-labs"} {
+		assert.NoError(t, ValidateOrganizationSlug(slug), slug)
+	}
+	for _, slug := range []string{"", "a", "Acme", "-acme", "acme_inc", "acme inc", "acmé", string(make([]byte, 64))} {
+		assert.ErrorIs(t, ValidateOrganizationSlug(slug), ErrInvalidOrganizationSlug, slug)
+	}
+}
+
+func TestGenerateOrgTokenPair(t *testing.T) {
+	manager, err := NewJWTManager(testSecretKey, 15*time.Minute, 7*24*time.Hour)
+	require.NoError(t, err)
+
+	t.Run("carries the active organization", func(t *testing.T) {
+		access, refresh, err := manager.GenerateOrgTokenPair("user-1", "jane@example.com", "authenticated", "session-1",
+			"3f0d6f0e-8f5e-4a0b-9a43-2f1f7d2b7c11", OrgRoleAdmin, nil, map[string]any{"plan": "pro"})
+		require.NoError(t, err)
+
+		accessClaims, err := manager.ValidateAccessToken(access)
+		require.NoError(t, err)
+		assert.Equal(t, "3f0d6f0e-8f5e-4a0b-9a43-2f1f7d2b7c11", accessClaims.OrgID)
+		assert.Equal(t, OrgRoleAdmin, accessClaims.OrgRole)
+		assert.Equal(t, "session-1", accessClaims.SessionID)
+		assert.Equal(t, "3f0d6f0e-8f5e-4a0b-9a43-2f1f7d2b7c11", accessClaims.RawClaims["org_id"])
+
+		refreshClaims, err := manager.ValidateRefreshToken(refresh)
+		require.NoError(t, err)
+		assert.Equal(t, accessClaims.OrgID, refreshClaims.OrgID)
+		assert.Equal(t, OrgRoleAdmin, refreshClaims.OrgRole)
+		assert.Equal(t, "session-1", refreshClaims.SessionID)
+	})
+
+	t.Run("empty organization leaves the claims out", func(t *testing.T) {
+		access, _, err := manager.GenerateOrgTokenPair("user-1", "jane@example.com", "authenticated", "session-1", "", "", nil, nil)
+		require.NoError(t, err)
+
+		claims, err := manager.ValidateAccessToken(access)
+		require.NoError(t, err)
+		assert.Empty(t, claims.OrgID)
+		assert.NotContains(t, claims.RawClaims, "org_id")
+		assert.NotContains(t, claims.RawClaims, "org_role")
+	})
+}
@@ -15,6 +15,12 @@ type RealEmailService interface {
 	IsConfigured() bool
 }
 
+// templatedOTPEmailService is implemented by email services that render OTP emails from a
+// customized template. It reports false without sending when there is none.
+type templatedOTPEmailService interface {
+	SendOTP(ctx context.Context, to, code, purpose string) (bool, error)
+}
+
 // DefaultOTPSender implements OTPSender using email service
 type DefaultOTPSender struct {
 	emailService RealEmailService
@@ -46,6 +52,16 @@ func (s *DefaultOTPSender) SendEmailOTP(ctx context.Context, to, code, purpose s
 		return nil
 	}
 
+	if templated, ok := s.emailService.(templatedOTPEmailService); ok {
+		sent, err := templated.SendOTP(ctx, to, code, purpose)
+		if err != nil {
+			return fmt.Errorf("failed to send OTP email: %w", err)
+		}
+		if sent {
+			return nil
+		}
+	}
+
 	subject := s.getEmailSubject(purpose)
 	body := s.getEmailBody(code, purpose)
 
@@ -377,6 +377,51 @@ func TestSendEmailOTP_EmailServiceError(t *testing.T) {
 	assert.Contains(t, err.Error(), "email service unavailable")
 }
 
+// templatedMockEmailService renders OTP emails from a customized template when it has one
+type templatedMockEmailService struct {
+	MockEmailService
+	hasTemplate bool
+	otpCalls    []string
+}
+
+func (m *templatedMockEmailService) SendOTP(ctx context.Context, to, code, purpose string) (bool, error) {
+	if !m.hasTemplate {
+		return false, nil
+	}
+	m.otpCalls = append(m.otpCalls, to+":"+code+":"+purpose)
+	return true, m.sendError
+}
+
+func TestSendEmailOTP_CustomTemplate(t *testing.T) {
+	t.Run("uses the customized template", func(t *testing.T) {
+		mockEmail := &templatedMockEmailService{MockEmailService: MockEmailService{configured: true}, hasTemplate: true}
+		sender := NewDefaultOTPSender(mockEmail, "noreply@example.com", "TestApp")
+
+		require.NoError(t, sender.SendEmailOTP(context.Background(), "user@example.com", "123456", "signin"))
+		assert.Equal(t, []string{"user@example.com:123456:signin"}, mockEmail.otpCalls)
+		assert.Empty(t, mockEmail.sendCalls)
+	})
+
+	t.Run("falls back to the built-in email", func(t *testing.T) {
+		mockEmail := &templatedMockEmailService{MockEmailService: MockEmailService{configured: true}}
+		sender := NewDefaultOTPSender(mockEmail, "noreply@example.com", "TestApp")
+
+		require.NoError(t, sender.SendEmailOTP(context.Background(), "user@example.com", "123456", "signin"))
+		assert.Empty(t, mockEmail.otpCalls)
+		require.Len(t, mockEmail.sendCalls, 1)
+		assert.Equal(t, "Your TestApp Sign In Code", mockEmail.sendCalls[0].Subject)
+	})
+
+	t.Run("reports template send errors", func(t *testing.T) {
+		mockEmail := &templatedMockEmailService{MockEmailService: MockEmailService{sendError: errors.New("smtp down")}, hasTemplate: true}
+		sender := NewDefaultOTPSender(mockEmail, "noreply@example.com", "TestApp")
+
+		err := sender.SendEmailOTP(context.Background(), "user@example.com", "123456", "signin")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "smtp down")
+	})
+}
+
 func TestSendEmailOTP_DifferentCodes(t *testing.T) {
 	tests := []struct {
 		name string
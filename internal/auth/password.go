@@ -94,11 +94,25 @@ func (h *PasswordHasher) HashPassword(password string) (string, error) {
 	return string(hashedBytes), nil
 }
 
-// ComparePassword compares a plain password with a hashed password
+// ComparePassword compares a plain password with a hashed password.
+// Besides bcrypt it verifies hashes imported from other auth providers (see password_legacy.go).
 func (h *PasswordHasher) ComparePassword(hashedPassword, plainPassword string) error {
+	if PasswordHashAlgorithm(hashedPassword) == HashAlgorithmFirebaseScrypt {
+		return compareFirebaseScrypt(hashedPassword, plainPassword)
+	}
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(plainPassword))
 }
 
+// RehashPassword hashes an already verified password with bcrypt. Unlike HashPassword it
+// does not apply the password policy, so users with older passwords can still sign in.
+func (h *PasswordHasher) RehashPassword(password string) (string, error) {
+	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashedBytes), nil
+}
+
 // ValidatePassword validates a password against configured requirements
 func (h *PasswordHasher) ValidatePassword(password string) error {
 	// Check length
@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Password hash algorithms. Hashes are self-describing: bcrypt hashes carry their
+// own "$2a$"-style prefix, imported hashes of other algorithms are stored with a
+// "$<algorithm>$" prefix and replaced by bcrypt on the user's next sign-in.
+const (
+	HashAlgorithmBcrypt         = "bcrypt"
+	HashAlgorithmFirebaseScrypt = "firebase-scrypt"
+)
+
+const firebaseScryptPrefix = "$" + HashAlgorithmFirebaseScrypt + "$"
+
+// ErrUnsupportedPasswordHash is returned for password hashes of an unknown algorithm
+var ErrUnsupportedPasswordHash = errors.New("unsupported password hash algorithm")
+
+// FirebaseScryptParams are the project-wide password hash parameters of a Firebase
+// project (Authentication > Users > Password hash parameters in the Firebase console)
+type FirebaseScryptParams struct {
+	SignerKey     string `json:"signer_key"`     // base64_signer_key
+	SaltSeparator string `json:"salt_separator"` // base64_salt_separator
+	Rounds        int    `json:"rounds"`
+	MemCost       int    `json:"mem_cost"`
+}
+
+// Validate checks that the parameters are complete and decodable
+func (p *FirebaseScryptParams) Validate() error {
+	if _, err := base64.StdEncoding.DecodeString(p.SignerKey); err != nil || p.SignerKey == "" {
+		return errors.New("signer_key must be a non-empty base64 string")
+	}
+	if _, err := base64.StdEncoding.DecodeString(p.SaltSeparator); err != nil {
+		return errors.New("salt_separator must be base64")
+	}
+	if p.Rounds < 1 || p.Rounds > 8 {
+		return fmt.Errorf("rounds must be between 1 and 8, got %d", p.Rounds)
+	}
+	if p.MemCost < 1 || p.MemCost > 14 {
+		return fmt.Errorf("mem_cost must be between 1 and 14, got %d", p.MemCost)
+	}
+	return nil
+}
+
+// EncodeFirebaseScryptHash builds the stored form of a Firebase password hash.
+// salt and hash are the base64 values from the Firebase users export.
+func EncodeFirebaseScryptHash(params FirebaseScryptParams, salt, hash string) (string, error) {
+	if err := params.Validate(); err != nil {
+		return "", err
+	}
+	if _, err := base64.StdEncoding.DecodeString(salt); err != nil {
+		return "", errors.New("salt must be base64")
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(hash); err != nil || len(decoded) == 0 {
+		return "", errors.New("password hash must be a non-empty base64 string")
+	}
+	return fmt.Sprintf("%s%d$%d$%s$%s$%s$%s", firebaseScryptPrefix,
+		params.Rounds, params.MemCost, params.SaltSeparator, params.SignerKey, salt, hash), nil
+}
+
+// PasswordHashAlgorithm returns the algorithm of a stored password hash, or "" if it is unknown
+func PasswordHashAlgorithm(hash string) string {
+	switch {
+	case strings.HasPrefix(hash, firebaseScryptPrefix):
+		return HashAlgorithmFirebaseScrypt
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		return HashAlgorithmBcrypt
+	default:
+		return ""
+	}
+}
+
+// compareFirebaseScrypt verifies a password against a hash produced by Firebase's
+// modified scrypt: the scrypt-derived key encrypts the signer key with AES-256-CTR.
+func compareFirebaseScrypt(encoded, password string) error {
+	parts := strings.Split(strings.TrimPrefix(encoded, firebaseScryptPrefix), "$")
+	if len(parts) != 6 {
+		return ErrUnsupportedPasswordHash
+	}
+
+	rounds, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return ErrUnsupportedPasswordHash
+	}
+	memCost, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return ErrUnsupportedPasswordHash
+	}
+
+	var decoded [4][]byte
+	for i, part := range parts[2:] {
+		if decoded[i], err = base64.StdEncoding.DecodeString(part); err != nil {
+			return ErrUnsupportedPasswordHash
+		}
+	}
+	saltSeparator, signerKey, salt, expected := decoded[0], decoded[1], decoded[2], decoded[3]
+
+	key, err := scrypt.Key([]byte(password), append(salt, saltSeparator...), 1<<memCost, rounds, 1, 32)
+	if err != nil {
+		return fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return fmt.Errorf("failed to create cipher: %w", err)
+	}
+	actual := make([]byte, len(signerKey))
+	cipher.NewCTR(block, make([]byte, aes.BlockSize)).XORKeyStream(actual, signerKey)
+
+	if subtle.ConstantTimeCompare(actual, expected) != 1 {
+		return bcrypt.ErrMismatchedHashAndPassword
+	}
+	return nil
+}
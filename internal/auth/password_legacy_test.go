@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test vector from https://github.com/firebase/scrypt
+var firebaseTestParams = FirebaseScryptParams{
+	SignerKey:     "jxspr8Ki0RYycVU8zykbdLGjFQ3McFUH0uiiTvC8pVMXAn210wjLNmdZJzxUECKbm0QsEmYUSDzZvpjeJ9WmXA==",
+	SaltSeparator: "Bw==",
+	Rounds:        8,
+	MemCost:       14,
+}
+
+const (
+	firebaseTestSalt     = "42xEC+ixf3L2lw=="
+	firebaseTestHash     = "lSrfV15cpx95/sZS2W9c9Kp6i/LVgQNDNC/qzrCnh1SAyZvqmZqAjTdn3aoItz+VHjoZilo78198JAdRuid5lQ=="
+	firebaseTestPassword = "user1password"
+)
+
+func TestComparePassword_FirebaseScrypt(t *testing.T) {
+	encoded, err := EncodeFirebaseScryptHash(firebaseTestParams, firebaseTestSalt, firebaseTestHash)
+	require.NoError(t, err)
+	assert.Equal(t, HashAlgorithmFirebaseScrypt, PasswordHashAlgorithm(encoded))
+
+	hasher := NewPasswordHasher()
+	assert.NoError(t, hasher.ComparePassword(encoded, firebaseTestPassword))
+	assert.Error(t, hasher.ComparePassword(encoded, "user2password"))
+
+	// Imported hashes are replaced by bcrypt on the next sign-in
+	assert.True(t, hasher.NeedsRehash(encoded))
+}
+
+func TestComparePassword_MalformedFirebaseHash(t *testing.T) {
+	hasher := NewPasswordHasher()
+	assert.ErrorIs(t, hasher.ComparePassword("$firebase-scrypt$8$14$Bw==", "password"), ErrUnsupportedPasswordHash)
+	assert.ErrorIs(t, hasher.ComparePassword("$firebase-scrypt$x$14$Bw==$a2V5$c2FsdA==$aGFzaA==", "password"), ErrUnsupportedPasswordHash)
+}
+
+func TestRehashPassword_SkipsPolicy(t *testing.T) {
+	hasher := NewPasswordHasherWithConfig(PasswordHasherConfig{Cost: 4})
+
+	// Too weak for HashPassword, but an existing password must still be re-hashable
+	_, err := hasher.HashPassword("short")
+	require.ErrorIs(t, err, ErrWeakPassword)
+
+	hash, err := hasher.RehashPassword("short")
+	require.NoError(t, err)
+	assert.Equal(t, HashAlgorithmBcrypt, PasswordHashAlgorithm(hash))
+	assert.NoError(t, hasher.ComparePassword(hash, "short"))
+	assert.False(t, hasher.NeedsRehash(hash))
+}
+
+func TestPasswordHashAlgorithm(t *testing.T) {
+	assert.Equal(t, HashAlgorithmBcrypt, PasswordHashAlgorithm("$2a$10$abcdefghijklmnopqrstuv"))
+	assert.Equal(t, HashAlgorithmBcrypt, PasswordHashAlgorithm("$2b$12$abcdefghijklmnopqrstuv"))
+	assert.Equal(t, HashAlgorithmFirebaseScrypt, PasswordHashAlgorithm("$firebase-scrypt$8$14$..."))
+	assert.Equal(t, "", PasswordHashAlgorithm("$argon2id$v=19$..."))
+	assert.Equal(t, "", PasswordHashAlgorithm(""))
+}
+
+func TestFirebaseScryptParams_Validate(t *testing.T) {
+	require.NoError(t, firebaseTestParams.Validate())
+
+	tests := []struct {
+		name   string
+		mutate func(*FirebaseScryptParams)
+		errMsg string
+	}{
+		{"missing signer key", func(p *FirebaseScryptParams) { p.SignerKey = "" }, "signer_key"},
+		{"signer key not base64", func(p *FirebaseScryptParams) { p.SignerKey = "not base64!" }, "signer_key"},
+		{"salt separator not base64", func(p *FirebaseScryptParams) { p.SaltSeparator = "%%" }, "salt_separator"},
+		{"rounds out of range", func(p *FirebaseScryptParams) { p.Rounds = 0 }, "rounds"},
+		{"mem cost out of range", func(p *FirebaseScryptParams) { p.MemCost = 20 }, "mem_cost"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := firebaseTestParams
+			tt.mutate(&params)
+			err := params.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
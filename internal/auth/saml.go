@@ -1080,6 +1080,47 @@ func (s *SAMLService) HasSigningKey(providerName string) bool {
 	return provider.spKey != nil && provider.spCert != nil
 }
 
+// SAMLCertificateInfo describes a certificate used by a SAML provider
+type SAMLCertificateInfo struct {
+	Provider string    `json:"provider"`
+	Use      string    `json:"use"` // "idp_signing" or "sp_signing"
+	Subject  string    `json:"subject"`
+	NotAfter time.Time `json:"not_after"`
+}
+
+// Certificates returns the IdP signing and SP signing certificates of all enabled providers.
+// Certificates that cannot be parsed are skipped.
+func (s *SAMLService) Certificates() []SAMLCertificateInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var certs []SAMLCertificateInfo
+	for _, p := range s.providers {
+		if !p.Enabled {
+			continue
+		}
+		if p.Certificate != "" {
+			if cert, err := parsePEMCertificate(p.Certificate); err == nil {
+				certs = append(certs, SAMLCertificateInfo{
+					Provider: p.Name,
+					Use:      "idp_signing",
+					Subject:  cert.Subject.String(),
+					NotAfter: cert.NotAfter,
+				})
+			}
+		}
+		if p.spCert != nil {
+			certs = append(certs, SAMLCertificateInfo{
+				Provider: p.Name,
+				Use:      "sp_signing",
+				Subject:  p.spCert.Subject.String(),
+				NotAfter: p.spCert.NotAfter,
+			})
+		}
+	}
+	return certs
+}
+
 // inflateBytes decompresses deflated SAML data (used in HTTP-Redirect binding)
 func inflateBytes(data []byte) ([]byte, error) {
 	reader := flate.NewReader(bytes.NewReader(data))
@@ -1,6 +1,13 @@
 package auth
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
 	"testing"
 	"time"
 
@@ -923,3 +930,58 @@ func TestSAMLService_MetadataFetch(t *testing.T) {
 	_, err = svc.fetchMetadata("http://invalid-url-that-does-not-exist.example")
 	assert.Error(t, err)
 }
+
+func TestSAMLService_Certificates(t *testing.T) {
+	newCert := func(cn string, notAfter time.Time) *x509.Certificate {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: cn},
+			NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+			NotAfter:     notAfter,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+		require.NoError(t, err)
+		cert, err := x509.ParseCertificate(der)
+		require.NoError(t, err)
+		return cert
+	}
+
+	idpExpiry := time.Now().Add(10 * 24 * time.Hour).UTC().Truncate(time.Second)
+	spExpiry := time.Now().Add(90 * 24 * time.Hour).UTC().Truncate(time.Second)
+	idpCert := newCert("idp.example.com", idpExpiry)
+
+	svc := &SAMLService{
+		providers: map[string]*SAMLProvider{
+			"okta": {
+				Name:        "okta",
+				Enabled:     true,
+				Certificate: base64.StdEncoding.EncodeToString(idpCert.Raw),
+				spCert:      newCert("sp.example.com", spExpiry),
+			},
+			"disabled": {
+				Name:        "disabled",
+				Enabled:     false,
+				Certificate: base64.StdEncoding.EncodeToString(idpCert.Raw),
+			},
+			"broken": {
+				Name:        "broken",
+				Enabled:     true,
+				Certificate: "not-a-certificate",
+			},
+		},
+	}
+
+	certs := svc.Certificates()
+	require.Len(t, certs, 2)
+
+	byUse := map[string]SAMLCertificateInfo{}
+	for _, c := range certs {
+		assert.Equal(t, "okta", c.Provider)
+		byUse[c.Use] = c
+	}
+	assert.Equal(t, idpExpiry, byUse["idp_signing"].NotAfter.UTC())
+	assert.Equal(t, "CN=idp.example.com", byUse["idp_signing"].Subject)
+	assert.Equal(t, spExpiry, byUse["sp_signing"].NotAfter.UTC())
+}
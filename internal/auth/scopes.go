@@ -54,6 +54,10 @@ const (
 	ScopeSecretsRead  = "read:secrets"
 	ScopeSecretsWrite = "write:secrets"
 
+	// Queues
+	ScopeQueuesRead  = "read:queues"
+	ScopeQueuesWrite = "write:queues"
+
 	// Migrations
 	ScopeMigrationsRead    = "migrations:read"
 	ScopeMigrationsExecute = "migrations:execute"
@@ -87,6 +91,8 @@ var AllScopes = []string{
 	ScopeAIWrite,
 	ScopeSecretsRead,
 	ScopeSecretsWrite,
+	ScopeQueuesRead,
+	ScopeQueuesWrite,
 	ScopeMigrationsRead,
 	ScopeMigrationsExecute,
 }
@@ -30,6 +30,7 @@ type Service struct {
 	impersonationService    *ImpersonationService
 	otpService              *OTPService
 	identityService         *IdentityService
+	organizationService     *OrganizationService
 	systemSettings          *SystemSettingsService
 	settingsCache           *SettingsCache
 	nonceRepo               *NonceRepository
@@ -208,6 +209,7 @@ func NewService(
 		impersonationService:    impersonationService,
 		otpService:              otpService,
 		identityService:         identityService,
+		organizationService:     NewOrganizationService(db),
 		systemSettings:          systemSettingsService,
 		settingsCache:           settingsCache,
 		nonceRepo:               nonceRepo,
@@ -257,6 +259,17 @@ func (s *Service) SignUp(ctx context.Context, req SignUpRequest) (*SignUpRespons
 		return nil, fmt.Errorf("invalid password: %w", err)
 	}
 
+	// Reject aliases of an existing account (e.g. jane.doe+promo@gmail.com for janedoe@gmail.com)
+	if s.isEmailNormalizationEnabled(ctx) {
+		existing, err := s.userRepo.ListByNormalizedEmail(ctx, NormalizeEmail(req.Email))
+		if err != nil {
+			return nil, fmt.Errorf("failed to check existing users: %w", err)
+		}
+		if len(existing) > 0 {
+			return nil, fmt.Errorf("failed to create user: %w", ErrUserAlreadyExists)
+		}
+	}
+
 	// Hash password
 	hashedPassword, err := s.passwordHasher.HashPassword(req.Password)
 	if err != nil {
@@ -344,8 +357,11 @@ type SignInResponse struct {
 
 // SignIn authenticates a user with email and password
 func (s *Service) SignIn(ctx context.Context, req SignInRequest) (*SignInResponse, error) {
-	// Get user by email
+	// Get user by email, falling back to an alias of the address when normalization is enabled
 	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if errors.Is(err, ErrUserNotFound) && s.isEmailNormalizationEnabled(ctx) {
+		user, err = s.getUserByEmailAlias(ctx, req.Email)
+	}
 	if err != nil {
 		if errors.Is(err, ErrUserNotFound) {
 			// Log failed login attempt for non-existent user
@@ -429,6 +445,15 @@ func (s *Service) SignIn(ctx context.Context, req SignInRequest) (*SignInRespons
 		return nil, fmt.Errorf("invalid email or password")
 	}
 
+	// Replace imported or outdated password hashes now that the plain password is known
+	if s.passwordHasher.NeedsRehash(user.PasswordHash) {
+		if hash, err := s.passwordHasher.RehashPassword(req.Password); err == nil {
+			if err := s.userRepo.UpdatePassword(ctx, user.ID, hash); err != nil {
+				log.Warn().Err(err).Str("user_id", user.ID).Msg("Failed to upgrade password hash")
+			}
+		}
+	}
+
 	// Reset failed login attempts on successful login
 	if user.FailedLoginAttempts > 0 {
 		if err := s.userRepo.ResetFailedLoginAttempts(ctx, user.ID); err != nil {
@@ -529,22 +554,90 @@ type RefreshTokenResponse struct {
 	ExpiresIn    int64  `json:"expires_in"` // seconds
 }
 
-// RefreshToken generates new access and refresh tokens using a refresh token (token rotation)
-// SECURITY: Implements refresh token rotation - each refresh generates a new refresh token
-// and invalidates the old one. This limits the window of opportunity for stolen tokens.
+// RefreshToken generates new access and refresh tokens using a refresh token (token rotation).
+// The active organization is kept while the user is still a member of it.
 func (s *Service) RefreshToken(ctx context.Context, req RefreshTokenRequest) (*RefreshTokenResponse, error) {
+	claims, session, user, err := s.validateRefreshSession(ctx, req.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.OrgID != "" {
+		orgRole, err := s.organizationService.GetMemberRole(ctx, claims.OrgID, claims.UserID)
+		if err != nil && !errors.Is(err, ErrOrgMemberNotFound) {
+			return nil, fmt.Errorf("failed to get organization membership: %w", err)
+		}
+		if err == nil {
+			return s.rotateOrgSession(ctx, claims, session, user, claims.OrgID, orgRole)
+		}
+		log.Debug().
+			Str("user_id", claims.UserID).
+			Str("org_id", claims.OrgID).
+			Msg("User is no longer a member of the active organization - dropping it from refreshed tokens")
+	}
+
+	// Generate new access token
+	newAccessToken, _, err := s.jwtManager.GenerateAccessToken(
+		claims.UserID,
+		claims.Email,
+		user.Role,
+		claims.UserMetadata,
+		claims.AppMetadata,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	// Generate new refresh token (rotation)
+	newRefreshToken, _, err := s.jwtManager.GenerateRefreshToken(
+		claims.UserID,
+		claims.Email,
+		user.Role,
+		claims.SessionID,
+		claims.UserMetadata,
+		claims.AppMetadata,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	return s.rotateSessionTokens(ctx, session, newAccessToken, newRefreshToken)
+}
+
+// SwitchOrganization rotates the session of a refresh token into tokens with orgID as the
+// active organization, carried in the org_id and org_role claims. An empty orgID clears the
+// active organization.
+func (s *Service) SwitchOrganization(ctx context.Context, refreshToken, orgID string) (*RefreshTokenResponse, error) {
+	claims, session, user, err := s.validateRefreshSession(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var orgRole string
+	if orgID != "" {
+		orgRole, err = s.organizationService.GetMemberRole(ctx, orgID, claims.UserID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.rotateOrgSession(ctx, claims, session, user, orgID, orgRole)
+}
+
+// validateRefreshSession returns the claims, session and user of a refresh token
+func (s *Service) validateRefreshSession(ctx context.Context, refreshToken string) (*TokenClaims, *Session, *User, error) {
 	// Validate refresh token
-	claims, err := s.jwtManager.ValidateToken(req.RefreshToken)
+	claims, err := s.jwtManager.ValidateToken(refreshToken)
 	if err != nil {
-		return nil, fmt.Errorf("invalid refresh token: %w", err)
+		return nil, nil, nil, fmt.Errorf("invalid refresh token: %w", err)
 	}
 
 	if claims.TokenType != "refresh" {
-		return nil, fmt.Errorf("invalid token type")
+		return nil, nil, nil, fmt.Errorf("invalid token type")
 	}
 
 	// Get session by refresh token
-	session, err := s.sessionRepo.GetByRefreshToken(ctx, req.RefreshToken)
+	session, err := s.sessionRepo.GetByRefreshToken(ctx, refreshToken)
 	if err != nil {
 		if errors.Is(err, ErrSessionNotFound) {
 			// SECURITY: If the session is not found but the token is valid, it may indicate
@@ -554,58 +647,62 @@ func (s *Service) RefreshToken(ctx context.Context, req RefreshTokenRequest) (*R
 				Str("user_id", claims.UserID).
 				Str("session_id", claims.SessionID).
 				Msg("Valid refresh token used but session not found - possible token theft detected")
-			return nil, fmt.Errorf("session not found or expired")
+			return nil, nil, nil, fmt.Errorf("session not found or expired")
 		}
-		return nil, fmt.Errorf("failed to get session: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to get session: %w", err)
 	}
 
 	// Check if session is expired
 	if time.Now().After(session.ExpiresAt) {
-		return nil, fmt.Errorf("session expired")
+		return nil, nil, nil, fmt.Errorf("session expired")
 	}
 
 	// Get user to include metadata in new tokens
 	user, err := s.userRepo.GetByID(ctx, claims.UserID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	// Generate new access token
-	newAccessToken, _, err := s.jwtManager.GenerateAccessToken(
-		claims.UserID,
-		claims.Email,
-		user.Role,
-		claims.UserMetadata,
-		claims.AppMetadata,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	// Locked or deprovisioned users can't keep their sessions alive
+	if user.IsLockedNow() {
+		return nil, nil, nil, ErrAccountLocked
 	}
 
-	// Generate new refresh token (rotation)
-	newRefreshToken, _, err := s.jwtManager.GenerateRefreshToken(
+	return claims, session, user, nil
+}
+
+// rotateOrgSession replaces the tokens of a session with ones carrying the active organization
+func (s *Service) rotateOrgSession(ctx context.Context, claims *TokenClaims, session *Session, user *User, orgID, orgRole string) (*RefreshTokenResponse, error) {
+	accessToken, refreshToken, err := s.jwtManager.GenerateOrgTokenPair(
 		claims.UserID,
 		claims.Email,
 		user.Role,
 		claims.SessionID,
+		orgID,
+		orgRole,
 		claims.UserMetadata,
 		claims.AppMetadata,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+		return nil, fmt.Errorf("failed to generate tokens: %w", err)
 	}
 
+	return s.rotateSessionTokens(ctx, session, accessToken, refreshToken)
+}
+
+// rotateSessionTokens stores the new tokens of a session and extends it
+func (s *Service) rotateSessionTokens(ctx context.Context, session *Session, accessToken, refreshToken string) (*RefreshTokenResponse, error) {
 	// Calculate new expiry (extend session)
 	newExpiresAt := time.Now().Add(s.config.RefreshExpiry)
 
 	// Update session with new tokens (rotation)
-	if err := s.sessionRepo.UpdateTokens(ctx, session.ID, newAccessToken, newRefreshToken, newExpiresAt); err != nil {
+	if err := s.sessionRepo.UpdateTokens(ctx, session.ID, accessToken, refreshToken, newExpiresAt); err != nil {
 		return nil, fmt.Errorf("failed to update session: %w", err)
 	}
 
 	return &RefreshTokenResponse{
-		AccessToken:  newAccessToken,
-		RefreshToken: newRefreshToken, // New rotated refresh token
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken, // New rotated refresh token
 		ExpiresIn:    int64(s.config.JWTExpiry.Seconds()),
 	}, nil
 }
@@ -669,6 +766,7 @@ func (s *Service) VerifyMagicLink(ctx context.Context, token string) (*SignInRes
 	// Verify the magic link
 	email, err := s.magicLinkService.VerifyMagicLink(ctx, token)
 	if err != nil {
+		s.recordAuthAttempt("magic_link", false, "invalid_token")
 		return nil, fmt.Errorf("failed to verify magic link: %w", err)
 	}
 
@@ -677,11 +775,18 @@ func (s *Service) VerifyMagicLink(ctx context.Context, token string) (*SignInRes
 	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, ErrUserNotFound) {
+			s.recordAuthAttempt("magic_link", false, "user_not_found")
 			return nil, fmt.Errorf("no account found for this email - please sign up first")
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
+	// Locked and banned users cannot sign in with a magic link either
+	if user.IsLockedNow() {
+		s.recordAuthAttempt("magic_link", false, "account_locked")
+		return nil, ErrAccountLocked
+	}
+
 	// Generate tokens with metadata
 	accessToken, refreshToken, _, err := s.jwtManager.GenerateTokenPair(
 		user.ID,
@@ -701,6 +806,7 @@ func (s *Service) VerifyMagicLink(ctx context.Context, token string) (*SignInRes
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
 
+	s.recordAuthAttempt("magic_link", true, "")
 	return &SignInResponse{
 		User:         user,
 		AccessToken:  accessToken,
@@ -918,6 +1024,11 @@ func (s *Service) IsSignupEnabled() bool {
 	return s.settingsCache.GetBool(ctx, "app.auth.signup_enabled", s.config.SignupEnabled)
 }
 
+// GetOrganizationService returns the organization service
+func (s *Service) GetOrganizationService() *OrganizationService {
+	return s.organizationService
+}
+
 // GetSettingsCache returns the settings cache
 func (s *Service) GetSettingsCache() *SettingsCache {
 	return s.settingsCache
@@ -1065,6 +1176,7 @@ func (s *Service) VerifyTOTPWithContext(ctx context.Context, userID, code, ipAdd
 	// Check rate limit before attempting verification
 	if s.totpRateLimiter != nil {
 		if err := s.totpRateLimiter.CheckRateLimit(ctx, userID); err != nil {
+			s.recordAuthAttempt("totp", false, "rate_limited")
 			return err
 		}
 	}
@@ -1105,6 +1217,7 @@ func (s *Service) VerifyTOTPWithContext(ctx context.Context, userID, code, ipAdd
 		if s.totpRateLimiter != nil {
 			_ = s.totpRateLimiter.RecordAttempt(ctx, userID, true, ipAddress, userAgent)
 		}
+		s.recordAuthAttempt("totp", true, "")
 		return nil
 	}
 
@@ -1135,6 +1248,7 @@ func (s *Service) VerifyTOTPWithContext(ctx context.Context, userID, code, ipAdd
 				_ = s.totpRateLimiter.RecordAttempt(ctx, userID, true, ipAddress, userAgent)
 			}
 
+			s.recordAuthAttempt("totp", true, "")
 			return nil
 		}
 	}
@@ -1150,6 +1264,7 @@ func (s *Service) VerifyTOTPWithContext(ctx context.Context, userID, code, ipAdd
 		`, userID, "totp_code")
 	}
 
+	s.recordAuthAttempt("totp", false, "invalid_code")
 	return errors.New("invalid 2FA code")
 }
 
@@ -1209,6 +1324,11 @@ func (s *Service) GenerateTokensForUser(ctx context.Context, userID string) (*Si
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
 
+	// SSO and 2FA sign-ins skip SignIn, so check the lock here as well
+	if user.IsLockedNow() {
+		return nil, ErrAccountLocked
+	}
+
 	// Generate JWT token pair with metadata
 	accessToken, refreshToken, _, err := s.jwtManager.GenerateTokenPair(user.ID, user.Email, user.Role, user.UserMetadata, user.AppMetadata)
 	if err != nil {
@@ -1279,6 +1399,7 @@ func (s *Service) SignInWithIDToken(ctx context.Context, provider, idToken, nonc
 	// Verify the ID token and extract claims
 	claims, err := s.oidcVerifier.Verify(ctx, provider, idToken, nonce)
 	if err != nil {
+		s.recordAuthAttempt("id_token", false, "invalid_token")
 		return nil, fmt.Errorf("invalid ID token: %w", err)
 	}
 
@@ -1300,6 +1421,11 @@ func (s *Service) SignInWithIDToken(ctx context.Context, provider, idToken, nonc
 			return nil, fmt.Errorf("failed to create user: %w", err)
 		}
 	} else {
+		if user.IsLockedNow() {
+			s.recordAuthAttempt("id_token", false, "account_locked")
+			return nil, ErrAccountLocked
+		}
+
 		// Update user info from OIDC claims if changed
 		if err := s.updateUserFromOIDCClaims(ctx, user, claims); err != nil {
 			// Log but don't fail the sign-in
@@ -1319,6 +1445,7 @@ func (s *Service) SignInWithIDToken(ctx context.Context, provider, idToken, nonc
 		return nil, fmt.Errorf("failed to generate tokens: %w", err)
 	}
 
+	s.recordAuthAttempt("id_token", true, "")
 	return &SignInResponse{
 		User:         user,
 		AccessToken:  accessToken,
@@ -1421,7 +1548,13 @@ func (s *Service) SendOTP(ctx context.Context, email, purpose string) error {
 
 // VerifyOTP verifies an OTP code sent via email
 func (s *Service) VerifyOTP(ctx context.Context, email, code string) (*OTPCode, error) {
-	return s.otpService.VerifyEmailOTP(ctx, email, code)
+	otp, err := s.otpService.VerifyEmailOTP(ctx, email, code)
+	if err != nil {
+		s.recordAuthAttempt("otp", false, "invalid_code")
+		return nil, err
+	}
+	s.recordAuthAttempt("otp", true, "")
+	return otp, nil
 }
 
 // ResendOTP resends an OTP code to an email
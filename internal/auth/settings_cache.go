@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -160,6 +161,15 @@ func (c *SettingsCache) GetString(ctx context.Context, key string, defaultValue
 	// Cache miss or expired - fetch from database
 	setting, err := c.service.GetSetting(ctx, key)
 	if err != nil {
+		// Remember unset keys so per-request lookups (e.g. security headers) don't hit the database
+		if errors.Is(err, ErrSettingNotFound) {
+			c.mu.Lock()
+			c.cache[key] = cacheEntry{
+				value:      defaultValue,
+				expiration: time.Now().Add(c.ttl),
+			}
+			c.mu.Unlock()
+		}
 		return defaultValue
 	}
 
@@ -22,10 +22,33 @@ type SystemSetting struct {
 	Description    *string                `json:"description,omitempty"`
 	IsOverridden   bool                   `json:"is_overridden"`
 	OverrideSource string                 `json:"override_source,omitempty"`
+	Metadata       *SettingMetadata       `json:"metadata,omitempty"`
+	Warnings       []SettingWarning       `json:"warnings,omitempty"`
 	CreatedAt      time.Time              `json:"created_at"`
 	UpdatedAt      time.Time              `json:"updated_at"`
 }
 
+// SettingMetadata explains what a setting does and the impact of changing it
+type SettingMetadata struct {
+	Explanation     string `json:"explanation"`
+	RequiresRestart bool   `json:"requires_restart"`
+	AffectsSecurity bool   `json:"affects_security"`
+}
+
+// Severities of setting warnings
+const (
+	SettingWarningSeverityWarning  = "warning"
+	SettingWarningSeverityCritical = "critical"
+)
+
+// SettingWarning describes a conflict between the values of two or more settings
+type SettingWarning struct {
+	Code     string   `json:"code"`
+	Severity string   `json:"severity"`
+	Message  string   `json:"message"`
+	Settings []string `json:"settings"` // Keys of the conflicting settings
+}
+
 // SetupCompleteValue represents the value stored for setup_completed setting
 type SetupCompleteValue struct {
 	Completed       bool       `json:"completed"`
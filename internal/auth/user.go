@@ -51,6 +51,12 @@ type User struct {
 	UpdatedAt           time.Time  `json:"updated_at" db:"updated_at"`
 }
 
+// IsLockedNow reports whether the account is locked, either until an admin or SCIM
+// provisioning unlocks it or until a lock that has not expired yet
+func (u *User) IsLockedNow() bool {
+	return u.IsLocked && (u.LockedUntil == nil || time.Now().Before(*u.LockedUntil))
+}
+
 // CreateUserRequest represents a request to create a new user
 type CreateUserRequest struct {
 	Email        string `json:"email"`
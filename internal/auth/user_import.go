@@ -0,0 +1,462 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// User export formats accepted by ImportUsers
+const (
+	ImportFormatSupabase = "supabase"
+	ImportFormatFirebase = "firebase"
+	ImportFormatAuth0    = "auth0"
+)
+
+// MaxUserImportBatch is the maximum number of users per import request
+const MaxUserImportBatch = 5000
+
+// UserImportRequest is a batch of users from another auth provider's export
+type UserImportRequest struct {
+	Format string            `json:"format"` // "supabase", "firebase" or "auth0"
+	Users  []json.RawMessage `json:"users"`  // Records as they appear in the export
+	// FirebaseHashConfig holds the project's scrypt parameters; required for Firebase users with passwords
+	FirebaseHashConfig *FirebaseScryptParams `json:"firebase_hash_config,omitempty"`
+	// DryRun validates and maps the records without writing anything
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// UserImportError reports why a record was not imported
+type UserImportError struct {
+	Index int    `json:"index"`
+	Email string `json:"email,omitempty"`
+	Error string `json:"error"`
+}
+
+// UserImportResult summarizes an import. Skipped users already existed (same ID or email).
+type UserImportResult struct {
+	Imported int               `json:"imported"`
+	Skipped  int               `json:"skipped"`
+	Failed   int               `json:"failed"`
+	DryRun   bool              `json:"dry_run,omitempty"`
+	Errors   []UserImportError `json:"errors"`
+}
+
+// importedUser is an export record mapped onto auth.users
+type importedUser struct {
+	ID            string
+	Email         string
+	EmailVerified bool
+	PasswordHash  string
+	UserMetadata  map[string]any
+	AppMetadata   map[string]any
+	Identities    []importedIdentity
+	CreatedAt     time.Time
+}
+
+// importedIdentity is a linked social identity mapped onto auth.oauth_links
+type importedIdentity struct {
+	Provider       string
+	ProviderUserID string
+	Email          string
+	Data           map[string]any
+}
+
+// ImportUsers creates app users from another auth provider's export. Password hashes are kept
+// as-is (bcrypt) or tagged with their algorithm, so users sign in with their existing passwords
+// and are re-hashed with bcrypt on first sign-in. Existing users are skipped, never modified.
+func (s *UserManagementService) ImportUsers(ctx context.Context, req UserImportRequest) (*UserImportResult, error) {
+	if len(req.Users) == 0 {
+		return nil, errors.New("users must not be empty")
+	}
+	if len(req.Users) > MaxUserImportBatch {
+		return nil, fmt.Errorf("at most %d users can be imported per request", MaxUserImportBatch)
+	}
+
+	var parse func(json.RawMessage) (*importedUser, error)
+	switch req.Format {
+	case ImportFormatSupabase:
+		parse = parseSupabaseUser
+	case ImportFormatFirebase:
+		if req.FirebaseHashConfig != nil {
+			if err := req.FirebaseHashConfig.Validate(); err != nil {
+				return nil, fmt.Errorf("invalid firebase_hash_config: %w", err)
+			}
+		}
+		parse = func(raw json.RawMessage) (*importedUser, error) {
+			return parseFirebaseUser(raw, req.FirebaseHashConfig)
+		}
+	case ImportFormatAuth0:
+		parse = parseAuth0User
+	default:
+		return nil, fmt.Errorf("unsupported format %q, expected supabase, firebase or auth0", req.Format)
+	}
+
+	result := &UserImportResult{DryRun: req.DryRun, Errors: []UserImportError{}}
+	for i, raw := range req.Users {
+		user, err := parse(raw)
+		if err == nil {
+			err = validateImportedUser(user)
+		}
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, UserImportError{Index: i, Email: emailOf(user), Error: err.Error()})
+			continue
+		}
+
+		if req.DryRun {
+			result.Imported++
+			continue
+		}
+
+		created, err := s.insertImportedUser(ctx, user)
+		switch {
+		case err != nil:
+			result.Failed++
+			result.Errors = append(result.Errors, UserImportError{Index: i, Email: user.Email, Error: err.Error()})
+		case created:
+			result.Imported++
+		default:
+			result.Skipped++
+		}
+	}
+
+	return result, nil
+}
+
+// insertImportedUser inserts the user and its identities in one transaction.
+// It returns false without error when a user with the same ID or email exists.
+func (s *UserManagementService) insertImportedUser(ctx context.Context, user *importedUser) (bool, error) {
+	created := false
+	err := database.WrapWithServiceRole(ctx, s.userRepo.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO auth.users (id, email, password_hash, email_verified, role, user_metadata, app_metadata, created_at, updated_at)
+			VALUES ($1, $2, NULLIF($3, ''), $4, 'authenticated', $5, $6, $7, NOW())
+			ON CONFLICT DO NOTHING
+		`, user.ID, user.Email, user.PasswordHash, user.EmailVerified, user.UserMetadata, user.AppMetadata, user.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert user: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return nil
+		}
+		created = true
+
+		for _, identity := range user.Identities {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO auth.oauth_links (user_id, provider, provider_user_id, email, metadata)
+				VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+				ON CONFLICT (provider, provider_user_id) DO NOTHING
+			`, user.ID, identity.Provider, identity.ProviderUserID, identity.Email, identity.Data)
+			if err != nil {
+				return fmt.Errorf("failed to link %s identity: %w", identity.Provider, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return created, nil
+}
+
+// validateImportedUser checks the fields every format must provide
+func validateImportedUser(user *importedUser) error {
+	if user.Email == "" || !strings.Contains(user.Email, "@") {
+		return errors.New("a valid email is required")
+	}
+	if user.PasswordHash == "" {
+		return nil // social-only users sign in through their identities
+	}
+	switch PasswordHashAlgorithm(user.PasswordHash) {
+	case HashAlgorithmBcrypt:
+		if _, err := bcrypt.Cost([]byte(user.PasswordHash)); err != nil {
+			return fmt.Errorf("invalid bcrypt password hash: %w", err)
+		}
+	case HashAlgorithmFirebaseScrypt:
+		// Validated when encoded
+	default:
+		return ErrUnsupportedPasswordHash
+	}
+	return nil
+}
+
+func emailOf(user *importedUser) string {
+	if user == nil {
+		return ""
+	}
+	return user.Email
+}
+
+// importUserID keeps the source ID when it is a UUID, so references to users in
+// migrated data stay valid, and generates a new one otherwise
+func importUserID(sourceID string) string {
+	if id, err := uuid.Parse(sourceID); err == nil {
+		return id.String()
+	}
+	return uuid.New().String()
+}
+
+// importAppMetadata records where the user was imported from
+func importAppMetadata(base map[string]any, format, sourceID string) map[string]any {
+	metadata := map[string]any{}
+	for k, v := range base {
+		metadata[k] = v
+	}
+	metadata["imported_from"] = map[string]any{"provider": format, "id": sourceID}
+	return metadata
+}
+
+// setIfNotEmpty sets metadata[key] unless value is empty
+func setIfNotEmpty(metadata map[string]any, key, value string) {
+	if value != "" {
+		metadata[key] = value
+	}
+}
+
+// parseSupabaseUser maps a row of Supabase's auth.users (with an optional "identities"
+// array of auth.identities rows), e.g. exported with json_agg
+func parseSupabaseUser(raw json.RawMessage) (*importedUser, error) {
+	var rec struct {
+		ID                string         `json:"id"`
+		Email             string         `json:"email"`
+		EncryptedPassword string         `json:"encrypted_password"`
+		EmailConfirmedAt  *time.Time     `json:"email_confirmed_at"`
+		RawUserMetaData   map[string]any `json:"raw_user_meta_data"`
+		RawAppMetaData    map[string]any `json:"raw_app_meta_data"`
+		CreatedAt         *time.Time     `json:"created_at"`
+		Identities        []struct {
+			Provider     string         `json:"provider"`
+			ProviderID   string         `json:"provider_id"`
+			ID           string         `json:"id"`
+			Email        string         `json:"email"`
+			IdentityData map[string]any `json:"identity_data"`
+		} `json:"identities"`
+	}
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return nil, fmt.Errorf("invalid supabase user record: %w", err)
+	}
+
+	user := &importedUser{
+		ID:            importUserID(rec.ID),
+		Email:         strings.TrimSpace(rec.Email),
+		EmailVerified: rec.EmailConfirmedAt != nil,
+		PasswordHash:  rec.EncryptedPassword,
+		UserMetadata:  map[string]any{},
+		AppMetadata:   importAppMetadata(rec.RawAppMetaData, ImportFormatSupabase, rec.ID),
+		CreatedAt:     timeOrNow(rec.CreatedAt),
+	}
+	for k, v := range rec.RawUserMetaData {
+		user.UserMetadata[k] = v
+	}
+	for _, identity := range rec.Identities {
+		// Email/password and phone identities are covered by the user row itself
+		if identity.Provider == "email" || identity.Provider == "phone" {
+			continue
+		}
+		providerUserID := identity.ProviderID
+		if providerUserID == "" {
+			providerUserID = identity.ID // provider_id was added in later Supabase versions
+		}
+		if providerUserID == "" {
+			continue
+		}
+		user.Identities = append(user.Identities, importedIdentity{
+			Provider:       identity.Provider,
+			ProviderUserID: providerUserID,
+			Email:          identity.Email,
+			Data:           identity.IdentityData,
+		})
+	}
+	return user, nil
+}
+
+// firebaseProviders maps Firebase provider IDs to Fluxbase OAuth provider names
+var firebaseProviders = map[string]string{
+	"google.com":    "google",
+	"github.com":    "github",
+	"apple.com":     "apple",
+	"facebook.com":  "facebook",
+	"twitter.com":   "twitter",
+	"microsoft.com": "microsoft",
+	"gitlab.com":    "gitlab",
+}
+
+// parseFirebaseUser maps a record of `firebase auth:export --format=json`
+func parseFirebaseUser(raw json.RawMessage, params *FirebaseScryptParams) (*importedUser, error) {
+	var rec struct {
+		LocalID          string `json:"localId"`
+		Email            string `json:"email"`
+		EmailVerified    bool   `json:"emailVerified"`
+		PasswordHash     string `json:"passwordHash"`
+		Salt             string `json:"salt"`
+		DisplayName      string `json:"displayName"`
+		PhotoURL         string `json:"photoUrl"`
+		CreatedAt        string `json:"createdAt"` // milliseconds since epoch
+		CustomAttributes string `json:"customAttributes"`
+		ProviderUserInfo []struct {
+			ProviderID  string `json:"providerId"`
+			RawID       string `json:"rawId"`
+			Email       string `json:"email"`
+			DisplayName string `json:"displayName"`
+			PhotoURL    string `json:"photoUrl"`
+		} `json:"providerUserInfo"`
+	}
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return nil, fmt.Errorf("invalid firebase user record: %w", err)
+	}
+
+	user := &importedUser{
+		ID:            importUserID(rec.LocalID),
+		Email:         strings.TrimSpace(rec.Email),
+		EmailVerified: rec.EmailVerified,
+		UserMetadata:  map[string]any{},
+		CreatedAt:     time.Now(),
+	}
+	setIfNotEmpty(user.UserMetadata, "full_name", rec.DisplayName)
+	setIfNotEmpty(user.UserMetadata, "avatar_url", rec.PhotoURL)
+
+	// Custom claims become app metadata, as both are admin-controlled and included in tokens
+	var claims map[string]any
+	if rec.CustomAttributes != "" {
+		if err := json.Unmarshal([]byte(rec.CustomAttributes), &claims); err != nil {
+			return user, fmt.Errorf("invalid customAttributes: %w", err)
+		}
+	}
+	user.AppMetadata = importAppMetadata(claims, ImportFormatFirebase, rec.LocalID)
+
+	if ms, err := strconv.ParseInt(rec.CreatedAt, 10, 64); err == nil {
+		user.CreatedAt = time.UnixMilli(ms)
+	}
+
+	if rec.PasswordHash != "" {
+		if params == nil {
+			return user, errors.New("firebase_hash_config is required to import password hashes")
+		}
+		hash, err := EncodeFirebaseScryptHash(*params, rec.Salt, rec.PasswordHash)
+		if err != nil {
+			return user, err
+		}
+		user.PasswordHash = hash
+	}
+
+	for _, info := range rec.ProviderUserInfo {
+		provider, ok := firebaseProviders[info.ProviderID]
+		if !ok || info.RawID == "" {
+			continue // "password", "phone" and unknown providers
+		}
+		data := map[string]any{}
+		setIfNotEmpty(data, "name", info.DisplayName)
+		setIfNotEmpty(data, "avatar_url", info.PhotoURL)
+		user.Identities = append(user.Identities, importedIdentity{
+			Provider:       provider,
+			ProviderUserID: info.RawID,
+			Email:          info.Email,
+			Data:           data,
+		})
+	}
+	return user, nil
+}
+
+// auth0Providers maps Auth0 identity providers to Fluxbase OAuth provider names
+var auth0Providers = map[string]string{
+	"google-oauth2": "google",
+	"github":        "github",
+	"apple":         "apple",
+	"facebook":      "facebook",
+	"twitter":       "twitter",
+	"windowslive":   "microsoft",
+	"gitlab":        "gitlab",
+	"linkedin":      "linkedin",
+}
+
+// parseAuth0User maps a record of an Auth0 user export. Password hashes are only part of
+// exports requested from Auth0 support ("passwordHash") or of Auth0's own import format
+// ("custom_password_hash" with a bcrypt hash).
+func parseAuth0User(raw json.RawMessage) (*importedUser, error) {
+	var rec struct {
+		UserID             string         `json:"user_id"`
+		Email              string         `json:"email"`
+		EmailVerified      bool           `json:"email_verified"`
+		Name               string         `json:"name"`
+		Picture            string         `json:"picture"`
+		UserMetadata       map[string]any `json:"user_metadata"`
+		AppMetadata        map[string]any `json:"app_metadata"`
+		CreatedAt          *time.Time     `json:"created_at"`
+		PasswordHash       string         `json:"passwordHash"`
+		PasswordHashSnake  string         `json:"password_hash"`
+		CustomPasswordHash *struct {
+			Algorithm string `json:"algorithm"`
+			Hash      struct {
+				Value string `json:"value"`
+			} `json:"hash"`
+		} `json:"custom_password_hash"`
+		Identities []struct {
+			Provider string `json:"provider"`
+			UserID   any    `json:"user_id"` // numeric for some social providers
+			IsSocial bool   `json:"isSocial"`
+		} `json:"identities"`
+	}
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return nil, fmt.Errorf("invalid auth0 user record: %w", err)
+	}
+
+	user := &importedUser{
+		ID:            importUserID(rec.UserID),
+		Email:         strings.TrimSpace(rec.Email),
+		EmailVerified: rec.EmailVerified,
+		UserMetadata:  map[string]any{},
+		AppMetadata:   importAppMetadata(rec.AppMetadata, ImportFormatAuth0, rec.UserID),
+		CreatedAt:     timeOrNow(rec.CreatedAt),
+	}
+	for k, v := range rec.UserMetadata {
+		user.UserMetadata[k] = v
+	}
+	setIfNotEmpty(user.UserMetadata, "full_name", rec.Name)
+	setIfNotEmpty(user.UserMetadata, "avatar_url", rec.Picture)
+
+	switch {
+	case rec.PasswordHash != "":
+		user.PasswordHash = rec.PasswordHash
+	case rec.PasswordHashSnake != "":
+		user.PasswordHash = rec.PasswordHashSnake
+	case rec.CustomPasswordHash != nil:
+		if rec.CustomPasswordHash.Algorithm != "bcrypt" {
+			return user, fmt.Errorf("unsupported custom_password_hash algorithm %q, only bcrypt is supported", rec.CustomPasswordHash.Algorithm)
+		}
+		user.PasswordHash = rec.CustomPasswordHash.Hash.Value
+	}
+
+	for _, identity := range rec.Identities {
+		provider, ok := auth0Providers[identity.Provider]
+		if !ok || !identity.IsSocial {
+			continue // database and enterprise connections
+		}
+		providerUserID := fmt.Sprint(identity.UserID)
+		if providerUserID == "" || providerUserID == "<nil>" {
+			continue
+		}
+		user.Identities = append(user.Identities, importedIdentity{
+			Provider:       provider,
+			ProviderUserID: providerUserID,
+			Email:          user.Email,
+		})
+	}
+	return user, nil
+}
+
+func timeOrNow(t *time.Time) time.Time {
+	if t == nil || t.IsZero() {
+		return time.Now()
+	}
+	return *t
+}
@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBcryptHash = "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"
+
+func TestParseSupabaseUser(t *testing.T) {
+	raw := json.RawMessage(`{
+		"id": "6f1d3e0a-1b2c-4d5e-8f90-123456789abc",
+		"email": " jane@example.com ",
+		"encrypted_password": "` + testBcryptHash + `",
+		"email_confirmed_at": "2024-01-02T03:04:05.123456+00:00",
+		"raw_user_meta_data": {"full_name": "Jane"},
+		"raw_app_meta_data": {"provider": "email", "plan": "pro"},
+		"created_at": "2023-05-01T10:00:00+00:00",
+		"identities": [
+			{"provider": "email", "provider_id": "6f1d3e0a-1b2c-4d5e-8f90-123456789abc"},
+			{"provider": "github", "provider_id": "583231", "identity_data": {"user_name": "jane"}},
+			{"provider": "google", "id": "10769150350006150715113082367"}
+		]
+	}`)
+
+	user, err := parseSupabaseUser(raw)
+	require.NoError(t, err)
+	require.NoError(t, validateImportedUser(user))
+
+	assert.Equal(t, "6f1d3e0a-1b2c-4d5e-8f90-123456789abc", user.ID, "UUIDs are preserved")
+	assert.Equal(t, "jane@example.com", user.Email)
+	assert.True(t, user.EmailVerified)
+	assert.Equal(t, testBcryptHash, user.PasswordHash, "bcrypt hashes are kept as-is")
+	assert.Equal(t, "Jane", user.UserMetadata["full_name"])
+	assert.Equal(t, "pro", user.AppMetadata["plan"])
+	assert.Equal(t, map[string]any{"provider": "supabase", "id": "6f1d3e0a-1b2c-4d5e-8f90-123456789abc"}, user.AppMetadata["imported_from"])
+	assert.Equal(t, time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC), user.CreatedAt.UTC())
+
+	require.Len(t, user.Identities, 2)
+	assert.Equal(t, "github", user.Identities[0].Provider)
+	assert.Equal(t, "583231", user.Identities[0].ProviderUserID)
+	assert.Equal(t, "google", user.Identities[1].Provider)
+	assert.Equal(t, "10769150350006150715113082367", user.Identities[1].ProviderUserID)
+}
+
+func TestParseFirebaseUser(t *testing.T) {
+	raw := json.RawMessage(`{
+		"localId": "Ab3xYz9QwErTy",
+		"email": "sam@example.com",
+		"emailVerified": true,
+		"passwordHash": "` + firebaseTestHash + `",
+		"salt": "` + firebaseTestSalt + `",
+		"displayName": "Sam",
+		"photoUrl": "https://example.com/sam.png",
+		"createdAt": "1700000000000",
+		"customAttributes": "{\"admin\":true}",
+		"providerUserInfo": [
+			{"providerId": "password", "rawId": "sam@example.com"},
+			{"providerId": "google.com", "rawId": "1234567890", "email": "sam@gmail.com", "displayName": "Sam G"}
+		]
+	}`)
+
+	t.Run("maps record and tags the scrypt hash", func(t *testing.T) {
+		user, err := parseFirebaseUser(raw, &firebaseTestParams)
+		require.NoError(t, err)
+		require.NoError(t, validateImportedUser(user))
+
+		assert.NotEqual(t, "Ab3xYz9QwErTy", user.ID, "non-UUID IDs are replaced")
+		assert.Equal(t, HashAlgorithmFirebaseScrypt, PasswordHashAlgorithm(user.PasswordHash))
+		assert.NoError(t, NewPasswordHasher().ComparePassword(user.PasswordHash, firebaseTestPassword))
+		assert.Equal(t, "Sam", user.UserMetadata["full_name"])
+		assert.Equal(t, "https://example.com/sam.png", user.UserMetadata["avatar_url"])
+		assert.Equal(t, true, user.AppMetadata["admin"])
+		assert.Equal(t, map[string]any{"provider": "firebase", "id": "Ab3xYz9QwErTy"}, user.AppMetadata["imported_from"])
+		assert.Equal(t, time.UnixMilli(1700000000000), user.CreatedAt)
+
+		require.Len(t, user.Identities, 1)
+		assert.Equal(t, "google", user.Identities[0].Provider)
+		assert.Equal(t, "1234567890", user.Identities[0].ProviderUserID)
+		assert.Equal(t, "sam@gmail.com", user.Identities[0].Email)
+	})
+
+	t.Run("requires hash config for password users", func(t *testing.T) {
+		user, err := parseFirebaseUser(raw, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "firebase_hash_config")
+		assert.Equal(t, "sam@example.com", emailOf(user))
+	})
+}
+
+func TestParseAuth0User(t *testing.T) {
+	t.Run("social user with metadata", func(t *testing.T) {
+		raw := json.RawMessage(`{
+			"user_id": "google-oauth2|1234567890",
+			"email": "alex@example.com",
+			"email_verified": true,
+			"name": "Alex",
+			"picture": "https://example.com/alex.png",
+			"user_metadata": {"theme": "dark"},
+			"app_metadata": {"roles": ["editor"]},
+			"created_at": "2022-02-03T04:05:06.000Z",
+			"identities": [
+				{"provider": "google-oauth2", "user_id": "1234567890", "connection": "google-oauth2", "isSocial": true},
+				{"provider": "github", "user_id": 98765, "connection": "github", "isSocial": true},
+				{"provider": "samlp", "user_id": "alex", "connection": "corp", "isSocial": false}
+			]
+		}`)
+
+		user, err := parseAuth0User(raw)
+		require.NoError(t, err)
+		require.NoError(t, validateImportedUser(user))
+
+		assert.Empty(t, user.PasswordHash)
+		assert.Equal(t, "dark", user.UserMetadata["theme"])
+		assert.Equal(t, "Alex", user.UserMetadata["full_name"])
+		assert.Equal(t, []any{"editor"}, user.AppMetadata["roles"])
+		require.Len(t, user.Identities, 2)
+		assert.Equal(t, "google", user.Identities[0].Provider)
+		assert.Equal(t, "github", user.Identities[1].Provider)
+		assert.Equal(t, "98765", user.Identities[1].ProviderUserID)
+	})
+
+	t.Run("database user with bcrypt hash", func(t *testing.T) {
+		user, err := parseAuth0User(json.RawMessage(`{"user_id": "auth0|5f7c8ec7c33c6c004bbafe82", "email": "db@example.com", "passwordHash": "` + testBcryptHash + `"}`))
+		require.NoError(t, err)
+		assert.Equal(t, testBcryptHash, user.PasswordHash)
+	})
+
+	t.Run("custom password hash must be bcrypt", func(t *testing.T) {
+		_, err := parseAuth0User(json.RawMessage(`{"email": "md5@example.com", "custom_password_hash": {"algorithm": "md5", "hash": {"value": "abc"}}}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only bcrypt")
+	})
+}
+
+func TestValidateImportedUser(t *testing.T) {
+	assert.NoError(t, validateImportedUser(&importedUser{Email: "social@example.com"}))
+	assert.Error(t, validateImportedUser(&importedUser{Email: ""}))
+	assert.Error(t, validateImportedUser(&importedUser{Email: "not-an-email"}))
+	assert.Error(t, validateImportedUser(&importedUser{Email: "a@example.com", PasswordHash: "$2a$10$short"}))
+	assert.ErrorIs(t, validateImportedUser(&importedUser{Email: "a@example.com", PasswordHash: "5f4dcc3b5aa765d61d8327deb882cf99"}), ErrUnsupportedPasswordHash)
+}
+
+func TestImportUsers_DryRun(t *testing.T) {
+	svc := &UserManagementService{}
+	ctx := context.Background()
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		_, err := svc.ImportUsers(ctx, UserImportRequest{Format: "supabase"})
+		assert.Error(t, err)
+
+		_, err = svc.ImportUsers(ctx, UserImportRequest{Format: "cognito", Users: []json.RawMessage{json.RawMessage(`{}`)}})
+		assert.ErrorContains(t, err, "unsupported format")
+
+		params := firebaseTestParams
+		params.Rounds = 0
+		_, err = svc.ImportUsers(ctx, UserImportRequest{Format: "firebase", Users: []json.RawMessage{json.RawMessage(`{}`)}, FirebaseHashConfig: &params})
+		assert.ErrorContains(t, err, "firebase_hash_config")
+	})
+
+	t.Run("reports per-record errors without writing", func(t *testing.T) {
+		result, err := svc.ImportUsers(ctx, UserImportRequest{
+			Format: "supabase",
+			DryRun: true,
+			Users: []json.RawMessage{
+				json.RawMessage(`{"id": "6f1d3e0a-1b2c-4d5e-8f90-123456789abc", "email": "ok@example.com", "encrypted_password": "` + testBcryptHash + `"}`),
+				json.RawMessage(`{"id": "x", "phone": "+15555550100"}`),
+				json.RawMessage(`"not an object"`),
+			},
+		})
+		require.NoError(t, err)
+		assert.True(t, result.DryRun)
+		assert.Equal(t, 1, result.Imported)
+		assert.Equal(t, 2, result.Failed)
+		require.Len(t, result.Errors, 2)
+		assert.Equal(t, 1, result.Errors[0].Index)
+		assert.Equal(t, 2, result.Errors[1].Index)
+	})
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -21,6 +24,7 @@ type EnrichedUser struct {
 	ActiveSessions int                    `json:"active_sessions"`
 	LastSignIn     *time.Time             `json:"last_sign_in"`
 	IsLocked       bool                   `json:"is_locked"`
+	LockedUntil    *time.Time             `json:"locked_until,omitempty"` // Unset while locked until an admin unlocks
 	UserMetadata   map[string]interface{} `json:"user_metadata"`
 	AppMetadata    map[string]interface{} `json:"app_metadata"`
 	CreatedAt      time.Time              `json:"created_at"`
@@ -53,23 +57,34 @@ func NewUserManagementService(
 	}
 }
 
-// ListEnrichedUsers returns a list of users with enriched metadata
-// userType can be "app" for auth.users or "dashboard" for dashboard.users
-func (s *UserManagementService) ListEnrichedUsers(ctx context.Context, userType string) ([]*EnrichedUser, error) {
-	// Default to app users if not specified
-	if userType == "" {
-		userType = "app"
-	}
+// ListUsersOptions filters and paginates ListUsers
+type ListUsersOptions struct {
+	UserType      string                 // "app" for auth.users (default) or "dashboard" for dashboard.users
+	Search        string                 // Case-insensitive part of the email, or a user ID
+	Metadata      map[string]interface{} // Keys and values that user_metadata or app_metadata must contain
+	ExcludeAdmins bool
+	Banned        *bool // Only users that are, or are not, locked out right now
+	Limit         int   // 0 returns all matching users
+	Offset        int
+}
 
-	// Determine which table to query
-	usersTable := "auth.users"
-	sessionsTable := "auth.sessions"
+// userTables returns the users and sessions tables of a user type
+func userTables(userType string) (usersTable, sessionsTable string) {
 	if userType == "dashboard" {
-		usersTable = "dashboard.users"
-		sessionsTable = "dashboard.sessions"
+		return "dashboard.users", "dashboard.sessions"
 	}
+	return "auth.users", "auth.sessions"
+}
 
-	query := fmt.Sprintf(`
+// lockedNowSQL is true for users locked out right now: locked until an admin unlocks them, or
+// until a locked_until that has not passed yet
+const lockedNowSQL = `(COALESCE(u.is_locked, false) AND (u.locked_until IS NULL OR u.locked_until > NOW()))`
+
+// enrichedUserQuery selects EnrichedUser columns from the users of a type, aliased as u, to be
+// completed with WHERE and ORDER BY clauses
+func enrichedUserQuery(userType string) string {
+	usersTable, sessionsTable := userTables(userType)
+	return fmt.Sprintf(`
 		SELECT
 			u.id,
 			u.email,
@@ -79,44 +94,114 @@ func (s *UserManagementService) ListEnrichedUsers(ctx context.Context, userType
 			u.app_metadata,
 			u.created_at,
 			u.updated_at,
-			COALESCE(COUNT(DISTINCT CASE WHEN s.expires_at > NOW() THEN s.id END), 0) as active_sessions,
-			MAX(s.created_at) as last_sign_in,
+			s.active_sessions,
+			s.last_sign_in,
 			CASE
 				WHEN u.password_hash IS NOT NULL THEN 'email'
 				WHEN u.email_verified = false THEN 'invite_pending'
 				ELSE 'email'
 			END as provider,
-			COALESCE(u.is_locked, false) as is_locked
-		FROM %s u
-		LEFT JOIN %s s ON u.id = s.user_id
-		GROUP BY u.id, u.email, u.email_verified, u.role, u.user_metadata, u.app_metadata, u.created_at, u.updated_at, u.password_hash, u.is_locked
-		ORDER BY u.created_at DESC
-	`, usersTable, sessionsTable)
-
-	var users []*EnrichedUser
-	err := database.WrapWithServiceRole(ctx, s.userRepo.db, func(tx pgx.Tx) error {
-		rows, err := tx.Query(ctx, query)
+			%[3]s as is_locked,
+			CASE WHEN %[3]s THEN u.locked_until END as locked_until
+		FROM %[1]s u
+		LEFT JOIN LATERAL (
+			SELECT
+				COUNT(*) FILTER (WHERE expires_at > NOW()) as active_sessions,
+				MAX(created_at) as last_sign_in
+			FROM %[2]s
+			WHERE user_id = u.id
+		) s ON true
+	`, usersTable, sessionsTable, lockedNowSQL)
+}
+
+// scanEnrichedUser scans a row selected by enrichedUserQuery
+func scanEnrichedUser(row pgx.Row) (*EnrichedUser, error) {
+	user := &EnrichedUser{}
+	err := row.Scan(
+		&user.ID,
+		&user.Email,
+		&user.EmailVerified,
+		&user.Role,
+		&user.UserMetadata,
+		&user.AppMetadata,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.ActiveSessions,
+		&user.LastSignIn,
+		&user.Provider,
+		&user.IsLocked,
+		&user.LockedUntil,
+	)
+	return user, err
+}
+
+// listUsersFilter returns the WHERE clause and arguments of the filters of opts
+func listUsersFilter(opts ListUsersOptions) (string, []interface{}, error) {
+	var conditions []string
+	var args []interface{}
+
+	if opts.Search != "" {
+		args = append(args, opts.Search)
+		conditions = append(conditions, fmt.Sprintf("(strpos(lower(u.email), lower($%[1]d)) > 0 OR u.id::text = lower($%[1]d))", len(args)))
+	}
+	if len(opts.Metadata) > 0 {
+		metadata, err := json.Marshal(opts.Metadata)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid metadata filter: %w", err)
+		}
+		args = append(args, string(metadata))
+		conditions = append(conditions, fmt.Sprintf("(u.user_metadata @> $%[1]d::jsonb OR u.app_metadata @> $%[1]d::jsonb)", len(args)))
+	}
+	if opts.ExcludeAdmins {
+		conditions = append(conditions, "u.role <> 'admin'")
+	}
+	if opts.Banned != nil {
+		if *opts.Banned {
+			conditions = append(conditions, lockedNowSQL)
+		} else {
+			conditions = append(conditions, "NOT "+lockedNowSQL)
+		}
+	}
+
+	if len(conditions) == 0 {
+		return "", args, nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args, nil
+}
+
+// ListUsers returns a page of the users matching opts with enriched metadata, newest first,
+// and the number of users matching opts. Filtering and pagination happen in the database.
+func (s *UserManagementService) ListUsers(ctx context.Context, opts ListUsersOptions) ([]*EnrichedUser, int, error) {
+	where, args, err := listUsersFilter(opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	usersTable, _ := userTables(opts.UserType)
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM %s u %s`, usersTable, where)
+
+	query := enrichedUserQuery(opts.UserType) + where + " ORDER BY u.created_at DESC, u.id"
+	pageArgs := args
+	if opts.Limit > 0 {
+		pageArgs = append(append([]interface{}{}, args...), opts.Limit, opts.Offset)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(pageArgs)-1, len(pageArgs))
+	}
+
+	users := []*EnrichedUser{}
+	var total int
+	err = database.WrapWithServiceRole(ctx, s.userRepo.db, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+			return fmt.Errorf("failed to count users: %w", err)
+		}
+
+		rows, err := tx.Query(ctx, query, pageArgs...)
 		if err != nil {
 			return fmt.Errorf("failed to query enriched users: %w", err)
 		}
 		defer rows.Close()
 
 		for rows.Next() {
-			user := &EnrichedUser{}
-			err := rows.Scan(
-				&user.ID,
-				&user.Email,
-				&user.EmailVerified,
-				&user.Role,
-				&user.UserMetadata,
-				&user.AppMetadata,
-				&user.CreatedAt,
-				&user.UpdatedAt,
-				&user.ActiveSessions,
-				&user.LastSignIn,
-				&user.Provider,
-				&user.IsLocked,
-			)
+			user, err := scanEnrichedUser(rows)
 			if err != nil {
 				return fmt.Errorf("failed to scan enriched user: %w", err)
 			}
@@ -126,71 +211,32 @@ func (s *UserManagementService) ListEnrichedUsers(ctx context.Context, userType
 		return rows.Err()
 	})
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	return users, nil
+	return users, total, nil
+}
+
+// ListEnrichedUsers returns a list of users with enriched metadata
+// userType can be "app" for auth.users or "dashboard" for dashboard.users
+func (s *UserManagementService) ListEnrichedUsers(ctx context.Context, userType string) ([]*EnrichedUser, error) {
+	users, _, err := s.ListUsers(ctx, ListUsersOptions{UserType: userType})
+	return users, err
 }
 
 // GetEnrichedUserByID returns a single user with enriched metadata
 // userType can be "app" for auth.users or "dashboard" for dashboard.users
 func (s *UserManagementService) GetEnrichedUserByID(ctx context.Context, userID string, userType string) (*EnrichedUser, error) {
-	// Default to app users if not specified
-	if userType == "" {
-		userType = "app"
-	}
-
-	// Determine which table to query
-	usersTable := "auth.users"
-	sessionsTable := "auth.sessions"
-	if userType == "dashboard" {
-		usersTable = "dashboard.users"
-		sessionsTable = "dashboard.sessions"
-	}
-
-	query := fmt.Sprintf(`
-		SELECT
-			u.id,
-			u.email,
-			u.email_verified,
-			u.role,
-			u.user_metadata,
-			u.app_metadata,
-			u.created_at,
-			u.updated_at,
-			COALESCE(COUNT(DISTINCT CASE WHEN s.expires_at > NOW() THEN s.id END), 0) as active_sessions,
-			MAX(s.created_at) as last_sign_in,
-			CASE
-				WHEN u.password_hash IS NOT NULL THEN 'email'
-				WHEN u.email_verified = false THEN 'invite_pending'
-				ELSE 'email'
-			END as provider,
-			COALESCE(u.is_locked, false) as is_locked
-		FROM %s u
-		LEFT JOIN %s s ON u.id = s.user_id
-		WHERE u.id = $1
-		GROUP BY u.id, u.email, u.email_verified, u.role, u.user_metadata, u.app_metadata, u.created_at, u.updated_at, u.password_hash, u.is_locked
-	`, usersTable, sessionsTable)
+	query := enrichedUserQuery(userType) + "WHERE u.id = $1"
 
-	user := &EnrichedUser{}
+	var user *EnrichedUser
 	err := database.WrapWithServiceRole(ctx, s.userRepo.db, func(tx pgx.Tx) error {
-		return tx.QueryRow(ctx, query, userID).Scan(
-			&user.ID,
-			&user.Email,
-			&user.EmailVerified,
-			&user.Role,
-			&user.UserMetadata,
-			&user.AppMetadata,
-			&user.CreatedAt,
-			&user.UpdatedAt,
-			&user.ActiveSessions,
-			&user.LastSignIn,
-			&user.Provider,
-			&user.IsLocked,
-		)
+		var err error
+		user, err = scanEnrichedUser(tx.QueryRow(ctx, query, userID))
+		return err
 	})
 	if err != nil {
-		if err.Error() == "no rows in result set" {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to query enriched user: %w", err)
@@ -310,9 +356,39 @@ func (s *UserManagementService) UpdateUserRole(ctx context.Context, userID strin
 	return s.userRepo.UpdateInTable(ctx, userID, req, userType)
 }
 
-// DeleteUser deletes a user (cascades to sessions, tokens, etc.)
-func (s *UserManagementService) DeleteUser(ctx context.Context, userID string, userType string) error {
-	return s.userRepo.DeleteFromTable(ctx, userID, userType)
+// DeleteUser permanently deletes a user in one transaction and returns it. Sessions,
+// identities, MFA factors and recovery codes, and the other rows owned by the user are removed
+// with it by their foreign keys; magic links and OTP codes, which are keyed by email, are
+// deleted explicitly.
+func (s *UserManagementService) DeleteUser(ctx context.Context, userID string, userType string) (*User, error) {
+	usersTable, _ := userTables(userType)
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1 RETURNING id, email, role`, usersTable)
+
+	user := &User{}
+	err := database.WrapWithServiceRole(ctx, s.userRepo.db, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, query, userID).Scan(&user.ID, &user.Email, &user.Role); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+		if userType == "dashboard" {
+			return nil
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM auth.magic_links WHERE lower(email) = lower($1)`, user.Email); err != nil {
+			return fmt.Errorf("failed to delete magic links: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM auth.otp_codes WHERE lower(email) = lower($1)`, user.Email); err != nil {
+			return fmt.Errorf("failed to delete OTP codes: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
 }
 
 // ResetUserPassword triggers a password reset for a user
@@ -351,27 +427,24 @@ func (s *UserManagementService) ResetUserPassword(ctx context.Context, userID st
 	return tempPassword, nil
 }
 
-// LockUser locks a user account
+// LockUser locks a user account until an admin unlocks it
 func (s *UserManagementService) LockUser(ctx context.Context, userID string, userType string) error {
 	return s.setUserLockStatus(ctx, userID, userType, true)
 }
 
-// UnlockUser unlocks a user account
+// UnlockUser unlocks a user account, lifting bans and automatic lockouts
 func (s *UserManagementService) UnlockUser(ctx context.Context, userID string, userType string) error {
 	return s.setUserLockStatus(ctx, userID, userType, false)
 }
 
-// setUserLockStatus sets the lock status for a user
+// setUserLockStatus sets the lock status for a user. Both clear locked_until, so a lock lasts
+// until the user is unlocked and an unlock is not undone by an earlier expiry.
 func (s *UserManagementService) setUserLockStatus(ctx context.Context, userID string, userType string, locked bool) error {
-	// Determine which table to update
-	usersTable := "auth.users"
-	if userType == "dashboard" {
-		usersTable = "dashboard.users"
-	}
+	usersTable, _ := userTables(userType)
 
 	query := fmt.Sprintf(`
 		UPDATE %s
-		SET is_locked = $1, failed_login_attempts = CASE WHEN $1 = false THEN 0 ELSE failed_login_attempts END, updated_at = NOW()
+		SET is_locked = $1, locked_until = NULL, failed_login_attempts = CASE WHEN $1 = false THEN 0 ELSE failed_login_attempts END, updated_at = NOW()
 		WHERE id = $2
 	`, usersTable)
 
@@ -389,6 +462,106 @@ func (s *UserManagementService) setUserLockStatus(ctx context.Context, userID st
 	return err
 }
 
+// BanUser locks a user out and signs them out of all their sessions. The ban lasts until the
+// user is unbanned with UnlockUser, or until until when it is set. Access tokens issued before
+// the ban stay valid until they expire but can no longer be refreshed.
+func (s *UserManagementService) BanUser(ctx context.Context, userID string, userType string, until *time.Time) error {
+	usersTable, sessionsTable := userTables(userType)
+
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET is_locked = true, locked_until = $2, updated_at = NOW()
+		WHERE id = $1
+	`, usersTable)
+
+	return database.WrapWithServiceRole(ctx, s.userRepo.db, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, query, userID, until)
+		if err != nil {
+			return fmt.Errorf("failed to ban user: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return ErrUserNotFound
+		}
+		return deleteUserSessions(ctx, tx, sessionsTable, userID)
+	})
+}
+
+// ForcePasswordReset replaces the password of a user with a random one nobody knows and signs
+// them out of all their sessions, so they have to set a new password through a password reset
+// before they can sign in with one again. It returns the user, to send the reset email to.
+func (s *UserManagementService) ForcePasswordReset(ctx context.Context, userID string, userType string) (*User, error) {
+	password, err := generateSecurePassword(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate password: %w", err)
+	}
+	hashedPassword, err := s.passwordHasher.HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	usersTable, sessionsTable := userTables(userType)
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET password_hash = $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING id, email, role
+	`, usersTable)
+
+	user := &User{}
+	err = database.WrapWithServiceRole(ctx, s.userRepo.db, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, query, userID, hashedPassword).Scan(&user.ID, &user.Email, &user.Role); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("failed to reset password: %w", err)
+		}
+		return deleteUserSessions(ctx, tx, sessionsTable, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// VerifyUserEmail marks the email of a user as verified, e.g. after confirming it outside
+// Fluxbase, and discards the user's pending email verification tokens
+func (s *UserManagementService) VerifyUserEmail(ctx context.Context, userID string, userType string) (*EnrichedUser, error) {
+	usersTable, _ := userTables(userType)
+	query := fmt.Sprintf(`UPDATE %s SET email_verified = true, updated_at = NOW() WHERE id = $1`, usersTable)
+
+	err := database.WrapWithServiceRole(ctx, s.userRepo.db, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, query, userID)
+		if err != nil {
+			return fmt.Errorf("failed to verify email: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return ErrUserNotFound
+		}
+		if userType == "dashboard" {
+			return nil
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM auth.email_verification_tokens WHERE user_id = $1`, userID); err != nil {
+			return fmt.Errorf("failed to delete email verification tokens: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetEnrichedUserByID(ctx, userID, userType)
+}
+
+// deleteUserSessions deletes all sessions of a user, signing them out everywhere
+func deleteUserSessions(ctx context.Context, tx pgx.Tx, sessionsTable string, userID string) error {
+	if _, err := tx.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE user_id = $1`, sessionsTable), userID); err != nil {
+		return fmt.Errorf("failed to delete sessions: %w", err)
+	}
+	return nil
+}
+
 // UpdateUserRequest for admin user updates
 type UpdateAdminUserRequest struct {
 	Email        *string                `json:"email,omitempty"`
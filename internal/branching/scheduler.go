@@ -45,6 +45,10 @@ func (s *CleanupScheduler) Start() {
 		return
 	}
 	s.running = true
+	// A stopped service gets a fresh context so it can be restarted (e.g. on regaining leadership)
+	if s.ctx.Err() != nil {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+	}
 	s.mu.Unlock()
 
 	s.wg.Add(1)
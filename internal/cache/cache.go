@@ -0,0 +1,52 @@
+// Package cache provides pluggable cache and queue backends shared by components that
+// need to scale across instances, such as embedding caches and job queue wake-ups.
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrQueueFull is returned by in-memory queues when a queue has reached its capacity
+var ErrQueueFull = errors.New("queue is full")
+
+// Cache is the interface for key/value cache backends.
+// It supports different backends for different deployment scenarios:
+// - Memory: Single instance deployments (fastest, no external dependencies)
+// - PostgreSQL: Multi-instance deployments without additional infrastructure
+// - Redis: High-scale deployments (works with Dragonfly, Redis, Valkey, KeyDB)
+type Cache interface {
+	// Get retrieves the value for a key.
+	// Returns false if the key doesn't exist or has expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores a value for a key. A ttl of zero or less keeps the value until it is deleted.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete removes a key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// Close closes the cache and releases resources.
+	Close() error
+}
+
+// Queue is the interface for FIFO message queue backends.
+// Messages are delivered to exactly one consumer at most once; consumers that need
+// durability should keep the source of truth elsewhere and use the queue for signaling.
+// Backends:
+// - Memory: Single instance deployments
+// - PostgreSQL: Multi-instance deployments without additional infrastructure
+// - Redis: High-scale deployments (lists with blocking pops)
+// - NATS: High-scale deployments (queue groups)
+type Queue interface {
+	// Push appends a message to the named queue.
+	Push(ctx context.Context, queue string, payload []byte) error
+
+	// Pop removes and returns the oldest message from the named queue, waiting up to
+	// wait for one to arrive. Returns false if no message arrived in time.
+	Pop(ctx context.Context, queue string, wait time.Duration) ([]byte, bool, error)
+
+	// Close closes the queue and releases resources.
+	Close() error
+}
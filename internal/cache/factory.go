@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+// NewCache creates a cache based on the scaling configuration.
+//
+// The backend is scaling.cache_backend, falling back to scaling.backend.
+// Backend options:
+// - "local": In-memory cache (default for single instance)
+// - "postgres": PostgreSQL-backed cache (for multi-instance without Redis)
+// - "redis": Redis-compatible cache (Dragonfly recommended for high scale)
+//
+// The pool parameter is required for "postgres" backend.
+func NewCache(cfg *config.ScalingConfig, pool *pgxpool.Pool) (Cache, error) {
+	backend := cfg.BackendFor(config.ScalingComponentCache)
+	switch backend {
+	case "local", "":
+		log.Info().Msg("Using in-memory cache (single instance mode)")
+		return NewMemoryCache(10 * time.Minute), nil
+
+	case "postgres":
+		if pool == nil {
+			return nil, fmt.Errorf("database pool is required for postgres cache backend")
+		}
+		log.Info().Msg("Using PostgreSQL cache (multi-instance mode)")
+		return NewPostgresCache(pool, 10*time.Minute), nil
+
+	case "redis":
+		if cfg.RedisURL == "" {
+			return nil, fmt.Errorf("redis_url is required for redis cache backend")
+		}
+		log.Info().Msg("Using Redis-compatible cache (high-scale mode)")
+		c, err := NewRedisCache(cfg.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+		}
+		return c, nil
+
+	default:
+		return nil, fmt.Errorf("unknown cache backend: %s (valid options: local, postgres, redis)", backend)
+	}
+}
+
+// NewQueue creates a queue based on the scaling configuration.
+//
+// The backend is scaling.queue_backend, falling back to scaling.backend.
+// Backend options:
+// - "local": In-memory queue (default for single instance)
+// - "postgres": PostgreSQL-backed queue (for multi-instance without Redis)
+// - "redis": Redis list-backed queue (Dragonfly recommended for high scale)
+// - "nats": NATS queue groups
+//
+// The pool parameter is required for "postgres" backend.
+func NewQueue(cfg *config.ScalingConfig, pool *pgxpool.Pool) (Queue, error) {
+	backend := cfg.BackendFor(config.ScalingComponentQueue)
+	switch backend {
+	case "local", "":
+		log.Info().Msg("Using in-memory queue (single instance mode)")
+		return NewMemoryQueue(1000), nil
+
+	case "postgres":
+		if pool == nil {
+			return nil, fmt.Errorf("database pool is required for postgres queue backend")
+		}
+		log.Info().Msg("Using PostgreSQL queue (multi-instance mode)")
+		return NewPostgresQueue(pool), nil
+
+	case "redis":
+		if cfg.RedisURL == "" {
+			return nil, fmt.Errorf("redis_url is required for redis queue backend")
+		}
+		log.Info().Msg("Using Redis-compatible queue (high-scale mode)")
+		q, err := NewRedisQueue(cfg.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+		}
+		return q, nil
+
+	case "nats":
+		if cfg.NATSURL == "" {
+			return nil, fmt.Errorf("nats_url is required for nats queue backend")
+		}
+		log.Info().Msg("Using NATS queue (high-scale mode)")
+		q, err := NewNATSQueue(cfg.NATSURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+		}
+		return q, nil
+
+	default:
+		return nil, fmt.Errorf("unknown queue backend: %s (valid options: local, postgres, redis, nats)", backend)
+	}
+}
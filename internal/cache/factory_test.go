@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCache(t *testing.T) {
+	t.Run("creates memory cache for local backend", func(t *testing.T) {
+		c, err := NewCache(&config.ScalingConfig{Backend: "local"}, nil)
+		require.NoError(t, err)
+		defer func() { _ = c.Close() }()
+
+		_, ok := c.(*MemoryCache)
+		assert.True(t, ok, "should be MemoryCache")
+	})
+
+	t.Run("cache backend overrides scaling backend", func(t *testing.T) {
+		c, err := NewCache(&config.ScalingConfig{Backend: "postgres", CacheBackend: "local"}, nil)
+		require.NoError(t, err)
+		defer func() { _ = c.Close() }()
+
+		_, ok := c.(*MemoryCache)
+		assert.True(t, ok, "should be MemoryCache")
+	})
+
+	t.Run("errors for postgres backend without pool", func(t *testing.T) {
+		c, err := NewCache(&config.ScalingConfig{Backend: "postgres"}, nil)
+		require.Error(t, err)
+		assert.Nil(t, c)
+		assert.Contains(t, err.Error(), "database pool is required")
+	})
+
+	t.Run("errors for redis backend without url", func(t *testing.T) {
+		c, err := NewCache(&config.ScalingConfig{Backend: "redis"}, nil)
+		require.Error(t, err)
+		assert.Nil(t, c)
+		assert.Contains(t, err.Error(), "redis_url is required")
+	})
+
+	t.Run("errors for redis backend with invalid url", func(t *testing.T) {
+		c, err := NewCache(&config.ScalingConfig{Backend: "redis", RedisURL: "invalid://url"}, nil)
+		require.Error(t, err)
+		assert.Nil(t, c)
+		assert.Contains(t, err.Error(), "failed to connect to Redis")
+	})
+
+	t.Run("errors for nats backend", func(t *testing.T) {
+		c, err := NewCache(&config.ScalingConfig{Backend: "local", CacheBackend: "nats"}, nil)
+		require.Error(t, err)
+		assert.Nil(t, c)
+		assert.Contains(t, err.Error(), "unknown cache backend")
+	})
+}
+
+func TestNewQueue(t *testing.T) {
+	t.Run("creates memory queue for empty backend", func(t *testing.T) {
+		q, err := NewQueue(&config.ScalingConfig{}, nil)
+		require.NoError(t, err)
+		defer func() { _ = q.Close() }()
+
+		_, ok := q.(*MemoryQueue)
+		assert.True(t, ok, "should be MemoryQueue")
+	})
+
+	t.Run("errors for postgres backend without pool", func(t *testing.T) {
+		q, err := NewQueue(&config.ScalingConfig{Backend: "local", QueueBackend: "postgres"}, nil)
+		require.Error(t, err)
+		assert.Nil(t, q)
+		assert.Contains(t, err.Error(), "database pool is required")
+	})
+
+	t.Run("errors for nats backend without url", func(t *testing.T) {
+		q, err := NewQueue(&config.ScalingConfig{Backend: "local", QueueBackend: "nats"}, nil)
+		require.Error(t, err)
+		assert.Nil(t, q)
+		assert.Contains(t, err.Error(), "nats_url is required")
+	})
+
+	t.Run("errors for nats backend with unreachable server", func(t *testing.T) {
+		q, err := NewQueue(&config.ScalingConfig{Backend: "local", QueueBackend: "nats", NATSURL: "nats://127.0.0.1:1"}, nil)
+		require.Error(t, err)
+		assert.Nil(t, q)
+		assert.Contains(t, err.Error(), "failed to connect to NATS")
+	})
+
+	t.Run("errors for unknown backend", func(t *testing.T) {
+		q, err := NewQueue(&config.ScalingConfig{Backend: "kafka"}, nil)
+		require.Error(t, err)
+		assert.Nil(t, q)
+		assert.Contains(t, err.Error(), "valid options: local, postgres, redis, nats")
+	})
+}
@@ -0,0 +1,197 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MemoryCache implements Cache using in-memory storage.
+// This is the default cache for single-instance deployments.
+// It provides the fastest performance but doesn't share entries across instances.
+type MemoryCache struct {
+	data       map[string]*cacheEntry
+	mu         sync.RWMutex
+	gcInterval time.Duration
+	stopCh     chan struct{}
+	stopped    int32 // Atomic flag to prevent double-close (0=running, 1=stopped)
+}
+
+type cacheEntry struct {
+	value     []byte
+	expiresAt time.Time // zero means no expiry
+}
+
+func (e *cacheEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+// NewMemoryCache creates a new in-memory cache.
+// gcInterval specifies how often to clean up expired entries.
+func NewMemoryCache(gcInterval time.Duration) *MemoryCache {
+	if gcInterval <= 0 {
+		gcInterval = 10 * time.Minute
+	}
+
+	c := &MemoryCache{
+		data:       make(map[string]*cacheEntry),
+		gcInterval: gcInterval,
+		stopCh:     make(chan struct{}),
+	}
+
+	go c.gc()
+
+	return c
+}
+
+// Get retrieves the value for a key.
+func (c *MemoryCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	e, exists := c.data[key]
+	if !exists || e.expired(time.Now()) {
+		return nil, false, nil
+	}
+
+	return e.value, true, nil
+}
+
+// Set stores a value for a key.
+func (c *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	e := &cacheEntry{value: value}
+	if ttl > 0 {
+		e.expiresAt = time.Now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.data[key] = e
+	return nil
+}
+
+// Delete removes a key.
+func (c *MemoryCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.data, key)
+	return nil
+}
+
+// Close stops the garbage collection goroutine.
+func (c *MemoryCache) Close() error {
+	if !atomic.CompareAndSwapInt32(&c.stopped, 0, 1) {
+		return nil
+	}
+	close(c.stopCh)
+	return nil
+}
+
+// gc periodically removes expired entries.
+func (c *MemoryCache) gc() {
+	ticker := time.NewTicker(c.gcInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+			c.cleanup()
+		}
+	}
+}
+
+// cleanup removes all expired entries.
+func (c *MemoryCache) cleanup() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for key, e := range c.data {
+		if e.expired(now) {
+			delete(c.data, key)
+		}
+	}
+}
+
+// MemoryQueue implements Queue using buffered channels.
+// This is the default queue for single-instance deployments.
+// Messages are lost on restart and are not shared across instances.
+type MemoryQueue struct {
+	queues   map[string]chan []byte
+	mu       sync.Mutex
+	capacity int
+}
+
+// NewMemoryQueue creates a new in-memory queue.
+// capacity is the maximum number of pending messages per named queue.
+func NewMemoryQueue(capacity int) *MemoryQueue {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+
+	return &MemoryQueue{
+		queues:   make(map[string]chan []byte),
+		capacity: capacity,
+	}
+}
+
+// channel returns the channel for a named queue, creating it on first use
+func (q *MemoryQueue) channel(queue string) chan []byte {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ch, exists := q.queues[queue]
+	if !exists {
+		ch = make(chan []byte, q.capacity)
+		q.queues[queue] = ch
+	}
+	return ch
+}
+
+// Push appends a message to the named queue.
+// Returns ErrQueueFull if the queue is at capacity.
+func (q *MemoryQueue) Push(ctx context.Context, queue string, payload []byte) error {
+	select {
+	case q.channel(queue) <- payload:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Pop removes and returns the oldest message from the named queue.
+func (q *MemoryQueue) Pop(ctx context.Context, queue string, wait time.Duration) ([]byte, bool, error) {
+	ch := q.channel(queue)
+
+	// Return immediately if a message is already waiting
+	select {
+	case payload := <-ch:
+		return payload, true, nil
+	default:
+	}
+	if wait <= 0 {
+		return nil, false, nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case payload := <-ch:
+		return payload, true, nil
+	case <-timer.C:
+		return nil, false, nil
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+}
+
+// Close is a no-op for the in-memory queue.
+func (q *MemoryQueue) Close() error {
+	return nil
+}
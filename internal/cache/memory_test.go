@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(time.Minute)
+	defer func() { _ = c.Close() }()
+
+	t.Run("missing key", func(t *testing.T) {
+		_, ok, err := c.Get(ctx, "missing")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("set and get", func(t *testing.T) {
+		require.NoError(t, c.Set(ctx, "key", []byte("value"), 0))
+
+		value, ok, err := c.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []byte("value"), value)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, c.Set(ctx, "deleted", []byte("value"), 0))
+		require.NoError(t, c.Delete(ctx, "deleted"))
+		require.NoError(t, c.Delete(ctx, "never-set"))
+
+		_, ok, err := c.Get(ctx, "deleted")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("expired entries are hidden and cleaned up", func(t *testing.T) {
+		require.NoError(t, c.Set(ctx, "short", []byte("value"), time.Millisecond))
+		time.Sleep(5 * time.Millisecond)
+
+		_, ok, err := c.Get(ctx, "short")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		c.cleanup()
+		c.mu.RLock()
+		_, exists := c.data["short"]
+		c.mu.RUnlock()
+		assert.False(t, exists)
+	})
+
+	t.Run("close is idempotent", func(t *testing.T) {
+		other := NewMemoryCache(0)
+		require.NoError(t, other.Close())
+		require.NoError(t, other.Close())
+	})
+}
+
+func TestMemoryQueue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("fifo order per queue", func(t *testing.T) {
+		q := NewMemoryQueue(10)
+		require.NoError(t, q.Push(ctx, "a", []byte("1")))
+		require.NoError(t, q.Push(ctx, "a", []byte("2")))
+		require.NoError(t, q.Push(ctx, "b", []byte("3")))
+
+		msg, ok, err := q.Pop(ctx, "a", 0)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, []byte("1"), msg)
+
+		msg, ok, err = q.Pop(ctx, "b", 0)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, []byte("3"), msg)
+
+		msg, ok, err = q.Pop(ctx, "a", 0)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, []byte("2"), msg)
+	})
+
+	t.Run("pop times out on an empty queue", func(t *testing.T) {
+		q := NewMemoryQueue(10)
+
+		_, ok, err := q.Pop(ctx, "empty", 10*time.Millisecond)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("pop waits for a push", func(t *testing.T) {
+		q := NewMemoryQueue(10)
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			_ = q.Push(ctx, "jobs", []byte("wake"))
+		}()
+
+		msg, ok, err := q.Pop(ctx, "jobs", time.Second)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, []byte("wake"), msg)
+	})
+
+	t.Run("pop returns when the context is cancelled", func(t *testing.T) {
+		q := NewMemoryQueue(10)
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, ok, err := q.Pop(cancelled, "jobs", time.Second)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.False(t, ok)
+	})
+
+	t.Run("push fails when full", func(t *testing.T) {
+		q := NewMemoryQueue(1)
+		require.NoError(t, q.Push(ctx, "jobs", []byte("1")))
+		assert.ErrorIs(t, q.Push(ctx, "jobs", []byte("2")), ErrQueueFull)
+	})
+}
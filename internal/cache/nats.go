@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/rs/zerolog/log"
+)
+
+// Subject prefix and queue group shared by all instances
+const (
+	natsSubjectPrefix = "fluxbase.queue."
+	natsQueueGroup    = "fluxbase"
+)
+
+// NATSQueue implements Queue using NATS core queue groups.
+// Every instance joins the same queue group, so each message is delivered to one of them.
+// Core NATS does not persist messages: a message published while no instance is
+// consuming the queue is dropped, which makes this backend suitable for signaling
+// (such as job queue wake-ups) rather than as a source of truth.
+type NATSQueue struct {
+	conn *nats.Conn
+
+	mu   sync.Mutex
+	subs map[string]*nats.Subscription
+}
+
+// NewNATSQueue creates a new NATS-backed queue.
+// url should be in the format: nats://[user:password@]host:port
+func NewNATSQueue(url string) (*NATSQueue, error) {
+	conn, err := nats.Connect(url,
+		nats.Name("fluxbase"),
+		nats.Timeout(5*time.Second),
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Str("url", conn.ConnectedUrlRedacted()).Msg("Connected to NATS for queue")
+
+	return &NATSQueue{
+		conn: conn,
+		subs: make(map[string]*nats.Subscription),
+	}, nil
+}
+
+// subscription returns the queue group subscription for a named queue, creating it on first use
+func (q *NATSQueue) subscription(queue string) (*nats.Subscription, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if sub, exists := q.subs[queue]; exists {
+		return sub, nil
+	}
+
+	sub, err := q.conn.QueueSubscribeSync(natsSubjectPrefix+queue, natsQueueGroup)
+	if err != nil {
+		return nil, err
+	}
+	q.subs[queue] = sub
+	return sub, nil
+}
+
+// Push publishes a message to the named queue.
+func (q *NATSQueue) Push(ctx context.Context, queue string, payload []byte) error {
+	return q.conn.Publish(natsSubjectPrefix+queue, payload)
+}
+
+// Pop returns the next message delivered to this instance for the named queue.
+// The first call subscribes to the queue; messages published before that are not received.
+func (q *NATSQueue) Pop(ctx context.Context, queue string, wait time.Duration) ([]byte, bool, error) {
+	sub, err := q.subscription(queue)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if wait <= 0 {
+		// NextMsg returns a waiting message immediately and otherwise needs a positive timeout
+		wait = time.Nanosecond
+	}
+
+	msg, err := sub.NextMsg(wait)
+	if errors.Is(err, nats.ErrTimeout) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return msg.Data, true, nil
+}
+
+// Close drains the subscriptions and closes the NATS connection.
+func (q *NATSQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.subs = make(map[string]*nats.Subscription)
+	return q.conn.Drain()
+}
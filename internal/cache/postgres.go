@@ -0,0 +1,203 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// PostgresCache implements Cache using PostgreSQL.
+// This is suitable for multi-instance deployments without requiring Redis.
+// Entries live in the UNLOGGED system.cache_entries table, so writes skip the WAL
+// and the cache is emptied after a database crash.
+type PostgresCache struct {
+	pool       *pgxpool.Pool
+	gcInterval time.Duration
+	stopCh     chan struct{}
+	stopped    int32 // Atomic flag to prevent double-close (0=running, 1=stopped)
+}
+
+// NewPostgresCache creates a new PostgreSQL-backed cache.
+// The cache uses the system.cache_entries table which must be created via migration.
+// Expired entries are removed every gcInterval.
+func NewPostgresCache(pool *pgxpool.Pool, gcInterval time.Duration) *PostgresCache {
+	if gcInterval <= 0 {
+		gcInterval = 10 * time.Minute
+	}
+
+	c := &PostgresCache{
+		pool:       pool,
+		gcInterval: gcInterval,
+		stopCh:     make(chan struct{}),
+	}
+
+	go c.gc()
+
+	return c
+}
+
+// Get retrieves the value for a key.
+func (c *PostgresCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	var value []byte
+	err := c.pool.QueryRow(ctx, `
+		SELECT value
+		FROM system.cache_entries
+		WHERE key = $1 AND (expires_at IS NULL OR expires_at > NOW())
+	`, key).Scan(&value)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+// Set stores a value for a key.
+func (c *PostgresCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	var expiresAt *time.Time
+	if ttl > 0 {
+		t := time.Now().Add(ttl)
+		expiresAt = &t
+	}
+
+	_, err := c.pool.Exec(ctx, `
+		INSERT INTO system.cache_entries (key, value, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET
+			value = EXCLUDED.value,
+			expires_at = EXCLUDED.expires_at,
+			created_at = NOW()
+	`, key, value, expiresAt)
+	return err
+}
+
+// Delete removes a key.
+func (c *PostgresCache) Delete(ctx context.Context, key string) error {
+	_, err := c.pool.Exec(ctx, `DELETE FROM system.cache_entries WHERE key = $1`, key)
+	return err
+}
+
+// Close stops the cleanup goroutine. The connection pool is not closed as we don't own it.
+func (c *PostgresCache) Close() error {
+	if !atomic.CompareAndSwapInt32(&c.stopped, 0, 1) {
+		return nil
+	}
+	close(c.stopCh)
+	return nil
+}
+
+// Cleanup removes expired entries from the cache_entries table.
+func (c *PostgresCache) Cleanup(ctx context.Context) (int64, error) {
+	result, err := c.pool.Exec(ctx, `
+		DELETE FROM system.cache_entries WHERE expires_at IS NOT NULL AND expires_at <= NOW()
+	`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+// gc periodically removes expired entries.
+func (c *PostgresCache) gc() {
+	ticker := time.NewTicker(c.gcInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if _, err := c.Cleanup(ctx); err != nil {
+				log.Warn().Err(err).Msg("Failed to clean up expired cache entries")
+			}
+			cancel()
+		}
+	}
+}
+
+// postgresQueuePollInterval is how often Pop checks for new messages while waiting
+const postgresQueuePollInterval = 250 * time.Millisecond
+
+// PostgresQueue implements Queue using PostgreSQL.
+// This is suitable for multi-instance deployments without requiring Redis or NATS.
+// Messages live in the UNLOGGED system.queue_messages table and are claimed with
+// SELECT ... FOR UPDATE SKIP LOCKED, so each message is delivered to one consumer.
+type PostgresQueue struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresQueue creates a new PostgreSQL-backed queue.
+// The queue uses the system.queue_messages table which must be created via migration.
+func NewPostgresQueue(pool *pgxpool.Pool) *PostgresQueue {
+	return &PostgresQueue{
+		pool: pool,
+	}
+}
+
+// Push appends a message to the named queue.
+func (q *PostgresQueue) Push(ctx context.Context, queue string, payload []byte) error {
+	_, err := q.pool.Exec(ctx, `
+		INSERT INTO system.queue_messages (queue, payload) VALUES ($1, $2)
+	`, queue, payload)
+	return err
+}
+
+// Pop removes and returns the oldest message from the named queue, polling until wait elapses.
+func (q *PostgresQueue) Pop(ctx context.Context, queue string, wait time.Duration) ([]byte, bool, error) {
+	deadline := time.Now().Add(wait)
+
+	for {
+		payload, ok, err := q.popOnce(ctx, queue)
+		if err != nil || ok {
+			return payload, ok, err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, false, nil
+		}
+
+		select {
+		case <-time.After(min(remaining, postgresQueuePollInterval)):
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+}
+
+// popOnce claims the oldest message without waiting
+func (q *PostgresQueue) popOnce(ctx context.Context, queue string) ([]byte, bool, error) {
+	var payload []byte
+	err := q.pool.QueryRow(ctx, `
+		DELETE FROM system.queue_messages
+		WHERE id = (
+			SELECT id FROM system.queue_messages
+			WHERE queue = $1
+			ORDER BY id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING payload
+	`, queue).Scan(&payload)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return payload, true, nil
+}
+
+// Close is a no-op for PostgresQueue as we don't own the connection pool.
+func (q *PostgresQueue) Close() error {
+	return nil
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// Key prefixes keep cache and queue data apart from rate limit counters in a shared Redis
+const (
+	redisCachePrefix = "fluxbase:cache:"
+	redisQueuePrefix = "fluxbase:queue:"
+)
+
+// newRedisClient parses url and verifies the connection.
+// url should be in the format: redis://[password@]host:port[/db]
+func newRedisClient(url string) (*redis.Client, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redis.NewClient(opts)
+
+	// Test connection
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+
+	log.Info().Str("addr", opts.Addr).Msg("Connected to Redis-compatible backend for cache")
+
+	return client, nil
+}
+
+// RedisCache implements Cache using Redis (or Redis-compatible backends like Dragonfly).
+// This is the recommended cache for high-scale deployments.
+type RedisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache creates a new Redis-backed cache.
+// url should be in the format: redis://[password@]host:port[/db]
+func NewRedisCache(url string) (*RedisCache, error) {
+	client, err := newRedisClient(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RedisCache{
+		client: client,
+	}, nil
+}
+
+// Get retrieves the value for a key.
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, redisCachePrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+// Set stores a value for a key.
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+	return c.client.Set(ctx, redisCachePrefix+key, value, ttl).Err()
+}
+
+// Delete removes a key.
+func (c *RedisCache) Delete(ctx context.Context, key string) error {
+	return c.client.Del(ctx, redisCachePrefix+key).Err()
+}
+
+// Close closes the Redis connection.
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
+
+// RedisQueue implements Queue using Redis lists.
+// Messages are pushed with LPUSH and consumed with BRPOP, so each message is
+// delivered to one consumer.
+type RedisQueue struct {
+	client *redis.Client
+}
+
+// NewRedisQueue creates a new Redis-backed queue.
+// url should be in the format: redis://[password@]host:port[/db]
+func NewRedisQueue(url string) (*RedisQueue, error) {
+	client, err := newRedisClient(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RedisQueue{
+		client: client,
+	}, nil
+}
+
+// Push appends a message to the named queue.
+func (q *RedisQueue) Push(ctx context.Context, queue string, payload []byte) error {
+	return q.client.LPush(ctx, redisQueuePrefix+queue, payload).Err()
+}
+
+// Pop removes and returns the oldest message from the named queue.
+func (q *RedisQueue) Pop(ctx context.Context, queue string, wait time.Duration) ([]byte, bool, error) {
+	key := redisQueuePrefix + queue
+
+	if wait <= 0 {
+		payload, err := q.client.RPop(ctx, key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return payload, true, nil
+	}
+
+	// BRPOP returns the key and the value
+	result, err := q.client.BRPop(ctx, wait, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return []byte(result[1]), true, nil
+}
+
+// Close closes the Redis connection.
+func (q *RedisQueue) Close() error {
+	return q.client.Close()
+}
@@ -0,0 +1,148 @@
+// Package cdn puts an optional CDN in front of storage buckets: public object URLs point at
+// the CDN, signed URLs use the CDN's token scheme, and object updates and deletes purge the
+// cached copies.
+package cdn
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// URL signing schemes
+const (
+	// SigningNone serves objects from the CDN without a token
+	SigningNone = "none"
+	// SigningHMAC appends expires and signature, the hex HMAC-SHA256 of path+expires
+	SigningHMAC = "hmac"
+	// SigningBunny is Bunny CDN token authentication
+	SigningBunny = "bunny"
+)
+
+// Purge providers
+const (
+	PurgeNone       = "none"
+	PurgeCloudflare = "cloudflare"
+	PurgeFastly     = "fastly"
+	PurgeBunny      = "bunny"
+	PurgeWebhook    = "webhook"
+)
+
+// ErrNotConfigured is returned for a bucket without CDN settings
+var ErrNotConfigured = errors.New("bucket has no CDN configured")
+
+// Settings is the CDN configuration of a bucket. SigningKey and PurgeToken are only
+// set on the server and never serialized.
+type Settings struct {
+	Bucket        string    `json:"bucket"`
+	Enabled       bool      `json:"enabled"`
+	BaseURL       string    `json:"base_url"`
+	SigningScheme string    `json:"signing_scheme"`
+	SigningKey    string    `json:"-"`
+	PurgeProvider string    `json:"purge_provider"`
+	PurgeZoneID   string    `json:"purge_zone_id,omitempty"`
+	PurgeURL      string    `json:"purge_url,omitempty"`
+	PurgeToken    string    `json:"-"`
+	HasSigningKey bool      `json:"has_signing_key"`
+	HasPurgeToken bool      `json:"has_purge_token"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Validate checks the settings and that the credentials each scheme and provider needs
+// are present
+func (s *Settings) Validate() error {
+	if err := validateHTTPURL(s.BaseURL); err != nil {
+		return fmt.Errorf("base_url %w", err)
+	}
+
+	switch s.SigningScheme {
+	case SigningNone:
+	case SigningHMAC, SigningBunny:
+		if s.SigningKey == "" {
+			return fmt.Errorf("signing_key is required for the %s signing scheme", s.SigningScheme)
+		}
+	default:
+		return errors.New("signing_scheme must be one of: none, hmac, bunny")
+	}
+
+	switch s.PurgeProvider {
+	case PurgeNone:
+	case PurgeCloudflare:
+		if s.PurgeZoneID == "" || s.PurgeToken == "" {
+			return errors.New("purge_zone_id and purge_token are required for cloudflare")
+		}
+	case PurgeFastly, PurgeBunny:
+		if s.PurgeToken == "" {
+			return fmt.Errorf("purge_token is required for %s", s.PurgeProvider)
+		}
+	case PurgeWebhook:
+		if err := validateHTTPURL(s.PurgeURL); err != nil {
+			return fmt.Errorf("purge_url %w", err)
+		}
+	default:
+		return errors.New("purge_provider must be one of: none, cloudflare, fastly, bunny, webhook")
+	}
+	return nil
+}
+
+// validateHTTPURL checks that raw is an absolute http(s) URL without query or fragment
+func validateHTTPURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return errors.New("must be an absolute http or https URL")
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return errors.New("must not have a query or fragment")
+	}
+	return nil
+}
+
+// PublicURL returns the CDN URL of an object, escaping each segment of the key
+func (s *Settings) PublicURL(key string) string {
+	segments := strings.Split(strings.TrimPrefix(key, "/"), "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	return strings.TrimRight(s.BaseURL, "/") + "/" + strings.Join(segments, "/")
+}
+
+// SignedURL returns the CDN URL of an object with a token valid for expiresIn
+func (s *Settings) SignedURL(key string, expiresIn time.Duration, now time.Time) (string, error) {
+	publicURL := s.PublicURL(key)
+	u, err := url.Parse(publicURL)
+	if err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(now.Add(expiresIn).Unix(), 10)
+	path := u.EscapedPath()
+
+	switch s.SigningScheme {
+	case SigningHMAC:
+		mac := hmac.New(sha256.New, []byte(s.SigningKey))
+		mac.Write([]byte(path + expires))
+		return publicURL + "?expires=" + expires + "&signature=" + hex.EncodeToString(mac.Sum(nil)), nil
+	case SigningBunny:
+		sum := sha256.Sum256([]byte(s.SigningKey + path + expires))
+		token := base64.RawURLEncoding.EncodeToString(sum[:])
+		return publicURL + "?token=" + token + "&expires=" + expires, nil
+	default:
+		return "", fmt.Errorf("bucket %s does not sign CDN URLs", s.Bucket)
+	}
+}
+
+// Signs reports whether signed URLs of the bucket are served by the CDN
+func (s *Settings) Signs() bool {
+	return s.Enabled && s.SigningScheme != SigningNone
+}
+
+// Purges reports whether updates and deletes of the bucket purge the CDN
+func (s *Settings) Purges() bool {
+	return s.Enabled && s.PurgeProvider != PurgeNone
+}
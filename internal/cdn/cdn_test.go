@@ -0,0 +1,70 @@
+package cdn
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettings_Validate(t *testing.T) {
+	valid := Settings{BaseURL: "https://cdn.example.com/avatars", SigningScheme: SigningNone, PurgeProvider: PurgeNone}
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(s *Settings)
+		msg    string
+	}{
+		{"relative base URL", func(s *Settings) { s.BaseURL = "/avatars" }, "base_url must be an absolute"},
+		{"base URL with query", func(s *Settings) { s.BaseURL = "https://cdn.example.com/?v=1" }, "base_url must not have a query"},
+		{"unknown scheme", func(s *Settings) { s.SigningScheme = "cloudfront" }, "signing_scheme must be one of"},
+		{"hmac without key", func(s *Settings) { s.SigningScheme = SigningHMAC }, "signing_key is required"},
+		{"unknown provider", func(s *Settings) { s.PurgeProvider = "akamai" }, "purge_provider must be one of"},
+		{"cloudflare without zone", func(s *Settings) { s.PurgeProvider = PurgeCloudflare; s.PurgeToken = "t" }, "purge_zone_id and purge_token"},
+		{"fastly without token", func(s *Settings) { s.PurgeProvider = PurgeFastly }, "purge_token is required for fastly"},
+		{"webhook without URL", func(s *Settings) { s.PurgeProvider = PurgeWebhook }, "purge_url must be an absolute"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid
+			tt.modify(&s)
+			err := s.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.msg)
+		})
+	}
+}
+
+func TestSettings_PublicURL(t *testing.T) {
+	s := Settings{BaseURL: "https://cdn.example.com/avatars/"}
+	assert.Equal(t, "https://cdn.example.com/avatars/users/42/me.png", s.PublicURL("users/42/me.png"))
+	assert.Equal(t, "https://cdn.example.com/avatars/my%20photo%3F.png", s.PublicURL("/my photo?.png"))
+}
+
+func TestSettings_SignedURL(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	s := Settings{Bucket: "avatars", Enabled: true, BaseURL: "https://cdn.example.com/avatars", SigningScheme: SigningHMAC, SigningKey: "secret"}
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("/avatars/a%20b.png1800000600"))
+	signed, err := s.SignedURL("a b.png", 10*time.Minute, now)
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.example.com/avatars/a%20b.png?expires=1800000600&signature="+hex.EncodeToString(mac.Sum(nil)), signed)
+	assert.True(t, s.Signs())
+
+	s.SigningScheme = SigningBunny
+	signed, err = s.SignedURL("a.png", time.Minute, now)
+	require.NoError(t, err)
+	// base64url(sha256("secret" + "/avatars/a.png" + "1800000060")) without padding
+	assert.Regexp(t, `^https://cdn\.example\.com/avatars/a\.png\?token=[A-Za-z0-9_-]{43}&expires=1800000060$`, signed)
+
+	s.SigningScheme = SigningNone
+	_, err = s.SignedURL("a.png", time.Minute, now)
+	assert.Error(t, err)
+	assert.False(t, s.Signs())
+}
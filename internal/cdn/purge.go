@@ -0,0 +1,120 @@
+package cdn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// cloudflareBatchSize is the number of URLs Cloudflare accepts per purge request
+const cloudflareBatchSize = 30
+
+// Purger removes cached objects from a CDN through the provider's API
+type Purger struct {
+	client *http.Client
+
+	// Provider API base URLs, replaced in tests
+	cloudflareAPI string
+	fastlyAPI     string
+	bunnyAPI      string
+}
+
+// NewPurger creates a purger
+func NewPurger() *Purger {
+	return &Purger{
+		client:        &http.Client{Timeout: 30 * time.Second},
+		cloudflareAPI: "https://api.cloudflare.com/client/v4",
+		fastlyAPI:     "https://api.fastly.com",
+		bunnyAPI:      "https://api.bunny.net",
+	}
+}
+
+// Purge removes the objects at keys from the cache of the bucket's CDN
+func (p *Purger) Purge(ctx context.Context, s *Settings, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	urls := make([]string, len(keys))
+	for i, key := range keys {
+		urls[i] = s.PublicURL(key)
+	}
+
+	switch s.PurgeProvider {
+	case PurgeCloudflare:
+		endpoint := p.cloudflareAPI + "/zones/" + url.PathEscape(s.PurgeZoneID) + "/purge_cache"
+		for start := 0; start < len(urls); start += cloudflareBatchSize {
+			batch := urls[start:min(start+cloudflareBatchSize, len(urls))]
+			if err := p.send(ctx, http.MethodPost, endpoint, map[string]any{"files": batch},
+				map[string]string{"Authorization": "Bearer " + s.PurgeToken}); err != nil {
+				return err
+			}
+		}
+	case PurgeFastly:
+		for _, u := range urls {
+			target := strings.TrimPrefix(strings.TrimPrefix(u, "https://"), "http://")
+			if err := p.send(ctx, http.MethodPost, p.fastlyAPI+"/purge/"+target, nil,
+				map[string]string{"Fastly-Key": s.PurgeToken}); err != nil {
+				return err
+			}
+		}
+	case PurgeBunny:
+		for _, u := range urls {
+			if err := p.send(ctx, http.MethodPost, p.bunnyAPI+"/purge?url="+url.QueryEscape(u), nil,
+				map[string]string{"AccessKey": s.PurgeToken}); err != nil {
+				return err
+			}
+		}
+	case PurgeWebhook:
+		headers := map[string]string{}
+		if s.PurgeToken != "" {
+			headers["Authorization"] = "Bearer " + s.PurgeToken
+		}
+		return p.send(ctx, http.MethodPost, s.PurgeURL, map[string]any{
+			"bucket": s.Bucket,
+			"keys":   keys,
+			"urls":   urls,
+		}, headers)
+	}
+	return nil
+}
+
+// send makes a purge request, failing on any non-2xx response
+func (p *Purger) send(ctx context.Context, method, endpoint string, body any, headers map[string]string) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("purge request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("purge request returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package cdn
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Body   map[string]any
+}
+
+func newRecordingServer(t *testing.T, status int) (*httptest.Server, func() []recordedRequest) {
+	var mu sync.Mutex
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := recordedRequest{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery, Header: r.Header}
+		_ = json.NewDecoder(r.Body).Decode(&rec.Body)
+		mu.Lock()
+		requests = append(requests, rec)
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []recordedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+func TestPurger_Cloudflare(t *testing.T) {
+	server, requests := newRecordingServer(t, http.StatusOK)
+	p := NewPurger()
+	p.cloudflareAPI = server.URL
+
+	keys := make([]string, 45)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("img/%d.png", i)
+	}
+	s := &Settings{BaseURL: "https://cdn.example.com", PurgeProvider: PurgeCloudflare, PurgeZoneID: "zone1", PurgeToken: "cf-token"}
+	require.NoError(t, p.Purge(context.Background(), s, keys))
+
+	reqs := requests()
+	require.Len(t, reqs, 2, "URLs are sent in batches of 30")
+	assert.Equal(t, "/zones/zone1/purge_cache", reqs[0].Path)
+	assert.Equal(t, "Bearer cf-token", reqs[0].Header.Get("Authorization"))
+	assert.Len(t, reqs[0].Body["files"], 30)
+	assert.Len(t, reqs[1].Body["files"], 15)
+	assert.Equal(t, "https://cdn.example.com/img/0.png", reqs[0].Body["files"].([]any)[0])
+}
+
+func TestPurger_Fastly(t *testing.T) {
+	server, requests := newRecordingServer(t, http.StatusOK)
+	p := NewPurger()
+	p.fastlyAPI = server.URL
+
+	s := &Settings{BaseURL: "https://cdn.example.com/files", PurgeProvider: PurgeFastly, PurgeToken: "fastly-key"}
+	require.NoError(t, p.Purge(context.Background(), s, []string{"a.txt", "b.txt"}))
+
+	reqs := requests()
+	require.Len(t, reqs, 2)
+	assert.Equal(t, "/purge/cdn.example.com/files/a.txt", reqs[0].Path)
+	assert.Equal(t, "fastly-key", reqs[0].Header.Get("Fastly-Key"))
+}
+
+func TestPurger_Webhook(t *testing.T) {
+	server, requests := newRecordingServer(t, http.StatusAccepted)
+	p := NewPurger()
+
+	s := &Settings{Bucket: "docs", BaseURL: "https://cdn.example.com", PurgeProvider: PurgeWebhook, PurgeURL: server.URL + "/purge", PurgeToken: "hook"}
+	require.NoError(t, p.Purge(context.Background(), s, []string{"guide.pdf"}))
+
+	reqs := requests()
+	require.Len(t, reqs, 1)
+	assert.Equal(t, "/purge", reqs[0].Path)
+	assert.Equal(t, "Bearer hook", reqs[0].Header.Get("Authorization"))
+	assert.Equal(t, "docs", reqs[0].Body["bucket"])
+	assert.Equal(t, []any{"guide.pdf"}, reqs[0].Body["keys"])
+	assert.Equal(t, []any{"https://cdn.example.com/guide.pdf"}, reqs[0].Body["urls"])
+}
+
+func TestPurger_Error(t *testing.T) {
+	server, _ := newRecordingServer(t, http.StatusForbidden)
+	p := NewPurger()
+	p.bunnyAPI = server.URL
+
+	s := &Settings{BaseURL: "https://cdn.example.com", PurgeProvider: PurgeBunny, PurgeToken: "wrong"}
+	err := p.Purge(context.Background(), s, []string{"a.png"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "returned 403")
+}
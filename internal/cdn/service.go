@@ -0,0 +1,162 @@
+package cdn
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// cacheTTL bounds how long another instance's settings change takes to apply here
+	cacheTTL = 30 * time.Second
+	// purgeTimeout bounds a background purge
+	purgeTimeout = time.Minute
+)
+
+// cacheEntry is the cached settings of a bucket, nil if it has no CDN
+type cacheEntry struct {
+	settings *Settings
+	expires  time.Time
+}
+
+// Service resolves the CDN URLs of objects and purges updated and deleted objects.
+// Settings are cached briefly, since they are looked up on every upload and delete.
+type Service struct {
+	store  *Store
+	purger *Purger
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+
+	// ctx is cancelled by Stop to abort pending purges on shutdown
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewService creates a CDN service
+func NewService(store *Store, purger *Purger) *Service {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Service{
+		store:  store,
+		purger: purger,
+		now:    time.Now,
+		cache:  make(map[string]cacheEntry),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Settings returns the CDN settings of bucket, nil if it has no CDN
+func (s *Service) Settings(ctx context.Context, bucket string) (*Settings, error) {
+	now := s.now()
+	s.mu.Lock()
+	entry, ok := s.cache[bucket]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.settings, nil
+	}
+
+	settings, err := s.store.Get(ctx, bucket)
+	if errors.Is(err, ErrNotConfigured) {
+		settings, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache[bucket] = cacheEntry{settings: settings, expires: now.Add(cacheTTL)}
+	s.mu.Unlock()
+	return settings, nil
+}
+
+// Get returns the stored CDN settings of bucket, bypassing the cache
+func (s *Service) Get(ctx context.Context, bucket string) (*Settings, error) {
+	return s.store.Get(ctx, bucket)
+}
+
+// Put saves the CDN settings of a bucket
+func (s *Service) Put(ctx context.Context, settings *Settings, updatedBy *uuid.UUID) error {
+	if err := s.store.Put(ctx, settings, updatedBy); err != nil {
+		return err
+	}
+	s.invalidate(settings.Bucket)
+	return nil
+}
+
+// Delete removes the CDN settings of a bucket
+func (s *Service) Delete(ctx context.Context, bucket string) error {
+	if err := s.store.Delete(ctx, bucket); err != nil {
+		return err
+	}
+	s.invalidate(bucket)
+	return nil
+}
+
+func (s *Service) invalidate(bucket string) {
+	s.mu.Lock()
+	delete(s.cache, bucket)
+	s.mu.Unlock()
+}
+
+// PublicURL returns the CDN URL of an object, empty if the bucket has no enabled CDN or
+// its CDN requires signed URLs
+func (s *Service) PublicURL(ctx context.Context, bucket, key string) string {
+	settings, err := s.Settings(ctx, bucket)
+	if err != nil {
+		log.Warn().Err(err).Str("bucket", bucket).Msg("Failed to get CDN settings")
+		return ""
+	}
+	if settings == nil || !settings.Enabled || settings.SigningScheme != SigningNone {
+		return ""
+	}
+	return settings.PublicURL(key)
+}
+
+// PurgeNow purges the objects at keys from the bucket's CDN
+func (s *Service) PurgeNow(ctx context.Context, bucket string, keys []string) error {
+	settings, err := s.Settings(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	if settings == nil || !settings.Purges() {
+		return ErrNotConfigured
+	}
+	return s.purger.Purge(ctx, settings, keys)
+}
+
+// Purge purges the objects at keys from the bucket's CDN in the background. Buckets without
+// a purge provider are skipped. Failures are logged, the cached copies then expire with the
+// CDN's TTL.
+func (s *Service) Purge(bucket string, keys ...string) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ctx, cancel := context.WithTimeout(s.ctx, purgeTimeout)
+		defer cancel()
+
+		err := s.PurgeNow(ctx, bucket, keys)
+		if errors.Is(err, ErrNotConfigured) {
+			return
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("bucket", bucket).Strs("keys", keys).Msg("Failed to purge CDN cache")
+			return
+		}
+		log.Debug().Str("bucket", bucket).Strs("keys", keys).Msg("Purged CDN cache")
+	}()
+}
+
+// Stop cancels pending purges and waits for them to return
+func (s *Service) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
@@ -0,0 +1,133 @@
+package cdn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/crypto"
+	"github.com/nimbleflux/fluxbase/internal/database"
+)
+
+// ErrBucketNotFound is returned when configuring the CDN of a bucket that does not exist
+var ErrBucketNotFound = errors.New("bucket not found")
+
+// Store persists the CDN settings of buckets, encrypting the signing key and purge token
+type Store struct {
+	db            *pgxpool.Pool
+	encryptionKey string
+}
+
+// NewStore creates a CDN settings store
+func NewStore(db *pgxpool.Pool, encryptionKey string) *Store {
+	return &Store{db: db, encryptionKey: encryptionKey}
+}
+
+// Get returns the CDN settings of bucket with decrypted credentials
+func (s *Store) Get(ctx context.Context, bucket string) (*Settings, error) {
+	settings := &Settings{Bucket: bucket}
+	var signingKey, purgeZoneID, purgeURL, purgeToken *string
+	err := s.db.QueryRow(ctx, `
+		SELECT enabled, base_url, signing_scheme, signing_key_encrypted, purge_provider,
+			purge_zone_id, purge_url, purge_token_encrypted, updated_at
+		FROM storage.bucket_cdn
+		WHERE bucket_id = $1
+	`, bucket).Scan(&settings.Enabled, &settings.BaseURL, &settings.SigningScheme, &signingKey,
+		&settings.PurgeProvider, &purgeZoneID, &purgeURL, &purgeToken, &settings.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotConfigured
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CDN settings: %w", err)
+	}
+
+	if purgeZoneID != nil {
+		settings.PurgeZoneID = *purgeZoneID
+	}
+	if purgeURL != nil {
+		settings.PurgeURL = *purgeURL
+	}
+	if signingKey != nil {
+		if settings.SigningKey, err = crypto.Decrypt(*signingKey, s.encryptionKey); err != nil {
+			return nil, fmt.Errorf("failed to decrypt CDN signing key: %w", err)
+		}
+		settings.HasSigningKey = true
+	}
+	if purgeToken != nil {
+		if settings.PurgeToken, err = crypto.Decrypt(*purgeToken, s.encryptionKey); err != nil {
+			return nil, fmt.Errorf("failed to decrypt CDN purge token: %w", err)
+		}
+		settings.HasPurgeToken = true
+	}
+	return settings, nil
+}
+
+// Put creates or replaces the CDN settings of a bucket. The settings must already be
+// validated.
+func (s *Store) Put(ctx context.Context, settings *Settings, updatedBy *uuid.UUID) error {
+	signingKey, err := s.encrypt(settings.SigningKey)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt CDN signing key: %w", err)
+	}
+	purgeToken, err := s.encrypt(settings.PurgeToken)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt CDN purge token: %w", err)
+	}
+
+	err = s.db.QueryRow(ctx, `
+		INSERT INTO storage.bucket_cdn (bucket_id, enabled, base_url, signing_scheme, signing_key_encrypted,
+			purge_provider, purge_zone_id, purge_url, purge_token_encrypted, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), $9, $10)
+		ON CONFLICT (bucket_id) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			base_url = EXCLUDED.base_url,
+			signing_scheme = EXCLUDED.signing_scheme,
+			signing_key_encrypted = EXCLUDED.signing_key_encrypted,
+			purge_provider = EXCLUDED.purge_provider,
+			purge_zone_id = EXCLUDED.purge_zone_id,
+			purge_url = EXCLUDED.purge_url,
+			purge_token_encrypted = EXCLUDED.purge_token_encrypted,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+		RETURNING updated_at
+	`, settings.Bucket, settings.Enabled, settings.BaseURL, settings.SigningScheme, signingKey,
+		settings.PurgeProvider, settings.PurgeZoneID, settings.PurgeURL, purgeToken, updatedBy,
+	).Scan(&settings.UpdatedAt)
+	if database.IsForeignKeyViolation(err) {
+		return ErrBucketNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save CDN settings: %w", err)
+	}
+
+	settings.HasSigningKey = signingKey != nil
+	settings.HasPurgeToken = purgeToken != nil
+	return nil
+}
+
+// Delete removes the CDN settings of a bucket
+func (s *Store) Delete(ctx context.Context, bucket string) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM storage.bucket_cdn WHERE bucket_id = $1`, bucket)
+	if err != nil {
+		return fmt.Errorf("failed to delete CDN settings: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotConfigured
+	}
+	return nil
+}
+
+// encrypt encrypts a credential, nil if it is empty
+func (s *Store) encrypt(value string) (*string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	encrypted, err := crypto.Encrypt(value, s.encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	return &encrypted, nil
+}
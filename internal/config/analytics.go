@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// AnalyticsExportSources are the tables that can be exported to an analytics warehouse
+var AnalyticsExportSources = []string{
+	"retrieval_log", "conversations", "messages", "embedding_usage", "api_usage",
+}
+
+// AnalyticsConfig contains analytics warehouse export settings.
+// New and changed rows of AI and usage tables are exported incrementally as gzipped JSON lines
+// to a storage bucket that a warehouse (BigQuery, Snowflake, ...) loads from.
+type AnalyticsConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`     // Enable periodic analytics exports (default: false)
+	Interval   time.Duration `mapstructure:"interval"`    // How often new rows are exported (default: 15m)
+	Bucket     string        `mapstructure:"bucket"`      // Storage bucket for the export files
+	PathPrefix string        `mapstructure:"path_prefix"` // Object key prefix inside the bucket (default: "analytics")
+	Sources    []string      `mapstructure:"sources"`     // Tables to export (empty = all)
+	BatchSize  int           `mapstructure:"batch_size"`  // Max rows per file (default: 10000)
+}
+
+// Validate validates analytics export configuration
+func (ac *AnalyticsConfig) Validate() error {
+	if !ac.Enabled {
+		return nil // No validation needed if disabled
+	}
+
+	if ac.Bucket == "" {
+		return fmt.Errorf("analytics export requires a bucket")
+	}
+
+	if ac.Interval < time.Minute {
+		return fmt.Errorf("analytics export interval must be at least 1m, got: %s", ac.Interval)
+	}
+
+	if ac.BatchSize < 1 || ac.BatchSize > 100000 {
+		return fmt.Errorf("analytics export batch_size must be between 1 and 100000, got: %d", ac.BatchSize)
+	}
+
+	for _, source := range ac.Sources {
+		if !slices.Contains(AnalyticsExportSources, source) {
+			return fmt.Errorf("unknown analytics export source %q, expected one of %v", source, AnalyticsExportSources)
+		}
+	}
+
+	return nil
+}
+
+// EnabledSources returns the configured sources, or all sources if none are configured
+func (ac *AnalyticsConfig) EnabledSources() []string {
+	if len(ac.Sources) == 0 {
+		return AnalyticsExportSources
+	}
+	return ac.Sources
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyticsConfig_Validate(t *testing.T) {
+	valid := func() AnalyticsConfig {
+		return AnalyticsConfig{
+			Enabled:    true,
+			Interval:   15 * time.Minute,
+			Bucket:     "warehouse",
+			PathPrefix: "analytics",
+			BatchSize:  10000,
+		}
+	}
+
+	t.Run("disabled config needs no validation", func(t *testing.T) {
+		cfg := AnalyticsConfig{Enabled: false}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("valid config passes", func(t *testing.T) {
+		cfg := valid()
+		cfg.Sources = []string{"retrieval_log", "conversations"}
+		require.NoError(t, cfg.Validate())
+	})
+
+	tests := []struct {
+		name    string
+		modify  func(*AnalyticsConfig)
+		wantErr string
+	}{
+		{"rejects missing bucket", func(c *AnalyticsConfig) { c.Bucket = "" }, "requires a bucket"},
+		{"rejects sub-minute interval", func(c *AnalyticsConfig) { c.Interval = 30 * time.Second }, "at least 1m"},
+		{"rejects zero batch size", func(c *AnalyticsConfig) { c.BatchSize = 0 }, "batch_size"},
+		{"rejects huge batch size", func(c *AnalyticsConfig) { c.BatchSize = 1000000 }, "batch_size"},
+		{"rejects unknown source", func(c *AnalyticsConfig) { c.Sources = []string{"auth_users"} }, "unknown analytics export source"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestAnalyticsConfig_EnabledSources(t *testing.T) {
+	assert.Equal(t, AnalyticsExportSources, (&AnalyticsConfig{}).EnabledSources())
+	assert.Equal(t, []string{"messages"}, (&AnalyticsConfig{Sources: []string{"messages"}}).EnabledSources())
+}
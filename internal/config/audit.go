@@ -0,0 +1,23 @@
+package config
+
+import "fmt"
+
+// AuditConfig contains settings for the audit log. Sign-ins, impersonation, permission grants,
+// admin changes and SQL editor executions are recorded in audit.events.
+type AuditConfig struct {
+	Enabled       bool `mapstructure:"enabled"`        // Record audit events (default: true)
+	RetentionDays int  `mapstructure:"retention_days"` // Days of events to keep, 0 keeps them forever (default: 365)
+}
+
+// Validate validates audit configuration
+func (ac *AuditConfig) Validate() error {
+	if !ac.Enabled {
+		return nil // No validation needed if disabled
+	}
+
+	if ac.RetentionDays < 0 {
+		return fmt.Errorf("audit retention_days cannot be negative, got: %d", ac.RetentionDays)
+	}
+
+	return nil
+}
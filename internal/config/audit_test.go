@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditConfig_Validate(t *testing.T) {
+	t.Run("disabled config needs no validation", func(t *testing.T) {
+		cfg := AuditConfig{Enabled: false, RetentionDays: -1}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("valid config passes", func(t *testing.T) {
+		cfg := AuditConfig{Enabled: true, RetentionDays: 365}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("zero retention keeps events forever", func(t *testing.T) {
+		cfg := AuditConfig{Enabled: true, RetentionDays: 0}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("rejects negative retention", func(t *testing.T) {
+		cfg := AuditConfig{Enabled: true, RetentionDays: -1}
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot be negative")
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"math"
 	"net/url"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -15,32 +16,45 @@ import (
 
 // Config represents the application configuration
 type Config struct {
-	Server        ServerConfig     `mapstructure:"server"`
-	Database      DatabaseConfig   `mapstructure:"database"`
-	Auth          AuthConfig       `mapstructure:"auth"`
-	Security      SecurityConfig   `mapstructure:"security"`
-	CORS          CORSConfig       `mapstructure:"cors"`
-	Storage       StorageConfig    `mapstructure:"storage"`
-	Realtime      RealtimeConfig   `mapstructure:"realtime"`
-	Email         EmailConfig      `mapstructure:"email"`
-	Functions     FunctionsConfig  `mapstructure:"functions"`
-	API           APIConfig        `mapstructure:"api"`
-	Migrations    MigrationsConfig `mapstructure:"migrations"`
-	Jobs          JobsConfig       `mapstructure:"jobs"`
-	Deno          DenoConfig       `mapstructure:"deno"`
-	Tracing       TracingConfig    `mapstructure:"tracing"`
-	Metrics       MetricsConfig    `mapstructure:"metrics"`
-	AI            AIConfig         `mapstructure:"ai"`
-	RPC           RPCConfig        `mapstructure:"rpc"`
-	GraphQL       GraphQLConfig    `mapstructure:"graphql"`
-	MCP           MCPConfig        `mapstructure:"mcp"`
-	Branching     BranchingConfig  `mapstructure:"branching"`
-	Scaling       ScalingConfig    `mapstructure:"scaling"`
-	Logging       LoggingConfig    `mapstructure:"logging"`
-	Admin         AdminConfig      `mapstructure:"admin"`
-	BaseURL       string           `mapstructure:"base_url"`        // Internal base URL (for server-to-server communication)
-	PublicBaseURL string           `mapstructure:"public_base_url"` // Public base URL (for user-facing links, OAuth callbacks, etc.)
-	Debug         bool             `mapstructure:"debug"`
+	Server        ServerConfig        `mapstructure:"server"`
+	Database      DatabaseConfig      `mapstructure:"database"`
+	Auth          AuthConfig          `mapstructure:"auth"`
+	Security      SecurityConfig      `mapstructure:"security"`
+	CORS          CORSConfig          `mapstructure:"cors"`
+	Storage       StorageConfig       `mapstructure:"storage"`
+	Realtime      RealtimeConfig      `mapstructure:"realtime"`
+	Email         EmailConfig         `mapstructure:"email"`
+	Functions     FunctionsConfig     `mapstructure:"functions"`
+	API           APIConfig           `mapstructure:"api"`
+	Migrations    MigrationsConfig    `mapstructure:"migrations"`
+	Jobs          JobsConfig          `mapstructure:"jobs"`
+	Deno          DenoConfig          `mapstructure:"deno"`
+	Tracing       TracingConfig       `mapstructure:"tracing"`
+	Metrics       MetricsConfig       `mapstructure:"metrics"`
+	AI            AIConfig            `mapstructure:"ai"`
+	RPC           RPCConfig           `mapstructure:"rpc"`
+	GraphQL       GraphQLConfig       `mapstructure:"graphql"`
+	MCP           MCPConfig           `mapstructure:"mcp"`
+	Branching     BranchingConfig     `mapstructure:"branching"`
+	Scaling       ScalingConfig       `mapstructure:"scaling"`
+	Logging       LoggingConfig       `mapstructure:"logging"`
+	Metering      MeteringConfig      `mapstructure:"metering"`
+	Quotas        QuotasConfig        `mapstructure:"quotas"`
+	Analytics     AnalyticsConfig     `mapstructure:"analytics"`
+	Notifications NotificationsConfig `mapstructure:"notifications"`
+	TableStats    TableStatsConfig    `mapstructure:"table_stats"`
+	Maintenance   MaintenanceConfig   `mapstructure:"maintenance"`
+	ScheduledJobs ScheduledJobsConfig `mapstructure:"scheduled_jobs"`
+	Queues        QueuesConfig        `mapstructure:"queues"`
+	DataExport    DataExportConfig    `mapstructure:"data_export"`
+	Egress        EgressConfig        `mapstructure:"egress"`
+	TableSync     TableSyncConfig     `mapstructure:"table_sync"`
+	Preflight     PreflightConfig     `mapstructure:"preflight"`
+	Audit         AuditConfig         `mapstructure:"audit"`
+	Admin         AdminConfig         `mapstructure:"admin"`
+	BaseURL       string              `mapstructure:"base_url"`        // Internal base URL (for server-to-server communication)
+	PublicBaseURL string              `mapstructure:"public_base_url"` // Public base URL (for user-facing links, OAuth callbacks, etc.)
+	Debug         bool                `mapstructure:"debug"`
 
 	// EncryptionKey is used to encrypt sensitive data stored in the database (e.g., client keys, credentials)
 	// Must be exactly 32 bytes for AES-256. Generate with: openssl rand -base64 32 | head -c 32
@@ -79,6 +93,10 @@ type ScalingConfig struct {
 	// This is the recommended setting for multi-instance deployments
 	EnableSchedulerLeaderElection bool `mapstructure:"enable_scheduler_leader_election"`
 
+	// NodeID identifies this instance in the cluster status endpoint
+	// Defaults to "<hostname>-<pid>" when empty
+	NodeID string `mapstructure:"node_id"`
+
 	// Backend for distributed state (rate limiting, pub/sub, sessions)
 	// Options: "local" (single instance), "postgres", "redis"
 	// "redis" works with Dragonfly (recommended), Redis, Valkey, KeyDB
@@ -88,6 +106,41 @@ type ScalingConfig struct {
 	// Only used when Backend is "redis"
 	// Format: redis://[password@]host:port[/db]
 	RedisURL string `mapstructure:"redis_url"`
+
+	// RateLimitBackend, CacheBackend and QueueBackend override Backend for a single component,
+	// so components can be scaled independently. Empty uses Backend.
+	// Rate limiting and caches accept "local", "postgres" or "redis"; the queue also accepts "nats"
+	RateLimitBackend string `mapstructure:"rate_limit_backend"`
+	CacheBackend     string `mapstructure:"cache_backend"`
+	QueueBackend     string `mapstructure:"queue_backend"`
+
+	// NATSURL is the connection URL for the NATS queue backend
+	// Format: nats://[user:password@]host:port
+	NATSURL string `mapstructure:"nats_url"`
+}
+
+// Components whose scaling backend can be chosen independently
+const (
+	ScalingComponentRateLimit = "rate_limit"
+	ScalingComponentCache     = "cache"
+	ScalingComponentQueue     = "queue"
+)
+
+// BackendFor returns the backend configured for a component, falling back to Backend
+func (sc *ScalingConfig) BackendFor(component string) string {
+	var override string
+	switch component {
+	case ScalingComponentRateLimit:
+		override = sc.RateLimitBackend
+	case ScalingComponentCache:
+		override = sc.CacheBackend
+	case ScalingComponentQueue:
+		override = sc.QueueBackend
+	}
+	if override != "" {
+		return override
+	}
+	return sc.Backend
 }
 
 // TracingConfig contains OpenTelemetry tracing settings
@@ -119,6 +172,9 @@ type ServerConfig struct {
 
 	// Per-endpoint body limits (if not specified, uses defaults from middleware)
 	BodyLimits BodyLimitsConfig `mapstructure:"body_limits"`
+
+	// Shed low-priority traffic when the server is overloaded
+	LoadShedding LoadSheddingConfig `mapstructure:"load_shedding"`
 }
 
 // BodyLimitsConfig contains per-endpoint body size limits
@@ -157,6 +213,12 @@ type DatabaseConfig struct {
 	MaxConnIdleTime    time.Duration `mapstructure:"max_conn_idle_time"`
 	HealthCheck        time.Duration `mapstructure:"health_check_period"`
 	UserMigrationsPath string        `mapstructure:"user_migrations_path"` // Path to user-provided migration files
+
+	// Read replicas serving read-only REST queries, as connection strings
+	ReadReplicas          []string      `mapstructure:"read_replicas"`
+	ReplicaMaxLag         time.Duration `mapstructure:"replica_max_lag"`         // Replicas further behind the primary are skipped (default: 10s)
+	ReplicaHealthCheck    time.Duration `mapstructure:"replica_health_check"`    // How often replica health and lag are checked (default: 5s)
+	ReplicaMaxConnections int32         `mapstructure:"replica_max_connections"` // Pool size per replica (0 = max_connections)
 }
 
 // AuthConfig contains authentication settings
@@ -174,6 +236,16 @@ type AuthConfig struct {
 	MagicLinkEnabled    bool          `mapstructure:"magic_link_enabled"`
 	TOTPIssuer          string        `mapstructure:"totp_issuer"` // Issuer name displayed in authenticator apps for 2FA (e.g., "MyApp")
 
+	// EmailNormalization treats provider aliases of an address (Gmail dots, "+tag" suffixes)
+	// as the same account: signup rejects an alias of an existing user, and sign-in accepts one.
+	// Default: true
+	EmailNormalization bool `mapstructure:"email_normalization_enabled"`
+
+	// NewDeviceAlerts emails users when a sign-in succeeds from a device they haven't signed in
+	// from before, identified by the device_fingerprint sent with the sign-in.
+	// Default: true
+	NewDeviceAlerts bool `mapstructure:"new_device_alerts_enabled"`
+
 	// OAuth/OIDC provider configuration (unified for all providers)
 	// Well-known providers (google, apple, microsoft) auto-detect issuer URLs
 	// Custom providers require explicit issuer_url (supports base URLs like https://auth.domain.com or full .well-known URLs)
@@ -279,8 +351,47 @@ type SecurityConfig struct {
 	// Per-user rate limiting (track by user ID instead of IP for authenticated users)
 	EnablePerUserRateLimit bool `mapstructure:"enable_per_user_rate_limit"` // Enable per-user rate limiting (instead of per-IP)
 
+	// Algorithm counting requests against rate limits: "fixed_window", "sliding_window" or "token_bucket"
+	RateLimitAlgorithm string `mapstructure:"rate_limit_algorithm"`
+
 	// CAPTCHA configuration for bot protection
 	Captcha CaptchaConfig `mapstructure:"captcha"`
+
+	// HTTP security response headers (CSP, HSTS, X-Frame-Options, ...)
+	Headers SecurityHeadersConfig `mapstructure:"headers"`
+
+	// Detection of committed credentials in function code and public knowledge base documents
+	SecretScanning SecretScanningConfig `mapstructure:"secret_scanning"`
+}
+
+// SecretScanningConfig sets how detected credentials are handled: "block" rejects the upload
+// unless an admin gives an override reason, "flag" accepts it and records the finding, "off" skips scanning.
+type SecretScanningConfig struct {
+	Functions      string `mapstructure:"functions"`       // Edge function and shared module deploys (default: block)
+	KnowledgeBases string `mapstructure:"knowledge_bases"` // Documents added to public knowledge bases (default: block)
+}
+
+// SecurityHeadersConfig contains the HTTP security headers sent with every response.
+// Empty values keep the built-in defaults; "{nonce}" in a CSP is replaced with a per-request nonce.
+type SecurityHeadersConfig struct {
+	Enabled                        bool                         `mapstructure:"enabled"`                           // Send security headers (default: true)
+	ContentSecurityPolicy          string                       `mapstructure:"content_security_policy"`           // CSP for API routes
+	DashboardContentSecurityPolicy string                       `mapstructure:"dashboard_content_security_policy"` // CSP for the admin dashboard (/admin)
+	XFrameOptions                  string                       `mapstructure:"x_frame_options"`                   // X-Frame-Options (default: DENY)
+	StrictTransportSecurity        string                       `mapstructure:"strict_transport_security"`         // HSTS, only sent over HTTPS
+	ReferrerPolicy                 string                       `mapstructure:"referrer_policy"`                   // Referrer-Policy
+	PermissionsPolicy              string                       `mapstructure:"permissions_policy"`                // Permissions-Policy
+	Routes                         []SecurityHeadersRouteConfig `mapstructure:"routes"`                            // Per-route overrides, longest path prefix wins
+}
+
+// SecurityHeadersRouteConfig overrides security headers for requests under a path prefix
+type SecurityHeadersRouteConfig struct {
+	PathPrefix              string `mapstructure:"path_prefix"`
+	ContentSecurityPolicy   string `mapstructure:"content_security_policy"`
+	XFrameOptions           string `mapstructure:"x_frame_options"`
+	StrictTransportSecurity string `mapstructure:"strict_transport_security"`
+	ReferrerPolicy          string `mapstructure:"referrer_policy"`
+	PermissionsPolicy       string `mapstructure:"permissions_policy"`
 }
 
 // CaptchaConfig contains CAPTCHA verification settings for bot protection
@@ -358,6 +469,26 @@ type StorageConfig struct {
 
 	// Image transformation settings
 	Transforms TransformConfig `mapstructure:"transforms"`
+
+	// Resumable upload (tus protocol) settings
+	TUS TUSConfig `mapstructure:"tus"`
+
+	// Lifecycle rule (expiry and archival) settings
+	Lifecycle LifecycleConfig `mapstructure:"lifecycle"`
+}
+
+// TUSConfig contains settings for resumable uploads through the tus protocol
+type TUSConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`    // Serve the tus upload endpoints
+	ChunkSize  int64         `mapstructure:"chunk_size"` // Bytes stored per chunk, at least 5MB (S3 part minimum)
+	Expiration time.Duration `mapstructure:"expiration"` // How long an unfinished upload can be resumed
+}
+
+// LifecycleConfig contains settings for the sweeper applying bucket lifecycle rules
+type LifecycleConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`    // Apply lifecycle rules in the background
+	Interval  time.Duration `mapstructure:"interval"`   // How often the rules are applied
+	BatchSize int           `mapstructure:"batch_size"` // Objects processed per rule and batch
 }
 
 // TransformConfig contains image transformation settings
@@ -379,6 +510,8 @@ type TransformConfig struct {
 	CacheEnabled bool          `mapstructure:"cache_enabled"`  // Enable transform caching
 	CacheTTL     time.Duration `mapstructure:"cache_ttl"`      // Cache TTL (default 24h)
 	CacheMaxSize int64         `mapstructure:"cache_max_size"` // Max cache size in bytes (default 1GB)
+	CacheBackend string        `mapstructure:"cache_backend"`  // Where variants are cached: "bucket" (storage provider) or "disk"
+	CacheDir     string        `mapstructure:"cache_dir"`      // Directory of the disk cache backend
 }
 
 // RealtimeConfig contains realtime/websocket settings
@@ -401,6 +534,32 @@ type RealtimeConfig struct {
 	ClientMessageQueueSize int           `mapstructure:"client_message_queue_size"` // Size of per-client message queue for async sending (default: 256)
 	SlowClientThreshold    int           `mapstructure:"slow_client_threshold"`     // Queue length threshold for slow client detection (default: 100)
 	SlowClientTimeout      time.Duration `mapstructure:"slow_client_timeout"`       // Duration before disconnecting slow clients (default: 30s)
+
+	// Access rules for broadcast and presence channels, channels matching no rule are public
+	Channels []RealtimeChannelRule `mapstructure:"channels"`
+
+	// Logical replication change capture, replacing the notify trigger of newly enabled tables
+	Replication RealtimeReplicationConfig `mapstructure:"replication"`
+}
+
+// RealtimeReplicationConfig streams table changes from the WAL through a publication
+// instead of notify triggers. Requires wal_level=logical and a database admin user with
+// the REPLICATION attribute.
+type RealtimeReplicationConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	Publication    string        `mapstructure:"publication"`     // Publication realtime tables are added to (default: fluxbase_realtime)
+	SlotPrefix     string        `mapstructure:"slot_prefix"`     // Prefix of each instance's temporary replication slot (default: fluxbase_realtime)
+	StatusInterval time.Duration `mapstructure:"status_interval"` // How often the processed WAL position is reported (default: 10s)
+}
+
+// RealtimeChannelRule restricts the broadcast and presence channels starting with Prefix
+type RealtimeChannelRule struct {
+	Prefix string   `mapstructure:"prefix"` // Channels starting with the prefix, e.g. "team:"
+	Roles  []string `mapstructure:"roles"`  // Roles allowed, empty for any authenticated user
+	// JWT claim the rest of the channel name must equal, e.g. team_id for team:<team_id>.
+	// Nested claims use dots (app_metadata.team_id), array claims match any element.
+	Claim   string   `mapstructure:"claim"`
+	Actions []string `mapstructure:"actions"` // Allowed actions (subscribe, broadcast, presence), empty for all
 }
 
 // EmailConfig contains email/SMTP settings
@@ -434,6 +593,34 @@ type EmailConfig struct {
 	MagicLinkTemplate     string `mapstructure:"magic_link_template"`
 	VerificationTemplate  string `mapstructure:"verification_template"`
 	PasswordResetTemplate string `mapstructure:"password_reset_template"`
+
+	// MaxPerHour caps the emails sent through this provider per hour across all instances
+	// (0 = unlimited). Once reached, email fails over to the profiles in Failover.
+	MaxPerHour int `mapstructure:"max_per_hour"`
+
+	// Failover lists profile names tried in order when sending through this provider fails
+	// or its quota is exhausted ("default" is the provider configured above)
+	Failover []string `mapstructure:"failover"`
+
+	// Profiles are additional named provider profiles, selected by Routing
+	Profiles []EmailProfileConfig `mapstructure:"profiles"`
+
+	// Routing selects a profile by email category or namespace
+	Routing EmailRoutingConfig `mapstructure:"routing"`
+}
+
+// EmailProfileConfig is a named email provider profile. It accepts the provider settings of
+// EmailConfig: provider, from address, provider credentials, max_per_hour and failover.
+type EmailProfileConfig struct {
+	Name        string `mapstructure:"name"`
+	EmailConfig `mapstructure:",squash"`
+}
+
+// EmailRoutingConfig maps email categories and namespaces to profile names.
+// A namespace route takes precedence over a category route; unrouted email uses "default".
+type EmailRoutingConfig struct {
+	Categories map[string]string `mapstructure:"categories"` // "auth" or "notification" -> profile
+	Namespaces map[string]string `mapstructure:"namespaces"` // namespace -> profile
 }
 
 // FunctionsConfig contains edge functions settings
@@ -455,6 +642,41 @@ type APIConfig struct {
 	MaxTotalResults int `mapstructure:"max_total_results"` // Max total retrievable rows via offset+limit (-1 = unlimited)
 	DefaultPageSize int `mapstructure:"default_page_size"` // Auto-applied when no limit specified (-1 = no default)
 	MaxBatchSize    int `mapstructure:"max_batch_size"`    // Max records in batch insert/update (-1 = unlimited, default: 1000)
+
+	// Schemas reachable through /api/v1/tables (admins and the service role can reach all of them)
+	ExposedSchemas []string `mapstructure:"exposed_schemas"` // Allowlist (empty = every schema not hidden)
+	HiddenSchemas  []string `mapstructure:"hidden_schemas"`  // Denylist, applied after exposed_schemas (default: internal schemas)
+
+	// Reject REST writes with 405 regardless of database grants (the service role is exempt)
+	ReadOnlySchemas []string `mapstructure:"read_only_schemas"` // Schemas whose tables are read-only
+	ReadOnlyTables  []string `mapstructure:"read_only_tables"`  // "schema.table" entries, or bare names for public tables
+
+	// Snapshot pagination ("Prefer: snapshot"); each open snapshot holds a database connection
+	SnapshotTTL      time.Duration `mapstructure:"snapshot_ttl"`       // Default snapshot lifetime (default: 5m)
+	SnapshotMaxTTL   time.Duration `mapstructure:"snapshot_max_ttl"`   // Longest lifetime a client may request (default: 1h)
+	MaxOpenSnapshots int           `mapstructure:"max_open_snapshots"` // Snapshots held per node (0 = disabled, default: 10)
+
+	// Aggregate result caching ("Prefer: max-stale=<seconds>") for GET requests with aggregations or group_by
+	AggregateCacheMaxStale   time.Duration `mapstructure:"aggregate_cache_max_stale"`   // Longest staleness a client may accept (default: 5m)
+	AggregateCacheMaxEntries int           `mapstructure:"aggregate_cache_max_entries"` // Cached results per node (0 = disabled, default: 1000)
+
+	// Bulk updates and deletes by filter (PATCH/DELETE /tables/:table/bulk) roll back when they affect more rows
+	BulkMaxAffectedRows int `mapstructure:"bulk_max_affected_rows"` // Most rows one bulk update or delete may change (0 = unlimited, default: 10000)
+
+	// Prepared statements for REST reads; query shapes seen before run as cached statements on each connection
+	StatementCacheSize int `mapstructure:"statement_cache_size"` // Query shapes tracked per node (0 = disabled, default: 256)
+
+	// Streaming CSV exports (GET /tables/:table/export); each running export holds a database connection
+	ExportRateLimit  int           `mapstructure:"export_rate_limit"`  // Exports per client key, user or IP per window (0 = unlimited, default: 10)
+	ExportRateWindow time.Duration `mapstructure:"export_rate_window"` // Window of export_rate_limit (default: 1m)
+
+	// Usage analytics (per client key and per user)
+	UsageTracking      bool          `mapstructure:"usage_tracking"`       // Record hourly usage rollups (default: true)
+	UsageFlushInterval time.Duration `mapstructure:"usage_flush_interval"` // How often rollups are written to the database (default: 30s)
+	UsageRetentionDays int           `mapstructure:"usage_retention_days"` // Days to keep rollups (0 = forever, default: 90)
+
+	// Reject requests to built-in endpoints that do not match their OpenAPI schemas before handlers run
+	ValidateRequests []string `mapstructure:"validate_requests"` // Route groups under /api/v1, e.g. "auth", "storage" ("*" = all, empty = off)
 }
 
 // JobsConfig contains long-running background jobs settings
@@ -498,6 +720,7 @@ type AIConfig struct {
 	ConversationCacheTTL time.Duration `mapstructure:"conversation_cache_ttl"` // TTL for conversation cache
 	MaxConversationTurns int           `mapstructure:"max_conversation_turns"` // Max turns per conversation
 	SyncAllowedIPRanges  []string      `mapstructure:"sync_allowed_ip_ranges"` // IP CIDR ranges allowed to sync chatbots
+	MatchUserLanguage    bool          `mapstructure:"match_user_language"`    // Reply in the server-side detected language of each user message
 
 	// Provider Configuration (read-only in dashboard when set)
 	// If ProviderType is set, a config-based provider will be added to the list
@@ -510,6 +733,16 @@ type AIConfig struct {
 	EmbeddingProvider string `mapstructure:"embedding_provider"` // Embedding provider: openai, azure, ollama (defaults to ProviderType)
 	EmbeddingModel    string `mapstructure:"embedding_model"`    // Embedding model: text-embedding-3-small, text-embedding-3-large, etc.
 
+	// Embedding batching (document ingestion and POST /api/v1/ai/embeddings)
+	EmbeddingBatchSize      int `mapstructure:"embedding_batch_size"`       // Max texts sent to the provider per call
+	EmbeddingBatchMaxTokens int `mapstructure:"embedding_batch_max_tokens"` // Max estimated tokens sent to the provider per call
+	EmbeddingConcurrency    int `mapstructure:"embedding_concurrency"`      // Provider calls in flight at once per batched request
+	EmbeddingMaxRetries     int `mapstructure:"embedding_max_retries"`      // Retries of a call the provider rate limited (429) or was unavailable for (503)
+
+	// Embeddings API Configuration (POST /api/v1/ai/embeddings)
+	EmbeddingMaxInputs       int `mapstructure:"embedding_max_inputs"`        // Max texts accepted per API request
+	EmbeddingDailyTokenQuota int `mapstructure:"embedding_daily_token_quota"` // Per-user daily token quota for the embeddings API (0 = unlimited)
+
 	// OpenAI Settings
 	OpenAIAPIKey         string `mapstructure:"openai_api_key"`
 	OpenAIOrganizationID string `mapstructure:"openai_organization_id"`
@@ -530,7 +763,7 @@ type AIConfig struct {
 
 	// OCR Configuration (for image-based PDF extraction in knowledge bases)
 	OCREnabled   bool     `mapstructure:"ocr_enabled"`   // Enable OCR for image-based PDFs
-	OCRProvider  string   `mapstructure:"ocr_provider"`  // OCR provider: tesseract
+	OCRProvider  string   `mapstructure:"ocr_provider"`  // OCR provider: tesseract (library, falls back to the binary) or tesseract_cli
 	OCRLanguages []string `mapstructure:"ocr_languages"` // Default languages for OCR (e.g., ["eng", "deu"])
 
 	// RAG Configuration (for retrieval-augmented generation)
@@ -724,6 +957,13 @@ func setDefaults() {
 	viper.SetDefault("server.body_limits.admin_limit", 5*1024*1024)     // 5MB for admin
 	viper.SetDefault("server.body_limits.max_json_depth", 64)           // Max JSON nesting
 
+	// Load shedding defaults
+	viper.SetDefault("server.load_shedding.enabled", false)
+	viper.SetDefault("server.load_shedding.pool_saturation", 0.9)
+	viper.SetDefault("server.load_shedding.latency_threshold", "1s")
+	viper.SetDefault("server.load_shedding.cooldown", "10s")
+	viper.SetDefault("server.load_shedding.protected_paths", []string{})
+
 	// Database defaults
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", 5432)
@@ -742,6 +982,10 @@ func setDefaults() {
 	viper.SetDefault("database.max_conn_lifetime", "1h")
 	viper.SetDefault("database.max_conn_idle_time", "30m")
 	viper.SetDefault("database.health_check_period", "1m")
+	viper.SetDefault("database.read_replicas", []string{})
+	viper.SetDefault("database.replica_max_lag", "10s")
+	viper.SetDefault("database.replica_health_check", "5s")
+	viper.SetDefault("database.replica_max_connections", 0)
 	viper.SetDefault("database.user_migrations_path", "/migrations/user")
 
 	// Auth defaults
@@ -756,7 +1000,9 @@ func setDefaults() {
 	viper.SetDefault("auth.bcrypt_cost", 10)
 	viper.SetDefault("auth.signup_enabled", true) // Default to enabled to allow user registration
 	viper.SetDefault("auth.magic_link_enabled", true)
-	viper.SetDefault("auth.totp_issuer", "Fluxbase") // Default issuer name for 2FA TOTP (shown in authenticator apps)
+	viper.SetDefault("auth.email_normalization_enabled", true) // Treat gmail dots and +tag aliases as one account
+	viper.SetDefault("auth.new_device_alerts_enabled", true)   // Email users about sign-ins from new devices
+	viper.SetDefault("auth.totp_issuer", "Fluxbase")           // Default issuer name for 2FA TOTP (shown in authenticator apps)
 
 	// Security defaults
 	viper.SetDefault("security.enable_global_rate_limit", true) // Enabled by default for security (can be disabled if needed)
@@ -772,6 +1018,20 @@ func setDefaults() {
 	viper.SetDefault("security.service_role_rate_limit", 10000)   // 10000 requests per minute for service_role tokens (H-2)
 	viper.SetDefault("security.service_role_rate_window", "1m")   // per minute
 	viper.SetDefault("security.enable_per_user_rate_limit", true) // Enable per-user rate limiting for authenticated users
+	viper.SetDefault("security.rate_limit_algorithm", "fixed_window")
+
+	// Security headers defaults (empty values use the built-in policies)
+	viper.SetDefault("security.headers.enabled", true)
+	viper.SetDefault("security.headers.content_security_policy", "")
+	viper.SetDefault("security.headers.dashboard_content_security_policy", "")
+	viper.SetDefault("security.headers.x_frame_options", "")
+	viper.SetDefault("security.headers.strict_transport_security", "")
+	viper.SetDefault("security.headers.referrer_policy", "")
+	viper.SetDefault("security.headers.permissions_policy", "")
+
+	// Secret scanning defaults
+	viper.SetDefault("security.secret_scanning.functions", "block")
+	viper.SetDefault("security.secret_scanning.knowledge_bases", "block")
 
 	// CAPTCHA defaults
 	viper.SetDefault("security.captcha.enabled", false)       // Disabled by default
@@ -808,8 +1068,8 @@ func setDefaults() {
 	// CORS defaults
 	viper.SetDefault("cors.allowed_origins", "http://localhost:5173,http://localhost:8080")
 	viper.SetDefault("cors.allowed_methods", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
-	viper.SetDefault("cors.allowed_headers", "Origin,Content-Type,Accept,Authorization,X-Request-ID,X-CSRF-Token,X-Impersonation-Token,Prefer,apikey,x-client-app")
-	viper.SetDefault("cors.exposed_headers", "Content-Range,Content-Encoding,Content-Length,X-Request-ID,X-RateLimit-Limit,X-RateLimit-Remaining,X-RateLimit-Reset")
+	viper.SetDefault("cors.allowed_headers", "Origin,Content-Type,Accept,Authorization,X-Request-ID,X-CSRF-Token,X-Impersonation-Token,Prefer,Accept-Profile,Content-Profile,X-Snapshot-Token,apikey,x-client-app,Tus-Resumable,Upload-Length,Upload-Metadata,Upload-Offset")
+	viper.SetDefault("cors.exposed_headers", "Content-Range,Content-Profile,Content-Encoding,Content-Length,X-Request-ID,X-RateLimit-Limit,X-RateLimit-Remaining,X-RateLimit-Reset,RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,Retry-After,X-Snapshot-Token,X-Snapshot-Expires-At,Location,Tus-Resumable,Tus-Version,Tus-Extension,Tus-Max-Size,Upload-Offset,Upload-Length,Upload-Expires")
 	viper.SetDefault("cors.allow_credentials", true) // Required for CSRF tokens
 	viper.SetDefault("cors.max_age", 300)
 
@@ -842,6 +1102,18 @@ func setDefaults() {
 	viper.SetDefault("storage.transforms.cache_enabled", true)
 	viper.SetDefault("storage.transforms.cache_ttl", "24h")
 	viper.SetDefault("storage.transforms.cache_max_size", 1024*1024*1024) // 1GB
+	viper.SetDefault("storage.transforms.cache_backend", "bucket")
+	viper.SetDefault("storage.transforms.cache_dir", "./storage/.transform_cache")
+
+	// Resumable upload defaults
+	viper.SetDefault("storage.tus.enabled", true)
+	viper.SetDefault("storage.tus.chunk_size", 8*1024*1024) // 8MB
+	viper.SetDefault("storage.tus.expiration", "24h")
+
+	// Lifecycle rule defaults
+	viper.SetDefault("storage.lifecycle.enabled", true)
+	viper.SetDefault("storage.lifecycle.interval", "1h")
+	viper.SetDefault("storage.lifecycle.batch_size", 500)
 
 	// Realtime defaults
 	viper.SetDefault("realtime.enabled", true)
@@ -862,6 +1134,10 @@ func setDefaults() {
 	viper.SetDefault("realtime.client_message_queue_size", 256) // Per-client message queue for async sending
 	viper.SetDefault("realtime.slow_client_threshold", 100)     // Disconnect clients with 100+ pending messages
 	viper.SetDefault("realtime.slow_client_timeout", "30s")     // After 30s of being slow
+	viper.SetDefault("realtime.replication.enabled", false)
+	viper.SetDefault("realtime.replication.publication", "fluxbase_realtime")
+	viper.SetDefault("realtime.replication.slot_prefix", "fluxbase_realtime")
+	viper.SetDefault("realtime.replication.status_interval", "10s")
 
 	// Email defaults
 	viper.SetDefault("email.enabled", true)
@@ -910,6 +1186,23 @@ func setDefaults() {
 	viper.SetDefault("api.max_total_results", 10000) // Max 10k total rows retrievable
 	viper.SetDefault("api.default_page_size", 1000)  // Default to 1000 rows if not specified
 	viper.SetDefault("api.max_batch_size", 1000)     // Max 1000 records in batch insert/update (H-4)
+	viper.SetDefault("api.exposed_schemas", []string{})
+	viper.SetDefault("api.hidden_schemas", []string{"auth", "dashboard", "api", "app", "audit", "branching", "migrations", "mcp", "system"})
+	viper.SetDefault("api.read_only_schemas", []string{})
+	viper.SetDefault("api.read_only_tables", []string{})
+	viper.SetDefault("api.snapshot_ttl", "5m")
+	viper.SetDefault("api.snapshot_max_ttl", "1h")
+	viper.SetDefault("api.max_open_snapshots", 10)
+	viper.SetDefault("api.aggregate_cache_max_stale", "5m")
+	viper.SetDefault("api.aggregate_cache_max_entries", 1000)
+	viper.SetDefault("api.statement_cache_size", 256)
+	viper.SetDefault("api.bulk_max_affected_rows", 10000)
+	viper.SetDefault("api.export_rate_limit", 10)
+	viper.SetDefault("api.export_rate_window", "1m")
+	viper.SetDefault("api.usage_tracking", true)
+	viper.SetDefault("api.usage_flush_interval", "30s")
+	viper.SetDefault("api.usage_retention_days", 90)
+	viper.SetDefault("api.validate_requests", []string{})
 
 	// Migrations defaults
 	viper.SetDefault("migrations.enabled", true) // Enabled by default for better DX (security still enforced via service key + IP allowlist)
@@ -969,6 +1262,7 @@ func setDefaults() {
 	viper.SetDefault("ai.max_rows_per_query", 1000)      // Max 1000 rows per query
 	viper.SetDefault("ai.conversation_cache_ttl", "30m") // 30 minute cache TTL
 	viper.SetDefault("ai.max_conversation_turns", 50)    // Max 50 turns per conversation
+	viper.SetDefault("ai.match_user_language", false)    // Leave the reply language to the model unless enabled
 	viper.SetDefault("ai.sync_allowed_ip_ranges", []string{
 		"172.16.0.0/12",  // Docker default bridge networks
 		"10.0.0.0/8",     // Private networks (AWS VPC, etc.)
@@ -995,6 +1289,12 @@ func setDefaults() {
 	viper.SetDefault("ai.embedding_provider", "")              // Defaults to ai.provider_type if empty
 	viper.SetDefault("ai.embedding_model", "")                 // Empty = use provider-specific default (openai: text-embedding-3-small, azure: text-embedding-ada-002, ollama: nomic-embed-text)
 	viper.SetDefault("ai.azure_embedding_deployment_name", "") // Optional separate Azure embedding deployment
+	viper.SetDefault("ai.embedding_batch_size", 100)           // Texts per provider call
+	viper.SetDefault("ai.embedding_batch_max_tokens", 100000)  // Estimated tokens per provider call
+	viper.SetDefault("ai.embedding_concurrency", 4)            // Provider calls in flight per batched request
+	viper.SetDefault("ai.embedding_max_retries", 5)            // Retries of rate limited provider calls
+	viper.SetDefault("ai.embedding_max_inputs", 2048)          // Texts per embeddings API request
+	viper.SetDefault("ai.embedding_daily_token_quota", 0)      // Unlimited by default
 
 	// AI OCR Configuration defaults (for image-based PDF extraction)
 	viper.SetDefault("ai.ocr_enabled", true)              // Enabled by default (will gracefully degrade if Tesseract not installed)
@@ -1023,6 +1323,87 @@ func setDefaults() {
 	viper.SetDefault("graphql.allow_fragments", false) // H-5: Fragment spreads disabled by default (security)
 	viper.SetDefault("graphql.max_fields_per_lvl", 50) // H-6: Max 50 unique fields per level (alias abuse protection)
 
+	// Metering defaults (billing exports)
+	viper.SetDefault("metering.enabled", false)
+	viper.SetDefault("metering.interval", "1h")
+	viper.SetDefault("metering.bucket", "")
+	viper.SetDefault("metering.path_prefix", "metering")
+	viper.SetDefault("metering.webhook_url", "")
+	viper.SetDefault("metering.webhook_secret", "")
+	viper.SetDefault("metering.webhook_timeout", "30s")
+	viper.SetDefault("metering.max_catch_up", 24)
+
+	// Usage quota defaults
+	viper.SetDefault("quotas.enabled", false)
+	viper.SetDefault("quotas.flush_interval", "10s")
+	viper.SetDefault("quotas.cache_ttl", "1m")
+
+	// Analytics defaults (warehouse exports)
+	viper.SetDefault("analytics.enabled", false)
+	viper.SetDefault("analytics.interval", "15m")
+	viper.SetDefault("analytics.bucket", "")
+	viper.SetDefault("analytics.path_prefix", "analytics")
+	viper.SetDefault("analytics.sources", []string{})
+	viper.SetDefault("analytics.batch_size", 10000)
+
+	// Notification center defaults (credential expiry and configuration drift checks)
+	viper.SetDefault("notifications.enabled", true)
+	viper.SetDefault("notifications.check_interval", "1h")
+	viper.SetDefault("notifications.expiry_warning_days", 30)
+	viper.SetDefault("notifications.storage_quota_bytes", 0)
+	viper.SetDefault("notifications.storage_warning_percent", 80)
+	viper.SetDefault("notifications.email_failure_threshold", 3)
+
+	// Table stats defaults (daily table size snapshots)
+	viper.SetDefault("table_stats.enabled", true)
+	viper.SetDefault("table_stats.retention_days", 90)
+
+	// Audit log defaults
+	viper.SetDefault("audit.enabled", true)
+	viper.SetDefault("audit.retention_days", 365)
+
+	// Table maintenance defaults (VACUUM / ANALYZE schedules)
+	viper.SetDefault("maintenance.enabled", true)
+	viper.SetDefault("maintenance.run_timeout", "2h")
+	viper.SetDefault("maintenance.history_retention_days", 90)
+
+	// Scheduled jobs defaults (edge functions and SQL snippets on cron schedules)
+	viper.SetDefault("scheduled_jobs.enabled", true)
+	viper.SetDefault("scheduled_jobs.run_timeout", "10m")
+	viper.SetDefault("scheduled_jobs.max_concurrent", 5)
+	viper.SetDefault("scheduled_jobs.max_output_bytes", 65536)
+	viper.SetDefault("scheduled_jobs.history_retention_days", 30)
+
+	// Message queue defaults
+	viper.SetDefault("queues.enabled", true)
+	viper.SetDefault("queues.max_message_bytes", 262144)
+	viper.SetDefault("queues.max_batch_size", 100)
+
+	// Data export defaults (self-serve data subject access requests)
+	viper.SetDefault("data_export.enabled", true)
+	viper.SetDefault("data_export.bucket", "data-exports")
+	viper.SetDefault("data_export.retention", "168h")
+
+	// Egress policy defaults (outbound requests from functions, jobs and AI providers)
+	viper.SetDefault("egress.enabled", false)
+	viper.SetDefault("egress.allowed_hosts", []string{})
+	viper.SetDefault("egress.allowed_private_hosts", []string{})
+	viper.SetDefault("egress.proxy_url", "")
+
+	// Table sync defaults (one-way sync of selected tables to another instance)
+	viper.SetDefault("table_sync.enabled", false)
+	viper.SetDefault("table_sync.accept_ingest", false)
+	viper.SetDefault("table_sync.interval", "30s")
+	viper.SetDefault("table_sync.batch_size", 500)
+	viper.SetDefault("table_sync.request_timeout", "30s")
+	viper.SetDefault("table_sync.capture_delay", "5s")
+
+	// Preflight defaults (environment checks run on boot)
+	viper.SetDefault("preflight.enabled", true)
+	viper.SetDefault("preflight.skip", []string{})
+	viper.SetDefault("preflight.check_timeout", "5s")
+	viper.SetDefault("preflight.max_clock_skew", "30s")
+
 	// MCP defaults (Model Context Protocol server for AI assistants)
 	viper.SetDefault("mcp.enabled", true)                      // Enabled by default
 	viper.SetDefault("mcp.base_path", "/mcp")                  // Default MCP endpoint path
@@ -1060,7 +1441,12 @@ func setDefaults() {
 	viper.SetDefault("scaling.disable_realtime", false)                 // Run realtime by default
 	viper.SetDefault("scaling.enable_scheduler_leader_election", false) // Disabled by default (single instance)
 	viper.SetDefault("scaling.backend", "local")                        // Use local in-memory storage by default
+	viper.SetDefault("scaling.node_id", "")                             // Derived from hostname and PID when empty
 	viper.SetDefault("scaling.redis_url", "")                           // No Redis URL by default
+	viper.SetDefault("scaling.rate_limit_backend", "")                  // Use scaling.backend
+	viper.SetDefault("scaling.cache_backend", "")                       // Use scaling.backend
+	viper.SetDefault("scaling.queue_backend", "")                       // Use scaling.backend
+	viper.SetDefault("scaling.nats_url", "")                            // No NATS URL by default
 
 	// Logging defaults
 	viper.SetDefault("logging.console_enabled", true)
@@ -1165,6 +1551,111 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Validate metering configuration if enabled
+	if c.Metering.Enabled {
+		if err := c.Metering.Validate(); err != nil {
+			return fmt.Errorf("metering configuration error: %w", err)
+		}
+	}
+
+	// Validate usage quota configuration if enabled
+	if c.Quotas.Enabled {
+		if err := c.Quotas.Validate(); err != nil {
+			return fmt.Errorf("quotas configuration error: %w", err)
+		}
+	}
+
+	// Validate analytics export configuration if enabled
+	if c.Analytics.Enabled {
+		if err := c.Analytics.Validate(); err != nil {
+			return fmt.Errorf("analytics configuration error: %w", err)
+		}
+	}
+
+	// Validate load shedding configuration if enabled
+	if c.Server.LoadShedding.Enabled {
+		if err := c.Server.LoadShedding.Validate(); err != nil {
+			return fmt.Errorf("server configuration error: %w", err)
+		}
+	}
+
+	// Validate notifications configuration if enabled
+	if c.Notifications.Enabled {
+		if err := c.Notifications.Validate(); err != nil {
+			return fmt.Errorf("notifications configuration error: %w", err)
+		}
+	}
+
+	// Validate table stats configuration if enabled
+	if c.TableStats.Enabled {
+		if err := c.TableStats.Validate(); err != nil {
+			return fmt.Errorf("table_stats configuration error: %w", err)
+		}
+	}
+
+	// Validate realtime configuration if enabled
+	if c.Realtime.Enabled {
+		if err := c.Realtime.Validate(); err != nil {
+			return fmt.Errorf("realtime configuration error: %w", err)
+		}
+	}
+
+	// Validate audit configuration if enabled
+	if c.Audit.Enabled {
+		if err := c.Audit.Validate(); err != nil {
+			return fmt.Errorf("audit configuration error: %w", err)
+		}
+	}
+
+	// Validate maintenance configuration if enabled
+	if c.Maintenance.Enabled {
+		if err := c.Maintenance.Validate(); err != nil {
+			return fmt.Errorf("maintenance configuration error: %w", err)
+		}
+	}
+
+	// Validate scheduled jobs configuration if enabled
+	if c.ScheduledJobs.Enabled {
+		if err := c.ScheduledJobs.Validate(); err != nil {
+			return fmt.Errorf("scheduled_jobs configuration error: %w", err)
+		}
+	}
+
+	// Validate queues configuration if enabled
+	if c.Queues.Enabled {
+		if err := c.Queues.Validate(); err != nil {
+			return fmt.Errorf("queues configuration error: %w", err)
+		}
+	}
+
+	// Validate data export configuration if enabled
+	if c.DataExport.Enabled {
+		if err := c.DataExport.Validate(); err != nil {
+			return fmt.Errorf("data_export configuration error: %w", err)
+		}
+	}
+
+	// Validate egress configuration if enabled
+	if c.Egress.Enabled {
+		if err := c.Egress.Validate(); err != nil {
+			return fmt.Errorf("egress configuration error: %w", err)
+		}
+	}
+
+	// Validate table sync configuration if enabled
+	if c.TableSync.Enabled {
+		if err := c.TableSync.Validate(); err != nil {
+			return fmt.Errorf("table_sync configuration error: %w", err)
+		}
+	}
+
+	// Validate preflight configuration if enabled
+	if c.Preflight.Enabled {
+		if err := c.Preflight.Validate(); err != nil {
+			return fmt.Errorf("preflight configuration error: %w", err)
+		}
+	}
+
 	// Validate GraphQL configuration if enabled
 	if c.GraphQL.Enabled {
 		if err := c.GraphQL.Validate(); err != nil {
@@ -1330,6 +1821,23 @@ func (dc *DatabaseConfig) Validate() error {
 		return fmt.Errorf("health_check_period must be positive, got: %v", dc.HealthCheck)
 	}
 
+	if len(dc.ReadReplicas) > 0 {
+		for i, replica := range dc.ReadReplicas {
+			if strings.TrimSpace(replica) == "" {
+				return fmt.Errorf("read_replicas[%d] must not be empty", i)
+			}
+		}
+		if dc.ReplicaMaxLag <= 0 {
+			return fmt.Errorf("replica_max_lag must be positive, got: %v", dc.ReplicaMaxLag)
+		}
+		if dc.ReplicaHealthCheck < time.Second {
+			return fmt.Errorf("replica_health_check must be at least 1s, got: %v", dc.ReplicaHealthCheck)
+		}
+		if dc.ReplicaMaxConnections < 0 || dc.ReplicaMaxConnections > 1000 {
+			return fmt.Errorf("replica_max_connections must be between 0 and 1000, got: %d", dc.ReplicaMaxConnections)
+		}
+	}
+
 	return nil
 }
 
@@ -1463,6 +1971,37 @@ func (sc *StorageConfig) Validate() error {
 		return fmt.Errorf("max_upload_size must be positive, got: %d", sc.MaxUploadSize)
 	}
 
+	if sc.Transforms.Enabled && sc.Transforms.CacheEnabled {
+		switch sc.Transforms.CacheBackend {
+		case "", "bucket":
+		case "disk":
+			if sc.Transforms.CacheDir == "" {
+				return fmt.Errorf("transforms.cache_dir is required when using the disk cache backend")
+			}
+		default:
+			return fmt.Errorf("transforms.cache_backend must be 'bucket' or 'disk', got: %s", sc.Transforms.CacheBackend)
+		}
+	}
+
+	if sc.TUS.Enabled {
+		// Every chunk but the last becomes an S3 multipart part, which must be at least 5MB
+		if sc.TUS.ChunkSize < 5*1024*1024 {
+			return fmt.Errorf("tus.chunk_size must be at least 5MB, got: %d", sc.TUS.ChunkSize)
+		}
+		if sc.TUS.Expiration <= 0 {
+			return fmt.Errorf("tus.expiration must be positive, got: %s", sc.TUS.Expiration)
+		}
+	}
+
+	if sc.Lifecycle.Enabled {
+		if sc.Lifecycle.Interval < time.Minute {
+			return fmt.Errorf("lifecycle.interval must be at least 1m, got: %s", sc.Lifecycle.Interval)
+		}
+		if sc.Lifecycle.BatchSize <= 0 {
+			return fmt.Errorf("lifecycle.batch_size must be positive, got: %d", sc.Lifecycle.BatchSize)
+		}
+	}
+
 	return nil
 }
 
@@ -1550,11 +2089,94 @@ func (sc *SecurityConfig) Validate() error {
 		}
 	}
 
+	for i, route := range sc.Headers.Routes {
+		if !strings.HasPrefix(route.PathPrefix, "/") {
+			return fmt.Errorf("headers.routes[%d].path_prefix must start with '/', got '%s'", i, route.PathPrefix)
+		}
+	}
+
+	switch sc.RateLimitAlgorithm {
+	case "", "fixed_window", "sliding_window", "token_bucket":
+	default:
+		return fmt.Errorf("rate_limit_algorithm must be one of fixed_window, sliding_window or token_bucket, got '%s'", sc.RateLimitAlgorithm)
+	}
+
+	for _, setting := range []struct{ name, policy string }{
+		{"functions", sc.SecretScanning.Functions},
+		{"knowledge_bases", sc.SecretScanning.KnowledgeBases},
+	} {
+		switch setting.policy {
+		case "", "block", "flag", "off":
+		default:
+			return fmt.Errorf("secret_scanning.%s must be one of block, flag or off, got '%s'", setting.name, setting.policy)
+		}
+	}
+
 	return nil
 }
 
 // Validate validates email configuration
 func (ec *EmailConfig) Validate() error {
+	if err := ec.validateProvider(); err != nil {
+		return err
+	}
+
+	profiles := map[string]bool{"default": true}
+	for i, p := range ec.Profiles {
+		if p.Name == "" {
+			return fmt.Errorf("profiles[%d].name is required", i)
+		}
+		if profiles[p.Name] {
+			return fmt.Errorf("profiles[%d].name %q is duplicated or reserved", i, p.Name)
+		}
+		if len(p.Profiles) > 0 {
+			return fmt.Errorf("profile %q cannot define nested profiles", p.Name)
+		}
+		profileCfg := p.EmailConfig
+		if err := profileCfg.validateProvider(); err != nil {
+			return fmt.Errorf("profile %q: %w", p.Name, err)
+		}
+		profiles[p.Name] = true
+	}
+
+	checkProfile := func(field, name string) error {
+		if !profiles[name] {
+			return fmt.Errorf("%s references unknown profile %q", field, name)
+		}
+		return nil
+	}
+	for _, name := range ec.Failover {
+		if err := checkProfile("failover", name); err != nil {
+			return err
+		}
+	}
+	for _, p := range ec.Profiles {
+		for _, name := range p.Failover {
+			if err := checkProfile(fmt.Sprintf("profile %q failover", p.Name), name); err != nil {
+				return err
+			}
+		}
+	}
+	for category, name := range ec.Routing.Categories {
+		if category != "auth" && category != "notification" {
+			return fmt.Errorf("routing.categories: unknown category %q (must be auth or notification)", category)
+		}
+		if err := checkProfile("routing.categories."+category, name); err != nil {
+			return err
+		}
+	}
+	for namespace, name := range ec.Routing.Namespaces {
+		if err := checkProfile("routing.namespaces."+namespace, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateProvider validates the provider settings shared by the top-level email
+// configuration and its profiles
+func (ec *EmailConfig) validateProvider() error {
 	// Validate provider if specified
 	if ec.Provider != "" {
 		validProviders := []string{"smtp", "sendgrid", "mailgun", "ses"}
@@ -1573,6 +2195,10 @@ func (ec *EmailConfig) Validate() error {
 	// Provider-specific settings are validated at runtime when sending emails,
 	// allowing configuration via admin UI after startup
 
+	if ec.MaxPerHour < 0 {
+		return fmt.Errorf("max_per_hour cannot be negative, got: %d", ec.MaxPerHour)
+	}
+
 	return nil
 }
 
@@ -1672,6 +2298,70 @@ func (ac *APIConfig) Validate() error {
 		log.Warn().Msg("default_page_size is set to -1 (no default) - queries without limit parameter will return all rows")
 	}
 
+	for _, schema := range append(append([]string{}, ac.ExposedSchemas...), ac.HiddenSchemas...) {
+		if strings.TrimSpace(schema) == "" {
+			return fmt.Errorf("exposed_schemas and hidden_schemas cannot contain empty schema names")
+		}
+	}
+
+	for _, schema := range ac.ReadOnlySchemas {
+		if strings.TrimSpace(schema) == "" {
+			return fmt.Errorf("read_only_schemas cannot contain empty schema names")
+		}
+	}
+	for _, table := range ac.ReadOnlyTables {
+		if parts := strings.Split(table, "."); len(parts) > 2 || slices.Contains(parts, "") {
+			return fmt.Errorf("read_only_tables entries must be 'schema.table' or 'table', got: %q", table)
+		}
+	}
+
+	if ac.MaxOpenSnapshots < 0 {
+		return fmt.Errorf("max_open_snapshots cannot be negative, got: %d", ac.MaxOpenSnapshots)
+	}
+	if ac.MaxOpenSnapshots > 0 {
+		if ac.SnapshotTTL <= 0 {
+			return fmt.Errorf("snapshot_ttl must be positive, got: %s", ac.SnapshotTTL)
+		}
+		if ac.SnapshotMaxTTL < ac.SnapshotTTL {
+			return fmt.Errorf("snapshot_max_ttl (%s) cannot be less than snapshot_ttl (%s)", ac.SnapshotMaxTTL, ac.SnapshotTTL)
+		}
+	}
+
+	if ac.AggregateCacheMaxEntries < 0 {
+		return fmt.Errorf("aggregate_cache_max_entries cannot be negative, got: %d", ac.AggregateCacheMaxEntries)
+	}
+	if ac.AggregateCacheMaxEntries > 0 && ac.AggregateCacheMaxStale <= 0 {
+		return fmt.Errorf("aggregate_cache_max_stale must be positive, got: %s", ac.AggregateCacheMaxStale)
+	}
+
+	if ac.BulkMaxAffectedRows < 0 {
+		return fmt.Errorf("bulk_max_affected_rows cannot be negative, got: %d", ac.BulkMaxAffectedRows)
+	}
+
+	if ac.StatementCacheSize < 0 {
+		return fmt.Errorf("statement_cache_size cannot be negative, got: %d", ac.StatementCacheSize)
+	}
+
+	if ac.ExportRateLimit < 0 {
+		return fmt.Errorf("export_rate_limit cannot be negative, got: %d", ac.ExportRateLimit)
+	}
+	if ac.ExportRateLimit > 0 && ac.ExportRateWindow <= 0 {
+		return fmt.Errorf("export_rate_window must be positive, got: %s", ac.ExportRateWindow)
+	}
+
+	if ac.UsageFlushInterval < 0 {
+		return fmt.Errorf("usage_flush_interval cannot be negative, got: %s", ac.UsageFlushInterval)
+	}
+	if ac.UsageRetentionDays < 0 {
+		return fmt.Errorf("usage_retention_days cannot be negative, got: %d", ac.UsageRetentionDays)
+	}
+
+	for _, group := range ac.ValidateRequests {
+		if strings.TrimSpace(group) == "" || strings.Contains(group, "/") {
+			return fmt.Errorf("validate_requests entries must be route group names like 'auth' or '*', got: %q", group)
+		}
+	}
+
 	return nil
 }
 
@@ -1744,6 +2434,54 @@ func (jc *JobsConfig) Validate() error {
 	return nil
 }
 
+// Validate validates realtime configuration
+func (rc *RealtimeConfig) Validate() error {
+	prefixes := make(map[string]bool, len(rc.Channels))
+	for i, rule := range rc.Channels {
+		if rule.Prefix == "" {
+			return fmt.Errorf("channels[%d].prefix is required", i)
+		}
+		if prefixes[rule.Prefix] {
+			return fmt.Errorf("channels[%d].prefix %q is used by another rule", i, rule.Prefix)
+		}
+		prefixes[rule.Prefix] = true
+
+		for _, action := range rule.Actions {
+			if action != "subscribe" && action != "broadcast" && action != "presence" {
+				return fmt.Errorf("channels[%d].actions must be subscribe, broadcast or presence, got: %s", i, action)
+			}
+		}
+	}
+
+	if rc.Replication.Enabled {
+		if !isReplicationName(rc.Replication.Publication, 63) {
+			return fmt.Errorf("replication.publication must be a lower case identifier, got: %q", rc.Replication.Publication)
+		}
+		// The slot prefix is followed by _ and a 12 character suffix
+		if !isReplicationName(rc.Replication.SlotPrefix, 50) {
+			return fmt.Errorf("replication.slot_prefix must be a lower case identifier of at most 50 characters, got: %q", rc.Replication.SlotPrefix)
+		}
+		if rc.Replication.StatusInterval < time.Second {
+			return fmt.Errorf("replication.status_interval must be at least 1s, got: %s", rc.Replication.StatusInterval)
+		}
+	}
+	return nil
+}
+
+// isReplicationName reports whether name is usable as a publication or replication slot
+// name: lower case letters, digits and underscores, not starting with a digit
+func isReplicationName(name string, maxLen int) bool {
+	if name == "" || len(name) > maxLen || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
 // Validate validates tracing configuration
 func (tc *TracingConfig) Validate() error {
 	if !tc.Enabled {
@@ -1810,6 +2548,31 @@ func (sc *ScalingConfig) Validate() error {
 		return fmt.Errorf("redis_url is required when scaling backend is 'redis'")
 	}
 
+	// Validate per-component overrides
+	overrides := []struct {
+		name    string
+		backend string
+		valid   []string
+	}{
+		{"rate_limit_backend", sc.RateLimitBackend, validBackends},
+		{"cache_backend", sc.CacheBackend, validBackends},
+		{"queue_backend", sc.QueueBackend, []string{"local", "postgres", "redis", "nats"}},
+	}
+	for _, o := range overrides {
+		if o.backend == "" {
+			continue
+		}
+		if !slices.Contains(o.valid, o.backend) {
+			return fmt.Errorf("invalid scaling %s: %s (must be one of: %v)", o.name, o.backend, o.valid)
+		}
+		if o.backend == "redis" && sc.RedisURL == "" {
+			return fmt.Errorf("redis_url is required when scaling %s is 'redis'", o.name)
+		}
+		if o.backend == "nats" && sc.NATSURL == "" {
+			return fmt.Errorf("nats_url is required when scaling %s is 'nats'", o.name)
+		}
+	}
+
 	// Warn about conflicting settings
 	if sc.WorkerOnly && !sc.DisableScheduler {
 		log.Warn().Msg("Worker-only mode is enabled but scheduler is not disabled - consider setting disable_scheduler=true for worker containers")
@@ -1852,6 +2615,26 @@ func (ac *AIConfig) Validate() error {
 		return fmt.Errorf("max_conversation_turns must be positive, got: %d", ac.MaxConversationTurns)
 	}
 
+	// Validate embeddings API settings (0 = use defaults / unlimited)
+	if ac.EmbeddingBatchSize < 0 {
+		return fmt.Errorf("embedding_batch_size cannot be negative, got: %d", ac.EmbeddingBatchSize)
+	}
+	if ac.EmbeddingBatchMaxTokens < 0 {
+		return fmt.Errorf("embedding_batch_max_tokens cannot be negative, got: %d", ac.EmbeddingBatchMaxTokens)
+	}
+	if ac.EmbeddingConcurrency < 0 || ac.EmbeddingConcurrency > 64 {
+		return fmt.Errorf("embedding_concurrency must be between 0 and 64, got: %d", ac.EmbeddingConcurrency)
+	}
+	if ac.EmbeddingMaxRetries < 0 || ac.EmbeddingMaxRetries > 10 {
+		return fmt.Errorf("embedding_max_retries must be between 0 and 10, got: %d", ac.EmbeddingMaxRetries)
+	}
+	if ac.EmbeddingMaxInputs < 0 {
+		return fmt.Errorf("embedding_max_inputs cannot be negative, got: %d", ac.EmbeddingMaxInputs)
+	}
+	if ac.EmbeddingDailyTokenQuota < 0 {
+		return fmt.Errorf("embedding_daily_token_quota cannot be negative, got: %d", ac.EmbeddingDailyTokenQuota)
+	}
+
 	// Warn if max rows is very high
 	if ac.MaxRowsPerQuery > 10000 {
 		log.Warn().Int("max_rows_per_query", ac.MaxRowsPerQuery).Msg("max_rows_per_query is over 10000 - large result sets may impact performance")
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// DataExportConfig contains settings for self-serve data exports. Users can request an archive
+// of everything Fluxbase holds about them (a data subject access request), which is compiled in
+// the background and kept in a storage bucket until it expires.
+type DataExportConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`   // Let users export their own data (default: true)
+	Bucket    string        `mapstructure:"bucket"`    // Storage bucket that holds the archives (default: "data-exports")
+	Retention time.Duration `mapstructure:"retention"` // How long an archive can be downloaded (default: 168h)
+}
+
+// Validate validates data export configuration
+func (dc *DataExportConfig) Validate() error {
+	if !dc.Enabled {
+		return nil // No validation needed if disabled
+	}
+
+	if dc.Bucket == "" {
+		return fmt.Errorf("data_export bucket is required")
+	}
+
+	if dc.Retention < time.Hour {
+		return fmt.Errorf("data_export retention must be at least 1h, got: %s", dc.Retention)
+	}
+
+	return nil
+}
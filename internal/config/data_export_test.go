@@ -0,0 +1,35 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataExportConfig_Validate(t *testing.T) {
+	t.Run("disabled config needs no validation", func(t *testing.T) {
+		cfg := DataExportConfig{Enabled: false}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("valid config passes", func(t *testing.T) {
+		cfg := DataExportConfig{Enabled: true, Bucket: "data-exports", Retention: 168 * time.Hour}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("requires a bucket", func(t *testing.T) {
+		cfg := DataExportConfig{Enabled: true, Retention: 168 * time.Hour}
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "bucket is required")
+	})
+
+	t.Run("rejects retention under an hour", func(t *testing.T) {
+		cfg := DataExportConfig{Enabled: true, Bucket: "data-exports", Retention: time.Minute}
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "at least 1h")
+	})
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// EgressConfig contains the outbound request policy for edge functions, jobs and AI providers.
+// When enabled, requests to private networks are blocked and destinations can be restricted to an allowlist.
+type EgressConfig struct {
+	Enabled             bool     `mapstructure:"enabled"`               // Enforce the egress policy (default: false)
+	AllowedHosts        []string `mapstructure:"allowed_hosts"`         // Allowed destination hosts, "*.example.com" matches subdomains (empty = any public host)
+	AllowedPrivateHosts []string `mapstructure:"allowed_private_hosts"` // Hosts allowed to resolve to private addresses, e.g. a local Ollama server
+	ProxyURL            string   `mapstructure:"proxy_url"`             // Optional upstream HTTP proxy for all outbound requests
+}
+
+// Validate validates egress configuration
+func (ec *EgressConfig) Validate() error {
+	if !ec.Enabled {
+		return nil // No validation needed if disabled
+	}
+
+	for _, host := range append(append([]string{}, ec.AllowedHosts...), ec.AllowedPrivateHosts...) {
+		if err := validateEgressHost(host); err != nil {
+			return err
+		}
+	}
+
+	if ec.ProxyURL != "" {
+		u, err := url.Parse(ec.ProxyURL)
+		if err != nil {
+			return fmt.Errorf("egress proxy_url is invalid: %w", err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("egress proxy_url must be an http or https URL with a host, got: %s", ec.ProxyURL)
+		}
+	}
+
+	return nil
+}
+
+// validateEgressHost checks that an allowlist entry is a bare host name, IP or "*." wildcard
+func validateEgressHost(host string) error {
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+	name := strings.TrimPrefix(host, "*.")
+	if name == "" || strings.ContainsAny(name, "/:*@ ") {
+		return fmt.Errorf("egress host %q must be a host name, IP address or *.domain wildcard", host)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEgressConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  EgressConfig
+		wantErr string
+	}{
+		{
+			name:   "disabled skips validation",
+			config: EgressConfig{Enabled: false, ProxyURL: "::bad"},
+		},
+		{
+			name: "valid config",
+			config: EgressConfig{
+				Enabled:             true,
+				AllowedHosts:        []string{"api.openai.com", "*.example.com", "203.0.113.10", "2001:db8::1"},
+				AllowedPrivateHosts: []string{"ollama"},
+				ProxyURL:            "http://proxy.internal:3128",
+			},
+		},
+		{
+			name:    "host with scheme",
+			config:  EgressConfig{Enabled: true, AllowedHosts: []string{"https://api.openai.com"}},
+			wantErr: "must be a host name",
+		},
+		{
+			name:    "host with port",
+			config:  EgressConfig{Enabled: true, AllowedPrivateHosts: []string{"localhost:11434"}},
+			wantErr: "must be a host name",
+		},
+		{
+			name:    "wildcard in the middle",
+			config:  EgressConfig{Enabled: true, AllowedHosts: []string{"api.*.com"}},
+			wantErr: "must be a host name",
+		},
+		{
+			name:    "empty host",
+			config:  EgressConfig{Enabled: true, AllowedHosts: []string{""}},
+			wantErr: "must be a host name",
+		},
+		{
+			name:    "proxy without scheme",
+			config:  EgressConfig{Enabled: true, ProxyURL: "proxy.internal:3128"},
+			wantErr: "proxy_url",
+		},
+		{
+			name:    "socks proxy",
+			config:  EgressConfig{Enabled: true, ProxyURL: "socks5://proxy.internal:1080"},
+			wantErr: "proxy_url",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
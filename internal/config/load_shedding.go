@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// LoadSheddingConfig contains settings for request prioritization under load.
+// When the database pool is saturated or requests slow down, low-priority traffic
+// (anonymous reads) is rejected with 503 so auth, dashboard and webhook paths keep working.
+type LoadSheddingConfig struct {
+	Enabled          bool          `mapstructure:"enabled"`           // Enable load shedding (default: false)
+	PoolSaturation   float64       `mapstructure:"pool_saturation"`   // Fraction of database connections in use that counts as overload (default: 0.9)
+	LatencyThreshold time.Duration `mapstructure:"latency_threshold"` // Average request latency that counts as overload, 0 disables the check (default: 1s)
+	Cooldown         time.Duration `mapstructure:"cooldown"`          // Keep shedding this long after load drops, also sent as Retry-After (default: 10s)
+	ProtectedPaths   []string      `mapstructure:"protected_paths"`   // Additional path prefixes that are never shed
+}
+
+// Validate validates load shedding configuration
+func (lc *LoadSheddingConfig) Validate() error {
+	if !lc.Enabled {
+		return nil // No validation needed if disabled
+	}
+
+	if lc.PoolSaturation <= 0 || lc.PoolSaturation > 1 {
+		return fmt.Errorf("load_shedding pool_saturation must be greater than 0 and at most 1, got: %g", lc.PoolSaturation)
+	}
+
+	if lc.LatencyThreshold < 0 {
+		return fmt.Errorf("load_shedding latency_threshold cannot be negative, got: %s", lc.LatencyThreshold)
+	}
+
+	if lc.Cooldown < time.Second {
+		return fmt.Errorf("load_shedding cooldown must be at least 1s, got: %s", lc.Cooldown)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSheddingConfig_Validate(t *testing.T) {
+	valid := func() LoadSheddingConfig {
+		return LoadSheddingConfig{
+			Enabled:          true,
+			PoolSaturation:   0.9,
+			LatencyThreshold: time.Second,
+			Cooldown:         10 * time.Second,
+		}
+	}
+
+	t.Run("disabled config needs no validation", func(t *testing.T) {
+		cfg := LoadSheddingConfig{Enabled: false}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("valid config passes", func(t *testing.T) {
+		cfg := valid()
+		cfg.LatencyThreshold = 0
+		cfg.ProtectedPaths = []string{"/api/v1/functions/checkout"}
+		require.NoError(t, cfg.Validate())
+	})
+
+	tests := []struct {
+		name   string
+		mutate func(*LoadSheddingConfig)
+		errMsg string
+	}{
+		{"pool saturation zero", func(c *LoadSheddingConfig) { c.PoolSaturation = 0 }, "pool_saturation"},
+		{"pool saturation above 1", func(c *LoadSheddingConfig) { c.PoolSaturation = 1.5 }, "pool_saturation"},
+		{"negative latency threshold", func(c *LoadSheddingConfig) { c.LatencyThreshold = -time.Second }, "latency_threshold"},
+		{"cooldown too short", func(c *LoadSheddingConfig) { c.Cooldown = 100 * time.Millisecond }, "cooldown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.mutate(&cfg)
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
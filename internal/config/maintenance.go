@@ -0,0 +1,31 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaintenanceConfig contains settings for table maintenance (VACUUM / ANALYZE). Admins can
+// run maintenance manually at any time; the scheduler runs the configured off-peak schedules.
+type MaintenanceConfig struct {
+	Enabled              bool          `mapstructure:"enabled"`                // Run maintenance schedules and log partition management (default: true)
+	RunTimeout           time.Duration `mapstructure:"run_timeout"`            // Maximum duration of a single run (default: 2h)
+	HistoryRetentionDays int           `mapstructure:"history_retention_days"` // Days of run history to keep (default: 90)
+}
+
+// Validate validates maintenance configuration
+func (mc *MaintenanceConfig) Validate() error {
+	if !mc.Enabled {
+		return nil // No validation needed if disabled
+	}
+
+	if mc.RunTimeout < time.Minute {
+		return fmt.Errorf("maintenance run_timeout must be at least 1m, got: %s", mc.RunTimeout)
+	}
+
+	if mc.HistoryRetentionDays < 1 {
+		return fmt.Errorf("maintenance history_retention_days must be at least 1, got: %d", mc.HistoryRetentionDays)
+	}
+
+	return nil
+}
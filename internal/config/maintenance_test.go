@@ -0,0 +1,35 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceConfig_Validate(t *testing.T) {
+	t.Run("valid config passes", func(t *testing.T) {
+		cfg := MaintenanceConfig{Enabled: true, RunTimeout: 2 * time.Hour, HistoryRetentionDays: 90}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("disabled config needs no validation", func(t *testing.T) {
+		cfg := MaintenanceConfig{Enabled: false}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("rejects short run timeout", func(t *testing.T) {
+		cfg := MaintenanceConfig{Enabled: true, RunTimeout: 10 * time.Second, HistoryRetentionDays: 90}
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "run_timeout")
+	})
+
+	t.Run("rejects empty history retention", func(t *testing.T) {
+		cfg := MaintenanceConfig{Enabled: true, RunTimeout: time.Hour}
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "history_retention_days")
+	})
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MeteringConfig contains billing/metering export settings.
+// Billable events are exported per period as JSON lines to a storage bucket and/or a webhook.
+type MeteringConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`         // Enable periodic metering exports (default: false)
+	Interval       time.Duration `mapstructure:"interval"`        // Length of each export period, whole hours (default: 1h)
+	Bucket         string        `mapstructure:"bucket"`          // Storage bucket for JSON lines files (empty = no bucket export)
+	PathPrefix     string        `mapstructure:"path_prefix"`     // Object key prefix inside the bucket (default: "metering")
+	WebhookURL     string        `mapstructure:"webhook_url"`     // URL receiving each export as a POST (empty = no webhook export)
+	WebhookSecret  string        `mapstructure:"webhook_secret"`  // HMAC secret for the X-Fluxbase-Signature header
+	WebhookTimeout time.Duration `mapstructure:"webhook_timeout"` // Timeout for webhook deliveries (default: 30s)
+	MaxCatchUp     int           `mapstructure:"max_catch_up"`    // Max missed periods exported per run after downtime (default: 24)
+}
+
+// Validate validates metering configuration
+func (mc *MeteringConfig) Validate() error {
+	if !mc.Enabled {
+		return nil // No validation needed if disabled
+	}
+
+	if mc.Bucket == "" && mc.WebhookURL == "" {
+		return fmt.Errorf("metering requires a bucket or webhook_url destination")
+	}
+
+	// API request counts come from hourly rollups, so periods must cover whole hours
+	if mc.Interval < time.Hour || mc.Interval%time.Hour != 0 {
+		return fmt.Errorf("metering interval must be a whole number of hours, got: %s", mc.Interval)
+	}
+
+	if mc.WebhookURL != "" && !strings.HasPrefix(mc.WebhookURL, "http://") && !strings.HasPrefix(mc.WebhookURL, "https://") {
+		return fmt.Errorf("metering webhook_url must be an http(s) URL, got: %s", mc.WebhookURL)
+	}
+
+	if mc.WebhookTimeout <= 0 {
+		return fmt.Errorf("metering webhook_timeout must be positive, got: %s", mc.WebhookTimeout)
+	}
+
+	if mc.MaxCatchUp < 1 {
+		return fmt.Errorf("metering max_catch_up must be at least 1, got: %d", mc.MaxCatchUp)
+	}
+
+	return nil
+}
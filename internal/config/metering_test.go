@@ -0,0 +1,62 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeteringConfig_Validate(t *testing.T) {
+	valid := func() MeteringConfig {
+		return MeteringConfig{
+			Enabled:        true,
+			Interval:       time.Hour,
+			Bucket:         "billing",
+			PathPrefix:     "metering",
+			WebhookTimeout: 30 * time.Second,
+			MaxCatchUp:     24,
+		}
+	}
+
+	t.Run("disabled config needs no validation", func(t *testing.T) {
+		cfg := MeteringConfig{Enabled: false}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("valid bucket config passes", func(t *testing.T) {
+		cfg := valid()
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("valid webhook-only daily config passes", func(t *testing.T) {
+		cfg := valid()
+		cfg.Bucket = ""
+		cfg.WebhookURL = "https://billing.example.com/ingest"
+		cfg.Interval = 24 * time.Hour
+		require.NoError(t, cfg.Validate())
+	})
+
+	tests := []struct {
+		name    string
+		modify  func(*MeteringConfig)
+		wantErr string
+	}{
+		{"rejects missing destination", func(c *MeteringConfig) { c.Bucket = "" }, "bucket or webhook_url"},
+		{"rejects sub-hour interval", func(c *MeteringConfig) { c.Interval = 30 * time.Minute }, "whole number of hours"},
+		{"rejects fractional hours", func(c *MeteringConfig) { c.Interval = 90 * time.Minute }, "whole number of hours"},
+		{"rejects non-http webhook", func(c *MeteringConfig) { c.WebhookURL = "ftp://billing" }, "http(s) URL"},
+		{"rejects zero webhook timeout", func(c *MeteringConfig) { c.WebhookTimeout = 0 }, "webhook_timeout"},
+		{"rejects zero max catch-up", func(c *MeteringConfig) { c.MaxCatchUp = 0 }, "max_catch_up"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// NotificationsConfig contains settings for the dashboard notification center.
+// A background check flags expiring credentials and configuration drift for admins.
+type NotificationsConfig struct {
+	Enabled               bool          `mapstructure:"enabled"`                 // Enable background configuration checks (default: true)
+	CheckInterval         time.Duration `mapstructure:"check_interval"`          // How often checks run (default: 1h)
+	ExpiryWarningDays     int           `mapstructure:"expiry_warning_days"`     // Warn this many days before certificates and secrets expire (default: 30)
+	StorageQuotaBytes     int64         `mapstructure:"storage_quota_bytes"`     // Total storage quota in bytes (0 = no quota check)
+	StorageWarningPercent int           `mapstructure:"storage_warning_percent"` // Warn when storage usage reaches this percentage of the quota (default: 80)
+	EmailFailureThreshold int           `mapstructure:"email_failure_threshold"` // Consecutive email delivery failures before notifying (default: 3)
+}
+
+// Validate validates notifications configuration
+func (nc *NotificationsConfig) Validate() error {
+	if !nc.Enabled {
+		return nil // No validation needed if disabled
+	}
+
+	if nc.CheckInterval < time.Minute {
+		return fmt.Errorf("notifications check_interval must be at least 1m, got: %s", nc.CheckInterval)
+	}
+
+	if nc.ExpiryWarningDays < 1 {
+		return fmt.Errorf("notifications expiry_warning_days must be at least 1, got: %d", nc.ExpiryWarningDays)
+	}
+
+	if nc.StorageQuotaBytes < 0 {
+		return fmt.Errorf("notifications storage_quota_bytes cannot be negative, got: %d", nc.StorageQuotaBytes)
+	}
+
+	if nc.StorageWarningPercent < 1 || nc.StorageWarningPercent > 100 {
+		return fmt.Errorf("notifications storage_warning_percent must be between 1 and 100, got: %d", nc.StorageWarningPercent)
+	}
+
+	if nc.EmailFailureThreshold < 1 {
+		return fmt.Errorf("notifications email_failure_threshold must be at least 1, got: %d", nc.EmailFailureThreshold)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationsConfig_Validate(t *testing.T) {
+	valid := func() NotificationsConfig {
+		return NotificationsConfig{
+			Enabled:               true,
+			CheckInterval:         time.Hour,
+			ExpiryWarningDays:     30,
+			StorageWarningPercent: 80,
+			EmailFailureThreshold: 3,
+		}
+	}
+
+	t.Run("disabled config needs no validation", func(t *testing.T) {
+		cfg := NotificationsConfig{Enabled: false}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("valid config passes", func(t *testing.T) {
+		cfg := valid()
+		cfg.StorageQuotaBytes = 100 << 30
+		require.NoError(t, cfg.Validate())
+	})
+
+	tests := []struct {
+		name   string
+		mutate func(*NotificationsConfig)
+		errMsg string
+	}{
+		{"check interval too short", func(c *NotificationsConfig) { c.CheckInterval = time.Second }, "check_interval"},
+		{"no expiry warning window", func(c *NotificationsConfig) { c.ExpiryWarningDays = 0 }, "expiry_warning_days"},
+		{"negative storage quota", func(c *NotificationsConfig) { c.StorageQuotaBytes = -1 }, "storage_quota_bytes"},
+		{"storage warning percent zero", func(c *NotificationsConfig) { c.StorageWarningPercent = 0 }, "storage_warning_percent"},
+		{"storage warning percent above 100", func(c *NotificationsConfig) { c.StorageWarningPercent = 101 }, "storage_warning_percent"},
+		{"no email failure threshold", func(c *NotificationsConfig) { c.EmailFailureThreshold = 0 }, "email_failure_threshold"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.mutate(&cfg)
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
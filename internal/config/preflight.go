@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// PreflightConfig contains the startup preflight checks settings.
+// Preflight checks verify the environment on boot and stop startup on failures.
+type PreflightConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`        // Run preflight checks on boot (default: true)
+	Skip         []string      `mapstructure:"skip"`           // Names of checks to skip, e.g. "smtp"
+	CheckTimeout time.Duration `mapstructure:"check_timeout"`  // Timeout of each check (default: 5s)
+	MaxClockSkew time.Duration `mapstructure:"max_clock_skew"` // Clock skew from the database that fails startup (default: 30s)
+}
+
+// Validate validates preflight configuration
+func (pc *PreflightConfig) Validate() error {
+	if !pc.Enabled {
+		return nil // No validation needed if disabled
+	}
+
+	if pc.CheckTimeout <= 0 {
+		return fmt.Errorf("preflight check_timeout must be positive, got: %s", pc.CheckTimeout)
+	}
+
+	if pc.MaxClockSkew < time.Second {
+		return fmt.Errorf("preflight max_clock_skew must be at least 1s, got: %s", pc.MaxClockSkew)
+	}
+
+	return nil
+}
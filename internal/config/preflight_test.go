@@ -0,0 +1,47 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreflightConfig_Validate(t *testing.T) {
+	valid := func() PreflightConfig {
+		return PreflightConfig{
+			Enabled:      true,
+			CheckTimeout: 5 * time.Second,
+			MaxClockSkew: 30 * time.Second,
+		}
+	}
+
+	t.Run("disabled config needs no validation", func(t *testing.T) {
+		cfg := PreflightConfig{Enabled: false}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("valid config passes", func(t *testing.T) {
+		cfg := valid()
+		require.NoError(t, cfg.Validate())
+	})
+
+	tests := []struct {
+		name    string
+		modify  func(*PreflightConfig)
+		wantErr string
+	}{
+		{"rejects zero check timeout", func(c *PreflightConfig) { c.CheckTimeout = 0 }, "check_timeout"},
+		{"rejects sub-second clock skew", func(c *PreflightConfig) { c.MaxClockSkew = 500 * time.Millisecond }, "max_clock_skew"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
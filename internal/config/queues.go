@@ -0,0 +1,28 @@
+package config
+
+import "fmt"
+
+// QueuesConfig contains settings for the message queue API used by functions and external
+// workers to exchange messages
+type QueuesConfig struct {
+	Enabled         bool `mapstructure:"enabled"`           // Serve the queue API (default: true)
+	MaxMessageBytes int  `mapstructure:"max_message_bytes"` // Maximum size of a message body (default: 262144)
+	MaxBatchSize    int  `mapstructure:"max_batch_size"`    // Maximum messages sent or read per request (default: 100)
+}
+
+// Validate validates queues configuration
+func (qc *QueuesConfig) Validate() error {
+	if !qc.Enabled {
+		return nil // No validation needed if disabled
+	}
+
+	if qc.MaxMessageBytes < 1024 {
+		return fmt.Errorf("queues max_message_bytes must be at least 1024, got: %d", qc.MaxMessageBytes)
+	}
+
+	if qc.MaxBatchSize < 1 || qc.MaxBatchSize > 1000 {
+		return fmt.Errorf("queues max_batch_size must be between 1 and 1000, got: %d", qc.MaxBatchSize)
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueuesConfig_Validate(t *testing.T) {
+	t.Run("valid config passes", func(t *testing.T) {
+		cfg := QueuesConfig{Enabled: true, MaxMessageBytes: 262144, MaxBatchSize: 100}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("disabled config needs no validation", func(t *testing.T) {
+		cfg := QueuesConfig{Enabled: false}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("rejects tiny message limit", func(t *testing.T) {
+		cfg := QueuesConfig{Enabled: true, MaxMessageBytes: 10, MaxBatchSize: 100}
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "max_message_bytes")
+	})
+
+	t.Run("rejects batch size out of range", func(t *testing.T) {
+		for _, size := range []int{0, 5000} {
+			cfg := QueuesConfig{Enabled: true, MaxMessageBytes: 262144, MaxBatchSize: size}
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "max_batch_size")
+		}
+	})
+}
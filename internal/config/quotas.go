@@ -0,0 +1,31 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// QuotasConfig contains usage quota enforcement settings.
+// Quotas themselves are attached to users and organizations through the admin API.
+type QuotasConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`        // Enforce usage quotas and count usage (default: false)
+	FlushInterval time.Duration `mapstructure:"flush_interval"` // How often counted API calls are written to the database (default: 10s)
+	CacheTTL      time.Duration `mapstructure:"cache_ttl"`      // How long quotas and usage are cached before being re-read (default: 1m)
+}
+
+// Validate validates quota configuration
+func (qc *QuotasConfig) Validate() error {
+	if !qc.Enabled {
+		return nil // No validation needed if disabled
+	}
+
+	if qc.FlushInterval <= 0 {
+		return fmt.Errorf("quotas flush_interval must be positive, got: %s", qc.FlushInterval)
+	}
+
+	if qc.CacheTTL <= 0 {
+		return fmt.Errorf("quotas cache_ttl must be positive, got: %s", qc.CacheTTL)
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotasConfig_Validate(t *testing.T) {
+	t.Run("disabled config needs no validation", func(t *testing.T) {
+		cfg := QuotasConfig{Enabled: false}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("valid config passes", func(t *testing.T) {
+		cfg := QuotasConfig{Enabled: true, FlushInterval: 10 * time.Second, CacheTTL: time.Minute}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("flush interval must be positive", func(t *testing.T) {
+		cfg := QuotasConfig{Enabled: true, CacheTTL: time.Minute}
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "flush_interval")
+	})
+
+	t.Run("cache TTL must be positive", func(t *testing.T) {
+		cfg := QuotasConfig{Enabled: true, FlushInterval: 10 * time.Second, CacheTTL: -time.Second}
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cache_ttl")
+	})
+}
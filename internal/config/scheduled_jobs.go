@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// ScheduledJobsConfig contains settings for scheduled jobs, which run edge functions and SQL
+// snippets on cron schedules. Admins can also run a job manually at any time.
+type ScheduledJobsConfig struct {
+	Enabled              bool          `mapstructure:"enabled"`                // Run scheduled jobs (default: true)
+	RunTimeout           time.Duration `mapstructure:"run_timeout"`            // Maximum duration of a single run (default: 10m)
+	MaxConcurrent        int           `mapstructure:"max_concurrent"`         // Maximum number of scheduled runs at once (default: 5)
+	MaxOutputBytes       int           `mapstructure:"max_output_bytes"`       // Captured output kept per run (default: 65536)
+	HistoryRetentionDays int           `mapstructure:"history_retention_days"` // Days of run history to keep (default: 30)
+}
+
+// Validate validates scheduled jobs configuration
+func (sc *ScheduledJobsConfig) Validate() error {
+	if !sc.Enabled {
+		return nil // No validation needed if disabled
+	}
+
+	if sc.RunTimeout < time.Second {
+		return fmt.Errorf("scheduled_jobs run_timeout must be at least 1s, got: %s", sc.RunTimeout)
+	}
+
+	if sc.MaxConcurrent < 1 {
+		return fmt.Errorf("scheduled_jobs max_concurrent must be at least 1, got: %d", sc.MaxConcurrent)
+	}
+
+	if sc.MaxOutputBytes < 1024 {
+		return fmt.Errorf("scheduled_jobs max_output_bytes must be at least 1024, got: %d", sc.MaxOutputBytes)
+	}
+
+	if sc.HistoryRetentionDays < 1 {
+		return fmt.Errorf("scheduled_jobs history_retention_days must be at least 1, got: %d", sc.HistoryRetentionDays)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledJobsConfig_Validate(t *testing.T) {
+	valid := func() ScheduledJobsConfig {
+		return ScheduledJobsConfig{
+			Enabled:              true,
+			RunTimeout:           10 * time.Minute,
+			MaxConcurrent:        5,
+			MaxOutputBytes:       65536,
+			HistoryRetentionDays: 30,
+		}
+	}
+
+	t.Run("valid config passes", func(t *testing.T) {
+		cfg := valid()
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("disabled config needs no validation", func(t *testing.T) {
+		cfg := ScheduledJobsConfig{Enabled: false}
+		require.NoError(t, cfg.Validate())
+	})
+
+	tests := []struct {
+		name   string
+		modify func(*ScheduledJobsConfig)
+		field  string
+	}{
+		{"rejects short run timeout", func(c *ScheduledJobsConfig) { c.RunTimeout = 0 }, "run_timeout"},
+		{"rejects zero concurrency", func(c *ScheduledJobsConfig) { c.MaxConcurrent = 0 }, "max_concurrent"},
+		{"rejects tiny output limit", func(c *ScheduledJobsConfig) { c.MaxOutputBytes = 100 }, "max_output_bytes"},
+		{"rejects empty history retention", func(c *ScheduledJobsConfig) { c.HistoryRetentionDays = 0 }, "history_retention_days"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.field)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package config
+
+import "fmt"
+
+// TableStatsConfig contains settings for the table size dashboard. A background collector
+// snapshots the row estimate and size of every user table once a day to build growth trends.
+type TableStatsConfig struct {
+	Enabled       bool `mapstructure:"enabled"`        // Enable daily table size snapshots (default: true)
+	RetentionDays int  `mapstructure:"retention_days"` // Days of snapshots to keep (default: 90)
+}
+
+// Validate validates table stats configuration
+func (tc *TableStatsConfig) Validate() error {
+	if !tc.Enabled {
+		return nil // No validation needed if disabled
+	}
+
+	if tc.RetentionDays < 2 {
+		return fmt.Errorf("table_stats retention_days must be at least 2 to compute trends, got: %d", tc.RetentionDays)
+	}
+
+	return nil
+}
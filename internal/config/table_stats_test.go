@@ -0,0 +1,27 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableStatsConfig_Validate(t *testing.T) {
+	t.Run("disabled config needs no validation", func(t *testing.T) {
+		cfg := TableStatsConfig{Enabled: false}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("valid config passes", func(t *testing.T) {
+		cfg := TableStatsConfig{Enabled: true, RetentionDays: 90}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("rejects retention too short for trends", func(t *testing.T) {
+		cfg := TableStatsConfig{Enabled: true, RetentionDays: 1}
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "at least 2")
+	})
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// TableSyncConfig contains instance-to-instance table sync settings.
+// Selected tables are pushed one way to another Fluxbase instance, which must accept ingest.
+type TableSyncConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`         // Push changes of selected tables to sync targets (default: false)
+	AcceptIngest   bool          `mapstructure:"accept_ingest"`   // Accept rows pushed by other instances (default: false)
+	Interval       time.Duration `mapstructure:"interval"`        // How often changes are captured and sent (default: 30s)
+	BatchSize      int           `mapstructure:"batch_size"`      // Max rows sent per request (default: 500)
+	RequestTimeout time.Duration `mapstructure:"request_timeout"` // Timeout for each request to a target (default: 30s)
+	CaptureDelay   time.Duration `mapstructure:"capture_delay"`   // Changes younger than this wait for the next run, so in-flight transactions commit first (default: 5s)
+}
+
+// Validate validates table sync configuration
+func (tc *TableSyncConfig) Validate() error {
+	if !tc.Enabled {
+		return nil // No validation needed if disabled
+	}
+
+	if tc.Interval < time.Second {
+		return fmt.Errorf("table_sync interval must be at least 1s, got: %s", tc.Interval)
+	}
+
+	if tc.BatchSize < 1 || tc.BatchSize > 10000 {
+		return fmt.Errorf("table_sync batch_size must be between 1 and 10000, got: %d", tc.BatchSize)
+	}
+
+	if tc.RequestTimeout <= 0 {
+		return fmt.Errorf("table_sync request_timeout must be positive, got: %s", tc.RequestTimeout)
+	}
+
+	if tc.CaptureDelay < 0 {
+		return fmt.Errorf("table_sync capture_delay must not be negative, got: %s", tc.CaptureDelay)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableSyncConfig_Validate(t *testing.T) {
+	valid := func() TableSyncConfig {
+		return TableSyncConfig{
+			Enabled:        true,
+			Interval:       30 * time.Second,
+			BatchSize:      500,
+			RequestTimeout: 30 * time.Second,
+			CaptureDelay:   5 * time.Second,
+		}
+	}
+
+	t.Run("disabled config needs no validation", func(t *testing.T) {
+		cfg := TableSyncConfig{Enabled: false, AcceptIngest: true}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("valid config passes", func(t *testing.T) {
+		cfg := valid()
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("zero capture delay passes", func(t *testing.T) {
+		cfg := valid()
+		cfg.CaptureDelay = 0
+		require.NoError(t, cfg.Validate())
+	})
+
+	tests := []struct {
+		name    string
+		modify  func(*TableSyncConfig)
+		wantErr string
+	}{
+		{"rejects sub-second interval", func(c *TableSyncConfig) { c.Interval = 500 * time.Millisecond }, "interval"},
+		{"rejects zero batch size", func(c *TableSyncConfig) { c.BatchSize = 0 }, "batch_size"},
+		{"rejects huge batch size", func(c *TableSyncConfig) { c.BatchSize = 50000 }, "batch_size"},
+		{"rejects zero request timeout", func(c *TableSyncConfig) { c.RequestTimeout = 0 }, "request_timeout"},
+		{"rejects negative capture delay", func(c *TableSyncConfig) { c.CaptureDelay = -time.Second }, "capture_delay"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
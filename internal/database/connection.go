@@ -50,6 +50,7 @@ type Connection struct {
 	config    *config.DatabaseConfig
 	inspector *SchemaInspector
 	metrics   *observability.Metrics
+	replicas  *ReplicaSet
 }
 
 // SetMetrics sets the metrics instance for recording database metrics
@@ -126,7 +127,62 @@ func ExtractDDLMetadata(sql string) string {
 // The connection pool uses the runtime user, while migrations use the admin user
 func NewConnection(cfg config.DatabaseConfig) (*Connection, error) {
 	// Use runtime connection string for the connection pool
-	poolConfig, err := pgxpool.ParseConfig(cfg.RuntimeConnectionString())
+	poolConfig, err := newPoolConfig(cfg.RuntimeConnectionString(), &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create connection pool
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create connection pool: %w", err)
+	}
+
+	// Test the connection
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("unable to ping database: %w", err)
+	}
+
+	conn := &Connection{
+		pool:   pool,
+		config: &cfg,
+	}
+
+	// Initialize schema inspector
+	conn.inspector = NewSchemaInspector(conn)
+
+	// Route read-only queries to read replicas, if any
+	if len(cfg.ReadReplicas) > 0 {
+		replicas, err := newReplicaSet(&cfg)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+		replicas.start()
+		conn.replicas = replicas
+
+		log.Info().
+			Int("replicas", len(cfg.ReadReplicas)).
+			Dur("max_lag", cfg.ReplicaMaxLag).
+			Msg("Read replica routing enabled")
+	}
+
+	log.Info().
+		Str("database", cfg.Database).
+		Str("user", cfg.User).
+		Msg("Database connection established")
+
+	return conn, nil
+}
+
+// newPoolConfig parses a connection string into a pool configuration with the pool settings
+// of cfg, the query tracer and the custom type registrations
+func newPoolConfig(connString string, cfg *config.DatabaseConfig) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(connString)
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse connection string: %w", err)
 	}
@@ -143,6 +199,7 @@ func NewConnection(cfg config.DatabaseConfig) (*Connection, error) {
 	// (e.g., after schema changes or extension creation like pgvector).
 	// The tradeoff is slightly higher overhead per query, but more robust connections.
 	poolConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeDescribeExec
+	poolConfig.ConnConfig.Tracer = queryTracer{}
 
 	// Register custom types for PostgreSQL-specific types that pgx doesn't handle by default
 	// This allows scanning tsvector, tsquery, and other types into interface{}
@@ -187,35 +244,7 @@ func NewConnection(cfg config.DatabaseConfig) (*Connection, error) {
 		return nil
 	}
 
-	// Create connection pool
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
-	if err != nil {
-		return nil, fmt.Errorf("unable to create connection pool: %w", err)
-	}
-
-	// Test the connection
-	if err := pool.Ping(ctx); err != nil {
-		pool.Close()
-		return nil, fmt.Errorf("unable to ping database: %w", err)
-	}
-
-	conn := &Connection{
-		pool:   pool,
-		config: &cfg,
-	}
-
-	// Initialize schema inspector
-	conn.inspector = NewSchemaInspector(conn)
-
-	log.Info().
-		Str("database", cfg.Database).
-		Str("user", cfg.User).
-		Msg("Database connection established")
-
-	return conn, nil
+	return poolConfig, nil
 }
 
 // NewConnectionWithPool creates a new Connection wrapper around an existing pgxpool.Pool.
@@ -226,15 +255,61 @@ func NewConnectionWithPool(pool *pgxpool.Pool) *Connection {
 
 // Close closes the database connection pool
 func (c *Connection) Close() {
+	if c.replicas != nil {
+		c.replicas.Close()
+	}
 	c.pool.Close()
 	log.Info().Msg("Database connection closed")
 }
 
+// RecordPoolStats publishes the connection pool stats to the metrics, if metrics are set
+func (c *Connection) RecordPoolStats() {
+	if c.metrics == nil {
+		return
+	}
+	stat := c.pool.Stat()
+	c.metrics.UpdateDBPoolStats(observability.DBPoolStats{
+		Total:                stat.TotalConns(),
+		Idle:                 stat.IdleConns(),
+		Acquired:             stat.AcquiredConns(),
+		Max:                  stat.MaxConns(),
+		EmptyAcquireCount:    stat.EmptyAcquireCount(),
+		EmptyAcquireWaitTime: stat.EmptyAcquireWaitTime(),
+	})
+}
+
 // Pool returns the underlying connection pool
 func (c *Connection) Pool() *pgxpool.Pool {
 	return c.pool
 }
 
+// ReadPool returns the pool for read-only queries that may see slightly stale data: a
+// healthy read replica, or the primary pool when no replica is configured or healthy
+func (c *Connection) ReadPool() *pgxpool.Pool {
+	if c.replicas != nil {
+		if pool := c.replicas.Pool(); pool != nil {
+			return pool
+		}
+	}
+	return c.pool
+}
+
+// ReplicaFailed takes a replica pool returned by ReadPool out of rotation until its next
+// health check. It does nothing for the primary pool.
+func (c *Connection) ReplicaFailed(pool *pgxpool.Pool, err error) {
+	if c.replicas != nil && pool != c.pool {
+		c.replicas.MarkFailed(pool, err)
+	}
+}
+
+// ReplicaStatus returns the health of the read replicas, or nil when none are configured
+func (c *Connection) ReplicaStatus() []ReplicaStatus {
+	if c.replicas == nil {
+		return nil
+	}
+	return c.replicas.Status()
+}
+
 // RecreatePool closes the current pool and creates a new one.
 // This is safer than Reset() as it ensures a completely fresh pool state.
 // Use this after schema changes (migrations) to avoid prepared statement cache issues.
@@ -243,55 +318,9 @@ func (c *Connection) RecreatePool() error {
 	c.pool.Close()
 
 	// Create a new pool with the same configuration
-	poolConfig, err := pgxpool.ParseConfig(c.config.RuntimeConnectionString())
+	poolConfig, err := newPoolConfig(c.config.RuntimeConnectionString(), c.config)
 	if err != nil {
-		return fmt.Errorf("unable to parse connection string: %w", err)
-	}
-
-	// Apply same configuration as NewConnection
-	poolConfig.MaxConns = c.config.MaxConnections
-	poolConfig.MinConns = c.config.MinConnections
-	poolConfig.MaxConnLifetime = c.config.MaxConnLifetime
-	poolConfig.MaxConnIdleTime = c.config.MaxConnIdleTime
-	poolConfig.HealthCheckPeriod = c.config.HealthCheck
-	poolConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeDescribeExec
-
-	// Copy the AfterConnect hook logic for custom type registration
-	poolConfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
-		// Register tsvector (OID 3614) as text
-		conn.TypeMap().RegisterType(&pgtype.Type{
-			Name:  "tsvector",
-			OID:   3614,
-			Codec: pgtype.TextCodec{},
-		})
-		// Register tsquery (OID 3615) as text
-		conn.TypeMap().RegisterType(&pgtype.Type{
-			Name:  "tsquery",
-			OID:   3615,
-			Codec: pgtype.TextCodec{},
-		})
-		// Register regclass (OID 2205) as text - used in some system views
-		conn.TypeMap().RegisterType(&pgtype.Type{
-			Name:  "regclass",
-			OID:   2205,
-			Codec: pgtype.TextCodec{},
-		})
-
-		// Register pgvector 'vector' type if the extension is installed
-		queryCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-		defer cancel()
-
-		var vectorOID uint32
-		err := conn.QueryRow(queryCtx, "SELECT oid FROM pg_type WHERE typname = 'vector'").Scan(&vectorOID)
-		if err == nil && vectorOID > 0 {
-			conn.TypeMap().RegisterType(&pgtype.Type{
-				Name:  "vector",
-				OID:   vectorOID,
-				Codec: pgtype.TextCodec{}, // Vectors are text-encoded as '[0.1,0.2,...]'
-			})
-		}
-
-		return nil
+		return err
 	}
 
 	// Create new pool
@@ -766,6 +795,11 @@ func (c *Connection) applyMigrations(m *migrate.Migrate, source string) error {
 
 // findHighestMigrationVersion scans embedded migrations to find the highest version number
 func (c *Connection) findHighestMigrationVersion() int {
+	return LatestMigrationVersion()
+}
+
+// LatestMigrationVersion returns the highest system migration version embedded in the binary
+func LatestMigrationVersion() int {
 	entries, err := migrationsFS.ReadDir("migrations")
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to read embedded migrations directory")
@@ -0,0 +1,6 @@
+-- Drop trigger and function
+DROP TRIGGER IF EXISTS trigger_update_conversation_escalations_updated_at ON ai.conversation_escalations;
+DROP FUNCTION IF EXISTS ai.update_conversation_escalations_updated_at();
+
+-- Drop table
+DROP TABLE IF EXISTS ai.conversation_escalations;
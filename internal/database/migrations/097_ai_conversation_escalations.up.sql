@@ -0,0 +1,65 @@
+-- Human escalation records for chatbot conversations
+-- A conversation is escalated when the user asks for a human or the model is not
+-- grounded enough to answer. While an escalation is open the chatbot replies with a
+-- holding message instead of calling the AI provider.
+CREATE TABLE ai.conversation_escalations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- No foreign key: conversations are only persisted when the chatbot opts in
+    conversation_id UUID NOT NULL,
+    chatbot_id UUID NOT NULL REFERENCES ai.chatbots(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL CHECK (reason IN ('user_request', 'low_confidence')),
+    trigger_message TEXT,
+    best_similarity DOUBLE PRECISION,
+    transcript JSONB NOT NULL DEFAULT '[]',
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'acknowledged', 'resolved')),
+    notes TEXT,
+    webhook_delivered_at TIMESTAMPTZ,
+    email_delivered_at TIMESTAMPTZ,
+    notification_error TEXT,
+    resolved_by UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_ai_escalations_conversation ON ai.conversation_escalations(conversation_id);
+CREATE INDEX idx_ai_escalations_chatbot ON ai.conversation_escalations(chatbot_id);
+CREATE INDEX idx_ai_escalations_status ON ai.conversation_escalations(status) WHERE status <> 'resolved';
+CREATE INDEX idx_ai_escalations_created ON ai.conversation_escalations(created_at DESC);
+
+-- Only one unresolved escalation per conversation
+CREATE UNIQUE INDEX idx_ai_escalations_single_open
+    ON ai.conversation_escalations(conversation_id) WHERE status <> 'resolved';
+
+COMMENT ON TABLE ai.conversation_escalations IS 'Chatbot conversations handed off to a human via webhook or email';
+COMMENT ON COLUMN ai.conversation_escalations.transcript IS 'Conversation messages at the time of escalation';
+
+-- RLS policies
+ALTER TABLE ai.conversation_escalations ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "ai_escalations_dashboard_admin" ON ai.conversation_escalations
+    FOR ALL TO authenticated
+    USING (auth.role() = 'dashboard_admin')
+    WITH CHECK (auth.role() = 'dashboard_admin');
+
+CREATE POLICY "ai_escalations_service_role" ON ai.conversation_escalations
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON ai.conversation_escalations TO service_role;
+
+-- Trigger to update updated_at timestamp
+CREATE OR REPLACE FUNCTION ai.update_conversation_escalations_updated_at()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_update_conversation_escalations_updated_at
+    BEFORE UPDATE ON ai.conversation_escalations
+    FOR EACH ROW
+    EXECUTE FUNCTION ai.update_conversation_escalations_updated_at();
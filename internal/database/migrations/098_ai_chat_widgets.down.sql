@@ -0,0 +1,7 @@
+-- Drop trigger and function
+DROP TRIGGER IF EXISTS trigger_update_chat_widgets_updated_at ON ai.chat_widgets;
+DROP FUNCTION IF EXISTS ai.update_chat_widgets_updated_at();
+
+-- Drop tables
+DROP TABLE IF EXISTS ai.chat_widget_sessions;
+DROP TABLE IF EXISTS ai.chat_widgets;
@@ -0,0 +1,91 @@
+-- Embeddable chat widgets
+-- A widget exposes a chatbot to anonymous website visitors through a public key.
+-- Sessions are only created for requests whose Origin matches the widget's domain allowlist.
+CREATE TABLE ai.chat_widgets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL UNIQUE,
+    chatbot_id UUID NOT NULL REFERENCES ai.chatbots(id) ON DELETE CASCADE,
+    public_key TEXT NOT NULL UNIQUE,
+    allowed_domains TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+    rate_limit_per_minute INTEGER NOT NULL DEFAULT 10 CHECK (rate_limit_per_minute > 0),
+    session_ttl_hours INTEGER NOT NULL DEFAULT 24 CHECK (session_ttl_hours > 0),
+    require_captcha BOOLEAN NOT NULL DEFAULT false,
+    collect_leads BOOLEAN NOT NULL DEFAULT false,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_ai_chat_widgets_chatbot ON ai.chat_widgets(chatbot_id);
+
+COMMENT ON TABLE ai.chat_widgets IS 'Public, key-scoped chat widgets that embed a chatbot on allowlisted websites';
+COMMENT ON COLUMN ai.chat_widgets.allowed_domains IS 'Hostnames allowed to embed the widget; *.example.com matches subdomains';
+
+-- Visitor sessions for a widget. Each session owns exactly one conversation.
+CREATE TABLE ai.chat_widget_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    widget_id UUID NOT NULL REFERENCES ai.chat_widgets(id) ON DELETE CASCADE,
+    conversation_id UUID NOT NULL,
+    visitor_id TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    origin TEXT,
+    ip_address TEXT,
+    user_agent TEXT,
+    lead_email TEXT,
+    lead_name TEXT,
+    lead_metadata JSONB NOT NULL DEFAULT '{}',
+    lead_captured_at TIMESTAMPTZ,
+    message_count INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_ai_chat_widget_sessions_widget ON ai.chat_widget_sessions(widget_id, created_at DESC);
+CREATE INDEX idx_ai_chat_widget_sessions_conversation ON ai.chat_widget_sessions(conversation_id);
+CREATE INDEX idx_ai_chat_widget_sessions_leads ON ai.chat_widget_sessions(widget_id) WHERE lead_email IS NOT NULL;
+CREATE INDEX idx_ai_chat_widget_sessions_expires ON ai.chat_widget_sessions(expires_at);
+
+COMMENT ON TABLE ai.chat_widget_sessions IS 'Anonymous visitor sessions created through chat widgets, linked to conversations and leads';
+
+-- RLS policies
+ALTER TABLE ai.chat_widgets ENABLE ROW LEVEL SECURITY;
+ALTER TABLE ai.chat_widget_sessions ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "ai_chat_widgets_dashboard_admin" ON ai.chat_widgets
+    FOR ALL TO authenticated
+    USING (auth.role() = 'dashboard_admin')
+    WITH CHECK (auth.role() = 'dashboard_admin');
+
+CREATE POLICY "ai_chat_widgets_service_role" ON ai.chat_widgets
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+CREATE POLICY "ai_chat_widget_sessions_dashboard_admin" ON ai.chat_widget_sessions
+    FOR ALL TO authenticated
+    USING (auth.role() = 'dashboard_admin')
+    WITH CHECK (auth.role() = 'dashboard_admin');
+
+CREATE POLICY "ai_chat_widget_sessions_service_role" ON ai.chat_widget_sessions
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON ai.chat_widgets TO service_role;
+GRANT ALL ON ai.chat_widget_sessions TO service_role;
+
+-- Trigger to update updated_at timestamp
+CREATE OR REPLACE FUNCTION ai.update_chat_widgets_updated_at()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_update_chat_widgets_updated_at
+    BEFORE UPDATE ON ai.chat_widgets
+    FOR EACH ROW
+    EXECUTE FUNCTION ai.update_chat_widgets_updated_at();
@@ -0,0 +1,2 @@
+-- Drop table
+DROP TABLE IF EXISTS ai.embedding_usage;
@@ -0,0 +1,43 @@
+-- Embedding usage metering
+-- One row per embeddings API request, used for per-user daily quotas and cost reporting.
+CREATE TABLE ai.embedding_usage (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+    client_key_id UUID,
+    source TEXT NOT NULL DEFAULT 'api',
+    provider TEXT,
+    model TEXT NOT NULL,
+    input_count INTEGER NOT NULL DEFAULT 0,
+    batch_count INTEGER NOT NULL DEFAULT 0,
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    total_tokens INTEGER NOT NULL DEFAULT 0,
+    tokens_estimated BOOLEAN NOT NULL DEFAULT false,
+    duration_ms INTEGER,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_ai_embedding_usage_user_created ON ai.embedding_usage(user_id, created_at DESC);
+CREATE INDEX idx_ai_embedding_usage_created ON ai.embedding_usage(created_at DESC);
+
+COMMENT ON TABLE ai.embedding_usage IS 'Token usage of the embeddings API for quotas and cost tracking';
+COMMENT ON COLUMN ai.embedding_usage.tokens_estimated IS 'True when the provider did not report usage and tokens were estimated';
+
+-- RLS policies
+ALTER TABLE ai.embedding_usage ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "ai_embedding_usage_dashboard_admin" ON ai.embedding_usage
+    FOR ALL TO authenticated
+    USING (auth.role() = 'dashboard_admin')
+    WITH CHECK (auth.role() = 'dashboard_admin');
+
+CREATE POLICY "ai_embedding_usage_own_read" ON ai.embedding_usage
+    FOR SELECT TO authenticated
+    USING (user_id = auth.uid());
+
+CREATE POLICY "ai_embedding_usage_service_role" ON ai.embedding_usage
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT SELECT ON ai.embedding_usage TO authenticated;
+GRANT ALL ON ai.embedding_usage TO service_role;
@@ -0,0 +1,9 @@
+-- Drop triggers and function
+DROP TRIGGER IF EXISTS trigger_update_vector_points_updated_at ON ai.vector_points;
+DROP TRIGGER IF EXISTS trigger_update_vector_collections_updated_at ON ai.vector_collections;
+DROP FUNCTION IF EXISTS ai.update_vector_collections_updated_at();
+
+-- Drop tables (per-collection indexes are dropped with ai.vector_points)
+DROP TABLE IF EXISTS ai.vector_collection_permissions;
+DROP TABLE IF EXISTS ai.vector_points;
+DROP TABLE IF EXISTS ai.vector_collections;
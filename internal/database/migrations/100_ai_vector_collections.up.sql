@@ -0,0 +1,116 @@
+-- Vector collections
+-- Lightweight vector stores decoupled from the knowledge base document/chunk model.
+-- Points store a vector plus a JSON payload; each collection gets its own partial
+-- HNSW index (created by the server) so collections can use different dimensions.
+CREATE TABLE ai.vector_collections (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    namespace TEXT NOT NULL DEFAULT 'default',
+    description TEXT,
+    dimensions INTEGER NOT NULL CHECK (dimensions > 0 AND dimensions <= 16000),
+    distance_metric TEXT NOT NULL DEFAULT 'cosine' CHECK (distance_metric IN ('cosine', 'l2', 'inner_product')),
+    embedding_model TEXT,
+    point_count INTEGER NOT NULL DEFAULT 0,
+    quota_max_points INTEGER NOT NULL DEFAULT 100000 CHECK (quota_max_points >= 0),
+    owner_id UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    CONSTRAINT unique_vector_collection_name_namespace UNIQUE (name, namespace)
+);
+
+CREATE INDEX idx_ai_vector_collections_owner ON ai.vector_collections(owner_id) WHERE owner_id IS NOT NULL;
+
+COMMENT ON TABLE ai.vector_collections IS 'Standalone vector stores with payloads, independent of knowledge bases';
+COMMENT ON COLUMN ai.vector_collections.embedding_model IS 'Model used to embed text for points and queries that do not supply a vector';
+
+CREATE TABLE ai.vector_points (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    collection_id UUID NOT NULL REFERENCES ai.vector_collections(id) ON DELETE CASCADE,
+    external_id TEXT NOT NULL,
+    embedding vector NOT NULL,
+    content TEXT,
+    metadata JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    CONSTRAINT unique_vector_point_external_id UNIQUE (collection_id, external_id)
+);
+
+CREATE INDEX idx_ai_vector_points_metadata ON ai.vector_points USING GIN (metadata);
+
+COMMENT ON TABLE ai.vector_points IS 'Vectors and payloads stored in vector collections';
+COMMENT ON COLUMN ai.vector_points.external_id IS 'Caller-supplied point ID, unique within the collection';
+COMMENT ON COLUMN ai.vector_points.metadata IS 'Payload used for filtering query results';
+
+-- Sharing uses the same permission levels as knowledge bases
+CREATE TABLE ai.vector_collection_permissions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    collection_id UUID NOT NULL REFERENCES ai.vector_collections(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    permission TEXT NOT NULL CHECK (permission IN ('viewer', 'editor', 'owner')),
+    granted_by UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+    granted_at TIMESTAMPTZ DEFAULT NOW(),
+
+    CONSTRAINT unique_vector_collection_permission UNIQUE (collection_id, user_id)
+);
+
+CREATE INDEX idx_ai_vector_collection_permissions_user ON ai.vector_collection_permissions(user_id);
+
+-- RLS policies
+ALTER TABLE ai.vector_collections ENABLE ROW LEVEL SECURITY;
+ALTER TABLE ai.vector_points ENABLE ROW LEVEL SECURITY;
+ALTER TABLE ai.vector_collection_permissions ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "ai_vector_collections_dashboard_admin" ON ai.vector_collections
+    FOR ALL TO authenticated
+    USING (auth.role() = 'dashboard_admin')
+    WITH CHECK (auth.role() = 'dashboard_admin');
+
+CREATE POLICY "ai_vector_collections_service_role" ON ai.vector_collections
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+CREATE POLICY "ai_vector_points_dashboard_admin" ON ai.vector_points
+    FOR ALL TO authenticated
+    USING (auth.role() = 'dashboard_admin')
+    WITH CHECK (auth.role() = 'dashboard_admin');
+
+CREATE POLICY "ai_vector_points_service_role" ON ai.vector_points
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+CREATE POLICY "ai_vector_collection_permissions_dashboard_admin" ON ai.vector_collection_permissions
+    FOR ALL TO authenticated
+    USING (auth.role() = 'dashboard_admin')
+    WITH CHECK (auth.role() = 'dashboard_admin');
+
+CREATE POLICY "ai_vector_collection_permissions_service_role" ON ai.vector_collection_permissions
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON ai.vector_collections TO service_role;
+GRANT ALL ON ai.vector_points TO service_role;
+GRANT ALL ON ai.vector_collection_permissions TO service_role;
+
+-- Triggers to update updated_at timestamps
+CREATE OR REPLACE FUNCTION ai.update_vector_collections_updated_at()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_update_vector_collections_updated_at
+    BEFORE UPDATE ON ai.vector_collections
+    FOR EACH ROW
+    EXECUTE FUNCTION ai.update_vector_collections_updated_at();
+
+CREATE TRIGGER trigger_update_vector_points_updated_at
+    BEFORE UPDATE ON ai.vector_points
+    FOR EACH ROW
+    EXECUTE FUNCTION ai.update_vector_collections_updated_at();
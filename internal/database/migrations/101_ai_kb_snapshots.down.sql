@@ -0,0 +1,4 @@
+-- Drop tables
+DROP TABLE IF EXISTS ai.knowledge_base_snapshot_schedules;
+DROP TABLE IF EXISTS ai.knowledge_base_snapshot_items;
+DROP TABLE IF EXISTS ai.knowledge_base_snapshots;
@@ -0,0 +1,86 @@
+-- Knowledge base snapshots
+-- Point-in-time copies of a knowledge base's logical state (documents, chunks with
+-- embeddings, document permissions and entity mentions) that a knowledge base can be
+-- rolled back to, e.g. after a bad bulk import. Rows are stored as JSONB in the same
+-- format returned by the snapshot export endpoint.
+CREATE TABLE ai.knowledge_base_snapshots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    knowledge_base_id UUID NOT NULL REFERENCES ai.knowledge_bases(id) ON DELETE CASCADE,
+    label TEXT,
+    trigger TEXT NOT NULL DEFAULT 'manual' CHECK (trigger IN ('manual', 'scheduled', 'pre_rollback')),
+    format_version INTEGER NOT NULL DEFAULT 1,
+    document_count INTEGER NOT NULL DEFAULT 0,
+    chunk_count INTEGER NOT NULL DEFAULT 0,
+    manifest JSONB NOT NULL DEFAULT '{}',
+    created_by UUID, -- auth or dashboard user, so no foreign key
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_ai_kb_snapshots_kb_created ON ai.knowledge_base_snapshots(knowledge_base_id, created_at DESC);
+
+COMMENT ON TABLE ai.knowledge_base_snapshots IS 'Point-in-time snapshots of knowledge base documents and chunks for rollback';
+COMMENT ON COLUMN ai.knowledge_base_snapshots.manifest IS 'Embedding model, dimensions and per-item-type counts at snapshot time';
+
+CREATE TABLE ai.knowledge_base_snapshot_items (
+    id BIGSERIAL PRIMARY KEY,
+    snapshot_id UUID NOT NULL REFERENCES ai.knowledge_base_snapshots(id) ON DELETE CASCADE,
+    item_type TEXT NOT NULL CHECK (item_type IN ('document', 'chunk', 'document_permission', 'document_entity')),
+    data JSONB NOT NULL
+);
+
+CREATE INDEX idx_ai_kb_snapshot_items_snapshot ON ai.knowledge_base_snapshot_items(snapshot_id, item_type);
+
+COMMENT ON TABLE ai.knowledge_base_snapshot_items IS 'Rows captured by a knowledge base snapshot, stored as to_jsonb() of the source row';
+
+-- Recurring snapshot schedules (one per knowledge base)
+CREATE TABLE ai.knowledge_base_snapshot_schedules (
+    knowledge_base_id UUID PRIMARY KEY REFERENCES ai.knowledge_bases(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    interval_hours INTEGER NOT NULL DEFAULT 24 CHECK (interval_hours > 0),
+    retention INTEGER NOT NULL DEFAULT 7 CHECK (retention > 0),
+    last_snapshot_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+COMMENT ON COLUMN ai.knowledge_base_snapshot_schedules.retention IS 'Number of scheduled snapshots to keep; manual snapshots are never pruned';
+
+-- RLS policies
+ALTER TABLE ai.knowledge_base_snapshots ENABLE ROW LEVEL SECURITY;
+ALTER TABLE ai.knowledge_base_snapshot_items ENABLE ROW LEVEL SECURITY;
+ALTER TABLE ai.knowledge_base_snapshot_schedules ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "ai_kb_snapshots_dashboard_admin" ON ai.knowledge_base_snapshots
+    FOR ALL TO authenticated
+    USING (auth.role() = 'dashboard_admin')
+    WITH CHECK (auth.role() = 'dashboard_admin');
+
+CREATE POLICY "ai_kb_snapshots_service_role" ON ai.knowledge_base_snapshots
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+CREATE POLICY "ai_kb_snapshot_items_dashboard_admin" ON ai.knowledge_base_snapshot_items
+    FOR ALL TO authenticated
+    USING (auth.role() = 'dashboard_admin')
+    WITH CHECK (auth.role() = 'dashboard_admin');
+
+CREATE POLICY "ai_kb_snapshot_items_service_role" ON ai.knowledge_base_snapshot_items
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+CREATE POLICY "ai_kb_snapshot_schedules_dashboard_admin" ON ai.knowledge_base_snapshot_schedules
+    FOR ALL TO authenticated
+    USING (auth.role() = 'dashboard_admin')
+    WITH CHECK (auth.role() = 'dashboard_admin');
+
+CREATE POLICY "ai_kb_snapshot_schedules_service_role" ON ai.knowledge_base_snapshot_schedules
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON ai.knowledge_base_snapshots TO service_role;
+GRANT ALL ON ai.knowledge_base_snapshot_items TO service_role;
+GRANT USAGE, SELECT ON SEQUENCE ai.knowledge_base_snapshot_items_id_seq TO service_role;
+GRANT ALL ON ai.knowledge_base_snapshot_schedules TO service_role;
@@ -0,0 +1,2 @@
+-- Drop API usage rollups
+DROP TABLE IF EXISTS api.usage_hourly;
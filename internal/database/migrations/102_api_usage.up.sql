@@ -0,0 +1,54 @@
+-- API usage analytics
+-- Hourly rollups of request counts, bytes and latency per client key and per user.
+-- Rows are upserted by the server's usage recorder; daily figures are aggregated from hourly rows.
+CREATE TABLE IF NOT EXISTS api.usage_hourly (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- Start of the hour this row covers
+    bucket TIMESTAMPTZ NOT NULL,
+
+    -- Client key that authenticated the requests (NULL for JWT/anonymous requests)
+    client_key_id UUID,
+
+    -- Authenticated user (NULL for anonymous or key-only requests)
+    user_id UUID,
+
+    request_count BIGINT NOT NULL DEFAULT 0,
+    error_count BIGINT NOT NULL DEFAULT 0,
+    bytes_in BIGINT NOT NULL DEFAULT 0,
+    bytes_out BIGINT NOT NULL DEFAULT 0,
+    total_duration_ms BIGINT NOT NULL DEFAULT 0,
+    max_duration_ms INTEGER NOT NULL DEFAULT 0,
+
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One row per (hour, key, user); NULLs are folded so anonymous traffic shares a row
+CREATE UNIQUE INDEX IF NOT EXISTS idx_usage_hourly_bucket_subject
+    ON api.usage_hourly (
+        bucket,
+        COALESCE(client_key_id, '00000000-0000-0000-0000-000000000000'::uuid),
+        COALESCE(user_id, '00000000-0000-0000-0000-000000000000'::uuid)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_usage_hourly_client_key_bucket
+    ON api.usage_hourly(client_key_id, bucket DESC)
+    WHERE client_key_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_usage_hourly_user_bucket
+    ON api.usage_hourly(user_id, bucket DESC)
+    WHERE user_id IS NOT NULL;
+
+COMMENT ON TABLE api.usage_hourly IS 'Hourly API usage rollups per client key and per user';
+COMMENT ON COLUMN api.usage_hourly.error_count IS 'Requests answered with a 4xx or 5xx status';
+COMMENT ON COLUMN api.usage_hourly.total_duration_ms IS 'Sum of request latencies, divide by request_count for the average';
+
+-- RLS policies (the api schema is only reachable by service_role, see migration 076)
+ALTER TABLE api.usage_hourly ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "api_usage_hourly_service_role" ON api.usage_hourly
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON api.usage_hourly TO service_role;
@@ -0,0 +1,2 @@
+-- Drop metering export history
+DROP TABLE IF EXISTS api.metering_exports;
@@ -0,0 +1,43 @@
+-- Billing/metering exports
+-- One row per exported period. The latest completed period_end is the export cursor;
+-- failed periods are retried on the next run.
+CREATE TABLE IF NOT EXISTS api.metering_exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- Half-open period [period_start, period_end) covered by the export
+    period_start TIMESTAMPTZ NOT NULL,
+    period_end TIMESTAMPTZ NOT NULL,
+
+    status TEXT NOT NULL CHECK (status IN ('completed', 'failed')),
+    event_count INTEGER NOT NULL DEFAULT 0,
+
+    -- Sinks the export was delivered to ('bucket', 'webhook')
+    destinations TEXT[] NOT NULL DEFAULT '{}',
+
+    -- Object key of the JSON lines file when exported to a bucket
+    object_key TEXT,
+
+    error_message TEXT,
+    attempts INTEGER NOT NULL DEFAULT 1,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (period_start, period_end)
+);
+
+CREATE INDEX IF NOT EXISTS idx_metering_exports_completed_end
+    ON api.metering_exports(period_end DESC)
+    WHERE status = 'completed';
+
+COMMENT ON TABLE api.metering_exports IS 'History and cursor of billing/metering exports';
+
+-- RLS policies (the api schema is only reachable by service_role, see migration 076)
+ALTER TABLE api.metering_exports ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "api_metering_exports_service_role" ON api.metering_exports
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON api.metering_exports TO service_role;
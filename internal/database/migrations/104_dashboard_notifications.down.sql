@@ -0,0 +1,4 @@
+-- Drop dashboard notification center
+ALTER TABLE dashboard.oauth_providers DROP COLUMN IF EXISTS client_secret_expires_at;
+
+DROP TABLE IF EXISTS dashboard.notifications;
@@ -0,0 +1,55 @@
+--
+-- Dashboard notification center
+-- Background checks raise notifications about expiring credentials and
+-- configuration drift. Each condition has a stable key, so re-checks update
+-- the existing notification and conditions that clear resolve it.
+--
+
+CREATE TABLE IF NOT EXISTS dashboard.notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- Stable identity of the condition, e.g. 'saml_certificate:okta:idp_signing'
+    key TEXT NOT NULL UNIQUE,
+    category TEXT NOT NULL,
+    severity TEXT NOT NULL CHECK (severity IN ('info', 'warning', 'critical')),
+    title TEXT NOT NULL,
+    message TEXT NOT NULL,
+    metadata JSONB NOT NULL DEFAULT '{}'::JSONB,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ,
+    dismissed_at TIMESTAMPTZ,
+    dismissed_by UUID
+);
+
+CREATE INDEX IF NOT EXISTS idx_dashboard_notifications_active
+    ON dashboard.notifications(updated_at DESC)
+    WHERE resolved_at IS NULL;
+
+COMMENT ON TABLE dashboard.notifications IS 'Admin notifications about expiring credentials and configuration drift';
+
+ALTER TABLE dashboard.notifications ENABLE ROW LEVEL SECURITY;
+ALTER TABLE dashboard.notifications FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS dashboard_notifications_access ON dashboard.notifications;
+CREATE POLICY dashboard_notifications_access ON dashboard.notifications
+    FOR ALL
+    USING (
+        auth.current_user_role() = 'service_role'
+        OR auth.current_user_role() = 'dashboard_admin'
+    )
+    WITH CHECK (
+        auth.current_user_role() = 'service_role'
+        OR auth.current_user_role() = 'dashboard_admin'
+    );
+
+COMMENT ON POLICY dashboard_notifications_access ON dashboard.notifications IS 'Only service role and dashboard admins can access notifications.';
+
+GRANT ALL ON dashboard.notifications TO service_role;
+
+-- Rotation date of OAuth client secrets, set by admins when the secret is issued
+ALTER TABLE dashboard.oauth_providers
+ADD COLUMN IF NOT EXISTS client_secret_expires_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN dashboard.oauth_providers.client_secret_expires_at IS 'When the client secret expires or must be rotated; drives dashboard notifications';
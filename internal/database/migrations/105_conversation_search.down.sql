@@ -0,0 +1,5 @@
+-- Drop semantic conversation search
+
+DROP TABLE IF EXISTS ai.message_embeddings;
+DROP INDEX IF EXISTS ai.idx_ai_conversations_searchable;
+ALTER TABLE ai.conversations DROP COLUMN IF EXISTS searchable;
@@ -0,0 +1,44 @@
+-- Semantic conversation search
+-- Messages of conversations whose chatbot enables @fluxbase:conversation-search are embedded
+-- by a background indexer. Search is opt-in per chatbot and decided when a conversation starts,
+-- so enabling it later does not index conversations that began without it.
+
+ALTER TABLE ai.conversations
+ADD COLUMN IF NOT EXISTS searchable BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN ai.conversations.searchable IS 'Messages are embedded for semantic search (from @fluxbase:conversation-search when the conversation was created)';
+
+CREATE TABLE IF NOT EXISTS ai.message_embeddings (
+    message_id UUID PRIMARY KEY REFERENCES ai.messages(id) ON DELETE CASCADE,
+    conversation_id UUID NOT NULL REFERENCES ai.conversations(id) ON DELETE CASCADE,
+    embedding vector NOT NULL,
+    embedding_model TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_message_embeddings_conversation ON ai.message_embeddings(conversation_id);
+CREATE INDEX IF NOT EXISTS idx_ai_conversations_searchable ON ai.conversations(user_id) WHERE searchable AND status = 'active';
+
+COMMENT ON TABLE ai.message_embeddings IS 'Embeddings of conversation messages for semantic conversation search; removed when the conversation is deleted or expires';
+
+ALTER TABLE ai.message_embeddings ENABLE ROW LEVEL SECURITY;
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE schemaname = 'ai' AND tablename = 'message_embeddings' AND policyname = 'ai_message_embeddings_service_all') THEN
+        CREATE POLICY "ai_message_embeddings_service_all" ON ai.message_embeddings FOR ALL TO service_role USING (true);
+    END IF;
+END $$;
+
+-- Users can read embeddings of their own conversations
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE schemaname = 'ai' AND tablename = 'message_embeddings' AND policyname = 'ai_message_embeddings_own') THEN
+        CREATE POLICY "ai_message_embeddings_own" ON ai.message_embeddings
+            FOR SELECT TO authenticated
+            USING (conversation_id IN (
+                SELECT id FROM ai.conversations WHERE user_id = auth.current_user_id()
+            ));
+    END IF;
+END $$;
+
+GRANT SELECT ON ai.message_embeddings TO authenticated;
+GRANT ALL ON ai.message_embeddings TO service_role;
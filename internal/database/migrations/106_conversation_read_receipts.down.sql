@@ -0,0 +1,3 @@
+-- Remove read receipts for realtime chat
+
+ALTER TABLE ai.conversations DROP COLUMN IF EXISTS last_read_sequence;
@@ -0,0 +1,7 @@
+-- Read receipts for realtime chat
+-- Stores how far the user has read a conversation so every device shows the same read state.
+
+ALTER TABLE ai.conversations
+ADD COLUMN IF NOT EXISTS last_read_sequence INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN ai.conversations.last_read_sequence IS 'Sequence number of the last message the user has read on any device';
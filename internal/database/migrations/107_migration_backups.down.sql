@@ -0,0 +1,3 @@
+-- Remove backup tracking for destructive API migrations
+
+ALTER TABLE migrations.execution_logs DROP COLUMN IF EXISTS backup_tables;
@@ -0,0 +1,7 @@
+-- Backups for destructive API migrations
+-- Records the copies of tables taken before a migration dropped, truncated or narrowed them.
+
+ALTER TABLE migrations.execution_logs
+ADD COLUMN IF NOT EXISTS backup_tables TEXT[];
+
+COMMENT ON COLUMN migrations.execution_logs.backup_tables IS 'Backup copies (in the migrations schema) of the tables a destructive migration changed';
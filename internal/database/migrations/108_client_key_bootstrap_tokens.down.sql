@@ -0,0 +1,2 @@
+-- Drop client key bootstrap tokens
+DROP TABLE IF EXISTS auth.client_key_bootstrap_tokens;
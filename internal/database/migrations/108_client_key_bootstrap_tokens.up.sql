@@ -0,0 +1,40 @@
+-- Bootstrap tokens for client key enrollment
+-- An admin creates a one-time, short-lived token; a new service exchanges it for a
+-- client key with constrained scopes, so long-lived keys never appear in provisioning scripts.
+
+CREATE TABLE IF NOT EXISTS auth.client_key_bootstrap_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    description TEXT,
+    token_hash TEXT NOT NULL UNIQUE,
+    token_prefix TEXT NOT NULL,
+    scopes TEXT[] NOT NULL,
+    rate_limit_per_minute INTEGER NOT NULL DEFAULT 100,
+    key_ttl_seconds INTEGER,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_by UUID,
+    used_at TIMESTAMPTZ,
+    used_by_ip TEXT,
+    client_key_id UUID REFERENCES auth.client_keys(id) ON DELETE SET NULL,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_client_key_bootstrap_tokens_created_at ON auth.client_key_bootstrap_tokens(created_at DESC);
+
+ALTER TABLE auth.client_key_bootstrap_tokens ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY client_key_bootstrap_tokens_admin ON auth.client_key_bootstrap_tokens
+    FOR ALL
+    USING (
+        auth.is_admin()
+        OR auth.current_user_role() = 'dashboard_admin'
+        OR auth.current_user_role() = 'service_role'
+    );
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON auth.client_key_bootstrap_tokens TO service_role;
+
+COMMENT ON TABLE auth.client_key_bootstrap_tokens IS 'One-time enrollment tokens that are exchanged for a client key';
+COMMENT ON COLUMN auth.client_key_bootstrap_tokens.scopes IS 'Maximum scopes of the client key issued for this token';
+COMMENT ON COLUMN auth.client_key_bootstrap_tokens.key_ttl_seconds IS 'Lifetime of the issued client key in seconds, NULL for no expiry';
+COMMENT ON COLUMN auth.client_key_bootstrap_tokens.client_key_id IS 'Client key issued when the token was used';
@@ -0,0 +1,4 @@
+-- Drop email normalization
+DROP INDEX IF EXISTS auth.idx_auth_users_email_normalized;
+ALTER TABLE auth.users DROP COLUMN IF EXISTS email_normalized;
+DROP FUNCTION IF EXISTS auth.normalize_email(TEXT);
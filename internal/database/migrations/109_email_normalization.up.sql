@@ -0,0 +1,52 @@
+-- Email normalization
+-- Gmail ignores dots in the local part and many providers ignore a "+tag" suffix, so several
+-- addresses can reach the same mailbox. email_normalized holds the canonical form so signup can
+-- reject aliases of existing accounts, sign-in can accept them, and admins can find duplicates.
+-- The rules must stay in sync with NormalizeEmail in internal/auth/email_normalization.go.
+
+CREATE OR REPLACE FUNCTION auth.normalize_email(email TEXT)
+RETURNS TEXT AS $$
+DECLARE
+    addr TEXT := lower(btrim(email));
+    at_pos INTEGER;
+    local_part TEXT;
+    domain_part TEXT;
+BEGIN
+    IF addr IS NULL OR strpos(addr, '@') = 0 THEN
+        RETURN addr;
+    END IF;
+
+    -- Split at the last '@'
+    at_pos := length(addr) - strpos(reverse(addr), '@') + 1;
+    IF at_pos <= 1 OR at_pos = length(addr) THEN
+        RETURN addr;
+    END IF;
+    local_part := substr(addr, 1, at_pos - 1);
+    domain_part := substr(addr, at_pos + 1);
+
+    -- Providers that ignore a "+tag" suffix
+    IF domain_part = ANY (ARRAY[
+        'gmail.com', 'googlemail.com', 'outlook.com', 'hotmail.com', 'live.com', 'msn.com',
+        'icloud.com', 'me.com', 'mac.com', 'protonmail.com', 'proton.me', 'pm.me', 'fastmail.com'
+    ]) AND strpos(local_part, '+') > 1 THEN
+        local_part := substr(local_part, 1, strpos(local_part, '+') - 1);
+    END IF;
+
+    -- Gmail ignores dots, and googlemail.com is an alias of gmail.com
+    IF domain_part IN ('gmail.com', 'googlemail.com') THEN
+        local_part := replace(local_part, '.', '');
+        domain_part := 'gmail.com';
+    END IF;
+
+    RETURN local_part || '@' || domain_part;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE PARALLEL SAFE;
+
+COMMENT ON FUNCTION auth.normalize_email(TEXT) IS 'Returns the canonical form of an email address (lowercased, provider aliases such as Gmail dots and +tag suffixes removed).';
+
+-- Generated so every write path (signup, OAuth, SAML, import, admin) stays consistent and
+-- existing rows are backfilled. Not unique: existing duplicates must be resolved by an admin.
+ALTER TABLE auth.users
+    ADD COLUMN IF NOT EXISTS email_normalized TEXT GENERATED ALWAYS AS (auth.normalize_email(email)) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_auth_users_email_normalized ON auth.users(email_normalized);
@@ -0,0 +1,3 @@
+-- Drop document status webhooks
+DROP TRIGGER IF EXISTS documents_status_webhook ON ai.documents;
+DROP FUNCTION IF EXISTS ai.queue_document_status_webhook_event();
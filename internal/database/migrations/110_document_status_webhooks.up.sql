@@ -0,0 +1,97 @@
+-- Document status webhooks
+-- Queues an INDEXED or FAILED webhook event when a knowledge base document finishes processing,
+-- so ingestion pipelines can track completion without polling. Webhooks subscribe with
+-- {"table": "ai.documents", "operations": ["INDEXED", "FAILED"]}. The payload carries the chunk
+-- count and error message instead of the document content.
+
+CREATE OR REPLACE FUNCTION ai.queue_document_status_webhook_event()
+RETURNS TRIGGER AS $$
+DECLARE
+    webhook_record RECORD;
+    event_type TEXT := upper(NEW.status);
+    record_owner_id UUID := COALESCE(NEW.owner_id, NEW.created_by);
+    kb_name TEXT;
+    kb_namespace TEXT;
+    event_data JSONB;
+BEGIN
+    FOR webhook_record IN
+        SELECT id
+        FROM auth.webhooks
+        WHERE enabled = TRUE
+          AND (
+              scope = 'global'
+              OR created_by IS NULL
+              OR record_owner_id IS NULL
+              OR created_by = record_owner_id
+          )
+          AND jsonb_typeof(events) = 'array'
+          AND EXISTS (
+              SELECT 1
+              FROM jsonb_array_elements(events) AS event
+              WHERE event->>'table' = 'ai.documents'
+                AND (
+                    event->'operations' @> to_jsonb(ARRAY[event_type])
+                    OR event->'operations' @> to_jsonb(ARRAY['*'])
+                )
+          )
+    LOOP
+        IF event_data IS NULL THEN
+            SELECT name, namespace INTO kb_name, kb_namespace
+            FROM ai.knowledge_bases
+            WHERE id = NEW.knowledge_base_id;
+
+            event_data := jsonb_build_object(
+                'id', NEW.id,
+                'knowledge_base_id', NEW.knowledge_base_id,
+                'knowledge_base_name', kb_name,
+                'namespace', kb_namespace,
+                'title', NEW.title,
+                'source_type', NEW.source_type,
+                'source_url', NEW.source_url,
+                'status', NEW.status,
+                'previous_status', OLD.status,
+                'chunks_count', COALESCE(NEW.chunks_count, 0),
+                'error_message', NULLIF(NEW.error_message, ''),
+                'metadata', NEW.metadata,
+                'tags', to_jsonb(NEW.tags),
+                'owner_id', NEW.owner_id,
+                'indexed_at', NEW.indexed_at,
+                'updated_at', NEW.updated_at
+            );
+        END IF;
+
+        INSERT INTO auth.webhook_events (
+            webhook_id,
+            event_type,
+            table_schema,
+            table_name,
+            record_id,
+            old_data,
+            new_data,
+            next_retry_at
+        ) VALUES (
+            webhook_record.id,
+            event_type,
+            TG_TABLE_SCHEMA,
+            TG_TABLE_NAME,
+            NEW.id::TEXT,
+            NULL,
+            event_data,
+            CURRENT_TIMESTAMP
+        );
+
+        PERFORM pg_notify('webhook_event', webhook_record.id::TEXT);
+    END LOOP;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMENT ON FUNCTION ai.queue_document_status_webhook_event() IS 'Trigger function that queues INDEXED and FAILED webhook events when a document finishes processing';
+
+DROP TRIGGER IF EXISTS documents_status_webhook ON ai.documents;
+CREATE TRIGGER documents_status_webhook
+AFTER UPDATE OF status ON ai.documents
+FOR EACH ROW
+WHEN (NEW.status IS DISTINCT FROM OLD.status AND NEW.status IN ('indexed', 'failed'))
+EXECUTE FUNCTION ai.queue_document_status_webhook_event();
@@ -0,0 +1,4 @@
+-- Drop chatbot model routing columns
+ALTER TABLE ai.messages DROP COLUMN IF EXISTS cost_delta_usd;
+ALTER TABLE ai.messages DROP COLUMN IF EXISTS routing_rule;
+ALTER TABLE ai.messages DROP COLUMN IF EXISTS model;
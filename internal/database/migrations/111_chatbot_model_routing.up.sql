@@ -0,0 +1,11 @@
+-- Chatbot model routing
+-- Records which model answered each assistant message, the route that chose it, and the cost
+-- compared to the chatbot's default model, so usage metering can report routing savings.
+
+ALTER TABLE ai.messages ADD COLUMN IF NOT EXISTS model TEXT;
+ALTER TABLE ai.messages ADD COLUMN IF NOT EXISTS routing_rule TEXT;
+ALTER TABLE ai.messages ADD COLUMN IF NOT EXISTS cost_delta_usd NUMERIC(18, 8);
+
+COMMENT ON COLUMN ai.messages.model IS 'Model chosen by the chatbot''s model routes (NULL when the chatbot does not route)';
+COMMENT ON COLUMN ai.messages.routing_rule IS 'Conditions of the model route that chose the model (NULL for the default model)';
+COMMENT ON COLUMN ai.messages.cost_delta_usd IS 'Cost of the message minus its cost on the default model, from @fluxbase:model-cost prices';
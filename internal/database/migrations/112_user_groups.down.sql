@@ -0,0 +1,228 @@
+-- Rollback User Groups
+
+-- ============================================================================
+-- RESTORE USER-ONLY POLICIES
+-- ============================================================================
+
+DROP POLICY IF EXISTS "ai_kb_manage_own" ON ai.knowledge_bases;
+CREATE POLICY "ai_kb_manage_own" ON ai.knowledge_bases
+    FOR ALL
+    TO authenticated
+    USING (
+        owner_id = auth.current_user_id()
+        OR EXISTS (
+            SELECT 1 FROM ai.knowledge_base_permissions
+            WHERE knowledge_base_id = ai.knowledge_bases.id
+            AND user_id = auth.current_user_id()
+            AND permission IN ('editor', 'owner')
+        )
+    )
+    WITH CHECK (
+        owner_id = auth.current_user_id()
+        OR EXISTS (
+            SELECT 1 FROM ai.knowledge_base_permissions
+            WHERE knowledge_base_id = ai.knowledge_bases.id
+            AND user_id = auth.current_user_id()
+            AND permission IN ('editor', 'owner')
+        )
+    );
+
+DROP POLICY IF EXISTS "ai_kb_read_shared" ON ai.knowledge_bases;
+CREATE POLICY "ai_kb_read_shared" ON ai.knowledge_bases
+    FOR SELECT
+    TO authenticated
+    USING (
+        visibility = 'shared'
+        AND EXISTS (
+            SELECT 1 FROM ai.knowledge_base_permissions
+            WHERE knowledge_base_id = ai.knowledge_bases.id
+            AND user_id = auth.current_user_id()
+        )
+    );
+
+DROP POLICY IF EXISTS "ai_documents_read_via_kb" ON ai.documents;
+CREATE POLICY "ai_documents_read_via_kb" ON ai.documents
+    FOR SELECT
+    TO authenticated
+    USING (
+        EXISTS (
+            SELECT 1 FROM ai.knowledge_bases kb
+            WHERE kb.id = ai.documents.knowledge_base_id
+            AND kb.owner_id = auth.current_user_id()
+        )
+        OR EXISTS (
+            SELECT 1 FROM ai.knowledge_bases kb
+            JOIN ai.knowledge_base_permissions kbp ON kb.id = kbp.knowledge_base_id
+            WHERE kb.id = ai.documents.knowledge_base_id
+            AND kbp.user_id = auth.current_user_id()
+        )
+        OR EXISTS (
+            SELECT 1 FROM ai.knowledge_bases kb
+            WHERE kb.id = ai.documents.knowledge_base_id
+            AND kb.visibility = 'public'
+        )
+    );
+
+DROP POLICY IF EXISTS "ai_documents_insert_via_kb" ON ai.documents;
+CREATE POLICY "ai_documents_insert_via_kb" ON ai.documents
+    FOR INSERT
+    TO authenticated
+    WITH CHECK (
+        EXISTS (
+            SELECT 1 FROM ai.knowledge_bases kb
+            WHERE kb.id = ai.documents.knowledge_base_id
+            AND kb.owner_id = auth.current_user_id()
+        )
+        OR EXISTS (
+            SELECT 1 FROM ai.knowledge_bases kb
+            JOIN ai.knowledge_base_permissions kbp ON kb.id = kbp.knowledge_base_id
+            WHERE kb.id = ai.documents.knowledge_base_id
+            AND kbp.user_id = auth.current_user_id()
+            AND kbp.permission IN ('editor', 'owner')
+        )
+    );
+
+DROP POLICY IF EXISTS "ai_documents_update_via_kb" ON ai.documents;
+CREATE POLICY "ai_documents_update_via_kb" ON ai.documents
+    FOR UPDATE
+    TO authenticated
+    USING (
+        EXISTS (
+            SELECT 1 FROM ai.knowledge_bases kb
+            WHERE kb.id = ai.documents.knowledge_base_id
+            AND kb.owner_id = auth.current_user_id()
+        )
+        OR EXISTS (
+            SELECT 1 FROM ai.knowledge_bases kb
+            JOIN ai.knowledge_base_permissions kbp ON kb.id = kbp.knowledge_base_id
+            WHERE kb.id = ai.documents.knowledge_base_id
+            AND kbp.user_id = auth.current_user_id()
+            AND kbp.permission IN ('editor', 'owner')
+        )
+    )
+    WITH CHECK (
+        EXISTS (
+            SELECT 1 FROM ai.knowledge_bases kb
+            WHERE kb.id = ai.documents.knowledge_base_id
+            AND kb.owner_id = auth.current_user_id()
+        )
+        OR EXISTS (
+            SELECT 1 FROM ai.knowledge_bases kb
+            JOIN ai.knowledge_base_permissions kbp ON kb.id = kbp.knowledge_base_id
+            WHERE kb.id = ai.documents.knowledge_base_id
+            AND kbp.user_id = auth.current_user_id()
+            AND kbp.permission IN ('editor', 'owner')
+        )
+    );
+
+DROP POLICY IF EXISTS "ai_documents_delete_via_kb" ON ai.documents;
+CREATE POLICY "ai_documents_delete_via_kb" ON ai.documents
+    FOR DELETE
+    TO authenticated
+    USING (
+        EXISTS (
+            SELECT 1 FROM ai.knowledge_bases kb
+            WHERE kb.id = ai.documents.knowledge_base_id
+            AND kb.owner_id = auth.current_user_id()
+        )
+        OR EXISTS (
+            SELECT 1 FROM ai.knowledge_bases kb
+            JOIN ai.knowledge_base_permissions kbp ON kb.id = kbp.knowledge_base_id
+            WHERE kb.id = ai.documents.knowledge_base_id
+            AND kbp.user_id = auth.current_user_id()
+            AND kbp.permission IN ('editor', 'owner')
+        )
+    );
+
+DROP POLICY IF EXISTS "ai_documents_read_shared" ON ai.documents;
+CREATE POLICY "ai_documents_read_shared" ON ai.documents FOR SELECT TO authenticated
+USING (
+    EXISTS (
+        SELECT 1 FROM ai.document_permissions dp
+        WHERE dp.document_id = documents.id
+        AND dp.user_id = auth.uid()
+    )
+);
+
+DROP POLICY IF EXISTS "ai_documents_update_shared" ON ai.documents;
+CREATE POLICY "ai_documents_update_shared" ON ai.documents FOR UPDATE TO authenticated
+USING (
+    EXISTS (
+        SELECT 1 FROM ai.document_permissions dp
+        WHERE dp.document_id = documents.id
+        AND dp.user_id = auth.uid()
+        AND dp.permission = 'editor'
+    )
+);
+
+DROP POLICY IF EXISTS "ai_chunks_read_shared_docs" ON ai.chunks;
+CREATE POLICY "ai_chunks_read_shared_docs" ON ai.chunks FOR SELECT TO authenticated
+USING (
+    EXISTS (
+        SELECT 1 FROM ai.documents d
+        JOIN ai.document_permissions dp ON dp.document_id = d.id
+        WHERE d.id = chunks.document_id
+        AND dp.user_id = auth.uid()
+    )
+);
+
+DROP POLICY IF EXISTS "ai_doc_perms_user_read" ON ai.document_permissions;
+CREATE POLICY "ai_doc_perms_user_read" ON ai.document_permissions FOR SELECT TO authenticated
+USING (user_id = auth.uid());
+
+-- ============================================================================
+-- RESTORE USER-ONLY GRANT RESOLUTION
+-- ============================================================================
+
+CREATE OR REPLACE FUNCTION ai.can_access_document(p_document_id UUID, p_user_id UUID)
+RETURNS BOOLEAN AS $$
+BEGIN
+    -- User owns the document
+    IF EXISTS (
+        SELECT 1 FROM ai.documents
+        WHERE id = p_document_id
+        AND owner_id = p_user_id
+    ) THEN
+        RETURN true;
+    END IF;
+
+    -- Document is shared with user
+    IF EXISTS (
+        SELECT 1 FROM ai.document_permissions
+        WHERE document_id = p_document_id
+        AND user_id = p_user_id
+    ) THEN
+        RETURN true;
+    END IF;
+
+    RETURN false;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+DROP FUNCTION IF EXISTS ai.has_document_grant(UUID, UUID, TEXT[]);
+DROP FUNCTION IF EXISTS ai.has_kb_grant(UUID, UUID, TEXT[]);
+
+-- ============================================================================
+-- DROP GROUP GRANTS
+-- ============================================================================
+
+DELETE FROM ai.document_permissions WHERE group_id IS NOT NULL;
+DROP INDEX IF EXISTS ai.idx_ai_document_permissions_group;
+ALTER TABLE ai.document_permissions DROP CONSTRAINT IF EXISTS unique_document_group_permission;
+ALTER TABLE ai.document_permissions DROP CONSTRAINT IF EXISTS document_permission_grantee;
+ALTER TABLE ai.document_permissions DROP COLUMN IF EXISTS group_id;
+ALTER TABLE ai.document_permissions ALTER COLUMN user_id SET NOT NULL;
+
+DELETE FROM ai.knowledge_base_permissions WHERE group_id IS NOT NULL;
+DROP INDEX IF EXISTS ai.idx_ai_kb_permissions_group;
+ALTER TABLE ai.knowledge_base_permissions DROP CONSTRAINT IF EXISTS unique_kb_group_permission;
+ALTER TABLE ai.knowledge_base_permissions DROP CONSTRAINT IF EXISTS kb_permission_grantee;
+ALTER TABLE ai.knowledge_base_permissions DROP COLUMN IF EXISTS group_id;
+ALTER TABLE ai.knowledge_base_permissions ALTER COLUMN user_id SET NOT NULL;
+
+-- ============================================================================
+-- DROP GROUPS
+-- ============================================================================
+
+DROP TABLE IF EXISTS auth.group_members;
+DROP TABLE IF EXISTS auth.groups;
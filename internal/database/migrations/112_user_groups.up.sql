@@ -0,0 +1,276 @@
+-- User Groups
+-- Groups of users that knowledge base and document permissions can be granted to.
+-- Group grants are resolved at access-check time, so adding a user to a group gives
+-- them access to everything granted to the group without touching each resource.
+
+-- ============================================================================
+-- GROUPS
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS auth.groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL UNIQUE,
+    description TEXT,
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS auth.group_members (
+    group_id UUID NOT NULL REFERENCES auth.groups(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    added_by UUID,
+    added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_group_members_user ON auth.group_members(user_id);
+
+ALTER TABLE auth.groups ENABLE ROW LEVEL SECURITY;
+ALTER TABLE auth.group_members ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY groups_admin ON auth.groups
+    FOR ALL
+    USING (
+        auth.is_admin()
+        OR auth.current_user_role() = 'dashboard_admin'
+        OR auth.current_user_role() = 'service_role'
+    );
+
+CREATE POLICY group_members_admin ON auth.group_members
+    FOR ALL
+    USING (
+        auth.is_admin()
+        OR auth.current_user_role() = 'dashboard_admin'
+        OR auth.current_user_role() = 'service_role'
+    );
+
+-- Users can see their own memberships
+CREATE POLICY group_members_read_own ON auth.group_members
+    FOR SELECT
+    USING (user_id = auth.current_user_id());
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON auth.groups TO service_role;
+GRANT SELECT, INSERT, UPDATE, DELETE ON auth.group_members TO service_role;
+GRANT SELECT ON auth.group_members TO authenticated;
+
+COMMENT ON TABLE auth.groups IS 'Groups of users that knowledge base and document permissions can be granted to';
+COMMENT ON TABLE auth.group_members IS 'Users that belong to a group';
+
+-- ============================================================================
+-- GROUP GRANTS
+-- ============================================================================
+
+-- Knowledge base permissions are granted to either a user or a group
+ALTER TABLE ai.knowledge_base_permissions
+    ADD COLUMN IF NOT EXISTS group_id UUID REFERENCES auth.groups(id) ON DELETE CASCADE;
+ALTER TABLE ai.knowledge_base_permissions ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE ai.knowledge_base_permissions
+    ADD CONSTRAINT kb_permission_grantee CHECK ((user_id IS NULL) <> (group_id IS NULL));
+ALTER TABLE ai.knowledge_base_permissions
+    ADD CONSTRAINT unique_kb_group_permission UNIQUE (knowledge_base_id, group_id);
+
+CREATE INDEX IF NOT EXISTS idx_ai_kb_permissions_group
+    ON ai.knowledge_base_permissions(group_id) WHERE group_id IS NOT NULL;
+
+COMMENT ON COLUMN ai.knowledge_base_permissions.group_id IS 'Group the permission is granted to, NULL for user grants';
+
+-- Document permissions are granted to either a user or a group
+ALTER TABLE ai.document_permissions
+    ADD COLUMN IF NOT EXISTS group_id UUID REFERENCES auth.groups(id) ON DELETE CASCADE;
+ALTER TABLE ai.document_permissions ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE ai.document_permissions
+    ADD CONSTRAINT document_permission_grantee CHECK ((user_id IS NULL) <> (group_id IS NULL));
+ALTER TABLE ai.document_permissions
+    ADD CONSTRAINT unique_document_group_permission UNIQUE (document_id, group_id);
+
+CREATE INDEX IF NOT EXISTS idx_ai_document_permissions_group
+    ON ai.document_permissions(group_id) WHERE group_id IS NOT NULL;
+
+COMMENT ON COLUMN ai.document_permissions.group_id IS 'Group the permission is granted to, NULL for user grants';
+
+-- ============================================================================
+-- GRANT RESOLUTION
+-- ============================================================================
+
+-- SECURITY DEFINER so that policies can resolve memberships without reading
+-- auth.group_members through its own RLS policies
+CREATE OR REPLACE FUNCTION ai.has_kb_grant(p_kb_id UUID, p_user_id UUID, p_permissions TEXT[])
+RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM ai.knowledge_base_permissions kbp
+        WHERE kbp.knowledge_base_id = p_kb_id
+        AND kbp.permission = ANY(p_permissions)
+        AND (
+            kbp.user_id = p_user_id
+            OR kbp.group_id IN (SELECT gm.group_id FROM auth.group_members gm WHERE gm.user_id = p_user_id)
+        )
+    );
+$$ LANGUAGE sql STABLE SECURITY DEFINER;
+
+COMMENT ON FUNCTION ai.has_kb_grant IS 'Check if a user has one of the permissions on a knowledge base, directly or through a group';
+
+CREATE OR REPLACE FUNCTION ai.has_document_grant(p_document_id UUID, p_user_id UUID, p_permissions TEXT[])
+RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM ai.document_permissions dp
+        WHERE dp.document_id = p_document_id
+        AND dp.permission = ANY(p_permissions)
+        AND (
+            dp.user_id = p_user_id
+            OR dp.group_id IN (SELECT gm.group_id FROM auth.group_members gm WHERE gm.user_id = p_user_id)
+        )
+    );
+$$ LANGUAGE sql STABLE SECURITY DEFINER;
+
+COMMENT ON FUNCTION ai.has_document_grant IS 'Check if a user has one of the permissions on a document, directly or through a group';
+
+CREATE OR REPLACE FUNCTION ai.can_access_document(p_document_id UUID, p_user_id UUID)
+RETURNS BOOLEAN AS $$
+BEGIN
+    -- User owns the document
+    IF EXISTS (
+        SELECT 1 FROM ai.documents
+        WHERE id = p_document_id
+        AND owner_id = p_user_id
+    ) THEN
+        RETURN true;
+    END IF;
+
+    -- Document is shared with user or one of their groups
+    RETURN ai.has_document_grant(p_document_id, p_user_id, ARRAY['viewer', 'editor']);
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+-- ============================================================================
+-- KNOWLEDGE BASE POLICIES
+-- ============================================================================
+
+DROP POLICY IF EXISTS "ai_kb_manage_own" ON ai.knowledge_bases;
+CREATE POLICY "ai_kb_manage_own" ON ai.knowledge_bases
+    FOR ALL
+    TO authenticated
+    USING (
+        owner_id = auth.current_user_id()
+        OR ai.has_kb_grant(id, auth.current_user_id(), ARRAY['editor', 'owner'])
+    )
+    WITH CHECK (
+        owner_id = auth.current_user_id()
+        OR ai.has_kb_grant(id, auth.current_user_id(), ARRAY['editor', 'owner'])
+    );
+
+DROP POLICY IF EXISTS "ai_kb_read_shared" ON ai.knowledge_bases;
+CREATE POLICY "ai_kb_read_shared" ON ai.knowledge_bases
+    FOR SELECT
+    TO authenticated
+    USING (
+        visibility = 'shared'
+        AND ai.has_kb_grant(id, auth.current_user_id(), ARRAY['viewer', 'editor', 'owner'])
+    );
+
+-- ============================================================================
+-- DOCUMENT POLICIES
+-- ============================================================================
+
+DROP POLICY IF EXISTS "ai_documents_read_via_kb" ON ai.documents;
+CREATE POLICY "ai_documents_read_via_kb" ON ai.documents
+    FOR SELECT
+    TO authenticated
+    USING (
+        EXISTS (
+            SELECT 1 FROM ai.knowledge_bases kb
+            WHERE kb.id = ai.documents.knowledge_base_id
+            AND (
+                kb.owner_id = auth.current_user_id()
+                OR kb.visibility = 'public'
+                OR ai.has_kb_grant(kb.id, auth.current_user_id(), ARRAY['viewer', 'editor', 'owner'])
+            )
+        )
+    );
+
+DROP POLICY IF EXISTS "ai_documents_insert_via_kb" ON ai.documents;
+CREATE POLICY "ai_documents_insert_via_kb" ON ai.documents
+    FOR INSERT
+    TO authenticated
+    WITH CHECK (
+        EXISTS (
+            SELECT 1 FROM ai.knowledge_bases kb
+            WHERE kb.id = ai.documents.knowledge_base_id
+            AND (
+                kb.owner_id = auth.current_user_id()
+                OR ai.has_kb_grant(kb.id, auth.current_user_id(), ARRAY['editor', 'owner'])
+            )
+        )
+    );
+
+DROP POLICY IF EXISTS "ai_documents_update_via_kb" ON ai.documents;
+CREATE POLICY "ai_documents_update_via_kb" ON ai.documents
+    FOR UPDATE
+    TO authenticated
+    USING (
+        EXISTS (
+            SELECT 1 FROM ai.knowledge_bases kb
+            WHERE kb.id = ai.documents.knowledge_base_id
+            AND (
+                kb.owner_id = auth.current_user_id()
+                OR ai.has_kb_grant(kb.id, auth.current_user_id(), ARRAY['editor', 'owner'])
+            )
+        )
+    )
+    WITH CHECK (
+        EXISTS (
+            SELECT 1 FROM ai.knowledge_bases kb
+            WHERE kb.id = ai.documents.knowledge_base_id
+            AND (
+                kb.owner_id = auth.current_user_id()
+                OR ai.has_kb_grant(kb.id, auth.current_user_id(), ARRAY['editor', 'owner'])
+            )
+        )
+    );
+
+DROP POLICY IF EXISTS "ai_documents_delete_via_kb" ON ai.documents;
+CREATE POLICY "ai_documents_delete_via_kb" ON ai.documents
+    FOR DELETE
+    TO authenticated
+    USING (
+        EXISTS (
+            SELECT 1 FROM ai.knowledge_bases kb
+            WHERE kb.id = ai.documents.knowledge_base_id
+            AND (
+                kb.owner_id = auth.current_user_id()
+                OR ai.has_kb_grant(kb.id, auth.current_user_id(), ARRAY['editor', 'owner'])
+            )
+        )
+    );
+
+DROP POLICY IF EXISTS "ai_documents_read_shared" ON ai.documents;
+CREATE POLICY "ai_documents_read_shared" ON ai.documents
+    FOR SELECT
+    TO authenticated
+    USING (ai.has_document_grant(id, auth.uid(), ARRAY['viewer', 'editor']));
+
+DROP POLICY IF EXISTS "ai_documents_update_shared" ON ai.documents;
+CREATE POLICY "ai_documents_update_shared" ON ai.documents
+    FOR UPDATE
+    TO authenticated
+    USING (ai.has_document_grant(id, auth.uid(), ARRAY['editor']));
+
+-- ============================================================================
+-- CHUNK POLICIES
+-- ============================================================================
+
+DROP POLICY IF EXISTS "ai_chunks_read_shared_docs" ON ai.chunks;
+CREATE POLICY "ai_chunks_read_shared_docs" ON ai.chunks
+    FOR SELECT
+    TO authenticated
+    USING (ai.has_document_grant(document_id, auth.uid(), ARRAY['viewer', 'editor']));
+
+-- Users can read the group grants of groups they belong to
+DROP POLICY IF EXISTS "ai_doc_perms_user_read" ON ai.document_permissions;
+CREATE POLICY "ai_doc_perms_user_read" ON ai.document_permissions
+    FOR SELECT
+    TO authenticated
+    USING (
+        user_id = auth.uid()
+        OR group_id IN (SELECT gm.group_id FROM auth.group_members gm WHERE gm.user_id = auth.uid())
+    );
@@ -0,0 +1,11 @@
+-- Rollback storage download settings
+
+DROP FUNCTION IF EXISTS storage.get_bucket_download_settings(TEXT);
+
+ALTER TABLE storage.buckets DROP CONSTRAINT IF EXISTS buckets_html_policy_check;
+ALTER TABLE storage.buckets DROP CONSTRAINT IF EXISTS buckets_content_disposition_check;
+
+ALTER TABLE storage.buckets DROP COLUMN IF EXISTS html_policy;
+ALTER TABLE storage.buckets DROP COLUMN IF EXISTS nosniff;
+ALTER TABLE storage.buckets DROP COLUMN IF EXISTS inline_mime_types;
+ALTER TABLE storage.buckets DROP COLUMN IF EXISTS content_disposition;
@@ -0,0 +1,48 @@
+-- Storage download settings
+-- Per-bucket control over how downloads are presented to browsers: the default
+-- Content-Disposition, which MIME types may be displayed inline, MIME sniffing
+-- protection, and how user-uploaded HTML, SVG and XML is served.
+
+ALTER TABLE storage.buckets ADD COLUMN IF NOT EXISTS content_disposition TEXT NOT NULL DEFAULT 'attachment';
+ALTER TABLE storage.buckets ADD COLUMN IF NOT EXISTS inline_mime_types TEXT[];
+ALTER TABLE storage.buckets ADD COLUMN IF NOT EXISTS nosniff BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE storage.buckets ADD COLUMN IF NOT EXISTS html_policy TEXT NOT NULL DEFAULT 'attachment';
+
+ALTER TABLE storage.buckets DROP CONSTRAINT IF EXISTS buckets_content_disposition_check;
+ALTER TABLE storage.buckets ADD CONSTRAINT buckets_content_disposition_check
+    CHECK (content_disposition IN ('attachment', 'inline'));
+
+ALTER TABLE storage.buckets DROP CONSTRAINT IF EXISTS buckets_html_policy_check;
+ALTER TABLE storage.buckets ADD CONSTRAINT buckets_html_policy_check
+    CHECK (html_policy IN ('attachment', 'text', 'sandbox'));
+
+COMMENT ON COLUMN storage.buckets.content_disposition IS 'Default Content-Disposition for downloads: attachment, or inline for MIME types in inline_mime_types';
+COMMENT ON COLUMN storage.buckets.inline_mime_types IS 'MIME types that may be displayed inline, supports wildcards like image/* (NULL uses the built-in safe list)';
+COMMENT ON COLUMN storage.buckets.nosniff IS 'Send X-Content-Type-Options: nosniff with downloads';
+COMMENT ON COLUMN storage.buckets.html_policy IS 'How HTML, SVG and XML objects are served: attachment (forced download), text (as text/plain), or sandbox (inline under a CSP sandbox)';
+
+-- SECURITY DEFINER function to get bucket download settings
+-- This bypasses RLS so signed URL downloads, which carry no user context, can apply them
+CREATE OR REPLACE FUNCTION storage.get_bucket_download_settings(bucket_name TEXT)
+RETURNS TABLE (
+    content_disposition TEXT,
+    inline_mime_types TEXT[],
+    nosniff BOOLEAN,
+    html_policy TEXT
+)
+LANGUAGE plpgsql
+STABLE
+SECURITY DEFINER
+SET search_path = public, storage
+AS $$
+BEGIN
+    RETURN QUERY
+    SELECT b.content_disposition, b.inline_mime_types, b.nosniff, b.html_policy
+    FROM storage.buckets b
+    WHERE b.name = bucket_name;
+END;
+$$;
+
+COMMENT ON FUNCTION storage.get_bucket_download_settings(TEXT) IS 'SECURITY DEFINER function to get bucket download settings, bypassing RLS. Used by storage handler to set download headers.';
+
+GRANT EXECUTE ON FUNCTION storage.get_bucket_download_settings(TEXT) TO anon, authenticated, service_role;
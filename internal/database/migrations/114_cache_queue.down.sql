@@ -0,0 +1,4 @@
+-- Rollback shared cache and queue
+
+DROP TABLE IF EXISTS system.queue_messages;
+DROP TABLE IF EXISTS system.cache_entries;
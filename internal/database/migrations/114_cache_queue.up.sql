@@ -0,0 +1,30 @@
+-- Shared cache and queue
+-- Storage for the "postgres" cache and queue backends, used when scaling.cache_backend or
+-- scaling.queue_backend (or scaling.backend) is "postgres". Both tables hold ephemeral data
+-- that can be rebuilt, so they are UNLOGGED to avoid WAL writes.
+
+CREATE UNLOGGED TABLE IF NOT EXISTS system.cache_entries (
+    key TEXT PRIMARY KEY,
+    value BYTEA NOT NULL,
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cache_entries_expires_at
+ON system.cache_entries (expires_at) WHERE expires_at IS NOT NULL;
+
+COMMENT ON TABLE system.cache_entries IS 'Shared key/value cache for multi-instance deployments';
+COMMENT ON COLUMN system.cache_entries.expires_at IS 'When the entry expires (NULL never expires)';
+
+CREATE UNLOGGED TABLE IF NOT EXISTS system.queue_messages (
+    id BIGSERIAL PRIMARY KEY,
+    queue TEXT NOT NULL,
+    payload BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_queue_messages_queue_id
+ON system.queue_messages (queue, id);
+
+COMMENT ON TABLE system.queue_messages IS 'Shared FIFO message queues for multi-instance deployments';
+COMMENT ON COLUMN system.queue_messages.queue IS 'Queue name (e.g., "jobs")';
@@ -0,0 +1,14 @@
+-- Drop knowledge base vector quantization
+DROP TRIGGER IF EXISTS knowledge_bases_requantize_chunks ON ai.knowledge_bases;
+DROP FUNCTION IF EXISTS ai.requantize_knowledge_base_chunks();
+DROP TRIGGER IF EXISTS chunks_quantize_embedding ON ai.chunks;
+DROP FUNCTION IF EXISTS ai.quantize_chunk_embedding();
+
+DROP INDEX IF EXISTS ai.idx_ai_chunks_embedding_binary;
+DROP INDEX IF EXISTS ai.idx_ai_chunks_embedding_half;
+
+ALTER TABLE ai.chunks DROP COLUMN IF EXISTS embedding_binary;
+ALTER TABLE ai.chunks DROP COLUMN IF EXISTS embedding_half;
+
+ALTER TABLE ai.knowledge_bases DROP COLUMN IF EXISTS rerank_candidates;
+ALTER TABLE ai.knowledge_bases DROP COLUMN IF EXISTS vector_storage;
@@ -0,0 +1,72 @@
+-- Knowledge base vector quantization
+-- Knowledge bases can keep a half-precision (halfvec) or binary quantized copy of each chunk
+-- embedding in a compact HNSW index. Semantic search takes the nearest candidates from that index
+-- and reranks them on the full vectors, which stay the source of truth for every other search
+-- path. Requires pgvector 0.7+.
+
+ALTER TABLE ai.knowledge_bases ADD COLUMN IF NOT EXISTS vector_storage TEXT NOT NULL DEFAULT 'vector'
+    CHECK (vector_storage IN ('vector', 'halfvec', 'binary'));
+ALTER TABLE ai.knowledge_bases ADD COLUMN IF NOT EXISTS rerank_candidates INTEGER NOT NULL DEFAULT 100
+    CHECK (rerank_candidates > 0 AND rerank_candidates <= 1000);
+
+COMMENT ON COLUMN ai.knowledge_bases.vector_storage IS 'Index used for semantic search: vector (full precision), halfvec or binary (quantized, reranked on full vectors)';
+COMMENT ON COLUMN ai.knowledge_bases.rerank_candidates IS 'Candidates taken from the quantized index and reranked on full vectors per search';
+
+ALTER TABLE ai.chunks ADD COLUMN IF NOT EXISTS embedding_half halfvec(1536);
+ALTER TABLE ai.chunks ADD COLUMN IF NOT EXISTS embedding_binary bit(1536);
+
+COMMENT ON COLUMN ai.chunks.embedding_half IS 'Half-precision copy of embedding, set for knowledge bases with halfvec storage';
+COMMENT ON COLUMN ai.chunks.embedding_binary IS 'Binary quantized copy of embedding, set for knowledge bases with binary storage';
+
+-- Partial indexes only contain chunks of knowledge bases that opted in
+CREATE INDEX IF NOT EXISTS idx_ai_chunks_embedding_half ON ai.chunks
+    USING hnsw (embedding_half halfvec_cosine_ops) WHERE embedding_half IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_ai_chunks_embedding_binary ON ai.chunks
+    USING hnsw (embedding_binary bit_hamming_ops) WHERE embedding_binary IS NOT NULL;
+
+-- Keep the quantized copies in sync with the embedding and the knowledge base's storage
+CREATE OR REPLACE FUNCTION ai.quantize_chunk_embedding()
+RETURNS TRIGGER AS $$
+DECLARE
+    storage TEXT;
+BEGIN
+    SELECT vector_storage INTO storage
+    FROM ai.knowledge_bases
+    WHERE id = NEW.knowledge_base_id;
+
+    NEW.embedding_half := CASE
+        WHEN storage = 'halfvec' AND NEW.embedding IS NOT NULL THEN NEW.embedding::halfvec(1536)
+    END;
+    NEW.embedding_binary := CASE
+        WHEN storage = 'binary' AND NEW.embedding IS NOT NULL THEN binary_quantize(NEW.embedding)::bit(1536)
+    END;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMENT ON FUNCTION ai.quantize_chunk_embedding() IS 'Trigger function that maintains the quantized embedding copies of a chunk';
+
+DROP TRIGGER IF EXISTS chunks_quantize_embedding ON ai.chunks;
+CREATE TRIGGER chunks_quantize_embedding
+BEFORE INSERT OR UPDATE OF embedding ON ai.chunks
+FOR EACH ROW
+EXECUTE FUNCTION ai.quantize_chunk_embedding();
+
+-- Rebuild the quantized copies when a knowledge base switches storage
+CREATE OR REPLACE FUNCTION ai.requantize_knowledge_base_chunks()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE ai.chunks SET embedding = embedding WHERE knowledge_base_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMENT ON FUNCTION ai.requantize_knowledge_base_chunks() IS 'Trigger function that rebuilds quantized embeddings after a knowledge base changes vector storage';
+
+DROP TRIGGER IF EXISTS knowledge_bases_requantize_chunks ON ai.knowledge_bases;
+CREATE TRIGGER knowledge_bases_requantize_chunks
+AFTER UPDATE OF vector_storage ON ai.knowledge_bases
+FOR EACH ROW
+WHEN (NEW.vector_storage IS DISTINCT FROM OLD.vector_storage)
+EXECUTE FUNCTION ai.requantize_knowledge_base_chunks();
@@ -0,0 +1,2 @@
+-- Drop knowledge base re-index jobs
+DROP TABLE IF EXISTS ai.knowledge_base_reindex_jobs;
@@ -0,0 +1,41 @@
+-- Knowledge base re-index jobs
+-- Re-chunks and re-embeds every document of a knowledge base in the background, e.g. after
+-- changing chunk_size, chunk_overlap or the embedding model. One job per knowledge base can be
+-- active at a time; documents are marked indexed as the job reaches them.
+CREATE TABLE ai.knowledge_base_reindex_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    knowledge_base_id UUID NOT NULL REFERENCES ai.knowledge_bases(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    total_documents INTEGER NOT NULL DEFAULT 0,
+    processed_documents INTEGER NOT NULL DEFAULT 0,
+    failed_documents INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    created_by UUID, -- auth or dashboard user, so no foreign key
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ai_kb_reindex_jobs_kb_created ON ai.knowledge_base_reindex_jobs(knowledge_base_id, created_at DESC);
+CREATE UNIQUE INDEX idx_ai_kb_reindex_jobs_active ON ai.knowledge_base_reindex_jobs(knowledge_base_id)
+    WHERE status IN ('pending', 'running');
+
+COMMENT ON TABLE ai.knowledge_base_reindex_jobs IS 'Background jobs that re-chunk and re-embed all documents of a knowledge base';
+COMMENT ON COLUMN ai.knowledge_base_reindex_jobs.processed_documents IS 'Documents the job has reached, including failed ones';
+COMMENT ON COLUMN ai.knowledge_base_reindex_jobs.updated_at IS 'Last progress update; active jobs that stop updating are considered interrupted';
+
+-- RLS policies
+ALTER TABLE ai.knowledge_base_reindex_jobs ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "ai_kb_reindex_jobs_dashboard_admin" ON ai.knowledge_base_reindex_jobs
+    FOR ALL TO authenticated
+    USING (auth.role() = 'dashboard_admin')
+    WITH CHECK (auth.role() = 'dashboard_admin');
+
+CREATE POLICY "ai_kb_reindex_jobs_service_role" ON ai.knowledge_base_reindex_jobs
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON ai.knowledge_base_reindex_jobs TO service_role;
@@ -0,0 +1,6 @@
+-- Drop conversation metadata and tags
+DROP INDEX IF EXISTS ai.idx_ai_conversations_tags;
+DROP INDEX IF EXISTS ai.idx_ai_conversations_metadata;
+
+ALTER TABLE ai.conversations DROP COLUMN IF EXISTS tags;
+ALTER TABLE ai.conversations DROP COLUMN IF EXISTS metadata;
@@ -0,0 +1,12 @@
+-- Conversation metadata and tags
+-- Arbitrary business dimensions (channel, customer id, campaign, ...) set when a conversation
+-- starts, used to filter conversation lists and slice chatbot analytics.
+
+ALTER TABLE ai.conversations ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}'::jsonb;
+ALTER TABLE ai.conversations ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN ai.conversations.metadata IS 'String key/value pairs describing the conversation, e.g. channel or customer_id';
+COMMENT ON COLUMN ai.conversations.tags IS 'Labels used to filter and group conversations';
+
+CREATE INDEX IF NOT EXISTS idx_ai_conversations_metadata ON ai.conversations USING GIN (metadata jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_ai_conversations_tags ON ai.conversations USING GIN (tags);
@@ -0,0 +1,3 @@
+-- Drop the freshness boost per chatbot-knowledge base link
+ALTER TABLE ai.chatbot_knowledge_bases DROP CONSTRAINT IF EXISTS chatbot_knowledge_bases_freshness_half_life_days_check;
+ALTER TABLE ai.chatbot_knowledge_bases DROP COLUMN IF EXISTS freshness_half_life_days;
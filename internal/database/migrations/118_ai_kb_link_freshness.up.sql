@@ -0,0 +1,12 @@
+-- Freshness boost per chatbot-knowledge base link
+-- Hybrid search decays part of each chunk's score by the age of its document (updated_at),
+-- halving it every freshness_half_life_days, so newer documents outrank stale ones on the
+-- same topic. NULL disables the boost.
+
+ALTER TABLE ai.chatbot_knowledge_bases ADD COLUMN IF NOT EXISTS freshness_half_life_days FLOAT;
+
+ALTER TABLE ai.chatbot_knowledge_bases DROP CONSTRAINT IF EXISTS chatbot_knowledge_bases_freshness_half_life_days_check;
+ALTER TABLE ai.chatbot_knowledge_bases ADD CONSTRAINT chatbot_knowledge_bases_freshness_half_life_days_check
+    CHECK (freshness_half_life_days IS NULL OR freshness_half_life_days > 0);
+
+COMMENT ON COLUMN ai.chatbot_knowledge_bases.freshness_half_life_days IS 'Days after which the recency boost of a document halves, NULL = no boost';
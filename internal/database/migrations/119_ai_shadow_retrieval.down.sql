@@ -0,0 +1,3 @@
+-- Drop shadow retrieval profiles and runs
+DROP TABLE IF EXISTS ai.shadow_retrieval_runs;
+DROP TABLE IF EXISTS ai.chatbot_shadow_retrieval_profiles;
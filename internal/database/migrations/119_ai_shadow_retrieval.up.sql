@@ -0,0 +1,78 @@
+-- Shadow retrieval profiles
+-- A chatbot can have a second ("shadow") retrieval configuration that runs alongside the live one
+-- on a sample of chat turns. Both result sets, and optionally the answer the shadow context would
+-- have produced, are logged for comparison; users only ever see the live answer.
+CREATE TABLE ai.chatbot_shadow_retrieval_profiles (
+    chatbot_id UUID PRIMARY KEY REFERENCES ai.chatbots(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    sample_rate FLOAT NOT NULL DEFAULT 0.1 CHECK (sample_rate >= 0 AND sample_rate <= 1),
+    mode TEXT NOT NULL DEFAULT 'hybrid' CHECK (mode IN ('semantic', 'keyword', 'hybrid')),
+    max_chunks INTEGER NOT NULL DEFAULT 5 CHECK (max_chunks > 0),
+    similarity_threshold FLOAT NOT NULL DEFAULT 0.7,
+    semantic_weight FLOAT NOT NULL DEFAULT 0,
+    keyword_boost FLOAT NOT NULL DEFAULT 0,
+    freshness_half_life_days FLOAT,
+    compare_answers BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE ai.chatbot_shadow_retrieval_profiles IS 'Retrieval configurations evaluated alongside the live one without affecting users';
+COMMENT ON COLUMN ai.chatbot_shadow_retrieval_profiles.sample_rate IS 'Fraction of chat turns with RAG context that also run the shadow profile';
+COMMENT ON COLUMN ai.chatbot_shadow_retrieval_profiles.compare_answers IS 'Also generate the answer for the shadow context and compare it to the live answer';
+
+-- Shadow retrieval runs: one row per sampled chat turn
+CREATE TABLE ai.shadow_retrieval_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    chatbot_id UUID NOT NULL REFERENCES ai.chatbots(id) ON DELETE CASCADE,
+    conversation_id UUID, -- conversations are not always persisted, so no foreign key
+    query_text TEXT NOT NULL,
+    profile JSONB NOT NULL,
+    live_chunk_ids UUID[] NOT NULL DEFAULT '{}',
+    live_scores FLOAT[] NOT NULL DEFAULT '{}',
+    shadow_chunk_ids UUID[] NOT NULL DEFAULT '{}',
+    shadow_scores FLOAT[] NOT NULL DEFAULT '{}',
+    overlap FLOAT,
+    live_answer TEXT,
+    shadow_answer TEXT,
+    answer_similarity FLOAT,
+    shadow_prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    shadow_completion_tokens INTEGER NOT NULL DEFAULT 0,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ai_shadow_retrieval_runs_chatbot_created ON ai.shadow_retrieval_runs(chatbot_id, created_at DESC);
+
+COMMENT ON TABLE ai.shadow_retrieval_runs IS 'Live and shadow retrieval results of sampled chat turns';
+COMMENT ON COLUMN ai.shadow_retrieval_runs.profile IS 'The shadow profile the run used';
+COMMENT ON COLUMN ai.shadow_retrieval_runs.overlap IS 'Fraction of chunks retrieved by both the live and the shadow profile';
+COMMENT ON COLUMN ai.shadow_retrieval_runs.answer_similarity IS 'Word-level similarity of the live and shadow answers (0-1)';
+
+-- RLS policies
+ALTER TABLE ai.chatbot_shadow_retrieval_profiles ENABLE ROW LEVEL SECURITY;
+ALTER TABLE ai.shadow_retrieval_runs ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "ai_shadow_retrieval_profiles_dashboard_admin" ON ai.chatbot_shadow_retrieval_profiles
+    FOR ALL TO authenticated
+    USING (auth.role() = 'dashboard_admin')
+    WITH CHECK (auth.role() = 'dashboard_admin');
+
+CREATE POLICY "ai_shadow_retrieval_profiles_service_role" ON ai.chatbot_shadow_retrieval_profiles
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+CREATE POLICY "ai_shadow_retrieval_runs_dashboard_admin" ON ai.shadow_retrieval_runs
+    FOR ALL TO authenticated
+    USING (auth.role() = 'dashboard_admin')
+    WITH CHECK (auth.role() = 'dashboard_admin');
+
+CREATE POLICY "ai_shadow_retrieval_runs_service_role" ON ai.shadow_retrieval_runs
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON ai.chatbot_shadow_retrieval_profiles TO service_role;
+GRANT ALL ON ai.shadow_retrieval_runs TO service_role;
@@ -0,0 +1,2 @@
+-- Drop analytics export history and watermarks
+DROP TABLE IF EXISTS api.analytics_exports;
@@ -0,0 +1,43 @@
+-- Analytics warehouse exports
+-- One row per exported file. The latest completed row of a source holds its watermark
+-- (the cursor value and ID of the last exported row) and the schema the rows were exported with.
+CREATE TABLE IF NOT EXISTS api.analytics_exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- Exported table, e.g. 'retrieval_log' or 'conversations'
+    source TEXT NOT NULL,
+
+    status TEXT NOT NULL CHECK (status IN ('completed', 'failed')),
+    row_count INTEGER NOT NULL DEFAULT 0,
+
+    -- Object key of the gzipped JSON lines file
+    object_key TEXT,
+
+    -- Keyset watermark: the last exported row ordered by (cursor column, id)
+    watermark_at TIMESTAMPTZ,
+    watermark_id TEXT,
+
+    -- Columns of the table at export time, [{"name": ..., "type": ...}]; the version is
+    -- bumped whenever they change
+    schema_version INTEGER NOT NULL DEFAULT 1,
+    columns JSONB NOT NULL DEFAULT '[]'::jsonb,
+
+    error_message TEXT,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_analytics_exports_source_created
+    ON api.analytics_exports(source, created_at DESC);
+
+COMMENT ON TABLE api.analytics_exports IS 'History and watermarks of analytics warehouse exports';
+
+-- RLS policies (the api schema is only reachable by service_role, see migration 076)
+ALTER TABLE api.analytics_exports ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "api_analytics_exports_service_role" ON api.analytics_exports
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON api.analytics_exports TO service_role;
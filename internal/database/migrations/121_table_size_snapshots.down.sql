@@ -0,0 +1,2 @@
+-- Drop daily table size snapshots
+DROP TABLE IF EXISTS api.table_size_snapshots;
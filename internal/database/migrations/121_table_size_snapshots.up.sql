@@ -0,0 +1,36 @@
+-- Daily table size snapshots
+-- One row per user table and day, collected by the table stats collector so the dashboard
+-- can show how fast each table grows.
+CREATE TABLE IF NOT EXISTS api.table_size_snapshots (
+    snapshot_date DATE NOT NULL,
+    schema_name TEXT NOT NULL,
+    table_name TEXT NOT NULL,
+
+    -- Planner estimate (pg_class.reltuples), not an exact count
+    row_estimate BIGINT NOT NULL DEFAULT 0,
+    dead_rows BIGINT NOT NULL DEFAULT 0,
+
+    -- Heap (including TOAST), index and total size in bytes
+    table_bytes BIGINT NOT NULL DEFAULT 0,
+    index_bytes BIGINT NOT NULL DEFAULT 0,
+    total_bytes BIGINT NOT NULL DEFAULT 0,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (snapshot_date, schema_name, table_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_table_size_snapshots_table_date
+    ON api.table_size_snapshots(schema_name, table_name, snapshot_date DESC);
+
+COMMENT ON TABLE api.table_size_snapshots IS 'Daily row estimates and sizes of user tables for growth trends';
+
+-- RLS policies (the api schema is only reachable by service_role, see migration 076)
+ALTER TABLE api.table_size_snapshots ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "api_table_size_snapshots_service_role" ON api.table_size_snapshots
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON api.table_size_snapshots TO service_role;
@@ -0,0 +1,3 @@
+-- Drop table maintenance schedules and run history
+DROP TABLE IF EXISTS api.table_maintenance_runs;
+DROP TABLE IF EXISTS api.table_maintenance_schedules;
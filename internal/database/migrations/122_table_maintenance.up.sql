@@ -0,0 +1,76 @@
+-- Table maintenance (VACUUM / ANALYZE)
+-- Schedules run by the maintenance scheduler and the history of every manual and
+-- scheduled run, so operators can see how long maintenance of busy tables takes.
+CREATE TABLE IF NOT EXISTS api.table_maintenance_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    schema_name TEXT NOT NULL,
+    table_name TEXT NOT NULL,
+
+    -- vacuum, analyze or vacuum_analyze
+    operation TEXT NOT NULL CHECK (operation IN ('vacuum', 'analyze', 'vacuum_analyze')),
+
+    -- Cron expression, optionally prefixed with CRON_TZ=<zone> (UTC otherwise)
+    cron_schedule TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+
+    last_run_at TIMESTAMPTZ,
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (schema_name, table_name, operation)
+);
+
+COMMENT ON TABLE api.table_maintenance_schedules IS 'Off-peak VACUUM/ANALYZE schedules of user tables';
+
+CREATE TABLE IF NOT EXISTS api.table_maintenance_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    schedule_id UUID REFERENCES api.table_maintenance_schedules(id) ON DELETE SET NULL,
+    schema_name TEXT NOT NULL,
+    table_name TEXT NOT NULL,
+    operation TEXT NOT NULL CHECK (operation IN ('vacuum', 'analyze', 'vacuum_analyze')),
+
+    -- manual or scheduled
+    trigger TEXT NOT NULL CHECK (trigger IN ('manual', 'scheduled')),
+    status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'completed', 'failed')),
+    error TEXT,
+
+    -- Dead rows before and after the run, from pg_stat_user_tables
+    dead_rows_before BIGINT,
+    dead_rows_after BIGINT,
+
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ,
+    duration_ms BIGINT,
+    triggered_by UUID
+);
+
+-- At most one run per table at a time, across all instances
+CREATE UNIQUE INDEX IF NOT EXISTS idx_table_maintenance_runs_running
+    ON api.table_maintenance_runs(schema_name, table_name)
+    WHERE status = 'running';
+
+CREATE INDEX IF NOT EXISTS idx_table_maintenance_runs_table_started
+    ON api.table_maintenance_runs(schema_name, table_name, started_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_table_maintenance_runs_started
+    ON api.table_maintenance_runs(started_at DESC);
+
+COMMENT ON TABLE api.table_maintenance_runs IS 'History of manual and scheduled VACUUM/ANALYZE runs';
+
+-- RLS policies (the api schema is only reachable by service_role, see migration 076)
+ALTER TABLE api.table_maintenance_schedules ENABLE ROW LEVEL SECURITY;
+ALTER TABLE api.table_maintenance_runs ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "api_table_maintenance_schedules_service_role" ON api.table_maintenance_schedules
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+CREATE POLICY "api_table_maintenance_runs_service_role" ON api.table_maintenance_runs
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON api.table_maintenance_schedules TO service_role;
+GRANT ALL ON api.table_maintenance_runs TO service_role;
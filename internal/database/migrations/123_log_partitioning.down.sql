@@ -0,0 +1,105 @@
+-- Drop monthly partitioning of ai.retrieval_log and auth.rls_audit_log, copying the rows
+-- back into plain tables
+ALTER TABLE ai.retrieval_log RENAME TO retrieval_log_partitioned;
+ALTER INDEX ai.retrieval_log_pkey RENAME TO retrieval_log_partitioned_pkey;
+ALTER INDEX ai.idx_ai_retrieval_log_chatbot RENAME TO idx_ai_retrieval_log_partitioned_chatbot;
+ALTER INDEX ai.idx_ai_retrieval_log_kb RENAME TO idx_ai_retrieval_log_partitioned_kb;
+ALTER INDEX ai.idx_ai_retrieval_log_created RENAME TO idx_ai_retrieval_log_partitioned_created;
+
+CREATE TABLE ai.retrieval_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    chatbot_id UUID REFERENCES ai.chatbots(id) ON DELETE SET NULL,
+    conversation_id UUID REFERENCES ai.conversations(id) ON DELETE SET NULL,
+    knowledge_base_id UUID REFERENCES ai.knowledge_bases(id) ON DELETE SET NULL,
+    user_id UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+    query_text TEXT NOT NULL,
+    query_embedding_model TEXT,
+    chunks_retrieved INTEGER DEFAULT 0,
+    chunk_ids UUID[],
+    similarity_scores FLOAT[],
+    retrieval_duration_ms INTEGER,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+INSERT INTO ai.retrieval_log SELECT * FROM ai.retrieval_log_partitioned ON CONFLICT (id) DO NOTHING;
+DROP TABLE ai.retrieval_log_partitioned;
+
+CREATE INDEX IF NOT EXISTS idx_ai_retrieval_log_chatbot ON ai.retrieval_log(chatbot_id);
+CREATE INDEX IF NOT EXISTS idx_ai_retrieval_log_kb ON ai.retrieval_log(knowledge_base_id);
+CREATE INDEX IF NOT EXISTS idx_ai_retrieval_log_created ON ai.retrieval_log(created_at DESC);
+
+COMMENT ON TABLE ai.retrieval_log IS 'Audit log for RAG retrieval operations';
+
+ALTER TABLE ai.retrieval_log ENABLE ROW LEVEL SECURITY;
+CREATE POLICY "ai_retrieval_log_service_all" ON ai.retrieval_log FOR ALL TO service_role USING (true);
+CREATE POLICY "ai_retrieval_log_dashboard_admin" ON ai.retrieval_log
+    FOR SELECT TO authenticated
+    USING (auth.role() = 'dashboard_admin');
+
+GRANT SELECT ON ai.retrieval_log TO authenticated;
+GRANT ALL ON ai.retrieval_log TO service_role;
+
+ALTER TABLE auth.rls_audit_log RENAME TO rls_audit_log_partitioned;
+ALTER INDEX auth.rls_audit_log_pkey RENAME TO rls_audit_log_partitioned_pkey;
+ALTER INDEX auth.idx_rls_audit_user_id RENAME TO idx_rls_audit_partitioned_user_id;
+ALTER INDEX auth.idx_rls_audit_created_at RENAME TO idx_rls_audit_partitioned_created_at;
+ALTER INDEX auth.idx_rls_audit_table RENAME TO idx_rls_audit_partitioned_table;
+ALTER INDEX auth.idx_rls_audit_allowed RENAME TO idx_rls_audit_partitioned_allowed;
+ALTER INDEX auth.idx_rls_audit_role RENAME TO idx_rls_audit_partitioned_role;
+ALTER INDEX auth.idx_rls_audit_operation RENAME TO idx_rls_audit_partitioned_operation;
+ALTER INDEX auth.idx_rls_audit_request_id RENAME TO idx_rls_audit_partitioned_request_id;
+
+CREATE TABLE auth.rls_audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID,
+    role TEXT NOT NULL,
+    operation TEXT NOT NULL,
+    table_schema TEXT NOT NULL,
+    table_name TEXT NOT NULL,
+    allowed BOOLEAN NOT NULL DEFAULT false,
+    row_count INTEGER DEFAULT 0,
+    ip_address INET,
+    user_agent TEXT,
+    request_id TEXT,
+    execution_time_ms INTEGER,
+    details JSONB DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO auth.rls_audit_log SELECT * FROM auth.rls_audit_log_partitioned ON CONFLICT (id) DO NOTHING;
+DROP TABLE auth.rls_audit_log_partitioned;
+
+CREATE INDEX IF NOT EXISTS idx_rls_audit_user_id ON auth.rls_audit_log(user_id) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_rls_audit_created_at ON auth.rls_audit_log(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_rls_audit_table ON auth.rls_audit_log(table_schema, table_name);
+CREATE INDEX IF NOT EXISTS idx_rls_audit_allowed ON auth.rls_audit_log(allowed) WHERE allowed = false;
+CREATE INDEX IF NOT EXISTS idx_rls_audit_role ON auth.rls_audit_log(role);
+CREATE INDEX IF NOT EXISTS idx_rls_audit_operation ON auth.rls_audit_log(operation);
+CREATE INDEX IF NOT EXISTS idx_rls_audit_request_id ON auth.rls_audit_log(request_id) WHERE request_id IS NOT NULL;
+
+COMMENT ON TABLE auth.rls_audit_log IS 'Audit log for Row Level Security policy evaluations, primarily tracking access denials and violations for security monitoring and compliance';
+COMMENT ON COLUMN auth.rls_audit_log.allowed IS 'false indicates RLS policy blocked the operation (violation), true indicates policy allowed it';
+COMMENT ON COLUMN auth.rls_audit_log.details IS 'Flexible JSONB field for storing additional context like error messages, query hints, or policy names';
+
+ALTER TABLE auth.rls_audit_log ENABLE ROW LEVEL SECURITY;
+ALTER TABLE auth.rls_audit_log FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY rls_audit_log_service_insert ON auth.rls_audit_log
+    FOR INSERT
+    TO authenticated
+    WITH CHECK (auth.current_user_role() = 'service_role');
+
+CREATE POLICY rls_audit_log_admin_select ON auth.rls_audit_log
+    FOR SELECT
+    TO authenticated
+    USING (auth.current_user_role() IN ('admin', 'dashboard_admin', 'service_role'));
+
+CREATE POLICY rls_audit_log_user_select ON auth.rls_audit_log
+    FOR SELECT
+    TO authenticated
+    USING (auth.current_user_id() = user_id);
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON auth.rls_audit_log TO authenticated;
+GRANT ALL ON auth.rls_audit_log TO service_role;
+
+DROP TABLE IF EXISTS api.table_partition_policies;
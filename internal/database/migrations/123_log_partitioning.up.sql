@@ -0,0 +1,236 @@
+-- Monthly partitioning of append-only log tables
+-- ai.retrieval_log and auth.rls_audit_log grow without bound. Both are converted to
+-- tables partitioned by month on created_at, so the maintenance scheduler can enforce
+-- retention by dropping whole partitions instead of deleting rows.
+--
+-- Partitions are named <table>_pYYYYMM and cover a UTC calendar month. A default
+-- partition catches rows outside the created months; the scheduler moves them into
+-- their month when it creates the partition.
+
+CREATE TABLE IF NOT EXISTS api.table_partition_policies (
+    schema_name TEXT NOT NULL,
+    table_name TEXT NOT NULL,
+
+    -- Months of data kept, NULL keeps everything
+    retention_months INTEGER CHECK (retention_months IS NULL OR retention_months > 0),
+
+    -- Months created ahead of the current one
+    premake_months INTEGER NOT NULL DEFAULT 3 CHECK (premake_months BETWEEN 1 AND 24),
+
+    last_managed_at TIMESTAMPTZ,
+    last_error TEXT,
+    updated_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (schema_name, table_name)
+);
+
+COMMENT ON TABLE api.table_partition_policies IS 'Retention of tables partitioned by month on created_at, enforced by the maintenance scheduler';
+
+ALTER TABLE api.table_partition_policies ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "api_table_partition_policies_service_role" ON api.table_partition_policies
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON api.table_partition_policies TO service_role;
+
+-- Existing installations keep all their data until a retention is configured
+INSERT INTO api.table_partition_policies (schema_name, table_name)
+VALUES ('ai', 'retrieval_log'), ('auth', 'rls_audit_log')
+ON CONFLICT DO NOTHING;
+
+-- ============================================================================
+-- ai.retrieval_log
+-- ============================================================================
+
+ALTER TABLE ai.retrieval_log RENAME TO retrieval_log_unpartitioned;
+ALTER INDEX ai.retrieval_log_pkey RENAME TO retrieval_log_unpartitioned_pkey;
+
+-- The primary key of a partitioned table must include the partition key
+CREATE TABLE ai.retrieval_log (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    chatbot_id UUID REFERENCES ai.chatbots(id) ON DELETE SET NULL,
+    conversation_id UUID REFERENCES ai.conversations(id) ON DELETE SET NULL,
+    knowledge_base_id UUID REFERENCES ai.knowledge_bases(id) ON DELETE SET NULL,
+    user_id UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+
+    -- Query info
+    query_text TEXT NOT NULL,
+    query_embedding_model TEXT,
+
+    -- Results
+    chunks_retrieved INTEGER DEFAULT 0,
+    chunk_ids UUID[],
+    similarity_scores FLOAT[],
+
+    -- Performance
+    retrieval_duration_ms INTEGER,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+-- ============================================================================
+-- auth.rls_audit_log
+-- ============================================================================
+
+ALTER TABLE auth.rls_audit_log RENAME TO rls_audit_log_unpartitioned;
+ALTER INDEX auth.rls_audit_log_pkey RENAME TO rls_audit_log_unpartitioned_pkey;
+
+CREATE TABLE auth.rls_audit_log (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+
+    -- User context
+    user_id UUID, -- NULL for anonymous requests
+    role TEXT NOT NULL, -- anon, authenticated, admin, service_role, etc.
+
+    -- Operation details
+    operation TEXT NOT NULL, -- SELECT, INSERT, UPDATE, DELETE
+    table_schema TEXT NOT NULL,
+    table_name TEXT NOT NULL,
+
+    -- RLS evaluation result
+    allowed BOOLEAN NOT NULL DEFAULT false, -- true if access granted, false if denied
+    row_count INTEGER DEFAULT 0, -- number of rows affected/returned
+
+    -- Request context
+    ip_address INET,
+    user_agent TEXT,
+    request_id TEXT, -- for correlating with HTTP request logs
+
+    -- Performance metrics
+    execution_time_ms INTEGER,
+
+    -- Additional metadata (flexible JSONB for extensibility)
+    details JSONB DEFAULT '{}'::jsonb,
+
+    -- Timestamp
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+-- ============================================================================
+-- PARTITIONS
+-- ============================================================================
+
+-- Create a partition for every month from the oldest row to three months ahead, plus the
+-- default partition, then copy the rows. Partitions get RLS without policies, so they
+-- can only be read through the parent table.
+DO $$
+DECLARE
+    t RECORD;
+    first_month TIMESTAMP;
+    last_month TIMESTAMP := date_trunc('month', NOW() AT TIME ZONE 'UTC') + INTERVAL '3 months';
+    month_start TIMESTAMP;
+    oldest TIMESTAMPTZ;
+    partition_name TEXT;
+BEGIN
+    FOR t IN SELECT * FROM (VALUES ('ai', 'retrieval_log'), ('auth', 'rls_audit_log')) AS v(schema_name, table_name) LOOP
+        EXECUTE format('SELECT min(created_at) FROM %I.%I', t.schema_name, t.table_name || '_unpartitioned') INTO oldest;
+        first_month := date_trunc('month', COALESCE(oldest, NOW()) AT TIME ZONE 'UTC');
+
+        month_start := first_month;
+        WHILE month_start <= last_month LOOP
+            partition_name := t.table_name || '_p' || to_char(month_start, 'YYYYMM');
+            EXECUTE format('CREATE TABLE %I.%I PARTITION OF %I.%I FOR VALUES FROM (%L) TO (%L)',
+                t.schema_name, partition_name, t.schema_name, t.table_name,
+                month_start AT TIME ZONE 'UTC', (month_start + INTERVAL '1 month') AT TIME ZONE 'UTC');
+            EXECUTE format('ALTER TABLE %I.%I ENABLE ROW LEVEL SECURITY', t.schema_name, partition_name);
+            month_start := month_start + INTERVAL '1 month';
+        END LOOP;
+
+        EXECUTE format('CREATE TABLE %I.%I PARTITION OF %I.%I DEFAULT',
+            t.schema_name, t.table_name || '_default', t.schema_name, t.table_name);
+        EXECUTE format('ALTER TABLE %I.%I ENABLE ROW LEVEL SECURITY', t.schema_name, t.table_name || '_default');
+    END LOOP;
+END $$;
+
+INSERT INTO ai.retrieval_log (
+    id, chatbot_id, conversation_id, knowledge_base_id, user_id,
+    query_text, query_embedding_model, chunks_retrieved,
+    chunk_ids, similarity_scores, retrieval_duration_ms, created_at
+)
+SELECT
+    id, chatbot_id, conversation_id, knowledge_base_id, user_id,
+    query_text, query_embedding_model, chunks_retrieved,
+    chunk_ids, similarity_scores, retrieval_duration_ms, COALESCE(created_at, NOW())
+FROM ai.retrieval_log_unpartitioned;
+
+INSERT INTO auth.rls_audit_log (
+    id, user_id, role, operation, table_schema, table_name,
+    allowed, row_count, ip_address, user_agent, request_id,
+    execution_time_ms, details, created_at
+)
+SELECT
+    id, user_id, role, operation, table_schema, table_name,
+    allowed, row_count, ip_address, user_agent, request_id,
+    execution_time_ms, details, created_at
+FROM auth.rls_audit_log_unpartitioned;
+
+DROP TABLE ai.retrieval_log_unpartitioned;
+DROP TABLE auth.rls_audit_log_unpartitioned;
+
+-- ============================================================================
+-- INDEXES (created on every partition)
+-- ============================================================================
+
+CREATE INDEX IF NOT EXISTS idx_ai_retrieval_log_chatbot ON ai.retrieval_log(chatbot_id);
+CREATE INDEX IF NOT EXISTS idx_ai_retrieval_log_kb ON ai.retrieval_log(knowledge_base_id);
+CREATE INDEX IF NOT EXISTS idx_ai_retrieval_log_created ON ai.retrieval_log(created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_rls_audit_user_id ON auth.rls_audit_log(user_id) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_rls_audit_created_at ON auth.rls_audit_log(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_rls_audit_table ON auth.rls_audit_log(table_schema, table_name);
+CREATE INDEX IF NOT EXISTS idx_rls_audit_allowed ON auth.rls_audit_log(allowed) WHERE allowed = false;
+CREATE INDEX IF NOT EXISTS idx_rls_audit_role ON auth.rls_audit_log(role);
+CREATE INDEX IF NOT EXISTS idx_rls_audit_operation ON auth.rls_audit_log(operation);
+CREATE INDEX IF NOT EXISTS idx_rls_audit_request_id ON auth.rls_audit_log(request_id) WHERE request_id IS NOT NULL;
+
+-- ============================================================================
+-- COMMENTS, ROW LEVEL SECURITY AND GRANTS
+-- ============================================================================
+
+COMMENT ON TABLE ai.retrieval_log IS 'Audit log for RAG retrieval operations, partitioned by month on created_at';
+COMMENT ON TABLE auth.rls_audit_log IS 'Audit log for Row Level Security policy evaluations, primarily tracking access denials and violations for security monitoring and compliance. Partitioned by month on created_at';
+COMMENT ON COLUMN auth.rls_audit_log.allowed IS 'false indicates RLS policy blocked the operation (violation), true indicates policy allowed it';
+COMMENT ON COLUMN auth.rls_audit_log.details IS 'Flexible JSONB field for storing additional context like error messages, query hints, or policy names';
+
+ALTER TABLE ai.retrieval_log ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "ai_retrieval_log_service_all" ON ai.retrieval_log FOR ALL TO service_role USING (true);
+CREATE POLICY "ai_retrieval_log_dashboard_admin" ON ai.retrieval_log
+    FOR SELECT TO authenticated
+    USING (auth.role() = 'dashboard_admin');
+
+ALTER TABLE auth.rls_audit_log ENABLE ROW LEVEL SECURITY;
+ALTER TABLE auth.rls_audit_log FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY rls_audit_log_service_insert ON auth.rls_audit_log
+    FOR INSERT
+    TO authenticated
+    WITH CHECK (auth.current_user_role() = 'service_role');
+
+CREATE POLICY rls_audit_log_admin_select ON auth.rls_audit_log
+    FOR SELECT
+    TO authenticated
+    USING (auth.current_user_role() IN ('admin', 'dashboard_admin', 'service_role'));
+
+CREATE POLICY rls_audit_log_user_select ON auth.rls_audit_log
+    FOR SELECT
+    TO authenticated
+    USING (auth.current_user_id() = user_id);
+
+COMMENT ON POLICY rls_audit_log_service_insert ON auth.rls_audit_log IS 'Only service role can insert audit log entries to prevent users from tampering with logs.';
+COMMENT ON POLICY rls_audit_log_admin_select ON auth.rls_audit_log IS 'Admins can view all audit logs for security monitoring and compliance.';
+COMMENT ON POLICY rls_audit_log_user_select ON auth.rls_audit_log IS 'Users can view their own audit log entries for transparency.';
+
+GRANT SELECT ON ai.retrieval_log TO authenticated;
+GRANT ALL ON ai.retrieval_log TO service_role;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON auth.rls_audit_log TO authenticated;
+GRANT ALL ON auth.rls_audit_log TO service_role;
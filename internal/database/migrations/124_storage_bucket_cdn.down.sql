@@ -0,0 +1,2 @@
+-- Drop storage bucket CDN settings
+DROP TABLE IF EXISTS storage.bucket_cdn;
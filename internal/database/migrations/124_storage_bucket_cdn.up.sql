@@ -0,0 +1,40 @@
+-- Storage bucket CDN
+-- Optional CDN in front of a bucket: public object URLs point at the CDN, signed URLs
+-- use the CDN's token scheme, and object updates and deletes purge the CDN cache.
+-- Signing keys and purge credentials are encrypted with the server encryption key.
+CREATE TABLE IF NOT EXISTS storage.bucket_cdn (
+    bucket_id TEXT PRIMARY KEY REFERENCES storage.buckets(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+
+    -- CDN URL serving the bucket, object keys are appended to it
+    base_url TEXT NOT NULL,
+
+    -- none, hmac or bunny
+    signing_scheme TEXT NOT NULL DEFAULT 'none' CHECK (signing_scheme IN ('none', 'hmac', 'bunny')),
+    signing_key_encrypted TEXT,
+
+    -- none, cloudflare, fastly, bunny or webhook
+    purge_provider TEXT NOT NULL DEFAULT 'none' CHECK (purge_provider IN ('none', 'cloudflare', 'fastly', 'bunny', 'webhook')),
+    -- Cloudflare zone ID
+    purge_zone_id TEXT,
+    -- Endpoint called by the webhook provider
+    purge_url TEXT,
+    purge_token_encrypted TEXT,
+
+    updated_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE storage.bucket_cdn IS 'CDN URL, URL signing and cache purge settings of storage buckets';
+
+-- Only the server reads the settings, the credentials never leave it
+ALTER TABLE storage.bucket_cdn ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "storage_bucket_cdn_service_role" ON storage.bucket_cdn
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+REVOKE ALL ON storage.bucket_cdn FROM anon, authenticated;
+GRANT ALL ON storage.bucket_cdn TO service_role;
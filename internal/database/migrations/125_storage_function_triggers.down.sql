@@ -0,0 +1,2 @@
+-- Drop storage function triggers
+DROP TABLE IF EXISTS storage.function_triggers;
@@ -0,0 +1,37 @@
+-- Storage function triggers
+-- Objects uploaded to a bucket invoke an edge function with the object streamed as the
+-- request body, so functions can transform uploads without downloading them again.
+CREATE TABLE IF NOT EXISTS storage.function_triggers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    bucket_id TEXT NOT NULL REFERENCES storage.buckets(id) ON DELETE CASCADE,
+    function_id UUID NOT NULL REFERENCES functions.edge_functions(id) ON DELETE CASCADE,
+
+    -- Only keys starting with prefix trigger the function, empty for all keys
+    prefix TEXT NOT NULL DEFAULT '',
+    -- MIME types, optionally with a wildcard subtype like image/*, empty for all types
+    content_types TEXT[] NOT NULL DEFAULT '{}',
+    -- Larger objects don't trigger the function
+    max_size_bytes BIGINT NOT NULL DEFAULT 104857600 CHECK (max_size_bytes > 0),
+    enabled BOOLEAN NOT NULL DEFAULT true,
+
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (bucket_id, function_id, prefix)
+);
+
+CREATE INDEX IF NOT EXISTS idx_storage_function_triggers_function_id ON storage.function_triggers(function_id);
+
+COMMENT ON TABLE storage.function_triggers IS 'Edge functions invoked with the content of objects uploaded to a bucket';
+
+-- Only the server reads the triggers
+ALTER TABLE storage.function_triggers ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "storage_function_triggers_service_role" ON storage.function_triggers
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+REVOKE ALL ON storage.function_triggers FROM anon, authenticated;
+GRANT ALL ON storage.function_triggers TO service_role;
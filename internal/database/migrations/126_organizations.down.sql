@@ -0,0 +1,19 @@
+-- Drop organizations
+DROP POLICY IF EXISTS storage_objects_organization_delete ON storage.objects;
+DROP POLICY IF EXISTS storage_objects_organization_update ON storage.objects;
+DROP POLICY IF EXISTS storage_objects_organization_insert ON storage.objects;
+DROP POLICY IF EXISTS storage_objects_organization_read ON storage.objects;
+DROP POLICY IF EXISTS storage_objects_organization_member ON storage.objects;
+DROP POLICY IF EXISTS storage_buckets_organization_view ON storage.buckets;
+DROP FUNCTION IF EXISTS storage.organization_allows(TEXT, BOOLEAN);
+
+DROP INDEX IF EXISTS storage.idx_storage_buckets_organization;
+ALTER TABLE storage.buckets DROP COLUMN IF EXISTS organization_id;
+
+DROP TABLE IF EXISTS auth.organization_invitations;
+DROP TABLE IF EXISTS auth.organization_members;
+DROP TABLE IF EXISTS auth.organizations;
+
+DROP FUNCTION IF EXISTS auth.is_org_member(UUID, TEXT);
+DROP FUNCTION IF EXISTS auth.current_org_id();
+DROP FUNCTION IF EXISTS auth.org_role_rank(TEXT);
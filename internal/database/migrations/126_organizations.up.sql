@@ -0,0 +1,246 @@
+-- Organizations
+-- First-class tenants: organizations, their members with a role, and invitations by email.
+-- Access tokens carry the user's active organization in the org_id and org_role claims, and
+-- storage buckets can belong to an organization so only its members reach their objects.
+
+-- ============================================================================
+-- ORGANIZATIONS
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS auth.organizations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    -- URL-friendly unique identifier, e.g. acme-inc
+    slug TEXT NOT NULL UNIQUE CHECK (slug ~ '^[a-z0-9][a-z0-9-]{1,62}$'),
+    metadata JSONB NOT NULL DEFAULT '{}',
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS auth.organization_members (
+    organization_id UUID NOT NULL REFERENCES auth.organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'admin', 'member')),
+    invited_by UUID,
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_organization_members_user ON auth.organization_members(user_id);
+
+CREATE TABLE IF NOT EXISTS auth.organization_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES auth.organizations(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('admin', 'member')),
+    -- SHA-256 of the token sent by email, the token itself is never stored
+    token_hash TEXT NOT NULL UNIQUE,
+    invited_by UUID,
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One pending invitation per organization and email
+CREATE UNIQUE INDEX IF NOT EXISTS idx_auth_organization_invitations_pending
+    ON auth.organization_invitations(organization_id, lower(email)) WHERE accepted_at IS NULL;
+
+COMMENT ON TABLE auth.organizations IS 'Tenants that users belong to through auth.organization_members';
+COMMENT ON TABLE auth.organization_members IS 'Users that belong to an organization, with their role in it';
+COMMENT ON TABLE auth.organization_invitations IS 'Invitations to join an organization, sent by email';
+
+-- ============================================================================
+-- RLS HELPERS
+-- ============================================================================
+
+-- Rank of an organization role, higher roles include the lower ones
+CREATE OR REPLACE FUNCTION auth.org_role_rank(role TEXT)
+RETURNS INTEGER
+LANGUAGE sql
+IMMUTABLE
+AS $$
+    SELECT CASE role WHEN 'owner' THEN 3 WHEN 'admin' THEN 2 WHEN 'member' THEN 1 ELSE 0 END
+$$;
+
+-- Active organization of the request, from the org_id claim
+CREATE OR REPLACE FUNCTION auth.current_org_id()
+RETURNS UUID AS $$
+BEGIN
+    RETURN NULLIF(auth.jwt()->>'org_id', '')::UUID;
+EXCEPTION
+    WHEN OTHERS THEN
+        RETURN NULL;
+END;
+$$ LANGUAGE plpgsql STABLE;
+
+COMMENT ON FUNCTION auth.current_org_id() IS 'Returns the active organization from the org_id claim of request.jwt.claims. Returns NULL if not set or invalid.';
+
+-- Membership is looked up live rather than trusted from the token, so removed members
+-- lose access before their token expires. SECURITY DEFINER lets policies on the
+-- membership table itself call it.
+CREATE OR REPLACE FUNCTION auth.is_org_member(org_id UUID, min_role TEXT DEFAULT 'member')
+RETURNS BOOLEAN
+LANGUAGE sql
+STABLE
+SECURITY DEFINER
+SET search_path = public, auth
+AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM auth.organization_members m
+        WHERE m.organization_id = org_id
+        AND m.user_id = auth.current_user_id()
+        AND auth.org_role_rank(m.role) >= auth.org_role_rank(min_role)
+    )
+$$;
+
+COMMENT ON FUNCTION auth.is_org_member(UUID, TEXT) IS 'Returns TRUE if the current user is a member of the organization with at least min_role (member, admin or owner).';
+
+GRANT EXECUTE ON FUNCTION auth.org_role_rank(TEXT) TO anon, authenticated, service_role;
+GRANT EXECUTE ON FUNCTION auth.current_org_id() TO anon, authenticated, service_role;
+GRANT EXECUTE ON FUNCTION auth.is_org_member(UUID, TEXT) TO anon, authenticated, service_role;
+
+-- ============================================================================
+-- ORGANIZATION RLS
+-- ============================================================================
+
+ALTER TABLE auth.organizations ENABLE ROW LEVEL SECURITY;
+ALTER TABLE auth.organization_members ENABLE ROW LEVEL SECURITY;
+ALTER TABLE auth.organization_invitations ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY organizations_admin ON auth.organizations
+    FOR ALL
+    USING (
+        auth.is_admin()
+        OR auth.current_user_role() = 'dashboard_admin'
+        OR auth.current_user_role() = 'service_role'
+    );
+
+-- Members can see their organizations
+CREATE POLICY organizations_read_member ON auth.organizations
+    FOR SELECT
+    USING (auth.is_org_member(id));
+
+CREATE POLICY organization_members_admin ON auth.organization_members
+    FOR ALL
+    USING (
+        auth.is_admin()
+        OR auth.current_user_role() = 'dashboard_admin'
+        OR auth.current_user_role() = 'service_role'
+    );
+
+-- Members can see who else belongs to their organizations
+CREATE POLICY organization_members_read_member ON auth.organization_members
+    FOR SELECT
+    USING (auth.is_org_member(organization_id));
+
+-- Invitations hold token hashes and are only managed through the API
+CREATE POLICY organization_invitations_admin ON auth.organization_invitations
+    FOR ALL
+    USING (
+        auth.is_admin()
+        OR auth.current_user_role() = 'dashboard_admin'
+        OR auth.current_user_role() = 'service_role'
+    );
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON auth.organizations TO service_role;
+GRANT SELECT, INSERT, UPDATE, DELETE ON auth.organization_members TO service_role;
+GRANT SELECT, INSERT, UPDATE, DELETE ON auth.organization_invitations TO service_role;
+GRANT SELECT ON auth.organizations TO authenticated;
+GRANT SELECT ON auth.organization_members TO authenticated;
+REVOKE ALL ON auth.organization_invitations FROM anon, authenticated;
+
+-- ============================================================================
+-- ORGANIZATION BUCKETS
+-- ============================================================================
+
+-- Buckets of an organization must be deleted or moved before the organization,
+-- they would otherwise become reachable outside of it
+ALTER TABLE storage.buckets
+    ADD COLUMN IF NOT EXISTS organization_id UUID REFERENCES auth.organizations(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_storage_buckets_organization
+    ON storage.buckets(organization_id) WHERE organization_id IS NOT NULL;
+
+COMMENT ON COLUMN storage.buckets.organization_id IS 'Organization the bucket belongs to, only its members can access the objects. NULL for buckets without an organization.';
+
+-- Whether the current user may access objects of a bucket: buckets without an organization
+-- are unrestricted, organization buckets require membership, and public organization
+-- buckets stay readable by everyone. SECURITY DEFINER, since private buckets are not
+-- visible to users through storage.buckets RLS.
+CREATE OR REPLACE FUNCTION storage.organization_allows(bucket TEXT, reading BOOLEAN)
+RETURNS BOOLEAN
+LANGUAGE sql
+STABLE
+SECURITY DEFINER
+SET search_path = public, storage, auth
+AS $$
+    SELECT COALESCE((
+        SELECT b.organization_id IS NULL
+            OR (reading AND b.public = true)
+            OR auth.is_org_member(b.organization_id)
+        FROM storage.buckets b
+        WHERE b.id = bucket
+    ), true)
+$$;
+
+GRANT EXECUTE ON FUNCTION storage.organization_allows(TEXT, BOOLEAN) TO anon, authenticated, service_role;
+
+-- Members can see the buckets of their organizations
+CREATE POLICY storage_buckets_organization_view ON storage.buckets
+    FOR SELECT
+    USING (organization_id IS NOT NULL AND auth.is_org_member(organization_id));
+
+-- Members can work with all objects in their organization's buckets
+CREATE POLICY storage_objects_organization_member ON storage.objects
+    FOR ALL
+    USING (EXISTS (
+        SELECT 1 FROM storage.buckets b
+        WHERE b.id = objects.bucket_id
+        AND b.organization_id IS NOT NULL
+        AND auth.is_org_member(b.organization_id)
+    ))
+    WITH CHECK (EXISTS (
+        SELECT 1 FROM storage.buckets b
+        WHERE b.id = objects.bucket_id
+        AND b.organization_id IS NOT NULL
+        AND auth.is_org_member(b.organization_id)
+    ));
+
+-- Restrictive policies keep everyone else out of organization buckets, even owners of
+-- objects and users objects are shared with
+CREATE POLICY storage_objects_organization_read ON storage.objects
+    AS RESTRICTIVE
+    FOR SELECT
+    USING (
+        auth.current_user_role() IN ('dashboard_admin', 'service_role')
+        OR storage.organization_allows(bucket_id, true)
+    );
+
+CREATE POLICY storage_objects_organization_insert ON storage.objects
+    AS RESTRICTIVE
+    FOR INSERT
+    WITH CHECK (
+        auth.current_user_role() IN ('dashboard_admin', 'service_role')
+        OR storage.organization_allows(bucket_id, false)
+    );
+
+CREATE POLICY storage_objects_organization_update ON storage.objects
+    AS RESTRICTIVE
+    FOR UPDATE
+    USING (
+        auth.current_user_role() IN ('dashboard_admin', 'service_role')
+        OR storage.organization_allows(bucket_id, false)
+    )
+    WITH CHECK (
+        auth.current_user_role() IN ('dashboard_admin', 'service_role')
+        OR storage.organization_allows(bucket_id, false)
+    );
+
+CREATE POLICY storage_objects_organization_delete ON storage.objects
+    AS RESTRICTIVE
+    FOR DELETE
+    USING (
+        auth.current_user_role() IN ('dashboard_admin', 'service_role')
+        OR storage.organization_allows(bucket_id, false)
+    );
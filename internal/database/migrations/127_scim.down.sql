@@ -0,0 +1,6 @@
+-- Drop SCIM provisioning
+DROP INDEX IF EXISTS auth.idx_auth_groups_external_id;
+ALTER TABLE auth.groups DROP COLUMN IF EXISTS external_id;
+
+DROP TABLE IF EXISTS auth.scim_users;
+DROP TABLE IF EXISTS auth.scim_tokens;
@@ -0,0 +1,78 @@
+-- SCIM 2.0 provisioning
+-- Identity providers such as Okta and Entra ID create, update and deactivate users and groups
+-- through /api/v1/scim/v2, authenticated with a provisioning token. SCIM users map onto
+-- auth.users and SCIM groups onto auth.groups; the SCIM-only attributes are kept alongside.
+-- Deactivating a user locks the account permanently (is_locked with no locked_until).
+
+-- ============================================================================
+-- PROVISIONING TOKENS
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS auth.scim_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    -- SHA-256 of the token, the token itself is only shown once on creation
+    token_hash TEXT NOT NULL UNIQUE,
+    token_prefix TEXT NOT NULL,
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+COMMENT ON TABLE auth.scim_tokens IS 'Bearer tokens that identity providers use to call the SCIM API';
+
+-- ============================================================================
+-- SCIM ATTRIBUTES
+-- ============================================================================
+
+-- Attributes of users provisioned over SCIM. Users without a row here are still exposed
+-- over SCIM with their email as userName, so an identity provider can match existing accounts.
+CREATE TABLE IF NOT EXISTS auth.scim_users (
+    user_id UUID PRIMARY KEY REFERENCES auth.users(id) ON DELETE CASCADE,
+    external_id TEXT,
+    user_name TEXT NOT NULL,
+    display_name TEXT,
+    given_name TEXT,
+    family_name TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- userName is case-insensitive in SCIM
+CREATE UNIQUE INDEX IF NOT EXISTS idx_auth_scim_users_user_name ON auth.scim_users(lower(user_name));
+CREATE INDEX IF NOT EXISTS idx_auth_scim_users_external_id ON auth.scim_users(external_id) WHERE external_id IS NOT NULL;
+
+COMMENT ON TABLE auth.scim_users IS 'SCIM attributes of users provisioned by an identity provider';
+
+ALTER TABLE auth.groups ADD COLUMN IF NOT EXISTS external_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_auth_groups_external_id ON auth.groups(external_id) WHERE external_id IS NOT NULL;
+
+COMMENT ON COLUMN auth.groups.external_id IS 'Identifier of the group in the identity provider that provisions it over SCIM';
+
+-- ============================================================================
+-- ROW LEVEL SECURITY
+-- ============================================================================
+
+ALTER TABLE auth.scim_tokens ENABLE ROW LEVEL SECURITY;
+ALTER TABLE auth.scim_users ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY scim_tokens_admin ON auth.scim_tokens
+    FOR ALL
+    USING (
+        auth.current_user_role() = 'dashboard_admin'
+        OR auth.current_user_role() = 'service_role'
+    );
+
+CREATE POLICY scim_users_admin ON auth.scim_users
+    FOR ALL
+    USING (
+        auth.is_admin()
+        OR auth.current_user_role() = 'dashboard_admin'
+        OR auth.current_user_role() = 'service_role'
+    );
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON auth.scim_tokens TO service_role;
+GRANT SELECT, INSERT, UPDATE, DELETE ON auth.scim_users TO service_role;
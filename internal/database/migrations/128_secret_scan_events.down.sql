@@ -0,0 +1,2 @@
+-- Drop secret scanning events
+DROP TABLE IF EXISTS app.secret_scan_events;
@@ -0,0 +1,31 @@
+-- Secret scanning events
+-- Function and shared module deploys, and documents added to public knowledge bases, are
+-- scanned for committed credentials. Every upload with findings is recorded here, whether it
+-- was blocked, flagged, or let through by an admin with an override reason.
+-- Findings are redacted and never contain the full secret.
+
+CREATE TABLE IF NOT EXISTS app.secret_scan_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind TEXT NOT NULL CHECK (kind IN ('function', 'shared_module', 'kb_document')),
+    subject TEXT NOT NULL,
+    outcome TEXT NOT NULL CHECK (outcome IN ('blocked', 'flagged', 'overridden')),
+    findings JSONB NOT NULL DEFAULT '[]'::jsonb,
+    override_reason TEXT,
+    actor_id UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_app_secret_scan_events_created_at ON app.secret_scan_events(created_at DESC);
+
+COMMENT ON TABLE app.secret_scan_events IS 'Uploads in which credentials were detected, with the outcome and any override reason';
+
+ALTER TABLE app.secret_scan_events ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY secret_scan_events_admin ON app.secret_scan_events
+    FOR ALL
+    USING (
+        auth.current_user_role() = 'dashboard_admin'
+        OR auth.current_user_role() = 'service_role'
+    );
+
+GRANT SELECT, INSERT ON app.secret_scan_events TO service_role;
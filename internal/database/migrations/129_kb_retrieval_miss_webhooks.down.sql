@@ -0,0 +1,2 @@
+-- Drop knowledge base retrieval miss webhooks
+ALTER TABLE ai.knowledge_bases DROP COLUMN IF EXISTS retrieval_miss_webhook_url;
@@ -0,0 +1,8 @@
+-- Knowledge base retrieval miss webhooks
+-- When a chatbot query finds no chunk of a knowledge base above the similarity threshold,
+-- the query text and the best scores are posted to the knowledge base's webhook, giving
+-- content teams a feed of unanswered questions to write documentation for.
+
+ALTER TABLE ai.knowledge_bases ADD COLUMN IF NOT EXISTS retrieval_miss_webhook_url TEXT;
+
+COMMENT ON COLUMN ai.knowledge_bases.retrieval_miss_webhook_url IS 'Webhook notified when a chatbot query finds no chunk above the similarity threshold';
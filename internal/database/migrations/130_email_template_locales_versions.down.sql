@@ -0,0 +1,11 @@
+-- Drop email template locales and versions
+DROP TABLE IF EXISTS dashboard.email_template_versions;
+
+DELETE FROM dashboard.email_templates WHERE locale <> '';
+DROP INDEX IF EXISTS dashboard.idx_dashboard_email_templates_type_locale;
+ALTER TABLE dashboard.email_templates ADD CONSTRAINT email_templates_template_type_key UNIQUE (template_type);
+
+ALTER TABLE dashboard.email_templates DROP COLUMN IF EXISTS version;
+ALTER TABLE dashboard.email_templates DROP COLUMN IF EXISTS locale;
+
+COMMENT ON COLUMN dashboard.email_templates.template_type IS 'Type of template: magic_link, email_verification, password_reset';
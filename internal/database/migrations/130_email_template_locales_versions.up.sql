@@ -0,0 +1,47 @@
+-- Email template locales and versions
+-- Each template type can have a variant per locale. The variant for a user's locale
+-- (user_metadata.locale) is used, falling back to the base language and then to the
+-- variant without a locale. Every saved template is kept as a version so it can be restored.
+
+ALTER TABLE dashboard.email_templates ADD COLUMN IF NOT EXISTS locale TEXT NOT NULL DEFAULT '';
+ALTER TABLE dashboard.email_templates ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE dashboard.email_templates DROP CONSTRAINT IF EXISTS email_templates_template_type_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dashboard_email_templates_type_locale ON dashboard.email_templates(template_type, locale);
+
+COMMENT ON COLUMN dashboard.email_templates.template_type IS 'Type of template: magic_link, email_verification, password_reset, invitation, otp';
+COMMENT ON COLUMN dashboard.email_templates.locale IS 'Lowercase BCP 47 locale of the variant (e.g. de, pt-br), empty for the default variant';
+COMMENT ON COLUMN dashboard.email_templates.version IS 'Version of the template in dashboard.email_template_versions';
+
+CREATE TABLE IF NOT EXISTS dashboard.email_template_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    template_type TEXT NOT NULL,
+    locale TEXT NOT NULL DEFAULT '',
+    version INTEGER NOT NULL,
+    subject TEXT NOT NULL,
+    html_body TEXT NOT NULL,
+    text_body TEXT,
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (template_type, locale, version)
+);
+
+COMMENT ON TABLE dashboard.email_template_versions IS 'Every saved version of the customized email templates';
+
+INSERT INTO dashboard.email_template_versions (template_type, locale, version, subject, html_body, text_body, created_at)
+SELECT template_type, locale, version, subject, html_body, text_body, updated_at
+FROM dashboard.email_templates
+ON CONFLICT (template_type, locale, version) DO NOTHING;
+
+ALTER TABLE dashboard.email_template_versions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE dashboard.email_template_versions FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS dashboard_email_template_versions_select_policy ON dashboard.email_template_versions;
+CREATE POLICY dashboard_email_template_versions_select_policy ON dashboard.email_template_versions
+    FOR SELECT
+    USING (auth.current_user_role() = 'dashboard_admin' OR auth.current_user_role() = 'service_role');
+
+DROP POLICY IF EXISTS dashboard_email_template_versions_modify_policy ON dashboard.email_template_versions;
+CREATE POLICY dashboard_email_template_versions_modify_policy ON dashboard.email_template_versions
+    FOR ALL
+    USING (auth.current_user_role() = 'dashboard_admin');
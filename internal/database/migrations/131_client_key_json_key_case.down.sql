@@ -0,0 +1,2 @@
+-- Drop the JSON key case of client keys
+ALTER TABLE auth.client_keys DROP COLUMN IF EXISTS json_key_case;
@@ -0,0 +1,8 @@
+-- JSON key case per client key
+-- Requests made with a camel client key read and write table rows with camelCase keys
+-- for snake_case columns. A "Prefer: key-case=..." header overrides the key's setting.
+
+ALTER TABLE auth.client_keys ADD COLUMN IF NOT EXISTS json_key_case TEXT NOT NULL DEFAULT 'snake'
+    CHECK (json_key_case IN ('snake', 'camel'));
+
+COMMENT ON COLUMN auth.client_keys.json_key_case IS 'Key case of table rows in REST requests and responses: snake (column names) or camel';
@@ -0,0 +1,4 @@
+-- Drop known devices
+COMMENT ON COLUMN dashboard.email_templates.template_type IS 'Type of template: magic_link, email_verification, password_reset, invitation, otp';
+
+DROP TABLE IF EXISTS auth.known_devices;
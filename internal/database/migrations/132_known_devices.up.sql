@@ -0,0 +1,26 @@
+-- Known devices
+-- Devices a user has signed in from, identified by the client's device fingerprint. A successful
+-- sign-in from a device that isn't known sends the user a new-device security email.
+-- Users list and remove their known devices at /api/v1/auth/user/devices.
+
+CREATE TABLE IF NOT EXISTS auth.known_devices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    device_fingerprint TEXT NOT NULL,
+    ip_address TEXT,
+    location TEXT,
+    user_agent TEXT,
+    first_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, device_fingerprint)
+);
+
+CREATE INDEX IF NOT EXISTS idx_known_devices_last_seen ON auth.known_devices(last_seen_at);
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON auth.known_devices TO service_role;
+
+COMMENT ON TABLE auth.known_devices IS 'Devices users have signed in from, used for new-device sign-in alerts';
+COMMENT ON COLUMN auth.known_devices.ip_address IS 'Client IP address of the latest sign-in from the device';
+COMMENT ON COLUMN auth.known_devices.location IS 'Approximate location of the latest sign-in, from CDN geolocation headers';
+
+COMMENT ON COLUMN dashboard.email_templates.template_type IS 'Type of template: magic_link, email_verification, password_reset, invitation, otp, new_device';
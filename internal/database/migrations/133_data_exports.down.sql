@@ -0,0 +1,2 @@
+-- Drop data exports
+DROP TABLE IF EXISTS auth.data_exports;
@@ -0,0 +1,28 @@
+-- Data exports
+-- Self-serve data subject access requests: an archive of everything Fluxbase holds about a
+-- user, compiled in the background and stored in the data export bucket until it expires.
+-- Users request and download their exports at /api/v1/auth/user/data-exports.
+
+CREATE TABLE IF NOT EXISTS auth.data_exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    object_key TEXT,
+    size_bytes BIGINT,
+    error_message TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_exports_user ON auth.data_exports(user_id, created_at DESC);
+
+-- A user has at most one export in progress
+CREATE UNIQUE INDEX IF NOT EXISTS idx_data_exports_in_progress ON auth.data_exports(user_id)
+    WHERE status IN ('pending', 'processing');
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON auth.data_exports TO service_role;
+
+COMMENT ON TABLE auth.data_exports IS 'Self-serve exports of all data held about a user (data subject access requests)';
+COMMENT ON COLUMN auth.data_exports.object_key IS 'Key of the archive in the data export bucket, set once completed';
+COMMENT ON COLUMN auth.data_exports.expires_at IS 'When the archive is deleted and can no longer be downloaded';
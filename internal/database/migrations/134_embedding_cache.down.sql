@@ -0,0 +1,2 @@
+-- Drop embedding cache
+DROP TABLE IF EXISTS ai.embedding_cache;
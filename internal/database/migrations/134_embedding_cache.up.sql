@@ -0,0 +1,28 @@
+-- Embedding cache
+-- Embeddings of knowledge base chunks keyed by the embedding model and the SHA-256 of the embedded
+-- text, so that re-ingesting unchanged documents only sends new or changed chunks to the embedding
+-- provider. Entries are not tied to chunks; the table can be truncated at any time.
+
+CREATE TABLE IF NOT EXISTS ai.embedding_cache (
+    model TEXT NOT NULL,
+    content_sha256 TEXT NOT NULL,
+    embedding vector NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (model, content_sha256)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_embedding_cache_last_used ON ai.embedding_cache(last_used_at);
+
+ALTER TABLE ai.embedding_cache ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "ai_embedding_cache_service_role" ON ai.embedding_cache
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON ai.embedding_cache TO service_role;
+
+COMMENT ON TABLE ai.embedding_cache IS 'Chunk embeddings by model and content hash, reused when re-ingesting unchanged content';
+COMMENT ON COLUMN ai.embedding_cache.content_sha256 IS 'Hex SHA-256 of the embedded text';
+COMMENT ON COLUMN ai.embedding_cache.last_used_at IS 'When the embedding was last stored or reused';
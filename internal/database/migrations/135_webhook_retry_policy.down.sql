@@ -0,0 +1,9 @@
+-- Drop webhook retry policies and delivery replay
+DROP INDEX IF EXISTS auth.idx_auth_webhook_deliveries_status_created_at;
+
+ALTER TABLE auth.webhook_deliveries
+    DROP COLUMN IF EXISTS replay_of;
+
+ALTER TABLE auth.webhooks
+    DROP COLUMN IF EXISTS max_retry_backoff_seconds,
+    DROP COLUMN IF EXISTS retry_backoff_strategy;
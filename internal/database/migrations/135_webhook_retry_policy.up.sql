@@ -0,0 +1,19 @@
+-- Webhook retry policies and delivery replay
+-- Each webhook chooses how the wait between retries grows (linear, exponential or fixed) and caps
+-- it. Deliveries can be replayed by admins; a replay is recorded as a new delivery pointing at the
+-- delivery it replays.
+
+ALTER TABLE auth.webhooks
+    ADD COLUMN IF NOT EXISTS retry_backoff_strategy TEXT NOT NULL DEFAULT 'linear'
+        CHECK (retry_backoff_strategy IN ('linear', 'exponential', 'fixed')),
+    ADD COLUMN IF NOT EXISTS max_retry_backoff_seconds INTEGER NOT NULL DEFAULT 3600
+        CHECK (max_retry_backoff_seconds >= 0);
+
+ALTER TABLE auth.webhook_deliveries
+    ADD COLUMN IF NOT EXISTS replay_of UUID REFERENCES auth.webhook_deliveries(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_auth_webhook_deliveries_status_created_at ON auth.webhook_deliveries(status, created_at DESC);
+
+COMMENT ON COLUMN auth.webhooks.retry_backoff_strategy IS 'How the wait between retries grows: linear (backoff x attempt), exponential (backoff x 2^(attempt-1)) or fixed';
+COMMENT ON COLUMN auth.webhooks.max_retry_backoff_seconds IS 'Upper bound of the wait between retries in seconds (0 for no bound)';
+COMMENT ON COLUMN auth.webhook_deliveries.replay_of IS 'The delivery this delivery manually replays';
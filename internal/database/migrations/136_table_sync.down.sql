@@ -0,0 +1,3 @@
+-- Drop instance-to-instance table sync
+DROP TABLE IF EXISTS api.sync_table_state;
+DROP TABLE IF EXISTS api.sync_targets;
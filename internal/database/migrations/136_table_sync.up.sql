@@ -0,0 +1,65 @@
+-- Instance-to-instance table sync
+-- Selected tables are pushed one way to another Fluxbase instance. Changes are captured by a
+-- timestamp column (e.g. updated_at); each table keeps a cursor per target so a sync resumes
+-- where it stopped after downtime or a pause.
+CREATE TABLE IF NOT EXISTS api.sync_targets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL UNIQUE,
+
+    -- Base URL of the receiving instance and its service key
+    url TEXT NOT NULL,
+    service_key_encrypted TEXT NOT NULL,
+
+    -- Tables to sync as "schema.table"
+    tables TEXT[] NOT NULL DEFAULT '{}',
+    capture_column TEXT NOT NULL DEFAULT 'updated_at',
+    conflict_policy TEXT NOT NULL DEFAULT 'source_wins'
+        CHECK (conflict_policy IN ('source_wins', 'target_wins', 'newest_wins')),
+
+    paused BOOLEAN NOT NULL DEFAULT false,
+
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS api.sync_table_state (
+    target_id UUID NOT NULL REFERENCES api.sync_targets(id) ON DELETE CASCADE,
+    table_name TEXT NOT NULL,
+
+    -- Capture value and primary key (JSON array) of the last row sent
+    cursor_value TIMESTAMPTZ,
+    cursor_key JSONB,
+
+    rows_synced BIGINT NOT NULL DEFAULT 0,
+    last_synced_at TIMESTAMPTZ,
+
+    -- Capture value of the oldest change not sent yet, NULL when caught up
+    pending_since TIMESTAMPTZ,
+
+    last_error TEXT,
+    last_error_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (target_id, table_name)
+);
+
+COMMENT ON TABLE api.sync_targets IS 'Fluxbase instances that selected tables are synced to';
+COMMENT ON TABLE api.sync_table_state IS 'Cursor and lag of each table synced to a target';
+
+-- RLS policies (the api schema is only reachable by service_role, see migration 076)
+ALTER TABLE api.sync_targets ENABLE ROW LEVEL SECURITY;
+ALTER TABLE api.sync_table_state ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "api_sync_targets_service_role" ON api.sync_targets
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+CREATE POLICY "api_sync_table_state_service_role" ON api.sync_table_state
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON api.sync_targets TO service_role;
+GRANT ALL ON api.sync_table_state TO service_role;
@@ -0,0 +1,3 @@
+-- Drop anonymized views and their declarations
+DROP SCHEMA IF EXISTS anonymized CASCADE;
+DROP TABLE IF EXISTS api.anonymized_views;
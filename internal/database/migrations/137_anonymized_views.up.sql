@@ -0,0 +1,68 @@
+-- Anonymized views
+-- Admins declare read-only views of sensitive tables with per-column anonymization rules.
+-- The views live in the anonymized schema and are served by the REST API like any other view;
+-- they run with the privileges of their owner, so the raw tables can stay restricted to
+-- service_role. The declarations are kept here so the views can be listed and rebuilt.
+CREATE SCHEMA IF NOT EXISTS anonymized;
+
+COMMENT ON SCHEMA anonymized IS 'Read-only anonymized views of sensitive tables, managed through the admin API';
+
+GRANT USAGE ON SCHEMA anonymized TO authenticated, service_role;
+
+CREATE TABLE IF NOT EXISTS api.anonymized_views (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL UNIQUE,
+
+    source_schema TEXT NOT NULL,
+    source_table TEXT NOT NULL,
+
+    -- Column rules in view column order: [{"column": "email", "rule": "hash"}, ...]
+    columns JSONB NOT NULL,
+
+    -- Application roles (JWT role claim) allowed to read the view, empty for every authenticated user
+    allowed_roles TEXT[] NOT NULL DEFAULT '{}',
+
+    -- Whether anon and authenticated were revoked from the source table when the view was created
+    restrict_source BOOLEAN NOT NULL DEFAULT false,
+
+    -- Secret mixed into hashed values, so pseudonyms can't be recomputed from guessed inputs
+    salt TEXT NOT NULL,
+
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE api.anonymized_views IS 'Declarations of the views in the anonymized schema';
+
+-- RLS policies (the api schema is only reachable by service_role, see migration 076)
+ALTER TABLE api.anonymized_views ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "api_anonymized_views_service_role" ON api.anonymized_views
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON api.anonymized_views TO service_role;
+
+-- Pseudonym of a value for the "hash" rule: a salted SHA-256, stable per view so hashed columns
+-- can still be joined and counted. The salt is read with the owner's privileges and never
+-- appears in the view definition.
+CREATE OR REPLACE FUNCTION anonymized.pseudonym(value TEXT, view_name TEXT)
+RETURNS TEXT
+LANGUAGE sql
+STABLE
+SECURITY DEFINER
+SET search_path = pg_catalog
+AS $$
+    SELECT CASE WHEN value IS NULL THEN NULL
+        ELSE encode(sha256(convert_to(v.salt || value, 'UTF8')), 'hex')
+    END
+    FROM api.anonymized_views v
+    WHERE v.name = view_name
+$$;
+
+REVOKE ALL ON FUNCTION anonymized.pseudonym(TEXT, TEXT) FROM PUBLIC;
+GRANT EXECUTE ON FUNCTION anonymized.pseudonym(TEXT, TEXT) TO authenticated, service_role;
+
+COMMENT ON FUNCTION anonymized.pseudonym(TEXT, TEXT) IS 'Salted SHA-256 pseudonym of a value, used by the hash rule of anonymized views';
@@ -0,0 +1,2 @@
+-- Drop knowledge base document processing options
+ALTER TABLE ai.knowledge_bases DROP COLUMN IF EXISTS processing_options;
@@ -0,0 +1,8 @@
+-- Knowledge base document processing options
+-- Controls how uploaded files are turned into text before chunking: when OCR runs, which
+-- languages it uses, and whether PDF tables are serialized to markdown. Uploads can override
+-- these per file.
+
+ALTER TABLE ai.knowledge_bases ADD COLUMN IF NOT EXISTS processing_options JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN ai.knowledge_bases.processing_options IS 'Text extraction options for uploaded files: ocr (auto, always, never), ocr_languages, extract_tables';
@@ -0,0 +1,3 @@
+-- Drop the audit log
+DROP TABLE IF EXISTS audit.events;
+DROP SCHEMA IF EXISTS audit;
@@ -0,0 +1,54 @@
+-- Audit log
+-- One row per security-relevant event: sign-ins, impersonation, permission grants, admin
+-- changes (knowledge base deletion, RLS policies, settings) and SQL editor executions.
+-- Rows are written by the server and pruned by the audit retention pruner.
+CREATE SCHEMA IF NOT EXISTS audit;
+
+COMMENT ON SCHEMA audit IS 'Audit trail of authentication events and admin actions';
+
+GRANT USAGE ON SCHEMA audit TO service_role;
+
+CREATE TABLE IF NOT EXISTS audit.events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    -- Category is the prefix of the action, e.g. auth for auth.sign_in
+    category TEXT NOT NULL,
+    action TEXT NOT NULL,
+
+    -- Who acted. No foreign keys: app users and dashboard admins live in different tables,
+    -- and events must outlive the accounts they mention.
+    actor_id UUID,
+    actor_email TEXT,
+    actor_role TEXT,
+
+    -- What was acted on, e.g. knowledge_base and its ID
+    resource_type TEXT,
+    resource_id TEXT,
+
+    ip_address INET,
+    user_agent TEXT,
+    request_id TEXT,
+
+    -- Action-specific details, e.g. the impersonated user or the executed SQL
+    details JSONB NOT NULL DEFAULT '{}',
+
+    CONSTRAINT valid_category CHECK (category IN ('auth', 'admin', 'data'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_occurred_at ON audit.events(occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit.events(actor_id, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON audit.events(action, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_resource ON audit.events(resource_type, resource_id, occurred_at DESC);
+
+COMMENT ON TABLE audit.events IS 'Authentication events and admin actions, queried through the admin audit API';
+
+-- RLS policies (only the server, as service_role, reads and writes the audit log)
+ALTER TABLE audit.events ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "audit_events_service_role" ON audit.events
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON audit.events TO service_role;
@@ -0,0 +1,3 @@
+-- Drop scheduled jobs and their run history
+DROP TABLE IF EXISTS api.scheduled_job_runs;
+DROP TABLE IF EXISTS api.scheduled_jobs;
@@ -0,0 +1,80 @@
+-- Scheduled jobs
+-- Edge functions and SQL snippets run on cron schedules by the scheduled jobs service,
+-- with the history and captured output of every scheduled and manual run.
+CREATE TABLE IF NOT EXISTS api.scheduled_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL UNIQUE,
+    description TEXT,
+
+    -- Cron expression (5 fields, 6 with seconds, or a descriptor like @daily)
+    cron_schedule TEXT NOT NULL,
+    -- IANA time zone the cron expression is evaluated in
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+
+    -- function or sql
+    target_type TEXT NOT NULL CHECK (target_type IN ('function', 'sql')),
+    function_name TEXT,
+    function_namespace TEXT,
+    -- JSON body posted to the function
+    payload JSONB NOT NULL DEFAULT '{}'::jsonb,
+    sql_text TEXT,
+
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    last_run_at TIMESTAMPTZ,
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CHECK (target_type <> 'function' OR function_name IS NOT NULL),
+    CHECK (target_type <> 'sql' OR sql_text IS NOT NULL)
+);
+
+COMMENT ON TABLE api.scheduled_jobs IS 'Edge functions and SQL snippets run on cron schedules';
+
+CREATE TABLE IF NOT EXISTS api.scheduled_job_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_id UUID NOT NULL REFERENCES api.scheduled_jobs(id) ON DELETE CASCADE,
+
+    -- manual or scheduled
+    trigger TEXT NOT NULL CHECK (trigger IN ('manual', 'scheduled')),
+    status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'completed', 'failed')),
+    error TEXT,
+
+    -- Function response or statement results, truncated to scheduled_jobs.max_output_bytes
+    output JSONB,
+
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ,
+    duration_ms BIGINT,
+    triggered_by UUID
+);
+
+-- At most one run per job at a time, across all instances
+CREATE UNIQUE INDEX IF NOT EXISTS idx_scheduled_job_runs_running
+    ON api.scheduled_job_runs(job_id)
+    WHERE status = 'running';
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_job_runs_job_started
+    ON api.scheduled_job_runs(job_id, started_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_job_runs_started
+    ON api.scheduled_job_runs(started_at DESC);
+
+COMMENT ON TABLE api.scheduled_job_runs IS 'History and output of scheduled job runs';
+
+-- RLS policies (the api schema is only reachable by service_role, see migration 076)
+ALTER TABLE api.scheduled_jobs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE api.scheduled_job_runs ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "api_scheduled_jobs_service_role" ON api.scheduled_jobs
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+CREATE POLICY "api_scheduled_job_runs_service_role" ON api.scheduled_job_runs
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON api.scheduled_jobs TO service_role;
+GRANT ALL ON api.scheduled_job_runs TO service_role;
@@ -0,0 +1,4 @@
+-- Drop message queues
+DROP TABLE IF EXISTS api.queue_messages;
+DROP TABLE IF EXISTS api.queue_consumer_groups;
+DROP TABLE IF EXISTS api.queues;
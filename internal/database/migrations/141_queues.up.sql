@@ -0,0 +1,80 @@
+-- Message queues
+-- Postgres-backed queues for functions and external workers. Every consumer group of a
+-- queue receives its own copy of each message; within a group, a read hides a message
+-- from other consumers until its visibility timeout expires or it is acknowledged.
+CREATE TABLE IF NOT EXISTS api.queues (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL UNIQUE CHECK (name ~ '^[a-z0-9][a-z0-9_-]{0,62}$'),
+
+    -- Default time a read message stays hidden before it is delivered again
+    visibility_timeout_seconds INTEGER NOT NULL DEFAULT 30 CHECK (visibility_timeout_seconds > 0),
+    -- Deliveries after which a message is moved to the dead-letter queue (or discarded)
+    max_deliveries INTEGER NOT NULL DEFAULT 5 CHECK (max_deliveries > 0),
+    dead_letter_queue TEXT REFERENCES api.queues(name) ON UPDATE CASCADE ON DELETE SET NULL,
+
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CHECK (dead_letter_queue IS DISTINCT FROM name)
+);
+
+COMMENT ON TABLE api.queues IS 'Message queues with visibility timeouts and dead-letter queues';
+
+CREATE TABLE IF NOT EXISTS api.queue_consumer_groups (
+    queue_id UUID NOT NULL REFERENCES api.queues(id) ON DELETE CASCADE,
+    name TEXT NOT NULL CHECK (name ~ '^[a-z0-9][a-z0-9_-]{0,62}$'),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (queue_id, name)
+);
+
+COMMENT ON TABLE api.queue_consumer_groups IS 'Consumer groups, each receiving every message sent to the queue';
+
+CREATE TABLE IF NOT EXISTS api.queue_messages (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    queue_id UUID NOT NULL,
+    consumer_group TEXT NOT NULL,
+    body JSONB NOT NULL,
+    headers JSONB NOT NULL DEFAULT '{}'::jsonb,
+
+    read_count INTEGER NOT NULL DEFAULT 0,
+    enqueued_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- The message can be read once visible_at has passed
+    visible_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_read_at TIMESTAMPTZ,
+    -- Error given by the last consumer that rejected the message
+    last_error TEXT,
+    -- Queue the message was dead-lettered from
+    dead_lettered_from TEXT,
+
+    FOREIGN KEY (queue_id, consumer_group) REFERENCES api.queue_consumer_groups(queue_id, name) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_queue_messages_ready
+    ON api.queue_messages(queue_id, consumer_group, visible_at, id);
+
+COMMENT ON TABLE api.queue_messages IS 'Messages waiting to be read or acknowledged, one row per consumer group';
+
+-- RLS policies (the api schema is only reachable by service_role, see migration 076)
+ALTER TABLE api.queues ENABLE ROW LEVEL SECURITY;
+ALTER TABLE api.queue_consumer_groups ENABLE ROW LEVEL SECURITY;
+ALTER TABLE api.queue_messages ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "api_queues_service_role" ON api.queues
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+CREATE POLICY "api_queue_consumer_groups_service_role" ON api.queue_consumer_groups
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+CREATE POLICY "api_queue_messages_service_role" ON api.queue_messages
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON api.queues TO service_role;
+GRANT ALL ON api.queue_consumer_groups TO service_role;
+GRANT ALL ON api.queue_messages TO service_role;
@@ -0,0 +1,2 @@
+-- Drop resumable uploads
+DROP TABLE IF EXISTS storage.tus_uploads;
@@ -0,0 +1,37 @@
+--
+-- RESUMABLE UPLOADS (TUS PROTOCOL)
+-- Progress of uploads created through the tus endpoints of the storage API
+--
+
+CREATE TABLE IF NOT EXISTS storage.tus_uploads (
+    id TEXT PRIMARY KEY,
+    bucket_id TEXT NOT NULL REFERENCES storage.buckets(id) ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    upload_length BIGINT NOT NULL CHECK (upload_length > 0),
+    upload_offset BIGINT NOT NULL DEFAULT 0 CHECK (upload_offset >= 0 AND upload_offset <= upload_length),
+    chunk_size BIGINT NOT NULL CHECK (chunk_size > 0),
+    content_type TEXT,
+    cache_control TEXT,
+    metadata JSONB,
+    owner_id UUID,
+    -- Chunked upload session of the storage provider (S3 multipart upload ID and part ETags)
+    session JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_storage_tus_uploads_expires_at ON storage.tus_uploads(expires_at);
+
+COMMENT ON TABLE storage.tus_uploads IS 'Unfinished resumable uploads, deleted once complete, terminated or expired';
+COMMENT ON COLUMN storage.tus_uploads.upload_offset IS 'Bytes stored so far, always a multiple of chunk_size until the upload completes';
+
+ALTER TABLE storage.tus_uploads ENABLE ROW LEVEL SECURITY;
+
+-- Uploads are managed by the server on behalf of their owner
+CREATE POLICY storage_tus_uploads_service_role ON storage.tus_uploads
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON storage.tus_uploads TO service_role;
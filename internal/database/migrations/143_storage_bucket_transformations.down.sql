@@ -0,0 +1,5 @@
+-- Rollback per-bucket image transformations
+
+DROP FUNCTION IF EXISTS storage.bucket_transformations_enabled(TEXT);
+
+ALTER TABLE storage.buckets DROP COLUMN IF EXISTS transformations_enabled;
@@ -0,0 +1,25 @@
+-- Per-bucket image transformations
+-- Buckets can turn off on-the-fly image transformations, so their objects are
+-- always served as uploaded.
+
+ALTER TABLE storage.buckets ADD COLUMN IF NOT EXISTS transformations_enabled BOOLEAN NOT NULL DEFAULT true;
+
+COMMENT ON COLUMN storage.buckets.transformations_enabled IS 'Allow on-the-fly image transformations (resize, crop, format conversion) of the bucket''s objects';
+
+-- SECURITY DEFINER function to check whether a bucket allows transformations
+-- This bypasses RLS so signed URL downloads, which carry no user context, can check it
+CREATE OR REPLACE FUNCTION storage.bucket_transformations_enabled(bucket_name TEXT)
+RETURNS BOOLEAN
+LANGUAGE plpgsql
+STABLE
+SECURITY DEFINER
+SET search_path = public, storage
+AS $$
+BEGIN
+    RETURN COALESCE((SELECT b.transformations_enabled FROM storage.buckets b WHERE b.name = bucket_name), true);
+END;
+$$;
+
+COMMENT ON FUNCTION storage.bucket_transformations_enabled(TEXT) IS 'SECURITY DEFINER function to check whether a bucket allows image transformations, bypassing RLS. Used by storage handler before transforming downloads.';
+
+GRANT EXECUTE ON FUNCTION storage.bucket_transformations_enabled(TEXT) TO anon, authenticated, service_role;
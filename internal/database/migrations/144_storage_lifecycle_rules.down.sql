@@ -0,0 +1,3 @@
+-- Drop storage lifecycle rules
+DROP INDEX IF EXISTS storage.idx_storage_objects_bucket_id_created_at;
+DROP TABLE IF EXISTS storage.lifecycle_rules;
@@ -0,0 +1,43 @@
+-- Storage lifecycle rules
+-- Objects older than a rule's age are deleted, or archived by moving them to another
+-- bucket under the same path. A background sweeper applies the enabled rules in batches.
+CREATE TABLE IF NOT EXISTS storage.lifecycle_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    bucket_id TEXT NOT NULL REFERENCES storage.buckets(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+
+    -- Only keys starting with prefix are affected, empty for all keys
+    prefix TEXT NOT NULL DEFAULT '',
+    action TEXT NOT NULL CHECK (action IN ('delete', 'archive')),
+    -- Objects created more than age_days ago are affected
+    age_days INTEGER NOT NULL CHECK (age_days > 0),
+    -- Bucket archived objects are moved to, required for the archive action
+    target_bucket_id TEXT REFERENCES storage.buckets(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+
+    last_run_at TIMESTAMPTZ,
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (bucket_id, name),
+    CHECK ((action = 'archive') = (target_bucket_id IS NOT NULL)),
+    CHECK (target_bucket_id IS NULL OR target_bucket_id <> bucket_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_storage_lifecycle_rules_target_bucket_id ON storage.lifecycle_rules(target_bucket_id);
+-- Sweeps find expired objects by bucket and creation time
+CREATE INDEX IF NOT EXISTS idx_storage_objects_bucket_id_created_at ON storage.objects(bucket_id, created_at);
+
+COMMENT ON TABLE storage.lifecycle_rules IS 'Per-bucket rules deleting or archiving objects older than a number of days';
+
+-- Only the server reads the rules
+ALTER TABLE storage.lifecycle_rules ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "storage_lifecycle_rules_service_role" ON storage.lifecycle_rules
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+REVOKE ALL ON storage.lifecycle_rules FROM anon, authenticated;
+GRANT ALL ON storage.lifecycle_rules TO service_role;
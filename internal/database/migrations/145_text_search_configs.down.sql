@@ -0,0 +1,2 @@
+-- Drop text search configurations. Generated tsvector columns stay on their tables.
+DROP TABLE IF EXISTS api.text_search_configs;
@@ -0,0 +1,36 @@
+-- Full text search configuration of table columns
+-- Names the text search configuration (language) that fts, plfts and wfts filters on a
+-- table's columns use, a weight class per column, and optionally a generated tsvector column
+-- combining the columns. The schema inspector reads it into the column metadata of the REST API.
+CREATE TABLE IF NOT EXISTS api.text_search_configs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    schema_name TEXT NOT NULL,
+    table_name TEXT NOT NULL,
+
+    -- Text search configuration of the columns, e.g. 'french'
+    language TEXT NOT NULL DEFAULT 'simple',
+
+    -- Columns in weight order: [{"column": "title", "weight": "A"}, {"column": "body", "language": "english", "weight": "B"}]
+    columns JSONB NOT NULL,
+
+    -- Generated tsvector column combining the columns, NULL for none
+    vector_column TEXT,
+
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (schema_name, table_name)
+);
+
+COMMENT ON TABLE api.text_search_configs IS 'Per-table full text search languages, column weights and generated tsvector columns';
+
+-- RLS policies (the api schema is only reachable by service_role, see migration 076)
+ALTER TABLE api.text_search_configs ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "api_text_search_configs_service_role" ON api.text_search_configs
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON api.text_search_configs TO service_role;
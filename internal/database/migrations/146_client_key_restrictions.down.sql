@@ -0,0 +1,5 @@
+-- Drop client key restrictions and rotation
+ALTER TABLE auth.client_keys DROP COLUMN IF EXISTS rotated_to;
+ALTER TABLE auth.client_keys DROP COLUMN IF EXISTS allowed_ips;
+ALTER TABLE auth.client_keys DROP COLUMN IF EXISTS allowed_buckets;
+ALTER TABLE auth.client_keys DROP COLUMN IF EXISTS allowed_tables;
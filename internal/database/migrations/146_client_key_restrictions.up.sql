@@ -0,0 +1,16 @@
+-- Client key restrictions and rotation
+-- Beyond its scopes, a client key can be limited to specific tables (read or read-write),
+-- storage buckets and client IP ranges. NULL means no restriction.
+-- A rotated key points at its replacement and stays valid until its grace period ends.
+
+ALTER TABLE auth.client_keys ADD COLUMN IF NOT EXISTS allowed_tables JSONB DEFAULT NULL
+    CHECK (allowed_tables IS NULL OR jsonb_typeof(allowed_tables) = 'object');
+ALTER TABLE auth.client_keys ADD COLUMN IF NOT EXISTS allowed_buckets TEXT[] DEFAULT NULL;
+ALTER TABLE auth.client_keys ADD COLUMN IF NOT EXISTS allowed_ips TEXT[] DEFAULT NULL;
+ALTER TABLE auth.client_keys ADD COLUMN IF NOT EXISTS rotated_to UUID DEFAULT NULL
+    REFERENCES auth.client_keys(id) ON DELETE SET NULL;
+
+COMMENT ON COLUMN auth.client_keys.allowed_tables IS 'Tables the key can access, as {"schema.table": "read" | "write"}; "schema.*" matches a whole schema. NULL = all tables';
+COMMENT ON COLUMN auth.client_keys.allowed_buckets IS 'Storage buckets the key can access; "prefix-*" matches by prefix. NULL = all buckets';
+COMMENT ON COLUMN auth.client_keys.allowed_ips IS 'Client IP addresses or CIDR ranges the key can be used from. NULL = any address';
+COMMENT ON COLUMN auth.client_keys.rotated_to IS 'Key that replaced this one when it was rotated';
@@ -0,0 +1,3 @@
+-- Drop usage quotas and their counters
+DROP TABLE IF EXISTS api.quota_counters;
+DROP TABLE IF EXISTS api.usage_quotas;
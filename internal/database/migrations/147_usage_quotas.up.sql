@@ -0,0 +1,66 @@
+-- Usage quotas
+-- Daily and monthly limits on API calls and AI tokens, and limits on stored bytes, attached to
+-- users or organizations. API calls are counted by the server into api.quota_counters; stored
+-- bytes and AI tokens are measured from storage.objects, ai.messages and ai.embedding_usage.
+CREATE TABLE IF NOT EXISTS api.usage_quotas (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- User or organization the quota applies to
+    subject_type TEXT NOT NULL CHECK (subject_type IN ('user', 'organization')),
+    subject_id UUID NOT NULL,
+
+    metric TEXT NOT NULL CHECK (metric IN ('api_calls', 'storage_bytes', 'ai_tokens')),
+    period TEXT NOT NULL CHECK (period IN ('day', 'month', 'total')),
+    quota_limit BIGINT NOT NULL CHECK (quota_limit >= 0),
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (subject_type, subject_id, metric, period),
+
+    -- Stored bytes are a level rather than a flow, so they are limited at any time;
+    -- the other metrics reset at the start of each UTC day or month
+    CHECK ((metric = 'storage_bytes') = (period = 'total'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_quotas_subject
+    ON api.usage_quotas(subject_type, subject_id);
+
+COMMENT ON TABLE api.usage_quotas IS 'Daily, monthly and total usage limits of users and organizations';
+COMMENT ON COLUMN api.usage_quotas.quota_limit IS 'Maximum usage per period: calls, bytes or tokens depending on the metric';
+
+-- Counted usage per subject and period. Rows are upserted by the server's quota service,
+-- which keeps a cached counter in memory and adds to the row on every flush.
+CREATE TABLE IF NOT EXISTS api.quota_counters (
+    subject_type TEXT NOT NULL,
+    subject_id UUID NOT NULL,
+    metric TEXT NOT NULL,
+    period TEXT NOT NULL,
+
+    -- Start of the UTC day or month this row covers
+    period_start TIMESTAMPTZ NOT NULL,
+
+    used BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (subject_type, subject_id, metric, period, period_start)
+);
+
+COMMENT ON TABLE api.quota_counters IS 'Counted usage of users and organizations per day and month';
+
+-- RLS policies (the api schema is only reachable by service_role, see migration 076)
+ALTER TABLE api.usage_quotas ENABLE ROW LEVEL SECURITY;
+ALTER TABLE api.quota_counters ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "api_usage_quotas_service_role" ON api.usage_quotas
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+CREATE POLICY "api_quota_counters_service_role" ON api.quota_counters
+    FOR ALL TO service_role
+    USING (true)
+    WITH CHECK (true);
+
+GRANT ALL ON api.usage_quotas TO service_role;
+GRANT ALL ON api.quota_counters TO service_role;
@@ -0,0 +1,18 @@
+-- Keep branching history when the app user who created a branch, ran a branch operation or
+-- granted branch access is deleted. Without ON DELETE these foreign keys blocked deleting such
+-- users from the admin API.
+
+ALTER TABLE branching.branches DROP CONSTRAINT IF EXISTS branches_created_by_fkey;
+ALTER TABLE branching.branches
+    ADD CONSTRAINT branches_created_by_fkey
+    FOREIGN KEY (created_by) REFERENCES auth.users(id) ON DELETE SET NULL;
+
+ALTER TABLE branching.activity_log DROP CONSTRAINT IF EXISTS activity_log_executed_by_fkey;
+ALTER TABLE branching.activity_log
+    ADD CONSTRAINT activity_log_executed_by_fkey
+    FOREIGN KEY (executed_by) REFERENCES auth.users(id) ON DELETE SET NULL;
+
+ALTER TABLE branching.branch_access DROP CONSTRAINT IF EXISTS branch_access_granted_by_fkey;
+ALTER TABLE branching.branch_access
+    ADD CONSTRAINT branch_access_granted_by_fkey
+    FOREIGN KEY (granted_by) REFERENCES auth.users(id) ON DELETE SET NULL;
@@ -0,0 +1,216 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+// replicaLagQuery measures how far a replica is behind its primary. A replica that has
+// replayed everything it received is current, however old its last replayed transaction
+// is, so an idle primary does not make its replicas look lagging.
+const replicaLagQuery = `
+	SELECT CASE
+		WHEN NOT pg_is_in_recovery() THEN 0
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END::float8`
+
+// ReplicaStatus describes the health of a read replica
+type ReplicaStatus struct {
+	Name       string  `json:"name"`
+	Healthy    bool    `json:"healthy"`
+	LagSeconds float64 `json:"lag_seconds"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// replica is a read replica with the result of its last health check
+type replica struct {
+	name    string
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+	lag     atomic.Int64 // nanoseconds
+
+	mu      sync.Mutex
+	lastErr string
+}
+
+// update records the result of a health check, logging when the replica goes in or out
+// of rotation
+func (r *replica) update(lag time.Duration, err error, maxLag time.Duration) {
+	r.lag.Store(int64(lag))
+
+	r.mu.Lock()
+	switch {
+	case err != nil:
+		r.lastErr = err.Error()
+	case lag > maxLag:
+		r.lastErr = fmt.Sprintf("replication lag %s exceeds %s", lag.Round(time.Millisecond), maxLag)
+	default:
+		r.lastErr = ""
+	}
+	reason := r.lastErr
+	r.mu.Unlock()
+
+	healthy := reason == ""
+	if r.healthy.Swap(healthy) != healthy {
+		if healthy {
+			log.Info().Str("replica", r.name).Dur("lag", lag).Msg("Read replica back in rotation")
+		} else {
+			log.Warn().Str("replica", r.name).Str("reason", reason).Msg("Read replica taken out of rotation")
+		}
+	}
+}
+
+// ReplicaSet routes read-only queries to healthy read replicas in turn. Replicas are
+// checked periodically and skipped while unreachable or lagging more than the configured
+// maximum; callers fall back to the primary when none is healthy.
+type ReplicaSet struct {
+	replicas []*replica
+	maxLag   time.Duration
+	interval time.Duration
+	next     atomic.Uint64
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// newReplicaSet creates pools for the read replicas of cfg. Pools connect lazily, and
+// replicas stay out of rotation until their first successful health check.
+func newReplicaSet(cfg *config.DatabaseConfig) (*ReplicaSet, error) {
+	rs := &ReplicaSet{
+		maxLag:   cfg.ReplicaMaxLag,
+		interval: cfg.ReplicaHealthCheck,
+	}
+
+	for i, connString := range cfg.ReadReplicas {
+		poolConfig, err := newPoolConfig(connString, cfg)
+		if err != nil {
+			rs.closePools()
+			return nil, fmt.Errorf("read replica %d: %w", i, err)
+		}
+		if cfg.ReplicaMaxConnections > 0 {
+			poolConfig.MaxConns = cfg.ReplicaMaxConnections
+			poolConfig.MinConns = min(poolConfig.MinConns, poolConfig.MaxConns)
+		}
+
+		pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+		if err != nil {
+			rs.closePools()
+			return nil, fmt.Errorf("read replica %d: unable to create connection pool: %w", i, err)
+		}
+		rs.replicas = append(rs.replicas, &replica{
+			name:    fmt.Sprintf("%s:%d", poolConfig.ConnConfig.Host, poolConfig.ConnConfig.Port),
+			pool:    pool,
+			lastErr: "not checked yet",
+		})
+	}
+
+	return rs, nil
+}
+
+// start checks the replicas right away and then every interval
+func (rs *ReplicaSet) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	rs.cancel = cancel
+
+	rs.wg.Add(1)
+	go func() {
+		defer rs.wg.Done()
+
+		ticker := time.NewTicker(rs.interval)
+		defer ticker.Stop()
+
+		for {
+			rs.check(ctx)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// check runs a health check on every replica
+func (rs *ReplicaSet) check(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, r := range rs.replicas {
+		wg.Add(1)
+		go func(r *replica) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, min(rs.interval, 5*time.Second))
+			defer cancel()
+
+			var lagSeconds float64
+			err := r.pool.QueryRow(checkCtx, replicaLagQuery).Scan(&lagSeconds)
+			if ctx.Err() != nil {
+				return
+			}
+			r.update(time.Duration(lagSeconds*float64(time.Second)), err, rs.maxLag)
+		}(r)
+	}
+	wg.Wait()
+}
+
+// Pool returns the pool of the next healthy replica, or nil when none is healthy
+func (rs *ReplicaSet) Pool() *pgxpool.Pool {
+	n := uint64(len(rs.replicas))
+	start := rs.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		if r := rs.replicas[(start+i)%n]; r.healthy.Load() {
+			return r.pool
+		}
+	}
+	return nil
+}
+
+// MarkFailed takes the replica owning pool out of rotation until its next health check
+func (rs *ReplicaSet) MarkFailed(pool *pgxpool.Pool, err error) {
+	for _, r := range rs.replicas {
+		if r.pool == pool {
+			r.update(time.Duration(r.lag.Load()), err, rs.maxLag)
+			return
+		}
+	}
+}
+
+// Status returns the health of every replica
+func (rs *ReplicaSet) Status() []ReplicaStatus {
+	statuses := make([]ReplicaStatus, 0, len(rs.replicas))
+	for _, r := range rs.replicas {
+		r.mu.Lock()
+		lastErr := r.lastErr
+		r.mu.Unlock()
+
+		statuses = append(statuses, ReplicaStatus{
+			Name:       r.name,
+			Healthy:    r.healthy.Load(),
+			LagSeconds: time.Duration(r.lag.Load()).Seconds(),
+			Error:      lastErr,
+		})
+	}
+	return statuses
+}
+
+// Close stops the health checks and closes the replica pools
+func (rs *ReplicaSet) Close() {
+	if rs.cancel != nil {
+		rs.cancel()
+	}
+	rs.wg.Wait()
+	rs.closePools()
+}
+
+func (rs *ReplicaSet) closePools() {
+	for _, r := range rs.replicas {
+		r.pool.Close()
+	}
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testReplicaSet creates a replica set whose pools never connect, as no health check runs
+func testReplicaSet(t *testing.T, connStrings ...string) *ReplicaSet {
+	t.Helper()
+	rs, err := newReplicaSet(&config.DatabaseConfig{
+		MaxConnections:     10,
+		MaxConnLifetime:    time.Hour,
+		MaxConnIdleTime:    time.Minute,
+		HealthCheck:        time.Minute,
+		ReadReplicas:       connStrings,
+		ReplicaMaxLag:      10 * time.Second,
+		ReplicaHealthCheck: 5 * time.Second,
+	})
+	require.NoError(t, err)
+	t.Cleanup(rs.Close)
+	return rs
+}
+
+func TestReplicaSet_NewReplicaSet(t *testing.T) {
+	t.Run("names replicas by host and port", func(t *testing.T) {
+		rs := testReplicaSet(t, "postgres://u:p@replica-1:5433/db", "postgres://u:p@replica-2/db")
+
+		statuses := rs.Status()
+		require.Len(t, statuses, 2)
+		assert.Equal(t, "replica-1:5433", statuses[0].Name)
+		assert.Equal(t, "replica-2:5432", statuses[1].Name)
+		// Out of rotation until the first health check
+		assert.False(t, statuses[0].Healthy)
+		assert.Equal(t, "not checked yet", statuses[0].Error)
+		assert.Nil(t, rs.Pool())
+	})
+
+	t.Run("invalid connection string", func(t *testing.T) {
+		_, err := newReplicaSet(&config.DatabaseConfig{
+			MaxConnections: 10,
+			ReadReplicas:   []string{"postgres://u:p@replica-1/db", "postgres://u:p@replica-2:notaport/db"},
+		})
+		assert.ErrorContains(t, err, "read replica 1")
+	})
+
+	t.Run("replica pool size", func(t *testing.T) {
+		rs, err := newReplicaSet(&config.DatabaseConfig{
+			MaxConnections:        50,
+			MinConnections:        10,
+			ReadReplicas:          []string{"postgres://u:p@replica-1/db"},
+			ReplicaMaxConnections: 5,
+		})
+		require.NoError(t, err)
+		defer rs.Close()
+
+		assert.Equal(t, int32(5), rs.replicas[0].pool.Config().MaxConns)
+		assert.Equal(t, int32(5), rs.replicas[0].pool.Config().MinConns)
+	})
+}
+
+func TestReplicaSet_Pool(t *testing.T) {
+	rs := testReplicaSet(t, "postgres://u:p@replica-1/db", "postgres://u:p@replica-2/db", "postgres://u:p@replica-3/db")
+	for _, r := range rs.replicas {
+		r.update(time.Second, nil, rs.maxLag)
+	}
+
+	t.Run("rotates over healthy replicas", func(t *testing.T) {
+		seen := map[string]int{}
+		for i := 0; i < 6; i++ {
+			pool := rs.Pool()
+			require.NotNil(t, pool)
+			seen[pool.Config().ConnConfig.Host]++
+		}
+		assert.Equal(t, map[string]int{"replica-1": 2, "replica-2": 2, "replica-3": 2}, seen)
+	})
+
+	t.Run("skips lagging replicas", func(t *testing.T) {
+		rs.replicas[1].update(time.Minute, nil, rs.maxLag)
+		defer rs.replicas[1].update(0, nil, rs.maxLag)
+
+		for i := 0; i < 6; i++ {
+			assert.NotSame(t, rs.replicas[1].pool, rs.Pool())
+		}
+		assert.Contains(t, rs.Status()[1].Error, "replication lag 1m0s exceeds 10s")
+		assert.Equal(t, 60.0, rs.Status()[1].LagSeconds)
+	})
+
+	t.Run("skips failed replicas until the next check", func(t *testing.T) {
+		rs.MarkFailed(rs.replicas[0].pool, errors.New("connection refused"))
+
+		for i := 0; i < 6; i++ {
+			assert.NotSame(t, rs.replicas[0].pool, rs.Pool())
+		}
+		assert.False(t, rs.Status()[0].Healthy)
+		assert.Equal(t, "connection refused", rs.Status()[0].Error)
+
+		rs.replicas[0].update(0, nil, rs.maxLag)
+		assert.True(t, rs.Status()[0].Healthy)
+		assert.Empty(t, rs.Status()[0].Error)
+	})
+
+	t.Run("nil when no replica is healthy", func(t *testing.T) {
+		for _, r := range rs.replicas {
+			r.update(0, errors.New("down"), rs.maxLag)
+		}
+		assert.Nil(t, rs.Pool())
+	})
+}
+
+func TestConnection_ReadPool(t *testing.T) {
+	t.Run("primary without replicas", func(t *testing.T) {
+		conn := &Connection{}
+		assert.Nil(t, conn.ReadPool())
+		assert.Nil(t, conn.ReplicaStatus())
+	})
+
+	t.Run("healthy replica, then primary once it fails", func(t *testing.T) {
+		rs := testReplicaSet(t, "postgres://u:p@replica-1/db")
+		primary := testReplicaSet(t, "postgres://u:p@primary/db").replicas[0].pool
+		conn := &Connection{pool: primary, replicas: rs}
+		rs.replicas[0].update(0, nil, rs.maxLag)
+
+		replica := conn.ReadPool()
+		assert.Same(t, rs.replicas[0].pool, replica)
+
+		// Failures reported for the primary are ignored
+		conn.ReplicaFailed(primary, errors.New("boom"))
+		assert.Same(t, replica, conn.ReadPool())
+
+		conn.ReplicaFailed(replica, errors.New("connection refused"))
+		assert.Same(t, primary, conn.ReadPool())
+		assert.Len(t, conn.ReplicaStatus(), 1)
+	})
+}
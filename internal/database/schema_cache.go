@@ -398,27 +398,14 @@ func (c *SchemaCache) ViewCount() int {
 	return len(c.views)
 }
 
-// IsTableWritable checks if a table is writable (not a view or materialized view)
+// IsTableWritable checks if a table is writable: a table, or a view that accepts inserts,
+// updates and deletes. Materialized views are never writable.
 func (c *SchemaCache) IsTableWritable(ctx context.Context, schema, table string) (bool, error) {
 	c.mu.RLock()
 	if !c.needsRefresh() {
-		key := makeKey(schema, table)
-		// Check if it's a regular table (writable)
-		if _, ok := c.tables[key]; ok {
-			c.mu.RUnlock()
-			return true, nil
-		}
-		// Check if it's a view or materialized view (read-only)
-		if _, ok := c.views[key]; ok {
-			c.mu.RUnlock()
-			return false, nil
-		}
-		if _, ok := c.matViews[key]; ok {
-			c.mu.RUnlock()
-			return false, nil
-		}
+		writable := c.isWritable(makeKey(schema, table))
 		c.mu.RUnlock()
-		return false, nil // Not found
+		return writable, nil
 	}
 	c.mu.RUnlock()
 
@@ -429,10 +416,16 @@ func (c *SchemaCache) IsTableWritable(ctx context.Context, schema, table string)
 
 	c.mu.RLock()
 	defer c.mu.RUnlock()
+	return c.isWritable(makeKey(schema, table)), nil
+}
 
-	key := makeKey(schema, table)
+// isWritable reports whether the table or view of key is writable. The caller holds c.mu.
+func (c *SchemaCache) isWritable(key string) bool {
 	if _, ok := c.tables[key]; ok {
-		return true, nil
+		return true
+	}
+	if info, ok := c.views[key]; ok {
+		return info.IsUpdatable
 	}
-	return false, nil
+	return false // Materialized view or not found
 }
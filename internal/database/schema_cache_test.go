@@ -1,10 +1,12 @@
 package database
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMakeKey(t *testing.T) {
@@ -279,3 +281,25 @@ func TestSchemaCache_ViewCount(t *testing.T) {
 
 	assert.Equal(t, 2, cache.ViewCount())
 }
+
+func TestSchemaCache_IsTableWritable(t *testing.T) {
+	cache := &SchemaCache{
+		tables:      map[string]*TableInfo{"public.users": {Type: "table", IsUpdatable: true}},
+		views:       map[string]*TableInfo{"public.active_users": {Type: "view", IsUpdatable: true}, "public.user_stats": {Type: "view"}},
+		matViews:    map[string]*TableInfo{"public.daily_totals": {Type: "materialized_view"}},
+		ttl:         5 * time.Minute,
+		lastRefresh: time.Now(),
+	}
+
+	for table, want := range map[string]bool{
+		"users":        true,
+		"active_users": true,
+		"user_stats":   false,
+		"daily_totals": false,
+		"missing":      false,
+	} {
+		writable, err := cache.IsTableWritable(context.Background(), "public", table)
+		require.NoError(t, err)
+		assert.Equal(t, want, writable, table)
+	}
+}
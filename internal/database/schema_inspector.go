@@ -25,6 +25,7 @@ type TableInfo struct {
 	ForeignKeys []ForeignKey `json:"foreign_keys"`
 	Indexes     []IndexInfo  `json:"indexes"`
 	RLSEnabled  bool         `json:"rls_enabled"`
+	IsUpdatable bool         `json:"is_updatable"` // Tables, and views that accept INSERT, UPDATE and DELETE
 
 	// ColumnMap provides O(1) column lookup by name (populated lazily or by BuildColumnMap)
 	ColumnMap map[string]*ColumnInfo `json:"-"`
@@ -68,10 +69,18 @@ type ColumnInfo struct {
 	IsPrimaryKey bool             `json:"is_primary_key"`
 	IsForeignKey bool             `json:"is_foreign_key"`
 	IsUnique     bool             `json:"is_unique"`
+	IsGenerated  bool             `json:"is_generated"` // Computed by a GENERATED ALWAYS AS expression, not writable
 	MaxLength    *int             `json:"max_length"`
 	Position     int              `json:"position"`
 	Description  string           `json:"description,omitempty"`
 	JSONBSchema  *JSONBSchemaInfo `json:"jsonb_schema,omitempty"`
+	TextSearch   *TextSearchInfo  `json:"text_search,omitempty"` // Set for columns with a text search configuration
+}
+
+// TextSearchInfo is the full text search configuration of a column, from api.text_search_configs
+type TextSearchInfo struct {
+	Language string `json:"language"`         // Text search configuration used by fts, plfts and wfts filters
+	Weight   string `json:"weight,omitempty"` // Weight class in the table's generated tsvector column, if any
 }
 
 // JSONBSchemaInfo represents the schema of a JSONB column
@@ -160,10 +169,11 @@ func (si *SchemaInspector) GetAllTables(ctx context.Context, schemas ...string)
 
 		key := fmt.Sprintf("%s.%s", schema, name)
 		tableMap[key] = &TableInfo{
-			Schema:     schema,
-			Name:       name,
-			Type:       "table",
-			RLSEnabled: rlsEnabled,
+			Schema:      schema,
+			Name:        name,
+			Type:        "table",
+			RLSEnabled:  rlsEnabled,
+			IsUpdatable: true,
 		}
 		tableKeys = append(tableKeys, key)
 	}
@@ -225,6 +235,13 @@ func (si *SchemaInspector) GetTableInfo(ctx context.Context, schema, table strin
 	}
 	tableInfo.Indexes = indexes
 
+	// Get text search configuration
+	textSearch, err := si.batchGetTextSearch(ctx, []string{schema})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get text search configuration: %w", err)
+	}
+	applyTextSearch(tableInfo, textSearch[schema+"."+table])
+
 	// Mark primary key columns
 	for i := range tableInfo.Columns {
 		for _, pk := range tableInfo.PrimaryKey {
@@ -268,7 +285,8 @@ func (si *SchemaInspector) getColumns(ctx context.Context, schema, table string)
 			COALESCE(pg_catalog.col_description(
 				(c.table_schema || '.' || c.table_name)::regclass::oid,
 				c.ordinal_position
-			), '') as column_comment
+			), '') as column_comment,
+			c.is_generated = 'ALWAYS' as is_generated
 		FROM information_schema.columns c
 		WHERE c.table_schema = $1 AND c.table_name = $2
 		ORDER BY c.ordinal_position
@@ -295,6 +313,7 @@ func (si *SchemaInspector) getColumns(ctx context.Context, schema, table string)
 			&maxLength,
 			&col.Position,
 			&comment,
+			&col.IsGenerated,
 		)
 		if err != nil {
 			return nil, err
@@ -602,6 +621,18 @@ func (si *SchemaInspector) batchFetchTableMetadata(ctx context.Context, schemas
 				info.Indexes = idxs
 			}
 		}
+
+		// Batch fetch text search configurations
+		textSearch, err := si.batchGetTextSearch(ctx, schemas)
+		if err != nil {
+			return fmt.Errorf("failed to batch get text search configurations: %w", err)
+		}
+
+		for key, settings := range textSearch {
+			if info, ok := tableMap[key]; ok {
+				applyTextSearch(info, settings)
+			}
+		}
 	}
 
 	// For materialized views, fetch indexes (they can have indexes)
@@ -653,7 +684,8 @@ func (si *SchemaInspector) batchGetColumns(ctx context.Context, schemas []string
 			COALESCE(pg_catalog.col_description(
 				(c.table_schema || '.' || c.table_name)::regclass::oid,
 				c.ordinal_position
-			), '') as column_comment
+			), '') as column_comment,
+			c.is_generated = 'ALWAYS' as is_generated
 		FROM information_schema.columns c
 		WHERE c.table_schema = ANY($1)
 		ORDER BY c.table_schema, c.table_name, c.ordinal_position
@@ -682,6 +714,7 @@ func (si *SchemaInspector) batchGetColumns(ctx context.Context, schemas []string
 			&maxLength,
 			&col.Position,
 			&comment,
+			&col.IsGenerated,
 		)
 		if err != nil {
 			return nil, err
@@ -915,6 +948,60 @@ func (si *SchemaInspector) batchGetIndexes(ctx context.Context, schemas []string
 	return result, nil
 }
 
+// batchGetTextSearch retrieves the text search configuration of the columns of all tables in the
+// specified schemas, including their generated tsvector columns. Returns a map from
+// "schema.table" to the configuration of each column. Databases without the
+// api.text_search_configs table have none.
+func (si *SchemaInspector) batchGetTextSearch(ctx context.Context, schemas []string) (map[string]map[string]TextSearchInfo, error) {
+	result := make(map[string]map[string]TextSearchInfo)
+
+	var configured bool
+	if err := si.conn.QueryRow(ctx, "SELECT to_regclass('api.text_search_configs') IS NOT NULL").Scan(&configured); err != nil || !configured {
+		return result, err
+	}
+
+	query := `
+		SELECT t.schema_name, t.table_name, c->>'column',
+			COALESCE(NULLIF(c->>'language', ''), t.language), COALESCE(c->>'weight', '')
+		FROM api.text_search_configs t, jsonb_array_elements(t.columns) c
+		WHERE t.schema_name = ANY($1)
+		UNION ALL
+		SELECT schema_name, table_name, vector_column, language, ''
+		FROM api.text_search_configs
+		WHERE vector_column IS NOT NULL AND schema_name = ANY($1)
+	`
+
+	rows, err := si.conn.Query(ctx, query, schemas)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schema, table, column string
+		var info TextSearchInfo
+		if err := rows.Scan(&schema, &table, &column, &info.Language, &info.Weight); err != nil {
+			return nil, err
+		}
+		key := fmt.Sprintf("%s.%s", schema, table)
+		if result[key] == nil {
+			result[key] = make(map[string]TextSearchInfo)
+		}
+		result[key][column] = info
+	}
+
+	return result, rows.Err()
+}
+
+// applyTextSearch sets the text search configuration of the columns of a table
+func applyTextSearch(info *TableInfo, settings map[string]TextSearchInfo) {
+	for i := range info.Columns {
+		if ts, ok := settings[info.Columns[i].Name]; ok {
+			info.Columns[i].TextSearch = &ts
+		}
+	}
+}
+
 // GetSchemas retrieves all available schemas
 func (si *SchemaInspector) GetSchemas(ctx context.Context) ([]string, error) {
 	// Log schema introspection for audit purposes
@@ -958,7 +1045,9 @@ func (si *SchemaInspector) GetAllViews(ctx context.Context, schemas ...string) (
 	query := `
 		SELECT
 			schemaname,
-			viewname
+			viewname,
+			-- Updatable when INSERT (8), UPDATE (4) and DELETE (16) work, automatically or through INSTEAD OF triggers
+			pg_relation_is_updatable(format('%I.%I', schemaname, viewname)::regclass, true) & 28 = 28 AS is_updatable
 		FROM pg_views
 		WHERE schemaname = ANY($1)
 			AND schemaname NOT IN ('information_schema', 'pg_catalog')
@@ -977,17 +1066,19 @@ func (si *SchemaInspector) GetAllViews(ctx context.Context, schemas ...string) (
 
 	for rows.Next() {
 		var schema, name string
+		var isUpdatable bool
 
-		if err := rows.Scan(&schema, &name); err != nil {
+		if err := rows.Scan(&schema, &name, &isUpdatable); err != nil {
 			return nil, fmt.Errorf("failed to scan view: %w", err)
 		}
 
 		key := fmt.Sprintf("%s.%s", schema, name)
 		viewMap[key] = &TableInfo{
-			Schema:     schema,
-			Name:       name,
-			Type:       "view",
-			RLSEnabled: false,
+			Schema:      schema,
+			Name:        name,
+			Type:        "view",
+			RLSEnabled:  false,
+			IsUpdatable: isUpdatable,
 		}
 		viewKeys = append(viewKeys, key)
 	}
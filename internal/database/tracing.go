@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/observability"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxTracedStatement bounds the SQL recorded on a query span
+const maxTracedStatement = 2000
+
+// querySpanKey is the context key the span of a traced query is kept under until it ends
+type querySpanKey struct{}
+
+// queryTracer creates a span for every query run in the context of a trace, so a request's
+// trace shows the queries it ran. Queries outside a trace (e.g. background workers without a
+// span) are not traced, which keeps them from each starting a trace of their own.
+type queryTracer struct{}
+
+// TraceQueryStart starts the span of a query
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	ctx, span := observability.StartDBSpan(ctx, extractOperation(data.SQL), extractTableName(data.SQL))
+	statement := data.SQL
+	if len(statement) > maxTracedStatement {
+		statement = statement[:maxTracedStatement]
+	}
+	span.SetAttributes(semconv.DBStatement(statement))
+	return context.WithValue(ctx, querySpanKey{}, span)
+}
+
+// TraceQueryEnd ends the span of a query. Finding no rows is not recorded as an error.
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span, ok := ctx.Value(querySpanKey{}).(trace.Span)
+	if !ok {
+		return
+	}
+
+	err := data.Err
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if err == nil {
+		span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+	}
+	observability.EndDBSpan(span, err)
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestQueryTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	tracer := queryTracer{}
+	run := func(ctx context.Context, sql string, err error) {
+		ctx = tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: sql})
+		tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1"), Err: err})
+	}
+
+	t.Run("queries outside a trace are not traced", func(t *testing.T) {
+		run(context.Background(), "SELECT 1", nil)
+		assert.Empty(t, recorder.Ended())
+	})
+
+	ctx, request := otel.Tracer("test").Start(context.Background(), "request")
+	defer request.End()
+
+	t.Run("queries in a trace are child spans", func(t *testing.T) {
+		run(ctx, "SELECT * FROM auth.users WHERE id = $1", nil)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "db.select", spans[0].Name())
+		assert.Equal(t, request.SpanContext().SpanID(), spans[0].Parent().SpanID())
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	})
+
+	t.Run("no rows is not an error", func(t *testing.T) {
+		run(ctx, "SELECT 1", pgx.ErrNoRows)
+		spans := recorder.Ended()
+		assert.Equal(t, codes.Unset, spans[len(spans)-1].Status().Code)
+	})
+
+	t.Run("failed queries are errors", func(t *testing.T) {
+		run(ctx, "SELECT 1", errors.New("connection reset"))
+		spans := recorder.Ended()
+		assert.Equal(t, codes.Error, spans[len(spans)-1].Status().Code)
+	})
+}
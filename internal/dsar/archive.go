@@ -0,0 +1,186 @@
+// Package dsar compiles self-serve data subject access request (DSAR) exports: an archive of
+// everything Fluxbase holds about a user, generated in the background and emailed about when
+// it's ready to download.
+package dsar
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// section is one file of an export archive and the query that selects its data. Queries take the
+// user ID as $1 and select either a single row (the profile) or any number of rows.
+type section struct {
+	File        string
+	Description string
+	Single      bool
+	Query       string
+}
+
+// sections lists what an export contains. Secrets (password hashes, TOTP secrets, backup codes
+// and session tokens) are never exported, and storage objects are listed without their contents.
+var sections = []section{
+	{
+		File:        "profile.json",
+		Description: "Your account profile",
+		Single:      true,
+		Query: `
+			SELECT id, email, email_verified, role, user_metadata, app_metadata, totp_enabled,
+			       created_at, updated_at
+			FROM auth.users
+			WHERE id = $1`,
+	},
+	{
+		File:        "identities.json",
+		Description: "Social and SSO identities linked to your account",
+		Query: `
+			SELECT provider, provider_user_id, email, metadata, created_at, updated_at
+			FROM auth.oauth_links
+			WHERE user_id = $1
+			ORDER BY created_at`,
+	},
+	{
+		File:        "sessions.json",
+		Description: "Your sign-in sessions (without tokens)",
+		Query: `
+			SELECT id, created_at, updated_at, expires_at
+			FROM auth.sessions
+			WHERE user_id = $1
+			ORDER BY created_at`,
+	},
+	{
+		File:        "devices.json",
+		Description: "Devices you have signed in from",
+		Query: `
+			SELECT ip_address, location, user_agent, first_seen_at, last_seen_at
+			FROM auth.known_devices
+			WHERE user_id = $1
+			ORDER BY first_seen_at`,
+	},
+	{
+		File:        "storage_objects.json",
+		Description: "Files you own in storage (listed, not included)",
+		Query: `
+			SELECT bucket_id AS bucket, path, mime_type, size, metadata, created_at, updated_at
+			FROM storage.objects
+			WHERE owner_id = $1
+			ORDER BY bucket_id, path`,
+	},
+	{
+		File:        "ai_documents.json",
+		Description: "Knowledge base documents you own or added",
+		Query: `
+			SELECT d.id, kb.name AS knowledge_base, d.title, d.source_url, d.source_type, d.mime_type,
+			       d.content, d.metadata, d.tags, d.created_at, d.updated_at
+			FROM ai.documents d
+			JOIN ai.knowledge_bases kb ON kb.id = d.knowledge_base_id
+			WHERE d.owner_id = $1 OR d.created_by = $1
+			ORDER BY d.created_at`,
+	},
+	{
+		File:        "ai_conversations.json",
+		Description: "Your AI chatbot conversations and their messages",
+		Query: `
+			SELECT c.id, cb.name AS chatbot, c.title, c.status, c.created_at, c.last_message_at,
+			       COALESCE((
+			           SELECT json_agg(json_build_object('role', m.role, 'content', m.content, 'created_at', m.created_at)
+			                           ORDER BY m.sequence_number)
+			           FROM ai.messages m
+			           WHERE m.conversation_id = c.id
+			       ), '[]'::json) AS messages
+			FROM ai.conversations c
+			JOIN ai.chatbots cb ON cb.id = c.chatbot_id
+			WHERE c.user_id = $1
+			ORDER BY c.created_at`,
+	},
+}
+
+// sectionQuery wraps the query of a section so that it returns the section's data as one JSON value
+func sectionQuery(s section) string {
+	if s.Single {
+		return `SELECT row_to_json(t) FROM (` + s.Query + `) t`
+	}
+	return `SELECT COALESCE(json_agg(t), '[]'::json) FROM (` + s.Query + `) t`
+}
+
+// archiveFile is the JSON data of one section
+type archiveFile struct {
+	Section section
+	Data    json.RawMessage
+}
+
+// manifest is written to manifest.json at the root of an archive
+type manifest struct {
+	ExportID    uuid.UUID         `json:"export_id"`
+	UserID      uuid.UUID         `json:"user_id"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Files       map[string]string `json:"files"` // File name to description
+}
+
+// writeArchive writes a zip archive of the sections' data with a manifest and a README
+func writeArchive(w io.Writer, appName string, m manifest, files []archiveFile) error {
+	zw := zip.NewWriter(w)
+
+	m.Files = make(map[string]string, len(files))
+	for _, f := range files {
+		m.Files[f.Section.File] = f.Section.Description
+	}
+	manifestData, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := writeArchiveEntry(zw, "manifest.json", m.GeneratedAt, manifestData); err != nil {
+		return err
+	}
+	if err := writeArchiveEntry(zw, "README.txt", m.GeneratedAt, []byte(readme(appName, m, files))); err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, f.Data, "", "  "); err != nil {
+			return fmt.Errorf("failed to format %s: %w", f.Section.File, err)
+		}
+		if err := writeArchiveEntry(zw, f.Section.File, m.GeneratedAt, indented.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// writeArchiveEntry adds a compressed file to an archive
+func writeArchiveEntry(zw *zip.Writer, name string, modified time.Time, data []byte) error {
+	entry, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", name, err)
+	}
+	if _, err := entry.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", name, err)
+	}
+	return nil
+}
+
+// readme describes the files of an archive
+func readme(appName string, m manifest, files []archiveFile) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s data export\n\n", appName)
+	fmt.Fprintf(&b, "This archive contains the data %s holds about your account (user ID %s),\n", appName, m.UserID)
+	fmt.Fprintf(&b, "as of %s.\n\n", m.GeneratedAt.UTC().Format("2006-01-02 15:04 MST"))
+	b.WriteString("Files:\n")
+	for _, f := range files {
+		fmt.Fprintf(&b, "  %-24s %s\n", f.Section.File, f.Section.Description)
+	}
+	b.WriteString("\nPasswords, two-factor secrets and session tokens are never exported.\n")
+	return b.String()
+}
@@ -0,0 +1,96 @@
+package dsar
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSections(t *testing.T) {
+	files := map[string]bool{}
+	for _, s := range sections {
+		assert.False(t, files[s.File], "duplicate file %s", s.File)
+		files[s.File] = true
+		assert.NotEmpty(t, s.Description, s.File)
+		assert.Contains(t, s.Query, "$1", s.File)
+
+		// Secrets are never exported
+		for _, secret := range []string{"password_hash", "totp_secret", "backup_codes", "access_token", "refresh_token"} {
+			assert.NotContains(t, s.Query, secret, s.File)
+		}
+	}
+
+	for _, file := range []string{"profile.json", "identities.json", "sessions.json", "storage_objects.json", "ai_documents.json", "ai_conversations.json"} {
+		assert.True(t, files[file], "missing %s", file)
+	}
+}
+
+func TestSectionQuery(t *testing.T) {
+	assert.Equal(t, "SELECT row_to_json(t) FROM (SELECT 1) t", sectionQuery(section{Single: true, Query: "SELECT 1"}))
+	assert.Equal(t, "SELECT COALESCE(json_agg(t), '[]'::json) FROM (SELECT 1) t", sectionQuery(section{Query: "SELECT 1"}))
+}
+
+func TestWriteArchive(t *testing.T) {
+	m := manifest{
+		ExportID:    uuid.MustParse("8a1d5c4e-0f43-4c1b-9a51-3b7a2f6d9e10"),
+		UserID:      uuid.MustParse("2f9c7e1a-6b3d-4e8f-a0c2-5d4b3a2e1f00"),
+		GeneratedAt: time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC),
+	}
+	files := []archiveFile{
+		{Section: section{File: "profile.json", Description: "Your account profile"}, Data: json.RawMessage(`{"email":"user@example.com"}`)},
+		{Section: section{File: "sessions.json", Description: "Your sign-in sessions"}, Data: json.RawMessage(`[]`)},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeArchive(&buf, "TestApp", m, files))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	contents := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		contents[f.Name] = string(data)
+	}
+	require.Len(t, contents, 4)
+
+	assert.JSONEq(t, `{"email":"user@example.com"}`, contents["profile.json"])
+	assert.Equal(t, "{\n  \"email\": \"user@example.com\"\n}", contents["profile.json"])
+	assert.Equal(t, "[]", contents["sessions.json"])
+
+	var written manifest
+	require.NoError(t, json.Unmarshal([]byte(contents["manifest.json"]), &written))
+	assert.Equal(t, m.ExportID, written.ExportID)
+	assert.Equal(t, m.UserID, written.UserID)
+	assert.True(t, m.GeneratedAt.Equal(written.GeneratedAt))
+	assert.Equal(t, map[string]string{
+		"profile.json":  "Your account profile",
+		"sessions.json": "Your sign-in sessions",
+	}, written.Files)
+
+	readme := contents["README.txt"]
+	assert.True(t, strings.HasPrefix(readme, "TestApp data export\n"))
+	assert.Contains(t, readme, "user ID 2f9c7e1a-6b3d-4e8f-a0c2-5d4b3a2e1f00")
+	assert.Contains(t, readme, "as of 2026-03-01 09:30 UTC")
+	assert.Contains(t, readme, "profile.json")
+}
+
+func TestWriteArchive_InvalidJSON(t *testing.T) {
+	files := []archiveFile{{Section: section{File: "profile.json"}, Data: json.RawMessage(`{`)}}
+
+	err := writeArchive(io.Discard, "TestApp", manifest{}, files)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "profile.json")
+}
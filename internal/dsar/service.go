@@ -0,0 +1,379 @@
+package dsar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/nimbleflux/fluxbase/internal/email"
+	"github.com/nimbleflux/fluxbase/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// Status is the state of an export
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusProcessing Status = "processing"
+	StatusCompleted  Status = "completed"
+	StatusFailed     Status = "failed"
+)
+
+// exportTimeout bounds how long compiling a single export may take
+const exportTimeout = 30 * time.Minute
+
+// staleAfter is when an export that is still in progress is considered interrupted, e.g. by a
+// restart, so that it doesn't keep the user from requesting a new one
+const staleAfter = time.Hour
+
+// failureMessage is shown to users for failed exports; the cause is only logged
+const failureMessage = "The export could not be compiled. Please try again later."
+
+var (
+	// ErrExportNotFound is returned when an export doesn't exist or belongs to another user
+	ErrExportNotFound = errors.New("data export not found")
+	// ErrExportInProgress is returned when requesting an export while another one is in progress
+	ErrExportInProgress = errors.New("a data export is already in progress")
+	// ErrExportNotReady is returned when downloading an export that hasn't completed
+	ErrExportNotReady = errors.New("data export is not ready")
+	// ErrExportExpired is returned when downloading an export after it expired
+	ErrExportExpired = errors.New("data export has expired")
+)
+
+// Export is one row of auth.data_exports
+type Export struct {
+	ID           uuid.UUID  `json:"id"`
+	UserID       uuid.UUID  `json:"user_id"`
+	Status       Status     `json:"status"`
+	SizeBytes    *int64     `json:"size_bytes,omitempty"`
+	ErrorMessage *string    `json:"error_message,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+
+	objectKey *string
+}
+
+// exportColumns are the columns scanned by scanExport
+const exportColumns = `id, user_id, status, object_key, size_bytes, error_message, created_at, completed_at, expires_at`
+
+// Service compiles data exports in the background, stores the archives in the data export bucket
+// and emails users when their export is ready
+type Service struct {
+	cfg          *config.DataExportConfig
+	db           *pgxpool.Pool
+	storage      storage.Provider
+	emailService email.Service // nil when email is not available
+	appName      string
+	now          func() time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewService creates a data export service. emailService may be nil.
+func NewService(cfg *config.DataExportConfig, db *pgxpool.Pool, storageProvider storage.Provider, emailService email.Service, appName string) *Service {
+	if appName == "" {
+		appName = "Fluxbase"
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Service{
+		cfg:          cfg,
+		db:           db,
+		storage:      storageProvider,
+		emailService: emailService,
+		appName:      appName,
+		now:          time.Now,
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// Stop cancels the exports in progress and waits for them to finish
+func (s *Service) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Request starts compiling an export of a user's data in the background. A user can only have
+// one export in progress at a time.
+func (s *Service) Request(ctx context.Context, userID uuid.UUID) (*Export, error) {
+	s.removeExpired(ctx, userID)
+
+	// An export that has been in progress for too long was interrupted
+	_, err := s.db.Exec(ctx, `
+		UPDATE auth.data_exports
+		SET status = 'failed', error_message = $3
+		WHERE user_id = $1 AND status IN ('pending', 'processing') AND created_at < $2
+	`, userID, s.now().Add(-staleAfter), failureMessage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fail interrupted data exports: %w", err)
+	}
+
+	export, err := scanExport(s.db.QueryRow(ctx, `
+		INSERT INTO auth.data_exports (user_id)
+		VALUES ($1)
+		RETURNING `+exportColumns, userID))
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, ErrExportInProgress
+		}
+		return nil, fmt.Errorf("failed to create data export: %w", err)
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(export.ID, userID)
+	}()
+
+	return export, nil
+}
+
+// List returns the exports of a user, newest first
+func (s *Service) List(ctx context.Context, userID uuid.UUID) ([]Export, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT `+exportColumns+`
+		FROM auth.data_exports
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list data exports: %w", err)
+	}
+	defer rows.Close()
+
+	exports := []Export{}
+	for rows.Next() {
+		export, err := scanExport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan data export: %w", err)
+		}
+		exports = append(exports, *export)
+	}
+	return exports, rows.Err()
+}
+
+// Get returns an export of a user
+func (s *Service) Get(ctx context.Context, userID, exportID uuid.UUID) (*Export, error) {
+	export, err := scanExport(s.db.QueryRow(ctx, `
+		SELECT `+exportColumns+`
+		FROM auth.data_exports
+		WHERE id = $1 AND user_id = $2
+	`, exportID, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrExportNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data export: %w", err)
+	}
+	return export, nil
+}
+
+// Open returns the archive of a completed export of a user. The caller must close it.
+func (s *Service) Open(ctx context.Context, userID, exportID uuid.UUID) (io.ReadCloser, *Export, error) {
+	export, err := s.Get(ctx, userID, exportID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if export.Status != StatusCompleted || export.objectKey == nil {
+		return nil, nil, ErrExportNotReady
+	}
+	if export.ExpiresAt != nil && !s.now().Before(*export.ExpiresAt) {
+		return nil, nil, ErrExportExpired
+	}
+
+	reader, _, err := s.storage.Download(ctx, s.cfg.Bucket, *export.objectKey, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to download data export: %w", err)
+	}
+	return reader, export, nil
+}
+
+// run compiles an export and records the outcome
+func (s *Service) run(exportID, userID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(s.ctx, exportTimeout)
+	defer cancel()
+
+	logger := log.With().Str("export_id", exportID.String()).Str("user_id", userID.String()).Logger()
+
+	recipient, expiresAt, err := s.compile(ctx, exportID, userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to compile data export")
+		// The export's context may be the reason it failed
+		if _, err := s.db.Exec(context.Background(), `
+			UPDATE auth.data_exports
+			SET status = 'failed', error_message = $2, completed_at = NOW()
+			WHERE id = $1
+		`, exportID, failureMessage); err != nil {
+			logger.Error().Err(err).Msg("Failed to record data export failure")
+		}
+		return
+	}
+
+	logger.Info().Msg("Data export completed")
+	if err := s.notify(ctx, recipient, expiresAt); err != nil {
+		logger.Warn().Err(err).Msg("Failed to send data export notification")
+	}
+}
+
+// compile collects a user's data, stores the archive and marks the export completed. It returns
+// the user's email address and the archive's expiry for the notification.
+func (s *Service) compile(ctx context.Context, exportID, userID uuid.UUID) (string, time.Time, error) {
+	if s.storage == nil {
+		return "", time.Time{}, errors.New("storage provider not available")
+	}
+
+	if _, err := s.db.Exec(ctx, `UPDATE auth.data_exports SET status = 'processing' WHERE id = $1`, exportID); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to mark data export processing: %w", err)
+	}
+
+	var recipient string
+	if err := s.db.QueryRow(ctx, `SELECT email FROM auth.users WHERE id = $1`, userID).Scan(&recipient); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	files := make([]archiveFile, 0, len(sections))
+	for _, sec := range sections {
+		var data []byte
+		if err := s.db.QueryRow(ctx, sectionQuery(sec), userID).Scan(&data); err != nil {
+			return "", time.Time{}, fmt.Errorf("failed to collect %s: %w", sec.File, err)
+		}
+		if data == nil {
+			data = []byte("null")
+		}
+		files = append(files, archiveFile{Section: sec, Data: json.RawMessage(data)})
+	}
+
+	var archive bytes.Buffer
+	m := manifest{ExportID: exportID, UserID: userID, GeneratedAt: s.now().UTC()}
+	if err := writeArchive(&archive, s.appName, m, files); err != nil {
+		return "", time.Time{}, err
+	}
+
+	key := objectKey(userID, exportID)
+	if err := s.upload(ctx, key, archive.Bytes()); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to store data export: %w", err)
+	}
+
+	expiresAt := s.now().Add(s.cfg.Retention)
+	_, err := s.db.Exec(ctx, `
+		UPDATE auth.data_exports
+		SET status = 'completed', object_key = $2, size_bytes = $3, completed_at = NOW(), expires_at = $4
+		WHERE id = $1
+	`, exportID, key, archive.Len(), expiresAt)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to mark data export completed: %w", err)
+	}
+	return recipient, expiresAt, nil
+}
+
+// upload writes an archive to the data export bucket, creating the bucket if needed
+func (s *Service) upload(ctx context.Context, key string, data []byte) error {
+	exists, err := s.storage.BucketExists(ctx, s.cfg.Bucket)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if err := s.storage.CreateBucket(ctx, s.cfg.Bucket); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.storage.Upload(ctx, s.cfg.Bucket, key, bytes.NewReader(data), int64(len(data)), &storage.UploadOptions{
+		ContentType: "application/zip",
+	})
+	return err
+}
+
+// removeExpired deletes the archives and rows of a user's expired exports. Failures are only
+// logged, as they are retried with the next request.
+func (s *Service) removeExpired(ctx context.Context, userID uuid.UUID) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id, object_key
+		FROM auth.data_exports
+		WHERE user_id = $1 AND expires_at <= $2
+	`, userID, s.now())
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID.String()).Msg("Failed to list expired data exports")
+		return
+	}
+	type expired struct {
+		id  uuid.UUID
+		key *string
+	}
+	var exports []expired
+	for rows.Next() {
+		var e expired
+		if err := rows.Scan(&e.id, &e.key); err != nil {
+			rows.Close()
+			log.Warn().Err(err).Msg("Failed to scan expired data export")
+			return
+		}
+		exports = append(exports, e)
+	}
+	rows.Close()
+
+	for _, e := range exports {
+		if e.key != nil && s.storage != nil {
+			if err := s.storage.Delete(ctx, s.cfg.Bucket, *e.key); err != nil {
+				log.Warn().Err(err).Str("export_id", e.id.String()).Msg("Failed to delete expired data export archive")
+				continue
+			}
+		}
+		if _, err := s.db.Exec(ctx, `DELETE FROM auth.data_exports WHERE id = $1`, e.id); err != nil {
+			log.Warn().Err(err).Str("export_id", e.id.String()).Msg("Failed to delete expired data export")
+		}
+	}
+}
+
+// notify emails a user that their export is ready to download
+func (s *Service) notify(ctx context.Context, to string, expiresAt time.Time) error {
+	if s.emailService == nil || !s.emailService.IsConfigured() {
+		return nil
+	}
+
+	subject := fmt.Sprintf("Your %s data export is ready", s.appName)
+	return s.emailService.Send(ctx, to, subject, notificationBody(s.appName, expiresAt.UTC().Format("2006-01-02 15:04 MST")))
+}
+
+// notificationBody returns the body of the export-ready email
+func notificationBody(appName, expiresAt string) string {
+	return fmt.Sprintf(`Hello,
+
+The export of your %s account data that you requested is ready. Sign in to download it
+from your account settings. It is available until %s.
+
+If you didn't request this export, change your password right away.
+
+Best regards,
+The %s Team`, appName, expiresAt, appName)
+}
+
+// objectKey returns the bucket key of an export's archive
+func objectKey(userID, exportID uuid.UUID) string {
+	return fmt.Sprintf("%s/%s.zip", userID, exportID)
+}
+
+// scanExport scans the exportColumns of a row
+func scanExport(row pgx.Row) (*Export, error) {
+	var e Export
+	if err := row.Scan(&e.ID, &e.UserID, &e.Status, &e.objectKey, &e.SizeBytes, &e.ErrorMessage,
+		&e.CreatedAt, &e.CompletedAt, &e.ExpiresAt); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
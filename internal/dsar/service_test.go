@@ -0,0 +1,23 @@
+package dsar
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectKey(t *testing.T) {
+	userID := uuid.MustParse("2f9c7e1a-6b3d-4e8f-a0c2-5d4b3a2e1f00")
+	exportID := uuid.MustParse("8a1d5c4e-0f43-4c1b-9a51-3b7a2f6d9e10")
+
+	assert.Equal(t, "2f9c7e1a-6b3d-4e8f-a0c2-5d4b3a2e1f00/8a1d5c4e-0f43-4c1b-9a51-3b7a2f6d9e10.zip", objectKey(userID, exportID))
+}
+
+func TestNotificationBody(t *testing.T) {
+	body := notificationBody("TestApp", "2026-03-08 09:30 UTC")
+
+	assert.Contains(t, body, "The export of your TestApp account data that you requested is ready.")
+	assert.Contains(t, body, "It is available until 2026-03-08 09:30 UTC.")
+	assert.Contains(t, body, "The TestApp Team")
+}
@@ -0,0 +1,358 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/observability"
+	"github.com/rs/zerolog/log"
+)
+
+// Sources of outbound requests, used in metrics and deny logs
+const (
+	SourceFunctions = "functions" // Edge functions and jobs (through the runtime proxy)
+	SourceAI        = "ai"        // AI chat and embedding providers
+	SourceAdmin     = "admin"     // Admin tools that call other Fluxbase instances
+)
+
+// DeniedError is returned when the egress policy blocks a destination
+type DeniedError struct {
+	Host   string
+	Reason string
+}
+
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("egress to %s denied: %s", e.Host, e.Reason)
+}
+
+// IsDenied reports whether err was caused by the egress policy
+func IsDenied(err error) bool {
+	var denied *DeniedError
+	return errors.As(err, &denied)
+}
+
+// Policy decides which destinations outbound requests may reach.
+// Host names are checked against the allowlist before connecting, and every
+// resolved address is checked again at dial time so DNS rebinding cannot
+// reach private networks.
+type Policy struct {
+	allowedHosts    []string
+	privateHosts    map[string]bool
+	proxyURL        *url.URL // upstream proxy, nil to connect directly
+	runtimeProxyURL string   // local proxy used by the Deno runtime
+	metrics         *observability.Metrics
+	dialer          *net.Dialer
+	lookupIP        func(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// NewPolicy creates an egress policy from configuration. metrics may be nil.
+func NewPolicy(cfg *config.EgressConfig, metrics *observability.Metrics) (*Policy, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	p := &Policy{
+		privateHosts: make(map[string]bool, len(cfg.AllowedPrivateHosts)),
+		metrics:      metrics,
+		dialer:       &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+		lookupIP:     net.DefaultResolver.LookupIPAddr,
+	}
+	for _, host := range cfg.AllowedHosts {
+		p.allowedHosts = append(p.allowedHosts, normalizeHost(host))
+	}
+	for _, host := range cfg.AllowedPrivateHosts {
+		p.privateHosts[normalizeHost(host)] = true
+	}
+	if cfg.ProxyURL != "" {
+		u, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid egress proxy_url: %w", err)
+		}
+		p.proxyURL = u
+	}
+
+	return p, nil
+}
+
+// CheckHost checks a destination host name against the policy without resolving it
+func (p *Policy) CheckHost(host string) error {
+	host = normalizeHost(host)
+	if host == "" {
+		return &DeniedError{Host: host, Reason: "missing host"}
+	}
+
+	if len(p.allowedHosts) > 0 && !p.hostAllowed(host) {
+		return &DeniedError{Host: host, Reason: "host is not in allowed_hosts"}
+	}
+	if p.privateHosts[host] {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && isPrivateIP(ip) {
+		return &DeniedError{Host: host, Reason: "private network address"}
+	}
+	if isInternalHostname(host) {
+		return &DeniedError{Host: host, Reason: "internal host name"}
+	}
+	return nil
+}
+
+// hostAllowed matches host against the allowlist; "*.example.com" matches example.com and its subdomains
+func (p *Policy) hostAllowed(host string) bool {
+	for _, allowed := range p.allowedHosts {
+		if base, ok := strings.CutPrefix(allowed, "*."); ok {
+			if host == base || strings.HasSuffix(host, "."+base) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// ExactAllowedHosts returns the allowlist when it only contains exact host names.
+// It returns false when any destination is allowed or the allowlist has wildcards.
+func (p *Policy) ExactAllowedHosts() ([]string, bool) {
+	if len(p.allowedHosts) == 0 {
+		return nil, false
+	}
+	for _, host := range p.allowedHosts {
+		if strings.HasPrefix(host, "*.") {
+			return nil, false
+		}
+	}
+	return append([]string{}, p.allowedHosts...), true
+}
+
+// SetRuntimeProxyURL sets the URL of the local proxy that the Deno runtime sends requests through
+func (p *Policy) SetRuntimeProxyURL(proxyURL string) {
+	p.runtimeProxyURL = proxyURL
+}
+
+// RuntimeProxyURL returns the URL of the local proxy for the Deno runtime, or "" if none is running
+func (p *Policy) RuntimeProxyURL() string {
+	return p.runtimeProxyURL
+}
+
+// check checks a destination host and records a denial
+func (p *Policy) check(source, host string) error {
+	if err := p.CheckHost(host); err != nil {
+		p.recordDenied(source, err)
+		return err
+	}
+	return nil
+}
+
+// recordDenied logs and counts a blocked request
+func (p *Policy) recordDenied(source string, err error) {
+	var denied *DeniedError
+	if !errors.As(err, &denied) {
+		return
+	}
+	log.Warn().
+		Str("source", source).
+		Str("host", denied.Host).
+		Str("reason", denied.Reason).
+		Msg("Outbound request blocked by egress policy")
+	if p.metrics != nil {
+		p.metrics.RecordEgressRequest(source, denied.Host, "denied")
+	}
+}
+
+// recordAllowed counts a request that reached its destination
+func (p *Policy) recordAllowed(source, host string) {
+	if p.metrics != nil {
+		p.metrics.RecordEgressRequest(source, normalizeHost(host), "allowed")
+	}
+}
+
+// dialContext returns a dial function that connects directly to addr after
+// checking every address the host resolves to
+func (p *Policy) dialContext(source string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		var ips []net.IP
+		if ip := net.ParseIP(host); ip != nil {
+			ips = []net.IP{ip}
+		} else {
+			addrs, err := p.lookupIP(ctx, host)
+			if err != nil {
+				return nil, err
+			}
+			for _, a := range addrs {
+				ips = append(ips, a.IP)
+			}
+		}
+
+		// Reject the host if any address is private, like webhook URL validation does
+		if !p.privateHosts[normalizeHost(host)] {
+			for _, ip := range ips {
+				if isPrivateIP(ip) {
+					err := &DeniedError{Host: normalizeHost(host), Reason: fmt.Sprintf("resolves to private network address %s", ip)}
+					p.recordDenied(source, err)
+					return nil, err
+				}
+			}
+		}
+
+		var lastErr error
+		for _, ip := range ips {
+			conn, err := p.dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no addresses found for %s", host)
+		}
+		return nil, lastErr
+	}
+}
+
+// Transport returns an HTTP transport for outbound requests from source that enforces the policy.
+// With an upstream proxy the proxy resolves destinations, so only host names are checked.
+func (p *Policy) Transport(source string) http.RoundTripper {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	if p.proxyURL != nil {
+		base.Proxy = http.ProxyURL(p.proxyURL)
+		base.DialContext = p.dialer.DialContext
+	} else {
+		base.Proxy = nil
+		base.DialContext = p.dialContext(source)
+	}
+
+	return &roundTripper{policy: p, source: source, base: base}
+}
+
+// roundTripper checks every request, including redirects, before sending it
+type roundTripper struct {
+	policy *Policy
+	source string
+	base   *http.Transport
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := rt.policy.check(rt.source, req.URL.Hostname()); err != nil {
+		return nil, err
+	}
+
+	resp, err := rt.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	rt.policy.recordAllowed(rt.source, req.URL.Hostname())
+	return resp, nil
+}
+
+// CloseIdleConnections closes idle connections of the underlying transport
+func (rt *roundTripper) CloseIdleConnections() {
+	rt.base.CloseIdleConnections()
+}
+
+// globalPolicy is the process-wide egress policy. It is set during server
+// initialization when egress.enabled is true, and nil otherwise.
+var (
+	globalPolicy   *Policy
+	globalPolicyMu sync.RWMutex
+)
+
+// SetGlobalPolicy sets the process-wide egress policy
+func SetGlobalPolicy(p *Policy) {
+	globalPolicyMu.Lock()
+	defer globalPolicyMu.Unlock()
+	globalPolicy = p
+}
+
+// GetGlobalPolicy returns the process-wide egress policy, or nil if egress is not restricted
+func GetGlobalPolicy() *Policy {
+	globalPolicyMu.RLock()
+	defer globalPolicyMu.RUnlock()
+	return globalPolicy
+}
+
+// NewHTTPClient returns an HTTP client for outbound requests from source.
+// The global egress policy is enforced when one is set.
+func NewHTTPClient(source string, timeout time.Duration) *http.Client {
+	client := &http.Client{Timeout: timeout}
+	if p := GetGlobalPolicy(); p != nil {
+		client.Transport = p.Transport(source)
+	}
+	return client
+}
+
+// normalizeHost lowercases a host and strips a trailing dot and IPv6 brackets
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	return strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+}
+
+// isInternalHostname reports whether host names a local or cloud-internal service
+func isInternalHostname(host string) bool {
+	if host == "localhost" || host == "ip6-localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	if strings.HasSuffix(host, ".local") || strings.HasSuffix(host, ".internal") {
+		return true
+	}
+
+	blockedHostnames := []string{
+		"metadata",
+		"instance-data",
+		"kubernetes.default",
+		"kubernetes.default.svc",
+		"kubernetes.default.svc.cluster.local",
+	}
+	for _, blocked := range blockedHostnames {
+		if host == blocked || strings.HasSuffix(host, "."+blocked) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPrivateIP checks if an IP address is in a private/internal range
+func isPrivateIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return true
+	}
+
+	privateBlocks := []string{
+		"10.0.0.0/8",     // Private-Use
+		"172.16.0.0/12",  // Private-Use
+		"192.168.0.0/16", // Private-Use
+		"169.254.0.0/16", // Link-Local (cloud metadata endpoints)
+		"127.0.0.0/8",    // Loopback
+		"0.0.0.0/8",      // "This" network
+		"100.64.0.0/10",  // Carrier-Grade NAT
+		"192.0.0.0/24",   // IETF Protocol Assignments
+		"224.0.0.0/4",    // Multicast
+		"240.0.0.0/4",    // Reserved
+		"::1/128",        // IPv6 loopback
+		"fc00::/7",       // IPv6 unique local
+		"fe80::/10",      // IPv6 link local
+	}
+
+	for _, block := range privateBlocks {
+		_, cidr, err := net.ParseCIDR(block)
+		if err != nil {
+			continue
+		}
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package egress
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPolicy(t *testing.T, cfg config.EgressConfig) *Policy {
+	t.Helper()
+	cfg.Enabled = true
+	p, err := NewPolicy(&cfg, nil)
+	require.NoError(t, err)
+	return p
+}
+
+func TestPolicy_CheckHost(t *testing.T) {
+	open := newTestPolicy(t, config.EgressConfig{AllowedPrivateHosts: []string{"ollama"}})
+	restricted := newTestPolicy(t, config.EgressConfig{
+		AllowedHosts:        []string{"api.openai.com", "*.example.com", "ollama"},
+		AllowedPrivateHosts: []string{"ollama"},
+	})
+
+	tests := []struct {
+		name    string
+		policy  *Policy
+		host    string
+		allowed bool
+	}{
+		{"public host without allowlist", open, "api.anthropic.com", true},
+		{"private IP", open, "10.0.0.5", false},
+		{"loopback", open, "127.0.0.1", false},
+		{"IPv6 loopback", open, "[::1]", false},
+		{"cloud metadata IP", open, "169.254.169.254", false},
+		{"localhost", open, "localhost", false},
+		{"cloud metadata host", open, "metadata.google.internal", false},
+		{"kubernetes service", open, "kubernetes.default.svc", false},
+		{"allowed private host", open, "ollama", true},
+		{"exact allowlist match", restricted, "api.openai.com", true},
+		{"case and trailing dot", restricted, "API.OpenAI.com.", true},
+		{"wildcard subdomain", restricted, "hooks.eu.example.com", true},
+		{"wildcard base domain", restricted, "example.com", true},
+		{"suffix without dot", restricted, "badexample.com", false},
+		{"not in allowlist", restricted, "api.anthropic.com", false},
+		{"private host in allowlist", restricted, "ollama", true},
+		{"empty host", open, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.CheckHost(tt.host)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, IsDenied(err), "expected denial, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPolicy_ExactAllowedHosts(t *testing.T) {
+	hosts, ok := newTestPolicy(t, config.EgressConfig{AllowedHosts: []string{"api.openai.com", "Hooks.Slack.com"}}).ExactAllowedHosts()
+	assert.True(t, ok)
+	assert.Equal(t, []string{"api.openai.com", "hooks.slack.com"}, hosts)
+
+	_, ok = newTestPolicy(t, config.EgressConfig{AllowedHosts: []string{"api.openai.com", "*.example.com"}}).ExactAllowedHosts()
+	assert.False(t, ok)
+
+	_, ok = newTestPolicy(t, config.EgressConfig{}).ExactAllowedHosts()
+	assert.False(t, ok)
+}
+
+func TestPolicy_DialRejectsPrivateResolution(t *testing.T) {
+	p := newTestPolicy(t, config.EgressConfig{AllowedHosts: []string{"api.example.com"}})
+	p.lookupIP = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		// A public name that resolves to an internal address (DNS rebinding)
+		return []net.IPAddr{{IP: net.ParseIP("203.0.113.7")}, {IP: net.ParseIP("10.1.2.3")}}, nil
+	}
+
+	_, err := p.dialContext(SourceAI)(context.Background(), "tcp", "api.example.com:443")
+	require.Error(t, err)
+	assert.True(t, IsDenied(err))
+	assert.Contains(t, err.Error(), "10.1.2.3")
+}
+
+func TestPolicy_Transport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	t.Run("blocks private destinations", func(t *testing.T) {
+		client := &http.Client{Transport: newTestPolicy(t, config.EgressConfig{}).Transport(SourceAI), Timeout: 5 * time.Second}
+
+		_, err := client.Get(server.URL)
+		require.Error(t, err)
+		assert.True(t, IsDenied(err))
+	})
+
+	t.Run("allows private hosts that are explicitly listed", func(t *testing.T) {
+		p := newTestPolicy(t, config.EgressConfig{AllowedPrivateHosts: []string{"127.0.0.1"}})
+		client := &http.Client{Transport: p.Transport(SourceAI), Timeout: 5 * time.Second}
+
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "ok", string(body))
+	})
+
+	t.Run("checks redirects", func(t *testing.T) {
+		redirect := httptest.NewServer(http.RedirectHandler("http://169.254.169.254/latest/meta-data/", http.StatusFound))
+		defer redirect.Close()
+
+		p := newTestPolicy(t, config.EgressConfig{AllowedPrivateHosts: []string{"127.0.0.1"}})
+		client := &http.Client{Transport: p.Transport(SourceAI), Timeout: 5 * time.Second}
+
+		_, err := client.Get(redirect.URL)
+		require.Error(t, err)
+		assert.True(t, IsDenied(err))
+	})
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	defer SetGlobalPolicy(nil)
+
+	SetGlobalPolicy(nil)
+	assert.Nil(t, NewHTTPClient(SourceAI, time.Second).Transport, "no policy keeps the default transport")
+
+	SetGlobalPolicy(newTestPolicy(t, config.EgressConfig{}))
+	client := NewHTTPClient(SourceAI, time.Second)
+	assert.IsType(t, &roundTripper{}, client.Transport)
+	assert.Equal(t, time.Second, client.Timeout)
+}
@@ -0,0 +1,250 @@
+package egress
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// hopHeaders are connection-specific headers that a proxy must not forward
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// Proxy is a forward HTTP proxy that applies the egress policy to requests
+// from the Deno runtime. It handles plain HTTP requests and CONNECT tunnels.
+type Proxy struct {
+	policy    *Policy
+	transport http.RoundTripper
+	listener  net.Listener
+	server    *http.Server
+}
+
+// NewProxy creates a runtime proxy for the policy
+func NewProxy(policy *Policy) *Proxy {
+	return &Proxy{
+		policy:    policy,
+		transport: policy.Transport(SourceFunctions),
+	}
+}
+
+// Start listens on addr (e.g. "127.0.0.1:0") and serves in the background
+func (p *Proxy) Start(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to start egress proxy: %w", err)
+	}
+	p.listener = listener
+	p.server = &http.Server{
+		Handler:           p,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := p.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msg("Egress proxy stopped")
+		}
+	}()
+
+	log.Info().Str("address", listener.Addr().String()).Msg("Egress proxy started")
+	return nil
+}
+
+// URL returns the proxy URL to pass to the runtime as HTTP_PROXY/HTTPS_PROXY
+func (p *Proxy) URL() string {
+	if p.listener == nil {
+		return ""
+	}
+	return "http://" + p.listener.Addr().String()
+}
+
+// Shutdown stops the proxy. Open tunnels are closed when their clients disconnect.
+func (p *Proxy) Shutdown(ctx context.Context) error {
+	if p.server == nil {
+		return nil
+	}
+	return p.server.Shutdown(ctx)
+}
+
+// ServeHTTP implements http.Handler
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		p.handleConnect(w, r)
+		return
+	}
+	if !r.URL.IsAbs() {
+		http.Error(w, "egress proxy only accepts proxy requests", http.StatusBadRequest)
+		return
+	}
+	p.handleForward(w, r)
+}
+
+// handleForward sends a plain HTTP request through the policy transport
+func (p *Proxy) handleForward(w http.ResponseWriter, r *http.Request) {
+	outReq := r.Clone(r.Context())
+	outReq.RequestURI = ""
+	removeHopHeaders(outReq.Header)
+
+	resp, err := p.transport.RoundTrip(outReq)
+	if err != nil {
+		writeProxyError(w, err)
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	removeHopHeaders(resp.Header)
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+// handleConnect opens a tunnel to the destination for HTTPS and other TLS traffic
+func (p *Proxy) handleConnect(w http.ResponseWriter, r *http.Request) {
+	host := r.URL.Hostname()
+	if err := p.policy.check(SourceFunctions, host); err != nil {
+		writeProxyError(w, err)
+		return
+	}
+
+	upstream, err := p.dialTunnel(r.Context(), r.Host)
+	if err != nil {
+		writeProxyError(w, err)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		_ = upstream.Close()
+		http.Error(w, "tunneling not supported", http.StatusInternalServerError)
+		return
+	}
+	client, buffered, err := hijacker.Hijack()
+	if err != nil {
+		_ = upstream.Close()
+		return
+	}
+
+	if _, err := client.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		_ = client.Close()
+		_ = upstream.Close()
+		return
+	}
+	p.policy.recordAllowed(SourceFunctions, host)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(upstream, buffered)
+		closeWrite(upstream)
+	}()
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(client, upstream)
+		closeWrite(client)
+	}()
+	wg.Wait()
+
+	_ = client.Close()
+	_ = upstream.Close()
+}
+
+// dialTunnel connects to addr directly or through the upstream proxy
+func (p *Proxy) dialTunnel(ctx context.Context, addr string) (net.Conn, error) {
+	if p.policy.proxyURL == nil {
+		return p.policy.dialContext(SourceFunctions)(ctx, "tcp", addr)
+	}
+
+	proxyURL := p.policy.proxyURL
+	proxyAddr := proxyURL.Host
+	if proxyURL.Port() == "" {
+		port := "80"
+		if proxyURL.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddr = net.JoinHostPort(proxyURL.Hostname(), port)
+	}
+
+	conn, err := p.policy.dialer.DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to upstream proxy: %w", err)
+	}
+	if proxyURL.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname(), MinVersion: tls.VersionTLS12})
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if user := proxyURL.User; user != nil {
+		password, _ := user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to send CONNECT to upstream proxy: %w", err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to read upstream proxy response: %w", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		_ = conn.Close()
+		return nil, fmt.Errorf("upstream proxy refused CONNECT to %s: %s", addr, resp.Status)
+	}
+	return conn, nil
+}
+
+// writeProxyError responds 403 for policy denials and 502 for connection failures
+func writeProxyError(w http.ResponseWriter, err error) {
+	if IsDenied(err) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadGateway)
+}
+
+func removeHopHeaders(header http.Header) {
+	for _, h := range hopHeaders {
+		header.Del(h)
+	}
+}
+
+// closeWrite half-closes a connection so the peer sees EOF
+func closeWrite(conn net.Conn) {
+	if cw, ok := conn.(interface{ CloseWrite() error }); ok {
+		_ = cw.CloseWrite()
+		return
+	}
+	_ = conn.Close()
+}
@@ -0,0 +1,98 @@
+package egress
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startTestProxy(t *testing.T, cfg config.EgressConfig) *Proxy {
+	t.Helper()
+	proxy := NewProxy(newTestPolicy(t, cfg))
+	require.NoError(t, proxy.Start("127.0.0.1:0"))
+	t.Cleanup(func() { _ = proxy.Shutdown(t.Context()) })
+	return proxy
+}
+
+func proxiedClient(t *testing.T, proxy *Proxy, base *http.Transport) *http.Client {
+	t.Helper()
+	proxyURL, err := url.Parse(proxy.URL())
+	require.NoError(t, err)
+	base.Proxy = http.ProxyURL(proxyURL)
+	return &http.Client{Transport: base, Timeout: 5 * time.Second}
+}
+
+func TestProxy_Forward(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Proxy-Connection"))
+		_, _ = w.Write([]byte("hello " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	t.Run("allowed", func(t *testing.T) {
+		proxy := startTestProxy(t, config.EgressConfig{AllowedPrivateHosts: []string{"127.0.0.1"}})
+		client := proxiedClient(t, proxy, &http.Transport{})
+
+		resp, err := client.Get(server.URL + "/world")
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "hello /world", string(body))
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		proxy := startTestProxy(t, config.EgressConfig{})
+		client := proxiedClient(t, proxy, &http.Transport{})
+
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		assert.Contains(t, string(body), "private network address")
+	})
+}
+
+func TestProxy_Connect(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("secure"))
+	}))
+	defer server.Close()
+
+	t.Run("tunnels allowed hosts", func(t *testing.T) {
+		proxy := startTestProxy(t, config.EgressConfig{AllowedPrivateHosts: []string{"127.0.0.1"}})
+		client := proxiedClient(t, proxy, server.Client().Transport.(*http.Transport).Clone())
+
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "secure", string(body))
+	})
+
+	t.Run("refuses hosts outside the allowlist", func(t *testing.T) {
+		proxy := startTestProxy(t, config.EgressConfig{AllowedHosts: []string{"api.openai.com"}})
+		client := proxiedClient(t, proxy, server.Client().Transport.(*http.Transport).Clone())
+
+		_, err := client.Get(server.URL)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Forbidden")
+	})
+}
+
+func TestProxy_RejectsDirectRequests(t *testing.T) {
+	proxy := startTestProxy(t, config.EgressConfig{})
+
+	resp, err := http.Get(proxy.URL() + "/")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
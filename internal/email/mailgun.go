@@ -65,11 +65,16 @@ func (s *MailgunService) SendInvitationEmail(ctx context.Context, to, inviterNam
 
 // Send sends a generic email via Mailgun
 func (s *MailgunService) Send(ctx context.Context, to, subject, body string) error {
+	return s.SendWithText(ctx, to, subject, body, "")
+}
+
+// SendWithText sends an email with an HTML body and a plain text alternative via Mailgun
+func (s *MailgunService) SendWithText(ctx context.Context, to, subject, body, textBody string) error {
 	message := mailgun.NewMessage(
 		s.domain,
 		fmt.Sprintf("%s <%s>", s.config.FromName, s.config.FromAddress),
 		subject,
-		"", // Plain text body (optional)
+		textBody, // Plain text body (optional)
 		to,
 	)
 
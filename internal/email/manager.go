@@ -2,7 +2,9 @@ package email
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/nimbleflux/fluxbase/internal/auth"
 	"github.com/nimbleflux/fluxbase/internal/config"
@@ -13,10 +15,25 @@ import (
 // Manager manages the email service with support for dynamic configuration refresh
 type Manager struct {
 	mu             sync.RWMutex
-	service        Service
+	service        Service  // default profile
+	routing        *routing // named profiles and routing rules; nil routes everything to service
 	settingsCache  *auth.SettingsCache
 	secretsService *settings.SecretsService
 	envConfig      *config.EmailConfig // Fallback to env config
+	templates      *TemplateStore      // customized templates; nil uses the built-in templates
+	fromName       string
+
+	deliveryMu       sync.Mutex
+	delivery         DeliveryStatus
+	deliveryObserver func(DeliveryStatus)
+}
+
+// DeliveryStatus summarizes the outcome of recent email deliveries on this node
+type DeliveryStatus struct {
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastFailureAt       time.Time `json:"last_failure_at,omitempty"`
+	LastSuccessAt       time.Time `json:"last_success_at,omitempty"`
 }
 
 // NewManager creates a new email service manager
@@ -34,6 +51,10 @@ func NewManager(envConfig *config.EmailConfig, settingsCache *auth.SettingsCache
 		service = NewNoOpService("initialization failed: " + err.Error())
 	}
 	m.service = service
+	if envConfig != nil {
+		m.routing = newRouting(envConfig)
+		m.fromName = envConfig.FromName
+	}
 
 	return m
 }
@@ -45,6 +66,42 @@ func (m *Manager) GetService() Service {
 	return m.service
 }
 
+// DeliveryStatus returns the outcome of recent deliveries through the wrapped service
+func (m *Manager) DeliveryStatus() DeliveryStatus {
+	m.deliveryMu.Lock()
+	defer m.deliveryMu.Unlock()
+	return m.delivery
+}
+
+// SetDeliveryObserver registers a callback invoked after every failed delivery
+// and after the first successful delivery following a failure
+func (m *Manager) SetDeliveryObserver(fn func(DeliveryStatus)) {
+	m.deliveryMu.Lock()
+	defer m.deliveryMu.Unlock()
+	m.deliveryObserver = fn
+}
+
+// recordDelivery updates the delivery status with the result of a send
+func (m *Manager) recordDelivery(err error) {
+	m.deliveryMu.Lock()
+	recovered := err == nil && m.delivery.ConsecutiveFailures > 0
+	if err != nil {
+		m.delivery.ConsecutiveFailures++
+		m.delivery.LastError = err.Error()
+		m.delivery.LastFailureAt = time.Now()
+	} else {
+		m.delivery.ConsecutiveFailures = 0
+		m.delivery.LastSuccessAt = time.Now()
+	}
+	status := m.delivery
+	observer := m.deliveryObserver
+	m.deliveryMu.Unlock()
+
+	if observer != nil && (err != nil || recovered) {
+		observer(status)
+	}
+}
+
 // SetSettingsCache sets the settings cache for dynamic configuration
 func (m *Manager) SetSettingsCache(cache *auth.SettingsCache) {
 	m.mu.Lock()
@@ -59,6 +116,26 @@ func (m *Manager) SetSecretsService(svc *settings.SecretsService) {
 	m.secretsService = svc
 }
 
+// SetTemplateStore sets the store of customized templates. Auth emails of a customized
+// template type are rendered from the variant for the recipient's locale.
+func (m *Manager) SetTemplateStore(store *TemplateStore) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.templates = store
+}
+
+// templateStore returns the store of customized templates and the name of the sender,
+// which templates use as AppName
+func (m *Manager) templateStore() (*TemplateStore, string) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	appName := m.fromName
+	if appName == "" {
+		appName = "Fluxbase"
+	}
+	return m.templates, appName
+}
+
 // RefreshFromSettings rebuilds the email service from database settings
 func (m *Manager) RefreshFromSettings(ctx context.Context) error {
 	// Build config from settings cache
@@ -71,9 +148,11 @@ func (m *Manager) RefreshFromSettings(ctx context.Context) error {
 		return err
 	}
 
-	// Swap service
+	// Swap service and profiles
 	m.mu.Lock()
 	m.service = service
+	m.routing = newRouting(cfg)
+	m.fromName = cfg.FromName
 	m.mu.Unlock()
 
 	log.Info().
@@ -162,29 +241,141 @@ func (m *Manager) WrapAsService() Service {
 	return &ServiceWrapper{manager: m}
 }
 
+// profileChain returns the profile for an email followed by its failover profiles
+func (m *Manager) profileChain(category Category, namespace string) []*profile {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.routing.chain(m.routing.resolve(category, namespace), m.service)
+}
+
+// deliver sends through the profile routed for the email's category and namespace,
+// failing over along the profile's failover chain when a send fails or a profile is over
+// its quota, and records the result.
+// Sends rejected because email is not configured are not delivery failures.
+func (w *ServiceWrapper) deliver(ctx context.Context, category Category, send func(Service) error) error {
+	category = categoryFromContext(ctx, category)
+	chain := w.manager.profileChain(category, namespaceFromContext(ctx))
+
+	var err error
+	attempted := false
+	for i, p := range chain {
+		if !p.service.IsConfigured() {
+			continue
+		}
+		if !reserveQuota(ctx, p) {
+			log.Warn().Str("profile", p.name).Msg("Email profile is over its hourly quota")
+			err = fmt.Errorf("%w for profile %q", ErrQuotaExceeded, p.name)
+			continue
+		}
+
+		attempted = true
+		if err = send(p.service); err == nil {
+			break
+		}
+		if i < len(chain)-1 {
+			log.Warn().Err(err).Str("profile", p.name).Str("category", string(category)).Msg("Email send failed, trying failover profile")
+		}
+	}
+
+	if !attempted {
+		if err != nil {
+			return err
+		}
+		// Nothing is configured; let the routed service report why
+		return send(chain[0].service)
+	}
+
+	w.manager.recordDelivery(err)
+	return err
+}
+
+// sendTemplate sends an email with the customized template of a type that best matches the
+// recipient's locale. It reports false without sending if the type isn't customized, so that
+// the caller falls back to the built-in template.
+func (w *ServiceWrapper) sendTemplate(ctx context.Context, to, templateType string, data map[string]string) (bool, error) {
+	store, appName := w.manager.templateStore()
+	if store == nil {
+		return false, nil
+	}
+
+	locale, err := store.UserLocale(ctx, to)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to look up recipient locale, using the default template")
+	}
+	tmpl, err := store.Resolve(ctx, templateType, locale)
+	if err != nil {
+		log.Warn().Err(err).Str("template_type", templateType).Msg("Failed to load email template, using built-in template")
+		return false, nil
+	}
+	if tmpl == nil {
+		return false, nil
+	}
+
+	data["AppName"] = appName
+	data["Email"] = to
+	data["Locale"] = locale
+	rendered, err := tmpl.Render(data)
+	if err != nil {
+		log.Warn().Err(err).Str("template_type", templateType).Str("locale", tmpl.Locale).Msg("Failed to render email template, using built-in template")
+		return false, nil
+	}
+
+	return true, w.deliver(ctx, CategoryAuth, func(s Service) error { return SendRendered(ctx, s, to, rendered) })
+}
+
 // SendMagicLink implements Service
 func (w *ServiceWrapper) SendMagicLink(ctx context.Context, to, token, link string) error {
-	return w.manager.GetService().SendMagicLink(ctx, to, token, link)
+	if sent, err := w.sendTemplate(ctx, to, TemplateMagicLink, map[string]string{"MagicLink": link, "Link": link, "Token": token}); sent {
+		return err
+	}
+	return w.deliver(ctx, CategoryAuth, func(s Service) error { return s.SendMagicLink(ctx, to, token, link) })
 }
 
 // SendVerificationEmail implements Service
 func (w *ServiceWrapper) SendVerificationEmail(ctx context.Context, to, token, link string) error {
-	return w.manager.GetService().SendVerificationEmail(ctx, to, token, link)
+	if sent, err := w.sendTemplate(ctx, to, TemplateEmailVerification, map[string]string{"VerificationLink": link, "Link": link, "Token": token}); sent {
+		return err
+	}
+	return w.deliver(ctx, CategoryAuth, func(s Service) error { return s.SendVerificationEmail(ctx, to, token, link) })
 }
 
 // SendPasswordReset implements Service
 func (w *ServiceWrapper) SendPasswordReset(ctx context.Context, to, token, link string) error {
-	return w.manager.GetService().SendPasswordReset(ctx, to, token, link)
+	if sent, err := w.sendTemplate(ctx, to, TemplatePasswordReset, map[string]string{"ResetLink": link, "Link": link, "Token": token}); sent {
+		return err
+	}
+	return w.deliver(ctx, CategoryAuth, func(s Service) error { return s.SendPasswordReset(ctx, to, token, link) })
 }
 
 // SendInvitationEmail implements Service
 func (w *ServiceWrapper) SendInvitationEmail(ctx context.Context, to, inviterName, inviteLink string) error {
-	return w.manager.GetService().SendInvitationEmail(ctx, to, inviterName, inviteLink)
+	if sent, err := w.sendTemplate(ctx, to, TemplateInvitation, map[string]string{"InviteLink": inviteLink, "InviterName": inviterName}); sent {
+		return err
+	}
+	return w.deliver(ctx, CategoryAuth, func(s Service) error { return s.SendInvitationEmail(ctx, to, inviterName, inviteLink) })
+}
+
+// SendOTP sends an OTP code with the customized otp template. It reports false without
+// sending if the otp template isn't customized.
+func (w *ServiceWrapper) SendOTP(ctx context.Context, to, code, purpose string) (bool, error) {
+	return w.sendTemplate(ctx, to, TemplateOTP, map[string]string{"Code": code, "Purpose": purpose})
 }
 
-// Send implements Service
+// SendNewDeviceAlert sends a sign-in alert for a new device with the customized new_device
+// template. It reports false without sending if the new_device template isn't customized.
+func (w *ServiceWrapper) SendNewDeviceAlert(ctx context.Context, to string, data map[string]string) (bool, error) {
+	return w.sendTemplate(ctx, to, TemplateNewDevice, data)
+}
+
+// Send implements Service. Generic email is a notification unless the context sets a category.
 func (w *ServiceWrapper) Send(ctx context.Context, to, subject, body string) error {
-	return w.manager.GetService().Send(ctx, to, subject, body)
+	return w.deliver(ctx, CategoryNotification, func(s Service) error { return s.Send(ctx, to, subject, body) })
+}
+
+// SendWithText sends an email with a plain text alternative if the routed provider supports one
+func (w *ServiceWrapper) SendWithText(ctx context.Context, to, subject, body, textBody string) error {
+	rendered := &RenderedEmail{Subject: subject, HTMLBody: body, TextBody: textBody}
+	return w.deliver(ctx, CategoryNotification, func(s Service) error { return SendRendered(ctx, s, to, rendered) })
 }
 
 // IsConfigured implements Service
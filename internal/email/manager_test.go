@@ -2,6 +2,7 @@ package email
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/nimbleflux/fluxbase/internal/config"
@@ -199,3 +200,51 @@ func TestManager_Concurrency(t *testing.T) {
 		}
 	})
 }
+
+// failingEmailService is a configured service whose sends always fail
+type failingEmailService struct {
+	TestEmailService
+}
+
+func (s *failingEmailService) Send(ctx context.Context, to, subject, body string) error {
+	return errors.New("535 authentication failed")
+}
+
+func TestServiceWrapper_RecordsDeliveryStatus(t *testing.T) {
+	manager := &Manager{service: &failingEmailService{}}
+	wrapper := manager.WrapAsService()
+
+	var observed []DeliveryStatus
+	manager.SetDeliveryObserver(func(status DeliveryStatus) {
+		observed = append(observed, status)
+	})
+
+	ctx := context.Background()
+	require.Error(t, wrapper.Send(ctx, "user@example.com", "Subject", "Body"))
+	require.Error(t, wrapper.Send(ctx, "user@example.com", "Subject", "Body"))
+
+	status := manager.DeliveryStatus()
+	assert.Equal(t, 2, status.ConsecutiveFailures)
+	assert.Equal(t, "535 authentication failed", status.LastError)
+	assert.False(t, status.LastFailureAt.IsZero())
+	require.Len(t, observed, 2)
+
+	// The first success after failures is reported, later successes are not
+	manager.service = NewTestEmailService()
+	require.NoError(t, wrapper.Send(ctx, "user@example.com", "Subject", "Body"))
+	require.NoError(t, wrapper.Send(ctx, "user@example.com", "Subject", "Body"))
+
+	status = manager.DeliveryStatus()
+	assert.Equal(t, 0, status.ConsecutiveFailures)
+	assert.False(t, status.LastSuccessAt.IsZero())
+	require.Len(t, observed, 3)
+	assert.Equal(t, 0, observed[2].ConsecutiveFailures)
+}
+
+func TestServiceWrapper_IgnoresUnconfiguredService(t *testing.T) {
+	manager := NewManager(&config.EmailConfig{Enabled: false}, nil, nil)
+	wrapper := manager.WrapAsService()
+
+	require.Error(t, wrapper.Send(context.Background(), "user@example.com", "Subject", "Body"))
+	assert.Equal(t, 0, manager.DeliveryStatus().ConsecutiveFailures)
+}
@@ -0,0 +1,255 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/ratelimit"
+	"github.com/rs/zerolog/log"
+)
+
+// Category classifies an email for routing to a provider profile
+type Category string
+
+const (
+	// CategoryAuth covers sign-in, verification, password reset, invitation and OTP emails
+	CategoryAuth Category = "auth"
+	// CategoryNotification covers everything else, such as escalation and notification emails
+	CategoryNotification Category = "notification"
+)
+
+// DefaultProfile is the name of the profile configured by the top-level email settings
+const DefaultProfile = "default"
+
+// ErrQuotaExceeded is returned when every profile that could send an email is over its quota
+var ErrQuotaExceeded = errors.New("email send quota exceeded")
+
+type contextKey int
+
+const (
+	categoryContextKey contextKey = iota
+	namespaceContextKey
+)
+
+// WithCategory returns a context that routes emails sent with it as the given category
+func WithCategory(ctx context.Context, category Category) context.Context {
+	return context.WithValue(ctx, categoryContextKey, category)
+}
+
+// WithNamespace returns a context that routes emails sent with it using the namespace's profile
+func WithNamespace(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, namespaceContextKey, namespace)
+}
+
+// categoryFromContext returns the category set with WithCategory, or fallback
+func categoryFromContext(ctx context.Context, fallback Category) Category {
+	if category, ok := ctx.Value(categoryContextKey).(Category); ok && category != "" {
+		return category
+	}
+	return fallback
+}
+
+// namespaceFromContext returns the namespace set with WithNamespace
+func namespaceFromContext(ctx context.Context) string {
+	namespace, _ := ctx.Value(namespaceContextKey).(string)
+	return namespace
+}
+
+// ForCategory returns a Service that sends every email as the given category, unless the
+// context already sets one. Use it to route generic sends such as OTP codes as auth email.
+func ForCategory(service Service, category Category) Service {
+	return &categoryService{service: service, category: category}
+}
+
+// categoryService tags every send with a category
+type categoryService struct {
+	service  Service
+	category Category
+}
+
+func (s *categoryService) withCategory(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(categoryContextKey).(Category); ok {
+		return ctx
+	}
+	return WithCategory(ctx, s.category)
+}
+
+// SendMagicLink implements Service
+func (s *categoryService) SendMagicLink(ctx context.Context, to, token, link string) error {
+	return s.service.SendMagicLink(s.withCategory(ctx), to, token, link)
+}
+
+// SendVerificationEmail implements Service
+func (s *categoryService) SendVerificationEmail(ctx context.Context, to, token, link string) error {
+	return s.service.SendVerificationEmail(s.withCategory(ctx), to, token, link)
+}
+
+// SendPasswordReset implements Service
+func (s *categoryService) SendPasswordReset(ctx context.Context, to, token, link string) error {
+	return s.service.SendPasswordReset(s.withCategory(ctx), to, token, link)
+}
+
+// SendInvitationEmail implements Service
+func (s *categoryService) SendInvitationEmail(ctx context.Context, to, inviterName, inviteLink string) error {
+	return s.service.SendInvitationEmail(s.withCategory(ctx), to, inviterName, inviteLink)
+}
+
+// Send implements Service
+func (s *categoryService) Send(ctx context.Context, to, subject, body string) error {
+	return s.service.Send(s.withCategory(ctx), to, subject, body)
+}
+
+// SendWithText sends an email with a plain text alternative if the wrapped service supports one
+func (s *categoryService) SendWithText(ctx context.Context, to, subject, body, textBody string) error {
+	return SendRendered(s.withCategory(ctx), s.service, to, &RenderedEmail{Subject: subject, HTMLBody: body, TextBody: textBody})
+}
+
+// SendOTP sends an OTP code with the customized otp template if the wrapped service has one
+func (s *categoryService) SendOTP(ctx context.Context, to, code, purpose string) (bool, error) {
+	if ts, ok := s.service.(otpTemplateSender); ok {
+		return ts.SendOTP(s.withCategory(ctx), to, code, purpose)
+	}
+	return false, nil
+}
+
+// SendNewDeviceAlert sends a new-device alert with the customized new_device template if the
+// wrapped service has one
+func (s *categoryService) SendNewDeviceAlert(ctx context.Context, to string, data map[string]string) (bool, error) {
+	if ts, ok := s.service.(newDeviceTemplateSender); ok {
+		return ts.SendNewDeviceAlert(s.withCategory(ctx), to, data)
+	}
+	return false, nil
+}
+
+// IsConfigured implements Service
+func (s *categoryService) IsConfigured() bool {
+	return s.service.IsConfigured()
+}
+
+// profile is a provider service with its send quota and failover chain
+type profile struct {
+	name       string
+	service    Service
+	maxPerHour int
+	failover   []string
+}
+
+// routing holds the named profiles and routing rules from the email configuration.
+// The default profile's service is the manager's current service, so it is not stored here.
+type routing struct {
+	profiles          map[string]*profile
+	categories        map[string]string
+	namespaces        map[string]string
+	defaultMaxPerHour int
+	defaultFailover   []string
+}
+
+// newRouting builds the profiles and routing rules of cfg. Profiles inherit the enabled
+// flag of the top-level configuration; a profile that fails to initialize is kept as a
+// NoOpService so routing and failover still work.
+func newRouting(cfg *config.EmailConfig) *routing {
+	r := &routing{
+		profiles:          make(map[string]*profile, len(cfg.Profiles)),
+		categories:        cfg.Routing.Categories,
+		namespaces:        cfg.Routing.Namespaces,
+		defaultMaxPerHour: cfg.MaxPerHour,
+		defaultFailover:   cfg.Failover,
+	}
+
+	for _, p := range cfg.Profiles {
+		profileCfg := p.EmailConfig
+		profileCfg.Enabled = cfg.Enabled
+
+		service, err := NewService(&profileCfg)
+		if err != nil {
+			log.Warn().Err(err).Str("profile", p.Name).Msg("Failed to initialize email profile, using NoOpService")
+			service = NewNoOpService("initialization failed: " + err.Error())
+		} else if !service.IsConfigured() {
+			service = NewNoOpService(fmt.Sprintf("email profile %q is not fully configured", p.Name))
+		}
+
+		r.profiles[p.Name] = &profile{
+			name:       p.Name,
+			service:    service,
+			maxPerHour: p.MaxPerHour,
+			failover:   p.Failover,
+		}
+	}
+
+	return r
+}
+
+// resolve returns the profile name for an email: a namespace route wins over a category
+// route, and anything unrouted uses the default profile
+func (r *routing) resolve(category Category, namespace string) string {
+	if r == nil {
+		return DefaultProfile
+	}
+	if namespace != "" {
+		if name, ok := r.namespaces[namespace]; ok {
+			return name
+		}
+	}
+	if name, ok := r.categories[string(category)]; ok {
+		return name
+	}
+	return DefaultProfile
+}
+
+// chain returns the routed profile followed by its failover profiles, skipping unknown
+// names and duplicates
+func (r *routing) chain(name string, defaultService Service) []*profile {
+	lookup := func(name string) *profile {
+		if name == DefaultProfile {
+			p := &profile{name: DefaultProfile, service: defaultService}
+			if r != nil {
+				p.maxPerHour = r.defaultMaxPerHour
+				p.failover = r.defaultFailover
+			}
+			return p
+		}
+		if r == nil {
+			return nil
+		}
+		return r.profiles[name]
+	}
+
+	first := lookup(name)
+	if first == nil {
+		first = lookup(DefaultProfile)
+	}
+
+	chain := []*profile{first}
+	seen := map[string]bool{first.name: true}
+	for _, next := range first.failover {
+		if seen[next] {
+			continue
+		}
+		if p := lookup(next); p != nil {
+			chain = append(chain, p)
+			seen[next] = true
+		}
+	}
+	return chain
+}
+
+// reserveQuota counts a send against the profile's hourly quota and reports whether it is
+// allowed. Quotas are counted in the shared rate limit store, so they hold across instances.
+// If the store fails, sends are allowed.
+func reserveQuota(ctx context.Context, p *profile) bool {
+	if p.maxPerHour <= 0 {
+		return true
+	}
+
+	store := ratelimit.GetGlobalStore()
+	key := "email_profile:" + p.name
+	result, err := ratelimit.Check(ctx, store, key, int64(p.maxPerHour), time.Hour)
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("Email quota check failed")
+		return true
+	}
+	return result.Allowed
+}
@@ -0,0 +1,222 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingEmailService records the recipients of every email it sends
+type recordingEmailService struct {
+	TestEmailService
+	sent []string
+	err  error
+}
+
+func (s *recordingEmailService) SendMagicLink(ctx context.Context, to, token, link string) error {
+	return s.Send(ctx, to, "", "")
+}
+
+func (s *recordingEmailService) Send(ctx context.Context, to, subject, body string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.sent = append(s.sent, to)
+	return nil
+}
+
+// newRoutedManager returns a manager with the given routing and default profile service
+func newRoutedManager(r *routing, defaultService Service) *Manager {
+	return &Manager{service: defaultService, routing: r}
+}
+
+func TestRouting_Resolve(t *testing.T) {
+	r := &routing{
+		categories: map[string]string{"notification": "bulk"},
+		namespaces: map[string]string{"tenant-a": "tenant"},
+	}
+
+	assert.Equal(t, "bulk", r.resolve(CategoryNotification, ""))
+	assert.Equal(t, DefaultProfile, r.resolve(CategoryAuth, ""))
+	assert.Equal(t, "tenant", r.resolve(CategoryNotification, "tenant-a"), "namespace route wins over category route")
+	assert.Equal(t, "bulk", r.resolve(CategoryNotification, "tenant-b"))
+
+	var none *routing
+	assert.Equal(t, DefaultProfile, none.resolve(CategoryNotification, "tenant-a"))
+}
+
+func TestRouting_Chain(t *testing.T) {
+	defaultService := NewTestEmailService()
+	r := &routing{
+		profiles: map[string]*profile{
+			"bulk":   {name: "bulk", service: NewTestEmailService(), failover: []string{"backup", "missing", "backup", "default"}},
+			"backup": {name: "backup", service: NewTestEmailService()},
+		},
+		defaultFailover: []string{"backup"},
+	}
+
+	names := func(chain []*profile) []string {
+		out := make([]string, 0, len(chain))
+		for _, p := range chain {
+			out = append(out, p.name)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"bulk", "backup", "default"}, names(r.chain("bulk", defaultService)))
+	assert.Equal(t, []string{"default", "backup"}, names(r.chain("default", defaultService)))
+	assert.Equal(t, []string{"default", "backup"}, names(r.chain("unknown", defaultService)), "unknown profiles fall back to default")
+
+	var none *routing
+	chain := none.chain(DefaultProfile, defaultService)
+	require.Len(t, chain, 1)
+	assert.Same(t, defaultService, chain[0].service)
+}
+
+func TestNewRouting(t *testing.T) {
+	r := newRouting(&config.EmailConfig{
+		Enabled:    true,
+		MaxPerHour: 500,
+		Failover:   []string{"backup"},
+		Profiles: []config.EmailProfileConfig{
+			{Name: "backup", EmailConfig: config.EmailConfig{
+				Provider:    "smtp",
+				FromAddress: "noreply@example.com",
+				SMTPHost:    "smtp.example.com",
+				SMTPPort:    587,
+				MaxPerHour:  100,
+			}},
+			{Name: "incomplete", EmailConfig: config.EmailConfig{Provider: "sendgrid"}},
+		},
+		Routing: config.EmailRoutingConfig{Categories: map[string]string{"notification": "backup"}},
+	})
+
+	require.Len(t, r.profiles, 2)
+	assert.True(t, r.profiles["backup"].service.IsConfigured(), "profiles inherit the enabled flag")
+	assert.Equal(t, 100, r.profiles["backup"].maxPerHour)
+	assert.False(t, r.profiles["incomplete"].service.IsConfigured())
+	assert.Equal(t, 500, r.defaultMaxPerHour)
+	assert.Equal(t, []string{"backup"}, r.defaultFailover)
+	assert.Equal(t, "backup", r.resolve(CategoryNotification, ""))
+}
+
+func TestServiceWrapper_RoutesByCategoryAndNamespace(t *testing.T) {
+	defaultService := &recordingEmailService{}
+	bulk := &recordingEmailService{}
+	tenant := &recordingEmailService{}
+	manager := newRoutedManager(&routing{
+		profiles: map[string]*profile{
+			"bulk":   {name: "bulk", service: bulk},
+			"tenant": {name: "tenant", service: tenant},
+		},
+		categories: map[string]string{"notification": "bulk"},
+		namespaces: map[string]string{"tenant-a": "tenant"},
+	}, defaultService)
+	wrapper := manager.WrapAsService()
+	ctx := context.Background()
+
+	require.NoError(t, wrapper.SendMagicLink(ctx, "auth@example.com", "token", "link"))
+	require.NoError(t, wrapper.Send(ctx, "notification@example.com", "Subject", "Body"))
+	require.NoError(t, wrapper.Send(WithNamespace(ctx, "tenant-a"), "tenant@example.com", "Subject", "Body"))
+	require.NoError(t, wrapper.Send(WithCategory(ctx, CategoryAuth), "otp@example.com", "Subject", "Body"))
+
+	assert.Equal(t, []string{"auth@example.com", "otp@example.com"}, defaultService.sent)
+	assert.Equal(t, []string{"notification@example.com"}, bulk.sent)
+	assert.Equal(t, []string{"tenant@example.com"}, tenant.sent)
+}
+
+func TestServiceWrapper_FailsOver(t *testing.T) {
+	defaultService := &recordingEmailService{}
+	bulk := &recordingEmailService{err: errors.New("421 service not available")}
+	manager := newRoutedManager(&routing{
+		profiles: map[string]*profile{
+			"bulk": {name: "bulk", service: bulk, failover: []string{"default"}},
+		},
+		categories: map[string]string{"notification": "bulk"},
+	}, defaultService)
+	wrapper := manager.WrapAsService()
+
+	require.NoError(t, wrapper.Send(context.Background(), "user@example.com", "Subject", "Body"))
+	assert.Equal(t, []string{"user@example.com"}, defaultService.sent)
+	assert.Equal(t, 0, manager.DeliveryStatus().ConsecutiveFailures)
+
+	// Without a working failover the last error is returned and recorded
+	defaultService.err = errors.New("535 authentication failed")
+	err := wrapper.Send(context.Background(), "user@example.com", "Subject", "Body")
+	require.EqualError(t, err, "535 authentication failed")
+	assert.Equal(t, 1, manager.DeliveryStatus().ConsecutiveFailures)
+}
+
+func TestServiceWrapper_SkipsUnconfiguredProfiles(t *testing.T) {
+	defaultService := &recordingEmailService{}
+	manager := newRoutedManager(&routing{
+		profiles: map[string]*profile{
+			"bulk": {name: "bulk", service: NewNoOpService("not configured"), failover: []string{"default"}},
+		},
+		categories: map[string]string{"notification": "bulk"},
+	}, defaultService)
+
+	require.NoError(t, manager.WrapAsService().Send(context.Background(), "user@example.com", "Subject", "Body"))
+	assert.Equal(t, []string{"user@example.com"}, defaultService.sent)
+}
+
+func TestServiceWrapper_Quota(t *testing.T) {
+	ctx := context.Background()
+	store := ratelimit.GetGlobalStore()
+	for _, name := range []string{"quota-test-bulk", "quota-test-capped"} {
+		require.NoError(t, store.Reset(ctx, "email_profile:"+name))
+		t.Cleanup(func() { _ = store.Reset(ctx, "email_profile:"+name) })
+	}
+
+	defaultService := &recordingEmailService{}
+	bulk := &recordingEmailService{}
+	capped := &recordingEmailService{}
+	manager := newRoutedManager(&routing{
+		profiles: map[string]*profile{
+			"quota-test-bulk":   {name: "quota-test-bulk", service: bulk, maxPerHour: 1, failover: []string{"default"}},
+			"quota-test-capped": {name: "quota-test-capped", service: capped, maxPerHour: 1},
+		},
+		categories: map[string]string{"notification": "quota-test-bulk", "auth": "quota-test-capped"},
+	}, defaultService)
+	wrapper := manager.WrapAsService()
+
+	t.Run("fails over once the quota is used", func(t *testing.T) {
+		require.NoError(t, wrapper.Send(ctx, "first@example.com", "Subject", "Body"))
+		require.NoError(t, wrapper.Send(ctx, "second@example.com", "Subject", "Body"))
+
+		assert.Equal(t, []string{"first@example.com"}, bulk.sent)
+		assert.Equal(t, []string{"second@example.com"}, defaultService.sent)
+	})
+
+	t.Run("returns ErrQuotaExceeded without a failover", func(t *testing.T) {
+		require.NoError(t, wrapper.SendMagicLink(ctx, "first@example.com", "token", "link"))
+		err := wrapper.SendMagicLink(ctx, "second@example.com", "token", "link")
+
+		require.ErrorIs(t, err, ErrQuotaExceeded)
+		assert.Equal(t, []string{"first@example.com"}, capped.sent)
+		assert.Equal(t, 0, manager.DeliveryStatus().ConsecutiveFailures, "quota rejections are not delivery failures")
+	})
+}
+
+func TestForCategory(t *testing.T) {
+	defaultService := &recordingEmailService{}
+	bulk := &recordingEmailService{}
+	manager := newRoutedManager(&routing{
+		profiles:   map[string]*profile{"bulk": {name: "bulk", service: bulk}},
+		categories: map[string]string{"notification": "bulk"},
+	}, defaultService)
+	authEmail := ForCategory(manager.WrapAsService(), CategoryAuth)
+	ctx := context.Background()
+
+	require.NoError(t, authEmail.Send(ctx, "otp@example.com", "Subject", "Body"))
+	require.NoError(t, authEmail.Send(WithCategory(ctx, CategoryNotification), "news@example.com", "Subject", "Body"))
+
+	assert.Equal(t, []string{"otp@example.com"}, defaultService.sent)
+	assert.Equal(t, []string{"news@example.com"}, bulk.sent, "a category set on the context takes precedence")
+	assert.True(t, authEmail.IsConfigured())
+}
@@ -60,9 +60,14 @@ func (s *SendGridService) SendInvitationEmail(ctx context.Context, to, inviterNa
 
 // Send sends a generic email via SendGrid
 func (s *SendGridService) Send(ctx context.Context, to, subject, body string) error {
+	return s.SendWithText(ctx, to, subject, body, "")
+}
+
+// SendWithText sends an email with an HTML body and a plain text alternative via SendGrid
+func (s *SendGridService) SendWithText(ctx context.Context, to, subject, body, textBody string) error {
 	from := mail.NewEmail(s.config.FromName, s.config.FromAddress)
 	toEmail := mail.NewEmail("", to)
-	message := mail.NewSingleEmail(from, subject, toEmail, "", body)
+	message := mail.NewSingleEmail(from, subject, toEmail, textBody, body)
 
 	// Set reply-to if configured
 	if s.config.ReplyToAddress != "" {
@@ -28,6 +28,29 @@ type Service interface {
 	IsConfigured() bool
 }
 
+// textSender is implemented by services that can send an HTML body with a plain text alternative
+type textSender interface {
+	SendWithText(ctx context.Context, to, subject, body, textBody string) error
+}
+
+// otpTemplateSender is implemented by services that send OTP codes with a customized template
+type otpTemplateSender interface {
+	SendOTP(ctx context.Context, to, code, purpose string) (bool, error)
+}
+
+// newDeviceTemplateSender is implemented by services that send new-device alerts with a customized template
+type newDeviceTemplateSender interface {
+	SendNewDeviceAlert(ctx context.Context, to string, data map[string]string) (bool, error)
+}
+
+// SendRendered sends a rendered template, with its plain text body if the service supports one
+func SendRendered(ctx context.Context, s Service, to string, email *RenderedEmail) error {
+	if ts, ok := s.(textSender); ok && email.TextBody != "" {
+		return ts.SendWithText(ctx, to, email.Subject, email.HTMLBody, email.TextBody)
+	}
+	return s.Send(ctx, to, email.Subject, email.HTMLBody)
+}
+
 // NewService creates an email service based on configuration
 func NewService(cfg *config.EmailConfig) (Service, error) {
 	if cfg == nil || !cfg.Enabled {
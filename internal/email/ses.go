@@ -78,6 +78,11 @@ func (s *SESService) SendInvitationEmail(ctx context.Context, to, inviterName, i
 
 // Send sends a generic email via AWS SES
 func (s *SESService) Send(ctx context.Context, to, subject, body string) error {
+	return s.SendWithText(ctx, to, subject, body, "")
+}
+
+// SendWithText sends an email with an HTML body and a plain text alternative via AWS SES
+func (s *SESService) SendWithText(ctx context.Context, to, subject, body, textBody string) error {
 	input := &ses.SendEmailInput{
 		Source: aws.String(fmt.Sprintf("%s <%s>", s.config.FromName, s.config.FromAddress)),
 		Destination: &types.Destination{
@@ -97,6 +102,13 @@ func (s *SESService) Send(ctx context.Context, to, subject, body string) error {
 		},
 	}
 
+	if textBody != "" {
+		input.Message.Body.Text = &types.Content{
+			Data:    aws.String(textBody),
+			Charset: aws.String("UTF-8"),
+		}
+	}
+
 	// Set reply-to if configured
 	if s.config.ReplyToAddress != "" {
 		input.ReplyToAddresses = []string{s.config.ReplyToAddress}
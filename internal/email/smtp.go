@@ -5,7 +5,9 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"mime/multipart"
 	"net/smtp"
+	"net/textproto"
 
 	"github.com/nimbleflux/fluxbase/internal/config"
 )
@@ -54,12 +56,17 @@ func (s *SMTPService) SendInvitationEmail(ctx context.Context, to, inviterName,
 
 // Send sends an email via SMTP
 func (s *SMTPService) Send(ctx context.Context, to, subject, body string) error {
+	return s.SendWithText(ctx, to, subject, body, "")
+}
+
+// SendWithText sends an email with an HTML body and a plain text alternative via SMTP
+func (s *SMTPService) SendWithText(ctx context.Context, to, subject, body, textBody string) error {
 	if !s.config.Enabled {
 		return fmt.Errorf("email service is disabled")
 	}
 
 	// Build the message
-	message := s.buildMessage(to, subject, body)
+	message := s.buildMessageWithText(to, subject, body, textBody)
 
 	// Set up authentication (only if credentials are provided)
 	var auth smtp.Auth
@@ -157,6 +164,12 @@ func sanitizeHeaderValue(value string) string {
 // buildMessage builds an email message
 // All user-controlled header values are sanitized to prevent header injection attacks.
 func (s *SMTPService) buildMessage(to, subject, body string) []byte {
+	return s.buildMessageWithText(to, subject, body, "")
+}
+
+// buildMessageWithText builds an email message with an HTML body and, if textBody is set,
+// a plain text alternative
+func (s *SMTPService) buildMessageWithText(to, subject, body, textBody string) []byte {
 	var buf bytes.Buffer
 
 	// Sanitize all header values to prevent CRLF injection
@@ -173,9 +186,23 @@ func (s *SMTPService) buildMessage(to, subject, body string) []byte {
 	}
 	fmt.Fprintf(&buf, "Subject: %s\r\n", safeSubject)
 	buf.WriteString("MIME-Version: 1.0\r\n")
-	buf.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
-	buf.WriteString("\r\n")
-	buf.WriteString(body)
+	if textBody == "" {
+		buf.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+		buf.WriteString("\r\n")
+		buf.WriteString(body)
+		return buf.Bytes()
+	}
+
+	parts := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", parts.Boundary())
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", textBody},
+		{"text/html; charset=UTF-8", body},
+	} {
+		w, _ := parts.CreatePart(textproto.MIMEHeader{"Content-Type": {part.contentType}})
+		_, _ = w.Write([]byte(part.content))
+	}
+	_ = parts.Close()
 
 	return buf.Bytes()
 }
@@ -84,6 +84,25 @@ func TestSMTPService_buildMessage(t *testing.T) {
 	}
 }
 
+func TestSMTPService_buildMessageWithText(t *testing.T) {
+	service := NewSMTPService(&config.EmailConfig{FromAddress: "noreply@example.com", FromName: "Test Service"})
+
+	message := string(service.buildMessageWithText("user@example.com", "Hello", "<p>Hello</p>", "Hello"))
+
+	assert.Contains(t, message, "Subject: Hello\r\n")
+	assert.Contains(t, message, "Content-Type: multipart/alternative; boundary=")
+	assert.NotContains(t, message, "MIME-Version: 1.0\r\nContent-Type: text/html")
+	textAt := strings.Index(message, "Content-Type: text/plain; charset=UTF-8")
+	htmlAt := strings.Index(message, "Content-Type: text/html; charset=UTF-8")
+	require.Positive(t, textAt)
+	assert.Greater(t, htmlAt, textAt, "the preferred HTML part goes last")
+	assert.Contains(t, message[htmlAt:], "<p>Hello</p>")
+
+	plain := string(service.buildMessageWithText("user@example.com", "Hello", "<p>Hello</p>", ""))
+	assert.Equal(t, string(service.buildMessage("user@example.com", "Hello", "<p>Hello</p>")), plain)
+	assert.NotContains(t, plain, "multipart")
+}
+
 func TestSMTPService_renderMagicLinkTemplate(t *testing.T) {
 	cfg := &config.EmailConfig{}
 	service := NewSMTPService(cfg)
@@ -0,0 +1,282 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/database"
+)
+
+// Template types that can be customized
+const (
+	TemplateMagicLink         = "magic_link"
+	TemplateEmailVerification = "email_verification"
+	TemplatePasswordReset     = "password_reset"
+	TemplateInvitation        = "invitation"
+	TemplateOTP               = "otp"
+	TemplateNewDevice         = "new_device"
+)
+
+// ErrInvalidLocale is returned for a locale that is not a BCP 47 language tag
+var ErrInvalidLocale = errors.New("locale must be a language tag such as en, de or pt-BR")
+
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// Template is a customized email template. An empty locale is the default variant.
+type Template struct {
+	ID           uuid.UUID `json:"id"`
+	TemplateType string    `json:"template_type"`
+	Locale       string    `json:"locale"`
+	Subject      string    `json:"subject"`
+	HTMLBody     string    `json:"html_body"`
+	TextBody     *string   `json:"text_body,omitempty"`
+	Version      int       `json:"version,omitempty"`
+	IsCustom     bool      `json:"is_custom"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TemplateVersion is a saved version of a customized email template
+type TemplateVersion struct {
+	ID           uuid.UUID  `json:"id"`
+	TemplateType string     `json:"template_type"`
+	Locale       string     `json:"locale"`
+	Version      int        `json:"version"`
+	Subject      string     `json:"subject"`
+	HTMLBody     string     `json:"html_body"`
+	TextBody     *string    `json:"text_body,omitempty"`
+	CreatedBy    *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// NormalizeLocale lowercases a locale and replaces underscores, so that "pt_BR" and
+// "pt-br" select the same variant. An empty locale stays empty.
+func NormalizeLocale(locale string) (string, error) {
+	locale = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+	if locale != "" && !localePattern.MatchString(locale) {
+		return "", ErrInvalidLocale
+	}
+	return locale, nil
+}
+
+// localeCandidates returns the variants tried for a locale, most specific first:
+// "pt-br" tries "pt-br", "pt" and then the default variant
+func localeCandidates(locale string) []string {
+	locale, err := NormalizeLocale(locale)
+	if err != nil || locale == "" {
+		return []string{""}
+	}
+
+	candidates := []string{locale}
+	for i := strings.LastIndex(locale, "-"); i > 0; i = strings.LastIndex(locale, "-") {
+		locale = locale[:i]
+		candidates = append(candidates, locale)
+	}
+	return append(candidates, "")
+}
+
+// TemplateStore persists customized email templates and their versions
+type TemplateStore struct {
+	db *database.Connection
+}
+
+// NewTemplateStore creates an email template store
+func NewTemplateStore(db *database.Connection) *TemplateStore {
+	return &TemplateStore{db: db}
+}
+
+const templateColumns = `id, template_type, locale, subject, html_body, text_body, version, is_custom, created_at, updated_at`
+
+func scanTemplate(row pgx.Row) (*Template, error) {
+	var t Template
+	err := row.Scan(&t.ID, &t.TemplateType, &t.Locale, &t.Subject, &t.HTMLBody, &t.TextBody, &t.Version, &t.IsCustom, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// List returns all customized templates
+func (s *TemplateStore) List(ctx context.Context) ([]Template, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT `+templateColumns+`
+		FROM dashboard.email_templates
+		ORDER BY template_type, locale
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list email templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []Template
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan email template: %w", err)
+		}
+		templates = append(templates, *t)
+	}
+	return templates, rows.Err()
+}
+
+// Get returns the customized template of a type and locale, or nil if there is none
+func (s *TemplateStore) Get(ctx context.Context, templateType, locale string) (*Template, error) {
+	t, err := scanTemplate(s.db.QueryRow(ctx, `
+		SELECT `+templateColumns+`
+		FROM dashboard.email_templates
+		WHERE template_type = $1 AND locale = $2
+	`, templateType, locale))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get email template: %w", err)
+	}
+	return t, nil
+}
+
+// Resolve returns the customized template of a type that best matches a locale, or nil
+// if the type isn't customized for the locale, its base language or the default variant
+func (s *TemplateStore) Resolve(ctx context.Context, templateType, locale string) (*Template, error) {
+	candidates := localeCandidates(locale)
+	t, err := scanTemplate(s.db.QueryRow(ctx, `
+		SELECT `+templateColumns+`
+		FROM dashboard.email_templates
+		WHERE template_type = $1 AND locale = ANY($2::text[])
+		ORDER BY array_position($2::text[], locale)
+		LIMIT 1
+	`, templateType, candidates))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve email template: %w", err)
+	}
+	return t, nil
+}
+
+// Save stores a template as the next version of its type and locale
+func (s *TemplateStore) Save(ctx context.Context, t *Template, createdBy *uuid.UUID) (*Template, error) {
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var version int
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(MAX(version), 0) + 1
+		FROM dashboard.email_template_versions
+		WHERE template_type = $1 AND locale = $2
+	`, t.TemplateType, t.Locale).Scan(&version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next template version: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO dashboard.email_template_versions (template_type, locale, version, subject, html_body, text_body, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, t.TemplateType, t.Locale, version, t.Subject, t.HTMLBody, t.TextBody, createdBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save template version: %w", err)
+	}
+
+	saved, err := scanTemplate(tx.QueryRow(ctx, `
+		INSERT INTO dashboard.email_templates (template_type, locale, subject, html_body, text_body, version, is_custom)
+		VALUES ($1, $2, $3, $4, $5, $6, true)
+		ON CONFLICT (template_type, locale) DO UPDATE
+		SET subject = EXCLUDED.subject,
+		    html_body = EXCLUDED.html_body,
+		    text_body = EXCLUDED.text_body,
+		    version = EXCLUDED.version,
+		    is_custom = true,
+		    updated_at = NOW()
+		RETURNING `+templateColumns,
+		t.TemplateType, t.Locale, t.Subject, t.HTMLBody, t.TextBody, version))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save email template: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit email template: %w", err)
+	}
+	return saved, nil
+}
+
+// Delete removes the customized template of a type and locale. Its versions are kept.
+func (s *TemplateStore) Delete(ctx context.Context, templateType, locale string) error {
+	_, err := s.db.Exec(ctx, `
+		DELETE FROM dashboard.email_templates
+		WHERE template_type = $1 AND locale = $2
+	`, templateType, locale)
+	if err != nil {
+		return fmt.Errorf("failed to delete email template: %w", err)
+	}
+	return nil
+}
+
+// Versions returns the saved versions of a type and locale, newest first
+func (s *TemplateStore) Versions(ctx context.Context, templateType, locale string) ([]TemplateVersion, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id, template_type, locale, version, subject, html_body, text_body, created_by, created_at
+		FROM dashboard.email_template_versions
+		WHERE template_type = $1 AND locale = $2
+		ORDER BY version DESC
+	`, templateType, locale)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list email template versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []TemplateVersion
+	for rows.Next() {
+		var v TemplateVersion
+		if err := rows.Scan(&v.ID, &v.TemplateType, &v.Locale, &v.Version, &v.Subject, &v.HTMLBody, &v.TextBody, &v.CreatedBy, &v.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan email template version: %w", err)
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+// Restore saves an earlier version of a type and locale as its newest version.
+// It returns nil if the version doesn't exist.
+func (s *TemplateStore) Restore(ctx context.Context, templateType, locale string, version int, createdBy *uuid.UUID) (*Template, error) {
+	t := Template{TemplateType: templateType, Locale: locale}
+	err := s.db.QueryRow(ctx, `
+		SELECT subject, html_body, text_body
+		FROM dashboard.email_template_versions
+		WHERE template_type = $1 AND locale = $2 AND version = $3
+	`, templateType, locale, version).Scan(&t.Subject, &t.HTMLBody, &t.TextBody)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get email template version: %w", err)
+	}
+	return s.Save(ctx, &t, createdBy)
+}
+
+// UserLocale returns the locale in the user_metadata of the user with an email address,
+// or an empty string for unknown addresses and users without a locale
+func (s *TemplateStore) UserLocale(ctx context.Context, email string) (string, error) {
+	var locale string
+	err := s.db.QueryRow(ctx, `
+		SELECT COALESCE(user_metadata->>'locale', '')
+		FROM auth.users
+		WHERE lower(email) = lower($1)
+	`, email).Scan(&locale)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get user locale: %w", err)
+	}
+	return locale, nil
+}
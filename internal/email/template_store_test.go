@@ -0,0 +1,35 @@
+package email
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeLocale(t *testing.T) {
+	for input, want := range map[string]string{
+		"":           "",
+		"de":         "de",
+		"pt-BR":      "pt-br",
+		" pt_BR ":    "pt-br",
+		"zh-Hant-TW": "zh-hant-tw",
+	} {
+		locale, err := NormalizeLocale(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, locale, input)
+	}
+
+	for _, input := range []string{"english", "d", "de-", "de/at", "../../etc"} {
+		_, err := NormalizeLocale(input)
+		assert.ErrorIs(t, err, ErrInvalidLocale, input)
+	}
+}
+
+func TestLocaleCandidates(t *testing.T) {
+	assert.Equal(t, []string{""}, localeCandidates(""))
+	assert.Equal(t, []string{""}, localeCandidates("not a locale"))
+	assert.Equal(t, []string{"de", ""}, localeCandidates("de"))
+	assert.Equal(t, []string{"pt-br", "pt", ""}, localeCandidates("pt_BR"))
+	assert.Equal(t, []string{"zh-hant-tw", "zh-hant", "zh", ""}, localeCandidates("zh-Hant-TW"))
+}
@@ -2,8 +2,10 @@ package email
 
 import (
 	"bytes"
+	"fmt"
 	"html/template"
 	"os"
+	texttemplate "text/template"
 
 	"github.com/rs/zerolog/log"
 )
@@ -143,3 +145,107 @@ func fallbackPasswordResetHTML(link string) string {
 func fallbackInvitationHTML(link string) string {
 	return `<html><body><h2>You've Been Invited!</h2><p>Click the link below to accept your invitation:</p><p><a href="` + link + `">Accept Invitation</a></p><p>This invitation expires in 7 days</p></body></html>`
 }
+
+// RenderedEmail is a customized template rendered for one email
+type RenderedEmail struct {
+	Subject  string `json:"subject"`
+	HTMLBody string `json:"html_body"`
+	TextBody string `json:"text_body,omitempty"`
+}
+
+// Validate checks that the subject and bodies of a template parse
+func (t *Template) Validate() error {
+	_, err := t.Render(nil)
+	return err
+}
+
+// Render renders a template with data. The HTML body is escaped for HTML, the subject and
+// text body are not. Variables missing from data render empty.
+func (t *Template) Render(data map[string]string) (*RenderedEmail, error) {
+	subject, err := renderText("subject", t.Subject, data)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New("html_body").Option("missingkey=zero").Parse(t.HTMLBody)
+	if err != nil {
+		return nil, fmt.Errorf("invalid html_body: %w", err)
+	}
+	var html bytes.Buffer
+	if err := tmpl.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("invalid html_body: %w", err)
+	}
+
+	rendered := &RenderedEmail{Subject: subject, HTMLBody: html.String()}
+	if t.TextBody != nil {
+		if rendered.TextBody, err = renderText("text_body", *t.TextBody, data); err != nil {
+			return nil, err
+		}
+	}
+	return rendered, nil
+}
+
+// renderText renders a plain text part of a template
+func renderText(name, text string, data map[string]string) (string, error) {
+	tmpl, err := texttemplate.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// templateVariables lists the variables available to each template type in addition to
+// AppName, Email and Locale
+var templateVariables = map[string][]string{
+	TemplateMagicLink:         {"MagicLink", "Link", "Token"},
+	TemplateEmailVerification: {"VerificationLink", "Link", "Token"},
+	TemplatePasswordReset:     {"ResetLink", "Link", "Token"},
+	TemplateInvitation:        {"InviteLink", "InviterName"},
+	TemplateOTP:               {"Code", "Purpose"},
+	TemplateNewDevice:         {"IPAddress", "Location", "UserAgent", "SignedInAt"},
+}
+
+// TemplateVariables returns the variables available to a template type
+func TemplateVariables(templateType string) []string {
+	return append([]string{"AppName", "Email", "Locale"}, templateVariables[templateType]...)
+}
+
+// SampleTemplateData returns example values for the variables of a template type, used to
+// preview templates and send test emails
+func SampleTemplateData(templateType, locale string) map[string]string {
+	data := map[string]string{
+		"AppName": "Test Application",
+		"Email":   "user@example.com",
+		"Locale":  locale,
+	}
+	switch templateType {
+	case TemplateMagicLink:
+		data["Link"] = "https://example.com/magic-link/test-token"
+		data["MagicLink"] = data["Link"]
+		data["Token"] = "test-token-12345"
+	case TemplateEmailVerification:
+		data["Link"] = "https://example.com/verify/test-token"
+		data["VerificationLink"] = data["Link"]
+		data["Token"] = "test-token-12345"
+	case TemplatePasswordReset:
+		data["Link"] = "https://example.com/reset/test-token"
+		data["ResetLink"] = data["Link"]
+		data["Token"] = "test-token-12345"
+	case TemplateInvitation:
+		data["InviteLink"] = "https://example.com/invite/test-token"
+		data["InviterName"] = "Test Admin"
+	case TemplateOTP:
+		data["Code"] = "123456"
+		data["Purpose"] = "signin"
+	case TemplateNewDevice:
+		data["IPAddress"] = "203.0.113.7"
+		data["Location"] = "Berlin, DE"
+		data["UserAgent"] = "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) Firefox/128.0"
+		data["SignedInAt"] = "2026-01-01 12:00 UTC"
+	}
+	return data
+}
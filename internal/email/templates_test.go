@@ -472,3 +472,67 @@ func BenchmarkFallbackMagicLinkHTML(b *testing.B) {
 		_ = fallbackMagicLinkHTML("https://example.com/login")
 	}
 }
+
+// =============================================================================
+// Template.Render Tests
+// =============================================================================
+
+func TestTemplate_Render(t *testing.T) {
+	t.Run("interpolates variables", func(t *testing.T) {
+		textBody := "Hallo {{.Email}}, dein Code: {{.Code}}"
+		tmpl := &Template{
+			Subject:  "{{.AppName}}: Code {{.Code}}",
+			HTMLBody: "<p>Code <b>{{.Code}}</b></p>",
+			TextBody: &textBody,
+		}
+
+		rendered, err := tmpl.Render(map[string]string{"AppName": "Acme", "Code": "123456", "Email": "max@example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, "Acme: Code 123456", rendered.Subject)
+		assert.Equal(t, "<p>Code <b>123456</b></p>", rendered.HTMLBody)
+		assert.Equal(t, "Hallo max@example.com, dein Code: 123456", rendered.TextBody)
+	})
+
+	t.Run("escapes only the HTML body", func(t *testing.T) {
+		textBody := "{{.InviterName}}"
+		tmpl := &Template{Subject: "{{.InviterName}}", HTMLBody: "<p>{{.InviterName}}</p>", TextBody: &textBody}
+
+		rendered, err := tmpl.Render(map[string]string{"InviterName": "Tom & <Jerry>"})
+		require.NoError(t, err)
+		assert.Equal(t, "Tom & <Jerry>", rendered.Subject)
+		assert.Equal(t, "<p>Tom &amp; &lt;Jerry&gt;</p>", rendered.HTMLBody)
+		assert.Equal(t, "Tom & <Jerry>", rendered.TextBody)
+	})
+
+	t.Run("missing variables render empty", func(t *testing.T) {
+		rendered, err := (&Template{Subject: "Hi{{.Name}}", HTMLBody: "<p>{{.Name}}</p>"}).Render(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Hi", rendered.Subject)
+		assert.Equal(t, "<p></p>", rendered.HTMLBody)
+		assert.Empty(t, rendered.TextBody)
+	})
+
+	t.Run("reports the invalid part", func(t *testing.T) {
+		textBody := "{{.Code"
+		err := (&Template{Subject: "ok", HTMLBody: "<p>ok</p>", TextBody: &textBody}).Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid text_body")
+
+		err = (&Template{Subject: "{{if .Code}}", HTMLBody: "<p>ok</p>"}).Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid subject")
+	})
+}
+
+func TestSampleTemplateData(t *testing.T) {
+	for _, templateType := range []string{TemplateMagicLink, TemplateEmailVerification, TemplatePasswordReset, TemplateInvitation, TemplateOTP, TemplateNewDevice} {
+		data := SampleTemplateData(templateType, "de")
+		for _, variable := range TemplateVariables(templateType) {
+			if variable == "Locale" {
+				assert.Equal(t, "de", data[variable])
+				continue
+			}
+			assert.NotEmpty(t, data[variable], "%s: %s", templateType, variable)
+		}
+	}
+}
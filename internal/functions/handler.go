@@ -22,6 +22,7 @@ import (
 	"github.com/nimbleflux/fluxbase/internal/ratelimit"
 	"github.com/nimbleflux/fluxbase/internal/runtime"
 	"github.com/nimbleflux/fluxbase/internal/secrets"
+	"github.com/nimbleflux/fluxbase/internal/secretscan"
 	"github.com/nimbleflux/fluxbase/internal/settings"
 	"github.com/rs/zerolog/log"
 )
@@ -38,6 +39,7 @@ type Handler struct {
 	functionsDir           string
 	corsConfig             config.CORSConfig
 	publicURL              string
+	secretScanner          *secretscan.Guard
 	npmRegistry            string   // Custom npm registry URL for Deno bundling
 	jsrRegistry            string   // Custom JSR registry URL for Deno bundling
 	logCounters            sync.Map // map[uuid.UUID]*int for tracking log line numbers per execution
@@ -74,6 +76,11 @@ func (h *Handler) SetSettingsSecretsService(svc *settings.SecretsService) {
 	h.settingsSecretsService = svc
 }
 
+// SetSecretScanner sets the guard that checks deployed code for committed credentials
+func (h *Handler) SetSecretScanner(guard *secretscan.Guard) {
+	h.secretScanner = guard
+}
+
 // GetRuntime returns the Deno runtime for external use (e.g., MCP tools)
 func (h *Handler) GetRuntime() *runtime.DenoRuntime {
 	return h.runtime
@@ -269,6 +276,8 @@ func (h *Handler) checkRateLimit(c fiber.Ctx, fn *EdgeFunction) error {
 		{fn.RateLimitPerDay, 24 * time.Hour, ":day", "day"},
 	}
 
+	// Headers report the window with the fewest requests remaining
+	var tightest *ratelimit.Result
 	for _, check := range checks {
 		if check.limit == nil || *check.limit <= 0 {
 			continue
@@ -283,23 +292,18 @@ func (h *Handler) checkRateLimit(c fiber.Ctx, fn *EdgeFunction) error {
 		}
 
 		if !result.Allowed {
-			retryAfter := int(time.Until(result.ResetAt).Seconds())
-			if retryAfter < 1 {
-				retryAfter = 1
-			}
-
-			c.Set("Retry-After", strconv.Itoa(retryAfter))
-			c.Set("X-RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
-			c.Set("X-RateLimit-Remaining", "0")
-			c.Set("X-RateLimit-Reset", strconv.FormatInt(result.ResetAt.Unix(), 10))
+			return middleware.SendRateLimitExceeded(c, result,
+				fmt.Sprintf("Rate limit exceeded: %d requests per %s", *check.limit, check.unitName))
+		}
 
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"error":       fmt.Sprintf("Rate limit exceeded: %d requests per %s", *check.limit, check.unitName),
-				"retry_after": retryAfter,
-			})
+		if tightest == nil || result.Remaining < tightest.Remaining {
+			tightest = result
 		}
 	}
 
+	if tightest != nil {
+		middleware.SetRateLimitHeaders(c, tightest)
+	}
 	return nil
 }
 
@@ -371,6 +375,7 @@ func (h *Handler) CreateFunction(c fiber.Ctx) error {
 		RateLimitPerHour     *int    `json:"rate_limit_per_hour"`
 		RateLimitPerDay      *int    `json:"rate_limit_per_day"`
 		CronSchedule         *string `json:"cron_schedule"`
+		SecretsOverride      string  `json:"secrets_override_reason"` // Admin-only, deploys code with detected secrets
 	}
 
 	if err := c.Bind().Body(&req); err != nil {
@@ -396,6 +401,14 @@ func (h *Handler) CreateFunction(c fiber.Ctx) error {
 		}
 	}
 
+	sourceCode := req.Code
+	if req.OriginalCode != nil && *req.OriginalCode != "" {
+		sourceCode = *req.OriginalCode
+	}
+	if err := h.checkSecrets(c, secretscan.KindFunction, req.Name, req.SecretsOverride, map[string]string{"index.ts": sourceCode}); err != nil {
+		return secretScanErrorResponse(c, err)
+	}
+
 	// Parse configuration from code comments (if not explicitly set in request)
 	config := ParseFunctionConfig(req.Code)
 
@@ -692,8 +705,15 @@ func (h *Handler) UpdateFunction(c fiber.Ctx) error {
 	delete(updates, "updated_at")
 	delete(updates, "version")
 
+	overrideReason, _ := updates["secrets_override_reason"].(string)
+	delete(updates, "secrets_override_reason")
+
 	// If code is being updated, re-bundle with shared modules
 	if codeUpdate, ok := updates["code"].(string); ok && codeUpdate != "" {
+		if err := h.checkSecrets(c, secretscan.KindFunction, name, overrideReason, map[string]string{"index.ts": codeUpdate}); err != nil {
+			return secretScanErrorResponse(c, err)
+		}
+
 		bundler, err := h.createBundler()
 		if err == nil {
 			// Check if code imports from _shared/ modules
@@ -915,11 +935,7 @@ func (h *Handler) InvokeFunction(c fiber.Ctx) error {
 				Int64("limit", result.Limit).
 				Time("reset_at", result.ResetAt).
 				Msg("SECURITY: Impersonation token rate limit exceeded - possible brute force attack")
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"error":       "Rate limit exceeded",
-				"message":     "Too many impersonation attempts. Please try again in 5 minutes.",
-				"retry_after": int(time.Until(result.ResetAt).Seconds()),
-			})
+			return middleware.SendRateLimitExceeded(c, result, "Too many impersonation attempts. Please try again later.")
 		}
 
 		// Trim any whitespace that might have been added
@@ -1429,8 +1445,9 @@ func (h *Handler) SyncFunctions(c fiber.Ctx) error {
 			CronSchedule         *string `json:"cron_schedule"`
 		} `json:"functions"`
 		Options struct {
-			DeleteMissing bool `json:"delete_missing"`
-			DryRun        bool `json:"dry_run"`
+			DeleteMissing         bool   `json:"delete_missing"`
+			DryRun                bool   `json:"dry_run"`
+			SecretsOverrideReason string `json:"secrets_override_reason"` // Admin-only, deploys code with detected secrets
 		} `json:"options"`
 	}
 
@@ -1659,6 +1676,21 @@ func (h *Handler) SyncFunctions(c fiber.Ctx) error {
 			continue
 		}
 
+		// Skip functions with detected secrets
+		if err := h.checkSecrets(c, secretscan.KindFunction, spec.Name, req.Options.SecretsOverrideReason, map[string]string{"index.ts": result.OriginalCode}); err != nil {
+			entry := fiber.Map{
+				"function": spec.Name,
+				"error":    err.Error(),
+				"action":   "secret_scan",
+			}
+			var blocked *secretscan.BlockedError
+			if errors.As(err, &blocked) {
+				entry["findings"] = blocked.Findings
+			}
+			errorList = append(errorList, entry)
+			continue
+		}
+
 		// Parse configuration from code comments
 		config := ParseFunctionConfig(spec.Code)
 
@@ -1956,6 +1988,39 @@ func isAdminRole(role string) bool {
 	return role == "admin" || role == "dashboard_admin" || role == "service_role"
 }
 
+// errSecretsOverrideForbidden is returned when a non-admin gives a secrets override reason
+var errSecretsOverrideForbidden = errors.New("only admins can override secret scanning")
+
+// checkSecrets scans uploaded code for committed credentials
+func (h *Handler) checkSecrets(c fiber.Ctx, kind, name, overrideReason string, files map[string]string) error {
+	overrideReason = strings.TrimSpace(overrideReason)
+	role, _ := c.Locals("user_role").(string)
+	if overrideReason != "" && !isAdminRole(role) {
+		return errSecretsOverrideForbidden
+	}
+
+	subject := secretscan.Subject{Kind: kind, Name: name, OverrideReason: overrideReason}
+	if uid, ok := c.Locals("user_id").(string); ok {
+		if parsed, err := uuid.Parse(uid); err == nil {
+			subject.ActorID = &parsed
+		}
+	}
+	return h.secretScanner.Check(c.RequestCtx(), subject, files)
+}
+
+// secretScanErrorResponse writes the response for an error from checkSecrets
+func secretScanErrorResponse(c fiber.Ctx, err error) error {
+	var blocked *secretscan.BlockedError
+	if errors.As(err, &blocked) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":    "Potential secrets detected",
+			"details":  blocked.Error(),
+			"findings": blocked.Findings,
+		})
+	}
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+}
+
 func valueOr[T any](ptr *T, defaultVal T) T {
 	if ptr != nil {
 		return *ptr
@@ -1999,9 +2064,10 @@ func toString(v interface{}) string {
 // CreateSharedModule creates a new shared module
 func (h *Handler) CreateSharedModule(c fiber.Ctx) error {
 	var req struct {
-		ModulePath  string  `json:"module_path"`
-		Content     string  `json:"content"`
-		Description *string `json:"description"`
+		ModulePath      string  `json:"module_path"`
+		Content         string  `json:"content"`
+		Description     *string `json:"description"`
+		SecretsOverride string  `json:"secrets_override_reason"` // Admin-only, saves a module with detected secrets
 	}
 
 	if err := c.Bind().Body(&req); err != nil {
@@ -2013,6 +2079,10 @@ func (h *Handler) CreateSharedModule(c fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Module path must start with '_shared/'"})
 	}
 
+	if err := h.checkSecrets(c, secretscan.KindSharedModule, req.ModulePath, req.SecretsOverride, map[string]string{req.ModulePath: req.Content}); err != nil {
+		return secretScanErrorResponse(c, err)
+	}
+
 	// Get user ID from context (if authenticated)
 	var userID *uuid.UUID
 	if uid := c.Locals("user_id"); uid != nil {
@@ -2088,14 +2158,19 @@ func (h *Handler) UpdateSharedModule(c fiber.Ctx) error {
 	}
 
 	var req struct {
-		Content     string  `json:"content"`
-		Description *string `json:"description"`
+		Content         string  `json:"content"`
+		Description     *string `json:"description"`
+		SecretsOverride string  `json:"secrets_override_reason"` // Admin-only, saves a module with detected secrets
 	}
 
 	if err := c.Bind().Body(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
+	if err := h.checkSecrets(c, secretscan.KindSharedModule, modulePath, req.SecretsOverride, map[string]string{modulePath: req.Content}); err != nil {
+		return secretScanErrorResponse(c, err)
+	}
+
 	if err := h.storage.UpdateSharedModule(c.RequestCtx(), modulePath, req.Content, req.Description); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return c.Status(404).JSON(fiber.Map{"error": "Shared module not found"})
@@ -0,0 +1,378 @@
+package functions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/nimbleflux/fluxbase/internal/runtime"
+	"github.com/nimbleflux/fluxbase/internal/secrets"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// triggerCacheTTL bounds how long another instance's trigger change takes to apply here
+	triggerCacheTTL = 30 * time.Second
+	// maxConcurrentObjectTriggers bounds the functions processing uploads at once, further
+	// uploads wait for a slot
+	maxConcurrentObjectTriggers = 10
+)
+
+// ObjectEvent is an uploaded object that may trigger functions
+type ObjectEvent struct {
+	Bucket      string
+	Key         string
+	ContentType string
+	Size        int64
+
+	// Uploader the functions run as
+	UserID    string
+	UserEmail string
+	UserRole  string
+
+	// Open returns the object's content, called once per triggered function
+	Open func(ctx context.Context) (io.ReadCloser, error)
+}
+
+// triggerCacheEntry is the cached triggers of a bucket
+type triggerCacheEntry struct {
+	triggers []*StorageTrigger
+	expires  time.Time
+}
+
+// ObjectTriggers invokes edge functions with the content of objects uploaded to buckets.
+// The object is piped to the function as its request body, so it is never buffered in memory
+// and the function doesn't download it again.
+type ObjectTriggers struct {
+	storage        *Storage
+	runtime        *runtime.DenoRuntime
+	secretsStorage *secrets.Storage
+	publicURL      string
+	now            func() time.Time
+	sem            chan struct{}
+
+	mu    sync.Mutex
+	cache map[string]triggerCacheEntry
+	// inFlight counts the running executions per object. A function writing its result back
+	// to the object it processes doesn't trigger itself again.
+	inFlight map[string]int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewObjectTriggers creates the storage trigger runner
+func NewObjectTriggers(db *database.Connection, jwtSecret, publicURL string, secretsStorage *secrets.Storage) *ObjectTriggers {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &ObjectTriggers{
+		storage:        NewStorage(db),
+		runtime:        runtime.NewRuntime(runtime.RuntimeTypeFunction, jwtSecret, publicURL),
+		secretsStorage: secretsStorage,
+		publicURL:      publicURL,
+		now:            time.Now,
+		sem:            make(chan struct{}, maxConcurrentObjectTriggers),
+		cache:          make(map[string]triggerCacheEntry),
+		inFlight:       make(map[string]int),
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+}
+
+// List returns the function triggers of a bucket
+func (t *ObjectTriggers) List(ctx context.Context, bucket string) ([]*StorageTrigger, error) {
+	return t.storage.ListStorageTriggers(ctx, bucket)
+}
+
+// Create adds a trigger for a function of the given namespace to a bucket
+func (t *ObjectTriggers) Create(ctx context.Context, trigger *StorageTrigger) error {
+	fn, err := t.storage.GetFunctionByNamespace(ctx, trigger.FunctionName, trigger.FunctionNamespace)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrTriggerFunctionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	trigger.FunctionID = fn.ID
+
+	if err := t.storage.CreateStorageTrigger(ctx, trigger); err != nil {
+		return err
+	}
+	t.invalidate(trigger.Bucket)
+	return nil
+}
+
+// Delete removes a trigger from a bucket
+func (t *ObjectTriggers) Delete(ctx context.Context, bucket string, id uuid.UUID) error {
+	if err := t.storage.DeleteStorageTrigger(ctx, bucket, id); err != nil {
+		return err
+	}
+	t.invalidate(bucket)
+	return nil
+}
+
+func (t *ObjectTriggers) invalidate(bucket string) {
+	t.mu.Lock()
+	delete(t.cache, bucket)
+	t.mu.Unlock()
+}
+
+// triggers returns the cached triggers of a bucket
+func (t *ObjectTriggers) triggers(ctx context.Context, bucket string) ([]*StorageTrigger, error) {
+	now := t.now()
+	t.mu.Lock()
+	entry, ok := t.cache[bucket]
+	t.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.triggers, nil
+	}
+
+	triggers, err := t.storage.ListStorageTriggers(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	t.cache[bucket] = triggerCacheEntry{triggers: triggers, expires: now.Add(triggerCacheTTL)}
+	t.mu.Unlock()
+	return triggers, nil
+}
+
+// Fire invokes the functions triggered by an uploaded object in the background. Failures
+// are logged and recorded as failed executions of the function.
+func (t *ObjectTriggers) Fire(event ObjectEvent) {
+	triggers, err := t.triggers(t.ctx, event.Bucket)
+	if err != nil {
+		log.Warn().Err(err).Str("bucket", event.Bucket).Msg("Failed to get storage triggers")
+		return
+	}
+
+	objectKey := event.Bucket + "/" + event.Key
+	t.mu.Lock()
+	running := t.inFlight[objectKey] > 0
+	t.mu.Unlock()
+
+	for _, trigger := range triggers {
+		if !trigger.Matches(event.Key, event.ContentType, event.Size) {
+			continue
+		}
+		if running {
+			log.Debug().
+				Str("bucket", event.Bucket).
+				Str("key", event.Key).
+				Str("function", trigger.FunctionName).
+				Msg("Skipping storage trigger - object is being processed")
+			continue
+		}
+
+		t.start(objectKey)
+		t.wg.Add(1)
+		go func(trigger *StorageTrigger) {
+			defer t.wg.Done()
+			defer t.finish(objectKey)
+			t.execute(trigger, event)
+		}(trigger)
+	}
+}
+
+func (t *ObjectTriggers) start(objectKey string) {
+	t.mu.Lock()
+	t.inFlight[objectKey]++
+	t.mu.Unlock()
+}
+
+func (t *ObjectTriggers) finish(objectKey string) {
+	t.mu.Lock()
+	if t.inFlight[objectKey]--; t.inFlight[objectKey] <= 0 {
+		delete(t.inFlight, objectKey)
+	}
+	t.mu.Unlock()
+}
+
+// execute runs a triggered function with the object as its request body
+func (t *ObjectTriggers) execute(trigger *StorageTrigger, event ObjectEvent) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Error().
+				Interface("panic", rec).
+				Str("function", trigger.FunctionName).
+				Str("bucket", event.Bucket).
+				Msg("Panic in storage trigger execution - recovered")
+		}
+	}()
+
+	select {
+	case t.sem <- struct{}{}:
+		defer func() { <-t.sem }()
+	case <-t.ctx.Done():
+		return
+	}
+
+	fn, err := t.storage.GetFunctionByNamespace(t.ctx, trigger.FunctionName, trigger.FunctionNamespace)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("function", trigger.FunctionName).
+			Str("namespace", trigger.FunctionNamespace).
+			Msg("Failed to fetch function for storage trigger")
+		return
+	}
+	if !fn.Enabled {
+		log.Debug().Str("function", fn.Name).Msg("Skipping storage trigger - function is disabled")
+		return
+	}
+
+	object, err := event.Open(t.ctx)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("bucket", event.Bucket).
+			Str("key", event.Key).
+			Str("function", fn.Name).
+			Msg("Failed to open object for storage trigger")
+		return
+	}
+	defer func() { _ = object.Close() }()
+
+	log.Info().
+		Str("function", fn.Name).
+		Str("trigger", "storage").
+		Str("bucket", event.Bucket).
+		Str("key", event.Key).
+		Msg("Executing storage triggered function")
+
+	start := time.Now()
+	executionID := uuid.New()
+	req := runtime.ExecutionRequest{
+		ID:         executionID,
+		Name:       fn.Name,
+		Namespace:  fn.Namespace,
+		UserID:     event.UserID,
+		UserEmail:  event.UserEmail,
+		UserRole:   event.UserRole,
+		BaseURL:    t.publicURL,
+		Method:     "POST",
+		URL:        t.publicURL + "/api/v1/storage/" + url.PathEscape(event.Bucket) + "/" + escapeKey(event.Key),
+		Headers:    objectHeaders(event),
+		BodyStream: object,
+	}
+
+	if !fn.DisableExecutionLogs {
+		if err := t.storage.CreateExecution(t.ctx, executionID, fn.ID, "storage"); err != nil {
+			log.Error().Err(err).Str("execution_id", executionID.String()).Msg("Failed to create execution record")
+		}
+	}
+
+	perms := runtime.Permissions{
+		AllowNet:   fn.AllowNet,
+		AllowEnv:   fn.AllowEnv,
+		AllowRead:  fn.AllowRead,
+		AllowWrite: fn.AllowWrite,
+	}
+
+	var timeoutOverride *time.Duration
+	if fn.TimeoutSeconds > 0 {
+		timeout := time.Duration(fn.TimeoutSeconds) * time.Second
+		timeoutOverride = &timeout
+	}
+
+	var functionSecrets map[string]string
+	if t.secretsStorage != nil {
+		functionSecrets, err = t.secretsStorage.GetSecretsForNamespace(t.ctx, fn.Namespace)
+		if err != nil {
+			log.Warn().Err(err).Str("namespace", fn.Namespace).Msg("Failed to load secrets for storage triggered function")
+		}
+	}
+
+	result, err := t.runtime.Execute(t.ctx, fn.Code, req, perms, nil, timeoutOverride, functionSecrets)
+	duration := time.Since(start)
+
+	status := "success"
+	var errorMessage *string
+	durationMs := int(duration.Milliseconds())
+	if err != nil {
+		status = "error"
+		errorMsg := err.Error()
+		errorMessage = &errorMsg
+		log.Error().
+			Err(err).
+			Str("function", fn.Name).
+			Str("bucket", event.Bucket).
+			Str("key", event.Key).
+			Dur("duration", duration).
+			Msg("Storage triggered function execution failed")
+	} else {
+		if result.Error != "" {
+			status = "error"
+			errorMessage = &result.Error
+		}
+		log.Info().
+			Str("function", fn.Name).
+			Str("status", status).
+			Int("status_code", result.Status).
+			Dur("duration", duration).
+			Msg("Storage triggered function execution completed")
+	}
+
+	if fn.DisableExecutionLogs {
+		return
+	}
+
+	var resultStr, logs *string
+	var statusCode *int
+	if result != nil {
+		if resultJSON, jsonErr := json.Marshal(result); jsonErr == nil {
+			rs := string(resultJSON)
+			resultStr = &rs
+		}
+		logs, statusCode = &result.Logs, &result.Status
+	}
+	if err := t.storage.CompleteExecution(context.Background(), executionID, status, statusCode, &durationMs, resultStr, logs, errorMessage); err != nil {
+		log.Error().
+			Err(err).
+			Str("function", fn.Name).
+			Str("execution_id", executionID.String()).
+			Msg("Failed to complete storage triggered execution record")
+	}
+}
+
+// objectHeaders are the request headers describing the object to the function. The key is
+// percent-encoded, header values can't hold every character a key may contain.
+func objectHeaders(event ObjectEvent) map[string]string {
+	contentType := event.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return map[string]string{
+		"Content-Type":           contentType,
+		"X-Fluxbase-Event":       "storage.object.created",
+		"X-Fluxbase-Bucket":      event.Bucket,
+		"X-Fluxbase-Object-Key":  escapeKey(event.Key),
+		"X-Fluxbase-Object-Size": strconv.FormatInt(event.Size, 10),
+	}
+}
+
+// escapeKey escapes each segment of an object key for use in a URL path
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+// Stop cancels running trigger executions and waits for them to return
+func (t *ObjectTriggers) Stop() {
+	t.cancel()
+	t.wg.Wait()
+}
@@ -0,0 +1,57 @@
+package functions
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectHeaders(t *testing.T) {
+	headers := objectHeaders(ObjectEvent{Bucket: "uploads", Key: "imports/q3 report.csv", Size: 42})
+
+	assert.Equal(t, "application/octet-stream", headers["Content-Type"])
+	assert.Equal(t, "storage.object.created", headers["X-Fluxbase-Event"])
+	assert.Equal(t, "uploads", headers["X-Fluxbase-Bucket"])
+	assert.Equal(t, "imports/q3%20report.csv", headers["X-Fluxbase-Object-Key"])
+	assert.Equal(t, "42", headers["X-Fluxbase-Object-Size"])
+}
+
+func TestObjectTriggers_FireSkipsObjectsBeingProcessed(t *testing.T) {
+	triggers := NewObjectTriggers(nil, "secret", "http://localhost:8080", nil)
+	defer triggers.Stop()
+
+	// Cached triggers keep Fire off the database
+	triggers.cache["uploads"] = triggerCacheEntry{
+		triggers: []*StorageTrigger{{Enabled: true, Prefix: "photos/", MaxSizeBytes: DefaultTriggerMaxSize, FunctionName: "strip-exif"}},
+		expires:  time.Now().Add(time.Minute),
+	}
+
+	var opened atomic.Int32
+	event := ObjectEvent{
+		Bucket: "uploads",
+		Key:    "photos/cat.jpg",
+		Size:   10,
+		Open: func(ctx context.Context) (io.ReadCloser, error) {
+			opened.Add(1)
+			return io.NopCloser(strings.NewReader("jpeg")), nil
+		},
+	}
+
+	// The function writing its result back to the object it processes
+	triggers.start("uploads/photos/cat.jpg")
+	triggers.Fire(event)
+	triggers.finish("uploads/photos/cat.jpg")
+
+	// Objects outside the prefix never trigger
+	event.Key = "avatars/cat.jpg"
+	triggers.Fire(event)
+
+	triggers.wg.Wait()
+	assert.Zero(t, opened.Load())
+	assert.Empty(t, triggers.inFlight)
+}
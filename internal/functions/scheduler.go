@@ -3,6 +3,8 @@ package functions
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -14,6 +16,9 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// ErrFunctionDisabled is returned when invoking a disabled function
+var ErrFunctionDisabled = errors.New("function is disabled")
+
 // Scheduler manages scheduled execution of edge functions via cron
 type Scheduler struct {
 	cron           *cron.Cron
@@ -264,33 +269,42 @@ func (s *Scheduler) executeScheduledFunction(funcName, funcNamespace string) {
 		s.activeMu.Unlock()
 	}()
 
+	// Failures are logged by Invoke
+	if _, err := s.Invoke(s.ctx, funcName, funcNamespace, "cron", "{}"); errors.Is(err, ErrFunctionDisabled) {
+		log.Debug().
+			Str("function", funcName).
+			Msg("Skipping scheduled execution - function is disabled")
+	}
+}
+
+// Invoke runs a function with body as the POST request body and records the execution
+// under triggerType. It returns the function's response, or an error if the function could
+// not be loaded or run; a function that ran but threw is reported in the result's Error.
+func (s *Scheduler) Invoke(ctx context.Context, funcName, funcNamespace, triggerType, body string) (*runtime.ExecutionResult, error) {
 	// Fetch the current function data from storage
-	fn, err := s.storage.GetFunctionByNamespace(s.ctx, funcName, funcNamespace)
+	fn, err := s.storage.GetFunctionByNamespace(ctx, funcName, funcNamespace)
 	if err != nil {
 		log.Error().
 			Err(err).
 			Str("function", funcName).
 			Str("namespace", funcNamespace).
 			Msg("Failed to fetch function for scheduled execution")
-		return
+		return nil, fmt.Errorf("failed to fetch function %s: %w", funcName, err)
 	}
 
 	// Check if function is still enabled
 	if !fn.Enabled {
-		log.Debug().
-			Str("function", funcName).
-			Msg("Skipping scheduled execution - function is disabled")
-		return
+		return nil, ErrFunctionDisabled
 	}
 
 	log.Info().
 		Str("function", fn.Name).
-		Str("trigger", "cron").
+		Str("trigger", triggerType).
 		Msg("Executing scheduled function")
 
 	start := time.Now()
 
-	// Prepare execution request (empty for cron triggers)
+	// Prepare execution request
 	executionID := uuid.New()
 	req := runtime.ExecutionRequest{
 		ID:        executionID,
@@ -298,14 +312,14 @@ func (s *Scheduler) executeScheduledFunction(funcName, funcNamespace string) {
 		Namespace: fn.Namespace,
 		Method:    "POST",
 		URL:       "/scheduled",
-		Headers:   make(map[string]string),
-		Body:      "{}",
+		Headers:   map[string]string{"Content-Type": "application/json"},
+		Body:      body,
 	}
 
 	// Create execution record BEFORE running to enable real-time logging
 	// Skip if execution logs are disabled for this function
 	if !fn.DisableExecutionLogs {
-		if err := s.storage.CreateExecution(s.ctx, executionID, fn.ID, "cron"); err != nil {
+		if err := s.storage.CreateExecution(ctx, executionID, fn.ID, triggerType); err != nil {
 			log.Error().Err(err).Str("execution_id", executionID.String()).Msg("Failed to create execution record")
 			// Continue anyway - logging will still work via stderr fallback
 		}
@@ -335,7 +349,7 @@ func (s *Scheduler) executeScheduledFunction(funcName, funcNamespace string) {
 	var functionSecrets map[string]string
 	if s.secretsStorage != nil {
 		var err error
-		functionSecrets, err = s.secretsStorage.GetSecretsForNamespace(s.ctx, fn.Namespace)
+		functionSecrets, err = s.secretsStorage.GetSecretsForNamespace(ctx, fn.Namespace)
 		if err != nil {
 			log.Warn().Err(err).Str("namespace", fn.Namespace).Msg("Failed to load secrets for scheduled function execution")
 			// Continue without secrets - don't fail the function invocation
@@ -343,7 +357,7 @@ func (s *Scheduler) executeScheduledFunction(funcName, funcNamespace string) {
 	}
 
 	// Execute (nil cancel signal for scheduled executions)
-	result, err := s.runtime.Execute(s.ctx, fn.Code, req, perms, nil, timeoutOverride, functionSecrets)
+	result, execErr := s.runtime.Execute(ctx, fn.Code, req, perms, nil, timeoutOverride, functionSecrets)
 	duration := time.Since(start)
 
 	// Determine final status
@@ -351,12 +365,12 @@ func (s *Scheduler) executeScheduledFunction(funcName, funcNamespace string) {
 	var errorMessage *string
 	durationMs := int(duration.Milliseconds())
 
-	if err != nil {
+	if execErr != nil {
 		status = "error"
-		errorMsg := err.Error()
+		errorMsg := execErr.Error()
 		errorMessage = &errorMsg
 		log.Error().
-			Err(err).
+			Err(execErr).
 			Str("function", fn.Name).
 			Dur("duration", duration).
 			Msg("Scheduled function execution failed")
@@ -374,12 +388,14 @@ func (s *Scheduler) executeScheduledFunction(funcName, funcNamespace string) {
 	}
 
 	// Serialize result to JSON
-	var resultStr *string
+	var resultStr, logs *string
+	var statusCode *int
 	if result != nil {
 		if resultJSON, jsonErr := json.Marshal(result); jsonErr == nil {
 			rs := string(resultJSON)
 			resultStr = &rs
 		}
+		logs, statusCode = &result.Logs, &result.Status
 	}
 
 	// Complete execution record asynchronously
@@ -395,7 +411,7 @@ func (s *Scheduler) executeScheduledFunction(funcName, funcNamespace string) {
 						Msg("Panic in scheduled function execution record completion - recovered")
 				}
 			}()
-			if updateErr := s.storage.CompleteExecution(context.Background(), executionID, status, &result.Status, &durationMs, resultStr, &result.Logs, errorMessage); updateErr != nil {
+			if updateErr := s.storage.CompleteExecution(context.Background(), executionID, status, statusCode, &durationMs, resultStr, logs, errorMessage); updateErr != nil {
 				log.Error().
 					Err(updateErr).
 					Str("function", fn.Name).
@@ -404,6 +420,8 @@ func (s *Scheduler) executeScheduledFunction(funcName, funcNamespace string) {
 			}
 		}()
 	}
+
+	return result, execErr
 }
 
 // GetScheduledFunctions returns a list of all currently scheduled functions
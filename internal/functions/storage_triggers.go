@@ -0,0 +1,174 @@
+package functions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/database"
+)
+
+// DefaultTriggerMaxSize is the largest object a storage trigger streams by default (100MB)
+const DefaultTriggerMaxSize = 100 * 1024 * 1024
+
+var (
+	// ErrStorageTriggerNotFound is returned for an unknown storage trigger
+	ErrStorageTriggerNotFound = errors.New("storage trigger not found")
+	// ErrStorageTriggerExists is returned when the function already has a trigger for the bucket and prefix
+	ErrStorageTriggerExists = errors.New("function already has a trigger for this bucket and prefix")
+	// ErrTriggerBucketNotFound is returned when adding a trigger to a bucket that does not exist
+	ErrTriggerBucketNotFound = errors.New("bucket not found")
+	// ErrTriggerFunctionNotFound is returned when adding a trigger for a function that does not exist
+	ErrTriggerFunctionNotFound = errors.New("function not found")
+)
+
+// StorageTrigger invokes an edge function with the content of objects uploaded to a bucket
+type StorageTrigger struct {
+	ID                uuid.UUID  `json:"id"`
+	Bucket            string     `json:"bucket"`
+	FunctionID        uuid.UUID  `json:"function_id"`
+	FunctionName      string     `json:"function_name"`
+	FunctionNamespace string     `json:"function_namespace"`
+	Prefix            string     `json:"prefix"`
+	ContentTypes      []string   `json:"content_types"`
+	MaxSizeBytes      int64      `json:"max_size_bytes"`
+	Enabled           bool       `json:"enabled"`
+	CreatedBy         *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// Validate checks the trigger's filters
+func (t *StorageTrigger) Validate() error {
+	if t.MaxSizeBytes <= 0 {
+		return errors.New("max_size_bytes must be positive")
+	}
+	for _, contentType := range t.ContentTypes {
+		mediaType, subtype, ok := strings.Cut(contentType, "/")
+		if !ok || mediaType == "" || subtype == "" || (mediaType == "*" && subtype != "*") {
+			return fmt.Errorf("invalid content type %q, expected e.g. text/csv or image/*", contentType)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether an uploaded object triggers the function
+func (t *StorageTrigger) Matches(key, contentType string, size int64) bool {
+	if !t.Enabled || size > t.MaxSizeBytes || !strings.HasPrefix(key, t.Prefix) {
+		return false
+	}
+	if len(t.ContentTypes) == 0 {
+		return true
+	}
+
+	// Parameters like charset don't take part in matching
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	for _, allowed := range t.ContentTypes {
+		allowed = strings.ToLower(allowed)
+		if allowed == mediaType || allowed == "*/*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+const storageTriggerColumns = `
+	t.id, t.bucket_id, t.function_id, f.name, f.namespace, t.prefix, t.content_types,
+	t.max_size_bytes, t.enabled, t.created_by, t.created_at, t.updated_at
+`
+
+func scanStorageTrigger(row pgx.Row) (*StorageTrigger, error) {
+	t := &StorageTrigger{}
+	err := row.Scan(&t.ID, &t.Bucket, &t.FunctionID, &t.FunctionName, &t.FunctionNamespace, &t.Prefix,
+		&t.ContentTypes, &t.MaxSizeBytes, &t.Enabled, &t.CreatedBy, &t.CreatedAt, &t.UpdatedAt)
+	return t, err
+}
+
+// ListStorageTriggers returns the function triggers of a bucket
+func (s *Storage) ListStorageTriggers(ctx context.Context, bucket string) ([]*StorageTrigger, error) {
+	query := `
+		SELECT ` + storageTriggerColumns + `
+		FROM storage.function_triggers t
+		JOIN functions.edge_functions f ON f.id = t.function_id
+		WHERE t.bucket_id = $1
+		ORDER BY t.created_at
+	`
+
+	triggers := []*StorageTrigger{}
+	err := database.WrapWithServiceRole(ctx, s.db, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, query, bucket)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			t, err := scanStorageTrigger(rows)
+			if err != nil {
+				return err
+			}
+			triggers = append(triggers, t)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storage triggers: %w", err)
+	}
+
+	return triggers, nil
+}
+
+// CreateStorageTrigger adds a function trigger to a bucket. The trigger must already be validated.
+func (s *Storage) CreateStorageTrigger(ctx context.Context, t *StorageTrigger) error {
+	query := `
+		INSERT INTO storage.function_triggers (bucket_id, function_id, prefix, content_types, max_size_bytes, enabled, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	if t.ContentTypes == nil {
+		t.ContentTypes = []string{}
+	}
+	err := database.WrapWithServiceRole(ctx, s.db, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, query,
+			t.Bucket, t.FunctionID, t.Prefix, t.ContentTypes, t.MaxSizeBytes, t.Enabled, t.CreatedBy,
+		).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+	})
+	if database.IsUniqueViolation(err) {
+		return ErrStorageTriggerExists
+	}
+	if database.IsForeignKeyViolation(err) {
+		return ErrTriggerBucketNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create storage trigger: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteStorageTrigger removes a function trigger from a bucket
+func (s *Storage) DeleteStorageTrigger(ctx context.Context, bucket string, id uuid.UUID) error {
+	var deleted int64
+	err := database.WrapWithServiceRole(ctx, s.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `DELETE FROM storage.function_triggers WHERE bucket_id = $1 AND id = $2`, bucket, id)
+		deleted = tag.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete storage trigger: %w", err)
+	}
+	if deleted == 0 {
+		return ErrStorageTriggerNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package functions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorageTrigger_Validate(t *testing.T) {
+	valid := &StorageTrigger{MaxSizeBytes: DefaultTriggerMaxSize, ContentTypes: []string{"text/csv", "image/*", "*/*"}}
+	assert.NoError(t, valid.Validate())
+
+	assert.Error(t, (&StorageTrigger{MaxSizeBytes: 0}).Validate())
+	for _, contentType := range []string{"csv", "text/", "/csv", "*/csv"} {
+		trigger := &StorageTrigger{MaxSizeBytes: 1, ContentTypes: []string{contentType}}
+		assert.Error(t, trigger.Validate(), contentType)
+	}
+}
+
+func TestStorageTrigger_Matches(t *testing.T) {
+	trigger := &StorageTrigger{
+		Enabled:      true,
+		Prefix:       "imports/",
+		ContentTypes: []string{"text/csv", "image/*"},
+		MaxSizeBytes: 1024,
+	}
+
+	assert.True(t, trigger.Matches("imports/users.csv", "text/csv", 100))
+	assert.True(t, trigger.Matches("imports/users.csv", "Text/CSV; charset=utf-8", 100))
+	assert.True(t, trigger.Matches("imports/photo.jpg", "image/jpeg", 1024))
+
+	assert.False(t, trigger.Matches("exports/users.csv", "text/csv", 100), "outside the prefix")
+	assert.False(t, trigger.Matches("imports/users.json", "application/json", 100), "other content type")
+	assert.False(t, trigger.Matches("imports/photo.jpg", "image/jpeg", 1025), "too large")
+
+	anything := &StorageTrigger{Enabled: true, MaxSizeBytes: 1024}
+	assert.True(t, anything.Matches("a/b/c", "", 0))
+
+	anything.Enabled = false
+	assert.False(t, anything.Matches("a/b/c", "", 0))
+}
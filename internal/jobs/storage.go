@@ -352,11 +352,20 @@ func (s *Storage) EnqueueJob(ctx context.Context, job *Job) error {
 		RETURNING created_at
 	`
 
-	return s.conn.Pool().QueryRow(ctx, query,
+	err := s.conn.Pool().QueryRow(ctx, query,
 		job.ID, job.Namespace, job.JobFunctionID, job.JobName, job.Status, job.Payload,
 		job.Priority, job.MaxDurationSeconds, job.ProgressTimeoutSeconds,
 		job.MaxRetries, job.CreatedBy, job.UserRole, job.UserEmail, job.ScheduledAt,
 	).Scan(&job.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	// Jobs scheduled for later are found by polling once they are due
+	if job.ScheduledAt == nil || !job.ScheduledAt.After(time.Now()) {
+		notifyJobEnqueued(ctx, job.ID)
+	}
+	return nil
 }
 
 // ComputeDeduplicationKey generates a deduplication key from job parameters
@@ -0,0 +1,84 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/cache"
+	"github.com/rs/zerolog/log"
+)
+
+// WakeupQueueName is the queue that signals workers when jobs are enqueued
+const WakeupQueueName = "jobs"
+
+// wakeupWait is how long a worker blocks on the wake-up queue before checking for shutdown
+const wakeupWait = 5 * time.Second
+
+// The wake-up queue lets workers start a job as soon as it is enqueued instead of waiting
+// for the next poll. jobs.queue stays the source of truth: a lost wake-up only delays a
+// job until the next poll.
+var (
+	wakeupQueue   cache.Queue
+	wakeupQueueMu sync.RWMutex
+)
+
+// SetWakeupQueue sets the queue used to wake workers when jobs are enqueued.
+// This should be called once during server initialization; nil disables wake-ups.
+func SetWakeupQueue(q cache.Queue) {
+	wakeupQueueMu.Lock()
+	defer wakeupQueueMu.Unlock()
+	wakeupQueue = q
+}
+
+func getWakeupQueue() cache.Queue {
+	wakeupQueueMu.RLock()
+	defer wakeupQueueMu.RUnlock()
+	return wakeupQueue
+}
+
+// notifyJobEnqueued wakes one worker to claim a newly enqueued job
+func notifyJobEnqueued(ctx context.Context, jobID uuid.UUID) {
+	q := getWakeupQueue()
+	if q == nil {
+		return
+	}
+	if err := q.Push(ctx, WakeupQueueName, []byte(jobID.String())); err != nil {
+		log.Debug().Err(err).Msg("Failed to wake job workers, job will be picked up by the next poll")
+	}
+}
+
+// wakeupLoop forwards wake-up messages to wake until ctx is cancelled or the worker shuts down
+func (w *Worker) wakeupLoop(ctx context.Context, q cache.Queue, wake chan<- struct{}) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.shutdownChan:
+			return
+		default:
+		}
+
+		_, ok, err := q.Pop(ctx, WakeupQueueName, wakeupWait)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Warn().Err(err).Str("worker_id", w.ID.String()).Msg("Failed to read job wake-up queue")
+			// Back off before retrying so an unavailable backend doesn't spin
+			select {
+			case <-time.After(w.Config.PollInterval):
+			case <-w.shutdownChan:
+				return
+			}
+			continue
+		}
+		if ok {
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/cache"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifyJobEnqueued(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("no-op without a wake-up queue", func(t *testing.T) {
+		SetWakeupQueue(nil)
+		notifyJobEnqueued(ctx, uuid.New())
+	})
+
+	t.Run("pushes the job id", func(t *testing.T) {
+		q := cache.NewMemoryQueue(10)
+		SetWakeupQueue(q)
+		defer SetWakeupQueue(nil)
+
+		jobID := uuid.New()
+		notifyJobEnqueued(ctx, jobID)
+
+		msg, ok, err := q.Pop(ctx, WakeupQueueName, 0)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, jobID.String(), string(msg))
+	})
+}
+
+func TestWorker_WakeupLoop(t *testing.T) {
+	cfg := &config.JobsConfig{PollInterval: time.Hour}
+	worker := NewWorker(cfg, nil, "secret", "http://localhost", nil)
+
+	q := cache.NewMemoryQueue(10)
+	wake := make(chan struct{}, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		worker.wakeupLoop(ctx, q, wake)
+		close(done)
+	}()
+
+	require.NoError(t, q.Push(ctx, WakeupQueueName, []byte("job")))
+
+	select {
+	case <-wake:
+	case <-time.After(time.Second):
+		t.Fatal("worker was not woken")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("wake-up loop did not stop")
+	}
+}
@@ -213,47 +213,62 @@ func (w *Worker) pollLoop(ctx context.Context) {
 	ticker := time.NewTicker(w.Config.PollInterval)
 	defer ticker.Stop()
 
+	// Wake up as soon as a job is enqueued, in addition to polling
+	wake := make(chan struct{}, 1)
+	if q := getWakeupQueue(); q != nil {
+		go w.wakeupLoop(ctx, q, wake)
+	}
+
 	for {
 		select {
 		case <-ticker.C:
-			// Don't accept new jobs if draining
-			if w.isDraining() {
-				continue
-			}
-
-			// Check if we have capacity
-			if w.hasCapacity() {
-				// Try to claim a job
-				job, err := w.Storage.ClaimNextJob(ctx, w.ID)
-				if err != nil {
-					log.Error().Err(err).Msg("Failed to claim job")
-					continue
-				}
-
-				if job != nil {
-					// Execute job in goroutine with panic recovery
-					go func(j *Job) {
-						defer func() {
-							if rec := recover(); rec != nil {
-								log.Error().
-									Interface("panic", rec).
-									Str("job_id", j.ID.String()).
-									Str("job_name", j.JobName).
-									Msg("Panic in job execution - recovered, marking job as failed")
-								// Mark job as failed (defers in executeJob will have already cleaned up job count)
-								_ = w.Storage.FailJob(context.Background(), j.ID, fmt.Sprintf("Internal error: job execution panic: %v", rec))
-							}
-						}()
-						w.executeJob(ctx, j)
-					}(job)
-				}
-			}
+			w.claimAndExecute(ctx)
+		case <-wake:
+			w.claimAndExecute(ctx)
 		case <-w.shutdownChan:
 			return
 		}
 	}
 }
 
+// claimAndExecute claims the next available job, if the worker has capacity, and runs it
+func (w *Worker) claimAndExecute(ctx context.Context) {
+	// Don't accept new jobs if draining
+	if w.isDraining() {
+		return
+	}
+
+	// Check if we have capacity
+	if !w.hasCapacity() {
+		return
+	}
+
+	// Try to claim a job
+	job, err := w.Storage.ClaimNextJob(ctx, w.ID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to claim job")
+		return
+	}
+
+	if job != nil {
+		// Execute job in goroutine with panic recovery
+		go func(j *Job) {
+			defer func() {
+				if rec := recover(); rec != nil {
+					log.Error().
+						Interface("panic", rec).
+						Str("job_id", j.ID.String()).
+						Str("job_name", j.JobName).
+						Msg("Panic in job execution - recovered, marking job as failed")
+					// Mark job as failed (defers in executeJob will have already cleaned up job count)
+					_ = w.Storage.FailJob(context.Background(), j.ID, fmt.Sprintf("Internal error: job execution panic: %v", rec))
+				}
+			}()
+			w.executeJob(ctx, j)
+		}(job)
+	}
+}
+
 // heartbeatLoop sends periodic heartbeats
 func (w *Worker) heartbeatLoop(ctx context.Context) {
 	defer func() {
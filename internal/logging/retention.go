@@ -51,6 +51,10 @@ func (r *RetentionService) Start() {
 		return
 	}
 	r.running = true
+	// A stopped service gets a fresh context so it can be restarted (e.g. on regaining leadership)
+	if r.ctx.Err() != nil {
+		r.ctx, r.cancel = context.WithCancel(context.Background())
+	}
 	r.mu.Unlock()
 
 	r.wg.Add(1)
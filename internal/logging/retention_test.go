@@ -155,6 +155,27 @@ func TestRetentionService_StartStop(t *testing.T) {
 		assert.False(t, svc.running)
 	})
 
+	t.Run("can be restarted after stop", func(t *testing.T) {
+		cfg := &config.LoggingConfig{
+			RetentionCheckInterval: time.Hour,
+			SystemRetentionDays:    30,
+		}
+		mockStorage := newMockLogStorage()
+		svc := NewRetentionService(cfg, mockStorage)
+
+		svc.Start()
+		time.Sleep(50 * time.Millisecond)
+		svc.Stop()
+
+		svc.Start()
+		time.Sleep(50 * time.Millisecond)
+		assert.True(t, svc.running)
+		svc.Stop()
+
+		// Cleanup runs once per start
+		assert.GreaterOrEqual(t, len(mockStorage.getDeleteCalls()), 2)
+	})
+
 	t.Run("cleanup runs on start", func(t *testing.T) {
 		cfg := &config.LoggingConfig{
 			RetentionCheckInterval: time.Hour,
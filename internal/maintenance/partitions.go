@@ -0,0 +1,421 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// partitionMonthLayout is the month suffix of partition names, e.g. retrieval_log_p202610
+const partitionMonthLayout = "200601"
+
+// ErrPartitionPolicyNotFound is returned for a table that is not partitioned by month
+var ErrPartitionPolicyNotFound = errors.New("table is not partitioned by month")
+
+// PartitionPolicy is the retention of a table partitioned by month on created_at
+type PartitionPolicy struct {
+	Schema string `json:"schema"`
+	Table  string `json:"table"`
+	// RetentionMonths is how many months of data are kept, nil keeps everything
+	RetentionMonths *int `json:"retention_months"`
+	// PremakeMonths is how many months are created ahead of the current one
+	PremakeMonths int         `json:"premake_months"`
+	LastManagedAt *time.Time  `json:"last_managed_at,omitempty"`
+	LastError     *string     `json:"last_error,omitempty"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+	Partitions    []Partition `json:"partitions,omitempty"`
+}
+
+// Partition is a partition of a table partitioned by month
+type Partition struct {
+	Name string `json:"name"`
+	// From and To are the month covered by the partition, nil for the default partition
+	From          *time.Time `json:"from,omitempty"`
+	To            *time.Time `json:"to,omitempty"`
+	Default       bool       `json:"default"`
+	EstimatedRows int64      `json:"estimated_rows"`
+	Bytes         int64      `json:"bytes"`
+}
+
+// PartitionResult is the outcome of managing the partitions of a table
+type PartitionResult struct {
+	Schema  string   `json:"schema"`
+	Table   string   `json:"table"`
+	Created []string `json:"created"`
+	Dropped []string `json:"dropped"`
+	// MovedRows were moved from the default partition into created partitions
+	MovedRows int64 `json:"moved_rows"`
+	// PrunedRows were deleted from the default partition because they expired
+	PrunedRows int64 `json:"pruned_rows"`
+}
+
+// monthStart returns the first instant of the UTC month of t
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// partitionName returns the name of the partition of table covering month
+func partitionName(table string, month time.Time) string {
+	return table + "_p" + month.UTC().Format(partitionMonthLayout)
+}
+
+// partitionMonth returns the month covered by a partition of table, false if name is not
+// a monthly partition of table
+func partitionMonth(table, name string) (time.Time, bool) {
+	suffix, ok := strings.CutPrefix(name, table+"_p")
+	if !ok || len(suffix) != len(partitionMonthLayout) {
+		return time.Time{}, false
+	}
+	month, err := time.Parse(partitionMonthLayout, suffix)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return month, true
+}
+
+// retentionCutoff returns the instant before which data of policy expires, nil if it is
+// kept forever
+func retentionCutoff(policy PartitionPolicy, now time.Time) *time.Time {
+	if policy.RetentionMonths == nil {
+		return nil
+	}
+	cutoff := now.UTC().AddDate(0, -*policy.RetentionMonths, 0)
+	return &cutoff
+}
+
+// planPartitions returns the months to create, from the current one to PremakeMonths
+// ahead, and the partitions whose whole month is older than the retention. Partitions
+// that don't follow the naming scheme are never dropped.
+func planPartitions(policy PartitionPolicy, existing []string, now time.Time) (create []time.Time, drop []string) {
+	have := make(map[string]bool, len(existing))
+	for _, name := range existing {
+		have[name] = true
+	}
+
+	current := monthStart(now)
+	for i := 0; i <= policy.PremakeMonths; i++ {
+		month := current.AddDate(0, i, 0)
+		if !have[partitionName(policy.Table, month)] {
+			create = append(create, month)
+		}
+	}
+
+	if cutoff := retentionCutoff(policy, now); cutoff != nil {
+		for _, name := range existing {
+			month, ok := partitionMonth(policy.Table, name)
+			if ok && !month.AddDate(0, 1, 0).After(*cutoff) {
+				drop = append(drop, name)
+			}
+		}
+		sort.Strings(drop)
+	}
+	return create, drop
+}
+
+const partitionPolicyColumns = `schema_name, table_name, retention_months, premake_months, last_managed_at,
+	last_error, updated_at`
+
+func scanPartitionPolicy(row pgx.Row) (PartitionPolicy, error) {
+	var p PartitionPolicy
+	err := row.Scan(&p.Schema, &p.Table, &p.RetentionMonths, &p.PremakeMonths, &p.LastManagedAt,
+		&p.LastError, &p.UpdatedAt)
+	return p, err
+}
+
+// ListPartitionPolicies returns the policy of every partitioned table ordered by table
+func (s *Store) ListPartitionPolicies(ctx context.Context) ([]PartitionPolicy, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT `+partitionPolicyColumns+`
+		FROM api.table_partition_policies
+		ORDER BY schema_name, table_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partition policies: %w", err)
+	}
+	defer rows.Close()
+
+	policies := []PartitionPolicy{}
+	for rows.Next() {
+		p, err := scanPartitionPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan partition policy: %w", err)
+		}
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
+
+// GetPartitionPolicy returns the policy of schema.table
+func (s *Store) GetPartitionPolicy(ctx context.Context, schema, table string) (*PartitionPolicy, error) {
+	p, err := scanPartitionPolicy(s.db.QueryRow(ctx, `
+		SELECT `+partitionPolicyColumns+`
+		FROM api.table_partition_policies
+		WHERE schema_name = $1 AND table_name = $2
+	`, schema, table))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPartitionPolicyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partition policy: %w", err)
+	}
+	return &p, nil
+}
+
+// UpdatePartitionPolicy changes the retention and premade months of schema.table. Expired
+// partitions are dropped the next time the partitions are managed.
+func (s *Store) UpdatePartitionPolicy(ctx context.Context, schema, table string, retentionMonths *int, premakeMonths int, updatedBy *uuid.UUID) (*PartitionPolicy, error) {
+	p, err := scanPartitionPolicy(s.db.QueryRow(ctx, `
+		UPDATE api.table_partition_policies
+		SET retention_months = $3, premake_months = $4, updated_by = $5, updated_at = NOW()
+		WHERE schema_name = $1 AND table_name = $2
+		RETURNING `+partitionPolicyColumns,
+		schema, table, retentionMonths, premakeMonths, updatedBy))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPartitionPolicyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update partition policy: %w", err)
+	}
+	return &p, nil
+}
+
+// Partitions returns the partitions of schema.table with their estimated size, oldest first
+// and the default partition last
+func (s *Store) Partitions(ctx context.Context, schema, table string) ([]Partition, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT c.relname,
+			pg_get_expr(c.relpartbound, c.oid) = 'DEFAULT',
+			GREATEST(c.reltuples, 0)::bigint,
+			pg_total_relation_size(c.oid)
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class p ON p.oid = i.inhparent
+		JOIN pg_namespace n ON n.oid = p.relnamespace
+		WHERE n.nspname = $1 AND p.relname = $2
+	`, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partitions: %w", err)
+	}
+	defer rows.Close()
+
+	partitions := []Partition{}
+	for rows.Next() {
+		var p Partition
+		if err := rows.Scan(&p.Name, &p.Default, &p.EstimatedRows, &p.Bytes); err != nil {
+			return nil, fmt.Errorf("failed to scan partition: %w", err)
+		}
+		if month, ok := partitionMonth(table, p.Name); ok && !p.Default {
+			to := month.AddDate(0, 1, 0)
+			p.From, p.To = &month, &to
+		}
+		partitions = append(partitions, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(partitions, func(i, j int) bool {
+		a, b := partitions[i], partitions[j]
+		if a.Default != b.Default {
+			return b.Default
+		}
+		return a.Name < b.Name
+	})
+	return partitions, nil
+}
+
+// lockPartitions serializes partition changes of schema.table across instances for the
+// rest of tx
+func lockPartitions(ctx context.Context, tx pgx.Tx, schema, table string) error {
+	_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended('fluxbase.partitions.' || $1 || '.' || $2, 0))`, schema, table)
+	return err
+}
+
+// createPartition creates the partition of schema.table covering month, moving the rows of
+// that month out of the default partition if there is one. The partition is built detached
+// and then attached, so inserts into the table are not blocked meanwhile. It returns false
+// if the partition already exists.
+func (s *Store) createPartition(ctx context.Context, schema, table string, month time.Time, defaultPartition string) (bool, int64, error) {
+	name := partitionName(table, month)
+	parent := pgx.Identifier{schema, table}.Sanitize()
+	ident := pgx.Identifier{schema, name}.Sanitize()
+	from, to := month, month.AddDate(0, 1, 0)
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return false, 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := lockPartitions(ctx, tx, schema, table); err != nil {
+		return false, 0, err
+	}
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, ident).Scan(&exists); err != nil {
+		return false, 0, err
+	}
+	if exists {
+		return false, 0, nil
+	}
+
+	if _, err := tx.Exec(ctx, `CREATE TABLE `+ident+` (LIKE `+parent+` INCLUDING DEFAULTS INCLUDING CONSTRAINTS)`); err != nil {
+		return false, 0, err
+	}
+	// Partitions are only read through the table, whose policies don't apply to direct access
+	if _, err := tx.Exec(ctx, `ALTER TABLE `+ident+` ENABLE ROW LEVEL SECURITY`); err != nil {
+		return false, 0, err
+	}
+
+	var moved int64
+	if defaultPartition != "" {
+		tag, err := tx.Exec(ctx, `
+			WITH moved AS (
+				DELETE FROM `+pgx.Identifier{schema, defaultPartition}.Sanitize()+`
+				WHERE created_at >= $1 AND created_at < $2
+				RETURNING *
+			)
+			INSERT INTO `+ident+` SELECT * FROM moved
+		`, from, to)
+		if err != nil {
+			return false, 0, err
+		}
+		moved = tag.RowsAffected()
+	}
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf(`ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM ('%s') TO ('%s')`,
+		parent, ident, from.Format(time.RFC3339), to.Format(time.RFC3339))); err != nil {
+		return false, 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, 0, err
+	}
+	return true, moved, nil
+}
+
+// dropPartition drops an expired partition of schema.table
+func (s *Store) dropPartition(ctx context.Context, schema, table, name string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := lockPartitions(ctx, tx, schema, table); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DROP TABLE IF EXISTS `+pgx.Identifier{schema, name}.Sanitize()); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// pruneDefaultPartition deletes the expired rows of the default partition, which only holds
+// rows outside the created months
+func (s *Store) pruneDefaultPartition(ctx context.Context, schema, name string, cutoff time.Time) (int64, error) {
+	tag, err := s.db.Exec(ctx, `DELETE FROM `+pgx.Identifier{schema, name}.Sanitize()+` WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// markPartitionsManaged records when the partitions of schema.table were last managed and
+// the error of that run, if any
+func (s *Store) markPartitionsManaged(ctx context.Context, schema, table string, at time.Time, errMsg *string) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE api.table_partition_policies
+		SET last_managed_at = $3, last_error = $4
+		WHERE schema_name = $1 AND table_name = $2
+	`, schema, table, at, errMsg)
+	if err != nil {
+		return fmt.Errorf("failed to update partition policy: %w", err)
+	}
+	return nil
+}
+
+// ManagePartitions creates the upcoming monthly partitions of schema.table and drops the
+// partitions older than its retention. Dropping a partition is instant, unlike deleting
+// its rows.
+func (r *Runner) ManagePartitions(ctx context.Context, schema, table string) (*PartitionResult, error) {
+	policy, err := r.store.GetPartitionPolicy(ctx, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	partitions, err := r.store.Partitions(ctx, schema, table)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(partitions))
+	defaultPartition := ""
+	for _, p := range partitions {
+		if p.Default {
+			defaultPartition = p.Name
+		}
+		names = append(names, p.Name)
+	}
+
+	now := r.now()
+	create, drop := planPartitions(*policy, names, now)
+	result := &PartitionResult{Schema: schema, Table: table, Created: []string{}, Dropped: []string{}}
+
+	runErr := func() error {
+		for _, month := range create {
+			created, moved, err := r.store.createPartition(ctx, schema, table, month, defaultPartition)
+			if err != nil {
+				return fmt.Errorf("failed to create partition %s: %w", partitionName(table, month), err)
+			}
+			if created {
+				result.Created = append(result.Created, partitionName(table, month))
+				result.MovedRows += moved
+			}
+		}
+		for _, name := range drop {
+			if err := r.store.dropPartition(ctx, schema, table, name); err != nil {
+				return fmt.Errorf("failed to drop partition %s: %w", name, err)
+			}
+			result.Dropped = append(result.Dropped, name)
+		}
+		if cutoff := retentionCutoff(*policy, now); cutoff != nil && defaultPartition != "" {
+			pruned, err := r.store.pruneDefaultPartition(ctx, schema, defaultPartition, *cutoff)
+			if err != nil {
+				return fmt.Errorf("failed to prune default partition: %w", err)
+			}
+			result.PrunedRows = pruned
+		}
+		return nil
+	}()
+
+	var errMsg *string
+	if runErr != nil {
+		msg := runErr.Error()
+		errMsg = &msg
+	}
+	if err := r.store.markPartitionsManaged(ctx, schema, table, now, errMsg); err != nil {
+		log.Error().Err(err).Str("schema", schema).Str("table", table).Msg("Failed to record partition management")
+	}
+	if runErr != nil {
+		return result, runErr
+	}
+
+	if len(result.Created) > 0 || len(result.Dropped) > 0 || result.PrunedRows > 0 {
+		log.Info().
+			Str("schema", schema).
+			Str("table", table).
+			Strs("created", result.Created).
+			Strs("dropped", result.Dropped).
+			Int64("moved_rows", result.MovedRows).
+			Int64("pruned_rows", result.PrunedRows).
+			Msg("Managed table partitions")
+	}
+	return result, nil
+}
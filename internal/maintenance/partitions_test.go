@@ -0,0 +1,66 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartitionMonth(t *testing.T) {
+	month, ok := partitionMonth("retrieval_log", "retrieval_log_p202610")
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), month)
+	assert.Equal(t, "retrieval_log_p202610", partitionName("retrieval_log", month))
+
+	for _, name := range []string{"retrieval_log_default", "retrieval_log_p2026", "retrieval_log_p202613", "rls_audit_log_p202610", "retrieval_log_p202610_old"} {
+		_, ok := partitionMonth("retrieval_log", name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestPlanPartitions(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	existing := []string{
+		"rls_audit_log_default",
+		"rls_audit_log_p202603",
+		"rls_audit_log_p202604",
+		"rls_audit_log_p202605",
+		"rls_audit_log_p202610",
+		"rls_audit_log_p202611",
+		"rls_audit_log_archive",
+	}
+
+	policy := PartitionPolicy{Schema: "auth", Table: "rls_audit_log", PremakeMonths: 3}
+	create, drop := planPartitions(policy, existing, now)
+	assert.Equal(t, []time.Time{
+		time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, create)
+	assert.Empty(t, drop, "no retention keeps every partition")
+
+	retention := 6
+	policy.RetentionMonths = &retention
+	_, drop = planPartitions(policy, existing, now)
+	// April ends on May 1st, before the cutoff of April 18th; May still holds data to keep
+	assert.Equal(t, []string{"rls_audit_log_p202603"}, drop)
+
+	retention = 1
+	_, drop = planPartitions(policy, existing, now)
+	assert.Equal(t, []string{"rls_audit_log_p202603", "rls_audit_log_p202604", "rls_audit_log_p202605"}, drop)
+}
+
+func TestPlanPartitions_LocalTime(t *testing.T) {
+	// 23:30 on October 31st in New York is already November in UTC
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	now := time.Date(2026, 10, 31, 23, 30, 0, 0, ny)
+
+	create, _ := planPartitions(PartitionPolicy{Table: "retrieval_log", PremakeMonths: 1}, nil, now)
+	assert.Equal(t, []time.Time{
+		time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC),
+	}, create)
+}
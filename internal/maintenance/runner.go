@@ -0,0 +1,154 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultRunTimeout bounds a run when no timeout is configured
+const defaultRunTimeout = 2 * time.Hour
+
+// RunRequest describes a maintenance run
+type RunRequest struct {
+	Schema      string
+	Table       string
+	Operation   Operation
+	Trigger     string
+	ScheduleID  *string
+	TriggeredBy *uuid.UUID
+}
+
+// Runner runs VACUUM and ANALYZE and records each run. VACUUM cannot run inside a
+// transaction, so statements are executed directly on the pool.
+type Runner struct {
+	store   *Store
+	timeout time.Duration
+	now     func() time.Time
+
+	// ctx is cancelled by Stop to abort background runs on shutdown
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRunner creates a maintenance runner. Runs are cancelled after timeout.
+func NewRunner(store *Store, timeout time.Duration) *Runner {
+	if timeout <= 0 {
+		timeout = defaultRunTimeout
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Runner{
+		store:   store,
+		timeout: timeout,
+		now:     time.Now,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Start records a run and executes it in the background, returning the running run.
+// Use ListRuns to follow its progress.
+func (r *Runner) Start(ctx context.Context, req RunRequest) (*Run, error) {
+	run, err := r.begin(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	started := *run
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.execute(r.ctx, run)
+	}()
+	return &started, nil
+}
+
+// Run records a run and executes it, returning the finished run
+func (r *Runner) Run(ctx context.Context, req RunRequest) (*Run, error) {
+	run, err := r.begin(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	r.execute(ctx, run)
+	return run, nil
+}
+
+// Stop cancels the background runs and waits for them to record their result
+func (r *Runner) Stop() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// begin validates the request and records the run as running
+func (r *Runner) begin(ctx context.Context, req RunRequest) (*Run, error) {
+	if !req.Operation.Valid() {
+		return nil, fmt.Errorf("unknown maintenance operation: %s", req.Operation)
+	}
+
+	exists, err := r.store.TableExists(ctx, req.Schema, req.Table)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrTableNotFound
+	}
+
+	run := &Run{
+		ScheduleID:     req.ScheduleID,
+		Schema:         req.Schema,
+		Table:          req.Table,
+		Operation:      req.Operation,
+		Trigger:        req.Trigger,
+		DeadRowsBefore: r.store.deadRows(ctx, req.Schema, req.Table),
+	}
+	if err := r.store.startRun(ctx, run, r.timeout, req.TriggeredBy); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// execute runs the statement and records the outcome
+func (r *Runner) execute(ctx context.Context, run *Run) {
+	runCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := r.now()
+	_, execErr := r.store.db.Exec(runCtx, run.Operation.Statement(run.Schema, run.Table))
+	finished := r.now()
+	duration := finished.Sub(start).Milliseconds()
+
+	run.FinishedAt = &finished
+	run.DurationMs = &duration
+	run.Status = StatusCompleted
+	if execErr != nil {
+		msg := execErr.Error()
+		run.Status = StatusFailed
+		run.Error = &msg
+	}
+
+	// Record the result even if the run was cancelled on shutdown
+	recordCtx, recordCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer recordCancel()
+	run.DeadRowsAfter = r.store.deadRows(recordCtx, run.Schema, run.Table)
+	if err := r.store.finishRun(recordCtx, run); err != nil {
+		log.Error().Err(err).Str("run_id", run.ID).Msg("Failed to record maintenance run result")
+	}
+
+	event := log.Info()
+	if execErr != nil {
+		event = log.Error().Err(execErr)
+	}
+	event.
+		Str("schema", run.Schema).
+		Str("table", run.Table).
+		Str("operation", string(run.Operation)).
+		Str("trigger", run.Trigger).
+		Int64("duration_ms", duration).
+		Msg("Table maintenance run finished")
+}
@@ -0,0 +1,218 @@
+package maintenance
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// checkInterval is how often the scheduler looks for due schedules
+const checkInterval = time.Minute
+
+// partitionInterval is how often the partitions of log tables are managed
+const partitionInterval = time.Hour
+
+// cronParser accepts the same expressions as the job and function schedulers:
+// 5 fields, 6 fields with seconds, descriptors like @daily and a CRON_TZ= prefix
+var cronParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// ParseSchedule validates a cron expression
+func ParseSchedule(expr string) (cron.Schedule, error) {
+	return cronParser.Parse(expr)
+}
+
+// NextRun returns when sc fires next, nil if its expression is invalid
+func NextRun(sc Schedule, now time.Time) *time.Time {
+	parsed, err := ParseSchedule(sc.CronSchedule)
+	if err != nil {
+		return nil
+	}
+	next := parsed.Next(now)
+	return &next
+}
+
+// isDue reports whether sc fired since it last ran or was last changed, so enabling or
+// rescheduling does not catch up on past firings. A schedule that missed several firings,
+// e.g. while no instance was leader, runs once.
+func isDue(sc Schedule, parsed cron.Schedule, now time.Time) bool {
+	from := sc.UpdatedAt
+	if sc.LastRunAt != nil && sc.LastRunAt.After(from) {
+		from = *sc.LastRunAt
+	}
+	return !parsed.Next(from).After(now)
+}
+
+// Scheduler runs due maintenance schedules one at a time, prunes the run history and
+// hourly creates and drops the partitions of log tables. Schedules are read from the
+// database on every check, so schedules created on any instance are picked up. It must
+// run on a single node (leader-elected).
+type Scheduler struct {
+	cfg    *config.MaintenanceConfig
+	store  *Store
+	runner *Runner
+	now    func() time.Time
+
+	// partitionsManagedAt is when partitions were last managed, zero before the first check
+	partitionsManagedAt time.Time
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running bool
+	mu      sync.Mutex
+}
+
+// NewScheduler creates a maintenance scheduler
+func NewScheduler(cfg *config.MaintenanceConfig, store *Store, runner *Runner) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Scheduler{
+		cfg:    cfg,
+		store:  store,
+		runner: runner,
+		now:    time.Now,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start begins running schedules
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = true
+	if s.ctx.Err() != nil {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+	}
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.run()
+
+	log.Info().Dur("run_timeout", s.cfg.RunTimeout).Msg("Table maintenance scheduler started")
+}
+
+// Stop stops running schedules, cancelling the run in progress
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	s.mu.Unlock()
+
+	s.cancel()
+	s.wg.Wait()
+
+	log.Info().Msg("Table maintenance scheduler stopped")
+}
+
+// run checks on start and then every check interval
+func (s *Scheduler) run() {
+	defer s.wg.Done()
+
+	s.check(s.ctx)
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.check(s.ctx)
+		}
+	}
+}
+
+// check runs the due schedules, prunes expired history and manages partitions when due
+func (s *Scheduler) check(ctx context.Context) {
+	s.checkSchedules(ctx)
+
+	if now := s.now(); now.Sub(s.partitionsManagedAt) >= partitionInterval {
+		s.partitionsManagedAt = now
+		s.managePartitions(ctx)
+	}
+}
+
+// checkSchedules runs the due schedules and prunes expired history
+func (s *Scheduler) checkSchedules(ctx context.Context) {
+	schedules, err := s.store.enabledSchedules(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load maintenance schedules")
+		return
+	}
+
+	for _, sc := range schedules {
+		if ctx.Err() != nil {
+			return
+		}
+		parsed, err := ParseSchedule(sc.CronSchedule)
+		if err != nil {
+			log.Warn().Err(err).Str("schedule_id", sc.ID).Msg("Skipping maintenance schedule with invalid cron expression")
+			continue
+		}
+		now := s.now()
+		if !isDue(sc, parsed, now) {
+			continue
+		}
+
+		// Mark the schedule first, so a failing run is not retried every minute
+		if err := s.store.markScheduleRun(ctx, sc.ID, now); err != nil {
+			log.Error().Err(err).Str("schedule_id", sc.ID).Msg("Failed to mark maintenance schedule")
+			continue
+		}
+		id := sc.ID
+		if _, err := s.runner.Run(ctx, RunRequest{
+			Schema:     sc.Schema,
+			Table:      sc.Table,
+			Operation:  sc.Operation,
+			Trigger:    TriggerScheduled,
+			ScheduleID: &id,
+		}); err != nil {
+			log.Warn().Err(err).
+				Str("schema", sc.Schema).
+				Str("table", sc.Table).
+				Str("operation", string(sc.Operation)).
+				Msg("Skipped scheduled table maintenance")
+		}
+	}
+
+	pruned, err := s.store.PruneRuns(ctx, s.now().AddDate(0, 0, -s.cfg.HistoryRetentionDays))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to prune table maintenance history")
+		return
+	}
+	if pruned > 0 {
+		log.Debug().Int64("runs", pruned).Msg("Pruned expired table maintenance runs")
+	}
+}
+
+// managePartitions creates and drops the partitions of every partitioned log table
+func (s *Scheduler) managePartitions(ctx context.Context) {
+	policies, err := s.store.ListPartitionPolicies(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load partition policies")
+		return
+	}
+
+	for _, p := range policies {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err := s.runner.ManagePartitions(ctx, p.Schema, p.Table); err != nil {
+			log.Error().Err(err).Str("schema", p.Schema).Str("table", p.Table).Msg("Failed to manage table partitions")
+		}
+	}
+}
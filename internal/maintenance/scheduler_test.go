@@ -0,0 +1,66 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperation(t *testing.T) {
+	assert.True(t, OperationVacuum.Valid())
+	assert.True(t, OperationAnalyze.Valid())
+	assert.True(t, OperationVacuumAnalyze.Valid())
+	assert.False(t, Operation("vacuum_full").Valid())
+	assert.False(t, Operation("").Valid())
+
+	assert.Equal(t, `VACUUM "public"."chunks"`, OperationVacuum.Statement("public", "chunks"))
+	assert.Equal(t, `ANALYZE "logs"."entries"`, OperationAnalyze.Statement("logs", "entries"))
+	assert.Equal(t, `VACUUM (ANALYZE) "public"."my ""odd"" table"`, OperationVacuumAnalyze.Statement("public", `my "odd" table`))
+}
+
+func TestParseSchedule(t *testing.T) {
+	for _, expr := range []string{"0 3 * * *", "0 0 3 * * *", "@daily", "CRON_TZ=Europe/Berlin 30 2 * * 0"} {
+		_, err := ParseSchedule(expr)
+		assert.NoError(t, err, expr)
+	}
+
+	for _, expr := range []string{"", "every night", "61 * * * *", "CRON_TZ=Nowhere/City 0 3 * * *"} {
+		_, err := ParseSchedule(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestIsDue(t *testing.T) {
+	parsed, err := ParseSchedule("0 3 * * *")
+	require.NoError(t, err)
+
+	created := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	sc := Schedule{CreatedAt: created, UpdatedAt: created}
+
+	assert.False(t, isDue(sc, parsed, time.Date(2026, 3, 11, 2, 59, 0, 0, time.UTC)), "before the first firing")
+	assert.True(t, isDue(sc, parsed, time.Date(2026, 3, 11, 3, 0, 0, 0, time.UTC)), "at the first firing")
+
+	lastRun := time.Date(2026, 3, 11, 3, 0, 30, 0, time.UTC)
+	sc.LastRunAt = &lastRun
+	assert.False(t, isDue(sc, parsed, time.Date(2026, 3, 11, 23, 0, 0, 0, time.UTC)), "already ran today")
+	assert.True(t, isDue(sc, parsed, time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)), "missed firings run once")
+
+	sc.UpdatedAt = time.Date(2026, 3, 14, 8, 0, 0, 0, time.UTC)
+	assert.False(t, isDue(sc, parsed, time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)), "re-enabled schedules don't catch up")
+}
+
+func TestNextRun(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	next := NextRun(Schedule{CronSchedule: "0 3 * * *"}, now)
+	require.NotNil(t, next)
+	assert.Equal(t, time.Date(2026, 3, 11, 3, 0, 0, 0, time.UTC), next.UTC())
+
+	next = NextRun(Schedule{CronSchedule: "CRON_TZ=Asia/Tokyo 0 3 * * *"}, now)
+	require.NotNil(t, next)
+	assert.Equal(t, time.Date(2026, 3, 10, 18, 0, 0, 0, time.UTC), next.UTC())
+
+	assert.Nil(t, NextRun(Schedule{CronSchedule: "nope"}, now))
+}
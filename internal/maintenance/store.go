@@ -0,0 +1,460 @@
+// Package maintenance reports autovacuum activity and runs VACUUM and ANALYZE on user
+// tables, manually or on off-peak schedules, keeping a history of every run. It also
+// manages the monthly partitions of append-only log tables and their retention.
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/database"
+)
+
+// Operation is a maintenance command
+type Operation string
+
+const (
+	OperationVacuum        Operation = "vacuum"
+	OperationAnalyze       Operation = "analyze"
+	OperationVacuumAnalyze Operation = "vacuum_analyze"
+)
+
+// Valid reports whether o is a known operation
+func (o Operation) Valid() bool {
+	switch o {
+	case OperationVacuum, OperationAnalyze, OperationVacuumAnalyze:
+		return true
+	}
+	return false
+}
+
+// Statement returns the SQL running o on schema.table. VACUUM FULL is deliberately not
+// offered: it locks the table exclusively for the whole rewrite.
+func (o Operation) Statement(schema, table string) string {
+	ident := pgx.Identifier{schema, table}.Sanitize()
+	switch o {
+	case OperationVacuum:
+		return "VACUUM " + ident
+	case OperationVacuumAnalyze:
+		return "VACUUM (ANALYZE) " + ident
+	default:
+		return "ANALYZE " + ident
+	}
+}
+
+// How a run was started
+const (
+	TriggerManual    = "manual"
+	TriggerScheduled = "scheduled"
+)
+
+// Run statuses
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+var (
+	// ErrTableNotFound is returned for maintenance on a table that does not exist
+	ErrTableNotFound = errors.New("table not found")
+	// ErrRunInProgress is returned when maintenance of the table is already running
+	ErrRunInProgress = errors.New("maintenance of this table is already running")
+	// ErrScheduleNotFound is returned for an unknown schedule ID
+	ErrScheduleNotFound = errors.New("maintenance schedule not found")
+	// ErrScheduleExists is returned when the table already has a schedule for the operation
+	ErrScheduleExists = errors.New("table already has a schedule for this operation")
+)
+
+// TableActivity is the vacuum and analyze activity of a table from pg_stat_user_tables
+type TableActivity struct {
+	Schema               string     `json:"schema"`
+	Table                string     `json:"table"`
+	LiveRows             int64      `json:"live_rows"`
+	DeadRows             int64      `json:"dead_rows"`
+	ModifiedSinceAnalyze int64      `json:"modified_since_analyze"`
+	LastVacuum           *time.Time `json:"last_vacuum"`
+	LastAutovacuum       *time.Time `json:"last_autovacuum"`
+	LastAnalyze          *time.Time `json:"last_analyze"`
+	LastAutoanalyze      *time.Time `json:"last_autoanalyze"`
+	VacuumCount          int64      `json:"vacuum_count"`
+	AutovacuumCount      int64      `json:"autovacuum_count"`
+	AnalyzeCount         int64      `json:"analyze_count"`
+	AutoanalyzeCount     int64      `json:"autoanalyze_count"`
+	// AutovacuumThreshold is the dead row count at which autovacuum picks the table up,
+	// computed from the server-wide autovacuum settings
+	AutovacuumThreshold int64 `json:"autovacuum_threshold"`
+	NeedsVacuum         bool  `json:"needs_vacuum"`
+}
+
+// VacuumProgress is a VACUUM that is currently running, from pg_stat_progress_vacuum
+type VacuumProgress struct {
+	PID               int        `json:"pid"`
+	Schema            string     `json:"schema"`
+	Table             string     `json:"table"`
+	Phase             string     `json:"phase"`
+	HeapBlocksTotal   int64      `json:"heap_blocks_total"`
+	HeapBlocksScanned int64      `json:"heap_blocks_scanned"`
+	Autovacuum        bool       `json:"autovacuum"`
+	StartedAt         *time.Time `json:"started_at"`
+}
+
+// Run is a manual or scheduled maintenance run
+type Run struct {
+	ID             string     `json:"id"`
+	ScheduleID     *string    `json:"schedule_id"`
+	Schema         string     `json:"schema"`
+	Table          string     `json:"table"`
+	Operation      Operation  `json:"operation"`
+	Trigger        string     `json:"trigger"`
+	Status         string     `json:"status"`
+	Error          *string    `json:"error,omitempty"`
+	DeadRowsBefore *int64     `json:"dead_rows_before"`
+	DeadRowsAfter  *int64     `json:"dead_rows_after"`
+	StartedAt      time.Time  `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+	DurationMs     *int64     `json:"duration_ms"`
+	TriggeredBy    *string    `json:"triggered_by,omitempty"`
+}
+
+// RunFilter selects the runs returned by ListRuns
+type RunFilter struct {
+	Schema string
+	Table  string
+	Limit  int
+}
+
+// Schedule runs an operation on a table whenever its cron expression fires
+type Schedule struct {
+	ID           string     `json:"id"`
+	Schema       string     `json:"schema"`
+	Table        string     `json:"table"`
+	Operation    Operation  `json:"operation"`
+	CronSchedule string     `json:"cron_schedule"`
+	Enabled      bool       `json:"enabled"`
+	LastRunAt    *time.Time `json:"last_run_at"`
+	NextRunAt    *time.Time `json:"next_run_at,omitempty"`
+	CreatedBy    *string    `json:"created_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// Store reads maintenance statistics and persists schedules and runs
+type Store struct {
+	db *pgxpool.Pool
+}
+
+// NewStore creates a maintenance store
+func NewStore(db *pgxpool.Pool) *Store {
+	return &Store{db: db}
+}
+
+// Activity returns the vacuum activity of every user table, or of a single schema if schema
+// is not empty, most dead rows first
+func (s *Store) Activity(ctx context.Context, schema string) ([]TableActivity, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT s.schemaname, s.relname, s.n_live_tup, s.n_dead_tup, s.n_mod_since_analyze,
+		       s.last_vacuum, s.last_autovacuum, s.last_analyze, s.last_autoanalyze,
+		       s.vacuum_count, s.autovacuum_count, s.analyze_count, s.autoanalyze_count,
+		       (current_setting('autovacuum_vacuum_threshold')::float8
+		        + current_setting('autovacuum_vacuum_scale_factor')::float8 * GREATEST(c.reltuples, 0))::bigint
+		FROM pg_stat_user_tables s
+		JOIN pg_class c ON c.oid = s.relid
+		WHERE $1 = '' OR s.schemaname = $1
+		ORDER BY s.n_dead_tup DESC, s.schemaname, s.relname
+	`, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vacuum activity: %w", err)
+	}
+	defer rows.Close()
+
+	tables := []TableActivity{}
+	for rows.Next() {
+		var t TableActivity
+		if err := rows.Scan(&t.Schema, &t.Table, &t.LiveRows, &t.DeadRows, &t.ModifiedSinceAnalyze,
+			&t.LastVacuum, &t.LastAutovacuum, &t.LastAnalyze, &t.LastAutoanalyze,
+			&t.VacuumCount, &t.AutovacuumCount, &t.AnalyzeCount, &t.AutoanalyzeCount,
+			&t.AutovacuumThreshold); err != nil {
+			return nil, fmt.Errorf("failed to scan vacuum activity: %w", err)
+		}
+		t.NeedsVacuum = t.DeadRows > t.AutovacuumThreshold
+		tables = append(tables, t)
+	}
+	return tables, rows.Err()
+}
+
+// RunningVacuums returns the manual and automatic vacuums in progress
+func (s *Store) RunningVacuums(ctx context.Context) ([]VacuumProgress, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT p.pid, n.nspname, c.relname, p.phase, p.heap_blks_total, p.heap_blks_scanned,
+		       COALESCE(a.backend_type = 'autovacuum worker', false), a.query_start
+		FROM pg_stat_progress_vacuum p
+		JOIN pg_class c ON c.oid = p.relid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_stat_activity a ON a.pid = p.pid
+		ORDER BY a.query_start
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vacuum progress: %w", err)
+	}
+	defer rows.Close()
+
+	running := []VacuumProgress{}
+	for rows.Next() {
+		var p VacuumProgress
+		if err := rows.Scan(&p.PID, &p.Schema, &p.Table, &p.Phase, &p.HeapBlocksTotal, &p.HeapBlocksScanned,
+			&p.Autovacuum, &p.StartedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan vacuum progress: %w", err)
+		}
+		running = append(running, p)
+	}
+	return running, rows.Err()
+}
+
+// TableExists reports whether schema.table is a table that can be vacuumed
+func (s *Store) TableExists(ctx context.Context, schema, table string) (bool, error) {
+	var exists bool
+	err := s.db.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1 AND c.relname = $2 AND c.relkind IN ('r', 'm', 'p')
+		)
+	`, schema, table).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check table: %w", err)
+	}
+	return exists, nil
+}
+
+// deadRows returns the dead row count of a table, nil when it has no statistics yet
+func (s *Store) deadRows(ctx context.Context, schema, table string) *int64 {
+	var dead int64
+	err := s.db.QueryRow(ctx, `
+		SELECT n_dead_tup FROM pg_stat_user_tables WHERE schemaname = $1 AND relname = $2
+	`, schema, table).Scan(&dead)
+	if err != nil {
+		return nil
+	}
+	return &dead
+}
+
+// startRun records a running run. Runs left running for longer than staleAfter, by an
+// instance that stopped mid-run, are marked failed first so they don't block the table.
+func (s *Store) startRun(ctx context.Context, run *Run, staleAfter time.Duration, triggeredBy *uuid.UUID) error {
+	if _, err := s.db.Exec(ctx, `
+		UPDATE api.table_maintenance_runs
+		SET status = 'failed', error = 'interrupted', finished_at = NOW()
+		WHERE status = 'running' AND schema_name = $1 AND table_name = $2 AND started_at < NOW() - make_interval(secs => $3)
+	`, run.Schema, run.Table, staleAfter.Seconds()); err != nil {
+		return fmt.Errorf("failed to expire interrupted maintenance runs: %w", err)
+	}
+
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO api.table_maintenance_runs
+			(schedule_id, schema_name, table_name, operation, trigger, dead_rows_before, triggered_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id::text, started_at
+	`, run.ScheduleID, run.Schema, run.Table, run.Operation, run.Trigger, run.DeadRowsBefore, triggeredBy).Scan(&run.ID, &run.StartedAt)
+	if database.IsUniqueViolation(err) {
+		return ErrRunInProgress
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record maintenance run: %w", err)
+	}
+	run.Status = StatusRunning
+	if triggeredBy != nil {
+		id := triggeredBy.String()
+		run.TriggeredBy = &id
+	}
+	return nil
+}
+
+// finishRun records the outcome of a run
+func (s *Store) finishRun(ctx context.Context, run *Run) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE api.table_maintenance_runs
+		SET status = $2, error = $3, dead_rows_after = $4, finished_at = $5, duration_ms = $6
+		WHERE id = $1
+	`, run.ID, run.Status, run.Error, run.DeadRowsAfter, run.FinishedAt, run.DurationMs)
+	if err != nil {
+		return fmt.Errorf("failed to record maintenance run result: %w", err)
+	}
+	return nil
+}
+
+const runColumns = `id::text, schedule_id::text, schema_name, table_name, operation, trigger, status, error,
+	dead_rows_before, dead_rows_after, started_at, finished_at, duration_ms, triggered_by::text`
+
+func scanRun(row pgx.Row) (Run, error) {
+	var r Run
+	err := row.Scan(&r.ID, &r.ScheduleID, &r.Schema, &r.Table, &r.Operation, &r.Trigger, &r.Status, &r.Error,
+		&r.DeadRowsBefore, &r.DeadRowsAfter, &r.StartedAt, &r.FinishedAt, &r.DurationMs, &r.TriggeredBy)
+	return r, err
+}
+
+// ListRuns returns the most recent runs, newest first
+func (s *Store) ListRuns(ctx context.Context, filter RunFilter) ([]Run, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT `+runColumns+`
+		FROM api.table_maintenance_runs
+		WHERE ($1 = '' OR schema_name = $1) AND ($2 = '' OR table_name = $2)
+		ORDER BY started_at DESC
+		LIMIT $3
+	`, filter.Schema, filter.Table, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []Run{}
+	for rows.Next() {
+		r, err := scanRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan maintenance run: %w", err)
+		}
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
+
+// PruneRuns deletes the finished runs that started before before
+func (s *Store) PruneRuns(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := s.db.Exec(ctx, `
+		DELETE FROM api.table_maintenance_runs WHERE status <> 'running' AND started_at < $1
+	`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune maintenance runs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+const scheduleColumns = `id::text, schema_name, table_name, operation, cron_schedule, enabled, last_run_at,
+	created_by::text, created_at, updated_at`
+
+func scanSchedule(row pgx.Row) (Schedule, error) {
+	var sc Schedule
+	err := row.Scan(&sc.ID, &sc.Schema, &sc.Table, &sc.Operation, &sc.CronSchedule, &sc.Enabled, &sc.LastRunAt,
+		&sc.CreatedBy, &sc.CreatedAt, &sc.UpdatedAt)
+	return sc, err
+}
+
+// ListSchedules returns every schedule ordered by table
+func (s *Store) ListSchedules(ctx context.Context) ([]Schedule, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT `+scheduleColumns+`
+		FROM api.table_maintenance_schedules
+		ORDER BY schema_name, table_name, operation
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []Schedule{}
+	for rows.Next() {
+		sc, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan maintenance schedule: %w", err)
+		}
+		schedules = append(schedules, sc)
+	}
+	return schedules, rows.Err()
+}
+
+// GetSchedule returns a schedule by ID
+func (s *Store) GetSchedule(ctx context.Context, id string) (*Schedule, error) {
+	sc, err := scanSchedule(s.db.QueryRow(ctx, `
+		SELECT `+scheduleColumns+` FROM api.table_maintenance_schedules WHERE id = $1
+	`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrScheduleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get maintenance schedule: %w", err)
+	}
+	return &sc, nil
+}
+
+// CreateSchedule stores a new schedule. The cron expression must already be validated.
+func (s *Store) CreateSchedule(ctx context.Context, sc Schedule, createdBy *uuid.UUID) (*Schedule, error) {
+	created, err := scanSchedule(s.db.QueryRow(ctx, `
+		INSERT INTO api.table_maintenance_schedules (schema_name, table_name, operation, cron_schedule, enabled, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+scheduleColumns,
+		sc.Schema, sc.Table, sc.Operation, sc.CronSchedule, sc.Enabled, createdBy))
+	if database.IsUniqueViolation(err) {
+		return nil, ErrScheduleExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create maintenance schedule: %w", err)
+	}
+	return &created, nil
+}
+
+// UpdateSchedule changes the cron expression and enabled flag of a schedule
+func (s *Store) UpdateSchedule(ctx context.Context, id, cronSchedule string, enabled bool) (*Schedule, error) {
+	sc, err := scanSchedule(s.db.QueryRow(ctx, `
+		UPDATE api.table_maintenance_schedules
+		SET cron_schedule = $2, enabled = $3, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+scheduleColumns,
+		id, cronSchedule, enabled))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrScheduleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update maintenance schedule: %w", err)
+	}
+	return &sc, nil
+}
+
+// DeleteSchedule deletes a schedule. Its runs are kept in the history.
+func (s *Store) DeleteSchedule(ctx context.Context, id string) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM api.table_maintenance_schedules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete maintenance schedule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrScheduleNotFound
+	}
+	return nil
+}
+
+// enabledSchedules returns the enabled schedules
+func (s *Store) enabledSchedules(ctx context.Context) ([]Schedule, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT `+scheduleColumns+`
+		FROM api.table_maintenance_schedules
+		WHERE enabled
+		ORDER BY schema_name, table_name, operation
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var schedules []Schedule
+	for rows.Next() {
+		sc, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan maintenance schedule: %w", err)
+		}
+		schedules = append(schedules, sc)
+	}
+	return schedules, rows.Err()
+}
+
+// markScheduleRun records when a schedule last fired
+func (s *Store) markScheduleRun(ctx context.Context, id string, at time.Time) error {
+	_, err := s.db.Exec(ctx, `UPDATE api.table_maintenance_schedules SET last_run_at = $2 WHERE id = $1`, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to update maintenance schedule: %w", err)
+	}
+	return nil
+}
@@ -50,6 +50,7 @@ Configuration annotations:
   @fluxbase:persist-conversations - Enable conversation persistence
   @fluxbase:conversation-ttl <hours> - Conversation TTL in hours (default: 24)
   @fluxbase:response-language <lang> - Response language (default: "auto")
+  @fluxbase:match-user-language <true|false> - Reply in the detected language of each message (default: ai.match_user_language)
   @fluxbase:disable-logs - Disable execution logging
 
 MCP Tools (use with @fluxbase:mcp-tools):
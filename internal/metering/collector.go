@@ -0,0 +1,248 @@
+package metering
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Collector reads billable usage for a period from the tables that already track it
+type Collector struct {
+	db *pgxpool.Pool
+}
+
+// NewCollector creates a new metering collector
+func NewCollector(db *pgxpool.Pool) *Collector {
+	return &Collector{db: db}
+}
+
+// Collect returns all billable events for the half-open period [start, end)
+func (c *Collector) Collect(ctx context.Context, start, end time.Time) ([]Event, error) {
+	sources := []struct {
+		name    string
+		collect func(context.Context, time.Time, time.Time) ([]Event, error)
+	}{
+		{"api requests", c.collectAPIRequests},
+		{"storage bytes", c.collectStorageBytes},
+		{"ai chat tokens", c.collectChatTokens},
+		{"ai cost deltas", c.collectChatCostDeltas},
+		{"ai embedding tokens", c.collectEmbeddingTokens},
+		{"function gb-seconds", c.collectFunctionGBSeconds},
+		{"job gb-seconds", c.collectJobGBSeconds},
+	}
+
+	events := []Event{}
+	for _, source := range sources {
+		sourceEvents, err := source.collect(ctx, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect %s: %w", source.name, err)
+		}
+		events = append(events, sourceEvents...)
+	}
+	return events, nil
+}
+
+// collectRows scans rows into events with the given scan function
+func collectRows(rows pgx.Rows, scan func(pgx.Rows) (Event, error)) ([]Event, error) {
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		event, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// collectAPIRequests sums the hourly API usage rollups per client key and user
+func (c *Collector) collectAPIRequests(ctx context.Context, start, end time.Time) ([]Event, error) {
+	rows, err := c.db.Query(ctx, `
+		SELECT COALESCE(client_key_id::text, ''), COALESCE(user_id::text, ''), SUM(request_count)::bigint
+		FROM api.usage_hourly
+		WHERE bucket >= $1 AND bucket < $2
+		GROUP BY client_key_id, user_id
+	`, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return collectRows(rows, func(rows pgx.Rows) (Event, error) {
+		var clientKeyID, userID string
+		var requests int64
+		if err := rows.Scan(&clientKeyID, &userID, &requests); err != nil {
+			return Event{}, err
+		}
+		return newEvent(EventAPIRequests, "requests", AggregationSum, float64(requests),
+			userID, clientKeyID, nil, start, end), nil
+	})
+}
+
+// collectStorageBytes snapshots stored bytes per owner and bucket.
+// Object history is not kept, so the snapshot is taken when the period is exported.
+func (c *Collector) collectStorageBytes(ctx context.Context, start, end time.Time) ([]Event, error) {
+	rows, err := c.db.Query(ctx, `
+		SELECT COALESCE(owner_id::text, ''), bucket_id, COALESCE(SUM(size), 0)::bigint
+		FROM storage.objects
+		GROUP BY owner_id, bucket_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return collectRows(rows, func(rows pgx.Rows) (Event, error) {
+		var ownerID, bucket string
+		var size int64
+		if err := rows.Scan(&ownerID, &bucket, &size); err != nil {
+			return Event{}, err
+		}
+		return newEvent(EventStorageBytes, "bytes", AggregationSnapshot, float64(size),
+			ownerID, "", map[string]string{"bucket": bucket}, start, end), nil
+	})
+}
+
+// collectChatTokens sums chatbot message tokens per user, chatbot and routed model, split into prompt
+// and completion. A row yields up to two events, so it cannot use collectRows.
+func (c *Collector) collectChatTokens(ctx context.Context, start, end time.Time) ([]Event, error) {
+	rows, err := c.db.Query(ctx, `
+		SELECT COALESCE(conv.user_id::text, ''), conv.chatbot_id::text, COALESCE(m.model, ''),
+			COALESCE(SUM(m.prompt_tokens), 0)::bigint, COALESCE(SUM(m.completion_tokens), 0)::bigint
+		FROM ai.messages m
+		JOIN ai.conversations conv ON conv.id = m.conversation_id
+		WHERE m.created_at >= $1 AND m.created_at < $2
+		GROUP BY conv.user_id, conv.chatbot_id, m.model
+	`, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		var userID, chatbotID, model string
+		var promptTokens, completionTokens int64
+		if err := rows.Scan(&userID, &chatbotID, &model, &promptTokens, &completionTokens); err != nil {
+			return nil, err
+		}
+		split := []struct {
+			tokenType string
+			tokens    int64
+		}{{"prompt", promptTokens}, {"completion", completionTokens}}
+		for _, part := range split {
+			if part.tokens == 0 {
+				continue
+			}
+			dimensions := map[string]string{"source": "chat", "chatbot_id": chatbotID, "token_type": part.tokenType}
+			if model != "" {
+				dimensions["model"] = model
+			}
+			events = append(events, newEvent(EventAITokens, "tokens", AggregationSum, float64(part.tokens), userID, "",
+				dimensions, start, end))
+		}
+	}
+	return events, rows.Err()
+}
+
+// collectChatCostDeltas sums the cost difference of routed chat messages per user, chatbot, model and
+// routing rule. Messages answered by the default model or without known prices are skipped.
+func (c *Collector) collectChatCostDeltas(ctx context.Context, start, end time.Time) ([]Event, error) {
+	rows, err := c.db.Query(ctx, `
+		SELECT COALESCE(conv.user_id::text, ''), conv.chatbot_id::text, m.model, m.routing_rule,
+			SUM(m.cost_delta_usd)::float8
+		FROM ai.messages m
+		JOIN ai.conversations conv ON conv.id = m.conversation_id
+		WHERE m.created_at >= $1 AND m.created_at < $2
+			AND m.routing_rule IS NOT NULL AND m.cost_delta_usd IS NOT NULL
+		GROUP BY conv.user_id, conv.chatbot_id, m.model, m.routing_rule
+	`, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return collectRows(rows, func(rows pgx.Rows) (Event, error) {
+		var userID, chatbotID, model, rule string
+		var delta float64
+		if err := rows.Scan(&userID, &chatbotID, &model, &rule, &delta); err != nil {
+			return Event{}, err
+		}
+		return newEvent(EventAICostDelta, "usd", AggregationSum, delta, userID, "",
+			map[string]string{"source": "chat", "chatbot_id": chatbotID, "model": model, "routing_rule": rule}, start, end), nil
+	})
+}
+
+// collectEmbeddingTokens sums embeddings API tokens per user, client key and model
+func (c *Collector) collectEmbeddingTokens(ctx context.Context, start, end time.Time) ([]Event, error) {
+	rows, err := c.db.Query(ctx, `
+		SELECT COALESCE(user_id::text, ''), COALESCE(client_key_id::text, ''), model, SUM(total_tokens)::bigint
+		FROM ai.embedding_usage
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY user_id, client_key_id, model
+	`, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return collectRows(rows, func(rows pgx.Rows) (Event, error) {
+		var userID, clientKeyID, model string
+		var tokens int64
+		if err := rows.Scan(&userID, &clientKeyID, &model, &tokens); err != nil {
+			return Event{}, err
+		}
+		return newEvent(EventAITokens, "tokens", AggregationSum, float64(tokens), userID, clientKeyID,
+			map[string]string{"source": "embeddings", "model": model}, start, end), nil
+	})
+}
+
+// collectFunctionGBSeconds sums edge function execution time weighted by the function's memory limit
+func (c *Collector) collectFunctionGBSeconds(ctx context.Context, start, end time.Time) ([]Event, error) {
+	rows, err := c.db.Query(ctx, `
+		SELECT f.id::text, f.namespace, f.name,
+			COALESCE(SUM(e.duration_ms::float8 * COALESCE(f.memory_limit_mb, 128)) / 1000.0 / 1024.0, 0)
+		FROM functions.edge_executions e
+		JOIN functions.edge_functions f ON f.id = e.function_id
+		WHERE e.completed_at >= $1 AND e.completed_at < $2 AND e.duration_ms IS NOT NULL
+		GROUP BY f.id, f.namespace, f.name
+	`, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return collectRows(rows, func(rows pgx.Rows) (Event, error) {
+		var functionID, namespace, name string
+		var gbSeconds float64
+		if err := rows.Scan(&functionID, &namespace, &name, &gbSeconds); err != nil {
+			return Event{}, err
+		}
+		return newEvent(EventFunctionGBSeconds, "gb_seconds", AggregationSum, gbSeconds, "", "",
+			map[string]string{
+				"runtime":     "function",
+				"function":    name,
+				"namespace":   namespace,
+				"function_id": functionID,
+			}, start, end), nil
+	})
+}
+
+// collectJobGBSeconds sums background job run time weighted by the job's memory limit, per submitting user
+func (c *Collector) collectJobGBSeconds(ctx context.Context, start, end time.Time) ([]Event, error) {
+	rows, err := c.db.Query(ctx, `
+		SELECT COALESCE(q.created_by::text, ''), q.namespace, q.job_name,
+			COALESCE(SUM(EXTRACT(EPOCH FROM (q.completed_at - q.started_at)) * COALESCE(jf.memory_limit_mb, 256)) / 1024.0, 0)::float8
+		FROM jobs.queue q
+		LEFT JOIN jobs.functions jf ON jf.id = q.function_id
+		WHERE q.completed_at >= $1 AND q.completed_at < $2 AND q.started_at IS NOT NULL
+		GROUP BY q.created_by, q.namespace, q.job_name
+	`, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return collectRows(rows, func(rows pgx.Rows) (Event, error) {
+		var userID, namespace, name string
+		var gbSeconds float64
+		if err := rows.Scan(&userID, &namespace, &name, &gbSeconds); err != nil {
+			return Event{}, err
+		}
+		return newEvent(EventFunctionGBSeconds, "gb_seconds", AggregationSum, gbSeconds, userID, "",
+			map[string]string{"runtime": "job", "function": name, "namespace": namespace}, start, end), nil
+	})
+}
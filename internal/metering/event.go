@@ -0,0 +1,116 @@
+// Package metering exports billable usage (API calls, storage bytes, AI tokens,
+// function GB-seconds) as JSON lines so self-hosters can feed their billing systems.
+package metering
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchemaVersion is the version of the Event JSON schema. It is bumped on breaking changes.
+const SchemaVersion = 1
+
+// EventType is the kind of billable usage an event reports
+type EventType string
+
+const (
+	// EventAPIRequests counts API requests per client key and user
+	EventAPIRequests EventType = "api_requests"
+	// EventStorageBytes is the stored object size per owner and bucket at the end of the period
+	EventStorageBytes EventType = "storage_bytes"
+	// EventAITokens counts LLM tokens per user for chat and embeddings
+	EventAITokens EventType = "ai_tokens"
+	// EventFunctionGBSeconds is execution time multiplied by the memory limit for edge functions and jobs
+	EventFunctionGBSeconds EventType = "function_gb_seconds"
+	// EventAICostDelta is the chat cost difference from routing messages away from a chatbot's default model
+	EventAICostDelta EventType = "ai_cost_delta"
+)
+
+// Aggregation describes how an event quantity relates to its period
+type Aggregation string
+
+const (
+	// AggregationSum is a total over the period
+	AggregationSum Aggregation = "sum"
+	// AggregationSnapshot is a point-in-time value at the end of the period
+	AggregationSnapshot Aggregation = "snapshot"
+)
+
+// Event is one billable usage record. Events are exported one per line (JSON lines).
+type Event struct {
+	SchemaVersion int       `json:"schema_version"`
+	ID            string    `json:"id"` // Stable across re-exports of the same period, use it to deduplicate
+	Type          EventType `json:"type"`
+	// Instance identifies the Fluxbase deployment (its public base URL)
+	Instance    string            `json:"instance,omitempty"`
+	Quantity    float64           `json:"quantity"`
+	Unit        string            `json:"unit"`
+	Aggregation Aggregation       `json:"aggregation"`
+	UserID      string            `json:"user_id,omitempty"`
+	ClientKeyID string            `json:"client_key_id,omitempty"`
+	Dimensions  map[string]string `json:"dimensions,omitempty"`
+	PeriodStart time.Time         `json:"period_start"`
+	PeriodEnd   time.Time         `json:"period_end"`
+}
+
+// newEvent creates an event for the period and derives its ID from the
+// type, period, subject and dimensions
+func newEvent(eventType EventType, unit string, aggregation Aggregation, quantity float64,
+	userID, clientKeyID string, dimensions map[string]string, start, end time.Time,
+) Event {
+	e := Event{
+		SchemaVersion: SchemaVersion,
+		Type:          eventType,
+		Quantity:      quantity,
+		Unit:          unit,
+		Aggregation:   aggregation,
+		UserID:        userID,
+		ClientKeyID:   clientKeyID,
+		Dimensions:    dimensions,
+		PeriodStart:   start.UTC(),
+		PeriodEnd:     end.UTC(),
+	}
+	e.ID = eventID(e)
+	return e
+}
+
+// eventID hashes everything that identifies an event except its quantity
+func eventID(e Event) string {
+	parts := []string{
+		string(e.Type),
+		e.PeriodStart.Format(time.RFC3339),
+		e.PeriodEnd.Format(time.RFC3339),
+		e.UserID,
+		e.ClientKeyID,
+	}
+
+	keys := make([]string, 0, len(e.Dimensions))
+	for k := range e.Dimensions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		parts = append(parts, k+"="+e.Dimensions[k])
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x1f")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// EncodeJSONLines renders events as JSON lines, stamping each with the instance name
+func EncodeJSONLines(events []Event, instance string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for i := range events {
+		events[i].Instance = instance
+		if err := enc.Encode(events[i]); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
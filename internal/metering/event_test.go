@@ -0,0 +1,90 @@
+package metering
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEvent_ID(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	t.Run("stable across quantities and dimension order", func(t *testing.T) {
+		a := newEvent(EventAITokens, "tokens", AggregationSum, 10, "user-1", "",
+			map[string]string{"source": "chat", "token_type": "prompt"}, start, end)
+		b := newEvent(EventAITokens, "tokens", AggregationSum, 99, "user-1", "",
+			map[string]string{"token_type": "prompt", "source": "chat"}, start, end)
+
+		assert.Equal(t, a.ID, b.ID)
+		assert.Len(t, a.ID, 32)
+	})
+
+	t.Run("differs by subject, dimensions and period", func(t *testing.T) {
+		base := newEvent(EventAPIRequests, "requests", AggregationSum, 1, "user-1", "key-1", nil, start, end)
+
+		ids := map[string]bool{base.ID: true}
+		for _, e := range []Event{
+			newEvent(EventAPIRequests, "requests", AggregationSum, 1, "user-2", "key-1", nil, start, end),
+			newEvent(EventAPIRequests, "requests", AggregationSum, 1, "user-1", "key-2", nil, start, end),
+			newEvent(EventAPIRequests, "requests", AggregationSum, 1, "user-1", "key-1", map[string]string{"x": "y"}, start, end),
+			newEvent(EventAPIRequests, "requests", AggregationSum, 1, "user-1", "key-1", nil, end, end.Add(time.Hour)),
+			newEvent(EventStorageBytes, "bytes", AggregationSnapshot, 1, "user-1", "key-1", nil, start, end),
+		} {
+			assert.False(t, ids[e.ID], "duplicate id for %+v", e)
+			ids[e.ID] = true
+		}
+	})
+
+	t.Run("normalizes period to UTC", func(t *testing.T) {
+		local := start.In(time.FixedZone("CET", 3600))
+		e := newEvent(EventAPIRequests, "requests", AggregationSum, 1, "", "", nil, local, local.Add(time.Hour))
+
+		assert.Equal(t, time.UTC, e.PeriodStart.Location())
+		assert.Equal(t, newEvent(EventAPIRequests, "requests", AggregationSum, 1, "", "", nil, start, end).ID, e.ID)
+	})
+}
+
+func TestEncodeJSONLines(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	events := []Event{
+		newEvent(EventAPIRequests, "requests", AggregationSum, 42, "user-1", "", nil, start, start.Add(time.Hour)),
+		newEvent(EventFunctionGBSeconds, "gb_seconds", AggregationSum, 1.5, "", "",
+			map[string]string{"runtime": "function", "function": "hello"}, start, start.Add(time.Hour)),
+	}
+
+	data, err := EncodeJSONLines(events, "https://api.example.com")
+	require.NoError(t, err)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	var lines []map[string]interface{}
+	for scanner.Scan() {
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.Len(t, lines, 2)
+
+	assert.Equal(t, float64(SchemaVersion), lines[0]["schema_version"])
+	assert.Equal(t, "api_requests", lines[0]["type"])
+	assert.Equal(t, "https://api.example.com", lines[0]["instance"])
+	assert.Equal(t, float64(42), lines[0]["quantity"])
+	assert.Equal(t, "user-1", lines[0]["user_id"])
+	assert.NotContains(t, lines[0], "client_key_id")
+	assert.NotContains(t, lines[0], "dimensions")
+	assert.Equal(t, "2026-03-01T10:00:00Z", lines[0]["period_start"])
+
+	assert.Equal(t, "function_gb_seconds", lines[1]["type"])
+	assert.Equal(t, map[string]interface{}{"runtime": "function", "function": "hello"}, lines[1]["dimensions"])
+}
+
+func TestEncodeJSONLines_Empty(t *testing.T) {
+	data, err := EncodeJSONLines(nil, "instance")
+	require.NoError(t, err)
+	assert.Empty(t, data)
+}
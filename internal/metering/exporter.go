@@ -0,0 +1,351 @@
+package metering
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/storage"
+	"github.com/nimbleflux/fluxbase/internal/webhook"
+	"github.com/rs/zerolog/log"
+)
+
+// settleDelay is how long after a period ends before it is exported, so that
+// usage buffered in memory (e.g. API usage rollups) has been flushed
+const settleDelay = 5 * time.Minute
+
+// checkInterval is how often the exporter looks for periods ready to export
+const checkInterval = time.Minute
+
+// ExportRecord is one row of api.metering_exports
+type ExportRecord struct {
+	ID           string    `json:"id"`
+	PeriodStart  time.Time `json:"period_start"`
+	PeriodEnd    time.Time `json:"period_end"`
+	Status       string    `json:"status"`
+	EventCount   int       `json:"event_count"`
+	Destinations []string  `json:"destinations"`
+	ObjectKey    *string   `json:"object_key,omitempty"`
+	ErrorMessage *string   `json:"error_message,omitempty"`
+	Attempts     int       `json:"attempts"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Exporter periodically exports billable events for each completed period
+// to a storage bucket and/or a webhook. It must run on a single node (leader-elected).
+type Exporter struct {
+	cfg        *config.MeteringConfig
+	db         *pgxpool.Pool
+	collector  *Collector
+	storage    storage.Provider
+	instance   string
+	httpClient *http.Client
+	now        func() time.Time
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running bool
+	mu      sync.Mutex
+}
+
+// NewExporter creates a metering exporter. instance identifies this deployment in exported events.
+func NewExporter(cfg *config.MeteringConfig, db *pgxpool.Pool, storageProvider storage.Provider, instance string) *Exporter {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Exporter{
+		cfg:        cfg,
+		db:         db,
+		collector:  NewCollector(db),
+		storage:    storageProvider,
+		instance:   instance,
+		httpClient: &http.Client{Timeout: cfg.WebhookTimeout},
+		now:        time.Now,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Collector returns the collector used for exports
+func (e *Exporter) Collector() *Collector {
+	return e.collector
+}
+
+// Start begins periodic exports
+func (e *Exporter) Start() {
+	e.mu.Lock()
+	if e.running {
+		e.mu.Unlock()
+		return
+	}
+	e.running = true
+	if e.ctx.Err() != nil {
+		e.ctx, e.cancel = context.WithCancel(context.Background())
+	}
+	e.mu.Unlock()
+
+	e.wg.Add(1)
+	go e.run()
+
+	log.Info().
+		Dur("interval", e.cfg.Interval).
+		Str("bucket", e.cfg.Bucket).
+		Bool("webhook", e.cfg.WebhookURL != "").
+		Msg("Metering exporter started")
+}
+
+// Stop stops periodic exports
+func (e *Exporter) Stop() {
+	e.mu.Lock()
+	if !e.running {
+		e.mu.Unlock()
+		return
+	}
+	e.running = false
+	e.mu.Unlock()
+
+	e.cancel()
+	e.wg.Wait()
+
+	log.Info().Msg("Metering exporter stopped")
+}
+
+// run exports pending periods on start and then every checkInterval
+func (e *Exporter) run() {
+	defer e.wg.Done()
+
+	e.exportPending(e.ctx)
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+			e.exportPending(e.ctx)
+		}
+	}
+}
+
+// exportPending exports every completed period after the cursor, oldest first.
+// It stops at the first failure so periods are always exported in order.
+func (e *Exporter) exportPending(ctx context.Context) {
+	cursor, err := e.lastExportedPeriodEnd(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read metering export cursor")
+		return
+	}
+
+	for _, period := range pendingPeriods(cursor, e.now(), e.cfg.Interval, e.cfg.MaxCatchUp) {
+		if _, err := e.ExportPeriod(ctx, period[0], period[1]); err != nil {
+			log.Error().Err(err).
+				Time("period_start", period[0]).
+				Time("period_end", period[1]).
+				Msg("Metering export failed, will retry")
+			return
+		}
+	}
+}
+
+// pendingPeriods returns the completed, settled periods after cursor (at most maxCatchUp, the most recent ones).
+// Without a cursor only the latest completed period is returned, so enabling metering does not backfill history.
+func pendingPeriods(cursor *time.Time, now time.Time, interval time.Duration, maxCatchUp int) [][2]time.Time {
+	// The latest period that ended at least settleDelay ago
+	lastEnd := now.Add(-settleDelay).UTC().Truncate(interval)
+
+	first := lastEnd.Add(-interval)
+	if cursor != nil {
+		first = cursor.UTC()
+	}
+	if maxCatchUp > 0 {
+		if earliest := lastEnd.Add(-time.Duration(maxCatchUp) * interval); first.Before(earliest) {
+			first = earliest
+		}
+	}
+
+	var periods [][2]time.Time
+	for start := first; !start.Add(interval).After(lastEnd); start = start.Add(interval) {
+		periods = append(periods, [2]time.Time{start, start.Add(interval)})
+	}
+	return periods
+}
+
+// lastExportedPeriodEnd returns the end of the latest completed export, or nil before the first export
+func (e *Exporter) lastExportedPeriodEnd(ctx context.Context) (*time.Time, error) {
+	var end *time.Time
+	err := e.db.QueryRow(ctx, `SELECT MAX(period_end) FROM api.metering_exports WHERE status = 'completed'`).Scan(&end)
+	return end, err
+}
+
+// ExportPeriod collects and delivers the events for one period and records the outcome.
+// Re-exporting a period produces events with the same IDs, so receivers can deduplicate.
+func (e *Exporter) ExportPeriod(ctx context.Context, start, end time.Time) (*ExportRecord, error) {
+	events, err := e.collector.Collect(ctx, start, end)
+	if err != nil {
+		return nil, e.recordFailure(ctx, start, end, err)
+	}
+
+	data, err := EncodeJSONLines(events, e.instance)
+	if err != nil {
+		return nil, e.recordFailure(ctx, start, end, err)
+	}
+
+	var destinations []string
+	var objectKey *string
+	if e.cfg.Bucket != "" {
+		key, err := e.writeToBucket(ctx, start, data)
+		if err != nil {
+			return nil, e.recordFailure(ctx, start, end, fmt.Errorf("bucket export failed: %w", err))
+		}
+		destinations = append(destinations, "bucket")
+		objectKey = &key
+	}
+	if e.cfg.WebhookURL != "" {
+		if err := e.sendWebhook(ctx, start, end, data); err != nil {
+			return nil, e.recordFailure(ctx, start, end, fmt.Errorf("webhook export failed: %w", err))
+		}
+		destinations = append(destinations, "webhook")
+	}
+
+	record := &ExportRecord{}
+	err = e.db.QueryRow(ctx, `
+		INSERT INTO api.metering_exports (period_start, period_end, status, event_count, destinations, object_key)
+		VALUES ($1, $2, 'completed', $3, $4, $5)
+		ON CONFLICT (period_start, period_end) DO UPDATE SET
+			status = 'completed',
+			event_count = EXCLUDED.event_count,
+			destinations = EXCLUDED.destinations,
+			object_key = EXCLUDED.object_key,
+			error_message = NULL,
+			attempts = api.metering_exports.attempts + 1,
+			updated_at = NOW()
+		RETURNING id::text, period_start, period_end, status, event_count, destinations, object_key,
+			error_message, attempts, created_at, updated_at
+	`, start, end, len(events), destinations, objectKey).Scan(
+		&record.ID, &record.PeriodStart, &record.PeriodEnd, &record.Status, &record.EventCount,
+		&record.Destinations, &record.ObjectKey, &record.ErrorMessage, &record.Attempts,
+		&record.CreatedAt, &record.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record metering export: %w", err)
+	}
+
+	log.Info().
+		Time("period_start", start).
+		Time("period_end", end).
+		Int("events", len(events)).
+		Strs("destinations", destinations).
+		Msg("Metering period exported")
+
+	return record, nil
+}
+
+// recordFailure stores a failed attempt and returns the original error
+func (e *Exporter) recordFailure(ctx context.Context, start, end time.Time, cause error) error {
+	_, err := e.db.Exec(ctx, `
+		INSERT INTO api.metering_exports (period_start, period_end, status, error_message)
+		VALUES ($1, $2, 'failed', $3)
+		ON CONFLICT (period_start, period_end) DO UPDATE SET
+			status = CASE WHEN api.metering_exports.status = 'completed' THEN 'completed' ELSE 'failed' END,
+			error_message = EXCLUDED.error_message,
+			attempts = api.metering_exports.attempts + 1,
+			updated_at = NOW()
+	`, start, end, cause.Error())
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to record metering export failure")
+	}
+	return cause
+}
+
+// objectKey returns the bucket key of a period's export, e.g. metering/2026/03/01/20260301T100000Z.jsonl
+func objectKey(prefix string, start time.Time) string {
+	start = start.UTC()
+	return fmt.Sprintf("%s/%s/%s.jsonl", strings.Trim(prefix, "/"), start.Format("2006/01/02"), start.Format("20060102T150405Z"))
+}
+
+// writeToBucket uploads the JSON lines file of a period, creating the bucket if needed
+func (e *Exporter) writeToBucket(ctx context.Context, start time.Time, data []byte) (string, error) {
+	if e.storage == nil {
+		return "", errors.New("storage provider not available")
+	}
+
+	exists, err := e.storage.BucketExists(ctx, e.cfg.Bucket)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		if err := e.storage.CreateBucket(ctx, e.cfg.Bucket); err != nil {
+			return "", err
+		}
+	}
+
+	key := objectKey(e.cfg.PathPrefix, start)
+	_, err = e.storage.Upload(ctx, e.cfg.Bucket, key, bytes.NewReader(data), int64(len(data)), &storage.UploadOptions{
+		ContentType: "application/x-ndjson",
+	})
+	return key, err
+}
+
+// sendWebhook POSTs the JSON lines of a period, signed like regular Fluxbase webhooks
+func (e *Exporter) sendWebhook(ctx context.Context, start, end time.Time, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.cfg.WebhookURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("User-Agent", "Fluxbase-Metering/1.0")
+	req.Header.Set("X-Fluxbase-Metering-Schema-Version", fmt.Sprintf("%d", SchemaVersion))
+	req.Header.Set("X-Fluxbase-Metering-Period-Start", start.UTC().Format(time.RFC3339))
+	req.Header.Set("X-Fluxbase-Metering-Period-End", end.UTC().Format(time.RFC3339))
+	// Retries of the same period carry the same key
+	req.Header.Set("Idempotency-Key", "metering-"+start.UTC().Format("20060102T150405Z")+"-"+end.UTC().Format("20060102T150405Z"))
+	if e.cfg.WebhookSecret != "" {
+		req.Header.Set("X-Fluxbase-Signature", webhook.SignPayload(data, e.cfg.WebhookSecret, e.now().Unix()))
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// ListExports returns the most recent exports, newest first
+func (e *Exporter) ListExports(ctx context.Context, limit int) ([]ExportRecord, error) {
+	rows, err := e.db.Query(ctx, `
+		SELECT id::text, period_start, period_end, status, event_count, destinations, object_key,
+			error_message, attempts, created_at, updated_at
+		FROM api.metering_exports
+		ORDER BY period_start DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (ExportRecord, error) {
+		var r ExportRecord
+		err := row.Scan(&r.ID, &r.PeriodStart, &r.PeriodEnd, &r.Status, &r.EventCount, &r.Destinations,
+			&r.ObjectKey, &r.ErrorMessage, &r.Attempts, &r.CreatedAt, &r.UpdatedAt)
+		return r, err
+	})
+}
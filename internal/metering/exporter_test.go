@@ -0,0 +1,151 @@
+package metering
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/storage"
+	"github.com/nimbleflux/fluxbase/internal/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingPeriods(t *testing.T) {
+	hour := time.Hour
+	ts := func(h, m int) time.Time { return time.Date(2026, 3, 1, h, m, 0, 0, time.UTC) }
+
+	t.Run("first run exports only the latest completed period", func(t *testing.T) {
+		periods := pendingPeriods(nil, ts(12, 30), hour, 24)
+		require.Len(t, periods, 1)
+		assert.Equal(t, [2]time.Time{ts(11, 0), ts(12, 0)}, periods[0])
+	})
+
+	t.Run("waits for the settle delay", func(t *testing.T) {
+		cursor := ts(11, 0)
+		assert.Empty(t, pendingPeriods(&cursor, ts(12, 2), hour, 24))
+
+		periods := pendingPeriods(&cursor, ts(12, 5), hour, 24)
+		require.Len(t, periods, 1)
+		assert.Equal(t, [2]time.Time{ts(11, 0), ts(12, 0)}, periods[0])
+	})
+
+	t.Run("catches up missed periods in order", func(t *testing.T) {
+		cursor := ts(8, 0)
+		periods := pendingPeriods(&cursor, ts(12, 30), hour, 24)
+		require.Len(t, periods, 4)
+		assert.Equal(t, ts(8, 0), periods[0][0])
+		assert.Equal(t, ts(12, 0), periods[3][1])
+	})
+
+	t.Run("caps catch-up to the most recent periods", func(t *testing.T) {
+		cursor := ts(0, 0)
+		periods := pendingPeriods(&cursor, ts(12, 30), hour, 3)
+		require.Len(t, periods, 3)
+		assert.Equal(t, ts(9, 0), periods[0][0])
+		assert.Equal(t, ts(12, 0), periods[2][1])
+	})
+
+	t.Run("nothing pending when up to date", func(t *testing.T) {
+		cursor := ts(12, 0)
+		assert.Empty(t, pendingPeriods(&cursor, ts(12, 59), hour, 24))
+	})
+
+	t.Run("daily interval", func(t *testing.T) {
+		periods := pendingPeriods(nil, time.Date(2026, 3, 2, 0, 10, 0, 0, time.UTC), 24*hour, 24)
+		require.Len(t, periods, 1)
+		assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), periods[0][0])
+	})
+}
+
+func TestObjectKey(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, "metering/2026/03/01/20260301T100000Z.jsonl", objectKey("metering", start))
+	assert.Equal(t, "billing/usage/2026/03/01/20260301T100000Z.jsonl", objectKey("/billing/usage/", start))
+}
+
+func TestExporter_SendWebhook(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	data := []byte("{\"type\":\"api_requests\"}\n")
+
+	t.Run("signed delivery", func(t *testing.T) {
+		var received *http.Request
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		e := NewExporter(&config.MeteringConfig{WebhookURL: server.URL, WebhookSecret: "secret", WebhookTimeout: 5 * time.Second}, nil, nil, "")
+		require.NoError(t, e.sendWebhook(context.Background(), start, end, data))
+
+		assert.Equal(t, data, body)
+		assert.Equal(t, "application/x-ndjson", received.Header.Get("Content-Type"))
+		assert.Equal(t, "2026-03-01T10:00:00Z", received.Header.Get("X-Fluxbase-Metering-Period-Start"))
+		assert.Equal(t, "2026-03-01T11:00:00Z", received.Header.Get("X-Fluxbase-Metering-Period-End"))
+		assert.Equal(t, "metering-20260301T100000Z-20260301T110000Z", received.Header.Get("Idempotency-Key"))
+		assert.NoError(t, webhook.VerifyWebhookSignature(body, received.Header.Get("X-Fluxbase-Signature"), "secret", 5*time.Minute))
+	})
+
+	t.Run("unsigned without secret", func(t *testing.T) {
+		var signature string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			signature = r.Header.Get("X-Fluxbase-Signature")
+		}))
+		defer server.Close()
+
+		e := NewExporter(&config.MeteringConfig{WebhookURL: server.URL, WebhookTimeout: 5 * time.Second}, nil, nil, "")
+		require.NoError(t, e.sendWebhook(context.Background(), start, end, data))
+		assert.Empty(t, signature)
+	})
+
+	t.Run("non-2xx is an error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "billing down", http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		e := NewExporter(&config.MeteringConfig{WebhookURL: server.URL, WebhookTimeout: 5 * time.Second}, nil, nil, "")
+		err := e.sendWebhook(context.Background(), start, end, data)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "HTTP 503")
+		assert.Contains(t, err.Error(), "billing down")
+	})
+}
+
+func TestExporter_WriteToBucket(t *testing.T) {
+	provider, err := storage.NewLocalStorage(t.TempDir(), "", "secret")
+	require.NoError(t, err)
+
+	e := NewExporter(&config.MeteringConfig{Bucket: "billing", PathPrefix: "metering", WebhookTimeout: time.Second}, nil, provider, "")
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	data := []byte("{\"type\":\"storage_bytes\"}\n")
+
+	key, err := e.writeToBucket(context.Background(), start, data)
+	require.NoError(t, err)
+	assert.Equal(t, "metering/2026/03/01/20260301T100000Z.jsonl", key)
+
+	reader, _, err := provider.Download(context.Background(), "billing", key, nil)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+	stored, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, data, stored)
+
+	// Re-exporting the same period overwrites the object in the existing bucket
+	_, err = e.writeToBucket(context.Background(), start, data)
+	require.NoError(t, err)
+}
+
+func TestExporter_WriteToBucketWithoutStorage(t *testing.T) {
+	e := NewExporter(&config.MeteringConfig{Bucket: "billing", WebhookTimeout: time.Second}, nil, nil, "")
+	_, err := e.writeToBucket(context.Background(), time.Now(), nil)
+	assert.Error(t, err)
+}
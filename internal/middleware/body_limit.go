@@ -8,6 +8,7 @@ import (
 	"sync"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
 	"github.com/rs/zerolog/log"
 )
 
@@ -100,6 +101,8 @@ func DefaultBodyLimitPatterns() []BodyLimitPattern {
 
 		// Bulk operations - larger limits
 		{Pattern: "/api/v1/rest/*/bulk", Limit: LargePayloadLimit, Description: "bulk operations"},
+		{Pattern: "/api/v1/tables/*/bulk", Limit: LargePayloadLimit, Description: "bulk operations"},
+		{Pattern: "/api/v1/tables/*/*/bulk", Limit: LargePayloadLimit, Description: "bulk operations"},
 		{Pattern: "/api/v1/rpc/**", Limit: LargePayloadLimit, Description: "RPC"},
 
 		// REST endpoints - standard limit
@@ -181,6 +184,8 @@ func BodyLimitsFromConfig(defaultLimit, restLimit, authLimit, storageLimit, bulk
 
 		// Bulk operations - larger limits
 		{Pattern: "/api/v1/rest/*/bulk", Limit: bulkLimit, Description: "bulk operations"},
+		{Pattern: "/api/v1/tables/*/bulk", Limit: bulkLimit, Description: "bulk operations"},
+		{Pattern: "/api/v1/tables/*/*/bulk", Limit: bulkLimit, Description: "bulk operations"},
 		{Pattern: "/api/v1/rpc/**", Limit: bulkLimit, Description: "RPC"},
 
 		// REST endpoints - standard limit
@@ -375,12 +380,13 @@ func (l *PatternBodyLimiter) sendLimitExceeded(c fiber.Ctx, limit int64, descrip
 		return l.config.ErrorHandler(c, limit)
 	}
 
-	return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
-		"error":   "Payload Too Large",
-		"code":    "PAYLOAD_TOO_LARGE",
-		"message": fmt.Sprintf("Request body exceeds maximum size of %s for %s endpoints", formatBytes(limit), description),
-		"hint":    "Reduce size of your request body or use chunked upload for large files",
-		"details": fiber.Map{
+	return apierror.SendError(c, &apierror.Error{
+		Status:  fiber.StatusRequestEntityTooLarge,
+		Code:    "PAYLOAD_TOO_LARGE",
+		Message: "Payload Too Large",
+		Detail:  fmt.Sprintf("Request body exceeds maximum size of %s for %s endpoints", formatBytes(limit), description),
+		Hint:    "Reduce size of your request body or use chunked upload for large files",
+		Details: fiber.Map{
 			"max_bytes":     limit,
 			"endpoint_type": description,
 		},
@@ -437,11 +443,12 @@ func (l *JSONDepthLimiter) CheckDepth(c fiber.Ctx) error {
 			Str("path", c.Path()).
 			Msg("JSON depth validation failed")
 
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "Invalid JSON",
-			"code":    "JSON_TOO_DEEP",
-			"message": fmt.Sprintf("JSON nesting exceeds maximum depth of %d", l.maxDepth),
-			"hint":    "Flatten your JSON structure or reduce nesting levels",
+		return apierror.SendError(c, &apierror.Error{
+			Status:  fiber.StatusBadRequest,
+			Code:    "JSON_TOO_DEEP",
+			Message: "Invalid JSON",
+			Detail:  fmt.Sprintf("JSON nesting exceeds maximum depth of %d", l.maxDepth),
+			Hint:    "Flatten your JSON structure or reduce nesting levels",
 		})
 	}
 
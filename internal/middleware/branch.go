@@ -6,6 +6,7 @@ import (
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
 	"github.com/nimbleflux/fluxbase/internal/branching"
 	"github.com/rs/zerolog/log"
 )
@@ -74,17 +75,21 @@ func BranchContext(config BranchContextConfig) fiber.Handler {
 		if !branching.IsMainBranch(branchSlug) {
 			// Check if branching is enabled
 			if config.Router == nil {
-				return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-					"error":   "branching_disabled",
-					"message": "Database branching is not enabled",
+				return apierror.SendError(c, &apierror.Error{
+					Status:  fiber.StatusServiceUnavailable,
+					Code:    "BRANCHING_DISABLED",
+					Message: "branching_disabled",
+					Detail:  "Database branching is not enabled",
 				})
 			}
 
 			// Check authentication for non-main branches
 			if config.RequireAccess && userID == nil && !config.AllowAnonymous {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-					"error":   "authentication_required",
-					"message": "Authentication is required to access branches",
+				return apierror.SendError(c, &apierror.Error{
+					Status:  fiber.StatusUnauthorized,
+					Code:    apierror.CodeAuthRequired,
+					Message: "authentication_required",
+					Detail:  "Authentication is required to access branches",
 				})
 			}
 
@@ -96,16 +101,20 @@ func BranchContext(config BranchContextConfig) fiber.Handler {
 						Str("branch", branchSlug).
 						Str("user_id", userID.String()).
 						Msg("Failed to check branch access")
-					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-						"error":   "access_check_failed",
-						"message": "Failed to verify branch access",
+					return apierror.SendError(c, &apierror.Error{
+						Status:  fiber.StatusInternalServerError,
+						Code:    apierror.CodeOperationFailed,
+						Message: "access_check_failed",
+						Detail:  "Failed to verify branch access",
 					})
 				}
 
 				if !hasAccess {
-					return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-						"error":   "access_denied",
-						"message": "You do not have access to this branch",
+					return apierror.SendError(c, &apierror.Error{
+						Status:  fiber.StatusForbidden,
+						Code:    apierror.CodeAccessDenied,
+						Message: "access_denied",
+						Detail:  "You do not have access to this branch",
 					})
 				}
 			}
@@ -119,15 +128,19 @@ func BranchContext(config BranchContextConfig) fiber.Handler {
 			pool, err = config.Router.GetPool(c.RequestCtx(), branchSlug)
 			if err != nil {
 				if errors.Is(err, branching.ErrBranchNotFound) {
-					return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-						"error":   "branch_not_found",
-						"message": "Branch not found: " + branchSlug,
+					return apierror.SendError(c, &apierror.Error{
+						Status:  fiber.StatusNotFound,
+						Code:    "BRANCH_NOT_FOUND",
+						Message: "branch_not_found",
+						Detail:  "Branch not found: " + branchSlug,
 					})
 				}
 				if errors.Is(err, branching.ErrBranchNotReady) {
-					return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-						"error":   "branch_not_ready",
-						"message": "Branch is not ready: " + branchSlug,
+					return apierror.SendError(c, &apierror.Error{
+						Status:  fiber.StatusServiceUnavailable,
+						Code:    "BRANCH_NOT_READY",
+						Message: "branch_not_ready",
+						Detail:  "Branch is not ready: " + branchSlug,
 					})
 				}
 				if errors.Is(err, branching.ErrBranchingDisabled) {
@@ -135,18 +148,22 @@ func BranchContext(config BranchContextConfig) fiber.Handler {
 					if branching.IsMainBranch(branchSlug) {
 						pool = config.Router.GetMainPool()
 					} else {
-						return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-							"error":   "branching_disabled",
-							"message": "Database branching is not enabled",
+						return apierror.SendError(c, &apierror.Error{
+							Status:  fiber.StatusServiceUnavailable,
+							Code:    "BRANCHING_DISABLED",
+							Message: "branching_disabled",
+							Detail:  "Database branching is not enabled",
 						})
 					}
 				} else {
 					log.Error().Err(err).
 						Str("branch", branchSlug).
 						Msg("Failed to get branch pool")
-					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-						"error":   "pool_error",
-						"message": "Failed to get database connection for branch",
+					return apierror.SendError(c, &apierror.Error{
+						Status:  fiber.StatusInternalServerError,
+						Code:    "POOL_ERROR",
+						Message: "pool_error",
+						Detail:  "Failed to get database connection for branch",
 					})
 				}
 			}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/config"
+)
+
+// locationHeaders are the city, region and country geolocation headers set by CDNs
+var locationHeaders = [][3]string{
+	{"CF-IPCity", "CF-Region", "CF-IPCountry"},                                                  // Cloudflare
+	{"X-Vercel-IP-City", "X-Vercel-IP-Country-Region", "X-Vercel-IP-Country"},                   // Vercel
+	{"CloudFront-Viewer-City", "CloudFront-Viewer-Country-Region", "CloudFront-Viewer-Country"}, // Amazon CloudFront
+}
+
+// GetApproximateLocation returns the approximate location of the client as "City, Region, Country"
+// from the geolocation headers of a CDN in front of Fluxbase, or "" when it is unknown.
+// Like GetTrustedClientIP, the headers are only trusted when the request comes from a
+// configured trusted proxy.
+func GetApproximateLocation(c fiber.Ctx, cfg *config.ServerConfig) string {
+	if len(cfg.TrustedProxies) == 0 || !isTrustedProxy(getDirectIP(c), cfg.TrustedProxies) {
+		return ""
+	}
+	return locationFromHeaders(func(name string) string { return c.Get(name) })
+}
+
+// locationFromHeaders formats the location from the first CDN whose country header is set
+func locationFromHeaders(get func(name string) string) string {
+	for _, headers := range locationHeaders {
+		country := strings.TrimSpace(get(headers[2]))
+		// Cloudflare uses XX for unknown countries and T1 for Tor exit nodes
+		if country == "" || country == "XX" || country == "T1" {
+			continue
+		}
+
+		var parts []string
+		for _, name := range headers[:2] {
+			value := strings.TrimSpace(get(name))
+			// Vercel URL-encodes the city name
+			if decoded, err := url.QueryUnescape(value); err == nil {
+				value = decoded
+			}
+			if value != "" {
+				parts = append(parts, value)
+			}
+		}
+		return strings.Join(append(parts, country), ", ")
+	}
+	return ""
+}
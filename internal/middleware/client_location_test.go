@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocationFromHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"no headers", nil, ""},
+		{"cloudflare", map[string]string{"CF-IPCity": "Berlin", "CF-Region": "Berlin", "CF-IPCountry": "DE"}, "Berlin, Berlin, DE"},
+		{"country only", map[string]string{"CF-IPCountry": "FR"}, "FR"},
+		{"unknown country", map[string]string{"CF-IPCity": "Nowhere", "CF-IPCountry": "XX"}, ""},
+		{"tor", map[string]string{"CF-IPCountry": "T1"}, ""},
+		{"vercel encodes the city", map[string]string{"X-Vercel-IP-City": "S%C3%A3o%20Paulo", "X-Vercel-IP-Country-Region": "SP", "X-Vercel-IP-Country": "BR"}, "São Paulo, SP, BR"},
+		{"cloudfront", map[string]string{"CloudFront-Viewer-City": "Seattle", "CloudFront-Viewer-Country": "US"}, "Seattle, US"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, locationFromHeaders(func(name string) string { return tt.headers[name] }))
+		})
+	}
+}
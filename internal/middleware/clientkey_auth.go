@@ -3,11 +3,13 @@ package middleware
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
 	"github.com/nimbleflux/fluxbase/internal/auth"
 	"github.com/rs/zerolog/log"
 	"golang.org/x/crypto/bcrypt"
@@ -22,9 +24,7 @@ func ClientKeyAuth(clientKeyService *auth.ClientKeyService) fiber.Handler {
 
 		// If no client key provided, return unauthorized
 		if clientKey == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Missing client key. Provide via X-Client-Key header",
-			})
+			return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeMissingAuth, "Missing client key. Provide via X-Client-Key header")
 		}
 
 		// Validate the client key
@@ -34,24 +34,16 @@ func ClientKeyAuth(clientKeyService *auth.ClientKeyService) fiber.Handler {
 
 			// Return specific error messages using errors.Is for proper error wrapping
 			if errors.Is(err, auth.ErrClientKeyRevoked) {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-					"error": "Client key has been revoked",
-				})
+				return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeRevokedToken, "Client key has been revoked")
 			}
 			if errors.Is(err, auth.ErrClientKeyExpired) {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-					"error": "Client key has expired",
-				})
+				return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeExpiredToken, "Client key has expired")
 			}
 			if errors.Is(err, auth.ErrUserClientKeysDisabled) {
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-					"error": "User client keys are disabled. Contact an administrator.",
-				})
+				return apierror.Send(c, fiber.StatusForbidden, apierror.CodeFeatureDisabled, "User client keys are disabled. Contact an administrator.")
 			}
 
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid client key",
-			})
+			return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeInvalidToken, "Invalid client key")
 		}
 
 		// Store client key information in context
@@ -63,6 +55,10 @@ func ClientKeyAuth(clientKeyService *auth.ClientKeyService) fiber.Handler {
 		if validatedKey.AllowedNamespaces != nil {
 			c.Locals("allowed_namespaces", validatedKey.AllowedNamespaces)
 		}
+		c.Locals("json_key_case", validatedKey.JSONKeyCase)
+		if err := applyClientKeyRestrictions(c, validatedKey); err != nil {
+			return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Client key cannot be used from this IP address")
+		}
 
 		// If client key is associated with a user, store user ID
 		if validatedKey.UserID != nil {
@@ -91,15 +87,19 @@ func OptionalClientKeyAuth(authService *auth.Service, clientKeyService *auth.Cli
 				isRevoked, err := authService.IsTokenRevoked(c.RequestCtx(), claims.ID)
 				if err != nil {
 					log.Error().Err(err).Str("jti", claims.ID).Msg("Token revocation check failed")
-					return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-						"error":   "service_unavailable",
-						"message": "Unable to verify token status",
+					return apierror.SendError(c, &apierror.Error{
+						Status:  fiber.StatusServiceUnavailable,
+						Code:    apierror.CodeServiceUnavailable,
+						Message: "service_unavailable",
+						Detail:  "Unable to verify token status",
 					})
 				}
 				if isRevoked {
-					return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-						"error":   "token_revoked",
-						"message": "Token has been revoked",
+					return apierror.SendError(c, &apierror.Error{
+						Status:  fiber.StatusUnauthorized,
+						Code:    apierror.CodeRevokedToken,
+						Message: "token_revoked",
+						Detail:  "Token has been revoked",
 					})
 				}
 
@@ -130,6 +130,10 @@ func OptionalClientKeyAuth(authService *auth.Service, clientKeyService *auth.Cli
 				if validatedKey.AllowedNamespaces != nil {
 					c.Locals("allowed_namespaces", validatedKey.AllowedNamespaces)
 				}
+				c.Locals("json_key_case", validatedKey.JSONKeyCase)
+				if err := applyClientKeyRestrictions(c, validatedKey); err != nil {
+					return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Client key cannot be used from this IP address")
+				}
 
 				if validatedKey.UserID != nil {
 					c.Locals("user_id", *validatedKey.UserID)
@@ -161,15 +165,19 @@ func RequireEitherAuth(authService *auth.Service, clientKeyService *auth.ClientK
 				isRevoked, err := authService.IsTokenRevoked(c.RequestCtx(), claims.ID)
 				if err != nil {
 					log.Error().Err(err).Str("jti", claims.ID).Msg("Token revocation check failed")
-					return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-						"error":   "service_unavailable",
-						"message": "Unable to verify token status",
+					return apierror.SendError(c, &apierror.Error{
+						Status:  fiber.StatusServiceUnavailable,
+						Code:    apierror.CodeServiceUnavailable,
+						Message: "service_unavailable",
+						Detail:  "Unable to verify token status",
 					})
 				}
 				if isRevoked {
-					return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-						"error":   "token_revoked",
-						"message": "Token has been revoked",
+					return apierror.SendError(c, &apierror.Error{
+						Status:  fiber.StatusUnauthorized,
+						Code:    apierror.CodeRevokedToken,
+						Message: "token_revoked",
+						Detail:  "Token has been revoked",
 					})
 				}
 
@@ -198,6 +206,10 @@ func RequireEitherAuth(authService *auth.Service, clientKeyService *auth.ClientK
 				if validatedKey.AllowedNamespaces != nil {
 					c.Locals("allowed_namespaces", validatedKey.AllowedNamespaces)
 				}
+				c.Locals("json_key_case", validatedKey.JSONKeyCase)
+				if err := applyClientKeyRestrictions(c, validatedKey); err != nil {
+					return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Client key cannot be used from this IP address")
+				}
 
 				c.Locals("auth_type", "clientkey")
 
@@ -210,17 +222,24 @@ func RequireEitherAuth(authService *auth.Service, clientKeyService *auth.ClientK
 
 			// Return specific error for disabled user keys
 			if errors.Is(err, auth.ErrUserClientKeysDisabled) {
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-					"error": "User client keys are disabled. Contact an administrator.",
-				})
+				return apierror.Send(c, fiber.StatusForbidden, apierror.CodeFeatureDisabled, "User client keys are disabled. Contact an administrator.")
 			}
 		}
 
 		// No valid authentication provided
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required. Provide either a Bearer token or X-Client-Key header",
-		})
+		return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeAuthRequired, "Authentication required. Provide either a Bearer token or X-Client-Key header")
+	}
+}
+
+// applyClientKeyRestrictions rejects a client key used from outside its IP allowlist and
+// stores its table and bucket restrictions for the handlers and RequireScope
+func applyClientKeyRestrictions(c fiber.Ctx, key *auth.ClientKey) error {
+	if !key.AllowsIP(c.IP()) {
+		log.Debug().Str("client_key_id", key.ID.String()).Str("ip", c.IP()).Msg("Client key used from a disallowed IP address")
+		return auth.ErrClientKeyIPNotAllowed
 	}
+	c.Locals("client_key_restrictions", key.ClientKeyRestrictions)
+	return nil
 }
 
 // RequireScope checks if the authenticated user/client key/service key has required scopes
@@ -232,9 +251,7 @@ func RequireScope(requiredScopes ...string) fiber.Handler {
 		if authType == "clientkey" {
 			scopes, ok := c.Locals("client_key_scopes").([]string)
 			if !ok {
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-					"error": "No scopes found for client key",
-				})
+				return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "No scopes found for client key")
 			}
 
 			// Check if all required scopes are present
@@ -247,21 +264,27 @@ func RequireScope(requiredScopes ...string) fiber.Handler {
 					}
 				}
 				if !found {
-					return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-						"error":          "Insufficient permissions",
-						"required_scope": required,
+					return apierror.SendError(c, &apierror.Error{
+						Status:  fiber.StatusForbidden,
+						Code:    apierror.CodeInsufficientPermissions,
+						Message: "Insufficient permissions",
+						Details: fiber.Map{"required_scope": required},
 					})
 				}
 			}
+
+			// Storage routes are limited to the key's buckets
+			restrictions, _ := c.Locals("client_key_restrictions").(auth.ClientKeyRestrictions)
+			if bucket := c.Params("bucket"); bucket != "" && !restrictions.AllowsBucket(bucket) {
+				return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, fmt.Sprintf("Client key cannot access bucket '%s'", bucket))
+			}
 		}
 
 		// If authenticated via service key, check scopes
 		if authType == "service_key" {
 			scopes, ok := c.Locals("service_key_scopes").([]string)
 			if !ok {
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-					"error": "No scopes found for service key",
-				})
+				return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "No scopes found for service key")
 			}
 
 			// Check if all required scopes are present
@@ -274,9 +297,11 @@ func RequireScope(requiredScopes ...string) fiber.Handler {
 					}
 				}
 				if !found {
-					return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-						"error":          "Insufficient permissions",
-						"required_scope": required,
+					return apierror.SendError(c, &apierror.Error{
+						Status:  fiber.StatusForbidden,
+						Code:    apierror.CodeInsufficientPermissions,
+						Message: "Insufficient permissions",
+						Details: fiber.Map{"required_scope": required},
 					})
 				}
 			}
@@ -331,9 +356,7 @@ func RequireAuthOrServiceKey(authService *auth.Service, clientKeyService *auth.C
 				}
 				// JWT validation failed
 				log.Debug().Err(err).Msg("X-Service-Key JWT validation failed")
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-					"error": "Invalid service role token",
-				})
+				return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeInvalidToken, "Invalid service role token")
 			}
 
 			// Validate as service key (sk_... format)
@@ -341,9 +364,7 @@ func RequireAuthOrServiceKey(authService *auth.Service, clientKeyService *auth.C
 				return c.Next()
 			}
 			// If service key validation failed, don't try other methods
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid service key",
-			})
+			return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeInvalidToken, "Invalid service key")
 		}
 
 		// Try JWT authentication
@@ -393,15 +414,19 @@ func RequireAuthOrServiceKey(authService *auth.Service, clientKeyService *auth.C
 				isRevoked, err := authService.IsTokenRevoked(c.RequestCtx(), claims.ID)
 				if err != nil {
 					log.Error().Err(err).Str("jti", claims.ID).Msg("Token revocation check failed")
-					return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-						"error":   "service_unavailable",
-						"message": "Unable to verify token status",
+					return apierror.SendError(c, &apierror.Error{
+						Status:  fiber.StatusServiceUnavailable,
+						Code:    apierror.CodeServiceUnavailable,
+						Message: "service_unavailable",
+						Detail:  "Unable to verify token status",
 					})
 				}
 				if isRevoked {
-					return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-						"error":   "token_revoked",
-						"message": "Token has been revoked",
+					return apierror.SendError(c, &apierror.Error{
+						Status:  fiber.StatusUnauthorized,
+						Code:    apierror.CodeRevokedToken,
+						Message: "token_revoked",
+						Detail:  "Token has been revoked",
 					})
 				}
 
@@ -459,6 +484,7 @@ func RequireAuthOrServiceKey(authService *auth.Service, clientKeyService *auth.C
 								log.Warn().Str("jti", claims.ID).Msg("Service role token has been emergency revoked")
 								return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 									"error":             "Service role token has been revoked",
+									"code":              apierror.CodeRevokedToken,
 									"error_code":        "token_revoked",
 									"revocation_reason": "emergency_revocation",
 								})
@@ -482,9 +508,7 @@ func RequireAuthOrServiceKey(authService *auth.Service, clientKeyService *auth.C
 			}
 
 			// Bearer token was provided but invalid - return specific error
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid or expired Bearer token",
-			})
+			return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeExpiredToken, "Invalid or expired Bearer token")
 		}
 
 		// Try client key authentication (header only, query parameter removed for security)
@@ -502,6 +526,10 @@ func RequireAuthOrServiceKey(authService *auth.Service, clientKeyService *auth.C
 				if validatedKey.AllowedNamespaces != nil {
 					c.Locals("allowed_namespaces", validatedKey.AllowedNamespaces)
 				}
+				c.Locals("json_key_case", validatedKey.JSONKeyCase)
+				if err := applyClientKeyRestrictions(c, validatedKey); err != nil {
+					return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Client key cannot be used from this IP address")
+				}
 
 				if validatedKey.UserID != nil {
 					c.Locals("user_id", *validatedKey.UserID)
@@ -513,19 +541,13 @@ func RequireAuthOrServiceKey(authService *auth.Service, clientKeyService *auth.C
 			}
 			// Client key was provided but invalid - return specific error
 			if errors.Is(err, auth.ErrUserClientKeysDisabled) {
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-					"error": "User client keys are disabled. Contact an administrator.",
-				})
+				return apierror.Send(c, fiber.StatusForbidden, apierror.CodeFeatureDisabled, "User client keys are disabled. Contact an administrator.")
 			}
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid client key",
-			})
+			return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeInvalidToken, "Invalid client key")
 		}
 
 		// No authentication provided at all
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Authentication required. Provide Bearer token, X-Client-Key, or X-Service-Key",
-		})
+		return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeAuthRequired, "Authentication required. Provide Bearer token, X-Client-Key, or X-Service-Key")
 	}
 }
 
@@ -569,9 +591,7 @@ func OptionalAuthOrServiceKey(authService *auth.Service, clientKeyService *auth.
 				}
 				// JWT validation failed
 				log.Debug().Err(err).Msg("X-Service-Key JWT validation failed")
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-					"error": "Invalid service role token",
-				})
+				return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeInvalidToken, "Invalid service role token")
 			}
 
 			// Validate as service key (sk_... format)
@@ -579,9 +599,7 @@ func OptionalAuthOrServiceKey(authService *auth.Service, clientKeyService *auth.
 				return c.Next()
 			}
 			// If service key validation failed, don't try other methods
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid service key",
-			})
+			return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeInvalidToken, "Invalid service key")
 		}
 
 		// Try JWT authentication via Authorization Bearer header or token query param
@@ -604,15 +622,19 @@ func OptionalAuthOrServiceKey(authService *auth.Service, clientKeyService *auth.
 				isRevoked, err := authService.IsTokenRevoked(c.RequestCtx(), claims.ID)
 				if err != nil {
 					log.Error().Err(err).Str("jti", claims.ID).Msg("Token revocation check failed")
-					return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-						"error":   "service_unavailable",
-						"message": "Unable to verify token status",
+					return apierror.SendError(c, &apierror.Error{
+						Status:  fiber.StatusServiceUnavailable,
+						Code:    apierror.CodeServiceUnavailable,
+						Message: "service_unavailable",
+						Detail:  "Unable to verify token status",
 					})
 				}
 				if isRevoked {
-					return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-						"error":   "token_revoked",
-						"message": "Token has been revoked",
+					return apierror.SendError(c, &apierror.Error{
+						Status:  fiber.StatusUnauthorized,
+						Code:    apierror.CodeRevokedToken,
+						Message: "token_revoked",
+						Detail:  "Token has been revoked",
 					})
 				}
 
@@ -689,9 +711,7 @@ func OptionalAuthOrServiceKey(authService *auth.Service, clientKeyService *auth.
 
 			// If Bearer token was provided but invalid, return 401
 			// Don't fall back to anonymous access when invalid credentials are provided
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid or expired Bearer token",
-			})
+			return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeExpiredToken, "Invalid or expired Bearer token")
 		}
 
 		// Check for Supabase-style clientkey header (lowercase)
@@ -706,15 +726,19 @@ func OptionalAuthOrServiceKey(authService *auth.Service, clientKeyService *auth.
 				isRevoked, err := authService.IsTokenRevoked(c.RequestCtx(), claims.ID)
 				if err != nil {
 					log.Error().Err(err).Str("jti", claims.ID).Msg("Token revocation check failed")
-					return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-						"error":   "service_unavailable",
-						"message": "Unable to verify token status",
+					return apierror.SendError(c, &apierror.Error{
+						Status:  fiber.StatusServiceUnavailable,
+						Code:    apierror.CodeServiceUnavailable,
+						Message: "service_unavailable",
+						Detail:  "Unable to verify token status",
 					})
 				}
 				if isRevoked {
-					return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-						"error":   "token_revoked",
-						"message": "Token has been revoked",
+					return apierror.SendError(c, &apierror.Error{
+						Status:  fiber.StatusUnauthorized,
+						Code:    apierror.CodeRevokedToken,
+						Message: "token_revoked",
+						Detail:  "Token has been revoked",
 					})
 				}
 
@@ -783,6 +807,10 @@ func OptionalAuthOrServiceKey(authService *auth.Service, clientKeyService *auth.
 				if validatedKey.AllowedNamespaces != nil {
 					c.Locals("allowed_namespaces", validatedKey.AllowedNamespaces)
 				}
+				c.Locals("json_key_case", validatedKey.JSONKeyCase)
+				if err := applyClientKeyRestrictions(c, validatedKey); err != nil {
+					return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Client key cannot be used from this IP address")
+				}
 
 				c.Locals("auth_type", "clientkey")
 
@@ -798,13 +826,9 @@ func OptionalAuthOrServiceKey(authService *auth.Service, clientKeyService *auth.
 			// If client key was provided but invalid, return specific error
 			// Don't fall back to anonymous access when invalid credentials are provided
 			if errors.Is(err, auth.ErrUserClientKeysDisabled) {
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-					"error": "User client keys are disabled. Contact an administrator.",
-				})
+				return apierror.Send(c, fiber.StatusForbidden, apierror.CodeFeatureDisabled, "User client keys are disabled. Contact an administrator.")
 			}
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid client key",
-			})
+			return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeInvalidToken, "Invalid client key")
 		}
 
 		// No authentication provided - allow anonymous access with RLS
@@ -931,9 +955,7 @@ func RequireAdmin() fiber.Handler {
 			Str("path", c.Path()).
 			Msg("Admin access denied - requires service_role or dashboard_admin")
 
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Admin access required. Only service_role and dashboard_admin can access this endpoint.",
-		})
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAdminRequired, "Admin access required. Only service_role and dashboard_admin can access this endpoint.")
 	}
 }
 
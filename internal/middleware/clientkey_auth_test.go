@@ -1,12 +1,15 @@
 package middleware
 
 import (
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
+	"github.com/nimbleflux/fluxbase/internal/auth"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -75,9 +78,11 @@ func TestRequireScope_ClientKeyMissingScope(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
 
-	body, _ := io.ReadAll(resp.Body)
-	assert.Contains(t, string(body), "Insufficient permissions")
-	assert.Contains(t, string(body), "write")
+	var body apierror.Response
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "Insufficient permissions", body.Error)
+	assert.Equal(t, apierror.CodeInsufficientPermissions, body.Code)
+	assert.Equal(t, map[string]interface{}{"required_scope": "write"}, body.Details)
 }
 
 func TestRequireScope_ClientKeyNoScopes(t *testing.T) {
@@ -177,6 +182,58 @@ func TestRequireScope_NoAuthType(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 }
 
+func TestRequireScope_ClientKeyBuckets(t *testing.T) {
+	app := fiber.New()
+
+	app.Use(func(c fiber.Ctx) error {
+		c.Locals("auth_type", "clientkey")
+		c.Locals("client_key_scopes", []string{"read:storage"})
+		c.Locals("client_key_restrictions", auth.ClientKeyRestrictions{AllowedBuckets: []string{"avatars", "exports-*"}})
+		return c.Next()
+	})
+	app.Get("/storage/buckets", RequireScope("read:storage"), func(c fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+	app.Get("/storage/:bucket/*", RequireScope("read:storage"), func(c fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+
+	for path, status := range map[string]int{
+		"/storage/avatars/me.png":       http.StatusOK,
+		"/storage/exports-2026/q3.csv":  http.StatusOK,
+		"/storage/buckets":              http.StatusOK,
+		"/storage/invoices/inv-001.pdf": http.StatusForbidden,
+	} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		require.NoError(t, err)
+		assert.Equal(t, status, resp.StatusCode, path)
+	}
+}
+
+func TestApplyClientKeyRestrictions(t *testing.T) {
+	key := &auth.ClientKey{ClientKeyRestrictions: auth.ClientKeyRestrictions{AllowedIPs: []string{"10.0.0.0/8"}}}
+
+	app := fiber.New()
+	app.Get("/test", func(c fiber.Ctx) error {
+		if err := applyClientKeyRestrictions(c, key); err != nil {
+			return c.Status(fiber.StatusForbidden).SendString(err.Error())
+		}
+		_, ok := c.Locals("client_key_restrictions").(auth.ClientKeyRestrictions)
+		assert.True(t, ok)
+		return c.SendString("OK")
+	})
+
+	// Test requests come from 0.0.0.0
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/test", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	key.AllowedIPs = []string{"0.0.0.0/0"}
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/test", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
 // =============================================================================
 // RequireAdmin Tests
 // =============================================================================
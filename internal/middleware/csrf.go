@@ -8,6 +8,7 @@ import (
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/storage/memory/v2"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
 )
 
 // CSRFConfig holds configuration for CSRF protection
@@ -119,17 +120,21 @@ func CSRF(config ...CSRFConfig) fiber.Handler {
 		if cookieToken == "" {
 			token, err := generateCSRFToken(cfg.TokenLength)
 			if err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error":   "Internal server error",
-					"message": "Failed to generate CSRF token",
+				return apierror.SendError(c, &apierror.Error{
+					Status:  fiber.StatusInternalServerError,
+					Code:    apierror.CodeInternalError,
+					Message: "Internal server error",
+					Detail:  "Failed to generate CSRF token",
 				})
 			}
 
 			// Store token
 			if err := cfg.Storage.Set(token, []byte("1"), cfg.Expiration); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error":   "Internal server error",
-					"message": "Failed to store CSRF token",
+				return apierror.SendError(c, &apierror.Error{
+					Status:  fiber.StatusInternalServerError,
+					Code:    apierror.CodeInternalError,
+					Message: "Internal server error",
+					Detail:  "Failed to store CSRF token",
 				})
 			}
 
@@ -149,17 +154,21 @@ func CSRF(config ...CSRFConfig) fiber.Handler {
 			// The cookie is set, so the next request will include it
 			// Previously this allowed the first request through, which was a vulnerability
 			// (attacker could clear cookie and submit malicious POST)
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":   "CSRF token required",
-				"message": "CSRF token was not present. A new token has been issued. Please retry your request.",
+			return apierror.SendError(c, &apierror.Error{
+				Status:  fiber.StatusForbidden,
+				Code:    apierror.CodeAccessDenied,
+				Message: "CSRF token required",
+				Detail:  "CSRF token was not present. A new token has been issued. Please retry your request.",
 			})
 		}
 
 		// Validate tokens match
 		if cookieToken != requestToken || requestToken == "" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":   "CSRF token validation failed",
-				"message": "Invalid or missing CSRF token. Please refresh the page and try again.",
+			return apierror.SendError(c, &apierror.Error{
+				Status:  fiber.StatusForbidden,
+				Code:    apierror.CodeAccessDenied,
+				Message: "CSRF token validation failed",
+				Detail:  "Invalid or missing CSRF token. Please refresh the page and try again.",
 			})
 		}
 
@@ -169,17 +178,21 @@ func CSRF(config ...CSRFConfig) fiber.Handler {
 			// Token expired or doesn't exist, generate new one
 			token, err := generateCSRFToken(cfg.TokenLength)
 			if err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error":   "Internal server error",
-					"message": "Failed to generate CSRF token",
+				return apierror.SendError(c, &apierror.Error{
+					Status:  fiber.StatusInternalServerError,
+					Code:    apierror.CodeInternalError,
+					Message: "Internal server error",
+					Detail:  "Failed to generate CSRF token",
 				})
 			}
 
 			// Store new token
 			if err := cfg.Storage.Set(token, []byte("1"), cfg.Expiration); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error":   "Internal server error",
-					"message": "Failed to store CSRF token",
+				return apierror.SendError(c, &apierror.Error{
+					Status:  fiber.StatusInternalServerError,
+					Code:    apierror.CodeInternalError,
+					Message: "Internal server error",
+					Detail:  "Failed to store CSRF token",
 				})
 			}
 
@@ -195,9 +208,11 @@ func CSRF(config ...CSRFConfig) fiber.Handler {
 				SameSite: cfg.CookieSameSite,
 			})
 
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":   "CSRF token expired",
-				"message": "CSRF token has expired. Please refresh the page and try again.",
+			return apierror.SendError(c, &apierror.Error{
+				Status:  fiber.StatusForbidden,
+				Code:    apierror.CodeAccessDenied,
+				Message: "CSRF token expired",
+				Detail:  "CSRF token has expired. Please refresh the page and try again.",
 			})
 		}
 
@@ -81,6 +81,11 @@ func ETagWithConfig(config ETagConfig) fiber.Handler {
 			return nil
 		}
 
+		// Streamed bodies are not hashed: reading them would buffer the whole response
+		if c.Response().IsBodyStream() {
+			return nil
+		}
+
 		// Get response body
 		body := c.Response().Body()
 		if len(body) == 0 {
@@ -3,6 +3,7 @@ package middleware
 import (
 	"io"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gofiber/fiber/v3"
@@ -181,6 +182,28 @@ func TestETagMiddleware(t *testing.T) {
 		}
 	})
 
+	t.Run("skips ETag for streamed responses", func(t *testing.T) {
+		app := fiber.New()
+		app.Use(ETag())
+		app.Get("/test", func(c fiber.Ctx) error {
+			return c.SendStream(strings.NewReader("id,name\n1,test\n"))
+		})
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to test request: %v", err)
+		}
+
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			t.Error("Expected no ETag header for a streamed response")
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if string(body) != "id,name\n1,test\n" {
+			t.Errorf("Unexpected body %q", body)
+		}
+	})
+
 	t.Run("skips ETag for non-GET methods", func(t *testing.T) {
 		app := fiber.New()
 		app.Use(ETag())
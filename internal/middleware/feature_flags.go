@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
 	"github.com/nimbleflux/fluxbase/internal/auth"
 )
 
@@ -12,10 +13,7 @@ func RequireFeatureEnabled(settingsCache *auth.SettingsCache, featureKey string)
 	return func(c fiber.Ctx) error {
 		// If settings cache is nil, treat the feature as disabled
 		if settingsCache == nil {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Feature not available",
-				"code":  "FEATURE_DISABLED",
-			})
+			return apierror.Send(c, fiber.StatusNotFound, "FEATURE_DISABLED", "Feature not available")
 		}
 
 		// Check if feature is enabled (checks env vars first, then cache, then database)
@@ -23,10 +21,7 @@ func RequireFeatureEnabled(settingsCache *auth.SettingsCache, featureKey string)
 		isEnabled := settingsCache.GetBool(ctx, featureKey, false)
 
 		if !isEnabled {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Feature not available",
-				"code":  "FEATURE_DISABLED",
-			})
+			return apierror.Send(c, fiber.StatusNotFound, "FEATURE_DISABLED", "Feature not available")
 		}
 
 		return c.Next()
@@ -4,6 +4,7 @@ import (
 	"net"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
 	"github.com/nimbleflux/fluxbase/internal/config"
 	"github.com/rs/zerolog/log"
 )
@@ -48,8 +49,6 @@ func RequireGlobalIPAllowlist(cfg *config.ServerConfig) fiber.Handler {
 			Str("path", c.Path()).
 			Msg("Global IP allowlist: access denied - IP not in allowlist")
 
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Access denied - IP not allowlisted",
-		})
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Access denied - IP not allowlisted")
 	}
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/gofiber/fiber/v3"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
 	"github.com/rs/zerolog/log"
 )
 
@@ -191,10 +192,11 @@ func (m *IdempotencyMiddleware) Middleware() fiber.Handler {
 
 		// Validate key length regardless of other checks - this is a client error
 		if key != "" && len(key) > m.config.MaxKeyLength {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error":   "Invalid Idempotency-Key",
-				"code":    "IDEMPOTENCY_KEY_TOO_LONG",
-				"message": fmt.Sprintf("Idempotency key exceeds maximum length of %d characters", m.config.MaxKeyLength),
+			return apierror.SendError(c, &apierror.Error{
+				Status:  fiber.StatusBadRequest,
+				Code:    "IDEMPOTENCY_KEY_TOO_LONG",
+				Message: "Invalid Idempotency-Key",
+				Detail:  fmt.Sprintf("Idempotency key exceeds maximum length of %d characters", m.config.MaxKeyLength),
 			})
 		}
 
@@ -381,11 +383,12 @@ func (m *IdempotencyMiddleware) updateRecord(ctx context.Context, key string, st
 func (m *IdempotencyMiddleware) handleExistingKey(c fiber.Ctx, record *IdempotencyRecord, requestHash string) error {
 	// Verify request matches original (same method, path, body hash)
 	if record.Method != c.Method() || record.Path != c.Path() {
-		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
-			"error":   "Idempotency Key Mismatch",
-			"code":    "IDEMPOTENCY_KEY_MISMATCH",
-			"message": "This idempotency key was used for a different request",
-			"details": fiber.Map{
+		return apierror.SendError(c, &apierror.Error{
+			Status:  fiber.StatusUnprocessableEntity,
+			Code:    "IDEMPOTENCY_KEY_MISMATCH",
+			Message: "Idempotency Key Mismatch",
+			Detail:  "This idempotency key was used for a different request",
+			Details: fiber.Map{
 				"original_method": record.Method,
 				"original_path":   record.Path,
 				"current_method":  c.Method(),
@@ -396,21 +399,23 @@ func (m *IdempotencyMiddleware) handleExistingKey(c fiber.Ctx, record *Idempoten
 
 	// Optionally verify request body hash
 	if record.RequestHash != "" && requestHash != "" && record.RequestHash != requestHash {
-		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
-			"error":   "Idempotency Key Mismatch",
-			"code":    "IDEMPOTENCY_REQUEST_BODY_MISMATCH",
-			"message": "This idempotency key was used with a different request body",
+		return apierror.SendError(c, &apierror.Error{
+			Status:  fiber.StatusUnprocessableEntity,
+			Code:    "IDEMPOTENCY_REQUEST_BODY_MISMATCH",
+			Message: "Idempotency Key Mismatch",
+			Detail:  "This idempotency key was used with a different request body",
 		})
 	}
 
 	switch record.Status {
 	case StatusProcessing:
 		// Request is still being processed
-		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-			"error":   "Request In Progress",
-			"code":    "IDEMPOTENCY_REQUEST_IN_PROGRESS",
-			"message": "A request with this idempotency key is currently being processed",
-			"hint":    "Please wait and retry in a few seconds",
+		return apierror.SendError(c, &apierror.Error{
+			Status:  fiber.StatusConflict,
+			Code:    "IDEMPOTENCY_REQUEST_IN_PROGRESS",
+			Message: "Request In Progress",
+			Detail:  "A request with this idempotency key is currently being processed",
+			Hint:    "Please wait and retry in a few seconds",
 		})
 
 	case StatusCompleted:
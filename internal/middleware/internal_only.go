@@ -6,6 +6,7 @@ import (
 	"sync"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
 	"github.com/nimbleflux/fluxbase/internal/config"
 	"github.com/rs/zerolog/log"
 )
@@ -47,9 +48,7 @@ func RequireInternalWithExtractor(extractor IPExtractor) fiber.Handler {
 				Str("path", c.Path()).
 				Msg("Internal endpoint access denied - not from localhost")
 
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Access denied - internal endpoint",
-			})
+			return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Access denied - internal endpoint")
 		}
 
 		return c.Next()
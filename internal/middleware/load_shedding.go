@@ -0,0 +1,234 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
+	"github.com/nimbleflux/fluxbase/internal/observability"
+	"github.com/rs/zerolog/log"
+)
+
+// RequestPriority ranks requests for load shedding
+type RequestPriority string
+
+const (
+	// PriorityCritical requests are never shed: health checks, auth, the dashboard, admin APIs and webhooks
+	PriorityCritical RequestPriority = "critical"
+	// PriorityNormal requests carry credentials or change data
+	PriorityNormal RequestPriority = "normal"
+	// PriorityLow requests are anonymous reads, the first to be shed under load
+	PriorityLow RequestPriority = "low"
+)
+
+// Reasons a request was shed, used as the metric label
+const (
+	ShedReasonPoolSaturation = "pool_saturation"
+	ShedReasonLatency        = "latency"
+)
+
+// latencySmoothing is the weight of the newest request in the latency moving average
+const latencySmoothing = 0.1
+
+// criticalPathPrefixes are never shed, so operators and users can still sign in and manage the
+// server while it is overloaded, and incoming webhooks are not lost
+var criticalPathPrefixes = []string{
+	"/health",
+	"/ready",
+	"/metrics",
+	"/admin",
+	"/api/v1/admin",
+	"/api/v1/auth",
+	"/api/v1/webhooks",
+}
+
+// LoadSheddingConfig configures the load shedding middleware
+type LoadSheddingConfig struct {
+	// PoolSaturation is the fraction of database connections in use that counts as overload
+	PoolSaturation float64
+	// LatencyThreshold is the average request latency that counts as overload (0 disables the check)
+	LatencyThreshold time.Duration
+	// Cooldown keeps shedding active this long after load drops; it is also sent as Retry-After
+	Cooldown time.Duration
+	// ProtectedPaths are additional path prefixes that are never shed
+	ProtectedPaths []string
+	// PoolUsage returns the fraction of database connections in use (nil disables the check)
+	PoolUsage func() float64
+	// Metrics records shed requests (optional)
+	Metrics *observability.Metrics
+}
+
+// LoadShedder rejects low-priority requests with 503 while the server is overloaded.
+// Overload is detected from database pool saturation and a moving average of request latency.
+type LoadShedder struct {
+	config LoadSheddingConfig
+	now    func() time.Time
+
+	mu            sync.Mutex
+	avgLatency    time.Duration
+	lastSample    time.Time
+	sheddingUntil time.Time
+	reason        string
+}
+
+// NewLoadShedder creates a load shedder
+func NewLoadShedder(config LoadSheddingConfig) *LoadShedder {
+	if config.Cooldown <= 0 {
+		config.Cooldown = 10 * time.Second
+	}
+	return &LoadShedder{config: config, now: time.Now}
+}
+
+// ClassifyRequestPriority decides how important a request is before authentication runs.
+// Credentials are only inspected, not verified: a forged token merely avoids shedding and is
+// still rejected by the route's auth middleware.
+func ClassifyRequestPriority(c fiber.Ctx, protectedPaths []string) RequestPriority {
+	path := c.Path()
+	for _, prefix := range criticalPathPrefixes {
+		if hasPathPrefix(path, prefix) {
+			return PriorityCritical
+		}
+	}
+	for _, prefix := range protectedPaths {
+		if hasPathPrefix(path, prefix) {
+			return PriorityCritical
+		}
+	}
+
+	if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+		return PriorityNormal
+	}
+	if c.Get("X-Service-Key") != "" || c.Get("X-Client-Key") != "" {
+		return PriorityNormal
+	}
+	if role := bearerTokenRole(c.Get("Authorization")); role != "" && role != "anon" {
+		return PriorityNormal
+	}
+	return PriorityLow
+}
+
+// hasPathPrefix matches prefix as a whole path segment, so /admin doesn't match /administrators
+func hasPathPrefix(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/")
+}
+
+// bearerTokenRole returns the unverified role claim of a bearer JWT, "" if there is none
+func bearerTokenRole(header string) string {
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || token == "" {
+		return ""
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		// Not a JWT (e.g. an opaque client key) - treat it as credentials
+		return "authenticated"
+	}
+	role, _ := claims["role"].(string)
+	if role == "" {
+		return "authenticated"
+	}
+	return role
+}
+
+// overloaded reports whether requests should currently be shed, and why
+func (s *LoadShedder) overloaded() (bool, string) {
+	now := s.now()
+
+	reason := ""
+	if s.config.PoolUsage != nil && s.config.PoolSaturation > 0 && s.config.PoolUsage() >= s.config.PoolSaturation {
+		reason = ShedReasonPoolSaturation
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A stale average says nothing about current load, so only recent samples count
+	if reason == "" && s.config.LatencyThreshold > 0 && now.Sub(s.lastSample) < s.config.Cooldown &&
+		s.avgLatency >= s.config.LatencyThreshold {
+		reason = ShedReasonLatency
+	}
+
+	if reason != "" {
+		if !now.Before(s.sheddingUntil) {
+			log.Warn().Str("reason", reason).Msg("Server overloaded, shedding low-priority requests")
+			if s.config.Metrics != nil {
+				s.config.Metrics.SetLoadSheddingActive(true)
+			}
+		}
+		s.sheddingUntil = now.Add(s.config.Cooldown)
+		s.reason = reason
+		return true, reason
+	}
+
+	if now.Before(s.sheddingUntil) {
+		return true, s.reason
+	}
+	if s.reason != "" {
+		log.Info().Msg("Server load recovered, no longer shedding requests")
+		if s.config.Metrics != nil {
+			s.config.Metrics.SetLoadSheddingActive(false)
+		}
+		s.reason = ""
+	}
+	return false, ""
+}
+
+// observe adds a completed request's latency to the moving average
+func (s *LoadShedder) observe(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.lastSample.IsZero() || now.Sub(s.lastSample) >= s.config.Cooldown {
+		s.avgLatency = latency
+	} else {
+		s.avgLatency = time.Duration(latencySmoothing*float64(latency) + (1-latencySmoothing)*float64(s.avgLatency))
+	}
+	s.lastSample = now
+}
+
+// Middleware returns the load shedding middleware
+func (s *LoadShedder) Middleware() fiber.Handler {
+	retryAfter := strconv.Itoa(int(math.Ceil(s.config.Cooldown.Seconds())))
+
+	return func(c fiber.Ctx) error {
+		priority := ClassifyRequestPriority(c, s.config.ProtectedPaths)
+
+		if priority == PriorityLow {
+			if shed, reason := s.overloaded(); shed {
+				if s.config.Metrics != nil {
+					s.config.Metrics.RecordLoadShed(string(priority), reason)
+				}
+				c.Set(fiber.HeaderRetryAfter, retryAfter)
+				return apierror.SendError(c, &apierror.Error{
+					Status:  fiber.StatusServiceUnavailable,
+					Code:    "SERVICE_OVERLOADED",
+					Message: "Service overloaded",
+					Detail:  "The server is under heavy load. Please retry later or sign in.",
+				})
+			}
+		}
+
+		start := s.now()
+		err := c.Next()
+
+		// Long-lived connections would skew the average, and critical paths aren't representative
+		if priority != PriorityCritical && !isStreamingResponse(c) {
+			s.observe(s.now().Sub(start))
+		}
+		return err
+	}
+}
+
+// isStreamingResponse reports whether the request was a websocket upgrade or server-sent event stream
+func isStreamingResponse(c fiber.Ctx) bool {
+	if strings.EqualFold(c.Get(fiber.HeaderUpgrade), "websocket") {
+		return true
+	}
+	return strings.HasPrefix(string(c.Response().Header.ContentType()), "text/event-stream")
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signedRoleToken(t *testing.T, role string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"role": role}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	return token
+}
+
+func TestClassifyRequestPriority(t *testing.T) {
+	anonToken := signedRoleToken(t, "anon")
+	userToken := signedRoleToken(t, "authenticated")
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		headers map[string]string
+		want    RequestPriority
+	}{
+		{"health check", "GET", "/health", nil, PriorityCritical},
+		{"auth endpoint", "POST", "/api/v1/auth/signin", nil, PriorityCritical},
+		{"dashboard", "GET", "/admin/tables", nil, PriorityCritical},
+		{"admin api", "GET", "/api/v1/admin/users", nil, PriorityCritical},
+		{"incoming webhook", "POST", "/api/v1/webhooks/github", nil, PriorityCritical},
+		{"protected path", "GET", "/api/v1/functions/checkout", nil, PriorityCritical},
+		{"prefix is a whole segment", "GET", "/administrators", nil, PriorityLow},
+		{"anonymous read", "GET", "/api/v1/tables/posts", nil, PriorityLow},
+		{"anon key read", "GET", "/api/v1/tables/posts", map[string]string{"Authorization": "Bearer " + anonToken}, PriorityLow},
+		{"user read", "GET", "/api/v1/tables/posts", map[string]string{"Authorization": "Bearer " + userToken}, PriorityNormal},
+		{"opaque bearer token", "GET", "/api/v1/tables/posts", map[string]string{"Authorization": "Bearer fbk_abc"}, PriorityNormal},
+		{"client key read", "GET", "/api/v1/tables/posts", map[string]string{"X-Client-Key": "key"}, PriorityNormal},
+		{"anonymous write", "POST", "/api/v1/tables/posts", nil, PriorityNormal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			var got RequestPriority
+			app.Use(func(c fiber.Ctx) error {
+				got = ClassifyRequestPriority(c, []string{"/api/v1/functions/checkout"})
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoadShedder_PoolSaturation(t *testing.T) {
+	usage := 0.5
+	shedder := NewLoadShedder(LoadSheddingConfig{
+		PoolSaturation: 0.9,
+		Cooldown:       5 * time.Second,
+		PoolUsage:      func() float64 { return usage },
+	})
+	now := time.Now()
+	shedder.now = func() time.Time { return now }
+
+	app := fiber.New()
+	app.Use(shedder.Middleware())
+	app.All("/*", func(c fiber.Ctx) error { return c.SendString("ok") })
+
+	status := func(method, path string) int {
+		resp, err := app.Test(httptest.NewRequest(method, path, nil))
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusOK, status("GET", "/api/v1/tables/posts"))
+
+	usage = 0.95
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/tables/posts", nil))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "5", resp.Header.Get(fiber.HeaderRetryAfter))
+
+	// Higher priority traffic still goes through
+	assert.Equal(t, fiber.StatusOK, status("POST", "/api/v1/tables/posts"))
+	assert.Equal(t, fiber.StatusOK, status("POST", "/api/v1/auth/signin"))
+
+	// Shedding continues through the cooldown after load drops
+	usage = 0.1
+	now = now.Add(2 * time.Second)
+	assert.Equal(t, fiber.StatusServiceUnavailable, status("GET", "/api/v1/tables/posts"))
+
+	now = now.Add(6 * time.Second)
+	assert.Equal(t, fiber.StatusOK, status("GET", "/api/v1/tables/posts"))
+}
+
+func TestLoadShedder_Latency(t *testing.T) {
+	shedder := NewLoadShedder(LoadSheddingConfig{
+		LatencyThreshold: time.Second,
+		Cooldown:         5 * time.Second,
+	})
+	now := time.Now()
+	shedder.now = func() time.Time { return now }
+
+	shedder.observe(2 * time.Second)
+	shed, reason := shedder.overloaded()
+	assert.True(t, shed)
+	assert.Equal(t, ShedReasonLatency, reason)
+
+	// Fast requests pull the average down, and shedding ends after the cooldown
+	for i := 0; i < 30; i++ {
+		shedder.observe(10 * time.Millisecond)
+	}
+	now = now.Add(4 * time.Second)
+	shedder.observe(10 * time.Millisecond)
+	now = now.Add(2 * time.Second)
+	shed, _ = shedder.overloaded()
+	assert.False(t, shed)
+
+	// A stale average is ignored
+	shedder.observe(3 * time.Second)
+	now = now.Add(time.Minute)
+	shed, _ = shedder.overloaded()
+	assert.False(t, shed)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
 	"github.com/nimbleflux/fluxbase/internal/auth"
 	"github.com/nimbleflux/fluxbase/internal/config"
 	"github.com/rs/zerolog/log"
@@ -23,9 +24,7 @@ func RequireMigrationsEnabled(cfg *config.MigrationsConfig) fiber.Handler {
 				Str("path", c.Path()).
 				Str("ip", c.IP()).
 				Msg("Migrations API access denied - feature disabled")
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Not Found",
-			})
+			return apierror.Send(c, fiber.StatusNotFound, apierror.CodeNotFound, "Not Found")
 		}
 		return c.Next()
 	}
@@ -72,9 +71,7 @@ func RequireMigrationsIPAllowlist(migrationsCfg *config.MigrationsConfig, server
 			Str("path", c.Path()).
 			Msg("Migrations API access denied - IP not in allowlist")
 
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Access denied - IP not allowlisted for migrations",
-		})
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Access denied - IP not allowlisted for migrations")
 	}
 }
 
@@ -124,9 +121,7 @@ func RequireServiceKeyOnly(db *pgxpool.Pool, authService *auth.Service) fiber.Ha
 				log.Warn().
 					Str("role", claims.Role).
 					Msg("Migrations API access denied - JWT role must be service_role")
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-					"error": "Migrations API requires service_role JWT",
-				})
+				return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Migrations API requires service_role JWT")
 			}
 
 			log.Debug().Err(err).Msg("JWT validation failed, trying service key")
@@ -168,9 +163,7 @@ func RequireServiceKeyOnly(db *pgxpool.Pool, authService *auth.Service) fiber.Ha
 				return c.Next()
 			}
 
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid service key",
-			})
+			return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeInvalidToken, "Invalid service key")
 		}
 
 		// Neither JWT nor service key provided
@@ -178,9 +171,7 @@ func RequireServiceKeyOnly(db *pgxpool.Pool, authService *auth.Service) fiber.Ha
 			Str("path", c.Path()).
 			Str("ip", c.IP()).
 			Msg("Migrations API access denied - service key or service_role JWT required")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Service key or service_role JWT authentication required for migrations API",
-		})
+		return apierror.Send(c, fiber.StatusUnauthorized, apierror.CodeAuthRequired, "Service key or service_role JWT authentication required for migrations API")
 	}
 }
 
@@ -213,23 +204,17 @@ func RequireMigrationScope() fiber.Handler {
 				Str("auth_type", "jwt").
 				Interface("role", role).
 				Msg("Migrations require service_role JWT")
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Migrations require service_role JWT",
-			})
+			return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Migrations require service_role JWT")
 		}
 
 		// For service keys, check scopes
 		if authType != "service_key" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Migrations require service key or service_role JWT authentication",
-			})
+			return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Migrations require service key or service_role JWT authentication")
 		}
 
 		scopes, ok := c.Locals("service_key_scopes").([]string)
 		if !ok {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "No scopes found",
-			})
+			return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "No scopes found")
 		}
 
 		// Check for migration scope
@@ -247,9 +232,7 @@ func RequireMigrationScope() fiber.Handler {
 				Str("required", requiredScope).
 				Interface("scopes", scopes).
 				Msg("Service key missing required scope")
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Service key does not have migrations:execute scope",
-			})
+			return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, "Service key does not have migrations:execute scope")
 		}
 
 		return c.Next()
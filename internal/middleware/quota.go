@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
+	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/nimbleflux/fluxbase/internal/quota"
+	"github.com/rs/zerolog/log"
+)
+
+// QuotaEnforcer checks and records usage against quotas. It is implemented by *quota.Service.
+type QuotaEnforcer interface {
+	Check(ctx context.Context, subjects []quota.Subject, metric quota.Metric, amount int64) (*quota.Usage, error)
+	Record(subjects []quota.Subject, metric quota.Metric, amount int64)
+}
+
+// QuotaSubjects returns whose quotas a request counts against: its authenticated user and the
+// active organization of its token. Anonymous requests have no subjects.
+func QuotaSubjects(c fiber.Ctx) []quota.Subject {
+	var subjects []quota.Subject
+	if userID := quotaSubjectID(c.Locals("user_id")); userID != "" {
+		subjects = append(subjects, quota.Subject{Type: quota.SubjectUser, ID: userID})
+	}
+	if claims, ok := c.Locals("jwt_claims").(*auth.TokenClaims); ok && claims != nil {
+		if orgID := quotaSubjectID(claims.OrgID); orgID != "" {
+			subjects = append(subjects, quota.Subject{Type: quota.SubjectOrganization, ID: orgID})
+		}
+	}
+	return subjects
+}
+
+// quotaSubjectID normalizes an ID from Locals or claims, dropping values that are not UUIDs
+// (e.g. the "anonymous" user)
+func quotaSubjectID(v interface{}) string {
+	var id uuid.UUID
+	switch value := v.(type) {
+	case uuid.UUID:
+		id = value
+	case string:
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			return ""
+		}
+		id = parsed
+	}
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
+
+// RequireQuota rejects requests with 429 Too Many Requests once a quota of metric of the
+// requesting user or organization is used up. cost returns how much of the quota a request
+// uses; with a nil cost a request is one API call, and for the other metrics it only needs
+// some quota left. The cost is recorded as the request passes. Quotas fail open: when usage
+// cannot be read the request is let through.
+//
+// It must run after the auth middleware so the user and token claims are in Locals.
+func RequireQuota(enforcer QuotaEnforcer, metric quota.Metric, cost func(fiber.Ctx) int64) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		// Admins and the service role are exempt, like they are from rate limits
+		if role, ok := c.Locals("user_role").(string); ok && (role == "admin" || role == "dashboard_admin" || role == "service_role") {
+			return c.Next()
+		}
+
+		subjects := QuotaSubjects(c)
+		if len(subjects) == 0 {
+			return c.Next()
+		}
+
+		var amount int64
+		if cost != nil {
+			amount = cost(c)
+		} else if metric.Counted() {
+			amount = 1
+		}
+
+		exceeded, err := enforcer.Check(c.Context(), subjects, metric, amount)
+		if err != nil {
+			log.Warn().Err(err).Str("metric", string(metric)).Msg("Failed to check usage quotas, allowing request")
+		} else if exceeded != nil {
+			return SendQuotaExceeded(c, exceeded)
+		}
+
+		enforcer.Record(subjects, metric, amount)
+		return c.Next()
+	}
+}
+
+// SendQuotaExceeded sends a 429 error response whose details are the used up quota. Quotas
+// that reset also get a Retry-After header with the seconds until the next period starts.
+func SendQuotaExceeded(c fiber.Ctx, usage *quota.Usage) error {
+	var limit int64
+	if usage.Limit != nil {
+		limit = *usage.Limit
+	}
+
+	detail := fmt.Sprintf("The %s quota of %d of this %s is used up", usage.Metric, limit, usage.SubjectType)
+	hint := "Free up usage or ask an administrator to raise the quota"
+	if usage.ResetsAt != nil {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(max(secondsUntil(*usage.ResetsAt), 1)))
+		detail = fmt.Sprintf("The %s quota of %d per %s of this %s is used up", usage.Metric, limit, usage.Period, usage.SubjectType)
+		hint = "Retry after the quota resets or ask an administrator to raise it"
+	}
+
+	return apierror.SendError(c, &apierror.Error{
+		Status:  fiber.StatusTooManyRequests,
+		Code:    apierror.CodeQuotaExceeded,
+		Message: "Usage quota exceeded",
+		Detail:  detail,
+		Hint:    hint,
+		Details: usage,
+	})
+}
+
+// UploadSizeCost is a cost for quota.MetricStorageBytes that reads the size of an upload from
+// the Upload-Length header of resumable uploads, or else from the Content-Length header
+func UploadSizeCost(c fiber.Ctx) int64 {
+	if size, err := strconv.ParseInt(c.Get("Upload-Length"), 10, 64); err == nil && size > 0 {
+		return size
+	}
+	if size := c.Request().Header.ContentLength(); size > 0 {
+		return int64(size)
+	}
+	return 0
+}
@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
+	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/nimbleflux/fluxbase/internal/quota"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	quotaTestUserID = "3f1c9a52-7d3e-4c41-9f0a-2b6d8e5a1c70"
+	quotaTestOrgID  = "9b2e4d10-5a6f-4e8b-8c1d-7f3a2e9b0d64"
+)
+
+// fakeQuotaEnforcer allows requests until limit units have been recorded
+type fakeQuotaEnforcer struct {
+	limit    int64
+	used     int64
+	err      error
+	subjects []quota.Subject
+	amounts  []int64
+}
+
+func (f *fakeQuotaEnforcer) Check(ctx context.Context, subjects []quota.Subject, metric quota.Metric, amount int64) (*quota.Usage, error) {
+	f.subjects = subjects
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.used >= f.limit || f.used+amount > f.limit {
+		limit, remaining := f.limit, int64(0)
+		resetsAt := time.Now().Add(time.Hour)
+		return &quota.Usage{
+			SubjectType: subjects[0].Type,
+			SubjectID:   subjects[0].ID,
+			Metric:      metric,
+			Period:      quota.PeriodDay,
+			Limit:       &limit,
+			Used:        f.used,
+			Remaining:   &remaining,
+			ResetsAt:    &resetsAt,
+		}, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeQuotaEnforcer) Record(subjects []quota.Subject, metric quota.Metric, amount int64) {
+	f.used += amount
+	f.amounts = append(f.amounts, amount)
+}
+
+// newQuotaTestApp sets the user, role and organization of every request before the quota middleware
+func newQuotaTestApp(enforcer QuotaEnforcer, metric quota.Metric, cost func(fiber.Ctx) int64, role, orgID string) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c fiber.Ctx) error {
+		c.Locals("user_id", quotaTestUserID)
+		c.Locals("user_role", role)
+		c.Locals("jwt_claims", &auth.TokenClaims{UserID: quotaTestUserID, OrgID: orgID})
+		return c.Next()
+	})
+	app.Post("/test", RequireQuota(enforcer, metric, cost), func(c fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+	return app
+}
+
+func TestQuotaSubjects(t *testing.T) {
+	app := fiber.New()
+	var got []quota.Subject
+	app.Get("/test", func(c fiber.Ctx) error {
+		c.Locals("user_id", c.Query("user"))
+		c.Locals("jwt_claims", &auth.TokenClaims{OrgID: c.Query("org")})
+		got = QuotaSubjects(c)
+		return nil
+	})
+
+	_, err := app.Test(httptest.NewRequest("GET", "/test?user="+quotaTestUserID+"&org="+quotaTestOrgID, nil))
+	require.NoError(t, err)
+	assert.Equal(t, []quota.Subject{
+		{Type: quota.SubjectUser, ID: quotaTestUserID},
+		{Type: quota.SubjectOrganization, ID: quotaTestOrgID},
+	}, got)
+
+	_, err = app.Test(httptest.NewRequest("GET", "/test?user=anonymous", nil))
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestRequireQuota(t *testing.T) {
+	t.Run("counts calls until the quota is used up", func(t *testing.T) {
+		enforcer := &fakeQuotaEnforcer{limit: 2}
+		app := newQuotaTestApp(enforcer, quota.MetricAPICalls, nil, "authenticated", quotaTestOrgID)
+
+		for i := 0; i < 2; i++ {
+			resp, err := app.Test(httptest.NewRequest("POST", "/test", nil))
+			require.NoError(t, err)
+			assert.Equal(t, 200, resp.StatusCode)
+		}
+		assert.Equal(t, []int64{1, 1}, enforcer.amounts)
+		assert.Len(t, enforcer.subjects, 2, "user and organization")
+
+		resp, err := app.Test(httptest.NewRequest("POST", "/test", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 429, resp.StatusCode)
+
+		retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+		require.NoError(t, err)
+		assert.InDelta(t, 3600, retryAfter, 2)
+
+		var body struct {
+			apierror.Response
+			Details *quota.Usage `json:"details"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, apierror.CodeQuotaExceeded, body.Code)
+		assert.Equal(t, "Usage quota exceeded", body.Error)
+		assert.Contains(t, body.Message, "api_calls quota of 2 per day")
+		assert.NotEmpty(t, body.Hint)
+		require.NotNil(t, body.Details)
+		assert.Equal(t, int64(2), body.Details.Used)
+		assert.Equal(t, quotaTestUserID, body.Details.SubjectID)
+	})
+
+	t.Run("costs come from the request", func(t *testing.T) {
+		enforcer := &fakeQuotaEnforcer{limit: 1000}
+		app := newQuotaTestApp(enforcer, quota.MetricStorageBytes, UploadSizeCost, "authenticated", "")
+
+		req := httptest.NewRequest("POST", "/test", strings.NewReader(strings.Repeat("x", 600)))
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, []int64{600}, enforcer.amounts)
+
+		req = httptest.NewRequest("POST", "/test", nil)
+		req.Header.Set("Upload-Length", "500")
+		resp, err = app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, 429, resp.StatusCode)
+	})
+
+	t.Run("measured metrics only need quota left", func(t *testing.T) {
+		enforcer := &fakeQuotaEnforcer{limit: 10, used: 9}
+		app := newQuotaTestApp(enforcer, quota.MetricAITokens, nil, "authenticated", "")
+
+		resp, err := app.Test(httptest.NewRequest("POST", "/test", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, []int64{0}, enforcer.amounts)
+	})
+
+	t.Run("admins are exempt", func(t *testing.T) {
+		enforcer := &fakeQuotaEnforcer{limit: 0}
+		app := newQuotaTestApp(enforcer, quota.MetricAPICalls, nil, "service_role", "")
+
+		resp, err := app.Test(httptest.NewRequest("POST", "/test", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Nil(t, enforcer.subjects)
+	})
+
+	t.Run("fails open", func(t *testing.T) {
+		enforcer := &fakeQuotaEnforcer{limit: 0, err: errors.New("database unavailable")}
+		app := newQuotaTestApp(enforcer, quota.MetricAPICalls, nil, "authenticated", "")
+
+		resp, err := app.Test(httptest.NewRequest("POST", "/test", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+	})
+}
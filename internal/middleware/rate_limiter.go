@@ -2,9 +2,12 @@ package middleware
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
@@ -12,6 +15,7 @@ import (
 	"github.com/gofiber/storage/memory/v2"
 	"github.com/nimbleflux/fluxbase/internal/auth"
 	"github.com/nimbleflux/fluxbase/internal/observability"
+	"github.com/nimbleflux/fluxbase/internal/ratelimit"
 	"github.com/rs/zerolog/log"
 )
 
@@ -70,18 +74,131 @@ type RateLimiterConfig struct {
 	KeyFunc    func(fiber.Ctx) string // Function to generate the key for rate limiting
 	Message    string                 // Custom error message
 	Storage    fiber.Storage          // Optional shared storage (if nil, creates new storage)
+
+	// MaxFunc returns the limit for a request, so one limiter can apply different limits to
+	// the dimensions its KeyFunc separates (client keys, users, IPs). Nil uses Max.
+	MaxFunc func(fiber.Ctx) int
+
+	// Algorithm counts requests in the rate limit store. Empty uses the algorithm set with
+	// SetRateLimitAlgorithm. Without a store, sliding windows use Fiber's sliding window
+	// and other algorithms its fixed window.
+	Algorithm ratelimit.Algorithm
+}
+
+// SetRateLimitStore makes rate limiters created by NewRateLimiter count requests in store,
+// so limits are shared by all instances using the same backend. It is read on every
+// request, so it can be set after the limiters have been created. With no store, each
+// limiter counts in its own (or the provided fiber) storage.
+func SetRateLimitStore(store ratelimit.Store) {
+	if store == nil {
+		rateLimitStore.Store(nil)
+		return
+	}
+	rateLimitStore.Store(&rateLimitStoreRef{store: store})
+}
+
+type rateLimitStoreRef struct {
+	store ratelimit.Store
+}
+
+var rateLimitStore atomic.Pointer[rateLimitStoreRef]
+
+func currentRateLimitStore() ratelimit.Store {
+	if ref := rateLimitStore.Load(); ref != nil {
+		return ref.store
+	}
+	return nil
+}
+
+var rateLimitAlgorithm atomic.Value // ratelimit.Algorithm
+
+// SetRateLimitAlgorithm sets the algorithm of rate limiters that don't choose one. Like the
+// store it is read on every request. Empty restores the fixed window.
+func SetRateLimitAlgorithm(algorithm ratelimit.Algorithm) {
+	rateLimitAlgorithm.Store(algorithm)
+}
+
+func currentRateLimitAlgorithm() ratelimit.Algorithm {
+	algorithm, _ := rateLimitAlgorithm.Load().(ratelimit.Algorithm)
+	return algorithm
+}
+
+// Rate limit response headers
+const (
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderRateLimitReset     = "X-RateLimit-Reset" // Unix timestamp when the window resets
+
+	// Standard RateLimit header fields (IETF httpapi-ratelimit-headers), sent alongside the
+	// X-RateLimit-* headers
+	HeaderStandardRateLimitLimit     = "RateLimit-Limit"
+	HeaderStandardRateLimitRemaining = "RateLimit-Remaining"
+	HeaderStandardRateLimitReset     = "RateLimit-Reset" // Seconds until the window resets
+)
+
+// RateLimitExceededResponse is the body of a 429 response from a rate limiter
+type RateLimitExceededResponse struct {
+	Code       string    `json:"code"`
+	Error      string    `json:"error"`
+	Message    string    `json:"message"`
+	Limit      int64     `json:"limit"`
+	Remaining  int64     `json:"remaining"`
+	ResetAt    time.Time `json:"reset_at"`
+	RetryAfter int       `json:"retry_after"` // Seconds until the window resets
+}
+
+// SetRateLimitHeaders sets the X-RateLimit-* and RateLimit-* headers for a rate limit check result
+func SetRateLimitHeaders(c fiber.Ctx, result *ratelimit.Result) {
+	limit := strconv.FormatInt(result.Limit, 10)
+	remaining := strconv.FormatInt(result.Remaining, 10)
+
+	c.Set(HeaderRateLimitLimit, limit)
+	c.Set(HeaderRateLimitRemaining, remaining)
+	c.Set(HeaderRateLimitReset, strconv.FormatInt(result.ResetAt.Unix(), 10))
+	c.Set(HeaderStandardRateLimitLimit, limit)
+	c.Set(HeaderStandardRateLimitRemaining, remaining)
+	c.Set(HeaderStandardRateLimitReset, strconv.Itoa(secondsUntil(result.ResetAt)))
+}
+
+// secondsUntil returns the whole seconds until t, rounded up and never negative
+func secondsUntil(t time.Time) int {
+	return max(int(math.Ceil(time.Until(t).Seconds())), 0)
+}
+
+// SendRateLimitExceeded sends a 429 response with the rate limit and Retry-After headers
+// and a RateLimitExceededResponse body, so clients can back off until the window resets
+func SendRateLimitExceeded(c fiber.Ctx, result *ratelimit.Result, message string) error {
+	retryAfter := max(secondsUntil(result.ResetAt), 1)
+
+	SetRateLimitHeaders(c, result)
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+	return c.Status(fiber.StatusTooManyRequests).JSON(RateLimitExceededResponse{
+		Code:       "RATE_LIMIT_EXCEEDED",
+		Error:      "Rate limit exceeded",
+		Message:    message,
+		Limit:      result.Limit,
+		Remaining:  result.Remaining,
+		ResetAt:    result.ResetAt.UTC().Truncate(time.Second),
+		RetryAfter: retryAfter,
+	})
 }
 
 // NewRateLimiter creates a new rate limiter middleware with custom configuration.
 //
-// IMPORTANT: This middleware uses Fiber's native in-memory storage for rate limiting.
-// Rate limiting is per-instance only. In multi-instance deployments, each instance
-// maintains its own rate limit counters independently.
+// When a store has been set with SetRateLimitStore (the server sets the scaling backend's
+// store: memory, PostgreSQL or Redis), requests are counted there and limits hold across
+// instances. Otherwise Fiber's in-memory limiter is used.
+//
+// Requests are counted with the limiter's algorithm (fixed window, sliding window or token
+// bucket), and MaxFunc can vary the limit by request, for example per user or per IP.
+//
+// Every response carries X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset
+// headers and their standard RateLimit-* counterparts, and requests over the limit get a 429
+// with Retry-After and a RateLimitExceededResponse body.
 //
 // SECURITY WARNING: In-memory rate limiting is per-instance only. In multi-instance deployments,
 // attackers can bypass rate limits by targeting different instances. For production environments
-// with horizontal scaling, consider using a reverse proxy (nginx, Traefik) with centralized
-// rate limiting, or implement custom middleware with Redis-backed storage.
+// with horizontal scaling, configure the postgres or redis scaling backend.
 // See docs/deployment/production-checklist.md for details.
 func NewRateLimiter(config RateLimiterConfig) fiber.Handler {
 	// Log warning about in-memory rate limiting in multi-instance environments
@@ -111,6 +228,13 @@ func NewRateLimiter(config RateLimiterConfig) fiber.Handler {
 		}
 	}
 
+	maxFor := func(c fiber.Ctx) int {
+		if config.MaxFunc != nil {
+			return config.MaxFunc(c)
+		}
+		return config.Max
+	}
+
 	// Default error message
 	if config.Message == "" {
 		config.Message = fmt.Sprintf("Rate limit exceeded. Maximum %d requests per %s allowed.",
@@ -123,27 +247,76 @@ func NewRateLimiter(config RateLimiterConfig) fiber.Handler {
 		limiterName = "default"
 	}
 
-	return limiter.New(limiter.Config{
-		Max:          config.Max,
-		Expiration:   config.Expiration,
-		KeyGenerator: config.KeyFunc,
+	recordHit := func() {
+		if rateLimiterMetrics != nil {
+			rateLimiterMetrics.RecordRateLimitHit(limiterName)
+		}
+	}
+
+	var localAlgorithm limiter.Handler = limiter.FixedWindow{}
+	algorithm := config.Algorithm
+	if algorithm == "" {
+		algorithm = currentRateLimitAlgorithm()
+	}
+	if algorithm == ratelimit.AlgorithmSlidingWindow {
+		localAlgorithm = limiter.SlidingWindow{}
+	}
+
+	localLimiter := limiter.New(limiter.Config{
+		Max:               config.Max,
+		MaxFunc:           maxFor,
+		Expiration:        config.Expiration,
+		KeyGenerator:      config.KeyFunc,
+		LimiterMiddleware: localAlgorithm,
 		LimitReached: func(c fiber.Ctx) error {
-			// Record rate limit hit metric
-			if rateLimiterMetrics != nil {
-				rateLimiterMetrics.RecordRateLimitHit(limiterName, c.IP())
-			}
+			recordHit()
 
-			retryAfter := int(config.Expiration.Seconds())
-			c.Set("Retry-After", fmt.Sprintf("%d", retryAfter))
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"code":        "RATE_LIMIT_EXCEEDED",
-				"error":       "Rate limit exceeded",
-				"message":     config.Message,
-				"retry_after": retryAfter,
-			})
+			// The limiter sets Retry-After to the seconds left in the window
+			retryAfter, err := strconv.Atoi(string(c.Response().Header.Peek(fiber.HeaderRetryAfter)))
+			if err != nil {
+				retryAfter = int(config.Expiration.Seconds())
+			}
+			return SendRateLimitExceeded(c, &ratelimit.Result{
+				Limit:   int64(maxFor(c)),
+				ResetAt: time.Now().Add(time.Duration(retryAfter) * time.Second),
+			}, config.Message)
 		},
 		Storage: storage,
 	})
+
+	return func(c fiber.Ctx) error {
+		if store := currentRateLimitStore(); store != nil {
+			algorithm := config.Algorithm
+			if algorithm == "" {
+				algorithm = currentRateLimitAlgorithm()
+			}
+			key := config.KeyFunc(c)
+			result, err := ratelimit.CheckAlgorithm(c.RequestCtx(), store, algorithm, key, int64(maxFor(c)), config.Expiration)
+			if err != nil {
+				// Fail open on store errors, like other rate limit checks
+				log.Error().Err(err).Str("limiter", limiterName).Msg("Rate limit check failed")
+				return c.Next()
+			}
+			if !result.Allowed {
+				recordHit()
+				return SendRateLimitExceeded(c, result, config.Message)
+			}
+			SetRateLimitHeaders(c, result)
+			return c.Next()
+		}
+
+		err := localLimiter(c)
+		// The limiter reports the reset as seconds from now, convert it to a timestamp and
+		// add the standard headers. Values longer than the window are already timestamps
+		// (set by SendRateLimitExceeded along with the standard headers).
+		if reset, parseErr := strconv.ParseInt(string(c.Response().Header.Peek(HeaderRateLimitReset)), 10, 64); parseErr == nil && reset <= int64(config.Expiration.Seconds()) {
+			c.Set(HeaderRateLimitReset, strconv.FormatInt(time.Now().Unix()+reset, 10))
+			c.Set(HeaderStandardRateLimitLimit, string(c.Response().Header.Peek(HeaderRateLimitLimit)))
+			c.Set(HeaderStandardRateLimitRemaining, string(c.Response().Header.Peek(HeaderRateLimitRemaining)))
+			c.Set(HeaderStandardRateLimitReset, strconv.FormatInt(reset, 10))
+		}
+		return err
+	}
 }
 
 // AuthLoginLimiter limits login attempts per IP
@@ -465,40 +638,46 @@ func DefaultClientKeyLimiter() fiber.Handler {
 	return ClientKeyLimiter(1000, 1*time.Minute)
 }
 
-// PerUserOrIPLimiter implements tiered rate limiting:
+// PerUserOrIPLimiter implements tiered rate limiting, counting each client key, user and
+// anonymous IP separately against its own limit:
 // - Authenticated users: higher limit
 // - Client keys: configurable limit
 // - Anonymous (IP): lower limit
 func PerUserOrIPLimiter(anonMax, userMax, clientKeyMax int, duration time.Duration) fiber.Handler {
 	return NewRateLimiter(RateLimiterConfig{
-		Max:        anonMax, // Base max (will be adjusted by key function)
+		Name:       "per_user_or_ip",
+		Max:        anonMax,
 		Expiration: duration,
 		KeyFunc: func(c fiber.Ctx) string {
-			// Priority 1: Check for client key
-			clientKeyID := c.Locals("client_key_id")
-			if clientKeyID != nil {
-				if kid, ok := clientKeyID.(string); ok && kid != "" {
-					// Use client key specific limit
-					return fmt.Sprintf("clientkey:%s:%d", kid, clientKeyMax)
-				}
-			}
-
-			// Priority 2: Check for authenticated user
-			userID := c.Locals("user_id")
-			if userID != nil {
-				if uid, ok := userID.(string); ok && uid != "" {
-					// Use user specific limit
-					return fmt.Sprintf("user:%s:%d", uid, userMax)
-				}
+			dimension, id := rateLimitDimension(c)
+			return dimension + ":" + id
+		},
+		MaxFunc: func(c fiber.Ctx) int {
+			switch dimension, _ := rateLimitDimension(c); dimension {
+			case "clientkey":
+				return clientKeyMax
+			case "user":
+				return userMax
+			default:
+				return anonMax
 			}
-
-			// Priority 3: Fallback to IP (anonymous)
-			return fmt.Sprintf("ip:%s:%d", c.IP(), anonMax)
 		},
 		Message: "Rate limit exceeded. Please try again later.",
 	})
 }
 
+// rateLimitDimension returns who a request is counted against: its client key, else its
+// authenticated user, else its IP address
+func rateLimitDimension(c fiber.Ctx) (dimension, id string) {
+	if kid, ok := c.Locals("client_key_id").(string); ok && kid != "" {
+		return "clientkey", kid
+	}
+	if uid, ok := c.Locals("user_id").(string); ok && uid != "" && uid != "anonymous" {
+		return "user", uid
+	}
+	return "ip", c.IP()
+}
+
 // AdminSetupLimiter limits admin setup attempts per IP
 // Very strict since this is a one-time operation
 func AdminSetupLimiter(storage ...fiber.Storage) fiber.Handler {
@@ -521,6 +700,28 @@ func AdminSetupLimiterWithConfig(max int, expiration time.Duration, storage ...f
 	return NewRateLimiter(cfg)
 }
 
+// ClientKeyEnrollLimiter limits client key enrollment attempts per IP
+// Bootstrap tokens are single use, so legitimate clients only need a few attempts
+func ClientKeyEnrollLimiter(storage ...fiber.Storage) fiber.Handler {
+	return ClientKeyEnrollLimiterWithConfig(10, 15*time.Minute, storage...)
+}
+
+// ClientKeyEnrollLimiterWithConfig creates a client key enrollment rate limiter with custom limits
+func ClientKeyEnrollLimiterWithConfig(max int, expiration time.Duration, storage ...fiber.Storage) fiber.Handler {
+	cfg := RateLimiterConfig{
+		Max:        max,
+		Expiration: expiration,
+		KeyFunc: func(c fiber.Ctx) string {
+			return "client_key_enroll:" + c.IP()
+		},
+		Message: fmt.Sprintf("Too many client key enrollment attempts. Please try again in %d minutes.", int(expiration.Minutes())),
+	}
+	if len(storage) > 0 && storage[0] != nil {
+		cfg.Storage = storage[0]
+	}
+	return NewRateLimiter(cfg)
+}
+
 // AdminLoginLimiter limits admin login attempts per IP
 // Max is set to 4 to trigger rate limiting before account lockout (which happens at 5 failed attempts)
 func AdminLoginLimiter(storage ...fiber.Storage) fiber.Handler {
@@ -543,6 +744,36 @@ func AdminLoginLimiterWithConfig(max int, expiration time.Duration, storage ...f
 	return NewRateLimiter(cfg)
 }
 
+// TableExportLimiterWithConfig limits table exports per client key, user or IP.
+// Each export holds a database connection while it streams, so the limit is much lower
+// than for regular reads. A max of 0 disables the limit.
+func TableExportLimiterWithConfig(max int, expiration time.Duration, storage ...fiber.Storage) fiber.Handler {
+	if max <= 0 {
+		return func(c fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+	cfg := RateLimiterConfig{
+		Name:       "table_export",
+		Max:        max,
+		Expiration: expiration,
+		KeyFunc: func(c fiber.Ctx) string {
+			if kid, ok := c.Locals("client_key_id").(string); ok && kid != "" {
+				return "table_export:clientkey:" + kid
+			}
+			if uid, ok := c.Locals("user_id").(string); ok && uid != "" {
+				return "table_export:user:" + uid
+			}
+			return "table_export:ip:" + c.IP()
+		},
+		Message: fmt.Sprintf("Too many table exports. Maximum %d exports per %s allowed.", max, expiration.String()),
+	}
+	if len(storage) > 0 && storage[0] != nil {
+		cfg.Storage = storage[0]
+	}
+	return NewRateLimiter(cfg)
+}
+
 // GitHubWebhookLimiter limits GitHub webhook requests per IP and repository
 // Prevents abuse of the webhook endpoint for branch creation/deletion
 func GitHubWebhookLimiter(storage ...fiber.Storage) fiber.Handler {
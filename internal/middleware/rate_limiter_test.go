@@ -1,12 +1,15 @@
 package middleware
 
 import (
+	"encoding/json"
 	"io"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/ratelimit"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -132,6 +135,177 @@ func TestNewRateLimiter_RetryAfterHeader(t *testing.T) {
 	assert.Equal(t, "30", resp2.Header.Get("Retry-After"))
 }
 
+func TestNewRateLimiter_QuotaHeaders(t *testing.T) {
+	limiter := NewRateLimiter(RateLimiterConfig{
+		Max:        2,
+		Expiration: time.Minute,
+	})
+
+	app := fiber.New()
+	app.Use(limiter)
+	app.Get("/test", func(c fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+
+	resp1, err := app.Test(httptest.NewRequest("GET", "/test", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp1.StatusCode)
+	assert.Equal(t, "2", resp1.Header.Get(HeaderRateLimitLimit))
+	assert.Equal(t, "1", resp1.Header.Get(HeaderRateLimitRemaining))
+
+	// Reset is a Unix timestamp, not seconds from now
+	reset, err := strconv.ParseInt(resp1.Header.Get(HeaderRateLimitReset), 10, 64)
+	require.NoError(t, err)
+	assert.InDelta(t, time.Now().Add(time.Minute).Unix(), reset, 2)
+
+	_, _ = app.Test(httptest.NewRequest("GET", "/test", nil))
+	resp3, err := app.Test(httptest.NewRequest("GET", "/test", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 429, resp3.StatusCode)
+	assert.Equal(t, "2", resp3.Header.Get(HeaderRateLimitLimit))
+	assert.Equal(t, "0", resp3.Header.Get(HeaderRateLimitRemaining))
+
+	// Fiber's limiter counts in whole seconds, so allow for a second boundary between requests
+	limitedReset, err := strconv.ParseInt(resp3.Header.Get(HeaderRateLimitReset), 10, 64)
+	require.NoError(t, err)
+	assert.InDelta(t, reset, limitedReset, 1)
+
+	var body RateLimitExceededResponse
+	require.NoError(t, json.NewDecoder(resp3.Body).Decode(&body))
+	assert.Equal(t, "RATE_LIMIT_EXCEEDED", body.Code)
+	assert.Equal(t, int64(2), body.Limit)
+	assert.Equal(t, resp3.Header.Get("Retry-After"), strconv.Itoa(body.RetryAfter))
+	assert.Equal(t, limitedReset, body.ResetAt.Unix())
+}
+
+func TestNewRateLimiter_SharedStore(t *testing.T) {
+	store := ratelimit.NewMemoryStore(time.Minute)
+	defer func() { _ = store.Close() }()
+	SetRateLimitStore(store)
+	defer SetRateLimitStore(nil)
+
+	newApp := func() *fiber.App {
+		app := fiber.New()
+		app.Use(NewRateLimiter(RateLimiterConfig{
+			Max:        2,
+			Expiration: time.Minute,
+			KeyFunc: func(c fiber.Ctx) string {
+				return "shared_store_test"
+			},
+			Message: "Too many requests",
+		}))
+		app.Get("/test", func(c fiber.Ctx) error {
+			return c.SendString("OK")
+		})
+		return app
+	}
+
+	// Two instances count against the same store
+	instance1, instance2 := newApp(), newApp()
+
+	resp1, err := instance1.Test(httptest.NewRequest("GET", "/test", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp1.StatusCode)
+	assert.Equal(t, "1", resp1.Header.Get(HeaderRateLimitRemaining))
+
+	resp2, err := instance2.Test(httptest.NewRequest("GET", "/test", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp2.StatusCode)
+	assert.Equal(t, "0", resp2.Header.Get(HeaderRateLimitRemaining))
+
+	resp3, err := instance1.Test(httptest.NewRequest("GET", "/test", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 429, resp3.StatusCode)
+	assert.Equal(t, resp1.Header.Get(HeaderRateLimitReset), resp3.Header.Get(HeaderRateLimitReset))
+	assert.NotEmpty(t, resp3.Header.Get("Retry-After"))
+
+	var body RateLimitExceededResponse
+	require.NoError(t, json.NewDecoder(resp3.Body).Decode(&body))
+	assert.Equal(t, "Too many requests", body.Message)
+	assert.Equal(t, int64(0), body.Remaining)
+	assert.LessOrEqual(t, body.RetryAfter, 60)
+}
+
+func TestNewRateLimiter_StandardHeaders(t *testing.T) {
+	newApp := func() *fiber.App {
+		app := fiber.New()
+		app.Use(NewRateLimiter(RateLimiterConfig{
+			Max:        1,
+			Expiration: time.Minute,
+			KeyFunc: func(c fiber.Ctx) string {
+				return "standard_headers_test"
+			},
+		}))
+		app.Get("/test", func(c fiber.Ctx) error {
+			return c.SendString("OK")
+		})
+		return app
+	}
+
+	check := func(t *testing.T, app *fiber.App) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/test", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, "1", resp.Header.Get(HeaderStandardRateLimitLimit))
+		assert.Equal(t, "0", resp.Header.Get(HeaderStandardRateLimitRemaining))
+		reset, err := strconv.Atoi(resp.Header.Get(HeaderStandardRateLimitReset))
+		require.NoError(t, err)
+		assert.InDelta(t, 60, reset, 2, "reset is in seconds from now")
+
+		resp, err = app.Test(httptest.NewRequest("GET", "/test", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 429, resp.StatusCode)
+		assert.Equal(t, "0", resp.Header.Get(HeaderStandardRateLimitRemaining))
+		assert.Equal(t, resp.Header.Get("Retry-After"), resp.Header.Get(HeaderStandardRateLimitReset))
+	}
+
+	t.Run("local limiter", func(t *testing.T) {
+		check(t, newApp())
+	})
+
+	t.Run("shared store", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore(time.Minute)
+		defer func() { _ = store.Close() }()
+		SetRateLimitStore(store)
+		defer SetRateLimitStore(nil)
+
+		check(t, newApp())
+	})
+}
+
+func TestNewRateLimiter_Algorithm(t *testing.T) {
+	store := ratelimit.NewMemoryStore(time.Minute)
+	defer func() { _ = store.Close() }()
+	SetRateLimitStore(store)
+	defer SetRateLimitStore(nil)
+	SetRateLimitAlgorithm(ratelimit.AlgorithmTokenBucket)
+	defer SetRateLimitAlgorithm("")
+
+	app := fiber.New()
+	app.Use(NewRateLimiter(RateLimiterConfig{
+		Max:        2,
+		Expiration: time.Minute,
+		KeyFunc: func(c fiber.Ctx) string {
+			return "algorithm_test"
+		},
+	}))
+	app.Get("/test", func(c fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+
+	for i := 0; i < 2; i++ {
+		resp, err := app.Test(httptest.NewRequest("GET", "/test", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+	}
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/test", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 429, resp.StatusCode)
+	// The next token is available after half the window, not the whole window
+	assert.Equal(t, "30", resp.Header.Get("Retry-After"))
+}
+
 // =============================================================================
 // Preset Limiter Tests
 // =============================================================================
@@ -186,11 +360,54 @@ func TestAdminSetupLimiter(t *testing.T) {
 	assert.NotNil(t, limiter)
 }
 
+func TestClientKeyEnrollLimiter(t *testing.T) {
+	limiter := ClientKeyEnrollLimiter()
+	assert.NotNil(t, limiter)
+}
+
 func TestAdminLoginLimiter(t *testing.T) {
 	limiter := AdminLoginLimiter()
 	assert.NotNil(t, limiter)
 }
 
+func TestTableExportLimiterWithConfig(t *testing.T) {
+	t.Run("limits exports per user", func(t *testing.T) {
+		app := fiber.New()
+		app.Use(func(c fiber.Ctx) error {
+			c.Locals("user_id", c.Get("X-User"))
+			return c.Next()
+		})
+		app.Get("/export", TableExportLimiterWithConfig(1, time.Minute), func(c fiber.Ctx) error {
+			return c.SendString("ok")
+		})
+
+		request := func(user string) int {
+			req := httptest.NewRequest("GET", "/export", nil)
+			req.Header.Set("X-User", user)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			return resp.StatusCode
+		}
+
+		assert.Equal(t, fiber.StatusOK, request("alice"))
+		assert.Equal(t, fiber.StatusTooManyRequests, request("alice"))
+		assert.Equal(t, fiber.StatusOK, request("bob"))
+	})
+
+	t.Run("zero disables the limit", func(t *testing.T) {
+		app := fiber.New()
+		app.Get("/export", TableExportLimiterWithConfig(0, time.Minute), func(c fiber.Ctx) error {
+			return c.SendString("ok")
+		})
+
+		for range 3 {
+			resp, err := app.Test(httptest.NewRequest("GET", "/export", nil))
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		}
+	})
+}
+
 func TestGitHubWebhookLimiter(t *testing.T) {
 	limiter := GitHubWebhookLimiter()
 	assert.NotNil(t, limiter)
@@ -271,6 +488,44 @@ func TestPerUserOrIPLimiter_DifferentLimits(t *testing.T) {
 	}
 }
 
+func TestPerUserOrIPLimiter_LimitsPerDimension(t *testing.T) {
+	app := fiber.New()
+	app.Use(func(c fiber.Ctx) error {
+		if id := c.Get("X-Client-Key-ID"); id != "" {
+			c.Locals("client_key_id", id)
+		}
+		if id := c.Get("X-User-ID"); id != "" {
+			c.Locals("user_id", id)
+		}
+		return c.Next()
+	})
+	app.Use(PerUserOrIPLimiter(1, 2, 3, time.Minute))
+	app.Get("/test", func(c fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+
+	allowed := func(header, value string) int {
+		n := 0
+		for i := 0; i < 5; i++ {
+			req := httptest.NewRequest("GET", "/test", nil)
+			if header != "" {
+				req.Header.Set(header, value)
+			}
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			if resp.StatusCode == 200 {
+				n++
+			}
+		}
+		return n
+	}
+
+	assert.Equal(t, 1, allowed("", ""), "anonymous limit")
+	assert.Equal(t, 2, allowed("X-User-ID", "user-1"), "user limit")
+	assert.Equal(t, 2, allowed("X-User-ID", "user-2"), "users are counted separately")
+	assert.Equal(t, 3, allowed("X-Client-Key-ID", "key-1"), "client key limit")
+}
+
 // =============================================================================
 // SetRateLimiterMetrics Tests
 // =============================================================================
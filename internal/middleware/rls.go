@@ -3,11 +3,14 @@ package middleware
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/nimbleflux/fluxbase/internal/auth"
 	"github.com/nimbleflux/fluxbase/internal/database"
 	"github.com/rs/zerolog/log"
@@ -19,6 +22,10 @@ func quoteIdentifier(identifier string) string {
 	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
 }
 
+// ErrSnapshotUnavailable is returned when an exported snapshot can no longer be imported,
+// typically because the transaction that exported it has ended
+var ErrSnapshotUnavailable = errors.New("snapshot is no longer available")
+
 // RLSConfig holds configuration for RLS middleware
 type RLSConfig struct {
 	// DB is the database connection pool
@@ -181,6 +188,11 @@ func SetRLSContext(ctx context.Context, tx pgx.Tx, userID string, role string, c
 		if claims.IsAnonymous {
 			jwtClaims["is_anonymous"] = claims.IsAnonymous
 		}
+		if claims.OrgID != "" {
+			// Read by auth.current_org_id() to scope rows to the active organization
+			jwtClaims["org_id"] = claims.OrgID
+			jwtClaims["org_role"] = claims.OrgRole
+		}
 	}
 
 	// Marshal to JSON
@@ -245,20 +257,94 @@ func WrapWithServiceRole(ctx context.Context, conn *database.Connection, fn func
 // This is a helper function for setting RLS context in queries
 // If a branch pool is set in context (by BranchContext middleware), it uses that pool instead
 func WrapWithRLS(ctx context.Context, conn *database.Connection, c fiber.Ctx, fn func(tx pgx.Tx) error) error {
-	// Check for branch pool in context (set by BranchContext middleware)
-	pool := GetBranchPool(c)
-	if pool == nil {
-		// Fall back to main connection pool
-		pool = conn.Pool()
+	return wrapWithRLS(ctx, ConnectionPool(conn, c), c, "", fn)
+}
+
+// WrapWithRLSRead is WrapWithRLS for read-only queries that may see slightly stale data. They
+// run on a healthy read replica when there is one; a replica that cannot start the transaction
+// is taken out of rotation and the query runs on the primary instead. Branch requests always
+// use their branch pool.
+func WrapWithRLSRead(ctx context.Context, conn *database.Connection, c fiber.Ctx, fn func(tx pgx.Tx) error) error {
+	if GetBranchPool(c) == nil {
+		if pool := conn.ReadPool(); pool != conn.Pool() {
+			err := wrapWithRLS(ctx, pool, c, "", fn)
+			if !errors.Is(err, errBeginTx) {
+				return err
+			}
+			conn.ReplicaFailed(pool, err)
+			log.Warn().Err(err).Str("path", c.Path()).Msg("Read replica unavailable, falling back to primary")
+		}
+	}
+	return WrapWithRLS(ctx, conn, c, fn)
+}
+
+// WrapWithRLSSnapshot is WrapWithRLS in a read-only repeatable-read transaction that
+// imports an exported snapshot (pg_export_snapshot), so repeated reads see the same data.
+// The transaction that exported the snapshot must still be open.
+func WrapWithRLSSnapshot(ctx context.Context, conn *database.Connection, c fiber.Ctx, snapshotID string, fn func(tx pgx.Tx) error) error {
+	if !snapshotIDPattern.MatchString(snapshotID) {
+		return fmt.Errorf("invalid snapshot id %q", snapshotID)
+	}
+	return wrapWithRLS(ctx, ConnectionPool(conn, c), c, snapshotID, fn)
+}
+
+// BeginWithRLS starts a transaction with the RLS context of the request, for work that outlives
+// the handler, such as a response streamed after it returns. The caller must commit or roll back.
+func BeginWithRLS(ctx context.Context, conn *database.Connection, c fiber.Ctx) (pgx.Tx, error) {
+	tx, err := ConnectionPool(conn, c).Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	rls := GetRLSContext(c)
+	var userID string
+	if rls.UserID != nil {
+		userID = fmt.Sprintf("%v", rls.UserID)
+	}
+	claims, _ := c.Locals("jwt_claims").(*auth.TokenClaims)
+
+	if err := SetRLSContext(ctx, tx, userID, rls.Role, claims); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+	return tx, nil
+}
+
+// snapshotIDPattern matches identifiers returned by pg_export_snapshot(), e.g. 00000003-0000001B-1
+var snapshotIDPattern = regexp.MustCompile(`^[0-9A-F]+-[0-9A-F]+-[0-9]+$`)
+
+// ConnectionPool returns the pool a request should use: the branch pool set by the
+// BranchContext middleware, or the main pool
+func ConnectionPool(conn *database.Connection, c fiber.Ctx) *pgxpool.Pool {
+	if pool := GetBranchPool(c); pool != nil {
+		return pool
 	}
+	return conn.Pool()
+}
+
+// errBeginTx wraps failures to start the transaction of wrapWithRLS
+var errBeginTx = errors.New("failed to begin transaction")
 
+func wrapWithRLS(ctx context.Context, pool *pgxpool.Pool, c fiber.Ctx, snapshotID string, fn func(tx pgx.Tx) error) error {
 	// Start transaction
-	tx, err := pool.Begin(ctx)
+	txOptions := pgx.TxOptions{}
+	if snapshotID != "" {
+		txOptions = pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}
+	}
+	tx, err := pool.BeginTx(ctx, txOptions)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return fmt.Errorf("%w: %w", errBeginTx, err)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
+	// SET TRANSACTION SNAPSHOT must run before any query; it does not accept parameters,
+	// so the id is validated against snapshotIDPattern instead
+	if snapshotID != "" {
+		if _, err := tx.Exec(ctx, fmt.Sprintf("SET TRANSACTION SNAPSHOT '%s'", snapshotID)); err != nil {
+			return fmt.Errorf("%w: %v", ErrSnapshotUnavailable, err)
+		}
+	}
+
 	// Set RLS context from Fiber context
 	userID := c.Locals("rls_user_id")
 	role := c.Locals("rls_role")
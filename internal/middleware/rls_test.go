@@ -1,11 +1,13 @@
 package middleware
 
 import (
+	"context"
 	"io"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -521,3 +523,27 @@ func BenchmarkRLSMiddleware(b *testing.B) {
 		_, _ = app.Test(req)
 	}
 }
+
+func TestSnapshotIDPattern(t *testing.T) {
+	for _, id := range []string{"00000003-0000001B-1", "0000000A-00000002-12"} {
+		assert.True(t, snapshotIDPattern.MatchString(id), id)
+	}
+	for _, id := range []string{"", "abc", "00000003-0000001B-1'; DROP TABLE x; --", "00000003-0000001b-1"} {
+		assert.False(t, snapshotIDPattern.MatchString(id), id)
+	}
+}
+
+func TestWrapWithRLSSnapshot_RejectsInvalidID(t *testing.T) {
+	app := fiber.New()
+	var err error
+	app.Get("/", func(c fiber.Ctx) error {
+		err = WrapWithRLSSnapshot(context.Background(), nil, c, "1'; SELECT 1; --", func(tx pgx.Tx) error { return nil })
+		return nil
+	})
+	resp, testErr := app.Test(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, testErr)
+	_ = resp.Body.Close()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid snapshot id")
+}
@@ -1,7 +1,14 @@
 package middleware
 
 import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+
 	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
+	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/rs/zerolog/log"
 )
 
 // SecurityHeadersConfig holds configuration for security headers
@@ -53,55 +60,60 @@ func SecurityHeaders(config ...SecurityHeadersConfig) fiber.Handler {
 	}
 
 	return func(c fiber.Ctx) error {
-		// Content Security Policy
-		if cfg.ContentSecurityPolicy != "" {
-			c.Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
-		}
-
-		// X-Frame-Options
-		if cfg.XFrameOptions != "" {
-			c.Set("X-Frame-Options", cfg.XFrameOptions)
-		}
+		setSecurityHeaders(c, cfg)
+		return c.Next()
+	}
+}
 
-		// X-Content-Type-Options
-		if cfg.XContentTypeOptions != "" {
-			c.Set("X-Content-Type-Options", cfg.XContentTypeOptions)
-		}
+// setSecurityHeaders writes the non-empty headers of cfg to the response
+func setSecurityHeaders(c fiber.Ctx, cfg SecurityHeadersConfig) {
+	// Content Security Policy
+	if cfg.ContentSecurityPolicy != "" {
+		c.Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
+	}
 
-		// X-XSS-Protection
-		if cfg.XXSSProtection != "" {
-			c.Set("X-XSS-Protection", cfg.XXSSProtection)
-		}
+	// X-Frame-Options
+	if cfg.XFrameOptions != "" {
+		c.Set("X-Frame-Options", cfg.XFrameOptions)
+	}
 
-		// Strict-Transport-Security (only on HTTPS)
-		if cfg.StrictTransportSecurity != "" && c.Protocol() == "https" {
-			c.Set("Strict-Transport-Security", cfg.StrictTransportSecurity)
-		}
+	// X-Content-Type-Options
+	if cfg.XContentTypeOptions != "" {
+		c.Set("X-Content-Type-Options", cfg.XContentTypeOptions)
+	}
 
-		// Referrer-Policy
-		if cfg.ReferrerPolicy != "" {
-			c.Set("Referrer-Policy", cfg.ReferrerPolicy)
-		}
+	// X-XSS-Protection
+	if cfg.XXSSProtection != "" {
+		c.Set("X-XSS-Protection", cfg.XXSSProtection)
+	}
 
-		// Permissions-Policy
-		if cfg.PermissionsPolicy != "" {
-			c.Set("Permissions-Policy", cfg.PermissionsPolicy)
-		}
+	// Strict-Transport-Security (only on HTTPS)
+	if cfg.StrictTransportSecurity != "" && c.Protocol() == "https" {
+		c.Set("Strict-Transport-Security", cfg.StrictTransportSecurity)
+	}
 
-		// Remove server header to avoid information disclosure
-		c.Set("Server", "")
+	// Referrer-Policy
+	if cfg.ReferrerPolicy != "" {
+		c.Set("Referrer-Policy", cfg.ReferrerPolicy)
+	}
 
-		return c.Next()
+	// Permissions-Policy
+	if cfg.PermissionsPolicy != "" {
+		c.Set("Permissions-Policy", cfg.PermissionsPolicy)
 	}
+
+	// Remove server header to avoid information disclosure
+	c.Set("Server", "")
 }
 
-// AdminUISecurityHeaders returns relaxed security headers for Admin UI
-// Admin UI needs 'unsafe-inline' and 'unsafe-eval' for React
+// AdminUISecurityHeadersConfig returns relaxed security headers for Admin UI
+// Admin UI needs 'unsafe-eval' and inline styles for React; the injected runtime
+// config script is allowed through a per-request nonce instead of 'unsafe-inline'
 // Also allows Google Fonts from googleapis.com and gstatic.com
-func AdminUISecurityHeaders() fiber.Handler {
-	cfg := SecurityHeadersConfig{
+func AdminUISecurityHeadersConfig() SecurityHeadersConfig {
+	return SecurityHeadersConfig{
 		ContentSecurityPolicy: "default-src 'self'; " +
-			"script-src 'self' 'unsafe-inline' 'unsafe-eval' https://cdn.jsdelivr.net; " +
+			"script-src 'self' 'nonce-" + CSPNoncePlaceholder + "' 'unsafe-eval' https://cdn.jsdelivr.net; " +
 			"style-src 'self' 'unsafe-inline' https://fonts.googleapis.com https://cdn.jsdelivr.net; " +
 			"img-src 'self' data: blob: https:; " +
 			"font-src 'self' data: https://fonts.gstatic.com; " +
@@ -113,6 +125,147 @@ func AdminUISecurityHeaders() fiber.Handler {
 		XXSSProtection:      "1; mode=block",
 		ReferrerPolicy:      "strict-origin-when-cross-origin",
 	}
+}
+
+// AdminUISecurityHeaders returns a middleware applying AdminUISecurityHeadersConfig
+func AdminUISecurityHeaders() fiber.Handler {
+	return PolicySecurityHeaders(SecurityHeadersPolicy{Default: AdminUISecurityHeadersConfig()}, nil)
+}
+
+// CSPNoncePlaceholder is replaced with a fresh per-request nonce wherever it appears in a
+// Content-Security-Policy, e.g. "script-src 'self' 'nonce-{nonce}'". The nonce is stored in
+// c.Locals("csp_nonce") so handlers can add it to the inline scripts they render.
+const CSPNoncePlaceholder = "{nonce}"
+
+// Settings registry keys that override the configured security headers at runtime.
+// Empty values fall back to the static configuration.
+const (
+	SettingSecurityHeadersCSP               = "app.security.headers.content_security_policy"
+	SettingSecurityHeadersDashboardCSP      = "app.security.headers.dashboard_content_security_policy"
+	SettingSecurityHeadersXFrameOptions     = "app.security.headers.x_frame_options"
+	SettingSecurityHeadersHSTS              = "app.security.headers.strict_transport_security"
+	SettingSecurityHeadersReferrerPolicy    = "app.security.headers.referrer_policy"
+	SettingSecurityHeadersPermissionsPolicy = "app.security.headers.permissions_policy"
+)
+
+// SecurityHeadersRoute overrides security headers for requests under a path prefix
+type SecurityHeadersRoute struct {
+	// PathPrefix selects the requests this route applies to; the longest matching prefix wins
+	PathPrefix string
+	// Headers replace the default headers field by field; empty fields keep the default
+	Headers SecurityHeadersConfig
+	// CSPSettingKey optionally names a settings registry key whose value replaces Headers.ContentSecurityPolicy
+	CSPSettingKey string
+}
+
+// SecurityHeadersPolicy is the full set of security headers: defaults plus per-route overrides
+type SecurityHeadersPolicy struct {
+	Default SecurityHeadersConfig
+	Routes  []SecurityHeadersRoute
+}
+
+// Merge returns cfg with every non-empty field of override applied
+func (cfg SecurityHeadersConfig) Merge(override SecurityHeadersConfig) SecurityHeadersConfig {
+	if override.ContentSecurityPolicy != "" {
+		cfg.ContentSecurityPolicy = override.ContentSecurityPolicy
+	}
+	if override.XFrameOptions != "" {
+		cfg.XFrameOptions = override.XFrameOptions
+	}
+	if override.XContentTypeOptions != "" {
+		cfg.XContentTypeOptions = override.XContentTypeOptions
+	}
+	if override.XXSSProtection != "" {
+		cfg.XXSSProtection = override.XXSSProtection
+	}
+	if override.StrictTransportSecurity != "" {
+		cfg.StrictTransportSecurity = override.StrictTransportSecurity
+	}
+	if override.ReferrerPolicy != "" {
+		cfg.ReferrerPolicy = override.ReferrerPolicy
+	}
+	if override.PermissionsPolicy != "" {
+		cfg.PermissionsPolicy = override.PermissionsPolicy
+	}
+	return cfg
+}
+
+// route returns the most specific route matching path, or nil
+func (p SecurityHeadersPolicy) route(path string) *SecurityHeadersRoute {
+	var match *SecurityHeadersRoute
+	for i := range p.Routes {
+		r := &p.Routes[i]
+		if r.PathPrefix == "" || !strings.HasPrefix(path, r.PathPrefix) {
+			continue
+		}
+		if match == nil || len(r.PathPrefix) > len(match.PathPrefix) {
+			match = r
+		}
+	}
+	return match
+}
+
+// ForPath resolves the headers for a request path
+func (p SecurityHeadersPolicy) ForPath(path string) SecurityHeadersConfig {
+	if r := p.route(path); r != nil {
+		return p.Default.Merge(r.Headers)
+	}
+	return p.Default
+}
+
+// PolicySecurityHeaders returns a middleware that applies a SecurityHeadersPolicy.
+// When settingsCache is non-nil, values from the settings registry take precedence over the
+// static policy so headers can be tuned from the dashboard without a restart.
+func PolicySecurityHeaders(policy SecurityHeadersPolicy, settingsCache *auth.SettingsCache) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		cfg := resolveSecurityHeaders(c, policy, settingsCache)
+
+		if strings.Contains(cfg.ContentSecurityPolicy, CSPNoncePlaceholder) {
+			nonce, err := generateCSPNonce()
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to generate CSP nonce")
+				return apierror.Send(c, fiber.StatusInternalServerError, apierror.CodeInternalError, "Internal server error")
+			}
+			c.Locals("csp_nonce", nonce)
+			cfg.ContentSecurityPolicy = strings.ReplaceAll(cfg.ContentSecurityPolicy, CSPNoncePlaceholder, nonce)
+		}
+
+		setSecurityHeaders(c, cfg)
+		return c.Next()
+	}
+}
+
+// resolveSecurityHeaders merges the settings registry overrides into the policy for the request path
+func resolveSecurityHeaders(c fiber.Ctx, policy SecurityHeadersPolicy, settingsCache *auth.SettingsCache) SecurityHeadersConfig {
+	if settingsCache == nil {
+		return policy.ForPath(c.Path())
+	}
 
-	return SecurityHeaders(cfg)
+	ctx := c.RequestCtx()
+	cfg := policy.Default.Merge(SecurityHeadersConfig{
+		ContentSecurityPolicy:   settingsCache.GetString(ctx, SettingSecurityHeadersCSP, ""),
+		XFrameOptions:           settingsCache.GetString(ctx, SettingSecurityHeadersXFrameOptions, ""),
+		StrictTransportSecurity: settingsCache.GetString(ctx, SettingSecurityHeadersHSTS, ""),
+		ReferrerPolicy:          settingsCache.GetString(ctx, SettingSecurityHeadersReferrerPolicy, ""),
+		PermissionsPolicy:       settingsCache.GetString(ctx, SettingSecurityHeadersPermissionsPolicy, ""),
+	})
+
+	if r := policy.route(c.Path()); r != nil {
+		cfg = cfg.Merge(r.Headers)
+		if r.CSPSettingKey != "" {
+			if csp := settingsCache.GetString(ctx, r.CSPSettingKey, ""); csp != "" {
+				cfg.ContentSecurityPolicy = csp
+			}
+		}
+	}
+	return cfg
+}
+
+// generateCSPNonce returns a random base64 nonce suitable for a CSP 'nonce-...' source
+func generateCSPNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
 }
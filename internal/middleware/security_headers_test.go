@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"io"
 	"net/http/httptest"
 	"testing"
 
@@ -136,6 +137,8 @@ func TestAdminUISecurityHeaders(t *testing.T) {
 	assert.Contains(t, csp, "'unsafe-eval'")
 	assert.Contains(t, csp, "fonts.googleapis.com")
 	assert.Contains(t, csp, "fonts.gstatic.com")
+	assert.NotContains(t, csp, CSPNoncePlaceholder)
+	assert.Regexp(t, `script-src 'self' 'nonce-[A-Za-z0-9+/=]+'`, csp)
 
 	// But still have basic security headers
 	assert.Equal(t, "DENY", resp.Header.Get("X-Frame-Options"))
@@ -161,3 +164,84 @@ func TestSecurityHeadersConfig_AllFields(t *testing.T) {
 	assert.Equal(t, "test-referrer", cfg.ReferrerPolicy)
 	assert.Equal(t, "test-permissions", cfg.PermissionsPolicy)
 }
+
+func TestSecurityHeadersConfig_Merge(t *testing.T) {
+	base := DefaultSecurityHeadersConfig()
+	merged := base.Merge(SecurityHeadersConfig{XFrameOptions: "SAMEORIGIN"})
+
+	assert.Equal(t, "SAMEORIGIN", merged.XFrameOptions)
+	assert.Equal(t, base.ContentSecurityPolicy, merged.ContentSecurityPolicy)
+	assert.Equal(t, base.ReferrerPolicy, merged.ReferrerPolicy)
+}
+
+func TestSecurityHeadersPolicy_ForPath(t *testing.T) {
+	policy := SecurityHeadersPolicy{
+		Default: DefaultSecurityHeadersConfig(),
+		Routes: []SecurityHeadersRoute{
+			{PathPrefix: "/admin", Headers: SecurityHeadersConfig{ContentSecurityPolicy: "admin-csp"}},
+			{PathPrefix: "/admin/embed", Headers: SecurityHeadersConfig{XFrameOptions: "SAMEORIGIN"}},
+			{PathPrefix: "/storage", Headers: SecurityHeadersConfig{ContentSecurityPolicy: "sandbox"}},
+		},
+	}
+
+	t.Run("default for unmatched path", func(t *testing.T) {
+		assert.Equal(t, policy.Default, policy.ForPath("/api/v1/tables"))
+	})
+
+	t.Run("route override keeps other defaults", func(t *testing.T) {
+		cfg := policy.ForPath("/admin/tables")
+		assert.Equal(t, "admin-csp", cfg.ContentSecurityPolicy)
+		assert.Equal(t, "DENY", cfg.XFrameOptions)
+	})
+
+	t.Run("longest prefix wins", func(t *testing.T) {
+		cfg := policy.ForPath("/admin/embed/chart")
+		assert.Equal(t, "SAMEORIGIN", cfg.XFrameOptions)
+		assert.Equal(t, policy.Default.ContentSecurityPolicy, cfg.ContentSecurityPolicy)
+	})
+}
+
+func TestPolicySecurityHeaders(t *testing.T) {
+	policy := SecurityHeadersPolicy{
+		Default: DefaultSecurityHeadersConfig(),
+		Routes: []SecurityHeadersRoute{
+			{PathPrefix: "/admin", Headers: SecurityHeadersConfig{ContentSecurityPolicy: "script-src 'nonce-{nonce}'"}},
+		},
+	}
+
+	app := fiber.New()
+	app.Use(PolicySecurityHeaders(policy, nil))
+	app.Get("/*", func(c fiber.Ctx) error {
+		nonce, _ := c.Locals("csp_nonce").(string)
+		return c.SendString(nonce)
+	})
+
+	t.Run("API route has no nonce", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/test", nil))
+		require.NoError(t, err)
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.Empty(t, body)
+		assert.Equal(t, policy.Default.ContentSecurityPolicy, resp.Header.Get("Content-Security-Policy"))
+	})
+
+	t.Run("nonce is substituted and exposed to handlers", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/admin", nil))
+		require.NoError(t, err)
+
+		body, _ := io.ReadAll(resp.Body)
+		nonce := string(body)
+		require.NotEmpty(t, nonce)
+		assert.Equal(t, "script-src 'nonce-"+nonce+"'", resp.Header.Get("Content-Security-Policy"))
+		assert.Equal(t, "DENY", resp.Header.Get("X-Frame-Options"))
+	})
+
+	t.Run("nonce changes per request", func(t *testing.T) {
+		resp1, err := app.Test(httptest.NewRequest("GET", "/admin", nil))
+		require.NoError(t, err)
+		resp2, err := app.Test(httptest.NewRequest("GET", "/admin", nil))
+		require.NoError(t, err)
+
+		assert.NotEqual(t, resp1.Header.Get("Content-Security-Policy"), resp2.Header.Get("Content-Security-Policy"))
+	})
+}
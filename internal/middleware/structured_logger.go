@@ -163,8 +163,10 @@ func StructuredLogger(config ...StructuredLoggerConfig) fiber.Handler {
 			logEvent = logEvent.Str("client_key_id", toString(clientKeyID))
 		}
 
-		// Add response size
-		logEvent = logEvent.Int("response_bytes", len(c.Response().Body()))
+		// Add response size (unknown for streamed bodies, reading them would buffer the whole response)
+		if !c.Response().IsBodyStream() {
+			logEvent = logEvent.Int("response_bytes", len(c.Response().Body()))
+		}
 
 		// Add referer if present
 		if referer := c.Get("Referer"); referer != "" {
@@ -5,6 +5,7 @@ import (
 	"net"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/nimbleflux/fluxbase/internal/apierror"
 	"github.com/nimbleflux/fluxbase/internal/config"
 	"github.com/rs/zerolog/log"
 )
@@ -50,8 +51,6 @@ func RequireSyncIPAllowlist(allowedRanges []string, featureName string, serverCf
 			Str("path", c.Path()).
 			Msgf("%s sync access denied - IP not in allowlist", featureName)
 
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": fmt.Sprintf("Access denied - IP not allowlisted for %s sync", featureName),
-		})
+		return apierror.Send(c, fiber.StatusForbidden, apierror.CodeAccessDenied, fmt.Sprintf("Access denied - IP not allowlisted for %s sync", featureName))
 	}
 }
@@ -42,7 +42,29 @@ func DefaultTracingConfig() TracingConfig {
 	}
 }
 
-// TracingMiddleware returns a Fiber middleware that creates spans for HTTP requests
+// currentSpanKey is the context key OpenTelemetry keeps the current span under. The key is
+// unexported, so it is found by asking which key trace.SpanFromContext looks up.
+var currentSpanKey = func() any {
+	probe := &contextKeyProbe{Context: context.Background()}
+	trace.SpanFromContext(probe)
+	return probe.key
+}()
+
+// contextKeyProbe is a context that remembers the last key it was asked for
+type contextKeyProbe struct {
+	context.Context
+	key any
+}
+
+func (p *contextKeyProbe) Value(key any) any {
+	p.key = key
+	return nil
+}
+
+// TracingMiddleware returns a Fiber middleware that creates spans for HTTP requests. Handlers
+// pass c.RequestCtx() to the services they call, so the span is also stored in its user values
+// (which RequestCtx.Value reads): database queries and AI calls then become child spans of the
+// request.
 func TracingMiddleware(cfg TracingConfig) fiber.Handler {
 	if !cfg.Enabled {
 		return func(c fiber.Ctx) error {
@@ -98,6 +120,8 @@ func TracingMiddleware(cfg TracingConfig) fiber.Handler {
 		// Store trace context in Fiber locals for downstream use
 		c.Locals("trace_ctx", ctx)
 		c.Locals("trace_span", span)
+		c.SetContext(ctx)
+		c.RequestCtx().SetUserValue(currentSpanKey, span)
 
 		// Add trace ID to response headers for debugging
 		if span.SpanContext().HasTraceID() {
@@ -110,17 +134,26 @@ func TracingMiddleware(cfg TracingConfig) fiber.Handler {
 		}
 
 		// Process the request
+		ownRoute := c.FullPath()
 		err := c.Next()
 
+		// Name the span after the route that handled the request, which is only known now
+		if route := c.FullPath(); route != "" && route != ownRoute {
+			span.SetName(fmt.Sprintf("%s %s", c.Method(), route))
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+
 		// Record response attributes
+		// Streamed bodies are skipped: reading them would buffer the whole response
 		statusCode := c.Response().StatusCode()
-		span.SetAttributes(
-			attribute.Int("http.response.status_code", statusCode),
-			attribute.Int("http.response_size", len(c.Response().Body())),
-		)
+		span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+		streamed := c.Response().IsBodyStream()
+		if !streamed {
+			span.SetAttributes(attribute.Int("http.response_size", len(c.Response().Body())))
+		}
 
 		// Record response body if configured
-		if cfg.RecordResponseBody && len(c.Response().Body()) > 0 && len(c.Response().Body()) < 4096 {
+		if cfg.RecordResponseBody && !streamed && len(c.Response().Body()) > 0 && len(c.Response().Body()) < 4096 {
 			span.SetAttributes(attribute.String("http.response.body", string(c.Response().Body())))
 		}
 
@@ -8,6 +8,9 @@ import (
 	"github.com/gofiber/fiber/v3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // =============================================================================
@@ -409,6 +412,33 @@ func TestTracingMiddleware_RequestLifecycle(t *testing.T) {
 	})
 }
 
+func TestTracingMiddleware_PropagatesSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	app := fiber.New()
+	app.Use(TracingMiddleware(DefaultTracingConfig()))
+	app.Get("/api/items/:id", func(c fiber.Ctx) error {
+		// Services are called with the request context, as handlers do
+		_, span := otel.Tracer("test").Start(c.RequestCtx(), "query")
+		span.End()
+		return c.SendString("ok")
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/items/42", nil))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	child, request := spans[0], spans[1]
+	assert.Equal(t, "GET /api/items/:id", request.Name())
+	assert.Equal(t, request.SpanContext().SpanID(), child.Parent().SpanID())
+	assert.Equal(t, request.SpanContext().TraceID(), child.SpanContext().TraceID())
+}
+
 // =============================================================================
 // TracingMiddleware User Context Tests
 // =============================================================================
@@ -25,12 +25,26 @@ func NewExecutor(db *database.Connection) *Executor {
 	}
 }
 
-// ApplyMigration applies a single migration
+// ApplyResult describes the destructive changes of an applied migration and the backups taken before it ran
+type ApplyResult struct {
+	DestructiveChanges []DestructiveChange `json:"destructive_changes,omitempty"`
+	Backups            []string            `json:"backups,omitempty"`
+}
+
+// ApplyMigration applies a single migration, refusing destructive changes
 func (e *Executor) ApplyMigration(ctx context.Context, namespace, name string, executedBy *uuid.UUID) error {
+	_, err := e.ApplyMigrationWithOptions(ctx, namespace, name, executedBy, ApplyOptions{})
+	return err
+}
+
+// ApplyMigrationWithOptions applies a single migration. Migrations that drop tables or columns, truncate
+// tables or narrow column types return a *DestructiveMigrationError unless opts allows or confirms the
+// changes; the affected tables are then backed up in the same transaction before the migration runs.
+func (e *Executor) ApplyMigrationWithOptions(ctx context.Context, namespace, name string, executedBy *uuid.UUID, opts ApplyOptions) (*ApplyResult, error) {
 	// Get migration
 	migration, err := e.storage.GetMigration(ctx, namespace, name)
 	if err != nil {
-		return fmt.Errorf("failed to get migration: %w", err)
+		return nil, fmt.Errorf("failed to get migration: %w", err)
 	}
 
 	// Check if already applied
@@ -39,12 +53,12 @@ func (e *Executor) ApplyMigration(ctx context.Context, namespace, name string, e
 			Str("namespace", namespace).
 			Str("name", name).
 			Msg("Migration already applied, skipping")
-		return nil
+		return &ApplyResult{}, nil
 	}
 
 	// Check if status allows application
 	if migration.Status != "pending" && migration.Status != "failed" {
-		return fmt.Errorf("migration status is %s, cannot apply", migration.Status)
+		return nil, fmt.Errorf("migration status is %s, cannot apply", migration.Status)
 	}
 
 	log.Info().
@@ -54,10 +68,33 @@ func (e *Executor) ApplyMigration(ctx context.Context, namespace, name string, e
 
 	startTime := time.Now()
 	var executionLog *ExecutionLog
+	result := &ApplyResult{}
+	candidates := DetectDestructiveChanges(migration.UpSQL)
 
 	// Execute migration in transaction with admin credentials
 	// Migrations require DDL privileges (CREATE TABLE, ALTER, etc.)
 	err = e.db.ExecuteWithAdminRole(ctx, func(conn *pgx.Conn) error {
+		if len(candidates) > 0 {
+			changes, err := resolveDestructiveChanges(ctx, conn, candidates)
+			if err != nil {
+				return err
+			}
+			if len(changes) > 0 {
+				token := destructiveConfirmationToken(migration, changes)
+				if !opts.AllowDestructive && opts.ConfirmationToken != token {
+					return &DestructiveMigrationError{Migration: name, Changes: changes, ConfirmationToken: token}
+				}
+
+				// Back up affected tables in the same transaction, so a failed migration leaves no backups behind
+				backups, err := backupTables(ctx, conn, changes, startTime)
+				if err != nil {
+					return err
+				}
+				result.DestructiveChanges = changes
+				result.Backups = backups
+			}
+		}
+
 		// Execute the up SQL
 		_, err := conn.Exec(ctx, migration.UpSQL)
 		return err
@@ -65,6 +102,16 @@ func (e *Executor) ApplyMigration(ctx context.Context, namespace, name string, e
 
 	durationMs := int(time.Since(startTime).Milliseconds())
 
+	// Unconfirmed destructive changes leave the migration untouched
+	if destructiveErr, ok := IsDestructiveMigrationError(err); ok {
+		log.Warn().
+			Str("namespace", namespace).
+			Str("name", name).
+			Int("changes", len(destructiveErr.Changes)).
+			Msg("Migration has unconfirmed destructive changes, not applying")
+		return nil, destructiveErr
+	}
+
 	if err != nil {
 		// Migration failed
 		log.Error().
@@ -90,7 +137,7 @@ func (e *Executor) ApplyMigration(ctx context.Context, namespace, name string, e
 		// Update migration status to failed
 		_ = e.storage.UpdateMigrationStatus(ctx, migration.ID, "failed", executedBy)
 
-		return fmt.Errorf("migration failed: %w", err)
+		return nil, fmt.Errorf("migration failed: %w", err)
 	}
 
 	// Migration succeeded
@@ -98,14 +145,16 @@ func (e *Executor) ApplyMigration(ctx context.Context, namespace, name string, e
 		Str("namespace", namespace).
 		Str("name", name).
 		Int("duration_ms", durationMs).
+		Strs("backups", result.Backups).
 		Msg("Migration applied successfully")
 
 	executionLog = &ExecutionLog{
-		MigrationID: migration.ID,
-		Action:      "apply",
-		Status:      "success",
-		DurationMs:  &durationMs,
-		ExecutedBy:  executedBy,
+		MigrationID:  migration.ID,
+		Action:       "apply",
+		Status:       "success",
+		DurationMs:   &durationMs,
+		ExecutedBy:   executedBy,
+		BackupTables: result.Backups,
 	}
 
 	// Log execution
@@ -115,10 +164,10 @@ func (e *Executor) ApplyMigration(ctx context.Context, namespace, name string, e
 
 	// Update migration status to applied
 	if err := e.storage.UpdateMigrationStatus(ctx, migration.ID, "applied", executedBy); err != nil {
-		return fmt.Errorf("failed to update migration status: %w", err)
+		return nil, fmt.Errorf("failed to update migration status: %w", err)
 	}
 
-	return nil
+	return result, nil
 }
 
 // RollbackMigration rolls back a single migration
@@ -210,8 +259,14 @@ func (e *Executor) RollbackMigration(ctx context.Context, namespace, name string
 	return nil
 }
 
-// ApplyPendingMigrations applies all pending migrations in a namespace in order
+// ApplyPendingMigrations applies all pending migrations in a namespace in order, stopping at destructive changes
 func (e *Executor) ApplyPendingMigrations(ctx context.Context, namespace string, executedBy *uuid.UUID) ([]string, []string, error) {
+	return e.ApplyPendingMigrationsWithOptions(ctx, namespace, executedBy, ApplyOptions{})
+}
+
+// ApplyPendingMigrationsWithOptions applies all pending migrations in a namespace in order.
+// Confirmation tokens are per migration, so only opts.AllowDestructive applies here.
+func (e *Executor) ApplyPendingMigrationsWithOptions(ctx context.Context, namespace string, executedBy *uuid.UUID, opts ApplyOptions) ([]string, []string, error) {
 	// Get pending migrations
 	pendingStatus := "pending"
 	migrations, err := e.storage.ListMigrations(ctx, namespace, &pendingStatus)
@@ -234,7 +289,7 @@ func (e *Executor) ApplyPendingMigrations(ctx context.Context, namespace string,
 
 	// Apply migrations in order (sorted by name in ListMigrations)
 	for _, migration := range migrations {
-		err := e.ApplyMigration(ctx, namespace, migration.Name, executedBy)
+		_, err := e.ApplyMigrationWithOptions(ctx, namespace, migration.Name, executedBy, ApplyOptions{AllowDestructive: opts.AllowDestructive})
 		if err != nil {
 			log.Error().
 				Err(err).
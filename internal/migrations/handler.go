@@ -185,7 +185,9 @@ func (h *Handler) ApplyMigration(c fiber.Ctx) error {
 	name := c.Params("name")
 
 	var req struct {
-		Namespace string `json:"namespace"`
+		Namespace         string `json:"namespace"`
+		AllowDestructive  bool   `json:"allow_destructive"`
+		ConfirmationToken string `json:"confirmation_token"`
 	}
 	if err := c.Bind().Body(&req); err != nil {
 		req.Namespace = "default"
@@ -205,7 +207,12 @@ func (h *Handler) ApplyMigration(c fiber.Ctx) error {
 		}
 	}
 
-	if err := h.executor.ApplyMigration(c.RequestCtx(), req.Namespace, name, executedBy); err != nil {
+	opts := ApplyOptions{AllowDestructive: req.AllowDestructive, ConfirmationToken: req.ConfirmationToken}
+	result, err := h.executor.ApplyMigrationWithOptions(c.RequestCtx(), req.Namespace, name, executedBy, opts)
+	if destructiveErr, ok := IsDestructiveMigrationError(err); ok {
+		return destructiveChangesResponse(c, destructiveErr)
+	}
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to apply migration", "details": err.Error()})
 	}
 
@@ -215,7 +222,25 @@ func (h *Handler) ApplyMigration(c fiber.Ctx) error {
 		log.Debug().Str("migration", name).Msg("Schema cache invalidated after applying migration")
 	}
 
-	return c.JSON(fiber.Map{"message": "Migration applied successfully"})
+	response := fiber.Map{"message": "Migration applied successfully"}
+	if len(result.DestructiveChanges) > 0 {
+		response["destructive_changes"] = result.DestructiveChanges
+		response["backups"] = result.Backups
+	}
+	return c.JSON(response)
+}
+
+// destructiveChangesResponse asks the caller to confirm a migration's destructive changes.
+// Repeating the request with the returned confirmation_token (or allow_destructive) applies it.
+func destructiveChangesResponse(c fiber.Ctx, err *DestructiveMigrationError) error {
+	return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+		"error":              "Migration contains destructive changes",
+		"code":               "DESTRUCTIVE_MIGRATION",
+		"migration":          err.Migration,
+		"changes":            err.Changes,
+		"confirmation_token": err.ConfirmationToken,
+		"hint":               "Affected tables are backed up before the migration runs. Repeat the request with confirmation_token to apply it.",
+	})
 }
 
 // RollbackMigration rolls back a migration
@@ -259,7 +284,8 @@ func (h *Handler) RollbackMigration(c fiber.Ctx) error {
 // ApplyPending applies all pending migrations in order
 func (h *Handler) ApplyPending(c fiber.Ctx) error {
 	var req struct {
-		Namespace string `json:"namespace"`
+		Namespace        string `json:"namespace"`
+		AllowDestructive bool   `json:"allow_destructive"`
 	}
 	if err := c.Bind().Body(&req); err != nil {
 		req.Namespace = "default"
@@ -279,7 +305,21 @@ func (h *Handler) ApplyPending(c fiber.Ctx) error {
 		}
 	}
 
-	applied, failed, err := h.executor.ApplyPendingMigrations(c.RequestCtx(), req.Namespace, executedBy)
+	applied, failed, err := h.executor.ApplyPendingMigrationsWithOptions(c.RequestCtx(), req.Namespace, executedBy,
+		ApplyOptions{AllowDestructive: req.AllowDestructive})
+	if destructiveErr, ok := IsDestructiveMigrationError(err); ok {
+		if len(applied) > 0 && h.schemaCache != nil {
+			h.schemaCache.InvalidateAll(c.RequestCtx())
+		}
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":     "Migration contains destructive changes",
+			"code":      "DESTRUCTIVE_MIGRATION",
+			"migration": destructiveErr.Migration,
+			"changes":   destructiveErr.Changes,
+			"applied":   applied,
+			"hint":      "Apply the migration on its own to confirm the changes, or repeat the request with allow_destructive.",
+		})
+	}
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error":   "Failed to apply pending migrations",
@@ -336,9 +376,10 @@ func (h *Handler) SyncMigrations(c fiber.Ctx) error {
 			DownSQL     *string `json:"down_sql"`
 		} `json:"migrations"`
 		Options struct {
-			UpdateIfChanged bool `json:"update_if_changed"`
-			AutoApply       bool `json:"auto_apply"`
-			DryRun          bool `json:"dry_run"`
+			UpdateIfChanged  bool `json:"update_if_changed"`
+			AutoApply        bool `json:"auto_apply"`
+			DryRun           bool `json:"dry_run"`
+			AllowDestructive bool `json:"allow_destructive"`
 		} `json:"options"`
 	}
 
@@ -394,6 +435,18 @@ func (h *Handler) SyncMigrations(c fiber.Ctx) error {
 
 	warnings := []string{}
 
+	// Destructive migrations stop the sync unless allowed; their backups are reported in the response
+	var backups []string
+	applyMigration := func(name string) error {
+		result, err := h.executor.ApplyMigrationWithOptions(c.RequestCtx(), req.Namespace, name, createdBy,
+			ApplyOptions{AllowDestructive: req.Options.AllowDestructive})
+		if err != nil {
+			return err
+		}
+		backups = append(backups, result.Backups...)
+		return nil
+	}
+
 	// Track if any migration failed during auto-apply
 	// Migrations must be applied sequentially - stop on first failure
 	autoApplyFailed := false
@@ -435,7 +488,7 @@ func (h *Handler) SyncMigrations(c fiber.Ctx) error {
 
 			// Auto-apply if requested
 			if req.Options.AutoApply && !req.Options.DryRun {
-				if err := h.executor.ApplyMigration(c.RequestCtx(), req.Namespace, reqMig.Name, createdBy); err != nil {
+				if err := applyMigration(reqMig.Name); err != nil {
 					log.Error().Err(err).Str("name", reqMig.Name).Msg("Failed to auto-apply migration")
 					summary.Errors++
 					details.Errors = append(details.Errors, fmt.Sprintf("%s: failed to apply - %v", reqMig.Name, err))
@@ -460,7 +513,7 @@ func (h *Handler) SyncMigrations(c fiber.Ctx) error {
 					action = "Retrying"
 				}
 				log.Info().Str("name", reqMig.Name).Str("status", existingMig.Status).Msg(action + " migration")
-				if err := h.executor.ApplyMigration(c.RequestCtx(), req.Namespace, reqMig.Name, createdBy); err != nil {
+				if err := applyMigration(reqMig.Name); err != nil {
 					log.Error().Err(err).Str("name", reqMig.Name).Msg("Failed to apply migration")
 					summary.Errors++
 					details.Errors = append(details.Errors, fmt.Sprintf("%s: failed to apply - %v", reqMig.Name, err))
@@ -532,7 +585,7 @@ func (h *Handler) SyncMigrations(c fiber.Ctx) error {
 			// Auto-apply if requested
 			if req.Options.AutoApply && !req.Options.DryRun {
 				log.Info().Str("name", reqMig.Name).Msg("Retrying updated failed migration")
-				if err := h.executor.ApplyMigration(c.RequestCtx(), req.Namespace, reqMig.Name, createdBy); err != nil {
+				if err := applyMigration(reqMig.Name); err != nil {
 					log.Error().Err(err).Str("name", reqMig.Name).Msg("Failed to apply updated migration")
 					summary.Errors++
 					details.Errors = append(details.Errors, fmt.Sprintf("%s: failed to apply after update - %v", reqMig.Name, err))
@@ -574,6 +627,9 @@ func (h *Handler) SyncMigrations(c fiber.Ctx) error {
 	if len(warnings) > 0 {
 		response["warnings"] = warnings
 	}
+	if len(backups) > 0 {
+		response["backups"] = backups
+	}
 
 	// Return appropriate status code
 	// 422 Unprocessable Entity if there were errors (partial failure in batch operation)
@@ -0,0 +1,396 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	pg_query "github.com/pganalyze/pg_query_go/v6"
+)
+
+// Kinds of destructive change detected in migration SQL
+const (
+	ChangeDropTable    = "drop_table"
+	ChangeDropColumn   = "drop_column"
+	ChangeNarrowType   = "narrow_type"
+	ChangeTruncate     = "truncate"
+	ChangeUnanalyzable = "unanalyzable"
+)
+
+// backupSchema holds the copies of tables taken before destructive migrations.
+// It is hidden from the REST API and only the service role can read it.
+const backupSchema = "migrations"
+
+// DestructiveChange is a statement in a migration that can lose existing data
+type DestructiveChange struct {
+	Kind   string `json:"kind"`
+	Schema string `json:"schema,omitempty"`
+	Table  string `json:"table,omitempty"`
+	Column string `json:"column,omitempty"`
+	// Detail describes the change, e.g. the old and new type of a narrowed column
+	Detail string `json:"detail,omitempty"`
+
+	// newType is the target type of an ALTER COLUMN ... TYPE, compared to the current type before applying
+	newType columnType
+}
+
+// ApplyOptions controls how destructive migrations are applied
+type ApplyOptions struct {
+	// AllowDestructive applies migrations with destructive changes without a confirmation token
+	AllowDestructive bool
+	// ConfirmationToken confirms the destructive changes reported by a previous attempt
+	ConfirmationToken string
+}
+
+// DestructiveMigrationError is returned when a migration has destructive changes that were not allowed
+type DestructiveMigrationError struct {
+	Migration         string
+	Changes           []DestructiveChange
+	ConfirmationToken string
+}
+
+func (e *DestructiveMigrationError) Error() string {
+	kinds := make([]string, 0, len(e.Changes))
+	for _, change := range e.Changes {
+		kinds = append(kinds, change.String())
+	}
+	return fmt.Sprintf("migration %s contains destructive changes (%s); confirm them or allow destructive changes to apply it",
+		e.Migration, strings.Join(kinds, ", "))
+}
+
+// IsDestructiveMigrationError reports whether err was caused by unconfirmed destructive changes
+func IsDestructiveMigrationError(err error) (*DestructiveMigrationError, bool) {
+	var destructiveErr *DestructiveMigrationError
+	ok := errors.As(err, &destructiveErr)
+	return destructiveErr, ok
+}
+
+// String formats the change for error messages
+func (c DestructiveChange) String() string {
+	target := c.qualifiedTable()
+	if c.Column != "" {
+		target += "." + c.Column
+	}
+	if target == "" {
+		return c.Kind
+	}
+	return c.Kind + " " + target
+}
+
+func (c DestructiveChange) qualifiedTable() string {
+	if c.Schema == "" {
+		return c.Table
+	}
+	return c.Schema + "." + c.Table
+}
+
+// DetectDestructiveChanges parses migration SQL and returns the statements that can lose data.
+// Type changes are returned as candidates; whether they narrow the column depends on its current
+// type, which resolveDestructiveChanges checks against the database.
+func DetectDestructiveChanges(sql string) []DestructiveChange {
+	tree, err := pg_query.Parse(sql)
+	if err != nil {
+		// Statements that cannot be inspected are treated as destructive so they still need confirmation
+		return []DestructiveChange{{Kind: ChangeUnanalyzable, Detail: err.Error()}}
+	}
+
+	var changes []DestructiveChange
+	for _, raw := range tree.GetStmts() {
+		stmt := raw.GetStmt()
+		switch {
+		case stmt.GetDropStmt() != nil:
+			drop := stmt.GetDropStmt()
+			if drop.GetRemoveType() != pg_query.ObjectType_OBJECT_TABLE {
+				continue
+			}
+			for _, object := range drop.GetObjects() {
+				schema, table := qualifiedName(object.GetList().GetItems())
+				changes = append(changes, DestructiveChange{Kind: ChangeDropTable, Schema: schema, Table: table})
+			}
+
+		case stmt.GetTruncateStmt() != nil:
+			for _, relation := range stmt.GetTruncateStmt().GetRelations() {
+				rv := relation.GetRangeVar()
+				changes = append(changes, DestructiveChange{Kind: ChangeTruncate, Schema: rv.GetSchemaname(), Table: rv.GetRelname()})
+			}
+
+		case stmt.GetAlterTableStmt() != nil:
+			alter := stmt.GetAlterTableStmt()
+			if alter.GetObjtype() != pg_query.ObjectType_OBJECT_TABLE {
+				continue
+			}
+			rv := alter.GetRelation()
+			for _, node := range alter.GetCmds() {
+				cmd := node.GetAlterTableCmd()
+				switch cmd.GetSubtype() {
+				case pg_query.AlterTableType_AT_DropColumn:
+					changes = append(changes, DestructiveChange{
+						Kind: ChangeDropColumn, Schema: rv.GetSchemaname(), Table: rv.GetRelname(), Column: cmd.GetName(),
+					})
+				case pg_query.AlterTableType_AT_AlterColumnType:
+					changes = append(changes, DestructiveChange{
+						Kind: ChangeNarrowType, Schema: rv.GetSchemaname(), Table: rv.GetRelname(), Column: cmd.GetName(),
+						newType: typeNameToColumnType(cmd.GetDef().GetColumnDef().GetTypeName()),
+					})
+				}
+			}
+		}
+	}
+	return changes
+}
+
+// qualifiedName splits a parsed [schema.]name list
+func qualifiedName(items []*pg_query.Node) (string, string) {
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		parts = append(parts, item.GetString_().GetSval())
+	}
+	switch len(parts) {
+	case 0:
+		return "", ""
+	case 1:
+		return "", parts[0]
+	default:
+		return parts[len(parts)-2], parts[len(parts)-1]
+	}
+}
+
+// columnType is a column type in the form Postgres' format_type() reports it, e.g. "character varying" with modifiers [50]
+type columnType struct {
+	Base      string
+	Modifiers []int
+	Array     bool
+}
+
+func (t columnType) String() string {
+	s := t.Base
+	if len(t.Modifiers) > 0 {
+		mods := make([]string, len(t.Modifiers))
+		for i, mod := range t.Modifiers {
+			mods[i] = strconv.Itoa(mod)
+		}
+		s += "(" + strings.Join(mods, ",") + ")"
+	}
+	if t.Array {
+		s += "[]"
+	}
+	return s
+}
+
+// typeAliases maps the parser's internal type names to the names format_type() uses
+var typeAliases = map[string]string{
+	"int2":        "smallint",
+	"int4":        "integer",
+	"int8":        "bigint",
+	"float4":      "real",
+	"float8":      "double precision",
+	"bool":        "boolean",
+	"varchar":     "character varying",
+	"bpchar":      "character",
+	"decimal":     "numeric",
+	"timestamp":   "timestamp without time zone",
+	"timestamptz": "timestamp with time zone",
+	"time":        "time without time zone",
+	"timetz":      "time with time zone",
+	"varbit":      "bit varying",
+}
+
+func typeNameToColumnType(typeName *pg_query.TypeName) columnType {
+	names := typeName.GetNames()
+	if len(names) == 0 {
+		return columnType{}
+	}
+	base := names[len(names)-1].GetString_().GetSval()
+	if alias, ok := typeAliases[base]; ok {
+		base = alias
+	}
+
+	t := columnType{Base: base, Array: len(typeName.GetArrayBounds()) > 0}
+	for _, mod := range typeName.GetTypmods() {
+		if ival := mod.GetAConst().GetIval(); ival != nil {
+			t.Modifiers = append(t.Modifiers, int(ival.GetIval()))
+		}
+	}
+	return t
+}
+
+// parseFormattedType parses the output of format_type(), e.g. "numeric(10,2)" or "character varying(50)[]"
+func parseFormattedType(s string) columnType {
+	t := columnType{}
+	s, t.Array = strings.CutSuffix(s, "[]")
+	base, mods, hasMods := strings.Cut(s, "(")
+	t.Base = strings.TrimSpace(base)
+	if hasMods {
+		mods, rest, _ := strings.Cut(mods, ")")
+		for _, mod := range strings.Split(mods, ",") {
+			if n, err := strconv.Atoi(strings.TrimSpace(mod)); err == nil {
+				t.Modifiers = append(t.Modifiers, n)
+			}
+		}
+		// Time types put the precision before the zone, e.g. "timestamp(3) with time zone"
+		t.Base = strings.TrimSpace(t.Base + rest)
+	}
+	return t
+}
+
+// integerWidths ranks integer types by range
+var integerWidths = map[string]int{"smallint": 1, "integer": 2, "bigint": 3}
+
+// isNarrowingTypeChange reports whether converting a column from one type to another can lose data.
+// Only well-known widening conversions are treated as safe.
+func isNarrowingTypeChange(from, to columnType) bool {
+	if from.String() == to.String() {
+		return false
+	}
+	if to.Base == "text" && !to.Array {
+		return false // every value has a text representation
+	}
+	if from.Array != to.Array {
+		return true
+	}
+
+	switch {
+	case integerWidths[from.Base] > 0 && integerWidths[to.Base] > 0:
+		return integerWidths[to.Base] < integerWidths[from.Base]
+	case integerWidths[from.Base] > 0 && to.Base == "numeric":
+		return len(to.Modifiers) > 0
+	case from.Base == "real" && to.Base == "double precision":
+		return false
+	case from.Base == "numeric" && to.Base == "numeric":
+		if len(to.Modifiers) == 0 {
+			return false
+		}
+		if len(from.Modifiers) == 0 {
+			return true
+		}
+		fromScale, toScale := modifier(from, 1), modifier(to, 1)
+		return toScale < fromScale || to.Modifiers[0]-toScale < from.Modifiers[0]-fromScale
+	case (from.Base == "character varying" || from.Base == "character") && to.Base == "character varying":
+		if len(to.Modifiers) == 0 {
+			return false
+		}
+		return len(from.Modifiers) == 0 || to.Modifiers[0] < from.Modifiers[0]
+	}
+	return true
+}
+
+func modifier(t columnType, i int) int {
+	if i < len(t.Modifiers) {
+		return t.Modifiers[i]
+	}
+	return 0
+}
+
+// resolveDestructiveChanges checks candidate changes against the database before the migration runs.
+// Changes to tables or columns that do not exist yet (e.g. created earlier in the same migration) and
+// type changes that only widen a column are dropped. Table names are resolved to their schema.
+func resolveDestructiveChanges(ctx context.Context, conn *pgx.Conn, changes []DestructiveChange) ([]DestructiveChange, error) {
+	resolved := make([]DestructiveChange, 0, len(changes))
+	for _, change := range changes {
+		if change.Kind == ChangeUnanalyzable {
+			resolved = append(resolved, change)
+			continue
+		}
+
+		var schema, table string
+		err := conn.QueryRow(ctx, `
+			SELECT n.nspname, c.relname
+			FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE c.oid = to_regclass($1)`,
+			pgx.Identifier(nonEmpty(change.Schema, change.Table)).Sanitize(),
+		).Scan(&schema, &table)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up table %s: %w", change.qualifiedTable(), err)
+		}
+		change.Schema, change.Table = schema, table
+
+		if change.Column != "" {
+			var currentType string
+			err := conn.QueryRow(ctx, `
+				SELECT format_type(a.atttypid, a.atttypmod)
+				FROM pg_attribute a
+				WHERE a.attrelid = to_regclass($1) AND a.attname = $2 AND a.attnum > 0 AND NOT a.attisdropped`,
+				pgx.Identifier{schema, table}.Sanitize(), change.Column,
+			).Scan(&currentType)
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up column %s.%s: %w", change.qualifiedTable(), change.Column, err)
+			}
+
+			if change.Kind == ChangeNarrowType {
+				from := parseFormattedType(currentType)
+				if !isNarrowingTypeChange(from, change.newType) {
+					continue
+				}
+				change.Detail = fmt.Sprintf("%s to %s", from, change.newType)
+			}
+		}
+
+		resolved = append(resolved, change)
+	}
+	return resolved, nil
+}
+
+func nonEmpty(parts ...string) []string {
+	out := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// destructiveConfirmationToken identifies a migration's SQL together with the changes reported for it,
+// so a confirmation only applies to the exact changes the caller was shown
+func destructiveConfirmationToken(migration *Migration, changes []DestructiveChange) string {
+	described := make([]string, len(changes))
+	for i, change := range changes {
+		described[i] = change.String() + ":" + change.Detail
+	}
+	sort.Strings(described)
+	return calculateHash(migration.ID.String() + "\n" + migration.UpSQL + "\n" + strings.Join(described, "\n"))[:32]
+}
+
+// backupTableName names the backup copy of a table, keeping within Postgres' 63 byte identifier limit
+func backupTableName(schema, table string, at time.Time) string {
+	name := fmt.Sprintf("backup_%s_%s", at.UTC().Format("20060102150405"), schema+"_"+table)
+	if len(name) <= 63 {
+		return name
+	}
+	// Keep the name unique by replacing the tail with a hash of the full name
+	return name[:54] + "_" + calculateHash(name)[:8]
+}
+
+// backupTables copies every table touched by changes into the backup schema and returns the backup names
+func backupTables(ctx context.Context, conn *pgx.Conn, changes []DestructiveChange, at time.Time) ([]string, error) {
+	seen := make(map[string]bool)
+	var backups []string
+	for _, change := range changes {
+		if change.Table == "" || seen[change.qualifiedTable()] {
+			continue
+		}
+		seen[change.qualifiedTable()] = true
+
+		backup := backupTableName(change.Schema, change.Table, at)
+		query := fmt.Sprintf("CREATE TABLE %s AS TABLE %s",
+			pgx.Identifier{backupSchema, backup}.Sanitize(),
+			pgx.Identifier{change.Schema, change.Table}.Sanitize(),
+		)
+		if _, err := conn.Exec(ctx, query); err != nil {
+			return nil, fmt.Errorf("failed to back up %s: %w", change.qualifiedTable(), err)
+		}
+		backups = append(backups, backupSchema+"."+backup)
+	}
+	return backups, nil
+}
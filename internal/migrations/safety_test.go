@@ -0,0 +1,140 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectDestructiveChanges(t *testing.T) {
+	t.Run("finds drops, truncates and type changes", func(t *testing.T) {
+		changes := DetectDestructiveChanges(`
+			CREATE TABLE audit (id serial PRIMARY KEY);
+			DROP TABLE IF EXISTS legacy_orders, archive.events CASCADE;
+			ALTER TABLE public.users DROP COLUMN nickname, ALTER COLUMN bio TYPE varchar(200);
+			TRUNCATE sessions;
+			DROP INDEX idx_users_email;
+		`)
+
+		require.Len(t, changes, 5)
+		assert.Equal(t, DestructiveChange{Kind: ChangeDropTable, Table: "legacy_orders"}, changes[0])
+		assert.Equal(t, DestructiveChange{Kind: ChangeDropTable, Schema: "archive", Table: "events"}, changes[1])
+		assert.Equal(t, DestructiveChange{Kind: ChangeDropColumn, Schema: "public", Table: "users", Column: "nickname"}, changes[2])
+		assert.Equal(t, ChangeNarrowType, changes[3].Kind)
+		assert.Equal(t, "bio", changes[3].Column)
+		assert.Equal(t, "character varying(200)", changes[3].newType.String())
+		assert.Equal(t, DestructiveChange{Kind: ChangeTruncate, Table: "sessions"}, changes[4])
+	})
+
+	t.Run("additive migrations have no destructive changes", func(t *testing.T) {
+		changes := DetectDestructiveChanges(`
+			CREATE TABLE posts (id uuid PRIMARY KEY, title text NOT NULL);
+			ALTER TABLE posts ADD COLUMN body text;
+			ALTER TABLE posts RENAME COLUMN title TO heading;
+			CREATE INDEX idx_posts_heading ON posts (heading);
+		`)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("unparsable SQL needs confirmation", func(t *testing.T) {
+		changes := DetectDestructiveChanges("DROP TABEL users")
+		require.Len(t, changes, 1)
+		assert.Equal(t, ChangeUnanalyzable, changes[0].Kind)
+	})
+}
+
+func TestIsNarrowingTypeChange(t *testing.T) {
+	tests := []struct {
+		from, to  string
+		narrowing bool
+	}{
+		{"integer", "bigint", false},
+		{"bigint", "integer", true},
+		{"smallint", "numeric", false},
+		{"integer", "numeric(5,0)", true},
+		{"real", "double precision", false},
+		{"double precision", "real", true},
+		{"numeric(10,2)", "numeric(12,2)", false},
+		{"numeric(10,2)", "numeric(10,4)", true},
+		{"numeric(10,2)", "numeric", false},
+		{"numeric", "numeric(10,2)", true},
+		{"character varying(50)", "character varying(100)", false},
+		{"character varying(50)", "character varying(20)", true},
+		{"character varying(50)", "character varying", false},
+		{"character varying", "character varying(50)", true},
+		{"character(5)", "character varying(10)", false},
+		{"jsonb", "text", false},
+		{"text", "character varying(100)", true},
+		{"text", "jsonb", true},
+		{"integer[]", "bigint[]", false},
+		{"integer[]", "integer", true},
+		{"uuid", "uuid", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			assert.Equal(t, tt.narrowing, isNarrowingTypeChange(parseFormattedType(tt.from), parseFormattedType(tt.to)))
+		})
+	}
+}
+
+func TestParseFormattedType(t *testing.T) {
+	assert.Equal(t, columnType{Base: "numeric", Modifiers: []int{10, 2}}, parseFormattedType("numeric(10,2)"))
+	assert.Equal(t, columnType{Base: "character varying", Modifiers: []int{50}, Array: true}, parseFormattedType("character varying(50)[]"))
+	assert.Equal(t, columnType{Base: "timestamp with time zone", Modifiers: []int{3}}, parseFormattedType("timestamp(3) with time zone"))
+
+	// Parsed migration types use the same names as format_type()
+	changes := DetectDestructiveChanges("ALTER TABLE t ALTER COLUMN a TYPE int8, ALTER COLUMN b TYPE decimal(8,2), ALTER COLUMN c TYPE timestamptz")
+	require.Len(t, changes, 3)
+	assert.Equal(t, "bigint", changes[0].newType.String())
+	assert.Equal(t, "numeric(8,2)", changes[1].newType.String())
+	assert.Equal(t, "timestamp with time zone", changes[2].newType.String())
+}
+
+func TestDestructiveConfirmationToken(t *testing.T) {
+	migration := &Migration{ID: uuid.New(), UpSQL: "DROP TABLE legacy"}
+	changes := []DestructiveChange{{Kind: ChangeDropTable, Schema: "public", Table: "legacy"}}
+
+	token := destructiveConfirmationToken(migration, changes)
+	assert.Len(t, token, 32)
+	assert.Equal(t, token, destructiveConfirmationToken(migration, changes))
+
+	edited := &Migration{ID: migration.ID, UpSQL: "DROP TABLE legacy CASCADE"}
+	assert.NotEqual(t, token, destructiveConfirmationToken(edited, changes))
+
+	moreChanges := append(changes, DestructiveChange{Kind: ChangeTruncate, Schema: "public", Table: "sessions"})
+	assert.NotEqual(t, token, destructiveConfirmationToken(migration, moreChanges))
+}
+
+func TestBackupTableName(t *testing.T) {
+	at := time.Date(2026, 3, 1, 10, 30, 0, 0, time.UTC)
+	assert.Equal(t, "backup_20260301103000_public_users", backupTableName("public", "users", at))
+
+	long := backupTableName("analytics", strings.Repeat("x", 60), at)
+	assert.Len(t, long, 63)
+	assert.NotEqual(t, long, backupTableName("analytics", strings.Repeat("x", 61), at))
+}
+
+func TestDestructiveMigrationError(t *testing.T) {
+	err := fmt.Errorf("apply: %w", &DestructiveMigrationError{
+		Migration: "004_cleanup",
+		Changes: []DestructiveChange{
+			{Kind: ChangeDropColumn, Schema: "public", Table: "users", Column: "nickname"},
+			{Kind: ChangeUnanalyzable},
+		},
+	})
+
+	destructiveErr, ok := IsDestructiveMigrationError(err)
+	require.True(t, ok)
+	assert.Equal(t, "004_cleanup", destructiveErr.Migration)
+	assert.Contains(t, err.Error(), "drop_column public.users.nickname, unanalyzable")
+
+	_, ok = IsDestructiveMigrationError(errors.New("syntax error"))
+	assert.False(t, ok)
+}
@@ -39,6 +39,7 @@ type ExecutionLog struct {
 	Logs         *string    `json:"logs"`
 	ExecutedAt   time.Time  `json:"executed_at"`
 	ExecutedBy   *uuid.UUID `json:"executed_by"`
+	BackupTables []string   `json:"backup_tables,omitempty"` // Copies of tables taken before destructive changes
 }
 
 // Storage handles database operations for migrations
@@ -248,15 +249,15 @@ func (s *Storage) UpdateMigrationStatus(ctx context.Context, id uuid.UUID, statu
 func (s *Storage) LogExecution(ctx context.Context, log *ExecutionLog) error {
 	query := `
 		INSERT INTO migrations.execution_logs (
-			migration_id, action, status, duration_ms, error_message, logs, executed_by
-		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+			migration_id, action, status, duration_ms, error_message, logs, executed_by, backup_tables
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, executed_at
 	`
 
 	err := database.WrapWithServiceRole(ctx, s.db, func(tx pgx.Tx) error {
 		return tx.QueryRow(ctx, query,
 			log.MigrationID, log.Action, log.Status, log.DurationMs,
-			log.ErrorMessage, log.Logs, log.ExecutedBy,
+			log.ErrorMessage, log.Logs, log.ExecutedBy, log.BackupTables,
 		).Scan(&log.ID, &log.ExecutedAt)
 	})
 	if err != nil {
@@ -269,7 +270,7 @@ func (s *Storage) LogExecution(ctx context.Context, log *ExecutionLog) error {
 // GetExecutionLogs returns execution logs for a migration
 func (s *Storage) GetExecutionLogs(ctx context.Context, migrationID uuid.UUID, limit int) ([]ExecutionLog, error) {
 	query := `
-		SELECT id, migration_id, action, status, duration_ms, error_message, logs, executed_at, executed_by
+		SELECT id, migration_id, action, status, duration_ms, error_message, logs, executed_at, executed_by, backup_tables
 		FROM migrations.execution_logs
 		WHERE migration_id = $1
 		ORDER BY executed_at DESC
@@ -289,7 +290,7 @@ func (s *Storage) GetExecutionLogs(ctx context.Context, migrationID uuid.UUID, l
 			err := rows.Scan(
 				&log.ID, &log.MigrationID, &log.Action, &log.Status,
 				&log.DurationMs, &log.ErrorMessage, &log.Logs,
-				&log.ExecutedAt, &log.ExecutedBy,
+				&log.ExecutedAt, &log.ExecutedBy, &log.BackupTables,
 			)
 			if err != nil {
 				return err
@@ -0,0 +1,337 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/email"
+	"github.com/rs/zerolog/log"
+)
+
+// emailDeliveryKey identifies the single email delivery notification
+const emailDeliveryKey = CategoryEmailDelivery
+
+// oauthSecret is the rotation date of an OAuth provider's client secret
+type oauthSecret struct {
+	Provider  string
+	ExpiresAt time.Time
+}
+
+// Checker periodically checks credentials and configuration and keeps the
+// notification center in sync. It must run on a single node (leader-elected).
+type Checker struct {
+	cfg   *config.NotificationsConfig
+	db    *pgxpool.Pool
+	store *Store
+	saml  *auth.SAMLService // nil when SAML is not configured
+	now   func() time.Time
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running bool
+	mu      sync.Mutex
+}
+
+// NewChecker creates a configuration checker. samlService may be nil.
+func NewChecker(cfg *config.NotificationsConfig, db *pgxpool.Pool, store *Store, samlService *auth.SAMLService) *Checker {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Checker{
+		cfg:    cfg,
+		db:     db,
+		store:  store,
+		saml:   samlService,
+		now:    time.Now,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start begins periodic checks
+func (c *Checker) Start() {
+	c.mu.Lock()
+	if c.running {
+		c.mu.Unlock()
+		return
+	}
+	c.running = true
+	if c.ctx.Err() != nil {
+		c.ctx, c.cancel = context.WithCancel(context.Background())
+	}
+	c.mu.Unlock()
+
+	c.wg.Add(1)
+	go c.run()
+
+	log.Info().
+		Dur("interval", c.cfg.CheckInterval).
+		Int("expiry_warning_days", c.cfg.ExpiryWarningDays).
+		Msg("Notification checker started")
+}
+
+// Stop stops periodic checks
+func (c *Checker) Stop() {
+	c.mu.Lock()
+	if !c.running {
+		c.mu.Unlock()
+		return
+	}
+	c.running = false
+	c.mu.Unlock()
+
+	c.cancel()
+	c.wg.Wait()
+
+	log.Info().Msg("Notification checker stopped")
+}
+
+// run checks on start and then every check interval
+func (c *Checker) run() {
+	defer c.wg.Done()
+
+	c.RunChecks(c.ctx)
+
+	ticker := time.NewTicker(c.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			c.RunChecks(c.ctx)
+		}
+	}
+}
+
+// RunChecks runs every check once. A check that fails leaves its category's
+// notifications untouched, so a transient error does not resolve them.
+func (c *Checker) RunChecks(ctx context.Context) {
+	now := c.now()
+
+	if c.saml != nil {
+		active := certificateNotifications(c.saml.Certificates(), now, c.cfg.ExpiryWarningDays)
+		c.sync(ctx, CategorySAMLCertificate, active)
+	}
+
+	if secrets, err := c.oauthSecrets(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to check OAuth client secret rotation dates")
+	} else {
+		c.sync(ctx, CategoryOAuthSecret, oauthSecretNotifications(secrets, now, c.cfg.ExpiryWarningDays))
+	}
+
+	// Without a quota the sync resolves notifications left over from a previous quota
+	var storageActive []Notification
+	if c.cfg.StorageQuotaBytes > 0 {
+		used, err := c.storageUsage(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to check storage usage")
+			return
+		}
+		if n := storageNotification(used, c.cfg.StorageQuotaBytes, c.cfg.StorageWarningPercent); n != nil {
+			storageActive = append(storageActive, *n)
+		}
+	}
+	c.sync(ctx, CategoryStorageQuota, storageActive)
+}
+
+func (c *Checker) sync(ctx context.Context, category string, active []Notification) {
+	if err := c.store.Sync(ctx, category, active); err != nil {
+		log.Error().Err(err).Str("category", category).Msg("Failed to update notifications")
+	}
+}
+
+// RecordEmailDelivery raises or resolves the email delivery notification.
+// It is registered as the email manager's delivery observer on every node.
+func (c *Checker) RecordEmailDelivery(status email.DeliveryStatus) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var err error
+	if n := emailDeliveryNotification(status, c.cfg.EmailFailureThreshold); n != nil {
+		err = c.store.Raise(ctx, *n)
+	} else if status.ConsecutiveFailures == 0 {
+		err = c.store.Resolve(ctx, emailDeliveryKey)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to update email delivery notification")
+	}
+}
+
+// oauthSecrets returns the client secret rotation dates of enabled OAuth providers
+func (c *Checker) oauthSecrets(ctx context.Context) ([]oauthSecret, error) {
+	rows, err := c.db.Query(ctx, `
+		SELECT provider_name, client_secret_expires_at
+		FROM dashboard.oauth_providers
+		WHERE enabled AND client_secret_expires_at IS NOT NULL
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var secrets []oauthSecret
+	for rows.Next() {
+		var s oauthSecret
+		if err := rows.Scan(&s.Provider, &s.ExpiresAt); err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, s)
+	}
+	return secrets, rows.Err()
+}
+
+// storageUsage returns the total size of stored objects in bytes
+func (c *Checker) storageUsage(ctx context.Context) (int64, error) {
+	var used int64
+	err := c.db.QueryRow(ctx, `SELECT COALESCE(SUM(size), 0)::BIGINT FROM storage.objects`).Scan(&used)
+	return used, err
+}
+
+// expirySeverity returns the severity of a credential expiring at expiresAt,
+// or false when it is outside the warning window
+func expirySeverity(expiresAt, now time.Time, warningDays int) (Severity, bool) {
+	switch {
+	case !expiresAt.After(now):
+		return SeverityCritical, true
+	case expiresAt.Sub(now) <= time.Duration(warningDays)*24*time.Hour:
+		return SeverityWarning, true
+	default:
+		return "", false
+	}
+}
+
+// describeExpiry renders "expired on <date>" or "expires in N days (<date>)"
+func describeExpiry(expiresAt, now time.Time) string {
+	date := expiresAt.UTC().Format("2006-01-02")
+	if !expiresAt.After(now) {
+		return "expired on " + date
+	}
+	days := int(expiresAt.Sub(now).Hours() / 24)
+	switch days {
+	case 0:
+		return fmt.Sprintf("expires today (%s)", date)
+	case 1:
+		return fmt.Sprintf("expires in 1 day (%s)", date)
+	default:
+		return fmt.Sprintf("expires in %d days (%s)", days, date)
+	}
+}
+
+// certificateNotifications flags SAML certificates that expired or expire within the warning window
+func certificateNotifications(certs []auth.SAMLCertificateInfo, now time.Time, warningDays int) []Notification {
+	var active []Notification
+	for _, cert := range certs {
+		severity, ok := expirySeverity(cert.NotAfter, now, warningDays)
+		if !ok {
+			continue
+		}
+
+		kind := "IdP signing certificate"
+		action := "Update the provider's certificate or metadata once the identity provider has rolled over its key."
+		if cert.Use == "sp_signing" {
+			kind = "SP signing certificate"
+			action = "Generate a new SP certificate and share the updated metadata with the identity provider."
+		}
+
+		active = append(active, Notification{
+			Key:      fmt.Sprintf("%s:%s:%s", CategorySAMLCertificate, cert.Provider, cert.Use),
+			Category: CategorySAMLCertificate,
+			Severity: severity,
+			Title:    fmt.Sprintf("SAML %s for %s %s", kind, cert.Provider, describeExpiry(cert.NotAfter, now)),
+			Message:  fmt.Sprintf("The %s of SAML provider '%s' (%s) %s. %s", kind, cert.Provider, cert.Subject, describeExpiry(cert.NotAfter, now), action),
+			Metadata: map[string]any{
+				"provider":  cert.Provider,
+				"use":       cert.Use,
+				"subject":   cert.Subject,
+				"not_after": cert.NotAfter.UTC(),
+			},
+		})
+	}
+	return active
+}
+
+// oauthSecretNotifications flags OAuth client secrets past or near their rotation date
+func oauthSecretNotifications(secrets []oauthSecret, now time.Time, warningDays int) []Notification {
+	var active []Notification
+	for _, secret := range secrets {
+		severity, ok := expirySeverity(secret.ExpiresAt, now, warningDays)
+		if !ok {
+			continue
+		}
+
+		active = append(active, Notification{
+			Key:      fmt.Sprintf("%s:%s", CategoryOAuthSecret, secret.Provider),
+			Category: CategoryOAuthSecret,
+			Severity: severity,
+			Title:    fmt.Sprintf("OAuth client secret for %s %s", secret.Provider, describeExpiry(secret.ExpiresAt, now)),
+			Message: fmt.Sprintf("The client secret of OAuth provider '%s' %s. Issue a new secret at the provider and update it in Fluxbase.",
+				secret.Provider, describeExpiry(secret.ExpiresAt, now)),
+			Metadata: map[string]any{
+				"provider":   secret.Provider,
+				"expires_at": secret.ExpiresAt.UTC(),
+			},
+		})
+	}
+	return active
+}
+
+// storageNotification flags storage usage at or above warningPercent of the quota
+func storageNotification(usedBytes, quotaBytes int64, warningPercent int) *Notification {
+	if quotaBytes <= 0 {
+		return nil
+	}
+
+	percent := float64(usedBytes) / float64(quotaBytes) * 100
+	if percent < float64(warningPercent) {
+		return nil
+	}
+
+	severity := SeverityWarning
+	title := fmt.Sprintf("Storage is %.0f%% full", percent)
+	if usedBytes >= quotaBytes {
+		severity = SeverityCritical
+		title = "Storage quota exceeded"
+	}
+
+	return &Notification{
+		Key:      CategoryStorageQuota,
+		Category: CategoryStorageQuota,
+		Severity: severity,
+		Title:    title,
+		Message: fmt.Sprintf("Stored objects use %d of %d bytes (%.1f%%). Delete unused files or raise notifications.storage_quota_bytes.",
+			usedBytes, quotaBytes, percent),
+		Metadata: map[string]any{
+			"used_bytes":  usedBytes,
+			"quota_bytes": quotaBytes,
+			"percent":     percent,
+		},
+	}
+}
+
+// emailDeliveryNotification flags repeated email delivery failures
+func emailDeliveryNotification(status email.DeliveryStatus, threshold int) *Notification {
+	if status.ConsecutiveFailures < threshold {
+		return nil
+	}
+
+	return &Notification{
+		Key:      emailDeliveryKey,
+		Category: CategoryEmailDelivery,
+		Severity: SeverityCritical,
+		Title:    "Email delivery is failing",
+		Message: fmt.Sprintf("The last %d emails could not be sent: %s. Check the email provider settings and credentials.",
+			status.ConsecutiveFailures, status.LastError),
+		Metadata: map[string]any{
+			"consecutive_failures": status.ConsecutiveFailures,
+			"last_error":           status.LastError,
+			"last_failure_at":      status.LastFailureAt.UTC(),
+		},
+	}
+}
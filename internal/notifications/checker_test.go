@@ -0,0 +1,118 @@
+package notifications
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nimbleflux/fluxbase/internal/auth"
+	"github.com/nimbleflux/fluxbase/internal/email"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var checkTime = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestExpirySeverity(t *testing.T) {
+	tests := []struct {
+		name      string
+		expiresAt time.Time
+		severity  Severity
+		flagged   bool
+	}{
+		{"already expired", checkTime.Add(-time.Hour), SeverityCritical, true},
+		{"expires now", checkTime, SeverityCritical, true},
+		{"inside warning window", checkTime.Add(10 * 24 * time.Hour), SeverityWarning, true},
+		{"at warning window edge", checkTime.Add(30 * 24 * time.Hour), SeverityWarning, true},
+		{"outside warning window", checkTime.Add(31 * 24 * time.Hour), "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			severity, flagged := expirySeverity(tt.expiresAt, checkTime, 30)
+			assert.Equal(t, tt.flagged, flagged)
+			assert.Equal(t, tt.severity, severity)
+		})
+	}
+}
+
+func TestDescribeExpiry(t *testing.T) {
+	assert.Equal(t, "expired on 2026-02-28", describeExpiry(checkTime.Add(-24*time.Hour), checkTime))
+	assert.Equal(t, "expires today (2026-03-01)", describeExpiry(checkTime.Add(time.Hour), checkTime))
+	assert.Equal(t, "expires in 1 day (2026-03-02)", describeExpiry(checkTime.Add(30*time.Hour), checkTime))
+	assert.Equal(t, "expires in 14 days (2026-03-15)", describeExpiry(checkTime.Add(14*24*time.Hour), checkTime))
+}
+
+func TestCertificateNotifications(t *testing.T) {
+	certs := []auth.SAMLCertificateInfo{
+		{Provider: "okta", Use: "idp_signing", Subject: "CN=okta", NotAfter: checkTime.Add(5 * 24 * time.Hour)},
+		{Provider: "okta", Use: "sp_signing", Subject: "CN=fluxbase", NotAfter: checkTime.Add(-time.Hour)},
+		{Provider: "azure", Use: "idp_signing", Subject: "CN=azure", NotAfter: checkTime.Add(365 * 24 * time.Hour)},
+	}
+
+	active := certificateNotifications(certs, checkTime, 30)
+	require.Len(t, active, 2)
+
+	assert.Equal(t, "saml_certificate:okta:idp_signing", active[0].Key)
+	assert.Equal(t, CategorySAMLCertificate, active[0].Category)
+	assert.Equal(t, SeverityWarning, active[0].Severity)
+	assert.Contains(t, active[0].Title, "expires in 5 days")
+
+	assert.Equal(t, "saml_certificate:okta:sp_signing", active[1].Key)
+	assert.Equal(t, SeverityCritical, active[1].Severity)
+	assert.Contains(t, active[1].Title, "SP signing certificate")
+	assert.Contains(t, active[1].Message, "expired on")
+}
+
+func TestOAuthSecretNotifications(t *testing.T) {
+	secrets := []oauthSecret{
+		{Provider: "google", ExpiresAt: checkTime.Add(20 * 24 * time.Hour)},
+		{Provider: "github", ExpiresAt: checkTime.Add(90 * 24 * time.Hour)},
+	}
+
+	active := oauthSecretNotifications(secrets, checkTime, 30)
+	require.Len(t, active, 1)
+	assert.Equal(t, "oauth_client_secret:google", active[0].Key)
+	assert.Equal(t, SeverityWarning, active[0].Severity)
+	assert.Equal(t, "google", active[0].Metadata["provider"])
+}
+
+func TestStorageNotification(t *testing.T) {
+	t.Run("no quota", func(t *testing.T) {
+		assert.Nil(t, storageNotification(100, 0, 80))
+	})
+
+	t.Run("below warning threshold", func(t *testing.T) {
+		assert.Nil(t, storageNotification(79, 100, 80))
+	})
+
+	t.Run("at warning threshold", func(t *testing.T) {
+		n := storageNotification(80, 100, 80)
+		require.NotNil(t, n)
+		assert.Equal(t, SeverityWarning, n.Severity)
+		assert.Equal(t, "Storage is 80% full", n.Title)
+		assert.Equal(t, CategoryStorageQuota, n.Key)
+	})
+
+	t.Run("over quota", func(t *testing.T) {
+		n := storageNotification(120, 100, 80)
+		require.NotNil(t, n)
+		assert.Equal(t, SeverityCritical, n.Severity)
+		assert.Equal(t, "Storage quota exceeded", n.Title)
+	})
+}
+
+func TestEmailDeliveryNotification(t *testing.T) {
+	status := email.DeliveryStatus{
+		ConsecutiveFailures: 2,
+		LastError:           "dial tcp: connection refused",
+		LastFailureAt:       checkTime,
+	}
+	assert.Nil(t, emailDeliveryNotification(status, 3))
+
+	status.ConsecutiveFailures = 3
+	n := emailDeliveryNotification(status, 3)
+	require.NotNil(t, n)
+	assert.Equal(t, CategoryEmailDelivery, n.Key)
+	assert.Equal(t, SeverityCritical, n.Severity)
+	assert.Contains(t, n.Message, "connection refused")
+}
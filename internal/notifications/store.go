@@ -0,0 +1,190 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Severity indicates how urgently a notification needs attention
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// Notification categories raised by the background checks
+const (
+	CategorySAMLCertificate = "saml_certificate"
+	CategoryOAuthSecret     = "oauth_client_secret"
+	CategoryEmailDelivery   = "email_delivery"
+	CategoryStorageQuota    = "storage_quota"
+)
+
+// Notification is one row of dashboard.notifications. Key identifies the
+// underlying condition, so raising it again updates the same notification.
+type Notification struct {
+	ID          string         `json:"id"`
+	Key         string         `json:"key"`
+	Category    string         `json:"category"`
+	Severity    Severity       `json:"severity"`
+	Title       string         `json:"title"`
+	Message     string         `json:"message"`
+	Metadata    map[string]any `json:"metadata"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	ResolvedAt  *time.Time     `json:"resolved_at,omitempty"`
+	DismissedAt *time.Time     `json:"dismissed_at,omitempty"`
+}
+
+// ListOptions filters notifications returned by List
+type ListOptions struct {
+	IncludeResolved  bool
+	IncludeDismissed bool
+	Limit            int
+}
+
+// Store persists notifications in dashboard.notifications
+type Store struct {
+	db *pgxpool.Pool
+}
+
+// NewStore creates a notification store
+func NewStore(db *pgxpool.Pool) *Store {
+	return &Store{db: db}
+}
+
+// Raise creates or refreshes the notification for n.Key.
+// A notification that was resolved, or that escalates to critical, is shown again even if it was dismissed.
+func (s *Store) Raise(ctx context.Context, n Notification) error {
+	metadata := []byte("{}")
+	if n.Metadata != nil {
+		var err error
+		if metadata, err = json.Marshal(n.Metadata); err != nil {
+			return fmt.Errorf("failed to encode notification metadata: %w", err)
+		}
+	}
+
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO dashboard.notifications (key, category, severity, title, message, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (key) DO UPDATE SET
+			category = EXCLUDED.category,
+			severity = EXCLUDED.severity,
+			title = EXCLUDED.title,
+			message = EXCLUDED.message,
+			metadata = EXCLUDED.metadata,
+			updated_at = NOW(),
+			dismissed_at = CASE
+				WHEN notifications.resolved_at IS NOT NULL
+				  OR (EXCLUDED.severity = 'critical' AND notifications.severity <> 'critical')
+				THEN NULL
+				ELSE notifications.dismissed_at
+			END,
+			dismissed_by = CASE
+				WHEN notifications.resolved_at IS NOT NULL
+				  OR (EXCLUDED.severity = 'critical' AND notifications.severity <> 'critical')
+				THEN NULL
+				ELSE notifications.dismissed_by
+			END,
+			resolved_at = NULL
+	`, n.Key, n.Category, n.Severity, n.Title, n.Message, metadata)
+	if err != nil {
+		return fmt.Errorf("failed to raise notification %s: %w", n.Key, err)
+	}
+	return nil
+}
+
+// Resolve marks the notification for key as resolved
+func (s *Store) Resolve(ctx context.Context, key string) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE dashboard.notifications
+		SET resolved_at = NOW(), updated_at = NOW()
+		WHERE key = $1 AND resolved_at IS NULL
+	`, key)
+	if err != nil {
+		return fmt.Errorf("failed to resolve notification %s: %w", key, err)
+	}
+	return nil
+}
+
+// Sync raises the active notifications of a category and resolves the category's
+// open notifications whose condition is no longer present
+func (s *Store) Sync(ctx context.Context, category string, active []Notification) error {
+	keys := make([]string, 0, len(active))
+	for _, n := range active {
+		if err := s.Raise(ctx, n); err != nil {
+			return err
+		}
+		keys = append(keys, n.Key)
+	}
+
+	_, err := s.db.Exec(ctx, `
+		UPDATE dashboard.notifications
+		SET resolved_at = NOW(), updated_at = NOW()
+		WHERE category = $1 AND resolved_at IS NULL AND NOT (key = ANY($2))
+	`, category, keys)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s notifications: %w", category, err)
+	}
+	return nil
+}
+
+// List returns notifications, most recently updated first
+func (s *Store) List(ctx context.Context, opts ListOptions) ([]Notification, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT id, key, category, severity, title, message, metadata,
+		       created_at, updated_at, resolved_at, dismissed_at
+		FROM dashboard.notifications
+		WHERE ($1 OR resolved_at IS NULL)
+		  AND ($2 OR dismissed_at IS NULL)
+		ORDER BY updated_at DESC
+		LIMIT $3
+	`, opts.IncludeResolved, opts.IncludeDismissed, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []Notification{}
+	for rows.Next() {
+		var n Notification
+		var metadata []byte
+		if err := rows.Scan(
+			&n.ID, &n.Key, &n.Category, &n.Severity, &n.Title, &n.Message, &metadata,
+			&n.CreatedAt, &n.UpdatedAt, &n.ResolvedAt, &n.DismissedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		if len(metadata) > 0 {
+			_ = json.Unmarshal(metadata, &n.Metadata)
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+// Dismiss hides a notification until its condition is resolved and raised again.
+// It reports whether the notification exists.
+func (s *Store) Dismiss(ctx context.Context, id string, dismissedBy *uuid.UUID) (bool, error) {
+	tag, err := s.db.Exec(ctx, `
+		UPDATE dashboard.notifications
+		SET dismissed_at = COALESCE(dismissed_at, NOW()), dismissed_by = $2
+		WHERE id = $1
+	`, id, dismissedBy)
+	if err != nil {
+		return false, fmt.Errorf("failed to dismiss notification: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
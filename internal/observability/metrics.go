@@ -2,6 +2,7 @@ package observability
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
@@ -35,6 +36,14 @@ type Metrics struct {
 	dbConnections     prometheus.Gauge
 	dbConnectionsIdle prometheus.Gauge
 	dbConnectionsMax  prometheus.Gauge
+	dbConnectionsUsed prometheus.Gauge
+	dbAcquireWaits    prometheus.Counter
+	dbAcquireWaitTime prometheus.Counter
+	dbPoolMu          sync.Mutex
+	dbPoolLast        DBPoolStats
+
+	// REST metrics
+	restStatementCacheTotal *prometheus.CounterVec
 
 	// Realtime metrics
 	realtimeConnections      prometheus.Gauge
@@ -57,6 +66,10 @@ type Metrics struct {
 	// Rate limiting metrics
 	rateLimitHitsTotal *prometheus.CounterVec
 
+	// Load shedding metrics
+	loadShedRequestsTotal *prometheus.CounterVec
+	loadSheddingActive    prometheus.Gauge
+
 	// Job metrics
 	jobsQueueDepth       *prometheus.GaugeVec
 	jobsProcessing       prometheus.Gauge
@@ -76,6 +89,16 @@ type Metrics struct {
 	aiWebSocketConnections  prometheus.Gauge
 	aiProviderRequestsTotal *prometheus.CounterVec
 	aiProviderLatency       *prometheus.HistogramVec
+	aiEmbeddingCacheTotal   *prometheus.CounterVec
+	aiEmbeddingTokensTotal  *prometheus.CounterVec
+	aiRAGRetrievalDuration  *prometheus.HistogramVec
+
+	// Egress metrics
+	egressRequestsTotal *prometheus.CounterVec
+
+	// Table sync metrics
+	tableSyncRowsTotal  *prometheus.CounterVec
+	tableSyncLagSeconds *prometheus.GaugeVec
 
 	// System metrics
 	systemUptime prometheus.Gauge
@@ -165,6 +188,24 @@ func createMetrics() *Metrics {
 				Help: "Maximum number of database connections",
 			},
 		),
+		dbConnectionsUsed: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "fluxbase_db_connections_acquired",
+				Help: "Current number of database connections acquired by queries",
+			},
+		),
+		dbAcquireWaits: promauto.NewCounter(
+			prometheus.CounterOpts{
+				Name: "fluxbase_db_pool_acquire_waits_total",
+				Help: "Total number of connection acquires that waited because the pool was exhausted",
+			},
+		),
+		dbAcquireWaitTime: promauto.NewCounter(
+			prometheus.CounterOpts{
+				Name: "fluxbase_db_pool_acquire_wait_seconds_total",
+				Help: "Total time spent waiting for a database connection in seconds",
+			},
+		),
 
 		// Realtime metrics
 		realtimeConnections: promauto.NewGauge(
@@ -258,9 +299,24 @@ func createMetrics() *Metrics {
 		rateLimitHitsTotal: promauto.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "fluxbase_rate_limit_hits_total",
-				Help: "Total number of rate limit hits",
+				Help: "Total number of requests rejected by a rate limiter",
+			},
+			[]string{"limiter_type"},
+		),
+
+		// Load shedding metrics
+		loadShedRequestsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "fluxbase_load_shed_requests_total",
+				Help: "Requests rejected by load shedding",
+			},
+			[]string{"priority", "reason"}, // reason: pool_saturation, latency
+		),
+		loadSheddingActive: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "fluxbase_load_shedding_active",
+				Help: "Whether low-priority requests are currently being shed (1) or not (0)",
 			},
-			[]string{"limiter_type", "identifier"},
 		),
 
 		// Job metrics
@@ -377,6 +433,62 @@ func createMetrics() *Metrics {
 			},
 			[]string{"provider"},
 		),
+		aiEmbeddingCacheTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "fluxbase_ai_embedding_cache_total",
+				Help: "Knowledge base chunks whose embedding was reused from the embedding cache (hit) or generated (miss)",
+			},
+			[]string{"result"}, // result: hit, miss
+		),
+		aiEmbeddingTokensTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "fluxbase_ai_embedding_tokens_total",
+				Help: "Total tokens sent to embedding providers",
+			},
+			[]string{"provider", "model"},
+		),
+		aiRAGRetrievalDuration: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "fluxbase_ai_rag_retrieval_duration_seconds",
+				Help:    "RAG context retrieval duration in seconds, including query embedding and search",
+				Buckets: []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+			},
+			[]string{"status"}, // status: success, error
+		),
+
+		// Egress metrics
+		egressRequestsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "fluxbase_egress_requests_total",
+				Help: "Outbound requests checked by the egress policy",
+			},
+			[]string{"source", "host", "decision"}, // source: functions, ai, admin; decision: allowed, denied
+		),
+
+		// REST metrics
+		restStatementCacheTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "fluxbase_rest_statement_cache_total",
+				Help: "REST reads run as a cached prepared statement (hit) or parsed and planned afresh (miss)",
+			},
+			[]string{"result"}, // result: hit, miss
+		),
+
+		// Table sync metrics
+		tableSyncRowsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "fluxbase_table_sync_rows_total",
+				Help: "Rows sent to sync targets",
+			},
+			[]string{"target", "table"},
+		),
+		tableSyncLagSeconds: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "fluxbase_table_sync_lag_seconds",
+				Help: "Age of the oldest change not yet sent to a sync target (0 when caught up)",
+			},
+			[]string{"target", "table"},
+		),
 
 		// System metrics
 		systemUptime: promauto.NewGauge(
@@ -399,16 +511,27 @@ func (m *Metrics) MetricsMiddleware() fiber.Handler {
 
 		// Get request size
 		requestSize := len(c.Body())
-		path := normalizePath(c.Path())
 		method := c.Method()
+		ownRoute := c.FullPath()
 
 		// Process request
 		err := c.Next()
 
 		// Calculate duration
 		duration := time.Since(start).Seconds()
-		status := statusClass(c.Response().StatusCode())
-		responseSize := len(c.Response().Body())
+		statusCode := c.Response().StatusCode()
+		// Returned errors are turned into responses by the error handler after middleware ran
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			statusCode = fiberErr.Code
+		}
+		path := routeLabel(c.FullPath(), ownRoute, statusCode)
+		status := statusClass(statusCode)
+		// The size of streamed bodies is unknown, reading them would buffer the whole response
+		responseSize := max(c.Response().Header.ContentLength(), 0)
+		if !c.Response().IsBodyStream() {
+			responseSize = len(c.Response().Body())
+		}
 
 		// Record metrics
 		m.httpRequestsTotal.WithLabelValues(method, path, status).Inc()
@@ -426,11 +549,35 @@ func (m *Metrics) RecordDBQuery(operation, table string, duration time.Duration,
 	m.dbQueryDuration.WithLabelValues(operation, table).Observe(duration.Seconds())
 }
 
-// UpdateDBStats updates database connection pool stats
-func (m *Metrics) UpdateDBStats(total, idle, max int32) {
-	m.dbConnections.Set(float64(total))
-	m.dbConnectionsIdle.Set(float64(idle))
-	m.dbConnectionsMax.Set(float64(max))
+// DBPoolStats is a snapshot of the database connection pool. The acquire wait fields are
+// cumulative since the pool was created, as pgxpool reports them.
+type DBPoolStats struct {
+	Total                int32
+	Idle                 int32
+	Acquired             int32
+	Max                  int32
+	EmptyAcquireCount    int64
+	EmptyAcquireWaitTime time.Duration
+}
+
+// UpdateDBPoolStats updates database connection pool stats
+func (m *Metrics) UpdateDBPoolStats(stats DBPoolStats) {
+	m.dbConnections.Set(float64(stats.Total))
+	m.dbConnectionsIdle.Set(float64(stats.Idle))
+	m.dbConnectionsUsed.Set(float64(stats.Acquired))
+	m.dbConnectionsMax.Set(float64(stats.Max))
+
+	m.dbPoolMu.Lock()
+	defer m.dbPoolMu.Unlock()
+
+	// Counters only grow: a recreated pool restarts its cumulative stats from zero
+	last := m.dbPoolLast
+	if stats.EmptyAcquireCount < last.EmptyAcquireCount || stats.EmptyAcquireWaitTime < last.EmptyAcquireWaitTime {
+		last = DBPoolStats{}
+	}
+	m.dbAcquireWaits.Add(float64(stats.EmptyAcquireCount - last.EmptyAcquireCount))
+	m.dbAcquireWaitTime.Add((stats.EmptyAcquireWaitTime - last.EmptyAcquireWaitTime).Seconds())
+	m.dbPoolLast = stats
 }
 
 // UpdateRealtimeStats updates realtime connection stats
@@ -483,9 +630,23 @@ func (m *Metrics) RecordAuthToken(tokenType string) {
 	m.authTokensIssued.WithLabelValues(tokenType).Inc()
 }
 
-// RecordRateLimitHit records a rate limit hit
-func (m *Metrics) RecordRateLimitHit(limiterType, identifier string) {
-	m.rateLimitHitsTotal.WithLabelValues(limiterType, identifier).Inc()
+// RecordRateLimitHit records a request rejected by a rate limiter
+func (m *Metrics) RecordRateLimitHit(limiterType string) {
+	m.rateLimitHitsTotal.WithLabelValues(limiterType).Inc()
+}
+
+// RecordLoadShed records a request rejected by load shedding
+func (m *Metrics) RecordLoadShed(priority, reason string) {
+	m.loadShedRequestsTotal.WithLabelValues(priority, reason).Inc()
+}
+
+// SetLoadSheddingActive records whether load shedding is currently active
+func (m *Metrics) SetLoadSheddingActive(active bool) {
+	if active {
+		m.loadSheddingActive.Set(1)
+	} else {
+		m.loadSheddingActive.Set(0)
+	}
 }
 
 // UpdateJobQueueDepth updates the job queue depth metric
@@ -569,6 +730,42 @@ func (m *Metrics) RecordAIProviderRequest(provider, status string, duration time
 	m.aiProviderLatency.WithLabelValues(provider).Observe(duration.Seconds())
 }
 
+// RecordAIEmbeddingCache records embedding cache hits and misses of knowledge base chunks
+func (m *Metrics) RecordAIEmbeddingCache(hits, misses int) {
+	m.aiEmbeddingCacheTotal.WithLabelValues("hit").Add(float64(hits))
+	m.aiEmbeddingCacheTotal.WithLabelValues("miss").Add(float64(misses))
+}
+
+// RecordAIEmbeddingTokens records tokens sent to an embedding provider
+func (m *Metrics) RecordAIEmbeddingTokens(provider, model string, tokens int) {
+	m.aiEmbeddingTokensTotal.WithLabelValues(provider, model).Add(float64(tokens))
+}
+
+// RecordAIRAGRetrieval records the duration of a RAG context retrieval
+func (m *Metrics) RecordAIRAGRetrieval(status string, duration time.Duration) {
+	m.aiRAGRetrievalDuration.WithLabelValues(status).Observe(duration.Seconds())
+}
+
+// RecordEgressRequest records an outbound request checked by the egress policy
+func (m *Metrics) RecordEgressRequest(source, host, decision string) {
+	m.egressRequestsTotal.WithLabelValues(source, host, decision).Inc()
+}
+
+// RecordRESTStatementCache records a REST read looked up in the statement cache
+func (m *Metrics) RecordRESTStatementCache(hit bool) {
+	if hit {
+		m.restStatementCacheTotal.WithLabelValues("hit").Inc()
+	} else {
+		m.restStatementCacheTotal.WithLabelValues("miss").Inc()
+	}
+}
+
+// RecordTableSync records rows sent to a sync target and the remaining lag of the table
+func (m *Metrics) RecordTableSync(target, table string, rows int, lag time.Duration) {
+	m.tableSyncRowsTotal.WithLabelValues(target, table).Add(float64(rows))
+	m.tableSyncLagSeconds.WithLabelValues(target, table).Set(lag.Seconds())
+}
+
 // RecordRPCExecution records an RPC procedure execution
 func (m *Metrics) RecordRPCExecution(procedure, status string, duration time.Duration) {
 	// Reuse AI SQL query metrics for RPC since they track similar SQL execution patterns
@@ -583,17 +780,15 @@ func (m *Metrics) Handler() fiber.Handler {
 	return adaptor.HTTPHandler(promhttp.Handler())
 }
 
-// normalizePath normalizes API paths for metrics (replaces IDs with placeholders)
-func normalizePath(path string) string {
-	// For metrics, we want to group paths like /api/v1/tables/users/123 -> /api/v1/tables/users/:id
-	// This prevents cardinality explosion
-
-	// Simple heuristic: if path segment looks like UUID or number, replace with :id
-	// This is a simplified version - production might need more sophisticated logic
-	if len(path) > 50 {
-		return "long_path" // Prevent cardinality explosion
+// routeLabel returns the path label of a request: the pattern of the route that handled it
+// (e.g. /api/v1/tables/:schema/:table), so metrics are grouped per route rather than per URL
+// and IDs in paths don't multiply the series. After the handlers ran, the context reports the
+// last route matched; a 404 still reporting the middleware's own route matched no route.
+func routeLabel(route, middlewareRoute string, status int) string {
+	if route == "" || (route == middlewareRoute && status == fiber.StatusNotFound) {
+		return "unmatched"
 	}
-	return path
+	return route
 }
 
 // statusClass returns the HTTP status class (2xx, 3xx, 4xx, 5xx)
@@ -3,9 +3,13 @@ package observability
 import (
 	"context"
 	"fmt"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/gofiber/fiber/v3"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -45,26 +49,72 @@ func TestStatusClass(t *testing.T) {
 	}
 }
 
-func TestNormalizePath(t *testing.T) {
-	t.Run("returns path unchanged for short paths", func(t *testing.T) {
-		result := normalizePath("/api/v1/users")
-		assert.Equal(t, "/api/v1/users", result)
+func TestRouteLabel(t *testing.T) {
+	t.Run("uses the matched route pattern", func(t *testing.T) {
+		assert.Equal(t, "/api/v1/tables/:schema/:table", routeLabel("/api/v1/tables/:schema/:table", "/", 200))
 	})
 
-	t.Run("returns long_path for paths over 50 chars", func(t *testing.T) {
-		longPath := "/api/v1/very/long/path/that/exceeds/fifty/characters/limit/here"
-		result := normalizePath(longPath)
-		assert.Equal(t, "long_path", result)
+	t.Run("keeps errors of matched routes", func(t *testing.T) {
+		assert.Equal(t, "/api/v1/users/:id", routeLabel("/api/v1/users/:id", "/", 404))
 	})
 
-	t.Run("handles empty path", func(t *testing.T) {
-		result := normalizePath("")
-		assert.Equal(t, "", result)
+	t.Run("groups requests no route matched", func(t *testing.T) {
+		assert.Equal(t, "unmatched", routeLabel("/", "/", 404))
+		assert.Equal(t, "unmatched", routeLabel("", "/", 200))
 	})
+}
+
+// counterValue returns the current value of a counter
+func counterValue(t *testing.T, c prometheus.Counter) float64 {
+	t.Helper()
+	var metric dto.Metric
+	require.NoError(t, c.Write(&metric))
+	return metric.GetCounter().GetValue()
+}
+
+// gaugeValue returns the current value of a gauge
+func gaugeValue(t *testing.T, g prometheus.Gauge) float64 {
+	t.Helper()
+	var metric dto.Metric
+	require.NoError(t, g.Write(&metric))
+	return metric.GetGauge().GetValue()
+}
 
-	t.Run("handles root path", func(t *testing.T) {
-		result := normalizePath("/")
-		assert.Equal(t, "/", result)
+func TestMetricsMiddleware_LabelsRoutes(t *testing.T) {
+	m := NewMetrics()
+	app := fiber.New()
+	app.Use(m.MetricsMiddleware())
+	app.Get("/test-routes/items/:id", func(c fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+
+	routeRequests := m.httpRequestsTotal.WithLabelValues("GET", "/test-routes/items/:id", "2xx")
+	unmatchedRequests := m.httpRequestsTotal.WithLabelValues("GET", "unmatched", "4xx")
+	routeBefore, unmatchedBefore := counterValue(t, routeRequests), counterValue(t, unmatchedRequests)
+
+	for _, path := range []string{"/test-routes/items/1", "/test-routes/items/2", "/test-routes/missing"} {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+
+	assert.Equal(t, 2.0, counterValue(t, routeRequests)-routeBefore)
+	assert.Equal(t, 1.0, counterValue(t, unmatchedRequests)-unmatchedBefore)
+}
+
+func TestMetrics_UpdateDBPoolStats(t *testing.T) {
+	m := NewMetrics()
+	waitsBefore := counterValue(t, m.dbAcquireWaits)
+
+	m.UpdateDBPoolStats(DBPoolStats{Total: 10, Idle: 4, Acquired: 6, Max: 20, EmptyAcquireCount: 3, EmptyAcquireWaitTime: time.Second})
+	m.UpdateDBPoolStats(DBPoolStats{Total: 10, Idle: 2, Acquired: 8, Max: 20, EmptyAcquireCount: 5, EmptyAcquireWaitTime: 2 * time.Second})
+	assert.Equal(t, 8.0, gaugeValue(t, m.dbConnectionsUsed))
+	assert.Equal(t, 2.0, gaugeValue(t, m.dbConnectionsIdle))
+	assert.Equal(t, 5.0, counterValue(t, m.dbAcquireWaits)-waitsBefore)
+
+	t.Run("a recreated pool restarts counting", func(t *testing.T) {
+		m.UpdateDBPoolStats(DBPoolStats{Total: 1, Max: 20, EmptyAcquireCount: 1})
+		assert.Equal(t, 6.0, counterValue(t, m.dbAcquireWaits)-waitsBefore)
 	})
 }
 
@@ -89,9 +139,9 @@ func TestMetrics_AllMethods(t *testing.T) {
 		})
 	})
 
-	t.Run("UpdateDBStats", func(t *testing.T) {
+	t.Run("UpdateDBPoolStats", func(t *testing.T) {
 		assert.NotPanics(t, func() {
-			m.UpdateDBStats(10, 5, 100)
+			m.UpdateDBPoolStats(DBPoolStats{Total: 10, Idle: 5, Max: 100})
 		})
 	})
 
@@ -146,7 +196,15 @@ func TestMetrics_AllMethods(t *testing.T) {
 
 	t.Run("RecordRateLimitHit", func(t *testing.T) {
 		assert.NotPanics(t, func() {
-			m.RecordRateLimitHit("api", "192.168.1.1")
+			m.RecordRateLimitHit("api")
+		})
+	})
+
+	t.Run("RecordLoadShed", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			m.RecordLoadShed("low", "pool_saturation")
+			m.SetLoadSheddingActive(true)
+			m.SetLoadSheddingActive(false)
 		})
 	})
 
@@ -199,6 +257,32 @@ func TestMetrics_AllMethods(t *testing.T) {
 		})
 	})
 
+	t.Run("RecordAIEmbeddingCache", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			m.RecordAIEmbeddingCache(8, 2)
+		})
+	})
+
+	t.Run("RecordAIEmbeddingTokens", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			m.RecordAIEmbeddingTokens("openai", "text-embedding-3-small", 1200)
+		})
+	})
+
+	t.Run("RecordAIRAGRetrieval", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			m.RecordAIRAGRetrieval("success", 120*time.Millisecond)
+			m.RecordAIRAGRetrieval("error", 2*time.Second)
+		})
+	})
+
+	t.Run("RecordTableSync", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			m.RecordTableSync("central", "public.orders", 500, 3*time.Second)
+			m.RecordTableSync("central", "public.orders", 0, 0)
+		})
+	})
+
 	t.Run("RecordRPCExecution_success", func(t *testing.T) {
 		assert.NotPanics(t, func() {
 			m.RecordRPCExecution("get_user_stats", "success", 50*time.Millisecond)
@@ -367,26 +451,6 @@ func TestStatusClass_EdgeCases(t *testing.T) {
 	}
 }
 
-func TestNormalizePath_EdgeCases(t *testing.T) {
-	tests := []struct {
-		name     string
-		path     string
-		expected string
-	}{
-		{"exactly 50 chars", "/api/v1/tables/users/records/000000000000000000000", "/api/v1/tables/users/records/000000000000000000000"},
-		{"51 chars", "/api/v1/tables/users/records/0000000000000000000000", "long_path"},
-		{"special characters", "/api/v1/users?filter=name%3Djohn", "/api/v1/users?filter=name%3Djohn"},
-		{"unicode path", "/api/v1/用户/数据", "/api/v1/用户/数据"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := normalizePath(tt.path)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
 // =============================================================================
 // Benchmarks for Helper Functions
 // =============================================================================
@@ -402,20 +466,8 @@ func BenchmarkStatusClass(b *testing.B) {
 	}
 }
 
-func BenchmarkNormalizePath_Short(b *testing.B) {
-	path := "/api/v1/users"
-
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		_ = normalizePath(path)
-	}
-}
-
-func BenchmarkNormalizePath_Long(b *testing.B) {
-	path := "/api/v1/tables/users/records/12345678901234567890/attachments/files/metadata"
-
-	b.ResetTimer()
+func BenchmarkRouteLabel(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		_ = normalizePath(path)
+		_ = routeLabel("/api/v1/tables/:schema/:table", "/", 200)
 	}
 }
@@ -255,6 +255,26 @@ func StartAuthSpan(ctx context.Context, operation string) (context.Context, trac
 	)
 }
 
+// AI tracing helpers
+
+// StartAISpan starts a span for an AI operation, such as a provider call or a vector search
+func StartAISpan(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	tracer := otel.Tracer("fluxbase-ai")
+	return tracer.Start(ctx, fmt.Sprintf("ai.%s", operation),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(append([]attribute.KeyValue{attribute.String("ai.operation", operation)}, attrs...)...),
+	)
+}
+
+// EndAISpan ends an AI span and records any error
+func EndAISpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
 // HTTP tracing helpers
 
 // ExtractTraceID extracts the trace ID from context as a string
@@ -0,0 +1,320 @@
+package observability
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// UsageKey identifies one hourly usage rollup row.
+// Empty IDs stand for anonymous traffic or requests without a client key.
+type UsageKey struct {
+	Bucket      time.Time
+	ClientKeyID string
+	UserID      string
+}
+
+// UsageCounters are the aggregated figures of a rollup row
+type UsageCounters struct {
+	Requests        int64
+	Errors          int64
+	BytesIn         int64
+	BytesOut        int64
+	TotalDurationMs int64
+	MaxDurationMs   int64
+}
+
+// UsageRecorder aggregates per-key and per-user API usage in memory and periodically
+// upserts it into api.usage_hourly, so request handling never waits on the database.
+type UsageRecorder struct {
+	db            *pgxpool.Pool
+	flushInterval time.Duration
+	retentionDays int
+
+	mu          sync.Mutex
+	pending     map[UsageKey]*UsageCounters
+	lastCleanup time.Time
+
+	// write persists a batch of rollups; replaced in tests
+	write func(ctx context.Context, batch map[UsageKey]*UsageCounters) error
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	running   bool
+	runningMu sync.Mutex
+}
+
+// NewUsageRecorder creates a usage recorder. Rollups older than retentionDays are
+// deleted periodically; 0 keeps them forever.
+func NewUsageRecorder(db *pgxpool.Pool, flushInterval time.Duration, retentionDays int) *UsageRecorder {
+	if flushInterval <= 0 {
+		flushInterval = 30 * time.Second
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r := &UsageRecorder{
+		db:            db,
+		flushInterval: flushInterval,
+		retentionDays: retentionDays,
+		pending:       make(map[UsageKey]*UsageCounters),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+	r.write = r.writeBatch
+	return r
+}
+
+// Record adds a single request to the in-memory rollups
+func (r *UsageRecorder) Record(at time.Time, clientKeyID, userID string, status int, bytesIn, bytesOut int64, duration time.Duration) {
+	key := UsageKey{
+		Bucket:      at.UTC().Truncate(time.Hour),
+		ClientKeyID: clientKeyID,
+		UserID:      userID,
+	}
+	durationMs := duration.Milliseconds()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counters, ok := r.pending[key]
+	if !ok {
+		counters = &UsageCounters{}
+		r.pending[key] = counters
+	}
+	counters.Requests++
+	if status >= 400 {
+		counters.Errors++
+	}
+	counters.BytesIn += bytesIn
+	counters.BytesOut += bytesOut
+	counters.TotalDurationMs += durationMs
+	if durationMs > counters.MaxDurationMs {
+		counters.MaxDurationMs = durationMs
+	}
+}
+
+// Middleware returns a Fiber middleware that records every request that is not under skipPaths.
+// It must run before the auth middlewares so it can read the client key and user they
+// store in Locals once the request has been handled.
+func (r *UsageRecorder) Middleware(skipPaths []string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		path := c.Path()
+		for _, skip := range skipPaths {
+			if strings.HasPrefix(path, skip) {
+				return c.Next()
+			}
+		}
+
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			// Errors are turned into responses by the error handler after this middleware returns
+			status = fiber.StatusInternalServerError
+			if fiberErr, ok := err.(*fiber.Error); ok {
+				status = fiberErr.Code
+			}
+		}
+
+		bytesIn := int64(len(c.Request().Body()))
+		if bytesIn == 0 && c.Request().Header.ContentLength() > 0 {
+			bytesIn = int64(c.Request().Header.ContentLength())
+		}
+		// Streamed bodies are not read, that would buffer the whole response
+		var bytesOut int64
+		if !c.Response().IsBodyStream() {
+			bytesOut = int64(len(c.Response().Body()))
+		}
+		if bytesOut == 0 && c.Response().Header.ContentLength() > 0 {
+			bytesOut = int64(c.Response().Header.ContentLength())
+		}
+
+		r.Record(start, localUUID(c.Locals("client_key_id")), localUUID(c.Locals("user_id")),
+			status, bytesIn, bytesOut, time.Since(start))
+		return err
+	}
+}
+
+// localUUID normalizes an ID stored in fiber.Locals, dropping values that are not UUIDs
+// (e.g. the "service_role" pseudo user)
+func localUUID(v interface{}) string {
+	switch id := v.(type) {
+	case uuid.UUID:
+		if id == uuid.Nil {
+			return ""
+		}
+		return id.String()
+	case *uuid.UUID:
+		if id == nil || *id == uuid.Nil {
+			return ""
+		}
+		return id.String()
+	case string:
+		parsed, err := uuid.Parse(id)
+		if err != nil || parsed == uuid.Nil {
+			return ""
+		}
+		return parsed.String()
+	default:
+		return ""
+	}
+}
+
+// Start begins periodic flushing
+func (r *UsageRecorder) Start() {
+	r.runningMu.Lock()
+	if r.running {
+		r.runningMu.Unlock()
+		return
+	}
+	r.running = true
+	if r.ctx.Err() != nil {
+		r.ctx, r.cancel = context.WithCancel(context.Background())
+	}
+	r.runningMu.Unlock()
+
+	r.wg.Add(1)
+	go r.run()
+
+	log.Info().
+		Dur("flush_interval", r.flushInterval).
+		Int("retention_days", r.retentionDays).
+		Msg("API usage recorder started")
+}
+
+// Stop stops periodic flushing and writes any pending rollups
+func (r *UsageRecorder) Stop() {
+	r.runningMu.Lock()
+	if !r.running {
+		r.runningMu.Unlock()
+		return
+	}
+	r.running = false
+	r.runningMu.Unlock()
+
+	r.cancel()
+	r.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := r.Flush(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to flush API usage on shutdown")
+	}
+
+	log.Info().Msg("API usage recorder stopped")
+}
+
+// run flushes pending rollups on every tick
+func (r *UsageRecorder) run() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Flush(r.ctx); err != nil {
+				log.Warn().Err(err).Msg("Failed to flush API usage")
+			}
+			r.cleanup(r.ctx)
+		}
+	}
+}
+
+// Flush writes pending rollups to the database. On failure the batch is merged
+// back so counts are retried with the next flush instead of being lost.
+func (r *UsageRecorder) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	if len(r.pending) == 0 {
+		r.mu.Unlock()
+		return nil
+	}
+	batch := r.pending
+	r.pending = make(map[UsageKey]*UsageCounters)
+	r.mu.Unlock()
+
+	if err := r.write(ctx, batch); err != nil {
+		r.mu.Lock()
+		for key, counters := range batch {
+			if existing, ok := r.pending[key]; ok {
+				existing.merge(counters)
+			} else {
+				r.pending[key] = counters
+			}
+		}
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// merge adds other into c
+func (c *UsageCounters) merge(other *UsageCounters) {
+	c.Requests += other.Requests
+	c.Errors += other.Errors
+	c.BytesIn += other.BytesIn
+	c.BytesOut += other.BytesOut
+	c.TotalDurationMs += other.TotalDurationMs
+	if other.MaxDurationMs > c.MaxDurationMs {
+		c.MaxDurationMs = other.MaxDurationMs
+	}
+}
+
+// writeBatch upserts rollups, adding to the counters of rows written by earlier flushes or other nodes
+func (r *UsageRecorder) writeBatch(ctx context.Context, batch map[UsageKey]*UsageCounters) error {
+	b := &pgx.Batch{}
+	for key, counters := range batch {
+		b.Queue(`
+			INSERT INTO api.usage_hourly (
+				bucket, client_key_id, user_id,
+				request_count, error_count, bytes_in, bytes_out, total_duration_ms, max_duration_ms
+			)
+			VALUES ($1, NULLIF($2, '')::uuid, NULLIF($3, '')::uuid, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (
+				bucket,
+				COALESCE(client_key_id, '00000000-0000-0000-0000-000000000000'::uuid),
+				COALESCE(user_id, '00000000-0000-0000-0000-000000000000'::uuid)
+			)
+			DO UPDATE SET
+				request_count = api.usage_hourly.request_count + EXCLUDED.request_count,
+				error_count = api.usage_hourly.error_count + EXCLUDED.error_count,
+				bytes_in = api.usage_hourly.bytes_in + EXCLUDED.bytes_in,
+				bytes_out = api.usage_hourly.bytes_out + EXCLUDED.bytes_out,
+				total_duration_ms = api.usage_hourly.total_duration_ms + EXCLUDED.total_duration_ms,
+				max_duration_ms = GREATEST(api.usage_hourly.max_duration_ms, EXCLUDED.max_duration_ms),
+				updated_at = NOW()
+		`, key.Bucket, key.ClientKeyID, key.UserID,
+			counters.Requests, counters.Errors, counters.BytesIn, counters.BytesOut,
+			counters.TotalDurationMs, counters.MaxDurationMs)
+	}
+	return r.db.SendBatch(ctx, b).Close()
+}
+
+// cleanup deletes rollups past the retention period, at most once an hour
+func (r *UsageRecorder) cleanup(ctx context.Context) {
+	if r.retentionDays <= 0 || r.db == nil || time.Since(r.lastCleanup) < time.Hour {
+		return
+	}
+	r.lastCleanup = time.Now()
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM api.usage_hourly WHERE bucket < NOW() - make_interval(days => $1)`, r.retentionDays)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to delete expired API usage rollups")
+		return
+	}
+	if tag.RowsAffected() > 0 {
+		log.Debug().Int64("deleted", tag.RowsAffected()).Msg("Deleted expired API usage rollups")
+	}
+}
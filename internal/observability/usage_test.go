@@ -0,0 +1,137 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageRecorder_Record(t *testing.T) {
+	r := NewUsageRecorder(nil, time.Minute, 0)
+	at := time.Date(2026, 3, 1, 10, 15, 0, 0, time.UTC)
+	keyID := uuid.NewString()
+
+	r.Record(at, keyID, "", 200, 100, 2000, 40*time.Millisecond)
+	r.Record(at.Add(30*time.Minute), keyID, "", 500, 50, 10, 90*time.Millisecond)
+	r.Record(at.Add(time.Hour), keyID, "", 200, 0, 0, time.Millisecond)
+
+	require.Len(t, r.pending, 2)
+
+	counters := r.pending[UsageKey{Bucket: at.Truncate(time.Hour), ClientKeyID: keyID}]
+	require.NotNil(t, counters)
+	assert.Equal(t, int64(2), counters.Requests)
+	assert.Equal(t, int64(1), counters.Errors)
+	assert.Equal(t, int64(150), counters.BytesIn)
+	assert.Equal(t, int64(2010), counters.BytesOut)
+	assert.Equal(t, int64(130), counters.TotalDurationMs)
+	assert.Equal(t, int64(90), counters.MaxDurationMs)
+}
+
+func TestUsageRecorder_Flush(t *testing.T) {
+	t.Run("writes and clears pending rollups", func(t *testing.T) {
+		r := NewUsageRecorder(nil, time.Minute, 0)
+		var written map[UsageKey]*UsageCounters
+		r.write = func(ctx context.Context, batch map[UsageKey]*UsageCounters) error {
+			written = batch
+			return nil
+		}
+
+		r.Record(time.Now(), "", "", 200, 0, 0, 0)
+		require.NoError(t, r.Flush(context.Background()))
+
+		assert.Len(t, written, 1)
+		assert.Empty(t, r.pending)
+	})
+
+	t.Run("keeps rollups for retry when the write fails", func(t *testing.T) {
+		r := NewUsageRecorder(nil, time.Minute, 0)
+		r.write = func(ctx context.Context, batch map[UsageKey]*UsageCounters) error {
+			return errors.New("database unavailable")
+		}
+
+		at := time.Now()
+		r.Record(at, "", "", 200, 10, 0, 5*time.Millisecond)
+		require.Error(t, r.Flush(context.Background()))
+
+		// Traffic recorded after the failed flush is merged with the retained batch
+		r.Record(at, "", "", 200, 10, 0, 20*time.Millisecond)
+
+		counters := r.pending[UsageKey{Bucket: at.UTC().Truncate(time.Hour)}]
+		require.NotNil(t, counters)
+		assert.Equal(t, int64(2), counters.Requests)
+		assert.Equal(t, int64(20), counters.BytesIn)
+		assert.Equal(t, int64(20), counters.MaxDurationMs)
+	})
+
+	t.Run("no-op without pending rollups", func(t *testing.T) {
+		r := NewUsageRecorder(nil, time.Minute, 0)
+		r.write = func(ctx context.Context, batch map[UsageKey]*UsageCounters) error {
+			t.Fatal("write should not be called")
+			return nil
+		}
+		assert.NoError(t, r.Flush(context.Background()))
+	})
+}
+
+func TestUsageRecorder_Middleware(t *testing.T) {
+	r := NewUsageRecorder(nil, time.Minute, 0)
+	keyID := uuid.New()
+	userID := uuid.NewString()
+
+	app := fiber.New()
+	app.Use(r.Middleware([]string{"/health"}))
+	app.Get("/health", func(c fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	app.Get("/api/v1/rest/posts", func(c fiber.Ctx) error {
+		c.Locals("client_key_id", keyID)
+		c.Locals("user_id", userID)
+		return c.SendString("[]")
+	})
+	app.Get("/api/v1/missing", func(c fiber.Ctx) error {
+		c.Locals("user_id", "service_role")
+		return fiber.ErrNotFound
+	})
+
+	for _, path := range []string{"/health", "/api/v1/rest/posts", "/api/v1/missing"} {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+
+	require.Len(t, r.pending, 2)
+	for key, counters := range r.pending {
+		switch key.ClientKeyID {
+		case keyID.String():
+			assert.Equal(t, userID, key.UserID)
+			assert.Equal(t, int64(1), counters.Requests)
+			assert.Equal(t, int64(2), counters.BytesOut)
+			assert.Zero(t, counters.Errors)
+		case "":
+			// Non-UUID user IDs are recorded as anonymous traffic
+			assert.Empty(t, key.UserID)
+			assert.Equal(t, int64(1), counters.Errors)
+		default:
+			t.Fatalf("unexpected usage key %+v", key)
+		}
+	}
+}
+
+func TestLocalUUID(t *testing.T) {
+	id := uuid.New()
+
+	assert.Equal(t, id.String(), localUUID(id))
+	assert.Equal(t, id.String(), localUUID(&id))
+	assert.Equal(t, id.String(), localUUID(id.String()))
+	assert.Empty(t, localUUID("service_role"))
+	assert.Empty(t, localUUID(uuid.Nil))
+	assert.Empty(t, localUUID(nil))
+	assert.Empty(t, localUUID(42))
+}
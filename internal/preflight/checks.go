@@ -0,0 +1,226 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/config"
+)
+
+// minJWTSecretLength is the shortest accepted JWT signing secret
+const minJWTSecretLength = 32
+
+// clockSkewWarning is the clock skew from the database that is reported as a warning
+const clockSkewWarning = 2 * time.Second
+
+// requiredExtensions are created by the system migrations
+var requiredExtensions = []string{"uuid-ossp", "pgcrypto", "pg_trgm", "btree_gin", "vector"}
+
+// extensionHints explain how to provide an extension that PostgreSQL does not offer
+var extensionHints = map[string]string{
+	"vector":  "install pgvector on the database server, e.g. use the pgvector/pgvector Docker image",
+	"postgis": "install PostGIS on the database server to enable spatial filters",
+}
+
+// requiredSchemas are created by the system migrations
+var requiredSchemas = []string{
+	"ai", "api", "app", "audit", "auth", "branching", "dashboard", "functions", "jobs",
+	"logging", "mcp", "migrations", "realtime", "rpc", "storage", "system",
+}
+
+// checks run in this order; names are what preflight.skip refers to
+var checks = []check{
+	{name: "secret_keys", stage: StageBeforeMigrations, run: checkSecretKeys},
+	{name: "extensions", stage: StageBeforeMigrations, run: checkExtensions},
+	{name: "clock_skew", stage: StageBeforeMigrations, run: checkClockSkew},
+	{name: "smtp", stage: StageBeforeMigrations, run: checkSMTP},
+	{name: "migrations", stage: StageAfterMigrations, run: checkMigrations},
+	{name: "schemas", stage: StageAfterMigrations, run: checkSchemas},
+}
+
+// isCheck reports whether name is the name of a preflight check
+func isCheck(name string) bool {
+	return slices.ContainsFunc(checks, func(c check) bool { return c.name == name })
+}
+
+// checkSecretKeys verifies the signing and encryption keys are long enough
+func checkSecretKeys(_ context.Context, r *Runner) (Status, string, string) {
+	if n := len(r.cfg.Auth.JWTSecret); n < minJWTSecretLength {
+		return StatusFail,
+			fmt.Sprintf("auth.jwt_secret is %d characters, at least %d are required", n, minJWTSecretLength),
+			"generate a secret with: openssl rand -base64 32"
+	}
+	if n := len(r.cfg.EncryptionKey); n != 32 {
+		return StatusFail,
+			fmt.Sprintf("encryption_key is %d bytes, AES-256 requires exactly 32", n),
+			"generate a key with: openssl rand -base64 24"
+	}
+	return StatusPass, "JWT secret and encryption key have secure lengths", ""
+}
+
+// checkExtensions verifies PostgreSQL offers the extensions the migrations create
+func checkExtensions(ctx context.Context, r *Runner) (Status, string, string) {
+	var available []string
+	err := r.db.QueryRow(ctx,
+		`SELECT COALESCE(array_agg(name::text), '{}') FROM pg_available_extensions WHERE name = ANY($1)`,
+		append(slices.Clone(requiredExtensions), "postgis"),
+	).Scan(&available)
+	if err != nil {
+		return StatusFail, fmt.Sprintf("failed to list available extensions: %v", err), "check the database connection settings"
+	}
+
+	var missing, hints []string
+	for _, name := range requiredExtensions {
+		if !slices.Contains(available, name) {
+			missing = append(missing, name)
+			if hint, ok := extensionHints[name]; ok {
+				hints = append(hints, hint)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		if len(hints) == 0 {
+			hints = append(hints, "install the PostgreSQL contrib packages on the database server")
+		}
+		return StatusFail,
+			fmt.Sprintf("PostgreSQL does not offer required extensions: %s", strings.Join(missing, ", ")),
+			strings.Join(hints, "; ")
+	}
+
+	if !slices.Contains(available, "postgis") {
+		return StatusPass, "required extensions are available; PostGIS is not, so spatial filters are disabled", extensionHints["postgis"]
+	}
+	return StatusPass, "required extensions and PostGIS are available", ""
+}
+
+// checkClockSkew compares the server clock with the database clock. Token expiry,
+// rate limits and schedules mix timestamps from both.
+func checkClockSkew(ctx context.Context, r *Runner) (Status, string, string) {
+	before := r.now()
+	var dbTime time.Time
+	if err := r.db.QueryRow(ctx, `SELECT clock_timestamp()`).Scan(&dbTime); err != nil {
+		return StatusFail, fmt.Sprintf("failed to read the database clock: %v", err), "check the database connection settings"
+	}
+	after := r.now()
+
+	// Compare with the middle of the round trip
+	local := before.Add(after.Sub(before) / 2)
+	skew := dbTime.Sub(local)
+	if skew < 0 {
+		skew = -skew
+	}
+	skew = skew.Round(time.Millisecond)
+
+	const hint = "synchronize the clocks of the server and database hosts with NTP"
+	switch {
+	case skew > r.maxClockSkew():
+		return StatusFail, fmt.Sprintf("server clock is %s off the database clock (max %s)", skew, r.maxClockSkew()), hint
+	case skew > clockSkewWarning:
+		return StatusWarn, fmt.Sprintf("server clock is %s off the database clock", skew), hint
+	}
+	return StatusPass, fmt.Sprintf("server clock is within %s of the database clock", clockSkewWarning), ""
+}
+
+// checkSMTP verifies configured SMTP servers accept connections. An unreachable mail
+// server only affects email, so it is a warning.
+func checkSMTP(ctx context.Context, r *Runner) (Status, string, string) {
+	email := r.cfg.Email
+	if !email.Enabled {
+		return StatusPass, "email is disabled", ""
+	}
+
+	var addresses []string
+	add := func(ec config.EmailConfig) {
+		if (ec.Provider == "" || ec.Provider == "smtp") && ec.SMTPHost != "" {
+			address := net.JoinHostPort(ec.SMTPHost, strconv.Itoa(ec.SMTPPort))
+			if !slices.Contains(addresses, address) {
+				addresses = append(addresses, address)
+			}
+		}
+	}
+	add(email)
+	for _, p := range email.Profiles {
+		add(p.EmailConfig)
+	}
+	if len(addresses) == 0 {
+		return StatusPass, "no SMTP server is configured", ""
+	}
+
+	var unreachable []string
+	for _, address := range addresses {
+		conn, err := r.dial(ctx, "tcp", address)
+		if err != nil {
+			unreachable = append(unreachable, fmt.Sprintf("%s (%v)", address, err))
+			continue
+		}
+		_ = conn.Close()
+	}
+	if len(unreachable) > 0 {
+		return StatusWarn,
+			fmt.Sprintf("cannot connect to SMTP server %s", strings.Join(unreachable, ", ")),
+			"check email.smtp_host and email.smtp_port, and that outbound connections to the port are allowed"
+	}
+	return StatusPass, fmt.Sprintf("SMTP server %s is reachable", strings.Join(addresses, ", ")), ""
+}
+
+// checkMigrations verifies the database schema matches the migrations of this binary
+func checkMigrations(ctx context.Context, r *Runner) (Status, string, string) {
+	var version int64
+	var dirty bool
+	err := r.db.QueryRow(ctx, `SELECT version, dirty FROM "migrations"."fluxbase" LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return StatusFail, "no system migrations have been applied", "check the migration output earlier in the log"
+	}
+	if err != nil {
+		return StatusFail, fmt.Sprintf("failed to read the migration version: %v", err), "check that the runtime user has the service_role role"
+	}
+
+	latest := int64(r.latestMigration)
+	switch {
+	case dirty:
+		return StatusFail,
+			fmt.Sprintf("migration %d did not complete", version),
+			"inspect the failed migration, then restart to retry it"
+	case version < latest:
+		return StatusFail,
+			fmt.Sprintf("database is at migration %d, this version requires %d", version, latest),
+			"check the migration output earlier in the log"
+	case version > latest:
+		return StatusWarn,
+			fmt.Sprintf("database is at migration %d, newer than the %d of this version", version, latest),
+			"upgrade this instance to the version that migrated the database"
+	}
+	return StatusPass, fmt.Sprintf("database is at migration %d", version), ""
+}
+
+// checkSchemas verifies the schemas of the system migrations exist
+func checkSchemas(ctx context.Context, r *Runner) (Status, string, string) {
+	var present []string
+	err := r.db.QueryRow(ctx,
+		`SELECT COALESCE(array_agg(nspname::text), '{}') FROM pg_namespace WHERE nspname = ANY($1)`,
+		requiredSchemas,
+	).Scan(&present)
+	if err != nil {
+		return StatusFail, fmt.Sprintf("failed to list schemas: %v", err), "check the database connection settings"
+	}
+
+	var missing []string
+	for _, schema := range requiredSchemas {
+		if !slices.Contains(present, schema) {
+			missing = append(missing, schema)
+		}
+	}
+	if len(missing) > 0 {
+		return StatusFail,
+			fmt.Sprintf("required schemas are missing: %s", strings.Join(missing, ", ")),
+			"a schema was dropped or the migrations ran against another database; check database.database"
+	}
+	return StatusPass, fmt.Sprintf("all %d required schemas exist", len(requiredSchemas)), ""
+}
@@ -0,0 +1,202 @@
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/rs/zerolog/log"
+)
+
+// Defaults used when the preflight configuration was not loaded through viper
+const (
+	defaultCheckTimeout = 5 * time.Second
+	defaultMaxClockSkew = 30 * time.Second
+)
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	StatusPass    Status = "pass"
+	StatusWarn    Status = "warn" // Startup continues, but a feature may not work
+	StatusFail    Status = "fail" // Startup stops
+	StatusSkipped Status = "skipped"
+)
+
+// Stage selects checks by when they can run during startup
+type Stage int
+
+const (
+	// StageBeforeMigrations checks what migrations and the server depend on
+	StageBeforeMigrations Stage = 1 << iota
+	// StageAfterMigrations checks the migrated database
+	StageAfterMigrations
+
+	StageAll = StageBeforeMigrations | StageAfterMigrations
+)
+
+// Result is the outcome of one check
+type Result struct {
+	Name       string `json:"name"`
+	Status     Status `json:"status"`
+	Message    string `json:"message"`
+	Hint       string `json:"hint,omitempty"` // How to fix a warning or failure
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// Report holds the results of a preflight run
+type Report struct {
+	Status    Status    `json:"status"` // Worst status of the checks
+	Checks    []Result  `json:"checks"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Failures returns the failed checks
+func (r *Report) Failures() []Result {
+	var failures []Result
+	for _, check := range r.Checks {
+		if check.Status == StatusFail {
+			failures = append(failures, check)
+		}
+	}
+	return failures
+}
+
+// Err returns an error describing the failed checks and how to fix them, or nil
+func (r *Report) Err() error {
+	failures := r.Failures()
+	if len(failures) == 0 {
+		return nil
+	}
+
+	lines := make([]string, 0, len(failures))
+	for _, f := range failures {
+		line := fmt.Sprintf("%s: %s", f.Name, f.Message)
+		if f.Hint != "" {
+			line += " (" + f.Hint + ")"
+		}
+		lines = append(lines, line)
+	}
+	return fmt.Errorf("preflight checks failed: %s", strings.Join(lines, "; "))
+}
+
+// Merge combines the reports of several stages into one
+func Merge(reports ...*Report) *Report {
+	merged := &Report{Status: StatusPass}
+	for _, r := range reports {
+		if r == nil {
+			continue
+		}
+		merged.Checks = append(merged.Checks, r.Checks...)
+		merged.CheckedAt = r.CheckedAt
+	}
+	merged.Status = worstStatus(merged.Checks)
+	return merged
+}
+
+// worstStatus returns the most severe status of results
+func worstStatus(results []Result) Status {
+	status := StatusPass
+	for _, r := range results {
+		switch {
+		case r.Status == StatusFail:
+			return StatusFail
+		case r.Status == StatusWarn:
+			status = StatusWarn
+		}
+	}
+	return status
+}
+
+// Querier runs the queries of database checks, implemented by database.Connection
+type Querier interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// check is a single preflight check. run returns the status, a message and a hint.
+type check struct {
+	name  string
+	stage Stage
+	run   func(ctx context.Context, r *Runner) (Status, string, string)
+}
+
+// Runner runs preflight checks against the configuration and the database
+type Runner struct {
+	cfg             *config.Config
+	db              Querier
+	latestMigration int // Highest system migration embedded in the binary
+	dial            func(ctx context.Context, network, address string) (net.Conn, error)
+	now             func() time.Time
+}
+
+// NewRunner creates a new preflight runner
+func NewRunner(cfg *config.Config, db Querier) *Runner {
+	for _, name := range cfg.Preflight.Skip {
+		if !isCheck(name) {
+			log.Warn().Str("check", name).Msg("Unknown preflight check in preflight.skip")
+		}
+	}
+
+	dialer := &net.Dialer{}
+	return &Runner{
+		cfg:             cfg,
+		db:              db,
+		latestMigration: database.LatestMigrationVersion(),
+		dial:            dialer.DialContext,
+		now:             time.Now,
+	}
+}
+
+// checkTimeout returns the timeout of each check
+func (r *Runner) checkTimeout() time.Duration {
+	if r.cfg.Preflight.CheckTimeout > 0 {
+		return r.cfg.Preflight.CheckTimeout
+	}
+	return defaultCheckTimeout
+}
+
+// maxClockSkew returns the clock skew that fails the clock_skew check
+func (r *Runner) maxClockSkew() time.Duration {
+	if r.cfg.Preflight.MaxClockSkew > 0 {
+		return r.cfg.Preflight.MaxClockSkew
+	}
+	return defaultMaxClockSkew
+}
+
+// Run runs the checks of the given stages and returns their report
+func (r *Runner) Run(ctx context.Context, stages Stage) *Report {
+	report := &Report{CheckedAt: r.now().UTC()}
+
+	for _, c := range checks {
+		if c.stage&stages == 0 {
+			continue
+		}
+		if slices.Contains(r.cfg.Preflight.Skip, c.name) {
+			report.Checks = append(report.Checks, Result{Name: c.name, Status: StatusSkipped, Message: "skipped by preflight.skip"})
+			continue
+		}
+
+		start := r.now()
+		checkCtx, cancel := context.WithTimeout(ctx, r.checkTimeout())
+		status, message, hint := c.run(checkCtx, r)
+		cancel()
+
+		report.Checks = append(report.Checks, Result{
+			Name:       c.name,
+			Status:     status,
+			Message:    message,
+			Hint:       hint,
+			DurationMS: r.now().Sub(start).Milliseconds(),
+		})
+	}
+
+	report.Status = worstStatus(report.Checks)
+	return report
+}
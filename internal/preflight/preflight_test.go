@@ -0,0 +1,191 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRow scans fixed values into the destinations
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i, d := range dest {
+		reflect.ValueOf(d).Elem().Set(reflect.ValueOf(r.values[i]))
+	}
+	return nil
+}
+
+// fakeDB answers queries by the first registered fragment they contain
+type fakeDB map[string]fakeRow
+
+func (db fakeDB) QueryRow(_ context.Context, sql string, _ ...interface{}) pgx.Row {
+	for fragment, row := range db {
+		if strings.Contains(sql, fragment) {
+			return row
+		}
+	}
+	return fakeRow{err: errors.New("unexpected query: " + sql)}
+}
+
+func healthyDB(now time.Time) fakeDB {
+	return fakeDB{
+		"pg_available_extensions": {values: []any{[]string{"uuid-ossp", "pgcrypto", "pg_trgm", "btree_gin", "vector"}}},
+		"clock_timestamp":         {values: []any{now}},
+		"migrations":              {values: []any{int64(137), false}},
+		"pg_namespace":            {values: []any{append([]string{}, requiredSchemas...)}},
+	}
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Auth:          config.AuthConfig{JWTSecret: strings.Repeat("k", 32)},
+		EncryptionKey: strings.Repeat("e", 32),
+		Email:         config.EmailConfig{Enabled: true, Provider: "smtp", SMTPHost: "mail.example.com", SMTPPort: 587},
+		Preflight:     config.PreflightConfig{Enabled: true},
+	}
+}
+
+func newTestRunner(cfg *config.Config, db fakeDB, now time.Time) *Runner {
+	r := NewRunner(cfg, db)
+	r.latestMigration = 137
+	r.now = func() time.Time { return now }
+	r.dial = func(_ context.Context, _, _ string) (net.Conn, error) {
+		client, server := net.Pipe()
+		_ = server.Close()
+		return client, nil
+	}
+	return r
+}
+
+func findResult(t *testing.T, report *Report, name string) Result {
+	t.Helper()
+	for _, r := range report.Checks {
+		if r.Name == name {
+			return r
+		}
+	}
+	t.Fatalf("check %s did not run", name)
+	return Result{}
+}
+
+func TestRunner_Run(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+
+	t.Run("healthy environment passes", func(t *testing.T) {
+		report := newTestRunner(testConfig(), healthyDB(now), now).Run(context.Background(), StageAll)
+		assert.Equal(t, StatusPass, report.Status)
+		assert.Len(t, report.Checks, len(checks))
+		assert.NoError(t, report.Err())
+		assert.Contains(t, findResult(t, report, "extensions").Message, "PostGIS is not")
+	})
+
+	t.Run("stages select checks", func(t *testing.T) {
+		runner := newTestRunner(testConfig(), healthyDB(now), now)
+		before := runner.Run(context.Background(), StageBeforeMigrations)
+		after := runner.Run(context.Background(), StageAfterMigrations)
+		assert.Len(t, before.Checks, 4)
+		assert.Equal(t, []Result{findResult(t, after, "migrations"), findResult(t, after, "schemas")}, after.Checks)
+		assert.Len(t, Merge(before, nil, after).Checks, len(checks))
+	})
+
+	t.Run("skipped checks", func(t *testing.T) {
+		cfg := testConfig()
+		cfg.Preflight.Skip = []string{"smtp"}
+		runner := newTestRunner(cfg, healthyDB(now), now)
+		runner.dial = func(context.Context, string, string) (net.Conn, error) {
+			t.Fatal("skipped check ran")
+			return nil, nil
+		}
+		report := runner.Run(context.Background(), StageAll)
+		assert.Equal(t, StatusSkipped, findResult(t, report, "smtp").Status)
+		assert.Equal(t, StatusPass, report.Status)
+	})
+}
+
+func TestChecks(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		check      string
+		modify     func(cfg *config.Config, db fakeDB, r *Runner)
+		wantStatus Status
+		wantText   string
+	}{
+		{"short JWT secret", "secret_keys", func(cfg *config.Config, _ fakeDB, _ *Runner) {
+			cfg.Auth.JWTSecret = "short"
+		}, StatusFail, "at least 32"},
+		{"short encryption key", "secret_keys", func(cfg *config.Config, _ fakeDB, _ *Runner) {
+			cfg.EncryptionKey = "short"
+		}, StatusFail, "exactly 32"},
+		{"missing pgvector", "extensions", func(_ *config.Config, db fakeDB, _ *Runner) {
+			db["pg_available_extensions"] = fakeRow{values: []any{[]string{"uuid-ossp", "pgcrypto", "pg_trgm", "btree_gin"}}}
+		}, StatusFail, "pgvector/pgvector"},
+		{"clock skew warning", "clock_skew", func(_ *config.Config, db fakeDB, _ *Runner) {
+			db["clock_timestamp"] = fakeRow{values: []any{now.Add(-5 * time.Second)}}
+		}, StatusWarn, "5s off"},
+		{"clock skew failure", "clock_skew", func(_ *config.Config, db fakeDB, _ *Runner) {
+			db["clock_timestamp"] = fakeRow{values: []any{now.Add(2 * time.Minute)}}
+		}, StatusFail, "NTP"},
+		{"unreachable SMTP server", "smtp", func(_ *config.Config, _ fakeDB, r *Runner) {
+			r.dial = func(context.Context, string, string) (net.Conn, error) {
+				return nil, errors.New("connection refused")
+			}
+		}, StatusWarn, "mail.example.com:587 (connection refused)"},
+		{"email disabled", "smtp", func(cfg *config.Config, _ fakeDB, r *Runner) {
+			cfg.Email.Enabled = false
+			r.dial = nil
+		}, StatusPass, "disabled"},
+		{"pending migrations", "migrations", func(_ *config.Config, db fakeDB, _ *Runner) {
+			db["migrations"] = fakeRow{values: []any{int64(130), false}}
+		}, StatusFail, "requires 137"},
+		{"dirty migration", "migrations", func(_ *config.Config, db fakeDB, _ *Runner) {
+			db["migrations"] = fakeRow{values: []any{int64(137), true}}
+		}, StatusFail, "did not complete"},
+		{"newer database", "migrations", func(_ *config.Config, db fakeDB, _ *Runner) {
+			db["migrations"] = fakeRow{values: []any{int64(140), false}}
+		}, StatusWarn, "newer"},
+		{"no migrations", "migrations", func(_ *config.Config, db fakeDB, _ *Runner) {
+			db["migrations"] = fakeRow{err: pgx.ErrNoRows}
+		}, StatusFail, "no system migrations"},
+		{"missing schema", "schemas", func(_ *config.Config, db fakeDB, _ *Runner) {
+			db["pg_namespace"] = fakeRow{values: []any{[]string{"auth", "storage"}}}
+		}, StatusFail, "ai, api"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			db := healthyDB(now)
+			runner := newTestRunner(cfg, db, now)
+			tt.modify(cfg, db, runner)
+
+			report := runner.Run(context.Background(), StageAll)
+			result := findResult(t, report, tt.check)
+			assert.Equal(t, tt.wantStatus, result.Status)
+			assert.Contains(t, result.Message+" "+result.Hint, tt.wantText)
+			assert.Equal(t, tt.wantStatus, report.Status)
+
+			if tt.wantStatus == StatusFail {
+				err := report.Err()
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.check+": ")
+			}
+		})
+	}
+}
@@ -40,3 +40,6 @@ const PresenceChannel = "fluxbase:presence"
 
 // SchemaCacheChannel is the channel used for schema cache invalidation across instances
 const SchemaCacheChannel = "fluxbase:schema_cache"
+
+// KnowledgeBaseCacheChannel is the channel used for knowledge base cache invalidation across instances
+const KnowledgeBaseCacheChannel = "fluxbase:kb_cache"
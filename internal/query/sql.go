@@ -0,0 +1,63 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Safe SQL building helpers shared by the REST query builder and AI storage.
+//
+// Identifiers cannot be bound as parameters, so they are always quoted with QuoteIdentifier.
+// Everything else that comes from callers (JSONB keys, values, vectors) is bound as a
+// positional parameter. The *int argument follows the "$n" counter convention used by
+// the query builders: it holds the next placeholder number and is advanced on use.
+
+// JSONBOperator is a JSONB field access operator
+type JSONBOperator string
+
+const (
+	// JSONBField extracts a field as jsonb (->)
+	JSONBField JSONBOperator = "->"
+	// JSONBFieldText extracts a field as text (->>)
+	JSONBFieldText JSONBOperator = "->>"
+)
+
+// QuoteIdentifier quotes a PostgreSQL identifier, escaping embedded double quotes.
+func QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// QuoteQualifiedName quotes a schema-qualified name such as a table or function.
+func QuoteQualifiedName(schema, name string) string {
+	return QuoteIdentifier(schema) + "." + QuoteIdentifier(name)
+}
+
+// QuoteLiteral quotes a string literal for the rare places a value cannot be bound
+// (e.g. DDL statements). NUL bytes are dropped because PostgreSQL rejects them in text.
+func QuoteLiteral(value string) string {
+	value = strings.ReplaceAll(value, "\x00", "")
+	if strings.Contains(value, `\`) {
+		// E'' syntax so backslashes are escaped regardless of standard_conforming_strings
+		return `E'` + strings.ReplaceAll(strings.ReplaceAll(value, `\`, `\\`), "'", "''") + `'`
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// Placeholder returns the next positional parameter ("$n") and advances the counter.
+func Placeholder(argIndex *int) string {
+	p := fmt.Sprintf("$%d", *argIndex)
+	*argIndex++
+	return p
+}
+
+// BindJSONBKey appends a JSONB object key access to expr with the key bound as a parameter,
+// e.g. BindJSONBKey("d.metadata", JSONBFieldText, "category", &n) returns `d.metadata->>$1::text`.
+// The returned argument must be added to the query args in placeholder order.
+func BindJSONBKey(expr string, op JSONBOperator, key string, argIndex *int) (string, interface{}) {
+	return fmt.Sprintf("%s%s%s::text", expr, op, Placeholder(argIndex)), key
+}
+
+// BindJSONBIndex appends a JSONB array element access to expr with the index bound as a parameter.
+func BindJSONBIndex(expr string, op JSONBOperator, index int, argIndex *int) (string, interface{}) {
+	return fmt.Sprintf("%s%s%s::int", expr, op, Placeholder(argIndex)), index
+}
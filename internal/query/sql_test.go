@@ -0,0 +1,108 @@
+package query
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	assert.Equal(t, `"users"`, QuoteIdentifier("users"))
+	assert.Equal(t, `"my""table"`, QuoteIdentifier(`my"table`))
+	assert.Equal(t, `"public"."users"`, QuoteQualifiedName("public", "users"))
+}
+
+func TestQuoteLiteral(t *testing.T) {
+	assert.Equal(t, `'plain'`, QuoteLiteral("plain"))
+	assert.Equal(t, `'it''s'`, QuoteLiteral("it's"))
+	assert.Equal(t, `E'a\\b'`, QuoteLiteral(`a\b`))
+	assert.Equal(t, `'ab'`, QuoteLiteral("a\x00b"))
+}
+
+func TestPlaceholder(t *testing.T) {
+	argIndex := 3
+	assert.Equal(t, "$3", Placeholder(&argIndex))
+	assert.Equal(t, "$4", Placeholder(&argIndex))
+	assert.Equal(t, 5, argIndex)
+}
+
+func TestBindJSONBKey(t *testing.T) {
+	argIndex := 2
+	expr, arg := BindJSONBKey("d.metadata", JSONBFieldText, "category", &argIndex)
+	assert.Equal(t, "d.metadata->>$2::text", expr)
+	assert.Equal(t, "category", arg)
+	assert.Equal(t, 3, argIndex)
+
+	expr, arg = BindJSONBKey(expr, JSONBField, "nested", &argIndex)
+	assert.Equal(t, "d.metadata->>$2::text->$3::text", expr)
+	assert.Equal(t, "nested", arg)
+}
+
+func TestBindJSONBIndex(t *testing.T) {
+	argIndex := 1
+	expr, arg := BindJSONBIndex(`"items"`, JSONBField, 0, &argIndex)
+	assert.Equal(t, `"items"->$1::int`, expr)
+	assert.Equal(t, 0, arg)
+}
+
+// unquoteIdentifier reverses QuoteIdentifier, reporting whether the input was a single well-formed quoted identifier
+func unquoteIdentifier(quoted string) (string, bool) {
+	if len(quoted) < 2 || quoted[0] != '"' || quoted[len(quoted)-1] != '"' {
+		return "", false
+	}
+	inner := quoted[1 : len(quoted)-1]
+	// Every embedded quote must be doubled, otherwise the identifier would terminate early
+	if strings.Count(strings.ReplaceAll(inner, `""`, ""), `"`) != 0 {
+		return "", false
+	}
+	return strings.ReplaceAll(inner, `""`, `"`), true
+}
+
+func FuzzQuoteIdentifier(f *testing.F) {
+	for _, seed := range []string{"users", `a"b`, `"; DROP TABLE users; --`, "", `""`} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, name string) {
+		unquoted, ok := unquoteIdentifier(QuoteIdentifier(name))
+		if !ok || unquoted != name {
+			t.Fatalf("QuoteIdentifier(%q) does not round-trip", name)
+		}
+	})
+}
+
+func FuzzQuoteLiteral(f *testing.F) {
+	for _, seed := range []string{"plain", "it's", `a\b`, `\'; DROP TABLE users; --`, "'"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, value string) {
+		quoted := QuoteLiteral(value)
+		body := strings.TrimPrefix(quoted, "E")
+		if len(body) < 2 || body[0] != '\'' || body[len(body)-1] != '\'' {
+			t.Fatalf("QuoteLiteral(%q) = %q is not a single literal", value, quoted)
+		}
+		inner := body[1 : len(body)-1]
+		if strings.Contains(strings.ReplaceAll(inner, "''", ""), "'") {
+			t.Fatalf("QuoteLiteral(%q) = %q contains an unescaped quote", value, quoted)
+		}
+		if strings.HasPrefix(quoted, "E") && strings.Contains(strings.ReplaceAll(inner, `\\`, ""), `\`) {
+			t.Fatalf("QuoteLiteral(%q) = %q contains an unescaped backslash", value, quoted)
+		}
+	})
+}
+
+func FuzzBindJSONBKey(f *testing.F) {
+	for _, seed := range []string{"category", "x' OR '1'='1", "$1", "a->>b", "'; DROP TABLE users; --"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, key string) {
+		argIndex := 1
+		expr, arg := BindJSONBKey("metadata", JSONBFieldText, key, &argIndex)
+		if expr != "metadata->>$1::text" {
+			t.Fatalf("BindJSONBKey(%q) leaked the key into SQL: %q", key, expr)
+		}
+		if arg != key {
+			t.Fatalf("BindJSONBKey(%q) bound %v", key, arg)
+		}
+	})
+}
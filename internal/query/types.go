@@ -42,6 +42,7 @@ const (
 	OpSTTouches    FilterOperator = "st_touches"    // ST_Touches - geometries touch
 	OpSTCrosses    FilterOperator = "st_crosses"    // ST_Crosses - geometries cross
 	OpSTOverlaps   FilterOperator = "st_overlaps"   // ST_Overlaps - geometries overlap
+	OpBBox         FilterOperator = "bbox"          // && - bounding boxes intersect
 
 	// pgvector similarity operators
 	OpVectorL2     FilterOperator = "vec_l2"  // L2/Euclidean distance <-> (lower = more similar)
@@ -56,6 +57,11 @@ type Filter struct {
 	Value     interface{}
 	IsOr      bool // OR instead of AND
 	OrGroupID int  // Groups OR filters together (filters with same non-zero ID are ORed)
+	// Language is the text search configuration of fts, plfts and wfts filters, empty for
+	// the database default
+	Language string
+	// IsTSVector is set for text search filters on tsvector columns, which aren't converted
+	IsTSVector bool
 }
 
 // OrderBy represents an ORDER BY clause
@@ -66,4 +72,5 @@ type OrderBy struct {
 	NullsFirst  bool           // Deprecated: use Nulls field instead
 	VectorOp    FilterOperator // Vector operator for similarity ordering (vec_l2, vec_cos, vec_ip)
 	VectorValue interface{}    // Vector value for similarity ordering
+	Geometry    string         // GeoJSON geometry for st_distance ordering (nearest first)
 }
@@ -0,0 +1,564 @@
+// Package queues is a lightweight message queue backed by Postgres. Each consumer group of
+// a queue receives every message; within a group, reading a message hides it from other
+// consumers for a visibility timeout, after which it is delivered again unless it was
+// acknowledged. Messages delivered too often are moved to a dead-letter queue.
+package queues
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultGroup is the consumer group every queue is created with
+const DefaultGroup = "default"
+
+// Queue defaults
+const (
+	DefaultVisibilityTimeout = 30
+	DefaultMaxDeliveries     = 5
+)
+
+var (
+	// ErrQueueNotFound is returned for an unknown queue
+	ErrQueueNotFound = errors.New("queue not found")
+	// ErrQueueExists is returned when a queue with the name already exists
+	ErrQueueExists = errors.New("a queue with this name already exists")
+	// ErrGroupNotFound is returned for an unknown consumer group
+	ErrGroupNotFound = errors.New("consumer group not found")
+	// ErrGroupExists is returned when the queue already has the consumer group
+	ErrGroupExists = errors.New("queue already has this consumer group")
+	// ErrDeadLetterQueueNotFound is returned when the dead-letter queue doesn't exist
+	ErrDeadLetterQueueNotFound = errors.New("dead-letter queue not found")
+)
+
+// namePattern matches queue and consumer group names
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// ValidName reports whether name can name a queue or consumer group
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// Queue is a message queue and its delivery settings
+type Queue struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// VisibilityTimeout is the default number of seconds a read message stays hidden
+	VisibilityTimeout int `json:"visibility_timeout"`
+	// MaxDeliveries is the number of deliveries after which a message is dead-lettered
+	MaxDeliveries   int          `json:"max_deliveries"`
+	DeadLetterQueue *string      `json:"dead_letter_queue"`
+	Groups          []GroupStats `json:"consumer_groups"`
+	CreatedBy       *string      `json:"created_by,omitempty"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+// Validate checks the name and delivery settings of a queue, filling in the defaults
+func (q *Queue) Validate() error {
+	if !ValidName(q.Name) {
+		return errors.New("name must be 1-63 lowercase letters, digits, '-' or '_', starting with a letter or digit")
+	}
+	if q.VisibilityTimeout == 0 {
+		q.VisibilityTimeout = DefaultVisibilityTimeout
+	}
+	if q.VisibilityTimeout < 1 || q.VisibilityTimeout > 12*60*60 {
+		return errors.New("visibility_timeout must be between 1 and 43200 seconds")
+	}
+	if q.MaxDeliveries == 0 {
+		q.MaxDeliveries = DefaultMaxDeliveries
+	}
+	if q.MaxDeliveries < 1 || q.MaxDeliveries > 1000 {
+		return errors.New("max_deliveries must be between 1 and 1000")
+	}
+	if q.DeadLetterQueue != nil {
+		if *q.DeadLetterQueue == "" {
+			q.DeadLetterQueue = nil
+		} else if *q.DeadLetterQueue == q.Name {
+			return errors.New("a queue can't be its own dead-letter queue")
+		}
+	}
+	return nil
+}
+
+// GroupStats is a consumer group with the number of its pending messages
+type GroupStats struct {
+	Name string `json:"name"`
+	// Ready is the number of messages that can be read now
+	Ready int64 `json:"ready"`
+	// InFlight is the number of messages read and not yet acknowledged, or delayed
+	InFlight int64 `json:"in_flight"`
+	// OldestReadyAt is when the oldest ready message was enqueued
+	OldestReadyAt *time.Time `json:"oldest_ready_at"`
+}
+
+// Message is a message delivered to a consumer group
+type Message struct {
+	ID               int64             `json:"id"`
+	Body             json.RawMessage   `json:"body"`
+	Headers          map[string]string `json:"headers"`
+	ReadCount        int               `json:"read_count"`
+	EnqueuedAt       time.Time         `json:"enqueued_at"`
+	VisibleAt        time.Time         `json:"visible_at"`
+	LastError        *string           `json:"last_error,omitempty"`
+	DeadLetteredFrom *string           `json:"dead_lettered_from,omitempty"`
+}
+
+// OutgoingMessage is a message to send
+type OutgoingMessage struct {
+	Body    json.RawMessage   `json:"body"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// Delay is the number of seconds before the message can be read
+	Delay int `json:"delay_seconds,omitempty"`
+}
+
+// Store persists queues and their messages
+type Store struct {
+	db *pgxpool.Pool
+}
+
+// NewStore creates a queue store
+func NewStore(db *pgxpool.Pool) *Store {
+	return &Store{db: db}
+}
+
+const queueColumns = `id::text, name, visibility_timeout_seconds, max_deliveries, dead_letter_queue,
+	created_by::text, created_at, updated_at`
+
+func scanQueue(row pgx.Row) (Queue, error) {
+	var q Queue
+	err := row.Scan(&q.ID, &q.Name, &q.VisibilityTimeout, &q.MaxDeliveries, &q.DeadLetterQueue,
+		&q.CreatedBy, &q.CreatedAt, &q.UpdatedAt)
+	return q, err
+}
+
+// groupStats returns the consumer groups of the queues with their message counts, by queue ID
+func (s *Store) groupStats(ctx context.Context, queueIDs []string) (map[string][]GroupStats, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT g.queue_id::text, g.name,
+		       COUNT(m.id) FILTER (WHERE m.visible_at <= NOW()),
+		       COUNT(m.id) FILTER (WHERE m.visible_at > NOW()),
+		       MIN(m.enqueued_at) FILTER (WHERE m.visible_at <= NOW())
+		FROM api.queue_consumer_groups g
+		LEFT JOIN api.queue_messages m ON m.queue_id = g.queue_id AND m.consumer_group = g.name
+		WHERE g.queue_id::text = ANY($1)
+		GROUP BY g.queue_id, g.name
+		ORDER BY g.name
+	`, queueIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read consumer groups: %w", err)
+	}
+	defer rows.Close()
+
+	groups := make(map[string][]GroupStats)
+	for rows.Next() {
+		var queueID string
+		var g GroupStats
+		if err := rows.Scan(&queueID, &g.Name, &g.Ready, &g.InFlight, &g.OldestReadyAt); err != nil {
+			return nil, fmt.Errorf("failed to scan consumer group: %w", err)
+		}
+		groups[queueID] = append(groups[queueID], g)
+	}
+	return groups, rows.Err()
+}
+
+// ListQueues returns every queue with its consumer groups, ordered by name
+func (s *Store) ListQueues(ctx context.Context) ([]Queue, error) {
+	rows, err := s.db.Query(ctx, `SELECT `+queueColumns+` FROM api.queues ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queues: %w", err)
+	}
+	defer rows.Close()
+
+	queues := []Queue{}
+	ids := []string{}
+	for rows.Next() {
+		q, err := scanQueue(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan queue: %w", err)
+		}
+		queues = append(queues, q)
+		ids = append(ids, q.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list queues: %w", err)
+	}
+	rows.Close()
+
+	groups, err := s.groupStats(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range queues {
+		queues[i].Groups = groups[queues[i].ID]
+	}
+	return queues, nil
+}
+
+// GetQueue returns a queue by name with its consumer groups
+func (s *Store) GetQueue(ctx context.Context, name string) (*Queue, error) {
+	q, err := scanQueue(s.db.QueryRow(ctx, `SELECT `+queueColumns+` FROM api.queues WHERE name = $1`, name))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrQueueNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue: %w", err)
+	}
+
+	groups, err := s.groupStats(ctx, []string{q.ID})
+	if err != nil {
+		return nil, err
+	}
+	q.Groups = groups[q.ID]
+	return &q, nil
+}
+
+// CreateQueue stores a new queue with the default consumer group. The queue must already
+// be validated.
+func (s *Store) CreateQueue(ctx context.Context, q Queue, createdBy *uuid.UUID) (*Queue, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create queue: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	created, err := scanQueue(tx.QueryRow(ctx, `
+		INSERT INTO api.queues (name, visibility_timeout_seconds, max_deliveries, dead_letter_queue, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+queueColumns,
+		q.Name, q.VisibilityTimeout, q.MaxDeliveries, q.DeadLetterQueue, createdBy))
+	switch {
+	case database.IsUniqueViolation(err):
+		return nil, ErrQueueExists
+	case database.IsForeignKeyViolation(err):
+		return nil, ErrDeadLetterQueueNotFound
+	case err != nil:
+		return nil, fmt.Errorf("failed to create queue: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO api.queue_consumer_groups (queue_id, name) VALUES ($1, $2)
+	`, created.ID, DefaultGroup); err != nil {
+		return nil, fmt.Errorf("failed to create default consumer group: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create queue: %w", err)
+	}
+	created.Groups = []GroupStats{{Name: DefaultGroup}}
+	return &created, nil
+}
+
+// UpdateQueue changes the delivery settings of a queue. The queue must already be validated.
+func (s *Store) UpdateQueue(ctx context.Context, q Queue) (*Queue, error) {
+	updated, err := scanQueue(s.db.QueryRow(ctx, `
+		UPDATE api.queues
+		SET visibility_timeout_seconds = $2, max_deliveries = $3, dead_letter_queue = $4, updated_at = NOW()
+		WHERE name = $1
+		RETURNING `+queueColumns,
+		q.Name, q.VisibilityTimeout, q.MaxDeliveries, q.DeadLetterQueue))
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, ErrQueueNotFound
+	case database.IsForeignKeyViolation(err):
+		return nil, ErrDeadLetterQueueNotFound
+	case err != nil:
+		return nil, fmt.Errorf("failed to update queue: %w", err)
+	}
+
+	groups, err := s.groupStats(ctx, []string{updated.ID})
+	if err != nil {
+		return nil, err
+	}
+	updated.Groups = groups[updated.ID]
+	return &updated, nil
+}
+
+// DeleteQueue deletes a queue with its consumer groups and messages. Queues using it as
+// their dead-letter queue no longer have one.
+func (s *Store) DeleteQueue(ctx context.Context, name string) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM api.queues WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete queue: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrQueueNotFound
+	}
+	return nil
+}
+
+// CreateGroup adds a consumer group to a queue. The group receives the messages sent from
+// now on.
+func (s *Store) CreateGroup(ctx context.Context, queue, group string) error {
+	tag, err := s.db.Exec(ctx, `
+		INSERT INTO api.queue_consumer_groups (queue_id, name)
+		SELECT id, $2 FROM api.queues WHERE name = $1
+	`, queue, group)
+	if database.IsUniqueViolation(err) {
+		return ErrGroupExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create consumer group: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrQueueNotFound
+	}
+	return nil
+}
+
+// DeleteGroup deletes a consumer group and its pending messages
+func (s *Store) DeleteGroup(ctx context.Context, queue, group string) error {
+	tag, err := s.db.Exec(ctx, `
+		DELETE FROM api.queue_consumer_groups g
+		USING api.queues q
+		WHERE g.queue_id = q.id AND q.name = $1 AND g.name = $2
+	`, queue, group)
+	if err != nil {
+		return fmt.Errorf("failed to delete consumer group: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrGroupNotFound
+	}
+	return nil
+}
+
+// queueID returns the ID of a queue by name
+func (s *Store) queueID(ctx context.Context, name string) (string, error) {
+	var id string
+	err := s.db.QueryRow(ctx, `SELECT id::text FROM api.queues WHERE name = $1`, name).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrQueueNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get queue: %w", err)
+	}
+	return id, nil
+}
+
+// Send enqueues messages for every consumer group of a queue, returning the number of
+// messages enqueued per group
+func (s *Store) Send(ctx context.Context, queue string, messages []OutgoingMessage) (int, error) {
+	bodies := make([]string, len(messages))
+	headers := make([]string, len(messages))
+	delays := make([]int32, len(messages))
+	for i, m := range messages {
+		bodies[i] = string(m.Body)
+		encoded, err := json.Marshal(m.Headers)
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode message headers: %w", err)
+		}
+		if m.Headers == nil {
+			encoded = []byte("{}")
+		}
+		headers[i] = string(encoded)
+		delays[i] = int32(m.Delay)
+	}
+
+	queueID, err := s.queueID(ctx, queue)
+	if err != nil {
+		return 0, err
+	}
+
+	// unnest keeps the messages in order, so IDs follow the order they were sent in
+	_, err = s.db.Exec(ctx, `
+		INSERT INTO api.queue_messages (queue_id, consumer_group, body, headers, visible_at)
+		SELECT g.queue_id, g.name, m.body::jsonb, m.headers::jsonb, NOW() + make_interval(secs => m.delay)
+		FROM unnest($2::text[], $3::text[], $4::int[]) WITH ORDINALITY AS m(body, headers, delay, ord)
+		CROSS JOIN api.queue_consumer_groups g
+		WHERE g.queue_id = $1
+		ORDER BY m.ord, g.name
+	`, queueID, bodies, headers, delays)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send messages: %w", err)
+	}
+	return len(messages), nil
+}
+
+// ReadOptions selects the messages returned by Read
+type ReadOptions struct {
+	Group string
+	Limit int
+	// VisibilityTimeout overrides the queue's visibility timeout in seconds when positive
+	VisibilityTimeout int
+}
+
+// Read delivers up to opts.Limit visible messages of a consumer group, oldest first, and
+// hides them from other consumers for the visibility timeout. Visible messages that were
+// already delivered max_deliveries times are first moved to the dead-letter queue, or
+// discarded if the queue has none.
+func (s *Store) Read(ctx context.Context, queue string, opts ReadOptions) ([]Message, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read messages: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var q Queue
+	var groupExists bool
+	err = tx.QueryRow(ctx, `
+		SELECT q.id::text, q.visibility_timeout_seconds, q.max_deliveries, q.dead_letter_queue,
+		       EXISTS(SELECT 1 FROM api.queue_consumer_groups g WHERE g.queue_id = q.id AND g.name = $2)
+		FROM api.queues q WHERE q.name = $1
+	`, queue, opts.Group).Scan(&q.ID, &q.VisibilityTimeout, &q.MaxDeliveries, &q.DeadLetterQueue, &groupExists)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrQueueNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read messages: %w", err)
+	}
+	if !groupExists {
+		return nil, ErrGroupNotFound
+	}
+
+	dead, err := s.deadLetter(ctx, tx, queue, q, opts.Group)
+	if err != nil {
+		return nil, err
+	}
+	if dead > 0 {
+		event := log.Warn().Str("queue", queue).Str("group", opts.Group).Int64("messages", dead)
+		if q.DeadLetterQueue != nil {
+			event.Str("dead_letter_queue", *q.DeadLetterQueue).Msg("Moved undeliverable messages to the dead-letter queue")
+		} else {
+			event.Msg("Discarded undeliverable messages, the queue has no dead-letter queue")
+		}
+	}
+
+	visibilityTimeout := q.VisibilityTimeout
+	if opts.VisibilityTimeout > 0 {
+		visibilityTimeout = opts.VisibilityTimeout
+	}
+
+	rows, err := tx.Query(ctx, `
+		WITH next AS (
+			SELECT id FROM api.queue_messages
+			WHERE queue_id = $1 AND consumer_group = $2 AND visible_at <= NOW()
+			ORDER BY id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE api.queue_messages m
+		SET visible_at = NOW() + make_interval(secs => $4), read_count = m.read_count + 1, last_read_at = NOW()
+		FROM next
+		WHERE m.id = next.id
+		RETURNING m.id, m.body, m.headers, m.read_count, m.enqueued_at, m.visible_at, m.last_error, m.dead_lettered_from
+	`, q.ID, opts.Group, opts.Limit, visibilityTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read messages: %w", err)
+	}
+
+	messages := []Message{}
+	for rows.Next() {
+		var m Message
+		if err := rows.Scan(&m.ID, &m.Body, &m.Headers, &m.ReadCount, &m.EnqueuedAt, &m.VisibleAt,
+			&m.LastError, &m.DeadLetteredFrom); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		messages = append(messages, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read messages: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to read messages: %w", err)
+	}
+	slices.SortFunc(messages, func(a, b Message) int { return cmp.Compare(a.ID, b.ID) })
+	return messages, nil
+}
+
+// deadLetter removes the visible messages of a group that were delivered max_deliveries
+// times and sends them to every consumer group of the dead-letter queue
+func (s *Store) deadLetter(ctx context.Context, tx pgx.Tx, queue string, q Queue, group string) (int64, error) {
+	if q.DeadLetterQueue == nil {
+		tag, err := tx.Exec(ctx, `
+			DELETE FROM api.queue_messages
+			WHERE queue_id = $1 AND consumer_group = $2 AND visible_at <= NOW() AND read_count >= $3
+		`, q.ID, group, q.MaxDeliveries)
+		if err != nil {
+			return 0, fmt.Errorf("failed to discard undeliverable messages: %w", err)
+		}
+		return tag.RowsAffected(), nil
+	}
+
+	var moved int64
+	err := tx.QueryRow(ctx, `
+		WITH dead AS (
+			DELETE FROM api.queue_messages
+			WHERE queue_id = $1 AND consumer_group = $2 AND visible_at <= NOW() AND read_count >= $3
+			RETURNING id, body, headers, last_error
+		), moved AS (
+			INSERT INTO api.queue_messages (queue_id, consumer_group, body, headers, last_error, dead_lettered_from)
+			SELECT g.queue_id, g.name, d.body, d.headers, d.last_error, $4
+			FROM dead d
+			CROSS JOIN api.queue_consumer_groups g
+			JOIN api.queues dlq ON dlq.id = g.queue_id
+			WHERE dlq.name = $5
+			ORDER BY d.id, g.name
+			RETURNING 1
+		)
+		SELECT COUNT(*) FROM dead
+	`, q.ID, group, q.MaxDeliveries, queue, *q.DeadLetterQueue).Scan(&moved)
+	if err != nil {
+		return 0, fmt.Errorf("failed to dead-letter messages: %w", err)
+	}
+	return moved, nil
+}
+
+// Ack deletes messages of a consumer group that were processed, returning how many existed
+func (s *Store) Ack(ctx context.Context, queue, group string, ids []int64) (int64, error) {
+	tag, err := s.db.Exec(ctx, `
+		DELETE FROM api.queue_messages m
+		USING api.queues q
+		WHERE m.queue_id = q.id AND q.name = $1 AND m.consumer_group = $2 AND m.id = ANY($3)
+	`, queue, group, ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to acknowledge messages: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Nack returns messages of a consumer group to the queue, visible again after delay seconds,
+// recording the consumer's error. It returns how many messages existed.
+func (s *Store) Nack(ctx context.Context, queue, group string, ids []int64, delay int, reason *string) (int64, error) {
+	tag, err := s.db.Exec(ctx, `
+		UPDATE api.queue_messages m
+		SET visible_at = NOW() + make_interval(secs => $4), last_error = COALESCE($5, m.last_error)
+		FROM api.queues q
+		WHERE m.queue_id = q.id AND q.name = $1 AND m.consumer_group = $2 AND m.id = ANY($3)
+	`, queue, group, ids, delay, reason)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reject messages: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Purge deletes all messages of a queue, or of one consumer group if group is not empty,
+// returning how many were deleted
+func (s *Store) Purge(ctx context.Context, queue, group string) (int64, error) {
+	queueID, err := s.queueID(ctx, queue)
+	if err != nil {
+		return 0, err
+	}
+	tag, err := s.db.Exec(ctx, `
+		DELETE FROM api.queue_messages
+		WHERE queue_id = $1 AND ($2 = '' OR consumer_group = $2)
+	`, queueID, group)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge queue: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
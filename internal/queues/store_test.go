@@ -0,0 +1,53 @@
+package queues
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidName(t *testing.T) {
+	for _, name := range []string{"emails", "emails-dead", "a", "order_events_2"} {
+		assert.True(t, ValidName(name), name)
+	}
+	for _, name := range []string{"", "Emails", "-emails", "_emails", "emails.dead", "e mails", string(make([]byte, 64))} {
+		assert.False(t, ValidName(name), name)
+	}
+}
+
+func TestQueue_Validate(t *testing.T) {
+	t.Run("fills in defaults", func(t *testing.T) {
+		q := Queue{Name: "emails"}
+		require.NoError(t, q.Validate())
+		assert.Equal(t, DefaultVisibilityTimeout, q.VisibilityTimeout)
+		assert.Equal(t, DefaultMaxDeliveries, q.MaxDeliveries)
+	})
+
+	t.Run("empty dead-letter queue is removed", func(t *testing.T) {
+		dlq := ""
+		q := Queue{Name: "emails", DeadLetterQueue: &dlq}
+		require.NoError(t, q.Validate())
+		assert.Nil(t, q.DeadLetterQueue)
+	})
+
+	self := "emails"
+	tests := []struct {
+		name  string
+		queue Queue
+		msg   string
+	}{
+		{"invalid name", Queue{Name: "Emails"}, "name must be"},
+		{"negative visibility timeout", Queue{Name: "emails", VisibilityTimeout: -1}, "visibility_timeout"},
+		{"visibility timeout above 12 hours", Queue{Name: "emails", VisibilityTimeout: 43201}, "visibility_timeout"},
+		{"too many deliveries", Queue{Name: "emails", MaxDeliveries: 1001}, "max_deliveries"},
+		{"own dead-letter queue", Queue{Name: "emails", DeadLetterQueue: &self}, "own dead-letter queue"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.queue.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.msg)
+		})
+	}
+}
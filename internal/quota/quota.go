@@ -0,0 +1,179 @@
+// Package quota enforces daily, monthly and total usage quotas of API calls, stored bytes and
+// AI tokens attached to users and organizations.
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Metric is a kind of usage a quota limits
+type Metric string
+
+const (
+	// MetricAPICalls counts API requests. It is counted by the server as requests pass the
+	// quota middleware.
+	MetricAPICalls Metric = "api_calls"
+
+	// MetricStorageBytes is the size of the stored objects owned by a user or in the buckets
+	// of an organization. It is measured from storage.objects.
+	MetricStorageBytes Metric = "storage_bytes"
+
+	// MetricAITokens is the prompt and completion tokens of AI chats plus the tokens of
+	// embedding requests. It is measured from ai.messages and ai.embedding_usage.
+	MetricAITokens Metric = "ai_tokens"
+)
+
+// ValidMetrics lists the metrics quotas can limit
+var ValidMetrics = []Metric{MetricAPICalls, MetricStorageBytes, MetricAITokens}
+
+// Counted reports whether the server counts the metric itself as requests pass, instead of
+// measuring it from the tables that record the usage
+func (m Metric) Counted() bool {
+	return m == MetricAPICalls
+}
+
+// Period is the span a quota's usage accumulates over
+type Period string
+
+const (
+	PeriodDay   Period = "day"   // resets at midnight UTC
+	PeriodMonth Period = "month" // resets on the first of the month, UTC
+	PeriodTotal Period = "total" // never resets, for levels like stored bytes
+)
+
+// ValidPeriods lists the periods quotas can use
+var ValidPeriods = []Period{PeriodDay, PeriodMonth, PeriodTotal}
+
+// Bounds returns the start and end of the period containing t. A total period has a zero
+// start and end.
+func (p Period) Bounds(t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	switch p {
+	case PeriodDay:
+		start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 0, 1)
+	case PeriodMonth:
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	default:
+		return time.Time{}, time.Time{}
+	}
+}
+
+// defaultPeriod is the period usage is reported over when a subject has no quota of the metric
+func (m Metric) defaultPeriod() Period {
+	if m == MetricStorageBytes {
+		return PeriodTotal
+	}
+	return PeriodMonth
+}
+
+// SubjectType is the kind of subject a quota is attached to
+type SubjectType string
+
+const (
+	SubjectUser         SubjectType = "user"
+	SubjectOrganization SubjectType = "organization"
+)
+
+// Subject is a user or organization whose usage counts against its quotas
+type Subject struct {
+	Type SubjectType `json:"subject_type"`
+	ID   string      `json:"subject_id"`
+}
+
+// Validate checks the subject type and that the ID is a UUID
+func (s Subject) Validate() error {
+	if s.Type != SubjectUser && s.Type != SubjectOrganization {
+		return fmt.Errorf("invalid subject type %q, expected %q or %q", s.Type, SubjectUser, SubjectOrganization)
+	}
+	if _, err := uuid.Parse(s.ID); err != nil {
+		return fmt.Errorf("invalid subject ID %q: expected a UUID", s.ID)
+	}
+	return nil
+}
+
+// ErrQuotaNotFound is returned when a quota to delete does not exist
+var ErrQuotaNotFound = errors.New("quota not found")
+
+// Quota limits the usage of a metric by a subject over a period
+type Quota struct {
+	ID          string      `json:"id"`
+	SubjectType SubjectType `json:"subject_type"`
+	SubjectID   string      `json:"subject_id"`
+	Metric      Metric      `json:"metric"`
+	Period      Period      `json:"period"`
+	Limit       int64       `json:"limit"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// Subject returns the subject the quota applies to
+func (q Quota) Subject() Subject {
+	return Subject{Type: q.SubjectType, ID: q.SubjectID}
+}
+
+// Validate checks the subject, metric, period and limit. Stored bytes can only be limited
+// over the total period, the other metrics only per day or month.
+func (q Quota) Validate() error {
+	if err := q.Subject().Validate(); err != nil {
+		return err
+	}
+	if !slices.Contains(ValidMetrics, q.Metric) {
+		return fmt.Errorf("invalid metric %q, expected one of %v", q.Metric, ValidMetrics)
+	}
+	if !slices.Contains(ValidPeriods, q.Period) {
+		return fmt.Errorf("invalid period %q, expected one of %v", q.Period, ValidPeriods)
+	}
+	if (q.Metric == MetricStorageBytes) != (q.Period == PeriodTotal) {
+		if q.Metric == MetricStorageBytes {
+			return fmt.Errorf("%s quotas must use the %q period", q.Metric, PeriodTotal)
+		}
+		return fmt.Errorf("%s quotas must use the %q or %q period", q.Metric, PeriodDay, PeriodMonth)
+	}
+	if q.Limit < 0 {
+		return fmt.Errorf("limit must not be negative")
+	}
+	return nil
+}
+
+// Usage is a subject's usage of a metric over the current period. Limit and Remaining are nil
+// when the subject has no quota of the metric.
+type Usage struct {
+	SubjectType SubjectType `json:"subject_type"`
+	SubjectID   string      `json:"subject_id"`
+	Metric      Metric      `json:"metric"`
+	Period      Period      `json:"period"`
+	Limit       *int64      `json:"limit"`
+	Used        int64       `json:"used"`
+	Remaining   *int64      `json:"remaining"`
+	PeriodStart *time.Time  `json:"period_start,omitempty"`
+	ResetsAt    *time.Time  `json:"resets_at,omitempty"`
+}
+
+// newUsage builds the usage of a metric over the period containing now, limited by limit when not nil
+func newUsage(subject Subject, metric Metric, period Period, limit *int64, used int64, now time.Time) Usage {
+	usage := Usage{
+		SubjectType: subject.Type,
+		SubjectID:   subject.ID,
+		Metric:      metric,
+		Period:      period,
+		Limit:       limit,
+		Used:        used,
+	}
+	if limit != nil {
+		remaining := max(*limit-used, 0)
+		usage.Remaining = &remaining
+	}
+	if period != PeriodTotal {
+		start, end := period.Bounds(now)
+		usage.PeriodStart = &start
+		usage.ResetsAt = &end
+	}
+	return usage
+}
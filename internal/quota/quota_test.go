@@ -0,0 +1,64 @@
+package quota
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriod_Bounds(t *testing.T) {
+	at := time.Date(2026, 12, 31, 23, 30, 0, 0, time.FixedZone("CET", 3600))
+
+	start, end := PeriodDay.Bounds(at)
+	assert.Equal(t, time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), end)
+
+	start, end = PeriodMonth.Bounds(at)
+	assert.Equal(t, time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), end)
+
+	start, end = PeriodTotal.Bounds(at)
+	assert.True(t, start.IsZero())
+	assert.True(t, end.IsZero())
+}
+
+func TestQuota_Validate(t *testing.T) {
+	subjectID := "3f1c9a52-7d3e-4c41-9f0a-2b6d8e5a1c70"
+	valid := []Quota{
+		{SubjectType: SubjectUser, SubjectID: subjectID, Metric: MetricAPICalls, Period: PeriodDay, Limit: 1000},
+		{SubjectType: SubjectOrganization, SubjectID: subjectID, Metric: MetricAITokens, Period: PeriodMonth, Limit: 0},
+		{SubjectType: SubjectOrganization, SubjectID: subjectID, Metric: MetricStorageBytes, Period: PeriodTotal, Limit: 1 << 30},
+	}
+	for _, q := range valid {
+		assert.NoError(t, q.Validate(), "%s %s", q.Metric, q.Period)
+	}
+
+	for name, q := range map[string]Quota{
+		"unknown subject type":  {SubjectType: "team", SubjectID: subjectID, Metric: MetricAPICalls, Period: PeriodDay},
+		"subject ID not a UUID": {SubjectType: SubjectUser, SubjectID: "alice", Metric: MetricAPICalls, Period: PeriodDay},
+		"unknown metric":        {SubjectType: SubjectUser, SubjectID: subjectID, Metric: "emails", Period: PeriodDay},
+		"unknown period":        {SubjectType: SubjectUser, SubjectID: subjectID, Metric: MetricAPICalls, Period: "week"},
+		"storage per month":     {SubjectType: SubjectUser, SubjectID: subjectID, Metric: MetricStorageBytes, Period: PeriodMonth},
+		"api calls in total":    {SubjectType: SubjectUser, SubjectID: subjectID, Metric: MetricAPICalls, Period: PeriodTotal},
+		"negative limit":        {SubjectType: SubjectUser, SubjectID: subjectID, Metric: MetricAPICalls, Period: PeriodDay, Limit: -1},
+	} {
+		assert.Error(t, q.Validate(), name)
+	}
+}
+
+func TestNewUsage(t *testing.T) {
+	subject := Subject{Type: SubjectUser, ID: "3f1c9a52-7d3e-4c41-9f0a-2b6d8e5a1c70"}
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	limit := int64(100)
+	usage := newUsage(subject, MetricAPICalls, PeriodMonth, &limit, 130, now)
+	assert.Equal(t, int64(0), *usage.Remaining, "remaining never goes negative")
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), *usage.PeriodStart)
+	assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), *usage.ResetsAt)
+
+	usage = newUsage(subject, MetricStorageBytes, PeriodTotal, nil, 42, now)
+	assert.Nil(t, usage.Limit)
+	assert.Nil(t, usage.Remaining)
+	assert.Nil(t, usage.ResetsAt, "total usage does not reset")
+}
@@ -0,0 +1,586 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// counterKey identifies a counted usage row in api.quota_counters
+type counterKey struct {
+	Subject Subject
+	Metric  Metric
+	Period  Period
+	Start   time.Time
+}
+
+// counter is the cached value of a counted usage row
+type counter struct {
+	used     int64     // usage in the database as of loadedAt, plus usage being flushed
+	pending  int64     // usage counted since the last flush
+	loadedAt time.Time // zero until the row has been read or written
+}
+
+// measureKey identifies a measured usage, Start is zero for the total period
+type measureKey struct {
+	Subject Subject
+	Metric  Metric
+	Start   time.Time
+}
+
+// measurement is a cached measured usage
+type measurement struct {
+	used       int64
+	measuredAt time.Time
+}
+
+// cachedQuotas are the cached quotas of a subject
+type cachedQuotas struct {
+	quotas   []Quota
+	loadedAt time.Time
+}
+
+// Service enforces usage quotas. Quotas and usage are cached in memory for the cache TTL so
+// checks rarely wait on the database. API calls are counted in memory and periodically added
+// to api.quota_counters; the counters are re-read after the cache TTL to pick up the usage
+// counted by other instances, so a quota can be overshot by what other instances count
+// within one TTL. Stored bytes and AI tokens are measured from the tables that record them.
+type Service struct {
+	db            *pgxpool.Pool
+	flushInterval time.Duration
+	cacheTTL      time.Duration
+
+	mu       sync.Mutex
+	quotas   map[Subject]cachedQuotas
+	counters map[counterKey]*counter
+	measured map[measureKey]*measurement
+
+	// Database access; replaced in tests
+	loadQuotas  func(ctx context.Context, subject Subject) ([]Quota, error)
+	loadCounter func(ctx context.Context, key counterKey) (int64, error)
+	addCounters func(ctx context.Context, batch map[counterKey]int64) (map[counterKey]int64, error)
+	measure     func(ctx context.Context, subject Subject, metric Metric, since time.Time) (int64, error)
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	running   bool
+	runningMu sync.Mutex
+}
+
+// NewService creates a quota service that flushes counted usage every flushInterval and
+// caches quotas and usage for cacheTTL
+func NewService(db *pgxpool.Pool, flushInterval, cacheTTL time.Duration) *Service {
+	if flushInterval <= 0 {
+		flushInterval = 10 * time.Second
+	}
+	if cacheTTL <= 0 {
+		cacheTTL = time.Minute
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s := &Service{
+		db:            db,
+		flushInterval: flushInterval,
+		cacheTTL:      cacheTTL,
+		quotas:        make(map[Subject]cachedQuotas),
+		counters:      make(map[counterKey]*counter),
+		measured:      make(map[measureKey]*measurement),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+	s.loadQuotas = s.loadQuotasDB
+	s.loadCounter = s.loadCounterDB
+	s.addCounters = s.addCountersDB
+	s.measure = s.measureDB
+	return s
+}
+
+// Check returns the usage of the first quota of metric that amount more would exceed for one
+// of subjects, or nil when every quota has room. A quota that is used up is exceeded even by
+// an amount of zero, which checks that some quota is left before usage of unknown size.
+func (s *Service) Check(ctx context.Context, subjects []Subject, metric Metric, amount int64) (*Usage, error) {
+	now := time.Now()
+	for _, subject := range subjects {
+		quotas, err := s.quotasOf(ctx, subject)
+		if err != nil {
+			return nil, err
+		}
+		for _, q := range quotas {
+			if q.Metric != metric {
+				continue
+			}
+			used, err := s.used(ctx, subject, metric, q.Period, now)
+			if err != nil {
+				return nil, err
+			}
+			if used >= q.Limit || used+amount > q.Limit {
+				limit := q.Limit
+				usage := newUsage(subject, metric, q.Period, &limit, used, now)
+				return &usage, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Record adds amount to the usage of metric by subjects. Counted metrics are added to the
+// daily and monthly counters that are flushed to the database. For measured metrics the
+// amount is added to the cached measurements, so usage is not missed until the next one.
+func (s *Service) Record(subjects []Subject, metric Metric, amount int64) {
+	if amount <= 0 {
+		return
+	}
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, subject := range subjects {
+		if metric.Counted() {
+			for _, period := range []Period{PeriodDay, PeriodMonth} {
+				start, _ := period.Bounds(now)
+				key := counterKey{Subject: subject, Metric: metric, Period: period, Start: start}
+				c, ok := s.counters[key]
+				if !ok {
+					c = &counter{}
+					s.counters[key] = c
+				}
+				c.pending += amount
+			}
+			continue
+		}
+		for _, period := range ValidPeriods {
+			start, _ := period.Bounds(now)
+			if m, ok := s.measured[measureKey{Subject: subject, Metric: metric, Start: start}]; ok {
+				m.used += amount
+			}
+		}
+	}
+}
+
+// Usage returns the usage of every metric by subject over the current period of each of its
+// quotas. Metrics without a quota are reported per month, stored bytes in total.
+func (s *Service) Usage(ctx context.Context, subject Subject) ([]Usage, error) {
+	quotas, err := s.quotasOf(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	usages := []Usage{}
+	for _, metric := range ValidMetrics {
+		limited := false
+		for _, q := range quotas {
+			if q.Metric != metric {
+				continue
+			}
+			limited = true
+			used, err := s.used(ctx, subject, metric, q.Period, now)
+			if err != nil {
+				return nil, err
+			}
+			limit := q.Limit
+			usages = append(usages, newUsage(subject, metric, q.Period, &limit, used, now))
+		}
+		if !limited {
+			period := metric.defaultPeriod()
+			used, err := s.used(ctx, subject, metric, period, now)
+			if err != nil {
+				return nil, err
+			}
+			usages = append(usages, newUsage(subject, metric, period, nil, used, now))
+		}
+	}
+	return usages, nil
+}
+
+// used returns the usage of metric by subject over the period containing now
+func (s *Service) used(ctx context.Context, subject Subject, metric Metric, period Period, now time.Time) (int64, error) {
+	start, _ := period.Bounds(now)
+	if metric.Counted() {
+		return s.countedUsage(ctx, counterKey{Subject: subject, Metric: metric, Period: period, Start: start})
+	}
+	return s.measuredUsage(ctx, measureKey{Subject: subject, Metric: metric, Start: start})
+}
+
+// countedUsage returns a counter, reading it from the database when it is not cached
+func (s *Service) countedUsage(ctx context.Context, key counterKey) (int64, error) {
+	s.mu.Lock()
+	if c, ok := s.counters[key]; ok && !c.loadedAt.IsZero() && time.Since(c.loadedAt) < s.cacheTTL {
+		used := c.used + c.pending
+		s.mu.Unlock()
+		return used, nil
+	}
+	s.mu.Unlock()
+
+	used, err := s.loadCounter(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.counters[key]
+	if !ok {
+		c = &counter{}
+		s.counters[key] = c
+	}
+	c.used = used
+	c.loadedAt = time.Now()
+	return c.used + c.pending, nil
+}
+
+// measuredUsage returns a measured usage, measuring it when it is not cached
+func (s *Service) measuredUsage(ctx context.Context, key measureKey) (int64, error) {
+	s.mu.Lock()
+	if m, ok := s.measured[key]; ok && time.Since(m.measuredAt) < s.cacheTTL {
+		used := m.used
+		s.mu.Unlock()
+		return used, nil
+	}
+	s.mu.Unlock()
+
+	used, err := s.measure(ctx, key.Subject, key.Metric, key.Start)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	s.measured[key] = &measurement{used: used, measuredAt: time.Now()}
+	s.mu.Unlock()
+	return used, nil
+}
+
+// quotasOf returns the quotas of subject, reading them from the database when they are not cached
+func (s *Service) quotasOf(ctx context.Context, subject Subject) ([]Quota, error) {
+	s.mu.Lock()
+	cached, ok := s.quotas[subject]
+	s.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < s.cacheTTL {
+		return cached.quotas, nil
+	}
+
+	quotas, err := s.loadQuotas(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.quotas[subject] = cachedQuotas{quotas: quotas, loadedAt: time.Now()}
+	s.mu.Unlock()
+	return quotas, nil
+}
+
+// invalidate drops the cached quotas of subject after they were changed
+func (s *Service) invalidate(subject Subject) {
+	s.mu.Lock()
+	delete(s.quotas, subject)
+	s.mu.Unlock()
+}
+
+// Start begins periodic flushing
+func (s *Service) Start() {
+	s.runningMu.Lock()
+	if s.running {
+		s.runningMu.Unlock()
+		return
+	}
+	s.running = true
+	if s.ctx.Err() != nil {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+	}
+	s.runningMu.Unlock()
+
+	s.wg.Add(1)
+	go s.run()
+
+	log.Info().
+		Dur("flush_interval", s.flushInterval).
+		Dur("cache_ttl", s.cacheTTL).
+		Msg("Usage quota service started")
+}
+
+// Stop stops periodic flushing and writes any pending usage
+func (s *Service) Stop() {
+	s.runningMu.Lock()
+	if !s.running {
+		s.runningMu.Unlock()
+		return
+	}
+	s.running = false
+	s.runningMu.Unlock()
+
+	s.cancel()
+	s.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.Flush(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to flush quota usage on shutdown")
+	}
+
+	log.Info().Msg("Usage quota service stopped")
+}
+
+// run flushes pending usage and prunes the caches on every tick
+func (s *Service) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Flush(s.ctx); err != nil {
+				log.Warn().Err(err).Msg("Failed to flush quota usage")
+			}
+			s.prune()
+		}
+	}
+}
+
+// Flush adds pending counted usage to the database and refreshes the cached counters with
+// the totals, which include the usage flushed by other instances. On failure the usage is
+// kept pending so it is retried with the next flush instead of being lost.
+func (s *Service) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	batch := make(map[counterKey]int64)
+	for key, c := range s.counters {
+		if c.pending > 0 {
+			batch[key] = c.pending
+			// Moved into used so checks keep seeing it while it is being written
+			c.used += c.pending
+			c.pending = 0
+		}
+	}
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	totals, err := s.addCounters(ctx, batch)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		for key, n := range batch {
+			c, ok := s.counters[key]
+			if !ok {
+				c = &counter{}
+				s.counters[key] = c
+			}
+			c.used -= n
+			c.pending += n
+		}
+		return err
+	}
+
+	now := time.Now()
+	for key, used := range totals {
+		if c, ok := s.counters[key]; ok {
+			c.used = used
+			c.loadedAt = now
+		}
+	}
+	return nil
+}
+
+// prune drops cache entries that would be re-read on their next use anyway
+func (s *Service) prune() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, c := range s.counters {
+		if c.pending == 0 && time.Since(c.loadedAt) >= s.cacheTTL {
+			delete(s.counters, key)
+		}
+	}
+	for key, m := range s.measured {
+		if time.Since(m.measuredAt) >= s.cacheTTL {
+			delete(s.measured, key)
+		}
+	}
+	for subject, cached := range s.quotas {
+		if time.Since(cached.loadedAt) >= s.cacheTTL {
+			delete(s.quotas, subject)
+		}
+	}
+}
+
+// ListQuotas returns the quotas of subject. A zero subject type or ID matches every type or ID.
+func (s *Service) ListQuotas(ctx context.Context, subject Subject) ([]Quota, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id::text, subject_type, subject_id::text, metric, period, quota_limit, created_at, updated_at
+		FROM api.usage_quotas
+		WHERE ($1 = '' OR subject_type = $1) AND ($2 = '' OR subject_id::text = $2)
+		ORDER BY subject_type, subject_id, metric, period
+	`, string(subject.Type), subject.ID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, scanQuota)
+}
+
+// SetQuota creates the quota of a subject, metric and period, or replaces its limit
+func (s *Service) SetQuota(ctx context.Context, q Quota) (*Quota, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(ctx, `
+		INSERT INTO api.usage_quotas (subject_type, subject_id, metric, period, quota_limit)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (subject_type, subject_id, metric, period)
+		DO UPDATE SET quota_limit = EXCLUDED.quota_limit, updated_at = NOW()
+		RETURNING id::text, subject_type, subject_id::text, metric, period, quota_limit, created_at, updated_at
+	`, string(q.SubjectType), q.SubjectID, string(q.Metric), string(q.Period), q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	saved, err := pgx.CollectExactlyOneRow(rows, scanQuota)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate(saved.Subject())
+	return &saved, nil
+}
+
+// DeleteQuota deletes a quota by ID
+func (s *Service) DeleteQuota(ctx context.Context, id string) error {
+	var subject Subject
+	err := s.db.QueryRow(ctx, `
+		DELETE FROM api.usage_quotas WHERE id = $1
+		RETURNING subject_type, subject_id::text
+	`, id).Scan(&subject.Type, &subject.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrQuotaNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	s.invalidate(subject)
+	return nil
+}
+
+// scanQuota scans a row of api.usage_quotas
+func scanQuota(row pgx.CollectableRow) (Quota, error) {
+	var q Quota
+	err := row.Scan(&q.ID, &q.SubjectType, &q.SubjectID, &q.Metric, &q.Period, &q.Limit, &q.CreatedAt, &q.UpdatedAt)
+	return q, err
+}
+
+// loadQuotasDB reads the quotas of a subject
+func (s *Service) loadQuotasDB(ctx context.Context, subject Subject) ([]Quota, error) {
+	return s.ListQuotas(ctx, subject)
+}
+
+// loadCounterDB reads a counted usage, zero when nothing has been counted yet
+func (s *Service) loadCounterDB(ctx context.Context, key counterKey) (int64, error) {
+	var used int64
+	err := s.db.QueryRow(ctx, `
+		SELECT COALESCE(SUM(used), 0)::bigint
+		FROM api.quota_counters
+		WHERE subject_type = $1 AND subject_id = $2 AND metric = $3 AND period = $4 AND period_start = $5
+	`, string(key.Subject.Type), key.Subject.ID, string(key.Metric), string(key.Period), key.Start).Scan(&used)
+	return used, err
+}
+
+// addCountersDB upserts counted usage, adding to the rows written by earlier flushes or other
+// instances, and returns the new totals. The batch runs in one implicit transaction.
+func (s *Service) addCountersDB(ctx context.Context, batch map[counterKey]int64) (map[counterKey]int64, error) {
+	keys := make([]counterKey, 0, len(batch))
+	b := &pgx.Batch{}
+	for key, n := range batch {
+		keys = append(keys, key)
+		b.Queue(`
+			INSERT INTO api.quota_counters (subject_type, subject_id, metric, period, period_start, used)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (subject_type, subject_id, metric, period, period_start)
+			DO UPDATE SET used = api.quota_counters.used + EXCLUDED.used, updated_at = NOW()
+			RETURNING used
+		`, string(key.Subject.Type), key.Subject.ID, string(key.Metric), string(key.Period), key.Start, n)
+	}
+
+	results := s.db.SendBatch(ctx, b)
+	defer func() { _ = results.Close() }()
+
+	totals := make(map[counterKey]int64, len(keys))
+	for _, key := range keys {
+		var used int64
+		if err := results.QueryRow().Scan(&used); err != nil {
+			return nil, err
+		}
+		totals[key] = used
+	}
+	return totals, nil
+}
+
+// measureDB measures the stored bytes of a subject, or its AI tokens since a time. The usage
+// of an organization is what is stored in its buckets and the AI tokens of its members.
+func (s *Service) measureDB(ctx context.Context, subject Subject, metric Metric, since time.Time) (int64, error) {
+	var query string
+	switch {
+	case metric == MetricStorageBytes && subject.Type == SubjectUser:
+		query = `
+			SELECT COALESCE(SUM(size), 0)::bigint
+			FROM storage.objects
+			WHERE owner_id = $1`
+	case metric == MetricStorageBytes:
+		query = `
+			SELECT COALESCE(SUM(o.size), 0)::bigint
+			FROM storage.objects o
+			JOIN storage.buckets b ON b.id = o.bucket_id
+			WHERE b.organization_id = $1`
+	case metric == MetricAITokens && subject.Type == SubjectUser:
+		query = `
+			SELECT (
+				SELECT COALESCE(SUM(COALESCE(m.prompt_tokens, 0) + COALESCE(m.completion_tokens, 0)), 0)
+				FROM ai.messages m
+				JOIN ai.conversations conv ON conv.id = m.conversation_id
+				WHERE conv.user_id = $1 AND m.created_at >= $2
+			)::bigint + (
+				SELECT COALESCE(SUM(total_tokens), 0)
+				FROM ai.embedding_usage
+				WHERE user_id = $1 AND created_at >= $2
+			)::bigint`
+	case metric == MetricAITokens:
+		query = `
+			WITH members AS (
+				SELECT user_id FROM auth.organization_members WHERE organization_id = $1
+			)
+			SELECT (
+				SELECT COALESCE(SUM(COALESCE(m.prompt_tokens, 0) + COALESCE(m.completion_tokens, 0)), 0)
+				FROM ai.messages m
+				JOIN ai.conversations conv ON conv.id = m.conversation_id
+				WHERE conv.user_id IN (SELECT user_id FROM members) AND m.created_at >= $2
+			)::bigint + (
+				SELECT COALESCE(SUM(total_tokens), 0)
+				FROM ai.embedding_usage
+				WHERE user_id IN (SELECT user_id FROM members) AND created_at >= $2
+			)::bigint`
+	default:
+		return 0, nil
+	}
+
+	var used int64
+	var err error
+	if metric == MetricStorageBytes {
+		err = s.db.QueryRow(ctx, query, subject.ID).Scan(&used)
+	} else {
+		err = s.db.QueryRow(ctx, query, subject.ID, since).Scan(&used)
+	}
+	return used, err
+}
@@ -0,0 +1,218 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore stands in for the database tables of a Service
+type fakeStore struct {
+	mu       sync.Mutex
+	quotas   []Quota
+	counters map[counterKey]int64
+	measured map[Metric]int64
+	measures int
+	failAdd  bool
+}
+
+// newTestService creates a service backed by a fake store
+func newTestService(t *testing.T, cacheTTL time.Duration) (*Service, *fakeStore) {
+	t.Helper()
+	store := &fakeStore{counters: make(map[counterKey]int64), measured: make(map[Metric]int64)}
+	s := NewService(nil, time.Hour, cacheTTL)
+
+	s.loadQuotas = func(ctx context.Context, subject Subject) ([]Quota, error) {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		var quotas []Quota
+		for _, q := range store.quotas {
+			if q.Subject() == subject {
+				quotas = append(quotas, q)
+			}
+		}
+		return quotas, nil
+	}
+	s.loadCounter = func(ctx context.Context, key counterKey) (int64, error) {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return store.counters[key], nil
+	}
+	s.addCounters = func(ctx context.Context, batch map[counterKey]int64) (map[counterKey]int64, error) {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		if store.failAdd {
+			return nil, errors.New("database unavailable")
+		}
+		totals := make(map[counterKey]int64)
+		for key, n := range batch {
+			store.counters[key] += n
+			totals[key] = store.counters[key]
+		}
+		return totals, nil
+	}
+	s.measure = func(ctx context.Context, subject Subject, metric Metric, since time.Time) (int64, error) {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		store.measures++
+		return store.measured[metric], nil
+	}
+	return s, store
+}
+
+var (
+	testUser = Subject{Type: SubjectUser, ID: "3f1c9a52-7d3e-4c41-9f0a-2b6d8e5a1c70"}
+	testOrg  = Subject{Type: SubjectOrganization, ID: "9b2e4d10-5a6f-4e8b-8c1d-7f3a2e9b0d64"}
+)
+
+func TestService_CheckCountedUsage(t *testing.T) {
+	s, store := newTestService(t, time.Minute)
+	store.quotas = []Quota{{SubjectType: SubjectUser, SubjectID: testUser.ID, Metric: MetricAPICalls, Period: PeriodDay, Limit: 3}}
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		exceeded, err := s.Check(ctx, []Subject{testUser}, MetricAPICalls, 1)
+		require.NoError(t, err)
+		require.Nil(t, exceeded, "call %d", i+1)
+		s.Record([]Subject{testUser}, MetricAPICalls, 1)
+	}
+
+	exceeded, err := s.Check(ctx, []Subject{testUser}, MetricAPICalls, 1)
+	require.NoError(t, err)
+	require.NotNil(t, exceeded)
+	assert.Equal(t, int64(3), exceeded.Used)
+	assert.Equal(t, int64(0), *exceeded.Remaining)
+	assert.Equal(t, PeriodDay, exceeded.Period)
+
+	t.Run("other subjects are not affected", func(t *testing.T) {
+		exceeded, err := s.Check(ctx, []Subject{testOrg}, MetricAPICalls, 1)
+		require.NoError(t, err)
+		assert.Nil(t, exceeded)
+	})
+}
+
+func TestService_CheckOrganizationQuota(t *testing.T) {
+	s, store := newTestService(t, time.Minute)
+	store.quotas = []Quota{{SubjectType: SubjectOrganization, SubjectID: testOrg.ID, Metric: MetricAPICalls, Period: PeriodMonth, Limit: 10}}
+	monthStart, _ := PeriodMonth.Bounds(time.Now())
+	store.counters[counterKey{Subject: testOrg, Metric: MetricAPICalls, Period: PeriodMonth, Start: monthStart}] = 10
+
+	exceeded, err := s.Check(context.Background(), []Subject{testUser, testOrg}, MetricAPICalls, 1)
+	require.NoError(t, err)
+	require.NotNil(t, exceeded, "usage counted by other instances is read from the database")
+	assert.Equal(t, SubjectOrganization, exceeded.SubjectType)
+	assert.Equal(t, testOrg.ID, exceeded.SubjectID)
+}
+
+func TestService_CheckMeasuredUsage(t *testing.T) {
+	s, store := newTestService(t, time.Minute)
+	store.quotas = []Quota{{SubjectType: SubjectUser, SubjectID: testUser.ID, Metric: MetricStorageBytes, Period: PeriodTotal, Limit: 1000}}
+	store.measured[MetricStorageBytes] = 600
+	ctx := context.Background()
+
+	exceeded, err := s.Check(ctx, []Subject{testUser}, MetricStorageBytes, 300)
+	require.NoError(t, err)
+	assert.Nil(t, exceeded)
+	s.Record([]Subject{testUser}, MetricStorageBytes, 300)
+
+	exceeded, err = s.Check(ctx, []Subject{testUser}, MetricStorageBytes, 300)
+	require.NoError(t, err)
+	require.NotNil(t, exceeded, "recorded uploads count until the next measurement")
+	assert.Equal(t, int64(900), exceeded.Used)
+	assert.Nil(t, exceeded.ResetsAt)
+	assert.Equal(t, 1, store.measures, "measurements are cached")
+
+	t.Run("a used up quota is exceeded by any amount", func(t *testing.T) {
+		store.measured[MetricStorageBytes] = 1000
+		s.mu.Lock()
+		s.measured = make(map[measureKey]*measurement)
+		s.mu.Unlock()
+
+		exceeded, err := s.Check(ctx, []Subject{testUser}, MetricStorageBytes, 0)
+		require.NoError(t, err)
+		assert.NotNil(t, exceeded)
+	})
+}
+
+func TestService_Flush(t *testing.T) {
+	s, store := newTestService(t, time.Minute)
+	ctx := context.Background()
+	dayStart, _ := PeriodDay.Bounds(time.Now())
+	dayKey := counterKey{Subject: testUser, Metric: MetricAPICalls, Period: PeriodDay, Start: dayStart}
+
+	s.Record([]Subject{testUser}, MetricAPICalls, 2)
+
+	t.Run("failed flushes keep usage pending", func(t *testing.T) {
+		store.failAdd = true
+		require.Error(t, s.Flush(ctx))
+		assert.Equal(t, int64(2), s.counters[dayKey].pending)
+		assert.Equal(t, int64(0), s.counters[dayKey].used)
+	})
+
+	t.Run("flushes add to the database and refresh the counter", func(t *testing.T) {
+		store.failAdd = false
+		store.counters[dayKey] = 5 // counted by another instance
+
+		require.NoError(t, s.Flush(ctx))
+		assert.Equal(t, int64(7), store.counters[dayKey])
+		assert.Equal(t, int64(0), s.counters[dayKey].pending)
+
+		used, err := s.countedUsage(ctx, dayKey)
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), used)
+	})
+
+	t.Run("nothing to flush", func(t *testing.T) {
+		store.failAdd = true
+		assert.NoError(t, s.Flush(ctx))
+	})
+}
+
+func TestService_Usage(t *testing.T) {
+	s, store := newTestService(t, time.Minute)
+	store.quotas = []Quota{
+		{SubjectType: SubjectUser, SubjectID: testUser.ID, Metric: MetricAPICalls, Period: PeriodDay, Limit: 100},
+		{SubjectType: SubjectUser, SubjectID: testUser.ID, Metric: MetricAPICalls, Period: PeriodMonth, Limit: 1000},
+	}
+	store.measured[MetricAITokens] = 1234
+	s.Record([]Subject{testUser}, MetricAPICalls, 5)
+
+	usages, err := s.Usage(context.Background(), testUser)
+	require.NoError(t, err)
+	require.Len(t, usages, 4)
+
+	assert.Equal(t, PeriodDay, usages[0].Period)
+	assert.Equal(t, int64(5), usages[0].Used)
+	assert.Equal(t, int64(95), *usages[0].Remaining)
+	assert.Equal(t, PeriodMonth, usages[1].Period)
+	assert.Equal(t, int64(995), *usages[1].Remaining)
+
+	assert.Equal(t, MetricStorageBytes, usages[2].Metric)
+	assert.Equal(t, PeriodTotal, usages[2].Period)
+	assert.Nil(t, usages[2].Limit)
+
+	assert.Equal(t, MetricAITokens, usages[3].Metric)
+	assert.Equal(t, PeriodMonth, usages[3].Period)
+	assert.Equal(t, int64(1234), usages[3].Used)
+}
+
+func TestService_Prune(t *testing.T) {
+	s, _ := newTestService(t, time.Millisecond)
+	ctx := context.Background()
+
+	_, err := s.Usage(ctx, testUser)
+	require.NoError(t, err)
+	s.Record([]Subject{testOrg}, MetricAPICalls, 1)
+
+	time.Sleep(5 * time.Millisecond)
+	s.prune()
+
+	assert.Empty(t, s.quotas)
+	assert.Empty(t, s.measured)
+	assert.Len(t, s.counters, 2, "counters with pending usage are kept until flushed")
+}
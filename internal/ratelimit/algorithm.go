@@ -0,0 +1,145 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Algorithm selects how requests are counted against a limit
+type Algorithm string
+
+const (
+	// AlgorithmFixedWindow counts requests in consecutive windows. It is the cheapest, but a
+	// client can send up to twice the limit around a window boundary.
+	AlgorithmFixedWindow Algorithm = "fixed_window"
+
+	// AlgorithmSlidingWindow weights the previous window's count by how much of it still
+	// overlaps the sliding window, which smooths out bursts at window boundaries.
+	AlgorithmSlidingWindow Algorithm = "sliding_window"
+
+	// AlgorithmTokenBucket lets a client burst up to the limit, then refills the bucket
+	// steadily at limit requests per window.
+	AlgorithmTokenBucket Algorithm = "token_bucket"
+)
+
+// ValidAlgorithms lists the algorithms CheckAlgorithm accepts
+var ValidAlgorithms = []Algorithm{AlgorithmFixedWindow, AlgorithmSlidingWindow, AlgorithmTokenBucket}
+
+// TokenBucketStore is implemented by stores that can take a token from a bucket atomically.
+// Buckets are tracked with the generic cell rate algorithm: a key holds the time at which its
+// bucket is full again, and each request pushes it back by one emission interval.
+type TokenBucketStore interface {
+	// TakeToken takes a token from the bucket of key, which refills one token every interval
+	// and holds burst/interval tokens. It returns whether a token was taken and how long it
+	// takes from now for the bucket to be full again.
+	TakeToken(ctx context.Context, key string, interval, burst time.Duration) (bool, time.Duration, error)
+}
+
+// CheckAlgorithm performs a rate limit check with the given algorithm. An empty algorithm
+// uses the fixed window of Check. Token buckets need a store implementing TokenBucketStore,
+// other stores fall back to the sliding window.
+func CheckAlgorithm(ctx context.Context, store Store, algorithm Algorithm, key string, limit int64, window time.Duration) (*Result, error) {
+	switch algorithm {
+	case "", AlgorithmFixedWindow:
+		return Check(ctx, store, key, limit, window)
+	case AlgorithmSlidingWindow:
+		return CheckSlidingWindow(ctx, store, key, limit, window)
+	case AlgorithmTokenBucket:
+		if tbs, ok := store.(TokenBucketStore); ok {
+			return CheckTokenBucket(ctx, tbs, key, limit, window)
+		}
+		return CheckSlidingWindow(ctx, store, key, limit, window)
+	default:
+		return nil, fmt.Errorf("unknown rate limit algorithm %q", algorithm)
+	}
+}
+
+// CheckSlidingWindow performs a sliding window check on top of the store's counters.
+// Requests are counted in fixed windows, and the estimate for the window ending now adds the
+// previous window's count weighted by its overlap. ResetAt is when the estimate next drops
+// below the limit, or the end of the current window while requests remain.
+func CheckSlidingWindow(ctx context.Context, store Store, key string, limit int64, window time.Duration) (*Result, error) {
+	now := time.Now()
+	start := now.Truncate(window)
+	elapsed := now.Sub(start)
+
+	// Counters live for two windows so they can be read as the previous window
+	current, err := store.Increment(ctx, slidingWindowKey(key, start), 2*window)
+	if err != nil {
+		return nil, err
+	}
+	previous, _, err := store.Get(ctx, slidingWindowKey(key, start.Add(-window)))
+	if err != nil {
+		return nil, err
+	}
+
+	overlap := 1 - float64(elapsed)/float64(window)
+	estimate := int64(math.Floor(float64(previous)*overlap)) + current
+
+	result := &Result{
+		Allowed:   estimate <= limit,
+		Remaining: max(limit-estimate, 0),
+		Limit:     limit,
+		ResetAt:   start.Add(window),
+	}
+
+	// While the current window alone is under the limit, the estimate drops below it once
+	// enough of the previous window has slid out
+	if !result.Allowed && previous > 0 && current < limit {
+		untilFree := time.Duration(float64(window) * (1 - float64(limit-current)/float64(previous)))
+		result.ResetAt = start.Add(untilFree)
+	}
+
+	return result, nil
+}
+
+func slidingWindowKey(key string, start time.Time) string {
+	return fmt.Sprintf("%s:sw:%d", key, start.UnixMilli())
+}
+
+// CheckTokenBucket takes a token from a bucket holding limit tokens that refills at limit
+// tokens per window. ResetAt is when the bucket is full again, or for denied requests when
+// the next token is available.
+func CheckTokenBucket(ctx context.Context, store TokenBucketStore, key string, limit int64, window time.Duration) (*Result, error) {
+	if limit <= 0 {
+		return &Result{Limit: limit, ResetAt: time.Now().Add(window)}, nil
+	}
+
+	interval := window / time.Duration(limit)
+	if interval <= 0 {
+		interval = 1
+	}
+
+	allowed, untilFull, err := store.TakeToken(ctx, key+":tb", interval, window)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := &Result{
+		Allowed:   allowed,
+		Remaining: max(int64((window-untilFull)/interval), 0),
+		Limit:     limit,
+		ResetAt:   now.Add(untilFull),
+	}
+	if !allowed {
+		result.ResetAt = now.Add(untilFull + interval - window)
+	}
+
+	return result, nil
+}
+
+// takeToken applies a token bucket request to the time at which the bucket is full (its
+// theoretical arrival time) and returns the new time and whether a token was taken
+func takeToken(now, fullAt time.Time, interval, burst time.Duration) (time.Time, bool) {
+	if fullAt.Before(now) {
+		fullAt = now
+	}
+	next := fullAt.Add(interval)
+	if next.Sub(now) > burst {
+		return fullAt, false
+	}
+	return next, true
+}
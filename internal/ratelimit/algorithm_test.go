@@ -0,0 +1,156 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// counterStore hides the optional interfaces of a store
+type counterStore struct {
+	Store
+}
+
+func TestCheckSlidingWindow(t *testing.T) {
+	store := NewMemoryStore(time.Minute)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	window := 24 * time.Hour
+
+	t.Run("allows requests up to the limit", func(t *testing.T) {
+		for i := 1; i <= 5; i++ {
+			result, err := CheckSlidingWindow(ctx, store, "sw-limit", 5, window)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, int64(5-i), result.Remaining)
+			assert.Equal(t, time.Now().Truncate(window).Add(window), result.ResetAt)
+		}
+
+		result, err := CheckSlidingWindow(ctx, store, "sw-limit", 5, window)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, int64(0), result.Remaining)
+	})
+
+	t.Run("counts the previous window", func(t *testing.T) {
+		previous := time.Now().Truncate(window).Add(-window)
+		for i := 0; i < 1000; i++ {
+			_, err := store.Increment(ctx, slidingWindowKey("sw-previous", previous), 2*window)
+			require.NoError(t, err)
+		}
+
+		result, err := CheckSlidingWindow(ctx, store, "sw-previous", 100, window)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed, "most of the previous window still overlaps")
+		assert.True(t, result.ResetAt.After(time.Now()))
+		assert.False(t, result.ResetAt.After(previous.Add(2*window)))
+	})
+
+	t.Run("separates keys", func(t *testing.T) {
+		_, err := CheckSlidingWindow(ctx, store, "sw-a", 1, window)
+		require.NoError(t, err)
+
+		result, err := CheckSlidingWindow(ctx, store, "sw-b", 1, window)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+}
+
+func TestCheckTokenBucket(t *testing.T) {
+	store := NewMemoryStore(time.Minute)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+
+	t.Run("allows a burst up to the limit", func(t *testing.T) {
+		for i := 1; i <= 3; i++ {
+			result, err := CheckTokenBucket(ctx, store, "tb-burst", 3, time.Minute)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, int64(3-i), result.Remaining)
+			assert.WithinDuration(t, time.Now().Add(time.Duration(i)*20*time.Second), result.ResetAt, time.Second)
+		}
+
+		result, err := CheckTokenBucket(ctx, store, "tb-burst", 3, time.Minute)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, int64(0), result.Remaining)
+		assert.WithinDuration(t, time.Now().Add(20*time.Second), result.ResetAt, time.Second, "next token in one interval")
+	})
+
+	t.Run("refills over time", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			result, err := CheckTokenBucket(ctx, store, "tb-refill", 10, 100*time.Millisecond)
+			require.NoError(t, err)
+			require.True(t, result.Allowed)
+		}
+
+		result, err := CheckTokenBucket(ctx, store, "tb-refill", 10, 100*time.Millisecond)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+
+		time.Sleep(25 * time.Millisecond)
+
+		result, err = CheckTokenBucket(ctx, store, "tb-refill", 10, 100*time.Millisecond)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("zero limit denies", func(t *testing.T) {
+		result, err := CheckTokenBucket(ctx, store, "tb-zero", 0, time.Minute)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+	})
+}
+
+func TestTakeToken(t *testing.T) {
+	now := time.Now()
+
+	fullAt, allowed := takeToken(now, time.Time{}, time.Second, 3*time.Second)
+	assert.True(t, allowed)
+	assert.Equal(t, now.Add(time.Second), fullAt)
+
+	fullAt, allowed = takeToken(now, now.Add(2*time.Second), time.Second, 3*time.Second)
+	assert.True(t, allowed)
+	assert.Equal(t, now.Add(3*time.Second), fullAt)
+
+	fullAt, allowed = takeToken(now, now.Add(3*time.Second), time.Second, 3*time.Second)
+	assert.False(t, allowed)
+	assert.Equal(t, now.Add(3*time.Second), fullAt, "denied requests don't drain the bucket")
+}
+
+func TestCheckAlgorithm(t *testing.T) {
+	store := NewMemoryStore(time.Minute)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+
+	for _, algorithm := range append(ValidAlgorithms, "") {
+		result, err := CheckAlgorithm(ctx, store, algorithm, "algo-"+string(algorithm), 1, time.Minute)
+		require.NoError(t, err, algorithm)
+		assert.True(t, result.Allowed, algorithm)
+
+		result, err = CheckAlgorithm(ctx, store, algorithm, "algo-"+string(algorithm), 1, time.Minute)
+		require.NoError(t, err, algorithm)
+		assert.False(t, result.Allowed, algorithm)
+	}
+
+	t.Run("token bucket falls back to sliding window", func(t *testing.T) {
+		plain := counterStore{store}
+		_, err := CheckAlgorithm(ctx, plain, AlgorithmTokenBucket, "algo-fallback", 1, time.Minute)
+		require.NoError(t, err)
+
+		count, _, err := store.Get(ctx, slidingWindowKey("algo-fallback", time.Now().Truncate(time.Minute)))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("rejects unknown algorithms", func(t *testing.T) {
+		_, err := CheckAlgorithm(ctx, store, "leaky_bucket", "algo-unknown", 1, time.Minute)
+		assert.Error(t, err)
+	})
+}
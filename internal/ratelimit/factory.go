@@ -12,6 +12,7 @@ import (
 
 // NewStore creates a rate limit store based on the scaling configuration.
 //
+// The backend is scaling.rate_limit_backend, falling back to scaling.backend.
 // Backend options:
 // - "local": In-memory store (default for single instance)
 // - "postgres": PostgreSQL-backed store (for multi-instance without Redis)
@@ -20,7 +21,8 @@ import (
 // The pool parameter is required for "postgres" backend.
 // The redisURL is required for "redis" backend (from config.Scaling.RedisURL).
 func NewStore(cfg *config.ScalingConfig, pool *pgxpool.Pool) (Store, error) {
-	switch cfg.Backend {
+	backend := cfg.BackendFor(config.ScalingComponentRateLimit)
+	switch backend {
 	case "local", "":
 		log.Info().Msg("Using in-memory rate limit store (single instance mode)")
 		return NewMemoryStore(10 * time.Minute), nil
@@ -45,7 +47,7 @@ func NewStore(cfg *config.ScalingConfig, pool *pgxpool.Pool) (Store, error) {
 		return store, nil
 
 	default:
-		return nil, fmt.Errorf("unknown rate limit backend: %s (valid options: local, postgres, redis)", cfg.Backend)
+		return nil, fmt.Errorf("unknown rate limit backend: %s (valid options: local, postgres, redis)", backend)
 	}
 }
 
@@ -84,6 +84,20 @@ func TestNewStore(t *testing.T) {
 		assert.Contains(t, err.Error(), "unknown rate limit backend")
 		assert.Contains(t, err.Error(), "valid options: local, postgres, redis")
 	})
+
+	t.Run("rate limit backend overrides scaling backend", func(t *testing.T) {
+		cfg := &config.ScalingConfig{
+			Backend:          "postgres",
+			RateLimitBackend: "local",
+		}
+
+		store, err := NewStore(cfg, nil)
+		require.NoError(t, err)
+		defer func() { _ = store.Close() }()
+
+		_, ok := store.(*MemoryStore)
+		assert.True(t, ok, "should be MemoryStore")
+	})
 }
 
 func TestGlobalStore(t *testing.T) {
@@ -97,6 +97,12 @@ func (s *MemoryStore) Get(ctx context.Context, key string) (int64, time.Time, er
 
 // Increment atomically increments the counter for a key.
 func (s *MemoryStore) Increment(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	count, _, err := s.IncrementWindow(ctx, key, expiration)
+	return count, err
+}
+
+// IncrementWindow atomically increments the counter for a key and returns when its window expires.
+func (s *MemoryStore) IncrementWindow(ctx context.Context, key string, expiration time.Duration) (int64, time.Time, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -105,16 +111,36 @@ func (s *MemoryStore) Increment(ctx context.Context, key string, expiration time
 
 	if !exists || now.After(e.expiresAt) {
 		// Create new entry or reset expired one
-		s.data[key] = &entry{
+		e = &entry{
 			count:     1,
 			expiresAt: now.Add(expiration),
 		}
-		return 1, nil
+		s.data[key] = e
+		return e.count, e.expiresAt, nil
 	}
 
 	// Increment existing entry
 	e.count++
-	return e.count, nil
+	return e.count, e.expiresAt, nil
+}
+
+// TakeToken takes a token from the bucket of key. The key's entry expires when the bucket is
+// full again, so full buckets are collected like expired counters.
+func (s *MemoryStore) TakeToken(ctx context.Context, key string, interval, burst time.Duration) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var fullAt time.Time
+	if e, exists := s.data[key]; exists {
+		fullAt = e.expiresAt
+	}
+
+	fullAt, allowed := takeToken(now, fullAt, interval, burst)
+	if allowed {
+		s.data[key] = &entry{count: 1, expiresAt: fullAt}
+	}
+	return allowed, fullAt.Sub(now), nil
 }
 
 // Reset resets the counter for a key.
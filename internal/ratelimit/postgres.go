@@ -50,6 +50,12 @@ func (s *PostgresStore) Get(ctx context.Context, key string) (int64, time.Time,
 // Increment atomically increments the counter for a key.
 // Uses PostgreSQL's UPSERT to handle concurrent access safely.
 func (s *PostgresStore) Increment(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	count, _, err := s.IncrementWindow(ctx, key, expiration)
+	return count, err
+}
+
+// IncrementWindow atomically increments the counter for a key and returns when its window expires.
+func (s *PostgresStore) IncrementWindow(ctx context.Context, key string, expiration time.Duration) (int64, time.Time, error) {
 	expiresAt := time.Now().Add(expiration)
 
 	var count int64
@@ -65,14 +71,44 @@ func (s *PostgresStore) Increment(ctx context.Context, key string, expiration ti
 				WHEN system.rate_limits.expires_at <= NOW() THEN $2
 				ELSE system.rate_limits.expires_at
 			END
-		RETURNING count
-	`, key, expiresAt).Scan(&count)
+		RETURNING count, expires_at
+	`, key, expiresAt).Scan(&count, &expiresAt)
 	if err != nil {
 		log.Error().Err(err).Str("key", key).Msg("Failed to increment rate limit counter")
-		return 0, err
+		return 0, time.Time{}, err
+	}
+
+	return count, expiresAt, nil
+}
+
+// TakeToken atomically takes a token from the bucket of key. The row's expires_at holds the
+// time at which the bucket is full again, so Cleanup removes full buckets, and count is 1 when
+// the last request took a token and 0 when it was denied.
+func (s *PostgresStore) TakeToken(ctx context.Context, key string, interval, burst time.Duration) (bool, time.Duration, error) {
+	var taken, untilFull int64
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO system.rate_limits AS rl (key, count, expires_at)
+		VALUES ($1, 1, NOW() + $2::bigint * INTERVAL '1 microsecond')
+		ON CONFLICT (key) DO UPDATE SET
+			count = CASE
+				WHEN GREATEST(rl.expires_at, NOW()) + $2::bigint * INTERVAL '1 microsecond'
+					<= NOW() + $3::bigint * INTERVAL '1 microsecond' THEN 1
+				ELSE 0
+			END,
+			expires_at = CASE
+				WHEN GREATEST(rl.expires_at, NOW()) + $2::bigint * INTERVAL '1 microsecond'
+					<= NOW() + $3::bigint * INTERVAL '1 microsecond'
+					THEN GREATEST(rl.expires_at, NOW()) + $2::bigint * INTERVAL '1 microsecond'
+				ELSE rl.expires_at
+			END
+		RETURNING count, (EXTRACT(EPOCH FROM GREATEST(expires_at - NOW(), INTERVAL '0')) * 1000000)::bigint
+	`, key, interval.Microseconds(), burst.Microseconds()).Scan(&taken, &untilFull)
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("Failed to take rate limit token")
+		return false, 0, err
 	}
 
-	return count, nil
+	return taken == 1, time.Duration(untilFull) * time.Microsecond, nil
 }
 
 // Reset resets the counter for a key.
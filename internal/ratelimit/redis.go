@@ -3,6 +3,7 @@ package ratelimit
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strconv"
 	"time"
 
@@ -92,25 +93,78 @@ func (s *RedisStore) Get(ctx context.Context, key string) (int64, time.Time, err
 // Increment atomically increments the counter for a key.
 // Uses INCR + EXPIRE for atomic increment with TTL.
 func (s *RedisStore) Increment(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	count, _, err := s.IncrementWindow(ctx, key, expiration)
+	return count, err
+}
+
+// IncrementWindow atomically increments the counter for a key and returns when its window expires.
+func (s *RedisStore) IncrementWindow(ctx context.Context, key string, expiration time.Duration) (int64, time.Time, error) {
 	prefixedKey := "ratelimit:" + key
 
 	// Use a Lua script for atomic increment with conditional expiration
 	// This ensures the expiration is only set on the first increment
+	// The remaining TTL is returned with the count so callers know when the window resets
 	script := redis.NewScript(`
 		local current = redis.call('INCR', KEYS[1])
 		if current == 1 then
 			redis.call('PEXPIRE', KEYS[1], ARGV[1])
 		end
-		return current
+		return {current, redis.call('PTTL', KEYS[1])}
 	`)
 
-	result, err := script.Run(ctx, s.client, []string{prefixedKey}, expiration.Milliseconds()).Int64()
-	if err != nil {
+	result, err := script.Run(ctx, s.client, []string{prefixedKey}, expiration.Milliseconds()).Int64Slice()
+	if err != nil || len(result) != 2 {
+		if err == nil {
+			err = fmt.Errorf("unexpected script result: %v", result)
+		}
 		log.Error().Err(err).Str("key", key).Msg("Failed to increment rate limit counter in Redis")
-		return 0, err
+		return 0, time.Time{}, err
+	}
+
+	ttl := time.Duration(result[1]) * time.Millisecond
+	if ttl <= 0 {
+		// Key without expiry (should not happen), assume a full window
+		ttl = expiration
+	}
+
+	return result[0], time.Now().Add(ttl), nil
+}
+
+// tokenBucketScript takes a token from a bucket stored as the time, in microseconds, at
+// which it is full again. The key expires at that time, so full buckets take no memory.
+var tokenBucketScript = redis.NewScript(`
+	local now = tonumber(ARGV[1])
+	local interval = tonumber(ARGV[2])
+	local burst = tonumber(ARGV[3])
+	local full_at = tonumber(redis.call('GET', KEYS[1]) or now)
+	if full_at < now then
+		full_at = now
+	end
+	local next_full_at = full_at + interval
+	if next_full_at - now > burst then
+		return {0, full_at - now}
+	end
+	-- Format as an integer, Lua would write large numbers in exponent notation
+	redis.call('SET', KEYS[1], string.format('%d', next_full_at), 'PX', math.ceil((next_full_at - now) / 1000))
+	return {1, next_full_at - now}
+`)
+
+// TakeToken atomically takes a token from the bucket of key with a Lua script.
+// The bucket is timed with this instance's clock, like counter windows.
+func (s *RedisStore) TakeToken(ctx context.Context, key string, interval, burst time.Duration) (bool, time.Duration, error) {
+	prefixedKey := "ratelimit:" + key
+
+	result, err := tokenBucketScript.Run(ctx, s.client, []string{prefixedKey},
+		time.Now().UnixMicro(), interval.Microseconds(), burst.Microseconds()).Int64Slice()
+	if err != nil || len(result) != 2 {
+		if err == nil {
+			err = fmt.Errorf("unexpected script result: %v", result)
+		}
+		log.Error().Err(err).Str("key", key).Msg("Failed to take rate limit token in Redis")
+		return false, 0, err
 	}
 
-	return result, nil
+	return result[0] == 1, time.Duration(result[1]) * time.Microsecond, nil
 }
 
 // Reset resets the counter for a key.
@@ -35,6 +35,13 @@ type Store interface {
 	Close() error
 }
 
+// WindowStore is implemented by stores that report when a counter's window ends as part
+// of the increment, so Check can return an accurate reset time without a second lookup.
+type WindowStore interface {
+	// IncrementWindow works like Increment and also returns when the current window expires.
+	IncrementWindow(ctx context.Context, key string, expiration time.Duration) (int64, time.Time, error)
+}
+
 // Result contains the rate limit check result
 type Result struct {
 	// Allowed indicates whether the request is allowed
@@ -52,8 +59,18 @@ type Result struct {
 
 // Check performs a rate limit check using the store.
 // It increments the counter and returns whether the request is allowed.
+// ResetAt is the end of the fixed window when the store implements WindowStore,
+// otherwise it is estimated as a full window from now.
 func Check(ctx context.Context, store Store, key string, limit int64, window time.Duration) (*Result, error) {
-	count, err := store.Increment(ctx, key, window)
+	var count int64
+	var resetAt time.Time
+	var err error
+	if ws, ok := store.(WindowStore); ok {
+		count, resetAt, err = ws.IncrementWindow(ctx, key, window)
+	} else {
+		count, err = store.Increment(ctx, key, window)
+		resetAt = time.Now().Add(window)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -62,7 +79,7 @@ func Check(ctx context.Context, store Store, key string, limit int64, window tim
 		Allowed:   count <= limit,
 		Remaining: limit - count,
 		Limit:     limit,
-		ResetAt:   time.Now().Add(window),
+		ResetAt:   resetAt,
 	}
 
 	if result.Remaining < 0 {
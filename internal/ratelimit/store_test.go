@@ -475,7 +475,7 @@ func TestCheck_ResetTimeAccuracy(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.window.String(), func(t *testing.T) {
 			before := time.Now()
-			result, err := Check(ctx, store, "reset-time-test", 10, tc.window)
+			result, err := Check(ctx, store, "reset-time-test:"+tc.window.String(), 10, tc.window)
 			require.NoError(t, err)
 
 			after := time.Now()
@@ -492,6 +492,24 @@ func TestCheck_ResetTimeAccuracy(t *testing.T) {
 	}
 }
 
+func TestCheck_ResetTimeIsWindowEnd(t *testing.T) {
+	store := NewMemoryStore(time.Minute)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+
+	first, err := Check(ctx, store, "window-end-test", 10, time.Minute)
+	require.NoError(t, err)
+
+	time.Sleep(10 * time.Millisecond)
+
+	// Later requests in the same window report the same reset time instead of a full window from now
+	second, err := Check(ctx, store, "window-end-test", 10, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, first.ResetAt, second.ResetAt)
+	assert.Equal(t, int64(8), second.Remaining)
+}
+
 func TestCheck_VeryShortWindow(t *testing.T) {
 	store := NewMemoryStore(time.Minute)
 	defer func() { _ = store.Close() }()
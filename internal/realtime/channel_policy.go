@@ -0,0 +1,154 @@
+package realtime
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ChannelAction is an action of a client on a broadcast and presence channel
+type ChannelAction string
+
+const (
+	// ChannelActionSubscribe receives the channel's broadcasts and presence events
+	ChannelActionSubscribe ChannelAction = "subscribe"
+	// ChannelActionBroadcast sends a message to the channel's subscribers
+	ChannelActionBroadcast ChannelAction = "broadcast"
+	// ChannelActionPresence tracks or untracks the client's presence in the channel
+	ChannelActionPresence ChannelAction = "presence"
+)
+
+// ChannelPolicy decides whether a connection may perform an action on a broadcast and
+// presence channel. It returns an error describing why the action is refused.
+type ChannelPolicy func(ctx context.Context, conn *Connection, channel string, action ChannelAction) error
+
+// SetChannelPolicy registers the policy of the broadcast and presence channels starting with
+// prefix. When several prefixes match a channel the longest one applies, and channels
+// matching no prefix are open to every connection. Call it before serving connections.
+func (h *RealtimeHandler) SetChannelPolicy(prefix string, policy ChannelPolicy) {
+	if h.channelPolicies == nil {
+		h.channelPolicies = make(map[string]ChannelPolicy)
+	}
+	h.channelPolicies[prefix] = policy
+}
+
+// channelPolicy returns the policy of the longest prefix matching channel, or nil
+func (h *RealtimeHandler) channelPolicy(channel string) ChannelPolicy {
+	var policy ChannelPolicy
+	longest := -1
+	for prefix, p := range h.channelPolicies {
+		if len(prefix) > longest && strings.HasPrefix(channel, prefix) {
+			policy, longest = p, len(prefix)
+		}
+	}
+	return policy
+}
+
+// checkChannelAction returns why the policy of a broadcast and presence channel refuses an
+// action, or nil when it is allowed
+func (h *RealtimeHandler) checkChannelAction(conn *Connection, channel string, action ChannelAction) error {
+	policy := h.channelPolicy(channel)
+	if policy == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), channelAuthorizerTimeout)
+	defer cancel()
+
+	return policy(ctx, conn, channel, action)
+}
+
+// authorizeChannelAction checks an action on a broadcast and presence channel. It answers
+// the connection with the reason and returns false when the action is refused.
+func (h *RealtimeHandler) authorizeChannelAction(conn *Connection, channel string, action ChannelAction) bool {
+	if err := h.checkChannelAction(conn, channel, action); err != nil {
+		log.Debug().Err(err).
+			Str("connection_id", conn.ID).
+			Str("channel", channel).
+			Str("action", string(action)).
+			Msg("Channel action refused")
+		_ = conn.SendMessage(ServerMessage{
+			Type:    MessageTypeError,
+			Channel: channel,
+			Error:   err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// joinSendingChannel subscribes conn to a channel it broadcasts or tracks presence on, as
+// Supabase clients expect, but only when the policy also allows it to receive the channel
+func (h *RealtimeHandler) joinSendingChannel(conn *Connection, channel string) {
+	if conn.IsSubscribed(channel) {
+		return
+	}
+	if h.checkChannelAction(conn, channel, ChannelActionSubscribe) != nil {
+		return
+	}
+	conn.Subscribe(channel)
+}
+
+// subscribeChannel subscribes conn to the broadcasts and presence events of a channel and
+// sends it the channel's current presence state
+func (h *RealtimeHandler) subscribeChannel(conn *Connection, channel string) {
+	if !h.authorizeChannelAction(conn, channel, ChannelActionSubscribe) {
+		return
+	}
+
+	if !conn.IsSubscribed(channel) {
+		conn.Subscribe(channel)
+	}
+
+	_ = conn.SendMessage(ServerMessage{
+		Type: MessageTypeAck,
+		Payload: map[string]interface{}{
+			"subscribed": true,
+			"channel":    channel,
+		},
+	})
+
+	_ = conn.SendMessage(ServerMessage{
+		Type:    MessageTypePresence,
+		Channel: channel,
+		Payload: map[string]interface{}{
+			"presence": map[string]interface{}{
+				"event":            "sync",
+				"currentPresences": h.presenceManager.GetPresenceState(channel),
+			},
+		},
+	})
+}
+
+// leaveChannel unsubscribes conn from a channel and removes its presence there
+func (h *RealtimeHandler) leaveChannel(conn *Connection, channel string) {
+	conn.Unsubscribe(channel)
+	if info := h.presenceManager.LeaveChannel(channel, conn.ID); info != nil {
+		h.notifyPresenceLeave(channel, info)
+	}
+}
+
+// reauthorizeChannels removes conn from the policy-guarded channels it may no longer
+// subscribe to, after its access token changed
+func (h *RealtimeHandler) reauthorizeChannels(conn *Connection) {
+	if len(h.channelPolicies) == 0 {
+		return
+	}
+
+	conn.mu.RLock()
+	channels := make([]string, 0, len(conn.Subscriptions))
+	for channel := range conn.Subscriptions {
+		channels = append(channels, channel)
+	}
+	conn.mu.RUnlock()
+
+	for _, channel := range channels {
+		if h.channelPolicy(channel) == nil || h.channelAuthorizer(channel) != nil {
+			continue
+		}
+		if !h.authorizeChannelAction(conn, channel, ChannelActionSubscribe) {
+			h.leaveChannel(conn, channel)
+		}
+	}
+}
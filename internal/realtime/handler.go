@@ -90,12 +90,21 @@ type TokenClaims struct {
 	RawClaims map[string]interface{} // Full claims map for RLS (includes custom claims like meeting_id, player_id)
 }
 
+// ChannelAuthorizer decides whether a connection may subscribe to a server-published channel.
+// It returns an error describing why the subscription is refused.
+type ChannelAuthorizer func(ctx context.Context, conn *Connection, channel string) error
+
+// channelAuthorizerTimeout bounds the lookups a ChannelAuthorizer makes
+const channelAuthorizerTimeout = 5 * time.Second
+
 // RealtimeHandler handles WebSocket connections
 type RealtimeHandler struct {
-	manager         *Manager
-	authService     AuthService
-	subManager      *SubscriptionManager
-	presenceManager *PresenceManager
+	manager            *Manager
+	authService        AuthService
+	subManager         *SubscriptionManager
+	presenceManager    *PresenceManager
+	channelAuthorizers map[string]ChannelAuthorizer // channel prefix -> authorizer
+	channelPolicies    map[string]ChannelPolicy     // channel prefix -> policy of client channels
 }
 
 // NewRealtimeHandler creates a new realtime handler
@@ -360,6 +369,12 @@ func (h *RealtimeHandler) handleConnection(c *websocket.Conn) {
 func (h *RealtimeHandler) handleMessage(conn *Connection, msg ClientMessage) {
 	switch msg.Type {
 	case MessageTypeSubscribe:
+		// Server-published channels with their own access rules
+		if authorize := h.channelAuthorizer(msg.Channel); authorize != nil {
+			h.subscribeAuthorizedChannel(conn, msg.Channel, authorize)
+			return
+		}
+
 		// Check if this is a broadcast-only channel (no table required)
 		isAdminChannel := len(msg.Channel) >= 15 && msg.Channel[:15] == "realtime:admin:"
 		isFluxbaseChannel := len(msg.Channel) >= 9 && msg.Channel[:9] == "fluxbase:"
@@ -414,6 +429,12 @@ func (h *RealtimeHandler) handleMessage(conn *Connection, msg ClientMessage) {
 			filter = msg.Filter
 		}
 
+		// Without a table, the client listens to the channel's broadcasts and presence
+		if table == "" && msg.Channel != "" {
+			h.subscribeChannel(conn, msg.Channel)
+			return
+		}
+
 		// Validate table is provided
 		if table == "" {
 			_ = conn.SendMessage(ServerMessage{
@@ -525,6 +546,9 @@ func (h *RealtimeHandler) handleMessage(conn *Connection, msg ClientMessage) {
 			if h.subManager != nil {
 				h.subManager.RemoveConnectionSubscriptions(conn.ID)
 			}
+			if msg.Channel != "" {
+				h.leaveChannel(conn, msg.Channel)
+			}
 
 			// Send acknowledgment
 			_ = conn.SendMessage(ServerMessage{
@@ -579,6 +603,53 @@ func (h *RealtimeHandler) GetManager() *Manager {
 	return h.manager
 }
 
+// SetChannelAuthorizer registers the authorizer of the channels starting with prefix. These
+// channels are broadcast-only and published by the server: clients may subscribe once the
+// authorizer accepts them, but cannot broadcast to them. Call it before serving connections.
+func (h *RealtimeHandler) SetChannelAuthorizer(prefix string, authorize ChannelAuthorizer) {
+	if h.channelAuthorizers == nil {
+		h.channelAuthorizers = make(map[string]ChannelAuthorizer)
+	}
+	h.channelAuthorizers[prefix] = authorize
+}
+
+// channelAuthorizer returns the authorizer registered for channel, or nil
+func (h *RealtimeHandler) channelAuthorizer(channel string) ChannelAuthorizer {
+	for prefix, authorize := range h.channelAuthorizers {
+		if strings.HasPrefix(channel, prefix) {
+			return authorize
+		}
+	}
+	return nil
+}
+
+// subscribeAuthorizedChannel subscribes conn to a channel guarded by authorize
+func (h *RealtimeHandler) subscribeAuthorizedChannel(conn *Connection, channel string, authorize ChannelAuthorizer) {
+	ctx, cancel := context.WithTimeout(context.Background(), channelAuthorizerTimeout)
+	defer cancel()
+
+	if err := authorize(ctx, conn, channel); err != nil {
+		log.Debug().Err(err).Str("connection_id", conn.ID).Str("channel", channel).Msg("Channel subscription refused")
+		_ = conn.SendMessage(ServerMessage{
+			Type:  MessageTypeError,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	if !conn.IsSubscribed(channel) {
+		conn.Subscribe(channel)
+	}
+
+	_ = conn.SendMessage(ServerMessage{
+		Type: MessageTypeAck,
+		Payload: map[string]interface{}{
+			"subscribed": true,
+			"channel":    channel,
+		},
+	})
+}
+
 // handleBroadcast processes broadcast messages
 func (h *RealtimeHandler) handleBroadcast(conn *Connection, msg ClientMessage) {
 	if msg.Channel == "" {
@@ -589,6 +660,15 @@ func (h *RealtimeHandler) handleBroadcast(conn *Connection, msg ClientMessage) {
 		return
 	}
 
+	// Server-published channels only accept subscriptions
+	if h.channelAuthorizer(msg.Channel) != nil {
+		_ = conn.SendMessage(ServerMessage{
+			Type:  MessageTypeError,
+			Error: "broadcasting to this channel is not allowed",
+		})
+		return
+	}
+
 	// Check if this is an admin channel subscription (read-only)
 	if len(msg.Channel) >= 15 && msg.Channel[:15] == "realtime:admin:" {
 		// Admin channels require admin role and are subscribe-only (no broadcasting)
@@ -617,10 +697,10 @@ func (h *RealtimeHandler) handleBroadcast(conn *Connection, msg ClientMessage) {
 		return
 	}
 
-	// Subscribe connection to channel if not already subscribed
-	if !conn.IsSubscribed(msg.Channel) {
-		conn.Subscribe(msg.Channel)
+	if !h.authorizeChannelAction(conn, msg.Channel, ChannelActionBroadcast) {
+		return
 	}
+	h.joinSendingChannel(conn, msg.Channel)
 
 	// Build broadcast payload
 	broadcastPayload := map[string]interface{}{
@@ -628,14 +708,22 @@ func (h *RealtimeHandler) handleBroadcast(conn *Connection, msg ClientMessage) {
 		"payload": msg.Payload,
 	}
 
-	// Broadcast to all connections subscribed to this channel
-	h.manager.BroadcastToChannel(msg.Channel, ServerMessage{
+	// Broadcast to all connections subscribed to this channel, on every instance
+	err := h.manager.BroadcastGlobal(msg.Channel, ServerMessage{
 		Type:    MessageTypeBroadcast,
 		Channel: msg.Channel,
 		Payload: map[string]interface{}{
 			"broadcast": broadcastPayload,
 		},
 	})
+	if err != nil {
+		log.Error().Err(err).Str("channel", msg.Channel).Msg("Failed to publish broadcast")
+		_ = conn.SendMessage(ServerMessage{
+			Type:  MessageTypeError,
+			Error: "failed to broadcast message",
+		})
+		return
+	}
 
 	// Send acknowledgment if messageId is present (Supabase-compatible broadcast acks)
 	if msg.MessageID != "" {
@@ -663,10 +751,19 @@ func (h *RealtimeHandler) handlePresence(conn *Connection, msg ClientMessage) {
 		return
 	}
 
-	// Subscribe connection to channel if not already subscribed
-	if !conn.IsSubscribed(msg.Channel) {
-		conn.Subscribe(msg.Channel)
+	// Server-published channels only accept subscriptions
+	if h.channelAuthorizer(msg.Channel) != nil {
+		_ = conn.SendMessage(ServerMessage{
+			Type:  MessageTypeError,
+			Error: "presence is not allowed on this channel",
+		})
+		return
+	}
+
+	if !h.authorizeChannelAction(conn, msg.Channel, ChannelActionPresence) {
+		return
 	}
+	h.joinSendingChannel(conn, msg.Channel)
 
 	// Parse payload to get presence event and data
 	var presencePayload struct {
@@ -835,6 +932,8 @@ func (h *RealtimeHandler) handleAccessToken(conn *Connection, msg ClientMessage)
 	if h.subManager != nil {
 		h.subManager.UpdateConnectionClaims(conn.ID, claims.RawClaims)
 	}
+	// Leave the guarded channels the new claims no longer allow
+	h.reauthorizeChannels(conn)
 
 	log.Info().
 		Str("connection_id", conn.ID).
@@ -1,7 +1,9 @@
 package realtime
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -409,6 +411,176 @@ func TestRealtimeHandler_Struct(t *testing.T) {
 	})
 }
 
+func TestRealtimeHandler_ChannelAuthorizer(t *testing.T) {
+	handler := NewRealtimeHandler(nil, nil, nil)
+	var authorized []string
+	handler.SetChannelAuthorizer("kb:", func(ctx context.Context, conn *Connection, channel string) error {
+		authorized = append(authorized, channel)
+		if conn.Role != "admin" {
+			return errors.New("access denied")
+		}
+		return nil
+	})
+
+	t.Run("subscribes when the authorizer accepts", func(t *testing.T) {
+		conn := NewConnectionSync("conn1", nil, nil, "admin", nil)
+		handler.handleMessage(conn, ClientMessage{Type: MessageTypeSubscribe, Channel: "kb:123"})
+		assert.True(t, conn.IsSubscribed("kb:123"))
+	})
+
+	t.Run("refuses when the authorizer rejects", func(t *testing.T) {
+		conn := NewConnectionSync("conn2", nil, nil, "anon", nil)
+		handler.handleMessage(conn, ClientMessage{Type: MessageTypeSubscribe, Channel: "kb:123"})
+		assert.False(t, conn.IsSubscribed("kb:123"))
+	})
+
+	t.Run("clients cannot broadcast", func(t *testing.T) {
+		conn := NewConnectionSync("conn3", nil, nil, "admin", nil)
+		handler.handleMessage(conn, ClientMessage{Type: MessageTypeBroadcast, Channel: "kb:456", Event: "document_status"})
+		assert.False(t, conn.IsSubscribed("kb:456"))
+	})
+
+	assert.Equal(t, []string{"kb:123", "kb:123"}, authorized)
+	assert.Nil(t, handler.channelAuthorizer("realtime:admin:connections"))
+}
+
+type staticAuthService struct {
+	claims *TokenClaims
+}
+
+func (s staticAuthService) ValidateToken(token string) (*TokenClaims, error) {
+	return s.claims, nil
+}
+
+func TestRealtimeHandler_ChannelPolicy(t *testing.T) {
+	handler := NewRealtimeHandler(NewManager(context.Background()), nil, nil)
+	handler.SetChannelPolicy("team:", func(ctx context.Context, conn *Connection, channel string, action ChannelAction) error {
+		if conn.Claims["team_id"] != channel[len("team:"):] {
+			return errors.New("access denied")
+		}
+		return nil
+	})
+	handler.SetChannelPolicy("team:announcements", func(ctx context.Context, conn *Connection, channel string, action ChannelAction) error {
+		if action != ChannelActionSubscribe {
+			return errors.New("read-only channel")
+		}
+		return nil
+	})
+	member := map[string]interface{}{"team_id": "42"}
+
+	t.Run("subscribes to open channels without a table", func(t *testing.T) {
+		conn := NewConnectionSync("conn1", nil, nil, "anon", nil)
+		handler.handleMessage(conn, ClientMessage{Type: MessageTypeSubscribe, Channel: "room-1"})
+		assert.True(t, conn.IsSubscribed("room-1"))
+	})
+
+	t.Run("applies the policy to subscriptions", func(t *testing.T) {
+		conn := NewConnectionSync("conn2", nil, nil, "authenticated", member)
+		handler.handleMessage(conn, ClientMessage{Type: MessageTypeSubscribe, Channel: "team:42"})
+		handler.handleMessage(conn, ClientMessage{Type: MessageTypeSubscribe, Channel: "team:7"})
+		assert.True(t, conn.IsSubscribed("team:42"))
+		assert.False(t, conn.IsSubscribed("team:7"))
+	})
+
+	t.Run("applies the policy to broadcasts and presence", func(t *testing.T) {
+		conn := NewConnectionSync("conn3", nil, nil, "authenticated", member)
+		handler.handleMessage(conn, ClientMessage{Type: MessageTypeBroadcast, Channel: "team:7", Event: "cursor"})
+		assert.False(t, conn.IsSubscribed("team:7"))
+
+		handler.handleMessage(conn, ClientMessage{
+			Type:    MessageTypePresence,
+			Channel: "team:7",
+			Payload: json.RawMessage(`{"event":"track","key":"user-1","state":{"status":"online"}}`),
+		})
+		assert.Equal(t, 0, handler.presenceManager.GetChannelPresenceCount("team:7"))
+
+		handler.handleMessage(conn, ClientMessage{
+			Type:    MessageTypePresence,
+			Channel: "team:42",
+			Payload: json.RawMessage(`{"event":"track","key":"user-1","state":{"status":"online"}}`),
+		})
+		assert.Equal(t, 1, handler.presenceManager.GetChannelPresenceCount("team:42"))
+	})
+
+	t.Run("longest prefix wins", func(t *testing.T) {
+		conn := NewConnectionSync("conn4", nil, nil, "authenticated", member)
+		handler.handleMessage(conn, ClientMessage{Type: MessageTypeSubscribe, Channel: "team:announcements"})
+		assert.True(t, conn.IsSubscribed("team:announcements"))
+
+		handler.handleMessage(conn, ClientMessage{Type: MessageTypeUnsubscribe, Channel: "team:announcements"})
+		assert.False(t, conn.IsSubscribed("team:announcements"))
+
+		handler.handleMessage(conn, ClientMessage{Type: MessageTypeBroadcast, Channel: "team:announcements", Event: "news"})
+		assert.False(t, conn.IsSubscribed("team:announcements"))
+	})
+
+	t.Run("sending does not subscribe to broadcast-only channels", func(t *testing.T) {
+		h := NewRealtimeHandler(NewManager(context.Background()), nil, nil)
+		h.SetChannelPolicy("alerts:", func(ctx context.Context, conn *Connection, channel string, action ChannelAction) error {
+			if action == ChannelActionSubscribe {
+				return errors.New("write-only channel")
+			}
+			return nil
+		})
+
+		conn := NewConnectionSync("conn6", nil, nil, "authenticated", nil)
+		h.handleMessage(conn, ClientMessage{Type: MessageTypeBroadcast, Channel: "alerts:ops", Event: "page"})
+		assert.False(t, conn.IsSubscribed("alerts:ops"))
+
+		h.handleMessage(conn, ClientMessage{
+			Type:    MessageTypePresence,
+			Channel: "alerts:ops",
+			Payload: json.RawMessage(`{"event":"track","key":"user-6"}`),
+		})
+		assert.False(t, conn.IsSubscribed("alerts:ops"))
+		assert.Equal(t, 1, h.presenceManager.GetChannelPresenceCount("alerts:ops"))
+
+		h.handleMessage(conn, ClientMessage{Type: MessageTypeBroadcast, Channel: "room-2", Event: "cursor"})
+		assert.True(t, conn.IsSubscribed("room-2"))
+	})
+
+	t.Run("leaves channels a new access token no longer allows", func(t *testing.T) {
+		userID := "user-5"
+		h := NewRealtimeHandler(NewManager(context.Background()), staticAuthService{claims: &TokenClaims{
+			UserID:    userID,
+			Role:      "authenticated",
+			RawClaims: map[string]interface{}{"team_id": "7"},
+		}}, nil)
+		h.SetChannelPolicy("team:", handler.channelPolicies["team:"])
+
+		conn := NewConnectionSync("conn5", nil, &userID, "authenticated", member)
+		h.handleMessage(conn, ClientMessage{Type: MessageTypeSubscribe, Channel: "team:42"})
+		h.handleMessage(conn, ClientMessage{Type: MessageTypeSubscribe, Channel: "room-1"})
+		h.handleMessage(conn, ClientMessage{
+			Type:    MessageTypePresence,
+			Channel: "team:42",
+			Payload: json.RawMessage(`{"event":"track","key":"user-5"}`),
+		})
+		require.True(t, conn.IsSubscribed("team:42"))
+
+		h.handleMessage(conn, ClientMessage{Type: MessageTypeAccessToken, Token: "new-token"})
+		assert.False(t, conn.IsSubscribed("team:42"))
+		assert.True(t, conn.IsSubscribed("room-1"))
+		assert.Equal(t, 0, h.presenceManager.GetChannelPresenceCount("team:42"))
+	})
+}
+
+func TestRealtimeHandler_PresenceOnServerChannels(t *testing.T) {
+	handler := NewRealtimeHandler(NewManager(context.Background()), nil, nil)
+	handler.SetChannelAuthorizer("kb:", func(ctx context.Context, conn *Connection, channel string) error {
+		return nil
+	})
+
+	conn := NewConnectionSync("conn1", nil, nil, "authenticated", nil)
+	handler.handleMessage(conn, ClientMessage{
+		Type:    MessageTypePresence,
+		Channel: "kb:123",
+		Payload: json.RawMessage(`{"event":"track","key":"user-1"}`),
+	})
+	assert.False(t, conn.IsSubscribed("kb:123"))
+	assert.Equal(t, 0, handler.presenceManager.GetChannelPresenceCount("kb:123"))
+}
+
 // =============================================================================
 // Integration Tests
 // =============================================================================
@@ -42,6 +42,9 @@ type ListenerPool struct {
 	subManager *SubscriptionManager
 	pubsub     pubsub.PubSub
 
+	// onChange is called with the schema and table of every change event
+	onChange func(schema, table string)
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -100,6 +103,12 @@ func NewListenerPool(
 	}
 }
 
+// SetChangeObserver registers a function called with the schema and table of every change
+// event, e.g. to drop cached query results. It must be called before Start.
+func (lp *ListenerPool) SetChangeObserver(fn func(schema, table string)) {
+	lp.onChange = fn
+}
+
 // Start begins the listener pool.
 func (lp *ListenerPool) Start() error {
 	// Start worker goroutines
@@ -309,6 +318,10 @@ func (lp *ListenerPool) processNotification(notification *pgconn.Notification) {
 			Msg("Processing notification")
 	}
 
+	if lp.onChange != nil {
+		lp.onChange(event.Schema, event.Table)
+	}
+
 	// Compute ETA for job queue events
 	if event.Schema == "jobs" && event.Table == "queue" && event.Record != nil {
 		lp.enrichJobWithETA(&event)
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -197,6 +198,21 @@ func TestListenerPoolMetrics_Struct(t *testing.T) {
 	assert.Equal(t, 4000, metrics.QueueCapacity)
 }
 
+func TestListenerPool_ChangeObserver(t *testing.T) {
+	handler := NewRealtimeHandler(NewManager(context.Background()), nil, nil)
+	lp := NewListenerPool(nil, handler, nil, nil, DefaultListenerPoolConfig())
+
+	var changed []string
+	lp.SetChangeObserver(func(schema, table string) {
+		changed = append(changed, schema+"."+table)
+	})
+
+	lp.processNotification(&pgconn.Notification{Payload: `{"type":"INSERT","schema":"public","table":"orders","record":{"id":1}}`})
+	lp.processNotification(&pgconn.Notification{Payload: `not json`})
+
+	assert.Equal(t, []string{"public.orders"}, changed)
+}
+
 func TestListenerPool_EnrichJobWithETA_NoProgress(t *testing.T) {
 	ctx := context.Background()
 	manager := NewManager(ctx)
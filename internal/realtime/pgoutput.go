@@ -0,0 +1,349 @@
+package realtime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// errShortMessage is returned for replication messages that end early
+var errShortMessage = errors.New("replication message too short")
+
+// postgresEpoch is the origin of replication protocol timestamps
+var postgresEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// pgoutputColumn is a column of a relation sent by pgoutput
+type pgoutputColumn struct {
+	Name    string
+	TypeOID uint32
+}
+
+// pgoutputRelation describes a table before its rows are sent by pgoutput
+type pgoutputRelation struct {
+	Schema  string
+	Table   string
+	Columns []pgoutputColumn
+}
+
+// pgoutputDecoder turns pgoutput (protocol version 1) messages into change events. It keeps
+// the relations announced by the server, so one decoder must be used per replication stream.
+type pgoutputDecoder struct {
+	relations map[uint32]*pgoutputRelation
+}
+
+// newPgoutputDecoder creates a decoder for a new replication stream
+func newPgoutputDecoder() *pgoutputDecoder {
+	return &pgoutputDecoder{relations: make(map[uint32]*pgoutputRelation)}
+}
+
+// Decode decodes a pgoutput message. It returns the change event of inserts, updates and
+// deletes, and nil for the other messages (begin, commit, relation, truncate, ...).
+func (d *pgoutputDecoder) Decode(data []byte) (*ChangeEvent, error) {
+	if len(data) == 0 {
+		return nil, errShortMessage
+	}
+
+	r := &pgoutputReader{data: data[1:]}
+	switch data[0] {
+	case 'R':
+		return nil, d.decodeRelation(r)
+	case 'I':
+		return d.decodeInsert(r)
+	case 'U':
+		return d.decodeUpdate(r)
+	case 'D':
+		return d.decodeDelete(r)
+	}
+	return nil, nil
+}
+
+// decodeRelation remembers the schema, name and columns of a relation
+func (d *pgoutputDecoder) decodeRelation(r *pgoutputReader) error {
+	id := r.uint32()
+	rel := &pgoutputRelation{
+		Schema: r.string(),
+		Table:  r.string(),
+	}
+	if rel.Schema == "" {
+		rel.Schema = "pg_catalog"
+	}
+	r.byte() // replica identity
+
+	columns := int(r.uint16())
+	for i := 0; i < columns && r.err == nil; i++ {
+		r.byte() // flags
+		col := pgoutputColumn{Name: r.string(), TypeOID: r.uint32()}
+		r.uint32() // type modifier
+		rel.Columns = append(rel.Columns, col)
+	}
+	if r.err != nil {
+		return r.err
+	}
+
+	d.relations[id] = rel
+	return nil
+}
+
+// decodeInsert decodes the new row of an insert
+func (d *pgoutputDecoder) decodeInsert(r *pgoutputReader) (*ChangeEvent, error) {
+	rel, err := d.relation(r.uint32())
+	if err != nil {
+		return nil, err
+	}
+	if kind := r.byte(); r.err == nil && kind != 'N' {
+		return nil, fmt.Errorf("unexpected insert tuple kind %q", kind)
+	}
+
+	record, _, err := decodeTuple(r, rel)
+	if err != nil {
+		return nil, err
+	}
+	return &ChangeEvent{Type: "INSERT", Schema: rel.Schema, Table: rel.Table, Record: record}, nil
+}
+
+// decodeUpdate decodes the old row, when the table's replica identity sends it, and the
+// new row of an update. Unchanged TOASTed values are taken from the old row.
+func (d *pgoutputDecoder) decodeUpdate(r *pgoutputReader) (*ChangeEvent, error) {
+	rel, err := d.relation(r.uint32())
+	if err != nil {
+		return nil, err
+	}
+	event := &ChangeEvent{Type: "UPDATE", Schema: rel.Schema, Table: rel.Table}
+
+	kind := r.byte()
+	if kind == 'K' || kind == 'O' {
+		if event.OldRecord, _, err = decodeTuple(r, rel); err != nil {
+			return nil, err
+		}
+		kind = r.byte()
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if kind != 'N' {
+		return nil, fmt.Errorf("unexpected update tuple kind %q", kind)
+	}
+
+	record, unchanged, err := decodeTuple(r, rel)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range unchanged {
+		if value, ok := event.OldRecord[name]; ok {
+			record[name] = value
+		}
+	}
+	event.Record = record
+	return event, nil
+}
+
+// decodeDelete decodes the old row of a delete
+func (d *pgoutputDecoder) decodeDelete(r *pgoutputReader) (*ChangeEvent, error) {
+	rel, err := d.relation(r.uint32())
+	if err != nil {
+		return nil, err
+	}
+	if kind := r.byte(); r.err == nil && kind != 'K' && kind != 'O' {
+		return nil, fmt.Errorf("unexpected delete tuple kind %q", kind)
+	}
+
+	oldRecord, _, err := decodeTuple(r, rel)
+	if err != nil {
+		return nil, err
+	}
+	return &ChangeEvent{Type: "DELETE", Schema: rel.Schema, Table: rel.Table, OldRecord: oldRecord}, nil
+}
+
+// relation returns an announced relation
+func (d *pgoutputDecoder) relation(id uint32) (*pgoutputRelation, error) {
+	rel, ok := d.relations[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown relation %d", id)
+	}
+	return rel, nil
+}
+
+// decodeTuple decodes a row into a record. It also returns the columns holding unchanged
+// TOASTed values, which pgoutput leaves out.
+func decodeTuple(r *pgoutputReader, rel *pgoutputRelation) (map[string]interface{}, []string, error) {
+	columns := int(r.uint16())
+	if r.err == nil && columns != len(rel.Columns) {
+		return nil, nil, fmt.Errorf("relation %s.%s has %d columns, row has %d", rel.Schema, rel.Table, len(rel.Columns), columns)
+	}
+
+	record := make(map[string]interface{}, columns)
+	var unchanged []string
+	for i := 0; i < columns && r.err == nil; i++ {
+		col := rel.Columns[i]
+		switch kind := r.byte(); kind {
+		case 'n':
+			record[col.Name] = nil
+		case 'u':
+			unchanged = append(unchanged, col.Name)
+		case 't':
+			value := r.bytes(int(r.uint32()))
+			record[col.Name] = decodeColumnValue(col.TypeOID, string(value))
+		default:
+			if r.err == nil {
+				return nil, nil, fmt.Errorf("unexpected column kind %q", kind)
+			}
+		}
+	}
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+	return record, unchanged, nil
+}
+
+// decodeColumnValue converts a column in text format to the JSON value to_jsonb gives the
+// notify triggers, so both change sources send the same records. Types without a JSON
+// counterpart, arrays included, are kept as text.
+func decodeColumnValue(oid uint32, text string) interface{} {
+	switch oid {
+	case pgtype.BoolOID:
+		return text == "t"
+	case pgtype.Int2OID, pgtype.Int4OID, pgtype.Int8OID, pgtype.OIDOID:
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n
+		}
+	case pgtype.Float4OID, pgtype.Float8OID:
+		if f, err := strconv.ParseFloat(text, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+			return f
+		}
+	case pgtype.NumericOID:
+		// NaN and Infinity are not JSON numbers
+		if json.Valid([]byte(text)) {
+			return json.Number(text)
+		}
+	case pgtype.JSONOID, pgtype.JSONBOID:
+		var value interface{}
+		if err := json.Unmarshal([]byte(text), &value); err == nil {
+			return value
+		}
+	case pgtype.TimestamptzOID:
+		for _, layout := range []string{"2006-01-02 15:04:05.999999999-07", "2006-01-02 15:04:05.999999999-07:00", "2006-01-02 15:04:05.999999999-07:00:00"} {
+			if t, err := time.Parse(layout, text); err == nil {
+				return t.Format("2006-01-02T15:04:05.999999-07:00")
+			}
+		}
+	case pgtype.TimestampOID:
+		return strings.Replace(text, " ", "T", 1)
+	}
+	return text
+}
+
+// parseLSN parses a WAL position in the X/X text form
+func parseLSN(s string) (uint64, error) {
+	hi, lo, ok := strings.Cut(s, "/")
+	if !ok {
+		return 0, fmt.Errorf("invalid LSN %q", s)
+	}
+	h, err := strconv.ParseUint(hi, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid LSN %q", s)
+	}
+	l, err := strconv.ParseUint(lo, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid LSN %q", s)
+	}
+	return h<<32 | l, nil
+}
+
+// formatLSN formats a WAL position in the X/X text form
+func formatLSN(lsn uint64) string {
+	return fmt.Sprintf("%X/%X", lsn>>32, uint32(lsn))
+}
+
+// parseXLogData splits a replication XLogData message ('w') into its start position and the
+// pgoutput message it carries
+func parseXLogData(data []byte) (uint64, []byte, error) {
+	if len(data) < 25 {
+		return 0, nil, errShortMessage
+	}
+	return binary.BigEndian.Uint64(data[1:9]), data[25:], nil
+}
+
+// parseKeepalive parses a primary keepalive message ('k'), returning the server's WAL end
+// and whether it wants a status update right away
+func parseKeepalive(data []byte) (uint64, bool, error) {
+	if len(data) < 18 {
+		return 0, false, errShortMessage
+	}
+	return binary.BigEndian.Uint64(data[1:9]), data[17] != 0, nil
+}
+
+// standbyStatusUpdate builds the status update ('r') reporting lsn as written, flushed and
+// applied, which lets the server recycle the WAL before it
+func standbyStatusUpdate(lsn uint64, now time.Time) []byte {
+	buf := make([]byte, 34)
+	buf[0] = 'r'
+	binary.BigEndian.PutUint64(buf[1:], lsn)
+	binary.BigEndian.PutUint64(buf[9:], lsn)
+	binary.BigEndian.PutUint64(buf[17:], lsn)
+	binary.BigEndian.PutUint64(buf[25:], uint64(now.Sub(postgresEpoch).Microseconds()))
+	return buf
+}
+
+// pgoutputReader reads the fields of a replication message. After the first short read
+// it returns zero values and err is set.
+type pgoutputReader struct {
+	data []byte
+	err  error
+}
+
+func (r *pgoutputReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || len(r.data) < n {
+		r.err = errShortMessage
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *pgoutputReader) byte() byte {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *pgoutputReader) uint16() uint16 {
+	if b := r.bytes(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *pgoutputReader) uint32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+// string reads a NUL-terminated string
+func (r *pgoutputReader) string() string {
+	if r.err != nil {
+		return ""
+	}
+	i := bytes.IndexByte(r.data, 0)
+	if i < 0 {
+		r.err = errShortMessage
+		return ""
+	}
+	s := string(r.data[:i])
+	r.data = r.data[i+1:]
+	return s
+}
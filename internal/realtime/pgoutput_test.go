@@ -0,0 +1,251 @@
+package realtime
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pgoutputMessage builds pgoutput messages for the decoder tests
+type pgoutputMessage []byte
+
+func (m pgoutputMessage) byte(b byte) pgoutputMessage {
+	return append(m, b)
+}
+
+func (m pgoutputMessage) uint16(v uint16) pgoutputMessage {
+	return binary.BigEndian.AppendUint16(m, v)
+}
+
+func (m pgoutputMessage) uint32(v uint32) pgoutputMessage {
+	return binary.BigEndian.AppendUint32(m, v)
+}
+
+func (m pgoutputMessage) string(s string) pgoutputMessage {
+	return append(append(m, s...), 0)
+}
+
+// tuple appends a row; nil values are sent as NULL and "\x00toast" as unchanged TOAST
+func (m pgoutputMessage) tuple(values ...interface{}) pgoutputMessage {
+	m = m.uint16(uint16(len(values)))
+	for _, v := range values {
+		switch v := v.(type) {
+		case nil:
+			m = m.byte('n')
+		case string:
+			if v == "\x00toast" {
+				m = m.byte('u')
+				continue
+			}
+			m = m.byte('t').uint32(uint32(len(v)))
+			m = append(m, v...)
+		}
+	}
+	return m
+}
+
+// relationMessage announces public.todos (id int8, title text, meta jsonb, done bool)
+func relationMessage() []byte {
+	m := pgoutputMessage{'R'}.uint32(42).string("public").string("todos").byte('f').uint16(4)
+	m = m.byte(1).string("id").uint32(pgtype.Int8OID).uint32(0xFFFFFFFF)
+	m = m.byte(0).string("title").uint32(pgtype.TextOID).uint32(0xFFFFFFFF)
+	m = m.byte(0).string("meta").uint32(pgtype.JSONBOID).uint32(0xFFFFFFFF)
+	m = m.byte(0).string("done").uint32(pgtype.BoolOID).uint32(0xFFFFFFFF)
+	return m
+}
+
+func TestPgoutputDecoder_Insert(t *testing.T) {
+	d := newPgoutputDecoder()
+	event, err := d.Decode(relationMessage())
+	require.NoError(t, err)
+	assert.Nil(t, event)
+
+	msg := pgoutputMessage{'I'}.uint32(42).byte('N').tuple("1", "Buy milk", `{"tags":["home"]}`, "f")
+	event, err = d.Decode(msg)
+	require.NoError(t, err)
+	require.NotNil(t, event)
+
+	assert.Equal(t, "INSERT", event.Type)
+	assert.Equal(t, "public", event.Schema)
+	assert.Equal(t, "todos", event.Table)
+	assert.Equal(t, map[string]interface{}{
+		"id":    int64(1),
+		"title": "Buy milk",
+		"meta":  map[string]interface{}{"tags": []interface{}{"home"}},
+		"done":  false,
+	}, event.Record)
+	assert.Nil(t, event.OldRecord)
+}
+
+func TestPgoutputDecoder_UpdateWithOldRow(t *testing.T) {
+	d := newPgoutputDecoder()
+	_, err := d.Decode(relationMessage())
+	require.NoError(t, err)
+
+	msg := pgoutputMessage{'U'}.uint32(42).
+		byte('O').tuple("1", "Buy milk", `{"big":true}`, "f").
+		byte('N').tuple("1", nil, "\x00toast", "t")
+	event, err := d.Decode(msg)
+	require.NoError(t, err)
+	require.NotNil(t, event)
+
+	assert.Equal(t, "UPDATE", event.Type)
+	assert.Equal(t, "Buy milk", event.OldRecord["title"])
+	assert.Equal(t, false, event.OldRecord["done"])
+	assert.Nil(t, event.Record["title"])
+	assert.Equal(t, true, event.Record["done"])
+	// Unchanged TOASTed values come from the old row
+	assert.Equal(t, map[string]interface{}{"big": true}, event.Record["meta"])
+}
+
+func TestPgoutputDecoder_UpdateWithoutOldRow(t *testing.T) {
+	d := newPgoutputDecoder()
+	_, err := d.Decode(relationMessage())
+	require.NoError(t, err)
+
+	msg := pgoutputMessage{'U'}.uint32(42).byte('N').tuple("1", "Buy milk", "\x00toast", "t")
+	event, err := d.Decode(msg)
+	require.NoError(t, err)
+	require.NotNil(t, event)
+
+	assert.Nil(t, event.OldRecord)
+	assert.NotContains(t, event.Record, "meta")
+	assert.Equal(t, int64(1), event.Record["id"])
+}
+
+func TestPgoutputDecoder_Delete(t *testing.T) {
+	d := newPgoutputDecoder()
+	_, err := d.Decode(relationMessage())
+	require.NoError(t, err)
+
+	msg := pgoutputMessage{'D'}.uint32(42).byte('O').tuple("7", "Done", nil, "t")
+	event, err := d.Decode(msg)
+	require.NoError(t, err)
+	require.NotNil(t, event)
+
+	assert.Equal(t, "DELETE", event.Type)
+	assert.Nil(t, event.Record)
+	assert.Equal(t, int64(7), event.OldRecord["id"])
+	assert.Nil(t, event.OldRecord["meta"])
+}
+
+func TestPgoutputDecoder_Errors(t *testing.T) {
+	t.Run("unknown relation", func(t *testing.T) {
+		d := newPgoutputDecoder()
+		_, err := d.Decode(pgoutputMessage{'I'}.uint32(42).byte('N').tuple("1", "x", nil, "t"))
+		assert.ErrorContains(t, err, "unknown relation 42")
+	})
+
+	t.Run("column count mismatch", func(t *testing.T) {
+		d := newPgoutputDecoder()
+		_, err := d.Decode(relationMessage())
+		require.NoError(t, err)
+		_, err = d.Decode(pgoutputMessage{'I'}.uint32(42).byte('N').tuple("1", "x"))
+		assert.ErrorContains(t, err, "has 4 columns, row has 2")
+	})
+
+	t.Run("truncated message", func(t *testing.T) {
+		d := newPgoutputDecoder()
+		_, err := d.Decode(relationMessage())
+		require.NoError(t, err)
+		msg := pgoutputMessage{'I'}.uint32(42).byte('N').tuple("1", "Buy milk", "{}", "f")
+		_, err = d.Decode(msg[:len(msg)-3])
+		assert.ErrorIs(t, err, errShortMessage)
+	})
+
+	t.Run("empty message", func(t *testing.T) {
+		_, err := newPgoutputDecoder().Decode(nil)
+		assert.ErrorIs(t, err, errShortMessage)
+	})
+
+	t.Run("other messages are skipped", func(t *testing.T) {
+		event, err := newPgoutputDecoder().Decode(pgoutputMessage{'B'}.uint32(0).uint32(1).uint32(0).uint32(2).uint32(3))
+		assert.NoError(t, err)
+		assert.Nil(t, event)
+	})
+}
+
+func TestDecodeColumnValue(t *testing.T) {
+	tests := []struct {
+		name string
+		oid  uint32
+		text string
+		want interface{}
+	}{
+		{"integer", pgtype.Int4OID, "-12", int64(-12)},
+		{"float", pgtype.Float8OID, "1.5", 1.5},
+		{"float NaN stays text", pgtype.Float8OID, "NaN", "NaN"},
+		{"numeric", pgtype.NumericOID, "12345678901234567890.12", json.Number("12345678901234567890.12")},
+		{"numeric NaN stays text", pgtype.NumericOID, "NaN", "NaN"},
+		{"boolean", pgtype.BoolOID, "t", true},
+		{"json", pgtype.JSONOID, `[1,"a"]`, []interface{}{float64(1), "a"}},
+		{"timestamptz", pgtype.TimestamptzOID, "2024-03-01 12:30:00.25+00", "2024-03-01T12:30:00.25+00:00"},
+		{"timestamptz with minutes offset", pgtype.TimestamptzOID, "2024-03-01 12:30:00+05:30", "2024-03-01T12:30:00+05:30"},
+		{"timestamp", pgtype.TimestampOID, "2024-03-01 12:30:00", "2024-03-01T12:30:00"},
+		{"uuid", pgtype.UUIDOID, "0b0e1c34-8c4d-4bb1-9c77-2c5cfbd0d1a2", "0b0e1c34-8c4d-4bb1-9c77-2c5cfbd0d1a2"},
+		{"array stays text", pgtype.TextArrayOID, "{a,b}", "{a,b}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, decodeColumnValue(tt.oid, tt.text))
+		})
+	}
+}
+
+func TestLSN(t *testing.T) {
+	lsn, err := parseLSN("16/B374D848")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0x16B374D848), lsn)
+	assert.Equal(t, "16/B374D848", formatLSN(lsn))
+
+	_, err = parseLSN("16B374D848")
+	assert.Error(t, err)
+	_, err = parseLSN("zz/1")
+	assert.Error(t, err)
+}
+
+func TestReplicationMessages(t *testing.T) {
+	t.Run("xlog data", func(t *testing.T) {
+		msg := binary.BigEndian.AppendUint64([]byte{'w'}, 100)
+		msg = binary.BigEndian.AppendUint64(msg, 200)
+		msg = binary.BigEndian.AppendUint64(msg, 0)
+		msg = append(msg, 'B', 1, 2)
+
+		start, data, err := parseXLogData(msg)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(100), start)
+		assert.Equal(t, []byte{'B', 1, 2}, data)
+
+		_, _, err = parseXLogData(msg[:10])
+		assert.ErrorIs(t, err, errShortMessage)
+	})
+
+	t.Run("keepalive", func(t *testing.T) {
+		msg := binary.BigEndian.AppendUint64([]byte{'k'}, 300)
+		msg = binary.BigEndian.AppendUint64(msg, 0)
+		msg = append(msg, 1)
+
+		walEnd, reply, err := parseKeepalive(msg)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(300), walEnd)
+		assert.True(t, reply)
+	})
+
+	t.Run("standby status", func(t *testing.T) {
+		now := postgresEpoch.Add(3 * time.Second)
+		msg := standbyStatusUpdate(500, now)
+
+		require.Len(t, msg, 34)
+		assert.Equal(t, byte('r'), msg[0])
+		assert.Equal(t, uint64(500), binary.BigEndian.Uint64(msg[1:]))
+		assert.Equal(t, uint64(500), binary.BigEndian.Uint64(msg[17:]))
+		assert.Equal(t, uint64(3_000_000), binary.BigEndian.Uint64(msg[25:]))
+		assert.Equal(t, byte(0), msg[33])
+	})
+}
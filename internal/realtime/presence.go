@@ -186,3 +186,14 @@ func (pm *PresenceManager) GetChannelPresenceCount(channel string) int {
 	}
 	return 0
 }
+
+// LeaveChannel removes the presence a connection tracks in a channel, if any
+func (pm *PresenceManager) LeaveChannel(channel, connID string) *PresenceInfo {
+	pm.mu.RLock()
+	key, ok := pm.connPresences[connID][channel]
+	pm.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return pm.Untrack(channel, key, connID)
+}
@@ -223,6 +223,21 @@ func TestPresenceManager_GetChannelPresenceCount(t *testing.T) {
 	})
 }
 
+func TestPresenceManager_LeaveChannel(t *testing.T) {
+	pm := NewPresenceManager()
+	pm.Track("room:1", "user:1", PresenceState{"status": "online"}, nil, "conn-1")
+	pm.Track("room:2", "user:1", PresenceState{}, nil, "conn-1")
+
+	info := pm.LeaveChannel("room:1", "conn-1")
+	require.NotNil(t, info)
+	assert.Equal(t, "user:1", info.Key)
+	assert.Equal(t, 0, pm.GetChannelPresenceCount("room:1"))
+	assert.Equal(t, 1, pm.GetChannelPresenceCount("room:2"))
+
+	assert.Nil(t, pm.LeaveChannel("room:1", "conn-1"))
+	assert.Nil(t, pm.LeaveChannel("room:2", "conn-2"))
+}
+
 func TestPresenceInfo_Struct(t *testing.T) {
 	userID := "user-123"
 	info := PresenceInfo{
@@ -0,0 +1,389 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// excludedColumnsTTL is how long the excluded columns of the schema registry are cached
+const excludedColumnsTTL = 30 * time.Second
+
+// ReplicationConfig holds configuration for the logical replication listener.
+type ReplicationConfig struct {
+	Publication    string        // Publication holding the realtime tables
+	SlotPrefix     string        // Prefix of the temporary replication slot
+	StatusInterval time.Duration // How often the processed WAL position is reported (default: 10s)
+	RetryInterval  time.Duration // Delay before reconnecting after an error (default: 5s)
+}
+
+// ReplicationListener streams changes of the tables in a publication through logical
+// replication (pgoutput) and delivers them like the notify triggers' events. Row images
+// are decoded from the WAL, so writes to these tables pay for neither a trigger nor
+// pg_notify, and records are not bound by the 8000 byte NOTIFY payload limit.
+//
+// Each listener uses its own temporary slot, dropped by Postgres when the connection
+// ends, so every instance receives all changes and no WAL is retained for stopped
+// instances. As with LISTEN, changes committed while disconnected are not delivered.
+type ReplicationListener struct {
+	connString string
+	pool       *pgxpool.Pool
+	handler    *RealtimeHandler
+	subManager *SubscriptionManager
+	config     ReplicationConfig
+
+	// onChange is called with the schema and table of every change event
+	onChange func(schema, table string)
+
+	// Excluded columns per schema.table, only used by the streaming goroutine
+	excluded         map[string][]string
+	excludedLoadedAt time.Time
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	stopped int32
+
+	// Metrics
+	eventsReceived uint64
+	reconnections  uint64
+}
+
+// NewReplicationListener creates a logical replication listener. connString must connect
+// as a role with the REPLICATION attribute that may create the publication.
+func NewReplicationListener(
+	connString string,
+	pool *pgxpool.Pool,
+	handler *RealtimeHandler,
+	subManager *SubscriptionManager,
+	config ReplicationConfig,
+) *ReplicationListener {
+	if config.StatusInterval <= 0 {
+		config.StatusInterval = 10 * time.Second
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = 5 * time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ReplicationListener{
+		connString: connString,
+		pool:       pool,
+		handler:    handler,
+		subManager: subManager,
+		config:     config,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// SetChangeObserver registers a function called with the schema and table of every change
+// event, e.g. to drop cached query results. It must be called before Start.
+func (rl *ReplicationListener) SetChangeObserver(fn func(schema, table string)) {
+	rl.onChange = fn
+}
+
+// Start begins streaming changes in the background.
+func (rl *ReplicationListener) Start() error {
+	rl.wg.Add(1)
+	go rl.run()
+
+	log.Info().
+		Str("publication", rl.config.Publication).
+		Msg("Realtime logical replication listener started")
+	return nil
+}
+
+// Stop ends the stream and waits for it to close, which drops the temporary slot.
+func (rl *ReplicationListener) Stop() {
+	if !atomic.CompareAndSwapInt32(&rl.stopped, 0, 1) {
+		return
+	}
+
+	rl.cancel()
+	rl.wg.Wait()
+
+	log.Info().
+		Uint64("events_received", atomic.LoadUint64(&rl.eventsReceived)).
+		Uint64("reconnections", atomic.LoadUint64(&rl.reconnections)).
+		Msg("Realtime logical replication listener stopped")
+}
+
+// run streams changes, reconnecting after errors until stopped
+func (rl *ReplicationListener) run() {
+	defer rl.wg.Done()
+
+	for {
+		err := rl.stream()
+		if rl.ctx.Err() != nil {
+			return
+		}
+		log.Error().Err(err).Msg("Realtime replication stream failed, reconnecting...")
+
+		select {
+		case <-time.After(rl.config.RetryInterval):
+			atomic.AddUint64(&rl.reconnections, 1)
+		case <-rl.ctx.Done():
+			return
+		}
+	}
+}
+
+// stream opens a replication connection with a new temporary slot and processes its
+// messages until the connection fails or the listener stops
+func (rl *ReplicationListener) stream() error {
+	connConfig, err := pgconn.ParseConfig(rl.connString)
+	if err != nil {
+		return fmt.Errorf("failed to parse connection string: %w", err)
+	}
+	connConfig.RuntimeParams["replication"] = "database"
+	// Timestamps are decoded from their ISO text form
+	connConfig.RuntimeParams["datestyle"] = "ISO"
+
+	conn, err := pgconn.ConnectConfig(rl.ctx, connConfig)
+	if err != nil {
+		return fmt.Errorf("failed to open replication connection: %w", err)
+	}
+	defer conn.Close(context.Background()) //nolint:errcheck
+
+	if err := rl.ensurePublication(conn); err != nil {
+		return err
+	}
+
+	// Slot names may only hold lower case letters, digits and underscores
+	slot := rl.config.SlotPrefix + "_" + strings.ReplaceAll(uuid.NewString(), "-", "")[:12]
+	results, err := conn.Exec(rl.ctx, fmt.Sprintf("CREATE_REPLICATION_SLOT %s TEMPORARY LOGICAL pgoutput NOEXPORT_SNAPSHOT", slot)).ReadAll()
+	if err != nil {
+		return fmt.Errorf("failed to create replication slot: %w", err)
+	}
+	if len(results) != 1 || len(results[0].Rows) != 1 || len(results[0].Rows[0]) < 2 {
+		return errors.New("unexpected CREATE_REPLICATION_SLOT result")
+	}
+	pos, err := parseLSN(string(results[0].Rows[0][1]))
+	if err != nil {
+		return err
+	}
+
+	conn.Frontend().SendQuery(&pgproto3.Query{String: fmt.Sprintf(
+		"START_REPLICATION SLOT %s LOGICAL %s (proto_version '1', publication_names '%s')",
+		slot, formatLSN(pos), rl.config.Publication,
+	)})
+	if err := conn.Frontend().Flush(); err != nil {
+		return fmt.Errorf("failed to start replication: %w", err)
+	}
+	if err := awaitCopyBoth(rl.ctx, conn); err != nil {
+		return fmt.Errorf("failed to start replication: %w", err)
+	}
+
+	log.Info().
+		Str("slot", slot).
+		Str("publication", rl.config.Publication).
+		Str("lsn", formatLSN(pos)).
+		Msg("Realtime replication stream started")
+
+	decoder := newPgoutputDecoder()
+	nextStatus := time.Now().Add(rl.config.StatusInterval)
+	for {
+		if !time.Now().Before(nextStatus) {
+			if err := sendStandbyStatus(conn, pos); err != nil {
+				return err
+			}
+			nextStatus = time.Now().Add(rl.config.StatusInterval)
+		}
+
+		ctx, cancel := context.WithDeadline(rl.ctx, nextStatus)
+		msg, err := conn.ReceiveMessage(ctx)
+		cancel()
+		if err != nil {
+			if rl.ctx.Err() != nil {
+				return nil
+			}
+			if pgconn.Timeout(err) {
+				continue
+			}
+			return fmt.Errorf("failed to receive replication message: %w", err)
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.ErrorResponse:
+			return pgconn.ErrorResponseToPgError(msg)
+		case *pgproto3.CopyData:
+			if len(msg.Data) == 0 {
+				continue
+			}
+			switch msg.Data[0] {
+			case 'k':
+				walEnd, replyRequested, err := parseKeepalive(msg.Data)
+				if err != nil {
+					return err
+				}
+				// Transactions are sent whole at commit, so a keepalive never splits one
+				if walEnd > pos {
+					pos = walEnd
+				}
+				if replyRequested {
+					nextStatus = time.Now()
+				}
+			case 'w':
+				walStart, data, err := parseXLogData(msg.Data)
+				if err != nil {
+					return err
+				}
+				event, err := decoder.Decode(data)
+				if err != nil {
+					log.Error().Err(err).Msg("Failed to decode replication message")
+				} else if event != nil {
+					rl.processEvent(event)
+				}
+				if end := walStart + uint64(len(data)); end > pos {
+					pos = end
+				}
+			}
+		}
+	}
+}
+
+// ensurePublication creates the publication when it does not exist yet. Tables are added
+// to it when realtime is enabled on them.
+func (rl *ReplicationListener) ensurePublication(conn *pgconn.PgConn) error {
+	results, err := conn.Exec(rl.ctx, fmt.Sprintf("SELECT 1 FROM pg_publication WHERE pubname = '%s'", rl.config.Publication)).ReadAll()
+	if err != nil {
+		return fmt.Errorf("failed to look up publication: %w", err)
+	}
+	if len(results) == 1 && len(results[0].Rows) > 0 {
+		return nil
+	}
+
+	_, err = conn.Exec(rl.ctx, fmt.Sprintf("CREATE PUBLICATION %s", rl.config.Publication)).ReadAll()
+	var pgErr *pgconn.PgError
+	// Another instance created it first (duplicate_object, or unique_violation on pg_publication)
+	if errors.As(err, &pgErr) && (pgErr.Code == "42710" || pgErr.Code == "23505") {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create publication: %w", err)
+	}
+
+	log.Info().Str("publication", rl.config.Publication).Msg("Created realtime publication")
+	return nil
+}
+
+// processEvent delivers a decoded change to the subscribers allowed to see it
+func (rl *ReplicationListener) processEvent(event *ChangeEvent) {
+	atomic.AddUint64(&rl.eventsReceived, 1)
+
+	for _, col := range rl.excludedColumns(event.Schema, event.Table) {
+		delete(event.Record, col)
+		delete(event.OldRecord, col)
+	}
+
+	log.Debug().
+		Str("table", event.Schema+"."+event.Table).
+		Str("type", event.Type).
+		Msg("Processing replicated change")
+
+	if rl.onChange != nil {
+		rl.onChange(event.Schema, event.Table)
+	}
+
+	if rl.subManager == nil {
+		return
+	}
+
+	filteredEvents := rl.subManager.FilterEventForSubscribers(rl.ctx, event)
+	manager := rl.handler.GetManager()
+	for connID, filteredEvent := range filteredEvents {
+		manager.mu.RLock()
+		conn, exists := manager.connections[connID]
+		manager.mu.RUnlock()
+
+		if exists {
+			_ = conn.SendMessage(ServerMessage{
+				Type:    MessageTypeChange,
+				Payload: filteredEvent,
+			})
+		}
+	}
+}
+
+// excludedColumns returns the columns of a table left out of its change events, which the
+// notify triggers read from the schema registry on every change
+func (rl *ReplicationListener) excludedColumns(schema, table string) []string {
+	if rl.pool == nil {
+		return nil
+	}
+
+	if rl.excluded == nil || time.Since(rl.excludedLoadedAt) > excludedColumnsTTL {
+		excluded, err := rl.loadExcludedColumns()
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to load excluded realtime columns")
+		} else {
+			rl.excluded = excluded
+		}
+		// Failed loads are retried after the TTL rather than on every change
+		rl.excludedLoadedAt = time.Now()
+	}
+	return rl.excluded[schema+"."+table]
+}
+
+// loadExcludedColumns reads the excluded columns of all realtime tables
+func (rl *ReplicationListener) loadExcludedColumns() (map[string][]string, error) {
+	ctx, cancel := context.WithTimeout(rl.ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := rl.pool.Query(ctx, `
+		SELECT schema_name, table_name, excluded_columns
+		FROM realtime.schema_registry
+		WHERE realtime_enabled = true AND cardinality(excluded_columns) > 0
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	excluded := make(map[string][]string)
+	for rows.Next() {
+		var schema, table string
+		var columns []string
+		if err := rows.Scan(&schema, &table, &columns); err != nil {
+			return nil, err
+		}
+		excluded[schema+"."+table] = columns
+	}
+	return excluded, rows.Err()
+}
+
+// awaitCopyBoth waits for the server to switch to streaming after START_REPLICATION
+func awaitCopyBoth(ctx context.Context, conn *pgconn.PgConn) error {
+	for {
+		msg, err := conn.ReceiveMessage(ctx)
+		if err != nil {
+			return err
+		}
+		switch msg := msg.(type) {
+		case *pgproto3.CopyBothResponse:
+			return nil
+		case *pgproto3.ErrorResponse:
+			return pgconn.ErrorResponseToPgError(msg)
+		}
+	}
+}
+
+// sendStandbyStatus reports the WAL position processed so far
+func sendStandbyStatus(conn *pgconn.PgConn, pos uint64) error {
+	conn.Frontend().Send(&pgproto3.CopyData{Data: standbyStatusUpdate(pos, time.Now())})
+	if err := conn.Frontend().Flush(); err != nil {
+		return fmt.Errorf("failed to send standby status: %w", err)
+	}
+	return nil
+}
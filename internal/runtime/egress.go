@@ -0,0 +1,46 @@
+package runtime
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/nimbleflux/fluxbase/internal/egress"
+)
+
+// allowNetFlag returns Deno's network permission flag. When the egress policy
+// only allows exact host names, Deno itself is restricted to those hosts plus
+// Fluxbase and the egress proxy, so raw sockets cannot bypass the proxy.
+func allowNetFlag(policy *egress.Policy, publicURL string) string {
+	if policy == nil {
+		return "--allow-net"
+	}
+	hosts, ok := policy.ExactAllowedHosts()
+	if !ok {
+		return "--allow-net"
+	}
+
+	for _, rawURL := range []string{publicURL, policy.RuntimeProxyURL()} {
+		if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+			hosts = append(hosts, u.Host)
+		}
+	}
+	return "--allow-net=" + strings.Join(hosts, ",")
+}
+
+// egressEnv returns proxy variables that route fetch() through the egress proxy.
+// Calls back to Fluxbase itself bypass the proxy.
+func egressEnv(policy *egress.Policy, publicURL string) []string {
+	if policy == nil || policy.RuntimeProxyURL() == "" {
+		return nil
+	}
+
+	proxyURL := policy.RuntimeProxyURL()
+	env := []string{
+		"HTTP_PROXY=" + proxyURL,
+		"HTTPS_PROXY=" + proxyURL,
+	}
+	if u, err := url.Parse(publicURL); err == nil && u.Hostname() != "" {
+		env = append(env, "NO_PROXY="+u.Hostname())
+	}
+	return env
+}
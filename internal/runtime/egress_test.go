@@ -0,0 +1,44 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/egress"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEgressPolicy(t *testing.T, allowedHosts ...string) *egress.Policy {
+	t.Helper()
+	policy, err := egress.NewPolicy(&config.EgressConfig{Enabled: true, AllowedHosts: allowedHosts}, nil)
+	require.NoError(t, err)
+	policy.SetRuntimeProxyURL("http://127.0.0.1:41000")
+	return policy
+}
+
+func TestAllowNetFlag(t *testing.T) {
+	publicURL := "http://fluxbase.internal:8080"
+
+	assert.Equal(t, "--allow-net", allowNetFlag(nil, publicURL), "no policy")
+	assert.Equal(t, "--allow-net", allowNetFlag(newEgressPolicy(t), publicURL), "no allowlist")
+	assert.Equal(t, "--allow-net", allowNetFlag(newEgressPolicy(t, "api.stripe.com", "*.example.com"), publicURL), "wildcards are enforced by the proxy only")
+	assert.Equal(t,
+		"--allow-net=api.stripe.com,hooks.slack.com,fluxbase.internal:8080,127.0.0.1:41000",
+		allowNetFlag(newEgressPolicy(t, "api.stripe.com", "hooks.slack.com"), publicURL),
+	)
+}
+
+func TestEgressEnv(t *testing.T) {
+	assert.Nil(t, egressEnv(nil, "http://localhost:8080"))
+
+	policy, err := egress.NewPolicy(&config.EgressConfig{Enabled: true}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, egressEnv(policy, "http://localhost:8080"), "no proxy running")
+
+	assert.Equal(t, []string{
+		"HTTP_PROXY=http://127.0.0.1:41000",
+		"HTTPS_PROXY=http://127.0.0.1:41000",
+		"NO_PROXY=localhost",
+	}, egressEnv(newEgressPolicy(t), "http://localhost:8080"))
+}
@@ -16,6 +16,7 @@ var blockedVars = map[string]bool{
 	"FLUXBASE_EMAIL_SMTP_PASSWORD":     true,
 	"FLUXBASE_SECURITY_SETUP_TOKEN":    true,
 	"FLUXBASE_ENCRYPTION_KEY":          true, // Used for encrypting secrets at rest
+	"FLUXBASE_EGRESS_PROXY_URL":        true, // May contain proxy credentials
 }
 
 // buildEnv creates the environment variable list for execution
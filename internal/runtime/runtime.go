@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/egress"
 	"github.com/rs/zerolog/log"
 	"github.com/shirou/gopsutil/v4/mem"
 )
@@ -198,6 +199,9 @@ func (r *DenoRuntime) Execute(
 			Msg("SDK tokens NOT generated - missing jwtSecret or publicURL")
 	}
 
+	// A streamed body is read by the bridge from stdin
+	req.StreamBody = req.BodyStream != nil
+
 	// Wrap the user code with our runtime bridge
 	wrappedCode := r.wrapCode(code, req)
 
@@ -254,8 +258,9 @@ func (r *DenoRuntime) Execute(
 	}
 
 	// Apply permissions - always allow net for SDK API calls
+	egressPolicy := egress.GetGlobalPolicy()
 	if permissions.AllowNet || (userToken != "" || serviceToken != "") {
-		args = append(args, "--allow-net")
+		args = append(args, allowNetFlag(egressPolicy, r.publicURL))
 	}
 	if permissions.AllowEnv {
 		args = append(args, "--allow-env")
@@ -282,6 +287,10 @@ func (r *DenoRuntime) Execute(
 
 	// Set environment variables (including secrets)
 	cmd.Env = buildEnv(req, r.runtimeType, r.publicURL, userToken, serviceToken, cancelSignal, secrets)
+	cmd.Env = append(cmd.Env, egressEnv(egressPolicy, r.publicURL)...)
+	if req.BodyStream != nil {
+		cmd.Stdin = req.BodyStream
+	}
 
 	// Capture stdout and stderr with streaming
 	// Note: Pipes must be closed on error to avoid file descriptor leaks
@@ -1,6 +1,10 @@
 package runtime
 
-import "github.com/google/uuid"
+import (
+	"io"
+
+	"github.com/google/uuid"
+)
 
 // RuntimeType distinguishes between edge functions and job functions
 type RuntimeType int
@@ -42,6 +46,10 @@ type ExecutionRequest struct {
 	Body      string            `json:"body,omitempty"`
 	Params    map[string]string `json:"params,omitempty"`
 	SessionID string            `json:"session_id,omitempty"`
+	// BodyStream replaces Body for large inputs, it is piped to the function's stdin and
+	// read as the request body stream
+	BodyStream io.Reader `json:"-"`
+	StreamBody bool      `json:"stream_body,omitempty"` // set by Execute when BodyStream is used
 
 	// Job context (jobs)
 	Payload    map[string]interface{} `json:"payload,omitempty"`
@@ -204,7 +204,11 @@ const Fluxbase = _functionUtils;
     const webRequest = new Request(request.url || 'http://localhost', {
       method: request.method || 'POST',
       headers: request.headers || { 'Content-Type': 'application/json' },
-      body: request.method !== 'GET' && request.method !== 'HEAD' ? request.body : undefined
+      body: request.method !== 'GET' && request.method !== 'HEAD'
+        ? (request.stream_body ? Deno.stdin.readable : request.body)
+        : undefined,
+      // Streamed bodies (e.g. storage trigger objects) are piped through stdin
+      duplex: request.stream_body ? 'half' : undefined
     });
 
     // Add user context to request object for convenience
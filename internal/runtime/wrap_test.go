@@ -175,6 +175,24 @@ func TestWrapFunctionCode_RequestJSONEmbedded(t *testing.T) {
 	assert.Contains(t, result, req.ID.String())
 }
 
+func TestWrapFunctionCode_StreamBody(t *testing.T) {
+	r := NewRuntime(RuntimeTypeFunction, "secret", "http://localhost:8080")
+	req := ExecutionRequest{
+		ID:         uuid.New(),
+		Name:       "strip-exif",
+		Method:     "POST",
+		BodyStream: strings.NewReader("object bytes"),
+		StreamBody: true,
+	}
+
+	result := r.wrapFunctionCode(`export function handler(req) { return new Response("ok"); }`, req)
+
+	// The stream itself is never embedded, the bridge reads it from stdin
+	assert.Contains(t, result, `"stream_body":true`)
+	assert.Contains(t, result, "Deno.stdin.readable")
+	assert.NotContains(t, result, "object bytes")
+}
+
 // =============================================================================
 // wrapJobCode Tests
 // =============================================================================
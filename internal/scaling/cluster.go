@@ -0,0 +1,159 @@
+package scaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// leaderApplicationPrefix prefixes the application_name of leader lock sessions
+const leaderApplicationPrefix = "fluxbase-leader:"
+
+var (
+	nodeID   string
+	nodeIDMu sync.Mutex
+)
+
+// NodeID returns the identifier of this instance in the cluster.
+// Defaults to "<hostname>-<pid>" unless set with SetNodeID.
+func NodeID() string {
+	nodeIDMu.Lock()
+	defer nodeIDMu.Unlock()
+
+	if nodeID == "" {
+		host, err := os.Hostname()
+		if err != nil || host == "" {
+			host = "fluxbase"
+		}
+		nodeID = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	return nodeID
+}
+
+// SetNodeID overrides the identifier of this instance (e.g. from configuration).
+// Empty values are ignored.
+func SetNodeID(id string) {
+	if id == "" {
+		return
+	}
+	nodeIDMu.Lock()
+	nodeID = id
+	nodeIDMu.Unlock()
+}
+
+// leaderApplicationName returns the application_name used for a node's lock sessions
+func leaderApplicationName(node string) string {
+	name := leaderApplicationPrefix + node
+	// application_name is truncated to NAMEDATALEN-1 (63) bytes by PostgreSQL
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return name
+}
+
+// RoleStatus describes which node holds a leader-elected role
+type RoleStatus struct {
+	Role           string     `json:"role"`
+	LockID         int64      `json:"lock_id"`
+	HeldByThisNode bool       `json:"held_by_this_node"`
+	LeaderSince    *time.Time `json:"leader_since,omitempty"`
+	HolderNodeID   string     `json:"holder_node_id,omitempty"`
+	HolderPID      *int       `json:"holder_pid,omitempty"`
+	HolderAddress  string     `json:"holder_address,omitempty"`
+}
+
+// ClusterStatus is this node's view of leader election across the cluster
+type ClusterStatus struct {
+	NodeID         string       `json:"node_id"`
+	LeaderElection bool         `json:"leader_election"`
+	Roles          []RoleStatus `json:"roles"`
+}
+
+// GetClusterStatus reports which node holds each elector's role.
+// Holders are found through pg_locks, so roles held by other instances are visible too.
+func GetClusterStatus(ctx context.Context, pool *pgxpool.Pool, electors []*LeaderElector) (*ClusterStatus, error) {
+	status := &ClusterStatus{
+		NodeID:         NodeID(),
+		LeaderElection: len(electors) > 0,
+		Roles:          make([]RoleStatus, 0, len(electors)),
+	}
+
+	for _, le := range electors {
+		role := RoleStatus{
+			Role:           le.Name(),
+			LockID:         le.LockID(),
+			HeldByThisNode: le.IsLeader(),
+		}
+		if since := le.LeaderSince(); !since.IsZero() {
+			role.LeaderSince = &since
+		}
+
+		if pool != nil {
+			holder, err := lookupLockHolder(ctx, pool, le.LockID())
+			if err != nil {
+				return nil, err
+			}
+			if holder != nil {
+				role.HolderPID = &holder.pid
+				role.HolderAddress = holder.address
+				role.HolderNodeID = holder.nodeID
+			}
+		}
+		if role.HeldByThisNode && role.HolderNodeID == "" {
+			role.HolderNodeID = status.NodeID
+		}
+
+		status.Roles = append(status.Roles, role)
+	}
+
+	return status, nil
+}
+
+type lockHolder struct {
+	pid     int
+	address string
+	nodeID  string
+}
+
+// lookupLockHolder finds the session holding a bigint advisory lock.
+// A bigint key is stored in pg_locks as classid (high 32 bits) and objid (low 32 bits).
+func lookupLockHolder(ctx context.Context, pool *pgxpool.Pool, lockID int64) (*lockHolder, error) {
+	classID, objID := splitAdvisoryLockID(lockID)
+
+	var holder lockHolder
+	var appName string
+	err := pool.QueryRow(ctx, `
+		SELECT l.pid, COALESCE(host(a.client_addr), ''), COALESCE(a.application_name, '')
+		FROM pg_locks l
+		LEFT JOIN pg_stat_activity a ON a.pid = l.pid
+		WHERE l.locktype = 'advisory' AND l.granted
+		  AND l.classid::bigint = $1 AND l.objid::bigint = $2 AND l.objsubid = 1
+		LIMIT 1
+	`, classID, objID).Scan(&holder.pid, &holder.address, &appName)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up lock holder: %w", err)
+	}
+
+	holder.nodeID = strings.TrimPrefix(appName, leaderApplicationPrefix)
+	if holder.nodeID == appName {
+		// Held by a session that is not a tagged leader session
+		holder.nodeID = ""
+	}
+	return &holder, nil
+}
+
+// splitAdvisoryLockID splits a bigint advisory lock key into its pg_locks classid and objid
+func splitAdvisoryLockID(lockID int64) (int64, int64) {
+	u := uint64(lockID)
+	return int64(u >> 32), int64(u & 0xFFFFFFFF)
+}
@@ -0,0 +1,75 @@
+package scaling
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeID(t *testing.T) {
+	original := NodeID()
+	t.Cleanup(func() { SetNodeID(original) })
+
+	assert.NotEmpty(t, original)
+
+	SetNodeID("node-a")
+	assert.Equal(t, "node-a", NodeID())
+
+	SetNodeID("")
+	assert.Equal(t, "node-a", NodeID(), "empty IDs are ignored")
+}
+
+func TestLeaderApplicationName(t *testing.T) {
+	assert.Equal(t, "fluxbase-leader:node-a", leaderApplicationName("node-a"))
+
+	long := leaderApplicationName(strings.Repeat("x", 100))
+	assert.Len(t, long, 63)
+	assert.True(t, strings.HasPrefix(long, leaderApplicationPrefix))
+}
+
+func TestSplitAdvisoryLockID(t *testing.T) {
+	classID, objID := splitAdvisoryLockID(JobsSchedulerLockID)
+	assert.Equal(t, int64(0x466C7578), classID)
+	assert.Equal(t, int64(1), objID)
+
+	classID, objID = splitAdvisoryLockID(-1)
+	assert.Equal(t, int64(0xFFFFFFFF), classID)
+	assert.Equal(t, int64(0xFFFFFFFF), objID)
+}
+
+func TestGetClusterStatus(t *testing.T) {
+	original := NodeID()
+	t.Cleanup(func() { SetNodeID(original) })
+	SetNodeID("node-a")
+
+	t.Run("no electors means leader election is off", func(t *testing.T) {
+		status, err := GetClusterStatus(context.Background(), nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "node-a", status.NodeID)
+		assert.False(t, status.LeaderElection)
+		assert.Empty(t, status.Roles)
+	})
+
+	t.Run("reports roles held locally", func(t *testing.T) {
+		leader := NewLeaderElector(nil, JobsSchedulerLockID, "jobs-scheduler")
+		leader.isLeader = true
+		follower := NewLeaderElector(nil, RPCSchedulerLockID, "rpc-scheduler")
+
+		status, err := GetClusterStatus(context.Background(), nil, []*LeaderElector{leader, follower})
+		require.NoError(t, err)
+		assert.True(t, status.LeaderElection)
+		require.Len(t, status.Roles, 2)
+
+		assert.Equal(t, "jobs-scheduler", status.Roles[0].Role)
+		assert.True(t, status.Roles[0].HeldByThisNode)
+		assert.Equal(t, "node-a", status.Roles[0].HolderNodeID)
+
+		assert.Equal(t, "rpc-scheduler", status.Roles[1].Role)
+		assert.Equal(t, RPCSchedulerLockID, status.Roles[1].LockID)
+		assert.False(t, status.Roles[1].HeldByThisNode)
+		assert.Empty(t, status.Roles[1].HolderNodeID)
+	})
+}
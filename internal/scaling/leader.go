@@ -22,11 +22,57 @@ const (
 
 	// RPCSchedulerLockID is the advisory lock ID for the RPC scheduler
 	RPCSchedulerLockID int64 = 0x466C7578_00000003 // "Flux" + 3
+
+	// BranchCleanupLockID is the advisory lock ID for the expired branch cleanup scheduler
+	BranchCleanupLockID int64 = 0x466C7578_00000004 // "Flux" + 4
+
+	// LogRetentionLockID is the advisory lock ID for the log retention cleanup service
+	LogRetentionLockID int64 = 0x466C7578_00000005 // "Flux" + 5
+
+	// KBSnapshotSchedulerLockID is the advisory lock ID for the knowledge base snapshot scheduler
+	KBSnapshotSchedulerLockID int64 = 0x466C7578_00000006 // "Flux" + 6
+
+	// MeteringExportLockID is the advisory lock ID for the billing/metering exporter
+	MeteringExportLockID int64 = 0x466C7578_00000007 // "Flux" + 7
+
+	// NotificationCheckLockID is the advisory lock ID for credential expiry and configuration drift checks
+	NotificationCheckLockID int64 = 0x466C7578_00000008 // "Flux" + 8
+
+	// ConversationIndexLockID is the advisory lock ID for the conversation search indexer
+	ConversationIndexLockID int64 = 0x466C7578_00000009 // "Flux" + 9
+
+	// AnalyticsExportLockID is the advisory lock ID for the analytics warehouse exporter
+	AnalyticsExportLockID int64 = 0x466C7578_0000000A // "Flux" + 10
+
+	// TableStatsLockID is the advisory lock ID for the daily table size collector
+	TableStatsLockID int64 = 0x466C7578_0000000B // "Flux" + 11
+
+	// MaintenanceSchedulerLockID is the advisory lock ID for the table maintenance scheduler
+	MaintenanceSchedulerLockID int64 = 0x466C7578_0000000C // "Flux" + 12
+
+	// TableSyncLockID is the advisory lock ID for the instance-to-instance table syncer
+	TableSyncLockID int64 = 0x466C7578_0000000D // "Flux" + 13
+
+	// AuditRetentionLockID is the advisory lock ID for the audit log retention pruner
+	AuditRetentionLockID int64 = 0x466C7578_0000000E // "Flux" + 14
+
+	// ScheduledJobsLockID is the advisory lock ID for the scheduled jobs scheduler
+	ScheduledJobsLockID int64 = 0x466C7578_0000000F // "Flux" + 15
+
+	// TUSPruneLockID is the advisory lock ID for the expired resumable upload pruner
+	TUSPruneLockID int64 = 0x466C7578_00000010 // "Flux" + 16
+
+	// StorageLifecycleLockID is the advisory lock ID for the storage lifecycle rule sweeper
+	StorageLifecycleLockID int64 = 0x466C7578_00000011 // "Flux" + 17
 )
 
 // LeaderElector manages leader election using PostgreSQL advisory locks.
 // It uses pg_try_advisory_lock for non-blocking lock acquisition, allowing
 // the instance to gracefully handle not being the leader.
+//
+// The lock is taken on a dedicated connection that is held for as long as this
+// instance is the leader, because advisory locks belong to a database session and
+// a pooled connection may be handed to other queries between checks.
 type LeaderElector struct {
 	pool          *pgxpool.Pool
 	lockID        int64
@@ -36,6 +82,11 @@ type LeaderElector struct {
 	ctx           context.Context
 	cancel        context.CancelFunc
 	checkInterval time.Duration
+
+	// conn is the session holding the advisory lock while this instance is the leader
+	conn        *pgxpool.Conn
+	connMu      sync.Mutex
+	leaderSince time.Time
 }
 
 // NewLeaderElector creates a new leader elector for the given lock ID.
@@ -76,9 +127,7 @@ func (le *LeaderElector) Stop() {
 	le.cancel()
 
 	// Release the lock if we were the leader
-	if le.IsLeader() {
-		le.releaseLock()
-	}
+	le.releaseLock()
 }
 
 // IsLeader returns true if this instance currently holds the leader lock.
@@ -121,32 +170,38 @@ func (le *LeaderElector) electionLoop(onBecomeLeader, onLoseLeadership func()) {
 	}
 }
 
-// tryAcquireLock attempts to acquire the advisory lock.
-// PostgreSQL advisory locks are session-level, so we need to keep the connection alive.
+// tryAcquireLock attempts to acquire the advisory lock, or verifies that the
+// session holding it is still alive when this instance is already the leader.
 func (le *LeaderElector) tryAcquireLock(onBecomeLeader, onLoseLeadership func()) {
 	ctx, cancel := context.WithTimeout(le.ctx, 5*time.Second)
 	defer cancel()
 
-	// Try to acquire the lock (non-blocking)
-	var acquired bool
-	err := le.pool.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", le.lockID).Scan(&acquired)
+	acquired, err := le.acquireOrCheck(ctx)
 	if err != nil {
 		log.Error().
 			Err(err).
 			Str("lock", le.lockName).
 			Msg("Failed to try advisory lock")
-		return
+		if !le.IsLeader() {
+			return
+		}
+		// The lock session is gone, so the lock has been released server-side
+		acquired = false
 	}
 
 	le.isLeaderMu.Lock()
 	wasLeader := le.isLeader
 	le.isLeader = acquired
+	if acquired && !wasLeader {
+		le.leaderSince = time.Now()
+	}
 	le.isLeaderMu.Unlock()
 
 	// Handle state transitions
 	if acquired && !wasLeader {
 		log.Info().
 			Str("lock", le.lockName).
+			Str("node_id", NodeID()).
 			Msg("Acquired leader lock - this instance is now the leader")
 		if onBecomeLeader != nil {
 			le.safeCallback(onBecomeLeader, "onBecomeLeader")
@@ -161,6 +216,57 @@ func (le *LeaderElector) tryAcquireLock(onBecomeLeader, onLoseLeadership func())
 	}
 }
 
+// acquireOrCheck pings the lock session when leader, otherwise tries to take the lock
+// on a fresh dedicated connection. The connection is kept only if the lock was acquired.
+func (le *LeaderElector) acquireOrCheck(ctx context.Context) (bool, error) {
+	le.connMu.Lock()
+	defer le.connMu.Unlock()
+
+	if le.conn != nil {
+		if _, err := le.conn.Exec(ctx, "SELECT 1"); err != nil {
+			le.discardConn()
+			return false, err
+		}
+		return true, nil
+	}
+
+	conn, err := le.pool.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var acquired bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", le.lockID).Scan(&acquired); err != nil {
+		conn.Release()
+		return false, err
+	}
+	if !acquired {
+		conn.Release()
+		return false, nil
+	}
+
+	// Tag the session so ClusterStatus can report which node holds the role
+	if _, err := conn.Exec(ctx, "SELECT set_config('application_name', $1, false)", leaderApplicationName(NodeID())); err != nil {
+		log.Debug().Err(err).Str("lock", le.lockName).Msg("Failed to set application_name on leader session")
+	}
+
+	le.conn = conn
+	return true, nil
+}
+
+// discardConn closes the lock session instead of returning it to the pool,
+// which releases the advisory lock server-side. Callers must hold connMu.
+func (le *LeaderElector) discardConn() {
+	if le.conn == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_ = le.conn.Conn().Close(ctx)
+	le.conn.Release()
+	le.conn = nil
+}
+
 // safeCallback executes a callback with panic recovery
 func (le *LeaderElector) safeCallback(fn func(), name string) {
 	defer func() {
@@ -175,66 +281,59 @@ func (le *LeaderElector) safeCallback(fn func(), name string) {
 	fn()
 }
 
-// releaseLock releases the advisory lock if held.
-//
-// Note: PostgreSQL advisory locks are session-level and are automatically
-// released when the connection that acquired them closes. With connection
-// pooling, we cannot guarantee that the same connection will be used to
-// release the lock that acquired it. Therefore:
-//
-// 1. We attempt to release the lock but handle failures gracefully
-// 2. The lock will be automatically released when the connection closes
-// 3. Leadership state is tracked in memory via isLeader
-// 4. If this instance was the leader, another instance will acquire the lock
+// releaseLock releases the advisory lock if held by closing the lock session.
 func (le *LeaderElector) releaseLock() {
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	defer cancel()
-
-	var released bool
-	err := le.pool.QueryRow(ctx, "SELECT pg_advisory_unlock($1)", le.lockID).Scan(&released)
-
-	// Clear local leadership state regardless of unlock result
 	le.isLeaderMu.Lock()
-	wasLeader := le.isLeader
 	le.isLeader = false
 	le.isLeaderMu.Unlock()
 
-	if err != nil {
-		// Connection may be closed or we may be using a different connection from the pool
-		// This is expected with connection pooling - the lock will be auto-released
-		// when the original connection closes
-		log.Debug().
-			Err(err).
-			Str("lock", le.lockName).
-			Bool("was_leader", wasLeader).
-			Msg("Advisory lock release failed (connection may have closed, lock auto-released)")
+	le.connMu.Lock()
+	defer le.connMu.Unlock()
+
+	if le.conn == nil {
 		return
 	}
+	le.discardConn()
 
-	if released {
-		log.Info().
-			Str("lock", le.lockName).
-			Msg("Released leader lock")
-	} else {
-		// Lock was not held by this session (connection was recycled)
-		log.Debug().
-			Str("lock", le.lockName).
-			Msg("Advisory lock not held by current session (connection was recycled)")
-	}
+	log.Info().
+		Str("lock", le.lockName).
+		Msg("Released leader lock")
 }
 
 // TryAcquireOnce tries to acquire the lock once and returns immediately.
 // This is useful for one-time checks without starting the election loop.
 func (le *LeaderElector) TryAcquireOnce(ctx context.Context) (bool, error) {
-	var acquired bool
-	err := le.pool.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", le.lockID).Scan(&acquired)
+	acquired, err := le.acquireOrCheck(ctx)
 	if err != nil {
 		return false, err
 	}
 
 	le.isLeaderMu.Lock()
+	if acquired && !le.isLeader {
+		le.leaderSince = time.Now()
+	}
 	le.isLeader = acquired
 	le.isLeaderMu.Unlock()
 
 	return acquired, nil
 }
+
+// Name returns the role name of this elector
+func (le *LeaderElector) Name() string {
+	return le.lockName
+}
+
+// LockID returns the advisory lock ID of this elector
+func (le *LeaderElector) LockID() int64 {
+	return le.lockID
+}
+
+// LeaderSince returns when this instance became the leader, or the zero time if it is not the leader
+func (le *LeaderElector) LeaderSince() time.Time {
+	le.isLeaderMu.RLock()
+	defer le.isLeaderMu.RUnlock()
+	if !le.isLeader {
+		return time.Time{}
+	}
+	return le.leaderSince
+}
@@ -37,6 +37,20 @@ func TestLeaderLockID_Constants(t *testing.T) {
 			JobsSchedulerLockID,
 			FunctionsSchedulerLockID,
 			RPCSchedulerLockID,
+			BranchCleanupLockID,
+			LogRetentionLockID,
+			KBSnapshotSchedulerLockID,
+			MeteringExportLockID,
+			NotificationCheckLockID,
+			ConversationIndexLockID,
+			AnalyticsExportLockID,
+			TableStatsLockID,
+			MaintenanceSchedulerLockID,
+			TableSyncLockID,
+			AuditRetentionLockID,
+			ScheduledJobsLockID,
+			TUSPruneLockID,
+			StorageLifecycleLockID,
 		}
 
 		seen := make(map[int64]bool)
@@ -54,6 +68,20 @@ func TestLeaderLockID_Constants(t *testing.T) {
 		assert.Equal(t, prefix, JobsSchedulerLockID&mask)
 		assert.Equal(t, prefix, FunctionsSchedulerLockID&mask)
 		assert.Equal(t, prefix, RPCSchedulerLockID&mask)
+		assert.Equal(t, prefix, BranchCleanupLockID&mask)
+		assert.Equal(t, prefix, LogRetentionLockID&mask)
+		assert.Equal(t, prefix, KBSnapshotSchedulerLockID&mask)
+		assert.Equal(t, prefix, MeteringExportLockID&mask)
+		assert.Equal(t, prefix, NotificationCheckLockID&mask)
+		assert.Equal(t, prefix, ConversationIndexLockID&mask)
+		assert.Equal(t, prefix, AnalyticsExportLockID&mask)
+		assert.Equal(t, prefix, TableStatsLockID&mask)
+		assert.Equal(t, prefix, MaintenanceSchedulerLockID&mask)
+		assert.Equal(t, prefix, TableSyncLockID&mask)
+		assert.Equal(t, prefix, AuditRetentionLockID&mask)
+		assert.Equal(t, prefix, ScheduledJobsLockID&mask)
+		assert.Equal(t, prefix, TUSPruneLockID&mask)
+		assert.Equal(t, prefix, StorageLifecycleLockID&mask)
 	})
 
 	t.Run("lock IDs are positive", func(t *testing.T) {
@@ -0,0 +1,346 @@
+package scheduledjobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/runtime"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// defaultRunTimeout bounds a run when no timeout is configured
+	defaultRunTimeout = 10 * time.Minute
+	// defaultMaxOutputBytes bounds the captured output when no limit is configured
+	defaultMaxOutputBytes = 64 * 1024
+	// maxOutputRows is the number of rows captured per SQL statement
+	maxOutputRows = 100
+)
+
+// FunctionInvoker runs edge functions. It is implemented by functions.Scheduler.
+type FunctionInvoker interface {
+	Invoke(ctx context.Context, name, namespace, triggerType, body string) (*runtime.ExecutionResult, error)
+}
+
+// FunctionOutput is the captured response of a function run
+type FunctionOutput struct {
+	Status int `json:"status"`
+	// Body is the response body, as JSON when the function returned JSON
+	Body      json.RawMessage `json:"body,omitempty"`
+	Logs      string          `json:"logs,omitempty"`
+	Truncated bool            `json:"truncated,omitempty"`
+}
+
+// StatementOutput is the captured result of one statement of a SQL run
+type StatementOutput struct {
+	Command      string      `json:"command"`
+	RowsAffected int64       `json:"rows_affected"`
+	Columns      []string    `json:"columns,omitempty"`
+	Rows         [][]*string `json:"rows,omitempty"`
+	Truncated    bool        `json:"truncated,omitempty"`
+}
+
+// RunRequest describes a job run
+type RunRequest struct {
+	Job         Job
+	Trigger     string
+	TriggeredBy *uuid.UUID
+}
+
+// Runner runs jobs and records each run with its output
+type Runner struct {
+	store          *Store
+	functions      FunctionInvoker
+	timeout        time.Duration
+	maxOutputBytes int
+	now            func() time.Time
+
+	// ctx is cancelled by Stop to abort background runs on shutdown
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRunner creates a job runner. Runs are cancelled after timeout and their output is
+// truncated to maxOutputBytes. functions may be nil, function jobs then fail.
+func NewRunner(store *Store, functions FunctionInvoker, timeout time.Duration, maxOutputBytes int) *Runner {
+	if timeout <= 0 {
+		timeout = defaultRunTimeout
+	}
+	if maxOutputBytes <= 0 {
+		maxOutputBytes = defaultMaxOutputBytes
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Runner{
+		store:          store,
+		functions:      functions,
+		timeout:        timeout,
+		maxOutputBytes: maxOutputBytes,
+		now:            time.Now,
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+}
+
+// Start records a run and executes it in the background, returning the running run.
+// Use ListRuns to follow its progress.
+func (r *Runner) Start(ctx context.Context, req RunRequest) (*Run, error) {
+	run, err := r.begin(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	started := *run
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.execute(r.ctx, req.Job, run)
+	}()
+	return &started, nil
+}
+
+// Run records a run and executes it, returning the finished run
+func (r *Runner) Run(ctx context.Context, req RunRequest) (*Run, error) {
+	run, err := r.begin(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	r.execute(ctx, req.Job, run)
+	return run, nil
+}
+
+// Stop cancels the background runs and waits for them to record their result
+func (r *Runner) Stop() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// begin records the run as running
+func (r *Runner) begin(ctx context.Context, req RunRequest) (*Run, error) {
+	run := &Run{
+		JobID:   req.Job.ID,
+		JobName: req.Job.Name,
+		Trigger: req.Trigger,
+	}
+	if err := r.store.startRun(ctx, run, r.timeout, req.TriggeredBy); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// execute runs the job's target and records the outcome
+func (r *Runner) execute(ctx context.Context, job Job, run *Run) {
+	runCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := r.now()
+	var output any
+	var execErr error
+	switch job.TargetType {
+	case TargetFunction:
+		output, execErr = r.runFunction(runCtx, job)
+	case TargetSQL:
+		output, execErr = r.runSQL(runCtx, job)
+	default:
+		execErr = fmt.Errorf("unknown target type: %s", job.TargetType)
+	}
+	finished := r.now()
+	duration := finished.Sub(start).Milliseconds()
+
+	run.FinishedAt = &finished
+	run.DurationMs = &duration
+	run.Status = StatusCompleted
+	if execErr != nil {
+		msg := execErr.Error()
+		run.Status = StatusFailed
+		run.Error = &msg
+	}
+	if output != nil {
+		if encoded, err := json.Marshal(output); err == nil {
+			run.Output = encoded
+		}
+	}
+
+	// Record the result even if the run was cancelled on shutdown
+	recordCtx, recordCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer recordCancel()
+	if err := r.store.finishRun(recordCtx, run); err != nil {
+		log.Error().Err(err).Str("run_id", run.ID).Msg("Failed to record scheduled job run result")
+	}
+
+	event := log.Info()
+	if execErr != nil {
+		event = log.Error().Err(execErr)
+	}
+	event.
+		Str("job", job.Name).
+		Str("target_type", string(job.TargetType)).
+		Str("trigger", run.Trigger).
+		Int64("duration_ms", duration).
+		Msg("Scheduled job run finished")
+}
+
+// runFunction posts the job's payload to its function. A function that throws or answers
+// with an error status fails the run.
+func (r *Runner) runFunction(ctx context.Context, job Job) (*FunctionOutput, error) {
+	if r.functions == nil {
+		return nil, errors.New("edge functions are not available")
+	}
+	name, namespace := "", DefaultNamespace
+	if job.FunctionName != nil {
+		name = *job.FunctionName
+	}
+	if job.FunctionNamespace != nil && *job.FunctionNamespace != "" {
+		namespace = *job.FunctionNamespace
+	}
+
+	result, err := r.functions.Invoke(ctx, name, namespace, "schedule", string(job.Payload))
+	if result == nil {
+		return nil, err
+	}
+
+	output := r.functionOutput(result)
+	if err != nil {
+		return output, err
+	}
+	if result.Error != "" {
+		return output, errors.New(result.Error)
+	}
+	if result.Status >= 400 {
+		return output, fmt.Errorf("function responded with status %d", result.Status)
+	}
+	return output, nil
+}
+
+// functionOutput captures the status, body and logs of a function response
+func (r *Runner) functionOutput(result *runtime.ExecutionResult) *FunctionOutput {
+	output := &FunctionOutput{Status: result.Status}
+
+	body, bodyTruncated := truncate(result.Body, r.maxOutputBytes)
+	switch {
+	case body == "":
+	case !bodyTruncated && json.Valid([]byte(body)):
+		output.Body = json.RawMessage(body)
+	default:
+		output.Body, _ = json.Marshal(body)
+	}
+
+	var logsTruncated bool
+	output.Logs, logsTruncated = truncate(result.Logs, r.maxOutputBytes)
+	output.Truncated = bodyTruncated || logsTruncated
+	return output
+}
+
+// runSQL runs the job's statements in a single transaction as service_role, capturing the
+// command tag and the first rows of every statement. Statements are sent with the simple
+// query protocol, so a snippet may hold several of them.
+//
+// The snippet runs on a connection taken out of the pool and closed afterwards, so session
+// settings it changes (role, search_path, timeouts) never reach other requests. Statements
+// that would end the transaction or change the role are rejected, and the transaction is
+// only committed when it is still open and still running as service_role.
+func (r *Runner) runSQL(ctx context.Context, job Job) ([]StatementOutput, error) {
+	if job.SQL == nil {
+		return nil, errors.New("job has no sql")
+	}
+	if err := validateJobSQL(*job.SQL); err != nil {
+		return nil, err
+	}
+
+	pooled, err := r.store.db.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire database connection: %w", err)
+	}
+	conn := pooled.Hijack()
+	defer func() { _ = conn.Close(context.Background()) }()
+
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	if _, err := tx.Exec(ctx, "SET LOCAL ROLE service_role"); err != nil {
+		return nil, fmt.Errorf("failed to set service_role: %w", err)
+	}
+
+	results := []StatementOutput{}
+	budget := r.maxOutputBytes
+	multi := tx.Conn().PgConn().Exec(ctx, *job.SQL)
+	for multi.NextResult() {
+		reader := multi.ResultReader()
+
+		var stmt StatementOutput
+		for _, fd := range reader.FieldDescriptions() {
+			stmt.Columns = append(stmt.Columns, fd.Name)
+		}
+		for reader.NextRow() {
+			if len(stmt.Rows) >= maxOutputRows || budget <= 0 {
+				stmt.Truncated = true
+				continue
+			}
+			stmt.Rows = append(stmt.Rows, textRow(reader.Values(), &budget))
+		}
+		tag, err := reader.Close()
+		if err != nil {
+			_ = multi.Close()
+			return results, err
+		}
+		stmt.Command = tag.String()
+		stmt.RowsAffected = tag.RowsAffected()
+		results = append(results, stmt)
+	}
+	if err := multi.Close(); err != nil {
+		return results, err
+	}
+
+	if status := conn.PgConn().TxStatus(); status != 'T' {
+		return results, errors.New("job sql ended its transaction")
+	}
+	var role string
+	if err := tx.QueryRow(ctx, "SELECT current_user").Scan(&role); err != nil {
+		return results, fmt.Errorf("failed to check role: %w", err)
+	}
+	if role != "service_role" {
+		return results, fmt.Errorf("job sql changed its role to %s", role)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return results, fmt.Errorf("failed to commit: %w", err)
+	}
+	return results, nil
+}
+
+// textRow converts a row of text-format values, charging their size to budget. NULLs are nil.
+func textRow(values [][]byte, budget *int) []*string {
+	row := make([]*string, len(values))
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		s := string(v)
+		*budget -= len(s)
+		row[i] = &s
+	}
+	return row
+}
+
+// truncate cuts s to at most max bytes on a rune boundary, reporting whether it was cut
+func truncate(s string, max int) (string, bool) {
+	if len(s) <= max {
+		return s, false
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut], true
+}
@@ -0,0 +1,55 @@
+//go:build integration
+
+package scheduledjobs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/test/dbhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunner_RunSQLDoesNotLeakSessionSettings_Integration runs jobs on a pool of a single
+// connection, so the next pool user would get the job's connection if it were returned
+func TestRunner_RunSQLDoesNotLeakSessionSettings_Integration(t *testing.T) {
+	testCtx := dbhelpers.NewDBTestContext(t)
+	defer testCtx.Close()
+
+	ctx := context.Background()
+	cfg := testCtx.Pool.Config()
+	cfg.MaxConns = 1
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	var loginRole, searchPath string
+	require.NoError(t, pool.QueryRow(ctx, "SELECT current_user, current_setting('search_path')").Scan(&loginRole, &searchPath))
+
+	r := NewRunner(NewStore(pool), nil, 0, 0)
+	sql := "SET search_path TO pg_catalog; SET statement_timeout = '1ms'; SELECT 1"
+	_, err = r.runSQL(ctx, Job{SQL: &sql})
+	require.NoError(t, err)
+
+	var role, path, timeout string
+	require.NoError(t, pool.QueryRow(ctx, "SELECT current_user, current_setting('search_path'), current_setting('statement_timeout')").Scan(&role, &path, &timeout))
+	assert.Equal(t, loginRole, role)
+	assert.Equal(t, searchPath, path)
+	assert.NotEqual(t, "1ms", timeout)
+
+	t.Run("role changes are rejected", func(t *testing.T) {
+		for _, sql := range []string{"RESET ROLE; SELECT 1", "COMMIT; SET ROLE postgres"} {
+			_, err := r.runSQL(ctx, Job{SQL: &sql})
+			assert.Error(t, err, sql)
+		}
+
+		sql := "DO $$ BEGIN EXECUTE 'RESET ROLE'; END $$"
+		_, err := r.runSQL(ctx, Job{SQL: &sql})
+		assert.ErrorContains(t, err, "changed its role")
+
+		require.NoError(t, pool.QueryRow(ctx, "SELECT current_user").Scan(&role))
+		assert.Equal(t, loginRole, role)
+	})
+}
@@ -0,0 +1,156 @@
+package scheduledjobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nimbleflux/fluxbase/internal/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeInvoker struct {
+	result *runtime.ExecutionResult
+	err    error
+
+	name, namespace, trigger, body string
+}
+
+func (f *fakeInvoker) Invoke(_ context.Context, name, namespace, triggerType, body string) (*runtime.ExecutionResult, error) {
+	f.name, f.namespace, f.trigger, f.body = name, namespace, triggerType, body
+	return f.result, f.err
+}
+
+func functionJob() Job {
+	name, ns := "report", "billing"
+	return Job{Name: "nightly", TargetType: TargetFunction, FunctionName: &name, FunctionNamespace: &ns, Payload: []byte(`{"day":1}`)}
+}
+
+func TestRunner_RunFunction(t *testing.T) {
+	t.Run("captures JSON response", func(t *testing.T) {
+		invoker := &fakeInvoker{result: &runtime.ExecutionResult{Success: true, Status: 200, Body: `{"sent":3}`, Logs: "done\n"}}
+		r := NewRunner(nil, invoker, 0, 0)
+
+		output, err := r.runFunction(context.Background(), functionJob())
+		require.NoError(t, err)
+		assert.Equal(t, "report", invoker.name)
+		assert.Equal(t, "billing", invoker.namespace)
+		assert.Equal(t, "schedule", invoker.trigger)
+		assert.Equal(t, `{"day":1}`, invoker.body)
+		assert.Equal(t, 200, output.Status)
+		assert.JSONEq(t, `{"sent":3}`, string(output.Body))
+		assert.Equal(t, "done\n", output.Logs)
+		assert.False(t, output.Truncated)
+	})
+
+	t.Run("error status fails the run", func(t *testing.T) {
+		invoker := &fakeInvoker{result: &runtime.ExecutionResult{Status: 502, Body: "upstream down"}}
+		r := NewRunner(nil, invoker, 0, 0)
+
+		output, err := r.runFunction(context.Background(), functionJob())
+		require.ErrorContains(t, err, "status 502")
+		assert.JSONEq(t, `"upstream down"`, string(output.Body))
+	})
+
+	t.Run("thrown error fails the run", func(t *testing.T) {
+		invoker := &fakeInvoker{result: &runtime.ExecutionResult{Status: 500, Error: "boom"}}
+		r := NewRunner(nil, invoker, 0, 0)
+
+		_, err := r.runFunction(context.Background(), functionJob())
+		require.EqualError(t, err, "boom")
+	})
+
+	t.Run("invoke failure has no output", func(t *testing.T) {
+		invoker := &fakeInvoker{err: errors.New("function not found")}
+		r := NewRunner(nil, invoker, 0, 0)
+
+		output, err := r.runFunction(context.Background(), functionJob())
+		require.Error(t, err)
+		assert.Nil(t, output)
+	})
+
+	t.Run("no functions available", func(t *testing.T) {
+		r := NewRunner(nil, nil, 0, 0)
+		_, err := r.runFunction(context.Background(), functionJob())
+		require.Error(t, err)
+	})
+}
+
+func TestRunner_FunctionOutputTruncated(t *testing.T) {
+	r := NewRunner(nil, nil, 0, 1024)
+
+	output := r.functionOutput(&runtime.ExecutionResult{
+		Status: 200,
+		Body:   `{"data":"` + strings.Repeat("x", 2000) + `"}`,
+		Logs:   strings.Repeat("l", 10),
+	})
+	assert.True(t, output.Truncated)
+
+	// A truncated JSON body is kept as a string
+	var body string
+	require.NoError(t, json.Unmarshal(output.Body, &body))
+	assert.Len(t, body, 1024)
+}
+
+func TestTruncate(t *testing.T) {
+	s, cut := truncate("hello", 10)
+	assert.Equal(t, "hello", s)
+	assert.False(t, cut)
+
+	s, cut = truncate("héllo", 2)
+	assert.Equal(t, "h", s, "doesn't split a multi-byte rune")
+	assert.True(t, cut)
+}
+
+func TestTextRow(t *testing.T) {
+	budget := 100
+	row := textRow([][]byte{[]byte("42"), nil, []byte("abc")}, &budget)
+
+	require.Len(t, row, 3)
+	assert.Equal(t, "42", *row[0])
+	assert.Nil(t, row[1])
+	assert.Equal(t, "abc", *row[2])
+	assert.Equal(t, 95, budget)
+}
+
+func TestValidateJobSQL(t *testing.T) {
+	allowed := []string{
+		"DELETE FROM logs WHERE created_at < now() - interval '30 days'",
+		"SET LOCAL statement_timeout = '5s'; ANALYZE logs; SELECT 1",
+		"DO $$ BEGIN PERFORM pg_sleep(0); END $$",
+		"DO $body$ BEGIN COMMIT; END $body$",
+		"SELECT 'COMMIT; RESET ROLE'; -- ROLLBACK\nSELECT \"end\" FROM t",
+		"/* BEGIN; */ SELECT 1",
+		"SAVEPOINT s; DELETE FROM t; ROLLBACK TO SAVEPOINT s",
+	}
+	for _, sql := range allowed {
+		assert.NoError(t, validateJobSQL(sql), sql)
+	}
+
+	rejected := []string{
+		"COMMIT; DELETE FROM auth.users",
+		"select 1; begin",
+		"START TRANSACTION",
+		"END",
+		"ROLLBACK",
+		"ABORT",
+		"PREPARE TRANSACTION 'x'",
+		"RESET ROLE; SELECT 1",
+		"SET ROLE postgres",
+		"set local role postgres",
+		"SET SESSION AUTHORIZATION postgres",
+		"RESET ALL",
+	}
+	for _, sql := range rejected {
+		assert.Error(t, validateJobSQL(sql), sql)
+	}
+}
+
+func TestJob_ValidateRejectsTransactionControl(t *testing.T) {
+	sql := "UPDATE t SET x = 1; COMMIT"
+	job := Job{Name: "cleanup", CronSchedule: "0 * * * *", TargetType: TargetSQL, SQL: &sql}
+	assert.ErrorContains(t, job.Validate(), "transaction control")
+}
@@ -0,0 +1,216 @@
+package scheduledjobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// checkInterval is how often the scheduler looks for due jobs
+const checkInterval = 15 * time.Second
+
+// cronParser accepts the same expressions as the job and function schedulers: 5 fields,
+// 6 fields with seconds and descriptors like @daily
+var cronParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// ParseSchedule validates a cron expression and evaluates it in the IANA time zone tz.
+// The zone is set on the job, so the expression itself must not carry a TZ prefix.
+func ParseSchedule(expr, tz string) (cron.Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, errors.New("cron_schedule is required")
+	}
+	if strings.HasPrefix(expr, "TZ=") || strings.HasPrefix(expr, "CRON_TZ=") {
+		return nil, errors.New("cron_schedule must not set a time zone, use timezone instead")
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", tz)
+	}
+	parsed, err := cronParser.Parse("CRON_TZ=" + tz + " " + expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron_schedule: %w", err)
+	}
+	return parsed, nil
+}
+
+// NextRun returns when j fires next, nil if its expression is invalid
+func NextRun(j Job, now time.Time) *time.Time {
+	parsed, err := ParseSchedule(j.CronSchedule, j.Timezone)
+	if err != nil {
+		return nil
+	}
+	next := parsed.Next(now)
+	return &next
+}
+
+// isDue reports whether j fired since it last ran or was last changed, so enabling or
+// rescheduling does not catch up on past firings. A job that missed several firings,
+// e.g. while no instance was leader, runs once.
+func isDue(j Job, parsed cron.Schedule, now time.Time) bool {
+	from := j.UpdatedAt
+	if j.LastRunAt != nil && j.LastRunAt.After(from) {
+		from = *j.LastRunAt
+	}
+	return !parsed.Next(from).After(now)
+}
+
+// Scheduler runs due jobs and prunes the run history. Jobs are read from the database on
+// every check, so jobs created on any instance are picked up. Due jobs run concurrently,
+// up to the configured limit, and a job still running when it fires again is skipped.
+// It must run on a single node (leader-elected).
+type Scheduler struct {
+	cfg    *config.ScheduledJobsConfig
+	store  *Store
+	runner *Runner
+	now    func() time.Time
+	sem    chan struct{}
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running bool
+	mu      sync.Mutex
+}
+
+// NewScheduler creates a scheduled jobs scheduler
+func NewScheduler(cfg *config.ScheduledJobsConfig, store *Store, runner *Runner) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	maxConcurrent := cfg.MaxConcurrent
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+
+	return &Scheduler{
+		cfg:    cfg,
+		store:  store,
+		runner: runner,
+		now:    time.Now,
+		sem:    make(chan struct{}, maxConcurrent),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start begins running jobs
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = true
+	if s.ctx.Err() != nil {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+	}
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.run()
+
+	log.Info().
+		Dur("run_timeout", s.cfg.RunTimeout).
+		Int("max_concurrent", cap(s.sem)).
+		Msg("Scheduled jobs scheduler started")
+}
+
+// Stop stops running jobs, cancelling the runs in progress
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	s.mu.Unlock()
+
+	s.cancel()
+	s.wg.Wait()
+
+	log.Info().Msg("Scheduled jobs scheduler stopped")
+}
+
+// run checks on start and then every check interval
+func (s *Scheduler) run() {
+	defer s.wg.Done()
+
+	s.check(s.ctx)
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.check(s.ctx)
+		}
+	}
+}
+
+// check starts the due jobs and prunes expired history
+func (s *Scheduler) check(ctx context.Context) {
+	jobs, err := s.store.enabledJobs(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load scheduled jobs")
+		return
+	}
+
+	for _, j := range jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		parsed, err := ParseSchedule(j.CronSchedule, j.Timezone)
+		if err != nil {
+			log.Warn().Err(err).Str("job", j.Name).Msg("Skipping scheduled job with invalid schedule")
+			continue
+		}
+		now := s.now()
+		if !isDue(j, parsed, now) {
+			continue
+		}
+
+		// Wait for a free slot, so a job isn't marked as run before it can start
+		select {
+		case s.sem <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+
+		// Mark the job first, so a failing run is not retried on every check
+		if err := s.store.markJobRun(ctx, j.ID, now); err != nil {
+			<-s.sem
+			log.Error().Err(err).Str("job", j.Name).Msg("Failed to mark scheduled job")
+			continue
+		}
+
+		s.wg.Add(1)
+		go func(j Job) {
+			defer s.wg.Done()
+			defer func() { <-s.sem }()
+
+			if _, err := s.runner.Run(ctx, RunRequest{Job: j, Trigger: TriggerScheduled}); err != nil {
+				log.Warn().Err(err).Str("job", j.Name).Msg("Skipped scheduled job")
+			}
+		}(j)
+	}
+
+	pruned, err := s.store.PruneRuns(ctx, s.now().AddDate(0, 0, -s.cfg.HistoryRetentionDays))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to prune scheduled job history")
+		return
+	}
+	if pruned > 0 {
+		log.Debug().Int64("runs", pruned).Msg("Pruned expired scheduled job runs")
+	}
+}
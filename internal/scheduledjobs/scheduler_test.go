@@ -0,0 +1,99 @@
+package scheduledjobs
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule(t *testing.T) {
+	for _, expr := range []string{"0 3 * * *", "0 0 3 * * *", "@daily", "*/5 * * * *"} {
+		_, err := ParseSchedule(expr, "Europe/Berlin")
+		assert.NoError(t, err, expr)
+	}
+
+	for _, expr := range []string{"", "every night", "61 * * * *", "CRON_TZ=Europe/Berlin 0 3 * * *", "TZ=UTC @daily"} {
+		_, err := ParseSchedule(expr, "UTC")
+		assert.Error(t, err, expr)
+	}
+
+	_, err := ParseSchedule("0 3 * * *", "Nowhere/City")
+	assert.ErrorContains(t, err, "timezone")
+}
+
+func TestIsDue(t *testing.T) {
+	parsed, err := ParseSchedule("0 3 * * *", "UTC")
+	require.NoError(t, err)
+
+	created := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	j := Job{CreatedAt: created, UpdatedAt: created}
+
+	assert.False(t, isDue(j, parsed, time.Date(2026, 3, 11, 2, 59, 0, 0, time.UTC)), "before the first firing")
+	assert.True(t, isDue(j, parsed, time.Date(2026, 3, 11, 3, 0, 0, 0, time.UTC)), "at the first firing")
+
+	lastRun := time.Date(2026, 3, 11, 3, 0, 5, 0, time.UTC)
+	j.LastRunAt = &lastRun
+	assert.False(t, isDue(j, parsed, time.Date(2026, 3, 11, 23, 0, 0, 0, time.UTC)), "already ran today")
+	assert.True(t, isDue(j, parsed, time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)), "missed firings run once")
+
+	j.UpdatedAt = time.Date(2026, 3, 14, 8, 0, 0, 0, time.UTC)
+	assert.False(t, isDue(j, parsed, time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)), "re-enabled jobs don't catch up")
+}
+
+func TestNextRun(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	next := NextRun(Job{CronSchedule: "0 3 * * *", Timezone: "UTC"}, now)
+	require.NotNil(t, next)
+	assert.Equal(t, time.Date(2026, 3, 11, 3, 0, 0, 0, time.UTC), next.UTC())
+
+	next = NextRun(Job{CronSchedule: "0 3 * * *", Timezone: "Asia/Tokyo"}, now)
+	require.NotNil(t, next)
+	assert.Equal(t, time.Date(2026, 3, 10, 18, 0, 0, 0, time.UTC), next.UTC())
+
+	assert.Nil(t, NextRun(Job{CronSchedule: "nope", Timezone: "UTC"}, now))
+}
+
+func TestJob_Validate(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+
+	t.Run("function job gets defaults", func(t *testing.T) {
+		j := Job{Name: " nightly-report ", CronSchedule: "@daily", TargetType: TargetFunction, FunctionName: strPtr("report")}
+		require.NoError(t, j.Validate())
+		assert.Equal(t, "nightly-report", j.Name)
+		assert.Equal(t, "UTC", j.Timezone)
+		assert.Equal(t, DefaultNamespace, *j.FunctionNamespace)
+		assert.JSONEq(t, `{}`, string(j.Payload))
+	})
+
+	t.Run("sql job drops function fields", func(t *testing.T) {
+		j := Job{Name: "cleanup", CronSchedule: "0 * * * *", Timezone: "Europe/Paris", TargetType: TargetSQL,
+			SQL: strPtr("DELETE FROM sessions WHERE expires_at < now()"), FunctionName: strPtr("ignored")}
+		require.NoError(t, j.Validate())
+		assert.Nil(t, j.FunctionName)
+	})
+
+	tests := []struct {
+		name string
+		job  Job
+		want string
+	}{
+		{"missing name", Job{CronSchedule: "@daily", TargetType: TargetSQL, SQL: strPtr("SELECT 1")}, "name"},
+		{"bad schedule", Job{Name: "x", CronSchedule: "daily", TargetType: TargetSQL, SQL: strPtr("SELECT 1")}, "cron_schedule"},
+		{"bad timezone", Job{Name: "x", CronSchedule: "@daily", Timezone: "Mars/Base", TargetType: TargetSQL, SQL: strPtr("SELECT 1")}, "timezone"},
+		{"unknown target", Job{Name: "x", CronSchedule: "@daily", TargetType: "webhook"}, "target_type"},
+		{"function without name", Job{Name: "x", CronSchedule: "@daily", TargetType: TargetFunction}, "function_name"},
+		{"invalid payload", Job{Name: "x", CronSchedule: "@daily", TargetType: TargetFunction, FunctionName: strPtr("f"), Payload: json.RawMessage(`{`)}, "payload"},
+		{"sql without statements", Job{Name: "x", CronSchedule: "@daily", TargetType: TargetSQL, SQL: strPtr("  ")}, "sql"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.job.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
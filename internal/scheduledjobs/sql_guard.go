@@ -0,0 +1,158 @@
+package scheduledjobs
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// validateJobSQL rejects snippets with statements that would escape the transaction and
+// role a SQL job runs in: transaction control (BEGIN, COMMIT, ROLLBACK, ...) and role
+// changes (SET ROLE, RESET ROLE, SET SESSION AUTHORIZATION, RESET ALL). Function and DO
+// bodies are not inspected; they cannot end the job's transaction, and a role they change
+// is caught before the job commits.
+func validateJobSQL(sql string) error {
+	for _, stmt := range splitStatements(sql) {
+		words := leadingWords(stmt, 3)
+		if len(words) == 0 {
+			continue
+		}
+
+		switch words[0] {
+		case "BEGIN", "START", "COMMIT", "END", "ABORT":
+			return fmt.Errorf("sql must not contain transaction control statements (%s)", words[0])
+		case "ROLLBACK":
+			if len(words) < 2 || words[1] != "TO" {
+				return fmt.Errorf("sql must not contain transaction control statements (%s)", words[0])
+			}
+		case "PREPARE":
+			if len(words) > 1 && words[1] == "TRANSACTION" {
+				return fmt.Errorf("sql must not contain transaction control statements (%s)", "PREPARE TRANSACTION")
+			}
+		case "SET", "RESET":
+			rest := words[1:]
+			if len(rest) > 0 && (rest[0] == "SESSION" || rest[0] == "LOCAL") {
+				rest = rest[1:]
+			}
+			if len(rest) > 0 && (rest[0] == "ROLE" || rest[0] == "AUTHORIZATION" || (words[0] == "RESET" && rest[0] == "ALL")) {
+				return fmt.Errorf("sql must not change the role it runs as (%s)", strings.Join(words, " "))
+			}
+		}
+	}
+	return nil
+}
+
+// splitStatements splits a snippet on the semicolons outside of string literals, quoted
+// identifiers, dollar-quoted bodies and comments. Comments are dropped.
+func splitStatements(sql string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+	)
+	for i := 0; i < len(sql); {
+		switch {
+		case sql[i] == ';':
+			statements = append(statements, current.String())
+			current.Reset()
+			i++
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end
+			}
+			current.WriteByte(' ')
+		case strings.HasPrefix(sql[i:], "/*"):
+			i = skipBlockComment(sql, i)
+			current.WriteByte(' ')
+		case sql[i] == '\'' || sql[i] == '"':
+			end := skipQuoted(sql, i, sql[i])
+			current.WriteString(sql[i:end])
+			i = end
+		case sql[i] == '$':
+			if tag, ok := dollarTag(sql[i:]); ok {
+				end := strings.Index(sql[i+len(tag):], tag)
+				if end < 0 {
+					end = len(sql)
+				} else {
+					end = i + len(tag) + end + len(tag)
+				}
+				current.WriteString(sql[i:end])
+				i = end
+				continue
+			}
+			current.WriteByte(sql[i])
+			i++
+		default:
+			current.WriteByte(sql[i])
+			i++
+		}
+	}
+	return append(statements, current.String())
+}
+
+// skipBlockComment returns the index after the (possibly nested) block comment at i
+func skipBlockComment(sql string, i int) int {
+	depth := 0
+	for i < len(sql) {
+		switch {
+		case strings.HasPrefix(sql[i:], "/*"):
+			depth++
+			i += 2
+		case strings.HasPrefix(sql[i:], "*/"):
+			depth--
+			i += 2
+			if depth == 0 {
+				return i
+			}
+		default:
+			i++
+		}
+	}
+	return i
+}
+
+// skipQuoted returns the index after the literal or identifier quoted with quote at i,
+// where a doubled quote is an escaped one
+func skipQuoted(sql string, i int, quote byte) int {
+	for i++; i < len(sql); i++ {
+		if sql[i] != quote {
+			continue
+		}
+		if i+1 < len(sql) && sql[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return i
+}
+
+// dollarTag returns the dollar-quote delimiter ($$ or $tag$) s starts with
+func dollarTag(s string) (string, bool) {
+	for i := 1; i < len(s); i++ {
+		c := rune(s[i])
+		if c == '$' {
+			return s[:i+1], true
+		}
+		if !(c == '_' || unicode.IsLetter(c) || (i > 1 && unicode.IsDigit(c))) {
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// leadingWords returns up to n upper-cased leading words of a statement
+func leadingWords(stmt string, n int) []string {
+	words := strings.FieldsFunc(stmt, func(r rune) bool {
+		return !(r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+	if len(words) > n {
+		words = words[:n]
+	}
+	for i, w := range words {
+		words[i] = strings.ToUpper(w)
+	}
+	return words
+}
@@ -0,0 +1,358 @@
+// Package scheduledjobs runs edge functions and SQL snippets on cron schedules, keeping
+// the history and captured output of every scheduled and manual run.
+package scheduledjobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/database"
+)
+
+// TargetType is what a job runs
+type TargetType string
+
+const (
+	TargetFunction TargetType = "function"
+	TargetSQL      TargetType = "sql"
+)
+
+// Valid reports whether t is a known target type
+func (t TargetType) Valid() bool {
+	return t == TargetFunction || t == TargetSQL
+}
+
+// How a run was started
+const (
+	TriggerManual    = "manual"
+	TriggerScheduled = "scheduled"
+)
+
+// Run statuses
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// DefaultNamespace is the function namespace used when a job doesn't name one
+const DefaultNamespace = "default"
+
+var (
+	// ErrJobNotFound is returned for an unknown job ID
+	ErrJobNotFound = errors.New("scheduled job not found")
+	// ErrJobExists is returned when another job already has the name
+	ErrJobExists = errors.New("a scheduled job with this name already exists")
+	// ErrRunInProgress is returned when the job is already running
+	ErrRunInProgress = errors.New("this job is already running")
+)
+
+// Job runs an edge function or a SQL snippet whenever its cron expression fires
+type Job struct {
+	ID           string  `json:"id"`
+	Name         string  `json:"name"`
+	Description  *string `json:"description,omitempty"`
+	CronSchedule string  `json:"cron_schedule"`
+	// Timezone is the IANA zone the cron expression is evaluated in
+	Timezone   string     `json:"timezone"`
+	TargetType TargetType `json:"target_type"`
+
+	// Function target: the function and the JSON body posted to it
+	FunctionName      *string         `json:"function_name,omitempty"`
+	FunctionNamespace *string         `json:"function_namespace,omitempty"`
+	Payload           json.RawMessage `json:"payload,omitempty"`
+
+	// SQL target: one or more statements run in a single transaction
+	SQL *string `json:"sql,omitempty"`
+
+	Enabled   bool       `json:"enabled"`
+	LastRunAt *time.Time `json:"last_run_at"`
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+	CreatedBy *string    `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Validate checks the name, schedule, time zone and target of a job, filling in the
+// default time zone, namespace and payload
+func (j *Job) Validate() error {
+	j.Name = strings.TrimSpace(j.Name)
+	if j.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(j.Name) > 100 {
+		return errors.New("name must be at most 100 characters")
+	}
+
+	if j.Timezone == "" {
+		j.Timezone = "UTC"
+	}
+	if _, err := ParseSchedule(j.CronSchedule, j.Timezone); err != nil {
+		return err
+	}
+
+	switch j.TargetType {
+	case TargetFunction:
+		if j.FunctionName == nil || strings.TrimSpace(*j.FunctionName) == "" {
+			return errors.New("function_name is required for function jobs")
+		}
+		if j.FunctionNamespace == nil || *j.FunctionNamespace == "" {
+			ns := DefaultNamespace
+			j.FunctionNamespace = &ns
+		}
+		if len(j.Payload) == 0 || string(j.Payload) == "null" {
+			j.Payload = json.RawMessage("{}")
+		}
+		if !json.Valid(j.Payload) {
+			return errors.New("payload must be valid JSON")
+		}
+		j.SQL = nil
+	case TargetSQL:
+		if j.SQL == nil || strings.TrimSpace(*j.SQL) == "" {
+			return errors.New("sql is required for sql jobs")
+		}
+		if err := validateJobSQL(*j.SQL); err != nil {
+			return err
+		}
+		j.FunctionName, j.FunctionNamespace, j.Payload = nil, nil, json.RawMessage("{}")
+	default:
+		return fmt.Errorf("target_type must be %q or %q", TargetFunction, TargetSQL)
+	}
+	return nil
+}
+
+// Run is a manual or scheduled run of a job
+type Run struct {
+	ID      string  `json:"id"`
+	JobID   string  `json:"job_id"`
+	JobName string  `json:"job_name"`
+	Trigger string  `json:"trigger"`
+	Status  string  `json:"status"`
+	Error   *string `json:"error,omitempty"`
+	// Output is the function response or the statement results
+	Output      json.RawMessage `json:"output,omitempty"`
+	StartedAt   time.Time       `json:"started_at"`
+	FinishedAt  *time.Time      `json:"finished_at"`
+	DurationMs  *int64          `json:"duration_ms"`
+	TriggeredBy *string         `json:"triggered_by,omitempty"`
+}
+
+// RunFilter selects the runs returned by ListRuns
+type RunFilter struct {
+	JobID  string
+	Status string
+	Limit  int
+}
+
+// Store persists scheduled jobs and their runs
+type Store struct {
+	db *pgxpool.Pool
+}
+
+// NewStore creates a scheduled jobs store
+func NewStore(db *pgxpool.Pool) *Store {
+	return &Store{db: db}
+}
+
+const jobColumns = `id::text, name, description, cron_schedule, timezone, target_type, function_name,
+	function_namespace, payload, sql_text, enabled, last_run_at, created_by::text, created_at, updated_at`
+
+func scanJob(row pgx.Row) (Job, error) {
+	var j Job
+	err := row.Scan(&j.ID, &j.Name, &j.Description, &j.CronSchedule, &j.Timezone, &j.TargetType, &j.FunctionName,
+		&j.FunctionNamespace, &j.Payload, &j.SQL, &j.Enabled, &j.LastRunAt, &j.CreatedBy, &j.CreatedAt, &j.UpdatedAt)
+	return j, err
+}
+
+// queryJobs returns the jobs selected by where, ordered by name
+func (s *Store) queryJobs(ctx context.Context, where string) ([]Job, error) {
+	rows, err := s.db.Query(ctx, `SELECT `+jobColumns+` FROM api.scheduled_jobs `+where+` ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []Job{}
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled job: %w", err)
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// ListJobs returns every job ordered by name
+func (s *Store) ListJobs(ctx context.Context) ([]Job, error) {
+	return s.queryJobs(ctx, "")
+}
+
+// enabledJobs returns the enabled jobs
+func (s *Store) enabledJobs(ctx context.Context) ([]Job, error) {
+	return s.queryJobs(ctx, "WHERE enabled")
+}
+
+// GetJob returns a job by ID
+func (s *Store) GetJob(ctx context.Context, id string) (*Job, error) {
+	j, err := scanJob(s.db.QueryRow(ctx, `SELECT `+jobColumns+` FROM api.scheduled_jobs WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduled job: %w", err)
+	}
+	return &j, nil
+}
+
+// CreateJob stores a new job. The job must already be validated.
+func (s *Store) CreateJob(ctx context.Context, j Job, createdBy *uuid.UUID) (*Job, error) {
+	created, err := scanJob(s.db.QueryRow(ctx, `
+		INSERT INTO api.scheduled_jobs
+			(name, description, cron_schedule, timezone, target_type, function_name, function_namespace,
+			 payload, sql_text, enabled, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING `+jobColumns,
+		j.Name, j.Description, j.CronSchedule, j.Timezone, j.TargetType, j.FunctionName, j.FunctionNamespace,
+		j.Payload, j.SQL, j.Enabled, createdBy))
+	if database.IsUniqueViolation(err) {
+		return nil, ErrJobExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scheduled job: %w", err)
+	}
+	return &created, nil
+}
+
+// UpdateJob replaces the definition of a job. The job must already be validated.
+func (s *Store) UpdateJob(ctx context.Context, id string, j Job) (*Job, error) {
+	updated, err := scanJob(s.db.QueryRow(ctx, `
+		UPDATE api.scheduled_jobs
+		SET name = $2, description = $3, cron_schedule = $4, timezone = $5, target_type = $6,
+		    function_name = $7, function_namespace = $8, payload = $9, sql_text = $10, enabled = $11,
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+jobColumns,
+		id, j.Name, j.Description, j.CronSchedule, j.Timezone, j.TargetType,
+		j.FunctionName, j.FunctionNamespace, j.Payload, j.SQL, j.Enabled))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrJobNotFound
+	}
+	if database.IsUniqueViolation(err) {
+		return nil, ErrJobExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update scheduled job: %w", err)
+	}
+	return &updated, nil
+}
+
+// DeleteJob deletes a job and its run history
+func (s *Store) DeleteJob(ctx context.Context, id string) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM api.scheduled_jobs WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete scheduled job: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
+
+// markJobRun records when a job last fired
+func (s *Store) markJobRun(ctx context.Context, id string, at time.Time) error {
+	_, err := s.db.Exec(ctx, `UPDATE api.scheduled_jobs SET last_run_at = $2 WHERE id = $1`, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to update scheduled job: %w", err)
+	}
+	return nil
+}
+
+// startRun records a running run. Runs left running for longer than staleAfter, by an
+// instance that stopped mid-run, are marked failed first so they don't block the job.
+func (s *Store) startRun(ctx context.Context, run *Run, staleAfter time.Duration, triggeredBy *uuid.UUID) error {
+	if _, err := s.db.Exec(ctx, `
+		UPDATE api.scheduled_job_runs
+		SET status = 'failed', error = 'interrupted', finished_at = NOW()
+		WHERE status = 'running' AND job_id = $1 AND started_at < NOW() - make_interval(secs => $2)
+	`, run.JobID, staleAfter.Seconds()); err != nil {
+		return fmt.Errorf("failed to expire interrupted job runs: %w", err)
+	}
+
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO api.scheduled_job_runs (job_id, trigger, triggered_by)
+		VALUES ($1, $2, $3)
+		RETURNING id::text, started_at
+	`, run.JobID, run.Trigger, triggeredBy).Scan(&run.ID, &run.StartedAt)
+	if database.IsUniqueViolation(err) {
+		return ErrRunInProgress
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record job run: %w", err)
+	}
+	run.Status = StatusRunning
+	if triggeredBy != nil {
+		id := triggeredBy.String()
+		run.TriggeredBy = &id
+	}
+	return nil
+}
+
+// finishRun records the outcome and output of a run
+func (s *Store) finishRun(ctx context.Context, run *Run) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE api.scheduled_job_runs
+		SET status = $2, error = $3, output = $4, finished_at = $5, duration_ms = $6
+		WHERE id = $1
+	`, run.ID, run.Status, run.Error, run.Output, run.FinishedAt, run.DurationMs)
+	if err != nil {
+		return fmt.Errorf("failed to record job run result: %w", err)
+	}
+	return nil
+}
+
+// ListRuns returns the most recent runs, newest first
+func (s *Store) ListRuns(ctx context.Context, filter RunFilter) ([]Run, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT r.id::text, r.job_id::text, j.name, r.trigger, r.status, r.error, r.output,
+		       r.started_at, r.finished_at, r.duration_ms, r.triggered_by::text
+		FROM api.scheduled_job_runs r
+		JOIN api.scheduled_jobs j ON j.id = r.job_id
+		WHERE ($1 = '' OR r.job_id::text = $1) AND ($2 = '' OR r.status = $2)
+		ORDER BY r.started_at DESC
+		LIMIT $3
+	`, filter.JobID, filter.Status, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []Run{}
+	for rows.Next() {
+		var r Run
+		if err := rows.Scan(&r.ID, &r.JobID, &r.JobName, &r.Trigger, &r.Status, &r.Error, &r.Output,
+			&r.StartedAt, &r.FinishedAt, &r.DurationMs, &r.TriggeredBy); err != nil {
+			return nil, fmt.Errorf("failed to scan job run: %w", err)
+		}
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
+
+// PruneRuns deletes the finished runs that started before before
+func (s *Store) PruneRuns(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := s.db.Exec(ctx, `
+		DELETE FROM api.scheduled_job_runs WHERE status <> 'running' AND started_at < $1
+	`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune job runs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
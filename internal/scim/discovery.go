@@ -0,0 +1,123 @@
+package scim
+
+// Attribute describes an attribute in a schema definition (RFC 7643 section 7)
+type Attribute struct {
+	Name          string      `json:"name"`
+	Type          string      `json:"type"`
+	MultiValued   bool        `json:"multiValued"`
+	Required      bool        `json:"required"`
+	CaseExact     bool        `json:"caseExact"`
+	Mutability    string      `json:"mutability"`
+	Returned      string      `json:"returned"`
+	Uniqueness    string      `json:"uniqueness"`
+	SubAttributes []Attribute `json:"subAttributes,omitempty"`
+}
+
+func attribute(name, typ string, opts ...func(*Attribute)) Attribute {
+	attr := Attribute{Name: name, Type: typ, Mutability: "readWrite", Returned: "default", Uniqueness: "none"}
+	for _, opt := range opts {
+		opt(&attr)
+	}
+	return attr
+}
+
+func required(a *Attribute)     { a.Required = true }
+func multiValued(a *Attribute)  { a.MultiValued = true }
+func caseExact(a *Attribute)    { a.CaseExact = true }
+func readOnly(a *Attribute)     { a.Mutability = "readOnly" }
+func uniqueServer(a *Attribute) { a.Uniqueness = "server" }
+
+func subAttributes(attrs ...Attribute) func(*Attribute) {
+	return func(a *Attribute) { a.SubAttributes = attrs }
+}
+
+func multiValueAttributes() func(*Attribute) {
+	return subAttributes(
+		attribute("value", "string"),
+		attribute("display", "string"),
+		attribute("type", "string"),
+		attribute("primary", "boolean"),
+	)
+}
+
+// ServiceProviderConfig returns the capabilities of the SCIM API
+func ServiceProviderConfig(location string) map[string]any {
+	return map[string]any{
+		"schemas":        []string{SchemaServiceProviderConfig},
+		"patch":          map[string]any{"supported": true},
+		"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]any{"supported": true, "maxResults": MaxCount},
+		"changePassword": map[string]any{"supported": false},
+		"sort":           map[string]any{"supported": false},
+		"etag":           map[string]any{"supported": false},
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "SCIM provisioning token",
+			"description": "Bearer token created in the Fluxbase dashboard",
+			"primary":     true,
+		}},
+		"meta": map[string]any{"resourceType": "ServiceProviderConfig", "location": location},
+	}
+}
+
+// ResourceTypes returns the User and Group resource types, with locations under baseURL
+func ResourceTypes(baseURL string) []map[string]any {
+	return []map[string]any{
+		{
+			"schemas":     []string{SchemaResourceType},
+			"id":          "User",
+			"name":        "User",
+			"endpoint":    "/Users",
+			"description": "User account",
+			"schema":      SchemaUser,
+			"meta":        map[string]any{"resourceType": "ResourceType", "location": baseURL + "/ResourceTypes/User"},
+		},
+		{
+			"schemas":     []string{SchemaResourceType},
+			"id":          "Group",
+			"name":        "Group",
+			"endpoint":    "/Groups",
+			"description": "Group of users",
+			"schema":      SchemaGroup,
+			"meta":        map[string]any{"resourceType": "ResourceType", "location": baseURL + "/ResourceTypes/Group"},
+		},
+	}
+}
+
+// Schemas returns the definitions of the supported User and Group attributes
+func Schemas(baseURL string) []map[string]any {
+	return []map[string]any{
+		{
+			"schemas":     []string{SchemaSchema},
+			"id":          SchemaUser,
+			"name":        "User",
+			"description": "User account",
+			"attributes": []Attribute{
+				attribute("userName", "string", required, uniqueServer),
+				attribute("externalId", "string", caseExact),
+				attribute("displayName", "string"),
+				attribute("name", "complex", subAttributes(
+					attribute("formatted", "string"),
+					attribute("givenName", "string"),
+					attribute("familyName", "string"),
+				)),
+				attribute("emails", "complex", multiValued, multiValueAttributes()),
+				attribute("active", "boolean"),
+				attribute("groups", "complex", multiValued, readOnly, multiValueAttributes()),
+			},
+			"meta": map[string]any{"resourceType": "Schema", "location": baseURL + "/Schemas/" + SchemaUser},
+		},
+		{
+			"schemas":     []string{SchemaSchema},
+			"id":          SchemaGroup,
+			"name":        "Group",
+			"description": "Group of users",
+			"attributes": []Attribute{
+				attribute("displayName", "string", required, uniqueServer),
+				attribute("externalId", "string", caseExact),
+				attribute("members", "complex", multiValued, multiValueAttributes()),
+			},
+			"meta": map[string]any{"resourceType": "Schema", "location": baseURL + "/Schemas/" + SchemaGroup},
+		},
+	}
+}
@@ -0,0 +1,551 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Expr is a parsed filter expression (RFC 7644 section 3.4.2.2)
+type Expr interface {
+	expr()
+}
+
+// LogicalExpr combines two expressions with "and" or "or"
+type LogicalExpr struct {
+	Op          string
+	Left, Right Expr
+}
+
+// NotExpr negates an expression
+type NotExpr struct {
+	X Expr
+}
+
+// CompareExpr compares an attribute with a value. Op is "pr" for a presence test, in which case
+// Value is unused. Value is a string, bool, float64 or nil.
+type CompareExpr struct {
+	Attr  string
+	Op    string
+	Value any
+}
+
+// ValuePathExpr filters the entries of a multi-valued attribute, as in emails[type eq "work"]
+type ValuePathExpr struct {
+	Attr   string
+	Filter Expr
+}
+
+func (*LogicalExpr) expr()   {}
+func (*NotExpr) expr()       {}
+func (*CompareExpr) expr()   {}
+func (*ValuePathExpr) expr() {}
+
+var compareOps = map[string]bool{
+	"eq": true, "ne": true, "co": true, "sw": true, "ew": true,
+	"gt": true, "lt": true, "ge": true, "le": true,
+}
+
+// ParseFilter parses a filter. Attribute names are returned lower-cased, with any schema URN
+// prefix removed, and sub-attributes joined with a dot as in "name.givenname".
+func ParseFilter(filter string) (Expr, error) {
+	p, err := newParser(filter)
+	if err != nil {
+		return nil, err
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, invalidFilter("unexpected %q", p.peek().text)
+	}
+	return expr, nil
+}
+
+// Path is a parsed PATCH path: an attribute, optionally filtered, optionally followed
+// by a sub-attribute as in emails[type eq "work"].value
+type Path struct {
+	Attr    string
+	Filter  Expr
+	SubAttr string
+}
+
+// ParsePath parses the path of a PATCH operation. Attribute names are lower-cased.
+func ParsePath(path string) (*Path, error) {
+	p, err := newParser(path)
+	if err != nil {
+		return nil, NewError(http.StatusBadRequest, ErrorTypeInvalidPath, err.(*Error).Detail)
+	}
+	tok := p.next()
+	if tok.kind != tokenWord {
+		return nil, NewError(http.StatusBadRequest, ErrorTypeInvalidPath, "invalid path "+path)
+	}
+	result := &Path{Attr: normalizeAttr(tok.text)}
+	if p.peek().kind == tokenLBracket {
+		p.next()
+		if result.Filter, err = p.parseOr(); err != nil {
+			return nil, NewError(http.StatusBadRequest, ErrorTypeInvalidPath, err.(*Error).Detail)
+		}
+		if p.next().kind != tokenRBracket {
+			return nil, NewError(http.StatusBadRequest, ErrorTypeInvalidPath, "missing ] in path "+path)
+		}
+		if p.peek().kind == tokenWord && strings.HasPrefix(p.peek().text, ".") {
+			result.SubAttr = strings.ToLower(strings.TrimPrefix(p.next().text, "."))
+		}
+	} else if attr, sub, ok := strings.Cut(result.Attr, "."); ok {
+		result.Attr, result.SubAttr = attr, sub
+	}
+	if !p.done() {
+		return nil, NewError(http.StatusBadRequest, ErrorTypeInvalidPath, "invalid path "+path)
+	}
+	return result, nil
+}
+
+func normalizeAttr(attr string) string {
+	return strings.ToLower(attributeName(attr))
+}
+
+func invalidFilter(format string, args ...any) *Error {
+	return NewError(http.StatusBadRequest, ErrorTypeInvalidFilter, fmt.Sprintf(format, args...))
+}
+
+// ============================================================================
+// PARSER
+// ============================================================================
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func newParser(input string) (*parser, error) {
+	var tokens []token
+	for i := 0; i < len(input); {
+		switch c := input[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokenLParen, "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokenRParen, ")"})
+			i++
+		case c == '[':
+			tokens = append(tokens, token{tokenLBracket, "["})
+			i++
+		case c == ']':
+			tokens = append(tokens, token{tokenRBracket, "]"})
+			i++
+		case c == '"':
+			end := i + 1
+			for ; end < len(input) && input[end] != '"'; end++ {
+				if input[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(input) {
+				return nil, invalidFilter("unterminated string")
+			}
+			var s string
+			if err := json.Unmarshal([]byte(input[i:end+1]), &s); err != nil {
+				return nil, invalidFilter("invalid string %s", input[i:end+1])
+			}
+			tokens = append(tokens, token{tokenString, s})
+			i = end + 1
+		default:
+			end := i
+			for end < len(input) && !strings.ContainsRune(" \t\n\r()[]\"", rune(input[end])) {
+				end++
+			}
+			tokens = append(tokens, token{tokenWord, input[i:end]})
+			i = end
+		}
+	}
+	return &parser{tokens: tokens}, nil
+}
+
+func (p *parser) peek() token {
+	if p.pos >= len(p.tokens) {
+		return token{kind: tokenEOF}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.peek()
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) done() bool {
+	return p.peek().kind == tokenEOF
+}
+
+func (p *parser) keyword(word string) bool {
+	tok := p.peek()
+	return tok.kind == tokenWord && strings.EqualFold(tok.text, word)
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &LogicalExpr{Op: "or", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &LogicalExpr{Op: "and", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if p.keyword("not") {
+		p.next()
+		if p.peek().kind != tokenLParen {
+			return nil, invalidFilter("expected ( after not")
+		}
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &NotExpr{X: x}, nil
+	}
+
+	if p.peek().kind == tokenLParen {
+		p.next()
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokenRParen {
+			return nil, invalidFilter("missing )")
+		}
+		return x, nil
+	}
+
+	tok := p.next()
+	if tok.kind != tokenWord {
+		return nil, invalidFilter("expected attribute, got %q", tok.text)
+	}
+	attr := normalizeAttr(tok.text)
+
+	if p.peek().kind == tokenLBracket {
+		p.next()
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokenRBracket {
+			return nil, invalidFilter("missing ]")
+		}
+		return &ValuePathExpr{Attr: attr, Filter: filter}, nil
+	}
+
+	opTok := p.next()
+	op := strings.ToLower(opTok.text)
+	if opTok.kind != tokenWord || (op != "pr" && !compareOps[op]) {
+		return nil, invalidFilter("invalid operator %q", opTok.text)
+	}
+	if op == "pr" {
+		return &CompareExpr{Attr: attr, Op: op}, nil
+	}
+
+	valTok := p.next()
+	switch valTok.kind {
+	case tokenString:
+		return &CompareExpr{Attr: attr, Op: op, Value: valTok.text}, nil
+	case tokenWord:
+		var value any
+		if err := json.Unmarshal([]byte(valTok.text), &value); err != nil {
+			return nil, invalidFilter("invalid value %q", valTok.text)
+		}
+		if _, ok := value.(map[string]any); ok {
+			return nil, invalidFilter("invalid value %q", valTok.text)
+		}
+		if _, ok := value.([]any); ok {
+			return nil, invalidFilter("invalid value %q", valTok.text)
+		}
+		return &CompareExpr{Attr: attr, Op: op, Value: value}, nil
+	default:
+		return nil, invalidFilter("missing value for %s", attr)
+	}
+}
+
+// ============================================================================
+// SQL
+// ============================================================================
+
+// ColumnType is the SCIM data type of a filterable attribute
+type ColumnType int
+
+const (
+	TypeString ColumnType = iota
+	TypeBoolean
+	TypeDateTime
+)
+
+// Column maps a filterable attribute onto SQL
+type Column struct {
+	// Expr is the SQL expression of the attribute
+	Expr string
+	Type ColumnType
+	// CaseExact attributes are compared case-sensitively, all others case-insensitively
+	CaseExact bool
+	// Exists, when set, is a subquery selecting the rows of a multi-valued attribute; the
+	// comparison is then wrapped in EXISTS (Exists AND ...)
+	Exists string
+}
+
+// ToSQL compiles a filter into a SQL condition over columns, which is keyed by lower-case
+// attribute name. Values are appended to args as bind parameters.
+func ToSQL(expr Expr, columns map[string]Column, args *[]any) (string, error) {
+	return toSQL(expr, "", columns, args)
+}
+
+func toSQL(expr Expr, prefix string, columns map[string]Column, args *[]any) (string, error) {
+	switch e := expr.(type) {
+	case *LogicalExpr:
+		left, err := toSQL(e.Left, prefix, columns, args)
+		if err != nil {
+			return "", err
+		}
+		right, err := toSQL(e.Right, prefix, columns, args)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(%s %s %s)", left, strings.ToUpper(e.Op), right), nil
+	case *NotExpr:
+		x, err := toSQL(e.X, prefix, columns, args)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(NOT COALESCE(%s, FALSE))", x), nil
+	case *ValuePathExpr:
+		return toSQL(e.Filter, prefix+e.Attr+".", columns, args)
+	case *CompareExpr:
+		attr := prefix + e.Attr
+		col, ok := columns[attr]
+		if !ok {
+			// A multi-valued attribute compares on its value sub-attribute
+			col, ok = columns[attr+".value"]
+		}
+		if !ok {
+			return "", invalidFilter("filtering on %s is not supported", attr)
+		}
+		cond, err := compareSQL(col, e, args)
+		if err != nil {
+			return "", err
+		}
+		if col.Exists != "" {
+			return fmt.Sprintf("EXISTS (%s AND %s)", col.Exists, cond), nil
+		}
+		return cond, nil
+	}
+	return "", invalidFilter("invalid filter")
+}
+
+func compareSQL(col Column, e *CompareExpr, args *[]any) (string, error) {
+	bind := func(v any) string {
+		*args = append(*args, v)
+		return fmt.Sprintf("$%d", len(*args))
+	}
+
+	if e.Op == "pr" {
+		if col.Type == TypeString {
+			return fmt.Sprintf("(%s IS NOT NULL AND %s <> '')", col.Expr, col.Expr), nil
+		}
+		return fmt.Sprintf("(%s IS NOT NULL)", col.Expr), nil
+	}
+
+	if e.Value == nil {
+		switch e.Op {
+		case "eq":
+			return fmt.Sprintf("(%s IS NULL)", col.Expr), nil
+		case "ne":
+			return fmt.Sprintf("(%s IS NOT NULL)", col.Expr), nil
+		}
+		return "", invalidFilter("null can only be compared with eq or ne")
+	}
+
+	switch col.Type {
+	case TypeBoolean:
+		value, ok := e.Value.(bool)
+		if !ok {
+			return "", invalidFilter("%s must be compared with a boolean", e.Attr)
+		}
+		switch e.Op {
+		case "eq":
+			return fmt.Sprintf("(%s = %s)", col.Expr, bind(value)), nil
+		case "ne":
+			return fmt.Sprintf("(%s IS DISTINCT FROM %s)", col.Expr, bind(value)), nil
+		}
+		return "", invalidFilter("%s can only be compared with eq or ne", e.Attr)
+
+	case TypeDateTime:
+		s, ok := e.Value.(string)
+		if !ok {
+			return "", invalidFilter("%s must be compared with a dateTime", e.Attr)
+		}
+		value, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return "", invalidFilter("invalid dateTime %q", s)
+		}
+		op, ok := orderingOps[e.Op]
+		if !ok {
+			return "", invalidFilter("%s can't be compared with %s", e.Attr, e.Op)
+		}
+		return fmt.Sprintf("(%s %s %s)", col.Expr, op, bind(value)), nil
+	}
+
+	value, ok := e.Value.(string)
+	if !ok {
+		return "", invalidFilter("%s must be compared with a string", e.Attr)
+	}
+	expr := col.Expr
+	if !col.CaseExact {
+		expr = "lower(" + col.Expr + ")"
+		value = strings.ToLower(value)
+	}
+	switch e.Op {
+	case "eq":
+		return fmt.Sprintf("(%s = %s)", expr, bind(value)), nil
+	case "ne":
+		return fmt.Sprintf("(%s IS DISTINCT FROM %s)", expr, bind(value)), nil
+	case "co":
+		return fmt.Sprintf("(%s LIKE %s)", expr, bind("%"+escapeLike(value)+"%")), nil
+	case "sw":
+		return fmt.Sprintf("(%s LIKE %s)", expr, bind(escapeLike(value)+"%")), nil
+	case "ew":
+		return fmt.Sprintf("(%s LIKE %s)", expr, bind("%"+escapeLike(value))), nil
+	}
+	return fmt.Sprintf("(%s %s %s)", expr, orderingOps[e.Op], bind(value)), nil
+}
+
+var orderingOps = map[string]string{
+	"eq": "=", "ne": "<>", "gt": ">", "lt": "<", "ge": ">=", "le": "<=",
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// ============================================================================
+// MATCHING
+// ============================================================================
+
+// Matches evaluates a filter against the sub-attributes of one entry of a multi-valued
+// attribute, such as an email or member. Strings compare case-insensitively.
+func Matches(expr Expr, entry MultiValue) bool {
+	switch e := expr.(type) {
+	case *LogicalExpr:
+		if e.Op == "and" {
+			return Matches(e.Left, entry) && Matches(e.Right, entry)
+		}
+		return Matches(e.Left, entry) || Matches(e.Right, entry)
+	case *NotExpr:
+		return !Matches(e.X, entry)
+	case *CompareExpr:
+		var actual any
+		switch e.Attr {
+		case "value":
+			actual = entry.Value
+		case "display":
+			actual = entry.Display
+		case "type":
+			actual = entry.Type
+		case "primary":
+			actual = entry.Primary
+		default:
+			return false
+		}
+		return matchValue(actual, e.Op, e.Value)
+	}
+	return false
+}
+
+func matchValue(actual any, op string, expected any) bool {
+	if b, ok := actual.(bool); ok {
+		switch op {
+		case "pr":
+			return true
+		case "eq":
+			return expected == b
+		case "ne":
+			return expected != b
+		}
+		return false
+	}
+
+	a := strings.ToLower(actual.(string))
+	if op == "pr" {
+		return a != ""
+	}
+	s, ok := expected.(string)
+	if !ok {
+		return op == "ne"
+	}
+	s = strings.ToLower(s)
+	switch op {
+	case "eq":
+		return a == s
+	case "ne":
+		return a != s
+	case "co":
+		return strings.Contains(a, s)
+	case "sw":
+		return strings.HasPrefix(a, s)
+	case "ew":
+		return strings.HasSuffix(a, s)
+	case "gt":
+		return a > s
+	case "lt":
+		return a < s
+	case "ge":
+		return a >= s
+	case "le":
+		return a <= s
+	}
+	return false
+}
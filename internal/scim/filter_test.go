@@ -0,0 +1,280 @@
+package scim
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		want   Expr
+	}{
+		{
+			name:   "equality",
+			filter: `userName eq "bjensen@example.com"`,
+			want:   &CompareExpr{Attr: "username", Op: "eq", Value: "bjensen@example.com"},
+		},
+		{
+			name:   "case-insensitive operator and schema prefix",
+			filter: `urn:ietf:params:scim:schemas:core:2.0:User:userName EQ "bjensen"`,
+			want:   &CompareExpr{Attr: "username", Op: "eq", Value: "bjensen"},
+		},
+		{
+			name:   "presence",
+			filter: `externalId pr`,
+			want:   &CompareExpr{Attr: "externalid", Op: "pr"},
+		},
+		{
+			name:   "boolean and null values",
+			filter: `active eq false or displayName eq null`,
+			want: &LogicalExpr{
+				Op:    "or",
+				Left:  &CompareExpr{Attr: "active", Op: "eq", Value: false},
+				Right: &CompareExpr{Attr: "displayname", Op: "eq", Value: nil},
+			},
+		},
+		{
+			name:   "and binds tighter than or",
+			filter: `a eq "1" or b eq "2" and c eq "3"`,
+			want: &LogicalExpr{
+				Op:   "or",
+				Left: &CompareExpr{Attr: "a", Op: "eq", Value: "1"},
+				Right: &LogicalExpr{
+					Op:    "and",
+					Left:  &CompareExpr{Attr: "b", Op: "eq", Value: "2"},
+					Right: &CompareExpr{Attr: "c", Op: "eq", Value: "3"},
+				},
+			},
+		},
+		{
+			name:   "not with parentheses",
+			filter: `not (name.givenName sw "J")`,
+			want:   &NotExpr{X: &CompareExpr{Attr: "name.givenname", Op: "sw", Value: "J"}},
+		},
+		{
+			name:   "value path",
+			filter: `emails[type eq "work" and value co "@example.com"]`,
+			want: &ValuePathExpr{Attr: "emails", Filter: &LogicalExpr{
+				Op:    "and",
+				Left:  &CompareExpr{Attr: "type", Op: "eq", Value: "work"},
+				Right: &CompareExpr{Attr: "value", Op: "co", Value: "@example.com"},
+			}},
+		},
+		{
+			name:   "escaped string",
+			filter: `displayName eq "say \"hi\""`,
+			want:   &CompareExpr{Attr: "displayname", Op: "eq", Value: `say "hi"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseFilter(tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, expr)
+		})
+	}
+}
+
+func TestParseFilter_Invalid(t *testing.T) {
+	for _, filter := range []string{
+		`userName`,
+		`userName xx "a"`,
+		`userName eq`,
+		`userName eq "a" and`,
+		`(userName eq "a"`,
+		`emails[type eq "work"`,
+		`userName eq "unterminated`,
+		`userName eq {"a":1}`,
+		`not userName eq "a"`,
+	} {
+		t.Run(filter, func(t *testing.T) {
+			_, err := ParseFilter(filter)
+			var scimErr *Error
+			require.ErrorAs(t, err, &scimErr)
+			assert.Equal(t, 400, scimErr.Status)
+			assert.Equal(t, ErrorTypeInvalidFilter, scimErr.ScimType)
+		})
+	}
+}
+
+func TestToSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "case-insensitive equality",
+			filter:   `userName eq "BJensen"`,
+			wantSQL:  `(lower(COALESCE(su.user_name, u.email)) = $1)`,
+			wantArgs: []any{"bjensen"},
+		},
+		{
+			name:     "case-exact equality",
+			filter:   `externalId eq "ABC"`,
+			wantSQL:  `(su.external_id = $1)`,
+			wantArgs: []any{"ABC"},
+		},
+		{
+			name:     "contains escapes wildcards",
+			filter:   `displayName co "50%_off"`,
+			wantSQL:  `(lower(su.display_name) LIKE $1)`,
+			wantArgs: []any{`%50\%\_off%`},
+		},
+		{
+			name:     "multi-valued attribute compares its value",
+			filter:   `emails co "example.com"`,
+			wantSQL:  `(lower(u.email) LIKE $1)`,
+			wantArgs: []any{"%example.com%"},
+		},
+		{
+			name:     "value path",
+			filter:   `emails[value ew "@example.com"]`,
+			wantSQL:  `(lower(u.email) LIKE $1)`,
+			wantArgs: []any{"%@example.com"},
+		},
+		{
+			name:     "boolean",
+			filter:   `active eq true`,
+			wantSQL:  `(NOT (COALESCE(u.is_locked, false) AND u.locked_until IS NULL) = $1)`,
+			wantArgs: []any{true},
+		},
+		{
+			name:    "presence of string",
+			filter:  `externalId pr`,
+			wantSQL: `(su.external_id IS NOT NULL AND su.external_id <> '')`,
+		},
+		{
+			name:    "null",
+			filter:  `displayName eq null`,
+			wantSQL: `(su.display_name IS NULL)`,
+		},
+		{
+			name:     "logical and not",
+			filter:   `not (active eq false) and userName sw "j"`,
+			wantSQL:  `((NOT COALESCE((NOT (COALESCE(u.is_locked, false) AND u.locked_until IS NULL) = $1), FALSE)) AND (lower(COALESCE(su.user_name, u.email)) LIKE $2))`,
+			wantArgs: []any{false, "j%"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseFilter(tt.filter)
+			require.NoError(t, err)
+
+			var args []any
+			sql, err := ToSQL(expr, userFilterColumns, &args)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSQL, sql)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func TestToSQL_DateTime(t *testing.T) {
+	expr, err := ParseFilter(`meta.lastModified gt "2024-05-13T04:42:34Z"`)
+	require.NoError(t, err)
+
+	var args []any
+	sql, err := ToSQL(expr, userFilterColumns, &args)
+	require.NoError(t, err)
+	assert.Equal(t, `(GREATEST(u.created_at, u.updated_at, su.updated_at) > $1)`, sql)
+	assert.Equal(t, []any{time.Date(2024, 5, 13, 4, 42, 34, 0, time.UTC)}, args)
+}
+
+func TestToSQL_GroupMembers(t *testing.T) {
+	expr, err := ParseFilter(`displayName eq "Engineering" and members[value eq "2819c223-7f76-453a-919d-413861904646"]`)
+	require.NoError(t, err)
+
+	var args []any
+	sql, err := ToSQL(expr, groupFilterColumns, &args)
+	require.NoError(t, err)
+	assert.Equal(t, `((lower(g.name) = $1) AND EXISTS (SELECT 1 FROM auth.group_members gm WHERE gm.group_id = g.id AND (gm.user_id::text = $2)))`, sql)
+	assert.Equal(t, []any{"engineering", "2819c223-7f76-453a-919d-413861904646"}, args)
+}
+
+func TestToSQL_Invalid(t *testing.T) {
+	for _, filter := range []string{
+		`title eq "Manager"`,
+		`active eq "yes"`,
+		`active co true`,
+		`userName eq 42`,
+		`meta.created gt "yesterday"`,
+		`userName gt null`,
+	} {
+		t.Run(filter, func(t *testing.T) {
+			expr, err := ParseFilter(filter)
+			require.NoError(t, err)
+
+			var args []any
+			_, err = ToSQL(expr, userFilterColumns, &args)
+			var scimErr *Error
+			require.ErrorAs(t, err, &scimErr)
+			assert.Equal(t, ErrorTypeInvalidFilter, scimErr.ScimType)
+		})
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want *Path
+	}{
+		{"active", &Path{Attr: "active"}},
+		{"name.givenName", &Path{Attr: "name", SubAttr: "givenname"}},
+		{"urn:ietf:params:scim:schemas:core:2.0:User:name.familyName", &Path{Attr: "name", SubAttr: "familyname"}},
+		{`emails[type eq "work"].value`, &Path{
+			Attr:    "emails",
+			Filter:  &CompareExpr{Attr: "type", Op: "eq", Value: "work"},
+			SubAttr: "value",
+		}},
+		{`members[value eq "abc"]`, &Path{
+			Attr:   "members",
+			Filter: &CompareExpr{Attr: "value", Op: "eq", Value: "abc"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, err := ParsePath(tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, path)
+		})
+	}
+
+	_, err := ParsePath(`emails[type eq "work"`)
+	var scimErr *Error
+	require.ErrorAs(t, err, &scimErr)
+	assert.Equal(t, ErrorTypeInvalidPath, scimErr.ScimType)
+}
+
+func TestMatches(t *testing.T) {
+	email := MultiValue{Value: "Jane@Example.com", Type: "work", Primary: true}
+
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{`type eq "work"`, true},
+		{`type eq "home"`, false},
+		{`value eq "jane@example.com"`, true},
+		{`value ew "@example.com" and primary eq true`, true},
+		{`not (primary eq true)`, false},
+		{`type eq "home" or display pr`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			expr, err := ParseFilter(tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, Matches(expr, email))
+		})
+	}
+}
@@ -0,0 +1,439 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Patch operations
+const (
+	OpAdd     = "add"
+	OpReplace = "replace"
+	OpRemove  = "remove"
+)
+
+// ApplyUserPatch applies the operations of a PATCH request to user. Attributes that are not
+// stored, such as phone numbers or enterprise extension attributes, are ignored so that
+// identity providers sending their full attribute mapping don't fail.
+func ApplyUserPatch(user *User, ops []PatchOperation) error {
+	for _, op := range ops {
+		name, err := patchOp(op)
+		if err != nil {
+			return err
+		}
+		if op.Path == "" {
+			values, err := patchObject(op)
+			if err != nil {
+				return err
+			}
+			for attr, value := range values {
+				path, err := ParsePath(attr)
+				if err != nil {
+					return err
+				}
+				if err := patchUserAttr(user, name, path, value); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		path, err := ParsePath(op.Path)
+		if err != nil {
+			return err
+		}
+		if err := patchUserAttr(user, name, path, op.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func patchUserAttr(user *User, op string, path *Path, value json.RawMessage) error {
+	if op != OpRemove && len(value) == 0 {
+		return NewError(http.StatusBadRequest, ErrorTypeInvalidValue, "missing value for "+path.Attr)
+	}
+
+	switch path.Attr {
+	case "active":
+		if op == OpRemove {
+			return NewError(http.StatusBadRequest, ErrorTypeMutability, "active can't be removed")
+		}
+		active, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		user.Active = &active
+
+	case "username":
+		if op == OpRemove {
+			return NewError(http.StatusBadRequest, ErrorTypeMutability, "userName is required")
+		}
+		s, err := parseString(value, "userName")
+		if err != nil {
+			return err
+		}
+		if s == "" {
+			return NewError(http.StatusBadRequest, ErrorTypeInvalidValue, "userName is required")
+		}
+		user.UserName = s
+
+	case "externalid":
+		return patchString(&user.ExternalID, op, value, "externalId")
+
+	case "displayname":
+		return patchString(&user.DisplayName, op, value, "displayName")
+
+	case "name":
+		if user.Name == nil {
+			user.Name = &Name{}
+		}
+		switch path.SubAttr {
+		case "":
+			if op == OpRemove {
+				user.Name = nil
+				return nil
+			}
+			var name Name
+			if err := json.Unmarshal(value, &name); err != nil {
+				return NewError(http.StatusBadRequest, ErrorTypeInvalidValue, "name must be an object")
+			}
+			if op == OpReplace {
+				*user.Name = name
+				return nil
+			}
+			if name.GivenName != "" {
+				user.Name.GivenName = name.GivenName
+			}
+			if name.FamilyName != "" {
+				user.Name.FamilyName = name.FamilyName
+			}
+			if name.Formatted != "" {
+				user.Name.Formatted = name.Formatted
+			}
+		case "givenname":
+			return patchString(&user.Name.GivenName, op, value, "name.givenName")
+		case "familyname":
+			return patchString(&user.Name.FamilyName, op, value, "name.familyName")
+		case "formatted":
+			return patchString(&user.Name.Formatted, op, value, "name.formatted")
+		}
+
+	case "emails":
+		return patchEmails(user, op, path, value)
+
+	case "groups":
+		return NewError(http.StatusBadRequest, ErrorTypeMutability, "groups is read-only, change the group members instead")
+	}
+	return nil
+}
+
+func patchEmails(user *User, op string, path *Path, value json.RawMessage) error {
+	if path.Filter == nil && path.SubAttr == "" {
+		if op == OpRemove {
+			user.Emails = nil
+			return nil
+		}
+		var emails []MultiValue
+		if err := json.Unmarshal(value, &emails); err != nil {
+			return NewError(http.StatusBadRequest, ErrorTypeInvalidValue, "emails must be an array")
+		}
+		if op == OpReplace {
+			user.Emails = emails
+			return nil
+		}
+		for _, email := range emails {
+			if email.Primary {
+				for i := range user.Emails {
+					user.Emails[i].Primary = false
+				}
+			}
+			user.Emails = append(user.Emails, email)
+		}
+		return nil
+	}
+
+	// A filtered path such as emails[type eq "work"].value, or emails.value which targets
+	// every email
+	var matched []int
+	for i, email := range user.Emails {
+		if path.Filter == nil || Matches(path.Filter, email) {
+			matched = append(matched, i)
+		}
+	}
+
+	if op == OpRemove {
+		if path.SubAttr != "" && path.SubAttr != "value" {
+			return nil
+		}
+		kept := user.Emails[:0]
+		for i, email := range user.Emails {
+			if !containsIndex(matched, i) {
+				kept = append(kept, email)
+			}
+		}
+		user.Emails = kept
+		return nil
+	}
+
+	var entry MultiValue
+	switch path.SubAttr {
+	case "":
+		if err := json.Unmarshal(value, &entry); err != nil {
+			return NewError(http.StatusBadRequest, ErrorTypeInvalidValue, "email must be an object")
+		}
+	case "value":
+		s, err := parseString(value, "emails.value")
+		if err != nil {
+			return err
+		}
+		entry.Value = s
+	case "primary":
+		primary, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		entry.Primary = primary
+	case "type":
+		s, err := parseString(value, "emails.type")
+		if err != nil {
+			return err
+		}
+		entry.Type = s
+	default:
+		return nil
+	}
+
+	if len(matched) == 0 {
+		if entry.Type == "" {
+			entry.Type = filterType(path.Filter)
+		}
+		entry.Primary = entry.Primary || len(user.Emails) == 0
+		user.Emails = append(user.Emails, entry)
+		return nil
+	}
+	for _, i := range matched {
+		switch path.SubAttr {
+		case "":
+			user.Emails[i] = entry
+		case "value":
+			user.Emails[i].Value = entry.Value
+		case "primary":
+			user.Emails[i].Primary = entry.Primary
+		case "type":
+			user.Emails[i].Type = entry.Type
+		}
+	}
+	return nil
+}
+
+// filterType returns the type an email filter such as [type eq "work"] selects
+func filterType(filter Expr) string {
+	if e, ok := filter.(*CompareExpr); ok && e.Attr == "type" && e.Op == "eq" {
+		if s, ok := e.Value.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// ApplyGroupPatch applies the operations of a PATCH request to group. Unknown attributes
+// are ignored.
+func ApplyGroupPatch(group *Group, ops []PatchOperation) error {
+	for _, op := range ops {
+		name, err := patchOp(op)
+		if err != nil {
+			return err
+		}
+		if op.Path == "" {
+			values, err := patchObject(op)
+			if err != nil {
+				return err
+			}
+			for attr, value := range values {
+				path, err := ParsePath(attr)
+				if err != nil {
+					return err
+				}
+				if err := patchGroupAttr(group, name, path, value); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		path, err := ParsePath(op.Path)
+		if err != nil {
+			return err
+		}
+		if err := patchGroupAttr(group, name, path, op.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func patchGroupAttr(group *Group, op string, path *Path, value json.RawMessage) error {
+	if op != OpRemove && len(value) == 0 {
+		return NewError(http.StatusBadRequest, ErrorTypeInvalidValue, "missing value for "+path.Attr)
+	}
+
+	switch path.Attr {
+	case "displayname":
+		if op == OpRemove {
+			return NewError(http.StatusBadRequest, ErrorTypeMutability, "displayName is required")
+		}
+		s, err := parseString(value, "displayName")
+		if err != nil {
+			return err
+		}
+		if s == "" {
+			return NewError(http.StatusBadRequest, ErrorTypeInvalidValue, "displayName is required")
+		}
+		group.DisplayName = s
+
+	case "externalid":
+		return patchString(&group.ExternalID, op, value, "externalId")
+
+	case "members":
+		return patchMembers(group, op, path, value)
+	}
+	return nil
+}
+
+func patchMembers(group *Group, op string, path *Path, value json.RawMessage) error {
+	if path.Filter != nil {
+		if op != OpRemove {
+			return NewError(http.StatusBadRequest, ErrorTypeInvalidPath, "members can only be added or replaced as a whole")
+		}
+		kept := group.Members[:0]
+		for _, member := range group.Members {
+			if !Matches(path.Filter, member) {
+				kept = append(kept, member)
+			}
+		}
+		group.Members = kept
+		return nil
+	}
+
+	var members []MultiValue
+	if len(value) > 0 && string(value) != "null" {
+		if err := json.Unmarshal(value, &members); err != nil {
+			// Some providers send a single member as an object
+			var member MultiValue
+			if err := json.Unmarshal(value, &member); err != nil {
+				return NewError(http.StatusBadRequest, ErrorTypeInvalidValue, "members must be an array")
+			}
+			members = []MultiValue{member}
+		}
+	}
+
+	switch op {
+	case OpReplace:
+		group.Members = members
+	case OpAdd:
+		for _, member := range members {
+			if !hasMember(group.Members, member.Value) {
+				group.Members = append(group.Members, member)
+			}
+		}
+	case OpRemove:
+		if len(members) == 0 {
+			group.Members = nil
+			return nil
+		}
+		kept := group.Members[:0]
+		for _, member := range group.Members {
+			if !hasMember(members, member.Value) {
+				kept = append(kept, member)
+			}
+		}
+		group.Members = kept
+	}
+	return nil
+}
+
+func hasMember(members []MultiValue, value string) bool {
+	for _, member := range members {
+		if strings.EqualFold(member.Value, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsIndex(indexes []int, i int) bool {
+	for _, index := range indexes {
+		if index == i {
+			return true
+		}
+	}
+	return false
+}
+
+// patchOp validates an operation and returns its lower-cased name. Entra ID sends
+// capitalized names such as "Replace".
+func patchOp(op PatchOperation) (string, error) {
+	name := strings.ToLower(op.Op)
+	switch name {
+	case OpAdd, OpReplace, OpRemove:
+	default:
+		return "", NewError(http.StatusBadRequest, ErrorTypeInvalidSyntax, fmt.Sprintf("invalid op %q", op.Op))
+	}
+	if name == OpRemove && op.Path == "" {
+		return "", NewError(http.StatusBadRequest, ErrorTypeNoTarget, "remove requires a path")
+	}
+	return name, nil
+}
+
+// patchObject returns the attributes of an operation without a path, whose value is an object
+func patchObject(op PatchOperation) (map[string]json.RawMessage, error) {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(op.Value, &values); err != nil {
+		return nil, NewError(http.StatusBadRequest, ErrorTypeInvalidValue, "value must be an object when no path is given")
+	}
+	return values, nil
+}
+
+func patchString(target *string, op string, value json.RawMessage, attr string) error {
+	if op == OpRemove {
+		*target = ""
+		return nil
+	}
+	s, err := parseString(value, attr)
+	if err != nil {
+		return err
+	}
+	*target = s
+	return nil
+}
+
+func parseString(value json.RawMessage, attr string) (string, error) {
+	var s *string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return "", NewError(http.StatusBadRequest, ErrorTypeInvalidValue, attr+" must be a string")
+	}
+	if s == nil {
+		return "", nil
+	}
+	return strings.TrimSpace(*s), nil
+}
+
+// parseBool parses a boolean, also accepting the strings "True" and "False" that Entra ID
+// sends for active
+func parseBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		if b, err := strconv.ParseBool(strings.ToLower(s)); err == nil {
+			return b, nil
+		}
+	}
+	return false, NewError(http.StatusBadRequest, ErrorTypeInvalidValue, "active must be a boolean")
+}
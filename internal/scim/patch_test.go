@@ -0,0 +1,198 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func patchOps(t *testing.T, body string) []PatchOperation {
+	t.Helper()
+	var req PatchRequest
+	require.NoError(t, json.Unmarshal([]byte(body), &req))
+	return req.Operations
+}
+
+func testUser() *User {
+	active := true
+	return &User{
+		Schemas:  []string{SchemaUser},
+		ID:       "2819c223-7f76-453a-919d-413861904646",
+		UserName: "jane@example.com",
+		Name:     &Name{GivenName: "Jane", FamilyName: "Doe"},
+		Emails:   []MultiValue{{Value: "jane@example.com", Type: "work", Primary: true}},
+		Active:   &active,
+	}
+}
+
+func TestApplyUserPatch_Deactivate(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"okta", `{"Operations":[{"op":"replace","value":{"active":false}}]}`},
+		{"entra id", `{"Operations":[{"op":"Replace","path":"active","value":"False"}]}`},
+		{"boolean path", `{"Operations":[{"op":"replace","path":"active","value":false}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := testUser()
+			require.NoError(t, ApplyUserPatch(user, patchOps(t, tt.body)))
+			assert.False(t, user.IsActive())
+		})
+	}
+}
+
+func TestApplyUserPatch_Attributes(t *testing.T) {
+	user := testUser()
+	err := ApplyUserPatch(user, patchOps(t, `{"Operations":[
+		{"op":"Replace","path":"userName","value":"jdoe@example.com"},
+		{"op":"Add","path":"externalId","value":"00u1abcd"},
+		{"op":"Replace","path":"name.givenName","value":"Janet"},
+		{"op":"Replace","path":"emails[type eq \"work\"].value","value":"jdoe@example.com"},
+		{"op":"Add","path":"displayName","value":"Janet Doe"},
+		{"op":"Add","path":"title","value":"Engineer"},
+		{"op":"Add","path":"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department","value":"R&D"}
+	]}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "jdoe@example.com", user.UserName)
+	assert.Equal(t, "00u1abcd", user.ExternalID)
+	assert.Equal(t, "Janet", user.Name.GivenName)
+	assert.Equal(t, "Doe", user.Name.FamilyName)
+	assert.Equal(t, "Janet Doe", user.DisplayName)
+	assert.Equal(t, "jdoe@example.com", user.Email())
+}
+
+func TestApplyUserPatch_NoPathObject(t *testing.T) {
+	user := testUser()
+	err := ApplyUserPatch(user, patchOps(t, `{"Operations":[
+		{"op":"replace","value":{"name.familyName":"Smith","externalId":"ext-1"}}
+	]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "Smith", user.Name.FamilyName)
+	assert.Equal(t, "ext-1", user.ExternalID)
+}
+
+func TestApplyUserPatch_Emails(t *testing.T) {
+	user := testUser()
+	user.Emails = nil
+	require.NoError(t, ApplyUserPatch(user, patchOps(t, `{"Operations":[
+		{"op":"add","path":"emails[type eq \"work\"].value","value":"new@example.com"}
+	]}`)))
+	assert.Equal(t, []MultiValue{{Value: "new@example.com", Type: "work", Primary: true}}, user.Emails)
+
+	require.NoError(t, ApplyUserPatch(user, patchOps(t, `{"Operations":[
+		{"op":"replace","path":"emails","value":[{"value":"other@example.com","primary":true}]}
+	]}`)))
+	assert.Equal(t, "other@example.com", user.Email())
+
+	require.NoError(t, ApplyUserPatch(user, patchOps(t, `{"Operations":[{"op":"remove","path":"emails"}]}`)))
+	assert.Empty(t, user.Emails)
+	assert.Equal(t, user.UserName, user.Email())
+}
+
+func TestApplyUserPatch_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		scimType string
+	}{
+		{"unknown op", `{"Operations":[{"op":"move","path":"active","value":true}]}`, ErrorTypeInvalidSyntax},
+		{"remove without path", `{"Operations":[{"op":"remove"}]}`, ErrorTypeNoTarget},
+		{"no path with non-object", `{"Operations":[{"op":"replace","value":true}]}`, ErrorTypeInvalidValue},
+		{"invalid active", `{"Operations":[{"op":"replace","path":"active","value":"maybe"}]}`, ErrorTypeInvalidValue},
+		{"remove userName", `{"Operations":[{"op":"remove","path":"userName"}]}`, ErrorTypeMutability},
+		{"read-only groups", `{"Operations":[{"op":"add","path":"groups","value":[{"value":"g1"}]}]}`, ErrorTypeMutability},
+		{"missing value", `{"Operations":[{"op":"replace","path":"displayName"}]}`, ErrorTypeInvalidValue},
+		{"invalid path", `{"Operations":[{"op":"replace","path":"emails[type eq","value":"x"}]}`, ErrorTypeInvalidPath},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ApplyUserPatch(testUser(), patchOps(t, tt.body))
+			var scimErr *Error
+			require.ErrorAs(t, err, &scimErr)
+			assert.Equal(t, 400, scimErr.Status)
+			assert.Equal(t, tt.scimType, scimErr.ScimType)
+		})
+	}
+}
+
+func TestApplyGroupPatch_Members(t *testing.T) {
+	group := &Group{
+		DisplayName: "Engineering",
+		Members:     []MultiValue{{Value: "u1"}, {Value: "u2"}},
+	}
+
+	require.NoError(t, ApplyGroupPatch(group, patchOps(t, `{"Operations":[
+		{"op":"Add","path":"members","value":[{"value":"u2"},{"value":"u3"}]}
+	]}`)))
+	assert.Equal(t, []string{"u1", "u2", "u3"}, group.MemberIDs())
+
+	require.NoError(t, ApplyGroupPatch(group, patchOps(t, `{"Operations":[
+		{"op":"Remove","path":"members[value eq \"u1\"]"}
+	]}`)))
+	assert.Equal(t, []string{"u2", "u3"}, group.MemberIDs())
+
+	require.NoError(t, ApplyGroupPatch(group, patchOps(t, `{"Operations":[
+		{"op":"remove","path":"members","value":[{"value":"u3"}]}
+	]}`)))
+	assert.Equal(t, []string{"u2"}, group.MemberIDs())
+
+	require.NoError(t, ApplyGroupPatch(group, patchOps(t, `{"Operations":[
+		{"op":"replace","value":{"displayName":"Platform","members":[{"value":"u4"}]}}
+	]}`)))
+	assert.Equal(t, "Platform", group.DisplayName)
+	assert.Equal(t, []string{"u4"}, group.MemberIDs())
+
+	require.NoError(t, ApplyGroupPatch(group, patchOps(t, `{"Operations":[{"op":"remove","path":"members"}]}`)))
+	assert.Empty(t, group.MemberIDs())
+}
+
+func TestApplyGroupPatch_Errors(t *testing.T) {
+	group := &Group{DisplayName: "Engineering"}
+
+	err := ApplyGroupPatch(group, patchOps(t, `{"Operations":[{"op":"replace","path":"displayName","value":""}]}`))
+	var scimErr *Error
+	require.ErrorAs(t, err, &scimErr)
+	assert.Equal(t, ErrorTypeInvalidValue, scimErr.ScimType)
+
+	err = ApplyGroupPatch(group, patchOps(t, `{"Operations":[{"op":"add","path":"members[value eq \"u1\"]","value":[]}]}`))
+	require.ErrorAs(t, err, &scimErr)
+	assert.Equal(t, ErrorTypeInvalidPath, scimErr.ScimType)
+}
+
+func TestError_MarshalJSON(t *testing.T) {
+	data, err := json.Marshal(NewError(409, ErrorTypeUniqueness, "userName is taken"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:Error"],
+		"status": "409",
+		"scimType": "uniqueness",
+		"detail": "userName is taken"
+	}`, string(data))
+}
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		startIndex, count string
+		wantStart         int
+		wantCount         int
+	}{
+		{"", "", 1, DefaultCount},
+		{"0", "10", 1, 10},
+		{"21", "20", 21, 20},
+		{"-5", "-1", 1, 0},
+		{"x", "5000", 1, MaxCount},
+	}
+
+	for _, tt := range tests {
+		start, count := ParsePagination(tt.startIndex, tt.count)
+		assert.Equal(t, tt.wantStart, start, "startIndex %q", tt.startIndex)
+		assert.Equal(t, tt.wantCount, count, "count %q", tt.count)
+	}
+}
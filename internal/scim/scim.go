@@ -0,0 +1,225 @@
+// Package scim implements SCIM 2.0 (RFC 7643/7644) provisioning of users and groups,
+// mapped onto auth.users and auth.groups, so identity providers such as Okta and Entra ID
+// can create, update and deactivate accounts.
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema URNs
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaEnterpriseUser        = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	SchemaSchema                = "urn:ietf:params:scim:schemas:core:2.0:Schema"
+)
+
+// ContentType is the media type of SCIM requests and responses
+const ContentType = "application/scim+json"
+
+// Page size limits of list requests
+const (
+	DefaultCount = 100
+	MaxCount     = 1000
+)
+
+// Error types from RFC 7644 section 3.12
+const (
+	ErrorTypeInvalidFilter = "invalidFilter"
+	ErrorTypeUniqueness    = "uniqueness"
+	ErrorTypeMutability    = "mutability"
+	ErrorTypeInvalidSyntax = "invalidSyntax"
+	ErrorTypeInvalidPath   = "invalidPath"
+	ErrorTypeNoTarget      = "noTarget"
+	ErrorTypeInvalidValue  = "invalidValue"
+)
+
+// Error is a SCIM error response. Store and patch functions return it for errors the
+// identity provider should see; other errors are internal.
+type Error struct {
+	Status   int
+	ScimType string
+	Detail   string
+}
+
+// NewError creates a SCIM error
+func NewError(status int, scimType, detail string) *Error {
+	return &Error{Status: status, ScimType: scimType, Detail: detail}
+}
+
+// ErrNotFound returns the error for a resource that does not exist
+func ErrNotFound(resourceType, id string) *Error {
+	return NewError(http.StatusNotFound, "", fmt.Sprintf("%s %s not found", resourceType, id))
+}
+
+func (e *Error) Error() string {
+	if e.ScimType != "" {
+		return fmt.Sprintf("scim %d %s: %s", e.Status, e.ScimType, e.Detail)
+	}
+	return fmt.Sprintf("scim %d: %s", e.Status, e.Detail)
+}
+
+// MarshalJSON encodes the error as a SCIM error message, with the status as a string
+func (e *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Schemas  []string `json:"schemas"`
+		Status   string   `json:"status"`
+		ScimType string   `json:"scimType,omitempty"`
+		Detail   string   `json:"detail,omitempty"`
+	}{[]string{SchemaError}, strconv.Itoa(e.Status), e.ScimType, e.Detail})
+}
+
+// Meta holds the resource metadata
+type Meta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location,omitempty"`
+}
+
+// Name is the name of a user
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// MultiValue is an entry of a multi-valued attribute such as emails or members
+type MultiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// User is a SCIM user resource
+type User struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	UserName    string       `json:"userName"`
+	Name        *Name        `json:"name,omitempty"`
+	DisplayName string       `json:"displayName,omitempty"`
+	Emails      []MultiValue `json:"emails,omitempty"`
+	Active      *bool        `json:"active,omitempty"`
+	Groups      []MultiValue `json:"groups,omitempty"`
+	Meta        *Meta        `json:"meta,omitempty"`
+}
+
+// Email returns the address a user signs in with: the primary email, the first email,
+// or the userName when no emails are given
+func (u *User) Email() string {
+	for _, email := range u.Emails {
+		if email.Primary && email.Value != "" {
+			return strings.TrimSpace(email.Value)
+		}
+	}
+	for _, email := range u.Emails {
+		if email.Value != "" {
+			return strings.TrimSpace(email.Value)
+		}
+	}
+	return strings.TrimSpace(u.UserName)
+}
+
+// IsActive reports whether the user is active. Users are active unless set otherwise.
+func (u *User) IsActive() bool {
+	return u.Active == nil || *u.Active
+}
+
+// Group is a SCIM group resource
+type Group struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []MultiValue `json:"members,omitempty"`
+	Meta        *Meta        `json:"meta,omitempty"`
+}
+
+// MemberIDs returns the distinct user IDs of the group members
+func (g *Group) MemberIDs() []string {
+	seen := make(map[string]bool, len(g.Members))
+	ids := make([]string, 0, len(g.Members))
+	for _, member := range g.Members {
+		if member.Value != "" && !seen[member.Value] {
+			seen[member.Value] = true
+			ids = append(ids, member.Value)
+		}
+	}
+	return ids
+}
+
+// ListResponse is the response of a list or filter request
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []any    `json:"Resources"`
+}
+
+// NewListResponse creates a list response for one page of resources
+func NewListResponse[T any](resources []T, total, startIndex int) *ListResponse {
+	items := make([]any, len(resources))
+	for i, resource := range resources {
+		items[i] = resource
+	}
+	return &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(items),
+		Resources:    items,
+	}
+}
+
+// PatchRequest is the body of a PATCH request
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation is one operation of a PATCH request
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// ParsePagination returns the 1-based start index and page size of a list request from its
+// startIndex and count query parameters. Out-of-range values are clamped as RFC 7644 requires.
+func ParsePagination(startIndex, count string) (int, int) {
+	start := 1
+	if n, err := strconv.Atoi(startIndex); err == nil && n > 1 {
+		start = n
+	}
+	size := DefaultCount
+	if n, err := strconv.Atoi(count); err == nil {
+		size = max(0, min(n, MaxCount))
+	}
+	return start, size
+}
+
+// attributeName strips a schema URN prefix from an attribute path, so that
+// "urn:ietf:params:scim:schemas:core:2.0:User:userName" becomes "userName"
+func attributeName(path string) string {
+	for _, schema := range []string{SchemaUser, SchemaGroup, SchemaEnterpriseUser} {
+		if len(path) > len(schema) && strings.EqualFold(path[:len(schema)], schema) && path[len(schema)] == ':' {
+			return path[len(schema)+1:]
+		}
+	}
+	return path
+}
@@ -0,0 +1,686 @@
+package scim
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/database"
+)
+
+// TokenPrefix is the prefix of provisioning tokens
+const TokenPrefix = "scim_"
+
+var (
+	// ErrTokenNotFound is returned when revoking a provisioning token that does not exist
+	ErrTokenNotFound = errors.New("SCIM token not found")
+	// ErrInvalidToken is returned for unknown, expired or revoked provisioning tokens
+	ErrInvalidToken = errors.New("invalid SCIM token")
+)
+
+// Token is a provisioning token. The token itself is only returned on creation.
+type Token struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"token_prefix"`
+	CreatedBy  *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// TokenWithSecret is a newly created token together with its plaintext
+type TokenWithSecret struct {
+	Token
+	Secret string `json:"token"`
+}
+
+// Store maps SCIM users and groups onto auth.users and auth.groups
+type Store struct {
+	db *database.Connection
+}
+
+// NewStore creates a SCIM store
+func NewStore(db *database.Connection) *Store {
+	return &Store{db: db}
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ============================================================================
+// TOKENS
+// ============================================================================
+
+const tokenColumns = `id, name, token_prefix, created_by, created_at, last_used_at, expires_at, revoked_at`
+
+func scanToken(row pgx.Row) (*Token, error) {
+	var token Token
+	err := row.Scan(&token.ID, &token.Name, &token.Prefix, &token.CreatedBy, &token.CreatedAt,
+		&token.LastUsedAt, &token.ExpiresAt, &token.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// CreateToken creates a provisioning token
+func (s *Store) CreateToken(ctx context.Context, name string, expiresAt *time.Time, createdBy *uuid.UUID) (*TokenWithSecret, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate SCIM token: %w", err)
+	}
+	plaintext := TokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	token, err := scanToken(s.db.QueryRow(ctx, `
+		INSERT INTO auth.scim_tokens (name, token_hash, token_prefix, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+tokenColumns,
+		name, hashToken(plaintext), plaintext[:len(TokenPrefix)+8], createdBy, expiresAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SCIM token: %w", err)
+	}
+	return &TokenWithSecret{Token: *token, Secret: plaintext}, nil
+}
+
+// ListTokens returns all provisioning tokens, newest first
+func (s *Store) ListTokens(ctx context.Context) ([]*Token, error) {
+	rows, err := s.db.Query(ctx, `SELECT `+tokenColumns+` FROM auth.scim_tokens ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SCIM tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []*Token{}
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan SCIM token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+// RevokeToken revokes a provisioning token
+func (s *Store) RevokeToken(ctx context.Context, id uuid.UUID) error {
+	tag, err := s.db.Exec(ctx, `
+		UPDATE auth.scim_tokens SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke SCIM token: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+// ValidateToken checks a provisioning token and records its use
+func (s *Store) ValidateToken(ctx context.Context, plaintext string) (*Token, error) {
+	if !strings.HasPrefix(plaintext, TokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+	token, err := scanToken(s.db.QueryRow(ctx, `
+		UPDATE auth.scim_tokens SET last_used_at = NOW()
+		WHERE token_hash = $1
+		  AND revoked_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > NOW())
+		RETURNING `+tokenColumns,
+		hashToken(plaintext)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate SCIM token: %w", err)
+	}
+	return token, nil
+}
+
+// ============================================================================
+// USERS
+// ============================================================================
+
+// ListQuery selects a page of resources
+type ListQuery struct {
+	Filter     Expr
+	StartIndex int
+	Count      int
+	// ExcludeMembers leaves out group members, which identity providers request
+	// with excludedAttributes=members when they only need to find a group
+	ExcludeMembers bool
+}
+
+const userFrom = `
+	FROM auth.users u
+	LEFT JOIN auth.scim_users su ON su.user_id = u.id`
+
+const userColumns = `
+	u.id, COALESCE(su.user_name, u.email), su.external_id, su.display_name, su.given_name, su.family_name,
+	u.email, NOT (COALESCE(u.is_locked, false) AND u.locked_until IS NULL),
+	COALESCE((
+		SELECT json_agg(json_build_object('value', g.id, 'display', g.name) ORDER BY g.name)
+		FROM auth.group_members gm JOIN auth.groups g ON g.id = gm.group_id
+		WHERE gm.user_id = u.id
+	), '[]'),
+	u.created_at, GREATEST(u.created_at, u.updated_at, su.updated_at)`
+
+// userFilterColumns maps the filterable user attributes onto SQL. Users have a single
+// email, which is their primary work email.
+var userFilterColumns = map[string]Column{
+	"id":                {Expr: "u.id::text", CaseExact: true},
+	"username":          {Expr: "COALESCE(su.user_name, u.email)"},
+	"externalid":        {Expr: "su.external_id", CaseExact: true},
+	"displayname":       {Expr: "su.display_name"},
+	"name.givenname":    {Expr: "su.given_name"},
+	"name.familyname":   {Expr: "su.family_name"},
+	"emails.value":      {Expr: "u.email"},
+	"emails.type":       {Expr: "'work'"},
+	"emails.primary":    {Expr: "TRUE", Type: TypeBoolean},
+	"active":            {Expr: "NOT (COALESCE(u.is_locked, false) AND u.locked_until IS NULL)", Type: TypeBoolean},
+	"meta.created":      {Expr: "u.created_at", Type: TypeDateTime},
+	"meta.lastmodified": {Expr: "GREATEST(u.created_at, u.updated_at, su.updated_at)", Type: TypeDateTime},
+}
+
+func scanUser(row pgx.Row) (*User, error) {
+	var (
+		user                                           User
+		externalID, displayName, givenName, familyName *string
+		email                                          string
+		active                                         bool
+		groups                                         []byte
+		created, lastModified                          time.Time
+	)
+	err := row.Scan(&user.ID, &user.UserName, &externalID, &displayName, &givenName, &familyName,
+		&email, &active, &groups, &created, &lastModified)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Schemas = []string{SchemaUser}
+	user.ExternalID = deref(externalID)
+	user.DisplayName = deref(displayName)
+	if givenName != nil || familyName != nil {
+		user.Name = &Name{GivenName: deref(givenName), FamilyName: deref(familyName)}
+		user.Name.Formatted = strings.TrimSpace(user.Name.GivenName + " " + user.Name.FamilyName)
+	}
+	user.Emails = []MultiValue{{Value: email, Type: "work", Primary: true}}
+	user.Active = &active
+	if err := json.Unmarshal(groups, &user.Groups); err != nil {
+		return nil, fmt.Errorf("failed to decode groups: %w", err)
+	}
+	user.Meta = &Meta{ResourceType: "User", Created: created, LastModified: lastModified}
+	return &user, nil
+}
+
+// ListUsers returns a page of users matching the query, and the total number of matches
+func (s *Store) ListUsers(ctx context.Context, query ListQuery) ([]*User, int, error) {
+	where, args, err := whereClause(query.Filter, userFilterColumns)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := s.db.QueryRow(ctx, `SELECT COUNT(*) `+userFrom+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+	if query.Count == 0 || total < query.StartIndex {
+		return []*User{}, total, nil
+	}
+
+	args = append(args, query.Count, query.StartIndex-1)
+	rows, err := s.db.Query(ctx, fmt.Sprintf(`SELECT %s %s %s ORDER BY u.created_at, u.id LIMIT $%d OFFSET $%d`,
+		userColumns, userFrom, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []*User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+	return users, total, rows.Err()
+}
+
+// GetUser returns a user by ID
+func (s *Store) GetUser(ctx context.Context, id string) (*User, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrNotFound("User", id)
+	}
+	user, err := scanUser(s.db.QueryRow(ctx, `SELECT `+userColumns+userFrom+` WHERE u.id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound("User", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// CreateUser creates a user. Provisioned users have no password and sign in through the
+// identity provider; their email counts as verified.
+func (s *Store) CreateUser(ctx context.Context, user *User) (*User, error) {
+	email, err := validateUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	var id string
+	err = s.inTx(ctx, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+			INSERT INTO auth.users (email, email_verified, role, is_locked)
+			VALUES ($1, true, 'authenticated', $2)
+			RETURNING id
+		`, email, !user.IsActive()).Scan(&id)
+		if err != nil {
+			return err
+		}
+		return upsertSCIMUser(ctx, tx, id, user)
+	})
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, NewError(http.StatusConflict, ErrorTypeUniqueness, "a user with this userName or email already exists")
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	return s.GetUser(ctx, id)
+}
+
+// ReplaceUser replaces the attributes of a user. Deactivating a user locks the account and
+// ends its sessions; reactivating it lifts a lock set by deactivation, but not a temporary
+// lock after failed sign-ins.
+func (s *Store) ReplaceUser(ctx context.Context, id string, user *User) (*User, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrNotFound("User", id)
+	}
+	email, err := validateUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.inTx(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			UPDATE auth.users SET email = $2, updated_at = NOW() WHERE id = $1
+		`, id, email)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrNotFound("User", id)
+		}
+		if err := upsertSCIMUser(ctx, tx, id, user); err != nil {
+			return err
+		}
+
+		if user.IsActive() {
+			_, err = tx.Exec(ctx, `
+				UPDATE auth.users
+				SET is_locked = false, failed_login_attempts = 0, updated_at = NOW()
+				WHERE id = $1 AND is_locked AND locked_until IS NULL
+			`, id)
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+			UPDATE auth.users SET is_locked = true, locked_until = NULL, updated_at = NOW() WHERE id = $1
+		`, id); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `DELETE FROM auth.sessions WHERE user_id = $1`, id)
+		return err
+	})
+	if err != nil {
+		var scimErr *Error
+		if errors.As(err, &scimErr) {
+			return nil, scimErr
+		}
+		if database.IsUniqueViolation(err) {
+			return nil, NewError(http.StatusConflict, ErrorTypeUniqueness, "a user with this userName or email already exists")
+		}
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	return s.GetUser(ctx, id)
+}
+
+// DeleteUser deletes a user together with its sessions and group memberships
+func (s *Store) DeleteUser(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrNotFound("User", id)
+	}
+	tag, err := s.db.Exec(ctx, `DELETE FROM auth.users WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound("User", id)
+	}
+	return nil
+}
+
+func upsertSCIMUser(ctx context.Context, tx pgx.Tx, id string, user *User) error {
+	var givenName, familyName string
+	if user.Name != nil {
+		givenName, familyName = user.Name.GivenName, user.Name.FamilyName
+	}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO auth.scim_users (user_id, external_id, user_name, display_name, given_name, family_name)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE SET
+			external_id = EXCLUDED.external_id,
+			user_name = EXCLUDED.user_name,
+			display_name = EXCLUDED.display_name,
+			given_name = EXCLUDED.given_name,
+			family_name = EXCLUDED.family_name,
+			updated_at = NOW()
+	`, id, nullIfEmpty(user.ExternalID), user.UserName, nullIfEmpty(user.DisplayName),
+		nullIfEmpty(givenName), nullIfEmpty(familyName))
+	return err
+}
+
+// validateUser checks the required attributes of a user and returns its email
+func validateUser(user *User) (string, error) {
+	user.UserName = strings.TrimSpace(user.UserName)
+	if user.UserName == "" {
+		return "", NewError(http.StatusBadRequest, ErrorTypeInvalidValue, "userName is required")
+	}
+	email := user.Email()
+	if !strings.Contains(email, "@") {
+		return "", NewError(http.StatusBadRequest, ErrorTypeInvalidValue, "an email address is required, either in emails or as userName")
+	}
+	return email, nil
+}
+
+// ============================================================================
+// GROUPS
+// ============================================================================
+
+const groupColumns = `g.id, g.name, g.external_id, g.created_at, g.updated_at`
+
+var groupFilterColumns = map[string]Column{
+	"id":          {Expr: "g.id::text", CaseExact: true},
+	"displayname": {Expr: "g.name"},
+	"externalid":  {Expr: "g.external_id", CaseExact: true},
+	"members.value": {
+		Expr:      "gm.user_id::text",
+		CaseExact: true,
+		Exists:    "SELECT 1 FROM auth.group_members gm WHERE gm.group_id = g.id",
+	},
+	"meta.created":      {Expr: "g.created_at", Type: TypeDateTime},
+	"meta.lastmodified": {Expr: "g.updated_at", Type: TypeDateTime},
+}
+
+func scanGroup(row pgx.Row) (*Group, error) {
+	var (
+		group      Group
+		externalID *string
+		created    time.Time
+		modified   time.Time
+	)
+	if err := row.Scan(&group.ID, &group.DisplayName, &externalID, &created, &modified); err != nil {
+		return nil, err
+	}
+	group.Schemas = []string{SchemaGroup}
+	group.ExternalID = deref(externalID)
+	group.Meta = &Meta{ResourceType: "Group", Created: created, LastModified: modified}
+	return &group, nil
+}
+
+// ListGroups returns a page of groups matching the query, and the total number of matches
+func (s *Store) ListGroups(ctx context.Context, query ListQuery) ([]*Group, int, error) {
+	where, args, err := whereClause(query.Filter, groupFilterColumns)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := s.db.QueryRow(ctx, `SELECT COUNT(*) FROM auth.groups g`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count groups: %w", err)
+	}
+	if query.Count == 0 || total < query.StartIndex {
+		return []*Group{}, total, nil
+	}
+
+	args = append(args, query.Count, query.StartIndex-1)
+	rows, err := s.db.Query(ctx, fmt.Sprintf(`SELECT %s FROM auth.groups g %s ORDER BY g.name, g.id LIMIT $%d OFFSET $%d`,
+		groupColumns, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list groups: %w", err)
+	}
+	groups := []*Group{}
+	for rows.Next() {
+		group, err := scanGroup(rows)
+		if err != nil {
+			rows.Close()
+			return nil, 0, fmt.Errorf("failed to scan group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list groups: %w", err)
+	}
+
+	if !query.ExcludeMembers {
+		for _, group := range groups {
+			if group.Members, err = s.groupMembers(ctx, group.ID); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+	return groups, total, nil
+}
+
+// GetGroup returns a group with its members
+func (s *Store) GetGroup(ctx context.Context, id string, excludeMembers bool) (*Group, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrNotFound("Group", id)
+	}
+	group, err := scanGroup(s.db.QueryRow(ctx, `SELECT `+groupColumns+` FROM auth.groups g WHERE g.id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound("Group", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+	if !excludeMembers {
+		if group.Members, err = s.groupMembers(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	return group, nil
+}
+
+func (s *Store) groupMembers(ctx context.Context, groupID string) ([]MultiValue, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT u.id, COALESCE(su.user_name, u.email)
+		FROM auth.group_members gm
+		JOIN auth.users u ON u.id = gm.user_id
+		LEFT JOIN auth.scim_users su ON su.user_id = u.id
+		WHERE gm.group_id = $1
+		ORDER BY gm.added_at, u.id
+	`, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list group members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []MultiValue{}
+	for rows.Next() {
+		var member MultiValue
+		if err := rows.Scan(&member.Value, &member.Display); err != nil {
+			return nil, fmt.Errorf("failed to scan group member: %w", err)
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+// CreateGroup creates a group with its members
+func (s *Store) CreateGroup(ctx context.Context, group *Group) (*Group, error) {
+	members, err := validateGroup(group)
+	if err != nil {
+		return nil, err
+	}
+
+	var id string
+	err = s.inTx(ctx, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+			INSERT INTO auth.groups (name, external_id) VALUES ($1, $2) RETURNING id
+		`, group.DisplayName, nullIfEmpty(group.ExternalID)).Scan(&id)
+		if err != nil {
+			return err
+		}
+		return setGroupMembers(ctx, tx, id, members)
+	})
+	if err != nil {
+		return nil, groupError(err, "create")
+	}
+	return s.GetGroup(ctx, id, false)
+}
+
+// ReplaceGroup replaces the attributes and members of a group
+func (s *Store) ReplaceGroup(ctx context.Context, id string, group *Group) (*Group, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrNotFound("Group", id)
+	}
+	members, err := validateGroup(group)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.inTx(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			UPDATE auth.groups SET name = $2, external_id = $3, updated_at = NOW() WHERE id = $1
+		`, id, group.DisplayName, nullIfEmpty(group.ExternalID))
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrNotFound("Group", id)
+		}
+		return setGroupMembers(ctx, tx, id, members)
+	})
+	if err != nil {
+		return nil, groupError(err, "update")
+	}
+	return s.GetGroup(ctx, id, false)
+}
+
+// DeleteGroup deletes a group
+func (s *Store) DeleteGroup(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrNotFound("Group", id)
+	}
+	tag, err := s.db.Exec(ctx, `DELETE FROM auth.groups WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound("Group", id)
+	}
+	return nil
+}
+
+// setGroupMembers makes members the exact member list of a group, keeping the added_at
+// of existing members
+func setGroupMembers(ctx context.Context, tx pgx.Tx, groupID string, members []string) error {
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM auth.group_members WHERE group_id = $1 AND NOT (user_id = ANY($2::uuid[]))
+	`, groupID, members); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO auth.group_members (group_id, user_id)
+		SELECT $1, unnest($2::uuid[])
+		ON CONFLICT (group_id, user_id) DO NOTHING
+	`, groupID, members)
+	return err
+}
+
+// validateGroup checks the required attributes of a group and returns its member IDs
+func validateGroup(group *Group) ([]string, error) {
+	group.DisplayName = strings.TrimSpace(group.DisplayName)
+	if group.DisplayName == "" {
+		return nil, NewError(http.StatusBadRequest, ErrorTypeInvalidValue, "displayName is required")
+	}
+	members := group.MemberIDs()
+	for _, id := range members {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, NewError(http.StatusBadRequest, ErrorTypeInvalidValue, fmt.Sprintf("member %s is not a user ID", id))
+		}
+	}
+	return members, nil
+}
+
+func groupError(err error, action string) error {
+	var scimErr *Error
+	switch {
+	case errors.As(err, &scimErr):
+		return scimErr
+	case database.IsUniqueViolation(err):
+		return NewError(http.StatusConflict, ErrorTypeUniqueness, "a group with this displayName already exists")
+	case database.IsForeignKeyViolation(err):
+		return NewError(http.StatusBadRequest, ErrorTypeInvalidValue, "a member does not exist")
+	}
+	return fmt.Errorf("failed to %s group: %w", action, err)
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+func (s *Store) inTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func whereClause(filter Expr, columns map[string]Column) (string, []any, error) {
+	if filter == nil {
+		return "", nil, nil
+	}
+	var args []any
+	cond, err := ToSQL(filter, columns, &args)
+	if err != nil {
+		return "", nil, err
+	}
+	return " WHERE " + cond, args, nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,127 @@
+package secretscan
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// Policy sets how a Guard handles detected credentials
+type Policy string
+
+const (
+	// PolicyBlock rejects content with credentials unless an override reason is given
+	PolicyBlock Policy = "block"
+	// PolicyFlag accepts content with credentials and records the findings
+	PolicyFlag Policy = "flag"
+	// PolicyOff disables scanning
+	PolicyOff Policy = "off"
+)
+
+// ParsePolicy parses a configured policy, defaulting to block
+func ParsePolicy(s string) (Policy, error) {
+	switch Policy(strings.ToLower(strings.TrimSpace(s))) {
+	case "", PolicyBlock:
+		return PolicyBlock, nil
+	case PolicyFlag:
+		return PolicyFlag, nil
+	case PolicyOff:
+		return PolicyOff, nil
+	}
+	return "", fmt.Errorf("invalid secret scanning policy %q", s)
+}
+
+// Subject kinds
+const (
+	KindFunction     = "function"
+	KindSharedModule = "shared_module"
+	KindKBDocument   = "kb_document"
+)
+
+// Outcomes recorded for content with findings
+const (
+	OutcomeBlocked    = "blocked"
+	OutcomeFlagged    = "flagged"
+	OutcomeOverridden = "overridden"
+)
+
+// Subject is the content being checked
+type Subject struct {
+	Kind    string
+	Name    string
+	ActorID *uuid.UUID
+	// OverrideReason lets blocked content through. Callers must only set it for admins.
+	OverrideReason string
+}
+
+// BlockedError is returned when content with credentials is rejected
+type BlockedError struct {
+	Findings []Finding
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("content contains %d potential secret(s); remove them or provide an override reason", len(e.Findings))
+}
+
+// Guard checks content against a policy and records what it finds
+type Guard struct {
+	policy Policy
+	store  *Store
+}
+
+// NewGuard creates a guard. A nil store skips recording events.
+func NewGuard(policy Policy, store *Store) *Guard {
+	return &Guard{policy: policy, store: store}
+}
+
+// Check scans files keyed by path and returns a *BlockedError if they must be rejected.
+// A nil guard allows everything.
+func (g *Guard) Check(ctx context.Context, subject Subject, files map[string]string) error {
+	if g == nil || g.policy == PolicyOff {
+		return nil
+	}
+
+	findings := ScanFiles(files)
+	if len(findings) == 0 {
+		return nil
+	}
+
+	outcome := OutcomeFlagged
+	if g.policy == PolicyBlock {
+		outcome = OutcomeBlocked
+		if subject.OverrideReason != "" {
+			outcome = OutcomeOverridden
+		}
+	}
+
+	log.Warn().
+		Str("kind", subject.Kind).
+		Str("subject", subject.Name).
+		Str("outcome", outcome).
+		Int("findings", len(findings)).
+		Msg("Potential secrets detected")
+
+	if g.store != nil {
+		event := &Event{
+			Kind:     subject.Kind,
+			Subject:  subject.Name,
+			Outcome:  outcome,
+			Findings: findings,
+			ActorID:  subject.ActorID,
+		}
+		if outcome == OutcomeOverridden {
+			event.OverrideReason = &subject.OverrideReason
+		}
+		if err := g.store.Record(ctx, event); err != nil {
+			log.Error().Err(err).Str("subject", subject.Name).Msg("Failed to record secret scan event")
+		}
+	}
+
+	if outcome == OutcomeBlocked {
+		return &BlockedError{Findings: findings}
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+// Package secretscan detects committed credentials such as cloud access keys, private keys and
+// JWTs in deployed function code and in documents ingested into public knowledge bases.
+package secretscan
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// AllowComment suppresses findings on the line it appears on, for known false positives
+// such as test fixtures
+const AllowComment = "fluxbase:allow-secret"
+
+// maxFindings caps the findings reported per scan
+const maxFindings = 100
+
+// Finding is a detected credential. Match is redacted and never holds the full secret.
+type Finding struct {
+	Rule        string `json:"rule"`
+	Description string `json:"description"`
+	File        string `json:"file,omitempty"`
+	Line        int    `json:"line"`
+	Match       string `json:"match"`
+}
+
+// rule detects one kind of credential. The secret is the first submatch, or the whole
+// match when the pattern has no groups; verify, when set, filters out false positives.
+type rule struct {
+	id          string
+	description string
+	pattern     *regexp.Regexp
+	verify      func(secret string) bool
+}
+
+var rules = []rule{
+	{
+		id:          "aws-access-key-id",
+		description: "AWS access key ID",
+		pattern:     regexp.MustCompile(`\b((?:AKIA|ASIA|ABIA|ACCA|A3T[A-Z0-9])[A-Z0-9]{16})\b`),
+		verify:      notExample,
+	},
+	{
+		id:          "aws-secret-access-key",
+		description: "AWS secret access key",
+		pattern:     regexp.MustCompile(`(?i)(?:aws_?secret_?access_?key|aws_?secret_?key|secret_?access_?key)["'\x60]?\s*[:=]\s*["'\x60]?([A-Za-z0-9/+=]{40})(?:[^A-Za-z0-9/+=]|$)`),
+		verify:      notExample,
+	},
+	{
+		id:          "private-key",
+		description: "Private key",
+		pattern:     regexp.MustCompile(`-----BEGIN (?:(?:RSA|EC|DSA|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY(?: BLOCK)?-----`),
+	},
+	{
+		id:          "jwt",
+		description: "JSON Web Token",
+		pattern:     regexp.MustCompile(`\b(eyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{16,})`),
+		verify:      isSensitiveJWT,
+	},
+	{
+		id:          "github-token",
+		description: "GitHub token",
+		pattern:     regexp.MustCompile(`\b((?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{82})\b`),
+	},
+	{
+		id:          "slack-token",
+		description: "Slack token",
+		pattern:     regexp.MustCompile(`\b(xox[baprs]-[0-9A-Za-z-]{10,})`),
+	},
+	{
+		id:          "stripe-secret-key",
+		description: "Stripe live secret key",
+		pattern:     regexp.MustCompile(`\b((?:sk|rk)_live_[0-9A-Za-z]{24,})\b`),
+	},
+	{
+		id:          "google-api-key",
+		description: "Google API key",
+		pattern:     regexp.MustCompile(`\b(AIza[0-9A-Za-z_-]{35})(?:[^0-9A-Za-z_-]|$)`),
+	},
+}
+
+// Scan returns the credentials found in content
+func Scan(content string) []Finding {
+	return scan("", content, nil)
+}
+
+// ScanFiles returns the credentials found in a set of files keyed by path, ordered by path
+func ScanFiles(files map[string]string) []Finding {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var findings []Finding
+	for _, path := range paths {
+		findings = scan(path, files[path], findings)
+		if len(findings) >= maxFindings {
+			break
+		}
+	}
+	return findings
+}
+
+func scan(file, content string, findings []Finding) []Finding {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), len(content)+1)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.Contains(text, AllowComment) {
+			continue
+		}
+		for _, r := range rules {
+			for _, match := range r.pattern.FindAllStringSubmatch(text, -1) {
+				secret := match[0]
+				if len(match) > 1 {
+					secret = match[1]
+				}
+				if r.verify != nil && !r.verify(secret) {
+					continue
+				}
+				findings = append(findings, Finding{
+					Rule:        r.id,
+					Description: r.description,
+					File:        file,
+					Line:        line,
+					Match:       redact(secret),
+				})
+				if len(findings) >= maxFindings {
+					return findings
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// redact keeps enough of a secret to recognize it
+func redact(secret string) string {
+	if strings.HasPrefix(secret, "-----") {
+		return secret
+	}
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:4] + strings.Repeat("*", min(len(secret)-4, 16))
+}
+
+// notExample skips the placeholder keys used in the AWS documentation
+func notExample(secret string) bool {
+	return !strings.Contains(secret, "EXAMPLE")
+}
+
+// isSensitiveJWT checks that a token is a JWT and not an anon key, which is meant to be public
+func isSensitiveJWT(token string) bool {
+	parts := strings.Split(token, ".")
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if !decodeSegment(parts[0], &header) || header.Alg == "" {
+		return false
+	}
+	var claims struct {
+		Role string `json:"role"`
+	}
+	if decodeSegment(parts[1], &claims) && claims.Role == "anon" {
+		return false
+	}
+	return true
+}
+
+func decodeSegment(segment string, v any) bool {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
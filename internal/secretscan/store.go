@@ -0,0 +1,93 @@
+package secretscan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nimbleflux/fluxbase/internal/database"
+)
+
+// Event records content that was found to contain credentials
+type Event struct {
+	ID             uuid.UUID  `json:"id"`
+	Kind           string     `json:"kind"`
+	Subject        string     `json:"subject"`
+	Outcome        string     `json:"outcome"`
+	Findings       []Finding  `json:"findings"`
+	OverrideReason *string    `json:"override_reason,omitempty"`
+	ActorID        *uuid.UUID `json:"actor_id,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ListFilter filters listed events. Empty fields match everything.
+type ListFilter struct {
+	Kind    string
+	Outcome string
+	Limit   int
+	Offset  int
+}
+
+// Store persists secret scan events
+type Store struct {
+	db *database.Connection
+}
+
+// NewStore creates a secret scan event store
+func NewStore(db *database.Connection) *Store {
+	return &Store{db: db}
+}
+
+// Record stores an event
+func (s *Store) Record(ctx context.Context, event *Event) error {
+	findings, err := json.Marshal(event.Findings)
+	if err != nil {
+		return fmt.Errorf("failed to encode findings: %w", err)
+	}
+
+	err = s.db.QueryRow(ctx, `
+		INSERT INTO app.secret_scan_events (kind, subject, outcome, findings, override_reason, actor_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, event.Kind, event.Subject, event.Outcome, findings, event.OverrideReason, event.ActorID).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record secret scan event: %w", err)
+	}
+	return nil
+}
+
+// List returns events, newest first
+func (s *Store) List(ctx context.Context, filter ListFilter) ([]Event, error) {
+	if filter.Limit <= 0 || filter.Limit > 1000 {
+		filter.Limit = 100
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT id, kind, subject, outcome, findings, override_reason, actor_id, created_at
+		FROM app.secret_scan_events
+		WHERE ($1 = '' OR kind = $1) AND ($2 = '' OR outcome = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`, filter.Kind, filter.Outcome, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secret scan events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		var event Event
+		var findings []byte
+		if err := rows.Scan(&event.ID, &event.Kind, &event.Subject, &event.Outcome, &findings,
+			&event.OverrideReason, &event.ActorID, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan secret scan event: %w", err)
+		}
+		if err := json.Unmarshal(findings, &event.Findings); err != nil {
+			return nil, fmt.Errorf("failed to decode findings: %w", err)
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
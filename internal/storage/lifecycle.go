@@ -0,0 +1,484 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/rs/zerolog/log"
+)
+
+// LifecycleAction is what a lifecycle rule does with expired objects
+type LifecycleAction string
+
+const (
+	// LifecycleActionDelete deletes expired objects
+	LifecycleActionDelete LifecycleAction = "delete"
+	// LifecycleActionArchive moves expired objects to the rule's target bucket under the same path
+	LifecycleActionArchive LifecycleAction = "archive"
+)
+
+var (
+	// ErrLifecycleRuleNotFound is returned for an unknown lifecycle rule
+	ErrLifecycleRuleNotFound = errors.New("lifecycle rule not found")
+	// ErrLifecycleRuleExists is returned when the bucket already has a rule with the same name
+	ErrLifecycleRuleExists = errors.New("bucket already has a lifecycle rule with this name")
+	// ErrLifecycleBucketNotFound is returned when the rule's bucket or target bucket does not exist
+	ErrLifecycleBucketNotFound = errors.New("bucket not found")
+)
+
+// LifecycleRule deletes or archives the objects of a bucket once they are older than AgeDays
+type LifecycleRule struct {
+	ID           uuid.UUID       `json:"id"`
+	Bucket       string          `json:"bucket"`
+	Name         string          `json:"name"`
+	Prefix       string          `json:"prefix"`
+	Action       LifecycleAction `json:"action"`
+	AgeDays      int             `json:"age_days"`
+	TargetBucket string          `json:"target_bucket,omitempty"`
+	Enabled      bool            `json:"enabled"`
+	LastRunAt    *time.Time      `json:"last_run_at,omitempty"`
+	CreatedBy    *uuid.UUID      `json:"created_by,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+// Validate checks the rule's action and age
+func (r *LifecycleRule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("name is required")
+	}
+	if r.AgeDays <= 0 {
+		return errors.New("age_days must be positive")
+	}
+	switch r.Action {
+	case LifecycleActionDelete:
+		if r.TargetBucket != "" {
+			return errors.New("target_bucket is only allowed for the archive action")
+		}
+	case LifecycleActionArchive:
+		if r.TargetBucket == "" {
+			return errors.New("target_bucket is required for the archive action")
+		}
+		if r.TargetBucket == r.Bucket {
+			return errors.New("target_bucket must differ from the rule's bucket")
+		}
+	default:
+		return fmt.Errorf("invalid action %q, expected delete or archive", r.Action)
+	}
+	return nil
+}
+
+// Cutoff returns the creation time objects must be older than to be affected at now
+func (r *LifecycleRule) Cutoff(now time.Time) time.Time {
+	return now.AddDate(0, 0, -r.AgeDays)
+}
+
+// LifecycleObject is an object a lifecycle rule applies to
+type LifecycleObject struct {
+	ID          uuid.UUID `json:"id"`
+	Key         string    `json:"key"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// LifecycleStore persists lifecycle rules and finds the objects they apply to
+type LifecycleStore struct {
+	db *pgxpool.Pool
+}
+
+// NewLifecycleStore creates a lifecycle rule store
+func NewLifecycleStore(db *pgxpool.Pool) *LifecycleStore {
+	return &LifecycleStore{db: db}
+}
+
+const lifecycleRuleColumns = `id, bucket_id, name, prefix, action, age_days, COALESCE(target_bucket_id, ''),
+	enabled, last_run_at, created_by, created_at, updated_at`
+
+func scanLifecycleRule(row pgx.Row) (*LifecycleRule, error) {
+	r := &LifecycleRule{}
+	err := row.Scan(&r.ID, &r.Bucket, &r.Name, &r.Prefix, &r.Action, &r.AgeDays, &r.TargetBucket,
+		&r.Enabled, &r.LastRunAt, &r.CreatedBy, &r.CreatedAt, &r.UpdatedAt)
+	return r, err
+}
+
+func (s *LifecycleStore) queryRules(ctx context.Context, query string, args ...any) ([]*LifecycleRule, error) {
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []*LifecycleRule{}
+	for rows.Next() {
+		r, err := scanLifecycleRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
+
+// List returns the lifecycle rules of a bucket
+func (s *LifecycleStore) List(ctx context.Context, bucket string) ([]*LifecycleRule, error) {
+	rules, err := s.queryRules(ctx, `
+		SELECT `+lifecycleRuleColumns+` FROM storage.lifecycle_rules
+		WHERE bucket_id = $1
+		ORDER BY created_at
+	`, bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lifecycle rules: %w", err)
+	}
+	return rules, nil
+}
+
+// Enabled returns the enabled lifecycle rules of all buckets
+func (s *LifecycleStore) Enabled(ctx context.Context) ([]*LifecycleRule, error) {
+	rules, err := s.queryRules(ctx, `
+		SELECT `+lifecycleRuleColumns+` FROM storage.lifecycle_rules
+		WHERE enabled
+		ORDER BY bucket_id, created_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list enabled lifecycle rules: %w", err)
+	}
+	return rules, nil
+}
+
+// Get returns a lifecycle rule of a bucket
+func (s *LifecycleStore) Get(ctx context.Context, bucket string, id uuid.UUID) (*LifecycleRule, error) {
+	r, err := scanLifecycleRule(s.db.QueryRow(ctx, `
+		SELECT `+lifecycleRuleColumns+` FROM storage.lifecycle_rules
+		WHERE bucket_id = $1 AND id = $2
+	`, bucket, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrLifecycleRuleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lifecycle rule: %w", err)
+	}
+	return r, nil
+}
+
+// Create adds a lifecycle rule to a bucket. The rule must already be validated.
+func (s *LifecycleStore) Create(ctx context.Context, r *LifecycleRule) error {
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO storage.lifecycle_rules (bucket_id, name, prefix, action, age_days, target_bucket_id, enabled, created_by)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8)
+		RETURNING id, created_at, updated_at
+	`, r.Bucket, r.Name, r.Prefix, r.Action, r.AgeDays, r.TargetBucket, r.Enabled, r.CreatedBy,
+	).Scan(&r.ID, &r.CreatedAt, &r.UpdatedAt)
+	if database.IsUniqueViolation(err) {
+		return ErrLifecycleRuleExists
+	}
+	if database.IsForeignKeyViolation(err) {
+		return ErrLifecycleBucketNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create lifecycle rule: %w", err)
+	}
+	return nil
+}
+
+// Update replaces the settings of a lifecycle rule. The rule must already be validated.
+func (s *LifecycleStore) Update(ctx context.Context, r *LifecycleRule) error {
+	err := s.db.QueryRow(ctx, `
+		UPDATE storage.lifecycle_rules
+		SET name = $3, prefix = $4, action = $5, age_days = $6, target_bucket_id = NULLIF($7, ''),
+			enabled = $8, updated_at = NOW()
+		WHERE bucket_id = $1 AND id = $2
+		RETURNING updated_at
+	`, r.Bucket, r.ID, r.Name, r.Prefix, r.Action, r.AgeDays, r.TargetBucket, r.Enabled).Scan(&r.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrLifecycleRuleNotFound
+	}
+	if database.IsUniqueViolation(err) {
+		return ErrLifecycleRuleExists
+	}
+	if database.IsForeignKeyViolation(err) {
+		return ErrLifecycleBucketNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update lifecycle rule: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a lifecycle rule from a bucket
+func (s *LifecycleStore) Delete(ctx context.Context, bucket string, id uuid.UUID) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM storage.lifecycle_rules WHERE bucket_id = $1 AND id = $2`, bucket, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete lifecycle rule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrLifecycleRuleNotFound
+	}
+	return nil
+}
+
+// MarkRun records when a rule was last applied
+func (s *LifecycleStore) MarkRun(ctx context.Context, id uuid.UUID, at time.Time) error {
+	if _, err := s.db.Exec(ctx, `UPDATE storage.lifecycle_rules SET last_run_at = $2 WHERE id = $1`, id, at); err != nil {
+		return fmt.Errorf("failed to record lifecycle run: %w", err)
+	}
+	return nil
+}
+
+// Due returns up to limit of the oldest objects the rule applies to at now
+func (s *LifecycleStore) Due(ctx context.Context, r *LifecycleRule, now time.Time, limit int) ([]LifecycleObject, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id, path, COALESCE(size, 0), COALESCE(mime_type, ''), created_at
+		FROM storage.objects
+		WHERE bucket_id = $1 AND starts_with(path, $2) AND created_at < $3
+		ORDER BY created_at
+		LIMIT $4
+	`, r.Bucket, r.Prefix, r.Cutoff(now), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired objects: %w", err)
+	}
+	defer rows.Close()
+
+	objects := []LifecycleObject{}
+	for rows.Next() {
+		var o LifecycleObject
+		if err := rows.Scan(&o.ID, &o.Key, &o.Size, &o.ContentType, &o.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan expired object: %w", err)
+		}
+		objects = append(objects, o)
+	}
+	return objects, rows.Err()
+}
+
+// CountDue returns the number and total size of the objects the rule applies to at now
+func (s *LifecycleStore) CountDue(ctx context.Context, r *LifecycleRule, now time.Time) (int64, int64, error) {
+	var count, size int64
+	err := s.db.QueryRow(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(size), 0)
+		FROM storage.objects
+		WHERE bucket_id = $1 AND starts_with(path, $2) AND created_at < $3
+	`, r.Bucket, r.Prefix, r.Cutoff(now)).Scan(&count, &size)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count expired objects: %w", err)
+	}
+	return count, size, nil
+}
+
+// deleteObject removes an object's record
+func (s *LifecycleStore) deleteObject(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.db.Exec(ctx, `DELETE FROM storage.objects WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete object record: %w", err)
+	}
+	return nil
+}
+
+// archiveObject moves an object's record to the target bucket, replacing an object with
+// the same path there. The archived object's age starts over, so rules of the target
+// bucket count from the time of archival.
+func (s *LifecycleStore) archiveObject(ctx context.Context, id uuid.UUID, targetBucket string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO storage.objects (bucket_id, path, mime_type, size, metadata, owner_id)
+		SELECT $2, path, mime_type, size, metadata, owner_id FROM storage.objects WHERE id = $1
+		ON CONFLICT (bucket_id, path) DO UPDATE
+		SET mime_type = EXCLUDED.mime_type, size = EXCLUDED.size, metadata = EXCLUDED.metadata,
+			owner_id = EXCLUDED.owner_id, created_at = NOW(), updated_at = NOW()
+	`, id, targetBucket)
+	if err != nil {
+		return fmt.Errorf("failed to copy object record: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM storage.objects WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete object record: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// LifecycleSweeper applies the enabled lifecycle rules in batches. It must run on a
+// single node (leader-elected).
+type LifecycleSweeper struct {
+	store     *LifecycleStore
+	provider  Storage
+	interval  time.Duration
+	batchSize int
+	now       func() time.Time
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running bool
+	mu      sync.Mutex
+}
+
+// NewLifecycleSweeper creates a sweeper for the lifecycle rules of all buckets
+func NewLifecycleSweeper(store *LifecycleStore, provider Storage, cfg *config.LifecycleConfig) *LifecycleSweeper {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &LifecycleSweeper{
+		store:     store,
+		provider:  provider,
+		interval:  cfg.Interval,
+		batchSize: cfg.BatchSize,
+		now:       time.Now,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Start begins applying lifecycle rules
+func (s *LifecycleSweeper) Start() {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = true
+	if s.ctx.Err() != nil {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+	}
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.run()
+
+	log.Info().Dur("interval", s.interval).Msg("Storage lifecycle sweeper started")
+}
+
+// Stop stops applying lifecycle rules
+func (s *LifecycleSweeper) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	s.mu.Unlock()
+
+	s.cancel()
+	s.wg.Wait()
+
+	log.Info().Msg("Storage lifecycle sweeper stopped")
+}
+
+// run sweeps on start and then every interval
+func (s *LifecycleSweeper) run() {
+	defer s.wg.Done()
+
+	s.sweep(s.ctx)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(s.ctx)
+		}
+	}
+}
+
+// sweep applies every enabled rule
+func (s *LifecycleSweeper) sweep(ctx context.Context) {
+	rules, err := s.store.Enabled(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list storage lifecycle rules")
+		return
+	}
+
+	for _, rule := range rules {
+		if ctx.Err() != nil {
+			return
+		}
+		s.apply(ctx, rule)
+	}
+}
+
+// apply processes a rule's expired objects batch by batch. A batch with failures ends
+// the rule's run, so objects that keep failing are retried on the next sweep instead of
+// being listed again right away.
+func (s *LifecycleSweeper) apply(ctx context.Context, rule *LifecycleRule) {
+	now := s.now()
+	processed, failed := 0, 0
+
+	for ctx.Err() == nil {
+		objects, err := s.store.Due(ctx, rule, now, s.batchSize)
+		if err != nil {
+			log.Error().Err(err).Str("bucket", rule.Bucket).Str("rule", rule.Name).Msg("Failed to list expired objects")
+			return
+		}
+
+		batchFailed := 0
+		for _, object := range objects {
+			if err := s.applyToObject(ctx, rule, object); err != nil {
+				log.Warn().Err(err).
+					Str("bucket", rule.Bucket).
+					Str("key", object.Key).
+					Str("rule", rule.Name).
+					Msg("Failed to apply storage lifecycle rule")
+				batchFailed++
+				continue
+			}
+			processed++
+		}
+		failed += batchFailed
+
+		if len(objects) < s.batchSize || batchFailed > 0 {
+			break
+		}
+	}
+
+	if err := s.store.MarkRun(ctx, rule.ID, now); err != nil {
+		log.Warn().Err(err).Str("rule", rule.Name).Msg("Failed to record storage lifecycle run")
+	}
+	if processed > 0 || failed > 0 {
+		log.Info().
+			Str("bucket", rule.Bucket).
+			Str("rule", rule.Name).
+			Str("action", string(rule.Action)).
+			Int("objects", processed).
+			Int("failed", failed).
+			Msg("Applied storage lifecycle rule")
+	}
+}
+
+// applyToObject deletes or archives one object. Records are changed before the source
+// file is deleted, so a failed file deletion leaves an orphaned file rather than a
+// record without a file.
+func (s *LifecycleSweeper) applyToObject(ctx context.Context, rule *LifecycleRule, object LifecycleObject) error {
+	switch rule.Action {
+	case LifecycleActionDelete:
+		if err := s.store.deleteObject(ctx, object.ID); err != nil {
+			return err
+		}
+	case LifecycleActionArchive:
+		if err := s.provider.CopyObject(ctx, rule.Bucket, object.Key, rule.TargetBucket, object.Key); err != nil {
+			return fmt.Errorf("failed to copy object to %s: %w", rule.TargetBucket, err)
+		}
+		if err := s.store.archiveObject(ctx, object.ID, rule.TargetBucket); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown lifecycle action %q", rule.Action)
+	}
+
+	if err := s.provider.Delete(ctx, rule.Bucket, object.Key); err != nil {
+		log.Warn().Err(err).Str("bucket", rule.Bucket).Str("key", object.Key).
+			Msg("Failed to delete file from provider (metadata already removed)")
+	}
+	return nil
+}
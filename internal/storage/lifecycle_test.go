@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycleRule_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    LifecycleRule
+		wantErr string
+	}{
+		{
+			name: "delete",
+			rule: LifecycleRule{Bucket: "logs", Name: "expire", Action: LifecycleActionDelete, AgeDays: 30},
+		},
+		{
+			name: "archive",
+			rule: LifecycleRule{Bucket: "logs", Name: "archive", Action: LifecycleActionArchive, AgeDays: 7, TargetBucket: "logs-archive"},
+		},
+		{
+			name:    "missing name",
+			rule:    LifecycleRule{Bucket: "logs", Name: " ", Action: LifecycleActionDelete, AgeDays: 30},
+			wantErr: "name is required",
+		},
+		{
+			name:    "zero age",
+			rule:    LifecycleRule{Bucket: "logs", Name: "expire", Action: LifecycleActionDelete},
+			wantErr: "age_days must be positive",
+		},
+		{
+			name:    "unknown action",
+			rule:    LifecycleRule{Bucket: "logs", Name: "expire", Action: "glacier", AgeDays: 30},
+			wantErr: "invalid action",
+		},
+		{
+			name:    "delete with target bucket",
+			rule:    LifecycleRule{Bucket: "logs", Name: "expire", Action: LifecycleActionDelete, AgeDays: 30, TargetBucket: "other"},
+			wantErr: "only allowed for the archive action",
+		},
+		{
+			name:    "archive without target bucket",
+			rule:    LifecycleRule{Bucket: "logs", Name: "archive", Action: LifecycleActionArchive, AgeDays: 30},
+			wantErr: "target_bucket is required",
+		},
+		{
+			name:    "archive into the same bucket",
+			rule:    LifecycleRule{Bucket: "logs", Name: "archive", Action: LifecycleActionArchive, AgeDays: 30, TargetBucket: "logs"},
+			wantErr: "must differ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLifecycleRule_Cutoff(t *testing.T) {
+	rule := LifecycleRule{AgeDays: 30}
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), rule.Cutoff(now))
+}
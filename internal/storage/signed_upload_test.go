@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadToken_RoundTrip(t *testing.T) {
+	now := time.Now()
+	claims := &UploadTokenClaims{
+		Bucket:       "avatars",
+		Key:          "users/42.png",
+		ExpiresAt:    now.Add(time.Hour).Unix(),
+		ContentTypes: []string{"image/*"},
+		MaxSize:      1024,
+		UserID:       "2b8a4a9e-2f34-4a4f-8f0e-2f2a0d1b5c3d",
+		Role:         "authenticated",
+	}
+
+	token, err := SignUploadToken("secret", claims)
+	require.NoError(t, err)
+
+	verified, err := VerifyUploadToken("secret", token, now)
+	require.NoError(t, err)
+	assert.Equal(t, claims, verified)
+}
+
+func TestVerifyUploadToken_Rejects(t *testing.T) {
+	now := time.Now()
+	token, err := SignUploadToken("secret", &UploadTokenClaims{
+		Bucket:    "avatars",
+		Key:       "a.png",
+		ExpiresAt: now.Add(time.Minute).Unix(),
+		MaxSize:   1024,
+	})
+	require.NoError(t, err)
+	payload, sig, _ := strings.Cut(token, ".")
+
+	t.Run("wrong secret", func(t *testing.T) {
+		_, err := VerifyUploadToken("other", token, now)
+		assert.ErrorIs(t, err, ErrInvalidUploadToken)
+	})
+
+	t.Run("tampered payload", func(t *testing.T) {
+		forged, err := SignUploadToken("other", &UploadTokenClaims{
+			Bucket:    "avatars",
+			Key:       "a.png",
+			ExpiresAt: now.Add(time.Minute).Unix(),
+			MaxSize:   1 << 40,
+		})
+		require.NoError(t, err)
+		forgedPayload, _, _ := strings.Cut(forged, ".")
+
+		_, err = VerifyUploadToken("secret", forgedPayload+"."+sig, now)
+		assert.ErrorIs(t, err, ErrInvalidUploadToken)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, token := range []string{"", payload, payload + ".!!", "!!." + sig} {
+			_, err := VerifyUploadToken("secret", token, now)
+			assert.ErrorIs(t, err, ErrInvalidUploadToken, token)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		_, err := VerifyUploadToken("secret", token, now.Add(2*time.Minute))
+		assert.ErrorIs(t, err, ErrUploadTokenExpired)
+	})
+
+	t.Run("no secret", func(t *testing.T) {
+		_, err := VerifyUploadToken("", token, now)
+		assert.Error(t, err)
+	})
+}
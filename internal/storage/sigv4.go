@@ -0,0 +1,318 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SigV4Algorithm is the only signing algorithm accepted by the S3-compatible API
+	SigV4Algorithm = "AWS4-HMAC-SHA256"
+	// UnsignedPayload is the payload hash of requests whose body isn't signed
+	UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+	sigV4TimeFormat = "20060102T150405Z"
+	sigV4DateFormat = "20060102"
+	// sigV4MaxSkew is how far the request time may be from the server's clock
+	sigV4MaxSkew = 15 * time.Minute
+	// sigV4MaxExpires is the longest validity of a presigned URL (7 days, as in S3)
+	sigV4MaxExpires = 7 * 24 * time.Hour
+)
+
+var (
+	// ErrSigV4Missing is returned for a request carrying no SigV4 signature
+	ErrSigV4Missing = errors.New("request is not signed")
+	// ErrSigV4Malformed is returned for a signature that can't be parsed
+	ErrSigV4Malformed = errors.New("malformed signature")
+	// ErrSigV4Mismatch is returned when the signature doesn't match the request
+	ErrSigV4Mismatch = errors.New("signature does not match")
+	// ErrSigV4Skewed is returned when the request time is too far from the server's clock
+	ErrSigV4Skewed = errors.New("request time is too skewed")
+	// ErrSigV4Expired is returned for a presigned URL past its expiry
+	ErrSigV4Expired = errors.New("request has expired")
+	// ErrPayloadHashMismatch is returned when a body doesn't match its signed SHA-256
+	ErrPayloadHashMismatch = errors.New("payload does not match x-amz-content-sha256")
+)
+
+// SigV4Request is the part of an HTTP request covered by an AWS Signature Version 4
+type SigV4Request struct {
+	Method string
+	// Path is the decoded request path
+	Path  string
+	Query url.Values
+	// Header returns a request header, Host included
+	Header func(name string) string
+}
+
+// SigV4 is the parsed signature of a request, from the Authorization header or the
+// query string of a presigned URL
+type SigV4 struct {
+	AccessKeyID   string
+	Region        string
+	Service       string
+	SignedHeaders []string
+	Signature     string
+	Time          time.Time
+	// PayloadHash is the signed SHA-256 of the body, or UnsignedPayload
+	PayloadHash string
+	// Expires is the validity of a presigned URL, zero for header authentication
+	Expires   time.Duration
+	Presigned bool
+
+	scopeDate string
+}
+
+// IsSigV4Request reports whether a request carries a SigV4 signature
+func IsSigV4Request(req SigV4Request) bool {
+	return strings.HasPrefix(req.Header("Authorization"), SigV4Algorithm+" ") ||
+		req.Query.Get("X-Amz-Algorithm") != ""
+}
+
+// ParseSigV4 reads the signature of a request. Presigned URLs are always signed over an
+// unsigned payload.
+func ParseSigV4(req SigV4Request) (*SigV4, error) {
+	if authHeader := req.Header("Authorization"); authHeader != "" {
+		return parseSigV4Header(req, authHeader)
+	}
+	if req.Query.Get("X-Amz-Algorithm") != "" {
+		return parseSigV4Query(req)
+	}
+	return nil, ErrSigV4Missing
+}
+
+// parseSigV4Header parses e.g.
+// AWS4-HMAC-SHA256 Credential=AKID/20130524/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-date, Signature=...
+func parseSigV4Header(req SigV4Request, authHeader string) (*SigV4, error) {
+	fields, ok := strings.CutPrefix(authHeader, SigV4Algorithm+" ")
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported authorization type", ErrSigV4Malformed)
+	}
+
+	values := make(map[string]string, 3)
+	for _, field := range strings.Split(fields, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid authorization field %q", ErrSigV4Malformed, field)
+		}
+		values[name] = value
+	}
+
+	sig := &SigV4{
+		Signature:   values["Signature"],
+		PayloadHash: req.Header("X-Amz-Content-Sha256"),
+	}
+	if sig.PayloadHash == "" {
+		return nil, fmt.Errorf("%w: missing x-amz-content-sha256 header", ErrSigV4Malformed)
+	}
+
+	amzDate := req.Header("X-Amz-Date")
+	if amzDate == "" {
+		return nil, fmt.Errorf("%w: missing x-amz-date header", ErrSigV4Malformed)
+	}
+	if err := sig.parseCommon(values["Credential"], values["SignedHeaders"], amzDate); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// parseSigV4Query parses the X-Amz-* parameters of a presigned URL
+func parseSigV4Query(req SigV4Request) (*SigV4, error) {
+	if algorithm := req.Query.Get("X-Amz-Algorithm"); algorithm != SigV4Algorithm {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrSigV4Malformed, algorithm)
+	}
+
+	expires, err := strconv.Atoi(req.Query.Get("X-Amz-Expires"))
+	if err != nil || expires <= 0 {
+		return nil, fmt.Errorf("%w: invalid X-Amz-Expires", ErrSigV4Malformed)
+	}
+	if time.Duration(expires)*time.Second > sigV4MaxExpires {
+		return nil, fmt.Errorf("%w: X-Amz-Expires must be at most %d seconds", ErrSigV4Malformed, int(sigV4MaxExpires.Seconds()))
+	}
+
+	sig := &SigV4{
+		Signature:   req.Query.Get("X-Amz-Signature"),
+		PayloadHash: UnsignedPayload,
+		Expires:     time.Duration(expires) * time.Second,
+		Presigned:   true,
+	}
+	if err := sig.parseCommon(req.Query.Get("X-Amz-Credential"), req.Query.Get("X-Amz-SignedHeaders"), req.Query.Get("X-Amz-Date")); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// parseCommon parses the credential scope, signed headers and request time
+func (s *SigV4) parseCommon(credential, signedHeaders, amzDate string) error {
+	// Credential is <access key>/<date>/<region>/<service>/aws4_request
+	parts := strings.Split(credential, "/")
+	if len(parts) != 5 || parts[0] == "" || parts[4] != "aws4_request" {
+		return fmt.Errorf("%w: invalid credential", ErrSigV4Malformed)
+	}
+	s.AccessKeyID, s.scopeDate, s.Region, s.Service = parts[0], parts[1], parts[2], parts[3]
+	if s.Service != "s3" {
+		return fmt.Errorf("%w: credential scope service must be s3", ErrSigV4Malformed)
+	}
+
+	if signedHeaders == "" {
+		return fmt.Errorf("%w: missing signed headers", ErrSigV4Malformed)
+	}
+	s.SignedHeaders = strings.Split(signedHeaders, ";")
+	hasHost := false
+	for _, name := range s.SignedHeaders {
+		hasHost = hasHost || name == "host"
+	}
+	if !hasHost {
+		return fmt.Errorf("%w: the host header must be signed", ErrSigV4Malformed)
+	}
+
+	if len(s.Signature) != sha256.Size*2 {
+		return fmt.Errorf("%w: invalid signature", ErrSigV4Malformed)
+	}
+
+	t, err := time.Parse(sigV4TimeFormat, amzDate)
+	if err != nil {
+		return fmt.Errorf("%w: invalid x-amz-date", ErrSigV4Malformed)
+	}
+	s.Time = t
+	if t.Format(sigV4DateFormat) != s.scopeDate {
+		return fmt.Errorf("%w: credential date doesn't match x-amz-date", ErrSigV4Malformed)
+	}
+	return nil
+}
+
+// Verify checks the signature of a request against the secret key of its access key
+func (s *SigV4) Verify(req SigV4Request, secretKey string, now time.Time) error {
+	if s.Presigned {
+		if now.Before(s.Time.Add(-sigV4MaxSkew)) {
+			return ErrSigV4Skewed
+		}
+		if now.After(s.Time.Add(s.Expires)) {
+			return ErrSigV4Expired
+		}
+	} else if d := now.Sub(s.Time); d > sigV4MaxSkew || d < -sigV4MaxSkew {
+		return ErrSigV4Skewed
+	}
+
+	expected := s.sign(req, secretKey)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(s.Signature))) != 1 {
+		return ErrSigV4Mismatch
+	}
+	return nil
+}
+
+// sign computes the signature of a request
+func (s *SigV4) sign(req SigV4Request, secretKey string) string {
+	scope := strings.Join([]string{s.scopeDate, s.Region, s.Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		SigV4Algorithm,
+		s.Time.Format(sigV4TimeFormat),
+		scope,
+		hexSHA256(s.canonicalRequest(req)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), s.scopeDate)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, s.Service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// canonicalRequest builds the canonical form of a request that is hashed and signed
+func (s *SigV4) canonicalRequest(req SigV4Request) string {
+	var headers strings.Builder
+	for _, name := range s.SignedHeaders {
+		headers.WriteString(name)
+		headers.WriteByte(':')
+		headers.WriteString(strings.Join(strings.Fields(req.Header(name)), " "))
+		headers.WriteByte('\n')
+	}
+
+	return strings.Join([]string{
+		req.Method,
+		sigV4Encode(req.Path, false),
+		canonicalQuery(req.Query, s.Presigned),
+		headers.String(),
+		strings.Join(s.SignedHeaders, ";"),
+		s.PayloadHash,
+	}, "\n")
+}
+
+// canonicalQuery sorts and encodes the query string, without the signature of a presigned URL
+func canonicalQuery(query url.Values, presigned bool) string {
+	params := make([]string, 0, len(query))
+	for name, values := range query {
+		if presigned && name == "X-Amz-Signature" {
+			continue
+		}
+		for _, value := range values {
+			params = append(params, sigV4Encode(name, true)+"="+sigV4Encode(value, true))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// sigV4Encode percent-encodes everything but unreserved characters, as SigV4 requires.
+// Slashes are kept in paths.
+func sigV4Encode(s string, encodeSlash bool) string {
+	const hexDigits = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hexDigits[c>>4])
+		b.WriteByte(hexDigits[c&0x0f])
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hexSHA256(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// payloadVerifier fails the last read of a body that doesn't match its signed hash
+type payloadVerifier struct {
+	reader   io.Reader
+	hash     hash.Hash
+	expected string
+}
+
+// NewPayloadVerifier checks a body against the hex SHA-256 it was signed with while it is
+// read, so the body doesn't have to be buffered. Reading the end of a mismatching body
+// returns ErrPayloadHashMismatch instead of io.EOF.
+func NewPayloadVerifier(r io.Reader, expectedHash string) io.Reader {
+	return &payloadVerifier{reader: r, hash: sha256.New(), expected: strings.ToLower(expectedHash)}
+}
+
+func (v *payloadVerifier) Read(p []byte) (int, error) {
+	n, err := v.reader.Read(p)
+	v.hash.Write(p[:n])
+	if errors.Is(err, io.EOF) && hex.EncodeToString(v.hash.Sum(nil)) != v.expected {
+		return n, ErrPayloadHashMismatch
+	}
+	return n, err
+}
@@ -0,0 +1,323 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// tusPruneInterval is how often expired resumable uploads are removed
+const tusPruneInterval = time.Hour
+
+var (
+	// ErrTUSUploadNotFound is returned for unknown or already finished uploads
+	ErrTUSUploadNotFound = errors.New("upload not found")
+	// ErrTUSOffsetMismatch is returned when another request advanced the upload first
+	ErrTUSOffsetMismatch = errors.New("upload offset changed")
+)
+
+// TUSUpload is a resumable upload created through the tus protocol. Its data is stored
+// through the provider's chunked upload session, one chunk of ChunkSize bytes at a time,
+// so Offset is a multiple of ChunkSize until the upload is complete.
+type TUSUpload struct {
+	ID           string
+	Bucket       string
+	Path         string
+	Length       int64
+	Offset       int64
+	ChunkSize    int64
+	ContentType  string
+	CacheControl string
+	Metadata     map[string]string
+	OwnerID      *string
+	Session      *ChunkedUploadSession
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+// NextChunk returns the index and size of the chunk starting at the current offset
+func (u *TUSUpload) NextChunk() (int, int64) {
+	return int(u.Offset / u.ChunkSize), min(u.ChunkSize, u.Length-u.Offset)
+}
+
+// Complete reports whether all bytes of the upload were stored
+func (u *TUSUpload) Complete() bool {
+	return u.Offset >= u.Length
+}
+
+// NewTUSUploadID generates an unguessable upload ID
+func NewTUSUploadID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate upload ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ParseTUSMetadata decodes an Upload-Metadata header: comma-separated pairs of a key and
+// an optional base64-encoded value
+func ParseTUSMetadata(header string) (map[string]string, error) {
+	metadata := map[string]string{}
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+	for pair := range strings.SplitSeq(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("empty metadata key")
+		}
+		if _, dup := metadata[key]; dup {
+			return nil, fmt.Errorf("duplicate metadata key %q", key)
+		}
+		value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("metadata value of %q is not base64", key)
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+// TUSStore persists resumable uploads, so they can be resumed through any instance
+type TUSStore struct {
+	db *pgxpool.Pool
+}
+
+// NewTUSStore creates a resumable upload store
+func NewTUSStore(db *pgxpool.Pool) *TUSStore {
+	return &TUSStore{db: db}
+}
+
+const tusUploadColumns = `id, bucket_id, path, upload_length, upload_offset, chunk_size,
+	COALESCE(content_type, ''), COALESCE(cache_control, ''), metadata, owner_id::text, session, created_at, expires_at`
+
+func scanTUSUpload(row pgx.Row) (*TUSUpload, error) {
+	var u TUSUpload
+	var metadata, session []byte
+	if err := row.Scan(&u.ID, &u.Bucket, &u.Path, &u.Length, &u.Offset, &u.ChunkSize,
+		&u.ContentType, &u.CacheControl, &metadata, &u.OwnerID, &session, &u.CreatedAt, &u.ExpiresAt); err != nil {
+		return nil, err
+	}
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &u.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to decode upload metadata: %w", err)
+		}
+	}
+	if err := json.Unmarshal(session, &u.Session); err != nil {
+		return nil, fmt.Errorf("failed to decode upload session: %w", err)
+	}
+	return &u, nil
+}
+
+// Create records a new upload
+func (s *TUSStore) Create(ctx context.Context, u *TUSUpload) error {
+	session, err := json.Marshal(u.Session)
+	if err != nil {
+		return fmt.Errorf("failed to encode upload session: %w", err)
+	}
+	var metadata []byte
+	if len(u.Metadata) > 0 {
+		if metadata, err = json.Marshal(u.Metadata); err != nil {
+			return fmt.Errorf("failed to encode upload metadata: %w", err)
+		}
+	}
+
+	err = s.db.QueryRow(ctx, `
+		INSERT INTO storage.tus_uploads (id, bucket_id, path, upload_length, chunk_size, content_type,
+			cache_control, metadata, owner_id, session, expires_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), $8, $9, $10, $11)
+		RETURNING created_at
+	`, u.ID, u.Bucket, u.Path, u.Length, u.ChunkSize, u.ContentType, u.CacheControl, metadata,
+		u.OwnerID, session, u.ExpiresAt).Scan(&u.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create upload: %w", err)
+	}
+	return nil
+}
+
+// Get returns an upload
+func (s *TUSStore) Get(ctx context.Context, id string) (*TUSUpload, error) {
+	u, err := scanTUSUpload(s.db.QueryRow(ctx,
+		`SELECT `+tusUploadColumns+` FROM storage.tus_uploads WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTUSUploadNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upload: %w", err)
+	}
+	return u, nil
+}
+
+// Advance records the offset and session of an upload that was at offset from. It
+// returns ErrTUSOffsetMismatch if the upload moved on in the meantime.
+func (s *TUSStore) Advance(ctx context.Context, u *TUSUpload, from int64) error {
+	session, err := json.Marshal(u.Session)
+	if err != nil {
+		return fmt.Errorf("failed to encode upload session: %w", err)
+	}
+
+	tag, err := s.db.Exec(ctx, `
+		UPDATE storage.tus_uploads
+		SET upload_offset = $2, session = $3, updated_at = NOW()
+		WHERE id = $1 AND upload_offset = $4
+	`, u.ID, u.Offset, session, from)
+	if err != nil {
+		return fmt.Errorf("failed to update upload: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTUSOffsetMismatch
+	}
+	return nil
+}
+
+// Delete removes an upload
+func (s *TUSStore) Delete(ctx context.Context, id string) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM storage.tus_uploads WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete upload: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTUSUploadNotFound
+	}
+	return nil
+}
+
+// Expired returns up to limit uploads that expired before now
+func (s *TUSStore) Expired(ctx context.Context, now time.Time, limit int) ([]TUSUpload, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT `+tusUploadColumns+` FROM storage.tus_uploads
+		WHERE expires_at < $1
+		ORDER BY expires_at
+		LIMIT $2
+	`, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired uploads: %w", err)
+	}
+	defer rows.Close()
+
+	var uploads []TUSUpload
+	for rows.Next() {
+		u, err := scanTUSUpload(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan upload: %w", err)
+		}
+		uploads = append(uploads, *u)
+	}
+	return uploads, rows.Err()
+}
+
+// TUSPruner aborts expired resumable uploads, releasing their stored chunks. It must run
+// on a single node (leader-elected).
+type TUSPruner struct {
+	store    *TUSStore
+	uploader ChunkedUploader
+	now      func() time.Time
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running bool
+	mu      sync.Mutex
+}
+
+// NewTUSPruner creates a pruner for expired resumable uploads
+func NewTUSPruner(store *TUSStore, uploader ChunkedUploader) *TUSPruner {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &TUSPruner{
+		store:    store,
+		uploader: uploader,
+		now:      time.Now,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start begins pruning expired uploads
+func (p *TUSPruner) Start() {
+	p.mu.Lock()
+	if p.running {
+		p.mu.Unlock()
+		return
+	}
+	p.running = true
+	if p.ctx.Err() != nil {
+		p.ctx, p.cancel = context.WithCancel(context.Background())
+	}
+	p.mu.Unlock()
+
+	p.wg.Add(1)
+	go p.run()
+
+	log.Info().Msg("Resumable upload pruner started")
+}
+
+// Stop stops pruning
+func (p *TUSPruner) Stop() {
+	p.mu.Lock()
+	if !p.running {
+		p.mu.Unlock()
+		return
+	}
+	p.running = false
+	p.mu.Unlock()
+
+	p.cancel()
+	p.wg.Wait()
+
+	log.Info().Msg("Resumable upload pruner stopped")
+}
+
+// run prunes on start and then every prune interval
+func (p *TUSPruner) run() {
+	defer p.wg.Done()
+
+	p.prune(p.ctx)
+
+	ticker := time.NewTicker(tusPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			p.prune(p.ctx)
+		}
+	}
+}
+
+// prune aborts the provider sessions of expired uploads and deletes them
+func (p *TUSPruner) prune(ctx context.Context) {
+	uploads, err := p.store.Expired(ctx, p.now(), 1000)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list expired resumable uploads")
+		return
+	}
+
+	pruned := 0
+	for _, u := range uploads {
+		if err := p.uploader.AbortChunkedUpload(ctx, u.Session); err != nil {
+			log.Warn().Err(err).Str("upload_id", u.ID).Msg("Failed to abort expired resumable upload")
+		}
+		if err := p.store.Delete(ctx, u.ID); err != nil && !errors.Is(err, ErrTUSUploadNotFound) {
+			log.Error().Err(err).Str("upload_id", u.ID).Msg("Failed to delete expired resumable upload")
+			continue
+		}
+		pruned++
+	}
+	if pruned > 0 {
+		log.Debug().Int("uploads", pruned).Msg("Pruned expired resumable uploads")
+	}
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTUSMetadata(t *testing.T) {
+	t.Run("decodes pairs", func(t *testing.T) {
+		metadata, err := ParseTUSMetadata("objectName ZG9jcy9yZXBvcnQucGRm, contentType YXBwbGljYXRpb24vcGRm,is_confidential")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"objectName":      "docs/report.pdf",
+			"contentType":     "application/pdf",
+			"is_confidential": "",
+		}, metadata)
+	})
+
+	t.Run("empty header", func(t *testing.T) {
+		metadata, err := ParseTUSMetadata("  ")
+		require.NoError(t, err)
+		assert.Empty(t, metadata)
+	})
+
+	t.Run("rejects invalid headers", func(t *testing.T) {
+		for _, header := range []string{
+			"objectName not-base64!",
+			"objectName YQ==,objectName Yg==",
+			"objectName YQ==,,contentType Yg==",
+		} {
+			_, err := ParseTUSMetadata(header)
+			assert.Error(t, err, header)
+		}
+	})
+}
+
+func TestTUSUpload_NextChunk(t *testing.T) {
+	u := &TUSUpload{Length: 25, ChunkSize: 10}
+
+	index, size := u.NextChunk()
+	assert.Equal(t, 0, index)
+	assert.Equal(t, int64(10), size)
+	assert.False(t, u.Complete())
+
+	u.Offset = 20
+	index, size = u.NextChunk()
+	assert.Equal(t, 2, index)
+	assert.Equal(t, int64(5), size)
+
+	u.Offset = 25
+	assert.True(t, u.Complete())
+}
+
+func TestNewTUSUploadID(t *testing.T) {
+	a, err := NewTUSUploadID()
+	require.NoError(t, err)
+	b, err := NewTUSUploadID()
+	require.NoError(t, err)
+
+	assert.Len(t, a, 32)
+	assert.NotEqual(t, a, b)
+}
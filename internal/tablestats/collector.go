@@ -0,0 +1,134 @@
+package tablestats
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+// collectInterval is how often the collector checks whether today's snapshot is due.
+// Snapshots are daily, so checking hourly only bounds how late after midnight one is taken.
+const collectInterval = time.Hour
+
+// Collector takes a snapshot of every user table once a day and prunes snapshots older
+// than the retention. It must run on a single node (leader-elected).
+type Collector struct {
+	cfg   *config.TableStatsConfig
+	store *Store
+	now   func() time.Time
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running bool
+	mu      sync.Mutex
+}
+
+// NewCollector creates a daily table size collector
+func NewCollector(cfg *config.TableStatsConfig, store *Store) *Collector {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Collector{
+		cfg:    cfg,
+		store:  store,
+		now:    time.Now,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start begins collecting daily snapshots
+func (c *Collector) Start() {
+	c.mu.Lock()
+	if c.running {
+		c.mu.Unlock()
+		return
+	}
+	c.running = true
+	if c.ctx.Err() != nil {
+		c.ctx, c.cancel = context.WithCancel(context.Background())
+	}
+	c.mu.Unlock()
+
+	c.wg.Add(1)
+	go c.run()
+
+	log.Info().Int("retention_days", c.cfg.RetentionDays).Msg("Table size collector started")
+}
+
+// Stop stops collecting snapshots
+func (c *Collector) Stop() {
+	c.mu.Lock()
+	if !c.running {
+		c.mu.Unlock()
+		return
+	}
+	c.running = false
+	c.mu.Unlock()
+
+	c.cancel()
+	c.wg.Wait()
+
+	log.Info().Msg("Table size collector stopped")
+}
+
+// run collects on start and then every collect interval
+func (c *Collector) run() {
+	defer c.wg.Done()
+
+	c.collect(c.ctx)
+
+	ticker := time.NewTicker(collectInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			c.collect(c.ctx)
+		}
+	}
+}
+
+// collect takes today's snapshot unless one exists and prunes expired snapshots
+func (c *Collector) collect(ctx context.Context) {
+	today := SnapshotDate(c.now())
+
+	taken, err := c.store.HasSnapshot(ctx, today)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to check table size snapshot")
+		return
+	}
+	if !taken {
+		tables, err := c.store.TakeSnapshot(ctx, today)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to snapshot table sizes")
+			return
+		}
+		log.Debug().Int64("tables", tables).Time("date", today).Msg("Snapshotted table sizes")
+	}
+
+	pruned, err := c.store.Prune(ctx, today.AddDate(0, 0, -c.cfg.RetentionDays))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to prune table size snapshots")
+		return
+	}
+	if pruned > 0 {
+		log.Debug().Int64("snapshots", pruned).Msg("Pruned expired table size snapshots")
+	}
+}
+
+// Snapshot takes today's snapshot now, replacing the one already taken today
+func (c *Collector) Snapshot(ctx context.Context) (int64, error) {
+	return c.store.TakeSnapshot(ctx, SnapshotDate(c.now()))
+}
+
+// SnapshotDate returns the UTC day a snapshot taken at t belongs to
+func SnapshotDate(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,117 @@
+package tablestats
+
+import (
+	"sort"
+	"time"
+)
+
+// Sort orders for reports
+const (
+	SortBySize   = "size"
+	SortByGrowth = "growth"
+	SortByBloat  = "bloat"
+)
+
+// Growth is how much a table grew between its oldest snapshot in the report window and now
+type Growth struct {
+	Since       time.Time `json:"since"`
+	Days        float64   `json:"days"`
+	RowsChange  int64     `json:"rows_change"`
+	BytesChange int64     `json:"bytes_change"`
+	RowsPerDay  float64   `json:"rows_per_day"`
+	BytesPerDay float64   `json:"bytes_per_day"`
+	// GrowthPercent is the size change relative to the oldest snapshot
+	GrowthPercent float64 `json:"growth_percent"`
+}
+
+// TableReport is a table's current size, bloat estimate and growth trend
+type TableReport struct {
+	TableSize
+	// BloatBytes estimates the heap space held by dead rows: table_bytes * dead / (live + dead)
+	BloatBytes int64   `json:"bloat_bytes"`
+	BloatRatio float64 `json:"bloat_ratio"`
+	// Growth is nil until the table has a snapshot older than today
+	Growth  *Growth    `json:"growth"`
+	History []Snapshot `json:"history"`
+}
+
+// Report is the table size dashboard
+type Report struct {
+	GeneratedAt   time.Time     `json:"generated_at"`
+	Days          int           `json:"days"`
+	DatabaseBytes int64         `json:"database_bytes"`
+	Tables        []TableReport `json:"tables"`
+}
+
+// BuildReport combines the current table sizes with their snapshots into a report sorted
+// by sortBy, keeping at most limit tables (0 keeps all)
+func BuildReport(current []TableSize, history map[string][]Snapshot, now time.Time, sortBy string, limit int) []TableReport {
+	tables := make([]TableReport, 0, len(current))
+	for _, size := range current {
+		report := TableReport{TableSize: size, History: history[tableKey(size.Schema, size.Table)]}
+		if report.History == nil {
+			report.History = []Snapshot{}
+		}
+		if total := size.RowEstimate + size.DeadRows; total > 0 && size.DeadRows > 0 {
+			report.BloatRatio = float64(size.DeadRows) / float64(total)
+			report.BloatBytes = int64(float64(size.TableBytes) * report.BloatRatio)
+		}
+		report.Growth = growthSince(report.History, size, now)
+		tables = append(tables, report)
+	}
+
+	sort.SliceStable(tables, func(i, j int) bool {
+		a, b := tables[i], tables[j]
+		switch sortBy {
+		case SortByGrowth:
+			if ga, gb := bytesPerDay(a), bytesPerDay(b); ga != gb {
+				return ga > gb
+			}
+		case SortByBloat:
+			if a.BloatBytes != b.BloatBytes {
+				return a.BloatBytes > b.BloatBytes
+			}
+		}
+		if a.TotalBytes != b.TotalBytes {
+			return a.TotalBytes > b.TotalBytes
+		}
+		return tableKey(a.Schema, a.Table) < tableKey(b.Schema, b.Table)
+	})
+
+	if limit > 0 && len(tables) > limit {
+		tables = tables[:limit]
+	}
+	return tables
+}
+
+// growthSince compares the current size with the oldest snapshot taken before today
+func growthSince(history []Snapshot, current TableSize, now time.Time) *Growth {
+	if len(history) == 0 {
+		return nil
+	}
+	oldest := history[0]
+	days := now.Sub(oldest.Date).Hours() / 24
+	if days < 1 {
+		return nil
+	}
+
+	growth := &Growth{
+		Since:       oldest.Date,
+		Days:        days,
+		RowsChange:  current.RowEstimate - oldest.RowEstimate,
+		BytesChange: current.TotalBytes - oldest.TotalBytes,
+	}
+	growth.RowsPerDay = float64(growth.RowsChange) / days
+	growth.BytesPerDay = float64(growth.BytesChange) / days
+	if oldest.TotalBytes > 0 {
+		growth.GrowthPercent = float64(growth.BytesChange) / float64(oldest.TotalBytes) * 100
+	}
+	return growth
+}
+
+func bytesPerDay(t TableReport) float64 {
+	if t.Growth == nil {
+		return 0
+	}
+	return t.Growth.BytesPerDay
+}
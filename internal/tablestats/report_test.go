@@ -0,0 +1,111 @@
+package tablestats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestBuildReport_BloatEstimate(t *testing.T) {
+	current := []TableSize{
+		{Schema: "public", Table: "events", RowEstimate: 750, DeadRows: 250, TableBytes: 4000, TotalBytes: 5000},
+		{Schema: "public", Table: "clean", RowEstimate: 100, TableBytes: 1000, TotalBytes: 1000},
+		{Schema: "public", Table: "empty"},
+	}
+
+	tables := BuildReport(current, nil, day("2026-03-10"), SortBySize, 0)
+	require.Len(t, tables, 3)
+
+	assert.Equal(t, "events", tables[0].Table)
+	assert.InDelta(t, 0.25, tables[0].BloatRatio, 0.0001)
+	assert.Equal(t, int64(1000), tables[0].BloatBytes)
+
+	assert.Equal(t, "clean", tables[1].Table)
+	assert.Zero(t, tables[1].BloatBytes)
+	assert.Zero(t, tables[2].BloatRatio)
+}
+
+func TestBuildReport_Growth(t *testing.T) {
+	now := day("2026-03-11").Add(6 * time.Hour)
+	current := []TableSize{
+		{Schema: "public", Table: "orders", RowEstimate: 2000, TotalBytes: 30000},
+		{Schema: "public", Table: "new_table", RowEstimate: 10, TotalBytes: 100},
+		{Schema: "public", Table: "today_only", RowEstimate: 10, TotalBytes: 100},
+	}
+	history := map[string][]Snapshot{
+		"public.orders": {
+			{Date: day("2026-03-01"), RowEstimate: 1000, TotalBytes: 10000},
+			{Date: day("2026-03-06"), RowEstimate: 1500, TotalBytes: 20000},
+		},
+		"public.today_only": {
+			{Date: day("2026-03-11"), RowEstimate: 10, TotalBytes: 100},
+		},
+	}
+
+	tables := BuildReport(current, history, now, SortBySize, 0)
+	require.Len(t, tables, 3)
+
+	orders := tables[0]
+	require.NotNil(t, orders.Growth)
+	assert.Equal(t, day("2026-03-01"), orders.Growth.Since)
+	assert.InDelta(t, 10.25, orders.Growth.Days, 0.0001)
+	assert.Equal(t, int64(1000), orders.Growth.RowsChange)
+	assert.Equal(t, int64(20000), orders.Growth.BytesChange)
+	assert.InDelta(t, 20000/10.25, orders.Growth.BytesPerDay, 0.01)
+	assert.InDelta(t, 200.0, orders.Growth.GrowthPercent, 0.0001)
+	assert.Len(t, orders.History, 2)
+
+	for _, table := range tables[1:] {
+		assert.Nil(t, table.Growth, table.Table)
+		assert.NotNil(t, table.History, table.Table)
+	}
+}
+
+func TestBuildReport_Sorting(t *testing.T) {
+	now := day("2026-03-11")
+	current := []TableSize{
+		{Schema: "public", Table: "big_static", TotalBytes: 100000},
+		{Schema: "public", Table: "fast_growing", TotalBytes: 50000},
+		{Schema: "public", Table: "bloated", RowEstimate: 10, DeadRows: 90, TableBytes: 20000, TotalBytes: 20000},
+		{Schema: "audit", Table: "log", TotalBytes: 20000},
+	}
+	history := map[string][]Snapshot{
+		"public.big_static":   {{Date: day("2026-03-01"), TotalBytes: 100000}},
+		"public.fast_growing": {{Date: day("2026-03-01"), TotalBytes: 10000}},
+	}
+
+	names := func(tables []TableReport) []string {
+		var out []string
+		for _, table := range tables {
+			out = append(out, table.Schema+"."+table.Table)
+		}
+		return out
+	}
+
+	assert.Equal(t,
+		[]string{"public.big_static", "public.fast_growing", "audit.log", "public.bloated"},
+		names(BuildReport(current, history, now, SortBySize, 0)))
+	assert.Equal(t,
+		[]string{"public.fast_growing", "public.big_static", "audit.log", "public.bloated"},
+		names(BuildReport(current, history, now, SortByGrowth, 0)))
+	assert.Equal(t,
+		[]string{"public.bloated", "public.big_static", "public.fast_growing"},
+		names(BuildReport(current, history, now, SortByBloat, 3)))
+}
+
+func TestSnapshotDate(t *testing.T) {
+	loc := time.FixedZone("UTC+10", 10*60*60)
+	// 2026-03-11 08:00 in UTC+10 is still March 10 in UTC
+	assert.Equal(t, day("2026-03-10"), SnapshotDate(time.Date(2026, 3, 11, 8, 0, 0, 0, loc)))
+	assert.Equal(t, day("2026-03-11"), SnapshotDate(time.Date(2026, 3, 11, 23, 59, 0, 0, time.UTC)))
+}
@@ -0,0 +1,167 @@
+// Package tablestats reports the row estimates and sizes of user tables and keeps daily
+// snapshots of them, so operators can see which tables grow fastest before the disk fills.
+package tablestats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// userTablesQuery selects the ordinary tables outside the system schemas. reltuples is -1
+// for tables that were never analyzed, which fall back to the live tuple counter.
+const userTablesQuery = `
+	SELECT n.nspname,
+	       c.relname,
+	       CASE WHEN c.reltuples < 0 THEN COALESCE(s.n_live_tup, 0) ELSE c.reltuples::bigint END,
+	       COALESCE(s.n_dead_tup, 0),
+	       pg_table_size(c.oid),
+	       pg_indexes_size(c.oid),
+	       pg_total_relation_size(c.oid)
+	FROM pg_class c
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	LEFT JOIN pg_stat_user_tables s ON s.relid = c.oid
+	WHERE c.relkind = 'r'
+	  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+	  AND n.nspname NOT LIKE 'pg_toast%'
+	  AND n.nspname NOT LIKE 'pg_temp%'
+`
+
+// TableSize is the current row estimate and size of a table
+type TableSize struct {
+	Schema      string `json:"schema"`
+	Table       string `json:"table"`
+	RowEstimate int64  `json:"row_estimate"`
+	DeadRows    int64  `json:"dead_rows"`
+	TableBytes  int64  `json:"table_bytes"` // Heap including TOAST
+	IndexBytes  int64  `json:"index_bytes"`
+	TotalBytes  int64  `json:"total_bytes"`
+
+	LastVacuum     *time.Time `json:"last_vacuum,omitempty"`
+	LastAutovacuum *time.Time `json:"last_autovacuum,omitempty"`
+}
+
+// Snapshot is a table's row estimate and size on a day
+type Snapshot struct {
+	Date        time.Time `json:"date"`
+	RowEstimate int64     `json:"row_estimate"`
+	TableBytes  int64     `json:"table_bytes"`
+	IndexBytes  int64     `json:"index_bytes"`
+	TotalBytes  int64     `json:"total_bytes"`
+}
+
+// Store reads table sizes from the catalog and persists daily snapshots
+type Store struct {
+	db *pgxpool.Pool
+}
+
+// NewStore creates a table stats store
+func NewStore(db *pgxpool.Pool) *Store {
+	return &Store{db: db}
+}
+
+// Current returns the current size of every user table, of a single schema if schema is not empty
+func (s *Store) Current(ctx context.Context, schema string) ([]TableSize, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT t.*, s.last_vacuum, s.last_autovacuum
+		FROM (`+userTablesQuery+`) AS t(schema_name, table_name, row_estimate, dead_rows, table_bytes, index_bytes, total_bytes)
+		LEFT JOIN pg_stat_user_tables s ON s.schemaname = t.schema_name AND s.relname = t.table_name
+		WHERE $1 = '' OR t.schema_name = $1
+	`, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table sizes: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []TableSize
+	for rows.Next() {
+		var t TableSize
+		if err := rows.Scan(&t.Schema, &t.Table, &t.RowEstimate, &t.DeadRows, &t.TableBytes, &t.IndexBytes, &t.TotalBytes,
+			&t.LastVacuum, &t.LastAutovacuum); err != nil {
+			return nil, fmt.Errorf("failed to scan table size: %w", err)
+		}
+		tables = append(tables, t)
+	}
+	return tables, rows.Err()
+}
+
+// DatabaseBytes returns the size of the current database
+func (s *Store) DatabaseBytes(ctx context.Context) (int64, error) {
+	var size int64
+	if err := s.db.QueryRow(ctx, `SELECT pg_database_size(current_database())`).Scan(&size); err != nil {
+		return 0, fmt.Errorf("failed to read database size: %w", err)
+	}
+	return size, nil
+}
+
+// TakeSnapshot records the size of every user table for date. Tables already recorded
+// for that day are overwritten, so a manual snapshot refreshes the day's values.
+func (s *Store) TakeSnapshot(ctx context.Context, date time.Time) (int64, error) {
+	tag, err := s.db.Exec(ctx, `
+		INSERT INTO api.table_size_snapshots
+			(snapshot_date, schema_name, table_name, row_estimate, dead_rows, table_bytes, index_bytes, total_bytes)
+		SELECT $1::date, t.* FROM (`+userTablesQuery+`) AS t
+		ON CONFLICT (snapshot_date, schema_name, table_name) DO UPDATE SET
+			row_estimate = EXCLUDED.row_estimate,
+			dead_rows = EXCLUDED.dead_rows,
+			table_bytes = EXCLUDED.table_bytes,
+			index_bytes = EXCLUDED.index_bytes,
+			total_bytes = EXCLUDED.total_bytes,
+			created_at = NOW()
+	`, date)
+	if err != nil {
+		return 0, fmt.Errorf("failed to snapshot table sizes: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// HasSnapshot reports whether a snapshot was taken on date
+func (s *Store) HasSnapshot(ctx context.Context, date time.Time) (bool, error) {
+	var exists bool
+	err := s.db.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM api.table_size_snapshots WHERE snapshot_date = $1::date)`, date).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check table size snapshot: %w", err)
+	}
+	return exists, nil
+}
+
+// History returns the snapshots taken on or after since by table key (schema.table), oldest first
+func (s *Store) History(ctx context.Context, since time.Time, schema string) (map[string][]Snapshot, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT schema_name, table_name, snapshot_date, row_estimate, table_bytes, index_bytes, total_bytes
+		FROM api.table_size_snapshots
+		WHERE snapshot_date >= $1::date AND ($2 = '' OR schema_name = $2)
+		ORDER BY schema_name, table_name, snapshot_date
+	`, since, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table size snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	history := map[string][]Snapshot{}
+	for rows.Next() {
+		var schemaName, table string
+		var snap Snapshot
+		if err := rows.Scan(&schemaName, &table, &snap.Date, &snap.RowEstimate, &snap.TableBytes, &snap.IndexBytes, &snap.TotalBytes); err != nil {
+			return nil, fmt.Errorf("failed to scan table size snapshot: %w", err)
+		}
+		key := tableKey(schemaName, table)
+		history[key] = append(history[key], snap)
+	}
+	return history, rows.Err()
+}
+
+// Prune deletes the snapshots taken before date
+func (s *Store) Prune(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := s.db.Exec(ctx, `DELETE FROM api.table_size_snapshots WHERE snapshot_date < $1::date`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune table size snapshots: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func tableKey(schema, table string) string {
+	return schema + "." + table
+}
@@ -0,0 +1,112 @@
+package tablesync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/database"
+)
+
+// MaxIngestRows bounds the rows of one ingest request
+const MaxIngestRows = 10000
+
+// ErrInvalidIngest is returned for ingest requests that can't be applied to this instance
+var ErrInvalidIngest = errors.New("invalid sync batch")
+
+// Ingester applies rows pushed by another instance
+type Ingester struct {
+	db *database.Connection
+}
+
+// NewIngester creates an ingester
+func NewIngester(db *database.Connection) *Ingester {
+	return &Ingester{db: db}
+}
+
+// Apply upserts a batch of rows into the table it names, resolving conflicts with the
+// request's policy. Only columns that exist on this instance are written, so the target may
+// have fewer columns than the source.
+func (i *Ingester) Apply(ctx context.Context, req *IngestRequest) (*IngestResult, error) {
+	schema, table, err := SplitTable(req.Table)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIngest, err)
+	}
+	switch req.ConflictPolicy {
+	case ConflictSourceWins, ConflictTargetWins:
+	case ConflictNewestWins:
+		if !identifierPattern.MatchString(req.CaptureColumn) {
+			return nil, fmt.Errorf("%w: newest_wins requires a capture_column", ErrInvalidIngest)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown conflict_policy %q", ErrInvalidIngest, req.ConflictPolicy)
+	}
+	if len(req.Rows) > MaxIngestRows {
+		return nil, fmt.Errorf("%w: at most %d rows per batch", ErrInvalidIngest, MaxIngestRows)
+	}
+	if len(req.Rows) == 0 {
+		return &IngestResult{}, nil
+	}
+
+	present, err := rowKeys(req.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIngest, err)
+	}
+	rows, err := json.Marshal(req.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIngest, err)
+	}
+
+	result := &IngestResult{}
+	err = database.WrapWithServiceRole(ctx, i.db, func(tx pgx.Tx) error {
+		shape, err := loadTableShape(ctx, tx, schema, table)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidIngest, err)
+		}
+
+		var columns []string
+		for _, col := range shape.Writable {
+			if present[col] {
+				columns = append(columns, col)
+			}
+		}
+		for _, col := range shape.PrimaryKey {
+			if !present[col] {
+				return fmt.Errorf("%w: rows are missing primary key column %s", ErrInvalidIngest, col)
+			}
+		}
+		if req.ConflictPolicy == ConflictNewestWins && (!present[req.CaptureColumn] || !shape.Columns[req.CaptureColumn]) {
+			return fmt.Errorf("%w: capture column %s must exist on both instances for newest_wins", ErrInvalidIngest, req.CaptureColumn)
+		}
+
+		sql := buildUpsertSQL(schema, table, columns, shape.PrimaryKey, req.ConflictPolicy, req.CaptureColumn)
+		tag, err := tx.Exec(ctx, sql, rows)
+		if err != nil {
+			return err
+		}
+		result.Applied = int(tag.RowsAffected())
+		result.Skipped = len(req.Rows) - result.Applied
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// rowKeys returns the union of the keys of rows, which must be JSON objects
+func rowKeys(rows []json.RawMessage) (map[string]bool, error) {
+	keys := make(map[string]bool)
+	for n, raw := range rows {
+		var row map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &row); err != nil || row == nil {
+			return nil, fmt.Errorf("row %d is not a JSON object", n)
+		}
+		for key := range row {
+			keys[key] = true
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,141 @@
+package tablesync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/query"
+)
+
+// tableShape is what syncing needs to know about a table on either side
+type tableShape struct {
+	PrimaryKey []string        // Primary key columns in key order
+	Writable   []string        // Columns that can be inserted (not generated), in table order
+	Columns    map[string]bool // All columns
+}
+
+// loadTableShape reads the primary key and columns of a table. Views are rejected.
+func loadTableShape(ctx context.Context, tx pgx.Tx, schema, table string) (*tableShape, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT a.attname, a.attgenerated = '', COALESCE(array_position(i.indkey::int2[], a.attnum), 0)
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid AND c.relkind IN ('r', 'p')
+		LEFT JOIN pg_index i ON i.indrelid = a.attrelid AND i.indisprimary
+		WHERE a.attrelid = to_regclass($1) AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum
+	`, query.QuoteQualifiedName(schema, table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shape := &tableShape{Columns: make(map[string]bool)}
+	keyPositions := make(map[int]string)
+	for rows.Next() {
+		var name string
+		var writable bool
+		var keyPosition int
+		if err := rows.Scan(&name, &writable, &keyPosition); err != nil {
+			return nil, err
+		}
+		shape.Columns[name] = true
+		if writable {
+			shape.Writable = append(shape.Writable, name)
+		}
+		if keyPosition > 0 {
+			keyPositions[keyPosition] = name
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(shape.Columns) == 0 {
+		return nil, fmt.Errorf("table %s.%s not found", schema, table)
+	}
+	if len(keyPositions) == 0 {
+		return nil, fmt.Errorf("table %s.%s has no primary key", schema, table)
+	}
+	for i := 1; i <= len(keyPositions); i++ {
+		shape.PrimaryKey = append(shape.PrimaryKey, keyPositions[i])
+	}
+	return shape, nil
+}
+
+// keyExpression returns the primary key of row alias t as a JSON array, the key of cursors
+func keyExpression(primaryKey []string) string {
+	columns := make([]string, len(primaryKey))
+	for i, col := range primaryKey {
+		columns[i] = "t." + query.QuoteIdentifier(col)
+	}
+	return "jsonb_build_array(" + strings.Join(columns, ", ") + ")"
+}
+
+// buildChangesQuery returns a query for the next changes after a cursor as (row, capture value, key),
+// ordered by capture value and key. Parameters: $1 capture delay in seconds, $2 cursor value (NULL
+// from the start), $3 cursor key, $4 row limit. Rows without a capture value are never synced.
+func buildChangesQuery(schema, table, captureColumn string, primaryKey []string) string {
+	capture := "t." + query.QuoteIdentifier(captureColumn)
+	key := keyExpression(primaryKey)
+	return fmt.Sprintf(`SELECT to_jsonb(t), %[1]s, %[2]s
+FROM %[3]s AS t
+WHERE %[1]s IS NOT NULL
+  AND %[1]s <= NOW() - make_interval(secs => $1)
+  AND ($2::timestamptz IS NULL OR (%[1]s >= $2::timestamptz AND (%[1]s, %[2]s) > ($2::timestamptz, $3::jsonb)))
+ORDER BY %[1]s, %[2]s
+LIMIT $4`, capture, key, query.QuoteQualifiedName(schema, table))
+}
+
+// buildPendingQuery returns a query for the capture value of the oldest change after a cursor,
+// NULL when there is none. Parameters: $1 cursor value (NULL from the start), $2 cursor key.
+func buildPendingQuery(schema, table, captureColumn string, primaryKey []string) string {
+	capture := "t." + query.QuoteIdentifier(captureColumn)
+	key := keyExpression(primaryKey)
+	return fmt.Sprintf(`SELECT MIN(%[1]s)
+FROM %[3]s AS t
+WHERE %[1]s IS NOT NULL
+  AND ($1::timestamptz IS NULL OR (%[1]s >= $1::timestamptz AND (%[1]s, %[2]s) > ($1::timestamptz, $2::jsonb)))`,
+		capture, key, query.QuoteQualifiedName(schema, table))
+}
+
+// buildUpsertSQL returns the statement applying ingested rows ($1, a JSON array) to a table.
+// columns are the columns present in the rows and writable on the target, and must include the
+// primary key. Identity values are kept from the source.
+func buildUpsertSQL(schema, table string, columns, primaryKey []string, policy, captureColumn string) string {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = query.QuoteIdentifier(col)
+	}
+	keys := make([]string, len(primaryKey))
+	isKey := make(map[string]bool, len(primaryKey))
+	for i, col := range primaryKey {
+		keys[i] = query.QuoteIdentifier(col)
+		isKey[col] = true
+	}
+	tableName := query.QuoteQualifiedName(schema, table)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "INSERT INTO %s AS t (%s) OVERRIDING SYSTEM VALUE\n", tableName, strings.Join(quoted, ", "))
+	fmt.Fprintf(&b, "SELECT %s FROM jsonb_populate_recordset(NULL::%s, $1::jsonb)\n", strings.Join(quoted, ", "), tableName)
+	fmt.Fprintf(&b, "ON CONFLICT (%s) ", strings.Join(keys, ", "))
+
+	var updates []string
+	for i, col := range columns {
+		if !isKey[col] {
+			updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", quoted[i], quoted[i]))
+		}
+	}
+	if policy == ConflictTargetWins || len(updates) == 0 {
+		b.WriteString("DO NOTHING")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "DO UPDATE SET %s", strings.Join(updates, ", "))
+	if policy == ConflictNewestWins {
+		capture := query.QuoteIdentifier(captureColumn)
+		fmt.Fprintf(&b, "\nWHERE t.%[1]s IS NULL OR t.%[1]s < EXCLUDED.%[1]s", capture)
+	}
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package tablesync
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildChangesQuery(t *testing.T) {
+	sql := buildChangesQuery("public", "orders", "updated_at", []string{"tenant_id", "id"})
+
+	assert.Contains(t, sql, `SELECT to_jsonb(t), t."updated_at", jsonb_build_array(t."tenant_id", t."id")`)
+	assert.Contains(t, sql, `FROM "public"."orders" AS t`)
+	assert.Contains(t, sql, `t."updated_at" <= NOW() - make_interval(secs => $1)`)
+	assert.Contains(t, sql, `(t."updated_at", jsonb_build_array(t."tenant_id", t."id")) > ($2::timestamptz, $3::jsonb)`)
+	assert.Contains(t, sql, `ORDER BY t."updated_at", jsonb_build_array(t."tenant_id", t."id")`)
+	assert.Contains(t, sql, "LIMIT $4")
+}
+
+func TestBuildPendingQuery(t *testing.T) {
+	sql := buildPendingQuery("public", "orders", "updated_at", []string{"id"})
+
+	assert.Contains(t, sql, `SELECT MIN(t."updated_at")`)
+	assert.Contains(t, sql, `(t."updated_at", jsonb_build_array(t."id")) > ($1::timestamptz, $2::jsonb)`)
+	assert.NotContains(t, sql, "make_interval", "changes inside the capture delay are pending too")
+}
+
+func TestBuildUpsertSQL(t *testing.T) {
+	columns := []string{"id", "status", "updated_at"}
+
+	t.Run("source wins updates every column", func(t *testing.T) {
+		sql := buildUpsertSQL("public", "orders", columns, []string{"id"}, ConflictSourceWins, "updated_at")
+		assert.Contains(t, sql, `INSERT INTO "public"."orders" AS t ("id", "status", "updated_at") OVERRIDING SYSTEM VALUE`)
+		assert.Contains(t, sql, `FROM jsonb_populate_recordset(NULL::"public"."orders", $1::jsonb)`)
+		assert.Contains(t, sql, `ON CONFLICT ("id") DO UPDATE SET "status" = EXCLUDED."status", "updated_at" = EXCLUDED."updated_at"`)
+		assert.NotContains(t, sql, "WHERE")
+	})
+
+	t.Run("target wins keeps existing rows", func(t *testing.T) {
+		sql := buildUpsertSQL("public", "orders", columns, []string{"id"}, ConflictTargetWins, "updated_at")
+		assert.Contains(t, sql, `ON CONFLICT ("id") DO NOTHING`)
+	})
+
+	t.Run("newest wins compares capture values", func(t *testing.T) {
+		sql := buildUpsertSQL("public", "orders", columns, []string{"id"}, ConflictNewestWins, "updated_at")
+		assert.Contains(t, sql, "DO UPDATE SET")
+		assert.Contains(t, sql, `WHERE t."updated_at" IS NULL OR t."updated_at" < EXCLUDED."updated_at"`)
+	})
+
+	t.Run("key-only tables have nothing to update", func(t *testing.T) {
+		sql := buildUpsertSQL("public", "tags", []string{"post_id", "tag"}, []string{"post_id", "tag"}, ConflictSourceWins, "")
+		assert.Contains(t, sql, `ON CONFLICT ("post_id", "tag") DO NOTHING`)
+	})
+}
+
+func TestRowKeys(t *testing.T) {
+	keys, err := rowKeys([]json.RawMessage{
+		json.RawMessage(`{"id": 1, "status": "open"}`),
+		json.RawMessage(`{"id": 2, "note": null}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"id": true, "status": true, "note": true}, keys)
+
+	_, err = rowKeys([]json.RawMessage{json.RawMessage(`[1, 2]`)})
+	assert.Error(t, err)
+	_, err = rowKeys([]json.RawMessage{json.RawMessage(`null`)})
+	assert.Error(t, err)
+}
@@ -0,0 +1,301 @@
+package tablesync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nimbleflux/fluxbase/internal/crypto"
+	"github.com/nimbleflux/fluxbase/internal/database"
+)
+
+// Store persists sync targets, encrypting their service keys, and the cursor of each table
+type Store struct {
+	db            *pgxpool.Pool
+	encryptionKey string
+	now           func() time.Time
+}
+
+// NewStore creates a sync target store
+func NewStore(db *pgxpool.Pool, encryptionKey string) *Store {
+	return &Store{db: db, encryptionKey: encryptionKey, now: time.Now}
+}
+
+const targetColumns = `id, name, url, service_key_encrypted, tables, capture_column, conflict_policy,
+	paused, created_at, updated_at`
+
+// scanTarget scans a row of targetColumns, decrypting the service key
+func (s *Store) scanTarget(row pgx.Row) (*Target, error) {
+	target := &Target{}
+	var serviceKey string
+	err := row.Scan(&target.ID, &target.Name, &target.URL, &serviceKey, &target.Tables, &target.CaptureColumn,
+		&target.ConflictPolicy, &target.Paused, &target.CreatedAt, &target.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if target.ServiceKey, err = crypto.Decrypt(serviceKey, s.encryptionKey); err != nil {
+		return nil, fmt.Errorf("failed to decrypt service key of sync target %s: %w", target.Name, err)
+	}
+	target.HasServiceKey = true
+	return target, nil
+}
+
+// List returns all sync targets with the status of their tables
+func (s *Store) List(ctx context.Context) ([]*Target, error) {
+	rows, err := s.db.Query(ctx, `SELECT `+targetColumns+` FROM api.sync_targets ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sync targets: %w", err)
+	}
+	defer rows.Close()
+
+	targets := []*Target{}
+	for rows.Next() {
+		target, err := s.scanTarget(rows)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list sync targets: %w", err)
+	}
+
+	for _, target := range targets {
+		if target.Status, err = s.tableStatus(ctx, target); err != nil {
+			return nil, err
+		}
+	}
+	return targets, nil
+}
+
+// Get returns a sync target with the status of its tables
+func (s *Store) Get(ctx context.Context, id string) (*Target, error) {
+	target, err := s.scanTarget(s.db.QueryRow(ctx, `SELECT `+targetColumns+` FROM api.sync_targets WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTargetNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync target: %w", err)
+	}
+	if target.Status, err = s.tableStatus(ctx, target); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// Create stores a new sync target. The target must already be validated.
+func (s *Store) Create(ctx context.Context, target *Target, createdBy *uuid.UUID) error {
+	serviceKey, err := crypto.Encrypt(target.ServiceKey, s.encryptionKey)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt service key: %w", err)
+	}
+
+	err = s.db.QueryRow(ctx, `
+		INSERT INTO api.sync_targets (name, url, service_key_encrypted, tables, capture_column, conflict_policy, paused, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`, target.Name, target.URL, serviceKey, target.Tables, target.CaptureColumn, target.ConflictPolicy, target.Paused, createdBy,
+	).Scan(&target.ID, &target.CreatedAt, &target.UpdatedAt)
+	if database.IsUniqueViolation(err) {
+		return ErrTargetExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create sync target: %w", err)
+	}
+	target.HasServiceKey = true
+	return nil
+}
+
+// Update replaces the settings of a sync target, keeping the stored service key when none is
+// given. The target must already be validated. Cursors of removed tables are dropped, and all
+// cursors restart when the capture column changes.
+func (s *Store) Update(ctx context.Context, target *Target) error {
+	var serviceKey *string
+	if target.ServiceKey != "" {
+		encrypted, err := crypto.Encrypt(target.ServiceKey, s.encryptionKey)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt service key: %w", err)
+		}
+		serviceKey = &encrypted
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update sync target: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var previousCaptureColumn string
+	err = tx.QueryRow(ctx, `SELECT capture_column FROM api.sync_targets WHERE id = $1 FOR UPDATE`, target.ID).Scan(&previousCaptureColumn)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrTargetNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update sync target: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		UPDATE api.sync_targets SET
+			name = $2, url = $3, service_key_encrypted = COALESCE($4, service_key_encrypted), tables = $5,
+			capture_column = $6, conflict_policy = $7, paused = $8, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`, target.ID, target.Name, target.URL, serviceKey, target.Tables, target.CaptureColumn, target.ConflictPolicy, target.Paused,
+	).Scan(&target.CreatedAt, &target.UpdatedAt)
+	if database.IsUniqueViolation(err) {
+		return ErrTargetExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update sync target: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM api.sync_table_state
+		WHERE target_id = $1 AND (NOT table_name = ANY($2) OR $3)
+	`, target.ID, target.Tables, previousCaptureColumn != target.CaptureColumn)
+	if err != nil {
+		return fmt.Errorf("failed to reset sync cursors: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to update sync target: %w", err)
+	}
+	target.HasServiceKey = true
+	return nil
+}
+
+// Delete removes a sync target and its cursors
+func (s *Store) Delete(ctx context.Context, id string) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM api.sync_targets WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete sync target: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTargetNotFound
+	}
+	return nil
+}
+
+// SetPaused pauses or resumes a sync target. A resumed target continues from its cursors.
+func (s *Store) SetPaused(ctx context.Context, id string, paused bool) error {
+	tag, err := s.db.Exec(ctx, `UPDATE api.sync_targets SET paused = $2, updated_at = NOW() WHERE id = $1`, id, paused)
+	if err != nil {
+		return fmt.Errorf("failed to update sync target: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTargetNotFound
+	}
+	return nil
+}
+
+// tableStatus returns the status of every table of target, including tables not synced yet
+func (s *Store) tableStatus(ctx context.Context, target *Target) ([]TableStatus, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT table_name, cursor_value, rows_synced, last_synced_at, pending_since, last_error, last_error_at
+		FROM api.sync_table_state
+		WHERE target_id = $1
+	`, target.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync status: %w", err)
+	}
+	defer rows.Close()
+
+	states := make(map[string]TableStatus)
+	for rows.Next() {
+		var status TableStatus
+		if err := rows.Scan(&status.Table, &status.CursorValue, &status.RowsSynced, &status.LastSyncedAt,
+			&status.PendingSince, &status.LastError, &status.LastErrorAt); err != nil {
+			return nil, fmt.Errorf("failed to get sync status: %w", err)
+		}
+		states[status.Table] = status
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get sync status: %w", err)
+	}
+
+	now := s.now()
+	statuses := make([]TableStatus, 0, len(target.Tables))
+	for _, table := range target.Tables {
+		status, ok := states[table]
+		if !ok {
+			status = TableStatus{Table: table}
+		}
+		status.LagSeconds = status.lag(now).Seconds()
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// cursor is the position of a table's sync: the capture value and primary key of the last row sent
+type cursor struct {
+	Value *time.Time
+	Key   json.RawMessage
+}
+
+// getCursor returns the cursor of a table, empty before the first sync
+func (s *Store) getCursor(ctx context.Context, targetID, table string) (cursor, error) {
+	var c cursor
+	err := s.db.QueryRow(ctx, `
+		SELECT cursor_value, cursor_key FROM api.sync_table_state WHERE target_id = $1 AND table_name = $2
+	`, targetID, table).Scan(&c.Value, &c.Key)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return cursor{}, nil
+	}
+	return c, err
+}
+
+// recordProgress moves the cursor of a table after a batch was accepted by the target
+func (s *Store) recordProgress(ctx context.Context, targetID, table string, c cursor, rows int, pendingSince *time.Time) error {
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO api.sync_table_state (target_id, table_name, cursor_value, cursor_key, rows_synced, last_synced_at, pending_since)
+		VALUES ($1, $2, $3, $4, $5, NOW(), $6)
+		ON CONFLICT (target_id, table_name) DO UPDATE SET
+			cursor_value = EXCLUDED.cursor_value,
+			cursor_key = EXCLUDED.cursor_key,
+			rows_synced = api.sync_table_state.rows_synced + EXCLUDED.rows_synced,
+			last_synced_at = EXCLUDED.last_synced_at,
+			pending_since = EXCLUDED.pending_since,
+			last_error = NULL,
+			last_error_at = NULL,
+			updated_at = NOW()
+	`, targetID, table, c.Value, c.Key, rows, pendingSince)
+	return err
+}
+
+// recordFailure records why a table could not be synced, keeping its cursor
+func (s *Store) recordFailure(ctx context.Context, targetID, table string, syncErr error, pendingSince *time.Time) error {
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO api.sync_table_state (target_id, table_name, pending_since, last_error, last_error_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (target_id, table_name) DO UPDATE SET
+			pending_since = COALESCE(EXCLUDED.pending_since, api.sync_table_state.pending_since),
+			last_error = EXCLUDED.last_error,
+			last_error_at = EXCLUDED.last_error_at,
+			updated_at = NOW()
+	`, targetID, table, pendingSince, syncErr.Error())
+	return err
+}
+
+// activeTargets returns the targets that are not paused
+func (s *Store) activeTargets(ctx context.Context) ([]*Target, error) {
+	rows, err := s.db.Query(ctx, `SELECT `+targetColumns+` FROM api.sync_targets WHERE NOT paused ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var targets []*Target
+	for rows.Next() {
+		target, err := s.scanTarget(rows)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+	return targets, rows.Err()
+}
@@ -0,0 +1,283 @@
+package tablesync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/nimbleflux/fluxbase/internal/egress"
+	"github.com/nimbleflux/fluxbase/internal/observability"
+	"github.com/rs/zerolog/log"
+)
+
+// maxBatchesPerRun bounds the batches sent for one table per run, so a large backlog of one
+// table doesn't hold up the other tables
+const maxBatchesPerRun = 20
+
+// maxIngestResponseBytes bounds the response read from a target
+const maxIngestResponseBytes = 1 << 20
+
+// Syncer periodically sends the changes of synced tables to their targets.
+// It must run on a single node (leader-elected).
+type Syncer struct {
+	cfg        *config.TableSyncConfig
+	db         *database.Connection
+	store      *Store
+	instance   string
+	httpClient *http.Client
+	metrics    *observability.Metrics
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running bool
+	mu      sync.Mutex
+}
+
+// NewSyncer creates a table syncer. instance identifies this deployment to targets; metrics may be nil.
+func NewSyncer(cfg *config.TableSyncConfig, db *database.Connection, store *Store, instance string, metrics *observability.Metrics) *Syncer {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Syncer{
+		cfg:        cfg,
+		db:         db,
+		store:      store,
+		instance:   instance,
+		httpClient: egress.NewHTTPClient(egress.SourceAdmin, cfg.RequestTimeout),
+		metrics:    metrics,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Start begins periodic syncs
+func (s *Syncer) Start() {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = true
+	if s.ctx.Err() != nil {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+	}
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.run()
+
+	log.Info().
+		Dur("interval", s.cfg.Interval).
+		Int("batch_size", s.cfg.BatchSize).
+		Msg("Table syncer started")
+}
+
+// Stop stops periodic syncs
+func (s *Syncer) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	s.mu.Unlock()
+
+	s.cancel()
+	s.wg.Wait()
+
+	log.Info().Msg("Table syncer stopped")
+}
+
+// run syncs all targets on start and then every interval
+func (s *Syncer) run() {
+	defer s.wg.Done()
+
+	s.syncAll(s.ctx)
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.syncAll(s.ctx)
+		}
+	}
+}
+
+// syncAll syncs every table of every target that is not paused. A failing table doesn't stop
+// the others; it is retried from its cursor on the next run.
+func (s *Syncer) syncAll(ctx context.Context) {
+	targets, err := s.store.activeTargets(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load sync targets")
+		return
+	}
+
+	for _, target := range targets {
+		for _, table := range target.Tables {
+			if ctx.Err() != nil {
+				return
+			}
+			if err := s.syncTable(ctx, target, table); err != nil {
+				log.Warn().Err(err).
+					Str("target", target.Name).
+					Str("table", table).
+					Msg("Table sync failed, will retry")
+			}
+		}
+	}
+}
+
+// syncTable sends the changes of one table to a target in batches until it is caught up
+func (s *Syncer) syncTable(ctx context.Context, target *Target, table string) error {
+	pos, err := s.store.getCursor(ctx, target.ID, table)
+	if err != nil {
+		return fmt.Errorf("failed to read sync cursor: %w", err)
+	}
+
+	for range maxBatchesPerRun {
+		batch, err := s.readChanges(ctx, target, table, pos)
+		if err == nil && len(batch.rows) > 0 {
+			_, err = s.push(ctx, target, &IngestRequest{
+				Source:         s.instance,
+				Table:          table,
+				CaptureColumn:  target.CaptureColumn,
+				ConflictPolicy: target.ConflictPolicy,
+				Rows:           batch.rows,
+			})
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				if recordErr := s.store.recordFailure(ctx, target.ID, table, err, batch.oldest); recordErr != nil {
+					log.Error().Err(recordErr).Msg("Failed to record table sync failure")
+				}
+			}
+			return err
+		}
+
+		pos = batch.next
+		if err := s.store.recordProgress(ctx, target.ID, table, pos, len(batch.rows), batch.pendingSince); err != nil {
+			return fmt.Errorf("failed to record sync progress: %w", err)
+		}
+		if s.metrics != nil {
+			lag := (&TableStatus{PendingSince: batch.pendingSince}).lag(time.Now())
+			s.metrics.RecordTableSync(target.Name, table, len(batch.rows), lag)
+		}
+
+		if len(batch.rows) < s.cfg.BatchSize {
+			return nil
+		}
+	}
+	return nil
+}
+
+// changeBatch is a batch of changes read after a cursor
+type changeBatch struct {
+	rows         []json.RawMessage
+	oldest       *time.Time // Capture value of the first row of the batch
+	next         cursor     // Cursor after the batch
+	pendingSince *time.Time // Capture value of the oldest change after the batch, nil when caught up
+}
+
+// readChanges reads the next batch of changes of a table after pos
+func (s *Syncer) readChanges(ctx context.Context, target *Target, table string, pos cursor) (*changeBatch, error) {
+	schema, name, err := SplitTable(table)
+	if err != nil {
+		return &changeBatch{}, err
+	}
+
+	batch := &changeBatch{next: pos}
+	err = database.WrapWithServiceRole(ctx, s.db, func(tx pgx.Tx) error {
+		// to_jsonb renders timestamptz in the session time zone, keep it independent of the server
+		if _, err := tx.Exec(ctx, "SET LOCAL TimeZone = 'UTC'"); err != nil {
+			return err
+		}
+		shape, err := loadTableShape(ctx, tx, schema, name)
+		if err != nil {
+			return err
+		}
+		if !shape.Columns[target.CaptureColumn] {
+			return fmt.Errorf("table %s has no capture column %s", table, target.CaptureColumn)
+		}
+
+		rows, err := tx.Query(ctx, buildChangesQuery(schema, name, target.CaptureColumn, shape.PrimaryKey),
+			s.cfg.CaptureDelay.Seconds(), pos.Value, pos.Key, s.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var row, key json.RawMessage
+			var captured time.Time
+			if err := rows.Scan(&row, &captured, &key); err != nil {
+				rows.Close()
+				return err
+			}
+			if batch.oldest == nil {
+				batch.oldest = &captured
+			}
+			batch.rows = append(batch.rows, row)
+			batch.next = cursor{Value: &captured, Key: key}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		return tx.QueryRow(ctx, buildPendingQuery(schema, name, target.CaptureColumn, shape.PrimaryKey),
+			batch.next.Value, batch.next.Key).Scan(&batch.pendingSince)
+	})
+	if err != nil {
+		return &changeBatch{}, err
+	}
+	return batch, nil
+}
+
+// push sends a batch of rows to the ingest endpoint of a target
+func (s *Syncer) push(ctx context.Context, target *Target, batch *IngestRequest) (*IngestResult, error) {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL+IngestPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Service-Key", target.ServiceKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody := io.LimitReader(resp.Body, maxIngestResponseBytes)
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(respBody).Decode(&errResp) == nil && errResp.Error != "" {
+			return nil, fmt.Errorf("target returned %d: %s", resp.StatusCode, errResp.Error)
+		}
+		return nil, fmt.Errorf("target returned %d", resp.StatusCode)
+	}
+
+	var result IngestResult
+	if err := json.NewDecoder(respBody).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid ingest response: %w", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,60 @@
+package tablesync
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nimbleflux/fluxbase/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSyncer() *Syncer {
+	return NewSyncer(&config.TableSyncConfig{
+		Interval:       time.Second,
+		BatchSize:      100,
+		RequestTimeout: 5 * time.Second,
+	}, nil, nil, "https://edge-1.example.com", nil)
+}
+
+func TestSyncer_Push(t *testing.T) {
+	t.Run("sends the batch with the service key", func(t *testing.T) {
+		var received IngestRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, IngestPath, r.URL.Path)
+			assert.Equal(t, "secret-key", r.Header.Get("X-Service-Key"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			_ = json.NewEncoder(w).Encode(IngestResult{Applied: 1, Skipped: 1})
+		}))
+		defer server.Close()
+
+		target := &Target{URL: server.URL, ServiceKey: "secret-key"}
+		result, err := testSyncer().push(context.Background(), target, &IngestRequest{
+			Source:         "https://edge-1.example.com",
+			Table:          "public.orders",
+			CaptureColumn:  "updated_at",
+			ConflictPolicy: ConflictNewestWins,
+			Rows:           []json.RawMessage{json.RawMessage(`{"id":9007199254740993}`), json.RawMessage(`{"id":2}`)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &IngestResult{Applied: 1, Skipped: 1}, result)
+		assert.Equal(t, "public.orders", received.Table)
+		assert.JSONEq(t, `{"id":9007199254740993}`, string(received.Rows[0]), "ids keep their precision")
+	})
+
+	t.Run("reports the target's error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":"Table sync ingest is disabled"}`))
+		}))
+		defer server.Close()
+
+		_, err := testSyncer().push(context.Background(), &Target{URL: server.URL}, &IngestRequest{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "target returned 403: Table sync ingest is disabled")
+	})
+}
@@ -0,0 +1,162 @@
+// Package tablesync pushes selected tables one way to another Fluxbase instance.
+//
+// Changes are captured with a timestamp column (updated_at by default): each table keeps a
+// cursor per target holding the capture value and primary key of the last row sent, and rows
+// after the cursor are sent in batches to the target's ingest endpoint, which upserts them
+// according to the target's conflict policy. Deletes are not captured; use soft deletes for
+// tables whose deletions must reach the target.
+package tablesync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Conflict policies decide what happens when a synced row already exists on the target
+const (
+	ConflictSourceWins = "source_wins" // The synced row replaces the target row
+	ConflictTargetWins = "target_wins" // The target row is kept
+	ConflictNewestWins = "newest_wins" // The row with the later capture value is kept
+)
+
+// DefaultCaptureColumn is the capture column of targets that don't set one
+const DefaultCaptureColumn = "updated_at"
+
+// IngestPath is the path of the ingest endpoint, relative to the target's base URL
+const IngestPath = "/api/v1/admin/sync/ingest"
+
+// maxTablesPerTarget bounds how many tables a target syncs
+const maxTablesPerTarget = 100
+
+var (
+	// ErrTargetNotFound is returned for unknown sync targets
+	ErrTargetNotFound = errors.New("sync target not found")
+	// ErrTargetExists is returned when a sync target with the same name exists
+	ErrTargetExists = errors.New("a sync target with this name already exists")
+)
+
+var identifierPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Target is an instance that selected tables are synced to
+type Target struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	URL            string    `json:"url"`
+	ServiceKey     string    `json:"service_key,omitempty"` // Write-only, never returned
+	HasServiceKey  bool      `json:"has_service_key"`
+	Tables         []string  `json:"tables"`
+	CaptureColumn  string    `json:"capture_column"`
+	ConflictPolicy string    `json:"conflict_policy"`
+	Paused         bool      `json:"paused"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	// Status of each synced table, filled by Store.Get and Store.List
+	Status []TableStatus `json:"status,omitempty"`
+}
+
+// TableStatus is the sync progress of one table of a target
+type TableStatus struct {
+	Table        string     `json:"table"`
+	CursorValue  *time.Time `json:"cursor_value,omitempty"`
+	RowsSynced   int64      `json:"rows_synced"`
+	LastSyncedAt *time.Time `json:"last_synced_at,omitempty"`
+	PendingSince *time.Time `json:"pending_since,omitempty"`
+	LagSeconds   float64    `json:"lag_seconds"`
+	LastError    *string    `json:"last_error,omitempty"`
+	LastErrorAt  *time.Time `json:"last_error_at,omitempty"`
+}
+
+// lag returns how far the table is behind at now: the age of the oldest change not sent yet
+func (s *TableStatus) lag(now time.Time) time.Duration {
+	if s.PendingSince == nil || s.PendingSince.After(now) {
+		return 0
+	}
+	return now.Sub(*s.PendingSince)
+}
+
+// Validate normalizes a target and checks it. requireKey is false for updates that keep the
+// stored service key.
+func (t *Target) Validate(requireKey bool) error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	t.URL = strings.TrimRight(strings.TrimSpace(t.URL), "/")
+	base, err := url.Parse(t.URL)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return fmt.Errorf("url must be an http or https URL")
+	}
+	if requireKey && t.ServiceKey == "" {
+		return fmt.Errorf("service_key is required")
+	}
+
+	if len(t.Tables) == 0 {
+		return fmt.Errorf("at least one table is required")
+	}
+	if len(t.Tables) > maxTablesPerTarget {
+		return fmt.Errorf("at most %d tables can be synced to a target", maxTablesPerTarget)
+	}
+	seen := make(map[string]bool, len(t.Tables))
+	for i, table := range t.Tables {
+		schema, name, err := SplitTable(table)
+		if err != nil {
+			return err
+		}
+		t.Tables[i] = schema + "." + name
+		if seen[t.Tables[i]] {
+			return fmt.Errorf("table %s is listed twice", t.Tables[i])
+		}
+		seen[t.Tables[i]] = true
+	}
+
+	if t.CaptureColumn == "" {
+		t.CaptureColumn = DefaultCaptureColumn
+	}
+	if !identifierPattern.MatchString(t.CaptureColumn) {
+		return fmt.Errorf("invalid capture_column %q", t.CaptureColumn)
+	}
+
+	if t.ConflictPolicy == "" {
+		t.ConflictPolicy = ConflictSourceWins
+	}
+	switch t.ConflictPolicy {
+	case ConflictSourceWins, ConflictTargetWins, ConflictNewestWins:
+	default:
+		return fmt.Errorf("conflict_policy must be one of %s, %s or %s", ConflictSourceWins, ConflictTargetWins, ConflictNewestWins)
+	}
+	return nil
+}
+
+// SplitTable splits a "schema.table" name, defaulting to the public schema
+func SplitTable(table string) (string, string, error) {
+	schema, name, ok := strings.Cut(strings.TrimSpace(table), ".")
+	if !ok {
+		schema, name = "public", schema
+	}
+	if !identifierPattern.MatchString(schema) || !identifierPattern.MatchString(name) {
+		return "", "", fmt.Errorf("invalid table %q, expected schema.table", table)
+	}
+	return schema, name, nil
+}
+
+// IngestRequest is a batch of rows pushed to a target
+type IngestRequest struct {
+	Source         string            `json:"source"` // Base URL of the sending instance
+	Table          string            `json:"table"`  // "schema.table"
+	CaptureColumn  string            `json:"capture_column"`
+	ConflictPolicy string            `json:"conflict_policy"`
+	Rows           []json.RawMessage `json:"rows"` // Rows as JSON objects (to_jsonb), kept raw so numbers keep their precision
+}
+
+// IngestResult reports how a batch was applied
+type IngestResult struct {
+	Applied int `json:"applied"` // Rows inserted or updated
+	Skipped int `json:"skipped"` // Rows left alone by the conflict policy
+}
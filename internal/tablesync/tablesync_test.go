@@ -0,0 +1,96 @@
+package tablesync
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTarget_Validate(t *testing.T) {
+	valid := func() Target {
+		return Target{
+			Name:       "central",
+			URL:        "https://central.example.com/",
+			ServiceKey: "service-key",
+			Tables:     []string{"public.orders", "readings"},
+		}
+	}
+
+	t.Run("normalizes and applies defaults", func(t *testing.T) {
+		target := valid()
+		require.NoError(t, target.Validate(true))
+		assert.Equal(t, "https://central.example.com", target.URL)
+		assert.Equal(t, []string{"public.orders", "public.readings"}, target.Tables)
+		assert.Equal(t, DefaultCaptureColumn, target.CaptureColumn)
+		assert.Equal(t, ConflictSourceWins, target.ConflictPolicy)
+	})
+
+	t.Run("updates may keep the stored service key", func(t *testing.T) {
+		target := valid()
+		target.ServiceKey = ""
+		require.NoError(t, target.Validate(false))
+	})
+
+	tests := []struct {
+		name    string
+		modify  func(*Target)
+		wantErr string
+	}{
+		{"rejects missing name", func(t *Target) { t.Name = " " }, "name is required"},
+		{"rejects non-http url", func(t *Target) { t.URL = "ftp://central" }, "http or https"},
+		{"rejects url without host", func(t *Target) { t.URL = "https://" }, "http or https"},
+		{"rejects missing service key", func(t *Target) { t.ServiceKey = "" }, "service_key is required"},
+		{"rejects no tables", func(t *Target) { t.Tables = nil }, "at least one table"},
+		{"rejects invalid table", func(t *Target) { t.Tables = []string{"public.orders; DROP"} }, "invalid table"},
+		{"rejects duplicate tables", func(t *Target) { t.Tables = []string{"orders", "public.orders"} }, "listed twice"},
+		{"rejects invalid capture column", func(t *Target) { t.CaptureColumn = "updated at" }, "capture_column"},
+		{"rejects unknown conflict policy", func(t *Target) { t.ConflictPolicy = "merge" }, "conflict_policy"},
+		{"rejects too many tables", func(t *Target) {
+			t.Tables = nil
+			for i := 0; i <= maxTablesPerTarget; i++ {
+				t.Tables = append(t.Tables, fmt.Sprintf("public.t%d", i))
+			}
+		}, "at most"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := valid()
+			tt.modify(&target)
+			err := target.Validate(true)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestSplitTable(t *testing.T) {
+	schema, table, err := SplitTable("sensors.readings")
+	require.NoError(t, err)
+	assert.Equal(t, "sensors", schema)
+	assert.Equal(t, "readings", table)
+
+	schema, table, err = SplitTable("readings")
+	require.NoError(t, err)
+	assert.Equal(t, "public", schema)
+	assert.Equal(t, "readings", table)
+
+	for _, invalid := range []string{"", "a.b.c", `public."orders"`, "public."} {
+		_, _, err := SplitTable(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestTableStatus_Lag(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	assert.Zero(t, (&TableStatus{}).lag(now), "caught up")
+
+	pending := now.Add(-90 * time.Second)
+	assert.Equal(t, 90*time.Second, (&TableStatus{PendingSince: &pending}).lag(now))
+
+	future := now.Add(time.Minute)
+	assert.Zero(t, (&TableStatus{PendingSince: &future}).lag(now), "clock skew")
+}
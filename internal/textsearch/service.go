@@ -0,0 +1,274 @@
+package textsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/nimbleflux/fluxbase/internal/query"
+)
+
+// textColumnTypes are the column types text search can be configured on
+var textColumnTypes = map[string]bool{
+	"text": true, "character varying": true, "character": true, "citext": true, "name": true,
+}
+
+// Service stores text search configurations and manages their generated tsvector columns
+type Service struct {
+	db          *database.Connection
+	schemaCache *database.SchemaCache // may be nil
+}
+
+// NewService creates a text search configuration service. schemaCache is refreshed after
+// every change so REST filters use the new configuration right away; it may be nil.
+func NewService(db *database.Connection, schemaCache *database.SchemaCache) *Service {
+	return &Service{db: db, schemaCache: schemaCache}
+}
+
+const configColumns = `id, schema_name || '.' || table_name, language, columns, COALESCE(vector_column, ''), created_at, updated_at`
+
+func scanConfig(row pgx.Row) (*Config, error) {
+	cfg := &Config{}
+	var columns []byte
+	if err := row.Scan(&cfg.ID, &cfg.Table, &cfg.Language, &columns, &cfg.VectorColumn, &cfg.CreatedAt, &cfg.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(columns, &cfg.Columns); err != nil {
+		return nil, fmt.Errorf("invalid columns of text search configuration of %s: %w", cfg.Table, err)
+	}
+	return cfg, nil
+}
+
+// List returns all text search configurations
+func (s *Service) List(ctx context.Context) ([]*Config, error) {
+	rows, err := s.db.Pool().Query(ctx, `SELECT `+configColumns+` FROM api.text_search_configs ORDER BY schema_name, table_name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list text search configurations: %w", err)
+	}
+	defer rows.Close()
+
+	configs := []*Config{}
+	for rows.Next() {
+		cfg, err := scanConfig(rows)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, cfg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list text search configurations: %w", err)
+	}
+	return configs, nil
+}
+
+// Get returns a text search configuration
+func (s *Service) Get(ctx context.Context, id string) (*Config, error) {
+	cfg, err := scanConfig(s.db.Pool().QueryRow(ctx, `SELECT `+configColumns+` FROM api.text_search_configs WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrConfigNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get text search configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// Create stores a validated configuration and adds its generated tsvector column
+func (s *Service) Create(ctx context.Context, cfg *Config, createdBy *uuid.UUID) error {
+	if err := s.checkTable(ctx, cfg, ""); err != nil {
+		return err
+	}
+	columns, err := json.Marshal(cfg.Columns)
+	if err != nil {
+		return err
+	}
+	schema, table, _ := cfg.TableName()
+
+	err = s.db.ExecuteWithAdminRole(ctx, func(conn *pgx.Conn) error {
+		return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			err := tx.QueryRow(ctx, `
+				INSERT INTO api.text_search_configs (schema_name, table_name, language, columns, vector_column, created_by)
+				VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+				RETURNING id, created_at, updated_at
+			`, schema, table, cfg.Language, columns, cfg.VectorColumn, createdBy,
+			).Scan(&cfg.ID, &cfg.CreatedAt, &cfg.UpdatedAt)
+			if err != nil {
+				return err
+			}
+			return addVector(ctx, tx, cfg)
+		})
+	})
+	if err != nil {
+		return configError(err, "create")
+	}
+
+	s.refreshSchemaCache(ctx)
+	return nil
+}
+
+// Update replaces a configuration. Its generated tsvector column is dropped and added again,
+// which rewrites the table.
+func (s *Service) Update(ctx context.Context, cfg *Config) error {
+	previous, err := s.Get(ctx, cfg.ID)
+	if err != nil {
+		return err
+	}
+	if previous.Table != cfg.Table {
+		return fmt.Errorf("%w: the table of a configuration can't be changed", ErrInvalidConfig)
+	}
+	if err := s.checkTable(ctx, cfg, previous.VectorColumn); err != nil {
+		return err
+	}
+	columns, err := json.Marshal(cfg.Columns)
+	if err != nil {
+		return err
+	}
+	schema, table, _ := cfg.TableName()
+
+	err = s.db.ExecuteWithAdminRole(ctx, func(conn *pgx.Conn) error {
+		return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			err := tx.QueryRow(ctx, `
+				UPDATE api.text_search_configs SET
+					language = $2, columns = $3, vector_column = NULLIF($4, ''), updated_at = NOW()
+				WHERE id = $1
+				RETURNING created_at, updated_at
+			`, cfg.ID, cfg.Language, columns, cfg.VectorColumn,
+			).Scan(&cfg.CreatedAt, &cfg.UpdatedAt)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrConfigNotFound
+			}
+			if err != nil {
+				return err
+			}
+
+			if previous.VectorColumn != "" {
+				if _, err := tx.Exec(ctx, dropVectorSQL(schema, table, previous.VectorColumn)); err != nil {
+					return err
+				}
+			}
+			return addVector(ctx, tx, cfg)
+		})
+	})
+	if err != nil {
+		return configError(err, "update")
+	}
+
+	s.refreshSchemaCache(ctx)
+	return nil
+}
+
+// Delete removes a configuration and drops its generated tsvector column
+func (s *Service) Delete(ctx context.Context, id string) error {
+	err := s.db.ExecuteWithAdminRole(ctx, func(conn *pgx.Conn) error {
+		return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			var schema, table, vectorColumn string
+			err := tx.QueryRow(ctx, `
+				DELETE FROM api.text_search_configs WHERE id = $1
+				RETURNING schema_name, table_name, COALESCE(vector_column, '')
+			`, id).Scan(&schema, &table, &vectorColumn)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrConfigNotFound
+			}
+			if err != nil || vectorColumn == "" {
+				return err
+			}
+			_, err = tx.Exec(ctx, dropVectorSQL(schema, table, vectorColumn))
+			return err
+		})
+	})
+	if err != nil {
+		return configError(err, "delete")
+	}
+
+	s.refreshSchemaCache(ctx)
+	return nil
+}
+
+// addVector adds the generated tsvector column of a configuration, if it has one
+func addVector(ctx context.Context, tx pgx.Tx, cfg *Config) error {
+	if cfg.VectorColumn == "" {
+		return nil
+	}
+	for _, statement := range addVectorSQL(cfg) {
+		if _, err := tx.Exec(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkTable checks that the table of a configuration exists with the configured text
+// columns, and that its vector column doesn't exist yet unless it is ownVector, the vector
+// column the configuration already manages
+func (s *Service) checkTable(ctx context.Context, cfg *Config, ownVector string) error {
+	schema, table, err := cfg.TableName()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	rows, err := s.db.Pool().Query(ctx, `
+		SELECT a.attname, format_type(a.atttypid, NULL)
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid AND c.relkind IN ('r', 'p')
+		WHERE a.attrelid = to_regclass($1) AND a.attnum > 0 AND NOT a.attisdropped
+	`, query.QuoteQualifiedName(schema, table))
+	if err != nil {
+		return fmt.Errorf("failed to inspect table: %w", err)
+	}
+	types := make(map[string]string)
+	var name, dataType string
+	_, err = pgx.ForEachRow(rows, []any{&name, &dataType}, func() error {
+		types[name] = dataType
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to inspect table: %w", err)
+	}
+
+	if len(types) == 0 {
+		return fmt.Errorf("%w: table %s.%s not found", ErrInvalidConfig, schema, table)
+	}
+	for _, col := range cfg.Columns {
+		dataType, ok := types[col.Column]
+		if !ok {
+			return fmt.Errorf("%w: column %s not found in %s.%s", ErrInvalidConfig, col.Column, schema, table)
+		}
+		if !textColumnTypes[dataType] {
+			return fmt.Errorf("%w: column %s has type %s, expected a text type", ErrInvalidConfig, col.Column, dataType)
+		}
+	}
+	if cfg.VectorColumn != "" && cfg.VectorColumn != ownVector {
+		if _, exists := types[cfg.VectorColumn]; exists {
+			return fmt.Errorf("%w: column %s already exists in %s.%s", ErrInvalidConfig, cfg.VectorColumn, schema, table)
+		}
+	}
+	return nil
+}
+
+// configError maps errors of statements run for action to the errors of the package. Errors
+// reported by PostgreSQL, e.g. an unknown text search configuration, reject the configuration.
+func configError(err error, action string) error {
+	if errors.Is(err, ErrConfigNotFound) {
+		return err
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		if pgErr.Code == "23505" { // unique_violation
+			return ErrConfigExists
+		}
+		return fmt.Errorf("%w: %s", ErrInvalidConfig, pgErr.Message)
+	}
+	return fmt.Errorf("failed to %s text search configuration: %w", action, err)
+}
+
+// refreshSchemaCache makes the REST API see configuration changes right away
+func (s *Service) refreshSchemaCache(ctx context.Context) {
+	if s.schemaCache != nil {
+		s.schemaCache.InvalidateAll(ctx)
+	}
+}
@@ -0,0 +1,210 @@
+// Package textsearch manages the full text search configuration of table columns.
+//
+// A table's configuration names the text search configuration (language) that fts, plfts and
+// wfts filters on its columns use instead of the database default, and a weight class for
+// each column. It can also add a generated tsvector column combining the columns with their
+// weights, indexed with GIN, so searches across them don't convert every row at query time.
+package textsearch
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nimbleflux/fluxbase/internal/query"
+)
+
+const (
+	// DefaultLanguage is the text search configuration of tables configured without a language
+	DefaultLanguage = "simple"
+	// DefaultWeight is the weight class of columns configured without a weight
+	DefaultWeight = "D"
+)
+
+// maxColumnsPerTable bounds the columns of a configuration
+const maxColumnsPerTable = 100
+
+var (
+	// ErrConfigNotFound is returned for unknown text search configurations
+	ErrConfigNotFound = errors.New("text search configuration not found")
+	// ErrConfigExists is returned when the table already has a text search configuration
+	ErrConfigExists = errors.New("the table already has a text search configuration")
+	// ErrInvalidConfig is returned for configurations that can't be applied
+	ErrInvalidConfig = errors.New("invalid text search configuration")
+)
+
+var (
+	identifierPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	// Text search configuration names, optionally schema-qualified for custom configurations
+	languagePattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*(\.[a-z_][a-z0-9_]*)?$`)
+)
+
+// languageCodes maps ISO 639-1 codes to the text search configurations shipped with PostgreSQL
+var languageCodes = map[string]string{
+	"ar": "arabic", "ca": "catalan", "da": "danish", "de": "german", "el": "greek", "en": "english",
+	"es": "spanish", "eu": "basque", "fi": "finnish", "fr": "french", "ga": "irish", "hi": "hindi",
+	"hu": "hungarian", "hy": "armenian", "id": "indonesian", "it": "italian", "lt": "lithuanian",
+	"ne": "nepali", "nl": "dutch", "no": "norwegian", "pt": "portuguese", "ro": "romanian",
+	"ru": "russian", "sr": "serbian", "sv": "swedish", "ta": "tamil", "tr": "turkish", "yi": "yiddish",
+}
+
+// ResolveLanguage returns the text search configuration named by language: a configuration
+// name such as "french" or "public.french_unaccent", or an ISO 639-1 code such as "fr"
+func ResolveLanguage(language string) (string, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if name, ok := languageCodes[language]; ok {
+		return name, nil
+	}
+	if !languagePattern.MatchString(language) {
+		return "", fmt.Errorf("invalid text search language %q", language)
+	}
+	return language, nil
+}
+
+// Config is the text search configuration of a table
+type Config struct {
+	ID       string         `json:"id"`
+	Table    string         `json:"table"`    // "schema.table"
+	Language string         `json:"language"` // Text search configuration of the columns, default simple
+	Columns  []ColumnConfig `json:"columns"`
+	// Generated tsvector column combining the columns with their weights, empty for none
+	VectorColumn string    `json:"vector_column,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ColumnConfig is the text search configuration of one text column
+type ColumnConfig struct {
+	Column   string `json:"column"`
+	Language string `json:"language,omitempty"` // Overrides the language of the table
+	Weight   string `json:"weight"`             // A (highest) to D
+}
+
+// Validate normalizes a configuration and checks it
+func (cfg *Config) Validate() error {
+	schema, table, err := cfg.TableName()
+	if err != nil {
+		return err
+	}
+	cfg.Table = schema + "." + table
+
+	if cfg.Language == "" {
+		cfg.Language = DefaultLanguage
+	}
+	if cfg.Language, err = ResolveLanguage(cfg.Language); err != nil {
+		return err
+	}
+
+	if len(cfg.Columns) == 0 {
+		return fmt.Errorf("at least one column is required")
+	}
+	if len(cfg.Columns) > maxColumnsPerTable {
+		return fmt.Errorf("at most %d columns can be configured", maxColumnsPerTable)
+	}
+	seen := make(map[string]bool, len(cfg.Columns))
+	for i := range cfg.Columns {
+		col := &cfg.Columns[i]
+		if err := col.validate(); err != nil {
+			return err
+		}
+		if seen[col.Column] {
+			return fmt.Errorf("column %s is configured twice", col.Column)
+		}
+		seen[col.Column] = true
+	}
+
+	cfg.VectorColumn = strings.TrimSpace(cfg.VectorColumn)
+	if cfg.VectorColumn != "" {
+		if !identifierPattern.MatchString(cfg.VectorColumn) {
+			return fmt.Errorf("invalid vector_column %q", cfg.VectorColumn)
+		}
+		if seen[cfg.VectorColumn] {
+			return fmt.Errorf("vector_column %s must not be one of the configured columns", cfg.VectorColumn)
+		}
+	}
+	return nil
+}
+
+// TableName splits the table of a configuration, defaulting to the public schema
+func (cfg *Config) TableName() (string, string, error) {
+	schema, table, ok := strings.Cut(strings.TrimSpace(cfg.Table), ".")
+	if !ok {
+		schema, table = "public", schema
+	}
+	if !identifierPattern.MatchString(schema) || !identifierPattern.MatchString(table) {
+		return "", "", fmt.Errorf("invalid table %q, expected schema.table", cfg.Table)
+	}
+	return schema, table, nil
+}
+
+// ColumnLanguage returns the text search configuration of a column
+func (cfg *Config) ColumnLanguage(col ColumnConfig) string {
+	if col.Language != "" {
+		return col.Language
+	}
+	return cfg.Language
+}
+
+func (c *ColumnConfig) validate() error {
+	if !identifierPattern.MatchString(c.Column) {
+		return fmt.Errorf("invalid column %q", c.Column)
+	}
+	if c.Language != "" {
+		language, err := ResolveLanguage(c.Language)
+		if err != nil {
+			return fmt.Errorf("column %s: %w", c.Column, err)
+		}
+		c.Language = language
+	}
+
+	c.Weight = strings.ToUpper(strings.TrimSpace(c.Weight))
+	switch c.Weight {
+	case "":
+		c.Weight = DefaultWeight
+	case "A", "B", "C", "D":
+	default:
+		return fmt.Errorf("column %s: weight must be one of A, B, C or D", c.Column)
+	}
+	return nil
+}
+
+// vectorExpression returns the expression of the generated tsvector column: the columns
+// converted in their language and weighted, concatenated in configuration order. NULL
+// columns contribute nothing.
+func vectorExpression(cfg *Config) string {
+	parts := make([]string, len(cfg.Columns))
+	for i, col := range cfg.Columns {
+		parts[i] = fmt.Sprintf("setweight(to_tsvector(%s::regconfig, coalesce(%s, '')), %s)",
+			query.QuoteLiteral(cfg.ColumnLanguage(col)), query.QuoteIdentifier(col.Column), query.QuoteLiteral(col.Weight))
+	}
+	return strings.Join(parts, " || ")
+}
+
+// addVectorSQL returns the statements adding the generated tsvector column of a validated
+// configuration and its GIN index
+func addVectorSQL(cfg *Config) []string {
+	schema, table, _ := cfg.TableName()
+	return []string{
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s tsvector GENERATED ALWAYS AS (%s) STORED",
+			query.QuoteQualifiedName(schema, table), query.QuoteIdentifier(cfg.VectorColumn), vectorExpression(cfg)),
+		fmt.Sprintf("CREATE INDEX %s ON %s USING gin (%s)",
+			query.QuoteIdentifier(vectorIndexName(table, cfg.VectorColumn)), query.QuoteQualifiedName(schema, table), query.QuoteIdentifier(cfg.VectorColumn)),
+	}
+}
+
+// dropVectorSQL returns the statement dropping a generated tsvector column, with its index
+func dropVectorSQL(schema, table, column string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s", query.QuoteQualifiedName(schema, table), query.QuoteIdentifier(column))
+}
+
+// vectorIndexName names the GIN index of a generated tsvector column, within PostgreSQL's
+// 63 byte identifier limit
+func vectorIndexName(table, column string) string {
+	name := fmt.Sprintf("idx_%s_%s", table, column)
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return name
+}
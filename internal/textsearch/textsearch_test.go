@@ -0,0 +1,114 @@
+package textsearch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveLanguage(t *testing.T) {
+	tests := []struct {
+		language string
+		want     string
+		wantErr  bool
+	}{
+		{"fr", "french", false},
+		{"FR", "french", false},
+		{"english", "english", false},
+		{"public.french_unaccent", "public.french_unaccent", false},
+		{"simple", "simple", false},
+		{"french'; DROP TABLE users; --", "", true},
+		{"", "", true},
+		{"a.b.c", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			got, err := ResolveLanguage(tt.language)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	valid := func() *Config {
+		return &Config{
+			Table: "blog.posts",
+			Columns: []ColumnConfig{
+				{Column: "title", Weight: "a"},
+				{Column: "body", Language: "de"},
+			},
+			VectorColumn: "search",
+		}
+	}
+
+	t.Run("normalizes the configuration", func(t *testing.T) {
+		cfg := valid()
+		cfg.Table = "posts"
+		require.NoError(t, cfg.Validate())
+		assert.Equal(t, "public.posts", cfg.Table)
+		assert.Equal(t, DefaultLanguage, cfg.Language)
+		assert.Equal(t, "A", cfg.Columns[0].Weight)
+		assert.Equal(t, DefaultWeight, cfg.Columns[1].Weight)
+		assert.Equal(t, "german", cfg.Columns[1].Language)
+	})
+
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{"invalid table", func(cfg *Config) { cfg.Table = "blog.posts;" }, "invalid table"},
+		{"invalid language", func(cfg *Config) { cfg.Language = "french'" }, "invalid text search language"},
+		{"no columns", func(cfg *Config) { cfg.Columns = nil }, "at least one column"},
+		{"invalid column", func(cfg *Config) { cfg.Columns[0].Column = "title)" }, "invalid column"},
+		{"invalid weight", func(cfg *Config) { cfg.Columns[0].Weight = "E" }, "weight must be one of"},
+		{"invalid column language", func(cfg *Config) { cfg.Columns[1].Language = "de de" }, "column body"},
+		{"duplicate column", func(cfg *Config) { cfg.Columns[1].Column = "title" }, "configured twice"},
+		{"invalid vector column", func(cfg *Config) { cfg.VectorColumn = "search vector" }, "invalid vector_column"},
+		{"vector column is a configured column", func(cfg *Config) { cfg.VectorColumn = "body" }, "must not be one of"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestAddVectorSQL(t *testing.T) {
+	cfg := &Config{
+		Table:    "blog.posts",
+		Language: "english",
+		Columns: []ColumnConfig{
+			{Column: "title", Weight: "A"},
+			{Column: "body", Language: "german", Weight: "D"},
+		},
+		VectorColumn: "search",
+	}
+	require.NoError(t, cfg.Validate())
+
+	statements := addVectorSQL(cfg)
+	require.Len(t, statements, 2)
+	assert.Equal(t, `ALTER TABLE "blog"."posts" ADD COLUMN "search" tsvector GENERATED ALWAYS AS (`+
+		`setweight(to_tsvector('english'::regconfig, coalesce("title", '')), 'A') || `+
+		`setweight(to_tsvector('german'::regconfig, coalesce("body", '')), 'D')) STORED`, statements[0])
+	assert.Equal(t, `CREATE INDEX "idx_posts_search" ON "blog"."posts" USING gin ("search")`, statements[1])
+
+	assert.Equal(t, `ALTER TABLE "blog"."posts" DROP COLUMN IF EXISTS "search"`, dropVectorSQL("blog", "posts", "search"))
+}
+
+func TestVectorIndexName_FitsIdentifierLimit(t *testing.T) {
+	name := vectorIndexName(strings.Repeat("t", 60), "search")
+	assert.Len(t, name, 63)
+	assert.True(t, strings.HasPrefix(name, "idx_ttt"))
+}
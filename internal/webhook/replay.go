@@ -0,0 +1,195 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/nimbleflux/fluxbase/internal/database"
+	"github.com/rs/zerolog/log"
+)
+
+// Delivery statuses
+const (
+	DeliveryPending = "pending"
+	DeliverySuccess = "success"
+	DeliveryFailed  = "failed"
+)
+
+// ErrDeliveryNotFound is returned when a delivery doesn't exist
+var ErrDeliveryNotFound = errors.New("delivery not found")
+
+// deliveryColumns are the columns scanned by scanDelivery
+const deliveryColumns = `id, webhook_id, event, payload, status, status_code, response_body, error, attempt, replay_of, delivered_at, created_at`
+
+// scanDelivery scans a row of deliveryColumns
+func scanDelivery(row pgx.Row) (*WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.Event,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.StatusCode,
+		&delivery.ResponseBody,
+		&delivery.Error,
+		&delivery.Attempt,
+		&delivery.ReplayOf,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// DeliveryFilter selects deliveries across webhooks. Zero fields don't filter.
+type DeliveryFilter struct {
+	WebhookID *uuid.UUID
+	Status    string // pending, success or failed
+	Since     *time.Time
+	Until     *time.Time
+	Limit     int // Defaults to 50
+}
+
+// deliveryQuery builds the query selecting the deliveries of a filter, newest first
+func deliveryQuery(filter DeliveryFilter) (string, []any) {
+	var conditions []string
+	var args []any
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.WebhookID != nil {
+		add("webhook_id = $%d", *filter.WebhookID)
+	}
+	if filter.Status != "" {
+		add("status = $%d", filter.Status)
+	}
+	if filter.Since != nil {
+		add("created_at >= $%d", *filter.Since)
+	}
+	if filter.Until != nil {
+		add("created_at < $%d", *filter.Until)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	args = append(args, limit)
+
+	query := `SELECT ` + deliveryColumns + ` FROM auth.webhook_deliveries`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(` ORDER BY created_at DESC LIMIT $%d`, len(args))
+	return query, args
+}
+
+// SearchDeliveries lists the deliveries of all webhooks matching a filter, newest first
+func (s *WebhookService) SearchDeliveries(ctx context.Context, filter DeliveryFilter) ([]*WebhookDelivery, error) {
+	query, args := deliveryQuery(filter)
+
+	deliveries := []*WebhookDelivery{}
+	err := database.WrapWithServiceRole(ctx, s.db, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			delivery, err := scanDelivery(rows)
+			if err != nil {
+				return err
+			}
+			deliveries = append(deliveries, delivery)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// GetDelivery retrieves a delivery with its payload and the endpoint's response
+func (s *WebhookService) GetDelivery(ctx context.Context, id uuid.UUID) (*WebhookDelivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM auth.webhook_deliveries WHERE id = $1`
+
+	var delivery *WebhookDelivery
+	err := database.WrapWithServiceRole(ctx, s.db, func(tx pgx.Tx) error {
+		var err error
+		delivery, err = scanDelivery(tx.QueryRow(ctx, query, id))
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// ReplayDelivery sends the payload of a delivery to its webhook again, with a fresh signature.
+// The replay is recorded as a new delivery, which is returned whether or not it succeeded.
+func (s *WebhookService) ReplayDelivery(ctx context.Context, id uuid.UUID) (*WebhookDelivery, error) {
+	original, err := s.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err := s.Get(ctx, original.WebhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	replayID, err := s.createDeliveryRecord(ctx, webhook.ID, original.Event, original.Payload, 1, &original.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response, deliveryErr := s.sendWebhookSync(ctx, webhook, original.Payload)
+	if deliveryErr != nil {
+		s.markDeliveryFailed(ctx, replayID, response.StatusCode, response.Body, deliveryErr.Error())
+	} else {
+		s.markDeliverySuccess(ctx, replayID, response.StatusCode, response.Body)
+	}
+
+	log.Info().
+		Str("delivery_id", original.ID.String()).
+		Str("replay_id", replayID.String()).
+		Str("webhook_id", webhook.ID.String()).
+		Bool("success", deliveryErr == nil).
+		Msg("Replayed webhook delivery")
+
+	return s.GetDelivery(ctx, replayID)
+}
+
+// ReplayDeliveries replays deliveries one after another and returns the replays. Deliveries that
+// no longer exist are skipped.
+func (s *WebhookService) ReplayDeliveries(ctx context.Context, ids []uuid.UUID) ([]*WebhookDelivery, error) {
+	replays := []*WebhookDelivery{}
+	for _, id := range ids {
+		replay, err := s.ReplayDelivery(ctx, id)
+		if errors.Is(err, ErrDeliveryNotFound) {
+			continue
+		}
+		if err != nil {
+			return replays, err
+		}
+		replays = append(replays, replay)
+	}
+	return replays, nil
+}
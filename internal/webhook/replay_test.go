@@ -0,0 +1,35 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryQuery(t *testing.T) {
+	t.Run("no filter", func(t *testing.T) {
+		query, args := deliveryQuery(DeliveryFilter{})
+
+		assert.Equal(t, `SELECT `+deliveryColumns+` FROM auth.webhook_deliveries ORDER BY created_at DESC LIMIT $1`, query)
+		assert.Equal(t, []any{50}, args)
+	})
+
+	t.Run("all filters", func(t *testing.T) {
+		webhookID := uuid.New()
+		since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+		until := since.Add(24 * time.Hour)
+
+		query, args := deliveryQuery(DeliveryFilter{
+			WebhookID: &webhookID,
+			Status:    DeliveryFailed,
+			Since:     &since,
+			Until:     &until,
+			Limit:     10,
+		})
+
+		assert.Contains(t, query, "WHERE webhook_id = $1 AND status = $2 AND created_at >= $3 AND created_at < $4 ORDER BY created_at DESC LIMIT $5")
+		assert.Equal(t, []any{webhookID, DeliveryFailed, since, until, 10}, args)
+	})
+}
@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"fmt"
+	"time"
+)
+
+// Retry backoff strategies: how the wait before a retry grows with the number of failed attempts
+const (
+	BackoffLinear      = "linear"      // retry_backoff_seconds x attempt
+	BackoffExponential = "exponential" // retry_backoff_seconds x 2^(attempt-1)
+	BackoffFixed       = "fixed"       // retry_backoff_seconds
+)
+
+// ValidateRetryPolicy checks the retry settings of a webhook. An empty strategy is treated as linear.
+func ValidateRetryPolicy(webhook *Webhook) error {
+	switch webhook.RetryBackoffStrategy {
+	case "", BackoffLinear, BackoffExponential, BackoffFixed:
+	default:
+		return fmt.Errorf("retry_backoff_strategy must be one of %s, %s or %s", BackoffLinear, BackoffExponential, BackoffFixed)
+	}
+	if webhook.MaxRetries < 0 {
+		return fmt.Errorf("max_retries must not be negative")
+	}
+	if webhook.RetryBackoffSeconds < 0 {
+		return fmt.Errorf("retry_backoff_seconds must not be negative")
+	}
+	if webhook.MaxRetryBackoffSeconds < 0 {
+		return fmt.Errorf("max_retry_backoff_seconds must not be negative")
+	}
+	return nil
+}
+
+// retryBackoff returns how long to wait before retrying a delivery that has failed attempts times
+func retryBackoff(webhook *Webhook, attempts int) time.Duration {
+	base := time.Duration(webhook.RetryBackoffSeconds) * time.Second
+	limit := time.Duration(webhook.MaxRetryBackoffSeconds) * time.Second
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var backoff time.Duration
+	switch webhook.RetryBackoffStrategy {
+	case BackoffFixed:
+		backoff = base
+	case BackoffExponential:
+		backoff = base
+		for i := 1; i < attempts; i++ {
+			backoff *= 2
+			// Stop doubling once past the bound (or before overflowing when there is none)
+			if (limit > 0 && backoff >= limit) || backoff >= 365*24*time.Hour {
+				break
+			}
+		}
+	default:
+		backoff = base * time.Duration(attempts)
+	}
+
+	if limit > 0 && backoff > limit {
+		return limit
+	}
+	return backoff
+}
@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		webhook  Webhook
+		attempts int
+		expected time.Duration
+	}{
+		{"linear", Webhook{RetryBackoffSeconds: 5, RetryBackoffStrategy: BackoffLinear}, 3, 15 * time.Second},
+		{"empty strategy is linear", Webhook{RetryBackoffSeconds: 5}, 2, 10 * time.Second},
+		{"exponential first attempt", Webhook{RetryBackoffSeconds: 5, RetryBackoffStrategy: BackoffExponential}, 1, 5 * time.Second},
+		{"exponential", Webhook{RetryBackoffSeconds: 5, RetryBackoffStrategy: BackoffExponential}, 4, 40 * time.Second},
+		{"fixed", Webhook{RetryBackoffSeconds: 30, RetryBackoffStrategy: BackoffFixed}, 7, 30 * time.Second},
+		{"capped", Webhook{RetryBackoffSeconds: 60, RetryBackoffStrategy: BackoffExponential, MaxRetryBackoffSeconds: 300}, 10, 300 * time.Second},
+		{"linear capped", Webhook{RetryBackoffSeconds: 60, RetryBackoffStrategy: BackoffLinear, MaxRetryBackoffSeconds: 100}, 2, 100 * time.Second},
+		{"exponential unbounded does not overflow", Webhook{RetryBackoffSeconds: 60, RetryBackoffStrategy: BackoffExponential}, 200, 60 * time.Second << 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, retryBackoff(&tt.webhook, tt.attempts))
+		})
+	}
+}
+
+func TestValidateRetryPolicy(t *testing.T) {
+	assert.NoError(t, ValidateRetryPolicy(&Webhook{MaxRetries: 3, RetryBackoffSeconds: 5}))
+	assert.NoError(t, ValidateRetryPolicy(&Webhook{RetryBackoffStrategy: BackoffExponential, MaxRetryBackoffSeconds: 3600}))
+
+	assert.ErrorContains(t, ValidateRetryPolicy(&Webhook{RetryBackoffStrategy: "random"}), "retry_backoff_strategy")
+	assert.ErrorContains(t, ValidateRetryPolicy(&Webhook{MaxRetries: -1}), "max_retries")
+	assert.ErrorContains(t, ValidateRetryPolicy(&Webhook{RetryBackoffSeconds: -1}), "retry_backoff_seconds")
+	assert.ErrorContains(t, ValidateRetryPolicy(&Webhook{MaxRetryBackoffSeconds: -1}), "max_retry_backoff_seconds")
+}
@@ -544,24 +544,7 @@ func (s *TriggerService) deliverEvent(ctx context.Context, webhook *Webhook, eve
 		return
 	}
 
-	// Create payload
-	payload := &WebhookPayload{
-		Event:     event.EventType,
-		Table:     event.TableName,
-		Schema:    event.TableSchema,
-		Timestamp: time.Now(),
-	}
-
-	// Add record data based on event type
-	switch event.EventType {
-	case "INSERT":
-		payload.Record = event.NewData
-	case "UPDATE":
-		payload.Record = event.NewData
-		payload.OldRecord = event.OldData
-	case "DELETE":
-		payload.Record = event.OldData
-	}
+	payload := buildPayload(event)
 
 	// Marshal payload for delivery record
 	payloadJSON, err := json.Marshal(payload)
@@ -579,14 +562,14 @@ func (s *TriggerService) deliverEvent(ctx context.Context, webhook *Webhook, eve
 	}
 
 	// Deliver webhook
-	deliveryErr := s.webhookSvc.Deliver(ctx, webhook, payload)
+	response, deliveryErr := s.webhookSvc.sendWebhookSync(ctx, webhook, payloadJSON)
 
 	// Update delivery record status
 	if deliveryID != uuid.Nil {
 		if deliveryErr != nil {
-			s.webhookSvc.markDeliveryFailed(ctx, deliveryID, 0, nil, deliveryErr.Error())
+			s.webhookSvc.markDeliveryFailed(ctx, deliveryID, response.StatusCode, response.Body, deliveryErr.Error())
 		} else {
-			s.webhookSvc.markDeliverySuccess(ctx, deliveryID, 200, nil)
+			s.webhookSvc.markDeliverySuccess(ctx, deliveryID, response.StatusCode, response.Body)
 		}
 	}
 
@@ -598,6 +581,29 @@ func (s *TriggerService) deliverEvent(ctx context.Context, webhook *Webhook, eve
 	}
 }
 
+// buildPayload creates the webhook payload for a queued event
+func buildPayload(event *WebhookEvent) *WebhookPayload {
+	payload := &WebhookPayload{
+		Event:     event.EventType,
+		Table:     event.TableName,
+		Schema:    event.TableSchema,
+		Timestamp: time.Now(),
+	}
+
+	// Add record data based on event type
+	switch event.EventType {
+	case "INSERT", EventDocumentIndexed, EventDocumentFailed:
+		payload.Record = event.NewData
+	case "UPDATE":
+		payload.Record = event.NewData
+		payload.OldRecord = event.OldData
+	case "DELETE":
+		payload.Record = event.OldData
+	}
+
+	return payload
+}
+
 // scheduleRetryWithoutIncrement schedules a retry without incrementing the attempt count
 // Used for rate limiting scenarios where we want to retry but not count it as a failed attempt
 func (s *TriggerService) scheduleRetryWithoutIncrement(ctx context.Context, event *WebhookEvent) {
@@ -649,9 +655,8 @@ func (s *TriggerService) handleDeliveryFailure(ctx context.Context, event *Webho
 		return
 	}
 
-	// Calculate next retry time with exponential backoff
-	backoffSeconds := webhook.RetryBackoffSeconds * attempts
-	nextRetry := time.Now().Add(time.Duration(backoffSeconds) * time.Second)
+	// Calculate next retry time from the webhook's retry policy
+	nextRetry := time.Now().Add(retryBackoff(webhook, attempts))
 
 	log.Debug().
 		Str("event_id", event.ID.String()).
@@ -1155,3 +1155,49 @@ func TestWebhookDeliveryCreation(t *testing.T) {
 		}
 	})
 }
+
+func TestBuildPayload(t *testing.T) {
+	t.Run("UPDATE event includes both records", func(t *testing.T) {
+		payload := buildPayload(&WebhookEvent{
+			EventType:   "UPDATE",
+			TableSchema: "public",
+			TableName:   "users",
+			OldData:     json.RawMessage(`{"id": 1, "name": "Bob"}`),
+			NewData:     json.RawMessage(`{"id": 1, "name": "Robert"}`),
+		})
+
+		assert.Equal(t, "UPDATE", payload.Event)
+		assert.Equal(t, "users", payload.Table)
+		assert.Equal(t, "public", payload.Schema)
+		assert.JSONEq(t, `{"id": 1, "name": "Robert"}`, string(payload.Record))
+		assert.JSONEq(t, `{"id": 1, "name": "Bob"}`, string(payload.OldRecord))
+	})
+
+	t.Run("DELETE event uses old record", func(t *testing.T) {
+		payload := buildPayload(&WebhookEvent{
+			EventType: "DELETE",
+			OldData:   json.RawMessage(`{"id": 1}`),
+		})
+
+		assert.JSONEq(t, `{"id": 1}`, string(payload.Record))
+		assert.Nil(t, payload.OldRecord)
+	})
+
+	for _, eventType := range []string{EventDocumentIndexed, EventDocumentFailed} {
+		t.Run(eventType+" document event includes the status record", func(t *testing.T) {
+			record := json.RawMessage(`{"id": "doc-1", "status": "indexed", "chunks_count": 12}`)
+			payload := buildPayload(&WebhookEvent{
+				EventType:   eventType,
+				TableSchema: "ai",
+				TableName:   "documents",
+				NewData:     record,
+			})
+
+			assert.Equal(t, eventType, payload.Event)
+			assert.Equal(t, "ai", payload.Schema)
+			assert.Equal(t, "documents", payload.Table)
+			assert.JSONEq(t, string(record), string(payload.Record))
+			assert.Nil(t, payload.OldRecord)
+		})
+	}
+}
@@ -25,27 +25,54 @@ import (
 
 // Webhook represents a webhook configuration
 type Webhook struct {
-	ID                  uuid.UUID         `json:"id"`
-	Name                string            `json:"name"`
-	Description         *string           `json:"description,omitempty"`
-	URL                 string            `json:"url"`
-	Secret              *string           `json:"secret,omitempty"`
-	Enabled             bool              `json:"enabled"`
-	Events              []EventConfig     `json:"events"`
-	MaxRetries          int               `json:"max_retries"`
-	RetryBackoffSeconds int               `json:"retry_backoff_seconds"`
-	TimeoutSeconds      int               `json:"timeout_seconds"`
-	Headers             map[string]string `json:"headers"`
-	Scope               string            `json:"scope"` // "user" or "global"
-	CreatedBy           *uuid.UUID        `json:"created_by,omitempty"`
-	CreatedAt           time.Time         `json:"created_at"`
-	UpdatedAt           time.Time         `json:"updated_at"`
+	ID                     uuid.UUID         `json:"id"`
+	Name                   string            `json:"name"`
+	Description            *string           `json:"description,omitempty"`
+	URL                    string            `json:"url"`
+	Secret                 *string           `json:"secret,omitempty"`
+	Enabled                bool              `json:"enabled"`
+	Events                 []EventConfig     `json:"events"`
+	MaxRetries             int               `json:"max_retries"`
+	RetryBackoffSeconds    int               `json:"retry_backoff_seconds"`
+	RetryBackoffStrategy   string            `json:"retry_backoff_strategy"`    // linear, exponential or fixed
+	MaxRetryBackoffSeconds int               `json:"max_retry_backoff_seconds"` // 0 for no bound
+	TimeoutSeconds         int               `json:"timeout_seconds"`
+	Headers                map[string]string `json:"headers"`
+	Scope                  string            `json:"scope"` // "user" or "global"
+	CreatedBy              *uuid.UUID        `json:"created_by,omitempty"`
+	CreatedAt              time.Time         `json:"created_at"`
+	UpdatedAt              time.Time         `json:"updated_at"`
 }
 
 // EventConfig represents events a webhook subscribes to
 type EventConfig struct {
 	Table      string   `json:"table"`      // e.g., "products", "users"
-	Operations []string `json:"operations"` // INSERT, UPDATE, DELETE
+	Operations []string `json:"operations"` // INSERT, UPDATE, DELETE (INDEXED, FAILED for ai.documents)
+}
+
+// Document status events are queued by the ai.documents status trigger when a knowledge base
+// document finishes processing. Subscribe with {Table: DocumentsTable, Operations: [...]}.
+const (
+	DocumentsTable       = "ai.documents"
+	EventDocumentIndexed = "INDEXED"
+	EventDocumentFailed  = "FAILED"
+)
+
+// needsTableTrigger reports whether an event config needs a row-change trigger on its table.
+// Wildcard tables need none, and document status events have their own built-in trigger.
+func needsTableTrigger(event EventConfig) bool {
+	if event.Table == "*" {
+		return false
+	}
+	if event.Table != DocumentsTable {
+		return true
+	}
+	for _, op := range event.Operations {
+		if op != EventDocumentIndexed && op != EventDocumentFailed {
+			return true
+		}
+	}
+	return false
 }
 
 // WebhookDelivery represents a webhook delivery attempt
@@ -59,13 +86,14 @@ type WebhookDelivery struct {
 	ResponseBody *string         `json:"response_body,omitempty"`
 	Error        *string         `json:"error,omitempty"`
 	Attempt      int             `json:"attempt"`
+	ReplayOf     *uuid.UUID      `json:"replay_of,omitempty"` // Delivery this one manually replays
 	DeliveredAt  *time.Time      `json:"delivered_at,omitempty"`
 	CreatedAt    time.Time       `json:"created_at"`
 }
 
 // WebhookPayload represents the payload sent to webhooks
 type WebhookPayload struct {
-	Event     string          `json:"event"`                // INSERT, UPDATE, DELETE
+	Event     string          `json:"event"`                // INSERT, UPDATE, DELETE, INDEXED, FAILED
 	Table     string          `json:"table"`                // table name
 	Schema    string          `json:"schema"`               // schema name
 	Record    json.RawMessage `json:"record"`               // new record data
@@ -253,8 +281,8 @@ func parseTableReference(tableRef string) (schema, table string) {
 // ManageTriggersForWebhook ensures database triggers exist for all tables monitored by this webhook
 func (s *WebhookService) ManageTriggersForWebhook(ctx context.Context, events []EventConfig) error {
 	for _, event := range events {
-		if event.Table == "*" {
-			continue // Wildcard doesn't need specific trigger
+		if !needsTableTrigger(event) {
+			continue // Wildcards and document status events don't need a row trigger
 		}
 		schema, table := parseTableReference(event.Table)
 		if err := s.incrementTableCount(ctx, schema, table); err != nil {
@@ -267,7 +295,7 @@ func (s *WebhookService) ManageTriggersForWebhook(ctx context.Context, events []
 // CleanupTriggersForWebhook decrements reference counts for monitored tables
 func (s *WebhookService) CleanupTriggersForWebhook(ctx context.Context, events []EventConfig) error {
 	for _, event := range events {
-		if event.Table == "*" {
+		if !needsTableTrigger(event) {
 			continue
 		}
 		schema, table := parseTableReference(event.Table)
@@ -315,6 +343,13 @@ func (s *WebhookService) Create(ctx context.Context, webhook *Webhook) error {
 		webhook.Scope = "user"
 	}
 
+	if webhook.RetryBackoffStrategy == "" {
+		webhook.RetryBackoffStrategy = BackoffLinear
+	}
+	if err := ValidateRetryPolicy(webhook); err != nil {
+		return fmt.Errorf("invalid retry policy: %w", err)
+	}
+
 	eventsJSON, err := json.Marshal(webhook.Events)
 	if err != nil {
 		return fmt.Errorf("failed to marshal events: %w", err)
@@ -326,8 +361,8 @@ func (s *WebhookService) Create(ctx context.Context, webhook *Webhook) error {
 	}
 
 	query := `
-		INSERT INTO auth.webhooks (name, description, url, secret, enabled, events, max_retries, retry_backoff_seconds, timeout_seconds, headers, scope, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		INSERT INTO auth.webhooks (name, description, url, secret, enabled, events, max_retries, retry_backoff_seconds, retry_backoff_strategy, max_retry_backoff_seconds, timeout_seconds, headers, scope, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -341,6 +376,8 @@ func (s *WebhookService) Create(ctx context.Context, webhook *Webhook) error {
 			eventsJSON,
 			webhook.MaxRetries,
 			webhook.RetryBackoffSeconds,
+			webhook.RetryBackoffStrategy,
+			webhook.MaxRetryBackoffSeconds,
 			webhook.TimeoutSeconds,
 			headersJSON,
 			webhook.Scope,
@@ -365,7 +402,7 @@ func (s *WebhookService) Create(ctx context.Context, webhook *Webhook) error {
 // List lists all webhooks
 func (s *WebhookService) List(ctx context.Context) ([]*Webhook, error) {
 	query := `
-		SELECT id, name, description, url, secret, enabled, events, max_retries, retry_backoff_seconds, timeout_seconds, headers, scope, created_by, created_at, updated_at
+		SELECT id, name, description, url, secret, enabled, events, max_retries, retry_backoff_seconds, retry_backoff_strategy, max_retry_backoff_seconds, timeout_seconds, headers, scope, created_by, created_at, updated_at
 		FROM auth.webhooks
 		ORDER BY created_at DESC
 	`
@@ -393,6 +430,8 @@ func (s *WebhookService) List(ctx context.Context) ([]*Webhook, error) {
 				&eventsJSON,
 				&webhook.MaxRetries,
 				&webhook.RetryBackoffSeconds,
+				&webhook.RetryBackoffStrategy,
+				&webhook.MaxRetryBackoffSeconds,
 				&webhook.TimeoutSeconds,
 				&headersJSON,
 				&scope,
@@ -433,7 +472,7 @@ func (s *WebhookService) List(ctx context.Context) ([]*Webhook, error) {
 // Get retrieves a webhook by ID
 func (s *WebhookService) Get(ctx context.Context, id uuid.UUID) (*Webhook, error) {
 	query := `
-		SELECT id, name, description, url, secret, enabled, events, max_retries, retry_backoff_seconds, timeout_seconds, headers, scope, created_by, created_at, updated_at
+		SELECT id, name, description, url, secret, enabled, events, max_retries, retry_backoff_seconds, retry_backoff_strategy, max_retry_backoff_seconds, timeout_seconds, headers, scope, created_by, created_at, updated_at
 		FROM auth.webhooks
 		WHERE id = $1
 	`
@@ -453,6 +492,8 @@ func (s *WebhookService) Get(ctx context.Context, id uuid.UUID) (*Webhook, error
 			&eventsJSON,
 			&webhook.MaxRetries,
 			&webhook.RetryBackoffSeconds,
+			&webhook.RetryBackoffStrategy,
+			&webhook.MaxRetryBackoffSeconds,
 			&webhook.TimeoutSeconds,
 			&headersJSON,
 			&scope,
@@ -512,6 +553,13 @@ func (s *WebhookService) Update(ctx context.Context, id uuid.UUID, webhook *Webh
 		webhook.Scope = "user"
 	}
 
+	if webhook.RetryBackoffStrategy == "" {
+		webhook.RetryBackoffStrategy = BackoffLinear
+	}
+	if err := ValidateRetryPolicy(webhook); err != nil {
+		return fmt.Errorf("invalid retry policy: %w", err)
+	}
+
 	eventsJSON, err := json.Marshal(webhook.Events)
 	if err != nil {
 		return fmt.Errorf("failed to marshal events: %w", err)
@@ -525,8 +573,9 @@ func (s *WebhookService) Update(ctx context.Context, id uuid.UUID, webhook *Webh
 	query := `
 		UPDATE auth.webhooks
 		SET name = $1, description = $2, url = $3, secret = $4, enabled = $5, events = $6,
-		    max_retries = $7, retry_backoff_seconds = $8, timeout_seconds = $9, headers = $10, scope = $11
-		WHERE id = $12
+		    max_retries = $7, retry_backoff_seconds = $8, retry_backoff_strategy = $9, max_retry_backoff_seconds = $10,
+		    timeout_seconds = $11, headers = $12, scope = $13
+		WHERE id = $14
 	`
 
 	err = database.WrapWithServiceRole(ctx, s.db, func(tx pgx.Tx) error {
@@ -539,6 +588,8 @@ func (s *WebhookService) Update(ctx context.Context, id uuid.UUID, webhook *Webh
 			eventsJSON,
 			webhook.MaxRetries,
 			webhook.RetryBackoffSeconds,
+			webhook.RetryBackoffStrategy,
+			webhook.MaxRetryBackoffSeconds,
 			webhook.TimeoutSeconds,
 			headersJSON,
 			webhook.Scope,
@@ -658,24 +709,34 @@ func (s *WebhookService) Deliver(ctx context.Context, webhook *Webhook, payload
 
 	// Send HTTP request synchronously and return error if it fails
 	// The trigger service will handle retries via webhook_events table
-	return s.sendWebhookSync(ctx, webhook, payloadJSON)
+	_, err = s.sendWebhookSync(ctx, webhook, payloadJSON)
+	return err
+}
+
+// maxResponseBodyBytes bounds how much of a response body is kept in the delivery history
+const maxResponseBodyBytes = 16 << 10
+
+// deliveryResponse is what an endpoint answered to a delivery
+type deliveryResponse struct {
+	StatusCode int     // 0 if no response was received
+	Body       *string // Truncated to maxResponseBodyBytes
 }
 
-// sendWebhookSync sends an HTTP request synchronously and returns any error
-func (s *WebhookService) sendWebhookSync(ctx context.Context, webhook *Webhook, payloadJSON []byte) error {
+// sendWebhookSync sends an HTTP request synchronously and returns the response and any error
+func (s *WebhookService) sendWebhookSync(ctx context.Context, webhook *Webhook, payloadJSON []byte) (deliveryResponse, error) {
 	// SECURITY FIX: Validate webhook URL at request time to prevent DNS rebinding attacks
 	// An attacker could create a webhook with a URL that initially resolves to a public IP,
 	// then change the DNS to point to a private IP (e.g., 169.254.169.254 for cloud metadata)
 	if !s.AllowPrivateIPs {
 		if err := validateWebhookURL(webhook.URL); err != nil {
-			return fmt.Errorf("webhook URL validation failed (possible DNS rebinding attack): %w", err)
+			return deliveryResponse{}, fmt.Errorf("webhook URL validation failed (possible DNS rebinding attack): %w", err)
 		}
 	}
 
 	// Create HTTP request
 	req, err := http.NewRequestWithContext(ctx, "POST", webhook.URL, bytes.NewReader(payloadJSON))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return deliveryResponse{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
@@ -707,17 +768,20 @@ func (s *WebhookService) sendWebhookSync(ctx context.Context, webhook *Webhook,
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return deliveryResponse{}, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodyBytes))
+	bodyStr := string(body)
+	response := deliveryResponse{StatusCode: resp.StatusCode, Body: &bodyStr}
+
 	// Check status code
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+		return response, fmt.Errorf("HTTP %d: %s", resp.StatusCode, bodyStr)
 	}
 
-	return nil
+	return response, nil
 }
 
 // sendWebhook sends the actual HTTP request (runs asynchronously).
@@ -803,6 +867,13 @@ func generateTimestampedSignature(payload []byte, secret string, timestamp int64
 	return hex.EncodeToString(mac.Sum(nil))
 }
 
+// SignPayload returns an X-Fluxbase-Signature header value ("t=timestamp,v1=signature") for payload.
+// Other outbound deliveries (e.g. metering exports) use it so receivers can verify them
+// with VerifyWebhookSignature.
+func SignPayload(payload []byte, secret string, timestamp int64) string {
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, generateTimestampedSignature(payload, secret, timestamp))
+}
+
 // WebhookSignature represents a parsed webhook signature header
 type WebhookSignature struct {
 	Timestamp  int64
@@ -891,15 +962,20 @@ func VerifyWebhookSignature(payload []byte, header, secret string, tolerance tim
 
 // CreateDeliveryRecord creates a delivery record before attempting delivery
 func (s *WebhookService) CreateDeliveryRecord(ctx context.Context, webhookID uuid.UUID, event string, payload []byte, attempt int) (uuid.UUID, error) {
+	return s.createDeliveryRecord(ctx, webhookID, event, payload, attempt, nil)
+}
+
+// createDeliveryRecord creates a delivery record, optionally as the replay of an earlier delivery
+func (s *WebhookService) createDeliveryRecord(ctx context.Context, webhookID uuid.UUID, event string, payload []byte, attempt int, replayOf *uuid.UUID) (uuid.UUID, error) {
 	query := `
-		INSERT INTO auth.webhook_deliveries (webhook_id, event, payload, status, attempt)
-		VALUES ($1, $2, $3, 'pending', $4)
+		INSERT INTO auth.webhook_deliveries (webhook_id, event, payload, status, attempt, replay_of)
+		VALUES ($1, $2, $3, 'pending', $4, $5)
 		RETURNING id
 	`
 
 	var deliveryID uuid.UUID
 	err := database.WrapWithServiceRole(ctx, s.db, func(tx pgx.Tx) error {
-		return tx.QueryRow(ctx, query, webhookID, event, payload, attempt).Scan(&deliveryID)
+		return tx.QueryRow(ctx, query, webhookID, event, payload, attempt, replayOf).Scan(&deliveryID)
 	})
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("failed to create delivery record: %w", err)
@@ -945,7 +1021,7 @@ func (s *WebhookService) markDeliveryFailed(ctx context.Context, deliveryID uuid
 // ListDeliveries lists webhook deliveries
 func (s *WebhookService) ListDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]*WebhookDelivery, error) {
 	query := `
-		SELECT id, webhook_id, event, payload, status, status_code, response_body, error, attempt, delivered_at, created_at
+		SELECT ` + deliveryColumns + `
 		FROM auth.webhook_deliveries
 		WHERE webhook_id = $1
 		ORDER BY created_at DESC
@@ -961,28 +1037,14 @@ func (s *WebhookService) ListDeliveries(ctx context.Context, webhookID uuid.UUID
 		defer rows.Close()
 
 		for rows.Next() {
-			var delivery WebhookDelivery
-
-			err := rows.Scan(
-				&delivery.ID,
-				&delivery.WebhookID,
-				&delivery.Event,
-				&delivery.Payload,
-				&delivery.Status,
-				&delivery.StatusCode,
-				&delivery.ResponseBody,
-				&delivery.Error,
-				&delivery.Attempt,
-				&delivery.DeliveredAt,
-				&delivery.CreatedAt,
-			)
+			delivery, err := scanDelivery(rows)
 			if err != nil {
 				return err
 			}
 
-			deliveries = append(deliveries, &delivery)
+			deliveries = append(deliveries, delivery)
 		}
-		return nil
+		return rows.Err()
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list deliveries: %w", err)
@@ -533,6 +533,13 @@ func TestVerifyWebhookSignature(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("Verify SignPayload header", func(t *testing.T) {
+		header := SignPayload(payload, secret, time.Now().Unix())
+
+		err := VerifyWebhookSignature(payload, header, secret, 5*time.Minute)
+		assert.NoError(t, err)
+	})
+
 	t.Run("Reject old signature", func(t *testing.T) {
 		// Signature from 10 minutes ago
 		timestamp := time.Now().Add(-10 * time.Minute).Unix()
@@ -644,7 +651,7 @@ func TestSendWebhookSync(t *testing.T) {
 		require.NoError(t, err)
 
 		// Deliver webhook
-		err = service.sendWebhookSync(context.Background(), webhook, payloadJSON)
+		_, err = service.sendWebhookSync(context.Background(), webhook, payloadJSON)
 		assert.NoError(t, err)
 
 		// Verify server received request
@@ -691,7 +698,7 @@ func TestSendWebhookSync(t *testing.T) {
 		}
 
 		payloadJSON, _ := json.Marshal(payload)
-		err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
+		_, err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
 		assert.NoError(t, err)
 
 		// Verify headers were sent
@@ -741,7 +748,7 @@ func TestSendWebhookSync(t *testing.T) {
 		}
 
 		payloadJSON, _ := json.Marshal(payload)
-		err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
+		_, err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
 		assert.NoError(t, err)
 
 		// Verify signature was sent
@@ -793,7 +800,7 @@ func TestSendWebhookSync(t *testing.T) {
 		}
 
 		payloadJSON, _ := json.Marshal(payload)
-		err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
+		_, err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
 		assert.NoError(t, err)
 
 		var signature string
@@ -841,9 +848,14 @@ func TestSendWebhookSync(t *testing.T) {
 		}
 
 		payloadJSON, _ := json.Marshal(payload)
-		err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
+		response, err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "HTTP 404")
+
+		// The response is kept for the delivery history
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+		require.NotNil(t, response.Body)
+		assert.Equal(t, "Not found", *response.Body)
 	})
 
 	t.Run("webhook delivery failure - 500 response", func(t *testing.T) {
@@ -872,7 +884,7 @@ func TestSendWebhookSync(t *testing.T) {
 		}
 
 		payloadJSON, _ := json.Marshal(payload)
-		err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
+		_, err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "HTTP 500")
 	})
@@ -904,7 +916,7 @@ func TestSendWebhookSync(t *testing.T) {
 		}
 
 		payloadJSON, _ := json.Marshal(payload)
-		err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
+		_, err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
 		assert.Error(t, err)
 	})
 
@@ -928,7 +940,7 @@ func TestSendWebhookSync(t *testing.T) {
 		}
 
 		payloadJSON, _ := json.Marshal(payload)
-		err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
+		_, err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to send request")
 	})
@@ -953,7 +965,7 @@ func TestSendWebhookSync(t *testing.T) {
 		}
 
 		payloadJSON, _ := json.Marshal(payload)
-		err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
+		_, err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to create request")
 	})
@@ -984,7 +996,7 @@ func TestSendWebhookSync(t *testing.T) {
 		}
 
 		payloadJSON, _ := json.Marshal(payload)
-		err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
+		_, err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
 		assert.NoError(t, err) // Should complete within 1s
 	})
 
@@ -1017,7 +1029,7 @@ func TestSendWebhookSync(t *testing.T) {
 				}
 
 				payloadJSON, _ := json.Marshal(payload)
-				err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
+				_, err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
 				assert.NoError(t, err, "HTTP %d should succeed", code)
 			})
 		}
@@ -1049,7 +1061,7 @@ func TestSendWebhookSync(t *testing.T) {
 		}
 
 		payloadJSON, _ := json.Marshal(payload)
-		err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
+		_, err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "HTTP 302")
 	})
@@ -1084,7 +1096,7 @@ func TestSendWebhookSync(t *testing.T) {
 				}
 
 				payloadJSON, _ := json.Marshal(payload)
-				err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
+				_, err := service.sendWebhookSync(context.Background(), webhook, payloadJSON)
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), fmt.Sprintf("HTTP %d", code))
 			})
@@ -1536,3 +1548,23 @@ func TestEventConfig_Operations(t *testing.T) {
 		assert.NotContains(t, config.Operations, "INSERT")
 	})
 }
+
+func TestNeedsTableTrigger(t *testing.T) {
+	tests := []struct {
+		name  string
+		event EventConfig
+		want  bool
+	}{
+		{name: "table operations", event: EventConfig{Table: "users", Operations: []string{"INSERT"}}, want: true},
+		{name: "wildcard table", event: EventConfig{Table: "*", Operations: []string{"INSERT"}}, want: false},
+		{name: "document status events", event: EventConfig{Table: DocumentsTable, Operations: []string{EventDocumentIndexed, EventDocumentFailed}}, want: false},
+		{name: "document status and row events", event: EventConfig{Table: DocumentsTable, Operations: []string{EventDocumentIndexed, "UPDATE"}}, want: true},
+		{name: "all document operations", event: EventConfig{Table: DocumentsTable, Operations: []string{"*"}}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, needsTableTrigger(tt.event))
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// AIService calls the AI endpoints: chatbots, the current user's conversations,
+// embeddings and vector search. Chat itself runs over the /ai/ws websocket, which this
+// client does not cover.
+type AIService struct {
+	client *Client
+}
+
+// Chatbot is a public chatbot
+type Chatbot struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace"`
+	Description string `json:"description,omitempty"`
+	Model       string `json:"model,omitempty"`
+	Enabled     bool   `json:"enabled"`
+	IsPublic    bool   `json:"is_public"`
+	Source      string `json:"source"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// ConversationSummary is a conversation in a listing
+type ConversationSummary struct {
+	ID           string            `json:"id"`
+	Chatbot      string            `json:"chatbot"`
+	Namespace    string            `json:"namespace"`
+	Title        *string           `json:"title"`
+	Preview      string            `json:"preview"`
+	MessageCount int               `json:"message_count"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	Metadata     map[string]string `json:"metadata"`
+	Tags         []string          `json:"tags"`
+}
+
+// ConversationList is a page of conversations
+type ConversationList struct {
+	Conversations []ConversationSummary `json:"conversations"`
+	Total         int                   `json:"total"`
+	HasMore       bool                  `json:"has_more"`
+}
+
+// ListConversationsOptions filters and pages the conversation listing
+type ListConversationsOptions struct {
+	Chatbot   string
+	Namespace string
+	Limit     int
+	Offset    int
+}
+
+// Message is a message of a conversation
+type Message struct {
+	ID        string    `json:"id"`
+	Sequence  int       `json:"sequence"`
+	Role      string    `json:"role"`
+	Content   string    `json:"content"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Conversation is a conversation with its messages
+type Conversation struct {
+	ID               string            `json:"id"`
+	Chatbot          string            `json:"chatbot"`
+	Namespace        string            `json:"namespace"`
+	Title            *string           `json:"title"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+	LastReadSequence int               `json:"last_read_sequence"`
+	Metadata         map[string]string `json:"metadata"`
+	Tags             []string          `json:"tags"`
+	Messages         []Message         `json:"messages"`
+}
+
+// EmbeddingsRequest embeds a batch of texts
+type EmbeddingsRequest struct {
+	Texts []string `json:"texts"`
+	// Model overrides the configured embedding model
+	Model string `json:"model,omitempty"`
+}
+
+// EmbeddingsResponse holds one embedding per input text, in input order
+type EmbeddingsResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+	Model      string      `json:"model"`
+	Dimensions int         `json:"dimensions"`
+	Batches    int         `json:"batches"`
+	Usage      struct {
+		PromptTokens int  `json:"prompt_tokens"`
+		TotalTokens  int  `json:"total_tokens"`
+		Estimated    bool `json:"estimated,omitempty"`
+	} `json:"usage"`
+}
+
+// VectorFilter restricts vector search results, like a table filter
+type VectorFilter struct {
+	Column   string      `json:"column"`
+	Operator string      `json:"operator"`
+	Value    interface{} `json:"value"`
+}
+
+// VectorSearchRequest searches a vector column by similarity to Query, which is
+// embedded by the server, or to Vector
+type VectorSearchRequest struct {
+	Table          string         `json:"table"`
+	Column         string         `json:"column"`
+	Query          string         `json:"query,omitempty"`
+	Vector         []float64      `json:"vector,omitempty"`
+	Metric         string         `json:"metric,omitempty"` // l2, cosine or inner_product
+	MatchThreshold *float64       `json:"match_threshold,omitempty"`
+	MatchCount     *int           `json:"match_count,omitempty"`
+	Select         string         `json:"select,omitempty"`
+	Filters        []VectorFilter `json:"filters,omitempty"`
+}
+
+// VectorSearchResponse holds the matching rows, most similar first
+type VectorSearchResponse struct {
+	Data      []map[string]interface{} `json:"data"`
+	Distances []float64                `json:"distances,omitempty"`
+	Model     string                   `json:"model,omitempty"`
+}
+
+// ListChatbots returns the public, enabled chatbots
+func (s *AIService) ListChatbots(ctx context.Context) ([]Chatbot, error) {
+	var result struct {
+		Chatbots []Chatbot `json:"chatbots"`
+	}
+	if _, err := s.client.do(ctx, request{method: http.MethodGet, path: "/api/v1/ai/chatbots"}, &result); err != nil {
+		return nil, err
+	}
+	return result.Chatbots, nil
+}
+
+// ListConversations returns the current user's conversations, most recent first
+func (s *AIService) ListConversations(ctx context.Context, opts ListConversationsOptions) (*ConversationList, error) {
+	query := make(url.Values)
+	if opts.Chatbot != "" {
+		query.Set("chatbot", opts.Chatbot)
+	}
+	if opts.Namespace != "" {
+		query.Set("namespace", opts.Namespace)
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		query.Set("offset", strconv.Itoa(opts.Offset))
+	}
+	var list ConversationList
+	if _, err := s.client.do(ctx, request{method: http.MethodGet, path: "/api/v1/ai/conversations", query: query}, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetConversation returns a conversation of the current user with its messages
+func (s *AIService) GetConversation(ctx context.Context, id string) (*Conversation, error) {
+	var conversation Conversation
+	if _, err := s.client.do(ctx, request{method: http.MethodGet, path: "/api/v1/ai/conversations/" + id}, &conversation); err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// DeleteConversation deletes a conversation of the current user
+func (s *AIService) DeleteConversation(ctx context.Context, id string) error {
+	_, err := s.client.do(ctx, request{method: http.MethodDelete, path: "/api/v1/ai/conversations/" + id}, nil)
+	return err
+}
+
+// Embeddings embeds texts with the configured embedding provider. Requests count
+// against the caller's embedding quota.
+func (s *AIService) Embeddings(ctx context.Context, req EmbeddingsRequest) (*EmbeddingsResponse, error) {
+	var resp EmbeddingsResponse
+	if _, err := s.client.do(ctx, request{method: http.MethodPost, path: "/api/v1/ai/embeddings", body: req}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// VectorSearch runs a similarity search on a vector column
+func (s *AIService) VectorSearch(ctx context.Context, req VectorSearchRequest) (*VectorSearchResponse, error) {
+	var resp VectorSearchResponse
+	if _, err := s.client.do(ctx, request{method: http.MethodPost, path: "/api/v1/vector/search", body: req}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// AuthService calls the application auth endpoints under /api/v1/auth. Calls acting on
+// the current user (GetUser, UpdateUser, SignOut) authenticate with the client's access
+// token; use Client.WithAccessToken to act as a signed in user.
+type AuthService struct {
+	client *Client
+}
+
+// User is an application user
+type User struct {
+	ID            string                 `json:"id"`
+	Email         string                 `json:"email"`
+	EmailVerified bool                   `json:"email_verified"`
+	Role          string                 `json:"role,omitempty"`
+	UserMetadata  map[string]interface{} `json:"user_metadata,omitempty"`
+	AppMetadata   map[string]interface{} `json:"app_metadata,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+}
+
+// Session is the result of signing up, signing in or verifying a 2FA code. When
+// Requires2FA is set, the tokens are empty and the sign in must be completed with
+// AuthService.Verify2FA.
+type Session struct {
+	User         *User  `json:"user,omitempty"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"` // seconds
+	TrustToken   string `json:"trust_token,omitempty"`
+
+	Requires2FA               bool   `json:"requires_2fa,omitempty"`
+	UserID                    string `json:"user_id,omitempty"`
+	RequiresEmailVerification bool   `json:"requires_email_verification,omitempty"`
+}
+
+// SignUpRequest registers a user with email and password
+type SignUpRequest struct {
+	Email        string                 `json:"email"`
+	Password     string                 `json:"password"`
+	UserMetadata map[string]interface{} `json:"user_metadata,omitempty"`
+	CaptchaToken string                 `json:"captcha_token,omitempty"`
+}
+
+// UpdateUserRequest changes the current user. Nil fields are left unchanged.
+type UpdateUserRequest struct {
+	Email        *string                `json:"email,omitempty"`
+	UserMetadata map[string]interface{} `json:"user_metadata,omitempty"`
+}
+
+// SignUp registers a new user. The session has no tokens when email verification is required.
+func (s *AuthService) SignUp(ctx context.Context, req SignUpRequest) (*Session, error) {
+	var session Session
+	if _, err := s.client.do(ctx, request{method: http.MethodPost, path: "/api/v1/auth/signup", body: req}, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// SignIn authenticates a user with email and password
+func (s *AuthService) SignIn(ctx context.Context, email, password string) (*Session, error) {
+	body := map[string]string{"email": email, "password": password}
+	var session Session
+	if _, err := s.client.do(ctx, request{method: http.MethodPost, path: "/api/v1/auth/signin", body: body}, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Verify2FA completes a sign in that returned Requires2FA with a TOTP or backup code
+func (s *AuthService) Verify2FA(ctx context.Context, userID, code string) (*Session, error) {
+	body := map[string]string{"user_id": userID, "code": code}
+	var session Session
+	if _, err := s.client.do(ctx, request{method: http.MethodPost, path: "/api/v1/auth/2fa/verify", body: body}, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Refresh exchanges a refresh token for new tokens. Refresh tokens are rotated, so the
+// returned refresh token replaces the old one.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*Session, error) {
+	body := map[string]string{"refresh_token": refreshToken}
+	var session Session
+	if _, err := s.client.do(ctx, request{method: http.MethodPost, path: "/api/v1/auth/refresh", body: body}, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// SignOut revokes the client's access token
+func (s *AuthService) SignOut(ctx context.Context) error {
+	_, err := s.client.do(ctx, request{method: http.MethodPost, path: "/api/v1/auth/signout"}, nil)
+	return err
+}
+
+// GetUser returns the user owning the client's access token
+func (s *AuthService) GetUser(ctx context.Context) (*User, error) {
+	var user User
+	if _, err := s.client.do(ctx, request{method: http.MethodGet, path: "/api/v1/auth/user"}, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateUser changes the user owning the client's access token
+func (s *AuthService) UpdateUser(ctx context.Context, req UpdateUserRequest) (*User, error) {
+	var user User
+	if _, err := s.client.do(ctx, request{method: http.MethodPatch, path: "/api/v1/auth/user", body: req}, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,218 @@
+// Package client is a Go SDK for the Fluxbase REST API.
+//
+// It covers the auth, tables, storage, functions and AI endpoints so services
+// can talk to a Fluxbase server without hand-rolling HTTP calls:
+//
+//	fb, err := client.New("https://fluxbase.example.com", client.WithServiceKey(key))
+//	if err != nil {
+//		return err
+//	}
+//	var posts []Post
+//	err = fb.From("posts").Select("id,title").Eq("published", true).Order("created_at", client.Desc).Execute(ctx, &posts)
+//
+// Failed requests return an *Error carrying the code from the API error catalog,
+// which can be matched with errors.Is against the Err* sentinels.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultUserAgent = "fluxbase-go/1.0"
+
+// Client is a Fluxbase API client. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	headers    http.Header
+
+	// Auth signs users in and manages the current user
+	Auth *AuthService
+	// Storage manages buckets and files
+	Storage *StorageService
+	// Functions invokes edge functions
+	Functions *FunctionsService
+	// AI exposes chatbots, conversations, embeddings and vector search
+	AI *AIService
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithClientKey authenticates requests with a client key (X-Client-Key header)
+func WithClientKey(key string) Option {
+	return WithHeader("X-Client-Key", key)
+}
+
+// WithServiceKey authenticates requests with a service key (X-Service-Key header),
+// bypassing row level security
+func WithServiceKey(key string) Option {
+	return WithHeader("X-Service-Key", key)
+}
+
+// WithAccessToken authenticates requests as a user with a JWT access token
+func WithAccessToken(token string) Option {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithHTTPClient sets the underlying HTTP client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout sets the timeout of the default HTTP client
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithHeader sets a header sent with every request
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.headers.Set(key, value)
+	}
+}
+
+// WithUserAgent sets the User-Agent header
+func WithUserAgent(userAgent string) Option {
+	return WithHeader("User-Agent", userAgent)
+}
+
+// New creates a client for the Fluxbase server at baseURL
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: scheme and host are required", baseURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		headers:    make(http.Header),
+	}
+	c.headers.Set("User-Agent", defaultUserAgent)
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.Auth = &AuthService{client: c}
+	c.Storage = &StorageService{client: c}
+	c.Functions = &FunctionsService{client: c}
+	c.AI = &AIService{client: c}
+	return c, nil
+}
+
+// WithAccessToken returns a copy of the client that acts as the user owning token.
+// Services use it to forward an end user's identity so row level security applies.
+func (c *Client) WithAccessToken(token string) *Client {
+	clone := *c
+	clone.headers = c.headers.Clone()
+	clone.headers.Del("X-Service-Key")
+	clone.headers.Set("Authorization", "Bearer "+token)
+	clone.Auth = &AuthService{client: &clone}
+	clone.Storage = &StorageService{client: &clone}
+	clone.Functions = &FunctionsService{client: &clone}
+	clone.AI = &AIService{client: &clone}
+	return &clone
+}
+
+// request describes a single API call
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	// body is encoded as JSON unless it is an io.Reader, which is sent as is
+	body interface{}
+}
+
+// do sends req and decodes a successful JSON response into target. Error responses are
+// returned as *Error. The response is returned with its body closed.
+func (c *Client) do(ctx context.Context, req request, target interface{}) (*http.Response, error) {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if target == nil || resp.StatusCode == http.StatusNoContent {
+		return resp, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return resp, nil
+	}
+	if err := json.Unmarshal(body, target); err != nil {
+		return resp, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return resp, nil
+}
+
+// send sends req and returns the open response of a successful request
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	// Paths are unescaped here; URL.String escapes each segment
+	u := *c.baseURL
+	u.Path += req.path
+	if len(req.query) > 0 {
+		u.RawQuery = req.query.Encode()
+	}
+
+	var body io.Reader
+	contentType := ""
+	switch b := req.body.(type) {
+	case nil:
+	case io.Reader:
+		body = b
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		body = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range c.headers {
+		httpReq.Header[key] = values
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	for key, values := range req.header {
+		httpReq.Header[key] = values
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		defer func() { _ = resp.Body.Close() }()
+		return nil, parseError(resp)
+	}
+	return resp, nil
+}
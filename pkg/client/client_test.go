@@ -0,0 +1,308 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	c, err := New(server.URL, opts...)
+	require.NoError(t, err)
+	return c
+}
+
+func TestNew_InvalidBaseURL(t *testing.T) {
+	_, err := New("localhost:8080")
+	assert.Error(t, err)
+
+	_, err = New("://")
+	assert.Error(t, err)
+}
+
+func TestQueryBuilder_Execute(t *testing.T) {
+	var got *http.Request
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.Header().Set("Content-Range", "0-1/42")
+		_, _ = w.Write([]byte(`[{"id":1,"title":"a"},{"id":2,"title":"b"}]`))
+	}, WithServiceKey("service-key"))
+
+	var rows []struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+	}
+	count, err := c.From("posts").
+		Select("id,title,author:users(name)").
+		Eq("published", true).
+		Gte("created_at", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)).
+		In("status", "draft", "review").
+		Is("deleted_at", nil).
+		Not("category", "eq", "spam").
+		Or("views.gt.100,pinned.is.true").
+		Order("created_at", Desc).
+		Order("id", Asc).
+		Limit(2).
+		Offset(4).
+		Count(CountExact).
+		Param("author.name", "neq.bot").
+		ExecuteWithCount(context.Background(), &rows)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(42), count)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, http.MethodGet, got.Method)
+	assert.Equal(t, "/api/v1/tables/posts", got.URL.Path)
+	assert.Equal(t, "service-key", got.Header.Get("X-Service-Key"))
+	assert.Empty(t, got.Header.Get("Accept-Profile"))
+
+	query := got.URL.Query()
+	assert.Equal(t, "id,title,author:users(name)", query.Get("select"))
+	assert.Equal(t, "eq.true", query.Get("published"))
+	assert.Equal(t, "gte.2026-01-02T03:04:05Z", query.Get("created_at"))
+	assert.Equal(t, "in.(draft,review)", query.Get("status"))
+	assert.Equal(t, "is.null", query.Get("deleted_at"))
+	assert.Equal(t, "not.eq.spam", query.Get("category"))
+	assert.Equal(t, "(views.gt.100,pinned.is.true)", query.Get("or"))
+	assert.Equal(t, "created_at.desc,id.asc", query.Get("order"))
+	assert.Equal(t, "2", query.Get("limit"))
+	assert.Equal(t, "4", query.Get("offset"))
+	assert.Equal(t, "exact", query.Get("count"))
+	assert.Equal(t, "neq.bot", query.Get("author.name"))
+}
+
+func TestQueryBuilder_Schema(t *testing.T) {
+	var got *http.Request
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	require.NoError(t, c.From("analytics.events").Execute(context.Background(), nil))
+	assert.Equal(t, "/api/v1/tables/events", got.URL.Path)
+	assert.Equal(t, "analytics", got.Header.Get("Accept-Profile"))
+
+	require.NoError(t, c.From("events").Schema("analytics").Eq("id", 1).Delete(context.Background(), nil))
+	assert.Equal(t, http.MethodDelete, got.Method)
+	assert.Equal(t, "analytics", got.Header.Get("Content-Profile"))
+	assert.Empty(t, got.Header.Get("Accept-Profile"))
+}
+
+func TestQueryBuilder_Writes(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(`{"id":"abc"}`))
+	})
+	ctx := context.Background()
+	var row map[string]interface{}
+
+	require.NoError(t, c.From("items").Upsert(ctx, []map[string]interface{}{{"sku": "a"}}, "sku", &row))
+	assert.Equal(t, http.MethodPost, got.Method)
+	assert.Equal(t, "resolution=merge-duplicates", got.Header.Get("Prefer"))
+	assert.Equal(t, "sku", got.URL.Query().Get("on_conflict"))
+	assert.JSONEq(t, `[{"sku":"a"}]`, string(body))
+	assert.Equal(t, "abc", row["id"])
+
+	require.NoError(t, c.From("items").Eq("sku", "a").Update(ctx, map[string]interface{}{"stock": 3}, nil))
+	assert.Equal(t, http.MethodPatch, got.Method)
+	assert.Equal(t, "application/json", got.Header.Get("Content-Type"))
+	assert.Equal(t, "eq.a", got.URL.Query().Get("sku"))
+
+	require.NoError(t, c.From("items").MergePatch(ctx, "abc", map[string]interface{}{"settings": map[string]interface{}{"theme": nil}}, nil))
+	assert.Equal(t, "/api/v1/tables/items/abc", got.URL.Path)
+	assert.Equal(t, MergePatchContentType, got.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"settings":{"theme":null}}`, string(body))
+
+	ops := []PatchOperation{
+		{Op: "replace", Path: "/settings/enabled", Value: false},
+		{Op: "move", From: "/settings/a", Path: "/settings/b"},
+		{Op: "remove", Path: "/settings/c"},
+	}
+	require.NoError(t, c.From("items").JSONPatch(ctx, "abc", ops, nil))
+	assert.Equal(t, JSONPatchContentType, got.Header.Get("Content-Type"))
+	assert.JSONEq(t, `[
+		{"op":"replace","path":"/settings/enabled","value":false},
+		{"op":"move","from":"/settings/a","path":"/settings/b"},
+		{"op":"remove","path":"/settings/c"}
+	]`, string(body))
+
+	require.NoError(t, c.From("items").DeleteByID(ctx, 7))
+	assert.Equal(t, http.MethodDelete, got.Method)
+	assert.Equal(t, "/api/v1/tables/items/7", got.URL.Path)
+}
+
+func TestErrors(t *testing.T) {
+	status, response := http.StatusNotFound, `{"error":"Record not found","code":"NOT_FOUND","hint":"check the id","request_id":"req-1"}`
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	})
+	ctx := context.Background()
+
+	err := c.From("items").Get(ctx, 1, nil)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.False(t, errors.Is(err, ErrConflict))
+	assert.Equal(t, CodeNotFound, ErrorCode(err))
+	assert.Equal(t, http.StatusNotFound, StatusCode(err))
+
+	var apiErr *Error
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "check the id", apiErr.Hint)
+	assert.Equal(t, "req-1", apiErr.RequestID)
+	assert.Equal(t, "fluxbase: Record not found (404 NOT_FOUND)", err.Error())
+
+	// Endpoints without error codes match the status sentinels
+	status, response = http.StatusForbidden, `{"error":"insufficient permissions to upload file"}`
+	err = c.From("items").Get(ctx, 1, nil)
+	assert.True(t, errors.Is(err, ErrForbidden))
+	assert.False(t, errors.Is(err, ErrRLSViolation))
+	assert.Empty(t, ErrorCode(err))
+
+	// Non-JSON bodies become the message
+	status, response = http.StatusBadGateway, "upstream unavailable"
+	err = c.From("items").Get(ctx, 1, nil)
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "upstream unavailable", apiErr.Message)
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+
+	assert.Equal(t, 0, StatusCode(errors.New("other")))
+}
+
+func TestAuth(t *testing.T) {
+	var got *http.Request
+	var body map[string]string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch r.URL.Path {
+		case "/api/v1/auth/signin":
+			_, _ = w.Write([]byte(`{"requires_2fa":true,"user_id":"u1","message":"2FA verification required"}`))
+		case "/api/v1/auth/2fa/verify":
+			_, _ = w.Write([]byte(`{"user":{"id":"u1","email":"a@example.com"},"access_token":"at","refresh_token":"rt","expires_in":900}`))
+		case "/api/v1/auth/user":
+			_, _ = w.Write([]byte(`{"id":"u1","email":"a@example.com","email_verified":true}`))
+		}
+	}, WithServiceKey("service-key"))
+	ctx := context.Background()
+
+	session, err := c.Auth.SignIn(ctx, "a@example.com", "secret")
+	require.NoError(t, err)
+	assert.True(t, session.Requires2FA)
+	assert.Equal(t, "u1", session.UserID)
+	assert.Equal(t, map[string]string{"email": "a@example.com", "password": "secret"}, body)
+
+	session, err = c.Auth.Verify2FA(ctx, session.UserID, "123456")
+	require.NoError(t, err)
+	assert.Equal(t, "at", session.AccessToken)
+	assert.Equal(t, int64(900), session.ExpiresIn)
+
+	user, err := c.WithAccessToken(session.AccessToken).Auth.GetUser(ctx)
+	require.NoError(t, err)
+	assert.True(t, user.EmailVerified)
+	assert.Equal(t, "Bearer at", got.Header.Get("Authorization"))
+	assert.Empty(t, got.Header.Get("X-Service-Key"), "user clients must not send the service key")
+
+	// The original client is unchanged
+	_, err = c.Auth.GetUser(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, got.Header.Get("Authorization"))
+}
+
+func TestStorage_UploadAndDownload(t *testing.T) {
+	var uploaded []byte
+	var uploadedType, uploadedName string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			assert.Equal(t, "/api/v1/storage/avatars/users/u1/photo 1.png", r.URL.Path)
+			file, header, err := r.FormFile("file")
+			if !assert.NoError(t, err) {
+				return
+			}
+			uploaded, _ = io.ReadAll(file)
+			uploadedType = header.Header.Get("Content-Type")
+			uploadedName = header.Filename
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"key":"users/u1/photo 1.png","bucket":"avatars","size":5}`))
+		case http.MethodGet:
+			_, _ = w.Write([]byte("image"))
+		}
+	})
+	ctx := context.Background()
+
+	object, err := c.Storage.Upload(ctx, "avatars", "users/u1/photo 1.png", strings.NewReader("image"), "image/png")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), object.Size)
+	assert.Equal(t, "image", string(uploaded))
+	assert.Equal(t, "image/png", uploadedType)
+	assert.Equal(t, "photo 1.png", uploadedName)
+
+	reader, err := c.Storage.Download(ctx, "avatars", "users/u1/photo 1.png")
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "image", string(data))
+}
+
+func TestFunctions_Invoke(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/functions/resize/invoke", r.URL.Path)
+		assert.Equal(t, "media", r.URL.Query().Get("namespace"))
+		assert.Equal(t, "1", r.Header.Get("X-Trace"))
+		w.Header().Set("X-Function", "resize")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"queued":true}`))
+	})
+
+	resp, err := c.Functions.Invoke(context.Background(), "resize", map[string]int{"width": 100}, &InvokeOptions{
+		Namespace: "media",
+		Header:    http.Header{"X-Trace": {"1"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Equal(t, "resize", resp.Header.Get("X-Function"))
+
+	var result struct {
+		Queued bool `json:"queued"`
+	}
+	require.NoError(t, resp.Decode(&result))
+	assert.True(t, result.Queued)
+}
+
+func TestAI_Embeddings(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/ai/embeddings", r.URL.Path)
+		var req EmbeddingsRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, []string{"a", "b"}, req.Texts)
+		_, _ = w.Write([]byte(`{"embeddings":[[0.1],[0.2]],"model":"m","dimensions":1,"batches":1,"usage":{"prompt_tokens":2,"total_tokens":2}}`))
+	})
+
+	resp, err := c.AI.Embeddings(context.Background(), EmbeddingsRequest{Texts: []string{"a", "b"}})
+	require.NoError(t, err)
+	assert.Len(t, resp.Embeddings, 2)
+	assert.Equal(t, 2, resp.Usage.TotalTokens)
+}
+
+func TestParseContentRangeTotal(t *testing.T) {
+	assert.Equal(t, int64(42), parseContentRangeTotal("0-9/42"))
+	assert.Equal(t, int64(-1), parseContentRangeTotal(""))
+	assert.Equal(t, int64(-1), parseContentRangeTotal("0-9/*"))
+}
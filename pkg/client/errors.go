@@ -0,0 +1,170 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Error codes returned in the "code" field of API error responses. They mirror the
+// server's error catalog.
+const (
+	// Authentication errors (401)
+	CodeMissingAuth        = "MISSING_AUTHENTICATION"
+	CodeInvalidToken       = "INVALID_TOKEN"
+	CodeExpiredToken       = "EXPIRED_TOKEN"
+	CodeRevokedToken       = "REVOKED_TOKEN"
+	CodeAuthRequired       = "AUTHENTICATION_REQUIRED"
+	CodeInvalidUserID      = "INVALID_USER_ID"
+	CodeAccountLocked      = "ACCOUNT_LOCKED"
+	CodeInvalidCredentials = "INVALID_CREDENTIALS" //nolint:gosec // Not a credential, just an error code constant
+
+	// Authorization errors (403)
+	CodeInsufficientPermissions = "INSUFFICIENT_PERMISSIONS"
+	CodeAdminRequired           = "ADMIN_REQUIRED"
+	CodeInvalidRole             = "INVALID_ROLE"
+	CodeRLSViolation            = "RLS_POLICY_VIOLATION"
+	CodeAccessDenied            = "ACCESS_DENIED"
+	CodeFeatureDisabled         = "FEATURE_DISABLED"
+
+	// Validation errors (400)
+	CodeInvalidBody      = "INVALID_REQUEST_BODY"
+	CodeMissingField     = "MISSING_REQUIRED_FIELD"
+	CodeInvalidInput     = "INVALID_INPUT"
+	CodeInvalidID        = "INVALID_ID"
+	CodeInvalidFormat    = "INVALID_FORMAT"
+	CodeValidationFailed = "VALIDATION_FAILED"
+
+	// Resource errors (404, 409)
+	CodeNotFound            = "NOT_FOUND"
+	CodeAlreadyExists       = "ALREADY_EXISTS"
+	CodeDuplicateKey        = "DUPLICATE_KEY"
+	CodeConflict            = "CONFLICT"
+	CodeForeignKeyViolation = "FOREIGN_KEY_VIOLATION"
+
+	// Constraint errors (400)
+	CodeNotNullViolation = "NOT_NULL_VIOLATION"
+	CodeCheckViolation   = "CHECK_VIOLATION"
+
+	// Server errors (500)
+	CodeInternalError   = "INTERNAL_ERROR"
+	CodeDatabaseError   = "DATABASE_ERROR"
+	CodeOperationFailed = "OPERATION_FAILED"
+
+	// Rate limiting (429)
+	CodeRateLimited     = "RATE_LIMIT_EXCEEDED"
+	CodeTooManyRequests = "TOO_MANY_REQUESTS"
+
+	// Setup/config errors
+	CodeSetupRequired     = "SETUP_REQUIRED"
+	CodeSetupCompleted    = "SETUP_ALREADY_COMPLETED"
+	CodeSetupDisabled     = "SETUP_DISABLED"
+	CodeInvalidSetupToken = "INVALID_SETUP_TOKEN"
+)
+
+// Sentinel errors for matching API errors with errors.Is. Most of them match by code;
+// ErrUnauthorized, ErrForbidden, ErrNotFound, ErrConflict and ErrRateLimited also match
+// responses without a code by their HTTP status.
+var (
+	ErrUnauthorized        = &Error{StatusCode: http.StatusUnauthorized, Code: CodeAuthRequired}
+	ErrInvalidToken        = &Error{StatusCode: http.StatusUnauthorized, Code: CodeInvalidToken}
+	ErrExpiredToken        = &Error{StatusCode: http.StatusUnauthorized, Code: CodeExpiredToken}
+	ErrInvalidCredentials  = &Error{StatusCode: http.StatusUnauthorized, Code: CodeInvalidCredentials}
+	ErrForbidden           = &Error{StatusCode: http.StatusForbidden, Code: CodeInsufficientPermissions}
+	ErrRLSViolation        = &Error{StatusCode: http.StatusForbidden, Code: CodeRLSViolation}
+	ErrFeatureDisabled     = &Error{StatusCode: http.StatusForbidden, Code: CodeFeatureDisabled}
+	ErrValidationFailed    = &Error{StatusCode: http.StatusBadRequest, Code: CodeValidationFailed}
+	ErrNotFound            = &Error{StatusCode: http.StatusNotFound, Code: CodeNotFound}
+	ErrConflict            = &Error{StatusCode: http.StatusConflict, Code: CodeConflict}
+	ErrDuplicateKey        = &Error{StatusCode: http.StatusConflict, Code: CodeDuplicateKey}
+	ErrForeignKeyViolation = &Error{StatusCode: http.StatusConflict, Code: CodeForeignKeyViolation}
+	ErrRateLimited         = &Error{StatusCode: http.StatusTooManyRequests, Code: CodeRateLimited}
+)
+
+// statusSentinels match error responses without a code by HTTP status
+var statusSentinels = map[*Error]bool{
+	ErrUnauthorized: true,
+	ErrForbidden:    true,
+	ErrNotFound:     true,
+	ErrConflict:     true,
+	ErrRateLimited:  true,
+}
+
+// Error is an error response from the Fluxbase API
+type Error struct {
+	// StatusCode is the HTTP status of the response
+	StatusCode int `json:"-"`
+	// Message is the "error" field, the human readable error
+	Message string `json:"error"`
+	// Code is one of the Code* constants, or empty for endpoints without an error code
+	Code      string      `json:"code,omitempty"`
+	Detail    string      `json:"message,omitempty"`
+	Hint      string      `json:"hint,omitempty"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	if e.Detail != "" && e.Detail != msg {
+		msg += ": " + e.Detail
+	}
+	if e.Code != "" {
+		return fmt.Sprintf("fluxbase: %s (%d %s)", msg, e.StatusCode, e.Code)
+	}
+	return fmt.Sprintf("fluxbase: %s (%d)", msg, e.StatusCode)
+}
+
+// Is matches errors by code, so errors.Is(err, client.ErrNotFound) works for any not found
+// response. Sentinels in statusSentinels also match uncoded responses with their status.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	if e.Code != "" {
+		return e.Code == t.Code
+	}
+	return statusSentinels[t] && e.StatusCode == t.StatusCode
+}
+
+// ErrorCode returns the API error code of err, or "" if err is not an API error
+func ErrorCode(err error) string {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return ""
+}
+
+// StatusCode returns the HTTP status of an API error, or 0 if err is not an API error
+func StatusCode(err error) int {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// parseError reads an error response body into an *Error
+func parseError(resp *http.Response) error {
+	apiErr := &Error{StatusCode: resp.StatusCode}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		apiErr.Message = fmt.Sprintf("failed to read error response: %v", err)
+		return apiErr
+	}
+	if err := json.Unmarshal(body, apiErr); err != nil {
+		apiErr.Message = string(body)
+	}
+	apiErr.StatusCode = resp.StatusCode
+	if apiErr.RequestID == "" {
+		apiErr.RequestID = resp.Header.Get("X-Request-ID")
+	}
+	return apiErr
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// FunctionsService invokes edge functions under /api/v1/functions
+type FunctionsService struct {
+	client *Client
+}
+
+// InvokeOptions configures a function invocation
+type InvokeOptions struct {
+	// Namespace selects the function when the name exists in several namespaces
+	Namespace string
+	// Method is the HTTP method, POST by default
+	Method string
+	// Header is passed to the function
+	Header http.Header
+}
+
+// FunctionResponse is the response returned by a function
+type FunctionResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Decode decodes a JSON response body into target
+func (r *FunctionResponse) Decode(target interface{}) error {
+	if err := json.Unmarshal(r.Body, target); err != nil {
+		return fmt.Errorf("failed to decode function response: %w", err)
+	}
+	return nil
+}
+
+// Invoke calls the function name with payload encoded as JSON. Failed executions and
+// error statuses returned by the function are reported as *Error.
+func (s *FunctionsService) Invoke(ctx context.Context, name string, payload interface{}, opts *InvokeOptions) (*FunctionResponse, error) {
+	if opts == nil {
+		opts = &InvokeOptions{}
+	}
+	req := request{
+		method: http.MethodPost,
+		path:   "/api/v1/functions/" + name + "/invoke",
+		header: opts.Header.Clone(),
+		body:   payload,
+	}
+	if opts.Method != "" {
+		req.method = opts.Method
+	}
+	if opts.Namespace != "" {
+		req.query = url.Values{"namespace": {opts.Namespace}}
+	}
+
+	resp, err := s.client.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read function response: %w", err)
+	}
+	return &FunctionResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: body}, nil
+}
@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StorageService calls the storage endpoints under /api/v1/storage
+type StorageService struct {
+	client *Client
+}
+
+// Bucket is a storage bucket
+type Bucket struct {
+	ID                 string    `json:"id"`
+	Name               string    `json:"name"`
+	Public             bool      `json:"public"`
+	AllowedMimeTypes   []string  `json:"allowed_mime_types"`
+	MaxFileSize        *int64    `json:"max_file_size"`
+	ContentDisposition string    `json:"content_disposition"`
+	InlineMimeTypes    []string  `json:"inline_mime_types"`
+	NoSniff            bool      `json:"nosniff"`
+	HTMLPolicy         string    `json:"html_policy"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// BucketOptions configures a new bucket. Nil download settings use the server's secure defaults.
+type BucketOptions struct {
+	Public             bool     `json:"public"`
+	AllowedMimeTypes   []string `json:"allowed_mime_types,omitempty"`
+	MaxFileSize        *int64   `json:"max_file_size,omitempty"`
+	ContentDisposition *string  `json:"content_disposition,omitempty"`
+	InlineMimeTypes    []string `json:"inline_mime_types,omitempty"`
+	NoSniff            *bool    `json:"nosniff,omitempty"`
+	HTMLPolicy         *string  `json:"html_policy,omitempty"`
+}
+
+// Object is a stored file
+type Object struct {
+	ID        string                 `json:"id"`
+	Bucket    string                 `json:"bucket"`
+	Path      string                 `json:"path"`
+	MimeType  *string                `json:"mime_type"`
+	Size      int64                  `json:"size"`
+	Metadata  map[string]interface{} `json:"metadata"`
+	OwnerID   *string                `json:"owner_id"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+// UploadedObject describes a file after upload
+type UploadedObject struct {
+	Key          string    `json:"key"`
+	Bucket       string    `json:"bucket"`
+	Size         int64     `json:"size"`
+	ContentType  string    `json:"content_type"`
+	LastModified time.Time `json:"last_modified"`
+	OwnerID      string    `json:"owner_id,omitempty"`
+	// PublicURL is the CDN URL of the object if its bucket is served by a CDN
+	PublicURL string `json:"public_url,omitempty"`
+}
+
+// ListOptions filters and pages a file listing
+type ListOptions struct {
+	Prefix string
+	// Delimiter groups keys below the prefix into ObjectList.Prefixes, like directories
+	Delimiter string
+	Limit     int
+	Offset    int
+}
+
+// ObjectList is a page of files in a bucket
+type ObjectList struct {
+	Bucket   string   `json:"bucket"`
+	Objects  []Object `json:"objects"`
+	Count    int      `json:"count"`
+	Prefix   string   `json:"prefix,omitempty"`
+	Prefixes []string `json:"prefixes,omitempty"`
+}
+
+// SignedURL is a time limited URL for a file
+type SignedURL struct {
+	SignedURL string `json:"signed_url"`
+	ExpiresIn int    `json:"expires_in"`
+	Method    string `json:"method"`
+}
+
+// ListBuckets returns the buckets visible to the caller
+func (s *StorageService) ListBuckets(ctx context.Context) ([]Bucket, error) {
+	var result struct {
+		Buckets []Bucket `json:"buckets"`
+	}
+	if _, err := s.client.do(ctx, request{method: http.MethodGet, path: "/api/v1/storage/buckets"}, &result); err != nil {
+		return nil, err
+	}
+	return result.Buckets, nil
+}
+
+// CreateBucket creates a bucket
+func (s *StorageService) CreateBucket(ctx context.Context, name string, opts BucketOptions) error {
+	_, err := s.client.do(ctx, request{method: http.MethodPost, path: "/api/v1/storage/buckets/" + name, body: opts}, nil)
+	return err
+}
+
+// DeleteBucket deletes an empty bucket
+func (s *StorageService) DeleteBucket(ctx context.Context, name string) error {
+	_, err := s.client.do(ctx, request{method: http.MethodDelete, path: "/api/v1/storage/buckets/" + name}, nil)
+	return err
+}
+
+// List returns the files of a bucket
+func (s *StorageService) List(ctx context.Context, bucket string, opts ListOptions) (*ObjectList, error) {
+	query := make(url.Values)
+	if opts.Prefix != "" {
+		query.Set("prefix", opts.Prefix)
+	}
+	if opts.Delimiter != "" {
+		query.Set("delimiter", opts.Delimiter)
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		query.Set("offset", strconv.Itoa(opts.Offset))
+	}
+	var list ObjectList
+	if _, err := s.client.do(ctx, request{method: http.MethodGet, path: "/api/v1/storage/" + bucket, query: query}, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// Upload stores the contents of r at key. An empty contentType is detected by the server
+// from the file extension.
+func (s *StorageService) Upload(ctx context.Context, bucket, key string, r io.Reader, contentType string) (*UploadedObject, error) {
+	// Stream the multipart body instead of buffering the file
+	pr, pw := io.Pipe()
+	form := multipart.NewWriter(pw)
+	go func() {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, path.Base(key)))
+		if contentType != "" {
+			header.Set("Content-Type", contentType)
+		}
+		part, err := form.CreatePart(header)
+		if err == nil {
+			_, err = io.Copy(part, r)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	req := request{method: http.MethodPost, path: "/api/v1/storage/" + bucket + "/" + strings.TrimPrefix(key, "/"), header: make(http.Header), body: pr}
+	req.header.Set("Content-Type", form.FormDataContentType())
+
+	var object UploadedObject
+	_, err := s.client.do(ctx, req, &object)
+	// Unblock the writer if the request failed before reading the whole body
+	_ = pr.CloseWithError(io.ErrClosedPipe)
+	if err != nil {
+		return nil, err
+	}
+	return &object, nil
+}
+
+// Download returns the contents of the file at key. The caller must close the reader.
+func (s *StorageService) Download(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	resp, err := s.client.send(ctx, request{method: http.MethodGet, path: "/api/v1/storage/" + bucket + "/" + strings.TrimPrefix(key, "/")})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Remove deletes the file at key
+func (s *StorageService) Remove(ctx context.Context, bucket, key string) error {
+	_, err := s.client.do(ctx, request{method: http.MethodDelete, path: "/api/v1/storage/" + bucket + "/" + strings.TrimPrefix(key, "/")}, nil)
+	return err
+}
+
+// CreateSignedURL returns a URL granting GET access to the file at key for expiresIn
+func (s *StorageService) CreateSignedURL(ctx context.Context, bucket, key string, expiresIn time.Duration) (*SignedURL, error) {
+	body := map[string]interface{}{"expires_in": int(expiresIn.Seconds()), "method": http.MethodGet}
+	var signed SignedURL
+	if _, err := s.client.do(ctx, request{method: http.MethodPost, path: "/api/v1/storage/" + bucket + "/sign/" + strings.TrimPrefix(key, "/"), body: body}, &signed); err != nil {
+		return nil, err
+	}
+	return &signed, nil
+}
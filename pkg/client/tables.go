@@ -0,0 +1,348 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Direction is the sort direction of an order clause
+type Direction string
+
+// Sort directions
+const (
+	Asc  Direction = "asc"
+	Desc Direction = "desc"
+)
+
+// Count modes for QueryBuilder.Count
+const (
+	CountExact     = "exact"
+	CountPlanned   = "planned"
+	CountEstimated = "estimated"
+)
+
+// Patch document content types sent by QueryBuilder.MergePatch and QueryBuilder.JSONPatch
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+// PatchOperation is a single JSON Patch (RFC 6902) operation
+type PatchOperation struct {
+	Op    string
+	Path  string
+	From  string
+	Value interface{}
+}
+
+// MarshalJSON encodes the operation with the members its op takes, so a nil or zero
+// Value is still sent for add, replace and test
+func (op PatchOperation) MarshalJSON() ([]byte, error) {
+	doc := map[string]interface{}{"op": op.Op, "path": op.Path}
+	switch op.Op {
+	case "add", "replace", "test":
+		doc["value"] = op.Value
+	case "move", "copy":
+		doc["from"] = op.From
+	}
+	return json.Marshal(doc)
+}
+
+// QueryBuilder builds a request against a table of the REST API. Its methods mirror the
+// query grammar: Eq("status", "active") sends status=eq.active. Builders are not safe for
+// concurrent use; create one per request with Client.From.
+type QueryBuilder struct {
+	client *Client
+	table  string
+	schema string
+	query  url.Values
+}
+
+// From starts a query on table. Qualify it as "schema.table" to address another schema.
+func (c *Client) From(table string) *QueryBuilder {
+	q := &QueryBuilder{client: c, table: table, query: make(url.Values)}
+	if schema, name, ok := strings.Cut(table, "."); ok {
+		q.schema, q.table = schema, name
+	}
+	return q
+}
+
+// Schema selects the schema of an unqualified table via the Accept-Profile and
+// Content-Profile headers
+func (q *QueryBuilder) Schema(schema string) *QueryBuilder {
+	q.schema = schema
+	return q
+}
+
+// Select sets the returned columns, including embedded relations: "id,title,author:users(name)"
+func (q *QueryBuilder) Select(columns string) *QueryBuilder {
+	q.query.Set("select", columns)
+	return q
+}
+
+// Filter adds a column=operator.value filter for any operator of the query grammar
+func (q *QueryBuilder) Filter(column, operator string, value interface{}) *QueryBuilder {
+	q.query.Add(column, operator+"."+formatValue(value))
+	return q
+}
+
+// Eq filters rows where column equals value
+func (q *QueryBuilder) Eq(column string, value interface{}) *QueryBuilder {
+	return q.Filter(column, "eq", value)
+}
+
+// Neq filters rows where column does not equal value
+func (q *QueryBuilder) Neq(column string, value interface{}) *QueryBuilder {
+	return q.Filter(column, "neq", value)
+}
+
+// Gt filters rows where column is greater than value
+func (q *QueryBuilder) Gt(column string, value interface{}) *QueryBuilder {
+	return q.Filter(column, "gt", value)
+}
+
+// Gte filters rows where column is greater than or equal to value
+func (q *QueryBuilder) Gte(column string, value interface{}) *QueryBuilder {
+	return q.Filter(column, "gte", value)
+}
+
+// Lt filters rows where column is less than value
+func (q *QueryBuilder) Lt(column string, value interface{}) *QueryBuilder {
+	return q.Filter(column, "lt", value)
+}
+
+// Lte filters rows where column is less than or equal to value
+func (q *QueryBuilder) Lte(column string, value interface{}) *QueryBuilder {
+	return q.Filter(column, "lte", value)
+}
+
+// Like filters rows where column matches a LIKE pattern
+func (q *QueryBuilder) Like(column, pattern string) *QueryBuilder {
+	return q.Filter(column, "like", pattern)
+}
+
+// ILike filters rows where column matches a case insensitive LIKE pattern
+func (q *QueryBuilder) ILike(column, pattern string) *QueryBuilder {
+	return q.Filter(column, "ilike", pattern)
+}
+
+// Is filters rows where column IS value, which must be nil, true or false
+func (q *QueryBuilder) Is(column string, value interface{}) *QueryBuilder {
+	return q.Filter(column, "is", value)
+}
+
+// In filters rows where column is one of values
+func (q *QueryBuilder) In(column string, values ...interface{}) *QueryBuilder {
+	return q.Filter(column, "in", listValue(values))
+}
+
+// NotIn filters rows where column is none of values
+func (q *QueryBuilder) NotIn(column string, values ...interface{}) *QueryBuilder {
+	return q.Filter(column, "nin", listValue(values))
+}
+
+// Contains filters rows where an array or JSONB column contains value
+func (q *QueryBuilder) Contains(column string, value interface{}) *QueryBuilder {
+	return q.Filter(column, "cs", value)
+}
+
+// TextSearch filters rows where a tsvector column matches a full text query
+func (q *QueryBuilder) TextSearch(column, query string) *QueryBuilder {
+	return q.Filter(column, "fts", query)
+}
+
+// Not negates a filter: Not("status", "eq", "deleted") sends status=not.eq.deleted
+func (q *QueryBuilder) Not(column, operator string, value interface{}) *QueryBuilder {
+	return q.Filter(column, "not", operator+"."+formatValue(value))
+}
+
+// Or adds a group of alternative conditions in the grammar's syntax: "status.eq.draft,author_id.is.null"
+func (q *QueryBuilder) Or(conditions string) *QueryBuilder {
+	q.query.Add("or", "("+conditions+")")
+	return q
+}
+
+// Order sorts by column. Repeated calls add further sort keys.
+func (q *QueryBuilder) Order(column string, direction Direction) *QueryBuilder {
+	clause := column + "." + string(direction)
+	if existing := q.query.Get("order"); existing != "" {
+		clause = existing + "," + clause
+	}
+	q.query.Set("order", clause)
+	return q
+}
+
+// Limit caps the number of returned rows
+func (q *QueryBuilder) Limit(limit int) *QueryBuilder {
+	q.query.Set("limit", strconv.Itoa(limit))
+	return q
+}
+
+// Offset skips the first offset rows
+func (q *QueryBuilder) Offset(offset int) *QueryBuilder {
+	q.query.Set("offset", strconv.Itoa(offset))
+	return q
+}
+
+// Count requests the total number of matching rows, returned by ExecuteWithCount
+func (q *QueryBuilder) Count(mode string) *QueryBuilder {
+	q.query.Set("count", mode)
+	return q
+}
+
+// Param sets a raw query parameter, for parts of the grammar without a builder method
+// such as embedded relation filters ("posts.published", "eq.true")
+func (q *QueryBuilder) Param(key, value string) *QueryBuilder {
+	q.query.Add(key, value)
+	return q
+}
+
+// Execute fetches the matching rows into target, usually a pointer to a slice
+func (q *QueryBuilder) Execute(ctx context.Context, target interface{}) error {
+	_, err := q.client.do(ctx, q.request(http.MethodGet, "", nil), target)
+	return err
+}
+
+// ExecuteWithCount fetches the matching rows into target and returns the total count
+// requested with Count, or -1 if the server did not report one
+func (q *QueryBuilder) ExecuteWithCount(ctx context.Context, target interface{}) (int64, error) {
+	resp, err := q.client.do(ctx, q.request(http.MethodGet, "", nil), target)
+	if err != nil {
+		return 0, err
+	}
+	return parseContentRangeTotal(resp.Header.Get("Content-Range")), nil
+}
+
+// Get fetches a single row by primary key into target
+func (q *QueryBuilder) Get(ctx context.Context, id interface{}, target interface{}) error {
+	_, err := q.client.do(ctx, q.request(http.MethodGet, formatValue(id), nil), target)
+	return err
+}
+
+// Insert inserts a row or a slice of rows and decodes the created rows into target
+func (q *QueryBuilder) Insert(ctx context.Context, rows interface{}, target interface{}) error {
+	_, err := q.client.do(ctx, q.request(http.MethodPost, "", rows), target)
+	return err
+}
+
+// Upsert inserts rows, updating the existing rows that conflict on onConflict (a comma
+// separated column list, or "" for the primary key)
+func (q *QueryBuilder) Upsert(ctx context.Context, rows interface{}, onConflict string, target interface{}) error {
+	if onConflict != "" {
+		q.query.Set("on_conflict", onConflict)
+	}
+	req := q.request(http.MethodPost, "", rows)
+	req.header.Set("Prefer", "resolution=merge-duplicates")
+	_, err := q.client.do(ctx, req, target)
+	return err
+}
+
+// Update sets values on every row matching the filters and decodes the updated rows into target
+func (q *QueryBuilder) Update(ctx context.Context, values interface{}, target interface{}) error {
+	_, err := q.client.do(ctx, q.request(http.MethodPatch, "", values), target)
+	return err
+}
+
+// UpdateByID sets values on the row with primary key id
+func (q *QueryBuilder) UpdateByID(ctx context.Context, id interface{}, values interface{}, target interface{}) error {
+	_, err := q.client.do(ctx, q.request(http.MethodPatch, formatValue(id), values), target)
+	return err
+}
+
+// MergePatch applies a JSON Merge Patch (RFC 7386) to the row with primary key id.
+// Nested objects are merged, so single keys of JSON columns can be changed.
+func (q *QueryBuilder) MergePatch(ctx context.Context, id interface{}, patch interface{}, target interface{}) error {
+	return q.patch(ctx, id, MergePatchContentType, patch, target)
+}
+
+// JSONPatch applies JSON Patch (RFC 6902) operations to the row with primary key id
+func (q *QueryBuilder) JSONPatch(ctx context.Context, id interface{}, ops []PatchOperation, target interface{}) error {
+	return q.patch(ctx, id, JSONPatchContentType, ops, target)
+}
+
+func (q *QueryBuilder) patch(ctx context.Context, id interface{}, contentType string, document interface{}, target interface{}) error {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("failed to marshal patch: %w", err)
+	}
+	req := q.request(http.MethodPatch, formatValue(id), bytes.NewReader(data))
+	req.header.Set("Content-Type", contentType)
+	_, err = q.client.do(ctx, req, target)
+	return err
+}
+
+// Delete deletes every row matching the filters and decodes the deleted rows into target,
+// which may be nil
+func (q *QueryBuilder) Delete(ctx context.Context, target interface{}) error {
+	_, err := q.client.do(ctx, q.request(http.MethodDelete, "", nil), target)
+	return err
+}
+
+// DeleteByID deletes the row with primary key id
+func (q *QueryBuilder) DeleteByID(ctx context.Context, id interface{}) error {
+	_, err := q.client.do(ctx, q.request(http.MethodDelete, formatValue(id), nil), nil)
+	return err
+}
+
+// request builds a request for the table, or for the row id when it is not empty
+func (q *QueryBuilder) request(method, id string, body interface{}) request {
+	path := "/api/v1/tables/" + q.table
+	if id != "" {
+		path += "/" + id
+	}
+	header := make(http.Header)
+	if q.schema != "" {
+		if method == http.MethodGet {
+			header.Set("Accept-Profile", q.schema)
+		} else {
+			header.Set("Content-Profile", q.schema)
+		}
+	}
+	return request{method: method, path: path, query: q.query, header: header, body: body}
+}
+
+// formatValue renders a filter value in the query grammar
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// listValue renders an in() list. The server splits lists on commas, so values must not
+// contain them.
+func listValue(values []interface{}) string {
+	items := make([]string, len(values))
+	for i, value := range values {
+		items[i] = formatValue(value)
+	}
+	return "(" + strings.Join(items, ",") + ")"
+}
+
+// parseContentRangeTotal returns the total of a "0-9/42" Content-Range header, or -1
+func parseContentRangeTotal(header string) int64 {
+	_, total, ok := strings.Cut(header, "/")
+	if !ok {
+		return -1
+	}
+	n, err := strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
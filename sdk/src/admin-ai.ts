@@ -4,9 +4,13 @@
  */
 
 import type { FluxbaseFetch } from "./fetch";
+import { appendConversationFilter } from "./ai";
 import type {
   AIChatbot,
   AIChatbotSummary,
+  AIConversationAttributes,
+  AIConversationBreakdownEntry,
+  AIConversationBreakdownOptions,
   AIProvider,
   CreateAIProviderRequest,
   UpdateAIProviderRequest,
@@ -23,7 +27,14 @@ import type {
   ChatbotKnowledgeBaseLink,
   LinkKnowledgeBaseRequest,
   UpdateChatbotKnowledgeBaseRequest,
+  ShadowRetrievalProfile,
+  ShadowRetrievalProfileRequest,
+  ShadowRetrievalRun,
+  ShadowRetrievalSummary,
   SearchKnowledgeBaseResponse,
+  ExplainKnowledgeBaseSearchRequest,
+  ExplainKnowledgeBaseSearchResponse,
+  KnowledgeBaseReindexJob,
   UpdateDocumentRequest,
   DeleteDocumentsByFilterRequest,
   DeleteDocumentsByFilterResponse,
@@ -217,6 +228,75 @@ export class FluxbaseAdminAI {
     }
   }
 
+  // ============================================================================
+  // CONVERSATION METADATA
+  // ============================================================================
+
+  /**
+   * Set the metadata and/or tags of a conversation. Each field that is set
+   * replaces the stored value.
+   *
+   * @param conversationId - Conversation ID
+   * @param attributes - Metadata and tags to store
+   * @returns Promise resolving to { data, error } tuple
+   *
+   * @example
+   * ```typescript
+   * await client.admin.ai.updateConversationAttributes('conv-uuid', {
+   *   tags: ['escalated', 'vip'],
+   * })
+   * ```
+   */
+  async updateConversationAttributes(
+    conversationId: string,
+    attributes: AIConversationAttributes,
+  ): Promise<{ data: null; error: Error | null }> {
+    try {
+      await this.fetch.patch(
+        `/api/v1/admin/ai/conversations/${conversationId}`,
+        attributes,
+      );
+      return { data: null, error: null };
+    } catch (error) {
+      return { data: null, error: error as Error };
+    }
+  }
+
+  /**
+   * Group conversations by a tag or metadata key, with token usage per value
+   *
+   * @param options - Dimension to group by and optional filters
+   * @returns Promise resolving to { data, error } tuple with one entry per value
+   *
+   * @example
+   * ```typescript
+   * const { data } = await client.admin.ai.getConversationBreakdown({
+   *   group_by: 'metadata.channel',
+   *   tags: ['campaign-spring'],
+   * })
+   * data?.forEach((entry) => console.log(entry.value, entry.conversations))
+   * ```
+   */
+  async getConversationBreakdown(
+    options: AIConversationBreakdownOptions,
+  ): Promise<{
+    data: AIConversationBreakdownEntry[] | null;
+    error: Error | null;
+  }> {
+    try {
+      const params = new URLSearchParams({ group_by: options.group_by });
+      if (options.chatbot_id) params.set("chatbot_id", options.chatbot_id);
+      appendConversationFilter(params, options);
+
+      const response = await this.fetch.get<{
+        breakdown: AIConversationBreakdownEntry[];
+      }>(`/api/v1/admin/ai/conversations/breakdown?${params.toString()}`);
+      return { data: response.breakdown, error: null };
+    } catch (error) {
+      return { data: null, error: error as Error };
+    }
+  }
+
   // ============================================================================
   // PROVIDER MANAGEMENT
   // ============================================================================
@@ -824,6 +904,107 @@ export class FluxbaseAdminAI {
     }
   }
 
+  /**
+   * Run a dry-run search that explains how each candidate chunk was scored
+   *
+   * Returns the query embedding and the top scoring chunks with their vector,
+   * keyword and boost scores, including chunks cut by the threshold, filters or
+   * max_chunks.
+   *
+   * @param knowledgeBaseId - Knowledge base ID
+   * @param request - Search parameters
+   * @returns Promise resolving to { data, error } tuple with the explanation
+   *
+   * @example
+   * ```typescript
+   * const { data, error } = await client.admin.ai.explainKnowledgeBaseSearch('kb-uuid', {
+   *   query: 'how to reset password',
+   *   mode: 'hybrid',
+   *   semantic_weight: 0.7,
+   * })
+   * if (data) {
+   *   for (const c of data.candidates) {
+   *     console.log(c.score, c.vector_similarity, c.text_rank, c.cut_reason ?? 'included')
+   *   }
+   * }
+   * ```
+   */
+  async explainKnowledgeBaseSearch(
+    knowledgeBaseId: string,
+    request: ExplainKnowledgeBaseSearchRequest,
+  ): Promise<{
+    data: ExplainKnowledgeBaseSearchResponse | null;
+    error: Error | null;
+  }> {
+    try {
+      const data = await this.fetch.post<ExplainKnowledgeBaseSearchResponse>(
+        `/api/v1/admin/ai/knowledge-bases/${knowledgeBaseId}/search/debug`,
+        request,
+      );
+      return { data, error: null };
+    } catch (error) {
+      return { data: null, error: error as Error };
+    }
+  }
+
+  /**
+   * Re-chunk and re-embed all documents of a knowledge base in the background
+   *
+   * Use after changing chunk_size, chunk_overlap or the embedding model. Documents are
+   * marked pending and flip to indexed as the job reaches them.
+   *
+   * @param knowledgeBaseId - Knowledge base ID
+   * @returns Promise resolving to { data, error } tuple with the started job
+   *
+   * @example
+   * ```typescript
+   * const { data, error } = await client.admin.ai.reindexKnowledgeBase('kb-uuid')
+   * if (data) {
+   *   console.log('Re-indexing', data.total_documents, 'documents')
+   * }
+   * ```
+   */
+  async reindexKnowledgeBase(
+    knowledgeBaseId: string,
+  ): Promise<{ data: KnowledgeBaseReindexJob | null; error: Error | null }> {
+    try {
+      const data = await this.fetch.post<KnowledgeBaseReindexJob>(
+        `/api/v1/admin/ai/knowledge-bases/${knowledgeBaseId}/reindex`,
+        {},
+      );
+      return { data, error: null };
+    } catch (error) {
+      return { data: null, error: error as Error };
+    }
+  }
+
+  /**
+   * Get the progress of a knowledge base's latest re-index
+   *
+   * @param knowledgeBaseId - Knowledge base ID
+   * @returns Promise resolving to { data, error } tuple with the latest job
+   *
+   * @example
+   * ```typescript
+   * const { data } = await client.admin.ai.getKnowledgeBaseReindexStatus('kb-uuid')
+   * if (data) {
+   *   console.log(`${Math.round(data.progress * 100)}% (${data.failed_documents} failed)`)
+   * }
+   * ```
+   */
+  async getKnowledgeBaseReindexStatus(
+    knowledgeBaseId: string,
+  ): Promise<{ data: KnowledgeBaseReindexJob | null; error: Error | null }> {
+    try {
+      const data = await this.fetch.get<KnowledgeBaseReindexJob>(
+        `/api/v1/admin/ai/knowledge-bases/${knowledgeBaseId}/reindex`,
+      );
+      return { data, error: null };
+    } catch (error) {
+      return { data: null, error: error as Error };
+    }
+  }
+
   // ============================================================================
   // CHATBOT KNOWLEDGE BASE LINKING
   // ============================================================================
@@ -947,6 +1128,136 @@ export class FluxbaseAdminAI {
     }
   }
 
+  // ============================================================================
+  // SHADOW RETRIEVAL
+  // ============================================================================
+
+  /**
+   * Get the shadow retrieval profile of a chatbot
+   *
+   * @param chatbotId - Chatbot ID
+   * @returns Promise resolving to { data, error } tuple with the profile
+   *
+   * @example
+   * ```typescript
+   * const { data, error } = await client.admin.ai.getShadowRetrievalProfile('chatbot-uuid')
+   * ```
+   */
+  async getShadowRetrievalProfile(
+    chatbotId: string,
+  ): Promise<{ data: ShadowRetrievalProfile | null; error: Error | null }> {
+    try {
+      const data = await this.fetch.get<ShadowRetrievalProfile>(
+        `/api/v1/admin/ai/chatbots/${chatbotId}/shadow-retrieval`,
+      );
+      return { data, error: null };
+    } catch (error) {
+      return { data: null, error: error as Error };
+    }
+  }
+
+  /**
+   * Create or replace the shadow retrieval profile of a chatbot.
+   * The profile runs alongside the live retrieval on a sample of chat turns
+   * and never changes what users see.
+   *
+   * @param chatbotId - Chatbot ID
+   * @param request - Profile configuration
+   * @returns Promise resolving to { data, error } tuple with the saved profile
+   *
+   * @example
+   * ```typescript
+   * const { data, error } = await client.admin.ai.setShadowRetrievalProfile('chatbot-uuid', {
+   *   sample_rate: 0.2,
+   *   mode: 'hybrid',
+   *   similarity_threshold: 0.5,
+   *   compare_answers: true,
+   * })
+   * ```
+   */
+  async setShadowRetrievalProfile(
+    chatbotId: string,
+    request: ShadowRetrievalProfileRequest,
+  ): Promise<{ data: ShadowRetrievalProfile | null; error: Error | null }> {
+    try {
+      const data = await this.fetch.put<ShadowRetrievalProfile>(
+        `/api/v1/admin/ai/chatbots/${chatbotId}/shadow-retrieval`,
+        request,
+      );
+      return { data, error: null };
+    } catch (error) {
+      return { data: null, error: error as Error };
+    }
+  }
+
+  /**
+   * Delete the shadow retrieval profile of a chatbot. Recorded runs are kept.
+   *
+   * @param chatbotId - Chatbot ID
+   * @returns Promise resolving to { data, error } tuple
+   *
+   * @example
+   * ```typescript
+   * const { error } = await client.admin.ai.deleteShadowRetrievalProfile('chatbot-uuid')
+   * ```
+   */
+  async deleteShadowRetrievalProfile(
+    chatbotId: string,
+  ): Promise<{ data: null; error: Error | null }> {
+    try {
+      await this.fetch.delete(
+        `/api/v1/admin/ai/chatbots/${chatbotId}/shadow-retrieval`,
+      );
+      return { data: null, error: null };
+    } catch (error) {
+      return { data: null, error: error as Error };
+    }
+  }
+
+  /**
+   * List recent shadow retrieval runs of a chatbot with a summary of all runs
+   *
+   * @param chatbotId - Chatbot ID
+   * @param options - Pagination (limit defaults to 50, max 500)
+   * @returns Promise resolving to { data, error } tuple with runs and summary
+   *
+   * @example
+   * ```typescript
+   * const { data } = await client.admin.ai.listShadowRetrievalRuns('chatbot-uuid', { limit: 20 })
+   * console.log('Changed results:', data?.summary.changed_results, 'of', data?.summary.runs)
+   * ```
+   */
+  async listShadowRetrievalRuns(
+    chatbotId: string,
+    options?: { limit?: number; offset?: number },
+  ): Promise<{
+    data: {
+      runs: ShadowRetrievalRun[];
+      summary: ShadowRetrievalSummary;
+    } | null;
+    error: Error | null;
+  }> {
+    try {
+      const params = new URLSearchParams();
+      if (options?.limit) params.set("limit", String(options.limit));
+      if (options?.offset) params.set("offset", String(options.offset));
+      const query = params.toString();
+
+      const response = await this.fetch.get<{
+        runs: ShadowRetrievalRun[];
+        summary: ShadowRetrievalSummary;
+      }>(
+        `/api/v1/admin/ai/chatbots/${chatbotId}/shadow-retrieval/runs${query ? `?${query}` : ""}`,
+      );
+      return {
+        data: { runs: response.runs || [], summary: response.summary },
+        error: null,
+      };
+    } catch (error) {
+      return { data: null, error: error as Error };
+    }
+  }
+
   // ============================================================================
   // DOCUMENT UPDATE AND BULK DELETE
   // ============================================================================
@@ -582,6 +582,47 @@ describe("FluxbaseAdminMigrations", () => {
       expect(data).toBeNull();
       expect(error).toBeDefined();
     });
+
+    it("should send destructive change confirmation", async () => {
+      const response = {
+        message: "Migration applied successfully",
+        destructive_changes: [
+          { kind: "drop_table", schema: "public", table: "legacy" },
+        ],
+        backups: ["backup_20260301103000_public_legacy"],
+      };
+      vi.mocked(mockFetch.post).mockResolvedValue(response);
+
+      const { data, error } = await migrations.apply(
+        "004_drop_legacy",
+        "myapp",
+        { confirmation_token: "abc123" }
+      );
+
+      expect(mockFetch.post).toHaveBeenCalledWith(
+        "/api/v1/admin/migrations/004_drop_legacy/apply",
+        { namespace: "myapp", confirmation_token: "abc123" }
+      );
+      expect(error).toBeNull();
+      expect(data!.backups).toEqual(["backup_20260301103000_public_legacy"]);
+    });
+
+    it("should expose destructive changes on 409", async () => {
+      const conflict = new Error("Migration contains destructive changes") as any;
+      conflict.status = 409;
+      conflict.details = {
+        code: "DESTRUCTIVE_MIGRATION",
+        changes: [{ kind: "truncate", schema: "public", table: "sessions" }],
+        confirmation_token: "abc123",
+      };
+      vi.mocked(mockFetch.post).mockRejectedValue(conflict);
+
+      const { data, error } = await migrations.apply("005_reset_sessions");
+
+      expect(data).toBeNull();
+      expect((error as any).status).toBe(409);
+      expect((error as any).details.confirmation_token).toBe("abc123");
+    });
   });
 
   describe("rollback()", () => {
@@ -654,6 +695,21 @@ describe("FluxbaseAdminMigrations", () => {
       );
     });
 
+    it("should allow destructive migrations", async () => {
+      vi.mocked(mockFetch.post).mockResolvedValue({
+        message: "Applied 1 migrations",
+        applied: ["004_drop_legacy"],
+        failed: [],
+      });
+
+      await migrations.applyPending("myapp", { allow_destructive: true });
+
+      expect(mockFetch.post).toHaveBeenCalledWith(
+        "/api/v1/admin/migrations/apply-pending",
+        { namespace: "myapp", allow_destructive: true }
+      );
+    });
+
     it("should handle error", async () => {
       vi.mocked(mockFetch.post).mockRejectedValue(new Error("Apply failed"));
 
@@ -9,6 +9,9 @@ import type {
   CreateMigrationRequest,
   UpdateMigrationRequest,
   MigrationExecution,
+  ApplyMigrationRequest,
+  ApplyMigrationResult,
+  ApplyPendingRequest,
   SyncMigrationsOptions,
   SyncMigrationsResult,
 } from "./types";
@@ -161,6 +164,7 @@ export class FluxbaseAdminMigrations {
                 update_if_changed: options.update_if_changed ?? true,
                 auto_apply: options.auto_apply ?? false,
                 dry_run: options.dry_run ?? false,
+                allow_destructive: options.allow_destructive ?? false,
               },
             }
           );
@@ -201,6 +205,7 @@ export class FluxbaseAdminMigrations {
         },
         dry_run: options.dry_run ?? false,
         warnings: results.flatMap((r) => r.warnings || []),
+        backups: results.flatMap((r) => r.backups || []),
       };
 
       // Note: Schema cache is automatically invalidated by the server after migrations are applied.
@@ -387,9 +392,16 @@ export class FluxbaseAdminMigrations {
   /**
    * Apply a specific migration
    *
+   * Migrations that drop tables or columns, truncate tables or narrow column
+   * types fail with a 409 error until they are confirmed. The error's `details`
+   * list the changes and carry a `confirmation_token`; pass it back (or set
+   * `allow_destructive`) to apply the migration. Affected tables are backed up
+   * in the `migrations` schema first.
+   *
    * @param name - Migration name
    * @param namespace - Migration namespace (default: 'default')
-   * @returns Promise resolving to { data, error } tuple with result message
+   * @param options - Destructive change confirmation
+   * @returns Promise resolving to { data, error } tuple with result message and backups
    *
    * @example
    * ```typescript
@@ -397,16 +409,22 @@ export class FluxbaseAdminMigrations {
    * if (data) {
    *   console.log(data.message) // "Migration applied successfully"
    * }
+   *
+   * // Confirm destructive changes reported by a previous attempt
+   * const { error } = await client.admin.migrations.apply('004_drop_legacy', 'myapp')
+   * const token = (error as any)?.details?.confirmation_token
+   * await client.admin.migrations.apply('004_drop_legacy', 'myapp', { confirmation_token: token })
    * ```
    */
   async apply(
     name: string,
-    namespace: string = "default"
-  ): Promise<{ data: { message: string } | null; error: Error | null }> {
+    namespace: string = "default",
+    options: Omit<ApplyMigrationRequest, "namespace"> = {}
+  ): Promise<{ data: ApplyMigrationResult | null; error: Error | null }> {
     try {
-      const data = await this.fetch.post<{ message: string }>(
+      const data = await this.fetch.post<ApplyMigrationResult>(
         `/api/v1/admin/migrations/${name}/apply`,
-        { namespace }
+        { namespace, ...options }
       );
       return { data, error: null };
     } catch (error) {
@@ -445,6 +463,7 @@ export class FluxbaseAdminMigrations {
    * Apply all pending migrations in order
    *
    * @param namespace - Migration namespace (default: 'default')
+   * @param options - Set allow_destructive to apply destructive migrations without confirmation
    * @returns Promise resolving to { data, error } tuple with applied/failed counts
    *
    * @example
@@ -455,7 +474,10 @@ export class FluxbaseAdminMigrations {
    * }
    * ```
    */
-  async applyPending(namespace: string = "default"): Promise<{
+  async applyPending(
+    namespace: string = "default",
+    options: Omit<ApplyPendingRequest, "namespace"> = {}
+  ): Promise<{
     data: { message: string; applied: string[]; failed: string[] } | null;
     error: Error | null;
   }> {
@@ -464,7 +486,7 @@ export class FluxbaseAdminMigrations {
         message: string;
         applied: string[];
         failed: string[];
-      }>("/api/v1/admin/migrations/apply-pending", { namespace });
+      }>("/api/v1/admin/migrations/apply-pending", { namespace, ...options });
       return { data, error: null };
     } catch (error) {
       return { data: null, error: error as Error };
@@ -13,7 +13,10 @@ import type {
   InviteUserResponse,
   EnrichedUser,
   DeleteUserResponse,
+  ListDuplicateEmailsResponse,
   ResetUserPasswordResponse,
+  BanUserResponse,
+  ForcePasswordResetResponse,
 } from "./types";
 
 // Mock FluxbaseFetch
@@ -304,6 +307,22 @@ describe("FluxbaseAdmin", () => {
         expect(mockFetch.get).toHaveBeenCalledWith("/api/v1/admin/users");
         expect(error).toBeNull();
       });
+
+      it("should filter by metadata and ban status with an offset", async () => {
+        const response: ListUsersResponse = { users: [], total: 0 };
+        vi.mocked(mockFetch.get).mockResolvedValue(response);
+
+        await admin.listUsers({
+          metadata: { plan: "pro" },
+          banned: true,
+          limit: 50,
+          offset: 50,
+        });
+
+        expect(mockFetch.get).toHaveBeenCalledWith(
+          "/api/v1/admin/users?metadata=%7B%22plan%22%3A%22pro%22%7D&banned=true&limit=50&offset=50",
+        );
+      });
     });
 
     describe("inviteUser()", () => {
@@ -412,6 +431,43 @@ describe("FluxbaseAdmin", () => {
       });
     });
 
+    describe("listDuplicateEmails()", () => {
+      it("should list duplicate email groups", async () => {
+        const response: ListDuplicateEmailsResponse = {
+          groups: [
+            {
+              normalized_email: "janedoe@gmail.com",
+              users: [
+                {
+                  id: "user-1",
+                  email: "janedoe@gmail.com",
+                  email_verified: true,
+                  created_at: "2024-01-01T00:00:00Z",
+                },
+                {
+                  id: "user-2",
+                  email: "jane.doe+promo@gmail.com",
+                  email_verified: false,
+                  created_at: "2024-02-01T00:00:00Z",
+                },
+              ],
+            },
+          ],
+          total: 1,
+        };
+
+        vi.mocked(mockFetch.get).mockResolvedValue(response);
+
+        const { data: result, error } = await admin.listDuplicateEmails();
+
+        expect(mockFetch.get).toHaveBeenCalledWith(
+          "/api/v1/admin/users/duplicate-emails",
+        );
+        expect(error).toBeNull();
+        expect(result!.groups[0].users).toHaveLength(2);
+      });
+    });
+
     describe("updateUserRole()", () => {
       it("should update user role", async () => {
         const user: EnrichedUser = {
@@ -505,6 +561,82 @@ describe("FluxbaseAdmin", () => {
         expect(error).toBeNull();
       });
     });
+
+    describe("forcePasswordReset()", () => {
+      it("should force a password reset", async () => {
+        const response: ForcePasswordResetResponse = {
+          message: "Password reset forced. A reset link was sent to user@example.com",
+          email_sent: true,
+        };
+        vi.mocked(mockFetch.post).mockResolvedValue(response);
+
+        const { data: result, error } =
+          await admin.forcePasswordReset("user-123");
+
+        expect(mockFetch.post).toHaveBeenCalledWith(
+          "/api/v1/admin/users/user-123/force-password-reset?type=app",
+          {},
+        );
+        expect(error).toBeNull();
+        expect(result!.email_sent).toBe(true);
+      });
+    });
+
+    describe("verifyUserEmail()", () => {
+      it("should verify a user's email", async () => {
+        vi.mocked(mockFetch.post).mockResolvedValue({
+          id: "user-123",
+          email: "user@example.com",
+          created_at: "2024-01-26T10:00:00Z",
+          email_verified: true,
+        });
+
+        const { data: user, error } = await admin.verifyUserEmail("user-123");
+
+        expect(mockFetch.post).toHaveBeenCalledWith(
+          "/api/v1/admin/users/user-123/verify-email?type=app",
+          {},
+        );
+        expect(error).toBeNull();
+        expect(user!.email_verified).toBe(true);
+      });
+    });
+
+    describe("banUser() and unbanUser()", () => {
+      it("should ban a user for a duration", async () => {
+        const response: BanUserResponse = {
+          message: "User banned successfully",
+          banned_until: "2024-01-29T10:00:00Z",
+        };
+        vi.mocked(mockFetch.post).mockResolvedValue(response);
+
+        const { data: result, error } = await admin.banUser("user-123", {
+          duration: "72h",
+          reason: "spam",
+        });
+
+        expect(mockFetch.post).toHaveBeenCalledWith(
+          "/api/v1/admin/users/user-123/ban?type=app",
+          { duration: "72h", reason: "spam" },
+        );
+        expect(error).toBeNull();
+        expect(result!.banned_until).toBe("2024-01-29T10:00:00Z");
+      });
+
+      it("should unban a dashboard user", async () => {
+        vi.mocked(mockFetch.post).mockResolvedValue({
+          message: "User unbanned successfully",
+        });
+
+        const { error } = await admin.unbanUser("user-123", "dashboard");
+
+        expect(mockFetch.post).toHaveBeenCalledWith(
+          "/api/v1/admin/users/user-123/unban?type=dashboard",
+          {},
+        );
+        expect(error).toBeNull();
+      });
+    });
   });
 
   describe("Integration Scenarios", () => {
@@ -7,11 +7,15 @@ import type {
   AdminRefreshResponse,
   AdminSetupRequest,
   AdminSetupStatusResponse,
+  BanUserRequest,
+  BanUserResponse,
   DeleteUserResponse,
   EnrichedUser,
+  ForcePasswordResetResponse,
   HealthResponse,
   InviteUserRequest,
   InviteUserResponse,
+  ListDuplicateEmailsResponse,
   ListUsersOptions,
   ListUsersResponse,
   ResetUserPasswordResponse,
@@ -375,6 +379,14 @@ export class FluxbaseAdmin {
    *   limit: 50,
    *   type: 'app'
    * });
+   *
+   * // Banned users on the pro plan, second page
+   * const banned = await admin.listUsers({
+   *   metadata: { plan: 'pro' },
+   *   banned: true,
+   *   limit: 50,
+   *   offset: 50
+   * });
    * ```
    */
   async listUsers(
@@ -389,9 +401,18 @@ export class FluxbaseAdmin {
       if (options.search) {
         params.append("search", options.search);
       }
+      if (options.metadata) {
+        params.append("metadata", JSON.stringify(options.metadata));
+      }
+      if (options.banned !== undefined) {
+        params.append("banned", String(options.banned));
+      }
       if (options.limit !== undefined) {
         params.append("limit", String(options.limit));
       }
+      if (options.offset !== undefined) {
+        params.append("offset", String(options.offset));
+      }
       if (options.type) {
         params.append("type", options.type);
       }
@@ -491,6 +512,33 @@ export class FluxbaseAdmin {
     });
   }
 
+  /**
+   * List duplicate email addresses
+   *
+   * Finds groups of app users whose email addresses are aliases of each other
+   * (Gmail dots, "+tag" suffixes). With email normalization enabled, new aliases
+   * are rejected at signup; this finds accounts created before that.
+   *
+   * @returns Groups of users sharing a normalized email
+   *
+   * @example
+   * ```typescript
+   * const { data } = await admin.listDuplicateEmails();
+   * for (const group of data!.groups) {
+   *   console.log(group.normalized_email, group.users.map((u) => u.email));
+   * }
+   * ```
+   */
+  async listDuplicateEmails(): Promise<
+    DataResponse<ListDuplicateEmailsResponse>
+  > {
+    return wrapAsync(async () => {
+      return await this.fetch.get<ListDuplicateEmailsResponse>(
+        "/api/v1/admin/users/duplicate-emails",
+      );
+    });
+  }
+
   /**
    * Update user role
    *
@@ -542,4 +590,105 @@ export class FluxbaseAdmin {
       return await this.fetch.post<ResetUserPasswordResponse>(url, {});
     });
   }
+
+  /**
+   * Force a password reset
+   *
+   * The user's current password stops working and they are signed out of all
+   * sessions. App users are emailed a password reset link.
+   *
+   * @param userId - User ID
+   * @param type - User type ('app' or 'dashboard')
+   * @returns Whether the reset link was emailed
+   *
+   * @example
+   * ```typescript
+   * const { data } = await admin.forcePasswordReset('user-uuid');
+   * if (!data!.email_sent) console.warn(data!.message);
+   * ```
+   */
+  async forcePasswordReset(
+    userId: string,
+    type: "app" | "dashboard" = "app",
+  ): Promise<DataResponse<ForcePasswordResetResponse>> {
+    return wrapAsync(async () => {
+      const url = `/api/v1/admin/users/${userId}/force-password-reset?type=${type}`;
+      return await this.fetch.post<ForcePasswordResetResponse>(url, {});
+    });
+  }
+
+  /**
+   * Verify a user's email
+   *
+   * Marks the email as verified without a verification link
+   *
+   * @param userId - User ID
+   * @param type - User type ('app' or 'dashboard')
+   * @returns Updated user
+   *
+   * @example
+   * ```typescript
+   * const { data: user } = await admin.verifyUserEmail('user-uuid');
+   * console.log(user!.email_verified); // true
+   * ```
+   */
+  async verifyUserEmail(
+    userId: string,
+    type: "app" | "dashboard" = "app",
+  ): Promise<DataResponse<EnrichedUser>> {
+    return wrapAsync(async () => {
+      const url = `/api/v1/admin/users/${userId}/verify-email?type=${type}`;
+      return await this.fetch.post<EnrichedUser>(url, {});
+    });
+  }
+
+  /**
+   * Ban a user
+   *
+   * Locks the user out and signs them out of all sessions. Access tokens
+   * already issued stay valid until they expire but cannot be refreshed.
+   *
+   * @param userId - User ID
+   * @param request - Duration or end of the ban and its reason; without either the ban lasts until the user is unbanned
+   * @param type - User type ('app' or 'dashboard')
+   * @returns When the ban ends
+   *
+   * @example
+   * ```typescript
+   * await admin.banUser('user-uuid', { duration: '72h', reason: 'spam' });
+   * ```
+   */
+  async banUser(
+    userId: string,
+    request: BanUserRequest = {},
+    type: "app" | "dashboard" = "app",
+  ): Promise<DataResponse<BanUserResponse>> {
+    return wrapAsync(async () => {
+      const url = `/api/v1/admin/users/${userId}/ban?type=${type}`;
+      return await this.fetch.post<BanUserResponse>(url, request);
+    });
+  }
+
+  /**
+   * Unban a user
+   *
+   * Lifts a ban or an automatic lockout after failed sign-ins
+   *
+   * @param userId - User ID
+   * @param type - User type ('app' or 'dashboard')
+   *
+   * @example
+   * ```typescript
+   * await admin.unbanUser('user-uuid');
+   * ```
+   */
+  async unbanUser(
+    userId: string,
+    type: "app" | "dashboard" = "app",
+  ): Promise<DataResponse<{ message: string }>> {
+    return wrapAsync(async () => {
+      const url = `/api/v1/admin/users/${userId}/unban?type=${type}`;
+      return await this.fetch.post<{ message: string }>(url, {});
+    });
+  }
 }
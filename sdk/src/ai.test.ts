@@ -829,6 +829,87 @@ describe('FluxbaseAIChat', () => {
     });
   });
 
+  describe('typing and receipts', () => {
+    it('should throw when sendTyping or markRead is called without connection', () => {
+      const chat = new FluxbaseAIChat({
+        wsUrl: 'ws://localhost:8080/ai/ws',
+      });
+
+      expect(() => chat.sendTyping('conv-123')).toThrow();
+      expect(() => chat.markRead('conv-123', 2)).toThrow();
+    });
+
+    it('should send typing, read and client message IDs', async () => {
+      const chat = new FluxbaseAIChat({
+        wsUrl: 'ws://localhost:8080/ai/ws',
+      });
+
+      const connectPromise = chat.connect();
+      mockWs.simulateOpen();
+      await connectPromise;
+
+      chat.sendTyping('conv-123');
+      chat.sendTyping('conv-123', false);
+      chat.markRead('conv-123', 4);
+      chat.sendMessage('conv-123', 'Hello', 'local-1');
+
+      const sent = mockWs.sentMessages.map((m) => JSON.parse(m));
+      expect(sent[0]).toEqual({ type: 'typing', conversation_id: 'conv-123', typing: true });
+      expect(sent[1]).toEqual({ type: 'typing', conversation_id: 'conv-123', typing: false });
+      expect(sent[2]).toEqual({ type: 'read', conversation_id: 'conv-123', sequence: 4 });
+      expect(sent[3].client_message_id).toBe('local-1');
+    });
+
+    it('should call onTyping, onReceipt and onUserMessage', async () => {
+      const onTyping = vi.fn();
+      const onReceipt = vi.fn();
+      const onUserMessage = vi.fn();
+      const chat = new FluxbaseAIChat({
+        wsUrl: 'ws://localhost:8080/ai/ws',
+        onTyping,
+        onReceipt,
+        onUserMessage,
+      });
+
+      const connectPromise = chat.connect();
+      mockWs.simulateOpen();
+      await connectPromise;
+
+      mockWs.simulateMessage({
+        type: 'content',
+        conversation_id: 'conv-123',
+        delta: 'Earlier reply',
+      });
+      mockWs.simulateMessage({
+        type: 'user_message',
+        conversation_id: 'conv-123',
+        role: 'user',
+        content: 'Sent from my phone',
+        sequence: 3,
+        seq: 7,
+      });
+      mockWs.simulateMessage({
+        type: 'typing',
+        conversation_id: 'conv-123',
+        role: 'assistant',
+        typing: true,
+        seq: 8,
+      });
+      mockWs.simulateMessage({
+        type: 'receipt',
+        conversation_id: 'conv-123',
+        status: 'delivered',
+        sequence: 3,
+        client_message_id: 'local-1',
+      });
+
+      expect(onUserMessage).toHaveBeenCalledWith('Sent from my phone', 3, 'conv-123');
+      expect(chat.getAccumulatedContent('conv-123')).toBe('');
+      expect(onTyping).toHaveBeenCalledWith('assistant', true, 'conv-123');
+      expect(onReceipt).toHaveBeenCalledWith('delivered', 3, 'conv-123', 'local-1');
+    });
+  });
+
   describe('message handling', () => {
     it('should handle content messages and accumulate', async () => {
       const onContent = vi.fn();
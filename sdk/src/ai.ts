@@ -8,6 +8,7 @@ import type {
   AIChatbotLookupResponse,
   AIChatClientMessage,
   AIChatServerMessage,
+  AIConversationAttributes,
   AIUsageStats,
   AIUserConversationDetail,
   ListConversationsOptions,
@@ -15,6 +16,20 @@ import type {
   UpdateConversationOptions,
 } from "./types";
 
+/**
+ * Add tag and metadata conversation filters to query parameters
+ * (?tag=a,b&metadata.<key>=<value>)
+ */
+export function appendConversationFilter(
+  params: URLSearchParams,
+  filter?: { tags?: string[]; metadata?: Record<string, string> },
+): void {
+  if (filter?.tags?.length) params.set("tag", filter.tags.join(","));
+  for (const [key, value] of Object.entries(filter?.metadata ?? {})) {
+    params.set(`metadata.${key}`, value);
+  }
+}
+
 /**
  * Event types for chat callbacks
  */
@@ -28,6 +43,10 @@ export type AIChatEventType =
   | "done"
   | "error"
   | "cancelled"
+  | "escalated"
+  | "user_message"
+  | "typing"
+  | "receipt"
   | "disconnected";
 
 /**
@@ -47,6 +66,15 @@ export interface AIChatEvent {
   usage?: AIUsageStats;
   error?: string;
   code?: string;
+  messageId?: string;
+  seq?: number;
+  sequence?: number;
+  readSequence?: number;
+  clientMessageId?: string;
+  role?: "user" | "assistant";
+  content?: string;
+  typing?: boolean;
+  status?: "delivered" | "read";
 }
 
 /**
@@ -79,6 +107,25 @@ export interface AIChatOptions {
     code: string | undefined,
     conversationId: string | undefined,
   ) => void;
+  /** Callback for messages the user sent from another device */
+  onUserMessage?: (
+    content: string,
+    sequence: number,
+    conversationId: string,
+  ) => void;
+  /** Callback when the user (on another device) or the assistant starts or stops typing */
+  onTyping?: (
+    role: "user" | "assistant",
+    typing: boolean,
+    conversationId: string,
+  ) => void;
+  /** Callback for delivered and read receipts */
+  onReceipt?: (
+    status: "delivered" | "read",
+    sequence: number,
+    conversationId: string,
+    clientMessageId?: string,
+  ) => void;
   /** Reconnect attempts (0 = no reconnect) */
   reconnectAttempts?: number;
   /** Reconnect delay in ms */
@@ -201,6 +248,7 @@ export class FluxbaseAIChat {
    *                    If no lookup function, falls back to "default" namespace.
    * @param conversationId - Optional conversation ID to resume
    * @param impersonateUserId - Optional user ID to impersonate (admin only)
+   * @param attributes - Optional metadata and tags of a new conversation
    * @returns Promise resolving to conversation ID
    */
   async startChat(
@@ -208,6 +256,7 @@ export class FluxbaseAIChat {
     namespace?: string,
     conversationId?: string,
     impersonateUserId?: string,
+    attributes?: AIConversationAttributes,
   ): Promise<string> {
     if (!this.isConnected()) {
       throw new Error("Not connected to AI chat");
@@ -251,6 +300,8 @@ export class FluxbaseAIChat {
         namespace: resolvedNamespace,
         conversation_id: conversationId,
         impersonate_user_id: impersonateUserId,
+        metadata: attributes?.metadata,
+        tags: attributes?.tags,
       };
 
       this.ws!.send(JSON.stringify(message));
@@ -262,8 +313,13 @@ export class FluxbaseAIChat {
    *
    * @param conversationId - Conversation ID
    * @param content - Message content
+   * @param clientMessageId - Optional ID echoed in the delivered receipt
    */
-  sendMessage(conversationId: string, content: string): void {
+  sendMessage(
+    conversationId: string,
+    content: string,
+    clientMessageId?: string,
+  ): void {
     if (!this.isConnected()) {
       throw new Error("Not connected to AI chat");
     }
@@ -275,6 +331,47 @@ export class FluxbaseAIChat {
       type: "message",
       conversation_id: conversationId,
       content,
+      client_message_id: clientMessageId,
+    };
+
+    this.ws!.send(JSON.stringify(message));
+  }
+
+  /**
+   * Tell the user's other devices whether the user is typing
+   *
+   * @param conversationId - Conversation ID
+   * @param typing - Whether the user is typing
+   */
+  sendTyping(conversationId: string, typing = true): void {
+    if (!this.isConnected()) {
+      throw new Error("Not connected to AI chat");
+    }
+
+    const message: AIChatClientMessage = {
+      type: "typing",
+      conversation_id: conversationId,
+      typing,
+    };
+
+    this.ws!.send(JSON.stringify(message));
+  }
+
+  /**
+   * Mark a conversation as read up to a message
+   *
+   * @param conversationId - Conversation ID
+   * @param sequence - Sequence number of the last message read
+   */
+  markRead(conversationId: string, sequence: number): void {
+    if (!this.isConnected()) {
+      throw new Error("Not connected to AI chat");
+    }
+
+    const message: AIChatClientMessage = {
+      type: "read",
+      conversation_id: conversationId,
+      sequence,
     };
 
     this.ws!.send(JSON.stringify(message));
@@ -392,6 +489,39 @@ export class FluxbaseAIChat {
           }
           break;
 
+        case "user_message":
+          if (message.conversation_id) {
+            // The assistant's reply to a message from another device starts fresh
+            this.accumulatedContent.set(message.conversation_id, "");
+            this.options.onUserMessage?.(
+              message.content || "",
+              message.sequence || 0,
+              message.conversation_id,
+            );
+          }
+          break;
+
+        case "typing":
+          if (message.conversation_id && message.role) {
+            this.options.onTyping?.(
+              message.role,
+              message.typing ?? false,
+              message.conversation_id,
+            );
+          }
+          break;
+
+        case "receipt":
+          if (message.conversation_id && message.status) {
+            this.options.onReceipt?.(
+              message.status,
+              message.sequence || 0,
+              message.conversation_id,
+              message.client_message_id,
+            );
+          }
+          break;
+
         case "error":
           if (this.pendingStartReject) {
             this.pendingStartReject(
@@ -430,6 +560,15 @@ export class FluxbaseAIChat {
       usage: message.usage,
       error: message.error,
       code: message.code,
+      messageId: message.message_id,
+      seq: message.seq,
+      sequence: message.sequence,
+      readSequence: message.read_sequence,
+      clientMessageId: message.client_message_id,
+      role: message.role,
+      content: message.content,
+      typing: message.typing,
+      status: message.status,
     };
   }
 
@@ -598,6 +737,9 @@ export class FluxbaseAI {
    * // Filter by chatbot
    * const { data, error } = await ai.listConversations({ chatbot: 'sql-assistant' })
    *
+   * // Filter by tag and metadata
+   * const { data, error } = await ai.listConversations({ tags: ['vip'], metadata: { channel: 'web' } })
+   *
    * // With pagination
    * const { data, error } = await ai.listConversations({ limit: 20, offset: 0 })
    * ```
@@ -609,6 +751,7 @@ export class FluxbaseAI {
       const params = new URLSearchParams();
       if (options?.chatbot) params.set("chatbot", options.chatbot);
       if (options?.namespace) params.set("namespace", options.namespace);
+      appendConversationFilter(params, options);
       if (options?.limit !== undefined)
         params.set("limit", String(options.limit));
       if (options?.offset !== undefined)
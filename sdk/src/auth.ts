@@ -45,6 +45,9 @@ import type {
   UserIdentitiesResponse,
   LinkIdentityCredentials,
   UnlinkIdentityParams,
+  KnownDevicesResponse,
+  DataExport,
+  DataExportsResponse,
   ReauthenticateResponse,
   SignInWithIdTokenCredentials,
   AuthConfig,
@@ -1257,6 +1260,104 @@ export class FluxbaseAuth {
     });
   }
 
+  /**
+   * List the devices the current user has signed in from
+   * A sign-in from a device that isn't in this list emails the user a security alert
+   * @returns Promise with the known devices, most recently used first
+   */
+  async listDevices(): Promise<DataResponse<KnownDevicesResponse>> {
+    return wrapAsync(async () => {
+      if (!this.session) {
+        throw new Error("Not authenticated");
+      }
+
+      return await this.fetch.get<KnownDevicesResponse>(
+        "/api/v1/auth/user/devices",
+      );
+    });
+  }
+
+  /**
+   * Remove a known device of the current user
+   * The next sign-in from the device sends a new-device alert again
+   * @param deviceId - ID of the device to remove
+   * @returns Promise with void response
+   */
+  async removeDevice(deviceId: string): Promise<VoidResponse> {
+    return wrapAsyncVoid(async () => {
+      if (!this.session) {
+        throw new Error("Not authenticated");
+      }
+
+      await this.fetch.delete(`/api/v1/auth/user/devices/${deviceId}`);
+    });
+  }
+
+  /**
+   * Request an export of everything stored about the current user
+   * The archive is compiled in the background and the user is emailed when it's ready
+   * @returns Promise with the pending export
+   */
+  async requestDataExport(): Promise<DataResponse<DataExport>> {
+    return wrapAsync(async () => {
+      if (!this.session) {
+        throw new Error("Not authenticated");
+      }
+
+      return await this.fetch.post<DataExport>("/api/v1/auth/user/data-exports");
+    });
+  }
+
+  /**
+   * List the data exports of the current user
+   * @returns Promise with the exports, newest first
+   */
+  async listDataExports(): Promise<DataResponse<DataExportsResponse>> {
+    return wrapAsync(async () => {
+      if (!this.session) {
+        throw new Error("Not authenticated");
+      }
+
+      return await this.fetch.get<DataExportsResponse>(
+        "/api/v1/auth/user/data-exports",
+      );
+    });
+  }
+
+  /**
+   * Get a data export of the current user, e.g. to check whether it completed
+   * @param exportId - ID of the export
+   * @returns Promise with the export
+   */
+  async getDataExport(exportId: string): Promise<DataResponse<DataExport>> {
+    return wrapAsync(async () => {
+      if (!this.session) {
+        throw new Error("Not authenticated");
+      }
+
+      return await this.fetch.get<DataExport>(
+        `/api/v1/auth/user/data-exports/${exportId}`,
+      );
+    });
+  }
+
+  /**
+   * Download the zip archive of a completed data export of the current user
+   * @param exportId - ID of the export
+   * @returns Promise with the archive
+   */
+  async downloadDataExport(exportId: string): Promise<DataResponse<Blob>> {
+    return wrapAsync(async () => {
+      if (!this.session) {
+        throw new Error("Not authenticated");
+      }
+
+      return await this.fetch.getBlob(
+        `/api/v1/auth/user/data-exports/${exportId}/download`,
+      );
+    });
+  }
+
   /**
    * Reauthenticate to get security nonce - Supabase-compatible
    * Get a security nonce for sensitive operations (password change, etc.)
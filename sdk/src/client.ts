@@ -49,6 +49,7 @@ import { FluxbaseAdmin } from "./admin";
 import { FluxbaseManagement } from "./management";
 import { SettingsClient } from "./settings";
 import { SecretsManager } from "./secrets";
+import { QueuesManager } from "./queues";
 import { FluxbaseAI } from "./ai";
 import { FluxbaseVector } from "./vector";
 import { FluxbaseGraphQL } from "./graphql";
@@ -119,6 +120,9 @@ export class FluxbaseClient<
   /** Secrets module for managing edge function and job secrets */
   public secrets: SecretsManager;
 
+  /** Queues module for message queues with consumer groups and dead-letter queues */
+  public queues: QueuesManager;
+
   /** AI module for chatbots and conversation history */
   public ai: FluxbaseAI;
 
@@ -313,6 +317,9 @@ export class FluxbaseClient<
     // Initialize secrets module
     this.secrets = new SecretsManager(this.fetch);
 
+    // Initialize queues module
+    this.queues = new QueuesManager(this.fetch);
+
     // Initialize AI module
     // Convert HTTP URL to WebSocket URL (http(s) -> ws(s))
     const wsProtocol = fluxbaseUrl.startsWith("https") ? "wss" : "ws";
@@ -648,6 +648,52 @@ describe('FluxbaseFetch', () => {
       } catch (err: any) {
         expect(err.status).toBe(400)
         expect(err.details).toEqual({ error: 'Invalid input', details: { field: 'name' } })
+        expect(err.retryAfter).toBeUndefined()
+      }
+    })
+
+    it('should expose the error code and request ID', async () => {
+      mockFetch.mockResolvedValueOnce({
+        ok: false,
+        status: 404,
+        statusText: 'Not Found',
+        headers: new Headers({ 'content-type': 'application/json' }),
+        json: async () => ({ error: 'Bucket not found', code: 'NOT_FOUND', request_id: 'req-123' }),
+      })
+
+      try {
+        await fluxFetch.get('/api/test')
+        expect.fail('Should have thrown')
+      } catch (err: any) {
+        expect(err.message).toBe('Bucket not found')
+        expect(err.code).toBe('NOT_FOUND')
+        expect(err.requestId).toBe('req-123')
+      }
+    })
+
+    it('should include rate limit info on 429 errors', async () => {
+      mockFetch.mockResolvedValueOnce({
+        ok: false,
+        status: 429,
+        statusText: 'Too Many Requests',
+        headers: new Headers({
+          'content-type': 'application/json',
+          'Retry-After': '42',
+          'X-RateLimit-Limit': '100',
+          'X-RateLimit-Remaining': '0',
+          'X-RateLimit-Reset': '1700000042',
+        }),
+        json: async () => ({ code: 'RATE_LIMIT_EXCEEDED', error: 'Rate limit exceeded', retry_after: 42 }),
+      })
+
+      try {
+        await fluxFetch.get('/api/test')
+        expect.fail('Should have thrown')
+      } catch (err: any) {
+        expect(err.status).toBe(429)
+        expect(err.code).toBe('RATE_LIMIT_EXCEEDED')
+        expect(err.retryAfter).toBe(42)
+        expect(err.rateLimit).toEqual({ limit: 100, remaining: 0, reset: new Date(1700000042 * 1000) })
       }
     })
   })
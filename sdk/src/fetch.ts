@@ -2,7 +2,7 @@
  * HTTP client for making requests to the Fluxbase API
  */
 
-import type { FluxbaseError, HttpMethod } from './types'
+import type { FluxbaseError, HttpMethod, RateLimitInfo } from './types'
 
 export interface FetchOptions {
   method: HttpMethod
@@ -170,6 +170,8 @@ export class FluxbaseFetch {
 
         error.status = response.status
         error.details = data
+        applyErrorBody(error, data)
+        applyRateLimit(error, response.headers)
 
         throw error
       }
@@ -338,6 +340,8 @@ export class FluxbaseFetch {
 
         error.status = response.status
         error.details = data
+        applyErrorBody(error, data)
+        applyRateLimit(error, response.headers)
 
         throw error
       }
@@ -435,6 +439,7 @@ export class FluxbaseFetch {
       if (!response.ok) {
         const error = new Error(response.statusText) as FluxbaseError
         error.status = response.status
+        applyRateLimit(error, response.headers)
         throw error
       }
 
@@ -455,3 +460,53 @@ export class FluxbaseFetch {
     }
   }
 }
+
+/**
+ * Parse the X-RateLimit-* headers, if present
+ */
+function parseRateLimitHeaders(headers: Headers): RateLimitInfo | undefined {
+  const limit = headers.get('X-RateLimit-Limit')
+  const remaining = headers.get('X-RateLimit-Remaining')
+  const reset = headers.get('X-RateLimit-Reset')
+  if (limit === null || remaining === null || reset === null) {
+    return undefined
+  }
+  return {
+    limit: Number(limit),
+    remaining: Number(remaining),
+    reset: new Date(Number(reset) * 1000),
+  }
+}
+
+/**
+ * Copy the machine-readable error code and request ID from an API error body
+ */
+function applyErrorBody(error: FluxbaseError, data: unknown) {
+  if (typeof data !== 'object' || !data) {
+    return
+  }
+  const body = data as { code?: unknown; request_id?: unknown }
+  if (typeof body.code === 'string') {
+    error.code = body.code
+  }
+  if (typeof body.request_id === 'string') {
+    error.requestId = body.request_id
+  }
+}
+
+/**
+ * Attach Retry-After and rate limit quota to a 429 error so callers can back off
+ */
+function applyRateLimit(error: FluxbaseError, headers: Headers) {
+  if (error.status !== 429) {
+    return
+  }
+  error.code = 'RATE_LIMIT_EXCEEDED'
+  error.rateLimit = parseRateLimitHeaders(headers)
+  const retryAfter = Number(headers.get('Retry-After'))
+  if (retryAfter > 0) {
+    error.retryAfter = retryAfter
+  } else if (error.rateLimit) {
+    error.retryAfter = Math.max(1, Math.ceil((error.rateLimit.reset.getTime() - Date.now()) / 1000))
+  }
+}
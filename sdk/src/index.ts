@@ -146,6 +146,9 @@ export {
 // Secrets module
 export { SecretsManager } from "./secrets";
 
+// Queues module
+export { QueuesManager } from "./queues";
+
 // DDL module
 export { DDLManager } from "./ddl";
 
@@ -200,6 +203,11 @@ export type {
   SignInWith2FAResponse,
   CaptchaConfig,
   CaptchaProvider,
+  KnownDevice,
+  KnownDevicesResponse,
+  DataExport,
+  DataExportStatus,
+  DataExportsResponse,
 
   // SAML SSO types
   SAMLProvider,
@@ -251,6 +259,8 @@ export type {
   StreamUploadOptions,
   ListOptions,
   SignedUrlOptions,
+  SignedUploadUrlOptions,
+  SignedUploadUrl,
   DownloadOptions,
   StreamDownloadData,
   ResumableDownloadOptions,
@@ -282,6 +292,8 @@ export type {
   UpdateMigrationRequest,
   MigrationExecution,
   ApplyMigrationRequest,
+  ApplyMigrationResult,
+  DestructiveMigrationChange,
   RollbackMigrationRequest,
   ApplyPendingRequest,
   SyncMigrationsOptions,
@@ -311,21 +323,33 @@ export type {
   AdminMeResponse,
   EnrichedUser,
   ListUsersResponse,
+  ListDuplicateEmailsResponse,
+  DuplicateEmailGroup,
   ListUsersOptions,
   InviteUserRequest,
   InviteUserResponse,
   UpdateUserRoleRequest,
   ResetUserPasswordResponse,
   DeleteUserResponse,
+  BanUserRequest,
+  BanUserResponse,
+  ForcePasswordResetResponse,
 
   // Management types - Client Keys
   ClientKey,
   CreateClientKeyRequest,
+  JSONKeyCase,
   CreateClientKeyResponse,
   ListClientKeysResponse,
   UpdateClientKeyRequest,
   RevokeClientKeyResponse,
   DeleteClientKeyResponse,
+  ClientKeyBootstrapToken,
+  ClientKeyBootstrapTokenStatus,
+  CreateClientKeyBootstrapTokenRequest,
+  CreateClientKeyBootstrapTokenResponse,
+  EnrollClientKeyRequest,
+  EnrollClientKeyResponse,
 
   // Management types - Client Keys (Deprecated aliases)
   APIKey,
@@ -343,7 +367,12 @@ export type {
   ListWebhooksResponse,
   TestWebhookResponse,
   WebhookDelivery,
+  WebhookDeliveryStatus,
+  WebhookRetryBackoffStrategy,
   ListWebhookDeliveriesResponse,
+  SearchWebhookDeliveriesOptions,
+  ReplayWebhookDeliveriesRequest,
+  ReplayWebhookDeliveriesResponse,
   DeleteWebhookResponse,
 
   // Management types - Invitations
@@ -385,6 +414,10 @@ export type {
   UpdateEmailTemplateRequest,
   TestEmailTemplateRequest,
   ListEmailTemplatesResponse,
+  EmailTemplateVersion,
+  ListEmailTemplateVersionsResponse,
+  PreviewEmailTemplateRequest,
+  PreviewEmailTemplateResponse,
 
   // Email Provider Settings types (Admin API)
   EmailSettingOverride,
@@ -462,6 +495,9 @@ export type {
   AIChatServerMessage,
   AIUsageStats,
   AIConversation,
+  AIConversationAttributes,
+  AIConversationBreakdownEntry,
+  AIConversationBreakdownOptions,
   AIConversationMessage,
 
   // AI User Conversation History types
@@ -502,6 +538,7 @@ export type {
 
   // HTTP types
   FluxbaseError,
+  RateLimitInfo,
   HttpMethod,
   RequestOptions,
 
@@ -550,3 +587,16 @@ export type {
   ListSecretsOptions,
   SecretByNameOptions,
 } from "./secrets";
+
+// Queues types (defined in queues module)
+export type {
+  Queue,
+  QueueConsumerGroup,
+  QueueMessage,
+  CreateQueueRequest,
+  UpdateQueueRequest,
+  SendMessageOptions,
+  OutgoingQueueMessage,
+  ReadMessagesOptions,
+  NackMessagesOptions,
+} from "./queues";
@@ -197,6 +197,25 @@ describe('ClientKeysManager', () => {
     })
   })
 
+  describe('rotate()', () => {
+    it('should rotate a client key with a grace period', async () => {
+      vi.mocked(mockFetch.post).mockResolvedValue({ id: 'key-456', key: 'fbk_new' })
+
+      const result = await manager.rotate('key-123', { grace_period: 3600 })
+
+      expect(mockFetch.post).toHaveBeenCalledWith('/api/v1/client-keys/key-123/rotate', { grace_period: 3600 })
+      expect(result.key).toBe('fbk_new')
+    })
+
+    it('should use the default grace period', async () => {
+      vi.mocked(mockFetch.post).mockResolvedValue({ id: 'key-456', key: 'fbk_new' })
+
+      await manager.rotate('key-123')
+
+      expect(mockFetch.post).toHaveBeenCalledWith('/api/v1/client-keys/key-123/rotate', {})
+    })
+  })
+
   describe('delete()', () => {
     it('should delete a client key', async () => {
       vi.mocked(mockFetch.delete).mockResolvedValue({ message: 'Client key deleted successfully' })
@@ -207,6 +226,44 @@ describe('ClientKeysManager', () => {
       expect(result.message).toBe('Client key deleted successfully')
     })
   })
+
+  describe('bootstrap tokens', () => {
+    it('should create a bootstrap token', async () => {
+      const request = { name: 'worker', scopes: ['read:tables'], expires_in: 900 }
+      vi.mocked(mockFetch.post).mockResolvedValue({ id: 'bt-1', token: 'fbb_secret', status: 'pending' })
+
+      const result = await manager.createBootstrapToken(request)
+
+      expect(mockFetch.post).toHaveBeenCalledWith('/api/v1/client-keys/bootstrap-tokens', request)
+      expect(result.token).toBe('fbb_secret')
+    })
+
+    it('should list bootstrap tokens', async () => {
+      vi.mocked(mockFetch.get).mockResolvedValue([{ id: 'bt-1', status: 'used' }])
+
+      const result = await manager.listBootstrapTokens()
+
+      expect(mockFetch.get).toHaveBeenCalledWith('/api/v1/client-keys/bootstrap-tokens')
+      expect(result[0].status).toBe('used')
+    })
+
+    it('should revoke a bootstrap token', async () => {
+      vi.mocked(mockFetch.post).mockResolvedValue({ message: 'Bootstrap token revoked successfully' })
+
+      await manager.revokeBootstrapToken('bt-1')
+
+      expect(mockFetch.post).toHaveBeenCalledWith('/api/v1/client-keys/bootstrap-tokens/bt-1/revoke', {})
+    })
+
+    it('should enroll a client key', async () => {
+      vi.mocked(mockFetch.post).mockResolvedValue({ id: 'key-1', name: 'worker: worker-1', key: 'fbk_secret' })
+
+      const result = await manager.enroll({ token: 'fbb_secret', name: 'worker-1' })
+
+      expect(mockFetch.post).toHaveBeenCalledWith('/api/v1/client-keys/enroll', { token: 'fbb_secret', name: 'worker-1' })
+      expect(result.key).toBe('fbk_secret')
+    })
+  })
 })
 
 describe('WebhooksManager', () => {
@@ -2,16 +2,27 @@ import type { FluxbaseFetch } from './fetch'
 import type {
   // Client Keys
   ClientKey,
+  ClientKeyBootstrapToken,
+  CreateClientKeyBootstrapTokenRequest,
+  CreateClientKeyBootstrapTokenResponse,
   CreateClientKeyRequest,
   CreateClientKeyResponse,
   DeleteClientKeyResponse,
+  EnrollClientKeyRequest,
+  EnrollClientKeyResponse,
   ListClientKeysResponse,
   RevokeClientKeyResponse,
+  RotateClientKeyRequest,
+  RotateClientKeyResponse,
   UpdateClientKeyRequest,
   // Webhooks
   CreateWebhookRequest,
   DeleteWebhookResponse,
   ListWebhookDeliveriesResponse,
+  SearchWebhookDeliveriesOptions,
+  ReplayWebhookDeliveriesRequest,
+  ReplayWebhookDeliveriesResponse,
+  WebhookDelivery,
   ListWebhooksResponse,
   TestWebhookResponse,
   UpdateWebhookRequest,
@@ -153,6 +164,26 @@ export class ClientKeysManager {
     return await this.fetch.post<RevokeClientKeyResponse>(`/api/v1/client-keys/${keyId}/revoke`, {})
   }
 
+  /**
+   * Rotate a client key
+   *
+   * Creates a new key with the same name, scopes, restrictions and expiry. The old key
+   * keeps working for the grace period so clients can switch over, and then expires.
+   *
+   * @param keyId - Client key ID
+   * @param request - Grace period of the old key in seconds (default 24 hours)
+   * @returns The new client key including the full key (only returned once)
+   *
+   * @example
+   * ```typescript
+   * const { key } = await client.management.clientKeys.rotate('key-uuid', { grace_period: 3600 })
+   * // Deploy the new key within the hour
+   * ```
+   */
+  async rotate(keyId: string, request: RotateClientKeyRequest = {}): Promise<RotateClientKeyResponse> {
+    return await this.fetch.post<RotateClientKeyResponse>(`/api/v1/client-keys/${keyId}/rotate`, request)
+  }
+
   /**
    * Delete a client key
    *
@@ -170,6 +201,69 @@ export class ClientKeysManager {
   async delete(keyId: string): Promise<DeleteClientKeyResponse> {
     return await this.fetch.delete<DeleteClientKeyResponse>(`/api/v1/client-keys/${keyId}`)
   }
+
+  /**
+   * Create a bootstrap token (admin only)
+   *
+   * A bootstrap token is a short-lived, one-time token that a new service exchanges
+   * for a client key with `enroll()`, so long-lived keys never need to be copied around.
+   *
+   * @param request - Token name, allowed scopes and expiry
+   * @returns The created token including the full token value (only returned once)
+   *
+   * @example
+   * ```typescript
+   * const { token } = await client.management.clientKeys.createBootstrapToken({
+   *   name: 'worker',
+   *   scopes: ['read:tables', 'write:tables'],
+   *   expires_in: 900,
+   *   key_expires_in: 30 * 24 * 3600
+   * })
+   * ```
+   */
+  async createBootstrapToken(request: CreateClientKeyBootstrapTokenRequest): Promise<CreateClientKeyBootstrapTokenResponse> {
+    return await this.fetch.post<CreateClientKeyBootstrapTokenResponse>('/api/v1/client-keys/bootstrap-tokens', request)
+  }
+
+  /**
+   * List bootstrap tokens (admin only)
+   *
+   * @returns Bootstrap tokens, newest first, with their status
+   */
+  async listBootstrapTokens(): Promise<ClientKeyBootstrapToken[]> {
+    return await this.fetch.get<ClientKeyBootstrapToken[]>('/api/v1/client-keys/bootstrap-tokens')
+  }
+
+  /**
+   * Revoke an unused bootstrap token (admin only)
+   *
+   * @param tokenId - Bootstrap token ID
+   * @returns Revocation confirmation
+   */
+  async revokeBootstrapToken(tokenId: string): Promise<RevokeClientKeyResponse> {
+    return await this.fetch.post<RevokeClientKeyResponse>(`/api/v1/client-keys/bootstrap-tokens/${tokenId}/revoke`, {})
+  }
+
+  /**
+   * Exchange a bootstrap token for a client key
+   *
+   * Does not require authentication. The token can only be used once.
+   *
+   * @param request - Bootstrap token, optional key name suffix and scopes
+   * @returns The enrolled client key including the full key (only returned once)
+   *
+   * @example
+   * ```typescript
+   * const client = createClient({ url: 'http://localhost:8080' })
+   * const { key } = await client.management.clientKeys.enroll({
+   *   token: process.env.FLUXBASE_BOOTSTRAP_TOKEN!,
+   *   name: 'worker-1'
+   * })
+   * ```
+   */
+  async enroll(request: EnrollClientKeyRequest): Promise<EnrollClientKeyResponse> {
+    return await this.fetch.post<EnrollClientKeyResponse>('/api/v1/client-keys/enroll', request)
+  }
 }
 
 /**
@@ -339,6 +433,78 @@ export class WebhooksManager {
       `/api/v1/webhooks/${webhookId}/deliveries?limit=${limit}`,
     )
   }
+
+  /**
+   * Search deliveries across webhooks, failed ones by default
+   *
+   * @param options - Status, webhook and time range to filter by
+   * @returns Deliveries with their payloads and the endpoints' responses, newest first
+   *
+   * @example
+   * ```typescript
+   * const failed = await client.management.webhooks.searchDeliveries({
+   *   since: '2026-03-01T00:00:00Z'
+   * })
+   * failed.forEach(d => console.log(d.status_code, d.error, d.response_body))
+   * ```
+   */
+  async searchDeliveries(options: SearchWebhookDeliveriesOptions = {}): Promise<WebhookDelivery[]> {
+    const params = new URLSearchParams()
+    for (const [key, value] of Object.entries(options)) {
+      if (value !== undefined) {
+        params.append(key, String(value))
+      }
+    }
+    const query = params.toString()
+    return await this.fetch.get<WebhookDelivery[]>(
+      `/api/v1/webhooks/deliveries${query ? `?${query}` : ''}`,
+    )
+  }
+
+  /**
+   * Get a delivery with its payload and the endpoint's response
+   *
+   * @param deliveryId - Delivery ID
+   * @returns The delivery
+   */
+  async getDelivery(deliveryId: string): Promise<WebhookDelivery> {
+    return await this.fetch.get<WebhookDelivery>(`/api/v1/webhooks/deliveries/${deliveryId}`)
+  }
+
+  /**
+   * Send a delivery's payload to its webhook again
+   *
+   * @param deliveryId - Delivery ID
+   * @returns The replay, recorded as a new delivery whether or not it succeeded
+   *
+   * @example
+   * ```typescript
+   * const replay = await client.management.webhooks.replayDelivery('delivery-uuid')
+   * console.log(replay.status, replay.status_code)
+   * ```
+   */
+  async replayDelivery(deliveryId: string): Promise<WebhookDelivery> {
+    return await this.fetch.post<WebhookDelivery>(`/api/v1/webhooks/deliveries/${deliveryId}/replay`, {})
+  }
+
+  /**
+   * Replay up to 100 deliveries, given by ID or by time range
+   *
+   * @param request - Delivery IDs, or a time range (failed deliveries unless status is given)
+   * @returns The replays
+   *
+   * @example
+   * ```typescript
+   * // Replay everything that failed during an outage, in the original order
+   * const { count } = await client.management.webhooks.replayDeliveries({
+   *   since: '2026-03-01T09:00:00Z',
+   *   until: '2026-03-01T10:00:00Z'
+   * })
+   * ```
+   */
+  async replayDeliveries(request: ReplayWebhookDeliveriesRequest): Promise<ReplayWebhookDeliveriesResponse> {
+    return await this.fetch.post<ReplayWebhookDeliveriesResponse>('/api/v1/webhooks/deliveries/replay', request)
+  }
 }
 
 /**
@@ -89,6 +89,19 @@ class MockFetch implements FluxbaseFetch {
     }
   }
 
+  async getBlob(
+    path: string,
+    options: { headers?: Record<string, string> } = {},
+  ): Promise<Blob> {
+    this.lastUrl = path;
+    this.lastMethod = "GET";
+    this.lastHeaders = options.headers ?? {};
+    if (this.mockError) {
+      throw this.mockError;
+    }
+    return new Blob(["id\n1\n"], { type: "text/csv" });
+  }
+
   async postWithHeaders<T>(path: string, body?: unknown): Promise<{ data: T; headers: Headers; status: number }> {
     this.lastUrl = path;
     this.lastMethod = "POST";
@@ -1631,3 +1644,43 @@ describe("QueryBuilder - POST-based Queries", () => {
     });
   });
 });
+
+describe("QueryBuilder - CSV export", () => {
+  let fetch: MockFetch;
+
+  beforeEach(() => {
+    fetch = new MockFetch();
+  });
+
+  it("should export with filters, select and order", async () => {
+    const blob = await new QueryBuilder(fetch, "orders")
+      .select("id,status")
+      .eq("status", "shipped")
+      .order("created_at", { ascending: false })
+      .exportCsv();
+
+    expect(await blob.text()).toBe("id\n1\n");
+    expect(fetch.lastUrl).toContain("/api/v1/tables/orders/export?");
+    expect(fetch.lastUrl).toContain("format=csv");
+    expect(fetch.lastUrl).toContain("status=eq.shipped");
+    expect(fetch.lastUrl).toContain("order=created_at.desc");
+    expect(fetch.lastHeaders).toEqual({});
+  });
+
+  it("should send range and offset as a Range header", async () => {
+    await new QueryBuilder(fetch, "orders")
+      .range(100, 199)
+      .exportCsv();
+
+    expect(fetch.lastUrl).not.toContain("limit=");
+    expect(fetch.lastUrl).not.toContain("offset=");
+    expect(fetch.lastHeaders).toEqual({ Range: "rows=100-199" });
+
+    await new QueryBuilder(fetch, "orders", "sales")
+      .offset(250000)
+      .exportCsv();
+
+    expect(fetch.lastUrl).toContain("/api/v1/tables/sales/orders/export?");
+    expect(fetch.lastHeaders).toEqual({ Range: "rows=250000-" });
+  });
+});
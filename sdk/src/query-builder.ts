@@ -463,6 +463,58 @@ export class QueryBuilder<T = unknown>
     return this;
   }
 
+  /**
+   * Check if the bounding box of a geometry intersects a box (PostGIS &&)
+   * Uses the spatial index, so it is a cheap pre-filter for map viewports
+   * @param column - Column containing geometry/geography data
+   * @param bounds - [minLon, minLat, maxLon, maxLat] in WGS 84, or a GeoJSON object whose bounding box is used
+   * @example bbox('location', [-122.5, 37.7, -122.3, 37.9])
+   */
+  bbox(
+    column: string,
+    bounds: [number, number, number, number] | Record<string, unknown>,
+  ): this {
+    this.filters.push({
+      column,
+      operator: "bbox" as FilterOperator,
+      value: bounds,
+    });
+    return this;
+  }
+
+  /**
+   * Order results by distance from a geometry (PostGIS), nearest first by default
+   * Select the computed `_distance` field to get the distance of each row, in meters for
+   * geography columns and in the units of the column's SRID for geometry columns
+   *
+   * @example
+   * ```typescript
+   * // The 5 nearest cafes
+   * const { data } = await client
+   *   .from('places')
+   *   .select('id, name, _distance')
+   *   .eq('kind', 'cafe')
+   *   .orderByDistance('location', { type: 'Point', coordinates: [-122.4, 37.8] })
+   *   .limit(5)
+   * ```
+   *
+   * @param column - Column containing geometry/geography data
+   * @param geojson - GeoJSON geometry to measure the distance from
+   * @param options - Optional: { ascending?: boolean } - defaults to true (nearest first)
+   */
+  orderByDistance(
+    column: string,
+    geojson: unknown,
+    options?: { ascending?: boolean },
+  ): this {
+    this.orderBys.push({
+      column,
+      direction: options?.ascending === false ? "desc" : "asc",
+      geometry: geojson,
+    });
+    return this;
+  }
+
   /**
    * Order results
    */
@@ -872,6 +924,45 @@ export class QueryBuilder<T = unknown>
     return this.delete().execute() as Promise<PostgrestResponse<null>>;
   }
 
+  /**
+   * Export the matching rows as CSV, streamed by the server with PostgreSQL COPY
+   *
+   * Filters, select and order are applied; rows are ordered by the primary key by default.
+   * `range()`, `offset()` and `limit()` select rows with a `Range: rows=<first>-<last>` header,
+   * and the CSV header row is only included when the range starts at row 0, so an interrupted
+   * export can be resumed by appending `.offset(rowsReceived)`.
+   *
+   * @example
+   * ```typescript
+   * const csv = await client.from('orders').eq('status', 'shipped').exportCsv()
+   *
+   * // Resume after the first 250000 rows
+   * const rest = await client.from('orders').eq('status', 'shipped').offset(250000).exportCsv()
+   * ```
+   *
+   * @param options - `timeout` in milliseconds; large exports need more than the client default
+   */
+  async exportCsv(options: { timeout?: number } = {}): Promise<Blob> {
+    const params = new URLSearchParams(this.buildQueryString());
+    for (const key of ["limit", "offset", "count", "truncate"]) {
+      params.delete(key);
+    }
+    params.set("format", "csv");
+
+    const headers: Record<string, string> = {};
+    if (this.offsetValue !== undefined || this.limitValue !== undefined) {
+      const first = this.offsetValue ?? 0;
+      const last =
+        this.limitValue !== undefined ? String(first + this.limitValue - 1) : "";
+      headers["Range"] = `rows=${first}-${last}`;
+    }
+
+    return this.fetch.getBlob(
+      `${this.buildTablePath()}/export?${params.toString()}`,
+      { headers, timeout: options.timeout },
+    );
+  }
+
   /**
    * Execute the query and return results
    */
@@ -1236,6 +1327,10 @@ export class QueryBuilder<T = unknown>
             const vectorStr = `[${o.vectorValue.join(",")}]`;
             return `${o.column}.${o.vectorOp}.${vectorStr}.${o.direction}`;
           }
+          if (o.geometry) {
+            // Distance ordering: column.st_distance.{geojson}.direction
+            return `${o.column}.st_distance.${JSON.stringify(o.geometry)}.${o.direction}`;
+          }
           // Standard ordering: column.direction.nulls
           return `${o.column}.${o.direction}${o.nulls ? `.nulls${o.nulls}` : ""}`;
         })
@@ -1502,6 +1597,7 @@ export class QueryBuilder<T = unknown>
         nulls: o.nulls,
         vectorOp: o.vectorOp,
         vectorValue: o.vectorValue,
+        geometry: o.geometry,
       })),
       limit: this.limitValue,
       offset: this.offsetValue,
@@ -1536,6 +1632,7 @@ interface PostQueryRequestBody {
     nulls?: string;
     vectorOp?: string;
     vectorValue?: number[];
+    geometry?: unknown;
   }>;
   limit?: number;
   offset?: number;
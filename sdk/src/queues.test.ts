@@ -0,0 +1,180 @@
+/**
+ * Queues Module Tests
+ */
+
+import { describe, it, expect, beforeEach } from 'vitest'
+import { QueuesManager } from './queues'
+import type { FluxbaseFetch } from './fetch'
+
+// Mock FluxbaseFetch
+class MockFetch implements Partial<FluxbaseFetch> {
+  public lastUrl: string = ''
+  public lastMethod: string = ''
+  public lastBody: unknown = null
+  public mockResponse: any = null
+  public shouldThrow: boolean = false
+  public errorMessage: string = 'Test error'
+
+  async get<T>(path: string): Promise<T> {
+    this.lastUrl = path
+    this.lastMethod = 'GET'
+    if (this.shouldThrow) {
+      throw new Error(this.errorMessage)
+    }
+    return this.mockResponse as T
+  }
+
+  async post<T>(path: string, body?: unknown): Promise<T> {
+    this.lastUrl = path
+    this.lastMethod = 'POST'
+    this.lastBody = body
+    if (this.shouldThrow) {
+      throw new Error(this.errorMessage)
+    }
+    return this.mockResponse as T
+  }
+
+  async patch<T>(path: string, body?: unknown): Promise<T> {
+    this.lastUrl = path
+    this.lastMethod = 'PATCH'
+    this.lastBody = body
+    if (this.shouldThrow) {
+      throw new Error(this.errorMessage)
+    }
+    return this.mockResponse as T
+  }
+
+  async delete<T>(path: string): Promise<T> {
+    this.lastUrl = path
+    this.lastMethod = 'DELETE'
+    if (this.shouldThrow) {
+      throw new Error(this.errorMessage)
+    }
+    return this.mockResponse as T
+  }
+}
+
+describe('QueuesManager', () => {
+  let mockFetch: MockFetch
+  let queues: QueuesManager
+
+  beforeEach(() => {
+    mockFetch = new MockFetch()
+    queues = new QueuesManager(mockFetch as unknown as FluxbaseFetch)
+  })
+
+  describe('queues', () => {
+    it('should list queues', async () => {
+      mockFetch.mockResponse = { queues: [{ id: 'q1', name: 'emails' }] }
+
+      const result = await queues.list()
+
+      expect(mockFetch.lastUrl).toBe('/api/v1/queues')
+      expect(mockFetch.lastMethod).toBe('GET')
+      expect(result).toHaveLength(1)
+      expect(result[0].name).toBe('emails')
+    })
+
+    it('should return an empty list when there are no queues', async () => {
+      mockFetch.mockResponse = { queues: null }
+
+      expect(await queues.list()).toEqual([])
+    })
+
+    it('should create a queue', async () => {
+      mockFetch.mockResponse = { id: 'q1', name: 'emails' }
+
+      await queues.create({ name: 'emails', max_deliveries: 3, dead_letter_queue: 'emails-dead' })
+
+      expect(mockFetch.lastUrl).toBe('/api/v1/queues')
+      expect(mockFetch.lastMethod).toBe('POST')
+      expect(mockFetch.lastBody).toEqual({ name: 'emails', max_deliveries: 3, dead_letter_queue: 'emails-dead' })
+    })
+
+    it('should update a queue', async () => {
+      await queues.update('emails', { visibility_timeout: 120 })
+
+      expect(mockFetch.lastUrl).toBe('/api/v1/queues/emails')
+      expect(mockFetch.lastMethod).toBe('PATCH')
+      expect(mockFetch.lastBody).toEqual({ visibility_timeout: 120 })
+    })
+
+    it('should delete a queue', async () => {
+      await queues.delete('emails')
+
+      expect(mockFetch.lastUrl).toBe('/api/v1/queues/emails')
+      expect(mockFetch.lastMethod).toBe('DELETE')
+    })
+
+    it('should manage consumer groups', async () => {
+      await queues.createGroup('emails', 'analytics')
+      expect(mockFetch.lastUrl).toBe('/api/v1/queues/emails/groups')
+      expect(mockFetch.lastBody).toEqual({ name: 'analytics' })
+
+      await queues.deleteGroup('emails', 'analytics')
+      expect(mockFetch.lastUrl).toBe('/api/v1/queues/emails/groups/analytics')
+      expect(mockFetch.lastMethod).toBe('DELETE')
+    })
+  })
+
+  describe('messages', () => {
+    it('should send a message', async () => {
+      mockFetch.mockResponse = { sent: 1 }
+
+      const sent = await queues.send('emails', { to: 'user@example.com' }, { delay_seconds: 60 })
+
+      expect(mockFetch.lastUrl).toBe('/api/v1/queues/emails/messages')
+      expect(mockFetch.lastBody).toEqual({
+        messages: [{ body: { to: 'user@example.com' }, delay_seconds: 60 }],
+      })
+      expect(sent).toBe(1)
+    })
+
+    it('should read messages', async () => {
+      mockFetch.mockResponse = { messages: [{ id: 7, body: { to: 'user@example.com' }, read_count: 1 }] }
+
+      const messages = await queues.read<{ to: string }>('emails', { group: 'analytics', limit: 10 })
+
+      expect(mockFetch.lastUrl).toBe('/api/v1/queues/emails/read')
+      expect(mockFetch.lastBody).toEqual({ group: 'analytics', limit: 10 })
+      expect(messages[0].body.to).toBe('user@example.com')
+    })
+
+    it('should acknowledge messages', async () => {
+      mockFetch.mockResponse = { acknowledged: 2 }
+
+      const acked = await queues.ack('emails', [1, 2])
+
+      expect(mockFetch.lastUrl).toBe('/api/v1/queues/emails/ack')
+      expect(mockFetch.lastBody).toEqual({ group: undefined, ids: [1, 2] })
+      expect(acked).toBe(2)
+    })
+
+    it('should reject messages', async () => {
+      mockFetch.mockResponse = { rejected: 1 }
+
+      const rejected = await queues.nack('emails', [3], { delay_seconds: 30, error: 'SMTP timeout' })
+
+      expect(mockFetch.lastUrl).toBe('/api/v1/queues/emails/nack')
+      expect(mockFetch.lastBody).toEqual({ ids: [3], delay_seconds: 30, error: 'SMTP timeout' })
+      expect(rejected).toBe(1)
+    })
+
+    it('should purge a consumer group', async () => {
+      mockFetch.mockResponse = { purged: 5 }
+
+      const purged = await queues.purge('emails', 'analytics')
+
+      expect(mockFetch.lastUrl).toBe('/api/v1/queues/emails/messages?group=analytics')
+      expect(mockFetch.lastMethod).toBe('DELETE')
+      expect(purged).toBe(5)
+    })
+
+    it('should propagate errors', async () => {
+      mockFetch.shouldThrow = true
+      mockFetch.errorMessage = 'Queue not found'
+
+      await expect(queues.read('missing')).rejects.toThrow('Queue not found')
+    })
+  })
+})
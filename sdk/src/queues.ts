@@ -0,0 +1,352 @@
+/**
+ * Message Queues module for Fluxbase SDK
+ *
+ * Provides methods for managing message queues, sending messages and consuming
+ * them with visibility timeouts. Every consumer group of a queue receives its own
+ * copy of each message, and messages that keep failing are moved to the queue's
+ * dead-letter queue.
+ *
+ * Queues require the admin or service role.
+ *
+ * @example
+ * ```typescript
+ * // Create a queue
+ * await client.queues.create({ name: 'emails', dead_letter_queue: 'emails-dead' })
+ *
+ * // Send a message
+ * await client.queues.send('emails', { to: 'user@example.com' })
+ *
+ * // Read, process and acknowledge messages
+ * const messages = await client.queues.read('emails', { limit: 10 })
+ * await client.queues.ack('emails', messages.map(m => m.id))
+ * ```
+ *
+ * @category Queues
+ */
+
+import type { FluxbaseFetch } from './fetch'
+
+/**
+ * Pending messages of a consumer group
+ */
+export interface QueueConsumerGroup {
+  name: string
+  /** Number of messages that can be read now */
+  ready: number
+  /** Number of messages read and not yet acknowledged, or delayed */
+  in_flight: number
+  /** When the oldest ready message was enqueued */
+  oldest_ready_at: string | null
+}
+
+/**
+ * A message queue and its delivery settings
+ */
+export interface Queue {
+  id: string
+  name: string
+  /** Default number of seconds a read message stays hidden from other consumers */
+  visibility_timeout: number
+  /** Number of deliveries after which a message is dead-lettered */
+  max_deliveries: number
+  dead_letter_queue: string | null
+  consumer_groups: QueueConsumerGroup[]
+  created_by?: string
+  created_at: string
+  updated_at: string
+}
+
+/**
+ * Request to create a queue
+ */
+export interface CreateQueueRequest {
+  name: string
+  /** Defaults to 30 seconds */
+  visibility_timeout?: number
+  /** Defaults to 5 */
+  max_deliveries?: number
+  dead_letter_queue?: string
+}
+
+/**
+ * Request to update a queue. An empty dead_letter_queue removes it.
+ */
+export interface UpdateQueueRequest {
+  visibility_timeout?: number
+  max_deliveries?: number
+  dead_letter_queue?: string
+}
+
+/**
+ * A message delivered to a consumer group
+ */
+export interface QueueMessage<T = unknown> {
+  id: number
+  body: T
+  headers?: Record<string, string>
+  /** Number of times the message was delivered, including this delivery */
+  read_count: number
+  enqueued_at: string
+  /** When the message becomes visible again unless it is acknowledged */
+  visible_at: string
+  /** Error given when the message was last rejected */
+  last_error?: string
+  /** Queue the message was dead-lettered from */
+  dead_lettered_from?: string
+}
+
+/**
+ * Options for sending a message
+ */
+export interface SendMessageOptions {
+  headers?: Record<string, string>
+  /** Number of seconds before the message can be read */
+  delay_seconds?: number
+}
+
+/**
+ * A message of a batch send
+ */
+export interface OutgoingQueueMessage<T = unknown> extends SendMessageOptions {
+  body: T
+}
+
+/**
+ * Options for reading messages
+ */
+export interface ReadMessagesOptions {
+  /** Consumer group to read from, defaults to "default" */
+  group?: string
+  /** Maximum number of messages, defaults to 1 */
+  limit?: number
+  /** Seconds the messages stay hidden, defaults to the queue's visibility timeout */
+  visibility_timeout?: number
+}
+
+/**
+ * Options for rejecting messages
+ */
+export interface NackMessagesOptions {
+  /** Consumer group the messages were read from, defaults to "default" */
+  group?: string
+  /** Seconds before the messages can be read again */
+  delay_seconds?: number
+  /** Reason recorded on the messages */
+  error?: string
+}
+
+/**
+ * Queues Manager for message queues with consumer groups
+ *
+ * Messages are delivered at least once: a message that is not acknowledged before
+ * its visibility timeout expires is delivered again, and once it was delivered
+ * max_deliveries times it is moved to the dead-letter queue (or discarded when the
+ * queue has none).
+ *
+ * @example
+ * ```typescript
+ * // Create a queue and a second consumer group
+ * await client.queues.create({ name: 'orders', visibility_timeout: 60 })
+ * await client.queues.createGroup('orders', 'analytics')
+ *
+ * // Send messages
+ * await client.queues.send('orders', { order_id: 42 }, { headers: { source: 'checkout' } })
+ *
+ * // Consume messages of a group
+ * const messages = await client.queues.read<{ order_id: number }>('orders', { group: 'analytics', limit: 10 })
+ * for (const message of messages) {
+ *   try {
+ *     await process(message.body)
+ *     await client.queues.ack('orders', [message.id], 'analytics')
+ *   } catch (err) {
+ *     await client.queues.nack('orders', [message.id], { group: 'analytics', delay_seconds: 30, error: String(err) })
+ *   }
+ * }
+ * ```
+ *
+ * @category Queues
+ */
+export class QueuesManager {
+  private fetch: FluxbaseFetch
+
+  constructor(fetch: FluxbaseFetch) {
+    this.fetch = fetch
+  }
+
+  /**
+   * List queues with the pending messages of their consumer groups
+   *
+   * @returns Promise resolving to the queues
+   */
+  async list(): Promise<Queue[]> {
+    const response = await this.fetch.get<{ queues: Queue[] }>('/api/v1/queues')
+    return response.queues || []
+  }
+
+  /**
+   * Get a queue with the pending messages of its consumer groups
+   *
+   * @param name - Queue name
+   * @returns Promise resolving to the queue
+   */
+  async get(name: string): Promise<Queue> {
+    return await this.fetch.get<Queue>(`/api/v1/queues/${encodeURIComponent(name)}`)
+  }
+
+  /**
+   * Create a queue with a "default" consumer group
+   *
+   * @param request - Queue name and delivery settings
+   * @returns Promise resolving to the created queue
+   *
+   * @example
+   * ```typescript
+   * await client.queues.create({ name: 'emails-dead' })
+   * await client.queues.create({
+   *   name: 'emails',
+   *   visibility_timeout: 120,
+   *   max_deliveries: 3,
+   *   dead_letter_queue: 'emails-dead'
+   * })
+   * ```
+   */
+  async create(request: CreateQueueRequest): Promise<Queue> {
+    return await this.fetch.post<Queue>('/api/v1/queues', request)
+  }
+
+  /**
+   * Update the delivery settings of a queue
+   *
+   * @param name - Queue name
+   * @param request - Settings to change
+   * @returns Promise resolving to the updated queue
+   */
+  async update(name: string, request: UpdateQueueRequest): Promise<Queue> {
+    return await this.fetch.patch<Queue>(`/api/v1/queues/${encodeURIComponent(name)}`, request)
+  }
+
+  /**
+   * Delete a queue with its consumer groups and messages
+   *
+   * @param name - Queue name
+   */
+  async delete(name: string): Promise<void> {
+    await this.fetch.delete(`/api/v1/queues/${encodeURIComponent(name)}`)
+  }
+
+  /**
+   * Add a consumer group. The group receives the messages sent from now on.
+   *
+   * @param name - Queue name
+   * @param group - Consumer group name
+   */
+  async createGroup(name: string, group: string): Promise<void> {
+    await this.fetch.post(`/api/v1/queues/${encodeURIComponent(name)}/groups`, { name: group })
+  }
+
+  /**
+   * Delete a consumer group and its pending messages
+   *
+   * @param name - Queue name
+   * @param group - Consumer group name
+   */
+  async deleteGroup(name: string, group: string): Promise<void> {
+    await this.fetch.delete(`/api/v1/queues/${encodeURIComponent(name)}/groups/${encodeURIComponent(group)}`)
+  }
+
+  /**
+   * Send a message to every consumer group of a queue
+   *
+   * @param name - Queue name
+   * @param body - JSON message body
+   * @param options - Headers and delay
+   * @returns Promise resolving to the number of messages sent
+   *
+   * @example
+   * ```typescript
+   * await client.queues.send('emails', { to: 'user@example.com' }, { delay_seconds: 60 })
+   * ```
+   */
+  async send<T = unknown>(name: string, body: T, options?: SendMessageOptions): Promise<number> {
+    return await this.sendBatch(name, [{ body, ...options }])
+  }
+
+  /**
+   * Send several messages to every consumer group of a queue in one request
+   *
+   * @param name - Queue name
+   * @param messages - Messages to send
+   * @returns Promise resolving to the number of messages sent
+   */
+  async sendBatch<T = unknown>(name: string, messages: OutgoingQueueMessage<T>[]): Promise<number> {
+    const response = await this.fetch.post<{ sent: number }>(
+      `/api/v1/queues/${encodeURIComponent(name)}/messages`,
+      { messages }
+    )
+    return response.sent
+  }
+
+  /**
+   * Read visible messages of a consumer group. The messages stay hidden from other
+   * consumers until the visibility timeout expires and must be acknowledged once
+   * processed.
+   *
+   * @param name - Queue name
+   * @param options - Consumer group, limit and visibility timeout
+   * @returns Promise resolving to the messages, empty when none are visible
+   */
+  async read<T = unknown>(name: string, options?: ReadMessagesOptions): Promise<QueueMessage<T>[]> {
+    const response = await this.fetch.post<{ messages: QueueMessage<T>[] }>(
+      `/api/v1/queues/${encodeURIComponent(name)}/read`,
+      options ?? {}
+    )
+    return response.messages || []
+  }
+
+  /**
+   * Acknowledge processed messages, deleting them
+   *
+   * @param name - Queue name
+   * @param ids - Message IDs
+   * @param group - Consumer group the messages were read from, defaults to "default"
+   * @returns Promise resolving to the number of messages acknowledged
+   */
+  async ack(name: string, ids: number[], group?: string): Promise<number> {
+    const response = await this.fetch.post<{ acknowledged: number }>(
+      `/api/v1/queues/${encodeURIComponent(name)}/ack`,
+      { group, ids }
+    )
+    return response.acknowledged
+  }
+
+  /**
+   * Reject messages, returning them to the queue
+   *
+   * @param name - Queue name
+   * @param ids - Message IDs
+   * @param options - Consumer group, delay and error
+   * @returns Promise resolving to the number of messages rejected
+   */
+  async nack(name: string, ids: number[], options?: NackMessagesOptions): Promise<number> {
+    const response = await this.fetch.post<{ rejected: number }>(
+      `/api/v1/queues/${encodeURIComponent(name)}/nack`,
+      { ...options, ids }
+    )
+    return response.rejected
+  }
+
+  /**
+   * Delete all messages of a queue, or of one consumer group
+   *
+   * @param name - Queue name
+   * @param group - Consumer group to purge, all groups when omitted
+   * @returns Promise resolving to the number of messages deleted
+   */
+  async purge(name: string, group?: string): Promise<number> {
+    const query = group ? `?group=${encodeURIComponent(group)}` : ''
+    const response = await this.fetch.delete<{ purged: number }>(
+      `/api/v1/queues/${encodeURIComponent(name)}/messages${query}`
+    )
+    return response.purged
+  }
+}
@@ -1256,6 +1256,65 @@ describe('EmailTemplateManager', () => {
         recipient_email: 'test@example.com',
       })
     })
+
+    it('should send test email for a locale', async () => {
+      vi.mocked(mockFetch.post).mockResolvedValue(undefined)
+
+      await manager.test('otp', 'test@example.com', 'pt-BR')
+
+      expect(mockFetch.post).toHaveBeenCalledWith('/api/v1/admin/email/templates/otp/test', {
+        recipient_email: 'test@example.com',
+        locale: 'pt-BR',
+      })
+    })
+  })
+
+  describe('locale variants', () => {
+    it('should get, update and reset a locale variant', async () => {
+      vi.mocked(mockFetch.get).mockResolvedValue(mockTemplate)
+      vi.mocked(mockFetch.put).mockResolvedValue(mockTemplate)
+      vi.mocked(mockFetch.post).mockResolvedValue(mockTemplate)
+
+      await manager.get('magic_link', 'de')
+      await manager.update('magic_link', { subject: 'Anmelden', html_body: '<p>Hallo</p>' }, 'de')
+      await manager.reset('magic_link', 'de')
+
+      expect(mockFetch.get).toHaveBeenCalledWith('/api/v1/admin/email/templates/magic_link?locale=de')
+      expect(mockFetch.put).toHaveBeenCalledWith('/api/v1/admin/email/templates/magic_link?locale=de', {
+        subject: 'Anmelden',
+        html_body: '<p>Hallo</p>',
+      })
+      expect(mockFetch.post).toHaveBeenCalledWith('/api/v1/admin/email/templates/magic_link/reset?locale=de', {})
+    })
+  })
+
+  describe('preview', () => {
+    it('should preview a draft', async () => {
+      const rendered = { template_type: 'otp', locale: 'de', subject: 'Code', html_body: '<p>123456</p>', variables: ['Code'] }
+      vi.mocked(mockFetch.post).mockResolvedValue(rendered)
+
+      const result = await manager.preview('otp', { locale: 'de', subject: 'Code', html_body: '<p>{{.Code}}</p>' })
+
+      expect(mockFetch.post).toHaveBeenCalledWith('/api/v1/admin/email/templates/otp/preview', {
+        locale: 'de',
+        subject: 'Code',
+        html_body: '<p>{{.Code}}</p>',
+      })
+      expect(result).toEqual(rendered)
+    })
+  })
+
+  describe('versions', () => {
+    it('should list and restore versions', async () => {
+      vi.mocked(mockFetch.get).mockResolvedValue({ versions: [], count: 0 })
+      vi.mocked(mockFetch.post).mockResolvedValue(mockTemplate)
+
+      await manager.versions('password_reset', 'fr')
+      await manager.restoreVersion('password_reset', 2)
+
+      expect(mockFetch.get).toHaveBeenCalledWith('/api/v1/admin/email/templates/password_reset/versions?locale=fr')
+      expect(mockFetch.post).toHaveBeenCalledWith('/api/v1/admin/email/templates/password_reset/versions/2/restore', {})
+    })
   })
 })
 
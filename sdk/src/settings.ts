@@ -11,6 +11,9 @@ import type {
   EmailTemplateType,
   UpdateEmailTemplateRequest,
   ListEmailTemplatesResponse,
+  ListEmailTemplateVersionsResponse,
+  PreviewEmailTemplateRequest,
+  PreviewEmailTemplateResponse,
   EmailProviderSettings,
   UpdateEmailProviderSettingsRequest,
   TestEmailSettingsResponse,
@@ -977,7 +980,8 @@ export class EmailTemplateManager {
   /**
    * Get a specific email template by type
    *
-   * @param type - Template type (magic_link | verify_email | reset_password | invite_user)
+   * @param type - Template type (magic_link | email_verification | password_reset | invitation | otp)
+   * @param locale - Locale variant (e.g. `de`, `pt-BR`); omit for the default variant
    * @returns Promise resolving to EmailTemplate
    *
    * @example
@@ -985,25 +989,29 @@ export class EmailTemplateManager {
    * const template = await client.admin.emailTemplates.get('magic_link')
    * console.log(template.subject)
    * console.log(template.html_body)
+   *
+   * const german = await client.admin.emailTemplates.get('magic_link', 'de')
    * ```
    */
-  async get(type: EmailTemplateType): Promise<EmailTemplate> {
+  async get(type: EmailTemplateType, locale?: string): Promise<EmailTemplate> {
     return await this.fetch.get<EmailTemplate>(
-      `/api/v1/admin/email/templates/${type}`,
+      `/api/v1/admin/email/templates/${type}${localeQuery(locale)}`,
     );
   }
 
   /**
-   * Update an email template
+   * Update an email template. Every update is saved as a new version.
    *
-   * Available template variables:
-   * - magic_link: `{{.MagicLink}}`, `{{.AppName}}`, `{{.ExpiryMinutes}}`
-   * - verify_email: `{{.VerificationLink}}`, `{{.AppName}}`
-   * - reset_password: `{{.ResetLink}}`, `{{.AppName}}`, `{{.ExpiryMinutes}}`
-   * - invite_user: `{{.InviteLink}}`, `{{.AppName}}`, `{{.InviterName}}`
+   * Available template variables (all templates also get `{{.AppName}}`, `{{.Email}}` and `{{.Locale}}`):
+   * - magic_link: `{{.MagicLink}}`, `{{.Link}}`, `{{.Token}}`
+   * - email_verification: `{{.VerificationLink}}`, `{{.Link}}`, `{{.Token}}`
+   * - password_reset: `{{.ResetLink}}`, `{{.Link}}`, `{{.Token}}`
+   * - invitation: `{{.InviteLink}}`, `{{.InviterName}}`
+   * - otp: `{{.Code}}`, `{{.Purpose}}`
    *
    * @param type - Template type to update
    * @param request - Update request with subject, html_body, and optional text_body
+   * @param locale - Locale variant to update; omit for the default variant
    * @returns Promise resolving to EmailTemplate
    *
    * @example
@@ -1018,9 +1026,10 @@ export class EmailTemplateManager {
   async update(
     type: EmailTemplateType,
     request: UpdateEmailTemplateRequest,
+    locale?: string,
   ): Promise<EmailTemplate> {
     return await this.fetch.put<EmailTemplate>(
-      `/api/v1/admin/email/templates/${type}`,
+      `/api/v1/admin/email/templates/${type}${localeQuery(locale)}`,
       request,
     );
   }
@@ -1029,8 +1038,10 @@ export class EmailTemplateManager {
    * Reset an email template to default
    *
    * Removes any customizations and restores the template to its original state.
+   * A reset locale variant falls back to the default variant. Saved versions are kept.
    *
    * @param type - Template type to reset
+   * @param locale - Locale variant to reset; omit for the default variant
    * @returns Promise resolving to EmailTemplate - The default template
    *
    * @example
@@ -1038,9 +1049,71 @@ export class EmailTemplateManager {
    * const defaultTemplate = await client.admin.emailTemplates.reset('magic_link')
    * ```
    */
-  async reset(type: EmailTemplateType): Promise<EmailTemplate> {
+  async reset(type: EmailTemplateType, locale?: string): Promise<EmailTemplate> {
+    return await this.fetch.post<EmailTemplate>(
+      `/api/v1/admin/email/templates/${type}/reset${localeQuery(locale)}`,
+      {},
+    );
+  }
+
+  /**
+   * Render a template with sample data without sending it
+   *
+   * @param type - Template type to preview
+   * @param request - A draft to render, or only a locale to render the saved template
+   * @returns Promise resolving to the rendered subject and bodies
+   *
+   * @example
+   * ```typescript
+   * const preview = await client.admin.emailTemplates.preview('otp', {
+   *   locale: 'de',
+   *   subject: 'Dein Code für ' + '{{.AppName}}',
+   *   html_body: '<p>' + '{{.Code}}' + '</p>',
+   * })
+   * console.log(preview.html_body)
+   * ```
+   */
+  async preview(
+    type: EmailTemplateType,
+    request: PreviewEmailTemplateRequest = {},
+  ): Promise<PreviewEmailTemplateResponse> {
+    return await this.fetch.post<PreviewEmailTemplateResponse>(
+      `/api/v1/admin/email/templates/${type}/preview`,
+      request,
+    );
+  }
+
+  /**
+   * List the saved versions of a template, newest first
+   *
+   * @param type - Template type
+   * @param locale - Locale variant; omit for the default variant
+   * @returns Promise resolving to ListEmailTemplateVersionsResponse
+   */
+  async versions(
+    type: EmailTemplateType,
+    locale?: string,
+  ): Promise<ListEmailTemplateVersionsResponse> {
+    return await this.fetch.get<ListEmailTemplateVersionsResponse>(
+      `/api/v1/admin/email/templates/${type}/versions${localeQuery(locale)}`,
+    );
+  }
+
+  /**
+   * Restore an earlier version of a template. It is saved as the newest version.
+   *
+   * @param type - Template type
+   * @param version - Version to restore
+   * @param locale - Locale variant; omit for the default variant
+   * @returns Promise resolving to the restored EmailTemplate
+   */
+  async restoreVersion(
+    type: EmailTemplateType,
+    version: number,
+    locale?: string,
+  ): Promise<EmailTemplate> {
     return await this.fetch.post<EmailTemplate>(
-      `/api/v1/admin/email/templates/${type}/reset`,
+      `/api/v1/admin/email/templates/${type}/versions/${version}/restore${localeQuery(locale)}`,
       {},
     );
   }
@@ -1052,6 +1125,7 @@ export class EmailTemplateManager {
    *
    * @param type - Template type to test
    * @param recipientEmail - Email address to send test to
+   * @param locale - Locale whose variant to send; falls back like real emails do
    * @returns Promise<void>
    *
    * @example
@@ -1059,13 +1133,25 @@ export class EmailTemplateManager {
    * await client.admin.emailTemplates.test('magic_link', 'test@example.com')
    * ```
    */
-  async test(type: EmailTemplateType, recipientEmail: string): Promise<void> {
+  async test(
+    type: EmailTemplateType,
+    recipientEmail: string,
+    locale?: string,
+  ): Promise<void> {
     await this.fetch.post(`/api/v1/admin/email/templates/${type}/test`, {
       recipient_email: recipientEmail,
+      ...(locale ? { locale } : {}),
     });
   }
 }
 
+/**
+ * Returns the locale query string for an email template variant
+ */
+function localeQuery(locale?: string): string {
+  return locale ? `?locale=${encodeURIComponent(locale)}` : "";
+}
+
 /**
  * Email Settings Manager
  *
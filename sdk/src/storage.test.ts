@@ -387,6 +387,37 @@ describe('StorageBucket - URL Generation', () => {
     expect(fetch.lastBody).toHaveProperty('expires_in')
     expect(error).toBeNull()
   })
+
+  it('should create signed upload URL', async () => {
+    fetch.mockResponse = {
+      signed_url: 'http://example.com/api/v1/storage/object?token=abc',
+      key: 'users/42.png',
+      max_size: 1024,
+      content_types: ['image/png'],
+    }
+
+    const { data, error } = await bucket.createSignedUploadUrl('users/42.png', {
+      expiresIn: 600,
+      contentTypes: ['image/png'],
+      maxSize: 1024,
+    })
+
+    expect(fetch.lastUrl).toContain('/api/v1/storage/public-files/sign/users/42.png')
+    expect(fetch.lastBody).toEqual({
+      method: 'PUT',
+      expires_in: 600,
+      content_types: ['image/png'],
+      max_size: 1024,
+      upsert: undefined,
+    })
+    expect(error).toBeNull()
+    expect(data).toEqual({
+      signedUrl: 'http://example.com/api/v1/storage/object?token=abc',
+      path: 'users/42.png',
+      maxSize: 1024,
+      contentTypes: ['image/png'],
+    })
+  })
 })
 
 describe('Storage - Error Handling', () => {
@@ -10,6 +10,8 @@ import type {
   StreamUploadOptions,
   ListOptions,
   SignedUrlOptions,
+  SignedUploadUrlOptions,
+  SignedUploadUrl,
   DownloadOptions,
   StreamDownloadData,
   ResumableDownloadOptions,
@@ -1222,6 +1224,88 @@ export class StorageBucket {
     }
   }
 
+  /**
+   * Create a signed URL that uploads a file without authentication, for example from a
+   * mobile client. The upload acts as the current user, so the bucket's policies apply.
+   * @param path - The file path
+   * @param options - Expiry, allowed content types, maximum size and overwrite behavior
+   *
+   * @example
+   * ```typescript
+   * const { data } = await storage.from('avatars').createSignedUploadUrl('users/42.png', {
+   *   expiresIn: 600,
+   *   contentTypes: ['image/png', 'image/jpeg'],
+   *   maxSize: 5 * 1024 * 1024,
+   * });
+   * ```
+   */
+  async createSignedUploadUrl(
+    path: string,
+    options?: SignedUploadUrlOptions,
+  ): Promise<{ data: SignedUploadUrl | null; error: Error | null }> {
+    try {
+      const data = await this.fetch.post<{
+        signed_url: string;
+        key: string;
+        max_size: number;
+        content_types: string[] | null;
+      }>(`/api/v1/storage/${this.bucketName}/sign/${path}`, {
+        method: "PUT",
+        expires_in: options?.expiresIn || 3600,
+        content_types: options?.contentTypes,
+        max_size: options?.maxSize,
+        upsert: options?.upsert,
+      });
+
+      return {
+        data: {
+          signedUrl: data.signed_url,
+          path: data.key,
+          maxSize: data.max_size,
+          contentTypes: data.content_types || [],
+        },
+        error: null,
+      };
+    } catch (error) {
+      return { data: null, error: error as Error };
+    }
+  }
+
+  /**
+   * Upload a file to a signed upload URL created with createSignedUploadUrl
+   * @param signedUrl - The signed upload URL
+   * @param file - The file to upload
+   * @param options - Content type of the file (default: the file's type)
+   */
+  async uploadToSignedUrl(
+    signedUrl: string,
+    file: File | Blob | ArrayBuffer | Uint8Array,
+    options?: { contentType?: string },
+  ): Promise<{ data: { path: string; size: number } | null; error: Error | null }> {
+    try {
+      const contentType =
+        options?.contentType ||
+        (file instanceof Blob && file.type) ||
+        "application/octet-stream";
+
+      const response = await fetch(signedUrl, {
+        method: "PUT",
+        headers: { "Content-Type": contentType },
+        body: file as BodyInit,
+      });
+
+      if (!response.ok) {
+        const errorData = await response.json().catch(() => ({ error: response.statusText }));
+        throw new Error(errorData.error || `Upload failed: ${response.statusText}`);
+      }
+
+      const result = await response.json();
+      return { data: { path: result.key, size: result.size }, error: null };
+    } catch (error) {
+      return { data: null, error: error as Error };
+    }
+  }
+
   /**
    * Move a file to a new location
    * @param fromPath - Current file path
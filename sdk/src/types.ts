@@ -197,8 +197,27 @@ export interface SignInWith2FAResponse {
 
 export interface FluxbaseError extends Error {
   status?: number;
+  /** Machine-readable error code from the response, e.g. INVALID_REQUEST_BODY */
   code?: string;
   details?: unknown;
+  /** Request ID from the response, for finding the request in the server logs */
+  requestId?: string;
+  /** Seconds to wait before retrying, from the Retry-After header of a 429 response */
+  retryAfter?: number;
+  /** Rate limit quota, from the X-RateLimit-* headers of a 429 response */
+  rateLimit?: RateLimitInfo;
+}
+
+/**
+ * Rate limit quota reported by the X-RateLimit-* response headers
+ */
+export interface RateLimitInfo {
+  /** Maximum requests allowed in the window */
+  limit: number;
+  /** Requests left in the current window */
+  remaining: number;
+  /** When the window resets */
+  reset: Date;
 }
 
 export type HttpMethod = "GET" | "POST" | "PUT" | "PATCH" | "DELETE" | "HEAD";
@@ -281,6 +300,7 @@ export type FilterOperator =
   | "st_touches" // geometries touch
   | "st_crosses" // geometries cross
   | "st_overlaps" // geometries overlap
+  | "bbox" // bounding boxes intersect
   | "between" // inclusive range filter (value >= min AND value <= max)
   | "not.between" // exclusive range filter (value < min OR value > max)
   // pgvector similarity operators
@@ -304,6 +324,8 @@ export interface OrderBy {
   vectorOp?: "vec_l2" | "vec_cos" | "vec_ip";
   /** Vector value for similarity ordering */
   vectorValue?: number[];
+  /** GeoJSON geometry for distance ordering (nearest first) */
+  geometry?: unknown;
 }
 
 /**
@@ -536,6 +558,28 @@ export interface SignedUrlOptions {
   transform?: TransformOptions;
 }
 
+export interface SignedUploadUrlOptions {
+  /** Expiration time in seconds (default: 3600 = 1 hour, at most 7 days) */
+  expiresIn?: number;
+  /** MIME types the upload may have, wildcards like image/* allowed (default: the bucket's allowed types) */
+  contentTypes?: string[];
+  /** Maximum upload size in bytes (default: the bucket's, or the server's, upload limit) */
+  maxSize?: number;
+  /** Overwrite an existing object (default: false) */
+  upsert?: boolean;
+}
+
+export interface SignedUploadUrl {
+  /** URL to PUT the file to, no authentication needed */
+  signedUrl: string;
+  /** Object path the upload is stored at */
+  path: string;
+  /** Maximum upload size in bytes */
+  maxSize: number;
+  /** MIME types the upload may have (empty allows any) */
+  contentTypes: string[];
+}
+
 export interface DownloadOptions {
   /** If true, returns a ReadableStream instead of Blob */
   stream?: boolean;
@@ -732,10 +776,27 @@ export interface FileShare {
 }
 
 // Bucket Settings Types (RLS)
+
+/**
+ * How HTML, SVG and XML objects are served: forced download, as text/plain,
+ * or inline under a Content-Security-Policy sandbox
+ */
+export type BucketHtmlPolicy = "attachment" | "text" | "sandbox";
+
 export interface BucketSettings {
   public?: boolean;
   allowed_mime_types?: string[];
   max_file_size?: number;
+  /** Default Content-Disposition for downloads (default: attachment) */
+  content_disposition?: "attachment" | "inline";
+  /** MIME types that may be displayed inline, wildcards like image/* allowed (null uses the built-in safe list) */
+  inline_mime_types?: string[] | null;
+  /** Send X-Content-Type-Options: nosniff with downloads (default: true) */
+  nosniff?: boolean;
+  /** How HTML-like objects are served (default: attachment) */
+  html_policy?: BucketHtmlPolicy;
+  /** Allow on-the-fly image transformations of the bucket's objects (default: true) */
+  transformations_enabled?: boolean;
 }
 
 export interface Bucket {
@@ -744,6 +805,11 @@ export interface Bucket {
   public: boolean;
   allowed_mime_types: string[];
   max_file_size?: number;
+  content_disposition?: "attachment" | "inline";
+  inline_mime_types?: string[] | null;
+  nosniff?: boolean;
+  html_policy?: BucketHtmlPolicy;
+  transformations_enabled?: boolean;
   created_at: string;
   updated_at: string;
 }
@@ -1047,6 +1113,53 @@ export interface UnlinkIdentityParams {
   identity: UserIdentity;
 }
 
+// Known Device Types
+
+/**
+ * A device the user has signed in from, identified by the device fingerprint
+ * sent with sign-in. A sign-in from a device that isn't known emails the user.
+ */
+export interface KnownDevice {
+  id: string;
+  user_id: string;
+  /** Client IP address of the latest sign-in from the device */
+  ip_address?: string;
+  /** Approximate location of the latest sign-in, e.g. "Berlin, BE, DE" */
+  location?: string;
+  user_agent?: string;
+  first_seen_at: string;
+  last_seen_at: string;
+}
+
+export interface KnownDevicesResponse {
+  devices: KnownDevice[];
+}
+
+// Data Export Types
+
+export type DataExportStatus = "pending" | "processing" | "completed" | "failed";
+
+/**
+ * An archive of everything Fluxbase holds about the user (profile, identities,
+ * sessions, devices, storage objects, AI documents and conversations)
+ */
+export interface DataExport {
+  id: string;
+  user_id: string;
+  status: DataExportStatus;
+  /** Size of the archive in bytes, once completed */
+  size_bytes?: number;
+  error_message?: string;
+  created_at: string;
+  completed_at?: string;
+  /** When the archive is deleted and can no longer be downloaded */
+  expires_at?: string;
+}
+
+export interface DataExportsResponse {
+  exports: DataExport[];
+}
+
 // Reauthenticate Types
 export interface ReauthenticateResponse {
   nonce: string;
@@ -1129,17 +1242,48 @@ export interface EnrichedUser {
   session_count?: number;
   is_anonymous?: boolean;
   metadata?: Record<string, unknown>;
+  /** Whether the user is banned or locked out right now */
+  is_locked?: boolean;
+  /** When a temporary ban or lockout ends; unset while banned until unbanned */
+  locked_until?: string;
 }
 
 export interface ListUsersResponse {
   users: EnrichedUser[];
   total: number;
+  limit?: number;
+  offset?: number;
+}
+
+/**
+ * A set of app users whose email addresses are aliases of each other
+ * (e.g. Gmail dots or "+tag" suffixes)
+ */
+export interface DuplicateEmailGroup {
+  normalized_email: string;
+  users: Array<{
+    id: string;
+    email: string;
+    email_verified: boolean;
+    created_at: string;
+  }>;
+}
+
+export interface ListDuplicateEmailsResponse {
+  groups: DuplicateEmailGroup[];
+  total: number;
 }
 
 export interface ListUsersOptions {
   exclude_admins?: boolean;
+  /** Part of the email (case-insensitive) or a user ID */
   search?: string;
+  /** Keys and values that user_metadata or app_metadata must contain */
+  metadata?: Record<string, unknown>;
+  /** Only banned (true) or only active (false) users */
+  banned?: boolean;
   limit?: number;
+  offset?: number;
   type?: "app" | "dashboard";
 }
 
@@ -1167,6 +1311,29 @@ export interface DeleteUserResponse {
   message: string;
 }
 
+/**
+ * Ban of a user. Without a duration or until the ban lasts until the user is unbanned.
+ */
+export interface BanUserRequest {
+  /** How long the ban lasts, e.g. "24h" */
+  duration?: string;
+  /** When the ban ends (RFC 3339) */
+  until?: string;
+  /** Recorded in the audit log */
+  reason?: string;
+}
+
+export interface BanUserResponse {
+  message: string;
+  banned_until: string | null;
+}
+
+export interface ForcePasswordResetResponse {
+  message: string;
+  /** Whether a password reset link was emailed to the user */
+  email_sent: boolean;
+}
+
 // ============================================================================
 // Client Keys Management Types
 // ============================================================================
@@ -1178,6 +1345,16 @@ export interface ClientKey {
   key_prefix: string;
   scopes: string[];
   rate_limit_per_minute: number;
+  /** Key case of table rows in REST requests and responses made with the key */
+  json_key_case?: JSONKeyCase;
+  /** Tables the key is limited to (default: all tables its scopes allow) */
+  allowed_tables?: Record<string, TableAccess>;
+  /** Storage buckets the key is limited to; a trailing `*` matches by prefix */
+  allowed_buckets?: string[];
+  /** IP addresses and CIDR ranges the key can be used from */
+  allowed_ips?: string[];
+  /** Key that replaced this one when it was rotated */
+  rotated_to?: string;
   created_at: string;
   updated_at?: string;
   expires_at?: string;
@@ -1186,12 +1363,27 @@ export interface ClientKey {
   user_id: string;
 }
 
+/**
+ * Access of a client key to a table it is limited to: "write" includes "read"
+ */
+export type TableAccess = "read" | "write";
+
+/**
+ * Key case of table rows: "snake" uses column names, "camel" exposes snake_case columns as camelCase keys
+ */
+export type JSONKeyCase = "snake" | "camel";
+
 export interface CreateClientKeyRequest {
   name: string;
   description?: string;
   scopes: string[];
   rate_limit_per_minute: number;
   expires_at?: string;
+  json_key_case?: JSONKeyCase;
+  /** Limit the key to tables, as `schema.table` (public when omitted) or `schema.*` */
+  allowed_tables?: Record<string, TableAccess>;
+  allowed_buckets?: string[];
+  allowed_ips?: string[];
 }
 
 export interface CreateClientKeyResponse {
@@ -1209,6 +1401,20 @@ export interface UpdateClientKeyRequest {
   description?: string;
   scopes?: string[];
   rate_limit_per_minute?: number;
+  json_key_case?: JSONKeyCase;
+  /** Replace the key's restrictions; an empty value removes the restriction */
+  allowed_tables?: Record<string, TableAccess>;
+  allowed_buckets?: string[];
+  allowed_ips?: string[];
+}
+
+export interface RotateClientKeyRequest {
+  /** Seconds the old key stays valid (default 86400, max 2592000) */
+  grace_period?: number;
+}
+
+export interface RotateClientKeyResponse extends ClientKey {
+  key: string; // Full key of the new client key - only returned once
 }
 
 export interface RevokeClientKeyResponse {
@@ -1219,6 +1425,56 @@ export interface DeleteClientKeyResponse {
   message: string;
 }
 
+export type ClientKeyBootstrapTokenStatus = "pending" | "used" | "expired" | "revoked";
+
+export interface ClientKeyBootstrapToken {
+  id: string;
+  name: string;
+  description?: string;
+  token_prefix: string;
+  scopes: string[];
+  rate_limit_per_minute: number;
+  key_ttl_seconds?: number;
+  expires_at: string;
+  created_by?: string;
+  used_at?: string;
+  used_by_ip?: string;
+  client_key_id?: string;
+  revoked_at?: string;
+  created_at: string;
+  status: ClientKeyBootstrapTokenStatus;
+}
+
+export interface CreateClientKeyBootstrapTokenRequest {
+  /** Name of the token, used as the prefix of the enrolled key's name */
+  name: string;
+  description?: string;
+  /** Scopes the enrolled key may have */
+  scopes: string[];
+  rate_limit_per_minute?: number;
+  /** How long the token can be used, in seconds (default 1 hour, max 7 days) */
+  expires_in?: number;
+  /** Lifetime of the enrolled client key, in seconds (default: no expiry) */
+  key_expires_in?: number;
+}
+
+export interface CreateClientKeyBootstrapTokenResponse extends ClientKeyBootstrapToken {
+  token: string; // Full token - only returned on creation
+}
+
+export interface EnrollClientKeyRequest {
+  /** The bootstrap token (fbb_...) */
+  token: string;
+  /** Appended to the token name to form the key name */
+  name?: string;
+  /** Narrows the token's scopes, defaults to all of them */
+  scopes?: string[];
+}
+
+export interface EnrollClientKeyResponse extends ClientKey {
+  key: string; // Full key - only returned on enrollment
+}
+
 // ============================================================================
 // client keys Management Types (Deprecated - use Client Keys instead)
 // ============================================================================
@@ -1258,13 +1514,28 @@ export interface Webhook {
   created_at: string;
   updated_at?: string;
   user_id: string;
+  max_retries?: number;
+  retry_backoff_seconds?: number;
+  retry_backoff_strategy?: WebhookRetryBackoffStrategy;
+  /** Upper bound of the wait between retries (0 for no bound) */
+  max_retry_backoff_seconds?: number;
 }
 
+/**
+ * How the wait between retries grows with failed attempts: `linear` (backoff x attempt),
+ * `exponential` (backoff x 2^(attempt-1)) or `fixed`
+ */
+export type WebhookRetryBackoffStrategy = "linear" | "exponential" | "fixed";
+
 export interface CreateWebhookRequest {
   url: string;
   events: string[];
   description?: string;
   secret?: string;
+  max_retries?: number;
+  retry_backoff_seconds?: number;
+  retry_backoff_strategy?: WebhookRetryBackoffStrategy;
+  max_retry_backoff_seconds?: number;
 }
 
 export interface UpdateWebhookRequest {
@@ -1272,6 +1543,10 @@ export interface UpdateWebhookRequest {
   events?: string[];
   description?: string;
   is_active?: boolean;
+  max_retries?: number;
+  retry_backoff_seconds?: number;
+  retry_backoff_strategy?: WebhookRetryBackoffStrategy;
+  max_retry_backoff_seconds?: number;
 }
 
 export interface ListWebhooksResponse {
@@ -1291,17 +1566,52 @@ export interface WebhookDelivery {
   webhook_id: string;
   event: string;
   payload: Record<string, unknown>;
+  status?: WebhookDeliveryStatus;
   status_code?: number;
   response_body?: string;
   error?: string;
+  attempt?: number;
+  /** The delivery this one manually replays */
+  replay_of?: string;
   created_at: string;
   delivered_at?: string;
 }
 
+export type WebhookDeliveryStatus = "pending" | "success" | "failed";
+
 export interface ListWebhookDeliveriesResponse {
   deliveries: WebhookDelivery[];
 }
 
+export interface SearchWebhookDeliveriesOptions {
+  /** Defaults to failed deliveries */
+  status?: WebhookDeliveryStatus | "all";
+  webhook_id?: string;
+  /** RFC 3339 timestamp */
+  since?: string;
+  /** RFC 3339 timestamp */
+  until?: string;
+  /** 1-500, defaults to 50 */
+  limit?: number;
+}
+
+/**
+ * Deliveries to replay: either explicit IDs, or the deliveries created in a time range
+ * (failed ones unless status is given). At most 100 are replayed at once.
+ */
+export interface ReplayWebhookDeliveriesRequest {
+  ids?: string[];
+  webhook_id?: string;
+  status?: WebhookDeliveryStatus;
+  since?: string;
+  until?: string;
+}
+
+export interface ReplayWebhookDeliveriesResponse {
+  replayed: WebhookDelivery[];
+  count: number;
+}
+
 export interface DeleteWebhookResponse {
   message: string;
 }
@@ -1390,10 +1700,37 @@ export interface SystemSetting {
   is_overridden?: boolean;
   /** The environment variable name if overridden */
   override_source?: string;
+  /** What the setting does and the impact of changing it (known settings only) */
+  metadata?: SystemSettingMetadata;
+  /** Conflicts between this setting and other settings */
+  warnings?: SystemSettingWarning[];
   created_at: string;
   updated_at: string;
 }
 
+/**
+ * Explanation and impact of a system setting
+ */
+export interface SystemSettingMetadata {
+  explanation: string;
+  /** Changes only take effect after the server restarts */
+  requires_restart: boolean;
+  /** Changing the setting affects the security of the project */
+  affects_security: boolean;
+}
+
+/**
+ * Conflict between the values of two or more system settings
+ */
+export interface SystemSettingWarning {
+  /** Stable identifier, e.g. 'EMAIL_VERIFICATION_WITHOUT_EMAIL' */
+  code: string;
+  severity: "warning" | "critical";
+  message: string;
+  /** Keys of the conflicting settings */
+  settings: string[];
+}
+
 /**
  * Request to update a system setting
  */
@@ -1660,9 +1997,11 @@ export interface UpdateAppSettingsRequest {
  */
 export type EmailTemplateType =
   | "magic_link"
-  | "verify_email"
-  | "reset_password"
-  | "invite_user";
+  | "email_verification"
+  | "password_reset"
+  | "invitation"
+  | "otp"
+  | "new_device";
 
 /**
  * Email template structure
@@ -1670,14 +2009,66 @@ export type EmailTemplateType =
 export interface EmailTemplate {
   id: string;
   template_type: EmailTemplateType;
+  /** Lowercase locale of the variant (e.g. `de`, `pt-br`), empty for the default variant */
+  locale: string;
   subject: string;
   html_body: string;
   text_body?: string;
+  /** Version of a customized template */
+  version?: number;
   is_custom: boolean;
   created_at: string;
   updated_at: string;
 }
 
+/**
+ * A saved version of a customized email template
+ */
+export interface EmailTemplateVersion {
+  id: string;
+  template_type: EmailTemplateType;
+  locale: string;
+  version: number;
+  subject: string;
+  html_body: string;
+  text_body?: string;
+  created_by?: string;
+  created_at: string;
+}
+
+/**
+ * Response when listing the versions of an email template
+ */
+export interface ListEmailTemplateVersionsResponse {
+  versions: EmailTemplateVersion[];
+  count: number;
+}
+
+/**
+ * Request to preview an email template. Without a subject and HTML body, the saved
+ * template for the locale is rendered.
+ */
+export interface PreviewEmailTemplateRequest {
+  locale?: string;
+  subject?: string;
+  html_body?: string;
+  text_body?: string;
+  /** Overrides for the sample values of the template variables */
+  data?: Record<string, string>;
+}
+
+/**
+ * A template rendered with sample data
+ */
+export interface PreviewEmailTemplateResponse {
+  template_type: EmailTemplateType;
+  locale: string;
+  subject: string;
+  html_body: string;
+  text_body?: string;
+  variables: string[];
+}
+
 /**
  * Request to update an email template
  */
@@ -1692,6 +2083,7 @@ export interface UpdateEmailTemplateRequest {
  */
 export interface TestEmailTemplateRequest {
   recipient_email: string;
+  locale?: string;
 }
 
 /**
@@ -2834,6 +3226,8 @@ export interface MigrationExecution {
   logs?: string;
   executed_at: string;
   executed_by?: string;
+  /** Tables backed up in the migrations schema before a destructive migration ran */
+  backup_tables?: string[];
 }
 
 /**
@@ -2841,6 +3235,38 @@ export interface MigrationExecution {
  */
 export interface ApplyMigrationRequest {
   namespace?: string;
+  /** Apply destructive changes without a confirmation token */
+  allow_destructive?: boolean;
+  /** Token returned by a previous 409 response, confirming its destructive changes */
+  confirmation_token?: string;
+}
+
+/**
+ * A destructive change detected in a migration
+ */
+export interface DestructiveMigrationChange {
+  kind:
+    | "drop_table"
+    | "drop_column"
+    | "narrow_type"
+    | "truncate"
+    | "unanalyzable";
+  schema?: string;
+  table?: string;
+  column?: string;
+  /** Additional context, e.g. "character varying(100) to character varying(20)" */
+  detail?: string;
+}
+
+/**
+ * Result of applying a migration
+ */
+export interface ApplyMigrationResult {
+  message: string;
+  /** Destructive changes that were applied */
+  destructive_changes?: DestructiveMigrationChange[];
+  /** Backup tables created in the migrations schema before applying */
+  backups?: string[];
 }
 
 /**
@@ -2855,6 +3281,8 @@ export interface RollbackMigrationRequest {
  */
 export interface ApplyPendingRequest {
   namespace?: string;
+  /** Apply migrations with destructive changes without confirmation */
+  allow_destructive?: boolean;
 }
 
 /**
@@ -2867,6 +3295,8 @@ export interface SyncMigrationsOptions {
   auto_apply?: boolean;
   /** Preview changes without applying them */
   dry_run?: boolean;
+  /** Apply migrations with destructive changes when auto-applying */
+  allow_destructive?: boolean;
 }
 
 /**
@@ -2899,6 +3329,8 @@ export interface SyncMigrationsResult {
   dry_run: boolean;
   /** Warning messages */
   warnings?: string[];
+  /** Backup tables created before destructive migrations were applied */
+  backups?: string[];
 }
 
 // ============================================================================
@@ -3080,12 +3512,17 @@ export type AIChatMessageRole = "user" | "assistant" | "system" | "tool";
  * AI chat message for WebSocket
  */
 export interface AIChatClientMessage {
-  type: "start_chat" | "message" | "cancel";
+  type: "start_chat" | "message" | "cancel" | "typing" | "read";
   chatbot?: string;
   namespace?: string;
   conversation_id?: string;
   content?: string;
+  client_message_id?: string; // Echoed in the delivered receipt
+  typing?: boolean; // For "typing" messages
+  sequence?: number; // For "read" messages: last message read
   impersonate_user_id?: string; // Admin-only: test as this user
+  metadata?: Record<string, string>; // For "start_chat": metadata of a new conversation
+  tags?: string[]; // For "start_chat": tags of a new conversation
 }
 
 /**
@@ -3100,7 +3537,11 @@ export interface AIChatServerMessage {
     | "tool_result"
     | "done"
     | "error"
-    | "cancelled";
+    | "cancelled"
+    | "escalated"
+    | "user_message"
+    | "typing"
+    | "receipt";
   conversation_id?: string;
   message_id?: string;
   chatbot?: string;
@@ -3114,6 +3555,18 @@ export interface AIChatServerMessage {
   usage?: AIUsageStats;
   error?: string;
   code?: string;
+  escalation_id?: string;
+  /** Per-conversation event order, identical on every connection */
+  seq?: number;
+  /** Message position in the conversation */
+  sequence?: number;
+  /** Last message read on any device (chat_started) */
+  read_sequence?: number;
+  client_message_id?: string;
+  role?: "user" | "assistant";
+  content?: string;
+  typing?: boolean;
+  status?: "delivered" | "read";
 }
 
 /**
@@ -3125,6 +3578,14 @@ export interface AIUsageStats {
   total_tokens?: number;
 }
 
+/**
+ * Business dimensions attached to a conversation, e.g. channel or customer id
+ */
+export interface AIConversationAttributes {
+  metadata?: Record<string, string>;
+  tags?: string[];
+}
+
 /**
  * AI conversation summary
  */
@@ -3142,6 +3603,8 @@ export interface AIConversation {
   updated_at: string;
   last_message_at: string;
   expires_at?: string;
+  metadata: Record<string, string>;
+  tags: string[];
 }
 
 /**
@@ -3161,6 +3624,12 @@ export interface AIConversationMessage {
   sql_duration_ms?: number;
   prompt_tokens?: number;
   completion_tokens?: number;
+  /** Model chosen by the chatbot's model routes */
+  model?: string;
+  /** Conditions of the model route that chose the model (absent for the default model) */
+  routing_rule?: string;
+  /** Cost of the message minus its cost on the default model, in USD */
+  cost_delta_usd?: number;
   created_at: string;
   sequence_number: number;
 }
@@ -3181,6 +3650,8 @@ export interface AIUserConversationSummary {
   message_count: number;
   created_at: string;
   updated_at: string;
+  metadata: Record<string, string>;
+  tags: string[];
 }
 
 /**
@@ -3207,6 +3678,7 @@ export interface AIUserUsageStats {
  */
 export interface AIUserMessage {
   id: string;
+  sequence: number;
   role: "user" | "assistant";
   content: string;
   timestamp: string;
@@ -3224,6 +3696,10 @@ export interface AIUserConversationDetail {
   title?: string;
   created_at: string;
   updated_at: string;
+  /** Sequence number of the last message read on any device */
+  last_read_sequence: number;
+  metadata: Record<string, string>;
+  tags: string[];
   messages: AIUserMessage[];
 }
 
@@ -3235,6 +3711,10 @@ export interface ListConversationsOptions {
   chatbot?: string;
   /** Filter by namespace */
   namespace?: string;
+  /** Only conversations that have all of these tags */
+  tags?: string[];
+  /** Only conversations whose metadata contains all of these values */
+  metadata?: Record<string, string>;
   /** Number of conversations to return (default: 50, max: 100) */
   limit?: number;
   /** Offset for pagination */
@@ -3250,6 +3730,32 @@ export interface ListConversationsResult {
   has_more: boolean;
 }
 
+/**
+ * Conversations sharing one value of a breakdown dimension
+ */
+export interface AIConversationBreakdownEntry {
+  /** Tag or metadata value; null groups conversations without it */
+  value: string | null;
+  conversations: number;
+  turns: number;
+  total_prompt_tokens: number;
+  total_completion_tokens: number;
+}
+
+/**
+ * Options for grouping conversations by a business dimension
+ */
+export interface AIConversationBreakdownOptions {
+  /** "tag" or "metadata.<key>", e.g. "metadata.channel" */
+  group_by: string;
+  /** Only conversations of this chatbot */
+  chatbot_id?: string;
+  /** Only conversations that have all of these tags */
+  tags?: string[];
+  /** Only conversations whose metadata contains all of these values */
+  metadata?: Record<string, string>;
+}
+
 /**
  * Options for updating a conversation
  */
@@ -3286,10 +3792,18 @@ export interface KnowledgeBase extends KnowledgeBaseSummary {
   chunk_size: number;
   chunk_overlap: number;
   chunk_strategy: string;
+  vector_storage: KnowledgeBaseVectorStorage;
+  rerank_candidates: number;
   source: string;
   created_by?: string;
 }
 
+/**
+ * How a knowledge base indexes chunk embeddings for search.
+ * `halfvec` and `binary` search a compact quantized index and rerank on the full vectors.
+ */
+export type KnowledgeBaseVectorStorage = "vector" | "halfvec" | "binary";
+
 /**
  * Request to create a knowledge base
  */
@@ -3302,6 +3816,8 @@ export interface CreateKnowledgeBaseRequest {
   chunk_size?: number;
   chunk_overlap?: number;
   chunk_strategy?: string;
+  vector_storage?: KnowledgeBaseVectorStorage;
+  rerank_candidates?: number;
 }
 
 /**
@@ -3315,6 +3831,8 @@ export interface UpdateKnowledgeBaseRequest {
   chunk_size?: number;
   chunk_overlap?: number;
   chunk_strategy?: string;
+  vector_storage?: KnowledgeBaseVectorStorage;
+  rerank_candidates?: number;
   enabled?: boolean;
 }
 
@@ -3352,6 +3870,8 @@ export interface AddDocumentRequest {
   source?: string;
   mime_type?: string;
   metadata?: Record<string, string>;
+  /** Set to 'qa_pairs' to index `content` as question/answer pairs */
+  source_type?: string;
 }
 
 /**
@@ -3385,6 +3905,8 @@ export interface ChatbotKnowledgeBaseLink {
   enabled: boolean;
   max_chunks: number;
   similarity_threshold: number;
+  /** Days after which the freshness boost of a document halves, null = no boost */
+  freshness_half_life_days: number | null;
   priority: number;
   created_at: string;
 }
@@ -3397,6 +3919,8 @@ export interface LinkKnowledgeBaseRequest {
   priority?: number;
   max_chunks?: number;
   similarity_threshold?: number;
+  /** Rank newer documents higher: days after which the boost halves */
+  freshness_half_life_days?: number;
 }
 
 /**
@@ -3407,6 +3931,86 @@ export interface UpdateChatbotKnowledgeBaseRequest {
   max_chunks?: number;
   similarity_threshold?: number;
   enabled?: boolean;
+  /** Days after which the freshness boost halves, 0 removes the boost */
+  freshness_half_life_days?: number;
+}
+
+/**
+ * Shadow retrieval profile of a chatbot. It runs alongside the live retrieval on a
+ * sample of chat turns and records both results without affecting users.
+ */
+export interface ShadowRetrievalProfile {
+  chatbot_id: string;
+  enabled: boolean;
+  /** Fraction of chat turns with RAG context that also run the profile (0-1) */
+  sample_rate: number;
+  mode: "semantic" | "keyword" | "hybrid";
+  /** Chunks per linked knowledge base */
+  max_chunks: number;
+  similarity_threshold: number;
+  semantic_weight: number;
+  keyword_boost: number;
+  /** Overrides the freshness half-life of all links, 0 turns the boost off */
+  freshness_half_life_days?: number;
+  /** Also generate an answer from the shadow context and compare it with the live answer */
+  compare_answers: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+/**
+ * Request to create or replace a shadow retrieval profile
+ */
+export interface ShadowRetrievalProfileRequest {
+  enabled?: boolean;
+  sample_rate?: number;
+  mode?: "semantic" | "keyword" | "hybrid";
+  max_chunks?: number;
+  similarity_threshold?: number;
+  semantic_weight?: number;
+  keyword_boost?: number;
+  freshness_half_life_days?: number;
+  compare_answers?: boolean;
+}
+
+/**
+ * A chat turn on which a shadow retrieval profile ran
+ */
+export interface ShadowRetrievalRun {
+  id: string;
+  chatbot_id: string;
+  conversation_id?: string;
+  query_text: string;
+  /** The profile as it was when the run was recorded */
+  profile: ShadowRetrievalProfile;
+  live_chunk_ids: string[];
+  live_scores: number[];
+  shadow_chunk_ids: string[];
+  shadow_scores: number[];
+  /** Jaccard overlap of the live and shadow chunk sets (0-1) */
+  overlap?: number;
+  live_answer?: string;
+  shadow_answer?: string;
+  /** Word-level similarity of the live and shadow answers (0-1) */
+  answer_similarity?: number;
+  shadow_prompt_tokens: number;
+  shadow_completion_tokens: number;
+  duration_ms: number;
+  error_message?: string;
+  created_at: string;
+}
+
+/**
+ * Aggregate of all shadow retrieval runs of a chatbot
+ */
+export interface ShadowRetrievalSummary {
+  runs: number;
+  /** Runs in which the shadow profile retrieved different chunks */
+  changed_results: number;
+  average_overlap: number | null;
+  answers_compared: number;
+  average_answer_similarity: number | null;
+  errors: number;
 }
 
 /**
@@ -3440,6 +4044,109 @@ export interface SearchKnowledgeBaseResponse {
   query: string;
 }
 
+/**
+ * Request for a dry-run knowledge base search that explains candidate scores
+ */
+export interface ExplainKnowledgeBaseSearchRequest {
+  query: string;
+  mode?: "semantic" | "keyword" | "hybrid";
+  max_chunks?: number;
+  threshold?: number;
+  /** Hybrid mode: weight of the vector similarity, 0-1 */
+  semantic_weight?: number;
+  /** Hybrid mode: added to the score when the chunk contains the query */
+  keyword_boost?: number;
+  /** Hybrid mode: documents must have all these tags */
+  tags?: string[];
+  /** Hybrid mode: exact match on document metadata */
+  metadata?: Record<string, string>;
+  /** Number of top scoring chunks to explain, including cut ones */
+  candidates?: number;
+}
+
+/**
+ * Why a search candidate was left out of the results
+ */
+export type SearchCutReason =
+  | "filter"
+  | "no_embedding"
+  | "no_keyword_match"
+  | "threshold"
+  | "limit";
+
+/**
+ * A chunk considered by a search, with its component scores
+ */
+export interface SearchCandidate {
+  /** Position in the results, absent if the chunk was cut */
+  rank?: number;
+  chunk_id: string;
+  document_id: string;
+  document_title?: string;
+  content_preview: string;
+  score: number;
+  /** Absent if the chunk has no embedding */
+  vector_similarity?: number;
+  text_rank: number;
+  keyword_match: boolean;
+  phrase_match: boolean;
+  boost: number;
+  passes_filter: boolean;
+  included: boolean;
+  cut_reason?: SearchCutReason;
+}
+
+/**
+ * Response from a dry-run knowledge base search
+ */
+export interface ExplainKnowledgeBaseSearchResponse {
+  knowledge_base_id: string;
+  query: string;
+  mode: "semantic" | "keyword" | "hybrid";
+  threshold: number;
+  max_chunks: number;
+  semantic_weight: number;
+  keyword_weight: number;
+  keyword_boost: number;
+  filters_applied: string[];
+  embedding_model?: string;
+  embedding_dims: number;
+  query_embedding?: number[];
+  candidates: SearchCandidate[];
+  returned: number;
+  cut_counts: Partial<Record<SearchCutReason, number>>;
+}
+
+/**
+ * Status of a knowledge base re-index job
+ */
+export type KnowledgeBaseReindexStatus =
+  | "pending"
+  | "running"
+  | "completed"
+  | "failed";
+
+/**
+ * Background job that re-chunks and re-embeds all documents of a knowledge base
+ */
+export interface KnowledgeBaseReindexJob {
+  id: string;
+  knowledge_base_id: string;
+  status: KnowledgeBaseReindexStatus;
+  total_documents: number;
+  /** Documents reached so far, including failed ones */
+  processed_documents: number;
+  failed_documents: number;
+  /** Fraction of documents reached (0-1) */
+  progress: number;
+  error_message?: string;
+  created_by?: string;
+  created_at: string;
+  started_at?: string;
+  completed_at?: string;
+  updated_at: string;
+}
+
 /**
  * Entity type classification (matching backend EntityType)
  */
@@ -3572,10 +4279,14 @@ export interface TableColumn {
   is_primary_key: boolean;
   is_foreign_key: boolean;
   is_unique: boolean;
+  /** Computed by a GENERATED ALWAYS AS expression and not writable */
+  is_generated?: boolean;
   max_length?: number;
   position: number;
   /** Column description from PostgreSQL comment */
   description?: string;
+  /** Full text search language and weight configured by an admin */
+  text_search?: { language: string; weight?: string };
   /** JSONB schema if this is a JSONB/JSON column with schema annotation */
   jsonb_schema?: JSONBSchema;
 }
@@ -3615,6 +4326,8 @@ export interface TableDetails {
   foreign_keys: TableForeignKey[];
   indexes: TableIndex[];
   rls_enabled: boolean;
+  /** Tables, and views that accept inserts, updates and deletes */
+  is_updatable?: boolean;
 }
 
 // ============================================================================
@@ -3699,6 +4412,11 @@ export interface AdminBucket {
   public: boolean;
   allowed_mime_types: string[] | null;
   max_file_size: number | null;
+  content_disposition: "attachment" | "inline";
+  inline_mime_types: string[] | null;
+  nosniff: boolean;
+  html_policy: BucketHtmlPolicy;
+  transformations_enabled: boolean;
   created_at: string;
   updated_at: string;
 }
@@ -4394,8 +5112,10 @@ export interface EnableRealtimeResponse {
   table: string;
   /** Events being tracked */
   events: string[];
-  /** Name of the created trigger */
-  trigger_name: string;
+  /** Name of the created trigger, unset when the table uses logical replication */
+  trigger_name?: string;
+  /** Publication the table was added to, when the server uses logical replication */
+  publication?: string;
   /** Columns excluded from notifications */
   exclude?: string[];
 }